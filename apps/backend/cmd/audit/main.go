@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const usage = "Usage: audit [-config path] [-org id] [-json] [verify|anchor]"
+
+func main() {
+	var (
+		configPath = flag.String("config", "configs/development.yaml", "Path to configuration file")
+		orgID      = flag.String("org", "", "Organization ID (default: all organizations)")
+		jsonOutput = flag.Bool("json", false, "Print verification reports as JSON")
+	)
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		log.Fatal(usage)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.NewWithConfig(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	auditService := logging.NewAuditService(db)
+
+	orgIDs := []string{*orgID}
+	if *orgID == "" {
+		orgIDs, err = auditService.ChainOrganizations()
+		if err != nil {
+			log.Fatalf("Failed to list organizations: %v", err)
+		}
+	}
+
+	switch flag.Arg(0) {
+	case "verify":
+		valid := true
+		for _, id := range orgIDs {
+			report, err := auditService.VerifyChain(id)
+			if err != nil {
+				log.Fatalf("Failed to verify audit chain for %s: %v", id, err)
+			}
+			printReport(report, *jsonOutput)
+			valid = valid && report.Valid
+		}
+		if !valid {
+			os.Exit(1)
+		}
+	case "anchor":
+		for _, id := range orgIDs {
+			anchor, err := auditService.CreateAnchor(id)
+			if err != nil {
+				log.Fatalf("Failed to anchor audit chain for %s: %v", id, err)
+			}
+			if anchor == nil {
+				fmt.Printf("%s: no new records since last anchor\n", id)
+				continue
+			}
+			fmt.Printf("%s: anchored sequence %d digest %s\n", id, anchor.Sequence, anchor.Digest)
+		}
+	default:
+		log.Fatal(usage)
+	}
+}
+
+func printReport(report *types.AuditChainReport, asJSON bool) {
+	if asJSON {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	status := "OK"
+	if !report.Valid {
+		status = "TAMPERED"
+	}
+	fmt.Printf("%s: %s (%d records, %d anchors, head sequence %d)\n",
+		report.OrganizationID, status, report.RecordsChecked, report.AnchorsChecked, report.HeadSequence)
+	for _, failure := range report.Failures {
+		target := failure.RecordID
+		if target == "" {
+			target = "anchor " + failure.AnchorID
+		}
+		fmt.Printf("  sequence %d (%s): %s\n", failure.Sequence, target, failure.Reason)
+	}
+}
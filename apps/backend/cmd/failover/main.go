@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const usage = `Usage: failover [-config path] [-json] status
+       failover [-config path] [-json] -from region -reason text [-dry-run] promote
+       failover [-config path] [-json] -by region [-reason text] fence`
+
+func main() {
+	var (
+		configPath = flag.String("config", "configs/development.yaml", "Path to configuration file")
+		fromRegion = flag.String("from", "", "Region to take over from (promote)")
+		byRegion   = flag.String("by", "", "Region that took over from this one (fence)")
+		reason     = flag.String("reason", "", "Reason recorded with the failover")
+		dryRun     = flag.Bool("dry-run", false, "Report the failover steps without running them")
+		jsonOutput = flag.Bool("json", false, "Print results as JSON")
+	)
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		log.Fatal(usage)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.NewWithConfig(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	regionCfg := cfg.Region
+	peers := make([]services.RegionPeer, 0, len(regionCfg.Peers))
+	for _, peer := range regionCfg.Peers {
+		peers = append(peers, services.RegionPeer{Name: peer.Name, URL: peer.URL})
+	}
+	failoverService := services.NewFailoverService(db, services.RegionSettings{
+		Region:  regionCfg.Name,
+		BaseURL: cfg.Server.GetBaseURL(),
+		Token:   regionCfg.ReplicationToken,
+		Peers:   peers,
+	}, regionCfg.Role)
+	failoverService.SetAuditor(logging.NewAuditService(db))
+
+	ctx := context.Background()
+	operator := "cli:" + os.Getenv("USER")
+
+	switch flag.Arg(0) {
+	case "status":
+		status, err := failoverService.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to get deployment state: %v", err)
+		}
+		if *jsonOutput {
+			printJSON(status)
+			return
+		}
+		printState(status.State)
+		for _, failover := range status.Failovers {
+			fmt.Printf("%s  %s -> %s  %s  %s\n", failover.StartedAt.Format("2006-01-02 15:04:05"),
+				failover.FromRegion, failover.ToRegion, failover.Status, failover.Reason)
+		}
+	case "promote":
+		if *fromRegion == "" || *reason == "" {
+			log.Fatal(usage)
+		}
+		failover, err := failoverService.Failover(ctx, &types.FailoverRequest{
+			FromRegion: *fromRegion,
+			Reason:     *reason,
+			DryRun:     *dryRun,
+		}, operator)
+		if err != nil {
+			log.Fatalf("Failover failed: %v", err)
+		}
+		if *jsonOutput {
+			printJSON(failover)
+		} else {
+			printFailover(failover)
+		}
+		if failover.Status != types.FailoverStatusCompleted {
+			os.Exit(1)
+		}
+	case "fence":
+		if *byRegion == "" {
+			log.Fatal(usage)
+		}
+		state, err := failoverService.Fence(ctx, &types.FenceRequest{Region: *byRegion, Reason: *reason}, operator)
+		if err != nil {
+			log.Fatalf("Fence failed: %v", err)
+		}
+		if *jsonOutput {
+			printJSON(state)
+			return
+		}
+		printState(state)
+	default:
+		log.Fatal(usage)
+	}
+}
+
+func printJSON(value interface{}) {
+	data, _ := json.MarshalIndent(value, "", "  ")
+	fmt.Println(string(data))
+}
+
+func printState(state *types.DeploymentState) {
+	fmt.Printf("%s: %s (epoch %d)\n", state.Region, state.Role, state.Epoch)
+	if state.ReadOnly {
+		fmt.Printf("  read-only: %s\n", state.ReadOnlyReason)
+	}
+}
+
+func printFailover(failover *types.Failover) {
+	mode := ""
+	if failover.DryRun {
+		mode = " (dry run)"
+	}
+	fmt.Printf("Failover %s -> %s%s: %s\n", failover.FromRegion, failover.ToRegion, mode, failover.Status)
+	for _, step := range failover.Steps {
+		fmt.Printf("  %-18s %-8s %s\n", step.Name, step.Status, step.Detail)
+	}
+}
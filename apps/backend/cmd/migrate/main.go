@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -15,12 +17,13 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
 )
 
 func main() {
 	// Check command
 	if len(os.Args) < 2 {
-		log.Fatal("Usage: migrate [up|down|status|setup-admin]")
+		log.Fatal("Usage: migrate [up|down|status|partitions|setup-admin]")
 	}
 
 	command := os.Args[1]
@@ -90,6 +93,11 @@ func main() {
 	switch command {
 	case "up", "down", "status":
 		runMigrations(db, command)
+		if command == "up" {
+			createPartitions(db, cfg)
+		}
+	case "partitions":
+		maintainPartitions(db, cfg)
 	case "setup-admin":
 		setupAdminUser(db)
 	default:
@@ -159,6 +167,37 @@ func runMigrations(db *sql.DB, command string) {
 	}
 }
 
+func newPartitionService(db *sql.DB, cfg *config.Config) *services.PartitionService {
+	partitioning := cfg.Database.Partitioning
+	partitionService := services.NewPartitionService(db, partitioning.Premake,
+		time.Duration(cfg.Logging.RetentionDays)*24*time.Hour, partitioning.HealthCheckRetention)
+	partitionService.SetDetachOnly(partitioning.DetachOnly)
+	return partitionService
+}
+
+// createPartitions makes sure the log tables have partitions for the
+// coming months, so inserts succeed even before the worker first runs
+func createPartitions(db *sql.DB, cfg *config.Config) {
+	created, err := newPartitionService(db, cfg).CreatePartitions(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to create log partitions: %v", err)
+	}
+	if len(created) > 0 {
+		log.Printf("Created log partitions: %s", strings.Join(created, ", "))
+	}
+}
+
+// maintainPartitions creates upcoming log partitions and removes expired
+// ones, as the worker does on its interval
+func maintainPartitions(db *sql.DB, cfg *config.Config) {
+	result, err := newPartitionService(db, cfg).Maintain(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to maintain log partitions: %v", err)
+	}
+	log.Printf("Log partitions: %d created, %d detached, %d dropped, %d kept under legal hold",
+		len(result.Created), len(result.Detached), len(result.Dropped), len(result.Held))
+}
+
 func setupAdminUser(db *sql.DB) {
 	// Check if admin user already exists
 	var exists bool
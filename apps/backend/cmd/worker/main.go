@@ -9,10 +9,26 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/a2a"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/commandpolicy"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/discovery"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging/plugins/file"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mailer"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/ratelimit"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/residency"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/serverlogs"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/telemetry"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/jmoiron/sqlx"
 )
 
 func main() {
@@ -35,7 +51,7 @@ func main() {
 	defer db.Close()
 
 	// Create context for graceful shutdown
-	_, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Initialize transport manager
@@ -44,6 +60,11 @@ func main() {
 	if err := transportManager.Initialize(context.Background()); err != nil {
 		log.Printf("Warning: Failed to initialize transport manager: %v", err)
 	}
+	commandPolicy, err := commandpolicy.New(cfg.Transport.STDIOCommands)
+	if err != nil {
+		log.Fatalf("Invalid stdio_commands policy: %v", err)
+	}
+	transportManager.SetCommandPolicy(commandPolicy)
 
 	// Initialize services
 	// TODO: Initialize discovery service and other background workers
@@ -53,8 +74,315 @@ func main() {
 		FailureThreshold: cfg.Discovery.FailureThreshold,
 		RecoveryTimeout:  cfg.Discovery.RecoveryTimeout,
 		SingleTenant:     true,
+		CommandPolicy:    commandPolicy,
 	}
 	discoveryService := discovery.NewService(db, discoveryConfig, transportManager)
+	notificationService := services.NewNotificationService(db)
+	discoveryService.SetNotifier(notificationService)
+
+	offline := cfg.Gateway.Offline
+	offlinePolicy, err := airgap.New(offline.Enabled, offline.Mirrors, offline.AllowedHosts)
+	if err != nil {
+		log.Fatalf("Invalid gateway.offline configuration: %v", err)
+	}
+
+	// Only the active region of a disaster-recovery pair runs quota checks,
+	// telemetry and webhook deliveries; a failover moves them without a
+	// restart
+	failoverService := services.NewFailoverService(db, services.RegionSettings{Region: cfg.Region.Name}, cfg.Region.Role)
+
+	// Alert server owners directly when their server changes state
+	notifyCfg := cfg.Notifications
+	smtp := notifyCfg.SMTP
+	smtpMailer := mailer.NewSMTPMailer(smtp.Host, smtp.Port, smtp.Username, smtp.Password, smtp.From)
+	serverOwnerService := services.NewServerOwnerService(db, cfg.Server.GetBaseURL(), notifyCfg.OwnerEscalateAfter)
+	serverOwnerService.SetEgressPolicy(offlinePolicy)
+	serverOwnerService.SetNotifier(notificationService)
+	serverOwnerService.SetActiveCheck(failoverService.IsActive)
+	if smtpMailer != nil {
+		serverOwnerService.SetMailer(smtpMailer)
+	}
+	discoveryService.SetOwnerAlerts(serverOwnerService)
+
+	// Register labelled Kubernetes Services as MCP servers, healthy while
+	// they have ready pods
+	if k8sCfg := cfg.Discovery.Kubernetes; k8sCfg.Enabled {
+		kubernetesProvider, err := discovery.NewKubernetesProvider(discoveryService, discovery.KubernetesConfig{
+			APIServer:      k8sCfg.APIServer,
+			TokenFile:      k8sCfg.TokenFile,
+			CAFile:         k8sCfg.CAFile,
+			LabelSelector:  k8sCfg.LabelSelector,
+			ClusterDomain:  k8sCfg.ClusterDomain,
+			OrganizationID: k8sCfg.OrganizationID,
+			Namespaces:     k8sCfg.Namespaces,
+			ResyncInterval: k8sCfg.ResyncInterval,
+		})
+		if err != nil {
+			log.Printf("Warning: Kubernetes discovery disabled: %v", err)
+		} else {
+			go kubernetesProvider.Run(ctx, failoverService.IsActive)
+		}
+	}
+
+	// Fetch A2A agent cards again so their capabilities stay current
+	if interval := cfg.Discovery.A2ACards.RefreshInterval; interval > 0 {
+		cardClient := a2a.NewClient(30*time.Second, 3)
+		cardClient.SetEgressPolicy(offlinePolicy)
+		go runA2ACardRefresh(ctx, a2a.NewService(db), cardClient, failoverService, interval)
+	}
+
+	// Post gateway events to the webhooks organizations registered for them
+	webhooksCfg := notifyCfg.Webhooks
+	webhookService := services.NewWebhookService(db, services.WebhookSettings{
+		MaxAttempts:  webhooksCfg.MaxAttempts,
+		RetryBackoff: webhooksCfg.RetryBackoff,
+		Timeout:      webhooksCfg.Timeout,
+	})
+	webhookService.SetEgressPolicy(offlinePolicy)
+	notificationService.SetWebhooks(webhookService)
+	if webhooksCfg.DeliveryInterval > 0 {
+		go runWebhookDelivery(ctx, webhookService, failoverService, webhooksCfg.DeliveryInterval)
+	}
+	if webhooksCfg.Retention > 0 {
+		go runWebhookPruning(ctx, webhookService, webhooksCfg.Retention)
+	}
+
+	// Periodically anchor audit hash chains so tampering can be detected
+	if cfg.Logging.AuditAnchorInterval > 0 {
+		go runAuditAnchoring(ctx, logging.NewAuditService(db), cfg.Logging.AuditAnchorInterval)
+	}
+
+	// Stream audit records to the organizations' SIEM sinks
+	if cfg.Logging.AuditExportInterval > 0 {
+		auditExportService := logging.NewAuditExportService(db)
+		auditExportService.SetEgressPolicy(offlinePolicy)
+		go runAuditExport(ctx, auditExportService, failoverService, cfg.Logging.AuditExportInterval)
+	}
+
+	// Expire dynamically registered OAuth clients that were never used
+	if cfg.Auth.OAuthClientCleanupInterval > 0 {
+		oauthService := auth.NewOAuthService(sqlx.NewDb(db, "postgres"), cfg.Auth.JWTSecret, cfg.Server.GetBaseURL(), nil)
+		go runOAuthClientCleanup(ctx, oauthService, cfg.Auth.OAuthClientCleanupInterval)
+	}
+
+	// Notify admins about quotas nearing their limit and expiring certificates
+	if notifyCfg.CheckInterval > 0 {
+		limitsService := services.NewLimitsService(db, nil)
+		go runNotificationChecks(ctx, notificationService, limitsService, failoverService, notifyCfg.CheckInterval,
+			notifyCfg.QuotaThresholdPct, notifyCfg.CertificateExpiryDays)
+	}
+
+	// Email digests of unread notifications to admins who opted in
+	if notifyCfg.DigestInterval > 0 {
+		if smtpMailer != nil {
+			go runNotificationDigests(ctx, notificationService, smtpMailer, notifyCfg.DigestInterval)
+		} else {
+			log.Println("Notification digests disabled: no SMTP host configured")
+		}
+	}
+
+	// Escalate owner alerts left unacknowledged to organization admins
+	if notifyCfg.OwnerEscalationInterval > 0 {
+		go runOwnerAlertEscalation(ctx, serverOwnerService, failoverService, notifyCfg.OwnerEscalationInterval)
+	}
+
+	// Notify admins as metered usage crosses their monthly budgets
+	if notifyCfg.BudgetCheckInterval > 0 {
+		usageBudgetService := services.NewUsageBudgetService(db)
+		usageBudgetService.SetNotifier(notificationService)
+		go runBudgetEvaluation(ctx, usageBudgetService, failoverService, notifyCfg.BudgetCheckInterval)
+	}
+
+	// Run queued tool executions with the same policies, limits and budgets
+	// as calls through the server, as the caller who queued them
+	if jobsCfg := cfg.Gateway.Jobs; jobsCfg.Workers > 0 {
+		namespaceService := services.NewNamespaceService(db, services.NewEndpointService(db, cfg.Server.GetBaseURL()))
+		namespaceService.SetCommandPolicy(commandPolicy)
+		residencyPolicy, err := residency.New(cfg.Gateway.Residency.StorageRegion, cfg.Gateway.Residency.RequireMarked)
+		if err != nil {
+			log.Fatalf("Invalid residency configuration: %v", err)
+		}
+		namespaceService.SetResidencyPolicy(residencyPolicy)
+		mockServerService := services.NewMockServerService(db)
+		mockServerService.SetToolRefresher(discoveryService)
+		namespaceService.SetMockTools(mockServerService)
+		namespaceService.SetToolPolicy(services.NewToolPolicyService(db))
+		namespaceService.SetHeaderPropagation(services.NewHeaderPropagationService(db))
+		namespaceService.SetIdentityInjection(services.NewIdentityInjectionService(db))
+		if dlpCfg := cfg.Gateway.DLP; dlpCfg.Enabled {
+			if err := services.ValidateDLPDefaults(dlpCfg.GetDefaultSensitivity(), dlpCfg.GetDefaultAction()); err != nil {
+				log.Fatalf("Invalid gateway.dlp configuration: %v", err)
+			}
+			dlpScanner, err := services.NewDLPScanner(dlpCfg.External.URL, dlpCfg.External.APIKey, dlpCfg.External.Timeout,
+				dlpCfg.External.FailOpen, offlinePolicy)
+			if err != nil {
+				log.Fatalf("Invalid gateway.dlp.external configuration: %v", err)
+			}
+			namespaceService.SetDLP(services.NewDLPService(db, dlpScanner, dlpCfg.GetDefaultSensitivity(), dlpCfg.GetDefaultAction()))
+		}
+		approvalService := services.NewApprovalService(db, cfg.Server.GetBaseURL(), services.ApprovalSettings{
+			Timeout:      cfg.Gateway.Approvals.Timeout,
+			MaxTimeout:   cfg.Gateway.Approvals.MaxTimeout,
+			PollInterval: cfg.Gateway.Approvals.PollInterval,
+		})
+		approvalService.SetNotifier(notificationService)
+		approvalService.SetEgressPolicy(offlinePolicy)
+		if smtpMailer != nil {
+			approvalService.SetMailer(smtpMailer)
+		}
+		namespaceService.SetApprovals(approvalService)
+		namespaceService.SetToolLimits(services.NewToolLimitService(db, ratelimit.NewMemoryLimiter()))
+		meteringService := services.NewMeteringService(db, 0)
+		meteringService.Start()
+		defer meteringService.Stop()
+		namespaceService.SetUsageMeter(meteringService)
+		namespaceService.SetBudgets(services.NewUsageBudgetService(db))
+
+		toolJobService := services.NewToolJobService(db, namespaceService)
+		toolJobService.SetExecutor(namespaceService)
+		pollInterval := jobsCfg.PollInterval
+		if pollInterval <= 0 {
+			pollInterval = services.DefaultToolJobPollInterval
+		}
+		for i := 0; i < jobsCfg.Workers; i++ {
+			go runToolJobConsumer(ctx, toolJobService, failoverService, pollInterval)
+		}
+		if jobsCfg.Retention > 0 {
+			go runToolJobPruning(ctx, toolJobService, jobsCfg.Retention)
+		}
+	}
+
+	// Expire time-boxed role and namespace grants, reminding admins and
+	// grantees before they end
+	if notifyCfg.GrantExpiryInterval > 0 {
+		accessGrantService := services.NewAccessGrantService(db, notifyCfg.GrantReminderBefore)
+		accessGrantService.SetNotifier(notificationService)
+		accessGrantService.SetAuditor(logging.NewAuditService(db))
+		go runGrantExpiry(ctx, accessGrantService, notifyCfg.GrantExpiryInterval)
+	}
+
+	// End break-glass sessions whose window passed and alert admins
+	breakGlassService := services.NewBreakGlassService(db, nil, cfg.Auth.BreakGlassWindow)
+	breakGlassService.SetNotifier(notificationService)
+	breakGlassService.SetAuditor(logging.NewAuditService(db))
+	go runBreakGlassExpiry(ctx, breakGlassService)
+
+	// Drop persisted upstream server output past its retention
+	if serverLogsCfg := cfg.Transport.ServerLogs; serverLogsCfg.Enabled && serverLogsCfg.Retention > 0 {
+		go runServerLogPruning(ctx, serverlogs.NewDBStore(db), serverLogsCfg.Retention)
+	}
+
+	// Write queued CSV and Parquet log exports and remove expired files. The
+	// worker reads the same log backend as the server.
+	if exportsCfg := cfg.Logging.Exports; exportsCfg.PollInterval > 0 {
+		if err := logging.RegisterPlugin(file.NewFilePlugin()); err != nil {
+			log.Fatalf("Failed to register file logging plugin: %v", err)
+		}
+		loggingService, err := logging.NewService(&logging.LoggingConfig{
+			Backend:     cfg.Logging.Backend,
+			Level:       logging.LogLevel(cfg.Logging.Level),
+			Environment: cfg.Logging.Environment,
+			Config:      cfg.Logging.Config,
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize logging service: %v", err)
+		}
+		logExportService := services.NewLogExportService(db, loggingService, services.NewLimitsService(db, nil),
+			services.NewFileExportStorage(exportsCfg.GetStorageDir()), exportsCfg.PlanLimits())
+		logExportService.SetRetention(exportsCfg.GetRetention())
+		go runLogExports(ctx, logExportService, exportsCfg.PollInterval)
+	}
+
+	// Move old execution logs and session events to cold storage and serve
+	// rehydration requests
+	if archiveCfg := cfg.Logging.Archive; archiveCfg.Interval > 0 {
+		archiveStorage, err := services.NewArchiveStorage(archiveCfg.Storage, archiveCfg.GetStorageDir(), archiveCfg.S3Config(), offlinePolicy)
+		if err != nil {
+			log.Fatalf("Failed to initialize archive storage: %v", err)
+		}
+		archiveService := services.NewArchiveService(db, archiveStorage, archiveCfg.GetRehydrationTTL())
+		go runArchival(ctx, archiveService, archiveCfg.Interval)
+	}
+
+	// Delete logs past each organization's retention. The rows purged are
+	// counted in the logs and, with StatsD on, as metrics.
+	if retentionCfg := cfg.Logging.RetentionEnforcement; retentionCfg.Interval > 0 {
+		retentionService := services.NewRetentionService(db, retentionCfg.BatchSize, retentionCfg.DryRun)
+		retentionService.SetEgressPolicy(offlinePolicy)
+		if statsd := cfg.Observability.StatsD; statsd.Enabled {
+			emitter, err := observability.NewStatsDEmitter(&observability.StatsDConfig{
+				GlobalTags:  statsd.GlobalTags,
+				SampleRates: statsd.SampleRates,
+				MetricNames: statsd.MetricNames,
+				Address:     statsd.Address,
+				Prefix:      statsd.Prefix,
+				Flavor:      statsd.Flavor,
+				SampleRate:  statsd.SampleRate,
+			})
+			if err != nil {
+				log.Fatalf("Failed to initialize statsd emitter: %v", err)
+			}
+			defer emitter.Close()
+			retentionService.SetMetricEmitter(func(metric *types.Metric) {
+				if err := emitter.Emit(metric); err != nil {
+					log.Printf("Failed to emit retention metric: %v", err)
+				}
+			})
+		}
+		go runRetentionEnforcement(ctx, retentionService, retentionCfg.Interval)
+	}
+
+	// Embed new and changed tools, prompts and resources for semantic search
+	if embeddingsCfg := cfg.Search.Embeddings; embeddingsCfg.Enabled {
+		embedder, err := services.NewHTTPEmbedder(embeddingsCfg.Endpoint, embeddingsCfg.Model,
+			embeddingsCfg.APIKey, offlinePolicy)
+		if err != nil {
+			log.Printf("Search embeddings disabled: %v", err)
+		} else {
+			go runSearchEmbedding(ctx, services.NewSearchService(db, embedder),
+				embeddingsCfg.GetInterval(), embeddingsCfg.GetBatchSize())
+		}
+	}
+
+	// Keep monthly log partitions ready ahead of time and drop expired ones
+	if partitioning := cfg.Database.Partitioning; partitioning.Interval > 0 {
+		partitionService := services.NewPartitionService(db, partitioning.Premake,
+			time.Duration(cfg.Logging.RetentionDays)*24*time.Hour, partitioning.HealthCheckRetention)
+		partitionService.SetDetachOnly(partitioning.DetachOnly)
+		go runPartitionMaintenance(ctx, partitionService, partitioning.Interval)
+	}
+
+	// Refresh the views behind the admin stats endpoint
+	if statsCfg := cfg.Observability.Stats; statsCfg.RefreshInterval > 0 {
+		go runStatsRefresh(ctx, services.NewAdminStatsService(db, statsCfg.GetStaleAfter()), statsCfg.RefreshInterval)
+	}
+
+	// Pull servers, namespaces and endpoints changed in the peer regions
+	if regionCfg := cfg.Region; len(regionCfg.Peers) > 0 && regionCfg.Interval > 0 {
+		peers := make([]services.RegionPeer, 0, len(regionCfg.Peers))
+		for _, peer := range regionCfg.Peers {
+			peers = append(peers, services.RegionPeer{Name: peer.Name, URL: peer.URL})
+		}
+		regionService := services.NewRegionService(db, services.RegionSettings{
+			Region:    regionCfg.Name,
+			BaseURL:   cfg.Server.GetBaseURL(),
+			GlobalURL: regionCfg.GlobalURL,
+			Token:     regionCfg.ReplicationToken,
+			Peers:     peers,
+			BatchSize: regionCfg.GetBatchSize(),
+			Interval:  regionCfg.Interval,
+		})
+		go runCatalogReplication(ctx, regionService, regionCfg.Interval)
+	}
+
+	// Report anonymous aggregate usage unless opted out
+	telemetryReporter := telemetry.NewReporter(telemetry.NewDBSource(db), cfg.Telemetry.Enabled,
+		cfg.Telemetry.Endpoint, cfg.Telemetry.Interval, offlinePolicy)
+	if telemetryReporter.Enabled() {
+		go runTelemetry(ctx, telemetryReporter, failoverService)
+	} else {
+		log.Printf("Telemetry off: %s", telemetryReporter.DisabledReason())
+	}
 
 	log.Println("Background worker started")
 
@@ -83,3 +411,580 @@ func main() {
 
 	log.Println("Worker stopped")
 }
+
+// runAuditAnchoring records an anchor digest for every audit chain that has
+// advanced since the previous run
+func runAuditAnchoring(ctx context.Context, auditService *logging.AuditService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			anchors, err := auditService.CreateAnchors()
+			if err != nil {
+				log.Printf("Error anchoring audit chains: %v", err)
+			}
+			for _, anchor := range anchors {
+				log.Printf("Audit anchor org=%s sequence=%d digest=%s", anchor.OrganizationID, anchor.Sequence, anchor.Digest)
+			}
+		}
+	}
+}
+
+// runAuditExport delivers the audit records of due sinks every interval
+// while this region is active
+func runAuditExport(ctx context.Context, auditExportService *logging.AuditExportService, failover *services.FailoverService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !failover.IsActive(ctx) {
+				continue
+			}
+			delivered, err := auditExportService.DeliverPending(ctx, 20)
+			if err != nil {
+				log.Printf("Error exporting audit records: %v", err)
+			} else if delivered > 0 {
+				log.Printf("Exported %d audit records", delivered)
+			}
+		}
+	}
+}
+
+// runOAuthClientCleanup deletes dynamically registered OAuth clients that
+// outlived their organization's unused client TTL
+func runOAuthClientCleanup(ctx context.Context, oauthService *auth.OAuthService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := oauthService.CleanupUnusedClients(ctx)
+			if err != nil {
+				log.Printf("Error cleaning up unused OAuth clients: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("Deleted %d unused OAuth clients", deleted)
+			}
+		}
+	}
+}
+
+// runNotificationChecks raises quota and certificate expiry notifications
+// while this region is active
+func runNotificationChecks(ctx context.Context, notificationService *services.NotificationService, quotas services.QuotaSource, failover *services.FailoverService, interval time.Duration, thresholdPct, certificateDays int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !failover.IsActive(ctx) {
+				continue
+			}
+			if _, err := notificationService.CheckQuotas(ctx, quotas, thresholdPct); err != nil {
+				log.Printf("Error checking quotas for notifications: %v", err)
+			}
+			if _, err := notificationService.CheckCertificates(ctx, nil, certificateDays); err != nil {
+				log.Printf("Error checking certificates for notifications: %v", err)
+			}
+		}
+	}
+}
+
+// runNotificationDigests emails unread notification digests
+func runNotificationDigests(ctx context.Context, notificationService *services.NotificationService, m mailer.Mailer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, err := notificationService.SendDigests(ctx, m)
+			if err != nil {
+				log.Printf("Error sending notification digests: %v", err)
+			}
+			if sent > 0 {
+				log.Printf("Sent %d notification digests", sent)
+			}
+		}
+	}
+}
+
+// runOwnerAlertEscalation escalates unacknowledged server owner alerts
+// while this region is active
+func runOwnerAlertEscalation(ctx context.Context, serverOwnerService *services.ServerOwnerService, failover *services.FailoverService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !failover.IsActive(ctx) {
+				continue
+			}
+			escalated, err := serverOwnerService.Escalate(ctx)
+			if err != nil {
+				log.Printf("Error escalating server owner alerts: %v", err)
+			}
+			if escalated > 0 {
+				log.Printf("Escalated %d unacknowledged server owner alerts", escalated)
+			}
+		}
+	}
+}
+
+// runBudgetEvaluation notifies admins of usage budget thresholds crossed
+// while this region is active
+func runBudgetEvaluation(ctx context.Context, usageBudgetService *services.UsageBudgetService, failover *services.FailoverService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !failover.IsActive(ctx) {
+				continue
+			}
+			created, err := usageBudgetService.Evaluate(ctx)
+			if err != nil {
+				log.Printf("Error evaluating usage budgets: %v", err)
+			}
+			if created > 0 {
+				log.Printf("Created %d usage budget notifications", created)
+			}
+		}
+	}
+}
+
+// runToolJobConsumer runs queued tool jobs one at a time while this region
+// is active, polling every interval while the queue is empty
+func runToolJobConsumer(ctx context.Context, toolJobService *services.ToolJobService, failover *services.FailoverService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !failover.IsActive(ctx) {
+				continue
+			}
+			for ctx.Err() == nil {
+				ran, err := toolJobService.RunNext(ctx)
+				if err != nil {
+					log.Printf("Error running tool job: %v", err)
+				}
+				if !ran || err != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// runToolJobPruning hourly deletes tool jobs that finished more than
+// retention ago
+func runToolJobPruning(ctx context.Context, toolJobService *services.ToolJobService, retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := toolJobService.Prune(ctx, retention)
+			if err != nil {
+				log.Printf("Error pruning tool jobs: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("Pruned %d tool jobs", deleted)
+			}
+		}
+	}
+}
+
+// runWebhookDelivery posts due webhook deliveries until none are left
+func runWebhookDelivery(ctx context.Context, webhookService *services.WebhookService, failover *services.FailoverService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !failover.IsActive(ctx) {
+				continue
+			}
+			for ctx.Err() == nil {
+				attempted, err := webhookService.DeliverDue(ctx)
+				if err != nil {
+					log.Printf("Error delivering webhooks: %v", err)
+				}
+				if attempted == 0 || err != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// runA2ACardRefresh every minute refreshes the agent cards checked longer
+// than interval ago, a batch at a time
+func runA2ACardRefresh(ctx context.Context, a2aService *a2a.Service, a2aClient *a2a.Client, failover *services.FailoverService, interval time.Duration) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !failover.IsActive(ctx) {
+				continue
+			}
+			refreshed, err := a2aService.RefreshStaleCards(ctx, a2aClient, interval, 50)
+			if err != nil {
+				log.Printf("Error refreshing A2A agent cards: %v", err)
+			}
+			if refreshed > 0 {
+				log.Printf("Refreshed %d A2A agent cards", refreshed)
+			}
+		}
+	}
+}
+
+// runWebhookPruning hourly deletes finished webhook deliveries older than
+// retention
+func runWebhookPruning(ctx context.Context, webhookService *services.WebhookService, retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := webhookService.Prune(ctx, retention)
+			if err != nil {
+				log.Printf("Error pruning webhook deliveries: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("Pruned %d webhook deliveries", deleted)
+			}
+		}
+	}
+}
+
+// runGrantExpiry expires time-boxed access grants and sends reminders for
+// those about to end
+func runGrantExpiry(ctx context.Context, accessGrantService *services.AccessGrantService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, reminded, err := accessGrantService.ProcessExpiry(ctx)
+			if err != nil {
+				log.Printf("Error processing access grant expiry: %v", err)
+			}
+			if expired > 0 || reminded > 0 {
+				log.Printf("Expired %d access grants, reminded %d", expired, reminded)
+			}
+		}
+	}
+}
+
+// runBreakGlassExpiry ends emergency sessions once their window passes.
+// Their tokens stop working at expiry regardless; ending them records it.
+func runBreakGlassExpiry(ctx context.Context, breakGlassService *services.BreakGlassService) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ended, err := breakGlassService.ExpireSessions(ctx)
+			if err != nil {
+				log.Printf("Error expiring break-glass sessions: %v", err)
+			}
+			if ended > 0 {
+				log.Printf("Ended %d expired break-glass sessions", ended)
+			}
+		}
+	}
+}
+
+// runServerLogPruning deletes captured server output older than retention
+func runServerLogPruning(ctx context.Context, store *serverlogs.DBStore, retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := store.Prune(ctx, time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("Error pruning server logs: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("Pruned %d server log lines", deleted)
+			}
+		}
+	}
+}
+
+// runLogExports processes pending log exports every interval and hourly
+// removes the files of expired ones
+func runLogExports(ctx context.Context, logExportService *services.LogExportService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastExpiry := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			processed, err := logExportService.ProcessPending(ctx, 5)
+			if err != nil {
+				log.Printf("Error processing log exports: %v", err)
+			} else if processed > 0 {
+				log.Printf("Processed %d log exports", processed)
+			}
+
+			if time.Since(lastExpiry) < time.Hour {
+				continue
+			}
+			lastExpiry = time.Now()
+			expired, err := logExportService.ExpireExports(ctx)
+			if err != nil {
+				log.Printf("Error expiring log exports: %v", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf("Expired %d log exports", expired)
+			}
+		}
+	}
+}
+
+// runArchival archives expired days every interval and checks for pending
+// and expired rehydrations every minute, so investigations don't wait for
+// the next archival run
+func runArchival(ctx context.Context, archiveService *services.ArchiveService, interval time.Duration) {
+	ticker := time.NewTicker(min(interval, time.Minute))
+	defer ticker.Stop()
+	var lastArchive time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			processed, err := archiveService.ProcessRehydrations(ctx, 5)
+			if err != nil {
+				log.Printf("Error processing archive rehydrations: %v", err)
+			} else if processed > 0 {
+				log.Printf("Processed %d archive rehydrations", processed)
+			}
+			expired, err := archiveService.ExpireRehydrations(ctx)
+			if err != nil {
+				log.Printf("Error expiring archive rehydrations: %v", err)
+			} else if expired > 0 {
+				log.Printf("Expired %d archive rehydrations", expired)
+			}
+
+			if time.Since(lastArchive) < interval {
+				continue
+			}
+			lastArchive = time.Now()
+			run, err := archiveService.Archive(ctx)
+			if err != nil {
+				log.Printf("Error archiving logs: %v", err)
+			}
+			if run != nil && len(run.Archived) > 0 {
+				log.Printf("Archived %d days of logs (%d rows), %d organizations kept under legal hold",
+					len(run.Archived), run.Rows, len(run.Held))
+			}
+		}
+	}
+}
+
+// runRetentionEnforcement purges logs past retention at startup and then
+// every interval
+func runRetentionEnforcement(ctx context.Context, retentionService *services.RetentionService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run, err := retentionService.Enforce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Error enforcing log retention: %v", err)
+		}
+		if run != nil {
+			for _, purge := range run.Purges {
+				verb := "Purged"
+				if run.DryRun {
+					verb = "Dry run: would purge"
+				}
+				log.Printf("%s %d %s rows of organization %s older than %s", verb, purge.Rows,
+					purge.Table, purge.OrganizationID, purge.Cutoff.Format(time.RFC3339))
+				if purge.Export != nil {
+					log.Printf("Exported %d %s rows of organization %s to s3://%s/%s before purging them",
+						purge.Export.RowCount, purge.Table, purge.OrganizationID, purge.Export.Bucket, purge.Export.StorageKey)
+				}
+			}
+			if len(run.Held) > 0 {
+				log.Printf("Kept the logs of %d organizations under legal hold", len(run.Held))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runSearchEmbedding embeds the catalog entries that are new or changed
+// every interval, in batches until none are left
+func runSearchEmbedding(ctx context.Context, searchService *services.SearchService, interval time.Duration, batch int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			total := 0
+			for ctx.Err() == nil {
+				embedded, err := searchService.EmbedPending(ctx, batch)
+				total += embedded
+				if err != nil {
+					log.Printf("Error embedding catalog entries: %v", err)
+					break
+				}
+				if embedded < batch {
+					break
+				}
+			}
+			if total > 0 {
+				log.Printf("Embedded %d catalog entries for search", total)
+			}
+		}
+	}
+}
+
+// runPartitionMaintenance creates upcoming log partitions and removes
+// expired ones at startup and then every interval
+func runPartitionMaintenance(ctx context.Context, partitionService *services.PartitionService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := partitionService.Maintain(ctx)
+		if err != nil {
+			log.Printf("Error maintaining log partitions: %v", err)
+		} else if len(result.Created)+len(result.Detached)+len(result.Held) > 0 {
+			log.Printf("Log partitions: %d created, %d detached, %d dropped, %d kept under legal hold",
+				len(result.Created), len(result.Detached), len(result.Dropped), len(result.Held))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runStatsRefresh refreshes the admin stats views every interval
+func runStatsRefresh(ctx context.Context, statsService *services.AdminStatsService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := statsService.Refresh(ctx); err != nil {
+				log.Printf("Error refreshing admin stats: %v", err)
+			}
+		}
+	}
+}
+
+// runCatalogReplication applies the catalog changes of the peer regions
+// every interval
+func runCatalogReplication(ctx context.Context, regionService *services.RegionService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := regionService.Sync(ctx); err != nil {
+				log.Printf("Error replicating catalog: %v", err)
+			}
+		}
+	}
+}
+
+// runTelemetry sends an anonymous usage report every interval while this
+// region is active
+func runTelemetry(ctx context.Context, reporter *telemetry.Reporter, failover *services.FailoverService) {
+	ticker := time.NewTicker(reporter.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !failover.IsActive(ctx) {
+				continue
+			}
+			if err := reporter.Send(ctx); err != nil {
+				log.Printf("Error sending telemetry report: %v", err)
+			}
+		}
+	}
+}
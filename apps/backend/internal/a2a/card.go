@@ -0,0 +1,401 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// AgentCardPath is where A2A agents publish their agent card, relative
+	// to the origin of their endpoint
+	AgentCardPath = "/.well-known/agent.json"
+	// agentCardURLConfigKey overrides where an agent's card is fetched
+	// from, for agents publishing it elsewhere
+	agentCardURLConfigKey = "agent_card_url"
+	agentCardTimeout      = 10 * time.Second
+	maxAgentCardSize      = 1 << 20
+)
+
+// CardAgentTypes are the agent types speaking the A2A protocol, and so
+// publishing an agent card
+var CardAgentTypes = []types.AgentType{types.AgentTypeCustom, types.AgentTypeGeneric}
+
+// CardFetcher fetches the raw agent card at a URL
+type CardFetcher interface {
+	FetchAgentCard(ctx context.Context, cardURL string) ([]byte, error)
+}
+
+// HasAgentCard reports whether an agent is expected to publish an agent
+// card
+func HasAgentCard(agent *types.A2AAgent) bool {
+	for _, agentType := range CardAgentTypes {
+		if agent.AgentType == agentType {
+			return true
+		}
+	}
+	return false
+}
+
+// AgentCardURL returns where an agent's card is published: the agent's
+// agent_card_url config, or /.well-known/agent.json on the origin of its
+// endpoint
+func AgentCardURL(agent *types.A2AAgent) (string, error) {
+	if override, ok := agent.ConfigData[agentCardURLConfigKey].(string); ok && override != "" {
+		return override, nil
+	}
+	endpoint, err := url.Parse(agent.EndpointURL)
+	if err != nil || endpoint.Host == "" {
+		return "", fmt.Errorf("invalid endpoint URL %q", agent.EndpointURL)
+	}
+	return endpoint.Scheme + "://" + endpoint.Host + AgentCardPath, nil
+}
+
+// FetchAgentCard fetches the raw agent card at cardURL. Cards are public,
+// so the agent's credentials are not sent, and only public hosts or hosts
+// the egress policy allow-lists are contacted.
+func (c *Client) FetchAgentCard(ctx context.Context, cardURL string) ([]byte, error) {
+	if err := c.egress.CheckURL(types.ConnectivityFeatureAgentCards, cardURL); err != nil {
+		return nil, err
+	}
+	if err := c.egress.CheckPublicURL(cardURL); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, agentCardTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cardURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MCP-Gateway-A2A/1.0")
+
+	resp, err := c.cardClient.Do(req)
+	if errors.Is(err, airgap.ErrNonPublicAddress) {
+		return nil, errors.New("agent card URL does not resolve to a public address")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAgentCardSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(body) > maxAgentCardSize {
+		return nil, fmt.Errorf("agent card is larger than %d bytes", maxAgentCardSize)
+	}
+	return body, nil
+}
+
+// CheckAgentCard fetches and validates an agent's card. When the card
+// cannot be fetched the previous check's card is kept, marked unavailable.
+func CheckAgentCard(ctx context.Context, fetcher CardFetcher, agent *types.A2AAgent, previous *types.A2AAgentCardRecord, now time.Time) *types.A2AAgentCardRecord {
+	record := &types.A2AAgentCardRecord{AgentID: agent.ID, CheckedAt: now}
+
+	var data []byte
+	cardURL, err := AgentCardURL(agent)
+	if err == nil {
+		record.URL = cardURL
+		data, err = fetcher.FetchAgentCard(ctx, cardURL)
+	}
+	if err != nil {
+		record.Status = types.A2AAgentCardStatusUnavailable
+		record.Error = err.Error()
+		if previous != nil && previous.Card != nil {
+			record.Card = previous.Card
+			record.FetchedAt = previous.FetchedAt
+			record.Problems = previous.Problems
+			record.Warnings = previous.Warnings
+		}
+		return record
+	}
+
+	record.FetchedAt = &now
+	card := &types.A2AAgentCard{}
+	if err := json.Unmarshal(data, card); err != nil {
+		record.Status = types.A2AAgentCardStatusInvalid
+		record.Problems = []string{"agent card is not valid JSON: " + err.Error()}
+		return record
+	}
+
+	record.Card = card
+	record.Problems, record.Warnings = ValidateAgentCard(card, agent)
+	record.Status = types.A2AAgentCardStatusValid
+	if len(record.Problems) > 0 {
+		record.Status = types.A2AAgentCardStatusInvalid
+	}
+	return record
+}
+
+// ValidateAgentCard returns the problems that make a card invalid, and
+// warnings where it disagrees with how the agent is registered: its
+// endpoint, its auth type or the capabilities it was registered with
+func ValidateAgentCard(card *types.A2AAgentCard, agent *types.A2AAgent) (problems, warnings []string) {
+	if card.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	if card.Version == "" {
+		problems = append(problems, "version is required")
+	}
+	cardURL, err := url.Parse(card.URL)
+	if card.URL == "" {
+		problems = append(problems, "url is required")
+	} else if err != nil || (cardURL.Scheme != "http" && cardURL.Scheme != "https") || cardURL.Host == "" {
+		problems = append(problems, fmt.Sprintf("url %q is not an absolute http(s) URL", card.URL))
+	} else if endpoint, err := url.Parse(agent.EndpointURL); err == nil && !strings.EqualFold(endpoint.Host, cardURL.Host) {
+		warnings = append(warnings, fmt.Sprintf("card url %s is not on the registered endpoint's host %s", card.URL, endpoint.Host))
+	}
+
+	skillIDs := make(map[string]bool, len(card.Skills))
+	for i, skill := range card.Skills {
+		switch {
+		case skill == nil || skill.ID == "" || skill.Name == "":
+			problems = append(problems, fmt.Sprintf("skills[%d] needs an id and a name", i))
+		case skillIDs[skill.ID]:
+			problems = append(problems, fmt.Sprintf("skill id %q is declared twice", skill.ID))
+		default:
+			skillIDs[skill.ID] = true
+		}
+	}
+
+	problems = append(problems, validateSecuritySchemes(card)...)
+	if warning := checkCardAuth(card, agent.AuthType); warning != "" {
+		warnings = append(warnings, warning)
+	}
+
+	if streaming, _ := agent.CapabilitiesData[types.CapabilityStreaming].(bool); streaming && !card.Capabilities.Streaming {
+		warnings = append(warnings, "the agent is registered with streaming, which its card does not declare")
+	}
+	return problems, warnings
+}
+
+// validateSecuritySchemes checks that each declared security scheme is
+// complete and that security requirements name declared schemes
+func validateSecuritySchemes(card *types.A2AAgentCard) []string {
+	var problems []string
+	names := make([]string, 0, len(card.SecuritySchemes))
+	for name := range card.SecuritySchemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		scheme := card.SecuritySchemes[name]
+		if scheme == nil {
+			problems = append(problems, fmt.Sprintf("securitySchemes.%s is empty", name))
+			continue
+		}
+		switch scheme.Type {
+		case types.A2ASecuritySchemeAPIKey:
+			if scheme.Name == "" || (scheme.In != "header" && scheme.In != "query" && scheme.In != "cookie") {
+				problems = append(problems, fmt.Sprintf("securitySchemes.%s needs a name and in of header, query or cookie", name))
+			}
+		case types.A2ASecuritySchemeHTTP:
+			if scheme.Scheme == "" {
+				problems = append(problems, fmt.Sprintf("securitySchemes.%s needs a scheme", name))
+			}
+		case types.A2ASecuritySchemeOAuth2:
+			if len(scheme.Flows) == 0 || string(scheme.Flows) == "null" {
+				problems = append(problems, fmt.Sprintf("securitySchemes.%s needs flows", name))
+			}
+		case types.A2ASecuritySchemeOpenIDConnect:
+			if scheme.OpenIDConnectURL == "" {
+				problems = append(problems, fmt.Sprintf("securitySchemes.%s needs an openIdConnectUrl", name))
+			}
+		case types.A2ASecuritySchemeMutualTLS:
+		default:
+			problems = append(problems, fmt.Sprintf("securitySchemes.%s has unsupported type %q", name, scheme.Type))
+		}
+	}
+
+	undeclared := make(map[string]bool)
+	for _, requirement := range card.Security {
+		for name := range requirement {
+			if _, ok := card.SecuritySchemes[name]; !ok {
+				undeclared[name] = true
+			}
+		}
+	}
+	names = names[:0]
+	for name := range undeclared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		problems = append(problems, fmt.Sprintf("security requires undeclared scheme %q", name))
+	}
+	return problems
+}
+
+// checkCardAuth returns a warning when the agent's auth type cannot satisfy
+// the authentication its card requires
+func checkCardAuth(card *types.A2AAgentCard, authType types.AuthType) string {
+	required, accepted := cardAuthTypes(card)
+	if !required {
+		return ""
+	}
+	if authType == types.AuthTypeNone || authType == "" {
+		return "the agent card requires authentication but the agent is registered without auth"
+	}
+	if accepted[authType] {
+		return ""
+	}
+
+	names := make([]string, 0, len(accepted))
+	for accepted := range accepted {
+		names = append(names, string(accepted))
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return fmt.Sprintf("the agent card requires authentication the gateway cannot provide, not auth_type %s", authType)
+	}
+	return fmt.Sprintf("the agent card does not accept auth_type %s, only %s", authType, strings.Join(names, ", "))
+}
+
+// cardAuthTypes reports whether a card requires authentication and which
+// auth types of registered agents provide it. Without security
+// requirements, any one declared scheme is taken as required.
+func cardAuthTypes(card *types.A2AAgentCard) (bool, map[types.AuthType]bool) {
+	accepted := make(map[types.AuthType]bool)
+
+	requirements := card.Security
+	if len(requirements) == 0 {
+		for name := range card.SecuritySchemes {
+			requirements = append(requirements, map[string][]string{name: nil})
+		}
+	}
+	if len(requirements) > 0 {
+		for _, requirement := range requirements {
+			if len(requirement) == 0 {
+				// An empty requirement makes authentication optional
+				return false, nil
+			}
+			// Every scheme of a requirement applies at once, which one
+			// auth type only provides when they all accept it
+			var common map[types.AuthType]bool
+			for name := range requirement {
+				authTypes := schemeAuthTypes(card.SecuritySchemes[name])
+				if common == nil {
+					common = authTypes
+					continue
+				}
+				for authType := range common {
+					if !authTypes[authType] {
+						delete(common, authType)
+					}
+				}
+			}
+			for authType := range common {
+				accepted[authType] = true
+			}
+		}
+		return true, accepted
+	}
+
+	if card.Authentication == nil || len(card.Authentication.Schemes) == 0 {
+		return false, nil
+	}
+	for _, scheme := range card.Authentication.Schemes {
+		switch strings.ToLower(scheme) {
+		case "public", "none":
+			return false, nil
+		case "bearer", "oauth2", "oauth", "openidconnect":
+			accepted[types.AuthTypeBearer] = true
+			accepted[types.AuthTypeOAuth] = true
+		case "apikey", "api_key", "api-key":
+			accepted[types.AuthTypeAPIKey] = true
+		}
+	}
+	return true, accepted
+}
+
+// schemeAuthTypes returns the auth types that authenticate with a scheme
+func schemeAuthTypes(scheme *types.A2ASecurityScheme) map[types.AuthType]bool {
+	authTypes := make(map[types.AuthType]bool)
+	if scheme == nil {
+		return authTypes
+	}
+	switch scheme.Type {
+	case types.A2ASecuritySchemeAPIKey:
+		authTypes[types.AuthTypeAPIKey] = true
+	case types.A2ASecuritySchemeHTTP:
+		if strings.EqualFold(scheme.Scheme, "bearer") {
+			authTypes[types.AuthTypeBearer] = true
+			authTypes[types.AuthTypeOAuth] = true
+		}
+	case types.A2ASecuritySchemeOAuth2, types.A2ASecuritySchemeOpenIDConnect:
+		authTypes[types.AuthTypeBearer] = true
+		authTypes[types.AuthTypeOAuth] = true
+	}
+	return authTypes
+}
+
+// RefreshCard fetches and validates an agent's card and stores the result.
+// Agents not speaking A2A have no card and get nil.
+func (s *Service) RefreshCard(ctx context.Context, agent *types.A2AAgent, fetcher CardFetcher) (*types.A2AAgentCardRecord, error) {
+	if !HasAgentCard(agent) {
+		return nil, nil
+	}
+	previous, err := s.cardModel.Get(agent.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	record := CheckAgentCard(ctx, fetcher, agent, previous, time.Now())
+	if err := s.cardModel.Upsert(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetCard returns the latest check of an agent's card, or nil when it was
+// never checked
+func (s *Service) GetCard(agentID uuid.UUID) (*types.A2AAgentCardRecord, error) {
+	return s.cardModel.Get(agentID)
+}
+
+// RefreshStaleCards refreshes up to limit cards of active agents checked
+// longer than maxAge ago and returns how many it refreshed
+func (s *Service) RefreshStaleCards(ctx context.Context, fetcher CardFetcher, maxAge time.Duration, limit int) (int, error) {
+	ids, err := s.cardModel.ListStale(CardAgentTypes, time.Now().Add(-maxAge), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	var errs []error
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return refreshed, err
+		}
+		agent, err := s.agentModel.GetByID(id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := s.RefreshCard(ctx, agent, fetcher); err != nil {
+			errs = append(errs, fmt.Errorf("failed to refresh card of agent %s: %w", id, err))
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, errors.Join(errs...)
+}
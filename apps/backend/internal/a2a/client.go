@@ -10,12 +10,23 @@ import (
 	"strings"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/pkg/requestsig"
 )
 
+// RequestSigner returns the key requests to an agent are signed with, or
+// nil when they go unsigned
+type RequestSigner interface {
+	SigningKey(ctx context.Context, destinationType, destinationID string) (*requestsig.Key, error)
+}
+
 // Client implements A2A communication with external agents
 type Client struct {
 	httpClient *http.Client
+	cardClient *http.Client
+	egress     *airgap.Policy
+	signer     RequestSigner
 	timeout    time.Duration
 	retries    int
 }
@@ -29,13 +40,28 @@ func NewClient(timeout time.Duration, retries int) *Client {
 		retries = 3
 	}
 
-	return &Client{
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
 		timeout: timeout,
 		retries: retries,
 	}
+	c.cardClient = c.egress.PublicClient(types.ConnectivityFeatureAgentCards, agentCardTimeout)
+	return c
+}
+
+// SetEgressPolicy restricts where agent cards are fetched from. Only hosts
+// it allow-lists may be inside the gateway's network.
+func (c *Client) SetEgressPolicy(policy *airgap.Policy) {
+	c.egress = policy
+	c.cardClient = policy.PublicClient(types.ConnectivityFeatureAgentCards, agentCardTimeout)
+}
+
+// SetRequestSigner makes requests carry a request signature when their
+// agent has a signing key
+func (c *Client) SetRequestSigner(signer RequestSigner) {
+	c.signer = signer
 }
 
 // Chat sends a chat request to an A2A agent
@@ -191,6 +217,14 @@ func (c *Client) makeHTTPRequestToEndpoint(agent *types.A2AAgent, endpoint strin
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	var signingKey *requestsig.Key
+	if c.signer != nil {
+		signingKey, err = c.signer.SigningKey(context.Background(), types.SigningDestinationA2AAgent, agent.ID.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get signing key: %w", err)
+		}
+	}
+
 	var lastErr error
 	for attempt := 0; attempt < c.retries; attempt++ {
 		// Create HTTP request
@@ -210,6 +244,11 @@ func (c *Client) makeHTTPRequestToEndpoint(agent *types.A2AAgent, endpoint strin
 			lastErr = fmt.Errorf("failed to set auth headers: %w", err)
 			continue
 		}
+		if signingKey != nil {
+			if err := signingKey.Sign(req, bodyBytes, time.Now()); err != nil {
+				return nil, fmt.Errorf("failed to sign request: %w", err)
+			}
+		}
 
 		// Make request
 		resp, err := c.httpClient.Do(req)
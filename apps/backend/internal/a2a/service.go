@@ -1,9 +1,12 @@
 package a2a
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log"
 	"sync"
+	"time"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
@@ -16,10 +19,22 @@ type Service struct {
 	db             *sql.DB
 	agentModel     *models.A2AAgentModel
 	agentToolModel *models.A2AAgentToolModel
+	cardModel      *models.A2AAgentCardModel
 	cache          *sync.Map // In-memory cache for performance
+	events         EventEmitter
+	failures       map[uuid.UUID]time.Time // Last failure event per agent
 	mu             sync.RWMutex
+	failuresMu     sync.Mutex
 }
 
+// EventEmitter publishes gateway events to organizations' webhooks
+type EventEmitter interface {
+	Emit(ctx context.Context, orgID, eventType string, data map[string]interface{}) error
+}
+
+// failureEventInterval throttles agent.failed events for failed calls
+const failureEventInterval = time.Minute
+
 // dbWrapper wraps *sql.DB to implement the Database interface
 type dbWrapper struct {
 	*sql.DB
@@ -32,10 +47,18 @@ func NewService(db *sql.DB) *Service {
 		db:             db,
 		agentModel:     models.NewA2AAgentModel(dbWrap),
 		agentToolModel: models.NewA2AAgentToolModel(dbWrap),
+		cardModel:      models.NewA2AAgentCardModel(dbWrap),
 		cache:          &sync.Map{},
+		failures:       make(map[uuid.UUID]time.Time),
 	}
 }
 
+// SetEvents publishes agent.failed events when agents turn unhealthy or
+// calls to them fail
+func (s *Service) SetEvents(events EventEmitter) {
+	s.events = events
+}
+
 // Create creates a new A2A agent
 func (s *Service) Create(spec *types.A2AAgentSpec) (*types.A2AAgent, error) {
 	s.mu.Lock()
@@ -311,6 +334,12 @@ func (s *Service) UpdateHealth(id uuid.UUID, status types.A2AHealthStatus, messa
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// Load the previous status so only transitions to unhealthy are published
+	var previous *types.A2AAgent
+	if s.events != nil && status == types.A2AHealthStatusUnhealthy {
+		previous, _ = s.agentModel.GetByID(id)
+	}
+
 	// Update health in database
 	if err := s.agentModel.UpdateHealth(id, status, message); err != nil {
 		return fmt.Errorf("failed to update agent health: %w", err)
@@ -320,9 +349,43 @@ func (s *Service) UpdateHealth(id uuid.UUID, status types.A2AHealthStatus, messa
 	cacheKey := fmt.Sprintf("agent:%s", id.String())
 	s.cache.Delete(cacheKey)
 
+	if previous != nil && previous.HealthStatus != types.A2AHealthStatusUnhealthy {
+		s.emitFailure(previous, "health_check", message)
+	}
+
 	return nil
 }
 
+// ReportFailure publishes an agent.failed event for a failed call to an
+// agent, at most once a minute per agent
+func (s *Service) ReportFailure(agent *types.A2AAgent, operation string, err error) {
+	if s.events == nil {
+		return
+	}
+	s.failuresMu.Lock()
+	now := time.Now()
+	if last, ok := s.failures[agent.ID]; ok && now.Sub(last) < failureEventInterval {
+		s.failuresMu.Unlock()
+		return
+	}
+	s.failures[agent.ID] = now
+	s.failuresMu.Unlock()
+
+	s.emitFailure(agent, operation, err.Error())
+}
+
+func (s *Service) emitFailure(agent *types.A2AAgent, operation, message string) {
+	err := s.events.Emit(context.Background(), agent.OrganizationID.String(), types.WebhookEventAgentFailed, map[string]interface{}{
+		"agent_id":   agent.ID.String(),
+		"agent_name": agent.Name,
+		"operation":  operation,
+		"error":      message,
+	})
+	if err != nil {
+		log.Printf("Failed to publish agent.failed event for agent %s: %v", agent.ID, err)
+	}
+}
+
 // ListActive retrieves all active A2A agents for an organization
 func (s *Service) ListActive(orgID uuid.UUID) ([]*types.A2AAgent, error) {
 	filters := map[string]interface{}{
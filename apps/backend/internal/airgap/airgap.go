@@ -0,0 +1,162 @@
+// Package airgap decides which outbound calls the gateway may make when it
+// runs without internet access, substituting local mirrors where configured.
+package airgap
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Policy is the deployment's outbound connectivity policy. A nil Policy is
+// online and allows everything.
+type Policy struct {
+	mirrors      map[string]string
+	allowedHosts []string
+	offline      bool
+}
+
+// New creates a policy. Mirrors map connectivity feature keys to local
+// replacement URLs; allowedHosts are extra hosts reachable while offline,
+// either exact names or "*.suffix" patterns.
+func New(offline bool, mirrors map[string]string, allowedHosts []string) (*Policy, error) {
+	for feature, mirror := range mirrors {
+		f, ok := types.LookupConnectivityFeature(feature)
+		if !ok {
+			return nil, fmt.Errorf("unknown feature %q in offline mirrors", feature)
+		}
+		if !f.Mirrorable {
+			return nil, fmt.Errorf("feature %q cannot use a mirror", feature)
+		}
+		if _, err := url.ParseRequestURI(mirror); err != nil {
+			return nil, fmt.Errorf("invalid mirror URL for %q: %w", feature, err)
+		}
+	}
+
+	hosts := make([]string, 0, len(allowedHosts))
+	for _, host := range allowedHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return &Policy{
+		offline:      offline,
+		mirrors:      mirrors,
+		allowedHosts: hosts,
+	}, nil
+}
+
+// Offline reports whether outbound calls are restricted
+func (p *Policy) Offline() bool {
+	return p != nil && p.offline
+}
+
+// ResolveURL returns the URL feature should call. Online it is defaultURL;
+// offline it is the configured mirror, or defaultURL when that host is
+// reachable anyway. Otherwise a connectivity error names the feature.
+func (p *Policy) ResolveURL(feature, defaultURL string) (string, error) {
+	if !p.Offline() {
+		return defaultURL, nil
+	}
+	if mirror := p.mirrors[feature]; mirror != "" {
+		return mirror, nil
+	}
+	if defaultURL != "" && p.hostAllowed(defaultURL) {
+		return defaultURL, nil
+	}
+	return "", types.NewConnectivityRequiredError(feature, "configure gateway.offline.mirrors."+feature+" to use a local mirror")
+}
+
+// CheckURL returns an error when feature may not call rawURL. Offline, only
+// private, loopback, cluster-internal and allow-listed hosts are reachable.
+func (p *Policy) CheckURL(feature, rawURL string) error {
+	if !p.Offline() || p.hostAllowed(rawURL) {
+		return nil
+	}
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	return types.NewConnectivityRequiredError(feature, fmt.Sprintf("%s is not a local host; add it to gateway.offline.allowed_hosts to allow it", host))
+}
+
+// Status lists every connectivity feature and whether it works in the
+// current mode
+func (p *Policy) Status() *types.OfflineStatus {
+	status := &types.OfflineStatus{
+		Offline:      p.Offline(),
+		AllowedHosts: []string{},
+		Features:     make([]types.ConnectivityFeatureStatus, 0, len(types.ConnectivityFeatures)),
+	}
+	if p != nil {
+		status.AllowedHosts = append(status.AllowedHosts, p.allowedHosts...)
+	}
+
+	for _, feature := range types.ConnectivityFeatures {
+		fs := types.ConnectivityFeatureStatus{ConnectivityFeature: feature, Available: true}
+		if p.Offline() {
+			switch mirror := p.mirrors[feature.Key]; {
+			case mirror != "":
+				fs.Mirror = mirror
+			case feature.Mirrorable:
+				fs.Available = false
+				fs.Reason = "Requires outbound connectivity; configure a local mirror"
+			default:
+				fs.Reason = "Only self-hosted or allow-listed endpoints can be used"
+			}
+		}
+		status.Features = append(status.Features, fs)
+	}
+	return status
+}
+
+func (p *Policy) hostAllowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	return p.trustsHost(u.Hostname()) || IsLocalHost(u.Hostname())
+}
+
+// trustsHost reports whether the operator listed host in the allowed hosts
+// or as a mirror
+func (p *Policy) trustsHost(host string) bool {
+	if p == nil {
+		return false
+	}
+	host = strings.ToLower(host)
+	for _, allowed := range p.allowedHosts {
+		if allowed == host || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return true
+		}
+	}
+	for _, mirror := range p.mirrors {
+		if m, err := url.Parse(mirror); err == nil && strings.EqualFold(m.Hostname(), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLocalHost reports whether host can only be inside the deployment's
+// network: loopback, private and link-local addresses, single-label names
+// and the .local, .internal and .svc domains
+func IsLocalHost(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || !strings.Contains(host, ".") {
+		return true
+	}
+	for _, suffix := range []string{".localhost", ".local", ".internal", ".svc"} {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package airgap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	// maxPublicRedirects bounds the redirects a public client follows
+	maxPublicRedirects = 5
+	publicDialTimeout  = 30 * time.Second
+)
+
+// ErrNonPublicAddress is returned when a public client would connect to an
+// address inside the deployment's network
+var ErrNonPublicAddress = errors.New("connections to loopback, private and link-local addresses are not allowed")
+
+// IsPublicIP reports whether ip is reachable on the internet, so not a
+// loopback, private, link-local, shared (RFC 6598), unspecified or
+// multicast address
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil && (ip4[0] == 0 || (ip4[0] == 100 && ip4[1]&0xc0 == 64)) {
+		return false
+	}
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// CheckPublicURL returns a validation error when rawURL is not an http or
+// https URL of a public host. Organizations may not point the gateway at
+// its own network, only at hosts the operator allow-listed. Names are only
+// resolved when PublicClient connects, so this catches literal addresses
+// and local names early.
+func (p *Policy) CheckPublicURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return types.NewValidationError("URL must be an http or https URL")
+	}
+	host := u.Hostname()
+	if p.trustsHost(host) {
+		return nil
+	}
+	if ip := net.ParseIP(host); (ip != nil && !IsPublicIP(ip)) || (ip == nil && IsLocalHost(host)) {
+		return types.NewValidationError(fmt.Sprintf("%s is not a public host; add it to gateway.offline.allowed_hosts to allow it", host))
+	}
+	return nil
+}
+
+// PublicClient returns an HTTP client for URLs organizations configure. It
+// only connects to public addresses, checked after DNS resolution as each
+// connection is dialed so a name cannot be rebound to an internal address.
+// Redirects must pass CheckURL for feature and CheckPublicURL too.
+// Allow-listed hosts are exempt.
+func (p *Policy) PublicClient(feature string, timeout time.Duration) *http.Client {
+	guarded := &net.Dialer{Timeout: publicDialTimeout, KeepAlive: 30 * time.Second, Control: rejectNonPublic}
+	trusted := &net.Dialer{Timeout: publicDialTimeout, KeepAlive: 30 * time.Second}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed in place of the destination, hiding it from
+	// the address check
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if host, _, err := net.SplitHostPort(address); err == nil && p.trustsHost(host) {
+			return trusted.DialContext(ctx, network, address)
+		}
+		return guarded.DialContext(ctx, network, address)
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxPublicRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxPublicRedirects)
+			}
+			if err := p.CheckURL(feature, req.URL.String()); err != nil {
+				return err
+			}
+			return p.CheckPublicURL(req.URL.String())
+		},
+	}
+}
+
+// rejectNonPublic refuses to connect to addresses that are not public
+func rejectNonPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+		return ErrNonPublicAddress
+	}
+	return nil
+}
@@ -8,18 +8,21 @@ import (
 	"strings"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 )
 
 // AuditLogger handles authentication audit logging
 type AuditLogger struct {
-	db *sql.DB
+	db    *sql.DB
+	chain *logging.AuditService
 }
 
 // NewAuditLogger creates a new audit logger
 func NewAuditLogger(db *sql.DB) *AuditLogger {
 	return &AuditLogger{
-		db: db,
+		db:    db,
+		chain: logging.NewAuditService(db),
 	}
 }
 
@@ -56,115 +59,63 @@ const (
 	ActionSuspiciousActivity = "security.suspicious_activity"
 )
 
-// LogEvent logs an audit event to the database
+// LogEvent logs an audit event to the organization's audit chain
 func (a *AuditLogger) LogEvent(event *AuditEvent) error {
-	query := `
-		INSERT INTO audit_logs (
-			organization_id, action, resource_type, resource_id,
-			actor_id, actor_ip, old_values, new_values, metadata, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
-	`
-
-	var oldValuesJSON, newValuesJSON, metadataJSON []byte
-	var err error
-
+	details := cleanAuditValues(event.Metadata)
+	if details == nil {
+		details = make(map[string]interface{})
+	}
 	if event.OldValues != nil {
-		// Clean old values
-		cleanedOldValues := make(map[string]interface{})
-		for k, v := range event.OldValues {
-			if str, ok := v.(string); ok {
-				cleanedOldValues[k] = strings.ReplaceAll(str, "\x00", "")
-			} else {
-				cleanedOldValues[k] = v
-			}
-		}
-		oldValuesJSON, err = json.Marshal(cleanedOldValues)
-		if err != nil {
-			return fmt.Errorf("failed to marshal old values: %w", err)
-		}
+		details["old_values"] = cleanAuditValues(event.OldValues)
 	}
-
 	if event.NewValues != nil {
-		// Clean new values
-		cleanedNewValues := make(map[string]interface{})
-		for k, v := range event.NewValues {
-			if str, ok := v.(string); ok {
-				cleanedNewValues[k] = strings.ReplaceAll(str, "\x00", "")
-			} else {
-				cleanedNewValues[k] = v
-			}
-		}
-		newValuesJSON, err = json.Marshal(cleanedNewValues)
-		if err != nil {
-			return fmt.Errorf("failed to marshal new values: %w", err)
-		}
+		details["new_values"] = cleanAuditValues(event.NewValues)
 	}
 
-	// Add success status and error to metadata
-	metadata := make(map[string]interface{})
-	if event.Metadata != nil {
-		// Copy metadata, cleaning any problematic values
-		for k, v := range event.Metadata {
-			// Ensure string values are UTF-8 safe
-			if str, ok := v.(string); ok {
-				// Replace any null bytes or invalid UTF-8
-				metadata[k] = strings.ReplaceAll(str, "\x00", "")
-			} else {
-				metadata[k] = v
-			}
-		}
-	}
-	metadata["success"] = event.Success
-	if event.ErrorMessage != "" {
-		// Clean error message as well
-		metadata["error"] = strings.ReplaceAll(event.ErrorMessage, "\x00", "")
-	}
-	metadata["timestamp"] = time.Now().Unix()
-
-	metadataJSON, err = json.Marshal(metadata)
-	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
+	audit := &types.AuditLog{
+		Timestamp:      time.Now(),
+		Details:        details,
+		UserID:         event.ActorID,
+		OrganizationID: event.OrganizationID,
+		Action:         event.Action,
+		Resource:       event.ResourceType,
+		ResourceID:     event.ResourceID,
+		Error:          strings.ReplaceAll(event.ErrorMessage, "\x00", ""),
+		Success:        event.Success,
 	}
-
-	// Convert net.IP to string for database storage
-	var actorIP interface{}
 	if event.ActorIP != nil {
-		actorIP = event.ActorIP.String()
+		audit.RemoteIP = event.ActorIP.String()
 	}
-
-	// Convert JSON byte slices to strings for PostgreSQL JSONB columns
-	var oldValuesStr, newValuesStr, metadataStr interface{}
-
-	if oldValuesJSON != nil {
-		oldValuesStr = string(oldValuesJSON)
+	if userAgent, ok := details["user_agent"].(string); ok {
+		audit.UserAgent = userAgent
 	}
-	if newValuesJSON != nil {
-		newValuesStr = string(newValuesJSON)
+	if event.Action == ActionSuspiciousActivity {
+		audit.Severity = types.AuditSeverityCritical
 	}
-	if metadataJSON != nil {
-		metadataStr = string(metadataJSON)
-	}
-
-	_, err = a.db.Exec(
-		query,
-		event.OrganizationID,
-		event.Action,
-		event.ResourceType,
-		event.ResourceID,
-		event.ActorID,
-		actorIP,
-		oldValuesStr,
-		newValuesStr,
-		metadataStr,
-	)
 
-	if err != nil {
+	if err := a.chain.LogAudit(audit); err != nil {
 		return fmt.Errorf("failed to insert audit log: %w", err)
 	}
 
 	return nil
 }
 
+// cleanAuditValues strips null bytes, which PostgreSQL rejects in JSONB
+func cleanAuditValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	cleaned := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if str, ok := v.(string); ok {
+			cleaned[k] = strings.ReplaceAll(str, "\x00", "")
+		} else {
+			cleaned[k] = v
+		}
+	}
+	return cleaned
+}
+
 // LogLogin logs a successful login event
 func (a *AuditLogger) LogLogin(user *types.User, clientIP net.IP, userAgent string) error {
 	return a.LogEvent(&AuditEvent{
@@ -48,6 +48,8 @@ type Claims struct {
 	OrganizationID string `json:"organization_id"`
 	Role           string `json:"role"`
 	TokenType      string `json:"token_type"` // "access" or "refresh"
+	// BreakGlassSessionID marks access tokens of an emergency session
+	BreakGlassSessionID string `json:"break_glass_session_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -78,6 +80,35 @@ func (j *JWTManager) GenerateAccessToken(user *types.User) (string, error) {
 	return tokenString, nil
 }
 
+// GenerateBreakGlassToken generates an access token for an emergency
+// session that expires with the session
+func (j *JWTManager) GenerateBreakGlassToken(user *types.User, sessionID string, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:              user.ID,
+		OrganizationID:      user.OrganizationID,
+		Role:                user.Role,
+		TokenType:           "access",
+		BreakGlassSessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "omnimesh-gateway",
+			Subject:   user.ID,
+			ID:        fmt.Sprintf("%s-break-glass-%s", user.ID, sessionID),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(j.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign break-glass token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
 // GenerateRefreshToken generates a new refresh token
 func (j *JWTManager) GenerateRefreshToken(user *types.User) (string, error) {
 	now := time.Now()
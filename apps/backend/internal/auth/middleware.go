@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -18,11 +19,40 @@ type ServiceInterface interface {
 // Ensure Service implements ServiceInterface
 var _ ServiceInterface = (*Service)(nil)
 
+// NamespaceAccessChecker decides whether a caller may use a namespace, or
+// the namespace an endpoint serves, at an access level
+type NamespaceAccessChecker interface {
+	CheckNamespace(ctx context.Context, subject *types.NamespaceSubject, namespaceID, level string) error
+	CheckEndpoint(ctx context.Context, subject *types.NamespaceSubject, endpointID, level string) error
+}
+
+// TeamRoleResolver returns the roles a user's teams grant
+type TeamRoleResolver interface {
+	TeamRoles(ctx context.Context, userID string) ([]string, error)
+}
+
+// RoleGrantResolver returns the roles a user's unexpired role grants give
+type RoleGrantResolver interface {
+	ActiveRoles(ctx context.Context, userID string) ([]string, error)
+}
+
+// BreakGlassSessions verifies emergency sessions and audits what is done
+// with them
+type BreakGlassSessions interface {
+	ActiveSession(ctx context.Context, sessionID string) (*types.BreakGlassSession, error)
+	RecordAction(ctx context.Context, session *types.BreakGlassSession, action *types.BreakGlassAction)
+}
+
 // Middleware handles authentication and authorization
 type Middleware struct {
-	jwtManager *JWTManager
-	service    ServiceInterface
-	rbac       *RBAC
+	jwtManager      *JWTManager
+	service         ServiceInterface
+	rbac            *RBAC
+	namespaceAccess NamespaceAccessChecker
+	teams           TeamRoleResolver
+	roleGrants      RoleGrantResolver
+	breakGlass      BreakGlassSessions
+	platformAdmins  map[string]bool
 }
 
 // NewMiddleware creates a new auth middleware
@@ -43,6 +73,41 @@ func NewMiddlewareWithInterface(jwtManager *JWTManager, service ServiceInterface
 	}
 }
 
+// SetPlatformAdmins lists the emails of admins allowed to manage
+// platform-wide controls such as legal holds
+func (m *Middleware) SetPlatformAdmins(emails []string) {
+	m.platformAdmins = make(map[string]bool, len(emails))
+	for _, email := range emails {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			m.platformAdmins[email] = true
+		}
+	}
+}
+
+// SetNamespaceAccess makes RequireNamespaceAccess and
+// RequireEndpointNamespaceAccess enforce namespace grants
+func (m *Middleware) SetNamespaceAccess(access NamespaceAccessChecker) {
+	m.namespaceAccess = access
+}
+
+// SetTeams makes authenticated users act with the highest of their own role
+// and the roles their teams grant
+func (m *Middleware) SetTeams(teams TeamRoleResolver) {
+	m.teams = teams
+}
+
+// SetRoleGrants makes authenticated users act with the roles their
+// unexpired role grants give when those rank above their own
+func (m *Middleware) SetRoleGrants(grants RoleGrantResolver) {
+	m.roleGrants = grants
+}
+
+// SetBreakGlass enables emergency session tokens. Without it they are
+// rejected.
+func (m *Middleware) SetBreakGlass(sessions BreakGlassSessions) {
+	m.breakGlass = sessions
+}
+
 // RequireAuth middleware that requires valid authentication
 func (m *Middleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -79,12 +144,49 @@ func (m *Middleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
+		if claims.BreakGlassSessionID != "" {
+			m.serveBreakGlass(c, user, claims.BreakGlassSessionID)
+			return
+		}
+
 		// Set user context
 		m.setUserContext(c, user)
 		c.Next()
 	}
 }
 
+// serveBreakGlass serves a request made with an emergency session token as
+// a platform admin while the session lasts, auditing the request
+func (m *Middleware) serveBreakGlass(c *gin.Context, user *types.User, sessionID string) {
+	if m.breakGlass == nil {
+		m.respondWithError(c, http.StatusUnauthorized, "Break-glass access is not enabled")
+		return
+	}
+	session, err := m.breakGlass.ActiveSession(c.Request.Context(), sessionID)
+	if err != nil {
+		m.respondWithError(c, http.StatusInternalServerError, "Failed to verify break-glass session")
+		return
+	}
+	if session == nil || session.UserID != user.ID {
+		m.respondWithError(c, http.StatusUnauthorized, "Break-glass session has ended")
+		return
+	}
+
+	elevated := *user
+	elevated.Role = types.RoleAdmin
+	m.setUserContext(c, &elevated)
+	c.Set("break_glass_session_id", session.ID)
+	c.Next()
+
+	m.breakGlass.RecordAction(c.Request.Context(), session, &types.BreakGlassAction{
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		RemoteIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Status:    c.Writer.Status(),
+	})
+}
+
 // RequireRole middleware that requires specific role
 func (m *Middleware) RequireRole(requiredRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -122,6 +224,13 @@ func (m *Middleware) RequireAPIKey() gin.HandlerFunc {
 			return
 		}
 
+		// Leaked keys are useless outside the networks and origins they are
+		// restricted to
+		if apiErr := validatedKey.CheckClient(c.ClientIP(), c.GetHeader("Origin"), c.GetHeader("Referer")); apiErr != nil {
+			c.AbortWithStatusJSON(apiErr.Status, types.ErrorResponse{Error: apiErr, Success: false})
+			return
+		}
+
 		// Get user associated with API key
 		user, err := m.service.GetUserByID(validatedKey.UserID)
 		if err != nil || !user.IsActive {
@@ -132,6 +241,19 @@ func (m *Middleware) RequireAPIKey() gin.HandlerFunc {
 		// Set user context and API key info
 		m.setUserContext(c, user)
 		c.Set("api_key", validatedKey)
+		principalType := types.PrincipalTypeAPIKey
+		if user.IsServiceAccount() {
+			principalType = types.PrincipalTypeServiceAccount
+		}
+		SetPrincipal(c, &types.Principal{
+			Type:           principalType,
+			UserID:         user.ID,
+			APIKeyID:       validatedKey.ID,
+			OrganizationID: user.OrganizationID,
+			Email:          user.Email,
+			Labels:         validatedKey.Labels,
+			Scope:          validatedKey.Scope(),
+		})
 		c.Next()
 	}
 }
@@ -155,8 +277,9 @@ func (m *Middleware) OptionalAuth() gin.HandlerFunc {
 			return
 		}
 
-		// Ensure it's an access token
-		if claims.TokenType != "access" {
+		// Ensure it's an access token; emergency session tokens are only
+		// honored where authentication is required
+		if claims.TokenType != "access" || claims.BreakGlassSessionID != "" {
 			// Wrong token type, continue without authentication
 			c.Next()
 			return
@@ -198,7 +321,46 @@ func (m *Middleware) setUserContext(c *gin.Context, user *types.User) {
 	c.Set("user", user)
 	c.Set("user_id", user.ID)
 	c.Set("organization_id", user.OrganizationID)
-	c.Set("role", user.Role)
+	c.Set("role", m.effectiveRole(c, user))
+	SetPrincipal(c, &types.Principal{
+		Type:           types.PrincipalTypeUser,
+		UserID:         user.ID,
+		OrganizationID: user.OrganizationID,
+		Email:          user.Email,
+	})
+}
+
+// effectiveRole returns the highest of the user's role, the roles their
+// teams grant and the roles of their unexpired role grants. A failed lookup
+// leaves out the roles it would have added.
+func (m *Middleware) effectiveRole(c *gin.Context, user *types.User) string {
+	role := user.Role
+	raise := func(granted []string, err error) {
+		if err != nil {
+			return
+		}
+		for _, grantedRole := range granted {
+			if m.rbac.GetRoleLevel(grantedRole) > m.rbac.GetRoleLevel(role) {
+				role = grantedRole
+			}
+		}
+	}
+	if m.teams != nil {
+		raise(m.teams.TeamRoles(c.Request.Context(), user.ID))
+	}
+	if m.roleGrants != nil {
+		raise(m.roleGrants.ActiveRoles(c.Request.Context(), user.ID))
+	}
+	return role
+}
+
+// SetPrincipal records the authenticated principal on the gin context and
+// the request context so downstream services can attribute usage
+func SetPrincipal(c *gin.Context, principal *types.Principal) {
+	c.Set("principal", principal)
+	if c.Request != nil {
+		c.Request = c.Request.WithContext(types.WithPrincipal(c.Request.Context(), principal))
+	}
 }
 
 // respondWithError sends error response
@@ -297,6 +459,58 @@ func (m *Middleware) RequireResourceAccess(resource, action string) gin.HandlerF
 	}
 }
 
+// RequireNamespaceAccess middleware that requires level access to the
+// namespace in the :id path parameter
+func (m *Middleware) RequireNamespaceAccess(level string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m.namespaceAccess == nil {
+			c.Next()
+			return
+		}
+		err := m.namespaceAccess.CheckNamespace(c.Request.Context(), namespaceSubject(c), c.Param("id"), level)
+		if err != nil {
+			m.respondWithNamespaceError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireEndpointNamespaceAccess middleware that requires level access to
+// the namespace served by the endpoint in the :id path parameter
+func (m *Middleware) RequireEndpointNamespaceAccess(level string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m.namespaceAccess == nil {
+			c.Next()
+			return
+		}
+		err := m.namespaceAccess.CheckEndpoint(c.Request.Context(), namespaceSubject(c), c.Param("id"), level)
+		if err != nil {
+			m.respondWithNamespaceError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// namespaceSubject returns the authenticated caller whose namespace access
+// is checked
+func namespaceSubject(c *gin.Context) *types.NamespaceSubject {
+	return &types.NamespaceSubject{
+		UserID:         c.GetString("user_id"),
+		Role:           c.GetString("role"),
+		OrganizationID: c.GetString("organization_id"),
+	}
+}
+
+func (m *Middleware) respondWithNamespaceError(c *gin.Context, err error) {
+	if e, ok := err.(*types.Error); ok && e.Status == http.StatusForbidden {
+		m.respondWithError(c, http.StatusForbidden, e.Message)
+		return
+	}
+	m.respondWithError(c, http.StatusInternalServerError, "Failed to check namespace access")
+}
+
 // RequireOrganizationAccess middleware for organization-level access control
 func (m *Middleware) RequireOrganizationAccess() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -362,6 +576,27 @@ func (m *Middleware) RequireAdmin() gin.HandlerFunc {
 	}
 }
 
+// RequirePlatformAdmin middleware that requires an admin listed in
+// auth.platform_admins or an open break-glass session
+func (m *Middleware) RequirePlatformAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("user")
+		user, ok := value.(*types.User)
+		if !exists || !ok || user == nil {
+			m.respondWithError(c, http.StatusUnauthorized, "User not authenticated")
+			return
+		}
+
+		platformAdmin := m.platformAdmins[strings.ToLower(user.Email)] || c.GetString("break_glass_session_id") != ""
+		if !m.rbac.IsAdmin(user.Role) || !platformAdmin {
+			m.respondWithError(c, http.StatusForbidden, "Platform admin access required")
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // RequireUser middleware that requires user role or higher
 func (m *Middleware) RequireUser() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisNonceStore remembers the nonces of signed inbound calls in Redis, so
+// that every gateway instance refuses a replay
+type RedisNonceStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisNonceStore creates a new Redis-backed nonce store
+func NewRedisNonceStore(addr, password string, db int) (*RedisNonceStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	return &RedisNonceStore{
+		client: client,
+		prefix: "inbound_nonce:",
+	}, nil
+}
+
+// Claim records a nonce until expiresAt. It reports false when the nonce
+// was already claimed in scope and has not yet expired.
+func (r *RedisNonceStore) Claim(ctx context.Context, scope, nonce string, expiresAt time.Time) (bool, error) {
+	ttl := time.Until(expiresAt)
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return r.client.SetNX(ctx, r.prefix+scope+":"+nonce, "1", ttl).Result()
+}
+
+// Close closes the Redis connection
+func (r *RedisNonceStore) Close() error {
+	return r.client.Close()
+}
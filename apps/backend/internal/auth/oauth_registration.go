@@ -0,0 +1,400 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// softwareStatementMethods are the asymmetric algorithms accepted for
+// software statements; shared-secret algorithms are never accepted
+var softwareStatementMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// RegisterDynamicClient registers a client through the RFC 7591 endpoint,
+// enforcing the organization's registration policy. A non-empty
+// initialAccessToken selects the organization it was issued for.
+func (s *OAuthService) RegisterDynamicClient(ctx context.Context, req *types.ClientRegistrationRequest, orgID, initialAccessToken string) (*types.ClientRegistrationResponse, error) {
+	if !s.config.EnableDynamicRegistration {
+		return nil, fmt.Errorf("dynamic client registration is disabled")
+	}
+
+	var iat *types.OAuthInitialAccessToken
+	if initialAccessToken != "" {
+		var err error
+		iat, err = s.lookupInitialAccessToken(ctx, initialAccessToken)
+		if err != nil {
+			return nil, err
+		}
+		orgID = iat.OrganizationID
+	}
+
+	policy, err := s.GetRegistrationPolicy(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil && policy.RequireInitialAccessToken && iat == nil {
+		return nil, types.NewRegistrationError(types.ErrorInvalidToken, "an initial access token is required to register clients")
+	}
+	if err := ValidateRegistration(policy, req); err != nil {
+		return nil, err
+	}
+
+	if iat != nil {
+		if err := s.consumeInitialAccessToken(ctx, iat.ID); err != nil {
+			return nil, err
+		}
+	}
+	return s.RegisterClient(ctx, req, orgID)
+}
+
+// ValidateRegistration checks a registration request against a policy; a nil
+// policy allows everything. Claims from a verified software statement replace
+// the matching request fields as RFC 7591 section 2.3 requires.
+func ValidateRegistration(policy *types.OAuthRegistrationPolicy, req *types.ClientRegistrationRequest) error {
+	if policy == nil {
+		return nil
+	}
+
+	if req.SoftwareStatement != "" {
+		claims, err := verifySoftwareStatement(policy, req.SoftwareStatement)
+		if err != nil {
+			return err
+		}
+		applySoftwareStatement(claims, req)
+	} else if policy.RequireSoftwareStatement {
+		return types.NewRegistrationError(types.ErrorInvalidSoftwareStatement, "a software statement is required")
+	}
+
+	if len(policy.AllowedGrantTypes) > 0 {
+		grantTypes := req.GrantTypes
+		if len(grantTypes) == 0 {
+			grantTypes = []string{types.GrantTypeClientCredentials}
+		}
+		for _, grantType := range grantTypes {
+			if !contains(policy.AllowedGrantTypes, grantType) {
+				return types.NewRegistrationError(types.ErrorInvalidClientMetadata,
+					fmt.Sprintf("grant type %q is not allowed", grantType))
+			}
+		}
+	}
+
+	if len(policy.RedirectURIPatterns) > 0 {
+		for _, uri := range req.RedirectURIs {
+			if !matchesAnyPattern(policy.RedirectURIPatterns, uri) {
+				return types.NewRegistrationError(types.ErrorInvalidRedirectURI,
+					fmt.Sprintf("redirect URI %q is not allowed", uri))
+			}
+		}
+	}
+
+	return nil
+}
+
+// ParseSoftwareStatementKey parses a PEM encoded RSA or ECDSA public key
+func ParseSoftwareStatementKey(pemKey string) (interface{}, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(pemKey)); err == nil {
+		return key, nil
+	}
+	key, err := jwt.ParseECPublicKeyFromPEM([]byte(pemKey))
+	if err != nil {
+		return nil, fmt.Errorf("expected a PEM encoded RSA or ECDSA public key")
+	}
+	return key, nil
+}
+
+func verifySoftwareStatement(policy *types.OAuthRegistrationPolicy, statement string) (jwt.MapClaims, error) {
+	keys := jwt.VerificationKeySet{}
+	for _, pemKey := range policy.SoftwareStatementKeys {
+		if key, err := ParseSoftwareStatementKey(pemKey); err == nil {
+			keys.Keys = append(keys.Keys, key)
+		}
+	}
+	if len(keys.Keys) == 0 {
+		return nil, types.NewRegistrationError(types.ErrorUnapprovedSoftwareStatement, "software statements are not accepted")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(statement, claims, func(*jwt.Token) (interface{}, error) {
+		return keys, nil
+	}, jwt.WithValidMethods(softwareStatementMethods))
+	if err != nil {
+		return nil, types.NewRegistrationError(types.ErrorInvalidSoftwareStatement, "software statement verification failed")
+	}
+
+	if len(policy.TrustedSoftwareIssuers) > 0 {
+		issuer, _ := claims.GetIssuer()
+		if !contains(policy.TrustedSoftwareIssuers, issuer) {
+			return nil, types.NewRegistrationError(types.ErrorUnapprovedSoftwareStatement,
+				fmt.Sprintf("software statement issuer %q is not trusted", issuer))
+		}
+	}
+	return claims, nil
+}
+
+func applySoftwareStatement(claims jwt.MapClaims, req *types.ClientRegistrationRequest) {
+	stringFields := map[string]*string{
+		"software_id":                &req.SoftwareID,
+		"software_version":           &req.SoftwareVersion,
+		"client_name":                &req.ClientName,
+		"client_uri":                 &req.ClientURI,
+		"logo_uri":                   &req.LogoURI,
+		"tos_uri":                    &req.TOSURI,
+		"policy_uri":                 &req.PolicyURI,
+		"jwks_uri":                   &req.JWKSURI,
+		"scope":                      &req.Scope,
+		"token_endpoint_auth_method": &req.TokenEndpointAuthMethod,
+	}
+	for claim, field := range stringFields {
+		if value, ok := claims[claim].(string); ok {
+			*field = value
+		}
+	}
+
+	listFields := map[string]*[]string{
+		"redirect_uris":  &req.RedirectURIs,
+		"grant_types":    &req.GrantTypes,
+		"response_types": &req.ResponseTypes,
+		"contacts":       &req.Contacts,
+	}
+	for claim, field := range listFields {
+		values, ok := claims[claim].([]interface{})
+		if !ok {
+			continue
+		}
+		list := make([]string, 0, len(values))
+		for _, value := range values {
+			if str, ok := value.(string); ok {
+				list = append(list, str)
+			}
+		}
+		*field = list
+	}
+}
+
+// matchesAnyPattern reports whether value matches one of the patterns. A *
+// matches within a single URI component and never crosses /, ?, # or @, so
+// https://*.example.com/cb cannot be satisfied by another host.
+func matchesAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, `[^/?#@]*`) + "$"
+		if matched, _ := regexp.MatchString(expr, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRegistrationPolicy returns an organization's registration policy, or nil
+// when none is configured
+func (s *OAuthService) GetRegistrationPolicy(ctx context.Context, orgID string) (*types.OAuthRegistrationPolicy, error) {
+	query := `
+		SELECT organization_id, require_initial_access_token, require_software_statement,
+			   allowed_grant_types, redirect_uri_patterns, trusted_software_issuers,
+			   software_statement_keys, unused_client_ttl_hours, COALESCE(updated_by, ''), updated_at
+		FROM oauth_registration_policies
+		WHERE organization_id = $1`
+
+	var policy types.OAuthRegistrationPolicy
+	err := s.db.QueryRowContext(ctx, query, orgID).Scan(
+		&policy.OrganizationID, &policy.RequireInitialAccessToken, &policy.RequireSoftwareStatement,
+		pq.Array(&policy.AllowedGrantTypes), pq.Array(&policy.RedirectURIPatterns), pq.Array(&policy.TrustedSoftwareIssuers),
+		pq.Array(&policy.SoftwareStatementKeys), &policy.UnusedClientTTLHours, &policy.UpdatedBy, &policy.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registration policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// SetRegistrationPolicy replaces an organization's registration policy
+func (s *OAuthService) SetRegistrationPolicy(ctx context.Context, orgID string, req *types.UpdateOAuthRegistrationPolicyRequest, updatedBy string) (*types.OAuthRegistrationPolicy, error) {
+	for _, grantType := range req.AllowedGrantTypes {
+		if !contains(s.config.SupportedGrantTypes, grantType) {
+			return nil, types.NewValidationError(fmt.Sprintf("unsupported grant type: %s", grantType))
+		}
+	}
+	for i, pemKey := range req.SoftwareStatementKeys {
+		if _, err := ParseSoftwareStatementKey(pemKey); err != nil {
+			return nil, types.NewValidationError(fmt.Sprintf("software_statement_keys[%d]: %v", i, err))
+		}
+	}
+	if req.RequireSoftwareStatement && len(req.SoftwareStatementKeys) == 0 {
+		return nil, types.NewValidationError("software_statement_keys are required when software statements are required")
+	}
+
+	query := `
+		INSERT INTO oauth_registration_policies (
+			organization_id, require_initial_access_token, require_software_statement,
+			allowed_grant_types, redirect_uri_patterns, trusted_software_issuers,
+			software_statement_keys, unused_client_ttl_hours, updated_by, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		ON CONFLICT (organization_id) DO UPDATE SET
+			require_initial_access_token = EXCLUDED.require_initial_access_token,
+			require_software_statement = EXCLUDED.require_software_statement,
+			allowed_grant_types = EXCLUDED.allowed_grant_types,
+			redirect_uri_patterns = EXCLUDED.redirect_uri_patterns,
+			trusted_software_issuers = EXCLUDED.trusted_software_issuers,
+			software_statement_keys = EXCLUDED.software_statement_keys,
+			unused_client_ttl_hours = EXCLUDED.unused_client_ttl_hours,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()`
+
+	_, err := s.db.ExecContext(ctx, query, orgID, req.RequireInitialAccessToken, req.RequireSoftwareStatement,
+		pq.Array(req.AllowedGrantTypes), pq.Array(req.RedirectURIPatterns), pq.Array(req.TrustedSoftwareIssuers),
+		pq.Array(req.SoftwareStatementKeys), req.UnusedClientTTLHours, updatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save registration policy: %w", err)
+	}
+	return s.GetRegistrationPolicy(ctx, orgID)
+}
+
+// CreateInitialAccessToken issues a token that authorizes client registration
+// for an organization. The token value is only returned here.
+func (s *OAuthService) CreateInitialAccessToken(ctx context.Context, orgID string, req *types.CreateInitialAccessTokenRequest, createdBy string) (*types.CreateInitialAccessTokenResponse, error) {
+	token := "iat_" + generateRandomString(48)
+	iat := &types.OAuthInitialAccessToken{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		TokenHash:      hashToken(token),
+		Description:    req.Description,
+		MaxUses:        req.MaxUses,
+		CreatedBy:      createdBy,
+		CreatedAt:      time.Now(),
+	}
+	if req.ExpiresInHours > 0 {
+		expiresAt := iat.CreatedAt.Add(time.Duration(req.ExpiresInHours) * time.Hour)
+		iat.ExpiresAt = &expiresAt
+	}
+
+	query := `
+		INSERT INTO oauth_initial_access_tokens (
+			id, organization_id, token_hash, description, max_uses, expires_at, created_by, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := s.db.ExecContext(ctx, query, iat.ID, iat.OrganizationID, iat.TokenHash, iat.Description,
+		iat.MaxUses, iat.ExpiresAt, iat.CreatedBy, iat.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create initial access token: %w", err)
+	}
+	return &types.CreateInitialAccessTokenResponse{OAuthInitialAccessToken: iat, Token: token}, nil
+}
+
+// ListInitialAccessTokens lists an organization's initial access tokens
+func (s *OAuthService) ListInitialAccessTokens(ctx context.Context, orgID string) ([]*types.OAuthInitialAccessToken, error) {
+	query := `
+		SELECT id, organization_id, COALESCE(description, ''), max_uses, use_count,
+			   expires_at, revoked_at, COALESCE(created_by, ''), created_at
+		FROM oauth_initial_access_tokens
+		WHERE organization_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list initial access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*types.OAuthInitialAccessToken{}
+	for rows.Next() {
+		var iat types.OAuthInitialAccessToken
+		if err := rows.Scan(&iat.ID, &iat.OrganizationID, &iat.Description, &iat.MaxUses, &iat.UseCount,
+			&iat.ExpiresAt, &iat.RevokedAt, &iat.CreatedBy, &iat.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan initial access token: %w", err)
+		}
+		tokens = append(tokens, &iat)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeInitialAccessToken revokes one of an organization's initial access tokens
+func (s *OAuthService) RevokeInitialAccessToken(ctx context.Context, orgID, id string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE oauth_initial_access_tokens SET revoked_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke initial access token: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return types.NewNotFoundError("Initial access token not found")
+	}
+	return nil
+}
+
+// CleanupUnusedClients deletes dynamically registered clients that have not
+// obtained a token within their organization's unused_client_ttl_hours
+func (s *OAuthService) CleanupUnusedClients(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM oauth_clients c
+		USING oauth_registration_policies p
+		WHERE c.organization_id = p.organization_id
+		  AND c.dynamically_registered
+		  AND p.unused_client_ttl_hours > 0
+		  AND COALESCE(c.last_used_at, c.created_at) < NOW() - make_interval(hours => p.unused_client_ttl_hours)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up unused clients: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (s *OAuthService) lookupInitialAccessToken(ctx context.Context, token string) (*types.OAuthInitialAccessToken, error) {
+	query := `
+		SELECT id, organization_id, max_uses, use_count, expires_at, revoked_at
+		FROM oauth_initial_access_tokens
+		WHERE token_hash = $1`
+
+	var iat types.OAuthInitialAccessToken
+	err := s.db.QueryRowContext(ctx, query, hashToken(token)).Scan(
+		&iat.ID, &iat.OrganizationID, &iat.MaxUses, &iat.UseCount, &iat.ExpiresAt, &iat.RevokedAt)
+	if err == sql.ErrNoRows {
+		return nil, types.NewRegistrationError(types.ErrorInvalidToken, "initial access token is invalid")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up initial access token: %w", err)
+	}
+
+	switch {
+	case iat.RevokedAt != nil:
+		return nil, types.NewRegistrationError(types.ErrorInvalidToken, "initial access token has been revoked")
+	case iat.ExpiresAt != nil && time.Now().After(*iat.ExpiresAt):
+		return nil, types.NewRegistrationError(types.ErrorInvalidToken, "initial access token has expired")
+	case iat.MaxUses > 0 && iat.UseCount >= iat.MaxUses:
+		return nil, types.NewRegistrationError(types.ErrorInvalidToken, "initial access token has been used up")
+	}
+	return &iat, nil
+}
+
+// consumeInitialAccessToken counts a use, failing if a concurrent
+// registration used the last one
+func (s *OAuthService) consumeInitialAccessToken(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE oauth_initial_access_tokens SET use_count = use_count + 1
+		WHERE id = $1 AND revoked_at IS NULL AND (max_uses = 0 OR use_count < max_uses)`, id)
+	if err != nil {
+		return fmt.Errorf("failed to use initial access token: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return types.NewRegistrationError(types.ErrorInvalidToken, "initial access token has been used up")
+	}
+	return nil
+}
+
+// touchClient records that a client obtained a token
+func (s *OAuthService) touchClient(ctx context.Context, clientID string) {
+	if clientID == "" {
+		return
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE oauth_clients SET last_used_at = NOW() WHERE client_id = $1`, clientID); err != nil {
+		fmt.Printf("Warning: failed to record OAuth client use: %v\n", err)
+	}
+}
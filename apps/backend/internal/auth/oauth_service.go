@@ -22,6 +22,8 @@ import (
 // OAuthService handles OAuth 2.0 operations
 type OAuthService struct {
 	db        *sqlx.DB
+	hasher    *PasswordHasher
+	keys      *KeySet
 	jwtSecret string
 	issuer    string
 	config    *OAuthConfig
@@ -80,12 +82,26 @@ func NewOAuthService(db *sqlx.DB, jwtSecret string, issuer string, config *OAuth
 
 	return &OAuthService{
 		db:        db,
+		hasher:    NewPasswordHasher(HashAlgorithmBcrypt, bcrypt.DefaultCost, Argon2Params{}),
 		jwtSecret: jwtSecret,
 		issuer:    config.Issuer,
 		config:    config,
 	}
 }
 
+// SetPasswordHasher configures how client secrets are hashed. Secrets
+// hashed otherwise are rehashed when a client next authenticates.
+func (s *OAuthService) SetPasswordHasher(hasher *PasswordHasher) {
+	s.hasher = hasher
+}
+
+// SetSigningKeys signs tokens with the set's active RS256 or ES256 key and
+// publishes its public keys at the JWKS endpoint. Without signing keys
+// tokens are signed with the JWT secret using HS256.
+func (s *OAuthService) SetSigningKeys(keys *KeySet) {
+	s.keys = keys
+}
+
 // GetServerMetadata returns OAuth 2.0 Authorization Server Metadata
 func (s *OAuthService) GetServerMetadata() *types.AuthorizationServerMetadata {
 	baseURL := strings.TrimSuffix(s.issuer, "/")
@@ -122,6 +138,20 @@ func (s *OAuthService) GetProtectedResourceMetadata() *types.ProtectedResourceMe
 	}
 }
 
+// GetEndpointProtectedResourceMetadata returns RFC 9728 metadata for the
+// endpoint resource at resourcePath. Endpoints only accept bearer tokens in
+// the Authorization header.
+func (s *OAuthService) GetEndpointProtectedResourceMetadata(resourcePath, name, documentation string) *types.ProtectedResourceMetadata {
+	metadata := s.GetProtectedResourceMetadata()
+	metadata.Resource = strings.TrimSuffix(s.issuer, "/") + resourcePath
+	metadata.BearerMethodsSupported = []string{"header"}
+	metadata.ResourceName = stringPtr(name)
+	if documentation != "" {
+		metadata.ResourceDocumentation = stringPtr(documentation)
+	}
+	return metadata
+}
+
 // RegisterClient handles dynamic client registration
 func (s *OAuthService) RegisterClient(ctx context.Context, req *types.ClientRegistrationRequest, orgID string) (*types.ClientRegistrationResponse, error) {
 	if !s.config.EnableDynamicRegistration {
@@ -144,12 +174,11 @@ func (s *OAuthService) RegisterClient(ctx context.Context, req *types.ClientRegi
 	// Generate secret for clients that need it
 	if tokenEndpointAuthMethod != types.TokenEndpointAuthNone {
 		clientSecret = generateClientSecret()
-		hash, err := bcrypt.GenerateFromPassword([]byte(clientSecret), bcrypt.DefaultCost)
+		hash, err := s.hasher.Hash(clientSecret)
 		if err != nil {
 			return nil, fmt.Errorf("failed to hash client secret: %w", err)
 		}
-		hashStr := string(hash)
-		clientSecretHash = &hashStr
+		clientSecretHash = &hash
 	}
 
 	// Set defaults
@@ -204,10 +233,11 @@ func (s *OAuthService) RegisterClient(ctx context.Context, req *types.ClientRegi
 			redirect_uris, grant_types, response_types, scope, contacts,
 			logo_uri, client_uri, policy_uri, tos_uri, jwks_uri,
 			token_endpoint_auth_method, organization_id, is_active,
-			created_at, updated_at
+			created_at, updated_at, dynamically_registered, software_id, software_version
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
-			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			true, $21, $22
 		)`
 
 	_, err := s.db.ExecContext(ctx, query,
@@ -215,7 +245,7 @@ func (s *OAuthService) RegisterClient(ctx context.Context, req *types.ClientRegi
 		pq.Array(client.RedirectURIs), pq.Array(client.GrantTypes), pq.Array(client.ResponseTypes), client.Scope, pq.Array(client.Contacts),
 		client.LogoURI, client.ClientURI, client.PolicyURI, client.TOSURI, client.JWKSURI,
 		client.TokenEndpointAuthMethod, client.OrganizationID, client.IsActive,
-		client.CreatedAt, client.UpdatedAt)
+		client.CreatedAt, client.UpdatedAt, stringPtrIfNotEmpty(req.SoftwareID), stringPtrIfNotEmpty(req.SoftwareVersion))
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert client: %w", err)
 	}
@@ -258,18 +288,69 @@ func (s *OAuthService) RegisterClient(ctx context.Context, req *types.ClientRegi
 	return response, nil
 }
 
+// CreateServiceAccountClient issues a confidential client whose
+// client_credentials tokens act as a service account. Unlike dynamically
+// registered clients it is kept until deleted.
+func (s *OAuthService) CreateServiceAccountClient(ctx context.Context, orgID, serviceAccountID, name, scope string) (*types.ClientRegistrationResponse, error) {
+	if scope == "" {
+		scope = types.ScopeRead
+	}
+	clientID := generateClientID()
+	clientSecret := generateClientSecret()
+	hash, err := s.hasher.Hash(clientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash client secret: %w", err)
+	}
+
+	now := time.Now()
+	grantTypes := []string{types.GrantTypeClientCredentials}
+	responseTypes := []string{types.ResponseTypeToken}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO oauth_clients (
+			id, client_id, client_secret_hash, client_name, client_type,
+			grant_types, response_types, scope, token_endpoint_auth_method,
+			organization_id, service_account_id, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, true, $12, $12)`,
+		uuid.New().String(), clientID, hash, name, types.ClientTypeConfidential,
+		pq.Array(grantTypes), pq.Array(responseTypes), scope, types.TokenEndpointAuthClientSecretBasic,
+		orgID, serviceAccountID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert client: %w", err)
+	}
+
+	return &types.ClientRegistrationResponse{
+		ClientID:                clientID,
+		ClientSecret:            clientSecret,
+		ClientIdIssuedAt:        now.Unix(),
+		TokenEndpointAuthMethod: types.TokenEndpointAuthClientSecretBasic,
+		GrantTypes:              grantTypes,
+		ResponseTypes:           responseTypes,
+		ClientName:              name,
+		Scope:                   scope,
+	}, nil
+}
+
 // IssueToken issues an access token based on the grant type
 func (s *OAuthService) IssueToken(ctx context.Context, req *types.TokenRequest) (*types.TokenResponse, error) {
+	var response *types.TokenResponse
+	var err error
+
 	switch req.GrantType {
 	case types.GrantTypeClientCredentials:
-		return s.handleClientCredentialsGrant(ctx, req)
+		response, err = s.handleClientCredentialsGrant(ctx, req)
 	case types.GrantTypeAuthorizationCode:
-		return s.handleAuthorizationCodeGrant(ctx, req)
+		response, err = s.handleAuthorizationCodeGrant(ctx, req)
 	case types.GrantTypeRefreshToken:
-		return s.handleRefreshTokenGrant(ctx, req)
+		response, err = s.handleRefreshTokenGrant(ctx, req)
 	default:
 		return nil, fmt.Errorf("unsupported grant type: %s", req.GrantType)
 	}
+
+	// Track use so unused dynamically registered clients can be expired
+	if err == nil {
+		s.touchClient(ctx, req.ClientID)
+	}
+	return response, err
 }
 
 // handleClientCredentialsGrant handles client_credentials grant
@@ -294,9 +375,25 @@ func (s *OAuthService) handleClientCredentialsGrant(ctx context.Context, req *ty
 		return nil, fmt.Errorf("invalid scope")
 	}
 
+	// Clients of a service account act as the account while it is enabled
+	var userID *string
+	var accountID string
+	if client.ServiceAccountID != nil {
+		var active bool
+		err := s.db.QueryRowContext(ctx, `SELECT is_active FROM users WHERE id = $1`, *client.ServiceAccountID).Scan(&active)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get service account: %w", err)
+		}
+		if !active {
+			return nil, fmt.Errorf("service account is disabled")
+		}
+		userID = client.ServiceAccountID
+		accountID = *userID
+	}
+
 	// Generate access token
 	expiresAt := time.Now().Add(s.config.TokenExpiry)
-	accessToken, err := s.generateAccessToken(client.ClientID, "", scope, expiresAt)
+	accessToken, err := s.generateAccessToken(client.ClientID, accountID, scope, expiresAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -308,7 +405,7 @@ func (s *OAuthService) handleClientCredentialsGrant(ctx context.Context, req *ty
 		TokenHash: tokenHash,
 		TokenType: types.TokenTypeAccess,
 		ClientID:  client.ClientID,
-		UserID:    nil, // No user for client credentials
+		UserID:    userID, // Only service account clients act as a user
 		Scope:     scope,
 		ExpiresAt: expiresAt,
 		CreatedAt: time.Now(),
@@ -692,17 +789,20 @@ func (s *OAuthService) ValidateToken(ctx context.Context, bearerToken string) (*
 	query := `
 		SELECT t.id, t.token_hash, t.token_type, t.client_id, t.user_id, t.scope,
 			   t.expires_at, t.revoked_at, t.parent_token_id, t.created_at,
-			   c.client_name, c.organization_id, u.email as user_email, u.role as user_role
+			   c.client_name, c.organization_id, u.email as user_email, u.role as user_role,
+			   u.account_type as user_account_type
 		FROM oauth_tokens t
 		JOIN oauth_clients c ON t.client_id = c.client_id
 		LEFT JOIN users u ON t.user_id = u.id
-		WHERE t.token_hash = $1 AND t.revoked_at IS NULL AND t.expires_at > NOW()`
+		WHERE t.token_hash = $1 AND t.revoked_at IS NULL AND t.expires_at > NOW()
+		  AND (u.id IS NULL OR u.is_active = true)`
 
 	err := s.db.QueryRowContext(ctx, query, tokenHash).Scan(
 		&tokenRecord.ID, &tokenRecord.TokenHash, &tokenRecord.TokenType, &tokenRecord.ClientID,
 		&tokenRecord.UserID, &tokenRecord.Scope, &tokenRecord.ExpiresAt, &tokenRecord.RevokedAt,
 		&tokenRecord.ParentTokenID, &tokenRecord.CreatedAt, &tokenRecord.ClientName,
-		&tokenRecord.OrganizationID, &tokenRecord.UserEmail, &tokenRecord.UserRole)
+		&tokenRecord.OrganizationID, &tokenRecord.UserEmail, &tokenRecord.UserRole,
+		&tokenRecord.UserAccountType)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("invalid or expired token")
@@ -720,7 +820,7 @@ func (s *OAuthService) GetClient(ctx context.Context, clientID string) (*types.O
 		SELECT id, client_id, client_secret_hash, client_name, client_type,
 			   redirect_uris, grant_types, response_types, scope, contacts,
 			   logo_uri, client_uri, policy_uri, tos_uri, jwks_uri,
-			   token_endpoint_auth_method, organization_id, is_active,
+			   token_endpoint_auth_method, organization_id, service_account_id, is_active,
 			   created_at, updated_at
 		FROM oauth_clients
 		WHERE client_id = $1 AND is_active = true`
@@ -729,7 +829,7 @@ func (s *OAuthService) GetClient(ctx context.Context, clientID string) (*types.O
 		&client.ID, &client.ClientID, &client.ClientSecretHash, &client.ClientName, &client.ClientType,
 		pq.Array(&client.RedirectURIs), pq.Array(&client.GrantTypes), pq.Array(&client.ResponseTypes), &client.Scope, pq.Array(&client.Contacts),
 		&client.LogoURI, &client.ClientURI, &client.PolicyURI, &client.TOSURI, &client.JWKSURI,
-		&client.TokenEndpointAuthMethod, &client.OrganizationID, &client.IsActive,
+		&client.TokenEndpointAuthMethod, &client.OrganizationID, &client.ServiceAccountID, &client.IsActive,
 		&client.CreatedAt, &client.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -767,9 +867,17 @@ func (s *OAuthService) authenticateClient(ctx context.Context, clientID, clientS
 		return nil, fmt.Errorf("client_secret required for confidential client")
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(*client.ClientSecretHash), []byte(clientSecret)); err != nil {
+	valid, rehash := s.hasher.Verify(clientSecret, *client.ClientSecretHash)
+	if !valid {
 		return nil, fmt.Errorf("invalid client credentials")
 	}
+	if rehash {
+		if hash, err := s.hasher.Hash(clientSecret); err == nil {
+			if _, err := s.db.Exec(`UPDATE oauth_clients SET client_secret_hash = $2 WHERE id = $1`, client.ID, hash); err == nil {
+				client.ClientSecretHash = &hash
+			}
+		}
+	}
 
 	return client, nil
 }
@@ -793,6 +901,15 @@ func (s *OAuthService) generateAccessToken(clientID, userID, scope string, expir
 		claims["user_id"] = userID
 	}
 
+	return s.signToken(claims)
+}
+
+// signToken signs claims with the active signing key, or with the JWT
+// secret when no signing keys are configured
+func (s *OAuthService) signToken(claims jwt.MapClaims) (string, error) {
+	if s.keys != nil {
+		return s.keys.Sign(claims)
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(s.jwtSecret))
 }
@@ -978,8 +1095,7 @@ func (s *OAuthService) generateRefreshToken(clientID, userID, scope string, expi
 		"token_use": "refresh",
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	return s.signToken(claims)
 }
 
 // verifyRefreshToken verifies and retrieves a refresh token
@@ -1095,8 +1211,12 @@ type JWK struct {
 
 // GetJWKS returns the JSON Web Key Set for token verification
 func (s *OAuthService) GetJWKS() (*JWKS, error) {
-	// For HMAC signing (HS256), we don't expose the key in JWKS
-	// This is a simplified implementation. In production, you'd want RSA keys
+	if s.keys != nil {
+		return s.keys.JWKS(), nil
+	}
+
+	// For HMAC signing (HS256), we don't expose the key in JWKS. Configure
+	// RS256 or ES256 signing keys to publish keys that verify tokens offline
 
 	// Generate a key ID based on the JWT secret (for caching/rotation purposes)
 	keyID := generateKeyID(s.jwtSecret)
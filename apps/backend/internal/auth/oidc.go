@@ -0,0 +1,406 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// oidcDiscoveryTTL is how long an issuer's metadata and keys are cached
+	oidcDiscoveryTTL = time.Hour
+
+	// oidcKeyRefreshInterval limits how often an unknown kid triggers a
+	// refetch of the issuer's keys, so forged tokens cannot hammer the IdP
+	oidcKeyRefreshInterval = time.Minute
+
+	// oidcClockSkew is the leeway allowed on ID token timestamps
+	oidcClockSkew = time.Minute
+
+	oidcMaxResponseBytes = 1 << 20
+)
+
+// OIDCProviderMetadata is the part of an issuer's discovery document the
+// gateway uses
+type OIDCProviderMetadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	TokenAuthMethods      []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+}
+
+// OIDCAuthRequest describes an authorization code request with PKCE
+type OIDCAuthRequest struct {
+	ClientID     string
+	RedirectURI  string
+	State        string
+	Nonce        string
+	CodeVerifier string
+	Scopes       []string
+}
+
+// OIDCTokenResponse is an issuer's answer to a code exchange
+type OIDCTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+}
+
+// OIDCClaims are the verified claims of an ID token
+type OIDCClaims struct {
+	jwt.MapClaims
+}
+
+// Subject returns the user's stable identifier at the issuer
+func (c OIDCClaims) Subject() string {
+	return c.String("sub")
+}
+
+// Email returns the user's email address
+func (c OIDCClaims) Email() string {
+	return c.String("email")
+}
+
+// EmailVerified reports whether the issuer verified the email address.
+// Some issuers send the flag as a string.
+func (c OIDCClaims) EmailVerified() bool {
+	switch verified := c.MapClaims["email_verified"].(type) {
+	case bool:
+		return verified
+	case string:
+		return verified == "true"
+	default:
+		return false
+	}
+}
+
+// Name returns the user's display name, falling back to the preferred
+// username
+func (c OIDCClaims) Name() string {
+	if name := c.String("name"); name != "" {
+		return name
+	}
+	return c.String("preferred_username")
+}
+
+// String returns a string claim, or empty when it is missing or not a
+// string
+func (c OIDCClaims) String(name string) string {
+	value, _ := c.MapClaims[name].(string)
+	return value
+}
+
+// Strings returns a claim holding a list of strings, such as groups. A
+// single string is returned as a list of one.
+func (c OIDCClaims) Strings(name string) []string {
+	switch value := c.MapClaims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// oidcIssuer caches an issuer's metadata and signing keys
+type oidcIssuer struct {
+	fetchedAt     time.Time
+	keysFetchedAt time.Time
+	metadata      *OIDCProviderMetadata
+	keys          []JWK
+}
+
+// OIDCClient signs users in through OpenID Connect identity providers
+// using the authorization code flow with PKCE. Issuers' discovery
+// documents and keys are cached.
+type OIDCClient struct {
+	httpClient *http.Client
+	now        func() time.Time
+	issuers    map[string]*oidcIssuer
+	mu         sync.Mutex
+}
+
+// NewOIDCClient creates an OIDC client. A nil httpClient uses one with a
+// 10 second timeout.
+func NewOIDCClient(httpClient *http.Client) *OIDCClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &OIDCClient{
+		httpClient: httpClient,
+		now:        time.Now,
+		issuers:    make(map[string]*oidcIssuer),
+	}
+}
+
+// SetClock replaces the clock ID token lifetimes are checked against
+func (c *OIDCClient) SetClock(now func() time.Time) {
+	c.now = now
+}
+
+// Discover returns an issuer's metadata, fetching its discovery document
+// unless a recent copy is cached. The document must name the issuer
+// exactly as configured.
+func (c *OIDCClient) Discover(ctx context.Context, issuer string) (*OIDCProviderMetadata, error) {
+	c.mu.Lock()
+	cached := c.issuers[issuer]
+	c.mu.Unlock()
+	if cached != nil && c.now().Sub(cached.fetchedAt) < oidcDiscoveryTTL {
+		return cached.metadata, nil
+	}
+
+	metadata := &OIDCProviderMetadata{}
+	if err := c.getJSON(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", metadata); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer %s: %w", issuer, err)
+	}
+	if metadata.Issuer != issuer {
+		return nil, fmt.Errorf("OIDC discovery document names issuer %q, expected %q", metadata.Issuer, issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery document of %s is missing endpoints", issuer)
+	}
+
+	c.mu.Lock()
+	c.issuers[issuer] = &oidcIssuer{fetchedAt: c.now(), metadata: metadata}
+	c.mu.Unlock()
+	return metadata, nil
+}
+
+// AuthCodeURL returns the issuer URL to send the user to for signing in
+func (c *OIDCClient) AuthCodeURL(ctx context.Context, issuer string, req *OIDCAuthRequest) (string, error) {
+	metadata, err := c.Discover(ctx, issuer)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", req.ClientID)
+	query.Set("redirect_uri", req.RedirectURI)
+	query.Set("scope", strings.Join(req.Scopes, " "))
+	query.Set("state", req.State)
+	query.Set("nonce", req.Nonce)
+	query.Set("code_challenge", PKCEChallenge(req.CodeVerifier))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+	return authURL.String(), nil
+}
+
+// Exchange trades an authorization code for the issuer's tokens
+func (c *OIDCClient) Exchange(ctx context.Context, issuer, clientID, clientSecret, code, redirectURI, codeVerifier string) (*OIDCTokenResponse, error) {
+	metadata, err := c.Discover(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", codeVerifier)
+
+	// client_secret_basic is the default when an issuer lists no methods
+	useBasic := len(metadata.TokenAuthMethods) == 0 || slices.Contains(metadata.TokenAuthMethods, "client_secret_basic")
+	if !useBasic {
+		form.Set("client_id", clientID)
+		form.Set("client_secret", clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasic {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, oidcMaxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			return nil, fmt.Errorf("token endpoint returned %s: %s", oauthErr.Error, oauthErr.Description)
+		}
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	tokens := &OIDCTokenResponse{}
+	if err := json.Unmarshal(body, tokens); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("token response has no ID token")
+	}
+	return tokens, nil
+}
+
+// VerifyIDToken checks an ID token's signature against the issuer's keys
+// and its issuer, audience, lifetime and nonce, returning its claims
+func (c *OIDCClient) VerifyIDToken(ctx context.Context, issuer, clientID, rawIDToken, nonce string) (*OIDCClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return c.verificationKey(ctx, issuer, token)
+	},
+		jwt.WithValidMethods([]string{SigningAlgorithmRS256, SigningAlgorithmES256}),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+		jwt.WithTimeFunc(c.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	verified := &OIDCClaims{MapClaims: claims}
+	if verified.String("nonce") != nonce {
+		return nil, fmt.Errorf("invalid ID token: nonce mismatch")
+	}
+	// A token issued to several audiences must name this client as the
+	// party it was issued to
+	audience, _ := claims.GetAudience()
+	if azp := verified.String("azp"); (azp != "" || len(audience) > 1) && azp != clientID {
+		return nil, fmt.Errorf("invalid ID token: issued to another party")
+	}
+	if verified.Subject() == "" {
+		return nil, fmt.Errorf("invalid ID token: missing subject")
+	}
+	return verified, nil
+}
+
+// verificationKey returns the issuer's key that signed token, refetching
+// the issuer's keys once when the kid is unknown, as after a rotation
+func (c *OIDCClient) verificationKey(ctx context.Context, issuer string, token *jwt.Token) (crypto.PublicKey, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	keys, err := c.keys(ctx, issuer, false)
+	if err != nil {
+		return nil, err
+	}
+	key := findOIDCKey(keys, kid)
+	if key == nil {
+		if keys, err = c.keys(ctx, issuer, true); err != nil {
+			return nil, err
+		}
+		if key = findOIDCKey(keys, kid); key == nil {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+	}
+	if key.Algorithm != "" && key.Algorithm != token.Method.Alg() {
+		return nil, fmt.Errorf("token algorithm %s does not match key %q", token.Method.Alg(), kid)
+	}
+	return key.PublicKey()
+}
+
+// findOIDCKey returns the signing key named kid, or the only signing key
+// when the token names none
+func findOIDCKey(keys []JWK, kid string) *JWK {
+	var signing []JWK
+	for _, key := range keys {
+		if key.Use == "" || key.Use == "sig" {
+			signing = append(signing, key)
+		}
+	}
+	if kid == "" && len(signing) == 1 {
+		return &signing[0]
+	}
+	for i := range signing {
+		if kid != "" && signing[i].KeyID == kid {
+			return &signing[i]
+		}
+	}
+	return nil
+}
+
+// keys returns the issuer's signing keys. refresh refetches them unless
+// they were fetched very recently.
+func (c *OIDCClient) keys(ctx context.Context, issuer string, refresh bool) ([]JWK, error) {
+	metadata, err := c.Discover(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	cached := c.issuers[issuer]
+	keys, fetchedAt := cached.keys, cached.keysFetchedAt
+	c.mu.Unlock()
+
+	age := c.now().Sub(fetchedAt)
+	if keys != nil && age < oidcDiscoveryTTL && (!refresh || age < oidcKeyRefreshInterval) {
+		return keys, nil
+	}
+
+	jwks := &JWKS{}
+	if err := c.getJSON(ctx, metadata.JWKSURI, jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys of %s: %w", issuer, err)
+	}
+
+	c.mu.Lock()
+	cached.keys, cached.keysFetchedAt = jwks.Keys, c.now()
+	c.mu.Unlock()
+	return jwks.Keys, nil
+}
+
+// getJSON fetches and decodes a JSON document
+func (c *OIDCClient) getJSON(ctx context.Context, target string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, oidcMaxResponseBytes)).Decode(v)
+}
+
+// PKCEChallenge returns the S256 code challenge of a PKCE code verifier
+func PKCEChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms. Every stored hash names its algorithm and
+// parameters, so records hashed under older settings keep verifying and are
+// rehashed the next time their secret is presented.
+const (
+	HashAlgorithmBcrypt   = "bcrypt"
+	HashAlgorithmArgon2id = "argon2id"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// Argon2Params tunes argon2id hashing
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// DefaultArgon2Params is the OWASP baseline for argon2id
+var DefaultArgon2Params = Argon2Params{Memory: 19 * 1024, Iterations: 2, Parallelism: 1}
+
+// PasswordHasher hashes passwords and client secrets with the configured
+// algorithm and verifies hashes made with any supported algorithm
+type PasswordHasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2     Argon2Params
+}
+
+// NewPasswordHasher creates a hasher for algorithm. Zero parameters fall
+// back to their defaults.
+func NewPasswordHasher(algorithm string, bcryptCost int, argon2Params Argon2Params) *PasswordHasher {
+	if algorithm == "" {
+		algorithm = HashAlgorithmBcrypt
+	}
+	if bcryptCost == 0 {
+		bcryptCost = bcrypt.DefaultCost
+	}
+	if argon2Params.Memory == 0 {
+		argon2Params.Memory = DefaultArgon2Params.Memory
+	}
+	if argon2Params.Iterations == 0 {
+		argon2Params.Iterations = DefaultArgon2Params.Iterations
+	}
+	if argon2Params.Parallelism == 0 {
+		argon2Params.Parallelism = DefaultArgon2Params.Parallelism
+	}
+	return &PasswordHasher{
+		algorithm:  algorithm,
+		bcryptCost: bcryptCost,
+		argon2:     argon2Params,
+	}
+}
+
+// Algorithm returns the algorithm new hashes are made with
+func (h *PasswordHasher) Algorithm() string {
+	return h.algorithm
+}
+
+// Hash hashes secret with the configured algorithm
+func (h *PasswordHasher) Hash(secret string) (string, error) {
+	if h.algorithm == HashAlgorithmArgon2id {
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", fmt.Errorf("failed to generate salt: %w", err)
+		}
+		key := argon2.IDKey([]byte(secret), salt, h.argon2.Iterations, h.argon2.Memory, h.argon2.Parallelism, argon2KeyLength)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+			h.argon2.Memory, h.argon2.Iterations, h.argon2.Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), h.bcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Verify reports whether secret matches encoded, and whether encoded should
+// be replaced by a fresh hash because it was made with another algorithm or
+// other parameters than the configured ones
+func (h *PasswordHasher) Verify(secret, encoded string) (bool, bool) {
+	if strings.HasPrefix(encoded, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, false
+		}
+		candidate := argon2.IDKey([]byte(secret), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(candidate, key) != 1 {
+			return false, false
+		}
+		return true, h.algorithm != HashAlgorithmArgon2id || params != h.argon2
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(encoded), []byte(secret)) != nil {
+		return false, false
+	}
+	if h.algorithm != HashAlgorithmBcrypt {
+		return true, true
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return true, err == nil && cost != h.bcryptCost
+}
+
+// VerifySecret reports whether secret matches a hash made with any
+// supported algorithm
+func VerifySecret(secret, encoded string) bool {
+	ok, _ := NewPasswordHasher("", 0, Argon2Params{}).Verify(secret, encoded)
+	return ok
+}
+
+// HashAlgorithmOf returns the algorithm a stored hash was made with, or ""
+// when it is not recognized
+func HashAlgorithmOf(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return HashAlgorithmArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return HashAlgorithmBcrypt
+	}
+	return ""
+}
+
+// decodeArgon2id parses a hash in the PHC string format
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+func decodeArgon2id(encoded string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, fmt.Errorf("malformed argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("malformed argon2id key")
+	}
+	return params, salt, key, nil
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/lib/pq"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // Service handles authentication and user management
@@ -24,6 +23,12 @@ type Service struct {
 	config         *Config
 	auditLogger    *AuditLogger
 	attemptTracker *LoginAttemptTracker
+	seats          SeatLimiter
+}
+
+// SeatLimiter rejects new users once the licensed seats are taken
+type SeatLimiter interface {
+	CheckSeatAvailable(ctx context.Context) error
 }
 
 // Config holds authentication service configuration
@@ -33,6 +38,10 @@ type Config struct {
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
 	BCryptCost         int
+	// PasswordAlgorithm is the algorithm new password hashes use; stored
+	// hashes of other algorithms are upgraded on login
+	PasswordAlgorithm string
+	Argon2            Argon2Params
 }
 
 // NewService creates a new authentication service
@@ -134,6 +143,20 @@ func (s *Service) LoginWithContext(email, password string, ctx *LoginContext) (*
 		return nil, types.NewUnauthorizedError("invalid credentials")
 	}
 
+	// Service accounts have no password and never sign in interactively
+	if user.IsServiceAccount() {
+		s.attemptTracker.RecordLoginAttempt(email, ctx.ClientIP, false)
+		s.auditLogger.LogLoginFailed(
+			email,
+			user.OrganizationID,
+			ctx.ClientIP,
+			ctx.UserAgent,
+			"service_account",
+		)
+
+		return nil, types.NewUnauthorizedError("invalid credentials")
+	}
+
 	// Check if user account is active
 	if !user.IsActive {
 		// Record failed attempt
@@ -152,7 +175,8 @@ func (s *Service) LoginWithContext(email, password string, ctx *LoginContext) (*
 	}
 
 	// Validate password
-	if !s.validatePassword(password, user.PasswordHash) {
+	valid, rehash := s.passwordHasher().Verify(password, user.PasswordHash)
+	if !valid {
 		// Record failed attempt
 		s.attemptTracker.RecordLoginAttempt(email, ctx.ClientIP, false)
 
@@ -168,6 +192,26 @@ func (s *Service) LoginWithContext(email, password string, ctx *LoginContext) (*
 		return nil, types.NewUnauthorizedError("invalid credentials")
 	}
 
+	// Move the stored hash to the configured algorithm and parameters
+	// while the password is at hand
+	if rehash {
+		s.rehashPassword(user.ID, password)
+	}
+
+	return s.IssueLogin(user, ctx)
+}
+
+// IssueLogin signs in a user who has already been authenticated, by
+// password or by an external identity provider, issuing their tokens and
+// recording the login
+func (s *Service) IssueLogin(user *types.User, ctx *LoginContext) (*types.LoginResponse, error) {
+	if ctx == nil {
+		ctx = &LoginContext{
+			ClientIP:  net.IPv4(127, 0, 0, 1),
+			UserAgent: "unknown",
+		}
+	}
+
 	// Generate tokens
 	accessToken, err := s.jwtManager.GenerateAccessToken(user)
 	if err != nil {
@@ -180,7 +224,7 @@ func (s *Service) LoginWithContext(email, password string, ctx *LoginContext) (*
 	}
 
 	// Record successful login attempt
-	s.attemptTracker.RecordLoginAttempt(email, ctx.ClientIP, true)
+	s.attemptTracker.RecordLoginAttempt(user.Email, ctx.ClientIP, true)
 
 	// Log successful login
 	err = s.auditLogger.LogLogin(user, ctx.ClientIP, ctx.UserAgent)
@@ -494,7 +538,7 @@ func (s *Service) LogoutWithContext(accessToken string, ctx *LoginContext, volun
 // GetUserByID retrieves user by ID
 func (s *Service) GetUserByID(userID string) (*types.User, error) {
 	query := `
-		SELECT id, email, name, password_hash, organization_id, role, is_active, created_at, updated_at
+		SELECT id, email, name, password_hash, organization_id, role, account_type, is_active, created_at, updated_at
 		FROM users
 		WHERE id = $1 AND is_active = true
 	`
@@ -507,6 +551,7 @@ func (s *Service) GetUserByID(userID string) (*types.User, error) {
 		&user.PasswordHash,
 		&user.OrganizationID,
 		&user.Role,
+		&user.AccountType,
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -525,7 +570,7 @@ func (s *Service) GetUserByID(userID string) (*types.User, error) {
 // GetUserByEmail retrieves user by email
 func (s *Service) GetUserByEmail(email string) (*types.User, error) {
 	query := `
-		SELECT id, email, name, password_hash, organization_id, role, is_active, created_at, updated_at
+		SELECT id, email, name, password_hash, organization_id, role, account_type, is_active, created_at, updated_at
 		FROM users
 		WHERE email = $1 AND is_active = true
 	`
@@ -538,6 +583,7 @@ func (s *Service) GetUserByEmail(email string) (*types.User, error) {
 		&user.PasswordHash,
 		&user.OrganizationID,
 		&user.Role,
+		&user.AccountType,
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -553,8 +599,19 @@ func (s *Service) GetUserByEmail(email string) (*types.User, error) {
 	return &user, nil
 }
 
-// CreateUser creates a new user
+// SetSeatLimiter enforces licensed seats when users are created
+func (s *Service) SetSeatLimiter(seats SeatLimiter) {
+	s.seats = seats
+}
+
+// CreateUser creates a new user. Service accounts do not take a seat.
 func (s *Service) CreateUser(req *types.CreateUserRequest) (*types.User, error) {
+	if s.seats != nil && req.Role != types.RoleService {
+		if err := s.seats.CheckSeatAvailable(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
 	// Hash the password
 	hashedPassword, err := s.hashPassword(req.Password)
 	if err != nil {
@@ -655,9 +712,24 @@ func (s *Service) UpdateUser(userID string, req *types.UpdateUserRequest) (*type
 	return &user, nil
 }
 
-// DeleteUser soft deletes a user
+// DeleteUser soft deletes a user by deactivating them. Their sessions and
+// API keys stop working on the next request and they can be reactivated
+// through the user admin API.
 func (s *Service) DeleteUser(userID string) error {
-	// TODO: Implement user deletion (soft delete)
+	result, err := s.db.Exec(`
+		UPDATE users SET is_active = false, deactivated_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND account_type = 'human' AND retired_at IS NULL AND deactivated_at IS NULL
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if affected == 0 {
+		return types.NewNotFoundError("user not found")
+	}
 	return nil
 }
 
@@ -669,6 +741,35 @@ func (s *Service) CreateAPIKey(userID string, req *types.CreateAPIKeyRequest) (*
 		return nil, err
 	}
 
+	if err := req.Labels.Validate(); err != nil {
+		return nil, types.NewValidationError(err.Error())
+	}
+	allowedCIDRs, err := types.NormalizeAPIKeyCIDRs(req.AllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+	allowedOrigins, err := types.NormalizeAPIKeyOrigins(req.AllowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := types.NormalizeAPIKeyScope(req.AllowedNamespaces, req.AllowedServers, req.AllowedTools)
+	if err != nil {
+		return nil, err
+	}
+
+	// Team keys can only be created by members of the team
+	var teamID sql.NullString
+	if req.TeamID != "" {
+		member, err := s.isTeamMember(req.TeamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, types.NewForbiddenError("you are not a member of this team")
+		}
+		teamID = sql.NullString{String: req.TeamID, Valid: true}
+	}
+
 	// Generate a secure random API key
 	keyString := generateAPIKey()
 	keyHash := hashAPIKey(keyString)
@@ -678,8 +779,9 @@ func (s *Service) CreateAPIKey(userID string, req *types.CreateAPIKeyRequest) (*
 	query := `
 		INSERT INTO api_keys (
 			user_id, organization_id, name, key_hash, prefix,
-			key_type, permissions, expires_at, is_active
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			key_type, permissions, expires_at, is_active, labels, team_id,
+			allowed_cidrs, allowed_origins, allowed_namespaces, allowed_servers, allowed_tools
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING id, created_at
 	`
 
@@ -707,6 +809,13 @@ func (s *Service) CreateAPIKey(userID string, req *types.CreateAPIKeyRequest) (*
 		pq.Array(permissions),
 		expiresAt,
 		true,
+		req.Labels,
+		teamID,
+		pq.Array(allowedCIDRs),
+		pq.Array(allowedOrigins),
+		pq.Array(scope.Namespaces),
+		pq.Array(scope.Servers),
+		pq.Array(scope.Tools),
 	).Scan(&apiKey.ID, &apiKey.CreatedAt)
 
 	if err != nil {
@@ -718,6 +827,13 @@ func (s *Service) CreateAPIKey(userID string, req *types.CreateAPIKeyRequest) (*
 	apiKey.KeyHash = prefix + "..." // Only show prefix in response
 	apiKey.Role = req.Role
 	apiKey.IsActive = true
+	apiKey.Labels = req.Labels
+	apiKey.TeamID = req.TeamID
+	apiKey.AllowedCIDRs = allowedCIDRs
+	apiKey.AllowedOrigins = allowedOrigins
+	apiKey.AllowedNamespaces = scope.Namespaces
+	apiKey.AllowedServers = scope.Servers
+	apiKey.AllowedTools = scope.Tools
 	if expiresAt.Valid {
 		apiKey.ExpiresAt = &expiresAt.Time
 	}
@@ -728,13 +844,16 @@ func (s *Service) CreateAPIKey(userID string, req *types.CreateAPIKeyRequest) (*
 	}, nil
 }
 
-// ListAPIKeys lists all API keys for a user
+// ListAPIKeys lists all API keys for a user, including the keys of the
+// teams they belong to
 func (s *Service) ListAPIKeys(userID string) ([]*types.APIKey, error) {
 	query := `
 		SELECT id, name, prefix || '...' as key_hash, permissions,
-		       is_active, expires_at, created_at, last_used_at
+		       is_active, expires_at, created_at, last_used_at, labels, team_id,
+		       allowed_cidrs, allowed_origins, allowed_namespaces, allowed_servers, allowed_tools
 		FROM api_keys
 		WHERE user_id = $1
+		   OR team_id IN (SELECT team_id FROM team_members WHERE user_id = $1)
 		ORDER BY created_at DESC
 	`
 
@@ -748,6 +867,7 @@ func (s *Service) ListAPIKeys(userID string) ([]*types.APIKey, error) {
 	for rows.Next() {
 		var key types.APIKey
 		var expiresAt, lastUsedAt sql.NullTime
+		var teamID sql.NullString
 		var permissions []string
 
 		err := rows.Scan(
@@ -759,6 +879,13 @@ func (s *Service) ListAPIKeys(userID string) ([]*types.APIKey, error) {
 			&expiresAt,
 			&key.CreatedAt,
 			&lastUsedAt,
+			&key.Labels,
+			&teamID,
+			pq.Array(&key.AllowedCIDRs),
+			pq.Array(&key.AllowedOrigins),
+			pq.Array(&key.AllowedNamespaces),
+			pq.Array(&key.AllowedServers),
+			pq.Array(&key.AllowedTools),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan API key: %w", err)
@@ -766,6 +893,7 @@ func (s *Service) ListAPIKeys(userID string) ([]*types.APIKey, error) {
 
 		// Map permissions back to role
 		key.Role = getRoleFromPermissions(permissions)
+		key.TeamID = teamID.String
 
 		if expiresAt.Valid {
 			key.ExpiresAt = &expiresAt.Time
@@ -785,7 +913,8 @@ func (s *Service) ListAllAPIKeys(organizationID string) ([]*types.APIKey, error)
 	query := `
 		SELECT ak.id, ak.name, ak.prefix || '...' as key_hash, ak.permissions,
 		       ak.is_active, ak.expires_at, ak.created_at, ak.last_used_at,
-		       ak.user_id, ak.organization_id, u.email as user_email
+		       ak.user_id, ak.organization_id, u.email as user_email, ak.labels, ak.team_id,
+		       ak.allowed_cidrs, ak.allowed_origins, ak.allowed_namespaces, ak.allowed_servers, ak.allowed_tools
 		FROM api_keys ak
 		LEFT JOIN users u ON ak.user_id = u.id
 		WHERE ak.organization_id = $1
@@ -803,7 +932,7 @@ func (s *Service) ListAllAPIKeys(organizationID string) ([]*types.APIKey, error)
 		var key types.APIKey
 		var expiresAt, lastUsedAt sql.NullTime
 		var permissions []string
-		var userEmail sql.NullString
+		var userEmail, teamID sql.NullString
 
 		err := rows.Scan(
 			&key.ID,
@@ -817,6 +946,13 @@ func (s *Service) ListAllAPIKeys(organizationID string) ([]*types.APIKey, error)
 			&key.UserID,
 			&key.OrganizationID,
 			&userEmail,
+			&key.Labels,
+			&teamID,
+			pq.Array(&key.AllowedCIDRs),
+			pq.Array(&key.AllowedOrigins),
+			pq.Array(&key.AllowedNamespaces),
+			pq.Array(&key.AllowedServers),
+			pq.Array(&key.AllowedTools),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan API key: %w", err)
@@ -824,6 +960,7 @@ func (s *Service) ListAllAPIKeys(organizationID string) ([]*types.APIKey, error)
 
 		// Set the role based on permissions
 		key.Role = getRoleFromPermissions(permissions)
+		key.TeamID = teamID.String
 
 		// Set optional fields
 		if expiresAt.Valid {
@@ -849,18 +986,9 @@ func (s *Service) ListAllAPIKeys(organizationID string) ([]*types.APIKey, error)
 
 // DeleteAPIKey deletes an API key
 func (s *Service) DeleteAPIKey(userID, keyID string) error {
-	// Verify the key belongs to the user
-	var ownerID string
-	err := s.db.QueryRow("SELECT user_id FROM api_keys WHERE id = $1", keyID).Scan(&ownerID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return types.NewNotFoundError("API key not found")
-		}
-		return fmt.Errorf("failed to verify API key ownership: %w", err)
-	}
-
-	if ownerID != userID {
-		return types.NewForbiddenError("you do not have permission to delete this API key")
+	// Verify the key belongs to the user or to one of their teams
+	if err := s.checkAPIKeyOwner(userID, keyID, "delete"); err != nil {
+		return err
 	}
 
 	// Delete the key
@@ -881,6 +1009,96 @@ func (s *Service) DeleteAPIKey(userID, keyID string) error {
 	return nil
 }
 
+// UpdateAPIKeyRestrictions replaces the networks and browser origins an API
+// key of the user, or of one of their teams, may be used from
+func (s *Service) UpdateAPIKeyRestrictions(userID, keyID string, req *types.UpdateAPIKeyRestrictionsRequest) (*types.APIKey, error) {
+	allowedCIDRs, err := types.NormalizeAPIKeyCIDRs(req.AllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+	allowedOrigins, err := types.NormalizeAPIKeyOrigins(req.AllowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAPIKeyOwner(userID, keyID, "update"); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE api_keys SET allowed_cidrs = $2, allowed_origins = $3, updated_at = NOW()
+		WHERE id = $1
+	`, keyID, pq.Array(allowedCIDRs), pq.Array(allowedOrigins))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update API key restrictions: %w", err)
+	}
+
+	return s.getUserAPIKey(userID, keyID)
+}
+
+// UpdateAPIKeyScope replaces the namespaces, servers and tools an API key
+// of the user, or of one of their teams, may use
+func (s *Service) UpdateAPIKeyScope(userID, keyID string, req *types.UpdateAPIKeyScopeRequest) (*types.APIKey, error) {
+	scope, err := types.NormalizeAPIKeyScope(req.AllowedNamespaces, req.AllowedServers, req.AllowedTools)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAPIKeyOwner(userID, keyID, "update"); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE api_keys
+		SET allowed_namespaces = $2, allowed_servers = $3, allowed_tools = $4, updated_at = NOW()
+		WHERE id = $1
+	`, keyID, pq.Array(scope.Namespaces), pq.Array(scope.Servers), pq.Array(scope.Tools))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update API key scope: %w", err)
+	}
+
+	return s.getUserAPIKey(userID, keyID)
+}
+
+// getUserAPIKey returns a key as ListAPIKeys shows it to the user
+func (s *Service) getUserAPIKey(userID, keyID string) (*types.APIKey, error) {
+	keys, err := s.ListAPIKeys(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.ID == keyID {
+			return key, nil
+		}
+	}
+	return nil, types.NewNotFoundError("API key not found")
+}
+
+// checkAPIKeyOwner returns an error unless the key belongs to the user or
+// to one of their teams
+func (s *Service) checkAPIKeyOwner(userID, keyID, action string) error {
+	var ownerID string
+	var teamID sql.NullString
+	err := s.db.QueryRow("SELECT user_id, team_id FROM api_keys WHERE id = $1", keyID).Scan(&ownerID, &teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.NewNotFoundError("API key not found")
+		}
+		return fmt.Errorf("failed to verify API key ownership: %w", err)
+	}
+
+	if ownerID != userID {
+		member := false
+		if teamID.Valid {
+			if member, err = s.isTeamMember(teamID.String, userID); err != nil {
+				return err
+			}
+		}
+		if !member {
+			return types.NewForbiddenError("you do not have permission to " + action + " this API key")
+		}
+	}
+	return nil
+}
+
 // DeleteAPIKeyByAdmin deletes any API key in the organization (admin only)
 func (s *Service) DeleteAPIKeyByAdmin(organizationID, keyID string) error {
 	// Verify the key belongs to the organization
@@ -921,13 +1139,15 @@ func (s *Service) ValidateAPIKey(keyString string) (*types.APIKey, error) {
 
 	query := `
 		SELECT id, user_id, organization_id, name, permissions,
-		       is_active, expires_at, created_at, last_used_at
+		       is_active, expires_at, created_at, last_used_at, labels, team_id,
+		       allowed_cidrs, allowed_origins, allowed_namespaces, allowed_servers, allowed_tools
 		FROM api_keys
 		WHERE key_hash = $1 AND is_active = true
 	`
 
 	var apiKey types.APIKey
 	var expiresAt, lastUsedAt sql.NullTime
+	var teamID sql.NullString
 	var permissions []string
 
 	err := s.db.QueryRow(query, keyHash).Scan(
@@ -940,6 +1160,13 @@ func (s *Service) ValidateAPIKey(keyString string) (*types.APIKey, error) {
 		&expiresAt,
 		&apiKey.CreatedAt,
 		&lastUsedAt,
+		&apiKey.Labels,
+		&teamID,
+		pq.Array(&apiKey.AllowedCIDRs),
+		pq.Array(&apiKey.AllowedOrigins),
+		pq.Array(&apiKey.AllowedNamespaces),
+		pq.Array(&apiKey.AllowedServers),
+		pq.Array(&apiKey.AllowedTools),
 	)
 
 	if err != nil {
@@ -961,6 +1188,7 @@ func (s *Service) ValidateAPIKey(keyString string) (*types.APIKey, error) {
 
 	// Map permissions to role
 	apiKey.Role = getRoleFromPermissions(permissions)
+	apiKey.TeamID = teamID.String
 
 	if expiresAt.Valid {
 		apiKey.ExpiresAt = &expiresAt.Time
@@ -991,19 +1219,34 @@ func (s *Service) RevokeAPIKey(keyID string) error {
 	return nil
 }
 
-// hashPassword hashes a password using bcrypt
+// passwordHasher returns the hasher for the configured algorithm
+func (s *Service) passwordHasher() *PasswordHasher {
+	return NewPasswordHasher(s.config.PasswordAlgorithm, s.config.BCryptCost, s.config.Argon2)
+}
+
+// hashPassword hashes a password with the configured algorithm
 func (s *Service) hashPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.config.BCryptCost)
-	if err != nil {
-		return "", fmt.Errorf("failed to hash password: %w", err)
-	}
-	return string(hash), nil
+	return s.passwordHasher().Hash(password)
 }
 
-// validatePassword validates a password against hash
+// validatePassword validates a password against a hash of any supported
+// algorithm
 func (s *Service) validatePassword(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	valid, _ := s.passwordHasher().Verify(password, hash)
+	return valid
+}
+
+// rehashPassword stores a fresh hash of a verified password. Failures only
+// delay the upgrade to the next login.
+func (s *Service) rehashPassword(userID, password string) {
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		fmt.Printf("Warning: failed to rehash password: %v\n", err)
+		return
+	}
+	if _, err := s.db.Exec("UPDATE users SET password_hash = $2 WHERE id = $1", userID, hash); err != nil {
+		fmt.Printf("Warning: failed to store rehashed password: %v\n", err)
+	}
 }
 
 // generateAPIKey generates a secure random API key
@@ -1026,6 +1269,19 @@ func hashAPIKey(key string) string {
 }
 
 // getPermissionsForRole maps a role to permissions
+// isTeamMember reports whether a user belongs to a team
+func (s *Service) isTeamMember(teamID, userID string) (bool, error) {
+	var member bool
+	err := s.db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2)",
+		teamID, userID,
+	).Scan(&member)
+	if err != nil {
+		return false, fmt.Errorf("failed to check team membership: %w", err)
+	}
+	return member, nil
+}
+
 func getPermissionsForRole(role string) []string {
 	switch role {
 	case "admin":
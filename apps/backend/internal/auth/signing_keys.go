@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algorithms OAuth access and refresh tokens can be signed with
+const (
+	SigningAlgorithmHS256 = "HS256"
+	SigningAlgorithmRS256 = "RS256"
+	SigningAlgorithmES256 = "ES256"
+)
+
+// minRSAKeyBits is the smallest RSA modulus accepted for signing
+const minRSAKeyBits = 2048
+
+// SigningKey is an asymmetric private key that signs OAuth tokens. Its ID
+// names it in the kid header of the tokens it signs and in the JWKS.
+type SigningKey struct {
+	private   crypto.Signer
+	ID        string
+	Algorithm string
+}
+
+// ParseSigningKey reads a PEM private key for algorithm: an RSA key of at
+// least 2048 bits for RS256, or a P-256 ECDSA key for ES256. An empty id is
+// replaced by the key's RFC 7638 thumbprint.
+func ParseSigningKey(id, algorithm string, pemData []byte) (*SigningKey, error) {
+	var signer crypto.Signer
+	var err error
+	switch algorithm {
+	case SigningAlgorithmRS256:
+		signer, err = jwt.ParseRSAPrivateKeyFromPEM(pemData)
+	case SigningAlgorithmES256:
+		signer, err = jwt.ParseECPrivateKeyFromPEM(pemData)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expected a PEM encoded %s private key: %w", algorithm, err)
+	}
+	return newSigningKey(id, algorithm, signer)
+}
+
+// GenerateSigningKey creates a new key for algorithm, identified by its
+// thumbprint
+func GenerateSigningKey(algorithm string) (*SigningKey, error) {
+	var signer crypto.Signer
+	var err error
+	switch algorithm {
+	case SigningAlgorithmRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, minRSAKeyBits)
+	case SigningAlgorithmES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return newSigningKey("", algorithm, signer)
+}
+
+// newSigningKey checks that signer suits algorithm
+func newSigningKey(id, algorithm string, signer crypto.Signer) (*SigningKey, error) {
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != SigningAlgorithmRS256 {
+			return nil, fmt.Errorf("an RSA key cannot sign %s", algorithm)
+		}
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA signing keys must have at least %d bits", minRSAKeyBits)
+		}
+	case *ecdsa.PrivateKey:
+		if algorithm != SigningAlgorithmES256 {
+			return nil, fmt.Errorf("an ECDSA key cannot sign %s", algorithm)
+		}
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ES256 signing keys must use the P-256 curve")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", signer)
+	}
+
+	k := &SigningKey{private: signer, ID: id, Algorithm: algorithm}
+	if k.ID == "" {
+		k.ID = k.PublicJWK().Thumbprint()
+	}
+	return k, nil
+}
+
+// method returns the JWT signing method of the key's algorithm
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == SigningAlgorithmES256 {
+		return jwt.SigningMethodES256
+	}
+	return jwt.SigningMethodRS256
+}
+
+// Public returns the key's public half
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.private.Public()
+}
+
+// PublicJWK describes the key's public half as a JWK
+func (k *SigningKey) PublicJWK() JWK {
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+	switch public := k.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk.KeyType = "EC"
+		jwk.Curve = "P-256"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, 32)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, 32)))
+	}
+	return jwk
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of an RSA or EC key
+func (j JWK) Thumbprint() string {
+	var canonical string
+	switch j.KeyType {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, j.E, j.N)
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, j.Curve, j.X, j.Y)
+	default:
+		return ""
+	}
+	hash := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// PublicKey returns the RSA or P-256 ECDSA public key a JWK describes
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if j.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", j.Curve)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(j.X)
+		y, errY := base64.RawURLEncoding.DecodeString(j.Y)
+		if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("invalid EC coordinates")
+		}
+		// Reject points that are not on the curve
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, fmt.Errorf("invalid EC public key: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.KeyType)
+	}
+}
+
+// KeySet holds the key new tokens are signed with, along with keys rotated
+// out of signing. All of them are published in the JWKS so that tokens
+// signed before a rotation verify until they expire.
+type KeySet struct {
+	active *SigningKey
+	keys   []*SigningKey
+}
+
+// NewKeySet signs with the key named activeID, or the first key when
+// activeID is empty
+func NewKeySet(activeID string, keys []*SigningKey) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one signing key is required")
+	}
+
+	set := &KeySet{keys: keys}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate signing key id %q", key.ID)
+		}
+		seen[key.ID] = true
+		if key.ID == activeID {
+			set.active = key
+		}
+	}
+	if activeID == "" {
+		set.active = keys[0]
+	}
+	if set.active == nil {
+		return nil, fmt.Errorf("active signing key %q is not configured", activeID)
+	}
+	return set, nil
+}
+
+// Active returns the key new tokens are signed with
+func (s *KeySet) Active() *SigningKey {
+	return s.active
+}
+
+// Sign signs claims with the active key, naming it in the kid header
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.method(), claims)
+	token.Header["kid"] = s.active.ID
+	return token.SignedString(s.active.private)
+}
+
+// JWKS publishes the public half of every key, the active key first
+func (s *KeySet) JWKS() *JWKS {
+	jwks := &JWKS{Keys: []JWK{s.active.PublicJWK()}}
+	for _, key := range s.keys {
+		if key != s.active {
+			jwks.Keys = append(jwks.Keys, key.PublicJWK())
+		}
+	}
+	return jwks
+}
+
+// Keyfunc resolves the public key that verifies a token from its kid
+// header, refusing tokens whose algorithm does not match the key's
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	for _, key := range s.keys {
+		if key.ID != kid {
+			continue
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("token algorithm %s does not match key %q", token.Method.Alg(), kid)
+		}
+		return key.Public(), nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
@@ -0,0 +1,61 @@
+// Package buildinfo describes the running gateway build and the changelog
+// shipped with it.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Build metadata, set at link time:
+//
+//	go build -ldflags "-X github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/buildinfo.Version=1.4.0 ..."
+//
+// Unset values fall back to the VCS stamp the Go toolchain embeds.
+var (
+	Version   = ""
+	GitCommit = ""
+	BuildDate = ""
+)
+
+// Get returns the metadata of the running build
+func Get() *types.BuildInfo {
+	info := &types.BuildInfo{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.GitCommit == "" {
+					info.GitCommit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				info.Dirty = setting.Value == "true"
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	if info.BuildDate != "" {
+		if t, err := time.Parse(time.RFC3339, info.BuildDate); err == nil {
+			info.BuildDate = t.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return info
+}
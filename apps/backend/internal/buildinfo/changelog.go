@@ -0,0 +1,60 @@
+package buildinfo
+
+import (
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed changelog.yaml
+var changelogYAML []byte
+
+var (
+	changelogOnce     sync.Once
+	changelogReleases []types.ChangelogRelease
+	changelogErr      error
+)
+
+// Changelog returns the release notes bundled with the build, newest first
+func Changelog() ([]types.ChangelogRelease, error) {
+	changelogOnce.Do(func() {
+		changelogReleases, changelogErr = ParseChangelog(changelogYAML)
+	})
+	return changelogReleases, changelogErr
+}
+
+// ParseChangelog parses YAML release notes, newest first
+func ParseChangelog(data []byte) ([]types.ChangelogRelease, error) {
+	var releases []types.ChangelogRelease
+	if err := yaml.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("invalid changelog: %w", err)
+	}
+	for i, release := range releases {
+		if release.Version == "" {
+			return nil, fmt.Errorf("invalid changelog: release %d has no version", i)
+		}
+		if release.Changes == nil {
+			releases[i].Changes = []types.ChangelogChange{}
+		}
+	}
+	return releases, nil
+}
+
+// ReleasesSince returns the releases newer than version. An empty or unknown
+// version returns every release, so clients that never saw a changelog get
+// the full history.
+func ReleasesSince(releases []types.ChangelogRelease, version string) []types.ChangelogRelease {
+	if version == "" {
+		return releases
+	}
+	for i, release := range releases {
+		if release.Version == version {
+			return releases[:i]
+		}
+	}
+	return releases
+}
@@ -0,0 +1,142 @@
+// Package cache keeps rendered tool, resource and prompt listings so that
+// repeated list requests do not reach Postgres. Entries live in an
+// in-memory LRU for a single gateway instance or in Redis when several
+// instances share them, and are dropped per organization whenever what
+// they list changes.
+package cache
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Kinds of listings that are cached
+const (
+	KindTools     = "tools"
+	KindResources = "resources"
+	KindPrompts   = "prompts"
+)
+
+// Kinds lists every cached kind of listing
+var Kinds = []string{KindTools, KindResources, KindPrompts}
+
+// Store holds cache entries until they expire. Get reports a missing or
+// expired entry with false.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// DeletePrefix removes every entry whose key starts with prefix and
+	// returns how many were removed
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+	Close() error
+}
+
+// Stats reports how well the cache is doing since the gateway started
+type Stats struct {
+	Backend       string `json:"backend"`
+	TTLSeconds    int64  `json:"ttl_seconds"`
+	Hits          int64  `json:"hits"`
+	Misses        int64  `json:"misses"`
+	Invalidations int64  `json:"invalidations"`
+}
+
+// ListCache caches listings per organization and kind. Store errors are
+// logged and treated as misses, so an unreachable Redis server slows
+// listings down instead of failing them.
+type ListCache struct {
+	store         Store
+	backend       string
+	ttl           time.Duration
+	hits          atomic.Int64
+	misses        atomic.Int64
+	invalidations atomic.Int64
+}
+
+// New creates a list cache keeping entries in store for ttl. backend names
+// the store in Stats.
+func New(store Store, backend string, ttl time.Duration) *ListCache {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &ListCache{store: store, backend: backend, ttl: ttl}
+}
+
+// Key returns the key of a listing of the organization. key identifies the
+// listing within its kind, such as the request path and query.
+func Key(orgID, kind, key string) string {
+	return orgID + ":" + kind + ":" + key
+}
+
+// Get returns a cached listing
+func (c *ListCache) Get(ctx context.Context, orgID, kind, key string) ([]byte, bool) {
+	value, ok, err := c.store.Get(ctx, Key(orgID, kind, key))
+	if err != nil {
+		log.Printf("Warning: list cache read failed: %v", err)
+	}
+	if !ok || err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return value, true
+}
+
+// Set caches a listing for the cache's TTL
+func (c *ListCache) Set(ctx context.Context, orgID, kind, key string, value []byte) {
+	if err := c.store.Set(ctx, Key(orgID, kind, key), value, c.ttl); err != nil {
+		log.Printf("Warning: list cache write failed: %v", err)
+	}
+}
+
+// Flush drops the organization's cached listings of kind, or of every kind
+// when kind is empty, and returns how many were dropped
+func (c *ListCache) Flush(ctx context.Context, orgID, kind string) (int, error) {
+	prefix := orgID + ":"
+	if kind != "" {
+		prefix += kind + ":"
+	}
+	removed, err := c.store.DeletePrefix(ctx, prefix)
+	if err != nil {
+		return removed, err
+	}
+	c.invalidations.Add(1)
+	return removed, nil
+}
+
+// FlushAll drops the cached listings of every organization
+func (c *ListCache) FlushAll(ctx context.Context) (int, error) {
+	removed, err := c.store.DeletePrefix(ctx, "")
+	if err != nil {
+		return removed, err
+	}
+	c.invalidations.Add(1)
+	return removed, nil
+}
+
+// InvalidateOrganization drops every cached listing of the organization
+func (c *ListCache) InvalidateOrganization(ctx context.Context, orgID string) error {
+	if strings.TrimSpace(orgID) == "" {
+		return nil
+	}
+	_, err := c.Flush(ctx, orgID, "")
+	return err
+}
+
+// Stats returns the cache's counters
+func (c *ListCache) Stats() Stats {
+	return Stats{
+		Backend:       c.backend,
+		TTLSeconds:    int64(c.ttl / time.Second),
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+	}
+}
+
+// Close releases the store
+func (c *ListCache) Close() error {
+	return c.store.Close()
+}
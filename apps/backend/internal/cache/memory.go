@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryEntry is an entry of the LRU list
+type memoryEntry struct {
+	expiresAt time.Time
+	key       string
+	value     []byte
+}
+
+// MemoryStore is an in-memory LRU store for a single gateway instance.
+// Once it holds maxEntries, the least recently used entry makes room.
+type MemoryStore struct {
+	entries    map[string]*list.Element
+	order      *list.List
+	now        func() time.Time
+	mu         sync.Mutex
+	maxEntries int
+}
+
+// NewMemoryStore creates an in-memory store of at most maxEntries entries
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &MemoryStore{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+		maxEntries: maxEntries,
+	}
+}
+
+// SetClock replaces the clock entries expire against
+func (m *MemoryStore) SetClock(now func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+}
+
+// Get returns an entry and marks it recently used
+func (m *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if !m.now().Before(entry.expiresAt) {
+		m.remove(element)
+		return nil, false, nil
+	}
+	m.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set stores an entry, evicting the least recently used one when full
+func (m *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expiresAt := m.now().Add(ttl)
+	if element, ok := m.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		m.order.MoveToFront(element)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for m.order.Len() > m.maxEntries {
+		m.remove(m.order.Back())
+	}
+	return nil
+}
+
+// DeletePrefix removes every entry whose key starts with prefix
+func (m *MemoryStore) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for key, element := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			m.remove(element)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Len returns how many entries the store holds, expired ones included
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// Close is a no-op for the memory store
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+func (m *MemoryStore) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisKeyPrefix namespaces cache entries in Redis
+	redisKeyPrefix = "listcache:"
+
+	// redisScanCount is how many keys each SCAN step looks at
+	redisScanCount = 500
+)
+
+// RedisStore keeps entries in Redis, so that every gateway instance
+// sharing the Redis server sees the same entries and invalidations
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore connects to Redis
+func NewRedisStore(addr, password string, db int) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &RedisStore{client: client}, nil
+}
+
+// Get returns an entry
+func (r *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores an entry for ttl
+func (r *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, redisKeyPrefix+key, value, ttl).Err()
+}
+
+// DeletePrefix removes every entry whose key starts with prefix
+func (r *RedisStore) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	removed := 0
+	iter := r.client.Scan(ctx, 0, redisKeyPrefix+escapeGlob(prefix)+"*", redisScanCount).Iterator()
+	var keys []string
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		n, err := r.client.Del(ctx, keys...).Result()
+		removed += int(n)
+		keys = keys[:0]
+		return err
+	}
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == redisScanCount {
+			if err := flush(); err != nil {
+				return removed, fmt.Errorf("failed to delete cache entries: %w", err)
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return removed, fmt.Errorf("failed to scan cache entries: %w", err)
+	}
+	if err := flush(); err != nil {
+		return removed, fmt.Errorf("failed to delete cache entries: %w", err)
+	}
+	return removed, nil
+}
+
+// Close closes the Redis connection
+func (r *RedisStore) Close() error {
+	return r.client.Close()
+}
+
+// escapeGlob escapes the characters SCAN MATCH treats as patterns
+func escapeGlob(s string) string {
+	var escaped []byte
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, s[i])
+	}
+	return string(escaped)
+}
@@ -0,0 +1,126 @@
+// Package clientcompat detects MCP clients from their User-Agent and
+// describes the quirks the gateway works around for each of them.
+package clientcompat
+
+import (
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Shim names reported in DetectedClient.Shims
+const (
+	ShimSessionHeaderAlias     = "session_header_alias"
+	ShimLowercaseSessionHeader = "lowercase_session_header"
+	ShimSSERetry               = "sse_retry"
+)
+
+// Shims describes the workarounds applied for a client
+type Shims struct {
+	// SSERetry is sent as the SSE retry field when a stream opens, for
+	// clients that otherwise reconnect in a tight loop
+	SSERetry time.Duration
+	// LowercaseSessionHeader writes the session header as "mcp-session-id"
+	// verbatim, for clients that look it up case-sensitively
+	LowercaseSessionHeader bool
+}
+
+// Profile matches a client family by User-Agent tokens
+type Profile struct {
+	Name   string
+	Tokens []string
+	Shims  Shims
+}
+
+// Profiles are checked in order; the first profile with a matching token
+// wins, so more specific clients must come before generic ones (Cursor is
+// built on VS Code and says so in its User-Agent).
+var Profiles = []Profile{
+	{
+		Name:   types.ClientClaudeDesktop,
+		Tokens: []string{"claude-desktop", "claude-user", "claude/"},
+		Shims:  Shims{SSERetry: 3 * time.Second},
+	},
+	{
+		Name:   types.ClientCursor,
+		Tokens: []string{"cursor/", "cursor-"},
+		Shims:  Shims{SSERetry: time.Second, LowercaseSessionHeader: true},
+	},
+	{
+		Name:   types.ClientVSCode,
+		Tokens: []string{"visual studio code", "vscode/", "vscode-"},
+	},
+	{
+		Name:   types.ClientInspector,
+		Tokens: []string{"mcp-inspector"},
+	},
+	{
+		Name:   types.ClientPythonSDK,
+		Tokens: []string{"mcp-python-sdk", "python-httpx", "python-requests"},
+	},
+	{
+		Name:   types.ClientTypeScriptSDK,
+		Tokens: []string{"mcp-typescript-sdk", "node-fetch", "undici"},
+	},
+}
+
+// Detect identifies the client behind userAgent. Every client gets the
+// session header alias; the remaining shims depend on the profile.
+func Detect(userAgent string) (*types.DetectedClient, Shims) {
+	ua := strings.ToLower(userAgent)
+	for _, profile := range Profiles {
+		matched := false
+		version := ""
+		for _, token := range profile.Tokens {
+			idx := strings.Index(ua, token)
+			if idx < 0 {
+				continue
+			}
+			matched = true
+			// Any of the profile's tokens may carry the version
+			if version = versionAfter(userAgent, idx+len(token)); version != "" {
+				break
+			}
+		}
+		if matched {
+			return &types.DetectedClient{
+				Name:    profile.Name,
+				Version: version,
+				Shims:   shimNames(profile.Shims),
+			}, profile.Shims
+		}
+	}
+
+	return &types.DetectedClient{
+		Name:  types.ClientUnknown,
+		Shims: []string{ShimSessionHeaderAlias},
+	}, Shims{}
+}
+
+// versionAfter reads a version following a product token, such as the
+// "0.45.2" in "Cursor/0.45.2"
+func versionAfter(userAgent string, pos int) string {
+	if pos > 0 && pos <= len(userAgent) && userAgent[pos-1] != '/' {
+		if pos >= len(userAgent) || userAgent[pos] != '/' {
+			return ""
+		}
+		pos++
+	}
+	end := pos
+	for end < len(userAgent) && strings.IndexByte(" ;()", userAgent[end]) < 0 {
+		end++
+	}
+	return userAgent[pos:end]
+}
+
+func shimNames(shims Shims) []string {
+	names := []string{ShimSessionHeaderAlias}
+	if shims.LowercaseSessionHeader {
+		names = append(names, ShimLowercaseSessionHeader)
+	}
+	if shims.SSERetry > 0 {
+		names = append(names, ShimSSERetry)
+	}
+	return names
+}
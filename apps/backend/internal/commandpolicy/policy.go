@@ -0,0 +1,230 @@
+// Package commandpolicy enforces the deployment-level allowlist of
+// executables and banned argument patterns for STDIO MCP servers.
+package commandpolicy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Rejection rules reported in violations and audit events
+const (
+	RuleCommandNotAllowed = "command_not_allowed"
+	RuleRelativePath      = "relative_path"
+	RuleDeniedArgument    = "denied_argument"
+)
+
+// Enforcement stages reported in audit events
+const (
+	StageRegistration = "registration"
+	StageLaunch       = "launch"
+)
+
+// Auditor records policy rejections; *logging.Service satisfies it
+type Auditor interface {
+	LogAudit(ctx context.Context, event *types.AuditLog) error
+}
+
+// Subject identifies who asked for a command and which server it belongs to
+type Subject struct {
+	OrganizationID string
+	UserID         string
+	ServerID       string
+	ServerName     string
+	Stage          string
+}
+
+// Violation describes why a command was rejected
+type Violation struct {
+	Command  string
+	Rule     string
+	Pattern  string
+	Reason   string
+	ArgIndex int
+}
+
+// Error implements the error interface
+func (v *Violation) Error() string {
+	return v.Reason
+}
+
+// Policy checks STDIO commands against the configured allow and deny lists.
+// A nil *Policy allows everything.
+type Policy struct {
+	auditor     Auditor
+	allowed     []string
+	deniedArgs  []*regexp.Regexp
+	deniedExprs []string
+}
+
+// New compiles a policy from configuration
+func New(cfg types.STDIOCommandPolicy) (*Policy, error) {
+	p := &Policy{}
+
+	for _, pattern := range cfg.AllowedCommands {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if strings.ContainsRune(pattern, '/') && !filepath.IsAbs(pattern) {
+			return nil, fmt.Errorf("allowed command %q must be a bare name or an absolute path", pattern)
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid allowed command pattern %q: %w", pattern, err)
+		}
+		p.allowed = append(p.allowed, pattern)
+	}
+
+	for _, expr := range cfg.DeniedArgPatterns {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid denied argument pattern %q: %w", expr, err)
+		}
+		p.deniedArgs = append(p.deniedArgs, re)
+		p.deniedExprs = append(p.deniedExprs, expr)
+	}
+
+	return p, nil
+}
+
+// SetAuditor configures where rejections are recorded
+func (p *Policy) SetAuditor(auditor Auditor) {
+	if p != nil {
+		p.auditor = auditor
+	}
+}
+
+// Restricted reports whether an executable allowlist is in force
+func (p *Policy) Restricted() bool {
+	return p != nil && len(p.allowed) > 0
+}
+
+// Check returns a *Violation if the command or any of its arguments is not
+// permitted, or nil otherwise.
+func (p *Policy) Check(command string, args []string) error {
+	if p == nil {
+		return nil
+	}
+
+	if v := p.checkCommand(command); v != nil {
+		return v
+	}
+
+	for i, arg := range args {
+		for j, re := range p.deniedArgs {
+			if re.MatchString(arg) {
+				return &Violation{
+					Command:  command,
+					Rule:     RuleDeniedArgument,
+					Pattern:  p.deniedExprs[j],
+					Reason:   fmt.Sprintf("argument %d matches denied pattern %q", i, p.deniedExprs[j]),
+					ArgIndex: i,
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkCommand matches the executable against the allowlist. Bare-name
+// patterns only match bare commands, which are also resolved through PATH so
+// absolute-path patterns can cover them. Absolute commands only match
+// absolute-path patterns, so an allowed name cannot be spoofed from another
+// directory.
+func (p *Policy) checkCommand(command string) *Violation {
+	if len(p.allowed) == 0 {
+		return nil
+	}
+
+	bare := !strings.ContainsRune(command, '/')
+	if !bare && !filepath.IsAbs(command) {
+		return &Violation{
+			Command:  command,
+			Rule:     RuleRelativePath,
+			Reason:   fmt.Sprintf("command %q must be a bare name or an absolute path", command),
+			ArgIndex: -1,
+		}
+	}
+
+	var resolved string
+	if bare {
+		if path, err := exec.LookPath(command); err == nil && filepath.IsAbs(path) {
+			resolved = filepath.Clean(path)
+		}
+	} else {
+		resolved = filepath.Clean(command)
+	}
+
+	for _, pattern := range p.allowed {
+		target := resolved
+		if !strings.ContainsRune(pattern, '/') {
+			if !bare {
+				continue
+			}
+			target = command
+		}
+		if target == "" {
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, target); ok {
+			return nil
+		}
+	}
+
+	return &Violation{
+		Command:  command,
+		Rule:     RuleCommandNotAllowed,
+		Reason:   fmt.Sprintf("command %q is not in the allowed command list", command),
+		ArgIndex: -1,
+	}
+}
+
+// Enforce checks the command and, on rejection, records an audit event and
+// returns a policy violation error suitable for API responses.
+func (p *Policy) Enforce(ctx context.Context, subject Subject, command string, args []string) error {
+	err := p.Check(command, args)
+	if err == nil {
+		return nil
+	}
+
+	v := err.(*Violation)
+	log.Printf("Rejected STDIO command %q at %s for server %s: %s", command, subject.Stage, subject.ServerName, v.Reason)
+
+	if p.auditor != nil {
+		details := map[string]interface{}{
+			"stage":       subject.Stage,
+			"command":     command,
+			"rule":        v.Rule,
+			"server_name": subject.ServerName,
+		}
+		// Argument values are left out as they may carry credentials
+		if v.Rule == RuleDeniedArgument {
+			details["arg_index"] = v.ArgIndex
+			details["pattern"] = v.Pattern
+		}
+
+		if auditErr := p.auditor.LogAudit(ctx, &types.AuditLog{
+			OrganizationID: subject.OrganizationID,
+			UserID:         subject.UserID,
+			Action:         "reject-command",
+			Resource:       "stdio_command",
+			ResourceID:     subject.ServerID,
+			Details:        details,
+			Error:          v.Reason,
+		}); auditErr != nil {
+			log.Printf("Failed to audit STDIO command rejection: %v", auditErr)
+		}
+	}
+
+	return types.NewErrorWithDetails(types.ErrCodePolicyViolation,
+		"STDIO command rejected by deployment policy", v.Reason, http.StatusForbidden)
+}
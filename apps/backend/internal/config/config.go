@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/commandpolicy"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/objectstore"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"gopkg.in/yaml.v3"
@@ -14,16 +16,22 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Redis     RedisConfig     `yaml:"redis"`
-	Filters   FiltersConfig   `yaml:"filters"`
-	Auth      AuthConfig      `yaml:"auth"`
-	Database  DatabaseConfig  `yaml:"database"`
-	Server    ServerConfig    `yaml:"server"`
-	RateLimit RateLimitConfig `yaml:"rate_limit"`
-	Logging   LoggingConfig   `yaml:"logging"`
-	Gateway   GatewayConfig   `yaml:"gateway"`
-	Transport TransportConfig `yaml:"transport"`
-	Discovery DiscoveryConfig `yaml:"discovery"`
+	Redis         RedisConfig         `yaml:"redis"`
+	Filters       FiltersConfig       `yaml:"filters"`
+	Auth          AuthConfig          `yaml:"auth"`
+	Database      DatabaseConfig      `yaml:"database"`
+	Server        ServerConfig        `yaml:"server"`
+	RateLimit     RateLimitConfig     `yaml:"rate_limit"`
+	Logging       LoggingConfig       `yaml:"logging"`
+	Observability ObservabilityConfig `yaml:"observability"`
+	Gateway       GatewayConfig       `yaml:"gateway"`
+	Transport     TransportConfig     `yaml:"transport"`
+	Discovery     DiscoveryConfig     `yaml:"discovery"`
+	Search        SearchConfig        `yaml:"search"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	License       LicenseConfig       `yaml:"license"`
+	Telemetry     TelemetryConfig     `yaml:"telemetry"`
+	Region        RegionConfig        `yaml:"region"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -35,6 +43,14 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `yaml:"read_timeout"`
 	WriteTimeout time.Duration `yaml:"write_timeout"`
 	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// TrustedProxies lists the addresses and CIDR ranges of the load
+	// balancers and proxies whose forwarding headers are believed. Requests
+	// from anywhere else are attributed to their connection's address.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// ClientIPHeaders are the headers, in order, trusted proxies put the
+	// client address in. X-Forwarded-For is walked from the right, skipping
+	// trusted proxies, so clients cannot prepend addresses of their own.
+	ClientIPHeaders []string `yaml:"client_ip_headers"`
 }
 
 // GetBaseURL returns the base URL for the server, generating it if not explicitly set
@@ -80,6 +96,25 @@ type DatabaseConfig struct {
 	MaxOpenConns int           `yaml:"max_open_conns"`
 	MaxIdleConns int           `yaml:"max_idle_conns"`
 	MaxLifetime  time.Duration `yaml:"max_lifetime"`
+
+	// Partitioning controls upkeep of the monthly log table partitions
+	Partitioning PartitioningConfig `yaml:"partitioning"`
+}
+
+// PartitioningConfig holds monthly log table partition maintenance settings
+type PartitioningConfig struct {
+	// Interval is how often the worker creates upcoming partitions and
+	// removes expired ones; zero disables maintenance
+	Interval time.Duration `yaml:"interval"`
+	// Premake is how many months of partitions are created ahead of the
+	// current one
+	Premake int `yaml:"premake"`
+	// HealthCheckRetention is how long health check partitions are kept;
+	// zero keeps them forever
+	HealthCheckRetention time.Duration `yaml:"health_check_retention"`
+	// DetachOnly leaves expired partitions as standalone tables instead of
+	// dropping them
+	DetachOnly bool `yaml:"detach_only"`
 }
 
 // AuthConfig holds authentication configuration
@@ -87,25 +122,135 @@ type AuthConfig struct {
 	JWTSecret          string        `yaml:"jwt_secret" env:"JWT_SECRET"`
 	AccessTokenExpiry  time.Duration `yaml:"access_token_expiry"`
 	RefreshTokenExpiry time.Duration `yaml:"refresh_token_expiry"`
-	BCryptCost         int           `yaml:"bcrypt_cost"`
+	// OAuthClientCleanupInterval is how often the worker removes unused
+	// dynamically registered OAuth clients; zero disables cleanup
+	OAuthClientCleanupInterval time.Duration `yaml:"oauth_client_cleanup_interval"`
+	BCryptCost                 int           `yaml:"bcrypt_cost"`
+	// PlatformAdmins are the emails of admins who manage platform-wide
+	// controls such as legal holds
+	PlatformAdmins []string `yaml:"platform_admins"`
+	// BreakGlassWindow is how long an emergency session opened with a
+	// break-glass credential lasts before it is revoked
+	BreakGlassWindow time.Duration `yaml:"break_glass_window"`
+	// InvitationTTL and PasswordResetTTL are how long links emailed to
+	// invited users and to users resetting their password stay valid
+	InvitationTTL    time.Duration `yaml:"invitation_ttl"`
+	PasswordResetTTL time.Duration `yaml:"password_reset_ttl"`
+	// ThreatFeedSecret signs reports of leaked credentials pushed by a
+	// threat-intel feed; the feed hook is disabled while it is empty
+	ThreatFeedSecret string `yaml:"threat_feed_secret" env:"THREAT_FEED_SECRET"`
+	// PasswordHashing selects how passwords and client secrets are hashed
+	PasswordHashing PasswordHashingConfig `yaml:"password_hashing"`
+	// ReplayProtection rejects replays of signed inbound calls such as
+	// threat feed reports
+	ReplayProtection ReplayProtectionConfig `yaml:"replay_protection"`
+	// TokenSigning selects how OAuth access and refresh tokens are signed
+	TokenSigning TokenSigningConfig `yaml:"token_signing"`
+	// SSO configures sign-in through organizations' OIDC identity providers
+	SSO SSOConfig `yaml:"sso"`
+}
+
+// SSOConfig configures single sign-on. Identity providers themselves are
+// configured per organization through the admin API.
+type SSOConfig struct {
+	// RedirectURL is the frontend page users land on after signing in at
+	// their identity provider, with the outcome in the URL fragment; it
+	// defaults to /sign-in/sso on the server's base URL
+	RedirectURL string `yaml:"redirect_url"`
+	// StateTTL is how long a user has to sign in at the identity provider
+	StateTTL time.Duration `yaml:"state_ttl"`
+}
+
+// GetRedirectURL returns where signed-in users are sent
+func (s *SSOConfig) GetRedirectURL(baseURL string) string {
+	if s.RedirectURL != "" {
+		return s.RedirectURL
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/sign-in/sso"
+}
+
+// TokenSigningConfig selects how the gateway signs OAuth access and refresh
+// tokens. HS256 signs them with the JWT secret. RS256 and ES256 sign them
+// with the active key and publish the public half of every listed key at
+// /oauth/jwks, so MCP servers can verify gateway-issued tokens offline. To
+// rotate, add a key and make it active, then remove the old key once the
+// tokens it signed have expired.
+type TokenSigningConfig struct {
+	Algorithm string `yaml:"algorithm"`
+	// ActiveKeyID names the key new tokens are signed with; the first key
+	// is used when it is empty
+	ActiveKeyID string             `yaml:"active_key_id"`
+	Keys        []SigningKeyConfig `yaml:"keys"`
+}
+
+// SigningKeyConfig is a PEM private key given inline or in a file
+type SigningKeyConfig struct {
+	ID             string `yaml:"id"`
+	PrivateKey     string `yaml:"private_key"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+}
+
+// LoadPrivateKey returns the key PEM, reading PrivateKeyFile when
+// PrivateKey is unset
+func (k *SigningKeyConfig) LoadPrivateKey() (string, error) {
+	return inlineOrFile(k.PrivateKey, k.PrivateKeyFile)
+}
+
+// PasswordHashingConfig selects the algorithm for new password hashes.
+// Hashes made with another algorithm or other parameters are rehashed the
+// next time their secret is verified.
+type PasswordHashingConfig struct {
+	Algorithm string       `yaml:"algorithm"`
+	Argon2    Argon2Config `yaml:"argon2"`
+}
+
+// ReplayProtectionConfig tunes how signed inbound calls are checked for
+// replay. Nonces are remembered until their timestamp falls outside the
+// clock skew, in the database or, with Store "redis", in Redis so that
+// every gateway instance shares them.
+type ReplayProtectionConfig struct {
+	Store     string        `yaml:"store"`
+	ClockSkew time.Duration `yaml:"clock_skew"`
+	// AllowUnstamped accepts signed calls that carry no timestamp and
+	// nonce, without replay protection; by default they are refused
+	AllowUnstamped bool `yaml:"allow_unstamped"`
+}
+
+// Argon2Config tunes argon2id hashing
+type Argon2Config struct {
+	Memory      uint32 `yaml:"memory"` // KiB
+	Iterations  uint32 `yaml:"iterations"`
+	Parallelism uint8  `yaml:"parallelism"`
 }
 
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
-	Config         map[string]interface{} `yaml:"config"`
-	Retention      *RetentionConfig       `yaml:"retention,omitempty"`
-	Format         string                 `yaml:"format"`
-	Backend        string                 `yaml:"backend" env:"LOG_BACKEND"`
-	Environment    string                 `yaml:"environment" env:"ENVIRONMENT"`
-	Level          string                 `yaml:"level" env:"LOG_LEVEL"`
-	BufferSize     int                    `yaml:"buffer_size"`
-	BatchSize      int                    `yaml:"batch_size"`
-	FlushInterval  time.Duration          `yaml:"flush_interval"`
-	RetentionDays  int                    `yaml:"retention_days"`
-	Async          bool                   `yaml:"async"`
-	RequestLogging bool                   `yaml:"request_logging"`
-	AuditLogging   bool                   `yaml:"audit_logging"`
-	MetricsEnabled bool                   `yaml:"metrics_enabled"`
+	Config              map[string]interface{} `yaml:"config"`
+	Retention           *RetentionConfig       `yaml:"retention,omitempty"`
+	Exports             LogExportConfig        `yaml:"exports"`
+	Sampling            LogSamplingConfig      `yaml:"sampling"`
+	Format              string                 `yaml:"format"`
+	Backend             string                 `yaml:"backend" env:"LOG_BACKEND"`
+	Environment         string                 `yaml:"environment" env:"ENVIRONMENT"`
+	Level               string                 `yaml:"level" env:"LOG_LEVEL"`
+	OverflowPolicy      string                 `yaml:"overflow_policy"`
+	BufferSize          int                    `yaml:"buffer_size"`
+	BatchSize           int                    `yaml:"batch_size"`
+	FlushInterval       time.Duration          `yaml:"flush_interval"`
+	OverflowTimeout     time.Duration          `yaml:"overflow_timeout"`
+	AuditAnchorInterval time.Duration          `yaml:"audit_anchor_interval"`
+	AuditExportInterval time.Duration          `yaml:"audit_export_interval"`
+	RetentionDays       int                    `yaml:"retention_days"`
+	Async               bool                   `yaml:"async"`
+	RequestLogging      bool                   `yaml:"request_logging"`
+	AuditLogging        bool                   `yaml:"audit_logging"`
+	MetricsEnabled      bool                   `yaml:"metrics_enabled"`
+
+	// Archive moves old execution logs and session events to cold storage
+	Archive LogArchiveConfig `yaml:"archive"`
+
+	// RetentionEnforcement purges logs past each organization's retention
+	RetentionEnforcement RetentionEnforcementConfig `yaml:"retention_enforcement"`
 }
 
 // RetentionConfig defines log retention policies
@@ -115,6 +260,414 @@ type RetentionConfig struct {
 	KeepCount int    `yaml:"keep_count"`
 }
 
+// LogSamplingConfig thins out request logs of successful requests under
+// load. Failed requests are always logged.
+type LogSamplingConfig struct {
+	// SuccessRate is the fraction of successful requests logged, rounded to
+	// one in N; 0 or 1 logs them all until TargetPerSecond is exceeded
+	SuccessRate float64 `yaml:"success_rate"`
+	// TargetPerSecond lowers the rate further while more successful
+	// requests than this arrive per second; 0 keeps the rate fixed
+	TargetPerSecond int  `yaml:"target_per_second"`
+	Enabled         bool `yaml:"enabled" env:"LOG_SAMPLING_ENABLED"`
+}
+
+// LogExportConfig configures bulk CSV and Parquet exports of execution logs
+type LogExportConfig struct {
+	// Plans overrides the export limits of organization plans
+	Plans map[string]LogExportPlanConfig `yaml:"plans"`
+	// StorageDir is where the worker writes export files
+	StorageDir string `yaml:"storage_dir"`
+	// LinkTTL is how long signed download links stay valid
+	LinkTTL time.Duration `yaml:"link_ttl"`
+	// Retention is how long export files are kept before they expire
+	Retention time.Duration `yaml:"retention"`
+	// PollInterval is how often the worker looks for pending exports; zero
+	// disables export processing
+	PollInterval time.Duration `yaml:"poll_interval"`
+}
+
+// LogExportPlanConfig holds the export limits of one plan
+type LogExportPlanConfig struct {
+	MaxRows      int   `yaml:"max_rows"`
+	MaxBytes     int64 `yaml:"max_bytes"`
+	MaxRangeDays int   `yaml:"max_range_days"`
+}
+
+// defaultLogExportLimits are the export limits of each plan
+var defaultLogExportLimits = map[string]types.LogExportLimits{
+	"free":       {MaxRows: 100000, MaxBytes: 50 << 20, MaxRangeDays: 7},
+	"pro":        {MaxRows: 1000000, MaxBytes: 500 << 20, MaxRangeDays: 31},
+	"enterprise": {MaxRows: 10000000, MaxBytes: 5 << 30, MaxRangeDays: 90},
+}
+
+// PlanLimits returns the export limits of every plan, filling in defaults
+// for unset values. Unknown plans get the free plan's limits.
+func (c LogExportConfig) PlanLimits() map[string]types.LogExportLimits {
+	limits := make(map[string]types.LogExportLimits, len(defaultLogExportLimits))
+	for plan, defaults := range defaultLogExportLimits {
+		limits[plan] = defaults
+	}
+	for plan, override := range c.Plans {
+		planLimits, ok := limits[plan]
+		if !ok {
+			planLimits = defaultLogExportLimits["free"]
+		}
+		if override.MaxRows > 0 {
+			planLimits.MaxRows = override.MaxRows
+		}
+		if override.MaxBytes > 0 {
+			planLimits.MaxBytes = override.MaxBytes
+		}
+		if override.MaxRangeDays > 0 {
+			planLimits.MaxRangeDays = override.MaxRangeDays
+		}
+		limits[plan] = planLimits
+	}
+	return limits
+}
+
+// GetStorageDir returns the export storage directory, defaulting to
+// exports/ under the working directory
+func (c LogExportConfig) GetStorageDir() string {
+	if c.StorageDir == "" {
+		return "exports"
+	}
+	return c.StorageDir
+}
+
+// GetLinkTTL returns how long download links stay valid, 1 hour by default
+func (c LogExportConfig) GetLinkTTL() time.Duration {
+	if c.LinkTTL <= 0 {
+		return time.Hour
+	}
+	return c.LinkTTL
+}
+
+// GetRetention returns how long export files are kept, 7 days by default
+func (c LogExportConfig) GetRetention() time.Duration {
+	if c.Retention <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return c.Retention
+}
+
+// LogArchiveConfig configures archival of old execution logs and session
+// events to cold storage, and their temporary rehydration
+type LogArchiveConfig struct {
+	// Storage is where archives are kept: file (the default) or s3
+	Storage string `yaml:"storage"`
+	// StorageDir is where file storage keeps archives
+	StorageDir string `yaml:"storage_dir"`
+	// S3 locates the bucket of s3 storage
+	S3 ArchiveS3Config `yaml:"s3"`
+	// Interval is how often the worker archives logs past the organizations'
+	// archive policies and processes rehydrations; zero disables archival
+	Interval time.Duration `yaml:"interval"`
+	// RehydrationTTL is how long rehydrated logs stay when the request does
+	// not say, 24 hours by default
+	RehydrationTTL time.Duration `yaml:"rehydration_ttl"`
+}
+
+// ArchiveS3Config locates an S3 or S3-compatible bucket
+type ArchiveS3Config struct {
+	// Endpoint is the service URL, AWS S3 of the region when empty
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	Bucket          string `yaml:"bucket"`
+	Prefix          string `yaml:"prefix"`
+	AccessKeyID     string `yaml:"access_key_id" env:"ARCHIVE_S3_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secret_access_key" env:"ARCHIVE_S3_SECRET_ACCESS_KEY"`
+	UsePathStyle    bool   `yaml:"use_path_style"`
+}
+
+// GetStorageDir returns where file storage keeps archives, "archives" by
+// default
+func (c LogArchiveConfig) GetStorageDir() string {
+	if c.StorageDir == "" {
+		return "archives"
+	}
+	return c.StorageDir
+}
+
+// S3Config returns the S3 location of archives
+func (c LogArchiveConfig) S3Config() objectstore.S3Config {
+	return objectstore.S3Config{
+		Endpoint:        c.S3.Endpoint,
+		Region:          c.S3.Region,
+		Bucket:          c.S3.Bucket,
+		Prefix:          c.S3.Prefix,
+		AccessKeyID:     c.S3.AccessKeyID,
+		SecretAccessKey: c.S3.SecretAccessKey,
+		UsePathStyle:    c.S3.UsePathStyle,
+	}
+}
+
+// GetRehydrationTTL returns how long rehydrated logs stay by default, 24
+// hours unless configured
+func (c LogArchiveConfig) GetRehydrationTTL() time.Duration {
+	if c.RehydrationTTL <= 0 {
+		return 24 * time.Hour
+	}
+	return c.RehydrationTTL
+}
+
+// RetentionEnforcementConfig configures the worker job that deletes
+// execution logs, audit records and health checks older than each
+// organization's log_retention_days
+type RetentionEnforcementConfig struct {
+	// Interval is how often the job runs; zero disables it
+	Interval time.Duration `yaml:"interval"`
+	// BatchSize is how many rows one delete removes, 1000 by default
+	BatchSize int `yaml:"batch_size"`
+	// DryRun only counts and reports the rows that would be deleted
+	DryRun bool `yaml:"dry_run"`
+}
+
+// ObservabilityConfig holds external metrics export configuration
+type ObservabilityConfig struct {
+	StatsD StatsDConfig `yaml:"statsd"`
+
+	// Prometheus serves the gateway metrics for scraping
+	Prometheus PrometheusConfig `yaml:"prometheus"`
+
+	// Tracing exports OpenTelemetry spans of requests and upstream calls
+	Tracing TracingConfig `yaml:"tracing"`
+
+	// Stats controls the views behind the admin stats endpoint
+	Stats AdminStatsConfig `yaml:"stats"`
+}
+
+// AdminStatsConfig holds admin stats view refresh settings
+type AdminStatsConfig struct {
+	// RefreshInterval is how often the worker refreshes the stats views;
+	// zero disables refreshing
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+	// StaleAfter is how old a view may get before the API reports it
+	// stale, three refresh intervals when unset
+	StaleAfter time.Duration `yaml:"stale_after"`
+}
+
+// GetStaleAfter returns how old a stats view may get before it is stale
+func (a *AdminStatsConfig) GetStaleAfter() time.Duration {
+	if a.StaleAfter > 0 {
+		return a.StaleAfter
+	}
+	return 3 * a.RefreshInterval
+}
+
+// SearchConfig holds catalog search settings
+type SearchConfig struct {
+	// Embeddings enables semantic search over an embeddings API
+	Embeddings SearchEmbeddingsConfig `yaml:"embeddings"`
+}
+
+// SearchEmbeddingsConfig configures the OpenAI-compatible embeddings API the
+// worker embeds tools, prompts and resources with
+type SearchEmbeddingsConfig struct {
+	Endpoint string `yaml:"endpoint" env:"SEARCH_EMBEDDINGS_ENDPOINT"`
+	Model    string `yaml:"model" env:"SEARCH_EMBEDDINGS_MODEL"`
+	APIKey   string `yaml:"api_key" env:"SEARCH_EMBEDDINGS_API_KEY"`
+	// Interval is how often the worker embeds new and changed entries
+	Interval time.Duration `yaml:"interval"`
+	// BatchSize bounds the entries embedded per API call
+	BatchSize int  `yaml:"batch_size"`
+	Enabled   bool `yaml:"enabled" env:"SEARCH_EMBEDDINGS_ENABLED"`
+}
+
+// GetInterval returns how often entries are embedded, defaulting to 5 minutes
+func (s *SearchEmbeddingsConfig) GetInterval() time.Duration {
+	if s.Interval <= 0 {
+		return 5 * time.Minute
+	}
+	return s.Interval
+}
+
+// GetBatchSize returns the entries embedded per API call, defaulting to 64
+func (s *SearchEmbeddingsConfig) GetBatchSize() int {
+	if s.BatchSize <= 0 {
+		return 64
+	}
+	return s.BatchSize
+}
+
+// LicenseConfig locates the enterprise license key and the public key it is
+// verified against. Inline values take precedence over files.
+type LicenseConfig struct {
+	Key           string        `yaml:"key" env:"LICENSE_KEY"`
+	KeyFile       string        `yaml:"key_file" env:"LICENSE_KEY_FILE"`
+	PublicKey     string        `yaml:"public_key" env:"LICENSE_PUBLIC_KEY"`
+	PublicKeyFile string        `yaml:"public_key_file" env:"LICENSE_PUBLIC_KEY_FILE"`
+	GracePeriod   time.Duration `yaml:"grace_period"`
+}
+
+// LoadKey returns the license key, reading KeyFile when Key is unset
+func (l *LicenseConfig) LoadKey() (string, error) {
+	return inlineOrFile(l.Key, l.KeyFile)
+}
+
+// LoadPublicKey returns the license public key PEM, reading PublicKeyFile
+// when PublicKey is unset
+func (l *LicenseConfig) LoadPublicKey() (string, error) {
+	return inlineOrFile(l.PublicKey, l.PublicKeyFile)
+}
+
+func inlineOrFile(value, path string) (string, error) {
+	if value != "" || path == "" {
+		return value, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// NotificationsConfig controls admin notification checks and email digests
+type NotificationsConfig struct {
+	SMTP     SMTPConfig     `yaml:"smtp"`
+	Webhooks WebhooksConfig `yaml:"webhooks"`
+	// CheckInterval is how often the worker checks quotas and certificate
+	// expiry; zero disables the checks
+	CheckInterval time.Duration `yaml:"check_interval"`
+	// DigestInterval is how often unread notifications are emailed to admins
+	// who opted in; zero disables digests
+	DigestInterval time.Duration `yaml:"digest_interval"`
+	// OwnerEscalateAfter is how long a server owner may leave a failure
+	// alert unacknowledged before admins are notified, unless the owner set
+	// their own period
+	OwnerEscalateAfter time.Duration `yaml:"owner_escalate_after"`
+	// OwnerEscalationInterval is how often the worker escalates
+	// unacknowledged owner alerts; zero disables escalation
+	OwnerEscalationInterval time.Duration `yaml:"owner_escalation_interval"`
+	// GrantReminderBefore is how long before a time-boxed role or namespace
+	// grant expires that admins and the grantee are reminded
+	GrantReminderBefore time.Duration `yaml:"grant_reminder_before"`
+	// GrantExpiryInterval is how often the worker expires grants and sends
+	// reminders; zero disables both
+	GrantExpiryInterval time.Duration `yaml:"grant_expiry_interval"`
+	// BudgetCheckInterval is how often the worker notifies admins of usage
+	// crossing their monthly budgets' thresholds; zero disables the alerts
+	BudgetCheckInterval   time.Duration `yaml:"budget_check_interval"`
+	QuotaThresholdPct     int           `yaml:"quota_threshold_percent"`
+	CertificateExpiryDays int           `yaml:"certificate_expiry_days"`
+}
+
+// WebhooksConfig controls delivery of gateway events to organizations'
+// webhooks
+type WebhooksConfig struct {
+	// DeliveryInterval is how often the worker posts due deliveries; zero
+	// disables delivery, leaving events queued
+	DeliveryInterval time.Duration `yaml:"delivery_interval"`
+	// Timeout bounds each attempt; RetryBackoff is the wait after the first
+	// failed attempt, doubling after each one up to an hour
+	Timeout      time.Duration `yaml:"timeout"`
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	// Retention is how long finished deliveries stay in the delivery log
+	Retention   time.Duration `yaml:"retention"`
+	MaxAttempts int           `yaml:"max_attempts"`
+}
+
+// TelemetryConfig controls anonymous usage reporting. Reporting is on unless
+// disabled here or by the DO_NOT_TRACK environment variable.
+type TelemetryConfig struct {
+	Endpoint string        `yaml:"endpoint" env:"TELEMETRY_ENDPOINT"`
+	Interval time.Duration `yaml:"interval"`
+	Enabled  bool          `yaml:"enabled" env:"TELEMETRY_ENABLED"`
+}
+
+// RegionConfig places the gateway in a multi-region active-active
+// deployment. Each region runs against its own database; the worker pulls
+// servers, namespaces and endpoints changed in the peer regions and applies
+// them locally. A single-region deployment leaves Name empty.
+type RegionConfig struct {
+	Name string `yaml:"name" env:"GATEWAY_REGION"`
+	// GlobalURL is the latency-routed URL clients use, resolved by DNS to
+	// the nearest healthy region
+	GlobalURL string `yaml:"global_url"`
+	// ReplicationToken is the shared secret peers present to read this
+	// region's catalog changes
+	ReplicationToken string             `yaml:"replication_token" env:"REPLICATION_TOKEN"`
+	Peers            []RegionPeerConfig `yaml:"peers"`
+	// Role is active or standby until the first failover, after which the
+	// role stored in the database applies. Only the active region runs
+	// quota checks, telemetry and webhook deliveries.
+	Role string `yaml:"role" env:"GATEWAY_ROLE"`
+	// Interval is how often the worker pulls changes from each peer
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+}
+
+// RegionPeerConfig is another region of the deployment
+type RegionPeerConfig struct {
+	Name string `yaml:"name"`
+	// URL is the peer's own base URL, not the global one
+	URL string `yaml:"url"`
+}
+
+// GetBatchSize returns how many changes are pulled per request, defaulting
+// to 500
+func (r *RegionConfig) GetBatchSize() int {
+	if r.BatchSize <= 0 {
+		return 500
+	}
+	return r.BatchSize
+}
+
+// SMTPConfig configures the mail server used for notification digests
+type SMTPConfig struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD"`
+	From     string `yaml:"from" env:"SMTP_FROM"`
+	Port     int    `yaml:"port" env:"SMTP_PORT"`
+}
+
+// StatsDConfig configures metric export to a StatsD or DogStatsD agent
+type StatsDConfig struct {
+	GlobalTags  map[string]string  `yaml:"global_tags"`
+	SampleRates map[string]float64 `yaml:"sample_rates"`
+	MetricNames map[string]string  `yaml:"metric_names"`
+	Address     string             `yaml:"address" env:"STATSD_ADDRESS"`
+	Prefix      string             `yaml:"prefix"`
+	Flavor      string             `yaml:"flavor"` // statsd, dogstatsd
+	SampleRate  float64            `yaml:"sample_rate"`
+	Enabled     bool               `yaml:"enabled" env:"STATSD_ENABLED"`
+}
+
+// PrometheusConfig holds the Prometheus metrics endpoint configuration
+type PrometheusConfig struct {
+	Path      string    `yaml:"path"`
+	Namespace string    `yaml:"namespace"`
+	Buckets   []float64 `yaml:"buckets"` // Histogram bucket bounds in seconds
+	// MaxSeries caps the label combinations kept per metric
+	MaxSeries int `yaml:"max_series"`
+	// BearerToken, when set, must be presented by scrapers
+	BearerToken string `yaml:"bearer_token" env:"PROMETHEUS_BEARER_TOKEN"`
+	Enabled     bool   `yaml:"enabled" env:"PROMETHEUS_ENABLED"`
+}
+
+// TracingConfig configures OpenTelemetry tracing and its OTLP exporter
+type TracingConfig struct {
+	Headers     map[string]string `yaml:"headers"` // Sent with every export, such as a collector API key
+	ServiceName string            `yaml:"service_name"`
+	Endpoint    string            `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"` // host:port or URL of the collector
+	Protocol    string            `yaml:"protocol"`                                   // grpc, http
+	// SampleRatio is the share of new traces recorded, all when unset;
+	// traces continued from a caller follow the caller's decision
+	SampleRatio *float64 `yaml:"sample_ratio"`
+	Insecure    bool     `yaml:"insecure"`
+	Enabled     bool     `yaml:"enabled" env:"TRACING_ENABLED"`
+}
+
+// GetSampleRatio returns the share of new traces recorded
+func (t *TracingConfig) GetSampleRatio() float64 {
+	if t.SampleRatio == nil {
+		return 1
+	}
+	return *t.SampleRatio
+}
+
 // RateLimitConfig holds rate limiting configuration
 type RateLimitConfig struct {
 	Storage         string        `yaml:"storage"`
@@ -126,7 +679,10 @@ type RateLimitConfig struct {
 	IPEnabled           bool     `yaml:"ip_enabled"`
 	IPRequestsPerMinute int      `yaml:"ip_requests_per_minute"`
 	IPSkipPaths         []string `yaml:"ip_skip_paths"`
-	IPCustomHeaders     []string `yaml:"ip_custom_headers"`
+	// IPCustomHeaders is deprecated in favour of server.client_ip_headers,
+	// and only used in its place when that is not set. IP rate limits are
+	// keyed by the client address those headers resolve.
+	IPCustomHeaders []string `yaml:"ip_custom_headers"`
 }
 
 // DiscoveryConfig holds MCP server discovery configuration
@@ -136,14 +692,295 @@ type DiscoveryConfig struct {
 	FailureThreshold int           `yaml:"failure_threshold"`
 	RecoveryTimeout  time.Duration `yaml:"recovery_timeout"`
 	MCPURL           string        `yaml:"mcp_discovery_url" env:"MCP_DISCOVERY_URL"`
+	SecretScanning   string        `yaml:"secret_scanning"`
+	// Kubernetes registers labelled Services as MCP servers
+	Kubernetes KubernetesDiscoveryConfig `yaml:"kubernetes"`
+	// A2ACards refreshes the agent cards of A2A agents
+	A2ACards A2ACardConfig `yaml:"a2a_cards"`
+}
+
+// A2ACardConfig controls how often A2A agent cards are fetched again.
+// Zero disables the refresh.
+type A2ACardConfig struct {
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+}
+
+// KubernetesDiscoveryConfig controls Kubernetes service discovery. The API
+// server, token and CA default to the pod's service account.
+type KubernetesDiscoveryConfig struct {
+	APIServer      string        `yaml:"api_server"`
+	TokenFile      string        `yaml:"token_file"`
+	CAFile         string        `yaml:"ca_file"`
+	LabelSelector  string        `yaml:"label_selector"`
+	ClusterDomain  string        `yaml:"cluster_domain"`
+	OrganizationID string        `yaml:"organization_id"`
+	Namespaces     []string      `yaml:"namespaces"`
+	ResyncInterval time.Duration `yaml:"resync_interval"`
+	Enabled        bool          `yaml:"enabled" env:"KUBERNETES_DISCOVERY_ENABLED"`
 }
 
 // GatewayConfig holds core gateway configuration
 type GatewayConfig struct {
-	LoadBalancer   string               `yaml:"load_balancer"`
-	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
-	ProxyTimeout   time.Duration        `yaml:"proxy_timeout"`
-	MaxRetries     int                  `yaml:"max_retries"`
+	LoadBalancer       string               `yaml:"load_balancer"`
+	CircuitBreaker     CircuitBreakerConfig `yaml:"circuit_breaker"`
+	ProxyTimeout       time.Duration        `yaml:"proxy_timeout"`
+	MaxRetries         int                  `yaml:"max_retries"`
+	Retry              RetryConfig          `yaml:"retry"`
+	Scheduler          SchedulerConfig      `yaml:"scheduler"`
+	LoadShedding       LoadSheddingConfig   `yaml:"load_shedding"`
+	ServiceHealth      ServiceHealthConfig  `yaml:"service_health"`
+	Nodes              NodeRegistryConfig   `yaml:"nodes"`
+	Approvals          ApprovalConfig       `yaml:"approvals"`
+	Jobs               JobsConfig           `yaml:"jobs"`
+	Sandbox            SandboxConfig        `yaml:"sandbox"`
+	StatusPage         StatusPageConfig     `yaml:"status_page"`
+	Offline            OfflineConfig        `yaml:"offline"`
+	ToolValidation     ToolValidationConfig `yaml:"tool_schema_validation"`
+	Residency          ResidencyConfig      `yaml:"residency"`
+	DLP                DLPConfig            `yaml:"dlp"`
+	GeoIP              GeoIPConfig          `yaml:"geoip"`
+	OpenAPI            OpenAPIConfig        `yaml:"openapi"`
+	ListCache          ListCacheConfig      `yaml:"list_cache"`
+	ReadOnly           bool                 `yaml:"read_only"`
+	DisableClientShims bool                 `yaml:"disable_client_shims"`
+}
+
+// RetryConfig controls retries of failed calls to upstream servers. Calls
+// are retried up to each server's max_retries when they are safe to repeat.
+type RetryConfig struct {
+	// Budgets cap retries per server protocol; "default" covers protocols
+	// without a budget of their own
+	Budgets   map[string]RetryBudgetConfig `yaml:"budgets"`
+	BaseDelay time.Duration                `yaml:"base_delay"`
+	MaxDelay  time.Duration                `yaml:"max_delay"`
+}
+
+// RetryBudgetConfig caps the retries of a protocol. Every call adds Ratio
+// to the budget, up to Reserve, and every retry spends one.
+type RetryBudgetConfig struct {
+	Ratio   float64 `yaml:"ratio"`
+	Reserve int     `yaml:"reserve"`
+}
+
+// ToolValidationConfig controls JSON Schema validation of namespace tool calls
+type ToolValidationConfig struct {
+	// Inputs rejects calls whose arguments do not match the tool's input schema
+	Inputs bool `yaml:"inputs"`
+	// Outputs rejects results whose structured content does not match the
+	// tool's declared output schema
+	Outputs bool `yaml:"outputs"`
+}
+
+// ListCacheConfig controls caching of tool, resource and prompt listings.
+// The "memory" backend caches per gateway instance; "redis" shares entries
+// and invalidations between instances through the configured Redis server.
+type ListCacheConfig struct {
+	Backend string        `yaml:"backend" env:"LIST_CACHE_BACKEND"`
+	TTL     time.Duration `yaml:"ttl"`
+	// MaxEntries bounds the memory backend, which evicts the least
+	// recently used listing once full
+	MaxEntries int  `yaml:"max_entries"`
+	Enabled    bool `yaml:"enabled"`
+}
+
+// GetTTL returns how long listings are cached, defaulting to 30 seconds
+func (l *ListCacheConfig) GetTTL() time.Duration {
+	if l.TTL <= 0 {
+		return 30 * time.Second
+	}
+	return l.TTL
+}
+
+// OpenAPIConfig controls the OpenAPI specs generated for public endpoints
+type OpenAPIConfig struct {
+	// RecordedExamples uses arguments from logged tool calls as request
+	// examples. Specs are public, so this is off unless every endpoint's
+	// traffic is fit to publish.
+	RecordedExamples bool `yaml:"recorded_examples"`
+}
+
+// ResidencyConfig configures data residency enforcement for namespaces
+// pinned to a region
+type ResidencyConfig struct {
+	// StorageRegion is the region of the gateway's database and log storage.
+	// Pinned namespaces in other regions are refused.
+	StorageRegion string `yaml:"storage_region"`
+	// RequireMarked also refuses servers and storage without a region for
+	// pinned namespaces
+	RequireMarked bool `yaml:"require_marked"`
+}
+
+// GeoIPConfig locates clients for endpoint geo policies. Each path names a
+// MaxMind DB file, such as GeoLite2-Country.mmdb and GeoLite2-ASN.mmdb;
+// a single database carrying both countries and ASNs may be set alone.
+// Geo policies cannot be set while neither is configured.
+type GeoIPConfig struct {
+	CountryDatabase string `yaml:"country_database" env:"GEOIP_COUNTRY_DB"`
+	ASNDatabase     string `yaml:"asn_database" env:"GEOIP_ASN_DB"`
+}
+
+// OfflineConfig configures air-gapped operation
+type OfflineConfig struct {
+	// Mirrors replace external services with local ones, keyed by
+	// connectivity feature (e.g. mcp_package_discovery)
+	Mirrors map[string]string `yaml:"mirrors"`
+	// AllowedHosts are reachable while offline in addition to private and
+	// cluster-internal hosts; "*.example.com" matches subdomains. They are
+	// also the only internal hosts URLs set by organizations may point at.
+	AllowedHosts []string `yaml:"allowed_hosts"`
+	Enabled      bool     `yaml:"enabled" env:"OFFLINE_MODE"`
+}
+
+// SandboxConfig holds the limits for the endpoint test console
+type SandboxConfig struct {
+	Timeout           time.Duration `yaml:"timeout"`
+	RequestsPerMinute int           `yaml:"requests_per_minute"`
+	MaxArgumentBytes  int           `yaml:"max_argument_bytes"`
+}
+
+// Limits returns the sandbox limits, filling in defaults for unset values
+func (s SandboxConfig) Limits() types.SandboxLimits {
+	limits := types.SandboxLimits{
+		RequestsPerMinute: s.RequestsPerMinute,
+		TimeoutSeconds:    int(s.Timeout / time.Second),
+		MaxArgumentBytes:  s.MaxArgumentBytes,
+	}
+	if limits.RequestsPerMinute <= 0 {
+		limits.RequestsPerMinute = 10
+	}
+	if limits.TimeoutSeconds <= 0 {
+		limits.TimeoutSeconds = 15
+	}
+	if limits.MaxArgumentBytes <= 0 {
+		limits.MaxArgumentBytes = 16 * 1024
+	}
+	return limits
+}
+
+// StatusPageConfig holds the limits for public endpoint status pages
+type StatusPageConfig struct {
+	CacheTTL          time.Duration `yaml:"cache_ttl"`
+	RequestsPerMinute int           `yaml:"requests_per_minute"`
+}
+
+// Limits returns the status page limits, filling in defaults for unset values
+func (s StatusPageConfig) Limits() types.StatusPageLimits {
+	limits := types.StatusPageLimits{
+		RequestsPerMinute: s.RequestsPerMinute,
+		CacheTTLSeconds:   int(s.CacheTTL / time.Second),
+	}
+	if limits.RequestsPerMinute <= 0 {
+		limits.RequestsPerMinute = 60
+	}
+	if limits.CacheTTLSeconds <= 0 {
+		limits.CacheTTLSeconds = 30
+	}
+	return limits
+}
+
+// SchedulerConfig controls plan-tier prioritization of tool executions and
+// transport connections
+type SchedulerConfig struct {
+	ConnectionShares        map[string]float64 `yaml:"connection_shares"`
+	MaxQueueWait            time.Duration      `yaml:"max_queue_wait"`
+	MaxConcurrentExecutions int                `yaml:"max_concurrent_executions"`
+	MaxQueuePerClass        int                `yaml:"max_queue_per_class"`
+}
+
+// LoadSheddingConfig controls the adaptive concurrency limit of the whole
+// gateway. Requests over the limit get a 503 instead of adding to the load
+// on Postgres and upstream servers.
+type LoadSheddingConfig struct {
+	ExemptPaths    []string      `yaml:"exempt_paths"`
+	LatencyFactor  float64       `yaml:"latency_factor"`
+	Backoff        float64       `yaml:"backoff"`
+	BaselineWindow time.Duration `yaml:"baseline_window"`
+	InitialLimit   int           `yaml:"initial_limit"`
+	MinLimit       int           `yaml:"min_limit"`
+	MaxLimit       int           `yaml:"max_limit"`
+	Enabled        bool          `yaml:"enabled"`
+}
+
+// ServiceHealthConfig controls how the gateway scores its own health.
+// Postgres and, when a feature uses it, Redis are probed every Interval;
+// /readyz fails while Postgres is down so load balancers stop routing to
+// the instance. Calls to upstream servers score each server, and server
+// groups prefer replicas within ReplicaTolerance of the best score.
+type ServiceHealthConfig struct {
+	Interval          time.Duration `yaml:"interval"`
+	Timeout           time.Duration `yaml:"timeout"`
+	DatabaseSlowAfter time.Duration `yaml:"database_slow_after"`
+	RedisSlowAfter    time.Duration `yaml:"redis_slow_after"`
+	// ImpactHalfLife is how fast a server's failed and slow calls are
+	// forgotten once no new calls are seen
+	ImpactHalfLife   time.Duration `yaml:"impact_half_life"`
+	ReplicaTolerance float64       `yaml:"replica_tolerance"`
+}
+
+// NodeRegistryConfig controls the registry of the gateway's API instances.
+// Each instance heartbeats every HeartbeatInterval and is reported stale
+// once StaleAfter passes without one. Address is where operators can reach
+// the instance directly.
+type NodeRegistryConfig struct {
+	Address           string        `yaml:"address" env:"GATEWAY_NODE_ADDRESS"`
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+	StaleAfter        time.Duration `yaml:"stale_after"`
+}
+
+// ApprovalConfig controls tool calls held for approval. A held call waits
+// Timeout for a decision unless its policy sets a timeout, and never longer
+// than MaxTimeout. Waiting instances check for decisions taken on other
+// instances every PollInterval.
+type ApprovalConfig struct {
+	Timeout      time.Duration `yaml:"timeout"`
+	MaxTimeout   time.Duration `yaml:"max_timeout"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+}
+
+// DLPConfig controls outbound DLP checks of tool arguments. Servers without
+// a DLP policy of their own get DefaultSensitivity and DefaultAction; the
+// restricted default lets them receive any data. External adds an external
+// DLP API to the built-in classifiers.
+type DLPConfig struct {
+	Enabled            bool              `yaml:"enabled"`
+	DefaultSensitivity string            `yaml:"default_sensitivity"`
+	DefaultAction      string            `yaml:"default_action"`
+	External           DLPExternalConfig `yaml:"external"`
+}
+
+// DLPExternalConfig configures an external DLP API. Calls fail while it
+// cannot be reached unless FailOpen checks them with the built-in
+// classifiers alone.
+type DLPExternalConfig struct {
+	URL      string        `yaml:"url" env:"DLP_API_URL"`
+	APIKey   string        `yaml:"api_key" env:"DLP_API_KEY"`
+	Timeout  time.Duration `yaml:"timeout"`
+	FailOpen bool          `yaml:"fail_open"`
+}
+
+// GetDefaultSensitivity returns the label of servers without a DLP policy
+func (d DLPConfig) GetDefaultSensitivity() string {
+	if d.DefaultSensitivity == "" {
+		return types.DLPSensitivityRestricted
+	}
+	return d.DefaultSensitivity
+}
+
+// GetDefaultAction returns the action of servers without a DLP policy
+func (d DLPConfig) GetDefaultAction() string {
+	if d.DefaultAction == "" {
+		return types.DLPActionLog
+	}
+	return d.DefaultAction
+}
+
+// JobsConfig controls the worker consumers of queued tool executions. Each
+// worker process runs up to Workers jobs at once, looks for new jobs every
+// PollInterval when idle and deletes finished jobs after Retention.
+type JobsConfig struct {
+	Workers      int           `yaml:"workers"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	Retention    time.Duration `yaml:"retention"`
 }
 
 // CircuitBreakerConfig holds circuit breaker configuration
@@ -165,15 +1002,48 @@ type RedisConfig struct {
 
 // TransportConfig holds transport layer configuration
 type TransportConfig struct {
-	EnabledTransports  []types.TransportType `yaml:"enabled_transports" env:"TRANSPORT_ENABLED"`
-	PathRewrite        PathRewriteConfig     `yaml:"path_rewrite"`
-	SSEKeepAlive       time.Duration         `yaml:"sse_keep_alive"`
-	WebSocketTimeout   time.Duration         `yaml:"websocket_timeout"`
-	SessionTimeout     time.Duration         `yaml:"session_timeout"`
-	MaxConnections     int                   `yaml:"max_connections"`
-	BufferSize         int                   `yaml:"buffer_size"`
-	STDIOTimeout       time.Duration         `yaml:"stdio_timeout"`
-	StreamableStateful bool                  `yaml:"streamable_stateful"`
+	EnabledTransports  []types.TransportType    `yaml:"enabled_transports" env:"TRANSPORT_ENABLED"`
+	PathRewrite        PathRewriteConfig        `yaml:"path_rewrite"`
+	STDIOCommands      types.STDIOCommandPolicy `yaml:"stdio_commands"`
+	SSEKeepAlive       time.Duration            `yaml:"sse_keep_alive"`
+	WebSocketTimeout   time.Duration            `yaml:"websocket_timeout"`
+	SessionTimeout     time.Duration            `yaml:"session_timeout"`
+	MaxConnections     int                      `yaml:"max_connections"`
+	BufferSize         int                      `yaml:"buffer_size"`
+	STDIOTimeout       time.Duration            `yaml:"stdio_timeout"`
+	StreamableStateful bool                     `yaml:"streamable_stateful"`
+	// WebSocketRequireSubprotocol rejects /ws upgrades without a supported
+	// Sec-WebSocket-Protocol
+	WebSocketRequireSubprotocol bool             `yaml:"websocket_require_subprotocol"`
+	ServerLogs                  ServerLogsConfig `yaml:"server_logs"`
+	EventBus                    EventBusConfig   `yaml:"event_bus"`
+}
+
+// EventBusConfig selects how gateway instances share broadcasts. The
+// "local" backend reaches only this instance's clients; "redis" shares
+// them through Redis Streams on the configured Redis server.
+type EventBusConfig struct {
+	Backend string `yaml:"backend" env:"EVENT_BUS_BACKEND"`
+	// MaxLen caps each topic's stream in Redis
+	MaxLen int64 `yaml:"max_len"`
+}
+
+// GetMaxLen returns the stream cap, defaulting to 10000 events
+func (e *EventBusConfig) GetMaxLen() int64 {
+	if e.MaxLen <= 0 {
+		return 10000
+	}
+	return e.MaxLen
+}
+
+// ServerLogsConfig controls capture of stdout and stderr of STDIO servers
+type ServerLogsConfig struct {
+	// BufferLines is how many recent lines are kept in memory per server
+	BufferLines int `yaml:"buffer_lines"`
+	// Retention is how long persisted lines are kept; zero keeps output in
+	// memory only
+	Retention time.Duration `yaml:"retention"`
+	Enabled   bool          `yaml:"enabled"`
 }
 
 // PathRewriteConfig holds path rewriting configuration
@@ -284,6 +1154,7 @@ func (t *TransportConfig) SetDefaults() {
 			types.TransportTypeSSE,
 			types.TransportTypeWebSocket,
 			types.TransportTypeStreamable,
+			types.TransportTypeGRPC,
 		}
 	}
 
@@ -327,7 +1198,8 @@ func (t *TransportConfig) Validate() error {
 			types.TransportTypeSSE,
 			types.TransportTypeWebSocket,
 			types.TransportTypeStreamable,
-			types.TransportTypeSTDIO:
+			types.TransportTypeSTDIO,
+			types.TransportTypeGRPC:
 			// Valid transport types
 		default:
 			return fmt.Errorf("invalid transport type: %s", transportType)
@@ -360,6 +1232,16 @@ func (t *TransportConfig) Validate() error {
 		return fmt.Errorf("buffer_size must be positive")
 	}
 
+	if _, err := commandpolicy.New(t.STDIOCommands); err != nil {
+		return fmt.Errorf("stdio_commands: %w", err)
+	}
+
+	switch t.EventBus.Backend {
+	case "", "local", "redis":
+	default:
+		return fmt.Errorf("event_bus.backend must be local or redis, got %q", t.EventBus.Backend)
+	}
+
 	return nil
 }
 
@@ -374,5 +1256,8 @@ func (t *TransportConfig) ToTransportConfig() *types.TransportConfig {
 		BufferSize:         t.BufferSize,
 		StreamableStateful: t.StreamableStateful,
 		STDIOTimeout:       t.STDIOTimeout,
+		STDIOCommands:      t.STDIOCommands,
+
+		WebSocketRequireSubprotocol: t.WebSocketRequireSubprotocol,
 	}
 }
@@ -5,10 +5,13 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/secrets"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/google/uuid"
@@ -23,6 +26,12 @@ type Service struct {
 	toolModel          *models.MCPToolModel
 	promptModel        *models.MCPPromptModel
 	resourceModel      *models.MCPResourceModel
+	events             EventEmitter
+}
+
+// EventEmitter publishes gateway events to organizations' webhooks
+type EventEmitter interface {
+	Emit(ctx context.Context, orgID, eventType string, data map[string]interface{}) error
 }
 
 // NewService creates a new configuration service
@@ -37,6 +46,11 @@ func NewService(db models.Database) *Service {
 	}
 }
 
+// SetEvents publishes a config.imported event after every committed import
+func (s *Service) SetEvents(events EventEmitter) {
+	s.events = events
+}
+
 // ExportConfiguration exports configuration entities based on the request
 func (s *Service) ExportConfiguration(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, req *types.ExportRequest) (*types.ConfigurationExport, error) {
 	exportID := "export-" + time.Now().Format("20060102-150405")
@@ -180,6 +194,7 @@ func (s *Service) ImportConfiguration(ctx context.Context, orgID uuid.UUID, user
 			return nil, fmt.Errorf("failed to commit import: %w", err)
 		}
 		result.Status = types.ImportStatusCompleted
+		s.emitImported(ctx, orgID, userID, result)
 	} else if req.DryRun {
 		result.Status = types.ImportStatusCompleted
 	}
@@ -197,6 +212,20 @@ func (s *Service) ImportConfiguration(ctx context.Context, orgID uuid.UUID, user
 	return result, nil
 }
 
+func (s *Service) emitImported(ctx context.Context, orgID, userID uuid.UUID, result *types.ImportResult) {
+	if s.events == nil {
+		return
+	}
+	err := s.events.Emit(ctx, orgID.String(), types.WebhookEventConfigImported, map[string]interface{}{
+		"import_id":   result.ImportID,
+		"imported_by": userID.String(),
+		"summary":     result.Summary,
+	})
+	if err != nil {
+		log.Printf("Failed to publish config.imported event for import %s: %v", result.ImportID, err)
+	}
+}
+
 // ValidateImport validates import data without making changes
 func (s *Service) ValidateImport(ctx context.Context, orgID uuid.UUID, req *types.ValidateImportRequest) (*types.ValidationResult, error) {
 	validation := &types.ValidationResult{
@@ -364,7 +393,14 @@ func (s *Service) exportServers(_ context.Context, orgID uuid.UUID, filters type
 			continue
 		}
 
-		result = append(result, server)
+		// Exports leave the gateway, so credentials are masked; an import
+		// of a masked value must be followed by re-entering the secret
+		masked := *server
+		masked.Args = pq.StringArray(secrets.MaskArgs(server.Args))
+		masked.Environment = pq.StringArray(secrets.MaskEnvironment(server.Environment))
+		masked.Command.String = secrets.Mask(server.Command.String)
+		masked.URL.String = secrets.Mask(server.URL.String)
+		result = append(result, &masked)
 	}
 
 	return result, nil
@@ -490,6 +526,21 @@ func (s *Service) importServer(ctx context.Context, tx *sql.Tx, orgID uuid.UUID,
 
 	// Check for existing server by name
 	existing, err := s.mcpServerModel.GetByName(orgID, server.Name)
+	if err == nil {
+		// Exports mask credentials; keep the stored values they stand for
+		server.Args = pq.StringArray(secrets.RestoreArgs(server.Args, existing.Args))
+		server.Environment = pq.StringArray(secrets.RestoreEnvironment(server.Environment, existing.Environment))
+		server.Command.String = secrets.RestoreString(server.Command.String, existing.Command.String)
+		server.URL.String = secrets.RestoreString(server.URL.String, existing.URL.String)
+	}
+	if hasMaskedCredentials(&server) {
+		result.Warnings = append(result.Warnings, types.ImportWarning{
+			Code:       "MASKED_CREDENTIALS",
+			Message:    "Server configuration contains masked credentials that must be re-entered",
+			EntityType: types.EntityTypeServer,
+			EntityName: server.Name,
+		})
+	}
 	if err == nil {
 		// Server exists, handle conflict
 		switch strategy {
@@ -550,6 +601,19 @@ func (s *Service) importServer(ctx context.Context, tx *sql.Tx, orgID uuid.UUID,
 	return nil
 }
 
+// hasMaskedCredentials reports whether an imported server still carries
+// values masked on export
+func hasMaskedCredentials(server *models.MCPServer) bool {
+	values := append([]string{server.Command.String, server.URL.String}, server.Args...)
+	values = append(values, server.Environment...)
+	for _, value := range values {
+		if strings.Contains(value, types.RedactedValue) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Service) importVirtualServer(_ context.Context, _ *sql.Tx, _ uuid.UUID, _ any, _ types.ConflictStrategy, _ *types.ImportResult, entityCount *types.ImportEntityCount) error {
 	// Similar implementation for virtual servers
 	// This is a simplified version - full implementation would follow same pattern as importServer
@@ -3,7 +3,11 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
+	"strings"
 	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 )
 
 // Validate validates the entire configuration
@@ -24,6 +28,22 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("logging config: %w", err)
 	}
 
+	if err := c.Observability.StatsD.Validate(); err != nil {
+		return fmt.Errorf("statsd config: %w", err)
+	}
+
+	if err := c.Observability.Prometheus.Validate(); err != nil {
+		return fmt.Errorf("prometheus config: %w", err)
+	}
+
+	if err := c.Observability.Tracing.Validate(); err != nil {
+		return fmt.Errorf("tracing config: %w", err)
+	}
+
+	if err := c.Observability.Stats.Validate(); err != nil {
+		return fmt.Errorf("stats config: %w", err)
+	}
+
 	if err := c.RateLimit.Validate(); err != nil {
 		return fmt.Errorf("rate limit config: %w", err)
 	}
@@ -36,6 +56,56 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("gateway config: %w", err)
 	}
 
+	if err := c.Search.Embeddings.Validate(); err != nil {
+		return fmt.Errorf("search config: %w", err)
+	}
+
+	if err := c.Region.Validate(); err != nil {
+		return fmt.Errorf("region config: %w", err)
+	}
+
+	return nil
+}
+
+// Validate validates multi-region configuration
+func (r *RegionConfig) Validate() error {
+	if r.Interval < 0 {
+		return errors.New("replication interval cannot be negative")
+	}
+
+	if r.BatchSize < 0 {
+		return errors.New("replication batch_size cannot be negative")
+	}
+
+	switch r.Role {
+	case "", "active", "standby":
+	default:
+		return fmt.Errorf("invalid role: %s (must be active or standby)", r.Role)
+	}
+
+	if len(r.Peers) == 0 {
+		return nil
+	}
+
+	if r.Name == "" {
+		return errors.New("name is required when peers are configured")
+	}
+
+	if r.ReplicationToken == "" {
+		return errors.New("replication_token is required when peers are configured")
+	}
+
+	seen := map[string]bool{r.Name: true}
+	for _, peer := range r.Peers {
+		if peer.Name == "" || peer.URL == "" {
+			return errors.New("every peer needs a name and url")
+		}
+		if seen[peer.Name] {
+			return fmt.Errorf("region %s is listed twice", peer.Name)
+		}
+		seen[peer.Name] = true
+	}
+
 	return nil
 }
 
@@ -57,6 +127,14 @@ func (s *ServerConfig) Validate() error {
 		return errors.New("idle timeout cannot be negative")
 	}
 
+	for _, proxy := range s.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("trusted proxy %q is not an IP address or CIDR range", proxy)
+			}
+		}
+	}
+
 	return s.TLS.Validate()
 }
 
@@ -107,6 +185,18 @@ func (d *DatabaseConfig) Validate() error {
 		return errors.New("max connection lifetime cannot be negative")
 	}
 
+	if d.Partitioning.Interval < 0 {
+		return errors.New("partition maintenance interval cannot be negative")
+	}
+
+	if d.Partitioning.Premake < 0 || d.Partitioning.Premake > 12 {
+		return errors.New("partition premake must be between 0 and 12 months")
+	}
+
+	if d.Partitioning.HealthCheckRetention < 0 {
+		return errors.New("health check retention cannot be negative")
+	}
+
 	return nil
 }
 
@@ -132,6 +222,45 @@ func (a *AuthConfig) Validate() error {
 		return errors.New("bcrypt cost must be between 4 and 31")
 	}
 
+	if a.BreakGlassWindow < 0 || a.BreakGlassWindow > 24*time.Hour {
+		return errors.New("break-glass window must be at most 24 hours")
+	}
+
+	if a.ThreatFeedSecret != "" && len(a.ThreatFeedSecret) < 32 {
+		return errors.New("threat feed secret must be at least 32 characters")
+	}
+
+	if err := a.TokenSigning.Validate(); err != nil {
+		return err
+	}
+
+	if a.SSO.StateTTL < 0 || a.SSO.StateTTL > time.Hour {
+		return errors.New("SSO state TTL must be at most 1 hour")
+	}
+
+	switch a.ReplayProtection.Store {
+	case "", "database", "redis":
+	default:
+		return errors.New("replay protection store must be database or redis")
+	}
+
+	if a.ReplayProtection.ClockSkew < 0 || a.ReplayProtection.ClockSkew > time.Hour {
+		return errors.New("replay protection clock skew must be at most 1 hour")
+	}
+
+	switch a.PasswordHashing.Algorithm {
+	case "", "bcrypt":
+	case "argon2id":
+		if a.PasswordHashing.Argon2.Memory < 8*uint32(a.PasswordHashing.Argon2.Parallelism) {
+			return errors.New("argon2 memory must be at least 8 KiB per thread")
+		}
+		if a.PasswordHashing.Argon2.Iterations == 0 || a.PasswordHashing.Argon2.Parallelism == 0 {
+			return errors.New("argon2 iterations and parallelism must be positive")
+		}
+	default:
+		return errors.New("password hashing algorithm must be bcrypt or argon2id")
+	}
+
 	return nil
 }
 
@@ -162,6 +291,179 @@ func (l *LoggingConfig) Validate() error {
 		return errors.New("retention days cannot be negative")
 	}
 
+	if l.Sampling.SuccessRate < 0 || l.Sampling.SuccessRate > 1 {
+		return errors.New("logging sampling success_rate must be between 0 and 1")
+	}
+	if l.Sampling.TargetPerSecond < 0 {
+		return errors.New("logging sampling target_per_second cannot be negative")
+	}
+
+	switch l.OverflowPolicy {
+	case "", "drop_oldest", "backpressure":
+	default:
+		return errors.New("logging overflow_policy must be drop_oldest or backpressure")
+	}
+	if l.OverflowTimeout < 0 {
+		return errors.New("logging overflow_timeout cannot be negative")
+	}
+
+	if err := l.Archive.Validate(); err != nil {
+		return err
+	}
+	if err := l.RetentionEnforcement.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Validate validates log archive configuration
+func (a *LogArchiveConfig) Validate() error {
+	switch a.Storage {
+	case "", "file":
+	case "s3":
+		if a.S3.Bucket == "" || a.S3.Region == "" {
+			return errors.New("logging archive s3 storage requires a bucket and region")
+		}
+	default:
+		return errors.New("logging archive storage must be file or s3")
+	}
+
+	if a.Interval < 0 {
+		return errors.New("logging archive interval cannot be negative")
+	}
+	if a.RehydrationTTL < 0 {
+		return errors.New("logging archive rehydration_ttl cannot be negative")
+	}
+
+	return nil
+}
+
+// Validate validates retention enforcement configuration
+func (r *RetentionEnforcementConfig) Validate() error {
+	if r.Interval < 0 {
+		return errors.New("logging retention_enforcement interval cannot be negative")
+	}
+	if r.BatchSize < 0 {
+		return errors.New("logging retention_enforcement batch_size cannot be negative")
+	}
+	return nil
+}
+
+// Validate validates OAuth token signing configuration
+func (t *TokenSigningConfig) Validate() error {
+	switch t.Algorithm {
+	case "", "HS256":
+		if len(t.Keys) > 0 {
+			return errors.New("token signing keys require algorithm RS256 or ES256")
+		}
+		return nil
+	case "RS256", "ES256":
+	default:
+		return errors.New("token signing algorithm must be HS256, RS256 or ES256")
+	}
+
+	ids := make(map[string]bool, len(t.Keys))
+	for _, key := range t.Keys {
+		if key.ID == "" {
+			return errors.New("token signing keys require an id")
+		}
+		if ids[key.ID] {
+			return fmt.Errorf("duplicate token signing key id %q", key.ID)
+		}
+		ids[key.ID] = true
+		if (key.PrivateKey == "") == (key.PrivateKeyFile == "") {
+			return fmt.Errorf("token signing key %q needs exactly one of private_key and private_key_file", key.ID)
+		}
+	}
+	if t.ActiveKeyID != "" && !ids[t.ActiveKeyID] {
+		return fmt.Errorf("active token signing key %q is not configured", t.ActiveKeyID)
+	}
+	return nil
+}
+
+// Validate validates admin stats view configuration
+func (a *AdminStatsConfig) Validate() error {
+	if a.RefreshInterval < 0 {
+		return errors.New("stats refresh interval cannot be negative")
+	}
+
+	if a.StaleAfter < 0 {
+		return errors.New("stats staleness threshold cannot be negative")
+	}
+
+	return nil
+}
+
+// Validate validates the Prometheus metrics endpoint configuration
+func (p *PrometheusConfig) Validate() error {
+	if !p.Enabled {
+		return nil
+	}
+
+	if !strings.HasPrefix(p.Path, "/") {
+		return errors.New("path must start with /")
+	}
+
+	if p.MaxSeries < 0 {
+		return errors.New("max_series cannot be negative")
+	}
+
+	for i, bound := range p.Buckets {
+		if bound <= 0 {
+			return errors.New("buckets must be positive")
+		}
+		if i > 0 && bound <= p.Buckets[i-1] {
+			return errors.New("buckets must be in increasing order")
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the tracing configuration
+func (t *TracingConfig) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+
+	switch t.Protocol {
+	case "", "grpc", "http":
+	default:
+		return fmt.Errorf("unsupported protocol %q, must be grpc or http", t.Protocol)
+	}
+
+	if ratio := t.GetSampleRatio(); ratio < 0 || ratio > 1 {
+		return errors.New("sample_ratio must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// Validate validates StatsD export configuration
+func (s *StatsDConfig) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+
+	if s.Address == "" {
+		return errors.New("address is required when statsd is enabled")
+	}
+
+	if s.Flavor != "statsd" && s.Flavor != "dogstatsd" {
+		return errors.New("flavor must be statsd or dogstatsd")
+	}
+
+	if s.SampleRate <= 0 || s.SampleRate > 1 {
+		return errors.New("sample rate must be in (0, 1]")
+	}
+
+	for name, rate := range s.SampleRates {
+		if rate <= 0 || rate > 1 {
+			return fmt.Errorf("sample rate for %s must be in (0, 1]", name)
+		}
+	}
+
 	return nil
 }
 
@@ -205,6 +507,10 @@ func (d *DiscoveryConfig) Validate() error {
 		return errors.New("recovery timeout must be positive")
 	}
 
+	if d.SecretScanning != "" && !types.IsValidSecretScanMode(d.SecretScanning) {
+		return fmt.Errorf("secret scanning must be one of warn, block, off, got %q", d.SecretScanning)
+	}
+
 	return nil
 }
 
@@ -228,9 +534,161 @@ func (g *GatewayConfig) Validate() error {
 		return errors.New("invalid load balancer type")
 	}
 
+	if err := g.ListCache.Validate(); err != nil {
+		return err
+	}
+
+	if err := g.LoadShedding.Validate(); err != nil {
+		return err
+	}
+
+	if err := g.ServiceHealth.Validate(); err != nil {
+		return err
+	}
+
+	if err := g.Nodes.Validate(); err != nil {
+		return err
+	}
+
+	if err := g.Approvals.Validate(); err != nil {
+		return err
+	}
+
+	if err := g.Retry.Validate(); err != nil {
+		return err
+	}
+
 	return g.CircuitBreaker.Validate()
 }
 
+// Validate validates search embeddings configuration
+func (s *SearchEmbeddingsConfig) Validate() error {
+	if s.Interval < 0 {
+		return errors.New("embeddings interval cannot be negative")
+	}
+
+	if s.BatchSize < 0 {
+		return errors.New("embeddings batch_size cannot be negative")
+	}
+
+	if !s.Enabled {
+		return nil
+	}
+
+	if s.Endpoint == "" {
+		return errors.New("embeddings endpoint is required when embeddings are enabled")
+	}
+
+	if s.Model == "" {
+		return errors.New("embeddings model is required when embeddings are enabled")
+	}
+
+	return nil
+}
+
+// Validate validates list cache configuration
+func (l *ListCacheConfig) Validate() error {
+	if l.Backend != "" && l.Backend != "memory" && l.Backend != "redis" {
+		return errors.New("list cache backend must be memory or redis")
+	}
+
+	if l.TTL < 0 {
+		return errors.New("list cache ttl cannot be negative")
+	}
+
+	if l.MaxEntries < 0 {
+		return errors.New("list cache max_entries cannot be negative")
+	}
+
+	return nil
+}
+
+// Validate validates load shedding configuration
+func (l *LoadSheddingConfig) Validate() error {
+	if l.InitialLimit < 0 || l.MinLimit < 0 || l.MaxLimit < 0 {
+		return errors.New("load shedding limits cannot be negative")
+	}
+
+	if l.MaxLimit > 0 && l.MaxLimit < l.MinLimit {
+		return errors.New("load shedding max_limit cannot be less than min_limit")
+	}
+
+	if l.LatencyFactor != 0 && l.LatencyFactor <= 1 {
+		return errors.New("load shedding latency_factor must be greater than 1")
+	}
+
+	if l.Backoff != 0 && (l.Backoff <= 0 || l.Backoff >= 1) {
+		return errors.New("load shedding backoff must be between 0 and 1")
+	}
+
+	if l.BaselineWindow < 0 {
+		return errors.New("load shedding baseline_window cannot be negative")
+	}
+
+	return nil
+}
+
+// Validate validates service health configuration
+func (h *ServiceHealthConfig) Validate() error {
+	if h.Interval < 0 || h.Timeout < 0 || h.DatabaseSlowAfter < 0 || h.RedisSlowAfter < 0 || h.ImpactHalfLife < 0 {
+		return errors.New("service health durations cannot be negative")
+	}
+
+	if h.ReplicaTolerance < 0 || h.ReplicaTolerance > 1 {
+		return errors.New("service health replica_tolerance must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// Validate validates node registry configuration
+func (n *NodeRegistryConfig) Validate() error {
+	if n.HeartbeatInterval < 0 || n.StaleAfter < 0 {
+		return errors.New("node registry durations cannot be negative")
+	}
+
+	if n.HeartbeatInterval > 0 && n.StaleAfter > 0 && n.StaleAfter <= n.HeartbeatInterval {
+		return errors.New("node registry stale_after must be longer than heartbeat_interval")
+	}
+
+	return nil
+}
+
+// Validate validates tool approval configuration
+func (a *ApprovalConfig) Validate() error {
+	if a.Timeout < 0 || a.MaxTimeout < 0 || a.PollInterval < 0 {
+		return errors.New("approval durations cannot be negative")
+	}
+
+	if a.Timeout > 0 && a.MaxTimeout > 0 && a.Timeout > a.MaxTimeout {
+		return errors.New("approval timeout cannot exceed max_timeout")
+	}
+
+	return nil
+}
+
+// Validate validates retry configuration
+func (r *RetryConfig) Validate() error {
+	if r.BaseDelay < 0 || r.MaxDelay < 0 {
+		return errors.New("retry delays cannot be negative")
+	}
+
+	if r.MaxDelay > 0 && r.MaxDelay < r.BaseDelay {
+		return errors.New("retry max_delay cannot be less than base_delay")
+	}
+
+	for protocol, budget := range r.Budgets {
+		if budget.Ratio < 0 || budget.Ratio > 1 {
+			return fmt.Errorf("retry budget ratio of %s must be between 0 and 1", protocol)
+		}
+		if budget.Reserve < 0 {
+			return fmt.Errorf("retry budget reserve of %s cannot be negative", protocol)
+		}
+	}
+
+	return nil
+}
+
 // Validate validates circuit breaker configuration
 func (c *CircuitBreakerConfig) Validate() error {
 	if !c.Enabled {
@@ -298,6 +756,21 @@ func (c *Config) SetDefaults() {
 	if c.Auth.BCryptCost == 0 {
 		c.Auth.BCryptCost = 12
 	}
+	if c.Auth.BreakGlassWindow == 0 {
+		c.Auth.BreakGlassWindow = time.Hour
+	}
+	if c.Auth.PasswordHashing.Algorithm == "" {
+		c.Auth.PasswordHashing.Algorithm = "argon2id"
+	}
+	if c.Auth.PasswordHashing.Argon2.Memory == 0 {
+		c.Auth.PasswordHashing.Argon2.Memory = 19 * 1024
+	}
+	if c.Auth.PasswordHashing.Argon2.Iterations == 0 {
+		c.Auth.PasswordHashing.Argon2.Iterations = 2
+	}
+	if c.Auth.PasswordHashing.Argon2.Parallelism == 0 {
+		c.Auth.PasswordHashing.Argon2.Parallelism = 1
+	}
 
 	// Logging defaults
 	if c.Logging.Level == "" {
@@ -309,6 +782,42 @@ func (c *Config) SetDefaults() {
 	if c.Logging.RetentionDays == 0 {
 		c.Logging.RetentionDays = 30
 	}
+	if c.Logging.AuditAnchorInterval == 0 {
+		c.Logging.AuditAnchorInterval = time.Hour
+	}
+	if c.Logging.AuditExportInterval == 0 {
+		c.Logging.AuditExportInterval = 10 * time.Second
+	}
+
+	// Prometheus defaults
+	if c.Observability.Prometheus.Path == "" {
+		c.Observability.Prometheus.Path = "/metrics"
+	}
+	if c.Observability.Prometheus.Namespace == "" {
+		c.Observability.Prometheus.Namespace = "omnimesh"
+	}
+	if c.Observability.Prometheus.MaxSeries == 0 {
+		c.Observability.Prometheus.MaxSeries = 10000
+	}
+
+	// Tracing defaults
+	if c.Observability.Tracing.ServiceName == "" {
+		c.Observability.Tracing.ServiceName = "omnimesh-gateway"
+	}
+	if c.Observability.Tracing.Protocol == "" {
+		c.Observability.Tracing.Protocol = "grpc"
+	}
+
+	// StatsD defaults
+	if c.Observability.StatsD.Address == "" {
+		c.Observability.StatsD.Address = "127.0.0.1:8125"
+	}
+	if c.Observability.StatsD.Flavor == "" {
+		c.Observability.StatsD.Flavor = "dogstatsd"
+	}
+	if c.Observability.StatsD.SampleRate == 0 {
+		c.Observability.StatsD.SampleRate = 1
+	}
 
 	// Rate limit defaults
 	if c.RateLimit.DefaultLimit == 0 {
@@ -342,6 +851,17 @@ func (c *Config) SetDefaults() {
 	if c.Gateway.MaxRetries == 0 {
 		c.Gateway.MaxRetries = 3
 	}
+	if c.Gateway.Retry.BaseDelay == 0 {
+		c.Gateway.Retry.BaseDelay = 100 * time.Millisecond
+	}
+	if c.Gateway.Retry.MaxDelay == 0 {
+		c.Gateway.Retry.MaxDelay = 5 * time.Second
+	}
+	if c.Gateway.Retry.Budgets == nil {
+		c.Gateway.Retry.Budgets = map[string]RetryBudgetConfig{
+			"default": {Ratio: 0.1, Reserve: 10},
+		}
+	}
 	if c.Gateway.LoadBalancer == "" {
 		c.Gateway.LoadBalancer = "round_robin"
 	}
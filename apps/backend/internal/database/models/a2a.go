@@ -555,3 +555,98 @@ func (m *A2AAgentToolModel) DeleteByAgent(agentID uuid.UUID) error {
 	}
 	return nil
 }
+
+// A2AAgentCardModel handles the agent cards of A2A agents
+type A2AAgentCardModel struct {
+	db Database
+}
+
+// NewA2AAgentCardModel creates a new A2A agent card model
+func NewA2AAgentCardModel(db Database) *A2AAgentCardModel {
+	return &A2AAgentCardModel{db: db}
+}
+
+// Upsert stores the latest check of an agent's card
+func (m *A2AAgentCardModel) Upsert(record *types.A2AAgentCardRecord) error {
+	var cardJSON []byte
+	if record.Card != nil {
+		var err error
+		if cardJSON, err = json.Marshal(record.Card); err != nil {
+			return fmt.Errorf("failed to marshal agent card: %w", err)
+		}
+	}
+
+	_, err := m.db.Exec(`
+		INSERT INTO a2a_agent_cards (agent_id, url, status, card, error, problems, warnings, fetched_at, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (agent_id) DO UPDATE
+		SET url = EXCLUDED.url, status = EXCLUDED.status, card = EXCLUDED.card, error = EXCLUDED.error,
+		    problems = EXCLUDED.problems, warnings = EXCLUDED.warnings, fetched_at = EXCLUDED.fetched_at,
+		    checked_at = EXCLUDED.checked_at`,
+		record.AgentID, record.URL, record.Status, cardJSON, nullIfEmpty(record.Error),
+		pq.StringArray(record.Problems), pq.StringArray(record.Warnings), record.FetchedAt, record.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store agent card: %w", err)
+	}
+	return nil
+}
+
+// Get returns the latest check of an agent's card, or nil when its card
+// was never checked
+func (m *A2AAgentCardModel) Get(agentID uuid.UUID) (*types.A2AAgentCardRecord, error) {
+	record := &types.A2AAgentCardRecord{}
+	var cardJSON []byte
+	var errorMessage sql.NullString
+	err := m.db.QueryRow(`
+		SELECT agent_id, url, status, card, error, problems, warnings, fetched_at, checked_at
+		FROM a2a_agent_cards
+		WHERE agent_id = $1`, agentID).Scan(
+		&record.AgentID, &record.URL, &record.Status, &cardJSON, &errorMessage,
+		(*pq.StringArray)(&record.Problems), (*pq.StringArray)(&record.Warnings), &record.FetchedAt, &record.CheckedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent card: %w", err)
+	}
+
+	record.Error = errorMessage.String
+	if cardJSON != nil {
+		record.Card = &types.A2AAgentCard{}
+		if err := json.Unmarshal(cardJSON, record.Card); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal agent card: %w", err)
+		}
+	}
+	return record, nil
+}
+
+// ListStale returns up to limit active agents of the given types whose
+// card was not checked since checkedBefore, never checked ones first
+func (m *A2AAgentCardModel) ListStale(agentTypes []types.AgentType, checkedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	names := make([]string, len(agentTypes))
+	for i, agentType := range agentTypes {
+		names[i] = string(agentType)
+	}
+
+	rows, err := m.db.Query(`
+		SELECT a.id
+		FROM a2a_agents a
+		LEFT JOIN a2a_agent_cards c ON c.agent_id = a.id
+		WHERE a.is_active AND a.agent_type = ANY($1) AND (c.checked_at IS NULL OR c.checked_at < $2)
+		ORDER BY c.checked_at NULLS FIRST
+		LIMIT $3`, pq.StringArray(names), checkedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale agent cards: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,203 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// expiringGrantsQuery selects time-boxed role and namespace grants that have
+// not expired yet, formatted with the condition on expires_at
+const expiringGrantsQuery = `
+	SELECT 'role', g.id::text, g.organization_id::text, g.user_id::text, 'user', g.user_id::text,
+		g.role, '', '', '', g.expires_at
+	FROM role_grants g
+	WHERE g.revoked_at IS NULL AND g.expired_at IS NULL AND %[1]s
+	UNION ALL
+	SELECT 'namespace', g.id::text, g.organization_id::text,
+		CASE WHEN g.subject_type = 'user' THEN g.subject_id ELSE '' END, g.subject_type, g.subject_id,
+		'', g.namespace_id::text, n.name, g.access_level, g.expires_at
+	FROM namespace_grants g
+	JOIN namespaces n ON n.id = g.namespace_id
+	WHERE g.expires_at IS NOT NULL AND g.expired_at IS NULL AND %[1]s
+	ORDER BY 11
+`
+
+// accessGrantTables maps grant kinds to their tables
+var accessGrantTables = map[string]string{
+	types.AccessGrantKindRole:      "role_grants",
+	types.AccessGrantKindNamespace: "namespace_grants",
+}
+
+// AccessGrantModel handles temporary role grants and the expiry of role
+// and namespace grants
+type AccessGrantModel struct {
+	db Database
+}
+
+// NewAccessGrantModel creates a new access grant model
+func NewAccessGrantModel(db Database) *AccessGrantModel {
+	return &AccessGrantModel{db: db}
+}
+
+// ListRoleGrants returns the organization's role grants, newest first
+func (m *AccessGrantModel) ListRoleGrants(orgID string, filter *types.RoleGrantFilter, now time.Time) ([]*types.RoleGrant, error) {
+	query := `
+		SELECT g.id, g.organization_id, g.user_id, COALESCE(u.email, ''), g.role, g.reason, g.granted_by,
+			g.expires_at, g.reminded_at, g.expired_at, g.revoked_at, g.revoked_by, g.created_at
+		FROM role_grants g
+		LEFT JOIN users u ON u.id = g.user_id
+		WHERE g.organization_id = $1
+	`
+	args := []interface{}{orgID}
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		query += fmt.Sprintf(` AND g.user_id = $%d`, len(args))
+	}
+	if !filter.IncludeInactive {
+		args = append(args, now)
+		query += fmt.Sprintf(` AND g.revoked_at IS NULL AND g.expires_at > $%d`, len(args))
+	}
+	query += ` ORDER BY g.created_at DESC`
+
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := []*types.RoleGrant{}
+	for rows.Next() {
+		grant := &types.RoleGrant{}
+		if err := rows.Scan(
+			&grant.ID, &grant.OrganizationID, &grant.UserID, &grant.UserEmail, &grant.Role, &grant.Reason,
+			&grant.GrantedBy, &grant.ExpiresAt, &grant.RemindedAt, &grant.ExpiredAt, &grant.RevokedAt,
+			&grant.RevokedBy, &grant.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
+
+// CreateRoleGrant inserts a role grant
+func (m *AccessGrantModel) CreateRoleGrant(grant *types.RoleGrant) error {
+	query := `
+		INSERT INTO role_grants (id, organization_id, user_id, role, reason, granted_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+
+	if grant.ID == "" {
+		grant.ID = uuid.New().String()
+	}
+
+	return m.db.QueryRow(query,
+		grant.ID, grant.OrganizationID, grant.UserID, grant.Role, grant.Reason, grant.GrantedBy, grant.ExpiresAt,
+	).Scan(&grant.CreatedAt)
+}
+
+// RevokeRoleGrant ends an active role grant of the organization early
+func (m *AccessGrantModel) RevokeRoleGrant(orgID, id, revokedBy string) (bool, error) {
+	result, err := m.db.Exec(`
+		UPDATE role_grants SET revoked_at = NOW(), revoked_by = $3
+		WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL AND expired_at IS NULL
+	`, id, orgID, revokedBy)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ActiveRoles returns the roles a user's role grants give at now
+func (m *AccessGrantModel) ActiveRoles(userID string, now time.Time) ([]string, error) {
+	rows, err := m.db.Query(`
+		SELECT DISTINCT role FROM role_grants
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+	`, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// UserOrganization returns the organization of a user, or "" when there is
+// no such user
+func (m *AccessGrantModel) UserOrganization(userID string) (string, error) {
+	var orgID string
+	err := m.db.QueryRow(`SELECT organization_id FROM users WHERE id = $1`, userID).Scan(&orgID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return orgID, err
+}
+
+// ListDueReminders returns unexpired grants ending before before whose
+// holders have not been reminded yet
+func (m *AccessGrantModel) ListDueReminders(before time.Time) ([]*types.ExpiringGrant, error) {
+	return m.queryExpiring(fmt.Sprintf(expiringGrantsQuery, `g.reminded_at IS NULL AND g.expires_at <= $1`), before)
+}
+
+// ListDueExpiry returns grants that ended at or before now but have not
+// been marked expired
+func (m *AccessGrantModel) ListDueExpiry(now time.Time) ([]*types.ExpiringGrant, error) {
+	return m.queryExpiring(fmt.Sprintf(expiringGrantsQuery, `g.expires_at <= $1`), now)
+}
+
+// MarkReminded records that a grant's expiry reminder was sent
+func (m *AccessGrantModel) MarkReminded(kind, id string, at time.Time) error {
+	return m.markGrant(kind, "reminded_at", id, at)
+}
+
+// MarkExpired records that a grant expired
+func (m *AccessGrantModel) MarkExpired(kind, id string, at time.Time) error {
+	return m.markGrant(kind, "expired_at", id, at)
+}
+
+func (m *AccessGrantModel) markGrant(kind, column, id string, at time.Time) error {
+	table, ok := accessGrantTables[kind]
+	if !ok {
+		return fmt.Errorf("unknown grant kind %q", kind)
+	}
+	_, err := m.db.Exec(fmt.Sprintf(`UPDATE %s SET %s = $2 WHERE id = $1`, table, column), id, at)
+	return err
+}
+
+func (m *AccessGrantModel) queryExpiring(query string, at time.Time) ([]*types.ExpiringGrant, error) {
+	rows, err := m.db.Query(query, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := []*types.ExpiringGrant{}
+	for rows.Next() {
+		grant := &types.ExpiringGrant{}
+		if err := rows.Scan(
+			&grant.Kind, &grant.ID, &grant.OrganizationID, &grant.UserID, &grant.SubjectType, &grant.SubjectID,
+			&grant.Role, &grant.NamespaceID, &grant.NamespaceName, &grant.AccessLevel, &grant.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// nilServerID stands for requests not routed to a server in
+// stats_server_requests_daily
+const nilServerID = "00000000-0000-0000-0000-000000000000"
+
+// AdminStatsModel reads the admin stats views and refreshes them
+type AdminStatsModel struct {
+	db Database
+}
+
+// NewAdminStatsModel creates a new admin stats model
+func NewAdminStatsModel(db Database) *AdminStatsModel {
+	return &AdminStatsModel{db: db}
+}
+
+// RefreshView recomputes a stats view without blocking readers and records
+// when it was refreshed
+func (m *AdminStatsModel) RefreshView(view string, refreshedAt time.Time) error {
+	started := time.Now()
+	if _, err := m.db.Exec(fmt.Sprintf(`REFRESH MATERIALIZED VIEW CONCURRENTLY %s`, pq.QuoteIdentifier(view))); err != nil {
+		return err
+	}
+	_, err := m.db.Exec(`
+		INSERT INTO stats_view_refreshes (view_name, refreshed_at, duration_ms)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (view_name) DO UPDATE
+		SET refreshed_at = EXCLUDED.refreshed_at, duration_ms = EXCLUDED.duration_ms
+	`, view, refreshedAt, time.Since(started).Milliseconds())
+	return err
+}
+
+// ListRefreshes returns when each stats view was last refreshed
+func (m *AdminStatsModel) ListRefreshes() (map[string]time.Time, error) {
+	rows, err := m.db.Query(`SELECT view_name, refreshed_at FROM stats_view_refreshes`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refreshes := make(map[string]time.Time)
+	for rows.Next() {
+		var view string
+		var refreshedAt time.Time
+		if err := rows.Scan(&view, &refreshedAt); err != nil {
+			return nil, err
+		}
+		refreshes[view] = refreshedAt
+	}
+	return refreshes, rows.Err()
+}
+
+// TopTools returns the most called tools of an organization since a day,
+// most called first
+func (m *AdminStatsModel) TopTools(orgID string, since time.Time, limit int) ([]*types.ToolUsageStat, error) {
+	rows, err := m.db.Query(`
+		SELECT tool_name, SUM(calls), SUM(errors), COALESCE(SUM(total_duration_ms), 0)
+		FROM stats_tool_usage_daily
+		WHERE organization_id = $1 AND day >= $2::date
+		GROUP BY tool_name
+		ORDER BY SUM(calls) DESC, tool_name ASC
+		LIMIT $3
+	`, orgID, since.UTC().Format("2006-01-02"), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tools := []*types.ToolUsageStat{}
+	for rows.Next() {
+		tool := &types.ToolUsageStat{}
+		var totalDuration float64
+		if err := rows.Scan(&tool.Tool, &tool.Calls, &tool.Errors, &totalDuration); err != nil {
+			return nil, err
+		}
+		if tool.Calls > 0 {
+			tool.ErrorRate = float64(tool.Errors) / float64(tool.Calls)
+			tool.AvgDurationMS = totalDuration / float64(tool.Calls)
+		}
+		tools = append(tools, tool)
+	}
+	return tools, rows.Err()
+}
+
+// ServerRequests returns the request counts of each of an organization's
+// servers since a day. Requests not routed to a server have an empty
+// server ID.
+func (m *AdminStatsModel) ServerRequests(orgID string, since time.Time) ([]*types.ServerErrorStat, error) {
+	rows, err := m.db.Query(`
+		SELECT s.server_id, COALESCE(ms.name, ''), SUM(s.requests), SUM(s.errors), SUM(s.total_duration_ms)
+		FROM stats_server_requests_daily s
+		LEFT JOIN mcp_servers ms ON ms.id = s.server_id
+		WHERE s.organization_id = $1 AND s.day >= $2::date
+		GROUP BY s.server_id, ms.name
+	`, orgID, since.UTC().Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	servers := []*types.ServerErrorStat{}
+	for rows.Next() {
+		server := &types.ServerErrorStat{}
+		var totalDuration float64
+		if err := rows.Scan(&server.ServerID, &server.ServerName, &server.Requests, &server.Errors, &totalDuration); err != nil {
+			return nil, err
+		}
+		if server.ServerID == nilServerID {
+			server.ServerID = ""
+		}
+		if server.Requests > 0 {
+			server.ErrorRate = float64(server.Errors) / float64(server.Requests)
+			server.AvgDurationMS = totalDuration / float64(server.Requests)
+		}
+		servers = append(servers, server)
+	}
+	return servers, rows.Err()
+}
+
+// CountActiveUsers counts the users of an organization who made requests
+// since a day
+func (m *AdminStatsModel) CountActiveUsers(orgID string, since time.Time) (int64, error) {
+	var count int64
+	err := m.db.QueryRow(`
+		SELECT COUNT(DISTINCT user_id) FROM stats_active_users_daily
+		WHERE organization_id = $1 AND day >= $2::date
+	`, orgID, since.UTC().Format("2006-01-02")).Scan(&count)
+	return count, err
+}
+
+// CountUsers counts the active user accounts of an organization
+func (m *AdminStatsModel) CountUsers(orgID string) (int64, error) {
+	var count int64
+	err := m.db.QueryRow(`SELECT COUNT(*) FROM users WHERE organization_id = $1 AND is_active = true`, orgID).Scan(&count)
+	return count, err
+}
+
+// CountServers counts the enabled MCP servers of an organization and those
+// of them that are not unhealthy or in maintenance
+func (m *AdminStatsModel) CountServers(orgID string) (*types.ServerCounts, error) {
+	counts := &types.ServerCounts{}
+	err := m.db.QueryRow(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE status = 'active')
+		FROM mcp_servers
+		WHERE organization_id = $1 AND is_active = true
+	`, orgID).Scan(&counts.Total, &counts.Healthy)
+	return counts, err
+}
@@ -0,0 +1,249 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const breakGlassCredentialColumns = `
+	c.id, c.organization_id, c.user_id, COALESCE(u.email, ''), c.name, c.credential_hash, c.mfa_secret,
+	c.created_by, c.created_at, c.rotated_at, c.used_at
+`
+
+const breakGlassSessionColumns = `
+	s.id, s.credential_id, s.organization_id, s.user_id, COALESCE(u.email, ''), s.reason, s.remote_ip,
+	s.started_at, s.expires_at, s.ended_at, s.ended_by, s.action_count
+`
+
+// BreakGlassModel handles emergency credentials and their sessions
+type BreakGlassModel struct {
+	db Database
+}
+
+// NewBreakGlassModel creates a new break-glass model
+func NewBreakGlassModel(db Database) *BreakGlassModel {
+	return &BreakGlassModel{db: db}
+}
+
+// ListCredentials returns the organization's emergency credentials
+func (m *BreakGlassModel) ListCredentials(orgID string) ([]*types.BreakGlassCredential, error) {
+	rows, err := m.db.Query(`
+		SELECT `+breakGlassCredentialColumns+`
+		FROM break_glass_credentials c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.organization_id = $1
+		ORDER BY c.name
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credentials := []*types.BreakGlassCredential{}
+	for rows.Next() {
+		credential, err := scanBreakGlassCredential(rows)
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, credential)
+	}
+	return credentials, rows.Err()
+}
+
+// GetCredential returns a credential of the organization, or nil when there
+// is none
+func (m *BreakGlassModel) GetCredential(orgID, id string) (*types.BreakGlassCredential, error) {
+	return m.queryCredential(`c.id = $1 AND c.organization_id = $2`, id, orgID)
+}
+
+// GetCredentialByHash returns the credential with a hash, or nil when there
+// is none
+func (m *BreakGlassModel) GetCredentialByHash(hash string) (*types.BreakGlassCredential, error) {
+	return m.queryCredential(`c.credential_hash = $1`, hash)
+}
+
+func (m *BreakGlassModel) queryCredential(condition string, args ...interface{}) (*types.BreakGlassCredential, error) {
+	row := m.db.QueryRow(`
+		SELECT `+breakGlassCredentialColumns+`
+		FROM break_glass_credentials c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE `+condition, args...)
+	credential, err := scanBreakGlassCredential(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return credential, err
+}
+
+// CreateCredential inserts a credential
+func (m *BreakGlassModel) CreateCredential(credential *types.BreakGlassCredential) error {
+	if credential.ID == "" {
+		credential.ID = uuid.New().String()
+	}
+	return m.db.QueryRow(`
+		INSERT INTO break_glass_credentials (id, organization_id, user_id, name, credential_hash, mfa_secret, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`, credential.ID, credential.OrganizationID, credential.UserID, credential.Name,
+		credential.CredentialHash, credential.MFASecret, credential.CreatedBy,
+	).Scan(&credential.CreatedAt)
+}
+
+// RotateCredential replaces a credential's secrets and seals it again
+func (m *BreakGlassModel) RotateCredential(orgID, id, hash, mfaSecret string) (bool, error) {
+	result, err := m.db.Exec(`
+		UPDATE break_glass_credentials
+		SET credential_hash = $3, mfa_secret = $4, rotated_at = NOW(), used_at = NULL
+		WHERE id = $1 AND organization_id = $2
+	`, id, orgID, hash, mfaSecret)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// DeleteCredential deletes a credential of the organization
+func (m *BreakGlassModel) DeleteCredential(orgID, id string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM break_glass_credentials WHERE id = $1 AND organization_id = $2`, id, orgID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ClaimCredential unseals a credential, reporting false when it was already
+// used
+func (m *BreakGlassModel) ClaimCredential(id string, at time.Time) (bool, error) {
+	result, err := m.db.Exec(`
+		UPDATE break_glass_credentials SET used_at = $2 WHERE id = $1 AND used_at IS NULL
+	`, id, at)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// UserOrganization returns the organization of an active user, or "" when
+// there is no such user
+func (m *BreakGlassModel) UserOrganization(userID string) (string, error) {
+	var orgID string
+	err := m.db.QueryRow(`SELECT organization_id FROM users WHERE id = $1 AND is_active = true`, userID).Scan(&orgID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return orgID, err
+}
+
+// CreateSession inserts a session
+func (m *BreakGlassModel) CreateSession(session *types.BreakGlassSession) error {
+	if session.ID == "" {
+		session.ID = uuid.New().String()
+	}
+	_, err := m.db.Exec(`
+		INSERT INTO break_glass_sessions (id, credential_id, organization_id, user_id, reason, remote_ip, started_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, session.ID, session.CredentialID, session.OrganizationID, session.UserID, session.Reason,
+		session.RemoteIP, session.StartedAt, session.ExpiresAt)
+	return err
+}
+
+// GetSession returns a session, or nil when there is none
+func (m *BreakGlassModel) GetSession(id string) (*types.BreakGlassSession, error) {
+	row := m.db.QueryRow(`
+		SELECT `+breakGlassSessionColumns+`
+		FROM break_glass_sessions s
+		LEFT JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1
+	`, id)
+	session, err := scanBreakGlassSession(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return session, err
+}
+
+// ListSessions returns the organization's sessions, newest first
+func (m *BreakGlassModel) ListSessions(orgID string) ([]*types.BreakGlassSession, error) {
+	return m.querySessions(`s.organization_id = $1 ORDER BY s.started_at DESC`, orgID)
+}
+
+// ListExpiredSessions returns sessions past their expiry that have not been
+// ended
+func (m *BreakGlassModel) ListExpiredSessions(now time.Time) ([]*types.BreakGlassSession, error) {
+	return m.querySessions(`s.ended_at IS NULL AND s.expires_at <= $1 ORDER BY s.expires_at`, now)
+}
+
+func (m *BreakGlassModel) querySessions(condition string, args ...interface{}) ([]*types.BreakGlassSession, error) {
+	rows, err := m.db.Query(`
+		SELECT `+breakGlassSessionColumns+`
+		FROM break_glass_sessions s
+		LEFT JOIN users u ON u.id = s.user_id
+		WHERE `+condition, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*types.BreakGlassSession{}
+	for rows.Next() {
+		session, err := scanBreakGlassSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// EndSession ends an open session, reporting false when it had already
+// ended
+func (m *BreakGlassModel) EndSession(id, endedBy string, at time.Time) (bool, error) {
+	result, err := m.db.Exec(`
+		UPDATE break_glass_sessions SET ended_at = $3, ended_by = $2
+		WHERE id = $1 AND ended_at IS NULL
+	`, id, endedBy, at)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// RecordAction counts a request made during a session
+func (m *BreakGlassModel) RecordAction(id string) error {
+	_, err := m.db.Exec(`UPDATE break_glass_sessions SET action_count = action_count + 1 WHERE id = $1`, id)
+	return err
+}
+
+func scanBreakGlassCredential(row rowScanner) (*types.BreakGlassCredential, error) {
+	credential := &types.BreakGlassCredential{}
+	err := row.Scan(
+		&credential.ID, &credential.OrganizationID, &credential.UserID, &credential.UserEmail, &credential.Name,
+		&credential.CredentialHash, &credential.MFASecret, &credential.CreatedBy, &credential.CreatedAt,
+		&credential.RotatedAt, &credential.UsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+func scanBreakGlassSession(row rowScanner) (*types.BreakGlassSession, error) {
+	session := &types.BreakGlassSession{}
+	err := row.Scan(
+		&session.ID, &session.CredentialID, &session.OrganizationID, &session.UserID, &session.UserEmail,
+		&session.Reason, &session.RemoteIP, &session.StartedAt, &session.ExpiresAt, &session.EndedAt,
+		&session.EndedBy, &session.ActionCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
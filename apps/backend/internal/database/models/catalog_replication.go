@@ -0,0 +1,384 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrCatalogNameTaken is returned when a replicated entry's name is held by
+// a different local entry
+var ErrCatalogNameTaken = errors.New("catalog entry name is taken")
+
+// catalogTables maps replicated entity types to their tables
+var catalogTables = map[string]string{
+	types.CatalogEntityServer:    "mcp_servers",
+	types.CatalogEntityNamespace: "namespaces",
+	types.CatalogEntityEndpoint:  "endpoints",
+}
+
+// CatalogNameHolder is the local entry holding a name
+type CatalogNameHolder struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// CatalogReplicationModel reads the local catalog changes and applies those
+// of peer regions
+type CatalogReplicationModel struct {
+	BaseModel
+}
+
+// NewCatalogReplicationModel creates a new catalog replication model
+func NewCatalogReplicationModel(db Database) *CatalogReplicationModel {
+	return &CatalogReplicationModel{BaseModel: BaseModel{db: db}}
+}
+
+// ListChanges returns up to limit local changes after the change afterID
+func (m *CatalogReplicationModel) ListChanges(afterID int64, limit int) ([]types.CatalogChange, error) {
+	rows, err := m.db.Query(`
+		SELECT id, entity_type, entity_id, organization_id, operation, COALESCE(payload, 'null'::jsonb), version
+		FROM catalog_changes
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []types.CatalogChange{}
+	for rows.Next() {
+		var change types.CatalogChange
+		var payload []byte
+		if err := rows.Scan(&change.ID, &change.EntityType, &change.EntityID, &change.OrganizationID,
+			&change.Operation, &payload, &change.Version); err != nil {
+			return nil, err
+		}
+		if string(payload) != "null" {
+			change.Payload = payload
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// Apply applies a change made in region unless the local version of the
+// entry supersedes it. Local edits count as made in localRegion. It
+// returns ErrCatalogNameTaken when another local entry holds the name.
+func (m *CatalogReplicationModel) Apply(change *types.CatalogChange, region, localRegion string) (bool, error) {
+	table, ok := catalogTables[change.EntityType]
+	if !ok {
+		return false, fmt.Errorf("unknown catalog entity type %q", change.EntityType)
+	}
+
+	remote := types.CatalogVersion{
+		Version: change.Version,
+		Region:  region,
+		Deleted: change.Operation == types.CatalogOperationDelete,
+	}
+	applied := false
+	err := m.Transaction(func(tx *sql.Tx) error {
+		// Keep the triggers from logging the change as a local one
+		if _, err := tx.Exec(`SET LOCAL omnimesh.replicating = 'on'`); err != nil {
+			return err
+		}
+
+		local := types.CatalogVersion{}
+		err := tx.QueryRow(`
+			SELECT version, origin_region, deleted FROM catalog_versions
+			WHERE entity_type = $1 AND entity_id = $2
+			FOR UPDATE
+		`, change.EntityType, change.EntityID).Scan(&local.Version, &local.Region, &local.Deleted)
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return err
+		default:
+			if local.Region == "" {
+				local.Region = localRegion
+			}
+			if !remote.Supersedes(local) {
+				return nil
+			}
+		}
+
+		if remote.Deleted {
+			_, err = tx.Exec(`DELETE FROM `+table+` WHERE id = $1`, change.EntityID)
+		} else {
+			err = applyCatalogUpsert(tx, table, change)
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO catalog_versions (entity_type, entity_id, version, origin_region, deleted)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (entity_type, entity_id) DO UPDATE
+				SET version = EXCLUDED.version, origin_region = EXCLUDED.origin_region, deleted = EXCLUDED.deleted
+		`, change.EntityType, change.EntityID, remote.Version, remote.Region, remote.Deleted); err != nil {
+			return err
+		}
+		applied = true
+		return nil
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && !strings.HasSuffix(pgErr.ConstraintName, "_pkey") {
+		return false, ErrCatalogNameTaken
+	}
+	return applied, err
+}
+
+// applyCatalogUpsert inserts or updates an entry from its replicated row.
+// Columns missing from the row, such as a server's health status, keep
+// their local value. References to users are cleared when the user does
+// not exist in this region.
+func applyCatalogUpsert(tx *sql.Tx, table string, change *types.CatalogChange) error {
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(change.Payload, &row); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", change.EntityType, err)
+	}
+	members, hasMembers := row["servers"]
+	delete(row, "servers")
+
+	columns, err := catalogColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	query := catalogUpsertQuery(table, columns, row, "jsonb_populate_record", "", "id")
+	if _, err := tx.Exec(query, body); err != nil {
+		return err
+	}
+
+	if change.EntityType != types.CatalogEntityNamespace || !hasMembers {
+		return nil
+	}
+	return applyNamespaceMembers(tx, change.EntityID, members)
+}
+
+// applyNamespaceMembers replaces a namespace's server memberships. Servers
+// not replicated to this region yet are left out until the namespace
+// changes again.
+func applyNamespaceMembers(tx *sql.Tx, namespaceID string, members json.RawMessage) error {
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(members, &rows); err != nil {
+		return fmt.Errorf("invalid namespace servers: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM namespace_server_mappings m
+		WHERE m.namespace_id = $1 AND NOT EXISTS (
+			SELECT 1 FROM jsonb_populate_recordset(NULL::namespace_server_mappings, $2) r
+			WHERE r.server_id = m.server_id
+		)
+	`, namespaceID, []byte(members)); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	columns, err := catalogColumns(tx, "namespace_server_mappings")
+	if err != nil {
+		return err
+	}
+	query := catalogUpsertQuery("namespace_server_mappings", columns, rows[0], "jsonb_populate_recordset",
+		`WHERE EXISTS (SELECT 1 FROM mcp_servers s WHERE s.id = r.server_id)`, "namespace_id, server_id")
+	_, err = tx.Exec(query, []byte(members))
+	return err
+}
+
+// catalogUpsertQuery builds an upsert of the row's columns from a JSON
+// argument expanded by populate, keeping the rows matching where
+func catalogUpsertQuery(table string, columns map[string]bool, row map[string]json.RawMessage, populate, where, conflict string) string {
+	names := make([]string, 0, len(row))
+	for name := range row {
+		if _, ok := columns[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	values := make([]string, len(names))
+	updates := make([]string, 0, len(names))
+	for i, name := range names {
+		values[i] = "r." + name
+		if columns[name] {
+			values[i] = fmt.Sprintf("(SELECT u.id FROM users u WHERE u.id = r.%s)", name)
+		}
+		if name != "id" {
+			updates = append(updates, fmt.Sprintf("%[1]s = EXCLUDED.%[1]s", name))
+		}
+	}
+
+	return fmt.Sprintf(`INSERT INTO %[1]s (%[2]s) SELECT %[3]s FROM %[4]s(NULL::%[1]s, $1) r %[5]s ON CONFLICT (%[6]s) DO UPDATE SET %[7]s`,
+		table, strings.Join(names, ", "), strings.Join(values, ", "), populate, where, conflict, strings.Join(updates, ", "))
+}
+
+// catalogColumns returns a table's columns, each mapped to whether it
+// references a user
+func catalogColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query(`
+		SELECT a.attname, EXISTS (
+			SELECT 1 FROM pg_constraint c
+			WHERE c.conrelid = a.attrelid AND c.contype = 'f'
+				AND c.confrelid = 'users'::regclass AND a.attnum = ANY(c.conkey)
+		)
+		FROM pg_attribute a
+		WHERE a.attrelid = $1::regclass AND a.attnum > 0 AND NOT a.attisdropped AND a.attgenerated = ''
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		var referencesUser bool
+		if err := rows.Scan(&name, &referencesUser); err != nil {
+			return nil, err
+		}
+		columns[name] = referencesUser
+	}
+	return columns, rows.Err()
+}
+
+// FindNameHolder returns the local entry other than excludeID that holds
+// name, or nil. Server names are only reserved among active servers and
+// endpoint names across organizations.
+func (m *CatalogReplicationModel) FindNameHolder(entityType, orgID, name, excludeID string) (*CatalogNameHolder, error) {
+	var query string
+	args := []interface{}{name, excludeID}
+	switch entityType {
+	case types.CatalogEntityServer:
+		query = `SELECT id, created_at FROM mcp_servers WHERE name = $1 AND id <> $2 AND organization_id = $3 AND is_active = true`
+		args = append(args, orgID)
+	case types.CatalogEntityNamespace:
+		query = `SELECT id, created_at FROM namespaces WHERE name = $1 AND id <> $2 AND organization_id = $3`
+		args = append(args, orgID)
+	case types.CatalogEntityEndpoint:
+		query = `SELECT id, created_at FROM endpoints WHERE name = $1 AND id <> $2`
+	default:
+		return nil, fmt.Errorf("unknown catalog entity type %q", entityType)
+	}
+
+	holder := &CatalogNameHolder{}
+	err := m.db.QueryRow(query, args...).Scan(&holder.ID, &holder.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return holder, nil
+}
+
+// Rename renames a local entry. The rename is a local edit and replicates
+// to the peers.
+func (m *CatalogReplicationModel) Rename(entityType, id, name string) error {
+	table, ok := catalogTables[entityType]
+	if !ok {
+		return fmt.Errorf("unknown catalog entity type %q", entityType)
+	}
+	_, err := m.db.Exec(`UPDATE `+table+` SET name = $2 WHERE id = $1`, id, name)
+	return err
+}
+
+// PeerCursor returns the id of the last change applied from a peer region
+func (m *CatalogReplicationModel) PeerCursor(region string) (int64, error) {
+	var cursor int64
+	err := m.db.QueryRow(`SELECT last_change_id FROM catalog_replication_peers WHERE region = $1`, region).Scan(&cursor)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return cursor, err
+}
+
+// RecordPull saves the progress of a pull from a peer region
+func (m *CatalogReplicationModel) RecordPull(region string, cursor int64, applied, superseded int, pulledAt time.Time, pullErr string) error {
+	_, err := m.db.Exec(`
+		INSERT INTO catalog_replication_peers (region, last_change_id, applied_count, superseded_count,
+			last_pulled_at, last_applied_at, last_error)
+		VALUES ($1, $2, $3, $4, $5, CASE WHEN $3 > 0 THEN $5::timestamptz END, $6)
+		ON CONFLICT (region) DO UPDATE SET
+			last_change_id = EXCLUDED.last_change_id,
+			applied_count = catalog_replication_peers.applied_count + EXCLUDED.applied_count,
+			superseded_count = catalog_replication_peers.superseded_count + EXCLUDED.superseded_count,
+			last_pulled_at = EXCLUDED.last_pulled_at,
+			last_applied_at = COALESCE(EXCLUDED.last_applied_at, catalog_replication_peers.last_applied_at),
+			last_error = EXCLUDED.last_error
+	`, region, cursor, applied, superseded, pulledAt, pullErr)
+	return err
+}
+
+// ListPeers returns the replication progress from every peer pulled so far
+func (m *CatalogReplicationModel) ListPeers() ([]types.RegionPeerStatus, error) {
+	rows, err := m.db.Query(`
+		SELECT region, last_change_id, applied_count, superseded_count, last_pulled_at, last_applied_at, last_error
+		FROM catalog_replication_peers
+		ORDER BY region
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	peers := []types.RegionPeerStatus{}
+	for rows.Next() {
+		var peer types.RegionPeerStatus
+		var pulledAt, appliedAt sql.NullTime
+		if err := rows.Scan(&peer.Region, &peer.LastChangeID, &peer.AppliedCount, &peer.SupersededCount,
+			&pulledAt, &appliedAt, &peer.LastError); err != nil {
+			return nil, err
+		}
+		if pulledAt.Valid {
+			peer.LastPulledAt = &pulledAt.Time
+		}
+		if appliedAt.Valid {
+			peer.LastAppliedAt = &appliedAt.Time
+		}
+		peers = append(peers, peer)
+	}
+	return peers, rows.Err()
+}
+
+// ListEndpointRegions returns the organization's active endpoint names
+// with the region their namespace is pinned to, empty when unpinned
+func (m *CatalogReplicationModel) ListEndpointRegions(orgID string) (map[string]string, error) {
+	rows, err := m.db.Query(`
+		SELECT e.name, n.region
+		FROM endpoints e
+		JOIN namespaces n ON n.id = e.namespace_id
+		WHERE e.organization_id = $1 AND e.is_active = true
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	regions := make(map[string]string)
+	for rows.Next() {
+		var name, region string
+		if err := rows.Scan(&name, &region); err != nil {
+			return nil, err
+		}
+		regions[name] = region
+	}
+	return regions, rows.Err()
+}
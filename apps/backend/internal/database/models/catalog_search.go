@@ -0,0 +1,339 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// catalogSource describes how one catalog table is searched
+type catalogSource struct {
+	itemType string
+	table    string
+	category string // expression of the entry's category
+	usage    string // expression of the entry's usage count
+	server   string // expression of the entry's server
+	text     string // expression of the text that is embedded
+}
+
+var catalogSources = []catalogSource{
+	{
+		itemType: types.SearchTypeTool,
+		table:    "mcp_tools",
+		category: "t.category::TEXT",
+		usage:    "COALESCE(t.usage_count, 0)",
+		server:   "COALESCE(t.server_id::TEXT, '')",
+		text: `t.name || ' ' || t.function_name || ' ' || COALESCE(t.description, '') || ' ' ||
+			COALESCE(array_to_string(t.tags, ' '), '')`,
+	},
+	{
+		itemType: types.SearchTypePrompt,
+		table:    "mcp_prompts",
+		category: "t.category::TEXT",
+		usage:    "COALESCE(t.usage_count, 0)",
+		server:   "''",
+		text: `t.name || ' ' || COALESCE(t.description, '') || ' ' ||
+			COALESCE(array_to_string(t.tags, ' '), '')`,
+	},
+	{
+		itemType: types.SearchTypeResource,
+		table:    "mcp_resources",
+		category: "t.resource_type::TEXT",
+		usage:    "0::BIGINT",
+		server:   "''",
+		text: `t.name || ' ' || COALESCE(t.description, '') || ' ' || t.uri || ' ' ||
+			COALESCE(array_to_string(t.tags, ' '), '')`,
+	},
+}
+
+// CatalogSearchModel searches an organization's tools, prompts and
+// resources and keeps their embeddings
+type CatalogSearchModel struct {
+	db Database
+
+	pgvectorOnce sync.Once
+	pgvector     bool
+}
+
+// NewCatalogSearchModel creates a new catalog search model
+func NewCatalogSearchModel(db Database) *CatalogSearchModel {
+	return &CatalogSearchModel{db: db}
+}
+
+// Search ranks the catalog entries matching the full-text query, and
+// returns a page of them with the number of matches. prefix is an optional
+// tsquery in the simple configuration OR'd with the query, so partially
+// typed names match.
+func (m *CatalogSearchModel) Search(orgID string, query *types.SearchQuery, prefix string) ([]*types.SearchResult, int, error) {
+	args := []interface{}{}
+	catalog := catalogQuery(orgID, query, &args)
+	if catalog == "" {
+		return []*types.SearchResult{}, 0, nil
+	}
+
+	args = append(args, query.Query)
+	tsquery := fmt.Sprintf("websearch_to_tsquery('english', $%d)", len(args))
+	if prefix != "" {
+		args = append(args, prefix)
+		tsquery += fmt.Sprintf(" || to_tsquery('simple', $%d)", len(args))
+	}
+	args = append(args, query.Limit, query.Offset)
+
+	rows, err := m.db.Query(`
+		SELECT c.item_type, c.id, c.name, c.description, c.category, c.tags, c.server_id, c.usage_count,
+			ts_rank_cd(c.search_vector, q.query) AS score, COUNT(*) OVER () AS total
+		FROM (`+catalog+`) c, (SELECT `+tsquery+` AS query) q
+		WHERE c.search_vector @@ q.query
+		ORDER BY score DESC, c.usage_count DESC, c.name
+		LIMIT $`+strconv.Itoa(len(args)-1)+` OFFSET $`+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []*types.SearchResult{}
+	total := 0
+	for rows.Next() {
+		result := &types.SearchResult{}
+		if err := rows.Scan(&result.Type, &result.ID, &result.Name, &result.Description, &result.Category,
+			pq.Array(&result.Tags), &result.ServerID, &result.UsageCount, &result.Score, &total); err != nil {
+			return nil, 0, err
+		}
+		results = append(results, result)
+	}
+	return results, total, rows.Err()
+}
+
+// SemanticSearch returns the limit catalog entries matching the query's
+// filters whose embeddings by model are most similar to vector. Where the
+// pgvector extension is installed the database ranks them; otherwise they
+// are ranked here.
+func (m *CatalogSearchModel) SemanticSearch(orgID string, query *types.SearchQuery, model string, vector []float32, limit int) ([]*types.SearchResult, error) {
+	args := []interface{}{}
+	catalog := catalogQuery(orgID, query, &args)
+	if catalog == "" || len(vector) == 0 {
+		return []*types.SearchResult{}, nil
+	}
+	args = append(args, model)
+	modelParam := len(args)
+
+	if m.hasPgvector() {
+		args = append(args, vectorLiteral(vector), limit)
+		rows, err := m.db.Query(fmt.Sprintf(`
+			SELECT c.item_type, c.id, c.name, c.description, c.category, c.tags, c.server_id, c.usage_count,
+				1 - (e.embedding::vector <=> $%d::vector) AS score
+			FROM (%s) c
+			JOIN catalog_embeddings e ON e.item_type = c.item_type AND e.item_id::TEXT = c.id
+			WHERE e.model = $%d AND array_length(e.embedding, 1) = %d
+			ORDER BY score DESC
+			LIMIT $%d
+		`, modelParam+1, catalog, modelParam, len(vector), modelParam+2), args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		results := []*types.SearchResult{}
+		for rows.Next() {
+			result := &types.SearchResult{}
+			if err := rows.Scan(&result.Type, &result.ID, &result.Name, &result.Description, &result.Category,
+				pq.Array(&result.Tags), &result.ServerID, &result.UsageCount, &result.Score); err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		return results, rows.Err()
+	}
+
+	rows, err := m.db.Query(fmt.Sprintf(`
+		SELECT c.item_type, c.id, c.name, c.description, c.category, c.tags, c.server_id, c.usage_count,
+			e.embedding
+		FROM (%s) c
+		JOIN catalog_embeddings e ON e.item_type = c.item_type AND e.item_id::TEXT = c.id
+		WHERE e.model = $%d
+	`, catalog, modelParam), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*types.SearchResult{}
+	for rows.Next() {
+		result := &types.SearchResult{}
+		var embedding pq.Float32Array
+		if err := rows.Scan(&result.Type, &result.ID, &result.Name, &result.Description, &result.Category,
+			pq.Array(&result.Tags), &result.ServerID, &result.UsageCount, &embedding); err != nil {
+			return nil, err
+		}
+		if len(embedding) != len(vector) {
+			continue
+		}
+		result.Score = CosineSimilarity(vector, embedding)
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// ListEmbeddingCandidates returns up to limit active catalog entries that
+// have no embedding by model, or whose text changed since it was embedded
+func (m *CatalogSearchModel) ListEmbeddingCandidates(model string, limit int) ([]*types.SearchDocument, error) {
+	var branches []string
+	for _, source := range catalogSources {
+		branches = append(branches, fmt.Sprintf(`
+			SELECT '%s' AS item_type, t.id, t.organization_id, d.text, md5(d.text) AS content_hash
+			FROM %s t
+			CROSS JOIN LATERAL (SELECT %s AS text) d
+			LEFT JOIN catalog_embeddings e ON e.item_type = '%s' AND e.item_id = t.id
+			WHERE t.is_active = true
+			AND (e.item_id IS NULL OR e.model <> $1 OR e.content_hash <> md5(d.text))
+		`, source.itemType, source.table, source.text, source.itemType))
+	}
+
+	rows, err := m.db.Query(`SELECT * FROM (`+strings.Join(branches, " UNION ALL ")+`) c LIMIT $2`, model, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []*types.SearchDocument{}
+	for rows.Next() {
+		document := &types.SearchDocument{}
+		if err := rows.Scan(&document.Type, &document.ID, &document.OrganizationID, &document.Text,
+			&document.ContentHash); err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
+
+// UpsertEmbedding stores the embedding of a catalog entry by model
+func (m *CatalogSearchModel) UpsertEmbedding(document *types.SearchDocument, model string, embedding []float32) error {
+	_, err := m.db.Exec(`
+		INSERT INTO catalog_embeddings (item_type, item_id, organization_id, model, content_hash, embedding)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (item_type, item_id) DO UPDATE SET
+			organization_id = EXCLUDED.organization_id,
+			model = EXCLUDED.model,
+			content_hash = EXCLUDED.content_hash,
+			embedding = EXCLUDED.embedding,
+			updated_at = NOW()
+	`, document.Type, document.ID, document.OrganizationID, model, document.ContentHash,
+		pq.Float32Array(embedding))
+	return err
+}
+
+// hasPgvector reports whether the pgvector extension is installed. It is
+// checked once.
+func (m *CatalogSearchModel) hasPgvector() bool {
+	m.pgvectorOnce.Do(func() {
+		if err := m.db.QueryRow(
+			`SELECT EXISTS(SELECT 1 FROM pg_extension WHERE extname = 'vector')`,
+		).Scan(&m.pgvector); err != nil {
+			m.pgvector = false
+		}
+	})
+	return m.pgvector
+}
+
+// catalogQuery returns the union of the organization's active catalog
+// entries matching the query's filters, appending its arguments to args.
+// It is empty when no searched type can match.
+func catalogQuery(orgID string, query *types.SearchQuery, args *[]interface{}) string {
+	param := func(value interface{}) string {
+		*args = append(*args, value)
+		return "$" + strconv.Itoa(len(*args))
+	}
+	org := param(orgID)
+	var category, tags, server, namespace string
+
+	var branches []string
+	for _, source := range catalogSources {
+		if len(query.Types) > 0 && !slices.Contains(query.Types, source.itemType) {
+			continue
+		}
+		// Only tools belong to servers
+		if (query.ServerID != "" || query.NamespaceID != "") && source.itemType != types.SearchTypeTool {
+			continue
+		}
+
+		where := []string{"t.organization_id = " + org, "t.is_active = true"}
+		if query.Category != "" {
+			if category == "" {
+				category = param(query.Category)
+			}
+			where = append(where, source.category+" = "+category)
+		}
+		if len(query.Tags) > 0 {
+			if tags == "" {
+				tags = param(pq.Array(query.Tags))
+			}
+			where = append(where, "t.tags @> "+tags)
+		}
+		if query.ServerID != "" {
+			server = param(query.ServerID)
+			where = append(where, "t.server_id = "+server)
+		}
+		if query.NamespaceID != "" {
+			namespace = param(query.NamespaceID)
+			where = append(where, `t.server_id IN (
+				SELECT m.server_id FROM namespace_server_mappings m
+				JOIN namespaces n ON n.id = m.namespace_id
+				WHERE m.namespace_id = `+namespace+` AND n.organization_id = `+org+` AND m.status = 'ACTIVE'
+			)`, `NOT EXISTS (
+				SELECT 1 FROM namespace_tool_mappings tm
+				WHERE tm.namespace_id = `+namespace+` AND tm.server_id = t.server_id
+				AND tm.tool_name = t.name AND tm.status = 'INACTIVE'
+			)`)
+		}
+
+		branches = append(branches, fmt.Sprintf(`
+			SELECT '%s'::TEXT AS item_type, t.id::TEXT AS id, t.name, COALESCE(t.description, '') AS description,
+				%s AS category, COALESCE(t.tags, '{}') AS tags, %s AS server_id, %s AS usage_count, t.search_vector
+			FROM %s t
+			WHERE %s
+		`, source.itemType, source.category, source.server, source.usage, source.table,
+			strings.Join(where, " AND ")))
+	}
+	return strings.Join(branches, " UNION ALL ")
+}
+
+// vectorLiteral formats an embedding as a pgvector literal
+func vectorLiteral(vector []float32) string {
+	values := make([]string, len(vector))
+	for i, v := range vector {
+		values[i] = strconv.FormatFloat(float64(v), 'g', -1, 32)
+	}
+	return "[" + strings.Join(values, ",") + "]"
+}
+
+// CosineSimilarity returns the cosine similarity of two equally long
+// vectors, or 0 when either is zero
+func CosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
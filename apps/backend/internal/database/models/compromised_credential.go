@@ -0,0 +1,120 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// CompromisedCredentialModel revokes leaked credentials and records the
+// reports that revoked them
+type CompromisedCredentialModel struct {
+	db Database
+}
+
+// NewCompromisedCredentialModel creates a new compromised credential model
+func NewCompromisedCredentialModel(db Database) *CompromisedCredentialModel {
+	return &CompromisedCredentialModel{db: db}
+}
+
+// RevokeAPIKeyByHash deactivates the active API key with a hash. An empty
+// orgID matches keys of every organization. It returns nil when no key was
+// revoked.
+func (m *CompromisedCredentialModel) RevokeAPIKeyByHash(orgID, keyHash string) (*types.CompromisedCredential, error) {
+	return m.revokeAPIKey(`key_hash = $1`, orgID, keyHash)
+}
+
+// RevokeAPIKeyByID deactivates an active API key of the organization. It
+// returns nil when no key was revoked.
+func (m *CompromisedCredentialModel) RevokeAPIKeyByID(orgID, id string) (*types.CompromisedCredential, error) {
+	return m.revokeAPIKey(`id = $1`, orgID, id)
+}
+
+func (m *CompromisedCredentialModel) revokeAPIKey(condition, orgID, value string) (*types.CompromisedCredential, error) {
+	credential := &types.CompromisedCredential{CredentialType: types.CompromisedAPIKey}
+	err := m.db.QueryRow(`
+		UPDATE api_keys SET is_active = false
+		WHERE `+condition+` AND is_active = true AND ($2 = '' OR organization_id::text = $2)
+		RETURNING id, user_id, organization_id, name
+	`, value, orgID).Scan(&credential.CredentialID, &credential.UserID, &credential.OrganizationID, &credential.CredentialName)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// RevokeOAuthToken revokes the unrevoked OAuth token with a hash together
+// with the tokens refreshed from it. An empty orgID matches tokens of every
+// organization. It returns nil when no token was revoked.
+func (m *CompromisedCredentialModel) RevokeOAuthToken(orgID, tokenHash string) (*types.CompromisedCredential, error) {
+	credential := &types.CompromisedCredential{CredentialType: types.CompromisedOAuthToken}
+	err := m.db.QueryRow(`
+		UPDATE oauth_tokens t SET revoked_at = NOW()
+		FROM oauth_clients c
+		WHERE c.client_id = t.client_id AND t.token_hash = $1 AND t.revoked_at IS NULL
+		  AND ($2 = '' OR c.organization_id::text = $2)
+		RETURNING t.id, COALESCE(t.user_id::text, ''), c.organization_id, c.client_name
+	`, tokenHash, orgID).Scan(&credential.CredentialID, &credential.UserID, &credential.OrganizationID, &credential.CredentialName)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := m.db.Exec(`
+		UPDATE oauth_tokens SET revoked_at = NOW()
+		WHERE parent_token_id = $1 AND revoked_at IS NULL
+	`, credential.CredentialID); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// Create records a revoked credential
+func (m *CompromisedCredentialModel) Create(credential *types.CompromisedCredential) error {
+	if credential.ID == "" {
+		credential.ID = uuid.New().String()
+	}
+	return m.db.QueryRow(`
+		INSERT INTO compromised_credentials (id, organization_id, user_id, credential_type, credential_id,
+			credential_name, fingerprint, source, reporter, reason)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at
+	`, credential.ID, credential.OrganizationID, credential.UserID, credential.CredentialType, credential.CredentialID,
+		credential.CredentialName, credential.Fingerprint, credential.Source, credential.Reporter, credential.Reason,
+	).Scan(&credential.CreatedAt)
+}
+
+// List returns the organization's revoked credentials, newest first
+func (m *CompromisedCredentialModel) List(orgID string) ([]*types.CompromisedCredential, error) {
+	rows, err := m.db.Query(`
+		SELECT id, organization_id, COALESCE(user_id::text, ''), credential_type, credential_id,
+			credential_name, fingerprint, source, reporter, reason, created_at
+		FROM compromised_credentials
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT 500
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credentials := []*types.CompromisedCredential{}
+	for rows.Next() {
+		credential := &types.CompromisedCredential{}
+		if err := rows.Scan(&credential.ID, &credential.OrganizationID, &credential.UserID, &credential.CredentialType,
+			&credential.CredentialID, &credential.CredentialName, &credential.Fingerprint, &credential.Source,
+			&credential.Reporter, &credential.Reason, &credential.CreatedAt); err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, credential)
+	}
+	return credentials, rows.Err()
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// DelegationPolicyModel handles the delegation policies of service accounts
+type DelegationPolicyModel struct {
+	db Database
+}
+
+// NewDelegationPolicyModel creates a new delegation policy model
+func NewDelegationPolicyModel(db Database) *DelegationPolicyModel {
+	return &DelegationPolicyModel{db: db}
+}
+
+// Get returns the delegation policy of a service account of the
+// organization, or nil when it has none
+func (m *DelegationPolicyModel) Get(orgID, serviceAccountID string) (*types.DelegationPolicy, error) {
+	policy := &types.DelegationPolicy{}
+	err := m.db.QueryRow(`
+		SELECT service_account_id, organization_id, allowed_roles, allowed_user_ids,
+			COALESCE(created_by::text, ''), created_at, updated_at
+		FROM delegation_policies
+		WHERE service_account_id = $1 AND organization_id = $2
+	`, serviceAccountID, orgID).Scan(&policy.ServiceAccountID, &policy.OrganizationID,
+		pq.Array(&policy.AllowedRoles), pq.Array(&policy.AllowedUserIDs),
+		&policy.CreatedBy, &policy.CreatedAt, &policy.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Set creates or replaces the delegation policy of a service account. It
+// reports false, saving nothing, when the organization has no such active
+// service account.
+func (m *DelegationPolicyModel) Set(policy *types.DelegationPolicy) (bool, error) {
+	err := m.db.QueryRow(`
+		INSERT INTO delegation_policies (service_account_id, organization_id, allowed_roles, allowed_user_ids, created_by)
+		SELECT u.id, u.organization_id, $3, $4, NULLIF($5, '')::uuid
+		FROM users u
+		WHERE u.id = $1 AND u.organization_id = $2 AND u.account_type = 'service' AND u.retired_at IS NULL
+		ON CONFLICT (service_account_id) DO UPDATE
+		SET allowed_roles = EXCLUDED.allowed_roles, allowed_user_ids = EXCLUDED.allowed_user_ids, updated_at = NOW()
+		RETURNING COALESCE(created_by::text, ''), created_at, updated_at
+	`, policy.ServiceAccountID, policy.OrganizationID, pq.Array(policy.AllowedRoles), pq.Array(policy.AllowedUserIDs),
+		policy.CreatedBy).Scan(&policy.CreatedBy, &policy.CreatedAt, &policy.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete removes the delegation policy of a service account
+func (m *DelegationPolicyModel) Delete(orgID, serviceAccountID string) (bool, error) {
+	result, err := m.db.Exec(`
+		DELETE FROM delegation_policies WHERE service_account_id = $1 AND organization_id = $2
+	`, serviceAccountID, orgID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// FindUser returns the user of the organization with the given ID or
+// email, or nil when there is none
+func (m *DelegationPolicyModel) FindUser(orgID, subject string) (*types.User, error) {
+	column := "id::text"
+	if strings.Contains(subject, "@") {
+		column = "LOWER(email)"
+		subject = strings.ToLower(subject)
+	}
+	user := &types.User{}
+	err := m.db.QueryRow(`
+		SELECT id, email, name, organization_id, role, account_type, COALESCE(is_active, true)
+		FROM users
+		WHERE organization_id = $1 AND `+column+` = $2
+	`, orgID, subject).Scan(&user.ID, &user.Email, &user.Name, &user.OrganizationID,
+		&user.Role, &user.AccountType, &user.IsActive)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// DeploymentModel stores the deployment's role and its failovers
+type DeploymentModel struct {
+	BaseModel
+}
+
+// NewDeploymentModel creates a new deployment model
+func NewDeploymentModel(db Database) *DeploymentModel {
+	return &DeploymentModel{BaseModel: BaseModel{db: db}}
+}
+
+// GetState returns the stored deployment state, or nil before the first
+// failover or fence
+func (m *DeploymentModel) GetState() (*types.DeploymentState, error) {
+	var state types.DeploymentState
+	var changedAt sql.NullTime
+	err := m.db.QueryRow(`
+		SELECT role, read_only, read_only_reason, fenced_by, epoch, changed_by, changed_at
+		FROM deployment_state
+	`).Scan(&state.Role, &state.ReadOnly, &state.ReadOnlyReason, &state.FencedBy, &state.Epoch,
+		&state.ChangedBy, &changedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if changedAt.Valid {
+		state.ChangedAt = &changedAt.Time
+	}
+	return &state, nil
+}
+
+// SetState stores the deployment's role under the next epoch and returns
+// the stored state
+func (m *DeploymentModel) SetState(state *types.DeploymentState) (*types.DeploymentState, error) {
+	var changedAt sql.NullTime
+	err := m.db.QueryRow(`
+		INSERT INTO deployment_state (id, role, read_only, read_only_reason, fenced_by, epoch, changed_by, changed_at)
+		VALUES (true, $1, $2, $3, $4, 1, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			role = EXCLUDED.role,
+			read_only = EXCLUDED.read_only,
+			read_only_reason = EXCLUDED.read_only_reason,
+			fenced_by = EXCLUDED.fenced_by,
+			epoch = deployment_state.epoch + 1,
+			changed_by = EXCLUDED.changed_by,
+			changed_at = EXCLUDED.changed_at
+		RETURNING epoch, changed_at
+	`, state.Role, state.ReadOnly, state.ReadOnlyReason, state.FencedBy, state.ChangedBy, state.ChangedAt,
+	).Scan(&state.Epoch, &changedAt)
+	if err != nil {
+		return nil, err
+	}
+	if changedAt.Valid {
+		state.ChangedAt = &changedAt.Time
+	}
+	return state, nil
+}
+
+// CreateFailover records the start of a failover and sets its ID
+func (m *DeploymentModel) CreateFailover(failover *types.Failover) error {
+	steps, err := json.Marshal(failover.Steps)
+	if err != nil {
+		return err
+	}
+	return m.db.QueryRow(`
+		INSERT INTO region_failovers (from_region, to_region, reason, initiated_by, dry_run, status, steps, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, failover.FromRegion, failover.ToRegion, failover.Reason, failover.InitiatedBy, failover.DryRun,
+		failover.Status, steps, failover.StartedAt).Scan(&failover.ID)
+}
+
+// UpdateFailover records the steps and outcome of a failover
+func (m *DeploymentModel) UpdateFailover(failover *types.Failover) error {
+	steps, err := json.Marshal(failover.Steps)
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec(`
+		UPDATE region_failovers SET status = $2, steps = $3, completed_at = $4
+		WHERE id = $1
+	`, failover.ID, failover.Status, steps, failover.CompletedAt)
+	return err
+}
+
+// ListFailovers returns the most recent failovers first
+func (m *DeploymentModel) ListFailovers(limit int) ([]*types.Failover, error) {
+	rows, err := m.db.Query(`
+		SELECT id, from_region, to_region, reason, initiated_by, dry_run, status, steps, started_at, completed_at
+		FROM region_failovers
+		ORDER BY started_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	failovers := []*types.Failover{}
+	for rows.Next() {
+		var failover types.Failover
+		var steps []byte
+		var completedAt sql.NullTime
+		if err := rows.Scan(&failover.ID, &failover.FromRegion, &failover.ToRegion, &failover.Reason,
+			&failover.InitiatedBy, &failover.DryRun, &failover.Status, &steps, &failover.StartedAt,
+			&completedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(steps, &failover.Steps); err != nil {
+			return nil, err
+		}
+		if completedAt.Valid {
+			failover.CompletedAt = &completedAt.Time
+		}
+		failovers = append(failovers, &failover)
+	}
+	return failovers, rows.Err()
+}
+
+// PlatformOrganization returns the organization that records
+// deployment-wide audit events: the default organization, or the oldest
+// one when there is no default
+func (m *DeploymentModel) PlatformOrganization() (string, error) {
+	var orgID string
+	err := m.db.QueryRow(`
+		SELECT id FROM organizations
+		ORDER BY (slug = 'default') DESC, created_at
+		LIMIT 1
+	`).Scan(&orgID)
+	return orgID, err
+}
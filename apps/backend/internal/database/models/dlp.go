@@ -0,0 +1,113 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// DLPModel stores the DLP policies of servers and the calls they caught
+type DLPModel struct {
+	db Database
+}
+
+// NewDLPModel creates a new DLP model
+func NewDLPModel(db Database) *DLPModel {
+	return &DLPModel{db: db}
+}
+
+// ServerExists reports whether a server belongs to the organization
+func (m *DLPModel) ServerExists(orgID, serverID string) (bool, error) {
+	var exists bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM mcp_servers WHERE id = $1 AND organization_id = $2)
+	`, serverID, orgID).Scan(&exists)
+	return exists, err
+}
+
+// GetPolicy returns the DLP policy of a server, or nil when it has none
+func (m *DLPModel) GetPolicy(serverID string) (*types.DLPPolicy, error) {
+	policy := &types.DLPPolicy{ServerID: serverID}
+	var updatedAt time.Time
+	err := m.db.QueryRow(`
+		SELECT sensitivity, action, updated_at FROM server_dlp_policies WHERE server_id = $1
+	`, serverID).Scan(&policy.Sensitivity, &policy.Action, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	policy.UpdatedAt = &updatedAt
+	return policy, nil
+}
+
+// UpsertPolicy replaces the DLP policy of a server
+func (m *DLPModel) UpsertPolicy(policy *types.DLPPolicy) error {
+	_, err := m.db.Exec(`
+		INSERT INTO server_dlp_policies (server_id, sensitivity, action)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (server_id) DO UPDATE
+		SET sensitivity = EXCLUDED.sensitivity, action = EXCLUDED.action
+	`, policy.ServerID, policy.Sensitivity, policy.Action)
+	return err
+}
+
+// DeletePolicy removes the DLP policy of a server and reports whether it
+// had one
+func (m *DLPModel) DeletePolicy(serverID string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM server_dlp_policies WHERE server_id = $1`, serverID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CreateEvent records a call caught by a DLP policy
+func (m *DLPModel) CreateEvent(event *types.DLPEvent) error {
+	findings, err := json.Marshal(event.Findings)
+	if err != nil {
+		return err
+	}
+	return m.db.QueryRow(`
+		INSERT INTO dlp_events (organization_id, namespace_id, server_id, tool, action, findings, user_id, api_key_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid, NULLIF($8, '')::uuid)
+		RETURNING id, created_at
+	`, event.OrganizationID, event.NamespaceID, event.ServerID, event.Tool, event.Action, findings,
+		event.UserID, event.APIKeyID).Scan(&event.ID, &event.CreatedAt)
+}
+
+// ListEvents returns the organization's most recent DLP events, of one
+// server when serverID is set
+func (m *DLPModel) ListEvents(orgID, serverID string, limit int) ([]*types.DLPEvent, error) {
+	rows, err := m.db.Query(`
+		SELECT id, organization_id, namespace_id, server_id, tool, action, findings,
+			COALESCE(user_id::text, ''), COALESCE(api_key_id::text, ''), created_at
+		FROM dlp_events
+		WHERE organization_id = $1 AND ($2 = '' OR server_id::text = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, orgID, serverID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*types.DLPEvent{}
+	for rows.Next() {
+		event := &types.DLPEvent{}
+		var findings []byte
+		if err := rows.Scan(&event.ID, &event.OrganizationID, &event.NamespaceID, &event.ServerID, &event.Tool,
+			&event.Action, &findings, &event.UserID, &event.APIKeyID, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(findings, &event.Findings); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// EndpointGeoPolicyModel stores the geo policies of endpoints and their
+// decisions
+type EndpointGeoPolicyModel struct {
+	db Database
+}
+
+// NewEndpointGeoPolicyModel creates a new endpoint geo policy model
+func NewEndpointGeoPolicyModel(db Database) *EndpointGeoPolicyModel {
+	return &EndpointGeoPolicyModel{db: db}
+}
+
+// EndpointExists reports whether an endpoint belongs to the organization
+func (m *EndpointGeoPolicyModel) EndpointExists(orgID, endpointID string) (bool, error) {
+	var exists bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM endpoints WHERE id = $1 AND organization_id = $2)
+	`, endpointID, orgID).Scan(&exists)
+	return exists, err
+}
+
+// GetPolicy returns the geo policy of an endpoint, or nil when it has none
+func (m *EndpointGeoPolicyModel) GetPolicy(endpointID string) (*types.EndpointGeoPolicy, error) {
+	policy := &types.EndpointGeoPolicy{EndpointID: endpointID}
+	var createdAt, updatedAt time.Time
+	err := m.db.QueryRow(`
+		SELECT allow_countries, deny_countries, allow_asns, deny_asns, unknown_action, log_allowed,
+			created_at, updated_at
+		FROM endpoint_geo_policies WHERE endpoint_id = $1
+	`, endpointID).Scan(pq.Array(&policy.AllowCountries), pq.Array(&policy.DenyCountries),
+		pq.Array(&policy.AllowASNs), pq.Array(&policy.DenyASNs), &policy.UnknownAction, &policy.LogAllowed,
+		&createdAt, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	policy.CreatedAt, policy.UpdatedAt = &createdAt, &updatedAt
+	return policy, nil
+}
+
+// UpsertPolicy replaces the geo policy of an endpoint
+func (m *EndpointGeoPolicyModel) UpsertPolicy(policy *types.EndpointGeoPolicy) error {
+	_, err := m.db.Exec(`
+		INSERT INTO endpoint_geo_policies
+			(endpoint_id, allow_countries, deny_countries, allow_asns, deny_asns, unknown_action, log_allowed)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (endpoint_id) DO UPDATE
+		SET allow_countries = EXCLUDED.allow_countries, deny_countries = EXCLUDED.deny_countries,
+			allow_asns = EXCLUDED.allow_asns, deny_asns = EXCLUDED.deny_asns,
+			unknown_action = EXCLUDED.unknown_action, log_allowed = EXCLUDED.log_allowed
+	`, policy.EndpointID, pq.Array(policy.AllowCountries), pq.Array(policy.DenyCountries),
+		pq.Array(policy.AllowASNs), pq.Array(policy.DenyASNs), policy.UnknownAction, policy.LogAllowed)
+	return err
+}
+
+// DeletePolicy removes the geo policy of an endpoint and reports whether it
+// had one
+func (m *EndpointGeoPolicyModel) DeletePolicy(endpointID string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM endpoint_geo_policies WHERE endpoint_id = $1`, endpointID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CreateDecision records a geo policy decision
+func (m *EndpointGeoPolicyModel) CreateDecision(event *types.GeoDecisionEvent) error {
+	return m.db.QueryRow(`
+		INSERT INTO endpoint_geo_decisions
+			(organization_id, endpoint_id, ip, country, asn, as_organization, allowed, rule, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`, event.OrganizationID, event.EndpointID, event.IP, event.Country, event.ASN, event.ASOrganization,
+		event.Allowed, event.Rule, event.Reason).Scan(&event.ID, &event.CreatedAt)
+}
+
+// ListDecisions returns the most recent decisions for an endpoint, only
+// denials when deniedOnly
+func (m *EndpointGeoPolicyModel) ListDecisions(endpointID string, deniedOnly bool, limit int) ([]*types.GeoDecisionEvent, error) {
+	rows, err := m.db.Query(`
+		SELECT id, organization_id, endpoint_id, host(ip), country, asn, as_organization, allowed, rule, reason,
+			created_at
+		FROM endpoint_geo_decisions
+		WHERE endpoint_id = $1 AND (NOT $2 OR NOT allowed)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, endpointID, deniedOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*types.GeoDecisionEvent{}
+	for rows.Next() {
+		event := &types.GeoDecisionEvent{}
+		if err := rows.Scan(&event.ID, &event.OrganizationID, &event.EndpointID, &event.IP, &event.Country,
+			&event.ASN, &event.ASOrganization, &event.Allowed, &event.Rule, &event.Reason, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
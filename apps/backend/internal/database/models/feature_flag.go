@@ -0,0 +1,91 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// FeatureFlagModel handles feature flag database operations
+type FeatureFlagModel struct {
+	db Database
+}
+
+// NewFeatureFlagModel creates a new feature flag model
+func NewFeatureFlagModel(db Database) *FeatureFlagModel {
+	return &FeatureFlagModel{db: db}
+}
+
+// List returns every stored flag value, global and per organization
+func (m *FeatureFlagModel) List() ([]*types.FeatureFlagOverride, error) {
+	query := `
+		SELECT id, key, organization_id, enabled, updated_by, created_at, updated_at
+		FROM feature_flags
+		ORDER BY key, organization_id NULLS FIRST
+	`
+
+	rows, err := m.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []*types.FeatureFlagOverride
+	for rows.Next() {
+		override := &types.FeatureFlagOverride{}
+		var orgID, updatedBy sql.NullString
+		if err := rows.Scan(&override.ID, &override.Key, &orgID, &override.Enabled,
+			&updatedBy, &override.CreatedAt, &override.UpdatedAt); err != nil {
+			return nil, err
+		}
+		override.OrganizationID = orgID.String
+		override.UpdatedBy = updatedBy.String
+		overrides = append(overrides, override)
+	}
+
+	return overrides, rows.Err()
+}
+
+// Upsert stores a flag value, replacing any existing value for the same scope
+func (m *FeatureFlagModel) Upsert(override *types.FeatureFlagOverride) error {
+	conflict := `(key) WHERE organization_id IS NULL`
+	if override.OrganizationID != "" {
+		conflict = `(key, organization_id) WHERE organization_id IS NOT NULL`
+	}
+
+	query := `
+		INSERT INTO feature_flags (id, key, organization_id, enabled, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT ` + conflict + ` DO UPDATE
+		SET enabled = EXCLUDED.enabled, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+
+	if override.ID == "" {
+		override.ID = uuid.New().String()
+	}
+
+	return m.db.QueryRow(query,
+		override.ID, override.Key, nullIfEmpty(override.OrganizationID),
+		override.Enabled, nullIfEmpty(override.UpdatedBy),
+	).Scan(&override.ID, &override.CreatedAt, &override.UpdatedAt)
+}
+
+// Delete removes a stored flag value; an empty orgID removes the global value
+func (m *FeatureFlagModel) Delete(key, orgID string) (bool, error) {
+	query := `DELETE FROM feature_flags WHERE key = $1 AND organization_id IS NULL`
+	args := []interface{}{key}
+	if orgID != "" {
+		query = `DELETE FROM feature_flags WHERE key = $1 AND organization_id = $2`
+		args = append(args, orgID)
+	}
+
+	result, err := m.db.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
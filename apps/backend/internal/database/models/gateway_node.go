@@ -0,0 +1,110 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// GatewayNodeModel stores the heartbeats of the gateway's API instances
+type GatewayNodeModel struct {
+	db Database
+}
+
+// NewGatewayNodeModel creates a new gateway node model
+func NewGatewayNodeModel(db Database) *GatewayNodeModel {
+	return &GatewayNodeModel{db: db}
+}
+
+// Heartbeat records the node's current state and returns whether an admin
+// asked it to drain. The drain flag is left as it is.
+func (m *GatewayNodeModel) Heartbeat(node *types.GatewayNode) (bool, error) {
+	sessions, err := json.Marshal(node.Sessions)
+	if err != nil {
+		return false, err
+	}
+
+	var draining bool
+	err = m.db.QueryRow(`
+		INSERT INTO gateway_nodes (id, hostname, address, version, region, started_at, last_heartbeat_at,
+			sessions, health_status, health_score)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO UPDATE SET
+			hostname = EXCLUDED.hostname,
+			address = EXCLUDED.address,
+			version = EXCLUDED.version,
+			region = EXCLUDED.region,
+			last_heartbeat_at = EXCLUDED.last_heartbeat_at,
+			sessions = EXCLUDED.sessions,
+			health_status = EXCLUDED.health_status,
+			health_score = EXCLUDED.health_score
+		RETURNING draining
+	`, node.ID, node.Hostname, node.Address, node.Version, node.Region, node.StartedAt, node.LastHeartbeatAt,
+		sessions, node.HealthStatus, node.HealthScore).Scan(&draining)
+	return draining, err
+}
+
+// List returns every registered node, oldest first
+func (m *GatewayNodeModel) List() ([]*types.GatewayNode, error) {
+	rows, err := m.db.Query(`
+		SELECT id, hostname, address, version, region, started_at, last_heartbeat_at, sessions,
+			health_status, health_score, draining, drain_requested_by, drain_requested_at
+		FROM gateway_nodes
+		ORDER BY started_at, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := []*types.GatewayNode{}
+	for rows.Next() {
+		var node types.GatewayNode
+		var sessions []byte
+		var drainRequestedAt sql.NullTime
+		if err := rows.Scan(&node.ID, &node.Hostname, &node.Address, &node.Version, &node.Region,
+			&node.StartedAt, &node.LastHeartbeatAt, &sessions, &node.HealthStatus, &node.HealthScore,
+			&node.Draining, &node.DrainRequestedBy, &drainRequestedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(sessions, &node.Sessions); err != nil {
+			return nil, err
+		}
+		if drainRequestedAt.Valid {
+			node.DrainRequestedAt = &drainRequestedAt.Time
+		}
+		nodes = append(nodes, &node)
+	}
+	return nodes, rows.Err()
+}
+
+// SetDraining starts or stops draining a node. It reports false when there
+// is no such node.
+func (m *GatewayNodeModel) SetDraining(id string, draining bool, requestedBy string, at time.Time) (bool, error) {
+	result, err := m.db.Exec(`
+		UPDATE gateway_nodes SET draining = $2, drain_requested_by = $3, drain_requested_at = $4
+		WHERE id = $1
+	`, id, draining, requestedBy, at)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Delete removes a node from the registry
+func (m *GatewayNodeModel) Delete(id string) error {
+	_, err := m.db.Exec(`DELETE FROM gateway_nodes WHERE id = $1`, id)
+	return err
+}
+
+// DeleteStale removes nodes whose last heartbeat is older than before
+func (m *GatewayNodeModel) DeleteStale(before time.Time) (int64, error) {
+	result, err := m.db.Exec(`DELETE FROM gateway_nodes WHERE last_heartbeat_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// HeaderPropagationModel stores the header propagation rules of servers and
+// namespaces
+type HeaderPropagationModel struct {
+	db Database
+}
+
+// NewHeaderPropagationModel creates a new header propagation model
+func NewHeaderPropagationModel(db Database) *HeaderPropagationModel {
+	return &HeaderPropagationModel{db: db}
+}
+
+// ScopeExists reports whether the server or namespace belongs to the
+// organization
+func (m *HeaderPropagationModel) ScopeExists(orgID, scopeType, scopeID string) (bool, error) {
+	table := "mcp_servers"
+	if scopeType == types.HeaderPropagationScopeNamespace {
+		table = "namespaces"
+	}
+	var exists bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM `+table+` WHERE id = $1 AND organization_id = $2)
+	`, scopeID, orgID).Scan(&exists)
+	return exists, err
+}
+
+// Get returns the rules of a scope, or nil when it has none
+func (m *HeaderPropagationModel) Get(scopeType, scopeID string) (*types.HeaderPropagationSettings, error) {
+	settings := &types.HeaderPropagationSettings{ScopeType: scopeType, ScopeID: scopeID}
+	var rules []byte
+	var updatedAt time.Time
+	err := m.db.QueryRow(`
+		SELECT rules, updated_at FROM header_propagation_rules WHERE scope_type = $1 AND scope_id = $2
+	`, scopeType, scopeID).Scan(&rules, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rules, &settings.Rules); err != nil {
+		return nil, err
+	}
+	settings.UpdatedAt = &updatedAt
+	return settings, nil
+}
+
+// Upsert replaces the rules of a scope
+func (m *HeaderPropagationModel) Upsert(orgID, scopeType, scopeID string, rules *types.HeaderPropagationRules) error {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec(`
+		INSERT INTO header_propagation_rules (scope_type, scope_id, organization_id, rules)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope_type, scope_id) DO UPDATE SET rules = EXCLUDED.rules
+	`, scopeType, scopeID, orgID, data)
+	return err
+}
+
+// Delete removes the rules of a scope and reports whether it had any
+func (m *HeaderPropagationModel) Delete(scopeType, scopeID string) (bool, error) {
+	result, err := m.db.Exec(`
+		DELETE FROM header_propagation_rules WHERE scope_type = $1 AND scope_id = $2
+	`, scopeType, scopeID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
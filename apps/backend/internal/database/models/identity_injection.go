@@ -0,0 +1,84 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// IdentityInjectionModel stores which caller identity servers receive
+type IdentityInjectionModel struct {
+	db Database
+}
+
+// NewIdentityInjectionModel creates a new identity injection model
+func NewIdentityInjectionModel(db Database) *IdentityInjectionModel {
+	return &IdentityInjectionModel{db: db}
+}
+
+// ServerExists reports whether a server belongs to the organization
+func (m *IdentityInjectionModel) ServerExists(orgID, serverID string) (bool, error) {
+	var exists bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM mcp_servers WHERE id = $1 AND organization_id = $2)
+	`, serverID, orgID).Scan(&exists)
+	return exists, err
+}
+
+// Get returns the identity injection settings of a server, or nil when it
+// receives no identity
+func (m *IdentityInjectionModel) Get(serverID string) (*types.IdentityInjectionSettings, error) {
+	settings := &types.IdentityInjectionSettings{ServerID: serverID}
+	var argument sql.NullString
+	var fields []byte
+	var updatedAt time.Time
+	err := m.db.QueryRow(`
+		SELECT target, argument, fields, updated_at FROM server_identity_injection WHERE server_id = $1
+	`, serverID).Scan(&settings.Target, &argument, &fields, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(fields, &settings.Fields); err != nil {
+		return nil, err
+	}
+	settings.Argument = argument.String
+	settings.UpdatedAt = &updatedAt
+	return settings, nil
+}
+
+// Upsert replaces the identity injection settings of a server
+func (m *IdentityInjectionModel) Upsert(settings *types.IdentityInjectionSettings) error {
+	fields, err := json.Marshal(settings.Fields)
+	if err != nil {
+		return err
+	}
+	var argument interface{}
+	if settings.Argument != "" {
+		argument = settings.Argument
+	}
+	_, err = m.db.Exec(`
+		INSERT INTO server_identity_injection (server_id, target, argument, fields)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (server_id) DO UPDATE
+		SET target = EXCLUDED.target, argument = EXCLUDED.argument, fields = EXCLUDED.fields
+	`, settings.ServerID, settings.Target, argument, fields)
+	return err
+}
+
+// Delete removes the identity injection settings of a server and reports
+// whether it had any
+func (m *IdentityInjectionModel) Delete(serverID string) (bool, error) {
+	result, err := m.db.Exec(`
+		DELETE FROM server_identity_injection WHERE server_id = $1
+	`, serverID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
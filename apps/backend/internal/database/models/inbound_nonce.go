@@ -0,0 +1,37 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// InboundNonceModel remembers the nonces of signed inbound calls
+type InboundNonceModel struct {
+	db Database
+}
+
+// NewInboundNonceModel creates a new inbound nonce model
+func NewInboundNonceModel(db Database) *InboundNonceModel {
+	return &InboundNonceModel{db: db}
+}
+
+// Claim records a nonce until expiresAt. It reports false when the nonce
+// was already claimed in scope and has not yet expired.
+func (m *InboundNonceModel) Claim(scope, nonce string, expiresAt time.Time) (bool, error) {
+	if _, err := m.db.Exec(`DELETE FROM inbound_nonces WHERE expires_at < NOW()`); err != nil {
+		return false, err
+	}
+
+	var claimed string
+	err := m.db.QueryRow(`
+		INSERT INTO inbound_nonces (scope, nonce, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (scope, nonce) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE inbound_nonces.expires_at < NOW()
+		RETURNING nonce
+	`, scope, nonce, expiresAt).Scan(&claimed)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const inspectorCollectionColumns = `
+	id, organization_id, server_id, name, description, requests, COALESCE(created_by::TEXT, ''),
+	created_at, updated_at
+`
+
+// InspectorCollectionModel handles saved inspector request collections
+type InspectorCollectionModel struct {
+	db Database
+}
+
+// NewInspectorCollectionModel creates a new inspector collection model
+func NewInspectorCollectionModel(db Database) *InspectorCollectionModel {
+	return &InspectorCollectionModel{db: db}
+}
+
+// ServerExists reports whether a server belongs to the organization
+func (m *InspectorCollectionModel) ServerExists(orgID, serverID string) (bool, error) {
+	var exists bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM mcp_servers WHERE id = $1 AND organization_id = $2)
+	`, serverID, orgID).Scan(&exists)
+	return exists, err
+}
+
+// List returns the collections saved for a server of the organization, by
+// name
+func (m *InspectorCollectionModel) List(orgID, serverID string) ([]*types.InspectorCollection, error) {
+	rows, err := m.db.Query(`
+		SELECT `+inspectorCollectionColumns+`
+		FROM inspector_collections
+		WHERE organization_id = $1 AND server_id = $2
+		ORDER BY name
+	`, orgID, serverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []*types.InspectorCollection{}
+	for rows.Next() {
+		collection, err := scanInspectorCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+	return collections, rows.Err()
+}
+
+// Get returns a collection of the organization, or nil when there is none
+func (m *InspectorCollectionModel) Get(orgID, id string) (*types.InspectorCollection, error) {
+	collection, err := scanInspectorCollection(m.db.QueryRow(`
+		SELECT `+inspectorCollectionColumns+`
+		FROM inspector_collections
+		WHERE id = $1 AND organization_id = $2
+	`, id, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return collection, err
+}
+
+// Create inserts a collection
+func (m *InspectorCollectionModel) Create(collection *types.InspectorCollection) error {
+	if collection.ID == "" {
+		collection.ID = uuid.New().String()
+	}
+	requests, err := json.Marshal(collection.Requests)
+	if err != nil {
+		return err
+	}
+	return m.db.QueryRow(`
+		INSERT INTO inspector_collections (id, organization_id, server_id, name, description, requests, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::UUID)
+		RETURNING created_at, updated_at
+	`, collection.ID, collection.OrganizationID, collection.ServerID, collection.Name, collection.Description,
+		requests, collection.CreatedBy,
+	).Scan(&collection.CreatedAt, &collection.UpdatedAt)
+}
+
+// Update saves a collection's name, description and requests
+func (m *InspectorCollectionModel) Update(collection *types.InspectorCollection) error {
+	requests, err := json.Marshal(collection.Requests)
+	if err != nil {
+		return err
+	}
+	return m.db.QueryRow(`
+		UPDATE inspector_collections
+		SET name = $3, description = $4, requests = $5
+		WHERE id = $1 AND organization_id = $2
+		RETURNING updated_at
+	`, collection.ID, collection.OrganizationID, collection.Name, collection.Description, requests,
+	).Scan(&collection.UpdatedAt)
+}
+
+// Delete removes a collection of the organization
+func (m *InspectorCollectionModel) Delete(orgID, id string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM inspector_collections WHERE id = $1 AND organization_id = $2`, id, orgID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func scanInspectorCollection(row rowScanner) (*types.InspectorCollection, error) {
+	collection := &types.InspectorCollection{}
+	var requests []byte
+	err := row.Scan(
+		&collection.ID, &collection.OrganizationID, &collection.ServerID, &collection.Name,
+		&collection.Description, &requests, &collection.CreatedBy, &collection.CreatedAt, &collection.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(requests, &collection.Requests); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
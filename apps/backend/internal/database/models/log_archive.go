@@ -0,0 +1,535 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// archiveTable is the table behind an archived dataset
+type archiveTable struct {
+	// references maps columns to the tables they reference, whose rows may
+	// be gone by the time an archive is rehydrated
+	references   map[string]string
+	name         string
+	timeColumn   string
+	methodColumn string
+	errorColumn  string
+}
+
+var archiveTables = map[string]archiveTable{
+	types.ArchiveDatasetExecutionLogs: {
+		name:         "log_index",
+		timeColumn:   "started_at",
+		methodColumn: "rpc_method",
+		errorColumn:  "error_flag",
+		references:   map[string]string{"server_id": "mcp_servers", "session_id": "mcp_sessions", "api_key_id": "api_keys"},
+	},
+	types.ArchiveDatasetSessionEvents: {
+		name:         "mcp_message_logs",
+		timeColumn:   "created_at",
+		methodColumn: "method",
+		errorColumn:  "is_error",
+		references:   map[string]string{"namespace_id": "namespaces", "endpoint_id": "endpoints"},
+	},
+}
+
+// ArchiveTableName returns the table holding a dataset, or "" for unknown
+// datasets
+func ArchiveTableName(dataset string) string {
+	return archiveTables[dataset].name
+}
+
+// LogArchiveModel handles archive policies, archived days of the log tables
+// and their rehydrations
+type LogArchiveModel struct {
+	BaseModel
+}
+
+// NewLogArchiveModel creates a new log archive model
+func NewLogArchiveModel(db Database) *LogArchiveModel {
+	return &LogArchiveModel{BaseModel: BaseModel{db: db}}
+}
+
+// GetPolicy returns an organization's archive policy, or nil when it has
+// none
+func (m *LogArchiveModel) GetPolicy(orgID string) (*types.ArchivePolicy, error) {
+	policy, err := scanArchivePolicy(m.db.QueryRow(archivePolicySelect+` WHERE organization_id = $1`, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return policy, err
+}
+
+// UpsertPolicy creates or replaces an organization's archive policy
+func (m *LogArchiveModel) UpsertPolicy(policy *types.ArchivePolicy) error {
+	var updatedAt time.Time
+	err := m.db.QueryRow(`
+		INSERT INTO archive_policies (organization_id, enabled, archive_after_days, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled, archive_after_days = EXCLUDED.archive_after_days,
+		    updated_by = EXCLUDED.updated_by
+		RETURNING updated_at
+	`, policy.OrganizationID, policy.Enabled, policy.ArchiveAfterDays, nullIfEmpty(policy.UpdatedBy)).Scan(&updatedAt)
+	if err != nil {
+		return err
+	}
+	policy.UpdatedAt = &updatedAt
+	return nil
+}
+
+// ListEnabledPolicies returns the archive policies that are enabled
+func (m *LogArchiveModel) ListEnabledPolicies() ([]*types.ArchivePolicy, error) {
+	rows, err := m.db.Query(archivePolicySelect + ` WHERE enabled = true ORDER BY organization_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*types.ArchivePolicy
+	for rows.Next() {
+		policy, err := scanArchivePolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan archive policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+// HasActiveLegalHold reports whether an organization is under legal hold
+func (m *LogArchiveModel) HasActiveLegalHold(orgID string) (bool, error) {
+	var held bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM legal_holds WHERE organization_id = $1 AND released_at IS NULL)
+	`, orgID).Scan(&held)
+	return held, err
+}
+
+// ListArchivableDays returns the first UTC days, up to limit, before
+// before that hold rows of the dataset and are not archived yet
+func (m *LogArchiveModel) ListArchivableDays(orgID, dataset string, before time.Time, limit int) ([]time.Time, error) {
+	table, ok := archiveTables[dataset]
+	if !ok {
+		return nil, fmt.Errorf("unknown dataset %q", dataset)
+	}
+	day := `(date_trunc('day', t.` + table.timeColumn + ` AT TIME ZONE 'UTC') AT TIME ZONE 'UTC')`
+
+	rows, err := m.db.Query(`
+		SELECT DISTINCT `+day+` AS day
+		FROM `+pq.QuoteIdentifier(table.name)+` t
+		WHERE t.organization_id = $1 AND t.`+table.timeColumn+` < $2
+		  AND NOT EXISTS (
+			SELECT 1 FROM log_archives a
+			WHERE a.organization_id = $1 AND a.dataset = $3 AND a.range_start = `+day+`
+		  )
+		ORDER BY day
+		LIMIT $4
+	`, orgID, before, dataset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archivable days: %w", err)
+	}
+	defer rows.Close()
+
+	var days []time.Time
+	for rows.Next() {
+		var day time.Time
+		if err := rows.Scan(&day); err != nil {
+			return nil, err
+		}
+		days = append(days, day.UTC())
+	}
+	return days, rows.Err()
+}
+
+// ExportRows calls fn with each row of the dataset between start and end,
+// oldest first, as JSON
+func (m *LogArchiveModel) ExportRows(orgID, dataset string, start, end time.Time, fn func(row *types.ArchiveRow) error) error {
+	table, ok := archiveTables[dataset]
+	if !ok {
+		return fmt.Errorf("unknown dataset %q", dataset)
+	}
+
+	rows, err := m.db.Query(`
+		SELECT t.id::text, row_to_json(t)::text, COALESCE(t.`+table.methodColumn+`, ''), COALESCE(t.`+table.errorColumn+`, false)
+		FROM `+pq.QuoteIdentifier(table.name)+` t
+		WHERE t.organization_id = $1 AND t.`+table.timeColumn+` >= $2 AND t.`+table.timeColumn+` < $3
+		ORDER BY t.`+table.timeColumn, orgID, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dataset, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := &types.ArchiveRow{}
+		var data string
+		if err := rows.Scan(&row.ID, &data, &row.Method, &row.Error); err != nil {
+			return err
+		}
+		row.Data = []byte(data)
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// CompleteArchive records an archived day and deletes its rows, ids, from
+// the dataset's table. Rows that arrived after they were archived stay.
+func (m *LogArchiveModel) CompleteArchive(archive *types.LogArchive, ids []string) error {
+	table, ok := archiveTables[archive.Dataset]
+	if !ok {
+		return fmt.Errorf("unknown dataset %q", archive.Dataset)
+	}
+	methods, err := json.Marshal(archive.Methods)
+	if err != nil {
+		return fmt.Errorf("failed to marshal methods: %w", err)
+	}
+
+	return m.Transaction(func(tx *sql.Tx) error {
+		err := tx.QueryRow(`
+			INSERT INTO log_archives (organization_id, dataset, range_start, range_end, storage_key, manifest_key,
+			                          row_count, error_count, size_bytes, sha256, methods)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING id, created_at
+		`, archive.OrganizationID, archive.Dataset, archive.RangeStart, archive.RangeEnd, archive.StorageKey,
+			archive.ManifestKey, archive.RowCount, archive.ErrorCount, archive.SizeBytes, archive.SHA256, methods,
+		).Scan(&archive.ID, &archive.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to record archive: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			DELETE FROM `+pq.QuoteIdentifier(table.name)+`
+			WHERE organization_id = $1 AND `+table.timeColumn+` >= $2 AND `+table.timeColumn+` < $3
+			  AND id::text = ANY($4)
+		`, archive.OrganizationID, archive.RangeStart, archive.RangeEnd, pq.Array(ids))
+		if err != nil {
+			return fmt.Errorf("failed to delete archived rows: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListArchives returns an organization's archives overlapping the query,
+// newest first
+func (m *LogArchiveModel) ListArchives(orgID string, query *types.ArchiveQuery, limit int) ([]*types.LogArchive, error) {
+	sqlQuery := logArchiveSelect + ` WHERE organization_id = $1`
+	args := []interface{}{orgID}
+	if query.Dataset != "" {
+		args = append(args, query.Dataset)
+		sqlQuery += fmt.Sprintf(` AND dataset = $%d`, len(args))
+	}
+	if !query.StartTime.IsZero() {
+		args = append(args, query.StartTime)
+		sqlQuery += fmt.Sprintf(` AND range_end > $%d`, len(args))
+	}
+	if !query.EndTime.IsZero() {
+		args = append(args, query.EndTime)
+		sqlQuery += fmt.Sprintf(` AND range_start < $%d`, len(args))
+	}
+	args = append(args, limit)
+	sqlQuery += fmt.Sprintf(` ORDER BY range_start DESC, dataset LIMIT $%d`, len(args))
+
+	rows, err := m.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives: %w", err)
+	}
+	defer rows.Close()
+
+	var archives []*types.LogArchive
+	for rows.Next() {
+		archive := &types.LogArchive{}
+		var methods []byte
+		err := rows.Scan(&archive.ID, &archive.OrganizationID, &archive.Dataset, &archive.RangeStart, &archive.RangeEnd,
+			&archive.StorageKey, &archive.ManifestKey, &archive.RowCount, &archive.ErrorCount, &archive.SizeBytes,
+			&archive.SHA256, &methods, &archive.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan archive: %w", err)
+		}
+		if err := json.Unmarshal(methods, &archive.Methods); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal methods: %w", err)
+		}
+		archives = append(archives, archive)
+	}
+	return archives, rows.Err()
+}
+
+// RestoreRows inserts archived rows of an organization back into the
+// dataset's table, skipping rows that are already there. References to
+// rows deleted since are cleared. It returns how many rows were inserted.
+func (m *LogArchiveModel) RestoreRows(orgID, dataset string, rows [][]byte) (int64, error) {
+	table, ok := archiveTables[dataset]
+	if !ok {
+		return 0, fmt.Errorf("unknown dataset %q", dataset)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	records := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		var record map[string]interface{}
+		if err := json.Unmarshal(row, &record); err != nil {
+			return 0, fmt.Errorf("invalid archived row: %w", err)
+		}
+		records = append(records, record)
+	}
+	for column, referenced := range table.references {
+		if err := m.clearMissingReferences(records, column, referenced); err != nil {
+			return 0, err
+		}
+	}
+
+	// The monthly partitions of old rows may have been removed
+	months := make(map[string]bool)
+	for _, record := range records {
+		if value, ok := record[table.timeColumn].(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				months[t.UTC().Format("2006-01")+"-01"] = true
+			}
+		}
+	}
+	for month := range months {
+		if _, err := m.db.Exec(`SELECT create_monthly_partition($1, $2::date)`, table.name, month); err != nil {
+			return 0, fmt.Errorf("failed to create partition for %s: %w", month, err)
+		}
+	}
+
+	payload, err := json.Marshal(records)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal rows: %w", err)
+	}
+	name := pq.QuoteIdentifier(table.name)
+	result, err := m.db.Exec(`
+		INSERT INTO `+name+`
+		SELECT r.* FROM json_populate_recordset(NULL::`+name+`, $1::json) r
+		WHERE r.organization_id = $2
+		ON CONFLICT DO NOTHING
+	`, string(payload), orgID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore %s: %w", dataset, err)
+	}
+	return result.RowsAffected()
+}
+
+// RemoveRows deletes an organization's rows of the dataset between start
+// and end
+func (m *LogArchiveModel) RemoveRows(orgID, dataset string, start, end time.Time) (int64, error) {
+	table, ok := archiveTables[dataset]
+	if !ok {
+		return 0, fmt.Errorf("unknown dataset %q", dataset)
+	}
+	result, err := m.db.Exec(`
+		DELETE FROM `+pq.QuoteIdentifier(table.name)+`
+		WHERE organization_id = $1 AND `+table.timeColumn+` >= $2 AND `+table.timeColumn+` < $3
+	`, orgID, start, end)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// clearMissingReferences nulls column in records that reference rows of
+// referenced that no longer exist
+func (m *LogArchiveModel) clearMissingReferences(records []map[string]interface{}, column, referenced string) error {
+	ids := make(map[string]bool)
+	for _, record := range records {
+		if id, ok := record[column].(string); ok {
+			ids[id] = false
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	list := make([]string, 0, len(ids))
+	for id := range ids {
+		list = append(list, id)
+	}
+	rows, err := m.db.Query(`SELECT id::text FROM `+pq.QuoteIdentifier(referenced)+` WHERE id::text = ANY($1)`, pq.Array(list))
+	if err != nil {
+		return fmt.Errorf("failed to check %s references: %w", column, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if id, ok := record[column].(string); ok && !ids[id] {
+			record[column] = nil
+		}
+	}
+	return nil
+}
+
+// CreateRehydration queues a rehydration
+func (m *LogArchiveModel) CreateRehydration(r *types.ArchiveRehydration) error {
+	return m.db.QueryRow(`
+		INSERT INTO archive_rehydrations (organization_id, requested_by, datasets, start_time, end_time, ttl_seconds, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, r.OrganizationID, nullIfEmpty(r.RequestedBy), pq.Array(r.Datasets), r.StartTime, r.EndTime,
+		int64(r.TTL.Seconds()), r.Status,
+	).Scan(&r.ID, &r.CreatedAt)
+}
+
+// GetRehydration returns a rehydration within an organization, or nil when
+// there is none
+func (m *LogArchiveModel) GetRehydration(orgID, id string) (*types.ArchiveRehydration, error) {
+	r, err := scanRehydration(m.db.QueryRow(rehydrationSelect+` WHERE organization_id = $1 AND id = $2`, orgID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return r, err
+}
+
+// ListRehydrations returns an organization's most recent rehydrations
+func (m *LogArchiveModel) ListRehydrations(orgID string, limit int) ([]*types.ArchiveRehydration, error) {
+	rows, err := m.db.Query(rehydrationSelect+` WHERE organization_id = $1 ORDER BY created_at DESC LIMIT $2`, orgID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rehydrations: %w", err)
+	}
+	defer rows.Close()
+	return scanRehydrations(rows)
+}
+
+// ClaimPendingRehydrations marks up to limit pending rehydrations running
+// and returns them. Concurrent workers never claim the same rehydration.
+func (m *LogArchiveModel) ClaimPendingRehydrations(limit int) ([]*types.ArchiveRehydration, error) {
+	rows, err := m.db.Query(`
+		UPDATE archive_rehydrations SET status = 'running'
+		WHERE id IN (
+			SELECT id FROM archive_rehydrations WHERE status = 'pending'
+			ORDER BY created_at LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+rehydrationColumns, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim rehydrations: %w", err)
+	}
+	defer rows.Close()
+	return scanRehydrations(rows)
+}
+
+// FinishRehydration records the outcome of a processed rehydration
+func (m *LogArchiveModel) FinishRehydration(r *types.ArchiveRehydration) error {
+	_, err := m.db.Exec(`
+		UPDATE archive_rehydrations
+		SET status = $2, archive_count = $3, row_count = $4, error = $5, completed_at = $6, expires_at = $7
+		WHERE id = $1
+	`, r.ID, r.Status, r.ArchiveCount, r.RowCount, nullIfEmpty(r.Error), r.CompletedAt, r.ExpiresAt)
+	return err
+}
+
+// ListExpiredRehydrations returns the completed rehydrations that expired
+// before now
+func (m *LogArchiveModel) ListExpiredRehydrations(now time.Time) ([]*types.ArchiveRehydration, error) {
+	rows, err := m.db.Query(rehydrationSelect+` WHERE status = 'completed' AND expires_at < $1`, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired rehydrations: %w", err)
+	}
+	defer rows.Close()
+	return scanRehydrations(rows)
+}
+
+// MarkRehydrationExpired records that a rehydration's rows were removed
+func (m *LogArchiveModel) MarkRehydrationExpired(id string, at time.Time) error {
+	_, err := m.db.Exec(`UPDATE archive_rehydrations SET status = 'expired', expires_at = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+// IsRehydrated reports whether a rehydration other than excludeID still
+// holds the dataset's rows between start and end at now
+func (m *LogArchiveModel) IsRehydrated(orgID, dataset string, start, end time.Time, excludeID string, now time.Time) (bool, error) {
+	var rehydrated bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM archive_rehydrations
+			WHERE organization_id = $1 AND $2 = ANY(datasets) AND id <> $5
+			  AND start_time < $4 AND end_time > $3
+			  AND (status = 'running' OR (status = 'completed' AND expires_at > $6))
+		)
+	`, orgID, dataset, start, end, excludeID, now).Scan(&rehydrated)
+	return rehydrated, err
+}
+
+const archivePolicySelect = `
+		SELECT organization_id, enabled, archive_after_days, updated_by, updated_at
+		FROM archive_policies`
+
+func scanArchivePolicy(row rowScanner) (*types.ArchivePolicy, error) {
+	policy := &types.ArchivePolicy{}
+	var updatedBy sql.NullString
+	var updatedAt sql.NullTime
+	if err := row.Scan(&policy.OrganizationID, &policy.Enabled, &policy.ArchiveAfterDays, &updatedBy, &updatedAt); err != nil {
+		return nil, err
+	}
+	policy.UpdatedBy = updatedBy.String
+	if updatedAt.Valid {
+		policy.UpdatedAt = &updatedAt.Time
+	}
+	return policy, nil
+}
+
+const logArchiveSelect = `
+		SELECT id, organization_id, dataset, range_start, range_end, storage_key, manifest_key,
+		       row_count, error_count, size_bytes, sha256, methods, created_at
+		FROM log_archives`
+
+const rehydrationColumns = `id, organization_id, requested_by, datasets, start_time, end_time, ttl_seconds,
+		       status, archive_count, row_count, error, created_at, completed_at, expires_at`
+
+const rehydrationSelect = `
+		SELECT ` + rehydrationColumns + `
+		FROM archive_rehydrations`
+
+func scanRehydrations(rows *sql.Rows) ([]*types.ArchiveRehydration, error) {
+	var rehydrations []*types.ArchiveRehydration
+	for rows.Next() {
+		r, err := scanRehydration(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rehydration: %w", err)
+		}
+		rehydrations = append(rehydrations, r)
+	}
+	return rehydrations, rows.Err()
+}
+
+func scanRehydration(row rowScanner) (*types.ArchiveRehydration, error) {
+	r := &types.ArchiveRehydration{}
+	var requestedBy, rehydrationErr sql.NullString
+	var completedAt, expiresAt sql.NullTime
+	var ttlSeconds int64
+
+	err := row.Scan(&r.ID, &r.OrganizationID, &requestedBy, pq.Array(&r.Datasets), &r.StartTime, &r.EndTime,
+		&ttlSeconds, &r.Status, &r.ArchiveCount, &r.RowCount, &rehydrationErr, &r.CreatedAt, &completedAt, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	r.RequestedBy = requestedBy.String
+	r.Error = rehydrationErr.String
+	r.TTL = time.Duration(ttlSeconds) * time.Second
+	if completedAt.Valid {
+		r.CompletedAt = &completedAt.Time
+	}
+	if expiresAt.Valid {
+		r.ExpiresAt = &expiresAt.Time
+	}
+	return r, nil
+}
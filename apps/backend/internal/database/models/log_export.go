@@ -0,0 +1,163 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// LogExportModel handles bulk log export jobs
+type LogExportModel struct {
+	db Database
+}
+
+// NewLogExportModel creates a new log export model
+func NewLogExportModel(db Database) *LogExportModel {
+	return &LogExportModel{db: db}
+}
+
+// Create queues a log export
+func (m *LogExportModel) Create(export *types.LogExport) error {
+	filters, err := json.Marshal(export.Filters)
+	if err != nil {
+		return fmt.Errorf("failed to marshal filters: %w", err)
+	}
+
+	return m.db.QueryRow(`
+		INSERT INTO log_exports (organization_id, requested_by, format, status, start_time, end_time, filters)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, export.OrganizationID, nullIfEmpty(export.RequestedBy), export.Format, export.Status,
+		export.StartTime, export.EndTime, filters,
+	).Scan(&export.ID, &export.CreatedAt)
+}
+
+// Get returns an export within an organization, or nil when there is none
+func (m *LogExportModel) Get(orgID, id string) (*types.LogExport, error) {
+	export, err := scanLogExport(m.db.QueryRow(
+		logExportSelect+` WHERE organization_id = $1 AND id = $2`, orgID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return export, err
+}
+
+// GetByID returns an export of any organization, or nil when there is none
+func (m *LogExportModel) GetByID(id string) (*types.LogExport, error) {
+	export, err := scanLogExport(m.db.QueryRow(logExportSelect+` WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return export, err
+}
+
+// List returns an organization's most recent exports
+func (m *LogExportModel) List(orgID string, limit int) ([]*types.LogExport, error) {
+	rows, err := m.db.Query(logExportSelect+` WHERE organization_id = $1 ORDER BY created_at DESC LIMIT $2`, orgID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log exports: %w", err)
+	}
+	defer rows.Close()
+	return scanLogExports(rows)
+}
+
+// ClaimPending marks up to limit pending exports running and returns them.
+// Concurrent workers never claim the same export.
+func (m *LogExportModel) ClaimPending(limit int) ([]*types.LogExport, error) {
+	rows, err := m.db.Query(`
+		UPDATE log_exports SET status = 'running', started_at = NOW()
+		WHERE id IN (
+			SELECT id FROM log_exports WHERE status = 'pending'
+			ORDER BY created_at LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, organization_id, requested_by, format, status, start_time, end_time, filters,
+		          row_count, size_bytes, truncated, storage_key, error,
+		          created_at, started_at, completed_at, expires_at
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim log exports: %w", err)
+	}
+	defer rows.Close()
+	return scanLogExports(rows)
+}
+
+// Finish records the outcome of a processed export
+func (m *LogExportModel) Finish(export *types.LogExport) error {
+	_, err := m.db.Exec(`
+		UPDATE log_exports
+		SET status = $2, row_count = $3, size_bytes = $4, truncated = $5, storage_key = $6, error = $7,
+		    completed_at = $8, expires_at = $9
+		WHERE id = $1
+	`, export.ID, export.Status, export.RowCount, export.SizeBytes, export.Truncated,
+		nullIfEmpty(export.StorageKey), nullIfEmpty(export.Error), export.CompletedAt, export.ExpiresAt)
+	return err
+}
+
+// ListExpired returns the completed exports whose files expired before now
+func (m *LogExportModel) ListExpired(now time.Time) ([]*types.LogExport, error) {
+	rows, err := m.db.Query(logExportSelect+` WHERE status = 'completed' AND expires_at < $1`, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired log exports: %w", err)
+	}
+	defer rows.Close()
+	return scanLogExports(rows)
+}
+
+// MarkExpired records that an export's file was removed
+func (m *LogExportModel) MarkExpired(id string) error {
+	_, err := m.db.Exec(`UPDATE log_exports SET status = 'expired', storage_key = NULL WHERE id = $1`, id)
+	return err
+}
+
+const logExportSelect = `
+		SELECT id, organization_id, requested_by, format, status, start_time, end_time, filters,
+		       row_count, size_bytes, truncated, storage_key, error,
+		       created_at, started_at, completed_at, expires_at
+		FROM log_exports`
+
+func scanLogExports(rows *sql.Rows) ([]*types.LogExport, error) {
+	var exports []*types.LogExport
+	for rows.Next() {
+		export, err := scanLogExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan log export: %w", err)
+		}
+		exports = append(exports, export)
+	}
+	return exports, rows.Err()
+}
+
+func scanLogExport(row rowScanner) (*types.LogExport, error) {
+	export := &types.LogExport{}
+	var requestedBy, storageKey, exportErr sql.NullString
+	var startedAt, completedAt, expiresAt sql.NullTime
+	var filters []byte
+
+	err := row.Scan(&export.ID, &export.OrganizationID, &requestedBy, &export.Format, &export.Status,
+		&export.StartTime, &export.EndTime, &filters, &export.RowCount, &export.SizeBytes, &export.Truncated,
+		&storageKey, &exportErr, &export.CreatedAt, &startedAt, &completedAt, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	export.RequestedBy = requestedBy.String
+	export.StorageKey = storageKey.String
+	export.Error = exportErr.String
+	if startedAt.Valid {
+		export.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+	if expiresAt.Valid {
+		export.ExpiresAt = &expiresAt.Time
+	}
+	if err := json.Unmarshal(filters, &export.Filters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal filters: %w", err)
+	}
+	return export, nil
+}
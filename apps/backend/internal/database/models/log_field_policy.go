@@ -0,0 +1,57 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// LogFieldPolicyModel handles organizations' request log field policies
+type LogFieldPolicyModel struct {
+	db Database
+}
+
+// NewLogFieldPolicyModel creates a new log field policy model
+func NewLogFieldPolicyModel(db Database) *LogFieldPolicyModel {
+	return &LogFieldPolicyModel{db: db}
+}
+
+// Get returns the organization's policy, or nil when it has none
+func (m *LogFieldPolicyModel) Get(orgID string) (*types.LogFieldPolicy, error) {
+	policy := &types.LogFieldPolicy{}
+	err := m.db.QueryRow(`
+		SELECT organization_id, omit_fields, COALESCE(updated_by::text, ''), updated_at
+		FROM log_field_policies
+		WHERE organization_id = $1
+	`, orgID).Scan(&policy.OrganizationID, pq.Array(&policy.OmitFields), &policy.UpdatedBy, &policy.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Set creates or replaces the organization's policy
+func (m *LogFieldPolicyModel) Set(policy *types.LogFieldPolicy) error {
+	return m.db.QueryRow(`
+		INSERT INTO log_field_policies (organization_id, omit_fields, updated_by)
+		VALUES ($1, $2, NULLIF($3, '')::uuid)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET omit_fields = EXCLUDED.omit_fields, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING updated_at
+	`, policy.OrganizationID, pq.Array(policy.OmitFields), policy.UpdatedBy).Scan(&policy.UpdatedAt)
+}
+
+// Delete removes the organization's policy
+func (m *LogFieldPolicyModel) Delete(orgID string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM log_field_policies WHERE organization_id = $1`, orgID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -6,6 +6,8 @@ import (
 	"net"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
 	"github.com/google/uuid"
 )
 
@@ -18,6 +20,8 @@ type LogIndex struct {
 	ConnectionID    *uuid.UUID     `db:"connection_id" json:"connection_id,omitempty"`
 	ClientID        *uuid.UUID     `db:"client_id" json:"client_id,omitempty"`
 	RemoteIP        *net.IP        `db:"remote_ip" json:"remote_ip,omitempty"`
+	APIKeyID        *uuid.UUID     `db:"api_key_id" json:"api_key_id,omitempty"`
+	Labels          types.Labels   `db:"labels" json:"labels,omitempty"`
 	UserID          string         `db:"user_id" json:"user_id"`
 	Level           string         `db:"level" json:"level"`
 	StorageProvider string         `db:"storage_provider" json:"storage_provider"`
@@ -26,6 +30,8 @@ type LogIndex struct {
 	ByteOffset      sql.NullInt64  `db:"byte_offset" json:"byte_offset,omitempty"`
 	StatusCode      sql.NullInt32  `db:"status_code" json:"status_code,omitempty"`
 	DurationMS      sql.NullInt32  `db:"duration_ms" json:"duration_ms,omitempty"`
+	PrincipalType   sql.NullString `db:"principal_type" json:"principal_type,omitempty"`
+	OAuthClientID   sql.NullString `db:"oauth_client_id" json:"oauth_client_id,omitempty"`
 	ID              uuid.UUID      `db:"id" json:"id"`
 	OrganizationID  uuid.UUID      `db:"organization_id" json:"organization_id"`
 	ErrorFlag       bool           `db:"error_flag" json:"error_flag"`
@@ -83,8 +89,9 @@ func (m *LogIndexModel) Create(logEntry *LogIndex) error {
 		INSERT INTO log_index (
 			id, organization_id, server_id, session_id, rpc_method, level,
 			started_at, duration_ms, status_code, error_flag, storage_provider,
-			object_uri, byte_offset, user_id, remote_ip, client_id, connection_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			object_uri, byte_offset, user_id, remote_ip, client_id, connection_id,
+			principal_type, api_key_id, oauth_client_id, labels
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 	`
 
 	if logEntry.ID == uuid.Nil {
@@ -96,7 +103,8 @@ func (m *LogIndexModel) Create(logEntry *LogIndex) error {
 		logEntry.RPCMethod, logEntry.Level, logEntry.StartedAt, logEntry.DurationMS,
 		logEntry.StatusCode, logEntry.ErrorFlag, logEntry.StorageProvider,
 		logEntry.ObjectURI, logEntry.ByteOffset, logEntry.UserID, logEntry.RemoteIP,
-		logEntry.ClientID, logEntry.ConnectionID)
+		logEntry.ClientID, logEntry.ConnectionID, logEntry.PrincipalType,
+		logEntry.APIKeyID, logEntry.OAuthClientID, logEntry.Labels)
 	return err
 }
 
@@ -105,7 +113,8 @@ func (m *LogIndexModel) GetByID(id uuid.UUID) (*LogIndex, error) {
 	query := `
 		SELECT id, organization_id, server_id, session_id, rpc_method, level,
 			   started_at, duration_ms, status_code, error_flag, storage_provider,
-			   object_uri, byte_offset, user_id, remote_ip, client_id, connection_id, created_at
+			   object_uri, byte_offset, user_id, remote_ip, client_id, connection_id,
+			   principal_type, api_key_id, oauth_client_id, labels, created_at
 		FROM log_index
 		WHERE id = $1
 	`
@@ -116,7 +125,8 @@ func (m *LogIndexModel) GetByID(id uuid.UUID) (*LogIndex, error) {
 		&logEntry.RPCMethod, &logEntry.Level, &logEntry.StartedAt, &logEntry.DurationMS,
 		&logEntry.StatusCode, &logEntry.ErrorFlag, &logEntry.StorageProvider,
 		&logEntry.ObjectURI, &logEntry.ByteOffset, &logEntry.UserID, &logEntry.RemoteIP,
-		&logEntry.ClientID, &logEntry.ConnectionID, &logEntry.CreatedAt,
+		&logEntry.ClientID, &logEntry.ConnectionID, &logEntry.PrincipalType,
+		&logEntry.APIKeyID, &logEntry.OAuthClientID, &logEntry.Labels, &logEntry.CreatedAt,
 	)
 
 	if err != nil {
@@ -131,7 +141,8 @@ func (m *LogIndexModel) ListByOrganization(orgID uuid.UUID, limit, offset int) (
 	query := `
 		SELECT id, organization_id, server_id, session_id, rpc_method, level,
 			   started_at, duration_ms, status_code, error_flag, storage_provider,
-			   object_uri, byte_offset, user_id, remote_ip, client_id, connection_id, created_at
+			   object_uri, byte_offset, user_id, remote_ip, client_id, connection_id,
+			   principal_type, api_key_id, oauth_client_id, labels, created_at
 		FROM log_index
 		WHERE organization_id = $1
 		ORDER BY started_at DESC
@@ -152,7 +163,8 @@ func (m *LogIndexModel) ListByOrganization(orgID uuid.UUID, limit, offset int) (
 			&logEntry.RPCMethod, &logEntry.Level, &logEntry.StartedAt, &logEntry.DurationMS,
 			&logEntry.StatusCode, &logEntry.ErrorFlag, &logEntry.StorageProvider,
 			&logEntry.ObjectURI, &logEntry.ByteOffset, &logEntry.UserID, &logEntry.RemoteIP,
-			&logEntry.ClientID, &logEntry.ConnectionID, &logEntry.CreatedAt,
+			&logEntry.ClientID, &logEntry.ConnectionID, &logEntry.PrincipalType,
+			&logEntry.APIKeyID, &logEntry.OAuthClientID, &logEntry.Labels, &logEntry.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -168,7 +180,8 @@ func (m *LogIndexModel) ListErrors(orgID uuid.UUID, limit, offset int) ([]*LogIn
 	query := `
 		SELECT id, organization_id, server_id, session_id, rpc_method, level,
 			   started_at, duration_ms, status_code, error_flag, storage_provider,
-			   object_uri, byte_offset, user_id, remote_ip, client_id, connection_id, created_at
+			   object_uri, byte_offset, user_id, remote_ip, client_id, connection_id,
+			   principal_type, api_key_id, oauth_client_id, labels, created_at
 		FROM log_index
 		WHERE organization_id = $1 AND error_flag = true
 		ORDER BY started_at DESC
@@ -189,7 +202,8 @@ func (m *LogIndexModel) ListErrors(orgID uuid.UUID, limit, offset int) ([]*LogIn
 			&logEntry.RPCMethod, &logEntry.Level, &logEntry.StartedAt, &logEntry.DurationMS,
 			&logEntry.StatusCode, &logEntry.ErrorFlag, &logEntry.StorageProvider,
 			&logEntry.ObjectURI, &logEntry.ByteOffset, &logEntry.UserID, &logEntry.RemoteIP,
-			&logEntry.ClientID, &logEntry.ConnectionID, &logEntry.CreatedAt,
+			&logEntry.ClientID, &logEntry.ConnectionID, &logEntry.PrincipalType,
+			&logEntry.APIKeyID, &logEntry.OAuthClientID, &logEntry.Labels, &logEntry.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -200,11 +214,16 @@ func (m *LogIndexModel) ListErrors(orgID uuid.UUID, limit, offset int) ([]*LogIn
 	return logs, nil
 }
 
-// CleanupOldLogs removes log index entries older than the retention policy
+// CleanupOldLogs removes log index entries older than the retention policy.
+// Organizations under legal hold are skipped.
 func (m *LogIndexModel) CleanupOldLogs(orgID uuid.UUID, retentionDays int) error {
 	query := `
 		DELETE FROM log_index
-		WHERE organization_id = $1 AND created_at < NOW() - INTERVAL '%d days'
+		WHERE organization_id = $1 AND created_at < NOW() - make_interval(days => $2)
+		  AND NOT EXISTS (
+			SELECT 1 FROM legal_holds
+			WHERE legal_holds.organization_id = $1 AND released_at IS NULL
+		  )
 	`
 
 	_, err := m.db.Exec(query, orgID, retentionDays)
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Default and maximum page sizes for MCP message log listings
+const (
+	defaultMCPMessageLogLimit = 100
+	maxMCPMessageLogLimit     = 1000
+)
+
+// MCPMessageLogModel handles MCP message log database operations
+type MCPMessageLogModel struct {
+	db Database
+}
+
+// NewMCPMessageLogModel creates a new MCP message log model
+func NewMCPMessageLogModel(db Database) *MCPMessageLogModel {
+	return &MCPMessageLogModel{db: db}
+}
+
+// Create inserts a new MCP message log entry
+func (m *MCPMessageLogModel) Create(entry *types.MCPMessageLog) error {
+	query := `
+		INSERT INTO mcp_message_logs (
+			id, organization_id, namespace_id, endpoint_id, session_id, transport,
+			method, tool_name, rpc_id, principal_type, principal_id, is_error,
+			error_code, error_message, status_code, duration_ms, request_size,
+			result_size, params, redacted_fields, on_behalf_of
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		RETURNING created_at
+	`
+
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+
+	paramsJSON, err := json.Marshal(entry.Params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal params: %w", err)
+	}
+
+	return m.db.QueryRow(query,
+		entry.ID, nullIfEmpty(entry.OrganizationID), nullIfEmpty(entry.NamespaceID),
+		nullIfEmpty(entry.EndpointID), nullIfEmpty(entry.SessionID), entry.Transport,
+		entry.Method, nullIfEmpty(entry.ToolName), nullIfEmpty(entry.RPCID),
+		nullIfEmpty(entry.PrincipalType), nullIfEmpty(entry.PrincipalID), entry.IsError,
+		sql.NullInt32{Int32: int32(entry.ErrorCode), Valid: entry.ErrorCode != 0},
+		nullIfEmpty(entry.ErrorMessage),
+		sql.NullInt32{Int32: int32(entry.StatusCode), Valid: entry.StatusCode != 0},
+		entry.DurationMS, entry.RequestSize, entry.ResultSize, paramsJSON,
+		pq.Array(entry.RedactedFields), nullIfEmpty(entry.OnBehalfOf),
+	).Scan(&entry.CreatedAt)
+}
+
+// GetByID retrieves an MCP message log entry within an organization
+func (m *MCPMessageLogModel) GetByID(orgID, id string) (*types.MCPMessageLog, error) {
+	query := mcpMessageLogSelect + ` WHERE organization_id = $1 AND id = $2`
+
+	entry, err := scanMCPMessageLog(m.db.QueryRow(query, orgID, id))
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// List retrieves MCP message log entries for an organization, newest first
+func (m *MCPMessageLogModel) List(orgID string, filter *types.MCPMessageLogQuery) ([]*types.MCPMessageLog, error) {
+	query := mcpMessageLogSelect + ` WHERE organization_id = $1`
+	args := []interface{}{orgID}
+	argIndex := 2
+
+	if filter == nil {
+		filter = &types.MCPMessageLogQuery{}
+	}
+
+	addFilter := func(column, value string) {
+		if value == "" {
+			return
+		}
+		query += fmt.Sprintf(" AND %s = $%d", column, argIndex)
+		args = append(args, value)
+		argIndex++
+	}
+	addFilter("namespace_id", filter.NamespaceID)
+	addFilter("endpoint_id", filter.EndpointID)
+	addFilter("session_id", filter.SessionID)
+	addFilter("method", filter.Method)
+	addFilter("tool_name", filter.ToolName)
+	addFilter("principal_id", filter.PrincipalID)
+	addFilter("transport", filter.Transport)
+
+	if filter.ErrorsOnly {
+		query += " AND is_error = true"
+	}
+	if filter.StartTime != nil {
+		query += fmt.Sprintf(" AND created_at >= $%d", argIndex)
+		args = append(args, *filter.StartTime)
+		argIndex++
+	}
+	if filter.EndTime != nil {
+		query += fmt.Sprintf(" AND created_at <= $%d", argIndex)
+		args = append(args, *filter.EndTime)
+		argIndex++
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultMCPMessageLogLimit
+	}
+	if limit > maxMCPMessageLogLimit {
+		limit = maxMCPMessageLogLimit
+	}
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, limit, filter.Offset)
+
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MCP message logs: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*types.MCPMessageLog
+	for rows.Next() {
+		entry, err := scanMCPMessageLog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan MCP message log: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+const mcpMessageLogSelect = `
+		SELECT id, organization_id, namespace_id, endpoint_id, session_id, transport,
+		       method, tool_name, rpc_id, principal_type, principal_id, is_error,
+		       error_code, error_message, status_code, duration_ms, request_size,
+		       result_size, params, redacted_fields, on_behalf_of, created_at
+		FROM mcp_message_logs`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMCPMessageLog(row rowScanner) (*types.MCPMessageLog, error) {
+	entry := &types.MCPMessageLog{}
+	var orgID, namespaceID, endpointID, sessionID, toolName, rpcID sql.NullString
+	var principalType, principalID, onBehalfOf, errorMessage sql.NullString
+	var errorCode, statusCode sql.NullInt32
+	var paramsJSON []byte
+
+	err := row.Scan(
+		&entry.ID, &orgID, &namespaceID, &endpointID, &sessionID, &entry.Transport,
+		&entry.Method, &toolName, &rpcID, &principalType, &principalID, &entry.IsError,
+		&errorCode, &errorMessage, &statusCode, &entry.DurationMS, &entry.RequestSize,
+		&entry.ResultSize, &paramsJSON, pq.Array(&entry.RedactedFields), &onBehalfOf, &entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.OrganizationID = orgID.String
+	entry.NamespaceID = namespaceID.String
+	entry.EndpointID = endpointID.String
+	entry.SessionID = sessionID.String
+	entry.ToolName = toolName.String
+	entry.RPCID = rpcID.String
+	entry.PrincipalType = principalType.String
+	entry.PrincipalID = principalID.String
+	entry.OnBehalfOf = onBehalfOf.String
+	entry.ErrorMessage = errorMessage.String
+	entry.ErrorCode = int(errorCode.Int32)
+	entry.StatusCode = int(statusCode.Int32)
+
+	if len(paramsJSON) > 0 {
+		if err := json.Unmarshal(paramsJSON, &entry.Params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal params: %w", err)
+		}
+	}
+
+	return entry, nil
+}
+
+// nullIfEmpty maps empty strings to SQL NULL
+func nullIfEmpty(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const mockToolColumns = `
+	id, server_id, name, description, input_schema, response, is_error, delay_ms, created_at, updated_at
+`
+
+// MockToolModel handles the canned tools of mock MCP servers
+type MockToolModel struct {
+	db Database
+}
+
+// NewMockToolModel creates a new mock tool model
+func NewMockToolModel(db Database) *MockToolModel {
+	return &MockToolModel{db: db}
+}
+
+// ServerProtocol returns the protocol of a server of the organization, or ""
+// when there is none
+func (m *MockToolModel) ServerProtocol(orgID, serverID string) (string, error) {
+	var protocol string
+	err := m.db.QueryRow(`
+		SELECT protocol FROM mcp_servers WHERE id = $1 AND organization_id = $2
+	`, serverID, orgID).Scan(&protocol)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return protocol, err
+}
+
+// List returns the mock tools of a server, by name
+func (m *MockToolModel) List(serverID string) ([]*types.MockTool, error) {
+	rows, err := m.db.Query(`
+		SELECT `+mockToolColumns+`
+		FROM mock_tools
+		WHERE server_id = $1
+		ORDER BY name
+	`, serverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tools := []*types.MockTool{}
+	for rows.Next() {
+		tool, err := scanMockTool(rows)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tool)
+	}
+	return tools, rows.Err()
+}
+
+// Get returns a mock tool of a server, or nil when there is none
+func (m *MockToolModel) Get(serverID, id string) (*types.MockTool, error) {
+	tool, err := scanMockTool(m.db.QueryRow(`
+		SELECT `+mockToolColumns+`
+		FROM mock_tools
+		WHERE id = $1 AND server_id = $2
+	`, id, serverID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return tool, err
+}
+
+// Create inserts a mock tool
+func (m *MockToolModel) Create(tool *types.MockTool) error {
+	if tool.ID == "" {
+		tool.ID = uuid.New().String()
+	}
+	schema, err := json.Marshal(tool.InputSchema)
+	if err != nil {
+		return err
+	}
+	return m.db.QueryRow(`
+		INSERT INTO mock_tools (id, server_id, name, description, input_schema, response, is_error, delay_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at
+	`, tool.ID, tool.ServerID, tool.Name, tool.Description, schema, tool.Response, tool.IsError, tool.DelayMs,
+	).Scan(&tool.CreatedAt, &tool.UpdatedAt)
+}
+
+// Update saves a mock tool
+func (m *MockToolModel) Update(tool *types.MockTool) error {
+	schema, err := json.Marshal(tool.InputSchema)
+	if err != nil {
+		return err
+	}
+	return m.db.QueryRow(`
+		UPDATE mock_tools
+		SET name = $3, description = $4, input_schema = $5, response = $6, is_error = $7, delay_ms = $8
+		WHERE id = $1 AND server_id = $2
+		RETURNING updated_at
+	`, tool.ID, tool.ServerID, tool.Name, tool.Description, schema, tool.Response, tool.IsError, tool.DelayMs,
+	).Scan(&tool.UpdatedAt)
+}
+
+// Delete removes a mock tool of a server
+func (m *MockToolModel) Delete(serverID, id string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM mock_tools WHERE id = $1 AND server_id = $2`, id, serverID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func scanMockTool(row rowScanner) (*types.MockTool, error) {
+	tool := &types.MockTool{}
+	var schema []byte
+	err := row.Scan(
+		&tool.ID, &tool.ServerID, &tool.Name, &tool.Description, &schema, &tool.Response, &tool.IsError,
+		&tool.DelayMs, &tool.CreatedAt, &tool.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(schema, &tool.InputSchema); err != nil {
+		return nil, err
+	}
+	return tool, nil
+}
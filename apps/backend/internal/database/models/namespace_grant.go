@@ -0,0 +1,155 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// NamespaceGrantModel handles namespace grant database operations
+type NamespaceGrantModel struct {
+	db Database
+}
+
+// NewNamespaceGrantModel creates a new namespace grant model
+func NewNamespaceGrantModel(db Database) *NamespaceGrantModel {
+	return &NamespaceGrantModel{db: db}
+}
+
+// ListForNamespace returns the grants on a namespace
+func (m *NamespaceGrantModel) ListForNamespace(namespaceID string) ([]*types.NamespaceGrant, error) {
+	return m.queryGrants(`
+		SELECT id, organization_id, namespace_id, subject_type, subject_id, access_level, created_by, created_at,
+			expires_at, expired_at
+		FROM namespace_grants
+		WHERE namespace_id = $1
+		ORDER BY created_at
+	`, namespaceID)
+}
+
+// ListForOrganization returns every namespace grant in the organization
+func (m *NamespaceGrantModel) ListForOrganization(orgID string) ([]*types.NamespaceGrant, error) {
+	return m.queryGrants(`
+		SELECT id, organization_id, namespace_id, subject_type, subject_id, access_level, created_by, created_at,
+			expires_at, expired_at
+		FROM namespace_grants
+		WHERE organization_id = $1
+	`, orgID)
+}
+
+// Upsert records a grant, replacing the level and expiry of an existing
+// grant to the same subject
+func (m *NamespaceGrantModel) Upsert(grant *types.NamespaceGrant) error {
+	query := `
+		INSERT INTO namespace_grants (id, organization_id, namespace_id, subject_type, subject_id, access_level, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (namespace_id, subject_type, subject_id) DO UPDATE SET
+			access_level = EXCLUDED.access_level,
+			created_by = EXCLUDED.created_by,
+			expires_at = EXCLUDED.expires_at,
+			reminded_at = NULL,
+			expired_at = NULL
+		RETURNING id, created_at
+	`
+
+	if grant.ID == "" {
+		grant.ID = uuid.New().String()
+	}
+
+	return m.db.QueryRow(query,
+		grant.ID, grant.OrganizationID, grant.NamespaceID, grant.SubjectType,
+		grant.SubjectID, grant.AccessLevel, grant.CreatedBy, grant.ExpiresAt,
+	).Scan(&grant.ID, &grant.CreatedAt)
+}
+
+// Delete removes a grant from a namespace
+func (m *NamespaceGrantModel) Delete(namespaceID, id string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM namespace_grants WHERE id = $1 AND namespace_id = $2`, id, namespaceID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// NamespaceOrganization returns the organization of a namespace, or "" when
+// there is no such namespace
+func (m *NamespaceGrantModel) NamespaceOrganization(namespaceID string) (string, error) {
+	return m.queryOrganization(`SELECT organization_id FROM namespaces WHERE id = $1`, namespaceID)
+}
+
+// UserOrganization returns the organization of a user, or "" when there is
+// no such user
+func (m *NamespaceGrantModel) UserOrganization(userID string) (string, error) {
+	return m.queryOrganization(`SELECT organization_id FROM users WHERE id = $1`, userID)
+}
+
+// TeamOrganization returns the organization of a team, or "" when there is
+// no such team
+func (m *NamespaceGrantModel) TeamOrganization(teamID string) (string, error) {
+	return m.queryOrganization(`SELECT organization_id FROM teams WHERE id = $1`, teamID)
+}
+
+// UserTeams returns the IDs of the teams a user belongs to
+func (m *NamespaceGrantModel) UserTeams(userID string) ([]string, error) {
+	rows, err := m.db.Query(`SELECT team_id FROM team_members WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teamIDs := []string{}
+	for rows.Next() {
+		var teamID string
+		if err := rows.Scan(&teamID); err != nil {
+			return nil, err
+		}
+		teamIDs = append(teamIDs, teamID)
+	}
+	return teamIDs, rows.Err()
+}
+
+// EndpointNamespace returns the namespace an endpoint serves, or "" when
+// there is no such endpoint
+func (m *NamespaceGrantModel) EndpointNamespace(endpointID string) (string, error) {
+	var namespaceID string
+	err := m.db.QueryRow(`SELECT namespace_id FROM endpoints WHERE id = $1`, endpointID).Scan(&namespaceID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return namespaceID, err
+}
+
+func (m *NamespaceGrantModel) queryOrganization(query, id string) (string, error) {
+	var orgID string
+	err := m.db.QueryRow(query, id).Scan(&orgID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return orgID, err
+}
+
+func (m *NamespaceGrantModel) queryGrants(query string, args ...interface{}) ([]*types.NamespaceGrant, error) {
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := []*types.NamespaceGrant{}
+	for rows.Next() {
+		grant := &types.NamespaceGrant{}
+		if err := rows.Scan(
+			&grant.ID, &grant.OrganizationID, &grant.NamespaceID, &grant.SubjectType,
+			&grant.SubjectID, &grant.AccessLevel, &grant.CreatedBy, &grant.CreatedAt,
+			&grant.ExpiresAt, &grant.ExpiredAt,
+		); err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
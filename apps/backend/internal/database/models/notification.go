@@ -0,0 +1,295 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// NotificationModel handles notification database operations
+type NotificationModel struct {
+	db Database
+}
+
+// NewNotificationModel creates a new notification model
+func NewNotificationModel(db Database) *NotificationModel {
+	return &NotificationModel{db: db}
+}
+
+// ListAdmins returns the active admins of an organization
+func (m *NotificationModel) ListAdmins(orgID string) ([]*types.NotificationRecipient, error) {
+	query := `
+		SELECT id, email, name
+		FROM users
+		WHERE organization_id = $1 AND role = $2 AND is_active = true AND account_type = 'human'
+		ORDER BY created_at
+	`
+
+	return m.queryRecipients(query, orgID, types.RoleAdmin)
+}
+
+// ListDigestRecipients returns active users who opted in to email digests
+func (m *NotificationModel) ListDigestRecipients() ([]*types.NotificationRecipient, error) {
+	query := `
+		SELECT u.id, u.email, u.name
+		FROM users u
+		JOIN notification_preferences p ON p.user_id = u.id
+		WHERE p.email_digest = true AND u.is_active = true
+		ORDER BY u.id
+	`
+
+	return m.queryRecipients(query)
+}
+
+func (m *NotificationModel) queryRecipients(query string, args ...interface{}) ([]*types.NotificationRecipient, error) {
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipients []*types.NotificationRecipient
+	for rows.Next() {
+		recipient := &types.NotificationRecipient{}
+		if err := rows.Scan(&recipient.UserID, &recipient.Email, &recipient.Name); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, rows.Err()
+}
+
+// HasUnread reports whether the user has an unread notification with dedupKey
+func (m *NotificationModel) HasUnread(userID, dedupKey string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM notifications
+			WHERE user_id = $1 AND dedup_key = $2 AND read_at IS NULL
+		)
+	`
+
+	var exists bool
+	err := m.db.QueryRow(query, userID, dedupKey).Scan(&exists)
+	return exists, err
+}
+
+// Create inserts a notification
+func (m *NotificationModel) Create(n *types.Notification) error {
+	dataJSON, err := json.Marshal(n.Data)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO notifications (id, organization_id, user_id, type, severity, title, message,
+			resource_type, resource_id, dedup_key, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at
+	`
+
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+
+	return m.db.QueryRow(query,
+		n.ID, n.OrganizationID, n.UserID, n.Type, n.Severity, n.Title, n.Message,
+		nullIfEmpty(n.ResourceType), nullIfEmpty(n.ResourceID), nullIfEmpty(n.DedupKey), dataJSON,
+	).Scan(&n.CreatedAt)
+}
+
+// List returns a page of the user's notifications, newest first
+func (m *NotificationModel) List(filter *types.NotificationListFilter) ([]*types.Notification, error) {
+	query := `
+		SELECT id, organization_id, user_id, type, severity, title, message,
+			resource_type, resource_id, dedup_key, data, read_at, emailed_at, created_at
+		FROM notifications
+		WHERE user_id = $1
+	`
+	if filter.UnreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	query += ` ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+
+	return m.queryNotifications(query, filter.UserID, filter.Limit, filter.Offset)
+}
+
+// ListUndigested returns the user's unread notifications not yet emailed
+func (m *NotificationModel) ListUndigested(userID string) ([]*types.Notification, error) {
+	query := `
+		SELECT id, organization_id, user_id, type, severity, title, message,
+			resource_type, resource_id, dedup_key, data, read_at, emailed_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND read_at IS NULL AND emailed_at IS NULL
+		ORDER BY created_at
+	`
+
+	return m.queryNotifications(query, userID)
+}
+
+func (m *NotificationModel) queryNotifications(query string, args ...interface{}) ([]*types.Notification, error) {
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*types.Notification
+	for rows.Next() {
+		n := &types.Notification{}
+		var resourceType, resourceID, dedupKey sql.NullString
+		var readAt, emailedAt sql.NullTime
+		var dataJSON []byte
+		if err := rows.Scan(&n.ID, &n.OrganizationID, &n.UserID, &n.Type, &n.Severity, &n.Title,
+			&n.Message, &resourceType, &resourceID, &dedupKey, &dataJSON, &readAt, &emailedAt,
+			&n.CreatedAt); err != nil {
+			return nil, err
+		}
+		n.ResourceType = resourceType.String
+		n.ResourceID = resourceID.String
+		n.DedupKey = dedupKey.String
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		if emailedAt.Valid {
+			n.EmailedAt = &emailedAt.Time
+		}
+		if len(dataJSON) > 0 {
+			if err := json.Unmarshal(dataJSON, &n.Data); err != nil {
+				return nil, err
+			}
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// CountUnread returns the number of unread notifications for the user
+func (m *NotificationModel) CountUnread(userID string) (int, error) {
+	var count int
+	err := m.db.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks one of the user's notifications as read. It reports false
+// when the notification does not exist or belongs to another user.
+func (m *NotificationModel) MarkRead(userID, id string) (bool, error) {
+	result, err := m.db.Exec(`
+		UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// MarkAllRead marks every unread notification of the user as read
+func (m *NotificationModel) MarkAllRead(userID string) (int64, error) {
+	result, err := m.db.Exec(`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// MarkEmailed records that notifications were included in a digest and
+// advances the user's last digest time
+func (m *NotificationModel) MarkEmailed(userID string, ids []string, at time.Time) error {
+	if _, err := m.db.Exec(`
+		UPDATE notifications SET emailed_at = $3
+		WHERE user_id = $1 AND id = ANY($2)
+	`, userID, pq.Array(ids), at); err != nil {
+		return err
+	}
+
+	_, err := m.db.Exec(`
+		UPDATE notification_preferences SET last_digest_at = $2, updated_at = NOW()
+		WHERE user_id = $1
+	`, userID, at)
+	return err
+}
+
+// GetPreferences returns the user's stored preferences, or nil if none
+func (m *NotificationModel) GetPreferences(userID string) (*types.NotificationPreferences, error) {
+	prefs := &types.NotificationPreferences{UserID: userID}
+	var lastDigestAt sql.NullTime
+	err := m.db.QueryRow(`
+		SELECT email_digest, last_digest_at FROM notification_preferences WHERE user_id = $1
+	`, userID).Scan(&prefs.EmailDigest, &lastDigestAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lastDigestAt.Valid {
+		prefs.LastDigestAt = &lastDigestAt.Time
+	}
+	return prefs, nil
+}
+
+// UpsertPreferences stores the user's preferences
+func (m *NotificationModel) UpsertPreferences(prefs *types.NotificationPreferences) error {
+	_, err := m.db.Exec(`
+		INSERT INTO notification_preferences (user_id, email_digest)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET email_digest = EXCLUDED.email_digest, updated_at = NOW()
+	`, prefs.UserID, prefs.EmailDigest)
+	return err
+}
+
+// ListActiveOrganizations returns the IDs of active organizations
+func (m *NotificationModel) ListActiveOrganizations() ([]string, error) {
+	rows, err := m.db.Query(`SELECT id FROM organizations WHERE is_active = true ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ListTLSServers returns active servers with an https or wss URL
+func (m *NotificationModel) ListTLSServers() ([]*types.TLSServerTarget, error) {
+	query := `
+		SELECT id, organization_id, name, url
+		FROM mcp_servers
+		WHERE is_active = true AND (url LIKE 'https://%' OR url LIKE 'wss://%')
+		ORDER BY organization_id, name
+	`
+
+	rows, err := m.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []*types.TLSServerTarget
+	for rows.Next() {
+		target := &types.TLSServerTarget{}
+		if err := rows.Scan(&target.ServerID, &target.OrganizationID, &target.Name, &target.URL); err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, rows.Err()
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// OrganizationBrandingModel handles the branding of organizations' endpoint
+// docs
+type OrganizationBrandingModel struct {
+	db Database
+}
+
+// NewOrganizationBrandingModel creates a new organization branding model
+func NewOrganizationBrandingModel(db Database) *OrganizationBrandingModel {
+	return &OrganizationBrandingModel{db: db}
+}
+
+// Get returns the organization's branding, or nil when it has none
+func (m *OrganizationBrandingModel) Get(orgID string) (*types.OrganizationBranding, error) {
+	branding := &types.OrganizationBranding{}
+	err := m.db.QueryRow(`
+		SELECT organization_id, COALESCE(product_name, ''), COALESCE(logo_url, ''),
+			COALESCE(primary_color, ''), COALESCE(accent_color, ''), COALESCE(contact_name, ''),
+			COALESCE(contact_email, ''), COALESCE(contact_url, ''), COALESCE(updated_by::text, ''), updated_at
+		FROM organization_branding
+		WHERE organization_id = $1
+	`, orgID).Scan(&branding.OrganizationID, &branding.ProductName, &branding.LogoURL,
+		&branding.PrimaryColor, &branding.AccentColor, &branding.ContactName,
+		&branding.ContactEmail, &branding.ContactURL, &branding.UpdatedBy, &branding.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return branding, nil
+}
+
+// Set creates or replaces the organization's branding
+func (m *OrganizationBrandingModel) Set(branding *types.OrganizationBranding) error {
+	return m.db.QueryRow(`
+		INSERT INTO organization_branding (organization_id, product_name, logo_url, primary_color, accent_color,
+			contact_name, contact_email, contact_url, updated_by)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''),
+			NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, '')::uuid)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET product_name = EXCLUDED.product_name, logo_url = EXCLUDED.logo_url,
+			primary_color = EXCLUDED.primary_color, accent_color = EXCLUDED.accent_color,
+			contact_name = EXCLUDED.contact_name, contact_email = EXCLUDED.contact_email,
+			contact_url = EXCLUDED.contact_url, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING updated_at
+	`, branding.OrganizationID, branding.ProductName, branding.LogoURL, branding.PrimaryColor,
+		branding.AccentColor, branding.ContactName, branding.ContactEmail, branding.ContactURL,
+		branding.UpdatedBy).Scan(&branding.UpdatedAt)
+}
+
+// Delete removes the organization's branding
+func (m *OrganizationBrandingModel) Delete(orgID string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM organization_branding WHERE organization_id = $1`, orgID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// PartitionModel creates and removes the monthly partitions of log tables
+type PartitionModel struct {
+	db Database
+}
+
+// NewPartitionModel creates a new partition model
+func NewPartitionModel(db Database) *PartitionModel {
+	return &PartitionModel{db: db}
+}
+
+// CreateMonthlyPartition creates the partition of table for the UTC month
+// of month. It reports false when the month is already covered.
+func (m *PartitionModel) CreateMonthlyPartition(table string, month time.Time) (bool, error) {
+	var created bool
+	err := m.db.QueryRow(`SELECT create_monthly_partition($1, $2::date)`, table, month.UTC().Format("2006-01-02")).Scan(&created)
+	return created, err
+}
+
+// ListPartitions returns the partitions of table, oldest first
+func (m *PartitionModel) ListPartitions(table string) ([]*types.TablePartition, error) {
+	rows, err := m.db.Query(`
+		SELECT parent_table, partition_name, range_start, range_end
+		FROM table_partitions
+		WHERE parent_table = $1
+		ORDER BY range_start ASC NULLS FIRST
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	partitions := []*types.TablePartition{}
+	for rows.Next() {
+		partition := &types.TablePartition{}
+		var start, end sql.NullTime
+		if err := rows.Scan(&partition.Table, &partition.Name, &start, &end); err != nil {
+			return nil, err
+		}
+		if start.Valid {
+			partition.RangeStart = &start.Time
+		}
+		if end.Valid {
+			partition.RangeEnd = &end.Time
+		}
+		partitions = append(partitions, partition)
+	}
+	return partitions, rows.Err()
+}
+
+// HasHeldRows reports whether a partition holds rows of an organization
+// under an active legal hold
+func (m *PartitionModel) HasHeldRows(partition string) (bool, error) {
+	var held bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM legal_holds h
+			WHERE h.released_at IS NULL
+			  AND EXISTS (SELECT 1 FROM ` + pq.QuoteIdentifier(partition) + ` p WHERE p.organization_id = h.organization_id)
+		)
+	`).Scan(&held)
+	return held, err
+}
+
+// DetachPartition detaches a partition from table, leaving it as a
+// standalone table
+func (m *PartitionModel) DetachPartition(table, partition string) error {
+	_, err := m.db.Exec(`ALTER TABLE ` + pq.QuoteIdentifier(table) + ` DETACH PARTITION ` + pq.QuoteIdentifier(partition))
+	return err
+}
+
+// DropPartition drops a detached partition
+func (m *PartitionModel) DropPartition(partition string) error {
+	_, err := m.db.Exec(`DROP TABLE IF EXISTS ` + pq.QuoteIdentifier(partition))
+	return err
+}
+
+// LongestLogRetentionDays returns the longest log retention of any
+// organization
+func (m *PartitionModel) LongestLogRetentionDays() (int, error) {
+	var days int
+	err := m.db.QueryRow(`SELECT COALESCE(MAX(log_retention_days), 0) FROM organizations`).Scan(&days)
+	return days, err
+}
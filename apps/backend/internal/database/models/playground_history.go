@@ -0,0 +1,205 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	defaultPlaygroundHistoryLimit = 50
+	maxPlaygroundHistoryLimit     = 200
+)
+
+// PlaygroundHistoryModel handles users' playground execution history and
+// the organization settings that govern it
+type PlaygroundHistoryModel struct {
+	db Database
+}
+
+// NewPlaygroundHistoryModel creates a new playground history model
+func NewPlaygroundHistoryModel(db Database) *PlaygroundHistoryModel {
+	return &PlaygroundHistoryModel{db: db}
+}
+
+// Create records a playground execution
+func (m *PlaygroundHistoryModel) Create(execution *types.PlaygroundExecution) error {
+	arguments, err := json.Marshal(execution.Arguments)
+	if err != nil {
+		return fmt.Errorf("failed to marshal arguments: %w", err)
+	}
+	var result []byte
+	if execution.Result != nil {
+		if result, err = json.Marshal(execution.Result); err != nil {
+			return fmt.Errorf("failed to marshal result: %w", err)
+		}
+	}
+
+	return m.db.QueryRow(`
+		INSERT INTO playground_executions (organization_id, user_id, source, endpoint_id, server_id,
+			namespace_id, tool_name, arguments, result, is_error, error_message, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at
+	`, execution.OrganizationID, execution.UserID, execution.Source, nullIfEmpty(execution.EndpointID),
+		nullIfEmpty(execution.ServerID), nullIfEmpty(execution.NamespaceID), execution.ToolName,
+		arguments, result, execution.IsError, nullIfEmpty(execution.ErrorMessage), execution.DurationMS,
+	).Scan(&execution.ID, &execution.CreatedAt)
+}
+
+// List returns a user's playground executions, newest first
+func (m *PlaygroundHistoryModel) List(orgID, userID string, filter *types.PlaygroundHistoryQuery) ([]*types.PlaygroundExecution, error) {
+	query := playgroundExecutionSelect + ` WHERE organization_id = $1 AND user_id = $2`
+	args := []interface{}{orgID, userID}
+	argIndex := 3
+
+	addFilter := func(column, value string) {
+		if value == "" {
+			return
+		}
+		query += fmt.Sprintf(" AND %s = $%d", column, argIndex)
+		args = append(args, value)
+		argIndex++
+	}
+	addFilter("tool_name", filter.ToolName)
+	addFilter("source", filter.Source)
+	addFilter("endpoint_id", filter.EndpointID)
+	addFilter("server_id", filter.ServerID)
+
+	if filter.Search != "" {
+		query += fmt.Sprintf(" AND (tool_name ILIKE $%d OR arguments::text ILIKE $%d)", argIndex, argIndex)
+		args = append(args, "%"+filter.Search+"%")
+		argIndex++
+	}
+	if filter.ErrorsOnly {
+		query += " AND is_error = true"
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultPlaygroundHistoryLimit
+	}
+	if limit > maxPlaygroundHistoryLimit {
+		limit = maxPlaygroundHistoryLimit
+	}
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, limit, filter.Offset)
+
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list playground executions: %w", err)
+	}
+	defer rows.Close()
+
+	var executions []*types.PlaygroundExecution
+	for rows.Next() {
+		execution, err := scanPlaygroundExecution(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan playground execution: %w", err)
+		}
+		executions = append(executions, execution)
+	}
+	return executions, rows.Err()
+}
+
+// Get returns a playground execution within an organization, or nil when
+// there is none
+func (m *PlaygroundHistoryModel) Get(orgID, id string) (*types.PlaygroundExecution, error) {
+	execution, err := scanPlaygroundExecution(m.db.QueryRow(
+		playgroundExecutionSelect+` WHERE organization_id = $1 AND id = $2`, orgID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return execution, err
+}
+
+// Delete removes one of a user's playground executions
+func (m *PlaygroundHistoryModel) Delete(orgID, userID, id string) (bool, error) {
+	result, err := m.db.Exec(`
+		DELETE FROM playground_executions
+		WHERE organization_id = $1 AND user_id = $2 AND id = $3
+	`, orgID, userID, id)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+// Clear removes a user's whole playground history
+func (m *PlaygroundHistoryModel) Clear(orgID, userID string) (int64, error) {
+	result, err := m.db.Exec(`
+		DELETE FROM playground_executions WHERE organization_id = $1 AND user_id = $2
+	`, orgID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetSettings returns the organization's playground history settings, or
+// nil when it has none
+func (m *PlaygroundHistoryModel) GetSettings(orgID string) (*types.PlaygroundHistorySettings, error) {
+	settings := &types.PlaygroundHistorySettings{}
+	err := m.db.QueryRow(`
+		SELECT organization_id, enabled, admins_can_view, COALESCE(updated_by::text, ''), updated_at
+		FROM playground_history_settings
+		WHERE organization_id = $1
+	`, orgID).Scan(&settings.OrganizationID, &settings.Enabled, &settings.AdminsCanView,
+		&settings.UpdatedBy, &settings.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// SetSettings creates or replaces the organization's playground history
+// settings
+func (m *PlaygroundHistoryModel) SetSettings(settings *types.PlaygroundHistorySettings) error {
+	return m.db.QueryRow(`
+		INSERT INTO playground_history_settings (organization_id, enabled, admins_can_view, updated_by)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled, admins_can_view = EXCLUDED.admins_can_view,
+			updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING updated_at
+	`, settings.OrganizationID, settings.Enabled, settings.AdminsCanView, settings.UpdatedBy).Scan(&settings.UpdatedAt)
+}
+
+const playgroundExecutionSelect = `
+		SELECT id, organization_id, user_id, source, endpoint_id, server_id, namespace_id,
+		       tool_name, arguments, result, is_error, error_message, duration_ms, created_at
+		FROM playground_executions`
+
+func scanPlaygroundExecution(row rowScanner) (*types.PlaygroundExecution, error) {
+	execution := &types.PlaygroundExecution{}
+	var endpointID, serverID, namespaceID, errorMessage sql.NullString
+	var arguments, result []byte
+
+	err := row.Scan(&execution.ID, &execution.OrganizationID, &execution.UserID, &execution.Source,
+		&endpointID, &serverID, &namespaceID, &execution.ToolName, &arguments, &result,
+		&execution.IsError, &errorMessage, &execution.DurationMS, &execution.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	execution.EndpointID = endpointID.String
+	execution.ServerID = serverID.String
+	execution.NamespaceID = namespaceID.String
+	execution.ErrorMessage = errorMessage.String
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &execution.Arguments); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+		}
+	}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &execution.Result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+		}
+	}
+	return execution, nil
+}
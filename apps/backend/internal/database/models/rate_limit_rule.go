@@ -0,0 +1,115 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const rateLimitRuleColumns = `
+	id, organization_id, name, description, type, COALESCE(target_id, ''), algorithm, request_limit,
+	window_seconds, burst, priority, is_active, created_at, updated_at
+`
+
+// RateLimitRuleModel handles organizations' rate limit rules
+type RateLimitRuleModel struct {
+	db Database
+}
+
+// NewRateLimitRuleModel creates a new rate limit rule model
+func NewRateLimitRuleModel(db Database) *RateLimitRuleModel {
+	return &RateLimitRuleModel{db: db}
+}
+
+// ListRules returns the organization's rate limit rules, highest priority
+// first
+func (m *RateLimitRuleModel) ListRules(orgID string, activeOnly bool) ([]*types.RateLimitRule, error) {
+	query := `SELECT ` + rateLimitRuleColumns + ` FROM rate_limit_rules WHERE organization_id = $1`
+	if activeOnly {
+		query += ` AND is_active = true`
+	}
+	query += ` ORDER BY priority DESC, name`
+
+	rows, err := m.db.Query(query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*types.RateLimitRule{}
+	for rows.Next() {
+		rule, err := scanRateLimitRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetRule returns a rate limit rule of the organization, or nil when there
+// is none
+func (m *RateLimitRuleModel) GetRule(orgID, id string) (*types.RateLimitRule, error) {
+	rule, err := scanRateLimitRule(m.db.QueryRow(`
+		SELECT `+rateLimitRuleColumns+`
+		FROM rate_limit_rules
+		WHERE id = $1 AND organization_id = $2
+	`, id, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rule, err
+}
+
+// CreateRule inserts a rate limit rule
+func (m *RateLimitRuleModel) CreateRule(rule *types.RateLimitRule) error {
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+	return m.db.QueryRow(`
+		INSERT INTO rate_limit_rules (id, organization_id, name, description, type, target_id, algorithm,
+			request_limit, window_seconds, burst, priority, is_active)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, $11, $12)
+		RETURNING created_at, updated_at
+	`, rule.ID, rule.OrganizationID, rule.Name, rule.Description, rule.Type, rule.TargetID, rule.Algorithm,
+		rule.Limit, rule.WindowSeconds, rule.Burst, rule.Priority, rule.IsActive,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+}
+
+// UpdateRule saves a rate limit rule's settings
+func (m *RateLimitRuleModel) UpdateRule(rule *types.RateLimitRule) error {
+	return m.db.QueryRow(`
+		UPDATE rate_limit_rules
+		SET name = $3, description = $4, target_id = NULLIF($5, ''), algorithm = $6, request_limit = $7,
+			window_seconds = $8, burst = $9, priority = $10, is_active = $11
+		WHERE id = $1 AND organization_id = $2
+		RETURNING updated_at
+	`, rule.ID, rule.OrganizationID, rule.Name, rule.Description, rule.TargetID, rule.Algorithm, rule.Limit,
+		rule.WindowSeconds, rule.Burst, rule.Priority, rule.IsActive,
+	).Scan(&rule.UpdatedAt)
+}
+
+// DeleteRule removes a rate limit rule of the organization
+func (m *RateLimitRuleModel) DeleteRule(orgID, id string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM rate_limit_rules WHERE id = $1 AND organization_id = $2`, id, orgID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func scanRateLimitRule(row rowScanner) (*types.RateLimitRule, error) {
+	rule := &types.RateLimitRule{}
+	err := row.Scan(
+		&rule.ID, &rule.OrganizationID, &rule.Name, &rule.Description, &rule.Type, &rule.TargetID,
+		&rule.Algorithm, &rule.Limit, &rule.WindowSeconds, &rule.Burst, &rule.Priority, &rule.IsActive,
+		&rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
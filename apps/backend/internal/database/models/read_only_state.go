@@ -0,0 +1,51 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// ReadOnlyStateModel stores read-only mode as set through the API
+type ReadOnlyStateModel struct {
+	BaseModel
+}
+
+// NewReadOnlyStateModel creates a new read-only state model
+func NewReadOnlyStateModel(db Database) *ReadOnlyStateModel {
+	return &ReadOnlyStateModel{BaseModel: BaseModel{db: db}}
+}
+
+// Get returns the stored read-only state, or nil before it was first set
+func (m *ReadOnlyStateModel) Get() (*types.ReadOnlyStatus, error) {
+	status := types.ReadOnlyStatus{Source: types.ReadOnlySourceAPI}
+	var changedAt sql.NullTime
+	err := m.db.QueryRow(`
+		SELECT enabled, reason, changed_by, changed_at
+		FROM read_only_state
+	`).Scan(&status.Enabled, &status.Reason, &status.ChangedBy, &changedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if changedAt.Valid {
+		status.ChangedAt = &changedAt.Time
+	}
+	return &status, nil
+}
+
+// Save stores the read-only state
+func (m *ReadOnlyStateModel) Save(status *types.ReadOnlyStatus) error {
+	_, err := m.db.Exec(`
+		INSERT INTO read_only_state (id, enabled, reason, changed_by, changed_at)
+		VALUES (true, $1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			reason = EXCLUDED.reason,
+			changed_by = EXCLUDED.changed_by,
+			changed_at = EXCLUDED.changed_at
+	`, status.Enabled, status.Reason, status.ChangedBy, status.ChangedAt)
+	return err
+}
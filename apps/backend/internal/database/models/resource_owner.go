@@ -0,0 +1,199 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// ownedResourceTables maps owned resource types to their tables
+var ownedResourceTables = map[string]string{
+	types.OwnedResourceServer:    "mcp_servers",
+	types.OwnedResourceTool:      "mcp_tools",
+	types.OwnedResourceNamespace: "namespaces",
+	types.OwnedResourceEndpoint:  "endpoints",
+}
+
+// resourceOwnerQuery joins each ownership record with the resource's name,
+// the owner's name and why the resource is orphaned, if it is
+const resourceOwnerQuery = `
+	SELECT ro.resource_type, ro.resource_id, ro.organization_id, ro.owner_type, ro.owner_id,
+		ro.notes, ro.assigned_by, ro.assigned_at,
+		COALESCE(s.name, t.name, n.name, e.name, '') AS resource_name,
+		COALESCE(u.email, tm.name, '') AS owner_name,
+		CASE
+			WHEN ro.owner_type = 'user' AND u.id IS NULL THEN 'owner_deleted'
+			WHEN ro.owner_type = 'user' AND NOT COALESCE(u.is_active, true) THEN 'owner_deactivated'
+			WHEN ro.owner_type = 'team' AND tm.id IS NULL THEN 'team_deleted'
+			WHEN ro.owner_type = 'team' AND NOT EXISTS (
+				SELECT 1 FROM team_members m JOIN users mu ON mu.id = m.user_id
+				WHERE m.team_id = tm.id AND COALESCE(mu.is_active, true)
+			) THEN 'team_empty'
+			ELSE ''
+		END AS orphan_reason
+	FROM resource_owners ro
+	LEFT JOIN mcp_servers s ON ro.resource_type = 'server' AND s.id = ro.resource_id
+	LEFT JOIN mcp_tools t ON ro.resource_type = 'tool' AND t.id = ro.resource_id
+	LEFT JOIN namespaces n ON ro.resource_type = 'namespace' AND n.id = ro.resource_id
+	LEFT JOIN endpoints e ON ro.resource_type = 'endpoint' AND e.id = ro.resource_id
+	LEFT JOIN users u ON ro.owner_type = 'user' AND u.id = ro.owner_id
+	LEFT JOIN teams tm ON ro.owner_type = 'team' AND tm.id = ro.owner_id
+`
+
+// ResourceOwnerModel handles resource ownership database operations
+type ResourceOwnerModel struct {
+	db Database
+}
+
+// NewResourceOwnerModel creates a new resource owner model
+func NewResourceOwnerModel(db Database) *ResourceOwnerModel {
+	return &ResourceOwnerModel{db: db}
+}
+
+// ResourceOrganization returns the organization of a resource, or "" when
+// there is no such resource
+func (m *ResourceOwnerModel) ResourceOrganization(resourceType, resourceID string) (string, error) {
+	table, ok := ownedResourceTables[resourceType]
+	if !ok {
+		return "", nil
+	}
+	return m.queryOrganization(fmt.Sprintf(`SELECT organization_id FROM %s WHERE id = $1`, table), resourceID)
+}
+
+// OwnerOrganization returns the organization of a user or team, or "" when
+// there is no such owner or the user is inactive
+func (m *ResourceOwnerModel) OwnerOrganization(ownerType, ownerID string) (string, error) {
+	switch ownerType {
+	case types.ResourceOwnerUser:
+		return m.queryOrganization(`SELECT organization_id FROM users WHERE id = $1 AND COALESCE(is_active, true)`, ownerID)
+	case types.ResourceOwnerTeam:
+		return m.queryOrganization(`SELECT organization_id FROM teams WHERE id = $1`, ownerID)
+	}
+	return "", nil
+}
+
+// Get returns the owner of a resource, or nil when it has none
+func (m *ResourceOwnerModel) Get(resourceType, resourceID string) (*types.ResourceOwner, error) {
+	owners, err := m.queryOwners(resourceOwnerQuery+` WHERE ro.resource_type = $1 AND ro.resource_id = $2`, resourceType, resourceID)
+	if err != nil || len(owners) == 0 {
+		return nil, err
+	}
+	return owners[0], nil
+}
+
+// List returns the organization's ownership records matching filter
+func (m *ResourceOwnerModel) List(orgID string, filter *types.ResourceOwnerFilter) ([]*types.ResourceOwner, error) {
+	conditions := []string{"ro.organization_id = $1"}
+	args := []interface{}{orgID}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.ResourceType != "" {
+		add("ro.resource_type = $%d", filter.ResourceType)
+	}
+	if filter.OwnerType != "" {
+		add("ro.owner_type = $%d", filter.OwnerType)
+	}
+	if filter.OwnerID != "" {
+		add("ro.owner_id = $%d", filter.OwnerID)
+	}
+
+	query := `SELECT * FROM (` + resourceOwnerQuery + ` WHERE ` + strings.Join(conditions, " AND ") + `) owned`
+	if filter.OrphanedOnly {
+		query += ` WHERE orphan_reason <> ''`
+	}
+	query += ` ORDER BY resource_type, resource_name`
+	return m.queryOwners(query, args...)
+}
+
+// Upsert records the owner of a resource, replacing any previous owner
+func (m *ResourceOwnerModel) Upsert(owner *types.ResourceOwner) error {
+	query := `
+		INSERT INTO resource_owners (resource_type, resource_id, organization_id, owner_type, owner_id, notes, assigned_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (resource_type, resource_id) DO UPDATE SET
+			owner_type = EXCLUDED.owner_type,
+			owner_id = EXCLUDED.owner_id,
+			notes = EXCLUDED.notes,
+			assigned_by = EXCLUDED.assigned_by,
+			assigned_at = NOW()
+		RETURNING assigned_at
+	`
+
+	return m.db.QueryRow(query,
+		owner.ResourceType, owner.ResourceID, owner.OrganizationID, owner.OwnerType,
+		owner.OwnerID, owner.Notes, owner.AssignedBy,
+	).Scan(&owner.AssignedAt)
+}
+
+// Delete removes the owner of a resource
+func (m *ResourceOwnerModel) Delete(resourceType, resourceID string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM resource_owners WHERE resource_type = $1 AND resource_id = $2`, resourceType, resourceID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Transfer moves the organization's resources owned by one owner to
+// another, optionally only resources of one type or with the given IDs
+func (m *ResourceOwnerModel) Transfer(orgID string, req *types.TransferOwnershipRequest, assignedBy string) (int64, error) {
+	query := `
+		UPDATE resource_owners SET owner_type = $1, owner_id = $2, assigned_by = $3, assigned_at = NOW()
+		WHERE organization_id = $4 AND owner_type = $5 AND owner_id = $6
+	`
+	args := []interface{}{req.ToType, req.ToID, assignedBy, orgID, req.FromType, req.FromID}
+	if req.ResourceType != "" {
+		args = append(args, req.ResourceType)
+		query += fmt.Sprintf(` AND resource_type = $%d`, len(args))
+	}
+	if len(req.ResourceIDs) > 0 {
+		args = append(args, pq.Array(req.ResourceIDs))
+		query += fmt.Sprintf(` AND resource_id = ANY($%d::uuid[])`, len(args))
+	}
+
+	result, err := m.db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (m *ResourceOwnerModel) queryOrganization(query, id string) (string, error) {
+	var orgID string
+	err := m.db.QueryRow(query, id).Scan(&orgID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return orgID, err
+}
+
+func (m *ResourceOwnerModel) queryOwners(query string, args ...interface{}) ([]*types.ResourceOwner, error) {
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owners := []*types.ResourceOwner{}
+	for rows.Next() {
+		owner := &types.ResourceOwner{}
+		if err := rows.Scan(
+			&owner.ResourceType, &owner.ResourceID, &owner.OrganizationID, &owner.OwnerType, &owner.OwnerID,
+			&owner.Notes, &owner.AssignedBy, &owner.AssignedAt, &owner.ResourceName, &owner.OwnerName,
+			&owner.OrphanReason,
+		); err != nil {
+			return nil, err
+		}
+		owner.Orphaned = owner.OrphanReason != ""
+		owners = append(owners, owner)
+	}
+
+	return owners, rows.Err()
+}
@@ -0,0 +1,333 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// retentionTable locates the rows of an organization past a cutoff in one
+// of the tables purged by retention. The condition uses $1 for the
+// organization and $2 for the cutoff.
+type retentionTable struct {
+	key        string
+	order      string
+	timeColumn string
+	condition  string
+}
+
+var retentionTables = map[string]retentionTable{
+	// Execution logs of organizations archiving them are left to the
+	// archive job, and rows rehydrated from archives stay until their
+	// rehydration expires
+	types.RetentionTableExecutionLogs: {
+		key:        "id, started_at",
+		order:      "started_at",
+		timeColumn: "started_at",
+		condition: `organization_id = $1 AND started_at < $2
+			AND NOT EXISTS (SELECT 1 FROM archive_policies p WHERE p.organization_id = $1 AND p.enabled)
+			AND NOT EXISTS (
+				SELECT 1 FROM archive_rehydrations r
+				WHERE r.organization_id = $1 AND '` + types.ArchiveDatasetExecutionLogs + `' = ANY(r.datasets)
+				  AND r.start_time <= started_at AND r.end_time > started_at
+				  AND (r.status = 'running' OR (r.status = 'completed' AND r.expires_at > NOW()))
+			)`,
+	},
+	// Chained records go in sequence order, and not before every enabled
+	// audit sink has exported them
+	types.RetentionTableAuditLogs: {
+		key:        "id",
+		order:      "sequence NULLS FIRST, created_at",
+		timeColumn: "created_at",
+		condition: `organization_id = $1 AND created_at < $2
+			AND (sequence IS NULL OR sequence <= COALESCE((
+				SELECT MIN(s.cursor_sequence) FROM audit_sinks s
+				WHERE s.organization_id = $1 AND s.enabled
+			), sequence))`,
+	},
+	types.RetentionTableHealthChecks: {
+		key:        "id, checked_at",
+		order:      "checked_at",
+		timeColumn: "checked_at",
+		condition:  `server_id IN (SELECT id FROM mcp_servers WHERE organization_id = $1) AND checked_at < $2`,
+	},
+}
+
+// RetentionModel removes the rows of organizations past their log retention
+type RetentionModel struct {
+	db Database
+}
+
+// NewRetentionModel creates a new retention model
+func NewRetentionModel(db Database) *RetentionModel {
+	return &RetentionModel{db: db}
+}
+
+// ListRetentions returns the log retention of every organization, per
+// table where its retention policy sets one
+func (m *RetentionModel) ListRetentions() ([]*types.OrganizationRetention, error) {
+	rows, err := m.db.Query(logRetentionPolicySelect + ` ORDER BY o.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var retentions []*types.OrganizationRetention
+	for rows.Next() {
+		policy, err := scanLogRetentionPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		retention := &types.OrganizationRetention{
+			OrganizationID:   policy.OrganizationID,
+			LogRetentionDays: policy.DefaultDays,
+			Days:             make(map[string]int),
+		}
+		for _, table := range types.RetentionTables {
+			if days := policy.TableDays(table); days != nil {
+				retention.Days[table] = *days
+			}
+		}
+		if policy.Export.Enabled {
+			retention.Export = &policy.Export
+		}
+		retentions = append(retentions, retention)
+	}
+	return retentions, rows.Err()
+}
+
+// GetPolicy returns an organization's retention policy, or nil when the
+// organization does not exist
+func (m *RetentionModel) GetPolicy(orgID string) (*types.LogRetentionPolicy, error) {
+	policy, err := scanLogRetentionPolicy(m.db.QueryRow(logRetentionPolicySelect+` WHERE o.id = $1`, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return policy, err
+}
+
+// UpsertPolicy creates or replaces an organization's retention policy
+func (m *RetentionModel) UpsertPolicy(policy *types.LogRetentionPolicy) error {
+	export := policy.Export
+	var updatedAt time.Time
+	err := m.db.QueryRow(`
+		INSERT INTO log_retention_policies (
+			organization_id, request_log_days, audit_log_days, health_check_days,
+			export_enabled, export_endpoint, export_region, export_bucket, export_prefix,
+			export_access_key_id, export_secret_access_key, export_use_path_style, updated_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET request_log_days = EXCLUDED.request_log_days, audit_log_days = EXCLUDED.audit_log_days,
+		    health_check_days = EXCLUDED.health_check_days, export_enabled = EXCLUDED.export_enabled,
+		    export_endpoint = EXCLUDED.export_endpoint, export_region = EXCLUDED.export_region,
+		    export_bucket = EXCLUDED.export_bucket, export_prefix = EXCLUDED.export_prefix,
+		    export_access_key_id = EXCLUDED.export_access_key_id,
+		    export_secret_access_key = EXCLUDED.export_secret_access_key,
+		    export_use_path_style = EXCLUDED.export_use_path_style, updated_by = EXCLUDED.updated_by
+		RETURNING updated_at
+	`, policy.OrganizationID, policy.RequestLogDays, policy.AuditLogDays, policy.HealthCheckDays,
+		export.Enabled, export.Endpoint, export.Region, export.Bucket, export.Prefix,
+		export.AccessKeyID, export.SecretAccessKey, export.UsePathStyle, nullIfEmpty(policy.UpdatedBy)).Scan(&updatedAt)
+	if err != nil {
+		return err
+	}
+	policy.UpdatedAt = &updatedAt
+	return nil
+}
+
+// HasActiveLegalHold reports whether an organization is under legal hold
+func (m *RetentionModel) HasActiveLegalHold(orgID string) (bool, error) {
+	var held bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM legal_holds WHERE organization_id = $1 AND released_at IS NULL)
+	`, orgID).Scan(&held)
+	return held, err
+}
+
+// CountExpired counts the organization's rows of table older than cutoff
+func (m *RetentionModel) CountExpired(table, orgID string, cutoff time.Time) (int64, error) {
+	t, ok := retentionTables[table]
+	if !ok {
+		return 0, fmt.Errorf("unknown retention table %q", table)
+	}
+	var count int64
+	err := m.db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE `+t.condition, orgID, cutoff).Scan(&count)
+	return count, err
+}
+
+// PurgeExpired deletes up to limit of the organization's oldest rows of
+// table older than cutoff and returns how many it deleted
+func (m *RetentionModel) PurgeExpired(table, orgID string, cutoff time.Time, limit int) (int64, error) {
+	t, ok := retentionTables[table]
+	if !ok {
+		return 0, fmt.Errorf("unknown retention table %q", table)
+	}
+	result, err := m.db.Exec(`
+		DELETE FROM `+table+` WHERE (`+t.key+`) IN (
+			SELECT `+t.key+` FROM `+table+`
+			WHERE `+t.condition+`
+			ORDER BY `+t.order+`
+			LIMIT $3
+		)`, orgID, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ExportExpired calls fn with up to limit of the organization's oldest rows
+// of table older than cutoff, as JSON
+func (m *RetentionModel) ExportExpired(table, orgID string, cutoff time.Time, limit int, fn func(row *types.ArchiveRow) error) error {
+	t, ok := retentionTables[table]
+	if !ok {
+		return fmt.Errorf("unknown retention table %q", table)
+	}
+	rows, err := m.db.Query(`
+		SELECT t.id::text, row_to_json(t)::text FROM `+table+` t
+		WHERE `+t.condition+`
+		ORDER BY `+t.order+`
+		LIMIT $3`, orgID, cutoff, limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := &types.ArchiveRow{}
+		var data string
+		if err := rows.Scan(&row.ID, &data); err != nil {
+			return err
+		}
+		row.Data = []byte(data)
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// DeleteExpired deletes the rows of ids from table that are still past the
+// organization's cutoff and returns how many it deleted
+func (m *RetentionModel) DeleteExpired(table, orgID string, cutoff time.Time, ids []string) (int64, error) {
+	t, ok := retentionTables[table]
+	if !ok {
+		return 0, fmt.Errorf("unknown retention table %q", table)
+	}
+	result, err := m.db.Exec(`DELETE FROM `+table+` WHERE id = ANY($3::uuid[]) AND `+t.condition,
+		orgID, cutoff, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CountExpiringByDay counts the organization's rows of table from from
+// until they pass cutoff, by the UTC day they were written
+func (m *RetentionModel) CountExpiringByDay(table, orgID string, from, cutoff time.Time) ([]*types.RetentionPurgeDay, error) {
+	t, ok := retentionTables[table]
+	if !ok {
+		return nil, fmt.Errorf("unknown retention table %q", table)
+	}
+	rows, err := m.db.Query(`
+		SELECT to_char(`+t.timeColumn+` AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(*)
+		FROM `+table+`
+		WHERE `+t.condition+` AND `+t.timeColumn+` >= $3
+		GROUP BY day ORDER BY day`, orgID, cutoff, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []*types.RetentionPurgeDay{}
+	for rows.Next() {
+		var day string
+		count := &types.RetentionPurgeDay{}
+		if err := rows.Scan(&day, &count.Rows); err != nil {
+			return nil, err
+		}
+		if count.Date, err = time.Parse("2006-01-02", day); err != nil {
+			return nil, err
+		}
+		days = append(days, count)
+	}
+	return days, rows.Err()
+}
+
+// RecordExport records an export of purged rows
+func (m *RetentionModel) RecordExport(export *types.RetentionExport) error {
+	return m.db.QueryRow(`
+		INSERT INTO log_retention_exports (organization_id, log_table, cutoff, bucket, storage_key, sha256, row_count, size_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, export.OrganizationID, export.Table, export.Cutoff, export.Bucket, export.StorageKey,
+		export.SHA256, export.RowCount, export.SizeBytes).Scan(&export.ID, &export.CreatedAt)
+}
+
+// ListExports returns up to limit of an organization's exports, newest
+// first
+func (m *RetentionModel) ListExports(orgID string, limit int) ([]*types.RetentionExport, error) {
+	rows, err := m.db.Query(`
+		SELECT id, organization_id, log_table, cutoff, bucket, storage_key, sha256, row_count, size_bytes, created_at
+		FROM log_retention_exports
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`, orgID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exports := []*types.RetentionExport{}
+	for rows.Next() {
+		export := &types.RetentionExport{}
+		if err := rows.Scan(&export.ID, &export.OrganizationID, &export.Table, &export.Cutoff, &export.Bucket,
+			&export.StorageKey, &export.SHA256, &export.RowCount, &export.SizeBytes, &export.CreatedAt); err != nil {
+			return nil, err
+		}
+		exports = append(exports, export)
+	}
+	return exports, rows.Err()
+}
+
+const logRetentionPolicySelect = `
+		SELECT o.id, COALESCE(o.log_retention_days, 0), p.request_log_days, p.audit_log_days, p.health_check_days,
+		       COALESCE(p.export_enabled, false), COALESCE(p.export_endpoint, ''), COALESCE(p.export_region, ''),
+		       COALESCE(p.export_bucket, ''), COALESCE(p.export_prefix, ''), COALESCE(p.export_access_key_id, ''),
+		       COALESCE(p.export_secret_access_key, ''), COALESCE(p.export_use_path_style, false),
+		       p.updated_by, p.updated_at
+		FROM organizations o
+		LEFT JOIN log_retention_policies p ON p.organization_id = o.id`
+
+func scanLogRetentionPolicy(row rowScanner) (*types.LogRetentionPolicy, error) {
+	policy := &types.LogRetentionPolicy{}
+	export := &policy.Export
+	var requestDays, auditDays, healthDays sql.NullInt64
+	var updatedBy sql.NullString
+	var updatedAt sql.NullTime
+	if err := row.Scan(&policy.OrganizationID, &policy.DefaultDays, &requestDays, &auditDays, &healthDays,
+		&export.Enabled, &export.Endpoint, &export.Region, &export.Bucket, &export.Prefix, &export.AccessKeyID,
+		&export.SecretAccessKey, &export.UsePathStyle, &updatedBy, &updatedAt); err != nil {
+		return nil, err
+	}
+	policy.RequestLogDays = nullIntPtr(requestDays)
+	policy.AuditLogDays = nullIntPtr(auditDays)
+	policy.HealthCheckDays = nullIntPtr(healthDays)
+	export.HasSecret = export.SecretAccessKey != ""
+	policy.UpdatedBy = updatedBy.String
+	if updatedAt.Valid {
+		policy.UpdatedAt = &updatedAt.Time
+	}
+	return policy, nil
+}
+
+func nullIntPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	days := int(n.Int64)
+	return &days
+}
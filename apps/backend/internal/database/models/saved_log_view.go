@@ -0,0 +1,142 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// SavedLogViewModel handles saved log and audit views
+type SavedLogViewModel struct {
+	db Database
+}
+
+// NewSavedLogViewModel creates a new saved log view model
+func NewSavedLogViewModel(db Database) *SavedLogViewModel {
+	return &SavedLogViewModel{db: db}
+}
+
+// Create saves a log view
+func (m *SavedLogViewModel) Create(view *types.SavedLogView) error {
+	filters, widgets, err := marshalLogView(view)
+	if err != nil {
+		return err
+	}
+
+	return m.db.QueryRow(`
+		INSERT INTO saved_log_views (organization_id, owner_id, name, description, kind, filters, widgets, shared)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`, view.OrganizationID, view.OwnerID, view.Name, nullIfEmpty(view.Description), view.Kind,
+		filters, widgets, view.Shared,
+	).Scan(&view.ID, &view.CreatedAt, &view.UpdatedAt)
+}
+
+// List returns the user's own views and the views shared in the
+// organization, by name
+func (m *SavedLogViewModel) List(orgID, userID, kind string) ([]*types.SavedLogView, error) {
+	query := savedLogViewSelect + ` WHERE organization_id = $1 AND (owner_id = $2 OR shared)`
+	args := []interface{}{orgID, userID}
+	if kind != "" {
+		query += ` AND kind = $3`
+		args = append(args, kind)
+	}
+	query += ` ORDER BY name, created_at`
+
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved log views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []*types.SavedLogView
+	for rows.Next() {
+		view, err := scanSavedLogView(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saved log view: %w", err)
+		}
+		views = append(views, view)
+	}
+	return views, rows.Err()
+}
+
+// Get returns a saved log view within an organization, or nil when there
+// is none
+func (m *SavedLogViewModel) Get(orgID, id string) (*types.SavedLogView, error) {
+	view, err := scanSavedLogView(m.db.QueryRow(
+		savedLogViewSelect+` WHERE organization_id = $1 AND id = $2`, orgID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return view, err
+}
+
+// Update saves the name, description, filters, widgets and sharing of a view
+func (m *SavedLogViewModel) Update(view *types.SavedLogView) error {
+	filters, widgets, err := marshalLogView(view)
+	if err != nil {
+		return err
+	}
+
+	return m.db.QueryRow(`
+		UPDATE saved_log_views
+		SET name = $3, description = $4, filters = $5, widgets = $6, shared = $7, updated_at = NOW()
+		WHERE organization_id = $1 AND id = $2
+		RETURNING updated_at
+	`, view.OrganizationID, view.ID, view.Name, nullIfEmpty(view.Description), filters, widgets, view.Shared,
+	).Scan(&view.UpdatedAt)
+}
+
+// Delete removes a saved log view
+func (m *SavedLogViewModel) Delete(orgID, id string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM saved_log_views WHERE organization_id = $1 AND id = $2`, orgID, id)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+const savedLogViewSelect = `
+		SELECT id, organization_id, owner_id, name, description, kind, filters, widgets, shared,
+		       created_at, updated_at
+		FROM saved_log_views`
+
+func marshalLogView(view *types.SavedLogView) ([]byte, []byte, error) {
+	filters, err := json.Marshal(view.Filters)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal filters: %w", err)
+	}
+	widgets := view.Widgets
+	if widgets == nil {
+		widgets = []types.LogWidget{}
+	}
+	encodedWidgets, err := json.Marshal(widgets)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal widgets: %w", err)
+	}
+	return filters, encodedWidgets, nil
+}
+
+func scanSavedLogView(row rowScanner) (*types.SavedLogView, error) {
+	view := &types.SavedLogView{}
+	var description sql.NullString
+	var filters, widgets []byte
+
+	err := row.Scan(&view.ID, &view.OrganizationID, &view.OwnerID, &view.Name, &description, &view.Kind,
+		&filters, &widgets, &view.Shared, &view.CreatedAt, &view.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	view.Description = description.String
+	if err := json.Unmarshal(filters, &view.Filters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal filters: %w", err)
+	}
+	if err := json.Unmarshal(widgets, &view.Widgets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal widgets: %w", err)
+	}
+	return view, nil
+}
@@ -0,0 +1,223 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const serverGroupColumns = `id, organization_id, name, description, strategy, created_at, updated_at`
+
+// ServerGroupModel handles server groups and their replicas
+type ServerGroupModel struct {
+	db Database
+}
+
+// NewServerGroupModel creates a new server group model
+func NewServerGroupModel(db Database) *ServerGroupModel {
+	return &ServerGroupModel{db: db}
+}
+
+// ServerInOrganization reports whether a server belongs to the organization
+func (m *ServerGroupModel) ServerInOrganization(orgID, serverID string) (bool, error) {
+	var exists bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM mcp_servers WHERE id = $1 AND organization_id = $2)
+	`, serverID, orgID).Scan(&exists)
+	return exists, err
+}
+
+// List returns the organization's server groups with their replicas, by
+// name
+func (m *ServerGroupModel) List(orgID string) ([]*types.ServerGroup, error) {
+	rows, err := m.db.Query(`
+		SELECT `+serverGroupColumns+`
+		FROM server_groups
+		WHERE organization_id = $1
+		ORDER BY name
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []*types.ServerGroup{}
+	for rows.Next() {
+		group, err := scanServerGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groups, m.loadMembers(groups)
+}
+
+// Get returns a server group of the organization with its replicas, or nil
+// when there is none
+func (m *ServerGroupModel) Get(orgID, id string) (*types.ServerGroup, error) {
+	group, err := scanServerGroup(m.db.QueryRow(`
+		SELECT `+serverGroupColumns+`
+		FROM server_groups
+		WHERE id = $1 AND organization_id = $2
+	`, id, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return group, m.loadMembers([]*types.ServerGroup{group})
+}
+
+// GetByServer returns the group a server is a replica of with all its
+// replicas, or nil when the server is in no group
+func (m *ServerGroupModel) GetByServer(serverID string) (*types.ServerGroup, error) {
+	group, err := scanServerGroup(m.db.QueryRow(`
+		SELECT g.id, g.organization_id, g.name, g.description, g.strategy, g.created_at, g.updated_at
+		FROM server_groups g
+		JOIN server_group_members m ON m.group_id = g.id
+		WHERE m.server_id = $1
+	`, serverID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return group, m.loadMembers([]*types.ServerGroup{group})
+}
+
+// Create inserts a server group and its replicas
+func (m *ServerGroupModel) Create(group *types.ServerGroup) error {
+	if group.ID == "" {
+		group.ID = uuid.New().String()
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO server_groups (id, organization_id, name, description, strategy)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`, group.ID, group.OrganizationID, group.Name, group.Description, group.Strategy,
+	).Scan(&group.CreatedAt, &group.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	for _, member := range group.Members {
+		if _, err := tx.Exec(`
+			INSERT INTO server_group_members (group_id, server_id, weight) VALUES ($1, $2, $3)
+		`, group.ID, member.ServerID, member.Weight); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Update saves a server group's name, description and strategy
+func (m *ServerGroupModel) Update(group *types.ServerGroup) error {
+	return m.db.QueryRow(`
+		UPDATE server_groups
+		SET name = $3, description = $4, strategy = $5
+		WHERE id = $1 AND organization_id = $2
+		RETURNING updated_at
+	`, group.ID, group.OrganizationID, group.Name, group.Description, group.Strategy,
+	).Scan(&group.UpdatedAt)
+}
+
+// Delete removes a server group of the organization; its servers stay
+// registered
+func (m *ServerGroupModel) Delete(orgID, id string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM server_groups WHERE id = $1 AND organization_id = $2`, id, orgID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// SetMember adds a replica to a group, or changes its weight
+func (m *ServerGroupModel) SetMember(groupID, serverID string, weight int) error {
+	_, err := m.db.Exec(`
+		INSERT INTO server_group_members (group_id, server_id, weight)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (group_id, server_id) DO UPDATE SET weight = EXCLUDED.weight
+	`, groupID, serverID, weight)
+	return err
+}
+
+// RemoveMember removes a replica from a group
+func (m *ServerGroupModel) RemoveMember(groupID, serverID string) (bool, error) {
+	result, err := m.db.Exec(`
+		DELETE FROM server_group_members WHERE group_id = $1 AND server_id = $2
+	`, groupID, serverID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// loadMembers fills in the replicas of groups, by server name
+func (m *ServerGroupModel) loadMembers(groups []*types.ServerGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	byID := make(map[string]*types.ServerGroup, len(groups))
+	ids := make([]string, 0, len(groups))
+	for _, group := range groups {
+		group.Members = []types.ServerGroupMember{}
+		byID[group.ID] = group
+		ids = append(ids, group.ID)
+	}
+
+	rows, err := m.db.Query(`
+		SELECT m.group_id, m.server_id, s.name, s.status, COALESCE(s.metadata->>'region', ''),
+			m.weight, s.is_active
+		FROM server_group_members m
+		JOIN mcp_servers s ON s.id = m.server_id
+		WHERE m.group_id = ANY($1)
+		ORDER BY s.name, m.server_id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var groupID string
+		var member types.ServerGroupMember
+		if err := rows.Scan(
+			&groupID, &member.ServerID, &member.ServerName, &member.Status, &member.Region,
+			&member.Weight, &member.IsActive,
+		); err != nil {
+			return err
+		}
+		if group, ok := byID[groupID]; ok {
+			group.Members = append(group.Members, member)
+		}
+	}
+	return rows.Err()
+}
+
+func scanServerGroup(row rowScanner) (*types.ServerGroup, error) {
+	group := &types.ServerGroup{}
+	err := row.Scan(
+		&group.ID, &group.OrganizationID, &group.Name, &group.Description, &group.Strategy,
+		&group.CreatedAt, &group.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
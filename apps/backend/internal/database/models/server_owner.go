@@ -0,0 +1,215 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// ServerOwnerModel handles server owner and owner alert database operations
+type ServerOwnerModel struct {
+	db Database
+}
+
+// NewServerOwnerModel creates a new server owner model
+func NewServerOwnerModel(db Database) *ServerOwnerModel {
+	return &ServerOwnerModel{db: db}
+}
+
+// GetServerOrganization returns the organization of an active server, or ""
+// when there is no such server
+func (m *ServerOwnerModel) GetServerOrganization(serverID string) (string, error) {
+	var orgID string
+	err := m.db.QueryRow(`SELECT organization_id FROM mcp_servers WHERE id = $1 AND is_active = true`, serverID).Scan(&orgID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return orgID, err
+}
+
+// GetOwner returns the server's owner, or nil when none is recorded
+func (m *ServerOwnerModel) GetOwner(serverID string) (*types.ServerOwner, error) {
+	query := `
+		SELECT server_id, organization_id, contact_name, contact_email, webhook_url,
+			webhook_secret, escalate_after_minutes, created_at, updated_at
+		FROM server_owners
+		WHERE server_id = $1
+	`
+
+	owner := &types.ServerOwner{}
+	var escalateAfter sql.NullInt32
+	err := m.db.QueryRow(query, serverID).Scan(
+		&owner.ServerID, &owner.OrganizationID, &owner.ContactName, &owner.ContactEmail, &owner.WebhookURL,
+		&owner.WebhookSecret, &escalateAfter, &owner.CreatedAt, &owner.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if escalateAfter.Valid {
+		minutes := int(escalateAfter.Int32)
+		owner.EscalateAfterMinutes = &minutes
+	}
+	owner.HasWebhookSecret = owner.WebhookSecret != ""
+	return owner, nil
+}
+
+// UpsertOwner records the server's owner, replacing any previous one
+func (m *ServerOwnerModel) UpsertOwner(owner *types.ServerOwner) error {
+	query := `
+		INSERT INTO server_owners (server_id, organization_id, contact_name, contact_email,
+			webhook_url, webhook_secret, escalate_after_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (server_id) DO UPDATE SET
+			contact_name = EXCLUDED.contact_name,
+			contact_email = EXCLUDED.contact_email,
+			webhook_url = EXCLUDED.webhook_url,
+			webhook_secret = EXCLUDED.webhook_secret,
+			escalate_after_minutes = EXCLUDED.escalate_after_minutes
+		RETURNING created_at, updated_at
+	`
+
+	var escalateAfter sql.NullInt32
+	if owner.EscalateAfterMinutes != nil {
+		escalateAfter = sql.NullInt32{Int32: int32(*owner.EscalateAfterMinutes), Valid: true}
+	}
+	return m.db.QueryRow(query,
+		owner.ServerID, owner.OrganizationID, owner.ContactName, owner.ContactEmail,
+		owner.WebhookURL, owner.WebhookSecret, escalateAfter,
+	).Scan(&owner.CreatedAt, &owner.UpdatedAt)
+}
+
+// DeleteOwner removes the server's owner
+func (m *ServerOwnerModel) DeleteOwner(serverID string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM server_owners WHERE server_id = $1`, serverID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CreateAlert inserts an owner alert
+func (m *ServerOwnerModel) CreateAlert(alert *types.ServerOwnerAlert) error {
+	query := `
+		INSERT INTO server_owner_alerts (id, server_id, organization_id, previous_status,
+			status, health_status, resolved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+
+	if alert.ID == "" {
+		alert.ID = uuid.New().String()
+	}
+
+	return m.db.QueryRow(query,
+		alert.ID, alert.ServerID, alert.OrganizationID, alert.PreviousStatus,
+		alert.Status, alert.HealthStatus, alert.ResolvedAt,
+	).Scan(&alert.CreatedAt)
+}
+
+// MarkDelivered records the outcome of delivering an alert. A nil
+// deliveredAt means every delivery failed.
+func (m *ServerOwnerModel) MarkDelivered(id string, deliveredAt *time.Time, deliveryError string) error {
+	_, err := m.db.Exec(`
+		UPDATE server_owner_alerts SET delivered_at = COALESCE($2, delivered_at), delivery_error = $3
+		WHERE id = $1
+	`, id, deliveredAt, deliveryError)
+	return err
+}
+
+// Acknowledge marks an alert of the server as acknowledged by userID.
+// Acknowledging again keeps the first acknowledgement.
+func (m *ServerOwnerModel) Acknowledge(serverID, id, userID string) (bool, error) {
+	result, err := m.db.Exec(`
+		UPDATE server_owner_alerts SET
+			acknowledged_by = CASE WHEN acknowledged_at IS NULL THEN $3 ELSE acknowledged_by END,
+			acknowledged_at = COALESCE(acknowledged_at, NOW())
+		WHERE id = $1 AND server_id = $2
+	`, id, serverID, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ResolveOpen closes the server's open alerts, e.g. once it recovered
+func (m *ServerOwnerModel) ResolveOpen(serverID string) (int64, error) {
+	result, err := m.db.Exec(`
+		UPDATE server_owner_alerts SET resolved_at = NOW()
+		WHERE server_id = $1 AND acknowledged_at IS NULL AND resolved_at IS NULL AND escalated_at IS NULL
+	`, serverID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ListAlerts returns the server's most recent alerts, newest first
+func (m *ServerOwnerModel) ListAlerts(serverID string, limit int) ([]*types.ServerOwnerAlert, error) {
+	query := `
+		SELECT a.id, a.server_id, a.organization_id, s.name, a.previous_status, a.status,
+			a.health_status, a.delivery_error, a.delivered_at, a.acknowledged_at, a.acknowledged_by,
+			a.resolved_at, a.escalated_at, a.created_at
+		FROM server_owner_alerts a
+		JOIN mcp_servers s ON s.id = a.server_id
+		WHERE a.server_id = $1
+		ORDER BY a.created_at DESC
+		LIMIT $2
+	`
+
+	return m.queryAlerts(query, serverID, limit)
+}
+
+// ListEscalationDue returns open alerts older than their owner's escalation
+// period, using defaultMinutes for owners without one
+func (m *ServerOwnerModel) ListEscalationDue(defaultMinutes int) ([]*types.ServerOwnerAlert, error) {
+	query := `
+		SELECT a.id, a.server_id, a.organization_id, s.name, a.previous_status, a.status,
+			a.health_status, a.delivery_error, a.delivered_at, a.acknowledged_at, a.acknowledged_by,
+			a.resolved_at, a.escalated_at, a.created_at
+		FROM server_owner_alerts a
+		JOIN server_owners o ON o.server_id = a.server_id
+		JOIN mcp_servers s ON s.id = a.server_id
+		WHERE a.acknowledged_at IS NULL AND a.resolved_at IS NULL AND a.escalated_at IS NULL
+			AND a.created_at <= NOW() - make_interval(mins => COALESCE(o.escalate_after_minutes, $1))
+		ORDER BY a.created_at
+	`
+
+	return m.queryAlerts(query, defaultMinutes)
+}
+
+// MarkEscalated records that an alert was escalated
+func (m *ServerOwnerModel) MarkEscalated(id string) error {
+	_, err := m.db.Exec(`UPDATE server_owner_alerts SET escalated_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+func (m *ServerOwnerModel) queryAlerts(query string, args ...interface{}) ([]*types.ServerOwnerAlert, error) {
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []*types.ServerOwnerAlert{}
+	for rows.Next() {
+		alert := &types.ServerOwnerAlert{}
+		if err := rows.Scan(
+			&alert.ID, &alert.ServerID, &alert.OrganizationID, &alert.ServerName, &alert.PreviousStatus, &alert.Status,
+			&alert.HealthStatus, &alert.DeliveryError, &alert.DeliveredAt, &alert.AcknowledgedAt, &alert.AcknowledgedBy,
+			&alert.ResolvedAt, &alert.EscalatedAt, &alert.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, rows.Err()
+}
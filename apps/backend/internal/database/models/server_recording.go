@@ -0,0 +1,183 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const serverRecordingColumns = `
+	id, server_id, fingerprint, method, params, result, error, replay_count, recorded_at, last_replayed_at
+`
+
+// ServerRecordingModel handles recorded upstream responses and the recording
+// modes of servers
+type ServerRecordingModel struct {
+	db Database
+}
+
+// NewServerRecordingModel creates a new server recording model
+func NewServerRecordingModel(db Database) *ServerRecordingModel {
+	return &ServerRecordingModel{db: db}
+}
+
+// ServerExists reports whether a server belongs to the organization
+func (m *ServerRecordingModel) ServerExists(orgID, serverID string) (bool, error) {
+	var exists bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM mcp_servers WHERE id = $1 AND organization_id = $2)
+	`, serverID, orgID).Scan(&exists)
+	return exists, err
+}
+
+// GetMode returns the recording mode of a server
+func (m *ServerRecordingModel) GetMode(serverID string) (string, error) {
+	var mode string
+	err := m.db.QueryRow(`SELECT mode FROM server_recording_modes WHERE server_id = $1`, serverID).Scan(&mode)
+	if err == sql.ErrNoRows {
+		return types.RecordingModeOff, nil
+	}
+	return mode, err
+}
+
+// SetMode changes the recording mode of a server
+func (m *ServerRecordingModel) SetMode(serverID, mode string) error {
+	if mode == types.RecordingModeOff {
+		_, err := m.db.Exec(`DELETE FROM server_recording_modes WHERE server_id = $1`, serverID)
+		return err
+	}
+	_, err := m.db.Exec(`
+		INSERT INTO server_recording_modes (server_id, mode)
+		VALUES ($1, $2)
+		ON CONFLICT (server_id) DO UPDATE SET mode = EXCLUDED.mode
+	`, serverID, mode)
+	return err
+}
+
+// Count returns how many responses a server has recorded
+func (m *ServerRecordingModel) Count(serverID string) (int, error) {
+	var count int
+	err := m.db.QueryRow(`SELECT COUNT(*) FROM server_recordings WHERE server_id = $1`, serverID).Scan(&count)
+	return count, err
+}
+
+// List returns the recordings of a server, optionally of one method only,
+// oldest first
+func (m *ServerRecordingModel) List(serverID, method string) ([]*types.ServerRecording, error) {
+	rows, err := m.db.Query(`
+		SELECT `+serverRecordingColumns+`
+		FROM server_recordings
+		WHERE server_id = $1 AND ($2 = '' OR method = $2)
+		ORDER BY recorded_at, id
+	`, serverID, method)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recordings := []*types.ServerRecording{}
+	for rows.Next() {
+		recording, err := scanServerRecording(rows)
+		if err != nil {
+			return nil, err
+		}
+		recordings = append(recordings, recording)
+	}
+	return recordings, rows.Err()
+}
+
+// Save stores a recording, replacing the server's earlier recording of the
+// same request
+func (m *ServerRecordingModel) Save(recording *types.ServerRecording) error {
+	if recording.ID == "" {
+		recording.ID = uuid.New().String()
+	}
+	var recordedError []byte
+	if recording.Error != nil {
+		var err error
+		if recordedError, err = json.Marshal(recording.Error); err != nil {
+			return err
+		}
+	}
+	return m.db.QueryRow(`
+		INSERT INTO server_recordings (id, server_id, fingerprint, method, params, result, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (server_id, fingerprint) DO UPDATE
+		SET params = EXCLUDED.params, result = EXCLUDED.result, error = EXCLUDED.error,
+			recorded_at = NOW(), replay_count = 0, last_replayed_at = NULL
+		RETURNING id, recorded_at
+	`, recording.ID, recording.ServerID, recording.Fingerprint, recording.Method,
+		nullJSON(recording.Params), nullJSON(recording.Result), nullJSON(recordedError),
+	).Scan(&recording.ID, &recording.RecordedAt)
+}
+
+// Replay returns the recording of a server with fingerprint and counts the
+// replay, or nil when there is none
+func (m *ServerRecordingModel) Replay(serverID, fingerprint string) (*types.ServerRecording, error) {
+	recording, err := scanServerRecording(m.db.QueryRow(`
+		UPDATE server_recordings
+		SET replay_count = replay_count + 1, last_replayed_at = NOW()
+		WHERE server_id = $1 AND fingerprint = $2
+		RETURNING `+serverRecordingColumns,
+		serverID, fingerprint))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return recording, err
+}
+
+// Delete removes a recording of a server
+func (m *ServerRecordingModel) Delete(serverID, id string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM server_recordings WHERE id = $1 AND server_id = $2`, id, serverID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Clear removes every recording of a server and returns how many there were
+func (m *ServerRecordingModel) Clear(serverID string) (int, error) {
+	result, err := m.db.Exec(`DELETE FROM server_recordings WHERE server_id = $1`, serverID)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+func scanServerRecording(row rowScanner) (*types.ServerRecording, error) {
+	recording := &types.ServerRecording{}
+	var params, result, recordedError []byte
+	var lastReplayedAt sql.NullTime
+	err := row.Scan(
+		&recording.ID, &recording.ServerID, &recording.Fingerprint, &recording.Method, &params, &result,
+		&recordedError, &recording.ReplayCount, &recording.RecordedAt, &lastReplayedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	recording.Params = params
+	recording.Result = result
+	if len(recordedError) > 0 {
+		recording.Error = &types.MCPError{}
+		if err := json.Unmarshal(recordedError, recording.Error); err != nil {
+			return nil, err
+		}
+	}
+	if lastReplayedAt.Valid {
+		recording.LastReplayedAt = &lastReplayedAt.Time
+	}
+	return recording, nil
+}
+
+// nullJSON stores empty JSON documents as NULL
+func nullJSON(document []byte) interface{} {
+	if len(document) == 0 {
+		return nil
+	}
+	return document
+}
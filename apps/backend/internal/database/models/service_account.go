@@ -0,0 +1,175 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const serviceAccountColumns = `
+	u.id, u.organization_id, u.name, u.description, u.role, COALESCE(u.created_by::text, ''),
+	COALESCE(u.is_active, true), u.created_at, u.updated_at,
+	(SELECT COUNT(*) FROM api_keys k WHERE k.user_id = u.id AND k.is_active = true),
+	(SELECT COUNT(*) FROM oauth_clients c WHERE c.service_account_id = u.id)
+`
+
+// serviceAccountPasswordHash matches no password under any supported
+// algorithm, so service accounts can never sign in
+const serviceAccountPasswordHash = "!"
+
+// ServiceAccountModel handles service accounts and their OAuth clients
+type ServiceAccountModel struct {
+	BaseModel
+}
+
+// NewServiceAccountModel creates a new service account model
+func NewServiceAccountModel(db Database) *ServiceAccountModel {
+	return &ServiceAccountModel{BaseModel: BaseModel{db: db}}
+}
+
+// List returns the service accounts of an organization
+func (m *ServiceAccountModel) List(orgID string) ([]*types.ServiceAccount, error) {
+	rows, err := m.db.Query(`
+		SELECT `+serviceAccountColumns+`
+		FROM users u
+		WHERE u.organization_id = $1 AND u.account_type = 'service' AND u.retired_at IS NULL
+		ORDER BY u.name
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []*types.ServiceAccount{}
+	for rows.Next() {
+		account, err := scanServiceAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+// Get returns a service account of the organization, or nil when there is
+// none
+func (m *ServiceAccountModel) Get(orgID, id string) (*types.ServiceAccount, error) {
+	account, err := scanServiceAccount(m.db.QueryRow(`
+		SELECT `+serviceAccountColumns+`
+		FROM users u
+		WHERE u.id = $1 AND u.organization_id = $2 AND u.account_type = 'service' AND u.retired_at IS NULL
+	`, id, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return account, err
+}
+
+// Create inserts a service account. Its email is a placeholder that keeps
+// the users table's uniqueness and is never mailed.
+func (m *ServiceAccountModel) Create(account *types.ServiceAccount) error {
+	if account.ID == "" {
+		account.ID = uuid.New().String()
+	}
+	account.IsActive = true
+	return m.db.QueryRow(`
+		INSERT INTO users (id, email, name, password_hash, organization_id, role, account_type,
+			description, created_by, is_active, email_verified)
+		VALUES ($1, $2, $3, $4, $5, $6, 'service', $7, NULLIF($8, '')::uuid, true, false)
+		RETURNING created_at, updated_at
+	`, account.ID, "sa-"+account.ID+"@"+types.ServiceAccountEmailDomain, account.Name, serviceAccountPasswordHash,
+		account.OrganizationID, account.Role, account.Description, account.CreatedBy,
+	).Scan(&account.CreatedAt, &account.UpdatedAt)
+}
+
+// Update saves a service account's description, role and whether it is
+// enabled
+func (m *ServiceAccountModel) Update(account *types.ServiceAccount) error {
+	return m.db.QueryRow(`
+		UPDATE users SET description = $2, role = $3, is_active = $4
+		WHERE id = $1 AND account_type = 'service' AND retired_at IS NULL
+		RETURNING updated_at
+	`, account.ID, account.Description, account.Role, account.IsActive).Scan(&account.UpdatedAt)
+}
+
+// Retire disables a service account of the organization for good, revokes
+// its API keys and deletes its OAuth clients with their tokens. The account
+// itself is kept so audit logs and usage records still name it.
+func (m *ServiceAccountModel) Retire(orgID, id string) (bool, error) {
+	retired := false
+	err := m.Transaction(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			UPDATE users SET is_active = false, retired_at = NOW()
+			WHERE id = $1 AND organization_id = $2 AND account_type = 'service' AND retired_at IS NULL
+		`, id, orgID)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil || affected == 0 {
+			return err
+		}
+		retired = true
+
+		if _, err := tx.Exec(`UPDATE api_keys SET is_active = false, updated_at = NOW() WHERE user_id = $1`, id); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`DELETE FROM oauth_clients WHERE service_account_id = $1`, id)
+		return err
+	})
+	return retired, err
+}
+
+// ListClients returns the OAuth clients issued to a service account
+func (m *ServiceAccountModel) ListClients(serviceAccountID string) ([]*types.OAuthClient, error) {
+	rows, err := m.db.Query(`
+		SELECT id, client_id, client_name, client_type, grant_types, scope,
+			token_endpoint_auth_method, organization_id, is_active, created_at, updated_at
+		FROM oauth_clients
+		WHERE service_account_id = $1
+		ORDER BY created_at
+	`, serviceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []*types.OAuthClient{}
+	for rows.Next() {
+		client := &types.OAuthClient{ServiceAccountID: &serviceAccountID}
+		if err := rows.Scan(&client.ID, &client.ClientID, &client.ClientName, &client.ClientType,
+			pq.Array(&client.GrantTypes), &client.Scope, &client.TokenEndpointAuthMethod,
+			&client.OrganizationID, &client.IsActive, &client.CreatedAt, &client.UpdatedAt); err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, rows.Err()
+}
+
+// DeleteClient deletes an OAuth client of a service account together with
+// the tokens issued to it
+func (m *ServiceAccountModel) DeleteClient(serviceAccountID, clientID string) (bool, error) {
+	result, err := m.db.Exec(`
+		DELETE FROM oauth_clients WHERE client_id = $1 AND service_account_id = $2
+	`, clientID, serviceAccountID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func scanServiceAccount(row rowScanner) (*types.ServiceAccount, error) {
+	account := &types.ServiceAccount{}
+	err := row.Scan(&account.ID, &account.OrganizationID, &account.Name, &account.Description, &account.Role,
+		&account.CreatedBy, &account.IsActive, &account.CreatedAt, &account.UpdatedAt,
+		&account.APIKeyCount, &account.OAuthClientCount)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
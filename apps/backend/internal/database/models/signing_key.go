@@ -0,0 +1,116 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// SigningKeyModel handles the keys outbound requests are signed with
+type SigningKeyModel struct {
+	db Database
+}
+
+// NewSigningKeyModel creates a new signing key model
+func NewSigningKeyModel(db Database) *SigningKeyModel {
+	return &SigningKeyModel{db: db}
+}
+
+const signingKeyColumns = `id, organization_id, destination_type, destination_id, algorithm, secret,
+	COALESCE(public_key, ''), COALESCE(created_by::text, ''), created_at, revoked_at`
+
+func scanSigningKey(row rowScanner) (*types.SigningKey, error) {
+	key := &types.SigningKey{}
+	err := row.Scan(&key.ID, &key.OrganizationID, &key.DestinationType, &key.DestinationID, &key.Algorithm,
+		&key.Secret, &key.PublicKey, &key.CreatedBy, &key.CreatedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DestinationOrganization returns the organization of a signing destination,
+// or an empty string when it does not exist
+func (m *SigningKeyModel) DestinationOrganization(destinationType, destinationID string) (string, error) {
+	var query string
+	switch destinationType {
+	case types.SigningDestinationA2AAgent:
+		query = `SELECT organization_id FROM a2a_agents WHERE id = $1`
+	case types.SigningDestinationOwnerWebhook:
+		query = `SELECT organization_id FROM mcp_servers WHERE id = $1 AND is_active = true`
+	default:
+		return "", nil
+	}
+	var orgID string
+	err := m.db.QueryRow(query, destinationID).Scan(&orgID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return orgID, err
+}
+
+// Create stores a new signing key
+func (m *SigningKeyModel) Create(key *types.SigningKey) error {
+	return m.db.QueryRow(`
+		INSERT INTO signing_keys (organization_id, destination_type, destination_id, algorithm, secret, public_key, created_by)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, '')::uuid)
+		RETURNING id, created_at
+	`, key.OrganizationID, key.DestinationType, key.DestinationID, key.Algorithm, key.Secret,
+		key.PublicKey, key.CreatedBy).Scan(&key.ID, &key.CreatedAt)
+}
+
+// List returns the organization's signing keys, newest first, optionally
+// narrowed to one destination
+func (m *SigningKeyModel) List(orgID, destinationType, destinationID string) ([]*types.SigningKey, error) {
+	rows, err := m.db.Query(`
+		SELECT `+signingKeyColumns+`
+		FROM signing_keys
+		WHERE organization_id = $1
+			AND ($2 = '' OR destination_type = $2)
+			AND ($3 = '' OR destination_id::text = $3)
+		ORDER BY created_at DESC
+	`, orgID, destinationType, destinationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*types.SigningKey
+	for rows.Next() {
+		key, err := scanSigningKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke stops a signing key of the organization from signing
+func (m *SigningKeyModel) Revoke(orgID, id string) (bool, error) {
+	result, err := m.db.Exec(`
+		UPDATE signing_keys SET revoked_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL
+	`, id, orgID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Active returns the newest unrevoked key of a destination, or nil when it
+// has none
+func (m *SigningKeyModel) Active(destinationType, destinationID string) (*types.SigningKey, error) {
+	key, err := scanSigningKey(m.db.QueryRow(`
+		SELECT `+signingKeyColumns+`
+		FROM signing_keys
+		WHERE destination_type = $1 AND destination_id::text = $2 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, destinationType, destinationID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return key, err
+}
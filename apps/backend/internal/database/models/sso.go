@@ -0,0 +1,270 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const ssoProviderColumns = `
+	id, organization_id, display_name, issuer_url, client_id, client_secret, scopes, groups_claim,
+	group_roles, default_role, allowed_domains, auto_provision, is_active, COALESCE(created_by::text, ''),
+	created_at, updated_at
+`
+
+const ssoUserColumns = `id, email, name, organization_id, role, COALESCE(account_type, 'human'), is_active, created_at, updated_at`
+
+// ssoPasswordHash matches no password under any supported algorithm, so
+// users provisioned by an identity provider can only sign in through it
+const ssoPasswordHash = "!"
+
+// SSOModel handles organizations' identity providers, the identities of
+// their users and sign-ins in progress
+type SSOModel struct {
+	db Database
+}
+
+// NewSSOModel creates a new SSO model
+func NewSSOModel(db Database) *SSOModel {
+	return &SSOModel{db: db}
+}
+
+// ListProviders returns the organization's identity providers
+func (m *SSOModel) ListProviders(orgID string) ([]*types.SSOProvider, error) {
+	return m.queryProviders(`
+		SELECT `+ssoProviderColumns+`
+		FROM sso_providers
+		WHERE organization_id = $1
+		ORDER BY display_name
+	`, orgID)
+}
+
+// ListActiveProvidersByOrgSlug returns the active identity providers of
+// the organization with a slug
+func (m *SSOModel) ListActiveProvidersByOrgSlug(slug string) ([]*types.SSOProvider, error) {
+	return m.queryProviders(`
+		SELECT `+ssoProviderColumns+`
+		FROM sso_providers
+		WHERE is_active = true
+		  AND organization_id = (SELECT id FROM organizations WHERE slug = $1 AND is_active = true)
+		ORDER BY display_name
+	`, slug)
+}
+
+func (m *SSOModel) queryProviders(query string, args ...interface{}) ([]*types.SSOProvider, error) {
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	providers := []*types.SSOProvider{}
+	for rows.Next() {
+		provider, err := scanSSOProvider(rows)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+	return providers, rows.Err()
+}
+
+// GetProvider returns an identity provider, or nil when there is none
+func (m *SSOModel) GetProvider(id string) (*types.SSOProvider, error) {
+	provider, err := scanSSOProvider(m.db.QueryRow(`
+		SELECT `+ssoProviderColumns+`
+		FROM sso_providers
+		WHERE id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return provider, err
+}
+
+// CreateProvider inserts an identity provider
+func (m *SSOModel) CreateProvider(provider *types.SSOProvider) error {
+	if provider.ID == "" {
+		provider.ID = uuid.New().String()
+	}
+	groupRoles, err := json.Marshal(provider.GroupRoles)
+	if err != nil {
+		return err
+	}
+	return m.db.QueryRow(`
+		INSERT INTO sso_providers (id, organization_id, display_name, issuer_url, client_id, client_secret,
+			scopes, groups_claim, group_roles, default_role, allowed_domains, auto_provision, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, '')::uuid)
+		RETURNING created_at, updated_at
+	`, provider.ID, provider.OrganizationID, provider.DisplayName, provider.IssuerURL, provider.ClientID,
+		provider.ClientSecret, pq.Array(provider.Scopes), provider.GroupsClaim, groupRoles, provider.DefaultRole,
+		pq.Array(provider.AllowedDomains), provider.AutoProvision, provider.IsActive, provider.CreatedBy,
+	).Scan(&provider.CreatedAt, &provider.UpdatedAt)
+}
+
+// UpdateProvider saves an identity provider's settings
+func (m *SSOModel) UpdateProvider(provider *types.SSOProvider) error {
+	groupRoles, err := json.Marshal(provider.GroupRoles)
+	if err != nil {
+		return err
+	}
+	return m.db.QueryRow(`
+		UPDATE sso_providers
+		SET display_name = $3, issuer_url = $4, client_id = $5, client_secret = $6, scopes = $7,
+			groups_claim = $8, group_roles = $9, default_role = $10, allowed_domains = $11,
+			auto_provision = $12, is_active = $13, updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2
+		RETURNING updated_at
+	`, provider.ID, provider.OrganizationID, provider.DisplayName, provider.IssuerURL, provider.ClientID,
+		provider.ClientSecret, pq.Array(provider.Scopes), provider.GroupsClaim, groupRoles, provider.DefaultRole,
+		pq.Array(provider.AllowedDomains), provider.AutoProvision, provider.IsActive,
+	).Scan(&provider.UpdatedAt)
+}
+
+// DeleteProvider removes an identity provider of the organization along
+// with its users' identities. The users themselves are kept.
+func (m *SSOModel) DeleteProvider(orgID, id string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM sso_providers WHERE id = $1 AND organization_id = $2`, id, orgID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CreateLoginState records a sign-in in progress, clearing out abandoned
+// ones
+func (m *SSOModel) CreateLoginState(state *types.SSOLoginState) error {
+	if _, err := m.db.Exec(`DELETE FROM sso_login_states WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	_, err := m.db.Exec(`
+		INSERT INTO sso_login_states (state_hash, provider_id, nonce, code_verifier, redirect_path, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, state.StateHash, state.ProviderID, state.Nonce, state.CodeVerifier, state.RedirectPath, state.ExpiresAt)
+	return err
+}
+
+// ConsumeLoginState removes and returns a sign-in in progress, or nil when
+// there is none, so that each state is used at most once
+func (m *SSOModel) ConsumeLoginState(stateHash string) (*types.SSOLoginState, error) {
+	state := &types.SSOLoginState{}
+	err := m.db.QueryRow(`
+		DELETE FROM sso_login_states
+		WHERE state_hash = $1
+		RETURNING state_hash, provider_id, nonce, code_verifier, redirect_path, expires_at
+	`, stateHash).Scan(&state.StateHash, &state.ProviderID, &state.Nonce, &state.CodeVerifier,
+		&state.RedirectPath, &state.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// GetIdentity returns the identity of a provider's subject, or nil when
+// there is none
+func (m *SSOModel) GetIdentity(providerID, subject string) (*types.SSOIdentity, error) {
+	identity := &types.SSOIdentity{}
+	err := m.db.QueryRow(`
+		SELECT id, user_id, provider_id, subject, email, last_login_at, created_at
+		FROM sso_identities
+		WHERE provider_id = $1 AND subject = $2
+	`, providerID, subject).Scan(&identity.ID, &identity.UserID, &identity.ProviderID, &identity.Subject,
+		&identity.Email, &identity.LastLoginAt, &identity.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// CreateIdentity links a user to a provider's subject
+func (m *SSOModel) CreateIdentity(identity *types.SSOIdentity) error {
+	if identity.ID == "" {
+		identity.ID = uuid.New().String()
+	}
+	return m.db.QueryRow(`
+		INSERT INTO sso_identities (id, user_id, provider_id, subject, email, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING created_at
+	`, identity.ID, identity.UserID, identity.ProviderID, identity.Subject, identity.Email).Scan(&identity.CreatedAt)
+}
+
+// TouchIdentity records a sign-in through an identity
+func (m *SSOModel) TouchIdentity(id, email string) error {
+	_, err := m.db.Exec(`UPDATE sso_identities SET last_login_at = NOW(), email = $2 WHERE id = $1`, id, email)
+	return err
+}
+
+// GetUser returns a user, or nil when there is none
+func (m *SSOModel) GetUser(id string) (*types.User, error) {
+	return m.queryUser(`id = $1`, id)
+}
+
+// GetUserByEmail returns the user with an email, or nil when there is none
+func (m *SSOModel) GetUserByEmail(email string) (*types.User, error) {
+	return m.queryUser(`email = $1`, email)
+}
+
+func (m *SSOModel) queryUser(condition string, args ...interface{}) (*types.User, error) {
+	user := &types.User{}
+	err := m.db.QueryRow(`SELECT `+ssoUserColumns+` FROM users WHERE `+condition, args...).Scan(
+		&user.ID, &user.Email, &user.Name, &user.OrganizationID, &user.Role, &user.AccountType,
+		&user.IsActive, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CreateUser provisions a user who signs in through an identity provider.
+// Their email was verified by the provider and they have no password.
+func (m *SSOModel) CreateUser(user *types.User) error {
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+	user.IsActive = true
+	return m.db.QueryRow(`
+		INSERT INTO users (id, email, name, password_hash, organization_id, role, is_active, email_verified)
+		VALUES ($1, $2, $3, $4, $5, $6, true, true)
+		RETURNING created_at, updated_at
+	`, user.ID, user.Email, user.Name, ssoPasswordHash, user.OrganizationID, user.Role,
+	).Scan(&user.CreatedAt, &user.UpdatedAt)
+}
+
+// UpdateUserRole sets a user's role
+func (m *SSOModel) UpdateUserRole(userID, role string) error {
+	_, err := m.db.Exec(`UPDATE users SET role = $2 WHERE id = $1`, userID, role)
+	return err
+}
+
+func scanSSOProvider(row rowScanner) (*types.SSOProvider, error) {
+	provider := &types.SSOProvider{}
+	var groupRoles []byte
+	err := row.Scan(
+		&provider.ID, &provider.OrganizationID, &provider.DisplayName, &provider.IssuerURL, &provider.ClientID,
+		&provider.ClientSecret, pq.Array(&provider.Scopes), &provider.GroupsClaim, &groupRoles,
+		&provider.DefaultRole, pq.Array(&provider.AllowedDomains), &provider.AutoProvision, &provider.IsActive,
+		&provider.CreatedBy, &provider.CreatedAt, &provider.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(groupRoles, &provider.GroupRoles); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const teamColumns = `
+	t.id, t.organization_id, t.name, t.description, t.role, t.idp_group, t.created_by,
+	t.created_at, t.updated_at,
+	(SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = t.id)
+`
+
+// TeamModel handles team and team membership database operations
+type TeamModel struct {
+	db Database
+}
+
+// NewTeamModel creates a new team model
+func NewTeamModel(db Database) *TeamModel {
+	return &TeamModel{db: db}
+}
+
+// ListTeams returns the teams of an organization
+func (m *TeamModel) ListTeams(orgID string) ([]*types.Team, error) {
+	return m.queryTeams(`SELECT `+teamColumns+` FROM teams t WHERE t.organization_id = $1 ORDER BY t.name`, orgID)
+}
+
+// ListUserTeams returns the teams a user belongs to
+func (m *TeamModel) ListUserTeams(userID string) ([]*types.Team, error) {
+	return m.queryTeams(`
+		SELECT `+teamColumns+`
+		FROM teams t
+		JOIN team_members m ON m.team_id = t.id
+		WHERE m.user_id = $1
+		ORDER BY t.name
+	`, userID)
+}
+
+// GetTeam returns a team, or nil when there is no such team
+func (m *TeamModel) GetTeam(id string) (*types.Team, error) {
+	teams, err := m.queryTeams(`SELECT `+teamColumns+` FROM teams t WHERE t.id = $1`, id)
+	if err != nil || len(teams) == 0 {
+		return nil, err
+	}
+	return teams[0], nil
+}
+
+// CreateTeam inserts a team
+func (m *TeamModel) CreateTeam(team *types.Team) error {
+	query := `
+		INSERT INTO teams (id, organization_id, name, description, role, idp_group, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`
+
+	if team.ID == "" {
+		team.ID = uuid.New().String()
+	}
+
+	return m.db.QueryRow(query,
+		team.ID, team.OrganizationID, team.Name, team.Description, team.Role, team.IdPGroup, team.CreatedBy,
+	).Scan(&team.CreatedAt, &team.UpdatedAt)
+}
+
+// UpdateTeam saves a team's name, description, role and identity provider
+// group
+func (m *TeamModel) UpdateTeam(team *types.Team) error {
+	query := `
+		UPDATE teams SET name = $2, description = $3, role = $4, idp_group = $5
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	return m.db.QueryRow(query,
+		team.ID, team.Name, team.Description, team.Role, team.IdPGroup,
+	).Scan(&team.UpdatedAt)
+}
+
+// DeleteTeam removes a team and its memberships
+func (m *TeamModel) DeleteTeam(id string) (bool, error) {
+	return m.execAffected(`DELETE FROM teams WHERE id = $1`, id)
+}
+
+// ListMembers returns the members of a team
+func (m *TeamModel) ListMembers(teamID string) ([]*types.TeamMember, error) {
+	query := `
+		SELECT m.team_id, m.user_id, u.email, u.name, m.source, m.added_at
+		FROM team_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.team_id = $1
+		ORDER BY u.email
+	`
+
+	rows, err := m.db.Query(query, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*types.TeamMember{}
+	for rows.Next() {
+		member := &types.TeamMember{}
+		if err := rows.Scan(
+			&member.TeamID, &member.UserID, &member.Email, &member.Name, &member.Source, &member.AddedAt,
+		); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// AddMember adds a user to a team. Adding a synced member manually makes
+// the membership manual, so later syncs leave it alone.
+func (m *TeamModel) AddMember(teamID, userID, source string) error {
+	query := `
+		INSERT INTO team_members (team_id, user_id, source)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (team_id, user_id) DO UPDATE SET
+			source = CASE WHEN EXCLUDED.source = 'manual' THEN 'manual' ELSE team_members.source END
+	`
+
+	_, err := m.db.Exec(query, teamID, userID, source)
+	return err
+}
+
+// RemoveMember removes a user from a team
+func (m *TeamModel) RemoveMember(teamID, userID string) (bool, error) {
+	return m.execAffected(`DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+}
+
+// RemoveSyncedMember removes a user from a team unless they were added
+// manually
+func (m *TeamModel) RemoveSyncedMember(teamID, userID string) (bool, error) {
+	return m.execAffected(`DELETE FROM team_members WHERE team_id = $1 AND user_id = $2 AND source = 'idp'`, teamID, userID)
+}
+
+// UserOrganization returns the organization of a user, or "" when there is
+// no such user
+func (m *TeamModel) UserOrganization(userID string) (string, error) {
+	var orgID string
+	err := m.db.QueryRow(`SELECT organization_id FROM users WHERE id = $1`, userID).Scan(&orgID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return orgID, err
+}
+
+func (m *TeamModel) execAffected(query string, args ...interface{}) (bool, error) {
+	result, err := m.db.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (m *TeamModel) queryTeams(query string, args ...interface{}) ([]*types.Team, error) {
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []*types.Team{}
+	for rows.Next() {
+		team := &types.Team{}
+		if err := rows.Scan(
+			&team.ID, &team.OrganizationID, &team.Name, &team.Description, &team.Role, &team.IdPGroup,
+			&team.CreatedBy, &team.CreatedAt, &team.UpdatedAt, &team.MemberCount,
+		); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, rows.Err()
+}
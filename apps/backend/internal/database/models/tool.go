@@ -532,7 +532,9 @@ func (m *MCPToolModel) Delete(id uuid.UUID) error {
 	return err
 }
 
-// SearchTools searches tools by name, description, function name, or tags
+// SearchTools searches tools by name, description, function name, tags and
+// documentation through the full-text index, best matches first. Names
+// containing the term match too.
 func (m *MCPToolModel) SearchTools(orgID uuid.UUID, searchTerm string, limit int, offset int) ([]*MCPTool, error) {
 	query := `
 		SELECT id, organization_id, name, description, function_name, schema, category,
@@ -543,13 +545,12 @@ func (m *MCPToolModel) SearchTools(orgID uuid.UUID, searchTerm string, limit int
 		FROM mcp_tools
 		WHERE organization_id = $1 AND is_active = true
 		AND (
+			search_vector @@ websearch_to_tsquery('english', $3) OR
 			name ILIKE $2 OR
-			description ILIKE $2 OR
-			function_name ILIKE $2 OR
-			documentation ILIKE $2 OR
-			$3 = ANY(tags)
+			function_name ILIKE $2
 		)
-		ORDER BY usage_count DESC, created_at DESC
+		ORDER BY ts_rank_cd(search_vector, websearch_to_tsquery('english', $3)) DESC,
+			usage_count DESC, created_at DESC
 		LIMIT $4 OFFSET $5
 	`
 
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// ToolApprovalModel stores tool calls held for approval
+type ToolApprovalModel struct {
+	db Database
+}
+
+// NewToolApprovalModel creates a new tool approval model
+func NewToolApprovalModel(db Database) *ToolApprovalModel {
+	return &ToolApprovalModel{db: db}
+}
+
+const toolApprovalColumns = `id, organization_id, namespace_id, server_id, tool, arguments, policy_id, policy_name,
+	message, requested_by, status, decided_by, reason, created_at, expires_at, decided_at`
+
+// Create stores a pending approval and sets its ID and creation time
+func (m *ToolApprovalModel) Create(approval *types.ToolApproval) error {
+	arguments, err := json.Marshal(approval.Arguments)
+	if err != nil {
+		return err
+	}
+	return m.db.QueryRow(`
+		INSERT INTO tool_approvals (organization_id, namespace_id, server_id, tool, arguments, policy_id,
+			policy_name, message, requested_by, status, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`, approval.OrganizationID, approval.NamespaceID, approval.ServerID, approval.Tool, arguments,
+		approval.PolicyID, approval.PolicyName, approval.Message, approval.RequestedBy, approval.Status,
+		approval.CreatedAt, approval.ExpiresAt).Scan(&approval.ID)
+}
+
+// Get returns an approval, or nil when there is none. An empty orgID
+// matches any organization.
+func (m *ToolApprovalModel) Get(orgID, id string) (*types.ToolApproval, error) {
+	approval, err := scanToolApproval(m.db.QueryRow(`
+		SELECT `+toolApprovalColumns+`
+		FROM tool_approvals
+		WHERE id = $1 AND ($2 = '' OR organization_id::text = $2)
+	`, id, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return approval, err
+}
+
+// List returns a page of an organization's approvals, newest first
+func (m *ToolApprovalModel) List(filter *types.ApprovalListFilter) ([]*types.ToolApproval, error) {
+	rows, err := m.db.Query(`
+		SELECT `+toolApprovalColumns+`
+		FROM tool_approvals
+		WHERE organization_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`, filter.OrganizationID, filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approvals := []*types.ToolApproval{}
+	for rows.Next() {
+		approval, err := scanToolApproval(rows)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals, rows.Err()
+}
+
+// Decide moves a pending approval to status. It reports false, changing
+// nothing, when the approval is no longer pending.
+func (m *ToolApprovalModel) Decide(id, status, decidedBy, reason string, at time.Time) (bool, error) {
+	result, err := m.db.Exec(`
+		UPDATE tool_approvals SET status = $2, decided_by = $3, reason = $4, decided_at = $5
+		WHERE id = $1 AND status = 'pending'
+	`, id, status, decidedBy, reason, at)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ExpirePending expires the organization's pending approvals whose waiting
+// call is past its deadline, such as those of an instance that stopped
+func (m *ToolApprovalModel) ExpirePending(orgID string, now time.Time) (int64, error) {
+	result, err := m.db.Exec(`
+		UPDATE tool_approvals SET status = 'expired', decided_at = $2
+		WHERE organization_id = $1 AND status = 'pending' AND expires_at < $2
+	`, orgID, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanToolApproval(row interface{ Scan(...interface{}) error }) (*types.ToolApproval, error) {
+	var approval types.ToolApproval
+	var arguments []byte
+	var decidedAt sql.NullTime
+	if err := row.Scan(&approval.ID, &approval.OrganizationID, &approval.NamespaceID, &approval.ServerID,
+		&approval.Tool, &arguments, &approval.PolicyID, &approval.PolicyName, &approval.Message,
+		&approval.RequestedBy, &approval.Status, &approval.DecidedBy, &approval.Reason, &approval.CreatedAt,
+		&approval.ExpiresAt, &decidedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(arguments, &approval.Arguments); err != nil {
+		return nil, err
+	}
+	if decidedAt.Valid {
+		approval.DecidedAt = &decidedAt.Time
+	}
+	return &approval, nil
+}
@@ -0,0 +1,323 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// toolJobLeaseGrace is how long past its timeout a running job stays leased
+// to its worker before another worker may claim it
+const toolJobLeaseGrace = time.Minute
+
+const toolJobColumns = `id, organization_id, namespace_id, tool, arguments, idempotency_key, principal,
+	status, attempts, max_attempts, timeout_seconds, retry_backoff_seconds, result, error,
+	run_after, created_by, created_at, started_at, finished_at, updated_at`
+
+// ToolJobModel stores queued tool executions and the job settings of tools
+type ToolJobModel struct {
+	db Database
+}
+
+// NewToolJobModel creates a new tool job model
+func NewToolJobModel(db Database) *ToolJobModel {
+	return &ToolJobModel{db: db}
+}
+
+// storedPrincipal keeps the scope of scoped API keys, which principals
+// leave out of their JSON, so that a job is held to it when it runs
+type storedPrincipal struct {
+	*types.Principal
+	Scope *types.APIKeyScope `json:"scope,omitempty"`
+}
+
+// Create queues a job
+func (m *ToolJobModel) Create(job *types.ToolJob) error {
+	arguments, err := json.Marshal(job.Arguments)
+	if err != nil {
+		return err
+	}
+	var principal []byte
+	if job.Principal != nil {
+		if principal, err = json.Marshal(storedPrincipal{Principal: job.Principal, Scope: job.Principal.Scope}); err != nil {
+			return err
+		}
+	}
+	return m.db.QueryRow(`
+		INSERT INTO tool_jobs (organization_id, namespace_id, tool, arguments, idempotency_key, principal,
+			max_attempts, timeout_seconds, retry_backoff_seconds, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, status, run_after, created_at, updated_at
+	`, job.OrganizationID, job.NamespaceID, job.Tool, arguments, job.IdempotencyKey, principal,
+		job.MaxAttempts, job.TimeoutSeconds, job.RetryBackoffSeconds, job.CreatedBy,
+	).Scan(&job.ID, &job.Status, &job.RunAfter, &job.CreatedAt, &job.UpdatedAt)
+}
+
+// Get returns a job of the organization, or nil
+func (m *ToolJobModel) Get(orgID, id string) (*types.ToolJob, error) {
+	job, err := scanToolJob(m.db.QueryRow(`
+		SELECT `+toolJobColumns+` FROM tool_jobs WHERE id = $1 AND organization_id = $2
+	`, id, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+// List returns the organization's most recent jobs, of one creator and of
+// one status when those are set
+func (m *ToolJobModel) List(orgID, createdBy, status string, limit int) ([]*types.ToolJob, error) {
+	rows, err := m.db.Query(`
+		SELECT `+toolJobColumns+` FROM tool_jobs
+		WHERE organization_id = $1 AND ($2 = '' OR created_by::text = $2) AND ($3 = '' OR status = $3)
+		ORDER BY created_at DESC
+		LIMIT $4
+	`, orgID, createdBy, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*types.ToolJob{}
+	for rows.Next() {
+		job, err := scanToolJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// Cancel cancels a queued job of the organization and reports whether it
+// was still queued
+func (m *ToolJobModel) Cancel(orgID, id string) (bool, error) {
+	result, err := m.db.Exec(`
+		UPDATE tool_jobs SET status = 'cancelled', finished_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND status = 'queued'
+	`, id, orgID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Claim leases the next job due to run to worker, counting an attempt. Jobs
+// whose worker's lease ran out are claimed again. It returns nil when no job
+// is due.
+func (m *ToolJobModel) Claim(worker string) (*types.ToolJob, error) {
+	job, err := scanToolJob(m.db.QueryRow(`
+		UPDATE tool_jobs SET
+			status = 'running',
+			attempts = attempts + 1,
+			locked_by = $1,
+			locked_until = NOW() + make_interval(secs => timeout_seconds + $2),
+			started_at = COALESCE(started_at, NOW())
+		WHERE id = (
+			SELECT id FROM tool_jobs
+			WHERE (status = 'queued' AND run_after <= NOW())
+				OR (status = 'running' AND locked_until < NOW())
+			ORDER BY run_after
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+toolJobColumns, worker, int(toolJobLeaseGrace.Seconds())))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+// Finish ends a job leased to worker with status and reports whether the
+// worker still held the lease
+func (m *ToolJobModel) Finish(id, worker, status string, result *types.NamespaceToolResult, jobErr *types.Error) (bool, error) {
+	resultJSON, errJSON, err := marshalToolJobOutcome(result, jobErr)
+	if err != nil {
+		return false, err
+	}
+	res, err := m.db.Exec(`
+		UPDATE tool_jobs SET status = $3, result = $4, error = $5, finished_at = NOW(),
+			locked_by = NULL, locked_until = NULL
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'
+	`, id, worker, status, resultJSON, errJSON)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// Retry queues a job leased to worker again to run at runAfter, keeping
+// why the attempt failed, and reports whether the worker still held the
+// lease
+func (m *ToolJobModel) Retry(id, worker string, runAfter time.Time, result *types.NamespaceToolResult, jobErr *types.Error) (bool, error) {
+	resultJSON, errJSON, err := marshalToolJobOutcome(result, jobErr)
+	if err != nil {
+		return false, err
+	}
+	res, err := m.db.Exec(`
+		UPDATE tool_jobs SET status = 'queued', run_after = $3, result = $4, error = $5,
+			locked_by = NULL, locked_until = NULL
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'
+	`, id, worker, runAfter, resultJSON, errJSON)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// Release queues a job leased to worker again without counting the
+// attempt, for workers that stop before the job ends
+func (m *ToolJobModel) Release(id, worker string) error {
+	_, err := m.db.Exec(`
+		UPDATE tool_jobs SET status = 'queued', attempts = attempts - 1, run_after = NOW(),
+			locked_by = NULL, locked_until = NULL
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'
+	`, id, worker)
+	return err
+}
+
+// Prune deletes jobs that finished before before and returns how many
+func (m *ToolJobModel) Prune(before time.Time) (int64, error) {
+	result, err := m.db.Exec(`DELETE FROM tool_jobs WHERE finished_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ToolExists reports whether a tool belongs to the organization
+func (m *ToolJobModel) ToolExists(orgID, toolID string) (bool, error) {
+	var exists bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM mcp_tools WHERE id = $1 AND organization_id = $2)
+	`, toolID, orgID).Scan(&exists)
+	return exists, err
+}
+
+// GetSettings returns the job settings of a tool, or nil when it has none
+func (m *ToolJobModel) GetSettings(toolID string) (*types.ToolJobSettings, error) {
+	settings := &types.ToolJobSettings{ToolID: toolID}
+	var updatedAt time.Time
+	err := m.db.QueryRow(`
+		SELECT max_attempts, timeout_seconds, retry_backoff_seconds, updated_at
+		FROM tool_job_settings WHERE tool_id = $1
+	`, toolID).Scan(&settings.MaxAttempts, &settings.TimeoutSeconds, &settings.RetryBackoffSeconds, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	settings.UpdatedAt = &updatedAt
+	return settings, nil
+}
+
+// GetSettingsForServerTool returns the job settings of the tool a server
+// exposes under name, or nil when the tool is not registered or has none
+func (m *ToolJobModel) GetSettingsForServerTool(serverID, name string) (*types.ToolJobSettings, error) {
+	settings := &types.ToolJobSettings{}
+	var updatedAt time.Time
+	err := m.db.QueryRow(`
+		SELECT s.tool_id, s.max_attempts, s.timeout_seconds, s.retry_backoff_seconds, s.updated_at
+		FROM tool_job_settings s
+		JOIN mcp_tools t ON t.id = s.tool_id
+		WHERE t.server_id = $1 AND t.function_name = $2
+		ORDER BY t.created_at
+		LIMIT 1
+	`, serverID, name).Scan(&settings.ToolID, &settings.MaxAttempts, &settings.TimeoutSeconds,
+		&settings.RetryBackoffSeconds, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	settings.UpdatedAt = &updatedAt
+	return settings, nil
+}
+
+// UpsertSettings replaces the job settings of a tool
+func (m *ToolJobModel) UpsertSettings(settings *types.ToolJobSettings) error {
+	var updatedAt time.Time
+	err := m.db.QueryRow(`
+		INSERT INTO tool_job_settings (tool_id, max_attempts, timeout_seconds, retry_backoff_seconds)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tool_id) DO UPDATE
+		SET max_attempts = EXCLUDED.max_attempts, timeout_seconds = EXCLUDED.timeout_seconds,
+			retry_backoff_seconds = EXCLUDED.retry_backoff_seconds
+		RETURNING updated_at
+	`, settings.ToolID, settings.MaxAttempts, settings.TimeoutSeconds, settings.RetryBackoffSeconds).Scan(&updatedAt)
+	settings.UpdatedAt = &updatedAt
+	return err
+}
+
+// DeleteSettings removes the job settings of a tool and reports whether it
+// had any
+func (m *ToolJobModel) DeleteSettings(toolID string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM tool_job_settings WHERE tool_id = $1`, toolID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func marshalToolJobOutcome(result *types.NamespaceToolResult, jobErr *types.Error) ([]byte, []byte, error) {
+	var resultJSON, errJSON []byte
+	var err error
+	if result != nil {
+		if resultJSON, err = json.Marshal(result); err != nil {
+			return nil, nil, err
+		}
+	}
+	if jobErr != nil {
+		if errJSON, err = json.Marshal(jobErr); err != nil {
+			return nil, nil, err
+		}
+	}
+	return resultJSON, errJSON, nil
+}
+
+type toolJobScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanToolJob(row toolJobScanner) (*types.ToolJob, error) {
+	job := &types.ToolJob{}
+	var arguments, principal, result, jobErr []byte
+	err := row.Scan(&job.ID, &job.OrganizationID, &job.NamespaceID, &job.Tool, &arguments, &job.IdempotencyKey,
+		&principal, &job.Status, &job.Attempts, &job.MaxAttempts, &job.TimeoutSeconds, &job.RetryBackoffSeconds,
+		&result, &jobErr, &job.RunAfter, &job.CreatedBy, &job.CreatedAt, &job.StartedAt, &job.FinishedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(arguments, &job.Arguments); err != nil {
+		return nil, err
+	}
+	if principal != nil {
+		stored := storedPrincipal{}
+		if err := json.Unmarshal(principal, &stored); err != nil {
+			return nil, err
+		}
+		if stored.Principal != nil {
+			stored.Principal.Scope = stored.Scope
+			job.Principal = stored.Principal
+		}
+	}
+	if result != nil {
+		if err := json.Unmarshal(result, &job.Result); err != nil {
+			return nil, err
+		}
+	}
+	if jobErr != nil {
+		if err := json.Unmarshal(jobErr, &job.Error); err != nil {
+			return nil, err
+		}
+	}
+	return job, nil
+}
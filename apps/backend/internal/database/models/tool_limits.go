@@ -0,0 +1,164 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// ToolLimitModel stores the rate limits and daily budgets of tools and the
+// calls budgets admitted
+type ToolLimitModel struct {
+	db Database
+}
+
+// NewToolLimitModel creates a new tool limit model
+func NewToolLimitModel(db Database) *ToolLimitModel {
+	return &ToolLimitModel{db: db}
+}
+
+// ToolExists reports whether a tool belongs to the organization
+func (m *ToolLimitModel) ToolExists(orgID, toolID string) (bool, error) {
+	var exists bool
+	err := m.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM mcp_tools WHERE id = $1 AND organization_id = $2)
+	`, toolID, orgID).Scan(&exists)
+	return exists, err
+}
+
+// Get returns the limits of a tool, or nil when it has none
+func (m *ToolLimitModel) Get(toolID string) (*types.ToolLimits, error) {
+	limits := &types.ToolLimits{ToolID: toolID}
+	var updatedAt time.Time
+	err := m.db.QueryRow(`
+		SELECT rate_limit, rate_window_seconds, daily_budget, updated_at FROM tool_limits WHERE tool_id = $1
+	`, toolID).Scan(&limits.RateLimit, &limits.RateWindowSeconds, &limits.DailyBudget, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	limits.UpdatedAt = &updatedAt
+	return limits, nil
+}
+
+// GetForServerTool returns the limits of the tool a server exposes under
+// name, or nil when the tool is not registered or has no limits
+func (m *ToolLimitModel) GetForServerTool(serverID, name string) (*types.ToolLimits, error) {
+	limits := &types.ToolLimits{}
+	var updatedAt time.Time
+	err := m.db.QueryRow(`
+		SELECT l.tool_id, l.rate_limit, l.rate_window_seconds, l.daily_budget, l.updated_at
+		FROM tool_limits l
+		JOIN mcp_tools t ON t.id = l.tool_id
+		WHERE t.server_id = $1 AND t.function_name = $2
+		ORDER BY t.created_at
+		LIMIT 1
+	`, serverID, name).Scan(&limits.ToolID, &limits.RateLimit, &limits.RateWindowSeconds, &limits.DailyBudget, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	limits.UpdatedAt = &updatedAt
+	return limits, nil
+}
+
+// ListForTools returns the limits of the tools that have any, by tool ID
+func (m *ToolLimitModel) ListForTools(toolIDs []string) (map[string]*types.ToolLimits, error) {
+	rows, err := m.db.Query(`
+		SELECT tool_id, rate_limit, rate_window_seconds, daily_budget, updated_at
+		FROM tool_limits WHERE tool_id = ANY($1)
+	`, pq.Array(toolIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	limits := make(map[string]*types.ToolLimits)
+	for rows.Next() {
+		l := &types.ToolLimits{}
+		var updatedAt time.Time
+		if err := rows.Scan(&l.ToolID, &l.RateLimit, &l.RateWindowSeconds, &l.DailyBudget, &updatedAt); err != nil {
+			return nil, err
+		}
+		l.UpdatedAt = &updatedAt
+		limits[l.ToolID] = l
+	}
+	return limits, rows.Err()
+}
+
+// Upsert replaces the limits of a tool
+func (m *ToolLimitModel) Upsert(limits *types.ToolLimits) error {
+	_, err := m.db.Exec(`
+		INSERT INTO tool_limits (tool_id, rate_limit, rate_window_seconds, daily_budget)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tool_id) DO UPDATE
+		SET rate_limit = EXCLUDED.rate_limit, rate_window_seconds = EXCLUDED.rate_window_seconds,
+			daily_budget = EXCLUDED.daily_budget
+	`, limits.ToolID, limits.RateLimit, limits.RateWindowSeconds, limits.DailyBudget)
+	return err
+}
+
+// Delete removes the limits of a tool and reports whether it had any
+func (m *ToolLimitModel) Delete(toolID string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM tool_limits WHERE tool_id = $1`, toolID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ConsumeBudget counts a call of a tool on day when fewer than budget calls
+// were counted, and reports whether it did. The check and the increment are
+// one statement, so concurrent calls on several instances never exceed the
+// budget.
+func (m *ToolLimitModel) ConsumeBudget(toolID string, day time.Time, budget int64) (bool, error) {
+	var calls int64
+	err := m.db.QueryRow(`
+		INSERT INTO tool_budget_usage (tool_id, day, calls)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (tool_id, day) DO UPDATE
+		SET calls = tool_budget_usage.calls + 1
+		WHERE tool_budget_usage.calls < $3
+		RETURNING calls
+	`, toolID, day, budget).Scan(&calls)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Usage returns the calls budgets admitted on day, by tool ID
+func (m *ToolLimitModel) Usage(toolIDs []string, day time.Time) (map[string]int64, error) {
+	rows, err := m.db.Query(`
+		SELECT tool_id, calls FROM tool_budget_usage WHERE tool_id = ANY($1) AND day = $2
+	`, pq.Array(toolIDs), day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make(map[string]int64)
+	for rows.Next() {
+		var toolID string
+		var calls int64
+		if err := rows.Scan(&toolID, &calls); err != nil {
+			return nil, err
+		}
+		usage[toolID] = calls
+	}
+	return usage, rows.Err()
+}
+
+// PruneUsage deletes the usage of days before day
+func (m *ToolLimitModel) PruneUsage(before time.Time) error {
+	_, err := m.db.Exec(`DELETE FROM tool_budget_usage WHERE day < $1`, before)
+	return err
+}
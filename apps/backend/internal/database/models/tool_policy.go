@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// ToolPolicyModel reads the tool policies of organizations
+type ToolPolicyModel struct {
+	db Database
+}
+
+// NewToolPolicyModel creates a new tool policy model
+func NewToolPolicyModel(db Database) *ToolPolicyModel {
+	return &ToolPolicyModel{db: db}
+}
+
+// ListActive returns the active tool policies of an organization, highest
+// priority first
+func (m *ToolPolicyModel) ListActive(orgID string) ([]*types.Policy, error) {
+	rows, err := m.db.Query(`
+		SELECT id, organization_id, name, COALESCE(description, ''), type, priority, conditions, actions,
+			is_active, created_at, updated_at
+		FROM policies
+		WHERE organization_id = $1 AND type = $2 AND is_active = true
+		ORDER BY priority DESC, created_at
+	`, orgID, types.PolicyTypeTool)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := []*types.Policy{}
+	for rows.Next() {
+		var policy types.Policy
+		var conditions, actions []byte
+		if err := rows.Scan(&policy.ID, &policy.OrganizationID, &policy.Name, &policy.Description,
+			&policy.Type, &policy.Priority, &conditions, &actions, &policy.IsActive,
+			&policy.CreatedAt, &policy.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(conditions, &policy.Conditions); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(actions, &policy.Actions); err != nil {
+			return nil, err
+		}
+		policies = append(policies, &policy)
+	}
+	return policies, rows.Err()
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// UsageBudgetModel stores metered usage, monthly budgets and the budget
+// alerts sent
+type UsageBudgetModel struct {
+	db Database
+}
+
+// NewUsageBudgetModel creates a new usage budget model
+func NewUsageBudgetModel(db Database) *UsageBudgetModel {
+	return &UsageBudgetModel{db: db}
+}
+
+// AddUsage adds quantity to an organization's usage of dimension in the
+// month starting at period
+func (m *UsageBudgetModel) AddUsage(orgID, dimension string, period time.Time, quantity int64) error {
+	_, err := m.db.Exec(`
+		INSERT INTO usage_meters (organization_id, dimension, period, quantity)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (organization_id, dimension, period) DO UPDATE
+		SET quantity = usage_meters.quantity + EXCLUDED.quantity, updated_at = NOW()
+	`, orgID, dimension, period, quantity)
+	return err
+}
+
+// Usage returns an organization's usage in the month starting at period,
+// by dimension
+func (m *UsageBudgetModel) Usage(orgID string, period time.Time) (map[string]int64, error) {
+	rows, err := m.db.Query(`
+		SELECT dimension, quantity FROM usage_meters WHERE organization_id = $1 AND period = $2
+	`, orgID, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make(map[string]int64)
+	for rows.Next() {
+		var dimension string
+		var quantity int64
+		if err := rows.Scan(&dimension, &quantity); err != nil {
+			return nil, err
+		}
+		usage[dimension] = quantity
+	}
+	return usage, rows.Err()
+}
+
+// ListBudgets returns the budgets of an organization, or of every
+// organization when orgID is empty
+func (m *UsageBudgetModel) ListBudgets(orgID string) ([]*types.UsageBudget, error) {
+	query := `
+		SELECT organization_id, dimension, monthly_limit, hard_stop, critical_namespaces, created_at, updated_at
+		FROM usage_budgets`
+	var args []interface{}
+	if orgID != "" {
+		query += ` WHERE organization_id = $1`
+		args = append(args, orgID)
+	}
+	query += ` ORDER BY organization_id, dimension`
+
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var budgets []*types.UsageBudget
+	for rows.Next() {
+		b := &types.UsageBudget{}
+		if err := rows.Scan(&b.OrganizationID, &b.Dimension, &b.MonthlyLimit, &b.HardStop,
+			(*pq.StringArray)(&b.CriticalNamespaces), &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, b)
+	}
+	return budgets, rows.Err()
+}
+
+// UpsertBudget replaces an organization's budget of a dimension
+func (m *UsageBudgetModel) UpsertBudget(budget *types.UsageBudget) error {
+	return m.db.QueryRow(`
+		INSERT INTO usage_budgets (organization_id, dimension, monthly_limit, hard_stop, critical_namespaces)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id, dimension) DO UPDATE
+		SET monthly_limit = EXCLUDED.monthly_limit, hard_stop = EXCLUDED.hard_stop,
+			critical_namespaces = EXCLUDED.critical_namespaces
+		RETURNING created_at, updated_at
+	`, budget.OrganizationID, budget.Dimension, budget.MonthlyLimit, budget.HardStop,
+		pq.StringArray(budget.CriticalNamespaces)).Scan(&budget.CreatedAt, &budget.UpdatedAt)
+}
+
+// DeleteBudget removes an organization's budget of a dimension and reports
+// whether it had one
+func (m *UsageBudgetModel) DeleteBudget(orgID, dimension string) (bool, error) {
+	result, err := m.db.Exec(`
+		DELETE FROM usage_budgets WHERE organization_id = $1 AND dimension = $2
+	`, orgID, dimension)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// NamespacesExist reports whether every namespace belongs to the
+// organization
+func (m *UsageBudgetModel) NamespacesExist(orgID string, namespaceIDs []string) (bool, error) {
+	var count int
+	err := m.db.QueryRow(`
+		SELECT COUNT(*) FROM namespaces WHERE organization_id = $1 AND id::text = ANY($2)
+	`, orgID, pq.Array(namespaceIDs)).Scan(&count)
+	return count == len(namespaceIDs), err
+}
+
+// AlertsSent returns the thresholds of a budget admins were notified of in
+// the month starting at period
+func (m *UsageBudgetModel) AlertsSent(orgID, dimension string, period time.Time) ([]int, error) {
+	rows, err := m.db.Query(`
+		SELECT threshold FROM usage_budget_alerts
+		WHERE organization_id = $1 AND dimension = $2 AND period = $3
+		ORDER BY threshold
+	`, orgID, dimension, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	thresholds := []int{}
+	for rows.Next() {
+		var threshold int
+		if err := rows.Scan(&threshold); err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, rows.Err()
+}
+
+// RecordAlert records that admins are notified of a threshold and reports
+// false when they already were this month
+func (m *UsageBudgetModel) RecordAlert(orgID, dimension string, period time.Time, threshold int) (bool, error) {
+	result, err := m.db.Exec(`
+		INSERT INTO usage_budget_alerts (organization_id, dimension, period, threshold)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`, orgID, dimension, period, threshold)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ClearAlerts forgets the alerts sent for a budget in the month starting at
+// period, so a changed budget alerts afresh
+func (m *UsageBudgetModel) ClearAlerts(orgID, dimension string, period time.Time) error {
+	_, err := m.db.Exec(`
+		DELETE FROM usage_budget_alerts WHERE organization_id = $1 AND dimension = $2 AND period = $3
+	`, orgID, dimension, period)
+	return err
+}
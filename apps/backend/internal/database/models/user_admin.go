@@ -0,0 +1,258 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const managedUserColumns = `
+	u.id, u.organization_id, u.email, u.name, u.role,
+	CASE
+		WHEN u.deactivated_at IS NOT NULL THEN 'deactivated'
+		WHEN u.is_active THEN 'active'
+		WHEN u.invited_at IS NOT NULL THEN 'invited'
+		ELSE 'deactivated'
+	END,
+	u.invited_at, COALESCE(u.invited_by::text, ''), u.deactivated_at, u.created_at, u.updated_at
+`
+
+// invitedPasswordHash matches no password under any supported algorithm,
+// so invited users cannot sign in before choosing a password
+const invitedPasswordHash = "!"
+
+// UserAdminModel handles the human users of organizations and the tokens
+// emailed to them
+type UserAdminModel struct {
+	BaseModel
+}
+
+// NewUserAdminModel creates a new user admin model
+func NewUserAdminModel(db Database) *UserAdminModel {
+	return &UserAdminModel{BaseModel: BaseModel{db: db}}
+}
+
+// List returns the organization's human users ordered by email
+func (m *UserAdminModel) List(orgID string, filter *types.ManagedUserFilter) ([]*types.ManagedUser, error) {
+	query := `
+		SELECT ` + managedUserColumns + `
+		FROM users u
+		WHERE u.organization_id = $1 AND u.account_type = 'human' AND u.retired_at IS NULL
+	`
+	args := []interface{}{orgID}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		query += fmt.Sprintf(` AND u.role = $%d`, len(args))
+	}
+	if filter.Search != "" {
+		args = append(args, "%"+filter.Search+"%")
+		query += fmt.Sprintf(` AND (u.email ILIKE $%[1]d OR u.name ILIKE $%[1]d)`, len(args))
+	}
+	query += ` ORDER BY u.email`
+
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*types.ManagedUser{}
+	for rows.Next() {
+		user, err := scanManagedUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		if filter.Status != "" && user.Status != filter.Status {
+			continue
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// Get returns a human user of the organization, or nil when there is none
+func (m *UserAdminModel) Get(orgID, id string) (*types.ManagedUser, error) {
+	user, err := scanManagedUser(m.db.QueryRow(`
+		SELECT `+managedUserColumns+`
+		FROM users u
+		WHERE u.id = $1 AND u.organization_id = $2 AND u.account_type = 'human' AND u.retired_at IS NULL
+	`, id, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return user, err
+}
+
+// GetByEmail returns the user with an email, compared case-insensitively,
+// or nil when there is none. Users of every organization and account type
+// are searched since emails are unique across the gateway.
+func (m *UserAdminModel) GetByEmail(email string) (*types.ManagedUser, error) {
+	user, err := scanManagedUser(m.db.QueryRow(`
+		SELECT `+managedUserColumns+`
+		FROM users u
+		WHERE LOWER(u.email) = LOWER($1)
+		LIMIT 1
+	`, email))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return user, err
+}
+
+// Create inserts a human user. Invited users are inactive and have no
+// usable password until they accept their invitation.
+func (m *UserAdminModel) Create(user *types.ManagedUser, passwordHash string) error {
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+	invited := user.Status == types.UserStatusInvited
+	if invited {
+		passwordHash = invitedPasswordHash
+	}
+	return m.db.QueryRow(`
+		INSERT INTO users (id, email, name, password_hash, organization_id, role, account_type,
+			is_active, email_verified, invited_at, invited_by)
+		VALUES ($1, $2, $3, $4, $5, $6, 'human', $7, false,
+			CASE WHEN $8 THEN NOW() END, NULLIF($9, '')::uuid)
+		RETURNING invited_at, created_at, updated_at
+	`, user.ID, user.Email, user.Name, passwordHash, user.OrganizationID, user.Role,
+		!invited, invited, user.InvitedBy,
+	).Scan(&user.InvitedAt, &user.CreatedAt, &user.UpdatedAt)
+}
+
+// Update saves a user's name and role
+func (m *UserAdminModel) Update(user *types.ManagedUser) error {
+	return m.db.QueryRow(`
+		UPDATE users SET name = $3, role = $4, updated_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND account_type = 'human' AND retired_at IS NULL
+		RETURNING updated_at
+	`, user.ID, user.OrganizationID, user.Name, user.Role).Scan(&user.UpdatedAt)
+}
+
+// SetDeactivated deactivates or reactivates a user of the organization.
+// Deactivation discards the user's pending invitation and reset tokens; a
+// reactivated user who never accepted their invitation is invited again.
+func (m *UserAdminModel) SetDeactivated(orgID, id string, deactivated bool) (bool, error) {
+	changed := false
+	err := m.Transaction(func(tx *sql.Tx) error {
+		query := `
+			UPDATE users SET is_active = false, deactivated_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND organization_id = $2 AND account_type = 'human' AND retired_at IS NULL
+				AND deactivated_at IS NULL
+		`
+		if !deactivated {
+			query = `
+				UPDATE users SET is_active = password_hash <> '` + invitedPasswordHash + `',
+					deactivated_at = NULL, updated_at = NOW()
+				WHERE id = $1 AND organization_id = $2 AND account_type = 'human' AND retired_at IS NULL
+					AND (deactivated_at IS NOT NULL OR (NOT is_active AND invited_at IS NULL))
+			`
+		}
+		result, err := tx.Exec(query, id, orgID)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil || affected == 0 {
+			return err
+		}
+		changed = true
+
+		if deactivated {
+			_, err = tx.Exec(`DELETE FROM user_tokens WHERE user_id = $1 AND used_at IS NULL`, id)
+		}
+		return err
+	})
+	return changed, err
+}
+
+// CountActiveAdmins counts the organization's active human admins
+func (m *UserAdminModel) CountActiveAdmins(orgID string) (int, error) {
+	var count int
+	err := m.db.QueryRow(`
+		SELECT COUNT(*) FROM users
+		WHERE organization_id = $1 AND account_type = 'human' AND role = $2
+			AND is_active = true AND retired_at IS NULL
+	`, orgID, types.RoleAdmin).Scan(&count)
+	return count, err
+}
+
+// CreateToken stores a token, replacing the user's unused tokens of the
+// same purpose so only the latest link works
+func (m *UserAdminModel) CreateToken(token *types.UserToken) error {
+	return m.Transaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+		`, token.UserID, token.Purpose); err != nil {
+			return err
+		}
+		return tx.QueryRow(`
+			INSERT INTO user_tokens (organization_id, user_id, purpose, token_hash, created_by, expires_at)
+			VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, $6)
+			RETURNING id
+		`, token.OrganizationID, token.UserID, token.Purpose, token.TokenHash, token.CreatedBy, token.ExpiresAt,
+		).Scan(&token.ID)
+	})
+}
+
+// ConsumeToken marks an unused, unexpired token as used and returns it, or
+// nil when no such token exists
+func (m *UserAdminModel) ConsumeToken(purpose, tokenHash string, now time.Time) (*types.UserToken, error) {
+	token := &types.UserToken{Purpose: purpose, TokenHash: tokenHash}
+	err := m.db.QueryRow(`
+		UPDATE user_tokens SET used_at = $3
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+		RETURNING id, organization_id, user_id, COALESCE(created_by::text, ''), expires_at, used_at
+	`, tokenHash, purpose, now).Scan(&token.ID, &token.OrganizationID, &token.UserID, &token.CreatedBy,
+		&token.ExpiresAt, &token.UsedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// SetPassword stores a new password hash for a user who is not deactivated.
+// With activate set, an invited user becomes active and their email
+// verified, as they proved they receive mail there.
+func (m *UserAdminModel) SetPassword(userID, passwordHash string, activate bool) (bool, error) {
+	query := `
+		UPDATE users SET password_hash = $2, updated_at = NOW()
+		WHERE id = $1 AND account_type = 'human' AND retired_at IS NULL AND deactivated_at IS NULL
+	`
+	if activate {
+		query = `
+			UPDATE users SET password_hash = $2, is_active = true, email_verified = true, updated_at = NOW()
+			WHERE id = $1 AND account_type = 'human' AND retired_at IS NULL AND deactivated_at IS NULL
+		`
+	}
+	result, err := m.db.Exec(query, userID, passwordHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func scanManagedUser(row rowScanner) (*types.ManagedUser, error) {
+	user := &types.ManagedUser{}
+	var invitedAt, deactivatedAt sql.NullTime
+	err := row.Scan(&user.ID, &user.OrganizationID, &user.Email, &user.Name, &user.Role, &user.Status,
+		&invitedAt, &user.InvitedBy, &deactivatedAt, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if invitedAt.Valid {
+		user.InvitedAt = &invitedAt.Time
+	}
+	if deactivatedAt.Valid {
+		user.DeactivatedAt = &deactivatedAt.Time
+	}
+	return user, nil
+}
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/lib/pq"
+)
+
+// WebhookModel stores organizations' webhooks and their deliveries
+type WebhookModel struct {
+	db Database
+}
+
+// NewWebhookModel creates a new webhook model
+func NewWebhookModel(db Database) *WebhookModel {
+	return &WebhookModel{db: db}
+}
+
+const webhookColumns = `id, organization_id, name, url, secret, event_types, is_active,
+	COALESCE(created_by::text, ''), created_at, updated_at`
+
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*types.Webhook, error) {
+	webhook := &types.Webhook{}
+	err := row.Scan(&webhook.ID, &webhook.OrganizationID, &webhook.Name, &webhook.URL, &webhook.Secret,
+		pq.Array(&webhook.EventTypes), &webhook.IsActive, &webhook.CreatedBy, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (m *WebhookModel) queryWebhooks(query string, args ...interface{}) ([]*types.Webhook, error) {
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*types.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// List returns the organization's webhooks
+func (m *WebhookModel) List(orgID string) ([]*types.Webhook, error) {
+	return m.queryWebhooks(`
+		SELECT `+webhookColumns+` FROM webhooks WHERE organization_id = $1 ORDER BY created_at
+	`, orgID)
+}
+
+// ListSubscribers returns the organization's active webhooks subscribed to
+// eventType
+func (m *WebhookModel) ListSubscribers(orgID, eventType string) ([]*types.Webhook, error) {
+	return m.queryWebhooks(`
+		SELECT `+webhookColumns+` FROM webhooks
+		WHERE organization_id = $1 AND is_active AND ($2 = ANY(event_types) OR '*' = ANY(event_types))
+	`, orgID, eventType)
+}
+
+// Get returns a webhook of the organization, or nil when there is none
+func (m *WebhookModel) Get(orgID, id string) (*types.Webhook, error) {
+	webhook, err := scanWebhook(m.db.QueryRow(`
+		SELECT `+webhookColumns+` FROM webhooks WHERE id = $1 AND organization_id = $2
+	`, id, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return webhook, err
+}
+
+// Create inserts a webhook
+func (m *WebhookModel) Create(webhook *types.Webhook) error {
+	return m.db.QueryRow(`
+		INSERT INTO webhooks (organization_id, name, url, secret, event_types, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid)
+		RETURNING id, created_at, updated_at
+	`, webhook.OrganizationID, webhook.Name, webhook.URL, webhook.Secret, pq.Array(webhook.EventTypes),
+		webhook.IsActive, webhook.CreatedBy).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+}
+
+// Update saves the name, URL, event types, active flag and secret of a
+// webhook
+func (m *WebhookModel) Update(webhook *types.Webhook) error {
+	return m.db.QueryRow(`
+		UPDATE webhooks SET name = $3, url = $4, event_types = $5, is_active = $6, secret = $7
+		WHERE id = $1 AND organization_id = $2
+		RETURNING updated_at
+	`, webhook.ID, webhook.OrganizationID, webhook.Name, webhook.URL, pq.Array(webhook.EventTypes),
+		webhook.IsActive, webhook.Secret).Scan(&webhook.UpdatedAt)
+}
+
+// Delete removes a webhook of the organization with its deliveries and
+// reports whether it existed
+func (m *WebhookModel) Delete(orgID, id string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM webhooks WHERE id = $1 AND organization_id = $2`, id, orgID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+const webhookDeliveryColumns = `id, webhook_id, organization_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_attempt_at, response_status, last_error, duration_ms, delivered_at, created_at`
+
+func scanWebhookDelivery(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*types.WebhookDelivery, error) {
+	delivery := &types.WebhookDelivery{}
+	var nextAttemptAt, lastAttemptAt, deliveredAt sql.NullTime
+	var payload []byte
+	dest := []interface{}{&delivery.ID, &delivery.WebhookID, &delivery.OrganizationID, &delivery.EventID,
+		&delivery.EventType, &payload, &delivery.Status, &delivery.Attempts, &nextAttemptAt, &lastAttemptAt,
+		&delivery.ResponseStatus, &delivery.LastError, &delivery.DurationMs, &deliveredAt, &delivery.CreatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	if nextAttemptAt.Valid && delivery.Status == types.WebhookDeliveryPending {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if lastAttemptAt.Valid {
+		delivery.LastAttemptAt = &lastAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return delivery, nil
+}
+
+// CreateDelivery queues a delivery, due at once
+func (m *WebhookModel) CreateDelivery(delivery *types.WebhookDelivery) error {
+	return m.db.QueryRow(`
+		INSERT INTO webhook_deliveries (webhook_id, organization_id, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, next_attempt_at, created_at
+	`, delivery.WebhookID, delivery.OrganizationID, delivery.EventID, delivery.EventType,
+		[]byte(delivery.Payload)).Scan(&delivery.ID, &delivery.Status, &delivery.NextAttemptAt, &delivery.CreatedAt)
+}
+
+// ClaimDue leases up to limit due deliveries for an attempt. Their next
+// attempt moves lease into the future, so deliveries of a worker that
+// stops mid-request are retried.
+func (m *WebhookModel) ClaimDue(limit int, lease time.Duration) ([]*types.DueWebhookDelivery, error) {
+	rows, err := m.db.Query(`
+		UPDATE webhook_deliveries d SET
+			attempts = d.attempts + 1,
+			last_attempt_at = NOW(),
+			next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM webhooks w
+		WHERE w.id = d.webhook_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id, d.webhook_id, d.organization_id, d.event_id, d.event_type, d.payload, d.status,
+			d.attempts, d.next_attempt_at, d.last_attempt_at, d.response_status, d.last_error, d.duration_ms,
+			d.delivered_at, d.created_at, w.url, w.secret, w.is_active
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []*types.DueWebhookDelivery
+	for rows.Next() {
+		claimed := &types.DueWebhookDelivery{}
+		delivery, err := scanWebhookDelivery(rows, &claimed.URL, &claimed.Secret, &claimed.WebhookActive)
+		if err != nil {
+			return nil, err
+		}
+		claimed.WebhookDelivery = *delivery
+		due = append(due, claimed)
+	}
+	return due, rows.Err()
+}
+
+// FinishDelivery records the outcome of an attempt. Pending deliveries are
+// retried after retryAfter.
+func (m *WebhookModel) FinishDelivery(delivery *types.WebhookDelivery, retryAfter time.Duration) error {
+	_, err := m.db.Exec(`
+		UPDATE webhook_deliveries SET
+			status = $2,
+			response_status = $3,
+			last_error = $4,
+			duration_ms = $5,
+			next_attempt_at = CASE WHEN $2 = 'pending' THEN NOW() + make_interval(secs => $6) ELSE next_attempt_at END,
+			delivered_at = CASE WHEN $2 = 'succeeded' THEN NOW() ELSE delivered_at END
+		WHERE id = $1
+	`, delivery.ID, delivery.Status, delivery.ResponseStatus, delivery.LastError, delivery.DurationMs,
+		retryAfter.Seconds())
+	return err
+}
+
+// GetDelivery returns a delivery of a webhook of the organization, or nil
+// when there is none
+func (m *WebhookModel) GetDelivery(orgID, webhookID, id string) (*types.WebhookDelivery, error) {
+	delivery, err := scanWebhookDelivery(m.db.QueryRow(`
+		SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries
+		WHERE id = $1 AND webhook_id = $2 AND organization_id = $3
+	`, id, webhookID, orgID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return delivery, err
+}
+
+// ListDeliveries returns a page of a webhook's deliveries, newest first
+func (m *WebhookModel) ListDeliveries(filter *types.WebhookDeliveryFilter) ([]*types.WebhookDelivery, error) {
+	rows, err := m.db.Query(`
+		SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries
+		WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`, filter.WebhookID, filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*types.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// PruneDeliveries deletes finished deliveries created before cutoff
+func (m *WebhookModel) PruneDeliveries(cutoff time.Time) (int64, error) {
+	result, err := m.db.Exec(`
+		DELETE FROM webhook_deliveries WHERE status <> 'pending' AND created_at < $1
+	`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
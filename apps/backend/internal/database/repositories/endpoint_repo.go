@@ -71,9 +71,9 @@ func (r *EndpointRepository) Create(ctx context.Context, endpoint *types.Endpoin
 			enable_api_key_auth, enable_oauth, enable_public_access, use_query_param_auth,
 			rate_limit_requests, rate_limit_window,
 			allowed_origins, allowed_methods,
-			created_by, is_active, metadata
+			created_by, is_active, metadata, labels, enable_status_page
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
 		) RETURNING id, created_at, updated_at`
 
 	// Convert metadata to JSONB
@@ -91,7 +91,7 @@ func (r *EndpointRepository) Create(ctx context.Context, endpoint *types.Endpoin
 		endpoint.EnableAPIKeyAuth, endpoint.EnableOAuth, endpoint.EnablePublicAccess, endpoint.UseQueryParamAuth,
 		endpoint.RateLimitRequests, endpoint.RateLimitWindow,
 		pq.Array(endpoint.AllowedOrigins), pq.Array(endpoint.AllowedMethods),
-		endpoint.CreatedBy, endpoint.IsActive, metadataValue,
+		endpoint.CreatedBy, endpoint.IsActive, metadataValue, endpoint.Labels, endpoint.EnableStatusPage,
 	).Scan(&endpoint.ID, &endpoint.CreatedAt, &endpoint.UpdatedAt)
 
 	if err != nil {
@@ -111,7 +111,7 @@ func (r *EndpointRepository) GetByID(ctx context.Context, id string) (*types.End
 			enable_api_key_auth, enable_oauth, enable_public_access, use_query_param_auth,
 			rate_limit_requests, rate_limit_window,
 			allowed_origins, allowed_methods,
-			created_at, updated_at, created_by, is_active, metadata
+			created_at, updated_at, created_by, is_active, metadata, labels, enable_status_page
 		FROM endpoints
 		WHERE id = $1`
 
@@ -121,7 +121,7 @@ func (r *EndpointRepository) GetByID(ctx context.Context, id string) (*types.End
 		&endpoint.EnableAPIKeyAuth, &endpoint.EnableOAuth, &endpoint.EnablePublicAccess, &endpoint.UseQueryParamAuth,
 		&endpoint.RateLimitRequests, &endpoint.RateLimitWindow,
 		pq.Array(&endpoint.AllowedOrigins), pq.Array(&endpoint.AllowedMethods),
-		&endpoint.CreatedAt, &endpoint.UpdatedAt, &endpoint.CreatedBy, &endpoint.IsActive, &metadata,
+		&endpoint.CreatedAt, &endpoint.UpdatedAt, &endpoint.CreatedBy, &endpoint.IsActive, &metadata, &endpoint.Labels, &endpoint.EnableStatusPage,
 	)
 	endpoint.Metadata = metadata.Data
 
@@ -145,7 +145,7 @@ func (r *EndpointRepository) GetByName(ctx context.Context, name string) (*types
 			enable_api_key_auth, enable_oauth, enable_public_access, use_query_param_auth,
 			rate_limit_requests, rate_limit_window,
 			allowed_origins, allowed_methods,
-			created_at, updated_at, created_by, is_active, metadata
+			created_at, updated_at, created_by, is_active, metadata, labels, enable_status_page
 		FROM endpoints
 		WHERE name = $1 AND is_active = true`
 
@@ -155,7 +155,7 @@ func (r *EndpointRepository) GetByName(ctx context.Context, name string) (*types
 		&endpoint.EnableAPIKeyAuth, &endpoint.EnableOAuth, &endpoint.EnablePublicAccess, &endpoint.UseQueryParamAuth,
 		&endpoint.RateLimitRequests, &endpoint.RateLimitWindow,
 		pq.Array(&endpoint.AllowedOrigins), pq.Array(&endpoint.AllowedMethods),
-		&endpoint.CreatedAt, &endpoint.UpdatedAt, &endpoint.CreatedBy, &endpoint.IsActive, &metadata,
+		&endpoint.CreatedAt, &endpoint.UpdatedAt, &endpoint.CreatedBy, &endpoint.IsActive, &metadata, &endpoint.Labels, &endpoint.EnableStatusPage,
 	)
 	endpoint.Metadata = metadata.Data
 
@@ -195,7 +195,7 @@ func (r *EndpointRepository) List(ctx context.Context, orgID string) ([]*types.E
 			enable_api_key_auth, enable_oauth, enable_public_access, use_query_param_auth,
 			rate_limit_requests, rate_limit_window,
 			allowed_origins, allowed_methods,
-			created_at, updated_at, created_by, is_active, metadata
+			created_at, updated_at, created_by, is_active, metadata, labels, enable_status_page
 		FROM endpoints
 		WHERE organization_id = $1
 		ORDER BY name`
@@ -215,7 +215,7 @@ func (r *EndpointRepository) List(ctx context.Context, orgID string) ([]*types.E
 			&endpoint.EnableAPIKeyAuth, &endpoint.EnableOAuth, &endpoint.EnablePublicAccess, &endpoint.UseQueryParamAuth,
 			&endpoint.RateLimitRequests, &endpoint.RateLimitWindow,
 			pq.Array(&endpoint.AllowedOrigins), pq.Array(&endpoint.AllowedMethods),
-			&endpoint.CreatedAt, &endpoint.UpdatedAt, &endpoint.CreatedBy, &endpoint.IsActive, &metadata,
+			&endpoint.CreatedAt, &endpoint.UpdatedAt, &endpoint.CreatedBy, &endpoint.IsActive, &metadata, &endpoint.Labels, &endpoint.EnableStatusPage,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan endpoint: %w", err)
@@ -237,7 +237,7 @@ func (r *EndpointRepository) GetByNamespaceID(ctx context.Context, namespaceID s
 			enable_api_key_auth, enable_oauth, enable_public_access, use_query_param_auth,
 			rate_limit_requests, rate_limit_window,
 			allowed_origins, allowed_methods,
-			created_at, updated_at, created_by, is_active, metadata
+			created_at, updated_at, created_by, is_active, metadata, labels, enable_status_page
 		FROM endpoints
 		WHERE namespace_id = $1 AND is_active = true
 		LIMIT 1`
@@ -248,7 +248,7 @@ func (r *EndpointRepository) GetByNamespaceID(ctx context.Context, namespaceID s
 		&endpoint.EnableAPIKeyAuth, &endpoint.EnableOAuth, &endpoint.EnablePublicAccess, &endpoint.UseQueryParamAuth,
 		&endpoint.RateLimitRequests, &endpoint.RateLimitWindow,
 		pq.Array(&endpoint.AllowedOrigins), pq.Array(&endpoint.AllowedMethods),
-		&endpoint.CreatedAt, &endpoint.UpdatedAt, &endpoint.CreatedBy, &endpoint.IsActive, &metadata,
+		&endpoint.CreatedAt, &endpoint.UpdatedAt, &endpoint.CreatedBy, &endpoint.IsActive, &metadata, &endpoint.Labels, &endpoint.EnableStatusPage,
 	)
 	endpoint.Metadata = metadata.Data
 
@@ -270,7 +270,7 @@ func (r *EndpointRepository) ListPublic(ctx context.Context) ([]*types.Endpoint,
 			enable_api_key_auth, enable_oauth, enable_public_access, use_query_param_auth,
 			rate_limit_requests, rate_limit_window,
 			allowed_origins, allowed_methods,
-			created_at, updated_at, created_by, is_active, metadata
+			created_at, updated_at, created_by, is_active, metadata, labels, enable_status_page
 		FROM endpoints
 		WHERE is_active = true AND enable_public_access = true
 		ORDER BY name`
@@ -290,7 +290,7 @@ func (r *EndpointRepository) ListPublic(ctx context.Context) ([]*types.Endpoint,
 			&endpoint.EnableAPIKeyAuth, &endpoint.EnableOAuth, &endpoint.EnablePublicAccess, &endpoint.UseQueryParamAuth,
 			&endpoint.RateLimitRequests, &endpoint.RateLimitWindow,
 			pq.Array(&endpoint.AllowedOrigins), pq.Array(&endpoint.AllowedMethods),
-			&endpoint.CreatedAt, &endpoint.UpdatedAt, &endpoint.CreatedBy, &endpoint.IsActive, &metadata,
+			&endpoint.CreatedAt, &endpoint.UpdatedAt, &endpoint.CreatedBy, &endpoint.IsActive, &metadata, &endpoint.Labels, &endpoint.EnableStatusPage,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan endpoint: %w", err)
@@ -317,6 +317,8 @@ func (r *EndpointRepository) Update(ctx context.Context, endpoint *types.Endpoin
 			allowed_methods = $10,
 			is_active = $11,
 			metadata = $12,
+			labels = $13,
+			enable_status_page = $14,
 			updated_at = NOW()
 		WHERE id = $1`
 
@@ -335,7 +337,7 @@ func (r *EndpointRepository) Update(ctx context.Context, endpoint *types.Endpoin
 		endpoint.EnableAPIKeyAuth, endpoint.EnableOAuth, endpoint.EnablePublicAccess, endpoint.UseQueryParamAuth,
 		endpoint.RateLimitRequests, endpoint.RateLimitWindow,
 		pq.Array(endpoint.AllowedOrigins), pq.Array(endpoint.AllowedMethods),
-		endpoint.IsActive, metadataValue,
+		endpoint.IsActive, metadataValue, endpoint.Labels, endpoint.EnableStatusPage,
 	)
 
 	if err != nil {
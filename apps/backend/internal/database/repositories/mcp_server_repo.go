@@ -22,6 +22,8 @@ type MCPServer struct {
 	Environment    []string `db:"environment"`
 	WorkingDir     *string  `db:"working_dir"`
 	IsActive       bool     `db:"is_active"`
+	Region         string   `db:"region"`
+	MaxRetries     int      `db:"max_retries"`
 }
 
 // MCPServerRepository handles MCP server database operations
@@ -39,7 +41,8 @@ func (r *MCPServerRepository) GetByID(ctx context.Context, id string) (*MCPServe
 	server := &MCPServer{}
 
 	query := `
-		SELECT id, organization_id, name, description, protocol, url, command, args, environment, working_dir, is_active
+		SELECT id, organization_id, name, description, protocol, url, command, args, environment, working_dir, is_active,
+			COALESCE(metadata->>'region', ''), COALESCE(max_retries, 0)
 		FROM mcp_servers
 		WHERE id = $1`
 
@@ -47,6 +50,7 @@ func (r *MCPServerRepository) GetByID(ctx context.Context, id string) (*MCPServe
 		&server.ID, &server.OrganizationID, &server.Name, &server.Description,
 		&server.Protocol, &server.URL, &server.Command, (*pq.StringArray)(&server.Args),
 		(*pq.StringArray)(&server.Environment), &server.WorkingDir, &server.IsActive,
+		&server.Region, &server.MaxRetries,
 	)
 
 	if err == sql.ErrNoRows {
@@ -35,15 +35,15 @@ func (r *NamespaceRepository) Create(ctx context.Context, ns *types.Namespace) e
 
 	query := `
 		INSERT INTO namespaces (
-			id, organization_id, name, description,
+			id, organization_id, name, description, region,
 			created_by, is_active, metadata
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7
+			$1, $2, $3, $4, $5, $6, $7, $8
 		) RETURNING created_at, updated_at`
 
 	err = r.db.QueryRowContext(
 		ctx, query,
-		ns.ID, ns.OrganizationID, ns.Name, ns.Description,
+		ns.ID, ns.OrganizationID, ns.Name, ns.Description, ns.Region,
 		ns.CreatedBy, ns.IsActive, metadataJSON,
 	).Scan(&ns.CreatedAt, &ns.UpdatedAt)
 
@@ -61,13 +61,13 @@ func (r *NamespaceRepository) GetByID(ctx context.Context, id string) (*types.Na
 
 	query := `
 		SELECT
-			id, organization_id, name, description,
+			id, organization_id, name, description, region,
 			created_at, updated_at, created_by, is_active, metadata
 		FROM namespaces
 		WHERE id = $1`
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&ns.ID, &ns.OrganizationID, &ns.Name, &ns.Description,
+		&ns.ID, &ns.OrganizationID, &ns.Name, &ns.Description, &ns.Region,
 		&ns.CreatedAt, &ns.UpdatedAt, &ns.CreatedBy, &ns.IsActive, &metadataJSON,
 	)
 
@@ -92,13 +92,13 @@ func (r *NamespaceRepository) GetByName(ctx context.Context, orgID, name string)
 
 	query := `
 		SELECT
-			id, organization_id, name, description,
+			id, organization_id, name, description, region,
 			created_at, updated_at, created_by, is_active, metadata
 		FROM namespaces
 		WHERE organization_id = $1 AND name = $2`
 
 	err := r.db.QueryRowContext(ctx, query, orgID, name).Scan(
-		&ns.ID, &ns.OrganizationID, &ns.Name, &ns.Description,
+		&ns.ID, &ns.OrganizationID, &ns.Name, &ns.Description, &ns.Region,
 		&ns.CreatedAt, &ns.UpdatedAt, &ns.CreatedBy, &ns.IsActive, &metadataJSON,
 	)
 
@@ -120,7 +120,7 @@ func (r *NamespaceRepository) GetByName(ctx context.Context, orgID, name string)
 func (r *NamespaceRepository) List(ctx context.Context, orgID string) ([]*types.Namespace, error) {
 	query := `
 		SELECT
-			id, organization_id, name, description,
+			id, organization_id, name, description, region,
 			created_at, updated_at, created_by, is_active, metadata
 		FROM namespaces
 		WHERE organization_id = $1
@@ -138,7 +138,7 @@ func (r *NamespaceRepository) List(ctx context.Context, orgID string) ([]*types.
 		var metadataJSON []byte
 
 		err := rows.Scan(
-			&ns.ID, &ns.OrganizationID, &ns.Name, &ns.Description,
+			&ns.ID, &ns.OrganizationID, &ns.Name, &ns.Description, &ns.Region,
 			&ns.CreatedAt, &ns.UpdatedAt, &ns.CreatedBy, &ns.IsActive, &metadataJSON,
 		)
 		if err != nil {
@@ -159,13 +159,13 @@ func (r *NamespaceRepository) List(ctx context.Context, orgID string) ([]*types.
 func (r *NamespaceRepository) ListWithServerCount(ctx context.Context, orgID string) ([]*types.Namespace, error) {
 	query := `
 		SELECT
-			n.id, n.organization_id, n.name, n.description,
+			n.id, n.organization_id, n.name, n.description, n.region,
 			n.created_at, n.updated_at, n.created_by, n.is_active, n.metadata,
 			COUNT(DISTINCT nsm.server_id) as server_count
 		FROM namespaces n
 		LEFT JOIN namespace_server_mappings nsm ON n.id = nsm.namespace_id
 		WHERE n.organization_id = $1
-		GROUP BY n.id, n.organization_id, n.name, n.description,
+		GROUP BY n.id, n.organization_id, n.name, n.description, n.region,
 				 n.created_at, n.updated_at, n.created_by, n.is_active, n.metadata
 		ORDER BY n.name`
 
@@ -181,7 +181,7 @@ func (r *NamespaceRepository) ListWithServerCount(ctx context.Context, orgID str
 		var metadataJSON []byte
 
 		err := rows.Scan(
-			&ns.ID, &ns.OrganizationID, &ns.Name, &ns.Description,
+			&ns.ID, &ns.OrganizationID, &ns.Name, &ns.Description, &ns.Region,
 			&ns.CreatedAt, &ns.UpdatedAt, &ns.CreatedBy, &ns.IsActive, &metadataJSON,
 			&ns.ServerCount,
 		)
@@ -209,12 +209,12 @@ func (r *NamespaceRepository) Update(ctx context.Context, ns *types.Namespace) e
 	query := `
 		UPDATE namespaces
 		SET name = $2, description = $3, is_active = $4,
-		    metadata = $5, updated_at = NOW()
+		    metadata = $5, region = $6, updated_at = NOW()
 		WHERE id = $1`
 
 	result, err := r.db.ExecContext(
 		ctx, query,
-		ns.ID, ns.Name, ns.Description, ns.IsActive, metadataJSON,
+		ns.ID, ns.Name, ns.Description, ns.IsActive, metadataJSON, ns.Region,
 	)
 
 	if err != nil {
@@ -328,7 +328,8 @@ func (r *NamespaceRepository) GetServers(ctx context.Context, namespaceID string
 	query := `
 		SELECT
 			nsm.server_id, ms.name as server_name, nsm.status,
-			nsm.priority, nsm.created_at
+			nsm.priority, nsm.created_at,
+			COALESCE(ms.metadata->>'region', '') as region
 		FROM namespace_server_mappings nsm
 		JOIN mcp_servers ms ON nsm.server_id = ms.id
 		WHERE nsm.namespace_id = $1
@@ -345,7 +346,7 @@ func (r *NamespaceRepository) GetServers(ctx context.Context, namespaceID string
 		var server types.NamespaceServer
 		err := rows.Scan(
 			&server.ServerID, &server.ServerName, &server.Status,
-			&server.Priority, &server.JoinedAt,
+			&server.Priority, &server.JoinedAt, &server.Region,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan server: %w", err)
@@ -378,11 +379,96 @@ func (r *NamespaceRepository) SetToolStatus(ctx context.Context, namespaceID, se
 	return nil
 }
 
+// SetToolArguments sets the argument template of a tool in a namespace
+func (r *NamespaceRepository) SetToolArguments(ctx context.Context, namespaceID, serverID, toolName string, template *types.ToolArgumentTemplate) error {
+	defaults, err := json.Marshal(template.Defaults)
+	if err != nil {
+		return fmt.Errorf("failed to marshal argument defaults: %w", err)
+	}
+	locked, err := json.Marshal(template.Locked)
+	if err != nil {
+		return fmt.Errorf("failed to marshal locked arguments: %w", err)
+	}
+
+	query := `
+		INSERT INTO namespace_tool_mappings (
+			id, namespace_id, server_id, tool_name,
+			argument_defaults, locked_arguments, argument_conflict
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		) ON CONFLICT (namespace_id, server_id, tool_name) DO UPDATE
+		SET argument_defaults = $5, locked_arguments = $6, argument_conflict = $7`
+
+	_, err = r.db.ExecContext(
+		ctx, query,
+		uuid.New().String(), namespaceID, serverID, toolName,
+		defaults, locked, template.OnConflict,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set tool arguments: %w", err)
+	}
+
+	return nil
+}
+
+// SetToolResponsePolicy sets the response policy of a tool in a namespace
+func (r *NamespaceRepository) SetToolResponsePolicy(ctx context.Context, namespaceID, serverID, toolName string, policy *types.ToolResponsePolicy) error {
+	encoded, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response policy: %w", err)
+	}
+
+	query := `
+		INSERT INTO namespace_tool_mappings (
+			id, namespace_id, server_id, tool_name, response_policy
+		) VALUES (
+			$1, $2, $3, $4, $5
+		) ON CONFLICT (namespace_id, server_id, tool_name) DO UPDATE
+		SET response_policy = $5`
+
+	_, err = r.db.ExecContext(ctx, query, uuid.New().String(), namespaceID, serverID, toolName, encoded)
+	if err != nil {
+		return fmt.Errorf("failed to set tool response policy: %w", err)
+	}
+
+	return nil
+}
+
+// GetToolConfig retrieves the argument template and response policy of a
+// tool in a namespace. Unset parts are nil.
+func (r *NamespaceRepository) GetToolConfig(ctx context.Context, namespaceID, serverID, toolName string) (*types.NamespaceToolConfig, error) {
+	query := `
+		SELECT argument_defaults, locked_arguments, argument_conflict, response_policy
+		FROM namespace_tool_mappings
+		WHERE namespace_id = $1 AND server_id = $2 AND tool_name = $3`
+
+	var defaults, locked, policy []byte
+	var conflict string
+	err := r.db.QueryRowContext(ctx, query, namespaceID, serverID, toolName).Scan(&defaults, &locked, &conflict, &policy)
+	if err == sql.ErrNoRows {
+		return &types.NamespaceToolConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tool configuration: %w", err)
+	}
+
+	config := &types.NamespaceToolConfig{}
+	if config.Arguments, err = decodeToolArguments(defaults, locked, conflict); err != nil {
+		return nil, err
+	}
+	if config.Response, err = decodeResponsePolicy(policy); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
 // GetTools retrieves all tools in a namespace
 func (r *NamespaceRepository) GetTools(ctx context.Context, namespaceID string) ([]types.NamespaceTool, error) {
 	query := `
 		SELECT
-			ntm.server_id, ms.name as server_name, ntm.tool_name, ntm.status
+			ntm.server_id, ms.name as server_name, ntm.tool_name, ntm.status,
+			ntm.argument_defaults, ntm.locked_arguments, ntm.argument_conflict,
+			ntm.response_policy
 		FROM namespace_tool_mappings ntm
 		JOIN mcp_servers ms ON ntm.server_id = ms.id
 		WHERE ntm.namespace_id = $1
@@ -397,18 +483,55 @@ func (r *NamespaceRepository) GetTools(ctx context.Context, namespaceID string)
 	var tools []types.NamespaceTool
 	for rows.Next() {
 		var tool types.NamespaceTool
+		var defaults, locked, policy []byte
+		var conflict string
 		err := rows.Scan(
 			&tool.ServerID, &tool.ServerName, &tool.ToolName, &tool.Status,
+			&defaults, &locked, &conflict, &policy,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tool: %w", err)
 		}
+		if tool.Arguments, err = decodeToolArguments(defaults, locked, conflict); err != nil {
+			return nil, err
+		}
+		if tool.Response, err = decodeResponsePolicy(policy); err != nil {
+			return nil, err
+		}
 		tools = append(tools, tool)
 	}
 
 	return tools, nil
 }
 
+// decodeToolArguments returns nil when neither defaults nor locked
+// arguments are set
+func decodeToolArguments(defaults, locked []byte, conflict string) (*types.ToolArgumentTemplate, error) {
+	template := &types.ToolArgumentTemplate{OnConflict: conflict}
+	if err := json.Unmarshal(defaults, &template.Defaults); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal argument defaults: %w", err)
+	}
+	if err := json.Unmarshal(locked, &template.Locked); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal locked arguments: %w", err)
+	}
+	if len(template.Defaults) == 0 && len(template.Locked) == 0 {
+		return nil, nil
+	}
+	return template, nil
+}
+
+// decodeResponsePolicy returns nil when the policy has no rules
+func decodeResponsePolicy(encoded []byte) (*types.ToolResponsePolicy, error) {
+	var policy types.ToolResponsePolicy
+	if err := json.Unmarshal(encoded, &policy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response policy: %w", err)
+	}
+	if len(policy.Rules) == 0 {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
 // GetByIDWithServers retrieves a namespace with its servers
 func (r *NamespaceRepository) GetByIDWithServers(ctx context.Context, id string) (*types.Namespace, error) {
 	ns, err := r.GetByID(ctx, id)
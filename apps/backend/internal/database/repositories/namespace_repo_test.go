@@ -27,12 +27,13 @@ func TestNamespaceRepository_Create(t *testing.T) {
 		OrganizationID: "org-123",
 		Name:           "test-namespace",
 		Description:    "Test namespace",
+		Region:         "eu-west",
 		IsActive:       true,
 		Metadata:       map[string]interface{}{"key": "value"},
 	}
 
 	mock.ExpectQuery(`INSERT INTO namespaces`).
-		WithArgs(ns.ID, ns.OrganizationID, ns.Name, ns.Description, nil, ns.IsActive, sqlmock.AnyArg()).
+		WithArgs(ns.ID, ns.OrganizationID, ns.Name, ns.Description, ns.Region, nil, ns.IsActive, sqlmock.AnyArg()).
 		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).
 			AddRow(time.Now(), time.Now()))
 
@@ -55,16 +56,17 @@ func TestNamespaceRepository_GetByID(t *testing.T) {
 		OrganizationID: "org-123",
 		Name:           "test-namespace",
 		Description:    "Test namespace",
+		Region:         "eu-west",
 		IsActive:       true,
 	}
 
 	mock.ExpectQuery(`SELECT .+ FROM namespaces WHERE id = \$1`).
 		WithArgs(nsID).
 		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "organization_id", "name", "description",
+			"id", "organization_id", "name", "description", "region",
 			"created_at", "updated_at", "created_by", "is_active", "metadata",
 		}).AddRow(
-			expectedNS.ID, expectedNS.OrganizationID, expectedNS.Name, expectedNS.Description,
+			expectedNS.ID, expectedNS.OrganizationID, expectedNS.Name, expectedNS.Description, expectedNS.Region,
 			time.Now(), time.Now(), nil, expectedNS.IsActive, []byte("{}"),
 		))
 
@@ -74,6 +76,7 @@ func TestNamespaceRepository_GetByID(t *testing.T) {
 	if result != nil {
 		assert.Equal(t, expectedNS.ID, result.ID)
 		assert.Equal(t, expectedNS.Name, result.Name)
+		assert.Equal(t, expectedNS.Region, result.Region)
 	}
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -91,12 +94,12 @@ func TestNamespaceRepository_List(t *testing.T) {
 	mock.ExpectQuery(`SELECT .+ FROM namespaces WHERE organization_id = \$1`).
 		WithArgs(orgID).
 		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "organization_id", "name", "description",
+			"id", "organization_id", "name", "description", "region",
 			"created_at", "updated_at", "created_by", "is_active", "metadata",
 		}).
-			AddRow("ns-1", orgID, "namespace-1", "First namespace",
+			AddRow("ns-1", orgID, "namespace-1", "First namespace", "",
 				time.Now(), time.Now(), nil, true, []byte("{}")).
-			AddRow("ns-2", orgID, "namespace-2", "Second namespace",
+			AddRow("ns-2", orgID, "namespace-2", "Second namespace", "us-east",
 				time.Now(), time.Now(), nil, true, []byte("{}")))
 
 	result, err := repo.List(context.Background(), orgID)
@@ -125,7 +128,7 @@ func TestNamespaceRepository_Update(t *testing.T) {
 	}
 
 	mock.ExpectExec(`UPDATE namespaces SET`).
-		WithArgs(ns.ID, ns.Name, ns.Description, ns.IsActive, sqlmock.AnyArg()).
+		WithArgs(ns.ID, ns.Name, ns.Description, ns.IsActive, sqlmock.AnyArg(), ns.Region).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err = repo.Update(context.Background(), ns)
@@ -206,16 +209,17 @@ func TestNamespaceRepository_GetServers(t *testing.T) {
 	mock.ExpectQuery(`SELECT .+ FROM namespace_server_mappings`).
 		WithArgs(namespaceID).
 		WillReturnRows(sqlmock.NewRows([]string{
-			"server_id", "server_name", "status", "priority", "created_at",
+			"server_id", "server_name", "status", "priority", "created_at", "region",
 		}).
-			AddRow("srv-1", "server-1", "ACTIVE", 0, time.Now()).
-			AddRow("srv-2", "server-2", "INACTIVE", 1, time.Now()))
+			AddRow("srv-1", "server-1", "ACTIVE", 0, time.Now(), "eu-west").
+			AddRow("srv-2", "server-2", "INACTIVE", 1, time.Now(), ""))
 
 	servers, err := repo.GetServers(context.Background(), namespaceID)
 	assert.NoError(t, err)
 	assert.Len(t, servers, 2)
 	assert.Equal(t, "server-1", servers[0].ServerName)
 	assert.Equal(t, "ACTIVE", servers[0].Status)
+	assert.Equal(t, "eu-west", servers[0].Region)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -23,6 +23,7 @@ type HealthChecker struct {
 	stopCh        chan struct{}
 	healthModel   *models.HealthCheckModel
 	failureCounts map[string]int
+	emit          func(metric *types.Metric)
 	wg            sync.WaitGroup
 	mu            sync.RWMutex
 	running       bool
@@ -42,6 +43,13 @@ func NewHealthChecker(registry *Registry, config *Config, healthModel *models.He
 	}
 }
 
+// SetMetricEmitter reports every health check result through emit
+func (h *HealthChecker) SetMetricEmitter(emit func(metric *types.Metric)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.emit = emit
+}
+
 // Start starts the health checking process
 func (h *HealthChecker) Start() error {
 	if h.running {
@@ -228,6 +236,8 @@ func (h *HealthChecker) updateServerHealth(serverID string, healthCheck *types.H
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	h.emitHealthCheck(healthCheck)
+
 	switch healthCheck.Status {
 	case types.HealthStatusHealthy:
 		// Reset failure count on successful health check
@@ -306,6 +316,34 @@ func (h *HealthChecker) GetServerHealth(serverID string) (*types.HealthCheck, er
 	}, nil
 }
 
+// emitHealthCheck counts a health check by result and reports whether the
+// server passed; h.mu must be held
+func (h *HealthChecker) emitHealthCheck(healthCheck *types.HealthCheck) {
+	if h.emit == nil {
+		return
+	}
+
+	healthy := 0.0
+	if healthCheck.Status == types.HealthStatusHealthy {
+		healthy = 1
+	}
+	h.emit(&types.Metric{
+		Timestamp: healthCheck.CheckedAt,
+		Name:      "health_checks_total",
+		Type:      types.MetricTypeCounter,
+		Value:     1,
+		Tags:      map[string]string{"status": healthCheck.Status},
+		ServerID:  healthCheck.ServerID,
+	})
+	h.emit(&types.Metric{
+		Timestamp: healthCheck.CheckedAt,
+		Name:      "mcp_server_healthy",
+		Type:      types.MetricTypeGauge,
+		Value:     healthy,
+		ServerID:  healthCheck.ServerID,
+	})
+}
+
 // saveHealthCheck saves a health check result to the database
 func (h *HealthChecker) saveHealthCheck(healthCheck *types.HealthCheck) {
 	serverUUID, err := uuid.Parse(healthCheck.ServerID)
@@ -0,0 +1,562 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Metadata marking servers registered by a discovery provider. Only
+// servers carrying it are updated or deregistered by the provider.
+const (
+	MetadataDiscoverySource   = "discovery_source"
+	DiscoverySourceKubernetes = "kubernetes"
+	MetadataKubernetesNS      = "kubernetes_namespace"
+	MetadataKubernetesService = "kubernetes_service"
+	MetadataKubernetesUID     = "kubernetes_uid"
+)
+
+// Annotations on a Service that shape the server registered for it
+const (
+	AnnotationMCPName        = "omnimesh.io/mcp-name"
+	AnnotationMCPProtocol    = "omnimesh.io/mcp-protocol"
+	AnnotationMCPPort        = "omnimesh.io/mcp-port"
+	AnnotationMCPPath        = "omnimesh.io/mcp-path"
+	AnnotationMCPDescription = "omnimesh.io/mcp-description"
+)
+
+const (
+	// DefaultKubernetesLabelSelector selects the Services registered
+	// when the configuration sets no selector
+	DefaultKubernetesLabelSelector = "omnimesh.io/mcp-server=true"
+	// DefaultKubernetesResync is how often Services are listed again even
+	// when no watch event arrived
+	DefaultKubernetesResync = 5 * time.Minute
+	defaultClusterDomain    = "cluster.local"
+	serviceAccountDir       = "/var/run/secrets/kubernetes.io/serviceaccount"
+	// kubernetesDebounce batches the watch events of a rollout into one sync
+	kubernetesDebounce = time.Second
+	// kubernetesRetryDelay spaces syncs after the API server failed
+	kubernetesRetryDelay = 5 * time.Second
+	kubernetesPageSize   = 500
+)
+
+// KubernetesConfig selects the Services registered as MCP servers and how
+// the Kubernetes API is reached. Left empty, the API server, token and CA
+// are those of the pod's service account.
+type KubernetesConfig struct {
+	APIServer     string
+	TokenFile     string
+	CAFile        string
+	LabelSelector string
+	ClusterDomain string
+	// OrganizationID owns the registered servers when the registrar is
+	// multi-tenant; single-tenant registrars use the default organization
+	OrganizationID string
+	// Namespaces limits discovery; empty watches every namespace
+	Namespaces     []string
+	ResyncInterval time.Duration
+}
+
+// ServerRegistrar registers the servers a provider discovers, implemented
+// by Service
+type ServerRegistrar interface {
+	ListServers(orgID string) ([]*types.MCPServer, error)
+	RegisterServer(orgID string, req *types.CreateMCPServerRequest) (*types.MCPServer, error)
+	UpdateServer(serverID string, req *types.UpdateMCPServerRequest) (*types.MCPServer, error)
+	UnregisterServer(serverID string) error
+	SetServerReadiness(serverID string, ready bool, message string) error
+}
+
+// KubernetesSyncResult counts the changes a sync made
+type KubernetesSyncResult struct {
+	Registered   int
+	Updated      int
+	Deregistered int
+	Failed       int
+}
+
+// KubernetesProvider registers labelled Kubernetes Services as MCP servers
+// and deregisters them when they go away. A server is healthy while its
+// Service has ready pods, as listed by its EndpointSlices, and is not
+// probed by the gateway's own health checks.
+type KubernetesProvider struct {
+	registrar ServerRegistrar
+	client    *http.Client
+	// watchClient has no timeout, as watches stay open until the resync
+	watchClient *http.Client
+	config      KubernetesConfig
+}
+
+// NewKubernetesProvider creates a provider registering Services with
+// registrar
+func NewKubernetesProvider(registrar ServerRegistrar, config KubernetesConfig) (*KubernetesProvider, error) {
+	if config.APIServer == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, errors.New("kubernetes API server is not configured and the gateway is not running in a pod")
+		}
+		config.APIServer = "https://" + net.JoinHostPort(host, port)
+	}
+	config.APIServer = strings.TrimSuffix(config.APIServer, "/")
+	if config.LabelSelector == "" {
+		config.LabelSelector = DefaultKubernetesLabelSelector
+	}
+	if config.ClusterDomain == "" {
+		config.ClusterDomain = defaultClusterDomain
+	}
+	if config.ResyncInterval <= 0 {
+		config.ResyncInterval = DefaultKubernetesResync
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	caFile := config.CAFile
+	if caFile == "" {
+		caFile = serviceAccountDir + "/ca.crt"
+	}
+	if pem, err := os.ReadFile(caFile); err == nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in kubernetes CA file %s", caFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	} else if config.CAFile != "" {
+		return nil, fmt.Errorf("failed to read kubernetes CA file: %w", err)
+	}
+
+	return &KubernetesProvider{
+		registrar:   registrar,
+		client:      &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		watchClient: &http.Client{Transport: transport},
+		config:      config,
+	}, nil
+}
+
+// Run syncs the Services until ctx ends: after every change a watch
+// reports, and every resync interval. Syncs are skipped while active
+// reports false, so only one region of a failover pair registers servers.
+func (p *KubernetesProvider) Run(ctx context.Context, active func(context.Context) bool) {
+	for ctx.Err() == nil {
+		var versions []string
+		if active == nil || active(ctx) {
+			result, listed, err := p.sync(ctx)
+			if err != nil {
+				log.Printf("Kubernetes discovery sync failed: %v", err)
+				sleepContext(ctx, kubernetesRetryDelay)
+				continue
+			}
+			if result.Registered+result.Updated+result.Deregistered+result.Failed > 0 {
+				log.Printf("Kubernetes discovery: %d registered, %d updated, %d deregistered, %d failed",
+					result.Registered, result.Updated, result.Deregistered, result.Failed)
+			}
+			versions = listed
+		}
+
+		if len(versions) == 0 {
+			sleepContext(ctx, p.config.ResyncInterval)
+			continue
+		}
+		p.waitForChange(ctx, versions)
+		sleepContext(ctx, kubernetesDebounce)
+	}
+}
+
+// Sync registers, updates and deregisters servers to match the Services
+// the selector matches now
+func (p *KubernetesProvider) Sync(ctx context.Context) (*KubernetesSyncResult, error) {
+	result, _, err := p.sync(ctx)
+	return result, err
+}
+
+// discoveredService is the server a Service should be registered as
+type discoveredService struct {
+	request *types.CreateMCPServerRequest
+	message string
+	ready   bool
+}
+
+// sync returns, with its result, the list paths and resource versions to
+// watch for changes, as "path\x00version" pairs
+func (p *KubernetesProvider) sync(ctx context.Context) (*KubernetesSyncResult, []string, error) {
+	var services []kubeService
+	var slices []kubeEndpointSlice
+	var versions []string
+	for _, path := range p.listPaths("/api/v1", "services") {
+		var page []kubeService
+		version, err := p.list(ctx, path, &page)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		services = append(services, page...)
+		versions = append(versions, path+"\x00"+version)
+	}
+	for _, path := range p.listPaths("/apis/discovery.k8s.io/v1", "endpointslices") {
+		var page []kubeEndpointSlice
+		version, err := p.list(ctx, path, &page)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list endpoint slices: %w", err)
+		}
+		slices = append(slices, page...)
+		versions = append(versions, path+"\x00"+version)
+	}
+
+	// Pods are ready when their endpoints are; a nil condition means ready
+	readyPods := map[string]int{}
+	for _, slice := range slices {
+		key := slice.Metadata.Namespace + "/" + slice.Metadata.Labels["kubernetes.io/service-name"]
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				readyPods[key]++
+			}
+		}
+	}
+
+	desired := map[string]*discoveredService{}
+	result := &KubernetesSyncResult{}
+	for _, service := range services {
+		if service.Metadata.DeletionTimestamp != "" {
+			continue
+		}
+		key := service.Metadata.Namespace + "/" + service.Metadata.Name
+		request, err := p.serverRequest(service)
+		if err != nil {
+			log.Printf("Kubernetes discovery skipped service %s: %v", key, err)
+			result.Failed++
+			continue
+		}
+		discovered := &discoveredService{request: request, ready: readyPods[key] > 0}
+		if !discovered.ready {
+			discovered.message = fmt.Sprintf("Service %s has no ready pods", key)
+		}
+		desired[key] = discovered
+	}
+
+	servers, err := p.registrar.ListServers(p.config.OrganizationID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+	registered := map[string]*types.MCPServer{}
+	for _, server := range servers {
+		if server.Metadata[MetadataDiscoverySource] != DiscoverySourceKubernetes {
+			continue
+		}
+		key := server.Metadata[MetadataKubernetesNS] + "/" + server.Metadata[MetadataKubernetesService]
+		if _, ok := desired[key]; !ok {
+			if err := p.registrar.UnregisterServer(server.ID); err != nil {
+				log.Printf("Kubernetes discovery failed to deregister server %s: %v", server.Name, err)
+				result.Failed++
+				continue
+			}
+			result.Deregistered++
+			continue
+		}
+		registered[key] = server
+	}
+
+	for key, discovered := range desired {
+		server := registered[key]
+		request := discovered.request
+		if server == nil {
+			if server, err = p.registrar.RegisterServer(p.config.OrganizationID, request); err != nil {
+				log.Printf("Kubernetes discovery failed to register service %s: %v", key, err)
+				result.Failed++
+				continue
+			}
+			result.Registered++
+		} else if server.Name != request.Name || server.URL != request.URL || server.Protocol != request.Protocol ||
+			server.Description != request.Description || server.Metadata[MetadataKubernetesUID] != request.Metadata[MetadataKubernetesUID] {
+			if server, err = p.registrar.UpdateServer(server.ID, &types.UpdateMCPServerRequest{
+				Name:        request.Name,
+				URL:         request.URL,
+				Protocol:    request.Protocol,
+				Description: request.Description,
+				Metadata:    request.Metadata,
+			}); err != nil {
+				log.Printf("Kubernetes discovery failed to update service %s: %v", key, err)
+				result.Failed++
+				continue
+			}
+			result.Updated++
+		}
+
+		status := types.ServerStatusUnhealthy
+		if discovered.ready {
+			status = types.ServerStatusActive
+		}
+		if server.Status != status {
+			if err := p.registrar.SetServerReadiness(server.ID, discovered.ready, discovered.message); err != nil {
+				log.Printf("Kubernetes discovery failed to set readiness of service %s: %v", key, err)
+			}
+		}
+	}
+	return result, versions, nil
+}
+
+// serverRequest builds the registration of a Service from its annotations
+func (p *KubernetesProvider) serverRequest(service kubeService) (*types.CreateMCPServerRequest, error) {
+	meta := service.Metadata
+	annotations := meta.Annotations
+
+	protocol := types.ProtocolHTTP
+	if value := annotations[AnnotationMCPProtocol]; value != "" {
+		protocol = strings.ToLower(value)
+	}
+	scheme := "http"
+	switch protocol {
+	case types.ProtocolHTTP, types.ProtocolSSE:
+	case types.ProtocolHTTPS:
+		scheme = "https"
+	case types.ProtocolWebSocket:
+		scheme = "ws"
+	default:
+		return nil, fmt.Errorf("unsupported %s %q, expected http, https, sse or websocket", AnnotationMCPProtocol, protocol)
+	}
+
+	port, err := servicePort(service, annotations[AnnotationMCPPort])
+	if err != nil {
+		return nil, err
+	}
+	path := annotations[AnnotationMCPPath]
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	host := fmt.Sprintf("%s.%s.svc.%s", meta.Name, meta.Namespace, p.config.ClusterDomain)
+	serverURL := (&url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(port)), Path: path}).String()
+
+	name := annotations[AnnotationMCPName]
+	if name == "" {
+		name = meta.Name + "." + meta.Namespace
+	}
+	return &types.CreateMCPServerRequest{
+		Name:        name,
+		Description: annotations[AnnotationMCPDescription],
+		Protocol:    protocol,
+		URL:         serverURL,
+		Metadata: map[string]string{
+			MetadataDiscoverySource:   DiscoverySourceKubernetes,
+			MetadataKubernetesNS:      meta.Namespace,
+			MetadataKubernetesService: meta.Name,
+			MetadataKubernetesUID:     meta.UID,
+		},
+	}, nil
+}
+
+// servicePort picks the port named or numbered by the annotation, else the
+// port named "mcp", else the first port
+func servicePort(service kubeService, annotation string) (int, error) {
+	ports := service.Spec.Ports
+	if len(ports) == 0 {
+		return 0, errors.New("service has no ports")
+	}
+	if annotation == "" {
+		for _, port := range ports {
+			if port.Name == "mcp" {
+				return port.Port, nil
+			}
+		}
+		return ports[0].Port, nil
+	}
+	for _, port := range ports {
+		if port.Name == annotation || strconv.Itoa(port.Port) == annotation {
+			return port.Port, nil
+		}
+	}
+	return 0, fmt.Errorf("service has no port %q named by %s", annotation, AnnotationMCPPort)
+}
+
+// listPaths returns the collection paths of a resource, one per
+// configured namespace or a single cluster-wide path
+func (p *KubernetesProvider) listPaths(group, resource string) []string {
+	if len(p.config.Namespaces) == 0 {
+		return []string{group + "/" + resource}
+	}
+	paths := make([]string, len(p.config.Namespaces))
+	for i, namespace := range p.config.Namespaces {
+		paths[i] = group + "/namespaces/" + url.PathEscape(namespace) + "/" + resource
+	}
+	return paths
+}
+
+// list reads every page of a collection matching the selector into items
+// and returns the collection's resource version
+func (p *KubernetesProvider) list(ctx context.Context, path string, items interface{}) (string, error) {
+	var all []json.RawMessage
+	var version, next string
+	for {
+		query := url.Values{"labelSelector": {p.config.LabelSelector}, "limit": {strconv.Itoa(kubernetesPageSize)}}
+		if next != "" {
+			query.Set("continue", next)
+		}
+		var page kubeList
+		if err := p.get(ctx, p.client, path, query, func(body io.Reader) error {
+			return json.NewDecoder(body).Decode(&page)
+		}); err != nil {
+			return "", err
+		}
+		all = append(all, page.Items...)
+		version, next = page.Metadata.ResourceVersion, page.Metadata.Continue
+		if next == "" {
+			break
+		}
+	}
+
+	data, err := json.Marshal(all)
+	if err != nil {
+		return "", err
+	}
+	return version, json.Unmarshal(data, items)
+}
+
+// waitForChange watches the listed collections from their versions and
+// returns at the first change, or when the watches end at the resync
+func (p *KubernetesProvider) waitForChange(ctx context.Context, versions []string) {
+	ctx, cancel := context.WithTimeout(ctx, p.config.ResyncInterval+30*time.Second)
+	defer cancel()
+
+	done := make(chan struct{}, len(versions))
+	for _, pair := range versions {
+		path, version, _ := strings.Cut(pair, "\x00")
+		go func() {
+			if err := p.watch(ctx, path, version); err != nil && ctx.Err() == nil {
+				log.Printf("Kubernetes discovery watch of %s ended: %v", path, err)
+			}
+			done <- struct{}{}
+		}()
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// watch returns at the first event after version, or when the API server
+// closes the watch
+func (p *KubernetesProvider) watch(ctx context.Context, path, version string) error {
+	query := url.Values{
+		"labelSelector":   {p.config.LabelSelector},
+		"watch":           {"true"},
+		"resourceVersion": {version},
+		"timeoutSeconds":  {strconv.Itoa(int(p.config.ResyncInterval.Seconds()))},
+	}
+	return p.get(ctx, p.watchClient, path, query, func(body io.Reader) error {
+		decoder := json.NewDecoder(body)
+		for {
+			var event struct {
+				Type string `json:"type"`
+			}
+			if err := decoder.Decode(&event); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+			if event.Type != "BOOKMARK" {
+				return nil
+			}
+		}
+	})
+}
+
+func (p *KubernetesProvider) get(ctx context.Context, client *http.Client, path string, query url.Values, read func(io.Reader) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.APIServer+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	token, err := p.token()
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("kubernetes API returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return read(resp.Body)
+}
+
+// token reads the bearer token for every request, as projected service
+// account tokens are rotated on disk
+func (p *KubernetesProvider) token() (string, error) {
+	path := p.config.TokenFile
+	if path == "" {
+		path = serviceAccountDir + "/token"
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if p.config.TokenFile == "" && errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read kubernetes token: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// The parts of Kubernetes API objects discovery reads
+
+type kubeList struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+		Continue        string `json:"continue"`
+	} `json:"metadata"`
+	Items []json.RawMessage `json:"items"`
+}
+
+type kubeObjectMeta struct {
+	Labels            map[string]string `json:"labels"`
+	Annotations       map[string]string `json:"annotations"`
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	UID               string            `json:"uid"`
+	DeletionTimestamp string            `json:"deletionTimestamp"`
+}
+
+type kubeService struct {
+	Metadata kubeObjectMeta `json:"metadata"`
+	Spec     struct {
+		Ports []struct {
+			Name string `json:"name"`
+			Port int    `json:"port"`
+		} `json:"ports"`
+	} `json:"spec"`
+}
+
+type kubeEndpointSlice struct {
+	Metadata  kubeObjectMeta `json:"metadata"`
+	Endpoints []struct {
+		Conditions struct {
+			Ready *bool `json:"ready"`
+		} `json:"conditions"`
+	} `json:"endpoints"`
+}
@@ -12,8 +12,9 @@ import (
 
 // MCPDiscoveryService handles external MCP package discovery
 type MCPDiscoveryService struct {
-	httpClient *http.Client
-	baseURL    string
+	httpClient  *http.Client
+	unavailable error
+	baseURL     string
 }
 
 // NewMCPDiscoveryService creates a new MCP discovery service
@@ -26,8 +27,18 @@ func NewMCPDiscoveryService(baseURL string) *MCPDiscoveryService {
 	}
 }
 
+// NewUnavailableMCPDiscoveryService creates a discovery service whose
+// searches all fail with err, used when offline mode blocks the service
+func NewUnavailableMCPDiscoveryService(err error) *MCPDiscoveryService {
+	return &MCPDiscoveryService{unavailable: err}
+}
+
 // SearchPackages searches for MCP packages using the external discovery service
 func (s *MCPDiscoveryService) SearchPackages(req *types.MCPDiscoveryRequest) (*types.MCPDiscoveryResponse, error) {
+	if s.unavailable != nil {
+		return nil, s.unavailable
+	}
+
 	// Check if base URL is configured
 	if s.baseURL == "" {
 		return &types.MCPDiscoveryResponse{
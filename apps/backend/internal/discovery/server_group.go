@@ -0,0 +1,407 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// ServerGroupStore persists server groups and their replicas
+type ServerGroupStore interface {
+	ServerInOrganization(orgID, serverID string) (bool, error)
+	List(orgID string) ([]*types.ServerGroup, error)
+	Get(orgID, id string) (*types.ServerGroup, error)
+	GetByServer(serverID string) (*types.ServerGroup, error)
+	Create(group *types.ServerGroup) error
+	Update(group *types.ServerGroup) error
+	Delete(orgID, id string) (bool, error)
+	SetMember(groupID, serverID string, weight int) error
+	RemoveMember(groupID, serverID string) (bool, error)
+}
+
+// ReplicaScorer scores servers from 0 to 1 by how much a degradation
+// impacts the calls made to them
+type ReplicaScorer interface {
+	ServerScore(serverID string) float64
+}
+
+// DefaultReplicaTolerance is how far below the best score a replica may
+// fall and still share a group's calls
+const DefaultReplicaTolerance = 0.2
+
+// ServerGroups manages groups of replicas of the same MCP server and
+// balances calls over a group's healthy replicas
+type ServerGroups struct {
+	store  ServerGroupStore
+	scorer ReplicaScorer
+	// tolerance is how far below the best score a replica may fall and
+	// still be picked
+	tolerance float64
+
+	mu sync.Mutex
+	// next is the round robin position of each group
+	next map[string]int
+	// current holds the smooth weighted round robin state of each group
+	current map[string]map[string]int
+	// inFlight counts the calls in progress on each replica
+	inFlight map[string]int
+}
+
+// NewServerGroups creates server groups over store
+func NewServerGroups(store ServerGroupStore) *ServerGroups {
+	return &ServerGroups{
+		store:    store,
+		next:     make(map[string]int),
+		current:  make(map[string]map[string]int),
+		inFlight: make(map[string]int),
+	}
+}
+
+// SetReplicaScorer makes the groups prefer the replicas scored within
+// tolerance of the best one, so calls move away from replicas impacted by
+// a degradation while the others are fine. A tolerance of zero takes the
+// default.
+func (g *ServerGroups) SetReplicaScorer(scorer ReplicaScorer, tolerance float64) {
+	if tolerance <= 0 {
+		tolerance = DefaultReplicaTolerance
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.scorer = scorer
+	g.tolerance = tolerance
+}
+
+// ListGroups returns the organization's server groups
+func (g *ServerGroups) ListGroups(ctx context.Context, orgID string) ([]*types.ServerGroup, error) {
+	groups, err := g.store.List(orgID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list server groups: " + err.Error())
+	}
+	for _, group := range groups {
+		g.countRequests(group)
+	}
+	return groups, nil
+}
+
+// GetGroup returns a server group of the organization
+func (g *ServerGroups) GetGroup(ctx context.Context, orgID, id string) (*types.ServerGroup, error) {
+	group, err := g.store.Get(orgID, id)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get server group: " + err.Error())
+	}
+	if group == nil {
+		return nil, types.NewNotFoundError("Server group not found")
+	}
+	g.countRequests(group)
+	return group, nil
+}
+
+// CreateGroup creates a server group, optionally with its replicas
+func (g *ServerGroups) CreateGroup(ctx context.Context, orgID string, req *types.CreateServerGroupRequest) (*types.ServerGroup, error) {
+	group := &types.ServerGroup{
+		OrganizationID: orgID,
+		Name:           strings.TrimSpace(req.Name),
+		Description:    req.Description,
+		Strategy:       req.Strategy,
+		Members:        []types.ServerGroupMember{},
+	}
+	if group.Strategy == "" {
+		group.Strategy = types.LoadBalanceRoundRobin
+	}
+	if err := g.validateGroup(group); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(req.Members))
+	for _, member := range req.Members {
+		if seen[member.ServerID] {
+			return nil, types.NewValidationError(fmt.Sprintf("server %s is listed twice", member.ServerID))
+		}
+		seen[member.ServerID] = true
+		if err := g.checkMember(orgID, "", member.ServerID); err != nil {
+			return nil, err
+		}
+		group.Members = append(group.Members, types.ServerGroupMember{ServerID: member.ServerID, Weight: memberWeight(member.Weight)})
+	}
+
+	if err := g.store.Create(group); err != nil {
+		return nil, types.NewInternalError("Failed to create server group: " + err.Error())
+	}
+	return g.GetGroup(ctx, orgID, group.ID)
+}
+
+// UpdateGroup changes a server group's name, description or strategy
+func (g *ServerGroups) UpdateGroup(ctx context.Context, orgID, id string, req *types.UpdateServerGroupRequest) (*types.ServerGroup, error) {
+	group, err := g.GetGroup(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		group.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+	if req.Strategy != "" {
+		group.Strategy = req.Strategy
+	}
+	if err := g.validateGroup(group); err != nil {
+		return nil, err
+	}
+
+	if err := g.store.Update(group); err != nil {
+		return nil, types.NewInternalError("Failed to update server group: " + err.Error())
+	}
+	g.reset(group.ID)
+	return group, nil
+}
+
+// DeleteGroup removes a server group. Its servers stay registered and are
+// called directly again.
+func (g *ServerGroups) DeleteGroup(ctx context.Context, orgID, id string) error {
+	deleted, err := g.store.Delete(orgID, id)
+	if err != nil {
+		return types.NewInternalError("Failed to delete server group: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Server group not found")
+	}
+	g.reset(id)
+	return nil
+}
+
+// SetMember adds a replica to a server group, or changes its weight
+func (g *ServerGroups) SetMember(ctx context.Context, orgID, groupID string, req *types.AddServerGroupMemberRequest) (*types.ServerGroup, error) {
+	if _, err := g.GetGroup(ctx, orgID, groupID); err != nil {
+		return nil, err
+	}
+	if err := g.checkMember(orgID, groupID, req.ServerID); err != nil {
+		return nil, err
+	}
+
+	if err := g.store.SetMember(groupID, req.ServerID, memberWeight(req.Weight)); err != nil {
+		return nil, types.NewInternalError("Failed to add server to group: " + err.Error())
+	}
+	g.reset(groupID)
+	return g.GetGroup(ctx, orgID, groupID)
+}
+
+// RemoveMember removes a replica from a server group
+func (g *ServerGroups) RemoveMember(ctx context.Context, orgID, groupID, serverID string) (*types.ServerGroup, error) {
+	if _, err := g.GetGroup(ctx, orgID, groupID); err != nil {
+		return nil, err
+	}
+
+	removed, err := g.store.RemoveMember(groupID, serverID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to remove server from group: " + err.Error())
+	}
+	if !removed {
+		return nil, types.NewNotFoundError("Server is not in this group")
+	}
+	g.reset(groupID)
+	return g.GetGroup(ctx, orgID, groupID)
+}
+
+// PickReplica chooses the replica that serves a call to serverID and
+// returns a function to call once the call is done. Servers in no group
+// serve their own calls. allow, if set, limits the replicas to regions
+// the caller may use.
+func (g *ServerGroups) PickReplica(ctx context.Context, serverID string, allow func(region string) bool) (string, func(), error) {
+	group, err := g.store.GetByServer(serverID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get server group: %w", err)
+	}
+	if group == nil {
+		return serverID, func() {}, nil
+	}
+
+	replicas := healthyReplicas(group.Members, allow)
+	if len(replicas) == 0 {
+		return "", nil, fmt.Errorf("no healthy replica in server group %s", group.Name)
+	}
+
+	g.mu.Lock()
+	replicas = g.leastImpacted(replicas)
+	chosen := g.choose(group, replicas)
+	g.inFlight[chosen]++
+	g.mu.Unlock()
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			g.mu.Lock()
+			if g.inFlight[chosen]--; g.inFlight[chosen] <= 0 {
+				delete(g.inFlight, chosen)
+			}
+			g.mu.Unlock()
+		})
+	}
+	return chosen, release, nil
+}
+
+// choose picks one of replicas with the group's strategy; callers hold g.mu
+func (g *ServerGroups) choose(group *types.ServerGroup, replicas []types.ServerGroupMember) string {
+	switch group.Strategy {
+	case types.LoadBalanceLeastConnections:
+		// Ties go round robin so that idle replicas share the load
+		start := g.next[group.ID] % len(replicas)
+		g.next[group.ID] = start + 1
+		best := replicas[start].ServerID
+		for i := 1; i < len(replicas); i++ {
+			candidate := replicas[(start+i)%len(replicas)].ServerID
+			if g.inFlight[candidate] < g.inFlight[best] {
+				best = candidate
+			}
+		}
+		return best
+
+	case types.LoadBalanceWeighted:
+		// Smooth weighted round robin spreads a replica's turns evenly
+		// instead of sending its whole share in a burst
+		current := g.current[group.ID]
+		if current == nil {
+			current = make(map[string]int)
+			g.current[group.ID] = current
+		}
+		total, best := 0, ""
+		for _, replica := range replicas {
+			weight := memberWeight(replica.Weight)
+			current[replica.ServerID] += weight
+			total += weight
+			if best == "" || current[replica.ServerID] > current[best] {
+				best = replica.ServerID
+			}
+		}
+		current[best] -= total
+		return best
+
+	default:
+		position := g.next[group.ID] % len(replicas)
+		g.next[group.ID] = position + 1
+		return replicas[position].ServerID
+	}
+}
+
+// leastImpacted keeps the replicas scored within the tolerance of the best
+// one; callers hold g.mu
+func (g *ServerGroups) leastImpacted(replicas []types.ServerGroupMember) []types.ServerGroupMember {
+	if g.scorer == nil || len(replicas) < 2 {
+		return replicas
+	}
+
+	scores := make([]float64, len(replicas))
+	best := 0.0
+	for i, replica := range replicas {
+		scores[i] = g.scorer.ServerScore(replica.ServerID)
+		best = max(best, scores[i])
+	}
+
+	preferred := make([]types.ServerGroupMember, 0, len(replicas))
+	for i, replica := range replicas {
+		if scores[i] >= best-g.tolerance {
+			preferred = append(preferred, replica)
+		}
+	}
+	return preferred
+}
+
+// healthyReplicas returns the active replicas that passed their last health
+// check. Before any replica has been checked, those not known to be
+// unhealthy or under maintenance are used.
+func healthyReplicas(members []types.ServerGroupMember, allow func(region string) bool) []types.ServerGroupMember {
+	var healthy, unchecked []types.ServerGroupMember
+	for _, member := range members {
+		if !member.IsActive || (allow != nil && !allow(member.Region)) {
+			continue
+		}
+		switch member.Status {
+		case types.ServerStatusActive:
+			healthy = append(healthy, member)
+		case types.ServerStatusInactive:
+			unchecked = append(unchecked, member)
+		}
+	}
+	if len(healthy) > 0 {
+		return healthy
+	}
+	return unchecked
+}
+
+// checkMember returns an error unless the server belongs to the
+// organization and is in no group other than groupID
+func (g *ServerGroups) checkMember(orgID, groupID, serverID string) error {
+	exists, err := g.store.ServerInOrganization(orgID, serverID)
+	if err != nil {
+		return types.NewInternalError("Failed to get server: " + err.Error())
+	}
+	if !exists {
+		return types.NewNotFoundError(fmt.Sprintf("Server %s not found", serverID))
+	}
+
+	current, err := g.store.GetByServer(serverID)
+	if err != nil {
+		return types.NewInternalError("Failed to get server group: " + err.Error())
+	}
+	if current != nil && current.ID != groupID {
+		return types.NewConflictError(fmt.Sprintf("Server %s is already in server group %s", serverID, current.Name))
+	}
+	return nil
+}
+
+func (g *ServerGroups) validateGroup(group *types.ServerGroup) error {
+	if group.Name == "" {
+		return types.NewValidationError("name is required")
+	}
+	switch group.Strategy {
+	case types.LoadBalanceRoundRobin, types.LoadBalanceLeastConnections, types.LoadBalanceWeighted:
+	default:
+		return types.NewValidationError("strategy must be round_robin, least_connections or weighted")
+	}
+
+	existing, err := g.store.List(group.OrganizationID)
+	if err != nil {
+		return types.NewInternalError("Failed to list server groups: " + err.Error())
+	}
+	for _, other := range existing {
+		if other.ID != group.ID && strings.EqualFold(other.Name, group.Name) {
+			return types.NewConflictError("A server group with this name already exists")
+		}
+	}
+	return nil
+}
+
+// countRequests fills in the calls in flight on a group's replicas and
+// their health scores
+func (g *ServerGroups) countRequests(group *types.ServerGroup) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i := range group.Members {
+		member := &group.Members[i]
+		member.ActiveRequests = g.inFlight[member.ServerID]
+		member.HealthScore = 1
+		if g.scorer != nil {
+			member.HealthScore = g.scorer.ServerScore(member.ServerID)
+		}
+	}
+}
+
+// reset forgets a group's balancing position after its replicas or
+// strategy change
+func (g *ServerGroups) reset(groupID string) {
+	g.mu.Lock()
+	delete(g.next, groupID)
+	delete(g.current, groupID)
+	g.mu.Unlock()
+}
+
+func memberWeight(weight int) int {
+	if weight < 1 {
+		return 1
+	}
+	return weight
+}
@@ -3,13 +3,17 @@ package discovery
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/commandpolicy"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/secrets"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
@@ -26,10 +30,35 @@ type Service struct {
 	registry      *Registry
 	health        *HealthChecker
 	toolDiscovery *services.ToolDiscoveryService
+	notifier      Notifier
+	incidents     IncidentLookup
+	ownerAlerts   OwnerAlerter
+	retries       RetryStatsSource
+	groups        *ServerGroups
 	stopCh        map[uuid.UUID]chan struct{}
 	mu            sync.RWMutex
 }
 
+// Notifier delivers gateway events to organization admins
+type Notifier interface {
+	Notify(ctx context.Context, event *types.NotificationEvent) (int, error)
+}
+
+// IncidentLookup finds the ongoing incident a failing server belongs to
+type IncidentLookup interface {
+	OngoingForServer(ctx context.Context, orgID, serverID string) (*types.Incident, error)
+}
+
+// OwnerAlerter tells a server's owner that the server changed state
+type OwnerAlerter interface {
+	ServerTransition(ctx context.Context, transition *types.ServerTransition) error
+}
+
+// RetryStatsSource counts how failed calls to a server were retried
+type RetryStatsSource interface {
+	Stats(serverID string) *types.RetryStats
+}
+
 // Models contains all database models used by the discovery service
 type Models struct {
 	MCPServer   *models.MCPServerModel
@@ -39,6 +68,11 @@ type Models struct {
 
 // Config holds discovery service configuration
 type Config struct {
+	// CommandPolicy restricts the executables STDIO servers may register with
+	CommandPolicy *commandpolicy.Policy
+	// SecretScanMode controls how likely credentials in server configuration
+	// are handled: warn (default), block or off
+	SecretScanMode   string
 	HealthInterval   time.Duration
 	FailureThreshold int
 	RecoveryTimeout  time.Duration
@@ -80,15 +114,57 @@ func NewService(db *sql.DB, config *Config, transportManager *transport.Manager)
 	}
 
 	service.registry = NewRegistry(db)
+	service.groups = NewServerGroups(models.NewServerGroupModel(dbWrap))
 	service.health = NewHealthChecker(service.registry, config, service.models.HealthCheck)
 
 	// Create a server repository adapter for the tool discovery service
 	serverRepoAdapter := &serverRepositoryAdapter{mcpServerModel: service.models.MCPServer}
 	service.toolDiscovery = services.NewToolDiscoveryService(service.models.MCPTool, serverRepoAdapter, transportManager)
+	service.toolDiscovery.SetMockTools(models.NewMockToolModel(dbWrap))
 
 	return service
 }
 
+// SetNotifier makes health checks notify admins when a server becomes
+// unhealthy
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// SetIncidents makes unhealthy server notifications reference the ongoing
+// incident affecting the server
+func (s *Service) SetIncidents(incidents IncidentLookup) {
+	s.incidents = incidents
+}
+
+// SetOwnerAlerts makes every state change of a server alert the server's
+// owner
+func (s *Service) SetOwnerAlerts(ownerAlerts OwnerAlerter) {
+	s.ownerAlerts = ownerAlerts
+}
+
+// SetMetricEmitter reports health check results through emit
+func (s *Service) SetMetricEmitter(emit func(metric *types.Metric)) {
+	s.health.SetMetricEmitter(emit)
+}
+
+// SetRetryStats adds the retries of calls to a server to its stats
+func (s *Service) SetRetryStats(retries RetryStatsSource) {
+	s.retries = retries
+}
+
+// SetListingInvalidator makes tool discovery drop the organization's cached
+// tool listings
+func (s *Service) SetListingInvalidator(listings services.ListingInvalidator) {
+	s.toolDiscovery.SetListingInvalidator(listings)
+}
+
+// ServerGroups returns the groups of replicas that calls to their servers
+// are balanced over
+func (s *Service) ServerGroups() *ServerGroups {
+	return s.groups
+}
+
 // NewServiceWithoutTransport creates a new discovery service without transport manager (for backwards compatibility)
 func NewServiceWithoutTransport(db *sql.DB, config *Config) *Service {
 	return NewService(db, config, nil)
@@ -111,6 +187,17 @@ func (s *Service) RegisterServer(orgID string, req *types.CreateMCPServerRequest
 		return nil, fmt.Errorf("server with name '%s' already exists in organization", req.Name)
 	}
 
+	if req.Protocol == types.ProtocolStdio {
+		if err := s.enforceCommandPolicy(orgUUID.String(), "", req.Name, req.Command, req.Args); err != nil {
+			return nil, err
+		}
+	}
+
+	findings, err := s.scanForSecrets(req.Name, req.Command, req.Args, req.Environment, req.URL)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert request to model
 	server := &models.MCPServer{
 		ID:             uuid.New(),
@@ -154,7 +241,48 @@ func (s *Service) RegisterServer(orgID string, req *types.CreateMCPServerRequest
 	go s.discoverServerTools(context.Background(), server.ID, orgUUID)
 
 	// Convert back to types.MCPServer
-	return convertModelToTypesMCPServer(server), nil
+	result := convertModelToTypesMCPServer(server)
+	result.SecretFindings = findings
+	return result, nil
+}
+
+// scanForSecrets checks submitted server configuration for likely live
+// credentials. In block mode a finding rejects the request; in warn mode the
+// findings are logged (without values) and returned to the caller.
+func (s *Service) scanForSecrets(name, command string, args, env []string, url string) ([]types.SecretFinding, error) {
+	if s.config.SecretScanMode == types.SecretScanModeOff {
+		return nil, nil
+	}
+
+	findings := secrets.ScanServer(command, args, env, url)
+	if len(findings) == 0 {
+		return nil, nil
+	}
+
+	locations := make([]string, len(findings))
+	for i, finding := range findings {
+		locations[i] = fmt.Sprintf("%s %s (%s)", finding.Field, finding.Location, finding.Description)
+	}
+
+	if s.config.SecretScanMode == types.SecretScanModeBlock {
+		return nil, types.NewErrorWithDetails(types.ErrCodePolicyViolation,
+			"Server configuration contains what look like live credentials",
+			strings.Join(locations, "; "), http.StatusBadRequest)
+	}
+
+	log.Printf("Warning: server %s configured with likely credentials: %s", name, strings.Join(locations, "; "))
+	return findings, nil
+}
+
+// enforceCommandPolicy rejects STDIO commands outside the deployment allowlist
+func (s *Service) enforceCommandPolicy(orgID, serverID, name, command string, args []string) error {
+	subject := commandpolicy.Subject{
+		OrganizationID: orgID,
+		ServerID:       serverID,
+		ServerName:     name,
+		Stage:          commandpolicy.StageRegistration,
+	}
+	return s.config.CommandPolicy.Enforce(context.Background(), subject, command, args)
 }
 
 // UnregisterServer removes an MCP server
@@ -286,7 +414,7 @@ func (s *Service) UpdateServer(serverID string, req *types.UpdateMCPServerReques
 		server.Description = sql.NullString{String: req.Description, Valid: true}
 	}
 	if req.URL != "" {
-		server.URL = sql.NullString{String: req.URL, Valid: true}
+		server.URL = sql.NullString{String: secrets.RestoreString(req.URL, server.URL.String), Valid: true}
 	}
 	if req.Protocol != "" {
 		server.Protocol = req.Protocol
@@ -310,18 +438,36 @@ func (s *Service) UpdateServer(serverID string, req *types.UpdateMCPServerReques
 		server.IsActive = *req.IsActive
 	}
 	if req.Command != "" {
-		server.Command = sql.NullString{String: req.Command, Valid: true}
+		server.Command = sql.NullString{String: secrets.RestoreString(req.Command, server.Command.String), Valid: true}
 	}
 	if req.Args != nil {
-		server.Args = pq.StringArray(req.Args)
+		server.Args = pq.StringArray(secrets.RestoreArgs(req.Args, server.Args))
 	}
 	if req.Environment != nil {
-		server.Environment = pq.StringArray(req.Environment)
+		server.Environment = pq.StringArray(secrets.RestoreEnvironment(req.Environment, server.Environment))
 	}
 	if req.WorkingDir != "" {
 		server.WorkingDir = sql.NullString{String: req.WorkingDir, Valid: true}
 	}
 
+	if server.Protocol == types.ProtocolStdio && (req.Protocol != "" || req.Command != "" || req.Args != nil) {
+		if err := s.enforceCommandPolicy(server.OrganizationID.String(), server.ID.String(),
+			server.Name, server.Command.String, server.Args); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only scan what this request changes; values restored from masked
+	// input were already reported when they were first submitted
+	var findings []types.SecretFinding
+	if req.Command != "" || req.Args != nil || req.Environment != nil || req.URL != "" {
+		findings, err = s.scanForSecrets(server.Name,
+			req.Command, req.Args, req.Environment, req.URL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Update server in database
 	err = s.models.MCPServer.Update(server)
 	if err != nil {
@@ -336,7 +482,9 @@ func (s *Service) UpdateServer(serverID string, req *types.UpdateMCPServerReques
 	}
 
 	// Convert back to types.MCPServer
-	return convertModelToTypesMCPServer(server), nil
+	result := convertModelToTypesMCPServer(server)
+	result.SecretFindings = findings
+	return result, nil
 }
 
 // GetServerStats returns server statistics
@@ -348,7 +496,7 @@ func (s *Service) GetServerStats(serverID string) (*types.ServerStats, error) {
 
 	// First, try to get stats from registry cache (real-time stats)
 	if stats, exists := s.registry.GetServerStats(serverID); exists {
-		return stats, nil
+		return s.withRetries(serverID, stats), nil
 	}
 
 	// If not in cache, try to get from database (historical stats)
@@ -366,7 +514,18 @@ func (s *Service) GetServerStats(serverID string) (*types.ServerStats, error) {
 	// Initialize stats in registry cache for future use
 	s.registry.UpdateServerStats(serverID, defaultStats)
 
-	return defaultStats, nil
+	return s.withRetries(serverID, defaultStats), nil
+}
+
+// withRetries returns a copy of stats with the server's retry counts, leaving
+// the cached stats untouched
+func (s *Service) withRetries(serverID string, stats *types.ServerStats) *types.ServerStats {
+	if s.retries == nil {
+		return stats
+	}
+	merged := *stats
+	merged.Retries = s.retries.Stats(serverID)
+	return &merged
 }
 
 // Start starts the discovery service
@@ -544,8 +703,8 @@ func (s *Service) performHealthCheck(serverID uuid.UUID) {
 		return
 	}
 
-	// Skip if server is not active
-	if !server.IsActive {
+	// Skip if server is not active, or if its platform reports its health
+	if !server.IsActive || server.Metadata[MetadataDiscoverySource] == DiscoverySourceKubernetes {
 		return
 	}
 
@@ -556,25 +715,133 @@ func (s *Service) performHealthCheck(serverID uuid.UUID) {
 	}
 
 	// Perform the actual health check based on protocol
-	status := s.checkServerHealth(server)
-	check.Status = status
+	check.Status = s.checkServerHealth(server)
+	s.recordHealthCheck(server, check)
+}
+
+// SetServerReadiness records the health of a server reported by the
+// platform running it, such as Kubernetes pod readiness, instead of probed
+func (s *Service) SetServerReadiness(serverID string, ready bool, message string) error {
+	serverUUID, err := uuid.Parse(serverID)
+	if err != nil {
+		return fmt.Errorf("invalid server ID: %w", err)
+	}
+	server, err := s.models.MCPServer.GetByID(serverUUID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("server not found")
+		}
+		return fmt.Errorf("failed to get server: %w", err)
+	}
 
+	check := &models.HealthCheck{
+		ServerID:  serverUUID,
+		CheckedAt: time.Now(),
+		Status:    types.HealthStatusHealthy,
+	}
+	if !ready {
+		check.Status = types.HealthStatusUnhealthy
+		check.ErrorMessage = sql.NullString{String: message, Valid: message != ""}
+	}
+	s.recordHealthCheck(server, check)
+	return nil
+}
+
+// recordHealthCheck saves a health check and moves the server to the
+// matching status, notifying admins and the owner of the transition
+func (s *Service) recordHealthCheck(server *models.MCPServer, check *models.HealthCheck) {
 	// Update server status if needed (convert health status to server status)
-	serverStatus := s.mapHealthStatusToServerStatus(status)
+	serverStatus := s.mapHealthStatusToServerStatus(check.Status)
 	if serverStatus != server.Status {
-		err = s.models.MCPServer.UpdateStatus(serverID, serverStatus)
+		err := s.models.MCPServer.UpdateStatus(server.ID, serverStatus)
 		if err != nil {
-			log.Printf("Failed to update server %s status: %v", serverID, err)
+			log.Printf("Failed to update server %s status: %v", server.ID, err)
 		}
+		if serverStatus == "unhealthy" {
+			s.notifyUnhealthy(server, check.Status)
+		}
+		s.notifyOwner(server, serverStatus, check.Status)
 	}
 
 	// Save health check record
-	err = s.models.HealthCheck.Create(check)
+	err := s.models.HealthCheck.Create(check)
+	if err != nil {
+		log.Printf("Failed to save health check for server %s: %v", server.ID, err)
+	}
+}
+
+// notifyUnhealthy tells the server organization's admins that a health check
+// failed. Repeats are suppressed until the previous notification is read.
+func (s *Service) notifyUnhealthy(server *models.MCPServer, healthStatus string) {
+	if s.notifier == nil {
+		return
+	}
+
+	event := &types.NotificationEvent{
+		OrganizationID: server.OrganizationID.String(),
+		Type:           types.NotificationServerUnhealthy,
+		Severity:       types.NotificationSeverityCritical,
+		Title:          fmt.Sprintf("Server %s is unhealthy", server.Name),
+		Message:        fmt.Sprintf("Health check for MCP server %s returned %s.", server.Name, healthStatus),
+		ResourceType:   "server",
+		ResourceID:     server.ID.String(),
+		DedupKey:       types.NotificationServerUnhealthy + ":" + server.ID.String(),
+		Data:           map[string]interface{}{"health_status": healthStatus},
+	}
+	s.attachIncident(event, server)
+
+	_, err := s.notifier.Notify(context.Background(), event)
 	if err != nil {
-		log.Printf("Failed to save health check for server %s: %v", serverID, err)
+		log.Printf("Failed to notify admins about server %s: %v", server.ID, err)
 	}
 }
 
+// notifyOwner alerts the server's owner, if it has one, of a state change
+func (s *Service) notifyOwner(server *models.MCPServer, serverStatus, healthStatus string) {
+	if s.ownerAlerts == nil {
+		return
+	}
+
+	err := s.ownerAlerts.ServerTransition(context.Background(), &types.ServerTransition{
+		ServerID:       server.ID.String(),
+		OrganizationID: server.OrganizationID.String(),
+		ServerName:     server.Name,
+		PreviousStatus: server.Status,
+		Status:         serverStatus,
+		HealthStatus:   healthStatus,
+	})
+	if err != nil {
+		log.Printf("Failed to alert owner of server %s: %v", server.ID, err)
+	}
+}
+
+// attachIncident references the ongoing incident affecting the server, if
+// any, so admins can tell known problems from new ones
+func (s *Service) attachIncident(event *types.NotificationEvent, server *models.MCPServer) {
+	if s.incidents == nil {
+		return
+	}
+
+	incident, err := s.incidents.OngoingForServer(context.Background(), server.OrganizationID.String(), server.ID.String())
+	if err != nil {
+		log.Printf("Failed to look up incidents for server %s: %v", server.ID, err)
+		return
+	}
+	if incident == nil {
+		return
+	}
+
+	event.Message += fmt.Sprintf(" Part of ongoing incident %q.", incident.Title)
+	event.Data["incident_id"] = incident.ID
+	event.Data["incident_title"] = incident.Title
+	event.Data["incident_severity"] = incident.Severity
+}
+
+// GetHealthHistory returns a server's most recent health checks, newest first
+func (s *Service) GetHealthHistory(serverID string, limit int) ([]*types.HealthCheck, error) {
+	return s.health.GetHealthHistory(serverID, limit)
+}
+
 // checkServerHealth performs the actual health check logic
 func (s *Service) checkServerHealth(server *models.MCPServer) string {
 	// Implement health checking logic based on protocol
@@ -591,6 +858,14 @@ func (s *Service) checkServerHealth(server *models.MCPServer) string {
 		// For STDIO servers, check if command exists and is executable
 		return s.checkSTDIOHealth(server)
 
+	case "grpc":
+		// For gRPC servers, query the gRPC health service
+		return s.checkGRPCHealth(server)
+
+	case "mock":
+		// Mock servers are answered by the gateway itself
+		return types.HealthStatusHealthy
+
 	case "tcp":
 		// For TCP servers, attempt socket connection
 		return s.checkTCPHealth(server)
@@ -662,6 +937,34 @@ func (s *Service) checkSTDIOHealth(server *models.MCPServer) string {
 	return types.HealthStatusHealthy
 }
 
+// checkGRPCHealth performs a grpc.health.v1 check against the server
+func (s *Service) checkGRPCHealth(server *models.MCPServer) string {
+	if !server.URL.Valid || server.URL.String == "" {
+		return types.HealthStatusError
+	}
+
+	target := server.URL.String
+	if server.HealthCheckURL.Valid && server.HealthCheckURL.String != "" {
+		target = server.HealthCheckURL.String
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := transport.CheckGRPCHealth(ctx, target)
+	switch {
+	case err == nil:
+		return types.HealthStatusHealthy
+	case errors.Is(err, transport.ErrGRPCNotServing):
+		return types.HealthStatusUnhealthy
+	case ctx.Err() != nil:
+		return types.HealthStatusTimeout
+	default:
+		log.Printf("gRPC health check failed for server %s: %v", server.ID, err)
+		return types.HealthStatusError
+	}
+}
+
 // checkTCPHealth performs TCP-based health check
 func (s *Service) checkTCPHealth(server *models.MCPServer) string {
 	// For TCP servers, we could attempt a socket connection
@@ -752,3 +1055,22 @@ func (s *Service) DiscoverServerTools(serverID string) error {
 	log.Printf("Manual tool discovery completed successfully for server %s", serverID)
 	return nil
 }
+
+// RefreshServerTools replaces the discovered tools of a server with those it
+// offers now, dropping tools it no longer has
+func (s *Service) RefreshServerTools(ctx context.Context, serverID string) error {
+	serverUUID, err := uuid.Parse(serverID)
+	if err != nil {
+		return fmt.Errorf("invalid server ID: %w", err)
+	}
+
+	server, err := s.models.MCPServer.GetByID(serverUUID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("server not found")
+		}
+		return fmt.Errorf("failed to get server: %w", err)
+	}
+
+	return s.toolDiscovery.RefreshServerTools(ctx, serverUUID, server.OrganizationID)
+}
@@ -0,0 +1,142 @@
+package dlp
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/secrets"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// pattern is a built-in classifier. Matches valid rejects are dropped, such
+// as card numbers that fail the Luhn check.
+type pattern struct {
+	name        string
+	sensitivity string
+	re          *regexp.Regexp
+	valid       func(match string) bool
+}
+
+var patterns = []pattern{
+	{
+		name:        "email_address",
+		sensitivity: types.DLPSensitivityConfidential,
+		re:          regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+	},
+	{
+		name:        "phone_number",
+		sensitivity: types.DLPSensitivityConfidential,
+		re:          regexp.MustCompile(`\+[1-9]\d{7,14}\b`),
+	},
+	{
+		name:        "credit_card_number",
+		sensitivity: types.DLPSensitivityRestricted,
+		re:          regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		valid:       luhn,
+	},
+	{
+		name:        "us_ssn",
+		sensitivity: types.DLPSensitivityRestricted,
+		re:          regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		valid:       validSSN,
+	},
+	{
+		name:        "iban",
+		sensitivity: types.DLPSensitivityRestricted,
+		re:          regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`),
+		valid:       validIBAN,
+	},
+	{
+		name:        "ip_address",
+		sensitivity: types.DLPSensitivityInternal,
+		re:          regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`),
+		valid:       func(match string) bool { return net.ParseIP(match) != nil },
+	},
+}
+
+// builtin holds the gateway's own classifiers
+type builtin struct{}
+
+// Builtin returns the gateway's own classifiers: email addresses and
+// phone numbers are confidential; card numbers, US social security
+// numbers, IBANs and credentials are restricted; IP addresses are
+// internal.
+func Builtin() Classifier {
+	return builtin{}
+}
+
+func (builtin) Classify(ctx context.Context, texts []string) ([][]Match, error) {
+	matches := make([][]Match, len(texts))
+	for i, text := range texts {
+		for _, p := range patterns {
+			for _, loc := range p.re.FindAllStringIndex(text, -1) {
+				if p.valid != nil && !p.valid(text[loc[0]:loc[1]]) {
+					continue
+				}
+				matches[i] = append(matches[i], Match{Classifier: p.name, Sensitivity: p.sensitivity, Start: loc[0], End: loc[1]})
+			}
+		}
+		for _, span := range secrets.Spans(text) {
+			matches[i] = append(matches[i], Match{
+				Classifier:  "credential",
+				Sensitivity: types.DLPSensitivityRestricted,
+				Start:       span[0],
+				End:         span[1],
+			})
+		}
+	}
+	return matches, nil
+}
+
+// luhn reports whether the digits of a card number pass the Luhn check
+func luhn(number string) bool {
+	sum, double, digits := 0, false, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+// validSSN rejects numbers never issued as social security numbers
+func validSSN(ssn string) bool {
+	area, group, serial := ssn[0:3], ssn[4:6], ssn[7:11]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// validIBAN reports whether an IBAN passes its mod-97 check
+func validIBAN(iban string) bool {
+	iban = strings.ReplaceAll(iban, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	var digits strings.Builder
+	for _, c := range iban[4:] + iban[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			digits.WriteRune(c)
+		case c >= 'A' && c <= 'Z':
+			digits.WriteString(strconv.Itoa(int(c-'A') + 10))
+		default:
+			return false
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
@@ -0,0 +1,195 @@
+// Package dlp finds sensitive data in tool arguments leaving the gateway,
+// with built-in classifiers and optionally an external DLP API, and
+// redacts it.
+package dlp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Match is sensitive data found in a text, at bytes Start to End
+type Match struct {
+	Classifier  string
+	Sensitivity string
+	Start       int
+	End         int
+}
+
+// Classifier finds sensitive data in texts, returning the matches of each
+// text at the same index
+type Classifier interface {
+	Classify(ctx context.Context, texts []string) ([][]Match, error)
+}
+
+// Finding is a match in the tool argument at Path
+type Finding struct {
+	Path string
+	Match
+}
+
+// Scanner runs classifiers over the string values of tool arguments
+type Scanner struct {
+	classifiers []Classifier
+}
+
+// New creates a scanner over classifiers
+func New(classifiers ...Classifier) *Scanner {
+	return &Scanner{classifiers: classifiers}
+}
+
+// Scan returns what the classifiers find in the string values of args, in
+// path order. A classifier that fails fails the scan.
+func (s *Scanner) Scan(ctx context.Context, args map[string]interface{}) ([]Finding, error) {
+	var paths, texts []string
+	walk("", args, func(path, text string) {
+		paths = append(paths, path)
+		texts = append(texts, text)
+	})
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	var findings []Finding
+	for _, classifier := range s.classifiers {
+		matches, err := classifier.Classify(ctx, texts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range texts {
+			if i >= len(matches) {
+				break
+			}
+			for _, match := range matches[i] {
+				if match.Start < 0 || match.End > len(texts[i]) || match.Start >= match.End ||
+					types.DLPSensitivityRank(match.Sensitivity) < 0 {
+					continue
+				}
+				findings = append(findings, Finding{Path: paths[i], Match: match})
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Start < findings[j].Start
+	})
+	return findings, nil
+}
+
+// Above returns the findings more sensitive than sensitivity
+func Above(findings []Finding, sensitivity string) []Finding {
+	rank := types.DLPSensitivityRank(sensitivity)
+	var above []Finding
+	for _, finding := range findings {
+		if types.DLPSensitivityRank(finding.Sensitivity) > rank {
+			above = append(above, finding)
+		}
+	}
+	return above
+}
+
+// Describe returns findings without the positions of the data, one per
+// path and classifier
+func Describe(findings []Finding) []types.DLPFinding {
+	described := make([]types.DLPFinding, 0, len(findings))
+	seen := make(map[[2]string]bool, len(findings))
+	for _, finding := range findings {
+		key := [2]string{finding.Path, finding.Classifier}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		described = append(described, types.DLPFinding{
+			Path:        finding.Path,
+			Classifier:  finding.Classifier,
+			Sensitivity: finding.Sensitivity,
+		})
+	}
+	return described
+}
+
+// Redact returns a copy of args with the data of findings replaced by
+// [REDACTED]; args itself is never modified
+func Redact(args map[string]interface{}, findings []Finding) map[string]interface{} {
+	spans := make(map[string][][2]int)
+	for _, finding := range findings {
+		spans[finding.Path] = append(spans[finding.Path], [2]int{finding.Start, finding.End})
+	}
+	redacted, _ := redactValue("", args, spans).(map[string]interface{})
+	return redacted
+}
+
+func redactValue(path string, value interface{}, spans map[string][][2]int) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = redactValue(joinKey(path, key), item, spans)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = redactValue(fmt.Sprintf("%s[%d]", path, i), item, spans)
+		}
+		return copied
+	case string:
+		if ranges, ok := spans[path]; ok {
+			return redactString(v, ranges)
+		}
+	}
+	return value
+}
+
+// redactString replaces the merged ranges of text
+func redactString(text string, ranges [][2]int) string {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	var b strings.Builder
+	last := 0
+	for _, r := range ranges {
+		if r[1] <= last {
+			continue
+		}
+		if r[0] >= last {
+			b.WriteString(text[last:r[0]])
+			b.WriteString(types.RedactedValue)
+		}
+		last = r[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// walk calls visit with the path of every string value under value
+func walk(path string, value interface{}, visit func(path, text string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			walk(joinKey(path, key), v[key], visit)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walk(fmt.Sprintf("%s[%d]", path, i), item, visit)
+		}
+	case string:
+		visit(path, v)
+	}
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
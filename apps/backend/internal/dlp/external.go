@@ -0,0 +1,134 @@
+package dlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// DefaultExternalTimeout bounds a call to an external DLP API
+const DefaultExternalTimeout = 5 * time.Second
+
+// EgressPolicy restricts the hosts the gateway may call
+type EgressPolicy interface {
+	CheckURL(feature, rawURL string) error
+}
+
+// External classifies texts with an external DLP API. The API receives
+//
+//	{"texts": ["...", ...]}
+//
+// and answers with the findings in each text by index and byte offsets:
+//
+//	{"findings": [{"index": 0, "classifier": "customer_id", "sensitivity": "confidential", "start": 4, "end": 12}]}
+type External struct {
+	client   *http.Client
+	endpoint string
+	apiKey   string
+}
+
+// NewExternal creates a classifier posting to endpoint, which the egress
+// policy must allow
+func NewExternal(endpoint, apiKey string, timeout time.Duration, egress EgressPolicy) (*External, error) {
+	if egress != nil {
+		if err := egress.CheckURL(types.ConnectivityFeatureExternalDLP, endpoint); err != nil {
+			return nil, err
+		}
+	}
+	if timeout <= 0 {
+		timeout = DefaultExternalTimeout
+	}
+	return &External{
+		client:   &http.Client{Timeout: timeout},
+		endpoint: endpoint,
+		apiKey:   apiKey,
+	}, nil
+}
+
+// Classify posts texts to the DLP API. Findings with an unknown
+// sensitivity or outside their text are dropped by the scanner.
+func (e *External) Classify(ctx context.Context, texts []string) ([][]Match, error) {
+	body, err := json.Marshal(map[string]interface{}{"texts": texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("DLP API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("DLP API returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		Findings []struct {
+			Classifier  string `json:"classifier"`
+			Sensitivity string `json:"sensitivity"`
+			Index       int    `json:"index"`
+			Start       int    `json:"start"`
+			End         int    `json:"end"`
+		} `json:"findings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode DLP API findings: %w", err)
+	}
+
+	matches := make([][]Match, len(texts))
+	for _, finding := range result.Findings {
+		if finding.Index < 0 || finding.Index >= len(texts) {
+			return nil, fmt.Errorf("DLP API returned index %d for %d texts", finding.Index, len(texts))
+		}
+		classifier := finding.Classifier
+		if classifier == "" {
+			classifier = "external"
+		}
+		matches[finding.Index] = append(matches[finding.Index], Match{
+			Classifier:  classifier,
+			Sensitivity: finding.Sensitivity,
+			Start:       finding.Start,
+			End:         finding.End,
+		})
+	}
+	return matches, nil
+}
+
+// failOpen finds nothing when its classifier fails
+type failOpen struct {
+	classifier Classifier
+	report     func(error)
+}
+
+// FailOpen wraps a classifier so that calls are checked by the other
+// classifiers alone while it fails; report receives its errors
+func FailOpen(classifier Classifier, report func(error)) Classifier {
+	return failOpen{classifier: classifier, report: report}
+}
+
+func (f failOpen) Classify(ctx context.Context, texts []string) ([][]Match, error) {
+	matches, err := f.classifier.Classify(ctx, texts)
+	if err != nil {
+		if f.report != nil {
+			f.report(err)
+		}
+		return make([][]Match, len(texts)), nil
+	}
+	return matches, nil
+}
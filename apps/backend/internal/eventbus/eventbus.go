@@ -0,0 +1,61 @@
+// Package eventbus carries events between gateway instances, so that work
+// started on one instance, such as an SSE or WebSocket broadcast, reaches
+// clients connected to the others.
+package eventbus
+
+import (
+	"context"
+	"sync"
+)
+
+// Handler receives the payload of each event published on a topic
+type Handler func(payload []byte)
+
+// Bus publishes events to every subscriber of a topic, on any instance
+// sharing the bus. Delivery is at most once; subscribers only see events
+// published after they subscribed.
+type Bus interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+	Subscribe(topic string, handler Handler) error
+	Close() error
+}
+
+// LocalBus delivers events to subscribers in the same process. It is the
+// bus of a single gateway instance.
+type LocalBus struct {
+	handlers map[string][]Handler
+	mu       sync.RWMutex
+}
+
+// NewLocalBus creates an in-process bus
+func NewLocalBus() *LocalBus {
+	return &LocalBus{handlers: make(map[string][]Handler)}
+}
+
+// Publish hands payload to the topic's subscribers before returning
+func (b *LocalBus) Publish(ctx context.Context, topic string, payload []byte) error {
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers[topic]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(payload)
+	}
+	return nil
+}
+
+// Subscribe registers handler for events on topic
+func (b *LocalBus) Subscribe(topic string, handler Handler) error {
+	b.mu.Lock()
+	b.handlers[topic] = append(b.handlers[topic], handler)
+	b.mu.Unlock()
+	return nil
+}
+
+// Close drops all subscribers
+func (b *LocalBus) Close() error {
+	b.mu.Lock()
+	b.handlers = make(map[string][]Handler)
+	b.mu.Unlock()
+	return nil
+}
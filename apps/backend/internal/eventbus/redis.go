@@ -0,0 +1,141 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisStreamPrefix namespaces the stream of each topic
+	redisStreamPrefix = "eventbus:"
+
+	// redisReadBlock bounds each blocking read, so subscribers notice Close
+	redisReadBlock = 2 * time.Second
+
+	// redisRetryDelay spaces out reads while Redis is unreachable
+	redisRetryDelay = time.Second
+)
+
+// RedisBus shares events between gateway instances through Redis Streams.
+// Each topic is a stream capped at maxLen entries that every instance
+// reads independently, so all subscribers on all instances see each event.
+type RedisBus struct {
+	client *redis.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	maxLen int64
+}
+
+// NewRedisBus connects to Redis. maxLen caps each topic's stream; events
+// beyond it are trimmed, and a subscriber that falls further behind misses
+// them.
+func NewRedisBus(addr, password string, db int, maxLen int64) (*RedisBus, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	busCtx, busCancel := context.WithCancel(context.Background())
+	return &RedisBus{
+		client: client,
+		ctx:    busCtx,
+		cancel: busCancel,
+		maxLen: maxLen,
+	}, nil
+}
+
+// Publish appends payload to the topic's stream
+func (b *RedisBus) Publish(ctx context.Context, topic string, payload []byte) error {
+	err := b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: redisStreamPrefix + topic,
+		MaxLen: b.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"payload": payload},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// Subscribe reads the topic's stream from its current end until the bus is
+// closed, calling handler for each event
+func (b *RedisBus) Subscribe(topic string, handler Handler) error {
+	stream := redisStreamPrefix + topic
+
+	// Start after the newest entry so only events published from now on
+	// are delivered
+	lastID := "0-0"
+	latest, err := b.client.XRevRangeN(b.ctx, stream, "+", "-", 1).Result()
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+	}
+	if len(latest) > 0 {
+		lastID = latest[0].ID
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.read(stream, lastID, handler)
+	}()
+	return nil
+}
+
+// read delivers entries of stream after lastID until the bus is closed
+func (b *RedisBus) read(stream, lastID string, handler Handler) {
+	for b.ctx.Err() == nil {
+		streams, err := b.client.XRead(b.ctx, &redis.XReadArgs{
+			Streams: []string{stream, lastID},
+			Block:   redisReadBlock,
+			Count:   100,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return
+			}
+			log.Printf("Event bus: failed to read %s: %v", stream, err)
+			select {
+			case <-time.After(redisRetryDelay):
+			case <-b.ctx.Done():
+				return
+			}
+			continue
+		}
+
+		for _, s := range streams {
+			for _, message := range s.Messages {
+				lastID = message.ID
+				if payload, ok := message.Values["payload"].(string); ok {
+					handler([]byte(payload))
+				}
+			}
+		}
+	}
+}
+
+// Close stops all subscriptions and disconnects from Redis
+func (b *RedisBus) Close() error {
+	b.cancel()
+	b.wg.Wait()
+	return b.client.Close()
+}
@@ -0,0 +1,107 @@
+// Package geoip locates client IP addresses by country and autonomous
+// system with MaxMind DB (MMDB) files, such as GeoLite2 Country and ASN.
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Resolver looks IP addresses up in one or more databases. Each field of
+// a location comes from the first database that has it, so a country
+// database and an ASN database can be combined.
+type Resolver struct {
+	dbs []*DB
+}
+
+// NewResolver creates a resolver over dbs
+func NewResolver(dbs ...*DB) *Resolver {
+	return &Resolver{dbs: dbs}
+}
+
+// OpenResolver opens the MMDB files at paths, skipping empty paths. It
+// returns nil when no path is set.
+func OpenResolver(paths ...string) (*Resolver, error) {
+	var dbs []*DB
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		db, err := Open(path)
+		if err != nil {
+			return nil, err
+		}
+		dbs = append(dbs, db)
+	}
+	if len(dbs) == 0 {
+		return nil, nil
+	}
+	return NewResolver(dbs...), nil
+}
+
+// Lookup returns the country and autonomous system of an IP address. The
+// fields the databases do not know are left empty, as they are for
+// private addresses.
+func (r *Resolver) Lookup(address string) (*types.GeoIPInfo, error) {
+	ip := net.ParseIP(strings.TrimSpace(address))
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", address)
+	}
+	info := &types.GeoIPInfo{IP: ip.String()}
+	for _, db := range r.dbs {
+		record, err := db.Lookup(ip)
+		if err != nil {
+			return nil, fmt.Errorf("GeoIP lookup of %s failed: %w", info.IP, err)
+		}
+		if record == nil {
+			continue
+		}
+		if info.Country == "" {
+			info.Country = country(record)
+		}
+		if info.ASN == 0 {
+			info.ASN, info.ASOrganization = autonomousSystem(record)
+		}
+	}
+	return info, nil
+}
+
+// country reads the ISO code of MaxMind country and city records, falling
+// back to the registered country for anonymous and satellite networks.
+// Databases storing the code as a plain string are read too.
+func country(record map[string]interface{}) string {
+	for _, key := range []string{"country", "registered_country"} {
+		switch v := record[key].(type) {
+		case map[string]interface{}:
+			if code, _ := v["iso_code"].(string); code != "" {
+				return strings.ToUpper(code)
+			}
+		case string:
+			if v != "" {
+				return strings.ToUpper(v)
+			}
+		}
+	}
+	return ""
+}
+
+// autonomousSystem reads the number and organization of MaxMind ASN
+// records, or of records carrying an "AS"-prefixed asn string
+func autonomousSystem(record map[string]interface{}) (int64, string) {
+	if number, ok := record["autonomous_system_number"].(uint64); ok && number <= 1<<32-1 {
+		organization, _ := record["autonomous_system_organization"].(string)
+		return int64(number), organization
+	}
+	if asn, ok := record["asn"].(string); ok {
+		number, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(asn), "AS"), 10, 32)
+		if err == nil {
+			organization, _ := record["as_name"].(string)
+			return int64(number), organization
+		}
+	}
+	return 0, ""
+}
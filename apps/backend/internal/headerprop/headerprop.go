@@ -0,0 +1,248 @@
+// Package headerprop decides which headers of a caller's request reach an
+// upstream server. Rules propagate inbound headers by name, strip others
+// and derive new headers from templates over the caller's identity.
+package headerprop
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// maxRules bounds the entries of each list of a rule set
+const maxRules = 50
+
+// protectedHeaders are never propagated or set: they carry the caller's
+// credentials or are managed by the HTTP client
+var protectedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+	"Host":                true,
+	"Content-Length":      true,
+	"Content-Type":        true,
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Transfer-Encoding":   true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Upgrade":             true,
+}
+
+var (
+	headerNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+	placeholder       = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+)
+
+// claimNames are the identity claims templates may reference
+var claimNames = map[string]bool{
+	"sub":             true,
+	"email":           true,
+	"organization_id": true,
+	"type":            true,
+	"api_key_id":      true,
+	"client_id":       true,
+}
+
+// Request is what templates are resolved against
+type Request struct {
+	Header      http.Header
+	Principal   *types.Principal
+	NamespaceID string
+	ServerID    string
+	ServerName  string
+}
+
+// Validate checks that rules only name valid headers and known template
+// variables
+func Validate(rules *types.HeaderPropagationRules) error {
+	if len(rules.Propagate) > maxRules || len(rules.Strip) > maxRules || len(rules.Set) > maxRules {
+		return fmt.Errorf("at most %d headers may be listed in each of propagate, strip and set", maxRules)
+	}
+	for _, list := range [][]string{rules.Propagate, rules.Strip} {
+		for _, pattern := range list {
+			name := strings.TrimSuffix(pattern, "*")
+			if name == "" && pattern != "*" {
+				return fmt.Errorf("header pattern %q is empty", pattern)
+			}
+			if name != "" && !headerNamePattern.MatchString(name) {
+				return fmt.Errorf("header pattern %q is not a header name", pattern)
+			}
+		}
+	}
+	for name, template := range rules.Set {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("header %q is not a header name", name)
+		}
+		if protectedHeaders[http.CanonicalHeaderKey(name)] {
+			return fmt.Errorf("header %s cannot be set", http.CanonicalHeaderKey(name))
+		}
+		for _, match := range placeholder.FindAllStringSubmatch(template, -1) {
+			if err := validateVariable(match[1]); err != nil {
+				return fmt.Errorf("header %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Merge combines namespace rules with the rules of a server, which take
+// precedence: the propagated and stripped headers add up and server
+// templates replace namespace templates of the same header
+func Merge(namespace, server *types.HeaderPropagationRules) *types.HeaderPropagationRules {
+	merged := &types.HeaderPropagationRules{Set: map[string]string{}}
+	for _, rules := range []*types.HeaderPropagationRules{namespace, server} {
+		if rules == nil {
+			continue
+		}
+		merged.Propagate = append(merged.Propagate, rules.Propagate...)
+		merged.Strip = append(merged.Strip, rules.Strip...)
+		for name, template := range rules.Set {
+			merged.Set[http.CanonicalHeaderKey(name)] = template
+		}
+	}
+	return merged
+}
+
+// Resolve returns the headers rules send upstream for req. Inbound headers
+// are copied when Propagate matches them and Strip does not; Set headers
+// are added after, replacing copied values.
+func Resolve(rules *types.HeaderPropagationRules, req *Request) http.Header {
+	out := http.Header{}
+	if rules == nil {
+		return out
+	}
+
+	for name, values := range req.Header {
+		name = http.CanonicalHeaderKey(name)
+		if protectedHeaders[name] || !matchAny(rules.Propagate, name) || matchAny(rules.Strip, name) {
+			continue
+		}
+		out[name] = append([]string(nil), values...)
+	}
+
+	// Apply templates in a fixed order so that results do not depend on
+	// map iteration
+	names := make([]string, 0, len(rules.Set))
+	for name := range rules.Set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		canonical := http.CanonicalHeaderKey(name)
+		if protectedHeaders[canonical] {
+			continue
+		}
+		if value, ok := render(rules.Set[name], req); ok {
+			out.Set(canonical, value)
+		} else {
+			out.Del(canonical)
+		}
+	}
+	return out
+}
+
+// render fills in a template's variables. It reports false when any
+// variable is empty, so that a header is not sent without its value.
+func render(template string, req *Request) (string, bool) {
+	complete := true
+	value := placeholder.ReplaceAllStringFunc(template, func(match string) string {
+		resolved := lookup(strings.TrimSpace(match[2:len(match)-2]), req)
+		if resolved == "" {
+			complete = false
+		}
+		return resolved
+	})
+	value = strings.TrimSpace(value)
+	// Values cannot carry line breaks into the upstream request
+	if strings.ContainsAny(value, "\r\n") {
+		return "", false
+	}
+	return value, complete && value != ""
+}
+
+func lookup(variable string, req *Request) string {
+	scope, key, _ := strings.Cut(variable, ".")
+	principal := req.Principal
+	if principal == nil {
+		principal = &types.Principal{Type: types.PrincipalTypeAnonymous}
+	}
+
+	switch scope {
+	case "header":
+		return req.Header.Get(key)
+	case "labels":
+		return principal.Labels[key]
+	case "namespace":
+		return req.NamespaceID
+	case "server":
+		if key == "name" {
+			return req.ServerName
+		}
+		return req.ServerID
+	case "claims":
+		switch key {
+		case "sub":
+			return principal.EffectiveUserID()
+		case "email":
+			return principal.Email
+		case "organization_id":
+			return principal.OrganizationID
+		case "type":
+			return principal.Type
+		case "api_key_id":
+			return principal.APIKeyID
+		case "client_id":
+			return principal.ClientID
+		}
+	}
+	return ""
+}
+
+func validateVariable(variable string) error {
+	scope, key, _ := strings.Cut(variable, ".")
+	switch scope {
+	case "header":
+		if headerNamePattern.MatchString(key) {
+			return nil
+		}
+	case "labels":
+		if key != "" {
+			return nil
+		}
+	case "namespace":
+		if key == "id" {
+			return nil
+		}
+	case "server":
+		if key == "id" || key == "name" {
+			return nil
+		}
+	case "claims":
+		if claimNames[key] {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown template variable %q", variable)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DatabaseCheck pings a database
+func DatabaseCheck(db *sql.DB) Check {
+	return db.PingContext
+}
+
+// RedisCheck pings a Redis server. The client connects lazily, so a Redis
+// server that is down at startup is reported rather than fatal.
+func RedisCheck(addr, password string, db int) Check {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+		PoolSize: 1,
+	})
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
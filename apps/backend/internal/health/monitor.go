@@ -0,0 +1,368 @@
+// Package health scores the gateway instance's own health from its
+// dependencies and from the outcome of its calls to upstream servers
+package health
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Monitor defaults
+const (
+	DefaultInterval       = 10 * time.Second
+	DefaultTimeout        = 2 * time.Second
+	DefaultSlowAfter      = 250 * time.Millisecond
+	DefaultImpactHalfLife = 30 * time.Second
+
+	// minDependencyScore is the score of a dependency that answers, however
+	// slowly
+	minDependencyScore = 0.1
+	// optionalWeight is how much of the service score an optional
+	// dependency can take away when it is down
+	optionalWeight = 0.5
+	// callSmoothing is the weight of each call in a server's smoothed error
+	// rate and latency
+	callSmoothing = 0.1
+	// baselineSmoothing is the weight of each call in a server's baseline
+	// latency, which follows lasting changes slowly
+	baselineSmoothing = 0.01
+	// latencyFactor is how far a server's latency may rise above its
+	// baseline before its score falls
+	latencyFactor = 2.0
+)
+
+// Check probes a dependency and returns an error when it is unreachable
+type Check func(ctx context.Context) error
+
+// Dependency is something the gateway needs to serve requests
+type Dependency struct {
+	Check Check
+	Name  string
+	// SlowAfter is the probe latency above which the dependency counts as
+	// slow and its score falls
+	SlowAfter time.Duration
+	// Critical dependencies make the instance unavailable when down
+	Critical bool
+}
+
+// Config configures a monitor
+type Config struct {
+	// Interval is how often dependencies are probed
+	Interval time.Duration
+	// Timeout bounds each probe
+	Timeout time.Duration
+	// ImpactHalfLife is how fast a server's bad calls are forgotten when
+	// no new calls are seen, so a server that is avoided recovers
+	ImpactHalfLife time.Duration
+}
+
+// MetricEmitter receives the monitor's score gauges
+type MetricEmitter func(metric *types.Metric)
+
+// Monitor probes the gateway's dependencies in the background and keeps
+// a service health score for readiness checks. It also scores upstream
+// servers from the calls made to them so that the namespace router can
+// prefer the replicas least impacted by a degradation.
+type Monitor struct {
+	emit    MetricEmitter
+	now     func() time.Time
+	stopCh  chan struct{}
+	results map[string]types.DependencyHealth
+	servers map[string]*serverImpact
+	deps    []Dependency
+	cfg     Config
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	running bool
+}
+
+// serverImpact tracks the recent calls to an upstream server
+type serverImpact struct {
+	observedAt time.Time
+	errorRate  float64
+	latency    float64
+	baseline   float64
+}
+
+// NewMonitor creates a monitor; zero config values take the defaults
+func NewMonitor(cfg Config) *Monitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.ImpactHalfLife <= 0 {
+		cfg.ImpactHalfLife = DefaultImpactHalfLife
+	}
+	return &Monitor{
+		cfg:     cfg,
+		now:     time.Now,
+		stopCh:  make(chan struct{}),
+		results: make(map[string]types.DependencyHealth),
+		servers: make(map[string]*serverImpact),
+	}
+}
+
+// AddDependency registers a dependency to probe. Dependencies are added
+// before the monitor starts.
+func (m *Monitor) AddDependency(dep Dependency) {
+	if dep.SlowAfter <= 0 {
+		dep.SlowAfter = DefaultSlowAfter
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deps = append(m.deps, dep)
+}
+
+// SetMetricEmitter configures where the health score gauges are sent
+func (m *Monitor) SetMetricEmitter(emit MetricEmitter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.emit = emit
+}
+
+// SetClock replaces the monitor's clock, for tests
+func (m *Monitor) SetClock(now func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+}
+
+// Start probes the dependencies once, so readiness is known before the
+// first request, and then every interval until Stop
+func (m *Monitor) Start() {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = true
+	m.mu.Unlock()
+
+	m.CheckNow(context.Background())
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.CheckNow(context.Background())
+			case <-m.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops probing the dependencies
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	m.mu.Unlock()
+
+	close(m.stopCh)
+	m.wg.Wait()
+}
+
+// CheckNow probes every dependency in parallel and returns the resulting
+// service health
+func (m *Monitor) CheckNow(ctx context.Context) *types.ServiceHealth {
+	m.mu.RLock()
+	deps := append([]Dependency(nil), m.deps...)
+	now := m.now
+	m.mu.RUnlock()
+
+	results := make([]types.DependencyHealth, len(deps))
+	var wg sync.WaitGroup
+	for i, dep := range deps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = m.probe(ctx, dep, now)
+		}()
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	for _, result := range results {
+		m.results[result.Name] = result
+	}
+	emit := m.emit
+	m.mu.Unlock()
+
+	health := m.Health()
+	if emit != nil {
+		for _, dep := range health.Dependencies {
+			emit(&types.Metric{
+				Timestamp: health.CheckedAt,
+				Name:      "dependency_health_score",
+				Type:      types.MetricTypeGauge,
+				Value:     dep.Score,
+				Tags:      map[string]string{"dependency": dep.Name},
+			})
+		}
+		emit(&types.Metric{
+			Timestamp: health.CheckedAt,
+			Name:      "service_health_score",
+			Type:      types.MetricTypeGauge,
+			Value:     health.Score,
+		})
+	}
+	return health
+}
+
+// probe runs a dependency's check and scores its latency
+func (m *Monitor) probe(ctx context.Context, dep Dependency, now func() time.Time) types.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.Timeout)
+	defer cancel()
+
+	start := now()
+	err := dep.Check(ctx)
+	latency := now().Sub(start)
+
+	result := types.DependencyHealth{
+		CheckedAt: start,
+		Name:      dep.Name,
+		Critical:  dep.Critical,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	switch {
+	case err != nil:
+		result.Status = types.DependencyStatusDown
+		result.Error = err.Error()
+	case latency > dep.SlowAfter:
+		result.Status = types.DependencyStatusSlow
+		result.Score = math.Max(minDependencyScore, float64(dep.SlowAfter)/float64(latency))
+	default:
+		result.Status = types.DependencyStatusUp
+		result.Score = 1
+	}
+	return result
+}
+
+// Health returns the service health from the last probes. Dependencies not
+// yet probed count as healthy.
+func (m *Monitor) Health() *types.ServiceHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	health := &types.ServiceHealth{
+		Status:       types.ServiceHealthHealthy,
+		Dependencies: make([]types.DependencyHealth, 0, len(m.deps)),
+		Score:        1,
+	}
+	for _, dep := range m.deps {
+		result, ok := m.results[dep.Name]
+		if !ok {
+			result = types.DependencyHealth{
+				Name:     dep.Name,
+				Status:   types.DependencyStatusUnknown,
+				Critical: dep.Critical,
+				Score:    1,
+			}
+		}
+		if result.CheckedAt.After(health.CheckedAt) {
+			health.CheckedAt = result.CheckedAt
+		}
+		health.Dependencies = append(health.Dependencies, result)
+
+		if dep.Critical {
+			health.Score *= result.Score
+		} else {
+			health.Score *= 1 - optionalWeight*(1-result.Score)
+		}
+
+		switch {
+		case result.Status == types.DependencyStatusDown && dep.Critical:
+			health.Status = types.ServiceHealthUnavailable
+		case result.Status == types.DependencyStatusDown, result.Status == types.DependencyStatusSlow:
+			if health.Status == types.ServiceHealthHealthy {
+				health.Status = types.ServiceHealthDegraded
+			}
+		}
+	}
+	return health
+}
+
+// Score returns the service health score from the last probes
+func (m *Monitor) Score() float64 {
+	return m.Health().Score
+}
+
+// ObserveServerCall records the outcome of a call to an upstream server.
+// failed is set when the server could not be reached or failed the call,
+// not when the tool itself reported an error.
+func (m *Monitor) ObserveServerCall(serverID string, latency time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	failure, ms := 0.0, float64(latency.Microseconds())/1000
+	if failed {
+		failure = 1
+	}
+
+	impact, ok := m.servers[serverID]
+	if !ok {
+		m.servers[serverID] = &serverImpact{
+			observedAt: now,
+			errorRate:  callSmoothing * failure,
+			latency:    ms,
+			baseline:   ms,
+		}
+		return
+	}
+
+	impact.errorRate = m.decay(impact.errorRate, now.Sub(impact.observedAt))
+	impact.errorRate += callSmoothing * (failure - impact.errorRate)
+	if !failed {
+		// Failed calls often return early; their latency says nothing
+		// about the server's speed
+		impact.latency += callSmoothing * (ms - impact.latency)
+		impact.baseline += baselineSmoothing * (ms - impact.baseline)
+		if ms < impact.baseline {
+			impact.baseline = ms
+		}
+	}
+	impact.observedAt = now
+}
+
+// ServerScore scores an upstream server from 0 to 1 by its recent call
+// failures and slowdown against its usual latency. Servers without recent
+// calls score 1.
+func (m *Monitor) ServerScore(serverID string) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	impact, ok := m.servers[serverID]
+	if !ok {
+		return 1
+	}
+
+	score := 1 - impact.errorRate
+	if impact.baseline > 0 && impact.latency > latencyFactor*impact.baseline {
+		score *= math.Max(minDependencyScore, latencyFactor*impact.baseline/impact.latency)
+	}
+	return 1 - m.decay(1-score, m.now().Sub(impact.observedAt))
+}
+
+// decay shrinks an impact by the time since it was observed; callers hold
+// m.mu
+func (m *Monitor) decay(impact float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return impact
+	}
+	return impact * math.Pow(0.5, float64(elapsed)/float64(m.cfg.ImpactHalfLife))
+}
@@ -0,0 +1,245 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// maxAttachedServers caps the servers a session can broadcast to besides
+// its own
+const maxAttachedServers = 10
+
+// ErrServerAttached is returned when attaching a server a session already
+// uses
+var ErrServerAttached = errors.New("server is already attached to the session")
+
+// ErrServerNotAttached is returned when detaching a server a session does not
+// broadcast to
+var ErrServerNotAttached = errors.New("server is not attached to the session")
+
+// ErrTooManyAttachedServers is returned when a session would broadcast to
+// more than maxAttachedServers other servers
+var ErrTooManyAttachedServers = fmt.Errorf("a session can attach at most %d servers", maxAttachedServers)
+
+// AttachServersRequest lists the servers of the session's namespace to
+// attach; none attaches every server of the namespace
+type AttachServersRequest struct {
+	ServerIDs []string `json:"server_ids"`
+}
+
+// BroadcastRequest is a request sent to every server of a session
+type BroadcastRequest struct {
+	Params map[string]interface{} `json:"params"`
+	Method string                 `json:"method" binding:"required"`
+}
+
+// BroadcastResult is the response of one server to a broadcast request
+type BroadcastResult struct {
+	Response   *InspectorResponse `json:"response,omitempty"`
+	ServerID   string             `json:"server_id"`
+	ServerName string             `json:"server_name,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	// Primary marks the server the session was created for
+	Primary bool `json:"primary"`
+}
+
+// BroadcastResponse holds the side-by-side responses of a broadcast request
+type BroadcastResponse struct {
+	Timestamp time.Time          `json:"timestamp"`
+	ID        string             `json:"id"`
+	Method    string             `json:"method"`
+	Results   []*BroadcastResult `json:"results"`
+}
+
+// AttachServer connects a session to another server so that broadcast
+// requests are sent to it too
+func (s *Service) AttachServer(ctx context.Context, sessionID, serverID, serverName string) error {
+	s.mu.RLock()
+	session, exists := s.sessions[sessionID]
+	attachedCount := len(s.attached[sessionID])
+	_, attached := s.attached[sessionID][serverID]
+	s.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	if serverID == session.ServerID || attached {
+		return ErrServerAttached
+	}
+	if attachedCount >= maxAttachedServers {
+		return ErrTooManyAttachedServers
+	}
+
+	transport, _, err := s.transportManager.CreateConnection(ctx, types.TransportTypeHTTP, session.UserID, session.OrgID, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to create transport connection: %w", err)
+	}
+	if err := transport.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect transport: %w", err)
+	}
+
+	s.mu.Lock()
+	if _, open := s.sessions[sessionID]; !open || s.attached[sessionID][serverID] != nil {
+		// The session was closed, or the server attached, while connecting
+		s.mu.Unlock()
+		_ = transport.Disconnect(ctx)
+		if !open {
+			return fmt.Errorf("session not found: %s", sessionID)
+		}
+		return ErrServerAttached
+	}
+	if s.attached == nil {
+		s.attached = make(map[string]map[string]types.Transport)
+	}
+	if s.attached[sessionID] == nil {
+		s.attached[sessionID] = make(map[string]types.Transport)
+	}
+	s.attached[sessionID][serverID] = transport
+	session.AttachedServers = append(session.AttachedServers, AttachedServer{
+		ServerID:   serverID,
+		ServerName: serverName,
+		AttachedAt: time.Now(),
+	})
+	s.mu.Unlock()
+
+	s.publishEvent(sessionID, InspectorEvent{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Type:      "server_attached",
+		Data:      map[string]string{"server_id": serverID, "server_name": serverName},
+		Timestamp: time.Now(),
+	})
+	return nil
+}
+
+// DetachServer disconnects a session from an attached server
+func (s *Service) DetachServer(ctx context.Context, sessionID, serverID string) error {
+	s.mu.Lock()
+	session, exists := s.sessions[sessionID]
+	if !exists {
+		s.mu.Unlock()
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	transport, attached := s.attached[sessionID][serverID]
+	if !attached {
+		s.mu.Unlock()
+		return ErrServerNotAttached
+	}
+	delete(s.attached[sessionID], serverID)
+	servers := session.AttachedServers[:0]
+	for _, server := range session.AttachedServers {
+		if server.ServerID != serverID {
+			servers = append(servers, server)
+		}
+	}
+	session.AttachedServers = servers
+	s.mu.Unlock()
+
+	if err := transport.Disconnect(ctx); err != nil {
+		fmt.Printf("Error closing transport connection: %v\n", err)
+	}
+
+	s.publishEvent(sessionID, InspectorEvent{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Type:      "server_detached",
+		Data:      map[string]string{"server_id": serverID},
+		Timestamp: time.Now(),
+	})
+	return nil
+}
+
+// Broadcast sends the same request to the session's server and every
+// attached server at once, and returns their responses side by side in
+// the order the servers were attached
+func (s *Service) Broadcast(ctx context.Context, sessionID string, req BroadcastRequest) (*BroadcastResponse, error) {
+	s.mu.RLock()
+	session, sessionExists := s.sessions[sessionID]
+	conn, connExists := s.connections[sessionID]
+	results := []*BroadcastResult{{ServerID: "", Primary: true}}
+	conns := []types.Transport{conn}
+	if sessionExists {
+		results[0].ServerID = session.ServerID
+		for _, server := range session.AttachedServers {
+			if transport, ok := s.attached[sessionID][server.ServerID]; ok {
+				results = append(results, &BroadcastResult{ServerID: server.ServerID, ServerName: server.ServerName})
+				conns = append(conns, transport)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	if !sessionExists {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+	if !connExists {
+		return nil, fmt.Errorf("connection not found for session: %s", sessionID)
+	}
+	session.LastActivity = time.Now()
+
+	request := InspectorRequest{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Method:    req.Method,
+		Params:    req.Params,
+		Timestamp: time.Now(),
+	}
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(result *BroadcastResult, conn types.Transport) {
+			defer wg.Done()
+			defer func() {
+				// One misbehaving server must not fail the others
+				if r := recover(); r != nil {
+					result.Error = fmt.Sprintf("request failed: %v", r)
+				}
+			}()
+
+			start := time.Now()
+			value, mcpErr := s.dispatch(ctx, conn, request)
+			result.Response = &InspectorResponse{
+				ID:        uuid.New().String(),
+				RequestID: request.ID,
+				Result:    value,
+				Error:     mcpErr,
+				Duration:  time.Since(start),
+				Timestamp: time.Now(),
+			}
+		}(results[i], conns[i])
+	}
+	wg.Wait()
+
+	response := &BroadcastResponse{
+		ID:        request.ID,
+		Method:    req.Method,
+		Results:   results,
+		Timestamp: time.Now(),
+	}
+	s.publishEvent(sessionID, InspectorEvent{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Type:      "broadcast",
+		Data:      response,
+		Timestamp: response.Timestamp,
+	})
+	return response, nil
+}
+
+// disconnectAttached closes a session's connections to attached servers;
+// callers hold s.mu
+func (s *Service) disconnectAttached(ctx context.Context, sessionID string) {
+	for _, transport := range s.attached[sessionID] {
+		if err := transport.Disconnect(ctx); err != nil {
+			fmt.Printf("Error closing transport connection: %v\n", err)
+		}
+	}
+	delete(s.attached, sessionID)
+}
@@ -0,0 +1,142 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// broadcastTestService returns a service with a connected session on
+// server-a in a namespace, whose transport lists an "a" tool
+func broadcastTestService(t *testing.T, manager *MockTransportManager) (*Service, *InspectorSession) {
+	t.Helper()
+	service := NewService(manager)
+	session := NewInspectorSession("server-a", "user123", "org456", "namespace001")
+	session.Status = SessionStatusConnected
+
+	primary := &MockTransport{}
+	primary.On("SendMessage", mock.Anything, mock.AnythingOfType("types.MCPMessage")).Return(nil)
+	primary.On("ReceiveMessage", mock.Anything).Return(types.MCPMessage{Result: toolList("a")}, nil)
+	primary.On("Disconnect", mock.Anything).Return(nil)
+
+	service.sessions[session.ID] = session
+	service.connections[session.ID] = primary
+	service.eventChannels[session.ID] = make(chan InspectorEvent, 100)
+	return service, session
+}
+
+// attachableServer expects a connection to serverID whose transport
+// answers every request with result
+func attachableServer(manager *MockTransportManager, serverID string, result interface{}) *MockTransport {
+	transport := &MockTransport{}
+	transport.On("Connect", mock.Anything).Return(nil)
+	transport.On("SendMessage", mock.Anything, mock.AnythingOfType("types.MCPMessage")).Return(nil)
+	transport.On("ReceiveMessage", mock.Anything).Return(types.MCPMessage{Result: result}, nil)
+	transport.On("Disconnect", mock.Anything).Return(nil)
+	manager.On("CreateConnection", mock.Anything, types.TransportTypeHTTP, "user123", "org456", serverID).
+		Return(transport, (*types.TransportSession)(nil), nil)
+	return transport
+}
+
+// toolList is a tools/list result with a single tool
+func toolList(name string) map[string]interface{} {
+	return map[string]interface{}{"tools": []interface{}{map[string]interface{}{"name": name}}}
+}
+
+func TestBroadcast_SideBySideResponses(t *testing.T) {
+	ctx := context.Background()
+	manager := &MockTransportManager{}
+	service, session := broadcastTestService(t, manager)
+	attachableServer(manager, "server-b", toolList("b"))
+	failing := attachableServer(manager, "server-c", nil)
+	failing.ExpectedCalls = nil
+	failing.On("Connect", mock.Anything).Return(nil)
+	failing.On("SendMessage", mock.Anything, mock.AnythingOfType("types.MCPMessage")).Return(errors.New("connection refused"))
+
+	require.NoError(t, service.AttachServer(ctx, session.ID, "server-b", "Server B"))
+	require.NoError(t, service.AttachServer(ctx, session.ID, "server-c", "Server C"))
+	require.Len(t, session.AttachedServers, 2)
+
+	response, err := service.Broadcast(ctx, session.ID, BroadcastRequest{Method: "tools/list"})
+	require.NoError(t, err)
+	assert.Equal(t, "tools/list", response.Method)
+	require.Len(t, response.Results, 3)
+
+	assert.True(t, response.Results[0].Primary)
+	assert.Equal(t, "server-a", response.Results[0].ServerID)
+	assert.Equal(t, "a", response.Results[0].Response.Result.(*ListToolsResult).Tools[0].Name)
+
+	assert.Equal(t, "server-b", response.Results[1].ServerID)
+	assert.Equal(t, "Server B", response.Results[1].ServerName)
+	assert.False(t, response.Results[1].Primary)
+	assert.Equal(t, "b", response.Results[1].Response.Result.(*ListToolsResult).Tools[0].Name)
+
+	// A failing server does not fail the broadcast
+	assert.Equal(t, "server-c", response.Results[2].ServerID)
+	require.NotNil(t, response.Results[2].Response.Error)
+	assert.Contains(t, response.Results[2].Response.Error.Message, "connection refused")
+
+	for _, result := range response.Results {
+		assert.Equal(t, response.ID, result.Response.RequestID)
+	}
+}
+
+func TestBroadcast_AttachAndDetach(t *testing.T) {
+	ctx := context.Background()
+	manager := &MockTransportManager{}
+	service, session := broadcastTestService(t, manager)
+	attached := attachableServer(manager, "server-b", map[string]interface{}{})
+
+	assert.ErrorIs(t, service.AttachServer(ctx, session.ID, "server-a", ""), ErrServerAttached)
+	require.NoError(t, service.AttachServer(ctx, session.ID, "server-b", "Server B"))
+	assert.ErrorIs(t, service.AttachServer(ctx, session.ID, "server-b", "Server B"), ErrServerAttached)
+
+	assert.ErrorIs(t, service.DetachServer(ctx, session.ID, "server-x"), ErrServerNotAttached)
+	require.NoError(t, service.DetachServer(ctx, session.ID, "server-b"))
+	assert.Empty(t, session.AttachedServers)
+	attached.AssertCalled(t, "Disconnect", ctx)
+
+	response, err := service.Broadcast(ctx, session.ID, BroadcastRequest{Method: "ping"})
+	require.NoError(t, err)
+	assert.Len(t, response.Results, 1)
+}
+
+func TestBroadcast_AttachLimitAndErrors(t *testing.T) {
+	ctx := context.Background()
+	manager := &MockTransportManager{}
+	service, session := broadcastTestService(t, manager)
+
+	manager.On("CreateConnection", mock.Anything, types.TransportTypeHTTP, "user123", "org456", "unreachable").
+		Return(nil, nil, errors.New("no route to server"))
+	assert.Error(t, service.AttachServer(ctx, session.ID, "unreachable", ""))
+	assert.Empty(t, session.AttachedServers)
+
+	for i := 0; i < maxAttachedServers; i++ {
+		serverID := string(rune('b' + i))
+		attachableServer(manager, serverID, nil)
+		require.NoError(t, service.AttachServer(ctx, session.ID, serverID, ""))
+	}
+	assert.ErrorIs(t, service.AttachServer(ctx, session.ID, "one-too-many", ""), ErrTooManyAttachedServers)
+
+	assert.Error(t, service.AttachServer(ctx, "missing", "server-b", ""))
+	_, err := service.Broadcast(ctx, "missing", BroadcastRequest{Method: "ping"})
+	assert.Error(t, err)
+}
+
+func TestBroadcast_CloseSessionDisconnectsAttached(t *testing.T) {
+	ctx := context.Background()
+	manager := &MockTransportManager{}
+	service, session := broadcastTestService(t, manager)
+	attached := attachableServer(manager, "server-b", nil)
+	require.NoError(t, service.AttachServer(ctx, session.ID, "server-b", "Server B"))
+
+	require.NoError(t, service.CloseSession(ctx, session.ID))
+	attached.AssertCalled(t, "Disconnect", ctx)
+	assert.Empty(t, service.attached)
+}
@@ -0,0 +1,470 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// Frame directions, relative to the gateway
+const (
+	FrameDirectionOutbound = "outbound"
+	FrameDirectionInbound  = "inbound"
+)
+
+// Frame kinds
+const (
+	FrameKindRequest      = "request"
+	FrameKindResponse     = "response"
+	FrameKindNotification = "notification"
+	FrameKindError        = "error"
+)
+
+// Token types of highlighted frame text
+const (
+	TokenKey         = "key"
+	TokenString      = "string"
+	TokenNumber      = "number"
+	TokenBoolean     = "boolean"
+	TokenNull        = "null"
+	TokenPunctuation = "punctuation"
+)
+
+const (
+	// maxSessionFrames bounds the frames kept per session; the oldest are
+	// dropped first
+	maxSessionFrames = 500
+	// maxHighlightedFrameSize bounds the frames that are tokenized; larger
+	// frames are shown without highlighting
+	maxHighlightedFrameSize = 256 * 1024
+	// maxNotificationsPerRequest bounds the notifications read while
+	// waiting for a response
+	maxNotificationsPerRequest = 100
+)
+
+// ErrInvalidFrame is returned for raw frames that are not JSON-RPC requests
+// or notifications
+var ErrInvalidFrame = errors.New("invalid JSON-RPC frame")
+
+// ErrFrameNotFound is returned for frames the session did not capture
+var ErrFrameNotFound = errors.New("frame not found")
+
+// Frame is a JSON-RPC message sent to or received from a session's server,
+// as it appeared on the wire. Text is Raw indented for display and Tokens
+// mark its spans for syntax highlighting.
+type Frame struct {
+	ID        string          `json:"id"`
+	SessionID string          `json:"session_id"`
+	Direction string          `json:"direction"`
+	Kind      string          `json:"kind"`
+	Method    string          `json:"method,omitempty"`
+	RPCID     string          `json:"rpc_id,omitempty"`
+	Raw       json.RawMessage `json:"raw"`
+	Text      string          `json:"text"`
+	Tokens    []Token         `json:"tokens,omitempty"`
+	Size      int             `json:"size"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Token is a span of a frame's text, in bytes
+type Token struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Type  string `json:"type"`
+}
+
+// RawExchange is a raw frame sent to a server with what came back: the
+// response, none for notifications, and notifications the server sent
+// before responding
+type RawExchange struct {
+	Request       *Frame   `json:"request"`
+	Response      *Frame   `json:"response,omitempty"`
+	Notifications []*Frame `json:"notifications"`
+	Duration      int64    `json:"duration"` // milliseconds
+}
+
+// SendRawRequest is a raw JSON-RPC frame to send on a session
+type SendRawRequest struct {
+	Frame json.RawMessage `json:"frame" binding:"required"`
+}
+
+// ResendFrameRequest optionally replaces a captured frame before it is sent
+// again
+type ResendFrameRequest struct {
+	Frame json.RawMessage `json:"frame"`
+}
+
+// rpcFrame is the JSON-RPC 2.0 wire form of an MCP message
+type rpcFrame struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  interface{}     `json:"params,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *types.MCPError `json:"error,omitempty"`
+}
+
+// ListFrames returns the frames captured on a session, oldest first
+func (s *Service) ListFrames(sessionID string) ([]*Frame, error) {
+	if _, err := s.GetSession(sessionID); err != nil {
+		return nil, err
+	}
+
+	s.framesMu.Lock()
+	defer s.framesMu.Unlock()
+	frames := make([]*Frame, len(s.frames[sessionID]))
+	copy(frames, s.frames[sessionID])
+	return frames, nil
+}
+
+// GetFrame returns a frame captured on a session
+func (s *Service) GetFrame(sessionID, frameID string) (*Frame, error) {
+	frames, err := s.ListFrames(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, frame := range frames {
+		if frame.ID == frameID {
+			return frame, nil
+		}
+	}
+	return nil, ErrFrameNotFound
+}
+
+// SendRaw sends a JSON-RPC request or notification as given and, for
+// requests, waits for the server's response
+func (s *Service) SendRaw(ctx context.Context, sessionID string, raw json.RawMessage) (*RawExchange, error) {
+	message, err := parseRawFrame(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	session, sessionExists := s.sessions[sessionID]
+	conn, connExists := s.connections[sessionID]
+	s.mu.RUnlock()
+	if !sessionExists {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+	if !connExists {
+		return nil, fmt.Errorf("connection not found for session: %s", sessionID)
+	}
+	session.LastActivity = time.Now()
+
+	// Frames are captured without the recording transport so that the
+	// exchange can return them
+	transport := conn
+	if recording, ok := conn.(*recordingTransport); ok {
+		transport = recording.Transport
+	}
+
+	start := time.Now()
+	exchange := &RawExchange{Notifications: []*Frame{}}
+	if err := transport.SendMessage(ctx, message); err != nil {
+		return nil, fmt.Errorf("failed to send frame: %w", err)
+	}
+	exchange.Request = s.recordFrame(sessionID, FrameDirectionOutbound, message)
+
+	if message.Type == types.MCPMessageTypeRequest {
+		for i := 0; ; i++ {
+			received, err := transport.ReceiveMessage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to receive response: %w", err)
+			}
+			frame := s.recordFrame(sessionID, FrameDirectionInbound, received)
+			if frame.Kind != FrameKindNotification {
+				exchange.Response = frame
+				break
+			}
+			exchange.Notifications = append(exchange.Notifications, frame)
+			if i >= maxNotificationsPerRequest {
+				return nil, fmt.Errorf("no response after %d notifications", maxNotificationsPerRequest)
+			}
+		}
+	}
+	exchange.Duration = time.Since(start).Milliseconds()
+	return exchange, nil
+}
+
+// ResendFrame sends a captured outbound frame again, or edited in its
+// place
+func (s *Service) ResendFrame(ctx context.Context, sessionID, frameID string, edited json.RawMessage) (*RawExchange, error) {
+	frame, err := s.GetFrame(sessionID, frameID)
+	if err != nil {
+		return nil, err
+	}
+	if frame.Direction != FrameDirectionOutbound {
+		return nil, fmt.Errorf("%w: only frames sent to the server can be resent", ErrInvalidFrame)
+	}
+	if len(bytes.TrimSpace(edited)) == 0 || bytes.Equal(bytes.TrimSpace(edited), []byte("null")) {
+		edited = frame.Raw
+	}
+	return s.SendRaw(ctx, sessionID, edited)
+}
+
+// recordFrame captures a message on a session and publishes it as a frame
+// event
+func (s *Service) recordFrame(sessionID, direction string, message interface{}) *Frame {
+	frame := newFrame(sessionID, direction, message)
+
+	s.framesMu.Lock()
+	if s.frames == nil {
+		s.frames = make(map[string][]*Frame)
+	}
+	frames := append(s.frames[sessionID], frame)
+	if len(frames) > maxSessionFrames {
+		frames = frames[len(frames)-maxSessionFrames:]
+	}
+	s.frames[sessionID] = frames
+	s.framesMu.Unlock()
+
+	s.publishEvent(sessionID, InspectorEvent{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Type:      "frame",
+		Data:      frame,
+		Timestamp: frame.Timestamp,
+	})
+	return frame
+}
+
+// dropFrames forgets a closed session's frames
+func (s *Service) dropFrames(sessionID string) {
+	s.framesMu.Lock()
+	delete(s.frames, sessionID)
+	s.framesMu.Unlock()
+}
+
+// newFrame renders a message in its JSON-RPC wire form
+func newFrame(sessionID, direction string, message interface{}) *Frame {
+	frame := &Frame{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Direction: direction,
+		Timestamp: time.Now(),
+	}
+
+	msg, ok := message.(types.MCPMessage)
+	if !ok {
+		if pointer, isPointer := message.(*types.MCPMessage); isPointer && pointer != nil {
+			msg, ok = *pointer, true
+		}
+	}
+	if !ok {
+		// Transports may hand back messages in other shapes; show them as
+		// they are
+		frame.Kind = FrameKindResponse
+		frame.Raw, _ = json.Marshal(message)
+		frame.finish()
+		return frame
+	}
+
+	wire := rpcFrame{JSONRPC: "2.0", Method: msg.Method, Result: msg.Result, Error: msg.Error}
+	if msg.Params != nil {
+		wire.Params = msg.Params
+	}
+	frame.Method = msg.Method
+	frame.RPCID = msg.ID
+	if msg.ID != "" {
+		wire.ID, _ = json.Marshal(msg.ID)
+	}
+
+	switch {
+	case isNotification(msg):
+		frame.Kind = FrameKindNotification
+	case msg.Error != nil:
+		frame.Kind = FrameKindError
+	case msg.Method != "" && direction == FrameDirectionOutbound:
+		frame.Kind = FrameKindRequest
+	case msg.Method != "" && msg.Result == nil:
+		// Requests from the server, such as sampling, are shown as requests
+		frame.Kind = FrameKindRequest
+	default:
+		frame.Kind = FrameKindResponse
+	}
+
+	frame.Raw, _ = json.Marshal(wire)
+	frame.finish()
+	return frame
+}
+
+// isNotification reports whether a message is a notification: marked as
+// one, or a method call without an id
+func isNotification(msg types.MCPMessage) bool {
+	return msg.Type == types.MCPMessageTypeNotification ||
+		(msg.ID == "" && msg.Method != "" && msg.Result == nil && msg.Error == nil)
+}
+
+// finish fills in the display text, tokens and size of a frame
+func (f *Frame) finish() {
+	f.Size = len(f.Raw)
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, f.Raw, "", "  "); err != nil {
+		f.Text = string(f.Raw)
+		return
+	}
+	f.Text = indented.String()
+	if len(f.Text) <= maxHighlightedFrameSize {
+		f.Tokens = Highlight(f.Text)
+	}
+}
+
+// RequestFrame builds a raw JSON-RPC request with a fresh id, or a
+// notification
+func RequestFrame(method string, params map[string]interface{}, notification bool) (json.RawMessage, error) {
+	frame := rpcFrame{JSONRPC: "2.0", Method: method}
+	if params != nil {
+		frame.Params = params
+	}
+	if !notification {
+		frame.ID, _ = json.Marshal(uuid.New().String())
+	}
+	return json.Marshal(frame)
+}
+
+// parseRawFrame turns a raw JSON-RPC request or notification into an MCP
+// message. A frame without an id is a notification.
+func parseRawFrame(raw json.RawMessage) (types.MCPMessage, error) {
+	var frame struct {
+		JSONRPC *string                `json:"jsonrpc"`
+		ID      json.RawMessage        `json:"id"`
+		Method  string                 `json:"method"`
+		Params  map[string]interface{} `json:"params"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&frame); err != nil {
+		return types.MCPMessage{}, fmt.Errorf("%w: %v", ErrInvalidFrame, err)
+	}
+	if frame.JSONRPC != nil && *frame.JSONRPC != "2.0" {
+		return types.MCPMessage{}, fmt.Errorf("%w: jsonrpc must be \"2.0\"", ErrInvalidFrame)
+	}
+	if strings.TrimSpace(frame.Method) == "" {
+		return types.MCPMessage{}, fmt.Errorf("%w: method is required", ErrInvalidFrame)
+	}
+
+	message := types.MCPMessage{
+		Type:    types.MCPMessageTypeNotification,
+		Method:  frame.Method,
+		Params:  frame.Params,
+		Version: "2.0",
+	}
+	if id := bytes.TrimSpace(frame.ID); len(id) > 0 && !bytes.Equal(id, []byte("null")) {
+		var value interface{}
+		if err := json.Unmarshal(id, &value); err != nil {
+			return types.MCPMessage{}, fmt.Errorf("%w: %v", ErrInvalidFrame, err)
+		}
+		switch value := value.(type) {
+		case string:
+			message.ID = value
+		case float64:
+			message.ID = string(id)
+		default:
+			return types.MCPMessage{}, fmt.Errorf("%w: id must be a string or number", ErrInvalidFrame)
+		}
+		message.Type = types.MCPMessageTypeRequest
+	}
+	return message, nil
+}
+
+// Highlight tokenizes JSON text for syntax highlighting. Object keys are
+// told apart from string values; whitespace is not tokenized.
+func Highlight(text string) []Token {
+	type container struct {
+		object    bool
+		expectKey bool
+	}
+	var open []container
+	punctuation := func(i int) Token { return Token{Start: i, End: i + 1, Type: TokenPunctuation} }
+
+	tokens := []Token{}
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\n' || c == '\r' || c == '\t':
+			i++
+			continue
+		case c == '{' || c == '[':
+			open = append(open, container{object: c == '{', expectKey: c == '{'})
+			tokens = append(tokens, punctuation(i))
+			i++
+		case c == '}' || c == ']':
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+			tokens = append(tokens, punctuation(i))
+			i++
+		case c == ':' || c == ',':
+			if len(open) > 0 {
+				top := &open[len(open)-1]
+				top.expectKey = top.object && c == ','
+			}
+			tokens = append(tokens, punctuation(i))
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(text) && text[end] != '"' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(text))
+			tokenType := TokenString
+			if len(open) > 0 && open[len(open)-1].expectKey {
+				tokenType = TokenKey
+			}
+			tokens = append(tokens, Token{Start: i, End: end, Type: tokenType})
+			i = end
+		default:
+			end := i + 1
+			for end < len(text) && !strings.ContainsRune(" \n\r\t,:{}[]\"", rune(text[end])) {
+				end++
+			}
+			tokenType := TokenNumber
+			switch text[i:end] {
+			case "true", "false":
+				tokenType = TokenBoolean
+			case "null":
+				tokenType = TokenNull
+			}
+			tokens = append(tokens, Token{Start: i, End: end, Type: tokenType})
+			i = end
+		}
+	}
+	return tokens
+}
+
+// recordingTransport captures the frames a session's transport sends and
+// receives
+type recordingTransport struct {
+	types.Transport
+	service   *Service
+	sessionID string
+}
+
+// SendMessage sends a message and captures it once sent
+func (t *recordingTransport) SendMessage(ctx context.Context, message interface{}) error {
+	if err := t.Transport.SendMessage(ctx, message); err != nil {
+		return err
+	}
+	t.service.recordFrame(t.sessionID, FrameDirectionOutbound, message)
+	return nil
+}
+
+// ReceiveMessage receives a message and captures it
+func (t *recordingTransport) ReceiveMessage(ctx context.Context) (interface{}, error) {
+	message, err := t.Transport.ReceiveMessage(ctx)
+	if err == nil {
+		t.service.recordFrame(t.sessionID, FrameDirectionInbound, message)
+	}
+	return message, err
+}
@@ -0,0 +1,242 @@
+package inspector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// frameTestService returns a service with a connected session over a
+// recording mock transport
+func frameTestService(t *testing.T) (*Service, *InspectorSession, *MockTransport) {
+	t.Helper()
+	service := NewService(nil)
+	session := NewInspectorSession("server789", "user123", "org456", "")
+	session.Status = SessionStatusConnected
+
+	mockTransport := &MockTransport{}
+	service.sessions[session.ID] = session
+	service.connections[session.ID] = &recordingTransport{Transport: mockTransport, service: service, sessionID: session.ID}
+	service.eventChannels[session.ID] = make(chan InspectorEvent, 100)
+	return service, session, mockTransport
+}
+
+func TestFrames_RecordedForExecutedRequests(t *testing.T) {
+	ctx := context.Background()
+	service, session, mockTransport := frameTestService(t)
+
+	mockTransport.On("SendMessage", ctx, mock.AnythingOfType("types.MCPMessage")).Return(nil)
+	mockTransport.On("ReceiveMessage", ctx).Return(types.MCPMessage{
+		Type:   types.MCPMessageTypeNotification,
+		Method: "notifications/progress",
+		Params: map[string]interface{}{"progress": 1},
+	}, nil).Once()
+	mockTransport.On("ReceiveMessage", ctx).Return(types.MCPMessage{
+		ID:     "1",
+		Result: map[string]interface{}{"tools": []interface{}{}},
+	}, nil).Once()
+
+	response, err := service.ExecuteRequest(ctx, session.ID, InspectorRequest{Method: "tools/list"})
+	require.NoError(t, err)
+	assert.Nil(t, response.Error)
+
+	frames, err := service.ListFrames(session.ID)
+	require.NoError(t, err)
+	require.Len(t, frames, 3)
+	assert.Equal(t, FrameDirectionOutbound, frames[0].Direction)
+	assert.Equal(t, FrameKindRequest, frames[0].Kind)
+	assert.Equal(t, "tools/list", frames[0].Method)
+	assert.Equal(t, FrameKindNotification, frames[1].Kind)
+	assert.Equal(t, "notifications/progress", frames[1].Method)
+	assert.Equal(t, FrameKindResponse, frames[2].Kind)
+
+	var wire map[string]interface{}
+	require.NoError(t, json.Unmarshal(frames[0].Raw, &wire))
+	assert.Equal(t, "2.0", wire["jsonrpc"])
+	assert.NotEmpty(t, frames[0].Tokens)
+	assert.Contains(t, frames[0].Text, "\n  \"jsonrpc\"")
+
+	found, err := service.GetFrame(session.ID, frames[1].ID)
+	require.NoError(t, err)
+	assert.Same(t, frames[1], found)
+
+	_, err = service.GetFrame(session.ID, "missing")
+	assert.ErrorIs(t, err, ErrFrameNotFound)
+
+	mockTransport.On("Disconnect", ctx).Return(nil)
+	require.NoError(t, service.CloseSession(ctx, session.ID))
+	_, err = service.ListFrames(session.ID)
+	assert.Error(t, err)
+}
+
+func TestFrames_SendRawCollectsNotifications(t *testing.T) {
+	ctx := context.Background()
+	service, session, mockTransport := frameTestService(t)
+
+	var sent types.MCPMessage
+	mockTransport.On("SendMessage", ctx, mock.AnythingOfType("types.MCPMessage")).
+		Run(func(args mock.Arguments) { sent = args.Get(1).(types.MCPMessage) }).
+		Return(nil)
+	mockTransport.On("ReceiveMessage", ctx).Return(types.MCPMessage{Method: "notifications/message"}, nil).Once()
+	mockTransport.On("ReceiveMessage", ctx).Return(types.MCPMessage{
+		ID:    "7",
+		Error: &types.MCPError{Code: -32601, Message: "Method not found"},
+	}, nil).Once()
+
+	exchange, err := service.SendRaw(ctx, session.ID, json.RawMessage(`{"jsonrpc":"2.0","id":7,"method":"custom/echo","params":{"text":"hi"}}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "7", sent.ID)
+	assert.Equal(t, types.MCPMessageTypeRequest, sent.Type)
+	assert.Equal(t, "hi", sent.Params["text"])
+	assert.Equal(t, FrameKindRequest, exchange.Request.Kind)
+	require.Len(t, exchange.Notifications, 1)
+	assert.Equal(t, "notifications/message", exchange.Notifications[0].Method)
+	require.NotNil(t, exchange.Response)
+	assert.Equal(t, FrameKindError, exchange.Response.Kind)
+
+	// The frames are captured once even though SendRaw bypasses the
+	// recording transport
+	frames, err := service.ListFrames(session.ID)
+	require.NoError(t, err)
+	assert.Len(t, frames, 3)
+}
+
+func TestFrames_SendRawNotificationDoesNotWait(t *testing.T) {
+	ctx := context.Background()
+	service, session, mockTransport := frameTestService(t)
+	mockTransport.On("SendMessage", ctx, mock.AnythingOfType("types.MCPMessage")).Return(nil)
+
+	exchange, err := service.SendRaw(ctx, session.ID, json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	require.NoError(t, err)
+	assert.Equal(t, FrameKindNotification, exchange.Request.Kind)
+	assert.Nil(t, exchange.Response)
+	mockTransport.AssertNotCalled(t, "ReceiveMessage", ctx)
+}
+
+func TestFrames_SendRawRejectsInvalidFrames(t *testing.T) {
+	service, session, _ := frameTestService(t)
+
+	for name, raw := range map[string]string{
+		"not json":       `{"method":`,
+		"no method":      `{"jsonrpc":"2.0","id":1}`,
+		"wrong version":  `{"jsonrpc":"1.0","id":1,"method":"ping"}`,
+		"object id":      `{"jsonrpc":"2.0","id":{},"method":"ping"}`,
+		"array as frame": `[{"jsonrpc":"2.0","id":1,"method":"ping"}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.SendRaw(context.Background(), session.ID, json.RawMessage(raw))
+			assert.ErrorIs(t, err, ErrInvalidFrame)
+		})
+	}
+}
+
+func TestFrames_ResendFrame(t *testing.T) {
+	ctx := context.Background()
+	service, session, mockTransport := frameTestService(t)
+
+	var sent []types.MCPMessage
+	mockTransport.On("SendMessage", ctx, mock.AnythingOfType("types.MCPMessage")).
+		Run(func(args mock.Arguments) { sent = append(sent, args.Get(1).(types.MCPMessage)) }).
+		Return(nil)
+	mockTransport.On("ReceiveMessage", ctx).Return(types.MCPMessage{ID: "1", Result: map[string]interface{}{}}, nil)
+
+	first, err := service.SendRaw(ctx, session.ID, json.RawMessage(`{"jsonrpc":"2.0","id":"1","method":"tools/call","params":{"name":"echo"}}`))
+	require.NoError(t, err)
+
+	_, err = service.ResendFrame(ctx, session.ID, first.Request.ID, nil)
+	require.NoError(t, err)
+	_, err = service.ResendFrame(ctx, session.ID, first.Request.ID, json.RawMessage(`{"jsonrpc":"2.0","id":"2","method":"tools/call","params":{"name":"reverse"}}`))
+	require.NoError(t, err)
+
+	require.Len(t, sent, 3)
+	assert.Equal(t, sent[0], sent[1])
+	assert.Equal(t, "2", sent[2].ID)
+	assert.Equal(t, "reverse", sent[2].Params["name"])
+
+	// Only frames sent to the server can be resent
+	_, err = service.ResendFrame(ctx, session.ID, first.Response.ID, nil)
+	assert.ErrorIs(t, err, ErrInvalidFrame)
+	_, err = service.ResendFrame(ctx, session.ID, "missing", nil)
+	assert.ErrorIs(t, err, ErrFrameNotFound)
+}
+
+func TestFrames_SendRawTransportError(t *testing.T) {
+	ctx := context.Background()
+	service, session, mockTransport := frameTestService(t)
+	mockTransport.On("SendMessage", ctx, mock.AnythingOfType("types.MCPMessage")).Return(errors.New("connection reset"))
+
+	_, err := service.SendRaw(ctx, session.ID, json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidFrame)
+
+	frames, err := service.ListFrames(session.ID)
+	require.NoError(t, err)
+	assert.Empty(t, frames)
+}
+
+func TestRequestFrame(t *testing.T) {
+	raw, err := RequestFrame("tools/call", map[string]interface{}{"name": "echo"}, false)
+	require.NoError(t, err)
+	message, err := parseRawFrame(raw)
+	require.NoError(t, err)
+	assert.Equal(t, types.MCPMessageTypeRequest, message.Type)
+	assert.NotEmpty(t, message.ID)
+	assert.Equal(t, "echo", message.Params["name"])
+
+	raw, err = RequestFrame("notifications/initialized", nil, true)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), `"id"`)
+	message, err = parseRawFrame(raw)
+	require.NoError(t, err)
+	assert.Equal(t, types.MCPMessageTypeNotification, message.Type)
+}
+
+func TestHighlight(t *testing.T) {
+	text := `{"name": "echo", "args": [1.5e3, true, null, {"k": "v"}], "ok": false}`
+	tokens := Highlight(text)
+
+	var got [][2]string
+	for _, token := range tokens {
+		got = append(got, [2]string{token.Type, text[token.Start:token.End]})
+	}
+	assert.Equal(t, [][2]string{
+		{TokenPunctuation, "{"},
+		{TokenKey, `"name"`},
+		{TokenPunctuation, ":"},
+		{TokenString, `"echo"`},
+		{TokenPunctuation, ","},
+		{TokenKey, `"args"`},
+		{TokenPunctuation, ":"},
+		{TokenPunctuation, "["},
+		{TokenNumber, "1.5e3"},
+		{TokenPunctuation, ","},
+		{TokenBoolean, "true"},
+		{TokenPunctuation, ","},
+		{TokenNull, "null"},
+		{TokenPunctuation, ","},
+		{TokenPunctuation, "{"},
+		{TokenKey, `"k"`},
+		{TokenPunctuation, ":"},
+		{TokenString, `"v"`},
+		{TokenPunctuation, "}"},
+		{TokenPunctuation, "]"},
+		{TokenPunctuation, ","},
+		{TokenKey, `"ok"`},
+		{TokenPunctuation, ":"},
+		{TokenBoolean, "false"},
+		{TokenPunctuation, "}"},
+	}, got)
+
+	// Escaped quotes stay inside their string
+	tokens = Highlight(`["a\"b"]`)
+	require.Len(t, tokens, 3)
+	assert.Equal(t, Token{Start: 1, End: 7, Type: TokenString}, tokens[1])
+}
@@ -34,6 +34,14 @@ type Service struct {
 	connections      map[string]types.Transport
 	eventChannels    map[string]chan InspectorEvent
 	mu               sync.RWMutex
+
+	// attached holds the connections to the other servers a session
+	// broadcasts to, by session and server
+	attached map[string]map[string]types.Transport
+
+	// frames holds the JSON-RPC frames captured per session
+	frames   map[string][]*Frame
+	framesMu sync.Mutex
 }
 
 // NewService creates a new inspector service
@@ -43,6 +51,8 @@ func NewService(transportManager TransportManager) *Service {
 		sessions:         make(map[string]*InspectorSession),
 		connections:      make(map[string]types.Transport),
 		eventChannels:    make(map[string]chan InspectorEvent),
+		attached:         make(map[string]map[string]types.Transport),
+		frames:           make(map[string][]*Frame),
 	}
 }
 
@@ -62,6 +72,7 @@ func (s *Service) CreateSession(ctx context.Context, serverID, userID, orgID, na
 		session.Status = SessionStatusError
 		return nil, fmt.Errorf("failed to connect transport: %w", err)
 	}
+	transport = &recordingTransport{Transport: transport, service: s, sessionID: session.ID}
 
 	// Get server capabilities
 	capabilities, err := s.getServerCapabilities(ctx, transport)
@@ -123,6 +134,7 @@ func (s *Service) CloseSession(ctx context.Context, sessionID string) error {
 		}
 		delete(s.connections, sessionID)
 	}
+	s.disconnectAttached(ctx, sessionID)
 
 	// Update session status
 	session.Status = SessionStatusDisconnected
@@ -151,6 +163,7 @@ func (s *Service) CloseSession(ctx context.Context, sessionID string) error {
 
 	// Remove session
 	delete(s.sessions, sessionID)
+	s.dropFrames(sessionID)
 
 	return nil
 }
@@ -176,7 +189,31 @@ func (s *Service) ExecuteRequest(ctx context.Context, sessionID string, req Insp
 	// Update last activity
 	session.LastActivity = time.Now()
 
-	// Execute based on method
+	result, mcpErr := s.dispatch(ctx, conn, req)
+
+	response := &InspectorResponse{
+		ID:        uuid.New().String(),
+		RequestID: req.ID,
+		Result:    result,
+		Error:     mcpErr,
+		Duration:  time.Since(start),
+		Timestamp: time.Now(),
+	}
+
+	// Publish response event
+	s.publishEvent(sessionID, InspectorEvent{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Type:      "response",
+		Data:      response,
+		Timestamp: time.Now(),
+	})
+
+	return response, nil
+}
+
+// dispatch executes a request on a connection based on its method
+func (s *Service) dispatch(ctx context.Context, conn types.Transport, req InspectorRequest) (interface{}, *MCPError) {
 	var result interface{}
 	var mcpErr *MCPError
 
@@ -203,25 +240,7 @@ func (s *Service) ExecuteRequest(ctx context.Context, sessionID string, req Insp
 		mcpErr = &MCPError{Code: -32601, Message: "Method not found"}
 	}
 
-	response := &InspectorResponse{
-		ID:        uuid.New().String(),
-		RequestID: req.ID,
-		Result:    result,
-		Error:     mcpErr,
-		Duration:  time.Since(start),
-		Timestamp: time.Now(),
-	}
-
-	// Publish response event
-	s.publishEvent(sessionID, InspectorEvent{
-		ID:        uuid.New().String(),
-		SessionID: sessionID,
-		Type:      "response",
-		Data:      response,
-		Timestamp: time.Now(),
-	})
-
-	return response, nil
+	return result, mcpErr
 }
 
 // GetEventChannel returns the event channel for a session
@@ -306,9 +325,19 @@ func (s *Service) sendAndReceive(ctx context.Context, transport types.Transport,
 		return nil, err
 	}
 
-	response, err := transport.ReceiveMessage(ctx)
-	if err != nil {
-		return nil, err
+	// Notifications the server sends before responding are captured as
+	// frames and skipped
+	var response interface{}
+	for i := 0; ; i++ {
+		received, err := transport.ReceiveMessage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if msg, ok := received.(types.MCPMessage); ok && isNotification(msg) && i < maxNotificationsPerRequest {
+			continue
+		}
+		response = received
+		break
 	}
 
 	if msg, ok := response.(types.MCPMessage); ok {
@@ -35,6 +35,16 @@ type InspectorSession struct {
 	CreatedAt    time.Time              `json:"created_at"`
 	LastActivity time.Time              `json:"last_activity"`
 	Metadata     map[string]interface{} `json:"metadata"`
+	// AttachedServers are the other servers of the namespace that
+	// broadcast requests are also sent to
+	AttachedServers []AttachedServer `json:"attached_servers,omitempty"`
+}
+
+// AttachedServer is a server attached to a session for broadcast requests
+type AttachedServer struct {
+	ServerID   string    `json:"server_id"`
+	ServerName string    `json:"server_name"`
+	AttachedAt time.Time `json:"attached_at"`
 }
 
 // InspectorRequest represents a request to execute on an MCP server
@@ -0,0 +1,269 @@
+// Package jsonpath selects values in decoded JSON documents with a JSONPath
+// subset and removes or replaces them in place. Supported syntax: the root
+// $, child names (.name and ['name']), array indexes ([0], [-1]), wildcards
+// (.* and [*]) and recursive descent (..name, ..*).
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	segmentName segmentKind = iota
+	segmentIndex
+	segmentWildcard
+)
+
+type segment struct {
+	name      string
+	kind      segmentKind
+	index     int
+	recursive bool
+}
+
+// Path is a compiled JSONPath expression
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// Parse compiles a JSONPath expression
+func Parse(expr string) (*Path, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("JSONPath %q must start with $", expr)
+	}
+
+	p := &Path{expr: expr}
+	rest := expr[1:]
+	for rest != "" {
+		var seg segment
+		var err error
+		recursive := strings.HasPrefix(rest, "..")
+		switch {
+		case recursive && strings.HasPrefix(rest[2:], "["):
+			seg, rest, err = parseBracket(rest[2:])
+		case recursive:
+			seg, rest = parseDotted(rest[2:])
+		case strings.HasPrefix(rest, "."):
+			seg, rest = parseDotted(rest[1:])
+		case strings.HasPrefix(rest, "["):
+			seg, rest, err = parseBracket(rest)
+		default:
+			err = fmt.Errorf("unexpected %q", rest)
+		}
+		if err == nil && seg.kind == segmentName && seg.name == "" {
+			err = fmt.Errorf("empty member name")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSONPath %q: %w", expr, err)
+		}
+		seg.recursive = recursive
+		p.segments = append(p.segments, seg)
+	}
+	if len(p.segments) == 0 {
+		return nil, fmt.Errorf("JSONPath %q must select something below the root", expr)
+	}
+	return p, nil
+}
+
+// String returns the original expression
+func (p *Path) String() string {
+	return p.expr
+}
+
+func parseDotted(rest string) (segment, string) {
+	end := strings.IndexAny(rest, ".[")
+	if end < 0 {
+		end = len(rest)
+	}
+	name := rest[:end]
+	seg := segment{kind: segmentName, name: name}
+	if name == "*" {
+		seg.kind = segmentWildcard
+	}
+	return seg, rest[end:]
+}
+
+func parseBracket(rest string) (segment, string, error) {
+	end := strings.Index(rest, "]")
+	if end < 0 {
+		return segment{}, "", fmt.Errorf("unterminated [")
+	}
+	inner := strings.TrimSpace(rest[1:end])
+	rest = rest[end+1:]
+
+	switch {
+	case inner == "*":
+		return segment{kind: segmentWildcard}, rest, nil
+	case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+		return segment{kind: segmentName, name: inner[1 : len(inner)-1]}, rest, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return segment{}, "", fmt.Errorf("unsupported selector [%s]", inner)
+	}
+	return segment{kind: segmentIndex, index: index}, rest, nil
+}
+
+// Delete removes every value the path selects from doc and returns the
+// updated document. Maps are modified in place.
+func (p *Path) Delete(doc interface{}) interface{} {
+	result, _ := apply(doc, p.segments, nil, true)
+	return result
+}
+
+// Replace sets every value the path selects to replacement and returns the
+// updated document. Maps are modified in place.
+func (p *Path) Replace(doc, replacement interface{}) interface{} {
+	result, _ := apply(doc, p.segments, replacement, false)
+	return result
+}
+
+// Select returns every value the path selects, leaving doc unchanged
+func (p *Path) Select(doc interface{}) []interface{} {
+	var selected []interface{}
+	collect(doc, p.segments, &selected)
+	return selected
+}
+
+func collect(node interface{}, segments []segment, selected *[]interface{}) {
+	if len(segments) == 0 {
+		*selected = append(*selected, node)
+		return
+	}
+
+	seg, rest := segments[0], segments[1:]
+	switch seg.kind {
+	case segmentName:
+		if object, ok := node.(map[string]interface{}); ok {
+			if child, ok := object[seg.name]; ok {
+				collect(child, rest, selected)
+			}
+		}
+	case segmentIndex:
+		if array, ok := node.([]interface{}); ok {
+			i := seg.index
+			if i < 0 {
+				i += len(array)
+			}
+			if i >= 0 && i < len(array) {
+				collect(array[i], rest, selected)
+			}
+		}
+	default:
+		eachChild(node, func(child interface{}) {
+			collect(child, rest, selected)
+		})
+	}
+
+	if seg.recursive {
+		eachChild(node, func(child interface{}) {
+			collect(child, segments, selected)
+		})
+	}
+}
+
+func eachChild(node interface{}, fn func(interface{})) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for _, child := range n {
+			fn(child)
+		}
+	case []interface{}:
+		for _, child := range n {
+			fn(child)
+		}
+	}
+}
+
+// apply walks node along segments. It returns the new node and whether the
+// node itself should be removed from its parent.
+func apply(node interface{}, segments []segment, replacement interface{}, remove bool) (interface{}, bool) {
+	if len(segments) == 0 {
+		if remove {
+			return nil, true
+		}
+		return replacement, false
+	}
+
+	seg, rest := segments[0], segments[1:]
+	node = applySelector(node, seg, rest, replacement, remove)
+
+	// Recursive descent also applies the same segment to every descendant
+	if seg.recursive {
+		node = mapChildren(node, func(child interface{}) (interface{}, bool) {
+			return apply(child, segments, replacement, remove)
+		})
+	}
+	return node, false
+}
+
+func applySelector(node interface{}, seg segment, rest []segment, replacement interface{}, remove bool) interface{} {
+	switch seg.kind {
+	case segmentName:
+		if object, ok := node.(map[string]interface{}); ok {
+			if child, ok := object[seg.name]; ok {
+				updated, drop := apply(child, rest, replacement, remove)
+				if drop {
+					delete(object, seg.name)
+				} else {
+					object[seg.name] = updated
+				}
+			}
+		}
+		return node
+	case segmentIndex:
+		array, ok := node.([]interface{})
+		if !ok {
+			return node
+		}
+		i := seg.index
+		if i < 0 {
+			i += len(array)
+		}
+		if i < 0 || i >= len(array) {
+			return node
+		}
+		updated, drop := apply(array[i], rest, replacement, remove)
+		if drop {
+			return append(append([]interface{}{}, array[:i]...), array[i+1:]...)
+		}
+		array[i] = updated
+		return array
+	default:
+		return mapChildren(node, func(child interface{}) (interface{}, bool) {
+			return apply(child, rest, replacement, remove)
+		})
+	}
+}
+
+// mapChildren replaces each child of an object or array with fn's result,
+// dropping children fn asks to remove
+func mapChildren(node interface{}, fn func(interface{}) (interface{}, bool)) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, child := range n {
+			updated, drop := fn(child)
+			if drop {
+				delete(n, key)
+			} else {
+				n[key] = updated
+			}
+		}
+		return n
+	case []interface{}:
+		kept := n[:0:0]
+		for _, child := range n {
+			if updated, drop := fn(child); !drop {
+				kept = append(kept, updated)
+			}
+		}
+		return kept
+	}
+	return node
+}
@@ -0,0 +1,378 @@
+// Package jsonschema validates decoded JSON values against the JSON Schema
+// subset MCP servers use to describe tool inputs and outputs: types, enums,
+// object properties, arrays, string and number bounds, composition keywords
+// and local $ref pointers. Unknown keywords are ignored.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// maxRefDepth bounds $ref resolution so recursive schemas cannot loop forever
+const maxRefDepth = 32
+
+// Validate returns every way value violates schema. A nil or empty schema
+// accepts anything.
+func Validate(schema map[string]interface{}, value interface{}) []types.SchemaViolation {
+	v := &validator{root: schema}
+	v.validate(schema, value, "", 0)
+	return v.violations
+}
+
+type validator struct {
+	root       map[string]interface{}
+	violations []types.SchemaViolation
+}
+
+func (v *validator) fail(path, keyword, format string, args ...interface{}) {
+	if path == "" {
+		path = "/"
+	}
+	v.violations = append(v.violations, types.SchemaViolation{
+		Path:    path,
+		Keyword: keyword,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validate(schema map[string]interface{}, value interface{}, path string, depth int) {
+	if len(schema) == 0 {
+		return
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		if depth >= maxRefDepth {
+			v.fail(path, "$ref", "schema reference %q nests too deeply", ref)
+			return
+		}
+		target, err := v.resolve(ref)
+		if err != nil {
+			v.fail(path, "$ref", "%v", err)
+			return
+		}
+		v.validate(target, value, path, depth+1)
+	}
+
+	if !v.checkType(schema, value, path) {
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if equal(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "enum", "must be one of %s", describe(enum))
+		}
+	}
+	if constant, ok := schema["const"]; ok && !equal(constant, value) {
+		v.fail(path, "const", "must equal %s", describe(constant))
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, value, path, depth)
+	case []interface{}:
+		v.validateArray(schema, value, path, depth)
+	case string:
+		v.validateString(schema, value, path)
+	default:
+		if n, ok := toNumber(value); ok {
+			v.validateNumber(schema, n, path)
+		}
+	}
+
+	v.validateComposition(schema, value, path, depth)
+}
+
+// checkType reports whether value matches the schema's type keyword. Further
+// keywords are skipped on a mismatch since they would only add noise.
+func (v *validator) checkType(schema map[string]interface{}, value interface{}, path string) bool {
+	var allowed []string
+	switch t := schema["type"].(type) {
+	case string:
+		allowed = []string{t}
+	case []interface{}:
+		for _, entry := range t {
+			if s, ok := entry.(string); ok {
+				allowed = append(allowed, s)
+			}
+		}
+	default:
+		return true
+	}
+
+	actual := typeOf(value)
+	for _, t := range allowed {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	v.fail(path, "type", "expected %s, got %s", strings.Join(allowed, " or "), actual)
+	return false
+}
+
+func (v *validator) validateObject(schema, object map[string]interface{}, path string, depth int) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, entry := range required {
+			if name, ok := entry.(string); ok {
+				if _, present := object[name]; !present {
+					v.fail(join(path, name), "required", "is required")
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if property, ok := properties[key].(map[string]interface{}); ok {
+			v.validate(property, object[key], join(path, key), depth)
+			continue
+		}
+		if _, declared := properties[key]; declared {
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail(join(path, key), "additionalProperties", "is not an allowed property")
+			}
+		case map[string]interface{}:
+			v.validate(additional, object[key], join(path, key), depth)
+		}
+	}
+
+	if min, ok := toInt(schema["minProperties"]); ok && len(object) < min {
+		v.fail(path, "minProperties", "must have at least %d properties", min)
+	}
+	if max, ok := toInt(schema["maxProperties"]); ok && len(object) > max {
+		v.fail(path, "maxProperties", "must have at most %d properties", max)
+	}
+}
+
+func (v *validator) validateArray(schema map[string]interface{}, array []interface{}, path string, depth int) {
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range array {
+			v.validate(items, item, join(path, strconv.Itoa(i)), depth)
+		}
+	}
+	if min, ok := toInt(schema["minItems"]); ok && len(array) < min {
+		v.fail(path, "minItems", "must have at least %d items", min)
+	}
+	if max, ok := toInt(schema["maxItems"]); ok && len(array) > max {
+		v.fail(path, "maxItems", "must have at most %d items", max)
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := 1; i < len(array); i++ {
+			for j := 0; j < i; j++ {
+				if equal(array[i], array[j]) {
+					v.fail(join(path, strconv.Itoa(i)), "uniqueItems", "duplicates item %d", j)
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) validateString(schema map[string]interface{}, s, path string) {
+	length := utf8.RuneCountInString(s)
+	if min, ok := toInt(schema["minLength"]); ok && length < min {
+		v.fail(path, "minLength", "must be at least %d characters", min)
+	}
+	if max, ok := toInt(schema["maxLength"]); ok && length > max {
+		v.fail(path, "maxLength", "must be at most %d characters", max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(s) {
+			v.fail(path, "pattern", "must match %q", pattern)
+		}
+	}
+}
+
+func (v *validator) validateNumber(schema map[string]interface{}, n float64, path string) {
+	if min, ok := toNumber(schema["minimum"]); ok && n < min {
+		v.fail(path, "minimum", "must be >= %v", min)
+	}
+	if max, ok := toNumber(schema["maximum"]); ok && n > max {
+		v.fail(path, "maximum", "must be <= %v", max)
+	}
+	if min, ok := toNumber(schema["exclusiveMinimum"]); ok && n <= min {
+		v.fail(path, "exclusiveMinimum", "must be > %v", min)
+	}
+	if max, ok := toNumber(schema["exclusiveMaximum"]); ok && n >= max {
+		v.fail(path, "exclusiveMaximum", "must be < %v", max)
+	}
+	if step, ok := toNumber(schema["multipleOf"]); ok && step > 0 {
+		if q := n / step; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(path, "multipleOf", "must be a multiple of %v", step)
+		}
+	}
+}
+
+func (v *validator) validateComposition(schema map[string]interface{}, value interface{}, path string, depth int) {
+	for _, sub := range subschemas(schema["allOf"]) {
+		v.validate(sub, value, path, depth)
+	}
+
+	if anyOf := subschemas(schema["anyOf"]); len(anyOf) > 0 {
+		matched := false
+		for _, sub := range anyOf {
+			if v.matches(sub, value, depth) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "anyOf", "must match at least one allowed schema")
+		}
+	}
+
+	if oneOf := subschemas(schema["oneOf"]); len(oneOf) > 0 {
+		matched := 0
+		for _, sub := range oneOf {
+			if v.matches(sub, value, depth) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.fail(path, "oneOf", "must match exactly one allowed schema, matched %d", matched)
+		}
+	}
+
+	if not, ok := schema["not"].(map[string]interface{}); ok && v.matches(not, value, depth) {
+		v.fail(path, "not", "must not match the excluded schema")
+	}
+}
+
+// matches validates value against schema without recording violations
+func (v *validator) matches(schema map[string]interface{}, value interface{}, depth int) bool {
+	sub := &validator{root: v.root}
+	sub.validate(schema, value, "", depth)
+	return len(sub.violations) == 0
+}
+
+// resolve follows a local JSON pointer such as #/$defs/address
+func (v *validator) resolve(ref string) (map[string]interface{}, error) {
+	if ref == "#" {
+		return v.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported schema reference %q", ref)
+	}
+
+	var node interface{} = v.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("schema reference %q not found", ref)
+		}
+		if node, ok = object[token]; !ok {
+			return nil, fmt.Errorf("schema reference %q not found", ref)
+		}
+	}
+	target, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema reference %q is not a schema", ref)
+	}
+	return target, nil
+}
+
+func subschemas(value interface{}) []map[string]interface{} {
+	list, _ := value.([]interface{})
+	schemas := make([]map[string]interface{}, 0, len(list))
+	for _, entry := range list {
+		if schema, ok := entry.(map[string]interface{}); ok {
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	if n, ok := toNumber(value); ok {
+		if n == math.Trunc(n) && !math.IsInf(n, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return reflect.TypeOf(value).String()
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toInt(value interface{}) (int, bool) {
+	n, ok := toNumber(value)
+	return int(n), ok
+}
+
+// equal compares decoded JSON values, treating all numeric types alike
+func equal(a, b interface{}) bool {
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func describe(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// join appends a JSON pointer token to path
+func join(path, token string) string {
+	token = strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+	return path + "/" + token
+}
@@ -0,0 +1,253 @@
+// Package license verifies signed enterprise license keys offline and
+// enforces the seats and features they entitle.
+package license
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultGracePeriod applies when neither the license nor the configuration
+// sets one
+const DefaultGracePeriod = 14 * 24 * time.Hour
+
+// SeatCounter counts the users that occupy a licensed seat
+type SeatCounter interface {
+	CountSeats(ctx context.Context) (int, error)
+}
+
+// Claims are the JWT claims of a license key. The license ID is the jti and
+// the licensee defaults to the subject.
+type Claims struct {
+	jwt.RegisteredClaims
+	Licensee  string   `json:"licensee,omitempty"`
+	Features  []string `json:"features,omitempty"`
+	Seats     int      `json:"seats,omitempty"`
+	GraceDays int      `json:"grace_days,omitempty"`
+}
+
+// Manager holds the installed license and answers entitlement questions.
+// Verification needs only the vendor public key, so it works air-gapped.
+type Manager struct {
+	license     *types.License
+	seats       SeatCounter
+	now         func() time.Time
+	invalid     string
+	gracePeriod time.Duration
+}
+
+// NewManager verifies key against publicKeyPEM. An empty key runs the
+// gateway in community mode. A key that fails verification is reported
+// through Status rather than stopping the gateway; a malformed public key is
+// a configuration error.
+func NewManager(key, publicKeyPEM string, gracePeriod time.Duration, seats SeatCounter) (*Manager, error) {
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultGracePeriod
+	}
+	m := &Manager{
+		seats:       seats,
+		now:         time.Now,
+		gracePeriod: gracePeriod,
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return m, nil
+	}
+	if strings.TrimSpace(publicKeyPEM) == "" {
+		return nil, errors.New("a license key is configured but no license public key is")
+	}
+	publicKey, err := ParsePublicKey(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid license public key: %w", err)
+	}
+
+	license, err := Verify(key, publicKey)
+	if err != nil {
+		log.Printf("Warning: license key rejected, running in community mode: %v", err)
+		m.invalid = err.Error()
+		return m, nil
+	}
+	m.license = license
+	return m, nil
+}
+
+// ParsePublicKey parses a PEM encoded Ed25519, ECDSA or RSA public key
+func ParsePublicKey(publicKeyPEM string) (interface{}, error) {
+	if key, err := jwt.ParseEdPublicKeyFromPEM([]byte(publicKeyPEM)); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM([]byte(publicKeyPEM)); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicKeyPEM)); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("expected a PEM encoded Ed25519, ECDSA or RSA public key")
+}
+
+// Verify checks the signature of a license key and returns its content.
+// Expiry is not enforced here so that expired licenses can still be
+// reported and honoured during their grace period.
+func Verify(key string, publicKey interface{}) (*types.License, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(key, claims, func(token *jwt.Token) (interface{}, error) {
+		return publicKey, nil
+	},
+		jwt.WithValidMethods([]string{"EdDSA", "ES256", "ES384", "ES512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}),
+		jwt.WithoutClaimsValidation(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("license signature is invalid: %w", err)
+	}
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("license has no expiry")
+	}
+	if claims.Seats < 0 {
+		return nil, errors.New("license seat count is negative")
+	}
+
+	license := &types.License{
+		ID:        claims.ID,
+		Licensee:  claims.Licensee,
+		Issuer:    claims.Issuer,
+		Features:  claims.Features,
+		Seats:     claims.Seats,
+		GraceDays: claims.GraceDays,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if license.Licensee == "" {
+		license.Licensee = claims.Subject
+	}
+	if license.Features == nil {
+		license.Features = []string{}
+	}
+	if claims.IssuedAt != nil {
+		license.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.NotBefore != nil && claims.NotBefore.After(time.Now()) {
+		return nil, errors.New("license is not valid yet")
+	}
+	return license, nil
+}
+
+// Status reports the license state and seat usage
+func (m *Manager) Status(ctx context.Context) *types.LicenseStatus {
+	license, invalid := m.license, m.invalid
+	now := m.now()
+	status := &types.LicenseStatus{
+		CheckedAt:    now,
+		Status:       types.LicenseStatusCommunity,
+		Entitlements: []string{},
+	}
+	if m.seats != nil {
+		used, err := m.seats.CountSeats(ctx)
+		if err != nil {
+			log.Printf("Failed to count licensed seats: %v", err)
+		}
+		status.SeatsUsed = used
+	}
+
+	if invalid != "" {
+		status.Status = types.LicenseStatusInvalid
+		status.Message = invalid
+		return status
+	}
+	if license == nil {
+		return status
+	}
+
+	status.License = license
+	status.SeatsLimit = license.Seats
+	graceEndsAt := license.ExpiresAt.Add(m.gracePeriodFor(license))
+	status.GraceEndsAt = &graceEndsAt
+
+	switch {
+	case now.Before(license.ExpiresAt):
+		status.Status = types.LicenseStatusValid
+		status.DaysRemaining = daysUntil(now, license.ExpiresAt)
+	case now.Before(graceEndsAt):
+		status.Status = types.LicenseStatusGracePeriod
+		status.DaysRemaining = daysUntil(now, graceEndsAt)
+		status.Message = "License expired; enterprise features stay enabled until the grace period ends"
+	default:
+		status.Status = types.LicenseStatusExpired
+		status.Message = "License expired; enterprise features are disabled"
+	}
+	if status.Status != types.LicenseStatusExpired {
+		status.Entitlements = license.Features
+	}
+	return status
+}
+
+// IsEntitled reports whether the license currently grants feature
+func (m *Manager) IsEntitled(feature string) bool {
+	license := m.license
+	if license == nil || !m.now().Before(license.ExpiresAt.Add(m.gracePeriodFor(license))) {
+		return false
+	}
+	for _, f := range license.Features {
+		if f == feature || f == types.LicenseEntitlementAll {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSeatAvailable returns an error when adding another user would exceed
+// the licensed seats. Community deployments and licenses without a seat
+// count are unlimited.
+func (m *Manager) CheckSeatAvailable(ctx context.Context) error {
+	license := m.license
+	if license == nil || license.Seats == 0 || m.seats == nil {
+		return nil
+	}
+	used, err := m.seats.CountSeats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count licensed seats: %w", err)
+	}
+	if used >= license.Seats {
+		return types.NewLicenseSeatsExceededError(license.Seats)
+	}
+	return nil
+}
+
+func (m *Manager) gracePeriodFor(license *types.License) time.Duration {
+	if license.GraceDays > 0 {
+		return time.Duration(license.GraceDays) * 24 * time.Hour
+	}
+	return m.gracePeriod
+}
+
+func daysUntil(now, t time.Time) int {
+	return int(math.Ceil(t.Sub(now).Hours() / 24))
+}
+
+// DBSeatCounter counts active users other than service accounts
+type DBSeatCounter struct {
+	db *sql.DB
+}
+
+// NewDBSeatCounter creates a seat counter over the users table
+func NewDBSeatCounter(db *sql.DB) *DBSeatCounter {
+	return &DBSeatCounter{db: db}
+}
+
+// CountSeats returns the number of active non-service users
+func (c *DBSeatCounter) CountSeats(ctx context.Context) (int, error) {
+	var count int
+	err := c.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM users WHERE is_active = true AND role <> 'service' AND account_type = 'human'
+	`).Scan(&count)
+	return count, err
+}
@@ -2,12 +2,24 @@ package logging
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 )
 
-// AuditService handles audit trail functionality
+// auditLogColumns are the columns read back into a types.AuditLog
+const auditLogColumns = `id, organization_id, action, resource_type, actor_id,
+	resource_id::text, host(actor_ip), metadata, severity, sequence, prev_hash, hash, created_at`
+
+// AuditService handles audit trail functionality. Records are appended to a
+// per-organization hash chain so that modifying or deleting history can be
+// detected by VerifyChain.
 type AuditService struct {
 	db *sql.DB
 }
@@ -37,33 +49,234 @@ func (a *AuditService) LogUserAction(userID, orgID, action, resource, resourceID
 	return a.LogAudit(audit)
 }
 
-// LogAudit stores an audit log entry
+// LogAudit stores an audit log entry at the head of its organization's chain.
+// The record's Timestamp, Sequence and hashes are set when it is appended.
 func (a *AuditService) LogAudit(audit *types.AuditLog) error {
-	// TODO: Implement audit log storage
-	// Insert into audit_logs table
-	// Handle sensitive data masking
-	// Ensure compliance with audit requirements
+	if audit.OrganizationID == "" {
+		return fmt.Errorf("audit log requires an organization")
+	}
+	if audit.Severity == "" {
+		audit.Severity = types.AuditSeverityFor(audit.Action, audit.Resource, audit.Success)
+	}
+	if !types.IsValidAuditSeverity(audit.Severity) {
+		return fmt.Errorf("invalid audit severity: %s", audit.Severity)
+	}
+	if audit.ID == "" {
+		audit.ID = uuid.New().String()
+	}
+	// Store exactly what is hashed so the record verifies after a round trip
+	details, err := normalizeAuditDetails(audit.Details)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit details: %w", err)
+	}
+	audit.Details = details
+
+	var actorIP interface{}
+	if ip := net.ParseIP(audit.RemoteIP); ip != nil {
+		audit.RemoteIP = ip.String()
+		actorIP = audit.RemoteIP
+	}
+
+	// resource_id is a UUID column; other identifiers are kept in metadata only
+	var resourceID interface{}
+	if _, err := uuid.Parse(audit.ResourceID); err == nil {
+		resourceID = audit.ResourceID
+	}
+
+	metadata := map[string]interface{}{
+		"resource_id": audit.ResourceID,
+		"remote_ip":   audit.RemoteIP,
+		"user_agent":  audit.UserAgent,
+		"success":     audit.Success,
+	}
+	if audit.Details != nil {
+		metadata["details"] = audit.Details
+	}
+	if audit.Error != "" {
+		metadata["error"] = audit.Error
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit metadata: %w", err)
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin audit transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockAuditChain(tx, audit.OrganizationID); err != nil {
+		return err
+	}
+
+	sequence, prevHash, err := chainHead(tx, audit.OrganizationID)
+	if err != nil {
+		return err
+	}
+	// Stamp the time under the lock so created_at follows sequence order,
+	// which keeps time-based retention from punching holes in the chain
+	audit.Timestamp = time.Now().UTC().Truncate(time.Microsecond)
+	audit.Sequence = sequence + 1
+	audit.PrevHash = prevHash
+	audit.Hash = ComputeAuditHash(audit)
+
+	query := `
+		INSERT INTO audit_logs (
+			id, organization_id, action, resource_type, resource_id,
+			actor_id, actor_ip, metadata, severity, sequence, prev_hash, hash, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err = tx.Exec(query,
+		audit.ID, audit.OrganizationID, audit.Action, audit.Resource, resourceID,
+		audit.UserID, actorIP, string(metadataJSON), audit.Severity,
+		audit.Sequence, audit.PrevHash, audit.Hash, audit.Timestamp,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit log: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// lockAuditChain serializes appends to an organization's chain for the
+// duration of the transaction
+func lockAuditChain(tx *sql.Tx, orgID string) error {
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, "audit_chain:"+orgID); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
 	return nil
 }
 
+// chainHead returns the sequence and hash of the latest chained record. If
+// retention has removed every record, the chain continues from the last anchor.
+func chainHead(tx *sql.Tx, orgID string) (int64, string, error) {
+	var sequence int64
+	var hash string
+
+	err := tx.QueryRow(`
+		SELECT sequence, hash FROM audit_logs
+		WHERE organization_id = $1 AND sequence IS NOT NULL
+		ORDER BY sequence DESC LIMIT 1
+	`, orgID).Scan(&sequence, &hash)
+	if err == nil {
+		return sequence, hash, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, "", fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+
+	err = tx.QueryRow(`
+		SELECT sequence, record_hash FROM audit_anchors
+		WHERE organization_id = $1
+		ORDER BY sequence DESC LIMIT 1
+	`, orgID).Scan(&sequence, &hash)
+	if err == sql.ErrNoRows {
+		return 0, types.AuditGenesisHash, nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read audit anchor head: %w", err)
+	}
+	return sequence, hash, nil
+}
+
+// normalizeAuditDetails round-trips details through JSON so the hashed
+// values match what is read back from JSONB
+func normalizeAuditDetails(details map[string]interface{}) (map[string]interface{}, error) {
+	if details == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// scanAuditLog reads a row selected with auditLogColumns
+func scanAuditLog(row interface{ Scan(...interface{}) error }) (*types.AuditLog, error) {
+	audit := &types.AuditLog{}
+	var resourceID, actorIP, prevHash, hash sql.NullString
+	var sequence sql.NullInt64
+	var metadataJSON []byte
+
+	err := row.Scan(
+		&audit.ID, &audit.OrganizationID, &audit.Action, &audit.Resource, &audit.UserID,
+		&resourceID, &actorIP, &metadataJSON, &audit.Severity, &sequence, &prevHash, &hash,
+		&audit.Timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.ResourceID = resourceID.String
+	audit.RemoteIP = actorIP.String
+	audit.Sequence = sequence.Int64
+	audit.PrevHash = prevHash.String
+	audit.Hash = hash.String
+
+	var metadata map[string]interface{}
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode audit metadata: %w", err)
+		}
+	}
+	if v, ok := metadata["resource_id"].(string); ok {
+		audit.ResourceID = v
+	}
+	if v, ok := metadata["remote_ip"].(string); ok {
+		audit.RemoteIP = v
+	}
+	if v, ok := metadata["user_agent"].(string); ok {
+		audit.UserAgent = v
+	}
+	if v, ok := metadata["success"].(bool); ok {
+		audit.Success = v
+	}
+	if v, ok := metadata["error"].(string); ok {
+		audit.Error = v
+	}
+	if v, ok := metadata["details"].(map[string]interface{}); ok {
+		audit.Details = v
+	}
+
+	return audit, nil
+}
+
 // GetAuditTrail retrieves audit trail for a resource
 func (a *AuditService) GetAuditTrail(resource, resourceID string, limit, offset int) ([]*types.AuditLog, error) {
-	// TODO: Implement audit trail retrieval
-	// Query audit logs for specific resource
-	// Return chronological order
-	return nil, nil
+	return a.QueryAuditLogs(&types.AuditLogQuery{
+		Resource:   resource,
+		ResourceID: resourceID,
+		Limit:      limit,
+		Offset:     offset,
+	})
 }
 
 // GetUserAuditLogs retrieves audit logs for a specific user
 func (a *AuditService) GetUserAuditLogs(userID string, startTime, endTime time.Time, limit, offset int) ([]*types.AuditLog, error) {
-	// TODO: Implement user audit log retrieval
-	return nil, nil
+	return a.QueryAuditLogs(&types.AuditLogQuery{
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		UserID:    userID,
+		Limit:     limit,
+		Offset:    offset,
+	})
 }
 
 // GetOrganizationAuditLogs retrieves audit logs for an organization
 func (a *AuditService) GetOrganizationAuditLogs(orgID string, startTime, endTime time.Time, limit, offset int) ([]*types.AuditLog, error) {
-	// TODO: Implement organization audit log retrieval
-	return nil, nil
+	return a.QueryAuditLogs(&types.AuditLogQuery{
+		StartTime:      &startTime,
+		EndTime:        &endTime,
+		OrganizationID: orgID,
+		Limit:          limit,
+		Offset:         offset,
+	})
 }
 
 // GetFailedActions retrieves failed actions for security monitoring
@@ -90,10 +303,97 @@ func (a *AuditService) GetAuditStats(orgID string, startTime, endTime time.Time)
 
 // SearchAuditLogs searches audit logs with filters
 func (a *AuditService) SearchAuditLogs(orgID, userID, action, resource string, startTime, endTime time.Time, limit, offset int) ([]*types.AuditLog, error) {
-	// TODO: Implement audit log search
-	// Support multiple filter criteria
-	// Return paginated results
-	return nil, nil
+	return a.QueryAuditLogs(&types.AuditLogQuery{
+		StartTime:      &startTime,
+		EndTime:        &endTime,
+		OrganizationID: orgID,
+		UserID:         userID,
+		Action:         action,
+		Resource:       resource,
+		Limit:          limit,
+		Offset:         offset,
+	})
+}
+
+// QueryAuditLogs lists audit logs matching the query, newest first
+func (a *AuditService) QueryAuditLogs(query *types.AuditLogQuery) ([]*types.AuditLog, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	addCondition := func(clause string, value interface{}) {
+		conditions = append(conditions, fmt.Sprintf(clause, argIndex))
+		args = append(args, value)
+		argIndex++
+	}
+
+	if query.OrganizationID != "" {
+		addCondition("organization_id = $%d", query.OrganizationID)
+	}
+	if query.UserID != "" {
+		addCondition("actor_id = $%d", query.UserID)
+	}
+	if query.Action != "" {
+		addCondition("action = $%d", query.Action)
+	}
+	if query.Resource != "" {
+		addCondition("resource_type = $%d", query.Resource)
+	}
+	if query.ResourceID != "" {
+		addCondition("COALESCE(metadata->>'resource_id', resource_id::text) = $%d", query.ResourceID)
+	}
+	if query.StartTime != nil && !query.StartTime.IsZero() {
+		addCondition("created_at >= $%d", *query.StartTime)
+	}
+	if query.EndTime != nil && !query.EndTime.IsZero() {
+		addCondition("created_at <= $%d", *query.EndTime)
+	}
+	if query.MinSeverity != "" {
+		rank := types.AuditSeverityRank(query.MinSeverity)
+		if rank < 0 {
+			return nil, fmt.Errorf("invalid audit severity: %s", query.MinSeverity)
+		}
+		var severities []string
+		for _, severity := range []string{
+			types.AuditSeverityInfo, types.AuditSeverityNotice,
+			types.AuditSeverityWarning, types.AuditSeverityCritical,
+		} {
+			if types.AuditSeverityRank(severity) >= rank {
+				severities = append(severities, "'"+severity+"'")
+			}
+		}
+		conditions = append(conditions, "severity IN ("+strings.Join(severities, ", ")+")")
+	}
+
+	sqlQuery := "SELECT " + auditLogColumns + " FROM audit_logs"
+	if len(conditions) > 0 {
+		sqlQuery += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	sqlQuery += " ORDER BY created_at DESC"
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	sqlQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, limit, query.Offset)
+
+	rows, err := a.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var audits []*types.AuditLog
+	for rows.Next() {
+		audit, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		audits = append(audits, audit)
+	}
+
+	return audits, rows.Err()
 }
 
 // CleanupOldAudits removes old audit logs based on retention policy
@@ -113,11 +413,187 @@ func (a *AuditService) ExportAuditLogs(orgID string, startTime, endTime time.Tim
 	return nil, nil
 }
 
-// ValidateAuditIntegrity checks audit log integrity
-func (a *AuditService) ValidateAuditIntegrity(orgID string, startTime, endTime time.Time) error {
-	// TODO: Implement audit integrity validation
-	// Check for missing logs, tampering
-	// Validate chronological order
-	// Check digital signatures if implemented
+// ValidateAuditIntegrity checks an organization's audit chain and returns
+// an error describing the first failure if it has been tampered with
+func (a *AuditService) ValidateAuditIntegrity(orgID string) error {
+	report, err := a.VerifyChain(orgID)
+	if err != nil {
+		return err
+	}
+	if !report.Valid {
+		failure := report.Failures[0]
+		return fmt.Errorf("audit chain invalid at sequence %d: %s", failure.Sequence, failure.Reason)
+	}
 	return nil
 }
+
+// VerifyChain recomputes every chained record's hash, checks the links
+// between records and compares the chain against its anchors
+func (a *AuditService) VerifyChain(orgID string) (*types.AuditChainReport, error) {
+	anchors, err := a.ListAnchors(orgID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier := NewAuditChainVerifier(orgID, anchors)
+
+	rows, err := a.db.Query(
+		"SELECT "+auditLogColumns+` FROM audit_logs
+		WHERE organization_id = $1 AND sequence IS NOT NULL
+		ORDER BY sequence ASC`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit chain: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		audit, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		verifier.Add(audit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit chain: %w", err)
+	}
+
+	return verifier.Finish(), nil
+}
+
+// ChainOrganizations returns the organizations that have chained audit records
+func (a *AuditService) ChainOrganizations() ([]string, error) {
+	rows, err := a.db.Query(`
+		SELECT DISTINCT organization_id FROM audit_logs WHERE sequence IS NOT NULL
+		UNION
+		SELECT DISTINCT organization_id FROM audit_anchors
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit chains: %w", err)
+	}
+	defer rows.Close()
+
+	var orgIDs []string
+	for rows.Next() {
+		var orgID string
+		if err := rows.Scan(&orgID); err != nil {
+			return nil, err
+		}
+		orgIDs = append(orgIDs, orgID)
+	}
+	return orgIDs, rows.Err()
+}
+
+// CreateAnchor records a digest of the organization's current chain head.
+// It returns nil when the chain has not advanced since the last anchor.
+func (a *AuditService) CreateAnchor(orgID string) (*types.AuditAnchor, error) {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin anchor transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockAuditChain(tx, orgID); err != nil {
+		return nil, err
+	}
+
+	anchor := &types.AuditAnchor{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		PrevDigest:     types.AuditGenesisHash,
+		CreatedAt:      time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	err = tx.QueryRow(`
+		SELECT sequence, hash FROM audit_logs
+		WHERE organization_id = $1 AND sequence IS NOT NULL
+		ORDER BY sequence DESC LIMIT 1
+	`, orgID).Scan(&anchor.Sequence, &anchor.RecordHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+
+	var lastSequence int64
+	err = tx.QueryRow(`
+		SELECT sequence, digest FROM audit_anchors
+		WHERE organization_id = $1
+		ORDER BY sequence DESC LIMIT 1
+	`, orgID).Scan(&lastSequence, &anchor.PrevDigest)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to read last audit anchor: %w", err)
+	}
+	if err == nil && lastSequence >= anchor.Sequence {
+		return nil, nil
+	}
+
+	anchor.Digest = ComputeAnchorDigest(anchor)
+
+	_, err = tx.Exec(`
+		INSERT INTO audit_anchors (id, organization_id, sequence, record_hash, prev_digest, digest, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, anchor.ID, anchor.OrganizationID, anchor.Sequence, anchor.RecordHash,
+		anchor.PrevDigest, anchor.Digest, anchor.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert audit anchor: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return anchor, nil
+}
+
+// CreateAnchors anchors every organization whose chain has advanced
+func (a *AuditService) CreateAnchors() ([]*types.AuditAnchor, error) {
+	orgIDs, err := a.ChainOrganizations()
+	if err != nil {
+		return nil, err
+	}
+
+	var anchors []*types.AuditAnchor
+	for _, orgID := range orgIDs {
+		anchor, err := a.CreateAnchor(orgID)
+		if err != nil {
+			return anchors, fmt.Errorf("failed to anchor organization %s: %w", orgID, err)
+		}
+		if anchor != nil {
+			anchors = append(anchors, anchor)
+		}
+	}
+	return anchors, nil
+}
+
+// ListAnchors returns an organization's anchors, newest first. A limit of
+// zero returns all anchors.
+func (a *AuditService) ListAnchors(orgID string, limit int) ([]*types.AuditAnchor, error) {
+	query := `
+		SELECT id, organization_id, sequence, record_hash, prev_digest, digest, created_at
+		FROM audit_anchors
+		WHERE organization_id = $1
+		ORDER BY sequence DESC
+	`
+	args := []interface{}{orgID}
+	if limit > 0 {
+		query += " LIMIT $2"
+		args = append(args, limit)
+	}
+
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit anchors: %w", err)
+	}
+	defer rows.Close()
+
+	var anchors []*types.AuditAnchor
+	for rows.Next() {
+		anchor := &types.AuditAnchor{}
+		if err := rows.Scan(&anchor.ID, &anchor.OrganizationID, &anchor.Sequence,
+			&anchor.RecordHash, &anchor.PrevDigest, &anchor.Digest, &anchor.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit anchor: %w", err)
+		}
+		anchors = append(anchors, anchor)
+	}
+	return anchors, rows.Err()
+}
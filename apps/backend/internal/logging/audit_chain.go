@@ -0,0 +1,189 @@
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// maxChainFailures bounds the number of failures collected in one report
+const maxChainFailures = 100
+
+// auditHashPayload is the canonical form of an audit record used for hashing.
+// Field order is fixed by the struct and map keys are sorted by encoding/json.
+type auditHashPayload struct {
+	Details        map[string]interface{} `json:"details"`
+	PrevHash       string                 `json:"prev_hash"`
+	OrganizationID string                 `json:"organization_id"`
+	Timestamp      string                 `json:"timestamp"`
+	Severity       string                 `json:"severity"`
+	Action         string                 `json:"action"`
+	Resource       string                 `json:"resource"`
+	ResourceID     string                 `json:"resource_id"`
+	UserID         string                 `json:"user_id"`
+	RemoteIP       string                 `json:"remote_ip"`
+	UserAgent      string                 `json:"user_agent"`
+	Error          string                 `json:"error"`
+	Sequence       int64                  `json:"sequence"`
+	Success        bool                   `json:"success"`
+}
+
+// ComputeAuditHash returns the SHA-256 chain hash of an audit record,
+// covering its content, sequence number and the previous record's hash
+func ComputeAuditHash(audit *types.AuditLog) string {
+	payload := auditHashPayload{
+		Details:        audit.Details,
+		PrevHash:       audit.PrevHash,
+		OrganizationID: audit.OrganizationID,
+		Timestamp:      canonicalTime(audit.Timestamp),
+		Severity:       audit.Severity,
+		Action:         audit.Action,
+		Resource:       audit.Resource,
+		ResourceID:     audit.ResourceID,
+		UserID:         audit.UserID,
+		RemoteIP:       audit.RemoteIP,
+		UserAgent:      audit.UserAgent,
+		Error:          audit.Error,
+		Sequence:       audit.Sequence,
+		Success:        audit.Success,
+	}
+
+	data, _ := json.Marshal(payload)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ComputeAnchorDigest returns the digest of an anchor, chained to the previous anchor
+func ComputeAnchorDigest(anchor *types.AuditAnchor) string {
+	h := sha256.New()
+	for _, part := range []string{
+		anchor.PrevDigest,
+		anchor.OrganizationID,
+		strconv.FormatInt(anchor.Sequence, 10),
+		anchor.RecordHash,
+		canonicalTime(anchor.CreatedAt),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonicalTime renders timestamps at the microsecond precision Postgres stores
+func canonicalTime(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
+
+// AuditChainVerifier checks an organization's audit chain record by record,
+// so large chains can be streamed rather than loaded at once
+type AuditChainVerifier struct {
+	report   *types.AuditChainReport
+	anchors  map[int64]*types.AuditAnchor
+	prevHash string
+	prevSeq  int64
+	started  bool
+}
+
+// NewAuditChainVerifier creates a verifier for an organization. Anchors are
+// checked against each other immediately and against records as they are added.
+func NewAuditChainVerifier(orgID string, anchors []*types.AuditAnchor) *AuditChainVerifier {
+	v := &AuditChainVerifier{
+		report: &types.AuditChainReport{
+			VerifiedAt:     time.Now(),
+			OrganizationID: orgID,
+		},
+		anchors: make(map[int64]*types.AuditAnchor, len(anchors)),
+	}
+
+	sorted := append([]*types.AuditAnchor(nil), anchors...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Sequence < sorted[j].Sequence })
+
+	prevDigest := types.AuditGenesisHash
+	for _, anchor := range sorted {
+		v.report.AnchorsChecked++
+		if anchor.PrevDigest != prevDigest {
+			v.fail(types.AuditChainFailure{AnchorID: anchor.ID, Sequence: anchor.Sequence, Reason: "anchor does not link to previous anchor"})
+		}
+		if ComputeAnchorDigest(anchor) != anchor.Digest {
+			v.fail(types.AuditChainFailure{AnchorID: anchor.ID, Sequence: anchor.Sequence, Reason: "anchor digest mismatch"})
+		}
+		prevDigest = anchor.Digest
+		v.anchors[anchor.Sequence] = anchor
+	}
+
+	return v
+}
+
+// Add verifies the next record in sequence order
+func (v *AuditChainVerifier) Add(audit *types.AuditLog) {
+	v.report.RecordsChecked++
+
+	fail := func(reason string) {
+		v.fail(types.AuditChainFailure{RecordID: audit.ID, Sequence: audit.Sequence, Reason: reason})
+	}
+
+	switch {
+	case !v.started:
+		// The chain may start after sequence 1 once old records are
+		// removed by retention; only a chain from 1 can be checked to genesis.
+		if audit.Sequence == 1 && audit.PrevHash != types.AuditGenesisHash {
+			fail("first record does not link to genesis")
+		}
+	case audit.Sequence != v.prevSeq+1:
+		fail(fmt.Sprintf("sequence gap: expected %d", v.prevSeq+1))
+	case audit.PrevHash != v.prevHash:
+		fail("previous hash does not match preceding record")
+	}
+
+	if ComputeAuditHash(audit) != audit.Hash {
+		fail("record hash mismatch")
+	}
+
+	if anchor, ok := v.anchors[audit.Sequence]; ok {
+		if anchor.RecordHash != audit.Hash {
+			fail("record does not match anchor " + anchor.ID)
+		}
+		delete(v.anchors, audit.Sequence)
+	}
+
+	v.started = true
+	v.prevSeq = audit.Sequence
+	v.prevHash = audit.Hash
+}
+
+// Finish completes verification and returns the report
+func (v *AuditChainVerifier) Finish() *types.AuditChainReport {
+	remaining := make([]*types.AuditAnchor, 0, len(v.anchors))
+	for _, anchor := range v.anchors {
+		remaining = append(remaining, anchor)
+	}
+	sort.Slice(remaining, func(i, j int) bool { return remaining[i].Sequence < remaining[j].Sequence })
+
+	firstSeq := v.prevSeq - int64(v.report.RecordsChecked) + 1
+	for _, anchor := range remaining {
+		// Anchors for records removed by retention cannot be checked
+		if v.started && anchor.Sequence < firstSeq {
+			continue
+		}
+		v.fail(types.AuditChainFailure{AnchorID: anchor.ID, Sequence: anchor.Sequence, Reason: "anchored record is missing"})
+	}
+
+	v.report.HeadSequence = v.prevSeq
+	v.report.HeadHash = v.prevHash
+	v.report.Valid = len(v.report.Failures) == 0
+	return v.report
+}
+
+func (v *AuditChainVerifier) fail(failure types.AuditChainFailure) {
+	if len(v.report.Failures) < maxChainFailures {
+		v.report.Failures = append(v.report.Failures, failure)
+	}
+	// Record that the chain is broken even when the failure list is full
+	v.report.Valid = false
+}
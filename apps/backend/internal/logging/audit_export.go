@@ -0,0 +1,464 @@
+package logging
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	defaultAuditSinkBatchSize = 100
+	maxAuditSinkBatchSize     = 1000
+	// auditSinkLease holds a claimed sink back from other workers while it
+	// is delivered to
+	auditSinkLease = 5 * time.Minute
+	// maxAuditSinkBatchesPerRun bounds how far one run catches a sink up,
+	// so that a large backlog does not starve the other sinks
+	maxAuditSinkBatchesPerRun = 10
+	auditSinkBaseBackoff      = 30 * time.Second
+	auditSinkMaxBackoff       = time.Hour
+	auditSinkTimeout          = 10 * time.Second
+	// defaultSyslogFacility is "log audit"
+	defaultSyslogFacility = 13
+	defaultSyslogAppName  = "omnimesh-gateway"
+)
+
+var kafkaTopicPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+
+// AuditSinkStore persists audit sinks and reads the chained audit records
+// they deliver
+type AuditSinkStore interface {
+	CreateSink(sink *types.AuditSink) error
+	GetSink(orgID, id string) (*types.AuditSink, error)
+	ListSinks(orgID string) ([]*types.AuditSink, error)
+	UpdateSink(sink *types.AuditSink, resetBackoff bool) error
+	DeleteSink(orgID, id string) (bool, error)
+	// ChainHead returns the sequence of the organization's latest record
+	ChainHead(orgID string) (int64, error)
+	// ClaimDueSinks leases up to limit enabled sinks that are not backing
+	// off until leaseUntil
+	ClaimDueSinks(now, leaseUntil time.Time, limit int) ([]*types.AuditSink, error)
+	// ListChainedAudits returns up to limit records of the organization's
+	// chain after sequence, in chain order
+	ListChainedAudits(orgID string, after int64, limit int) ([]*types.AuditLog, error)
+	// AdvanceSink records that the records up to cursor were handled
+	AdvanceSink(id string, cursor int64, deliveredAt *time.Time) error
+	// ReleaseSink ends a sink's lease, scheduling its next attempt after
+	// failures
+	ReleaseSink(id string, failures int, nextAttemptAt *time.Time, lastError string) error
+}
+
+// EgressPolicy decides which external destinations the gateway may reach
+type EgressPolicy interface {
+	CheckURL(feature, rawURL string) error
+}
+
+// AuditExportService streams audit records to per-organization SIEM sinks:
+// syslog servers, HTTPS webhooks and Kafka topics through a REST proxy.
+// Records are read from the audit chain, so every record that was stored
+// is exported, whichever service audited it.
+type AuditExportService struct {
+	store    AuditSinkStore
+	egress   EgressPolicy
+	client   *http.Client
+	dialer   *net.Dialer
+	now      func() time.Time
+	hostname string
+}
+
+// NewAuditExportService creates a database-backed audit export service
+func NewAuditExportService(db *sql.DB) *AuditExportService {
+	return NewAuditExportServiceWithStore(newAuditSinkStore(db))
+}
+
+// NewAuditExportServiceWithStore creates an audit export service over store
+func NewAuditExportServiceWithStore(store AuditSinkStore) *AuditExportService {
+	return &AuditExportService{
+		store:    store,
+		client:   &http.Client{Timeout: auditSinkTimeout},
+		dialer:   &net.Dialer{Timeout: auditSinkTimeout},
+		now:      time.Now,
+		hostname: syslogHostname(),
+	}
+}
+
+// SetEgressPolicy restricts the destinations sinks may use
+func (s *AuditExportService) SetEgressPolicy(policy EgressPolicy) {
+	s.egress = policy
+}
+
+// SetHTTPClient replaces the client webhooks and Kafka proxies are called
+// with
+func (s *AuditExportService) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// ListSinks returns the organization's sinks
+func (s *AuditExportService) ListSinks(ctx context.Context, orgID string) ([]*types.AuditSink, error) {
+	sinks, err := s.store.ListSinks(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit sinks: %w", err)
+	}
+	return sinks, nil
+}
+
+// GetSink returns one of the organization's sinks
+func (s *AuditExportService) GetSink(ctx context.Context, orgID, id string) (*types.AuditSink, error) {
+	sink, err := s.store.GetSink(orgID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit sink: %w", err)
+	}
+	if sink == nil {
+		return nil, types.NewNotFoundError("Audit sink not found")
+	}
+	return sink, nil
+}
+
+// CreateSink adds a sink to the organization
+func (s *AuditExportService) CreateSink(ctx context.Context, orgID, userID string, req *types.CreateAuditSinkRequest) (*types.AuditSink, error) {
+	sink := &types.AuditSink{
+		OrganizationID: orgID,
+		Name:           strings.TrimSpace(req.Name),
+		Type:           req.Type,
+		Format:         req.Format,
+		MinSeverity:    req.MinSeverity,
+		Config:         req.Config,
+		Secret:         req.Secret,
+		BatchSize:      req.BatchSize,
+		Enabled:        req.Enabled == nil || *req.Enabled,
+		CreatedBy:      userID,
+	}
+	if err := s.validate(sink); err != nil {
+		return nil, err
+	}
+	if err := s.checkName(orgID, "", sink.Name); err != nil {
+		return nil, err
+	}
+
+	if !req.Backfill {
+		head, err := s.store.ChainHead(orgID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit chain head: %w", err)
+		}
+		sink.CursorSequence = head
+	}
+
+	if err := s.store.CreateSink(sink); err != nil {
+		return nil, fmt.Errorf("failed to create audit sink: %w", err)
+	}
+	describeSecret(sink)
+	return sink, nil
+}
+
+// UpdateSink changes one of the organization's sinks. A sink whose
+// destination changed or that is enabled again is retried right away.
+func (s *AuditExportService) UpdateSink(ctx context.Context, orgID, id string, req *types.UpdateAuditSinkRequest) (*types.AuditSink, error) {
+	sink, err := s.GetSink(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resetBackoff := false
+	if req.Name != nil {
+		sink.Name = strings.TrimSpace(*req.Name)
+		if err := s.checkName(orgID, sink.ID, sink.Name); err != nil {
+			return nil, err
+		}
+	}
+	if req.Config != nil {
+		sink.Config = *req.Config
+		resetBackoff = true
+	}
+	if req.Format != nil {
+		sink.Format = *req.Format
+	}
+	if req.MinSeverity != nil {
+		sink.MinSeverity = *req.MinSeverity
+	}
+	if req.Secret != nil {
+		sink.Secret = *req.Secret
+		resetBackoff = true
+	}
+	if req.BatchSize != nil {
+		sink.BatchSize = *req.BatchSize
+	}
+	if req.Enabled != nil {
+		resetBackoff = resetBackoff || (*req.Enabled && !sink.Enabled)
+		sink.Enabled = *req.Enabled
+	}
+	if err := s.validate(sink); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpdateSink(sink, resetBackoff); err != nil {
+		return nil, fmt.Errorf("failed to update audit sink: %w", err)
+	}
+	if resetBackoff {
+		sink.Failures = 0
+		sink.NextAttemptAt = nil
+	}
+	describeSecret(sink)
+	return sink, nil
+}
+
+// DeleteSink removes one of the organization's sinks
+func (s *AuditExportService) DeleteSink(ctx context.Context, orgID, id string) error {
+	deleted, err := s.store.DeleteSink(orgID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete audit sink: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("Audit sink not found")
+	}
+	return nil
+}
+
+// TestSink sends a synthetic record to one of the organization's sinks and
+// reports whether it was delivered. The sink's cursor is not moved.
+func (s *AuditExportService) TestSink(ctx context.Context, orgID, id, userID string) (*types.AuditSinkTestResult, error) {
+	sink, err := s.GetSink(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &types.AuditLog{
+		Timestamp:      s.now().UTC(),
+		ID:             "test",
+		UserID:         userID,
+		OrganizationID: orgID,
+		Action:         "test",
+		Resource:       "audit-sink",
+		ResourceID:     sink.ID,
+		Severity:       types.AuditSeverityInfo,
+		Details:        map[string]interface{}{"message": "Test record from Omnimesh Gateway"},
+		Success:        true,
+	}
+	result := &types.AuditSinkTestResult{SinkID: sink.ID, Delivered: true}
+	if err := s.send(ctx, sink, []*types.AuditLog{event}); err != nil {
+		result.Delivered = false
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
+// DeliverPending delivers the records audited since each due sink's last
+// delivery and returns how many records were delivered. A sink whose
+// delivery fails backs off and is retried from the same record; a failure
+// for one sink does not hold back the others.
+func (s *AuditExportService) DeliverPending(ctx context.Context, limit int) (int, error) {
+	now := s.now()
+	sinks, err := s.store.ClaimDueSinks(now, now.Add(auditSinkLease), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim audit sinks: %w", err)
+	}
+
+	delivered := 0
+	for _, sink := range sinks {
+		n, deliverErr := s.deliver(ctx, sink)
+		delivered += n
+
+		failures, lastError := 0, ""
+		var nextAttemptAt *time.Time
+		if deliverErr != nil {
+			failures = sink.Failures + 1
+			lastError = deliverErr.Error()
+			next := s.now().Add(AuditSinkBackoff(failures))
+			nextAttemptAt = &next
+			log.Printf("Failed to deliver audit records to sink %s of organization %s (attempt %d): %v",
+				sink.ID, sink.OrganizationID, failures, deliverErr)
+		}
+		if err := s.store.ReleaseSink(sink.ID, failures, nextAttemptAt, lastError); err != nil {
+			log.Printf("Failed to release audit sink %s: %v", sink.ID, err)
+		}
+	}
+	return delivered, nil
+}
+
+// deliver sends a sink the batches of records after its cursor, moving the
+// cursor after each batch
+func (s *AuditExportService) deliver(ctx context.Context, sink *types.AuditSink) (int, error) {
+	batchSize := sink.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultAuditSinkBatchSize
+	}
+	minRank := types.AuditSeverityRank(sink.MinSeverity)
+
+	delivered := 0
+	for i := 0; i < maxAuditSinkBatchesPerRun; i++ {
+		records, err := s.store.ListChainedAudits(sink.OrganizationID, sink.CursorSequence, batchSize)
+		if err != nil {
+			return delivered, fmt.Errorf("failed to read audit records: %w", err)
+		}
+		if len(records) == 0 {
+			return delivered, nil
+		}
+
+		batch := make([]*types.AuditLog, 0, len(records))
+		for _, record := range records {
+			if types.AuditSeverityRank(record.Severity) >= minRank {
+				batch = append(batch, record)
+			}
+		}
+		var deliveredAt *time.Time
+		if len(batch) > 0 {
+			if err := s.send(ctx, sink, batch); err != nil {
+				return delivered, err
+			}
+			now := s.now()
+			deliveredAt = &now
+			delivered += len(batch)
+		}
+
+		// Records below the sink's severity are skipped, not retried
+		cursor := records[len(records)-1].Sequence
+		if err := s.store.AdvanceSink(sink.ID, cursor, deliveredAt); err != nil {
+			return delivered, fmt.Errorf("failed to record audit sink progress: %w", err)
+		}
+		sink.CursorSequence = cursor
+		if len(records) < batchSize {
+			return delivered, nil
+		}
+	}
+	return delivered, nil
+}
+
+// send writes one batch to the sink's destination
+func (s *AuditExportService) send(ctx context.Context, sink *types.AuditSink, events []*types.AuditLog) error {
+	if err := s.checkEgress(sink); err != nil {
+		return err
+	}
+	switch sink.Type {
+	case types.AuditSinkTypeSyslog:
+		return s.sendSyslog(ctx, sink, events)
+	case types.AuditSinkTypeWebhook:
+		return s.sendWebhook(ctx, sink, events)
+	case types.AuditSinkTypeKafka:
+		return s.sendKafka(ctx, sink, events)
+	default:
+		return fmt.Errorf("unsupported audit sink type %q", sink.Type)
+	}
+}
+
+// AuditSinkBackoff is how long a sink waits after its nth consecutive
+// failure: 30 seconds, doubling up to an hour
+func AuditSinkBackoff(failures int) time.Duration {
+	backoff := auditSinkBaseBackoff
+	for i := 1; i < failures && backoff < auditSinkMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, auditSinkMaxBackoff)
+}
+
+// validate fills in defaults and checks a sink's settings for its type
+func (s *AuditExportService) validate(sink *types.AuditSink) error {
+	if sink.Name == "" {
+		return types.NewValidationError("name is required")
+	}
+	if sink.Format == "" {
+		sink.Format = types.AuditSinkFormatJSON
+	}
+	if sink.Format != types.AuditSinkFormatJSON && sink.Format != types.AuditSinkFormatCEF {
+		return types.NewValidationError("format must be json or cef")
+	}
+	if sink.MinSeverity == "" {
+		sink.MinSeverity = types.AuditSeverityInfo
+	}
+	if !types.IsValidAuditSeverity(sink.MinSeverity) {
+		return types.NewValidationError("min_severity must be one of info, notice, warning, critical")
+	}
+	if sink.BatchSize == 0 {
+		sink.BatchSize = defaultAuditSinkBatchSize
+	}
+	if sink.BatchSize < 1 || sink.BatchSize > maxAuditSinkBatchSize {
+		return types.NewValidationError(fmt.Sprintf("batch_size must be between 1 and %d", maxAuditSinkBatchSize))
+	}
+
+	config := &sink.Config
+	switch sink.Type {
+	case types.AuditSinkTypeSyslog:
+		if _, _, err := net.SplitHostPort(config.Address); err != nil || config.Address == "" {
+			return types.NewValidationError("config.address must be a host:port syslog address")
+		}
+		if config.Network == "" {
+			config.Network = types.AuditSyslogNetworkUDP
+		}
+		switch config.Network {
+		case types.AuditSyslogNetworkUDP, types.AuditSyslogNetworkTCP, types.AuditSyslogNetworkTLS:
+		default:
+			return types.NewValidationError("config.network must be udp, tcp or tls")
+		}
+		if config.Facility != nil && (*config.Facility < 0 || *config.Facility > 23) {
+			return types.NewValidationError("config.facility must be between 0 and 23")
+		}
+		if len(config.AppName) > 48 || strings.ContainsFunc(config.AppName, notPrintableASCII) {
+			return types.NewValidationError("config.app_name must be at most 48 printable ASCII characters")
+		}
+	case types.AuditSinkTypeWebhook:
+		if err := checkSinkURL(config.URL); err != nil {
+			return err
+		}
+	case types.AuditSinkTypeKafka:
+		if err := checkSinkURL(config.URL); err != nil {
+			return err
+		}
+		if !kafkaTopicPattern.MatchString(config.Topic) {
+			return types.NewValidationError("config.topic must be a Kafka topic name")
+		}
+	default:
+		return types.NewValidationError("type must be syslog, webhook or kafka")
+	}
+	for name := range config.Headers {
+		if name == "" || strings.ContainsFunc(name, notPrintableASCII) || strings.ContainsAny(name, " :") {
+			return types.NewValidationError("config.headers has an invalid header name")
+		}
+	}
+
+	return s.checkEgress(sink)
+}
+
+// checkName rejects a name another sink of the organization already uses
+func (s *AuditExportService) checkName(orgID, id, name string) error {
+	sinks, err := s.store.ListSinks(orgID)
+	if err != nil {
+		return fmt.Errorf("failed to list audit sinks: %w", err)
+	}
+	for _, other := range sinks {
+		if other.ID != id && strings.EqualFold(other.Name, name) {
+			return types.NewConflictError("an audit sink with this name already exists")
+		}
+	}
+	return nil
+}
+
+// checkEgress applies the egress policy to the sink's destination
+func (s *AuditExportService) checkEgress(sink *types.AuditSink) error {
+	if s.egress == nil {
+		return nil
+	}
+	destination := sink.Config.URL
+	if sink.Type == types.AuditSinkTypeSyslog {
+		destination = sink.Config.Network + "://" + sink.Config.Address
+	}
+	return s.egress.CheckURL(types.ConnectivityFeatureAuditExport, destination)
+}
+
+func checkSinkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return types.NewValidationError("config.url must be an http or https URL")
+	}
+	return nil
+}
+
+func notPrintableASCII(r rune) bool {
+	return r < 33 || r > 126
+}
@@ -0,0 +1,226 @@
+package logging
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// auditSinkColumns are the columns read back into a types.AuditSink
+const auditSinkColumns = `s.id, s.organization_id, s.name, s.type, s.format, s.config,
+	COALESCE(s.secret, ''), s.min_severity, s.batch_size, s.enabled, s.cursor_sequence,
+	s.failures, s.next_attempt_at, s.last_delivered_at, COALESCE(s.last_error, ''),
+	COALESCE(s.created_by::text, ''), s.created_at, s.updated_at`
+
+// auditSinkLag counts the chained records after a sink's cursor
+const auditSinkLag = `GREATEST(COALESCE((
+		SELECT MAX(a.sequence) FROM audit_logs a WHERE a.organization_id = s.organization_id
+	), 0) - s.cursor_sequence, 0)`
+
+// auditSinkStore is the database-backed AuditSinkStore
+type auditSinkStore struct {
+	db *sql.DB
+}
+
+func newAuditSinkStore(db *sql.DB) *auditSinkStore {
+	return &auditSinkStore{db: db}
+}
+
+func scanAuditSink(row interface{ Scan(...interface{}) error }, withLag bool) (*types.AuditSink, error) {
+	sink := &types.AuditSink{}
+	var config []byte
+	var nextAttemptAt, lastDeliveredAt sql.NullTime
+
+	dest := []interface{}{
+		&sink.ID, &sink.OrganizationID, &sink.Name, &sink.Type, &sink.Format, &config,
+		&sink.Secret, &sink.MinSeverity, &sink.BatchSize, &sink.Enabled, &sink.CursorSequence,
+		&sink.Failures, &nextAttemptAt, &lastDeliveredAt, &sink.LastError,
+		&sink.CreatedBy, &sink.CreatedAt, &sink.UpdatedAt,
+	}
+	if withLag {
+		dest = append(dest, &sink.Lag)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &sink.Config); err != nil {
+			return nil, fmt.Errorf("failed to decode audit sink config: %w", err)
+		}
+	}
+	if nextAttemptAt.Valid {
+		sink.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if lastDeliveredAt.Valid {
+		sink.LastDeliveredAt = &lastDeliveredAt.Time
+	}
+	describeSecret(sink)
+	return sink, nil
+}
+
+func (st *auditSinkStore) CreateSink(sink *types.AuditSink) error {
+	config, err := json.Marshal(sink.Config)
+	if err != nil {
+		return err
+	}
+	err = st.db.QueryRow(`
+		INSERT INTO audit_sinks (
+			organization_id, name, type, format, config, secret, min_severity,
+			batch_size, enabled, cursor_sequence, created_by
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, NULLIF($11, '')::uuid)
+		RETURNING id, created_at, updated_at
+	`, sink.OrganizationID, sink.Name, sink.Type, sink.Format, string(config), sink.Secret,
+		sink.MinSeverity, sink.BatchSize, sink.Enabled, sink.CursorSequence, sink.CreatedBy,
+	).Scan(&sink.ID, &sink.CreatedAt, &sink.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	describeSecret(sink)
+	return nil
+}
+
+func (st *auditSinkStore) GetSink(orgID, id string) (*types.AuditSink, error) {
+	sink, err := scanAuditSink(st.db.QueryRow(`
+		SELECT `+auditSinkColumns+`, `+auditSinkLag+`
+		FROM audit_sinks s
+		WHERE s.organization_id = $1 AND s.id::text = $2
+	`, orgID, id), true)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return sink, err
+}
+
+func (st *auditSinkStore) ListSinks(orgID string) ([]*types.AuditSink, error) {
+	rows, err := st.db.Query(`
+		SELECT `+auditSinkColumns+`, `+auditSinkLag+`
+		FROM audit_sinks s
+		WHERE s.organization_id = $1
+		ORDER BY s.name
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sinks []*types.AuditSink
+	for rows.Next() {
+		sink, err := scanAuditSink(rows, true)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, rows.Err()
+}
+
+// UpdateSink saves a sink's settings. Delivery state is left alone, so that
+// an update does not undo the progress of a delivery running meanwhile.
+func (st *auditSinkStore) UpdateSink(sink *types.AuditSink, resetBackoff bool) error {
+	config, err := json.Marshal(sink.Config)
+	if err != nil {
+		return err
+	}
+	return st.db.QueryRow(`
+		UPDATE audit_sinks SET
+			name = $3, format = $4, config = $5, secret = NULLIF($6, ''),
+			min_severity = $7, batch_size = $8, enabled = $9,
+			failures = CASE WHEN $10 THEN 0 ELSE failures END,
+			next_attempt_at = CASE WHEN $10 THEN NULL ELSE next_attempt_at END
+		WHERE organization_id = $1 AND id = $2
+		RETURNING updated_at
+	`, sink.OrganizationID, sink.ID, sink.Name, sink.Format, string(config), sink.Secret,
+		sink.MinSeverity, sink.BatchSize, sink.Enabled, resetBackoff,
+	).Scan(&sink.UpdatedAt)
+}
+
+func (st *auditSinkStore) DeleteSink(orgID, id string) (bool, error) {
+	result, err := st.db.Exec(`DELETE FROM audit_sinks WHERE organization_id = $1 AND id::text = $2`, orgID, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (st *auditSinkStore) ChainHead(orgID string) (int64, error) {
+	var head int64
+	err := st.db.QueryRow(`
+		SELECT COALESCE(MAX(sequence), 0) FROM audit_logs WHERE organization_id = $1
+	`, orgID).Scan(&head)
+	return head, err
+}
+
+// ClaimDueSinks leases due sinks by pushing their next attempt past the
+// lease, skipping rows other workers are claiming
+func (st *auditSinkStore) ClaimDueSinks(now, leaseUntil time.Time, limit int) ([]*types.AuditSink, error) {
+	rows, err := st.db.Query(`
+		UPDATE audit_sinks s SET next_attempt_at = $2
+		WHERE s.id IN (
+			SELECT id FROM audit_sinks
+			WHERE enabled AND (next_attempt_at IS NULL OR next_attempt_at <= $1)
+			ORDER BY next_attempt_at NULLS FIRST
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+auditSinkColumns, now, leaseUntil, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sinks []*types.AuditSink
+	for rows.Next() {
+		sink, err := scanAuditSink(rows, false)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, rows.Err()
+}
+
+func (st *auditSinkStore) ListChainedAudits(orgID string, after int64, limit int) ([]*types.AuditLog, error) {
+	rows, err := st.db.Query(`
+		SELECT `+auditLogColumns+` FROM audit_logs
+		WHERE organization_id = $1 AND sequence > $2
+		ORDER BY sequence
+		LIMIT $3
+	`, orgID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var audits []*types.AuditLog
+	for rows.Next() {
+		audit, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		audits = append(audits, audit)
+	}
+	return audits, rows.Err()
+}
+
+func (st *auditSinkStore) AdvanceSink(id string, cursor int64, deliveredAt *time.Time) error {
+	_, err := st.db.Exec(`
+		UPDATE audit_sinks SET
+			cursor_sequence = GREATEST(cursor_sequence, $2),
+			last_delivered_at = COALESCE($3, last_delivered_at)
+		WHERE id = $1
+	`, id, cursor, deliveredAt)
+	return err
+}
+
+func (st *auditSinkStore) ReleaseSink(id string, failures int, nextAttemptAt *time.Time, lastError string) error {
+	_, err := st.db.Exec(`
+		UPDATE audit_sinks SET failures = $2, next_attempt_at = $3, last_error = NULLIF($4, '')
+		WHERE id = $1
+	`, id, failures, nextAttemptAt, lastError)
+	return err
+}
@@ -0,0 +1,377 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/buildinfo"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/pkg/requestsig"
+)
+
+const (
+	auditWebhookEvent    = "audit.batch"
+	kafkaJSONContentType = "application/vnd.kafka.json.v2+json"
+	maxSinkResponseBody  = 64 * 1024
+)
+
+// AuditWebhookPayload is the JSON body posted to webhook sinks
+type AuditWebhookPayload struct {
+	Events         []*types.AuditLog `json:"events"`
+	Event          string            `json:"event"`
+	OrganizationID string            `json:"organization_id"`
+}
+
+// sendSyslog writes each record as an RFC 5424 message. Over TCP and TLS
+// messages are framed by octet counting (RFC 6587); over UDP each message
+// is its own datagram.
+func (s *AuditExportService) sendSyslog(ctx context.Context, sink *types.AuditSink, events []*types.AuditLog) error {
+	config := sink.Config
+	var conn net.Conn
+	var err error
+	switch config.Network {
+	case types.AuditSyslogNetworkTLS:
+		dialer := &tls.Dialer{NetDialer: s.dialer}
+		conn, err = dialer.DialContext(ctx, "tcp", config.Address)
+	case types.AuditSyslogNetworkTCP:
+		conn, err = s.dialer.DialContext(ctx, "tcp", config.Address)
+	default:
+		conn, err = s.dialer.DialContext(ctx, "udp", config.Address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog server: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(auditSinkTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetWriteDeadline(deadline)
+
+	facility := defaultSyslogFacility
+	if config.Facility != nil {
+		facility = *config.Facility
+	}
+	appName := config.AppName
+	if appName == "" {
+		appName = defaultSyslogAppName
+	}
+
+	stream := config.Network != types.AuditSyslogNetworkUDP
+	var buf bytes.Buffer
+	for _, event := range events {
+		payload, err := formatAuditPayload(sink.Format, event)
+		if err != nil {
+			return err
+		}
+		message := FormatAuditSyslog(event, payload, facility, appName, s.hostname)
+		if !stream {
+			if _, err := conn.Write(message); err != nil {
+				return fmt.Errorf("failed to write to syslog server: %w", err)
+			}
+			continue
+		}
+		buf.WriteString(strconv.Itoa(len(message)))
+		buf.WriteByte(' ')
+		buf.Write(message)
+	}
+	if stream {
+		if _, err := conn.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write to syslog server: %w", err)
+		}
+	}
+	return nil
+}
+
+// sendWebhook posts a batch to the sink's URL: a JSON object listing the
+// records, or CEF lines as plain text. The body is signed when the sink has
+// a secret.
+func (s *AuditExportService) sendWebhook(ctx context.Context, sink *types.AuditSink, events []*types.AuditLog) error {
+	var body []byte
+	contentType := "application/json"
+	if sink.Format == types.AuditSinkFormatCEF {
+		lines := make([]string, len(events))
+		for i, event := range events {
+			lines[i] = FormatAuditCEF(event)
+		}
+		body = []byte(strings.Join(lines, "\n") + "\n")
+		contentType = "text/plain; charset=utf-8"
+	} else {
+		var err error
+		body, err = json.Marshal(&AuditWebhookPayload{
+			Events:         events,
+			Event:          auditWebhookEvent,
+			OrganizationID: sink.OrganizationID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode audit records: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.Config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, value := range sink.Config.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "Omnimesh-Gateway")
+	req.Header.Set("X-Omnimesh-Event", auditWebhookEvent)
+	if sink.Secret != "" {
+		if err := requestsig.NewHMACKey(sink.ID, sink.Secret).Sign(req, body, s.now()); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.post(req)
+	return err
+}
+
+// kafkaRecord is a record produced through the Kafka REST proxy v2 API
+type kafkaRecord struct {
+	Value interface{} `json:"value"`
+	Key   string      `json:"key"`
+}
+
+// kafkaProduceResponse reports the outcome of each produced record
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// sendKafka produces a batch to the sink's topic through a Kafka REST proxy.
+// Records are keyed by organization so that they stay in order within a
+// partition.
+func (s *AuditExportService) sendKafka(ctx context.Context, sink *types.AuditSink, events []*types.AuditLog) error {
+	records := make([]kafkaRecord, len(events))
+	for i, event := range events {
+		records[i] = kafkaRecord{Key: event.OrganizationID, Value: event}
+		if sink.Format == types.AuditSinkFormatCEF {
+			records[i].Value = FormatAuditCEF(event)
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return fmt.Errorf("failed to encode audit records: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(sink.Config.URL, "/") + "/topics/" + sink.Config.Topic
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, value := range sink.Config.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", kafkaJSONContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json, application/json")
+	req.Header.Set("User-Agent", "Omnimesh-Gateway")
+	if sink.Config.Username != "" {
+		req.SetBasicAuth(sink.Config.Username, sink.Secret)
+	}
+
+	respBody, err := s.post(req)
+	if err != nil {
+		return err
+	}
+	// Proxies report per-record failures in an otherwise successful answer
+	var produced kafkaProduceResponse
+	if err := json.Unmarshal(respBody, &produced); err != nil {
+		return nil
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil && *offset.ErrorCode != 0 {
+			return fmt.Errorf("kafka rejected a record: %s (code %d)", offset.Error, *offset.ErrorCode)
+		}
+	}
+	return nil
+}
+
+// post sends an HTTP request and returns the response body of a 2xx answer
+func (s *AuditExportService) post(req *http.Request) ([]byte, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxSinkResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("sink returned HTTP %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+// describeSecret sets what responses show of a sink's secret: whether it
+// has one and, for webhook sinks, the ID of the signing key it makes
+func describeSecret(sink *types.AuditSink) {
+	sink.HasSecret = sink.Secret != ""
+	sink.SigningKeyID = ""
+	if sink.HasSecret && sink.Type == types.AuditSinkTypeWebhook {
+		sink.SigningKeyID = requestsig.NewHMACKey(sink.ID, sink.Secret).ID
+	}
+}
+
+// formatAuditPayload encodes a record as the message of a syslog line
+func formatAuditPayload(format string, event *types.AuditLog) ([]byte, error) {
+	if format == types.AuditSinkFormatCEF {
+		return []byte(FormatAuditCEF(event)), nil
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	return payload, nil
+}
+
+// FormatAuditSyslog frames an encoded record as an RFC 5424 syslog message
+// of the given facility, with a severity matching the record's
+func FormatAuditSyslog(event *types.AuditLog, payload []byte, facility int, appName, hostname string) []byte {
+	priority := facility*8 + syslogSeverity(event.Severity)
+	timestamp := event.Timestamp.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+	if event.Timestamp.IsZero() {
+		timestamp = "-"
+	}
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s - audit - ", priority, timestamp, hostname, appName)
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// syslogSeverity maps an audit severity tier to a syslog severity
+func syslogSeverity(severity string) int {
+	switch severity {
+	case types.AuditSeverityCritical:
+		return 2
+	case types.AuditSeverityWarning:
+		return 4
+	case types.AuditSeverityNotice:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// cefSeverity maps an audit severity tier to the 0-10 CEF scale
+func cefSeverity(severity string) int {
+	switch severity {
+	case types.AuditSeverityCritical:
+		return 10
+	case types.AuditSeverityWarning:
+		return 7
+	case types.AuditSeverityNotice:
+		return 5
+	default:
+		return 3
+	}
+}
+
+// FormatAuditCEF encodes a record as an ArcSight Common Event Format line.
+// The signature ID is the audited resource and action, such as
+// server:delete; custom string fields carry the organization, resource and
+// chain hash, and cn1 the record's chain sequence.
+func FormatAuditCEF(event *types.AuditLog) string {
+	header := []string{
+		"CEF:0",
+		"Omnimesh",
+		"Omnimesh Gateway",
+		cefHeaderEscape(buildinfo.Get().Version),
+		cefHeaderEscape(event.Resource + ":" + event.Action),
+		cefHeaderEscape(strings.TrimSpace(event.Action + " " + event.Resource)),
+		strconv.Itoa(cefSeverity(event.Severity)),
+	}
+
+	var ext []string
+	add := func(key, value string) {
+		if value != "" {
+			ext = append(ext, key+"="+cefExtensionEscape(value))
+		}
+	}
+	if !event.Timestamp.IsZero() {
+		add("rt", strconv.FormatInt(event.Timestamp.UnixMilli(), 10))
+	}
+	add("externalId", event.ID)
+	add("suid", event.UserID)
+	if net.ParseIP(event.RemoteIP) != nil {
+		add("src", event.RemoteIP)
+	}
+	add("requestClientApplication", event.UserAgent)
+	add("act", event.Action)
+	outcome := "success"
+	if !event.Success {
+		outcome = "failure"
+	}
+	add("outcome", outcome)
+	add("reason", event.Error)
+	if event.OrganizationID != "" {
+		add("cs1Label", "organizationId")
+		add("cs1", event.OrganizationID)
+	}
+	if event.Resource != "" {
+		add("cs2Label", "resourceType")
+		add("cs2", event.Resource)
+	}
+	if event.ResourceID != "" {
+		add("cs3Label", "resourceId")
+		add("cs3", event.ResourceID)
+	}
+	if event.Hash != "" {
+		add("cs4Label", "chainHash")
+		add("cs4", event.Hash)
+	}
+	if len(event.Details) > 0 {
+		if details, err := json.Marshal(event.Details); err == nil {
+			add("cs5Label", "details")
+			add("cs5", string(details))
+		}
+	}
+	if event.Sequence > 0 {
+		add("cn1Label", "sequence")
+		add("cn1", strconv.FormatInt(event.Sequence, 10))
+	}
+
+	return strings.Join(header, "|") + "|" + strings.Join(ext, " ")
+}
+
+var (
+	cefHeaderReplacer    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionReplacer = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+func cefHeaderEscape(value string) string {
+	return cefHeaderReplacer.Replace(value)
+}
+
+func cefExtensionEscape(value string) string {
+	return cefExtensionReplacer.Replace(value)
+}
+
+// syslogHostname returns the host name sent in syslog messages, limited to
+// what RFC 5424 allows
+func syslogHostname() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" || strings.ContainsFunc(hostname, notPrintableASCII) {
+		return "-"
+	}
+	if len(hostname) > 255 {
+		hostname = hostname[:255]
+	}
+	return hostname
+}
@@ -36,6 +36,7 @@ type LogEntry struct {
 	OrgID       string                 `json:"org_id,omitempty"`
 	StatusCode  int                    `json:"status_code,omitempty"`
 	Data        map[string]interface{} `json:"data,omitempty"`
+	Principal   *types.Principal       `json:"principal,omitempty"`
 	Source      string                 `json:"source,omitempty"`
 	Environment string                 `json:"environment,omitempty"`
 }
@@ -75,20 +76,37 @@ type BackendCapabilities struct {
 
 // QueryRequest represents a log query with filters
 type QueryRequest struct {
-	StartTime  *time.Time             `json:"start_time,omitempty"`
-	EndTime    *time.Time             `json:"end_time,omitempty"`
-	Filters    map[string]interface{} `json:"filters,omitempty"`
-	RequestID  string                 `json:"request_id,omitempty"`
-	EntityType string                 `json:"entity_type,omitempty"`
-	EntityID   string                 `json:"entity_id,omitempty"`
-	Logger     string                 `json:"logger,omitempty"`
-	UserID     string                 `json:"user_id,omitempty"`
-	OrgID      string                 `json:"org_id,omitempty"`
-	Message    string                 `json:"message,omitempty"`
-	OrderBy    string                 `json:"order_by,omitempty"`
-	Level      LogLevel               `json:"level,omitempty"`
-	Limit      int                    `json:"limit,omitempty"`
-	Offset     int                    `json:"offset,omitempty"`
+	StartTime     *time.Time             `json:"start_time,omitempty"`
+	EndTime       *time.Time             `json:"end_time,omitempty"`
+	Filters       map[string]interface{} `json:"filters,omitempty"`
+	Labels        types.Labels           `json:"labels,omitempty"`
+	RequestID     string                 `json:"request_id,omitempty"`
+	EntityType    string                 `json:"entity_type,omitempty"`
+	EntityID      string                 `json:"entity_id,omitempty"`
+	Logger        string                 `json:"logger,omitempty"`
+	UserID        string                 `json:"user_id,omitempty"`
+	OrgID         string                 `json:"org_id,omitempty"`
+	Message       string                 `json:"message,omitempty"`
+	OrderBy       string                 `json:"order_by,omitempty"`
+	Level         LogLevel               `json:"level,omitempty"`
+	PrincipalType string                 `json:"principal_type,omitempty"`
+	PrincipalID   string                 `json:"principal_id,omitempty"`
+	Limit         int                    `json:"limit,omitempty"`
+	Offset        int                    `json:"offset,omitempty"`
+}
+
+// MetricEmitter forwards metrics to an external system such as StatsD
+type MetricEmitter interface {
+	// Emit sends a single metric
+	Emit(metric *types.Metric) error
+
+	// Close releases any resources held by the emitter
+	Close() error
+}
+
+// AuditStore persists audit records, such as the hash-chained AuditService
+type AuditStore interface {
+	LogAudit(audit *types.AuditLog) error
 }
 
 // LogSubscriber receives log events in real-time
@@ -145,15 +163,17 @@ type PluginInfo struct {
 
 // LoggingConfig represents the logging service configuration
 type LoggingConfig struct {
-	Config        map[string]interface{} `json:"config" yaml:"config"`
-	Retention     *RetentionConfig       `json:"retention,omitempty" yaml:"retention,omitempty"`
-	Level         LogLevel               `json:"level" yaml:"level"`
-	Environment   string                 `json:"environment" yaml:"environment"`
-	Backend       string                 `json:"backend" yaml:"backend"`
-	BufferSize    int                    `json:"buffer_size" yaml:"buffer_size"`
-	BatchSize     int                    `json:"batch_size" yaml:"batch_size"`
-	FlushInterval time.Duration          `json:"flush_interval" yaml:"flush_interval"`
-	Async         bool                   `json:"async" yaml:"async"`
+	Config          map[string]interface{} `json:"config" yaml:"config"`
+	Retention       *RetentionConfig       `json:"retention,omitempty" yaml:"retention,omitempty"`
+	Level           LogLevel               `json:"level" yaml:"level"`
+	Environment     string                 `json:"environment" yaml:"environment"`
+	Backend         string                 `json:"backend" yaml:"backend"`
+	OverflowPolicy  OverflowPolicy         `json:"overflow_policy" yaml:"overflow_policy"`
+	BufferSize      int                    `json:"buffer_size" yaml:"buffer_size"`
+	BatchSize       int                    `json:"batch_size" yaml:"batch_size"`
+	FlushInterval   time.Duration          `json:"flush_interval" yaml:"flush_interval"`
+	OverflowTimeout time.Duration          `json:"overflow_timeout" yaml:"overflow_timeout"`
+	Async           bool                   `json:"async" yaml:"async"`
 }
 
 // RetentionConfig defines log retention policies
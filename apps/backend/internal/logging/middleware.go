@@ -2,18 +2,29 @@ package logging
 
 import (
 	"bytes"
+	"context"
 	"io"
+	"strconv"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/secrets"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// FieldPolicySource returns the log field policy of an organization, or nil
+// to log every field
+type FieldPolicySource interface {
+	FieldPolicy(ctx context.Context, orgID string) *types.LogFieldPolicy
+}
+
 // Middleware provides request logging middleware
 type Middleware struct {
 	service *Service
+	fields  FieldPolicySource
+	sampler *Sampler
 }
 
 // NewMiddleware creates a new logging middleware
@@ -23,6 +34,18 @@ func NewMiddleware(service *Service) *Middleware {
 	}
 }
 
+// SetFieldPolicy makes the request logger leave out the optional fields
+// each organization's policy omits
+func (m *Middleware) SetFieldPolicy(fields FieldPolicySource) {
+	m.fields = fields
+}
+
+// SetSampler makes the request logger record only a sample of successful
+// requests
+func (m *Middleware) SetSampler(sampler *Sampler) {
+	m.sampler = sampler
+}
+
 // RequestLogger logs HTTP requests and responses
 func (m *Middleware) RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -53,7 +76,20 @@ func (m *Middleware) RequestLogger() gin.HandlerFunc {
 		}
 		c.Writer = writer
 
-		// Capture user context before processing (to avoid race conditions)
+		// Process request
+		c.Next()
+
+		sampleWeight := int64(1)
+		if m.sampler != nil {
+			var keep bool
+			keep, sampleWeight = m.sampler.Sample(writer.status, len(c.Errors) > 0)
+			if !keep {
+				return
+			}
+		}
+
+		// Capture user context after processing, once the auth middleware
+		// further down the chain has resolved the caller
 		var userID, orgID string
 		if uid, exists := c.Get("user_id"); exists {
 			if u, ok := uid.(string); ok {
@@ -65,9 +101,7 @@ func (m *Middleware) RequestLogger() gin.HandlerFunc {
 				orgID = o
 			}
 		}
-
-		// Process request
-		c.Next()
+		principal := PrincipalFromGinContext(c)
 
 		// Calculate duration and capture errors after processing
 		duration := time.Since(startTime)
@@ -94,35 +128,69 @@ func (m *Middleware) RequestLogger() gin.HandlerFunc {
 			OrganizationID: orgID,
 		}
 
+		var fields *types.LogFieldPolicy
+		if m.fields != nil {
+			fields = m.fields.FieldPolicy(c.Request.Context(), orgID)
+		}
+
 		// Add additional data
 		entry.Data = map[string]interface{}{
-			"query_params":  c.Request.URL.RawQuery,
 			"response_size": writer.size,
+			"method":        entry.Method,
+			"path":          entry.Path,
+			"duration_ms":   float64(duration.Microseconds()) / 1000,
+		}
+
+		if !fields.Omits(types.LogFieldQueryParams) {
+			entry.Data["query_params"] = c.Request.URL.RawQuery
 		}
 
-		if len(requestBody) > 0 && len(requestBody) < 1024 {
-			entry.Data["request_body"] = string(requestBody)
+		if len(requestBody) > 0 && len(requestBody) < 1024 && !fields.Omits(types.LogFieldRequestBody) {
+			entry.Data["request_body"] = secrets.Mask(string(requestBody))
 		}
 
-		if writer.body.Len() > 0 && writer.body.Len() < 1024 {
-			entry.Data["response_body"] = writer.body.String()
+		if writer.body.Len() > 0 && writer.body.Len() < 1024 && !fields.Omits(types.LogFieldResponseBody) {
+			entry.Data["response_body"] = secrets.Mask(writer.body.String())
 		}
 
 		if errorStr != "" {
 			entry.Error = errorStr
 		}
 
+		if val, exists := c.Get(types.DetectedClientContextKey); exists {
+			if client, ok := val.(*types.DetectedClient); ok && client != nil && !fields.Omits(types.LogFieldClient) {
+				entry.Data["client"] = client.Name
+				if client.Version != "" {
+					entry.Data["client_version"] = client.Version
+				}
+			}
+		}
+
+		if serverID := serverIDFromGinContext(c); serverID != "" {
+			entry.Data["server_id"] = serverID
+		}
+
+		if sampleWeight > 1 {
+			entry.Data[SampleWeightKey] = sampleWeight
+		}
+
+		if c.GetBool(types.SandboxContextKey) {
+			entry.Data["sandbox"] = true
+			entry.Data["billable"] = false
+		}
+
 		logEntry := &LogEntry{
 			ID:         entry.ID,
 			Timestamp:  entry.Timestamp,
 			Level:      LogLevel(entry.Level),
 			Message:    entry.Message,
-			Logger:     "request",
+			Logger:     LoggerRequest,
 			RequestID:  entry.RequestID,
 			UserID:     entry.UserID,
 			OrgID:      entry.OrganizationID,
 			StatusCode: entry.StatusCode,
 			Data:       entry.Data,
+			Principal:  principal,
 		}
 
 		if err := m.service.Log(c.Request.Context(), logEntry); err != nil {
@@ -184,6 +252,19 @@ func (m *Middleware) AuditLogger(action, resource string) gin.HandlerFunc {
 			"path":        c.Request.URL.Path,
 			"status_code": c.Writer.Status(),
 		}
+		if principal := PrincipalFromGinContext(c); principal != nil {
+			audit.Details["principal_type"] = principal.Type
+			audit.Details["principal_id"] = principal.ID()
+			if principal.APIKeyID != "" {
+				audit.Details["api_key_id"] = principal.APIKeyID
+			}
+			if principal.ClientID != "" {
+				audit.Details["client_id"] = principal.ClientID
+			}
+			if principal.OnBehalfOf != "" {
+				audit.Details["on_behalf_of"] = principal.OnBehalfOf
+			}
+		}
 
 		// Log the audit event
 		if err := m.service.LogAudit(c.Request.Context(), audit); err != nil {
@@ -213,13 +294,21 @@ func (m *Middleware) MetricsCollector() gin.HandlerFunc {
 			Tags: map[string]string{
 				"method": c.Request.Method,
 				"path":   c.Request.URL.Path,
-				"status": string(rune(c.Writer.Status())),
+				"status": strconv.Itoa(c.Writer.Status()),
 			},
 		}
 
 		if orgID != nil {
 			durationMetric.OrganizationID = orgID.(string)
 		}
+		if principal := PrincipalFromGinContext(c); principal != nil {
+			durationMetric.Tags["principal_type"] = principal.Type
+			for _, key := range []string{types.LabelTeam, types.LabelProject, types.LabelCostCenter} {
+				if value, ok := principal.Labels[key]; ok {
+					durationMetric.Tags["label_"+key] = value
+				}
+			}
+		}
 
 		// Request count metric
 		countMetric := &types.Metric{
@@ -230,7 +319,7 @@ func (m *Middleware) MetricsCollector() gin.HandlerFunc {
 			Tags: map[string]string{
 				"method": c.Request.Method,
 				"path":   c.Request.URL.Path,
-				"status": string(rune(c.Writer.Status())),
+				"status": strconv.Itoa(c.Writer.Status()),
 			},
 		}
 
@@ -299,3 +388,14 @@ func (m *Middleware) getLogLevel(statusCode int) string {
 		return types.LogLevelInfo
 	}
 }
+
+// serverIDFromGinContext returns the MCP server a request was routed to, as
+// resolved by the transport path rewriting or named in the route
+func serverIDFromGinContext(c *gin.Context) string {
+	if val, exists := c.Get("transport_context"); exists {
+		if transportCtx, ok := val.(*types.TransportContext); ok && transportCtx.ServerID != "" {
+			return transportCtx.ServerID
+		}
+	}
+	return c.Param("server_id")
+}
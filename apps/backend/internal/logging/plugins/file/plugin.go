@@ -398,6 +398,9 @@ func (f *FileStorageBackend) matchesQuery(entry *logging.LogEntry, query *loggin
 	if query.OrgID != "" && entry.OrgID != query.OrgID {
 		return false
 	}
+	if !logging.MatchesPrincipal(entry, query) {
+		return false
+	}
 
 	// Message search
 	if query.Message != "" && !strings.Contains(strings.ToLower(entry.Message), strings.ToLower(query.Message)) {
@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrincipalFromGinContext resolves the caller identity for a request.
+// It prefers the principal set by the auth middleware and falls back to
+// the individual user, API key and OAuth client context keys.
+func PrincipalFromGinContext(c *gin.Context) *types.Principal {
+	if val, exists := c.Get("principal"); exists {
+		if principal, ok := val.(*types.Principal); ok && principal != nil {
+			return principal
+		}
+	}
+
+	if c.Request != nil {
+		if principal := types.PrincipalFromContext(c.Request.Context()); principal != nil {
+			return principal
+		}
+	}
+
+	principal := &types.Principal{}
+	if uid, exists := c.Get("user_id"); exists {
+		if u, ok := uid.(string); ok {
+			principal.UserID = u
+			principal.Type = types.PrincipalTypeUser
+		}
+	}
+	if oid, exists := c.Get("organization_id"); exists {
+		if o, ok := oid.(string); ok {
+			principal.OrganizationID = o
+		}
+	}
+	if key, exists := c.Get("api_key"); exists {
+		if k, ok := key.(*types.APIKey); ok && k != nil {
+			principal.APIKeyID = k.ID
+			principal.Type = types.PrincipalTypeAPIKey
+		}
+	}
+	if cid, exists := c.Get("client_id"); exists {
+		if id, ok := cid.(string); ok && id != "" {
+			principal.ClientID = id
+			principal.Type = types.PrincipalTypeOAuthClient
+		}
+	}
+
+	if principal.Type == "" {
+		return nil
+	}
+	return principal
+}
+
+// MatchesPrincipal reports whether an entry satisfies the principal and
+// label filters of a query
+func MatchesPrincipal(entry *LogEntry, query *QueryRequest) bool {
+	if query == nil {
+		return true
+	}
+	if len(query.Labels) > 0 && !matchesLabels(entry.Principal, query.Labels) {
+		return false
+	}
+	if query.PrincipalType == "" && query.PrincipalID == "" {
+		return true
+	}
+	if entry.Principal == nil {
+		return query.PrincipalType == types.PrincipalTypeAnonymous && query.PrincipalID == ""
+	}
+	if query.PrincipalType != "" && entry.Principal.Type != query.PrincipalType {
+		return false
+	}
+	if query.PrincipalID != "" && entry.Principal.ID() != query.PrincipalID {
+		return false
+	}
+	return true
+}
+
+// matchesLabels reports whether the principal carries every requested label
+func matchesLabels(principal *types.Principal, labels types.Labels) bool {
+	if principal == nil {
+		return false
+	}
+	for key, value := range labels {
+		if principal.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// SampleWeightKey is the data key recording how many requests a sampled
+// request log entry stands for
+const SampleWeightKey = "sample_weight"
+
+// Sampler decides which successful requests the request logger records,
+// so that request logs do not overwhelm the database during traffic
+// spikes. Failed requests are always recorded. Successes are recorded one
+// in N, where N follows from the configured rate and, when traffic exceeds
+// the per-second target, grows so that about target successes a second are
+// recorded. Each recorded success carries N as its sample weight, from
+// which usage summaries extrapolate the real counts.
+type Sampler struct {
+	windowStart time.Time
+	now         func() time.Time
+	baseEvery   int64
+	every       int64
+	target      float64
+	windowSeen  int64
+	counter     int64
+	mu          sync.Mutex
+}
+
+// NewSampler creates a sampler recording rate of successful requests,
+// rounded to one in N, and at most about targetPerSecond successes a
+// second. A targetPerSecond of zero or less disables adaptation.
+func NewSampler(rate float64, targetPerSecond int) *Sampler {
+	baseEvery := int64(1)
+	if rate > 0 && rate < 1 {
+		baseEvery = int64(math.Round(1 / rate))
+	}
+	s := &Sampler{
+		now:       time.Now,
+		baseEvery: baseEvery,
+		every:     baseEvery,
+		target:    float64(targetPerSecond),
+	}
+	s.windowStart = s.now()
+	return s
+}
+
+// SetClock replaces the clock the per-second rate is measured with
+func (s *Sampler) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+	s.windowStart = now()
+}
+
+// Sample reports whether to record a request and the weight to record it
+// with
+func (s *Sampler) Sample(statusCode int, failed bool) (bool, int64) {
+	if failed || statusCode >= 400 {
+		return true, 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.adapt()
+	s.windowSeen++
+	s.counter++
+	if s.counter < s.every {
+		return false, 0
+	}
+	s.counter = 0
+	return true, s.every
+}
+
+// Every returns the current one-in-N sampling interval for successes
+func (s *Sampler) Every() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.every
+}
+
+// adapt recomputes the interval from the success rate seen over the last
+// second. It must be called with the lock held.
+func (s *Sampler) adapt() {
+	now := s.now()
+	elapsed := now.Sub(s.windowStart)
+	if elapsed < time.Second {
+		return
+	}
+
+	every := s.baseEvery
+	if s.target > 0 {
+		perSecond := float64(s.windowSeen) / elapsed.Seconds()
+		if adaptive := int64(math.Ceil(perSecond / s.target)); adaptive > every {
+			every = adaptive
+		}
+	}
+	s.every = every
+	if s.counter >= every {
+		s.counter = every - 1
+	}
+	s.windowStart = now
+	s.windowSeen = 0
+}
+
+// sampleWeight returns how many requests an entry stands for
+func sampleWeight(entry *LogEntry) int64 {
+	switch weight := entry.Data[SampleWeightKey].(type) {
+	case int64:
+		if weight > 1 {
+			return weight
+		}
+	case int:
+		if weight > 1 {
+			return int64(weight)
+		}
+	case float64:
+		if weight > 1 {
+			return int64(weight)
+		}
+	}
+	return 1
+}
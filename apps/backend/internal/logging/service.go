@@ -2,6 +2,7 @@ package logging
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -16,12 +17,12 @@ type Service struct {
 	backend     StorageBackend
 	config      *LoggingConfig
 	subscribers map[string]LogSubscriber
-	stopCh      chan struct{}
+	auditStore  AuditStore
+	emitters    []MetricEmitter
+	entries     *asyncWriter[*LogEntry]
+	audits      *asyncWriter[*types.AuditLog]
 	level       LogLevel
-	buffer      []*LogEntry
-	wg          sync.WaitGroup
 	mu          sync.RWMutex
-	bufferMu    sync.Mutex
 }
 
 // NewService creates a new logging service with plugin-based storage
@@ -50,8 +51,6 @@ func NewService(config *LoggingConfig) (LogService, error) {
 		backend:     backend,
 		subscribers: make(map[string]LogSubscriber),
 		level:       config.Level,
-		buffer:      make([]*LogEntry, 0, config.BufferSize),
-		stopCh:      make(chan struct{}),
 	}
 
 	// Initialize backend
@@ -62,9 +61,9 @@ func NewService(config *LoggingConfig) (LogService, error) {
 		return nil, fmt.Errorf("failed to initialize backend: %w", err)
 	}
 
-	// Start background flush if async mode is enabled
+	// Move storage off the request path if async mode is enabled
 	if config.Async {
-		s.startBackgroundFlush()
+		s.startAsyncWriters()
 	}
 
 	return s, nil
@@ -96,8 +95,8 @@ func (s *Service) Log(ctx context.Context, entry *LogEntry) error {
 	s.notifySubscribers(entry)
 
 	// Store the log
-	if s.config.Async {
-		return s.bufferLog(entry)
+	if s.entries != nil {
+		return s.entries.Write(ctx, entry)
 	}
 
 	return s.backend.Store(ctx, entry)
@@ -132,8 +131,8 @@ func (s *Service) LogBatch(ctx context.Context, entries []*LogEntry) error {
 	}
 
 	// Store the logs
-	if s.config.Async {
-		return s.bufferLogs(filteredEntries)
+	if s.entries != nil {
+		return s.entries.Write(ctx, filteredEntries...)
 	}
 
 	return s.backend.StoreBatch(ctx, filteredEntries)
@@ -214,15 +213,19 @@ func (s *Service) HealthCheck(ctx context.Context) error {
 
 // Close shuts down the service
 func (s *Service) Close() error {
-	// Stop background flush
-	close(s.stopCh)
-	s.wg.Wait()
+	// Flush buffered audit records and log entries
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if s.audits != nil {
+		_ = s.audits.Close(ctx)
+	}
+	if s.entries != nil {
+		_ = s.entries.Close(ctx)
+	}
 
-	// Flush remaining buffer
-	if len(s.buffer) > 0 {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		s.flushBuffer(ctx)
+	// Close metric emitters
+	for _, emitter := range s.emitters {
+		_ = emitter.Close()
 	}
 
 	// Close backend
@@ -236,17 +239,28 @@ func (s *Service) Close() error {
 // GetMetrics returns logging metrics
 func (s *Service) GetMetrics() (*types.LoggingMetrics, error) {
 	s.mu.RLock()
-	bufferSize := len(s.buffer)
 	subscriberCount := len(s.subscribers)
+	level := s.level
 	s.mu.RUnlock()
 
-	return &types.LoggingMetrics{
-		BufferSize:      bufferSize,
+	metrics := &types.LoggingMetrics{
 		SubscriberCount: subscriberCount,
-		CurrentLevel:    string(s.level),
+		CurrentLevel:    string(level),
 		BackendType:     s.config.Backend,
 		AsyncMode:       s.config.Async,
-	}, nil
+	}
+	if s.entries != nil {
+		metrics.BufferSize = s.entries.Len()
+		metrics.BufferCapacity = s.entries.Capacity()
+		metrics.OverflowPolicy = string(s.entries.policy)
+		metrics.DroppedEntries = s.entries.Dropped()
+		metrics.FailedEntries = s.entries.Failed()
+	}
+	if s.audits != nil {
+		metrics.AuditBufferSize = s.audits.Len()
+		metrics.FailedEntries += s.audits.Failed()
+	}
+	return metrics, nil
 }
 
 // Private helper methods
@@ -293,73 +307,52 @@ func (s *Service) matchesFilter(entry *LogEntry, filter *QueryRequest) bool {
 		return false
 	}
 
-	return true
+	return MatchesPrincipal(entry, filter)
 }
 
-func (s *Service) bufferLog(entry *LogEntry) error {
-	s.bufferMu.Lock()
-	defer s.bufferMu.Unlock()
-
-	s.buffer = append(s.buffer, entry)
-
-	// Check if buffer is full
-	if len(s.buffer) >= s.config.BatchSize {
-		return s.flushBufferLocked(context.Background())
-	}
+// startAsyncWriters buffers log entries and audit records in bounded
+// queues written to storage in batches by background goroutines. Log
+// entries follow the configured overflow policy. Audit records always wait
+// for room, and one that still finds the queue full is written directly,
+// so that audit records are never dropped.
+func (s *Service) startAsyncWriters() {
+	config := writerConfig{
+		policy:    s.config.OverflowPolicy,
+		capacity:  s.config.BufferSize,
+		batchSize: s.config.BatchSize,
+		interval:  s.config.FlushInterval,
+		timeout:   s.config.OverflowTimeout,
+	}
+	s.entries = newAsyncWriter(config, s.backend.StoreBatch, func(n int64) {
+		s.emitMetric(&types.Metric{
+			Timestamp: time.Now(),
+			Name:      "logging_entries_dropped_total",
+			Type:      types.MetricTypeCounter,
+			Value:     float64(n),
+			Tags:      map[string]string{"policy": string(s.entries.policy)},
+		})
+	})
 
-	return nil
+	config.policy = OverflowBackpressure
+	s.audits = newAsyncWriter(config, s.storeAudits, nil)
 }
 
-func (s *Service) bufferLogs(entries []*LogEntry) error {
-	s.bufferMu.Lock()
-	defer s.bufferMu.Unlock()
-
-	s.buffer = append(s.buffer, entries...)
-
-	// Check if buffer needs flushing
-	if len(s.buffer) >= s.config.BatchSize {
-		return s.flushBufferLocked(context.Background())
+// storeAudits writes buffered audit records in order
+func (s *Service) storeAudits(ctx context.Context, events []*types.AuditLog) error {
+	s.mu.RLock()
+	store := s.auditStore
+	s.mu.RUnlock()
+	if store == nil {
+		return nil
 	}
 
-	return nil
-}
-
-func (s *Service) startBackgroundFlush() {
-	s.wg.Add(1)
-	go func() {
-		defer s.wg.Done()
-		ticker := time.NewTicker(s.config.FlushInterval)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-				s.flushBuffer(ctx)
-				cancel()
-			case <-s.stopCh:
-				return
-			}
+	var errs []error
+	for _, event := range events {
+		if err := store.LogAudit(event); err != nil {
+			errs = append(errs, err)
 		}
-	}()
-}
-
-func (s *Service) flushBuffer(ctx context.Context) error {
-	s.bufferMu.Lock()
-	defer s.bufferMu.Unlock()
-	return s.flushBufferLocked(ctx)
-}
-
-func (s *Service) flushBufferLocked(ctx context.Context) error {
-	if len(s.buffer) == 0 {
-		return nil
 	}
-
-	batch := make([]*LogEntry, len(s.buffer))
-	copy(batch, s.buffer)
-	s.buffer = s.buffer[:0] // Clear buffer
-
-	return s.backend.StoreBatch(ctx, batch)
+	return errors.Join(errs...)
 }
 
 // LogRequest logs an HTTP request
@@ -369,14 +362,29 @@ func (s *Service) LogRequest(ctx context.Context, req *types.LogEntry) error {
 		Message:   fmt.Sprintf("%s %s", req.Method, req.Path),
 		Data:      req.Data,
 		Timestamp: time.Now(),
-		Logger:    "request",
+		Logger:    LoggerRequest,
 	})
 }
 
 // LogAudit logs an audit event
 func (s *Service) LogAudit(ctx context.Context, event *types.AuditLog) error {
-	return s.Log(ctx, &LogEntry{
-		Level:      LogLevelInfo,
+	if event.Severity == "" {
+		event.Severity = types.AuditSeverityFor(event.Action, event.Resource, event.Success)
+	}
+
+	s.mu.RLock()
+	store := s.auditStore
+	s.mu.RUnlock()
+
+	var storeErr error
+	if store != nil {
+		if s.audits == nil || s.audits.Write(ctx, event) != nil {
+			storeErr = store.LogAudit(event)
+		}
+	}
+
+	if err := s.Log(ctx, &LogEntry{
+		Level:      auditLogLevel(event.Severity),
 		Message:    event.Action,
 		Data:       event.Details,
 		Timestamp:  time.Now(),
@@ -385,11 +393,61 @@ func (s *Service) LogAudit(ctx context.Context, event *types.AuditLog) error {
 		OrgID:      event.OrganizationID,
 		EntityType: event.Resource,
 		EntityID:   event.ResourceID,
-	})
+	}); err != nil {
+		return err
+	}
+	return storeErr
+}
+
+// auditLogLevel maps an audit severity tier to a log level
+func auditLogLevel(severity string) LogLevel {
+	switch severity {
+	case types.AuditSeverityNotice:
+		return LogLevelNotice
+	case types.AuditSeverityWarning:
+		return LogLevelWarning
+	case types.AuditSeverityCritical:
+		return LogLevelCritical
+	default:
+		return LogLevelInfo
+	}
+}
+
+// SetAuditStore configures durable storage for audit records
+func (s *Service) SetAuditStore(store AuditStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auditStore = store
+}
+
+// AddMetricEmitter registers an external metrics sink such as StatsD
+func (s *Service) AddMetricEmitter(emitter MetricEmitter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emitters = append(s.emitters, emitter)
+}
+
+// emitMetric forwards a metric to the registered emitters. Emitter errors
+// are dropped so that an unreachable agent never fails a request.
+func (s *Service) emitMetric(metric *types.Metric) {
+	s.mu.RLock()
+	emitters := s.emitters
+	s.mu.RUnlock()
+	for _, emitter := range emitters {
+		_ = emitter.Emit(metric)
+	}
 }
 
-// LogMetric logs a metric event
+// EmitMetric forwards a metric to registered emitters without logging it,
+// for high-frequency counters that would flood the log
+func (s *Service) EmitMetric(metric *types.Metric) {
+	s.emitMetric(metric)
+}
+
+// LogMetric logs a metric event and forwards it to registered emitters
 func (s *Service) LogMetric(ctx context.Context, metric *types.Metric) error {
+	s.emitMetric(metric)
+
 	return s.Log(ctx, &LogEntry{
 		Level:     LogLevelInfo,
 		Message:   metric.Name,
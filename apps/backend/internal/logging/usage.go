@@ -0,0 +1,476 @@
+package logging
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Logger names used for attributed usage entries
+const (
+	LoggerRequest       = "request"
+	LoggerToolExecution = "tool_execution"
+)
+
+// UsageCounts holds the request and tool execution totals shared by the
+// principal and label usage summaries
+type UsageCounts struct {
+	LastSeen       time.Time `json:"last_seen"`
+	Requests       int64     `json:"requests"`
+	ToolExecutions int64     `json:"tool_executions"`
+	Errors         int64     `json:"errors"`
+	AvgDurationMS  float64   `json:"avg_duration_ms"`
+	totalDuration  float64
+	timedEntries   int64
+}
+
+// PrincipalUsage summarizes the activity attributed to a single principal
+type PrincipalUsage struct {
+	Principal types.Principal `json:"principal"`
+	Key       string          `json:"key"`
+	UsageCounts
+}
+
+// LabelUsage summarizes the activity attributed to a single label value
+type LabelUsage struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+	UsageCounts
+}
+
+// ClientUsage summarizes the requests sent by one MCP client family
+type ClientUsage struct {
+	Versions map[string]int64 `json:"versions,omitempty"`
+	Client   string           `json:"client"`
+	UsageCounts
+}
+
+// ServerUsage summarizes the requests routed to one MCP server
+type ServerUsage struct {
+	ServerID  string  `json:"server_id"`
+	ErrorRate float64 `json:"error_rate"`
+	UsageCounts
+}
+
+// ToolUsage summarizes the executions of one tool
+type ToolUsage struct {
+	Tool string `json:"tool"`
+	UsageCounts
+}
+
+// ToolExecutionRecord describes a single tool invocation for attribution
+type ToolExecutionRecord struct {
+	StartedAt   time.Time
+	Principal   *types.Principal
+	NamespaceID string
+	Tool        string
+	Error       string
+	Duration    time.Duration
+	Success     bool
+	Sandbox     bool
+	// Region is the data residency region the namespace is pinned to
+	Region string
+}
+
+// LogToolExecution records a tool invocation attributed to the calling principal
+func (s *Service) LogToolExecution(ctx context.Context, record *ToolExecutionRecord) error {
+	principal := record.Principal
+	if principal == nil {
+		principal = types.PrincipalFromContext(ctx)
+	}
+
+	level := LogLevelInfo
+	if !record.Success {
+		level = LogLevelWarning
+	}
+
+	entry := &LogEntry{
+		Timestamp:  record.StartedAt,
+		Level:      level,
+		Message:    "Tool execution",
+		Logger:     LoggerToolExecution,
+		EntityType: "tool",
+		EntityName: record.Tool,
+		Principal:  principal,
+		Data: map[string]interface{}{
+			"tool":         record.Tool,
+			"namespace_id": record.NamespaceID,
+			"duration_ms":  float64(record.Duration.Microseconds()) / 1000,
+			"success":      record.Success,
+		},
+	}
+	if principal != nil {
+		entry.UserID = principal.UserID
+		entry.OrgID = principal.OrganizationID
+	}
+	if record.Error != "" {
+		entry.Data["error"] = record.Error
+	}
+	if record.Region != "" {
+		entry.Data["residency_region"] = record.Region
+	}
+
+	// Sandbox calls go to separate metrics so production stats stay clean
+	metricPrefix := ""
+	if record.Sandbox {
+		entry.Data["sandbox"] = true
+		entry.Data["billable"] = false
+		metricPrefix = "sandbox_"
+	}
+
+	tags := map[string]string{
+		"tool":    record.Tool,
+		"success": strconv.FormatBool(record.Success),
+	}
+	if principal != nil {
+		tags["principal_type"] = principal.Type
+	}
+	s.emitMetric(&types.Metric{
+		Timestamp:      record.StartedAt,
+		Name:           metricPrefix + "tool_executions_total",
+		Type:           types.MetricTypeCounter,
+		Value:          1,
+		Tags:           tags,
+		OrganizationID: entry.OrgID,
+	})
+	s.emitMetric(&types.Metric{
+		Timestamp:      record.StartedAt,
+		Name:           metricPrefix + "tool_execution_duration",
+		Type:           types.MetricTypeHistogram,
+		Value:          float64(record.Duration.Microseconds()) / 1000,
+		Tags:           tags,
+		OrganizationID: entry.OrgID,
+	})
+
+	return s.Log(ctx, entry)
+}
+
+// GetUsageByPrincipal aggregates request and tool execution activity per principal
+func (s *Service) GetUsageByPrincipal(ctx context.Context, query *QueryRequest) ([]*PrincipalUsage, error) {
+	if query == nil {
+		query = &QueryRequest{}
+	}
+
+	entries, err := s.backend.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return SummarizeByPrincipal(entries), nil
+}
+
+// SummarizeByPrincipal groups log entries by principal. Entries that are
+// neither request nor tool execution logs are ignored.
+func SummarizeByPrincipal(entries []*LogEntry) []*PrincipalUsage {
+	usage := make(map[string]*PrincipalUsage)
+
+	for _, entry := range entries {
+		if !isUsageEntry(entry) {
+			continue
+		}
+
+		key := entry.Principal.Key()
+		summary, exists := usage[key]
+		if !exists {
+			summary = &PrincipalUsage{Key: key}
+			if entry.Principal != nil {
+				summary.Principal = *entry.Principal
+			} else {
+				summary.Principal = types.Principal{Type: types.PrincipalTypeAnonymous}
+			}
+			usage[key] = summary
+		}
+		summary.add(entry)
+	}
+
+	result := make([]*PrincipalUsage, 0, len(usage))
+	for _, summary := range usage {
+		summary.finalize()
+		result = append(result, summary)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		ti, tj := result[i].total(), result[j].total()
+		if ti != tj {
+			return ti > tj
+		}
+		return result[i].Key < result[j].Key
+	})
+
+	return result
+}
+
+// GetUsageByLabel aggregates request and tool execution activity per value of a label
+func (s *Service) GetUsageByLabel(ctx context.Context, label string, query *QueryRequest) ([]*LabelUsage, error) {
+	if query == nil {
+		query = &QueryRequest{}
+	}
+
+	entries, err := s.backend.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return SummarizeByLabel(entries, label), nil
+}
+
+// SummarizeByLabel groups log entries by the value of the given label.
+// Entries without the label are grouped under an empty value.
+func SummarizeByLabel(entries []*LogEntry, label string) []*LabelUsage {
+	usage := make(map[string]*LabelUsage)
+
+	for _, entry := range entries {
+		if !isUsageEntry(entry) {
+			continue
+		}
+
+		var value string
+		if entry.Principal != nil {
+			value = entry.Principal.Labels[label]
+		}
+
+		summary, exists := usage[value]
+		if !exists {
+			summary = &LabelUsage{Label: label, Value: value}
+			usage[value] = summary
+		}
+		summary.add(entry)
+	}
+
+	result := make([]*LabelUsage, 0, len(usage))
+	for _, summary := range usage {
+		summary.finalize()
+		result = append(result, summary)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		ti, tj := result[i].total(), result[j].total()
+		if ti != tj {
+			return ti > tj
+		}
+		return result[i].Value < result[j].Value
+	})
+
+	return result
+}
+
+// GetUsageByClient aggregates request activity per detected MCP client
+func (s *Service) GetUsageByClient(ctx context.Context, query *QueryRequest) ([]*ClientUsage, error) {
+	if query == nil {
+		query = &QueryRequest{}
+	}
+
+	entries, err := s.backend.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return SummarizeByClient(entries), nil
+}
+
+// SummarizeByClient groups request log entries by the client detected from
+// the User-Agent. Requests logged before detection existed count as unknown.
+func SummarizeByClient(entries []*LogEntry) []*ClientUsage {
+	usage := make(map[string]*ClientUsage)
+
+	for _, entry := range entries {
+		if entry.Logger != LoggerRequest || !isUsageEntry(entry) {
+			continue
+		}
+
+		client, _ := entry.Data["client"].(string)
+		if client == "" {
+			client = types.ClientUnknown
+		}
+
+		summary, exists := usage[client]
+		if !exists {
+			summary = &ClientUsage{Client: client}
+			usage[client] = summary
+		}
+		summary.add(entry)
+
+		if version, _ := entry.Data["client_version"].(string); version != "" {
+			if summary.Versions == nil {
+				summary.Versions = make(map[string]int64)
+			}
+			summary.Versions[version] += sampleWeight(entry)
+		}
+	}
+
+	result := make([]*ClientUsage, 0, len(usage))
+	for _, summary := range usage {
+		summary.finalize()
+		result = append(result, summary)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		ti, tj := result[i].total(), result[j].total()
+		if ti != tj {
+			return ti > tj
+		}
+		return result[i].Client < result[j].Client
+	})
+
+	return result
+}
+
+// GetUsageByServer aggregates request activity and error rates per MCP server
+func (s *Service) GetUsageByServer(ctx context.Context, query *QueryRequest) ([]*ServerUsage, error) {
+	if query == nil {
+		query = &QueryRequest{}
+	}
+
+	entries, err := s.backend.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return SummarizeByServer(entries), nil
+}
+
+// SummarizeByServer groups request log entries by the MCP server they were
+// routed to, highest error rate first. Requests not bound to a server are
+// ignored.
+func SummarizeByServer(entries []*LogEntry) []*ServerUsage {
+	usage := make(map[string]*ServerUsage)
+
+	for _, entry := range entries {
+		if entry.Logger != LoggerRequest || !isUsageEntry(entry) {
+			continue
+		}
+
+		serverID, _ := entry.Data["server_id"].(string)
+		if serverID == "" {
+			continue
+		}
+
+		summary, exists := usage[serverID]
+		if !exists {
+			summary = &ServerUsage{ServerID: serverID}
+			usage[serverID] = summary
+		}
+		summary.add(entry)
+	}
+
+	result := make([]*ServerUsage, 0, len(usage))
+	for _, summary := range usage {
+		summary.finalize()
+		if summary.Requests > 0 {
+			summary.ErrorRate = float64(summary.Errors) / float64(summary.Requests)
+		}
+		result = append(result, summary)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ErrorRate != result[j].ErrorRate {
+			return result[i].ErrorRate > result[j].ErrorRate
+		}
+		if result[i].Requests != result[j].Requests {
+			return result[i].Requests > result[j].Requests
+		}
+		return result[i].ServerID < result[j].ServerID
+	})
+
+	return result
+}
+
+// GetUsageByTool aggregates tool execution activity per tool
+func (s *Service) GetUsageByTool(ctx context.Context, query *QueryRequest) ([]*ToolUsage, error) {
+	if query == nil {
+		query = &QueryRequest{}
+	}
+
+	entries, err := s.backend.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return SummarizeByTool(entries), nil
+}
+
+// SummarizeByTool groups tool execution log entries by tool, most executed
+// first
+func SummarizeByTool(entries []*LogEntry) []*ToolUsage {
+	usage := make(map[string]*ToolUsage)
+
+	for _, entry := range entries {
+		if entry.Logger != LoggerToolExecution || !isUsageEntry(entry) {
+			continue
+		}
+
+		tool, _ := entry.Data["tool"].(string)
+		if tool == "" {
+			tool = entry.EntityName
+		}
+
+		summary, exists := usage[tool]
+		if !exists {
+			summary = &ToolUsage{Tool: tool}
+			usage[tool] = summary
+		}
+		summary.add(entry)
+	}
+
+	result := make([]*ToolUsage, 0, len(usage))
+	for _, summary := range usage {
+		summary.finalize()
+		result = append(result, summary)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ToolExecutions != result[j].ToolExecutions {
+			return result[i].ToolExecutions > result[j].ToolExecutions
+		}
+		return result[i].Tool < result[j].Tool
+	})
+
+	return result
+}
+
+// isUsageEntry reports whether an entry counts towards usage summaries.
+// Sandbox traffic from the endpoint test console never does.
+func isUsageEntry(entry *LogEntry) bool {
+	if sandbox, _ := entry.Data["sandbox"].(bool); sandbox {
+		return false
+	}
+	return entry.Logger == LoggerRequest || entry.Logger == LoggerToolExecution
+}
+
+// add counts an entry, as the number of requests it stands for when
+// request logging was sampled
+func (u *UsageCounts) add(entry *LogEntry) {
+	weight := sampleWeight(entry)
+	if entry.Logger == LoggerToolExecution {
+		u.ToolExecutions++
+		if success, ok := entry.Data["success"].(bool); ok && !success {
+			u.Errors++
+		}
+	} else {
+		u.Requests += weight
+		if entry.StatusCode >= 400 {
+			u.Errors += weight
+		}
+	}
+
+	if d, ok := entry.Data["duration_ms"].(float64); ok {
+		u.totalDuration += d * float64(weight)
+		u.timedEntries += weight
+	}
+	if entry.Timestamp.After(u.LastSeen) {
+		u.LastSeen = entry.Timestamp
+	}
+}
+
+func (u *UsageCounts) finalize() {
+	if u.timedEntries > 0 {
+		u.AvgDurationMS = u.totalDuration / float64(u.timedEntries)
+	}
+}
+
+func (u *UsageCounts) total() int64 {
+	return u.Requests + u.ToolExecutions
+}
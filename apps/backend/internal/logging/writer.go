@@ -0,0 +1,280 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what an async writer does with a new entry while
+// its buffer is full
+type OverflowPolicy string
+
+const (
+	// OverflowDropOldest discards the oldest buffered entry to make room, so
+	// writers never wait on a slow backend
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+
+	// OverflowBackpressure makes writers wait for room, up to the overflow
+	// timeout or until their context ends, and drops the new entry if none
+	// frees up
+	OverflowBackpressure OverflowPolicy = "backpressure"
+)
+
+// IsValid reports whether the policy is known; empty selects the default
+func (p OverflowPolicy) IsValid() bool {
+	switch p {
+	case "", OverflowDropOldest, OverflowBackpressure:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	defaultWriterCapacity      = 10000
+	defaultWriterBatchSize     = 100
+	defaultWriterFlushInterval = time.Second
+	writerFlushTimeout         = 30 * time.Second
+)
+
+// ErrBufferFull is returned when an entry is dropped because the buffer
+// stayed full
+var ErrBufferFull = errors.New("log buffer full")
+
+// ErrWriterClosed is returned for entries written after the writer closed
+var ErrWriterClosed = errors.New("log writer closed")
+
+// asyncWriter buffers entries in a bounded queue and hands them to flush in
+// batches from a single goroutine, keeping storage off the request path.
+// A batch is flushed once batchSize entries are queued or flushInterval
+// passes, whichever comes first. Entries keep their write order.
+type asyncWriter[T any] struct {
+	flush    func(ctx context.Context, batch []T) error
+	onDrop   func(n int64)
+	queue    chan T
+	full     chan struct{}
+	stopCh   chan struct{}
+	done     chan struct{}
+	policy   OverflowPolicy
+	timeout  time.Duration
+	interval time.Duration
+	batch    int
+	dropped  atomic.Int64
+	failed   atomic.Int64
+	closeMu  sync.RWMutex
+	closed   bool
+}
+
+// writerConfig sizes an async writer
+type writerConfig struct {
+	policy    OverflowPolicy
+	capacity  int
+	batchSize int
+	interval  time.Duration
+	// timeout bounds how long backpressure holds a writer; zero waits until
+	// the writer's context ends
+	timeout time.Duration
+}
+
+// newAsyncWriter starts a writer storing batches with flush. Zero sizes and
+// intervals fall back to defaults. onDrop, if set, is told after each flush
+// interval how many entries were dropped since it was last called.
+func newAsyncWriter[T any](config writerConfig, flush func(ctx context.Context, batch []T) error, onDrop func(n int64)) *asyncWriter[T] {
+	if config.capacity <= 0 {
+		config.capacity = defaultWriterCapacity
+	}
+	if config.batchSize <= 0 {
+		config.batchSize = defaultWriterBatchSize
+	}
+	if config.batchSize > config.capacity {
+		config.batchSize = config.capacity
+	}
+	if config.interval <= 0 {
+		config.interval = defaultWriterFlushInterval
+	}
+	if config.policy == "" {
+		config.policy = OverflowDropOldest
+	}
+
+	w := &asyncWriter[T]{
+		flush:    flush,
+		onDrop:   onDrop,
+		queue:    make(chan T, config.capacity),
+		full:     make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+		policy:   config.policy,
+		timeout:  config.timeout,
+		interval: config.interval,
+		batch:    config.batchSize,
+	}
+	go w.run()
+	return w
+}
+
+// Write queues entries, applying the overflow policy to each that finds the
+// buffer full. It returns ErrBufferFull if any entry was dropped.
+func (w *asyncWriter[T]) Write(ctx context.Context, entries ...T) error {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	var err error
+	for _, entry := range entries {
+		if !w.enqueue(ctx, entry) {
+			err = ErrBufferFull
+		}
+	}
+	if len(w.queue) >= w.batch {
+		select {
+		case w.full <- struct{}{}:
+		default:
+		}
+	}
+	return err
+}
+
+// enqueue queues one entry and reports whether it was kept
+func (w *asyncWriter[T]) enqueue(ctx context.Context, entry T) bool {
+	select {
+	case w.queue <- entry:
+		return true
+	default:
+	}
+
+	if w.policy == OverflowDropOldest {
+		for {
+			select {
+			case <-w.queue:
+				w.dropped.Add(1)
+			default:
+			}
+			select {
+			case w.queue <- entry:
+				return true
+			default:
+			}
+		}
+	}
+
+	var expired <-chan time.Time
+	if w.timeout > 0 {
+		timer := time.NewTimer(w.timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case w.queue <- entry:
+		return true
+	case <-expired:
+	case <-ctx.Done():
+	}
+	w.dropped.Add(1)
+	return false
+}
+
+// run collects queued entries into batches until the writer closes, then
+// flushes whatever is left
+func (w *asyncWriter[T]) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var reported int64
+	for {
+		select {
+		case <-w.full:
+			w.drain(false)
+		case <-ticker.C:
+			w.drain(true)
+			if dropped := w.dropped.Load(); dropped > reported && w.onDrop != nil {
+				w.onDrop(dropped - reported)
+				reported = dropped
+			}
+		case <-w.stopCh:
+			w.drain(true)
+			return
+		}
+	}
+}
+
+// drain flushes queued entries in batches. Without partial it leaves a
+// trailing batch smaller than batchSize for the next tick.
+func (w *asyncWriter[T]) drain(partial bool) {
+	for {
+		n := len(w.queue)
+		if n == 0 || (!partial && n < w.batch) {
+			return
+		}
+		if n > w.batch {
+			n = w.batch
+		}
+
+		batch := make([]T, 0, n)
+		for len(batch) < n {
+			select {
+			case entry := <-w.queue:
+				batch = append(batch, entry)
+			default:
+				n = len(batch)
+			}
+		}
+		if len(batch) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), writerFlushTimeout)
+		if err := w.flush(ctx, batch); err != nil {
+			w.failed.Add(int64(len(batch)))
+			log.Printf("Logging: failed to write batch of %d entries: %v", len(batch), err)
+		}
+		cancel()
+	}
+}
+
+// Len returns the number of queued entries
+func (w *asyncWriter[T]) Len() int {
+	return len(w.queue)
+}
+
+// Capacity returns the most entries the buffer holds
+func (w *asyncWriter[T]) Capacity() int {
+	return cap(w.queue)
+}
+
+// Dropped returns how many entries the overflow policy has discarded
+func (w *asyncWriter[T]) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// Failed returns how many entries were lost to failed flushes
+func (w *asyncWriter[T]) Failed() int64 {
+	return w.failed.Load()
+}
+
+// Close stops accepting entries and waits for the queued ones to be
+// flushed, or for ctx to end
+func (w *asyncWriter[T]) Close(ctx context.Context) error {
+	w.closeMu.Lock()
+	if w.closed {
+		w.closeMu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.closeMu.Unlock()
+
+	close(w.stopCh)
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("log writer did not finish flushing: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
+)
+
+// GRPCTransport implements the Transport interface for MCP servers that
+// exchange JSON-RPC frames over a gRPC session stream
+type GRPCTransport struct{}
+
+// Type returns the transport type identifier
+func (gt *GRPCTransport) Type() string {
+	return "grpc"
+}
+
+// Connect opens a gRPC session stream to an MCP server
+func (gt *GRPCTransport) Connect(ctx context.Context, config TransportConfig) (Connection, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("url is required for grpc transport")
+	}
+
+	stream, err := transport.DialGRPCStream(ctx, config.URL, config.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &GRPCConnection{
+		stream:    stream,
+		connected: true,
+		messages:  make(chan []byte, 100),
+		errors:    make(chan error, 1),
+		closed:    make(chan struct{}),
+	}
+	go conn.readLoop()
+
+	return conn, nil
+}
+
+// GRPCConnection represents a gRPC session stream to an MCP server
+type GRPCConnection struct {
+	stream    *transport.GRPCStream
+	connected bool
+	mu        sync.RWMutex
+	messages  chan []byte
+	errors    chan error
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Send sends a message to the MCP server as one frame
+func (gc *GRPCConnection) Send(ctx context.Context, message []byte) error {
+	if !gc.IsConnected() {
+		return fmt.Errorf("connection is closed")
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return gc.stream.Send(message)
+	}
+}
+
+// Receive waits for the next frame from the MCP server. Streams carry
+// notifications as well as responses, so there is no idle timeout.
+func (gc *GRPCConnection) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case err := <-gc.errors:
+		return nil, err
+	case message := <-gc.messages:
+		return message, nil
+	case <-gc.closed:
+		return nil, fmt.Errorf("connection is closed")
+	}
+}
+
+// Close ends the session stream
+func (gc *GRPCConnection) Close() error {
+	var err error
+	gc.closeOnce.Do(func() {
+		gc.mu.Lock()
+		gc.connected = false
+		gc.mu.Unlock()
+
+		close(gc.closed)
+		err = gc.stream.Close()
+	})
+	return err
+}
+
+// IsConnected returns whether the connection is active
+func (gc *GRPCConnection) IsConnected() bool {
+	gc.mu.RLock()
+	defer gc.mu.RUnlock()
+	return gc.connected
+}
+
+// readLoop forwards frames from the stream to the messages channel
+func (gc *GRPCConnection) readLoop() {
+	defer func() {
+		gc.mu.Lock()
+		gc.connected = false
+		gc.mu.Unlock()
+	}()
+
+	for {
+		frame, err := gc.stream.Recv()
+		if err != nil {
+			select {
+			case gc.errors <- fmt.Errorf("grpc stream ended: %w", err):
+			default:
+			}
+			return
+		}
+
+		select {
+		case gc.messages <- frame:
+		case <-gc.closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// localHandler answers one request of an in-process server and returns how
+// long to hold the answer back
+type localHandler func(ctx context.Context, req localRequest) (interface{}, time.Duration, *types.MCPError)
+
+// localRequest is a JSON-RPC request as an in-process server sees it. The
+// ID is kept raw so that responses echo it unchanged.
+type localRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Method string          `json:"method"`
+}
+
+// localResponse is a JSON-RPC response of an in-process server
+type localResponse struct {
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *types.MCPError `json:"error,omitempty"`
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// localConnection is a connection to a server the gateway answers itself,
+// such as a mock server or a replay of recorded responses
+type localConnection struct {
+	handle    localHandler
+	connected bool
+	mu        sync.RWMutex
+	messages  chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newLocalConnection(handle localHandler) *localConnection {
+	return &localConnection{
+		handle:    handle,
+		connected: true,
+		messages:  make(chan []byte, 100),
+		closed:    make(chan struct{}),
+	}
+}
+
+// Send hands a request to the server. Each request is answered on its own
+// so that a delayed answer does not hold up the others.
+func (lc *localConnection) Send(ctx context.Context, message []byte) error {
+	if !lc.IsConnected() {
+		return fmt.Errorf("connection is closed")
+	}
+
+	var req localRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		return fmt.Errorf("invalid JSON-RPC message: %w", err)
+	}
+	if len(req.ID) == 0 {
+		// Notifications need no answer
+		return nil
+	}
+
+	go lc.respond(ctx, req)
+	return nil
+}
+
+// Receive waits for the next response of the server
+func (lc *localConnection) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case message := <-lc.messages:
+		return message, nil
+	case <-lc.closed:
+		return nil, fmt.Errorf("connection is closed")
+	}
+}
+
+// Close stops the server
+func (lc *localConnection) Close() error {
+	lc.closeOnce.Do(func() {
+		lc.mu.Lock()
+		lc.connected = false
+		lc.mu.Unlock()
+
+		close(lc.closed)
+	})
+	return nil
+}
+
+// IsConnected returns whether the connection is active
+func (lc *localConnection) IsConnected() bool {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	return lc.connected
+}
+
+// respond answers req and queues the response for Receive
+func (lc *localConnection) respond(ctx context.Context, req localRequest) {
+	response := localResponse{JSONRPC: "2.0", ID: req.ID}
+	result, delay, mcpErr := lc.handle(ctx, req)
+	if mcpErr != nil {
+		response.Error = mcpErr
+	} else {
+		response.Result = result
+	}
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		case <-lc.closed:
+			return
+		}
+	}
+
+	message, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	select {
+	case lc.messages <- message:
+	case <-lc.closed:
+	}
+}
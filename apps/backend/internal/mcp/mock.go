@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// MockTransport implements the Transport interface for mock MCP servers,
+// which the gateway answers itself from canned tool definitions
+type MockTransport struct{}
+
+// Type returns the transport type identifier
+func (mt *MockTransport) Type() string {
+	return "mock"
+}
+
+// Connect opens an in-process connection that serves the mock tools of
+// config.MockTools
+func (mt *MockTransport) Connect(ctx context.Context, config TransportConfig) (Connection, error) {
+	if config.MockTools == nil {
+		return nil, fmt.Errorf("mock tools are required for mock transport")
+	}
+
+	server := &mockServer{tools: config.MockTools}
+	return newLocalConnection(server.handle), nil
+}
+
+// mockServer answers MCP requests from canned tool definitions. Tools are
+// loaded on every request so that edits apply to open sessions.
+type mockServer struct {
+	tools func(ctx context.Context) ([]types.MockTool, error)
+}
+
+// handle answers one request and returns how long to hold the answer back
+func (ms *mockServer) handle(ctx context.Context, req localRequest) (interface{}, time.Duration, *types.MCPError) {
+	switch req.Method {
+	case "initialize":
+		var params InitializeParams
+		_ = json.Unmarshal(req.Params, &params)
+		version := params.ProtocolVersion
+		if version == "" {
+			version = "2024-11-05"
+		}
+		return InitializeResult{
+			ProtocolVersion: version,
+			Capabilities:    map[string]interface{}{"tools": map[string]interface{}{}},
+			ServerInfo:      ServerInfo{Name: "omnimesh-mock", Version: "1.0.0"},
+		}, 0, nil
+
+	case "ping":
+		return map[string]interface{}{}, 0, nil
+
+	case "tools/list":
+		tools, err := ms.tools(ctx)
+		if err != nil {
+			return nil, 0, &types.MCPError{Code: types.MCPErrorCodeInternalError, Message: "failed to load mock tools: " + err.Error()}
+		}
+		result := ToolsListResult{Tools: make([]types.Tool, 0, len(tools))}
+		for _, tool := range tools {
+			schema := tool.InputSchema
+			if schema == nil {
+				schema = map[string]interface{}{"type": "object"}
+			}
+			result.Tools = append(result.Tools, types.Tool{
+				Name:        tool.Name,
+				Description: tool.Description,
+				InputSchema: schema,
+			})
+		}
+		return result, 0, nil
+
+	case "tools/call":
+		var params ToolsCallParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, 0, &types.MCPError{Code: types.MCPErrorCodeInvalidParams, Message: "invalid tools/call params"}
+		}
+		tools, err := ms.tools(ctx)
+		if err != nil {
+			return nil, 0, &types.MCPError{Code: types.MCPErrorCodeInternalError, Message: "failed to load mock tools: " + err.Error()}
+		}
+		for i := range tools {
+			if tools[i].Name == params.Name {
+				return MockToolResult(&tools[i], params.Arguments), time.Duration(tools[i].DelayMs) * time.Millisecond, nil
+			}
+		}
+		return nil, 0, &types.MCPError{Code: types.MCPErrorCodeInvalidParams, Message: "unknown tool: " + params.Name}
+
+	case "resources/list":
+		return map[string]interface{}{"resources": []interface{}{}}, 0, nil
+
+	case "prompts/list":
+		return map[string]interface{}{"prompts": []interface{}{}}, 0, nil
+
+	default:
+		return nil, 0, &types.MCPError{Code: types.MCPErrorCodeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+}
+
+// MockToolResult renders the canned response of tool for a call with
+// arguments. A response that renders to a JSON object is also returned as
+// structured content. Template errors become tool errors so that a broken
+// fixture shows up in the caller's result.
+func MockToolResult(tool *types.MockTool, arguments map[string]interface{}) ToolsCallResult {
+	text, err := RenderMockResponse(tool, arguments)
+	if err != nil {
+		return ToolsCallResult{
+			Content: []ToolCallContent{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}
+	}
+
+	result := ToolsCallResult{
+		Content: []ToolCallContent{{Type: "text", Text: text}},
+		IsError: tool.IsError,
+	}
+	if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "{") {
+		var structured map[string]interface{}
+		if json.Unmarshal([]byte(trimmed), &structured) == nil {
+			result.StructuredContent = structured
+		}
+	}
+	return result
+}
+
+// RenderMockResponse renders the response template of tool. The template
+// sees the call's arguments as .arguments and the tool's name as .tool.
+func RenderMockResponse(tool *types.MockTool, arguments map[string]interface{}) (string, error) {
+	tmpl, err := ParseMockResponse(tool.Response)
+	if err != nil {
+		return "", err
+	}
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+
+	var out bytes.Buffer
+	data := map[string]interface{}{"arguments": arguments, "tool": tool.Name}
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render mock response of %s: %w", tool.Name, err)
+	}
+	return out.String(), nil
+}
+
+// ParseMockResponse parses a mock tool's response template
+func ParseMockResponse(response string) (*template.Template, error) {
+	tmpl, err := template.New("response").Funcs(mockTemplateFuncs).Parse(response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response template: %w", err)
+	}
+	return tmpl, nil
+}
+
+var mockTemplateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	},
+}
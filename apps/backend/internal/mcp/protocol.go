@@ -8,17 +8,27 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// requestTimeout is how long a request waits for its response. Progress
+// notifications of a tool call restart the wait, and requests whose context
+// has a later deadline wait until it instead.
+const requestTimeout = 30 * time.Second
+
 // MCPClient implements the MCP protocol over a transport connection
 type MCPClient struct {
 	connection Connection
 	transport  Transport
 
 	// Request tracking
-	pendingRequests sync.Map // map[string]chan *JSONRPCResponse
-	requestID       int64
+	pendingRequests  sync.Map // map[string]chan *JSONRPCResponse
+	progressHandlers sync.Map // map[string]*progressSubscription
+	requestID        int64
 
 	// Server capabilities
 	serverCapabilities map[string]interface{}
@@ -34,6 +44,13 @@ type MCPClient struct {
 	messageHandler func(message []byte)
 }
 
+// progressSubscription receives the progress notifications of a tool call
+// and signals activity that restarts the call's timeout
+type progressSubscription struct {
+	report   func(types.ToolProgress)
+	activity chan struct{}
+}
+
 // ClientInfo represents information about the MCP client
 type ClientInfo struct {
 	Name         string                 `json:"name"`
@@ -84,14 +101,16 @@ type ToolsListResult struct {
 
 // ToolsCallParams represents parameters for tools/call
 type ToolsCallParams struct {
+	Meta      map[string]interface{} `json:"_meta,omitempty"`
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
 }
 
 // ToolsCallResult represents the result of tools/call
 type ToolsCallResult struct {
-	Content []ToolCallContent `json:"content"`
-	IsError bool              `json:"isError,omitempty"`
+	StructuredContent interface{}       `json:"structuredContent,omitempty"`
+	Content           []ToolCallContent `json:"content"`
+	IsError           bool              `json:"isError,omitempty"`
 }
 
 // ToolCallContent represents content returned by a tool call
@@ -178,8 +197,25 @@ func (c *MCPClient) ListTools(ctx context.Context) ([]types.Tool, error) {
 	return result.Tools, nil
 }
 
+// ToolError reports a tool call that the server answered with a tool
+// error result
+type ToolError struct {
+	Content []ToolCallContent
+}
+
+func (e *ToolError) Error() string {
+	return fmt.Sprintf("tool execution failed: %v", e.Content)
+}
+
 // CallTool sends a tools/call request to execute a tool
 func (c *MCPClient) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (interface{}, error) {
+	return c.CallToolWithMeta(ctx, name, arguments, nil)
+}
+
+// CallToolWithMeta sends a tools/call request that carries meta, such as an
+// idempotency key, in its _meta field. When ctx carries a progress reporter
+// the call asks the server for progress notifications and reports them.
+func (c *MCPClient) CallToolWithMeta(ctx context.Context, name string, arguments, meta map[string]interface{}) (interface{}, error) {
 	c.mu.RLock()
 	if !c.initialized {
 		c.mu.RUnlock()
@@ -187,19 +223,35 @@ func (c *MCPClient) CallTool(ctx context.Context, name string, arguments map[str
 	}
 	c.mu.RUnlock()
 
+	var activity chan struct{}
+	if report := types.ToolProgressFromContext(ctx); report != nil {
+		token := fmt.Sprintf("progress-%d", atomic.AddInt64(&c.requestID, 1))
+		activity = make(chan struct{}, 1)
+		c.progressHandlers.Store(token, &progressSubscription{report: report, activity: activity})
+		defer c.progressHandlers.Delete(token)
+
+		withToken := make(map[string]interface{}, len(meta)+1)
+		for k, v := range meta {
+			withToken[k] = v
+		}
+		withToken["progressToken"] = token
+		meta = withToken
+	}
+
 	params := ToolsCallParams{
+		Meta:      meta,
 		Name:      name,
 		Arguments: arguments,
 	}
 
 	var result ToolsCallResult
-	if err := c.sendRequest(ctx, "tools/call", params, &result); err != nil {
+	if err := c.sendRequestWithActivity(ctx, "tools/call", params, &result, activity); err != nil {
 		return nil, err
 	}
 
 	// Check if the tool call resulted in an error
 	if result.IsError {
-		return nil, fmt.Errorf("tool execution failed: %v", result.Content)
+		return nil, &ToolError{Content: result.Content}
 	}
 
 	return result, nil
@@ -235,10 +287,29 @@ func (c *MCPClient) generateRequestID() string {
 	return fmt.Sprintf("req-%d", id)
 }
 
-// sendRequest sends a JSON-RPC request and waits for the response
+// sendRequest sends a JSON-RPC request and waits for the response. The
+// request runs in a client span whose trace context travels in the _meta of
+// its params.
 func (c *MCPClient) sendRequest(ctx context.Context, method string, params interface{}, result interface{}) error {
+	return c.sendRequestWithActivity(ctx, method, params, result, nil)
+}
+
+// sendRequestWithActivity sends a request like sendRequest, restarting the
+// wait for its response whenever activity signals
+func (c *MCPClient) sendRequestWithActivity(ctx context.Context, method string, params interface{}, result interface{}, activity <-chan struct{}) (err error) {
 	requestID := c.generateRequestID()
 
+	ctx, span := observability.StartSpan(ctx, "mcp.client "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "jsonrpc"),
+			attribute.String("rpc.method", method),
+			attribute.String("rpc.jsonrpc.request_id", requestID),
+			attribute.String("mcp.transport", c.transport.Type()),
+		))
+	defer func() { observability.EndSpan(span, err) }()
+	params = withTraceContext(ctx, params)
+
 	// Create response channel
 	responseChan := make(chan *JSONRPCResponse, 1)
 	c.pendingRequests.Store(requestID, responseChan)
@@ -264,29 +335,63 @@ func (c *MCPClient) sendRequest(ctx context.Context, method string, params inter
 	}
 
 	// Wait for response
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case response := <-responseChan:
-		if response.Error != nil {
-			return response.Error
-		}
-
-		// Deserialize result if provided
-		if result != nil && response.Result != nil {
-			resultBytes, err := json.Marshal(response.Result)
-			if err != nil {
-				return fmt.Errorf("failed to marshal result: %w", err)
+	timeout := time.NewTimer(requestTimeout)
+	defer timeout.Stop()
+	expired := timeout.C
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) > requestTimeout {
+		expired = nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case response := <-responseChan:
+			if response.Error != nil {
+				return response.Error
 			}
 
-			if err := json.Unmarshal(resultBytes, result); err != nil {
-				return fmt.Errorf("failed to unmarshal result: %w", err)
+			// Deserialize result if provided
+			if result != nil && response.Result != nil {
+				resultBytes, err := json.Marshal(response.Result)
+				if err != nil {
+					return fmt.Errorf("failed to marshal result: %w", err)
+				}
+
+				if err := json.Unmarshal(resultBytes, result); err != nil {
+					return fmt.Errorf("failed to unmarshal result: %w", err)
+				}
 			}
+
+			return nil
+		case <-activity:
+			timeout.Reset(requestTimeout)
+		case <-expired:
+			return fmt.Errorf("request timeout")
 		}
+	}
+}
 
-		return nil
-	case <-time.After(30 * time.Second):
-		return fmt.Errorf("request timeout")
+// withTraceContext adds the trace context of ctx to the _meta of params
+// that carry one, so that servers can continue the gateway's trace
+func withTraceContext(ctx context.Context, params interface{}) interface{} {
+	switch p := params.(type) {
+	case ToolsCallParams:
+		p.Meta = observability.InjectMeta(ctx, p.Meta)
+		return p
+	case map[string]interface{}:
+		existing, _ := p["_meta"].(map[string]interface{})
+		meta := observability.InjectMeta(ctx, existing)
+		if len(meta) == 0 {
+			return params
+		}
+		copied := make(map[string]interface{}, len(p)+1)
+		for k, v := range p {
+			copied[k] = v
+		}
+		copied["_meta"] = meta
+		return copied
+	default:
+		return params
 	}
 }
 
@@ -331,12 +436,51 @@ func (c *MCPClient) handleMessage(messageBytes []byte) {
 		return
 	}
 
+	// Progress of a tool call goes to the call's reporter
+	if c.handleProgress(messageBytes) {
+		return
+	}
+
 	// Handle notifications or other messages
 	if c.messageHandler != nil {
 		c.messageHandler(messageBytes)
 	}
 }
 
+// handleProgress reports a progress notification to the tool call whose
+// token it carries, and reports whether the message was one
+func (c *MCPClient) handleProgress(messageBytes []byte) bool {
+	var notification struct {
+		Params struct {
+			ProgressToken interface{} `json:"progressToken"`
+			Message       string      `json:"message"`
+			Progress      float64     `json:"progress"`
+			Total         float64     `json:"total"`
+		} `json:"params"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(messageBytes, &notification); err != nil ||
+		notification.Method != "notifications/progress" || notification.Params.ProgressToken == nil {
+		return false
+	}
+
+	subscription, ok := c.progressHandlers.Load(fmt.Sprint(notification.Params.ProgressToken))
+	if !ok {
+		return false
+	}
+	sub := subscription.(*progressSubscription)
+	sub.report(types.ToolProgress{
+		Message:  notification.Params.Message,
+		Progress: notification.Params.Progress,
+		Total:    notification.Params.Total,
+	})
+	select {
+	case sub.activity <- struct{}{}:
+	default:
+	}
+	return true
+}
+
 // SetMessageHandler sets a handler for non-response messages
 func (c *MCPClient) SetMessageHandler(handler func(message []byte)) {
 	c.mu.Lock()
@@ -0,0 +1,195 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// RecordingStore keeps the recorded responses of upstream servers
+type RecordingStore interface {
+	SaveRecording(ctx context.Context, recording *types.ServerRecording) error
+	// FindRecording returns the recording of a server with fingerprint, or
+	// nil when there is none
+	FindRecording(ctx context.Context, serverID, fingerprint string) (*types.ServerRecording, error)
+}
+
+// RecordingFingerprint identifies a request by its method and params. Keys
+// of the params are compared in sorted order and _meta, which carries
+// per-call data such as progress tokens, is left out.
+func RecordingFingerprint(method string, params json.RawMessage) (string, error) {
+	var decoded interface{}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &decoded); err != nil {
+			return "", fmt.Errorf("invalid params: %w", err)
+		}
+	}
+	if object, ok := decoded.(map[string]interface{}); ok {
+		delete(object, "_meta")
+		if len(object) == 0 {
+			decoded = nil
+		}
+	}
+
+	// Maps marshal with sorted keys, so equal params encode equally
+	canonical, err := json.Marshal(decoded)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(method+"\n"), canonical...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// RecordingTransport wraps the transport of an upstream server and records
+// every response the server sends
+type RecordingTransport struct {
+	transport Transport
+	serverID  string
+	store     RecordingStore
+}
+
+// NewRecordingTransport records the responses of serverID, reached over
+// transport, in store
+func NewRecordingTransport(transport Transport, serverID string, store RecordingStore) *RecordingTransport {
+	return &RecordingTransport{transport: transport, serverID: serverID, store: store}
+}
+
+// Type returns the type of the wrapped transport
+func (rt *RecordingTransport) Type() string {
+	return rt.transport.Type()
+}
+
+// Connect connects to the upstream server
+func (rt *RecordingTransport) Connect(ctx context.Context, config TransportConfig) (Connection, error) {
+	conn, err := rt.transport.Connect(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingConnection{Connection: conn, serverID: rt.serverID, store: rt.store}, nil
+}
+
+// recordingConnection remembers the requests sent upstream until their
+// responses arrive, then records both
+type recordingConnection struct {
+	Connection
+	serverID string
+	store    RecordingStore
+	pending  sync.Map // request ID -> localRequest
+}
+
+// Send remembers the request and sends it upstream
+func (rc *recordingConnection) Send(ctx context.Context, message []byte) error {
+	var req localRequest
+	if err := json.Unmarshal(message, &req); err == nil && len(req.ID) > 0 && req.Method != "" {
+		rc.pending.Store(string(req.ID), req)
+	}
+	return rc.Connection.Send(ctx, message)
+}
+
+// Receive returns the next upstream message, recording it if it answers a
+// request
+func (rc *recordingConnection) Receive(ctx context.Context) ([]byte, error) {
+	message, err := rc.Connection.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		ID     json.RawMessage `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *types.MCPError `json:"error"`
+		Method string          `json:"method"`
+	}
+	if json.Unmarshal(message, &response) != nil || len(response.ID) == 0 || response.Method != "" {
+		return message, nil
+	}
+	pending, ok := rc.pending.LoadAndDelete(string(response.ID))
+	if !ok {
+		return message, nil
+	}
+
+	req := pending.(localRequest)
+	fingerprint, err := RecordingFingerprint(req.Method, req.Params)
+	if err != nil {
+		return message, nil
+	}
+	recording := &types.ServerRecording{
+		ServerID:    rc.serverID,
+		Fingerprint: fingerprint,
+		Method:      req.Method,
+		Params:      req.Params,
+		Result:      response.Result,
+		Error:       response.Error,
+		RecordedAt:  time.Now(),
+	}
+	if err := rc.store.SaveRecording(ctx, recording); err != nil {
+		log.Printf("Warning: failed to record %s response of server %s: %v", req.Method, rc.serverID, err)
+	}
+	return message, nil
+}
+
+// ReplayTransport answers requests to an upstream server from its recorded
+// responses, without reaching the server
+type ReplayTransport struct {
+	serverID string
+	store    RecordingStore
+}
+
+// NewReplayTransport answers requests to serverID from the recordings in
+// store
+func NewReplayTransport(serverID string, store RecordingStore) *ReplayTransport {
+	return &ReplayTransport{serverID: serverID, store: store}
+}
+
+// Type returns the transport type identifier
+func (rt *ReplayTransport) Type() string {
+	return "replay"
+}
+
+// Connect opens an in-process connection that replays recorded responses
+func (rt *ReplayTransport) Connect(ctx context.Context, config TransportConfig) (Connection, error) {
+	return newLocalConnection(rt.handle), nil
+}
+
+// handle answers a request with its recorded response. Sessions can always
+// be initialized and pinged, even when those requests were not recorded.
+func (rt *ReplayTransport) handle(ctx context.Context, req localRequest) (interface{}, time.Duration, *types.MCPError) {
+	fingerprint, err := RecordingFingerprint(req.Method, req.Params)
+	if err != nil {
+		return nil, 0, &types.MCPError{Code: types.MCPErrorCodeInvalidParams, Message: err.Error()}
+	}
+
+	recording, err := rt.store.FindRecording(ctx, rt.serverID, fingerprint)
+	if err != nil {
+		return nil, 0, &types.MCPError{Code: types.MCPErrorCodeInternalError, Message: "failed to load recording: " + err.Error()}
+	}
+	if recording != nil {
+		if recording.Error != nil {
+			return nil, 0, recording.Error
+		}
+		return recording.Result, 0, nil
+	}
+
+	switch req.Method {
+	case "initialize":
+		return InitializeResult{
+			ProtocolVersion: "2024-11-05",
+			Capabilities:    map[string]interface{}{"tools": map[string]interface{}{}},
+			ServerInfo:      ServerInfo{Name: "omnimesh-replay", Version: "1.0.0"},
+		}, 0, nil
+	case "ping":
+		return map[string]interface{}{}, 0, nil
+	}
+	return nil, 0, &types.MCPError{
+		Code:    types.MCPErrorCodeServerError,
+		Message: "no recorded response for " + req.Method,
+		Data:    map[string]interface{}{"fingerprint": fingerprint},
+	}
+}
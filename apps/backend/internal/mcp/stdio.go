@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -78,6 +79,7 @@ func (st *StdioTransport) Connect(ctx context.Context, config TransportConfig) (
 		mu:        &sync.RWMutex{},
 		messages:  make(chan []byte, 100),
 		errors:    make(chan error, 10),
+		output:    config.Output,
 	}
 
 	// Start reading from stdout in a separate goroutine
@@ -99,6 +101,7 @@ type StdioConnection struct {
 	mu        *sync.RWMutex
 	messages  chan []byte
 	errors    chan error
+	output    func(stream, line string)
 }
 
 // Send sends a message to the MCP server via stdin
@@ -190,6 +193,9 @@ func (sc *StdioConnection) readLoop(ctx context.Context) {
 
 	scanner := bufio.NewScanner(sc.stdout)
 	for scanner.Scan() {
+		if sc.output != nil && isStrayOutput(scanner.Bytes()) {
+			sc.output("stdout", scanner.Text())
+		}
 		select {
 		case <-ctx.Done():
 			return
@@ -208,12 +214,19 @@ func (sc *StdioConnection) readLoop(ctx context.Context) {
 	}
 }
 
-// readStderr reads from stderr for debugging purposes
+// readStderr passes stderr lines to the output sink for debugging
 func (sc *StdioConnection) readStderr(ctx context.Context) {
 	scanner := bufio.NewScanner(sc.stderr)
 	for scanner.Scan() {
-		// Log stderr messages for debugging
-		// In a production system, you might want to send these to a logger
-		_ = scanner.Text()
+		if sc.output != nil {
+			sc.output("stderr", scanner.Text())
+		}
 	}
 }
+
+// isStrayOutput reports whether a stdout line is text the server printed
+// rather than a JSON-RPC frame
+func isStrayOutput(line []byte) bool {
+	trimmed := bytes.TrimSpace(line)
+	return len(trimmed) > 0 && trimmed[0] != '{' && trimmed[0] != '['
+}
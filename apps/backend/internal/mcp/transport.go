@@ -21,13 +21,17 @@ type Connection interface {
 
 // TransportConfig holds configuration for different transport types
 type TransportConfig struct {
-	Type        string            `json:"type"`        // "stdio", "http", "websocket"
+	Type        string            `json:"type"`        // "stdio", "grpc", "mock", "http", "websocket"
 	Command     string            `json:"command"`     // For stdio: command to execute
 	Args        []string          `json:"args"`        // For stdio: command arguments
-	URL         string            `json:"url"`         // For http/ws: connection URL
+	URL         string            `json:"url"`         // For grpc/http/ws: connection URL
 	Headers     map[string]string `json:"headers"`     // For http/ws: custom headers
 	Environment map[string]string `json:"environment"` // For stdio: environment variables
 	WorkingDir  string            `json:"working_dir"` // For stdio: working directory
+	// Output receives stderr lines and non-JSON stdout lines of stdio servers
+	Output func(stream, line string) `json:"-"`
+	// MockTools loads the canned tools a mock server answers with
+	MockTools func(ctx context.Context) ([]types.MockTool, error) `json:"-"`
 }
 
 // TransportManager manages different transport types
@@ -43,6 +47,8 @@ func NewTransportManager() *TransportManager {
 
 	// Register built-in transports
 	tm.RegisterTransport(&StdioTransport{})
+	tm.RegisterTransport(&GRPCTransport{})
+	tm.RegisterTransport(&MockTransport{})
 
 	return tm
 }
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ToolServerResolver finds the namespace server a prefixed tool runs on
+type ToolServerResolver interface {
+	ToolServerID(ctx context.Context, namespaceID, tool string) (string, error)
+}
+
+// EndpointAPIKeyScopeMiddleware refuses requests made with a scoped API key
+// to endpoints outside the key's namespaces, and tool calls outside its
+// servers and tools. Tool calls are read from the tool path of the REST
+// interface and from JSON-RPC requests; calls it cannot see, such as those
+// over WebSocket or in oversized bodies, are still refused by the namespace
+// when executed.
+func EndpointAPIKeyScopeMiddleware(resolver ToolServerResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("principal")
+		principal, _ := value.(*types.Principal)
+		endpointVal, _ := c.Get("endpoint")
+		endpoint, _ := endpointVal.(*types.Endpoint)
+		if principal == nil || principal.Scope == nil || endpoint == nil {
+			c.Next()
+			return
+		}
+		scope := principal.Scope
+
+		if apiErr := scope.CheckNamespace(endpoint.NamespaceID); apiErr != nil {
+			abortWithScopeError(c, apiErr)
+			return
+		}
+
+		for _, tool := range calledTools(c) {
+			serverID, err := resolver.ToolServerID(c.Request.Context(), endpoint.NamespaceID, tool)
+			if err != nil {
+				abortWithScopeError(c, types.NewInternalError("Failed to check API key scope"))
+				return
+			}
+			if apiErr := scope.CheckTool(serverID, tool); apiErr != nil {
+				abortWithScopeError(c, apiErr)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// calledTools returns the tools the request calls, leaving its body intact
+func calledTools(c *gin.Context) []string {
+	if tool := c.Param("tool_name"); tool != "" {
+		return []string{tool}
+	}
+	if c.Request.Method != http.MethodPost || c.Request.Body == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxLoggedMessageSize+1))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	if err != nil || len(body) > maxLoggedMessageSize {
+		return nil
+	}
+
+	var tools []string
+	for _, msg := range parseJSONRPCMessages(body) {
+		if msg["method"] != "tools/call" {
+			continue
+		}
+		params, _ := msg["params"].(map[string]interface{})
+		name, _ := params["name"].(string)
+		tools = append(tools, name)
+	}
+	return tools
+}
+
+func abortWithScopeError(c *gin.Context, apiErr *types.Error) {
+	c.AbortWithStatusJSON(apiErr.Status, &types.ErrorResponse{
+		Error:   apiErr,
+		Success: false,
+	})
+}
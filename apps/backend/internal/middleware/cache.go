@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/cache"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCachedListingSize bounds the responses CacheListings keeps; larger
+// listings are served uncached
+const maxCachedListingSize = 4 << 20
+
+// CacheListings serves GET requests for listings of kind from the list
+// cache and caches successful responses, per organization and request URI.
+// Requests sent with "Cache-Control: no-cache" skip the cache. A nil cache
+// disables caching.
+func CacheListings(listCache *cache.ListCache, kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID := cacheOrganization(c)
+		if listCache == nil || c.Request.Method != http.MethodGet || orgID == "" ||
+			c.GetHeader("Cache-Control") == "no-cache" {
+			c.Next()
+			return
+		}
+
+		key := c.Request.URL.RequestURI()
+		if body, ok := listCache.Get(c.Request.Context(), orgID, kind, key); ok {
+			c.Header("X-Cache", "HIT")
+			c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+			c.Abort()
+			return
+		}
+
+		writer := &listingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Header("X-Cache", "MISS")
+		c.Next()
+
+		if writer.Status() == http.StatusOK && !writer.truncated {
+			listCache.Set(c.Request.Context(), orgID, kind, key, writer.body.Bytes())
+		}
+	}
+}
+
+// InvalidateListings drops the organization's cached listings after a
+// request that changed what they list succeeds
+func InvalidateListings(listCache *cache.ListCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if listCache == nil || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		orgID := cacheOrganization(c)
+		// The request is done, so its context may already be canceled
+		if err := listCache.InvalidateOrganization(context.WithoutCancel(c.Request.Context()), orgID); err != nil {
+			log.Printf("Warning: failed to invalidate cached listings of %s: %v", orgID, err)
+		}
+	}
+}
+
+// cacheOrganization returns the organization a request lists for: the
+// caller's, or the endpoint's on public endpoint routes
+func cacheOrganization(c *gin.Context) string {
+	if orgID := c.GetString("organization_id"); orgID != "" {
+		return orgID
+	}
+	if endpoint, ok := c.Get("endpoint"); ok {
+		if endpoint, ok := endpoint.(*types.Endpoint); ok {
+			return endpoint.OrganizationID
+		}
+	}
+	return ""
+}
+
+// listingWriter keeps a copy of the response body for the cache
+type listingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *listingWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+	if !w.truncated {
+		if w.body.Len()+n > maxCachedListingSize {
+			w.truncated = true
+			w.body.Reset()
+		} else {
+			w.body.Write(data[:n])
+		}
+	}
+	return n, err
+}
+
+func (w *listingWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/clientcompat"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Session header names used by MCP clients. The spec uses Mcp-Session-Id;
+// the gateway's own transports historically use X-Session-ID.
+const (
+	mcpSessionHeader     = "Mcp-Session-Id"
+	gatewaySessionHeader = "X-Session-ID"
+)
+
+// ClientCompat detects the MCP client from its User-Agent and stores it on
+// the request for analytics. When applyShims is set it also works around
+// known client quirks: session header names are aliased both ways, and
+// per-client shims adjust header casing and SSE reconnect behavior.
+func ClientCompat(applyShims bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, shims := clientcompat.Detect(c.Request.UserAgent())
+		if !applyShims {
+			client.Shims = nil
+		}
+		c.Set(types.DetectedClientContextKey, client)
+
+		if !applyShims {
+			c.Next()
+			return
+		}
+
+		// Accept either session header on the way in
+		header := c.Request.Header
+		if header.Get(gatewaySessionHeader) == "" && header.Get(mcpSessionHeader) != "" {
+			header.Set(gatewaySessionHeader, header.Get(mcpSessionHeader))
+		} else if header.Get(mcpSessionHeader) == "" && header.Get(gatewaySessionHeader) != "" {
+			header.Set(mcpSessionHeader, header.Get(gatewaySessionHeader))
+		}
+
+		c.Writer = &compatResponseWriter{ResponseWriter: c.Writer, shims: shims}
+		c.Next()
+	}
+}
+
+// DetectedClientFromContext returns the client detected by ClientCompat
+func DetectedClientFromContext(c *gin.Context) *types.DetectedClient {
+	if val, exists := c.Get(types.DetectedClientContextKey); exists {
+		if client, ok := val.(*types.DetectedClient); ok {
+			return client
+		}
+	}
+	return nil
+}
+
+// compatResponseWriter applies response shims just before headers are sent
+type compatResponseWriter struct {
+	gin.ResponseWriter
+	shims          clientcompat.Shims
+	headersApplied bool
+	retrySent      bool
+}
+
+func (w *compatResponseWriter) WriteHeader(code int) {
+	w.applyHeaders()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compatResponseWriter) WriteHeaderNow() {
+	w.start()
+}
+
+func (w *compatResponseWriter) Write(data []byte) (int, error) {
+	w.start()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compatResponseWriter) WriteString(s string) (int, error) {
+	w.start()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *compatResponseWriter) Flush() {
+	w.start()
+	w.ResponseWriter.Flush()
+}
+
+// start sends the headers and, for event streams, the retry hint
+func (w *compatResponseWriter) start() {
+	w.applyHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+
+	if w.retrySent || w.shims.SSERetry <= 0 {
+		return
+	}
+	w.retrySent = true
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		_, _ = fmt.Fprintf(w.ResponseWriter, "retry: %d\n\n", w.shims.SSERetry.Milliseconds())
+	}
+}
+
+// applyHeaders mirrors the session header under both names
+func (w *compatResponseWriter) applyHeaders() {
+	if w.headersApplied || w.ResponseWriter.Written() {
+		return
+	}
+	w.headersApplied = true
+
+	header := w.Header()
+	sessionID := header.Get(gatewaySessionHeader)
+	if sessionID == "" {
+		sessionID = header.Get(mcpSessionHeader)
+	}
+	if sessionID == "" {
+		return
+	}
+
+	header.Set(gatewaySessionHeader, sessionID)
+	if w.shims.LowercaseSessionHeader {
+		header.Del(mcpSessionHeader)
+		header["mcp-session-id"] = []string{sessionID}
+	} else {
+		header.Set(mcpSessionHeader, sessionID)
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTrustedProxies are trusted when none are configured, so that only a
+// proxy on the same host can set the client address
+var DefaultTrustedProxies = []string{"127.0.0.1", "::1"}
+
+// ConfigureClientIP makes c.ClientIP() return the real client address for
+// every request to r: rate limiting, audit and message logs, and API key
+// network restrictions all read it. Forwarding headers are only believed
+// from trustedProxies, and X-Forwarded-For is read right to left up to the
+// first address that is not a trusted proxy. headers defaults to
+// X-Forwarded-For and X-Real-IP.
+func ConfigureClientIP(r *gin.Engine, trustedProxies, headers []string) error {
+	if len(trustedProxies) == 0 {
+		trustedProxies = DefaultTrustedProxies
+	}
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return err
+	}
+	r.ForwardedByClientIP = true
+	if len(headers) > 0 {
+		r.RemoteIPHeaders = headers
+	}
+	return nil
+}
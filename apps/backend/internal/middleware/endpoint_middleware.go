@@ -8,9 +8,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/ratelimit"
+
 	"github.com/gin-gonic/gin"
-	"github.com/ulule/limiter/v3"
-	"github.com/ulule/limiter/v3/drivers/store/memory"
 )
 
 // EndpointService interface for endpoint operations
@@ -44,6 +44,71 @@ func EndpointLookupMiddleware(endpointService EndpointService) gin.HandlerFunc {
 	}
 }
 
+// ResidencyPolicy decides whether data of traffic pinned to a region may be
+// stored by this gateway
+type ResidencyPolicy interface {
+	CheckStorage(region string) error
+}
+
+// EndpointResidencyMiddleware refuses traffic for endpoints whose namespace is
+// pinned to a region other than the gateway's storage region
+func EndpointResidencyMiddleware(policy ResidencyPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("namespace")
+		namespace, _ := value.(*types.Namespace)
+		if namespace == nil || namespace.Region == "" {
+			c.Next()
+			return
+		}
+
+		if err := policy.CheckStorage(namespace.Region); err != nil {
+			apiErr, ok := err.(*types.Error)
+			if !ok {
+				apiErr = types.NewForbiddenError(err.Error())
+			}
+			c.AbortWithStatusJSON(apiErr.Status, &types.ErrorResponse{
+				Error:   apiErr,
+				Success: false,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GeoAccessPolicy decides whether a client IP address may call an endpoint
+type GeoAccessPolicy interface {
+	CheckEndpoint(ctx context.Context, endpoint *types.Endpoint, ip string) error
+}
+
+// EndpointGeoMiddleware refuses calls from clients whose country or
+// autonomous system the endpoint's geo policy denies
+func EndpointGeoMiddleware(policy GeoAccessPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("endpoint")
+		endpoint, _ := value.(*types.Endpoint)
+		if endpoint == nil {
+			c.Next()
+			return
+		}
+
+		if err := policy.CheckEndpoint(c.Request.Context(), endpoint, c.ClientIP()); err != nil {
+			apiErr, ok := err.(*types.Error)
+			if !ok {
+				apiErr = types.NewForbiddenError(err.Error())
+			}
+			c.AbortWithStatusJSON(apiErr.Status, &types.ErrorResponse{
+				Error:   apiErr,
+				Success: false,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // EndpointAuthService interface for validating API keys and OAuth tokens
 type EndpointAuthService interface {
 	ValidateAPIKey(apiKey string) (*types.APIKey, error)
@@ -55,8 +120,10 @@ type OAuthService interface {
 	ValidateToken(ctx context.Context, bearerToken string) (*types.OAuthToken, error)
 }
 
-// EndpointAuthMiddleware validates access to endpoint based on its auth settings
-func EndpointAuthMiddleware(endpointService EndpointService, authService EndpointAuthService, oauthService OAuthService) gin.HandlerFunc {
+// EndpointAuthMiddleware validates access to endpoint based on its auth settings.
+// baseURL is used to point OAuth clients at the endpoint's protected resource
+// metadata when authentication fails.
+func EndpointAuthMiddleware(endpointService EndpointService, authService EndpointAuthService, oauthService OAuthService, baseURL string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		endpointVal, exists := c.Get("endpoint")
 		if !exists {
@@ -79,6 +146,13 @@ func EndpointAuthMiddleware(endpointService EndpointService, authService Endpoin
 
 		// If public access is enabled, allow without authentication
 		if endpoint.EnablePublicAccess {
+			if len(endpoint.Labels) > 0 {
+				setPrincipal(c, &types.Principal{
+					Type:           types.PrincipalTypeAnonymous,
+					OrganizationID: endpoint.OrganizationID,
+					Labels:         endpoint.Labels,
+				})
+			}
 			c.Next()
 			return
 		}
@@ -90,12 +164,34 @@ func EndpointAuthMiddleware(endpointService EndpointService, authService Endpoin
 		if endpoint.EnableAPIKeyAuth {
 			if apiKey := extractAPIKey(c, endpoint); apiKey != "" {
 				if validatedKey, err := authService.ValidateAPIKey(apiKey); err == nil {
+					// Keys restricted to other networks or origins are refused
+					// outright rather than falling back to other auth methods
+					if apiErr := validatedKey.CheckClient(c.ClientIP(), c.GetHeader("Origin"), c.GetHeader("Referer")); apiErr != nil {
+						c.AbortWithStatusJSON(apiErr.Status, gin.H{
+							"error":   "Forbidden",
+							"details": apiErr.Message,
+						})
+						return
+					}
 					if u, err := authService.GetUserByID(validatedKey.UserID); err == nil && u.IsActive {
 						authenticated = true
 						c.Set("user_id", u.ID)
 						c.Set("organization_id", u.OrganizationID)
 						c.Set("role", u.Role)
 						c.Set("api_key", validatedKey)
+						principalType := types.PrincipalTypeAPIKey
+						if u.IsServiceAccount() {
+							principalType = types.PrincipalTypeServiceAccount
+						}
+						setPrincipal(c, &types.Principal{
+							Type:           principalType,
+							UserID:         u.ID,
+							APIKeyID:       validatedKey.ID,
+							OrganizationID: u.OrganizationID,
+							Email:          u.Email,
+							Labels:         endpoint.Labels.Merge(validatedKey.Labels),
+							Scope:          validatedKey.Scope(),
+						})
 					}
 				}
 			}
@@ -115,18 +211,36 @@ func EndpointAuthMiddleware(endpointService EndpointService, authService Endpoin
 					c.Set("organization_id", oauthToken.OrganizationID)
 					c.Set("token_scope", oauthToken.Scope)
 
+					principal := &types.Principal{
+						Type:     types.PrincipalTypeOAuthClient,
+						ClientID: oauthToken.ClientID,
+						Labels:   endpoint.Labels,
+					}
+					if oauthToken.OrganizationID != nil {
+						principal.OrganizationID = *oauthToken.OrganizationID
+					}
+
 					// Set user context if token has user info
 					if oauthToken.UserID != nil {
 						c.Set("user_id", *oauthToken.UserID)
+						principal.UserID = *oauthToken.UserID
 						if oauthToken.UserRole != nil {
 							c.Set("role", *oauthToken.UserRole)
 						}
+						if oauthToken.UserAccountType != nil && *oauthToken.UserAccountType == types.AccountTypeService {
+							principal.Type = types.PrincipalTypeServiceAccount
+						}
 					}
+					setPrincipal(c, principal)
 				}
 			}
 		}
 
 		if !authenticated {
+			if endpoint.EnableOAuth {
+				c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer resource_metadata="%s"`,
+					ProtectedResourceMetadataURL(baseURL, c.Request.URL.Path)))
+			}
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Unauthorized",
 				"details": "No valid authentication provided",
@@ -139,6 +253,59 @@ func EndpointAuthMiddleware(endpointService EndpointService, authService Endpoin
 	}
 }
 
+// NamespaceAccess decides whether a caller may use a namespace
+type NamespaceAccess interface {
+	CheckNamespace(ctx context.Context, subject *types.NamespaceSubject, namespaceID, level string) error
+}
+
+// EndpointNamespaceAccessMiddleware refuses endpoint traffic from users who
+// were not granted execute access to the endpoint's namespace. Anonymous
+// callers and OAuth clients acting without a user are governed by the
+// endpoint's auth settings alone.
+func EndpointNamespaceAccessMiddleware(access NamespaceAccess) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("principal")
+		principal, _ := value.(*types.Principal)
+		endpointVal, _ := c.Get("endpoint")
+		endpoint, _ := endpointVal.(*types.Endpoint)
+		if principal == nil || principal.EffectiveUserID() == "" || endpoint == nil {
+			c.Next()
+			return
+		}
+
+		subject := &types.NamespaceSubject{
+			UserID:         principal.EffectiveUserID(),
+			Role:           c.GetString("role"),
+			OrganizationID: principal.OrganizationID,
+		}
+		if err := access.CheckNamespace(c.Request.Context(), subject, endpoint.NamespaceID, types.NamespaceAccessExecute); err != nil {
+			apiErr, ok := err.(*types.Error)
+			if !ok {
+				apiErr = types.NewInternalError("Failed to check namespace access")
+			}
+			c.AbortWithStatusJSON(apiErr.Status, &types.ErrorResponse{
+				Error:   apiErr,
+				Success: false,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ProtectedResourceMetadataURL returns the RFC 9728 metadata URL for the
+// resource at resourcePath, inserting the well-known segment before the path
+func ProtectedResourceMetadataURL(baseURL, resourcePath string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/.well-known/oauth-protected-resource" + resourcePath
+}
+
+// setPrincipal records the authenticated principal for usage attribution
+func setPrincipal(c *gin.Context, principal *types.Principal) {
+	c.Set("principal", principal)
+	c.Request = c.Request.WithContext(types.WithPrincipal(c.Request.Context(), principal))
+}
+
 // extractAPIKey extracts API key from various sources based on endpoint configuration
 func extractAPIKey(c *gin.Context, endpoint *types.Endpoint) string {
 	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
@@ -160,10 +327,11 @@ func extractAPIKey(c *gin.Context, endpoint *types.Endpoint) string {
 	return ""
 }
 
-// EndpointRateLimitMiddleware applies rate limiting based on endpoint configuration
-func EndpointRateLimitMiddleware() gin.HandlerFunc {
-	limiters := make(map[string]*limiter.Limiter)
-
+// EndpointRateLimitMiddleware limits each client IP to the endpoint's
+// configured requests per window, counted with limiter so that instances
+// sharing Redis share the limit. Endpoints without a limit are not limited,
+// and changes to an endpoint's limit apply to the next request.
+func EndpointRateLimitMiddleware(limiter ratelimit.Limiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		endpointVal, exists := c.Get("endpoint")
 		if !exists {
@@ -173,41 +341,32 @@ func EndpointRateLimitMiddleware() gin.HandlerFunc {
 		}
 
 		endpoint := endpointVal.(*types.Endpoint)
-
-		limiterKey := endpoint.ID
-		lim, exists := limiters[limiterKey]
-		if !exists {
-			// Create rate limiter with endpoint-specific settings
-			rate := limiter.Rate{
-				Period: time.Duration(endpoint.RateLimitWindow) * time.Second,
-				Limit:  int64(endpoint.RateLimitRequests),
-			}
-			store := memory.NewStore()
-			lim = limiter.New(store, rate)
-			limiters[limiterKey] = lim
+		if endpoint.RateLimitRequests <= 0 || endpoint.RateLimitWindow <= 0 {
+			c.Next()
+			return
 		}
 
-		// Apply rate limiting based on client IP
-		key := fmt.Sprintf("endpoint:%s:%s", endpoint.Name, c.ClientIP())
-		context, err := lim.Get(c.Request.Context(), key)
+		policy := ratelimit.Policy{
+			Algorithm: ratelimit.AlgorithmSlidingWindow,
+			Window:    time.Duration(endpoint.RateLimitWindow) * time.Second,
+			Limit:     int64(endpoint.RateLimitRequests),
+		}
+		key := fmt.Sprintf("endpoint:%s:%s", endpoint.ID, c.ClientIP())
+		result, err := limiter.Allow(c.Request.Context(), key, policy)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Rate limiting error"})
-			c.Abort()
+			c.AbortWithStatusJSON(http.StatusInternalServerError, &types.ErrorResponse{
+				Error:   types.NewInternalError("Rate limiting error"),
+				Success: false,
+			})
 			return
 		}
 
-		// Set rate limit headers
-		c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", context.Limit))
-		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", context.Remaining))
-		c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", context.Reset))
-
-		// Check if rate limit exceeded
-		if context.Reached {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":       "Rate limit exceeded",
-				"retry_after": context.Reset,
+		bucket := newLimiterBucket(types.RateLimitScopeEndpoint, endpoint.Name, policy.Window, result)
+		if !ApplyRateLimitBucket(c, bucket) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, &types.ErrorResponse{
+				Error:   types.NewRateLimitExceededError("Too many requests to this endpoint. Please try again later."),
+				Success: false,
 			})
-			c.Abort()
 			return
 		}
 
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InboundHeaders records the headers of every request on its context, so
+// that header propagation rules can copy them to upstream servers
+func InboundHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(types.WithInboundHeaders(c.Request.Context(), c.Request.Header))
+		c.Next()
+	}
+}
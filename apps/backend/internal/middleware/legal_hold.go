@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LegalHoldChecker reports whether an organization is under legal hold
+type LegalHoldChecker interface {
+	CheckNotHeld(ctx context.Context, orgID string) error
+}
+
+// LegalHoldGuard refuses destructive operations for organizations under
+// legal hold. It runs after authentication, which sets organization_id.
+func LegalHoldGuard(checker LegalHoldChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := checker.CheckNotHeld(c.Request.Context(), c.GetString("organization_id")); err != nil {
+			apiErr, ok := err.(*types.Error)
+			if !ok {
+				apiErr = types.NewInternalError("Failed to check legal hold status")
+			}
+			c.AbortWithStatusJSON(apiErr.Status, &types.ErrorResponse{
+				Error:   apiErr,
+				Success: false,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConcurrencyLimiter admits requests under a concurrency limit, see
+// scheduler.AdaptiveLimiter
+type ConcurrencyLimiter interface {
+	Acquire() (func(latency time.Duration, overloaded bool), error)
+}
+
+// LoadShedding rejects requests with 503 and Retry-After once the limiter
+// is full, so a latency spike in Postgres or upstream servers is not made
+// worse by piling more work onto it. exemptPaths are gin route patterns (as
+// returned by c.FullPath), such as health checks, that are never shed.
+// Preflight requests and long-lived streams are not limited either: their
+// duration says nothing about load and they are bounded by transport
+// backpressure instead.
+func LoadShedding(limiter ConcurrencyLimiter, exemptPaths ...string) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if exempt[c.FullPath()] || c.Request.Method == http.MethodOptions || isStreamingRequest(c.Request) {
+			c.Next()
+			return
+		}
+
+		release, err := limiter.Acquire()
+		if err != nil {
+			apiErr, ok := err.(*types.Error)
+			if !ok {
+				apiErr = types.NewErrorWithDetails(types.ErrCodeServiceUnavailable,
+					"Gateway is overloaded, please retry", err.Error(), http.StatusServiceUnavailable)
+			}
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(apiErr.Status, &types.ErrorResponse{
+				Error:   apiErr,
+				Success: false,
+			})
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		overloaded := status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout ||
+			errors.Is(c.Request.Context().Err(), context.DeadlineExceeded)
+		release(time.Since(start), overloaded)
+	}
+}
+
+// isStreamingRequest reports whether the request opens a WebSocket or
+// server-sent event stream
+func isStreamingRequest(r *http.Request) bool {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream") && r.Method == http.MethodGet
+}
@@ -0,0 +1,231 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MCPMessageRecorder persists message-level MCP traffic
+type MCPMessageRecorder interface {
+	RecordMessage(ctx context.Context, entry *types.MCPMessageLog) error
+}
+
+// maxLoggedMessageSize bounds the request body parsed for message logging
+const maxLoggedMessageSize = 1 << 20
+
+// MCPMessageLogger records each JSON-RPC message handled by the route in a
+// structured message log, separate from HTTP request logs. Recording happens
+// off the request path so a slow store never delays MCP responses.
+func MCPMessageLogger(recorder MCPMessageRecorder, transport types.TransportType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if recorder == nil || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxLoggedMessageSize+1))
+		if err != nil {
+			c.Next()
+			return
+		}
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+		var messages []map[string]interface{}
+		if len(body) <= maxLoggedMessageSize {
+			messages = parseJSONRPCMessages(body)
+		}
+		if len(messages) == 0 {
+			c.Next()
+			return
+		}
+
+		writer := &messageLogWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		startTime := time.Now()
+
+		c.Next()
+
+		duration := time.Since(startTime)
+		base := messageLogBase(c, transport)
+		responses := indexJSONRPCResponses(writer.body.Bytes())
+
+		for _, msg := range messages {
+			entry := *base
+			entry.Method, _ = msg["method"].(string)
+			entry.Params, _ = msg["params"].(map[string]interface{})
+			entry.DurationMS = duration.Milliseconds()
+			entry.StatusCode = c.Writer.Status()
+			entry.RequestSize = int64(len(body))
+			if id, ok := msg["id"]; ok && id != nil {
+				entry.RPCID = fmt.Sprint(id)
+			}
+			if entry.Method == "tools/call" && entry.Params != nil {
+				entry.ToolName, _ = entry.Params["name"].(string)
+			}
+
+			if len(messages) == 1 {
+				entry.ResultSize = int64(writer.size)
+			}
+			if resp, ok := responses[entry.RPCID]; ok && entry.RPCID != "" {
+				applyJSONRPCOutcome(&entry, resp)
+				if len(messages) > 1 {
+					if encoded, err := json.Marshal(resp); err == nil {
+						entry.ResultSize = int64(len(encoded))
+					}
+				}
+			}
+			if entry.StatusCode >= http.StatusBadRequest {
+				entry.IsError = true
+			}
+
+			go func(e *types.MCPMessageLog) {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				_ = recorder.RecordMessage(ctx, e)
+			}(&entry)
+		}
+	}
+}
+
+// messageLogBase collects the request attribution shared by every message in a request
+func messageLogBase(c *gin.Context, transport types.TransportType) *types.MCPMessageLog {
+	entry := &types.MCPMessageLog{
+		Transport: string(transport),
+		SessionID: c.GetHeader("Mcp-Session-Id"),
+	}
+	if entry.SessionID == "" {
+		entry.SessionID = c.Query("session_id")
+	}
+
+	if val, exists := c.Get("endpoint"); exists {
+		if endpoint, ok := val.(*types.Endpoint); ok && endpoint != nil {
+			entry.EndpointID = endpoint.ID
+			entry.NamespaceID = endpoint.NamespaceID
+			entry.OrganizationID = endpoint.OrganizationID
+		}
+	}
+	if entry.OrganizationID == "" {
+		if orgID, ok := c.Get("organization_id"); ok {
+			entry.OrganizationID, _ = orgID.(string)
+		}
+	}
+
+	var principal *types.Principal
+	if val, exists := c.Get("principal"); exists {
+		principal, _ = val.(*types.Principal)
+	}
+	if principal != nil {
+		entry.PrincipalType = principal.Type
+		entry.PrincipalID = principal.ID()
+		entry.OnBehalfOf = principal.OnBehalfOf
+	}
+
+	return entry
+}
+
+// maxLoggedBatchMessages bounds the messages logged for one batch, since each
+// is recorded separately
+const maxLoggedBatchMessages = 1000
+
+// parseJSONRPCMessages decodes a single JSON-RPC message or a batch. Entries
+// that are not requests or notifications are skipped.
+func parseJSONRPCMessages(body []byte) []map[string]interface{} {
+	var messages []map[string]interface{}
+	for _, msg := range decodeJSONRPCObjects(body) {
+		if _, ok := msg["method"].(string); !ok {
+			continue
+		}
+		messages = append(messages, msg)
+		if len(messages) == maxLoggedBatchMessages {
+			break
+		}
+	}
+	return messages
+}
+
+// decodeJSONRPCObjects decodes a single JSON object or a batch of them,
+// skipping batch entries that are not objects
+func decodeJSONRPCObjects(body []byte) []map[string]interface{} {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil
+	}
+
+	if trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			return nil
+		}
+		objects := make([]map[string]interface{}, 0, len(batch))
+		for _, raw := range batch {
+			var obj map[string]interface{}
+			if err := json.Unmarshal(raw, &obj); err == nil && obj != nil {
+				objects = append(objects, obj)
+			}
+		}
+		return objects
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(trimmed, &obj); err != nil || obj == nil {
+		return nil
+	}
+	return []map[string]interface{}{obj}
+}
+
+// indexJSONRPCResponses maps response IDs to response objects
+func indexJSONRPCResponses(body []byte) map[string]map[string]interface{} {
+	index := make(map[string]map[string]interface{})
+	for _, resp := range decodeJSONRPCObjects(body) {
+		if id, ok := resp["id"]; ok && id != nil {
+			index[fmt.Sprint(id)] = resp
+		}
+	}
+	return index
+}
+
+// applyJSONRPCOutcome copies error details from a JSON-RPC response
+func applyJSONRPCOutcome(entry *types.MCPMessageLog, resp map[string]interface{}) {
+	if rpcErr, ok := resp["error"].(map[string]interface{}); ok {
+		entry.IsError = true
+		if code, ok := rpcErr["code"].(float64); ok {
+			entry.ErrorCode = int(code)
+		}
+		entry.ErrorMessage, _ = rpcErr["message"].(string)
+		return
+	}
+	if result, ok := resp["result"].(map[string]interface{}); ok {
+		if isError, _ := result["isError"].(bool); isError {
+			entry.IsError = true
+		}
+	}
+}
+
+// messageLogWriter captures the response body so outcomes can be recorded
+type messageLogWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+	size int
+}
+
+func (w *messageLogWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	if w.body.Len() < maxLoggedMessageSize {
+		w.body.Write(data[:n])
+	}
+	return n, err
+}
+
+func (w *messageLogWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
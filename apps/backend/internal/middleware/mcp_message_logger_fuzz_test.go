@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSONRPCMessagesBatch(t *testing.T) {
+	messages := parseJSONRPCMessages([]byte(`[null, 1, {"jsonrpc":"2.0","id":1,"result":{}}, {"jsonrpc":"2.0","id":2,"method":"tools/list"}]`))
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, "tools/list", messages[0]["method"])
+	}
+
+	batch := "[" + strings.Repeat(`{"method":"ping"},`, maxLoggedBatchMessages) + `{"method":"ping"}]`
+	assert.Len(t, parseJSONRPCMessages([]byte(batch)), maxLoggedBatchMessages)
+	assert.Nil(t, parseJSONRPCMessages([]byte(`null`)))
+}
+
+func FuzzParseJSONRPCMessages(f *testing.F) {
+	f.Add([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo"}}`))
+	f.Add([]byte(`[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","method":"b"}]`))
+	f.Add([]byte(`[null,{"id":{"nested":[1]},"error":{"code":1e400}}]`))
+	f.Add([]byte(`  `))
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		messages := parseJSONRPCMessages(body)
+		assert.LessOrEqual(t, len(messages), maxLoggedBatchMessages)
+		for _, msg := range messages {
+			_, ok := msg["method"].(string)
+			assert.True(t, ok)
+		}
+
+		for _, resp := range indexJSONRPCResponses(body) {
+			assert.NotNil(t, resp)
+		}
+	})
+}
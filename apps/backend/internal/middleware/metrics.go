@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, so that scanners
+// probing random paths do not create a series per path
+const unmatchedRoute = "unmatched"
+
+// RequestMetrics times every request and emits its latency and count by
+// route template, method and status, and counts rate-limited requests.
+// Routes are labeled by their template, such as /api/namespaces/:id, to keep
+// the number of series bounded.
+func RequestMetrics(emit func(metric *types.Metric)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if emit == nil {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+		duration := time.Since(start)
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := c.Writer.Status()
+		tags := map[string]string{
+			"method": c.Request.Method,
+			"route":  route,
+			"status": strconv.Itoa(status),
+		}
+
+		emit(&types.Metric{
+			Timestamp: start,
+			Name:      "http_request_duration",
+			Type:      types.MetricTypeHistogram,
+			Value:     float64(duration.Microseconds()) / 1000,
+			Tags:      tags,
+		})
+		emit(&types.Metric{
+			Timestamp: start,
+			Name:      "http_requests_total",
+			Type:      types.MetricTypeCounter,
+			Value:     1,
+			Tags:      tags,
+		})
+		if status == http.StatusTooManyRequests {
+			emit(&types.Metric{
+				Timestamp: start,
+				Name:      "rate_limit_rejections_total",
+				Type:      types.MetricTypeCounter,
+				Value:     1,
+				Tags:      map[string]string{"route": route},
+			})
+		}
+	}
+}
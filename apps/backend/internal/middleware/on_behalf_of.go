@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DelegationResolver resolves the user a service account acts for
+type DelegationResolver interface {
+	Resolve(ctx context.Context, orgID, serviceAccountID, subject string) (*types.User, error)
+}
+
+// OnBehalfOfMiddleware lets service accounts act on behalf of users of their
+// organization with the X-On-Behalf-Of header, as their delegation policy
+// allows. Later authorization uses the effective user and role, while the
+// principal keeps the service account so both are recorded. It runs after
+// authentication; the header is refused from any other caller.
+func OnBehalfOfMiddleware(delegation DelegationResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subject := strings.TrimSpace(c.GetHeader(types.OnBehalfOfHeader))
+		if subject == "" {
+			c.Next()
+			return
+		}
+
+		value, _ := c.Get("principal")
+		principal, _ := value.(*types.Principal)
+		var err error
+		var user *types.User
+		if principal == nil || principal.Type != types.PrincipalTypeServiceAccount {
+			err = types.NewForbiddenError("only service accounts may act on behalf of users")
+		} else {
+			user, err = delegation.Resolve(c.Request.Context(), principal.OrganizationID, principal.UserID, subject)
+		}
+		if err != nil {
+			apiErr, ok := err.(*types.Error)
+			if !ok {
+				apiErr = types.NewInternalError("Failed to check delegation policy")
+			}
+			c.AbortWithStatusJSON(apiErr.Status, &types.ErrorResponse{
+				Error:   apiErr,
+				Success: false,
+			})
+			return
+		}
+
+		delegated := *principal
+		delegated.OnBehalfOf = user.ID
+		c.Set("user_id", user.ID)
+		c.Set("role", user.Role)
+		setPrincipal(c, &delegated)
+		c.Next()
+	}
+}
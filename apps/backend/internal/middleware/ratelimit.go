@@ -1,27 +1,31 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/ratelimit"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"github.com/ulule/limiter/v3"
-	ginmiddleware "github.com/ulule/limiter/v3/drivers/middleware/gin"
 	memorystore "github.com/ulule/limiter/v3/drivers/store/memory"
 	redisstore "github.com/ulule/limiter/v3/drivers/store/redis"
 )
 
-// IPRateLimitConfig holds the configuration for IP rate limiting
+// IPRateLimitConfig holds the configuration for IP rate limiting. Clients
+// are keyed by c.ClientIP(), so the proxies and headers it trusts are
+// configured on the engine, see ConfigureClientIP.
 type IPRateLimitConfig struct {
 	RedisAddr      string   `yaml:"redis_addr"`
 	RedisPassword  string   `yaml:"redis_password"`
-	TrustedProxies []string `yaml:"trusted_proxies"`
 	SkipPaths      []string `yaml:"skip_paths"`
-	CustomHeaders  []string `yaml:"custom_headers"`
 	RequestsPerMin int      `yaml:"requests_per_minute"`
 	BurstSize      int      `yaml:"burst_size"`
 	RedisDB        int      `yaml:"redis_db"`
@@ -38,9 +42,7 @@ func DefaultIPRateLimitConfig() *IPRateLimitConfig {
 		RedisEnabled:   false,
 		RedisAddr:      "localhost:6379",
 		RedisDB:        0,
-		TrustedProxies: []string{"127.0.0.1", "::1"},
 		SkipPaths:      []string{"/health", "/metrics"},
-		CustomHeaders:  []string{"X-Real-IP", "X-Forwarded-For"},
 	}
 }
 
@@ -57,7 +59,6 @@ func IPRateLimitFromAppConfig(cfg *config.RateLimitConfig, redisCfg *config.Redi
 		Enabled:        cfg.IPEnabled,
 		RequestsPerMin: cfg.IPRequestsPerMinute,
 		SkipPaths:      cfg.IPSkipPaths,
-		CustomHeaders:  cfg.IPCustomHeaders,
 	}
 
 	// Determine storage backend
@@ -116,11 +117,6 @@ func IPRateLimit(config *IPRateLimitConfig) gin.HandlerFunc {
 
 	limiterInstance := limiter.New(store, rate)
 
-	// Create middleware with custom key getter
-	mw := ginmiddleware.NewMiddleware(limiterInstance, ginmiddleware.WithKeyGetter(func(c *gin.Context) string {
-		return getClientIP(c, config)
-	}))
-
 	return func(c *gin.Context) {
 		// Skip rate limiting for certain paths
 		if shouldSkipPath(c.Request.URL.Path, config.SkipPaths) {
@@ -129,34 +125,248 @@ func IPRateLimit(config *IPRateLimitConfig) gin.HandlerFunc {
 		}
 
 		// Apply rate limiting
-		mw(c)
+		key := getClientIP(c)
+		lctx, err := limiterInstance.Get(c.Request.Context(), key)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, &types.ErrorResponse{
+				Error:   types.NewInternalError("Rate limiting error"),
+				Success: false,
+			})
+			return
+		}
+
+		bucket := newRateLimitBucket(types.RateLimitScopeIP, key, rate.Period, lctx)
+		if !ApplyRateLimitBucket(c, bucket) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, &types.ErrorResponse{
+				Error:   types.NewRateLimitExceededError("Too many requests from this IP address. Please try again later."),
+				Success: false,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SandboxRateLimit limits endpoint test console calls per user, falling back
+// to the client IP. It is deliberately far below production endpoint limits.
+func SandboxRateLimit(requestsPerMin int) gin.HandlerFunc {
+	rate := limiter.Rate{
+		Period: time.Minute,
+		Limit:  int64(requestsPerMin),
+	}
+	limiterInstance := limiter.New(memorystore.NewStore(), rate)
+
+	return func(c *gin.Context) {
+		key := c.GetString("user_id")
+		if key == "" {
+			key = c.ClientIP()
+		}
+
+		lctx, err := limiterInstance.Get(c.Request.Context(), key)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, &types.ErrorResponse{
+				Error:   types.NewInternalError("Rate limiting error"),
+				Success: false,
+			})
+			return
+		}
+
+		bucket := newRateLimitBucket(types.RateLimitScopeSandbox, key, rate.Period, lctx)
+		if !ApplyRateLimitBucket(c, bucket) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, &types.ErrorResponse{
+				Error:   types.NewRateLimitExceededError("Too many sandbox executions. Please try again later."),
+				Success: false,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// StatusPageRateLimit limits unauthenticated status page requests per
+// client IP
+func StatusPageRateLimit(requestsPerMin int) gin.HandlerFunc {
+	rate := limiter.Rate{
+		Period: time.Minute,
+		Limit:  int64(requestsPerMin),
+	}
+	limiterInstance := limiter.New(memorystore.NewStore(), rate)
+
+	return func(c *gin.Context) {
+		key := c.ClientIP()
+		lctx, err := limiterInstance.Get(c.Request.Context(), key)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, &types.ErrorResponse{
+				Error:   types.NewInternalError("Rate limiting error"),
+				Success: false,
+			})
+			return
+		}
+
+		bucket := newRateLimitBucket(types.RateLimitScopeStatusPage, key, rate.Period, lctx)
+		if !ApplyRateLimitBucket(c, bucket) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, &types.ErrorResponse{
+				Error:   types.NewRateLimitExceededError("Too many status page requests. Please try again later."),
+				Success: false,
+			})
+			return
+		}
+
+		c.Next()
 	}
 }
 
-// getClientIP extracts the real client IP considering proxies and custom headers
-func getClientIP(c *gin.Context, config *IPRateLimitConfig) string {
-	// First, try custom headers in order
-	for _, header := range config.CustomHeaders {
-		if ip := c.GetHeader(header); ip != "" {
-			// Take first IP if comma-separated list
-			if len(ip) > 0 {
-				return extractFirstIP(ip)
+// newRateLimitBucket converts limiter state into a reportable bucket
+func newRateLimitBucket(scope, key string, period time.Duration, lctx limiter.Context) types.RateLimitBucket {
+	return types.RateLimitBucket{
+		Scope:     scope,
+		Key:       key,
+		Window:    period.String(),
+		Limit:     lctx.Limit,
+		Remaining: lctx.Remaining,
+		ResetAt:   time.Unix(lctx.Reset, 0).UTC(),
+		Exceeded:  lctx.Reached,
+	}
+}
+
+// newLimiterBucket converts a ratelimit result into a reportable bucket.
+// A denied request's bucket resets when it may be retried.
+func newLimiterBucket(scope, key string, window time.Duration, result *ratelimit.Result) types.RateLimitBucket {
+	bucket := types.RateLimitBucket{
+		Scope:     scope,
+		Key:       key,
+		Window:    window.String(),
+		Limit:     result.Limit,
+		Remaining: result.Remaining,
+		ResetAt:   result.ResetAt,
+		Exceeded:  !result.Allowed,
+	}
+	if !result.Allowed {
+		bucket.ResetAt = time.Now().Add(result.RetryAfter)
+	}
+	return bucket
+}
+
+// RateLimitChecker counts a request against its organization's rate limit
+// rules, returning the bucket of each rule it applies to
+type RateLimitChecker interface {
+	Check(ctx context.Context, subject *types.RateLimitSubject) ([]types.RateLimitBucket, error)
+}
+
+// RuleRateLimit enforces the rate limit rules of the request's
+// organization, by organization, user, API key and endpoint. It must run
+// after authentication and, for endpoint rules, endpoint lookup. A nil
+// checker disables it.
+func RuleRateLimit(checker RateLimitChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checker == nil {
+			c.Next()
+			return
+		}
+
+		subject := &types.RateLimitSubject{
+			OrganizationID: c.GetString("organization_id"),
+			UserID:         c.GetString("user_id"),
+		}
+		if principal := types.PrincipalFromContext(c.Request.Context()); principal != nil {
+			if subject.OrganizationID == "" {
+				subject.OrganizationID = principal.OrganizationID
+			}
+			if subject.UserID == "" {
+				subject.UserID = principal.UserID
+			}
+			subject.APIKeyID = principal.APIKeyID
+		}
+		if endpointVal, exists := c.Get("endpoint"); exists {
+			if endpoint, ok := endpointVal.(*types.Endpoint); ok {
+				subject.EndpointID = endpoint.ID
+			}
+		}
+
+		buckets, err := checker.Check(c.Request.Context(), subject)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, &types.ErrorResponse{
+				Error:   types.NewInternalError("Rate limiting error"),
+				Success: false,
+			})
+			return
+		}
+
+		for _, bucket := range buckets {
+			if !ApplyRateLimitBucket(c, bucket) {
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, &types.ErrorResponse{
+					Error:   types.NewRateLimitExceededError(fmt.Sprintf("Rate limit %q exceeded. Please try again later.", bucket.Key)),
+					Success: false,
+				})
+				return
 			}
 		}
+
+		c.Next()
 	}
+}
 
-	// Fallback to Gin's ClientIP which handles X-Forwarded-For, X-Real-IP, etc.
-	return c.ClientIP()
+// ApplyRateLimitBucket records the bucket on the request for GET /api/auth/limits
+// and sets rate-limit headers. When several buckets apply, the headers
+// describe the one with the fewest requests remaining. It reports whether the
+// request is within the limit.
+func ApplyRateLimitBucket(c *gin.Context, bucket types.RateLimitBucket) bool {
+	buckets := RateLimitBucketsFromContext(c)
+	c.Set(RateLimitBucketsKey, append(buckets, bucket))
+
+	tightest := true
+	for _, existing := range buckets {
+		if existing.Remaining < bucket.Remaining {
+			tightest = false
+		}
+	}
+	if tightest || bucket.Exceeded {
+		setRateLimitHeaders(c, bucket)
+	}
+
+	return !bucket.Exceeded
 }
 
-// extractFirstIP extracts the first IP from a comma-separated list
-func extractFirstIP(ipList string) string {
-	for i, char := range ipList {
-		if char == ',' {
-			return ipList[:i]
+// RateLimitBucketsKey is the gin context key holding the buckets a request passed through
+const RateLimitBucketsKey = "rate_limit_buckets"
+
+// RateLimitBucketsFromContext returns the rate-limit buckets applied to the request
+func RateLimitBucketsFromContext(c *gin.Context) []types.RateLimitBucket {
+	if val, exists := c.Get(RateLimitBucketsKey); exists {
+		if buckets, ok := val.([]types.RateLimitBucket); ok {
+			return buckets
 		}
 	}
-	return ipList
+	return nil
+}
+
+// setRateLimitHeaders writes both the X-RateLimit-* headers (reset as a Unix
+// timestamp) and the IETF RateLimit-* headers (reset in seconds), plus
+// Retry-After once the limit is reached
+func setRateLimitHeaders(c *gin.Context, bucket types.RateLimitBucket) {
+	resetIn := int64(math.Ceil(time.Until(bucket.ResetAt).Seconds()))
+	if resetIn < 0 {
+		resetIn = 0
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.FormatInt(bucket.Limit, 10))
+	c.Header("X-RateLimit-Remaining", strconv.FormatInt(bucket.Remaining, 10))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(bucket.ResetAt.Unix(), 10))
+	c.Header("RateLimit-Limit", strconv.FormatInt(bucket.Limit, 10))
+	c.Header("RateLimit-Remaining", strconv.FormatInt(bucket.Remaining, 10))
+	c.Header("RateLimit-Reset", strconv.FormatInt(resetIn, 10))
+	if bucket.Exceeded {
+		c.Header("Retry-After", strconv.FormatInt(resetIn, 10))
+	}
+}
+
+// getClientIP returns the client IP as resolved by the engine's trusted
+// proxies, see ConfigureClientIP. Forwarding headers are not read here, so
+// clients cannot pick their own rate limit bucket by sending them.
+func getClientIP(c *gin.Context) string {
+	return c.ClientIP()
 }
 
 // shouldSkipPath checks if the path should skip rate limiting
@@ -178,9 +388,7 @@ func IPRateLimitWithRedis(requestsPerMin int, redisAddr, redisPassword string, r
 		RedisAddr:      redisAddr,
 		RedisPassword:  redisPassword,
 		RedisDB:        redisDB,
-		TrustedProxies: []string{"127.0.0.1", "::1"},
 		SkipPaths:      []string{"/health", "/metrics"},
-		CustomHeaders:  []string{"X-Real-IP", "X-Forwarded-For"},
 	}
 	return IPRateLimit(config)
 }
@@ -191,9 +399,7 @@ func IPRateLimitWithMemory(requestsPerMin int) gin.HandlerFunc {
 		Enabled:        true,
 		RequestsPerMin: requestsPerMin,
 		RedisEnabled:   false,
-		TrustedProxies: []string{"127.0.0.1", "::1"},
 		SkipPaths:      []string{"/health", "/metrics"},
-		CustomHeaders:  []string{"X-Real-IP", "X-Forwarded-For"},
 	}
 	return IPRateLimit(config)
 }
@@ -107,10 +107,6 @@ func TestIPRateLimitSkipPaths(t *testing.T) {
 func TestGetClientIP(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	config := &IPRateLimitConfig{
-		CustomHeaders: []string{"X-Real-IP", "X-Forwarded-For"},
-	}
-
 	tests := []struct {
 		name        string
 		headers     map[string]string
@@ -123,14 +119,21 @@ func TestGetClientIP(t *testing.T) {
 			headers:     map[string]string{"X-Real-IP": "192.168.1.100"},
 			remoteAddr:  "127.0.0.1:12345",
 			expectedIP:  "192.168.1.100",
-			description: "Should use X-Real-IP header when present",
+			description: "Should use X-Real-IP header from a trusted proxy",
 		},
 		{
 			name:        "x_forwarded_for_header",
 			headers:     map[string]string{"X-Forwarded-For": "10.0.0.5, 192.168.1.1"},
 			remoteAddr:  "127.0.0.1:12345",
-			expectedIP:  "10.0.0.5",
-			description: "Should use first IP from X-Forwarded-For header",
+			expectedIP:  "192.168.1.1",
+			description: "Should use the last untrusted IP from X-Forwarded-For",
+		},
+		{
+			name:        "untrusted_headers_ignored",
+			headers:     map[string]string{"X-Real-IP": "192.168.1.100"},
+			remoteAddr:  "203.0.113.42:54321",
+			expectedIP:  "203.0.113.42",
+			description: "Should ignore forwarding headers from untrusted clients",
 		},
 		{
 			name:        "fallback_to_remote_addr",
@@ -144,8 +147,9 @@ func TestGetClientIP(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			router := gin.New()
+			assert.NoError(t, ConfigureClientIP(router, nil, nil))
 			router.GET("/test", func(c *gin.Context) {
-				ip := getClientIP(c, config)
+				ip := getClientIP(c)
 				c.JSON(http.StatusOK, gin.H{"ip": ip})
 			})
 
@@ -165,25 +169,6 @@ func TestGetClientIP(t *testing.T) {
 	}
 }
 
-func TestExtractFirstIP(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"192.168.1.1", "192.168.1.1"},
-		{"10.0.0.1, 192.168.1.1", "10.0.0.1"},
-		{"203.0.113.42, 198.51.100.1, 127.0.0.1", "203.0.113.42"},
-		{"  192.168.1.5  , 10.0.0.1", "  192.168.1.5  "},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			result := extractFirstIP(tt.input)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestShouldSkipPath(t *testing.T) {
 	skipPaths := []string{"/health", "/metrics", "/debug/vars"}
 
@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadOnlyStore keeps read-only mode as set through the API, so that every
+// gateway instance follows it and restarted instances come back in it. Get
+// returns nil until it was first set.
+type ReadOnlyStore interface {
+	Get() (*types.ReadOnlyStatus, error)
+	Save(status *types.ReadOnlyStatus) error
+}
+
+// ReadOnlyMode is a switch that rejects mutating management requests.
+// Routes listed as exempt, such as login and MCP tool execution, keep
+// working while it is on. Without a store it only covers this process.
+type ReadOnlyMode struct {
+	store  ReadOnlyStore
+	exempt map[string]bool
+	// stored is when the state last applied from the store was set
+	stored time.Time
+	status types.ReadOnlyStatus
+	mu     sync.RWMutex
+}
+
+// NewReadOnlyMode creates the switch with its initial state from configuration.
+// exemptPaths are gin route patterns (as returned by c.FullPath) that stay
+// writable in read-only mode.
+func NewReadOnlyMode(enabled bool, exemptPaths ...string) *ReadOnlyMode {
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	return &ReadOnlyMode{
+		exempt: exempt,
+		status: types.ReadOnlyStatus{
+			Enabled: enabled,
+			Source:  types.ReadOnlySourceConfig,
+		},
+	}
+}
+
+// Enabled reports whether management writes are rejected
+func (m *ReadOnlyMode) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status.Enabled
+}
+
+// Status returns a snapshot of the current state
+func (m *ReadOnlyMode) Status() types.ReadOnlyStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// SetStore shares the mode through store and applies the state stored
+// there, if any, over the configured one
+func (m *ReadOnlyMode) SetStore(store ReadOnlyStore) error {
+	m.mu.Lock()
+	m.store = store
+	m.mu.Unlock()
+	return m.Refresh()
+}
+
+// Set turns read-only mode on or off and records who changed it. The state
+// is saved to the store first, so it is not applied if saving fails.
+func (m *ReadOnlyMode) Set(enabled bool, reason, changedBy string) (types.ReadOnlyStatus, error) {
+	now := time.Now().UTC()
+	status := types.ReadOnlyStatus{
+		Enabled:   enabled,
+		Reason:    reason,
+		ChangedBy: changedBy,
+		ChangedAt: &now,
+		Source:    types.ReadOnlySourceAPI,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.store != nil {
+		if err := m.store.Save(&status); err != nil {
+			return m.status, fmt.Errorf("failed to save read-only mode: %w", err)
+		}
+		m.stored = now
+	}
+	m.status = status
+	return m.status, nil
+}
+
+// Refresh applies the stored state when it changed since it was last
+// applied, such as after another instance set it
+func (m *ReadOnlyMode) Refresh() error {
+	m.mu.RLock()
+	store := m.store
+	m.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+
+	status, err := store.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load read-only mode: %w", err)
+	}
+	if status == nil || status.ChangedAt == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !status.ChangedAt.After(m.stored) {
+		return nil
+	}
+	m.stored = *status.ChangedAt
+	m.status = *status
+	return nil
+}
+
+// Watch refreshes the mode from the store every interval until ctx is done
+func (m *ReadOnlyMode) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Refresh(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+	}
+}
+
+// SetFailover turns read-only mode on when another region fenced this one
+// and off when this region was promoted. Promotion only lifts read-only
+// mode a fence turned on, leaving mode set by configuration or the API.
+func (m *ReadOnlyMode) SetFailover(enabled bool, reason, changedBy string) types.ReadOnlyStatus {
+	now := time.Now().UTC()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !enabled && m.status.Source != types.ReadOnlySourceFailover {
+		return m.status
+	}
+	m.status = types.ReadOnlyStatus{
+		Enabled:   enabled,
+		Reason:    reason,
+		ChangedBy: changedBy,
+		ChangedAt: &now,
+		Source:    types.ReadOnlySourceFailover,
+	}
+	return m.status
+}
+
+// Handler rejects POST, PUT, PATCH and DELETE requests on non-exempt routes
+// while read-only mode is enabled
+func (m *ReadOnlyMode) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isMutatingMethod(c.Request.Method) || m.exempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		status := m.Status()
+		if !status.Enabled {
+			c.Next()
+			return
+		}
+
+		apiErr := types.NewReadOnlyModeError("Gateway is in read-only mode; management changes are disabled")
+		apiErr.Details = status.Reason
+		c.AbortWithStatusJSON(apiErr.Status, &types.ErrorResponse{
+			Error:   apiErr,
+			Success: false,
+		})
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
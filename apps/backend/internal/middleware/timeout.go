@@ -2,7 +2,10 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
@@ -20,12 +23,24 @@ func Timeout() gin.HandlerFunc {
 // TimeoutWithConfig returns a middleware that times out requests with custom configuration
 func TimeoutWithConfig(config *TimeoutConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Create context with timeout
-		ctx, cancel := context.WithTimeout(c.Request.Context(), config.Timeout)
+		// Create context with timeout. Handlers that wait on purpose, such as
+		// a tool call held for approval, may push the deadline back.
+		ctx, cancel := newExtendableContext(c.Request.Context(), time.Now().Add(config.Timeout))
 		defer cancel()
 
+		extend := func(until time.Time) {
+			if !ctx.extend(until) {
+				return
+			}
+			// The server's write timeout would otherwise cut the response off
+			err := http.NewResponseController(c.Writer).SetWriteDeadline(until)
+			if err != nil && !errors.Is(err, http.ErrNotSupported) {
+				log.Printf("Failed to extend write deadline: %v", err)
+			}
+		}
+
 		// Replace request context
-		c.Request = c.Request.WithContext(ctx)
+		c.Request = c.Request.WithContext(types.WithDeadlineExtender(ctx, extend))
 
 		// Use a simple context check instead of goroutines to avoid races
 		c.Next()
@@ -61,3 +76,88 @@ type TimeoutConfig struct {
 	PanicHandler   func(c *gin.Context, err interface{})
 	Timeout        time.Duration
 }
+
+// extendableContext is a context with a deadline that can be pushed back.
+// Like a context.WithDeadline context it reports context.DeadlineExceeded
+// once the deadline passes.
+type extendableContext struct {
+	context.Context
+	deadline time.Time
+	timer    *time.Timer
+	done     chan struct{}
+	err      error
+	mu       sync.Mutex
+}
+
+func newExtendableContext(parent context.Context, deadline time.Time) (*extendableContext, context.CancelFunc) {
+	ctx := &extendableContext{
+		Context:  parent,
+		deadline: deadline,
+		done:     make(chan struct{}),
+	}
+	ctx.mu.Lock()
+	ctx.timer = time.AfterFunc(time.Until(deadline), ctx.expire)
+	ctx.mu.Unlock()
+
+	go func() {
+		select {
+		case <-parent.Done():
+			ctx.cancel(parent.Err())
+		case <-ctx.done:
+		}
+	}()
+
+	return ctx, func() { ctx.cancel(context.Canceled) }
+}
+
+func (c *extendableContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deadline, true
+}
+
+func (c *extendableContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *extendableContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// extend moves the deadline to until when that is later, and reports
+// whether it did
+func (c *extendableContext) extend(until time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil || !until.After(c.deadline) {
+		return false
+	}
+	c.deadline = until
+	c.timer.Reset(time.Until(until))
+	return true
+}
+
+// expire cancels the context unless the deadline was pushed back after the
+// timer fired
+func (c *extendableContext) expire() {
+	c.mu.Lock()
+	if time.Now().Before(c.deadline) {
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+	c.cancel(context.DeadlineExceeded)
+}
+
+func (c *extendableContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	c.timer.Stop()
+	close(c.done)
+}
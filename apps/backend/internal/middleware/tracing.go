@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span for every request, continuing the trace of
+// a caller that sent a traceparent header. Spans are named after the route
+// template, like the request metrics, and are only recorded once tracing is
+// installed.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		ctx := observability.ExtractHTTP(c.Request.Context(), c.Request.Header)
+		ctx, span := observability.StartSpan(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+			))
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		for _, err := range c.Errors {
+			span.RecordError(err.Err)
+		}
+	}
+}
+
+// traceStageKey holds the stage of a traced chain whose span is open
+const traceStageKey = "trace_stage"
+
+type tracedStage struct {
+	span   trace.Span
+	parent trace.Span
+}
+
+// TraceStage runs a middleware in a span named after its stage, such as
+// endpoint.auth, so that the time spent in each stage of a chain shows in
+// the trace. The span ends when the stage hands the request on, at the next
+// TraceStage or at EndTraceStages, which must follow the last stage of a
+// chain. A stage that aborts the request marks its span with the status it
+// answered with.
+func TraceStage(name string, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		endTraceStage(c)
+
+		parent := trace.SpanFromContext(c.Request.Context())
+		ctx, span := observability.StartSpan(c.Request.Context(), name)
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(traceStageKey, &tracedStage{span: span, parent: parent})
+
+		handler(c)
+
+		if c.IsAborted() {
+			span.SetAttributes(
+				attribute.Bool("gateway.aborted", true),
+				attribute.Int("http.response.status_code", c.Writer.Status()),
+			)
+		}
+		endTraceStage(c)
+	}
+}
+
+// EndTraceStages ends the span of the last stage of a traced chain, so that
+// the handler does not count towards it
+func EndTraceStages() gin.HandlerFunc {
+	return endTraceStage
+}
+
+// endTraceStage ends the open stage span, if any. Its parent becomes the
+// active span again; the request may carry values the stage added to its
+// context, so only the active span is swapped back.
+func endTraceStage(c *gin.Context) {
+	val, _ := c.Get(traceStageKey)
+	stage, _ := val.(*tracedStage)
+	if stage == nil {
+		return
+	}
+	c.Set(traceStageKey, (*tracedStage)(nil))
+	stage.span.End()
+	c.Request = c.Request.WithContext(trace.ContextWithSpan(c.Request.Context(), stage.parent))
+}
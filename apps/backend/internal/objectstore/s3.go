@@ -0,0 +1,277 @@
+// Package objectstore keeps files in S3-compatible object storage. Requests
+// are signed with AWS Signature Version 4, so any S3-compatible service
+// (AWS S3, MinIO, Ceph, R2) works without the AWS SDK.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// S3RequestTimeout bounds one upload or download
+	S3RequestTimeout = 5 * time.Minute
+	s3Service        = "s3"
+	amzDateFormat    = "20060102T150405Z"
+)
+
+// S3Config locates a bucket and the credentials to reach it
+type S3Config struct {
+	// Endpoint is the service URL, https://s3.<region>.amazonaws.com when
+	// empty
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// HTTPClient sends the requests, a client with S3RequestTimeout when
+	// nil
+	HTTPClient *http.Client
+	// UsePathStyle addresses the bucket in the path rather than the host
+	// name, as MinIO and most self-hosted services expect
+	UsePathStyle bool
+}
+
+// S3Store keeps files as objects of one bucket, under an optional prefix
+type S3Store struct {
+	client *http.Client
+	now    func() time.Time
+	cfg    S3Config
+}
+
+// NewS3Store creates a store for the bucket of cfg
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("S3 bucket is required")
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("S3 region is required")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = AWSEndpoint(cfg.Region)
+	}
+	if _, err := url.Parse(cfg.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: S3RequestTimeout}
+	}
+	return &S3Store{
+		client: client,
+		now:    time.Now,
+		cfg:    cfg,
+	}, nil
+}
+
+// AWSEndpoint returns the AWS S3 service URL of region, the endpoint of
+// buckets configured without one
+func AWSEndpoint(region string) string {
+	return fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+}
+
+// Create returns a writer whose content is uploaded as the object of key
+// when it is closed
+func (s *S3Store) Create(key string) (io.WriteCloser, error) {
+	return &s3Upload{store: s, key: key}, nil
+}
+
+// Open downloads the object of key. Missing objects are reported as
+// os.ErrNotExist.
+func (s *S3Store) Open(key string) (io.ReadCloser, error) {
+	resp, err := s.do(context.Background(), http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("object %s: %w", key, os.ErrNotExist)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, s3Error(resp)
+	}
+	return resp.Body, nil
+}
+
+// Put uploads body as the object of key, replacing any existing object
+func (s *S3Store) Put(ctx context.Context, key string, body []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+// Delete removes the object of key; missing objects are not an error
+func (s *S3Store) Delete(key string) error {
+	resp, err := s.do(context.Background(), http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+func (s *S3Store) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+
+	payloadHash := sha256.Sum256(body)
+	SignV4(req, hex.EncodeToString(payloadHash[:]), s.cfg.AccessKeyID, s.cfg.SecretAccessKey, s.cfg.Region, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 %s %s failed: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+func (s *S3Store) objectURL(key string) string {
+	endpoint, _ := url.Parse(s.cfg.Endpoint)
+	path := strings.TrimLeft(key, "/")
+	if s.cfg.Prefix != "" {
+		path = s.cfg.Prefix + "/" + path
+	}
+	if s.cfg.UsePathStyle {
+		path = s.cfg.Bucket + "/" + path
+	} else {
+		endpoint.Host = s.cfg.Bucket + "." + endpoint.Host
+	}
+	return endpoint.Scheme + "://" + endpoint.Host + strings.TrimRight(endpoint.Path, "/") + "/" + uriEncode(path, false)
+}
+
+// s3Upload buffers an object until it is closed
+type s3Upload struct {
+	store  *S3Store
+	key    string
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (u *s3Upload) Write(p []byte) (int, error) {
+	return u.buf.Write(p)
+}
+
+func (u *s3Upload) Close() error {
+	if u.closed {
+		return nil
+	}
+	u.closed = true
+	return u.store.Put(context.Background(), u.key, u.buf.Bytes())
+}
+
+// SignV4 signs req for AWS Signature Version 4 with the hex SHA-256 of its
+// payload, setting its Host, X-Amz-Date, X-Amz-Content-Sha256 and
+// Authorization headers. Every header already set on req is signed.
+func SignV4(req *http.Request, payloadHash, accessKeyID, secretAccessKey, region string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + s3Service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes every byte but unreserved characters, and
+// slashes unless encodeSlash
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func s3Error(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
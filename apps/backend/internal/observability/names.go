@@ -0,0 +1,50 @@
+package observability
+
+// DatadogMetricNames maps gateway metric names to Datadog-style dotted names
+var DatadogMetricNames = map[string]string{
+	"http_request_duration":        "omnimesh.http.request.duration",
+	"http_requests_total":          "omnimesh.http.requests",
+	"tool_execution_duration":      "omnimesh.mcp.tool.duration",
+	"tool_executions_total":        "omnimesh.mcp.tool.executions",
+	"tool_schema_violations_total": "omnimesh.mcp.tool.schema_violations",
+	"retention_rows_purged_total":  "omnimesh.retention.rows_purged",
+	"retention_rows_expired":       "omnimesh.retention.rows_expired",
+}
+
+// DatadogTagNames maps gateway metric tag keys to Datadog standard tag keys
+var DatadogTagNames = map[string]string{
+	"method": "http.method",
+	"route":  "http.route",
+	"path":   "http.url_details.path",
+	"status": "http.status_code",
+}
+
+// PrometheusMetricHelp describes gateway metrics in the HELP lines of the
+// Prometheus exporter
+var PrometheusMetricHelp = map[string]string{
+	"http_request_duration":         "Latency of HTTP requests by route",
+	"http_requests_total":           "HTTP requests by route and status",
+	"mcp_server_calls_total":        "Calls to upstream MCP servers by method and outcome",
+	"mcp_server_call_duration":      "Latency of calls to upstream MCP servers",
+	"rate_limit_rejections_total":   "Requests rejected by rate limits, by route",
+	"health_checks_total":           "Health checks of MCP servers by result",
+	"mcp_server_healthy":            "Whether the last health check of an MCP server passed",
+	"tool_execution_duration":       "Latency of tool executions",
+	"tool_executions_total":         "Tool executions by tool and success",
+	"tool_schema_violations_total":  "Tool calls failing their input or output schema",
+	"logging_entries_dropped_total": "Log entries dropped by the logging buffer",
+	"inbound_replay_rejected_total": "Inbound requests rejected as replays",
+	"retention_rows_purged_total":   "Log rows deleted past organization retention, by table",
+	"retention_rows_expired":        "Log rows past organization retention found by a dry run, by table",
+	"load_shedding_limit":           "Adaptive concurrency limit of the gateway",
+	"load_shedding_rejected_total":  "Requests shed with a 503 by the adaptive concurrency limit",
+	"service_health_score":          "Health score of the gateway instance from its dependency probes",
+	"dependency_health_score":       "Health score of a gateway dependency from its last probe",
+}
+
+func prometheusHelp(name string) string {
+	if help, ok := PrometheusMetricHelp[name]; ok {
+		return help
+	}
+	return "Gateway metric " + name
+}
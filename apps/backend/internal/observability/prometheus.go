@@ -0,0 +1,382 @@
+package observability
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// PrometheusContentType is the content type of the text exposition format
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultPrometheusBuckets are the histogram bucket bounds in seconds
+var DefaultPrometheusBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+const defaultPrometheusMaxSeries = 10000
+
+// PrometheusConfig configures the Prometheus exporter
+type PrometheusConfig struct {
+	Namespace string    // Prefix of every metric name, such as omnimesh
+	Buckets   []float64 // Histogram bucket bounds in seconds
+	MaxSeries int       // Label combinations kept per metric; further ones are dropped
+}
+
+// Sample is one value of a gauge read at scrape time
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// GaugeFunc reads the current values of a gauge
+type GaugeFunc func() []Sample
+
+// PrometheusExporter aggregates gateway metrics and renders them in the
+// Prometheus text exposition format. Counters are summed and gauges keep
+// their last value. Histograms and summaries, which the gateway records in
+// milliseconds, become histograms in seconds named <name>_seconds.
+type PrometheusExporter struct {
+	config   *PrometheusConfig
+	families map[string]*family
+	gauges   map[string]*gaugeFunc
+	dropped  map[string]uint64
+	mu       sync.Mutex
+}
+
+type family struct {
+	name       string
+	help       string
+	metricType string
+	series     map[string]*series
+}
+
+type series struct {
+	labels  string
+	value   float64
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+type gaugeFunc struct {
+	help    string
+	collect GaugeFunc
+}
+
+// NewPrometheusExporter creates an exporter
+func NewPrometheusExporter(config *PrometheusConfig) *PrometheusExporter {
+	if config == nil {
+		config = &PrometheusConfig{}
+	}
+	if len(config.Buckets) == 0 {
+		config.Buckets = DefaultPrometheusBuckets
+	}
+	if config.MaxSeries <= 0 {
+		config.MaxSeries = defaultPrometheusMaxSeries
+	}
+	return &PrometheusExporter{
+		config:   config,
+		families: make(map[string]*family),
+		gauges:   make(map[string]*gaugeFunc),
+		dropped:  make(map[string]uint64),
+	}
+}
+
+// Emit records a single metric
+func (e *PrometheusExporter) Emit(metric *types.Metric) error {
+	if metric == nil || metric.Name == "" {
+		return nil
+	}
+
+	name, metricType, value := e.convert(metric)
+	labels := formatLabels(metricLabels(metric))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	f, ok := e.families[name]
+	if !ok {
+		f = &family{
+			name:       name,
+			help:       prometheusHelp(metric.Name),
+			metricType: metricType,
+			series:     make(map[string]*series),
+		}
+		e.families[name] = f
+	} else if f.metricType != metricType {
+		return fmt.Errorf("metric %s is already a %s", name, f.metricType)
+	}
+
+	s, ok := f.series[labels]
+	if !ok {
+		if len(f.series) >= e.config.MaxSeries {
+			e.dropped[name]++
+			return nil
+		}
+		s = &series{labels: labels}
+		if metricType == "histogram" {
+			s.buckets = make([]uint64, len(e.config.Buckets))
+		}
+		f.series[labels] = s
+	}
+
+	switch metricType {
+	case "counter":
+		s.value += value
+	case "gauge":
+		s.value = value
+	case "histogram":
+		s.count++
+		s.sum += value
+		for i, bound := range e.config.Buckets {
+			if value <= bound {
+				s.buckets[i]++
+			}
+		}
+	}
+	return nil
+}
+
+// Close has nothing to release
+func (e *PrometheusExporter) Close() error {
+	return nil
+}
+
+// RegisterGauge adds a gauge whose values are read from collect at every
+// scrape, for state the gateway keeps rather than emits, such as open
+// sessions
+func (e *PrometheusExporter) RegisterGauge(name, help string, collect GaugeFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.gauges[e.metricName(name)] = &gaugeFunc{help: help, collect: collect}
+}
+
+// convert maps a gateway metric to its Prometheus name, type and value
+func (e *PrometheusExporter) convert(metric *types.Metric) (string, string, float64) {
+	name := e.metricName(metric.Name)
+	switch metric.Type {
+	case types.MetricTypeCounter:
+		if !strings.HasSuffix(name, "_total") {
+			name += "_total"
+		}
+		return name, "counter", metric.Value
+	case types.MetricTypeHistogram, types.MetricTypeSummary:
+		return name + "_seconds", "histogram", metric.Value / 1000
+	default:
+		return name, "gauge", metric.Value
+	}
+}
+
+func (e *PrometheusExporter) metricName(name string) string {
+	name = sanitizeName(name)
+	if e.config.Namespace != "" {
+		return sanitizeName(e.config.Namespace) + "_" + name
+	}
+	return name
+}
+
+// Write renders every metric in the text exposition format
+func (e *PrometheusExporter) Write(w io.Writer) error {
+	// Gauges are read outside the lock, since collecting them may take the
+	// locks of other components
+	e.mu.Lock()
+	gauges := make(map[string]*gaugeFunc, len(e.gauges))
+	for name, gauge := range e.gauges {
+		gauges[name] = gauge
+	}
+	e.mu.Unlock()
+
+	collected := make(map[string][]Sample, len(gauges))
+	for name, gauge := range gauges {
+		collected[name] = gauge.collect()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	names := make([]string, 0, len(e.families)+len(gauges)+1)
+	for name := range e.families {
+		names = append(names, name)
+	}
+	for name := range gauges {
+		if _, ok := e.families[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	out := bufio.NewWriter(w)
+	for _, name := range names {
+		if f, ok := e.families[name]; ok {
+			e.writeFamily(out, f)
+			continue
+		}
+		writeHeader(out, name, gauges[name].help, "gauge")
+		samples := collected[name]
+		lines := make([]string, 0, len(samples))
+		for _, sample := range samples {
+			lines = append(lines, name+formatLabels(sample.Labels)+" "+formatValue(sample.Value))
+		}
+		sort.Strings(lines)
+		for _, line := range lines {
+			out.WriteString(line)
+			out.WriteByte('\n')
+		}
+	}
+
+	if len(e.dropped) > 0 {
+		dropped := e.metricName("prometheus_series_dropped_total")
+		writeHeader(out, dropped, "Samples dropped because their metric reached max_series label combinations", "counter")
+		metrics := make([]string, 0, len(e.dropped))
+		for name := range e.dropped {
+			metrics = append(metrics, name)
+		}
+		sort.Strings(metrics)
+		for _, name := range metrics {
+			fmt.Fprintf(out, "%s%s %d\n", dropped, formatLabels(map[string]string{"metric": name}), e.dropped[name])
+		}
+	}
+	return out.Flush()
+}
+
+func (e *PrometheusExporter) writeFamily(out *bufio.Writer, f *family) {
+	writeHeader(out, f.name, f.help, f.metricType)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+		if f.metricType != "histogram" {
+			fmt.Fprintf(out, "%s%s %s\n", f.name, s.labels, formatValue(s.value))
+			continue
+		}
+		for i, bound := range e.config.Buckets {
+			fmt.Fprintf(out, "%s_bucket%s %d\n", f.name, withLabel(s.labels, "le", formatValue(bound)), s.buckets[i])
+		}
+		fmt.Fprintf(out, "%s_bucket%s %d\n", f.name, withLabel(s.labels, "le", "+Inf"), s.count)
+		fmt.Fprintf(out, "%s_sum%s %s\n", f.name, s.labels, formatValue(s.sum))
+		fmt.Fprintf(out, "%s_count%s %d\n", f.name, s.labels, s.count)
+	}
+}
+
+// Handler serves the metrics. A non-empty bearerToken must be presented in
+// the Authorization header.
+func (e *PrometheusExporter) Handler(bearerToken string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if bearerToken != "" {
+			presented := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(presented), []byte(bearerToken)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", PrometheusContentType)
+		_ = e.Write(w)
+	})
+}
+
+func writeHeader(out *bufio.Writer, name, help, metricType string) {
+	fmt.Fprintf(out, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+	fmt.Fprintf(out, "# TYPE %s %s\n", name, metricType)
+}
+
+// metricLabels returns the tags of a metric along with its organization and
+// server
+func metricLabels(metric *types.Metric) map[string]string {
+	labels := make(map[string]string, len(metric.Tags)+2)
+	for k, v := range metric.Tags {
+		labels[k] = v
+	}
+	if metric.OrganizationID != "" {
+		labels["org_id"] = metric.OrganizationID
+	}
+	if metric.ServerID != "" {
+		labels["server_id"] = metric.ServerID
+	}
+	return labels
+}
+
+// formatLabels renders a label set in a stable order, such as
+// {method="GET",status="200"}
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(sanitizeName(k))
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(labels[k]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// withLabel adds a label to a formatted label set
+func withLabel(labels, name, value string) string {
+	label := name + `="` + escapeLabelValue(value) + `"`
+	if labels == "" {
+		return "{" + label + "}"
+	}
+	return labels[:len(labels)-1] + "," + label + "}"
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// sanitizeName replaces the characters Prometheus does not allow in metric
+// and label names
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,203 @@
+package observability
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// StatsD wire flavors
+const (
+	FlavorStatsD    = "statsd"
+	FlavorDogStatsD = "dogstatsd"
+)
+
+// StatsDConfig configures the StatsD/DogStatsD metrics emitter
+type StatsDConfig struct {
+	GlobalTags  map[string]string  // Tags added to every metric (DogStatsD only)
+	SampleRates map[string]float64 // Per-metric sample rates keyed by gateway metric name
+	MetricNames map[string]string  // Overrides for the gateway-to-Datadog name mapping
+	Address     string
+	Prefix      string
+	Flavor      string
+	SampleRate  float64 // Default sample rate in (0, 1]
+}
+
+// StatsDEmitter sends gateway metrics to a StatsD or DogStatsD agent over UDP
+type StatsDEmitter struct {
+	conn   net.Conn
+	config *StatsDConfig
+	names  map[string]string
+	random func() float64
+	mu     sync.Mutex
+}
+
+// NewStatsDEmitter creates an emitter connected to the configured agent address
+func NewStatsDEmitter(config *StatsDConfig) (*StatsDEmitter, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if config.Address == "" {
+		return nil, fmt.Errorf("statsd address is required")
+	}
+	if config.Flavor == "" {
+		config.Flavor = FlavorDogStatsD
+	}
+	if config.Flavor != FlavorStatsD && config.Flavor != FlavorDogStatsD {
+		return nil, fmt.Errorf("unsupported statsd flavor: %s", config.Flavor)
+	}
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		config.SampleRate = 1
+	}
+
+	conn, err := net.Dial("udp", config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd agent: %w", err)
+	}
+
+	names := make(map[string]string, len(DatadogMetricNames)+len(config.MetricNames))
+	for k, v := range DatadogMetricNames {
+		names[k] = v
+	}
+	for k, v := range config.MetricNames {
+		names[k] = v
+	}
+
+	return &StatsDEmitter{
+		conn:   conn,
+		config: config,
+		names:  names,
+		random: rand.Float64,
+	}, nil
+}
+
+// Emit sends a single metric, subject to its sample rate
+func (e *StatsDEmitter) Emit(metric *types.Metric) error {
+	if metric == nil {
+		return nil
+	}
+
+	rate := e.sampleRate(metric.Name)
+	if rate < 1 && e.random() >= rate {
+		return nil
+	}
+
+	line := e.Format(metric, rate)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.conn.Write([]byte(line))
+	return err
+}
+
+// Close releases the UDP connection
+func (e *StatsDEmitter) Close() error {
+	return e.conn.Close()
+}
+
+// Format renders a metric in the StatsD line protocol. Tags are only
+// included for the DogStatsD flavor.
+func (e *StatsDEmitter) Format(metric *types.Metric, rate float64) string {
+	var b strings.Builder
+
+	b.WriteString(e.metricName(metric.Name))
+	b.WriteByte(':')
+	b.WriteString(strconv.FormatFloat(metric.Value, 'f', -1, 64))
+	b.WriteByte('|')
+	b.WriteString(e.metricType(metric.Type))
+
+	if rate < 1 {
+		b.WriteString("|@")
+		b.WriteString(strconv.FormatFloat(rate, 'f', -1, 64))
+	}
+
+	if e.config.Flavor == FlavorDogStatsD {
+		if tags := e.tags(metric); len(tags) > 0 {
+			b.WriteString("|#")
+			b.WriteString(strings.Join(tags, ","))
+		}
+	}
+
+	return b.String()
+}
+
+func (e *StatsDEmitter) sampleRate(name string) float64 {
+	if rate, ok := e.config.SampleRates[name]; ok && rate > 0 && rate <= 1 {
+		return rate
+	}
+	return e.config.SampleRate
+}
+
+func (e *StatsDEmitter) metricName(name string) string {
+	if mapped, ok := e.names[name]; ok {
+		name = mapped
+	}
+	if e.config.Prefix != "" {
+		return strings.TrimSuffix(e.config.Prefix, ".") + "." + name
+	}
+	return name
+}
+
+func (e *StatsDEmitter) metricType(metricType string) string {
+	switch metricType {
+	case types.MetricTypeCounter:
+		return "c"
+	case types.MetricTypeGauge:
+		return "g"
+	case types.MetricTypeHistogram:
+		if e.config.Flavor == FlavorDogStatsD {
+			return "h"
+		}
+		return "ms"
+	case types.MetricTypeSummary:
+		if e.config.Flavor == FlavorDogStatsD {
+			return "d"
+		}
+		return "ms"
+	default:
+		return "g"
+	}
+}
+
+func (e *StatsDEmitter) tags(metric *types.Metric) []string {
+	merged := make(map[string]string, len(e.config.GlobalTags)+len(metric.Tags)+2)
+	for k, v := range e.config.GlobalTags {
+		merged[k] = v
+	}
+	for k, v := range metric.Tags {
+		if mapped, ok := DatadogTagNames[k]; ok {
+			k = mapped
+		}
+		merged[k] = v
+	}
+	if metric.OrganizationID != "" {
+		merged["org_id"] = metric.OrganizationID
+	}
+	if metric.ServerID != "" {
+		merged["server_id"] = metric.ServerID
+	}
+
+	tags := make([]string, 0, len(merged))
+	for k, v := range merged {
+		tags = append(tags, sanitizeTag(k)+":"+sanitizeTag(v))
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// sanitizeTag strips characters that are reserved by the DogStatsD protocol
+func sanitizeTag(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '|', ',', '#', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
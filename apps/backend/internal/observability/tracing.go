@@ -0,0 +1,179 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName names the instrumentation scope of the gateway's spans
+const TracerName = "github.com/omnimesh-labs/omnimesh-gateway"
+
+// OTLP protocols spans can be exported with
+const (
+	TracingProtocolGRPC = "grpc"
+	TracingProtocolHTTP = "http"
+)
+
+// TracingConfig configures span export over OTLP
+type TracingConfig struct {
+	Headers        map[string]string // Sent with every export, such as an API key of the collector
+	ServiceName    string
+	ServiceVersion string
+	// Endpoint of the collector, host:port or a URL
+	Endpoint string
+	// Protocol is grpc or http
+	Protocol string
+	// SampleRatio is the share of new traces recorded; traces continued from
+	// a caller follow the caller's decision
+	SampleRatio float64
+	Insecure    bool
+}
+
+// InstallTracing exports spans to an OTLP collector and installs the
+// provider and the W3C trace context propagator globally. Until it is
+// called, spans are not recorded and no trace context is propagated. The
+// returned function flushes pending spans and stops the exporter.
+func InstallTracing(ctx context.Context, config *TracingConfig) (func(context.Context) error, error) {
+	exporter, err := newSpanExporter(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = "omnimesh-gateway"
+	}
+	attrs := []attribute.KeyValue{semconv.ServiceName(serviceName)}
+	if config.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(config.ServiceVersion))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+func newSpanExporter(ctx context.Context, config *TracingConfig) (sdktrace.SpanExporter, error) {
+	endpoint := config.Endpoint
+	isURL := strings.Contains(endpoint, "://")
+
+	switch config.Protocol {
+	case TracingProtocolHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithTimeout(10 * time.Second)}
+		if isURL {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		} else if endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(config.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(config.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case TracingProtocolGRPC, "":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithTimeout(10 * time.Second)}
+		if isURL {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(endpoint))
+		} else if endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(config.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(config.Headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported tracing protocol %q", config.Protocol)
+	}
+}
+
+// StartSpan starts a span of the gateway tracer
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, opts...)
+}
+
+// EndSpan marks the span failed when err is set and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// InjectHTTP adds the trace context of ctx to the headers of an outgoing
+// request
+func InjectHTTP(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// ExtractHTTP returns ctx continuing the trace context of incoming headers
+func ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// InjectMeta returns a copy of a JSON-RPC _meta object with the trace
+// context of ctx added as traceparent and tracestate, so that MCP servers
+// can continue the trace whatever transport carries the message. meta is
+// returned as is when there is nothing to propagate.
+func InjectMeta(ctx context.Context, meta map[string]interface{}) map[string]interface{} {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return meta
+	}
+
+	merged := make(map[string]interface{}, len(meta)+len(carrier))
+	for k, v := range meta {
+		merged[k] = v
+	}
+	for k, v := range carrier {
+		merged[k] = v
+	}
+	return merged
+}
+
+// ExtractMeta returns ctx continuing the trace context carried in a
+// JSON-RPC _meta object, for messages of long-lived connections whose
+// callers trace each message separately
+func ExtractMeta(ctx context.Context, meta interface{}) context.Context {
+	fields, _ := meta.(map[string]interface{})
+	if len(fields) == 0 {
+		return ctx
+	}
+	carrier := propagation.MapCarrier{}
+	for k, v := range fields {
+		if s, ok := v.(string); ok {
+			carrier[strings.ToLower(k)] = s
+		}
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log"
 	"sync"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
@@ -22,6 +23,7 @@ import (
 type pluginService struct {
 	manager     shared.PluginManager
 	registry    shared.PluginRegistry
+	egress      shared.EgressPolicy
 	db          *sql.DB
 	orgPlugins  map[string][]Plugin
 	mu          sync.RWMutex
@@ -125,8 +127,13 @@ func (s *pluginService) LoadPluginsFromDatabase(ctx context.Context, organizatio
 			return fmt.Errorf("failed to parse filter config for filter %s: %w", cf.Name, err)
 		}
 
-		// Convert to filter instance
+		// Convert to filter instance. Filters whose endpoint the offline
+		// policy blocks are skipped so the remaining filters still apply.
 		filter, err := s.createFilterFromModel(&cf)
+		if types.IsError(err, types.ErrCodeConnectivityRequired) {
+			log.Printf("Skipping content filter %s: %v", cf.Name, err)
+			continue
+		}
 		if err != nil {
 			return fmt.Errorf("failed to create filter instance for %s: %w", cf.Name, err)
 		}
@@ -359,6 +366,10 @@ func (s *pluginService) createFilterFromModel(cf *models.ContentFilter) (Plugin,
 		return nil, err
 	}
 
+	if err := s.CheckEgress(pluginType, cf.Config); err != nil {
+		return nil, err
+	}
+
 	// Create the filter with the stored configuration
 	filter, err := factory.Create(cf.Config)
 	if err != nil {
@@ -374,6 +385,29 @@ func (s *pluginService) createFilterFromModel(cf *models.ContentFilter) (Plugin,
 	return filter, nil
 }
 
+// SetEgressPolicy restricts the external endpoints plugins may call
+func (s *pluginService) SetEgressPolicy(policy shared.EgressPolicy) {
+	s.egress = policy
+}
+
+// CheckEgress returns an error if an AI moderation plugin would call an
+// endpoint the egress policy blocks. Unset endpoints use the factory default.
+func (s *pluginService) CheckEgress(pluginType shared.PluginType, config map[string]interface{}) error {
+	if s.egress == nil || (pluginType != shared.PluginTypeLlamaGuard && pluginType != shared.PluginTypeOpenAIMod) {
+		return nil
+	}
+
+	endpoint, _ := config["api_endpoint"].(string)
+	if endpoint == "" {
+		factory, err := s.registry.Get(pluginType)
+		if err != nil {
+			return err
+		}
+		endpoint, _ = factory.GetDefaultConfig()["api_endpoint"].(string)
+	}
+	return s.egress.CheckURL(types.ConnectivityFeatureAIModeration, endpoint)
+}
+
 // LogViolation logs a filter violation to the database
 func (s *pluginService) LogViolation(ctx context.Context, violation *models.FilterViolation) error {
 	query := `
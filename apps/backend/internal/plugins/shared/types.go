@@ -343,6 +343,18 @@ type PluginService interface {
 
 	// GetViolations retrieves plugin violations with optional filtering
 	GetViolations(ctx context.Context, organizationID string, limit, offset int) ([]interface{}, error)
+
+	// SetEgressPolicy restricts the external endpoints plugins may call
+	SetEgressPolicy(policy EgressPolicy)
+
+	// CheckEgress returns an error if a plugin of the given type and
+	// configuration would call an endpoint the egress policy blocks
+	CheckEgress(pluginType PluginType, config map[string]interface{}) error
+}
+
+// EgressPolicy decides whether a feature may call an external URL
+type EgressPolicy interface {
+	CheckURL(feature, rawURL string) error
 }
 
 // PluginRegistry manages available plugin factories
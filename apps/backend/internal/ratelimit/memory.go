@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how often idle counters are dropped
+const memorySweepInterval = time.Minute
+
+// memoryCounter is the state of one key. Token buckets use tokens and
+// updated; sliding windows count requests per fixed window index.
+type memoryCounter struct {
+	updated  time.Time
+	expires  time.Time
+	tokens   float64
+	window   int64
+	current  int64
+	previous int64
+}
+
+// MemoryLimiter keeps counters in process memory. Limits are per gateway
+// instance.
+type MemoryLimiter struct {
+	counters  map[string]*memoryCounter
+	now       func() time.Time
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+// NewMemoryLimiter creates an in-memory limiter
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		counters: make(map[string]*memoryCounter),
+		now:      time.Now,
+	}
+}
+
+// SetClock replaces the clock requests are counted against
+func (l *MemoryLimiter) SetClock(now func() time.Time) {
+	l.now = now
+}
+
+// Allow counts a request for key against policy
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, policy Policy) (*Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	counter, exists := l.counters[key]
+	if !exists {
+		counter = &memoryCounter{tokens: policy.capacity(), updated: now}
+		l.counters[key] = counter
+	}
+	counter.expires = now.Add(2 * policy.Window)
+
+	if policy.Algorithm == AlgorithmTokenBucket {
+		elapsed := now.Sub(counter.updated).Seconds()
+		if elapsed > 0 {
+			counter.tokens = min(policy.capacity(), counter.tokens+elapsed*policy.refillRate())
+		}
+		counter.updated = now
+
+		allowed := counter.tokens >= 1
+		if allowed {
+			counter.tokens--
+		}
+		return tokenBucketResult(policy, counter.tokens, allowed, now), nil
+	}
+
+	window := now.UnixNano() / int64(policy.Window)
+	switch {
+	case counter.window == window-1:
+		counter.previous, counter.current = counter.current, 0
+	case counter.window != window:
+		counter.previous, counter.current = 0, 0
+	}
+	counter.window = window
+
+	elapsed := time.Duration(now.UnixNano() - window*int64(policy.Window))
+	progress := elapsed.Seconds() / policy.Window.Seconds()
+	allowed := float64(counter.previous)*(1-progress)+float64(counter.current)+1 <= float64(policy.Limit)
+	if allowed {
+		counter.current++
+	}
+	return slidingWindowResult(policy, counter.previous, counter.current, elapsed, allowed, now), nil
+}
+
+// sweep drops counters idle long enough to have fully reset
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < memorySweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, counter := range l.counters {
+		if now.After(counter.expires) {
+			delete(l.counters, key)
+		}
+	}
+}
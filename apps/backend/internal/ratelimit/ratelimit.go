@@ -0,0 +1,138 @@
+// Package ratelimit enforces request rate limits with token bucket and
+// sliding window algorithms. Counters live in memory for a single gateway
+// instance or in Redis when several instances share the limits.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// Algorithms a policy can use
+const (
+	// AlgorithmTokenBucket refills Limit tokens every Window up to Burst,
+	// and lets each request take one, so short bursts pass
+	AlgorithmTokenBucket = "token_bucket"
+	// AlgorithmSlidingWindow allows Limit requests in any Window, estimating
+	// the count from the current and previous fixed windows
+	AlgorithmSlidingWindow = "sliding_window"
+)
+
+// Policy is a limit of Limit requests per Window
+type Policy struct {
+	Algorithm string
+	Window    time.Duration
+	Limit     int64
+	// Burst is how many requests a token bucket lets through at once,
+	// Limit when zero
+	Burst int64
+}
+
+// Validate reports whether the policy can be enforced
+func (p Policy) Validate() error {
+	if p.Algorithm != AlgorithmTokenBucket && p.Algorithm != AlgorithmSlidingWindow {
+		return errors.New("algorithm must be token_bucket or sliding_window")
+	}
+	if p.Limit <= 0 {
+		return errors.New("limit must be positive")
+	}
+	if p.Window < time.Second {
+		return errors.New("window must be at least one second")
+	}
+	if p.Burst < 0 {
+		return errors.New("burst cannot be negative")
+	}
+	return nil
+}
+
+// capacity is the most tokens a bucket holds
+func (p Policy) capacity() float64 {
+	if p.Burst > 0 {
+		return float64(p.Burst)
+	}
+	return float64(p.Limit)
+}
+
+// refillRate is how many tokens a bucket regains per second
+func (p Policy) refillRate() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// Result is the outcome of one request against a policy
+type Result struct {
+	// ResetAt is when the bucket is full or the window ends
+	ResetAt time.Time
+	// RetryAfter is how long a denied request should wait
+	RetryAfter time.Duration
+	Limit      int64
+	Remaining  int64
+	Allowed    bool
+}
+
+// Limiter counts requests against policies. Each key has its own counter.
+type Limiter interface {
+	Allow(ctx context.Context, key string, policy Policy) (*Result, error)
+}
+
+// tokenBucketResult describes a token bucket holding tokens after the
+// request took one, or could not
+func tokenBucketResult(policy Policy, tokens float64, allowed bool, now time.Time) *Result {
+	rate := policy.refillRate()
+	result := &Result{
+		Limit:     int64(policy.capacity()),
+		Remaining: int64(math.Floor(tokens)),
+		ResetAt:   now.Add(secondsDuration((policy.capacity() - tokens) / rate)),
+		Allowed:   allowed,
+	}
+	if !allowed {
+		result.RetryAfter = secondsDuration((1 - tokens) / rate)
+	}
+	return result
+}
+
+// slidingWindowResult describes a sliding window whose previous fixed
+// window counted previous requests and current one current, elapsed into
+// the current window
+func slidingWindowResult(policy Policy, previous, current int64, elapsed time.Duration, allowed bool, now time.Time) *Result {
+	window := policy.Window.Seconds()
+	progress := elapsed.Seconds() / window
+	estimate := float64(previous)*(1-progress) + float64(current)
+
+	result := &Result{
+		Limit:     policy.Limit,
+		Remaining: policy.Limit - int64(math.Ceil(estimate)),
+		ResetAt:   now.Add(policy.Window - elapsed),
+		Allowed:   allowed,
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if allowed {
+		return result
+	}
+
+	// Wait until the previous window's share has faded enough, or when the
+	// current window is full by itself, into the next window until its
+	// share has
+	limit := float64(policy.Limit)
+	if float64(current)+1 <= limit && previous > 0 {
+		wait := window*(1-(limit-float64(current)-1)/float64(previous)) - elapsed.Seconds()
+		result.RetryAfter = secondsDuration(wait)
+	} else {
+		wait := window - elapsed.Seconds()
+		if current > 0 {
+			wait += window * math.Max(0, 1-(limit-1)/float64(current))
+		}
+		result.RetryAfter = secondsDuration(wait)
+	}
+	return result
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces the limiter's keys
+const redisKeyPrefix = "ratelimit:"
+
+// Both scripts read the clock of the Redis server, so that instances with
+// drifting clocks count against the same windows.
+
+// tokenBucketScript refills the bucket in KEYS[1] for the time since its
+// last request and takes a token if one is left. ARGV holds the refill
+// rate per millisecond, the capacity and the key TTL in milliseconds. It
+// returns whether the request was allowed and the tokens left.
+var tokenBucketScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1])
+if tokens == nil then
+	tokens = capacity
+else
+	tokens = math.min(capacity, tokens + math.max(0, now - tonumber(state[2])) * rate)
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', now)
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript counts the request in the current fixed window of
+// KEYS[1] if the estimate over the sliding window leaves room. ARGV holds
+// the window in milliseconds and the limit. It returns whether the request
+// was allowed, the previous and current window counts and the milliseconds
+// elapsed in the current window.
+var slidingWindowScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+local index = math.floor(now / window)
+local currentKey = KEYS[1] .. ':' .. index
+local previous = tonumber(redis.call('GET', KEYS[1] .. ':' .. (index - 1)) or '0')
+local current = tonumber(redis.call('GET', currentKey) or '0')
+local elapsed = now - index * window
+
+local allowed = 0
+if previous * (1 - elapsed / window) + current + 1 <= limit then
+	current = redis.call('INCR', currentKey)
+	redis.call('PEXPIRE', currentKey, window * 2)
+	allowed = 1
+end
+return {allowed, previous, current, elapsed}
+`)
+
+// RedisLimiter keeps counters in Redis, so that every gateway instance
+// sharing the Redis server enforces the same limits
+type RedisLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter connects to Redis
+func NewRedisLimiter(addr, password string, db int) (*RedisLimiter, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &RedisLimiter{client: client}, nil
+}
+
+// Allow counts a request for key against policy
+func (l *RedisLimiter) Allow(ctx context.Context, key string, policy Policy) (*Result, error) {
+	key = redisKeyPrefix + key
+	windowMS := policy.Window.Milliseconds()
+
+	if policy.Algorithm == AlgorithmTokenBucket {
+		values, err := tokenBucketScript.Run(ctx, l.client, []string{key},
+			strconv.FormatFloat(policy.refillRate()/1000, 'g', -1, 64),
+			strconv.FormatFloat(policy.capacity(), 'g', -1, 64),
+			2*windowMS).Slice()
+		if err != nil {
+			return nil, fmt.Errorf("failed to take token: %w", err)
+		}
+		if len(values) != 2 {
+			return nil, fmt.Errorf("unexpected token bucket reply %v", values)
+		}
+		tokens, err := strconv.ParseFloat(fmt.Sprint(values[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected token count %v", values[1])
+		}
+		return tokenBucketResult(policy, tokens, values[0] == int64(1), time.Now()), nil
+	}
+
+	values, err := slidingWindowScript.Run(ctx, l.client, []string{key}, windowMS, policy.Limit).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count request: %w", err)
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("unexpected sliding window reply %v", values)
+	}
+	return slidingWindowResult(policy, values[1], values[2], time.Duration(values[3])*time.Millisecond,
+		values[0] == 1, time.Now()), nil
+}
+
+// Close closes the Redis connection
+func (l *RedisLimiter) Close() error {
+	return l.client.Close()
+}
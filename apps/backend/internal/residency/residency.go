@@ -0,0 +1,75 @@
+// Package residency keeps traffic of namespaces pinned to a region on
+// servers and storage in that region.
+package residency
+
+import (
+	"fmt"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Policy decides where pinned traffic may go. A nil Policy only rejects
+// servers marked with a different region.
+type Policy struct {
+	storageRegion string
+	requireMarked bool
+}
+
+// New creates a policy. storageRegion is the region of the gateway's
+// database and log storage; requireMarked also rejects servers and storage
+// without a region for pinned namespaces.
+func New(storageRegion string, requireMarked bool) (*Policy, error) {
+	if err := types.ValidateRegion(storageRegion); err != nil {
+		return nil, fmt.Errorf("storage region: %w", err)
+	}
+	return &Policy{storageRegion: storageRegion, requireMarked: requireMarked}, nil
+}
+
+// StorageRegion is the region of the gateway's storage, empty if unmarked
+func (p *Policy) StorageRegion() string {
+	if p == nil {
+		return ""
+	}
+	return p.storageRegion
+}
+
+// Allows reports whether traffic pinned to pinned may reach something marked
+// with region. Unpinned traffic may go anywhere.
+func (p *Policy) Allows(pinned, region string) bool {
+	switch {
+	case pinned == "":
+		return true
+	case region == "":
+		return p == nil || !p.requireMarked
+	}
+	return pinned == region
+}
+
+// CheckServer returns an error when traffic pinned to pinned may not be
+// routed through the named server
+func (p *Policy) CheckServer(pinned, serverName, serverRegion string) error {
+	if p.Allows(pinned, serverRegion) {
+		return nil
+	}
+	return types.NewResidencyViolationError(
+		fmt.Sprintf("Server %s is outside the namespace's region %s", serverName, pinned),
+		describe("server", serverRegion))
+}
+
+// CheckStorage returns an error when data of traffic pinned to pinned would
+// be stored outside that region
+func (p *Policy) CheckStorage(pinned string) error {
+	if p.Allows(pinned, p.StorageRegion()) {
+		return nil
+	}
+	return types.NewResidencyViolationError(
+		fmt.Sprintf("Gateway storage is outside the namespace's region %s", pinned),
+		describe("storage", p.StorageRegion()))
+}
+
+func describe(what, region string) string {
+	if region == "" {
+		return what + " has no region"
+	}
+	return what + " region is " + region
+}
@@ -0,0 +1,295 @@
+// Package retry retries failed upstream calls with exponential backoff and
+// jitter. Only calls that are safe to repeat are retried, and retries of each
+// server protocol are capped by a budget so that an outage is not amplified
+// by the gateway's own retries.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// DefaultBudget is the budget of protocols without a budget of their own
+const DefaultBudget = "default"
+
+const (
+	defaultBaseDelay = 100 * time.Millisecond
+	defaultMaxDelay  = 5 * time.Second
+)
+
+// safeMethods are the JSON-RPC methods that only read, and can be repeated
+// without side effects
+var safeMethods = map[string]bool{
+	"initialize":               true,
+	"ping":                     true,
+	"tools/list":               true,
+	"resources/list":           true,
+	"resources/read":           true,
+	"resources/templates/list": true,
+	"prompts/list":             true,
+	"prompts/get":              true,
+	"completion/complete":      true,
+}
+
+// IsSafeMethod reports whether a JSON-RPC method can be retried without an
+// idempotency key
+func IsSafeMethod(method string) bool {
+	return safeMethods[method]
+}
+
+// Budget caps the retries of a protocol. Every call adds Ratio to the
+// budget, up to Reserve, and every retry spends one.
+type Budget struct {
+	// Ratio is the share of calls that may be retried once the reserve is
+	// spent
+	Ratio float64
+	// Reserve is how many retries can be made in a burst
+	Reserve int
+}
+
+// Config holds the backoff delays and retry budgets
+type Config struct {
+	// Budgets are keyed by server protocol; DefaultBudget covers protocols
+	// without a budget. Protocols covered by neither are not capped.
+	Budgets   map[string]Budget
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// Call describes an upstream call that may be retried
+type Call struct {
+	ServerID string
+	Protocol string
+	Method   string
+	// IdempotencyKey makes calls to unsafe methods retryable; the server is
+	// expected to apply calls with the same key once
+	IdempotencyKey string
+	// MaxRetries is how many times the call is attempted again after it
+	// first fails
+	MaxRetries int
+}
+
+// Idempotent reports whether the call can be repeated
+func (c Call) Idempotent() bool {
+	return IsSafeMethod(c.Method) || c.IdempotencyKey != ""
+}
+
+// Retrier retries calls and counts the retries of each server. A nil
+// Retrier calls once.
+type Retrier struct {
+	config  Config
+	budgets map[string]*bucket
+	stats   sync.Map // server ID -> *counters
+	mu      sync.Mutex
+	rand    *rand.Rand
+}
+
+// New creates a retrier
+func New(config Config) *Retrier {
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = defaultBaseDelay
+	}
+	if config.MaxDelay < config.BaseDelay {
+		config.MaxDelay = defaultMaxDelay
+		if config.MaxDelay < config.BaseDelay {
+			config.MaxDelay = config.BaseDelay
+		}
+	}
+
+	budgets := make(map[string]*bucket, len(config.Budgets))
+	for protocol, budget := range config.Budgets {
+		budgets[protocol] = &bucket{
+			ratio:   budget.Ratio,
+			reserve: float64(budget.Reserve),
+			tokens:  float64(budget.Reserve),
+		}
+	}
+	return &Retrier{
+		config:  config,
+		budgets: budgets,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Do calls fn and retries it while it fails with a retryable error, the call
+// is idempotent, its retries are not exhausted and the protocol's budget
+// allows. It returns how many attempts were made and the last error.
+func (r *Retrier) Do(ctx context.Context, call Call, fn func(ctx context.Context) error) (int, error) {
+	if r == nil {
+		return 1, unwrap(fn(ctx))
+	}
+
+	stats := r.counters(call.ServerID)
+	stats.calls.Add(1)
+	budget := r.budget(call.Protocol)
+	budget.deposit()
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		switch {
+		case err == nil:
+			if attempt > 1 {
+				stats.recovered.Add(1)
+			}
+			return attempt, nil
+		case !retryable(ctx, err):
+			return attempt, unwrap(err)
+		case attempt > call.MaxRetries:
+			if call.MaxRetries > 0 {
+				stats.exhausted.Add(1)
+			}
+			return attempt, err
+		case !call.Idempotent():
+			stats.notIdempotent.Add(1)
+			return attempt, err
+		case !budget.withdraw():
+			stats.budgetDenied.Add(1)
+			return attempt, err
+		}
+
+		stats.retries.Add(1)
+		timer := time.NewTimer(r.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, err
+		case <-timer.C:
+		}
+	}
+}
+
+// Stats returns the retry counts of a server, or nil when none of its calls
+// went through the retrier
+func (r *Retrier) Stats(serverID string) *types.RetryStats {
+	if r == nil {
+		return nil
+	}
+	value, ok := r.stats.Load(serverID)
+	if !ok {
+		return nil
+	}
+	stats := value.(*counters)
+	return &types.RetryStats{
+		Calls:         stats.calls.Load(),
+		Retries:       stats.retries.Load(),
+		Recovered:     stats.recovered.Load(),
+		Exhausted:     stats.exhausted.Load(),
+		BudgetDenied:  stats.budgetDenied.Load(),
+		NotIdempotent: stats.notIdempotent.Load(),
+	}
+}
+
+// backoff returns how long to wait before the retry following attempt: an
+// exponentially growing delay of which the second half is random, so that
+// callers failing together do not retry together
+func (r *Retrier) backoff(attempt int) time.Duration {
+	delay := r.config.MaxDelay
+	if shift := attempt - 1; shift < 32 {
+		if exp := r.config.BaseDelay << shift; exp > 0 && exp < delay {
+			delay = exp
+		}
+	}
+
+	half := delay / 2
+	r.mu.Lock()
+	jitter := time.Duration(r.rand.Int63n(int64(half) + 1))
+	r.mu.Unlock()
+	return half + jitter
+}
+
+func (r *Retrier) budget(protocol string) *bucket {
+	if budget, ok := r.budgets[protocol]; ok {
+		return budget
+	}
+	return r.budgets[DefaultBudget]
+}
+
+func (r *Retrier) counters(serverID string) *counters {
+	value, _ := r.stats.LoadOrStore(serverID, &counters{})
+	return value.(*counters)
+}
+
+type counters struct {
+	calls         atomic.Int64
+	retries       atomic.Int64
+	recovered     atomic.Int64
+	exhausted     atomic.Int64
+	budgetDenied  atomic.Int64
+	notIdempotent atomic.Int64
+}
+
+// bucket is a retry budget. A nil bucket does not cap retries.
+type bucket struct {
+	mu      sync.Mutex
+	ratio   float64
+	reserve float64
+	tokens  float64
+}
+
+func (b *bucket) deposit() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > b.reserve {
+		b.tokens = b.reserve
+	}
+}
+
+func (b *bucket) withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// permanentError is a failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as a failure that is not retried, such as a call the
+// server rejected as invalid
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func retryable(ctx context.Context, err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) || ctx.Err() != nil {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func unwrap(err error) error {
+	if permanent, ok := err.(*permanentError); ok {
+		return permanent.err
+	}
+	return err
+}
@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Adaptive limiter defaults
+const (
+	DefaultInitialLimit   = 100
+	DefaultMinLimit       = 10
+	DefaultMaxLimit       = 2000
+	DefaultLatencyFactor  = 2.0
+	DefaultBackoff        = 0.9
+	DefaultBaselineWindow = time.Minute
+
+	// latencySmoothing is the weight of each new sample in the smoothed latency
+	latencySmoothing = 0.1
+)
+
+// AdaptiveLimiterConfig configures an adaptive concurrency limit
+type AdaptiveLimiterConfig struct {
+	// InitialLimit is the concurrency limit before any latency is measured
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// LatencyFactor is how far the smoothed latency may rise above the
+	// baseline before the limit is lowered
+	LatencyFactor float64
+	// Backoff multiplies the limit each time latency degrades
+	Backoff float64
+	// BaselineWindow is how often the baseline latency is measured anew, so
+	// it follows lasting changes in the work the gateway does
+	BaselineWindow time.Duration
+}
+
+// AdaptiveLimiter bounds concurrent requests with a limit that follows
+// latency. While latency stays near its baseline the limit grows by about
+// one per round trip; once it degrades, or requests fail with overload
+// errors, the limit is cut by the backoff factor at most once per round
+// trip (AIMD). Requests over the limit are shed instead of queued.
+type AdaptiveLimiter struct {
+	lastDecrease   time.Time
+	windowStart    time.Time
+	emit           MetricEmitter
+	now            func() time.Time
+	name           string
+	cfg            AdaptiveLimiterConfig
+	limit          float64
+	baseline       time.Duration
+	windowBaseline time.Duration
+	smoothed       time.Duration
+	inFlight       int
+	admitted       int64
+	shed           int64
+	mu             sync.Mutex
+}
+
+// NewAdaptiveLimiter creates an adaptive limiter. Unset fields of cfg use
+// the package defaults.
+func NewAdaptiveLimiter(name string, cfg AdaptiveLimiterConfig) *AdaptiveLimiter {
+	if cfg.MinLimit <= 0 {
+		cfg.MinLimit = DefaultMinLimit
+	}
+	if cfg.MaxLimit <= 0 {
+		cfg.MaxLimit = DefaultMaxLimit
+	}
+	if cfg.MaxLimit < cfg.MinLimit {
+		cfg.MaxLimit = cfg.MinLimit
+	}
+	if cfg.InitialLimit <= 0 {
+		cfg.InitialLimit = DefaultInitialLimit
+	}
+	cfg.InitialLimit = min(max(cfg.InitialLimit, cfg.MinLimit), cfg.MaxLimit)
+	if cfg.LatencyFactor <= 1 {
+		cfg.LatencyFactor = DefaultLatencyFactor
+	}
+	if cfg.Backoff <= 0 || cfg.Backoff >= 1 {
+		cfg.Backoff = DefaultBackoff
+	}
+	if cfg.BaselineWindow <= 0 {
+		cfg.BaselineWindow = DefaultBaselineWindow
+	}
+
+	return &AdaptiveLimiter{
+		name:  name,
+		cfg:   cfg,
+		limit: float64(cfg.InitialLimit),
+		now:   time.Now,
+	}
+}
+
+// SetMetricEmitter configures where limit and shedding metrics are sent
+func (l *AdaptiveLimiter) SetMetricEmitter(emit MetricEmitter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.emit = emit
+}
+
+// SetClock replaces the limiter's time source, for tests
+func (l *AdaptiveLimiter) SetClock(now func() time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = now
+}
+
+// Acquire admits a request under the current limit. The returned release
+// function must be called once with the request's latency and whether it
+// failed because something downstream was overloaded.
+func (l *AdaptiveLimiter) Acquire() (func(latency time.Duration, overloaded bool), error) {
+	l.mu.Lock()
+	limit := int(l.limit)
+	if l.inFlight >= limit {
+		l.shed++
+		inFlight := l.inFlight
+		emit := l.emit
+		l.mu.Unlock()
+
+		if emit != nil {
+			emit(&types.Metric{
+				Timestamp: time.Now(),
+				Name:      l.name + "_rejected_total",
+				Type:      types.MetricTypeCounter,
+				Value:     1,
+			})
+		}
+		return nil, types.NewErrorWithDetails(types.ErrCodeServiceUnavailable,
+			"Gateway is overloaded, please retry",
+			fmt.Sprintf("%d of %d requests in flight", inFlight, limit), http.StatusServiceUnavailable)
+	}
+
+	l.inFlight++
+	l.admitted++
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func(latency time.Duration, overloaded bool) {
+		once.Do(func() {
+			l.release(latency, overloaded)
+		})
+	}, nil
+}
+
+// release records a finished request and adjusts the limit
+func (l *AdaptiveLimiter) release(latency time.Duration, overloaded bool) {
+	l.mu.Lock()
+	utilized := float64(l.inFlight) >= l.limit/2
+	l.inFlight--
+	now := l.now()
+	before := int(l.limit)
+
+	if latency > 0 {
+		l.observe(now, latency)
+	}
+
+	congested := overloaded ||
+		(l.baseline > 0 && float64(l.smoothed) > float64(l.baseline)*l.cfg.LatencyFactor)
+	switch {
+	case congested:
+		if now.Sub(l.lastDecrease) >= l.smoothed {
+			l.limit = math.Max(float64(l.cfg.MinLimit), l.limit*l.cfg.Backoff)
+			l.lastDecrease = now
+		}
+	case utilized:
+		// Additive increase of about one per limit's worth of requests
+		l.limit = math.Min(float64(l.cfg.MaxLimit), l.limit+1/l.limit)
+	}
+
+	after := int(l.limit)
+	emit := l.emit
+	l.mu.Unlock()
+
+	if emit != nil && after != before {
+		emit(&types.Metric{
+			Timestamp: time.Now(),
+			Name:      l.name + "_limit",
+			Type:      types.MetricTypeGauge,
+			Value:     float64(after),
+		})
+	}
+}
+
+// observe folds a latency sample into the smoothed latency and the
+// baseline, which is the lowest latency seen in the last window
+func (l *AdaptiveLimiter) observe(now time.Time, latency time.Duration) {
+	if l.smoothed == 0 {
+		l.smoothed = latency
+	} else {
+		l.smoothed += time.Duration(latencySmoothing * float64(latency-l.smoothed))
+	}
+
+	if l.windowBaseline == 0 || latency < l.windowBaseline {
+		l.windowBaseline = latency
+	}
+	if l.baseline == 0 || latency < l.baseline {
+		l.baseline = latency
+	}
+	if l.windowStart.IsZero() {
+		l.windowStart = now
+	}
+	if now.Sub(l.windowStart) >= l.cfg.BaselineWindow {
+		l.baseline = l.windowBaseline
+		l.windowBaseline = 0
+		l.windowStart = now
+	}
+}
+
+// Stats returns the current limit and admission statistics
+func (l *AdaptiveLimiter) Stats() *types.LoadSheddingStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return &types.LoadSheddingStats{
+		Limit:             int(l.limit),
+		MinLimit:          l.cfg.MinLimit,
+		MaxLimit:          l.cfg.MaxLimit,
+		InFlight:          l.inFlight,
+		BaselineLatencyMS: float64(l.baseline) / float64(time.Millisecond),
+		LatencyMS:         float64(l.smoothed) / float64(time.Millisecond),
+		Admitted:          l.admitted,
+		Shed:              l.shed,
+	}
+}
@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// ClassResolver maps an organization to its priority class
+type ClassResolver interface {
+	PriorityClass(ctx context.Context, orgID string) string
+}
+
+// DefaultShares is the fraction of capacity each class may fill. Lower
+// classes are shed first, leaving headroom for higher ones.
+var DefaultShares = map[string]float64{
+	types.PriorityClassEnterprise: 1.0,
+	types.PriorityClassPro:        0.9,
+	types.PriorityClassFree:       0.7,
+}
+
+// Backpressure admits long-lived work, such as transport connections,
+// against a shared capacity with per-class ceilings
+type Backpressure struct {
+	emit     MetricEmitter
+	shares   map[string]float64
+	counters map[string]*classCounters
+	name     string
+	capacity int
+	active   int
+	mu       sync.Mutex
+}
+
+// NewBackpressure creates a backpressure gate. A capacity of zero or less
+// admits everything; classes missing from shares use DefaultShares.
+func NewBackpressure(name string, capacity int, shares map[string]float64) *Backpressure {
+	b := &Backpressure{
+		name:     name,
+		capacity: capacity,
+		shares:   make(map[string]float64),
+		counters: make(map[string]*classCounters),
+	}
+	for _, class := range types.PriorityClasses {
+		share, ok := shares[class]
+		if !ok || share <= 0 {
+			share = DefaultShares[class]
+		}
+		b.shares[class] = share
+		b.counters[class] = &classCounters{}
+	}
+	return b
+}
+
+// SetMetricEmitter configures where per-class metrics are sent
+func (b *Backpressure) SetMetricEmitter(emit MetricEmitter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.emit = emit
+}
+
+// Admit reserves capacity for the class. The returned release function
+// must be called once the work ends.
+func (b *Backpressure) Admit(class string) (func(), error) {
+	class = normalizeClass(class)
+
+	b.mu.Lock()
+	if b.capacity > 0 && float64(b.active) >= float64(b.capacity)*b.shares[class] {
+		b.counters[class].rejected++
+		emit := b.emit
+		b.mu.Unlock()
+
+		if emit != nil {
+			emit(&types.Metric{
+				Timestamp: time.Now(),
+				Name:      b.name + "_rejected_total",
+				Type:      types.MetricTypeCounter,
+				Value:     1,
+				Tags:      map[string]string{"class": class},
+			})
+		}
+		return nil, capacityError(class, fmt.Sprintf("%d of %d slots in use", b.active, b.capacity))
+	}
+
+	b.active++
+	b.counters[class].inFlight++
+	b.counters[class].admitted++
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			b.active--
+			b.counters[class].inFlight--
+			b.mu.Unlock()
+		})
+	}, nil
+}
+
+// Stats returns per-class admission statistics, highest priority first
+func (b *Backpressure) Stats() []types.PriorityClassStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := make([]types.PriorityClassStats, 0, len(types.PriorityClasses))
+	for _, class := range types.PriorityClasses {
+		c := b.counters[class]
+		stats = append(stats, types.PriorityClassStats{
+			Class:    class,
+			Admitted: c.admitted,
+			Rejected: c.rejected,
+			InFlight: c.inFlight,
+		})
+	}
+	return stats
+}
@@ -0,0 +1,243 @@
+// Package scheduler admits work by organization priority class so that
+// higher-tier plans are not starved by lower-tier traffic under load.
+package scheduler
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// MetricEmitter forwards scheduler metrics, e.g. to StatsD
+type MetricEmitter func(metric *types.Metric)
+
+// Config controls the tool-execution scheduler
+type Config struct {
+	// MaxConcurrent caps executions in flight; zero disables queueing
+	MaxConcurrent int
+	// MaxQueue caps waiters per priority class
+	MaxQueue int
+	// MaxWait bounds how long a request waits for a slot
+	MaxWait time.Duration
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+type classCounters struct {
+	admitted  int64
+	rejected  int64
+	timedOut  int64
+	waitTotal time.Duration
+	inFlight  int
+}
+
+// Scheduler limits concurrent work and, when full, admits queued requests
+// strictly by priority class and first-come within a class
+type Scheduler struct {
+	emit     MetricEmitter
+	queues   map[string]*list.List
+	counters map[string]*classCounters
+	name     string
+	cfg      Config
+	inFlight int
+	mu       sync.Mutex
+}
+
+// New creates a scheduler. name prefixes emitted metrics.
+func New(name string, cfg Config) *Scheduler {
+	s := &Scheduler{
+		name:     name,
+		cfg:      cfg,
+		queues:   make(map[string]*list.List),
+		counters: make(map[string]*classCounters),
+	}
+	for _, class := range types.PriorityClasses {
+		s.queues[class] = list.New()
+		s.counters[class] = &classCounters{}
+	}
+	return s
+}
+
+// SetMetricEmitter configures where per-class metrics are sent
+func (s *Scheduler) SetMetricEmitter(emit MetricEmitter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emit = emit
+}
+
+// Acquire waits for an execution slot for the given class. The returned
+// release function must be called when the work finishes.
+func (s *Scheduler) Acquire(ctx context.Context, class string) (func(), error) {
+	class = normalizeClass(class)
+	start := time.Now()
+
+	s.mu.Lock()
+	if s.cfg.MaxConcurrent <= 0 || s.inFlight < s.cfg.MaxConcurrent {
+		s.admitLocked(class, 0)
+		s.mu.Unlock()
+		s.emitCounter("admitted_total", class)
+		return s.releaseFunc(class), nil
+	}
+
+	queue := s.queues[class]
+	if s.cfg.MaxQueue > 0 && queue.Len() >= s.cfg.MaxQueue {
+		s.counters[class].rejected++
+		s.mu.Unlock()
+		s.emitCounter("rejected_total", class)
+		return nil, capacityError(class, "queue is full")
+	}
+
+	w := &waiter{ready: make(chan struct{})}
+	elem := queue.PushBack(w)
+	s.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if s.cfg.MaxWait > 0 {
+		timer := time.NewTimer(s.cfg.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-w.ready:
+		s.recordWait(class, time.Since(start))
+		s.emitCounter("admitted_total", class)
+		return s.releaseFunc(class), nil
+	case <-ctx.Done():
+	case <-timeout:
+	}
+
+	s.mu.Lock()
+	s.counters[class].timedOut++
+	granted := w.granted
+	if !granted {
+		queue.Remove(elem)
+	}
+	s.mu.Unlock()
+	if granted {
+		// A slot was handed over while we gave up; pass it on
+		s.releaseFunc(class)()
+	}
+	s.emitCounter("timed_out_total", class)
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return nil, capacityError(class, fmt.Sprintf("no slot within %s", s.cfg.MaxWait))
+}
+
+// Stats returns per-class admission statistics, highest priority first
+func (s *Scheduler) Stats() []types.PriorityClassStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make([]types.PriorityClassStats, 0, len(types.PriorityClasses))
+	for _, class := range types.PriorityClasses {
+		c := s.counters[class]
+		stat := types.PriorityClassStats{
+			Class:    class,
+			Admitted: c.admitted,
+			Rejected: c.rejected,
+			TimedOut: c.timedOut,
+			InFlight: c.inFlight,
+			Queued:   s.queues[class].Len(),
+		}
+		if c.admitted > 0 {
+			stat.AvgWaitMS = float64(c.waitTotal.Microseconds()) / 1000 / float64(c.admitted)
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// admitLocked takes a slot for class; s.mu must be held
+func (s *Scheduler) admitLocked(class string, wait time.Duration) {
+	s.inFlight++
+	c := s.counters[class]
+	c.inFlight++
+	c.admitted++
+	c.waitTotal += wait
+}
+
+func (s *Scheduler) recordWait(class string, wait time.Duration) {
+	s.mu.Lock()
+	s.counters[class].waitTotal += wait
+	emit := s.emit
+	s.mu.Unlock()
+
+	if emit != nil {
+		emit(&types.Metric{
+			Timestamp: time.Now(),
+			Name:      s.name + "_queue_wait",
+			Type:      types.MetricTypeHistogram,
+			Value:     float64(wait.Microseconds()) / 1000,
+			Tags:      map[string]string{"class": class},
+		})
+	}
+}
+
+func (s *Scheduler) releaseFunc(class string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			s.inFlight--
+			s.counters[class].inFlight--
+			s.handOffLocked()
+			s.mu.Unlock()
+		})
+	}
+}
+
+// handOffLocked gives free slots to the highest-priority waiters; s.mu must be held
+func (s *Scheduler) handOffLocked() {
+	for _, class := range types.PriorityClasses {
+		queue := s.queues[class]
+		for queue.Len() > 0 && (s.cfg.MaxConcurrent <= 0 || s.inFlight < s.cfg.MaxConcurrent) {
+			w := queue.Remove(queue.Front()).(*waiter)
+			w.granted = true
+			// Wait time is added by the waiter once it wakes
+			s.admitLocked(class, 0)
+			close(w.ready)
+		}
+	}
+}
+
+func (s *Scheduler) emitCounter(name, class string) {
+	s.mu.Lock()
+	emit := s.emit
+	s.mu.Unlock()
+
+	if emit != nil {
+		emit(&types.Metric{
+			Timestamp: time.Now(),
+			Name:      s.name + "_" + name,
+			Type:      types.MetricTypeCounter,
+			Value:     1,
+			Tags:      map[string]string{"class": class},
+		})
+	}
+}
+
+func normalizeClass(class string) string {
+	for _, known := range types.PriorityClasses {
+		if class == known {
+			return class
+		}
+	}
+	return types.PriorityClassFree
+}
+
+func capacityError(class, reason string) error {
+	return types.NewErrorWithDetails(types.ErrCodeServiceUnavailable,
+		"Gateway is at capacity, please retry",
+		fmt.Sprintf("%s priority class: %s", class, reason), http.StatusServiceUnavailable)
+}
@@ -0,0 +1,299 @@
+// Package secrets detects and masks values that look like live credentials
+// in server configuration, such as cloud access keys and personal tokens.
+package secrets
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// rule matches a credential format. When group is non-zero only that
+// submatch is masked, so surrounding context such as a URL stays readable.
+type rule struct {
+	pattern     *regexp.Regexp
+	name        string
+	description string
+	group       int
+}
+
+var rules = []rule{
+	{
+		name:        "private_key",
+		description: "PEM private key",
+		pattern:     regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?s:.*?)(?:-----END [A-Z ]*PRIVATE KEY-----|$)`),
+	},
+	{
+		name:        "aws_access_key_id",
+		description: "AWS access key ID",
+		pattern:     regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA|A3T[A-Z0-9])[A-Z0-9]{16}\b`),
+	},
+	{
+		name:        "github_token",
+		description: "GitHub token",
+		pattern:     regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`),
+	},
+	{
+		name:        "gitlab_token",
+		description: "GitLab personal access token",
+		pattern:     regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`),
+	},
+	{
+		name:        "slack_token",
+		description: "Slack token",
+		pattern:     regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),
+	},
+	{
+		name:        "stripe_live_key",
+		description: "Stripe live secret key",
+		pattern:     regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}\b`),
+	},
+	{
+		name:        "google_api_key",
+		description: "Google API key",
+		pattern:     regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),
+	},
+	{
+		name:        "llm_api_key",
+		description: "OpenAI or Anthropic API key",
+		pattern:     regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`),
+	},
+	{
+		name:        "url_credentials",
+		description: "password embedded in URL",
+		pattern:     regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@"]+:([^/\s@"]+)@`),
+		group:       1,
+	},
+	{
+		name:        "sensitive_assignment",
+		description: "value assigned to a credential-like name",
+		pattern:     regexp.MustCompile(`(?i)\b[A-Z0-9_]*(?:SECRET|TOKEN|PASSWORD|PASSWD|API_?KEY|ACCESS_?KEY|PRIVATE_?KEY|CREDENTIALS?)[A-Z0-9_]*=([^\s"',&]+)`),
+		group:       1,
+	},
+	{
+		name:        "sensitive_flag",
+		description: "value passed to a credential flag",
+		pattern:     regexp.MustCompile(`(?i)--?(?:token|api[-_]?key|secret|password|access[-_]?key|auth[-_]?token)(?:=|\s+)([^\s"',-][^\s"',]*)`),
+		group:       1,
+	},
+}
+
+// sensitiveName matches environment variable and flag names that hold credentials
+var sensitiveName = regexp.MustCompile(`(?i)(?:SECRET|TOKEN|PASSWORD|PASSWD|API_?KEY|ACCESS_?KEY|PRIVATE_?KEY|CREDENTIALS?)`)
+
+// sensitiveFlag matches a bare credential flag whose value is the next argument
+var sensitiveFlag = regexp.MustCompile(`(?i)^--?(?:token|api[-_]?key|secret|password|access[-_]?key|auth[-_]?token)$`)
+
+// isPlaceholder reports whether a value is a reference or template rather
+// than a literal credential
+func isPlaceholder(value string) bool {
+	return len(value) < 6 ||
+		strings.HasPrefix(value, "$") ||
+		strings.HasPrefix(value, "<") ||
+		strings.HasPrefix(value, "{{") ||
+		value == types.RedactedValue
+}
+
+// ScanString returns the rules matched by a literal value
+func ScanString(field, location, value string) []types.SecretFinding {
+	var findings []types.SecretFinding
+	for _, r := range rules {
+		for _, match := range r.pattern.FindAllStringSubmatch(value, -1) {
+			if r.group > 0 && isPlaceholder(match[r.group]) {
+				continue
+			}
+			findings = append(findings, types.SecretFinding{
+				Field:       field,
+				Location:    location,
+				Rule:        r.name,
+				Description: r.description,
+			})
+			break
+		}
+	}
+	return findings
+}
+
+// ScanServer scans the parts of a server definition that may carry credentials
+func ScanServer(command string, args, env []string, url string) []types.SecretFinding {
+	var findings []types.SecretFinding
+
+	findings = append(findings, ScanString("command", "command", command)...)
+	findings = append(findings, ScanString("url", "url", url)...)
+
+	for i, arg := range args {
+		location := fmt.Sprintf("args[%d]", i)
+		if i > 0 && sensitiveFlag.MatchString(args[i-1]) && !strings.HasPrefix(arg, "-") && !isPlaceholder(arg) {
+			findings = append(findings, types.SecretFinding{
+				Field:       "args",
+				Location:    location,
+				Rule:        "sensitive_flag",
+				Description: "value passed to " + args[i-1],
+			})
+			continue
+		}
+		findings = append(findings, ScanString("args", location, arg)...)
+	}
+
+	for _, entry := range env {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		valueFindings := ScanString("environment", key, value)
+		if len(valueFindings) == 0 && sensitiveName.MatchString(key) && !isPlaceholder(value) {
+			valueFindings = append(valueFindings, types.SecretFinding{
+				Field:       "environment",
+				Location:    key,
+				Rule:        "sensitive_assignment",
+				Description: "value assigned to a credential-like name",
+			})
+		}
+		findings = append(findings, valueFindings...)
+	}
+
+	return findings
+}
+
+// Mask replaces every credential-like substring of value
+func Mask(value string) string {
+	for _, r := range rules {
+		if !r.pattern.MatchString(value) {
+			continue
+		}
+		value = r.pattern.ReplaceAllStringFunc(value, func(match string) string {
+			if r.group == 0 {
+				return types.RedactedValue
+			}
+			sub := r.pattern.FindStringSubmatchIndex(match)
+			start, end := sub[2*r.group], sub[2*r.group+1]
+			if start < 0 || isPlaceholder(match[start:end]) {
+				return match
+			}
+			return match[:start] + types.RedactedValue + match[end:]
+		})
+	}
+	return value
+}
+
+// Spans returns the byte ranges of value that Mask replaces, in the order
+// the rules match them; ranges of different rules may overlap
+func Spans(value string) [][2]int {
+	var spans [][2]int
+	for _, r := range rules {
+		for _, match := range r.pattern.FindAllStringSubmatchIndex(value, -1) {
+			start, end := match[0], match[1]
+			if r.group > 0 {
+				start, end = match[2*r.group], match[2*r.group+1]
+				if start < 0 || isPlaceholder(value[start:end]) {
+					continue
+				}
+			}
+			spans = append(spans, [2]int{start, end})
+		}
+	}
+	return spans
+}
+
+// MaskArgs masks credentials in command arguments, including values that
+// follow a bare credential flag such as "--token <value>"
+func MaskArgs(args []string) []string {
+	if args == nil {
+		return nil
+	}
+	masked := make([]string, len(args))
+	for i, arg := range args {
+		if i > 0 && sensitiveFlag.MatchString(args[i-1]) && !strings.HasPrefix(arg, "-") && !isPlaceholder(arg) {
+			masked[i] = types.RedactedValue
+			continue
+		}
+		masked[i] = Mask(arg)
+	}
+	return masked
+}
+
+// MaskEnvironment masks KEY=VALUE entries whose name or value looks like a credential
+func MaskEnvironment(env []string) []string {
+	if env == nil {
+		return nil
+	}
+	masked := make([]string, len(env))
+	for i, entry := range env {
+		key, value, ok := strings.Cut(entry, "=")
+		switch {
+		case !ok:
+			masked[i] = Mask(entry)
+		case sensitiveName.MatchString(key) && !isPlaceholder(value):
+			masked[i] = key + "=" + types.RedactedValue
+		default:
+			masked[i] = key + "=" + Mask(value)
+		}
+	}
+	return masked
+}
+
+// MaskServer returns a copy of server that is safe to return from the API
+func MaskServer(server *types.MCPServer) *types.MCPServer {
+	if server == nil {
+		return nil
+	}
+	masked := *server
+	masked.Command = Mask(server.Command)
+	masked.URL = Mask(server.URL)
+	masked.HealthCheckURL = Mask(server.HealthCheckURL)
+	masked.Args = MaskArgs(server.Args)
+	masked.Environment = MaskEnvironment(server.Environment)
+	return &masked
+}
+
+// RestoreString returns existing when submitted is its masked form, so that
+// a masked value read from the API and sent back does not overwrite the secret
+func RestoreString(submitted, existing string) string {
+	if strings.Contains(submitted, types.RedactedValue) && Mask(existing) == submitted {
+		return existing
+	}
+	return submitted
+}
+
+// RestoreArgs restores masked arguments from the existing arguments
+func RestoreArgs(submitted, existing []string) []string {
+	if submitted == nil {
+		return nil
+	}
+	maskedExisting := MaskArgs(existing)
+	restored := make([]string, len(submitted))
+	for i, arg := range submitted {
+		restored[i] = arg
+		if i < len(existing) && strings.Contains(arg, types.RedactedValue) && maskedExisting[i] == arg {
+			restored[i] = existing[i]
+		}
+	}
+	return restored
+}
+
+// RestoreEnvironment restores masked KEY=VALUE entries from the existing entry with the same key
+func RestoreEnvironment(submitted, existing []string) []string {
+	if submitted == nil {
+		return nil
+	}
+	existingByKey := make(map[string]string, len(existing))
+	for _, entry := range existing {
+		if key, _, ok := strings.Cut(entry, "="); ok {
+			existingByKey[key] = entry
+		}
+	}
+	restored := make([]string, len(submitted))
+	for i, entry := range submitted {
+		restored[i] = entry
+		key, _, ok := strings.Cut(entry, "=")
+		if !ok || !strings.Contains(entry, types.RedactedValue) {
+			continue
+		}
+		if previous, found := existingByKey[key]; found && MaskEnvironment([]string{previous})[0] == entry {
+			restored[i] = previous
+		}
+	}
+	return restored
+}
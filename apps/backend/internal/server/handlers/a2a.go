@@ -221,6 +221,13 @@ func (h *A2AHandler) RegisterAgent(c *gin.Context) {
 		UpdatedAt:       agent.UpdatedAt,
 	}
 
+	// A missing or broken card is reported on the agent, not as a failure
+	card, err := h.service.RefreshCard(c.Request.Context(), agent, h.client)
+	if err != nil {
+		log.Printf("Failed to refresh agent card of %s: %v", agent.ID, err)
+	}
+	responseSpec.Card = card
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"data":    responseSpec,
@@ -269,6 +276,10 @@ func (h *A2AHandler) GetAgent(c *gin.Context) {
 		UpdatedAt:       agent.UpdatedAt,
 	}
 
+	if spec.Card, err = h.service.GetCard(agent.ID); err != nil {
+		log.Printf("Failed to get agent card of %s: %v", agent.ID, err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    spec,
@@ -296,6 +307,17 @@ func (h *A2AHandler) UpdateAgent(c *gin.Context) {
 		return
 	}
 
+	previous, err := h.service.Get(agentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Agent not found",
+		})
+		return
+	}
+	previousCardURL, _ := a2a.AgentCardURL(previous)
+	previousAuthType := previous.AuthType
+
 	// Update agent
 	updatedAgent, err := h.service.Update(agentID, &spec)
 	if err != nil {
@@ -327,6 +349,17 @@ func (h *A2AHandler) UpdateAgent(c *gin.Context) {
 		UpdatedAt:       updatedAgent.UpdatedAt,
 	}
 
+	// Fetch the card again only when where or how it is fetched changed
+	cardURL, _ := a2a.AgentCardURL(updatedAgent)
+	if cardURL != previousCardURL || updatedAgent.AuthType != previousAuthType {
+		responseSpec.Card, err = h.service.RefreshCard(c.Request.Context(), updatedAgent, h.client)
+	} else {
+		responseSpec.Card, err = h.service.GetCard(updatedAgent.ID)
+	}
+	if err != nil {
+		log.Printf("Failed to refresh agent card of %s: %v", updatedAgent.ID, err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    responseSpec,
@@ -454,6 +487,7 @@ func (h *A2AHandler) InvokeAgent(c *gin.Context) {
 	duration := int(time.Since(start).Milliseconds())
 
 	if err != nil {
+		h.service.ReportFailure(agent, "invoke", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Invocation failed",
@@ -537,6 +571,7 @@ func (h *A2AHandler) ChatWithAgent(c *gin.Context) {
 	duration := int(time.Since(start).Milliseconds())
 
 	if err != nil {
+		h.service.ReportFailure(agent, "chat", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Chat request failed",
@@ -556,6 +591,51 @@ func (h *A2AHandler) ChatWithAgent(c *gin.Context) {
 	})
 }
 
+// RefreshAgentCard handles POST /a2a/{id}/card/refresh - Fetch the agent card again
+func (h *A2AHandler) RefreshAgentCard(c *gin.Context) {
+	idParam := c.Param("id")
+	agentID, err := uuid.Parse(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid agent ID format",
+		})
+		return
+	}
+
+	agent, err := h.service.Get(agentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Agent not found",
+		})
+		return
+	}
+
+	if !a2a.HasAgentCard(agent) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Agent type does not publish an agent card",
+		})
+		return
+	}
+
+	card, err := h.service.RefreshCard(c.Request.Context(), agent, h.client)
+	if err != nil {
+		log.Printf("Failed to refresh agent card of %s: %v", agent.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to refresh agent card",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    card,
+	})
+}
+
 // HealthCheckAgent handles GET /a2a/{id}/health - Check agent health
 func (h *A2AHandler) HealthCheckAgent(c *gin.Context) {
 	idParam := c.Param("id")
@@ -1,12 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
-	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
@@ -14,16 +15,42 @@ import (
 	"github.com/google/uuid"
 )
 
+// AdminStatsSource summarizes an organization's activity from precomputed
+// aggregates
+type AdminStatsSource interface {
+	GetStats(ctx context.Context, orgID string, days int) (*types.AdminStats, error)
+}
+
+// ConfigTransferService exports an organization's configuration and imports
+// it into another
+type ConfigTransferService interface {
+	ExportConfiguration(ctx context.Context, orgID, userID uuid.UUID, req *types.ExportRequest) (*types.ConfigurationExport, error)
+	ImportConfiguration(ctx context.Context, orgID, userID uuid.UUID, req *types.ImportRequest) (*types.ImportResult, error)
+	ValidateImport(ctx context.Context, orgID uuid.UUID, req *types.ValidateImportRequest) (*types.ValidationResult, error)
+	GetImportHistory(ctx context.Context, orgID uuid.UUID, query *types.ImportHistoryQuery) ([]types.ImportHistory, int, error)
+}
+
+// AuthConfigManager reads and changes an organization's authentication
+// methods, session settings and security policy
+type AuthConfigManager interface {
+	GetConfiguration(orgID uuid.UUID) (*types.AuthConfigurationResponse, error)
+	UpdateConfiguration(orgID uuid.UUID, req *types.CompleteAuthConfigurationRequest, updatedBy uuid.UUID) (*types.AuthConfigurationResponse, error)
+	ValidateConfiguration(req *types.CompleteAuthConfigurationRequest) error
+	GetDefaults() *types.AuthConfigDefaults
+}
+
 // AdminHandler handles administrative endpoints
 type AdminHandler struct {
 	authService       *auth.Service
 	loggingService    *logging.Service
-	configService     *config.Service
-	authConfigService *auth.ConfigService
+	configService     ConfigTransferService
+	authConfigService AuthConfigManager
+	stats             AdminStatsSource
+	metrics           http.Handler
 }
 
 // NewAdminHandler creates a new admin handler
-func NewAdminHandler(authService *auth.Service, loggingService *logging.Service, configService *config.Service, authConfigService *auth.ConfigService) *AdminHandler {
+func NewAdminHandler(authService *auth.Service, loggingService *logging.Service, configService ConfigTransferService, authConfigService AuthConfigManager) *AdminHandler {
 	return &AdminHandler{
 		authService:       authService,
 		loggingService:    loggingService,
@@ -32,142 +59,14 @@ func NewAdminHandler(authService *auth.Service, loggingService *logging.Service,
 	}
 }
 
-// ListUsers lists all users in the organization
-func (h *AdminHandler) ListUsers(c *gin.Context) {
-	// TODO: Implement user listing with pagination
-	// orgID, _ := c.Get("organization_id")
-
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    []interface{}{},
-		"pagination": gin.H{
-			"page":  1,
-			"limit": 10,
-			"total": 0,
-		},
-	})
-}
-
-// CreateUser creates a new user
-func (h *AdminHandler) CreateUser(c *gin.Context) {
-	var req types.CreateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error:   types.NewValidationError(err.Error()),
-			Success: false,
-		})
-		return
-	}
-
-	// Set organization ID from context
-	if orgID, exists := c.Get("organization_id"); exists {
-		req.OrganizationID = orgID.(string)
-	}
-
-	// TODO: Implement user creation logic
-	user, err := h.authService.CreateUser(&req)
-	if err != nil {
-		c.JSON(types.GetStatusCode(err), types.ErrorResponse{
-			Error:   err.(*types.Error),
-			Success: false,
-		})
-		return
-	}
-
-	c.JSON(http.StatusCreated, gin.H{
-		"success": true,
-		"data":    user,
-	})
-}
-
-// GetUser retrieves a specific user
-func (h *AdminHandler) GetUser(c *gin.Context) {
-	userID := c.Param("id")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error:   types.NewValidationError("User ID is required"),
-			Success: false,
-		})
-		return
-	}
-
-	// TODO: Implement user retrieval logic
-	user, err := h.authService.GetUserByID(userID)
-	if err != nil {
-		c.JSON(types.GetStatusCode(err), types.ErrorResponse{
-			Error:   err.(*types.Error),
-			Success: false,
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    user,
-	})
-}
-
-// UpdateUser updates a user
-func (h *AdminHandler) UpdateUser(c *gin.Context) {
-	userID := c.Param("id")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error:   types.NewValidationError("User ID is required"),
-			Success: false,
-		})
-		return
-	}
-
-	var req types.UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error:   types.NewValidationError(err.Error()),
-			Success: false,
-		})
-		return
-	}
-
-	// TODO: Implement user update logic
-	user, err := h.authService.UpdateUser(userID, &req)
-	if err != nil {
-		c.JSON(types.GetStatusCode(err), types.ErrorResponse{
-			Error:   err.(*types.Error),
-			Success: false,
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    user,
-	})
+// SetStatsSource serves GetStats from precomputed aggregates
+func (h *AdminHandler) SetStatsSource(stats AdminStatsSource) {
+	h.stats = stats
 }
 
-// DeleteUser deletes a user
-func (h *AdminHandler) DeleteUser(c *gin.Context) {
-	userID := c.Param("id")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error:   types.NewValidationError("User ID is required"),
-			Success: false,
-		})
-		return
-	}
-
-	// TODO: Implement user deletion logic
-	err := h.authService.DeleteUser(userID)
-	if err != nil {
-		c.JSON(types.GetStatusCode(err), types.ErrorResponse{
-			Error:   err.(*types.Error),
-			Success: false,
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "User deleted successfully",
-	})
+// SetMetricsHandler serves GetMetrics from the Prometheus exporter
+func (h *AdminHandler) SetMetricsHandler(metrics http.Handler) {
+	h.metrics = metrics
 }
 
 // GetLogs retrieves system logs
@@ -223,6 +122,10 @@ func (h *AdminHandler) GetLogs(c *gin.Context) {
 		Message:   query.Search,
 		Limit:     query.Limit,
 		Offset:    query.Offset,
+
+		PrincipalType: c.Query("principal_type"),
+		PrincipalID:   c.Query("principal_id"),
+		Labels:        labelFilters(c),
 	}
 
 	// Add method and path to filters if provided
@@ -252,56 +155,10 @@ func (h *AdminHandler) GetLogs(c *gin.Context) {
 	})
 }
 
-// GetAuditLogs retrieves audit logs
-func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
-	// Parse query parameters
-	limit := 100
-	offset := 0
-
-	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil {
-			limit = parsed
-		}
-	}
-
-	if o := c.Query("offset"); o != "" {
-		if parsed, err := strconv.Atoi(o); err == nil {
-			offset = parsed
-		}
-	}
-
-	resourceType := c.Query("resource_type")
-	action := c.Query("action")
-	actorID := c.Query("actor_id")
-
-	// TODO: Set organization ID from context
-	// orgID, _ := c.Get("organization_id")
-
-	// TODO: Implement audit log retrieval logic
-	// For now, return empty result with proper structure
-	auditLogs := []gin.H{}
-
-	// Filter logic would be implemented here
-	_ = resourceType
-	_ = action
-	_ = actorID
-
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    auditLogs,
-		"pagination": gin.H{
-			"limit":  limit,
-			"offset": offset,
-			"total":  0,
-		},
-	})
-}
-
 // GetStats returns system statistics
 func (h *AdminHandler) GetStats(c *gin.Context) {
 	orgID, _ := c.Get("organization_id")
 
-	// TODO: Aggregate statistics from various services
 	stats := gin.H{
 		"users": gin.H{
 			"total":  0,
@@ -322,10 +179,91 @@ func (h *AdminHandler) GetStats(c *gin.Context) {
 		},
 	}
 
-	if orgID != nil {
-		// Get organization-specific stats
-		// TODO: Implement organization-specific statistics gathering
-		// logStats, _ := h.loggingService.GetLogStats(orgID.(string), time.Now().Add(-24*time.Hour), time.Now())
+	if orgID != nil && h.stats != nil {
+		days := 0
+		if value := c.Query("days"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				RespondWithValidationError(c, "days must be a number")
+				return
+			}
+			days = parsed
+		}
+
+		summary, err := h.stats.GetStats(c.Request.Context(), orgID.(string), days)
+		if err != nil {
+			RespondWithError(c, err)
+			return
+		}
+		stats["users"] = summary.Users
+		stats["servers"] = summary.Servers
+		stats["requests"] = summary.Requests
+		stats["top_tools"] = summary.TopTools
+		stats["server_error_rates"] = summary.ServerErrorRates
+		stats["since"] = summary.Since
+		stats["days"] = summary.Days
+		stats["freshness"] = summary.Freshness
+		stats["stale"] = summary.Stale
+	}
+
+	if c.Query("group_by") == "principal" && h.loggingService != nil {
+		usage, err := h.loggingService.GetUsageByPrincipal(c.Request.Context(), usageQuery(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   types.NewInternalError("Failed to aggregate usage: " + err.Error()),
+				Success: false,
+			})
+			return
+		}
+		stats["by_principal"] = usage
+	}
+
+	if c.Query("group_by") == "client" && h.loggingService != nil {
+		usage, err := h.loggingService.GetUsageByClient(c.Request.Context(), usageQuery(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   types.NewInternalError("Failed to aggregate usage: " + err.Error()),
+				Success: false,
+			})
+			return
+		}
+		stats["by_client"] = usage
+	}
+
+	if c.Query("group_by") == "server" && h.loggingService != nil {
+		usage, err := h.loggingService.GetUsageByServer(c.Request.Context(), usageQuery(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   types.NewInternalError("Failed to aggregate usage: " + err.Error()),
+				Success: false,
+			})
+			return
+		}
+		stats["by_server"] = usage
+	}
+
+	if c.Query("group_by") == "tool" && h.loggingService != nil {
+		usage, err := h.loggingService.GetUsageByTool(c.Request.Context(), usageQuery(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   types.NewInternalError("Failed to aggregate usage: " + err.Error()),
+				Success: false,
+			})
+			return
+		}
+		stats["by_tool"] = usage
+	}
+
+	if label, ok := strings.CutPrefix(c.Query("group_by"), "label:"); ok && label != "" && h.loggingService != nil {
+		usage, err := h.loggingService.GetUsageByLabel(c.Request.Context(), label, usageQuery(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+				Error:   types.NewInternalError("Failed to aggregate usage: " + err.Error()),
+				Success: false,
+			})
+			return
+		}
+		stats["by_label"] = usage
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -334,25 +272,161 @@ func (h *AdminHandler) GetStats(c *gin.Context) {
 	})
 }
 
-// GetMetrics returns Prometheus-style metrics
+// GetPrincipalUsage returns request and tool execution counts grouped by principal
+func (h *AdminHandler) GetPrincipalUsage(c *gin.Context) {
+	usage, err := h.loggingService.GetUsageByPrincipal(c.Request.Context(), usageQuery(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   types.NewInternalError("Failed to aggregate usage: " + err.Error()),
+			Success: false,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    usage,
+	})
+}
+
+// GetLabelUsage returns request and tool execution counts grouped by the
+// value of an attribution label, for internal chargeback
+func (h *AdminHandler) GetLabelUsage(c *gin.Context) {
+	label := c.Query("key")
+	if label == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   types.NewValidationError("key query parameter is required"),
+			Success: false,
+		})
+		return
+	}
+
+	usage, err := h.loggingService.GetUsageByLabel(c.Request.Context(), label, usageQuery(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   types.NewInternalError("Failed to aggregate usage: " + err.Error()),
+			Success: false,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    usage,
+	})
+}
+
+// GetClientUsage returns request counts grouped by detected MCP client
+// (Claude Desktop, Cursor, SDKs, ...) with a per-version breakdown
+func (h *AdminHandler) GetClientUsage(c *gin.Context) {
+	usage, err := h.loggingService.GetUsageByClient(c.Request.Context(), usageQuery(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   types.NewInternalError("Failed to aggregate usage: " + err.Error()),
+			Success: false,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    usage,
+	})
+}
+
+// GetServerUsage returns request counts and error rates grouped by MCP
+// server, highest error rate first
+func (h *AdminHandler) GetServerUsage(c *gin.Context) {
+	usage, err := h.loggingService.GetUsageByServer(c.Request.Context(), usageQuery(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   types.NewInternalError("Failed to aggregate usage: " + err.Error()),
+			Success: false,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    usage,
+	})
+}
+
+// GetToolUsage returns execution counts grouped by tool, most executed first
+func (h *AdminHandler) GetToolUsage(c *gin.Context) {
+	usage, err := h.loggingService.GetUsageByTool(c.Request.Context(), usageQuery(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   types.NewInternalError("Failed to aggregate usage: " + err.Error()),
+			Success: false,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    usage,
+	})
+}
+
+// usageQuery builds a log query for usage aggregation, defaulting to the last 24 hours
+func usageQuery(c *gin.Context) *logging.QueryRequest {
+	endTime := time.Now()
+	startTime := endTime.Add(-24 * time.Hour)
+
+	if v := c.Query("start_time"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			startTime = t
+		}
+	}
+	if v := c.Query("end_time"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			endTime = t
+		}
+	}
+
+	query := &logging.QueryRequest{
+		StartTime:     &startTime,
+		EndTime:       &endTime,
+		PrincipalType: c.Query("principal_type"),
+		PrincipalID:   c.Query("principal_id"),
+		Labels:        labelFilters(c),
+	}
+	if orgID, exists := c.Get("organization_id"); exists {
+		if o, ok := orgID.(string); ok {
+			query.OrgID = o
+		}
+	}
+
+	return query
+}
+
+// labelFilters parses repeated label=key:value query parameters
+func labelFilters(c *gin.Context) types.Labels {
+	var labels types.Labels
+	for _, raw := range c.QueryArray("label") {
+		key, value, ok := strings.Cut(raw, ":")
+		if !ok || key == "" {
+			continue
+		}
+		if labels == nil {
+			labels = make(types.Labels)
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// GetMetrics serves the Prometheus exporter's metrics to admins
 func (h *AdminHandler) GetMetrics(c *gin.Context) {
-	// TODO: Implement Prometheus metrics export
-	// This should return metrics in Prometheus text format
-	c.Header("Content-Type", "text/plain; version=0.0.4")
-	c.String(http.StatusOK, `# HELP mcp_gateway_requests_total Total number of requests
-# TYPE mcp_gateway_requests_total counter
-mcp_gateway_requests_total{method="GET",status="200"} 100
-mcp_gateway_requests_total{method="POST",status="201"} 50
-
-# HELP mcp_gateway_request_duration_seconds Request duration in seconds
-# TYPE mcp_gateway_request_duration_seconds histogram
-mcp_gateway_request_duration_seconds_bucket{le="0.1"} 80
-mcp_gateway_request_duration_seconds_bucket{le="0.5"} 120
-mcp_gateway_request_duration_seconds_bucket{le="1.0"} 140
-mcp_gateway_request_duration_seconds_bucket{le="+Inf"} 150
-mcp_gateway_request_duration_seconds_sum 45.2
-mcp_gateway_request_duration_seconds_count 150
-`)
+	if h.metrics == nil {
+		c.JSON(http.StatusNotFound, types.ErrorResponse{
+			Error:   types.NewNotFoundError("Prometheus metrics are not enabled"),
+			Success: false,
+		})
+		return
+	}
+	h.metrics.ServeHTTP(c.Writer, c.Request)
 }
 
 // ExportConfiguration exports configuration entities based on the request
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApprovalManager lists and decides tool calls held for approval
+type ApprovalManager interface {
+	List(ctx context.Context, orgID, status string, limit, offset int) ([]*types.ToolApproval, error)
+	Get(ctx context.Context, orgID, id string) (*types.ToolApproval, error)
+	Decide(ctx context.Context, orgID, id string, approve bool, decidedBy, reason string) (*types.ToolApproval, error)
+}
+
+// ApprovalHandler handles tool call approvals
+type ApprovalHandler struct {
+	approvals ApprovalManager
+}
+
+// NewApprovalHandler creates a new approval handler
+func NewApprovalHandler(approvals ApprovalManager) *ApprovalHandler {
+	return &ApprovalHandler{approvals: approvals}
+}
+
+// ListApprovals handles GET /api/admin/approvals. Supports ?status=, limit
+// and offset.
+func (h *ApprovalHandler) ListApprovals(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	approvals, err := h.approvals.List(c.Request.Context(), c.GetString("organization_id"), c.Query("status"), limit, offset)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, approvals)
+}
+
+// GetApproval handles GET /api/admin/approvals/:id
+func (h *ApprovalHandler) GetApproval(c *gin.Context) {
+	approval, err := h.approvals.Get(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, approval)
+}
+
+// ApproveApproval handles POST /api/admin/approvals/:id/approve
+func (h *ApprovalHandler) ApproveApproval(c *gin.Context) {
+	h.decide(c, true)
+}
+
+// RejectApproval handles POST /api/admin/approvals/:id/reject
+func (h *ApprovalHandler) RejectApproval(c *gin.Context) {
+	h.decide(c, false)
+}
+
+func (h *ApprovalHandler) decide(c *gin.Context, approve bool) {
+	var req types.ApprovalDecisionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondWithValidationError(c, "Invalid request: "+err.Error())
+			return
+		}
+	}
+
+	approval, err := h.approvals.Decide(c.Request.Context(), c.GetString("organization_id"), c.Param("id"),
+		approve, c.GetString("user_id"), req.Reason)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, approval)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ArchiveManager manages organizations' archive policies, archived logs
+// and their rehydrations
+type ArchiveManager interface {
+	GetPolicy(ctx context.Context, orgID string) (*types.ArchivePolicy, error)
+	UpdatePolicy(ctx context.Context, orgID, userID string, req *types.UpdateArchivePolicyRequest) (*types.ArchivePolicy, error)
+	ListArchives(ctx context.Context, orgID string, query *types.ArchiveQuery) ([]*types.LogArchive, error)
+	CreateRehydration(ctx context.Context, orgID, userID string, req *types.CreateRehydrationRequest) (*types.ArchiveRehydration, error)
+	ListRehydrations(ctx context.Context, orgID string) ([]*types.ArchiveRehydration, error)
+	GetRehydration(ctx context.Context, orgID, id string) (*types.ArchiveRehydration, error)
+	ReleaseRehydration(ctx context.Context, orgID, id string) (*types.ArchiveRehydration, error)
+}
+
+// ArchiveHandler handles archival of execution logs and session events to
+// cold storage
+type ArchiveHandler struct {
+	archives ArchiveManager
+}
+
+// NewArchiveHandler creates a new archive handler
+func NewArchiveHandler(archives ArchiveManager) *ArchiveHandler {
+	return &ArchiveHandler{archives: archives}
+}
+
+// GetPolicy handles GET /api/admin/archive-policy
+func (h *ArchiveHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.archives.GetPolicy(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// UpdatePolicy handles PUT /api/admin/archive-policy
+func (h *ArchiveHandler) UpdatePolicy(c *gin.Context) {
+	var req types.UpdateArchivePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	policy, err := h.archives.UpdatePolicy(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// ListArchives handles GET /api/admin/archives?dataset=&start_time=&end_time=
+func (h *ArchiveHandler) ListArchives(c *gin.Context) {
+	var query types.ArchiveQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	archives, err := h.archives.ListArchives(c.Request.Context(), c.GetString("organization_id"), &query)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, archives)
+}
+
+// CreateRehydration handles POST /api/admin/archives/rehydrations, queueing
+// a rehydration for the worker
+func (h *ArchiveHandler) CreateRehydration(c *gin.Context) {
+	var req types.CreateRehydrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	rehydration, err := h.archives.CreateRehydration(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, rehydration)
+}
+
+// ListRehydrations handles GET /api/admin/archives/rehydrations
+func (h *ArchiveHandler) ListRehydrations(c *gin.Context) {
+	rehydrations, err := h.archives.ListRehydrations(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, rehydrations)
+}
+
+// GetRehydration handles GET /api/admin/archives/rehydrations/:id
+func (h *ArchiveHandler) GetRehydration(c *gin.Context) {
+	rehydration, err := h.archives.GetRehydration(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, rehydration)
+}
+
+// ReleaseRehydration handles DELETE /api/admin/archives/rehydrations/:id,
+// removing the rehydrated logs before the rehydration expires
+func (h *ArchiveHandler) ReleaseRehydration(c *gin.Context) {
+	rehydration, err := h.archives.ReleaseRehydration(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, rehydration)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditChainService defines the audit storage operations used by the handler
+type AuditChainService interface {
+	QueryAuditLogs(query *types.AuditLogQuery) ([]*types.AuditLog, error)
+	VerifyChain(orgID string) (*types.AuditChainReport, error)
+	ListAnchors(orgID string, limit int) ([]*types.AuditAnchor, error)
+	CreateAnchor(orgID string) (*types.AuditAnchor, error)
+}
+
+// AuditHandler serves the audit trail and its integrity checks
+type AuditHandler struct {
+	service AuditChainService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(service AuditChainService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+// ListAuditLogs handles GET /api/admin/audit
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	var query types.AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		RespondWithValidationError(c, "Invalid query parameters: "+err.Error())
+		return
+	}
+	// Older clients filter with resource_type and actor_id
+	if query.Resource == "" {
+		query.Resource = c.Query("resource_type")
+	}
+	if query.UserID == "" {
+		query.UserID = c.Query("actor_id")
+	}
+	if query.MinSeverity != "" && !types.IsValidAuditSeverity(query.MinSeverity) {
+		RespondWithValidationError(c, "min_severity must be one of info, notice, warning, critical")
+		return
+	}
+	if query.Limit <= 0 || query.Limit > 1000 {
+		query.Limit = 100
+	}
+	query.OrganizationID = c.GetString("organization_id")
+
+	audits, err := h.service.QueryAuditLogs(&query)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	if audits == nil {
+		audits = []*types.AuditLog{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    audits,
+		"pagination": gin.H{
+			"limit":  query.Limit,
+			"offset": query.Offset,
+			"total":  len(audits),
+		},
+	})
+}
+
+// VerifyChain handles GET /api/admin/audit/verify
+func (h *AuditHandler) VerifyChain(c *gin.Context) {
+	report, err := h.service.VerifyChain(c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, report)
+}
+
+// ListAnchors handles GET /api/admin/audit/anchors
+func (h *AuditHandler) ListAnchors(c *gin.Context) {
+	anchors, err := h.service.ListAnchors(c.GetString("organization_id"), 100)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	if anchors == nil {
+		anchors = []*types.AuditAnchor{}
+	}
+
+	RespondWithSuccess(c, gin.H{
+		"anchors": anchors,
+		"count":   len(anchors),
+	})
+}
+
+// CreateAnchor handles POST /api/admin/audit/anchors
+func (h *AuditHandler) CreateAnchor(c *gin.Context) {
+	anchor, err := h.service.CreateAnchor(c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	// A nil anchor means nothing has been audited since the last one
+	RespondWithSuccess(c, gin.H{
+		"anchor":  anchor,
+		"created": anchor != nil,
+	})
+}
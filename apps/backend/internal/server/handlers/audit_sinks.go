@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditSinkManager manages the SIEM sinks audit records are streamed to
+type AuditSinkManager interface {
+	ListSinks(ctx context.Context, orgID string) ([]*types.AuditSink, error)
+	GetSink(ctx context.Context, orgID, id string) (*types.AuditSink, error)
+	CreateSink(ctx context.Context, orgID, userID string, req *types.CreateAuditSinkRequest) (*types.AuditSink, error)
+	UpdateSink(ctx context.Context, orgID, id string, req *types.UpdateAuditSinkRequest) (*types.AuditSink, error)
+	DeleteSink(ctx context.Context, orgID, id string) error
+	TestSink(ctx context.Context, orgID, id, userID string) (*types.AuditSinkTestResult, error)
+}
+
+// AuditSinkHandler handles the organization's audit export sinks
+type AuditSinkHandler struct {
+	sinks AuditSinkManager
+}
+
+// NewAuditSinkHandler creates a new audit sink handler
+func NewAuditSinkHandler(sinks AuditSinkManager) *AuditSinkHandler {
+	return &AuditSinkHandler{sinks: sinks}
+}
+
+// ListSinks handles GET /api/admin/audit/sinks
+func (h *AuditSinkHandler) ListSinks(c *gin.Context) {
+	sinks, err := h.sinks.ListSinks(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	if sinks == nil {
+		sinks = []*types.AuditSink{}
+	}
+	RespondWithSuccess(c, sinks)
+}
+
+// GetSink handles GET /api/admin/audit/sinks/:id
+func (h *AuditSinkHandler) GetSink(c *gin.Context) {
+	sink, err := h.sinks.GetSink(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, sink)
+}
+
+// CreateSink handles POST /api/admin/audit/sinks
+func (h *AuditSinkHandler) CreateSink(c *gin.Context) {
+	var req types.CreateAuditSinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	sink, err := h.sinks.CreateSink(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, sink)
+}
+
+// UpdateSink handles PUT /api/admin/audit/sinks/:id
+func (h *AuditSinkHandler) UpdateSink(c *gin.Context) {
+	var req types.UpdateAuditSinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	sink, err := h.sinks.UpdateSink(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, sink)
+}
+
+// DeleteSink handles DELETE /api/admin/audit/sinks/:id
+func (h *AuditSinkHandler) DeleteSink(c *gin.Context) {
+	if err := h.sinks.DeleteSink(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Audit sink deleted"})
+}
+
+// TestSink handles POST /api/admin/audit/sinks/:id/test
+func (h *AuditSinkHandler) TestSink(c *gin.Context) {
+	result, err := h.sinks.TestSink(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, result)
+}
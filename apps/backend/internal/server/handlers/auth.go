@@ -3,19 +3,32 @@ package handlers
 import (
 	"net/http"
 
-	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/gin-gonic/gin"
 )
 
+// AuthService signs users in and manages their profile and API keys
+type AuthService interface {
+	Login(email, password string) (*types.LoginResponse, error)
+	RefreshToken(refreshToken string) (*types.LoginResponse, error)
+	Logout(accessToken string) error
+	GetUserByID(userID string) (*types.User, error)
+	UpdateUser(userID string, req *types.UpdateUserRequest) (*types.User, error)
+	CreateAPIKey(userID string, req *types.CreateAPIKeyRequest) (*types.CreateAPIKeyResponse, error)
+	ListAllAPIKeys(organizationID string) ([]*types.APIKey, error)
+	DeleteAPIKeyByAdmin(organizationID, keyID string) error
+	UpdateAPIKeyRestrictions(userID, keyID string, req *types.UpdateAPIKeyRestrictionsRequest) (*types.APIKey, error)
+	UpdateAPIKeyScope(userID, keyID string, req *types.UpdateAPIKeyScopeRequest) (*types.APIKey, error)
+}
+
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
-	authService *auth.Service
+	authService AuthService
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(authService *auth.Service) *AuthHandler {
+func NewAuthHandler(authService AuthService) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
 	}
@@ -290,3 +303,35 @@ func (h *AuthHandler) DeleteAPIKey(c *gin.Context) {
 		"message": "API key deleted successfully",
 	})
 }
+
+// UpdateAPIKeyRestrictions handles PUT /api/auth/api-keys/:id/restrictions
+func (h *AuthHandler) UpdateAPIKeyRestrictions(c *gin.Context) {
+	var req types.UpdateAPIKeyRestrictionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	key, err := h.authService.UpdateAPIKeyRestrictions(c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, key)
+}
+
+// UpdateAPIKeyScope handles PUT /api/auth/api-keys/:id/scope
+func (h *AuthHandler) UpdateAPIKeyScope(c *gin.Context) {
+	var req types.UpdateAPIKeyScopeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	key, err := h.authService.UpdateAPIKeyScope(c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, key)
+}
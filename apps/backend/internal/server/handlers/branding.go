@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BrandingManager manages the branding of organizations' endpoint docs
+type BrandingManager interface {
+	GetBranding(ctx context.Context, orgID string) (*types.OrganizationBranding, error)
+	SetBranding(ctx context.Context, orgID, updatedBy string, req *types.SetOrganizationBrandingRequest) (*types.OrganizationBranding, error)
+	ResetBranding(ctx context.Context, orgID string) error
+}
+
+// BrandingHandler handles organization branding
+type BrandingHandler struct {
+	branding BrandingManager
+}
+
+// NewBrandingHandler creates a new branding handler
+func NewBrandingHandler(branding BrandingManager) *BrandingHandler {
+	return &BrandingHandler{branding: branding}
+}
+
+// GetBranding handles GET /api/admin/branding
+func (h *BrandingHandler) GetBranding(c *gin.Context) {
+	branding, err := h.branding.GetBranding(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, branding)
+}
+
+// SetBranding handles PUT /api/admin/branding
+func (h *BrandingHandler) SetBranding(c *gin.Context) {
+	var req types.SetOrganizationBrandingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	branding, err := h.branding.SetBranding(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, branding)
+}
+
+// ResetBranding handles DELETE /api/admin/branding
+func (h *BrandingHandler) ResetBranding(c *gin.Context) {
+	if err := h.branding.ResetBranding(c.Request.Context(), c.GetString("organization_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Branding reset"})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BreakGlassManager manages emergency credentials and sessions
+type BreakGlassManager interface {
+	ListCredentials(ctx context.Context, orgID string) ([]*types.BreakGlassCredential, error)
+	CreateCredential(ctx context.Context, orgID, createdBy string, req *types.CreateBreakGlassCredentialRequest) (*types.BreakGlassCredentialSecret, error)
+	RotateCredential(ctx context.Context, orgID, id string) (*types.BreakGlassCredentialSecret, error)
+	DeleteCredential(ctx context.Context, orgID, id string) error
+	Activate(ctx context.Context, req *types.ActivateBreakGlassRequest, remoteIP string) (*types.BreakGlassActivation, error)
+	ListSessions(ctx context.Context, orgID string) ([]*types.BreakGlassSession, error)
+	EndSession(ctx context.Context, orgID, sessionID, endedBy string) error
+}
+
+// BreakGlassHandler handles break-glass emergency access
+type BreakGlassHandler struct {
+	breakGlass BreakGlassManager
+}
+
+// NewBreakGlassHandler creates a new break-glass handler
+func NewBreakGlassHandler(breakGlass BreakGlassManager) *BreakGlassHandler {
+	return &BreakGlassHandler{breakGlass: breakGlass}
+}
+
+// Activate handles POST /api/auth/break-glass
+func (h *BreakGlassHandler) Activate(c *gin.Context) {
+	var req types.ActivateBreakGlassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	activation, err := h.breakGlass.Activate(c.Request.Context(), &req, c.ClientIP())
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, activation)
+}
+
+// ListCredentials handles GET /api/admin/break-glass/credentials
+func (h *BreakGlassHandler) ListCredentials(c *gin.Context) {
+	credentials, err := h.breakGlass.ListCredentials(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, credentials)
+}
+
+// CreateCredential handles POST /api/admin/break-glass/credentials
+func (h *BreakGlassHandler) CreateCredential(c *gin.Context) {
+	var req types.CreateBreakGlassCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	secret, err := h.breakGlass.CreateCredential(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, secret)
+}
+
+// RotateCredential handles POST /api/admin/break-glass/credentials/:id/rotate
+func (h *BreakGlassHandler) RotateCredential(c *gin.Context) {
+	secret, err := h.breakGlass.RotateCredential(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, secret)
+}
+
+// DeleteCredential handles DELETE /api/admin/break-glass/credentials/:id
+func (h *BreakGlassHandler) DeleteCredential(c *gin.Context) {
+	if err := h.breakGlass.DeleteCredential(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Break-glass credential deleted"})
+}
+
+// ListSessions handles GET /api/admin/break-glass/sessions
+func (h *BreakGlassHandler) ListSessions(c *gin.Context) {
+	sessions, err := h.breakGlass.ListSessions(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, sessions)
+}
+
+// EndSession handles DELETE /api/admin/break-glass/sessions/:id
+func (h *BreakGlassHandler) EndSession(c *gin.Context) {
+	if err := h.breakGlass.EndSession(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.GetString("user_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Break-glass session ended"})
+}
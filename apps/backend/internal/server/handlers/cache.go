@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/cache"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListCacheManager reports on and flushes cached tool, resource and prompt
+// listings
+type ListCacheManager interface {
+	Stats() cache.Stats
+	Flush(ctx context.Context, orgID, kind string) (int, error)
+}
+
+// CacheHandler handles the list cache admin API
+type CacheHandler struct {
+	cache ListCacheManager
+}
+
+// NewCacheHandler creates a new cache handler. A nil manager means the
+// list cache is off.
+func NewCacheHandler(listCache ListCacheManager) *CacheHandler {
+	return &CacheHandler{cache: listCache}
+}
+
+// GetStats handles GET /api/admin/cache
+func (h *CacheHandler) GetStats(c *gin.Context) {
+	if h.cache == nil {
+		RespondWithSuccess(c, gin.H{"enabled": false})
+		return
+	}
+	RespondWithSuccess(c, gin.H{"enabled": true, "stats": h.cache.Stats()})
+}
+
+// Flush handles DELETE /api/admin/cache, dropping the organization's cached
+// listings of the kind given in the query, or of every kind
+func (h *CacheHandler) Flush(c *gin.Context) {
+	kind := c.Query("kind")
+	if kind != "" && !slices.Contains(cache.Kinds, kind) {
+		RespondWithValidationError(c, "kind must be tools, resources or prompts")
+		return
+	}
+	if h.cache == nil {
+		RespondWithSuccess(c, gin.H{"removed": 0})
+		return
+	}
+
+	removed, err := h.cache.Flush(c.Request.Context(), c.GetString("organization_id"), kind)
+	if err != nil {
+		RespondWithError(c, types.NewInternalError("Failed to flush cache: "+err.Error()))
+		return
+	}
+	RespondWithSuccess(c, gin.H{"removed": removed})
+}
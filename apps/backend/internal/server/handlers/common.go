@@ -99,3 +99,12 @@ func RespondWithCreated(c *gin.Context, data interface{}) {
 		"data":    data,
 	})
 }
+
+// RespondWithAccepted returns an accepted response for work that finishes
+// later
+func RespondWithAccepted(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
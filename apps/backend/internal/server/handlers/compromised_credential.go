@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// CompromisedCredentialManager revokes credentials reported as leaked
+type CompromisedCredentialManager interface {
+	List(ctx context.Context, orgID string) ([]*types.CompromisedCredential, error)
+	Report(ctx context.Context, orgID, reportedBy string, req *types.ReportCompromisedCredentialRequest) (*types.CompromiseReportResult, error)
+	VerifyFeedRequest(ctx context.Context, body []byte, signature, timestamp, nonce string) error
+	ReportFromFeed(ctx context.Context, report *types.ThreatFeedReport) (*types.CompromiseReportResult, error)
+}
+
+// CompromisedCredentialHandler handles reports of leaked credentials
+type CompromisedCredentialHandler struct {
+	compromised CompromisedCredentialManager
+}
+
+// NewCompromisedCredentialHandler creates a new compromised credential
+// handler
+func NewCompromisedCredentialHandler(compromised CompromisedCredentialManager) *CompromisedCredentialHandler {
+	return &CompromisedCredentialHandler{compromised: compromised}
+}
+
+// List handles GET /api/admin/compromised-credentials
+func (h *CompromisedCredentialHandler) List(c *gin.Context) {
+	credentials, err := h.compromised.List(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, credentials)
+}
+
+// Report handles POST /api/admin/compromised-credentials
+func (h *CompromisedCredentialHandler) Report(c *gin.Context) {
+	var req types.ReportCompromisedCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	result, err := h.compromised.Report(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, result)
+}
+
+// ThreatFeed handles POST /api/auth/threat-feed. Reports must be signed
+// with the configured feed secret in the X-Omnimesh-Signature header, and
+// carry X-Omnimesh-Timestamp and X-Omnimesh-Nonce to prevent replays.
+func (h *CompromisedCredentialHandler) ThreatFeed(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		RespondWithValidationError(c, "Failed to read request body")
+		return
+	}
+	if err := h.compromised.VerifyFeedRequest(c.Request.Context(), body, c.GetHeader(types.ThreatFeedSignatureHeader),
+		c.GetHeader(types.RequestTimestampHeader), c.GetHeader(types.RequestNonceHeader)); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	var report types.ThreatFeedReport
+	if err := binding.JSON.BindBody(body, &report); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	result, err := h.compromised.ReportFromFeed(c.Request.Context(), &report)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, result)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DelegationManager manages the delegation policies of service accounts
+type DelegationManager interface {
+	GetPolicy(ctx context.Context, orgID, serviceAccountID string) (*types.DelegationPolicy, error)
+	SetPolicy(ctx context.Context, orgID, serviceAccountID, setBy string, req *types.SetDelegationPolicyRequest) (*types.DelegationPolicy, error)
+	DeletePolicy(ctx context.Context, orgID, serviceAccountID string) error
+}
+
+// DelegationHandler handles the delegation policies of service accounts
+type DelegationHandler struct {
+	delegation DelegationManager
+}
+
+// NewDelegationHandler creates a new delegation handler
+func NewDelegationHandler(delegation DelegationManager) *DelegationHandler {
+	return &DelegationHandler{delegation: delegation}
+}
+
+// GetPolicy handles GET /api/admin/service-accounts/:id/delegation
+func (h *DelegationHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.delegation.GetPolicy(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// SetPolicy handles PUT /api/admin/service-accounts/:id/delegation
+func (h *DelegationHandler) SetPolicy(c *gin.Context) {
+	var req types.SetDelegationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	policy, err := h.delegation.SetPolicy(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// DeletePolicy handles DELETE /api/admin/service-accounts/:id/delegation
+func (h *DelegationHandler) DeletePolicy(c *gin.Context) {
+	if err := h.delegation.DeletePolicy(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Delegation policy deleted"})
+}
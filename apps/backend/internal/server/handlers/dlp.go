@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DLPManager manages the DLP policies of servers and lists the calls they
+// caught
+type DLPManager interface {
+	GetPolicy(ctx context.Context, orgID, serverID string) (*types.DLPPolicy, error)
+	SetPolicy(ctx context.Context, orgID, serverID string, req *types.SetDLPPolicyRequest) (*types.DLPPolicy, error)
+	DeletePolicy(ctx context.Context, orgID, serverID string) error
+	ListEvents(ctx context.Context, orgID, serverID string, limit int) ([]*types.DLPEvent, error)
+}
+
+// DLPHandler handles outbound data loss prevention
+type DLPHandler struct {
+	dlp DLPManager
+}
+
+// NewDLPHandler creates a new DLP handler
+func NewDLPHandler(dlp DLPManager) *DLPHandler {
+	return &DLPHandler{dlp: dlp}
+}
+
+// GetPolicy handles GET /api/gateway/servers/:id/dlp-policy
+func (h *DLPHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.dlp.GetPolicy(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// SetPolicy handles PUT /api/gateway/servers/:id/dlp-policy
+func (h *DLPHandler) SetPolicy(c *gin.Context) {
+	var req types.SetDLPPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	policy, err := h.dlp.SetPolicy(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// DeletePolicy handles DELETE /api/gateway/servers/:id/dlp-policy
+func (h *DLPHandler) DeletePolicy(c *gin.Context) {
+	if err := h.dlp.DeletePolicy(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "DLP policy removed"})
+}
+
+// ListEvents handles GET /api/admin/dlp/events
+func (h *DLPHandler) ListEvents(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			RespondWithValidationError(c, "limit must be a number")
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := h.dlp.ListEvents(c.Request.Context(), c.GetString("organization_id"), c.Query("server_id"), limit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, events)
+}
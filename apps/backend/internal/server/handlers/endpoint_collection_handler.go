@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// HandleEndpointCollection handles GET
+// /api/public/endpoints/:endpoint_name/collection, serving the endpoint's
+// tools as a Postman collection or Insomnia workspace, chosen by the format
+// query parameter
+func HandleEndpointCollection(endpointService EndpointService, namespaceService NamespaceService, branding EndpointBrandingProvider, examples ToolExampleSource, baseURL string) gin.HandlerFunc {
+	generator := services.NewOpenAPIGenerator(baseURL)
+	exporter := services.NewCollectionExporter()
+
+	return func(c *gin.Context) {
+		format := strings.ToLower(c.DefaultQuery("format", services.CollectionFormatPostman))
+
+		spec, config, _, ok := endpointSpec(c, generator, endpointService, namespaceService, branding, examples)
+		if !ok {
+			return
+		}
+
+		collection, err := exporter.Export(spec, config.Endpoint, format)
+		if err != nil {
+			if types.IsError(err, types.ErrCodeValidationFailed) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported collection format", "details": err.Error()})
+				return
+			}
+			log.Printf("Failed to export %s collection for endpoint %s: %v", format, config.Endpoint.Name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export collection"})
+			return
+		}
+
+		fileName := fmt.Sprintf("%s.%s_collection.json", sanitizeDownloadName(config.Endpoint.Name), format)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+		c.Data(http.StatusOK, "application/json; charset=utf-8", collection)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EndpointGeoPolicyManager manages the geo policies of endpoints and lists
+// their decisions
+type EndpointGeoPolicyManager interface {
+	GetPolicy(ctx context.Context, orgID, endpointID string) (*types.EndpointGeoPolicy, error)
+	SetPolicy(ctx context.Context, orgID, endpointID string, req *types.SetEndpointGeoPolicyRequest) (*types.EndpointGeoPolicy, error)
+	DeletePolicy(ctx context.Context, orgID, endpointID string) error
+	TestPolicy(ctx context.Context, orgID, endpointID string, req *types.TestGeoPolicyRequest) (*types.GeoDecision, error)
+	ListDecisions(ctx context.Context, orgID, endpointID string, deniedOnly bool, limit int) ([]*types.GeoDecisionEvent, error)
+}
+
+// EndpointGeoPolicyHandler handles geo access policies of endpoints
+type EndpointGeoPolicyHandler struct {
+	policies EndpointGeoPolicyManager
+}
+
+// NewEndpointGeoPolicyHandler creates a new endpoint geo policy handler
+func NewEndpointGeoPolicyHandler(policies EndpointGeoPolicyManager) *EndpointGeoPolicyHandler {
+	return &EndpointGeoPolicyHandler{policies: policies}
+}
+
+// GetPolicy handles GET /api/endpoints/:id/geo-policy
+func (h *EndpointGeoPolicyHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.policies.GetPolicy(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// SetPolicy handles PUT /api/endpoints/:id/geo-policy
+func (h *EndpointGeoPolicyHandler) SetPolicy(c *gin.Context) {
+	var req types.SetEndpointGeoPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	policy, err := h.policies.SetPolicy(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// DeletePolicy handles DELETE /api/endpoints/:id/geo-policy
+func (h *EndpointGeoPolicyHandler) DeletePolicy(c *gin.Context) {
+	if err := h.policies.DeletePolicy(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Geo policy removed"})
+}
+
+// TestPolicy handles POST /api/endpoints/:id/geo-policy/test
+func (h *EndpointGeoPolicyHandler) TestPolicy(c *gin.Context) {
+	var req types.TestGeoPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	decision, err := h.policies.TestPolicy(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, decision)
+}
+
+// ListDecisions handles GET /api/endpoints/:id/geo-decisions
+func (h *EndpointGeoPolicyHandler) ListDecisions(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			RespondWithValidationError(c, "limit must be a number")
+			return
+		}
+		limit = parsed
+	}
+	deniedOnly := c.Query("denied") == "true"
+
+	decisions, err := h.policies.ListDecisions(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), deniedOnly, limit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, decisions)
+}
@@ -24,6 +24,7 @@ type EndpointService interface {
 // EndpointHandler handles endpoint-related HTTP requests
 type EndpointHandler struct {
 	service EndpointService
+	access  NamespaceAccessFilter
 }
 
 // NewEndpointHandler creates a new endpoint handler
@@ -33,6 +34,12 @@ func NewEndpointHandler(service EndpointService) *EndpointHandler {
 	}
 }
 
+// SetNamespaceAccess hides endpoints of namespaces the caller was not
+// granted access to and requires write access to create one
+func (h *EndpointHandler) SetNamespaceAccess(access NamespaceAccessFilter) {
+	h.access = access
+}
+
 // CreateEndpoint handles POST /api/endpoints
 func (h *EndpointHandler) CreateEndpoint(c *gin.Context) {
 	var req types.CreateEndpointRequest
@@ -56,6 +63,18 @@ func (h *EndpointHandler) CreateEndpoint(c *gin.Context) {
 		userID = &userIDStr
 	}
 
+	if h.access != nil {
+		allowed, err := h.access.Allowed(c.Request.Context(), namespaceSubject(c), req.NamespaceID, types.NamespaceAccessWrite)
+		if err != nil {
+			RespondWithError(c, err)
+			return
+		}
+		if !allowed {
+			RespondWithError(c, types.NewForbiddenError("write access to this namespace has not been granted"))
+			return
+		}
+	}
+
 	endpoint, err := h.service.CreateEndpoint(c.Request.Context(), req, orgID.(string), userID)
 	if err != nil {
 		RespondWithError(c, err)
@@ -80,6 +99,22 @@ func (h *EndpointHandler) ListEndpoints(c *gin.Context) {
 		return
 	}
 
+	if h.access != nil {
+		subject := namespaceSubject(c)
+		visible := make([]*types.Endpoint, 0, len(endpoints))
+		for _, endpoint := range endpoints {
+			allowed, err := h.access.Allowed(c.Request.Context(), subject, endpoint.NamespaceID, types.NamespaceAccessRead)
+			if err != nil {
+				RespondWithError(c, err)
+				return
+			}
+			if allowed {
+				visible = append(visible, endpoint)
+			}
+		}
+		endpoints = visible
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"endpoints": endpoints,
 		"total":     len(endpoints),
@@ -3,6 +3,8 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"html"
+	"log"
 	"net/http"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
@@ -16,40 +18,38 @@ type OpenAPIService interface {
 	GenerateSpec(endpoint *types.Endpoint, namespace *types.Namespace, tools []types.NamespaceTool) *services.OpenAPISpec
 }
 
-// HandleEndpointOpenAPI handles OpenAPI spec generation for endpoints
-func HandleEndpointOpenAPI(endpointService EndpointService, namespaceService NamespaceService, baseURL string) gin.HandlerFunc {
+// EndpointBrandingProvider returns the branding of an organization's
+// endpoint docs
+type EndpointBrandingProvider interface {
+	GetBranding(ctx context.Context, orgID string) (*types.OrganizationBranding, error)
+}
+
+// ToolExampleSource returns recorded arguments of an endpoint's tool calls,
+// keyed by the called tool name
+type ToolExampleSource interface {
+	RecordedToolArguments(ctx context.Context, orgID, endpointID string) (map[string]map[string]interface{}, error)
+}
+
+// HandleEndpointOpenAPI handles OpenAPI spec generation for endpoints. The
+// spec and docs page carry the branding of the endpoint's organization when
+// branding is set, and request examples come from recorded calls when an
+// example source is given.
+func HandleEndpointOpenAPI(endpointService EndpointService, namespaceService NamespaceService, branding EndpointBrandingProvider, examples ToolExampleSource, baseURL string) gin.HandlerFunc {
 	generator := services.NewOpenAPIGenerator(baseURL)
 
 	return func(c *gin.Context) {
 		endpointName := c.Param("endpoint_name")
-		if endpointName == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Endpoint name required"})
-			return
-		}
-
-		// Get endpoint config
-		config, err := endpointService.ResolveEndpoint(c.Request.Context(), endpointName)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Endpoint not found"})
+		spec, config, orgBranding, ok := endpointSpec(c, generator, endpointService, namespaceService, branding, examples)
+		if !ok {
 			return
 		}
 
-		// Get tools for the namespace
-		tools, err := namespaceService.AggregateTools(c.Request.Context(), config.Namespace.ID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tools"})
-			return
-		}
-
-		// Generate OpenAPI spec
-		spec := generator.GenerateSpec(config.Endpoint, config.Namespace, tools)
-
 		// Return based on path
 		if strings.HasSuffix(c.Request.URL.Path, "/openapi.json") {
 			c.JSON(http.StatusOK, spec)
 		} else if strings.HasSuffix(c.Request.URL.Path, "/docs") {
 			// Serve Swagger UI HTML
-			swaggerHTML := generateSwaggerHTML(endpointName, config.Endpoint.Name)
+			swaggerHTML := generateSwaggerHTML(endpointName, config.Endpoint.Name, orgBranding)
 			c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerHTML))
 		} else {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
@@ -57,6 +57,68 @@ func HandleEndpointOpenAPI(endpointService EndpointService, namespaceService Nam
 	}
 }
 
+// endpointSpec generates the branded OpenAPI spec of the endpoint named in
+// the request, responding with an error when it cannot
+func endpointSpec(c *gin.Context, generator *services.OpenAPIGenerator, endpointService EndpointService, namespaceService NamespaceService,
+	branding EndpointBrandingProvider, examples ToolExampleSource) (*services.OpenAPISpec, *types.EndpointConfig, *types.OrganizationBranding, bool) {
+	endpointName := c.Param("endpoint_name")
+	if endpointName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Endpoint name required"})
+		return nil, nil, nil, false
+	}
+
+	// Get endpoint config
+	config, err := endpointService.ResolveEndpoint(c.Request.Context(), endpointName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Endpoint not found"})
+		return nil, nil, nil, false
+	}
+
+	// Get tools for the namespace
+	tools, err := namespaceService.AggregateTools(c.Request.Context(), config.Namespace.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tools"})
+		return nil, nil, nil, false
+	}
+
+	// Docs fall back to the gateway's branding rather than fail
+	var orgBranding *types.OrganizationBranding
+	if branding != nil {
+		orgBranding, err = branding.GetBranding(c.Request.Context(), config.Endpoint.OrganizationID)
+		if err != nil {
+			log.Printf("Failed to get branding for endpoint %s: %v", endpointName, err)
+			orgBranding = nil
+		}
+	}
+
+	// Generate OpenAPI spec
+	spec := generator.GenerateSpec(config.Endpoint, config.Namespace, tools)
+	generator.ApplyBranding(spec, config.Endpoint, orgBranding)
+	if examples != nil {
+		recorded, err := examples.RecordedToolArguments(c.Request.Context(), config.Endpoint.OrganizationID, config.Endpoint.ID)
+		if err != nil {
+			log.Printf("Failed to get recorded examples for endpoint %s: %v", endpointName, err)
+		} else {
+			generator.ApplyRecordedExamples(spec, recordedExamplesByTool(tools, recorded))
+		}
+	}
+	return spec, config, orgBranding, true
+}
+
+// recordedExamplesByTool keys recorded arguments by the tool names used in
+// the spec; calls are logged under the namespaced tool name
+func recordedExamplesByTool(tools []types.NamespaceTool, recorded map[string]map[string]interface{}) map[string]map[string]interface{} {
+	examples := make(map[string]map[string]interface{})
+	for _, tool := range tools {
+		if arguments, ok := recorded[tool.PrefixedName]; ok && tool.PrefixedName != "" {
+			examples[tool.ToolName] = arguments
+		} else if arguments, ok := recorded[tool.ToolName]; ok {
+			examples[tool.ToolName] = arguments
+		}
+	}
+	return examples
+}
+
 // HandleEndpointToolsList handles GET /metamcp/:endpoint_name/api/tools
 func HandleEndpointToolsList(namespaceService NamespaceService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -92,7 +154,11 @@ func HandleEndpointToolsList(namespaceService NamespaceService) gin.HandlerFunc
 	}
 }
 
-func generateSwaggerHTML(endpointName, title string) string {
+func generateSwaggerHTML(endpointName, title string, branding *types.OrganizationBranding) string {
+	if branding != nil && branding.ProductName != "" {
+		title = fmt.Sprintf("%s - %s", title, branding.ProductName)
+	}
+	style, header, footer := brandingHTML(branding)
 	return fmt.Sprintf(`
 <!DOCTYPE html>
 <html lang="en">
@@ -108,11 +174,11 @@ func generateSwaggerHTML(endpointName, title string) string {
         #swagger-ui {
             max-width: 1200px;
             margin: 0 auto;
-        }
+        }%s
     </style>
 </head>
-<body>
-    <div id="swagger-ui"></div>
+<body>%s
+    <div id="swagger-ui"></div>%s
     <script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5.9.0/swagger-ui-bundle.js"></script>
     <script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5.9.0/swagger-ui-standalone-preset.js"></script>
     <script>
@@ -132,7 +198,66 @@ func generateSwaggerHTML(endpointName, title string) string {
     </script>
 </body>
 </html>
-`, title, endpointName)
+`, html.EscapeString(title), style, header, footer, endpointName)
+}
+
+// brandingHTML returns the extra CSS, header and contact footer of a
+// branded docs page. Every value is escaped, as admins of any organization
+// set them and the page is public.
+func brandingHTML(branding *types.OrganizationBranding) (style, header, footer string) {
+	if branding == nil {
+		return "", "", ""
+	}
+	if branding.PrimaryColor != "" {
+		style += fmt.Sprintf(`
+        .branding-header, .swagger-ui .topbar {
+            background-color: %s;
+        }`, html.EscapeString(branding.PrimaryColor))
+	}
+	if branding.AccentColor != "" {
+		style += fmt.Sprintf(`
+        .swagger-ui .btn.execute, .swagger-ui .btn.authorize {
+            background-color: %[1]s;
+            border-color: %[1]s;
+            color: #fff;
+        }`, html.EscapeString(branding.AccentColor))
+	}
+
+	if branding.LogoURL != "" || branding.ProductName != "" {
+		name := html.EscapeString(branding.DisplayName())
+		header = `
+    <header class="branding-header" style="display: flex; align-items: center; gap: 12px; padding: 12px 24px;">`
+		if branding.LogoURL != "" {
+			header += fmt.Sprintf(`
+        <img src="%s" alt="%s" style="max-height: 40px;">`, html.EscapeString(branding.LogoURL), name)
+		}
+		if branding.ProductName != "" {
+			header += fmt.Sprintf(`
+        <strong style="font-family: sans-serif; font-size: 20px;">%s</strong>`, name)
+		}
+		header += `
+    </header>`
+	}
+
+	if branding.HasContact() {
+		var parts []string
+		if branding.ContactName != "" {
+			parts = append(parts, html.EscapeString(branding.ContactName))
+		}
+		if branding.ContactEmail != "" {
+			email := html.EscapeString(branding.ContactEmail)
+			parts = append(parts, fmt.Sprintf(`<a href="mailto:%s">%s</a>`, email, email))
+		}
+		if branding.ContactURL != "" {
+			url := html.EscapeString(branding.ContactURL)
+			parts = append(parts, fmt.Sprintf(`<a href="%s" rel="noopener">%s</a>`, url, url))
+		}
+		footer = fmt.Sprintf(`
+    <footer class="branding-contact" style="max-width: 1200px; margin: 24px auto; font-family: sans-serif;">
+        Contact: %s
+    </footer>`, strings.Join(parts, " &middot; "))
+	}
+	return style, header, footer
 }
 
 // EndpointOpenAPIService wrapper to implement the interface
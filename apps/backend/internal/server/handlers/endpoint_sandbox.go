@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SandboxToolExecutor runs namespace tools for the endpoint test console
+type SandboxToolExecutor interface {
+	AggregateTools(ctx context.Context, namespaceID string) ([]types.NamespaceTool, error)
+	ExecuteTool(ctx context.Context, namespaceID string, req types.ExecuteNamespaceToolRequest) (*types.NamespaceToolResult, error)
+}
+
+// SandboxEndpointLookup resolves the endpoint under test
+type SandboxEndpointLookup interface {
+	GetEndpoint(ctx context.Context, id string) (*types.Endpoint, error)
+}
+
+// PlaygroundHistoryRecorder keeps users' console and inspector tool calls
+// and returns them for re-running
+type PlaygroundHistoryRecorder interface {
+	Record(ctx context.Context, execution *types.PlaygroundExecution) error
+	OwnExecution(ctx context.Context, orgID, userID, id string) (*types.PlaygroundExecution, error)
+}
+
+// SandboxHandler powers the "try it" console for endpoints. Calls run with
+// the console user's session instead of the endpoint's API key or OAuth
+// configuration, under stricter limits, and are never billed or counted in
+// production usage.
+type SandboxHandler struct {
+	endpoints SandboxEndpointLookup
+	tools     SandboxToolExecutor
+	history   PlaygroundHistoryRecorder
+	limits    types.SandboxLimits
+}
+
+// NewSandboxHandler creates a new endpoint sandbox handler
+func NewSandboxHandler(endpoints SandboxEndpointLookup, tools SandboxToolExecutor, limits types.SandboxLimits) *SandboxHandler {
+	return &SandboxHandler{
+		endpoints: endpoints,
+		tools:     tools,
+		limits:    limits,
+	}
+}
+
+// SetHistory records console calls in the caller's playground history
+func (h *SandboxHandler) SetHistory(history PlaygroundHistoryRecorder) {
+	h.history = history
+}
+
+// ListTools handles GET /api/endpoints/:id/sandbox/tools
+func (h *SandboxHandler) ListTools(c *gin.Context) {
+	endpoint, ok := h.lookupEndpoint(c)
+	if !ok {
+		return
+	}
+
+	tools, err := h.tools.AggregateTools(c.Request.Context(), endpoint.NamespaceID)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, gin.H{
+		"endpoint_id": endpoint.ID,
+		"tools":       tools,
+		"limits":      h.limits,
+	})
+}
+
+// ExecuteTool handles POST /api/endpoints/:id/sandbox/tools/:tool_name
+func (h *SandboxHandler) ExecuteTool(c *gin.Context) {
+	// Flag the request first so its request log is excluded from usage too
+	c.Set(types.SandboxContextKey, true)
+
+	endpoint, ok := h.lookupEndpoint(c)
+	if !ok {
+		return
+	}
+
+	var req types.SandboxExecuteRequest
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(h.limits.MaxArgumentBytes)+1))
+	if err != nil {
+		RespondWithValidationError(c, "Failed to read request body")
+		return
+	}
+	if len(body) > h.limits.MaxArgumentBytes {
+		RespondWithError(c, types.NewErrorWithDetails(types.ErrCodeValidationFailed,
+			"Sandbox arguments are too large",
+			fmt.Sprintf("limit is %d bytes", h.limits.MaxArgumentBytes), http.StatusRequestEntityTooLarge))
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			RespondWithValidationError(c, "Invalid request format")
+			return
+		}
+	}
+
+	h.execute(c, endpoint, c.Param("tool_name"), req.Arguments)
+}
+
+// RerunExecution handles
+// POST /api/endpoints/:id/sandbox/history/:execution_id/rerun, calling a
+// tool again with the arguments of a console call from the caller's history
+func (h *SandboxHandler) RerunExecution(c *gin.Context) {
+	c.Set(types.SandboxContextKey, true)
+
+	endpoint, ok := h.lookupEndpoint(c)
+	if !ok {
+		return
+	}
+	if h.history == nil {
+		RespondWithNotFound(c, "Playground execution")
+		return
+	}
+
+	execution, err := h.history.OwnExecution(c.Request.Context(), endpoint.OrganizationID,
+		c.GetString("user_id"), c.Param("execution_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	if execution.Source != types.PlaygroundSourceSandbox || execution.EndpointID != endpoint.ID {
+		RespondWithNotFound(c, "Playground execution")
+		return
+	}
+
+	h.execute(c, endpoint, execution.ToolName, execution.Arguments)
+}
+
+// execute runs a console tool call under the sandbox limits and records it
+// in the caller's playground history
+func (h *SandboxHandler) execute(c *gin.Context, endpoint *types.Endpoint, tool string, arguments map[string]interface{}) {
+	ctx := types.WithSandbox(c.Request.Context())
+	if principal := logging.PrincipalFromGinContext(c); principal != nil {
+		ctx = types.WithPrincipal(ctx, principal)
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(h.limits.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	startedAt := time.Now()
+	result, err := h.tools.ExecuteTool(ctx, endpoint.NamespaceID, types.ExecuteNamespaceToolRequest{
+		Tool:      tool,
+		Arguments: arguments,
+	})
+	duration := float64(time.Since(startedAt).Microseconds()) / 1000
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = types.NewTimeoutError(fmt.Sprintf("Sandbox execution exceeded %ds", h.limits.TimeoutSeconds))
+	}
+
+	execution := h.record(c, endpoint, tool, arguments, result, err, duration)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	response := &types.SandboxExecutionResult{
+		ExecutedAt: startedAt.UTC(),
+		Result:     result,
+		EndpointID: endpoint.ID,
+		Tool:       tool,
+		Limits:     h.limits,
+		DurationMS: duration,
+		Sandbox:    true,
+		Billable:   false,
+	}
+	if execution != nil {
+		response.HistoryID = execution.ID
+	}
+	RespondWithSuccess(c, response)
+}
+
+// record adds a console call to the caller's playground history. Failing to
+// record never fails the call itself.
+func (h *SandboxHandler) record(c *gin.Context, endpoint *types.Endpoint, tool string, arguments map[string]interface{}, result *types.NamespaceToolResult, callErr error, duration float64) *types.PlaygroundExecution {
+	if h.history == nil {
+		return nil
+	}
+
+	execution := &types.PlaygroundExecution{
+		OrganizationID: endpoint.OrganizationID,
+		UserID:         c.GetString("user_id"),
+		Source:         types.PlaygroundSourceSandbox,
+		EndpointID:     endpoint.ID,
+		NamespaceID:    endpoint.NamespaceID,
+		ToolName:       tool,
+		Arguments:      arguments,
+		DurationMS:     duration,
+	}
+	switch {
+	case callErr != nil:
+		execution.IsError = true
+		execution.ErrorMessage = callErr.Error()
+	case result != nil:
+		execution.Result = result
+		execution.IsError = !result.Success
+		execution.ErrorMessage = result.Error
+	}
+
+	// Record under the request context's values but not its cancellation
+	if err := h.history.Record(context.WithoutCancel(c.Request.Context()), execution); err != nil {
+		log.Printf("Failed to record sandbox execution of %s on endpoint %s: %v", tool, endpoint.ID, err)
+		return nil
+	}
+	if execution.ID == "" {
+		return nil
+	}
+	return execution
+}
+
+// lookupEndpoint loads the endpoint and hides endpoints of other organizations
+func (h *SandboxHandler) lookupEndpoint(c *gin.Context) (*types.Endpoint, bool) {
+	endpoint, err := h.endpoints.GetEndpoint(c.Request.Context(), c.Param("id"))
+	if err != nil || endpoint == nil {
+		RespondWithNotFound(c, "Endpoint")
+		return nil, false
+	}
+	if orgID := c.GetString("organization_id"); orgID != "" && endpoint.OrganizationID != orgID {
+		RespondWithNotFound(c, "Endpoint")
+		return nil, false
+	}
+	return endpoint, true
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+)
+
+// HandleEndpointSDK handles GET /api/public/endpoints/:endpoint_name/sdk,
+// serving a zipped client library for the endpoint in the language given
+// by the lang query parameter. The library is generated from the same spec
+// as the endpoint's openapi.json.
+func HandleEndpointSDK(endpointService EndpointService, namespaceService NamespaceService, branding EndpointBrandingProvider, examples ToolExampleSource, baseURL string) gin.HandlerFunc {
+	generator := services.NewOpenAPIGenerator(baseURL)
+	sdkGenerator := services.NewSDKGenerator()
+
+	return func(c *gin.Context) {
+		language := strings.ToLower(c.Query("lang"))
+		if language == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "SDK language required",
+				"details": "set lang to one of: " + strings.Join(services.SDKLanguages, ", "),
+			})
+			return
+		}
+
+		spec, config, _, ok := endpointSpec(c, generator, endpointService, namespaceService, branding, examples)
+		if !ok {
+			return
+		}
+
+		files, err := sdkGenerator.Generate(spec, config.Endpoint.Name, language)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported SDK language", "details": err.Error()})
+			return
+		}
+
+		root := fmt.Sprintf("%s-%s-sdk", sanitizeDownloadName(config.Endpoint.Name), language)
+		var archive bytes.Buffer
+		if err := services.WriteSDKArchive(&archive, root, files); err != nil {
+			log.Printf("Failed to generate %s SDK for endpoint %s: %v", language, config.Endpoint.Name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate SDK"})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, root))
+		c.Data(http.StatusOK, "application/zip", archive.Bytes())
+	}
+}
+
+// sanitizeDownloadName keeps letters, digits, dashes and underscores of an
+// endpoint name so it is safe in a download file name
+func sanitizeDownloadName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "endpoint"
+	}
+	return b.String()
+}
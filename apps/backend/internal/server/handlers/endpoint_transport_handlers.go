@@ -2,13 +2,18 @@ package handlers
 
 import (
 	"fmt"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HandleEndpointSSE handles SSE connections for endpoints
@@ -108,8 +113,9 @@ func HandleEndpointSSEMessage(namespaceService NamespaceService) gin.HandlerFunc
 			args, _ := message["arguments"].(map[string]interface{})
 
 			result, err := namespaceService.ExecuteTool(c.Request.Context(), namespace.ID, types.ExecuteNamespaceToolRequest{
-				Tool:      toolName,
-				Arguments: args,
+				Tool:           toolName,
+				Arguments:      args,
+				IdempotencyKey: idempotencyKey(c, message["_meta"]),
 			})
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -172,7 +178,7 @@ func HandleEndpointHTTP(namespaceService NamespaceService) gin.HandlerFunc {
 		case "OPTIONS":
 			// Handle preflight requests
 			c.Header("Access-Control-Allow-Methods", "GET, POST, HEAD, OPTIONS")
-			c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+			c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Idempotency-Key")
 			c.Status(http.StatusNoContent)
 			return
 
@@ -347,18 +353,15 @@ func HandleEndpointHTTP(namespaceService NamespaceService) gin.HandlerFunc {
 			arguments, _ := params["arguments"].(map[string]interface{})
 
 			result, err := namespaceService.ExecuteTool(c.Request.Context(), namespace.ID, types.ExecuteNamespaceToolRequest{
-				Tool:      toolName,
-				Arguments: arguments,
+				Tool:           toolName,
+				Arguments:      arguments,
+				IdempotencyKey: idempotencyKey(c, params["_meta"]),
 			})
 			if err != nil {
 				c.JSON(http.StatusOK, gin.H{
 					"jsonrpc": "2.0",
-					"error": map[string]interface{}{
-						"code":    -32603,
-						"message": "Tool execution failed",
-						"data":    err.Error(),
-					},
-					"id": id,
+					"error":   toolCallRPCError(err),
+					"id":      id,
 				})
 				return
 			}
@@ -382,13 +385,15 @@ func HandleEndpointHTTP(namespaceService NamespaceService) gin.HandlerFunc {
 	}
 }
 
-// HandleEndpointWebSocket handles WebSocket connections for endpoints
-func HandleEndpointWebSocket(namespaceService NamespaceService) gin.HandlerFunc {
+// HandleEndpointWebSocket handles WebSocket connections for endpoints.
+// requireSubprotocol rejects clients that offer no Sec-WebSocket-Protocol.
+func HandleEndpointWebSocket(namespaceService NamespaceService, requireSubprotocol bool) gin.HandlerFunc {
 	upgrader := websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
 			// Check origin based on endpoint CORS settings
 			return true // TODO: Implement proper CORS check
 		},
+		Subprotocols: transport.WebSocketSubprotocols,
 	}
 
 	return func(c *gin.Context) {
@@ -399,6 +404,11 @@ func HandleEndpointWebSocket(namespaceService NamespaceService) gin.HandlerFunc
 		}
 		namespace := namespaceVal.(*types.Namespace)
 
+		if _, err := transport.NegotiateSubprotocol(c.Request, requireSubprotocol); err != nil {
+			rejectWebSocketHandshake(c, err)
+			return
+		}
+
 		// Upgrade to WebSocket
 		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 		if err != nil {
@@ -407,13 +417,21 @@ func HandleEndpointWebSocket(namespaceService NamespaceService) gin.HandlerFunc
 		}
 		defer conn.Close()
 
+		// Progress of a streamed tool call is written while the call runs
+		var writeMu sync.Mutex
+		writeJSON := func(v interface{}) {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			conn.WriteJSON(v)
+		}
+
 		// Send welcome message
 		welcomeMsg := map[string]interface{}{
 			"type":         "welcome",
 			"namespace_id": namespace.ID,
 			"session_id":   uuid.New().String(),
 		}
-		conn.WriteJSON(welcomeMsg)
+		writeJSON(welcomeMsg)
 
 		// Handle messages
 		for {
@@ -428,7 +446,7 @@ func HandleEndpointWebSocket(namespaceService NamespaceService) gin.HandlerFunc
 			switch messageType {
 			case "ping":
 				// Respond with pong
-				conn.WriteJSON(map[string]interface{}{
+				writeJSON(map[string]interface{}{
 					"type":      "pong",
 					"timestamp": time.Now().Unix(),
 				})
@@ -438,25 +456,49 @@ func HandleEndpointWebSocket(namespaceService NamespaceService) gin.HandlerFunc
 				toolName, _ := message["tool"].(string)
 				args, _ := message["arguments"].(map[string]interface{})
 
-				result, err := namespaceService.ExecuteTool(c.Request.Context(), namespace.ID, types.ExecuteNamespaceToolRequest{
-					Tool:      toolName,
-					Arguments: args,
+				// Each message is traced on its own, continuing the trace
+				// context of its _meta when the client sent one
+				ctx, span := observability.StartSpan(
+					observability.ExtractMeta(c.Request.Context(), message["_meta"]),
+					"websocket tool_call",
+					trace.WithSpanKind(trace.SpanKindServer),
+					trace.WithAttributes(attribute.String("mcp.tool.name", toolName)),
+				)
+
+				// Streamed calls forward the server's progress notifications
+				if stream, _ := message["stream"].(bool); stream {
+					ctx = types.WithToolProgress(ctx, func(p types.ToolProgress) {
+						writeJSON(map[string]interface{}{
+							"type":     "tool_progress",
+							"tool":     toolName,
+							"progress": p,
+						})
+					})
+				}
+
+				// The upgrade request's headers are shared by every message,
+				// so only a key in the message itself applies
+				result, err := namespaceService.ExecuteTool(ctx, namespace.ID, types.ExecuteNamespaceToolRequest{
+					Tool:           toolName,
+					Arguments:      args,
+					IdempotencyKey: metaIdempotencyKey(message["_meta"]),
 				})
+				observability.EndSpan(span, err)
 
 				if err != nil {
-					conn.WriteJSON(map[string]interface{}{
+					writeJSON(map[string]interface{}{
 						"type":  "error",
 						"error": err.Error(),
 					})
 				} else {
-					conn.WriteJSON(map[string]interface{}{
+					writeJSON(map[string]interface{}{
 						"type":   "tool_result",
 						"result": result,
 					})
 				}
 
 			default:
-				conn.WriteJSON(map[string]interface{}{
+				writeJSON(map[string]interface{}{
 					"type":  "error",
 					"error": "Unknown message type",
 				})
@@ -490,10 +532,15 @@ func HandleEndpointToolExecution(namespaceService NamespaceService) gin.HandlerF
 
 		// Execute tool through namespace
 		result, err := namespaceService.ExecuteTool(c.Request.Context(), namespace.ID, types.ExecuteNamespaceToolRequest{
-			Tool:      toolName,
-			Arguments: args,
+			Tool:           toolName,
+			Arguments:      args,
+			IdempotencyKey: c.GetHeader(IdempotencyKeyHeader),
 		})
 		if err != nil {
+			if _, ok := err.(*types.Error); ok {
+				RespondWithError(c, err)
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Tool execution failed",
 				"details": err.Error(),
@@ -505,6 +552,48 @@ func HandleEndpointToolExecution(namespaceService NamespaceService) gin.HandlerF
 	}
 }
 
+// toolCallRPCError describes a failed tool call as a JSON-RPC error. Errors
+// the gateway raised, such as a used-up tool budget, keep their message and
+// carry their code and details in data so that clients can tell them apart.
+func toolCallRPCError(err error) map[string]interface{} {
+	if gatewayErr, ok := err.(*types.Error); ok {
+		data := map[string]interface{}{"code": gatewayErr.Code}
+		if gatewayErr.Details != "" {
+			data["details"] = gatewayErr.Details
+		}
+		return map[string]interface{}{
+			"code":    -32603,
+			"message": gatewayErr.Message,
+			"data":    data,
+		}
+	}
+	return map[string]interface{}{
+		"code":    -32603,
+		"message": "Tool execution failed",
+		"data":    err.Error(),
+	}
+}
+
+// IdempotencyKeyHeader carries the idempotency key of a tool call, which
+// makes the call safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyKey returns the Idempotency-Key header of a tool call, or else
+// the idempotencyKey of the call's _meta
+func idempotencyKey(c *gin.Context, meta interface{}) string {
+	if key := c.GetHeader(IdempotencyKeyHeader); key != "" {
+		return key
+	}
+	return metaIdempotencyKey(meta)
+}
+
+// metaIdempotencyKey returns the idempotencyKey of a JSON-RPC _meta object
+func metaIdempotencyKey(meta interface{}) string {
+	fields, _ := meta.(map[string]interface{})
+	key, _ := fields["idempotencyKey"].(string)
+	return key
+}
+
 // HandleEndpointHealth handles health check for endpoints
 func HandleEndpointHealth() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FailoverManager promotes this region when the region it backs up is lost
+type FailoverManager interface {
+	Authorize(token string) bool
+	Status(ctx context.Context) (*types.FailoverStatus, error)
+	Failover(ctx context.Context, req *types.FailoverRequest, initiatedBy string) (*types.Failover, error)
+	Fence(ctx context.Context, req *types.FenceRequest, changedBy string) (*types.DeploymentState, error)
+}
+
+// FailoverHandler handles disaster-recovery failover
+type FailoverHandler struct {
+	failover FailoverManager
+}
+
+// NewFailoverHandler creates a new failover handler
+func NewFailoverHandler(failover FailoverManager) *FailoverHandler {
+	return &FailoverHandler{failover: failover}
+}
+
+// GetStatus handles GET /api/admin/failover
+func (h *FailoverHandler) GetStatus(c *gin.Context) {
+	status, err := h.failover.Status(c.Request.Context())
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, status)
+}
+
+// Failover handles POST /api/admin/failover
+func (h *FailoverHandler) Failover(c *gin.Context) {
+	var req types.FailoverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	failover, err := h.failover.Failover(c.Request.Context(), &req, c.GetString("user_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, failover)
+}
+
+// Fence handles POST /api/replication/fence. The region taking over calls
+// it with the shared replication token.
+func (h *FailoverHandler) Fence(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !h.failover.Authorize(token) {
+		RespondWithError(c, types.NewUnauthorizedError("Invalid replication token"))
+		return
+	}
+
+	var req types.FenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	state, err := h.failover.Fence(c.Request.Context(), &req, "region:"+req.Region)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, state)
+}
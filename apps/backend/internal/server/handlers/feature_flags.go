@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlagManager defines the feature flag operations used by the handler
+type FeatureFlagManager interface {
+	ListFlags(ctx context.Context, orgID string) []*types.FeatureFlagState
+	GetFlag(ctx context.Context, orgID, key string) (*types.FeatureFlagState, error)
+	SetFlag(ctx context.Context, key, orgID string, enabled bool, updatedBy string) error
+	ClearFlag(ctx context.Context, key, orgID string) error
+}
+
+// FeatureFlagHandler lists and flips feature flags and reports runtime
+// gateway configuration
+type FeatureFlagHandler struct {
+	flags    FeatureFlagManager
+	readOnly ReadOnlySwitch
+	auditor  AuditRecorder
+}
+
+// NewFeatureFlagHandler creates a new feature flag handler
+func NewFeatureFlagHandler(flags FeatureFlagManager, readOnly ReadOnlySwitch, auditor AuditRecorder) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		flags:    flags,
+		readOnly: readOnly,
+		auditor:  auditor,
+	}
+}
+
+// GetConfig handles GET /api/admin/config
+func (h *FeatureFlagHandler) GetConfig(c *gin.Context) {
+	config := gin.H{
+		"feature_flags": h.flags.ListFlags(c.Request.Context(), c.GetString("organization_id")),
+	}
+	if h.readOnly != nil {
+		config["read_only"] = h.readOnly.Status()
+	}
+	RespondWithSuccess(c, config)
+}
+
+// ListFlags handles GET /api/admin/feature-flags
+func (h *FeatureFlagHandler) ListFlags(c *gin.Context) {
+	RespondWithSuccess(c, h.flags.ListFlags(c.Request.Context(), c.GetString("organization_id")))
+}
+
+// SetFlag handles PUT /api/admin/feature-flags/:key
+func (h *FeatureFlagHandler) SetFlag(c *gin.Context) {
+	var req types.SetFeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	key := c.Param("key")
+	orgID := c.GetString("organization_id")
+	scopeOrgID := orgID
+	if req.Global {
+		scopeOrgID = ""
+	}
+
+	if err := h.flags.SetFlag(c.Request.Context(), key, scopeOrgID, *req.Enabled, c.GetString("user_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	h.audit(c, "update", key, map[string]interface{}{
+		"enabled": *req.Enabled,
+		"global":  req.Global,
+	})
+
+	state, err := h.flags.GetFlag(c.Request.Context(), orgID, key)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, state)
+}
+
+// ClearFlag handles DELETE /api/admin/feature-flags/:key. The stored value
+// for the caller's organization is removed, or the global value with ?global=true.
+func (h *FeatureFlagHandler) ClearFlag(c *gin.Context) {
+	key := c.Param("key")
+	orgID := c.GetString("organization_id")
+	global := c.Query("global") == "true"
+	scopeOrgID := orgID
+	if global {
+		scopeOrgID = ""
+	}
+
+	if err := h.flags.ClearFlag(c.Request.Context(), key, scopeOrgID); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	h.audit(c, "delete", key, map[string]interface{}{
+		"global": global,
+	})
+
+	state, err := h.flags.GetFlag(c.Request.Context(), orgID, key)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, state)
+}
+
+func (h *FeatureFlagHandler) audit(c *gin.Context, action, key string, details map[string]interface{}) {
+	if h.auditor == nil {
+		return
+	}
+	if err := h.auditor.LogAudit(c.Request.Context(), &types.AuditLog{
+		OrganizationID: c.GetString("organization_id"),
+		UserID:         c.GetString("user_id"),
+		Action:         action,
+		Resource:       "feature_flag",
+		ResourceID:     key,
+		RemoteIP:       c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Success:        true,
+		Details:        details,
+	}); err != nil {
+		log.Printf("Failed to audit feature flag change: %v", err)
+	}
+}
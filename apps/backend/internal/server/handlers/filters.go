@@ -60,6 +60,12 @@ func (h *FiltersHandler) CreateFilter(c *gin.Context) {
 		return
 	}
 
+	// Reject endpoints that offline mode cannot reach
+	if err := h.pluginService.CheckEgress(plugins.PluginType(req.Type), req.Config); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
 	// Create filter model
 	userIDStr := userID.(string)
 	filter := models.NewContentFilter(
@@ -285,6 +291,11 @@ func (h *FiltersHandler) UpdateFilter(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter configuration", "details": err.Error()})
 			return
 		}
+
+		if err := h.pluginService.CheckEgress(plugins.PluginType(existingFilter.Type), req.Config); err != nil {
+			RespondWithError(c, err)
+			return
+		}
 	}
 
 	// Build update query dynamically
@@ -4,19 +4,30 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/discovery"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/secrets"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/gin-gonic/gin"
 )
 
+// ServerRegistry registers the MCP servers the gateway proxies to
+type ServerRegistry interface {
+	ListServers(orgID string) ([]*types.MCPServer, error)
+	RegisterServer(orgID string, req *types.CreateMCPServerRequest) (*types.MCPServer, error)
+	GetServer(serverID string) (*types.MCPServer, error)
+	UpdateServer(serverID string, req *types.UpdateMCPServerRequest) (*types.MCPServer, error)
+	UnregisterServer(serverID string) error
+	GetServerStats(serverID string) (*types.ServerStats, error)
+	DiscoverServerTools(serverID string) error
+}
+
 // GatewayHandler handles gateway management endpoints
 type GatewayHandler struct {
-	discoveryService *discovery.Service
+	discoveryService ServerRegistry
 }
 
 // NewGatewayHandler creates a new gateway handler
-func NewGatewayHandler(discoveryService *discovery.Service) *GatewayHandler {
+func NewGatewayHandler(discoveryService ServerRegistry) *GatewayHandler {
 	return &GatewayHandler{
 		discoveryService: discoveryService,
 	}
@@ -67,9 +78,14 @@ func (h *GatewayHandler) ListServers(c *gin.Context) {
 		return
 	}
 
+	masked := make([]*types.MCPServer, len(servers))
+	for i, server := range servers {
+		masked[i] = secrets.MaskServer(server)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    servers,
+		"data":    masked,
 	})
 }
 
@@ -96,7 +112,7 @@ func (h *GatewayHandler) RegisterServer(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
-		"data":    server,
+		"data":    secrets.MaskServer(server),
 	})
 }
 
@@ -123,7 +139,7 @@ func (h *GatewayHandler) GetServer(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    server,
+		"data":    secrets.MaskServer(server),
 	})
 }
 
@@ -159,7 +175,7 @@ func (h *GatewayHandler) UpdateServer(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    server,
+		"data":    secrets.MaskServer(server),
 	})
 }
 
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EndpointURLResolver resolves endpoints and their public URLs
+type EndpointURLResolver interface {
+	ResolveEndpoint(ctx context.Context, name string) (*types.EndpointConfig, error)
+	GenerateURLs(endpointName string) *types.EndpointURLs
+}
+
+// GatewayDiscoveryHandler serves /.well-known/mcp-gateway
+type GatewayDiscoveryHandler struct {
+	endpoints EndpointURLResolver
+	baseURL   string
+}
+
+// NewGatewayDiscoveryHandler creates a new gateway discovery handler
+func NewGatewayDiscoveryHandler(endpoints EndpointURLResolver, baseURL string) *GatewayDiscoveryHandler {
+	return &GatewayDiscoveryHandler{
+		endpoints: endpoints,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Discover handles GET /.well-known/mcp-gateway. Without an endpoint it
+// describes the gateway with {endpoint_name} URL templates; with
+// ?endpoint=<name> it describes that endpoint's namespace.
+func (h *GatewayDiscoveryHandler) Discover(c *gin.Context) {
+	if name := c.Query("endpoint"); name != "" {
+		h.discoverEndpoint(c, name)
+		return
+	}
+
+	doc := h.document(h.endpoints.GenerateURLs("{endpoint_name}"))
+	doc.EndpointsURL = h.baseURL + "/api/public/endpoints"
+	doc.AuthMethods = []types.DiscoveryAuthMethod{
+		{Type: types.DiscoveryAuthAPIKey, Locations: []string{"header", "bearer", "query"}},
+		{Type: types.DiscoveryAuthOAuth2, Locations: []string{"bearer"}},
+		{Type: types.DiscoveryAuthNone},
+	}
+	doc.OAuth = h.oauthMetadata()
+
+	c.JSON(http.StatusOK, doc)
+}
+
+// DiscoverEndpoint handles GET /.well-known/mcp-gateway/:endpoint_name
+func (h *GatewayDiscoveryHandler) DiscoverEndpoint(c *gin.Context) {
+	h.discoverEndpoint(c, c.Param("endpoint_name"))
+}
+
+func (h *GatewayDiscoveryHandler) discoverEndpoint(c *gin.Context, name string) {
+	config, err := h.endpoints.ResolveEndpoint(c.Request.Context(), name)
+	if err != nil || config.Endpoint == nil || !config.Endpoint.IsActive {
+		RespondWithNotFound(c, "Endpoint")
+		return
+	}
+	endpoint := config.Endpoint
+
+	urls := h.endpoints.GenerateURLs(endpoint.Name)
+	doc := h.document(urls)
+	doc.Endpoint = &types.DiscoveredEndpoint{
+		Name:        endpoint.Name,
+		Description: endpoint.Description,
+		NamespaceID: endpoint.NamespaceID,
+		OpenAPIURL:  urls.OpenAPI,
+		DocsURL:     urls.Documentation,
+	}
+	if config.Namespace != nil {
+		doc.Endpoint.NamespaceName = config.Namespace.Name
+	}
+
+	doc.AuthMethods = endpointAuthMethods(endpoint)
+	if endpoint.EnableOAuth && !endpoint.EnablePublicAccess {
+		doc.OAuth = h.oauthMetadata()
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+// document builds the parts shared by gateway and endpoint discovery
+func (h *GatewayDiscoveryHandler) document(urls *types.EndpointURLs) *types.GatewayDiscoveryDocument {
+	return &types.GatewayDiscoveryDocument{
+		Issuer:           h.baseURL,
+		ProtocolVersions: types.MCPProtocolVersions,
+		Transports: []types.DiscoveredTransport{
+			{
+				Type:      types.DiscoveryTransportStreamableHTTP,
+				URL:       urls.HTTP,
+				Methods:   []string{http.MethodPost, http.MethodGet},
+				Preferred: true,
+			},
+			{
+				Type:       types.DiscoveryTransportSSE,
+				URL:        urls.SSE,
+				MessageURL: strings.TrimSuffix(urls.SSE, "/sse") + "/message",
+				Methods:    []string{http.MethodGet},
+			},
+			{
+				Type:         types.DiscoveryTransportWebSocket,
+				URL:          toWebSocketURL(urls.WebSocket),
+				Methods:      []string{http.MethodGet},
+				Subprotocols: transport.WebSocketSubprotocols,
+			},
+			{
+				Type:    types.DiscoveryTransportREST,
+				URL:     strings.TrimSuffix(urls.OpenAPI, "/openapi.json") + "/tools",
+				Methods: []string{http.MethodGet, http.MethodPost},
+			},
+		},
+	}
+}
+
+func (h *GatewayDiscoveryHandler) oauthMetadata() *types.DiscoveryOAuth {
+	return &types.DiscoveryOAuth{
+		AuthorizationServer: h.baseURL + "/.well-known/oauth-authorization-server",
+		ProtectedResource:   h.baseURL + "/.well-known/oauth-protected-resource",
+	}
+}
+
+// endpointAuthMethods mirrors the checks in EndpointAuthMiddleware
+func endpointAuthMethods(endpoint *types.Endpoint) []types.DiscoveryAuthMethod {
+	if endpoint.EnablePublicAccess {
+		return []types.DiscoveryAuthMethod{{Type: types.DiscoveryAuthNone}}
+	}
+
+	methods := []types.DiscoveryAuthMethod{}
+	if endpoint.EnableAPIKeyAuth {
+		locations := []string{"header", "bearer"}
+		if endpoint.UseQueryParamAuth {
+			locations = append(locations, "query")
+		}
+		methods = append(methods, types.DiscoveryAuthMethod{Type: types.DiscoveryAuthAPIKey, Locations: locations})
+	}
+	if endpoint.EnableOAuth {
+		methods = append(methods, types.DiscoveryAuthMethod{Type: types.DiscoveryAuthOAuth2, Locations: []string{"bearer"}})
+	}
+	return methods
+}
+
+// toWebSocketURL switches an http(s) URL to the matching ws(s) scheme
+func toWebSocketURL(url string) string {
+	switch {
+	case strings.HasPrefix(url, "https://"):
+		return "wss://" + strings.TrimPrefix(url, "https://")
+	case strings.HasPrefix(url, "http://"):
+		return "ws://" + strings.TrimPrefix(url, "http://")
+	}
+	return url
+}
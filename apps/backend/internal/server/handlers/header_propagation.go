@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderPropagationManager manages which caller headers reach the upstream
+// servers of a server or namespace
+type HeaderPropagationManager interface {
+	GetRules(ctx context.Context, orgID, scopeType, scopeID string) (*types.HeaderPropagationSettings, error)
+	SetRules(ctx context.Context, orgID, scopeType, scopeID string, rules *types.HeaderPropagationRules) (*types.HeaderPropagationSettings, error)
+	DeleteRules(ctx context.Context, orgID, scopeType, scopeID string) error
+}
+
+// HeaderPropagationHandler handles the header propagation rules of servers
+// and namespaces
+type HeaderPropagationHandler struct {
+	rules HeaderPropagationManager
+}
+
+// NewHeaderPropagationHandler creates a new header propagation handler
+func NewHeaderPropagationHandler(rules HeaderPropagationManager) *HeaderPropagationHandler {
+	return &HeaderPropagationHandler{rules: rules}
+}
+
+// GetServerRules handles GET /api/gateway/servers/:id/header-propagation
+func (h *HeaderPropagationHandler) GetServerRules(c *gin.Context) {
+	h.get(c, types.HeaderPropagationScopeServer)
+}
+
+// SetServerRules handles PUT /api/gateway/servers/:id/header-propagation
+func (h *HeaderPropagationHandler) SetServerRules(c *gin.Context) {
+	h.set(c, types.HeaderPropagationScopeServer)
+}
+
+// DeleteServerRules handles DELETE /api/gateway/servers/:id/header-propagation
+func (h *HeaderPropagationHandler) DeleteServerRules(c *gin.Context) {
+	h.delete(c, types.HeaderPropagationScopeServer)
+}
+
+// GetNamespaceRules handles GET /api/namespaces/:id/header-propagation
+func (h *HeaderPropagationHandler) GetNamespaceRules(c *gin.Context) {
+	h.get(c, types.HeaderPropagationScopeNamespace)
+}
+
+// SetNamespaceRules handles PUT /api/namespaces/:id/header-propagation
+func (h *HeaderPropagationHandler) SetNamespaceRules(c *gin.Context) {
+	h.set(c, types.HeaderPropagationScopeNamespace)
+}
+
+// DeleteNamespaceRules handles DELETE /api/namespaces/:id/header-propagation
+func (h *HeaderPropagationHandler) DeleteNamespaceRules(c *gin.Context) {
+	h.delete(c, types.HeaderPropagationScopeNamespace)
+}
+
+func (h *HeaderPropagationHandler) get(c *gin.Context, scopeType string) {
+	settings, err := h.rules.GetRules(c.Request.Context(), c.GetString("organization_id"), scopeType, c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, settings)
+}
+
+func (h *HeaderPropagationHandler) set(c *gin.Context, scopeType string) {
+	var rules types.HeaderPropagationRules
+	if err := c.ShouldBindJSON(&rules); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	settings, err := h.rules.SetRules(c.Request.Context(), c.GetString("organization_id"), scopeType, c.Param("id"), &rules)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, settings)
+}
+
+func (h *HeaderPropagationHandler) delete(c *gin.Context, scopeType string) {
+	if err := h.rules.DeleteRules(c.Request.Context(), c.GetString("organization_id"), scopeType, c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Header propagation rules deleted"})
+}
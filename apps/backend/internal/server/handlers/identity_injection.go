@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdentityInjectionManager manages which caller identity servers receive on
+// tool calls
+type IdentityInjectionManager interface {
+	GetSettings(ctx context.Context, orgID, serverID string) (*types.IdentityInjectionSettings, error)
+	SetSettings(ctx context.Context, orgID, serverID string, req *types.SetIdentityInjectionRequest) (*types.IdentityInjectionSettings, error)
+	DeleteSettings(ctx context.Context, orgID, serverID string) error
+}
+
+// IdentityInjectionHandler handles the identity injection settings of
+// servers
+type IdentityInjectionHandler struct {
+	identity IdentityInjectionManager
+}
+
+// NewIdentityInjectionHandler creates a new identity injection handler
+func NewIdentityInjectionHandler(identity IdentityInjectionManager) *IdentityInjectionHandler {
+	return &IdentityInjectionHandler{identity: identity}
+}
+
+// GetSettings handles GET /api/gateway/servers/:id/identity-injection
+func (h *IdentityInjectionHandler) GetSettings(c *gin.Context) {
+	settings, err := h.identity.GetSettings(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, settings)
+}
+
+// SetSettings handles PUT /api/gateway/servers/:id/identity-injection
+func (h *IdentityInjectionHandler) SetSettings(c *gin.Context) {
+	var req types.SetIdentityInjectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	settings, err := h.identity.SetSettings(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, settings)
+}
+
+// DeleteSettings handles DELETE /api/gateway/servers/:id/identity-injection
+func (h *IdentityInjectionHandler) DeleteSettings(c *gin.Context) {
+	if err := h.identity.DeleteSettings(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Identity injection disabled"})
+}
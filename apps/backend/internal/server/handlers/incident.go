@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IncidentManager declares and resolves incidents within an organization
+type IncidentManager interface {
+	Create(ctx context.Context, orgID, createdBy string, req *types.CreateIncidentRequest) (*types.Incident, error)
+	Get(ctx context.Context, orgID, id string) (*types.Incident, error)
+	List(ctx context.Context, filter *types.IncidentFilter) ([]*types.Incident, error)
+	Update(ctx context.Context, orgID, id string, req *types.UpdateIncidentRequest) (*types.Incident, error)
+	Delete(ctx context.Context, orgID, id string) error
+}
+
+// HealthHistorySource returns a server's recent health checks, newest first
+type HealthHistorySource interface {
+	GetHealthHistory(serverID string, limit int) ([]*types.HealthCheck, error)
+}
+
+// IncidentHandler manages incidents and serves the views they annotate
+type IncidentHandler struct {
+	incidents IncidentManager
+	health    HealthHistorySource
+}
+
+// NewIncidentHandler creates a new incident handler
+func NewIncidentHandler(incidents IncidentManager, health HealthHistorySource) *IncidentHandler {
+	return &IncidentHandler{
+		incidents: incidents,
+		health:    health,
+	}
+}
+
+// ListIncidents handles GET /api/admin/incidents. from and to (RFC 3339)
+// select incidents overlapping a metrics timeline.
+func (h *IncidentHandler) ListIncidents(c *gin.Context) {
+	filter := &types.IncidentFilter{
+		OrganizationID: c.GetString("organization_id"),
+		ServerID:       c.Query("server_id"),
+		NamespaceID:    c.Query("namespace_id"),
+		OngoingOnly:    c.Query("ongoing") == "true",
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			RespondWithValidationError(c, param+" must be an RFC 3339 timestamp")
+			return
+		}
+		*target = &t
+	}
+
+	incidents, err := h.incidents.List(c.Request.Context(), filter)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, incidents)
+}
+
+// GetIncident handles GET /api/admin/incidents/:id
+func (h *IncidentHandler) GetIncident(c *gin.Context) {
+	incident, err := h.incidents.Get(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, incident)
+}
+
+// CreateIncident handles POST /api/admin/incidents
+func (h *IncidentHandler) CreateIncident(c *gin.Context) {
+	var req types.CreateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	incident, err := h.incidents.Create(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, incident)
+}
+
+// UpdateIncident handles PUT /api/admin/incidents/:id
+func (h *IncidentHandler) UpdateIncident(c *gin.Context) {
+	var req types.UpdateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	incident, err := h.incidents.Update(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, incident)
+}
+
+// DeleteIncident handles DELETE /api/admin/incidents/:id
+func (h *IncidentHandler) DeleteIncident(c *gin.Context) {
+	if err := h.incidents.Delete(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Incident deleted"})
+}
+
+// GetServerHealthHistory handles GET /api/gateway/servers/:id/health-history,
+// returning recent checks annotated with the incidents over the same period
+func (h *IncidentHandler) GetServerHealthHistory(c *gin.Context) {
+	limit := 100
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			RespondWithValidationError(c, "limit must be between 1 and 1000")
+			return
+		}
+		limit = parsed
+	}
+
+	serverID := c.Param("id")
+	if _, err := uuid.Parse(serverID); err != nil {
+		RespondWithValidationError(c, "Invalid server ID")
+		return
+	}
+	checks, err := h.health.GetHealthHistory(serverID, limit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	history := &types.ServerHealthHistory{
+		ServerID:  serverID,
+		Checks:    checks,
+		Incidents: []*types.Incident{},
+	}
+	if history.Checks == nil {
+		history.Checks = []*types.HealthCheck{}
+	}
+	if len(checks) > 0 {
+		from := checks[len(checks)-1].CheckedAt
+		incidents, err := h.incidents.List(c.Request.Context(), &types.IncidentFilter{
+			OrganizationID: c.GetString("organization_id"),
+			ServerID:       serverID,
+			From:           &from,
+		})
+		if err != nil {
+			RespondWithError(c, err)
+			return
+		}
+		history.Incidents = incidents
+	}
+	RespondWithSuccess(c, history)
+}
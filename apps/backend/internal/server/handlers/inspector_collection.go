@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InspectorCollectionManager manages the request collections saved per
+// server for the inspector
+type InspectorCollectionManager interface {
+	ListCollections(ctx context.Context, orgID, serverID string) ([]*types.InspectorCollection, error)
+	GetCollection(ctx context.Context, orgID, id string) (*types.InspectorCollection, error)
+	CreateCollection(ctx context.Context, orgID, serverID, userID string, req *types.CreateInspectorCollectionRequest) (*types.InspectorCollection, error)
+	UpdateCollection(ctx context.Context, orgID, id string, req *types.UpdateInspectorCollectionRequest) (*types.InspectorCollection, error)
+	DeleteCollection(ctx context.Context, orgID, id string) error
+}
+
+// InspectorCollectionHandler handles saved inspector request collections
+type InspectorCollectionHandler struct {
+	collections InspectorCollectionManager
+}
+
+// NewInspectorCollectionHandler creates a new inspector collection handler
+func NewInspectorCollectionHandler(collections InspectorCollectionManager) *InspectorCollectionHandler {
+	return &InspectorCollectionHandler{collections: collections}
+}
+
+// ListCollections handles GET /api/inspector/servers/:id/collections
+func (h *InspectorCollectionHandler) ListCollections(c *gin.Context) {
+	collections, err := h.collections.ListCollections(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, collections)
+}
+
+// GetCollection handles GET /api/inspector/collections/:collection_id
+func (h *InspectorCollectionHandler) GetCollection(c *gin.Context) {
+	collection, err := h.collections.GetCollection(c.Request.Context(), c.GetString("organization_id"), c.Param("collection_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, collection)
+}
+
+// CreateCollection handles POST /api/inspector/servers/:id/collections
+func (h *InspectorCollectionHandler) CreateCollection(c *gin.Context) {
+	var req types.CreateInspectorCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	collection, err := h.collections.CreateCollection(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, collection)
+}
+
+// UpdateCollection handles PUT /api/inspector/collections/:collection_id
+func (h *InspectorCollectionHandler) UpdateCollection(c *gin.Context) {
+	var req types.UpdateInspectorCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	collection, err := h.collections.UpdateCollection(c.Request.Context(), c.GetString("organization_id"), c.Param("collection_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, collection)
+}
+
+// DeleteCollection handles DELETE /api/inspector/collections/:collection_id
+func (h *InspectorCollectionHandler) DeleteCollection(c *gin.Context) {
+	if err := h.collections.DeleteCollection(c.Request.Context(), c.GetString("organization_id"), c.Param("collection_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Inspector collection deleted"})
+}
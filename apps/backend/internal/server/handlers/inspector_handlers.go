@@ -3,20 +3,63 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/inspector"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+// InspectorFrames captures the raw JSON-RPC frames of inspector sessions
+// and sends raw frames
+type InspectorFrames interface {
+	ListFrames(sessionID string) ([]*inspector.Frame, error)
+	GetFrame(sessionID, frameID string) (*inspector.Frame, error)
+	SendRaw(ctx context.Context, sessionID string, raw json.RawMessage) (*inspector.RawExchange, error)
+	ResendFrame(ctx context.Context, sessionID, frameID string, edited json.RawMessage) (*inspector.RawExchange, error)
+}
+
+// InspectorCollectionSource looks up saved inspector request collections
+type InspectorCollectionSource interface {
+	GetCollection(ctx context.Context, orgID, id string) (*types.InspectorCollection, error)
+}
+
+// InspectorBroadcaster attaches further servers to inspector sessions and
+// sends requests to all of a session's servers at once
+type InspectorBroadcaster interface {
+	AttachServer(ctx context.Context, sessionID, serverID, serverName string) error
+	DetachServer(ctx context.Context, sessionID, serverID string) error
+	Broadcast(ctx context.Context, sessionID string, req inspector.BroadcastRequest) (*inspector.BroadcastResponse, error)
+}
+
+// NamespaceServerLister lists the servers of an organization's namespace
+type NamespaceServerLister interface {
+	ListNamespaceServers(ctx context.Context, orgID, namespaceID string) ([]types.NamespaceServer, error)
+}
+
+// CollectionRunStep is the outcome of one request of a collection run
+type CollectionRunStep struct {
+	Name     string                 `json:"name"`
+	Method   string                 `json:"method"`
+	Exchange *inspector.RawExchange `json:"exchange,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
 // InspectorHandler handles inspector-related HTTP requests
 type InspectorHandler struct {
-	service inspector.InspectorService
+	service     inspector.InspectorService
+	history     PlaygroundHistoryRecorder
+	frames      InspectorFrames
+	collections InspectorCollectionSource
+	broadcaster InspectorBroadcaster
+	namespaces  NamespaceServerLister
 }
 
 // NewInspectorHandler creates a new inspector handler
@@ -26,6 +69,29 @@ func NewInspectorHandler(service inspector.InspectorService) *InspectorHandler {
 	}
 }
 
+// SetHistory records tool calls made through the inspector in the caller's
+// playground history
+func (h *InspectorHandler) SetHistory(history PlaygroundHistoryRecorder) {
+	h.history = history
+}
+
+// SetFrames enables the raw frame view and sending raw frames
+func (h *InspectorHandler) SetFrames(frames InspectorFrames) {
+	h.frames = frames
+}
+
+// SetCollections enables running saved request collections on sessions
+func (h *InspectorHandler) SetCollections(collections InspectorCollectionSource) {
+	h.collections = collections
+}
+
+// SetBroadcast enables attaching the other servers of a session's
+// namespace and broadcasting requests to them
+func (h *InspectorHandler) SetBroadcast(broadcaster InspectorBroadcaster, namespaces NamespaceServerLister) {
+	h.broadcaster = broadcaster
+	h.namespaces = namespaces
+}
+
 // CreateSession creates a new inspector session
 func (h *InspectorHandler) CreateSession(c *gin.Context) {
 	var req inspector.CreateSessionRequest
@@ -124,25 +190,371 @@ func (h *InspectorHandler) ExecuteRequest(c *gin.Context) {
 		return
 	}
 
+	h.execute(c, session, reqBody.Method, reqBody.Params)
+}
+
+// RerunExecution calls a tool again with the arguments of an inspector call
+// from the caller's playground history, on the same server
+func (h *InspectorHandler) RerunExecution(c *gin.Context) {
+	session, err := h.service.GetSession(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if session.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+	if h.history == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "playground execution not found"})
+		return
+	}
+
+	execution, err := h.history.OwnExecution(c.Request.Context(), session.OrgID, userID, c.Param("execution_id"))
+	if err != nil || execution.Source != types.PlaygroundSourceInspector || execution.ServerID != session.ServerID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "playground execution not found"})
+		return
+	}
+
+	h.execute(c, session, "tools/call", map[string]interface{}{
+		"name":      execution.ToolName,
+		"arguments": execution.Arguments,
+	})
+}
+
+// execute runs an MCP request on a session, recording tool calls in the
+// caller's playground history
+func (h *InspectorHandler) execute(c *gin.Context, session *inspector.InspectorSession, method string, params map[string]interface{}) {
 	// Create inspector request
 	req := inspector.InspectorRequest{
 		ID:        uuid.New().String(),
-		SessionID: sessionID,
-		Method:    reqBody.Method,
-		Params:    reqBody.Params,
+		SessionID: session.ID,
+		Method:    method,
+		Params:    params,
 		Timestamp: time.Now(),
 	}
 
 	// Execute request
-	response, err := h.service.ExecuteRequest(c.Request.Context(), sessionID, req)
+	response, err := h.service.ExecuteRequest(c.Request.Context(), session.ID, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if method == "tools/call" {
+		h.record(c, session, params, response)
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
+// record adds an inspector tool call to the caller's playground history.
+// Failing to record never fails the call itself.
+func (h *InspectorHandler) record(c *gin.Context, session *inspector.InspectorSession, params map[string]interface{}, response *inspector.InspectorResponse) {
+	if h.history == nil {
+		return
+	}
+
+	toolName, _ := params["name"].(string)
+	arguments, _ := params["arguments"].(map[string]interface{})
+	execution := &types.PlaygroundExecution{
+		OrganizationID: session.OrgID,
+		UserID:         session.UserID,
+		Source:         types.PlaygroundSourceInspector,
+		ServerID:       session.ServerID,
+		NamespaceID:    session.NamespaceID,
+		ToolName:       toolName,
+		Arguments:      arguments,
+		Result:         response.Result,
+		DurationMS:     float64(response.Duration.Microseconds()) / 1000,
+	}
+	if response.Error != nil {
+		execution.IsError = true
+		execution.ErrorMessage = response.Error.Message
+	}
+
+	if err := h.history.Record(context.WithoutCancel(c.Request.Context()), execution); err != nil {
+		log.Printf("Failed to record inspector call of %s on server %s: %v", toolName, session.ServerID, err)
+	}
+}
+
+// ListFrames returns the raw JSON-RPC frames captured on a session, oldest
+// first, optionally only those of a kind or direction
+func (h *InspectorHandler) ListFrames(c *gin.Context) {
+	session, ok := h.framesSession(c)
+	if !ok {
+		return
+	}
+
+	frames, err := h.frames.ListFrames(session.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	kind, direction := c.Query("kind"), c.Query("direction")
+	filtered := make([]*inspector.Frame, 0, len(frames))
+	for _, frame := range frames {
+		if (kind == "" || frame.Kind == kind) && (direction == "" || frame.Direction == direction) {
+			filtered = append(filtered, frame)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"frames": filtered})
+}
+
+// GetFrame returns a raw JSON-RPC frame captured on a session
+func (h *InspectorHandler) GetFrame(c *gin.Context) {
+	session, ok := h.framesSession(c)
+	if !ok {
+		return
+	}
+
+	frame, err := h.frames.GetFrame(session.ID, c.Param("frame_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, frame)
+}
+
+// SendRaw sends a raw JSON-RPC request or notification on a session
+func (h *InspectorHandler) SendRaw(c *gin.Context) {
+	session, ok := h.framesSession(c)
+	if !ok {
+		return
+	}
+
+	var req inspector.SendRawRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exchange, err := h.frames.SendRaw(c.Request.Context(), session.ID, req.Frame)
+	h.respondWithExchange(c, exchange, err)
+}
+
+// ResendFrame sends a captured request again, edited when the body carries
+// a replacement frame
+func (h *InspectorHandler) ResendFrame(c *gin.Context) {
+	session, ok := h.framesSession(c)
+	if !ok {
+		return
+	}
+
+	var req inspector.ResendFrameRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	exchange, err := h.frames.ResendFrame(c.Request.Context(), session.ID, c.Param("frame_id"), req.Frame)
+	h.respondWithExchange(c, exchange, err)
+}
+
+// RunCollection sends the requests of a saved collection on a session, in
+// order. A failed request does not stop the run.
+func (h *InspectorHandler) RunCollection(c *gin.Context) {
+	session, ok := h.framesSession(c)
+	if !ok {
+		return
+	}
+	if h.collections == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "inspector collection not found"})
+		return
+	}
+
+	collection, err := h.collections.GetCollection(c.Request.Context(), session.OrgID, c.Param("collection_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	if collection.ServerID != session.ServerID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "collection belongs to another server"})
+		return
+	}
+
+	steps := make([]CollectionRunStep, 0, len(collection.Requests))
+	for _, request := range collection.Requests {
+		step := CollectionRunStep{Name: request.Name, Method: request.Method}
+		frame, err := inspector.RequestFrame(request.Method, request.Params, request.Notification)
+		if err == nil {
+			step.Exchange, err = h.frames.SendRaw(c.Request.Context(), session.ID, frame)
+		}
+		if err != nil {
+			step.Error = err.Error()
+		}
+		steps = append(steps, step)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collection_id": collection.ID, "steps": steps})
+}
+
+// AttachServers attaches servers of the session's namespace to a session
+// so that broadcast requests reach them too. Without server_ids every
+// other server of the namespace is attached.
+func (h *InspectorHandler) AttachServers(c *gin.Context) {
+	session, ok := h.broadcastSession(c)
+	if !ok {
+		return
+	}
+
+	var req inspector.AttachServersRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if session.NamespaceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "only sessions opened in a namespace can attach servers"})
+		return
+	}
+
+	servers, err := h.namespaces.ListNamespaceServers(c.Request.Context(), session.OrgID, session.NamespaceID)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	names := make(map[string]string, len(servers))
+	for _, server := range servers {
+		names[server.ServerID] = server.ServerName
+	}
+
+	serverIDs := req.ServerIDs
+	if len(serverIDs) == 0 {
+		attached := make(map[string]bool, len(session.AttachedServers))
+		for _, server := range session.AttachedServers {
+			attached[server.ServerID] = true
+		}
+		for _, server := range servers {
+			if server.ServerID != session.ServerID && !attached[server.ServerID] {
+				serverIDs = append(serverIDs, server.ServerID)
+			}
+		}
+	}
+	for _, serverID := range serverIDs {
+		if _, inNamespace := names[serverID]; !inNamespace {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("server %s is not in the session's namespace", serverID)})
+			return
+		}
+	}
+
+	for _, serverID := range serverIDs {
+		err := h.broadcaster.AttachServer(c.Request.Context(), session.ID, serverID, names[serverID])
+		switch {
+		case errors.Is(err, inspector.ErrServerAttached):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "server_id": serverID})
+			return
+		case errors.Is(err, inspector.ErrTooManyAttachedServers):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "server_id": serverID})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// DetachServer stops broadcasting a session's requests to a server
+func (h *InspectorHandler) DetachServer(c *gin.Context) {
+	session, ok := h.broadcastSession(c)
+	if !ok {
+		return
+	}
+
+	err := h.broadcaster.DetachServer(c.Request.Context(), session.ID, c.Param("server_id"))
+	if errors.Is(err, inspector.ErrServerNotAttached) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// Broadcast sends a request to the session's server and every attached
+// server, returning their responses side by side
+func (h *InspectorHandler) Broadcast(c *gin.Context) {
+	session, ok := h.broadcastSession(c)
+	if !ok {
+		return
+	}
+
+	var req inspector.BroadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.broadcaster.Broadcast(c.Request.Context(), session.ID, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// broadcastSession returns the session in the path if the caller owns it
+// and broadcasting is enabled, responding with an error otherwise
+func (h *InspectorHandler) broadcastSession(c *gin.Context) (*inspector.InspectorSession, bool) {
+	if h.broadcaster == nil || h.namespaces == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return nil, false
+	}
+	return h.ownSession(c)
+}
+
+// framesSession returns the session in the path if the caller owns it and
+// raw frames are enabled, responding with an error otherwise
+func (h *InspectorHandler) framesSession(c *gin.Context) (*inspector.InspectorSession, bool) {
+	if h.frames == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return nil, false
+	}
+	return h.ownSession(c)
+}
+
+// ownSession returns the session in the path if the caller owns it,
+// responding with an error otherwise
+func (h *InspectorHandler) ownSession(c *gin.Context) (*inspector.InspectorSession, bool) {
+	session, err := h.service.GetSession(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return nil, false
+	}
+	if session.UserID != c.GetString("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return nil, false
+	}
+	return session, true
+}
+
+// respondWithExchange writes the outcome of sending a raw frame
+func (h *InspectorHandler) respondWithExchange(c *gin.Context, exchange *inspector.RawExchange, err error) {
+	switch {
+	case errors.Is(err, inspector.ErrInvalidFrame):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, inspector.ErrFrameNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, exchange)
+	}
+}
+
 // StreamEvents streams events for a session using Server-Sent Events
 func (h *InspectorHandler) StreamEvents(c *gin.Context) {
 	sessionID := c.Param("id")
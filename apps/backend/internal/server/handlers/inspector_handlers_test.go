@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/inspector"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -366,6 +367,78 @@ func TestInspectorHandler_ExecuteRequest(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+// recordingHistory keeps recorded playground executions in memory
+type recordingHistory struct {
+	executions []*types.PlaygroundExecution
+}
+
+func (h *recordingHistory) Record(ctx context.Context, execution *types.PlaygroundExecution) error {
+	execution.ID = "exec-" + execution.ToolName
+	h.executions = append(h.executions, execution)
+	return nil
+}
+
+func (h *recordingHistory) OwnExecution(ctx context.Context, orgID, userID, id string) (*types.PlaygroundExecution, error) {
+	for _, execution := range h.executions {
+		if execution.ID == id && execution.OrganizationID == orgID && execution.UserID == userID {
+			return execution, nil
+		}
+	}
+	return nil, types.NewNotFoundError("Playground execution not found")
+}
+
+func TestInspectorHandler_RerunExecution(t *testing.T) {
+	handler, mockService := setupTestHandler()
+	history := &recordingHistory{}
+	handler.SetHistory(history)
+	router := setupInspectorTestRouter()
+	router.POST("/sessions/:id/request", handler.ExecuteRequest)
+	router.POST("/sessions/:id/history/:execution_id/rerun", handler.RerunExecution)
+
+	session := &inspector.InspectorSession{
+		ID:       "session-123",
+		ServerID: "server-1",
+		UserID:   "test-user-123",
+		OrgID:    "test-org-456",
+		Status:   inspector.SessionStatusConnected,
+	}
+	mockService.On("GetSession", "session-123").Return(session, nil)
+	mockService.On("ExecuteRequest", mock.Anything, "session-123", mock.MatchedBy(func(req inspector.InspectorRequest) bool {
+		return req.Method == "tools/call" && req.Params["name"] == "search"
+	})).Return(&inspector.InspectorResponse{ID: "response-1", Result: map[string]interface{}{"content": []interface{}{}}}, nil)
+
+	reqJSON, _ := json.Marshal(inspector.ExecuteRequestBody{
+		Method: "tools/call",
+		Params: map[string]interface{}{"name": "search", "arguments": map[string]interface{}{"query": "go"}},
+	})
+	req := httptest.NewRequest("POST", "/sessions/session-123/request", bytes.NewBuffer(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Len(t, history.executions, 1)
+	recorded := history.executions[0]
+	assert.Equal(t, types.PlaygroundSourceInspector, recorded.Source)
+	assert.Equal(t, "server-1", recorded.ServerID)
+	assert.Equal(t, "go", recorded.Arguments["query"])
+
+	req = httptest.NewRequest("POST", "/sessions/session-123/history/exec-search/rerun", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, history.executions, 2, "re-runs are recorded too")
+
+	// Executions from another server cannot be re-run on this session
+	recorded.ServerID = "server-2"
+	req = httptest.NewRequest("POST", "/sessions/session-123/history/exec-search/rerun", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
 func TestInspectorHandler_StreamEvents(t *testing.T) {
 	handler, mockService := setupTestHandler()
 	router := setupInspectorTestRouter()
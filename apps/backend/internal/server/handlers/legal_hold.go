@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LegalHoldManager places and releases legal holds on organizations
+type LegalHoldManager interface {
+	List(ctx context.Context, includeReleased bool) ([]*types.LegalHold, error)
+	Active(ctx context.Context, orgID string) (*types.LegalHold, error)
+	Place(ctx context.Context, orgID, placedBy, reason string) (*types.LegalHold, error)
+	Release(ctx context.Context, orgID, releasedBy, reason string) (*types.LegalHold, error)
+}
+
+// LegalHoldHandler manages legal holds. Every change is recorded in the
+// held organization's audit trail.
+type LegalHoldHandler struct {
+	holds   LegalHoldManager
+	auditor AuditRecorder
+}
+
+// NewLegalHoldHandler creates a new legal hold handler
+func NewLegalHoldHandler(holds LegalHoldManager, auditor AuditRecorder) *LegalHoldHandler {
+	return &LegalHoldHandler{
+		holds:   holds,
+		auditor: auditor,
+	}
+}
+
+// ListHolds handles GET /api/admin/legal-holds
+func (h *LegalHoldHandler) ListHolds(c *gin.Context) {
+	holds, err := h.holds.List(c.Request.Context(), c.Query("include_released") == "true")
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, holds)
+}
+
+// GetHold handles GET /api/admin/legal-holds/:org_id
+func (h *LegalHoldHandler) GetHold(c *gin.Context) {
+	hold, err := h.holds.Active(c.Request.Context(), c.Param("org_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	if hold == nil {
+		RespondWithNotFound(c, "Legal hold")
+		return
+	}
+	RespondWithSuccess(c, hold)
+}
+
+// PlaceHold handles POST /api/admin/legal-holds/:org_id
+func (h *LegalHoldHandler) PlaceHold(c *gin.Context) {
+	var req types.PlaceLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	orgID := c.Param("org_id")
+	userID := c.GetString("user_id")
+	hold, err := h.holds.Place(c.Request.Context(), orgID, userID, req.Reason)
+	h.audit(c, "place", orgID, req.Reason, err)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	log.Printf("Legal hold placed on organization %s by user %s: %s", orgID, userID, req.Reason)
+	RespondWithCreated(c, hold)
+}
+
+// ReleaseHold handles POST /api/admin/legal-holds/:org_id/release
+func (h *LegalHoldHandler) ReleaseHold(c *gin.Context) {
+	var req types.ReleaseLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	orgID := c.Param("org_id")
+	userID := c.GetString("user_id")
+	hold, err := h.holds.Release(c.Request.Context(), orgID, userID, req.Reason)
+	h.audit(c, "release", orgID, req.Reason, err)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	log.Printf("Legal hold released on organization %s by user %s: %s", orgID, userID, req.Reason)
+	RespondWithSuccess(c, hold)
+}
+
+func (h *LegalHoldHandler) audit(c *gin.Context, action, orgID, reason string, err error) {
+	if h.auditor == nil {
+		return
+	}
+
+	event := &types.AuditLog{
+		OrganizationID: orgID,
+		UserID:         c.GetString("user_id"),
+		Action:         action,
+		Resource:       "legal_hold",
+		ResourceID:     orgID,
+		RemoteIP:       c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Success:        err == nil,
+		Severity:       types.AuditSeverityCritical,
+		Details: map[string]interface{}{
+			"reason":                reason,
+			"actor_organization_id": c.GetString("organization_id"),
+		},
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	if auditErr := h.auditor.LogAudit(c.Request.Context(), event); auditErr != nil {
+		log.Printf("Failed to audit legal hold %s for organization %s: %v", action, orgID, auditErr)
+	}
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LicenseStatusProvider reports the deployment's license state
+type LicenseStatusProvider interface {
+	Status(ctx context.Context) *types.LicenseStatus
+}
+
+// LicenseHandler exposes the license status to admins
+type LicenseHandler struct {
+	license LicenseStatusProvider
+}
+
+// NewLicenseHandler creates a new license handler
+func NewLicenseHandler(license LicenseStatusProvider) *LicenseHandler {
+	return &LicenseHandler{license: license}
+}
+
+// GetStatus handles GET /api/admin/license
+func (h *LicenseHandler) GetStatus(c *gin.Context) {
+	RespondWithSuccess(c, h.license.Status(c.Request.Context()))
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LimitsProvider defines the quota and usage lookups used by the handler
+type LimitsProvider interface {
+	GetPlanQuotas(ctx context.Context, orgID string) (*types.PlanQuotas, error)
+	GetPrincipalUsage(ctx context.Context, principal *types.Principal) (*types.PrincipalUsageSummary, error)
+}
+
+// LimitsHandler lets callers inspect their own rate limits, quotas and usage
+type LimitsHandler struct {
+	limits LimitsProvider
+}
+
+// NewLimitsHandler creates a new limits handler
+func NewLimitsHandler(limits LimitsProvider) *LimitsHandler {
+	return &LimitsHandler{
+		limits: limits,
+	}
+}
+
+// GetLimits handles GET /api/auth/limits. Buckets are those this request
+// passed through, so Remaining already accounts for the request itself.
+func (h *LimitsHandler) GetLimits(c *gin.Context) {
+	report := &types.LimitsReport{
+		GeneratedAt: time.Now().UTC(),
+		Principal:   logging.PrincipalFromGinContext(c),
+		Buckets:     middleware.RateLimitBucketsFromContext(c),
+	}
+	if report.Buckets == nil {
+		report.Buckets = []types.RateLimitBucket{}
+	}
+
+	if orgID := c.GetString("organization_id"); orgID != "" {
+		quotas, err := h.limits.GetPlanQuotas(c.Request.Context(), orgID)
+		if err != nil {
+			RespondWithError(c, err)
+			return
+		}
+		report.Quotas = quotas
+	}
+
+	usage, err := h.limits.GetPrincipalUsage(c.Request.Context(), report.Principal)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	report.Usage = usage
+
+	RespondWithSuccess(c, report)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogExportManager queues, streams and serves bulk log exports
+type LogExportManager interface {
+	CreateExport(ctx context.Context, orgID, userID string, req *types.CreateLogExportRequest) (*types.LogExport, error)
+	ListExports(ctx context.Context, orgID string) ([]*types.LogExport, error)
+	GetExport(ctx context.Context, orgID, id string) (*types.LogExport, error)
+	PrepareStream(ctx context.Context, orgID string, req *types.CreateLogExportRequest) (*services.LogExportStream, error)
+	OpenDownload(ctx context.Context, id, expires, signature string) (*types.LogExport, io.ReadCloser, error)
+}
+
+// LogExportHandler handles bulk CSV and Parquet exports of execution logs
+type LogExportHandler struct {
+	exports LogExportManager
+}
+
+// NewLogExportHandler creates a new log export handler
+func NewLogExportHandler(exports LogExportManager) *LogExportHandler {
+	return &LogExportHandler{exports: exports}
+}
+
+// CreateExport handles POST /api/admin/log-exports, queueing an export for
+// the worker
+func (h *LogExportHandler) CreateExport(c *gin.Context) {
+	var req types.CreateLogExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	export, err := h.exports.CreateExport(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, export)
+}
+
+// ListExports handles GET /api/admin/log-exports
+func (h *LogExportHandler) ListExports(c *gin.Context) {
+	exports, err := h.exports.ListExports(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, exports)
+}
+
+// GetExport handles GET /api/admin/log-exports/:id
+func (h *LogExportHandler) GetExport(c *gin.Context) {
+	export, err := h.exports.GetExport(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, export)
+}
+
+// StreamExport handles GET /api/admin/log-exports/stream, writing the export
+// straight to the response instead of queueing it
+func (h *LogExportHandler) StreamExport(c *gin.Context) {
+	var req types.CreateLogExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	stream, err := h.exports.PrepareStream(c.Request.Context(), c.GetString("organization_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", stream.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", stream.Filename))
+	c.Status(http.StatusOK)
+	if err := stream.Write(c.Request.Context(), c.Writer); err != nil {
+		// The response has started, so the client sees a truncated file
+		_ = c.Error(err)
+	}
+}
+
+// Download handles GET /api/public/log-exports/:id/download. The link's
+// signature stands in for authentication.
+func (h *LogExportHandler) Download(c *gin.Context) {
+	export, file, err := h.exports.OpenDownload(c.Request.Context(), c.Param("id"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	defer file.Close()
+
+	filename := fmt.Sprintf("logs-%s.%s", export.ID, export.Format)
+	contentType := "text/csv; charset=utf-8"
+	if export.Format == types.LogExportFormatParquet {
+		contentType = "application/vnd.apache.parquet"
+	}
+	c.DataFromReader(http.StatusOK, export.SizeBytes, contentType, file, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", filename),
+	})
+}
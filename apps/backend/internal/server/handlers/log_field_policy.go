@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogFieldPolicyManager manages which optional fields request logs record
+type LogFieldPolicyManager interface {
+	GetPolicy(ctx context.Context, orgID string) (*types.LogFieldPolicy, error)
+	SetPolicy(ctx context.Context, orgID, updatedBy string, req *types.SetLogFieldPolicyRequest) (*types.LogFieldPolicy, error)
+	ResetPolicy(ctx context.Context, orgID string) error
+}
+
+// LogFieldPolicyHandler handles organizations' log field policies
+type LogFieldPolicyHandler struct {
+	policies LogFieldPolicyManager
+}
+
+// NewLogFieldPolicyHandler creates a new log field policy handler
+func NewLogFieldPolicyHandler(policies LogFieldPolicyManager) *LogFieldPolicyHandler {
+	return &LogFieldPolicyHandler{policies: policies}
+}
+
+// GetPolicy handles GET /api/admin/log-field-policy
+func (h *LogFieldPolicyHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.policies.GetPolicy(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// SetPolicy handles PUT /api/admin/log-field-policy
+func (h *LogFieldPolicyHandler) SetPolicy(c *gin.Context) {
+	var req types.SetLogFieldPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	policy, err := h.policies.SetPolicy(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// ResetPolicy handles DELETE /api/admin/log-field-policy
+func (h *LogFieldPolicyHandler) ResetPolicy(c *gin.Context) {
+	if err := h.policies.ResetPolicy(c.Request.Context(), c.GetString("organization_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Log field policy reset"})
+}
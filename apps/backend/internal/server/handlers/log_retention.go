@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogRetentionManager manages organizations' retention per type of log and
+// the exports made before logs are purged
+type LogRetentionManager interface {
+	GetPolicy(ctx context.Context, orgID string) (*types.LogRetentionPolicy, error)
+	UpdatePolicy(ctx context.Context, orgID, userID string, req *types.UpdateLogRetentionPolicyRequest) (*types.LogRetentionPolicy, error)
+	ListPurgeWindows(ctx context.Context, orgID string, withinDays int) (*types.RetentionPurgeSchedule, error)
+	ListExports(ctx context.Context, orgID string) ([]*types.RetentionExport, error)
+}
+
+// LogRetentionHandler handles log retention policies and upcoming purges
+type LogRetentionHandler struct {
+	retention LogRetentionManager
+}
+
+// NewLogRetentionHandler creates a new log retention handler
+func NewLogRetentionHandler(retention LogRetentionManager) *LogRetentionHandler {
+	return &LogRetentionHandler{retention: retention}
+}
+
+// GetPolicy handles GET /api/admin/log-retention
+func (h *LogRetentionHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.retention.GetPolicy(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// UpdatePolicy handles PUT /api/admin/log-retention
+func (h *LogRetentionHandler) UpdatePolicy(c *gin.Context) {
+	var req types.UpdateLogRetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	policy, err := h.retention.UpdatePolicy(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// ListPurgeWindows handles GET /api/admin/log-retention/purge-windows,
+// showing the rows purged over the next ?within_days= days (7 by default)
+func (h *LogRetentionHandler) ListPurgeWindows(c *gin.Context) {
+	withinDays := 0
+	if value := c.Query("within_days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			RespondWithValidationError(c, "within_days must be a positive number")
+			return
+		}
+		withinDays = parsed
+	}
+
+	schedule, err := h.retention.ListPurgeWindows(c.Request.Context(), c.GetString("organization_id"), withinDays)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, schedule)
+}
+
+// ListExports handles GET /api/admin/log-retention/exports
+func (h *LogRetentionHandler) ListExports(c *gin.Context) {
+	exports, err := h.retention.ListExports(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, exports)
+}
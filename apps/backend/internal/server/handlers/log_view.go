@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogViewManager manages saved log and audit views
+type LogViewManager interface {
+	ListViews(ctx context.Context, orgID, userID, kind string) ([]*types.SavedLogView, error)
+	GetView(ctx context.Context, orgID, userID, id string) (*types.SavedLogView, error)
+	CreateView(ctx context.Context, orgID, userID string, req *types.CreateLogViewRequest) (*types.SavedLogView, error)
+	UpdateView(ctx context.Context, orgID, userID, id string, req *types.UpdateLogViewRequest) (*types.SavedLogView, error)
+	DeleteView(ctx context.Context, orgID, userID, id string) error
+	RunView(ctx context.Context, orgID, userID, id string, limit, offset int) (*services.LogViewResults, error)
+	Widgets(ctx context.Context, orgID, userID, id string) ([]*types.LogWidgetResult, error)
+}
+
+// LogViewHandler handles saved log views and their dashboard widgets
+type LogViewHandler struct {
+	views LogViewManager
+}
+
+// NewLogViewHandler creates a new log view handler
+func NewLogViewHandler(views LogViewManager) *LogViewHandler {
+	return &LogViewHandler{views: views}
+}
+
+// ListViews handles GET /api/admin/log-views
+func (h *LogViewHandler) ListViews(c *gin.Context) {
+	views, err := h.views.ListViews(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), c.Query("kind"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, views)
+}
+
+// GetView handles GET /api/admin/log-views/:id
+func (h *LogViewHandler) GetView(c *gin.Context) {
+	view, err := h.views.GetView(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, view)
+}
+
+// CreateView handles POST /api/admin/log-views
+func (h *LogViewHandler) CreateView(c *gin.Context) {
+	var req types.CreateLogViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	view, err := h.views.CreateView(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, view)
+}
+
+// UpdateView handles PUT /api/admin/log-views/:id
+func (h *LogViewHandler) UpdateView(c *gin.Context) {
+	var req types.UpdateLogViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	view, err := h.views.UpdateView(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, view)
+}
+
+// DeleteView handles DELETE /api/admin/log-views/:id
+func (h *LogViewHandler) DeleteView(c *gin.Context) {
+	if err := h.views.DeleteView(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Saved log view deleted"})
+}
+
+// RunView handles GET /api/admin/log-views/:id/results
+func (h *LogViewHandler) RunView(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	results, err := h.views.RunView(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), c.Param("id"), limit, offset)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, results)
+}
+
+// GetWidgets handles GET /api/admin/log-views/:id/widgets, computing the
+// view's pinned widgets on demand
+func (h *LogViewHandler) GetWidgets(c *gin.Context) {
+	widgets, err := h.views.Widgets(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, widgets)
+}
@@ -49,10 +49,7 @@ func (h *MCPDiscoveryHandler) SearchPackages(c *gin.Context) {
 
 	result, err := h.discoveryService.SearchPackages(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error:   types.NewInternalError("Failed to search MCP packages: " + err.Error()),
-			Success: false,
-		})
+		respondWithDiscoveryError(c, "Failed to search MCP packages: ", err)
 		return
 	}
 
@@ -83,10 +80,7 @@ func (h *MCPDiscoveryHandler) ListPackages(c *gin.Context) {
 
 	result, err := h.discoveryService.ListAllPackages(offset, pageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error:   types.NewInternalError("Failed to list MCP packages: " + err.Error()),
-			Success: false,
-		})
+		respondWithDiscoveryError(c, "Failed to list MCP packages: ", err)
 		return
 	}
 
@@ -119,10 +113,7 @@ func (h *MCPDiscoveryHandler) GetPackageDetails(c *gin.Context) {
 
 	result, err := h.discoveryService.SearchPackages(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
-			Error:   types.NewInternalError("Failed to get package details: " + err.Error()),
-			Success: false,
-		})
+		respondWithDiscoveryError(c, "Failed to get package details: ", err)
 		return
 	}
 
@@ -143,3 +134,16 @@ func (h *MCPDiscoveryHandler) GetPackageDetails(c *gin.Context) {
 		Success: false,
 	})
 }
+
+// respondWithDiscoveryError passes structured errors such as offline mode
+// rejections through and wraps anything else as an internal error
+func respondWithDiscoveryError(c *gin.Context, message string, err error) {
+	if _, ok := err.(*types.Error); ok {
+		RespondWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+		Error:   types.NewInternalError(message + err.Error()),
+		Success: false,
+	})
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MCPMessageLogService defines the interface for querying MCP message logs
+type MCPMessageLogService interface {
+	ListMessages(ctx context.Context, orgID string, query *types.MCPMessageLogQuery) ([]*types.MCPMessageLog, error)
+	GetMessage(ctx context.Context, orgID, id string) (*types.MCPMessageLog, error)
+}
+
+// MCPMessageLogHandler serves the message-level MCP traffic log
+type MCPMessageLogHandler struct {
+	service MCPMessageLogService
+}
+
+// NewMCPMessageLogHandler creates a new MCP message log handler
+func NewMCPMessageLogHandler(service MCPMessageLogService) *MCPMessageLogHandler {
+	return &MCPMessageLogHandler{
+		service: service,
+	}
+}
+
+// ListMessages handles GET /api/admin/mcp-messages
+func (h *MCPMessageLogHandler) ListMessages(c *gin.Context) {
+	var query types.MCPMessageLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		RespondWithValidationError(c, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	orgID := c.GetString("organization_id")
+	messages, err := h.service.ListMessages(c.Request.Context(), orgID, &query)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, gin.H{
+		"messages": messages,
+		"count":    len(messages),
+		"limit":    query.Limit,
+		"offset":   query.Offset,
+	})
+}
+
+// GetMessage handles GET /api/admin/mcp-messages/:id
+func (h *MCPMessageLogHandler) GetMessage(c *gin.Context) {
+	orgID := c.GetString("organization_id")
+	message, err := h.service.GetMessage(c.Request.Context(), orgID, c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, message)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MockToolManager manages the canned tools of mock MCP servers
+type MockToolManager interface {
+	ListMockTools(ctx context.Context, orgID, serverID string) ([]*types.MockTool, error)
+	GetMockTool(ctx context.Context, orgID, serverID, id string) (*types.MockTool, error)
+	CreateMockTool(ctx context.Context, orgID, serverID string, req *types.CreateMockToolRequest) (*types.MockTool, error)
+	UpdateMockTool(ctx context.Context, orgID, serverID, id string, req *types.UpdateMockToolRequest) (*types.MockTool, error)
+	DeleteMockTool(ctx context.Context, orgID, serverID, id string) error
+}
+
+// MockServerHandler handles the canned tools of mock MCP servers
+type MockServerHandler struct {
+	tools MockToolManager
+}
+
+// NewMockServerHandler creates a new mock server handler
+func NewMockServerHandler(tools MockToolManager) *MockServerHandler {
+	return &MockServerHandler{tools: tools}
+}
+
+// ListTools handles GET /api/gateway/servers/:id/mock-tools
+func (h *MockServerHandler) ListTools(c *gin.Context) {
+	tools, err := h.tools.ListMockTools(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, tools)
+}
+
+// GetTool handles GET /api/gateway/servers/:id/mock-tools/:tool_id
+func (h *MockServerHandler) GetTool(c *gin.Context) {
+	tool, err := h.tools.GetMockTool(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.Param("tool_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, tool)
+}
+
+// CreateTool handles POST /api/gateway/servers/:id/mock-tools
+func (h *MockServerHandler) CreateTool(c *gin.Context) {
+	var req types.CreateMockToolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	tool, err := h.tools.CreateMockTool(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, tool)
+}
+
+// UpdateTool handles PUT /api/gateway/servers/:id/mock-tools/:tool_id
+func (h *MockServerHandler) UpdateTool(c *gin.Context) {
+	var req types.UpdateMockToolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	tool, err := h.tools.UpdateMockTool(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.Param("tool_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, tool)
+}
+
+// DeleteTool handles DELETE /api/gateway/servers/:id/mock-tools/:tool_id
+func (h *MockServerHandler) DeleteTool(c *gin.Context) {
+	if err := h.tools.DeleteMockTool(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.Param("tool_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Mock tool deleted"})
+}
@@ -2,7 +2,11 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
@@ -21,12 +25,27 @@ type NamespaceService interface {
 	UpdateServerStatus(ctx context.Context, namespaceID, serverID string, req types.UpdateServerStatusRequest) error
 	AggregateTools(ctx context.Context, namespaceID string) ([]types.NamespaceTool, error)
 	UpdateToolStatus(ctx context.Context, namespaceID, serverID, toolName string, req types.UpdateToolStatusRequest) error
+	UpdateToolArguments(ctx context.Context, namespaceID, serverID, toolName string, req types.UpdateToolArgumentsRequest) error
+	UpdateToolResponsePolicy(ctx context.Context, namespaceID, serverID, toolName string, req types.UpdateToolResponsePolicyRequest) error
 	ExecuteTool(ctx context.Context, namespaceID string, req types.ExecuteNamespaceToolRequest) (*types.NamespaceToolResult, error)
 }
 
+// ToolStreamer runs tool calls whose progress is streamed and keeps them
+// for polling
+type ToolStreamer interface {
+	Start(ctx context.Context, orgID, namespaceID string, req types.ExecuteNamespaceToolRequest) (*types.ToolExecutionJob, <-chan types.ToolProgress)
+	Get(ctx context.Context, orgID, namespaceID, jobID string) (*types.ToolExecutionJob, error)
+}
+
+// toolStreamHeartbeat is how often a quiet tool stream sends a comment so
+// that proxies and the request timeout keep it open
+const toolStreamHeartbeat = 15 * time.Second
+
 // NamespaceHandler handles namespace-related HTTP requests
 type NamespaceHandler struct {
 	service NamespaceService
+	access  NamespaceAccessFilter
+	streams ToolStreamer
 }
 
 // NewNamespaceHandler creates a new namespace handler
@@ -36,6 +55,17 @@ func NewNamespaceHandler(service NamespaceService) *NamespaceHandler {
 	}
 }
 
+// SetNamespaceAccess hides namespaces the caller was not granted access to
+// from listings
+func (h *NamespaceHandler) SetNamespaceAccess(access NamespaceAccessFilter) {
+	h.access = access
+}
+
+// SetToolStreams lets tool executions stream their progress over SSE
+func (h *NamespaceHandler) SetToolStreams(streams ToolStreamer) {
+	h.streams = streams
+}
+
 // CreateNamespace handles POST /api/namespaces
 func (h *NamespaceHandler) CreateNamespace(c *gin.Context) {
 	var req types.CreateNamespaceRequest
@@ -83,6 +113,22 @@ func (h *NamespaceHandler) ListNamespaces(c *gin.Context) {
 		return
 	}
 
+	if h.access != nil {
+		subject := namespaceSubject(c)
+		visible := make([]*types.Namespace, 0, len(namespaces))
+		for _, namespace := range namespaces {
+			allowed, err := h.access.Allowed(c.Request.Context(), subject, namespace.ID, types.NamespaceAccessRead)
+			if err != nil {
+				RespondWithError(c, err)
+				return
+			}
+			if allowed {
+				visible = append(visible, namespace)
+			}
+		}
+		namespaces = visible
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"namespaces": namespaces,
 		"total":      len(namespaces),
@@ -254,6 +300,56 @@ func (h *NamespaceHandler) UpdateToolStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "tool status updated"})
 }
 
+// UpdateToolArguments handles PUT /api/namespaces/:id/tools/:tool_id/arguments
+func (h *NamespaceHandler) UpdateToolArguments(c *gin.Context) {
+	namespaceID := c.Param("id")
+	serverID := c.Query("server_id")
+	toolName := c.Param("tool_id")
+
+	if namespaceID == "" || serverID == "" || toolName == "" {
+		RespondWithValidationError(c, "namespace ID, server ID, and tool name are required")
+		return
+	}
+
+	var req types.UpdateToolArgumentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format")
+		return
+	}
+
+	if err := h.service.UpdateToolArguments(c.Request.Context(), namespaceID, serverID, toolName, req); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tool arguments updated"})
+}
+
+// UpdateToolResponsePolicy handles PUT /api/namespaces/:id/tools/:tool_id/response-policy
+func (h *NamespaceHandler) UpdateToolResponsePolicy(c *gin.Context) {
+	namespaceID := c.Param("id")
+	serverID := c.Query("server_id")
+	toolName := c.Param("tool_id")
+
+	if namespaceID == "" || serverID == "" || toolName == "" {
+		RespondWithValidationError(c, "namespace ID, server ID, and tool name are required")
+		return
+	}
+
+	var req types.UpdateToolResponsePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format")
+		return
+	}
+
+	if err := h.service.UpdateToolResponsePolicy(c.Request.Context(), namespaceID, serverID, toolName, req); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tool response policy updated"})
+}
+
 // ExecuteNamespaceTool handles POST /api/namespaces/:id/execute
 func (h *NamespaceHandler) ExecuteNamespaceTool(c *gin.Context) {
 	namespaceID := c.Param("id")
@@ -267,6 +363,14 @@ func (h *NamespaceHandler) ExecuteNamespaceTool(c *gin.Context) {
 		RespondWithValidationError(c, "Invalid request format")
 		return
 	}
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = c.GetHeader(IdempotencyKeyHeader)
+	}
+
+	if h.streams != nil && (c.Query("stream") == "true" || strings.Contains(c.GetHeader("Accept"), "text/event-stream")) {
+		h.streamExecution(c, namespaceID, req)
+		return
+	}
 
 	result, err := h.service.ExecuteTool(c.Request.Context(), namespaceID, req)
 	if err != nil {
@@ -276,3 +380,74 @@ func (h *NamespaceHandler) ExecuteNamespaceTool(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// streamExecution runs a tool call as a job and streams its progress over
+// SSE, ending with a result or error event. The job event comes first so a
+// caller whose stream breaks can poll for the result.
+func (h *NamespaceHandler) streamExecution(c *gin.Context, namespaceID string, req types.ExecuteNamespaceToolRequest) {
+	orgID := c.GetString("organization_id")
+	job, progress := h.streams.Start(c.Request.Context(), orgID, namespaceID, req)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	ctx := c.Request.Context()
+	types.ExtendDeadline(ctx, time.Now().Add(2*toolStreamHeartbeat))
+	writeToolStreamEvent(c, types.ToolStreamEventJob, job)
+
+	heartbeat := time.NewTicker(toolStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case p, ok := <-progress:
+			if !ok {
+				h.writeToolStreamOutcome(c, orgID, namespaceID, job.ID)
+				return
+			}
+			writeToolStreamEvent(c, types.ToolStreamEventProgress, p)
+		case <-heartbeat.C:
+			fmt.Fprintf(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		case <-ctx.Done():
+			return
+		}
+		types.ExtendDeadline(ctx, time.Now().Add(2*toolStreamHeartbeat))
+	}
+}
+
+func (h *NamespaceHandler) writeToolStreamOutcome(c *gin.Context, orgID, namespaceID, jobID string) {
+	job, err := h.streams.Get(c.Request.Context(), orgID, namespaceID, jobID)
+	switch {
+	case err != nil:
+		writeToolStreamEvent(c, types.ToolStreamEventError, err)
+	case job.Error != nil:
+		writeToolStreamEvent(c, types.ToolStreamEventError, job.Error)
+	default:
+		writeToolStreamEvent(c, types.ToolStreamEventResult, job.Result)
+	}
+}
+
+func writeToolStreamEvent(c *gin.Context, event string, data interface{}) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, encoded)
+	c.Writer.Flush()
+}
+
+// GetExecution handles GET /api/namespaces/:id/executions/:job_id
+func (h *NamespaceHandler) GetExecution(c *gin.Context) {
+	if h.streams == nil {
+		RespondWithError(c, types.NewNotFoundError("Execution not found"))
+		return
+	}
+	job, err := h.streams.Get(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.Param("job_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, job)
+}
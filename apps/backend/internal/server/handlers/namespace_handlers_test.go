@@ -86,6 +86,16 @@ func (m *MockNamespaceService) UpdateToolStatus(ctx context.Context, namespaceID
 	return args.Error(0)
 }
 
+func (m *MockNamespaceService) UpdateToolArguments(ctx context.Context, namespaceID, serverID, toolName string, req types.UpdateToolArgumentsRequest) error {
+	args := m.Called(ctx, namespaceID, serverID, toolName, req)
+	return args.Error(0)
+}
+
+func (m *MockNamespaceService) UpdateToolResponsePolicy(ctx context.Context, namespaceID, serverID, toolName string, req types.UpdateToolResponsePolicyRequest) error {
+	args := m.Called(ctx, namespaceID, serverID, toolName, req)
+	return args.Error(0)
+}
+
 func (m *MockNamespaceService) ExecuteTool(ctx context.Context, namespaceID string, req types.ExecuteNamespaceToolRequest) (*types.NamespaceToolResult, error) {
 	args := m.Called(ctx, namespaceID, req)
 	if args.Get(0) == nil {
@@ -321,3 +331,65 @@ func TestNamespaceHandler_ExecuteNamespaceTool(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestNamespaceHandler_UpdateToolArguments(t *testing.T) {
+	mockService := new(MockNamespaceService)
+	handler := &NamespaceHandler{service: mockService}
+	router := setupTestRouter()
+	router.PUT("/namespaces/:id/tools/:tool_id/arguments", handler.UpdateToolArguments)
+
+	req := types.UpdateToolArgumentsRequest{
+		Defaults:   map[string]interface{}{"limit": float64(10)},
+		Locked:     map[string]interface{}{"project_id": "proj-1"},
+		OnConflict: types.ArgumentConflictReject,
+	}
+
+	mockService.On("UpdateToolArguments", mock.Anything, "ns-123", "srv-1", "search", req).
+		Return(nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/namespaces/ns-123/tools/search/arguments?server_id=srv-1", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+
+	// The server is required to identify the tool
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("PUT", "/namespaces/ns-123/tools/search/arguments", bytes.NewBuffer(body))
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestNamespaceHandler_UpdateToolResponsePolicy(t *testing.T) {
+	mockService := new(MockNamespaceService)
+	handler := &NamespaceHandler{service: mockService}
+	router := setupTestRouter()
+	router.PUT("/namespaces/:id/tools/:tool_id/response-policy", handler.UpdateToolResponsePolicy)
+
+	req := types.UpdateToolResponsePolicyRequest{
+		Rules: []types.ResponseRule{{Path: "$..api_key", Action: types.ResponseActionMask}},
+	}
+	mockService.On("UpdateToolResponsePolicy", mock.Anything, "ns-123", "srv-1", "search", req).
+		Return(nil)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/namespaces/ns-123/tools/search/response-policy?server_id=srv-1", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+
+	// Unknown actions are rejected before reaching the service
+	body = []byte(`{"rules":[{"path":"$.id","action":"hash"}]}`)
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("PUT", "/namespaces/ns-123/tools/search/response-policy?server_id=srv-1", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NodeManager lists the gateway's nodes and drains them
+type NodeManager interface {
+	List(ctx context.Context) (*types.NodeFleet, error)
+	SetDraining(ctx context.Context, id string, draining bool, requestedBy string) (*types.GatewayNode, error)
+}
+
+// NodeHandler handles the gateway node registry
+type NodeHandler struct {
+	nodes NodeManager
+}
+
+// NewNodeHandler creates a new node handler
+func NewNodeHandler(nodes NodeManager) *NodeHandler {
+	return &NodeHandler{nodes: nodes}
+}
+
+// ListNodes handles GET /api/admin/nodes
+func (h *NodeHandler) ListNodes(c *gin.Context) {
+	fleet, err := h.nodes.List(c.Request.Context())
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, fleet)
+}
+
+// DrainNode handles POST /api/admin/nodes/:id/drain
+func (h *NodeHandler) DrainNode(c *gin.Context) {
+	h.setDraining(c, true)
+}
+
+// UndrainNode handles DELETE /api/admin/nodes/:id/drain
+func (h *NodeHandler) UndrainNode(c *gin.Context) {
+	h.setDraining(c, false)
+}
+
+func (h *NodeHandler) setDraining(c *gin.Context, draining bool) {
+	node, err := h.nodes.SetDraining(c.Request.Context(), c.Param("id"), draining, c.GetString("user_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, node)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationManager defines the notification operations used by the handler
+type NotificationManager interface {
+	List(ctx context.Context, filter *types.NotificationListFilter) (*types.NotificationListResponse, error)
+	UnreadCount(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, userID, id string) error
+	MarkAllRead(ctx context.Context, userID string) (int64, error)
+	GetPreferences(ctx context.Context, userID string) (*types.NotificationPreferences, error)
+	SetPreferences(ctx context.Context, userID string, req *types.UpdateNotificationPreferencesRequest) (*types.NotificationPreferences, error)
+}
+
+// NotificationHandler serves the caller's in-app notifications
+type NotificationHandler struct {
+	notifications NotificationManager
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notifications NotificationManager) *NotificationHandler {
+	return &NotificationHandler{notifications: notifications}
+}
+
+// List handles GET /api/notifications. Supports ?unread=true, limit and offset.
+func (h *NotificationHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+
+	result, err := h.notifications.List(c.Request.Context(), &types.NotificationListFilter{
+		UserID:     c.GetString("user_id"),
+		Limit:      limit,
+		Offset:     offset,
+		UnreadOnly: unreadOnly,
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, result)
+}
+
+// UnreadCount handles GET /api/notifications/unread-count
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	count, err := h.notifications.UnreadCount(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"unread_count": count})
+}
+
+// MarkRead handles POST /api/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.notifications.MarkRead(c.Request.Context(), c.GetString("user_id"), id); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"id": id, "read": true})
+}
+
+// MarkAllRead handles POST /api/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	updated, err := h.notifications.MarkAllRead(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"updated": updated})
+}
+
+// GetPreferences handles GET /api/notifications/preferences
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	prefs, err := h.notifications.GetPreferences(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, prefs)
+}
+
+// UpdatePreferences handles PUT /api/notifications/preferences
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req types.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	prefs, err := h.notifications.SetPreferences(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, prefs)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"net/http"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
@@ -14,6 +15,7 @@ import (
 // OAuthHandler handles OAuth 2.0 endpoints
 type OAuthHandler struct {
 	oauthService *auth.OAuthService
+	endpoints    EndpointURLResolver
 }
 
 // NewOAuthHandler creates a new OAuth handler
@@ -23,18 +25,61 @@ func NewOAuthHandler(oauthService *auth.OAuthService) *OAuthHandler {
 	}
 }
 
+// SetEndpointResolver enables per-endpoint protected resource metadata
+func (h *OAuthHandler) SetEndpointResolver(endpoints EndpointURLResolver) {
+	h.endpoints = endpoints
+}
+
 // DiscoverAuthorizationServer handles .well-known/oauth-authorization-server
 func (h *OAuthHandler) DiscoverAuthorizationServer(c *gin.Context) {
 	metadata := h.oauthService.GetServerMetadata()
 	c.JSON(http.StatusOK, metadata)
 }
 
-// DiscoverProtectedResource handles .well-known/oauth-protected-resource
+// DiscoverProtectedResource handles .well-known/oauth-protected-resource.
+// Paths under /api/public/endpoints/ return RFC 9728 metadata for that
+// endpoint; other paths return the gateway-wide metadata.
 func (h *OAuthHandler) DiscoverProtectedResource(c *gin.Context) {
+	resourcePath := c.Param("path")
+	if name, ok := publicEndpointName(resourcePath); ok && h.endpoints != nil {
+		h.discoverEndpointResource(c, name, resourcePath)
+		return
+	}
+
 	metadata := h.oauthService.GetProtectedResourceMetadata()
 	c.JSON(http.StatusOK, metadata)
 }
 
+func (h *OAuthHandler) discoverEndpointResource(c *gin.Context, name, resourcePath string) {
+	config, err := h.endpoints.ResolveEndpoint(c.Request.Context(), name)
+	if err != nil || config.Endpoint == nil {
+		RespondWithNotFound(c, "Endpoint")
+		return
+	}
+
+	// Only endpoints that accept OAuth tokens are OAuth protected resources
+	endpoint := config.Endpoint
+	if !endpoint.IsActive || !endpoint.EnableOAuth || endpoint.EnablePublicAccess {
+		RespondWithNotFound(c, "Protected resource")
+		return
+	}
+
+	urls := h.endpoints.GenerateURLs(endpoint.Name)
+	metadata := h.oauthService.GetEndpointProtectedResourceMetadata(resourcePath, endpoint.Name, urls.Documentation)
+	c.JSON(http.StatusOK, metadata)
+}
+
+// publicEndpointName extracts the endpoint name from a resource path such as
+// /api/public/endpoints/<name>/mcp
+func publicEndpointName(resourcePath string) (string, bool) {
+	rest, ok := strings.CutPrefix(resourcePath, "/api/public/endpoints/")
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(rest, "/")
+	return name, name != ""
+}
+
 // RegisterClient handles POST /oauth/register
 func (h *OAuthHandler) RegisterClient(c *gin.Context) {
 	var req types.ClientRegistrationRequest
@@ -53,9 +98,29 @@ func (h *OAuthHandler) RegisterClient(c *gin.Context) {
 		orgID = orgIDVal.(string)
 	}
 
+	// An initial access token, when presented, selects the organization
+	initialAccessToken := ""
+	if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		initialAccessToken = strings.TrimPrefix(authHeader, "Bearer ")
+	}
+
 	// Register the client
-	response, err := h.oauthService.RegisterClient(c.Request.Context(), &req, orgID)
+	response, err := h.oauthService.RegisterDynamicClient(c.Request.Context(), &req, orgID, initialAccessToken)
 	if err != nil {
+		var regErr *types.RegistrationError
+		if errors.As(err, &regErr) {
+			status := http.StatusBadRequest
+			if regErr.Code == types.ErrorInvalidToken {
+				status = http.StatusUnauthorized
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			}
+			c.JSON(status, types.OAuthError{
+				Error:            regErr.Code,
+				ErrorDescription: regErr.Description,
+			})
+			return
+		}
+
 		c.JSON(http.StatusBadRequest, types.OAuthError{
 			Error:            types.ErrorInvalidRequest,
 			ErrorDescription: err.Error(),
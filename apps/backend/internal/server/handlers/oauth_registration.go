@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegistrationPolicyManager defines the registration policy operations used
+// by the handler
+type RegistrationPolicyManager interface {
+	GetRegistrationPolicy(ctx context.Context, orgID string) (*types.OAuthRegistrationPolicy, error)
+	SetRegistrationPolicy(ctx context.Context, orgID string, req *types.UpdateOAuthRegistrationPolicyRequest, updatedBy string) (*types.OAuthRegistrationPolicy, error)
+	CreateInitialAccessToken(ctx context.Context, orgID string, req *types.CreateInitialAccessTokenRequest, createdBy string) (*types.CreateInitialAccessTokenResponse, error)
+	ListInitialAccessTokens(ctx context.Context, orgID string) ([]*types.OAuthInitialAccessToken, error)
+	RevokeInitialAccessToken(ctx context.Context, orgID, id string) error
+}
+
+// OAuthRegistrationHandler manages dynamic client registration policy and
+// initial access tokens for the caller's organization
+type OAuthRegistrationHandler struct {
+	policies RegistrationPolicyManager
+	auditor  AuditRecorder
+}
+
+// NewOAuthRegistrationHandler creates a new registration policy handler
+func NewOAuthRegistrationHandler(policies RegistrationPolicyManager, auditor AuditRecorder) *OAuthRegistrationHandler {
+	return &OAuthRegistrationHandler{
+		policies: policies,
+		auditor:  auditor,
+	}
+}
+
+// GetPolicy handles GET /api/admin/oauth/registration-policy. Organizations
+// without a stored policy get an unrestricted one.
+func (h *OAuthRegistrationHandler) GetPolicy(c *gin.Context) {
+	orgID := c.GetString("organization_id")
+	policy, err := h.policies.GetRegistrationPolicy(c.Request.Context(), orgID)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	if policy == nil {
+		policy = &types.OAuthRegistrationPolicy{
+			OrganizationID:         orgID,
+			AllowedGrantTypes:      []string{},
+			RedirectURIPatterns:    []string{},
+			TrustedSoftwareIssuers: []string{},
+			SoftwareStatementKeys:  []string{},
+		}
+	}
+	RespondWithSuccess(c, policy)
+}
+
+// UpdatePolicy handles PUT /api/admin/oauth/registration-policy
+func (h *OAuthRegistrationHandler) UpdatePolicy(c *gin.Context) {
+	var req types.UpdateOAuthRegistrationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	orgID := c.GetString("organization_id")
+	policy, err := h.policies.SetRegistrationPolicy(c.Request.Context(), orgID, &req, c.GetString("user_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	h.audit(c, "update", "oauth_registration_policy", orgID, map[string]interface{}{
+		"require_initial_access_token": req.RequireInitialAccessToken,
+		"require_software_statement":   req.RequireSoftwareStatement,
+		"allowed_grant_types":          req.AllowedGrantTypes,
+		"redirect_uri_patterns":        req.RedirectURIPatterns,
+		"unused_client_ttl_hours":      req.UnusedClientTTLHours,
+	})
+	RespondWithSuccess(c, policy)
+}
+
+// ListInitialAccessTokens handles GET /api/admin/oauth/initial-access-tokens
+func (h *OAuthRegistrationHandler) ListInitialAccessTokens(c *gin.Context) {
+	tokens, err := h.policies.ListInitialAccessTokens(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, tokens)
+}
+
+// CreateInitialAccessToken handles POST /api/admin/oauth/initial-access-tokens
+func (h *OAuthRegistrationHandler) CreateInitialAccessToken(c *gin.Context) {
+	var req types.CreateInitialAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	token, err := h.policies.CreateInitialAccessToken(c.Request.Context(), c.GetString("organization_id"), &req, c.GetString("user_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	h.audit(c, "create", "oauth_initial_access_token", token.ID, map[string]interface{}{
+		"max_uses":         req.MaxUses,
+		"expires_in_hours": req.ExpiresInHours,
+	})
+	RespondWithSuccess(c, token)
+}
+
+// RevokeInitialAccessToken handles DELETE /api/admin/oauth/initial-access-tokens/:id
+func (h *OAuthRegistrationHandler) RevokeInitialAccessToken(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.policies.RevokeInitialAccessToken(c.Request.Context(), c.GetString("organization_id"), id); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	h.audit(c, "revoke", "oauth_initial_access_token", id, nil)
+	RespondWithSuccess(c, gin.H{"id": id, "revoked": true})
+}
+
+func (h *OAuthRegistrationHandler) audit(c *gin.Context, action, resource, resourceID string, details map[string]interface{}) {
+	if h.auditor == nil {
+		return
+	}
+	if err := h.auditor.LogAudit(c.Request.Context(), &types.AuditLog{
+		OrganizationID: c.GetString("organization_id"),
+		UserID:         c.GetString("user_id"),
+		Action:         action,
+		Resource:       resource,
+		ResourceID:     resourceID,
+		RemoteIP:       c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		Success:        true,
+		Details:        details,
+	}); err != nil {
+		log.Printf("Failed to audit OAuth registration change: %v", err)
+	}
+}
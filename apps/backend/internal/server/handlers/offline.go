@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OfflineStatusProvider reports air-gapped operation
+type OfflineStatusProvider interface {
+	Status() *types.OfflineStatus
+}
+
+// OfflineHandler exposes which features work in offline mode
+type OfflineHandler struct {
+	policy OfflineStatusProvider
+}
+
+// NewOfflineHandler creates a new offline mode handler
+func NewOfflineHandler(policy OfflineStatusProvider) *OfflineHandler {
+	return &OfflineHandler{policy: policy}
+}
+
+// GetStatus handles GET /api/admin/offline
+func (h *OfflineHandler) GetStatus(c *gin.Context) {
+	RespondWithSuccess(c, h.policy.Status())
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PlaygroundHistoryManager serves users' playground history and the
+// organization settings governing it
+type PlaygroundHistoryManager interface {
+	ListHistory(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, query *types.PlaygroundHistoryQuery) ([]*types.PlaygroundExecution, error)
+	GetExecution(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, id string) (*types.PlaygroundExecution, error)
+	DeleteExecution(ctx context.Context, orgID, userID, id string) error
+	ClearHistory(ctx context.Context, orgID, userID string) (int64, error)
+	GetSettings(ctx context.Context, orgID string) (*types.PlaygroundHistorySettings, error)
+	UpdateSettings(ctx context.Context, orgID, updatedBy string, req *types.UpdatePlaygroundHistorySettingsRequest) (*types.PlaygroundHistorySettings, error)
+}
+
+// PlaygroundHistoryHandler handles the tool playground execution history
+type PlaygroundHistoryHandler struct {
+	history PlaygroundHistoryManager
+}
+
+// NewPlaygroundHistoryHandler creates a new playground history handler
+func NewPlaygroundHistoryHandler(history PlaygroundHistoryManager) *PlaygroundHistoryHandler {
+	return &PlaygroundHistoryHandler{history: history}
+}
+
+// ListHistory handles GET /api/playground/history. Admins can pass user_id
+// to read a member's history when the organization allows it.
+func (h *PlaygroundHistoryHandler) ListHistory(c *gin.Context) {
+	var query types.PlaygroundHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		RespondWithValidationError(c, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	executions, err := h.history.ListHistory(c.Request.Context(), c.GetString("organization_id"),
+		c.GetString("user_id"), c.GetString("role") == types.RoleAdmin, &query)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, gin.H{
+		"executions": executions,
+		"count":      len(executions),
+		"limit":      query.Limit,
+		"offset":     query.Offset,
+	})
+}
+
+// GetExecution handles GET /api/playground/history/:id
+func (h *PlaygroundHistoryHandler) GetExecution(c *gin.Context) {
+	execution, err := h.history.GetExecution(c.Request.Context(), c.GetString("organization_id"),
+		c.GetString("user_id"), c.GetString("role") == types.RoleAdmin, c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, execution)
+}
+
+// DeleteExecution handles DELETE /api/playground/history/:id
+func (h *PlaygroundHistoryHandler) DeleteExecution(c *gin.Context) {
+	err := h.history.DeleteExecution(c.Request.Context(), c.GetString("organization_id"),
+		c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Execution deleted"})
+}
+
+// ClearHistory handles DELETE /api/playground/history
+func (h *PlaygroundHistoryHandler) ClearHistory(c *gin.Context) {
+	cleared, err := h.history.ClearHistory(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "History cleared", "deleted": cleared})
+}
+
+// GetSettings handles GET /api/admin/playground-history/settings
+func (h *PlaygroundHistoryHandler) GetSettings(c *gin.Context) {
+	settings, err := h.history.GetSettings(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, settings)
+}
+
+// UpdateSettings handles PUT /api/admin/playground-history/settings
+func (h *PlaygroundHistoryHandler) UpdateSettings(c *gin.Context) {
+	var req types.UpdatePlaygroundHistorySettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	settings, err := h.history.UpdateSettings(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, settings)
+}
@@ -1,21 +1,31 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/toolpolicy"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// ToolPolicyTester evaluates hypothetical tool calls against tool policies
+// and picks up policy changes
+type ToolPolicyTester interface {
+	Test(ctx context.Context, orgID string, req *types.TestToolPolicyRequest) (*types.ToolPolicyDecision, error)
+	Invalidate(orgID string)
+}
+
 // PolicyHandler handles policy-related requests
 type PolicyHandler struct {
-	db *sql.DB
+	db           *sql.DB
+	toolPolicies ToolPolicyTester
 }
 
 // NewPolicyHandler creates a new policy handler
@@ -25,6 +35,12 @@ func NewPolicyHandler(db *sql.DB) *PolicyHandler {
 	}
 }
 
+// SetToolPolicies enables the policy test endpoint and refreshes the tool
+// policies applied to calls when they change
+func (h *PolicyHandler) SetToolPolicies(toolPolicies ToolPolicyTester) {
+	h.toolPolicies = toolPolicies
+}
+
 // CreatePolicy handles POST /api/admin/policies
 func (h *PolicyHandler) CreatePolicy(c *gin.Context) {
 	var req types.CreatePolicyRequest
@@ -33,6 +49,13 @@ func (h *PolicyHandler) CreatePolicy(c *gin.Context) {
 		return
 	}
 
+	if req.Type == types.PolicyTypeTool {
+		if err := toolpolicy.Validate(req.Conditions, req.Actions); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tool policy", "details": err.Error()})
+			return
+		}
+	}
+
 	// Get user context from middleware
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -94,6 +117,8 @@ func (h *PolicyHandler) CreatePolicy(c *gin.Context) {
 		return
 	}
 
+	h.toolPoliciesChanged(policy.OrganizationID, policy.Type)
+
 	// Parse back the JSON for response
 	if err = json.Unmarshal(conditionsJSON, &policy.Conditions); err != nil {
 		// Log the error but continue, as this is for response formatting only
@@ -258,8 +283,10 @@ func (h *PolicyHandler) UpdatePolicy(c *gin.Context) {
 
 	// Check if policy exists and belongs to organization
 	var existingPolicy types.Policy
-	checkQuery := "SELECT id FROM policies WHERE id = $1 AND organization_id = $2"
-	err := h.db.QueryRow(checkQuery, policyID, orgID.(string)).Scan(&existingPolicy.ID)
+	var conditionsJSON, actionsJSON []byte
+	checkQuery := "SELECT id, type, conditions, actions FROM policies WHERE id = $1 AND organization_id = $2"
+	err := h.db.QueryRow(checkQuery, policyID, orgID.(string)).Scan(&existingPolicy.ID, &existingPolicy.Type,
+		&conditionsJSON, &actionsJSON)
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Policy not found"})
 		return
@@ -269,6 +296,22 @@ func (h *PolicyHandler) UpdatePolicy(c *gin.Context) {
 		return
 	}
 
+	// Tool policies must still compile with the new conditions and actions
+	if existingPolicy.Type == types.PolicyTypeTool {
+		json.Unmarshal(conditionsJSON, &existingPolicy.Conditions)
+		json.Unmarshal(actionsJSON, &existingPolicy.Actions)
+		if req.Conditions != nil {
+			existingPolicy.Conditions = req.Conditions
+		}
+		if req.Actions != nil {
+			existingPolicy.Actions = req.Actions
+		}
+		if err := toolpolicy.Validate(existingPolicy.Conditions, existingPolicy.Actions); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tool policy", "details": err.Error()})
+			return
+		}
+	}
+
 	// Build update query dynamically
 	updateFields := []string{}
 	args := []interface{}{}
@@ -356,6 +399,8 @@ func (h *PolicyHandler) UpdatePolicy(c *gin.Context) {
 		return
 	}
 
+	h.toolPoliciesChanged(orgID.(string), existingPolicy.Type)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":   "Policy updated successfully",
 		"policy_id": policyID,
@@ -378,8 +423,8 @@ func (h *PolicyHandler) DeletePolicy(c *gin.Context) {
 
 	// Check if policy exists and belongs to organization
 	var existingPolicy types.Policy
-	checkQuery := "SELECT id FROM policies WHERE id = $1 AND organization_id = $2"
-	err := h.db.QueryRow(checkQuery, policyID, orgID.(string)).Scan(&existingPolicy.ID)
+	checkQuery := "SELECT id, type FROM policies WHERE id = $1 AND organization_id = $2"
+	err := h.db.QueryRow(checkQuery, policyID, orgID.(string)).Scan(&existingPolicy.ID, &existingPolicy.Type)
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Policy not found"})
 		return
@@ -397,8 +442,57 @@ func (h *PolicyHandler) DeletePolicy(c *gin.Context) {
 		return
 	}
 
+	h.toolPoliciesChanged(orgID.(string), existingPolicy.Type)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":   "Policy deleted successfully",
 		"policy_id": policyID,
 	})
 }
+
+// TestPolicy handles POST /api/admin/policies/test. It evaluates a tool call
+// against the organization's tool policies and any candidate policies in the
+// request, without running the call, and reports which policy decided it.
+func (h *PolicyHandler) TestPolicy(c *gin.Context) {
+	if h.toolPolicies == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Tool policies are not enabled"})
+		return
+	}
+
+	var req types.TestToolPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	orgID, exists := c.Get("organization_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	// Without an explicit caller the call is evaluated as the admin testing it
+	if req.Caller == nil {
+		req.Caller = types.PrincipalFromContext(c.Request.Context())
+	}
+
+	decision, err := h.toolPolicies.Test(c.Request.Context(), orgID.(string), &req)
+	if err != nil {
+		if e, ok := err.(*types.Error); ok {
+			c.JSON(e.Status, gin.H{"error": e.Message})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to test policies", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"decision": decision})
+}
+
+// toolPoliciesChanged applies a change to the organization's tool policies
+// to the next tool call
+func (h *PolicyHandler) toolPoliciesChanged(orgID, policyType string) {
+	if h.toolPolicies != nil && policyType == types.PolicyTypeTool {
+		h.toolPolicies.Invalidate(orgID)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitRuleManager manages organizations' rate limit rules
+type RateLimitRuleManager interface {
+	ListRules(ctx context.Context, orgID string) ([]*types.RateLimitRule, error)
+	GetRule(ctx context.Context, orgID, id string) (*types.RateLimitRule, error)
+	CreateRule(ctx context.Context, orgID string, req *types.CreateRateLimitRuleRequest) (*types.RateLimitRule, error)
+	UpdateRule(ctx context.Context, orgID, id string, req *types.UpdateRateLimitRuleRequest) (*types.RateLimitRule, error)
+	DeleteRule(ctx context.Context, orgID, id string) error
+}
+
+// RateLimitRuleHandler handles the rate limit rules of organizations
+type RateLimitRuleHandler struct {
+	rules RateLimitRuleManager
+}
+
+// NewRateLimitRuleHandler creates a new rate limit rule handler
+func NewRateLimitRuleHandler(rules RateLimitRuleManager) *RateLimitRuleHandler {
+	return &RateLimitRuleHandler{rules: rules}
+}
+
+// ListRules handles GET /api/admin/rate-limits
+func (h *RateLimitRuleHandler) ListRules(c *gin.Context) {
+	rules, err := h.rules.ListRules(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, rules)
+}
+
+// GetRule handles GET /api/admin/rate-limits/:id
+func (h *RateLimitRuleHandler) GetRule(c *gin.Context) {
+	rule, err := h.rules.GetRule(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, rule)
+}
+
+// CreateRule handles POST /api/admin/rate-limits
+func (h *RateLimitRuleHandler) CreateRule(c *gin.Context) {
+	var req types.CreateRateLimitRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	rule, err := h.rules.CreateRule(c.Request.Context(), c.GetString("organization_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, rule)
+}
+
+// UpdateRule handles PUT /api/admin/rate-limits/:id
+func (h *RateLimitRuleHandler) UpdateRule(c *gin.Context) {
+	var req types.UpdateRateLimitRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	rule, err := h.rules.UpdateRule(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, rule)
+}
+
+// DeleteRule handles DELETE /api/admin/rate-limits/:id
+func (h *RateLimitRuleHandler) DeleteRule(c *gin.Context) {
+	if err := h.rules.DeleteRule(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Rate limit rule deleted"})
+}
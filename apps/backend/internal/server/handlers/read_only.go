@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadOnlySwitch is the runtime read-only toggle used by the handler
+type ReadOnlySwitch interface {
+	Status() types.ReadOnlyStatus
+	Set(enabled bool, reason, changedBy string) (types.ReadOnlyStatus, error)
+}
+
+// AuditRecorder records administrative actions
+type AuditRecorder interface {
+	LogAudit(ctx context.Context, event *types.AuditLog) error
+}
+
+// ReadOnlyHandler exposes and toggles gateway read-only mode
+type ReadOnlyHandler struct {
+	mode    ReadOnlySwitch
+	auditor AuditRecorder
+}
+
+// NewReadOnlyHandler creates a new read-only mode handler
+func NewReadOnlyHandler(mode ReadOnlySwitch, auditor AuditRecorder) *ReadOnlyHandler {
+	return &ReadOnlyHandler{
+		mode:    mode,
+		auditor: auditor,
+	}
+}
+
+// GetStatus handles GET /api/admin/read-only
+func (h *ReadOnlyHandler) GetStatus(c *gin.Context) {
+	RespondWithSuccess(c, h.mode.Status())
+}
+
+// UpdateStatus handles PUT /api/admin/read-only
+func (h *ReadOnlyHandler) UpdateStatus(c *gin.Context) {
+	var req types.UpdateReadOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	userID := c.GetString("user_id")
+	status, err := h.mode.Set(*req.Enabled, req.Reason, userID)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	log.Printf("Read-only mode set to %t by user %s: %s", status.Enabled, userID, req.Reason)
+
+	if h.auditor != nil {
+		if err := h.auditor.LogAudit(c.Request.Context(), &types.AuditLog{
+			OrganizationID: c.GetString("organization_id"),
+			UserID:         userID,
+			Action:         "update",
+			Resource:       "read_only_mode",
+			RemoteIP:       c.ClientIP(),
+			UserAgent:      c.Request.UserAgent(),
+			Success:        true,
+			Severity:       types.AuditSeverityWarning,
+			Details: map[string]interface{}{
+				"enabled": status.Enabled,
+				"reason":  req.Reason,
+			},
+		}); err != nil {
+			log.Printf("Failed to audit read-only mode change: %v", err)
+		}
+	}
+
+	RespondWithSuccess(c, status)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegionManager replicates the catalog between the regions of a deployment
+type RegionManager interface {
+	Authorize(token string) bool
+	Changes(ctx context.Context, after int64, limit int) (*types.CatalogChangeBatch, error)
+	Sync(ctx context.Context) (int, error)
+	Status(ctx context.Context) (*types.RegionStatus, error)
+	DNSGuidance(ctx context.Context, orgID string) (*types.RegionDNSGuidance, error)
+}
+
+// RegionHandler handles multi-region replication
+type RegionHandler struct {
+	regions RegionManager
+}
+
+// NewRegionHandler creates a new region handler
+func NewRegionHandler(regions RegionManager) *RegionHandler {
+	return &RegionHandler{regions: regions}
+}
+
+// ListChanges handles GET /api/replication/changes. Peer regions call it
+// with the shared replication token rather than a user session.
+func (h *RegionHandler) ListChanges(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !h.regions.Authorize(token) {
+		RespondWithError(c, types.NewUnauthorizedError("Invalid replication token"))
+		return
+	}
+
+	after, err := strconv.ParseInt(c.DefaultQuery("after", "0"), 10, 64)
+	if err != nil {
+		RespondWithValidationError(c, "Invalid after parameter")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		RespondWithValidationError(c, "Invalid limit parameter")
+		return
+	}
+
+	batch, err := h.regions.Changes(c.Request.Context(), after, limit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, batch)
+}
+
+// GetStatus handles GET /api/admin/regions
+func (h *RegionHandler) GetStatus(c *gin.Context) {
+	status, err := h.regions.Status(c.Request.Context())
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, status)
+}
+
+// Sync handles POST /api/admin/regions/sync. It pulls from every peer now
+// rather than waiting for the worker; a failing peer is reported in its
+// status instead of failing the request.
+func (h *RegionHandler) Sync(c *gin.Context) {
+	_, _ = h.regions.Sync(c.Request.Context())
+
+	status, err := h.regions.Status(c.Request.Context())
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, status)
+}
+
+// GetDNSGuidance handles GET /api/admin/regions/dns
+func (h *RegionHandler) GetDNSGuidance(c *gin.Context) {
+	guidance, err := h.regions.DNSGuidance(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, guidance)
+}
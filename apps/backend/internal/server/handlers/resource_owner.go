@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResourceOwnerManager records and transfers resource ownership
+type ResourceOwnerManager interface {
+	GetOwner(ctx context.Context, orgID, resourceType, resourceID string) (*types.ResourceOwner, error)
+	SetOwner(ctx context.Context, orgID, resourceType, resourceID, assignedBy string, req *types.SetResourceOwnerRequest) (*types.ResourceOwner, error)
+	RemoveOwner(ctx context.Context, orgID, resourceType, resourceID string) error
+	ListOwners(ctx context.Context, orgID string, filter *types.ResourceOwnerFilter) ([]*types.ResourceOwner, error)
+	Transfer(ctx context.Context, orgID, assignedBy string, req *types.TransferOwnershipRequest) (*types.TransferOwnershipResult, error)
+}
+
+// ResourceOwnerHandler handles resource ownership
+type ResourceOwnerHandler struct {
+	owners ResourceOwnerManager
+}
+
+// NewResourceOwnerHandler creates a new resource owner handler
+func NewResourceOwnerHandler(owners ResourceOwnerManager) *ResourceOwnerHandler {
+	return &ResourceOwnerHandler{owners: owners}
+}
+
+// ListOwners handles GET /api/ownership
+func (h *ResourceOwnerHandler) ListOwners(c *gin.Context) {
+	var filter types.ResourceOwnerFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		RespondWithValidationError(c, "Invalid query: "+err.Error())
+		return
+	}
+	h.list(c, &filter)
+}
+
+// ListOrphaned handles GET /api/ownership/orphaned
+func (h *ResourceOwnerHandler) ListOrphaned(c *gin.Context) {
+	h.list(c, &types.ResourceOwnerFilter{ResourceType: c.Query("resource_type"), OrphanedOnly: true})
+}
+
+func (h *ResourceOwnerHandler) list(c *gin.Context, filter *types.ResourceOwnerFilter) {
+	owners, err := h.owners.ListOwners(c.Request.Context(), c.GetString("organization_id"), filter)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, owners)
+}
+
+// GetOwner handles GET /api/ownership/:resource_type/:resource_id
+func (h *ResourceOwnerHandler) GetOwner(c *gin.Context) {
+	owner, err := h.owners.GetOwner(c.Request.Context(), c.GetString("organization_id"), c.Param("resource_type"), c.Param("resource_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, owner)
+}
+
+// SetOwner handles PUT /api/ownership/:resource_type/:resource_id
+func (h *ResourceOwnerHandler) SetOwner(c *gin.Context) {
+	var req types.SetResourceOwnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	owner, err := h.owners.SetOwner(c.Request.Context(), c.GetString("organization_id"),
+		c.Param("resource_type"), c.Param("resource_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, owner)
+}
+
+// RemoveOwner handles DELETE /api/ownership/:resource_type/:resource_id
+func (h *ResourceOwnerHandler) RemoveOwner(c *gin.Context) {
+	if err := h.owners.RemoveOwner(c.Request.Context(), c.GetString("organization_id"), c.Param("resource_type"), c.Param("resource_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Resource owner removed"})
+}
+
+// Transfer handles POST /api/ownership/transfer
+func (h *ResourceOwnerHandler) Transfer(c *gin.Context) {
+	var req types.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	result, err := h.owners.Transfer(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, result)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleGrantManager manages temporary role grants
+type RoleGrantManager interface {
+	ListRoleGrants(ctx context.Context, orgID string, filter *types.RoleGrantFilter) ([]*types.RoleGrant, error)
+	GrantRole(ctx context.Context, orgID, grantedBy string, req *types.CreateRoleGrantRequest) (*types.RoleGrant, error)
+	RevokeRole(ctx context.Context, orgID, grantID, revokedBy string) error
+}
+
+// RoleGrantHandler handles temporary role grants
+type RoleGrantHandler struct {
+	grants RoleGrantManager
+}
+
+// NewRoleGrantHandler creates a new role grant handler
+func NewRoleGrantHandler(grants RoleGrantManager) *RoleGrantHandler {
+	return &RoleGrantHandler{grants: grants}
+}
+
+// ListRoleGrants handles GET /api/admin/role-grants
+func (h *RoleGrantHandler) ListRoleGrants(c *gin.Context) {
+	var filter types.RoleGrantFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		RespondWithValidationError(c, "Invalid query: "+err.Error())
+		return
+	}
+
+	grants, err := h.grants.ListRoleGrants(c.Request.Context(), c.GetString("organization_id"), &filter)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, grants)
+}
+
+// CreateRoleGrant handles POST /api/admin/role-grants
+func (h *RoleGrantHandler) CreateRoleGrant(c *gin.Context) {
+	var req types.CreateRoleGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	grant, err := h.grants.GrantRole(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, grant)
+}
+
+// RevokeRoleGrant handles DELETE /api/admin/role-grants/:id
+func (h *RoleGrantHandler) RevokeRoleGrant(c *gin.Context) {
+	if err := h.grants.RevokeRole(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.GetString("user_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Role grant revoked"})
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriorityStatsSource reports per-class admission statistics
+type PriorityStatsSource interface {
+	Stats() []types.PriorityClassStats
+}
+
+// LoadSheddingStatsSource reports the gateway's adaptive concurrency limit
+type LoadSheddingStatsSource interface {
+	Stats() *types.LoadSheddingStats
+}
+
+// SchedulerHandler exposes plan-tier prioritization statistics
+type SchedulerHandler struct {
+	executions   PriorityStatsSource
+	connections  PriorityStatsSource
+	loadShedding LoadSheddingStatsSource
+}
+
+// NewSchedulerHandler creates a new scheduler handler
+func NewSchedulerHandler(executions, connections PriorityStatsSource) *SchedulerHandler {
+	return &SchedulerHandler{
+		executions:  executions,
+		connections: connections,
+	}
+}
+
+// SetLoadShedding adds the load shedding limit to the statistics
+func (h *SchedulerHandler) SetLoadShedding(source LoadSheddingStatsSource) {
+	h.loadShedding = source
+}
+
+// GetStats handles GET /api/admin/scheduler
+func (h *SchedulerHandler) GetStats(c *gin.Context) {
+	stats := &types.SchedulerStats{
+		ToolExecutions:       h.executions.Stats(),
+		TransportConnections: h.connections.Stats(),
+	}
+	if h.loadShedding != nil {
+		stats.LoadShedding = h.loadShedding.Stats()
+	}
+	RespondWithSuccess(c, stats)
+}
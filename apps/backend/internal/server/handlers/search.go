@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CatalogSearcher searches an organization's tools, prompts and resources
+type CatalogSearcher interface {
+	Search(ctx context.Context, orgID string, query *types.SearchQuery) (*types.SearchResponse, error)
+}
+
+// SearchHandler handles unified catalog search
+type SearchHandler struct {
+	search CatalogSearcher
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(search CatalogSearcher) *SearchHandler {
+	return &SearchHandler{search: search}
+}
+
+// Search handles GET /api/search?q=&types=&category=&tags=&server_id=&namespace_id=&mode=
+func (h *SearchHandler) Search(c *gin.Context) {
+	var query types.SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	response, err := h.search.Search(c.Request.Context(), c.GetString("organization_id"), &query)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, response)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityPostureService defines the interface for generating posture reports
+type SecurityPostureService interface {
+	GenerateReport(ctx context.Context, orgID string, maxKeyAge time.Duration) (*types.SecurityPostureReport, error)
+}
+
+// SecurityPostureHandler serves organization security posture reports
+type SecurityPostureHandler struct {
+	service SecurityPostureService
+}
+
+// NewSecurityPostureHandler creates a new security posture handler
+func NewSecurityPostureHandler(service SecurityPostureService) *SecurityPostureHandler {
+	return &SecurityPostureHandler{
+		service: service,
+	}
+}
+
+// GetPostureReport handles GET /api/admin/security/posture
+func (h *SecurityPostureHandler) GetPostureReport(c *gin.Context) {
+	var maxKeyAge time.Duration
+	if days := c.Query("max_key_age_days"); days != "" {
+		parsed, err := strconv.Atoi(days)
+		if err != nil || parsed <= 0 {
+			RespondWithValidationError(c, "max_key_age_days must be a positive integer")
+			return
+		}
+		maxKeyAge = time.Duration(parsed) * 24 * time.Hour
+	}
+
+	report, err := h.service.GenerateReport(c.Request.Context(), c.GetString("organization_id"), maxKeyAge)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, report)
+}
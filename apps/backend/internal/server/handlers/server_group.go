@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServerGroupManager manages groups of replicas of the same MCP server
+type ServerGroupManager interface {
+	ListGroups(ctx context.Context, orgID string) ([]*types.ServerGroup, error)
+	GetGroup(ctx context.Context, orgID, id string) (*types.ServerGroup, error)
+	CreateGroup(ctx context.Context, orgID string, req *types.CreateServerGroupRequest) (*types.ServerGroup, error)
+	UpdateGroup(ctx context.Context, orgID, id string, req *types.UpdateServerGroupRequest) (*types.ServerGroup, error)
+	DeleteGroup(ctx context.Context, orgID, id string) error
+	SetMember(ctx context.Context, orgID, groupID string, req *types.AddServerGroupMemberRequest) (*types.ServerGroup, error)
+	RemoveMember(ctx context.Context, orgID, groupID, serverID string) (*types.ServerGroup, error)
+}
+
+// ServerGroupHandler handles server groups
+type ServerGroupHandler struct {
+	groups ServerGroupManager
+}
+
+// NewServerGroupHandler creates a new server group handler
+func NewServerGroupHandler(groups ServerGroupManager) *ServerGroupHandler {
+	return &ServerGroupHandler{groups: groups}
+}
+
+// ListGroups handles GET /api/gateway/server-groups
+func (h *ServerGroupHandler) ListGroups(c *gin.Context) {
+	groups, err := h.groups.ListGroups(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, groups)
+}
+
+// GetGroup handles GET /api/gateway/server-groups/:id
+func (h *ServerGroupHandler) GetGroup(c *gin.Context) {
+	group, err := h.groups.GetGroup(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, group)
+}
+
+// CreateGroup handles POST /api/gateway/server-groups
+func (h *ServerGroupHandler) CreateGroup(c *gin.Context) {
+	var req types.CreateServerGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	group, err := h.groups.CreateGroup(c.Request.Context(), c.GetString("organization_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, group)
+}
+
+// UpdateGroup handles PUT /api/gateway/server-groups/:id
+func (h *ServerGroupHandler) UpdateGroup(c *gin.Context) {
+	var req types.UpdateServerGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	group, err := h.groups.UpdateGroup(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, group)
+}
+
+// DeleteGroup handles DELETE /api/gateway/server-groups/:id
+func (h *ServerGroupHandler) DeleteGroup(c *gin.Context) {
+	if err := h.groups.DeleteGroup(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Server group deleted"})
+}
+
+// SetMember handles PUT /api/gateway/server-groups/:id/members
+func (h *ServerGroupHandler) SetMember(c *gin.Context) {
+	var req types.AddServerGroupMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	group, err := h.groups.SetMember(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, group)
+}
+
+// RemoveMember handles DELETE /api/gateway/server-groups/:id/members/:server_id
+func (h *ServerGroupHandler) RemoveMember(c *gin.Context) {
+	group, err := h.groups.RemoveMember(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.Param("server_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, group)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ServerLogSource serves captured upstream server output
+type ServerLogSource interface {
+	Tail(ctx context.Context, serverID, stream string, n int) ([]types.ServerLogLine, error)
+	Follow(serverID string) (lines <-chan types.ServerLogLine, stop func())
+}
+
+// ServerLogsHandler lets operators read the stdout and stderr of upstream
+// servers the gateway launches without access to the host
+type ServerLogsHandler struct {
+	logs ServerLogSource
+}
+
+// NewServerLogsHandler creates a new server logs handler
+func NewServerLogsHandler(logs ServerLogSource) *ServerLogsHandler {
+	return &ServerLogsHandler{logs: logs}
+}
+
+// GetLogs handles GET /api/gateway/servers/:id/logs. tail sets how many
+// recent lines are returned, stream selects stdout or stderr, and
+// follow=true keeps streaming new lines as server-sent events.
+func (h *ServerLogsHandler) GetLogs(c *gin.Context) {
+	serverID := c.Param("id")
+	if _, err := uuid.Parse(serverID); err != nil {
+		RespondWithValidationError(c, "Invalid server ID")
+		return
+	}
+
+	tail := 100
+	if value := c.Query("tail"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > 5000 {
+			RespondWithValidationError(c, "tail must be between 0 and 5000")
+			return
+		}
+		tail = parsed
+	}
+
+	stream := c.Query("stream")
+	if stream != "" && stream != types.ServerLogStreamStdout && stream != types.ServerLogStreamStderr {
+		RespondWithValidationError(c, "stream must be stdout or stderr")
+		return
+	}
+
+	// Subscribe before reading the tail so no line falls between the two
+	var lines <-chan types.ServerLogLine
+	follow := c.Query("follow") == "true"
+	if follow {
+		var stop func()
+		lines, stop = h.logs.Follow(serverID)
+		defer stop()
+	}
+
+	recent := []types.ServerLogLine{}
+	if tail > 0 {
+		var err error
+		if recent, err = h.logs.Tail(c.Request.Context(), serverID, stream, tail); err != nil {
+			RespondWithError(c, err)
+			return
+		}
+	}
+
+	if !follow {
+		RespondWithSuccess(c, recent)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	var sent time.Time
+	for _, line := range recent {
+		writeServerLogEvent(c, line)
+		sent = line.Time
+	}
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case line := <-lines:
+			// Skip other streams and lines already sent with the tail
+			if (stream != "" && line.Stream != stream) || !line.Time.After(sent) {
+				continue
+			}
+			writeServerLogEvent(c, line)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprintf(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func writeServerLogEvent(c *gin.Context, line types.ServerLogLine) {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(c.Writer, "event: log\ndata: %s\n\n", data)
+}
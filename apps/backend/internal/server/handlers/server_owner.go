@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ServerOwnerManager records server owners and the alerts sent to them
+type ServerOwnerManager interface {
+	GetOwner(ctx context.Context, orgID, serverID string) (*types.ServerOwner, error)
+	SetOwner(ctx context.Context, orgID, serverID string, req *types.SetServerOwnerRequest) (*types.ServerOwner, error)
+	DeleteOwner(ctx context.Context, orgID, serverID string) error
+	ListAlerts(ctx context.Context, orgID, serverID string, limit int) ([]*types.ServerOwnerAlert, error)
+	Acknowledge(ctx context.Context, orgID, serverID, alertID, userID string) error
+}
+
+// ServerOwnerHandler manages who is alerted directly about a server
+type ServerOwnerHandler struct {
+	owners ServerOwnerManager
+}
+
+// NewServerOwnerHandler creates a new server owner handler
+func NewServerOwnerHandler(owners ServerOwnerManager) *ServerOwnerHandler {
+	return &ServerOwnerHandler{owners: owners}
+}
+
+// GetOwner handles GET /api/gateway/servers/:id/owner
+func (h *ServerOwnerHandler) GetOwner(c *gin.Context) {
+	serverID, ok := serverIDParam(c)
+	if !ok {
+		return
+	}
+
+	owner, err := h.owners.GetOwner(c.Request.Context(), c.GetString("organization_id"), serverID)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, owner)
+}
+
+// SetOwner handles PUT /api/gateway/servers/:id/owner
+func (h *ServerOwnerHandler) SetOwner(c *gin.Context) {
+	serverID, ok := serverIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req types.SetServerOwnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	owner, err := h.owners.SetOwner(c.Request.Context(), c.GetString("organization_id"), serverID, &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, owner)
+}
+
+// DeleteOwner handles DELETE /api/gateway/servers/:id/owner
+func (h *ServerOwnerHandler) DeleteOwner(c *gin.Context) {
+	serverID, ok := serverIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.owners.DeleteOwner(c.Request.Context(), c.GetString("organization_id"), serverID); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Server owner removed"})
+}
+
+// ListAlerts handles GET /api/gateway/servers/:id/owner/alerts
+func (h *ServerOwnerHandler) ListAlerts(c *gin.Context) {
+	serverID, ok := serverIDParam(c)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			RespondWithValidationError(c, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	alerts, err := h.owners.ListAlerts(c.Request.Context(), c.GetString("organization_id"), serverID, limit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, alerts)
+}
+
+// AcknowledgeAlert handles POST
+// /api/gateway/servers/:id/owner/alerts/:alert_id/acknowledge, which stops
+// the alert from escalating to organization admins
+func (h *ServerOwnerHandler) AcknowledgeAlert(c *gin.Context) {
+	serverID, ok := serverIDParam(c)
+	if !ok {
+		return
+	}
+	alertID := c.Param("alert_id")
+	if _, err := uuid.Parse(alertID); err != nil {
+		RespondWithValidationError(c, "Invalid alert ID")
+		return
+	}
+
+	err := h.owners.Acknowledge(c.Request.Context(), c.GetString("organization_id"), serverID, alertID, c.GetString("user_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Alert acknowledged"})
+}
+
+// serverIDParam returns the :id path parameter, responding with a validation
+// error when it is not a UUID
+func serverIDParam(c *gin.Context) (string, bool) {
+	serverID := c.Param("id")
+	if _, err := uuid.Parse(serverID); err != nil {
+		RespondWithValidationError(c, "Invalid server ID")
+		return "", false
+	}
+	return serverID, true
+}
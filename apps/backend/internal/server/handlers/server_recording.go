@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServerRecordingManager records upstream responses of servers and replays
+// them in place of the servers
+type ServerRecordingManager interface {
+	GetStatus(ctx context.Context, orgID, serverID string) (*types.ServerRecordingStatus, error)
+	SetMode(ctx context.Context, orgID, serverID string, req *types.SetRecordingModeRequest) (*types.ServerRecordingStatus, error)
+	ListRecordings(ctx context.Context, orgID, serverID, method string) ([]*types.ServerRecording, error)
+	ImportRecordings(ctx context.Context, orgID, serverID string, req *types.ImportRecordingsRequest) ([]*types.ServerRecording, error)
+	DeleteRecording(ctx context.Context, orgID, serverID, id string) error
+	ClearRecordings(ctx context.Context, orgID, serverID string) (int, error)
+}
+
+// ServerRecordingHandler handles record-and-replay of upstream servers
+type ServerRecordingHandler struct {
+	recordings ServerRecordingManager
+}
+
+// NewServerRecordingHandler creates a new server recording handler
+func NewServerRecordingHandler(recordings ServerRecordingManager) *ServerRecordingHandler {
+	return &ServerRecordingHandler{recordings: recordings}
+}
+
+// GetStatus handles GET /api/gateway/servers/:id/recording
+func (h *ServerRecordingHandler) GetStatus(c *gin.Context) {
+	status, err := h.recordings.GetStatus(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, status)
+}
+
+// SetMode handles PUT /api/gateway/servers/:id/recording
+func (h *ServerRecordingHandler) SetMode(c *gin.Context) {
+	var req types.SetRecordingModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	status, err := h.recordings.SetMode(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, status)
+}
+
+// ListRecordings handles GET /api/gateway/servers/:id/recordings
+func (h *ServerRecordingHandler) ListRecordings(c *gin.Context) {
+	recordings, err := h.recordings.ListRecordings(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.Query("method"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, recordings)
+}
+
+// ImportRecordings handles POST /api/gateway/servers/:id/recordings
+func (h *ServerRecordingHandler) ImportRecordings(c *gin.Context) {
+	var req types.ImportRecordingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	recordings, err := h.recordings.ImportRecordings(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, recordings)
+}
+
+// DeleteRecording handles DELETE /api/gateway/servers/:id/recordings/:recording_id
+func (h *ServerRecordingHandler) DeleteRecording(c *gin.Context) {
+	if err := h.recordings.DeleteRecording(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.Param("recording_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Recording deleted"})
+}
+
+// ClearRecordings handles DELETE /api/gateway/servers/:id/recordings
+func (h *ServerRecordingHandler) ClearRecordings(c *gin.Context) {
+	cleared, err := h.recordings.ClearRecordings(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"deleted": cleared})
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAccountManager manages service accounts and their credentials
+type ServiceAccountManager interface {
+	List(ctx context.Context, orgID string) ([]*types.ServiceAccount, error)
+	Get(ctx context.Context, orgID, id string) (*types.ServiceAccount, error)
+	Create(ctx context.Context, orgID, createdBy string, req *types.CreateServiceAccountRequest) (*types.ServiceAccount, error)
+	Update(ctx context.Context, orgID, id string, req *types.UpdateServiceAccountRequest) (*types.ServiceAccount, error)
+	Delete(ctx context.Context, orgID, id string) error
+	ListAPIKeys(ctx context.Context, orgID, id string) ([]*types.APIKey, error)
+	CreateAPIKey(ctx context.Context, orgID, id string, req *types.CreateServiceAccountKeyRequest) (*types.CreateAPIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, orgID, id, keyID string) error
+	ListClients(ctx context.Context, orgID, id string) ([]*types.OAuthClient, error)
+	CreateClient(ctx context.Context, orgID, id string, req *types.CreateServiceAccountClientRequest) (*types.ClientRegistrationResponse, error)
+	DeleteClient(ctx context.Context, orgID, id, clientID string) error
+}
+
+// ServiceAccountHandler handles service account management
+type ServiceAccountHandler struct {
+	accounts ServiceAccountManager
+}
+
+// NewServiceAccountHandler creates a new service account handler
+func NewServiceAccountHandler(accounts ServiceAccountManager) *ServiceAccountHandler {
+	return &ServiceAccountHandler{accounts: accounts}
+}
+
+// List handles GET /api/admin/service-accounts
+func (h *ServiceAccountHandler) List(c *gin.Context) {
+	accounts, err := h.accounts.List(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, accounts)
+}
+
+// Get handles GET /api/admin/service-accounts/:id
+func (h *ServiceAccountHandler) Get(c *gin.Context) {
+	account, err := h.accounts.Get(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, account)
+}
+
+// Create handles POST /api/admin/service-accounts
+func (h *ServiceAccountHandler) Create(c *gin.Context) {
+	var req types.CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	account, err := h.accounts.Create(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, account)
+}
+
+// Update handles PUT /api/admin/service-accounts/:id
+func (h *ServiceAccountHandler) Update(c *gin.Context) {
+	var req types.UpdateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	account, err := h.accounts.Update(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, account)
+}
+
+// Delete handles DELETE /api/admin/service-accounts/:id
+func (h *ServiceAccountHandler) Delete(c *gin.Context) {
+	if err := h.accounts.Delete(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Service account deleted"})
+}
+
+// ListAPIKeys handles GET /api/admin/service-accounts/:id/api-keys
+func (h *ServiceAccountHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.accounts.ListAPIKeys(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, keys)
+}
+
+// CreateAPIKey handles POST /api/admin/service-accounts/:id/api-keys
+func (h *ServiceAccountHandler) CreateAPIKey(c *gin.Context) {
+	var req types.CreateServiceAccountKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	key, err := h.accounts.CreateAPIKey(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, key)
+}
+
+// RevokeAPIKey handles DELETE /api/admin/service-accounts/:id/api-keys/:key_id
+func (h *ServiceAccountHandler) RevokeAPIKey(c *gin.Context) {
+	if err := h.accounts.RevokeAPIKey(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.Param("key_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "API key deleted"})
+}
+
+// ListClients handles GET /api/admin/service-accounts/:id/oauth-clients
+func (h *ServiceAccountHandler) ListClients(c *gin.Context) {
+	clients, err := h.accounts.ListClients(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, clients)
+}
+
+// CreateClient handles POST /api/admin/service-accounts/:id/oauth-clients
+func (h *ServiceAccountHandler) CreateClient(c *gin.Context) {
+	var req types.CreateServiceAccountClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	client, err := h.accounts.CreateClient(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, client)
+}
+
+// DeleteClient handles DELETE
+// /api/admin/service-accounts/:id/oauth-clients/:client_id
+func (h *ServiceAccountHandler) DeleteClient(c *gin.Context) {
+	if err := h.accounts.DeleteClient(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.Param("client_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "OAuth client deleted"})
+}
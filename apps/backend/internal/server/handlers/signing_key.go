@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SigningKeyManager manages the keys outbound requests are signed with
+type SigningKeyManager interface {
+	CreateKey(ctx context.Context, orgID, createdBy string, req *types.CreateSigningKeyRequest) (*types.CreateSigningKeyResponse, error)
+	ListKeys(ctx context.Context, orgID, destinationType, destinationID string) ([]*types.SigningKey, error)
+	RevokeKey(ctx context.Context, orgID, id string) error
+}
+
+// SigningKeyHandler handles request signing keys
+type SigningKeyHandler struct {
+	keys SigningKeyManager
+}
+
+// NewSigningKeyHandler creates a new signing key handler
+func NewSigningKeyHandler(keys SigningKeyManager) *SigningKeyHandler {
+	return &SigningKeyHandler{keys: keys}
+}
+
+// ListKeys handles GET /api/admin/signing-keys
+func (h *SigningKeyHandler) ListKeys(c *gin.Context) {
+	keys, err := h.keys.ListKeys(c.Request.Context(), c.GetString("organization_id"),
+		c.Query("destination_type"), c.Query("destination_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, keys)
+}
+
+// CreateKey handles POST /api/admin/signing-keys
+func (h *SigningKeyHandler) CreateKey(c *gin.Context) {
+	var req types.CreateSigningKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	resp, err := h.keys.CreateKey(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, resp)
+}
+
+// RevokeKey handles DELETE /api/admin/signing-keys/:id
+func (h *SigningKeyHandler) RevokeKey(c *gin.Context) {
+	if err := h.keys.RevokeKey(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Signing key revoked"})
+}
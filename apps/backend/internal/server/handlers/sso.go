@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SSOManager manages organizations' identity providers and signs users in
+// through them
+type SSOManager interface {
+	ListProviders(ctx context.Context, orgID string) ([]*types.SSOProvider, error)
+	GetProvider(ctx context.Context, orgID, id string) (*types.SSOProvider, error)
+	CreateProvider(ctx context.Context, orgID, createdBy string, req *types.CreateSSOProviderRequest) (*types.SSOProvider, error)
+	UpdateProvider(ctx context.Context, orgID, id string, req *types.UpdateSSOProviderRequest) (*types.SSOProvider, error)
+	DeleteProvider(ctx context.Context, orgID, id string) error
+	ListLoginProviders(ctx context.Context, orgSlug string) ([]*types.SSOProviderSummary, error)
+	BeginLogin(ctx context.Context, providerID, redirectPath string) (string, error)
+	CompleteLogin(ctx context.Context, state, code string, login *auth.LoginContext) (*types.LoginResponse, string, error)
+}
+
+// SSOHandler handles single sign-on through OIDC identity providers
+type SSOHandler struct {
+	sso         SSOManager
+	redirectURL string
+}
+
+// NewSSOHandler creates a new SSO handler. Signed-in users are sent to
+// redirectURL with their tokens in the URL fragment.
+func NewSSOHandler(sso SSOManager, redirectURL string) *SSOHandler {
+	return &SSOHandler{sso: sso, redirectURL: redirectURL}
+}
+
+// ListLoginProviders handles GET /api/auth/sso/providers?organization=<slug>
+func (h *SSOHandler) ListLoginProviders(c *gin.Context) {
+	slug := c.Query("organization")
+	if slug == "" {
+		RespondWithValidationError(c, "organization is required")
+		return
+	}
+
+	providers, err := h.sso.ListLoginProviders(c.Request.Context(), slug)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, providers)
+}
+
+// Login handles GET /api/auth/sso/:id/login by sending the user to the
+// identity provider
+func (h *SSOHandler) Login(c *gin.Context) {
+	authURL, err := h.sso.BeginLogin(c.Request.Context(), c.Param("id"), c.Query("redirect"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback handles GET /api/auth/sso/callback, where the identity provider
+// sends the user back. The outcome is passed to the frontend in the URL
+// fragment, which browsers do not send to servers or in Referer headers.
+func (h *SSOHandler) Callback(c *gin.Context) {
+	fragment := url.Values{}
+	if idpError := c.Query("error"); idpError != "" {
+		fragment.Set("error", idpError)
+		fragment.Set("error_description", c.Query("error_description"))
+		h.redirect(c, fragment)
+		return
+	}
+
+	response, redirectPath, err := h.sso.CompleteLogin(c.Request.Context(), c.Query("state"), c.Query("code"), &auth.LoginContext{
+		ClientIP:  net.ParseIP(c.ClientIP()),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		fragment.Set("error", "access_denied")
+		if typedErr, ok := err.(*types.Error); ok {
+			fragment.Set("error_description", typedErr.Message)
+		} else {
+			log.Printf("[ERROR] SSO login failed: %v", err)
+			fragment.Set("error_description", "Sign-in failed, please try again")
+		}
+		h.redirect(c, fragment)
+		return
+	}
+
+	fragment.Set("access_token", response.AccessToken)
+	fragment.Set("refresh_token", response.RefreshToken)
+	fragment.Set("token_type", response.TokenType)
+	fragment.Set("expires_in", strconv.FormatInt(response.ExpiresIn, 10))
+	fragment.Set("redirect", redirectPath)
+	h.redirect(c, fragment)
+}
+
+func (h *SSOHandler) redirect(c *gin.Context, fragment url.Values) {
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, h.redirectURL+"#"+fragment.Encode())
+}
+
+// ListProviders handles GET /api/admin/sso/providers
+func (h *SSOHandler) ListProviders(c *gin.Context) {
+	providers, err := h.sso.ListProviders(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, providers)
+}
+
+// GetProvider handles GET /api/admin/sso/providers/:id
+func (h *SSOHandler) GetProvider(c *gin.Context) {
+	provider, err := h.sso.GetProvider(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, provider)
+}
+
+// CreateProvider handles POST /api/admin/sso/providers
+func (h *SSOHandler) CreateProvider(c *gin.Context) {
+	var req types.CreateSSOProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	provider, err := h.sso.CreateProvider(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, provider)
+}
+
+// UpdateProvider handles PUT /api/admin/sso/providers/:id
+func (h *SSOHandler) UpdateProvider(c *gin.Context) {
+	var req types.UpdateSSOProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	provider, err := h.sso.UpdateProvider(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, provider)
+}
+
+// DeleteProvider handles DELETE /api/admin/sso/providers/:id
+func (h *SSOHandler) DeleteProvider(c *gin.Context) {
+	if err := h.sso.DeleteProvider(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "SSO provider deleted"})
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusProvider builds the public status of an endpoint
+type StatusProvider interface {
+	GetStatus(ctx context.Context, endpointName string) (*types.PublicStatus, error)
+}
+
+// StatusPageHandler serves unauthenticated status pages that customers embed
+// in their own status sites. Responses are cacheable by browsers and CDNs for
+// as long as the service caches them.
+type StatusPageHandler struct {
+	statuses StatusProvider
+	limits   types.StatusPageLimits
+}
+
+// NewStatusPageHandler creates a new status page handler
+func NewStatusPageHandler(statuses StatusProvider, limits types.StatusPageLimits) *StatusPageHandler {
+	return &StatusPageHandler{
+		statuses: statuses,
+		limits:   limits,
+	}
+}
+
+// GetStatus handles GET /api/public/status/:endpoint_name
+func (h *StatusPageHandler) GetStatus(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	status, err := h.statuses.GetStatus(c.Request.Context(), c.Param("endpoint_name"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+
+	body, err := json.Marshal(status)
+	if err != nil {
+		RespondWithError(c, types.NewInternalError("Failed to encode status"))
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", h.limits.CacheTTLSeconds))
+	c.Header("ETag", etag)
+	if matchesETag(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+func matchesETag(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TeamManager manages teams and their memberships
+type TeamManager interface {
+	ListTeams(ctx context.Context, orgID string) ([]*types.Team, error)
+	ListUserTeams(ctx context.Context, userID string) ([]*types.Team, error)
+	GetTeam(ctx context.Context, orgID, teamID string) (*types.Team, error)
+	CreateTeam(ctx context.Context, orgID, createdBy string, req *types.CreateTeamRequest) (*types.Team, error)
+	UpdateTeam(ctx context.Context, orgID, teamID string, req *types.UpdateTeamRequest) (*types.Team, error)
+	DeleteTeam(ctx context.Context, orgID, teamID string) error
+	ListMembers(ctx context.Context, orgID, teamID string) ([]*types.TeamMember, error)
+	AddMember(ctx context.Context, orgID, teamID, userID string) error
+	RemoveMember(ctx context.Context, orgID, teamID, userID string) error
+	SyncGroups(ctx context.Context, orgID, userID string, groups []string) (*types.TeamSyncResult, error)
+}
+
+// TeamHandler handles team management
+type TeamHandler struct {
+	teams TeamManager
+}
+
+// NewTeamHandler creates a new team handler
+func NewTeamHandler(teams TeamManager) *TeamHandler {
+	return &TeamHandler{teams: teams}
+}
+
+// ListTeams handles GET /api/admin/teams
+func (h *TeamHandler) ListTeams(c *gin.Context) {
+	teams, err := h.teams.ListTeams(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, teams)
+}
+
+// ListMyTeams handles GET /api/auth/teams
+func (h *TeamHandler) ListMyTeams(c *gin.Context) {
+	teams, err := h.teams.ListUserTeams(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, teams)
+}
+
+// GetTeam handles GET /api/admin/teams/:id
+func (h *TeamHandler) GetTeam(c *gin.Context) {
+	team, err := h.teams.GetTeam(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, team)
+}
+
+// CreateTeam handles POST /api/admin/teams
+func (h *TeamHandler) CreateTeam(c *gin.Context) {
+	var req types.CreateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	team, err := h.teams.CreateTeam(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, team)
+}
+
+// UpdateTeam handles PUT /api/admin/teams/:id
+func (h *TeamHandler) UpdateTeam(c *gin.Context) {
+	var req types.UpdateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	team, err := h.teams.UpdateTeam(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, team)
+}
+
+// DeleteTeam handles DELETE /api/admin/teams/:id
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	if err := h.teams.DeleteTeam(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Team deleted"})
+}
+
+// ListMembers handles GET /api/admin/teams/:id/members
+func (h *TeamHandler) ListMembers(c *gin.Context) {
+	members, err := h.teams.ListMembers(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, members)
+}
+
+// AddMember handles POST /api/admin/teams/:id/members
+func (h *TeamHandler) AddMember(c *gin.Context) {
+	var req types.AddTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	if err := h.teams.AddMember(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), req.UserID); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, gin.H{"message": "Team member added"})
+}
+
+// RemoveMember handles DELETE /api/admin/teams/:id/members/:user_id
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	if err := h.teams.RemoveMember(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.Param("user_id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Team member removed"})
+}
+
+// SyncGroups handles POST /api/admin/teams/sync. Identity provider
+// integrations call it with the groups asserted for a user so that teams
+// mapped to those groups gain or lose the user.
+func (h *TeamHandler) SyncGroups(c *gin.Context) {
+	var req types.SyncTeamGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	result, err := h.teams.SyncGroups(c.Request.Context(), c.GetString("organization_id"), req.UserID, req.Groups)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, result)
+}
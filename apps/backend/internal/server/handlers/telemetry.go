@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TelemetryStatusProvider previews the anonymous telemetry report
+type TelemetryStatusProvider interface {
+	Status(ctx context.Context) (*types.TelemetryStatus, error)
+}
+
+// TelemetryHandler lets admins inspect exactly what telemetry would send
+type TelemetryHandler struct {
+	telemetry TelemetryStatusProvider
+}
+
+// NewTelemetryHandler creates a new telemetry handler
+func NewTelemetryHandler(telemetry TelemetryStatusProvider) *TelemetryHandler {
+	return &TelemetryHandler{telemetry: telemetry}
+}
+
+// GetStatus handles GET /api/admin/telemetry
+func (h *TelemetryHandler) GetStatus(c *gin.Context) {
+	status, err := h.telemetry.Status(c.Request.Context())
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, status)
+}
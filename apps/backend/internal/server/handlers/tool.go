@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"strconv"
@@ -18,12 +19,23 @@ type ToolWithServerInfo struct {
 	ServerName     *string `json:"server_name,omitempty"`
 	ServerProtocol *string `json:"server_protocol,omitempty"`
 	ServerStatus   *string `json:"server_status,omitempty"`
+	// Limits are the tool's rate limit and daily budget with today's usage
+	Limits *types.ToolLimitStatus `json:"limits,omitempty"`
+}
+
+// ToolLimitManager manages the rate limits and daily budgets of tools
+type ToolLimitManager interface {
+	GetLimits(ctx context.Context, orgID, toolID string) (*types.ToolLimitStatus, error)
+	SetLimits(ctx context.Context, orgID, toolID string, req *types.SetToolLimitsRequest) (*types.ToolLimitStatus, error)
+	DeleteLimits(ctx context.Context, orgID, toolID string) error
+	UsageForTools(ctx context.Context, toolIDs []string) (map[string]*types.ToolLimitStatus, error)
 }
 
 // ToolHandler handles MCP tool endpoints
 type ToolHandler struct {
 	toolModel   *models.MCPToolModel
 	serverModel *models.MCPServerModel
+	limits      ToolLimitManager
 }
 
 // NewToolHandler creates a new tool handler
@@ -34,6 +46,12 @@ func NewToolHandler(toolModel *models.MCPToolModel, serverModel *models.MCPServe
 	}
 }
 
+// SetToolLimits makes tool responses include the limits and usage of tools
+// and enables the tool limit endpoints
+func (h *ToolHandler) SetToolLimits(limits ToolLimitManager) {
+	h.limits = limits
+}
+
 // ListTools lists all tools for an organization
 func (h *ToolHandler) ListTools(c *gin.Context) {
 	orgID, exists := c.Get("organization_id")
@@ -116,6 +134,7 @@ func (h *ToolHandler) ListTools(c *gin.Context) {
 		})
 		return
 	}
+	h.attachLimits(c.Request.Context(), enrichedTools)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -320,9 +339,12 @@ func (h *ToolHandler) GetTool(c *gin.Context) {
 		return
 	}
 
+	enriched := []*ToolWithServerInfo{{MCPTool: tool}}
+	h.attachLimits(c.Request.Context(), enriched)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    tool,
+		"data":    enriched[0],
 	})
 }
 
@@ -710,3 +732,57 @@ func (h *ToolHandler) enrichToolsWithServerInfo(tools []*models.MCPTool) ([]*Too
 
 	return enrichedTools, nil
 }
+
+// attachLimits adds the limits and usage of the tools that have limits.
+// Like server information, limits are left out when they cannot be loaded.
+func (h *ToolHandler) attachLimits(ctx context.Context, tools []*ToolWithServerInfo) {
+	if h.limits == nil || len(tools) == 0 {
+		return
+	}
+	ids := make([]string, len(tools))
+	for i, tool := range tools {
+		ids[i] = tool.ID.String()
+	}
+	statuses, err := h.limits.UsageForTools(ctx, ids)
+	if err != nil {
+		return
+	}
+	for _, tool := range tools {
+		tool.Limits = statuses[tool.ID.String()]
+	}
+}
+
+// GetLimits handles GET /api/gateway/tools/:id/limits
+func (h *ToolHandler) GetLimits(c *gin.Context) {
+	status, err := h.limits.GetLimits(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, status)
+}
+
+// SetLimits handles PUT /api/gateway/tools/:id/limits
+func (h *ToolHandler) SetLimits(c *gin.Context) {
+	var req types.SetToolLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	status, err := h.limits.SetLimits(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, status)
+}
+
+// DeleteLimits handles DELETE /api/gateway/tools/:id/limits
+func (h *ToolHandler) DeleteLimits(c *gin.Context) {
+	if err := h.limits.DeleteLimits(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Tool limits removed"})
+}
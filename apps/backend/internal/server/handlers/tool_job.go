@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ToolJobManager queues namespace tool calls as jobs and manages the job
+// settings of tools
+type ToolJobManager interface {
+	Enqueue(ctx context.Context, orgID, namespaceID, userID string, req types.ExecuteNamespaceToolRequest) (*types.ToolJob, error)
+	Get(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, id string) (*types.ToolJob, error)
+	List(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, status string, limit int) ([]*types.ToolJob, error)
+	Cancel(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, id string) (*types.ToolJob, error)
+	GetSettings(ctx context.Context, orgID, toolID string) (*types.ToolJobSettings, error)
+	SetSettings(ctx context.Context, orgID, toolID string, req *types.SetToolJobSettingsRequest) (*types.ToolJobSettings, error)
+	DeleteSettings(ctx context.Context, orgID, toolID string) error
+}
+
+// ToolJobHandler handles queued executions of namespace tools
+type ToolJobHandler struct {
+	jobs ToolJobManager
+}
+
+// NewToolJobHandler creates a new tool job handler
+func NewToolJobHandler(jobs ToolJobManager) *ToolJobHandler {
+	return &ToolJobHandler{jobs: jobs}
+}
+
+// EnqueueJob handles POST /api/namespaces/:id/jobs
+func (h *ToolJobHandler) EnqueueJob(c *gin.Context) {
+	var req types.ExecuteNamespaceToolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = c.GetHeader(IdempotencyKeyHeader)
+	}
+
+	job, err := h.jobs.Enqueue(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.GetString("user_id"), req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	c.Header("Location", "/api/jobs/"+job.ID)
+	RespondWithAccepted(c, job)
+}
+
+// ListJobs handles GET /api/jobs
+func (h *ToolJobHandler) ListJobs(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			RespondWithValidationError(c, "limit must be a number")
+			return
+		}
+		limit = parsed
+	}
+
+	jobs, err := h.jobs.List(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"),
+		c.GetString("role") == types.RoleAdmin, c.Query("status"), limit)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, jobs)
+}
+
+// GetJob handles GET /api/jobs/:id
+func (h *ToolJobHandler) GetJob(c *gin.Context) {
+	job, err := h.jobs.Get(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"),
+		c.GetString("role") == types.RoleAdmin, c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, job)
+}
+
+// CancelJob handles DELETE /api/jobs/:id
+func (h *ToolJobHandler) CancelJob(c *gin.Context) {
+	job, err := h.jobs.Cancel(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"),
+		c.GetString("role") == types.RoleAdmin, c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, job)
+}
+
+// GetSettings handles GET /api/gateway/tools/:id/job-settings
+func (h *ToolJobHandler) GetSettings(c *gin.Context) {
+	settings, err := h.jobs.GetSettings(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, settings)
+}
+
+// SetSettings handles PUT /api/gateway/tools/:id/job-settings
+func (h *ToolJobHandler) SetSettings(c *gin.Context) {
+	var req types.SetToolJobSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	settings, err := h.jobs.SetSettings(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, settings)
+}
+
+// DeleteSettings handles DELETE /api/gateway/tools/:id/job-settings
+func (h *ToolJobHandler) DeleteSettings(c *gin.Context) {
+	if err := h.jobs.DeleteSettings(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Tool job settings removed"})
+}
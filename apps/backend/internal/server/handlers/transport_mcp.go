@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -203,6 +204,12 @@ func (h *MCPHandler) handleStreamableJSONGET(c *gin.Context, transport Streamabl
 	c.JSON(http.StatusOK, response)
 }
 
+// isInvalidStreamableRequest reports whether a send failed because the
+// request itself was malformed
+func isInvalidStreamableRequest(err error) bool {
+	return errors.Is(err, transport.ErrInvalidStreamableRequest)
+}
+
 // handleStreamablePOST handles POST requests for streamable HTTP
 func (h *MCPHandler) handleStreamablePOST(c *gin.Context, transport StreamableTransport, session *types.TransportSession) {
 	// Parse streamable request
@@ -216,6 +223,12 @@ func (h *MCPHandler) handleStreamablePOST(c *gin.Context, transport StreamableTr
 
 	// Send message through transport
 	if err := h.transportManager.SendMessage(c.Request.Context(), session.ID, &streamableReq); err != nil {
+		if isInvalidStreamableRequest(err) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to send message: " + err.Error(),
 		})
@@ -338,11 +351,13 @@ func (h *MCPHandler) HandleMCPCapabilities(c *gin.Context) {
 			string(types.TransportTypeWebSocket),
 			string(types.TransportTypeStreamable),
 			string(types.TransportTypeSTDIO),
+			string(types.TransportTypeGRPC),
 		},
 		"modes": []string{
 			types.StreamableModeJSON,
 			types.StreamableModeSSE,
 		},
+		"websocket_subprotocols": transport.WebSocketSubprotocols,
 	}
 
 	c.JSON(http.StatusOK, capabilities)
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -32,6 +34,90 @@ func NewRPCHandler(transportManager *transport.Manager, discoveryService *discov
 	}
 }
 
+// maxRPCRequestSize bounds the body of a JSON-RPC request
+const maxRPCRequestSize = 4 << 20
+
+// rpcRequest is a JSON-RPC request. ID is a string, a json.Number or nil.
+type rpcRequest struct {
+	Params  interface{} `json:"params,omitempty"`
+	ID      interface{} `json:"id"`
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+}
+
+// idString returns the request ID as the string form MCP messages carry
+func (r *rpcRequest) idString() string {
+	if r.ID == nil {
+		return ""
+	}
+	return fmt.Sprint(r.ID)
+}
+
+// parseRPCRequest decodes and validates a JSON-RPC request body. The request
+// is returned along with the error when it was decoded, so that the error
+// response can echo its ID.
+func parseRPCRequest(body []byte) (*rpcRequest, *types.JSONRPCError) {
+	var raw struct {
+		Params  json.RawMessage `json:"params"`
+		ID      json.RawMessage `json:"id"`
+		JSONRPC string          `json:"jsonrpc"`
+		Method  string          `json:"method"`
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if !json.Valid(trimmed) {
+		return nil, &types.JSONRPCError{Code: -32700, Message: "Parse error", Data: "request body is not valid JSON"}
+	}
+	switch trimmed[0] {
+	case '{':
+	case '[':
+		return nil, &types.JSONRPCError{Code: -32600, Message: "Invalid Request", Data: "Batch requests are not supported"}
+	default:
+		return nil, &types.JSONRPCError{Code: -32600, Message: "Invalid Request", Data: "Request must be a JSON object"}
+	}
+	if err := json.Unmarshal(trimmed, &raw); err != nil {
+		return nil, &types.JSONRPCError{Code: -32600, Message: "Invalid Request", Data: err.Error()}
+	}
+
+	req := &rpcRequest{JSONRPC: raw.JSONRPC, Method: raw.Method}
+
+	// IDs are echoed exactly, so numbers are kept as written rather than
+	// rounded through float64
+	id := bytes.TrimSpace(raw.ID)
+	switch {
+	case len(id) == 0 || bytes.Equal(id, []byte("null")):
+	case id[0] == '"':
+		var s string
+		if err := json.Unmarshal(id, &s); err != nil {
+			return nil, &types.JSONRPCError{Code: -32600, Message: "Invalid Request", Data: err.Error()}
+		}
+		req.ID = s
+	case id[0] == '-' || (id[0] >= '0' && id[0] <= '9'):
+		req.ID = json.Number(id)
+	default:
+		return nil, &types.JSONRPCError{Code: -32600, Message: "Invalid Request", Data: "ID must be a string, number or null"}
+	}
+
+	if req.JSONRPC != "2.0" {
+		return req, &types.JSONRPCError{Code: -32600, Message: "Invalid Request", Data: "JSONRPC version must be 2.0"}
+	}
+	if req.Method == "" {
+		return req, &types.JSONRPCError{Code: -32600, Message: "Invalid Request", Data: "Method is required"}
+	}
+
+	params := bytes.TrimSpace(raw.Params)
+	if len(params) > 0 && !bytes.Equal(params, []byte("null")) {
+		if params[0] != '{' && params[0] != '[' {
+			return req, &types.JSONRPCError{Code: -32600, Message: "Invalid Request", Data: "Params must be an object or array"}
+		}
+		if err := json.Unmarshal(params, &req.Params); err != nil {
+			return req, &types.JSONRPCError{Code: -32600, Message: "Invalid Request", Data: err.Error()}
+		}
+	}
+
+	return req, nil
+}
+
 // HandleJSONRPC handles JSON-RPC requests
 func (h *RPCHandler) HandleJSONRPC(c *gin.Context) {
 	// Get transport context (optional for general RPC calls)
@@ -50,56 +136,41 @@ func (h *RPCHandler) HandleJSONRPC(c *gin.Context) {
 	}
 
 	// Parse JSON-RPC request
-	var rpcRequest struct {
-		Params  interface{} `json:"params,omitempty"`
-		ID      string      `json:"id"`
-		JSONRPC string      `json:"jsonrpc"`
-		Method  string      `json:"method"`
-	}
-
-	if err := c.ShouldBindJSON(&rpcRequest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"jsonrpc": "2.0",
-			"id":      nil,
-			"error": map[string]interface{}{
-				"code":    -32700,
-				"message": "Parse error",
-				"data":    err.Error(),
-			},
-		})
-		return
-	}
-
-	// Validate JSON-RPC format
-	if rpcRequest.JSONRPC != "2.0" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"jsonrpc": "2.0",
-			"id":      rpcRequest.ID,
-			"error": map[string]interface{}{
-				"code":    -32600,
-				"message": "Invalid Request",
-				"data":    "JSONRPC version must be 2.0",
-			},
-		})
-		return
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRPCRequestSize))
+		if err != nil {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"jsonrpc": "2.0",
+				"id":      nil,
+				"error": &types.JSONRPCError{
+					Code:    -32600,
+					Message: "Invalid Request",
+					Data:    fmt.Sprintf("request body exceeds %d bytes", maxRPCRequestSize),
+				},
+			})
+			return
+		}
 	}
 
-	if rpcRequest.Method == "" {
+	rpcRequest, rpcErr := parseRPCRequest(body)
+	if rpcErr != nil {
+		var id interface{}
+		if rpcRequest != nil {
+			id = rpcRequest.ID
+		}
 		c.JSON(http.StatusBadRequest, gin.H{
 			"jsonrpc": "2.0",
-			"id":      rpcRequest.ID,
-			"error": map[string]interface{}{
-				"code":    -32600,
-				"message": "Invalid Request",
-				"data":    "Method is required",
-			},
+			"id":      id,
+			"error":   rpcErr,
 		})
 		return
 	}
 
 	// Create MCP message
 	mcpMessage := &types.MCPMessage{
-		ID:      rpcRequest.ID,
+		ID:      rpcRequest.idString(),
 		Type:    types.MCPMessageTypeRequest,
 		Method:  rpcRequest.Method,
 		Version: "2024-11-05",
@@ -120,7 +191,7 @@ func (h *RPCHandler) HandleJSONRPC(c *gin.Context) {
 	// Check if this is a server-specific request that should be routed to an MCP server
 	if transportCtx.ServerID != "" && transportCtx.ServerID != "default-server" {
 		// Route to MCP server via STDIO transport
-		result, err := h.routeToMCPServer(c.Request.Context(), transportCtx.ServerID, &rpcRequest)
+		result, err := h.routeToMCPServer(c.Request.Context(), transportCtx.ServerID, rpcRequest)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"jsonrpc": "2.0",
@@ -178,12 +249,7 @@ func (h *RPCHandler) HandleJSONRPC(c *gin.Context) {
 }
 
 // routeToMCPServer routes the request to the actual MCP server via STDIO transport
-func (h *RPCHandler) routeToMCPServer(ctx context.Context, serverID string, rpcRequest *struct {
-	Params  interface{} `json:"params,omitempty"`
-	ID      string      `json:"id"`
-	JSONRPC string      `json:"jsonrpc"`
-	Method  string      `json:"method"`
-}) (interface{}, error) {
+func (h *RPCHandler) routeToMCPServer(ctx context.Context, serverID string, rpcRequest *rpcRequest) (interface{}, error) {
 	// Get the server configuration from discovery service
 	server, err := h.discoveryService.GetServer(serverID)
 	if err != nil {
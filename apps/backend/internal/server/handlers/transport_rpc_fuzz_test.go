@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRPCRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		id      interface{}
+		code    int
+		message string
+	}{
+		{name: "string id", body: `{"jsonrpc":"2.0","id":"abc","method":"ping"}`, id: "abc"},
+		{name: "numeric id kept exactly", body: `{"jsonrpc":"2.0","id":9007199254740993,"method":"ping"}`, id: json.Number("9007199254740993")},
+		{name: "notification", body: `{"jsonrpc":"2.0","method":"notifications/initialized"}`},
+		{name: "empty body", body: ``, code: -32700, message: "Parse error"},
+		{name: "truncated", body: `{"jsonrpc":"2.0",`, code: -32700, message: "Parse error"},
+		{name: "batch", body: `[{"jsonrpc":"2.0","id":1,"method":"ping"}]`, code: -32600, message: "Invalid Request"},
+		{name: "scalar", body: `42`, code: -32600, message: "Invalid Request"},
+		{name: "object id", body: `{"jsonrpc":"2.0","id":{},"method":"ping"}`, code: -32600, message: "Invalid Request"},
+		{name: "wrong version", body: `{"jsonrpc":"1.0","id":1,"method":"ping"}`, id: json.Number("1"), code: -32600, message: "Invalid Request"},
+		{name: "scalar params", body: `{"jsonrpc":"2.0","id":1,"method":"ping","params":"x"}`, id: json.Number("1"), code: -32600, message: "Invalid Request"},
+		{name: "method not a string", body: `{"jsonrpc":"2.0","id":1,"method":7}`, code: -32600, message: "Invalid Request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, rpcErr := parseRPCRequest([]byte(tt.body))
+			if tt.code == 0 {
+				require.Nil(t, rpcErr)
+				assert.Equal(t, tt.id, req.ID)
+				return
+			}
+			require.NotNil(t, rpcErr)
+			assert.Equal(t, tt.code, rpcErr.Code)
+			assert.Equal(t, tt.message, rpcErr.Message)
+			if req != nil {
+				assert.Equal(t, tt.id, req.ID)
+			}
+		})
+	}
+}
+
+func TestHandleJSONRPCEchoesNumericID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(`{"jsonrpc":"1.0","id":12,"method":"ping"}`))
+
+	NewRPCHandler(nil, nil, nil).HandleJSONRPC(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":12,"error":{"code":-32600,"message":"Invalid Request","data":"JSONRPC version must be 2.0"}}`, w.Body.String())
+}
+
+func FuzzParseRPCRequest(f *testing.F) {
+	f.Add([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`))
+	f.Add([]byte(`{"jsonrpc":"2.0","id":"a","method":"tools/list","params":[]}`))
+	f.Add([]byte(`{"jsonrpc":"2.0","id":-1.5e3,"method":"x","params":null}`))
+	f.Add([]byte(`[{"jsonrpc":"2.0"}]`))
+	f.Add([]byte(`{"jsonrpc":"2.0","id":true}`))
+	f.Add([]byte("\xff"))
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		req, rpcErr := parseRPCRequest(body)
+		if rpcErr != nil {
+			assert.Contains(t, []int{-32700, -32600}, rpcErr.Code)
+			if rpcErr.Code == -32700 {
+				assert.Nil(t, req)
+			}
+		} else {
+			require.NotNil(t, req)
+			assert.Equal(t, "2.0", req.JSONRPC)
+			assert.NotEmpty(t, req.Method)
+			switch req.Params.(type) {
+			case nil, map[string]interface{}, []interface{}:
+			default:
+				t.Fatalf("unexpected params type %T", req.Params)
+			}
+		}
+
+		if req != nil {
+			switch req.ID.(type) {
+			case nil, string, json.Number:
+			default:
+				t.Fatalf("unexpected id type %T", req.ID)
+			}
+			// Every error response must be encodable with the echoed ID
+			encoded, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": rpcErr})
+			require.NoError(t, err)
+			require.True(t, json.Valid(encoded))
+		}
+	})
+}
@@ -128,6 +128,13 @@ func (h *STDIOHandler) HandleSTDIOExecute(c *gin.Context) {
 		config,
 	)
 	if err != nil {
+		if policyErr, ok := err.(*types.Error); ok {
+			c.JSON(policyErr.Status, gin.H{
+				"error":   policyErr.Message,
+				"details": policyErr.Details,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create STDIO connection: " + err.Error(),
 		})
@@ -307,6 +314,13 @@ func (h *STDIOHandler) handleSTDIOStart(c *gin.Context) {
 		config,
 	)
 	if err != nil {
+		if policyErr, ok := err.(*types.Error); ok {
+			c.JSON(policyErr.Status, gin.H{
+				"error":   policyErr.Message,
+				"details": policyErr.Details,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create STDIO transport: " + err.Error(),
 		})
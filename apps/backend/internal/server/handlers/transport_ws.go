@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
@@ -34,6 +35,12 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		return
 	}
 
+	// Reject subprotocol mismatches before a session is created
+	if _, err := transport.NegotiateSubprotocol(c.Request, h.transportManager.WebSocketSubprotocolRequired()); err != nil {
+		rejectWebSocketHandshake(c, err)
+		return
+	}
+
 	// Create WebSocket transport connection
 	wsTransport, session, err := h.transportManager.CreateConnection(
 		c.Request.Context(),
@@ -54,6 +61,13 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		UpgradeHTTP(http.ResponseWriter, *http.Request) error
 	}); ok {
 		if err := upgrader.UpgradeHTTP(c.Writer, c.Request); err != nil {
+			if session != nil {
+				h.transportManager.CloseConnection(session.ID)
+			}
+			if _, ok := err.(*types.Error); ok {
+				rejectWebSocketHandshake(c, err)
+				return
+			}
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "Failed to upgrade to WebSocket: " + err.Error(),
 			})
@@ -89,6 +103,13 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	}
 }
 
+// rejectWebSocketHandshake fails a WebSocket handshake and advertises the
+// supported subprotocols so clients can retry with one of them
+func rejectWebSocketHandshake(c *gin.Context, err error) {
+	c.Header(transport.WebSocketProtocolsHeader, strings.Join(transport.WebSocketSubprotocols, ", "))
+	RespondWithError(c, err)
+}
+
 // HandleServerWebSocket handles server-specific WebSocket connections
 func (h *WebSocketHandler) HandleServerWebSocket(c *gin.Context) {
 	// This is handled by path rewriting middleware
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageBudgetManager manages an organization's monthly usage budgets
+type UsageBudgetManager interface {
+	List(ctx context.Context, orgID string) (*types.UsageBudgetListResponse, error)
+	SetBudget(ctx context.Context, orgID, dimension string, req *types.SetUsageBudgetRequest) (*types.UsageBudgetStatus, error)
+	DeleteBudget(ctx context.Context, orgID, dimension string) error
+}
+
+// UsageBudgetHandler handles monthly budgets on metered usage
+type UsageBudgetHandler struct {
+	budgets UsageBudgetManager
+}
+
+// NewUsageBudgetHandler creates a new usage budget handler
+func NewUsageBudgetHandler(budgets UsageBudgetManager) *UsageBudgetHandler {
+	return &UsageBudgetHandler{budgets: budgets}
+}
+
+// ListBudgets handles GET /api/admin/budgets
+func (h *UsageBudgetHandler) ListBudgets(c *gin.Context) {
+	resp, err := h.budgets.List(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, resp)
+}
+
+// SetBudget handles PUT /api/admin/budgets/:dimension
+func (h *UsageBudgetHandler) SetBudget(c *gin.Context) {
+	var req types.SetUsageBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	status, err := h.budgets.SetBudget(c.Request.Context(), c.GetString("organization_id"), c.Param("dimension"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, status)
+}
+
+// DeleteBudget handles DELETE /api/admin/budgets/:dimension
+func (h *UsageBudgetHandler) DeleteBudget(c *gin.Context) {
+	if err := h.budgets.DeleteBudget(c.Request.Context(), c.GetString("organization_id"), c.Param("dimension")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Budget deleted"})
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserManager manages the human users of an organization
+type UserManager interface {
+	List(ctx context.Context, orgID string, filter *types.ManagedUserFilter) ([]*types.ManagedUser, error)
+	Get(ctx context.Context, orgID, id string) (*types.ManagedUser, error)
+	Create(ctx context.Context, orgID, actorID string, req *types.CreateManagedUserRequest) (*types.ManagedUser, error)
+	Invite(ctx context.Context, orgID, actorID string, req *types.InviteUserRequest) (*types.UserTokenDelivery, error)
+	ResendInvitation(ctx context.Context, orgID, actorID, id string) (*types.UserTokenDelivery, error)
+	Update(ctx context.Context, orgID, actorID, id string, req *types.UpdateManagedUserRequest) (*types.ManagedUser, error)
+	Deactivate(ctx context.Context, orgID, actorID, id string) (*types.ManagedUser, error)
+	Reactivate(ctx context.Context, orgID, actorID, id string) (*types.ManagedUser, error)
+	SendPasswordReset(ctx context.Context, orgID, actorID, id string) (*types.UserTokenDelivery, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	AcceptInvitation(ctx context.Context, req *types.AcceptInvitationRequest) error
+	ResetPassword(ctx context.Context, req *types.ResetPasswordRequest) error
+}
+
+// UserHandler handles user management by admins and the invitation and
+// password reset links emailed to users
+type UserHandler struct {
+	users UserManager
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(users UserManager) *UserHandler {
+	return &UserHandler{users: users}
+}
+
+// List handles GET /api/admin/users
+func (h *UserHandler) List(c *gin.Context) {
+	users, err := h.users.List(c.Request.Context(), c.GetString("organization_id"), &types.ManagedUserFilter{
+		Status: c.Query("status"),
+		Role:   c.Query("role"),
+		Search: c.Query("search"),
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, users)
+}
+
+// Get handles GET /api/admin/users/:id
+func (h *UserHandler) Get(c *gin.Context) {
+	user, err := h.users.Get(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, user)
+}
+
+// Create handles POST /api/admin/users
+func (h *UserHandler) Create(c *gin.Context) {
+	var req types.CreateManagedUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	user, err := h.users.Create(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, user)
+}
+
+// Invite handles POST /api/admin/users/invitations
+func (h *UserHandler) Invite(c *gin.Context) {
+	var req types.InviteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	delivery, err := h.users.Invite(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, delivery)
+}
+
+// ResendInvitation handles POST /api/admin/users/:id/invitation
+func (h *UserHandler) ResendInvitation(c *gin.Context) {
+	delivery, err := h.users.ResendInvitation(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, delivery)
+}
+
+// Update handles PUT /api/admin/users/:id
+func (h *UserHandler) Update(c *gin.Context) {
+	var req types.UpdateManagedUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	user, err := h.users.Update(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, user)
+}
+
+// Deactivate handles POST /api/admin/users/:id/deactivate
+func (h *UserHandler) Deactivate(c *gin.Context) {
+	user, err := h.users.Deactivate(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, user)
+}
+
+// Reactivate handles POST /api/admin/users/:id/reactivate
+func (h *UserHandler) Reactivate(c *gin.Context) {
+	user, err := h.users.Reactivate(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, user)
+}
+
+// SendPasswordReset handles POST /api/admin/users/:id/password-reset
+func (h *UserHandler) SendPasswordReset(c *gin.Context) {
+	delivery, err := h.users.SendPasswordReset(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, delivery)
+}
+
+// RequestPasswordReset handles POST /api/auth/password-reset
+func (h *UserHandler) RequestPasswordReset(c *gin.Context) {
+	var req types.RequestPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	if err := h.users.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "If an active account uses this email, a reset link has been sent"})
+}
+
+// ResetPassword handles POST /api/auth/password-reset/confirm
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req types.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	if err := h.users.ResetPassword(c.Request.Context(), &req); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Password updated, you can now sign in"})
+}
+
+// AcceptInvitation handles POST /api/auth/invitations/accept
+func (h *UserHandler) AcceptInvitation(c *gin.Context) {
+	var req types.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	if err := h.users.AcceptInvitation(c.Request.Context(), &req); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Invitation accepted, you can now sign in"})
+}
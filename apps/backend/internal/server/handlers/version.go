@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/buildinfo"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlagLister resolves feature flags for an organization
+type FeatureFlagLister interface {
+	ListFlags(ctx context.Context, orgID string) []*types.FeatureFlagState
+}
+
+// VersionHandler reports the deployed build and its release notes
+type VersionHandler struct {
+	flags FeatureFlagLister
+}
+
+// NewVersionHandler creates a new version handler
+func NewVersionHandler(flags FeatureFlagLister) *VersionHandler {
+	return &VersionHandler{flags: flags}
+}
+
+// GetVersion handles GET /api/version. Features lists the feature flags
+// enabled for the caller's organization.
+func (h *VersionHandler) GetVersion(c *gin.Context) {
+	features := []string{}
+	if h.flags != nil {
+		for _, flag := range h.flags.ListFlags(c.Request.Context(), c.GetString("organization_id")) {
+			if flag.Enabled {
+				features = append(features, flag.Key)
+			}
+		}
+	}
+
+	RespondWithSuccess(c, &types.VersionResponse{
+		Build:           buildinfo.Get(),
+		ProtocolVersion: types.MCPProtocolVersion,
+		Features:        features,
+	})
+}
+
+// GetChangelog handles GET /api/changelog. With ?since=<version> only the
+// releases newer than that version are returned.
+func (h *VersionHandler) GetChangelog(c *gin.Context) {
+	releases, err := buildinfo.Changelog()
+	if err != nil {
+		log.Printf("Failed to load changelog: %v", err)
+		RespondWithError(c, types.NewInternalError("Changelog unavailable"))
+		return
+	}
+
+	RespondWithSuccess(c, &types.ChangelogResponse{
+		CurrentVersion: buildinfo.Get().Version,
+		Releases:       buildinfo.ReleasesSince(releases, c.Query("since")),
+	})
+}
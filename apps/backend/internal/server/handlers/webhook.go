@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookManager manages organizations' webhooks and their deliveries
+type WebhookManager interface {
+	List(ctx context.Context, orgID string) ([]*types.Webhook, error)
+	Get(ctx context.Context, orgID, id string) (*types.Webhook, error)
+	Create(ctx context.Context, orgID, userID string, req *types.CreateWebhookRequest) (*types.Webhook, error)
+	Update(ctx context.Context, orgID, id string, req *types.UpdateWebhookRequest) (*types.Webhook, error)
+	Delete(ctx context.Context, orgID, id string) error
+	RotateSecret(ctx context.Context, orgID, id string) (*types.Webhook, error)
+	Test(ctx context.Context, orgID, id string) (*types.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, orgID string, filter *types.WebhookDeliveryFilter) ([]*types.WebhookDelivery, error)
+	Redeliver(ctx context.Context, orgID, webhookID, deliveryID string) (*types.WebhookDelivery, error)
+}
+
+// WebhookHandler handles webhook registrations and their delivery log
+type WebhookHandler struct {
+	webhooks WebhookManager
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhooks WebhookManager) *WebhookHandler {
+	return &WebhookHandler{webhooks: webhooks}
+}
+
+// ListWebhooks handles GET /api/admin/webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.webhooks.List(c.Request.Context(), c.GetString("organization_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, webhooks)
+}
+
+// GetWebhook handles GET /api/admin/webhooks/:id
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	webhook, err := h.webhooks.Get(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, webhook)
+}
+
+// CreateWebhook handles POST /api/admin/webhooks. The response carries the
+// signing secret, which is not shown again.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req types.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	webhook, err := h.webhooks.Create(c.Request.Context(), c.GetString("organization_id"), c.GetString("user_id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithCreated(c, webhook)
+}
+
+// UpdateWebhook handles PUT /api/admin/webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	var req types.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	webhook, err := h.webhooks.Update(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), &req)
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, webhook)
+}
+
+// DeleteWebhook handles DELETE /api/admin/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	if err := h.webhooks.Delete(c.Request.Context(), c.GetString("organization_id"), c.Param("id")); err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, gin.H{"message": "Webhook deleted"})
+}
+
+// RotateSecret handles POST /api/admin/webhooks/:id/rotate-secret. The
+// response carries the new secret, which is not shown again.
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	webhook, err := h.webhooks.RotateSecret(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, webhook)
+}
+
+// TestWebhook handles POST /api/admin/webhooks/:id/test, queueing a
+// webhook.ping event for the webhook
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	delivery, err := h.webhooks.Test(c.Request.Context(), c.GetString("organization_id"), c.Param("id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithAccepted(c, delivery)
+}
+
+// ListDeliveries handles GET /api/admin/webhooks/:id/deliveries. Supports
+// ?status=, limit and offset.
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	deliveries, err := h.webhooks.ListDeliveries(c.Request.Context(), c.GetString("organization_id"), &types.WebhookDeliveryFilter{
+		WebhookID: c.Param("id"),
+		Status:    c.Query("status"),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithSuccess(c, deliveries)
+}
+
+// Redeliver handles POST /api/admin/webhooks/:id/deliveries/:delivery_id/redeliver
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	delivery, err := h.webhooks.Redeliver(c.Request.Context(), c.GetString("organization_id"), c.Param("id"), c.Param("delivery_id"))
+	if err != nil {
+		RespondWithError(c, err)
+		return
+	}
+	RespondWithAccepted(c, delivery)
+}
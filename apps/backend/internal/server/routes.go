@@ -2,23 +2,41 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/a2a"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/buildinfo"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/cache"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/commandpolicy"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/discovery"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/eventbus"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/geoip"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/health"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/inspector"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/license"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mailer"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/plugins"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/ratelimit"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/residency"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/retry"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/scheduler"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/serverlogs"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/telemetry"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/virtual"
@@ -30,14 +48,71 @@ import (
 
 func (s *Server) RegisterRoutes() http.Handler {
 	r := gin.New()
+
+	// Client IPs come from forwarding headers only when set by a trusted proxy
+	clientIPHeaders := s.cfg.Server.ClientIPHeaders
+	if len(clientIPHeaders) == 0 {
+		clientIPHeaders = s.cfg.RateLimit.IPCustomHeaders
+	}
+	if err := middleware.ConfigureClientIP(r, s.cfg.Server.TrustedProxies, clientIPHeaders); err != nil {
+		panic(fmt.Sprintf("invalid trusted proxies: %v", err))
+	}
+
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 
+	// Trace every request, continuing the caller's trace when it sent one
+	r.Use(middleware.Tracing())
+
+	// Keep the caller's headers for propagation to upstream servers
+	r.Use(middleware.InboundHeaders())
+
+	// Time every request by route for the metrics emitters
+	r.Use(middleware.RequestMetrics(s.logging.(*logging.Service).EmitMetric))
+
+	// Shed load with 503s once latency shows Postgres or upstream servers
+	// are saturated
+	var loadLimiter *scheduler.AdaptiveLimiter
+	if shedding := s.cfg.Gateway.LoadShedding; shedding.Enabled {
+		loadLimiter = scheduler.NewAdaptiveLimiter("load_shedding", scheduler.AdaptiveLimiterConfig{
+			InitialLimit:   shedding.InitialLimit,
+			MinLimit:       shedding.MinLimit,
+			MaxLimit:       shedding.MaxLimit,
+			LatencyFactor:  shedding.LatencyFactor,
+			Backoff:        shedding.Backoff,
+			BaselineWindow: shedding.BaselineWindow,
+		})
+		loadLimiter.SetMetricEmitter(s.logging.(*logging.Service).EmitMetric)
+		exemptPaths := shedding.ExemptPaths
+		if len(exemptPaths) == 0 {
+			exemptPaths = []string{"/health", "/readyz", "/metrics"}
+		}
+		r.Use(middleware.LoadShedding(loadLimiter, exemptPaths...))
+	}
+
 	// Initialize logging middleware
 	loggingMiddleware := logging.NewMiddleware(s.logging.(*logging.Service))
+	if sampling := s.cfg.Logging.Sampling; sampling.Enabled {
+		loggingMiddleware.SetSampler(logging.NewSampler(sampling.SuccessRate, sampling.TargetPerSecond))
+	}
+
+	// Outbound connectivity policy; offline mode blocks calls to external services
+	offline := s.cfg.Gateway.Offline
+	offlinePolicy, err := airgap.New(offline.Enabled, offline.Mirrors, offline.AllowedHosts)
+	if err != nil {
+		panic(fmt.Sprintf("invalid gateway.offline configuration: %v", err))
+	}
+	if offlinePolicy.Offline() {
+		for _, feature := range offlinePolicy.Status().Features {
+			if feature.Reason != "" {
+				log.Printf("Offline mode: %s: %s", feature.Key, feature.Reason)
+			}
+		}
+	}
 
 	// Initialize plugin service for content filtering
 	pluginService := plugins.NewPluginService(s.db.GetDB())
+	pluginService.SetEgressPolicy(offlinePolicy)
 	if err := pluginService.Initialize(context.TODO()); err != nil {
 		// Log error but continue - content filtering is optional for basic functionality
 	}
@@ -88,12 +163,15 @@ func (s *Server) RegisterRoutes() http.Handler {
 	corsConfig = cors.Config{
 		AllowOrigins:     allowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "X-Requested-With", "X-API-Key"},
+		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "X-Requested-With", "X-API-Key", "Mcp-Session-Id", "X-Session-ID", "Idempotency-Key"},
 		AllowCredentials: true,
-		ExposeHeaders:    []string{"Content-Length", "Content-Type"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Type", "Mcp-Session-Id", "X-Session-ID"},
 	}
 	r.Use(cors.New(corsConfig))
 
+	// Detect MCP clients for analytics and work around their known quirks
+	r.Use(middleware.ClientCompat(!s.cfg.Gateway.DisableClientShims))
+
 	// Apply default middleware chain to root router
 	defaultChain := middleware.DefaultChainWithConfig(securityConfig)
 	defaultChain.Use(loggingMiddleware.RequestLogger())
@@ -108,6 +186,19 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	r.GET("/health", s.healthHandler)
 
+	// Probe Postgres and Redis for /readyz and the service health score
+	s.health = s.newHealthMonitor()
+	r.GET("/readyz", s.readinessHandler)
+
+	// Prometheus scrape endpoint
+	if s.prometheus != nil {
+		metricsPath := s.cfg.Observability.Prometheus.Path
+		if metricsPath == "" {
+			metricsPath = "/metrics"
+		}
+		r.GET(metricsPath, gin.WrapH(s.prometheus.Handler(s.cfg.Observability.Prometheus.BearerToken)))
+	}
+
 	// Initialize middleware
 	pathRewriteMiddleware := middleware.NewPathRewriteMiddleware()
 
@@ -127,7 +218,12 @@ func (s *Server) RegisterRoutes() http.Handler {
 	if mcpDiscoveryURL == "" {
 		log.Printf("Warning: MCP discovery URL not configured, external package discovery will be unavailable")
 	}
-	mcpDiscoveryService := discovery.NewMCPDiscoveryService(mcpDiscoveryURL)
+	var mcpDiscoveryService *discovery.MCPDiscoveryService
+	if resolvedURL, err := offlinePolicy.ResolveURL(types.ConnectivityFeaturePackageDiscovery, mcpDiscoveryURL); err != nil {
+		mcpDiscoveryService = discovery.NewUnavailableMCPDiscoveryService(err)
+	} else {
+		mcpDiscoveryService = discovery.NewMCPDiscoveryService(resolvedURL)
+	}
 
 	// Initialize endpoint service with dynamic base URL
 	baseURL := s.cfg.Server.GetBaseURL()
@@ -140,6 +236,77 @@ func (s *Server) RegisterRoutes() http.Handler {
 		// Log error but continue - transport layer is optional
 	}
 
+	// STDIO command allow/deny lists apply at registration and at launch
+	commandPolicy, err := commandpolicy.New(s.cfg.Transport.STDIOCommands)
+	if err != nil {
+		panic(fmt.Sprintf("invalid stdio_commands policy: %v", err))
+	}
+	commandPolicy.SetAuditor(s.logging.(*logging.Service))
+	if !commandPolicy.Restricted() {
+		log.Printf("Warning: no STDIO command allowlist configured, any executable may be registered")
+	}
+	transportManager.SetCommandPolicy(commandPolicy)
+
+	// Broadcasts reach clients on every gateway instance sharing the bus
+	if s.cfg.Transport.EventBus.Backend == "redis" {
+		bus, err := eventbus.NewRedisBus(fmt.Sprintf("%s:%d", s.cfg.Redis.Host, s.cfg.Redis.Port),
+			s.cfg.Redis.Password, s.cfg.Redis.Database, s.cfg.Transport.EventBus.GetMaxLen())
+		if err != nil {
+			log.Printf("Warning: event bus cannot reach Redis, broadcasts only reach this instance: %v", err)
+		} else if err := transportManager.SetEventBus(bus); err != nil {
+			log.Printf("Warning: event bus subscription failed, broadcasts only reach this instance: %v", err)
+		}
+	}
+
+	// Tool, resource and prompt listings are served from the list cache
+	// until they change or expire
+	var listCache *cache.ListCache
+	if listCacheCfg := s.cfg.Gateway.ListCache; listCacheCfg.Enabled {
+		var store cache.Store
+		backend := "memory"
+		if listCacheCfg.Backend == "redis" {
+			redisStore, err := cache.NewRedisStore(fmt.Sprintf("%s:%d", s.cfg.Redis.Host, s.cfg.Redis.Port),
+				s.cfg.Redis.Password, s.cfg.Redis.Database)
+			if err != nil {
+				log.Printf("Warning: list cache cannot reach Redis, caching per instance: %v", err)
+			} else {
+				store, backend = redisStore, "redis"
+			}
+		}
+		if store == nil {
+			store = cache.NewMemoryStore(listCacheCfg.MaxEntries)
+		}
+		listCache = cache.New(store, backend, listCacheCfg.GetTTL())
+	}
+
+	// Capture stdout/stderr of STDIO servers so operators can debug them
+	// without host access; persisted when a retention is configured
+	var serverLogs *serverlogs.Capture
+	if serverLogsCfg := s.cfg.Transport.ServerLogs; serverLogsCfg.Enabled {
+		var store serverlogs.Store
+		if serverLogsCfg.Retention > 0 {
+			store = serverlogs.NewDBStore(s.db.GetDB())
+		}
+		serverLogs = serverlogs.New(serverLogsCfg.BufferLines, store)
+	}
+	transportManager.SetServerLogs(serverLogs)
+	serverLogsHandler := handlers.NewServerLogsHandler(serverLogs)
+
+	// Plan tiers decide who queues first for tool execution and who is shed
+	// first when transport connections run short
+	priorityService := services.NewPriorityService(s.db.GetDB())
+	schedulerCfg := s.cfg.Gateway.Scheduler
+	toolScheduler := scheduler.New("tool_execution", scheduler.Config{
+		MaxConcurrent: schedulerCfg.MaxConcurrentExecutions,
+		MaxQueue:      schedulerCfg.MaxQueuePerClass,
+		MaxWait:       schedulerCfg.MaxQueueWait,
+	})
+	toolScheduler.SetMetricEmitter(s.logging.(*logging.Service).EmitMetric)
+	connectionBackpressure := scheduler.NewBackpressure("transport_connection",
+		s.cfg.Transport.MaxConnections, schedulerCfg.ConnectionShares)
+	connectionBackpressure.SetMetricEmitter(s.logging.(*logging.Service).EmitMetric)
+	transportManager.SetBackpressure(connectionBackpressure, priorityService)
+
 	// Initialize discovery service with transport manager
 	discoveryConfig := &discovery.Config{
 		Enabled:          true,
@@ -147,19 +314,213 @@ func (s *Server) RegisterRoutes() http.Handler {
 		FailureThreshold: 3,
 		RecoveryTimeout:  5 * time.Minute,
 		SingleTenant:     true,
+		SecretScanMode:   s.cfg.Discovery.SecretScanning,
+		CommandPolicy:    commandPolicy,
 	}
 	discoveryService := discovery.NewService(s.db.GetDB(), discoveryConfig, transportManager)
+	discoveryService.SetMetricEmitter(s.logging.(*logging.Service).EmitMetric)
+	if s.prometheus != nil {
+		s.prometheus.RegisterGauge("transport_sessions", "Active client sessions by transport", func() []observability.Sample {
+			var samples []observability.Sample
+			for transportType, count := range transportManager.SessionsByTransport() {
+				samples = append(samples, observability.Sample{
+					Labels: map[string]string{"transport": transportType},
+					Value:  float64(count),
+				})
+			}
+			return samples
+		})
+	}
+	var listCacheManager handlers.ListCacheManager
+	if listCache != nil {
+		listCacheManager = listCache
+		discoveryService.SetListingInvalidator(listCache)
+	}
+	cacheHandler := handlers.NewCacheHandler(listCacheManager)
+
+	// Catalog search ranks by full text, and by meaning when an embeddings
+	// API is configured; the worker keeps the embeddings current
+	var searchEmbedder services.Embedder
+	if embeddingsCfg := s.cfg.Search.Embeddings; embeddingsCfg.Enabled {
+		embedder, err := services.NewHTTPEmbedder(embeddingsCfg.Endpoint, embeddingsCfg.Model,
+			embeddingsCfg.APIKey, offlinePolicy)
+		if err != nil {
+			log.Printf("Warning: semantic search disabled: %v", err)
+		} else {
+			searchEmbedder = embedder
+		}
+	}
+	searchHandler := handlers.NewSearchHandler(services.NewSearchService(s.db.GetDB(), searchEmbedder))
+
+	// Initialize notification service; health check failures notify admins
+	notificationService := services.NewNotificationService(s.db.GetDB())
+	discoveryService.SetNotifier(notificationService)
+
+	// Admin-declared incidents are referenced by health alerts and status pages
+	incidentService := services.NewIncidentService(s.db.GetDB())
+	discoveryService.SetIncidents(incidentService)
+
+	// Organizations brand the OpenAPI metadata and docs of their public endpoints
+	brandingService := services.NewBrandingService(s.db.GetDB())
+	brandingHandler := handlers.NewBrandingHandler(brandingService)
+
+	// Organizations choose which optional fields request logs record
+	logFieldPolicyService := services.NewLogFieldPolicyService(s.db.GetDB())
+	loggingMiddleware.SetFieldPolicy(logFieldPolicyService)
+	logFieldPolicyHandler := handlers.NewLogFieldPolicyHandler(logFieldPolicyService)
+
+	// Requests to owner webhooks and A2A agents are signed with per-destination keys
+	requestSigningService := services.NewRequestSigningService(s.db.GetDB())
+	signingKeyHandler := handlers.NewSigningKeyHandler(requestSigningService)
+
+	// Organizations register webhooks for gateway events; the worker posts them
+	notifyCfg := s.cfg.Notifications
+	webhookService := services.NewWebhookService(s.db.GetDB(), services.WebhookSettings{
+		MaxAttempts:  notifyCfg.Webhooks.MaxAttempts,
+		RetryBackoff: notifyCfg.Webhooks.RetryBackoff,
+		Timeout:      notifyCfg.Webhooks.Timeout,
+	})
+	webhookService.SetEgressPolicy(offlinePolicy)
+	notificationService.SetWebhooks(webhookService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+
+	// Server owners are alerted directly when their server changes state
+	serverOwnerService := services.NewServerOwnerService(s.db.GetDB(), s.cfg.Server.GetBaseURL(), notifyCfg.OwnerEscalateAfter)
+	serverOwnerService.SetEgressPolicy(offlinePolicy)
+	serverOwnerService.SetNotifier(notificationService)
+	serverOwnerService.SetRequestSigner(requestSigningService)
+	smtpMailer := mailer.NewSMTPMailer(notifyCfg.SMTP.Host, notifyCfg.SMTP.Port, notifyCfg.SMTP.Username,
+		notifyCfg.SMTP.Password, notifyCfg.SMTP.From)
+	if smtpMailer != nil {
+		serverOwnerService.SetMailer(smtpMailer)
+	}
+	discoveryService.SetOwnerAlerts(serverOwnerService)
+	serverOwnerHandler := handlers.NewServerOwnerHandler(serverOwnerService)
+	incidentHandler := handlers.NewIncidentHandler(incidentService, discoveryService)
 
 	// Initialize virtual server service
 	virtualService := virtual.NewService(s.db.GetDB())
 
 	// Initialize A2A services
 	a2aService := a2a.NewService(s.db.GetDB())
+	a2aService.SetEvents(webhookService)
 	a2aClient := a2a.NewClient(30*time.Second, 3)
+	a2aClient.SetRequestSigner(requestSigningService)
+	a2aClient.SetEgressPolicy(offlinePolicy)
 	a2aAdapter := a2a.NewAdapter(a2aService, a2aClient)
 
+	// Initialize feature flag service (DB-backed, FEATURE_FLAG_<KEY> env overrides)
+	featureFlagService := services.NewFeatureFlagService(s.db.GetDB())
+
+	// Verify the enterprise license offline; it gates enterprise flags and seats
+	licenseKey, err := s.cfg.License.LoadKey()
+	if err != nil {
+		panic(fmt.Sprintf("failed to read license key: %v", err))
+	}
+	licensePublicKey, err := s.cfg.License.LoadPublicKey()
+	if err != nil {
+		panic(fmt.Sprintf("failed to read license public key: %v", err))
+	}
+	licenseManager, err := license.NewManager(licenseKey, licensePublicKey, s.cfg.License.GracePeriod, license.NewDBSeatCounter(s.db.GetDB()))
+	if err != nil {
+		panic(fmt.Sprintf("invalid license configuration: %v", err))
+	}
+	featureFlagService.SetEntitlements(licenseManager)
+
 	// Initialize namespace service
 	namespaceService := services.NewNamespaceService(s.db.GetDB(), endpointService)
+	namespaceService.SetExecutionLogger(s.logging.(*logging.Service))
+	namespaceService.SetCommandPolicy(commandPolicy)
+	namespaceService.SetScheduler(toolScheduler, priorityService)
+	residencyCfg := s.cfg.Gateway.Residency
+	residencyPolicy, err := residency.New(residencyCfg.StorageRegion, residencyCfg.RequireMarked)
+	if err != nil {
+		panic(fmt.Sprintf("invalid residency configuration: %v", err))
+	}
+	namespaceService.SetResidencyPolicy(residencyPolicy)
+	namespaceService.SetServerLogs(serverLogs)
+	// Calls to a server in a server group are balanced over its replicas
+	namespaceService.SetReplicaPicker(discoveryService.ServerGroups())
+	namespaceService.SetServerCallObserver(s.health)
+	discoveryService.ServerGroups().SetReplicaScorer(s.health, s.cfg.Gateway.ServiceHealth.ReplicaTolerance)
+	// Mock servers are answered from their canned tools, without an upstream
+	mockServerService := services.NewMockServerService(s.db.GetDB())
+	mockServerService.SetToolRefresher(discoveryService)
+	namespaceService.SetMockTools(mockServerService)
+	// Servers can record their responses and be replayed from them later
+	serverRecordingService := services.NewServerRecordingService(s.db.GetDB())
+	serverRecordingService.SetSessionResetter(namespaceService)
+	namespaceService.SetRecordings(serverRecordingService)
+	// Failed calls that are safe to repeat are retried within per-protocol
+	// budgets, and the retries show in server stats
+	retryCfg := s.cfg.Gateway.Retry
+	retryBudgets := make(map[string]retry.Budget, len(retryCfg.Budgets))
+	for protocol, budget := range retryCfg.Budgets {
+		retryBudgets[protocol] = retry.Budget{Ratio: budget.Ratio, Reserve: budget.Reserve}
+	}
+	retrier := retry.New(retry.Config{
+		Budgets:   retryBudgets,
+		BaseDelay: retryCfg.BaseDelay,
+		MaxDelay:  retryCfg.MaxDelay,
+	})
+	namespaceService.SetRetrier(retrier)
+	namespaceService.SetMetricEmitter(s.logging.(*logging.Service).EmitMetric)
+	discoveryService.SetRetryStats(retrier)
+	toolValidation := s.cfg.Gateway.ToolValidation
+	namespaceService.SetSchemaValidation(toolValidation.Inputs, toolValidation.Outputs,
+		s.logging.(*logging.Service).EmitMetric)
+	// Tool policies allow, deny or hold calls before they reach a server
+	toolPolicyService := services.NewToolPolicyService(s.db.GetDB())
+	namespaceService.SetToolPolicy(toolPolicyService)
+	headerPropagationService := services.NewHeaderPropagationService(s.db.GetDB())
+	namespaceService.SetHeaderPropagation(headerPropagationService)
+	identityInjectionService := services.NewIdentityInjectionService(s.db.GetDB())
+	namespaceService.SetIdentityInjection(identityInjectionService)
+	// Tool arguments carrying data above their server's sensitivity label
+	// are blocked, redacted or logged before they leave the gateway
+	dlpCfg := s.cfg.Gateway.DLP
+	if err := services.ValidateDLPDefaults(dlpCfg.GetDefaultSensitivity(), dlpCfg.GetDefaultAction()); err != nil {
+		panic(fmt.Sprintf("invalid gateway.dlp configuration: %v", err))
+	}
+	dlpScanner, err := services.NewDLPScanner(dlpCfg.External.URL, dlpCfg.External.APIKey, dlpCfg.External.Timeout,
+		dlpCfg.External.FailOpen, offlinePolicy)
+	if err != nil {
+		panic(fmt.Sprintf("invalid gateway.dlp.external configuration: %v", err))
+	}
+	dlpService := services.NewDLPService(s.db.GetDB(), dlpScanner, dlpCfg.GetDefaultSensitivity(), dlpCfg.GetDefaultAction())
+	if dlpCfg.Enabled {
+		namespaceService.SetDLP(dlpService)
+	}
+	// Endpoint geo policies allow or deny calls by the country and
+	// autonomous system of the client
+	geoIPCfg := s.cfg.Gateway.GeoIP
+	geoResolver, err := geoip.OpenResolver(geoIPCfg.CountryDatabase, geoIPCfg.ASNDatabase)
+	if err != nil {
+		panic(fmt.Sprintf("invalid gateway.geoip configuration: %v", err))
+	}
+	var geoLocator services.GeoResolver
+	if geoResolver != nil {
+		geoLocator = geoResolver
+	}
+	endpointGeoPolicyService := services.NewEndpointGeoPolicyService(s.db.GetDB(), geoLocator)
+	// Calls held for approval wait for an admin to approve or reject them
+	approvalCfg := s.cfg.Gateway.Approvals
+	approvalService := services.NewApprovalService(s.db.GetDB(), s.cfg.Server.GetBaseURL(), services.ApprovalSettings{
+		Timeout:      approvalCfg.Timeout,
+		MaxTimeout:   approvalCfg.MaxTimeout,
+		PollInterval: approvalCfg.PollInterval,
+	})
+	approvalService.SetNotifier(notificationService)
+	approvalService.SetEgressPolicy(offlinePolicy)
+	if smtpMailer != nil {
+		approvalService.SetMailer(smtpMailer)
+	}
+	namespaceService.SetApprovals(approvalService)
+	approvalHandler := handlers.NewApprovalHandler(approvalService)
+
+	// Initialize MCP message log service (message-level traffic with redaction)
+	mcpMessageLogService := services.NewMCPMessageLogService(s.db.GetDB(), namespaceService)
+	mcpMessageLogHandler := handlers.NewMCPMessageLogHandler(mcpMessageLogService)
 
 	// Initialize inspector service
 	inspectorService := inspector.NewService(transportManager)
@@ -167,23 +528,90 @@ func (s *Server) RegisterRoutes() http.Handler {
 	// Initialize handlers
 	mcpDiscoveryHandler := handlers.NewMCPDiscoveryHandler(mcpDiscoveryService)
 	gatewayHandler := handlers.NewGatewayHandler(discoveryService)
+	serverGroupHandler := handlers.NewServerGroupHandler(discoveryService.ServerGroups())
+	mockServerHandler := handlers.NewMockServerHandler(mockServerService)
+	serverRecordingHandler := handlers.NewServerRecordingHandler(serverRecordingService)
+	headerPropagationHandler := handlers.NewHeaderPropagationHandler(headerPropagationService)
+	identityInjectionHandler := handlers.NewIdentityInjectionHandler(identityInjectionService)
+	dlpHandler := handlers.NewDLPHandler(dlpService)
 	virtualAdminHandler := handlers.NewVirtualAdminHandler(virtualService)
 	virtualMCPHandler := handlers.NewVirtualMCPHandler(virtualService)
 	a2aHandler := handlers.NewA2AHandler(a2aService, a2aClient, a2aAdapter)
 	namespaceHandler := handlers.NewNamespaceHandler(namespaceService)
+
+	// Namespace grants restrict namespaces to the users, teams and roles granted
+	// access to them
+	namespaceAccessService := services.NewNamespaceAccessService(s.db.GetDB())
+	namespaceHandler.SetNamespaceAccess(namespaceAccessService)
+	// Tool executions can stream the upstream server's progress and be
+	// polled by job ID
+	namespaceHandler.SetToolStreams(services.NewToolStreamService(namespaceService, 0))
+	// Long-running tool executions can be queued as jobs that the worker
+	// runs with per-tool retries and timeouts
+	toolJobHandler := handlers.NewToolJobHandler(services.NewToolJobService(s.db.GetDB(), namespaceService))
+	namespaceGrantHandler := handlers.NewNamespaceGrantHandler(namespaceAccessService)
+
+	// Teams share roles, namespace grants and API keys among their members
+	teamService := services.NewTeamService(s.db.GetDB())
+	teamHandler := handlers.NewTeamHandler(teamService)
+
+	// Role grants raise a user's role until they expire; the worker expires
+	// them and sends reminders
+	accessGrantService := services.NewAccessGrantService(s.db.GetDB(), s.cfg.Notifications.GrantReminderBefore)
+	roleGrantHandler := handlers.NewRoleGrantHandler(accessGrantService)
+	resourceOwnerHandler := handlers.NewResourceOwnerHandler(services.NewResourceOwnerService(s.db.GetDB()))
 	inspectorHandler := handlers.NewInspectorHandler(inspectorService)
 
+	// Console and inspector tool calls are kept in each user's playground history
+	playgroundHistoryService := services.NewPlaygroundHistoryService(s.db.GetDB())
+	playgroundHistoryHandler := handlers.NewPlaygroundHistoryHandler(playgroundHistoryService)
+	inspectorHandler.SetHistory(playgroundHistoryService)
+
+	// Inspector sessions capture raw JSON-RPC frames; request collections are
+	// saved per server so debugging steps can be replayed
+	inspectorCollectionService := services.NewInspectorCollectionService(s.db.GetDB())
+	inspectorCollectionHandler := handlers.NewInspectorCollectionHandler(inspectorCollectionService)
+	inspectorHandler.SetFrames(inspectorService)
+	inspectorHandler.SetCollections(inspectorCollectionService)
+	inspectorHandler.SetBroadcast(inspectorService, namespaceService)
+
 	// Initialize config service
 	configService := config.NewService(s.db.GetDB())
+	configService.SetEvents(webhookService)
 
 	// Initialize auth config service
 	authConfigService := auth.NewConfigService(s.db.GetDB())
 
 	// Initialize admin handler (for logging and system management)
 	adminHandler := handlers.NewAdminHandler(nil, s.logging.(*logging.Service), configService, authConfigService)
+	adminHandler.SetStatsSource(services.NewAdminStatsService(s.db.GetDB(), s.cfg.Observability.Stats.GetStaleAfter()))
+	if s.prometheus != nil {
+		// Admin auth guards this route, so the scrape token is not needed
+		adminHandler.SetMetricsHandler(s.prometheus.Handler(""))
+	}
+
+	// Initialize audit handler; the logging service persists audit records to the same chain
+	auditService := logging.NewAuditService(s.db.GetDB())
+	if s.cfg.Logging.AuditLogging {
+		s.logging.(*logging.Service).SetAuditStore(auditService)
+	}
+	auditHandler := handlers.NewAuditHandler(auditService)
+
+	// Audit records streamed to each organization's SIEM sinks by the worker
+	auditExportService := logging.NewAuditExportService(s.db.GetDB())
+	auditExportService.SetEgressPolicy(offlinePolicy)
+	auditSinkHandler := handlers.NewAuditSinkHandler(auditExportService)
+
+	// Saved log and audit queries, shareable within the organization, with
+	// pinned dashboard widgets
+	logViewHandler := handlers.NewLogViewHandler(services.NewLogViewService(s.db.GetDB(), s.logging.(*logging.Service), auditService))
+
+	// Initialize security posture handler
+	securityPostureHandler := handlers.NewSecurityPostureHandler(services.NewSecurityPostureService(s.db.GetDB(), authConfigService))
 
 	// Initialize policy handler
 	policyHandler := handlers.NewPolicyHandler(s.db.GetDB())
+	policyHandler.SetToolPolicies(toolPolicyService)
 
 	// Initialize filters handler
 	filtersHandler := handlers.NewFiltersHandler(s.db.GetDB(), pluginService)
@@ -203,6 +631,12 @@ func (s *Server) RegisterRoutes() http.Handler {
 		AccessTokenExpiry:  s.cfg.Auth.AccessTokenExpiry,
 		RefreshTokenExpiry: s.cfg.Auth.RefreshTokenExpiry,
 		BCryptCost:         s.cfg.Auth.BCryptCost,
+		PasswordAlgorithm:  s.cfg.Auth.PasswordHashing.Algorithm,
+		Argon2: auth.Argon2Params{
+			Memory:      s.cfg.Auth.PasswordHashing.Argon2.Memory,
+			Iterations:  s.cfg.Auth.PasswordHashing.Argon2.Iterations,
+			Parallelism: s.cfg.Auth.PasswordHashing.Argon2.Parallelism,
+		},
 	}
 
 	// Set defaults if not configured
@@ -224,14 +658,51 @@ func (s *Server) RegisterRoutes() http.Handler {
 		authConfig.BCryptCost = 12
 	}
 
+	// Bulk CSV and Parquet log exports, written by the worker and downloaded
+	// through signed links
+	exportsCfg := s.cfg.Logging.Exports
+	logExportService := services.NewLogExportService(s.db.GetDB(), s.logging.(*logging.Service),
+		services.NewLimitsService(s.db.GetDB(), nil), services.NewFileExportStorage(exportsCfg.GetStorageDir()),
+		exportsCfg.PlanLimits())
+	logExportService.SetSigning(authConfig.JWTSecret, baseURL, exportsCfg.GetLinkTTL())
+	logExportHandler := handlers.NewLogExportHandler(logExportService)
+
+	// Execution logs and session events past an organization's archive
+	// policy are moved to cold storage by the worker; rehydrations restore
+	// archived days for a while
+	archiveCfg := s.cfg.Logging.Archive
+	archiveStorage, err := services.NewArchiveStorage(archiveCfg.Storage, archiveCfg.GetStorageDir(), archiveCfg.S3Config(), offlinePolicy)
+	if err != nil {
+		log.Printf("Warning: invalid archive storage, using %s: %v", archiveCfg.GetStorageDir(), err)
+		archiveStorage = services.NewFileExportStorage(archiveCfg.GetStorageDir())
+	}
+	archiveHandler := handlers.NewArchiveHandler(services.NewArchiveService(s.db.GetDB(), archiveStorage, archiveCfg.GetRehydrationTTL()))
+
+	// Retention per type of log, enforced by the worker, which exports the
+	// rows to the organization's bucket first when it asked for that
+	retentionCfg := s.cfg.Logging.RetentionEnforcement
+	retentionService := services.NewRetentionService(s.db.GetDB(), retentionCfg.BatchSize, retentionCfg.DryRun)
+	retentionService.SetEgressPolicy(offlinePolicy)
+	logRetentionHandler := handlers.NewLogRetentionHandler(retentionService)
+
 	authService := auth.NewService(s.db.GetDB(), authConfig)
+	authService.SetSeatLimiter(licenseManager)
 	authHandler := handlers.NewAuthHandler(authService)
 
 	// Initialize OAuth service
 	oauthConfig := auth.DefaultOAuthConfig()
 	oauthConfig.Issuer = baseURL
 	oauthService := auth.NewOAuthService(sqlx.NewDb(s.db.GetDB(), "postgres"), s.cfg.Auth.JWTSecret, baseURL, oauthConfig)
+	oauthService.SetPasswordHasher(auth.NewPasswordHasher(authConfig.PasswordAlgorithm, authConfig.BCryptCost, authConfig.Argon2))
+	signingKeys, err := loadTokenSigningKeys(&s.cfg.Auth.TokenSigning)
+	if err != nil {
+		panic(fmt.Sprintf("invalid token signing configuration: %v", err))
+	}
+	if signingKeys != nil {
+		oauthService.SetSigningKeys(signingKeys)
+	}
 	oauthHandler := handlers.NewOAuthHandler(oauthService)
+	oauthHandler.SetEndpointResolver(endpointService)
 
 	// OAuth 2.0 Discovery endpoints (no authentication required)
 	r.GET("/.well-known/oauth-authorization-server", oauthHandler.DiscoverAuthorizationServer)
@@ -239,8 +710,110 @@ func (s *Server) RegisterRoutes() http.Handler {
 	r.GET("/.well-known/oauth-authorization-server/*path", oauthHandler.DiscoverAuthorizationServer) // Handle path variations
 	r.GET("/.well-known/oauth-protected-resource/*path", oauthHandler.DiscoverProtectedResource)     // Handle path variations
 
+	// MCP gateway discovery so clients can auto-configure transports and auth
+	gatewayDiscoveryHandler := handlers.NewGatewayDiscoveryHandler(endpointService, baseURL)
+	r.GET("/.well-known/mcp-gateway", gatewayDiscoveryHandler.Discover)
+	r.GET("/.well-known/mcp-gateway/:endpoint_name", gatewayDiscoveryHandler.DiscoverEndpoint)
+
+	// Sealed break-glass credentials open audited, time-boxed platform-admin
+	// sessions in an emergency
+	breakGlassService := services.NewBreakGlassService(s.db.GetDB(), authService.GetJWTManager(), s.cfg.Auth.BreakGlassWindow)
+	breakGlassService.SetNotifier(notificationService)
+	breakGlassService.SetAuditor(logging.NewAuditService(s.db.GetDB()))
+	breakGlassHandler := handlers.NewBreakGlassHandler(breakGlassService)
+
+	// Members of an organization sign in through its OIDC identity
+	// providers, which provision their users and map IdP groups to roles
+	ssoService := services.NewSSOService(s.db.GetDB(), authService, baseURL+"/api/auth/sso/callback", s.cfg.Auth.SSO.StateTTL)
+	ssoService.SetSeatLimiter(licenseManager)
+	ssoService.SetAuditor(logging.NewAuditService(s.db.GetDB()))
+	ssoService.SetTeamSync(teamService)
+	ssoHandler := handlers.NewSSOHandler(ssoService, s.cfg.Auth.SSO.GetRedirectURL(baseURL))
+
+	// Admins manage their organization's users and invite them by email
+	userAdminService := services.NewUserAdminService(s.db.GetDB(),
+		auth.NewPasswordHasher(authConfig.PasswordAlgorithm, authConfig.BCryptCost, authConfig.Argon2),
+		baseURL, s.cfg.Auth.InvitationTTL, s.cfg.Auth.PasswordResetTTL)
+	userAdminService.SetSeatLimiter(licenseManager)
+	userAdminService.SetAuditor(logging.NewAuditService(s.db.GetDB()))
+	if smtpMailer != nil {
+		userAdminService.SetMailer(smtpMailer)
+	}
+	userHandler := handlers.NewUserHandler(userAdminService)
+
+	// Signed inbound calls must carry a timestamp and nonce, and are accepted
+	// once; Redis shares the nonces between gateway instances
+	replayCfg := s.cfg.Auth.ReplayProtection
+	var replayGuard *services.ReplayGuard
+	if replayCfg.Store == "redis" {
+		nonceStore, err := auth.NewRedisNonceStore(fmt.Sprintf("%s:%d", s.cfg.Redis.Host, s.cfg.Redis.Port),
+			s.cfg.Redis.Password, s.cfg.Redis.Database)
+		if err != nil {
+			log.Printf("Warning: replay protection cannot reach Redis, remembering nonces in the database: %v", err)
+		} else {
+			replayGuard = services.NewReplayGuardWithStore(nonceStore, replayCfg.ClockSkew, !replayCfg.AllowUnstamped)
+		}
+	}
+	if replayGuard == nil {
+		replayGuard = services.NewReplayGuard(s.db.GetDB(), replayCfg.ClockSkew, !replayCfg.AllowUnstamped)
+	}
+	replayGuard.SetMetricEmitter(s.logging.(*logging.Service).EmitMetric)
+
+	// Rate limits are counted in Redis when it is the configured storage, so
+	// every gateway instance enforces the same limits
+	var requestLimiter ratelimit.Limiter = ratelimit.NewMemoryLimiter()
+	if s.cfg.RateLimit.Storage == "redis" {
+		redisLimiter, err := ratelimit.NewRedisLimiter(fmt.Sprintf("%s:%d", s.cfg.Redis.Host, s.cfg.Redis.Port),
+			s.cfg.Redis.Password, s.cfg.Redis.Database)
+		if err != nil {
+			log.Printf("Warning: rate limiting cannot reach Redis, counting requests per instance: %v", err)
+		} else {
+			requestLimiter = redisLimiter
+		}
+	}
+	rateLimitService := services.NewRateLimitService(s.db.GetDB(), requestLimiter)
+	// Tools can cap the calls of their organization with their own rate
+	// limits and daily budgets
+	toolLimitService := services.NewToolLimitService(s.db.GetDB(), requestLimiter)
+	namespaceService.SetToolLimits(toolLimitService)
+	toolHandler.SetToolLimits(toolLimitService)
+	// Organizations' metered usage and the monthly budgets that alert on it
+	// and can stop non-critical traffic
+	s.metering = services.NewMeteringService(s.db.GetDB(), 0)
+	s.metering.Start()
+	usageBudgetService := services.NewUsageBudgetService(s.db.GetDB())
+	namespaceService.SetUsageMeter(s.metering)
+	namespaceService.SetBudgets(usageBudgetService)
+	usageBudgetHandler := handlers.NewUsageBudgetHandler(usageBudgetService)
+	rateLimitRuleHandler := handlers.NewRateLimitRuleHandler(rateLimitService)
+	var rateLimitChecker middleware.RateLimitChecker
+	if s.cfg.RateLimit.Enabled {
+		rateLimitChecker = rateLimitService
+	}
+
+	// Credentials reported as leaked by admins or a threat-intel feed are
+	// revoked and their owners notified
+	compromisedService := services.NewCompromisedCredentialService(s.db.GetDB(), authService.GetJWTManager(), s.cfg.Auth.ThreatFeedSecret)
+	compromisedService.SetNotifier(notificationService)
+	compromisedService.SetAuditor(logging.NewAuditService(s.db.GetDB()))
+	compromisedService.SetReplayGuard(replayGuard)
+	compromisedHandler := handlers.NewCompromisedCredentialHandler(compromisedService)
+
+	// Service accounts are non-human principals acting through their own API
+	// keys and OAuth clients
+	serviceAccountHandler := handlers.NewServiceAccountHandler(services.NewServiceAccountService(s.db.GetDB(), authService, oauthService))
+
+	// Delegation policies let trusted service accounts act on behalf of users
+	delegationService := services.NewDelegationService(s.db.GetDB())
+	delegationHandler := handlers.NewDelegationHandler(delegationService)
+
 	// Initialize auth middleware
 	authMiddleware := auth.NewMiddleware(authService.GetJWTManager(), authService)
+	authMiddleware.SetPlatformAdmins(s.cfg.Auth.PlatformAdmins)
+	authMiddleware.SetNamespaceAccess(namespaceAccessService)
+	authMiddleware.SetTeams(teamService)
+	authMiddleware.SetRoleGrants(accessGrantService)
+	authMiddleware.SetBreakGlass(breakGlassService)
 
 	// Initialize transport handlers
 	rpcHandler := handlers.NewRPCHandler(transportManager, discoveryService, virtualService)
@@ -268,15 +841,122 @@ func (s *Server) RegisterRoutes() http.Handler {
 	// Dynamic client registration endpoint (also accessible outside /oauth path)
 	r.POST("/register", oauthHandler.RegisterClient)
 
+	// Read-only mode rejects management writes under /api; MCP traffic,
+	// sign-in and the toggle itself stay available
+	readOnlyMode := middleware.NewReadOnlyMode(s.cfg.Gateway.ReadOnly, readOnlyExemptRoutes...)
+	// Mode set through the API is shared by every instance and survives
+	// restarts
+	if err := readOnlyMode.SetStore(models.NewReadOnlyStateModel(s.db.GetDB())); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	go readOnlyMode.Watch(context.Background(), 10*time.Second)
+	readOnlyHandler := handlers.NewReadOnlyHandler(readOnlyMode, s.logging.(*logging.Service))
+	if readOnlyMode.Enabled() {
+		log.Printf("Gateway starting in read-only mode; management changes are disabled")
+	}
+
+	// Rate limits, plan quotas and usage for the calling principal
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	versionHandler := handlers.NewVersionHandler(featureFlagService)
+	licenseHandler := handlers.NewLicenseHandler(licenseManager)
+	offlineHandler := handlers.NewOfflineHandler(offlinePolicy)
+	telemetryCfg := s.cfg.Telemetry
+	telemetryReporter := telemetry.NewReporter(telemetry.NewDBSource(s.db.GetDB()),
+		telemetryCfg.Enabled, telemetryCfg.Endpoint, telemetryCfg.Interval, offlinePolicy)
+	telemetryHandler := handlers.NewTelemetryHandler(telemetryReporter)
+
+	// Catalog replication between the regions of an active-active deployment
+	regionCfg := s.cfg.Region
+	regionPeers := make([]services.RegionPeer, 0, len(regionCfg.Peers))
+	for _, peer := range regionCfg.Peers {
+		regionPeers = append(regionPeers, services.RegionPeer{Name: peer.Name, URL: peer.URL})
+	}
+	regionSettings := services.RegionSettings{
+		Region:    regionCfg.Name,
+		BaseURL:   baseURL,
+		GlobalURL: regionCfg.GlobalURL,
+		Token:     regionCfg.ReplicationToken,
+		Peers:     regionPeers,
+		BatchSize: regionCfg.GetBatchSize(),
+		Interval:  regionCfg.Interval,
+	}
+	regionHandler := handlers.NewRegionHandler(services.NewRegionService(s.db.GetDB(), regionSettings))
+
+	// Disaster-recovery failover. Every instance follows the deployment
+	// state: a fence makes it read-only, and any change drops its caches.
+	failoverService := services.NewFailoverService(s.db.GetDB(), regionSettings, regionCfg.Role)
+	failoverService.SetAuditor(logging.NewAuditService(s.db.GetDB()))
+	serverOwnerService.SetActiveCheck(failoverService.IsActive)
+	failoverHandler := handlers.NewFailoverHandler(failoverService)
+	go failoverService.Watch(context.Background(), regionCfg.Interval, func(state *types.DeploymentState) {
+		readOnlyMode.SetFailover(state.ReadOnly, state.ReadOnlyReason, state.ChangedBy)
+		if listCache != nil {
+			if _, err := listCache.FlushAll(context.Background()); err != nil {
+				log.Printf("Warning: failed to flush list cache after failover: %v", err)
+			}
+		}
+		virtualService.ClearCache()
+		a2aService.ClearCache()
+		log.Printf("Deployment is %s at epoch %d", state.Role, state.Epoch)
+	})
+
+	// Every API instance heartbeats into the node registry. A drained
+	// instance fails /readyz and takes no new sessions.
+	hostname, _ := os.Hostname()
+	nodesCfg := s.cfg.Gateway.Nodes
+	s.nodes = services.NewNodeRegistry(s.db.GetDB(), services.NodeSettings{
+		ID:         transportManager.InstanceID(),
+		Hostname:   hostname,
+		Address:    nodesCfg.Address,
+		Region:     regionCfg.Name,
+		Version:    buildinfo.Get().Version,
+		Interval:   nodesCfg.HeartbeatInterval,
+		StaleAfter: nodesCfg.StaleAfter,
+	}, transportManager, s.health)
+	transportManager.SetDrainCheck(s.nodes.Draining)
+	s.nodes.Start()
+	nodeHandler := handlers.NewNodeHandler(s.nodes)
+
+	// Legal holds suspend deletion of an organization's logs and audit records
+	legalHoldService := services.NewLegalHoldService(s.db.GetDB())
+	legalHoldHandler := handlers.NewLegalHoldHandler(legalHoldService, s.logging.(*logging.Service))
+	legalHoldGuard := middleware.LegalHoldGuard(legalHoldService)
+	limitsHandler := handlers.NewLimitsHandler(services.NewLimitsService(s.db.GetDB(), s.logging.(*logging.Service)))
+
+	// Per-class queueing and shedding statistics
+	schedulerHandler := handlers.NewSchedulerHandler(toolScheduler, connectionBackpressure)
+	if loadLimiter != nil {
+		schedulerHandler.SetLoadShedding(loadLimiter)
+	}
+
+	// Feature flags gate new subsystems per organization
+	featureFlagHandler := handlers.NewFeatureFlagHandler(featureFlagService, readOnlyMode, s.logging.(*logging.Service))
+
+	// Dynamic client registration policy and initial access tokens
+	oauthRegistrationHandler := handlers.NewOAuthRegistrationHandler(oauthService, s.logging.(*logging.Service))
+
 	// API routes
 	api := r.Group("/api")
+	api.Use(readOnlyMode.Handler())
 	{
+		// Peer regions read catalog changes with the replication token
+		api.GET("/replication/changes", regionHandler.ListChanges)
+		api.POST("/replication/fence", failoverHandler.Fence)
+
 		// Authentication routes
 		auth := api.Group("/auth")
 		{
 			// Public routes (no auth required)
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.POST("/break-glass", breakGlassHandler.Activate)
+			auth.POST("/threat-feed", compromisedHandler.ThreatFeed)
+			auth.GET("/sso/providers", ssoHandler.ListLoginProviders)
+			auth.GET("/sso/:id/login", ssoHandler.Login)
+			auth.GET("/sso/callback", ssoHandler.Callback)
+			auth.POST("/invitations/accept", userHandler.AcceptInvitation)
+			auth.POST("/password-reset", userHandler.RequestPasswordReset)
+			auth.POST("/password-reset/confirm", userHandler.ResetPassword)
 
 			// Protected routes (auth required)
 			authenticatedChain := middleware.AuthenticatedChain().Use(authMiddleware.RequireAuth())
@@ -289,9 +969,50 @@ func (s *Server) RegisterRoutes() http.Handler {
 				protected.POST("/api-keys", authHandler.CreateAPIKey)
 				protected.GET("/api-keys", authHandler.ListAPIKeys)
 				protected.DELETE("/api-keys/:id", authHandler.DeleteAPIKey)
+				protected.PUT("/api-keys/:id/restrictions", authHandler.UpdateAPIKeyRestrictions)
+				protected.PUT("/api-keys/:id/scope", authHandler.UpdateAPIKeyScope)
+				protected.GET("/teams", teamHandler.ListMyTeams)
+				protected.GET("/limits", limitsHandler.GetLimits)
 			}
 		}
 
+		// Deployed build and release notes
+		versionChain := middleware.AuthenticatedChain().
+			Use(authMiddleware.RequireAuth())
+		versionGroup := api.Group("")
+		versionChain.Apply(versionGroup)
+		{
+			versionGroup.GET("/version", versionHandler.GetVersion)
+			versionGroup.GET("/changelog", versionHandler.GetChangelog)
+		}
+
+		// Notification center for the calling user
+		notificationChain := middleware.AuthenticatedChain().
+			Use(authMiddleware.RequireAuth())
+		notifications := api.Group("/notifications")
+		notificationChain.Apply(notifications)
+		{
+			notifications.GET("", notificationHandler.List)
+			notifications.GET("/unread-count", notificationHandler.UnreadCount)
+			notifications.POST("/read-all", notificationHandler.MarkAllRead)
+			notifications.POST("/:id/read", notificationHandler.MarkRead)
+			notifications.GET("/preferences", notificationHandler.GetPreferences)
+			notifications.PUT("/preferences", notificationHandler.UpdatePreferences)
+		}
+
+		// Playground execution history of the calling user
+		playgroundChain := middleware.AuthenticatedChain().
+			Use(authMiddleware.RequireAuth()).
+			Use(authMiddleware.RequireOrganizationAccess())
+		playground := api.Group("/playground")
+		playgroundChain.Apply(playground)
+		{
+			playground.GET("/history", playgroundHistoryHandler.ListHistory)
+			playground.GET("/history/:id", playgroundHistoryHandler.GetExecution)
+			playground.DELETE("/history/:id", playgroundHistoryHandler.DeleteExecution)
+			playground.DELETE("/history", playgroundHistoryHandler.ClearHistory)
+		}
+
 		// MCP Discovery routes (require authentication and read permission)
 		mcpChain := middleware.AuthenticatedChain().
 			Use(authMiddleware.RequireAuth()).
@@ -309,7 +1030,8 @@ func (s *Server) RegisterRoutes() http.Handler {
 		// Gateway management routes (protected)
 		gatewayChain := middleware.AuthenticatedChain().
 			Use(authMiddleware.RequireAuth()).
-			Use(authMiddleware.RequireOrganizationAccess())
+			Use(authMiddleware.RequireOrganizationAccess()).
+			Use(middleware.RuleRateLimit(rateLimitChecker))
 		gateway := api.Group("/gateway")
 		gatewayChain.Apply(gateway)
 		{
@@ -320,6 +1042,7 @@ func (s *Server) RegisterRoutes() http.Handler {
 			gateway.POST("/servers",
 				authMiddleware.RequireResourceAccess("server", "write"),
 				loggingMiddleware.AuditLogger("register", "server"),
+				middleware.InvalidateListings(listCache),
 				gatewayHandler.RegisterServer)
 			gateway.GET("/servers/:id",
 				authMiddleware.RequireResourceAccess("server", "read"),
@@ -327,19 +1050,156 @@ func (s *Server) RegisterRoutes() http.Handler {
 			gateway.PUT("/servers/:id",
 				authMiddleware.RequireResourceAccess("server", "write"),
 				loggingMiddleware.AuditLogger("update", "server"),
+				middleware.InvalidateListings(listCache),
 				gatewayHandler.UpdateServer)
 			gateway.DELETE("/servers/:id",
 				authMiddleware.RequireResourceAccess("server", "delete"),
 				loggingMiddleware.AuditLogger("unregister", "server"),
+				middleware.InvalidateListings(listCache),
 				gatewayHandler.UnregisterServer)
 			gateway.GET("/servers/:id/stats",
 				authMiddleware.RequireResourceAccess("server", "read"),
 				gatewayHandler.GetServerStats)
+			gateway.GET("/servers/:id/logs",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				serverLogsHandler.GetLogs)
+			gateway.GET("/servers/:id/owner",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				serverOwnerHandler.GetOwner)
+			gateway.PUT("/servers/:id/owner",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("set_owner", "server"),
+				serverOwnerHandler.SetOwner)
+			gateway.DELETE("/servers/:id/owner",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("remove_owner", "server"),
+				serverOwnerHandler.DeleteOwner)
+			gateway.GET("/servers/:id/owner/alerts",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				serverOwnerHandler.ListAlerts)
+			gateway.POST("/servers/:id/owner/alerts/:alert_id/acknowledge",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				loggingMiddleware.AuditLogger("acknowledge_alert", "server"),
+				serverOwnerHandler.AcknowledgeAlert)
+			gateway.GET("/servers/:id/health-history",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				incidentHandler.GetServerHealthHistory)
 			gateway.POST("/servers/:id/discover-tools",
 				authMiddleware.RequireResourceAccess("server", "write"),
 				loggingMiddleware.AuditLogger("discover_tools", "server"),
+				middleware.InvalidateListings(listCache),
 				gatewayHandler.DiscoverServerTools)
 
+			// Canned tools of mock servers
+			gateway.GET("/servers/:id/mock-tools",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				mockServerHandler.ListTools)
+			gateway.POST("/servers/:id/mock-tools",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("create_mock_tool", "server"),
+				mockServerHandler.CreateTool)
+			gateway.GET("/servers/:id/mock-tools/:tool_id",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				mockServerHandler.GetTool)
+			gateway.PUT("/servers/:id/mock-tools/:tool_id",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("update_mock_tool", "server"),
+				mockServerHandler.UpdateTool)
+			gateway.DELETE("/servers/:id/mock-tools/:tool_id",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("delete_mock_tool", "server"),
+				mockServerHandler.DeleteTool)
+
+			// Recording upstream responses and replaying them in place of
+			// the server
+			gateway.GET("/servers/:id/recording",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				serverRecordingHandler.GetStatus)
+			gateway.PUT("/servers/:id/recording",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("set_recording_mode", "server"),
+				serverRecordingHandler.SetMode)
+			gateway.GET("/servers/:id/recordings",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				serverRecordingHandler.ListRecordings)
+			gateway.POST("/servers/:id/recordings",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("import_recordings", "server"),
+				serverRecordingHandler.ImportRecordings)
+			gateway.DELETE("/servers/:id/recordings",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("clear_recordings", "server"),
+				serverRecordingHandler.ClearRecordings)
+			gateway.DELETE("/servers/:id/recordings/:recording_id",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("delete_recording", "server"),
+				serverRecordingHandler.DeleteRecording)
+
+			// Caller headers propagated to the server
+			gateway.GET("/servers/:id/header-propagation",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				headerPropagationHandler.GetServerRules)
+			gateway.PUT("/servers/:id/header-propagation",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("set_header_propagation", "server"),
+				headerPropagationHandler.SetServerRules)
+			gateway.DELETE("/servers/:id/header-propagation",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("delete_header_propagation", "server"),
+				headerPropagationHandler.DeleteServerRules)
+
+			// Caller identity asserted to the server on tool calls
+			gateway.GET("/servers/:id/identity-injection",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				identityInjectionHandler.GetSettings)
+			gateway.PUT("/servers/:id/identity-injection",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("set_identity_injection", "server"),
+				identityInjectionHandler.SetSettings)
+			gateway.DELETE("/servers/:id/identity-injection",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("delete_identity_injection", "server"),
+				identityInjectionHandler.DeleteSettings)
+			gateway.GET("/servers/:id/dlp-policy",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				dlpHandler.GetPolicy)
+			gateway.PUT("/servers/:id/dlp-policy",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("set_dlp_policy", "server"),
+				dlpHandler.SetPolicy)
+			gateway.DELETE("/servers/:id/dlp-policy",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("delete_dlp_policy", "server"),
+				dlpHandler.DeletePolicy)
+
+			// Server groups balance calls over replicas of a server
+			gateway.GET("/server-groups",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				serverGroupHandler.ListGroups)
+			gateway.POST("/server-groups",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("create", "server-group"),
+				serverGroupHandler.CreateGroup)
+			gateway.GET("/server-groups/:id",
+				authMiddleware.RequireResourceAccess("server", "read"),
+				serverGroupHandler.GetGroup)
+			gateway.PUT("/server-groups/:id",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("update", "server-group"),
+				serverGroupHandler.UpdateGroup)
+			gateway.DELETE("/server-groups/:id",
+				authMiddleware.RequireResourceAccess("server", "delete"),
+				loggingMiddleware.AuditLogger("delete", "server-group"),
+				serverGroupHandler.DeleteGroup)
+			gateway.PUT("/server-groups/:id/members",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("set_member", "server-group"),
+				serverGroupHandler.SetMember)
+			gateway.DELETE("/server-groups/:id/members/:server_id",
+				authMiddleware.RequireResourceAccess("server", "write"),
+				loggingMiddleware.AuditLogger("remove_member", "server-group"),
+				serverGroupHandler.RemoveMember)
+
 			// MCP session management - requires session permissions
 			gateway.POST("/sessions",
 				authMiddleware.RequireResourceAccess("session", "write"),
@@ -359,10 +1219,12 @@ func (s *Server) RegisterRoutes() http.Handler {
 			// Resource management - requires resource permissions
 			gateway.GET("/resources",
 				authMiddleware.RequireResourceAccess("resource", "read"),
+				middleware.CacheListings(listCache, cache.KindResources),
 				resourceHandler.ListResources)
 			gateway.POST("/resources",
 				authMiddleware.RequireResourceAccess("resource", "write"),
 				loggingMiddleware.AuditLogger("create", "resource"),
+				middleware.InvalidateListings(listCache),
 				resourceHandler.CreateResource)
 			gateway.GET("/resources/:id",
 				authMiddleware.RequireResourceAccess("resource", "read"),
@@ -370,19 +1232,23 @@ func (s *Server) RegisterRoutes() http.Handler {
 			gateway.PUT("/resources/:id",
 				authMiddleware.RequireResourceAccess("resource", "write"),
 				loggingMiddleware.AuditLogger("update", "resource"),
+				middleware.InvalidateListings(listCache),
 				resourceHandler.UpdateResource)
 			gateway.DELETE("/resources/:id",
 				authMiddleware.RequireResourceAccess("resource", "delete"),
 				loggingMiddleware.AuditLogger("delete", "resource"),
+				middleware.InvalidateListings(listCache),
 				resourceHandler.DeleteResource)
 
 			// Prompt management - requires prompt permissions
 			gateway.GET("/prompts",
 				authMiddleware.RequireResourceAccess("prompt", "read"),
+				middleware.CacheListings(listCache, cache.KindPrompts),
 				promptHandler.ListPrompts)
 			gateway.POST("/prompts",
 				authMiddleware.RequireResourceAccess("prompt", "write"),
 				loggingMiddleware.AuditLogger("create", "prompt"),
+				middleware.InvalidateListings(listCache),
 				promptHandler.CreatePrompt)
 			gateway.GET("/prompts/:id",
 				authMiddleware.RequireResourceAccess("prompt", "read"),
@@ -390,10 +1256,12 @@ func (s *Server) RegisterRoutes() http.Handler {
 			gateway.PUT("/prompts/:id",
 				authMiddleware.RequireResourceAccess("prompt", "write"),
 				loggingMiddleware.AuditLogger("update", "prompt"),
+				middleware.InvalidateListings(listCache),
 				promptHandler.UpdatePrompt)
 			gateway.DELETE("/prompts/:id",
 				authMiddleware.RequireResourceAccess("prompt", "delete"),
 				loggingMiddleware.AuditLogger("delete", "prompt"),
+				middleware.InvalidateListings(listCache),
 				promptHandler.DeletePrompt)
 			gateway.POST("/prompts/:id/use",
 				authMiddleware.RequireResourceAccess("prompt", "read"),
@@ -402,10 +1270,12 @@ func (s *Server) RegisterRoutes() http.Handler {
 			// Tool management - requires tool permissions
 			gateway.GET("/tools",
 				authMiddleware.RequireResourceAccess("tool", "read"),
+				middleware.CacheListings(listCache, cache.KindTools),
 				toolHandler.ListTools)
 			gateway.POST("/tools",
 				authMiddleware.RequireResourceAccess("tool", "write"),
 				loggingMiddleware.AuditLogger("create", "tool"),
+				middleware.InvalidateListings(listCache),
 				toolHandler.CreateTool)
 			gateway.GET("/tools/:id",
 				authMiddleware.RequireResourceAccess("tool", "read"),
@@ -413,11 +1283,37 @@ func (s *Server) RegisterRoutes() http.Handler {
 			gateway.PUT("/tools/:id",
 				authMiddleware.RequireResourceAccess("tool", "write"),
 				loggingMiddleware.AuditLogger("update", "tool"),
+				middleware.InvalidateListings(listCache),
 				toolHandler.UpdateTool)
 			gateway.DELETE("/tools/:id",
 				authMiddleware.RequireResourceAccess("tool", "delete"),
 				loggingMiddleware.AuditLogger("delete", "tool"),
+				middleware.InvalidateListings(listCache),
 				toolHandler.DeleteTool)
+			gateway.GET("/tools/:id/limits",
+				authMiddleware.RequireResourceAccess("tool", "read"),
+				toolHandler.GetLimits)
+			gateway.PUT("/tools/:id/limits",
+				authMiddleware.RequireResourceAccess("tool", "write"),
+				loggingMiddleware.AuditLogger("set_limits", "tool"),
+				middleware.InvalidateListings(listCache),
+				toolHandler.SetLimits)
+			gateway.DELETE("/tools/:id/limits",
+				authMiddleware.RequireResourceAccess("tool", "write"),
+				loggingMiddleware.AuditLogger("delete_limits", "tool"),
+				middleware.InvalidateListings(listCache),
+				toolHandler.DeleteLimits)
+			gateway.GET("/tools/:id/job-settings",
+				authMiddleware.RequireResourceAccess("tool", "read"),
+				toolJobHandler.GetSettings)
+			gateway.PUT("/tools/:id/job-settings",
+				authMiddleware.RequireResourceAccess("tool", "write"),
+				loggingMiddleware.AuditLogger("set_job_settings", "tool"),
+				toolJobHandler.SetSettings)
+			gateway.DELETE("/tools/:id/job-settings",
+				authMiddleware.RequireResourceAccess("tool", "write"),
+				loggingMiddleware.AuditLogger("delete_job_settings", "tool"),
+				toolJobHandler.DeleteSettings)
 			gateway.POST("/tools/:id/execute",
 				authMiddleware.RequireResourceAccess("tool", "execute"),
 				toolHandler.ExecuteTool)
@@ -428,10 +1324,54 @@ func (s *Server) RegisterRoutes() http.Handler {
 				toolHandler.GetToolByFunction)
 		}
 
+		// Unified search across tools, prompts and resources
+		searchChain := middleware.AuthenticatedChain().
+			Use(authMiddleware.RequireAuth()).
+			Use(authMiddleware.RequireOrganizationAccess()).
+			Use(middleware.RuleRateLimit(rateLimitChecker))
+		search := api.Group("/search")
+		searchChain.Apply(search)
+		{
+			search.GET("",
+				authMiddleware.RequireResourceAccess("tool", "read"),
+				searchHandler.Search)
+		}
+
+		// Ownership of servers, tools, namespaces and endpoints
+		ownershipChain := middleware.AuthenticatedChain().
+			Use(authMiddleware.RequireAuth()).
+			Use(authMiddleware.RequireOrganizationAccess())
+		ownership := api.Group("/ownership")
+		ownershipChain.Apply(ownership)
+		{
+			ownership.GET("",
+				authMiddleware.RequirePermission(types.PermissionRead),
+				resourceOwnerHandler.ListOwners)
+			ownership.GET("/orphaned",
+				authMiddleware.RequirePermission(types.PermissionRead),
+				resourceOwnerHandler.ListOrphaned)
+			ownership.POST("/transfer",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("transfer", "ownership"),
+				resourceOwnerHandler.Transfer)
+			ownership.GET("/:resource_type/:resource_id",
+				authMiddleware.RequirePermission(types.PermissionRead),
+				resourceOwnerHandler.GetOwner)
+			ownership.PUT("/:resource_type/:resource_id",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("assign", "ownership"),
+				resourceOwnerHandler.SetOwner)
+			ownership.DELETE("/:resource_type/:resource_id",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("unassign", "ownership"),
+				resourceOwnerHandler.RemoveOwner)
+		}
+
 		// Namespace management routes (protected)
 		namespaceChain := middleware.AuthenticatedChain().
 			Use(authMiddleware.RequireAuth()).
-			Use(authMiddleware.RequireOrganizationAccess())
+			Use(authMiddleware.RequireOrganizationAccess()).
+			Use(middleware.RuleRateLimit(rateLimitChecker))
 		namespaces := api.Group("/namespaces")
 		namespaceChain.Apply(namespaces)
 		{
@@ -445,44 +1385,126 @@ func (s *Server) RegisterRoutes() http.Handler {
 				namespaceHandler.CreateNamespace)
 			namespaces.GET("/:id",
 				authMiddleware.RequireResourceAccess("namespace", "read"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessRead),
 				namespaceHandler.GetNamespace)
 			namespaces.PUT("/:id",
 				authMiddleware.RequireResourceAccess("namespace", "write"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessWrite),
 				loggingMiddleware.AuditLogger("update", "namespace"),
+				middleware.InvalidateListings(listCache),
 				namespaceHandler.UpdateNamespace)
 			namespaces.DELETE("/:id",
 				authMiddleware.RequireResourceAccess("namespace", "delete"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessAdmin),
 				loggingMiddleware.AuditLogger("delete", "namespace"),
+				legalHoldGuard,
+				middleware.InvalidateListings(listCache),
 				namespaceHandler.DeleteNamespace)
 
 			// Server mappings
 			namespaces.POST("/:id/servers",
 				authMiddleware.RequireResourceAccess("namespace", "write"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessWrite),
 				loggingMiddleware.AuditLogger("add-server", "namespace"),
+				middleware.InvalidateListings(listCache),
 				namespaceHandler.AddServerToNamespace)
 			namespaces.DELETE("/:id/servers/:server_id",
 				authMiddleware.RequireResourceAccess("namespace", "write"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessWrite),
 				loggingMiddleware.AuditLogger("remove-server", "namespace"),
+				middleware.InvalidateListings(listCache),
 				namespaceHandler.RemoveServerFromNamespace)
 			namespaces.PUT("/:id/servers/:server_id/status",
 				authMiddleware.RequireResourceAccess("namespace", "write"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessWrite),
 				loggingMiddleware.AuditLogger("update-server-status", "namespace"),
+				middleware.InvalidateListings(listCache),
 				namespaceHandler.UpdateServerStatus)
 
 			// Tool management
 			namespaces.GET("/:id/tools",
 				authMiddleware.RequireResourceAccess("namespace", "read"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessRead),
+				middleware.CacheListings(listCache, cache.KindTools),
 				namespaceHandler.GetNamespaceTools)
 			namespaces.PUT("/:id/tools/:tool_id/status",
 				authMiddleware.RequireResourceAccess("namespace", "write"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessWrite),
 				loggingMiddleware.AuditLogger("update-tool-status", "namespace"),
+				middleware.InvalidateListings(listCache),
 				namespaceHandler.UpdateToolStatus)
+			namespaces.PUT("/:id/tools/:tool_id/arguments",
+				authMiddleware.RequireResourceAccess("namespace", "write"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessWrite),
+				loggingMiddleware.AuditLogger("update-tool-arguments", "namespace"),
+				middleware.InvalidateListings(listCache),
+				namespaceHandler.UpdateToolArguments)
+			namespaces.PUT("/:id/tools/:tool_id/response-policy",
+				authMiddleware.RequireResourceAccess("namespace", "write"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessWrite),
+				loggingMiddleware.AuditLogger("update-tool-response-policy", "namespace"),
+				middleware.InvalidateListings(listCache),
+				namespaceHandler.UpdateToolResponsePolicy)
+
+			// Caller headers propagated to the namespace's servers
+			namespaces.GET("/:id/header-propagation",
+				authMiddleware.RequireResourceAccess("namespace", "read"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessRead),
+				headerPropagationHandler.GetNamespaceRules)
+			namespaces.PUT("/:id/header-propagation",
+				authMiddleware.RequireResourceAccess("namespace", "write"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessWrite),
+				loggingMiddleware.AuditLogger("set-header-propagation", "namespace"),
+				headerPropagationHandler.SetNamespaceRules)
+			namespaces.DELETE("/:id/header-propagation",
+				authMiddleware.RequireResourceAccess("namespace", "write"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessWrite),
+				loggingMiddleware.AuditLogger("delete-header-propagation", "namespace"),
+				headerPropagationHandler.DeleteNamespaceRules)
+
+			// Access grants
+			namespaces.GET("/:id/grants",
+				authMiddleware.RequireResourceAccess("namespace", "manage"),
+				namespaceGrantHandler.ListGrants)
+			namespaces.POST("/:id/grants",
+				authMiddleware.RequireResourceAccess("namespace", "manage"),
+				loggingMiddleware.AuditLogger("grant-access", "namespace"),
+				namespaceGrantHandler.CreateGrant)
+			namespaces.DELETE("/:id/grants/:grant_id",
+				authMiddleware.RequireResourceAccess("namespace", "manage"),
+				loggingMiddleware.AuditLogger("revoke-access", "namespace"),
+				namespaceGrantHandler.DeleteGrant)
 
 			// MCP operations
 			namespaces.POST("/:id/execute",
 				authMiddleware.RequireResourceAccess("namespace", "execute"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessExecute),
 				loggingMiddleware.AuditLogger("execute-tool", "namespace"),
 				namespaceHandler.ExecuteNamespaceTool)
+			namespaces.GET("/:id/executions/:job_id",
+				authMiddleware.RequireResourceAccess("namespace", "execute"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessExecute),
+				namespaceHandler.GetExecution)
+			namespaces.POST("/:id/jobs",
+				authMiddleware.RequireResourceAccess("namespace", "execute"),
+				authMiddleware.RequireNamespaceAccess(types.NamespaceAccessExecute),
+				loggingMiddleware.AuditLogger("enqueue-tool", "namespace"),
+				toolJobHandler.EnqueueJob)
+		}
+
+		// Queued tool executions; users see their own jobs and admins every
+		// job of the organization
+		jobChain := middleware.AuthenticatedChain().
+			Use(authMiddleware.RequireAuth()).
+			Use(authMiddleware.RequireOrganizationAccess())
+		jobs := api.Group("/jobs")
+		jobChain.Apply(jobs)
+		{
+			jobs.GET("", toolJobHandler.ListJobs)
+			jobs.GET("/:id", toolJobHandler.GetJob)
+			jobs.DELETE("/:id",
+				loggingMiddleware.AuditLogger("cancel", "job"),
+				toolJobHandler.CancelJob)
 		}
 
 		// Inspector routes (protected)
@@ -509,6 +1531,57 @@ func (s *Server) RegisterRoutes() http.Handler {
 			inspectorGroup.POST("/sessions/:id/request",
 				authMiddleware.RequireResourceAccess("inspector", "execute"),
 				inspectorHandler.ExecuteRequest)
+			inspectorGroup.POST("/sessions/:id/history/:execution_id/rerun",
+				authMiddleware.RequireResourceAccess("inspector", "execute"),
+				inspectorHandler.RerunExecution)
+
+			// Broadcasting to the other servers of a session's namespace
+			inspectorGroup.POST("/sessions/:id/servers",
+				authMiddleware.RequireResourceAccess("inspector", "write"),
+				inspectorHandler.AttachServers)
+			inspectorGroup.DELETE("/sessions/:id/servers/:server_id",
+				authMiddleware.RequireResourceAccess("inspector", "write"),
+				inspectorHandler.DetachServer)
+			inspectorGroup.POST("/sessions/:id/broadcast",
+				authMiddleware.RequireResourceAccess("inspector", "execute"),
+				inspectorHandler.Broadcast)
+
+			// Raw protocol frames
+			inspectorGroup.GET("/sessions/:id/frames",
+				authMiddleware.RequireResourceAccess("inspector", "read"),
+				inspectorHandler.ListFrames)
+			inspectorGroup.GET("/sessions/:id/frames/:frame_id",
+				authMiddleware.RequireResourceAccess("inspector", "read"),
+				inspectorHandler.GetFrame)
+			inspectorGroup.POST("/sessions/:id/raw",
+				authMiddleware.RequireResourceAccess("inspector", "execute"),
+				inspectorHandler.SendRaw)
+			inspectorGroup.POST("/sessions/:id/frames/:frame_id/resend",
+				authMiddleware.RequireResourceAccess("inspector", "execute"),
+				inspectorHandler.ResendFrame)
+
+			// Request collections
+			inspectorGroup.GET("/servers/:id/collections",
+				authMiddleware.RequireResourceAccess("inspector", "read"),
+				inspectorCollectionHandler.ListCollections)
+			inspectorGroup.POST("/servers/:id/collections",
+				authMiddleware.RequireResourceAccess("inspector", "write"),
+				loggingMiddleware.AuditLogger("create", "inspector-collection"),
+				inspectorCollectionHandler.CreateCollection)
+			inspectorGroup.GET("/collections/:collection_id",
+				authMiddleware.RequireResourceAccess("inspector", "read"),
+				inspectorCollectionHandler.GetCollection)
+			inspectorGroup.PUT("/collections/:collection_id",
+				authMiddleware.RequireResourceAccess("inspector", "write"),
+				loggingMiddleware.AuditLogger("update", "inspector-collection"),
+				inspectorCollectionHandler.UpdateCollection)
+			inspectorGroup.DELETE("/collections/:collection_id",
+				authMiddleware.RequireResourceAccess("inspector", "delete"),
+				loggingMiddleware.AuditLogger("delete", "inspector-collection"),
+				inspectorCollectionHandler.DeleteCollection)
+			inspectorGroup.POST("/sessions/:id/collections/:collection_id/run",
+				authMiddleware.RequireResourceAccess("inspector", "execute"),
+				inspectorHandler.RunCollection)
 
 			// Event streaming
 			inspectorGroup.GET("/sessions/:id/events",
@@ -527,7 +1600,8 @@ func (s *Server) RegisterRoutes() http.Handler {
 		// A2A (Agent-to-Agent) management routes (protected)
 		a2aChain := middleware.AuthenticatedChain().
 			Use(authMiddleware.RequireAuth()).
-			Use(authMiddleware.RequireOrganizationAccess())
+			Use(authMiddleware.RequireOrganizationAccess()).
+			Use(middleware.RuleRateLimit(rateLimitChecker))
 		a2aGroup := api.Group("/a2a")
 		a2aChain.Apply(a2aGroup)
 		{
@@ -562,6 +1636,12 @@ func (s *Server) RegisterRoutes() http.Handler {
 				authMiddleware.RequireResourceAccess("a2a_agent", "read"),
 				a2aHandler.HealthCheckAgent)
 
+			// Agent card discovery
+			a2aGroup.POST("/:id/card/refresh",
+				authMiddleware.RequireResourceAccess("a2a_agent", "write"),
+				loggingMiddleware.AuditLogger("refresh-card", "a2a-agent"),
+				a2aHandler.RefreshAgentCard)
+
 			// Agent testing
 			a2aGroup.POST("/:id/test",
 				authMiddleware.RequireResourceAccess("a2a_agent", "execute"),
@@ -593,6 +1673,10 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 		// Endpoint management routes (protected)
 		endpointHandler := handlers.NewEndpointHandler(endpointService)
+		endpointHandler.SetNamespaceAccess(namespaceAccessService)
+		sandboxLimits := s.cfg.Gateway.Sandbox.Limits()
+		sandboxHandler := handlers.NewSandboxHandler(endpointService, namespaceService, sandboxLimits)
+		sandboxHandler.SetHistory(playgroundHistoryService)
 		endpoints := api.Group("/endpoints")
 		endpoints.Use(authMiddleware.RequireAuth()).
 			Use(authMiddleware.RequireOrganizationAccess())
@@ -606,19 +1690,68 @@ func (s *Server) RegisterRoutes() http.Handler {
 				endpointHandler.CreateEndpoint)
 			endpoints.GET("/:id",
 				authMiddleware.RequireResourceAccess("endpoint", "read"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessRead),
 				endpointHandler.GetEndpoint)
 			endpoints.PUT("/:id",
 				authMiddleware.RequireResourceAccess("endpoint", "write"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessWrite),
 				loggingMiddleware.AuditLogger("update", "endpoint"),
 				endpointHandler.UpdateEndpoint)
 			endpoints.DELETE("/:id",
 				authMiddleware.RequireResourceAccess("endpoint", "delete"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessAdmin),
 				loggingMiddleware.AuditLogger("delete", "endpoint"),
+				legalHoldGuard,
 				endpointHandler.DeleteEndpoint)
 			endpoints.POST("/:id/regenerate-keys",
 				authMiddleware.RequireResourceAccess("endpoint", "write"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessWrite),
 				loggingMiddleware.AuditLogger("regenerate-keys", "endpoint"),
 				endpointHandler.RegenerateEndpointKeys)
+
+			// Test console: non-billable tool calls under stricter limits
+			endpoints.GET("/:id/sandbox/tools",
+				authMiddleware.RequireResourceAccess("endpoint", "read"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessRead),
+				sandboxHandler.ListTools)
+			endpoints.POST("/:id/sandbox/tools/:tool_name",
+				authMiddleware.RequireResourceAccess("endpoint", "read"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessExecute),
+				authMiddleware.RequirePermission(types.PermissionToolExecute),
+				middleware.SandboxRateLimit(sandboxLimits.RequestsPerMinute),
+				sandboxHandler.ExecuteTool)
+			endpoints.POST("/:id/sandbox/history/:execution_id/rerun",
+				authMiddleware.RequireResourceAccess("endpoint", "read"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessExecute),
+				authMiddleware.RequirePermission(types.PermissionToolExecute),
+				middleware.SandboxRateLimit(sandboxLimits.RequestsPerMinute),
+				sandboxHandler.RerunExecution)
+
+			// Geo policies allow or deny calls by the client's country and
+			// autonomous system
+			endpointGeoPolicyHandler := handlers.NewEndpointGeoPolicyHandler(endpointGeoPolicyService)
+			endpoints.GET("/:id/geo-policy",
+				authMiddleware.RequireResourceAccess("endpoint", "read"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessRead),
+				endpointGeoPolicyHandler.GetPolicy)
+			endpoints.PUT("/:id/geo-policy",
+				authMiddleware.RequireResourceAccess("endpoint", "write"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessWrite),
+				loggingMiddleware.AuditLogger("set_geo_policy", "endpoint"),
+				endpointGeoPolicyHandler.SetPolicy)
+			endpoints.DELETE("/:id/geo-policy",
+				authMiddleware.RequireResourceAccess("endpoint", "write"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessWrite),
+				loggingMiddleware.AuditLogger("delete_geo_policy", "endpoint"),
+				endpointGeoPolicyHandler.DeletePolicy)
+			endpoints.POST("/:id/geo-policy/test",
+				authMiddleware.RequireResourceAccess("endpoint", "read"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessRead),
+				endpointGeoPolicyHandler.TestPolicy)
+			endpoints.GET("/:id/geo-decisions",
+				authMiddleware.RequireResourceAccess("endpoint", "read"),
+				authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessRead),
+				endpointGeoPolicyHandler.ListDecisions)
 		}
 
 		// Admin routes for virtual servers and system management (protected)
@@ -636,15 +1769,675 @@ func (s *Server) RegisterRoutes() http.Handler {
 			admin.GET("/audit",
 				authMiddleware.RequireAdmin(),
 				authMiddleware.RequirePermission(types.PermissionAuditRead),
-				adminHandler.GetAuditLogs)
+				auditHandler.ListAuditLogs)
+			admin.GET("/audit/verify",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionAuditRead),
+				auditHandler.VerifyChain)
+			admin.GET("/audit/anchors",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionAuditRead),
+				auditHandler.ListAnchors)
+			admin.POST("/audit/anchors",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				auditHandler.CreateAnchor)
+			admin.GET("/audit/sinks",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionAuditRead),
+				auditSinkHandler.ListSinks)
+			admin.POST("/audit/sinks",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				loggingMiddleware.AuditLogger("create", "audit-sink"),
+				auditSinkHandler.CreateSink)
+			admin.GET("/audit/sinks/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionAuditRead),
+				auditSinkHandler.GetSink)
+			admin.PUT("/audit/sinks/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				loggingMiddleware.AuditLogger("update", "audit-sink"),
+				auditSinkHandler.UpdateSink)
+			admin.DELETE("/audit/sinks/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				loggingMiddleware.AuditLogger("delete", "audit-sink"),
+				auditSinkHandler.DeleteSink)
+			admin.POST("/audit/sinks/:id/test",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				auditSinkHandler.TestSink)
+			admin.GET("/webhooks",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgRead),
+				webhookHandler.ListWebhooks)
+			admin.POST("/webhooks",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("create", "webhook"),
+				webhookHandler.CreateWebhook)
+			admin.GET("/webhooks/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgRead),
+				webhookHandler.GetWebhook)
+			admin.PUT("/webhooks/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("update", "webhook"),
+				webhookHandler.UpdateWebhook)
+			admin.DELETE("/webhooks/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("delete", "webhook"),
+				webhookHandler.DeleteWebhook)
+			admin.POST("/webhooks/:id/rotate-secret",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("rotate-secret", "webhook"),
+				webhookHandler.RotateSecret)
+			admin.POST("/webhooks/:id/test",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				webhookHandler.TestWebhook)
+			admin.GET("/webhooks/:id/deliveries",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgRead),
+				webhookHandler.ListDeliveries)
+			admin.POST("/webhooks/:id/deliveries/:delivery_id/redeliver",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				webhookHandler.Redeliver)
+			admin.GET("/log-views",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				logViewHandler.ListViews)
+			admin.POST("/log-views",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				loggingMiddleware.AuditLogger("create", "log-view"),
+				logViewHandler.CreateView)
+			admin.GET("/log-views/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				logViewHandler.GetView)
+			admin.PUT("/log-views/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				loggingMiddleware.AuditLogger("update", "log-view"),
+				logViewHandler.UpdateView)
+			admin.DELETE("/log-views/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				loggingMiddleware.AuditLogger("delete", "log-view"),
+				logViewHandler.DeleteView)
+			admin.GET("/log-views/:id/results",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				logViewHandler.RunView)
+			admin.GET("/log-views/:id/widgets",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionMetricsRead),
+				logViewHandler.GetWidgets)
+			admin.GET("/log-exports",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				logExportHandler.ListExports)
+			admin.POST("/log-exports",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				loggingMiddleware.AuditLogger("create", "log-export"),
+				logExportHandler.CreateExport)
+			admin.GET("/log-exports/stream",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				loggingMiddleware.AuditLogger("export", "log-export"),
+				logExportHandler.StreamExport)
+			admin.GET("/log-exports/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				logExportHandler.GetExport)
+			admin.GET("/archive-policy",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				archiveHandler.GetPolicy)
+			admin.PUT("/archive-policy",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("update", "archive-policy"),
+				archiveHandler.UpdatePolicy)
+			admin.GET("/archives",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				archiveHandler.ListArchives)
+			admin.GET("/archives/rehydrations",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				archiveHandler.ListRehydrations)
+			admin.POST("/archives/rehydrations",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				loggingMiddleware.AuditLogger("create", "archive-rehydration"),
+				archiveHandler.CreateRehydration)
+			admin.GET("/archives/rehydrations/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				archiveHandler.GetRehydration)
+			admin.DELETE("/archives/rehydrations/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				loggingMiddleware.AuditLogger("release", "archive-rehydration"),
+				archiveHandler.ReleaseRehydration)
+			admin.GET("/log-retention",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				logRetentionHandler.GetPolicy)
+			admin.PUT("/log-retention",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("update", "log-retention"),
+				logRetentionHandler.UpdatePolicy)
+			admin.GET("/log-retention/purge-windows",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				logRetentionHandler.ListPurgeWindows)
+			admin.GET("/log-retention/exports",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				logRetentionHandler.ListExports)
+			admin.GET("/log-field-policy",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				logFieldPolicyHandler.GetPolicy)
+			admin.PUT("/log-field-policy",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("update", "log-field-policy"),
+				logFieldPolicyHandler.SetPolicy)
+			admin.DELETE("/log-field-policy",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("reset", "log-field-policy"),
+				logFieldPolicyHandler.ResetPolicy)
+			admin.GET("/read-only",
+				authMiddleware.RequireAdmin(),
+				readOnlyHandler.GetStatus)
+			admin.PUT("/read-only",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				readOnlyHandler.UpdateStatus)
+			admin.GET("/config",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionRead),
+				featureFlagHandler.GetConfig)
+			admin.GET("/feature-flags",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionRead),
+				featureFlagHandler.ListFlags)
+			admin.PUT("/feature-flags/:key",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				featureFlagHandler.SetFlag)
+			admin.DELETE("/feature-flags/:key",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				featureFlagHandler.ClearFlag)
+			admin.GET("/license",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionRead),
+				licenseHandler.GetStatus)
+			admin.GET("/offline",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionRead),
+				offlineHandler.GetStatus)
+			admin.GET("/telemetry",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionRead),
+				telemetryHandler.GetStatus)
+			admin.GET("/regions",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePlatformAdmin(),
+				regionHandler.GetStatus)
+			admin.POST("/regions/sync",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("sync", "region"),
+				authMiddleware.RequirePlatformAdmin(),
+				regionHandler.Sync)
+			admin.GET("/regions/dns",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionEndpointRead),
+				regionHandler.GetDNSGuidance)
+			admin.GET("/budgets",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionRead),
+				usageBudgetHandler.ListBudgets)
+			admin.PUT("/budgets/:dimension",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				loggingMiddleware.AuditLogger("set_budget", "budget"),
+				usageBudgetHandler.SetBudget)
+			admin.DELETE("/budgets/:dimension",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				loggingMiddleware.AuditLogger("delete_budget", "budget"),
+				usageBudgetHandler.DeleteBudget)
+			admin.GET("/dlp/events",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionAuditRead),
+				dlpHandler.ListEvents)
+			admin.GET("/nodes",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePlatformAdmin(),
+				nodeHandler.ListNodes)
+			admin.POST("/nodes/:id/drain",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("drain", "node"),
+				authMiddleware.RequirePlatformAdmin(),
+				nodeHandler.DrainNode)
+			admin.DELETE("/nodes/:id/drain",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("undrain", "node"),
+				authMiddleware.RequirePlatformAdmin(),
+				nodeHandler.UndrainNode)
+			admin.GET("/failover",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePlatformAdmin(),
+				failoverHandler.GetStatus)
+			admin.POST("/failover",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePlatformAdmin(),
+				failoverHandler.Failover)
+			admin.GET("/legal-holds",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePlatformAdmin(),
+				legalHoldHandler.ListHolds)
+			admin.GET("/legal-holds/:org_id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePlatformAdmin(),
+				legalHoldHandler.GetHold)
+			admin.POST("/legal-holds/:org_id",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("place", "legal_hold"),
+				authMiddleware.RequirePlatformAdmin(),
+				legalHoldHandler.PlaceHold)
+			admin.POST("/legal-holds/:org_id/release",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("release", "legal_hold"),
+				authMiddleware.RequirePlatformAdmin(),
+				legalHoldHandler.ReleaseHold)
+			admin.GET("/incidents",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionMetricsRead),
+				incidentHandler.ListIncidents)
+			admin.GET("/incidents/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionMetricsRead),
+				incidentHandler.GetIncident)
+			admin.POST("/incidents",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionWrite),
+				loggingMiddleware.AuditLogger("create", "incident"),
+				incidentHandler.CreateIncident)
+			admin.PUT("/incidents/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionWrite),
+				loggingMiddleware.AuditLogger("update", "incident"),
+				incidentHandler.UpdateIncident)
+			admin.DELETE("/incidents/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionDelete),
+				loggingMiddleware.AuditLogger("delete", "incident"),
+				incidentHandler.DeleteIncident)
+			admin.GET("/teams",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserRead),
+				teamHandler.ListTeams)
+			admin.POST("/teams",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("create", "team"),
+				teamHandler.CreateTeam)
+			admin.POST("/teams/sync",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("sync", "team"),
+				teamHandler.SyncGroups)
+			admin.GET("/teams/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserRead),
+				teamHandler.GetTeam)
+			admin.PUT("/teams/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("update", "team"),
+				teamHandler.UpdateTeam)
+			admin.DELETE("/teams/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("delete", "team"),
+				teamHandler.DeleteTeam)
+			admin.GET("/teams/:id/members",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserRead),
+				teamHandler.ListMembers)
+			admin.POST("/teams/:id/members",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("add-member", "team"),
+				teamHandler.AddMember)
+			admin.DELETE("/teams/:id/members/:user_id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("remove-member", "team"),
+				teamHandler.RemoveMember)
+			admin.GET("/users",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserRead),
+				userHandler.List)
+			admin.POST("/users",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				userHandler.Create)
+			admin.POST("/users/invitations",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				userHandler.Invite)
+			admin.GET("/users/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserRead),
+				userHandler.Get)
+			admin.PUT("/users/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				userHandler.Update)
+			admin.POST("/users/:id/invitation",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				userHandler.ResendInvitation)
+			admin.POST("/users/:id/deactivate",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				userHandler.Deactivate)
+			admin.POST("/users/:id/reactivate",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				userHandler.Reactivate)
+			admin.POST("/users/:id/password-reset",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				userHandler.SendPasswordReset)
+			admin.GET("/service-accounts",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserRead),
+				serviceAccountHandler.List)
+			admin.POST("/service-accounts",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("create", "service_account"),
+				serviceAccountHandler.Create)
+			admin.GET("/service-accounts/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserRead),
+				serviceAccountHandler.Get)
+			admin.PUT("/service-accounts/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("update", "service_account"),
+				serviceAccountHandler.Update)
+			admin.DELETE("/service-accounts/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("delete", "service_account"),
+				serviceAccountHandler.Delete)
+			admin.GET("/service-accounts/:id/api-keys",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserRead),
+				serviceAccountHandler.ListAPIKeys)
+			admin.POST("/service-accounts/:id/api-keys",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("create-api-key", "service_account"),
+				serviceAccountHandler.CreateAPIKey)
+			admin.DELETE("/service-accounts/:id/api-keys/:key_id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("revoke-api-key", "service_account"),
+				serviceAccountHandler.RevokeAPIKey)
+			admin.GET("/service-accounts/:id/oauth-clients",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserRead),
+				serviceAccountHandler.ListClients)
+			admin.POST("/service-accounts/:id/oauth-clients",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("create-oauth-client", "service_account"),
+				serviceAccountHandler.CreateClient)
+			admin.DELETE("/service-accounts/:id/oauth-clients/:client_id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("delete-oauth-client", "service_account"),
+				serviceAccountHandler.DeleteClient)
+			admin.GET("/service-accounts/:id/delegation",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserRead),
+				delegationHandler.GetPolicy)
+			admin.PUT("/service-accounts/:id/delegation",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("set-delegation", "service_account"),
+				delegationHandler.SetPolicy)
+			admin.DELETE("/service-accounts/:id/delegation",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("delete-delegation", "service_account"),
+				delegationHandler.DeletePolicy)
+			admin.GET("/branding",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgRead),
+				brandingHandler.GetBranding)
+			admin.PUT("/branding",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("update", "branding"),
+				brandingHandler.SetBranding)
+			admin.DELETE("/branding",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("reset", "branding"),
+				brandingHandler.ResetBranding)
+			admin.GET("/playground-history/settings",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgRead),
+				playgroundHistoryHandler.GetSettings)
+			admin.PUT("/playground-history/settings",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("update", "playground-history"),
+				playgroundHistoryHandler.UpdateSettings)
+			admin.GET("/signing-keys",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				signingKeyHandler.ListKeys)
+			admin.POST("/signing-keys",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				loggingMiddleware.AuditLogger("create", "signing_key"),
+				signingKeyHandler.CreateKey)
+			admin.DELETE("/signing-keys/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				loggingMiddleware.AuditLogger("revoke", "signing_key"),
+				signingKeyHandler.RevokeKey)
+			admin.GET("/role-grants",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserRead),
+				roleGrantHandler.ListRoleGrants)
+			admin.POST("/role-grants",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("grant", "role"),
+				roleGrantHandler.CreateRoleGrant)
+			admin.DELETE("/role-grants/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionUserManage),
+				loggingMiddleware.AuditLogger("revoke", "role"),
+				roleGrantHandler.RevokeRoleGrant)
+			admin.GET("/break-glass/credentials",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePlatformAdmin(),
+				breakGlassHandler.ListCredentials)
+			admin.POST("/break-glass/credentials",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("create", "break_glass"),
+				authMiddleware.RequirePlatformAdmin(),
+				breakGlassHandler.CreateCredential)
+			admin.POST("/break-glass/credentials/:id/rotate",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("rotate", "break_glass"),
+				authMiddleware.RequirePlatformAdmin(),
+				breakGlassHandler.RotateCredential)
+			admin.DELETE("/break-glass/credentials/:id",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("delete", "break_glass"),
+				authMiddleware.RequirePlatformAdmin(),
+				breakGlassHandler.DeleteCredential)
+			admin.GET("/break-glass/sessions",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionAuditRead),
+				breakGlassHandler.ListSessions)
+			admin.DELETE("/break-glass/sessions/:id",
+				authMiddleware.RequireAdmin(),
+				loggingMiddleware.AuditLogger("end", "break_glass"),
+				breakGlassHandler.EndSession)
+			admin.GET("/sso/providers",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgRead),
+				ssoHandler.ListProviders)
+			admin.GET("/sso/providers/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgRead),
+				ssoHandler.GetProvider)
+			admin.POST("/sso/providers",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("create", "sso_provider"),
+				ssoHandler.CreateProvider)
+			admin.PUT("/sso/providers/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("update", "sso_provider"),
+				ssoHandler.UpdateProvider)
+			admin.DELETE("/sso/providers/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("delete", "sso_provider"),
+				ssoHandler.DeleteProvider)
+			admin.GET("/rate-limits",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgRead),
+				rateLimitRuleHandler.ListRules)
+			admin.GET("/rate-limits/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgRead),
+				rateLimitRuleHandler.GetRule)
+			admin.POST("/rate-limits",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("create", "rate_limit_rule"),
+				rateLimitRuleHandler.CreateRule)
+			admin.PUT("/rate-limits/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("update", "rate_limit_rule"),
+				rateLimitRuleHandler.UpdateRule)
+			admin.DELETE("/rate-limits/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("delete", "rate_limit_rule"),
+				rateLimitRuleHandler.DeleteRule)
+			admin.GET("/cache",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgRead),
+				cacheHandler.GetStats)
+			admin.DELETE("/cache",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionOrgManage),
+				loggingMiddleware.AuditLogger("flush", "list_cache"),
+				cacheHandler.Flush)
+			admin.GET("/compromised-credentials",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionAuditRead),
+				compromisedHandler.List)
+			admin.POST("/compromised-credentials",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionAPIKeyManage),
+				loggingMiddleware.AuditLogger("report", "compromised_credential"),
+				compromisedHandler.Report)
+			admin.GET("/oauth/registration-policy",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionRead),
+				oauthRegistrationHandler.GetPolicy)
+			admin.PUT("/oauth/registration-policy",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				oauthRegistrationHandler.UpdatePolicy)
+			admin.GET("/oauth/initial-access-tokens",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionRead),
+				oauthRegistrationHandler.ListInitialAccessTokens)
+			admin.POST("/oauth/initial-access-tokens",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				oauthRegistrationHandler.CreateInitialAccessToken)
+			admin.DELETE("/oauth/initial-access-tokens/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionSystemManage),
+				oauthRegistrationHandler.RevokeInitialAccessToken)
+			admin.GET("/security/posture",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionAuditRead),
+				securityPostureHandler.GetPostureReport)
 			admin.GET("/stats",
 				authMiddleware.RequireAdmin(),
 				authMiddleware.RequirePermission(types.PermissionMetricsRead),
 				adminHandler.GetStats)
+			admin.GET("/stats/principals",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionMetricsRead),
+				adminHandler.GetPrincipalUsage)
+			admin.GET("/stats/labels",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionMetricsRead),
+				adminHandler.GetLabelUsage)
+			admin.GET("/stats/clients",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionMetricsRead),
+				adminHandler.GetClientUsage)
+			admin.GET("/stats/servers",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionMetricsRead),
+				adminHandler.GetServerUsage)
+			admin.GET("/stats/tools",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionMetricsRead),
+				adminHandler.GetToolUsage)
+			admin.GET("/mcp-messages",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				mcpMessageLogHandler.ListMessages)
+			admin.GET("/mcp-messages/:id",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionLogsRead),
+				mcpMessageLogHandler.GetMessage)
 			admin.GET("/metrics",
 				authMiddleware.RequireAdmin(),
 				authMiddleware.RequirePermission(types.PermissionMetricsRead),
 				adminHandler.GetMetrics)
+			admin.GET("/scheduler",
+				authMiddleware.RequireAdmin(),
+				authMiddleware.RequirePermission(types.PermissionMetricsRead),
+				schedulerHandler.GetStats)
 
 			// Virtual server management - role-based access
 			virtual := admin.Group("/virtual-servers")
@@ -687,6 +2480,10 @@ func (s *Server) RegisterRoutes() http.Handler {
 					authMiddleware.RequirePermission(types.PermissionWrite),
 					loggingMiddleware.AuditLogger("create", "policy"),
 					policyHandler.CreatePolicy)
+				policies.POST("/test",
+					authMiddleware.RequireAdmin(),
+					authMiddleware.RequirePermission(types.PermissionRead),
+					policyHandler.TestPolicy)
 				policies.GET("/:id",
 					authMiddleware.RequireAdmin(),
 					authMiddleware.RequirePermission(types.PermissionRead),
@@ -703,6 +2500,29 @@ func (s *Server) RegisterRoutes() http.Handler {
 					policyHandler.DeletePolicy)
 			}
 
+			// Tool calls held for approval by tool policies
+			approvals := admin.Group("/approvals")
+			{
+				approvals.GET("",
+					authMiddleware.RequireAdmin(),
+					authMiddleware.RequirePermission(types.PermissionRead),
+					approvalHandler.ListApprovals)
+				approvals.GET("/:id",
+					authMiddleware.RequireAdmin(),
+					authMiddleware.RequirePermission(types.PermissionRead),
+					approvalHandler.GetApproval)
+				approvals.POST("/:id/approve",
+					authMiddleware.RequireAdmin(),
+					authMiddleware.RequirePermission(types.PermissionWrite),
+					loggingMiddleware.AuditLogger("approve", "tool_approval"),
+					approvalHandler.ApproveApproval)
+				approvals.POST("/:id/reject",
+					authMiddleware.RequireAdmin(),
+					authMiddleware.RequirePermission(types.PermissionWrite),
+					loggingMiddleware.AuditLogger("reject", "tool_approval"),
+					approvalHandler.RejectApproval)
+			}
+
 			// Content Filters management - requires admin access and filter permissions
 			filters := admin.Group("/filters")
 			{
@@ -795,8 +2615,12 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	// Transport endpoints (with middleware applied)
 	// JSON-RPC over HTTP
-	transportGroup.POST("/rpc", rpcHandler.HandleJSONRPC)
-	transportGroup.POST("/rpc/batch", rpcHandler.HandleBatchRPC)
+	transportGroup.POST("/rpc",
+		middleware.MCPMessageLogger(mcpMessageLogService, types.TransportTypeHTTP),
+		rpcHandler.HandleJSONRPC)
+	transportGroup.POST("/rpc/batch",
+		middleware.MCPMessageLogger(mcpMessageLogService, types.TransportTypeHTTP),
+		rpcHandler.HandleBatchRPC)
 	transportGroup.GET("/rpc/introspection", rpcHandler.HandleRPCIntrospection)
 	transportGroup.GET("/rpc/health", rpcHandler.HandleRPCHealth)
 
@@ -819,7 +2643,9 @@ func (s *Server) RegisterRoutes() http.Handler {
 	transportGroup.GET("/ws/metrics", wsHandler.HandleWebSocketMetrics)
 
 	// Streamable HTTP (MCP Protocol)
-	transportGroup.Any("/mcp", mcpHandler.HandleStreamableHTTP)
+	transportGroup.Any("/mcp",
+		middleware.MCPMessageLogger(mcpMessageLogService, types.TransportTypeStreamable),
+		mcpHandler.HandleStreamableHTTP)
 	transportGroup.GET("/mcp/capabilities", mcpHandler.HandleMCPCapabilities)
 	transportGroup.GET("/mcp/status", mcpHandler.HandleMCPStatus)
 	transportGroup.GET("/mcp/health", mcpHandler.HandleMCPHealth)
@@ -844,33 +2670,66 @@ func (s *Server) RegisterRoutes() http.Handler {
 	{
 		// List all available endpoints (requires JWT auth for discovery)
 		endpointHandlerForPublic := handlers.NewEndpointHandler(endpointService)
+		endpointHandlerForPublic.SetNamespaceAccess(namespaceAccessService)
 		publicEndpoints.GET("/endpoints",
 			authMiddleware.RequireAuth(),
 			endpointHandlerForPublic.ListEndpoints)
 
+		// Embeddable status pages for endpoints that opted in
+		statusLimits := s.cfg.Gateway.StatusPage.Limits()
+		statusService := services.NewStatusService(s.db.GetDB(), endpointService,
+			time.Duration(statusLimits.CacheTTLSeconds)*time.Second)
+		statusService.SetIncidents(incidentService)
+		statusPageHandler := handlers.NewStatusPageHandler(statusService, statusLimits)
+		publicEndpoints.GET("/status/:endpoint_name",
+			middleware.StatusPageRateLimit(statusLimits.RequestsPerMinute),
+			statusPageHandler.GetStatus)
+
+		// Signed download links of completed log exports
+		publicEndpoints.GET("/log-exports/:id/download", logExportHandler.Download)
+
 		// Endpoint-specific routes with custom URL paths
 		endpoint := publicEndpoints.Group("/endpoints/:endpoint_name")
+		// Each stage gets its own span, so traces show how long a call spent
+		// in each before reaching the namespace
 		endpoint.Use(
-			middleware.EndpointLookupMiddleware(endpointService),
-			middleware.EndpointAuthMiddleware(endpointService, authService, oauthService),
-			middleware.EndpointRateLimitMiddleware(),
-			middleware.EndpointCORSMiddleware(),
+			middleware.TraceStage("endpoint.lookup", middleware.EndpointLookupMiddleware(endpointService)),
+			middleware.TraceStage("endpoint.residency", middleware.EndpointResidencyMiddleware(residencyPolicy)),
+			middleware.TraceStage("endpoint.geo", middleware.EndpointGeoMiddleware(endpointGeoPolicyService)),
+			middleware.TraceStage("endpoint.auth", middleware.EndpointAuthMiddleware(endpointService, authService, oauthService, baseURL)),
+			middleware.TraceStage("endpoint.on_behalf_of", middleware.OnBehalfOfMiddleware(delegationService)),
+			middleware.TraceStage("endpoint.namespace_access", middleware.EndpointNamespaceAccessMiddleware(namespaceAccessService)),
+			middleware.TraceStage("endpoint.api_key_scope", middleware.EndpointAPIKeyScopeMiddleware(namespaceService)),
+			middleware.TraceStage("endpoint.rate_limit", middleware.EndpointRateLimitMiddleware(requestLimiter)),
+			middleware.TraceStage("endpoint.rate_limit_rules", middleware.RuleRateLimit(rateLimitChecker)),
+			middleware.TraceStage("endpoint.cors", middleware.EndpointCORSMiddleware()),
+			middleware.EndTraceStages(),
 		)
 		{
 			// SSE transport
 			endpoint.GET("/sse", handlers.HandleEndpointSSE(namespaceService))
-			endpoint.POST("/message", handlers.HandleEndpointSSEMessage(namespaceService))
+			endpoint.POST("/message",
+				middleware.MCPMessageLogger(mcpMessageLogService, types.TransportTypeSSE),
+				handlers.HandleEndpointSSEMessage(namespaceService))
 
 			// HTTP transport (MCP protocol)
-			endpoint.Any("/mcp", handlers.HandleEndpointHTTP(namespaceService))
+			endpoint.Any("/mcp",
+				middleware.MCPMessageLogger(mcpMessageLogService, types.TransportTypeStreamable),
+				handlers.HandleEndpointHTTP(namespaceService))
 
 			// WebSocket transport
-			endpoint.GET("/ws", handlers.HandleEndpointWebSocket(namespaceService))
+			endpoint.GET("/ws", handlers.HandleEndpointWebSocket(namespaceService, s.cfg.Transport.WebSocketRequireSubprotocol))
 
 			// OpenAPI/REST interface
-			endpoint.GET("/api/openapi.json", handlers.HandleEndpointOpenAPI(endpointService, namespaceService, baseURL))
-			endpoint.GET("/api/docs", handlers.HandleEndpointOpenAPI(endpointService, namespaceService, baseURL))
-			endpoint.GET("/api/tools", handlers.HandleEndpointToolsList(namespaceService))
+			var toolExamples handlers.ToolExampleSource
+			if s.cfg.Gateway.OpenAPI.RecordedExamples {
+				toolExamples = mcpMessageLogService
+			}
+			endpoint.GET("/api/openapi.json", handlers.HandleEndpointOpenAPI(endpointService, namespaceService, brandingService, toolExamples, baseURL))
+			endpoint.GET("/api/docs", handlers.HandleEndpointOpenAPI(endpointService, namespaceService, brandingService, toolExamples, baseURL))
+			endpoint.GET("/sdk", handlers.HandleEndpointSDK(endpointService, namespaceService, brandingService, toolExamples, baseURL))
+			endpoint.GET("/collection", handlers.HandleEndpointCollection(endpointService, namespaceService, brandingService, toolExamples, baseURL))
+			endpoint.GET("/api/tools", middleware.CacheListings(listCache, cache.KindTools), handlers.HandleEndpointToolsList(namespaceService))
 			endpoint.POST("/api/tools/:tool_name", handlers.HandleEndpointToolExecution(namespaceService))
 
 			// Health check
@@ -881,6 +2740,67 @@ func (s *Server) RegisterRoutes() http.Handler {
 	return r
 }
 
+// readOnlyExemptRoutes are mutating /api routes that carry MCP or agent
+// traffic, sessions or audit anchoring rather than configuration changes,
+// and the failover routes, which must work in a fenced region
+var readOnlyExemptRoutes = []string{
+	"/api/auth/login",
+	"/api/auth/refresh",
+	"/api/auth/break-glass",
+	"/api/auth/threat-feed",
+	"/api/auth/logout",
+	"/api/notifications/read-all",
+	"/api/notifications/:id/read",
+	"/api/notifications/preferences",
+	"/api/playground/history",
+	"/api/playground/history/:id",
+	"/api/gateway/servers/:id/owner/alerts/:alert_id/acknowledge",
+	"/api/gateway/sessions",
+	"/api/gateway/sessions/:session_id",
+	"/api/gateway/prompts/:id/use",
+	"/api/gateway/tools/:id/execute",
+	"/api/namespaces/:id/execute",
+	"/api/namespaces/:id/jobs",
+	"/api/jobs/:id",
+	"/api/inspector/sessions",
+	"/api/inspector/sessions/:id",
+	"/api/inspector/sessions/:id/request",
+	"/api/inspector/sessions/:id/history/:execution_id/rerun",
+	"/api/inspector/sessions/:id/servers",
+	"/api/inspector/sessions/:id/servers/:server_id",
+	"/api/inspector/sessions/:id/broadcast",
+	"/api/inspector/sessions/:id/raw",
+	"/api/inspector/sessions/:id/frames/:frame_id/resend",
+	"/api/inspector/sessions/:id/collections/:collection_id/run",
+	"/api/a2a/:id/test",
+	"/api/a2a/:id/invoke",
+	"/api/a2a/:id/chat",
+	"/api/admin/audit/anchors",
+	"/api/admin/audit/sinks/:id/test",
+	"/api/admin/webhooks/:id/test",
+	"/api/admin/webhooks/:id/deliveries/:delivery_id/redeliver",
+	"/api/admin/read-only",
+	"/api/admin/log-exports",
+	"/api/admin/cache",
+	"/api/admin/archives/rehydrations",
+	"/api/admin/archives/rehydrations/:id",
+	"/api/admin/compromised-credentials",
+	"/api/admin/virtual-servers/:id/tools/:tool/test",
+	"/api/admin/config/validate-import",
+	"/api/admin/policies/test",
+	"/api/admin/approvals/:id/approve",
+	"/api/admin/approvals/:id/reject",
+	"/api/endpoints/:id/sandbox/tools/:tool_name",
+	"/api/endpoints/:id/sandbox/history/:execution_id/rerun",
+	"/api/endpoints/:id/geo-policy/test",
+	"/api/public/endpoints/:endpoint_name/message",
+	"/api/public/endpoints/:endpoint_name/mcp",
+	"/api/public/endpoints/:endpoint_name/api/tools/:tool_name",
+	"/api/replication/fence",
+	"/api/admin/failover",
+	"/api/admin/nodes/:id/drain",
+}
+
 func (s *Server) HelloWorldHandler(c *gin.Context) {
 	resp := make(map[string]string)
 	resp["message"] = "all quiet on the western front"
@@ -891,3 +2811,82 @@ func (s *Server) HelloWorldHandler(c *gin.Context) {
 func (s *Server) healthHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, s.db.Health())
 }
+
+// readinessHandler reports the service health from the last dependency
+// probes. It fails with a 503 while a critical dependency is down or the
+// node is draining so load balancers take the instance out of rotation; the
+// X-Health-Score header lets balancers that support it weight healthy
+// instances.
+func (s *Server) readinessHandler(c *gin.Context) {
+	health := s.health.Health()
+	health.Draining = s.nodes != nil && s.nodes.Draining()
+	c.Header("X-Health-Score", strconv.FormatFloat(health.Score, 'f', 2, 64))
+	if health.Status == types.ServiceHealthUnavailable || health.Draining {
+		c.JSON(http.StatusServiceUnavailable, health)
+		return
+	}
+	c.JSON(http.StatusOK, health)
+}
+
+// newHealthMonitor starts probing the database and, when a feature shares
+// state through it, Redis. Redis is optional since every such feature falls
+// back to per-instance state.
+func (s *Server) newHealthMonitor() *health.Monitor {
+	cfg := s.cfg.Gateway.ServiceHealth
+	monitor := health.NewMonitor(health.Config{
+		Interval:       cfg.Interval,
+		Timeout:        cfg.Timeout,
+		ImpactHalfLife: cfg.ImpactHalfLife,
+	})
+	monitor.SetMetricEmitter(s.logging.(*logging.Service).EmitMetric)
+	monitor.AddDependency(health.Dependency{
+		Name:      "database",
+		Check:     health.DatabaseCheck(s.db.GetDB()),
+		SlowAfter: cfg.DatabaseSlowAfter,
+		Critical:  true,
+	})
+	if s.cfg.RateLimit.Storage == "redis" || s.cfg.Transport.EventBus.Backend == "redis" ||
+		s.cfg.Gateway.ListCache.Backend == "redis" || s.cfg.Auth.ReplayProtection.Store == "redis" {
+		monitor.AddDependency(health.Dependency{
+			Name: "redis",
+			Check: health.RedisCheck(fmt.Sprintf("%s:%d", s.cfg.Redis.Host, s.cfg.Redis.Port),
+				s.cfg.Redis.Password, s.cfg.Redis.Database),
+			SlowAfter: cfg.RedisSlowAfter,
+		})
+	}
+	monitor.Start()
+	return monitor
+}
+
+// loadTokenSigningKeys reads the RS256 or ES256 keys OAuth tokens are
+// signed with. It returns nil for HS256. Without configured keys a key is
+// generated for this process, so tokens stop verifying after a restart and
+// other instances cannot verify them.
+func loadTokenSigningKeys(cfg *config.TokenSigningConfig) (*auth.KeySet, error) {
+	if cfg.Algorithm == "" || cfg.Algorithm == auth.SigningAlgorithmHS256 {
+		return nil, nil
+	}
+
+	if len(cfg.Keys) == 0 {
+		key, err := auth.GenerateSigningKey(cfg.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Warning: no %s token signing keys configured, signing with generated key %s until restart", cfg.Algorithm, key.ID)
+		return auth.NewKeySet("", []*auth.SigningKey{key})
+	}
+
+	keys := make([]*auth.SigningKey, 0, len(cfg.Keys))
+	for i := range cfg.Keys {
+		pemData, err := cfg.Keys[i].LoadPrivateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key %q: %w", cfg.Keys[i].ID, err)
+		}
+		key, err := auth.ParseSigningKey(cfg.Keys[i].ID, cfg.Algorithm, []byte(pemData))
+		if err != nil {
+			return nil, fmt.Errorf("signing key %q: %w", cfg.Keys[i].ID, err)
+		}
+		keys = append(keys, key)
+	}
+	return auth.NewKeySet(cfg.ActiveKeyID, keys)
+}
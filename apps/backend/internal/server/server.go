@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,17 +10,37 @@ import (
 
 	_ "github.com/joho/godotenv/autoload"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/buildinfo"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/health"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging/plugins/file"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
 )
 
 type Server struct {
-	db      database.Service
-	logging logging.LogService
-	cfg     *config.Config
-	port    int
+	db         database.Service
+	health     *health.Monitor
+	nodes      *services.NodeRegistry
+	metering   *services.MeteringService
+	logging    logging.LogService
+	prometheus *observability.PrometheusExporter
+	cfg        *config.Config
+	port       int
+}
+
+// New creates a server on db logging to loggingService, without the
+// metric exporters and tracing NewServer sets up. RegisterRoutes needs no
+// database connection, so tests can list its routes.
+func New(cfg *config.Config, db database.Service, loggingService logging.LogService) *Server {
+	return &Server{
+		port:    cfg.Server.Port,
+		cfg:     cfg,
+		db:      db,
+		logging: loggingService,
+	}
 }
 
 func NewServer(cfg *config.Config) *http.Server {
@@ -37,14 +58,16 @@ func NewServer(cfg *config.Config) *http.Server {
 
 	// Initialize logging service
 	loggingConfig := &logging.LoggingConfig{
-		Backend:       cfg.Logging.Backend,
-		Level:         logging.LogLevel(cfg.Logging.Level),
-		Environment:   cfg.Logging.Environment,
-		BufferSize:    cfg.Logging.BufferSize,
-		BatchSize:     cfg.Logging.BatchSize,
-		FlushInterval: cfg.Logging.FlushInterval,
-		Async:         cfg.Logging.Async,
-		Config:        cfg.Logging.Config,
+		Backend:         cfg.Logging.Backend,
+		Level:           logging.LogLevel(cfg.Logging.Level),
+		Environment:     cfg.Logging.Environment,
+		BufferSize:      cfg.Logging.BufferSize,
+		BatchSize:       cfg.Logging.BatchSize,
+		FlushInterval:   cfg.Logging.FlushInterval,
+		OverflowPolicy:  logging.OverflowPolicy(cfg.Logging.OverflowPolicy),
+		OverflowTimeout: cfg.Logging.OverflowTimeout,
+		Async:           cfg.Logging.Async,
+		Config:          cfg.Logging.Config,
 	}
 
 	loggingService, err := logging.NewService(loggingConfig)
@@ -52,13 +75,53 @@ func NewServer(cfg *config.Config) *http.Server {
 		panic(fmt.Sprintf("failed to initialize logging service: %v", err))
 	}
 
-	NewServer := &Server{
-		port:    port,
-		cfg:     cfg,
-		db:      database.New(),
-		logging: loggingService,
+	if cfg.Observability.StatsD.Enabled {
+		statsd := cfg.Observability.StatsD
+		emitter, err := observability.NewStatsDEmitter(&observability.StatsDConfig{
+			GlobalTags:  statsd.GlobalTags,
+			SampleRates: statsd.SampleRates,
+			MetricNames: statsd.MetricNames,
+			Address:     statsd.Address,
+			Prefix:      statsd.Prefix,
+			Flavor:      statsd.Flavor,
+			SampleRate:  statsd.SampleRate,
+		})
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize statsd emitter: %v", err))
+		}
+		loggingService.(*logging.Service).AddMetricEmitter(emitter)
+	}
+
+	var prometheus *observability.PrometheusExporter
+	if promCfg := cfg.Observability.Prometheus; promCfg.Enabled {
+		prometheus = observability.NewPrometheusExporter(&observability.PrometheusConfig{
+			Namespace: promCfg.Namespace,
+			Buckets:   promCfg.Buckets,
+			MaxSeries: promCfg.MaxSeries,
+		})
+		loggingService.(*logging.Service).AddMetricEmitter(prometheus)
 	}
 
+	var shutdownTracing func(context.Context) error
+	if tracing := cfg.Observability.Tracing; tracing.Enabled {
+		shutdownTracing, err = observability.InstallTracing(context.Background(), &observability.TracingConfig{
+			Headers:        tracing.Headers,
+			ServiceName:    tracing.ServiceName,
+			ServiceVersion: buildinfo.Get().Version,
+			Endpoint:       tracing.Endpoint,
+			Protocol:       tracing.Protocol,
+			SampleRatio:    tracing.GetSampleRatio(),
+			Insecure:       tracing.Insecure,
+		})
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize tracing: %v", err))
+		}
+	}
+
+	NewServer := New(cfg, database.New(), loggingService)
+	NewServer.port = port
+	NewServer.prometheus = prometheus
+
 	// Declare Server config
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", NewServer.port),
@@ -68,5 +131,20 @@ func NewServer(cfg *config.Config) *http.Server {
 		WriteTimeout: 30 * time.Second,
 	}
 
+	// Leave the node registry when the server shuts down
+	server.RegisterOnShutdown(NewServer.nodes.Stop)
+
+	// Write the usage metered since the last flush
+	server.RegisterOnShutdown(NewServer.metering.Stop)
+
+	// Flush the spans of the last requests when the server shuts down
+	if shutdownTracing != nil {
+		server.RegisterOnShutdown(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = shutdownTracing(ctx)
+		})
+	}
+
 	return server
 }
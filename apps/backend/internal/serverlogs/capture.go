@@ -0,0 +1,251 @@
+// Package serverlogs captures the stdout and stderr of upstream servers the
+// gateway launches, keeping recent lines in memory for tailing and following
+// and optionally persisting them.
+package serverlogs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxLineBytes truncates runaway lines such as minified dumps
+	maxLineBytes = 8 * 1024
+	// flushBatch and flushInterval bound how long lines wait to be persisted
+	flushBatch    = 200
+	flushInterval = time.Second
+	// pendingLines bounds the lines waiting to be persisted; more are dropped
+	// rather than slowing down the server's output
+	pendingLines = 10000
+	// followerBuffer is how far a follower may fall behind before lines are
+	// dropped for it
+	followerBuffer = 256
+)
+
+// Store persists captured lines
+type Store interface {
+	Append(ctx context.Context, lines []types.ServerLogLine) error
+	Recent(ctx context.Context, serverID, stream string, limit int) ([]types.ServerLogLine, error)
+}
+
+// Capture keeps a ring buffer of recent output per server. A nil Capture
+// discards everything.
+type Capture struct {
+	store     Store
+	buffers   map[string]*ring
+	followers map[string]map[chan types.ServerLogLine]struct{}
+	pending   chan types.ServerLogLine
+	size      int
+	mu        sync.Mutex
+	flushOnce sync.Once
+}
+
+// New creates a capture keeping bufferLines lines per server. Lines are also
+// written to store when it is not nil.
+func New(bufferLines int, store Store) *Capture {
+	if bufferLines <= 0 {
+		bufferLines = 1000
+	}
+	c := &Capture{
+		store:     store,
+		buffers:   make(map[string]*ring),
+		followers: make(map[string]map[chan types.ServerLogLine]struct{}),
+		size:      bufferLines,
+	}
+	if store != nil {
+		c.pending = make(chan types.ServerLogLine, pendingLines)
+	}
+	return c
+}
+
+// Sink returns a function recording output lines of the server, or nil when
+// capture is off. Servers without a UUID are not captured.
+func (c *Capture) Sink(serverID string) func(stream, line string) {
+	if c == nil {
+		return nil
+	}
+	if _, err := uuid.Parse(serverID); err != nil {
+		return nil
+	}
+	return func(stream, line string) {
+		c.Record(serverID, stream, line)
+	}
+}
+
+// Record captures one line of a server's output
+func (c *Capture) Record(serverID, stream, line string) {
+	if c == nil {
+		return
+	}
+
+	entry := types.ServerLogLine{
+		Time:     time.Now().UTC(),
+		ServerID: serverID,
+		Stream:   stream,
+		Line:     truncate(line),
+	}
+
+	c.mu.Lock()
+	buffer, ok := c.buffers[serverID]
+	if !ok {
+		buffer = newRing(c.size)
+		c.buffers[serverID] = buffer
+	}
+	buffer.add(entry)
+	for follower := range c.followers[serverID] {
+		select {
+		case follower <- entry:
+		default:
+		}
+	}
+	c.mu.Unlock()
+
+	if c.pending != nil {
+		c.flushOnce.Do(func() { go c.flushLoop() })
+		select {
+		case c.pending <- entry:
+		default:
+		}
+	}
+}
+
+// Tail returns up to n of the server's most recent lines, oldest first,
+// optionally of one stream. Lines are read from the persistent store when
+// none are in memory, e.g. after a restart.
+func (c *Capture) Tail(ctx context.Context, serverID, stream string, n int) ([]types.ServerLogLine, error) {
+	if c == nil {
+		return []types.ServerLogLine{}, nil
+	}
+
+	c.mu.Lock()
+	var lines []types.ServerLogLine
+	if buffer, ok := c.buffers[serverID]; ok {
+		lines = buffer.last(stream, n)
+	}
+	c.mu.Unlock()
+
+	if len(lines) == 0 && c.store != nil {
+		return c.store.Recent(ctx, serverID, stream, n)
+	}
+	if lines == nil {
+		lines = []types.ServerLogLine{}
+	}
+	return lines, nil
+}
+
+// Follow returns a channel receiving the server's new lines until stop is
+// called. Lines are dropped for followers that fall behind.
+func (c *Capture) Follow(serverID string) (lines <-chan types.ServerLogLine, stop func()) {
+	follower := make(chan types.ServerLogLine, followerBuffer)
+	if c == nil {
+		return follower, func() {}
+	}
+
+	c.mu.Lock()
+	if c.followers[serverID] == nil {
+		c.followers[serverID] = make(map[chan types.ServerLogLine]struct{})
+	}
+	c.followers[serverID][follower] = struct{}{}
+	c.mu.Unlock()
+
+	var once sync.Once
+	return follower, func() {
+		once.Do(func() {
+			c.mu.Lock()
+			delete(c.followers[serverID], follower)
+			if len(c.followers[serverID]) == 0 {
+				delete(c.followers, serverID)
+			}
+			c.mu.Unlock()
+		})
+	}
+}
+
+// flushLoop writes pending lines to the store in batches
+func (c *Capture) flushLoop() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]types.ServerLogLine, 0, flushBatch)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := c.store.Append(ctx, batch); err != nil {
+			log.Printf("Failed to persist %d server log lines: %v", len(batch), err)
+		}
+		cancel()
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case line := <-c.pending:
+			batch = append(batch, line)
+			if len(batch) >= flushBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func truncate(line string) string {
+	if len(line) <= maxLineBytes {
+		return line
+	}
+	cut := maxLineBytes
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "…"
+}
+
+// ring is a fixed-size buffer of a server's most recent lines
+type ring struct {
+	lines []types.ServerLogLine
+	next  int
+	full  bool
+}
+
+func newRing(size int) *ring {
+	return &ring{lines: make([]types.ServerLogLine, size)}
+}
+
+func (r *ring) add(line types.ServerLogLine) {
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % len(r.lines)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// last returns up to n of the most recent lines of stream ("" for all),
+// oldest first
+func (r *ring) last(stream string, n int) []types.ServerLogLine {
+	count := r.next
+	if r.full {
+		count = len(r.lines)
+	}
+
+	var lines []types.ServerLogLine
+	for i := 1; i <= count && len(lines) < n; i++ {
+		line := r.lines[(r.next-i+len(r.lines))%len(r.lines)]
+		if stream == "" || line.Stream == stream {
+			lines = append(lines, line)
+		}
+	}
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
@@ -0,0 +1,78 @@
+package serverlogs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// DBStore persists captured lines in the server_logs table
+type DBStore struct {
+	db *sql.DB
+}
+
+// NewDBStore creates a database-backed store
+func NewDBStore(db *sql.DB) *DBStore {
+	return &DBStore{db: db}
+}
+
+// Append inserts a batch of lines
+func (s *DBStore) Append(ctx context.Context, lines []types.ServerLogLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(lines))
+	args := make([]interface{}, 0, len(lines)*4)
+	for i, line := range lines {
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d)", i*4+1, i*4+2, i*4+3, i*4+4))
+		args = append(args, line.ServerID, line.Stream, line.Line, line.Time)
+	}
+
+	_, err := s.db.ExecContext(ctx, `INSERT INTO server_logs (server_id, stream, line, logged_at) VALUES `+
+		strings.Join(placeholders, ", "), args...)
+	if err != nil {
+		return fmt.Errorf("failed to insert server logs: %w", err)
+	}
+	return nil
+}
+
+// Recent returns up to limit of the server's most recent lines, oldest first
+func (s *DBStore) Recent(ctx context.Context, serverID, stream string, limit int) ([]types.ServerLogLine, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT server_id, stream, line, logged_at FROM (
+			SELECT id, server_id, stream, line, logged_at
+			FROM server_logs
+			WHERE server_id = $1 AND ($2 = '' OR stream = $2)
+			ORDER BY logged_at DESC, id DESC
+			LIMIT $3
+		) recent
+		ORDER BY logged_at, id`, serverID, stream, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query server logs: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []types.ServerLogLine{}
+	for rows.Next() {
+		var line types.ServerLogLine
+		if err := rows.Scan(&line.ServerID, &line.Stream, &line.Line, &line.Time); err != nil {
+			return nil, fmt.Errorf("failed to scan server log: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// Prune deletes lines logged before the cutoff
+func (s *DBStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM server_logs WHERE logged_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune server logs: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const defaultGrantReminderBefore = 72 * time.Hour
+
+// AccessGrantStore persists temporary role grants and tracks the expiry of
+// role and namespace grants
+type AccessGrantStore interface {
+	ListRoleGrants(orgID string, filter *types.RoleGrantFilter, now time.Time) ([]*types.RoleGrant, error)
+	CreateRoleGrant(grant *types.RoleGrant) error
+	RevokeRoleGrant(orgID, id, revokedBy string) (bool, error)
+	ActiveRoles(userID string, now time.Time) ([]string, error)
+	UserOrganization(userID string) (string, error)
+	ListDueReminders(before time.Time) ([]*types.ExpiringGrant, error)
+	ListDueExpiry(now time.Time) ([]*types.ExpiringGrant, error)
+	MarkReminded(kind, id string, at time.Time) error
+	MarkExpired(kind, id string, at time.Time) error
+}
+
+// GrantNotifier delivers expiry reminders to admins and grantees
+type GrantNotifier interface {
+	Notify(ctx context.Context, event *types.NotificationEvent) (int, error)
+	NotifyUser(ctx context.Context, userID string, event *types.NotificationEvent) (bool, error)
+}
+
+// GrantAuditor records grant expiry in the audit log
+type GrantAuditor interface {
+	LogAudit(audit *types.AuditLog) error
+}
+
+// AccessGrantService manages time-boxed access: role grants that raise a
+// user's role until they expire, and the expiry of both role grants and
+// namespace grants. Grantees and admins are reminded before a grant ends
+// and every expiry is audited.
+type AccessGrantService struct {
+	store        AccessGrantStore
+	notifier     GrantNotifier
+	auditor      GrantAuditor
+	now          func() time.Time
+	remindBefore time.Duration
+}
+
+// NewAccessGrantService creates a database-backed access grant service that
+// sends reminders remindBefore a grant expires
+func NewAccessGrantService(db *sql.DB, remindBefore time.Duration) *AccessGrantService {
+	return NewAccessGrantServiceWithStore(models.NewAccessGrantModel(db), remindBefore)
+}
+
+// NewAccessGrantServiceWithStore creates an access grant service over store
+func NewAccessGrantServiceWithStore(store AccessGrantStore, remindBefore time.Duration) *AccessGrantService {
+	if remindBefore <= 0 {
+		remindBefore = defaultGrantReminderBefore
+	}
+	return &AccessGrantService{
+		store:        store,
+		now:          time.Now,
+		remindBefore: remindBefore,
+	}
+}
+
+// SetNotifier configures where expiry reminders are delivered
+func (s *AccessGrantService) SetNotifier(notifier GrantNotifier) {
+	s.notifier = notifier
+}
+
+// SetAuditor configures where grant expiry is audited
+func (s *AccessGrantService) SetAuditor(auditor GrantAuditor) {
+	s.auditor = auditor
+}
+
+// ListRoleGrants returns the organization's role grants
+func (s *AccessGrantService) ListRoleGrants(ctx context.Context, orgID string, filter *types.RoleGrantFilter) ([]*types.RoleGrant, error) {
+	grants, err := s.store.ListRoleGrants(orgID, filter, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role grants: %w", err)
+	}
+	return grants, nil
+}
+
+// GrantRole gives a user of the organization a role until the requested
+// expiry
+func (s *AccessGrantService) GrantRole(ctx context.Context, orgID, grantedBy string, req *types.CreateRoleGrantRequest) (*types.RoleGrant, error) {
+	expiresAt, err := req.Expiry(s.now())
+	if err != nil {
+		return nil, err
+	}
+	userOrg, err := s.store.UserOrganization(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if userOrg != orgID {
+		return nil, types.NewValidationError("user is not a member of the organization")
+	}
+
+	grant := &types.RoleGrant{
+		OrganizationID: orgID,
+		UserID:         req.UserID,
+		Role:           req.Role,
+		Reason:         req.Reason,
+		GrantedBy:      grantedBy,
+		ExpiresAt:      expiresAt,
+	}
+	if err := s.store.CreateRoleGrant(grant); err != nil {
+		return nil, fmt.Errorf("failed to create role grant: %w", err)
+	}
+	return grant, nil
+}
+
+// RevokeRole ends an active role grant of the organization early
+func (s *AccessGrantService) RevokeRole(ctx context.Context, orgID, grantID, revokedBy string) error {
+	if _, err := uuid.Parse(grantID); err != nil {
+		return types.NewNotFoundError("Role grant not found")
+	}
+	revoked, err := s.store.RevokeRoleGrant(orgID, grantID, revokedBy)
+	if err != nil {
+		return fmt.Errorf("failed to revoke role grant: %w", err)
+	}
+	if !revoked {
+		return types.NewNotFoundError("Role grant not found")
+	}
+	return nil
+}
+
+// ActiveRoles returns the roles a user's unexpired role grants give
+func (s *AccessGrantService) ActiveRoles(ctx context.Context, userID string) ([]string, error) {
+	roles, err := s.store.ActiveRoles(userID, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role grants: %w", err)
+	}
+	return roles, nil
+}
+
+// ProcessExpiry marks grants past their expiry as expired, auditing each,
+// then reminds admins and grantees of grants ending within the reminder
+// period. It returns how many grants expired and how many were reminded.
+func (s *AccessGrantService) ProcessExpiry(ctx context.Context) (int, int, error) {
+	now := s.now()
+
+	due, err := s.store.ListDueExpiry(now)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list expired grants: %w", err)
+	}
+	expired := 0
+	for _, grant := range due {
+		if err := s.store.MarkExpired(grant.Kind, grant.ID, now); err != nil {
+			return expired, 0, fmt.Errorf("failed to mark grant expired: %w", err)
+		}
+		expired++
+		s.auditExpiry(grant)
+	}
+
+	if s.notifier == nil {
+		return expired, 0, nil
+	}
+	upcoming, err := s.store.ListDueReminders(now.Add(s.remindBefore))
+	if err != nil {
+		return expired, 0, fmt.Errorf("failed to list expiring grants: %w", err)
+	}
+	reminded := 0
+	for _, grant := range upcoming {
+		if err := s.remind(ctx, grant); err != nil {
+			log.Printf("Failed to send expiry reminder for %s grant %s: %v", grant.Kind, grant.ID, err)
+			continue
+		}
+		if err := s.store.MarkReminded(grant.Kind, grant.ID, now); err != nil {
+			return expired, reminded, fmt.Errorf("failed to mark grant reminded: %w", err)
+		}
+		reminded++
+	}
+	return expired, reminded, nil
+}
+
+// remind notifies the organization's admins, and the grantee when the grant
+// was made to a user, that a grant is about to end
+func (s *AccessGrantService) remind(ctx context.Context, grant *types.ExpiringGrant) error {
+	subject := grant.SubjectType + " " + grant.SubjectID
+	event := &types.NotificationEvent{
+		OrganizationID: grant.OrganizationID,
+		Type:           types.NotificationGrantExpiring,
+		Severity:       types.NotificationSeverityWarning,
+		Title:          "Access grant expiring",
+		Message: fmt.Sprintf("The %s granted to %s expires at %s.",
+			grant.Describe(), subject, grant.ExpiresAt.UTC().Format(time.RFC3339)),
+		ResourceType: grant.Kind + "_grant",
+		ResourceID:   grant.ID,
+		DedupKey:     "grant_expiring:" + grant.Kind + ":" + grant.ID,
+		Data: map[string]interface{}{
+			"kind":         grant.Kind,
+			"subject_type": grant.SubjectType,
+			"subject_id":   grant.SubjectID,
+			"expires_at":   grant.ExpiresAt,
+		},
+	}
+	if grant.NamespaceID != "" {
+		event.Data["namespace_id"] = grant.NamespaceID
+	}
+
+	if _, err := s.notifier.Notify(ctx, event); err != nil {
+		return err
+	}
+	if grant.UserID != "" {
+		personal := *event
+		personal.Message = fmt.Sprintf("Your %s expires at %s. Ask an admin to extend it if you still need it.",
+			grant.Describe(), grant.ExpiresAt.UTC().Format(time.RFC3339))
+		if _, err := s.notifier.NotifyUser(ctx, grant.UserID, &personal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AccessGrantService) auditExpiry(grant *types.ExpiringGrant) {
+	if s.auditor == nil {
+		return
+	}
+	details := map[string]interface{}{
+		"subject_type": grant.SubjectType,
+		"subject_id":   grant.SubjectID,
+		"expires_at":   grant.ExpiresAt,
+	}
+	if grant.Kind == types.AccessGrantKindRole {
+		details["role"] = grant.Role
+	} else {
+		details["namespace_id"] = grant.NamespaceID
+		details["access_level"] = grant.AccessLevel
+	}
+	if err := s.auditor.LogAudit(&types.AuditLog{
+		OrganizationID: grant.OrganizationID,
+		UserID:         grant.UserID,
+		Action:         "expire",
+		Resource:       grant.Kind + "_grant",
+		ResourceID:     grant.ID,
+		Details:        details,
+		Success:        true,
+	}); err != nil {
+		log.Printf("Failed to audit expiry of %s grant %s: %v", grant.Kind, grant.ID, err)
+	}
+}
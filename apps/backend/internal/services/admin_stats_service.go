@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	// defaultStatsDays is the stats window when none is requested
+	defaultStatsDays = 30
+	// maxStatsDays is how far back the stats views aggregate
+	maxStatsDays = 90
+	// defaultStatsStaleAfter is how old a view may get before it is
+	// reported stale
+	defaultStatsStaleAfter = 15 * time.Minute
+	// statsLeaderboardSize is how many tools the leaderboard lists
+	statsLeaderboardSize = 10
+)
+
+// AdminStatsStore reads and refreshes the admin stats views
+type AdminStatsStore interface {
+	RefreshView(view string, refreshedAt time.Time) error
+	ListRefreshes() (map[string]time.Time, error)
+	TopTools(orgID string, since time.Time, limit int) ([]*types.ToolUsageStat, error)
+	ServerRequests(orgID string, since time.Time) ([]*types.ServerErrorStat, error)
+	CountActiveUsers(orgID string, since time.Time) (int64, error)
+	CountUsers(orgID string) (int64, error)
+	CountServers(orgID string) (*types.ServerCounts, error)
+}
+
+// AdminStatsService serves the admin stats from materialized views the
+// worker refreshes, instead of aggregating logs on every request
+type AdminStatsService struct {
+	store      AdminStatsStore
+	now        func() time.Time
+	staleAfter time.Duration
+}
+
+// NewAdminStatsService creates a database-backed admin stats service. Views
+// not refreshed within staleAfter are reported stale.
+func NewAdminStatsService(db *sql.DB, staleAfter time.Duration) *AdminStatsService {
+	return NewAdminStatsServiceWithStore(models.NewAdminStatsModel(db), staleAfter)
+}
+
+// NewAdminStatsServiceWithStore creates an admin stats service over store
+func NewAdminStatsServiceWithStore(store AdminStatsStore, staleAfter time.Duration) *AdminStatsService {
+	if staleAfter <= 0 {
+		staleAfter = defaultStatsStaleAfter
+	}
+	return &AdminStatsService{
+		store:      store,
+		now:        time.Now,
+		staleAfter: staleAfter,
+	}
+}
+
+// SetClock replaces the clock view freshness is measured against
+func (s *AdminStatsService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Refresh recomputes every stats view. A view that fails to refresh does
+// not keep the others from refreshing.
+func (s *AdminStatsService) Refresh(ctx context.Context) error {
+	var errs []error
+	for _, view := range types.StatsViews {
+		if err := s.store.RefreshView(view, s.now()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to refresh %s: %w", view, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetStats summarizes an organization's activity over the last days days,
+// 30 when zero
+func (s *AdminStatsService) GetStats(ctx context.Context, orgID string, days int) (*types.AdminStats, error) {
+	if days == 0 {
+		days = defaultStatsDays
+	}
+	if days < 1 || days > maxStatsDays {
+		return nil, types.NewValidationError(fmt.Sprintf("days must be between 1 and %d", maxStatsDays))
+	}
+
+	now := s.now().UTC()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-days)
+	stats := &types.AdminStats{Since: since, Days: days}
+
+	freshness, err := s.freshness()
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get stats freshness: " + err.Error())
+	}
+	stats.Freshness = freshness
+	for _, view := range freshness {
+		stats.Stale = stats.Stale || view.Stale
+	}
+
+	if stats.Users.Total, err = s.store.CountUsers(orgID); err != nil {
+		return nil, types.NewInternalError("Failed to count users: " + err.Error())
+	}
+	if stats.Users.Active, err = s.store.CountActiveUsers(orgID, since); err != nil {
+		return nil, types.NewInternalError("Failed to count active users: " + err.Error())
+	}
+
+	servers, err := s.store.CountServers(orgID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to count servers: " + err.Error())
+	}
+	stats.Servers = *servers
+
+	if stats.TopTools, err = s.store.TopTools(orgID, since, statsLeaderboardSize); err != nil {
+		return nil, types.NewInternalError("Failed to get tool usage: " + err.Error())
+	}
+
+	requests, err := s.store.ServerRequests(orgID, since)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get server requests: " + err.Error())
+	}
+	stats.ServerErrorRates = []*types.ServerErrorStat{}
+	for _, server := range requests {
+		stats.Requests.Total += server.Requests
+		stats.Requests.Failed += server.Errors
+		if server.ServerID != "" {
+			stats.ServerErrorRates = append(stats.ServerErrorRates, server)
+		}
+	}
+	stats.Requests.Successful = stats.Requests.Total - stats.Requests.Failed
+
+	sort.Slice(stats.ServerErrorRates, func(i, j int) bool {
+		a, b := stats.ServerErrorRates[i], stats.ServerErrorRates[j]
+		if a.ErrorRate != b.ErrorRate {
+			return a.ErrorRate > b.ErrorRate
+		}
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.ServerID < b.ServerID
+	})
+
+	return stats, nil
+}
+
+// freshness reports when each stats view was last refreshed
+func (s *AdminStatsService) freshness() ([]*types.StatsFreshness, error) {
+	refreshes, err := s.store.ListRefreshes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	freshness := make([]*types.StatsFreshness, 0, len(types.StatsViews))
+	for _, view := range types.StatsViews {
+		entry := &types.StatsFreshness{View: view, Stale: true}
+		if refreshedAt, ok := refreshes[view]; ok {
+			age := now.Sub(refreshedAt)
+			entry.RefreshedAt = &refreshedAt
+			entry.AgeSeconds = int64(age.Seconds())
+			entry.Stale = age > s.staleAfter
+		}
+		freshness = append(freshness, entry)
+	}
+	return freshness, nil
+}
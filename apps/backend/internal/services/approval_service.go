@@ -0,0 +1,405 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mailer"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/toolpolicy"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Approval defaults
+const (
+	DefaultApprovalTimeout      = 5 * time.Minute
+	DefaultApprovalMaxTimeout   = 30 * time.Minute
+	DefaultApprovalPollInterval = 2 * time.Second
+
+	approvalWebhookTimeout  = 10 * time.Second
+	defaultApprovalPageSize = 50
+	maxApprovalPageSize     = 200
+	// approvalResponseMargin is how long past an approval's expiry the
+	// waiting request is kept alive to send its response
+	approvalResponseMargin = 10 * time.Second
+)
+
+// ApprovalStore persists tool calls held for approval
+type ApprovalStore interface {
+	Create(approval *types.ToolApproval) error
+	Get(orgID, id string) (*types.ToolApproval, error)
+	List(filter *types.ApprovalListFilter) ([]*types.ToolApproval, error)
+	Decide(id, status, decidedBy, reason string, at time.Time) (bool, error)
+	ExpirePending(orgID string, now time.Time) (int64, error)
+}
+
+// ApprovalSettings control how long held calls wait; zero values take the
+// defaults
+type ApprovalSettings struct {
+	Timeout      time.Duration
+	MaxTimeout   time.Duration
+	PollInterval time.Duration
+}
+
+// ApprovalService parks tool calls a policy holds for approval, tells
+// approvers and resumes or fails the call once an approver decides or the
+// approval times out. Decisions taken on another instance are picked up by
+// polling.
+type ApprovalService struct {
+	store    ApprovalStore
+	notifier AdminNotifier
+	mailer   mailer.Mailer
+	egress   EgressPolicy
+	client   *http.Client
+	now      func() time.Time
+	waiters  map[string]chan struct{}
+	baseURL  string
+	settings ApprovalSettings
+	mu       sync.Mutex
+}
+
+// NewApprovalService creates a database-backed approval service. Approval
+// webhooks link to the admin API under baseURL.
+func NewApprovalService(db *sql.DB, baseURL string, settings ApprovalSettings) *ApprovalService {
+	return NewApprovalServiceWithStore(models.NewToolApprovalModel(db), baseURL, settings)
+}
+
+// NewApprovalServiceWithStore creates an approval service over store
+func NewApprovalServiceWithStore(store ApprovalStore, baseURL string, settings ApprovalSettings) *ApprovalService {
+	if settings.Timeout <= 0 {
+		settings.Timeout = DefaultApprovalTimeout
+	}
+	if settings.MaxTimeout <= 0 {
+		settings.MaxTimeout = DefaultApprovalMaxTimeout
+	}
+	if settings.Timeout > settings.MaxTimeout {
+		settings.Timeout = settings.MaxTimeout
+	}
+	if settings.PollInterval <= 0 {
+		settings.PollInterval = DefaultApprovalPollInterval
+	}
+	return &ApprovalService{
+		store:    store,
+		client:   &http.Client{Timeout: approvalWebhookTimeout},
+		now:      time.Now,
+		waiters:  make(map[string]chan struct{}),
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		settings: settings,
+	}
+}
+
+// SetNotifier makes held calls notify the organization's admins
+func (s *ApprovalService) SetNotifier(notifier AdminNotifier) {
+	s.notifier = notifier
+}
+
+// SetMailer makes held calls email the approvers their policy lists
+func (s *ApprovalService) SetMailer(m mailer.Mailer) {
+	s.mailer = m
+}
+
+// SetEgressPolicy restricts the webhook URLs approvals are posted to
+func (s *ApprovalService) SetEgressPolicy(policy EgressPolicy) {
+	s.egress = policy
+}
+
+// SetHTTPClient replaces the client webhooks are posted with
+func (s *ApprovalService) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// SetClock replaces the clock approvals are timestamped with
+func (s *ApprovalService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// AwaitApproval records a call held by decision, notifies approvers and
+// waits until the approval is decided, times out or ctx is done. It
+// returns the approval in its final status.
+func (s *ApprovalService) AwaitApproval(ctx context.Context, orgID string, call *toolpolicy.Call, decision *types.ToolPolicyDecision) (*types.ToolApproval, error) {
+	timeout := s.settings.Timeout
+	if decision.Approval != nil && decision.Approval.TimeoutSeconds > 0 {
+		timeout = time.Duration(decision.Approval.TimeoutSeconds) * time.Second
+	}
+	if timeout > s.settings.MaxTimeout {
+		timeout = s.settings.MaxTimeout
+	}
+
+	now := s.now()
+	approval := &types.ToolApproval{
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(timeout),
+		Arguments:      call.Arguments,
+		OrganizationID: orgID,
+		NamespaceID:    call.NamespaceID,
+		ServerID:       call.ServerID,
+		Tool:           call.Tool,
+		PolicyID:       decision.PolicyID,
+		PolicyName:     decision.PolicyName,
+		Message:        decision.Message,
+		RequestedBy:    call.Principal.Key(),
+		Status:         types.ApprovalStatusPending,
+	}
+	if err := s.store.Create(approval); err != nil {
+		return nil, fmt.Errorf("failed to record approval: %w", err)
+	}
+
+	waiter := make(chan struct{})
+	s.mu.Lock()
+	s.waiters[approval.ID] = waiter
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.waiters, approval.ID)
+		s.mu.Unlock()
+	}()
+
+	// Keep the request open past the gateway's request timeout while the
+	// call waits
+	types.ExtendDeadline(ctx, time.Now().Add(timeout+approvalResponseMargin))
+
+	s.notify(ctx, approval, decision.Approval)
+
+	expired := time.NewTimer(timeout)
+	defer expired.Stop()
+	poll := time.NewTicker(s.settings.PollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-waiter:
+			// Decided on this instance; later checks fall back to polling
+			waiter = nil
+		case <-poll.C:
+		case <-expired.C:
+			return s.close(approval.ID, types.ApprovalStatusExpired)
+		case <-ctx.Done():
+			return s.close(approval.ID, types.ApprovalStatusCancelled)
+		}
+
+		current, err := s.store.Get("", approval.ID)
+		if err != nil {
+			log.Printf("Failed to check approval %s: %v", approval.ID, err)
+			continue
+		}
+		if current != nil && current.Status != types.ApprovalStatusPending {
+			return current, nil
+		}
+	}
+}
+
+// Decide approves or rejects a pending approval and resumes the call
+// waiting on it
+func (s *ApprovalService) Decide(ctx context.Context, orgID, id string, approve bool, decidedBy, reason string) (*types.ToolApproval, error) {
+	approval, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != types.ApprovalStatusPending {
+		return nil, types.NewConflictError(fmt.Sprintf("Approval is already %s", approval.Status))
+	}
+
+	status := types.ApprovalStatusRejected
+	if approve {
+		status = types.ApprovalStatusApproved
+	}
+	decided, err := s.store.Decide(id, status, decidedBy, reason, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decide approval: %w", err)
+	}
+	if !decided {
+		return nil, types.NewConflictError("Approval is no longer pending")
+	}
+
+	s.mu.Lock()
+	if waiter, ok := s.waiters[id]; ok {
+		close(waiter)
+		delete(s.waiters, id)
+	}
+	s.mu.Unlock()
+
+	return s.Get(ctx, orgID, id)
+}
+
+// Get returns an approval of the organization
+func (s *ApprovalService) Get(ctx context.Context, orgID, id string) (*types.ToolApproval, error) {
+	approval, err := s.store.Get(orgID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval: %w", err)
+	}
+	if approval == nil {
+		return nil, types.NewNotFoundError("Approval not found")
+	}
+	if approval.Status == types.ApprovalStatusPending && s.now().After(approval.ExpiresAt) {
+		// The instance waiting on it stopped before expiring it
+		return s.close(approval.ID, types.ApprovalStatusExpired)
+	}
+	return approval, nil
+}
+
+// List returns a page of the organization's approvals, newest first,
+// optionally only those in status
+func (s *ApprovalService) List(ctx context.Context, orgID, status string, limit, offset int) ([]*types.ToolApproval, error) {
+	switch status {
+	case "", types.ApprovalStatusPending, types.ApprovalStatusApproved, types.ApprovalStatusRejected,
+		types.ApprovalStatusExpired, types.ApprovalStatusCancelled:
+	default:
+		return nil, types.NewValidationError(fmt.Sprintf("unknown approval status %q", status))
+	}
+	if limit <= 0 {
+		limit = defaultApprovalPageSize
+	}
+	if limit > maxApprovalPageSize {
+		limit = maxApprovalPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if _, err := s.store.ExpirePending(orgID, s.now()); err != nil {
+		return nil, fmt.Errorf("failed to expire approvals: %w", err)
+	}
+	approvals, err := s.store.List(&types.ApprovalListFilter{
+		OrganizationID: orgID,
+		Status:         status,
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approvals: %w", err)
+	}
+	return approvals, nil
+}
+
+// close ends a pending approval nobody decided and returns it in its final
+// status, which is an approver's decision when one came in first
+func (s *ApprovalService) close(id, status string) (*types.ToolApproval, error) {
+	if _, err := s.store.Decide(id, status, "", "", s.now()); err != nil {
+		return nil, fmt.Errorf("failed to close approval: %w", err)
+	}
+	approval, err := s.store.Get("", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval: %w", err)
+	}
+	if approval == nil {
+		return nil, types.NewNotFoundError("Approval not found")
+	}
+	return approval, nil
+}
+
+// notify tells the organization's admins, the policy's approvers and its
+// webhook about a held call. Failures are logged; the call still waits.
+func (s *ApprovalService) notify(ctx context.Context, approval *types.ToolApproval, settings *types.ToolApprovalSettings) {
+	payload := &types.ApprovalWebhookPayload{
+		Approval: approval,
+		Event:    types.ApprovalWebhookEvent,
+	}
+	if s.baseURL != "" {
+		payload.DecideURL = fmt.Sprintf("%s/api/admin/approvals/%s", s.baseURL, approval.ID)
+	}
+
+	if s.notifier != nil {
+		_, err := s.notifier.Notify(ctx, &types.NotificationEvent{
+			OrganizationID: approval.OrganizationID,
+			Type:           types.NotificationApprovalPending,
+			Severity:       types.NotificationSeverityWarning,
+			Title:          fmt.Sprintf("Tool call %s is waiting for approval", approval.Tool),
+			Message: fmt.Sprintf("%s called %s, which policy %s holds for approval until %s.",
+				approval.RequestedBy, approval.Tool, approval.PolicyName, approval.ExpiresAt.UTC().Format(time.RFC1123)),
+			ResourceType: "tool_approval",
+			ResourceID:   approval.ID,
+			DedupKey:     types.NotificationApprovalPending + ":" + approval.ID,
+			Data: map[string]interface{}{
+				"namespace_id": approval.NamespaceID,
+				"policy_id":    approval.PolicyID,
+			},
+		})
+		if err != nil {
+			log.Printf("Failed to notify admins of approval %s: %v", approval.ID, err)
+		}
+	}
+	if settings == nil {
+		return
+	}
+
+	if settings.WebhookURL != "" {
+		if err := s.postWebhook(ctx, settings.WebhookURL, payload); err != nil {
+			log.Printf("Failed to post approval %s to webhook: %v", approval.ID, err)
+		}
+	}
+	if s.mailer != nil {
+		for _, email := range settings.Emails {
+			if err := s.mailer.Send(ctx, BuildApprovalEmail(email, payload)); err != nil {
+				log.Printf("Failed to email approval %s to %s: %v", approval.ID, email, err)
+			}
+		}
+	}
+}
+
+func (s *ApprovalService) postWebhook(ctx context.Context, webhookURL string, payload *types.ApprovalWebhookPayload) error {
+	if s.egress != nil {
+		if err := s.egress.CheckURL(types.ConnectivityFeatureApprovalWebhooks, webhookURL); err != nil {
+			return err
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Omnimesh-Gateway")
+	req.Header.Set("X-Omnimesh-Event", payload.Event)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// BuildApprovalEmail renders the email asking an approver to decide a held
+// call
+func BuildApprovalEmail(to string, payload *types.ApprovalWebhookPayload) *mailer.Message {
+	approval := payload.Approval
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s called tool %s in namespace %s.\n", approval.RequestedBy, approval.Tool, approval.NamespaceID)
+	fmt.Fprintf(&body, "Policy %s holds the call until it is approved or rejected.\n", approval.PolicyName)
+	if approval.Message != "" {
+		fmt.Fprintf(&body, "\n%s\n", approval.Message)
+	}
+	if len(approval.Arguments) > 0 {
+		if arguments, err := json.MarshalIndent(approval.Arguments, "", "  "); err == nil {
+			fmt.Fprintf(&body, "\nArguments:\n%s\n", arguments)
+		}
+	}
+	fmt.Fprintf(&body, "\nThe call fails if nobody decides by %s.\n", approval.ExpiresAt.UTC().Format(time.RFC1123))
+	if payload.DecideURL != "" {
+		fmt.Fprintf(&body, "\nApprove or reject it with an authenticated POST to:\n  %s/approve\n  %s/reject\n",
+			payload.DecideURL, payload.DecideURL)
+	}
+
+	return &mailer.Message{
+		To:      to,
+		Subject: fmt.Sprintf("Omnimesh Gateway: approve call to %s?", approval.Tool),
+		Body:    body.String(),
+	}
+}
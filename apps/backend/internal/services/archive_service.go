@@ -0,0 +1,624 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/objectstore"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	// defaultArchiveAfterDays is how old logs get before they are archived
+	// when an organization has not said
+	defaultArchiveAfterDays = 30
+	maxArchiveAfterDays     = 3650
+	// archiveDaysPerRun bounds the days archived per organization and
+	// dataset in one pass, so a backlog is worked off over several passes
+	archiveDaysPerRun      = 31
+	archiveListLimit       = 1000
+	archiveManifestVersion = 1
+	rehydrationListLimit   = 50
+	maxRehydrationTTL      = 7 * 24 * time.Hour
+	maxRehydrationRange    = 31 * 24 * time.Hour
+	// rehydrationBatchSize is how many rows are inserted at once
+	rehydrationBatchSize = 500
+	// maxArchivedRowSize bounds one archived row when it is read back
+	maxArchivedRowSize = 16 << 20
+)
+
+// ArchiveStore persists archive policies, archived days and rehydrations,
+// and moves rows out of and back into the log tables. GetPolicy and
+// GetRehydration return nil when there is none.
+type ArchiveStore interface {
+	GetPolicy(orgID string) (*types.ArchivePolicy, error)
+	UpsertPolicy(policy *types.ArchivePolicy) error
+	ListEnabledPolicies() ([]*types.ArchivePolicy, error)
+	HasActiveLegalHold(orgID string) (bool, error)
+	ListArchivableDays(orgID, dataset string, before time.Time, limit int) ([]time.Time, error)
+	ExportRows(orgID, dataset string, start, end time.Time, fn func(row *types.ArchiveRow) error) error
+	CompleteArchive(archive *types.LogArchive, ids []string) error
+	ListArchives(orgID string, query *types.ArchiveQuery, limit int) ([]*types.LogArchive, error)
+	RestoreRows(orgID, dataset string, rows [][]byte) (int64, error)
+	RemoveRows(orgID, dataset string, start, end time.Time) (int64, error)
+	CreateRehydration(r *types.ArchiveRehydration) error
+	GetRehydration(orgID, id string) (*types.ArchiveRehydration, error)
+	ListRehydrations(orgID string, limit int) ([]*types.ArchiveRehydration, error)
+	ClaimPendingRehydrations(limit int) ([]*types.ArchiveRehydration, error)
+	FinishRehydration(r *types.ArchiveRehydration) error
+	ListExpiredRehydrations(now time.Time) ([]*types.ArchiveRehydration, error)
+	MarkRehydrationExpired(id string, at time.Time) error
+	IsRehydrated(orgID, dataset string, start, end time.Time, excludeID string, now time.Time) (bool, error)
+}
+
+// ArchiveService moves execution logs and session events past an
+// organization's archive policy to cold storage, one gzipped JSON Lines
+// object and manifest per UTC day, keeping their counts queryable. Archived
+// days can be rehydrated into the log tables for a while to investigate
+// them with the usual tools.
+type ArchiveService struct {
+	store          ArchiveStore
+	storage        ExportStorage
+	now            func() time.Time
+	rehydrationTTL time.Duration
+}
+
+// NewArchiveStorage returns the archive storage named by storage: s3 keeps
+// archives in the bucket of s3Config, anything else under dir. The bucket
+// is operator configuration, so it may be inside the deployment's network,
+// but egress must allow reaching it.
+func NewArchiveStorage(storage, dir string, s3Config objectstore.S3Config, egress EgressPolicy) (ExportStorage, error) {
+	if storage == "s3" {
+		endpoint := s3Config.Endpoint
+		if endpoint == "" {
+			endpoint = objectstore.AWSEndpoint(s3Config.Region)
+		}
+		if egress != nil {
+			if err := egress.CheckURL(types.ConnectivityFeatureLogArchive, endpoint); err != nil {
+				return nil, err
+			}
+		}
+		return objectstore.NewS3Store(s3Config)
+	}
+	return NewFileExportStorage(dir), nil
+}
+
+// NewArchiveService creates a database-backed archive service keeping
+// archives in storage. Rehydrations last rehydrationTTL unless requested
+// otherwise.
+func NewArchiveService(db *sql.DB, storage ExportStorage, rehydrationTTL time.Duration) *ArchiveService {
+	return NewArchiveServiceWithStore(models.NewLogArchiveModel(db), storage, rehydrationTTL)
+}
+
+// NewArchiveServiceWithStore creates an archive service over store
+func NewArchiveServiceWithStore(store ArchiveStore, storage ExportStorage, rehydrationTTL time.Duration) *ArchiveService {
+	if rehydrationTTL <= 0 {
+		rehydrationTTL = 24 * time.Hour
+	}
+	return &ArchiveService{
+		store:          store,
+		storage:        storage,
+		now:            time.Now,
+		rehydrationTTL: rehydrationTTL,
+	}
+}
+
+// SetClock replaces the clock logs are archived and rehydrations expired
+// against
+func (s *ArchiveService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// GetPolicy returns an organization's archive policy, disabled by default
+func (s *ArchiveService) GetPolicy(ctx context.Context, orgID string) (*types.ArchivePolicy, error) {
+	policy, err := s.store.GetPolicy(orgID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get archive policy: " + err.Error())
+	}
+	if policy == nil {
+		policy = &types.ArchivePolicy{OrganizationID: orgID, ArchiveAfterDays: defaultArchiveAfterDays}
+	}
+	return policy, nil
+}
+
+// UpdatePolicy changes an organization's archive policy
+func (s *ArchiveService) UpdatePolicy(ctx context.Context, orgID, userID string, req *types.UpdateArchivePolicyRequest) (*types.ArchivePolicy, error) {
+	policy, err := s.GetPolicy(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+	if req.ArchiveAfterDays != nil {
+		policy.ArchiveAfterDays = *req.ArchiveAfterDays
+	}
+	if policy.ArchiveAfterDays < 1 || policy.ArchiveAfterDays > maxArchiveAfterDays {
+		return nil, types.NewValidationError(fmt.Sprintf("archive_after_days must be between 1 and %d", maxArchiveAfterDays))
+	}
+	policy.UpdatedBy = userID
+
+	if err := s.store.UpsertPolicy(policy); err != nil {
+		return nil, types.NewInternalError("Failed to update archive policy: " + err.Error())
+	}
+	return policy, nil
+}
+
+// ListArchives returns an organization's archived days overlapping the
+// query, newest first
+func (s *ArchiveService) ListArchives(ctx context.Context, orgID string, query *types.ArchiveQuery) ([]*types.LogArchive, error) {
+	if query.Dataset != "" && models.ArchiveTableName(query.Dataset) == "" {
+		return nil, types.NewValidationError("dataset must be execution_logs or session_events")
+	}
+	if !query.StartTime.IsZero() && !query.EndTime.IsZero() && !query.EndTime.After(query.StartTime) {
+		return nil, types.NewValidationError("end_time must be after start_time")
+	}
+
+	archives, err := s.store.ListArchives(orgID, query, archiveListLimit)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list archives: " + err.Error())
+	}
+	if archives == nil {
+		archives = []*types.LogArchive{}
+	}
+	return archives, nil
+}
+
+// Archive archives the days past every enabled archive policy.
+// Organizations under legal hold are skipped. A day that fails to archive
+// keeps its rows and is retried on the next pass.
+func (s *ArchiveService) Archive(ctx context.Context) (*types.ArchiveRun, error) {
+	run := &types.ArchiveRun{Archived: []*types.LogArchive{}, Held: []string{}}
+
+	policies, err := s.store.ListEnabledPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive policies: %w", err)
+	}
+
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var errs []error
+	for _, policy := range policies {
+		held, err := s.store.HasActiveLegalHold(policy.OrganizationID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to check legal hold of %s: %w", policy.OrganizationID, err))
+			continue
+		}
+		if held {
+			run.Held = append(run.Held, policy.OrganizationID)
+			continue
+		}
+
+		cutoff := today.AddDate(0, 0, -policy.ArchiveAfterDays)
+		for _, dataset := range types.ArchiveDatasets {
+			days, err := s.store.ListArchivableDays(policy.OrganizationID, dataset, cutoff, archiveDaysPerRun)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to list %s days of %s: %w", dataset, policy.OrganizationID, err))
+				continue
+			}
+			for _, day := range days {
+				archive, err := s.archiveDay(policy.OrganizationID, dataset, day)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to archive %s of %s for %s: %w",
+						dataset, policy.OrganizationID, day.Format("2006-01-02"), err))
+					break
+				}
+				if archive != nil {
+					run.Archived = append(run.Archived, archive)
+					run.Rows += archive.RowCount
+				}
+			}
+		}
+	}
+	return run, errors.Join(errs...)
+}
+
+// archiveDay writes one day of a dataset to storage with its manifest,
+// then records it and deletes its rows. Objects are keyed by day, so a day
+// that failed half way is overwritten when it is retried.
+func (s *ArchiveService) archiveDay(orgID, dataset string, day time.Time) (*types.LogArchive, error) {
+	base := fmt.Sprintf("%s/%s/%s", orgID, dataset, day.Format("2006/01/02"))
+	archive := &types.LogArchive{
+		OrganizationID: orgID,
+		Dataset:        dataset,
+		RangeStart:     day,
+		RangeEnd:       day.AddDate(0, 0, 1),
+		StorageKey:     base + ".jsonl.gz",
+		ManifestKey:    base + ".manifest.json",
+		Methods:        make(map[string]int64),
+	}
+
+	ids, err := s.writeArchive(archive)
+	if err != nil {
+		s.storage.Delete(archive.StorageKey)
+		return nil, err
+	}
+	if archive.RowCount == 0 {
+		return nil, s.storage.Delete(archive.StorageKey)
+	}
+
+	if err := s.writeManifest(archive); err != nil {
+		s.storage.Delete(archive.StorageKey)
+		return nil, err
+	}
+	if err := s.store.CompleteArchive(archive, ids); err != nil {
+		s.storage.Delete(archive.StorageKey)
+		s.storage.Delete(archive.ManifestKey)
+		return nil, err
+	}
+	return archive, nil
+}
+
+// writeArchive writes the rows of an archive's day to its object and
+// returns their IDs
+func (s *ArchiveService) writeArchive(archive *types.LogArchive) ([]string, error) {
+	w, err := s.storage.Create(archive.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive object: %w", err)
+	}
+
+	hash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(w, hash)}
+	gz := gzip.NewWriter(counter)
+
+	var ids []string
+	err = s.store.ExportRows(archive.OrganizationID, archive.Dataset, archive.RangeStart, archive.RangeEnd, func(row *types.ArchiveRow) error {
+		if _, err := gz.Write(append(row.Data, '\n')); err != nil {
+			return err
+		}
+		ids = append(ids, row.ID)
+		archive.RowCount++
+		if row.Error {
+			archive.ErrorCount++
+		}
+		method := row.Method
+		if method == "" {
+			method = "unknown"
+		}
+		archive.Methods[method]++
+		return nil
+	})
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	archive.SizeBytes = counter.n
+	archive.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return ids, nil
+}
+
+// writeManifest stores the manifest describing an archive next to it
+func (s *ArchiveService) writeManifest(archive *types.LogArchive) error {
+	manifest, err := json.MarshalIndent(&types.ArchiveManifest{
+		Version:        archiveManifestVersion,
+		OrganizationID: archive.OrganizationID,
+		Dataset:        archive.Dataset,
+		Table:          models.ArchiveTableName(archive.Dataset),
+		RangeStart:     archive.RangeStart,
+		RangeEnd:       archive.RangeEnd,
+		Object:         archive.StorageKey,
+		Format:         "jsonl",
+		Compression:    "gzip",
+		SHA256:         archive.SHA256,
+		RowCount:       archive.RowCount,
+		ErrorCount:     archive.ErrorCount,
+		SizeBytes:      archive.SizeBytes,
+		Methods:        archive.Methods,
+		CreatedAt:      s.now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	w, err := s.storage.Create(archive.ManifestKey)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest object: %w", err)
+	}
+	if _, err := w.Write(manifest); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// CreateRehydration queues a rehydration of the archived days overlapping
+// the requested range
+func (s *ArchiveService) CreateRehydration(ctx context.Context, orgID, userID string, req *types.CreateRehydrationRequest) (*types.ArchiveRehydration, error) {
+	datasets := req.Datasets
+	if len(datasets) == 0 {
+		datasets = types.ArchiveDatasets
+	}
+	seen := make(map[string]bool, len(datasets))
+	for _, dataset := range datasets {
+		if models.ArchiveTableName(dataset) == "" {
+			return nil, types.NewValidationError("datasets must be execution_logs or session_events")
+		}
+		if seen[dataset] {
+			return nil, types.NewValidationError("datasets cannot repeat")
+		}
+		seen[dataset] = true
+	}
+
+	if !req.EndTime.After(req.StartTime) {
+		return nil, types.NewValidationError("end_time must be after start_time")
+	}
+	if req.EndTime.Sub(req.StartTime) > maxRehydrationRange {
+		return nil, types.NewValidationError("a rehydration can cover at most 31 days")
+	}
+
+	ttl := s.rehydrationTTL
+	if req.TTLHours > 0 {
+		ttl = time.Duration(req.TTLHours) * time.Hour
+	}
+	if ttl > maxRehydrationTTL {
+		return nil, types.NewValidationError("ttl_hours cannot exceed 168")
+	}
+
+	archives, err := s.store.ListArchives(orgID, &types.ArchiveQuery{StartTime: req.StartTime, EndTime: req.EndTime}, archiveListLimit)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list archives: " + err.Error())
+	}
+	archived := false
+	for _, archive := range archives {
+		archived = archived || seen[archive.Dataset]
+	}
+	if !archived {
+		return nil, types.NewValidationError("No archived logs in this range")
+	}
+
+	rehydration := &types.ArchiveRehydration{
+		OrganizationID: orgID,
+		RequestedBy:    userID,
+		Datasets:       datasets,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		TTL:            ttl,
+		Status:         types.RehydrationStatusPending,
+	}
+	if err := s.store.CreateRehydration(rehydration); err != nil {
+		return nil, types.NewInternalError("Failed to create rehydration: " + err.Error())
+	}
+	return rehydration, nil
+}
+
+// ListRehydrations returns an organization's most recent rehydrations
+func (s *ArchiveService) ListRehydrations(ctx context.Context, orgID string) ([]*types.ArchiveRehydration, error) {
+	rehydrations, err := s.store.ListRehydrations(orgID, rehydrationListLimit)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list rehydrations: " + err.Error())
+	}
+	if rehydrations == nil {
+		rehydrations = []*types.ArchiveRehydration{}
+	}
+	return rehydrations, nil
+}
+
+// GetRehydration returns a rehydration of the organization
+func (s *ArchiveService) GetRehydration(ctx context.Context, orgID, id string) (*types.ArchiveRehydration, error) {
+	rehydration, err := s.store.GetRehydration(orgID, id)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get rehydration: " + err.Error())
+	}
+	if rehydration == nil {
+		return nil, types.NewNotFoundError("Rehydration not found")
+	}
+	return rehydration, nil
+}
+
+// ReleaseRehydration ends a rehydration before it expires, removing its
+// rows
+func (s *ArchiveService) ReleaseRehydration(ctx context.Context, orgID, id string) (*types.ArchiveRehydration, error) {
+	rehydration, err := s.GetRehydration(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch rehydration.Status {
+	case types.RehydrationStatusRunning:
+		return nil, types.NewConflictError("Rehydration is still running")
+	case types.RehydrationStatusPending, types.RehydrationStatusCompleted:
+	default:
+		return rehydration, nil
+	}
+
+	now := s.now()
+	if rehydration.Status == types.RehydrationStatusCompleted {
+		if err := s.removeRows(rehydration, now); err != nil {
+			return nil, types.NewInternalError("Failed to remove rehydrated logs: " + err.Error())
+		}
+	}
+	if err := s.store.MarkRehydrationExpired(rehydration.ID, now); err != nil {
+		return nil, types.NewInternalError("Failed to release rehydration: " + err.Error())
+	}
+	rehydration.Status = types.RehydrationStatusExpired
+	rehydration.ExpiresAt = &now
+	return rehydration, nil
+}
+
+// ProcessRehydrations restores up to batch pending rehydrations and returns
+// how many were processed. A rehydration that fails has the rows it
+// restored removed again.
+func (s *ArchiveService) ProcessRehydrations(ctx context.Context, batch int) (int, error) {
+	rehydrations, err := s.store.ClaimPendingRehydrations(batch)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, rehydration := range rehydrations {
+		if err := s.rehydrate(rehydration); err != nil {
+			log.Printf("Rehydration %s failed: %v", rehydration.ID, err)
+			if removeErr := s.removeRows(rehydration, s.now()); removeErr != nil {
+				log.Printf("Failed to remove rows of rehydration %s: %v", rehydration.ID, removeErr)
+			}
+			rehydration.Status = types.RehydrationStatusFailed
+			rehydration.Error = err.Error()
+		} else {
+			expiresAt := s.now().Add(rehydration.TTL)
+			rehydration.Status = types.RehydrationStatusCompleted
+			rehydration.ExpiresAt = &expiresAt
+		}
+		completedAt := s.now()
+		rehydration.CompletedAt = &completedAt
+
+		if err := s.store.FinishRehydration(rehydration); err != nil {
+			return 0, fmt.Errorf("failed to record rehydration %s: %w", rehydration.ID, err)
+		}
+	}
+	return len(rehydrations), nil
+}
+
+// ExpireRehydrations removes the rows of rehydrations that expired and
+// returns how many ended. Rows another rehydration still holds stay.
+func (s *ArchiveService) ExpireRehydrations(ctx context.Context) (int, error) {
+	now := s.now()
+	rehydrations, err := s.store.ListExpiredRehydrations(now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	var errs []error
+	for _, rehydration := range rehydrations {
+		if err := s.removeRows(rehydration, now); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove rows of rehydration %s: %w", rehydration.ID, err))
+			continue
+		}
+		if err := s.store.MarkRehydrationExpired(rehydration.ID, *rehydration.ExpiresAt); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		expired++
+	}
+	return expired, errors.Join(errs...)
+}
+
+// rehydrate restores every archived day in the rehydration's range
+func (s *ArchiveService) rehydrate(rehydration *types.ArchiveRehydration) error {
+	for _, dataset := range rehydration.Datasets {
+		archives, err := s.store.ListArchives(rehydration.OrganizationID, &types.ArchiveQuery{
+			Dataset:   dataset,
+			StartTime: rehydration.StartTime,
+			EndTime:   rehydration.EndTime,
+		}, archiveListLimit)
+		if err != nil {
+			return fmt.Errorf("failed to list archives: %w", err)
+		}
+		for _, archive := range archives {
+			restored, err := s.restoreArchive(archive)
+			if err != nil {
+				return fmt.Errorf("failed to restore %s: %w", archive.StorageKey, err)
+			}
+			rehydration.ArchiveCount++
+			rehydration.RowCount += restored
+		}
+	}
+	return nil
+}
+
+// restoreArchive loads an archive's rows back into its table after
+// checking the object is the one that was archived
+func (s *ArchiveService) restoreArchive(archive *types.LogArchive) (int64, error) {
+	r, err := s.storage.Open(archive.StorageKey)
+	if err != nil {
+		return 0, err
+	}
+	content, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return 0, err
+	}
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != archive.SHA256 {
+		return 0, errors.New("archive checksum does not match its manifest")
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return 0, err
+	}
+	defer gz.Close()
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxArchivedRowSize)
+	var restored int64
+	batch := make([][]byte, 0, rehydrationBatchSize)
+	flush := func() error {
+		n, err := s.store.RestoreRows(archive.OrganizationID, archive.Dataset, batch)
+		restored += n
+		batch = batch[:0]
+		return err
+	}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		batch = append(batch, []byte(line))
+		if len(batch) == rehydrationBatchSize {
+			if err := flush(); err != nil {
+				return restored, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return restored, err
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return restored, err
+		}
+	}
+	return restored, nil
+}
+
+// removeRows deletes the rehydrated days of a rehydration that no other
+// rehydration holds at now
+func (s *ArchiveService) removeRows(rehydration *types.ArchiveRehydration, now time.Time) error {
+	for _, dataset := range rehydration.Datasets {
+		archives, err := s.store.ListArchives(rehydration.OrganizationID, &types.ArchiveQuery{
+			Dataset:   dataset,
+			StartTime: rehydration.StartTime,
+			EndTime:   rehydration.EndTime,
+		}, archiveListLimit)
+		if err != nil {
+			return err
+		}
+		for _, archive := range archives {
+			held, err := s.store.IsRehydrated(rehydration.OrganizationID, dataset, archive.RangeStart, archive.RangeEnd, rehydration.ID, now)
+			if err != nil {
+				return err
+			}
+			if held {
+				continue
+			}
+			if _, err := s.store.RemoveRows(rehydration.OrganizationID, dataset, archive.RangeStart, archive.RangeEnd); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// BrandingStore persists organizations' branding
+type BrandingStore interface {
+	Get(orgID string) (*types.OrganizationBranding, error)
+	Set(branding *types.OrganizationBranding) error
+	Delete(orgID string) (bool, error)
+}
+
+// BrandingService manages the branding shown on organizations' public
+// endpoint docs
+type BrandingService struct {
+	store BrandingStore
+}
+
+// NewBrandingService creates a database-backed branding service
+func NewBrandingService(db *sql.DB) *BrandingService {
+	return NewBrandingServiceWithStore(models.NewOrganizationBrandingModel(db))
+}
+
+// NewBrandingServiceWithStore creates a branding service over store
+func NewBrandingServiceWithStore(store BrandingStore) *BrandingService {
+	return &BrandingService{store: store}
+}
+
+// GetBranding returns the organization's branding. Organizations without
+// branding get an empty one, which renders as the gateway's own.
+func (s *BrandingService) GetBranding(ctx context.Context, orgID string) (*types.OrganizationBranding, error) {
+	branding, err := s.store.Get(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branding: %w", err)
+	}
+	if branding == nil {
+		branding = &types.OrganizationBranding{OrganizationID: orgID}
+	}
+	return branding, nil
+}
+
+// SetBranding replaces the organization's branding
+func (s *BrandingService) SetBranding(ctx context.Context, orgID, updatedBy string, req *types.SetOrganizationBrandingRequest) (*types.OrganizationBranding, error) {
+	branding := &types.OrganizationBranding{
+		OrganizationID: orgID,
+		ProductName:    strings.TrimSpace(req.ProductName),
+		LogoURL:        req.LogoURL,
+		PrimaryColor:   strings.ToLower(req.PrimaryColor),
+		AccentColor:    strings.ToLower(req.AccentColor),
+		ContactName:    strings.TrimSpace(req.ContactName),
+		ContactEmail:   req.ContactEmail,
+		ContactURL:     req.ContactURL,
+		UpdatedBy:      updatedBy,
+	}
+	if err := s.store.Set(branding); err != nil {
+		return nil, fmt.Errorf("failed to save branding: %w", err)
+	}
+	return branding, nil
+}
+
+// ResetBranding returns the organization's docs to the gateway's branding
+func (s *BrandingService) ResetBranding(ctx context.Context, orgID string) error {
+	deleted, err := s.store.Delete(orgID)
+	if err != nil {
+		return fmt.Errorf("failed to reset branding: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("Organization has no branding")
+	}
+	return nil
+}
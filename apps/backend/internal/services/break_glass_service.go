@@ -0,0 +1,463 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// breakGlassMFAIssuer names the gateway in authenticator apps
+const breakGlassMFAIssuer = "Omnimesh Gateway break-glass"
+
+// BreakGlassStore persists emergency credentials and sessions
+type BreakGlassStore interface {
+	ListCredentials(orgID string) ([]*types.BreakGlassCredential, error)
+	GetCredential(orgID, id string) (*types.BreakGlassCredential, error)
+	GetCredentialByHash(hash string) (*types.BreakGlassCredential, error)
+	CreateCredential(credential *types.BreakGlassCredential) error
+	RotateCredential(orgID, id, hash, mfaSecret string) (bool, error)
+	DeleteCredential(orgID, id string) (bool, error)
+	ClaimCredential(id string, at time.Time) (bool, error)
+	UserOrganization(userID string) (string, error)
+	CreateSession(session *types.BreakGlassSession) error
+	GetSession(id string) (*types.BreakGlassSession, error)
+	ListSessions(orgID string) ([]*types.BreakGlassSession, error)
+	ListExpiredSessions(now time.Time) ([]*types.BreakGlassSession, error)
+	EndSession(id, endedBy string, at time.Time) (bool, error)
+	RecordAction(id string) error
+}
+
+// BreakGlassTokenIssuer signs the access token of an emergency session
+type BreakGlassTokenIssuer interface {
+	GenerateBreakGlassToken(user *types.User, sessionID string, expiresAt time.Time) (string, error)
+}
+
+// BreakGlassNotifier alerts organization admins
+type BreakGlassNotifier interface {
+	Notify(ctx context.Context, event *types.NotificationEvent) (int, error)
+}
+
+// BreakGlassAuditor records emergency access in the audit log
+type BreakGlassAuditor interface {
+	LogAudit(audit *types.AuditLog) error
+}
+
+// BreakGlassService manages sealed emergency credentials. Activating one
+// with its MFA code opens a session with platform-admin access for a fixed
+// window, alerts every admin of the organization and audits each request
+// made until the session ends on its own or is ended by an admin.
+type BreakGlassService struct {
+	store    BreakGlassStore
+	tokens   BreakGlassTokenIssuer
+	notifier BreakGlassNotifier
+	auditor  BreakGlassAuditor
+	now      func() time.Time
+	window   time.Duration
+}
+
+// NewBreakGlassService creates a database-backed break-glass service whose
+// sessions last window
+func NewBreakGlassService(db *sql.DB, tokens BreakGlassTokenIssuer, window time.Duration) *BreakGlassService {
+	return NewBreakGlassServiceWithStore(models.NewBreakGlassModel(db), tokens, window)
+}
+
+// NewBreakGlassServiceWithStore creates a break-glass service over store
+func NewBreakGlassServiceWithStore(store BreakGlassStore, tokens BreakGlassTokenIssuer, window time.Duration) *BreakGlassService {
+	if window <= 0 {
+		window = types.DefaultBreakGlassWindow
+	}
+	return &BreakGlassService{
+		store:  store,
+		tokens: tokens,
+		now:    time.Now,
+		window: window,
+	}
+}
+
+// SetNotifier configures where admins are alerted of emergency access
+func (s *BreakGlassService) SetNotifier(notifier BreakGlassNotifier) {
+	s.notifier = notifier
+}
+
+// SetAuditor configures where emergency access is audited
+func (s *BreakGlassService) SetAuditor(auditor BreakGlassAuditor) {
+	s.auditor = auditor
+}
+
+// ListCredentials returns the organization's emergency credentials
+func (s *BreakGlassService) ListCredentials(ctx context.Context, orgID string) ([]*types.BreakGlassCredential, error) {
+	credentials, err := s.store.ListCredentials(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list break-glass credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+// CreateCredential seals a new emergency credential for a user of the
+// organization. The returned secrets are not retrievable again.
+func (s *BreakGlassService) CreateCredential(ctx context.Context, orgID, createdBy string, req *types.CreateBreakGlassCredentialRequest) (*types.BreakGlassCredentialSecret, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, types.NewValidationError("name is required")
+	}
+	userOrg, err := s.store.UserOrganization(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if userOrg != orgID {
+		return nil, types.NewValidationError("user is not an active member of the organization")
+	}
+	existing, err := s.store.ListCredentials(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list break-glass credentials: %w", err)
+	}
+	for _, credential := range existing {
+		if strings.EqualFold(credential.Name, name) {
+			return nil, types.NewConflictError("a break-glass credential with this name already exists")
+		}
+	}
+
+	secret, hash, mfaSecret, err := generateBreakGlassSecrets()
+	if err != nil {
+		return nil, err
+	}
+	credential := &types.BreakGlassCredential{
+		OrganizationID: orgID,
+		UserID:         req.UserID,
+		Name:           name,
+		CreatedBy:      createdBy,
+		CredentialHash: hash,
+		MFASecret:      mfaSecret,
+	}
+	if err := s.store.CreateCredential(credential); err != nil {
+		return nil, fmt.Errorf("failed to create break-glass credential: %w", err)
+	}
+	return s.secretFor(credential, secret, mfaSecret), nil
+}
+
+// RotateCredential replaces a credential's secrets, sealing it again after
+// it was used or may have been exposed
+func (s *BreakGlassService) RotateCredential(ctx context.Context, orgID, id string) (*types.BreakGlassCredentialSecret, error) {
+	credential, err := s.credential(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	secret, hash, mfaSecret, err := generateBreakGlassSecrets()
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := s.store.RotateCredential(orgID, id, hash, mfaSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate break-glass credential: %w", err)
+	}
+	if !rotated {
+		return nil, types.NewNotFoundError("Break-glass credential not found")
+	}
+	now := s.now()
+	credential.RotatedAt = &now
+	credential.UsedAt = nil
+	return s.secretFor(credential, secret, mfaSecret), nil
+}
+
+// DeleteCredential deletes a credential of the organization
+func (s *BreakGlassService) DeleteCredential(ctx context.Context, orgID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return types.NewNotFoundError("Break-glass credential not found")
+	}
+	deleted, err := s.store.DeleteCredential(orgID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete break-glass credential: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("Break-glass credential not found")
+	}
+	return nil
+}
+
+// Activate opens an emergency session with a sealed credential and its MFA
+// code. Every failed attempt on a known credential is audited, and the
+// credential cannot be used again until it is rotated.
+func (s *BreakGlassService) Activate(ctx context.Context, req *types.ActivateBreakGlassRequest, remoteIP string) (*types.BreakGlassActivation, error) {
+	denied := types.NewUnauthorizedError("Invalid break-glass credential or MFA code")
+
+	credential, err := s.store.GetCredentialByHash(hashBreakGlassSecret(req.Credential))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get break-glass credential: %w", err)
+	}
+	if credential == nil {
+		return nil, denied
+	}
+	now := s.now()
+	if !auth.ValidateTOTP(credential.MFASecret, req.MFACode, now) {
+		s.audit(credential.OrganizationID, credential.UserID, "activate", credential.ID, remoteIP, "", false, map[string]interface{}{
+			"credential": credential.Name,
+			"failure":    "invalid MFA code",
+		})
+		return nil, denied
+	}
+	if !credential.Sealed() {
+		s.audit(credential.OrganizationID, credential.UserID, "activate", credential.ID, remoteIP, "", false, map[string]interface{}{
+			"credential": credential.Name,
+			"failure":    "credential already used",
+		})
+		return nil, types.NewForbiddenError("This break-glass credential was already used and must be rotated")
+	}
+	userOrg, err := s.store.UserOrganization(credential.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if userOrg != credential.OrganizationID {
+		return nil, types.NewForbiddenError("The break-glass user is no longer an active member of the organization")
+	}
+	claimed, err := s.store.ClaimCredential(credential.ID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim break-glass credential: %w", err)
+	}
+	if !claimed {
+		return nil, types.NewForbiddenError("This break-glass credential was already used and must be rotated")
+	}
+
+	session := &types.BreakGlassSession{
+		ID:             uuid.New().String(),
+		CredentialID:   credential.ID,
+		OrganizationID: credential.OrganizationID,
+		UserID:         credential.UserID,
+		UserEmail:      credential.UserEmail,
+		Reason:         req.Reason,
+		RemoteIP:       remoteIP,
+		StartedAt:      now,
+		ExpiresAt:      now.Add(s.window),
+	}
+	if err := s.store.CreateSession(session); err != nil {
+		return nil, fmt.Errorf("failed to create break-glass session: %w", err)
+	}
+	token, err := s.tokens.GenerateBreakGlassToken(&types.User{
+		ID:             credential.UserID,
+		Email:          credential.UserEmail,
+		OrganizationID: credential.OrganizationID,
+		Role:           types.RoleAdmin,
+		IsActive:       true,
+	}, session.ID, session.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue break-glass token: %w", err)
+	}
+
+	log.Printf("BREAK-GLASS session %s opened for user %s of organization %s: %s",
+		session.ID, session.UserID, session.OrganizationID, session.Reason)
+	s.audit(session.OrganizationID, session.UserID, "activate", session.ID, remoteIP, "", true, map[string]interface{}{
+		"credential": credential.Name,
+		"reason":     session.Reason,
+		"expires_at": session.ExpiresAt,
+	})
+	s.alert(ctx, session, types.NotificationBreakGlassActivated, "Break-glass access activated",
+		fmt.Sprintf("%s opened emergency platform-admin access with credential %q until %s. Reason: %s",
+			s.userLabel(session), credential.Name, session.ExpiresAt.UTC().Format(time.RFC3339), session.Reason))
+
+	return &types.BreakGlassActivation{
+		Session:     session,
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.window.Seconds()),
+	}, nil
+}
+
+// ActiveSession returns an emergency session that still grants access, or
+// nil once it has ended
+func (s *BreakGlassService) ActiveSession(ctx context.Context, sessionID string) (*types.BreakGlassSession, error) {
+	session, err := s.store.GetSession(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get break-glass session: %w", err)
+	}
+	if session == nil || !session.Active(s.now()) {
+		return nil, nil
+	}
+	return session, nil
+}
+
+// RecordAction audits a request made during an emergency session
+func (s *BreakGlassService) RecordAction(ctx context.Context, session *types.BreakGlassSession, action *types.BreakGlassAction) {
+	if err := s.store.RecordAction(session.ID); err != nil {
+		log.Printf("Failed to count break-glass action of session %s: %v", session.ID, err)
+	}
+	s.audit(session.OrganizationID, session.UserID, "request", session.ID, action.RemoteIP, action.UserAgent, action.Status < 400, map[string]interface{}{
+		"method": action.Method,
+		"path":   action.Path,
+		"status": action.Status,
+	})
+}
+
+// ListSessions returns the organization's emergency sessions, newest first
+func (s *BreakGlassService) ListSessions(ctx context.Context, orgID string) ([]*types.BreakGlassSession, error) {
+	sessions, err := s.store.ListSessions(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list break-glass sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// EndSession ends an open emergency session of the organization early
+func (s *BreakGlassService) EndSession(ctx context.Context, orgID, sessionID, endedBy string) error {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return types.NewNotFoundError("Break-glass session not found")
+	}
+	session, err := s.store.GetSession(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get break-glass session: %w", err)
+	}
+	if session == nil || session.OrganizationID != orgID || !session.Active(s.now()) {
+		return types.NewNotFoundError("Break-glass session not found")
+	}
+	return s.end(ctx, session, endedBy)
+}
+
+// ExpireSessions ends sessions whose window has passed, alerting admins of
+// each. It returns how many sessions were ended.
+func (s *BreakGlassService) ExpireSessions(ctx context.Context) (int, error) {
+	sessions, err := s.store.ListExpiredSessions(s.now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired break-glass sessions: %w", err)
+	}
+	ended := 0
+	for _, session := range sessions {
+		if err := s.end(ctx, session, ""); err != nil {
+			return ended, err
+		}
+		ended++
+	}
+	return ended, nil
+}
+
+// end closes a session; an empty endedBy means its window passed
+func (s *BreakGlassService) end(ctx context.Context, session *types.BreakGlassSession, endedBy string) error {
+	now := s.now()
+	ended, err := s.store.EndSession(session.ID, endedBy, now)
+	if err != nil {
+		return fmt.Errorf("failed to end break-glass session: %w", err)
+	}
+	if !ended {
+		return nil
+	}
+	session.EndedAt = &now
+	session.EndedBy = endedBy
+
+	how := "expired"
+	if endedBy != "" {
+		how = "was ended by an admin"
+	}
+	s.audit(session.OrganizationID, endedBy, "end", session.ID, "", "", true, map[string]interface{}{
+		"user_id":      session.UserID,
+		"ended":        how,
+		"action_count": session.ActionCount,
+	})
+	s.alert(ctx, session, types.NotificationBreakGlassEnded, "Break-glass access ended",
+		fmt.Sprintf("The emergency session of %s %s after %d requests. Rotate its credential before it can be used again.",
+			s.userLabel(session), how, session.ActionCount))
+	return nil
+}
+
+func (s *BreakGlassService) credential(orgID, id string) (*types.BreakGlassCredential, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, types.NewNotFoundError("Break-glass credential not found")
+	}
+	credential, err := s.store.GetCredential(orgID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get break-glass credential: %w", err)
+	}
+	if credential == nil {
+		return nil, types.NewNotFoundError("Break-glass credential not found")
+	}
+	return credential, nil
+}
+
+func (s *BreakGlassService) secretFor(credential *types.BreakGlassCredential, secret, mfaSecret string) *types.BreakGlassCredentialSecret {
+	account := credential.Name
+	if credential.UserEmail != "" {
+		account = credential.UserEmail
+	}
+	return &types.BreakGlassCredentialSecret{
+		Credential: credential,
+		Secret:     secret,
+		MFASecret:  mfaSecret,
+		MFAURI:     auth.TOTPURI(breakGlassMFAIssuer, account, mfaSecret),
+	}
+}
+
+func (s *BreakGlassService) userLabel(session *types.BreakGlassSession) string {
+	if session.UserEmail != "" {
+		return session.UserEmail
+	}
+	return "user " + session.UserID
+}
+
+func (s *BreakGlassService) alert(ctx context.Context, session *types.BreakGlassSession, notificationType, title, message string) {
+	if s.notifier == nil {
+		return
+	}
+	if _, err := s.notifier.Notify(ctx, &types.NotificationEvent{
+		OrganizationID: session.OrganizationID,
+		Type:           notificationType,
+		Severity:       types.NotificationSeverityCritical,
+		Title:          title,
+		Message:        message,
+		ResourceType:   "break_glass_session",
+		ResourceID:     session.ID,
+		Data: map[string]interface{}{
+			"user_id":    session.UserID,
+			"reason":     session.Reason,
+			"expires_at": session.ExpiresAt,
+		},
+	}); err != nil {
+		log.Printf("Failed to alert admins of break-glass session %s: %v", session.ID, err)
+	}
+}
+
+func (s *BreakGlassService) audit(orgID, userID, action, resourceID, remoteIP, userAgent string, success bool, details map[string]interface{}) {
+	if s.auditor == nil {
+		return
+	}
+	if err := s.auditor.LogAudit(&types.AuditLog{
+		OrganizationID: orgID,
+		UserID:         userID,
+		Action:         action,
+		Resource:       "break_glass",
+		ResourceID:     resourceID,
+		RemoteIP:       remoteIP,
+		UserAgent:      userAgent,
+		Details:        details,
+		Success:        success,
+	}); err != nil {
+		log.Printf("Failed to audit break-glass %s of %s: %v", action, resourceID, err)
+	}
+}
+
+// generateBreakGlassSecrets returns a new credential, its hash and an MFA
+// secret
+func generateBreakGlassSecrets() (string, string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate break-glass credential: %w", err)
+	}
+	secret := types.BreakGlassCredentialPrefix + hex.EncodeToString(b)
+	mfaSecret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", "", err
+	}
+	return secret, hashBreakGlassSecret(secret), mfaSecret, nil
+}
+
+func hashBreakGlassSecret(secret string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(secret)))
+	return hex.EncodeToString(hash[:])
+}
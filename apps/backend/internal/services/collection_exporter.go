@@ -0,0 +1,327 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// API client collection formats endpoints can be exported in
+const (
+	CollectionFormatPostman  = "postman"
+	CollectionFormatInsomnia = "insomnia"
+)
+
+// CollectionFormats lists the formats endpoint collections can be exported in
+var CollectionFormats = []string{CollectionFormatPostman, CollectionFormatInsomnia}
+
+// postmanSchema is the Postman collection format exports are written in
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// Collection variables holding the base URL and credentials. Exports leave
+// the credentials empty for the developer to fill in.
+const (
+	collectionBaseURLVar     = "baseUrl"
+	collectionAPIKeyVar      = "apiKey"
+	collectionAccessTokenVar = "accessToken"
+)
+
+// CollectionExporter converts an endpoint's OpenAPI spec into API client
+// collections, with a request per tool and auth variables pre-filled
+type CollectionExporter struct {
+	now func() time.Time
+}
+
+// NewCollectionExporter creates a new collection exporter
+func NewCollectionExporter() *CollectionExporter {
+	return &CollectionExporter{now: time.Now}
+}
+
+// collectionRequest is a request of an exported collection
+type collectionRequest struct {
+	Name        string
+	Description string
+	Method      string
+	Path        string
+	Body        string
+}
+
+// collectionAuth is how exported requests authenticate
+type collectionAuth struct {
+	APIKey bool
+	OAuth  bool
+}
+
+// Export renders spec as a collection in format, encoded as JSON
+func (e *CollectionExporter) Export(spec *OpenAPISpec, endpoint *types.Endpoint, format string) ([]byte, error) {
+	requests := collectionRequests(spec)
+	auth := collectionAuth{}
+	if len(spec.Security) > 0 {
+		_, auth.APIKey = spec.Components.SecuritySchemes["api_key"]
+		_, auth.OAuth = spec.Components.SecuritySchemes["oauth2"]
+	}
+	baseURL := ""
+	if len(spec.Servers) > 0 {
+		baseURL = spec.Servers[0].URL
+	}
+
+	var collection interface{}
+	switch format {
+	case CollectionFormatPostman:
+		collection = e.postman(spec, endpoint, baseURL, auth, requests)
+	case CollectionFormatInsomnia:
+		collection = e.insomnia(spec, endpoint, baseURL, auth, requests)
+	default:
+		return nil, types.NewValidationError(fmt.Sprintf("unsupported collection format %q, use one of: %s",
+			format, strings.Join(CollectionFormats, ", ")))
+	}
+	return json.MarshalIndent(collection, "", "  ")
+}
+
+// collectionRequests lists the tools listing and a call per tool, in path
+// order
+func collectionRequests(spec *OpenAPISpec) []collectionRequest {
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var requests []collectionRequest
+	for _, path := range paths {
+		item := spec.Paths[path]
+		if item.Get != nil {
+			requests = append(requests, collectionRequest{
+				Name:        item.Get.Summary,
+				Description: item.Get.Description,
+				Method:      "GET",
+				Path:        path,
+			})
+		}
+		if item.Post == nil {
+			continue
+		}
+		toolName := strings.TrimPrefix(path, "/tools/")
+		request := collectionRequest{
+			Name:        toolName,
+			Description: item.Post.Description,
+			Method:      "POST",
+			Path:        "/tools/" + url.PathEscape(toolName),
+			Body:        "{}",
+		}
+		if item.Post.RequestBody != nil {
+			if example := item.Post.RequestBody.Content["application/json"].Example; example != nil {
+				if encoded, err := json.MarshalIndent(example, "", "  "); err == nil {
+					request.Body = string(encoded)
+				}
+			}
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+// Postman collection v2.1
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Auth     *postmanAuth      `json:"auth,omitempty"`
+	Variable []postmanVariable `json:"variable"`
+	Item     []postmanItem     `json:"item"`
+}
+
+type postmanInfo struct {
+	PostmanID   string `json:"_postman_id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	APIKey []postmanVariable `json:"apikey,omitempty"`
+	Bearer []postmanVariable `json:"bearer,omitempty"`
+}
+
+type postmanVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+type postmanItem struct {
+	Name    string         `json:"name"`
+	Request postmanRequest `json:"request"`
+}
+
+type postmanRequest struct {
+	Method      string            `json:"method"`
+	Header      []postmanVariable `json:"header"`
+	Body        *postmanBody      `json:"body,omitempty"`
+	URL         postmanURL        `json:"url"`
+	Description string            `json:"description,omitempty"`
+}
+
+type postmanBody struct {
+	Mode    string                 `json:"mode"`
+	Raw     string                 `json:"raw"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+type postmanURL struct {
+	Raw  string   `json:"raw"`
+	Host []string `json:"host"`
+	Path []string `json:"path"`
+}
+
+func (e *CollectionExporter) postman(spec *OpenAPISpec, endpoint *types.Endpoint, baseURL string, auth collectionAuth, requests []collectionRequest) *postmanCollection {
+	collection := &postmanCollection{
+		Info: postmanInfo{
+			PostmanID:   collectionID(endpoint, "postman"),
+			Name:        spec.Info.Title,
+			Description: spec.Info.Description,
+			Schema:      postmanSchema,
+		},
+		Variable: []postmanVariable{{Key: collectionBaseURLVar, Value: baseURL}},
+	}
+
+	// API keys go in the X-API-Key header; OAuth-only endpoints take a token
+	switch {
+	case auth.APIKey:
+		collection.Auth = &postmanAuth{Type: "apikey", APIKey: []postmanVariable{
+			{Key: "key", Value: "X-API-Key", Type: "string"},
+			{Key: "value", Value: "{{" + collectionAPIKeyVar + "}}", Type: "string"},
+			{Key: "in", Value: "header", Type: "string"},
+		}}
+		collection.Variable = append(collection.Variable, postmanVariable{Key: collectionAPIKeyVar, Type: "secret"})
+	case auth.OAuth:
+		collection.Auth = &postmanAuth{Type: "bearer", Bearer: []postmanVariable{
+			{Key: "token", Value: "{{" + collectionAccessTokenVar + "}}", Type: "string"},
+		}}
+		collection.Variable = append(collection.Variable, postmanVariable{Key: collectionAccessTokenVar, Type: "secret"})
+	default:
+		collection.Auth = &postmanAuth{Type: "noauth"}
+	}
+
+	for _, request := range requests {
+		item := postmanItem{
+			Name: request.Name,
+			Request: postmanRequest{
+				Method:      request.Method,
+				Header:      []postmanVariable{{Key: "Accept", Value: "application/json"}},
+				Description: request.Description,
+				URL: postmanURL{
+					Raw:  "{{" + collectionBaseURLVar + "}}" + request.Path,
+					Host: []string{"{{" + collectionBaseURLVar + "}}"},
+					Path: strings.Split(strings.TrimPrefix(request.Path, "/"), "/"),
+				},
+			},
+		}
+		if request.Method == "POST" {
+			item.Request.Header = append(item.Request.Header, postmanVariable{Key: "Content-Type", Value: "application/json"})
+			item.Request.Body = &postmanBody{
+				Mode:    "raw",
+				Raw:     request.Body,
+				Options: map[string]interface{}{"raw": map[string]string{"language": "json"}},
+			}
+		}
+		collection.Item = append(collection.Item, item)
+	}
+	return collection
+}
+
+// Insomnia export format 4
+
+type insomniaExport struct {
+	Type         string             `json:"_type"`
+	ExportFormat int                `json:"__export_format"`
+	ExportDate   string             `json:"__export_date"`
+	ExportSource string             `json:"__export_source"`
+	Resources    []insomniaResource `json:"resources"`
+}
+
+type insomniaResource struct {
+	ID             string                 `json:"_id"`
+	Type           string                 `json:"_type"`
+	ParentID       *string                `json:"parentId"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description,omitempty"`
+	Scope          string                 `json:"scope,omitempty"`
+	Data           map[string]string      `json:"data,omitempty"`
+	Method         string                 `json:"method,omitempty"`
+	URL            string                 `json:"url,omitempty"`
+	Body           *insomniaBody          `json:"body,omitempty"`
+	Headers        []insomniaHeader       `json:"headers,omitempty"`
+	Authentication map[string]interface{} `json:"authentication,omitempty"`
+}
+
+type insomniaBody struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type insomniaHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (e *CollectionExporter) insomnia(spec *OpenAPISpec, endpoint *types.Endpoint, baseURL string, auth collectionAuth, requests []collectionRequest) *insomniaExport {
+	workspaceID := "wrk_" + collectionID(endpoint, "workspace")
+	environment := map[string]string{collectionBaseURLVar: baseURL}
+	if auth.APIKey {
+		environment[collectionAPIKeyVar] = ""
+	} else if auth.OAuth {
+		environment[collectionAccessTokenVar] = ""
+	}
+
+	export := &insomniaExport{
+		Type:         "export",
+		ExportFormat: 4,
+		ExportDate:   e.now().UTC().Format(time.RFC3339),
+		ExportSource: "omnimesh-gateway",
+		Resources: []insomniaResource{
+			{ID: workspaceID, Type: "workspace", Name: spec.Info.Title, Description: spec.Info.Description, Scope: "collection"},
+			{ID: "env_" + collectionID(endpoint, "environment"), Type: "environment", ParentID: &workspaceID, Name: "Base Environment", Data: environment},
+		},
+	}
+
+	for _, request := range requests {
+		resource := insomniaResource{
+			ID:          "req_" + collectionID(endpoint, request.Method+" "+request.Path),
+			Type:        "request",
+			ParentID:    &workspaceID,
+			Name:        request.Name,
+			Description: request.Description,
+			Method:      request.Method,
+			URL:         "{{ _." + collectionBaseURLVar + " }}" + request.Path,
+			Headers:     []insomniaHeader{{Name: "Accept", Value: "application/json"}},
+		}
+		if request.Method == "POST" {
+			resource.Body = &insomniaBody{MimeType: "application/json", Text: request.Body}
+			resource.Headers = append(resource.Headers, insomniaHeader{Name: "Content-Type", Value: "application/json"})
+		}
+		switch {
+		case auth.APIKey:
+			resource.Headers = append(resource.Headers, insomniaHeader{Name: "X-API-Key", Value: "{{ _." + collectionAPIKeyVar + " }}"})
+		case auth.OAuth:
+			resource.Authentication = map[string]interface{}{"type": "bearer", "token": "{{ _." + collectionAccessTokenVar + " }}"}
+		}
+		export.Resources = append(export.Resources, resource)
+	}
+	return export
+}
+
+// collectionID derives a stable ID from the endpoint and a resource name,
+// so re-imported collections replace the previous import
+func collectionID(endpoint *types.Endpoint, name string) string {
+	sum := sha256.Sum256([]byte(endpoint.ID + "/" + endpoint.Name + "/" + name))
+	id := hex.EncodeToString(sum[:16])
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:32])
+}
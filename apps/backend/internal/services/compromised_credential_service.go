@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// CompromisedCredentialStore revokes leaked credentials and records them
+type CompromisedCredentialStore interface {
+	RevokeAPIKeyByHash(orgID, keyHash string) (*types.CompromisedCredential, error)
+	RevokeAPIKeyByID(orgID, id string) (*types.CompromisedCredential, error)
+	RevokeOAuthToken(orgID, tokenHash string) (*types.CompromisedCredential, error)
+	Create(credential *types.CompromisedCredential) error
+	List(orgID string) ([]*types.CompromisedCredential, error)
+}
+
+// CompromisedTokenRevoker blacklists access and refresh tokens issued at
+// login
+type CompromisedTokenRevoker interface {
+	ValidateToken(tokenString string) (*auth.Claims, error)
+	InvalidateToken(ctx context.Context, tokenString string) error
+}
+
+// CompromiseNotifier tells owners, or the organization's admins for
+// credentials without an owner, that a credential was revoked
+type CompromiseNotifier interface {
+	Notify(ctx context.Context, event *types.NotificationEvent) (int, error)
+	NotifyUser(ctx context.Context, userID string, event *types.NotificationEvent) (bool, error)
+}
+
+// CompromiseAuditor records revoked credentials in the audit log
+type CompromiseAuditor interface {
+	LogAudit(audit *types.AuditLog) error
+}
+
+// ReplayChecker accepts a signed inbound call, identified by its timestamp
+// and nonce, at most once
+type ReplayChecker interface {
+	Check(ctx context.Context, scope, timestamp, nonce string) error
+}
+
+// CompromisedCredentialService revokes API keys, OAuth tokens and login
+// tokens reported as leaked, either by an admin of their organization or by
+// a threat-intel feed pushing signed reports. Revocation takes effect on the
+// next request: API keys and OAuth tokens are checked against the database
+// on every use and login tokens are added to the token blacklist.
+type CompromisedCredentialService struct {
+	store      CompromisedCredentialStore
+	tokens     CompromisedTokenRevoker
+	notifier   CompromiseNotifier
+	auditor    CompromiseAuditor
+	replay     ReplayChecker
+	feedSecret string
+}
+
+// NewCompromisedCredentialService creates a database-backed compromised
+// credential service. Threat feed reports are refused when feedSecret is
+// empty.
+func NewCompromisedCredentialService(db *sql.DB, tokens CompromisedTokenRevoker, feedSecret string) *CompromisedCredentialService {
+	return NewCompromisedCredentialServiceWithStore(models.NewCompromisedCredentialModel(db), tokens, feedSecret)
+}
+
+// NewCompromisedCredentialServiceWithStore creates a compromised credential
+// service over store
+func NewCompromisedCredentialServiceWithStore(store CompromisedCredentialStore, tokens CompromisedTokenRevoker, feedSecret string) *CompromisedCredentialService {
+	return &CompromisedCredentialService{
+		store:      store,
+		tokens:     tokens,
+		feedSecret: feedSecret,
+	}
+}
+
+// SetNotifier configures where owners are told of revoked credentials
+func (s *CompromisedCredentialService) SetNotifier(notifier CompromiseNotifier) {
+	s.notifier = notifier
+}
+
+// SetAuditor configures where revoked credentials are audited
+func (s *CompromisedCredentialService) SetAuditor(auditor CompromiseAuditor) {
+	s.auditor = auditor
+}
+
+// SetReplayGuard makes threat feed reports acceptable only once, and refuses
+// reports without a timestamp and nonce when the guard requires them
+func (s *CompromisedCredentialService) SetReplayGuard(replay ReplayChecker) {
+	s.replay = replay
+}
+
+// List returns the organization's credentials revoked as compromised
+func (s *CompromisedCredentialService) List(ctx context.Context, orgID string) ([]*types.CompromisedCredential, error) {
+	credentials, err := s.store.List(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list compromised credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+// Report revokes a credential of the organization reported by an admin
+func (s *CompromisedCredentialService) Report(ctx context.Context, orgID, reportedBy string, req *types.ReportCompromisedCredentialRequest) (*types.CompromiseReportResult, error) {
+	if problem := compromiseReportProblem(req); problem != "" {
+		return nil, types.NewValidationError(problem)
+	}
+	return s.revoke(ctx, orgID, types.CompromiseSourceAdmin, reportedBy, []*types.ReportCompromisedCredentialRequest{req})
+}
+
+// VerifyFeedSignature checks that a threat feed report was signed with the
+// configured secret
+func (s *CompromisedCredentialService) VerifyFeedSignature(body []byte, signature string) error {
+	if s.feedSecret == "" {
+		return types.NewNotFoundError("Threat feed is not configured")
+	}
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "sha256=")
+	mac := hmac.New(sha256.New, []byte(s.feedSecret))
+	mac.Write(body)
+	if !hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+		return types.NewUnauthorizedError("invalid threat feed signature")
+	}
+	return nil
+}
+
+// VerifyFeedRequest checks the signature of a threat feed report and that
+// it is not a replay. Reports with a timestamp and nonce are signed over
+// "<timestamp>.<nonce>.<body>". Reports without them are signed over the
+// body alone and only accepted when the replay guard does not require them,
+// so a body-only signature is never checked when it does.
+func (s *CompromisedCredentialService) VerifyFeedRequest(ctx context.Context, body []byte, signature, timestamp, nonce string) error {
+	if timestamp == "" && nonce == "" {
+		if s.replay != nil {
+			if err := s.replay.Check(ctx, types.CompromiseSourceThreatFeed, "", ""); err != nil {
+				return err
+			}
+		}
+		return s.VerifyFeedSignature(body, signature)
+	}
+	signed := append([]byte(timestamp+"."+nonce+"."), body...)
+	if err := s.VerifyFeedSignature(signed, signature); err != nil {
+		return err
+	}
+	if s.replay == nil {
+		return nil
+	}
+	return s.replay.Check(ctx, types.CompromiseSourceThreatFeed, timestamp, nonce)
+}
+
+// ReportFromFeed revokes the credentials of every organization listed in a
+// threat feed report
+func (s *CompromisedCredentialService) ReportFromFeed(ctx context.Context, report *types.ThreatFeedReport) (*types.CompromiseReportResult, error) {
+	for i, req := range report.Credentials {
+		if req.APIKeyID != "" {
+			return nil, types.NewValidationError(fmt.Sprintf("credentials[%d]: threat feeds report tokens or fingerprints, not API key IDs", i))
+		}
+		if problem := compromiseReportProblem(req); problem != "" {
+			return nil, types.NewValidationError(fmt.Sprintf("credentials[%d]: %s", i, problem))
+		}
+	}
+	return s.revoke(ctx, "", types.CompromiseSourceThreatFeed, report.Feed, report.Credentials)
+}
+
+// compromiseReportProblem describes why a report does not name exactly one
+// credential, or returns ""
+func compromiseReportProblem(req *types.ReportCompromisedCredentialRequest) string {
+	named := 0
+	for _, value := range []string{req.Token, req.Fingerprint, req.APIKeyID} {
+		if strings.TrimSpace(value) != "" {
+			named++
+		}
+	}
+	if named != 1 {
+		return "exactly one of token, fingerprint or api_key_id is required"
+	}
+	return ""
+}
+
+// revoke revokes each reported credential, scoped to orgID unless it is
+// empty
+func (s *CompromisedCredentialService) revoke(ctx context.Context, orgID, source, reporter string, reqs []*types.ReportCompromisedCredentialRequest) (*types.CompromiseReportResult, error) {
+	result := &types.CompromiseReportResult{Revoked: []*types.CompromisedCredential{}}
+	for _, req := range reqs {
+		credential, err := s.revokeOne(ctx, orgID, req)
+		if err != nil {
+			return nil, err
+		}
+		if credential == nil {
+			result.Unmatched++
+			continue
+		}
+
+		credential.Source = source
+		credential.Reporter = reporter
+		credential.Reason = strings.TrimSpace(req.Reason)
+		if err := s.store.Create(credential); err != nil {
+			return nil, fmt.Errorf("failed to record compromised credential: %w", err)
+		}
+		s.notify(ctx, credential)
+		s.audit(credential)
+		result.Revoked = append(result.Revoked, credential)
+	}
+	return result, nil
+}
+
+func (s *CompromisedCredentialService) revokeOne(ctx context.Context, orgID string, req *types.ReportCompromisedCredentialRequest) (*types.CompromisedCredential, error) {
+	if req.APIKeyID != "" {
+		credential, err := s.store.RevokeAPIKeyByID(orgID, req.APIKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to revoke API key: %w", err)
+		}
+		return credential, nil
+	}
+
+	token := strings.TrimSpace(req.Token)
+	fingerprint := strings.ToLower(strings.TrimSpace(req.Fingerprint))
+	if token != "" {
+		sum := sha256.Sum256([]byte(token))
+		fingerprint = hex.EncodeToString(sum[:])
+	}
+	digest, err := hex.DecodeString(fingerprint)
+	if err != nil || len(digest) != sha256.Size {
+		return nil, types.NewValidationError("fingerprint must be the hex SHA-256 of the credential")
+	}
+
+	// API keys and OAuth tokens store the digest in different encodings
+	credential, err := s.store.RevokeAPIKeyByHash(orgID, fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if credential == nil {
+		credential, err = s.store.RevokeOAuthToken(orgID, base64.URLEncoding.EncodeToString(digest))
+		if err != nil {
+			return nil, fmt.Errorf("failed to revoke OAuth token: %w", err)
+		}
+	}
+	if credential == nil && token != "" && s.tokens != nil {
+		credential, err = s.revokeLoginToken(ctx, orgID, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if credential != nil {
+		credential.Fingerprint = fingerprint
+	}
+	return credential, nil
+}
+
+// revokeLoginToken blacklists a still valid token issued at login
+func (s *CompromisedCredentialService) revokeLoginToken(ctx context.Context, orgID, token string) (*types.CompromisedCredential, error) {
+	claims, err := s.tokens.ValidateToken(token)
+	if err != nil || claims.UserID == "" || (orgID != "" && claims.OrganizationID != orgID) {
+		return nil, nil
+	}
+	if err := s.tokens.InvalidateToken(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return &types.CompromisedCredential{
+		OrganizationID: claims.OrganizationID,
+		UserID:         claims.UserID,
+		CredentialType: types.CompromisedJWT,
+		CredentialID:   claims.ID,
+		CredentialName: claims.TokenType + " token",
+	}, nil
+}
+
+func (s *CompromisedCredentialService) notify(ctx context.Context, credential *types.CompromisedCredential) {
+	if s.notifier == nil {
+		return
+	}
+	var title, message string
+	switch credential.CredentialType {
+	case types.CompromisedAPIKey:
+		title = fmt.Sprintf("API key %q was revoked", credential.CredentialName)
+		message = "The key was reported as leaked and no longer works. Create a new key and update the clients that used it."
+	case types.CompromisedOAuthToken:
+		title = fmt.Sprintf("A token of OAuth client %q was revoked", credential.CredentialName)
+		message = "The token was reported as leaked and no longer works. Request a new token, and rotate the client secret if it may have leaked too."
+	default:
+		title = "A sign-in token was revoked"
+		message = "One of your sign-in tokens was reported as leaked and no longer works. Sign in again and change your password."
+	}
+	event := &types.NotificationEvent{
+		OrganizationID: credential.OrganizationID,
+		Type:           types.NotificationCredentialCompromised,
+		Severity:       types.NotificationSeverityCritical,
+		Title:          title,
+		Message:        message,
+		ResourceType:   "compromised_credential",
+		ResourceID:     credential.ID,
+		Data: map[string]interface{}{
+			"credential_type": credential.CredentialType,
+			"credential_id":   credential.CredentialID,
+			"source":          credential.Source,
+		},
+	}
+
+	var err error
+	if credential.UserID != "" {
+		_, err = s.notifier.NotifyUser(ctx, credential.UserID, event)
+	} else {
+		_, err = s.notifier.Notify(ctx, event)
+	}
+	if err != nil {
+		log.Printf("Failed to notify of compromised credential %s: %v", credential.ID, err)
+	}
+}
+
+func (s *CompromisedCredentialService) audit(credential *types.CompromisedCredential) {
+	if s.auditor == nil {
+		return
+	}
+	if err := s.auditor.LogAudit(&types.AuditLog{
+		OrganizationID: credential.OrganizationID,
+		UserID:         credential.UserID,
+		Action:         "revoke",
+		Resource:       "compromised_credential",
+		ResourceID:     credential.ID,
+		Details: map[string]interface{}{
+			"credential_type": credential.CredentialType,
+			"credential_id":   credential.CredentialID,
+			"fingerprint":     credential.Fingerprint,
+			"source":          credential.Source,
+			"reporter":        credential.Reporter,
+			"reason":          credential.Reason,
+		},
+		Success: true,
+	}); err != nil {
+		log.Printf("Failed to audit compromised credential %s: %v", credential.ID, err)
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// DelegationStore persists delegation policies and looks up the users they
+// apply to
+type DelegationStore interface {
+	Get(orgID, serviceAccountID string) (*types.DelegationPolicy, error)
+	Set(policy *types.DelegationPolicy) (bool, error)
+	Delete(orgID, serviceAccountID string) (bool, error)
+	FindUser(orgID, subject string) (*types.User, error)
+}
+
+// DelegationService manages which users trusted service accounts may act on
+// behalf of, and resolves the X-On-Behalf-Of header of their requests to the
+// effective user
+type DelegationService struct {
+	store DelegationStore
+}
+
+// NewDelegationService creates a database-backed delegation service
+func NewDelegationService(db *sql.DB) *DelegationService {
+	return NewDelegationServiceWithStore(models.NewDelegationPolicyModel(db))
+}
+
+// NewDelegationServiceWithStore creates a delegation service over store
+func NewDelegationServiceWithStore(store DelegationStore) *DelegationService {
+	return &DelegationService{store: store}
+}
+
+// GetPolicy returns the delegation policy of a service account
+func (s *DelegationService) GetPolicy(ctx context.Context, orgID, serviceAccountID string) (*types.DelegationPolicy, error) {
+	policy, err := s.store.Get(orgID, serviceAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delegation policy: %w", err)
+	}
+	if policy == nil {
+		return nil, types.NewNotFoundError("Delegation policy not found")
+	}
+	return policy, nil
+}
+
+// SetPolicy lets a service account act on behalf of the users the request
+// allows, replacing its previous policy
+func (s *DelegationService) SetPolicy(ctx context.Context, orgID, serviceAccountID, setBy string, req *types.SetDelegationPolicyRequest) (*types.DelegationPolicy, error) {
+	policy := &types.DelegationPolicy{
+		ServiceAccountID: serviceAccountID,
+		OrganizationID:   orgID,
+		CreatedBy:        setBy,
+		AllowedRoles:     req.AllowedRoles,
+		AllowedUserIDs:   req.AllowedUserIDs,
+	}
+	if policy.AllowedRoles == nil {
+		policy.AllowedRoles = []string{}
+	}
+	if policy.AllowedUserIDs == nil {
+		policy.AllowedUserIDs = []string{}
+	}
+	saved, err := s.store.Set(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save delegation policy: %w", err)
+	}
+	if !saved {
+		return nil, types.NewNotFoundError("Service account not found")
+	}
+	return policy, nil
+}
+
+// DeletePolicy stops a service account from acting on behalf of users
+func (s *DelegationService) DeletePolicy(ctx context.Context, orgID, serviceAccountID string) error {
+	deleted, err := s.store.Delete(orgID, serviceAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to delete delegation policy: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("Delegation policy not found")
+	}
+	return nil
+}
+
+// Resolve returns the user, named by ID or email, a service account of the
+// organization acts for. It refuses unless the account's policy permits
+// that active user of the same organization; refusals do not reveal whether
+// the user exists.
+func (s *DelegationService) Resolve(ctx context.Context, orgID, serviceAccountID, subject string) (*types.User, error) {
+	policy, err := s.store.Get(orgID, serviceAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delegation policy: %w", err)
+	}
+	if policy == nil {
+		return nil, types.NewForbiddenError("this service account may not act on behalf of users")
+	}
+
+	user, err := s.store.FindUser(orgID, strings.TrimSpace(subject))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up delegating user: %w", err)
+	}
+	if user == nil || !user.IsActive || user.IsServiceAccount() || !policy.Permits(user) {
+		return nil, types.NewForbiddenError("this service account may not act on behalf of " + subject)
+	}
+	return user, nil
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/dlp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// dlpPolicyCacheTTL bounds how long a policy change made on another
+	// instance takes to apply
+	dlpPolicyCacheTTL = 10 * time.Second
+	// defaultDLPEventLimit and maxDLPEventLimit bound event listings
+	defaultDLPEventLimit = 100
+	maxDLPEventLimit     = 1000
+)
+
+// DLPStore persists the DLP policies of servers and the calls they caught
+type DLPStore interface {
+	ServerExists(orgID, serverID string) (bool, error)
+	GetPolicy(serverID string) (*types.DLPPolicy, error)
+	UpsertPolicy(policy *types.DLPPolicy) error
+	DeletePolicy(serverID string) (bool, error)
+	CreateEvent(event *types.DLPEvent) error
+	ListEvents(orgID, serverID string, limit int) ([]*types.DLPEvent, error)
+}
+
+// DLPService checks the arguments of tool calls against the sensitivity
+// label of the server they go to, and blocks, redacts or logs calls
+// carrying more sensitive data
+type DLPService struct {
+	store              DLPStore
+	scanner            *dlp.Scanner
+	defaultSensitivity string
+	defaultAction      string
+	now                func() time.Time
+	cache              map[string]cachedDLPPolicy
+	mu                 sync.Mutex
+}
+
+type cachedDLPPolicy struct {
+	loadedAt time.Time
+	policy   *types.DLPPolicy
+}
+
+// NewDLPService creates a database-backed DLP service. Servers without a
+// policy get defaultSensitivity and defaultAction.
+func NewDLPService(db *sql.DB, scanner *dlp.Scanner, defaultSensitivity, defaultAction string) *DLPService {
+	return NewDLPServiceWithStore(models.NewDLPModel(db), scanner, defaultSensitivity, defaultAction)
+}
+
+// NewDLPServiceWithStore creates a DLP service over store
+func NewDLPServiceWithStore(store DLPStore, scanner *dlp.Scanner, defaultSensitivity, defaultAction string) *DLPService {
+	return &DLPService{
+		store:              store,
+		scanner:            scanner,
+		defaultSensitivity: defaultSensitivity,
+		defaultAction:      defaultAction,
+		now:                time.Now,
+		cache:              make(map[string]cachedDLPPolicy),
+	}
+}
+
+// SetClock replaces the clock used to expire cached policies
+func (s *DLPService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// NewDLPScanner creates a scanner with the built-in classifiers and, when
+// externalURL is set, an external DLP API. Calls fail while the API fails
+// unless failOpen.
+func NewDLPScanner(externalURL, apiKey string, timeout time.Duration, failOpen bool, egress EgressPolicy) (*dlp.Scanner, error) {
+	classifiers := []dlp.Classifier{dlp.Builtin()}
+	if externalURL != "" {
+		external, err := dlp.NewExternal(externalURL, apiKey, timeout, egress)
+		if err != nil {
+			return nil, err
+		}
+		var classifier dlp.Classifier = external
+		if failOpen {
+			classifier = dlp.FailOpen(external, func(err error) {
+				log.Printf("DLP API failed, checking with built-in classifiers only: %v", err)
+			})
+		}
+		classifiers = append(classifiers, classifier)
+	}
+	return dlp.New(classifiers...), nil
+}
+
+// ValidateDLPDefaults returns an error unless sensitivity and action are a
+// known sensitivity label and DLP action
+func ValidateDLPDefaults(sensitivity, action string) error {
+	if types.DLPSensitivityRank(sensitivity) < 0 {
+		return fmt.Errorf("unknown sensitivity %q, expected one of %v", sensitivity, types.DLPSensitivities)
+	}
+	switch action {
+	case types.DLPActionBlock, types.DLPActionRedact, types.DLPActionLog:
+		return nil
+	}
+	return fmt.Errorf("unknown action %q, expected block, redact or log", action)
+}
+
+// GetPolicy returns the DLP policy of a server of the organization, or the
+// default policy when it has none
+func (s *DLPService) GetPolicy(ctx context.Context, orgID, serverID string) (*types.DLPPolicy, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	policy, err := s.store.GetPolicy(serverID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get DLP policy: " + err.Error())
+	}
+	if policy == nil {
+		policy = s.defaultPolicy(serverID)
+	}
+	return policy, nil
+}
+
+// SetPolicy labels a server of the organization with the most sensitive
+// data it may receive
+func (s *DLPService) SetPolicy(ctx context.Context, orgID, serverID string, req *types.SetDLPPolicyRequest) (*types.DLPPolicy, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	if err := ValidateDLPDefaults(req.Sensitivity, req.Action); err != nil {
+		return nil, types.NewValidationError(err.Error())
+	}
+	policy := &types.DLPPolicy{ServerID: serverID, Sensitivity: req.Sensitivity, Action: req.Action}
+	if err := s.store.UpsertPolicy(policy); err != nil {
+		return nil, types.NewInternalError("Failed to save DLP policy: " + err.Error())
+	}
+	s.invalidate(serverID)
+	return s.GetPolicy(ctx, orgID, serverID)
+}
+
+// DeletePolicy returns a server of the organization to the default policy
+func (s *DLPService) DeletePolicy(ctx context.Context, orgID, serverID string) error {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return err
+	}
+	deleted, err := s.store.DeletePolicy(serverID)
+	if err != nil {
+		return types.NewInternalError("Failed to delete DLP policy: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Server has no DLP policy")
+	}
+	s.invalidate(serverID)
+	return nil
+}
+
+// ListEvents returns the organization's most recent calls caught by DLP
+// policies, to one server when serverID is set
+func (s *DLPService) ListEvents(ctx context.Context, orgID, serverID string, limit int) ([]*types.DLPEvent, error) {
+	if serverID != "" {
+		if _, err := uuid.Parse(serverID); err != nil {
+			return nil, types.NewValidationError("server_id must be a UUID")
+		}
+	}
+	if limit <= 0 {
+		limit = defaultDLPEventLimit
+	}
+	events, err := s.store.ListEvents(orgID, serverID, min(limit, maxDLPEventLimit))
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list DLP events: " + err.Error())
+	}
+	return events, nil
+}
+
+// CheckArguments checks the arguments of a tool call to a server against
+// its sensitivity label. Calls carrying more sensitive data are recorded
+// and, by the server's action, refused, sent with that data redacted or
+// sent unchanged. args itself is never modified.
+func (s *DLPService) CheckArguments(ctx context.Context, orgID, namespaceID, serverID, tool string, args map[string]interface{}) (map[string]interface{}, error) {
+	policy, err := s.policy(serverID)
+	if err != nil {
+		return nil, types.NewServiceUnavailableError(fmt.Sprintf("DLP policy could not be loaded: %v", err))
+	}
+	// Nothing is above the most sensitive label
+	if policy.Sensitivity == types.DLPSensitivityRestricted {
+		return args, nil
+	}
+
+	findings, err := s.scanner.Scan(ctx, args)
+	if err != nil {
+		return nil, types.NewServiceUnavailableError(fmt.Sprintf("DLP check failed: %v", err))
+	}
+	above := dlp.Above(findings, policy.Sensitivity)
+	if len(above) == 0 {
+		return args, nil
+	}
+
+	described := dlp.Describe(above)
+	event := &types.DLPEvent{
+		Findings:       described,
+		OrganizationID: orgID,
+		NamespaceID:    namespaceID,
+		ServerID:       serverID,
+		Tool:           tool,
+		Action:         policy.Action,
+	}
+	if principal := types.PrincipalFromContext(ctx); principal != nil {
+		event.UserID, event.APIKeyID = principal.UserID, principal.APIKeyID
+	}
+	if err := s.store.CreateEvent(event); err != nil {
+		log.Printf("Failed to record DLP event for tool %s: %v", tool, err)
+	}
+
+	switch policy.Action {
+	case types.DLPActionBlock:
+		classifiers, paths := describeDLPFindings(described)
+		return nil, types.NewDLPViolationError(
+			fmt.Sprintf("Arguments of tool %s carry %s data above the %s label of its server", tool, classifiers, policy.Sensitivity),
+			"arguments "+paths)
+	case types.DLPActionRedact:
+		return dlp.Redact(args, above), nil
+	}
+	return args, nil
+}
+
+// policy returns the cached policy of a server, or the default policy
+func (s *DLPService) policy(serverID string) (*types.DLPPolicy, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[serverID]
+	s.mu.Unlock()
+	if ok && s.now().Sub(cached.loadedAt) < dlpPolicyCacheTTL {
+		return cached.policy, nil
+	}
+
+	policy, err := s.store.GetPolicy(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = s.defaultPolicy(serverID)
+	}
+	s.mu.Lock()
+	s.cache[serverID] = cachedDLPPolicy{loadedAt: s.now(), policy: policy}
+	s.mu.Unlock()
+	return policy, nil
+}
+
+func (s *DLPService) defaultPolicy(serverID string) *types.DLPPolicy {
+	return &types.DLPPolicy{
+		ServerID:    serverID,
+		Sensitivity: s.defaultSensitivity,
+		Action:      s.defaultAction,
+		Default:     true,
+	}
+}
+
+func (s *DLPService) invalidate(serverID string) {
+	s.mu.Lock()
+	delete(s.cache, serverID)
+	s.mu.Unlock()
+}
+
+func (s *DLPService) checkServer(orgID, serverID string) error {
+	if _, err := uuid.Parse(serverID); err != nil {
+		return types.NewNotFoundError("Server not found")
+	}
+	exists, err := s.store.ServerExists(orgID, serverID)
+	if err != nil {
+		return types.NewInternalError("Failed to get server: " + err.Error())
+	}
+	if !exists {
+		return types.NewNotFoundError("Server not found")
+	}
+	return nil
+}
+
+// describeDLPFindings lists the classifiers and argument paths of findings
+// once each
+func describeDLPFindings(findings []types.DLPFinding) (string, string) {
+	var classifiers, paths []string
+	seenClassifiers, seenPaths := map[string]bool{}, map[string]bool{}
+	for _, finding := range findings {
+		if !seenClassifiers[finding.Classifier] {
+			seenClassifiers[finding.Classifier] = true
+			classifiers = append(classifiers, finding.Classifier)
+		}
+		if !seenPaths[finding.Path] {
+			seenPaths[finding.Path] = true
+			paths = append(paths, finding.Path)
+		}
+	}
+	return strings.Join(classifiers, ", "), strings.Join(paths, ", ")
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// geoPolicyCacheTTL bounds how long a policy change made on another
+	// instance takes to apply
+	geoPolicyCacheTTL = 10 * time.Second
+	// geoDecisionLogInterval spaces out recorded decisions for the same
+	// client of an endpoint, so a client retrying in a loop cannot flood
+	// the decision log
+	geoDecisionLogInterval = time.Minute
+	// maxLoggedGeoClients bounds the clients remembered for spacing out
+	// recorded decisions
+	maxLoggedGeoClients = 10000
+	// defaultGeoDecisionLimit and maxGeoDecisionLimit bound decision listings
+	defaultGeoDecisionLimit = 100
+	maxGeoDecisionLimit     = 1000
+	// maxASN is the largest 32-bit autonomous system number
+	maxASN = 1<<32 - 1
+)
+
+// GeoResolver locates IP addresses by country and autonomous system
+type GeoResolver interface {
+	Lookup(ip string) (*types.GeoIPInfo, error)
+}
+
+// EndpointGeoPolicyStore persists the geo policies of endpoints and their
+// decisions
+type EndpointGeoPolicyStore interface {
+	EndpointExists(orgID, endpointID string) (bool, error)
+	GetPolicy(endpointID string) (*types.EndpointGeoPolicy, error)
+	UpsertPolicy(policy *types.EndpointGeoPolicy) error
+	DeletePolicy(endpointID string) (bool, error)
+	CreateDecision(event *types.GeoDecisionEvent) error
+	ListDecisions(endpointID string, deniedOnly bool, limit int) ([]*types.GeoDecisionEvent, error)
+}
+
+// EndpointGeoPolicyService allows or denies calls to endpoints by the
+// country and autonomous system of the client's IP address
+type EndpointGeoPolicyService struct {
+	store    EndpointGeoPolicyStore
+	resolver GeoResolver
+	now      func() time.Time
+	cache    map[string]cachedGeoPolicy
+	logged   map[string]time.Time
+	mu       sync.Mutex
+}
+
+type cachedGeoPolicy struct {
+	loadedAt time.Time
+	policy   *types.EndpointGeoPolicy
+}
+
+// NewEndpointGeoPolicyService creates a database-backed geo policy service.
+// resolver is nil when no GeoIP database is configured.
+func NewEndpointGeoPolicyService(db *sql.DB, resolver GeoResolver) *EndpointGeoPolicyService {
+	return NewEndpointGeoPolicyServiceWithStore(models.NewEndpointGeoPolicyModel(db), resolver)
+}
+
+// NewEndpointGeoPolicyServiceWithStore creates a geo policy service over
+// store
+func NewEndpointGeoPolicyServiceWithStore(store EndpointGeoPolicyStore, resolver GeoResolver) *EndpointGeoPolicyService {
+	return &EndpointGeoPolicyService{
+		store:    store,
+		resolver: resolver,
+		now:      time.Now,
+		cache:    make(map[string]cachedGeoPolicy),
+		logged:   make(map[string]time.Time),
+	}
+}
+
+// SetClock replaces the clock used to expire cached policies and space out
+// recorded decisions
+func (s *EndpointGeoPolicyService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// GetPolicy returns the geo policy of an endpoint of the organization
+func (s *EndpointGeoPolicyService) GetPolicy(ctx context.Context, orgID, endpointID string) (*types.EndpointGeoPolicy, error) {
+	if err := s.checkEndpoint(orgID, endpointID); err != nil {
+		return nil, err
+	}
+	policy, err := s.store.GetPolicy(endpointID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get geo policy: " + err.Error())
+	}
+	if policy == nil {
+		return nil, types.NewNotFoundError("Endpoint has no geo policy")
+	}
+	return policy, nil
+}
+
+// SetPolicy replaces the geo policy of an endpoint of the organization
+func (s *EndpointGeoPolicyService) SetPolicy(ctx context.Context, orgID, endpointID string, req *types.SetEndpointGeoPolicyRequest) (*types.EndpointGeoPolicy, error) {
+	if err := s.checkEndpoint(orgID, endpointID); err != nil {
+		return nil, err
+	}
+	if s.resolver == nil {
+		return nil, geoIPNotConfigured()
+	}
+	policy, err := newGeoPolicy(endpointID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.UpsertPolicy(policy); err != nil {
+		return nil, types.NewInternalError("Failed to save geo policy: " + err.Error())
+	}
+	s.invalidate(endpointID)
+	return s.GetPolicy(ctx, orgID, endpointID)
+}
+
+// DeletePolicy removes the geo policy of an endpoint of the organization
+func (s *EndpointGeoPolicyService) DeletePolicy(ctx context.Context, orgID, endpointID string) error {
+	if err := s.checkEndpoint(orgID, endpointID); err != nil {
+		return err
+	}
+	deleted, err := s.store.DeletePolicy(endpointID)
+	if err != nil {
+		return types.NewInternalError("Failed to delete geo policy: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Endpoint has no geo policy")
+	}
+	s.invalidate(endpointID)
+	return nil
+}
+
+// TestPolicy reports whether a client IP address may call an endpoint of
+// the organization, under its saved policy or the draft in req. Nothing
+// is recorded.
+func (s *EndpointGeoPolicyService) TestPolicy(ctx context.Context, orgID, endpointID string, req *types.TestGeoPolicyRequest) (*types.GeoDecision, error) {
+	if err := s.checkEndpoint(orgID, endpointID); err != nil {
+		return nil, err
+	}
+	if s.resolver == nil {
+		return nil, geoIPNotConfigured()
+	}
+	info, err := s.resolver.Lookup(req.IP)
+	if err != nil {
+		return nil, types.NewValidationError(err.Error())
+	}
+
+	var policy *types.EndpointGeoPolicy
+	if req.Policy != nil {
+		if policy, err = newGeoPolicy(endpointID, req.Policy); err != nil {
+			return nil, err
+		}
+	} else if policy, err = s.store.GetPolicy(endpointID); err != nil {
+		return nil, types.NewInternalError("Failed to get geo policy: " + err.Error())
+	}
+	if policy == nil {
+		return &types.GeoDecision{GeoIPInfo: *info, Allowed: true, Reason: "Endpoint has no geo policy"}, nil
+	}
+	return evaluateGeoPolicy(policy, info), nil
+}
+
+// ListDecisions returns the most recent recorded decisions for an endpoint
+// of the organization, only denials when deniedOnly
+func (s *EndpointGeoPolicyService) ListDecisions(ctx context.Context, orgID, endpointID string, deniedOnly bool, limit int) ([]*types.GeoDecisionEvent, error) {
+	if err := s.checkEndpoint(orgID, endpointID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultGeoDecisionLimit
+	}
+	events, err := s.store.ListDecisions(endpointID, deniedOnly, min(limit, maxGeoDecisionLimit))
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list geo decisions: " + err.Error())
+	}
+	return events, nil
+}
+
+// CheckEndpoint decides whether a client IP address may call an endpoint.
+// Denied calls are recorded, and allowed ones when the policy logs them.
+func (s *EndpointGeoPolicyService) CheckEndpoint(ctx context.Context, endpoint *types.Endpoint, ip string) error {
+	policy, err := s.policy(endpoint.ID)
+	if err != nil {
+		return types.NewServiceUnavailableError(fmt.Sprintf("Geo policy could not be loaded: %v", err))
+	}
+	if policy == nil {
+		return nil
+	}
+
+	info := &types.GeoIPInfo{IP: ip}
+	if s.resolver != nil {
+		located, err := s.resolver.Lookup(ip)
+		if err != nil {
+			log.Printf("GeoIP lookup for endpoint %s failed: %v", endpoint.Name, err)
+		} else {
+			info = located
+		}
+	}
+
+	decision := evaluateGeoPolicy(policy, info)
+	if !decision.Allowed || policy.LogAllowed {
+		s.record(endpoint, decision)
+	}
+	if decision.Allowed {
+		return nil
+	}
+	return types.NewGeoBlockedError(
+		fmt.Sprintf("Endpoint %s does not accept calls from this location", endpoint.Name),
+		decision.Reason)
+}
+
+// evaluateGeoPolicy applies deny rules, then allow rules, to a located
+// client. Clients located in neither country nor autonomous system get the
+// policy's unknown action.
+func evaluateGeoPolicy(policy *types.EndpointGeoPolicy, info *types.GeoIPInfo) *types.GeoDecision {
+	decision := &types.GeoDecision{GeoIPInfo: *info}
+	switch {
+	case info.Country == "" && info.ASN == 0:
+		decision.Allowed = policy.UnknownAction != types.GeoActionDeny
+		decision.Rule = "unknown_action"
+		decision.Reason = "Client location is unknown"
+	case info.Country != "" && slices.Contains(policy.DenyCountries, info.Country):
+		decision.Rule = "deny_countries"
+		decision.Reason = "Country " + info.Country + " is denied"
+	case info.ASN != 0 && slices.Contains(policy.DenyASNs, info.ASN):
+		decision.Rule = "deny_asns"
+		decision.Reason = fmt.Sprintf("AS%d is denied", info.ASN)
+	case info.Country != "" && slices.Contains(policy.AllowCountries, info.Country):
+		decision.Allowed = true
+		decision.Rule = "allow_countries"
+		decision.Reason = "Country " + info.Country + " is allowed"
+	case info.ASN != 0 && slices.Contains(policy.AllowASNs, info.ASN):
+		decision.Allowed = true
+		decision.Rule = "allow_asns"
+		decision.Reason = fmt.Sprintf("AS%d is allowed", info.ASN)
+	case len(policy.AllowCountries) > 0 || len(policy.AllowASNs) > 0:
+		decision.Reason = "Client matches no allow rule"
+	default:
+		decision.Allowed = true
+		decision.Reason = "Client matches no deny rule"
+	}
+	return decision
+}
+
+// newGeoPolicy validates and normalizes a policy request
+func newGeoPolicy(endpointID string, req *types.SetEndpointGeoPolicyRequest) (*types.EndpointGeoPolicy, error) {
+	policy := &types.EndpointGeoPolicy{
+		EndpointID:    endpointID,
+		UnknownAction: req.UnknownAction,
+		LogAllowed:    req.LogAllowed,
+	}
+	switch policy.UnknownAction {
+	case "":
+		policy.UnknownAction = types.GeoActionAllow
+	case types.GeoActionAllow, types.GeoActionDeny:
+	default:
+		return nil, types.NewValidationError("unknown_action must be allow or deny")
+	}
+
+	var err error
+	if policy.AllowCountries, err = normalizeCountries("allow_countries", req.AllowCountries); err != nil {
+		return nil, err
+	}
+	if policy.DenyCountries, err = normalizeCountries("deny_countries", req.DenyCountries); err != nil {
+		return nil, err
+	}
+	if policy.AllowASNs, err = normalizeASNs("allow_asns", req.AllowASNs); err != nil {
+		return nil, err
+	}
+	if policy.DenyASNs, err = normalizeASNs("deny_asns", req.DenyASNs); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// normalizeCountries upper-cases and deduplicates ISO 3166-1 alpha-2 codes
+func normalizeCountries(field string, countries []string) ([]string, error) {
+	normalized := []string{}
+	for _, country := range countries {
+		code := strings.ToUpper(strings.TrimSpace(country))
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return nil, types.NewValidationError(fmt.Sprintf("%s: %q is not a two-letter ISO country code", field, country))
+		}
+		if !slices.Contains(normalized, code) {
+			normalized = append(normalized, code)
+		}
+	}
+	return normalized, nil
+}
+
+// normalizeASNs deduplicates autonomous system numbers
+func normalizeASNs(field string, asns []int64) ([]int64, error) {
+	normalized := []int64{}
+	for _, asn := range asns {
+		if asn <= 0 || asn > maxASN {
+			return nil, types.NewValidationError(fmt.Sprintf("%s: %d is not an autonomous system number", field, asn))
+		}
+		if !slices.Contains(normalized, asn) {
+			normalized = append(normalized, asn)
+		}
+	}
+	return normalized, nil
+}
+
+// record logs a decision unless one was logged for the same client of the
+// endpoint within geoDecisionLogInterval
+func (s *EndpointGeoPolicyService) record(endpoint *types.Endpoint, decision *types.GeoDecision) {
+	key := fmt.Sprintf("%s|%s|%t", endpoint.ID, decision.IP, decision.Allowed)
+	now := s.now()
+	s.mu.Lock()
+	if last, ok := s.logged[key]; ok && now.Sub(last) < geoDecisionLogInterval {
+		s.mu.Unlock()
+		return
+	}
+	if len(s.logged) >= maxLoggedGeoClients {
+		for client, last := range s.logged {
+			if now.Sub(last) >= geoDecisionLogInterval {
+				delete(s.logged, client)
+			}
+		}
+	}
+	s.logged[key] = now
+	s.mu.Unlock()
+
+	event := &types.GeoDecisionEvent{
+		OrganizationID: endpoint.OrganizationID,
+		EndpointID:     endpoint.ID,
+		GeoDecision:    *decision,
+	}
+	if err := s.store.CreateDecision(event); err != nil {
+		log.Printf("Failed to record geo decision for endpoint %s: %v", endpoint.Name, err)
+	}
+}
+
+// policy returns the cached geo policy of an endpoint, or nil when it has
+// none
+func (s *EndpointGeoPolicyService) policy(endpointID string) (*types.EndpointGeoPolicy, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[endpointID]
+	s.mu.Unlock()
+	if ok && s.now().Sub(cached.loadedAt) < geoPolicyCacheTTL {
+		return cached.policy, nil
+	}
+
+	policy, err := s.store.GetPolicy(endpointID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.cache[endpointID] = cachedGeoPolicy{loadedAt: s.now(), policy: policy}
+	s.mu.Unlock()
+	return policy, nil
+}
+
+func (s *EndpointGeoPolicyService) invalidate(endpointID string) {
+	s.mu.Lock()
+	delete(s.cache, endpointID)
+	s.mu.Unlock()
+}
+
+func (s *EndpointGeoPolicyService) checkEndpoint(orgID, endpointID string) error {
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return types.NewNotFoundError("Endpoint not found")
+	}
+	exists, err := s.store.EndpointExists(orgID, endpointID)
+	if err != nil {
+		return types.NewInternalError("Failed to get endpoint: " + err.Error())
+	}
+	if !exists {
+		return types.NewNotFoundError("Endpoint not found")
+	}
+	return nil
+}
+
+func geoIPNotConfigured() error {
+	return types.NewServiceUnavailableError("No GeoIP database is configured; set gateway.geoip.country_database or asn_database")
+}
@@ -43,6 +43,10 @@ func (s *EndpointService) CreateEndpoint(ctx context.Context, req types.CreateEn
 		return nil, err
 	}
 
+	if err := req.Labels.Validate(); err != nil {
+		return nil, types.NewValidationError(err.Error())
+	}
+
 	// Verify namespace exists and user has access
 	namespace, err := s.namespaceRepo.GetByID(ctx, req.NamespaceID)
 	if err != nil {
@@ -85,6 +89,8 @@ func (s *EndpointService) CreateEndpoint(ctx context.Context, req types.CreateEn
 		CreatedBy:          userID,
 		IsActive:           true,
 		Metadata:           req.Metadata,
+		Labels:             req.Labels,
+		EnableStatusPage:   req.EnableStatusPage,
 	}
 
 	if err := s.repo.Create(ctx, endpoint); err != nil {
@@ -92,7 +98,7 @@ func (s *EndpointService) CreateEndpoint(ctx context.Context, req types.CreateEn
 	}
 
 	// Generate URLs
-	endpoint.URLs = s.generateURLs(endpoint.Name)
+	endpoint.URLs = s.GenerateURLs(endpoint.Name)
 
 	// Fetch namespace details
 	if endpoint.NamespaceID != "" {
@@ -116,7 +122,7 @@ func (s *EndpointService) GetEndpoint(ctx context.Context, id string) (*types.En
 	}
 
 	// Generate URLs
-	endpoint.URLs = s.generateURLs(endpoint.Name)
+	endpoint.URLs = s.GenerateURLs(endpoint.Name)
 
 	// Fetch namespace details
 	if endpoint.NamespaceID != "" {
@@ -137,7 +143,7 @@ func (s *EndpointService) GetEndpointByName(ctx context.Context, name string) (*
 	}
 
 	// Generate URLs
-	endpoint.URLs = s.generateURLs(endpoint.Name)
+	endpoint.URLs = s.GenerateURLs(endpoint.Name)
 
 	// Fetch namespace details
 	if endpoint.NamespaceID != "" {
@@ -159,7 +165,7 @@ func (s *EndpointService) ListEndpoints(ctx context.Context, orgID string) ([]*t
 
 	// Generate URLs and fetch namespace for each endpoint
 	for _, endpoint := range endpoints {
-		endpoint.URLs = s.generateURLs(endpoint.Name)
+		endpoint.URLs = s.GenerateURLs(endpoint.Name)
 
 		// Fetch namespace details for each endpoint
 		if endpoint.NamespaceID != "" {
@@ -186,7 +192,7 @@ func (s *EndpointService) GetEndpointByNamespace(ctx context.Context, namespaceI
 	}
 
 	// Generate URLs
-	endpoint.URLs = s.generateURLs(endpoint.Name)
+	endpoint.URLs = s.GenerateURLs(endpoint.Name)
 
 	// Fetch namespace details
 	if endpoint.NamespaceID != "" {
@@ -208,7 +214,7 @@ func (s *EndpointService) ListPublicEndpoints(ctx context.Context) ([]*types.End
 
 	// Generate URLs and fetch namespace for each endpoint
 	for _, endpoint := range endpoints {
-		endpoint.URLs = s.generateURLs(endpoint.Name)
+		endpoint.URLs = s.GenerateURLs(endpoint.Name)
 
 		// Fetch namespace details for each endpoint
 		if endpoint.NamespaceID != "" {
@@ -263,13 +269,22 @@ func (s *EndpointService) UpdateEndpoint(ctx context.Context, id string, req typ
 	if req.Metadata != nil {
 		endpoint.Metadata = req.Metadata
 	}
+	if req.Labels != nil {
+		if err := req.Labels.Validate(); err != nil {
+			return nil, types.NewValidationError(err.Error())
+		}
+		endpoint.Labels = req.Labels
+	}
+	if req.EnableStatusPage != nil {
+		endpoint.EnableStatusPage = *req.EnableStatusPage
+	}
 
 	if err := s.repo.Update(ctx, endpoint); err != nil {
 		return nil, err
 	}
 
 	// Generate URLs
-	endpoint.URLs = s.generateURLs(endpoint.Name)
+	endpoint.URLs = s.GenerateURLs(endpoint.Name)
 
 	// Fetch namespace details
 	if endpoint.NamespaceID != "" {
@@ -354,7 +369,8 @@ func (s *EndpointService) validateEndpointName(name string) error {
 	return nil
 }
 
-func (s *EndpointService) generateURLs(endpointName string) *types.EndpointURLs {
+// GenerateURLs returns the public transport URLs for an endpoint name
+func (s *EndpointService) GenerateURLs(endpointName string) *types.EndpointURLs {
 	baseURL := s.baseURL
 	if baseURL == "" {
 		baseURL = "http://localhost:8080"
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Failover steps, in the order they run
+const (
+	FailoverStepFencePrevious    = "fence_previous"
+	FailoverStepPromote          = "promote"
+	FailoverStepResumeDeliveries = "resume_deliveries"
+	FailoverStepInvalidateCaches = "invalidate_caches"
+)
+
+// recentFailovers is how many failovers the status reports
+const recentFailovers = 20
+
+// DeploymentStore persists the deployment's role and its failovers
+type DeploymentStore interface {
+	GetState() (*types.DeploymentState, error)
+	SetState(state *types.DeploymentState) (*types.DeploymentState, error)
+	CreateFailover(failover *types.Failover) error
+	UpdateFailover(failover *types.Failover) error
+	ListFailovers(limit int) ([]*types.Failover, error)
+	PlatformOrganization() (string, error)
+}
+
+// PeerFencer asks a peer region to stand down
+type PeerFencer interface {
+	Fence(ctx context.Context, peer RegionPeer, req *types.FenceRequest) error
+}
+
+// FailoverAuditor records each failover step
+type FailoverAuditor interface {
+	LogAudit(audit *types.AuditLog) error
+}
+
+// FailoverService promotes this region's deployment when the region it
+// backs up is lost. A failover fences the previous region, making it a
+// read-only standby, then makes this region active: quota checks,
+// telemetry and webhook deliveries move here, and every instance drops its
+// caches. Each step is recorded with the failover and in the audit log.
+type FailoverService struct {
+	store    DeploymentStore
+	fencer   PeerFencer
+	auditor  FailoverAuditor
+	now      func() time.Time
+	last     *types.DeploymentState
+	role     string
+	settings RegionSettings
+	mu       sync.Mutex
+}
+
+// NewFailoverService creates a failover service backed by the database.
+// role is the configured role, which applies until the first failover.
+func NewFailoverService(db *sql.DB, settings RegionSettings, role string) *FailoverService {
+	return NewFailoverServiceWithStore(models.NewDeploymentModel(db), NewHTTPCatalogPeerClient(settings.Token), settings, role)
+}
+
+// NewFailoverServiceWithStore creates a failover service over a store and
+// peer client
+func NewFailoverServiceWithStore(store DeploymentStore, fencer PeerFencer, settings RegionSettings, role string) *FailoverService {
+	if role == "" {
+		role = types.DeploymentRoleActive
+	}
+	return &FailoverService{
+		store:    store,
+		fencer:   fencer,
+		now:      time.Now,
+		role:     role,
+		settings: settings,
+	}
+}
+
+// SetAuditor configures where failovers are audited
+func (s *FailoverService) SetAuditor(auditor FailoverAuditor) {
+	s.auditor = auditor
+}
+
+// SetClock replaces the service's clock, for tests
+func (s *FailoverService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Authorize reports whether token is the replication token, which peers
+// present to fence this region
+func (s *FailoverService) Authorize(token string) bool {
+	return replicationTokenValid(s.settings.Token, token)
+}
+
+// State returns the deployment's role, the configured one until the first
+// failover or fence
+func (s *FailoverService) State(ctx context.Context) (*types.DeploymentState, error) {
+	state, err := s.store.GetState()
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get deployment state: " + err.Error())
+	}
+	if state == nil {
+		state = &types.DeploymentState{Role: s.role}
+	}
+	state.Region = s.settings.Region
+
+	s.mu.Lock()
+	s.last = state
+	s.mu.Unlock()
+	return state, nil
+}
+
+// IsActive reports whether this deployment runs quota checks, telemetry and
+// webhook deliveries. When the state cannot be read the last known role
+// applies.
+func (s *FailoverService) IsActive(ctx context.Context) bool {
+	state, err := s.State(ctx)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		s.mu.Lock()
+		state = s.last
+		s.mu.Unlock()
+		if state == nil {
+			return s.role == types.DeploymentRoleActive
+		}
+	}
+	return state.Role == types.DeploymentRoleActive
+}
+
+// Status reports the deployment's role and its recent failovers
+func (s *FailoverService) Status(ctx context.Context) (*types.FailoverStatus, error) {
+	state, err := s.State(ctx)
+	if err != nil {
+		return nil, err
+	}
+	failovers, err := s.store.ListFailovers(recentFailovers)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list failovers: " + err.Error())
+	}
+	return &types.FailoverStatus{State: state, Failovers: failovers}, nil
+}
+
+// Failover makes this region take over from req.FromRegion. The previous
+// region is fenced first so both are not active at once; when it cannot be
+// reached the failover still goes ahead, is reported as partial, and the
+// previous region must be fenced once it is back. A dry run records the
+// steps it would take without running them.
+func (s *FailoverService) Failover(ctx context.Context, req *types.FailoverRequest, initiatedBy string) (*types.Failover, error) {
+	if s.settings.Region == "" {
+		return nil, types.NewValidationError("region.name must be configured to fail over")
+	}
+	if req.FromRegion == s.settings.Region {
+		return nil, types.NewValidationError("cannot fail over from this region to itself")
+	}
+	peer, ok := s.peer(req.FromRegion)
+	if !ok {
+		return nil, types.NewValidationError(fmt.Sprintf("unknown region %q; it must be listed in region.peers", req.FromRegion))
+	}
+
+	failover := &types.Failover{
+		FromRegion:  req.FromRegion,
+		ToRegion:    s.settings.Region,
+		Reason:      req.Reason,
+		InitiatedBy: initiatedBy,
+		DryRun:      req.DryRun,
+		Status:      types.FailoverStatusRunning,
+		Steps:       []types.FailoverStep{},
+		StartedAt:   s.now(),
+	}
+	if err := s.store.CreateFailover(failover); err != nil {
+		return nil, types.NewInternalError("Failed to record failover: " + err.Error())
+	}
+	auditOrg := s.auditOrganization()
+	s.audit(auditOrg, initiatedBy, "start", failover.ID, true, map[string]interface{}{
+		"from_region": failover.FromRegion,
+		"to_region":   failover.ToRegion,
+		"reason":      failover.Reason,
+		"dry_run":     failover.DryRun,
+	})
+
+	record := func(name, status, detail string) {
+		step := types.FailoverStep{Name: name, Status: status, Detail: detail}
+		failover.Steps = append(failover.Steps, step)
+		s.audit(auditOrg, initiatedBy, name, failover.ID, status != types.FailoverStepFailed, map[string]interface{}{
+			"from_region": failover.FromRegion,
+			"to_region":   failover.ToRegion,
+			"dry_run":     failover.DryRun,
+			"status":      step.Status,
+			"detail":      step.Detail,
+		})
+	}
+
+	if req.DryRun {
+		record(FailoverStepFencePrevious, types.FailoverStepPlanned,
+			fmt.Sprintf("Ask %s at %s to become a read-only standby", peer.Name, peer.URL))
+		record(FailoverStepPromote, types.FailoverStepPlanned,
+			fmt.Sprintf("Make %s the active region", s.settings.Region))
+		record(FailoverStepResumeDeliveries, types.FailoverStepPlanned, deliveriesDetail(s.settings.Region))
+		record(FailoverStepInvalidateCaches, types.FailoverStepPlanned,
+			"Gateway instances drop cached listings and their virtual server and agent caches")
+		return s.finish(failover, types.FailoverStatusCompleted)
+	}
+
+	fenceErr := s.fencer.Fence(ctx, peer, &types.FenceRequest{Region: s.settings.Region, Reason: req.Reason})
+	if fenceErr != nil {
+		record(FailoverStepFencePrevious, types.FailoverStepFailed,
+			fmt.Sprintf("Could not reach %s: %v; fence it once it is back so it does not stay active", peer.Name, fenceErr))
+	} else {
+		record(FailoverStepFencePrevious, types.FailoverStepDone, peer.Name+" is now a read-only standby")
+	}
+
+	changedAt := s.now()
+	state, err := s.store.SetState(&types.DeploymentState{
+		Role:      types.DeploymentRoleActive,
+		ChangedBy: initiatedBy,
+		ChangedAt: &changedAt,
+	})
+	if err != nil {
+		record(FailoverStepPromote, types.FailoverStepFailed, "Failed to update deployment state: "+err.Error())
+		record(FailoverStepResumeDeliveries, types.FailoverStepSkipped, "The region was not promoted")
+		record(FailoverStepInvalidateCaches, types.FailoverStepSkipped, "The region was not promoted")
+		return s.finish(failover, types.FailoverStatusFailed)
+	}
+	record(FailoverStepPromote, types.FailoverStepDone,
+		fmt.Sprintf("%s is the active region at epoch %d", s.settings.Region, state.Epoch))
+	record(FailoverStepResumeDeliveries, types.FailoverStepDone, deliveriesDetail(s.settings.Region))
+	record(FailoverStepInvalidateCaches, types.FailoverStepDone,
+		fmt.Sprintf("Gateway instances drop cached listings and their virtual server and agent caches on seeing epoch %d", state.Epoch))
+
+	if fenceErr != nil {
+		return s.finish(failover, types.FailoverStatusPartial)
+	}
+	return s.finish(failover, types.FailoverStatusCompleted)
+}
+
+// Fence makes this deployment a read-only standby because region took over
+// from it. Peers call it at the start of a failover; when this region was
+// unreachable then, an operator runs it once the region is back.
+func (s *FailoverService) Fence(ctx context.Context, req *types.FenceRequest, changedBy string) (*types.DeploymentState, error) {
+	if req.Region == "" || req.Region == s.settings.Region {
+		return nil, types.NewValidationError("fencing region must be another region")
+	}
+
+	reason := "Failed over to " + req.Region
+	if req.Reason != "" {
+		reason += ": " + req.Reason
+	}
+	changedAt := s.now()
+	state, err := s.store.SetState(&types.DeploymentState{
+		Role:           types.DeploymentRoleStandby,
+		ReadOnly:       true,
+		ReadOnlyReason: reason,
+		FencedBy:       req.Region,
+		ChangedBy:      changedBy,
+		ChangedAt:      &changedAt,
+	})
+	if err != nil {
+		s.audit(s.auditOrganization(), changedBy, "fence", "", false, map[string]interface{}{
+			"fenced_by": req.Region,
+			"error":     err.Error(),
+		})
+		return nil, types.NewInternalError("Failed to fence deployment: " + err.Error())
+	}
+	state.Region = s.settings.Region
+	s.audit(s.auditOrganization(), changedBy, "fence", "", true, map[string]interface{}{
+		"fenced_by": req.Region,
+		"reason":    req.Reason,
+		"epoch":     state.Epoch,
+	})
+	return state, nil
+}
+
+// Watch polls the deployment state every interval until ctx is done and
+// calls onChange with the state whenever its epoch changes, including
+// once at start if a failover or fence was ever recorded
+func (s *FailoverService) Watch(ctx context.Context, interval time.Duration, onChange func(*types.DeploymentState)) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var epoch int64
+	for {
+		if state, err := s.State(ctx); err != nil {
+			log.Printf("Warning: %v", err)
+		} else if state.Epoch != epoch {
+			epoch = state.Epoch
+			onChange(state)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *FailoverService) finish(failover *types.Failover, status string) (*types.Failover, error) {
+	completedAt := s.now()
+	failover.Status = status
+	failover.CompletedAt = &completedAt
+	if err := s.store.UpdateFailover(failover); err != nil {
+		log.Printf("Failed to record outcome of failover %s: %v", failover.ID, err)
+	}
+	s.audit(s.auditOrganization(), failover.InitiatedBy, "complete", failover.ID, status != types.FailoverStatusFailed, map[string]interface{}{
+		"from_region": failover.FromRegion,
+		"to_region":   failover.ToRegion,
+		"dry_run":     failover.DryRun,
+		"status":      status,
+	})
+	return failover, nil
+}
+
+func (s *FailoverService) peer(name string) (RegionPeer, bool) {
+	for _, peer := range s.settings.Peers {
+		if peer.Name == name {
+			return peer, true
+		}
+	}
+	return RegionPeer{}, false
+}
+
+// auditOrganization is the organization whose audit log records
+// deployment-wide events
+func (s *FailoverService) auditOrganization() string {
+	if s.auditor == nil {
+		return ""
+	}
+	orgID, err := s.store.PlatformOrganization()
+	if err != nil {
+		log.Printf("Failed to find organization for failover audit: %v", err)
+	}
+	return orgID
+}
+
+func (s *FailoverService) audit(orgID, userID, action, resourceID string, success bool, details map[string]interface{}) {
+	if s.auditor == nil || orgID == "" {
+		return
+	}
+	if err := s.auditor.LogAudit(&types.AuditLog{
+		OrganizationID: orgID,
+		UserID:         userID,
+		Action:         action,
+		Resource:       "failover",
+		ResourceID:     resourceID,
+		Details:        details,
+		Success:        success,
+	}); err != nil {
+		log.Printf("Failed to audit failover %s: %v", action, err)
+	}
+}
+
+func deliveriesDetail(region string) string {
+	return "Quota checks, telemetry, audit sink exports and server owner webhooks run from " + region
+}
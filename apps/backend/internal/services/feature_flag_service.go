@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// featureFlagCacheTTL bounds how long a flag change on one instance takes to
+// reach the others
+const featureFlagCacheTTL = 30 * time.Second
+
+// FeatureFlagStore persists flag values
+type FeatureFlagStore interface {
+	List() ([]*types.FeatureFlagOverride, error)
+	Upsert(override *types.FeatureFlagOverride) error
+	Delete(key, orgID string) (bool, error)
+}
+
+// EntitlementChecker reports whether the license grants a feature
+type EntitlementChecker interface {
+	IsEntitled(feature string) bool
+}
+
+// FeatureFlagService resolves feature flags per organization. Precedence from
+// highest to lowest: the license for enterprise flags, FEATURE_FLAG_<KEY>
+// environment variables, the organization's stored value, the stored global
+// value, the flag default.
+type FeatureFlagService struct {
+	loadedAt     time.Time
+	store        FeatureFlagStore
+	entitlements EntitlementChecker
+	env          map[string]bool
+	overrides    map[string]*types.FeatureFlagOverride
+	mu           sync.RWMutex
+}
+
+// NewFeatureFlagService creates a database-backed feature flag service
+func NewFeatureFlagService(db *sql.DB) *FeatureFlagService {
+	return NewFeatureFlagServiceWithStore(models.NewFeatureFlagModel(db), os.Getenv)
+}
+
+// NewFeatureFlagServiceWithStore creates a feature flag service over store,
+// reading environment overrides through getenv
+func NewFeatureFlagServiceWithStore(store FeatureFlagStore, getenv func(string) string) *FeatureFlagService {
+	env := make(map[string]bool)
+	for _, def := range types.FeatureFlagDefinitions {
+		raw := getenv(FeatureFlagEnvVar(def.Key))
+		if raw == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			log.Printf("Warning: ignoring %s=%q, expected true or false", FeatureFlagEnvVar(def.Key), raw)
+			continue
+		}
+		env[def.Key] = enabled
+	}
+
+	return &FeatureFlagService{
+		store: store,
+		env:   env,
+	}
+}
+
+// SetEntitlements gates enterprise flags on the license. Without a checker
+// every flag can be enabled.
+func (s *FeatureFlagService) SetEntitlements(checker EntitlementChecker) {
+	s.entitlements = checker
+}
+
+// FeatureFlagEnvVar returns the environment variable that overrides key
+func FeatureFlagEnvVar(key string) string {
+	return "FEATURE_FLAG_" + strings.ToUpper(key)
+}
+
+// IsEnabled reports whether a flag is on for an organization. Unknown flags
+// are always off.
+func (s *FeatureFlagService) IsEnabled(ctx context.Context, orgID, key string) bool {
+	def, ok := types.LookupFeatureFlag(key)
+	if !ok {
+		return false
+	}
+	return s.resolve(def, orgID).Enabled
+}
+
+// ListFlags returns every known flag resolved for an organization
+func (s *FeatureFlagService) ListFlags(ctx context.Context, orgID string) []*types.FeatureFlagState {
+	states := make([]*types.FeatureFlagState, 0, len(types.FeatureFlagDefinitions))
+	for _, def := range types.FeatureFlagDefinitions {
+		states = append(states, s.resolve(def, orgID))
+	}
+	return states
+}
+
+// GetFlag returns one flag resolved for an organization
+func (s *FeatureFlagService) GetFlag(ctx context.Context, orgID, key string) (*types.FeatureFlagState, error) {
+	def, ok := types.LookupFeatureFlag(key)
+	if !ok {
+		return nil, types.NewNotFoundError("Feature flag not found: " + key)
+	}
+	return s.resolve(def, orgID), nil
+}
+
+// SetFlag stores a flag value for an organization, or deployment-wide when
+// orgID is empty
+func (s *FeatureFlagService) SetFlag(ctx context.Context, key, orgID string, enabled bool, updatedBy string) error {
+	def, ok := types.LookupFeatureFlag(key)
+	if !ok {
+		return types.NewNotFoundError("Feature flag not found: " + key)
+	}
+	if enabled && def.Enterprise && s.entitlements != nil && !s.entitlements.IsEntitled(key) {
+		return types.NewForbiddenError("Feature flag requires an enterprise license: " + key)
+	}
+
+	err := s.store.Upsert(&types.FeatureFlagOverride{
+		Key:            key,
+		OrganizationID: orgID,
+		Enabled:        enabled,
+		UpdatedBy:      updatedBy,
+	})
+	s.invalidate()
+	return err
+}
+
+// ClearFlag removes a stored value so the next scope down applies again
+func (s *FeatureFlagService) ClearFlag(ctx context.Context, key, orgID string) error {
+	if _, ok := types.LookupFeatureFlag(key); !ok {
+		return types.NewNotFoundError("Feature flag not found: " + key)
+	}
+
+	deleted, err := s.store.Delete(key, orgID)
+	s.invalidate()
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return types.NewNotFoundError("No stored value for feature flag: " + key)
+	}
+	return nil
+}
+
+func (s *FeatureFlagService) resolve(def types.FeatureFlagDefinition, orgID string) *types.FeatureFlagState {
+	state := &types.FeatureFlagState{
+		Key:         def.Key,
+		Description: def.Description,
+		Default:     def.Default,
+		Enabled:     def.Default,
+		Source:      types.FeatureFlagSourceDefault,
+		Enterprise:  def.Enterprise,
+	}
+
+	if def.Enterprise && s.entitlements != nil && !s.entitlements.IsEntitled(def.Key) {
+		state.Enabled = false
+		state.Source = types.FeatureFlagSourceLicense
+		return state
+	}
+
+	if enabled, ok := s.env[def.Key]; ok {
+		state.Enabled = enabled
+		state.Source = types.FeatureFlagSourceEnv
+		return state
+	}
+
+	overrides := s.loadOverrides()
+	override, source := overrides[overrideKey(def.Key, orgID)], types.FeatureFlagSourceOrganization
+	if override == nil || orgID == "" {
+		override, source = overrides[overrideKey(def.Key, "")], types.FeatureFlagSourceGlobal
+	}
+	if override != nil {
+		updatedAt := override.UpdatedAt
+		state.Enabled = override.Enabled
+		state.Source = source
+		state.UpdatedBy = override.UpdatedBy
+		state.UpdatedAt = &updatedAt
+	}
+
+	return state
+}
+
+// loadOverrides returns cached stored values, refreshing them after the TTL.
+// If the store is unreachable the last known values keep being served.
+func (s *FeatureFlagService) loadOverrides() map[string]*types.FeatureFlagOverride {
+	s.mu.RLock()
+	if s.overrides != nil && time.Since(s.loadedAt) < featureFlagCacheTTL {
+		overrides := s.overrides
+		s.mu.RUnlock()
+		return overrides
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.overrides != nil && time.Since(s.loadedAt) < featureFlagCacheTTL {
+		return s.overrides
+	}
+
+	rows, err := s.store.List()
+	if err != nil {
+		log.Printf("Failed to load feature flags: %v", err)
+		if s.overrides == nil {
+			return map[string]*types.FeatureFlagOverride{}
+		}
+		return s.overrides
+	}
+
+	overrides := make(map[string]*types.FeatureFlagOverride, len(rows))
+	for _, row := range rows {
+		overrides[overrideKey(row.Key, row.OrganizationID)] = row
+	}
+	s.overrides = overrides
+	s.loadedAt = time.Now()
+	return overrides
+}
+
+func (s *FeatureFlagService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+func overrideKey(key, orgID string) string {
+	return key + "|" + orgID
+}
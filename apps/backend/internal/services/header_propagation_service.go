@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/headerprop"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// headerPropagationCacheTTL bounds how long a rule change made on another
+// instance takes to apply
+const headerPropagationCacheTTL = 10 * time.Second
+
+// HeaderPropagationStore persists the header propagation rules of servers
+// and namespaces
+type HeaderPropagationStore interface {
+	ScopeExists(orgID, scopeType, scopeID string) (bool, error)
+	Get(scopeType, scopeID string) (*types.HeaderPropagationSettings, error)
+	Upsert(orgID, scopeType, scopeID string, rules *types.HeaderPropagationRules) error
+	Delete(scopeType, scopeID string) (bool, error)
+}
+
+// HeaderPropagationService manages which headers of a caller's request
+// reach upstream servers and resolves them for each call
+type HeaderPropagationService struct {
+	store HeaderPropagationStore
+	now   func() time.Time
+	cache map[string]cachedHeaderRules
+	mu    sync.Mutex
+}
+
+type cachedHeaderRules struct {
+	loadedAt time.Time
+	rules    *types.HeaderPropagationRules
+}
+
+// NewHeaderPropagationService creates a database-backed header propagation
+// service
+func NewHeaderPropagationService(db *sql.DB) *HeaderPropagationService {
+	return NewHeaderPropagationServiceWithStore(models.NewHeaderPropagationModel(db))
+}
+
+// NewHeaderPropagationServiceWithStore creates a header propagation service
+// over store
+func NewHeaderPropagationServiceWithStore(store HeaderPropagationStore) *HeaderPropagationService {
+	return &HeaderPropagationService{
+		store: store,
+		now:   time.Now,
+		cache: make(map[string]cachedHeaderRules),
+	}
+}
+
+// SetClock replaces the clock used to expire cached rules
+func (s *HeaderPropagationService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// GetRules returns the rules of a server or namespace of the organization;
+// scopes without rules propagate nothing
+func (s *HeaderPropagationService) GetRules(ctx context.Context, orgID, scopeType, scopeID string) (*types.HeaderPropagationSettings, error) {
+	if err := s.checkScope(orgID, scopeType, scopeID); err != nil {
+		return nil, err
+	}
+	settings, err := s.store.Get(scopeType, scopeID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get header propagation rules: " + err.Error())
+	}
+	if settings == nil {
+		settings = &types.HeaderPropagationSettings{ScopeType: scopeType, ScopeID: scopeID}
+	}
+	return settings, nil
+}
+
+// SetRules replaces the rules of a server or namespace of the organization
+func (s *HeaderPropagationService) SetRules(ctx context.Context, orgID, scopeType, scopeID string, rules *types.HeaderPropagationRules) (*types.HeaderPropagationSettings, error) {
+	if err := s.checkScope(orgID, scopeType, scopeID); err != nil {
+		return nil, err
+	}
+	if err := headerprop.Validate(rules); err != nil {
+		return nil, types.NewValidationError(err.Error())
+	}
+	if err := s.store.Upsert(orgID, scopeType, scopeID, rules); err != nil {
+		return nil, types.NewInternalError("Failed to save header propagation rules: " + err.Error())
+	}
+	s.invalidate(scopeType, scopeID)
+	return s.GetRules(ctx, orgID, scopeType, scopeID)
+}
+
+// DeleteRules removes the rules of a server or namespace, which then
+// receives no caller headers
+func (s *HeaderPropagationService) DeleteRules(ctx context.Context, orgID, scopeType, scopeID string) error {
+	if err := s.checkScope(orgID, scopeType, scopeID); err != nil {
+		return err
+	}
+	deleted, err := s.store.Delete(scopeType, scopeID)
+	if err != nil {
+		return types.NewInternalError("Failed to delete header propagation rules: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("No header propagation rules set")
+	}
+	s.invalidate(scopeType, scopeID)
+	return nil
+}
+
+// UpstreamHeaders resolves the headers a call from ctx sends to a server of
+// a namespace, merging the namespace's rules with the server's
+func (s *HeaderPropagationService) UpstreamHeaders(ctx context.Context, namespaceID, serverID, serverName string) (http.Header, error) {
+	var namespaceRules *types.HeaderPropagationRules
+	if namespaceID != "" {
+		rules, err := s.rules(types.HeaderPropagationScopeNamespace, namespaceID)
+		if err != nil {
+			return nil, err
+		}
+		namespaceRules = rules
+	}
+	serverRules, err := s.rules(types.HeaderPropagationScopeServer, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if namespaceRules == nil && serverRules == nil {
+		return nil, nil
+	}
+
+	return headerprop.Resolve(headerprop.Merge(namespaceRules, serverRules), &headerprop.Request{
+		Header:      types.InboundHeadersFromContext(ctx),
+		Principal:   types.PrincipalFromContext(ctx),
+		NamespaceID: namespaceID,
+		ServerID:    serverID,
+		ServerName:  serverName,
+	}), nil
+}
+
+// rules returns the cached rules of a scope, or nil when it has none
+func (s *HeaderPropagationService) rules(scopeType, scopeID string) (*types.HeaderPropagationRules, error) {
+	key := scopeType + ":" + scopeID
+	now := s.now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Sub(cached.loadedAt) < headerPropagationCacheTTL {
+		return cached.rules, nil
+	}
+
+	settings, err := s.store.Get(scopeType, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	var rules *types.HeaderPropagationRules
+	if settings != nil {
+		rules = &settings.Rules
+	}
+
+	s.mu.Lock()
+	s.cache[key] = cachedHeaderRules{loadedAt: now, rules: rules}
+	s.mu.Unlock()
+	return rules, nil
+}
+
+func (s *HeaderPropagationService) invalidate(scopeType, scopeID string) {
+	s.mu.Lock()
+	delete(s.cache, scopeType+":"+scopeID)
+	s.mu.Unlock()
+}
+
+func (s *HeaderPropagationService) checkScope(orgID, scopeType, scopeID string) error {
+	exists, err := s.store.ScopeExists(orgID, scopeType, scopeID)
+	if err != nil {
+		return types.NewInternalError("Failed to get " + scopeType + ": " + err.Error())
+	}
+	if !exists {
+		if scopeType == types.HeaderPropagationScopeNamespace {
+			return types.NewNotFoundError("Namespace not found")
+		}
+		return types.NewNotFoundError("Server not found")
+	}
+	return nil
+}
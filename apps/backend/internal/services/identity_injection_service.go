@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// identityInjectionCacheTTL bounds how long a settings change made on
+// another instance takes to apply
+const identityInjectionCacheTTL = 10 * time.Second
+
+var identityArgumentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]{0,63}$`)
+
+// IdentityInjectionStore persists which caller identity servers receive
+type IdentityInjectionStore interface {
+	ServerExists(orgID, serverID string) (bool, error)
+	Get(serverID string) (*types.IdentityInjectionSettings, error)
+	Upsert(settings *types.IdentityInjectionSettings) error
+	Delete(serverID string) (bool, error)
+}
+
+// IdentityInjectionService asserts the caller's identity to servers that
+// implement per-user behavior, in the _meta of tool calls or in a tool
+// argument
+type IdentityInjectionService struct {
+	store IdentityInjectionStore
+	now   func() time.Time
+	cache map[string]cachedIdentityInjection
+	mu    sync.Mutex
+}
+
+type cachedIdentityInjection struct {
+	loadedAt time.Time
+	settings *types.IdentityInjectionSettings
+}
+
+// NewIdentityInjectionService creates a database-backed identity injection
+// service
+func NewIdentityInjectionService(db *sql.DB) *IdentityInjectionService {
+	return NewIdentityInjectionServiceWithStore(models.NewIdentityInjectionModel(db))
+}
+
+// NewIdentityInjectionServiceWithStore creates an identity injection
+// service over store
+func NewIdentityInjectionServiceWithStore(store IdentityInjectionStore) *IdentityInjectionService {
+	return &IdentityInjectionService{
+		store: store,
+		now:   time.Now,
+		cache: make(map[string]cachedIdentityInjection),
+	}
+}
+
+// SetClock replaces the clock used to expire cached settings
+func (s *IdentityInjectionService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// GetSettings returns the identity injection settings of a server of the
+// organization. Servers that receive no identity report target off.
+func (s *IdentityInjectionService) GetSettings(ctx context.Context, orgID, serverID string) (*types.IdentityInjectionSettings, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	settings, err := s.store.Get(serverID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get identity injection settings: " + err.Error())
+	}
+	if settings == nil {
+		settings = &types.IdentityInjectionSettings{ServerID: serverID, Target: types.IdentityTargetOff, Fields: []string{}}
+	}
+	return settings, nil
+}
+
+// SetSettings makes a server of the organization receive the caller's
+// identity on tool calls
+func (s *IdentityInjectionService) SetSettings(ctx context.Context, orgID, serverID string, req *types.SetIdentityInjectionRequest) (*types.IdentityInjectionSettings, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	settings, err := identitySettings(serverID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Upsert(settings); err != nil {
+		return nil, types.NewInternalError("Failed to save identity injection settings: " + err.Error())
+	}
+	s.invalidate(serverID)
+	return s.GetSettings(ctx, orgID, serverID)
+}
+
+// DeleteSettings stops injecting the caller's identity into calls to a
+// server
+func (s *IdentityInjectionService) DeleteSettings(ctx context.Context, orgID, serverID string) error {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return err
+	}
+	deleted, err := s.store.Delete(serverID)
+	if err != nil {
+		return types.NewInternalError("Failed to delete identity injection settings: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Identity injection is not enabled for this server")
+	}
+	s.invalidate(serverID)
+	return nil
+}
+
+// InjectIdentity returns the arguments and _meta entries of a tool call to
+// a server. For servers that receive the identity in an argument, whatever
+// the caller sent in that argument is replaced, or removed when the call
+// has no authenticated caller; args itself is never modified.
+func (s *IdentityInjectionService) InjectIdentity(ctx context.Context, namespaceID, serverID string, args map[string]interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	settings, err := s.settings(serverID)
+	if err != nil || settings == nil {
+		return args, nil, err
+	}
+
+	var assertion map[string]interface{}
+	if principal := types.PrincipalFromContext(ctx); principal != nil && principal.Type != types.PrincipalTypeAnonymous {
+		if assertion, err = BuildIdentityAssertion(principal, namespaceID, settings.Fields); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if settings.Target == types.IdentityTargetMeta {
+		if assertion == nil {
+			return args, nil, nil
+		}
+		return args, map[string]interface{}{types.IdentityMetaKey: assertion}, nil
+	}
+
+	injected := make(map[string]interface{}, len(args)+1)
+	for key, value := range args {
+		injected[key] = value
+	}
+	delete(injected, settings.Argument)
+	if assertion != nil {
+		injected[settings.Argument] = assertion
+	}
+	return injected, nil, nil
+}
+
+// BuildIdentityAssertion returns the fields of principal a server receives,
+// in the form of a decoded JSON object
+func BuildIdentityAssertion(principal *types.Principal, namespaceID string, fields []string) (map[string]interface{}, error) {
+	assertion := types.IdentityAssertion{
+		Version: types.IdentityAssertionVersion,
+		Issuer:  types.IdentityAssertionIssuer,
+	}
+	for _, field := range fields {
+		switch field {
+		case types.IdentityFieldUserID:
+			assertion.UserID = principal.EffectiveUserID()
+		case types.IdentityFieldEmail:
+			assertion.Email = principal.Email
+		case types.IdentityFieldOrganizationID:
+			assertion.OrganizationID = principal.OrganizationID
+		case types.IdentityFieldPrincipalType:
+			assertion.PrincipalType = principal.Type
+		case types.IdentityFieldAPIKeyID:
+			assertion.APIKeyID = principal.APIKeyID
+		case types.IdentityFieldClientID:
+			assertion.ClientID = principal.ClientID
+		case types.IdentityFieldOnBehalfOf:
+			assertion.OnBehalfOf = principal.OnBehalfOf
+		case types.IdentityFieldLabels:
+			assertion.Labels = principal.Labels
+		case types.IdentityFieldNamespaceID:
+			assertion.NamespaceID = namespaceID
+		}
+	}
+
+	data, err := json.Marshal(assertion)
+	if err != nil {
+		return nil, err
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func identitySettings(serverID string, req *types.SetIdentityInjectionRequest) (*types.IdentityInjectionSettings, error) {
+	settings := &types.IdentityInjectionSettings{ServerID: serverID, Target: req.Target}
+
+	switch req.Target {
+	case types.IdentityTargetMeta:
+		if req.Argument != "" {
+			return nil, types.NewValidationError("argument is only used with target argument")
+		}
+	case types.IdentityTargetArgument:
+		if !identityArgumentPattern.MatchString(req.Argument) {
+			return nil, types.NewValidationError("argument must be a name of letters, digits, '_', '.' or '-' of at most 64 characters")
+		}
+		settings.Argument = req.Argument
+	default:
+		return nil, types.NewValidationError("target must be meta or argument")
+	}
+
+	known := make(map[string]bool, len(types.IdentityFields))
+	for _, field := range types.IdentityFields {
+		known[field] = true
+	}
+	seen := make(map[string]bool, len(req.Fields))
+	for _, field := range req.Fields {
+		if !known[field] {
+			return nil, types.NewValidationError(fmt.Sprintf("unknown identity field %q", field))
+		}
+		if !seen[field] {
+			seen[field] = true
+			settings.Fields = append(settings.Fields, field)
+		}
+	}
+	return settings, nil
+}
+
+// settings returns the cached settings of a server, or nil when it receives
+// no identity
+func (s *IdentityInjectionService) settings(serverID string) (*types.IdentityInjectionSettings, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[serverID]
+	s.mu.Unlock()
+	if ok && now.Sub(cached.loadedAt) < identityInjectionCacheTTL {
+		return cached.settings, nil
+	}
+
+	settings, err := s.store.Get(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[serverID] = cachedIdentityInjection{loadedAt: now, settings: settings}
+	s.mu.Unlock()
+	return settings, nil
+}
+
+func (s *IdentityInjectionService) invalidate(serverID string) {
+	s.mu.Lock()
+	delete(s.cache, serverID)
+	s.mu.Unlock()
+}
+
+func (s *IdentityInjectionService) checkServer(orgID, serverID string) error {
+	exists, err := s.store.ServerExists(orgID, serverID)
+	if err != nil {
+		return types.NewInternalError("Failed to get server: " + err.Error())
+	}
+	if !exists {
+		return types.NewNotFoundError("Server not found")
+	}
+	return nil
+}
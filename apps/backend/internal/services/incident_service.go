@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const incidentColumns = `id, organization_id, title, severity, started_at, ended_at,
+	server_ids::text[], namespace_ids::text[], notes, postmortem_url, created_by, created_at, updated_at`
+
+// IncidentService manages admin-declared incidents. Incidents annotate health
+// history and metrics timelines and are referenced by alert notifications
+// and public status pages.
+type IncidentService struct {
+	db *sql.DB
+}
+
+// NewIncidentService creates a new incident service
+func NewIncidentService(db *sql.DB) *IncidentService {
+	return &IncidentService{db: db}
+}
+
+// Create declares an incident in the organization
+func (s *IncidentService) Create(ctx context.Context, orgID, createdBy string, req *types.CreateIncidentRequest) (*types.Incident, error) {
+	startedAt := time.Now().UTC()
+	if req.StartedAt != nil {
+		startedAt = *req.StartedAt
+	}
+	if err := validateIncidentPeriod(startedAt, req.EndedAt); err != nil {
+		return nil, err
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO incidents (organization_id, title, severity, started_at, ended_at,
+			server_ids, namespace_ids, notes, postmortem_url, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6::uuid[], $7::uuid[], $8, $9, $10)
+		RETURNING `+incidentColumns,
+		orgID, req.Title, req.Severity, startedAt, req.EndedAt,
+		pq.Array(nonNilStrings(req.ServerIDs)), pq.Array(nonNilStrings(req.NamespaceIDs)),
+		req.Notes, req.PostmortemURL, createdBy)
+	incident, err := scanIncident(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create incident: %w", err)
+	}
+	return incident, nil
+}
+
+// Get returns one of the organization's incidents
+func (s *IncidentService) Get(ctx context.Context, orgID, id string) (*types.Incident, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, types.NewNotFoundError("incident not found")
+	}
+
+	row := s.db.QueryRowContext(ctx, `SELECT `+incidentColumns+` FROM incidents
+		WHERE id = $1 AND organization_id = $2`, id, orgID)
+	incident, err := scanIncident(row)
+	if err == sql.ErrNoRows {
+		return nil, types.NewNotFoundError("incident not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incident: %w", err)
+	}
+	return incident, nil
+}
+
+// List returns the incidents matching the filter, newest first
+func (s *IncidentService) List(ctx context.Context, filter *types.IncidentFilter) ([]*types.Incident, error) {
+	conditions := []string{"organization_id = $1"}
+	args := []interface{}{filter.OrganizationID}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ServerID != "" {
+		if _, err := uuid.Parse(filter.ServerID); err != nil {
+			return nil, types.NewValidationError("server_id must be a UUID")
+		}
+		// A server is affected directly or through any of its namespaces
+		addCondition(`($%[1]d::uuid = ANY(server_ids) OR namespace_ids && ARRAY(
+			SELECT namespace_id FROM namespace_server_mappings WHERE server_id = $%[1]d::uuid))`, filter.ServerID)
+	}
+	if filter.NamespaceID != "" {
+		if _, err := uuid.Parse(filter.NamespaceID); err != nil {
+			return nil, types.NewValidationError("namespace_id must be a UUID")
+		}
+		addCondition("$%d::uuid = ANY(namespace_ids)", filter.NamespaceID)
+	}
+	if filter.From != nil {
+		addCondition("(ended_at IS NULL OR ended_at >= $%d)", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("started_at <= $%d", *filter.To)
+	}
+	if filter.OngoingOnly {
+		conditions = append(conditions, "ended_at IS NULL")
+	}
+
+	query := `SELECT ` + incidentColumns + ` FROM incidents WHERE ` +
+		strings.Join(conditions, " AND ") + ` ORDER BY started_at DESC`
+	return s.query(ctx, query, args...)
+}
+
+// Update changes an incident; setting an end time resolves it
+func (s *IncidentService) Update(ctx context.Context, orgID, id string, req *types.UpdateIncidentRequest) (*types.Incident, error) {
+	incident, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		incident.Title = *req.Title
+	}
+	if req.Severity != nil {
+		incident.Severity = *req.Severity
+	}
+	if req.StartedAt != nil {
+		incident.StartedAt = *req.StartedAt
+	}
+	if req.EndedAt != nil {
+		incident.EndedAt = req.EndedAt
+	}
+	if req.Notes != nil {
+		incident.Notes = *req.Notes
+	}
+	if req.PostmortemURL != nil {
+		incident.PostmortemURL = *req.PostmortemURL
+	}
+	if req.ServerIDs != nil {
+		incident.ServerIDs = *req.ServerIDs
+	}
+	if req.NamespaceIDs != nil {
+		incident.NamespaceIDs = *req.NamespaceIDs
+	}
+	if err := validateIncidentPeriod(incident.StartedAt, incident.EndedAt); err != nil {
+		return nil, err
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE incidents
+		SET title = $3, severity = $4, started_at = $5, ended_at = $6,
+			server_ids = $7::uuid[], namespace_ids = $8::uuid[], notes = $9, postmortem_url = $10
+		WHERE id = $1 AND organization_id = $2
+		RETURNING `+incidentColumns,
+		id, orgID, incident.Title, incident.Severity, incident.StartedAt, incident.EndedAt,
+		pq.Array(nonNilStrings(incident.ServerIDs)), pq.Array(nonNilStrings(incident.NamespaceIDs)),
+		incident.Notes, incident.PostmortemURL)
+	updated, err := scanIncident(row)
+	if err == sql.ErrNoRows {
+		return nil, types.NewNotFoundError("incident not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update incident: %w", err)
+	}
+	return updated, nil
+}
+
+// Delete removes one of the organization's incidents
+func (s *IncidentService) Delete(ctx context.Context, orgID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return types.NewNotFoundError("incident not found")
+	}
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM incidents WHERE id = $1 AND organization_id = $2`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete incident: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return types.NewNotFoundError("incident not found")
+	}
+	return nil
+}
+
+// OngoingForServer returns the most recent ongoing incident affecting the
+// server, directly or through one of its namespaces, or nil if there is none
+func (s *IncidentService) OngoingForServer(ctx context.Context, orgID, serverID string) (*types.Incident, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+incidentColumns+` FROM incidents
+		WHERE organization_id = $1 AND ended_at IS NULL
+		AND ($2::uuid = ANY(server_ids) OR namespace_ids && ARRAY(
+			SELECT namespace_id FROM namespace_server_mappings WHERE server_id = $2::uuid))
+		ORDER BY started_at DESC
+		LIMIT 1`, orgID, serverID)
+	incident, err := scanIncident(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ongoing incident: %w", err)
+	}
+	return incident, nil
+}
+
+// ForNamespace returns the incidents since the given time that affected the
+// namespace or any of the given servers, newest first
+func (s *IncidentService) ForNamespace(ctx context.Context, orgID, namespaceID string, serverIDs []string, since time.Time) ([]*types.Incident, error) {
+	return s.query(ctx, `SELECT `+incidentColumns+` FROM incidents
+		WHERE organization_id = $1 AND (ended_at IS NULL OR ended_at >= $4)
+		AND ($2::uuid = ANY(namespace_ids) OR server_ids && $3::uuid[])
+		ORDER BY started_at DESC`, orgID, namespaceID, pq.Array(nonNilStrings(serverIDs)), since)
+}
+
+func (s *IncidentService) query(ctx context.Context, query string, args ...interface{}) ([]*types.Incident, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incidents: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := []*types.Incident{}
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan incident: %w", err)
+		}
+		incidents = append(incidents, incident)
+	}
+	return incidents, rows.Err()
+}
+
+func validateIncidentPeriod(startedAt time.Time, endedAt *time.Time) error {
+	if endedAt != nil && endedAt.Before(startedAt) {
+		return types.NewValidationError("ended_at must not be before started_at")
+	}
+	return nil
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func scanIncident(row rowScanner) (*types.Incident, error) {
+	incident := &types.Incident{}
+	var endedAt sql.NullTime
+	err := row.Scan(&incident.ID, &incident.OrganizationID, &incident.Title, &incident.Severity,
+		&incident.StartedAt, &endedAt, pq.Array(&incident.ServerIDs), pq.Array(&incident.NamespaceIDs),
+		&incident.Notes, &incident.PostmortemURL, &incident.CreatedBy, &incident.CreatedAt, &incident.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if endedAt.Valid {
+		incident.EndedAt = &endedAt.Time
+	}
+	incident.ServerIDs = nonNilStrings(incident.ServerIDs)
+	incident.NamespaceIDs = nonNilStrings(incident.NamespaceIDs)
+	return incident, nil
+}
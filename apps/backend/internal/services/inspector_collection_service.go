@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// InspectorCollectionStore persists saved inspector request collections
+type InspectorCollectionStore interface {
+	ServerExists(orgID, serverID string) (bool, error)
+	List(orgID, serverID string) ([]*types.InspectorCollection, error)
+	Get(orgID, id string) (*types.InspectorCollection, error)
+	Create(collection *types.InspectorCollection) error
+	Update(collection *types.InspectorCollection) error
+	Delete(orgID, id string) (bool, error)
+}
+
+// InspectorCollectionService manages the request collections an
+// organization saves per server for repeatable debugging in the inspector
+type InspectorCollectionService struct {
+	store InspectorCollectionStore
+}
+
+// NewInspectorCollectionService creates a database-backed inspector
+// collection service
+func NewInspectorCollectionService(db *sql.DB) *InspectorCollectionService {
+	return NewInspectorCollectionServiceWithStore(models.NewInspectorCollectionModel(db))
+}
+
+// NewInspectorCollectionServiceWithStore creates an inspector collection
+// service over store
+func NewInspectorCollectionServiceWithStore(store InspectorCollectionStore) *InspectorCollectionService {
+	return &InspectorCollectionService{store: store}
+}
+
+// ListCollections returns the collections saved for a server
+func (s *InspectorCollectionService) ListCollections(ctx context.Context, orgID, serverID string) ([]*types.InspectorCollection, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	collections, err := s.store.List(orgID, serverID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list inspector collections: " + err.Error())
+	}
+	return collections, nil
+}
+
+// GetCollection returns a collection of the organization
+func (s *InspectorCollectionService) GetCollection(ctx context.Context, orgID, id string) (*types.InspectorCollection, error) {
+	collection, err := s.store.Get(orgID, id)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get inspector collection: " + err.Error())
+	}
+	if collection == nil {
+		return nil, types.NewNotFoundError("Inspector collection not found")
+	}
+	return collection, nil
+}
+
+// CreateCollection saves a collection of requests for a server
+func (s *InspectorCollectionService) CreateCollection(ctx context.Context, orgID, serverID, userID string, req *types.CreateInspectorCollectionRequest) (*types.InspectorCollection, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+
+	collection := &types.InspectorCollection{
+		OrganizationID: orgID,
+		ServerID:       serverID,
+		Name:           strings.TrimSpace(req.Name),
+		Description:    req.Description,
+		Requests:       req.Requests,
+		CreatedBy:      userID,
+	}
+	if err := s.validateCollection(collection); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Create(collection); err != nil {
+		return nil, types.NewInternalError("Failed to create inspector collection: " + err.Error())
+	}
+	return collection, nil
+}
+
+// UpdateCollection changes a collection's name, description or requests
+func (s *InspectorCollectionService) UpdateCollection(ctx context.Context, orgID, id string, req *types.UpdateInspectorCollectionRequest) (*types.InspectorCollection, error) {
+	collection, err := s.GetCollection(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		collection.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Description != nil {
+		collection.Description = *req.Description
+	}
+	if req.Requests != nil {
+		collection.Requests = *req.Requests
+	}
+	if err := s.validateCollection(collection); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Update(collection); err != nil {
+		return nil, types.NewInternalError("Failed to update inspector collection: " + err.Error())
+	}
+	return collection, nil
+}
+
+// DeleteCollection removes a collection of the organization
+func (s *InspectorCollectionService) DeleteCollection(ctx context.Context, orgID, id string) error {
+	deleted, err := s.store.Delete(orgID, id)
+	if err != nil {
+		return types.NewInternalError("Failed to delete inspector collection: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Inspector collection not found")
+	}
+	return nil
+}
+
+// checkServer returns a not found error unless the server belongs to the
+// organization
+func (s *InspectorCollectionService) checkServer(orgID, serverID string) error {
+	exists, err := s.store.ServerExists(orgID, serverID)
+	if err != nil {
+		return types.NewInternalError("Failed to get server: " + err.Error())
+	}
+	if !exists {
+		return types.NewNotFoundError("Server not found")
+	}
+	return nil
+}
+
+func (s *InspectorCollectionService) validateCollection(collection *types.InspectorCollection) error {
+	if collection.Name == "" {
+		return types.NewValidationError("name is required")
+	}
+	if len(collection.Requests) > types.MaxInspectorCollectionRequests {
+		return types.NewValidationError(fmt.Sprintf("a collection can have at most %d requests", types.MaxInspectorCollectionRequests))
+	}
+	if collection.Requests == nil {
+		collection.Requests = []types.InspectorCollectionRequest{}
+	}
+	for i, request := range collection.Requests {
+		if strings.TrimSpace(request.Method) == "" {
+			return types.NewValidationError(fmt.Sprintf("request %d needs a method", i+1))
+		}
+	}
+
+	existing, err := s.store.List(collection.OrganizationID, collection.ServerID)
+	if err != nil {
+		return fmt.Errorf("failed to list inspector collections: %w", err)
+	}
+	for _, other := range existing {
+		if other.ID != collection.ID && strings.EqualFold(other.Name, collection.Name) {
+			return types.NewConflictError("This server already has a collection with this name")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const legalHoldColumns = `id, organization_id, reason, placed_by, placed_at,
+	COALESCE(released_by, ''), released_at, COALESCE(release_reason, '')`
+
+// LegalHoldService places and releases legal holds on organizations. While
+// an organization is held its logs and audit records are kept regardless of
+// retention settings and destructive operations on them are refused.
+type LegalHoldService struct {
+	db *sql.DB
+}
+
+// NewLegalHoldService creates a new legal hold service
+func NewLegalHoldService(db *sql.DB) *LegalHoldService {
+	return &LegalHoldService{db: db}
+}
+
+// List returns active holds, newest first, and released ones too when
+// includeReleased is set
+func (s *LegalHoldService) List(ctx context.Context, includeReleased bool) ([]*types.LegalHold, error) {
+	query := `SELECT ` + legalHoldColumns + ` FROM legal_holds`
+	if !includeReleased {
+		query += ` WHERE released_at IS NULL`
+	}
+	query += ` ORDER BY placed_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list legal holds: %w", err)
+	}
+	defer rows.Close()
+
+	holds := []*types.LegalHold{}
+	for rows.Next() {
+		hold, err := scanLegalHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan legal hold: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+	return holds, rows.Err()
+}
+
+// Active returns the organization's active hold, or nil if it is not held
+func (s *LegalHoldService) Active(ctx context.Context, orgID string) (*types.LegalHold, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+legalHoldColumns+` FROM legal_holds
+		WHERE organization_id = $1 AND released_at IS NULL`, orgID)
+	hold, err := scanLegalHold(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legal hold: %w", err)
+	}
+	return hold, nil
+}
+
+// CheckNotHeld returns a legal hold error when the organization is held.
+// Lookup failures are returned as well so callers fail closed.
+func (s *LegalHoldService) CheckNotHeld(ctx context.Context, orgID string) error {
+	if orgID == "" {
+		return nil
+	}
+	hold, err := s.Active(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if hold.Active() {
+		return types.NewLegalHoldError(orgID)
+	}
+	return nil
+}
+
+// Place puts the organization under legal hold
+func (s *LegalHoldService) Place(ctx context.Context, orgID, placedBy, reason string) (*types.LegalHold, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM organizations WHERE id = $1)`, orgID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up organization: %w", err)
+	}
+	if !exists {
+		return nil, types.NewNotFoundError("organization not found")
+	}
+
+	existing, err := s.Active(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, types.NewConflictError("organization is already under legal hold")
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO legal_holds (organization_id, reason, placed_by)
+		VALUES ($1, $2, $3)
+		RETURNING `+legalHoldColumns, orgID, reason, placedBy)
+	hold, err := scanLegalHold(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to place legal hold: %w", err)
+	}
+	return hold, nil
+}
+
+// Release lifts the organization's active hold
+func (s *LegalHoldService) Release(ctx context.Context, orgID, releasedBy, reason string) (*types.LegalHold, error) {
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE legal_holds
+		SET released_by = $2, released_at = NOW(), release_reason = $3
+		WHERE organization_id = $1 AND released_at IS NULL
+		RETURNING `+legalHoldColumns, orgID, releasedBy, reason)
+	hold, err := scanLegalHold(row)
+	if err == sql.ErrNoRows {
+		return nil, types.NewNotFoundError("organization is not under legal hold")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to release legal hold: %w", err)
+	}
+	return hold, nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLegalHold(row rowScanner) (*types.LegalHold, error) {
+	hold := &types.LegalHold{}
+	var releasedAt sql.NullTime
+	err := row.Scan(&hold.ID, &hold.OrganizationID, &hold.Reason, &hold.PlacedBy, &hold.PlacedAt,
+		&hold.ReleasedBy, &releasedAt, &hold.ReleaseReason)
+	if err != nil {
+		return nil, err
+	}
+	if releasedAt.Valid {
+		hold.ReleasedAt = &releasedAt.Time
+	}
+	return hold, nil
+}
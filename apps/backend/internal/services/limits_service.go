@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// limitsUsageWindow is how far back caller usage is summarized
+const limitsUsageWindow = 24 * time.Hour
+
+// PrincipalUsageSource aggregates logged activity per principal
+type PrincipalUsageSource interface {
+	GetUsageByPrincipal(ctx context.Context, query *logging.QueryRequest) ([]*logging.PrincipalUsage, error)
+}
+
+// LimitsService reports plan quotas and recent usage for the calling principal
+type LimitsService struct {
+	db    *sql.DB
+	usage PrincipalUsageSource
+}
+
+// NewLimitsService creates a new limits service
+func NewLimitsService(db *sql.DB, usage PrincipalUsageSource) *LimitsService {
+	return &LimitsService{
+		db:    db,
+		usage: usage,
+	}
+}
+
+// GetPlanQuotas returns the organization's plan limits with current consumption
+func (s *LimitsService) GetPlanQuotas(ctx context.Context, orgID string) (*types.PlanQuotas, error) {
+	query := `
+		SELECT o.plan_type, o.max_servers, o.max_sessions, o.log_retention_days,
+			(SELECT COUNT(*) FROM mcp_servers WHERE organization_id = o.id AND is_active = true),
+			(SELECT COUNT(*) FROM mcp_sessions WHERE organization_id = o.id AND status IN ('initializing', 'active'))
+		FROM organizations o
+		WHERE o.id = $1
+	`
+
+	quotas := &types.PlanQuotas{OrganizationID: orgID}
+	err := s.db.QueryRowContext(ctx, query, orgID).Scan(
+		&quotas.PlanType, &quotas.Servers.Limit, &quotas.Sessions.Limit,
+		&quotas.LogRetentionDays, &quotas.Servers.Used, &quotas.Sessions.Used,
+	)
+	if err == sql.ErrNoRows {
+		return nil, types.NewNotFoundError("Organization not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return quotas, nil
+}
+
+// GetPrincipalUsage summarizes the principal's requests and tool executions
+// over the last 24 hours
+func (s *LimitsService) GetPrincipalUsage(ctx context.Context, principal *types.Principal) (*types.PrincipalUsageSummary, error) {
+	summary := &types.PrincipalUsageSummary{Window: limitsUsageWindow.String()}
+	if s.usage == nil || principal == nil {
+		return summary, nil
+	}
+
+	endTime := time.Now()
+	startTime := endTime.Add(-limitsUsageWindow)
+	usage, err := s.usage.GetUsageByPrincipal(ctx, &logging.QueryRequest{
+		StartTime:     &startTime,
+		EndTime:       &endTime,
+		OrgID:         principal.OrganizationID,
+		PrincipalType: principal.Type,
+		PrincipalID:   principal.ID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	key := principal.Key()
+	for _, entry := range usage {
+		if entry.Key != key {
+			continue
+		}
+		summary.Requests = entry.Requests
+		summary.ToolExecutions = entry.ToolExecutions
+		summary.Errors = entry.Errors
+		if !entry.LastSeen.IsZero() {
+			lastSeen := entry.LastSeen
+			summary.LastSeen = &lastSeen
+		}
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,574 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	// logExportListLimit is how many recent exports are listed
+	logExportListLimit = 50
+	// logExportRowGroupSize is how many rows a Parquet row group holds
+	logExportRowGroupSize     = 10000
+	defaultLogExportLinkTTL   = time.Hour
+	defaultLogExportRetention = 7 * 24 * time.Hour
+)
+
+// LogExportStore persists log export jobs. Get and GetByID return nil when
+// the export does not exist.
+type LogExportStore interface {
+	Create(export *types.LogExport) error
+	Get(orgID, id string) (*types.LogExport, error)
+	GetByID(id string) (*types.LogExport, error)
+	List(orgID string, limit int) ([]*types.LogExport, error)
+	ClaimPending(limit int) ([]*types.LogExport, error)
+	Finish(export *types.LogExport) error
+	ListExpired(now time.Time) ([]*types.LogExport, error)
+	MarkExpired(id string) error
+}
+
+// ExportStorage holds export files by key. Implementations may keep them on
+// local disk or in object storage.
+type ExportStorage interface {
+	Create(key string) (io.WriteCloser, error)
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// LogExportPlanSource resolves an organization's plan
+type LogExportPlanSource interface {
+	GetPlanQuotas(ctx context.Context, orgID string) (*types.PlanQuotas, error)
+}
+
+// FileExportStorage keeps export files under a local directory
+type FileExportStorage struct {
+	dir string
+}
+
+// NewFileExportStorage creates export storage rooted at dir
+func NewFileExportStorage(dir string) *FileExportStorage {
+	return &FileExportStorage{dir: dir}
+}
+
+// Create creates or truncates the file of key
+func (s *FileExportStorage) Create(key string) (io.WriteCloser, error) {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	return os.Create(path)
+}
+
+// Open opens the file of key
+func (s *FileExportStorage) Open(key string) (io.ReadCloser, error) {
+	return os.Open(s.path(key))
+}
+
+// Delete removes the file of key; missing files are not an error
+func (s *FileExportStorage) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileExportStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(filepath.Clean("/"+key)))
+}
+
+// LogExportStream is a synchronous export, written straight to the client
+type LogExportStream struct {
+	svc         *LogExportService
+	query       *logging.QueryRequest
+	Filename    string
+	ContentType string
+	format      string
+	limits      types.LogExportLimits
+}
+
+// Write writes the export to w
+func (s *LogExportStream) Write(ctx context.Context, w io.Writer) error {
+	entries, err := s.svc.logs.Query(ctx, s.query)
+	if err != nil {
+		return fmt.Errorf("failed to query logs: %w", err)
+	}
+	_, err = writeLogExport(w, s.format, entries, s.limits)
+	return err
+}
+
+// LogExportService produces bulk CSV and Parquet exports of execution logs.
+// Exports are queued and written to export storage by the worker, then
+// downloaded through links signed with an expiry; small exports can also
+// be streamed directly. Every export is bounded by its organization's plan.
+type LogExportService struct {
+	store     LogExportStore
+	logs      LogViewLogSource
+	plans     LogExportPlanSource
+	storage   ExportStorage
+	limits    map[string]types.LogExportLimits
+	secret    []byte
+	baseURL   string
+	linkTTL   time.Duration
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewLogExportService creates a database-backed log export service
+func NewLogExportService(db *sql.DB, logs LogViewLogSource, plans LogExportPlanSource, storage ExportStorage, limits map[string]types.LogExportLimits) *LogExportService {
+	return NewLogExportServiceWithStore(models.NewLogExportModel(db), logs, plans, storage, limits)
+}
+
+// NewLogExportServiceWithStore creates a log export service over store
+func NewLogExportServiceWithStore(store LogExportStore, logs LogViewLogSource, plans LogExportPlanSource, storage ExportStorage, limits map[string]types.LogExportLimits) *LogExportService {
+	return &LogExportService{
+		store:     store,
+		logs:      logs,
+		plans:     plans,
+		storage:   storage,
+		limits:    limits,
+		linkTTL:   defaultLogExportLinkTTL,
+		retention: defaultLogExportRetention,
+		now:       time.Now,
+	}
+}
+
+// SetSigning sets the secret download links are signed with, the base URL
+// they point at and how long they stay valid
+func (s *LogExportService) SetSigning(secret, baseURL string, linkTTL time.Duration) {
+	s.secret = []byte(secret)
+	s.baseURL = strings.TrimSuffix(baseURL, "/")
+	if linkTTL > 0 {
+		s.linkTTL = linkTTL
+	}
+}
+
+// SetRetention sets how long export files are kept
+func (s *LogExportService) SetRetention(retention time.Duration) {
+	if retention > 0 {
+		s.retention = retention
+	}
+}
+
+// CreateExport queues an export of the organization's logs
+func (s *LogExportService) CreateExport(ctx context.Context, orgID, userID string, req *types.CreateLogExportRequest) (*types.LogExport, error) {
+	if _, err := s.validate(ctx, orgID, req); err != nil {
+		return nil, err
+	}
+
+	export := &types.LogExport{
+		OrganizationID: orgID,
+		RequestedBy:    userID,
+		Format:         req.Format,
+		Status:         types.LogExportStatusPending,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		Filters:        req.Filters,
+	}
+	export.Filters.Window = ""
+	if err := s.store.Create(export); err != nil {
+		return nil, fmt.Errorf("failed to create log export: %w", err)
+	}
+	return export, nil
+}
+
+// ListExports returns the organization's recent exports, newest first
+func (s *LogExportService) ListExports(ctx context.Context, orgID string) ([]*types.LogExport, error) {
+	exports, err := s.store.List(orgID, logExportListLimit)
+	if err != nil {
+		return nil, err
+	}
+	if exports == nil {
+		exports = []*types.LogExport{}
+	}
+	for _, export := range exports {
+		s.attachDownloadURL(export)
+	}
+	return exports, nil
+}
+
+// GetExport returns an export with a fresh download link once it completed
+func (s *LogExportService) GetExport(ctx context.Context, orgID, id string) (*types.LogExport, error) {
+	export, err := s.store.Get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if export == nil {
+		return nil, types.NewNotFoundError("Log export not found")
+	}
+	s.attachDownloadURL(export)
+	return export, nil
+}
+
+// PrepareStream validates a synchronous export so that errors can be
+// reported before anything is written to the client
+func (s *LogExportService) PrepareStream(ctx context.Context, orgID string, req *types.CreateLogExportRequest) (*LogExportStream, error) {
+	limits, err := s.validate(ctx, orgID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := &LogExportStream{
+		svc:         s,
+		query:       logExportQuery(orgID, req.Filters, req.StartTime, req.EndTime, limits),
+		Filename:    fmt.Sprintf("logs-%s.%s", s.now().UTC().Format("20060102T150405Z"), req.Format),
+		ContentType: logExportContentType(req.Format),
+		format:      req.Format,
+		limits:      limits,
+	}
+	return stream, nil
+}
+
+// ProcessPending claims up to batch pending exports and writes them to
+// export storage, returning how many were processed
+func (s *LogExportService) ProcessPending(ctx context.Context, batch int) (int, error) {
+	exports, err := s.store.ClaimPending(batch)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, export := range exports {
+		if err := s.process(ctx, export); err != nil {
+			export.Status = types.LogExportStatusFailed
+			export.Error = err.Error()
+			export.StorageKey = ""
+		}
+		completedAt := s.now()
+		export.CompletedAt = &completedAt
+		if export.Status == types.LogExportStatusCompleted {
+			expiresAt := completedAt.Add(s.retention)
+			export.ExpiresAt = &expiresAt
+		}
+		if err := s.store.Finish(export); err != nil {
+			return 0, fmt.Errorf("failed to finish log export %s: %w", export.ID, err)
+		}
+	}
+	return len(exports), nil
+}
+
+// ExpireExports removes the files of exports past their retention,
+// returning how many expired
+func (s *LogExportService) ExpireExports(ctx context.Context) (int, error) {
+	exports, err := s.store.ListExpired(s.now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, export := range exports {
+		if export.StorageKey != "" {
+			if err := s.storage.Delete(export.StorageKey); err != nil {
+				return 0, fmt.Errorf("failed to delete log export %s: %w", export.ID, err)
+			}
+		}
+		if err := s.store.MarkExpired(export.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(exports), nil
+}
+
+// DownloadURL returns a link to an export's file, valid for the link TTL
+func (s *LogExportService) DownloadURL(export *types.LogExport) string {
+	expires := s.now().Add(s.linkTTL).Unix()
+	return fmt.Sprintf("%s/api/public/log-exports/%s/download?expires=%d&signature=%s",
+		s.baseURL, export.ID, expires, s.sign(export.ID, expires))
+}
+
+// OpenDownload checks a download link's signature and expiry and opens the
+// export's file
+func (s *LogExportService) OpenDownload(ctx context.Context, id, expires, signature string) (*types.LogExport, io.ReadCloser, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || len(s.secret) == 0 ||
+		!hmac.Equal([]byte(signature), []byte(s.sign(id, expiresAt))) {
+		return nil, nil, types.NewForbiddenError("Invalid download link")
+	}
+	if s.now().Unix() > expiresAt {
+		return nil, nil, types.NewForbiddenError("Download link has expired")
+	}
+
+	export, err := s.store.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if export == nil || export.Status != types.LogExportStatusCompleted || export.StorageKey == "" {
+		return nil, nil, types.NewNotFoundError("Log export not found")
+	}
+
+	file, err := s.storage.Open(export.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log export: %w", err)
+	}
+	return export, file, nil
+}
+
+// process writes an export's file and records its size
+func (s *LogExportService) process(ctx context.Context, export *types.LogExport) error {
+	limits, err := s.planLimits(ctx, export.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	entries, err := s.logs.Query(ctx, logExportQuery(export.OrganizationID, export.Filters, export.StartTime, export.EndTime, limits))
+	if err != nil {
+		return fmt.Errorf("failed to query logs: %w", err)
+	}
+
+	key := fmt.Sprintf("%s/%s.%s", export.OrganizationID, export.ID, export.Format)
+	file, err := s.storage.Create(key)
+	if err != nil {
+		return err
+	}
+	result, err := writeLogExport(file, export.Format, entries, limits)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = s.storage.Delete(key)
+		return err
+	}
+
+	export.Status = types.LogExportStatusCompleted
+	export.StorageKey = key
+	export.RowCount = result.rows
+	export.SizeBytes = result.size
+	export.Truncated = result.truncated
+	return nil
+}
+
+// validate checks an export request against the organization's plan and
+// returns the plan's limits
+func (s *LogExportService) validate(ctx context.Context, orgID string, req *types.CreateLogExportRequest) (types.LogExportLimits, error) {
+	if req.Format != types.LogExportFormatCSV && req.Format != types.LogExportFormatParquet {
+		return types.LogExportLimits{}, types.NewValidationError("format must be csv or parquet")
+	}
+	if !req.EndTime.After(req.StartTime) {
+		return types.LogExportLimits{}, types.NewValidationError("end_time must be after start_time")
+	}
+
+	limits, err := s.planLimits(ctx, orgID)
+	if err != nil {
+		return types.LogExportLimits{}, err
+	}
+	if maxRange := time.Duration(limits.MaxRangeDays) * 24 * time.Hour; req.EndTime.Sub(req.StartTime) > maxRange {
+		return types.LogExportLimits{}, types.NewValidationError(
+			fmt.Sprintf("your plan allows exporting at most %d days of logs at once", limits.MaxRangeDays))
+	}
+	return limits, nil
+}
+
+// planLimits returns the export limits of the organization's plan, falling
+// back to the free plan's
+func (s *LogExportService) planLimits(ctx context.Context, orgID string) (types.LogExportLimits, error) {
+	quotas, err := s.plans.GetPlanQuotas(ctx, orgID)
+	if err != nil {
+		return types.LogExportLimits{}, err
+	}
+	if limits, ok := s.limits[quotas.PlanType]; ok {
+		return limits, nil
+	}
+	return s.limits["free"], nil
+}
+
+func (s *LogExportService) attachDownloadURL(export *types.LogExport) {
+	if export.Status == types.LogExportStatusCompleted && len(s.secret) > 0 {
+		export.DownloadURL = s.DownloadURL(export)
+	}
+}
+
+func (s *LogExportService) sign(id string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%d", id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// logExportQuery builds the log query of an export, asking for one row
+// more than the plan allows so truncation can be detected
+func logExportQuery(orgID string, filters types.LogViewFilters, startTime, endTime time.Time, limits types.LogExportLimits) *logging.QueryRequest {
+	query := logViewQuery(orgID, filters, startTime, endTime)
+	query.Limit = limits.MaxRows + 1
+	return query
+}
+
+func logExportContentType(format string) string {
+	if format == types.LogExportFormatParquet {
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// logExportColumns are the columns of every export, in order
+var logExportColumns = []parquetColumn{
+	{name: "timestamp", typ: parquetTimestamp},
+	{name: "level", typ: parquetString},
+	{name: "logger", typ: parquetString},
+	{name: "message", typ: parquetString},
+	{name: "request_id", typ: parquetString},
+	{name: "user_id", typ: parquetString},
+	{name: "org_id", typ: parquetString},
+	{name: "principal_type", typ: parquetString},
+	{name: "principal_id", typ: parquetString},
+	{name: "status_code", typ: parquetInt32},
+	{name: "method", typ: parquetString},
+	{name: "path", typ: parquetString},
+	{name: "server_id", typ: parquetString},
+	{name: "tool", typ: parquetString},
+	{name: "duration_ms", typ: parquetDouble},
+	{name: "success", typ: parquetString},
+	{name: "error", typ: parquetString},
+}
+
+// logExportRow returns an entry's values in column order
+func logExportRow(entry *logging.LogEntry) []interface{} {
+	var principalType, principalID string
+	if entry.Principal != nil {
+		principalType = entry.Principal.Type
+		principalID = entry.Principal.ID()
+	}
+	str := func(key string) string {
+		s, _ := entry.Data[key].(string)
+		return s
+	}
+	duration, _ := entry.Data["duration_ms"].(float64)
+	var success string
+	if ok, isBool := entry.Data["success"].(bool); isBool {
+		success = strconv.FormatBool(ok)
+	}
+
+	return []interface{}{
+		entry.Timestamp.UTC(), string(entry.Level), entry.Logger, entry.Message, entry.RequestID,
+		entry.UserID, entry.OrgID, principalType, principalID, int32(entry.StatusCode),
+		str("method"), str("path"), str("server_id"), str("tool"), duration, success, str("error"),
+	}
+}
+
+// logExportResult summarizes a written export
+type logExportResult struct {
+	rows      int64
+	size      int64
+	truncated bool
+}
+
+// logExportWriter writes export rows in one format
+type logExportWriter interface {
+	WriteRow(values []interface{}) error
+	Size() int64
+	Close() error
+}
+
+// writeLogExport writes entries to w, stopping at the plan's row and byte
+// limits
+func writeLogExport(w io.Writer, format string, entries []*logging.LogEntry, limits types.LogExportLimits) (*logExportResult, error) {
+	buffered := bufio.NewWriterSize(w, 1<<16)
+	var writer logExportWriter
+	var err error
+	if format == types.LogExportFormatParquet {
+		writer, err = newParquetWriter(buffered, logExportColumns, logExportRowGroupSize)
+	} else {
+		writer, err = newCSVExportWriter(buffered)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &logExportResult{}
+	for i, entry := range entries {
+		if i >= limits.MaxRows || writer.Size() >= limits.MaxBytes {
+			result.truncated = true
+			break
+		}
+		if err := writer.WriteRow(logExportRow(entry)); err != nil {
+			return nil, err
+		}
+		result.rows++
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	result.size = writer.Size()
+	return result, buffered.Flush()
+}
+
+// csvExportWriter writes export rows as CSV with a header row
+type csvExportWriter struct {
+	counter *countingWriter
+	csv     *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer) (*csvExportWriter, error) {
+	counter := &countingWriter{w: w}
+	writer := &csvExportWriter{counter: counter, csv: csv.NewWriter(counter)}
+
+	header := make([]string, len(logExportColumns))
+	for i, column := range logExportColumns {
+		header[i] = column.name
+	}
+	if err := writer.csv.Write(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// WriteRow writes one record. Cells that a spreadsheet would evaluate as
+// a formula are prefixed with a quote.
+func (c *csvExportWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			record[i] = v.Format(time.RFC3339Nano)
+		case int32:
+			record[i] = strconv.Itoa(int(v))
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case string:
+			if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+				v = "'" + v
+			}
+			record[i] = v
+		}
+	}
+	if err := c.csv.Write(record); err != nil {
+		return err
+	}
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+// Size returns the bytes written so far
+func (c *csvExportWriter) Size() int64 {
+	return c.counter.n
+}
+
+// Close flushes the last record
+func (c *csvExportWriter) Close() error {
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// logFieldPolicyCacheTTL bounds how long a policy change made on another
+// gateway instance takes to reach the request logger
+const logFieldPolicyCacheTTL = time.Minute
+
+// LogFieldPolicyStore persists organizations' log field policies
+type LogFieldPolicyStore interface {
+	Get(orgID string) (*types.LogFieldPolicy, error)
+	Set(policy *types.LogFieldPolicy) error
+	Delete(orgID string) (bool, error)
+}
+
+type cachedLogFieldPolicy struct {
+	loadedAt time.Time
+	policy   *types.LogFieldPolicy
+}
+
+// LogFieldPolicyService manages which optional fields the request logger
+// records for each organization. Lookups for the logger are cached.
+type LogFieldPolicyService struct {
+	store LogFieldPolicyStore
+	cache map[string]cachedLogFieldPolicy
+	mu    sync.RWMutex
+}
+
+// NewLogFieldPolicyService creates a database-backed log field policy service
+func NewLogFieldPolicyService(db *sql.DB) *LogFieldPolicyService {
+	return NewLogFieldPolicyServiceWithStore(models.NewLogFieldPolicyModel(db))
+}
+
+// NewLogFieldPolicyServiceWithStore creates a log field policy service over store
+func NewLogFieldPolicyServiceWithStore(store LogFieldPolicyStore) *LogFieldPolicyService {
+	return &LogFieldPolicyService{
+		store: store,
+		cache: make(map[string]cachedLogFieldPolicy),
+	}
+}
+
+// GetPolicy returns the organization's policy. Organizations without one
+// get an empty policy, which logs every field.
+func (s *LogFieldPolicyService) GetPolicy(ctx context.Context, orgID string) (*types.LogFieldPolicy, error) {
+	policy, err := s.store.Get(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log field policy: %w", err)
+	}
+	if policy == nil {
+		policy = &types.LogFieldPolicy{OrganizationID: orgID}
+	}
+	if policy.OmitFields == nil {
+		policy.OmitFields = []string{}
+	}
+	return policy, nil
+}
+
+// SetPolicy replaces the organization's policy
+func (s *LogFieldPolicyService) SetPolicy(ctx context.Context, orgID, updatedBy string, req *types.SetLogFieldPolicyRequest) (*types.LogFieldPolicy, error) {
+	omit := []string{}
+	requested := append(types.LogFieldPresetOmits(req.Preset), req.OmitFields...)
+	for _, field := range types.LogFields {
+		for _, name := range requested {
+			if name == field {
+				omit = append(omit, field)
+				break
+			}
+		}
+	}
+
+	policy := &types.LogFieldPolicy{
+		OrganizationID: orgID,
+		OmitFields:     omit,
+		UpdatedBy:      updatedBy,
+	}
+	if err := s.store.Set(policy); err != nil {
+		return nil, fmt.Errorf("failed to save log field policy: %w", err)
+	}
+	s.invalidate(orgID)
+	return policy, nil
+}
+
+// ResetPolicy returns the organization to logging every field
+func (s *LogFieldPolicyService) ResetPolicy(ctx context.Context, orgID string) error {
+	deleted, err := s.store.Delete(orgID)
+	if err != nil {
+		return fmt.Errorf("failed to reset log field policy: %w", err)
+	}
+	s.invalidate(orgID)
+	if !deleted {
+		return types.NewNotFoundError("Organization has no log field policy")
+	}
+	return nil
+}
+
+// FieldPolicy returns the policy the request logger applies for an
+// organization. When the policy cannot be loaded and none is cached,
+// bodies and query strings are left out rather than logged by mistake.
+func (s *LogFieldPolicyService) FieldPolicy(ctx context.Context, orgID string) *types.LogFieldPolicy {
+	if orgID == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	cached, ok := s.cache[orgID]
+	s.mu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < logFieldPolicyCacheTTL {
+		return cached.policy
+	}
+
+	policy, err := s.store.Get(orgID)
+	if err != nil {
+		if ok {
+			return cached.policy
+		}
+		return &types.LogFieldPolicy{
+			OrganizationID: orgID,
+			OmitFields:     types.LogFieldPresetOmits(types.LogFieldPresetMetadataOnly),
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[orgID] = cachedLogFieldPolicy{policy: policy, loadedAt: time.Now()}
+	s.mu.Unlock()
+	return policy
+}
+
+// invalidate drops the cached policy so this instance applies a change at once
+func (s *LogFieldPolicyService) invalidate(orgID string) {
+	s.mu.Lock()
+	delete(s.cache, orgID)
+	s.mu.Unlock()
+}
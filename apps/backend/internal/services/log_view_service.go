@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// LogViewStore persists saved log views. Get returns nil when the view does
+// not exist.
+type LogViewStore interface {
+	Create(view *types.SavedLogView) error
+	List(orgID, userID, kind string) ([]*types.SavedLogView, error)
+	Get(orgID, id string) (*types.SavedLogView, error)
+	Update(view *types.SavedLogView) error
+	Delete(orgID, id string) (bool, error)
+}
+
+// LogViewLogSource queries the request and tool execution logs
+type LogViewLogSource interface {
+	Query(ctx context.Context, query *logging.QueryRequest) ([]*logging.LogEntry, error)
+}
+
+// LogViewAuditSource queries the audit log
+type LogViewAuditSource interface {
+	QueryAuditLogs(query *types.AuditLogQuery) ([]*types.AuditLog, error)
+}
+
+// LogViewResults are the records matching a saved view over its window
+type LogViewResults struct {
+	StartTime time.Time           `json:"start_time"`
+	EndTime   time.Time           `json:"end_time"`
+	View      *types.SavedLogView `json:"view"`
+	Logs      []*logging.LogEntry `json:"logs,omitempty"`
+	AuditLogs []*types.AuditLog   `json:"audit_logs,omitempty"`
+}
+
+// LogViewService manages saved log and audit views and computes the
+// widgets pinned to them. Views are private to their owner unless shared
+// with the organization, and only the owner can change them.
+type LogViewService struct {
+	store  LogViewStore
+	logs   LogViewLogSource
+	audits LogViewAuditSource
+	now    func() time.Time
+}
+
+// NewLogViewService creates a database-backed log view service
+func NewLogViewService(db *sql.DB, logs LogViewLogSource, audits LogViewAuditSource) *LogViewService {
+	return NewLogViewServiceWithStore(models.NewSavedLogViewModel(db), logs, audits)
+}
+
+// NewLogViewServiceWithStore creates a log view service over store
+func NewLogViewServiceWithStore(store LogViewStore, logs LogViewLogSource, audits LogViewAuditSource) *LogViewService {
+	return &LogViewService{store: store, logs: logs, audits: audits, now: time.Now}
+}
+
+// ListViews returns the user's views and those shared in the organization,
+// optionally only of one kind
+func (s *LogViewService) ListViews(ctx context.Context, orgID, userID, kind string) ([]*types.SavedLogView, error) {
+	views, err := s.store.List(orgID, userID, kind)
+	if err != nil {
+		return nil, err
+	}
+	if views == nil {
+		views = []*types.SavedLogView{}
+	}
+	return views, nil
+}
+
+// GetView returns a view the user owns or that is shared with them
+func (s *LogViewService) GetView(ctx context.Context, orgID, userID, id string) (*types.SavedLogView, error) {
+	view, err := s.store.Get(orgID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved log view: %w", err)
+	}
+	if view == nil || (view.OwnerID != userID && !view.Shared) {
+		return nil, types.NewNotFoundError("Saved log view not found")
+	}
+	return view, nil
+}
+
+// CreateView saves a view owned by the user
+func (s *LogViewService) CreateView(ctx context.Context, orgID, userID string, req *types.CreateLogViewRequest) (*types.SavedLogView, error) {
+	view := &types.SavedLogView{
+		OrganizationID: orgID,
+		OwnerID:        userID,
+		Name:           strings.TrimSpace(req.Name),
+		Description:    req.Description,
+		Kind:           req.Kind,
+		Filters:        req.Filters,
+		Widgets:        req.Widgets,
+		Shared:         req.Shared,
+	}
+	if err := s.validate(orgID, view); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Create(view); err != nil {
+		return nil, fmt.Errorf("failed to create saved log view: %w", err)
+	}
+	return view, nil
+}
+
+// UpdateView changes a view the user owns
+func (s *LogViewService) UpdateView(ctx context.Context, orgID, userID, id string, req *types.UpdateLogViewRequest) (*types.SavedLogView, error) {
+	view, err := s.ownView(ctx, orgID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		view.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		view.Description = *req.Description
+	}
+	if req.Filters != nil {
+		view.Filters = *req.Filters
+	}
+	if req.Widgets != nil {
+		view.Widgets = *req.Widgets
+	}
+	if req.Shared != nil {
+		view.Shared = *req.Shared
+	}
+	if err := s.validate(orgID, view); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Update(view); err != nil {
+		return nil, fmt.Errorf("failed to update saved log view: %w", err)
+	}
+	return view, nil
+}
+
+// DeleteView removes a view the user owns
+func (s *LogViewService) DeleteView(ctx context.Context, orgID, userID, id string) error {
+	if _, err := s.ownView(ctx, orgID, userID, id); err != nil {
+		return err
+	}
+	deleted, err := s.store.Delete(orgID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved log view: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("Saved log view not found")
+	}
+	return nil
+}
+
+// RunView returns the logs or audit records matching a view over its window
+// ending now
+func (s *LogViewService) RunView(ctx context.Context, orgID, userID, id string, limit, offset int) (*LogViewResults, error) {
+	view, err := s.GetView(ctx, orgID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	startTime, endTime := s.window(view.Filters)
+	results := &LogViewResults{StartTime: startTime, EndTime: endTime, View: view}
+
+	if view.Kind == types.LogViewKindAudit {
+		query := auditViewQuery(orgID, view.Filters, startTime, endTime)
+		query.Limit = limit
+		query.Offset = offset
+		results.AuditLogs, err = s.audits.QueryAuditLogs(query)
+		if err != nil {
+			return nil, err
+		}
+		if results.AuditLogs == nil {
+			results.AuditLogs = []*types.AuditLog{}
+		}
+		return results, nil
+	}
+
+	query := logViewQuery(orgID, view.Filters, startTime, endTime)
+	query.Limit = limit
+	query.Offset = offset
+	results.Logs, err = s.logs.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if results.Logs == nil {
+		results.Logs = []*logging.LogEntry{}
+	}
+	return results, nil
+}
+
+// Widgets computes the widgets pinned to a log view over the view's logs
+func (s *LogViewService) Widgets(ctx context.Context, orgID, userID, id string) ([]*types.LogWidgetResult, error) {
+	view, err := s.GetView(ctx, orgID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*types.LogWidgetResult, 0, len(view.Widgets))
+	if len(view.Widgets) == 0 {
+		return results, nil
+	}
+
+	startTime, endTime := s.window(view.Filters)
+	entries, err := s.logs.Query(ctx, logViewQuery(orgID, view.Filters, startTime, endTime))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, widget := range view.Widgets {
+		limit := widget.Limit
+		if limit <= 0 {
+			limit = types.DefaultLogWidgetLimit
+		}
+
+		var data interface{}
+		switch widget.Type {
+		case types.LogWidgetErrorRateByServer:
+			data = firstN(logging.SummarizeByServer(entries), limit)
+		case types.LogWidgetTopTools:
+			data = firstN(logging.SummarizeByTool(entries), limit)
+		case types.LogWidgetUsageByPrincipal:
+			data = firstN(logging.SummarizeByPrincipal(entries), limit)
+		case types.LogWidgetUsageByClient:
+			data = firstN(logging.SummarizeByClient(entries), limit)
+		}
+		results = append(results, &types.LogWidgetResult{Widget: widget, Data: data})
+	}
+	return results, nil
+}
+
+// ownView returns a view the user owns. Shared views of other users are
+// forbidden; private ones look missing.
+func (s *LogViewService) ownView(ctx context.Context, orgID, userID, id string) (*types.SavedLogView, error) {
+	view, err := s.GetView(ctx, orgID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if view.OwnerID != userID {
+		return nil, types.NewForbiddenError("Only the owner can change a saved log view")
+	}
+	return view, nil
+}
+
+// validate checks a view's filters and widgets and that its owner has no
+// other view of the same name
+func (s *LogViewService) validate(orgID string, view *types.SavedLogView) error {
+	if view.Name == "" {
+		return types.NewValidationError("name is required")
+	}
+	if window := view.Filters.Window; window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			return types.NewValidationError(fmt.Sprintf("window %q must be a positive duration such as 24h", window))
+		}
+		if d > types.MaxLogViewWindow {
+			return types.NewValidationError(fmt.Sprintf("window must be at most %s", types.MaxLogViewWindow))
+		}
+	}
+	if severity := view.Filters.MinSeverity; severity != "" && !types.IsValidAuditSeverity(severity) {
+		return types.NewValidationError("min_severity must be one of info, notice, warning, critical")
+	}
+
+	if len(view.Widgets) > 0 && view.Kind != types.LogViewKindLogs {
+		return types.NewValidationError("widgets can only be pinned to log views")
+	}
+	if len(view.Widgets) > types.MaxLogViewWidgets {
+		return types.NewValidationError(fmt.Sprintf("a view can have at most %d widgets", types.MaxLogViewWidgets))
+	}
+	for _, widget := range view.Widgets {
+		switch widget.Type {
+		case types.LogWidgetErrorRateByServer, types.LogWidgetTopTools,
+			types.LogWidgetUsageByPrincipal, types.LogWidgetUsageByClient:
+		default:
+			return types.NewValidationError(fmt.Sprintf("unknown widget type %q", widget.Type))
+		}
+		if widget.Limit < 0 || widget.Limit > types.MaxLogWidgetLimit {
+			return types.NewValidationError(fmt.Sprintf("widget limit must be between 0 and %d", types.MaxLogWidgetLimit))
+		}
+	}
+
+	existing, err := s.store.List(orgID, view.OwnerID, "")
+	if err != nil {
+		return fmt.Errorf("failed to list saved log views: %w", err)
+	}
+	for _, other := range existing {
+		if other.OwnerID == view.OwnerID && other.ID != view.ID && strings.EqualFold(other.Name, view.Name) {
+			return types.NewConflictError("you already have a saved log view with this name")
+		}
+	}
+	return nil
+}
+
+// window resolves a view's relative time range against now
+func (s *LogViewService) window(filters types.LogViewFilters) (time.Time, time.Time) {
+	window := types.DefaultLogViewWindow
+	if d, err := time.ParseDuration(filters.Window); err == nil && d > 0 {
+		window = d
+	}
+	endTime := s.now()
+	return endTime.Add(-window), endTime
+}
+
+// logViewQuery builds the log query of a view's filters
+func logViewQuery(orgID string, filters types.LogViewFilters, startTime, endTime time.Time) *logging.QueryRequest {
+	query := &logging.QueryRequest{
+		StartTime:     &startTime,
+		EndTime:       &endTime,
+		Level:         logging.LogLevel(filters.Level),
+		UserID:        filters.UserID,
+		OrgID:         orgID,
+		Message:       filters.Search,
+		PrincipalType: filters.PrincipalType,
+		PrincipalID:   filters.PrincipalID,
+	}
+	if len(filters.Labels) > 0 {
+		query.Labels = types.Labels(filters.Labels)
+	}
+	if filters.Method != "" || filters.Path != "" {
+		query.Filters = make(map[string]interface{})
+		if filters.Method != "" {
+			query.Filters["method"] = filters.Method
+		}
+		if filters.Path != "" {
+			query.Filters["path"] = filters.Path
+		}
+	}
+	return query
+}
+
+// auditViewQuery builds the audit query of a view's filters
+func auditViewQuery(orgID string, filters types.LogViewFilters, startTime, endTime time.Time) *types.AuditLogQuery {
+	return &types.AuditLogQuery{
+		StartTime:      &startTime,
+		EndTime:        &endTime,
+		OrganizationID: orgID,
+		UserID:         filters.UserID,
+		Action:         filters.Action,
+		Resource:       filters.Resource,
+		ResourceID:     filters.ResourceID,
+		MinSeverity:    filters.MinSeverity,
+	}
+}
+
+// firstN returns at most the first n items
+func firstN[T any](items []T, n int) []T {
+	if len(items) > n {
+		return items[:n]
+	}
+	return items
+}
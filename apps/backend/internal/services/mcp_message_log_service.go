@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// ToolSchemaResolver looks up the input schema of a namespaced tool
+type ToolSchemaResolver interface {
+	ToolInputSchema(ctx context.Context, namespaceID, prefixedName string) (map[string]interface{}, bool)
+}
+
+// MCPMessageLogService records and queries message-level MCP traffic
+type MCPMessageLogService struct {
+	model   *models.MCPMessageLogModel
+	schemas ToolSchemaResolver
+}
+
+// NewMCPMessageLogService creates a new MCP message log service
+func NewMCPMessageLogService(db *sql.DB, schemas ToolSchemaResolver) *MCPMessageLogService {
+	return &MCPMessageLogService{
+		model:   models.NewMCPMessageLogModel(db),
+		schemas: schemas,
+	}
+}
+
+// RecordMessage redacts sensitive params and stores the message. Tool call
+// arguments are redacted against the tool's input schema when it is known.
+func (s *MCPMessageLogService) RecordMessage(ctx context.Context, entry *types.MCPMessageLog) error {
+	var schema map[string]interface{}
+	if entry.ToolName != "" && entry.NamespaceID != "" && s.schemas != nil {
+		schema, _ = s.schemas.ToolInputSchema(ctx, entry.NamespaceID, entry.ToolName)
+	}
+
+	entry.Params, entry.RedactedFields = RedactMessageParams(entry.Method, entry.Params, schema)
+	return s.model.Create(entry)
+}
+
+// ListMessages returns logged MCP messages for an organization
+func (s *MCPMessageLogService) ListMessages(ctx context.Context, orgID string, query *types.MCPMessageLogQuery) ([]*types.MCPMessageLog, error) {
+	return s.model.List(orgID, query)
+}
+
+// GetMessage returns a single logged MCP message
+func (s *MCPMessageLogService) GetMessage(ctx context.Context, orgID, id string) (*types.MCPMessageLog, error) {
+	entry, err := s.model.GetByID(orgID, id)
+	if err == sql.ErrNoRows {
+		return nil, types.NewNotFoundError("MCP message not found")
+	}
+	return entry, err
+}
+
+// recordedExampleLogs bounds how many tool calls are scanned for examples
+const recordedExampleLogs = 500
+
+// RecordedToolArguments returns the arguments of the latest successful call
+// to each tool of an endpoint, keyed by the called tool name
+func (s *MCPMessageLogService) RecordedToolArguments(ctx context.Context, orgID, endpointID string) (map[string]map[string]interface{}, error) {
+	logs, err := s.model.List(orgID, &types.MCPMessageLogQuery{
+		EndpointID: endpointID,
+		Method:     "tools/call",
+		Limit:      recordedExampleLogs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return LatestToolArguments(logs), nil
+}
+
+// LatestToolArguments picks example arguments per tool from logged calls,
+// newest first. Failed calls and calls with redacted arguments are skipped
+// so examples never carry masked or broken values.
+func LatestToolArguments(logs []*types.MCPMessageLog) map[string]map[string]interface{} {
+	examples := make(map[string]map[string]interface{})
+	for _, entry := range logs {
+		if entry.ToolName == "" || entry.IsError || len(entry.RedactedFields) > 0 {
+			continue
+		}
+		if _, seen := examples[entry.ToolName]; seen {
+			continue
+		}
+		if arguments, ok := entry.Params["arguments"].(map[string]interface{}); ok && len(arguments) > 0 {
+			examples[entry.ToolName] = arguments
+		}
+	}
+	return examples
+}
+
+// RedactMessageParams returns a redacted copy of JSON-RPC params and the
+// dotted paths that were masked. For tools/call the arguments are checked
+// against the tool input schema; everything else falls back to well-known
+// credential field names.
+func RedactMessageParams(method string, params, toolSchema map[string]interface{}) (map[string]interface{}, []string) {
+	if params == nil {
+		return nil, nil
+	}
+
+	var paths []string
+	redacted := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		if method == "tools/call" && key == "arguments" {
+			redacted[key] = redactValue(value, toolSchema, "arguments", &paths)
+			continue
+		}
+		if isSensitiveFieldName(key) {
+			redacted[key] = types.RedactedValue
+			paths = append(paths, key)
+			continue
+		}
+		redacted[key] = redactValue(value, nil, key, &paths)
+	}
+
+	sort.Strings(paths)
+	return redacted, paths
+}
+
+func redactValue(value interface{}, schema map[string]interface{}, path string, paths *[]string) interface{} {
+	if isSensitiveSchema(schema) {
+		*paths = append(*paths, path)
+		return types.RedactedValue
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+
+			childSchema, ok := properties[key].(map[string]interface{})
+			if !ok {
+				childSchema = additional
+			}
+			if childSchema == nil && isSensitiveFieldName(key) {
+				*paths = append(*paths, childPath)
+				out[key] = types.RedactedValue
+				continue
+			}
+			out[key] = redactValue(child, childSchema, childPath, paths)
+		}
+		return out
+
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = redactValue(child, items, path+"[]", paths)
+		}
+		return out
+
+	default:
+		return value
+	}
+}
+
+// isSensitiveSchema reports whether a JSON schema marks its value as sensitive
+func isSensitiveSchema(schema map[string]interface{}) bool {
+	if schema == nil {
+		return false
+	}
+	if sensitive, _ := schema["x-sensitive"].(bool); sensitive {
+		return true
+	}
+	if writeOnly, _ := schema["writeOnly"].(bool); writeOnly {
+		return true
+	}
+	format, _ := schema["format"].(string)
+	return format == "password"
+}
+
+var sensitiveFieldNames = map[string]bool{
+	"password":      true,
+	"passwd":        true,
+	"secret":        true,
+	"client_secret": true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"api_key":       true,
+	"apikey":        true,
+	"authorization": true,
+	"private_key":   true,
+}
+
+// isSensitiveFieldName matches well-known credential field names used when
+// no schema is available
+func isSensitiveFieldName(name string) bool {
+	normalized := strings.ReplaceAll(strings.ToLower(name), "-", "_")
+	return sensitiveFieldNames[normalized]
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+)
+
+// DefaultMeteringFlushInterval is how often metered usage is written
+const DefaultMeteringFlushInterval = 10 * time.Second
+
+// UsageMeterStore persists metered usage
+type UsageMeterStore interface {
+	AddUsage(orgID, dimension string, period time.Time, quantity int64) error
+}
+
+// MeteringService counts organizations' usage of metered dimensions. Usage
+// is added up in memory and written every flush interval, so tool calls do
+// not wait on the database.
+type MeteringService struct {
+	store    UsageMeterStore
+	now      func() time.Time
+	pending  map[meterKey]int64
+	stopCh   chan struct{}
+	interval time.Duration
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	running  bool
+}
+
+type meterKey struct {
+	period    time.Time
+	orgID     string
+	dimension string
+}
+
+// NewMeteringService creates a database-backed metering service
+func NewMeteringService(db *sql.DB, interval time.Duration) *MeteringService {
+	return NewMeteringServiceWithStore(models.NewUsageBudgetModel(db), interval)
+}
+
+// NewMeteringServiceWithStore creates a metering service over store; a
+// zero interval takes the default
+func NewMeteringServiceWithStore(store UsageMeterStore, interval time.Duration) *MeteringService {
+	if interval <= 0 {
+		interval = DefaultMeteringFlushInterval
+	}
+	return &MeteringService{
+		store:    store,
+		now:      time.Now,
+		pending:  make(map[meterKey]int64),
+		stopCh:   make(chan struct{}),
+		interval: interval,
+	}
+}
+
+// SetClock replaces the clock used to pick the month usage counts towards
+func (m *MeteringService) SetClock(now func() time.Time) {
+	m.now = now
+}
+
+// Record adds an organization's usage, by dimension, to the current month
+func (m *MeteringService) Record(orgID string, usage map[string]int64) {
+	if orgID == "" {
+		return
+	}
+	period := budgetPeriod(m.now())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for dimension, quantity := range usage {
+		if quantity > 0 {
+			m.pending[meterKey{orgID: orgID, dimension: dimension, period: period}] += quantity
+		}
+	}
+}
+
+// Flush writes the usage recorded since the last flush. Usage that fails to
+// be written is kept for the next flush.
+func (m *MeteringService) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = make(map[meterKey]int64)
+	m.mu.Unlock()
+
+	var firstErr error
+	for key, quantity := range pending {
+		if err := m.store.AddUsage(key.orgID, key.dimension, key.period, quantity); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			m.mu.Lock()
+			m.pending[key] += quantity
+			m.mu.Unlock()
+		}
+	}
+	return firstErr
+}
+
+// Start flushes usage every interval until Stop
+func (m *MeteringService) Start() {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = true
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.Flush(context.Background()); err != nil {
+					log.Printf("Error writing metered usage: %v", err)
+				}
+			case <-m.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the flush loop and writes the usage still pending
+func (m *MeteringService) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	m.mu.Unlock()
+
+	close(m.stopCh)
+	m.wg.Wait()
+	if err := m.Flush(context.Background()); err != nil {
+		log.Printf("Error writing metered usage: %v", err)
+	}
+}
+
+// budgetPeriod returns the first day of the UTC month of t
+func budgetPeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
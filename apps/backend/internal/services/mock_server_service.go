@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mcp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// MockToolStore persists the canned tools of mock MCP servers
+type MockToolStore interface {
+	MockToolSource
+	ServerProtocol(orgID, serverID string) (string, error)
+	Get(serverID, id string) (*types.MockTool, error)
+	Create(tool *types.MockTool) error
+	Update(tool *types.MockTool) error
+	Delete(serverID, id string) (bool, error)
+}
+
+// MockToolSource lists the canned tools of a mock server
+type MockToolSource interface {
+	List(serverID string) ([]*types.MockTool, error)
+}
+
+// MockToolLoader loads the canned tools a mock server answers with
+type MockToolLoader interface {
+	LoadMockTools(ctx context.Context, serverID string) ([]types.MockTool, error)
+}
+
+// ToolRefresher rediscovers the tools of a server after they changed
+type ToolRefresher interface {
+	RefreshServerTools(ctx context.Context, serverID string) error
+}
+
+// MockServerService manages the canned tools of mock MCP servers, which the
+// gateway answers itself so that frontends and agents can be developed
+// against stable fixtures
+type MockServerService struct {
+	store MockToolStore
+	tools ToolRefresher
+}
+
+// NewMockServerService creates a database-backed mock server service
+func NewMockServerService(db *sql.DB) *MockServerService {
+	return NewMockServerServiceWithStore(models.NewMockToolModel(db))
+}
+
+// NewMockServerServiceWithStore creates a mock server service over store
+func NewMockServerServiceWithStore(store MockToolStore) *MockServerService {
+	return &MockServerService{store: store}
+}
+
+// SetToolRefresher makes changes to mock tools update the server's
+// discovered tools
+func (s *MockServerService) SetToolRefresher(tools ToolRefresher) {
+	s.tools = tools
+}
+
+// ListMockTools returns the canned tools of a mock server
+func (s *MockServerService) ListMockTools(ctx context.Context, orgID, serverID string) ([]*types.MockTool, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	tools, err := s.store.List(serverID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list mock tools: " + err.Error())
+	}
+	return tools, nil
+}
+
+// GetMockTool returns a canned tool of a mock server
+func (s *MockServerService) GetMockTool(ctx context.Context, orgID, serverID, id string) (*types.MockTool, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	tool, err := s.store.Get(serverID, id)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get mock tool: " + err.Error())
+	}
+	if tool == nil {
+		return nil, types.NewNotFoundError("Mock tool not found")
+	}
+	return tool, nil
+}
+
+// CreateMockTool adds a canned tool to a mock server
+func (s *MockServerService) CreateMockTool(ctx context.Context, orgID, serverID string, req *types.CreateMockToolRequest) (*types.MockTool, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+
+	tool := &types.MockTool{
+		ServerID:    serverID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		InputSchema: req.InputSchema,
+		Response:    req.Response,
+		IsError:     req.IsError,
+		DelayMs:     req.DelayMs,
+	}
+	if err := s.validateTool(tool); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Create(tool); err != nil {
+		return nil, types.NewInternalError("Failed to create mock tool: " + err.Error())
+	}
+	s.refreshTools(ctx, serverID)
+	return tool, nil
+}
+
+// UpdateMockTool changes a canned tool of a mock server
+func (s *MockServerService) UpdateMockTool(ctx context.Context, orgID, serverID, id string, req *types.UpdateMockToolRequest) (*types.MockTool, error) {
+	tool, err := s.GetMockTool(ctx, orgID, serverID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		tool.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Description != nil {
+		tool.Description = *req.Description
+	}
+	if req.InputSchema != nil {
+		tool.InputSchema = req.InputSchema
+	}
+	if req.Response != nil {
+		tool.Response = *req.Response
+	}
+	if req.IsError != nil {
+		tool.IsError = *req.IsError
+	}
+	if req.DelayMs != nil {
+		tool.DelayMs = *req.DelayMs
+	}
+	if err := s.validateTool(tool); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Update(tool); err != nil {
+		return nil, types.NewInternalError("Failed to update mock tool: " + err.Error())
+	}
+	s.refreshTools(ctx, serverID)
+	return tool, nil
+}
+
+// DeleteMockTool removes a canned tool from a mock server
+func (s *MockServerService) DeleteMockTool(ctx context.Context, orgID, serverID, id string) error {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return err
+	}
+	deleted, err := s.store.Delete(serverID, id)
+	if err != nil {
+		return types.NewInternalError("Failed to delete mock tool: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Mock tool not found")
+	}
+	s.refreshTools(ctx, serverID)
+	return nil
+}
+
+// LoadMockTools returns the canned tools a mock server answers with
+func (s *MockServerService) LoadMockTools(ctx context.Context, serverID string) ([]types.MockTool, error) {
+	tools, err := s.store.List(serverID)
+	if err != nil {
+		return nil, err
+	}
+	loaded := make([]types.MockTool, 0, len(tools))
+	for _, tool := range tools {
+		loaded = append(loaded, *tool)
+	}
+	return loaded, nil
+}
+
+// checkServer returns an error unless serverID is a mock server of the
+// organization
+func (s *MockServerService) checkServer(orgID, serverID string) error {
+	protocol, err := s.store.ServerProtocol(orgID, serverID)
+	if err != nil {
+		return types.NewInternalError("Failed to get server: " + err.Error())
+	}
+	if protocol == "" {
+		return types.NewNotFoundError("Server not found")
+	}
+	if protocol != types.ProtocolMock {
+		return types.NewValidationError("Server is not a mock server")
+	}
+	return nil
+}
+
+func (s *MockServerService) validateTool(tool *types.MockTool) error {
+	if tool.Name == "" {
+		return types.NewValidationError("name is required")
+	}
+	if tool.DelayMs < 0 || tool.DelayMs > types.MaxMockToolDelay {
+		return types.NewValidationError("delay_ms must be between 0 and 60000")
+	}
+	if tool.InputSchema == nil {
+		tool.InputSchema = map[string]interface{}{"type": "object"}
+	}
+	if _, err := mcp.ParseMockResponse(tool.Response); err != nil {
+		return types.NewValidationError(err.Error())
+	}
+
+	existing, err := s.store.List(tool.ServerID)
+	if err != nil {
+		return types.NewInternalError("Failed to list mock tools: " + err.Error())
+	}
+	for _, other := range existing {
+		if other.ID != tool.ID && strings.EqualFold(other.Name, tool.Name) {
+			return types.NewConflictError("A mock tool with this name already exists on this server")
+		}
+	}
+	return nil
+}
+
+// refreshTools updates the server's discovered tools after its mock tools
+// changed
+func (s *MockServerService) refreshTools(ctx context.Context, serverID string) {
+	if s.tools == nil {
+		return
+	}
+	if err := s.tools.RefreshServerTools(ctx, serverID); err != nil {
+		log.Printf("Warning: failed to refresh tools of mock server %s: %v", serverID, err)
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// defaultNamespaceGrantCacheTTL bounds how long another gateway instance may
+// keep enforcing grants that were changed elsewhere
+const defaultNamespaceGrantCacheTTL = 30 * time.Second
+
+// NamespaceGrantStore persists namespace grants
+type NamespaceGrantStore interface {
+	ListForNamespace(namespaceID string) ([]*types.NamespaceGrant, error)
+	ListForOrganization(orgID string) ([]*types.NamespaceGrant, error)
+	Upsert(grant *types.NamespaceGrant) error
+	Delete(namespaceID, id string) (bool, error)
+	NamespaceOrganization(namespaceID string) (string, error)
+	UserOrganization(userID string) (string, error)
+	TeamOrganization(teamID string) (string, error)
+	UserTeams(userID string) ([]string, error)
+	EndpointNamespace(endpointID string) (string, error)
+}
+
+// NamespaceAccessService manages namespace grants and decides whether a
+// caller may use a namespace. Namespaces without grants are open to every
+// member of the organization, as governed by their role; once a namespace
+// has grants only its grantees and organization admins can use it. Expired
+// grants keep restricting their namespace without admitting anyone.
+type NamespaceAccessService struct {
+	store    NamespaceGrantStore
+	cache    map[string]*namespaceGrantCacheEntry
+	now      func() time.Time
+	cacheTTL time.Duration
+	mu       sync.Mutex
+}
+
+type namespaceGrantCacheEntry struct {
+	expires time.Time
+	// grants by namespace ID
+	grants map[string][]*types.NamespaceGrant
+}
+
+// NewNamespaceAccessService creates a database-backed namespace access
+// service
+func NewNamespaceAccessService(db *sql.DB) *NamespaceAccessService {
+	return NewNamespaceAccessServiceWithStore(models.NewNamespaceGrantModel(db), defaultNamespaceGrantCacheTTL)
+}
+
+// NewNamespaceAccessServiceWithStore creates a namespace access service over
+// store, caching each organization's grants for cacheTTL
+func NewNamespaceAccessServiceWithStore(store NamespaceGrantStore, cacheTTL time.Duration) *NamespaceAccessService {
+	return &NamespaceAccessService{
+		store:    store,
+		cache:    make(map[string]*namespaceGrantCacheEntry),
+		now:      time.Now,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// ListGrants returns the grants on a namespace of the organization
+func (s *NamespaceAccessService) ListGrants(ctx context.Context, orgID, namespaceID string) ([]*types.NamespaceGrant, error) {
+	if err := s.checkNamespace(orgID, namespaceID); err != nil {
+		return nil, err
+	}
+	grants, err := s.store.ListForNamespace(namespaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespace grants: %w", err)
+	}
+	return grants, nil
+}
+
+// Grant gives a user, team or role access to a namespace of the organization
+func (s *NamespaceAccessService) Grant(ctx context.Context, orgID, namespaceID, createdBy string, req *types.CreateNamespaceGrantRequest) (*types.NamespaceGrant, error) {
+	if err := s.checkNamespace(orgID, namespaceID); err != nil {
+		return nil, err
+	}
+
+	switch req.SubjectType {
+	case types.NamespaceGrantSubjectUser:
+		if _, err := uuid.Parse(req.SubjectID); err != nil {
+			return nil, types.NewValidationError("subject_id must be a user ID")
+		}
+		userOrg, err := s.store.UserOrganization(req.SubjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if userOrg != orgID {
+			return nil, types.NewValidationError("user is not a member of the organization")
+		}
+	case types.NamespaceGrantSubjectTeam:
+		if _, err := uuid.Parse(req.SubjectID); err != nil {
+			return nil, types.NewValidationError("subject_id must be a team ID")
+		}
+		teamOrg, err := s.store.TeamOrganization(req.SubjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get team: %w", err)
+		}
+		if teamOrg != orgID {
+			return nil, types.NewValidationError("team does not belong to the organization")
+		}
+	case types.NamespaceGrantSubjectRole:
+		switch req.SubjectID {
+		case types.RoleUser, types.RoleViewer, types.RoleAPIUser, types.RoleService:
+		case types.RoleAdmin:
+			return nil, types.NewValidationError("admins always have access to every namespace")
+		default:
+			return nil, types.NewValidationError("unknown role " + req.SubjectID)
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.now()) {
+		return nil, types.NewValidationError("expires_at must be in the future")
+	}
+
+	grant := &types.NamespaceGrant{
+		OrganizationID: orgID,
+		NamespaceID:    namespaceID,
+		SubjectType:    req.SubjectType,
+		SubjectID:      req.SubjectID,
+		AccessLevel:    req.AccessLevel,
+		CreatedBy:      createdBy,
+		ExpiresAt:      req.ExpiresAt,
+	}
+	if err := s.store.Upsert(grant); err != nil {
+		return nil, fmt.Errorf("failed to save namespace grant: %w", err)
+	}
+	s.invalidate(orgID)
+	return grant, nil
+}
+
+// Revoke removes a grant from a namespace of the organization. Revoking the
+// last grant opens the namespace to the whole organization again.
+func (s *NamespaceAccessService) Revoke(ctx context.Context, orgID, namespaceID, grantID string) error {
+	if err := s.checkNamespace(orgID, namespaceID); err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(grantID); err != nil {
+		return types.NewNotFoundError("Namespace grant not found")
+	}
+	deleted, err := s.store.Delete(namespaceID, grantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete namespace grant: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("Namespace grant not found")
+	}
+	s.invalidate(orgID)
+	return nil
+}
+
+// Allowed reports whether subject has at least level access to a namespace
+func (s *NamespaceAccessService) Allowed(ctx context.Context, subject *types.NamespaceSubject, namespaceID, level string) (bool, error) {
+	if subject.Role == types.RoleAdmin {
+		return true, nil
+	}
+
+	grants, err := s.organizationGrants(subject.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+	restrictions := grants[namespaceID]
+	if len(restrictions) == 0 {
+		return true, nil
+	}
+	if subject.TeamIDs == nil && subject.UserID != "" && hasTeamGrant(restrictions) {
+		teamIDs, err := s.store.UserTeams(subject.UserID)
+		if err != nil {
+			return false, fmt.Errorf("failed to list user teams: %w", err)
+		}
+		withTeams := *subject
+		withTeams.TeamIDs = teamIDs
+		subject = &withTeams
+	}
+	for _, grant := range restrictions {
+		if grant.Active(s.now()) && grant.Matches(subject) && types.NamespaceAccessAllows(grant.AccessLevel, level) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func hasTeamGrant(grants []*types.NamespaceGrant) bool {
+	for _, grant := range grants {
+		if grant.SubjectType == types.NamespaceGrantSubjectTeam {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckNamespace returns a forbidden error unless subject has at least level
+// access to a namespace
+func (s *NamespaceAccessService) CheckNamespace(ctx context.Context, subject *types.NamespaceSubject, namespaceID, level string) error {
+	allowed, err := s.Allowed(ctx, subject, namespaceID, level)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return types.NewForbiddenError(fmt.Sprintf("%s access to this namespace has not been granted", level))
+	}
+	return nil
+}
+
+// CheckEndpoint returns a forbidden error unless subject has at least level
+// access to the namespace an endpoint serves. Unknown endpoints are left for
+// the handler to report.
+func (s *NamespaceAccessService) CheckEndpoint(ctx context.Context, subject *types.NamespaceSubject, endpointID, level string) error {
+	if subject.Role == types.RoleAdmin {
+		return nil
+	}
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return nil
+	}
+	namespaceID, err := s.store.EndpointNamespace(endpointID)
+	if err != nil {
+		return fmt.Errorf("failed to get endpoint namespace: %w", err)
+	}
+	if namespaceID == "" {
+		return nil
+	}
+	return s.CheckNamespace(ctx, subject, namespaceID, level)
+}
+
+// organizationGrants returns the organization's grants by namespace
+func (s *NamespaceAccessService) organizationGrants(orgID string) (map[string][]*types.NamespaceGrant, error) {
+	s.mu.Lock()
+	entry, ok := s.cache[orgID]
+	s.mu.Unlock()
+	if ok && s.now().Before(entry.expires) {
+		return entry.grants, nil
+	}
+
+	grants, err := s.store.ListForOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespace grants: %w", err)
+	}
+	byNamespace := make(map[string][]*types.NamespaceGrant)
+	for _, grant := range grants {
+		byNamespace[grant.NamespaceID] = append(byNamespace[grant.NamespaceID], grant)
+	}
+
+	s.mu.Lock()
+	s.cache[orgID] = &namespaceGrantCacheEntry{grants: byNamespace, expires: s.now().Add(s.cacheTTL)}
+	s.mu.Unlock()
+	return byNamespace, nil
+}
+
+func (s *NamespaceAccessService) invalidate(orgID string) {
+	s.mu.Lock()
+	delete(s.cache, orgID)
+	s.mu.Unlock()
+}
+
+// checkNamespace returns a not found error unless the namespace belongs to
+// the organization
+func (s *NamespaceAccessService) checkNamespace(orgID, namespaceID string) error {
+	if _, err := uuid.Parse(namespaceID); err != nil {
+		return types.NewNotFoundError("Namespace not found")
+	}
+	namespaceOrg, err := s.store.NamespaceOrganization(namespaceID)
+	if err != nil {
+		return fmt.Errorf("failed to get namespace: %w", err)
+	}
+	if namespaceOrg == "" || namespaceOrg != orgID {
+		return types.NewNotFoundError("Namespace not found")
+	}
+	return nil
+}
@@ -3,15 +3,34 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/commandpolicy"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/repositories"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/jsonpath"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/jsonschema"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mcp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/residency"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/retry"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/scheduler"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/serverlogs"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/toolpolicy"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // NamespaceService handles namespace operations
@@ -20,7 +39,93 @@ type NamespaceService struct {
 	serverRepo      *repositories.MCPServerRepository
 	sessionPool     *NamespaceSessionPool
 	endpointService *EndpointService
+	execLogger      ToolExecutionLogger
+	commandPolicy   *commandpolicy.Policy
+	scheduler       *scheduler.Scheduler
+	priorities      scheduler.ClassResolver
+	residency       *residency.Policy
+	serverLogs      *serverlogs.Capture
+	replicas        ReplicaPicker
+	callObserver    ServerCallObserver
+	mockTools       MockToolLoader
+	recordings      RecordingProvider
+	retrier         *retry.Retrier
+	toolPolicy      ToolPolicyChecker
+	approvals       ToolApprover
+	headers         UpstreamHeaderResolver
+	identity        IdentityInjector
+	dlp             OutboundDLP
+	toolLimits      ToolLimitChecker
+	budgets         BudgetGuard
+	meter           UsageMeter
+	emitMetric      func(metric *types.Metric)
 	toolPrefixCache sync.Map // Cache for prefixed tool names
+	validateInputs  bool
+	validateOutputs bool
+}
+
+// ToolExecutionLogger records tool executions attributed to the calling principal
+type ToolExecutionLogger interface {
+	LogToolExecution(ctx context.Context, record *logging.ToolExecutionRecord) error
+}
+
+// ReplicaPicker chooses the replica of a server group that serves a call to
+// one of the group's servers, and returns a function to call once the call
+// is done
+type ReplicaPicker interface {
+	PickReplica(ctx context.Context, serverID string, allow func(region string) bool) (string, func(), error)
+}
+
+// ServerCallObserver is told the outcome of every attempted call to an
+// upstream server
+type ServerCallObserver interface {
+	ObserveServerCall(serverID string, latency time.Duration, failed bool)
+}
+
+// ToolPolicyChecker decides whether a tool call of an organization may run
+type ToolPolicyChecker interface {
+	CheckToolCall(ctx context.Context, orgID string, call *toolpolicy.Call) (*types.ToolPolicyDecision, error)
+}
+
+// ToolApprover holds a tool call until an approver decides it and returns
+// the approval in its final status
+type ToolApprover interface {
+	AwaitApproval(ctx context.Context, orgID string, call *toolpolicy.Call, decision *types.ToolPolicyDecision) (*types.ToolApproval, error)
+}
+
+// UpstreamHeaderResolver decides which headers a call sends to the
+// upstream server
+type UpstreamHeaderResolver interface {
+	UpstreamHeaders(ctx context.Context, namespaceID, serverID, serverName string) (http.Header, error)
+}
+
+// IdentityInjector adds the caller's identity to tool calls of servers that
+// receive it
+type IdentityInjector interface {
+	InjectIdentity(ctx context.Context, namespaceID, serverID string, args map[string]interface{}) (map[string]interface{}, map[string]interface{}, error)
+}
+
+// OutboundDLP checks tool arguments for data more sensitive than their
+// server may receive, returning the arguments to send
+type OutboundDLP interface {
+	CheckArguments(ctx context.Context, orgID, namespaceID, serverID, tool string, args map[string]interface{}) (map[string]interface{}, error)
+}
+
+// ToolLimitChecker counts a call against the rate limit and daily budget of
+// the tool a server exposes under name
+type ToolLimitChecker interface {
+	CheckToolLimits(ctx context.Context, serverID, name, tool string) error
+}
+
+// BudgetGuard refuses tool calls in a namespace once the organization has
+// spent a budget that stops them
+type BudgetGuard interface {
+	CheckBudget(ctx context.Context, orgID, namespaceID string) error
+}
+
+// UsageMeter counts an organization's usage of metered dimensions
+type UsageMeter interface {
+	Record(orgID string, usage map[string]int64)
 }
 
 // NewNamespaceService creates a new namespace service
@@ -36,6 +141,140 @@ func NewNamespaceService(db *sql.DB, endpointService *EndpointService) *Namespac
 	}
 }
 
+// SetExecutionLogger configures where tool executions are recorded
+func (s *NamespaceService) SetExecutionLogger(logger ToolExecutionLogger) {
+	s.execLogger = logger
+}
+
+// SetToolPolicy checks every tool call against the organization's tool
+// policies before it reaches the upstream server
+func (s *NamespaceService) SetToolPolicy(checker ToolPolicyChecker) {
+	s.toolPolicy = checker
+}
+
+// SetApprovals makes calls a policy holds for approval wait for an
+// approver's decision instead of failing at once
+func (s *NamespaceService) SetApprovals(approver ToolApprover) {
+	s.approvals = approver
+}
+
+// SetHeaderPropagation makes calls carry the caller headers the namespace
+// and server propagate to upstream HTTP requests
+func (s *NamespaceService) SetHeaderPropagation(resolver UpstreamHeaderResolver) {
+	s.headers = resolver
+}
+
+// SetIdentityInjection makes tool calls carry the caller's identity to
+// servers configured to receive it
+func (s *NamespaceService) SetIdentityInjection(injector IdentityInjector) {
+	s.identity = injector
+}
+
+// SetDLP checks the arguments of tool calls against the sensitivity label
+// of their server before they leave the gateway
+func (s *NamespaceService) SetDLP(checker OutboundDLP) {
+	s.dlp = checker
+}
+
+// SetToolLimits enforces per-tool rate limits and daily budgets on calls
+func (s *NamespaceService) SetToolLimits(checker ToolLimitChecker) {
+	s.toolLimits = checker
+}
+
+// SetBudgets refuses tool calls the organization's usage budgets stop
+func (s *NamespaceService) SetBudgets(guard BudgetGuard) {
+	s.budgets = guard
+}
+
+// SetUsageMeter meters the executions, tokens and egress bytes of tool
+// calls that ran
+func (s *NamespaceService) SetUsageMeter(meter UsageMeter) {
+	s.meter = meter
+}
+
+// SetCommandPolicy restricts which commands STDIO namespace servers may launch
+func (s *NamespaceService) SetCommandPolicy(policy *commandpolicy.Policy) {
+	s.commandPolicy = policy
+}
+
+// SetScheduler queues tool executions by the calling organization's priority class
+func (s *NamespaceService) SetScheduler(sched *scheduler.Scheduler, priorities scheduler.ClassResolver) {
+	s.scheduler = sched
+	s.priorities = priorities
+}
+
+// SetMetricEmitter counts calls to upstream servers, and calls failing
+// schema validation, through emit
+func (s *NamespaceService) SetMetricEmitter(emit func(metric *types.Metric)) {
+	s.emitMetric = emit
+}
+
+// SetSchemaValidation checks tool arguments, and optionally results, against
+// the JSON Schemas the tools declare. Calls that fail are counted per tool
+// through emit.
+func (s *NamespaceService) SetSchemaValidation(inputs, outputs bool, emit func(metric *types.Metric)) {
+	s.validateInputs = inputs
+	s.validateOutputs = outputs
+	s.emitMetric = emit
+}
+
+// SetResidencyPolicy keeps traffic of namespaces pinned to a region on
+// servers and storage in that region
+func (s *NamespaceService) SetResidencyPolicy(policy *residency.Policy) {
+	s.residency = policy
+}
+
+// SetServerLogs captures the output of STDIO servers launched for namespaces
+func (s *NamespaceService) SetServerLogs(capture *serverlogs.Capture) {
+	s.serverLogs = capture
+}
+
+// SetReplicaPicker routes tool calls to servers in a server group to a
+// healthy replica of the group
+func (s *NamespaceService) SetReplicaPicker(replicas ReplicaPicker) {
+	s.replicas = replicas
+}
+
+// SetServerCallObserver reports the outcome of calls to upstream servers,
+// which health scoring uses to steer calls away from impacted replicas
+func (s *NamespaceService) SetServerCallObserver(observer ServerCallObserver) {
+	s.callObserver = observer
+}
+
+// SetMockTools serves mock servers from their canned tools
+func (s *NamespaceService) SetMockTools(mockTools MockToolLoader) {
+	s.mockTools = mockTools
+}
+
+// RecordingProvider tells which servers record their responses or are
+// replayed from recordings, and keeps the recordings
+type RecordingProvider interface {
+	mcp.RecordingStore
+	RecordingMode(ctx context.Context, serverID string) (string, error)
+}
+
+// SetRecordings records the responses of servers in record mode and answers
+// servers in replay mode from their recordings
+func (s *NamespaceService) SetRecordings(recordings RecordingProvider) {
+	s.recordings = recordings
+}
+
+// SetRetrier retries failed tool calls and listings that are safe to repeat
+func (s *NamespaceService) SetRetrier(retrier *retry.Retrier) {
+	s.retrier = retrier
+}
+
+// ResetServerSessions closes the open sessions of a server in every
+// namespace so that the next call reconnects
+func (s *NamespaceService) ResetServerSessions(serverID string) {
+	for namespaceID, sessions := range s.sessionPool.GetAllSessions() {
+		if _, ok := sessions[serverID]; ok {
+			s.sessionPool.ClearServer(namespaceID, serverID)
+			s.clearToolCache(namespaceID)
+		}
+	}
+}
+
 // CreateNamespace creates a new namespace
 func (s *NamespaceService) CreateNamespace(ctx context.Context, req types.CreateNamespaceRequest) (*types.Namespace, error) {
 	// Validate namespace name
@@ -49,11 +288,19 @@ func (s *NamespaceService) CreateNamespace(ctx context.Context, req types.Create
 		return nil, fmt.Errorf("namespace with name %s already exists", req.Name)
 	}
 
+	if err := types.ValidateRegion(req.Region); err != nil {
+		return nil, types.NewValidationError(err.Error())
+	}
+	if err := s.checkServerRegions(ctx, req.Region, req.Servers); err != nil {
+		return nil, err
+	}
+
 	// Create namespace
 	namespace := &types.Namespace{
 		OrganizationID: req.OrganizationID,
 		Name:           req.Name,
 		Description:    req.Description,
+		Region:         req.Region,
 		CreatedBy:      req.CreatedBy,
 		IsActive:       true,
 		Metadata:       req.Metadata,
@@ -135,6 +382,30 @@ func (s *NamespaceService) UpdateNamespace(ctx context.Context, id string, req t
 		namespace.Metadata = req.Metadata
 	}
 
+	if req.Region != nil {
+		if err := types.ValidateRegion(*req.Region); err != nil {
+			return nil, types.NewValidationError(err.Error())
+		}
+		namespace.Region = *req.Region
+	}
+
+	// Every server the namespace will route to must satisfy its region
+	if namespace.Region != "" && (req.Region != nil || req.ServerIDs != nil) {
+		serverIDs := req.ServerIDs
+		if serverIDs == nil {
+			current, err := s.repo.GetServers(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get current servers: %w", err)
+			}
+			for _, server := range current {
+				serverIDs = append(serverIDs, server.ServerID)
+			}
+		}
+		if err := s.checkServerRegions(ctx, namespace.Region, serverIDs); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.repo.Update(ctx, namespace); err != nil {
 		return nil, err
 	}
@@ -213,14 +484,34 @@ func (s *NamespaceService) DeleteNamespace(ctx context.Context, id string) error
 	return s.repo.Delete(ctx, id)
 }
 
+// ListNamespaceServers returns the servers of an organization's namespace
+func (s *NamespaceService) ListNamespaceServers(ctx context.Context, orgID, namespaceID string) ([]types.NamespaceServer, error) {
+	namespace, err := s.repo.GetByID(ctx, namespaceID)
+	if err != nil {
+		return nil, err
+	}
+	if namespace.OrganizationID != orgID {
+		return nil, types.NewNotFoundError("namespace not found")
+	}
+	return s.repo.GetServers(ctx, namespaceID)
+}
+
 // AddServerToNamespace adds a server to a namespace
 func (s *NamespaceService) AddServerToNamespace(ctx context.Context, namespaceID string, req types.AddServerToNamespaceRequest) error {
 	// Verify server exists
-	_, err := s.serverRepo.GetByID(ctx, req.ServerID)
+	server, err := s.serverRepo.GetByID(ctx, req.ServerID)
 	if err != nil {
 		return fmt.Errorf("server not found: %w", err)
 	}
 
+	namespace, err := s.repo.GetByID(ctx, namespaceID)
+	if err != nil {
+		return err
+	}
+	if err := s.residency.CheckServer(namespace.Region, server.Name, server.Region); err != nil {
+		return err
+	}
+
 	// Add server to namespace
 	if err := s.repo.AddServer(ctx, namespaceID, req.ServerID, req.Priority); err != nil {
 		return err
@@ -273,7 +564,7 @@ func (s *NamespaceService) AggregateTools(ctx context.Context, namespaceID strin
 	}
 
 	// Get servers in namespace
-	servers, err := s.repo.GetServers(ctx, namespaceID)
+	namespace, err := s.repo.GetByIDWithServers(ctx, namespaceID)
 	if err != nil {
 		return nil, err
 	}
@@ -283,10 +574,14 @@ func (s *NamespaceService) AggregateTools(ctx context.Context, namespaceID strin
 	var wg sync.WaitGroup
 
 	// Fetch tools from each active server in parallel
-	for _, server := range servers {
+	for _, server := range namespace.Servers {
 		if server.Status != string(types.NamespaceStatusActive) {
 			continue
 		}
+		if !s.residency.Allows(namespace.Region, server.Region) {
+			fmt.Printf("Warning: skipping server %s outside the region %s of namespace %s\n", server.ServerName, namespace.Region, namespaceID)
+			continue
+		}
 
 		wg.Add(1)
 		go func(srv types.NamespaceServer) {
@@ -300,7 +595,14 @@ func (s *NamespaceService) AggregateTools(ctx context.Context, namespaceID strin
 			}
 
 			// Get tools from server
-			serverTools, err := s.getServerTools(ctx, session, srv.ServerID)
+			var serverTools []types.Tool
+			_, err = s.retrier.Do(ctx, s.retryCall(ctx, session, "tools/list", ""), func(ctx context.Context) error {
+				return permanentCallError(s.callServer(ctx, srv.ServerID, "tools/list", func(ctx context.Context) error {
+					var err error
+					serverTools, err = s.getServerTools(ctx, session, srv.ServerID)
+					return err
+				}))
+			})
 			if err != nil {
 				fmt.Printf("Warning: failed to get tools from server %s: %v\n", srv.ServerID, err)
 				return
@@ -316,6 +618,7 @@ func (s *NamespaceService) AggregateTools(ctx context.Context, namespaceID strin
 					PrefixedName: PrefixToolName(srv.ServerName, tool.Name),
 					Status:       string(types.NamespaceStatusActive),
 					Description:  tool.Description,
+					InputSchema:  tool.InputSchema,
 				}
 				tools = append(tools, prefixedTool)
 			}
@@ -330,18 +633,23 @@ func (s *NamespaceService) AggregateTools(ctx context.Context, namespaceID strin
 	if err == nil {
 		// Create a map for quick lookup
 		inactiveTools := make(map[string]bool)
+		templates := make(map[string]*types.ToolArgumentTemplate)
 		for _, mapping := range toolMappings {
+			key := fmt.Sprintf("%s:%s", mapping.ServerID, mapping.ToolName)
 			if mapping.Status == string(types.NamespaceStatusInactive) {
-				key := fmt.Sprintf("%s:%s", mapping.ServerID, mapping.ToolName)
 				inactiveTools[key] = true
 			}
+			if mapping.Arguments != nil {
+				templates[key] = mapping.Arguments
+			}
 		}
 
-		// Filter out inactive tools
+		// Filter out inactive tools and attach argument templates
 		var filteredTools []types.NamespaceTool
 		for _, tool := range tools {
 			key := fmt.Sprintf("%s:%s", tool.ServerID, tool.ToolName)
 			if !inactiveTools[key] {
+				tool.Arguments = templates[key]
 				filteredTools = append(filteredTools, tool)
 			}
 		}
@@ -354,8 +662,189 @@ func (s *NamespaceService) AggregateTools(ctx context.Context, namespaceID strin
 	return tools, nil
 }
 
-// ExecuteTool executes a tool in the namespace
-func (s *NamespaceService) ExecuteTool(ctx context.Context, namespaceID string, req types.ExecuteNamespaceToolRequest) (*types.NamespaceToolResult, error) {
+// ToolInputSchema returns the input schema of a prefixed tool in the namespace
+func (s *NamespaceService) ToolInputSchema(ctx context.Context, namespaceID, prefixedName string) (map[string]interface{}, bool) {
+	tool := s.findTool(ctx, namespaceID, prefixedName)
+	if tool == nil {
+		return nil, false
+	}
+	return tool.InputSchema, tool.InputSchema != nil
+}
+
+func (s *NamespaceService) findTool(ctx context.Context, namespaceID, prefixedName string) *types.NamespaceTool {
+	tools, err := s.AggregateTools(ctx, namespaceID)
+	if err != nil {
+		return nil
+	}
+	for i := range tools {
+		if tools[i].PrefixedName == prefixedName {
+			return &tools[i]
+		}
+	}
+	return nil
+}
+
+// ExecuteTool executes a tool in the namespace and records the execution
+// against the principal carried in ctx
+func (s *NamespaceService) ExecuteTool(ctx context.Context, namespaceID string, req types.ExecuteNamespaceToolRequest) (result *types.NamespaceToolResult, err error) {
+	ctx, span := observability.StartSpan(ctx, "namespace.execute_tool", trace.WithAttributes(
+		attribute.String("namespace.id", namespaceID),
+		attribute.String("mcp.tool.name", req.Tool),
+	))
+	defer func() {
+		if err == nil && result != nil && !result.Success {
+			span.SetStatus(codes.Error, result.Error)
+		}
+		observability.EndSpan(span, err)
+	}()
+
+	if s.scheduler != nil {
+		release, err := s.scheduler.Acquire(ctx, s.priorityClass(ctx, namespaceID))
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	namespace, err := s.repo.GetByID(ctx, namespaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	metered := !types.IsSandbox(ctx)
+	if metered && s.budgets != nil {
+		if err := s.budgets.CheckBudget(ctx, namespace.OrganizationID, namespaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	startedAt := time.Now()
+	result, err = s.executeTool(ctx, namespace, req)
+	if metered && err == nil && result != nil && result.Success && s.meter != nil {
+		s.meter.Record(namespace.OrganizationID, toolCallUsage(req.Arguments, result.Result))
+	}
+
+	if s.execLogger != nil {
+		record := &logging.ToolExecutionRecord{
+			StartedAt:   startedAt,
+			Principal:   types.PrincipalFromContext(ctx),
+			NamespaceID: namespaceID,
+			Tool:        req.Tool,
+			Duration:    time.Since(startedAt),
+			Sandbox:     types.IsSandbox(ctx),
+			Region:      namespace.Region,
+		}
+		switch {
+		case err != nil:
+			record.Error = err.Error()
+		case result != nil:
+			record.Success = result.Success
+			record.Error = result.Error
+		}
+		_ = s.execLogger.LogToolExecution(ctx, record)
+	}
+
+	return result, err
+}
+
+// toolCallUsage meters a tool call that ran: egress is the size of the
+// JSON result and tokens are estimated from the JSON of the arguments and
+// result at four bytes per token
+func toolCallUsage(args map[string]interface{}, result interface{}) map[string]int64 {
+	var argBytes, resultBytes int64
+	if len(args) > 0 {
+		if data, err := json.Marshal(args); err == nil {
+			argBytes = int64(len(data))
+		}
+	}
+	if result != nil {
+		if data, err := json.Marshal(result); err == nil {
+			resultBytes = int64(len(data))
+		}
+	}
+	return map[string]int64{
+		types.MeterExecutions:  1,
+		types.MeterTokens:      (argBytes + resultBytes + 3) / 4,
+		types.MeterEgressBytes: resultBytes,
+	}
+}
+
+// checkToolPolicy returns an error unless the organization's tool policies
+// allow the call. A failure to load the policies rejects the call. Calls
+// held for approval wait for a decision when approvals are configured.
+func (s *NamespaceService) checkToolPolicy(ctx context.Context, orgID string, call *toolpolicy.Call) error {
+	if s.toolPolicy == nil {
+		return nil
+	}
+
+	decision, err := s.toolPolicy.CheckToolCall(ctx, orgID, call)
+	if err != nil {
+		return types.NewServiceUnavailableError(fmt.Sprintf("tool policies could not be evaluated: %v", err))
+	}
+
+	message := decision.Message
+	switch decision.Effect {
+	case types.ToolPolicyEffectDeny:
+		if message == "" {
+			message = fmt.Sprintf("tool %s is denied by policy %s", call.Tool, decision.PolicyName)
+		}
+		violation := types.NewPolicyViolationError(message)
+		violation.Details = "policy " + decision.PolicyID
+		return violation
+	case types.ToolPolicyEffectRequireApproval:
+		if s.approvals != nil {
+			return s.awaitApproval(ctx, orgID, call, decision)
+		}
+		if message == "" {
+			message = fmt.Sprintf("tool %s requires approval under policy %s", call.Tool, decision.PolicyName)
+		}
+		return types.NewApprovalRequiredError(message, "policy "+decision.PolicyID)
+	}
+	return nil
+}
+
+// awaitApproval holds a call until an approver decides it and returns an
+// error unless it was approved
+func (s *NamespaceService) awaitApproval(ctx context.Context, orgID string, call *toolpolicy.Call, decision *types.ToolPolicyDecision) error {
+	approval, err := s.approvals.AwaitApproval(ctx, orgID, call, decision)
+	if err != nil {
+		return types.NewServiceUnavailableError(fmt.Sprintf("tool call could not be held for approval: %v", err))
+	}
+
+	switch approval.Status {
+	case types.ApprovalStatusApproved:
+		return nil
+	case types.ApprovalStatusRejected:
+		return types.NewApprovalRejectedError(approval.ID, approval.Reason)
+	default:
+		return types.NewApprovalExpiredError(approval.ID)
+	}
+}
+
+// priorityClass resolves the class of the calling organization, falling
+// back to the namespace owner for unauthenticated endpoint traffic. Sandbox
+// calls always queue behind production traffic.
+func (s *NamespaceService) priorityClass(ctx context.Context, namespaceID string) string {
+	if s.priorities == nil || types.IsSandbox(ctx) {
+		return types.PriorityClassFree
+	}
+
+	var orgID string
+	if principal := types.PrincipalFromContext(ctx); principal != nil {
+		orgID = principal.OrganizationID
+	}
+	if orgID == "" {
+		if namespace, err := s.repo.GetByID(ctx, namespaceID); err == nil && namespace != nil {
+			orgID = namespace.OrganizationID
+		}
+	}
+
+	return s.priorities.PriorityClass(ctx, orgID)
+}
+
+func (s *NamespaceService) executeTool(ctx context.Context, namespace *types.Namespace, req types.ExecuteNamespaceToolRequest) (*types.NamespaceToolResult, error) {
+	namespaceID, region := namespace.ID, namespace.Region
+
 	// Parse prefixed tool name
 	serverName, toolName, err := ParsePrefixedToolName(req.Tool)
 	if err != nil {
@@ -365,8 +854,7 @@ func (s *NamespaceService) ExecuteTool(ctx context.Context, namespaceID string,
 		}, nil
 	}
 
-	// Find the server by name
-	servers, err := s.repo.GetServers(ctx, namespaceID)
+	targetServer, err := s.findServer(ctx, namespaceID, serverName)
 	if err != nil {
 		return &types.NamespaceToolResult{
 			Success: false,
@@ -374,14 +862,6 @@ func (s *NamespaceService) ExecuteTool(ctx context.Context, namespaceID string,
 		}, nil
 	}
 
-	var targetServer *types.NamespaceServer
-	for _, server := range servers {
-		if SanitizeServerName(server.ServerName) == serverName {
-			targetServer = &server
-			break
-		}
-	}
-
 	if targetServer == nil {
 		return &types.NamespaceToolResult{
 			Success: false,
@@ -389,6 +869,17 @@ func (s *NamespaceService) ExecuteTool(ctx context.Context, namespaceID string,
 		}, nil
 	}
 
+	// Scoped API keys only reach the namespaces, servers and tools they
+	// list, whichever transport the call came over
+	if principal := types.PrincipalFromContext(ctx); principal != nil && principal.Scope != nil {
+		if err := principal.Scope.CheckNamespace(namespaceID); err != nil {
+			return nil, err
+		}
+		if err := principal.Scope.CheckTool(targetServer.ServerID, req.Tool); err != nil {
+			return nil, err
+		}
+	}
+
 	// Check if server is active
 	if targetServer.Status != string(types.NamespaceStatusActive) {
 		return &types.NamespaceToolResult{
@@ -397,8 +888,123 @@ func (s *NamespaceService) ExecuteTool(ctx context.Context, namespaceID string,
 		}, nil
 	}
 
+	// Keep traffic of a pinned namespace on servers and storage in its region
+	if err := s.checkResidency(region, targetServer); err != nil {
+		return &types.NamespaceToolResult{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	// Apply the namespace's argument template for this tool
+	toolConfig, err := s.repo.GetToolConfig(ctx, namespaceID, targetServer.ServerID, toolName)
+	if err != nil {
+		return &types.NamespaceToolResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to load tool configuration: %v", err),
+		}, nil
+	}
+	args, err := MergeToolArguments(toolConfig.Arguments, req.Arguments)
+	if err != nil {
+		return &types.NamespaceToolResult{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	// Tool policies see the arguments the server would receive
+	if err := s.checkToolPolicy(ctx, namespace.OrganizationID, &toolpolicy.Call{
+		Arguments:   args,
+		Principal:   types.PrincipalFromContext(ctx),
+		NamespaceID: namespaceID,
+		ServerID:    targetServer.ServerID,
+		ServerName:  targetServer.ServerName,
+		Tool:        req.Tool,
+	}); err != nil {
+		return nil, err
+	}
+
+	if s.headers != nil {
+		header, err := s.headers.UpstreamHeaders(ctx, namespaceID, targetServer.ServerID, targetServer.ServerName)
+		if err != nil {
+			return nil, types.NewServiceUnavailableError(fmt.Sprintf("header propagation rules could not be loaded: %v", err))
+		}
+		if len(header) > 0 {
+			ctx = types.WithUpstreamHeaders(ctx, header)
+		}
+	}
+
+	// The idempotency key lets the server recognize retries
+	meta := map[string]interface{}{}
+	if req.IdempotencyKey != "" {
+		meta["idempotencyKey"] = req.IdempotencyKey
+	}
+
+	// Keep data more sensitive than the server's label from leaving the
+	// gateway; the identity the gateway asserts below is not checked
+	if s.dlp != nil {
+		args, err = s.dlp.CheckArguments(ctx, namespace.OrganizationID, namespaceID, targetServer.ServerID, req.Tool, args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Assert the caller's identity to servers that implement per-user
+	// behavior, replacing anything the caller sent in its place
+	if s.identity != nil {
+		var identityMeta map[string]interface{}
+		args, identityMeta, err = s.identity.InjectIdentity(ctx, namespaceID, targetServer.ServerID, args)
+		if err != nil {
+			return nil, types.NewServiceUnavailableError(fmt.Sprintf("identity injection settings could not be loaded: %v", err))
+		}
+		for key, value := range identityMeta {
+			meta[key] = value
+		}
+	}
+
+	// Reject arguments that do not match the tool's input schema before
+	// anything reaches the upstream server
+	var tool *types.NamespaceTool
+	if s.validateInputs || s.validateOutputs {
+		tool = s.findTool(ctx, namespaceID, req.Tool)
+	}
+	if s.validateInputs && tool != nil && tool.InputSchema != nil {
+		if violations := validateToolArguments(tool.InputSchema, args); len(violations) > 0 {
+			s.recordSchemaViolation(req.Tool, "input")
+			return &types.NamespaceToolResult{
+				Success:    false,
+				Error:      fmt.Sprintf("arguments do not match the input schema of tool %s", req.Tool),
+				Violations: violations,
+			}, nil
+		}
+	}
+
+	// Only calls that would reach the server count against the tool's
+	// limits
+	if s.toolLimits != nil {
+		if err := s.toolLimits.CheckToolLimits(ctx, targetServer.ServerID, toolName, req.Tool); err != nil {
+			return nil, err
+		}
+	}
+
+	// Calls to a replicated server go to a healthy replica of its group
+	serverID := targetServer.ServerID
+	if s.replicas != nil {
+		replica, release, err := s.replicas.PickReplica(ctx, serverID, func(replicaRegion string) bool {
+			return s.residency.Allows(region, replicaRegion)
+		})
+		if err != nil {
+			return &types.NamespaceToolResult{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
+		defer release()
+		serverID = replica
+	}
+
 	// Get session for the server
-	session, err := s.sessionPool.GetSession(namespaceID, targetServer.ServerID)
+	session, err := s.sessionPool.GetSession(namespaceID, serverID)
 	if err != nil {
 		return &types.NamespaceToolResult{
 			Success: false,
@@ -406,21 +1012,239 @@ func (s *NamespaceService) ExecuteTool(ctx context.Context, namespaceID string,
 		}, nil
 	}
 
-	// Execute the tool
-	result, err := s.executeToolOnServer(ctx, session, toolName, req.Arguments)
+	// Execute the tool, retrying failures when the call can be repeated
+	var result interface{}
+	call := s.retryCall(ctx, session, "tools/call", req.IdempotencyKey)
+	attempts, err := s.retrier.Do(ctx, call, func(ctx context.Context) error {
+		return permanentCallError(s.callServer(ctx, serverID, "tools/call", func(ctx context.Context) error {
+			var err error
+			result, err = s.executeToolOnServer(ctx, session, toolName, args, meta)
+			return err
+		}))
+	})
+	if attempts == 1 {
+		attempts = 0 // only retried calls report their attempts
+	}
 	if err != nil {
 		return &types.NamespaceToolResult{
-			Success: false,
-			Error:   err.Error(),
+			Success:  false,
+			Error:    err.Error(),
+			Attempts: attempts,
 		}, nil
 	}
 
+	if s.validateOutputs && tool != nil && tool.OutputSchema != nil {
+		if violations := validateToolOutput(tool.OutputSchema, result); len(violations) > 0 {
+			s.recordSchemaViolation(req.Tool, "output")
+			return &types.NamespaceToolResult{
+				Success:    false,
+				Error:      fmt.Sprintf("result of tool %s does not match its output schema", req.Tool),
+				Violations: violations,
+			}, nil
+		}
+	}
+
+	// Drop or mask fields the namespace does not expose to callers
+	if toolConfig.Response != nil {
+		if result, err = ApplyResponsePolicy(toolConfig.Response, result); err != nil {
+			return &types.NamespaceToolResult{
+				Success: false,
+				Error:   fmt.Sprintf("failed to apply response policy: %v", err),
+			}, nil
+		}
+	}
+
 	return &types.NamespaceToolResult{
-		Success: true,
-		Result:  result,
+		Success:  true,
+		Result:   result,
+		Attempts: attempts,
 	}, nil
 }
 
+// retryCall describes a call to the server of session for the retrier. The
+// server's protocol and retry limit are read when the session is not
+// connected yet.
+func (s *NamespaceService) retryCall(ctx context.Context, session *Session, method, idempotencyKey string) retry.Call {
+	call := retry.Call{ServerID: session.ServerID, Method: method, IdempotencyKey: idempotencyKey}
+	if s.retrier == nil {
+		return call
+	}
+
+	session.mu.RLock()
+	call.Protocol, call.MaxRetries = session.Protocol, session.MaxRetries
+	session.mu.RUnlock()
+	if call.Protocol == "" {
+		if server, err := s.serverRepo.GetByID(ctx, session.ServerID); err == nil {
+			call.Protocol, call.MaxRetries = server.Protocol, server.MaxRetries
+		}
+	}
+	return call
+}
+
+// permanentCallError marks failures that retrying cannot fix: tool errors,
+// requests the server rejected as invalid and gateway policy violations
+func permanentCallError(err error) error {
+	var toolErr *mcp.ToolError
+	var gatewayErr *types.Error
+	var mcpErr *types.MCPError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &toolErr), errors.As(err, &gatewayErr):
+		return retry.Permanent(err)
+	case errors.As(err, &mcpErr):
+		switch mcpErr.Code {
+		case types.MCPErrorCodeParseError, types.MCPErrorCodeInvalidRequest, types.MCPErrorCodeMethodNotFound,
+			types.MCPErrorCodeInvalidParams, types.MCPErrorCodeCancelled:
+			return retry.Permanent(err)
+		}
+	}
+	return err
+}
+
+func (s *NamespaceService) checkResidency(region string, server *types.NamespaceServer) error {
+	if err := s.residency.CheckStorage(region); err != nil {
+		return err
+	}
+	return s.residency.CheckServer(region, server.ServerName, server.Region)
+}
+
+// checkServerRegions returns an error when any of serverIDs may not serve a
+// namespace pinned to region
+func (s *NamespaceService) checkServerRegions(ctx context.Context, region string, serverIDs []string) error {
+	if region == "" {
+		return nil
+	}
+	for _, serverID := range serverIDs {
+		server, err := s.serverRepo.GetByID(ctx, serverID)
+		if err != nil {
+			// Unknown servers are skipped when the mappings are written
+			continue
+		}
+		if err := s.residency.CheckServer(region, server.Name, server.Region); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callServer runs an attempt of a call to an upstream server in its own
+// span and records its outcome
+func (s *NamespaceService) callServer(ctx context.Context, serverID, method string, call func(ctx context.Context) error) error {
+	ctx, span := observability.StartSpan(ctx, "namespace.server_call "+method, trace.WithAttributes(
+		attribute.String("mcp.server.id", serverID),
+		attribute.String("rpc.method", method),
+	))
+	start := time.Now()
+	err := call(ctx)
+	span.SetAttributes(attribute.String("mcp.outcome", callOutcome(err)))
+	observability.EndSpan(span, err)
+	s.recordServerCall(serverID, method, start, err)
+	return err
+}
+
+// callOutcome classifies the result of a call to an upstream server
+func callOutcome(err error) string {
+	var toolErr *mcp.ToolError
+	switch {
+	case errors.As(err, &toolErr):
+		return "tool_error"
+	case err != nil:
+		return "error"
+	}
+	return "success"
+}
+
+// recordServerCall counts an attempted call to an upstream server by method
+// and outcome, and records its latency
+func (s *NamespaceService) recordServerCall(serverID, method string, start time.Time, err error) {
+	outcome := callOutcome(err)
+	if s.callObserver != nil {
+		s.callObserver.ObserveServerCall(serverID, time.Since(start), outcome == "error")
+	}
+	if s.emitMetric == nil {
+		return
+	}
+
+	tags := map[string]string{"method": method, "outcome": outcome}
+	s.emitMetric(&types.Metric{
+		Timestamp: start,
+		Name:      "mcp_server_calls_total",
+		Type:      types.MetricTypeCounter,
+		Value:     1,
+		Tags:      tags,
+		ServerID:  serverID,
+	})
+	s.emitMetric(&types.Metric{
+		Timestamp: start,
+		Name:      "mcp_server_call_duration",
+		Type:      types.MetricTypeHistogram,
+		Value:     float64(time.Since(start).Microseconds()) / 1000,
+		Tags:      tags,
+		ServerID:  serverID,
+	})
+}
+
+func (s *NamespaceService) recordSchemaViolation(tool, direction string) {
+	if s.emitMetric == nil {
+		return
+	}
+	s.emitMetric(&types.Metric{
+		Timestamp: time.Now(),
+		Name:      "tool_schema_violations_total",
+		Type:      types.MetricTypeCounter,
+		Value:     1,
+		Tags:      map[string]string{"tool": tool, "direction": direction},
+	})
+}
+
+// validateToolArguments checks tools/call arguments against an input schema.
+// Missing arguments validate as an empty object.
+func validateToolArguments(schema, args map[string]interface{}) []types.SchemaViolation {
+	var value interface{} = map[string]interface{}{}
+	if args != nil {
+		value = normalizeJSON(args)
+	}
+	return jsonschema.Validate(schema, value)
+}
+
+// validateToolOutput checks the structured content of a tools/call result
+// against the tool's output schema. MCP requires servers that declare an
+// output schema to return structured content.
+func validateToolOutput(schema map[string]interface{}, result interface{}) []types.SchemaViolation {
+	var structured interface{}
+	switch r := result.(type) {
+	case mcp.ToolsCallResult:
+		structured = r.StructuredContent
+	case *mcp.ToolsCallResult:
+		structured = r.StructuredContent
+	case map[string]interface{}:
+		structured = r["structuredContent"]
+	}
+	if structured == nil {
+		return []types.SchemaViolation{{
+			Path:    "/",
+			Keyword: "structuredContent",
+			Message: "tool declares an output schema but returned no structured content",
+		}}
+	}
+	return jsonschema.Validate(schema, normalizeJSON(structured))
+}
+
+// normalizeJSON converts Go values to their decoded JSON form so schema
+// validation sees the same types an upstream server would
+func normalizeJSON(value interface{}) interface{} {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
 // UpdateToolStatus updates the status of a tool in a namespace
 func (s *NamespaceService) UpdateToolStatus(ctx context.Context, namespaceID, serverID, toolName string, req types.UpdateToolStatusRequest) error {
 	if err := s.repo.SetToolStatus(ctx, namespaceID, serverID, toolName, req.Status); err != nil {
@@ -433,6 +1257,126 @@ func (s *NamespaceService) UpdateToolStatus(ctx context.Context, namespaceID, se
 	return nil
 }
 
+// UpdateToolArguments sets the default and locked arguments of a tool in a
+// namespace. An empty template removes them.
+func (s *NamespaceService) UpdateToolArguments(ctx context.Context, namespaceID, serverID, toolName string, req types.UpdateToolArgumentsRequest) error {
+	template := &types.ToolArgumentTemplate{
+		Defaults:   req.Defaults,
+		Locked:     req.Locked,
+		OnConflict: req.OnConflict,
+	}
+	if template.Defaults == nil {
+		template.Defaults = map[string]interface{}{}
+	}
+	if template.Locked == nil {
+		template.Locked = map[string]interface{}{}
+	}
+	if template.OnConflict == "" {
+		template.OnConflict = types.ArgumentConflictReject
+	}
+	if template.OnConflict != types.ArgumentConflictReject && template.OnConflict != types.ArgumentConflictOverride {
+		return types.NewValidationError("on_conflict must be reject or override")
+	}
+	for name := range template.Locked {
+		if _, ok := template.Defaults[name]; ok {
+			return types.NewValidationError(fmt.Sprintf("argument %q cannot be both a default and locked", name))
+		}
+	}
+
+	if err := s.repo.SetToolArguments(ctx, namespaceID, serverID, toolName, template); err != nil {
+		return err
+	}
+
+	// Clear cache for this namespace
+	s.clearToolCache(namespaceID)
+
+	return nil
+}
+
+// UpdateToolResponsePolicy sets the rules that drop or mask fields of a
+// tool's result in a namespace. No rules removes the policy.
+func (s *NamespaceService) UpdateToolResponsePolicy(ctx context.Context, namespaceID, serverID, toolName string, req types.UpdateToolResponsePolicyRequest) error {
+	policy := &types.ToolResponsePolicy{Rules: req.Rules}
+	if policy.Rules == nil {
+		policy.Rules = []types.ResponseRule{}
+	}
+	for _, rule := range policy.Rules {
+		if rule.Action != types.ResponseActionDrop && rule.Action != types.ResponseActionMask {
+			return types.NewValidationError(fmt.Sprintf("unsupported response action %q", rule.Action))
+		}
+		if _, err := jsonpath.Parse(rule.Path); err != nil {
+			return types.NewValidationError(err.Error())
+		}
+	}
+
+	if err := s.repo.SetToolResponsePolicy(ctx, namespaceID, serverID, toolName, policy); err != nil {
+		return err
+	}
+
+	// Clear cache for this namespace
+	s.clearToolCache(namespaceID)
+
+	return nil
+}
+
+// ApplyResponsePolicy returns a copy of a tools/call result with the
+// policy's rules applied in order
+func ApplyResponsePolicy(policy *types.ToolResponsePolicy, result interface{}) (interface{}, error) {
+	if policy == nil || len(policy.Rules) == 0 {
+		return result, nil
+	}
+
+	doc := normalizeJSON(result)
+	for _, rule := range policy.Rules {
+		path, err := jsonpath.Parse(rule.Path)
+		if err != nil {
+			return nil, err
+		}
+		switch rule.Action {
+		case types.ResponseActionDrop:
+			doc = path.Delete(doc)
+		case types.ResponseActionMask:
+			doc = path.Replace(doc, types.RedactedValue)
+		default:
+			return nil, fmt.Errorf("unsupported response action %q", rule.Action)
+		}
+	}
+	return doc, nil
+}
+
+// MergeToolArguments applies a tool's argument template to the caller's
+// arguments. Defaults fill in omitted arguments; locked arguments always
+// take the configured value, and a caller passing a different value is
+// rejected unless the template overrides conflicts.
+func MergeToolArguments(template *types.ToolArgumentTemplate, args map[string]interface{}) (map[string]interface{}, error) {
+	if template == nil {
+		return args, nil
+	}
+
+	merged := make(map[string]interface{}, len(args)+len(template.Defaults)+len(template.Locked))
+	for name, value := range template.Defaults {
+		merged[name] = value
+	}
+	for name, value := range args {
+		merged[name] = value
+	}
+
+	names := make([]string, 0, len(template.Locked))
+	for name := range template.Locked {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		locked := template.Locked[name]
+		if value, ok := args[name]; ok && template.OnConflict != types.ArgumentConflictOverride &&
+			!reflect.DeepEqual(normalizeJSON(value), normalizeJSON(locked)) {
+			return nil, fmt.Errorf("argument %q is locked for this tool and cannot be changed", name)
+		}
+		merged[name] = locked
+	}
+	return merged, nil
+}
+
 // Private helper methods
 
 func (s *NamespaceService) validateNamespaceName(name string) error {
@@ -479,6 +1423,16 @@ func (s *NamespaceService) ensureMCPConnection(ctx context.Context, session *Ses
 			return fmt.Errorf("stdio server requires command")
 		}
 
+		subject := commandpolicy.Subject{
+			OrganizationID: server.OrganizationID,
+			ServerID:       serverID,
+			ServerName:     server.Name,
+			Stage:          commandpolicy.StageLaunch,
+		}
+		if err := s.commandPolicy.Enforce(ctx, subject, *server.Command, server.Args); err != nil {
+			return err
+		}
+
 		// Convert environment array to map
 		envMap := make(map[string]string)
 		for _, env := range server.Environment {
@@ -500,13 +1454,52 @@ func (s *NamespaceService) ensureMCPConnection(ctx context.Context, session *Ses
 				}
 				return ""
 			}(),
+			Output: s.serverLogs.Sink(serverID),
+		}
+	case "grpc":
+		if server.URL == nil || *server.URL == "" {
+			return fmt.Errorf("grpc server requires url")
+		}
+
+		transportConfig = mcp.TransportConfig{
+			Type: "grpc",
+			URL:  *server.URL,
+		}
+	case "mock":
+		if s.mockTools == nil {
+			return fmt.Errorf("mock servers are not available")
+		}
+
+		transportConfig = mcp.TransportConfig{
+			Type: "mock",
+			MockTools: func(ctx context.Context) ([]types.MockTool, error) {
+				return s.mockTools.LoadMockTools(ctx, serverID)
+			},
 		}
 	default:
 		return fmt.Errorf("unsupported protocol: %s", server.Protocol)
 	}
 
 	// Create MCP client
-	transport := &mcp.StdioTransport{}
+	var transport mcp.Transport = &mcp.StdioTransport{}
+	switch transportConfig.Type {
+	case "grpc":
+		transport = &mcp.GRPCTransport{}
+	case "mock":
+		transport = &mcp.MockTransport{}
+	}
+	if s.recordings != nil {
+		mode, err := s.recordings.RecordingMode(ctx, serverID)
+		if err != nil {
+			return fmt.Errorf("failed to get recording mode: %w", err)
+		}
+		switch mode {
+		case types.RecordingModeRecord:
+			transport = mcp.NewRecordingTransport(transport, serverID, s.recordings)
+		case types.RecordingModeReplay:
+			transport = mcp.NewReplayTransport(serverID, s.recordings)
+		}
+	}
 	client := mcp.NewMCPClient(transport)
 
 	// Connect to the server
@@ -527,6 +1520,8 @@ func (s *NamespaceService) ensureMCPConnection(ctx context.Context, session *Ses
 	// Store connection in session
 	session.Connection = client
 	session.Status = "connected"
+	session.Protocol = server.Protocol
+	session.MaxRetries = server.MaxRetries
 
 	return nil
 }
@@ -576,7 +1571,7 @@ func (s *NamespaceService) getServerTools(ctx context.Context, session *Session,
 	return tools, nil
 }
 
-func (s *NamespaceService) executeToolOnServer(ctx context.Context, session *Session, toolName string, args map[string]interface{}) (interface{}, error) {
+func (s *NamespaceService) executeToolOnServer(ctx context.Context, session *Session, toolName string, args, meta map[string]interface{}) (interface{}, error) {
 	// Ensure we have an active connection
 	session.mu.RLock()
 	client := session.Connection
@@ -596,8 +1591,11 @@ func (s *NamespaceService) executeToolOnServer(ctx context.Context, session *Ses
 		return nil, fmt.Errorf("MCP connection not available")
 	}
 
-	// Execute tool via MCP protocol
-	result, err := client.CallTool(ctx, toolName, args)
+	// Execute tool via MCP protocol with the call's _meta entries
+	if len(meta) == 0 {
+		meta = nil
+	}
+	result, err := client.CallToolWithMeta(ctx, toolName, args, meta)
 	if err != nil {
 		// Check if it's a connection error and mark session as disconnected
 		session.mu.Lock()
@@ -633,6 +1631,35 @@ func PrefixToolName(serverName, toolName string) string {
 	return fmt.Sprintf("%s__%s", SanitizeServerName(serverName), toolName)
 }
 
+// ToolServerID returns the ID of the namespace server a prefixed tool runs
+// on, or an empty string when no server of the namespace matches its prefix
+func (s *NamespaceService) ToolServerID(ctx context.Context, namespaceID, tool string) (string, error) {
+	serverName, _, err := ParsePrefixedToolName(tool)
+	if err != nil {
+		return "", nil
+	}
+	server, err := s.findServer(ctx, namespaceID, serverName)
+	if err != nil || server == nil {
+		return "", err
+	}
+	return server.ServerID, nil
+}
+
+// findServer returns the namespace server whose sanitized name is
+// serverName, or nil
+func (s *NamespaceService) findServer(ctx context.Context, namespaceID, serverName string) (*types.NamespaceServer, error) {
+	servers, err := s.repo.GetServers(ctx, namespaceID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range servers {
+		if SanitizeServerName(servers[i].ServerName) == serverName {
+			return &servers[i], nil
+		}
+	}
+	return nil, nil
+}
+
 // ParsePrefixedToolName parses a prefixed tool name
 func ParsePrefixedToolName(prefixed string) (serverName, toolName string, err error) {
 	parts := strings.SplitN(prefixed, "__", 2)
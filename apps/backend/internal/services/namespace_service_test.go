@@ -3,6 +3,7 @@ package services
 import (
 	"testing"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mcp"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/stretchr/testify/assert"
@@ -369,3 +370,46 @@ func TestNamespaceService_ExecuteTool_ParsesToolName(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateToolArguments(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"path"},
+		"properties": map[string]interface{}{
+			"path":  map[string]interface{}{"type": "string"},
+			"lines": map[string]interface{}{"type": "integer", "minimum": 1},
+		},
+	}
+
+	assert.Empty(t, validateToolArguments(schema, map[string]interface{}{"path": "/tmp/a", "lines": 10}))
+
+	violations := validateToolArguments(schema, nil)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "/path", violations[0].Path)
+	assert.Equal(t, "required", violations[0].Keyword)
+
+	violations = validateToolArguments(schema, map[string]interface{}{"path": "/tmp/a", "lines": 0})
+	require.Len(t, violations, 1)
+	assert.Equal(t, "/lines", violations[0].Path)
+}
+
+func TestValidateToolOutput(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"required":   []interface{}{"temperature"},
+		"properties": map[string]interface{}{"temperature": map[string]interface{}{"type": "number"}},
+	}
+
+	valid := mcp.ToolsCallResult{StructuredContent: map[string]interface{}{"temperature": 21.5}}
+	assert.Empty(t, validateToolOutput(schema, valid))
+
+	violations := validateToolOutput(schema, mcp.ToolsCallResult{})
+	require.Len(t, violations, 1)
+	assert.Equal(t, "structuredContent", violations[0].Keyword)
+
+	violations = validateToolOutput(schema, map[string]interface{}{
+		"structuredContent": map[string]interface{}{"temperature": "warm"},
+	})
+	require.Len(t, violations, 1)
+	assert.Equal(t, "type", violations[0].Keyword)
+}
@@ -20,7 +20,10 @@ type Session struct {
 	LastUsed     time.Time
 	Tools        []types.Tool
 	Capabilities map[string]interface{}
-	mu           sync.RWMutex
+	// Protocol and MaxRetries of the server, known once connected
+	Protocol   string
+	MaxRetries int
+	mu         sync.RWMutex
 }
 
 // Close closes the session and cleans up resources
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Node registry defaults
+const (
+	DefaultNodeHeartbeatInterval = 15 * time.Second
+
+	// nodeStaleHeartbeats is how many heartbeats a node may miss before it
+	// is reported stale
+	nodeStaleHeartbeats = 3
+	// nodeForgetAfter is how long a stale node stays listed before it is
+	// removed from the registry
+	nodeForgetAfter = 24 * time.Hour
+)
+
+// NodeStore persists the heartbeats of the gateway's nodes
+type NodeStore interface {
+	Heartbeat(node *types.GatewayNode) (bool, error)
+	List() ([]*types.GatewayNode, error)
+	SetDraining(id string, draining bool, requestedBy string, at time.Time) (bool, error)
+	Delete(id string) error
+	DeleteStale(before time.Time) (int64, error)
+}
+
+// SessionCounter counts a node's client sessions by transport; the
+// transport manager satisfies it
+type SessionCounter interface {
+	SessionsByTransport() map[string]int
+}
+
+// HealthReporter reports a node's service health; the health monitor
+// satisfies it
+type HealthReporter interface {
+	Health() *types.ServiceHealth
+}
+
+// NodeSettings describe the node the registry runs on
+type NodeSettings struct {
+	ID       string
+	Hostname string
+	// Address is where other nodes and operators can reach this node
+	Address string
+	Region  string
+	Version string
+	// Interval is how often the node heartbeats
+	Interval time.Duration
+	// StaleAfter is how long after its last heartbeat a node is reported
+	// stale
+	StaleAfter time.Duration
+}
+
+// NodeRegistry keeps this node's entry in the registry of gateway nodes up
+// to date and lists the fleet. A node an admin drains fails readiness
+// checks and refuses new sessions until the drain is lifted.
+type NodeRegistry struct {
+	store     NodeStore
+	sessions  SessionCounter
+	health    HealthReporter
+	now       func() time.Time
+	stopCh    chan struct{}
+	startedAt time.Time
+	settings  NodeSettings
+	wg        sync.WaitGroup
+	mu        sync.RWMutex
+	draining  bool
+	running   bool
+}
+
+// NewNodeRegistry creates a database-backed node registry
+func NewNodeRegistry(db *sql.DB, settings NodeSettings, sessions SessionCounter, health HealthReporter) *NodeRegistry {
+	return NewNodeRegistryWithStore(models.NewGatewayNodeModel(db), settings, sessions, health)
+}
+
+// NewNodeRegistryWithStore creates a node registry over store; zero
+// settings take the defaults
+func NewNodeRegistryWithStore(store NodeStore, settings NodeSettings, sessions SessionCounter, health HealthReporter) *NodeRegistry {
+	if settings.Interval <= 0 {
+		settings.Interval = DefaultNodeHeartbeatInterval
+	}
+	if settings.StaleAfter <= 0 {
+		settings.StaleAfter = nodeStaleHeartbeats * settings.Interval
+	}
+	return &NodeRegistry{
+		store:     store,
+		sessions:  sessions,
+		health:    health,
+		now:       time.Now,
+		stopCh:    make(chan struct{}),
+		startedAt: time.Now(),
+		settings:  settings,
+	}
+}
+
+// SetClock replaces the registry's clock; the node counts as started at
+// the new clock's current time
+func (r *NodeRegistry) SetClock(now func() time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.now = now
+	r.startedAt = now()
+}
+
+// ID returns the ID of this node
+func (r *NodeRegistry) ID() string {
+	return r.settings.ID
+}
+
+// Draining reports whether this node is draining
+func (r *NodeRegistry) Draining() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.draining
+}
+
+// Start heartbeats once, so the node is listed before the first request,
+// and then every interval until Stop
+func (r *NodeRegistry) Start() {
+	r.mu.Lock()
+	if r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = true
+	r.mu.Unlock()
+
+	if err := r.Heartbeat(context.Background()); err != nil {
+		log.Printf("Error recording node heartbeat: %v", err)
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.settings.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.Heartbeat(context.Background()); err != nil {
+					log.Printf("Error recording node heartbeat: %v", err)
+				}
+			case <-r.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops heartbeating and removes this node from the registry, so a
+// node that shuts down cleanly is not reported stale
+func (r *NodeRegistry) Stop() {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = false
+	r.mu.Unlock()
+
+	close(r.stopCh)
+	r.wg.Wait()
+	if err := r.store.Delete(r.settings.ID); err != nil {
+		log.Printf("Error removing node %s from the registry: %v", r.settings.ID, err)
+	}
+}
+
+// Heartbeat records this node's state, picks up drain requests and
+// forgets nodes that have been stale for a day
+func (r *NodeRegistry) Heartbeat(ctx context.Context) error {
+	r.mu.RLock()
+	now, startedAt := r.now(), r.startedAt
+	r.mu.RUnlock()
+
+	node := &types.GatewayNode{
+		ID:              r.settings.ID,
+		Hostname:        r.settings.Hostname,
+		Address:         r.settings.Address,
+		Version:         r.settings.Version,
+		Region:          r.settings.Region,
+		StartedAt:       startedAt,
+		LastHeartbeatAt: now,
+		Sessions:        map[string]int{},
+	}
+	if r.sessions != nil {
+		node.Sessions = r.sessions.SessionsByTransport()
+	}
+	if r.health != nil {
+		health := r.health.Health()
+		node.HealthStatus = health.Status
+		node.HealthScore = health.Score
+	}
+
+	draining, err := r.store.Heartbeat(node)
+	if err != nil {
+		return fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+	r.setDraining(draining)
+
+	if _, err := r.store.DeleteStale(now.Add(-nodeForgetAfter)); err != nil {
+		return fmt.Errorf("failed to remove stale nodes: %w", err)
+	}
+	return nil
+}
+
+// List returns every registered node with its status and uptime
+func (r *NodeRegistry) List(ctx context.Context) (*types.NodeFleet, error) {
+	nodes, err := r.store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	r.mu.RLock()
+	now := r.now()
+	r.mu.RUnlock()
+
+	fleet := &types.NodeFleet{Nodes: nodes}
+	for _, node := range nodes {
+		node.Self = node.ID == r.settings.ID
+		for _, count := range node.Sessions {
+			node.SessionCount += count
+		}
+
+		upUntil := now
+		switch {
+		case now.Sub(node.LastHeartbeatAt) > r.settings.StaleAfter:
+			node.Status = types.NodeStatusStale
+			upUntil = node.LastHeartbeatAt
+			fleet.Stale++
+		case node.Draining:
+			node.Status = types.NodeStatusDraining
+			fleet.Draining++
+			fleet.Sessions += node.SessionCount
+		default:
+			node.Status = types.NodeStatusActive
+			fleet.Active++
+			fleet.Sessions += node.SessionCount
+		}
+		node.UptimeSeconds = int64(upUntil.Sub(node.StartedAt).Seconds())
+	}
+	return fleet, nil
+}
+
+// SetDraining starts or stops draining a node. Draining this node applies
+// at once; other nodes pick it up with their next heartbeat.
+func (r *NodeRegistry) SetDraining(ctx context.Context, id string, draining bool, requestedBy string) (*types.GatewayNode, error) {
+	r.mu.RLock()
+	now := r.now()
+	r.mu.RUnlock()
+
+	found, err := r.store.SetDraining(id, draining, requestedBy, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update node: %w", err)
+	}
+	if !found {
+		return nil, types.NewNotFoundError("Node not found")
+	}
+	if id == r.settings.ID {
+		r.setDraining(draining)
+	}
+
+	fleet, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range fleet.Nodes {
+		if node.ID == id {
+			return node, nil
+		}
+	}
+	return nil, types.NewNotFoundError("Node not found")
+}
+
+func (r *NodeRegistry) setDraining(draining bool) {
+	r.mu.Lock()
+	changed := r.draining != draining
+	r.draining = draining
+	r.mu.Unlock()
+
+	if changed && draining {
+		log.Printf("Node %s is draining: readiness checks fail and new sessions are refused", r.settings.ID)
+	} else if changed {
+		log.Printf("Node %s is no longer draining", r.settings.ID)
+	}
+}
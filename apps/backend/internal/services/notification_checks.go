@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// certificateDialTimeout bounds each TLS handshake made to read a server
+// certificate
+const certificateDialTimeout = 10 * time.Second
+
+// QuotaSource reports an organization's plan quotas
+type QuotaSource interface {
+	GetPlanQuotas(ctx context.Context, orgID string) (*types.PlanQuotas, error)
+}
+
+// CertificateProber returns when the certificate served at rawURL expires
+type CertificateProber func(ctx context.Context, rawURL string) (time.Time, error)
+
+// CheckQuotas notifies admins of every active organization whose server or
+// session usage reached thresholdPct of its plan limit
+func (s *NotificationService) CheckQuotas(ctx context.Context, quotas QuotaSource, thresholdPct int) (int, error) {
+	if thresholdPct <= 0 || thresholdPct > 100 {
+		thresholdPct = 80
+	}
+
+	orgIDs, err := s.store.ListActiveOrganizations()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	created := 0
+	for _, orgID := range orgIDs {
+		planQuotas, err := quotas.GetPlanQuotas(ctx, orgID)
+		if err != nil {
+			log.Printf("Failed to load plan quotas for organization %s: %v", orgID, err)
+			continue
+		}
+
+		for _, quota := range []struct {
+			usage types.QuotaUsage
+			name  string
+		}{
+			{name: "servers", usage: planQuotas.Servers},
+			{name: "sessions", usage: planQuotas.Sessions},
+		} {
+			if quota.usage.Limit <= 0 || quota.usage.Used*100 < quota.usage.Limit*thresholdPct {
+				continue
+			}
+
+			severity := types.NotificationSeverityWarning
+			if quota.usage.Used >= quota.usage.Limit {
+				severity = types.NotificationSeverityCritical
+			}
+			n, err := s.Notify(ctx, &types.NotificationEvent{
+				OrganizationID: orgID,
+				Type:           types.NotificationQuotaNearing,
+				Severity:       severity,
+				Title:          fmt.Sprintf("%s quota at %d%%", quota.name, quota.usage.Used*100/quota.usage.Limit),
+				Message: fmt.Sprintf("Your organization is using %d of %d %s allowed by the %s plan.",
+					quota.usage.Used, quota.usage.Limit, quota.name, planQuotas.PlanType),
+				ResourceType: "organization",
+				ResourceID:   orgID,
+				DedupKey:     types.NotificationQuotaNearing + ":" + orgID + ":" + quota.name,
+				Data: map[string]interface{}{
+					"quota": quota.name,
+					"used":  quota.usage.Used,
+					"limit": quota.usage.Limit,
+				},
+			})
+			if err != nil {
+				log.Printf("Failed to notify organization %s about %s quota: %v", orgID, quota.name, err)
+				continue
+			}
+			created += n
+		}
+	}
+
+	return created, nil
+}
+
+// CheckCertificates notifies admins when a TLS server's certificate expires
+// within the given number of days. A nil probe dials each server directly.
+func (s *NotificationService) CheckCertificates(ctx context.Context, probe CertificateProber, withinDays int) (int, error) {
+	if withinDays <= 0 {
+		withinDays = 14
+	}
+	if probe == nil {
+		probe = ProbeCertificateExpiry
+	}
+
+	targets, err := s.store.ListTLSServers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list TLS servers: %w", err)
+	}
+
+	deadline := s.now().Add(time.Duration(withinDays) * 24 * time.Hour)
+	created := 0
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			return created, ctx.Err()
+		}
+
+		expiresAt, err := probe(ctx, target.URL)
+		if err != nil {
+			log.Printf("Failed to read certificate for server %s: %v", target.ServerID, err)
+			continue
+		}
+		if expiresAt.After(deadline) {
+			continue
+		}
+
+		severity := types.NotificationSeverityWarning
+		title := fmt.Sprintf("Certificate for %s expires soon", target.Name)
+		if !expiresAt.After(s.now()) {
+			severity = types.NotificationSeverityCritical
+			title = fmt.Sprintf("Certificate for %s has expired", target.Name)
+		}
+		n, err := s.Notify(ctx, &types.NotificationEvent{
+			OrganizationID: target.OrganizationID,
+			Type:           types.NotificationCertificateExpiring,
+			Severity:       severity,
+			Title:          title,
+			Message:        fmt.Sprintf("The TLS certificate served at %s expires on %s.", target.URL, expiresAt.UTC().Format(time.RFC1123)),
+			ResourceType:   "server",
+			ResourceID:     target.ServerID,
+			DedupKey:       types.NotificationCertificateExpiring + ":" + target.ServerID,
+			Data:           map[string]interface{}{"expires_at": expiresAt.UTC()},
+		})
+		if err != nil {
+			log.Printf("Failed to notify about certificate for server %s: %v", target.ServerID, err)
+			continue
+		}
+		created += n
+	}
+
+	return created, nil
+}
+
+// ProbeCertificateExpiry connects to rawURL and returns the expiry of the
+// leaf certificate. Verification is skipped so already expired or
+// self-signed certificates are still reported.
+func ProbeCertificateExpiry(ctx context.Context, rawURL string) (time.Time, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return time.Time{}, err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "443")
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: certificateDialTimeout},
+		Config:    &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: true}, // #nosec G402 -- only the expiry is read
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return time.Time{}, fmt.Errorf("no certificate presented")
+	}
+	return certs[0].NotAfter, nil
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mailer"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	defaultNotificationPageSize = 50
+	maxNotificationPageSize     = 200
+)
+
+// NotificationStore persists notifications and delivery preferences
+type NotificationStore interface {
+	ListAdmins(orgID string) ([]*types.NotificationRecipient, error)
+	ListDigestRecipients() ([]*types.NotificationRecipient, error)
+	HasUnread(userID, dedupKey string) (bool, error)
+	Create(n *types.Notification) error
+	List(filter *types.NotificationListFilter) ([]*types.Notification, error)
+	ListUndigested(userID string) ([]*types.Notification, error)
+	CountUnread(userID string) (int, error)
+	MarkRead(userID, id string) (bool, error)
+	MarkAllRead(userID string) (int64, error)
+	MarkEmailed(userID string, ids []string, at time.Time) error
+	GetPreferences(userID string) (*types.NotificationPreferences, error)
+	UpsertPreferences(prefs *types.NotificationPreferences) error
+	ListActiveOrganizations() ([]string, error)
+	ListTLSServers() ([]*types.TLSServerTarget, error)
+}
+
+// NotificationService delivers gateway events to organization admins as
+// in-app notifications and optional email digests
+type NotificationService struct {
+	store    NotificationStore
+	webhooks WebhookEmitter
+	now      func() time.Time
+}
+
+// NewNotificationService creates a database-backed notification service
+func NewNotificationService(db *sql.DB) *NotificationService {
+	return NewNotificationServiceWithStore(models.NewNotificationModel(db))
+}
+
+// NewNotificationServiceWithStore creates a notification service over store
+func NewNotificationServiceWithStore(store NotificationStore) *NotificationService {
+	return &NotificationService{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// SetWebhooks also publishes notified events to the organization's
+// webhooks, under the types of types.WebhookEventForNotification
+func (s *NotificationService) SetWebhooks(webhooks WebhookEmitter) {
+	s.webhooks = webhooks
+}
+
+// Notify creates a notification for every active admin of the event's
+// organization and returns how many were created. Admins who still have an
+// unread notification with the same dedup key are skipped.
+func (s *NotificationService) Notify(ctx context.Context, event *types.NotificationEvent) (int, error) {
+	if event.OrganizationID == "" || event.Type == "" || event.Title == "" {
+		return 0, types.NewValidationError("organization, type and title are required")
+	}
+	severity := event.Severity
+	if severity == "" {
+		severity = types.NotificationSeverityInfo
+	}
+
+	admins, err := s.store.ListAdmins(event.OrganizationID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list admins: %w", err)
+	}
+
+	created := 0
+	for _, admin := range admins {
+		if event.DedupKey != "" {
+			exists, err := s.store.HasUnread(admin.UserID, event.DedupKey)
+			if err != nil {
+				return created, fmt.Errorf("failed to check existing notification: %w", err)
+			}
+			if exists {
+				continue
+			}
+		}
+
+		if err := s.store.Create(&types.Notification{
+			OrganizationID: event.OrganizationID,
+			UserID:         admin.UserID,
+			Type:           event.Type,
+			Severity:       severity,
+			Title:          event.Title,
+			Message:        event.Message,
+			ResourceType:   event.ResourceType,
+			ResourceID:     event.ResourceID,
+			DedupKey:       event.DedupKey,
+			Data:           event.Data,
+		}); err != nil {
+			return created, fmt.Errorf("failed to create notification: %w", err)
+		}
+		created++
+	}
+
+	// Deduplicated events are published once, with the notifications
+	if created > 0 || event.DedupKey == "" {
+		s.publish(ctx, event, severity)
+	}
+
+	return created, nil
+}
+
+func (s *NotificationService) publish(ctx context.Context, event *types.NotificationEvent, severity string) {
+	eventType, ok := types.WebhookEventForNotification[event.Type]
+	if s.webhooks == nil || !ok {
+		return
+	}
+	err := s.webhooks.Emit(ctx, event.OrganizationID, eventType, map[string]interface{}{
+		"title":         event.Title,
+		"message":       event.Message,
+		"severity":      severity,
+		"resource_type": event.ResourceType,
+		"resource_id":   event.ResourceID,
+		"data":          event.Data,
+	})
+	if err != nil {
+		log.Printf("Failed to publish %s webhook event: %v", eventType, err)
+	}
+}
+
+// NotifyUser creates a notification of the event for a single user of its
+// organization, unless they still have an unread one with the same dedup
+// key. It reports whether a notification was created.
+func (s *NotificationService) NotifyUser(ctx context.Context, userID string, event *types.NotificationEvent) (bool, error) {
+	if userID == "" || event.OrganizationID == "" || event.Type == "" || event.Title == "" {
+		return false, types.NewValidationError("user, organization, type and title are required")
+	}
+	severity := event.Severity
+	if severity == "" {
+		severity = types.NotificationSeverityInfo
+	}
+
+	if event.DedupKey != "" {
+		exists, err := s.store.HasUnread(userID, event.DedupKey)
+		if err != nil {
+			return false, fmt.Errorf("failed to check existing notification: %w", err)
+		}
+		if exists {
+			return false, nil
+		}
+	}
+
+	if err := s.store.Create(&types.Notification{
+		OrganizationID: event.OrganizationID,
+		UserID:         userID,
+		Type:           event.Type,
+		Severity:       severity,
+		Title:          event.Title,
+		Message:        event.Message,
+		ResourceType:   event.ResourceType,
+		ResourceID:     event.ResourceID,
+		DedupKey:       event.DedupKey,
+		Data:           event.Data,
+	}); err != nil {
+		return false, fmt.Errorf("failed to create notification: %w", err)
+	}
+	return true, nil
+}
+
+// List returns a page of the user's notifications with their unread count
+func (s *NotificationService) List(ctx context.Context, filter *types.NotificationListFilter) (*types.NotificationListResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultNotificationPageSize
+	}
+	if filter.Limit > maxNotificationPageSize {
+		filter.Limit = maxNotificationPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	notifications, err := s.store.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []*types.Notification{}
+	}
+	unread, err := s.store.CountUnread(filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.NotificationListResponse{
+		Notifications: notifications,
+		UnreadCount:   unread,
+		Limit:         filter.Limit,
+		Offset:        filter.Offset,
+	}, nil
+}
+
+// UnreadCount returns the number of unread notifications for the user
+func (s *NotificationService) UnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.store.CountUnread(userID)
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id string) error {
+	found, err := s.store.MarkRead(userID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return types.NewNotFoundError("Notification not found")
+	}
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications as read
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	return s.store.MarkAllRead(userID)
+}
+
+// GetPreferences returns the user's preferences, defaulting to no digests
+func (s *NotificationService) GetPreferences(ctx context.Context, userID string) (*types.NotificationPreferences, error) {
+	prefs, err := s.store.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs == nil {
+		prefs = &types.NotificationPreferences{UserID: userID}
+	}
+	return prefs, nil
+}
+
+// SetPreferences updates the user's preferences
+func (s *NotificationService) SetPreferences(ctx context.Context, userID string, req *types.UpdateNotificationPreferencesRequest) (*types.NotificationPreferences, error) {
+	if err := s.store.UpsertPreferences(&types.NotificationPreferences{
+		UserID:      userID,
+		EmailDigest: req.EmailDigest,
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(ctx, userID)
+}
+
+// SendDigests emails every opted-in user a summary of their unread
+// notifications that were not in a previous digest, and returns how many
+// digests were sent. A failure for one user does not stop the others.
+func (s *NotificationService) SendDigests(ctx context.Context, m mailer.Mailer) (int, error) {
+	recipients, err := s.store.ListDigestRecipients()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list digest recipients: %w", err)
+	}
+
+	sent := 0
+	for _, recipient := range recipients {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		pending, err := s.store.ListUndigested(recipient.UserID)
+		if err != nil {
+			log.Printf("Failed to load notifications for digest to %s: %v", recipient.UserID, err)
+			continue
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		if err := m.Send(ctx, BuildNotificationDigest(recipient, pending)); err != nil {
+			log.Printf("Failed to send notification digest to %s: %v", recipient.UserID, err)
+			continue
+		}
+
+		ids := make([]string, len(pending))
+		for i, n := range pending {
+			ids[i] = n.ID
+		}
+		if err := s.store.MarkEmailed(recipient.UserID, ids, s.now()); err != nil {
+			log.Printf("Failed to record notification digest for %s: %v", recipient.UserID, err)
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// BuildNotificationDigest renders a digest email of notifications
+func BuildNotificationDigest(recipient *types.NotificationRecipient, notifications []*types.Notification) *mailer.Message {
+	var body strings.Builder
+	name := recipient.Name
+	if name == "" {
+		name = recipient.Email
+	}
+	fmt.Fprintf(&body, "Hello %s,\n\nYou have %d unread gateway notification(s):\n\n", name, len(notifications))
+	for _, n := range notifications {
+		fmt.Fprintf(&body, "[%s] %s\n", strings.ToUpper(n.Severity), n.Title)
+		if n.Message != "" {
+			fmt.Fprintf(&body, "  %s\n", n.Message)
+		}
+		fmt.Fprintf(&body, "  %s\n\n", n.CreatedAt.UTC().Format(time.RFC1123))
+	}
+	body.WriteString("Open the gateway dashboard to review and dismiss them.\n")
+
+	return &mailer.Message{
+		To:      recipient.Email,
+		Subject: fmt.Sprintf("Omnimesh Gateway: %d unread notification(s)", len(notifications)),
+		Body:    body.String(),
+	}
+}
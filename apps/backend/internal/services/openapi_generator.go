@@ -30,9 +30,25 @@ type OpenAPISpec struct {
 
 // Info represents the info section of OpenAPI spec
 type Info struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Version     string `json:"version"`
+	Contact     *Contact `json:"contact,omitempty"`
+	Logo        *Logo    `json:"x-logo,omitempty"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Version     string   `json:"version"`
+}
+
+// Contact represents the contact information in OpenAPI spec
+type Contact struct {
+	Name  string `json:"name,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// Logo represents the x-logo extension read by docs renderers such as Redoc
+type Logo struct {
+	URL             string `json:"url"`
+	AltText         string `json:"altText,omitempty"`
+	BackgroundColor string `json:"backgroundColor,omitempty"`
 }
 
 // Server represents a server in OpenAPI spec
@@ -84,18 +100,31 @@ type Response struct {
 
 // MediaType represents a media type in OpenAPI spec
 type MediaType struct {
-	Schema Schema `json:"schema"`
+	Schema  Schema      `json:"schema"`
+	Example interface{} `json:"example,omitempty"`
 }
 
 // Schema represents a schema in OpenAPI spec
 type Schema struct {
-	Type       string            `json:"type,omitempty"`
-	Format     string            `json:"format,omitempty"`
-	Properties map[string]Schema `json:"properties,omitempty"`
-	Required   []string          `json:"required,omitempty"`
-	Items      *Schema           `json:"items,omitempty"`
-	Ref        string            `json:"$ref,omitempty"`
-	Example    interface{}       `json:"example,omitempty"`
+	Type                 string            `json:"type,omitempty"`
+	Format               string            `json:"format,omitempty"`
+	Description          string            `json:"description,omitempty"`
+	Properties           map[string]Schema `json:"properties,omitempty"`
+	Required             []string          `json:"required,omitempty"`
+	Items                *Schema           `json:"items,omitempty"`
+	AdditionalProperties *Schema           `json:"additionalProperties,omitempty"`
+	OneOf                []Schema          `json:"oneOf,omitempty"`
+	AnyOf                []Schema          `json:"anyOf,omitempty"`
+	Enum                 []interface{}     `json:"enum,omitempty"`
+	Default              interface{}       `json:"default,omitempty"`
+	Minimum              *float64          `json:"minimum,omitempty"`
+	Maximum              *float64          `json:"maximum,omitempty"`
+	MinLength            *int              `json:"minLength,omitempty"`
+	MaxLength            *int              `json:"maxLength,omitempty"`
+	Pattern              string            `json:"pattern,omitempty"`
+	Nullable             bool              `json:"nullable,omitempty"`
+	Ref                  string            `json:"$ref,omitempty"`
+	Example              interface{}       `json:"example,omitempty"`
 }
 
 // Components represents the components section of OpenAPI spec
@@ -136,14 +165,14 @@ func (g *OpenAPIGenerator) GenerateSpec(endpoint *types.Endpoint, namespace *typ
 	spec := &OpenAPISpec{
 		OpenAPI: "3.0.0",
 		Info: Info{
-			Title:       fmt.Sprintf("%s - Omnimesh AI Gateway", endpoint.Name),
+			Title:       fmt.Sprintf("%s - %s", endpoint.Name, types.DefaultProductName),
 			Description: g.generateDescription(endpoint, namespace),
 			Version:     "1.0.0",
 		},
 		Servers: []Server{
 			{
 				URL:         fmt.Sprintf("%s/api/public/endpoints/%s/api", g.baseURL, endpoint.Name),
-				Description: "Omnimesh AI Gateway Endpoint API",
+				Description: types.DefaultProductName + " Endpoint API",
 			},
 		},
 		Paths:      make(map[string]PathItem),
@@ -167,6 +196,33 @@ func (g *OpenAPIGenerator) GenerateSpec(endpoint *types.Endpoint, namespace *typ
 	return spec
 }
 
+// ApplyBranding presents spec under the branding of the endpoint's
+// organization
+func (g *OpenAPIGenerator) ApplyBranding(spec *OpenAPISpec, endpoint *types.Endpoint, branding *types.OrganizationBranding) {
+	if branding == nil {
+		return
+	}
+	name := branding.DisplayName()
+	spec.Info.Title = fmt.Sprintf("%s - %s", endpoint.Name, name)
+	for i := range spec.Servers {
+		spec.Servers[i].Description = name + " Endpoint API"
+	}
+	if branding.HasContact() {
+		spec.Info.Contact = &Contact{
+			Name:  branding.ContactName,
+			URL:   branding.ContactURL,
+			Email: branding.ContactEmail,
+		}
+	}
+	if branding.LogoURL != "" {
+		spec.Info.Logo = &Logo{
+			URL:             branding.LogoURL,
+			AltText:         name,
+			BackgroundColor: branding.PrimaryColor,
+		}
+	}
+}
+
 func (g *OpenAPIGenerator) generateDescription(endpoint *types.Endpoint, namespace *types.Namespace) string {
 	desc := endpoint.Description
 	if desc == "" {
@@ -188,37 +244,99 @@ func (g *OpenAPIGenerator) generateComponents(endpoint *types.Endpoint) Componen
 
 	// Add common schemas
 	components.Schemas["ToolRequest"] = Schema{
-		Type: "object",
-		Properties: map[string]Schema{
-			"arguments": {
-				Type: "object",
-			},
-		},
+		Type:                 "object",
+		Description:          "Arguments of the tool, as described by its input schema",
+		AdditionalProperties: &Schema{},
 	}
 
 	components.Schemas["ToolResponse"] = Schema{
-		Type: "object",
+		Type:     "object",
+		Required: []string{"success"},
 		Properties: map[string]Schema{
 			"success": {
 				Type: "boolean",
 			},
 			"result": {
-				Type: "object",
+				Description: "What the tool returned",
 			},
 			"error": {
 				Type: "string",
 			},
+			"violations": {
+				Type:        "array",
+				Description: "Ways the arguments or result failed the tool's schemas",
+				Items: &Schema{
+					Ref: "#/components/schemas/SchemaViolation",
+				},
+			},
 		},
 	}
 
-	components.Schemas["Error"] = Schema{
+	components.Schemas["SchemaViolation"] = Schema{
 		Type: "object",
 		Properties: map[string]Schema{
-			"error": {
+			"path": {
+				Type:        "string",
+				Description: "JSON pointer to the offending value",
+			},
+			"message": {
 				Type: "string",
 			},
+		},
+	}
+
+	// Endpoint errors come in two shapes: a short error with details from the
+	// endpoint itself, and the gateway's structured error from policy checks
+	components.Schemas["Error"] = Schema{
+		Description: "Error returned by the endpoint",
+		OneOf: []Schema{
+			{Ref: "#/components/schemas/EndpointError"},
+			{Ref: "#/components/schemas/GatewayError"},
+		},
+	}
+
+	components.Schemas["EndpointError"] = Schema{
+		Type:     "object",
+		Required: []string{"error"},
+		Properties: map[string]Schema{
+			"error": {
+				Type:    "string",
+				Example: "Unauthorized",
+			},
 			"details": {
-				Type: "string",
+				Type:    "string",
+				Example: "No valid authentication provided",
+			},
+			"retry_after": {
+				Type:        "integer",
+				Description: "Unix time the rate limit resets at, on 429 responses",
+			},
+		},
+	}
+
+	components.Schemas["GatewayError"] = Schema{
+		Type:     "object",
+		Required: []string{"success", "error"},
+		Properties: map[string]Schema{
+			"success": {
+				Type:    "boolean",
+				Example: false,
+			},
+			"error": {
+				Type:     "object",
+				Required: []string{"code", "message"},
+				Properties: map[string]Schema{
+					"code": {
+						Type:    "string",
+						Example: types.ErrCodeAccessDenied,
+					},
+					"message": {
+						Type: "string",
+					},
+					"details": {
+						Type: "string",
+					},
+				},
 			},
 		},
 	}
@@ -227,8 +345,8 @@ func (g *OpenAPIGenerator) generateComponents(endpoint *types.Endpoint) Componen
 	if endpoint.EnableAPIKeyAuth {
 		components.SecuritySchemes["api_key"] = SecurityScheme{
 			Type:        "apiKey",
-			Description: "API Key authentication",
-			Name:        "Authorization",
+			Description: "API key in the X-API-Key header",
+			Name:        "X-API-Key",
 			In:          "header",
 		}
 
@@ -242,7 +360,19 @@ func (g *OpenAPIGenerator) generateComponents(endpoint *types.Endpoint) Componen
 		}
 	}
 
+	if endpoint.EnableAPIKeyAuth || endpoint.EnableOAuth {
+		components.SecuritySchemes["bearer"] = SecurityScheme{
+			Type:        "http",
+			Scheme:      "bearer",
+			Description: g.bearerDescription(endpoint),
+		}
+	}
+
 	if endpoint.EnableOAuth {
+		scopes := map[string]string{
+			types.ScopeRead:  "Read access to tools",
+			types.ScopeWrite: "Execute tools",
+		}
 		components.SecuritySchemes["oauth2"] = SecurityScheme{
 			Type:        "oauth2",
 			Description: "OAuth 2.0 authentication",
@@ -250,10 +380,11 @@ func (g *OpenAPIGenerator) generateComponents(endpoint *types.Endpoint) Componen
 				AuthorizationCode: &Flow{
 					AuthorizationURL: fmt.Sprintf("%s/oauth/authorize", g.baseURL),
 					TokenURL:         fmt.Sprintf("%s/oauth/token", g.baseURL),
-					Scopes: map[string]string{
-						"read":  "Read access to tools",
-						"write": "Execute tools",
-					},
+					Scopes:           scopes,
+				},
+				ClientCredentials: &Flow{
+					TokenURL: fmt.Sprintf("%s/oauth/token", g.baseURL),
+					Scopes:   scopes,
 				},
 			},
 		}
@@ -262,6 +393,19 @@ func (g *OpenAPIGenerator) generateComponents(endpoint *types.Endpoint) Componen
 	return components
 }
 
+func (g *OpenAPIGenerator) bearerDescription(endpoint *types.Endpoint) string {
+	switch {
+	case endpoint.EnableAPIKeyAuth && endpoint.EnableOAuth:
+		return "API key or OAuth 2.0 access token in the Authorization header"
+	case endpoint.EnableOAuth:
+		return "OAuth 2.0 access token in the Authorization header"
+	default:
+		return "API key in the Authorization header"
+	}
+}
+
+// generateSecurityRequirements lists the endpoint's auth methods as
+// alternatives; any one of them authenticates a request
 func (g *OpenAPIGenerator) generateSecurityRequirements(endpoint *types.Endpoint) []SecurityRequirement {
 	var requirements []SecurityRequirement
 
@@ -277,9 +421,15 @@ func (g *OpenAPIGenerator) generateSecurityRequirements(endpoint *types.Endpoint
 		}
 	}
 
+	if endpoint.EnableAPIKeyAuth || endpoint.EnableOAuth {
+		requirements = append(requirements, SecurityRequirement{
+			"bearer": {},
+		})
+	}
+
 	if endpoint.EnableOAuth {
 		requirements = append(requirements, SecurityRequirement{
-			"oauth2": {"read", "write"},
+			"oauth2": {types.ScopeRead, types.ScopeWrite},
 		})
 	}
 
@@ -287,6 +437,37 @@ func (g *OpenAPIGenerator) generateSecurityRequirements(endpoint *types.Endpoint
 }
 
 func (g *OpenAPIGenerator) generateToolPath(tool types.NamespaceTool, endpoint *types.Endpoint) PathItem {
+	requestSchema := Schema{Ref: "#/components/schemas/ToolRequest"}
+	if tool.InputSchema != nil {
+		requestSchema = schemaFromJSONSchema(tool.InputSchema)
+	}
+
+	responseExample := map[string]interface{}{"success": true}
+	if tool.OutputSchema != nil {
+		responseExample["result"] = exampleFromSchema(schemaFromJSONSchema(tool.OutputSchema))
+	}
+
+	responses := map[string]Response{
+		"200": {
+			Description: "Successful tool execution",
+			Content: map[string]MediaType{
+				"application/json": {
+					Schema: Schema{
+						Ref: "#/components/schemas/ToolResponse",
+					},
+					Example: responseExample,
+				},
+			},
+		},
+		"400": errorResponse("Bad request"),
+		"403": errorResponse("Forbidden by a network, namespace or residency policy"),
+		"429": errorResponse("Rate limit exceeded"),
+		"500": errorResponse("Tool execution failed"),
+	}
+	if !endpoint.EnablePublicAccess {
+		responses["401"] = errorResponse("Unauthorized")
+	}
+
 	operation := &Operation{
 		Summary:     fmt.Sprintf("Execute %s", tool.ToolName),
 		Description: tool.Description,
@@ -296,54 +477,12 @@ func (g *OpenAPIGenerator) generateToolPath(tool types.NamespaceTool, endpoint *
 			Required:    true,
 			Content: map[string]MediaType{
 				"application/json": {
-					Schema: Schema{
-						Ref: "#/components/schemas/ToolRequest",
-					},
-				},
-			},
-		},
-		Responses: map[string]Response{
-			"200": {
-				Description: "Successful tool execution",
-				Content: map[string]MediaType{
-					"application/json": {
-						Schema: Schema{
-							Ref: "#/components/schemas/ToolResponse",
-						},
-					},
-				},
-			},
-			"400": {
-				Description: "Bad request",
-				Content: map[string]MediaType{
-					"application/json": {
-						Schema: Schema{
-							Ref: "#/components/schemas/Error",
-						},
-					},
-				},
-			},
-			"401": {
-				Description: "Unauthorized",
-				Content: map[string]MediaType{
-					"application/json": {
-						Schema: Schema{
-							Ref: "#/components/schemas/Error",
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]MediaType{
-					"application/json": {
-						Schema: Schema{
-							Ref: "#/components/schemas/Error",
-						},
-					},
+					Schema:  requestSchema,
+					Example: exampleFromSchema(requestSchema),
 				},
 			},
 		},
+		Responses: responses,
 	}
 
 	// Add security if not public
@@ -356,6 +495,33 @@ func (g *OpenAPIGenerator) generateToolPath(tool types.NamespaceTool, endpoint *
 	}
 }
 
+// ApplyRecordedExamples replaces the derived request examples of tools with
+// arguments recorded from real executions, keyed by tool name
+func (g *OpenAPIGenerator) ApplyRecordedExamples(spec *OpenAPISpec, examples map[string]map[string]interface{}) {
+	for toolName, arguments := range examples {
+		item, ok := spec.Paths[fmt.Sprintf("/tools/%s", toolName)]
+		if !ok || item.Post == nil || item.Post.RequestBody == nil {
+			continue
+		}
+		media := item.Post.RequestBody.Content["application/json"]
+		media.Example = arguments
+		item.Post.RequestBody.Content["application/json"] = media
+	}
+}
+
+func errorResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content: map[string]MediaType{
+			"application/json": {
+				Schema: Schema{
+					Ref: "#/components/schemas/Error",
+				},
+			},
+		},
+	}
+}
+
 func (g *OpenAPIGenerator) generateToolsListPath(endpoint *types.Endpoint) PathItem {
 	operation := &Operation{
 		Summary:     "List available tools",
@@ -383,24 +549,22 @@ func (g *OpenAPIGenerator) generateToolsListPath(endpoint *types.Endpoint) PathI
 											"server": {
 												Type: "string",
 											},
+											"status": {
+												Type: "string",
+											},
 										},
 									},
 								},
+								"total": {
+									Type: "integer",
+								},
 							},
 						},
 					},
 				},
 			},
-			"401": {
-				Description: "Unauthorized",
-				Content: map[string]MediaType{
-					"application/json": {
-						Schema: Schema{
-							Ref: "#/components/schemas/Error",
-						},
-					},
-				},
-			},
+			"401": errorResponse("Unauthorized"),
+			"429": errorResponse("Rate limit exceeded"),
 		},
 	}
 
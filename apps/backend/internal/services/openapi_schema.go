@@ -0,0 +1,203 @@
+package services
+
+import "sort"
+
+// maxExampleDepth bounds how deep examples are built for nested schemas
+const maxExampleDepth = 8
+
+// schemaFromJSONSchema converts a tool's JSON Schema to an OpenAPI schema.
+// Keywords OpenAPI 3.0 has no place for are dropped, and a type list such
+// as ["string", "null"] becomes a nullable string. References are dropped
+// as they point into the tool's own document.
+func schemaFromJSONSchema(raw map[string]interface{}) Schema {
+	var schema Schema
+
+	switch t := raw["type"].(type) {
+	case string:
+		schema.Type = t
+	case []interface{}:
+		for _, item := range t {
+			if name, _ := item.(string); name == "null" {
+				schema.Nullable = true
+			} else if schema.Type == "" {
+				schema.Type = name
+			}
+		}
+	}
+	if nullable, _ := raw["nullable"].(bool); nullable {
+		schema.Nullable = true
+	}
+
+	schema.Description, _ = raw["description"].(string)
+	schema.Format, _ = raw["format"].(string)
+	schema.Pattern, _ = raw["pattern"].(string)
+	schema.Default = raw["default"]
+	schema.Example = raw["example"]
+	if examples, ok := raw["examples"].([]interface{}); ok && len(examples) > 0 && schema.Example == nil {
+		schema.Example = examples[0]
+	}
+	if enum, ok := raw["enum"].([]interface{}); ok {
+		schema.Enum = enum
+	}
+	if value, ok := raw["const"]; ok && schema.Enum == nil {
+		schema.Enum = []interface{}{value}
+	}
+
+	schema.Minimum = jsonNumber(raw["minimum"])
+	schema.Maximum = jsonNumber(raw["maximum"])
+	schema.MinLength = jsonInt(raw["minLength"])
+	schema.MaxLength = jsonInt(raw["maxLength"])
+
+	if properties, ok := raw["properties"].(map[string]interface{}); ok {
+		schema.Properties = make(map[string]Schema, len(properties))
+		for name, property := range properties {
+			if propertySchema, ok := property.(map[string]interface{}); ok {
+				schema.Properties[name] = schemaFromJSONSchema(propertySchema)
+			}
+		}
+		if schema.Type == "" {
+			schema.Type = "object"
+		}
+	}
+	if required, ok := raw["required"].([]interface{}); ok {
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				schema.Required = append(schema.Required, s)
+			}
+		}
+	}
+	if additional, ok := raw["additionalProperties"].(map[string]interface{}); ok {
+		item := schemaFromJSONSchema(additional)
+		schema.AdditionalProperties = &item
+	}
+
+	switch items := raw["items"].(type) {
+	case map[string]interface{}:
+		item := schemaFromJSONSchema(items)
+		schema.Items = &item
+	case []interface{}:
+		if len(items) > 0 {
+			if first, ok := items[0].(map[string]interface{}); ok {
+				item := schemaFromJSONSchema(first)
+				schema.Items = &item
+			}
+		}
+	}
+	if schema.Type == "array" && schema.Items == nil {
+		schema.Items = &Schema{}
+	}
+
+	schema.OneOf = schemaList(raw["oneOf"])
+	schema.AnyOf = schemaList(raw["anyOf"])
+	return schema
+}
+
+func schemaList(raw interface{}) []Schema {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+	var schemas []Schema
+	for _, item := range list {
+		if itemSchema, ok := item.(map[string]interface{}); ok {
+			schemas = append(schemas, schemaFromJSONSchema(itemSchema))
+		}
+	}
+	return schemas
+}
+
+func jsonNumber(raw interface{}) *float64 {
+	if n, ok := raw.(float64); ok {
+		return &n
+	}
+	return nil
+}
+
+func jsonInt(raw interface{}) *int {
+	if n, ok := raw.(float64); ok {
+		i := int(n)
+		return &i
+	}
+	return nil
+}
+
+// exampleFromSchema builds an example value for schema from its own
+// examples, defaults and enums, falling back to a placeholder of its type
+func exampleFromSchema(schema Schema) interface{} {
+	return exampleAtDepth(schema, 0)
+}
+
+func exampleAtDepth(schema Schema, depth int) interface{} {
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case schema.Ref != "" || depth > maxExampleDepth:
+		return nil
+	case len(schema.OneOf) > 0:
+		return exampleAtDepth(schema.OneOf[0], depth+1)
+	case len(schema.AnyOf) > 0:
+		return exampleAtDepth(schema.AnyOf[0], depth+1)
+	}
+
+	switch schema.Type {
+	case "object", "":
+		if schema.Type == "" && len(schema.Properties) == 0 {
+			return nil
+		}
+		example := make(map[string]interface{}, len(schema.Properties))
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value := exampleAtDepth(schema.Properties[name], depth+1); value != nil {
+				example[name] = value
+			}
+		}
+		return example
+	case "array":
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		if item := exampleAtDepth(*schema.Items, depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "string":
+		return stringExample(schema.Format)
+	case "integer":
+		if schema.Minimum != nil {
+			return int64(*schema.Minimum)
+		}
+		return 0
+	case "number":
+		if schema.Minimum != nil {
+			return *schema.Minimum
+		}
+		return 0
+	case "boolean":
+		return false
+	}
+	return nil
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	}
+	return "string"
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// parquetType is the physical type of a Parquet column
+type parquetType int
+
+// Column types the Parquet writer supports. Strings are UTF8 byte arrays
+// and timestamps are INT64 milliseconds since the epoch.
+const (
+	parquetString parquetType = iota
+	parquetTimestamp
+	parquetInt32
+	parquetDouble
+)
+
+// parquetColumn describes one required column of a Parquet file
+type parquetColumn struct {
+	name string
+	typ  parquetType
+}
+
+// Parquet format constants, from parquet.thrift
+const (
+	parquetMagic              = "PAR1"
+	parquetPhysicalInt32      = 1
+	parquetPhysicalInt64      = 2
+	parquetPhysicalDouble     = 5
+	parquetPhysicalByteArray  = 6
+	parquetConvertedUTF8      = 0
+	parquetConvertedTimestamp = 9
+	parquetRepetitionRequired = 0
+	parquetEncodingPlain      = 0
+	parquetEncodingRLE        = 3
+	parquetCodecUncompressed  = 0
+	parquetPageTypeData       = 0
+)
+
+// parquetWriter writes rows as a Parquet file with uncompressed, PLAIN
+// encoded required columns. Rows are buffered column by column and flushed
+// as a row group every rowGroupSize rows, so memory stays bounded however
+// large the export.
+type parquetWriter struct {
+	w            io.Writer
+	columns      []parquetColumn
+	buffers      []bytes.Buffer
+	rowGroups    []parquetRowGroup
+	rowGroupSize int
+	buffered     int
+	offset       int64
+	rows         int64
+}
+
+type parquetRowGroup struct {
+	chunks    []parquetChunk
+	totalSize int64
+	rows      int64
+}
+
+type parquetChunk struct {
+	offset int64
+	size   int64
+}
+
+// newParquetWriter starts a Parquet file on w
+func newParquetWriter(w io.Writer, columns []parquetColumn, rowGroupSize int) (*parquetWriter, error) {
+	if _, err := io.WriteString(w, parquetMagic); err != nil {
+		return nil, err
+	}
+	return &parquetWriter{
+		w:            w,
+		columns:      columns,
+		buffers:      make([]bytes.Buffer, len(columns)),
+		rowGroupSize: rowGroupSize,
+		offset:       int64(len(parquetMagic)),
+	}, nil
+}
+
+// WriteRow appends a row. values must match the columns: string, time.Time,
+// int32 and float64 respectively.
+func (p *parquetWriter) WriteRow(values []interface{}) error {
+	if len(values) != len(p.columns) {
+		return fmt.Errorf("row has %d values for %d columns", len(values), len(p.columns))
+	}
+	for i, column := range p.columns {
+		buf := &p.buffers[i]
+		switch column.typ {
+		case parquetString:
+			s, _ := values[i].(string)
+			_ = binary.Write(buf, binary.LittleEndian, uint32(len(s)))
+			buf.WriteString(s)
+		case parquetTimestamp:
+			t, _ := values[i].(time.Time)
+			_ = binary.Write(buf, binary.LittleEndian, t.UnixMilli())
+		case parquetInt32:
+			n, _ := values[i].(int32)
+			_ = binary.Write(buf, binary.LittleEndian, n)
+		case parquetDouble:
+			f, _ := values[i].(float64)
+			_ = binary.Write(buf, binary.LittleEndian, math.Float64bits(f))
+		}
+	}
+
+	p.buffered++
+	p.rows++
+	if p.buffered >= p.rowGroupSize {
+		return p.flush()
+	}
+	return nil
+}
+
+// Size returns the bytes written so far plus the rows still buffered
+func (p *parquetWriter) Size() int64 {
+	size := p.offset
+	for i := range p.buffers {
+		size += int64(p.buffers[i].Len())
+	}
+	return size
+}
+
+// Close flushes the last row group and writes the file footer
+func (p *parquetWriter) Close() error {
+	if p.buffered > 0 || len(p.rowGroups) == 0 {
+		if err := p.flush(); err != nil {
+			return err
+		}
+	}
+
+	footer := p.fileMetadata()
+	if _, err := p.w.Write(footer); err != nil {
+		return err
+	}
+	if err := binary.Write(p.w, binary.LittleEndian, uint32(len(footer))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(p.w, parquetMagic)
+	return err
+}
+
+// flush writes the buffered rows as a row group with one data page per column
+func (p *parquetWriter) flush() error {
+	group := parquetRowGroup{rows: int64(p.buffered)}
+	for i := range p.columns {
+		data := p.buffers[i].Bytes()
+
+		header := &thriftWriter{}
+		header.i32Field(1, parquetPageTypeData)
+		header.i32Field(2, int32(len(data)))
+		header.i32Field(3, int32(len(data)))
+		header.structField(5)
+		header.i32Field(1, int32(p.buffered))
+		header.i32Field(2, parquetEncodingPlain)
+		header.i32Field(3, parquetEncodingRLE)
+		header.i32Field(4, parquetEncodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		if _, err := p.w.Write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if _, err := p.w.Write(data); err != nil {
+			return err
+		}
+
+		chunk := parquetChunk{offset: p.offset, size: int64(header.buf.Len() + len(data))}
+		p.offset += chunk.size
+		group.totalSize += chunk.size
+		group.chunks = append(group.chunks, chunk)
+		p.buffers[i].Reset()
+	}
+
+	p.rowGroups = append(p.rowGroups, group)
+	p.buffered = 0
+	return nil
+}
+
+// fileMetadata encodes the FileMetaData footer
+func (p *parquetWriter) fileMetadata() []byte {
+	t := &thriftWriter{}
+	t.i32Field(1, 1)
+
+	t.listField(2, thriftStruct, len(p.columns)+1)
+	t.beginStruct()
+	t.binaryField(4, "schema")
+	t.i32Field(5, int32(len(p.columns)))
+	t.endStruct()
+	for _, column := range p.columns {
+		physical, converted := column.typ.physical()
+		t.beginStruct()
+		t.i32Field(1, physical)
+		t.i32Field(3, parquetRepetitionRequired)
+		t.binaryField(4, column.name)
+		if converted >= 0 {
+			t.i32Field(6, converted)
+		}
+		t.endStruct()
+	}
+
+	t.i64Field(3, p.rows)
+
+	t.listField(4, thriftStruct, len(p.rowGroups))
+	for _, group := range p.rowGroups {
+		t.beginStruct()
+		t.listField(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			physical, _ := p.columns[i].typ.physical()
+			t.beginStruct()
+			t.i64Field(2, chunk.offset)
+			t.structField(3)
+			t.i32Field(1, physical)
+			t.listField(2, thriftI32, 2)
+			t.varint(parquetEncodingPlain)
+			t.varint(parquetEncodingRLE)
+			t.listField(3, thriftBinary, 1)
+			t.binary(p.columns[i].name)
+			t.i32Field(4, parquetCodecUncompressed)
+			t.i64Field(5, group.rows)
+			t.i64Field(6, chunk.size)
+			t.i64Field(7, chunk.size)
+			t.i64Field(9, chunk.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64Field(2, group.totalSize)
+		t.i64Field(3, group.rows)
+		t.endStruct()
+	}
+
+	t.binaryField(6, "omnimesh-gateway")
+	t.endStruct()
+	return t.buf.Bytes()
+}
+
+// physical returns the Parquet physical and converted type of a column
+// type; the converted type is -1 when there is none
+func (t parquetType) physical() (int32, int32) {
+	switch t {
+	case parquetTimestamp:
+		return parquetPhysicalInt64, parquetConvertedTimestamp
+	case parquetInt32:
+		return parquetPhysicalInt32, -1
+	case parquetDouble:
+		return parquetPhysicalDouble, -1
+	default:
+		return parquetPhysicalByteArray, parquetConvertedUTF8
+	}
+}
+
+// Thrift compact protocol type identifiers
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs in the Thrift compact protocol, which
+// Parquet uses for page headers and the file footer
+type thriftWriter struct {
+	buf   bytes.Buffer
+	stack []int16
+	last  int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(b[:], v)
+	t.buf.Write(b[:n])
+}
+
+func (t *thriftWriter) binary(s string) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], uint64(len(s)))
+	t.buf.Write(b[:n])
+	t.buf.WriteString(s)
+}
+
+func (t *thriftWriter) i32Field(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64Field(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binaryField(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary(s)
+}
+
+// listField starts a list field. Struct elements are each written between
+// beginStruct and endStruct; the list itself needs no terminator.
+func (t *thriftWriter) listField(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xf0 | elemType)
+		var b [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(b[:], uint64(size))
+		t.buf.Write(b[:n])
+	}
+}
+
+// structField starts a nested struct field, closed by endStruct
+func (t *thriftWriter) structField(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.beginStruct()
+}
+
+// beginStruct starts a struct, numbering its fields afresh
+func (t *thriftWriter) beginStruct() {
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+// endStruct closes the current struct and resumes the enclosing one
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0)
+	if n := len(t.stack); n > 0 {
+		t.last = t.stack[n-1]
+		t.stack = t.stack[:n-1]
+	}
+}
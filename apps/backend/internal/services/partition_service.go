@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// defaultPartitionPremake is how many months of partitions are kept ready
+// ahead of the current one
+const defaultPartitionPremake = 2
+
+// PartitionStore manages the partitions of range-partitioned tables
+type PartitionStore interface {
+	CreateMonthlyPartition(table string, month time.Time) (bool, error)
+	ListPartitions(table string) ([]*types.TablePartition, error)
+	HasHeldRows(partition string) (bool, error)
+	DetachPartition(table, partition string) error
+	DropPartition(partition string) error
+	LongestLogRetentionDays() (int, error)
+}
+
+// partitionedTable is a table partitioned by month. Log tables hold rows of
+// organizations, which legal holds and per-organization retention apply to.
+type partitionedTable struct {
+	name string
+	logs bool
+}
+
+// partitionedTables are the tables whose partitions are maintained
+var partitionedTables = []partitionedTable{
+	{name: "log_index", logs: true},
+	{name: "mcp_message_logs", logs: true},
+	{name: "health_checks"},
+}
+
+// PartitionService keeps monthly partitions of the log and health check
+// tables ready ahead of time and removes those whose rows are all past
+// retention, so that expiring old rows is a cheap metadata operation
+// rather than a bulk delete. Partitions holding rows of an organization
+// under legal hold are kept.
+type PartitionService struct {
+	store                PartitionStore
+	now                  func() time.Time
+	premake              int
+	logRetention         time.Duration
+	healthCheckRetention time.Duration
+	detachOnly           bool
+}
+
+// NewPartitionService creates a database-backed partition service. Log
+// partitions are removed once past logRetention and the log retention of
+// every organization; zero retentions keep partitions forever.
+func NewPartitionService(db *sql.DB, premake int, logRetention, healthCheckRetention time.Duration) *PartitionService {
+	return NewPartitionServiceWithStore(models.NewPartitionModel(db), premake, logRetention, healthCheckRetention)
+}
+
+// NewPartitionServiceWithStore creates a partition service over store
+func NewPartitionServiceWithStore(store PartitionStore, premake int, logRetention, healthCheckRetention time.Duration) *PartitionService {
+	if premake <= 0 {
+		premake = defaultPartitionPremake
+	}
+	return &PartitionService{
+		store:                store,
+		now:                  time.Now,
+		premake:              premake,
+		logRetention:         logRetention,
+		healthCheckRetention: healthCheckRetention,
+	}
+}
+
+// SetDetachOnly leaves expired partitions detached as standalone tables
+// instead of dropping them, for operators who archive them first
+func (s *PartitionService) SetDetachOnly(detachOnly bool) {
+	s.detachOnly = detachOnly
+}
+
+// SetClock replaces the clock partitions are created and expired against
+func (s *PartitionService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Maintain creates upcoming partitions and removes expired ones
+func (s *PartitionService) Maintain(ctx context.Context) (*types.PartitionMaintenance, error) {
+	created, err := s.CreatePartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.RemoveExpired(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.Created = created
+	return result, nil
+}
+
+// CreatePartitions creates the partitions of the current month and the
+// premade months after it that do not exist yet
+func (s *PartitionService) CreatePartitions(ctx context.Context) ([]string, error) {
+	now := s.now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	created := []string{}
+	for _, table := range partitionedTables {
+		for i := 0; i <= s.premake; i++ {
+			start := month.AddDate(0, i, 0)
+			ok, err := s.store.CreateMonthlyPartition(table.name, start)
+			if err != nil {
+				return created, fmt.Errorf("failed to create %s partition for %s: %w", table.name, start.Format("2006-01"), err)
+			}
+			if ok {
+				created = append(created, fmt.Sprintf("%s_p%s", table.name, start.Format("200601")))
+			}
+		}
+	}
+	return created, nil
+}
+
+// RemoveExpired detaches, and unless detach-only drops, every partition
+// whose whole range is past retention
+func (s *PartitionService) RemoveExpired(ctx context.Context) (*types.PartitionMaintenance, error) {
+	result := &types.PartitionMaintenance{Created: []string{}, Detached: []string{}, Dropped: []string{}, Held: []string{}}
+
+	logRetention := s.logRetention
+	if logRetention > 0 {
+		days, err := s.store.LongestLogRetentionDays()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get organization log retention: %w", err)
+		}
+		if orgRetention := time.Duration(days) * 24 * time.Hour; orgRetention > logRetention {
+			logRetention = orgRetention
+		}
+	}
+
+	for _, table := range partitionedTables {
+		retention := s.healthCheckRetention
+		if table.logs {
+			retention = logRetention
+		}
+		if retention <= 0 {
+			continue
+		}
+		cutoff := s.now().Add(-retention)
+
+		partitions, err := s.store.ListPartitions(table.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s partitions: %w", table.name, err)
+		}
+		for _, partition := range partitions {
+			if partition.RangeEnd == nil || partition.RangeEnd.After(cutoff) {
+				continue
+			}
+			if table.logs {
+				held, err := s.store.HasHeldRows(partition.Name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check legal holds on %s: %w", partition.Name, err)
+				}
+				if held {
+					log.Printf("Keeping expired partition %s: it holds logs under legal hold", partition.Name)
+					result.Held = append(result.Held, partition.Name)
+					continue
+				}
+			}
+
+			if err := s.store.DetachPartition(table.name, partition.Name); err != nil {
+				return nil, fmt.Errorf("failed to detach %s: %w", partition.Name, err)
+			}
+			result.Detached = append(result.Detached, partition.Name)
+			if s.detachOnly {
+				continue
+			}
+			if err := s.store.DropPartition(partition.Name); err != nil {
+				return nil, fmt.Errorf("failed to drop %s: %w", partition.Name, err)
+			}
+			result.Dropped = append(result.Dropped, partition.Name)
+		}
+	}
+	return result, nil
+}
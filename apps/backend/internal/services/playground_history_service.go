@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// PlaygroundHistoryStore persists playground executions and the settings
+// governing them. Get and GetSettings return nil when nothing is found.
+type PlaygroundHistoryStore interface {
+	Create(execution *types.PlaygroundExecution) error
+	List(orgID, userID string, query *types.PlaygroundHistoryQuery) ([]*types.PlaygroundExecution, error)
+	Get(orgID, id string) (*types.PlaygroundExecution, error)
+	Delete(orgID, userID, id string) (bool, error)
+	Clear(orgID, userID string) (int64, error)
+	GetSettings(orgID string) (*types.PlaygroundHistorySettings, error)
+	SetSettings(settings *types.PlaygroundHistorySettings) error
+}
+
+// PlaygroundHistoryService keeps each user's history of tool calls made from
+// the endpoint test console and the inspector. History is private to its
+// owner; organization admins can read it, with sensitive arguments masked,
+// only when the organization allows it.
+type PlaygroundHistoryService struct {
+	store PlaygroundHistoryStore
+}
+
+// NewPlaygroundHistoryService creates a database-backed playground history
+// service
+func NewPlaygroundHistoryService(db *sql.DB) *PlaygroundHistoryService {
+	return NewPlaygroundHistoryServiceWithStore(models.NewPlaygroundHistoryModel(db))
+}
+
+// NewPlaygroundHistoryServiceWithStore creates a playground history service
+// over store
+func NewPlaygroundHistoryServiceWithStore(store PlaygroundHistoryStore) *PlaygroundHistoryService {
+	return &PlaygroundHistoryService{store: store}
+}
+
+// GetSettings returns the organization's playground history settings.
+// Organizations without settings record history private to each user.
+func (s *PlaygroundHistoryService) GetSettings(ctx context.Context, orgID string) (*types.PlaygroundHistorySettings, error) {
+	settings, err := s.store.GetSettings(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playground history settings: %w", err)
+	}
+	if settings == nil {
+		settings = &types.PlaygroundHistorySettings{OrganizationID: orgID, Enabled: true}
+	}
+	return settings, nil
+}
+
+// UpdateSettings changes the organization's playground history settings;
+// fields left out of req keep their value
+func (s *PlaygroundHistoryService) UpdateSettings(ctx context.Context, orgID, updatedBy string, req *types.UpdatePlaygroundHistorySettingsRequest) (*types.PlaygroundHistorySettings, error) {
+	settings, err := s.GetSettings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Enabled != nil {
+		settings.Enabled = *req.Enabled
+	}
+	if req.AdminsCanView != nil {
+		settings.AdminsCanView = *req.AdminsCanView
+	}
+	settings.UpdatedBy = updatedBy
+
+	if err := s.store.SetSettings(settings); err != nil {
+		return nil, fmt.Errorf("failed to update playground history settings: %w", err)
+	}
+	return settings, nil
+}
+
+// Record adds an execution to its user's history, unless the organization
+// turned history off. Results too large to keep are replaced by a marker.
+func (s *PlaygroundHistoryService) Record(ctx context.Context, execution *types.PlaygroundExecution) error {
+	if execution.UserID == "" || execution.OrganizationID == "" {
+		return nil
+	}
+	settings, err := s.GetSettings(ctx, execution.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if !settings.Enabled {
+		return nil
+	}
+
+	if execution.Arguments == nil {
+		execution.Arguments = map[string]interface{}{}
+	}
+	if execution.Result != nil {
+		encoded, err := json.Marshal(execution.Result)
+		if err != nil || len(encoded) > types.MaxPlaygroundResultBytes {
+			execution.Result = map[string]interface{}{"truncated": true, "size_bytes": len(encoded)}
+		}
+	}
+
+	if err := s.store.Create(execution); err != nil {
+		return fmt.Errorf("failed to record playground execution: %w", err)
+	}
+	return nil
+}
+
+// ListHistory returns the playground history of query.UserID, or of the
+// viewer when it is unset
+func (s *PlaygroundHistoryService) ListHistory(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, query *types.PlaygroundHistoryQuery) ([]*types.PlaygroundExecution, error) {
+	userID := query.UserID
+	if userID == "" {
+		userID = viewerID
+	}
+	if userID != viewerID {
+		allowed, err := s.adminsCanView(ctx, orgID, viewerIsAdmin)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, types.NewForbiddenError("Playground history is private to each user")
+		}
+	}
+
+	executions, err := s.store.List(orgID, userID, query)
+	if err != nil {
+		return nil, err
+	}
+	if userID != viewerID {
+		for _, execution := range executions {
+			redactPlaygroundExecution(execution)
+		}
+	}
+	return executions, nil
+}
+
+// GetExecution returns an execution from the viewer's history, or from
+// another user's when admins may view it
+func (s *PlaygroundHistoryService) GetExecution(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, id string) (*types.PlaygroundExecution, error) {
+	execution, err := s.store.Get(orgID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playground execution: %w", err)
+	}
+	if execution == nil {
+		return nil, types.NewNotFoundError("Playground execution not found")
+	}
+	if execution.UserID == viewerID {
+		return execution, nil
+	}
+
+	// Other users' executions look missing rather than forbidden
+	allowed, err := s.adminsCanView(ctx, orgID, viewerIsAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, types.NewNotFoundError("Playground execution not found")
+	}
+	redactPlaygroundExecution(execution)
+	return execution, nil
+}
+
+// OwnExecution returns an execution from the user's own history, as needed
+// to re-run it
+func (s *PlaygroundHistoryService) OwnExecution(ctx context.Context, orgID, userID, id string) (*types.PlaygroundExecution, error) {
+	execution, err := s.store.Get(orgID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playground execution: %w", err)
+	}
+	if execution == nil || execution.UserID != userID {
+		return nil, types.NewNotFoundError("Playground execution not found")
+	}
+	return execution, nil
+}
+
+// DeleteExecution removes an execution from the user's own history
+func (s *PlaygroundHistoryService) DeleteExecution(ctx context.Context, orgID, userID, id string) error {
+	deleted, err := s.store.Delete(orgID, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete playground execution: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("Playground execution not found")
+	}
+	return nil
+}
+
+// ClearHistory removes the user's whole playground history
+func (s *PlaygroundHistoryService) ClearHistory(ctx context.Context, orgID, userID string) (int64, error) {
+	cleared, err := s.store.Clear(orgID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear playground history: %w", err)
+	}
+	return cleared, nil
+}
+
+func (s *PlaygroundHistoryService) adminsCanView(ctx context.Context, orgID string, viewerIsAdmin bool) (bool, error) {
+	if !viewerIsAdmin {
+		return false, nil
+	}
+	settings, err := s.GetSettings(ctx, orgID)
+	if err != nil {
+		return false, err
+	}
+	return settings.AdminsCanView, nil
+}
+
+// redactPlaygroundExecution masks sensitive arguments before an execution
+// is shown to someone other than its owner
+func redactPlaygroundExecution(execution *types.PlaygroundExecution) {
+	params, paths := RedactMessageParams("tools/call", map[string]interface{}{"arguments": execution.Arguments}, nil)
+	execution.Arguments, _ = params["arguments"].(map[string]interface{})
+	execution.RedactedFields = paths
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// planTierCacheTTL bounds how long a plan change takes to affect scheduling
+const planTierCacheTTL = 5 * time.Minute
+
+// PlanLookup returns an organization's plan_type
+type PlanLookup func(ctx context.Context, orgID string) (string, error)
+
+type cachedPlan struct {
+	loadedAt time.Time
+	class    string
+}
+
+// PriorityService maps organizations to scheduling priority classes by
+// plan tier. Lookups are cached; failures fall back to the lowest class.
+type PriorityService struct {
+	lookup PlanLookup
+	cache  map[string]cachedPlan
+	mu     sync.RWMutex
+}
+
+// NewPriorityService creates a priority service backed by the organizations table
+func NewPriorityService(db *sql.DB) *PriorityService {
+	return NewPriorityServiceWithLookup(func(ctx context.Context, orgID string) (string, error) {
+		var plan string
+		err := db.QueryRowContext(ctx, `SELECT plan_type FROM organizations WHERE id = $1`, orgID).Scan(&plan)
+		return plan, err
+	})
+}
+
+// NewPriorityServiceWithLookup creates a priority service over lookup
+func NewPriorityServiceWithLookup(lookup PlanLookup) *PriorityService {
+	return &PriorityService{
+		lookup: lookup,
+		cache:  make(map[string]cachedPlan),
+	}
+}
+
+// PriorityClass returns the priority class for an organization
+func (s *PriorityService) PriorityClass(ctx context.Context, orgID string) string {
+	if orgID == "" {
+		return types.PriorityClassFree
+	}
+
+	s.mu.RLock()
+	cached, ok := s.cache[orgID]
+	s.mu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < planTierCacheTTL {
+		return cached.class
+	}
+
+	plan, err := s.lookup(ctx, orgID)
+	if err == sql.ErrNoRows {
+		plan, err = "", nil
+	}
+	if err != nil {
+		if ok {
+			return cached.class
+		}
+		return types.PriorityClassFree
+	}
+
+	class := types.PriorityClassForPlan(plan)
+	s.mu.Lock()
+	s.cache[orgID] = cachedPlan{class: class, loadedAt: time.Now()}
+	s.mu.Unlock()
+
+	return class
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/ratelimit"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	// rateLimitRuleCacheTTL is how long an organization's active rules are
+	// reused before being read again, and so how long changes made on
+	// another instance take to apply
+	rateLimitRuleCacheTTL = 30 * time.Second
+	// maxRateLimitWindow is the longest window a rule can count over
+	maxRateLimitWindow = 24 * time.Hour
+)
+
+// RateLimitRuleStore persists organizations' rate limit rules
+type RateLimitRuleStore interface {
+	ListRules(orgID string, activeOnly bool) ([]*types.RateLimitRule, error)
+	GetRule(orgID, id string) (*types.RateLimitRule, error)
+	CreateRule(rule *types.RateLimitRule) error
+	UpdateRule(rule *types.RateLimitRule) error
+	DeleteRule(orgID, id string) (bool, error)
+}
+
+type cachedRateLimitRules struct {
+	expires time.Time
+	rules   []*types.RateLimitRule
+}
+
+// RateLimitService manages organizations' rate limit rules and counts
+// requests against them
+type RateLimitService struct {
+	store   RateLimitRuleStore
+	limiter ratelimit.Limiter
+	now     func() time.Time
+	cache   map[string]*cachedRateLimitRules
+	mu      sync.Mutex
+}
+
+// NewRateLimitService creates a database-backed rate limit service counting
+// requests with limiter
+func NewRateLimitService(db *sql.DB, limiter ratelimit.Limiter) *RateLimitService {
+	return NewRateLimitServiceWithStore(models.NewRateLimitRuleModel(db), limiter)
+}
+
+// NewRateLimitServiceWithStore creates a rate limit service over store
+func NewRateLimitServiceWithStore(store RateLimitRuleStore, limiter ratelimit.Limiter) *RateLimitService {
+	return &RateLimitService{
+		store:   store,
+		limiter: limiter,
+		now:     time.Now,
+		cache:   make(map[string]*cachedRateLimitRules),
+	}
+}
+
+// SetClock replaces the clock rule caching and retry times are measured
+// against
+func (s *RateLimitService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// ListRules returns the organization's rate limit rules
+func (s *RateLimitService) ListRules(ctx context.Context, orgID string) ([]*types.RateLimitRule, error) {
+	rules, err := s.store.ListRules(orgID, false)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list rate limit rules: " + err.Error())
+	}
+	return rules, nil
+}
+
+// GetRule returns a rate limit rule of the organization
+func (s *RateLimitService) GetRule(ctx context.Context, orgID, id string) (*types.RateLimitRule, error) {
+	rule, err := s.store.GetRule(orgID, id)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get rate limit rule: " + err.Error())
+	}
+	if rule == nil {
+		return nil, types.NewNotFoundError("Rate limit rule not found")
+	}
+	return rule, nil
+}
+
+// CreateRule adds a rate limit rule to the organization
+func (s *RateLimitService) CreateRule(ctx context.Context, orgID string, req *types.CreateRateLimitRuleRequest) (*types.RateLimitRule, error) {
+	rule := &types.RateLimitRule{
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Description:    req.Description,
+		Type:           req.Type,
+		TargetID:       req.TargetID,
+		Algorithm:      req.Algorithm,
+		Limit:          req.Limit,
+		WindowSeconds:  req.WindowSeconds,
+		Burst:          req.Burst,
+		Priority:       req.Priority,
+		IsActive:       true,
+	}
+	if err := s.validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.CreateRule(rule); err != nil {
+		return nil, types.NewInternalError("Failed to create rate limit rule: " + err.Error())
+	}
+	s.invalidate(orgID)
+	return rule, nil
+}
+
+// UpdateRule changes a rate limit rule of the organization. Its type is
+// fixed.
+func (s *RateLimitService) UpdateRule(ctx context.Context, orgID, id string, req *types.UpdateRateLimitRuleRequest) (*types.RateLimitRule, error) {
+	rule, err := s.GetRule(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		rule.Name = req.Name
+	}
+	if req.Description != "" {
+		rule.Description = req.Description
+	}
+	if req.TargetID != nil {
+		rule.TargetID = *req.TargetID
+	}
+	if req.Algorithm != "" {
+		rule.Algorithm = req.Algorithm
+	}
+	if req.Limit != 0 {
+		rule.Limit = req.Limit
+	}
+	if req.WindowSeconds != 0 {
+		rule.WindowSeconds = req.WindowSeconds
+	}
+	if req.Burst != nil {
+		rule.Burst = *req.Burst
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	if err := s.validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpdateRule(rule); err != nil {
+		return nil, types.NewInternalError("Failed to update rate limit rule: " + err.Error())
+	}
+	s.invalidate(orgID)
+	return rule, nil
+}
+
+// DeleteRule removes a rate limit rule of the organization
+func (s *RateLimitService) DeleteRule(ctx context.Context, orgID, id string) error {
+	deleted, err := s.store.DeleteRule(orgID, id)
+	if err != nil {
+		return types.NewInternalError("Failed to delete rate limit rule: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Rate limit rule not found")
+	}
+	s.invalidate(orgID)
+	return nil
+}
+
+// Check counts a request against every active rule of the subject's
+// organization that applies to it, highest priority first, and returns
+// their buckets. Counting stops at the first rule the request exceeds,
+// whose bucket is last and resets when the request may be retried. Rules
+// whose counters cannot be reached are skipped, so an outage of the
+// counter store does not take the gateway down with it.
+func (s *RateLimitService) Check(ctx context.Context, subject *types.RateLimitSubject) ([]types.RateLimitBucket, error) {
+	if subject.OrganizationID == "" {
+		return nil, nil
+	}
+	rules, err := s.activeRules(subject.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []types.RateLimitBucket
+	for _, rule := range rules {
+		key := rateLimitKey(rule, subject)
+		if key == "" {
+			continue
+		}
+
+		policy := rateLimitPolicy(rule)
+		result, err := s.limiter.Allow(ctx, key, policy)
+		if err != nil {
+			log.Printf("Skipping rate limit rule %s: %v", rule.ID, err)
+			continue
+		}
+
+		bucket := types.RateLimitBucket{
+			Scope:     rule.Type,
+			Key:       rule.Name,
+			Window:    policy.Window.String(),
+			Limit:     result.Limit,
+			Remaining: result.Remaining,
+			ResetAt:   result.ResetAt,
+			Exceeded:  !result.Allowed,
+		}
+		if !result.Allowed {
+			bucket.ResetAt = s.now().Add(result.RetryAfter)
+			return append(buckets, bucket), nil
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+// activeRules returns the organization's active rules, highest priority
+// first, from the cache while it is fresh
+func (s *RateLimitService) activeRules(orgID string) ([]*types.RateLimitRule, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[orgID]
+	s.mu.Unlock()
+	if ok && s.now().Before(cached.expires) {
+		return cached.rules, nil
+	}
+
+	rules, err := s.store.ListRules(orgID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rate limit rules: %w", err)
+	}
+
+	s.mu.Lock()
+	s.cache[orgID] = &cachedRateLimitRules{rules: rules, expires: s.now().Add(rateLimitRuleCacheTTL)}
+	s.mu.Unlock()
+	return rules, nil
+}
+
+func (s *RateLimitService) invalidate(orgID string) {
+	s.mu.Lock()
+	delete(s.cache, orgID)
+	s.mu.Unlock()
+}
+
+func (s *RateLimitService) validateRule(rule *types.RateLimitRule) error {
+	if len(rule.Name) < 2 || len(rule.Name) > 255 {
+		return types.NewValidationError("name must be between 2 and 255 characters")
+	}
+
+	switch rule.Type {
+	case types.RateLimitTypeOrganization:
+		if rule.TargetID != "" {
+			return types.NewValidationError("organization rules cannot have a target_id")
+		}
+	case types.RateLimitTypeUser, types.RateLimitTypeAPIKey, types.RateLimitTypeEndpoint:
+	default:
+		return types.NewValidationError("type must be organization, user, api_key or endpoint")
+	}
+
+	if err := rateLimitPolicy(rule).Validate(); err != nil {
+		return types.NewValidationError(err.Error())
+	}
+	if time.Duration(rule.WindowSeconds)*time.Second > maxRateLimitWindow {
+		return types.NewValidationError("window_seconds cannot exceed one day")
+	}
+	if rule.Burst > 0 && rule.Algorithm != types.RateLimitAlgorithmTokenBucket {
+		return types.NewValidationError("burst only applies to token_bucket rules")
+	}
+
+	existing, err := s.store.ListRules(rule.OrganizationID, false)
+	if err != nil {
+		return types.NewInternalError("Failed to check rate limit rules: " + err.Error())
+	}
+	for _, other := range existing {
+		if other.Name == rule.Name && other.ID != rule.ID {
+			return types.NewConflictError("A rate limit rule with this name already exists")
+		}
+	}
+	return nil
+}
+
+// rateLimitPolicy returns how rule limits the requests it applies to
+func rateLimitPolicy(rule *types.RateLimitRule) ratelimit.Policy {
+	return ratelimit.Policy{
+		Algorithm: rule.Algorithm,
+		Window:    time.Duration(rule.WindowSeconds) * time.Second,
+		Limit:     int64(rule.Limit),
+		Burst:     int64(rule.Burst),
+	}
+}
+
+// rateLimitKey returns the counter a request counts against under rule,
+// or "" when the rule does not apply to the subject
+func rateLimitKey(rule *types.RateLimitRule, subject *types.RateLimitSubject) string {
+	var id string
+	switch rule.Type {
+	case types.RateLimitTypeOrganization:
+		return "rule:" + rule.ID
+	case types.RateLimitTypeUser:
+		id = subject.UserID
+	case types.RateLimitTypeAPIKey:
+		id = subject.APIKeyID
+	case types.RateLimitTypeEndpoint:
+		id = subject.EndpointID
+	}
+	if id == "" || (rule.TargetID != "" && rule.TargetID != id) {
+		return ""
+	}
+	return "rule:" + rule.ID + ":" + id
+}
@@ -0,0 +1,450 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// maxReplicationBatches bounds the batches pulled from a peer per sync, so
+// a long backlog does not hold up the other peers
+const maxReplicationBatches = 20
+
+// regionNameSuffix strips characters not allowed in catalog names from the
+// region suffix given to an entry that lost its name
+var regionNameSuffix = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// CatalogReplicationStore persists catalog changes and replication progress
+type CatalogReplicationStore interface {
+	ListChanges(afterID int64, limit int) ([]types.CatalogChange, error)
+	Apply(change *types.CatalogChange, region, localRegion string) (bool, error)
+	FindNameHolder(entityType, orgID, name, excludeID string) (*models.CatalogNameHolder, error)
+	Rename(entityType, id, name string) error
+	PeerCursor(region string) (int64, error)
+	RecordPull(region string, cursor int64, applied, superseded int, pulledAt time.Time, pullErr string) error
+	ListPeers() ([]types.RegionPeerStatus, error)
+	ListEndpointRegions(orgID string) (map[string]string, error)
+}
+
+// CatalogPeerClient reads the catalog changes of a peer region
+type CatalogPeerClient interface {
+	FetchChanges(ctx context.Context, peer RegionPeer, after int64, limit int) (*types.CatalogChangeBatch, error)
+}
+
+// RegionPeer is another region of a multi-region deployment
+type RegionPeer struct {
+	Name string
+	URL  string
+}
+
+// RegionSettings places the gateway in a multi-region deployment
+type RegionSettings struct {
+	Region    string
+	BaseURL   string
+	GlobalURL string
+	// Token is the shared secret peers present to read changes
+	Token     string
+	Peers     []RegionPeer
+	BatchSize int
+	// Interval is how often peers are pulled, for the DNS guidance
+	Interval time.Duration
+}
+
+// RegionService replicates servers, namespaces and endpoints between the
+// regions of an active-active deployment. Each region logs its own changes
+// and pulls those of its peers; concurrent edits of an entry resolve to
+// the last writer, and entries of different regions that claim the same
+// name leave it to the one created first.
+type RegionService struct {
+	store    CatalogReplicationStore
+	client   CatalogPeerClient
+	now      func() time.Time
+	settings RegionSettings
+}
+
+// NewRegionService creates a region service backed by the database
+func NewRegionService(db *sql.DB, settings RegionSettings) *RegionService {
+	return NewRegionServiceWithStore(models.NewCatalogReplicationModel(db), NewHTTPCatalogPeerClient(settings.Token), settings)
+}
+
+// NewRegionServiceWithStore creates a region service over a store and peer
+// client
+func NewRegionServiceWithStore(store CatalogReplicationStore, client CatalogPeerClient, settings RegionSettings) *RegionService {
+	if settings.BatchSize <= 0 {
+		settings.BatchSize = 500
+	}
+	return &RegionService{
+		store:    store,
+		client:   client,
+		now:      time.Now,
+		settings: settings,
+	}
+}
+
+// SetClock replaces the service's clock, for tests
+func (s *RegionService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Authorize reports whether token is the replication token shared by the
+// regions. Without a configured token no peer is authorized.
+func (s *RegionService) Authorize(token string) bool {
+	return replicationTokenValid(s.settings.Token, token)
+}
+
+func replicationTokenValid(configured, token string) bool {
+	if configured == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(configured)) == 1
+}
+
+// Changes returns the local catalog changes after the change after, for a
+// peer to apply
+func (s *RegionService) Changes(ctx context.Context, after int64, limit int) (*types.CatalogChangeBatch, error) {
+	if after < 0 {
+		return nil, types.NewValidationError("after cannot be negative")
+	}
+	if limit <= 0 || limit > s.settings.BatchSize {
+		limit = s.settings.BatchSize
+	}
+
+	changes, err := s.store.ListChanges(after, limit+1)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list catalog changes: " + err.Error())
+	}
+	batch := &types.CatalogChangeBatch{Region: s.settings.Region, NextAfter: after}
+	if len(changes) > limit {
+		changes, batch.HasMore = changes[:limit], true
+	}
+	if len(changes) > 0 {
+		batch.NextAfter = changes[len(changes)-1].ID
+	}
+	batch.Changes = changes
+	return batch, nil
+}
+
+// Sync pulls and applies the pending changes of every peer and returns how
+// many were applied. A failing peer does not stop the others.
+func (s *RegionService) Sync(ctx context.Context) (int, error) {
+	total := 0
+	var errs []error
+	for _, peer := range s.settings.Peers {
+		applied, err := s.PullPeer(ctx, peer)
+		total += applied
+		if err != nil {
+			errs = append(errs, fmt.Errorf("region %s: %w", peer.Name, err))
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// PullPeer applies the changes made in a peer region since the last pull.
+// Progress is saved after each batch, so a failed change is retried on the
+// next pull without reapplying those before it.
+func (s *RegionService) PullPeer(ctx context.Context, peer RegionPeer) (int, error) {
+	cursor, err := s.store.PeerCursor(peer.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for range maxReplicationBatches {
+		batch, err := s.client.FetchChanges(ctx, peer, cursor, s.settings.BatchSize)
+		if err == nil && batch.Region != peer.Name {
+			err = fmt.Errorf("peer at %s reports region %q", peer.URL, batch.Region)
+		}
+		if err != nil {
+			return total, s.recordPull(peer.Name, cursor, 0, 0, err)
+		}
+
+		applied, superseded := 0, 0
+		for i := range batch.Changes {
+			change := &batch.Changes[i]
+			ok, err := s.applyChange(change, peer.Name)
+			if err != nil {
+				err = fmt.Errorf("change %d to %s %s: %w", change.ID, change.EntityType, change.EntityID, err)
+				return total + applied, s.recordPull(peer.Name, cursor, applied, superseded, err)
+			}
+			if ok {
+				applied++
+			} else {
+				superseded++
+			}
+			cursor = change.ID
+		}
+		if len(batch.Changes) == 0 {
+			cursor = max(cursor, batch.NextAfter)
+		}
+		total += applied
+		if err := s.recordPull(peer.Name, cursor, applied, superseded, nil); err != nil {
+			return total, err
+		}
+		if !batch.HasMore {
+			break
+		}
+	}
+	return total, nil
+}
+
+func (s *RegionService) recordPull(region string, cursor int64, applied, superseded int, pullErr error) error {
+	message := ""
+	if pullErr != nil {
+		message = pullErr.Error()
+	}
+	if err := s.store.RecordPull(region, cursor, applied, superseded, s.now(), message); err != nil {
+		return errors.Join(pullErr, err)
+	}
+	return pullErr
+}
+
+// applyChange applies a peer's change, settling a name held by a different
+// local entry first
+func (s *RegionService) applyChange(change *types.CatalogChange, region string) (bool, error) {
+	applied, err := s.store.Apply(change, region, s.settings.Region)
+	if !errors.Is(err, models.ErrCatalogNameTaken) {
+		return applied, err
+	}
+
+	renamed, err := s.settleName(change, region)
+	if err != nil {
+		return false, err
+	}
+	return s.store.Apply(renamed, region, s.settings.Region)
+}
+
+// settleName resolves two entries of different regions claiming one name.
+// The entry created first keeps it and the other gets its region appended;
+// both regions reach the same outcome, a local rename replicating to the
+// peer like any edit. It returns the change to apply.
+func (s *RegionService) settleName(change *types.CatalogChange, region string) (*types.CatalogChange, error) {
+	var entry struct {
+		CreatedAt time.Time `json:"created_at"`
+		Name      string    `json:"name"`
+	}
+	if err := json.Unmarshal(change.Payload, &entry); err != nil || entry.Name == "" {
+		return nil, fmt.Errorf("name conflict on an entry without a name")
+	}
+
+	holder, err := s.store.FindNameHolder(change.EntityType, change.OrganizationID, entry.Name, change.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	if holder == nil {
+		return nil, fmt.Errorf("name %q is taken by an unknown entry", entry.Name)
+	}
+
+	remoteFirst := entry.CreatedAt.Before(holder.CreatedAt) ||
+		(entry.CreatedAt.Equal(holder.CreatedAt) && region < s.settings.Region)
+	if remoteFirst {
+		return change, s.store.Rename(change.EntityType, holder.ID, regionalName(entry.Name, s.settings.Region))
+	}
+
+	var row map[string]interface{}
+	if err := json.Unmarshal(change.Payload, &row); err != nil {
+		return nil, err
+	}
+	row["name"] = regionalName(entry.Name, region)
+	payload, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	renamed := *change
+	renamed.Payload = payload
+	return &renamed, nil
+}
+
+// regionalName is the name given to the entry of region that lost name
+func regionalName(name, region string) string {
+	return name + "-" + regionNameSuffix.ReplaceAllString(region, "-")
+}
+
+// Status reports the replication progress from each configured peer
+func (s *RegionService) Status(ctx context.Context) (*types.RegionStatus, error) {
+	progress, err := s.store.ListPeers()
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get replication status: " + err.Error())
+	}
+	byRegion := make(map[string]types.RegionPeerStatus, len(progress))
+	for _, peer := range progress {
+		byRegion[peer.Region] = peer
+	}
+
+	status := &types.RegionStatus{Region: s.settings.Region, Peers: []types.RegionPeerStatus{}}
+	for _, peer := range s.settings.Peers {
+		peerStatus := byRegion[peer.Name]
+		peerStatus.Region = peer.Name
+		peerStatus.URL = peer.URL
+		status.Peers = append(status.Peers, peerStatus)
+	}
+	return status, nil
+}
+
+// DNSGuidance describes the DNS records that send clients to the nearest
+// healthy region, and the URL to publish for each of the organization's
+// endpoints
+func (s *RegionService) DNSGuidance(ctx context.Context, orgID string) (*types.RegionDNSGuidance, error) {
+	regions := s.regionURLs()
+	guidance := &types.RegionDNSGuidance{
+		Records:   []types.RegionDNSRecord{},
+		Endpoints: []types.EndpointDNSRoute{},
+		Notes:     []string{},
+	}
+
+	globalURL := strings.TrimRight(s.settings.GlobalURL, "/")
+	if global, err := url.Parse(globalURL); err == nil && global.Host != "" {
+		guidance.GlobalHost = global.Hostname()
+	}
+	switch {
+	case len(s.settings.Peers) == 0:
+		guidance.Notes = append(guidance.Notes, "This gateway runs in a single region; configure region.peers to run active-active.")
+	case guidance.GlobalHost == "":
+		guidance.Notes = append(guidance.Notes, "Set region.global_url to the hostname clients should use to get DNS records for it.")
+	default:
+		for _, region := range regions {
+			host := region.URL
+			if parsed, err := url.Parse(region.URL); err == nil && parsed.Host != "" {
+				host = parsed.Hostname()
+			}
+			guidance.Records = append(guidance.Records, types.RegionDNSRecord{
+				Name:        guidance.GlobalHost,
+				Type:        "CNAME",
+				Value:       host,
+				Routing:     "latency",
+				Region:      region.Name,
+				HealthCheck: region.URL + "/readyz",
+			})
+		}
+		guidance.Notes = append(guidance.Notes,
+			"Create one latency-based record per region for "+guidance.GlobalHost+" and attach the health check, so a region that fails /readyz leaves rotation.",
+			"Keep each regional hostname resolvable on its own: peers replicate from it and endpoints pinned to a region are served only there.",
+			fmt.Sprintf("Servers, namespaces and endpoints replicate asynchronously, polled every %s; concurrent edits of the same entry keep the last one.", s.settings.Interval),
+			"Users, API keys and logs stay in the region where they were created.")
+	}
+
+	endpoints, err := s.store.ListEndpointRegions(orgID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list endpoints: " + err.Error())
+	}
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := "/api/public/endpoints/" + name + "/mcp"
+		route := types.EndpointDNSRoute{
+			Name:         name,
+			PinnedRegion: endpoints[name],
+			RegionalURLs: make(map[string]string, len(regions)),
+		}
+		for _, region := range regions {
+			route.RegionalURLs[region.Name] = region.URL + path
+		}
+		switch pinned, ok := route.RegionalURLs[route.PinnedRegion]; {
+		case route.PinnedRegion != "" && ok:
+			route.URL = pinned
+		case globalURL != "" && len(s.settings.Peers) > 0:
+			route.URL = globalURL + path
+		default:
+			route.URL = strings.TrimRight(s.settings.BaseURL, "/") + path
+		}
+		guidance.Endpoints = append(guidance.Endpoints, route)
+	}
+	return guidance, nil
+}
+
+// regionURLs lists this region and its peers with their base URLs
+func (s *RegionService) regionURLs() []RegionPeer {
+	regions := []RegionPeer{{Name: s.settings.Region, URL: strings.TrimRight(s.settings.BaseURL, "/")}}
+	for _, peer := range s.settings.Peers {
+		regions = append(regions, RegionPeer{Name: peer.Name, URL: strings.TrimRight(peer.URL, "/")})
+	}
+	return regions
+}
+
+// HTTPCatalogPeerClient reads peer changes from their replication API
+type HTTPCatalogPeerClient struct {
+	client *http.Client
+	token  string
+}
+
+// NewHTTPCatalogPeerClient creates a peer client presenting token
+func NewHTTPCatalogPeerClient(token string) *HTTPCatalogPeerClient {
+	return &HTTPCatalogPeerClient{
+		client: &http.Client{Timeout: 30 * time.Second},
+		token:  token,
+	}
+}
+
+// FetchChanges gets a page of the peer's changes after the change after
+func (c *HTTPCatalogPeerClient) FetchChanges(ctx context.Context, peer RegionPeer, after int64, limit int) (*types.CatalogChangeBatch, error) {
+	endpoint := fmt.Sprintf("%s/api/replication/changes?after=%s&limit=%s",
+		strings.TrimRight(peer.URL, "/"), strconv.FormatInt(after, 10), strconv.Itoa(limit))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer responded with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data    *types.CatalogChangeBatch `json:"data"`
+		Success bool                      `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid response from peer: %w", err)
+	}
+	if body.Data == nil {
+		return nil, errors.New("empty response from peer")
+	}
+	return body.Data, nil
+}
+
+// Fence asks a peer to stand down because this region took over from it
+func (c *HTTPCatalogPeerClient) Fence(ctx context.Context, peer RegionPeer, req *types.FenceRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(peer.URL, "/")+"/api/replication/fence", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("peer responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/pkg/requestsig"
+)
+
+// SigningKeyStore persists the keys outbound requests are signed with
+type SigningKeyStore interface {
+	DestinationOrganization(destinationType, destinationID string) (string, error)
+	Create(key *types.SigningKey) error
+	List(orgID, destinationType, destinationID string) ([]*types.SigningKey, error)
+	Revoke(orgID, id string) (bool, error)
+	Active(destinationType, destinationID string) (*types.SigningKey, error)
+}
+
+// RequestSigner returns the key requests to a destination are signed with,
+// or nil when they go unsigned
+type RequestSigner interface {
+	SigningKey(ctx context.Context, destinationType, destinationID string) (*requestsig.Key, error)
+}
+
+// RequestSigningService manages per-destination signing keys, so that A2A
+// agents and owner webhooks can verify that requests come from the gateway
+type RequestSigningService struct {
+	store SigningKeyStore
+}
+
+// NewRequestSigningService creates a database-backed request signing service
+func NewRequestSigningService(db *sql.DB) *RequestSigningService {
+	return NewRequestSigningServiceWithStore(models.NewSigningKeyModel(db))
+}
+
+// NewRequestSigningServiceWithStore creates a request signing service over
+// store
+func NewRequestSigningServiceWithStore(store SigningKeyStore) *RequestSigningService {
+	return &RequestSigningService{store: store}
+}
+
+// CreateKey creates a signing key for a destination of the organization.
+// New requests to the destination are signed with it; older keys keep their
+// IDs so receivers can accept both while they rotate.
+func (s *RequestSigningService) CreateKey(ctx context.Context, orgID, createdBy string, req *types.CreateSigningKeyRequest) (*types.CreateSigningKeyResponse, error) {
+	destOrg, err := s.store.DestinationOrganization(req.DestinationType, req.DestinationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up signing destination: %w", err)
+	}
+	if destOrg == "" || destOrg != orgID {
+		return nil, types.NewNotFoundError("Signing destination not found")
+	}
+
+	key := &types.SigningKey{
+		OrganizationID:  orgID,
+		DestinationType: req.DestinationType,
+		DestinationID:   req.DestinationID,
+		Algorithm:       req.Algorithm,
+		CreatedBy:       createdBy,
+	}
+	if key.Algorithm == "" {
+		key.Algorithm = requestsig.AlgHMACSHA256
+	}
+	resp := &types.CreateSigningKeyResponse{Key: key}
+	switch key.Algorithm {
+	case requestsig.AlgHMACSHA256:
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate signing secret: %w", err)
+		}
+		key.Secret = base64.RawURLEncoding.EncodeToString(secret)
+		resp.Secret = key.Secret
+	case requestsig.AlgEd25519:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		key.Secret = base64.RawURLEncoding.EncodeToString(private)
+		key.PublicKey = base64.RawURLEncoding.EncodeToString(public)
+	default:
+		return nil, types.NewValidationError("unsupported signing algorithm: " + key.Algorithm)
+	}
+
+	if err := s.store.Create(key); err != nil {
+		return nil, fmt.Errorf("failed to create signing key: %w", err)
+	}
+	return resp, nil
+}
+
+// ListKeys returns the organization's signing keys, optionally narrowed to
+// one destination
+func (s *RequestSigningService) ListKeys(ctx context.Context, orgID, destinationType, destinationID string) ([]*types.SigningKey, error) {
+	keys, err := s.store.List(orgID, destinationType, destinationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	if keys == nil {
+		keys = []*types.SigningKey{}
+	}
+	return keys, nil
+}
+
+// RevokeKey stops a signing key from signing. Requests to its destination
+// fall back to the newest remaining key, or go unsigned.
+func (s *RequestSigningService) RevokeKey(ctx context.Context, orgID, id string) error {
+	revoked, err := s.store.Revoke(orgID, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke signing key: %w", err)
+	}
+	if !revoked {
+		return types.NewNotFoundError("Signing key not found")
+	}
+	return nil
+}
+
+// SigningKey returns the key requests to a destination are signed with, or
+// nil when the destination has no active key
+func (s *RequestSigningService) SigningKey(ctx context.Context, destinationType, destinationID string) (*requestsig.Key, error) {
+	stored, err := s.store.Active(destinationType, destinationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signing key: %w", err)
+	}
+	if stored == nil {
+		return nil, nil
+	}
+
+	key := &requestsig.Key{ID: stored.ID, Algorithm: stored.Algorithm}
+	switch stored.Algorithm {
+	case requestsig.AlgHMACSHA256:
+		key.Secret = []byte(stored.Secret)
+	case requestsig.AlgEd25519:
+		private, err := base64.RawURLEncoding.DecodeString(stored.Secret)
+		if err != nil || len(private) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("signing key %s is corrupt", stored.ID)
+		}
+		key.PrivateKey = ed25519.PrivateKey(private)
+	default:
+		return nil, fmt.Errorf("signing key %s has unsupported algorithm %q", stored.ID, stored.Algorithm)
+	}
+	return key, nil
+}
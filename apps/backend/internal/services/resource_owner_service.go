@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// ResourceOwnerStore persists resource ownership
+type ResourceOwnerStore interface {
+	ResourceOrganization(resourceType, resourceID string) (string, error)
+	OwnerOrganization(ownerType, ownerID string) (string, error)
+	Get(resourceType, resourceID string) (*types.ResourceOwner, error)
+	List(orgID string, filter *types.ResourceOwnerFilter) ([]*types.ResourceOwner, error)
+	Upsert(owner *types.ResourceOwner) error
+	Delete(resourceType, resourceID string) (bool, error)
+	Transfer(orgID string, req *types.TransferOwnershipRequest, assignedBy string) (int64, error)
+}
+
+// ResourceOwnerService records which user or team is accountable for each
+// server, tool, namespace and endpoint, surfaces resources whose owners are
+// gone and moves resources between owners in bulk
+type ResourceOwnerService struct {
+	store ResourceOwnerStore
+}
+
+// NewResourceOwnerService creates a database-backed resource owner service
+func NewResourceOwnerService(db *sql.DB) *ResourceOwnerService {
+	return NewResourceOwnerServiceWithStore(models.NewResourceOwnerModel(db))
+}
+
+// NewResourceOwnerServiceWithStore creates a resource owner service over
+// store
+func NewResourceOwnerServiceWithStore(store ResourceOwnerStore) *ResourceOwnerService {
+	return &ResourceOwnerService{store: store}
+}
+
+// GetOwner returns the owner of a resource of the organization
+func (s *ResourceOwnerService) GetOwner(ctx context.Context, orgID, resourceType, resourceID string) (*types.ResourceOwner, error) {
+	if err := s.checkResource(orgID, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	owner, err := s.store.Get(resourceType, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource owner: %w", err)
+	}
+	if owner == nil {
+		return nil, types.NewNotFoundError("Resource has no owner")
+	}
+	return owner, nil
+}
+
+// SetOwner assigns a resource of the organization to an active user or a
+// team of the same organization
+func (s *ResourceOwnerService) SetOwner(ctx context.Context, orgID, resourceType, resourceID, assignedBy string, req *types.SetResourceOwnerRequest) (*types.ResourceOwner, error) {
+	if err := s.checkResource(orgID, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	if err := s.checkOwner(orgID, req.OwnerType, req.OwnerID); err != nil {
+		return nil, err
+	}
+
+	owner := &types.ResourceOwner{
+		ResourceType:   resourceType,
+		ResourceID:     resourceID,
+		OrganizationID: orgID,
+		OwnerType:      req.OwnerType,
+		OwnerID:        req.OwnerID,
+		Notes:          req.Notes,
+		AssignedBy:     assignedBy,
+	}
+	if err := s.store.Upsert(owner); err != nil {
+		return nil, fmt.Errorf("failed to save resource owner: %w", err)
+	}
+
+	saved, err := s.store.Get(resourceType, resourceID)
+	if err != nil || saved == nil {
+		return owner, nil
+	}
+	return saved, nil
+}
+
+// RemoveOwner clears the owner of a resource of the organization
+func (s *ResourceOwnerService) RemoveOwner(ctx context.Context, orgID, resourceType, resourceID string) error {
+	if err := s.checkResource(orgID, resourceType, resourceID); err != nil {
+		return err
+	}
+	deleted, err := s.store.Delete(resourceType, resourceID)
+	if err != nil {
+		return fmt.Errorf("failed to delete resource owner: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("Resource has no owner")
+	}
+	return nil
+}
+
+// ListOwners returns the organization's ownership records matching filter
+func (s *ResourceOwnerService) ListOwners(ctx context.Context, orgID string, filter *types.ResourceOwnerFilter) ([]*types.ResourceOwner, error) {
+	owners, err := s.store.List(orgID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource owners: %w", err)
+	}
+	return owners, nil
+}
+
+// Transfer moves resources of the organization from one owner to another.
+// The current owner need not exist any more, so orphaned resources can be
+// reassigned; the new owner must.
+func (s *ResourceOwnerService) Transfer(ctx context.Context, orgID, assignedBy string, req *types.TransferOwnershipRequest) (*types.TransferOwnershipResult, error) {
+	if req.FromType == req.ToType && req.FromID == req.ToID {
+		return nil, types.NewValidationError("resources already belong to this owner")
+	}
+	if err := s.checkOwner(orgID, req.ToType, req.ToID); err != nil {
+		return nil, err
+	}
+
+	transferred, err := s.store.Transfer(orgID, req, assignedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer resources: %w", err)
+	}
+	return &types.TransferOwnershipResult{Transferred: transferred}, nil
+}
+
+// checkResource returns a not found error unless the resource belongs to
+// the organization
+func (s *ResourceOwnerService) checkResource(orgID, resourceType, resourceID string) error {
+	switch resourceType {
+	case types.OwnedResourceServer, types.OwnedResourceTool, types.OwnedResourceNamespace, types.OwnedResourceEndpoint:
+	default:
+		return types.NewValidationError("resource type must be one of server, tool, namespace or endpoint")
+	}
+	if _, err := uuid.Parse(resourceID); err != nil {
+		return types.NewNotFoundError("Resource not found")
+	}
+	resourceOrg, err := s.store.ResourceOrganization(resourceType, resourceID)
+	if err != nil {
+		return fmt.Errorf("failed to get resource: %w", err)
+	}
+	if resourceOrg == "" || resourceOrg != orgID {
+		return types.NewNotFoundError("Resource not found")
+	}
+	return nil
+}
+
+// checkOwner returns a validation error unless the owner is an active user
+// or a team of the organization
+func (s *ResourceOwnerService) checkOwner(orgID, ownerType, ownerID string) error {
+	ownerOrg, err := s.store.OwnerOrganization(ownerType, ownerID)
+	if err != nil {
+		return fmt.Errorf("failed to get owner: %w", err)
+	}
+	if ownerOrg != orgID {
+		if ownerType == types.ResourceOwnerTeam {
+			return types.NewValidationError("team does not belong to the organization")
+		}
+		return types.NewValidationError("owner must be an active user of the organization")
+	}
+	return nil
+}
@@ -0,0 +1,516 @@
+package services
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/objectstore"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	// defaultRetentionBatchSize is how many rows one delete removes
+	defaultRetentionBatchSize = 1000
+	maxRetentionBatchSize     = 100000
+	// retentionBatchesPerRun bounds the deletes per organization and table
+	// in one run, so a large backlog is worked off over several runs
+	// without holding up the other organizations
+	retentionBatchesPerRun = 100
+	// retentionExportRowsPerRun bounds the rows exported per organization
+	// and table in one run, and so the size of one export object
+	retentionExportRowsPerRun = 100000
+	maxRetentionDays          = 3650
+	defaultPurgeWindowDays    = 7
+	maxPurgeWindowDays        = 90
+	retentionExportListLimit  = 100
+)
+
+// RetentionStore lists organizations' log retention and removes their rows
+// past it. GetPolicy returns nil when the organization does not exist.
+type RetentionStore interface {
+	ListRetentions() ([]*types.OrganizationRetention, error)
+	HasActiveLegalHold(orgID string) (bool, error)
+	CountExpired(table, orgID string, cutoff time.Time) (int64, error)
+	PurgeExpired(table, orgID string, cutoff time.Time, limit int) (int64, error)
+	GetPolicy(orgID string) (*types.LogRetentionPolicy, error)
+	UpsertPolicy(policy *types.LogRetentionPolicy) error
+	ExportExpired(table, orgID string, cutoff time.Time, limit int, fn func(row *types.ArchiveRow) error) error
+	DeleteExpired(table, orgID string, cutoff time.Time, ids []string) (int64, error)
+	CountExpiringByDay(table, orgID string, from, cutoff time.Time) ([]*types.RetentionPurgeDay, error)
+	RecordExport(export *types.RetentionExport) error
+	ListExports(orgID string, limit int) ([]*types.RetentionExport, error)
+}
+
+// RetentionService enforces each organization's log retention by deleting
+// its execution logs, audit records and health checks older than their
+// retention, in batches. Each type of log is kept for the days the
+// organization's retention policy sets, or log_retention_days. With export
+// enabled, rows are uploaded to the organization's bucket before they are
+// deleted. Organizations under legal hold are skipped. In a dry run rows
+// are only counted.
+type RetentionService struct {
+	store      RetentionStore
+	egress     *airgap.Policy
+	now        func() time.Time
+	emitMetric func(metric *types.Metric)
+	openExport func(cfg *types.RetentionExportConfig) (ExportStorage, error)
+	batchSize  int
+	dryRun     bool
+}
+
+// NewRetentionService creates a database-backed retention service
+func NewRetentionService(db *sql.DB, batchSize int, dryRun bool) *RetentionService {
+	return NewRetentionServiceWithStore(models.NewRetentionModel(db), batchSize, dryRun)
+}
+
+// NewRetentionServiceWithStore creates a retention service over store
+func NewRetentionServiceWithStore(store RetentionStore, batchSize int, dryRun bool) *RetentionService {
+	if batchSize <= 0 {
+		batchSize = defaultRetentionBatchSize
+	}
+	s := &RetentionService{
+		store:     store,
+		now:       time.Now,
+		batchSize: min(batchSize, maxRetentionBatchSize),
+		dryRun:    dryRun,
+	}
+	s.openExport = s.openS3Export
+	return s
+}
+
+// openS3Export opens the S3 bucket of an export configuration, connecting
+// only to public addresses and allow-listed hosts
+func (s *RetentionService) openS3Export(cfg *types.RetentionExportConfig) (ExportStorage, error) {
+	return objectstore.NewS3Store(objectstore.S3Config{
+		Endpoint:        cfg.Endpoint,
+		Region:          cfg.Region,
+		Bucket:          cfg.Bucket,
+		Prefix:          cfg.Prefix,
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		UsePathStyle:    cfg.UsePathStyle,
+		HTTPClient:      s.egress.PublicClient(types.ConnectivityFeatureRetentionExport, objectstore.S3RequestTimeout),
+	})
+}
+
+// SetEgressPolicy restricts the endpoints organizations may export to.
+// Only hosts it allow-lists may be inside the gateway's network.
+func (s *RetentionService) SetEgressPolicy(policy *airgap.Policy) {
+	s.egress = policy
+}
+
+// SetClock replaces the clock retention cutoffs are computed against
+func (s *RetentionService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetExportStorage replaces how the bucket logs are exported to before
+// they are purged is opened
+func (s *RetentionService) SetExportStorage(open func(cfg *types.RetentionExportConfig) (ExportStorage, error)) {
+	s.openExport = open
+}
+
+// SetMetricEmitter reports the rows purged per table through emit, or in a
+// dry run the rows that would be purged
+func (s *RetentionService) SetMetricEmitter(emit func(metric *types.Metric)) {
+	s.emitMetric = emit
+}
+
+// Enforce purges every organization's rows past its retention. A table
+// that fails to purge, or to export before it is purged, keeps its
+// remaining rows until the next run; the other tables and organizations
+// are still purged.
+func (s *RetentionService) Enforce(ctx context.Context) (*types.RetentionRun, error) {
+	run := &types.RetentionRun{Purges: []*types.RetentionPurge{}, Held: []string{}, DryRun: s.dryRun}
+
+	retentions, err := s.store.ListRetentions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization retention: %w", err)
+	}
+
+	now := s.now()
+	totals := make(map[string]int64, len(types.RetentionTables))
+	var errs []error
+	for _, retention := range retentions {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if !hasRetention(retention) {
+			continue
+		}
+
+		held, err := s.store.HasActiveLegalHold(retention.OrganizationID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to check legal hold of %s: %w", retention.OrganizationID, err))
+			continue
+		}
+		if held {
+			run.Held = append(run.Held, retention.OrganizationID)
+			continue
+		}
+
+		var storage ExportStorage
+		if retention.Export != nil && !s.dryRun {
+			if err = s.checkExportEndpoint(retention.Export); err == nil {
+				storage, err = s.openExport(retention.Export)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to open export bucket of %s: %w", retention.OrganizationID, err))
+				continue
+			}
+		}
+
+		for _, table := range types.RetentionTables {
+			days := retention.TableDays(table)
+			if days <= 0 {
+				continue
+			}
+			purge := &types.RetentionPurge{
+				Cutoff:         now.Add(-time.Duration(days) * 24 * time.Hour),
+				OrganizationID: retention.OrganizationID,
+				Table:          table,
+			}
+			if storage != nil {
+				purge.Rows, purge.Export, err = s.exportAndPurge(ctx, storage, retention, purge)
+			} else {
+				purge.Rows, err = s.purge(ctx, table, retention.OrganizationID, purge.Cutoff)
+			}
+			if purge.Rows > 0 {
+				run.Purges = append(run.Purges, purge)
+				run.Rows += purge.Rows
+				totals[table] += purge.Rows
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to purge %s of %s: %w", table, retention.OrganizationID, err))
+			}
+		}
+	}
+
+	s.recordRun(totals, now)
+	return run, errors.Join(errs...)
+}
+
+// purge deletes the organization's rows of table older than cutoff batch by
+// batch, or counts them in a dry run
+func (s *RetentionService) purge(ctx context.Context, table, orgID string, cutoff time.Time) (int64, error) {
+	if s.dryRun {
+		return s.store.CountExpired(table, orgID, cutoff)
+	}
+
+	var purged int64
+	for i := 0; i < retentionBatchesPerRun; i++ {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		n, err := s.store.PurgeExpired(table, orgID, cutoff, s.batchSize)
+		purged += n
+		if err != nil || n < int64(s.batchSize) {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+// exportAndPurge uploads the organization's rows of a table past the
+// purge's cutoff to its bucket, then deletes the rows it uploaded. Rows
+// are only deleted once their upload succeeded.
+func (s *RetentionService) exportAndPurge(ctx context.Context, storage ExportStorage, retention *types.OrganizationRetention, purge *types.RetentionPurge) (int64, *types.RetentionExport, error) {
+	export := &types.RetentionExport{
+		OrganizationID: purge.OrganizationID,
+		Table:          purge.Table,
+		Cutoff:         purge.Cutoff,
+		Bucket:         retention.Export.Bucket,
+		StorageKey: fmt.Sprintf("%s/%s/%s.jsonl.gz", purge.OrganizationID, purge.Table,
+			s.now().UTC().Format("2006/01/02/150405")),
+	}
+
+	ids, err := s.writeExport(storage, export)
+	if err != nil {
+		storage.Delete(export.StorageKey)
+		return 0, nil, fmt.Errorf("failed to export: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil, storage.Delete(export.StorageKey)
+	}
+	if err := s.store.RecordExport(export); err != nil {
+		storage.Delete(export.StorageKey)
+		return 0, nil, fmt.Errorf("failed to record export: %w", err)
+	}
+
+	var purged int64
+	for start := 0; start < len(ids); start += s.batchSize {
+		if err := ctx.Err(); err != nil {
+			return purged, export, err
+		}
+		n, err := s.store.DeleteExpired(purge.Table, purge.OrganizationID, purge.Cutoff, ids[start:min(start+s.batchSize, len(ids))])
+		purged += n
+		if err != nil {
+			return purged, export, err
+		}
+	}
+	return purged, export, nil
+}
+
+// writeExport writes the oldest rows past an export's cutoff to its object
+// and returns their IDs
+func (s *RetentionService) writeExport(storage ExportStorage, export *types.RetentionExport) ([]string, error) {
+	w, err := storage.Create(export.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export object: %w", err)
+	}
+
+	hash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(w, hash)}
+	gz := gzip.NewWriter(counter)
+
+	var ids []string
+	limit := min(s.batchSize*retentionBatchesPerRun, retentionExportRowsPerRun)
+	err = s.store.ExportExpired(export.Table, export.OrganizationID, export.Cutoff, limit, func(row *types.ArchiveRow) error {
+		if _, err := gz.Write(append(row.Data, '\n')); err != nil {
+			return err
+		}
+		ids = append(ids, row.ID)
+		return nil
+	})
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	export.RowCount = int64(len(ids))
+	export.SizeBytes = counter.n
+	export.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return ids, nil
+}
+
+// GetPolicy returns an organization's retention per type of log
+func (s *RetentionService) GetPolicy(ctx context.Context, orgID string) (*types.LogRetentionPolicy, error) {
+	policy, err := s.store.GetPolicy(orgID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get log retention policy: " + err.Error())
+	}
+	if policy == nil {
+		return nil, types.NewNotFoundError("Organization not found")
+	}
+	return policy, nil
+}
+
+// UpdatePolicy changes an organization's retention per type of log and
+// where its logs are exported before they are purged
+func (s *RetentionService) UpdatePolicy(ctx context.Context, orgID, userID string, req *types.UpdateLogRetentionPolicyRequest) (*types.LogRetentionPolicy, error) {
+	policy, err := s.GetPolicy(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.RequestLogDays, err = overrideRetentionDays(policy.RequestLogDays, req.RequestLogDays, "request_log_days"); err != nil {
+		return nil, err
+	}
+	if policy.AuditLogDays, err = overrideRetentionDays(policy.AuditLogDays, req.AuditLogDays, "audit_log_days"); err != nil {
+		return nil, err
+	}
+	if policy.HealthCheckDays, err = overrideRetentionDays(policy.HealthCheckDays, req.HealthCheckDays, "health_check_days"); err != nil {
+		return nil, err
+	}
+	if req.Export != nil {
+		if err := s.updateExport(&policy.Export, req.Export); err != nil {
+			return nil, err
+		}
+	}
+	policy.UpdatedBy = userID
+
+	if err := s.store.UpsertPolicy(policy); err != nil {
+		return nil, types.NewInternalError("Failed to update log retention policy: " + err.Error())
+	}
+	return policy, nil
+}
+
+// updateExport applies a change of the export bucket. An enabled export
+// needs a bucket it can be opened with.
+func (s *RetentionService) updateExport(export *types.RetentionExportConfig, req *types.UpdateRetentionExportRequest) error {
+	for field, value := range map[*string]*string{
+		&export.Endpoint:    req.Endpoint,
+		&export.Region:      req.Region,
+		&export.Bucket:      req.Bucket,
+		&export.Prefix:      req.Prefix,
+		&export.AccessKeyID: req.AccessKeyID,
+	} {
+		if value != nil {
+			*field = strings.TrimSpace(*value)
+		}
+	}
+	if req.SecretAccessKey != nil {
+		export.SecretAccessKey = *req.SecretAccessKey
+	}
+	if req.UsePathStyle != nil {
+		export.UsePathStyle = *req.UsePathStyle
+	}
+	if req.Enabled != nil {
+		export.Enabled = *req.Enabled
+	}
+	export.HasSecret = export.SecretAccessKey != ""
+
+	if !export.Enabled {
+		return nil
+	}
+	if export.AccessKeyID == "" || export.SecretAccessKey == "" {
+		return types.NewValidationError("export needs access_key_id and secret_access_key")
+	}
+	if export.Bucket == "" || export.Region == "" {
+		return types.NewValidationError("export needs bucket and region")
+	}
+	if err := s.checkExportEndpoint(export); err != nil {
+		return err
+	}
+	// The reason is not returned, as it could describe the gateway's network
+	if _, err := s.openExport(export); err != nil {
+		return types.NewValidationError("Invalid export bucket; check its endpoint, region and bucket")
+	}
+	return nil
+}
+
+// checkExportEndpoint refuses export endpoints the egress policy blocks and
+// endpoints inside the gateway's network that it does not allow-list
+func (s *RetentionService) checkExportEndpoint(export *types.RetentionExportConfig) error {
+	endpoint := export.Endpoint
+	if endpoint == "" {
+		endpoint = objectstore.AWSEndpoint(export.Region)
+	}
+	if err := s.egress.CheckURL(types.ConnectivityFeatureRetentionExport, endpoint); err != nil {
+		return err
+	}
+	return s.egress.CheckPublicURL(endpoint)
+}
+
+// ListPurgeWindows returns what retention removes from each table of an
+// organization on its next run and over the next withinDays days
+func (s *RetentionService) ListPurgeWindows(ctx context.Context, orgID string, withinDays int) (*types.RetentionPurgeSchedule, error) {
+	if withinDays <= 0 {
+		withinDays = defaultPurgeWindowDays
+	}
+	if withinDays > maxPurgeWindowDays {
+		return nil, types.NewValidationError(fmt.Sprintf("within_days must be at most %d", maxPurgeWindowDays))
+	}
+
+	policy, err := s.GetPolicy(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	held, err := s.store.HasActiveLegalHold(orgID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to check legal hold: " + err.Error())
+	}
+
+	schedule := &types.RetentionPurgeSchedule{
+		OrganizationID: orgID,
+		WithinDays:     withinDays,
+		LegalHold:      held,
+		Windows:        make([]*types.RetentionPurgeWindow, 0, len(types.RetentionTables)),
+	}
+	now := s.now()
+	for _, table := range types.RetentionTables {
+		window := &types.RetentionPurgeWindow{
+			Table:    table,
+			Days:     policy.DefaultDays,
+			Upcoming: []*types.RetentionPurgeDay{},
+			Exported: policy.Export.Enabled,
+		}
+		if days := policy.TableDays(table); days != nil {
+			window.Days = *days
+		}
+		schedule.Windows = append(schedule.Windows, window)
+		if window.Days <= 0 {
+			continue
+		}
+
+		cutoff := now.Add(-time.Duration(window.Days) * 24 * time.Hour)
+		window.Cutoff = &cutoff
+		if window.Due, err = s.store.CountExpired(table, orgID, cutoff); err != nil {
+			return nil, types.NewInternalError("Failed to count expired logs: " + err.Error())
+		}
+		upcoming, err := s.store.CountExpiringByDay(table, orgID, cutoff, cutoff.AddDate(0, 0, withinDays))
+		if err != nil {
+			return nil, types.NewInternalError("Failed to count expiring logs: " + err.Error())
+		}
+		for _, day := range upcoming {
+			day.Date = day.Date.AddDate(0, 0, window.Days)
+			window.Upcoming = append(window.Upcoming, day)
+		}
+	}
+	return schedule, nil
+}
+
+// ListExports returns an organization's latest exports of purged rows,
+// newest first
+func (s *RetentionService) ListExports(ctx context.Context, orgID string) ([]*types.RetentionExport, error) {
+	exports, err := s.store.ListExports(orgID, retentionExportListLimit)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list log retention exports: " + err.Error())
+	}
+	if exports == nil {
+		exports = []*types.RetentionExport{}
+	}
+	return exports, nil
+}
+
+// hasRetention reports whether an organization purges any table
+func hasRetention(retention *types.OrganizationRetention) bool {
+	for _, table := range types.RetentionTables {
+		if retention.TableDays(table) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// overrideRetentionDays applies a change of one table's retention; 0
+// returns the table to the organization's default
+func overrideRetentionDays(current, update *int, field string) (*int, error) {
+	if update == nil {
+		return current, nil
+	}
+	if *update == 0 {
+		return nil, nil
+	}
+	if *update < 0 || *update > maxRetentionDays {
+		return nil, types.NewValidationError(fmt.Sprintf("%s must be between 0 and %d", field, maxRetentionDays))
+	}
+	days := *update
+	return &days, nil
+}
+
+func (s *RetentionService) recordRun(totals map[string]int64, at time.Time) {
+	if s.emitMetric == nil {
+		return
+	}
+	for _, table := range types.RetentionTables {
+		metric := &types.Metric{
+			Timestamp: at,
+			Name:      "retention_rows_purged_total",
+			Type:      types.MetricTypeCounter,
+			Value:     float64(totals[table]),
+			Tags:      map[string]string{"table": table},
+		}
+		if s.dryRun {
+			metric.Name = "retention_rows_expired"
+			metric.Type = types.MetricTypeGauge
+		}
+		s.emitMetric(metric)
+	}
+}
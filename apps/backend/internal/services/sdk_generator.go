@@ -0,0 +1,552 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Client SDK languages generated for endpoints
+const (
+	SDKLanguagePython     = "python"
+	SDKLanguageTypeScript = "typescript"
+)
+
+// SDKLanguages lists the languages client SDKs can be generated in
+var SDKLanguages = []string{SDKLanguagePython, SDKLanguageTypeScript}
+
+// SDKFile is a file of a generated client library
+type SDKFile struct {
+	Path    string
+	Content []byte
+}
+
+// SDKGenerator generates client libraries for endpoints from their OpenAPI
+// specs. The generators are bundled, so no external tooling is needed.
+type SDKGenerator struct{}
+
+// NewSDKGenerator creates a new SDK generator
+func NewSDKGenerator() *SDKGenerator {
+	return &SDKGenerator{}
+}
+
+// sdkModel is what the language templates render
+type sdkModel struct {
+	Title       string
+	Description string
+	Version     string
+	ServerURL   string
+	PackageName string
+	ModuleName  string
+	APIKey      bool
+	Bearer      bool
+	Operations  []sdkOperation
+	Sample      *sdkOperation
+}
+
+// sdkOperation is a tool call exposed as a client method
+type sdkOperation struct {
+	ToolName    string
+	Method      string
+	TypeName    string
+	Description string
+	Example     string
+	Params      []sdkParam
+	FreeForm    bool
+}
+
+// HasRequired reports whether the tool has required arguments
+func (o sdkOperation) HasRequired() bool {
+	for _, param := range o.Params {
+		if param.Required {
+			return true
+		}
+	}
+	return false
+}
+
+// sdkParam is a tool argument
+type sdkParam struct {
+	Name        string
+	Ident       string
+	Type        string
+	Description string
+	Required    bool
+}
+
+// Generate renders a client library for the endpoint described by spec in
+// language. File paths are relative to the library's root directory.
+func (g *SDKGenerator) Generate(spec *OpenAPISpec, endpointName, language string) ([]SDKFile, error) {
+	switch language {
+	case SDKLanguagePython:
+		return renderSDK(pythonTemplates, g.model(spec, endpointName, language))
+	case SDKLanguageTypeScript:
+		return renderSDK(typescriptTemplates, g.model(spec, endpointName, language))
+	default:
+		return nil, types.NewValidationError(fmt.Sprintf("unsupported SDK language %q, use one of: %s",
+			language, strings.Join(SDKLanguages, ", ")))
+	}
+}
+
+// WriteSDKArchive zips files under the directory root
+func WriteSDKArchive(w io.Writer, root string, files []SDKFile) error {
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		entry, err := archive.Create(root + "/" + file.Path)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to SDK archive: %w", file.Path, err)
+		}
+		if _, err := entry.Write(file.Content); err != nil {
+			return fmt.Errorf("failed to write %s to SDK archive: %w", file.Path, err)
+		}
+	}
+	return archive.Close()
+}
+
+func (g *SDKGenerator) model(spec *OpenAPISpec, endpointName, language string) *sdkModel {
+	words := identifierWords(endpointName)
+	if len(words) == 0 {
+		words = []string{"endpoint"}
+	}
+
+	model := &sdkModel{
+		Title:       spec.Info.Title,
+		Description: spec.Info.Description,
+		Version:     spec.Info.Version,
+		PackageName: strings.Join(words, "-") + "-client",
+		ModuleName:  strings.Join(words, "_") + "_client",
+	}
+	if len(spec.Servers) > 0 {
+		model.ServerURL = spec.Servers[0].URL
+	}
+	if unicode.IsDigit(rune(model.ModuleName[0])) {
+		model.ModuleName = "endpoint_" + model.ModuleName
+	}
+
+	// API keys are always accepted in the header, so clients skip the query
+	// parameter; OAuth access tokens are sent as bearer tokens
+	_, model.APIKey = spec.Components.SecuritySchemes["api_key"]
+	_, model.Bearer = spec.Components.SecuritySchemes["oauth2"]
+
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		if strings.HasPrefix(path, "/tools/") {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	// Methods must not shadow the client's own methods or each other
+	used := map[string]bool{}
+	for _, reserved := range sdkClientMethods[language] {
+		used[reserved] = true
+	}
+	for _, path := range paths {
+		operation := spec.Paths[path].Post
+		if operation == nil || operation.RequestBody == nil {
+			continue
+		}
+		toolName := strings.TrimPrefix(path, "/tools/")
+		model.Operations = append(model.Operations, sdkToolOperation(toolName, operation, language, used))
+	}
+
+	// READMEs show a call to the first tool with example arguments
+	for i := range model.Operations {
+		if model.Operations[i].Example != "" {
+			model.Sample = &model.Operations[i]
+			break
+		}
+	}
+	if model.Sample == nil && len(model.Operations) > 0 {
+		model.Sample = &model.Operations[0]
+	}
+	return model
+}
+
+// sdkClientMethods are the members generated clients have for every
+// endpoint, which tool methods must not shadow
+var sdkClientMethods = map[string][]string{
+	SDKLanguagePython:     {"call_tool", "list_tools", "base_url", "timeout"},
+	SDKLanguageTypeScript: {"callTool", "listTools", "constructor", "request", "baseUrl", "headers", "timeoutMs", "fetchImpl"},
+}
+
+func sdkToolOperation(toolName string, operation *Operation, language string, used map[string]bool) sdkOperation {
+	media := operation.RequestBody.Content["application/json"]
+	schema := media.Schema
+
+	op := sdkOperation{
+		ToolName:    toolName,
+		Description: operation.Description,
+		FreeForm:    len(schema.Properties) == 0,
+	}
+	words := identifierWords(toolName)
+	if len(words) == 0 {
+		words = []string{"tool"}
+	}
+	if language == SDKLanguagePython {
+		op.Method = uniqueIdentifier(pythonIdentifier(strings.Join(words, "_"), "tool"), used)
+	} else {
+		op.Method = uniqueIdentifier(typescriptIdentifier(camelCase(words)), used)
+	}
+	op.TypeName = pascalCase(strings.Split(op.Method, "_")) + "Input"
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Python takes arguments as keyword parameters, required ones first
+	params := map[string]bool{"self": true, "arguments": true}
+	for _, name := range names {
+		property := schema.Properties[name]
+		param := sdkParam{
+			Name:        name,
+			Description: property.Description,
+			Required:    required[name],
+		}
+		if language == SDKLanguagePython {
+			param.Ident = uniqueIdentifier(pythonIdentifier(strings.Join(identifierWords(name), "_"), "arg"), params)
+			param.Type = pythonType(property)
+			if !param.Required && !strings.HasPrefix(param.Type, "Optional[") {
+				param.Type = "Optional[" + param.Type + "]"
+			}
+		} else {
+			param.Type = typescriptType(property, 0)
+		}
+		op.Params = append(op.Params, param)
+	}
+	sort.SliceStable(op.Params, func(i, j int) bool {
+		return op.Params[i].Required && !op.Params[j].Required
+	})
+
+	if example, ok := media.Example.(map[string]interface{}); ok {
+		op.Example = sdkExample(op, example, language)
+	}
+	return op
+}
+
+// sdkExample renders example arguments as they are passed to the tool's
+// method: keyword arguments in Python and an object in TypeScript
+func sdkExample(op sdkOperation, example map[string]interface{}, language string) string {
+	if language != SDKLanguagePython {
+		encoded, err := json.MarshalIndent(example, "", "  ")
+		if err != nil {
+			return ""
+		}
+		return string(encoded)
+	}
+	if op.FreeForm {
+		return pythonLiteral(example)
+	}
+	var args []string
+	for _, param := range op.Params {
+		if value, ok := example[param.Name]; ok {
+			args = append(args, param.Ident+"="+pythonLiteral(value))
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// pythonLiteral renders a decoded JSON value as a Python expression
+func pythonLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case string:
+		return quoteString(v)
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, pythonLiteral(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, 0, len(keys))
+		for _, key := range keys {
+			items = append(items, quoteString(key)+": "+pythonLiteral(v[key]))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "None"
+		}
+		return string(encoded)
+	}
+}
+
+// identifierWords splits a name into lower case words at punctuation and
+// camel case boundaries
+func identifierWords(name string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)):
+			flush()
+		case unicode.IsUpper(r) && len(word) > 0 &&
+			(unicode.IsLower(word[len(word)-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return words
+}
+
+func camelCase(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	return words[0] + pascalCase(words[1:])
+}
+
+func pascalCase(words []string) string {
+	var b strings.Builder
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+func uniqueIdentifier(name string, used map[string]bool) string {
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s_%d", name, i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+var pythonKeywords = map[string]bool{
+	"false": true, "none": true, "true": true, "and": true, "as": true, "assert": true, "async": true,
+	"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true,
+	"else": true, "except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true,
+	"pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// pythonIdentifier makes name a valid identifier, prefixing names that
+// start with a digit
+func pythonIdentifier(name, prefix string) string {
+	if name == "" {
+		return prefix
+	}
+	if unicode.IsDigit(rune(name[0])) {
+		return prefix + "_" + name
+	}
+	if pythonKeywords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+var typescriptKeywords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true, "return": true,
+	"super": true, "switch": true, "this": true, "throw": true, "true": true, "try": true, "typeof": true,
+	"var": true, "void": true, "while": true, "with": true,
+}
+
+func typescriptIdentifier(name string) string {
+	if name == "" {
+		return "tool"
+	}
+	if unicode.IsDigit(rune(name[0])) || typescriptKeywords[name] {
+		return "tool" + strings.ToUpper(name[:1]) + name[1:]
+	}
+	return name
+}
+
+func pythonType(schema Schema) string {
+	var t string
+	switch schema.Type {
+	case "string":
+		t = "str"
+	case "integer":
+		t = "int"
+	case "number":
+		t = "float"
+	case "boolean":
+		t = "bool"
+	case "array":
+		item := "Any"
+		if schema.Items != nil {
+			item = pythonType(*schema.Items)
+		}
+		t = "List[" + item + "]"
+	case "object":
+		t = "Dict[str, Any]"
+	default:
+		return "Any"
+	}
+	if schema.Nullable {
+		return "Optional[" + t + "]"
+	}
+	return t
+}
+
+func typescriptType(schema Schema, depth int) string {
+	var t string
+	switch {
+	case len(schema.Enum) > 0:
+		literals := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			if encoded, err := json.Marshal(value); err == nil {
+				literals = append(literals, string(encoded))
+			}
+		}
+		t = strings.Join(literals, " | ")
+	case schema.Type == "string":
+		t = "string"
+	case schema.Type == "integer" || schema.Type == "number":
+		t = "number"
+	case schema.Type == "boolean":
+		t = "boolean"
+	case schema.Type == "array":
+		item := "unknown"
+		if schema.Items != nil {
+			item = typescriptType(*schema.Items, depth+1)
+		}
+		if strings.ContainsAny(item, " |") {
+			item = "(" + item + ")"
+		}
+		t = item + "[]"
+	case schema.Type == "object" && len(schema.Properties) > 0 && depth < maxExampleDepth:
+		required := make(map[string]bool, len(schema.Required))
+		for _, name := range schema.Required {
+			required[name] = true
+		}
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fields := make([]string, 0, len(names))
+		for _, name := range names {
+			optional := "?"
+			if required[name] {
+				optional = ""
+			}
+			fields = append(fields, fmt.Sprintf("%s%s: %s", typescriptKey(name), optional, typescriptType(schema.Properties[name], depth+1)))
+		}
+		t = "{ " + strings.Join(fields, "; ") + " }"
+	case schema.Type == "object":
+		t = "Record<string, unknown>"
+	default:
+		return "unknown"
+	}
+	if schema.Nullable {
+		return t + " | null"
+	}
+	return t
+}
+
+// typescriptKey quotes property names that are not identifiers
+func typescriptKey(name string) string {
+	for i, r := range name {
+		if !(r == '_' || r == '$' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) || r > unicode.MaxASCII {
+			return quoteString(name)
+		}
+	}
+	if name == "" {
+		return `""`
+	}
+	return name
+}
+
+// quoteString returns s as a string literal valid in Python and TypeScript
+func quoteString(s string) string {
+	encoded, _ := json.Marshal(s)
+	return string(encoded)
+}
+
+// pythonDoc makes s safe inside a Python docstring
+func pythonDoc(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), `\`, `\\`)
+	return strings.ReplaceAll(s, `"""`, `\"\"\"`)
+}
+
+// typescriptDoc makes s safe inside a TypeScript block comment
+func typescriptDoc(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "*/", "*\\/")
+}
+
+var sdkFuncs = template.FuncMap{
+	"quote": quoteString,
+	"pydoc": pythonDoc,
+	"tsdoc": typescriptDoc,
+	"indent": func(prefix, s string) string {
+		return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+	},
+	"hang": func(prefix, s string) string {
+		return strings.ReplaceAll(s, "\n", "\n"+prefix)
+	},
+	"key": typescriptKey,
+	"summary": func(s string) string {
+		line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+		return line
+	},
+}
+
+func renderSDK(templates map[string]string, model *sdkModel) ([]SDKFile, error) {
+	paths := make([]string, 0, len(templates))
+	for path := range templates {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	files := make([]SDKFile, 0, len(paths))
+	for _, path := range paths {
+		tmpl, err := template.New(path).Funcs(sdkFuncs).Parse(templates[path])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SDK template %s: %w", path, err)
+		}
+		var content bytes.Buffer
+		if err := tmpl.Execute(&content, model); err != nil {
+			return nil, fmt.Errorf("failed to render SDK template %s: %w", path, err)
+		}
+		files = append(files, SDKFile{
+			Path:    strings.ReplaceAll(path, "{module}", model.ModuleName),
+			Content: content.Bytes(),
+		})
+	}
+	return files, nil
+}
@@ -0,0 +1,356 @@
+package services
+
+// SDK templates render an sdkModel, keyed by file path. "{module}" in a
+// path is replaced by the model's module name. Templates avoid backticks,
+// so TypeScript code concatenates strings and READMEs use indented code.
+
+var pythonTemplates = map[string]string{
+	"pyproject.toml": `[build-system]
+requires = ["setuptools>=61"]
+build-backend = "setuptools.build_meta"
+
+[project]
+name = {{quote .PackageName}}
+version = {{quote .Version}}
+description = {{quote .Title}}
+readme = "README.md"
+requires-python = ">=3.8"
+dependencies = []
+
+[tool.setuptools]
+packages = [{{quote .ModuleName}}]
+`,
+
+	"README.md": `# {{.Title}}
+
+Python client for this endpoint's tools, generated from its OpenAPI spec.
+It only needs the standard library.
+
+## Install
+
+    pip install .
+
+## Usage
+
+    from {{.ModuleName}} import APIError, Client
+
+    client = Client({{if .APIKey}}api_key="YOUR_API_KEY"{{else if .Bearer}}access_token="YOUR_ACCESS_TOKEN"{{end}})
+    print(client.list_tools())
+{{- with .Sample}}
+    result = client.{{.Method}}({{.Example}}){{end}}
+
+Calls that fail raise APIError, which carries the HTTP status and the
+error body.
+
+## Tools
+{{range .Operations}}
+- {{.Method}}: calls {{.ToolName}}{{if .Description}}. {{summary .Description}}{{end}}
+{{- end}}
+`,
+
+	"{module}/__init__.py": `"""{{pydoc .Title}} client."""
+
+from .client import DEFAULT_BASE_URL, APIError, Client
+
+__all__ = ["DEFAULT_BASE_URL", "APIError", "Client"]
+__version__ = {{quote .Version}}
+`,
+
+	"{module}/client.py": `"""{{pydoc .Title}} client.
+
+Generated from the endpoint's OpenAPI spec.
+"""
+
+import json
+import urllib.error
+import urllib.parse
+import urllib.request
+from typing import Any, Dict, List, Optional
+
+DEFAULT_BASE_URL = {{quote .ServerURL}}
+
+
+class APIError(Exception):
+    """Raised when the endpoint answers with an error status."""
+
+    def __init__(self, status: int, body: Any):
+        self.status = status
+        self.body = body
+        message = body.get("error") if isinstance(body, dict) else body
+        if isinstance(message, dict):
+            message = message.get("message")
+        super().__init__("HTTP {}: {}".format(status, message))
+
+
+class Client:
+    """Calls the tools of the endpoint."""
+
+    def __init__(
+        self,
+        base_url: str = DEFAULT_BASE_URL,
+{{- if .APIKey}}
+        api_key: Optional[str] = None,
+{{- end}}
+{{- if .Bearer}}
+        access_token: Optional[str] = None,
+{{- end}}
+        timeout: float = 30.0,
+    ):
+        self.base_url = base_url.rstrip("/")
+        self.timeout = timeout
+        self._headers = {"Content-Type": "application/json", "Accept": "application/json"}
+{{- if .APIKey}}
+        if api_key:
+            self._headers["X-API-Key"] = api_key
+{{- end}}
+{{- if .Bearer}}
+        if access_token:
+            self._headers["Authorization"] = "Bearer " + access_token
+{{- end}}
+
+    def list_tools(self) -> Any:
+        """List the endpoint's tools."""
+        return self._request("GET", "/tools")
+
+    def call_tool(self, name: str, arguments: Optional[Dict[str, Any]] = None) -> Any:
+        """Call a tool by name with its arguments."""
+        path = "/tools/" + urllib.parse.quote(name, safe="")
+        return self._request("POST", path, arguments or {})
+{{range .Operations}}
+    def {{.Method}}(
+        self,
+{{- if .FreeForm}}
+        arguments: Optional[Dict[str, Any]] = None,
+{{- else}}{{range .Params}}
+        {{.Ident}}: {{.Type}}{{if not .Required}} = None{{end}},
+{{- end}}{{end}}
+    ) -> Any:
+        """
+{{- if .Description}}
+{{indent "        " (pydoc .Description)}}
+        """
+{{- else}}Call the {{pydoc .ToolName}} tool."""
+{{- end}}
+{{- if .FreeForm}}
+        return self.call_tool({{quote .ToolName}}, arguments)
+{{- else}}
+        arguments: Dict[str, Any] = {}
+{{- range .Params}}
+{{- if .Required}}
+        arguments[{{quote .Name}}] = {{.Ident}}
+{{- else}}
+        if {{.Ident}} is not None:
+            arguments[{{quote .Name}}] = {{.Ident}}
+{{- end}}
+{{- end}}
+        return self.call_tool({{quote .ToolName}}, arguments)
+{{- end}}
+{{end}}
+    def _request(self, method: str, path: str, body: Optional[Dict[str, Any]] = None) -> Any:
+        data = json.dumps(body).encode("utf-8") if body is not None else None
+        request = urllib.request.Request(self.base_url + path, data=data, headers=self._headers, method=method)
+        try:
+            with urllib.request.urlopen(request, timeout=self.timeout) as response:
+                payload = response.read()
+        except urllib.error.HTTPError as err:
+            raw = err.read()
+            try:
+                detail = json.loads(raw)
+            except ValueError:
+                detail = raw.decode("utf-8", "replace")
+            raise APIError(err.code, detail) from None
+        return json.loads(payload) if payload else None
+`,
+}
+
+var typescriptTemplates = map[string]string{
+	"package.json": `{
+  "name": {{quote .PackageName}},
+  "version": {{quote .Version}},
+  "description": {{quote .Title}},
+  "main": "dist/index.js",
+  "types": "dist/index.d.ts",
+  "files": ["dist"],
+  "scripts": {
+    "build": "tsc",
+    "prepare": "tsc"
+  },
+  "devDependencies": {
+    "typescript": "^5.4.0"
+  },
+  "engines": {
+    "node": ">=18"
+  }
+}
+`,
+
+	"tsconfig.json": `{
+  "compilerOptions": {
+    "target": "ES2020",
+    "module": "commonjs",
+    "lib": ["ES2020", "DOM"],
+    "declaration": true,
+    "outDir": "dist",
+    "rootDir": "src",
+    "strict": true
+  },
+  "include": ["src"]
+}
+`,
+
+	"README.md": `# {{.Title}}
+
+TypeScript client for this endpoint's tools, generated from its OpenAPI
+spec. It uses the fetch API of Node.js 18 and browsers.
+
+## Install
+
+    npm install
+    npm run build
+
+## Usage
+
+    import { ApiError, Client } from {{quote .PackageName}};
+
+    const client = new Client({ {{- if .APIKey}} apiKey: "YOUR_API_KEY" {{else if .Bearer}} accessToken: "YOUR_ACCESS_TOKEN" {{end -}} });
+    console.log(await client.listTools());
+{{- with .Sample}}
+    const result = await client.{{.Method}}({{hang "    " .Example}});{{end}}
+
+Calls that fail throw ApiError, which carries the HTTP status and the
+error body.
+
+## Tools
+{{range .Operations}}
+- {{.Method}}: calls {{.ToolName}}{{if .Description}}. {{summary .Description}}{{end}}
+{{- end}}
+`,
+
+	"src/index.ts": `/**
+ * {{tsdoc .Title}} client.
+ *
+ * Generated from the endpoint's OpenAPI spec.
+ */
+
+export const DEFAULT_BASE_URL = {{quote .ServerURL}};
+
+export interface ClientOptions {
+  baseUrl?: string;
+{{- if .APIKey}}
+  /** API key, sent in the X-API-Key header */
+  apiKey?: string;
+{{- end}}
+{{- if .Bearer}}
+  /** Access token, sent as a bearer token */
+  accessToken?: string;
+{{- end}}
+  /** Request timeout in milliseconds */
+  timeoutMs?: number;
+  fetch?: typeof fetch;
+}
+
+/** Thrown when the endpoint answers with an error status */
+export class ApiError extends Error {
+  readonly status: number;
+  readonly body: unknown;
+
+  constructor(status: number, body: unknown) {
+    super("HTTP " + status + ": " + errorMessage(body));
+    this.name = "ApiError";
+    this.status = status;
+    this.body = body;
+  }
+}
+
+function errorMessage(body: unknown): string {
+  if (body && typeof body === "object" && "error" in body) {
+    const error = (body as { error: unknown }).error;
+    if (error && typeof error === "object" && "message" in error) {
+      return String((error as { message: unknown }).message);
+    }
+    return String(error);
+  }
+  return String(body);
+}
+{{range .Operations}}{{if not .FreeForm}}
+export interface {{.TypeName}} {
+{{- range .Params}}
+{{- if .Description}}
+  /** {{tsdoc .Description}} */
+{{- end}}
+  {{key .Name}}{{if not .Required}}?{{end}}: {{.Type}};
+{{- end}}
+}
+{{end}}{{end}}
+export class Client {
+  private readonly baseUrl: string;
+  private readonly headers: Record<string, string>;
+  private readonly timeoutMs: number;
+  private readonly fetchImpl: typeof fetch;
+
+  constructor(options: ClientOptions = {}) {
+    this.baseUrl = (options.baseUrl ?? DEFAULT_BASE_URL).replace(/\/+$/, "");
+    this.timeoutMs = options.timeoutMs ?? 30000;
+    this.fetchImpl = options.fetch ?? globalThis.fetch.bind(globalThis);
+    this.headers = { "Content-Type": "application/json", Accept: "application/json" };
+{{- if .APIKey}}
+    if (options.apiKey) {
+      this.headers["X-API-Key"] = options.apiKey;
+    }
+{{- end}}
+{{- if .Bearer}}
+    if (options.accessToken) {
+      this.headers["Authorization"] = "Bearer " + options.accessToken;
+    }
+{{- end}}
+  }
+
+  /** Lists the endpoint's tools */
+  listTools(): Promise<unknown> {
+    return this.request("GET", "/tools");
+  }
+
+  /** Calls a tool by name with its arguments */
+  callTool(name: string, args: object = {}): Promise<unknown> {
+    return this.request("POST", "/tools/" + encodeURIComponent(name), args);
+  }
+{{range .Operations}}
+  /**
+{{- if .Description}}
+{{indent "   * " (tsdoc .Description)}}
+{{- else}}
+   * Calls the {{tsdoc .ToolName}} tool
+{{- end}}
+   */
+  {{.Method}}(input: {{if .FreeForm}}Record<string, unknown> = {}{{else}}{{.TypeName}}{{if not .HasRequired}} = {}{{end}}{{end}}): Promise<unknown> {
+    return this.callTool({{quote .ToolName}}, input);
+  }
+{{end}}
+  private async request(method: string, path: string, body?: object): Promise<unknown> {
+    const controller = new AbortController();
+    const timer = setTimeout(() => controller.abort(), this.timeoutMs);
+    try {
+      const response = await this.fetchImpl(this.baseUrl + path, {
+        method,
+        headers: this.headers,
+        body: body === undefined ? undefined : JSON.stringify(body),
+        signal: controller.signal,
+      });
+      const text = await response.text();
+      let payload: unknown = text;
+      try {
+        payload = text ? JSON.parse(text) : undefined;
+      } catch {
+        // Not JSON; keep the raw text
+      }
+      if (!response.ok) {
+        throw new ApiError(response.status, payload);
+      }
+      return payload;
+    } finally {
+      clearTimeout(timer);
+    }
+  }
+}
+`,
+}
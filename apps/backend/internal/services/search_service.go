@@ -0,0 +1,353 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultSearchLimit is the page size when none is requested
+	defaultSearchLimit = 20
+	// maxSearchQueryLength bounds the query text
+	maxSearchQueryLength = 256
+	// maxSearchPrefixWords bounds the words matched as name prefixes
+	maxSearchPrefixWords = 8
+	// searchFusionK damps the reciprocal rank fusion of hybrid search, so
+	// entries ranked well by both searches beat entries ranked first by one
+	searchFusionK = 60
+	// embeddingTimeout bounds each call to the embeddings API
+	embeddingTimeout = 30 * time.Second
+)
+
+// searchWords matches the words of a query that are matched as prefixes
+var searchWords = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// SearchStore searches the catalog and keeps its embeddings
+type SearchStore interface {
+	Search(orgID string, query *types.SearchQuery, prefix string) ([]*types.SearchResult, int, error)
+	SemanticSearch(orgID string, query *types.SearchQuery, model string, vector []float32, limit int) ([]*types.SearchResult, error)
+	ListEmbeddingCandidates(model string, limit int) ([]*types.SearchDocument, error)
+	UpsertEmbedding(document *types.SearchDocument, model string, embedding []float32) error
+}
+
+// Embedder turns text into embeddings
+type Embedder interface {
+	Model() string
+	Embed(ctx context.Context, inputs []string) ([][]float32, error)
+}
+
+// SearchService searches an organization's tools, prompts and resources by
+// full text and, when an embedder is configured, by meaning
+type SearchService struct {
+	store    SearchStore
+	embedder Embedder
+}
+
+// NewSearchService creates a database-backed search service. A nil
+// embedder limits search to full text.
+func NewSearchService(db *sql.DB, embedder Embedder) *SearchService {
+	return NewSearchServiceWithStore(models.NewCatalogSearchModel(db), embedder)
+}
+
+// NewSearchServiceWithStore creates a search service over store
+func NewSearchServiceWithStore(store SearchStore, embedder Embedder) *SearchService {
+	return &SearchService{store: store, embedder: embedder}
+}
+
+// SemanticEnabled reports whether semantic and hybrid search are available
+func (s *SearchService) SemanticEnabled() bool {
+	return s.embedder != nil
+}
+
+// Search ranks the organization's catalog entries matching query. Hybrid
+// search, the default when semantic search is available, fuses the
+// full-text and semantic rankings and falls back to full text when the
+// query cannot be embedded.
+func (s *SearchService) Search(ctx context.Context, orgID string, query *types.SearchQuery) (*types.SearchResponse, error) {
+	query, err := s.normalizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	switch query.Mode {
+	case types.SearchModeSemantic:
+		results, err := s.semanticSearch(ctx, orgID, query, query.Offset+query.Limit)
+		if err != nil {
+			return nil, err
+		}
+		return &types.SearchResponse{Results: pageResults(results, query.Offset, query.Limit), Mode: query.Mode, Total: len(results)}, nil
+
+	case types.SearchModeHybrid:
+		window := query.Offset + query.Limit
+		fullText := *query
+		fullText.Limit, fullText.Offset = window, 0
+		textResults, total, err := s.store.Search(orgID, &fullText, prefixQuery(query.Query))
+		if err != nil {
+			return nil, types.NewInternalError("Failed to search catalog: " + err.Error())
+		}
+		semanticResults, err := s.semanticSearch(ctx, orgID, query, window)
+		if err != nil {
+			log.Printf("Warning: semantic search failed, using full-text results: %v", err)
+			return &types.SearchResponse{Results: pageResults(textResults, query.Offset, query.Limit), Mode: types.SearchModeFullText, Total: total}, nil
+		}
+		fused := fuseRankings(textResults, semanticResults)
+		return &types.SearchResponse{Results: pageResults(fused, query.Offset, query.Limit), Mode: query.Mode, Total: max(total, len(fused))}, nil
+
+	default:
+		results, total, err := s.store.Search(orgID, query, prefixQuery(query.Query))
+		if err != nil {
+			return nil, types.NewInternalError("Failed to search catalog: " + err.Error())
+		}
+		return &types.SearchResponse{Results: results, Mode: query.Mode, Total: total}, nil
+	}
+}
+
+// EmbedPending embeds up to batch catalog entries that are new or changed
+// since they were embedded, returning how many were embedded
+func (s *SearchService) EmbedPending(ctx context.Context, batch int) (int, error) {
+	if s.embedder == nil {
+		return 0, nil
+	}
+
+	documents, err := s.store.ListEmbeddingCandidates(s.embedder.Model(), batch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list catalog entries to embed: %w", err)
+	}
+	if len(documents) == 0 {
+		return 0, nil
+	}
+
+	inputs := make([]string, len(documents))
+	for i, document := range documents {
+		inputs[i] = document.Text
+	}
+	embeddings, err := s.embedder.Embed(ctx, inputs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to embed catalog entries: %w", err)
+	}
+	if len(embeddings) != len(documents) {
+		return 0, fmt.Errorf("embeddings API returned %d embeddings for %d inputs", len(embeddings), len(documents))
+	}
+
+	embedded := 0
+	for i, document := range documents {
+		if err := s.store.UpsertEmbedding(document, s.embedder.Model(), embeddings[i]); err != nil {
+			return embedded, fmt.Errorf("failed to store embedding of %s %s: %w", document.Type, document.ID, err)
+		}
+		embedded++
+	}
+	return embedded, nil
+}
+
+// normalizeQuery validates a query and fills in its defaults. Types and
+// tags may be given comma separated, and types in the plural.
+func (s *SearchService) normalizeQuery(query *types.SearchQuery) (*types.SearchQuery, error) {
+	normalized := *query
+	normalized.Query = strings.TrimSpace(query.Query)
+	if !searchWords.MatchString(normalized.Query) {
+		return nil, types.NewValidationError("q must contain a word to search for")
+	}
+	if len(normalized.Query) > maxSearchQueryLength {
+		return nil, types.NewValidationError(fmt.Sprintf("q must be at most %d characters", maxSearchQueryLength))
+	}
+
+	normalized.Types = nil
+	for _, itemType := range splitList(query.Types) {
+		itemType = strings.TrimSuffix(strings.ToLower(itemType), "s")
+		if !slices.Contains(types.SearchTypes, itemType) {
+			return nil, types.NewValidationError("types must be tools, prompts or resources")
+		}
+		if !slices.Contains(normalized.Types, itemType) {
+			normalized.Types = append(normalized.Types, itemType)
+		}
+	}
+	normalized.Tags = splitList(query.Tags)
+
+	for field, id := range map[string]string{"server_id": query.ServerID, "namespace_id": query.NamespaceID} {
+		if _, err := uuid.Parse(id); id != "" && err != nil {
+			return nil, types.NewValidationError(field + " must be a UUID")
+		}
+	}
+
+	if normalized.Limit == 0 {
+		normalized.Limit = defaultSearchLimit
+	}
+
+	switch normalized.Mode {
+	case "":
+		normalized.Mode = types.SearchModeFullText
+		if s.embedder != nil {
+			normalized.Mode = types.SearchModeHybrid
+		}
+	case types.SearchModeFullText:
+	case types.SearchModeSemantic, types.SearchModeHybrid:
+		if s.embedder == nil {
+			return nil, types.NewValidationError("Semantic search is not configured")
+		}
+	default:
+		return nil, types.NewValidationError("mode must be fulltext, semantic or hybrid")
+	}
+	return &normalized, nil
+}
+
+// semanticSearch returns the limit entries closest in meaning to the query
+func (s *SearchService) semanticSearch(ctx context.Context, orgID string, query *types.SearchQuery, limit int) ([]*types.SearchResult, error) {
+	embeddings, err := s.embedder.Embed(ctx, []string{query.Query})
+	if err != nil || len(embeddings) != 1 {
+		return nil, types.NewInternalError(fmt.Sprintf("Failed to embed query: %v", err))
+	}
+	results, err := s.store.SemanticSearch(orgID, query, s.embedder.Model(), embeddings[0], limit)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to search catalog: " + err.Error())
+	}
+	return results, nil
+}
+
+// prefixQuery builds a tsquery matching entries with words starting with
+// each word of the query, so partially typed names match
+func prefixQuery(query string) string {
+	words := searchWords.FindAllString(strings.ToLower(query), maxSearchPrefixWords)
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// fuseRankings merges rankings by reciprocal rank fusion, scoring each
+// entry by the sum of 1/(k+rank) over the rankings it appears in
+func fuseRankings(rankings ...[]*types.SearchResult) []*types.SearchResult {
+	fused := map[string]*types.SearchResult{}
+	var order []*types.SearchResult
+	for _, ranking := range rankings {
+		for rank, result := range ranking {
+			key := result.Type + ":" + result.ID
+			entry, ok := fused[key]
+			if !ok {
+				copied := *result
+				entry = &copied
+				entry.Score = 0
+				fused[key] = entry
+				order = append(order, entry)
+			}
+			entry.Score += 1 / float64(searchFusionK+rank+1)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return order[i].Score > order[j].Score })
+	return order
+}
+
+// pageResults returns the results from offset, at most limit of them
+func pageResults(results []*types.SearchResult, offset, limit int) []*types.SearchResult {
+	if offset >= len(results) {
+		return []*types.SearchResult{}
+	}
+	return results[offset:min(offset+limit, len(results))]
+}
+
+// splitList splits comma separated values and drops empty ones
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// HTTPEmbedder calls an OpenAI-compatible embeddings API
+type HTTPEmbedder struct {
+	client   *http.Client
+	endpoint string
+	model    string
+	apiKey   string
+}
+
+// NewHTTPEmbedder creates an embedder posting to endpoint, which the egress
+// policy must allow
+func NewHTTPEmbedder(endpoint, model, apiKey string, egress EgressPolicy) (*HTTPEmbedder, error) {
+	if egress != nil {
+		if err := egress.CheckURL(types.ConnectivityFeatureSearchEmbeddings, endpoint); err != nil {
+			return nil, err
+		}
+	}
+	return &HTTPEmbedder{
+		client:   &http.Client{Timeout: embeddingTimeout},
+		endpoint: endpoint,
+		model:    model,
+		apiKey:   apiKey,
+	}, nil
+}
+
+// Model returns the embedding model
+func (e *HTTPEmbedder) Model() string {
+	return e.model
+}
+
+// Embed returns the embeddings of inputs, in order
+func (e *HTTPEmbedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{"model": e.model, "input": inputs})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("embeddings API returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings: %w", err)
+	}
+
+	embeddings := make([][]float32, len(inputs))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(inputs) {
+			return nil, fmt.Errorf("embeddings API returned index %d for %d inputs", item.Index, len(inputs))
+		}
+		embeddings[item.Index] = item.Embedding
+	}
+	for i, embedding := range embeddings {
+		if len(embedding) == 0 {
+			return nil, fmt.Errorf("embeddings API returned no embedding for input %d", i)
+		}
+	}
+	return embeddings, nil
+}
@@ -0,0 +1,429 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// DefaultMaxAPIKeyAge is how old an API key may get before it should be rotated
+const DefaultMaxAPIKeyAge = 90 * 24 * time.Hour
+
+// minRecommendedPasswordLength is the shortest password policy considered adequate
+const minRecommendedPasswordLength = 12
+
+// defaultPasswords are the credentials created by the setup and migrate
+// commands, plus common placeholders, checked against admin accounts
+var defaultPasswords = []string{"qwerty123", "password123", "admin", "password", "changeme"}
+
+// securitySeverityWeights determines how much each check contributes to the score
+var securitySeverityWeights = map[string]int{
+	types.SecuritySeverityCritical: 25,
+	types.SecuritySeverityHigh:     15,
+	types.SecuritySeverityMedium:   10,
+	types.SecuritySeverityLow:      5,
+}
+
+// AuthConfigProvider supplies an organization's authentication configuration
+type AuthConfigProvider interface {
+	GetConfiguration(orgID uuid.UUID) (*types.AuthConfigurationResponse, error)
+}
+
+// SecurityPostureFacts is the configuration state a posture report is evaluated against
+type SecurityPostureFacts struct {
+	// AuthConfig is nil when the configuration could not be loaded
+	AuthConfig *types.AuthConfigurationResponse
+	// DefaultCredentialUsers are admin accounts still using a known default password
+	DefaultCredentialUsers []string
+	// StaleAPIKeys are active keys older than the maximum key age
+	StaleAPIKeys []string
+	// NonExpiringAPIKeys are active keys with no expiry date
+	NonExpiringAPIKeys []string
+	// PublicEndpoints are active endpoints that allow unauthenticated access
+	PublicEndpoints []string
+	// QueryParamAuthEndpoints accept API keys in the URL
+	QueryParamAuthEndpoints []string
+	// PermissivePolicies are active access policies that allow everything
+	PermissivePolicies []string
+	MaxAPIKeyAge       time.Duration
+}
+
+// SecurityPostureService evaluates organizations against security best practices
+type SecurityPostureService struct {
+	db         *sql.DB
+	authConfig AuthConfigProvider
+}
+
+// NewSecurityPostureService creates a new security posture service
+func NewSecurityPostureService(db *sql.DB, authConfig AuthConfigProvider) *SecurityPostureService {
+	return &SecurityPostureService{
+		db:         db,
+		authConfig: authConfig,
+	}
+}
+
+// GenerateReport gathers the organization's configuration and scores it
+func (s *SecurityPostureService) GenerateReport(ctx context.Context, orgID string, maxKeyAge time.Duration) (*types.SecurityPostureReport, error) {
+	orgUUID, err := uuid.Parse(orgID)
+	if err != nil {
+		return nil, types.NewValidationError("invalid organization ID")
+	}
+	if maxKeyAge <= 0 {
+		maxKeyAge = DefaultMaxAPIKeyAge
+	}
+
+	facts := &SecurityPostureFacts{MaxAPIKeyAge: maxKeyAge}
+
+	if s.authConfig != nil {
+		if config, err := s.authConfig.GetConfiguration(orgUUID); err == nil {
+			facts.AuthConfig = config
+		}
+	}
+
+	if facts.DefaultCredentialUsers, err = s.defaultCredentialUsers(ctx, orgID); err != nil {
+		return nil, err
+	}
+	if err := s.collectAPIKeyFacts(ctx, orgID, facts); err != nil {
+		return nil, err
+	}
+	if err := s.collectEndpointFacts(ctx, orgID, facts); err != nil {
+		return nil, err
+	}
+	if facts.PermissivePolicies, err = s.permissivePolicies(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	return EvaluateSecurityPosture(orgID, facts, time.Now()), nil
+}
+
+// defaultCredentialUsers returns active admins whose password is a known default
+func (s *SecurityPostureService) defaultCredentialUsers(ctx context.Context, orgID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT email, password_hash FROM users
+		WHERE organization_id = $1 AND role = 'admin' AND is_active = true
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admin users: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var email, hash string
+		if err := rows.Scan(&email, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan admin user: %w", err)
+		}
+		for _, password := range defaultPasswords {
+			if auth.VerifySecret(password, hash) {
+				emails = append(emails, email)
+				break
+			}
+		}
+	}
+	return emails, rows.Err()
+}
+
+func (s *SecurityPostureService) collectAPIKeyFacts(ctx context.Context, orgID string, facts *SecurityPostureFacts) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, created_at, expires_at FROM api_keys
+		WHERE organization_id = $1 AND is_active = true
+	`, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	cutoff := time.Now().Add(-facts.MaxAPIKeyAge)
+	for rows.Next() {
+		var name string
+		var createdAt time.Time
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&name, &createdAt, &expiresAt); err != nil {
+			return fmt.Errorf("failed to scan API key: %w", err)
+		}
+		if createdAt.Before(cutoff) {
+			facts.StaleAPIKeys = append(facts.StaleAPIKeys, name)
+		}
+		if !expiresAt.Valid {
+			facts.NonExpiringAPIKeys = append(facts.NonExpiringAPIKeys, name)
+		}
+	}
+	return rows.Err()
+}
+
+func (s *SecurityPostureService) collectEndpointFacts(ctx context.Context, orgID string, facts *SecurityPostureFacts) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, enable_public_access, use_query_param_auth, allowed_origins FROM endpoints
+		WHERE organization_id = $1 AND is_active = true
+	`, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to list endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var public, queryParamAuth sql.NullBool
+		var origins pq.StringArray
+		if err := rows.Scan(&name, &public, &queryParamAuth, &origins); err != nil {
+			return fmt.Errorf("failed to scan endpoint: %w", err)
+		}
+		if public.Bool {
+			for _, origin := range origins {
+				if origin == "*" {
+					name += " (any origin)"
+					break
+				}
+			}
+			facts.PublicEndpoints = append(facts.PublicEndpoints, name)
+		}
+		if queryParamAuth.Bool {
+			facts.QueryParamAuthEndpoints = append(facts.QueryParamAuthEndpoints, name)
+		}
+	}
+	return rows.Err()
+}
+
+// permissivePolicies returns active access policies that match every request and allow it
+func (s *SecurityPostureService) permissivePolicies(ctx context.Context, orgID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, conditions, actions FROM policies
+		WHERE organization_id = $1 AND type = 'access' AND is_active = true
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		var conditionsJSON, actionsJSON []byte
+		if err := rows.Scan(&name, &conditionsJSON, &actionsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan policy: %w", err)
+		}
+		policy := &types.Policy{Name: name}
+		_ = json.Unmarshal(conditionsJSON, &policy.Conditions)
+		_ = json.Unmarshal(actionsJSON, &policy.Actions)
+		if IsPermissivePolicy(policy) {
+			names = append(names, name)
+		}
+	}
+	return names, rows.Err()
+}
+
+// IsPermissivePolicy reports whether a policy allows requests without
+// meaningfully restricting which requests it matches
+func IsPermissivePolicy(policy *types.Policy) bool {
+	if !containsValue(policy.Actions, "allow") {
+		return false
+	}
+	return len(policy.Conditions) == 0 || containsValue(policy.Conditions, "*")
+}
+
+// containsValue searches nested JSON values for a string, case-insensitively
+func containsValue(value interface{}, target string) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.EqualFold(v, target)
+	case map[string]interface{}:
+		for key, item := range v {
+			if strings.EqualFold(key, target) {
+				if b, ok := item.(bool); !ok || b {
+					return true
+				}
+			}
+			if containsValue(item, target) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if containsValue(item, target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EvaluateSecurityPosture scores the collected facts. Failing a critical
+// check caps the grade at F regardless of the overall score.
+func EvaluateSecurityPosture(orgID string, facts *SecurityPostureFacts, now time.Time) *types.SecurityPostureReport {
+	report := &types.SecurityPostureReport{
+		GeneratedAt:    now,
+		OrganizationID: orgID,
+	}
+
+	add := func(check types.SecurityCheck, failed bool, findings []string) {
+		check.Weight = securitySeverityWeights[check.Severity]
+		check.Status = types.SecurityCheckPass
+		if failed {
+			check.Status = types.SecurityCheckFail
+			check.Findings = findings
+		} else {
+			check.Remediation = ""
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	skip := func(check types.SecurityCheck, reason string) {
+		check.Weight = securitySeverityWeights[check.Severity]
+		check.Status = types.SecurityCheckSkip
+		check.Details = reason
+		check.Remediation = ""
+		report.Checks = append(report.Checks, check)
+	}
+
+	mfa := types.SecurityCheck{
+		ID:          "mfa_required",
+		Title:       "Multi-factor authentication is required",
+		Category:    "authentication",
+		Severity:    types.SecuritySeverityHigh,
+		Remediation: "Set methods.mfa_required to true via PUT /api/admin/auth-config.",
+	}
+	passwordPolicy := types.SecurityCheck{
+		ID:          "password_policy",
+		Title:       fmt.Sprintf("Passwords require at least %d characters", minRecommendedPasswordLength),
+		Category:    "authentication",
+		Severity:    types.SecuritySeverityMedium,
+		Remediation: fmt.Sprintf("Raise security.password_requirements.min_length to %d or more.", minRecommendedPasswordLength),
+	}
+	lockout := types.SecurityCheck{
+		ID:          "account_lockout",
+		Title:       "Accounts lock after repeated failed logins",
+		Category:    "authentication",
+		Severity:    types.SecuritySeverityMedium,
+		Remediation: "Enable security.account_security.lockout_enabled.",
+	}
+	if config := facts.AuthConfig; config != nil {
+		mfa.Details = "MFA is not required for users."
+		if config.Methods.MFARequired {
+			mfa.Details = "MFA is required for all users."
+		}
+		add(mfa, !config.Methods.MFARequired, nil)
+
+		minLength := config.Security.PasswordRequirements.MinLength
+		passwordPolicy.Details = fmt.Sprintf("Minimum password length is %d.", minLength)
+		add(passwordPolicy, minLength < minRecommendedPasswordLength, nil)
+
+		lockout.Details = "Account lockout is disabled."
+		if config.Security.AccountSecurity.LockoutEnabled {
+			lockout.Details = fmt.Sprintf("Accounts lock after %d failed attempts.", config.Security.AccountSecurity.LockoutThreshold)
+		}
+		add(lockout, !config.Security.AccountSecurity.LockoutEnabled, nil)
+	} else {
+		for _, check := range []types.SecurityCheck{mfa, passwordPolicy, lockout} {
+			skip(check, "Authentication configuration could not be loaded.")
+		}
+	}
+
+	add(types.SecurityCheck{
+		ID:          "default_admin_credentials",
+		Title:       "No admin account uses default credentials",
+		Category:    "authentication",
+		Severity:    types.SecuritySeverityCritical,
+		Details:     fmt.Sprintf("%d admin account(s) use a known default password.", len(facts.DefaultCredentialUsers)),
+		Remediation: "Change the password of each listed account, or deactivate accounts created by setup.",
+	}, len(facts.DefaultCredentialUsers) > 0, facts.DefaultCredentialUsers)
+
+	maxAgeDays := int(facts.MaxAPIKeyAge.Hours() / 24)
+	add(types.SecurityCheck{
+		ID:          "api_key_rotation",
+		Title:       fmt.Sprintf("API keys are rotated within %d days", maxAgeDays),
+		Category:    "credentials",
+		Severity:    types.SecuritySeverityMedium,
+		Details:     fmt.Sprintf("%d active API key(s) are older than %d days.", len(facts.StaleAPIKeys), maxAgeDays),
+		Remediation: "Create replacement keys, move clients over, then revoke the old keys.",
+	}, len(facts.StaleAPIKeys) > 0, facts.StaleAPIKeys)
+
+	add(types.SecurityCheck{
+		ID:          "api_key_expiry",
+		Title:       "API keys have an expiry date",
+		Category:    "credentials",
+		Severity:    types.SecuritySeverityLow,
+		Details:     fmt.Sprintf("%d active API key(s) never expire.", len(facts.NonExpiringAPIKeys)),
+		Remediation: "Set expires_at when creating keys, or configure methods.api_key_default_expiry.",
+	}, len(facts.NonExpiringAPIKeys) > 0, facts.NonExpiringAPIKeys)
+
+	add(types.SecurityCheck{
+		ID:          "public_tool_exposure",
+		Title:       "No endpoint exposes tools without authentication",
+		Category:    "exposure",
+		Severity:    types.SecuritySeverityHigh,
+		Details:     fmt.Sprintf("%d active endpoint(s) allow public access.", len(facts.PublicEndpoints)),
+		Remediation: "Disable enable_public_access on each listed endpoint, or restrict its namespace to read-only tools.",
+	}, len(facts.PublicEndpoints) > 0, facts.PublicEndpoints)
+
+	add(types.SecurityCheck{
+		ID:          "query_param_auth",
+		Title:       "API keys are not accepted in URLs",
+		Category:    "exposure",
+		Severity:    types.SecuritySeverityLow,
+		Details:     fmt.Sprintf("%d endpoint(s) accept API keys as query parameters.", len(facts.QueryParamAuthEndpoints)),
+		Remediation: "Disable use_query_param_auth so keys are not written to proxy and access logs.",
+	}, len(facts.QueryParamAuthEndpoints) > 0, facts.QueryParamAuthEndpoints)
+
+	add(types.SecurityCheck{
+		ID:          "permissive_policies",
+		Title:       "No access policy allows all requests",
+		Category:    "authorization",
+		Severity:    types.SecuritySeverityHigh,
+		Details:     fmt.Sprintf("%d active access policy(ies) allow requests without conditions.", len(facts.PermissivePolicies)),
+		Remediation: "Add conditions to each listed policy or deactivate it.",
+	}, len(facts.PermissivePolicies) > 0, facts.PermissivePolicies)
+
+	var earned, possible int
+	criticalFailed := false
+	for _, check := range report.Checks {
+		switch check.Status {
+		case types.SecurityCheckPass:
+			report.Passed++
+			earned += check.Weight
+			possible += check.Weight
+		case types.SecurityCheckFail:
+			report.Failed++
+			possible += check.Weight
+			if check.Severity == types.SecuritySeverityCritical {
+				criticalFailed = true
+			}
+		default:
+			report.Skipped++
+		}
+	}
+
+	report.Score = 100
+	if possible > 0 {
+		report.Score = int(math.Round(float64(earned) * 100 / float64(possible)))
+	}
+	report.Grade = securityGrade(report.Score)
+	if criticalFailed {
+		report.Grade = "F"
+	}
+
+	return report
+}
+
+func securityGrade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
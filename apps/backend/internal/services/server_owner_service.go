@@ -0,0 +1,476 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mailer"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	defaultOwnerEscalateAfter = 30 * time.Minute
+	ownerWebhookTimeout       = 10 * time.Second
+	defaultOwnerAlertPageSize = 50
+	maxOwnerAlertPageSize     = 200
+	// ownerSignatureHeader carries the hex HMAC-SHA256 of the webhook body
+	// keyed with the owner's webhook secret
+	ownerSignatureHeader = "X-Omnimesh-Signature"
+)
+
+// ServerOwnerStore persists server owners and the alerts sent to them
+type ServerOwnerStore interface {
+	GetServerOrganization(serverID string) (string, error)
+	GetOwner(serverID string) (*types.ServerOwner, error)
+	UpsertOwner(owner *types.ServerOwner) error
+	DeleteOwner(serverID string) (bool, error)
+	CreateAlert(alert *types.ServerOwnerAlert) error
+	MarkDelivered(id string, deliveredAt *time.Time, deliveryError string) error
+	Acknowledge(serverID, id, userID string) (bool, error)
+	ResolveOpen(serverID string) (int64, error)
+	ListAlerts(serverID string, limit int) ([]*types.ServerOwnerAlert, error)
+	ListEscalationDue(defaultMinutes int) ([]*types.ServerOwnerAlert, error)
+	MarkEscalated(id string) error
+}
+
+// EgressPolicy decides which external URLs the gateway may call
+type EgressPolicy interface {
+	CheckURL(feature, rawURL string) error
+}
+
+// AdminNotifier delivers events to an organization's admins
+type AdminNotifier interface {
+	Notify(ctx context.Context, event *types.NotificationEvent) (int, error)
+}
+
+// ServerOwnerService tells server owners directly when their server changes
+// state and escalates to organization admins when an owner does not
+// acknowledge a failure in time
+type ServerOwnerService struct {
+	store         ServerOwnerStore
+	mailer        mailer.Mailer
+	egress        EgressPolicy
+	notifier      AdminNotifier
+	signer        RequestSigner
+	client        *http.Client
+	now           func() time.Time
+	active        func(ctx context.Context) bool
+	baseURL       string
+	escalateAfter time.Duration
+}
+
+// NewServerOwnerService creates a database-backed server owner service.
+// Alerts link to acknowledgement endpoints under baseURL and escalate after
+// escalateAfter unless the owner set a period.
+func NewServerOwnerService(db *sql.DB, baseURL string, escalateAfter time.Duration) *ServerOwnerService {
+	return NewServerOwnerServiceWithStore(models.NewServerOwnerModel(db), baseURL, escalateAfter)
+}
+
+// NewServerOwnerServiceWithStore creates a server owner service over store
+func NewServerOwnerServiceWithStore(store ServerOwnerStore, baseURL string, escalateAfter time.Duration) *ServerOwnerService {
+	if escalateAfter <= 0 {
+		escalateAfter = defaultOwnerEscalateAfter
+	}
+	return &ServerOwnerService{
+		store:         store,
+		client:        &http.Client{Timeout: ownerWebhookTimeout},
+		now:           time.Now,
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		escalateAfter: escalateAfter,
+	}
+}
+
+// SetMailer makes alerts also go to owners' contact email
+func (s *ServerOwnerService) SetMailer(m mailer.Mailer) {
+	s.mailer = m
+}
+
+// SetEgressPolicy restricts the webhook URLs owners may use
+func (s *ServerOwnerService) SetEgressPolicy(policy EgressPolicy) {
+	s.egress = policy
+}
+
+// SetNotifier makes escalations notify organization admins
+func (s *ServerOwnerService) SetNotifier(notifier AdminNotifier) {
+	s.notifier = notifier
+}
+
+// SetRequestSigner makes webhooks carry a request signature when their
+// server has a signing key
+func (s *ServerOwnerService) SetRequestSigner(signer RequestSigner) {
+	s.signer = signer
+}
+
+// SetHTTPClient replaces the client webhooks are posted with
+func (s *ServerOwnerService) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// SetActiveCheck makes state change alerts be sent only while active
+// reports true. A standby region still records them.
+func (s *ServerOwnerService) SetActiveCheck(active func(ctx context.Context) bool) {
+	s.active = active
+}
+
+// GetOwner returns the owner of a server in the organization
+func (s *ServerOwnerService) GetOwner(ctx context.Context, orgID, serverID string) (*types.ServerOwner, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	owner, err := s.store.GetOwner(serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server owner: %w", err)
+	}
+	if owner == nil {
+		return nil, types.NewNotFoundError("Server owner not found")
+	}
+	return owner, nil
+}
+
+// SetOwner records the owner of a server in the organization
+func (s *ServerOwnerService) SetOwner(ctx context.Context, orgID, serverID string, req *types.SetServerOwnerRequest) (*types.ServerOwner, error) {
+	if req.ContactEmail == "" && req.WebhookURL == "" {
+		return nil, types.NewValidationError("contact_email or webhook_url is required")
+	}
+	if req.WebhookURL != "" {
+		if err := s.checkWebhookURL(req.WebhookURL); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+
+	owner := &types.ServerOwner{
+		ServerID:             serverID,
+		OrganizationID:       orgID,
+		ContactName:          req.ContactName,
+		ContactEmail:         req.ContactEmail,
+		WebhookURL:           req.WebhookURL,
+		EscalateAfterMinutes: req.EscalateAfterMinutes,
+	}
+	if req.WebhookSecret != nil {
+		owner.WebhookSecret = *req.WebhookSecret
+	} else {
+		existing, err := s.store.GetOwner(serverID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get server owner: %w", err)
+		}
+		if existing != nil {
+			owner.WebhookSecret = existing.WebhookSecret
+		}
+	}
+	owner.HasWebhookSecret = owner.WebhookSecret != ""
+
+	if err := s.store.UpsertOwner(owner); err != nil {
+		return nil, fmt.Errorf("failed to save server owner: %w", err)
+	}
+	return owner, nil
+}
+
+// DeleteOwner stops alerting the owner of a server in the organization
+func (s *ServerOwnerService) DeleteOwner(ctx context.Context, orgID, serverID string) error {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return err
+	}
+	deleted, err := s.store.DeleteOwner(serverID)
+	if err != nil {
+		return fmt.Errorf("failed to delete server owner: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("Server owner not found")
+	}
+	return nil
+}
+
+// ListAlerts returns the most recent alerts sent about a server
+func (s *ServerOwnerService) ListAlerts(ctx context.Context, orgID, serverID string, limit int) ([]*types.ServerOwnerAlert, error) {
+	if limit <= 0 {
+		limit = defaultOwnerAlertPageSize
+	}
+	if limit > maxOwnerAlertPageSize {
+		limit = maxOwnerAlertPageSize
+	}
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	return s.store.ListAlerts(serverID, limit)
+}
+
+// Acknowledge records that userID has seen an alert, which stops it from
+// escalating
+func (s *ServerOwnerService) Acknowledge(ctx context.Context, orgID, serverID, alertID, userID string) error {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return err
+	}
+	found, err := s.store.Acknowledge(serverID, alertID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+	if !found {
+		return types.NewNotFoundError("Alert not found")
+	}
+	return nil
+}
+
+// ServerTransition alerts the server's owner, if it has one, that the server
+// changed state. Recovery closes the server's open alerts.
+func (s *ServerOwnerService) ServerTransition(ctx context.Context, transition *types.ServerTransition) error {
+	owner, err := s.store.GetOwner(transition.ServerID)
+	if err != nil {
+		return fmt.Errorf("failed to get server owner: %w", err)
+	}
+	if owner == nil {
+		return nil
+	}
+
+	alert := &types.ServerOwnerAlert{
+		ServerID:       transition.ServerID,
+		OrganizationID: transition.OrganizationID,
+		ServerName:     transition.ServerName,
+		PreviousStatus: transition.PreviousStatus,
+		Status:         transition.Status,
+		HealthStatus:   transition.HealthStatus,
+	}
+	// Only failures wait for acknowledgement
+	if transition.Status == "active" {
+		if _, err := s.store.ResolveOpen(transition.ServerID); err != nil {
+			return fmt.Errorf("failed to resolve open alerts: %w", err)
+		}
+		now := s.now()
+		alert.ResolvedAt = &now
+	}
+	if err := s.store.CreateAlert(alert); err != nil {
+		return fmt.Errorf("failed to create owner alert: %w", err)
+	}
+
+	if s.active != nil && !s.active(ctx) {
+		return nil
+	}
+	s.deliver(ctx, owner, alert, types.ServerOwnerEventStatusChanged)
+	return nil
+}
+
+// Escalate notifies organization admins of every alert left unacknowledged
+// past its owner's escalation period, reminds the owner, and returns how many
+// alerts were escalated. Each alert is escalated once.
+func (s *ServerOwnerService) Escalate(ctx context.Context) (int, error) {
+	due, err := s.store.ListEscalationDue(int(s.escalateAfter / time.Minute))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list alerts due for escalation: %w", err)
+	}
+
+	escalated := 0
+	for _, alert := range due {
+		if ctx.Err() != nil {
+			return escalated, ctx.Err()
+		}
+
+		if s.notifier != nil {
+			_, err := s.notifier.Notify(ctx, &types.NotificationEvent{
+				OrganizationID: alert.OrganizationID,
+				Type:           types.NotificationOwnerAlertEscalated,
+				Severity:       types.NotificationSeverityCritical,
+				Title:          fmt.Sprintf("Server %s is %s and its owner has not responded", alert.ServerName, alert.Status),
+				Message: fmt.Sprintf("MCP server %s changed from %s to %s at %s and the alert to its owner was not acknowledged.",
+					alert.ServerName, alert.PreviousStatus, alert.Status, alert.CreatedAt.UTC().Format(time.RFC1123)),
+				ResourceType: "server",
+				ResourceID:   alert.ServerID,
+				DedupKey:     types.NotificationOwnerAlertEscalated + ":" + alert.ID,
+				Data: map[string]interface{}{
+					"alert_id":      alert.ID,
+					"health_status": alert.HealthStatus,
+				},
+			})
+			if err != nil {
+				log.Printf("Failed to notify admins about unacknowledged alert %s: %v", alert.ID, err)
+				continue
+			}
+		}
+
+		owner, err := s.store.GetOwner(alert.ServerID)
+		if err != nil {
+			log.Printf("Failed to get owner of server %s: %v", alert.ServerID, err)
+		} else if owner != nil {
+			s.deliver(ctx, owner, alert, types.ServerOwnerEventEscalated)
+		}
+
+		if err := s.store.MarkEscalated(alert.ID); err != nil {
+			log.Printf("Failed to record escalation of alert %s: %v", alert.ID, err)
+			continue
+		}
+		escalated++
+	}
+
+	return escalated, nil
+}
+
+// deliver sends an alert to the owner's webhook and contact email and
+// records the outcome
+func (s *ServerOwnerService) deliver(ctx context.Context, owner *types.ServerOwner, alert *types.ServerOwnerAlert, event string) {
+	payload := &types.ServerOwnerWebhookPayload{
+		OccurredAt:     alert.CreatedAt,
+		Event:          event,
+		AlertID:        alert.ID,
+		ServerID:       alert.ServerID,
+		ServerName:     alert.ServerName,
+		OrganizationID: alert.OrganizationID,
+		PreviousStatus: alert.PreviousStatus,
+		Status:         alert.Status,
+		HealthStatus:   alert.HealthStatus,
+	}
+	if alert.ResolvedAt == nil && s.baseURL != "" {
+		payload.AcknowledgeURL = fmt.Sprintf("%s/api/gateway/servers/%s/owner/alerts/%s/acknowledge", s.baseURL, alert.ServerID, alert.ID)
+	}
+
+	var failures []string
+	delivered := false
+	if owner.WebhookURL != "" {
+		if err := s.postWebhook(ctx, owner, payload); err != nil {
+			failures = append(failures, "webhook: "+err.Error())
+		} else {
+			delivered = true
+		}
+	}
+	if owner.ContactEmail != "" && s.mailer != nil {
+		if err := s.mailer.Send(ctx, BuildServerOwnerEmail(owner, payload)); err != nil {
+			failures = append(failures, "email: "+err.Error())
+		} else {
+			delivered = true
+		}
+	}
+
+	var deliveredAt *time.Time
+	if delivered {
+		now := s.now()
+		deliveredAt = &now
+	}
+	if len(failures) > 0 {
+		log.Printf("Failed to deliver alert %s to owner of server %s: %s", alert.ID, alert.ServerID, strings.Join(failures, "; "))
+	}
+	if err := s.store.MarkDelivered(alert.ID, deliveredAt, strings.Join(failures, "; ")); err != nil {
+		log.Printf("Failed to record delivery of alert %s: %v", alert.ID, err)
+	}
+}
+
+// postWebhook posts payload to the owner's webhook, signing it when the
+// owner set a secret or the server has a signing key
+func (s *ServerOwnerService) postWebhook(ctx context.Context, owner *types.ServerOwner, payload *types.ServerOwnerWebhookPayload) error {
+	if err := s.checkWebhookURL(owner.WebhookURL); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, owner.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Omnimesh-Gateway")
+	req.Header.Set("X-Omnimesh-Event", payload.Event)
+	if owner.WebhookSecret != "" {
+		req.Header.Set(ownerSignatureHeader, "sha256="+SignServerOwnerWebhook(owner.WebhookSecret, body))
+	}
+	if s.signer != nil {
+		key, err := s.signer.SigningKey(ctx, types.SigningDestinationOwnerWebhook, owner.ServerID)
+		if err != nil {
+			return err
+		}
+		if key != nil {
+			if err := key.Sign(req, body, s.now()); err != nil {
+				return err
+			}
+		}
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *ServerOwnerService) checkWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return types.NewValidationError("webhook_url must be an http or https URL")
+	}
+	if s.egress != nil {
+		return s.egress.CheckURL(types.ConnectivityFeatureOwnerWebhooks, rawURL)
+	}
+	return nil
+}
+
+// checkServer returns a not found error unless the server is active and
+// belongs to the organization
+func (s *ServerOwnerService) checkServer(orgID, serverID string) error {
+	serverOrg, err := s.store.GetServerOrganization(serverID)
+	if err != nil {
+		return fmt.Errorf("failed to get server: %w", err)
+	}
+	if serverOrg == "" || serverOrg != orgID {
+		return types.NewNotFoundError("Server not found")
+	}
+	return nil
+}
+
+// SignServerOwnerWebhook returns the hex HMAC-SHA256 of body keyed with
+// secret, as sent in the X-Omnimesh-Signature header
+func SignServerOwnerWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// BuildServerOwnerEmail renders an alert email to a server's owner
+func BuildServerOwnerEmail(owner *types.ServerOwner, payload *types.ServerOwnerWebhookPayload) *mailer.Message {
+	var body strings.Builder
+	name := owner.ContactName
+	if name == "" {
+		name = owner.ContactEmail
+	}
+	subject := fmt.Sprintf("Omnimesh Gateway: server %s is %s", payload.ServerName, payload.Status)
+	if payload.Event == types.ServerOwnerEventEscalated {
+		subject = fmt.Sprintf("Omnimesh Gateway: unacknowledged alert for server %s escalated", payload.ServerName)
+	}
+
+	fmt.Fprintf(&body, "Hello %s,\n\n", name)
+	fmt.Fprintf(&body, "MCP server %s, which you own, changed from %s to %s at %s.\n",
+		payload.ServerName, payload.PreviousStatus, payload.Status, payload.OccurredAt.UTC().Format(time.RFC1123))
+	if payload.HealthStatus != "" {
+		fmt.Fprintf(&body, "The last health check returned %s.\n", payload.HealthStatus)
+	}
+	if payload.Event == types.ServerOwnerEventEscalated {
+		body.WriteString("\nThe alert was not acknowledged in time and your organization's admins have been notified.\n")
+	}
+	if payload.AcknowledgeURL != "" {
+		fmt.Fprintf(&body, "\nAcknowledge the alert with an authenticated POST to:\n  %s\n", payload.AcknowledgeURL)
+	}
+
+	return &mailer.Message{
+		To:      owner.ContactEmail,
+		Subject: subject,
+		Body:    body.String(),
+	}
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mcp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// ServerRecordingStore persists recorded upstream responses and the
+// recording modes of servers
+type ServerRecordingStore interface {
+	ServerExists(orgID, serverID string) (bool, error)
+	GetMode(serverID string) (string, error)
+	SetMode(serverID, mode string) error
+	Count(serverID string) (int, error)
+	List(serverID, method string) ([]*types.ServerRecording, error)
+	Save(recording *types.ServerRecording) error
+	Replay(serverID, fingerprint string) (*types.ServerRecording, error)
+	Delete(serverID, id string) (bool, error)
+	Clear(serverID string) (int, error)
+}
+
+// ServerSessionResetter closes the open connections to a server so that
+// the next call reconnects
+type ServerSessionResetter interface {
+	ResetServerSessions(serverID string)
+}
+
+// ServerRecordingService records the responses of upstream servers and
+// replays them later in place of the server, VCR style, so that namespaces
+// can be tested deterministically and demonstrated offline
+type ServerRecordingService struct {
+	store    ServerRecordingStore
+	sessions ServerSessionResetter
+}
+
+// NewServerRecordingService creates a database-backed server recording
+// service
+func NewServerRecordingService(db *sql.DB) *ServerRecordingService {
+	return NewServerRecordingServiceWithStore(models.NewServerRecordingModel(db))
+}
+
+// NewServerRecordingServiceWithStore creates a server recording service
+// over store
+func NewServerRecordingServiceWithStore(store ServerRecordingStore) *ServerRecordingService {
+	return &ServerRecordingService{store: store}
+}
+
+// SetSessionResetter makes mode changes reconnect the open sessions of the
+// server, which pick their transport when they connect
+func (s *ServerRecordingService) SetSessionResetter(sessions ServerSessionResetter) {
+	s.sessions = sessions
+}
+
+// GetStatus returns the recording mode of a server
+func (s *ServerRecordingService) GetStatus(ctx context.Context, orgID, serverID string) (*types.ServerRecordingStatus, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	mode, err := s.store.GetMode(serverID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get recording mode: " + err.Error())
+	}
+	count, err := s.store.Count(serverID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to count recordings: " + err.Error())
+	}
+	return &types.ServerRecordingStatus{ServerID: serverID, Mode: mode, Recordings: count}, nil
+}
+
+// SetMode starts or stops recording a server, or replaying its recordings
+func (s *ServerRecordingService) SetMode(ctx context.Context, orgID, serverID string, req *types.SetRecordingModeRequest) (*types.ServerRecordingStatus, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	switch req.Mode {
+	case types.RecordingModeOff, types.RecordingModeRecord, types.RecordingModeReplay:
+	default:
+		return nil, types.NewValidationError("mode must be off, record or replay")
+	}
+
+	if err := s.store.SetMode(serverID, req.Mode); err != nil {
+		return nil, types.NewInternalError("Failed to set recording mode: " + err.Error())
+	}
+	if s.sessions != nil {
+		s.sessions.ResetServerSessions(serverID)
+	}
+	return s.GetStatus(ctx, orgID, serverID)
+}
+
+// ListRecordings returns the recordings of a server, optionally of one
+// method only
+func (s *ServerRecordingService) ListRecordings(ctx context.Context, orgID, serverID, method string) ([]*types.ServerRecording, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+	recordings, err := s.store.List(serverID, method)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list recordings: " + err.Error())
+	}
+	return recordings, nil
+}
+
+// ImportRecordings adds recorded responses to a server, replacing earlier
+// recordings of the same requests
+func (s *ServerRecordingService) ImportRecordings(ctx context.Context, orgID, serverID string, req *types.ImportRecordingsRequest) ([]*types.ServerRecording, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return nil, err
+	}
+
+	recordings := make([]*types.ServerRecording, 0, len(req.Recordings))
+	for _, imported := range req.Recordings {
+		if (imported.Error == nil) == (len(imported.Result) == 0) {
+			return nil, types.NewValidationError("recording " + imported.Method + " needs either a result or an error")
+		}
+		fingerprint, err := mcp.RecordingFingerprint(imported.Method, imported.Params)
+		if err != nil {
+			return nil, types.NewValidationError("recording " + imported.Method + ": " + err.Error())
+		}
+		recordings = append(recordings, &types.ServerRecording{
+			ServerID:    serverID,
+			Fingerprint: fingerprint,
+			Method:      imported.Method,
+			Params:      imported.Params,
+			Result:      imported.Result,
+			Error:       imported.Error,
+		})
+	}
+
+	for _, recording := range recordings {
+		if err := s.store.Save(recording); err != nil {
+			return nil, types.NewInternalError("Failed to import recording: " + err.Error())
+		}
+	}
+	return recordings, nil
+}
+
+// DeleteRecording removes a recording of a server
+func (s *ServerRecordingService) DeleteRecording(ctx context.Context, orgID, serverID, id string) error {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return err
+	}
+	deleted, err := s.store.Delete(serverID, id)
+	if err != nil {
+		return types.NewInternalError("Failed to delete recording: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Recording not found")
+	}
+	return nil
+}
+
+// ClearRecordings removes every recording of a server and returns how many
+// there were
+func (s *ServerRecordingService) ClearRecordings(ctx context.Context, orgID, serverID string) (int, error) {
+	if err := s.checkServer(orgID, serverID); err != nil {
+		return 0, err
+	}
+	cleared, err := s.store.Clear(serverID)
+	if err != nil {
+		return 0, types.NewInternalError("Failed to clear recordings: " + err.Error())
+	}
+	return cleared, nil
+}
+
+// RecordingMode returns the recording mode of a server
+func (s *ServerRecordingService) RecordingMode(ctx context.Context, serverID string) (string, error) {
+	return s.store.GetMode(serverID)
+}
+
+// SaveRecording implements mcp.RecordingStore
+func (s *ServerRecordingService) SaveRecording(ctx context.Context, recording *types.ServerRecording) error {
+	return s.store.Save(recording)
+}
+
+// FindRecording implements mcp.RecordingStore and counts the replay
+func (s *ServerRecordingService) FindRecording(ctx context.Context, serverID, fingerprint string) (*types.ServerRecording, error) {
+	return s.store.Replay(serverID, fingerprint)
+}
+
+func (s *ServerRecordingService) checkServer(orgID, serverID string) error {
+	exists, err := s.store.ServerExists(orgID, serverID)
+	if err != nil {
+		return types.NewInternalError("Failed to get server: " + err.Error())
+	}
+	if !exists {
+		return types.NewNotFoundError("Server not found")
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// ServiceAccountStore persists service accounts and their OAuth clients
+type ServiceAccountStore interface {
+	List(orgID string) ([]*types.ServiceAccount, error)
+	Get(orgID, id string) (*types.ServiceAccount, error)
+	Create(account *types.ServiceAccount) error
+	Update(account *types.ServiceAccount) error
+	Retire(orgID, id string) (bool, error)
+	ListClients(serviceAccountID string) ([]*types.OAuthClient, error)
+	DeleteClient(serviceAccountID, clientID string) (bool, error)
+}
+
+// ServiceAccountKeyIssuer manages the API keys of a user, which for a
+// service account are the keys acting as it
+type ServiceAccountKeyIssuer interface {
+	CreateAPIKey(userID string, req *types.CreateAPIKeyRequest) (*types.CreateAPIKeyResponse, error)
+	ListAPIKeys(userID string) ([]*types.APIKey, error)
+	DeleteAPIKey(userID, keyID string) error
+}
+
+// ServiceAccountClientIssuer registers OAuth clients acting as a service
+// account
+type ServiceAccountClientIssuer interface {
+	CreateServiceAccountClient(ctx context.Context, orgID, serviceAccountID, name, scope string) (*types.ClientRegistrationResponse, error)
+}
+
+// ServiceAccountService manages service accounts: non-human principals of an
+// organization bound to a role that authenticate with their own API keys or
+// OAuth clients. Requests they make are attributed to the account in audit
+// logs and usage, they cannot sign in interactively and they take no seat.
+type ServiceAccountService struct {
+	store   ServiceAccountStore
+	keys    ServiceAccountKeyIssuer
+	clients ServiceAccountClientIssuer
+}
+
+// NewServiceAccountService creates a database-backed service account service
+func NewServiceAccountService(db *sql.DB, keys ServiceAccountKeyIssuer, clients ServiceAccountClientIssuer) *ServiceAccountService {
+	return NewServiceAccountServiceWithStore(models.NewServiceAccountModel(db), keys, clients)
+}
+
+// NewServiceAccountServiceWithStore creates a service account service over
+// store
+func NewServiceAccountServiceWithStore(store ServiceAccountStore, keys ServiceAccountKeyIssuer, clients ServiceAccountClientIssuer) *ServiceAccountService {
+	return &ServiceAccountService{store: store, keys: keys, clients: clients}
+}
+
+// List returns the organization's service accounts
+func (s *ServiceAccountService) List(ctx context.Context, orgID string) ([]*types.ServiceAccount, error) {
+	accounts, err := s.store.List(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// Get returns a service account of the organization
+func (s *ServiceAccountService) Get(ctx context.Context, orgID, id string) (*types.ServiceAccount, error) {
+	account, err := s.store.Get(orgID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+	if account == nil {
+		return nil, types.NewNotFoundError("Service account not found")
+	}
+	return account, nil
+}
+
+// Create adds a service account to the organization. Names are unique
+// within an organization.
+func (s *ServiceAccountService) Create(ctx context.Context, orgID, createdBy string, req *types.CreateServiceAccountRequest) (*types.ServiceAccount, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, types.NewValidationError("name is required")
+	}
+
+	existing, err := s.store.List(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	for _, account := range existing {
+		if strings.EqualFold(account.Name, name) {
+			return nil, types.NewConflictError("a service account with this name already exists")
+		}
+	}
+
+	account := &types.ServiceAccount{
+		OrganizationID: orgID,
+		Name:           name,
+		Description:    strings.TrimSpace(req.Description),
+		Role:           req.Role,
+		CreatedBy:      createdBy,
+	}
+	if err := s.store.Create(account); err != nil {
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+	return account, nil
+}
+
+// Update changes a service account's description, role or whether it is
+// enabled. Role changes apply to its existing keys and clients at once.
+func (s *ServiceAccountService) Update(ctx context.Context, orgID, id string, req *types.UpdateServiceAccountRequest) (*types.ServiceAccount, error) {
+	account, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Description != nil {
+		account.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Role != nil {
+		account.Role = *req.Role
+	}
+	if req.IsActive != nil {
+		account.IsActive = *req.IsActive
+	}
+	if err := s.store.Update(account); err != nil {
+		return nil, fmt.Errorf("failed to update service account: %w", err)
+	}
+	return account, nil
+}
+
+// Delete retires a service account: its keys stop working, its OAuth clients
+// are removed and it disappears from listings, while audit logs keep naming it
+func (s *ServiceAccountService) Delete(ctx context.Context, orgID, id string) error {
+	retired, err := s.store.Retire(orgID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete service account: %w", err)
+	}
+	if !retired {
+		return types.NewNotFoundError("Service account not found")
+	}
+	return nil
+}
+
+// ListAPIKeys returns the API keys of a service account
+func (s *ServiceAccountService) ListAPIKeys(ctx context.Context, orgID, id string) ([]*types.APIKey, error) {
+	if _, err := s.Get(ctx, orgID, id); err != nil {
+		return nil, err
+	}
+	keys, err := s.keys.ListAPIKeys(id)
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		keys = []*types.APIKey{}
+	}
+	return keys, nil
+}
+
+// CreateAPIKey issues an API key acting as the service account. The key
+// carries the account's role; the secret is returned only once.
+func (s *ServiceAccountService) CreateAPIKey(ctx context.Context, orgID, id string, req *types.CreateServiceAccountKeyRequest) (*types.CreateAPIKeyResponse, error) {
+	account, err := s.usableAccount(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.keys.CreateAPIKey(account.ID, &types.CreateAPIKeyRequest{
+		Name:           req.Name,
+		Role:           account.Role,
+		ExpiresAt:      req.ExpiresAt,
+		Labels:         req.Labels,
+		AllowedCIDRs:   req.AllowedCIDRs,
+		AllowedOrigins: req.AllowedOrigins,
+	})
+}
+
+// RevokeAPIKey deletes an API key of a service account
+func (s *ServiceAccountService) RevokeAPIKey(ctx context.Context, orgID, id, keyID string) error {
+	if _, err := s.Get(ctx, orgID, id); err != nil {
+		return err
+	}
+	return s.keys.DeleteAPIKey(id, keyID)
+}
+
+// ListClients returns the OAuth clients of a service account
+func (s *ServiceAccountService) ListClients(ctx context.Context, orgID, id string) ([]*types.OAuthClient, error) {
+	if _, err := s.Get(ctx, orgID, id); err != nil {
+		return nil, err
+	}
+	clients, err := s.store.ListClients(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OAuth clients: %w", err)
+	}
+	return clients, nil
+}
+
+// CreateClient registers an OAuth client whose client_credentials tokens
+// act as the service account. The secret is returned only once.
+func (s *ServiceAccountService) CreateClient(ctx context.Context, orgID, id string, req *types.CreateServiceAccountClientRequest) (*types.ClientRegistrationResponse, error) {
+	account, err := s.usableAccount(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.clients == nil {
+		return nil, types.NewNotFoundError("OAuth is not enabled")
+	}
+	return s.clients.CreateServiceAccountClient(ctx, orgID, account.ID, strings.TrimSpace(req.Name), strings.TrimSpace(req.Scope))
+}
+
+// DeleteClient deletes an OAuth client of a service account and revokes the
+// tokens issued to it
+func (s *ServiceAccountService) DeleteClient(ctx context.Context, orgID, id, clientID string) error {
+	if _, err := s.Get(ctx, orgID, id); err != nil {
+		return err
+	}
+	deleted, err := s.store.DeleteClient(id, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to delete OAuth client: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("OAuth client not found")
+	}
+	return nil
+}
+
+// usableAccount returns an enabled service account of the organization
+func (s *ServiceAccountService) usableAccount(ctx context.Context, orgID, id string) (*types.ServiceAccount, error) {
+	account, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsActive {
+		return nil, types.NewValidationError("service account is disabled")
+	}
+	return account, nil
+}
@@ -0,0 +1,634 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// defaultSSOStateTTL is how long a user has to sign in at the identity
+// provider before the sign-in must be started again
+const defaultSSOStateTTL = 10 * time.Minute
+
+// SSOStore persists identity providers, identities and sign-ins in progress
+type SSOStore interface {
+	ListProviders(orgID string) ([]*types.SSOProvider, error)
+	ListActiveProvidersByOrgSlug(slug string) ([]*types.SSOProvider, error)
+	GetProvider(id string) (*types.SSOProvider, error)
+	CreateProvider(provider *types.SSOProvider) error
+	UpdateProvider(provider *types.SSOProvider) error
+	DeleteProvider(orgID, id string) (bool, error)
+	CreateLoginState(state *types.SSOLoginState) error
+	ConsumeLoginState(stateHash string) (*types.SSOLoginState, error)
+	GetIdentity(providerID, subject string) (*types.SSOIdentity, error)
+	CreateIdentity(identity *types.SSOIdentity) error
+	TouchIdentity(id, email string) error
+	GetUser(id string) (*types.User, error)
+	GetUserByEmail(email string) (*types.User, error)
+	CreateUser(user *types.User) error
+	UpdateUserRole(userID, role string) error
+}
+
+// SSOLoginIssuer issues the gateway's tokens to a user signed in by an
+// identity provider
+type SSOLoginIssuer interface {
+	IssueLogin(user *types.User, ctx *auth.LoginContext) (*types.LoginResponse, error)
+}
+
+// SSOAuditor records provisioning and linking of SSO users in the audit log
+type SSOAuditor interface {
+	LogAudit(audit *types.AuditLog) error
+}
+
+// SSOTeamSyncer keeps a user's memberships of IdP-mapped teams in line with
+// their groups
+type SSOTeamSyncer interface {
+	SyncGroups(ctx context.Context, orgID, userID string, groups []string) (*types.TeamSyncResult, error)
+}
+
+// SSOService signs users in through their organization's OpenID Connect
+// identity providers. A user is matched by their subject at the provider,
+// then by verified email within the organization; unknown users are
+// provisioned when the provider allows it. IdP groups map to roles and
+// team memberships.
+type SSOService struct {
+	store       SSOStore
+	issuer      SSOLoginIssuer
+	oidc        *auth.OIDCClient
+	seats       auth.SeatLimiter
+	auditor     SSOAuditor
+	teams       SSOTeamSyncer
+	now         func() time.Time
+	callbackURL string
+	stateTTL    time.Duration
+}
+
+// NewSSOService creates a database-backed SSO service. callbackURL is where
+// identity providers redirect users back to the gateway.
+func NewSSOService(db *sql.DB, issuer SSOLoginIssuer, callbackURL string, stateTTL time.Duration) *SSOService {
+	return NewSSOServiceWithStore(models.NewSSOModel(db), issuer, callbackURL, stateTTL)
+}
+
+// NewSSOServiceWithStore creates an SSO service over store
+func NewSSOServiceWithStore(store SSOStore, issuer SSOLoginIssuer, callbackURL string, stateTTL time.Duration) *SSOService {
+	if stateTTL <= 0 {
+		stateTTL = defaultSSOStateTTL
+	}
+	return &SSOService{
+		store:       store,
+		issuer:      issuer,
+		oidc:        auth.NewOIDCClient(nil),
+		now:         time.Now,
+		callbackURL: callbackURL,
+		stateTTL:    stateTTL,
+	}
+}
+
+// SetOIDCClient replaces the client identity providers are reached with
+func (s *SSOService) SetOIDCClient(client *auth.OIDCClient) {
+	s.oidc = client
+}
+
+// SetSeatLimiter enforces licensed seats when users are provisioned
+func (s *SSOService) SetSeatLimiter(seats auth.SeatLimiter) {
+	s.seats = seats
+}
+
+// SetAuditor configures where provisioning and linking are audited
+func (s *SSOService) SetAuditor(auditor SSOAuditor) {
+	s.auditor = auditor
+}
+
+// SetTeamSync makes every sign-in sync the user's IdP-mapped teams with
+// their groups
+func (s *SSOService) SetTeamSync(teams SSOTeamSyncer) {
+	s.teams = teams
+}
+
+// ListProviders returns the organization's identity providers
+func (s *SSOService) ListProviders(ctx context.Context, orgID string) ([]*types.SSOProvider, error) {
+	providers, err := s.store.ListProviders(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SSO providers: %w", err)
+	}
+	return providers, nil
+}
+
+// GetProvider returns an identity provider of the organization
+func (s *SSOService) GetProvider(ctx context.Context, orgID, id string) (*types.SSOProvider, error) {
+	provider, err := s.provider(id)
+	if err != nil {
+		return nil, err
+	}
+	if provider.OrganizationID != orgID {
+		return nil, types.NewNotFoundError("SSO provider not found")
+	}
+	return provider, nil
+}
+
+// CreateProvider adds an identity provider to the organization once its
+// issuer's discovery document has been fetched
+func (s *SSOService) CreateProvider(ctx context.Context, orgID, createdBy string, req *types.CreateSSOProviderRequest) (*types.SSOProvider, error) {
+	provider := &types.SSOProvider{
+		OrganizationID: orgID,
+		DisplayName:    strings.TrimSpace(req.DisplayName),
+		IssuerURL:      strings.TrimSpace(req.IssuerURL),
+		ClientID:       strings.TrimSpace(req.ClientID),
+		ClientSecret:   req.ClientSecret,
+		Scopes:         req.Scopes,
+		GroupsClaim:    req.GroupsClaim,
+		GroupRoles:     req.GroupRoles,
+		DefaultRole:    req.DefaultRole,
+		AllowedDomains: req.AllowedDomains,
+		AutoProvision:  req.AutoProvision != nil && *req.AutoProvision,
+		IsActive:       true,
+		CreatedBy:      createdBy,
+	}
+	if err := s.validateProvider(ctx, provider, true); err != nil {
+		return nil, err
+	}
+	if err := s.store.CreateProvider(provider); err != nil {
+		return nil, fmt.Errorf("failed to create SSO provider: %w", err)
+	}
+	return provider, nil
+}
+
+// UpdateProvider changes the settings of an identity provider of the
+// organization
+func (s *SSOService) UpdateProvider(ctx context.Context, orgID, id string, req *types.UpdateSSOProviderRequest) (*types.SSOProvider, error) {
+	provider, err := s.GetProvider(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := provider.IssuerURL
+	if req.DisplayName != nil {
+		provider.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.IssuerURL != nil {
+		provider.IssuerURL = strings.TrimSpace(*req.IssuerURL)
+	}
+	if req.ClientID != nil {
+		provider.ClientID = strings.TrimSpace(*req.ClientID)
+	}
+	if req.ClientSecret != nil && *req.ClientSecret != "" {
+		provider.ClientSecret = *req.ClientSecret
+	}
+	if req.GroupsClaim != nil {
+		provider.GroupsClaim = *req.GroupsClaim
+	}
+	if req.DefaultRole != nil {
+		provider.DefaultRole = *req.DefaultRole
+	}
+	if req.AutoProvision != nil {
+		provider.AutoProvision = *req.AutoProvision
+	}
+	if req.IsActive != nil {
+		provider.IsActive = *req.IsActive
+	}
+	if req.GroupRoles != nil {
+		provider.GroupRoles = req.GroupRoles
+	}
+	if req.Scopes != nil {
+		provider.Scopes = req.Scopes
+	}
+	if req.AllowedDomains != nil {
+		provider.AllowedDomains = req.AllowedDomains
+	}
+
+	if err := s.validateProvider(ctx, provider, provider.IssuerURL != issuer); err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdateProvider(provider); err != nil {
+		return nil, fmt.Errorf("failed to update SSO provider: %w", err)
+	}
+	return provider, nil
+}
+
+// DeleteProvider removes an identity provider of the organization. Users it
+// provisioned are kept but can no longer sign in through it.
+func (s *SSOService) DeleteProvider(ctx context.Context, orgID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return types.NewNotFoundError("SSO provider not found")
+	}
+	deleted, err := s.store.DeleteProvider(orgID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete SSO provider: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("SSO provider not found")
+	}
+	return nil
+}
+
+// ListLoginProviders returns the active identity providers the login page
+// offers members of an organization
+func (s *SSOService) ListLoginProviders(ctx context.Context, orgSlug string) ([]*types.SSOProviderSummary, error) {
+	providers, err := s.store.ListActiveProvidersByOrgSlug(orgSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SSO providers: %w", err)
+	}
+	summaries := make([]*types.SSOProviderSummary, 0, len(providers))
+	for _, provider := range providers {
+		summaries = append(summaries, &types.SSOProviderSummary{
+			ID:          provider.ID,
+			DisplayName: provider.DisplayName,
+			LoginURL:    "/api/auth/sso/" + provider.ID + "/login",
+		})
+	}
+	return summaries, nil
+}
+
+// BeginLogin starts a sign-in at an identity provider and returns the URL
+// to send the user to. redirectPath is where the user lands once signed
+// in; anything but a local path is replaced by the root.
+func (s *SSOService) BeginLogin(ctx context.Context, providerID, redirectPath string) (string, error) {
+	provider, err := s.provider(providerID)
+	if err != nil {
+		return "", err
+	}
+	if !provider.IsActive {
+		return "", types.NewNotFoundError("SSO provider not found")
+	}
+
+	secrets := make([]string, 3)
+	for i := range secrets {
+		if secrets[i], err = generateSSOSecret(); err != nil {
+			return "", err
+		}
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	if err := s.store.CreateLoginState(&types.SSOLoginState{
+		StateHash:    hashSSOState(state),
+		ProviderID:   provider.ID,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		RedirectPath: safeRedirectPath(redirectPath),
+		ExpiresAt:    s.now().Add(s.stateTTL),
+	}); err != nil {
+		return "", fmt.Errorf("failed to start SSO login: %w", err)
+	}
+
+	authURL, err := s.oidc.AuthCodeURL(ctx, provider.IssuerURL, &auth.OIDCAuthRequest{
+		ClientID:     provider.ClientID,
+		RedirectURI:  s.callbackURL,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		Scopes:       provider.Scopes,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to start SSO login: %w", err)
+	}
+	return authURL, nil
+}
+
+// CompleteLogin finishes a sign-in when the identity provider redirects
+// back with state and code, returning the gateway's tokens for the user and
+// the path they asked to land on
+func (s *SSOService) CompleteLogin(ctx context.Context, state, code string, login *auth.LoginContext) (*types.LoginResponse, string, error) {
+	if state == "" || code == "" {
+		return nil, "", types.NewValidationError("state and code are required")
+	}
+
+	pending, err := s.store.ConsumeLoginState(hashSSOState(state))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get SSO login: %w", err)
+	}
+	if pending == nil || !s.now().Before(pending.ExpiresAt) {
+		return nil, "", types.NewUnauthorizedError("Sign-in expired or was already completed, please start again")
+	}
+	provider, err := s.store.GetProvider(pending.ProviderID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get SSO provider: %w", err)
+	}
+	if provider == nil || !provider.IsActive {
+		return nil, "", types.NewForbiddenError("This identity provider is no longer enabled")
+	}
+
+	tokens, err := s.oidc.Exchange(ctx, provider.IssuerURL, provider.ClientID, provider.ClientSecret, code, s.callbackURL, pending.CodeVerifier)
+	if err != nil {
+		log.Printf("SSO login through provider %s failed: %v", provider.ID, err)
+		return nil, "", types.NewUnauthorizedError("The identity provider did not accept the sign-in")
+	}
+	claims, err := s.oidc.VerifyIDToken(ctx, provider.IssuerURL, provider.ClientID, tokens.IDToken, pending.Nonce)
+	if err != nil {
+		log.Printf("SSO login through provider %s failed: %v", provider.ID, err)
+		return nil, "", types.NewUnauthorizedError("The identity provider returned an invalid ID token")
+	}
+
+	user, err := s.resolveUser(ctx, provider, claims, login)
+	if err != nil {
+		return nil, "", err
+	}
+	if !user.IsActive {
+		return nil, "", types.NewForbiddenError("account is inactive")
+	}
+
+	response, err := s.issuer.IssueLogin(user, login)
+	if err != nil {
+		return nil, "", err
+	}
+	return response, pending.RedirectPath, nil
+}
+
+// resolveUser finds the user an ID token signs in, linking or provisioning
+// them on their first sign-in, and syncs their role and teams with their
+// groups
+func (s *SSOService) resolveUser(ctx context.Context, provider *types.SSOProvider, claims *auth.OIDCClaims, login *auth.LoginContext) (*types.User, error) {
+	email := strings.ToLower(claims.Email())
+	groups := claims.Strings(provider.GroupsClaim)
+	role, mapped := ssoRole(provider, groups)
+
+	identity, err := s.store.GetIdentity(provider.ID, claims.Subject())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SSO identity: %w", err)
+	}
+
+	var user *types.User
+	if identity != nil {
+		if user, err = s.store.GetUser(identity.UserID); err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil || user.OrganizationID != provider.OrganizationID {
+			return nil, types.NewForbiddenError("This account is not a member of the organization")
+		}
+		if email == "" {
+			email = identity.Email
+		}
+		if err := s.store.TouchIdentity(identity.ID, email); err != nil {
+			return nil, fmt.Errorf("failed to update SSO identity: %w", err)
+		}
+	} else {
+		// Only an email the provider vouches for may claim or create an
+		// account
+		if email == "" || !claims.EmailVerified() {
+			return nil, types.NewForbiddenError("The identity provider did not supply a verified email address")
+		}
+		if !ssoDomainAllowed(provider, email) {
+			return nil, types.NewForbiddenError("Your email domain may not sign in with this identity provider")
+		}
+
+		if user, err = s.store.GetUserByEmail(email); err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		action := "link"
+		if user != nil {
+			if user.OrganizationID != provider.OrganizationID || user.IsServiceAccount() {
+				return nil, types.NewForbiddenError("This email belongs to an account outside the organization")
+			}
+		} else {
+			if !provider.AutoProvision {
+				return nil, types.NewForbiddenError("No account exists for this email, ask an administrator to invite you")
+			}
+			if s.seats != nil {
+				if err := s.seats.CheckSeatAvailable(ctx); err != nil {
+					return nil, err
+				}
+			}
+			user = &types.User{
+				Email:          email,
+				Name:           claims.Name(),
+				OrganizationID: provider.OrganizationID,
+				Role:           role,
+			}
+			if user.Name == "" {
+				user.Name = email
+			}
+			if err := s.store.CreateUser(user); err != nil {
+				return nil, fmt.Errorf("failed to provision user: %w", err)
+			}
+			action = "provision"
+		}
+
+		identity = &types.SSOIdentity{UserID: user.ID, ProviderID: provider.ID, Subject: claims.Subject(), Email: email}
+		if err := s.store.CreateIdentity(identity); err != nil {
+			return nil, fmt.Errorf("failed to link SSO identity: %w", err)
+		}
+		s.audit(provider, user, action, login, map[string]interface{}{
+			"subject": identity.Subject,
+			"email":   email,
+			"role":    user.Role,
+		})
+	}
+
+	if mapped && user.Role != role && !user.IsServiceAccount() {
+		if err := s.store.UpdateUserRole(user.ID, role); err != nil {
+			return nil, fmt.Errorf("failed to update user role: %w", err)
+		}
+		s.audit(provider, user, "sync_role", login, map[string]interface{}{
+			"previous_role": user.Role,
+			"role":          role,
+		})
+		user.Role = role
+	}
+
+	// A failed sync could leave the user in teams they have left at the
+	// provider, so it fails the sign-in
+	if s.teams != nil && !user.IsServiceAccount() {
+		synced, err := s.teams.SyncGroups(ctx, provider.OrganizationID, user.ID, groups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sync teams: %w", err)
+		}
+		if len(synced.Joined) > 0 || len(synced.Left) > 0 {
+			s.audit(provider, user, "sync_teams", login, map[string]interface{}{
+				"joined": synced.Joined,
+				"left":   synced.Left,
+			})
+		}
+	}
+	return user, nil
+}
+
+// validateProvider normalizes a provider's settings and, when discover is
+// set, checks that its issuer can be reached
+func (s *SSOService) validateProvider(ctx context.Context, provider *types.SSOProvider, discover bool) error {
+	if provider.DisplayName == "" {
+		return types.NewValidationError("display_name is required")
+	}
+	if provider.ClientID == "" || provider.ClientSecret == "" {
+		return types.NewValidationError("client_id and client_secret are required")
+	}
+	if err := validateSSOIssuer(provider.IssuerURL); err != nil {
+		return err
+	}
+
+	if provider.DefaultRole == "" {
+		provider.DefaultRole = types.RoleUser
+	}
+	if !slices.Contains(types.SSORolePrecedence, provider.DefaultRole) {
+		return types.NewValidationError(fmt.Sprintf("invalid default_role %q", provider.DefaultRole))
+	}
+	for group, role := range provider.GroupRoles {
+		if !slices.Contains(types.SSORolePrecedence, role) {
+			return types.NewValidationError(fmt.Sprintf("invalid role %q for group %q", role, group))
+		}
+	}
+	if provider.GroupRoles == nil {
+		provider.GroupRoles = map[string]string{}
+	}
+	if provider.GroupsClaim == "" {
+		provider.GroupsClaim = types.DefaultSSOGroupsClaim
+	}
+	if len(provider.Scopes) == 0 {
+		provider.Scopes = append([]string{}, types.DefaultSSOScopes...)
+	}
+	if !slices.Contains(provider.Scopes, "openid") {
+		provider.Scopes = append([]string{"openid"}, provider.Scopes...)
+	}
+
+	domains := make([]string, 0, len(provider.AllowedDomains))
+	for _, domain := range provider.AllowedDomains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if domain == "" || strings.ContainsAny(domain, "@/ ") {
+			return types.NewValidationError(fmt.Sprintf("invalid allowed domain %q", domain))
+		}
+		domains = append(domains, domain)
+	}
+	provider.AllowedDomains = domains
+	// Without a domain allow list anyone the IdP verifies, such as any
+	// Google account, would get an account in the organization
+	if provider.AutoProvision && len(provider.AllowedDomains) == 0 {
+		return types.NewValidationError("auto_provision requires allowed_domains")
+	}
+
+	existing, err := s.store.ListProviders(provider.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to list SSO providers: %w", err)
+	}
+	for _, other := range existing {
+		if other.ID != provider.ID && other.IssuerURL == provider.IssuerURL && other.ClientID == provider.ClientID {
+			return types.NewConflictError("an SSO provider for this issuer and client already exists")
+		}
+	}
+
+	if discover {
+		if _, err := s.oidc.Discover(ctx, provider.IssuerURL); err != nil {
+			return types.NewValidationError(fmt.Sprintf("issuer_url could not be discovered: %v", err))
+		}
+	}
+	return nil
+}
+
+// provider returns an identity provider by id
+func (s *SSOService) provider(id string) (*types.SSOProvider, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, types.NewNotFoundError("SSO provider not found")
+	}
+	provider, err := s.store.GetProvider(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SSO provider: %w", err)
+	}
+	if provider == nil {
+		return nil, types.NewNotFoundError("SSO provider not found")
+	}
+	return provider, nil
+}
+
+func (s *SSOService) audit(provider *types.SSOProvider, user *types.User, action string, login *auth.LoginContext, details map[string]interface{}) {
+	if s.auditor == nil {
+		return
+	}
+	details["provider"] = provider.DisplayName
+	audit := &types.AuditLog{
+		OrganizationID: provider.OrganizationID,
+		UserID:         user.ID,
+		Action:         action,
+		Resource:       "sso",
+		ResourceID:     provider.ID,
+		Details:        details,
+		Success:        true,
+	}
+	if login != nil {
+		audit.RemoteIP = login.ClientIP.String()
+		audit.UserAgent = login.UserAgent
+	}
+	if err := s.auditor.LogAudit(audit); err != nil {
+		log.Printf("Failed to audit SSO %s of %s: %v", action, user.ID, err)
+	}
+}
+
+// ssoRole returns the most privileged role the groups map to, or the
+// provider's default role when none do. mapped is false when the provider
+// maps no groups, leaving roles to admins.
+func ssoRole(provider *types.SSOProvider, groups []string) (role string, mapped bool) {
+	if len(provider.GroupRoles) == 0 {
+		return provider.DefaultRole, false
+	}
+	best := -1
+	for _, group := range groups {
+		rank := slices.Index(types.SSORolePrecedence, provider.GroupRoles[group])
+		if rank >= 0 && (best < 0 || rank < best) {
+			best = rank
+		}
+	}
+	if best < 0 {
+		return provider.DefaultRole, true
+	}
+	return types.SSORolePrecedence[best], true
+}
+
+// ssoDomainAllowed reports whether the provider accepts an email's domain
+func ssoDomainAllowed(provider *types.SSOProvider, email string) bool {
+	if len(provider.AllowedDomains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	return at >= 0 && slices.Contains(provider.AllowedDomains, strings.ToLower(email[at+1:]))
+}
+
+// validateSSOIssuer requires an https issuer, or http on a loopback host
+// for local development
+func validateSSOIssuer(issuer string) error {
+	parsed, err := url.Parse(issuer)
+	if err != nil || parsed.Host == "" {
+		return types.NewValidationError("issuer_url must be an absolute URL")
+	}
+	if parsed.Scheme == "https" {
+		return nil
+	}
+	host := parsed.Hostname()
+	if ip := net.ParseIP(host); parsed.Scheme == "http" && (host == "localhost" || (ip != nil && ip.IsLoopback())) {
+		return nil
+	}
+	return types.NewValidationError("issuer_url must use https")
+}
+
+// safeRedirectPath keeps local paths and replaces anything that could
+// redirect off-site
+func safeRedirectPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/"
+	}
+	return path
+}
+
+func generateSSOSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate SSO state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashSSOState(state string) string {
+	hash := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(hash[:])
+}
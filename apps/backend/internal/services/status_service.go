@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// incidentLookback is how far back detected incidents are reported
+const incidentLookback = 7 * 24 * time.Hour
+
+// uptimeWindows are the trailing windows reported on status pages
+var uptimeWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// StatusEndpointResolver resolves public endpoints by name
+type StatusEndpointResolver interface {
+	ResolveEndpoint(ctx context.Context, name string) (*types.EndpointConfig, error)
+}
+
+// StatusIncidentSource lists admin-declared incidents shown on status pages
+type StatusIncidentSource interface {
+	ForNamespace(ctx context.Context, orgID, namespaceID string, serverIDs []string, since time.Time) ([]*types.Incident, error)
+}
+
+type cachedStatus struct {
+	expiresAt time.Time
+	status    *types.PublicStatus
+}
+
+// StatusService builds public status pages from the health checks of the
+// servers behind an endpoint. Results are cached so embedding pages cannot
+// turn into database load.
+type StatusService struct {
+	db        *sql.DB
+	endpoints StatusEndpointResolver
+	incidents StatusIncidentSource
+	cache     sync.Map // endpoint name -> cachedStatus
+	cacheTTL  time.Duration
+}
+
+// NewStatusService creates a new status service
+func NewStatusService(db *sql.DB, endpoints StatusEndpointResolver, cacheTTL time.Duration) *StatusService {
+	return &StatusService{
+		db:        db,
+		endpoints: endpoints,
+		cacheTTL:  cacheTTL,
+	}
+}
+
+// SetIncidents shows declared incidents alongside detected ones. Ongoing
+// declared incidents also worsen the reported status.
+func (s *StatusService) SetIncidents(incidents StatusIncidentSource) {
+	s.incidents = incidents
+}
+
+// GetStatus returns the public status of the named endpoint. Endpoints that
+// are inactive or have not enabled their status page are reported as not
+// found.
+func (s *StatusService) GetStatus(ctx context.Context, endpointName string) (*types.PublicStatus, error) {
+	now := time.Now().UTC()
+	if cached, ok := s.cache.Load(endpointName); ok && now.Before(cached.(cachedStatus).expiresAt) {
+		return cached.(cachedStatus).status, nil
+	}
+
+	config, err := s.endpoints.ResolveEndpoint(ctx, endpointName)
+	if err != nil || config.Endpoint == nil || !config.Endpoint.IsActive || !config.Endpoint.EnableStatusPage {
+		return nil, types.NewNotFoundError("status page not found")
+	}
+
+	var serverIDs []string
+	if config.Namespace != nil {
+		for _, server := range config.Namespace.Servers {
+			if server.Status == string(types.NamespaceStatusActive) {
+				serverIDs = append(serverIDs, server.ServerID)
+			}
+		}
+	}
+
+	status := &types.PublicStatus{
+		GeneratedAt: now,
+		Endpoint:    config.Endpoint.Name,
+		Status:      types.ServiceStatusOperational,
+		Incidents:   []types.StatusIncident{},
+	}
+
+	if len(serverIDs) > 0 {
+		latest, err := s.latestHealth(ctx, serverIDs)
+		if err != nil {
+			return nil, err
+		}
+		status.Status = OverallStatus(latest, len(serverIDs))
+
+		if status.Uptime, err = s.uptime(ctx, serverIDs, now); err != nil {
+			return nil, err
+		}
+
+		samples, err := s.healthTransitions(ctx, serverIDs, now.Add(-incidentLookback))
+		if err != nil {
+			return nil, err
+		}
+		status.Incidents = DetectIncidents(samples, len(serverIDs))
+	} else {
+		status.Uptime = emptyUptime()
+	}
+
+	if s.incidents != nil && config.Namespace != nil {
+		declared, err := s.incidents.ForNamespace(ctx, config.Endpoint.OrganizationID,
+			config.Namespace.ID, serverIDs, now.Add(-incidentLookback))
+		if err != nil {
+			return nil, err
+		}
+		for _, incident := range declared {
+			public := incident.StatusIncident()
+			if incident.Ongoing() {
+				status.Status = worseStatus(status.Status, public.Impact)
+			}
+			status.Incidents = append(status.Incidents, public)
+		}
+		sort.SliceStable(status.Incidents, func(i, j int) bool {
+			return status.Incidents[i].StartedAt.After(status.Incidents[j].StartedAt)
+		})
+	}
+
+	s.cache.Store(endpointName, cachedStatus{expiresAt: now.Add(s.cacheTTL), status: status})
+	return status, nil
+}
+
+// OverallStatus reduces the latest health of each server to a coarse status.
+// Servers without checks count as healthy.
+func OverallStatus(latest map[string]bool, servers int) string {
+	unhealthy := 0
+	for _, healthy := range latest {
+		if !healthy {
+			unhealthy++
+		}
+	}
+	switch {
+	case unhealthy == 0:
+		return types.ServiceStatusOperational
+	case unhealthy >= servers:
+		return types.ServiceStatusDown
+	}
+	return types.ServiceStatusDegraded
+}
+
+// worseStatus returns the more severe of two coarse statuses
+func worseStatus(a, b string) string {
+	rank := map[string]int{
+		types.ServiceStatusOperational: 0,
+		types.ServiceStatusDegraded:    1,
+		types.ServiceStatusDown:        2,
+	}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// DetectIncidents turns health samples, ordered by check time, into
+// incidents. An incident lasts while any server is unhealthy and is down if
+// all servers were unhealthy at once. Incidents are returned newest first.
+func DetectIncidents(samples []types.HealthSample, servers int) []types.StatusIncident {
+	incidents := []types.StatusIncident{}
+	unhealthy := map[string]bool{}
+	var current *types.StatusIncident
+
+	for _, sample := range samples {
+		if sample.Healthy {
+			delete(unhealthy, sample.ServerID)
+		} else {
+			unhealthy[sample.ServerID] = true
+		}
+
+		if len(unhealthy) > 0 && current == nil {
+			current = &types.StatusIncident{
+				StartedAt: sample.CheckedAt,
+				Impact:    types.ServiceStatusDegraded,
+				State:     types.IncidentStateOngoing,
+				Source:    types.IncidentSourceDetected,
+			}
+		}
+		if current == nil {
+			continue
+		}
+		if len(unhealthy) >= servers {
+			current.Impact = types.ServiceStatusDown
+		}
+		if len(unhealthy) == 0 {
+			endedAt := sample.CheckedAt
+			current.EndedAt = &endedAt
+			current.State = types.IncidentStateResolved
+			incidents = append(incidents, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		incidents = append(incidents, *current)
+	}
+
+	for i := range incidents {
+		incidents[i].Title = "Degraded performance"
+		if incidents[i].Impact == types.ServiceStatusDown {
+			incidents[i].Title = "Service outage"
+		}
+	}
+	sort.SliceStable(incidents, func(i, j int) bool {
+		return incidents[i].StartedAt.After(incidents[j].StartedAt)
+	})
+	return incidents
+}
+
+// latestHealth returns whether each server's most recent check was healthy
+func (s *StatusService) latestHealth(ctx context.Context, serverIDs []string) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT ON (server_id) server_id, status = 'healthy'
+		FROM health_checks
+		WHERE server_id = ANY($1::uuid[])
+		ORDER BY server_id, checked_at DESC`, pq.Array(serverIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest health checks: %w", err)
+	}
+	defer rows.Close()
+
+	latest := make(map[string]bool, len(serverIDs))
+	for rows.Next() {
+		var serverID string
+		var healthy bool
+		if err := rows.Scan(&serverID, &healthy); err != nil {
+			return nil, fmt.Errorf("failed to scan health check: %w", err)
+		}
+		latest[serverID] = healthy
+	}
+	return latest, rows.Err()
+}
+
+// uptime returns the share of healthy checks in each uptime window
+func (s *StatusService) uptime(ctx context.Context, serverIDs []string, now time.Time) ([]types.UptimeWindow, error) {
+	windows := emptyUptime()
+	for i, window := range uptimeWindows {
+		var healthy, total int64
+		err := s.db.QueryRowContext(ctx, `
+			SELECT COUNT(*) FILTER (WHERE status = 'healthy'), COUNT(*)
+			FROM health_checks
+			WHERE server_id = ANY($1::uuid[]) AND checked_at >= $2`,
+			pq.Array(serverIDs), now.Add(-window.duration)).Scan(&healthy, &total)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute %s uptime: %w", window.name, err)
+		}
+		if total > 0 {
+			percent := float64(healthy) * 100 / float64(total)
+			windows[i].Percent = &percent
+		}
+	}
+	return windows, nil
+}
+
+// healthTransitions returns the checks since the given time at which a
+// server's health changed, ordered by check time
+func (s *StatusService) healthTransitions(ctx context.Context, serverIDs []string, since time.Time) ([]types.HealthSample, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT server_id, healthy, checked_at FROM (
+			SELECT server_id, status = 'healthy' AS healthy, checked_at,
+				LAG(status = 'healthy') OVER (PARTITION BY server_id ORDER BY checked_at) AS previous
+			FROM health_checks
+			WHERE server_id = ANY($1::uuid[]) AND checked_at >= $2
+		) checks
+		WHERE previous IS DISTINCT FROM healthy
+		ORDER BY checked_at`, pq.Array(serverIDs), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get health history: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []types.HealthSample
+	for rows.Next() {
+		var sample types.HealthSample
+		if err := rows.Scan(&sample.ServerID, &sample.Healthy, &sample.CheckedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan health check: %w", err)
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+func emptyUptime() []types.UptimeWindow {
+	windows := make([]types.UptimeWindow, len(uptimeWindows))
+	for i, window := range uptimeWindows {
+		windows[i].Window = window.name
+	}
+	return windows
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// TeamStore persists teams and their memberships
+type TeamStore interface {
+	ListTeams(orgID string) ([]*types.Team, error)
+	ListUserTeams(userID string) ([]*types.Team, error)
+	GetTeam(id string) (*types.Team, error)
+	CreateTeam(team *types.Team) error
+	UpdateTeam(team *types.Team) error
+	DeleteTeam(id string) (bool, error)
+	ListMembers(teamID string) ([]*types.TeamMember, error)
+	AddMember(teamID, userID, source string) error
+	RemoveMember(teamID, userID string) (bool, error)
+	RemoveSyncedMember(teamID, userID string) (bool, error)
+	UserOrganization(userID string) (string, error)
+}
+
+// TeamService manages teams: groups of users within an organization that
+// are granted roles, namespace access and API keys collectively. Teams
+// mapped to an identity provider group have their membership synced from
+// the groups the IdP asserts for each user.
+type TeamService struct {
+	store TeamStore
+}
+
+// NewTeamService creates a database-backed team service
+func NewTeamService(db *sql.DB) *TeamService {
+	return NewTeamServiceWithStore(models.NewTeamModel(db))
+}
+
+// NewTeamServiceWithStore creates a team service over store
+func NewTeamServiceWithStore(store TeamStore) *TeamService {
+	return &TeamService{store: store}
+}
+
+// ListTeams returns the organization's teams
+func (s *TeamService) ListTeams(ctx context.Context, orgID string) ([]*types.Team, error) {
+	teams, err := s.store.ListTeams(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	return teams, nil
+}
+
+// ListUserTeams returns the teams a user belongs to
+func (s *TeamService) ListUserTeams(ctx context.Context, userID string) ([]*types.Team, error) {
+	teams, err := s.store.ListUserTeams(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user teams: %w", err)
+	}
+	return teams, nil
+}
+
+// GetTeam returns a team of the organization
+func (s *TeamService) GetTeam(ctx context.Context, orgID, teamID string) (*types.Team, error) {
+	if _, err := uuid.Parse(teamID); err != nil {
+		return nil, types.NewNotFoundError("Team not found")
+	}
+	team, err := s.store.GetTeam(teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+	if team == nil || team.OrganizationID != orgID {
+		return nil, types.NewNotFoundError("Team not found")
+	}
+	return team, nil
+}
+
+// CreateTeam creates a team in the organization
+func (s *TeamService) CreateTeam(ctx context.Context, orgID, createdBy string, req *types.CreateTeamRequest) (*types.Team, error) {
+	team := &types.Team{
+		OrganizationID: orgID,
+		Name:           strings.TrimSpace(req.Name),
+		Description:    req.Description,
+		Role:           req.Role,
+		IdPGroup:       strings.TrimSpace(req.IdPGroup),
+		CreatedBy:      createdBy,
+	}
+	if err := s.checkUnique(orgID, team); err != nil {
+		return nil, err
+	}
+	if err := s.store.CreateTeam(team); err != nil {
+		return nil, fmt.Errorf("failed to create team: %w", err)
+	}
+	return team, nil
+}
+
+// UpdateTeam updates a team of the organization
+func (s *TeamService) UpdateTeam(ctx context.Context, orgID, teamID string, req *types.UpdateTeamRequest) (*types.Team, error) {
+	team, err := s.GetTeam(ctx, orgID, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		team.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		team.Description = *req.Description
+	}
+	if req.Role != nil {
+		switch *req.Role {
+		case "", types.RoleAdmin, types.RoleUser, types.RoleViewer, types.RoleAPIUser:
+			team.Role = *req.Role
+		default:
+			return nil, types.NewValidationError("unknown role " + *req.Role)
+		}
+	}
+	if req.IdPGroup != nil {
+		team.IdPGroup = strings.TrimSpace(*req.IdPGroup)
+	}
+	if err := s.checkUnique(orgID, team); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpdateTeam(team); err != nil {
+		return nil, fmt.Errorf("failed to update team: %w", err)
+	}
+	return team, nil
+}
+
+// DeleteTeam deletes a team of the organization. Its API keys stay with the
+// users who created them.
+func (s *TeamService) DeleteTeam(ctx context.Context, orgID, teamID string) error {
+	if _, err := s.GetTeam(ctx, orgID, teamID); err != nil {
+		return err
+	}
+	deleted, err := s.store.DeleteTeam(teamID)
+	if err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+	if !deleted {
+		return types.NewNotFoundError("Team not found")
+	}
+	return nil
+}
+
+// ListMembers returns the members of a team of the organization
+func (s *TeamService) ListMembers(ctx context.Context, orgID, teamID string) ([]*types.TeamMember, error) {
+	if _, err := s.GetTeam(ctx, orgID, teamID); err != nil {
+		return nil, err
+	}
+	members, err := s.store.ListMembers(teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team members: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember adds a user of the organization to a team
+func (s *TeamService) AddMember(ctx context.Context, orgID, teamID, userID string) error {
+	if _, err := s.GetTeam(ctx, orgID, teamID); err != nil {
+		return err
+	}
+	if err := s.checkUser(orgID, userID); err != nil {
+		return err
+	}
+	if err := s.store.AddMember(teamID, userID, types.TeamMemberSourceManual); err != nil {
+		return fmt.Errorf("failed to add team member: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember removes a user from a team of the organization
+func (s *TeamService) RemoveMember(ctx context.Context, orgID, teamID, userID string) error {
+	if _, err := s.GetTeam(ctx, orgID, teamID); err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(userID); err != nil {
+		return types.NewNotFoundError("Team member not found")
+	}
+	removed, err := s.store.RemoveMember(teamID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+	if !removed {
+		return types.NewNotFoundError("Team member not found")
+	}
+	return nil
+}
+
+// SyncGroups brings a user's memberships of IdP-mapped teams in line with
+// the groups the identity provider asserts for them. Group names match
+// case-insensitively. Memberships added manually are never removed.
+func (s *TeamService) SyncGroups(ctx context.Context, orgID, userID string, groups []string) (*types.TeamSyncResult, error) {
+	if err := s.checkUser(orgID, userID); err != nil {
+		return nil, err
+	}
+
+	asserted := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		asserted[strings.ToLower(strings.TrimSpace(group))] = true
+	}
+
+	teams, err := s.store.ListTeams(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	current, err := s.store.ListUserTeams(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user teams: %w", err)
+	}
+	member := make(map[string]bool, len(current))
+	for _, team := range current {
+		member[team.ID] = true
+	}
+
+	result := &types.TeamSyncResult{Joined: []string{}, Left: []string{}}
+	for _, team := range teams {
+		if team.IdPGroup == "" {
+			continue
+		}
+		switch {
+		case asserted[strings.ToLower(team.IdPGroup)] && !member[team.ID]:
+			if err := s.store.AddMember(team.ID, userID, types.TeamMemberSourceIdP); err != nil {
+				return nil, fmt.Errorf("failed to add team member: %w", err)
+			}
+			result.Joined = append(result.Joined, team.ID)
+		case !asserted[strings.ToLower(team.IdPGroup)] && member[team.ID]:
+			removed, err := s.store.RemoveSyncedMember(team.ID, userID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to remove team member: %w", err)
+			}
+			if removed {
+				result.Left = append(result.Left, team.ID)
+			}
+		}
+	}
+	return result, nil
+}
+
+// TeamRoles returns the roles a user's teams grant
+func (s *TeamService) TeamRoles(ctx context.Context, userID string) ([]string, error) {
+	teams, err := s.store.ListUserTeams(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user teams: %w", err)
+	}
+	roles := []string{}
+	for _, team := range teams {
+		if team.Role != "" {
+			roles = append(roles, team.Role)
+		}
+	}
+	return roles, nil
+}
+
+// checkUser returns a validation error unless the user belongs to the
+// organization
+func (s *TeamService) checkUser(orgID, userID string) error {
+	if _, err := uuid.Parse(userID); err != nil {
+		return types.NewValidationError("user_id must be a user ID")
+	}
+	userOrg, err := s.store.UserOrganization(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if userOrg != orgID {
+		return types.NewValidationError("user is not a member of the organization")
+	}
+	return nil
+}
+
+// checkUnique returns a conflict error when another team of the
+// organization has the team's name or identity provider group
+func (s *TeamService) checkUnique(orgID string, team *types.Team) error {
+	if team.Name == "" {
+		return types.NewValidationError("name is required")
+	}
+	teams, err := s.store.ListTeams(orgID)
+	if err != nil {
+		return fmt.Errorf("failed to list teams: %w", err)
+	}
+	for _, other := range teams {
+		if other.ID == team.ID {
+			continue
+		}
+		if strings.EqualFold(other.Name, team.Name) {
+			return types.NewConflictError("a team named " + team.Name + " already exists")
+		}
+		if team.IdPGroup != "" && strings.EqualFold(other.IdPGroup, team.IdPGroup) {
+			return types.NewConflictError("team " + other.Name + " is already mapped to group " + team.IdPGroup)
+		}
+	}
+	return nil
+}
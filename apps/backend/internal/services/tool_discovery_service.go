@@ -9,6 +9,7 @@ import (
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/secrets"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/google/uuid"
@@ -19,6 +20,14 @@ type ToolDiscoveryService struct {
 	toolModel        *models.MCPToolModel
 	serverRepo       ServerRepository
 	transportManager *transport.Manager
+	listings         ListingInvalidator
+	mockTools        MockToolSource
+}
+
+// ListingInvalidator drops an organization's cached listings once what they
+// list has changed
+type ListingInvalidator interface {
+	InvalidateOrganization(ctx context.Context, orgID string) error
 }
 
 // ServerRepository interface for server operations
@@ -35,6 +44,18 @@ func NewToolDiscoveryService(toolModel *models.MCPToolModel, serverRepo ServerRe
 	}
 }
 
+// SetListingInvalidator makes discovery drop the organization's cached
+// listings once it stored the discovered tools
+func (s *ToolDiscoveryService) SetListingInvalidator(listings ListingInvalidator) {
+	s.listings = listings
+}
+
+// SetMockTools makes discovery read the tools of mock servers from their
+// canned definitions instead of connecting to them
+func (s *ToolDiscoveryService) SetMockTools(mockTools MockToolSource) {
+	s.mockTools = mockTools
+}
+
 // DiscoverServerTools discovers and stores tools from an MCP server using namespace service integration
 func (s *ToolDiscoveryService) DiscoverServerTools(ctx context.Context, serverID uuid.UUID, organizationID uuid.UUID) error {
 	// Get server configuration
@@ -43,8 +64,15 @@ func (s *ToolDiscoveryService) DiscoverServerTools(ctx context.Context, serverID
 		return fmt.Errorf("failed to get server config: %w", err)
 	}
 
-	// Attempt real MCP tool discovery
-	tools, discoveryErr := s.discoverRealMCPTools(ctx, server)
+	// Attempt real MCP tool discovery; mock servers are answered by the
+	// gateway itself
+	var tools []types.MCPTool
+	var discoveryErr error
+	if server.Protocol == types.ProtocolMock {
+		tools, discoveryErr = s.discoverMockTools(server)
+	} else {
+		tools, discoveryErr = s.discoverRealMCPTools(ctx, server)
+	}
 
 	// If real discovery fails, log error and continue with empty tools list
 	if discoveryErr != nil {
@@ -90,6 +118,12 @@ func (s *ToolDiscoveryService) DiscoverServerTools(ctx context.Context, serverID
 		}
 	}
 
+	if s.listings != nil {
+		if err := s.listings.InvalidateOrganization(ctx, organizationID.String()); err != nil {
+			log.Printf("Warning: failed to invalidate cached listings of %s: %v", organizationID, err)
+		}
+	}
+
 	log.Printf("Successfully discovered %d tools from server %s", len(tools), server.Name)
 	return nil
 }
@@ -162,7 +196,7 @@ func (s *ToolDiscoveryService) discoverRealMCPTools(ctx context.Context, server
 	if transportType == types.TransportTypeSTDIO {
 		if server.Command.Valid && server.Command.String != "" {
 			// Basic validation that the command exists
-			log.Printf("Attempting tool discovery on STDIO server with command: %s", server.Command.String)
+			log.Printf("Attempting tool discovery on STDIO server with command: %s", secrets.Mask(server.Command.String))
 		} else {
 			return nil, fmt.Errorf("STDIO server missing command configuration")
 		}
@@ -179,8 +213,8 @@ func (s *ToolDiscoveryService) discoverRealMCPTools(ctx context.Context, server
 
 	var mcpResponse *types.MCPMessage
 
-	// For STDIO transport, use synchronous request-response pattern
-	if transportType == types.TransportTypeSTDIO {
+	// For STDIO and gRPC transports, use synchronous request-response pattern
+	if transportType == types.TransportTypeSTDIO || transportType == types.TransportTypeGRPC {
 		// Check if the transport has a SendRequest method using reflection/interface
 		type syncTransport interface {
 			SendRequest(ctx context.Context, request *types.MCPMessage) (*types.MCPMessage, error)
@@ -242,6 +276,28 @@ func (s *ToolDiscoveryService) discoverRealMCPTools(ctx context.Context, server
 	return tools, nil
 }
 
+// discoverMockTools returns the canned tools of a mock server
+func (s *ToolDiscoveryService) discoverMockTools(server *models.MCPServer) ([]types.MCPTool, error) {
+	if s.mockTools == nil {
+		return nil, fmt.Errorf("mock servers are not available")
+	}
+
+	mockTools, err := s.mockTools.List(server.ID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mock tools: %w", err)
+	}
+
+	tools := make([]types.MCPTool, 0, len(mockTools))
+	for _, tool := range mockTools {
+		tools = append(tools, types.MCPTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+		})
+	}
+	return tools, nil
+}
+
 // getTransportTypeForServer determines the appropriate transport type for a server
 func (s *ToolDiscoveryService) getTransportTypeForServer(server *models.MCPServer) types.TransportType {
 	switch strings.ToLower(server.Protocol) {
@@ -253,6 +309,8 @@ func (s *ToolDiscoveryService) getTransportTypeForServer(server *models.MCPServe
 		return types.TransportTypeWebSocket
 	case "sse":
 		return types.TransportTypeSSE
+	case "grpc":
+		return types.TransportTypeGRPC
 	default:
 		return ""
 	}
@@ -292,7 +350,7 @@ func (s *ToolDiscoveryService) buildTransportConfig(server *models.MCPServer) ma
 		if server.URL.Valid {
 			config["url"] = server.URL.String
 		}
-	case "sse":
+	case "sse", "grpc":
 		if server.URL.Valid {
 			config["url"] = server.URL.String
 		}
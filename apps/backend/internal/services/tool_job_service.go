@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultToolJobPollInterval is how often an idle consumer looks for
+	// queued jobs
+	DefaultToolJobPollInterval = time.Second
+	// maxToolJobBackoff caps the doubling backoff between attempts
+	maxToolJobBackoff = time.Hour
+	// defaultToolJobListLimit and maxToolJobListLimit bound job listings
+	defaultToolJobListLimit = 50
+	maxToolJobListLimit     = 200
+)
+
+// toolJobStatuses are the statuses jobs can be listed by
+var toolJobStatuses = []string{
+	types.ToolJobStatusQueued, types.ToolJobStatusRunning, types.ToolJobStatusSucceeded,
+	types.ToolJobStatusFailed, types.ToolJobStatusCancelled,
+}
+
+// ToolJobStore persists queued tool executions and the job settings of
+// tools
+type ToolJobStore interface {
+	Create(job *types.ToolJob) error
+	Get(orgID, id string) (*types.ToolJob, error)
+	List(orgID, createdBy, status string, limit int) ([]*types.ToolJob, error)
+	Cancel(orgID, id string) (bool, error)
+	Claim(worker string) (*types.ToolJob, error)
+	Finish(id, worker, status string, result *types.NamespaceToolResult, jobErr *types.Error) (bool, error)
+	Retry(id, worker string, runAfter time.Time, result *types.NamespaceToolResult, jobErr *types.Error) (bool, error)
+	Release(id, worker string) error
+	Prune(before time.Time) (int64, error)
+	ToolExists(orgID, toolID string) (bool, error)
+	GetSettings(toolID string) (*types.ToolJobSettings, error)
+	GetSettingsForServerTool(serverID, name string) (*types.ToolJobSettings, error)
+	UpsertSettings(settings *types.ToolJobSettings) error
+	DeleteSettings(toolID string) (bool, error)
+}
+
+// NamespaceToolResolver finds the server a namespace tool runs on
+type NamespaceToolResolver interface {
+	ToolServerID(ctx context.Context, namespaceID, tool string) (string, error)
+}
+
+// ToolJobService queues namespace tool calls as jobs and, in the worker,
+// runs them with retries and timeouts set per tool
+type ToolJobService struct {
+	store    ToolJobStore
+	tools    NamespaceToolResolver
+	executor ToolExecutor
+	now      func() time.Time
+	worker   string
+}
+
+// NewToolJobService creates a database-backed tool job service
+func NewToolJobService(db *sql.DB, tools NamespaceToolResolver) *ToolJobService {
+	return NewToolJobServiceWithStore(models.NewToolJobModel(db), tools)
+}
+
+// NewToolJobServiceWithStore creates a tool job service over store
+func NewToolJobServiceWithStore(store ToolJobStore, tools NamespaceToolResolver) *ToolJobService {
+	host, _ := os.Hostname()
+	return &ToolJobService{
+		store:  store,
+		tools:  tools,
+		now:    time.Now,
+		worker: fmt.Sprintf("%s-%s", host, uuid.New().String()[:8]),
+	}
+}
+
+// SetExecutor sets what runs claimed jobs; only consumers need one
+func (s *ToolJobService) SetExecutor(executor ToolExecutor) {
+	s.executor = executor
+}
+
+// SetClock replaces the clock used to schedule retries
+func (s *ToolJobService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Enqueue queues a tool call in an organization's namespace to run as the
+// caller in ctx. Calls the caller's API key scope excludes are refused
+// here rather than when they run.
+func (s *ToolJobService) Enqueue(ctx context.Context, orgID, namespaceID, userID string, req types.ExecuteNamespaceToolRequest) (*types.ToolJob, error) {
+	serverName, toolName, err := ParsePrefixedToolName(req.Tool)
+	if err != nil {
+		return nil, types.NewValidationError("tool must be a namespace tool name such as server__tool")
+	}
+	serverID, err := s.tools.ToolServerID(ctx, namespaceID, req.Tool)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to resolve tool: " + err.Error())
+	}
+	if serverID == "" {
+		return nil, types.NewNotFoundError(fmt.Sprintf("Namespace has no server %s", serverName))
+	}
+
+	principal := types.PrincipalFromContext(ctx)
+	if principal != nil && principal.Scope != nil {
+		if err := principal.Scope.CheckNamespace(namespaceID); err != nil {
+			return nil, err
+		}
+		if err := principal.Scope.CheckTool(serverID, req.Tool); err != nil {
+			return nil, err
+		}
+	}
+
+	settings, err := s.settingsFor(serverID, toolName)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get tool job settings: " + err.Error())
+	}
+
+	job := &types.ToolJob{
+		OrganizationID:      orgID,
+		NamespaceID:         namespaceID,
+		Tool:                req.Tool,
+		Arguments:           req.Arguments,
+		IdempotencyKey:      req.IdempotencyKey,
+		Principal:           principal,
+		MaxAttempts:         settings.MaxAttempts,
+		TimeoutSeconds:      settings.TimeoutSeconds,
+		RetryBackoffSeconds: settings.RetryBackoffSeconds,
+	}
+	if job.Arguments == nil {
+		job.Arguments = map[string]interface{}{}
+	}
+	// Every attempt carries the same key so that servers apply the call once
+	if job.IdempotencyKey == "" {
+		job.IdempotencyKey = uuid.New().String()
+	}
+	if userID != "" {
+		job.CreatedBy = &userID
+	}
+	if err := s.store.Create(job); err != nil {
+		return nil, types.NewInternalError("Failed to queue job: " + err.Error())
+	}
+	return job, nil
+}
+
+// Get returns a job of the organization. Jobs are private to the user who
+// queued them; admins see every job.
+func (s *ToolJobService) Get(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, id string) (*types.ToolJob, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, types.NewNotFoundError("Job not found")
+	}
+	job, err := s.store.Get(orgID, id)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get job: " + err.Error())
+	}
+	if job == nil || (!viewerIsAdmin && (job.CreatedBy == nil || *job.CreatedBy != viewerID)) {
+		return nil, types.NewNotFoundError("Job not found")
+	}
+	return job, nil
+}
+
+// List returns the most recent jobs the viewer can see, of one status when
+// status is set
+func (s *ToolJobService) List(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, status string, limit int) ([]*types.ToolJob, error) {
+	if status != "" && !slices.Contains(toolJobStatuses, status) {
+		return nil, types.NewValidationError(fmt.Sprintf("unknown status %q, expected one of %v", status, toolJobStatuses))
+	}
+	if limit <= 0 {
+		limit = defaultToolJobListLimit
+	}
+	limit = min(limit, maxToolJobListLimit)
+	createdBy := viewerID
+	if viewerIsAdmin {
+		createdBy = ""
+	}
+	jobs, err := s.store.List(orgID, createdBy, status, limit)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list jobs: " + err.Error())
+	}
+	return jobs, nil
+}
+
+// Cancel cancels a queued job the viewer can see. Jobs that started run to
+// the end of their attempt.
+func (s *ToolJobService) Cancel(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, id string) (*types.ToolJob, error) {
+	job, err := s.Get(ctx, orgID, viewerID, viewerIsAdmin, id)
+	if err != nil {
+		return nil, err
+	}
+	cancelled, err := s.store.Cancel(orgID, id)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to cancel job: " + err.Error())
+	}
+	if !cancelled {
+		return nil, types.NewConflictError(fmt.Sprintf("Job is %s; only queued jobs can be cancelled", job.Status))
+	}
+	return s.Get(ctx, orgID, viewerID, viewerIsAdmin, id)
+}
+
+// RunNext claims the next job due to run and runs one attempt of it,
+// reporting whether there was a job. A job whose attempt fails is queued
+// again after its backoff unless it used up its attempts or the gateway
+// refused the call, such as for a policy violation.
+func (s *ToolJobService) RunNext(ctx context.Context) (bool, error) {
+	if s.executor == nil {
+		return false, errors.New("tool job service has no executor")
+	}
+	job, err := s.store.Claim(s.worker)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim job: %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	// A job claimed again after its last attempt's worker stopped
+	if job.Attempts > job.MaxAttempts {
+		s.finish(job, types.ToolJobStatusFailed, job.Result,
+			types.NewTimeoutError("Job's last attempt did not finish before its lease ran out"))
+		return true, nil
+	}
+
+	timeout := time.Duration(job.TimeoutSeconds) * time.Second
+	runCtx, cancel := context.WithTimeout(types.WithPrincipal(ctx, job.Principal), timeout)
+	result, err := s.executor.ExecuteTool(runCtx, job.NamespaceID, types.ExecuteNamespaceToolRequest{
+		Tool:           job.Tool,
+		Arguments:      job.Arguments,
+		IdempotencyKey: job.IdempotencyKey,
+	})
+	timedOut := runCtx.Err() == context.DeadlineExceeded
+	cancel()
+
+	if err == nil && result != nil && result.Success {
+		s.finish(job, types.ToolJobStatusSucceeded, result, nil)
+		return true, nil
+	}
+
+	// The worker is stopping; another one runs the job
+	if ctx.Err() != nil {
+		if err := s.store.Release(job.ID, s.worker); err != nil {
+			log.Printf("Failed to release job %s: %v", job.ID, err)
+		}
+		return true, nil
+	}
+
+	jobErr, retryable := toolJobError(result, err, timedOut, job.TimeoutSeconds)
+	if !retryable || job.Attempts >= job.MaxAttempts {
+		s.finish(job, types.ToolJobStatusFailed, result, jobErr)
+		return true, nil
+	}
+
+	backoff := time.Duration(job.RetryBackoffSeconds) * time.Second << min(job.Attempts-1, 10)
+	runAfter := s.now().Add(min(backoff, maxToolJobBackoff))
+	if ok, err := s.store.Retry(job.ID, s.worker, runAfter, result, jobErr); err != nil {
+		log.Printf("Failed to queue job %s for retry: %v", job.ID, err)
+	} else if !ok {
+		log.Printf("Job %s was claimed by another worker before its attempt ended", job.ID)
+	}
+	return true, nil
+}
+
+// Prune deletes jobs that finished more than retention ago
+func (s *ToolJobService) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.store.Prune(s.now().Add(-retention))
+}
+
+// GetSettings returns the job settings of a tool of the organization, or
+// the defaults when it has none
+func (s *ToolJobService) GetSettings(ctx context.Context, orgID, toolID string) (*types.ToolJobSettings, error) {
+	if err := s.checkTool(orgID, toolID); err != nil {
+		return nil, err
+	}
+	settings, err := s.store.GetSettings(toolID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get tool job settings: " + err.Error())
+	}
+	if settings == nil {
+		settings = defaultToolJobSettings()
+		settings.ToolID = toolID
+	}
+	return settings, nil
+}
+
+// SetSettings replaces the job settings of a tool of the organization.
+// Jobs already queued keep the settings they were queued with.
+func (s *ToolJobService) SetSettings(ctx context.Context, orgID, toolID string, req *types.SetToolJobSettingsRequest) (*types.ToolJobSettings, error) {
+	if err := s.checkTool(orgID, toolID); err != nil {
+		return nil, err
+	}
+	settings := &types.ToolJobSettings{
+		ToolID:              toolID,
+		MaxAttempts:         req.MaxAttempts,
+		TimeoutSeconds:      req.TimeoutSeconds,
+		RetryBackoffSeconds: req.RetryBackoffSeconds,
+	}
+	if err := s.store.UpsertSettings(settings); err != nil {
+		return nil, types.NewInternalError("Failed to save tool job settings: " + err.Error())
+	}
+	return settings, nil
+}
+
+// DeleteSettings returns a tool of the organization to the default job
+// settings
+func (s *ToolJobService) DeleteSettings(ctx context.Context, orgID, toolID string) error {
+	if err := s.checkTool(orgID, toolID); err != nil {
+		return err
+	}
+	deleted, err := s.store.DeleteSettings(toolID)
+	if err != nil {
+		return types.NewInternalError("Failed to delete tool job settings: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Tool has no job settings")
+	}
+	return nil
+}
+
+func (s *ToolJobService) finish(job *types.ToolJob, status string, result *types.NamespaceToolResult, jobErr *types.Error) {
+	ok, err := s.store.Finish(job.ID, s.worker, status, result, jobErr)
+	if err != nil {
+		log.Printf("Failed to record the end of job %s: %v", job.ID, err)
+	} else if !ok {
+		log.Printf("Job %s was claimed by another worker before its attempt ended", job.ID)
+	}
+}
+
+func (s *ToolJobService) settingsFor(serverID, toolName string) (*types.ToolJobSettings, error) {
+	settings, err := s.store.GetSettingsForServerTool(serverID, toolName)
+	if err != nil || settings != nil {
+		return settings, err
+	}
+	return defaultToolJobSettings(), nil
+}
+
+func (s *ToolJobService) checkTool(orgID, toolID string) error {
+	if _, err := uuid.Parse(toolID); err != nil {
+		return types.NewNotFoundError("Tool not found")
+	}
+	exists, err := s.store.ToolExists(orgID, toolID)
+	if err != nil {
+		return types.NewInternalError("Failed to get tool: " + err.Error())
+	}
+	if !exists {
+		return types.NewNotFoundError("Tool not found")
+	}
+	return nil
+}
+
+func defaultToolJobSettings() *types.ToolJobSettings {
+	return &types.ToolJobSettings{
+		MaxAttempts:         types.DefaultToolJobMaxAttempts,
+		TimeoutSeconds:      int(types.DefaultToolJobTimeout.Seconds()),
+		RetryBackoffSeconds: int(types.DefaultToolJobRetryBackoff.Seconds()),
+		Default:             true,
+	}
+}
+
+// toolJobError describes why an attempt failed and whether another attempt
+// may succeed. Calls the gateway refused for the caller, other than for
+// rate limits, fail the same way every time.
+func toolJobError(result *types.NamespaceToolResult, err error, timedOut bool, timeoutSeconds int) (*types.Error, bool) {
+	switch {
+	case timedOut:
+		return types.NewTimeoutError(fmt.Sprintf("Attempt timed out after %ds", timeoutSeconds)), true
+	case err != nil:
+		var gatewayErr *types.Error
+		if errors.As(err, &gatewayErr) {
+			refused := gatewayErr.Status >= 400 && gatewayErr.Status < 500 && gatewayErr.Status != http.StatusTooManyRequests
+			return gatewayErr, !refused
+		}
+		return types.NewInternalError(err.Error()), true
+	case result != nil:
+		return types.NewErrorWithDetails(types.ErrCodeBadGateway, "Tool call failed", result.Error, http.StatusBadGateway), true
+	default:
+		return types.NewInternalError("Tool call returned no result"), true
+	}
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/ratelimit"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// toolLimitCacheTTL bounds how long a limit change made on another
+// instance takes to apply
+const toolLimitCacheTTL = 10 * time.Second
+
+// toolBudgetUsageRetention is how many days of budget usage are kept
+const toolBudgetUsageRetention = 7
+
+// ToolLimitStore persists tool limits and counts the calls budgets admit
+type ToolLimitStore interface {
+	ToolExists(orgID, toolID string) (bool, error)
+	Get(toolID string) (*types.ToolLimits, error)
+	GetForServerTool(serverID, name string) (*types.ToolLimits, error)
+	ListForTools(toolIDs []string) (map[string]*types.ToolLimits, error)
+	Upsert(limits *types.ToolLimits) error
+	Delete(toolID string) (bool, error)
+	ConsumeBudget(toolID string, day time.Time, budget int64) (bool, error)
+	Usage(toolIDs []string, day time.Time) (map[string]int64, error)
+	PruneUsage(before time.Time) error
+}
+
+// ToolLimitService enforces per-tool rate limits and daily budgets on tool
+// calls, beyond the organization's request rate limits
+type ToolLimitService struct {
+	store     ToolLimitStore
+	limiter   ratelimit.Limiter
+	now       func() time.Time
+	cache     map[string]cachedToolLimits
+	prunedDay time.Time
+	mu        sync.Mutex
+}
+
+type cachedToolLimits struct {
+	loadedAt time.Time
+	limits   *types.ToolLimits
+}
+
+// NewToolLimitService creates a database-backed tool limit service whose
+// rate limits are counted by limiter
+func NewToolLimitService(db *sql.DB, limiter ratelimit.Limiter) *ToolLimitService {
+	return NewToolLimitServiceWithStore(models.NewToolLimitModel(db), limiter)
+}
+
+// NewToolLimitServiceWithStore creates a tool limit service over store
+func NewToolLimitServiceWithStore(store ToolLimitStore, limiter ratelimit.Limiter) *ToolLimitService {
+	return &ToolLimitService{
+		store:   store,
+		limiter: limiter,
+		now:     time.Now,
+		cache:   make(map[string]cachedToolLimits),
+	}
+}
+
+// SetClock replaces the clock used for budget days and cached limits
+func (s *ToolLimitService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// GetLimits returns the limits of a tool of the organization with its usage
+// today. Tools without limits report zero limits.
+func (s *ToolLimitService) GetLimits(ctx context.Context, orgID, toolID string) (*types.ToolLimitStatus, error) {
+	if err := s.checkTool(orgID, toolID); err != nil {
+		return nil, err
+	}
+	statuses, err := s.UsageForTools(ctx, []string{toolID})
+	if err != nil {
+		return nil, err
+	}
+	if status, ok := statuses[toolID]; ok {
+		return status, nil
+	}
+	return s.status(&types.ToolLimits{ToolID: toolID}, 0), nil
+}
+
+// SetLimits replaces the limits of a tool of the organization
+func (s *ToolLimitService) SetLimits(ctx context.Context, orgID, toolID string, req *types.SetToolLimitsRequest) (*types.ToolLimitStatus, error) {
+	if err := s.checkTool(orgID, toolID); err != nil {
+		return nil, err
+	}
+	if req.RateLimit <= 0 && req.DailyBudget <= 0 {
+		return nil, types.NewValidationError("set a rate_limit, a daily_budget or both")
+	}
+	limits := &types.ToolLimits{
+		ToolID:            toolID,
+		RateLimit:         req.RateLimit,
+		RateWindowSeconds: req.RateWindowSeconds,
+		DailyBudget:       req.DailyBudget,
+	}
+	if limits.RateWindowSeconds == 0 {
+		limits.RateWindowSeconds = int(types.DefaultToolRateWindow.Seconds())
+	}
+	if err := s.store.Upsert(limits); err != nil {
+		return nil, types.NewInternalError("Failed to save tool limits: " + err.Error())
+	}
+	s.invalidate()
+	return s.GetLimits(ctx, orgID, toolID)
+}
+
+// DeleteLimits removes the limits of a tool of the organization
+func (s *ToolLimitService) DeleteLimits(ctx context.Context, orgID, toolID string) error {
+	if err := s.checkTool(orgID, toolID); err != nil {
+		return err
+	}
+	deleted, err := s.store.Delete(toolID)
+	if err != nil {
+		return types.NewInternalError("Failed to delete tool limits: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Tool has no limits")
+	}
+	s.invalidate()
+	return nil
+}
+
+// UsageForTools returns the limits and today's usage of the tools that
+// have limits, by tool ID
+func (s *ToolLimitService) UsageForTools(ctx context.Context, toolIDs []string) (map[string]*types.ToolLimitStatus, error) {
+	statuses := make(map[string]*types.ToolLimitStatus)
+	if len(toolIDs) == 0 {
+		return statuses, nil
+	}
+	limits, err := s.store.ListForTools(toolIDs)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get tool limits: " + err.Error())
+	}
+	if len(limits) == 0 {
+		return statuses, nil
+	}
+	usage, err := s.store.Usage(toolIDs, budgetDay(s.now()))
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get tool usage: " + err.Error())
+	}
+	for toolID, l := range limits {
+		statuses[toolID] = s.status(l, usage[toolID])
+	}
+	return statuses, nil
+}
+
+// CheckToolLimits counts a call of the tool a server exposes under name
+// against the tool's rate limit and daily budget. tool names the tool in
+// errors. Rate limits are skipped when the limiter fails, while budgets are
+// enforced or the call refused.
+func (s *ToolLimitService) CheckToolLimits(ctx context.Context, serverID, name, tool string) error {
+	limits, err := s.limits(serverID, name)
+	if err != nil {
+		return types.NewServiceUnavailableError("Tool limits could not be loaded: " + err.Error())
+	}
+	if limits == nil {
+		return nil
+	}
+
+	if limits.RateLimit > 0 && s.limiter != nil {
+		window := time.Duration(limits.RateWindowSeconds) * time.Second
+		result, err := s.limiter.Allow(ctx, "tool:"+limits.ToolID, ratelimit.Policy{
+			Algorithm: ratelimit.AlgorithmSlidingWindow,
+			Window:    window,
+			Limit:     limits.RateLimit,
+		})
+		if err != nil {
+			log.Printf("Skipping rate limit of tool %s: %v", tool, err)
+		} else if !result.Allowed {
+			return types.NewErrorWithDetails(types.ErrCodeRateLimitExceeded,
+				fmt.Sprintf("Tool %s allows %d calls per %s", tool, limits.RateLimit, window),
+				fmt.Sprintf("retry after %s", result.RetryAfter.Round(time.Second)), http.StatusTooManyRequests)
+		}
+	}
+
+	if limits.DailyBudget > 0 {
+		now := s.now()
+		day := budgetDay(now)
+		s.pruneUsage(day)
+		admitted, err := s.store.ConsumeBudget(limits.ToolID, day, limits.DailyBudget)
+		if err != nil {
+			return types.NewServiceUnavailableError("Tool budget could not be checked: " + err.Error())
+		}
+		if !admitted {
+			return types.NewToolBudgetExceededError(tool, limits.DailyBudget, day.AddDate(0, 0, 1))
+		}
+	}
+	return nil
+}
+
+func (s *ToolLimitService) status(limits *types.ToolLimits, calls int64) *types.ToolLimitStatus {
+	status := &types.ToolLimitStatus{
+		ToolLimits: *limits,
+		ResetsAt:   budgetDay(s.now()).AddDate(0, 0, 1),
+		CallsToday: calls,
+		Remaining:  -1,
+	}
+	if limits.DailyBudget > 0 {
+		status.Remaining = max(limits.DailyBudget-calls, 0)
+	}
+	return status
+}
+
+// limits returns the cached limits of a server's tool, or nil when it has
+// none
+func (s *ToolLimitService) limits(serverID, name string) (*types.ToolLimits, error) {
+	key := serverID + ":" + name
+	now := s.now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Sub(cached.loadedAt) < toolLimitCacheTTL {
+		return cached.limits, nil
+	}
+
+	limits, err := s.store.GetForServerTool(serverID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[key] = cachedToolLimits{loadedAt: now, limits: limits}
+	s.mu.Unlock()
+	return limits, nil
+}
+
+// invalidate drops every cached limit: limits are cached by server and
+// tool name, which a change by tool ID does not name
+func (s *ToolLimitService) invalidate() {
+	s.mu.Lock()
+	s.cache = make(map[string]cachedToolLimits)
+	s.mu.Unlock()
+}
+
+// pruneUsage deletes old budget usage once a day
+func (s *ToolLimitService) pruneUsage(day time.Time) {
+	s.mu.Lock()
+	if !s.prunedDay.Before(day) {
+		s.mu.Unlock()
+		return
+	}
+	s.prunedDay = day
+	s.mu.Unlock()
+
+	if err := s.store.PruneUsage(day.AddDate(0, 0, -toolBudgetUsageRetention)); err != nil {
+		log.Printf("Failed to prune tool budget usage: %v", err)
+	}
+}
+
+func (s *ToolLimitService) checkTool(orgID, toolID string) error {
+	exists, err := s.store.ToolExists(orgID, toolID)
+	if err != nil {
+		return types.NewInternalError("Failed to get tool: " + err.Error())
+	}
+	if !exists {
+		return types.NewNotFoundError("Tool not found")
+	}
+	return nil
+}
+
+// budgetDay is the UTC day budgets count t against
+func budgetDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
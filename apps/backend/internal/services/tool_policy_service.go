@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/toolpolicy"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// toolPolicyCacheTTL bounds how long a policy change made on another
+// instance takes to apply
+const toolPolicyCacheTTL = 10 * time.Second
+
+// ToolPolicyStore loads the active tool policies of an organization
+type ToolPolicyStore interface {
+	ListActive(orgID string) ([]*types.Policy, error)
+}
+
+// ToolPolicyService evaluates namespace tool calls against the calling
+// organization's tool policies
+type ToolPolicyService struct {
+	store ToolPolicyStore
+	now   func() time.Time
+	cache map[string]cachedToolPolicies
+	mu    sync.Mutex
+}
+
+type cachedToolPolicies struct {
+	loadedAt time.Time
+	rules    []*toolpolicy.Rule
+}
+
+// NewToolPolicyService creates a database-backed tool policy service
+func NewToolPolicyService(db *sql.DB) *ToolPolicyService {
+	return NewToolPolicyServiceWithStore(models.NewToolPolicyModel(db))
+}
+
+// NewToolPolicyServiceWithStore creates a tool policy service over store
+func NewToolPolicyServiceWithStore(store ToolPolicyStore) *ToolPolicyService {
+	return &ToolPolicyService{
+		store: store,
+		now:   time.Now,
+		cache: make(map[string]cachedToolPolicies),
+	}
+}
+
+// SetClock replaces the clock used to expire cached policies
+func (s *ToolPolicyService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// CheckToolCall decides a tool call of the organization
+func (s *ToolPolicyService) CheckToolCall(ctx context.Context, orgID string, call *toolpolicy.Call) (*types.ToolPolicyDecision, error) {
+	rules, err := s.rules(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return toolpolicy.Evaluate(rules, call), nil
+}
+
+// Invalidate drops the cached policies of the organization so that changes
+// apply to the next call
+func (s *ToolPolicyService) Invalidate(orgID string) {
+	s.mu.Lock()
+	delete(s.cache, orgID)
+	s.mu.Unlock()
+}
+
+// Test evaluates a hypothetical call against the organization's active tool
+// policies and the request's candidate policies, with a trace of every
+// policy considered
+func (s *ToolPolicyService) Test(ctx context.Context, orgID string, req *types.TestToolPolicyRequest) (*types.ToolPolicyDecision, error) {
+	var rules []*toolpolicy.Rule
+	if !req.IgnoreSaved {
+		saved, err := s.rules(orgID)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, saved...)
+	}
+
+	for i, candidate := range req.Policies {
+		if candidate.Type != types.PolicyTypeTool {
+			return nil, types.NewValidationError(fmt.Sprintf("policies[%d]: only %s policies can be tested", i, types.PolicyTypeTool))
+		}
+		name := candidate.Name
+		if name == "" {
+			name = fmt.Sprintf("candidate %d", i+1)
+		}
+		rule, err := toolpolicy.Compile(&types.Policy{
+			Name:       name,
+			Type:       candidate.Type,
+			Priority:   candidate.Priority,
+			Conditions: candidate.Conditions,
+			Actions:    candidate.Actions,
+		})
+		if err != nil {
+			return nil, types.NewValidationError(fmt.Sprintf("policies[%d]: %v", i, err))
+		}
+		rules = append(rules, rule)
+	}
+
+	serverName := req.Server
+	if serverName == "" {
+		serverName, _, _ = ParsePrefixedToolName(req.Tool)
+	}
+	return toolpolicy.Evaluate(rules, &toolpolicy.Call{
+		Arguments:   req.Arguments,
+		Principal:   req.Caller,
+		NamespaceID: req.NamespaceID,
+		ServerName:  serverName,
+		Tool:        req.Tool,
+	}), nil
+}
+
+// rules returns the compiled active policies of the organization. Stored
+// policies that no longer compile are skipped and logged rather than
+// blocking every call.
+func (s *ToolPolicyService) rules(orgID string) ([]*toolpolicy.Rule, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[orgID]
+	s.mu.Unlock()
+	if ok && s.now().Sub(cached.loadedAt) < toolPolicyCacheTTL {
+		return cached.rules, nil
+	}
+
+	policies, err := s.store.ListActive(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tool policies: %w", err)
+	}
+	rules := make([]*toolpolicy.Rule, 0, len(policies))
+	for _, policy := range policies {
+		rule, err := toolpolicy.Compile(policy)
+		if err != nil {
+			log.Printf("Skipping tool policy %s: %v", policy.ID, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	s.mu.Lock()
+	s.cache[orgID] = cachedToolPolicies{loadedAt: s.now(), rules: rules}
+	s.mu.Unlock()
+	return rules, nil
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultToolJobRetention is how long a streamed execution can be
+	// polled after it ends
+	DefaultToolJobRetention = 15 * time.Minute
+	// toolJobTimeout bounds a streamed execution, which keeps running when
+	// its caller disconnects so that the result can be polled
+	toolJobTimeout = 30 * time.Minute
+	// toolJobProgressBuffer is how many progress notifications wait for a
+	// slow stream before further ones are dropped from it
+	toolJobProgressBuffer = 64
+)
+
+// ToolExecutor runs a tool call in a namespace
+type ToolExecutor interface {
+	ExecuteTool(ctx context.Context, namespaceID string, req types.ExecuteNamespaceToolRequest) (*types.NamespaceToolResult, error)
+}
+
+// ToolStreamService runs namespace tool calls whose progress is streamed to
+// the caller. Each call is a job that can be polled on this instance while
+// it runs and for the retention period after it ends, so a caller whose
+// stream broke can still collect the result.
+type ToolStreamService struct {
+	executor  ToolExecutor
+	now       func() time.Time
+	jobs      map[string]*toolJob
+	retention time.Duration
+	mu        sync.Mutex
+}
+
+type toolJob struct {
+	job   types.ToolExecutionJob
+	orgID string
+}
+
+// NewToolStreamService creates a tool stream service running calls with
+// executor; a zero retention takes the default
+func NewToolStreamService(executor ToolExecutor, retention time.Duration) *ToolStreamService {
+	if retention <= 0 {
+		retention = DefaultToolJobRetention
+	}
+	return &ToolStreamService{
+		executor:  executor,
+		now:       time.Now,
+		jobs:      make(map[string]*toolJob),
+		retention: retention,
+	}
+}
+
+// SetClock replaces the clock used to time jobs and expire them
+func (s *ToolStreamService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Start runs a tool call of an organization in a namespace as a job and
+// returns the job with the call's progress notifications. The channel is
+// closed when the call ends; Get then returns its result. The call
+// outlives ctx, keeping its values such as the caller's principal.
+func (s *ToolStreamService) Start(ctx context.Context, orgID, namespaceID string, req types.ExecuteNamespaceToolRequest) (*types.ToolExecutionJob, <-chan types.ToolProgress) {
+	now := s.now()
+	entry := &toolJob{
+		orgID: orgID,
+		job: types.ToolExecutionJob{
+			StartedAt:   now,
+			ID:          uuid.New().String(),
+			NamespaceID: namespaceID,
+			Tool:        req.Tool,
+			Status:      types.ToolJobStatusRunning,
+		},
+	}
+
+	s.mu.Lock()
+	s.prune(now)
+	s.jobs[entry.job.ID] = entry
+	job := entry.job
+	s.mu.Unlock()
+
+	progress := make(chan types.ToolProgress, toolJobProgressBuffer)
+	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), toolJobTimeout)
+	runCtx = types.WithToolProgress(runCtx, func(p types.ToolProgress) {
+		s.mu.Lock()
+		entry.job.Progress = &p
+		s.mu.Unlock()
+		select {
+		case progress <- p:
+		default:
+		}
+	})
+
+	go func() {
+		defer cancel()
+		result, err := s.executor.ExecuteTool(runCtx, namespaceID, req)
+		s.finish(entry, result, err)
+		close(progress)
+	}()
+
+	return &job, progress
+}
+
+// Get returns a job of an organization's namespace
+func (s *ToolStreamService) Get(ctx context.Context, orgID, namespaceID, jobID string) (*types.ToolExecutionJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(s.now())
+
+	entry, ok := s.jobs[jobID]
+	if !ok || entry.orgID != orgID || entry.job.NamespaceID != namespaceID {
+		return nil, types.NewNotFoundError("Execution not found")
+	}
+	job := entry.job
+	return &job, nil
+}
+
+func (s *ToolStreamService) finish(entry *toolJob, result *types.NamespaceToolResult, err error) {
+	finishedAt := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry.job.FinishedAt = &finishedAt
+	switch {
+	case err != nil:
+		entry.job.Status = types.ToolJobStatusFailed
+		gatewayErr, ok := err.(*types.Error)
+		if !ok {
+			gatewayErr = types.NewInternalError(err.Error())
+		}
+		entry.job.Error = gatewayErr
+	case result != nil && result.Success:
+		entry.job.Status = types.ToolJobStatusSucceeded
+		entry.job.Result = result
+	default:
+		entry.job.Status = types.ToolJobStatusFailed
+		entry.job.Result = result
+	}
+}
+
+// prune forgets jobs that ended more than the retention period ago. The
+// caller holds s.mu.
+func (s *ToolStreamService) prune(now time.Time) {
+	for id, entry := range s.jobs {
+		if entry.job.FinishedAt != nil && now.Sub(*entry.job.FinishedAt) > s.retention {
+			delete(s.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// usageBudgetCacheTTL bounds how long usage recorded by other instances, or
+// a budget changed on one, takes to stop or resume traffic
+const usageBudgetCacheTTL = 10 * time.Second
+
+// UsageBudgetStore persists monthly budgets, metered usage and the budget
+// alerts sent
+type UsageBudgetStore interface {
+	Usage(orgID string, period time.Time) (map[string]int64, error)
+	ListBudgets(orgID string) ([]*types.UsageBudget, error)
+	UpsertBudget(budget *types.UsageBudget) error
+	DeleteBudget(orgID, dimension string) (bool, error)
+	NamespacesExist(orgID string, namespaceIDs []string) (bool, error)
+	AlertsSent(orgID, dimension string, period time.Time) ([]int, error)
+	RecordAlert(orgID, dimension string, period time.Time, threshold int) (bool, error)
+	ClearAlerts(orgID, dimension string, period time.Time) error
+}
+
+// UsageBudgetService manages organizations' monthly budgets on metered
+// dimensions, notifies admins as usage crosses budget thresholds and stops
+// non-critical tool calls once a hard-stop budget is spent
+type UsageBudgetService struct {
+	store    UsageBudgetStore
+	notifier AdminNotifier
+	now      func() time.Time
+	cache    map[string]cachedUsageBudgets
+	mu       sync.Mutex
+}
+
+type cachedUsageBudgets struct {
+	loadedAt time.Time
+	usage    map[string]int64
+	budgets  []*types.UsageBudget
+}
+
+// NewUsageBudgetService creates a database-backed usage budget service
+func NewUsageBudgetService(db *sql.DB) *UsageBudgetService {
+	return NewUsageBudgetServiceWithStore(models.NewUsageBudgetModel(db))
+}
+
+// NewUsageBudgetServiceWithStore creates a usage budget service over store
+func NewUsageBudgetServiceWithStore(store UsageBudgetStore) *UsageBudgetService {
+	return &UsageBudgetService{
+		store: store,
+		now:   time.Now,
+		cache: make(map[string]cachedUsageBudgets),
+	}
+}
+
+// SetNotifier sets where budget threshold alerts are delivered
+func (s *UsageBudgetService) SetNotifier(notifier AdminNotifier) {
+	s.notifier = notifier
+}
+
+// SetClock replaces the clock used to pick the current month
+func (s *UsageBudgetService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// List returns an organization's budgets and this month's usage
+func (s *UsageBudgetService) List(ctx context.Context, orgID string) (*types.UsageBudgetListResponse, error) {
+	period := budgetPeriod(s.now())
+	usage, err := s.store.Usage(orgID, period)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get usage: " + err.Error())
+	}
+	budgets, err := s.store.ListBudgets(orgID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list budgets: " + err.Error())
+	}
+
+	resp := &types.UsageBudgetListResponse{
+		Usage:   &types.MeteredUsage{Usage: make(map[string]int64), Period: period.Format("2006-01")},
+		Budgets: []*types.UsageBudgetStatus{},
+	}
+	for _, dimension := range types.MeterDimensions {
+		resp.Usage.Usage[dimension] = usage[dimension]
+	}
+	for _, budget := range budgets {
+		status, err := s.status(budget, usage[budget.Dimension], period)
+		if err != nil {
+			return nil, err
+		}
+		resp.Budgets = append(resp.Budgets, status)
+	}
+	return resp, nil
+}
+
+// SetBudget replaces an organization's budget of a dimension. Alerts
+// already sent this month are forgotten, so they fire against the new
+// budget.
+func (s *UsageBudgetService) SetBudget(ctx context.Context, orgID, dimension string, req *types.SetUsageBudgetRequest) (*types.UsageBudgetStatus, error) {
+	if !slices.Contains(types.MeterDimensions, dimension) {
+		return nil, types.NewValidationError(fmt.Sprintf("unknown dimension %q, expected one of %v", dimension, types.MeterDimensions))
+	}
+	critical := []string{}
+	for _, id := range req.CriticalNamespaces {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, types.NewValidationError("invalid critical namespace ID: " + id)
+		}
+		if !slices.Contains(critical, id) {
+			critical = append(critical, id)
+		}
+	}
+	if len(critical) > 0 {
+		exist, err := s.store.NamespacesExist(orgID, critical)
+		if err != nil {
+			return nil, types.NewInternalError("Failed to check namespaces: " + err.Error())
+		}
+		if !exist {
+			return nil, types.NewValidationError("critical namespaces must belong to the organization")
+		}
+	}
+
+	budget := &types.UsageBudget{
+		OrganizationID:     orgID,
+		Dimension:          dimension,
+		CriticalNamespaces: critical,
+		MonthlyLimit:       req.MonthlyLimit,
+		HardStop:           req.HardStop,
+	}
+	if err := s.store.UpsertBudget(budget); err != nil {
+		return nil, types.NewInternalError("Failed to save budget: " + err.Error())
+	}
+	period := budgetPeriod(s.now())
+	if err := s.store.ClearAlerts(orgID, dimension, period); err != nil {
+		log.Printf("Failed to reset %s budget alerts of organization %s: %v", dimension, orgID, err)
+	}
+	s.invalidate(orgID)
+
+	usage, err := s.store.Usage(orgID, period)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get usage: " + err.Error())
+	}
+	return s.status(budget, usage[dimension], period)
+}
+
+// DeleteBudget removes an organization's budget of a dimension
+func (s *UsageBudgetService) DeleteBudget(ctx context.Context, orgID, dimension string) error {
+	deleted, err := s.store.DeleteBudget(orgID, dimension)
+	if err != nil {
+		return types.NewInternalError("Failed to delete budget: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Budget not found")
+	}
+	s.invalidate(orgID)
+	return nil
+}
+
+// CheckBudget refuses a tool call in a namespace when the organization has
+// spent a hard-stop budget the namespace is not critical to. Calls are let
+// through when budgets cannot be loaded: usage is metered after the fact
+// and a stop is a spending guard, not an access control.
+func (s *UsageBudgetService) CheckBudget(ctx context.Context, orgID, namespaceID string) error {
+	if orgID == "" {
+		return nil
+	}
+	cached, err := s.load(orgID)
+	if err != nil {
+		log.Printf("Skipping usage budgets of organization %s: %v", orgID, err)
+		return nil
+	}
+	for _, budget := range cached.budgets {
+		if !budget.HardStop || cached.usage[budget.Dimension] < budget.MonthlyLimit ||
+			slices.Contains(budget.CriticalNamespaces, namespaceID) {
+			continue
+		}
+		return types.NewBudgetExhaustedError(budget.Dimension, budget.MonthlyLimit, budgetPeriod(s.now()).AddDate(0, 1, 0))
+	}
+	return nil
+}
+
+// Evaluate notifies admins of every budget whose usage crossed a threshold
+// this month. Each threshold alerts once a month; when usage crossed
+// several since the last evaluation, only the highest is notified. It
+// returns the number of notifications created.
+func (s *UsageBudgetService) Evaluate(ctx context.Context) (int, error) {
+	budgets, err := s.store.ListBudgets("")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list budgets: %w", err)
+	}
+
+	period := budgetPeriod(s.now())
+	usageByOrg := make(map[string]map[string]int64)
+	created := 0
+	for _, budget := range budgets {
+		usage, ok := usageByOrg[budget.OrganizationID]
+		if !ok {
+			usage, err = s.store.Usage(budget.OrganizationID, period)
+			if err != nil {
+				log.Printf("Failed to get usage of organization %s: %v", budget.OrganizationID, err)
+				continue
+			}
+			usageByOrg[budget.OrganizationID] = usage
+		}
+
+		used := usage[budget.Dimension]
+		crossed := 0
+		for _, threshold := range types.UsageBudgetThresholds {
+			if used*100 < budget.MonthlyLimit*int64(threshold) {
+				break
+			}
+			recorded, err := s.store.RecordAlert(budget.OrganizationID, budget.Dimension, period, threshold)
+			if err != nil {
+				log.Printf("Failed to record %s budget alert of organization %s: %v", budget.Dimension, budget.OrganizationID, err)
+				continue
+			}
+			if recorded {
+				crossed = threshold
+			}
+		}
+		if crossed == 0 || s.notifier == nil {
+			continue
+		}
+
+		n, err := s.notifier.Notify(ctx, budgetThresholdEvent(budget, used, crossed, period))
+		if err != nil {
+			log.Printf("Failed to notify organization %s about its %s budget: %v", budget.OrganizationID, budget.Dimension, err)
+			continue
+		}
+		created += n
+	}
+	return created, nil
+}
+
+func budgetThresholdEvent(budget *types.UsageBudget, used int64, threshold int, period time.Time) *types.NotificationEvent {
+	severity := types.NotificationSeverityInfo
+	switch {
+	case threshold >= 100:
+		severity = types.NotificationSeverityCritical
+	case threshold >= 80:
+		severity = types.NotificationSeverityWarning
+	}
+	message := fmt.Sprintf("Your organization has used %d of its monthly %s budget of %d.",
+		used, budget.Dimension, budget.MonthlyLimit)
+	if budget.HardStop && threshold >= 100 {
+		message += " Tool calls outside critical namespaces are refused until the budget resets."
+	}
+	month := period.Format("2006-01")
+	return &types.NotificationEvent{
+		OrganizationID: budget.OrganizationID,
+		Type:           types.NotificationBudgetThreshold,
+		Severity:       severity,
+		Title:          fmt.Sprintf("%s budget at %d%%", budget.Dimension, threshold),
+		Message:        message,
+		ResourceType:   "organization",
+		ResourceID:     budget.OrganizationID,
+		DedupKey: fmt.Sprintf("%s:%s:%s:%s:%d", types.NotificationBudgetThreshold,
+			budget.OrganizationID, budget.Dimension, month, threshold),
+		Data: map[string]interface{}{
+			"dimension": budget.Dimension,
+			"period":    month,
+			"threshold": threshold,
+			"used":      used,
+			"limit":     budget.MonthlyLimit,
+			"hard_stop": budget.HardStop,
+		},
+	}
+}
+
+func (s *UsageBudgetService) status(budget *types.UsageBudget, used int64, period time.Time) (*types.UsageBudgetStatus, error) {
+	sent, err := s.store.AlertsSent(budget.OrganizationID, budget.Dimension, period)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get budget alerts: " + err.Error())
+	}
+	percent := float64(used) * 100 / float64(budget.MonthlyLimit)
+	return &types.UsageBudgetStatus{
+		UsageBudget: *budget,
+		ResetsAt:    period.AddDate(0, 1, 0),
+		Period:      period.Format("2006-01"),
+		AlertsSent:  sent,
+		Used:        used,
+		PercentUsed: math.Round(percent*100) / 100,
+		Stopping:    budget.HardStop && used >= budget.MonthlyLimit,
+	}, nil
+}
+
+// load returns the cached budgets and usage of an organization
+func (s *UsageBudgetService) load(orgID string) (cachedUsageBudgets, error) {
+	now := s.now()
+	s.mu.Lock()
+	cached, ok := s.cache[orgID]
+	s.mu.Unlock()
+	if ok && now.Sub(cached.loadedAt) < usageBudgetCacheTTL {
+		return cached, nil
+	}
+
+	budgets, err := s.store.ListBudgets(orgID)
+	if err != nil {
+		return cachedUsageBudgets{}, err
+	}
+	cached = cachedUsageBudgets{loadedAt: now, budgets: budgets}
+	if len(budgets) > 0 {
+		if cached.usage, err = s.store.Usage(orgID, budgetPeriod(now)); err != nil {
+			return cachedUsageBudgets{}, err
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[orgID] = cached
+	s.mu.Unlock()
+	return cached, nil
+}
+
+func (s *UsageBudgetService) invalidate(orgID string) {
+	s.mu.Lock()
+	delete(s.cache, orgID)
+	s.mu.Unlock()
+}
@@ -0,0 +1,514 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mailer"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultInvitationTTL    = 7 * 24 * time.Hour
+	defaultPasswordResetTTL = time.Hour
+)
+
+// UserAdminStore persists human users and the tokens emailed to them
+type UserAdminStore interface {
+	List(orgID string, filter *types.ManagedUserFilter) ([]*types.ManagedUser, error)
+	Get(orgID, id string) (*types.ManagedUser, error)
+	GetByEmail(email string) (*types.ManagedUser, error)
+	Create(user *types.ManagedUser, passwordHash string) error
+	Update(user *types.ManagedUser) error
+	SetDeactivated(orgID, id string, deactivated bool) (bool, error)
+	CountActiveAdmins(orgID string) (int, error)
+	CreateToken(token *types.UserToken) error
+	ConsumeToken(purpose, tokenHash string, now time.Time) (*types.UserToken, error)
+	SetPassword(userID, passwordHash string, activate bool) (bool, error)
+}
+
+// UserPasswordHasher hashes passwords with the configured algorithm
+type UserPasswordHasher interface {
+	Hash(secret string) (string, error)
+}
+
+// UserSeatLimiter enforces licensed seats when users become active
+type UserSeatLimiter interface {
+	CheckSeatAvailable(ctx context.Context) error
+}
+
+// UserAuditor records user lifecycle actions in the audit log
+type UserAuditor interface {
+	LogAudit(audit *types.AuditLog) error
+}
+
+// UserAdminService lets organization admins manage their human users:
+// create them with a password or invite them by email, change their name
+// and role, and deactivate or reactivate them. Invited users and users who
+// forgot their password get emailed single-use links. Every lifecycle
+// action is audited.
+type UserAdminService struct {
+	store            UserAdminStore
+	hasher           UserPasswordHasher
+	seats            UserSeatLimiter
+	mailer           mailer.Mailer
+	auditor          UserAuditor
+	now              func() time.Time
+	baseURL          string
+	invitationTTL    time.Duration
+	passwordResetTTL time.Duration
+}
+
+// NewUserAdminService creates a database-backed user admin service. Links
+// in emails point at baseURL.
+func NewUserAdminService(db *sql.DB, hasher UserPasswordHasher, baseURL string, invitationTTL, passwordResetTTL time.Duration) *UserAdminService {
+	return NewUserAdminServiceWithStore(models.NewUserAdminModel(db), hasher, baseURL, invitationTTL, passwordResetTTL)
+}
+
+// NewUserAdminServiceWithStore creates a user admin service over store
+func NewUserAdminServiceWithStore(store UserAdminStore, hasher UserPasswordHasher, baseURL string, invitationTTL, passwordResetTTL time.Duration) *UserAdminService {
+	if invitationTTL <= 0 {
+		invitationTTL = defaultInvitationTTL
+	}
+	if passwordResetTTL <= 0 {
+		passwordResetTTL = defaultPasswordResetTTL
+	}
+	return &UserAdminService{
+		store:            store,
+		hasher:           hasher,
+		now:              time.Now,
+		baseURL:          strings.TrimRight(baseURL, "/"),
+		invitationTTL:    invitationTTL,
+		passwordResetTTL: passwordResetTTL,
+	}
+}
+
+// SetSeatLimiter enforces licensed seats when users are created, accept an
+// invitation or are reactivated
+func (s *UserAdminService) SetSeatLimiter(seats UserSeatLimiter) {
+	s.seats = seats
+}
+
+// SetMailer configures how invitation and password reset links are sent.
+// Without one, links are returned to the admin instead.
+func (s *UserAdminService) SetMailer(m mailer.Mailer) {
+	s.mailer = m
+}
+
+// SetAuditor configures where user lifecycle actions are audited
+func (s *UserAdminService) SetAuditor(auditor UserAuditor) {
+	s.auditor = auditor
+}
+
+// SetClock replaces the service's time source, for tests
+func (s *UserAdminService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// List returns the organization's users
+func (s *UserAdminService) List(ctx context.Context, orgID string, filter *types.ManagedUserFilter) ([]*types.ManagedUser, error) {
+	switch filter.Status {
+	case "", types.UserStatusActive, types.UserStatusInvited, types.UserStatusDeactivated:
+	default:
+		return nil, types.NewValidationError("status must be active, invited or deactivated")
+	}
+	users, err := s.store.List(orgID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, nil
+}
+
+// Get returns a user of the organization
+func (s *UserAdminService) Get(ctx context.Context, orgID, id string) (*types.ManagedUser, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, types.NewNotFoundError("User not found")
+	}
+	user, err := s.store.Get(orgID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, types.NewNotFoundError("User not found")
+	}
+	return user, nil
+}
+
+// Create adds an active user with the password the admin chose
+func (s *UserAdminService) Create(ctx context.Context, orgID, actorID string, req *types.CreateManagedUserRequest) (*types.ManagedUser, error) {
+	if err := s.checkSeat(ctx); err != nil {
+		return nil, err
+	}
+	user, err := s.newUser(orgID, req.Email, req.Name, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user.Status = types.UserStatusActive
+	if err := s.store.Create(user, hash); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	s.audit(orgID, actorID, "create", user.ID, map[string]interface{}{
+		"email": user.Email,
+		"role":  user.Role,
+	})
+	return user, nil
+}
+
+// Invite adds a user who activates their account by choosing a password
+// through an emailed link
+func (s *UserAdminService) Invite(ctx context.Context, orgID, actorID string, req *types.InviteUserRequest) (*types.UserTokenDelivery, error) {
+	if err := s.checkSeat(ctx); err != nil {
+		return nil, err
+	}
+	user, err := s.newUser(orgID, req.Email, req.Name, req.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Status = types.UserStatusInvited
+	user.InvitedBy = actorID
+	if err := s.store.Create(user, ""); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	s.audit(orgID, actorID, "invite", user.ID, map[string]interface{}{
+		"email": user.Email,
+		"role":  user.Role,
+	})
+	return s.sendToken(ctx, user, actorID, types.UserTokenPurposeInvitation)
+}
+
+// ResendInvitation emails a new invitation link to a user who has not
+// accepted theirs yet. Earlier links stop working.
+func (s *UserAdminService) ResendInvitation(ctx context.Context, orgID, actorID, id string) (*types.UserTokenDelivery, error) {
+	user, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != types.UserStatusInvited {
+		return nil, types.NewConflictError("user has no pending invitation")
+	}
+	s.audit(orgID, actorID, "resend_invitation", user.ID, map[string]interface{}{"email": user.Email})
+	return s.sendToken(ctx, user, actorID, types.UserTokenPurposeInvitation)
+}
+
+// Update changes a user's name or role. The organization always keeps an
+// active admin.
+func (s *UserAdminService) Update(ctx context.Context, orgID, actorID, id string, req *types.UpdateManagedUserRequest) (*types.ManagedUser, error) {
+	user, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if len(name) < 2 {
+			return nil, types.NewValidationError("name must be at least 2 characters")
+		}
+		if name != user.Name {
+			changes["name"] = map[string]interface{}{"from": user.Name, "to": name}
+			user.Name = name
+		}
+	}
+	if req.Role != nil && *req.Role != user.Role {
+		if user.Role == types.RoleAdmin && user.Status == types.UserStatusActive {
+			if err := s.checkNotLastAdmin(orgID, "demote"); err != nil {
+				return nil, err
+			}
+		}
+		changes["role"] = map[string]interface{}{"from": user.Role, "to": *req.Role}
+		user.Role = *req.Role
+	}
+	if len(changes) == 0 {
+		return user, nil
+	}
+
+	if err := s.store.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	s.audit(orgID, actorID, "update", user.ID, changes)
+	return user, nil
+}
+
+// Deactivate stops a user from signing in and using their API keys, and
+// discards their pending invitation or reset links. Admins cannot
+// deactivate themselves or the organization's last active admin.
+func (s *UserAdminService) Deactivate(ctx context.Context, orgID, actorID, id string) (*types.ManagedUser, error) {
+	user, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == actorID {
+		return nil, types.NewValidationError("you cannot deactivate your own account")
+	}
+	if user.Status == types.UserStatusDeactivated {
+		return nil, types.NewConflictError("user is already deactivated")
+	}
+	if user.Role == types.RoleAdmin && user.Status == types.UserStatusActive {
+		if err := s.checkNotLastAdmin(orgID, "deactivate"); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.store.SetDeactivated(orgID, id, true); err != nil {
+		return nil, fmt.Errorf("failed to deactivate user: %w", err)
+	}
+	s.audit(orgID, actorID, "deactivate", user.ID, map[string]interface{}{"email": user.Email})
+	return s.Get(ctx, orgID, id)
+}
+
+// Reactivate restores a deactivated user. Users who had not accepted their
+// invitation are invited again.
+func (s *UserAdminService) Reactivate(ctx context.Context, orgID, actorID, id string) (*types.ManagedUser, error) {
+	user, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != types.UserStatusDeactivated {
+		return nil, types.NewConflictError("user is not deactivated")
+	}
+	if err := s.checkSeat(ctx); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.SetDeactivated(orgID, id, false); err != nil {
+		return nil, fmt.Errorf("failed to reactivate user: %w", err)
+	}
+	s.audit(orgID, actorID, "reactivate", user.ID, map[string]interface{}{"email": user.Email})
+	return s.Get(ctx, orgID, id)
+}
+
+// SendPasswordReset emails an active user a link to choose a new password
+func (s *UserAdminService) SendPasswordReset(ctx context.Context, orgID, actorID, id string) (*types.UserTokenDelivery, error) {
+	user, err := s.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != types.UserStatusActive {
+		return nil, types.NewConflictError("only active users can reset their password")
+	}
+	s.audit(orgID, actorID, "request_password_reset", user.ID, map[string]interface{}{"email": user.Email})
+	return s.sendToken(ctx, user, actorID, types.UserTokenPurposePasswordReset)
+}
+
+// RequestPasswordReset emails a reset link to the active user with the
+// given email. It succeeds whether or not such a user exists so callers
+// cannot probe for accounts.
+func (s *UserAdminService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.store.GetByEmail(types.NormalizeEmail(email))
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.Status != types.UserStatusActive || s.mailer == nil {
+		return nil
+	}
+	s.audit(user.OrganizationID, user.ID, "request_password_reset", user.ID, map[string]interface{}{"email": user.Email})
+	if _, err := s.sendToken(ctx, user, user.ID, types.UserTokenPurposePasswordReset); err != nil {
+		log.Printf("Failed to send password reset to user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// AcceptInvitation activates an invited user with the password they chose
+func (s *UserAdminService) AcceptInvitation(ctx context.Context, req *types.AcceptInvitationRequest) error {
+	if err := s.checkSeat(ctx); err != nil {
+		return err
+	}
+	token, err := s.redeem(types.UserTokenPurposeInvitation, req.Token, req.Password, true)
+	if err != nil {
+		return err
+	}
+	s.audit(token.OrganizationID, token.UserID, "accept_invitation", token.UserID, nil)
+	return nil
+}
+
+// ResetPassword sets a new password with an emailed reset token
+func (s *UserAdminService) ResetPassword(ctx context.Context, req *types.ResetPasswordRequest) error {
+	token, err := s.redeem(types.UserTokenPurposePasswordReset, req.Token, req.Password, false)
+	if err != nil {
+		return err
+	}
+	s.audit(token.OrganizationID, token.UserID, "reset_password", token.UserID, nil)
+	return nil
+}
+
+// redeem uses up a token and stores the password it was sent to choose
+func (s *UserAdminService) redeem(purpose, secret, password string, activate bool) (*types.UserToken, error) {
+	invalid := types.NewValidationError("the link is invalid or has expired")
+	token, err := s.store.ConsumeToken(purpose, hashUserToken(secret), s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem token: %w", err)
+	}
+	if token == nil {
+		return nil, invalid
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	updated, err := s.store.SetPassword(token.UserID, hash, activate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set password: %w", err)
+	}
+	if !updated {
+		return nil, invalid
+	}
+	return token, nil
+}
+
+// newUser validates a user to add to the organization. Emails are unique
+// across the gateway.
+func (s *UserAdminService) newUser(orgID, email, name, role string) (*types.ManagedUser, error) {
+	email = types.NormalizeEmail(email)
+	name = strings.TrimSpace(name)
+	if len(name) < 2 {
+		return nil, types.NewValidationError("name must be at least 2 characters")
+	}
+
+	existing, err := s.store.GetByEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if existing != nil {
+		return nil, types.NewConflictError("a user with this email already exists")
+	}
+	return &types.ManagedUser{
+		OrganizationID: orgID,
+		Email:          email,
+		Name:           name,
+		Role:           role,
+	}, nil
+}
+
+// sendToken issues a single-use link for the user and emails it, falling
+// back to returning it when email is not configured or fails
+func (s *UserAdminService) sendToken(ctx context.Context, user *types.ManagedUser, actorID, purpose string) (*types.UserTokenDelivery, error) {
+	secret, err := generateUserToken()
+	if err != nil {
+		return nil, err
+	}
+	ttl, path := s.invitationTTL, "/accept-invitation"
+	if purpose == types.UserTokenPurposePasswordReset {
+		ttl, path = s.passwordResetTTL, "/reset-password"
+	}
+
+	token := &types.UserToken{
+		OrganizationID: user.OrganizationID,
+		UserID:         user.ID,
+		Purpose:        purpose,
+		TokenHash:      hashUserToken(secret),
+		CreatedBy:      actorID,
+		ExpiresAt:      s.now().Add(ttl),
+	}
+	if err := s.store.CreateToken(token); err != nil {
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	delivery := &types.UserTokenDelivery{
+		User:      user,
+		ExpiresAt: token.ExpiresAt,
+	}
+	link := s.baseURL + path + "?token=" + url.QueryEscape(secret)
+	if s.mailer != nil {
+		if err := s.mailer.Send(ctx, BuildUserTokenEmail(user, purpose, link, token.ExpiresAt)); err != nil {
+			log.Printf("Failed to email %s link to user %s: %v", purpose, user.ID, err)
+		} else {
+			delivery.EmailSent = true
+		}
+	}
+	if !delivery.EmailSent {
+		delivery.URL = link
+	}
+	return delivery, nil
+}
+
+func (s *UserAdminService) checkSeat(ctx context.Context) error {
+	if s.seats == nil {
+		return nil
+	}
+	return s.seats.CheckSeatAvailable(ctx)
+}
+
+func (s *UserAdminService) checkNotLastAdmin(orgID, action string) error {
+	admins, err := s.store.CountActiveAdmins(orgID)
+	if err != nil {
+		return fmt.Errorf("failed to count admins: %w", err)
+	}
+	if admins <= 1 {
+		return types.NewConflictError(fmt.Sprintf("cannot %s the organization's last active admin", action))
+	}
+	return nil
+}
+
+func (s *UserAdminService) audit(orgID, actorID, action, userID string, details map[string]interface{}) {
+	if s.auditor == nil {
+		return
+	}
+	if err := s.auditor.LogAudit(&types.AuditLog{
+		OrganizationID: orgID,
+		UserID:         actorID,
+		Action:         action,
+		Resource:       "user",
+		ResourceID:     userID,
+		Details:        details,
+		Success:        true,
+	}); err != nil {
+		log.Printf("Failed to audit %s of user %s: %v", action, userID, err)
+	}
+}
+
+// BuildUserTokenEmail renders the email carrying an invitation or password
+// reset link
+func BuildUserTokenEmail(user *types.ManagedUser, purpose, link string, expiresAt time.Time) *mailer.Message {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\n", user.Name)
+	subject := "Omnimesh Gateway: reset your password"
+	if purpose == types.UserTokenPurposeInvitation {
+		subject = "Omnimesh Gateway: you have been invited"
+		body.WriteString("You have been invited to Omnimesh Gateway. Choose a password to activate your account:\n")
+	} else {
+		body.WriteString("A password reset was requested for your Omnimesh Gateway account. Choose a new password at:\n")
+	}
+	fmt.Fprintf(&body, "  %s\n\n", link)
+	fmt.Fprintf(&body, "The link can be used once and expires at %s.\n", expiresAt.UTC().Format(time.RFC1123))
+	if purpose == types.UserTokenPurposePasswordReset {
+		body.WriteString("If you did not request a reset you can ignore this email.\n")
+	}
+
+	return &mailer.Message{
+		To:      user.Email,
+		Subject: subject,
+		Body:    body.String(),
+	}
+}
+
+func generateUserToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashUserToken(token string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,518 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/pkg/requestsig"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultWebhookMaxAttempts, DefaultWebhookRetryBackoff and
+	// DefaultWebhookTimeout apply when the settings leave them unset
+	DefaultWebhookMaxAttempts  = 8
+	DefaultWebhookRetryBackoff = time.Minute
+	DefaultWebhookTimeout      = 10 * time.Second
+	// maxWebhookRetryBackoff caps the doubling backoff between attempts
+	maxWebhookRetryBackoff = time.Hour
+	// webhookDeliveryBatch bounds the deliveries claimed at once
+	webhookDeliveryBatch    = 20
+	defaultWebhookPageSize  = 50
+	maxWebhookPageSize      = 200
+	webhookSecretPrefix     = "whsec_"
+	webhookSecretRandomSize = 32
+)
+
+// WebhookSettings bound deliveries: attempts are retried MaxAttempts times
+// in all, RetryBackoff apart doubling each attempt, and each waits Timeout
+// for the receiver
+type WebhookSettings struct {
+	MaxAttempts  int
+	RetryBackoff time.Duration
+	Timeout      time.Duration
+}
+
+// WebhookEmitter publishes gateway events to organizations' webhooks
+type WebhookEmitter interface {
+	Emit(ctx context.Context, orgID, eventType string, data map[string]interface{}) error
+}
+
+// WebhookStore persists webhooks and their deliveries
+type WebhookStore interface {
+	List(orgID string) ([]*types.Webhook, error)
+	ListSubscribers(orgID, eventType string) ([]*types.Webhook, error)
+	Get(orgID, id string) (*types.Webhook, error)
+	Create(webhook *types.Webhook) error
+	Update(webhook *types.Webhook) error
+	Delete(orgID, id string) (bool, error)
+	CreateDelivery(delivery *types.WebhookDelivery) error
+	ClaimDue(limit int, lease time.Duration) ([]*types.DueWebhookDelivery, error)
+	FinishDelivery(delivery *types.WebhookDelivery, retryAfter time.Duration) error
+	GetDelivery(orgID, webhookID, id string) (*types.WebhookDelivery, error)
+	ListDeliveries(filter *types.WebhookDeliveryFilter) ([]*types.WebhookDelivery, error)
+	PruneDeliveries(cutoff time.Time) (int64, error)
+}
+
+// WebhookService delivers gateway events to the webhooks organizations
+// registered for them. Events are queued as deliveries and posted by the
+// worker, signed with each webhook's secret and retried with backoff.
+type WebhookService struct {
+	store    WebhookStore
+	egress   *airgap.Policy
+	client   *http.Client
+	now      func() time.Time
+	settings WebhookSettings
+}
+
+// NewWebhookService creates a database-backed webhook service
+func NewWebhookService(db *sql.DB, settings WebhookSettings) *WebhookService {
+	return NewWebhookServiceWithStore(models.NewWebhookModel(db), settings)
+}
+
+// NewWebhookServiceWithStore creates a webhook service over store
+func NewWebhookServiceWithStore(store WebhookStore, settings WebhookSettings) *WebhookService {
+	if settings.MaxAttempts <= 0 {
+		settings.MaxAttempts = DefaultWebhookMaxAttempts
+	}
+	if settings.RetryBackoff <= 0 {
+		settings.RetryBackoff = DefaultWebhookRetryBackoff
+	}
+	if settings.Timeout <= 0 {
+		settings.Timeout = DefaultWebhookTimeout
+	}
+	s := &WebhookService{
+		store:    store,
+		now:      time.Now,
+		settings: settings,
+	}
+	s.client = s.egress.PublicClient(types.ConnectivityFeatureWebhooks, settings.Timeout)
+	return s
+}
+
+// SetEgressPolicy restricts the URLs webhooks may use. Only hosts it
+// allow-lists may be inside the gateway's network.
+func (s *WebhookService) SetEgressPolicy(policy *airgap.Policy) {
+	s.egress = policy
+	s.client = policy.PublicClient(types.ConnectivityFeatureWebhooks, s.settings.Timeout)
+}
+
+// SetHTTPClient replaces the client deliveries are posted with
+func (s *WebhookService) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// SetClock replaces the clock events are timestamped with
+func (s *WebhookService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// List returns the organization's webhooks
+func (s *WebhookService) List(ctx context.Context, orgID string) ([]*types.Webhook, error) {
+	webhooks, err := s.store.List(orgID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list webhooks: " + err.Error())
+	}
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+	return webhooks, nil
+}
+
+// Get returns a webhook of the organization
+func (s *WebhookService) Get(ctx context.Context, orgID, id string) (*types.Webhook, error) {
+	webhook, err := s.get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// Create registers a webhook for the organization. The response carries
+// its signing secret, which is not shown again.
+func (s *WebhookService) Create(ctx context.Context, orgID, userID string, req *types.CreateWebhookRequest) (*types.Webhook, error) {
+	if err := s.checkURL(req.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeWebhookEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, types.NewInternalError("Failed to generate webhook secret: " + err.Error())
+	}
+
+	webhook := &types.Webhook{
+		OrganizationID: orgID,
+		Name:           strings.TrimSpace(req.Name),
+		URL:            req.URL,
+		Secret:         secret,
+		EventTypes:     eventTypes,
+		IsActive:       req.IsActive == nil || *req.IsActive,
+		CreatedBy:      userID,
+	}
+	if webhook.Name == "" {
+		return nil, types.NewValidationError("name is required")
+	}
+	if err := s.store.Create(webhook); err != nil {
+		return nil, types.NewInternalError("Failed to create webhook: " + err.Error())
+	}
+	webhook.SigningKeyID = requestsig.NewHMACKey(webhook.ID, webhook.Secret).ID
+	return webhook, nil
+}
+
+// Update changes the fields of a webhook of the organization set in req
+func (s *WebhookService) Update(ctx context.Context, orgID, id string, req *types.UpdateWebhookRequest) (*types.Webhook, error) {
+	webhook, err := s.get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		if webhook.Name = strings.TrimSpace(*req.Name); webhook.Name == "" {
+			return nil, types.NewValidationError("name must not be empty")
+		}
+	}
+	if req.URL != nil {
+		if err := s.checkURL(*req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		if webhook.EventTypes, err = normalizeWebhookEventTypes(req.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+	if req.IsActive != nil {
+		webhook.IsActive = *req.IsActive
+	}
+	if err := s.store.Update(webhook); err != nil {
+		return nil, types.NewInternalError("Failed to update webhook: " + err.Error())
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// Delete removes a webhook of the organization and its deliveries
+func (s *WebhookService) Delete(ctx context.Context, orgID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return types.NewNotFoundError("Webhook not found")
+	}
+	deleted, err := s.store.Delete(orgID, id)
+	if err != nil {
+		return types.NewInternalError("Failed to delete webhook: " + err.Error())
+	}
+	if !deleted {
+		return types.NewNotFoundError("Webhook not found")
+	}
+	return nil
+}
+
+// RotateSecret replaces the signing secret of a webhook of the
+// organization. The response carries the new secret, which is not shown
+// again; deliveries not yet posted are signed with it.
+func (s *WebhookService) RotateSecret(ctx context.Context, orgID, id string) (*types.Webhook, error) {
+	webhook, err := s.get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.Secret, err = newWebhookSecret(); err != nil {
+		return nil, types.NewInternalError("Failed to generate webhook secret: " + err.Error())
+	}
+	if err := s.store.Update(webhook); err != nil {
+		return nil, types.NewInternalError("Failed to rotate webhook secret: " + err.Error())
+	}
+	webhook.SigningKeyID = requestsig.NewHMACKey(webhook.ID, webhook.Secret).ID
+	return webhook, nil
+}
+
+// Test queues a webhook.ping event for a webhook of the organization,
+// whatever the event types it subscribes to
+func (s *WebhookService) Test(ctx context.Context, orgID, id string) (*types.WebhookDelivery, error) {
+	webhook, err := s.get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	event := s.newEvent(orgID, types.WebhookEventPing, map[string]interface{}{"webhook_id": webhook.ID})
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to encode webhook event: " + err.Error())
+	}
+	delivery, err := s.queue(webhook, event, payload)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to queue webhook delivery: " + err.Error())
+	}
+	return delivery, nil
+}
+
+// ListDeliveries returns a page of the deliveries of a webhook of the
+// organization, newest first
+func (s *WebhookService) ListDeliveries(ctx context.Context, orgID string, filter *types.WebhookDeliveryFilter) ([]*types.WebhookDelivery, error) {
+	if _, err := s.get(orgID, filter.WebhookID); err != nil {
+		return nil, err
+	}
+	switch filter.Status {
+	case "", types.WebhookDeliveryPending, types.WebhookDeliverySucceeded, types.WebhookDeliveryFailed:
+	default:
+		return nil, types.NewValidationError("status must be pending, succeeded or failed")
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultWebhookPageSize
+	}
+	filter.Limit = min(filter.Limit, maxWebhookPageSize)
+	filter.Offset = max(filter.Offset, 0)
+
+	deliveries, err := s.store.ListDeliveries(filter)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to list webhook deliveries: " + err.Error())
+	}
+	return deliveries, nil
+}
+
+// Redeliver queues the event of a past delivery again, as a new delivery
+// with fresh attempts
+func (s *WebhookService) Redeliver(ctx context.Context, orgID, webhookID, deliveryID string) (*types.WebhookDelivery, error) {
+	webhook, err := s.get(orgID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(deliveryID); err != nil {
+		return nil, types.NewNotFoundError("Webhook delivery not found")
+	}
+	previous, err := s.store.GetDelivery(orgID, webhookID, deliveryID)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get webhook delivery: " + err.Error())
+	}
+	if previous == nil {
+		return nil, types.NewNotFoundError("Webhook delivery not found")
+	}
+
+	delivery := &types.WebhookDelivery{
+		WebhookID:      webhook.ID,
+		OrganizationID: orgID,
+		EventID:        previous.EventID,
+		EventType:      previous.EventType,
+		Payload:        previous.Payload,
+	}
+	if err := s.store.CreateDelivery(delivery); err != nil {
+		return nil, types.NewInternalError("Failed to queue webhook delivery: " + err.Error())
+	}
+	return delivery, nil
+}
+
+// Emit queues an event for every active webhook of the organization
+// subscribed to its type
+func (s *WebhookService) Emit(ctx context.Context, orgID, eventType string, data map[string]interface{}) error {
+	webhooks, err := s.store.ListSubscribers(orgID, eventType)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	event := s.newEvent(orgID, eventType, data)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+	var failures []string
+	for _, webhook := range webhooks {
+		if _, err := s.queue(webhook, event, payload); err != nil {
+			failures = append(failures, webhook.ID+": "+err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to queue %s event for webhooks %s", eventType, strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// DeliverDue posts the deliveries that are due and returns how many it
+// attempted. Failed attempts are retried until the webhook's attempts run
+// out, except when the receiver refused the event with a 4xx other than
+// 408 or 429.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	// A claimed delivery is not retried before its request has timed out
+	lease := s.settings.Timeout + time.Minute
+	due, err := s.store.ClaimDue(webhookDeliveryBatch, lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	for _, delivery := range due {
+		s.deliver(ctx, delivery)
+	}
+	return len(due), nil
+}
+
+// Prune deletes finished deliveries older than retention
+func (s *WebhookService) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.store.PruneDeliveries(s.now().Add(-retention))
+}
+
+// deliver makes one attempt at a claimed delivery and records its outcome
+func (s *WebhookService) deliver(ctx context.Context, due *types.DueWebhookDelivery) {
+	delivery := &due.WebhookDelivery
+	delivery.ResponseStatus, delivery.LastError, delivery.DurationMs = 0, "", 0
+
+	retryable := true
+	if !due.WebhookActive {
+		delivery.LastError = "Webhook is disabled"
+		retryable = false
+	} else {
+		start := s.now()
+		status, err := s.post(ctx, due)
+		delivery.DurationMs = s.now().Sub(start).Milliseconds()
+		delivery.ResponseStatus = status
+		if err != nil {
+			delivery.LastError = err.Error()
+			retryable = status == 0 || status == http.StatusRequestTimeout ||
+				status == http.StatusTooManyRequests || status >= 500
+		}
+	}
+
+	var retryAfter time.Duration
+	switch {
+	case delivery.LastError == "":
+		delivery.Status = types.WebhookDeliverySucceeded
+	case retryable && delivery.Attempts < s.settings.MaxAttempts:
+		delivery.Status = types.WebhookDeliveryPending
+		retryAfter = min(s.settings.RetryBackoff<<(delivery.Attempts-1), maxWebhookRetryBackoff)
+	default:
+		delivery.Status = types.WebhookDeliveryFailed
+	}
+	if err := s.store.FinishDelivery(delivery, retryAfter); err != nil {
+		log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// post sends a delivery to its webhook, returning the response status, or
+// 0 when there was no response. Errors are shown to the organization, so
+// they carry the status but never the response body.
+func (s *WebhookService) post(ctx context.Context, due *types.DueWebhookDelivery) (int, error) {
+	if err := s.egress.CheckURL(types.ConnectivityFeatureWebhooks, due.URL); err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, due.URL, bytes.NewReader(due.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Omnimesh-Gateway")
+	req.Header.Set("X-Omnimesh-Event", due.EventType)
+	req.Header.Set("X-Omnimesh-Delivery", due.ID)
+	if err := requestsig.NewHMACKey(due.WebhookID, due.Secret).Sign(req, due.Payload, s.now()); err != nil {
+		return 0, err
+	}
+
+	resp, err := s.client.Do(req)
+	if errors.Is(err, airgap.ErrNonPublicAddress) {
+		return 0, errors.New("webhook URL does not resolve to a public address")
+	}
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *WebhookService) newEvent(orgID, eventType string, data map[string]interface{}) *types.WebhookEvent {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return &types.WebhookEvent{
+		ID:             uuid.NewString(),
+		Type:           eventType,
+		OrganizationID: orgID,
+		CreatedAt:      s.now().UTC(),
+		Data:           data,
+	}
+}
+
+func (s *WebhookService) queue(webhook *types.Webhook, event *types.WebhookEvent, payload []byte) (*types.WebhookDelivery, error) {
+	delivery := &types.WebhookDelivery{
+		WebhookID:      webhook.ID,
+		OrganizationID: webhook.OrganizationID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+	}
+	if err := s.store.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (s *WebhookService) get(orgID, id string) (*types.Webhook, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, types.NewNotFoundError("Webhook not found")
+	}
+	webhook, err := s.store.Get(orgID, id)
+	if err != nil {
+		return nil, types.NewInternalError("Failed to get webhook: " + err.Error())
+	}
+	if webhook == nil {
+		return nil, types.NewNotFoundError("Webhook not found")
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return types.NewValidationError("url must be an http or https URL")
+	}
+	if err := s.egress.CheckURL(types.ConnectivityFeatureWebhooks, rawURL); err != nil {
+		return err
+	}
+	return s.egress.CheckPublicURL(rawURL)
+}
+
+// normalizeWebhookEventTypes deduplicates event types, rejecting unknown
+// ones; "*" subscribes to every type
+func normalizeWebhookEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, types.NewValidationError("event_types must list at least one event type")
+	}
+	normalized := []string{}
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if eventType != types.WebhookEventAll && !slices.Contains(types.WebhookEventTypes, eventType) {
+			return nil, types.NewValidationError(fmt.Sprintf("unknown event type %q, expected \"*\" or one of %s",
+				eventType, strings.Join(types.WebhookEventTypes, ", ")))
+		}
+		if !slices.Contains(normalized, eventType) {
+			normalized = append(normalized, eventType)
+		}
+	}
+	return normalized, nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretRandomSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,247 @@
+// Package telemetry reports anonymous, aggregate usage of the gateway so
+// development can be prioritized. Reporting is opt-out and every payload can
+// be inspected locally before it is sent.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/buildinfo"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// SessionWindow is how far back session counts are aggregated
+const SessionWindow = 7 * 24 * time.Hour
+
+// Source aggregates usage counts
+type Source interface {
+	CollectUsage(ctx context.Context, since time.Time) (*types.TelemetryUsage, error)
+}
+
+// Reporter builds telemetry reports and sends them when reporting is enabled
+type Reporter struct {
+	source   Source
+	client   *http.Client
+	now      func() time.Time
+	endpoint string
+	disabled string
+	interval time.Duration
+}
+
+// NewReporter creates a reporter. Reporting is off when enabled is false, the
+// DO_NOT_TRACK environment variable is set, no endpoint is configured, or the
+// gateway is offline without a telemetry mirror. Reports can still be
+// previewed when reporting is off.
+func NewReporter(source Source, enabled bool, endpoint string, interval time.Duration, policy *airgap.Policy) *Reporter {
+	r := &Reporter{
+		source:   source,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		now:      time.Now,
+		interval: interval,
+	}
+
+	switch {
+	case !enabled:
+		r.disabled = "Disabled by telemetry.enabled"
+	case DoNotTrack(os.Getenv):
+		r.disabled = "Disabled by the DO_NOT_TRACK environment variable"
+	default:
+		resolved, err := policy.ResolveURL(types.ConnectivityFeatureTelemetry, endpoint)
+		switch {
+		case err != nil:
+			r.disabled = "Disabled in offline mode; set gateway.offline.mirrors.telemetry to report to a local collector"
+		case resolved == "":
+			r.disabled = "No telemetry endpoint configured"
+		case interval <= 0:
+			r.disabled = "Disabled by telemetry.interval"
+		default:
+			r.endpoint = resolved
+		}
+	}
+	return r
+}
+
+// DoNotTrack reports whether the DO_NOT_TRACK convention asks to opt out
+func DoNotTrack(getenv func(string) string) bool {
+	switch strings.ToLower(strings.TrimSpace(getenv("DO_NOT_TRACK"))) {
+	case "", "0", "false", "no":
+		return false
+	}
+	return true
+}
+
+// Enabled reports whether reports are sent
+func (r *Reporter) Enabled() bool {
+	return r.disabled == ""
+}
+
+// DisabledReason explains why reports are not sent
+func (r *Reporter) DisabledReason() string {
+	return r.disabled
+}
+
+// Interval is how often reports are sent
+func (r *Reporter) Interval() time.Duration {
+	return r.interval
+}
+
+// Report builds the payload that Send would post
+func (r *Reporter) Report(ctx context.Context) (*types.TelemetryReport, error) {
+	now := r.now()
+	usage, err := r.source.CollectUsage(ctx, now.Add(-SessionWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect usage: %w", err)
+	}
+	build := buildinfo.Get()
+	return &types.TelemetryReport{
+		GeneratedAt:    now.UTC().Truncate(time.Hour),
+		Version:        build.Version,
+		GoVersion:      build.GoVersion,
+		Platform:       build.Platform,
+		SessionWindow:  SessionWindow.String(),
+		TelemetryUsage: *usage,
+	}, nil
+}
+
+// Status describes whether reporting is on and what would be sent
+func (r *Reporter) Status(ctx context.Context) (*types.TelemetryStatus, error) {
+	report, err := r.Report(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &types.TelemetryStatus{
+		Enabled:  r.Enabled(),
+		Reason:   r.disabled,
+		Endpoint: r.endpoint,
+		Interval: r.interval.String(),
+		Report:   report,
+	}, nil
+}
+
+// Send posts a report to the telemetry endpoint. It does nothing when
+// reporting is off.
+func (r *Reporter) Send(ctx context.Context) error {
+	if !r.Enabled() {
+		return nil
+	}
+	report, err := r.Report(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "omnimesh-gateway/"+report.Version)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// UserRange buckets a user count so small deployments cannot be singled out
+func UserRange(users int) string {
+	switch {
+	case users <= 0:
+		return "0"
+	case users <= 10:
+		return "1-10"
+	case users <= 50:
+		return "11-50"
+	case users <= 250:
+		return "51-250"
+	case users <= 1000:
+		return "251-1000"
+	default:
+		return "1000+"
+	}
+}
+
+// DBSource aggregates usage from the gateway database
+type DBSource struct {
+	db *sql.DB
+}
+
+// NewDBSource creates a usage source over db
+func NewDBSource(db *sql.DB) *DBSource {
+	return &DBSource{db: db}
+}
+
+// CollectUsage counts active resources and the sessions started since since
+func (s *DBSource) CollectUsage(ctx context.Context, since time.Time) (*types.TelemetryUsage, error) {
+	usage := &types.TelemetryUsage{}
+	var users int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM namespaces WHERE is_active = true),
+			(SELECT COUNT(*) FROM endpoints WHERE is_active = true),
+			(SELECT COUNT(*) FROM virtual_servers WHERE is_active = true),
+			(SELECT COUNT(*) FROM users WHERE is_active = true)
+	`).Scan(&usage.Namespaces, &usage.Endpoints, &usage.VirtualServers, &users)
+	if err != nil {
+		return nil, err
+	}
+	usage.UserRange = UserRange(users)
+
+	usage.ServersByProtocol, err = s.countByProtocol(ctx, `
+		SELECT protocol::text, COUNT(*) FROM mcp_servers
+		WHERE is_active = true
+		GROUP BY protocol
+	`)
+	if err != nil {
+		return nil, err
+	}
+	for _, count := range usage.ServersByProtocol {
+		usage.Servers += count
+	}
+
+	usage.SessionsByProtocol, err = s.countByProtocol(ctx, `
+		SELECT protocol::text, COUNT(*) FROM mcp_sessions
+		WHERE started_at >= $1
+		GROUP BY protocol
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (s *DBSource) countByProtocol(ctx context.Context, query string, args ...interface{}) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var protocol string
+		var count int
+		if err := rows.Scan(&protocol, &count); err != nil {
+			return nil, err
+		}
+		counts[protocol] = count
+	}
+	return counts, rows.Err()
+}
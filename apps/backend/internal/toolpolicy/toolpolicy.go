@@ -0,0 +1,317 @@
+// Package toolpolicy evaluates namespace tool calls against an
+// organization's tool policies. A policy matches a call by namespace, tool,
+// server, argument patterns and caller, and allows, denies or holds the
+// call for approval.
+package toolpolicy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/jsonpath"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// prefixSeparator joins a server name and a tool name in prefixed tool names
+const prefixSeparator = "__"
+
+// Call is a tool call about to run in a namespace
+type Call struct {
+	Arguments   map[string]interface{}
+	Principal   *types.Principal
+	NamespaceID string
+	ServerID    string
+	ServerName  string
+	// Tool is the prefixed tool name, e.g. "shell__exec"
+	Tool string
+}
+
+// Rule is a compiled tool policy
+type Rule struct {
+	conditions types.ToolPolicyConditions
+	actions    types.ToolPolicyActions
+	arguments  []argumentMatcher
+	id         string
+	name       string
+	priority   int
+}
+
+type argumentMatcher struct {
+	path    *jsonpath.Path
+	pattern *regexp.Regexp
+	source  types.ToolArgumentPattern
+}
+
+// Parse decodes the conditions and actions of a tool policy, rejecting
+// fields the engine does not know
+func Parse(conditions, actions map[string]interface{}) (types.ToolPolicyConditions, types.ToolPolicyActions, error) {
+	var c types.ToolPolicyConditions
+	var a types.ToolPolicyActions
+	if err := decodeStrict(conditions, &c); err != nil {
+		return c, a, fmt.Errorf("invalid conditions: %w", err)
+	}
+	if err := decodeStrict(actions, &a); err != nil {
+		return c, a, fmt.Errorf("invalid actions: %w", err)
+	}
+	return c, a, nil
+}
+
+// Validate checks that a tool policy's conditions and actions decode and
+// compile
+func Validate(conditions, actions map[string]interface{}) error {
+	_, err := Compile(&types.Policy{Type: types.PolicyTypeTool, Conditions: conditions, Actions: actions})
+	return err
+}
+
+// Compile parses a tool policy and compiles its patterns
+func Compile(policy *types.Policy) (*Rule, error) {
+	c, a, err := Parse(policy.Conditions, policy.Actions)
+	if err != nil {
+		return nil, err
+	}
+
+	switch a.Effect {
+	case types.ToolPolicyEffectAllow, types.ToolPolicyEffectDeny, types.ToolPolicyEffectRequireApproval:
+	default:
+		return nil, fmt.Errorf("invalid actions: effect must be one of %s, %s or %s",
+			types.ToolPolicyEffectAllow, types.ToolPolicyEffectDeny, types.ToolPolicyEffectRequireApproval)
+	}
+
+	if err := validateApproval(a); err != nil {
+		return nil, fmt.Errorf("invalid actions: %w", err)
+	}
+
+	for _, patterns := range [][]string{c.Tools, c.Servers} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid conditions: bad pattern %q", pattern)
+			}
+		}
+	}
+	if c.Callers != nil {
+		if err := c.Callers.Labels.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid conditions: %w", err)
+		}
+	}
+
+	rule := &Rule{
+		conditions: c,
+		actions:    a,
+		id:         policy.ID,
+		name:       policy.Name,
+		priority:   policy.Priority,
+	}
+	for _, arg := range c.Arguments {
+		p, err := jsonpath.Parse(arg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid conditions: argument path %q: %w", arg.Path, err)
+		}
+		re, err := regexp.Compile(arg.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid conditions: argument pattern %q: %w", arg.Pattern, err)
+		}
+		rule.arguments = append(rule.arguments, argumentMatcher{path: p, pattern: re, source: arg})
+	}
+	return rule, nil
+}
+
+// Evaluate decides a call against the rules. Higher priorities are
+// considered first and, between rules of equal priority, the more
+// restrictive effect wins; the first matching rule decides. A call no rule
+// matches is allowed. Trace reports every rule considered.
+func Evaluate(rules []*Rule, call *Call) *types.ToolPolicyDecision {
+	ordered := make([]*Rule, len(rules))
+	copy(ordered, rules)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].priority != ordered[j].priority {
+			return ordered[i].priority > ordered[j].priority
+		}
+		return restrictiveness(ordered[i].actions.Effect) > restrictiveness(ordered[j].actions.Effect)
+	})
+
+	decision := &types.ToolPolicyDecision{Effect: types.ToolPolicyEffectAllow}
+	decided := false
+	for _, rule := range ordered {
+		entry := types.ToolPolicyTraceEntry{
+			PolicyID:   rule.id,
+			PolicyName: rule.name,
+			Effect:     rule.actions.Effect,
+			Priority:   rule.priority,
+		}
+		if decided {
+			entry.Reason = "not evaluated: an earlier policy decided the call"
+		} else if reason := rule.mismatch(call); reason != "" {
+			entry.Reason = reason
+		} else {
+			entry.Matched = true
+			decided = true
+			decision.Effect = rule.actions.Effect
+			decision.PolicyID = rule.id
+			decision.PolicyName = rule.name
+			decision.Message = rule.actions.Message
+			decision.Approval = rule.actions.Approval
+		}
+		decision.Trace = append(decision.Trace, entry)
+	}
+	return decision
+}
+
+// validateApproval checks the approval settings of a require_approval
+// policy
+func validateApproval(a types.ToolPolicyActions) error {
+	if a.Approval == nil {
+		return nil
+	}
+	if a.Effect != types.ToolPolicyEffectRequireApproval {
+		return fmt.Errorf("approval is only used with effect %s", types.ToolPolicyEffectRequireApproval)
+	}
+	if a.Approval.TimeoutSeconds < 0 {
+		return fmt.Errorf("approval timeout_seconds cannot be negative")
+	}
+	for _, email := range a.Approval.Emails {
+		if !strings.Contains(email, "@") || strings.ContainsAny(email, " \r\n") {
+			return fmt.Errorf("approval email %q is not an email address", email)
+		}
+	}
+	if a.Approval.WebhookURL != "" {
+		u, err := url.Parse(a.Approval.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("approval webhook_url must be an http or https URL")
+		}
+	}
+	return nil
+}
+
+// restrictiveness orders effects so that deny outranks require_approval,
+// which outranks allow
+func restrictiveness(effect string) int {
+	switch effect {
+	case types.ToolPolicyEffectDeny:
+		return 2
+	case types.ToolPolicyEffectRequireApproval:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// mismatch returns the first condition the call fails, or "" when the rule
+// matches
+func (r *Rule) mismatch(call *Call) string {
+	c := r.conditions
+	if len(c.Namespaces) > 0 && !contains(c.Namespaces, call.NamespaceID) {
+		return "namespace does not match"
+	}
+	if len(c.Tools) > 0 && !matchAny(c.Tools, call.Tool, bareToolName(call.Tool)) {
+		return "tool does not match"
+	}
+	if len(c.Servers) > 0 && !matchAny(c.Servers, call.ServerName, call.ServerID) {
+		return "server does not match"
+	}
+	for _, arg := range r.arguments {
+		if !arg.matches(call.Arguments) {
+			return fmt.Sprintf("argument %s does not match %s", arg.source.Path, arg.source.Pattern)
+		}
+	}
+	if c.Callers != nil && !callerMatches(c.Callers, call.Principal) {
+		return "caller does not match"
+	}
+	return ""
+}
+
+func (m argumentMatcher) matches(args map[string]interface{}) bool {
+	doc := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		doc[k] = v
+	}
+	for _, value := range m.path.Select(doc) {
+		if m.pattern.MatchString(argumentString(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// argumentString returns strings as they are and other values as JSON
+func argumentString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func callerMatches(callers *types.ToolPolicyCallers, principal *types.Principal) bool {
+	if principal == nil {
+		principal = &types.Principal{Type: types.PrincipalTypeAnonymous}
+	}
+
+	identified := len(callers.Types) == 0 && len(callers.UserIDs) == 0 &&
+		len(callers.APIKeyIDs) == 0 && len(callers.ClientIDs) == 0
+	if !identified {
+		userID := principal.EffectiveUserID()
+		identified = contains(callers.Types, principal.Type) ||
+			(userID != "" && contains(callers.UserIDs, userID)) ||
+			(principal.APIKeyID != "" && contains(callers.APIKeyIDs, principal.APIKeyID)) ||
+			(principal.ClientID != "" && contains(callers.ClientIDs, principal.ClientID))
+	}
+	if !identified {
+		return false
+	}
+
+	for key, value := range callers.Labels {
+		if principal.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func bareToolName(tool string) string {
+	if i := strings.Index(tool, prefixSeparator); i >= 0 {
+		return tool[i+len(prefixSeparator):]
+	}
+	return tool
+}
+
+func matchAny(patterns []string, values ...string) bool {
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			if ok, _ := path.Match(pattern, value); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeStrict(in map[string]interface{}, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(out)
+}
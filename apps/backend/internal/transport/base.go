@@ -1,7 +1,10 @@
 package transport
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"reflect"
 	"sync"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
@@ -81,6 +84,10 @@ func ValidateMessage(message interface{}) error {
 	if message == nil {
 		return fmt.Errorf("message cannot be nil")
 	}
+	// A nil pointer of any message type is as empty as a nil interface
+	if v := reflect.ValueOf(message); v.Kind() == reflect.Ptr && v.IsNil() {
+		return fmt.Errorf("message cannot be nil")
+	}
 
 	// Check if it's an MCP message
 	if mcpMsg, ok := message.(*types.MCPMessage); ok {
@@ -232,3 +239,12 @@ func IsTransportRegistered(transportType types.TransportType) bool {
 	_, exists := transportRegistry[transportType]
 	return exists
 }
+
+// applyUpstreamHeaders copies the caller headers propagated to the upstream
+// server onto an outgoing request. Headers the transport sets afterwards,
+// such as trace context and the session ID, take precedence.
+func applyUpstreamHeaders(ctx context.Context, header http.Header) {
+	for name, values := range types.UpstreamHeadersFromContext(ctx) {
+		header[name] = append([]string(nil), values...)
+	}
+}
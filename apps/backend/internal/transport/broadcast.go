@@ -0,0 +1,109 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/eventbus"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// BroadcastTopic is the event bus topic carrying broadcasts between gateway
+// instances
+const BroadcastTopic = "transport.broadcast"
+
+// remoteBroadcastTimeout bounds delivery of a broadcast from another
+// instance to this instance's connections
+const remoteBroadcastTimeout = 5 * time.Second
+
+// broadcastEvent is a broadcast as published on the event bus
+type broadcastEvent struct {
+	Origin        string              `json:"origin"`
+	TransportType types.TransportType `json:"transport_type"`
+	Message       json.RawMessage     `json:"message"`
+}
+
+// SetEventBus shares broadcasts with other gateway instances on bus.
+// Without a bus, broadcasts only reach this instance's connections.
+func (m *Manager) SetEventBus(bus eventbus.Bus) error {
+	if err := bus.Subscribe(BroadcastTopic, m.receiveBroadcast); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.eventBus = bus
+	m.mu.Unlock()
+	return nil
+}
+
+// InstanceID identifies this manager on the event bus
+func (m *Manager) InstanceID() string {
+	return m.instanceID
+}
+
+// publishBroadcast hands a broadcast to the other instances
+func (m *Manager) publishBroadcast(ctx context.Context, transportType types.TransportType, message interface{}) error {
+	m.mu.RLock()
+	bus := m.eventBus
+	m.mu.RUnlock()
+	if bus == nil {
+		return nil
+	}
+
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode broadcast: %w", err)
+	}
+	payload, err := json.Marshal(&broadcastEvent{
+		Origin:        m.instanceID,
+		TransportType: transportType,
+		Message:       encoded,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode broadcast: %w", err)
+	}
+	return bus.Publish(ctx, BroadcastTopic, payload)
+}
+
+// receiveBroadcast delivers a broadcast published by another instance to
+// this instance's connections
+func (m *Manager) receiveBroadcast(payload []byte) {
+	var event broadcastEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		log.Printf("Event bus: dropping malformed broadcast: %v", err)
+		return
+	}
+	if event.Origin == m.instanceID {
+		return
+	}
+
+	message, err := decodeBroadcastMessage(event.TransportType, event.Message)
+	if err != nil {
+		log.Printf("Event bus: dropping %s broadcast: %v", event.TransportType, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteBroadcastTimeout)
+	defer cancel()
+	if err := m.broadcastLocal(ctx, event.TransportType, message); err != nil {
+		log.Printf("Event bus: %v", err)
+	}
+}
+
+// decodeBroadcastMessage restores a broadcast message to the type its
+// transport sends
+func decodeBroadcastMessage(transportType types.TransportType, data json.RawMessage) (interface{}, error) {
+	switch transportType {
+	case types.TransportTypeSSE:
+		event := &types.SSEEvent{}
+		return event, json.Unmarshal(data, event)
+	case types.TransportTypeWebSocket:
+		message := &types.WebSocketMessage{}
+		return message, json.Unmarshal(data, message)
+	default:
+		var message map[string]interface{}
+		return message, json.Unmarshal(data, &message)
+	}
+}
@@ -0,0 +1,470 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Upstream gRPC MCP servers implement a single bidirectional streaming
+// method carrying one JSON-RPC 2.0 message per frame:
+//
+//	syntax = "proto3";
+//	package omnimesh.mcp.v1;
+//
+//	// Frame is wire-compatible with google.protobuf.BytesValue
+//	message Frame { bytes payload = 1; }
+//
+//	service MCPTransport {
+//	  rpc Session(stream Frame) returns (stream Frame);
+//	}
+//
+// A stream is one MCP session. Servers may also implement grpc.health.v1
+// for the MCPTransport service, which health checks prefer.
+const (
+	GRPCServiceName   = "omnimesh.mcp.v1.MCPTransport"
+	grpcSessionMethod = "/" + GRPCServiceName + "/Session"
+
+	// grpcMaxFrameSize bounds a single JSON-RPC frame received from upstream
+	grpcMaxFrameSize = 32 << 20
+)
+
+// ErrGRPCNotServing is returned by CheckGRPCHealth when the server reports
+// that the MCP service is not serving
+var ErrGRPCNotServing = errors.New("gRPC server is not serving MCP")
+
+// GRPCStream is a session stream of JSON-RPC frames to an upstream MCP
+// server
+type GRPCStream struct {
+	conn   *grpc.ClientConn
+	stream grpc.ClientStream
+	cancel context.CancelFunc
+	sendMu sync.Mutex
+}
+
+// DialGRPCStream opens a session stream to target, which is host:port,
+// grpc://host:port for plaintext or grpcs://host:port for TLS. headers are
+// sent as request metadata. The stream outlives ctx until closed.
+func DialGRPCStream(ctx context.Context, target string, headers map[string]string) (*GRPCStream, error) {
+	conn, err := dialGRPC(target)
+	if err != nil {
+		return nil, err
+	}
+
+	streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	for key, value := range headers {
+		streamCtx = metadata.AppendToOutgoingContext(streamCtx, strings.ToLower(key), value)
+	}
+
+	// Stop waiting for the stream once the caller gives up
+	opened := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-opened:
+		}
+	}()
+
+	stream, err := conn.NewStream(streamCtx, &grpc.StreamDesc{
+		StreamName:    "Session",
+		ClientStreams: true,
+		ServerStreams: true,
+	}, grpcSessionMethod)
+	close(opened)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return nil, fmt.Errorf("failed to open gRPC session: %w", err)
+	}
+
+	return &GRPCStream{conn: conn, stream: stream, cancel: cancel}, nil
+}
+
+// Send writes one frame
+func (s *GRPCStream) Send(frame []byte) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.stream.SendMsg(wrapperspb.Bytes(frame))
+}
+
+// Recv blocks for the next frame
+func (s *GRPCStream) Recv() ([]byte, error) {
+	frame := &wrapperspb.BytesValue{}
+	if err := s.stream.RecvMsg(frame); err != nil {
+		return nil, err
+	}
+	return frame.Value, nil
+}
+
+// Close ends the session and the underlying connection
+func (s *GRPCStream) Close() error {
+	s.sendMu.Lock()
+	_ = s.stream.CloseSend()
+	s.sendMu.Unlock()
+	s.cancel()
+	return s.conn.Close()
+}
+
+// CheckGRPCHealth probes target with grpc.health.v1. Servers without the
+// health service are healthy once a connection is established.
+func CheckGRPCHealth(ctx context.Context, target string) error {
+	conn, err := dialGRPC(target)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: GRPCServiceName})
+	switch {
+	case err == nil:
+		if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+			return ErrGRPCNotServing
+		}
+		return nil
+	case status.Code(err) == codes.Unimplemented:
+		return waitForReady(ctx, conn)
+	case status.Code(err) == codes.NotFound:
+		// The health service does not know MCPTransport; fall back to the
+		// server's overall status
+		resp, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+			return ErrGRPCNotServing
+		}
+		return nil
+	default:
+		return err
+	}
+}
+
+// waitForReady waits until conn is connected or ctx is done
+func waitForReady(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+}
+
+// dialGRPC creates a client connection for a target URL
+func dialGRPC(target string) (*grpc.ClientConn, error) {
+	address, useTLS, err := parseGRPCTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := insecure.NewCredentials()
+	if useTLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.NewClient(address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(grpcMaxFrameSize)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gRPC target %q: %w", target, err)
+	}
+	return conn, nil
+}
+
+// parseGRPCTarget splits a server URL into a dial address and whether to
+// use TLS
+func parseGRPCTarget(target string) (string, bool, error) {
+	address, useTLS := target, false
+	switch {
+	case strings.HasPrefix(target, "grpcs://"):
+		address, useTLS = strings.TrimPrefix(target, "grpcs://"), true
+	case strings.HasPrefix(target, "grpc://"):
+		address = strings.TrimPrefix(target, "grpc://")
+	case strings.Contains(target, "://"):
+		return "", false, fmt.Errorf("gRPC server URL must use grpc:// or grpcs://, got %q", target)
+	}
+	address = strings.TrimSuffix(address, "/")
+	if address == "" {
+		return "", false, fmt.Errorf("gRPC server URL is required")
+	}
+	return address, useTLS, nil
+}
+
+// GRPCTransport implements the transport for upstream MCP servers that
+// speak gRPC, exchanging JSON-RPC frames over a session stream
+type GRPCTransport struct {
+	*BaseTransport
+	stream      *GRPCStream
+	responseMap map[string]chan *types.MCPMessage
+	incoming    chan *types.MCPMessage
+	done        chan struct{}
+	headers     map[string]string
+	url         string
+	timeout     time.Duration
+	mu          sync.RWMutex
+	closeOnce   sync.Once
+}
+
+// NewGRPCTransport creates a new gRPC transport instance
+func NewGRPCTransport(config map[string]interface{}) (types.Transport, error) {
+	transport := &GRPCTransport{
+		BaseTransport: NewBaseTransport(types.TransportTypeGRPC),
+		responseMap:   make(map[string]chan *types.MCPMessage),
+		incoming:      make(chan *types.MCPMessage, 100),
+		done:          make(chan struct{}),
+		timeout:       30 * time.Second,
+	}
+
+	if url, ok := config["url"].(string); ok && url != "" {
+		transport.url = url
+	} else {
+		return nil, fmt.Errorf("url is required for gRPC transport")
+	}
+	if _, _, err := parseGRPCTarget(transport.url); err != nil {
+		return nil, err
+	}
+
+	if timeout, ok := config["timeout"].(time.Duration); ok && timeout > 0 {
+		transport.timeout = timeout
+	}
+
+	if headers, ok := config["headers"].(map[string]string); ok {
+		transport.headers = headers
+	}
+
+	return transport, nil
+}
+
+// Connect opens the session stream to the upstream server
+func (g *GRPCTransport) Connect(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stream != nil {
+		return fmt.Errorf("gRPC transport already connected")
+	}
+
+	stream, err := DialGRPCStream(ctx, g.url, g.headers)
+	if err != nil {
+		return err
+	}
+	g.stream = stream
+	g.setConnected(true)
+
+	go g.readLoop(stream)
+	return nil
+}
+
+// Disconnect closes the session stream
+func (g *GRPCTransport) Disconnect(ctx context.Context) error {
+	g.setConnected(false)
+	g.closeOnce.Do(func() {
+		close(g.done)
+	})
+
+	g.mu.Lock()
+	stream := g.stream
+	g.mu.Unlock()
+	if stream != nil {
+		return stream.Close()
+	}
+	return nil
+}
+
+// SendMessage sends a message to the upstream server as a JSON-RPC frame
+func (g *GRPCTransport) SendMessage(ctx context.Context, message interface{}) error {
+	if !g.IsConnected() {
+		return fmt.Errorf("gRPC transport not connected")
+	}
+
+	mcpMessage, ok := message.(*types.MCPMessage)
+	if !ok {
+		return fmt.Errorf("unsupported message type for gRPC transport: %T", message)
+	}
+	frame, err := encodeJSONRPCFrame(mcpMessage)
+	if err != nil {
+		return err
+	}
+
+	g.mu.RLock()
+	stream := g.stream
+	g.mu.RUnlock()
+	if err := stream.Send(frame); err != nil {
+		return fmt.Errorf("failed to send gRPC frame: %w", err)
+	}
+	return nil
+}
+
+// ReceiveMessage returns the next message from the upstream server that is
+// not the response to a SendRequest call
+func (g *GRPCTransport) ReceiveMessage(ctx context.Context) (interface{}, error) {
+	if !g.IsConnected() {
+		return nil, fmt.Errorf("gRPC transport not connected")
+	}
+
+	select {
+	case message := <-g.incoming:
+		return message, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-g.done:
+		return nil, fmt.Errorf("gRPC transport closed")
+	case <-time.After(g.timeout):
+		return nil, fmt.Errorf("request timeout")
+	}
+}
+
+// SendRequest sends a request and waits for its response
+func (g *GRPCTransport) SendRequest(ctx context.Context, request *types.MCPMessage) (*types.MCPMessage, error) {
+	responseChan := make(chan *types.MCPMessage, 1)
+	g.mu.Lock()
+	g.responseMap[request.ID] = responseChan
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.responseMap, request.ID)
+		g.mu.Unlock()
+	}()
+
+	if err := g.SendMessage(ctx, request); err != nil {
+		return nil, err
+	}
+
+	select {
+	case response := <-responseChan:
+		return response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-g.done:
+		return nil, fmt.Errorf("gRPC transport closed")
+	case <-time.After(g.timeout):
+		return nil, fmt.Errorf("request timeout")
+	}
+}
+
+// readLoop routes frames from the upstream server to waiting requests or
+// the incoming queue until the stream ends
+func (g *GRPCTransport) readLoop(stream *GRPCStream) {
+	defer g.setConnected(false)
+
+	for {
+		frame, err := stream.Recv()
+		if err != nil {
+			return
+		}
+
+		message, err := decodeJSONRPCFrame(frame)
+		if err != nil {
+			continue
+		}
+
+		g.mu.RLock()
+		responseChan, waiting := g.responseMap[message.ID]
+		g.mu.RUnlock()
+		if waiting && message.Type != types.MCPMessageTypeRequest {
+			select {
+			case responseChan <- message:
+			default:
+			}
+			continue
+		}
+
+		select {
+		case g.incoming <- message:
+		case <-g.done:
+			return
+		default:
+			// Nobody is reading; drop rather than stall responses
+		}
+	}
+}
+
+// jsonRPCFrame is a JSON-RPC 2.0 message as exchanged with upstream servers
+type jsonRPCFrame struct {
+	JSONRPC string                 `json:"jsonrpc"`
+	ID      json.RawMessage        `json:"id,omitempty"`
+	Method  string                 `json:"method,omitempty"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+	Result  interface{}            `json:"result,omitempty"`
+	Error   *types.MCPError        `json:"error,omitempty"`
+}
+
+// encodeJSONRPCFrame converts an MCP message to a JSON-RPC 2.0 frame
+func encodeJSONRPCFrame(message *types.MCPMessage) ([]byte, error) {
+	frame := jsonRPCFrame{
+		JSONRPC: "2.0",
+		Method:  message.Method,
+		Params:  message.Params,
+		Result:  message.Result,
+		Error:   message.Error,
+	}
+	if message.Type != types.MCPMessageTypeNotification && message.ID != "" {
+		id, err := json.Marshal(message.ID)
+		if err != nil {
+			return nil, err
+		}
+		frame.ID = id
+	}
+	return json.Marshal(frame)
+}
+
+// decodeJSONRPCFrame converts a JSON-RPC 2.0 frame to an MCP message.
+// Numeric IDs are kept in their decimal form.
+func decodeJSONRPCFrame(data []byte) (*types.MCPMessage, error) {
+	var frame jsonRPCFrame
+	if err := json.Unmarshal(data, &frame); err != nil {
+		return nil, fmt.Errorf("invalid JSON-RPC frame: %w", err)
+	}
+
+	message := &types.MCPMessage{
+		Method:  frame.Method,
+		Params:  frame.Params,
+		Result:  frame.Result,
+		Error:   frame.Error,
+		Version: "2024-11-05",
+	}
+	if len(frame.ID) > 0 && string(frame.ID) != "null" {
+		var id string
+		if err := json.Unmarshal(frame.ID, &id); err != nil {
+			id = string(frame.ID)
+		}
+		message.ID = id
+	}
+
+	switch {
+	case frame.Method != "" && message.ID != "":
+		message.Type = types.MCPMessageTypeRequest
+	case frame.Method != "":
+		message.Type = types.MCPMessageTypeNotification
+	case frame.Error != nil:
+		message.Type = types.MCPMessageTypeError
+	default:
+		message.Type = types.MCPMessageTypeResponse
+	}
+	return message, nil
+}
+
+func init() {
+	RegisterTransport(types.TransportTypeGRPC, NewGRPCTransport)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/google/uuid"
@@ -130,6 +131,8 @@ func (j *JSONRPCTransport) SendMessage(ctx context.Context, message interface{})
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	applyUpstreamHeaders(ctx, req.Header)
+	observability.InjectHTTP(ctx, req.Header)
 
 	// Add session ID if available
 	if sessionID := j.GetSessionID(); sessionID != "" {
@@ -193,6 +196,8 @@ func (j *JSONRPCTransport) SendRequest(ctx context.Context, method string, param
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	applyUpstreamHeaders(ctx, req.Header)
+	observability.InjectHTTP(ctx, req.Header)
 
 	// Add session ID if available
 	if sessionID := j.GetSessionID(); sessionID != "" {
@@ -407,6 +412,8 @@ func (j *JSONRPCTransport) SendBatchRequest(ctx context.Context, requests []*JSO
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	applyUpstreamHeaders(ctx, req.Header)
+	observability.InjectHTTP(ctx, req.Header)
 
 	// Add session ID if available
 	if sessionID := j.GetSessionID(); sessionID != "" {
@@ -6,7 +6,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/commandpolicy"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/eventbus"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/scheduler"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/serverlogs"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
 )
 
 // Manager coordinates all transport implementations and sessions
@@ -16,6 +22,14 @@ type Manager struct {
 	transports     map[types.TransportType]types.Transport
 	connections    map[string]types.Transport
 	metrics        *TransportMetrics
+	commandPolicy  *commandpolicy.Policy
+	backpressure   *scheduler.Backpressure
+	priorities     scheduler.ClassResolver
+	serverLogs     *serverlogs.Capture
+	admissions     map[string]func()
+	draining       func() bool
+	eventBus       eventbus.Bus
+	instanceID     string
 	mu             sync.RWMutex
 }
 
@@ -37,7 +51,9 @@ func NewManager(config *types.TransportConfig) *Manager {
 		sessionManager: NewSessionManager(config),
 		transports:     make(map[types.TransportType]types.Transport),
 		connections:    make(map[string]types.Transport),
+		admissions:     make(map[string]func()),
 		metrics:        NewTransportMetrics(),
+		instanceID:     uuid.New().String(),
 	}
 }
 
@@ -53,6 +69,30 @@ func NewTransportMetrics() *TransportMetrics {
 	}
 }
 
+// SetCommandPolicy restricts which commands STDIO connections may launch
+func (m *Manager) SetCommandPolicy(policy *commandpolicy.Policy) {
+	m.commandPolicy = policy
+}
+
+// SetBackpressure admits stateful connections by the organization's priority
+// class, shedding lower plan tiers first as the manager fills up
+func (m *Manager) SetBackpressure(backpressure *scheduler.Backpressure, priorities scheduler.ClassResolver) {
+	m.backpressure = backpressure
+	m.priorities = priorities
+}
+
+// SetDrainCheck refuses new stateful sessions while draining reports true,
+// so clients reconnect through the load balancer to another node
+func (m *Manager) SetDrainCheck(draining func() bool) {
+	m.draining = draining
+}
+
+// SetServerLogs captures the output of STDIO servers launched for a
+// registered server
+func (m *Manager) SetServerLogs(capture *serverlogs.Capture) {
+	m.serverLogs = capture
+}
+
 // Initialize initializes the transport manager with enabled transports
 func (m *Manager) Initialize(ctx context.Context) error {
 	for _, transportType := range m.config.EnabledTransports {
@@ -75,13 +115,45 @@ func (m *Manager) CreateConnectionWithConfig(ctx context.Context, transportType
 		return nil, nil, fmt.Errorf("transport type %s is not enabled", transportType)
 	}
 
+	if transportType == types.TransportTypeSTDIO {
+		command, _ := customConfig["command"].(string)
+		args, _ := customConfig["args"].([]string)
+		subject := commandpolicy.Subject{
+			OrganizationID: orgID,
+			UserID:         userID,
+			ServerID:       serverID,
+			Stage:          commandpolicy.StageLaunch,
+		}
+		if err := m.commandPolicy.Enforce(ctx, subject, command, args); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Create session for stateful transports
 	var session *types.TransportSession
+	var release func()
 	var err error
 
 	if m.isStatefulTransport(transportType) {
+		if m.draining != nil && m.draining() {
+			return nil, nil, types.NewServiceUnavailableError("This gateway node is draining; reconnect to reach another node")
+		}
+		if m.backpressure != nil {
+			class := types.PriorityClassFree
+			if m.priorities != nil {
+				class = m.priorities.PriorityClass(ctx, orgID)
+			}
+			release, err = m.backpressure.Admit(class)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
 		session, err = m.sessionManager.CreateSession(ctx, userID, orgID, serverID, transportType)
 		if err != nil {
+			if release != nil {
+				release()
+			}
 			return nil, nil, fmt.Errorf("failed to create session: %w", err)
 		}
 	}
@@ -96,6 +168,8 @@ func (m *Manager) CreateConnectionWithConfig(ctx context.Context, transportType
 		"websocket_timeout":   m.config.WebSocketTimeout,
 		"streamable_stateful": m.config.StreamableStateful,
 		"stdio_timeout":       m.config.STDIOTimeout,
+
+		"websocket_require_subprotocol": m.config.WebSocketRequireSubprotocol,
 	}
 
 	// Merge custom configuration
@@ -103,11 +177,20 @@ func (m *Manager) CreateConnectionWithConfig(ctx context.Context, transportType
 		config[key] = value
 	}
 
+	if transportType == types.TransportTypeSTDIO {
+		if sink := m.serverLogs.Sink(serverID); sink != nil {
+			config["output_sink"] = sink
+		}
+	}
+
 	transport, err := CreateTransport(transportType, config)
 	if err != nil {
 		if session != nil {
 			m.sessionManager.CloseSession(session.ID)
 		}
+		if release != nil {
+			release()
+		}
 		return nil, nil, fmt.Errorf("failed to create transport: %w", err)
 	}
 
@@ -120,6 +203,9 @@ func (m *Manager) CreateConnectionWithConfig(ctx context.Context, transportType
 	if session != nil {
 		m.mu.Lock()
 		m.connections[session.ID] = transport
+		if release != nil {
+			m.admissions[session.ID] = release
+		}
 		m.mu.Unlock()
 	}
 
@@ -153,8 +239,14 @@ func (m *Manager) CloseConnection(sessionID string) error {
 	if exists {
 		delete(m.connections, sessionID)
 	}
+	release := m.admissions[sessionID]
+	delete(m.admissions, sessionID)
 	m.mu.Unlock()
 
+	if release != nil {
+		release()
+	}
+
 	if !exists {
 		return fmt.Errorf("connection not found for session %s", sessionID)
 	}
@@ -280,8 +372,21 @@ func (m *Manager) ReceiveMessage(ctx context.Context, sessionID string) (interfa
 	return message, err
 }
 
-// BroadcastMessage sends a message to all connections of a specific transport type
+// BroadcastMessage sends a message to all connections of a specific transport
+// type, on this instance and, through the event bus, on the others
 func (m *Manager) BroadcastMessage(ctx context.Context, transportType types.TransportType, message interface{}) error {
+	localErr := m.broadcastLocal(ctx, transportType, message)
+	if err := m.publishBroadcast(ctx, transportType, message); err != nil {
+		if localErr != nil {
+			return fmt.Errorf("%w; %v", localErr, err)
+		}
+		return err
+	}
+	return localErr
+}
+
+// broadcastLocal sends a message to the connections held by this instance
+func (m *Manager) broadcastLocal(ctx context.Context, transportType types.TransportType, message interface{}) error {
 	m.mu.RLock()
 	var connections []types.Transport
 	for _, transport := range m.connections {
@@ -332,7 +437,7 @@ func (m *Manager) GetMetrics() map[string]interface{} {
 
 	sessionMetrics := m.sessionManager.GetMetrics()
 
-	return map[string]interface{}{
+	metrics := map[string]interface{}{
 		"connections_total":  m.metrics.ConnectionsTotal,
 		"active_connections": m.metrics.ActiveConnections,
 		"messages_total":     m.metrics.MessagesTotal,
@@ -343,6 +448,15 @@ func (m *Manager) GetMetrics() map[string]interface{} {
 		"enabled_transports": m.config.EnabledTransports,
 		"max_connections":    m.config.MaxConnections,
 	}
+	if m.backpressure != nil {
+		metrics["priority_classes"] = m.backpressure.Stats()
+	}
+	return metrics
+}
+
+// SessionsByTransport returns how many active sessions each transport holds
+func (m *Manager) SessionsByTransport() map[string]int {
+	return m.sessionManager.CountByTransport()
 }
 
 // HealthCheck performs health checks on all active transports
@@ -383,13 +497,20 @@ func (m *Manager) Shutdown(ctx context.Context) error {
 	for k, v := range m.connections {
 		connections[k] = v
 	}
+	admissions := m.admissions
+	m.admissions = make(map[string]func())
 	m.mu.Unlock()
 
 	for sessionID, transport := range connections {
 		if err := transport.Disconnect(ctx); err != nil {
 			// Log error but continue
 		}
+		m.mu.Lock()
 		delete(m.connections, sessionID)
+		m.mu.Unlock()
+	}
+	for _, release := range admissions {
+		release()
 	}
 
 	// Shutdown session manager
@@ -411,7 +532,7 @@ func (m *Manager) isTransportEnabled(transportType types.TransportType) bool {
 // isStatefulTransport checks if a transport type requires session management
 func (m *Manager) isStatefulTransport(transportType types.TransportType) bool {
 	switch transportType {
-	case types.TransportTypeSSE, types.TransportTypeWebSocket, types.TransportTypeStreamable, types.TransportTypeSTDIO:
+	case types.TransportTypeSSE, types.TransportTypeWebSocket, types.TransportTypeStreamable, types.TransportTypeSTDIO, types.TransportTypeGRPC:
 		return true
 	case types.TransportTypeHTTP:
 		return false
@@ -420,6 +541,12 @@ func (m *Manager) isStatefulTransport(transportType types.TransportType) bool {
 	}
 }
 
+// WebSocketSubprotocolRequired reports whether /ws upgrades must negotiate
+// a subprotocol
+func (m *Manager) WebSocketSubprotocolRequired() bool {
+	return m.config.WebSocketRequireSubprotocol
+}
+
 // GetSupportedTransports returns all supported transport types
 func (m *Manager) GetSupportedTransports() []types.TransportType {
 	return []types.TransportType{
@@ -428,6 +555,7 @@ func (m *Manager) GetSupportedTransports() []types.TransportType {
 		types.TransportTypeWebSocket,
 		types.TransportTypeStreamable,
 		types.TransportTypeSTDIO,
+		types.TransportTypeGRPC,
 	}
 }
 
@@ -13,14 +13,21 @@ import (
 	"github.com/google/uuid"
 )
 
-// SessionManager manages transport sessions for stateful transports
+// SessionManager manages transport sessions for stateful transports.
+//
+// A session's event log starts with its connect event and ends with at most
+// one disconnect event; once a session is closed or has expired, it takes no
+// further events, touches or updates. Sessions are handed out as copies, so
+// callers never share state with the manager.
 type SessionManager struct {
-	sessions map[string]*types.TransportSession
-	events   map[string][]types.TransportEvent
-	config   *types.TransportConfig
-	cleanup  chan struct{}
-	done     chan struct{}
-	mu       sync.RWMutex
+	sessions     map[string]*types.TransportSession
+	events       map[string][]types.TransportEvent
+	config       *types.TransportConfig
+	cleanup      chan struct{}
+	done         chan struct{}
+	shutdownOnce sync.Once
+	mu           sync.RWMutex
+	shutdown     bool
 }
 
 // NewSessionManager creates a new session manager
@@ -41,6 +48,13 @@ func NewSessionManager(config *types.TransportConfig) *SessionManager {
 
 // CreateSession creates a new transport session
 func (sm *SessionManager) CreateSession(ctx context.Context, userID, orgID, serverID string, transportType types.TransportType) (*types.TransportSession, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.shutdown {
+		return nil, fmt.Errorf("session manager is shut down")
+	}
+
 	sessionID := uuid.New().String()
 	now := time.Now()
 
@@ -58,9 +72,6 @@ func (sm *SessionManager) CreateSession(ctx context.Context, userID, orgID, serv
 		EventStore:     make([]types.TransportEvent, 0),
 	}
 
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
 	sm.sessions[sessionID] = session
 	sm.events[sessionID] = make([]types.TransportEvent, 0)
 
@@ -72,7 +83,7 @@ func (sm *SessionManager) CreateSession(ctx context.Context, userID, orgID, serv
 		"server_id":       serverID,
 	})
 
-	return session, nil
+	return sm.copySessionLocked(session), nil
 }
 
 // GetSession retrieves a session by ID
@@ -90,21 +101,50 @@ func (sm *SessionManager) GetSession(sessionID string) (*types.TransportSession,
 		return nil, fmt.Errorf("session %s has expired", sessionID)
 	}
 
-	// Return a copy to avoid concurrent modification
+	sessionCopy := sm.copySessionLocked(session)
+	sessionCopy.EventStore = append([]types.TransportEvent(nil), sm.events[sessionID]...)
+
+	return sessionCopy, nil
+}
+
+// copySessionLocked returns a copy of a session that shares no maps or
+// slices with the manager, without its events
+func (sm *SessionManager) copySessionLocked(session *types.TransportSession) *types.TransportSession {
 	sessionCopy := *session
-	sessionCopy.EventStore = sm.events[sessionID]
+	sessionCopy.Metadata = make(map[string]interface{}, len(session.Metadata))
+	for key, value := range session.Metadata {
+		sessionCopy.Metadata[key] = value
+	}
+	sessionCopy.EventStore = nil
+	return &sessionCopy
+}
 
-	return &sessionCopy, nil
+// liveSessionLocked returns a session that can still change: it exists, is
+// not closed and has not expired
+func (sm *SessionManager) liveSessionLocked(sessionID string) (*types.TransportSession, error) {
+	session, exists := sm.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+	if session.Status == types.TransportSessionStatusClosed {
+		return nil, fmt.Errorf("session %s is closed", sessionID)
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, fmt.Errorf("session %s has expired", sessionID)
+	}
+	return session, nil
 }
 
-// UpdateSession updates an existing session
+// UpdateSession updates an existing session. Closed and expired sessions
+// cannot be updated, and a session is closed with CloseSession rather than
+// by setting its status, so that its disconnect is recorded.
 func (sm *SessionManager) UpdateSession(sessionID string, updates map[string]interface{}) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	session, exists := sm.sessions[sessionID]
-	if !exists {
-		return fmt.Errorf("session %s not found", sessionID)
+	session, err := sm.liveSessionLocked(sessionID)
+	if err != nil {
+		return err
 	}
 
 	// Update last activity
@@ -114,7 +154,7 @@ func (sm *SessionManager) UpdateSession(sessionID string, updates map[string]int
 	for key, value := range updates {
 		switch key {
 		case "status":
-			if status, ok := value.(string); ok {
+			if status, ok := value.(string); ok && status != types.TransportSessionStatusClosed {
 				session.Status = status
 			}
 		case "server_id":
@@ -134,7 +174,8 @@ func (sm *SessionManager) UpdateSession(sessionID string, updates map[string]int
 	return nil
 }
 
-// CloseSession closes a session and cleans up resources
+// CloseSession closes a session and cleans up resources. Closing a session
+// that is already closed does nothing.
 func (sm *SessionManager) CloseSession(sessionID string) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
@@ -143,14 +184,11 @@ func (sm *SessionManager) CloseSession(sessionID string) error {
 	if !exists {
 		return fmt.Errorf("session %s not found", sessionID)
 	}
+	if session.Status == types.TransportSessionStatusClosed {
+		return nil
+	}
 
-	session.Status = types.TransportSessionStatusClosed
-	session.LastActivity = time.Now()
-
-	// Add disconnect event
-	sm.addEventLocked(sessionID, types.TransportEventTypeDisconnect, map[string]interface{}{
-		"reason": "manual_close",
-	})
+	sm.closeSessionLocked(sessionID, "manual_close")
 
 	// Remove from active sessions after a delay to allow for final event processing
 	go func() {
@@ -164,11 +202,22 @@ func (sm *SessionManager) CloseSession(sessionID string) error {
 	return nil
 }
 
-// AddEvent adds an event to a session's event store
+// closeSessionLocked marks a session closed and records its disconnect
+func (sm *SessionManager) closeSessionLocked(sessionID, reason string) {
+	sm.sessions[sessionID].Status = types.TransportSessionStatusClosed
+	sm.addEventLocked(sessionID, types.TransportEventTypeDisconnect, map[string]interface{}{
+		"reason": reason,
+	})
+}
+
+// AddEvent adds an event to the event store of a live session
 func (sm *SessionManager) AddEvent(sessionID string, eventType string, data map[string]interface{}) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
+	if _, err := sm.liveSessionLocked(sessionID); err != nil {
+		return err
+	}
 	return sm.addEventLocked(sessionID, eventType, data)
 }
 
@@ -229,8 +278,7 @@ func (sm *SessionManager) GetActiveSessions() []*types.TransportSession {
 	var sessions []*types.TransportSession
 	for _, session := range sm.sessions {
 		if session.Status == types.TransportSessionStatusActive && time.Now().Before(session.ExpiresAt) {
-			sessionCopy := *session
-			sessions = append(sessions, &sessionCopy)
+			sessions = append(sessions, sm.copySessionLocked(session))
 		}
 	}
 
@@ -245,8 +293,7 @@ func (sm *SessionManager) GetSessionsByUser(userID string) []*types.TransportSes
 	var sessions []*types.TransportSession
 	for _, session := range sm.sessions {
 		if session.UserID == userID {
-			sessionCopy := *session
-			sessions = append(sessions, &sessionCopy)
+			sessions = append(sessions, sm.copySessionLocked(session))
 		}
 	}
 
@@ -261,22 +308,22 @@ func (sm *SessionManager) GetSessionsByTransport(transportType types.TransportTy
 	var sessions []*types.TransportSession
 	for _, session := range sm.sessions {
 		if session.TransportType == transportType {
-			sessionCopy := *session
-			sessions = append(sessions, &sessionCopy)
+			sessions = append(sessions, sm.copySessionLocked(session))
 		}
 	}
 
 	return sessions
 }
 
-// TouchSession updates the last activity time for a session
+// TouchSession updates the last activity time for a live session. A session
+// that has already expired is not revived.
 func (sm *SessionManager) TouchSession(sessionID string) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	session, exists := sm.sessions[sessionID]
-	if !exists {
-		return fmt.Errorf("session %s not found", sessionID)
+	session, err := sm.liveSessionLocked(sessionID)
+	if err != nil {
+		return err
 	}
 
 	session.LastActivity = time.Now()
@@ -323,27 +370,25 @@ func (sm *SessionManager) cleanupExpiredSessions() {
 	}
 
 	for _, sessionID := range expiredSessions {
-		// Add expiration event before cleanup
-		sm.addEventLocked(sessionID, types.TransportEventTypeDisconnect, map[string]interface{}{
-			"reason": "expired",
-		})
 		delete(sm.sessions, sessionID)
 		delete(sm.events, sessionID)
 	}
 }
 
-// Shutdown gracefully shuts down the session manager
+// Shutdown gracefully shuts down the session manager, closing the sessions
+// still open. No sessions can be created afterwards; shutting down again
+// does nothing.
 func (sm *SessionManager) Shutdown(ctx context.Context) error {
-	close(sm.done)
+	sm.shutdownOnce.Do(func() { close(sm.done) })
 
-	// Close all active sessions
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	for sessionID := range sm.sessions {
-		sm.addEventLocked(sessionID, types.TransportEventTypeDisconnect, map[string]interface{}{
-			"reason": "shutdown",
-		})
+	sm.shutdown = true
+	for sessionID, session := range sm.sessions {
+		if session.Status != types.TransportSessionStatusClosed {
+			sm.closeSessionLocked(sessionID, "shutdown")
+		}
 	}
 
 	return nil
@@ -378,6 +423,22 @@ func (sm *SessionManager) GetMetrics() map[string]interface{} {
 	return metrics
 }
 
+// CountByTransport returns how many active, unexpired sessions each
+// transport holds
+func (sm *SessionManager) CountByTransport() map[string]int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	now := time.Now()
+	counts := make(map[string]int)
+	for _, session := range sm.sessions {
+		if session.Status == types.TransportSessionStatusActive && now.Before(session.ExpiresAt) {
+			counts[string(session.TransportType)]++
+		}
+	}
+	return counts
+}
+
 // Implement database storage helpers for session persistence
 
 // MetadataValue is a helper type for database storage
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,6 +27,7 @@ type STDIOTransport struct {
 	messageQueue chan *types.MCPMessage
 	*BaseTransport
 	config      map[string]interface{}
+	output      func(stream, line string)
 	done        chan struct{}
 	command     string
 	workingDir  string
@@ -67,6 +69,11 @@ func NewSTDIOTransport(config map[string]interface{}) (types.Transport, error) {
 		transport.workingDir = workingDir
 	}
 
+	// output_sink receives stderr and stray stdout lines for server log capture
+	if output, ok := config["output_sink"].(func(stream, line string)); ok {
+		transport.output = output
+	}
+
 	if env, ok := config["env"].(map[string]string); ok {
 		for key, value := range env {
 			transport.env = append(transport.env, fmt.Sprintf("%s=%s", key, value))
@@ -326,9 +333,9 @@ func (s *STDIOTransport) processMonitor() {
 	// If we're not already disconnecting, handle unexpected exit
 	if s.IsConnected() {
 		s.setConnected(false)
-		// Log process exit with error if any
-		if err != nil {
-			// Handle process exit error
+		// Record unexpected exits alongside the server's own output
+		if err != nil && s.output != nil {
+			s.output(types.ServerLogStreamStderr, "[gateway] process exited: "+err.Error())
 		}
 	}
 }
@@ -339,6 +346,9 @@ func (s *STDIOTransport) handleOutputLine(line string) {
 	var mcpMessage types.MCPMessage
 	if err := json.Unmarshal([]byte(line), &mcpMessage); err != nil {
 		// Not a valid JSON message, might be plain text output
+		if s.output != nil && strings.TrimSpace(line) != "" {
+			s.output(types.ServerLogStreamStdout, line)
+		}
 		return
 	}
 
@@ -363,8 +373,9 @@ func (s *STDIOTransport) handleOutputLine(line string) {
 
 // handleErrorLine processes a line from stderr
 func (s *STDIOTransport) handleErrorLine(line string) {
-	// Log or handle stderr output
-	// This could be error messages, debug output, etc.
+	if s.output != nil {
+		s.output(types.ServerLogStreamStderr, line)
+	}
 }
 
 // handleNotification handles notification messages
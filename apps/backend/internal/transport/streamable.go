@@ -1,9 +1,11 @@
 package transport
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +14,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
 	"github.com/google/uuid"
@@ -248,6 +251,8 @@ func (s *StreamableHTTPTransport) sendJSONRequest(ctx context.Context, request *
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/json")
+	applyUpstreamHeaders(ctx, httpReq.Header)
+	observability.InjectHTTP(ctx, httpReq.Header)
 
 	if s.stateful && s.GetSessionID() != "" {
 		httpReq.Header.Set("X-Session-ID", s.GetSessionID())
@@ -318,6 +323,8 @@ func (s *StreamableHTTPTransport) sendSSERequest(ctx context.Context, request *S
 	// Set headers for SSE
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "text/event-stream")
+	applyUpstreamHeaders(ctx, httpReq.Header)
+	observability.InjectHTTP(ctx, httpReq.Header)
 	httpReq.Header.Set("Cache-Control", "no-cache")
 
 	if s.stateful && s.GetSessionID() != "" {
@@ -335,53 +342,93 @@ func (s *StreamableHTTPTransport) sendSSERequest(ctx context.Context, request *S
 	return s.handleSSEStream(ctx, resp.Body)
 }
 
-// handleSSEStream processes incoming SSE events
+// maxSSEEventSize bounds the bytes buffered for a single SSE event, so that a
+// stream that never ends an event cannot exhaust memory
+const maxSSEEventSize = 1 << 20
+
+// handleSSEStream processes incoming SSE events. Events are dispatched at the
+// blank line that ends them, so events split across reads are parsed whole.
 func (s *StreamableHTTPTransport) handleSSEStream(ctx context.Context, reader io.Reader) error {
-	buffer := make([]byte, 4096)
+	buffered := bufio.NewReader(reader)
+	var block strings.Builder
+
+	dispatch := func() {
+		if block.Len() == 0 {
+			return
+		}
+		events := s.parseSSEData(block.String())
+		block.Reset()
+
+		// Add events to store for stateful mode
+		if s.stateful {
+			for _, event := range events {
+				s.addEvent(event)
+			}
+		}
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			n, err := reader.Read(buffer)
-			if err != nil {
-				if err == io.EOF {
-					return nil
-				}
-				return fmt.Errorf("failed to read SSE stream: %w", err)
-			}
+		}
 
-			// Parse SSE data
-			data := string(buffer[:n])
-			events := s.parseSSEData(data)
+		line, err := buffered.ReadString('\n')
+		if block.Len()+len(line) > maxSSEEventSize {
+			return fmt.Errorf("SSE event exceeds %d bytes", maxSSEEventSize)
+		}
+		block.WriteString(line)
+		if strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r") == "" && line != "" {
+			dispatch()
+		}
 
-			// Add events to store for stateful mode
-			if s.stateful {
-				for _, event := range events {
-					s.addEvent(event)
-				}
+		if err != nil {
+			if err == io.EOF {
+				dispatch()
+				return nil
 			}
+			return fmt.Errorf("failed to read SSE stream: %w", err)
 		}
 	}
 }
 
-// parseSSEData parses SSE formatted data into events
+// parseSSEData parses SSE formatted data into events. Lines of an event are
+// interpreted as described by the HTML event stream format: data lines are
+// joined with newlines, a single space after the colon is dropped and a line
+// without a colon is a field with an empty value.
 func (s *StreamableHTTPTransport) parseSSEData(data string) []*types.TransportEvent {
 	var events []*types.TransportEvent
-	lines := strings.Split(data, "\n")
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
 
 	var currentEvent *types.TransportEvent
+	var dataLines []string
+
+	finish := func() {
+		if currentEvent == nil {
+			return
+		}
+		if len(dataLines) > 0 {
+			value := strings.Join(dataLines, "\n")
+			// Try to parse as JSON
+			var jsonData interface{}
+			if err := json.Unmarshal([]byte(value), &jsonData); err == nil {
+				currentEvent.Data["parsed_data"] = jsonData
+			} else {
+				currentEvent.Data["raw_data"] = value
+			}
+		}
+		events = append(events, currentEvent)
+		currentEvent = nil
+		dataLines = nil
+	}
 
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
+		line = strings.TrimSuffix(line, "\r")
 
 		if line == "" {
 			// End of event
-			if currentEvent != nil {
-				events = append(events, currentEvent)
-				currentEvent = nil
-			}
+			finish()
 			continue
 		}
 
@@ -390,13 +437,8 @@ func (s *StreamableHTTPTransport) parseSSEData(data string) []*types.TransportEv
 			continue
 		}
 
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) != 2 {
-			continue
-		}
-
-		field := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
 
 		if currentEvent == nil {
 			currentEvent = &types.TransportEvent{
@@ -409,28 +451,28 @@ func (s *StreamableHTTPTransport) parseSSEData(data string) []*types.TransportEv
 
 		switch field {
 		case "id":
-			currentEvent.ID = value
+			// IDs containing NUL are ignored
+			if !strings.ContainsRune(value, 0) {
+				currentEvent.ID = value
+			}
 		case "event":
-			currentEvent.Type = value
-		case "data":
-			// Try to parse as JSON
-			var jsonData interface{}
-			if err := json.Unmarshal([]byte(value), &jsonData); err == nil {
-				currentEvent.Data["parsed_data"] = jsonData
-			} else {
-				currentEvent.Data["raw_data"] = value
+			if value != "" {
+				currentEvent.Type = value
 			}
+		case "data":
+			dataLines = append(dataLines, value)
 		case "retry":
-			if retryTime, err := strconv.Atoi(value); err == nil {
-				currentEvent.Data["retry"] = retryTime
+			// Only plain digits are a valid reconnection time
+			if value != "" && strings.Trim(value, "0123456789") == "" {
+				if retryTime, err := strconv.Atoi(value); err == nil {
+					currentEvent.Data["retry"] = retryTime
+				}
 			}
 		}
 	}
 
 	// Add final event if exists
-	if currentEvent != nil {
-		events = append(events, currentEvent)
-	}
+	finish()
 
 	return events
 }
@@ -464,22 +506,51 @@ func (s *StreamableHTTPTransport) SendMCPRequest(ctx context.Context, mcpMessage
 	return response, nil
 }
 
+// ErrInvalidStreamableRequest is returned for messages that cannot be sent as
+// a streamable request
+var ErrInvalidStreamableRequest = errors.New("invalid streamable request")
+
 // convertToStreamableRequest converts various message types to streamable request
 func (s *StreamableHTTPTransport) convertToStreamableRequest(message interface{}) (*StreamableRequest, error) {
 	switch msg := message.(type) {
+	case nil:
+		return nil, fmt.Errorf("%w: message is nil", ErrInvalidStreamableRequest)
 	case *StreamableRequest:
+		if msg == nil {
+			return nil, fmt.Errorf("%w: message is nil", ErrInvalidStreamableRequest)
+		}
 		return msg, nil
 	case *types.StreamableHTTPRequest:
+		if msg == nil {
+			return nil, fmt.Errorf("%w: message is nil", ErrInvalidStreamableRequest)
+		}
+		method := strings.ToUpper(msg.Method)
+		if method == "" {
+			method = "POST"
+		}
+		if method != "GET" && method != "POST" && method != "DELETE" {
+			return nil, fmt.Errorf("%w: unsupported method %q", ErrInvalidStreamableRequest, msg.Method)
+		}
+		mode := msg.StreamMode
+		if mode == "" {
+			mode = s.streamMode
+		}
+		if mode != types.StreamableModeJSON && mode != types.StreamableModeSSE {
+			return nil, fmt.Errorf("%w: unsupported stream mode %q", ErrInvalidStreamableRequest, msg.StreamMode)
+		}
 		return &StreamableRequest{
-			Method:    msg.Method,
+			Method:    method,
 			Headers:   msg.Headers,
 			Body:      msg.Body,
 			Stateful:  msg.Stateful,
 			SessionID: msg.SessionID,
-			Mode:      msg.StreamMode,
+			Mode:      mode,
 			Metadata:  msg.Metadata,
 		}, nil
 	case *types.MCPMessage:
+		if msg == nil {
+			return nil, fmt.Errorf("%w: message is nil", ErrInvalidStreamableRequest)
+		}
 		return &StreamableRequest{
 			Method:    "POST",
 			Body:      msg,
@@ -491,6 +562,9 @@ func (s *StreamableHTTPTransport) convertToStreamableRequest(message interface{}
 			},
 		}, nil
 	case *types.TransportRequest:
+		if msg == nil {
+			return nil, fmt.Errorf("%w: message is nil", ErrInvalidStreamableRequest)
+		}
 		return &StreamableRequest{
 			Method:    msg.Method,
 			Body:      msg.Body,
@@ -0,0 +1,171 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFuzzStreamableTransport(t testing.TB) *StreamableHTTPTransport {
+	transport, err := NewStreamableHTTPTransport(map[string]interface{}{})
+	require.NoError(t, err)
+	s := transport.(*StreamableHTTPTransport)
+	s.SetSessionID("fuzz-session")
+	return s
+}
+
+// chunkedReader returns at most size bytes per read, to split events at
+// arbitrary points like a network stream does
+type chunkedReader struct {
+	data []byte
+	size int
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	n := r.size
+	if n > len(p) {
+		n = len(p)
+	}
+	if n > len(r.data) {
+		n = len(r.data)
+	}
+	copy(p, r.data[:n])
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestParseSSEData(t *testing.T) {
+	s := newFuzzStreamableTransport(t)
+
+	events := s.parseSSEData("id: 7\nevent: progress\ndata: {\"a\":\ndata: 1}\nretry: 250\n\n: comment\ndata:  two spaces\r\n\r\n")
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "7", events[0].ID)
+	assert.Equal(t, "progress", events[0].Type)
+	assert.Equal(t, "fuzz-session", events[0].SessionID)
+	assert.Equal(t, map[string]interface{}{"a": float64(1)}, events[0].Data["parsed_data"])
+	assert.Equal(t, 250, events[0].Data["retry"])
+
+	assert.Equal(t, types.TransportEventTypeMessage, events[1].Type)
+	assert.Equal(t, " two spaces", events[1].Data["raw_data"])
+
+	events = s.parseSSEData("retry: -5\nid: a\x00b\ndata\n")
+	require.Len(t, events, 1)
+	assert.NotContains(t, events[0].Data, "retry")
+	assert.Empty(t, events[0].ID)
+	assert.Equal(t, "", events[0].Data["raw_data"])
+}
+
+func TestHandleSSEStreamSplitEvents(t *testing.T) {
+	s := newFuzzStreamableTransport(t)
+	stream := "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{}}\n\nevent: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":2,\"result\":{}}\n\n"
+
+	require.NoError(t, s.handleSSEStream(context.Background(), &chunkedReader{data: []byte(stream), size: 3}))
+
+	events := s.GetLatestEvents(0)
+	require.Len(t, events, 2)
+	for i, event := range events {
+		parsed, ok := event.Data["parsed_data"].(map[string]interface{})
+		require.True(t, ok, "event %d was not parsed whole: %v", i, event.Data)
+		assert.Equal(t, float64(i+1), parsed["id"])
+	}
+}
+
+func FuzzParseSSEData(f *testing.F) {
+	f.Add("event: message\ndata: {\"jsonrpc\":\"2.0\"}\n\n", uint8(4))
+	f.Add("id: 1\r\ndata: a\r\ndata: b\r\n\r\n", uint8(1))
+	f.Add(": keepalive\n\nretry: 1000\n\n", uint8(7))
+	f.Add("data\nevent\nid\n\n\r\r\r\n", uint8(2))
+	f.Add("data: {\"unterminated\"", uint8(0))
+
+	f.Fuzz(func(t *testing.T, data string, chunk uint8) {
+		whole := newFuzzStreamableTransport(t).parseSSEData(data)
+		for _, event := range whole {
+			require.NotNil(t, event.Data)
+			require.NotEmpty(t, event.Type)
+			if _, err := json.Marshal(event); err != nil {
+				t.Fatalf("event cannot be encoded: %v", err)
+			}
+		}
+
+		// Reading the same data as a stream split at any point yields the
+		// same events
+		s := newFuzzStreamableTransport(t)
+		reader := &chunkedReader{data: []byte(data), size: int(chunk) + 1}
+		require.NoError(t, s.handleSSEStream(context.Background(), reader))
+
+		streamed := s.GetLatestEvents(0)
+		if len(whole) > len(streamed) {
+			whole = whole[len(whole)-len(streamed):]
+		}
+		require.Len(t, streamed, len(whole))
+		for i := range whole {
+			assert.Equal(t, whole[i].ID, streamed[i].ID)
+			assert.Equal(t, whole[i].Type, streamed[i].Type)
+			assert.Equal(t, whole[i].Data, streamed[i].Data)
+		}
+	})
+}
+
+func TestConvertToStreamableRequestRejectsNil(t *testing.T) {
+	s := newFuzzStreamableTransport(t)
+
+	for _, message := range []interface{}{
+		nil,
+		(*StreamableRequest)(nil),
+		(*types.StreamableHTTPRequest)(nil),
+		(*types.MCPMessage)(nil),
+		(*types.TransportRequest)(nil),
+	} {
+		_, err := s.convertToStreamableRequest(message)
+		assert.ErrorIs(t, err, ErrInvalidStreamableRequest, "%T", message)
+		assert.Error(t, ValidateMessage(message), "%T", message)
+	}
+
+	_, err := s.convertToStreamableRequest(&types.StreamableHTTPRequest{Method: "POST", StreamMode: "carrier-pigeon"})
+	assert.ErrorIs(t, err, ErrInvalidStreamableRequest)
+}
+
+func FuzzConvertToStreamableRequest(f *testing.F) {
+	f.Add([]byte(`{"method":"POST","path":"/mcp","stream_mode":"sse","body":{"jsonrpc":"2.0","id":1,"method":"tools/list"}}`))
+	f.Add([]byte(`{"method":"get","headers":{"Accept":"text/event-stream"},"stateful":true}`))
+	f.Add([]byte(`{"method":"PATCH","stream_mode":"json"}`))
+	f.Add([]byte(`{"body":null,"metadata":{"a":[1,2,{}]}}`))
+	f.Add([]byte(`null`))
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		var req *types.StreamableHTTPRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return
+		}
+
+		s := newFuzzStreamableTransport(t)
+		if ValidateMessage(req) != nil {
+			return
+		}
+
+		converted, err := s.convertToStreamableRequest(req)
+		if err != nil {
+			require.ErrorIs(t, err, ErrInvalidStreamableRequest)
+			return
+		}
+		require.NotNil(t, converted)
+		assert.Contains(t, []string{"GET", "POST", "DELETE"}, converted.Method)
+		assert.Contains(t, []string{types.StreamableModeJSON, types.StreamableModeSSE}, converted.Mode)
+
+		require.NoError(t, s.Connect(context.Background()))
+		require.NoError(t, s.SendMessage(context.Background(), req))
+		if _, err := json.Marshal(s.GetLatestEvents(0)); err != nil {
+			t.Fatalf("stored events cannot be encoded: %v", err)
+		}
+	})
+}
@@ -26,6 +26,7 @@ type WebSocketTransport struct {
 	upgrader     websocket.Upgrader
 	timeout      time.Duration
 	bufferSize   int
+	requireProto bool
 	mu           sync.RWMutex
 }
 
@@ -40,6 +41,7 @@ func NewWebSocketTransport(config map[string]interface{}) (types.Transport, erro
 			},
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
+			Subprotocols:    WebSocketSubprotocols,
 		},
 		messageQueue: make(chan *types.WebSocketMessage, 100),
 		responseMap:  make(map[string]chan *types.MCPMessage),
@@ -54,6 +56,10 @@ func NewWebSocketTransport(config map[string]interface{}) (types.Transport, erro
 		transport.timeout = timeout
 	}
 
+	if required, ok := config["websocket_require_subprotocol"].(bool); ok {
+		transport.requireProto = required
+	}
+
 	if bufferSize, ok := config["buffer_size"].(int); ok {
 		transport.bufferSize = bufferSize
 		transport.upgrader.ReadBufferSize = bufferSize
@@ -64,8 +70,14 @@ func NewWebSocketTransport(config map[string]interface{}) (types.Transport, erro
 	return transport, nil
 }
 
-// UpgradeHTTP upgrades an HTTP connection to WebSocket
+// UpgradeHTTP upgrades an HTTP connection to WebSocket. Handshakes that
+// offer only unsupported subprotocols fail with a *types.Error before any
+// response is written.
 func (w *WebSocketTransport) UpgradeHTTP(writer http.ResponseWriter, request *http.Request) error {
+	if _, err := NegotiateSubprotocol(request, w.requireProto); err != nil {
+		return err
+	}
+
 	conn, err := w.upgrader.Upgrade(writer, request, nil)
 	if err != nil {
 		return fmt.Errorf("failed to upgrade to WebSocket: %w", err)
@@ -94,6 +106,16 @@ func (w *WebSocketTransport) UpgradeHTTP(writer http.ResponseWriter, request *ht
 	return nil
 }
 
+// Subprotocol returns the negotiated Sec-WebSocket-Protocol, if any
+func (w *WebSocketTransport) Subprotocol() string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.conn == nil {
+		return ""
+	}
+	return w.conn.Subprotocol()
+}
+
 // Connect establishes WebSocket connection
 func (w *WebSocketTransport) Connect(ctx context.Context) error {
 	if w.conn == nil {
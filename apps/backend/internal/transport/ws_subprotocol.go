@@ -0,0 +1,42 @@
+package transport
+
+import (
+	"net/http"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketProtocolsHeader advertises the supported subprotocols when a
+// handshake is rejected
+const WebSocketProtocolsHeader = "X-MCP-WebSocket-Protocols"
+
+// WebSocketSubprotocols are the MCP WebSocket subprotocols in server
+// preference order. "mcp" is the unversioned name used by the MCP SDK
+// WebSocket transports.
+var WebSocketSubprotocols = []string{"mcp.2024-11-05", "mcp"}
+
+// NegotiateSubprotocol selects the subprotocol for a WebSocket upgrade from
+// the client's Sec-WebSocket-Protocol offer. Clients that offer nothing are
+// accepted without a subprotocol unless required is set; clients that offer
+// only unsupported subprotocols are always rejected.
+func NegotiateSubprotocol(r *http.Request, required bool) (string, error) {
+	requested := websocket.Subprotocols(r)
+	if len(requested) == 0 {
+		if required {
+			return "", types.NewUnsupportedSubprotocolError(nil, WebSocketSubprotocols)
+		}
+		return "", nil
+	}
+
+	for _, supported := range WebSocketSubprotocols {
+		for _, offered := range requested {
+			if offered == supported {
+				return supported, nil
+			}
+		}
+	}
+
+	return "", types.NewUnsupportedSubprotocolError(requested, WebSocketSubprotocols)
+}
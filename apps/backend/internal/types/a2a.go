@@ -82,6 +82,8 @@ type A2AAgentSpec struct {
 	Name            string                 `json:"name" binding:"required"`
 	Tags            []string               `json:"tags"`
 	IsActive        bool                   `json:"is_active"`
+	// Card is the agent's latest agent card check, in responses only
+	Card *A2AAgentCardRecord `json:"card,omitempty"`
 }
 
 // A2AAgentTool represents a tool exposed by an A2A agent through a virtual server
@@ -0,0 +1,109 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Agent card statuses
+const (
+	// A2AAgentCardStatusValid is a card that passed validation
+	A2AAgentCardStatusValid = "valid"
+	// A2AAgentCardStatusInvalid is a card with problems; it is kept with
+	// the problems found
+	A2AAgentCardStatusInvalid = "invalid"
+	// A2AAgentCardStatusUnavailable is an agent whose card could not be
+	// fetched; the last card fetched, if any, is kept
+	A2AAgentCardStatusUnavailable = "unavailable"
+)
+
+// Security scheme types an agent card may declare
+const (
+	A2ASecuritySchemeAPIKey        = "apiKey"
+	A2ASecuritySchemeHTTP          = "http"
+	A2ASecuritySchemeOAuth2        = "oauth2"
+	A2ASecuritySchemeOpenIDConnect = "openIdConnect"
+	A2ASecuritySchemeMutualTLS     = "mutualTLS"
+)
+
+// A2AAgentCard is the self-description an A2A agent publishes at
+// /.well-known/agent.json: who it is, what it can do and how callers
+// authenticate
+type A2AAgentCard struct {
+	SecuritySchemes    map[string]*A2ASecurityScheme `json:"securitySchemes,omitempty"`
+	Provider           *A2AAgentProvider             `json:"provider,omitempty"`
+	Authentication     *A2AAgentAuthentication       `json:"authentication,omitempty"`
+	Name               string                        `json:"name"`
+	Description        string                        `json:"description,omitempty"`
+	URL                string                        `json:"url"`
+	Version            string                        `json:"version"`
+	ProtocolVersion    string                        `json:"protocolVersion,omitempty"`
+	DocumentationURL   string                        `json:"documentationUrl,omitempty"`
+	Security           []map[string][]string         `json:"security,omitempty"`
+	DefaultInputModes  []string                      `json:"defaultInputModes,omitempty"`
+	DefaultOutputModes []string                      `json:"defaultOutputModes,omitempty"`
+	Skills             []*A2AAgentSkill              `json:"skills"`
+	Capabilities       A2AAgentCardCapabilities      `json:"capabilities"`
+}
+
+// A2AAgentProvider is the organization behind an agent
+type A2AAgentProvider struct {
+	Organization string `json:"organization"`
+	URL          string `json:"url,omitempty"`
+}
+
+// A2AAgentCardCapabilities are the optional protocol features an agent
+// supports
+type A2AAgentCardCapabilities struct {
+	Streaming              bool `json:"streaming"`
+	PushNotifications      bool `json:"pushNotifications"`
+	StateTransitionHistory bool `json:"stateTransitionHistory"`
+}
+
+// A2ASecurityScheme is a way of authenticating to an agent, modelled on
+// OpenAPI security schemes
+type A2ASecurityScheme struct {
+	Flows            json.RawMessage `json:"flows,omitempty"`
+	Type             string          `json:"type"`
+	Description      string          `json:"description,omitempty"`
+	Name             string          `json:"name,omitempty"`
+	In               string          `json:"in,omitempty"`
+	Scheme           string          `json:"scheme,omitempty"`
+	BearerFormat     string          `json:"bearerFormat,omitempty"`
+	OpenIDConnectURL string          `json:"openIdConnectUrl,omitempty"`
+}
+
+// A2AAgentAuthentication lists the authentication schemes of cards
+// written before securitySchemes, such as "Bearer" or "ApiKey"
+type A2AAgentAuthentication struct {
+	Credentials string   `json:"credentials,omitempty"`
+	Schemes     []string `json:"schemes"`
+}
+
+// A2AAgentSkill is one thing an agent can do
+type A2AAgentSkill struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Examples    []string `json:"examples,omitempty"`
+	InputModes  []string `json:"inputModes,omitempty"`
+	OutputModes []string `json:"outputModes,omitempty"`
+}
+
+// A2AAgentCardRecord is the latest check of an agent's card. Card is the
+// last card fetched; Problems make it invalid, while Warnings point out
+// where it disagrees with how the agent is registered.
+type A2AAgentCardRecord struct {
+	CheckedAt time.Time     `json:"checked_at"`
+	FetchedAt *time.Time    `json:"fetched_at,omitempty"`
+	Card      *A2AAgentCard `json:"card,omitempty"`
+	Status    string        `json:"status"`
+	URL       string        `json:"url"`
+	Error     string        `json:"error,omitempty"`
+	Problems  []string      `json:"problems,omitempty"`
+	Warnings  []string      `json:"warnings,omitempty"`
+	AgentID   uuid.UUID     `json:"agent_id"`
+}
@@ -0,0 +1,77 @@
+package types
+
+import "time"
+
+// Materialized views behind the admin stats, refreshed by the worker
+const (
+	StatsViewToolUsage      = "stats_tool_usage_daily"
+	StatsViewServerRequests = "stats_server_requests_daily"
+	StatsViewActiveUsers    = "stats_active_users_daily"
+)
+
+// StatsViews lists every admin stats view in refresh order
+var StatsViews = []string{StatsViewToolUsage, StatsViewServerRequests, StatsViewActiveUsers}
+
+// UserStats counts an organization's users and those who made requests
+// during the stats window
+type UserStats struct {
+	Total  int64 `json:"total"`
+	Active int64 `json:"active"`
+}
+
+// ServerCounts counts an organization's MCP servers and the healthy ones
+type ServerCounts struct {
+	Total   int64 `json:"total"`
+	Healthy int64 `json:"healthy"`
+}
+
+// RequestTotals counts the requests made during the stats window
+type RequestTotals struct {
+	Total      int64 `json:"total"`
+	Successful int64 `json:"successful"`
+	Failed     int64 `json:"failed"`
+}
+
+// ToolUsageStat is one tool on the usage leaderboard
+type ToolUsageStat struct {
+	Tool          string  `json:"tool"`
+	Calls         int64   `json:"calls"`
+	Errors        int64   `json:"errors"`
+	ErrorRate     float64 `json:"error_rate"`
+	AvgDurationMS float64 `json:"avg_duration_ms"`
+}
+
+// ServerErrorStat is the request count and error rate of one MCP server
+type ServerErrorStat struct {
+	ServerID      string  `json:"server_id"`
+	ServerName    string  `json:"server_name"`
+	Requests      int64   `json:"requests"`
+	Errors        int64   `json:"errors"`
+	ErrorRate     float64 `json:"error_rate"`
+	AvgDurationMS float64 `json:"avg_duration_ms"`
+}
+
+// StatsFreshness tells when a stats view was last refreshed. Stale views
+// have not been refreshed within the expected interval, usually because the
+// worker is not running.
+type StatsFreshness struct {
+	RefreshedAt *time.Time `json:"refreshed_at"`
+	View        string     `json:"view"`
+	AgeSeconds  int64      `json:"age_seconds"`
+	Stale       bool       `json:"stale"`
+}
+
+// AdminStats summarizes an organization's activity over the last Days days.
+// Aggregates come from periodically refreshed views, so they lag behind by
+// up to the age in Freshness.
+type AdminStats struct {
+	Since            time.Time          `json:"since"`
+	TopTools         []*ToolUsageStat   `json:"top_tools"`
+	ServerErrorRates []*ServerErrorStat `json:"server_error_rates"`
+	Freshness        []*StatsFreshness  `json:"freshness"`
+	Users            UserStats          `json:"users"`
+	Servers          ServerCounts       `json:"servers"`
+	Requests         RequestTotals      `json:"requests"`
+	Days             int                `json:"days"`
+	Stale            bool               `json:"stale"`
+}
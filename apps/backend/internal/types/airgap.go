@@ -0,0 +1,104 @@
+package types
+
+// Features that reach services outside the deployment
+const (
+	ConnectivityFeaturePackageDiscovery = "mcp_package_discovery"
+	ConnectivityFeatureAIModeration     = "ai_moderation"
+	ConnectivityFeatureTelemetry        = "telemetry"
+	ConnectivityFeatureOwnerWebhooks    = "owner_webhooks"
+	ConnectivityFeatureSearchEmbeddings = "search_embeddings"
+	ConnectivityFeatureAuditExport      = "audit_export"
+	ConnectivityFeatureApprovalWebhooks = "approval_webhooks"
+	ConnectivityFeatureExternalDLP      = "external_dlp"
+	ConnectivityFeatureWebhooks         = "webhooks"
+	ConnectivityFeatureRetentionExport  = "retention_export"
+	ConnectivityFeatureLogArchive       = "log_archive"
+	ConnectivityFeatureAgentCards       = "a2a_agent_cards"
+)
+
+// ConnectivityFeature is a gateway feature that makes outbound calls
+type ConnectivityFeature struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	// Mirrorable features can use a local mirror in offline mode
+	Mirrorable bool `json:"mirrorable"`
+}
+
+// ConnectivityFeatures lists every feature that needs outbound connectivity
+var ConnectivityFeatures = []ConnectivityFeature{
+	{
+		Key:         ConnectivityFeaturePackageDiscovery,
+		Description: "MCP package search against the public discovery service",
+		Mirrorable:  true,
+	},
+	{
+		Key:         ConnectivityFeatureAIModeration,
+		Description: "LlamaGuard and OpenAI moderation content filters calling hosted APIs",
+	},
+	{
+		Key:         ConnectivityFeatureTelemetry,
+		Description: "Anonymous usage telemetry reporting",
+		Mirrorable:  true,
+	},
+	{
+		Key:         ConnectivityFeatureOwnerWebhooks,
+		Description: "Server state change webhooks to server owners",
+	},
+	{
+		Key:         ConnectivityFeatureSearchEmbeddings,
+		Description: "Catalog search embeddings from a hosted embeddings API",
+	},
+	{
+		Key:         ConnectivityFeatureAuditExport,
+		Description: "Audit record streaming to syslog, webhook and Kafka sinks",
+	},
+	{
+		Key:         ConnectivityFeatureApprovalWebhooks,
+		Description: "Tool approval request webhooks",
+	},
+	{
+		Key:         ConnectivityFeatureExternalDLP,
+		Description: "Outbound tool argument classification by an external DLP API",
+	},
+	{
+		Key:         ConnectivityFeatureWebhooks,
+		Description: "Gateway event webhooks registered by organizations",
+	},
+	{
+		Key:         ConnectivityFeatureRetentionExport,
+		Description: "Log exports to organizations' S3 buckets before retention purges them",
+	},
+	{
+		Key:         ConnectivityFeatureLogArchive,
+		Description: "Execution log archives in S3 cold storage",
+	},
+	{
+		Key:         ConnectivityFeatureAgentCards,
+		Description: "Agent card discovery for registered A2A agents",
+	},
+}
+
+// LookupConnectivityFeature returns the feature registered under key
+func LookupConnectivityFeature(key string) (ConnectivityFeature, bool) {
+	for _, feature := range ConnectivityFeatures {
+		if feature.Key == key {
+			return feature, true
+		}
+	}
+	return ConnectivityFeature{}, false
+}
+
+// ConnectivityFeatureStatus reports whether a feature works in the current mode
+type ConnectivityFeatureStatus struct {
+	ConnectivityFeature
+	Mirror    string `json:"mirror,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Available bool   `json:"available"`
+}
+
+// OfflineStatus describes air-gapped operation
+type OfflineStatus struct {
+	AllowedHosts []string                    `json:"allowed_hosts"`
+	Features     []ConnectivityFeatureStatus `json:"features"`
+	Offline      bool                        `json:"offline"`
+}
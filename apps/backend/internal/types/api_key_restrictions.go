@@ -0,0 +1,251 @@
+package types
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Limits on API key restrictions
+const (
+	maxAPIKeyCIDRs      = 50
+	maxAPIKeyOrigins    = 50
+	maxAPIKeyScopeItems = 100
+)
+
+// UpdateAPIKeyRestrictionsRequest replaces the networks and browser origins
+// an API key may be used from. Empty lists lift the restriction.
+type UpdateAPIKeyRestrictionsRequest struct {
+	AllowedCIDRs   []string `json:"allowed_cidrs"`
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+// UpdateAPIKeyScopeRequest replaces the namespaces, servers and tools an
+// API key may use. Empty lists lift the restriction.
+type UpdateAPIKeyScopeRequest struct {
+	AllowedNamespaces []string `json:"allowed_namespaces"`
+	AllowedServers    []string `json:"allowed_servers"`
+	AllowedTools      []string `json:"allowed_tools"`
+}
+
+// APIKeyScope is the set of namespaces, servers and tools a scoped API key
+// may use. An empty list leaves that dimension unrestricted.
+type APIKeyScope struct {
+	Namespaces []string
+	Servers    []string
+	// Tools are path.Match patterns, such as github__* or *__read_*, matched
+	// against the namespace-prefixed tool name and the bare tool name
+	Tools []string
+}
+
+// NormalizeAPIKeyScope validates the namespace and server IDs and the tool
+// name patterns of an API key's scope
+func NormalizeAPIKeyScope(namespaces, servers, tools []string) (*APIKeyScope, error) {
+	scope := &APIKeyScope{}
+	var err error
+	if scope.Namespaces, err = normalizeAPIKeyScopeIDs("namespace", namespaces); err != nil {
+		return nil, err
+	}
+	if scope.Servers, err = normalizeAPIKeyScopeIDs("server", servers); err != nil {
+		return nil, err
+	}
+	if len(tools) > maxAPIKeyScopeItems {
+		return nil, NewValidationError(fmt.Sprintf("at most %d tool patterns are allowed", maxAPIKeyScopeItems))
+	}
+	scope.Tools = make([]string, 0, len(tools))
+	for _, pattern := range tools {
+		pattern = strings.TrimSpace(pattern)
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" || len(pattern) > 255 {
+			return nil, NewValidationError("invalid tool pattern " + pattern + ": use a tool name with optional * and ? wildcards")
+		}
+		if !containsString(scope.Tools, pattern) {
+			scope.Tools = append(scope.Tools, pattern)
+		}
+	}
+	return scope, nil
+}
+
+func normalizeAPIKeyScopeIDs(kind string, ids []string) ([]string, error) {
+	if len(ids) > maxAPIKeyScopeItems {
+		return nil, NewValidationError(fmt.Sprintf("at most %d %ss are allowed", maxAPIKeyScopeItems, kind))
+	}
+	normalized := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parsed, err := uuid.Parse(strings.TrimSpace(id))
+		if err != nil {
+			return nil, NewValidationError("invalid " + kind + " ID " + id)
+		}
+		if !containsString(normalized, parsed.String()) {
+			normalized = append(normalized, parsed.String())
+		}
+	}
+	return normalized, nil
+}
+
+// Scope returns the key's scope, or nil when the key may use every
+// namespace, server and tool of its organization
+func (k *APIKey) Scope() *APIKeyScope {
+	if k == nil || len(k.AllowedNamespaces)+len(k.AllowedServers)+len(k.AllowedTools) == 0 {
+		return nil
+	}
+	return &APIKeyScope{Namespaces: k.AllowedNamespaces, Servers: k.AllowedServers, Tools: k.AllowedTools}
+}
+
+// CheckNamespace returns a forbidden error unless the scope includes the
+// namespace
+func (s *APIKeyScope) CheckNamespace(namespaceID string) *Error {
+	if s == nil || len(s.Namespaces) == 0 || containsString(s.Namespaces, namespaceID) {
+		return nil
+	}
+	return NewForbiddenError("API key is not allowed to use namespace " + namespaceID)
+}
+
+// CheckTool returns a forbidden error unless the scope includes the server
+// the tool runs on and the tool itself. tool is the name the client called,
+// which on namespaces is prefixed with the server name.
+func (s *APIKeyScope) CheckTool(serverID, tool string) *Error {
+	if s == nil {
+		return nil
+	}
+	if len(s.Servers) > 0 && !containsString(s.Servers, serverID) {
+		return NewForbiddenError("API key is not allowed to use the server of tool " + tool)
+	}
+	if len(s.Tools) == 0 {
+		return nil
+	}
+	_, bare, prefixed := strings.Cut(tool, "__")
+	for _, pattern := range s.Tools {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return nil
+		}
+		if ok, _ := path.Match(pattern, bare); prefixed && ok {
+			return nil
+		}
+	}
+	return NewForbiddenError("API key is not allowed to call tool " + tool)
+}
+
+// NormalizeAPIKeyCIDRs validates CIDR ranges for an API key, turning bare
+// addresses into single-host ranges
+func NormalizeAPIKeyCIDRs(cidrs []string) ([]string, error) {
+	if len(cidrs) > maxAPIKeyCIDRs {
+		return nil, NewValidationError(fmt.Sprintf("at most %d CIDR ranges are allowed", maxAPIKeyCIDRs))
+	}
+	normalized := make([]string, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, NewValidationError("invalid CIDR range " + cidr)
+			}
+			if ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, NewValidationError("invalid CIDR range " + cidr)
+		}
+		normalized = append(normalized, network.String())
+	}
+	return normalized, nil
+}
+
+// NormalizeAPIKeyOrigins validates origin patterns for an API key. A
+// pattern is a scheme and host with an optional port, such as
+// https://app.example.com, and may start the host with a "*." wildcard
+// matching any subdomain.
+func NormalizeAPIKeyOrigins(origins []string) ([]string, error) {
+	if len(origins) > maxAPIKeyOrigins {
+		return nil, NewValidationError(fmt.Sprintf("at most %d origins are allowed", maxAPIKeyOrigins))
+	}
+	normalized := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimRight(strings.TrimSpace(origin), "/"))
+		scheme, host, ok := strings.Cut(origin, "://")
+		if !ok || (scheme != "http" && scheme != "https") || !validOriginHost(strings.TrimPrefix(host, "*.")) {
+			return nil, NewValidationError("invalid origin " + origin + ": use scheme://host[:port], optionally with a *. subdomain wildcard")
+		}
+		normalized = append(normalized, origin)
+	}
+	return normalized, nil
+}
+
+// CheckClient returns a forbidden error unless a request from clientIP with
+// the given Origin and Referer headers satisfies the key's restrictions.
+// Keys restricted to origins require one of the headers, since only
+// browsers are expected to use them.
+func (k *APIKey) CheckClient(clientIP, origin, referer string) *Error {
+	if len(k.AllowedCIDRs) > 0 && !apiKeyAllowsIP(k.AllowedCIDRs, clientIP) {
+		return NewForbiddenError("API key is not allowed from this network")
+	}
+	if len(k.AllowedOrigins) > 0 {
+		if origin == "" || origin == "null" {
+			origin = refererOrigin(referer)
+		}
+		if !apiKeyAllowsOrigin(k.AllowedOrigins, origin) {
+			return NewForbiddenError("API key is not allowed from this origin")
+		}
+	}
+	return nil
+}
+
+// validOriginHost reports whether host is a bare host with an optional port
+func validOriginHost(host string) bool {
+	if host == "" || strings.ContainsAny(host, "/?#@*") {
+		return false
+	}
+	u, err := url.Parse("http://" + host)
+	return err == nil && u.Host == host && u.Hostname() != ""
+}
+
+func apiKeyAllowsIP(cidrs []string, clientIP string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range cidrs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func apiKeyAllowsOrigin(patterns []string, origin string) bool {
+	origin = strings.ToLower(strings.TrimRight(origin, "/"))
+	if origin == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if pattern == origin {
+			return true
+		}
+		scheme, host, ok := strings.Cut(pattern, "://*.")
+		if !ok {
+			continue
+		}
+		prefix := scheme + "://"
+		if strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, "."+host) &&
+			len(origin) > len(prefix)+len(host)+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// refererOrigin returns the origin of a Referer header
+func refererOrigin(referer string) string {
+	u, err := url.Parse(referer)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
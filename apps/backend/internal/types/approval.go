@@ -0,0 +1,95 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// Tool approval statuses. A pending approval is decided by an approver,
+// expires when nobody decides it in time, or is cancelled when the caller
+// gives up waiting.
+const (
+	ApprovalStatusPending   = "pending"
+	ApprovalStatusApproved  = "approved"
+	ApprovalStatusRejected  = "rejected"
+	ApprovalStatusExpired   = "expired"
+	ApprovalStatusCancelled = "cancelled"
+)
+
+// ApprovalWebhookEvent is the X-Omnimesh-Event of approval webhooks
+const ApprovalWebhookEvent = "tool_approval.requested"
+
+// ToolApprovalSettings configure who is asked to approve calls a tool
+// policy holds and how long the call waits. Organization admins are always
+// notified in the dashboard.
+type ToolApprovalSettings struct {
+	Emails     []string `json:"emails,omitempty"`
+	WebhookURL string   `json:"webhook_url,omitempty"`
+	// TimeoutSeconds is how long the call waits for a decision; zero uses
+	// the gateway's default
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// ToolApproval is a tool call parked until an approver approves or rejects
+// it
+type ToolApproval struct {
+	CreatedAt      time.Time              `json:"created_at"`
+	ExpiresAt      time.Time              `json:"expires_at"`
+	DecidedAt      *time.Time             `json:"decided_at,omitempty"`
+	Arguments      map[string]interface{} `json:"arguments,omitempty"`
+	ID             string                 `json:"id"`
+	OrganizationID string                 `json:"organization_id"`
+	NamespaceID    string                 `json:"namespace_id"`
+	ServerID       string                 `json:"server_id,omitempty"`
+	Tool           string                 `json:"tool"`
+	PolicyID       string                 `json:"policy_id,omitempty"`
+	PolicyName     string                 `json:"policy_name,omitempty"`
+	Message        string                 `json:"message,omitempty"`
+	// RequestedBy is the principal key of the caller, e.g. "api_key:<id>"
+	RequestedBy string `json:"requested_by"`
+	Status      string `json:"status"`
+	DecidedBy   string `json:"decided_by,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// ApprovalListFilter selects a page of an organization's approvals
+type ApprovalListFilter struct {
+	OrganizationID string
+	Status         string
+	Limit          int
+	Offset         int
+}
+
+// ApprovalDecisionRequest is the body of an approve or reject request
+type ApprovalDecisionRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// ApprovalWebhookPayload is posted to a policy's approval webhook when a
+// call is held for approval
+type ApprovalWebhookPayload struct {
+	Approval *ToolApproval `json:"approval"`
+	Event    string        `json:"event"`
+	// DecideURL is the admin API resource to approve or reject the call at
+	DecideURL string `json:"decide_url"`
+}
+
+type deadlineExtenderKey struct{}
+
+// WithDeadlineExtender returns a copy of ctx that lets code handling the
+// request push back its deadline through ExtendDeadline
+func WithDeadlineExtender(ctx context.Context, extend func(until time.Time)) context.Context {
+	return context.WithValue(ctx, deadlineExtenderKey{}, extend)
+}
+
+// ExtendDeadline keeps the request ctx belongs to alive until at least
+// until, and reports whether the request allows it. Requests without an
+// extender keep their deadline.
+func ExtendDeadline(ctx context.Context, until time.Time) bool {
+	extend, ok := ctx.Value(deadlineExtenderKey{}).(func(time.Time))
+	if !ok {
+		return false
+	}
+	extend(until)
+	return true
+}
@@ -0,0 +1,132 @@
+package types
+
+import "time"
+
+// Datasets that are archived to cold storage
+const (
+	// ArchiveDatasetExecutionLogs is the execution log index, log_index
+	ArchiveDatasetExecutionLogs = "execution_logs"
+	// ArchiveDatasetSessionEvents are the MCP messages of sessions,
+	// mcp_message_logs
+	ArchiveDatasetSessionEvents = "session_events"
+)
+
+// ArchiveDatasets lists every archived dataset
+var ArchiveDatasets = []string{ArchiveDatasetExecutionLogs, ArchiveDatasetSessionEvents}
+
+// Archive rehydration statuses
+const (
+	RehydrationStatusPending   = "pending"
+	RehydrationStatusRunning   = "running"
+	RehydrationStatusCompleted = "completed"
+	RehydrationStatusFailed    = "failed"
+	RehydrationStatusExpired   = "expired"
+)
+
+// ArchivePolicy is an organization's archive lifecycle policy. Once
+// enabled, execution logs and session events older than ArchiveAfterDays
+// move to archive storage.
+type ArchivePolicy struct {
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+	OrganizationID   string     `json:"organization_id"`
+	UpdatedBy        string     `json:"updated_by,omitempty"`
+	ArchiveAfterDays int        `json:"archive_after_days"`
+	Enabled          bool       `json:"enabled"`
+}
+
+// UpdateArchivePolicyRequest changes an organization's archive policy
+type UpdateArchivePolicyRequest struct {
+	Enabled          *bool `json:"enabled"`
+	ArchiveAfterDays *int  `json:"archive_after_days" binding:"omitempty,min=1"`
+}
+
+// LogArchive is one archived UTC day of a dataset. Its rows are in
+// archive storage; the counts stay queryable.
+type LogArchive struct {
+	RangeStart     time.Time        `json:"range_start"`
+	RangeEnd       time.Time        `json:"range_end"`
+	CreatedAt      time.Time        `json:"created_at"`
+	Methods        map[string]int64 `json:"methods"`
+	ID             string           `json:"id"`
+	OrganizationID string           `json:"organization_id"`
+	Dataset        string           `json:"dataset"`
+	StorageKey     string           `json:"storage_key"`
+	ManifestKey    string           `json:"manifest_key"`
+	SHA256         string           `json:"sha256"`
+	RowCount       int64            `json:"row_count"`
+	ErrorCount     int64            `json:"error_count"`
+	SizeBytes      int64            `json:"size_bytes"`
+}
+
+// ArchiveManifest describes an archive object. It is stored next to the
+// object so archives can be read without the database.
+type ArchiveManifest struct {
+	RangeStart     time.Time        `json:"range_start"`
+	RangeEnd       time.Time        `json:"range_end"`
+	CreatedAt      time.Time        `json:"created_at"`
+	Methods        map[string]int64 `json:"methods"`
+	OrganizationID string           `json:"organization_id"`
+	Dataset        string           `json:"dataset"`
+	Table          string           `json:"table"`
+	Object         string           `json:"object"`
+	Format         string           `json:"format"`
+	Compression    string           `json:"compression"`
+	SHA256         string           `json:"sha256"`
+	RowCount       int64            `json:"row_count"`
+	ErrorCount     int64            `json:"error_count"`
+	SizeBytes      int64            `json:"size_bytes"`
+	Version        int              `json:"version"`
+}
+
+// ArchiveQuery selects the archives of an organization overlapping
+// StartTime to EndTime. Zero times leave the range open.
+type ArchiveQuery struct {
+	StartTime time.Time `form:"start_time"`
+	EndTime   time.Time `form:"end_time"`
+	Dataset   string    `form:"dataset"`
+}
+
+// ArchiveRun summarizes one archival pass
+type ArchiveRun struct {
+	Archived []*LogArchive `json:"archived"`
+	// Held lists organizations skipped because they are under legal hold
+	Held []string `json:"held"`
+	Rows int64    `json:"rows"`
+}
+
+// ArchiveRehydration temporarily restores the archived days overlapping
+// StartTime to EndTime into the log tables, until ExpiresAt
+type ArchiveRehydration struct {
+	CreatedAt      time.Time     `json:"created_at"`
+	StartTime      time.Time     `json:"start_time"`
+	EndTime        time.Time     `json:"end_time"`
+	CompletedAt    *time.Time    `json:"completed_at,omitempty"`
+	ExpiresAt      *time.Time    `json:"expires_at,omitempty"`
+	ID             string        `json:"id"`
+	OrganizationID string        `json:"organization_id"`
+	RequestedBy    string        `json:"requested_by,omitempty"`
+	Status         string        `json:"status"`
+	Error          string        `json:"error,omitempty"`
+	Datasets       []string      `json:"datasets"`
+	TTL            time.Duration `json:"-"`
+	ArchiveCount   int           `json:"archive_count"`
+	RowCount       int64         `json:"row_count"`
+}
+
+// CreateRehydrationRequest requests the archived logs between StartTime
+// and EndTime for TTLHours, the configured default when zero. Both datasets
+// are restored when none are named.
+type CreateRehydrationRequest struct {
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required"`
+	Datasets  []string  `json:"datasets"`
+	TTLHours  int       `json:"ttl_hours" binding:"min=0"`
+}
+
+// ArchiveRow is one row of a dataset as written to an archive
+type ArchiveRow struct {
+	ID     string
+	Method string
+	Data   []byte
+	Error  bool
+}
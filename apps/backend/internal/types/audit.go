@@ -0,0 +1,118 @@
+package types
+
+import "time"
+
+// Audit severity tiers, in ascending order of importance
+const (
+	AuditSeverityInfo     = "info"
+	AuditSeverityNotice   = "notice"
+	AuditSeverityWarning  = "warning"
+	AuditSeverityCritical = "critical"
+)
+
+// AuditGenesisHash is the previous hash of the first record in a chain
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+var auditSeverityRank = map[string]int{
+	AuditSeverityInfo:     0,
+	AuditSeverityNotice:   1,
+	AuditSeverityWarning:  2,
+	AuditSeverityCritical: 3,
+}
+
+// AuditSeverityRank returns the ordering of a severity tier, or -1 if unknown
+func AuditSeverityRank(severity string) int {
+	if rank, ok := auditSeverityRank[severity]; ok {
+		return rank
+	}
+	return -1
+}
+
+// IsValidAuditSeverity reports whether severity is a known tier
+func IsValidAuditSeverity(severity string) bool {
+	return AuditSeverityRank(severity) >= 0
+}
+
+// AuditSeverityFor classifies an audited action. Security configuration
+// changes are critical, destructive actions and failures are warnings,
+// other mutations are notices and everything else is informational.
+func AuditSeverityFor(action, resource string, success bool) string {
+	switch resource {
+	case "auth-config", "policy", "content_filter":
+		if action != "read" {
+			return AuditSeverityCritical
+		}
+	case "configuration":
+		if action == "import" {
+			return AuditSeverityCritical
+		}
+	case "legal_hold", "break_glass", "compromised_credential", "audit-sink", "failover":
+		return AuditSeverityCritical
+	}
+
+	switch action {
+	case "regenerate-keys", "reject-command":
+		return AuditSeverityCritical
+	case "delete", "unregister", "remove-server", "revoke":
+		return AuditSeverityWarning
+	}
+
+	if !success {
+		return AuditSeverityWarning
+	}
+
+	switch action {
+	case "create", "update", "register", "add-server", "toggle", "export",
+		"update-server-status", "update-tool-status":
+		return AuditSeverityNotice
+	}
+
+	return AuditSeverityInfo
+}
+
+// AuditAnchor is a periodic digest of an organization's audit chain head.
+// Anchors are themselves chained so that rewriting history before an
+// anchor requires rewriting every later anchor as well.
+type AuditAnchor struct {
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	ID             string    `json:"id" db:"id"`
+	OrganizationID string    `json:"organization_id" db:"organization_id"`
+	RecordHash     string    `json:"record_hash" db:"record_hash"`
+	PrevDigest     string    `json:"prev_digest" db:"prev_digest"`
+	Digest         string    `json:"digest" db:"digest"`
+	Sequence       int64     `json:"sequence" db:"sequence"`
+}
+
+// AuditChainFailure describes a single integrity violation
+type AuditChainFailure struct {
+	RecordID string `json:"record_id,omitempty"`
+	AnchorID string `json:"anchor_id,omitempty"`
+	Reason   string `json:"reason"`
+	Sequence int64  `json:"sequence"`
+}
+
+// AuditChainReport is the result of verifying an audit chain
+type AuditChainReport struct {
+	VerifiedAt     time.Time           `json:"verified_at"`
+	OrganizationID string              `json:"organization_id"`
+	HeadHash       string              `json:"head_hash,omitempty"`
+	Failures       []AuditChainFailure `json:"failures,omitempty"`
+	RecordsChecked int                 `json:"records_checked"`
+	AnchorsChecked int                 `json:"anchors_checked"`
+	HeadSequence   int64               `json:"head_sequence"`
+	Valid          bool                `json:"valid"`
+}
+
+// AuditLogQuery filters audit log listings
+type AuditLogQuery struct {
+	StartTime      *time.Time `json:"start_time,omitempty" form:"start_time"`
+	EndTime        *time.Time `json:"end_time,omitempty" form:"end_time"`
+	OrganizationID string     `json:"organization_id,omitempty" form:"-"`
+	UserID         string     `json:"user_id,omitempty" form:"user_id"`
+	Action         string     `json:"action,omitempty" form:"action"`
+	Resource       string     `json:"resource,omitempty" form:"resource"`
+	ResourceID     string     `json:"resource_id,omitempty" form:"resource_id"`
+	MinSeverity    string     `json:"min_severity,omitempty" form:"min_severity"`
+	Limit          int        `json:"limit,omitempty" form:"limit"`
+	Offset         int        `json:"offset,omitempty" form:"offset"`
+}
@@ -0,0 +1,103 @@
+package types
+
+import "time"
+
+// Destinations audit records can be streamed to
+const (
+	AuditSinkTypeSyslog  = "syslog"
+	AuditSinkTypeWebhook = "webhook"
+	AuditSinkTypeKafka   = "kafka"
+)
+
+// Encodings of exported audit records
+const (
+	AuditSinkFormatJSON = "json"
+	AuditSinkFormatCEF  = "cef"
+)
+
+// Networks syslog sinks send over
+const (
+	AuditSyslogNetworkUDP = "udp"
+	AuditSyslogNetworkTCP = "tcp"
+	AuditSyslogNetworkTLS = "tls"
+)
+
+// AuditSinkConfig holds the settings of a sink's type. Syslog sinks use
+// Address, Network, Facility and AppName; webhooks use URL and Headers;
+// Kafka sinks use URL, the base URL of a Kafka REST proxy, with Topic and
+// an optional Username.
+type AuditSinkConfig struct {
+	Headers  map[string]string `json:"headers,omitempty"`
+	Facility *int              `json:"facility,omitempty"`
+	Address  string            `json:"address,omitempty"`
+	Network  string            `json:"network,omitempty"`
+	AppName  string            `json:"app_name,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Topic    string            `json:"topic,omitempty"`
+	Username string            `json:"username,omitempty"`
+}
+
+// AuditSink streams an organization's audit records to a SIEM. The worker
+// delivers records in chain order, in batches, and records how far it got
+// in CursorSequence; a failed batch is retried with backoff from the same
+// position, so records are delivered at least once.
+type AuditSink struct {
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	NextAttemptAt   *time.Time      `json:"next_attempt_at,omitempty"`
+	LastDeliveredAt *time.Time      `json:"last_delivered_at,omitempty"`
+	Config          AuditSinkConfig `json:"config"`
+	ID              string          `json:"id"`
+	OrganizationID  string          `json:"organization_id"`
+	Name            string          `json:"name"`
+	Type            string          `json:"type"`
+	Format          string          `json:"format"`
+	MinSeverity     string          `json:"min_severity"`
+	Secret          string          `json:"-"`
+	LastError       string          `json:"last_error,omitempty"`
+	CreatedBy       string          `json:"created_by,omitempty"`
+	CursorSequence  int64           `json:"cursor_sequence"`
+	// Lag counts the chained records not yet delivered
+	Lag       int64 `json:"lag"`
+	BatchSize int   `json:"batch_size"`
+	Failures  int   `json:"failures"`
+	Enabled   bool  `json:"enabled"`
+	HasSecret bool  `json:"has_secret"`
+	// SigningKeyID is the key ID in the X-Omnimesh-Request-Signature of
+	// webhook sinks with a secret
+	SigningKeyID string `json:"signing_key_id,omitempty"`
+}
+
+// CreateAuditSinkRequest adds a sink. Secret signs webhook bodies or is the
+// password of a Kafka REST proxy. Unless Backfill is set, the sink starts
+// with the records audited after its creation.
+type CreateAuditSinkRequest struct {
+	Enabled     *bool           `json:"enabled"`
+	Config      AuditSinkConfig `json:"config"`
+	Name        string          `json:"name" binding:"required,max=255"`
+	Type        string          `json:"type" binding:"required,oneof=syslog webhook kafka"`
+	Format      string          `json:"format" binding:"omitempty,oneof=json cef"`
+	MinSeverity string          `json:"min_severity" binding:"omitempty,oneof=info notice warning critical"`
+	Secret      string          `json:"secret" binding:"max=1024"`
+	BatchSize   int             `json:"batch_size" binding:"omitempty,min=1,max=1000"`
+	Backfill    bool            `json:"backfill"`
+}
+
+// UpdateAuditSinkRequest changes a sink; nil fields are left as they are.
+// An empty Secret removes the secret. Re-enabling a sink clears its backoff.
+type UpdateAuditSinkRequest struct {
+	Config      *AuditSinkConfig `json:"config"`
+	Name        *string          `json:"name" binding:"omitempty,min=1,max=255"`
+	Format      *string          `json:"format" binding:"omitempty,oneof=json cef"`
+	MinSeverity *string          `json:"min_severity" binding:"omitempty,oneof=info notice warning critical"`
+	Secret      *string          `json:"secret" binding:"omitempty,max=1024"`
+	BatchSize   *int             `json:"batch_size" binding:"omitempty,min=1,max=1000"`
+	Enabled     *bool            `json:"enabled"`
+}
+
+// AuditSinkTestResult reports the delivery of a test record to a sink
+type AuditSinkTestResult struct {
+	SinkID    string `json:"sink_id"`
+	Error     string `json:"error,omitempty"`
+	Delivered bool   `json:"delivered"`
+}
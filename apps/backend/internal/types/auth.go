@@ -12,9 +12,16 @@ type User struct {
 	PasswordHash   string    `json:"-" db:"password_hash"`
 	OrganizationID string    `json:"organization_id" db:"organization_id"`
 	Role           string    `json:"role" db:"role"`
+	AccountType    string    `json:"account_type,omitempty" db:"account_type"` // human or service
 	IsActive       bool      `json:"is_active" db:"is_active"`
 }
 
+// IsServiceAccount reports whether the user is a non-human principal that
+// authenticates with API keys or OAuth clients only
+func (u *User) IsServiceAccount() bool {
+	return u.AccountType == AccountTypeService
+}
+
 // Organization represents an organization
 type Organization struct {
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
@@ -31,14 +38,23 @@ type APIKey struct {
 	UpdatedAt      time.Time              `json:"updated_at" db:"updated_at"`
 	ExpiresAt      *time.Time             `json:"expires_at" db:"expires_at"`
 	LastUsedAt     *time.Time             `json:"last_used_at" db:"last_used_at"`
+	Labels         Labels                 `json:"labels,omitempty" db:"labels"`
 	ID             string                 `json:"id" db:"id"`
 	UserID         string                 `json:"user_id" db:"user_id"`
 	OrganizationID string                 `json:"organization_id" db:"organization_id"`
+	TeamID         string                 `json:"team_id,omitempty" db:"team_id"` // Set on keys the whole team shares
 	Name           string                 `json:"name" db:"name"`
 	KeyHash        string                 `json:"key_hash" db:"key_hash"`
 	Prefix         string                 `json:"prefix" db:"prefix"`
 	Permissions    []string               `json:"permissions" db:"permissions"`
-	Role           string                 `json:"role" db:"-"` // Computed from permissions
+	AllowedCIDRs   []string               `json:"allowed_cidrs,omitempty" db:"allowed_cidrs"`     // Networks the key may be used from
+	AllowedOrigins []string               `json:"allowed_origins,omitempty" db:"allowed_origins"` // Browser origins the key may be used from
+	// AllowedNamespaces, AllowedServers and AllowedTools scope the key; see
+	// APIKeyScope
+	AllowedNamespaces []string `json:"allowed_namespaces,omitempty" db:"allowed_namespaces"`
+	AllowedServers    []string `json:"allowed_servers,omitempty" db:"allowed_servers"`
+	AllowedTools      []string `json:"allowed_tools,omitempty" db:"allowed_tools"`
+	Role           string                 `json:"role" db:"-"`                                    // Computed from permissions
 	IsActive       bool                   `json:"is_active" db:"is_active"`
 	Metadata       map[string]interface{} `json:"metadata,omitempty" db:"-"` // Additional display metadata
 }
@@ -95,9 +111,20 @@ type UpdateUserRequest struct {
 
 // CreateAPIKeyRequest represents an API key creation request
 type CreateAPIKeyRequest struct {
+	Labels    Labels `json:"labels,omitempty"`
 	Name      string `json:"name" binding:"required,min=2"`
 	Role      string `json:"role" binding:"required"`
 	ExpiresAt string `json:"expires_at,omitempty"`
+	TeamID    string `json:"team_id,omitempty" binding:"omitempty,uuid"` // Share the key with a team the creator belongs to
+	// AllowedCIDRs and AllowedOrigins restrict where the key may be used
+	// from; see UpdateAPIKeyRestrictionsRequest
+	AllowedCIDRs   []string `json:"allowed_cidrs,omitempty"`
+	AllowedOrigins []string `json:"allowed_origins,omitempty"`
+	// AllowedNamespaces, AllowedServers and AllowedTools limit what the key
+	// may use; see UpdateAPIKeyScopeRequest
+	AllowedNamespaces []string `json:"allowed_namespaces,omitempty"`
+	AllowedServers    []string `json:"allowed_servers,omitempty"`
+	AllowedTools      []string `json:"allowed_tools,omitempty"`
 }
 
 // CreateAPIKeyResponse represents an API key creation response
@@ -144,6 +171,7 @@ const (
 	RoleUser    = "user"
 	RoleViewer  = "viewer"
 	RoleAPIUser = "api_user"
+	RoleService = "service"
 )
 
 // Permission constants
@@ -243,4 +271,6 @@ const (
 	PolicyTypeRateLimit = "rate_limit"
 	PolicyTypeRouting   = "routing"
 	PolicyTypeSecurity  = "security"
+	// PolicyTypeTool policies decide whether a namespace tool call may run
+	PolicyTypeTool = "tool"
 )
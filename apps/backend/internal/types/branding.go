@@ -0,0 +1,50 @@
+package types
+
+import "time"
+
+// DefaultProductName names the gateway on endpoint docs of organizations
+// without branding
+const DefaultProductName = "Omnimesh AI Gateway"
+
+// OrganizationBranding customizes the OpenAPI metadata and docs pages of an
+// organization's public endpoints. Empty fields fall back to the gateway's
+// own branding.
+type OrganizationBranding struct {
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+	OrganizationID string     `json:"organization_id"`
+	ProductName    string     `json:"product_name,omitempty"`
+	LogoURL        string     `json:"logo_url,omitempty"`
+	PrimaryColor   string     `json:"primary_color,omitempty"`
+	AccentColor    string     `json:"accent_color,omitempty"`
+	ContactName    string     `json:"contact_name,omitempty"`
+	ContactEmail   string     `json:"contact_email,omitempty"`
+	ContactURL     string     `json:"contact_url,omitempty"`
+	UpdatedBy      string     `json:"updated_by,omitempty"`
+}
+
+// DisplayName returns the product name shown on docs, falling back to the
+// gateway's name
+func (b *OrganizationBranding) DisplayName() string {
+	if b == nil || b.ProductName == "" {
+		return DefaultProductName
+	}
+	return b.ProductName
+}
+
+// HasContact reports whether the branding names someone to contact
+func (b *OrganizationBranding) HasContact() bool {
+	return b != nil && (b.ContactName != "" || b.ContactEmail != "" || b.ContactURL != "")
+}
+
+// SetOrganizationBrandingRequest replaces an organization's branding. Colors
+// are #rrggbb hex codes; the logo and contact URLs must be absolute https
+// URLs, as docs pages are served publicly.
+type SetOrganizationBrandingRequest struct {
+	ProductName  string `json:"product_name" binding:"omitempty,max=100"`
+	LogoURL      string `json:"logo_url" binding:"omitempty,url,startswith=https://,max=2048"`
+	PrimaryColor string `json:"primary_color" binding:"omitempty,hexcolor,len=7"`
+	AccentColor  string `json:"accent_color" binding:"omitempty,hexcolor,len=7"`
+	ContactName  string `json:"contact_name" binding:"omitempty,max=255"`
+	ContactEmail string `json:"contact_email" binding:"omitempty,email,max=255"`
+	ContactURL   string `json:"contact_url" binding:"omitempty,url,startswith=https://,max=2048"`
+}
@@ -0,0 +1,99 @@
+package types
+
+import "time"
+
+// Break-glass defaults
+const (
+	// DefaultBreakGlassWindow is how long an emergency session lasts when
+	// auth.break_glass_window is not set
+	DefaultBreakGlassWindow = time.Hour
+	// BreakGlassCredentialPrefix marks emergency credentials so they are
+	// recognizable when found
+	BreakGlassCredentialPrefix = "mgw_bg_"
+)
+
+// BreakGlassCredential is a sealed emergency credential. Activating it
+// opens a break-glass session for its user; a used credential must be
+// rotated before it can open another.
+type BreakGlassCredential struct {
+	CreatedAt      time.Time  `json:"created_at"`
+	RotatedAt      *time.Time `json:"rotated_at,omitempty"`
+	UsedAt         *time.Time `json:"used_at,omitempty"`
+	ID             string     `json:"id"`
+	OrganizationID string     `json:"organization_id"`
+	UserID         string     `json:"user_id"`
+	UserEmail      string     `json:"user_email,omitempty"`
+	Name           string     `json:"name"`
+	CreatedBy      string     `json:"created_by"`
+	CredentialHash string     `json:"-"`
+	MFASecret      string     `json:"-"`
+}
+
+// Sealed reports whether the credential can still open a session
+func (c *BreakGlassCredential) Sealed() bool {
+	return c.UsedAt == nil
+}
+
+// CreateBreakGlassCredentialRequest seals a new emergency credential for a
+// user of the organization
+type CreateBreakGlassCredentialRequest struct {
+	UserID string `json:"user_id" binding:"required,uuid"`
+	Name   string `json:"name" binding:"required,max=255"`
+}
+
+// BreakGlassCredentialSecret is returned once when a credential is created
+// or rotated. The credential and MFA secret are not retrievable afterwards
+// and should be sealed away, for example in a safe or a separate vault.
+type BreakGlassCredentialSecret struct {
+	Credential *BreakGlassCredential `json:"credential"`
+	Secret     string                `json:"secret"`
+	MFASecret  string                `json:"mfa_secret"`
+	MFAURI     string                `json:"mfa_uri"`
+}
+
+// BreakGlassSession is the emergency platform-admin access opened with a
+// credential
+type BreakGlassSession struct {
+	StartedAt      time.Time  `json:"started_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	ID             string     `json:"id"`
+	CredentialID   string     `json:"credential_id"`
+	OrganizationID string     `json:"organization_id"`
+	UserID         string     `json:"user_id"`
+	UserEmail      string     `json:"user_email,omitempty"`
+	Reason         string     `json:"reason"`
+	RemoteIP       string     `json:"remote_ip,omitempty"`
+	EndedBy        string     `json:"ended_by,omitempty"`
+	ActionCount    int        `json:"action_count"`
+}
+
+// Active reports whether the session still grants access at now
+func (s *BreakGlassSession) Active(now time.Time) bool {
+	return s.EndedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// ActivateBreakGlassRequest opens an emergency session
+type ActivateBreakGlassRequest struct {
+	Credential string `json:"credential" binding:"required"`
+	MFACode    string `json:"mfa_code" binding:"required"`
+	Reason     string `json:"reason" binding:"required,min=10,max=2000"`
+}
+
+// BreakGlassActivation is the access token of an emergency session. It
+// cannot be refreshed and stops working when the session ends.
+type BreakGlassActivation struct {
+	Session     *BreakGlassSession `json:"session"`
+	AccessToken string             `json:"access_token"`
+	TokenType   string             `json:"token_type"`
+	ExpiresIn   int64              `json:"expires_in"`
+}
+
+// BreakGlassAction is a request made during an emergency session
+type BreakGlassAction struct {
+	Method    string
+	Path      string
+	RemoteIP  string
+	UserAgent string
+	Status    int
+}
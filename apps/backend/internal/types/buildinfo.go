@@ -0,0 +1,38 @@
+package types
+
+// BuildInfo identifies the running gateway build
+type BuildInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	Dirty     bool   `json:"dirty,omitempty"`
+}
+
+// VersionResponse is returned by GET /api/version
+type VersionResponse struct {
+	Build           *BuildInfo `json:"build"`
+	ProtocolVersion string     `json:"mcp_protocol_version"`
+	Features        []string   `json:"features"`
+}
+
+// ChangelogChange is one entry in a release's notes
+type ChangelogChange struct {
+	Type        string `json:"type" yaml:"type"`
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description"`
+}
+
+// ChangelogRelease groups the changes shipped in one version
+type ChangelogRelease struct {
+	Version string            `json:"version" yaml:"version"`
+	Date    string            `json:"date,omitempty" yaml:"date"`
+	Changes []ChangelogChange `json:"changes" yaml:"changes"`
+}
+
+// ChangelogResponse is returned by GET /api/changelog
+type ChangelogResponse struct {
+	CurrentVersion string             `json:"current_version"`
+	Releases       []ChangelogRelease `json:"releases"`
+}
@@ -0,0 +1,23 @@
+package types
+
+// DetectedClientContextKey holds the detected *DetectedClient on a gin request
+const DetectedClientContextKey = "detected_client"
+
+// Known MCP client families
+const (
+	ClientClaudeDesktop = "claude-desktop"
+	ClientCursor        = "cursor"
+	ClientVSCode        = "vscode"
+	ClientInspector     = "mcp-inspector"
+	ClientPythonSDK     = "python-sdk"
+	ClientTypeScriptSDK = "typescript-sdk"
+	ClientUnknown       = "unknown"
+)
+
+// DetectedClient identifies the MCP client that sent a request, from its
+// User-Agent
+type DetectedClient struct {
+	Name    string   `json:"name"`
+	Version string   `json:"version,omitempty"`
+	Shims   []string `json:"shims,omitempty"`
+}
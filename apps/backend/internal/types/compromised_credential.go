@@ -0,0 +1,60 @@
+package types
+
+import "time"
+
+// Kinds of credentials that can be reported as compromised
+const (
+	CompromisedAPIKey     = "api_key"
+	CompromisedOAuthToken = "oauth_token"
+	CompromisedJWT        = "jwt"
+)
+
+// Sources of compromise reports
+const (
+	CompromiseSourceThreatFeed = "threat_feed"
+	CompromiseSourceAdmin      = "admin"
+)
+
+// ThreatFeedSignatureHeader carries the hex HMAC-SHA256 of a threat feed
+// report keyed with auth.threat_feed_secret
+const ThreatFeedSignatureHeader = "X-Omnimesh-Signature"
+
+// CompromisedCredential records a credential that was revoked because it
+// was reported as leaked
+type CompromisedCredential struct {
+	CreatedAt      time.Time `json:"created_at"`
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	UserID         string    `json:"user_id,omitempty"`
+	CredentialType string    `json:"credential_type"`
+	CredentialID   string    `json:"credential_id,omitempty"`
+	CredentialName string    `json:"credential_name,omitempty"`
+	Fingerprint    string    `json:"fingerprint,omitempty"`
+	Source         string    `json:"source"`
+	Reporter       string    `json:"reporter"`
+	Reason         string    `json:"reason,omitempty"`
+}
+
+// ReportCompromisedCredentialRequest names one leaked credential, either by
+// the secret itself, by its SHA-256 fingerprint in hex, or by API key ID.
+// Access tokens issued at login can only be reported by the token itself.
+type ReportCompromisedCredentialRequest struct {
+	Token       string `json:"token,omitempty" binding:"max=8192"`
+	Fingerprint string `json:"fingerprint,omitempty" binding:"omitempty,len=64,hexadecimal"`
+	APIKeyID    string `json:"api_key_id,omitempty" binding:"omitempty,uuid"`
+	Reason      string `json:"reason,omitempty" binding:"max=1000"`
+}
+
+// ThreatFeedReport is a batch of leaked credentials pushed by a threat-intel
+// feed
+type ThreatFeedReport struct {
+	Feed        string                                `json:"feed" binding:"required,max=100"`
+	Credentials []*ReportCompromisedCredentialRequest `json:"credentials" binding:"required,min=1,max=1000,dive"`
+}
+
+// CompromiseReportResult lists the credentials a report revoked. Credentials
+// that are unknown or were already revoked are only counted.
+type CompromiseReportResult struct {
+	Revoked   []*CompromisedCredential `json:"revoked"`
+	Unmatched int                      `json:"unmatched"`
+}
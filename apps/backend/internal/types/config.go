@@ -5,21 +5,25 @@ import (
 	"time"
 )
 
-// RateLimitRule represents a rate limiting rule
+// RateLimitRule limits the requests of an organization. Organization rules
+// share one counter across the organization; user, API key and endpoint
+// rules count each user, API key or endpoint separately, or only TargetID
+// when it is set.
 type RateLimitRule struct {
-	CreatedAt      time.Time              `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time              `json:"updated_at" db:"updated_at"`
-	Conditions     map[string]interface{} `json:"conditions" db:"conditions"`
-	OrganizationID string                 `json:"organization_id" db:"organization_id"`
-	Name           string                 `json:"name" db:"name"`
-	Description    string                 `json:"description" db:"description"`
-	Type           string                 `json:"type" db:"type"`
-	ID             string                 `json:"id" db:"id"`
-	Algorithm      string                 `json:"algorithm" db:"algorithm"`
-	Window         time.Duration          `json:"window" db:"window"`
-	Priority       int                    `json:"priority" db:"priority"`
-	Limit          int                    `json:"limit" db:"limit"`
-	IsActive       bool                   `json:"is_active" db:"is_active"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	OrganizationID string    `json:"organization_id" db:"organization_id"`
+	Name           string    `json:"name" db:"name"`
+	Description    string    `json:"description" db:"description"`
+	Type           string    `json:"type" db:"type"`
+	TargetID       string    `json:"target_id,omitempty" db:"target_id"`
+	ID             string    `json:"id" db:"id"`
+	Algorithm      string    `json:"algorithm" db:"algorithm"`
+	WindowSeconds  int       `json:"window_seconds" db:"window_seconds"`
+	Priority       int       `json:"priority" db:"priority"`
+	Limit          int       `json:"limit" db:"limit"`
+	Burst          int       `json:"burst,omitempty" db:"burst"`
+	IsActive       bool      `json:"is_active" db:"is_active"`
 }
 
 // LogEntry represents a log entry
@@ -55,6 +59,10 @@ type AuditLog struct {
 	RemoteIP       string                 `json:"remote_ip" db:"remote_ip"`
 	UserAgent      string                 `json:"user_agent" db:"user_agent"`
 	Error          string                 `json:"error,omitempty" db:"error"`
+	Severity       string                 `json:"severity" db:"severity"`
+	PrevHash       string                 `json:"prev_hash,omitempty" db:"prev_hash"`
+	Hash           string                 `json:"hash,omitempty" db:"hash"`
+	Sequence       int64                  `json:"sequence,omitempty" db:"sequence"`
 	Success        bool                   `json:"success" db:"success"`
 }
 
@@ -73,26 +81,28 @@ type Metric struct {
 
 // CreateRateLimitRuleRequest represents a rate limit rule creation request
 type CreateRateLimitRuleRequest struct {
-	Conditions  map[string]interface{} `json:"conditions"`
-	Name        string                 `json:"name" binding:"required,min=2"`
-	Description string                 `json:"description"`
-	Type        string                 `json:"type" binding:"required"`
-	Algorithm   string                 `json:"algorithm" binding:"required"`
-	Limit       int                    `json:"limit" binding:"required,min=1"`
-	Window      time.Duration          `json:"window" binding:"required"`
-	Priority    int                    `json:"priority"`
+	Name          string `json:"name" binding:"required,min=2"`
+	Description   string `json:"description"`
+	Type          string `json:"type" binding:"required"`
+	TargetID      string `json:"target_id"`
+	Algorithm     string `json:"algorithm" binding:"required"`
+	Limit         int    `json:"limit" binding:"required,min=1"`
+	WindowSeconds int    `json:"window_seconds" binding:"required,min=1"`
+	Burst         int    `json:"burst" binding:"min=0"`
+	Priority      int    `json:"priority"`
 }
 
 // UpdateRateLimitRuleRequest represents a rate limit rule update request
 type UpdateRateLimitRuleRequest struct {
-	Conditions  map[string]interface{} `json:"conditions,omitempty"`
-	IsActive    *bool                  `json:"is_active,omitempty"`
-	Name        string                 `json:"name,omitempty" binding:"omitempty,min=2"`
-	Description string                 `json:"description,omitempty"`
-	Algorithm   string                 `json:"algorithm,omitempty"`
-	Limit       int                    `json:"limit,omitempty" binding:"omitempty,min=1"`
-	Window      time.Duration          `json:"window,omitempty"`
-	Priority    int                    `json:"priority,omitempty"`
+	IsActive      *bool   `json:"is_active,omitempty"`
+	TargetID      *string `json:"target_id,omitempty"`
+	Name          string  `json:"name,omitempty" binding:"omitempty,min=2"`
+	Description   string  `json:"description,omitempty"`
+	Algorithm     string  `json:"algorithm,omitempty"`
+	Limit         int     `json:"limit,omitempty" binding:"omitempty,min=1"`
+	WindowSeconds int     `json:"window_seconds,omitempty" binding:"omitempty,min=1"`
+	Burst         *int    `json:"burst,omitempty" binding:"omitempty,min=0"`
+	Priority      *int    `json:"priority,omitempty"`
 }
 
 // LogQueryRequest represents a log query request
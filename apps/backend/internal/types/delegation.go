@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+// OnBehalfOfHeader names the user, by ID or email, a service account acts
+// for on a request
+const OnBehalfOfHeader = "X-On-Behalf-Of"
+
+// DelegationPolicy lets a service account act on behalf of users of its
+// organization. AllowedRoles and AllowedUserIDs narrow who it may act for;
+// an empty list places no limit. Service accounts without a policy cannot
+// act for anyone.
+type DelegationPolicy struct {
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	ServiceAccountID string    `json:"service_account_id"`
+	OrganizationID   string    `json:"organization_id"`
+	CreatedBy        string    `json:"created_by,omitempty"`
+	AllowedRoles     []string  `json:"allowed_roles"`
+	AllowedUserIDs   []string  `json:"allowed_user_ids"`
+}
+
+// Permits reports whether the policy lets its service account act for user
+func (p *DelegationPolicy) Permits(user *User) bool {
+	return (len(p.AllowedRoles) == 0 || containsString(p.AllowedRoles, user.Role)) &&
+		(len(p.AllowedUserIDs) == 0 || containsString(p.AllowedUserIDs, user.ID))
+}
+
+// SetDelegationPolicyRequest replaces the delegation policy of a service
+// account
+type SetDelegationPolicyRequest struct {
+	AllowedRoles   []string `json:"allowed_roles" binding:"omitempty,dive,oneof=admin user viewer api_user"`
+	AllowedUserIDs []string `json:"allowed_user_ids" binding:"omitempty,dive,uuid"`
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package types
+
+import "time"
+
+// Sensitivity labels, from least to most sensitive. Data carries the label
+// of the classifier that found it; servers carry the label of the most
+// sensitive data they may receive.
+const (
+	DLPSensitivityPublic       = "public"
+	DLPSensitivityInternal     = "internal"
+	DLPSensitivityConfidential = "confidential"
+	DLPSensitivityRestricted   = "restricted"
+)
+
+// DLPSensitivities lists the sensitivity labels from least to most
+// sensitive
+var DLPSensitivities = []string{
+	DLPSensitivityPublic, DLPSensitivityInternal, DLPSensitivityConfidential, DLPSensitivityRestricted,
+}
+
+// DLPSensitivityRank orders sensitivity labels, or returns -1 for unknown
+// labels
+func DLPSensitivityRank(label string) int {
+	for i, sensitivity := range DLPSensitivities {
+		if sensitivity == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// What happens to tool calls whose arguments carry data more sensitive
+// than their server may receive
+const (
+	DLPActionBlock  = "block"
+	DLPActionRedact = "redact"
+	DLPActionLog    = "log"
+)
+
+// DLPFinding is sensitive data found in a tool argument. Findings never
+// carry the data itself.
+type DLPFinding struct {
+	// Path locates the argument, such as query or rows[2].email
+	Path        string `json:"path"`
+	Classifier  string `json:"classifier"`
+	Sensitivity string `json:"sensitivity"`
+}
+
+// DLPPolicy labels a server with the most sensitive data its tool calls
+// may carry and what happens to calls that carry more
+type DLPPolicy struct {
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	ServerID    string     `json:"server_id"`
+	Sensitivity string     `json:"sensitivity"`
+	Action      string     `json:"action"`
+	// Default reports that the server has no policy of its own and gets
+	// the gateway's default
+	Default bool `json:"default"`
+}
+
+// SetDLPPolicyRequest labels a server for outbound DLP checks
+type SetDLPPolicyRequest struct {
+	Sensitivity string `json:"sensitivity" binding:"required,oneof=public internal confidential restricted"`
+	Action      string `json:"action" binding:"required,oneof=block redact log"`
+}
+
+// DLPEvent records a tool call whose arguments carried data more sensitive
+// than its server may receive, and the action taken
+type DLPEvent struct {
+	CreatedAt      time.Time    `json:"created_at"`
+	Findings       []DLPFinding `json:"findings"`
+	ID             string       `json:"id"`
+	OrganizationID string       `json:"organization_id"`
+	NamespaceID    string       `json:"namespace_id"`
+	ServerID       string       `json:"server_id"`
+	Tool           string       `json:"tool"`
+	Action         string       `json:"action"`
+	UserID         string       `json:"user_id,omitempty"`
+	APIKeyID       string       `json:"api_key_id,omitempty"`
+}
@@ -31,6 +31,10 @@ type Endpoint struct {
 	CreatedBy          *string                `json:"created_by" db:"created_by"`
 	IsActive           bool                   `json:"is_active" db:"is_active"`
 	Metadata           map[string]interface{} `json:"metadata" db:"metadata"`
+	Labels             Labels                 `json:"labels,omitempty" db:"labels"`
+
+	// Unauthenticated status page at /api/public/status/:endpoint_name
+	EnableStatusPage   bool                   `json:"enable_status_page" db:"enable_status_page"`
 
 	// Computed fields
 	Namespace          *Namespace             `json:"namespace,omitempty"`
@@ -60,6 +64,8 @@ type CreateEndpointRequest struct {
 	AllowedOrigins     []string               `json:"allowed_origins"`
 	AllowedMethods     []string               `json:"allowed_methods"`
 	Metadata           map[string]interface{} `json:"metadata"`
+	Labels             Labels                 `json:"labels"`
+	EnableStatusPage   bool                   `json:"enable_status_page"`
 }
 
 // UpdateEndpointRequest represents the request to update an endpoint
@@ -75,6 +81,8 @@ type UpdateEndpointRequest struct {
 	AllowedMethods     []string               `json:"allowed_methods,omitempty"`
 	IsActive           *bool                  `json:"is_active,omitempty"`
 	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+	Labels             Labels                 `json:"labels,omitempty"`
+	EnableStatusPage   *bool                  `json:"enable_status_page,omitempty"`
 }
 
 // EndpointConfig represents the configuration for an endpoint (used in middleware)
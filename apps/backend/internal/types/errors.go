@@ -3,6 +3,8 @@ package types
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // Error represents a structured error
@@ -66,11 +68,19 @@ const (
 	ErrCodeRateLimitExceeded = "RATE_LIMIT_EXCEEDED"
 	ErrCodeQuotaExceeded     = "QUOTA_EXCEEDED"
 
+	// ErrCodeToolBudgetExceeded reports a tool whose daily call budget is
+	// used up
+	ErrCodeToolBudgetExceeded = "TOOL_BUDGET_EXCEEDED"
+	// ErrCodeBudgetExhausted reports an organization whose monthly budget
+	// stops its non-critical traffic
+	ErrCodeBudgetExhausted = "BUDGET_EXHAUSTED"
+
 	// Server errors
 	ErrCodeInternalError      = "INTERNAL_ERROR"
 	ErrCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
 	ErrCodeTimeout            = "TIMEOUT"
 	ErrCodeBadGateway         = "BAD_GATEWAY"
+	ErrCodeReadOnlyMode       = "READ_ONLY_MODE"
 
 	// Gateway errors
 	ErrCodeServerNotFound     = "SERVER_NOT_FOUND"
@@ -79,8 +89,32 @@ const (
 	ErrCodeCircuitBreakerOpen = "CIRCUIT_BREAKER_OPEN"
 
 	// Policy errors
-	ErrCodePolicyViolation = "POLICY_VIOLATION"
-	ErrCodeAccessDenied    = "ACCESS_DENIED"
+	ErrCodePolicyViolation  = "POLICY_VIOLATION"
+	ErrCodeAccessDenied     = "ACCESS_DENIED"
+	ErrCodeApprovalRequired = "APPROVAL_REQUIRED"
+	ErrCodeApprovalRejected = "APPROVAL_REJECTED"
+	ErrCodeApprovalExpired  = "APPROVAL_EXPIRED"
+
+	// Transport errors
+	ErrCodeUnsupportedSubprotocol = "UNSUPPORTED_SUBPROTOCOL"
+
+	// License errors
+	ErrCodeLicenseSeatsExceeded = "LICENSE_SEATS_EXCEEDED"
+
+	// Offline mode errors
+	ErrCodeConnectivityRequired = "CONNECTIVITY_REQUIRED"
+
+	// Data residency errors
+	ErrCodeResidencyViolation = "RESIDENCY_VIOLATION"
+
+	// Legal hold errors
+	ErrCodeLegalHold = "LEGAL_HOLD"
+
+	// Data loss prevention errors
+	ErrCodeDLPViolation = "DLP_VIOLATION"
+
+	// Geo access policy errors
+	ErrCodeGeoBlocked = "GEO_BLOCKED"
 )
 
 // NewError creates a new structured error
@@ -162,6 +196,22 @@ func NewQuotaExceededError(message string) *Error {
 	return NewError(ErrCodeQuotaExceeded, message, http.StatusTooManyRequests)
 }
 
+// NewToolBudgetExceededError reports a call to a tool whose daily budget is
+// used up until resetAt
+func NewToolBudgetExceededError(tool string, budget int64, resetAt time.Time) *Error {
+	return NewErrorWithDetails(ErrCodeToolBudgetExceeded,
+		fmt.Sprintf("The daily budget of %d calls of tool %s is used up", budget, tool),
+		"resets at "+resetAt.UTC().Format(time.RFC3339), http.StatusTooManyRequests)
+}
+
+// NewBudgetExhaustedError reports a call refused because the organization
+// used up its monthly budget of dimension until resetAt
+func NewBudgetExhaustedError(dimension string, limit int64, resetAt time.Time) *Error {
+	return NewErrorWithDetails(ErrCodeBudgetExhausted,
+		fmt.Sprintf("The organization used up its monthly budget of %d %s", limit, dimension),
+		"resets at "+resetAt.UTC().Format(time.RFC3339), http.StatusTooManyRequests)
+}
+
 // Server error constructors
 func NewInternalError(message string) *Error {
 	return NewError(ErrCodeInternalError, message, http.StatusInternalServerError)
@@ -171,6 +221,10 @@ func NewServiceUnavailableError(message string) *Error {
 	return NewError(ErrCodeServiceUnavailable, message, http.StatusServiceUnavailable)
 }
 
+func NewReadOnlyModeError(message string) *Error {
+	return NewError(ErrCodeReadOnlyMode, message, http.StatusServiceUnavailable)
+}
+
 func NewNotImplementedError(message string) *Error {
 	return NewError("NOT_IMPLEMENTED", message, http.StatusNotImplemented)
 }
@@ -209,6 +263,74 @@ func NewAccessDeniedError(message string) *Error {
 	return NewError(ErrCodeAccessDenied, message, http.StatusForbidden)
 }
 
+// NewApprovalRequiredError reports a call that a policy holds until someone
+// approves it
+func NewApprovalRequiredError(message, details string) *Error {
+	return NewErrorWithDetails(ErrCodeApprovalRequired, message, details, http.StatusForbidden)
+}
+
+// NewApprovalRejectedError reports a held call an approver rejected
+func NewApprovalRejectedError(approvalID, reason string) *Error {
+	message := "The tool call was rejected by an approver"
+	if reason != "" {
+		message += ": " + reason
+	}
+	return NewErrorWithDetails(ErrCodeApprovalRejected, message, "approval "+approvalID, http.StatusForbidden)
+}
+
+// NewApprovalExpiredError reports a held call nobody decided in time
+func NewApprovalExpiredError(approvalID string) *Error {
+	return NewErrorWithDetails(ErrCodeApprovalExpired,
+		"The tool call was not approved in time", "approval "+approvalID, http.StatusForbidden)
+}
+
+// Transport error constructors
+func NewUnsupportedSubprotocolError(requested, supported []string) *Error {
+	details := "supported subprotocols: " + strings.Join(supported, ", ")
+	if len(requested) > 0 {
+		details = "requested " + strings.Join(requested, ", ") + "; " + details
+	}
+	return NewErrorWithDetails(ErrCodeUnsupportedSubprotocol,
+		"No supported WebSocket subprotocol was requested", details, http.StatusBadRequest)
+}
+
+// License error constructors
+func NewLicenseSeatsExceededError(seats int) *Error {
+	return NewErrorWithDetails(ErrCodeLicenseSeatsExceeded,
+		"All licensed seats are in use", fmt.Sprintf("license allows %d active users", seats), http.StatusForbidden)
+}
+
+// Offline mode error constructors
+func NewConnectivityRequiredError(feature, details string) *Error {
+	message := "This feature requires outbound connectivity, which is disabled in offline mode"
+	if f, ok := LookupConnectivityFeature(feature); ok {
+		message = f.Description + " requires outbound connectivity, which is disabled in offline mode"
+	}
+	return NewErrorWithDetails(ErrCodeConnectivityRequired, message, details, http.StatusServiceUnavailable)
+}
+
+// Data residency error constructors
+func NewResidencyViolationError(message, details string) *Error {
+	return NewErrorWithDetails(ErrCodeResidencyViolation, message, details, http.StatusForbidden)
+}
+
+// Legal hold error constructors
+func NewLegalHoldError(orgID string) *Error {
+	return NewErrorWithDetails(ErrCodeLegalHold,
+		"Organization is under legal hold; its logs and audit records cannot be deleted or modified",
+		"organization "+orgID, http.StatusLocked)
+}
+
+// Data loss prevention error constructors
+func NewDLPViolationError(message, details string) *Error {
+	return NewErrorWithDetails(ErrCodeDLPViolation, message, details, http.StatusForbidden)
+}
+
+// Geo access policy error constructors
+func NewGeoBlockedError(message, details string) *Error {
+	return NewErrorWithDetails(ErrCodeGeoBlocked, message, details, http.StatusForbidden)
+}
+
 // IsError checks if an error is of a specific type
 func IsError(err error, code string) bool {
 	if e, ok := err.(*Error); ok {
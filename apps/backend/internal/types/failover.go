@@ -0,0 +1,83 @@
+package types
+
+import "time"
+
+// Deployment roles
+const (
+	DeploymentRoleActive  = "active"
+	DeploymentRoleStandby = "standby"
+)
+
+// Failover outcomes
+const (
+	FailoverStatusRunning   = "running"
+	FailoverStatusCompleted = "completed"
+	FailoverStatusPartial   = "partial"
+	FailoverStatusFailed    = "failed"
+)
+
+// Failover step outcomes. Dry runs report every step as planned.
+const (
+	FailoverStepDone    = "done"
+	FailoverStepSkipped = "skipped"
+	FailoverStepFailed  = "failed"
+	FailoverStepPlanned = "planned"
+)
+
+// DeploymentState is the role of this region's deployment. Only an active
+// deployment runs quota checks, telemetry and webhook deliveries.
+type DeploymentState struct {
+	ChangedAt      *time.Time `json:"changed_at,omitempty"`
+	Region         string     `json:"region"`
+	Role           string     `json:"role"`
+	ReadOnlyReason string     `json:"read_only_reason,omitempty"`
+	// FencedBy is the region that took over from this one
+	FencedBy  string `json:"fenced_by,omitempty"`
+	ChangedBy string `json:"changed_by,omitempty"`
+	// Epoch increases with every failover or fence, so instances notice
+	// the change
+	Epoch    int64 `json:"epoch"`
+	ReadOnly bool  `json:"read_only"`
+}
+
+// FailoverRequest promotes this region to take over from another
+type FailoverRequest struct {
+	FromRegion string `json:"from_region" binding:"required,max=100"`
+	Reason     string `json:"reason" binding:"required,max=500"`
+	// DryRun reports the steps a failover would take without changing
+	// anything
+	DryRun bool `json:"dry_run"`
+}
+
+// FenceRequest is sent by the region taking over to the one it replaces
+type FenceRequest struct {
+	Region string `json:"region" binding:"required,max=100"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// FailoverStep is one step of a failover and its outcome
+type FailoverStep struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Failover is a failover run in this region
+type Failover struct {
+	StartedAt   time.Time      `json:"started_at"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	ID          string         `json:"id"`
+	FromRegion  string         `json:"from_region"`
+	ToRegion    string         `json:"to_region"`
+	Reason      string         `json:"reason"`
+	InitiatedBy string         `json:"initiated_by"`
+	Status      string         `json:"status"`
+	Steps       []FailoverStep `json:"steps"`
+	DryRun      bool           `json:"dry_run"`
+}
+
+// FailoverStatus reports the deployment's role and its recent failovers
+type FailoverStatus struct {
+	State     *DeploymentState `json:"state"`
+	Failovers []*Failover      `json:"failovers"`
+}
@@ -0,0 +1,84 @@
+package types
+
+import "time"
+
+// Feature flag keys for subsystems that roll out gradually
+const (
+	FeatureSemanticSearch = "semantic_search"
+	FeatureAsyncExecution = "async_execution"
+)
+
+// Feature flag state sources, from lowest to highest precedence
+const (
+	FeatureFlagSourceDefault      = "default"
+	FeatureFlagSourceGlobal       = "global"
+	FeatureFlagSourceOrganization = "organization"
+	FeatureFlagSourceEnv          = "env"
+	// FeatureFlagSourceLicense marks an enterprise flag forced off because
+	// the license does not entitle it
+	FeatureFlagSourceLicense = "license"
+)
+
+// FeatureFlagDefinition declares a flag and its default state
+type FeatureFlagDefinition struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+	// Enterprise flags can only be enabled when the license entitles them
+	Enterprise bool `json:"enterprise,omitempty"`
+}
+
+// FeatureFlagDefinitions lists every flag the gateway knows about. Flags not
+// listed here cannot be set through the admin API.
+var FeatureFlagDefinitions = []FeatureFlagDefinition{
+	{
+		Key:         FeatureSemanticSearch,
+		Description: "Semantic search across tools, prompts and resources",
+		Enterprise:  true,
+	},
+	{
+		Key:         FeatureAsyncExecution,
+		Description: "Asynchronous tool execution with job polling",
+	},
+}
+
+// LookupFeatureFlag returns the definition for key
+func LookupFeatureFlag(key string) (FeatureFlagDefinition, bool) {
+	for _, def := range FeatureFlagDefinitions {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return FeatureFlagDefinition{}, false
+}
+
+// FeatureFlagOverride is a stored flag value, deployment-wide when
+// OrganizationID is empty
+type FeatureFlagOverride struct {
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ID             string    `json:"id" db:"id"`
+	Key            string    `json:"key" db:"key"`
+	OrganizationID string    `json:"organization_id,omitempty" db:"organization_id"`
+	UpdatedBy      string    `json:"updated_by,omitempty" db:"updated_by"`
+	Enabled        bool      `json:"enabled" db:"enabled"`
+}
+
+// FeatureFlagState is a flag resolved for one organization
+type FeatureFlagState struct {
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Key         string     `json:"key"`
+	Description string     `json:"description"`
+	Source      string     `json:"source"`
+	UpdatedBy   string     `json:"updated_by,omitempty"`
+	Enabled     bool       `json:"enabled"`
+	Default     bool       `json:"default"`
+	Enterprise  bool       `json:"enterprise,omitempty"`
+}
+
+// SetFeatureFlagRequest flips a flag for the caller's organization, or for
+// the whole deployment when Global is set
+type SetFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+	Global  bool  `json:"global"`
+}
@@ -20,9 +20,12 @@ type MCPServer struct {
 	ID             string            `json:"id" db:"id"`
 	Args           []string          `json:"args,omitempty" db:"args"`
 	Environment    []string          `json:"environment,omitempty" db:"environment"`
-	MaxRetries     int               `json:"max_retries" db:"max_retries"`
-	Timeout        time.Duration     `json:"timeout" db:"timeout"`
-	IsActive       bool              `json:"is_active" db:"is_active"`
+	// SecretFindings is set on registration and update when the submitted
+	// configuration contains likely credentials
+	SecretFindings []SecretFinding `json:"secret_findings,omitempty" db:"-"`
+	MaxRetries     int             `json:"max_retries" db:"max_retries"`
+	Timeout        time.Duration   `json:"timeout" db:"timeout"`
+	IsActive       bool            `json:"is_active" db:"is_active"`
 }
 
 // HealthCheck represents a health check result
@@ -38,12 +41,31 @@ type HealthCheck struct {
 
 // ServerStats represents basic server statistics
 type ServerStats struct {
-	LastRequest     time.Time `json:"last_request"`
-	ServerID        string    `json:"server_id"`
-	TotalRequests   int64     `json:"total_requests"`
-	SuccessRequests int64     `json:"success_requests"`
-	ErrorRequests   int64     `json:"error_requests"`
-	AvgLatency      float64   `json:"avg_latency"`
+	LastRequest     time.Time   `json:"last_request"`
+	ServerID        string      `json:"server_id"`
+	TotalRequests   int64       `json:"total_requests"`
+	SuccessRequests int64       `json:"success_requests"`
+	ErrorRequests   int64       `json:"error_requests"`
+	AvgLatency      float64     `json:"avg_latency"`
+	Retries         *RetryStats `json:"retries,omitempty"`
+}
+
+// RetryStats counts how failed calls to a server were retried
+type RetryStats struct {
+	// Calls is how many calls could have been retried
+	Calls int64 `json:"calls"`
+	// Retries is how many times failed calls were attempted again
+	Retries int64 `json:"retries"`
+	// Recovered is how many calls succeeded after a retry
+	Recovered int64 `json:"recovered"`
+	// Exhausted is how many calls still failed after the server's max_retries
+	Exhausted int64 `json:"exhausted"`
+	// BudgetDenied is how many failed calls were not retried because the
+	// protocol's retry budget was spent
+	BudgetDenied int64 `json:"budget_denied"`
+	// NotIdempotent is how many failed calls were not retried because they
+	// were neither safe nor carried an idempotency key
+	NotIdempotent int64 `json:"not_idempotent"`
 }
 
 // CreateMCPServerRequest represents an MCP server registration request
@@ -96,6 +118,8 @@ const (
 	ProtocolWebSocket = "websocket"
 	ProtocolSSE       = "sse"
 	ProtocolStdio     = "stdio"
+	ProtocolGRPC      = "grpc"
+	ProtocolMock      = "mock"
 )
 
 // Health check status constants
@@ -0,0 +1,68 @@
+package types
+
+// MCPProtocolVersion is the MCP protocol revision the gateway speaks
+const MCPProtocolVersion = "2024-11-05"
+
+// MCPProtocolVersions lists the MCP protocol revisions the gateway accepts
+var MCPProtocolVersions = []string{MCPProtocolVersion}
+
+// Authentication methods advertised by gateway discovery
+const (
+	DiscoveryAuthNone   = "none"
+	DiscoveryAuthAPIKey = "api_key"
+	DiscoveryAuthOAuth2 = "oauth2"
+)
+
+// Transport names advertised by gateway discovery, as used by MCP client configs
+const (
+	DiscoveryTransportStreamableHTTP = "streamable-http"
+	DiscoveryTransportSSE            = "sse"
+	DiscoveryTransportWebSocket      = "websocket"
+	DiscoveryTransportREST           = "rest"
+)
+
+// GatewayDiscoveryDocument is served at /.well-known/mcp-gateway so clients
+// can auto-configure transports and authentication. Without an endpoint the
+// transport URLs are templates containing {endpoint_name}.
+type GatewayDiscoveryDocument struct {
+	Endpoint         *DiscoveredEndpoint   `json:"endpoint,omitempty"`
+	OAuth            *DiscoveryOAuth       `json:"oauth,omitempty"`
+	Issuer           string                `json:"issuer"`
+	EndpointsURL     string                `json:"endpoints_url,omitempty"`
+	ProtocolVersions []string              `json:"protocol_versions"`
+	AuthMethods      []DiscoveryAuthMethod `json:"auth_methods"`
+	Transports       []DiscoveredTransport `json:"transports"`
+}
+
+// DiscoveredEndpoint identifies the endpoint and namespace a document describes
+type DiscoveredEndpoint struct {
+	Name          string `json:"name"`
+	Description   string `json:"description,omitempty"`
+	NamespaceID   string `json:"namespace_id"`
+	NamespaceName string `json:"namespace_name,omitempty"`
+	OpenAPIURL    string `json:"openapi_url"`
+	DocsURL       string `json:"docs_url"`
+}
+
+// DiscoveredTransport describes how to reach an endpoint over one transport
+type DiscoveredTransport struct {
+	Type         string   `json:"type"`
+	URL          string   `json:"url"`
+	MessageURL   string   `json:"message_url,omitempty"`
+	Methods      []string `json:"methods"`
+	Subprotocols []string `json:"subprotocols,omitempty"`
+	Preferred    bool     `json:"preferred"`
+}
+
+// DiscoveryAuthMethod describes one accepted way to authenticate
+type DiscoveryAuthMethod struct {
+	Type string `json:"type"`
+	// Locations lists where credentials may be sent: header, bearer or query
+	Locations []string `json:"locations,omitempty"`
+}
+
+// DiscoveryOAuth points to the OAuth metadata documents
+type DiscoveryOAuth struct {
+	AuthorizationServer string `json:"authorization_server_metadata"`
+	ProtectedResource   string `json:"protected_resource_metadata"`
+}
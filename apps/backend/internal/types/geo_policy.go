@@ -0,0 +1,74 @@
+package types
+
+import "time"
+
+// What happens to calls from a client an endpoint's geo policy has no
+// rule for
+const (
+	GeoActionAllow = "allow"
+	GeoActionDeny  = "deny"
+)
+
+// GeoIPInfo locates a client IP address. Fields the GeoIP databases do not
+// know are empty, as they are for private addresses.
+type GeoIPInfo struct {
+	IP string `json:"ip"`
+	// Country is the ISO 3166-1 alpha-2 code, such as DE
+	Country        string `json:"country,omitempty"`
+	ASOrganization string `json:"as_organization,omitempty"`
+	ASN            int64  `json:"asn,omitempty"`
+}
+
+// EndpointGeoPolicy allows or denies calls to an endpoint by the country
+// and autonomous system of the client. Deny rules win over allow rules;
+// when any allow rule is set, clients matching none are denied.
+type EndpointGeoPolicy struct {
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+	EndpointID     string     `json:"endpoint_id"`
+	AllowCountries []string   `json:"allow_countries"`
+	DenyCountries  []string   `json:"deny_countries"`
+	AllowASNs      []int64    `json:"allow_asns"`
+	DenyASNs       []int64    `json:"deny_asns"`
+	// UnknownAction applies to clients the GeoIP databases cannot locate
+	UnknownAction string `json:"unknown_action"`
+	// LogAllowed records allowed calls as well as denied ones
+	LogAllowed bool `json:"log_allowed"`
+}
+
+// SetEndpointGeoPolicyRequest replaces the geo policy of an endpoint
+type SetEndpointGeoPolicyRequest struct {
+	AllowCountries []string `json:"allow_countries"`
+	DenyCountries  []string `json:"deny_countries"`
+	AllowASNs      []int64  `json:"allow_asns"`
+	DenyASNs       []int64  `json:"deny_asns"`
+	UnknownAction  string   `json:"unknown_action" binding:"omitempty,oneof=allow deny"`
+	LogAllowed     bool     `json:"log_allowed"`
+}
+
+// TestGeoPolicyRequest asks whether a client IP address may call an
+// endpoint, under its saved policy or under Policy when set
+type TestGeoPolicyRequest struct {
+	Policy *SetEndpointGeoPolicyRequest `json:"policy,omitempty"`
+	IP     string                       `json:"ip" binding:"required"`
+}
+
+// GeoDecision is the outcome of a geo policy for a client
+type GeoDecision struct {
+	GeoIPInfo
+	// Rule names the rule that decided, such as deny_countries, or is
+	// empty when the client matched no rule
+	Rule    string `json:"rule,omitempty"`
+	Reason  string `json:"reason"`
+	Allowed bool   `json:"allowed"`
+}
+
+// GeoDecisionEvent records a geo policy decision for a call to an
+// endpoint
+type GeoDecisionEvent struct {
+	CreatedAt      time.Time `json:"created_at"`
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	EndpointID     string    `json:"endpoint_id"`
+	GeoDecision
+}
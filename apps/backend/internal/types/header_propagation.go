@@ -0,0 +1,70 @@
+package types
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Scopes header propagation rules apply to. Namespace rules apply to every
+// server of the namespace; server rules apply wherever the server is called
+// and take precedence.
+const (
+	HeaderPropagationScopeServer    = "server"
+	HeaderPropagationScopeNamespace = "namespace"
+)
+
+// HeaderPropagationRules decide which headers of the caller's request reach
+// an upstream server. Header patterns are case-insensitive names with an
+// optional trailing "*", e.g. "X-Tenant-*". Set templates may reference
+// {{header.<name>}}, {{claims.<sub|email|organization_id|type|api_key_id|client_id>}},
+// {{labels.<key>}}, {{namespace.id}}, {{server.id}} and {{server.name}}; a
+// header whose template resolves to nothing is not sent.
+type HeaderPropagationRules struct {
+	// Set derives headers from templates and overrides propagated headers
+	Set map[string]string `json:"set,omitempty"`
+	// Propagate lists the inbound headers copied to the upstream request
+	Propagate []string `json:"propagate,omitempty"`
+	// Strip lists inbound headers never copied, even when Propagate
+	// matches them
+	Strip []string `json:"strip,omitempty"`
+}
+
+// HeaderPropagationSettings are the header propagation rules of a server or
+// namespace
+type HeaderPropagationSettings struct {
+	UpdatedAt *time.Time             `json:"updated_at,omitempty"`
+	Rules     HeaderPropagationRules `json:"rules"`
+	ScopeType string                 `json:"scope_type"`
+	ScopeID   string                 `json:"scope_id"`
+}
+
+type inboundHeadersKey struct{}
+
+type upstreamHeadersKey struct{}
+
+// WithInboundHeaders returns a copy of ctx carrying the headers of the
+// caller's request, for propagation to upstream servers
+func WithInboundHeaders(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, inboundHeadersKey{}, header)
+}
+
+// InboundHeadersFromContext returns the headers of the caller's request, or
+// nil outside a request
+func InboundHeadersFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(inboundHeadersKey{}).(http.Header)
+	return header
+}
+
+// WithUpstreamHeaders returns a copy of ctx carrying the headers to send on
+// requests to the upstream server
+func WithUpstreamHeaders(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, upstreamHeadersKey{}, header)
+}
+
+// UpstreamHeadersFromContext returns the headers to send on requests to the
+// upstream server, or nil when nothing is propagated
+func UpstreamHeadersFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(upstreamHeadersKey{}).(http.Header)
+	return header
+}
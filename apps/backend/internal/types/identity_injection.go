@@ -0,0 +1,88 @@
+package types
+
+import "time"
+
+// Where the gateway places the caller's identity on tool calls to a server
+const (
+	// IdentityTargetOff sends no identity
+	IdentityTargetOff = "off"
+	// IdentityTargetMeta adds the identity to the request's _meta under
+	// IdentityMetaKey
+	IdentityTargetMeta = "meta"
+	// IdentityTargetArgument passes the identity as a tool argument
+	IdentityTargetArgument = "argument"
+)
+
+// IdentityMetaKey is the _meta key of the identity asserted by the gateway
+const IdentityMetaKey = "io.omnimesh/identity"
+
+// IdentityAssertionVersion is the version of the IdentityAssertion schema.
+// It changes whenever fields are added, so that servers can reject
+// assertions they do not understand.
+const IdentityAssertionVersion = 1
+
+// IdentityAssertionIssuer names the gateway as the source of assertions
+const IdentityAssertionIssuer = "omnimesh-gateway"
+
+// Identity fields a server can receive
+const (
+	IdentityFieldUserID         = "user_id"
+	IdentityFieldEmail          = "email"
+	IdentityFieldOrganizationID = "organization_id"
+	IdentityFieldPrincipalType  = "principal_type"
+	IdentityFieldAPIKeyID       = "api_key_id"
+	IdentityFieldClientID       = "client_id"
+	IdentityFieldOnBehalfOf     = "on_behalf_of"
+	IdentityFieldLabels         = "labels"
+	IdentityFieldNamespaceID    = "namespace_id"
+)
+
+// IdentityFields are the identity fields in the order they are documented
+var IdentityFields = []string{
+	IdentityFieldUserID,
+	IdentityFieldEmail,
+	IdentityFieldOrganizationID,
+	IdentityFieldPrincipalType,
+	IdentityFieldAPIKeyID,
+	IdentityFieldClientID,
+	IdentityFieldOnBehalfOf,
+	IdentityFieldLabels,
+	IdentityFieldNamespaceID,
+}
+
+// IdentityAssertion is the caller identity the gateway asserts to a server.
+// Only the fields the server's settings select are set. Callers cannot
+// supply or alter it: whatever a caller sent under the same _meta key or
+// argument is replaced.
+type IdentityAssertion struct {
+	Labels         Labels `json:"labels,omitempty"`
+	Issuer         string `json:"iss"`
+	UserID         string `json:"user_id,omitempty"`
+	Email          string `json:"email,omitempty"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	PrincipalType  string `json:"principal_type,omitempty"`
+	APIKeyID       string `json:"api_key_id,omitempty"`
+	ClientID       string `json:"client_id,omitempty"`
+	OnBehalfOf     string `json:"on_behalf_of,omitempty"`
+	NamespaceID    string `json:"namespace_id,omitempty"`
+	Version        int    `json:"version"`
+}
+
+// IdentityInjectionSettings select the identity fields a server receives on
+// tool calls and where they are placed
+type IdentityInjectionSettings struct {
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	ServerID  string     `json:"server_id"`
+	Target    string     `json:"target"`
+	// Argument is the tool argument that carries the identity when Target
+	// is argument
+	Argument string   `json:"argument,omitempty"`
+	Fields   []string `json:"fields"`
+}
+
+// SetIdentityInjectionRequest enables identity injection for a server
+type SetIdentityInjectionRequest struct {
+	Target   string   `json:"target" binding:"required,oneof=meta argument"`
+	Argument string   `json:"argument"`
+	Fields   []string `json:"fields" binding:"required,min=1"`
+}
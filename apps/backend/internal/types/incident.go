@@ -0,0 +1,99 @@
+package types
+
+import "time"
+
+// Incident severities
+const (
+	IncidentSeverityMinor    = "minor"
+	IncidentSeverityMajor    = "major"
+	IncidentSeverityCritical = "critical"
+)
+
+// IncidentSourceDeclared marks status page incidents declared by an admin
+const IncidentSourceDeclared = "declared"
+
+// Incident is an admin-declared service disruption affecting servers and
+// namespaces. It is ongoing until it has an end time.
+type Incident struct {
+	StartedAt      time.Time  `json:"started_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	ID             string     `json:"id"`
+	OrganizationID string     `json:"organization_id"`
+	Title          string     `json:"title"`
+	Severity       string     `json:"severity"`
+	Notes          string     `json:"notes,omitempty"`
+	PostmortemURL  string     `json:"postmortem_url,omitempty"`
+	CreatedBy      string     `json:"created_by"`
+	ServerIDs      []string   `json:"server_ids"`
+	NamespaceIDs   []string   `json:"namespace_ids"`
+}
+
+// Ongoing reports whether the incident has not ended
+func (i *Incident) Ongoing() bool {
+	return i != nil && i.EndedAt == nil
+}
+
+// StatusIncident is the public view of the incident. Notes and postmortem
+// links stay internal.
+func (i *Incident) StatusIncident() StatusIncident {
+	incident := StatusIncident{
+		StartedAt: i.StartedAt,
+		EndedAt:   i.EndedAt,
+		Title:     i.Title,
+		Impact:    ServiceStatusDegraded,
+		State:     IncidentStateResolved,
+		Source:    IncidentSourceDeclared,
+	}
+	if i.Severity == IncidentSeverityCritical {
+		incident.Impact = ServiceStatusDown
+	}
+	if i.Ongoing() {
+		incident.State = IncidentStateOngoing
+	}
+	return incident
+}
+
+// CreateIncidentRequest declares an incident. StartedAt defaults to now.
+type CreateIncidentRequest struct {
+	StartedAt     *time.Time `json:"started_at"`
+	EndedAt       *time.Time `json:"ended_at"`
+	Title         string     `json:"title" binding:"required,max=255"`
+	Severity      string     `json:"severity" binding:"required,oneof=minor major critical"`
+	Notes         string     `json:"notes"`
+	PostmortemURL string     `json:"postmortem_url" binding:"omitempty,url"`
+	ServerIDs     []string   `json:"server_ids" binding:"dive,uuid"`
+	NamespaceIDs  []string   `json:"namespace_ids" binding:"dive,uuid"`
+}
+
+// UpdateIncidentRequest changes an incident. Setting EndedAt resolves it.
+type UpdateIncidentRequest struct {
+	StartedAt     *time.Time `json:"started_at"`
+	EndedAt       *time.Time `json:"ended_at"`
+	Title         *string    `json:"title" binding:"omitempty,min=1,max=255"`
+	Severity      *string    `json:"severity" binding:"omitempty,oneof=minor major critical"`
+	Notes         *string    `json:"notes"`
+	PostmortemURL *string    `json:"postmortem_url" binding:"omitempty,url"`
+	ServerIDs     *[]string  `json:"server_ids" binding:"omitempty,dive,uuid"`
+	NamespaceIDs  *[]string  `json:"namespace_ids" binding:"omitempty,dive,uuid"`
+}
+
+// IncidentFilter selects incidents of an organization. From and To select
+// incidents overlapping that period, which is how timelines are annotated.
+type IncidentFilter struct {
+	From           *time.Time
+	To             *time.Time
+	OrganizationID string
+	ServerID       string
+	NamespaceID    string
+	OngoingOnly    bool
+}
+
+// ServerHealthHistory is a server's recent health checks annotated with the
+// incidents that affected it over the same period
+type ServerHealthHistory struct {
+	ServerID  string         `json:"server_id"`
+	Checks    []*HealthCheck `json:"checks"`
+	Incidents []*Incident    `json:"incidents"`
+}
@@ -0,0 +1,48 @@
+package types
+
+import "time"
+
+const (
+	// MaxInspectorCollectionRequests bounds the requests saved in an
+	// inspector collection
+	MaxInspectorCollectionRequests = 100
+)
+
+// InspectorCollection is a named, ordered set of JSON-RPC requests saved
+// for a server, so that debugging it can be repeated. Collections are
+// shared by the organization.
+type InspectorCollection struct {
+	CreatedAt      time.Time                    `json:"created_at"`
+	UpdatedAt      time.Time                    `json:"updated_at"`
+	Requests       []InspectorCollectionRequest `json:"requests"`
+	ID             string                       `json:"id"`
+	OrganizationID string                       `json:"organization_id"`
+	ServerID       string                       `json:"server_id"`
+	Name           string                       `json:"name"`
+	Description    string                       `json:"description"`
+	CreatedBy      string                       `json:"created_by,omitempty"`
+}
+
+// InspectorCollectionRequest is a saved request. Notifications are sent
+// without an id and get no response.
+type InspectorCollectionRequest struct {
+	Params       map[string]interface{} `json:"params,omitempty"`
+	Name         string                 `json:"name"`
+	Method       string                 `json:"method" binding:"required"`
+	Notification bool                   `json:"notification,omitempty"`
+}
+
+// CreateInspectorCollectionRequest saves a collection for a server
+type CreateInspectorCollectionRequest struct {
+	Name        string                       `json:"name" binding:"required,max=255"`
+	Description string                       `json:"description"`
+	Requests    []InspectorCollectionRequest `json:"requests" binding:"dive"`
+}
+
+// UpdateInspectorCollectionRequest changes a collection; Requests replaces
+// all of its requests
+type UpdateInspectorCollectionRequest struct {
+	Name        string                        `json:"name" binding:"omitempty,max=255"`
+	Description *string                       `json:"description"`
+	Requests    *[]InspectorCollectionRequest `json:"requests" binding:"omitempty,dive"`
+}
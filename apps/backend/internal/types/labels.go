@@ -0,0 +1,105 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Labels are free-form attribution tags (team, project, cost-center, ...)
+// attached to API keys and endpoints and propagated into usage records
+type Labels map[string]string
+
+// Well-known attribution label keys
+const (
+	LabelTeam       = "team"
+	LabelProject    = "project"
+	LabelCostCenter = "cost-center"
+)
+
+// Label limits
+const (
+	MaxLabelsPerResource = 20
+	MaxLabelValueLength  = 128
+)
+
+var labelKeyPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9._-]{0,61}[a-z0-9])?$`)
+
+// Validate checks label keys and values against the allowed format
+func (l Labels) Validate() error {
+	if len(l) > MaxLabelsPerResource {
+		return fmt.Errorf("at most %d labels are allowed", MaxLabelsPerResource)
+	}
+	for key, value := range l {
+		if !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid label key %q: must be lowercase alphanumeric, '.', '_' or '-' and at most 63 characters", key)
+		}
+		if len(value) > MaxLabelValueLength {
+			return fmt.Errorf("label %q value exceeds %d characters", key, MaxLabelValueLength)
+		}
+	}
+	return nil
+}
+
+// Merge returns a new label set with other layered on top of l
+func (l Labels) Merge(other Labels) Labels {
+	if len(l) == 0 && len(other) == 0 {
+		return nil
+	}
+	merged := make(Labels, len(l)+len(other))
+	for k, v := range l {
+		merged[k] = v
+	}
+	for k, v := range other {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Keys returns the label keys in sorted order
+func (l Labels) Keys() []string {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Scan implements the sql.Scanner interface
+func (l *Labels) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan type %T into Labels", value)
+	}
+
+	if len(data) == 0 {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(data, l)
+}
+
+// Value implements the driver.Valuer interface
+func (l Labels) Value() (driver.Value, error) {
+	if l == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,31 @@
+package types
+
+import "time"
+
+// LegalHold suspends deletion of an organization's logs and audit records.
+// A hold is active until ReleasedAt is set.
+type LegalHold struct {
+	PlacedAt       time.Time  `json:"placed_at" db:"placed_at"`
+	ReleasedAt     *time.Time `json:"released_at,omitempty" db:"released_at"`
+	ID             string     `json:"id" db:"id"`
+	OrganizationID string     `json:"organization_id" db:"organization_id"`
+	Reason         string     `json:"reason" db:"reason"`
+	PlacedBy       string     `json:"placed_by" db:"placed_by"`
+	ReleasedBy     string     `json:"released_by,omitempty" db:"released_by"`
+	ReleaseReason  string     `json:"release_reason,omitempty" db:"release_reason"`
+}
+
+// Active reports whether the hold is still in force
+func (h *LegalHold) Active() bool {
+	return h != nil && h.ReleasedAt == nil
+}
+
+// PlaceLegalHoldRequest places a hold on an organization
+type PlaceLegalHoldRequest struct {
+	Reason string `json:"reason" binding:"required,max=1000"`
+}
+
+// ReleaseLegalHoldRequest lifts an organization's hold
+type ReleaseLegalHoldRequest struct {
+	Reason string `json:"reason" binding:"required,max=1000"`
+}
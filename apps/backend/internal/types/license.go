@@ -0,0 +1,45 @@
+package types
+
+import "time"
+
+// License states reported by the license status endpoint
+const (
+	// LicenseStatusCommunity means no license is installed; enterprise
+	// features are off and seats are unlimited
+	LicenseStatusCommunity = "community"
+	LicenseStatusValid     = "valid"
+	// LicenseStatusGracePeriod means the license expired but entitlements are
+	// kept until the grace period ends
+	LicenseStatusGracePeriod = "grace_period"
+	LicenseStatusExpired     = "expired"
+	LicenseStatusInvalid     = "invalid"
+)
+
+// LicenseEntitlementAll in a license's features entitles every feature
+const LicenseEntitlementAll = "*"
+
+// License is the verified content of a signed license key
+type License struct {
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ID        string    `json:"id"`
+	Licensee  string    `json:"licensee"`
+	Issuer    string    `json:"issuer"`
+	Features  []string  `json:"features"`
+	// Seats caps active non-service users; zero means unlimited
+	Seats     int `json:"seats"`
+	GraceDays int `json:"grace_days,omitempty"`
+}
+
+// LicenseStatus is the deployment's current license state
+type LicenseStatus struct {
+	CheckedAt     time.Time  `json:"checked_at"`
+	GraceEndsAt   *time.Time `json:"grace_ends_at,omitempty"`
+	License       *License   `json:"license,omitempty"`
+	Status        string     `json:"status"`
+	Message       string     `json:"message,omitempty"`
+	Entitlements  []string   `json:"entitlements"`
+	SeatsUsed     int        `json:"seats_used"`
+	SeatsLimit    int        `json:"seats_limit"`
+	DaysRemaining int        `json:"days_remaining"`
+}
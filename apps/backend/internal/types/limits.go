@@ -0,0 +1,65 @@
+package types
+
+import "time"
+
+// Rate limit bucket scopes
+const (
+	RateLimitScopeIP         = "ip"
+	RateLimitScopeEndpoint   = "endpoint"
+	RateLimitScopeSandbox    = "sandbox"
+	RateLimitScopeStatusPage = "status_page"
+)
+
+// RateLimitBucket is the state of one rate-limit window as seen by the
+// request that passed through it
+type RateLimitBucket struct {
+	ResetAt   time.Time `json:"reset_at"`
+	Scope     string    `json:"scope"`
+	Key       string    `json:"key"`
+	Window    string    `json:"window"`
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	Exceeded  bool      `json:"exceeded"`
+}
+
+// RateLimitSubject identifies whom a request counts against in the
+// organization's rate limit rules. Empty fields match no rule of their type.
+type RateLimitSubject struct {
+	OrganizationID string
+	UserID         string
+	APIKeyID       string
+	EndpointID     string
+}
+
+// QuotaUsage pairs a plan limit with current consumption
+type QuotaUsage struct {
+	Limit int `json:"limit"`
+	Used  int `json:"used"`
+}
+
+// PlanQuotas are the organization-level limits of the caller's plan
+type PlanQuotas struct {
+	OrganizationID   string     `json:"organization_id"`
+	PlanType         string     `json:"plan_type"`
+	Servers          QuotaUsage `json:"servers"`
+	Sessions         QuotaUsage `json:"sessions"`
+	LogRetentionDays int        `json:"log_retention_days"`
+}
+
+// PrincipalUsageSummary is the caller's recent activity
+type PrincipalUsageSummary struct {
+	LastSeen       *time.Time `json:"last_seen,omitempty"`
+	Window         string     `json:"window"`
+	Requests       int64      `json:"requests"`
+	ToolExecutions int64      `json:"tool_executions"`
+	Errors         int64      `json:"errors"`
+}
+
+// LimitsReport is returned by GET /api/auth/limits
+type LimitsReport struct {
+	GeneratedAt time.Time              `json:"generated_at"`
+	Principal   *Principal             `json:"principal,omitempty"`
+	Quotas      *PlanQuotas            `json:"quotas,omitempty"`
+	Usage       *PrincipalUsageSummary `json:"usage,omitempty"`
+	Buckets     []RateLimitBucket      `json:"buckets"`
+}
@@ -0,0 +1,58 @@
+package types
+
+import "time"
+
+// Formats execution logs can be exported in
+const (
+	LogExportFormatCSV     = "csv"
+	LogExportFormatParquet = "parquet"
+)
+
+// Log export statuses
+const (
+	LogExportStatusPending   = "pending"
+	LogExportStatusRunning   = "running"
+	LogExportStatusCompleted = "completed"
+	LogExportStatusFailed    = "failed"
+	LogExportStatusExpired   = "expired"
+)
+
+// LogExportLimits bound the exports of an organization's plan. Exports
+// stop at MaxRows rows or MaxBytes bytes and are marked truncated.
+type LogExportLimits struct {
+	MaxRows      int   `json:"max_rows"`
+	MaxBytes     int64 `json:"max_bytes"`
+	MaxRangeDays int   `json:"max_range_days"`
+}
+
+// LogExport is a bulk export of execution logs, produced asynchronously by
+// the worker. DownloadURL is a signed link set once the export completed.
+type LogExport struct {
+	CreatedAt      time.Time      `json:"created_at"`
+	StartTime      time.Time      `json:"start_time"`
+	EndTime        time.Time      `json:"end_time"`
+	StartedAt      *time.Time     `json:"started_at,omitempty"`
+	CompletedAt    *time.Time     `json:"completed_at,omitempty"`
+	ExpiresAt      *time.Time     `json:"expires_at,omitempty"`
+	Filters        LogViewFilters `json:"filters"`
+	ID             string         `json:"id"`
+	OrganizationID string         `json:"organization_id"`
+	RequestedBy    string         `json:"requested_by,omitempty"`
+	Format         string         `json:"format"`
+	Status         string         `json:"status"`
+	StorageKey     string         `json:"-"`
+	Error          string         `json:"error,omitempty"`
+	DownloadURL    string         `json:"download_url,omitempty"`
+	RowCount       int64          `json:"row_count"`
+	SizeBytes      int64          `json:"size_bytes"`
+	Truncated      bool           `json:"truncated"`
+}
+
+// CreateLogExportRequest requests an export of the logs between StartTime
+// and EndTime matching Filters. The filters' window is ignored.
+type CreateLogExportRequest struct {
+	StartTime time.Time      `json:"start_time" form:"start_time" binding:"required"`
+	EndTime   time.Time      `json:"end_time" form:"end_time" binding:"required"`
+	Filters   LogViewFilters `json:"filters"`
+	Format    string         `json:"format" form:"format" binding:"required,oneof=csv parquet"`
+}
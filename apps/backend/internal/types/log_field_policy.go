@@ -0,0 +1,60 @@
+package types
+
+import "time"
+
+// Optional fields of request logs an organization can leave out. Method,
+// path, status, timing and the caller are always logged.
+const (
+	LogFieldRequestBody  = "request_body"
+	LogFieldResponseBody = "response_body"
+	LogFieldQueryParams  = "query_params"
+	LogFieldClient       = "client"
+)
+
+// LogFields lists the optional request log fields
+var LogFields = []string{LogFieldRequestBody, LogFieldResponseBody, LogFieldQueryParams, LogFieldClient}
+
+// Log field presets
+const (
+	// LogFieldPresetFull logs every field
+	LogFieldPresetFull = "full"
+	// LogFieldPresetMetadataOnly leaves out bodies and query strings
+	LogFieldPresetMetadataOnly = "metadata_only"
+)
+
+// LogFieldPresetOmits returns the fields a preset leaves out
+func LogFieldPresetOmits(preset string) []string {
+	if preset == LogFieldPresetMetadataOnly {
+		return []string{LogFieldRequestBody, LogFieldResponseBody, LogFieldQueryParams}
+	}
+	return nil
+}
+
+// LogFieldPolicy selects which optional fields the request logger records
+// for an organization. Organizations without a policy log every field.
+type LogFieldPolicy struct {
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+	OrganizationID string     `json:"organization_id"`
+	UpdatedBy      string     `json:"updated_by,omitempty"`
+	OmitFields     []string   `json:"omit_fields"`
+}
+
+// Omits reports whether the policy leaves field out of request logs
+func (p *LogFieldPolicy) Omits(field string) bool {
+	if p == nil {
+		return false
+	}
+	for _, omitted := range p.OmitFields {
+		if omitted == field {
+			return true
+		}
+	}
+	return false
+}
+
+// SetLogFieldPolicyRequest replaces an organization's log field policy. The
+// fields left out are those of the preset plus omit_fields.
+type SetLogFieldPolicyRequest struct {
+	Preset     string   `json:"preset" binding:"omitempty,oneof=full metadata_only"`
+	OmitFields []string `json:"omit_fields" binding:"omitempty,dive,oneof=request_body response_body query_params client"`
+}
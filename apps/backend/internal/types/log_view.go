@@ -0,0 +1,94 @@
+package types
+
+import "time"
+
+// Kinds of records a saved log view queries
+const (
+	LogViewKindLogs  = "logs"
+	LogViewKindAudit = "audit"
+)
+
+// Widgets that can be pinned to a saved log view
+const (
+	LogWidgetErrorRateByServer = "error_rate_by_server"
+	LogWidgetTopTools          = "top_tools"
+	LogWidgetUsageByPrincipal  = "usage_by_principal"
+	LogWidgetUsageByClient     = "usage_by_client"
+)
+
+// Saved log view limits
+const (
+	DefaultLogViewWindow  = 24 * time.Hour
+	MaxLogViewWindow      = 90 * 24 * time.Hour
+	MaxLogViewWidgets     = 12
+	DefaultLogWidgetLimit = 10
+	MaxLogWidgetLimit     = 100
+)
+
+// LogViewFilters are the filters of a saved log view. The time range is
+// relative to when the view is opened: Window is a duration such as "24h"
+// ending now. Log views use the log fields and audit views the audit ones.
+type LogViewFilters struct {
+	Labels        map[string]string `json:"labels,omitempty"`
+	Window        string            `json:"window,omitempty"`
+	Level         string            `json:"level,omitempty"`
+	UserID        string            `json:"user_id,omitempty"`
+	Method        string            `json:"method,omitempty"`
+	Path          string            `json:"path,omitempty"`
+	Search        string            `json:"search,omitempty"`
+	PrincipalType string            `json:"principal_type,omitempty"`
+	PrincipalID   string            `json:"principal_id,omitempty"`
+	Action        string            `json:"action,omitempty"`
+	Resource      string            `json:"resource,omitempty"`
+	ResourceID    string            `json:"resource_id,omitempty"`
+	MinSeverity   string            `json:"min_severity,omitempty"`
+}
+
+// LogWidget is an aggregate pinned to a saved log view
+type LogWidget struct {
+	Type  string `json:"type" binding:"required,oneof=error_rate_by_server top_tools usage_by_principal usage_by_client"`
+	Title string `json:"title,omitempty" binding:"max=100"`
+	Limit int    `json:"limit,omitempty" binding:"min=0,max=100"`
+}
+
+// SavedLogView is a named log or audit query, private to its owner unless
+// shared with the organization
+type SavedLogView struct {
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	Filters        LogViewFilters `json:"filters"`
+	Widgets        []LogWidget    `json:"widgets"`
+	ID             string         `json:"id"`
+	OrganizationID string         `json:"organization_id"`
+	OwnerID        string         `json:"owner_id"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description,omitempty"`
+	Kind           string         `json:"kind"`
+	Shared         bool           `json:"shared"`
+}
+
+// CreateLogViewRequest saves a log view
+type CreateLogViewRequest struct {
+	Filters     LogViewFilters `json:"filters"`
+	Widgets     []LogWidget    `json:"widgets" binding:"omitempty,dive"`
+	Name        string         `json:"name" binding:"required,min=1,max=100"`
+	Description string         `json:"description,omitempty"`
+	Kind        string         `json:"kind" binding:"required,oneof=logs audit"`
+	Shared      bool           `json:"shared"`
+}
+
+// UpdateLogViewRequest changes a saved log view; fields left out keep their
+// value
+type UpdateLogViewRequest struct {
+	Filters     *LogViewFilters `json:"filters,omitempty"`
+	Widgets     *[]LogWidget    `json:"widgets,omitempty"`
+	Name        *string         `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Description *string         `json:"description,omitempty"`
+	Shared      *bool           `json:"shared,omitempty"`
+}
+
+// LogWidgetResult is a pinned widget with its data computed over the view
+type LogWidgetResult struct {
+	Data   interface{} `json:"data"`
+	Widget LogWidget   `json:"widget"`
+}
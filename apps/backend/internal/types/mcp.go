@@ -184,7 +184,12 @@ const (
 type LoggingMetrics struct {
 	CurrentLevel    string `json:"current_level"`
 	BackendType     string `json:"backend_type"`
+	OverflowPolicy  string `json:"overflow_policy,omitempty"`
 	BufferSize      int    `json:"buffer_size"`
+	BufferCapacity  int    `json:"buffer_capacity,omitempty"`
+	AuditBufferSize int    `json:"audit_buffer_size,omitempty"`
 	SubscriberCount int    `json:"subscriber_count"`
+	DroppedEntries  int64  `json:"dropped_entries"`
+	FailedEntries   int64  `json:"failed_entries"`
 	AsyncMode       bool   `json:"async_mode"`
 }
@@ -0,0 +1,50 @@
+package types
+
+import "time"
+
+// MCPMessageLog is a structured record of a single MCP JSON-RPC message
+// exchanged through the gateway. Params are stored with sensitive fields
+// redacted; RedactedFields lists the dotted paths that were masked.
+type MCPMessageLog struct {
+	CreatedAt      time.Time              `json:"created_at" db:"created_at"`
+	Params         map[string]interface{} `json:"params,omitempty" db:"params"`
+	ID             string                 `json:"id" db:"id"`
+	OrganizationID string                 `json:"organization_id,omitempty" db:"organization_id"`
+	NamespaceID    string                 `json:"namespace_id,omitempty" db:"namespace_id"`
+	EndpointID     string                 `json:"endpoint_id,omitempty" db:"endpoint_id"`
+	SessionID      string                 `json:"session_id,omitempty" db:"session_id"`
+	Transport      string                 `json:"transport" db:"transport"`
+	Method         string                 `json:"method" db:"method"`
+	ToolName       string                 `json:"tool_name,omitempty" db:"tool_name"`
+	RPCID          string                 `json:"rpc_id,omitempty" db:"rpc_id"`
+	PrincipalType  string                 `json:"principal_type,omitempty" db:"principal_type"`
+	PrincipalID    string                 `json:"principal_id,omitempty" db:"principal_id"`
+	OnBehalfOf     string                 `json:"on_behalf_of,omitempty" db:"on_behalf_of"`
+	ErrorMessage   string                 `json:"error_message,omitempty" db:"error_message"`
+	RedactedFields []string               `json:"redacted_fields,omitempty" db:"redacted_fields"`
+	ErrorCode      int                    `json:"error_code,omitempty" db:"error_code"`
+	StatusCode     int                    `json:"status_code,omitempty" db:"status_code"`
+	DurationMS     int64                  `json:"duration_ms" db:"duration_ms"`
+	RequestSize    int64                  `json:"request_size" db:"request_size"`
+	ResultSize     int64                  `json:"result_size" db:"result_size"`
+	IsError        bool                   `json:"is_error" db:"is_error"`
+}
+
+// MCPMessageLogQuery filters MCP message log queries
+type MCPMessageLogQuery struct {
+	StartTime   *time.Time `json:"start_time,omitempty" form:"start_time"`
+	EndTime     *time.Time `json:"end_time,omitempty" form:"end_time"`
+	NamespaceID string     `json:"namespace_id,omitempty" form:"namespace_id"`
+	EndpointID  string     `json:"endpoint_id,omitempty" form:"endpoint_id"`
+	SessionID   string     `json:"session_id,omitempty" form:"session_id"`
+	Method      string     `json:"method,omitempty" form:"method"`
+	ToolName    string     `json:"tool_name,omitempty" form:"tool_name"`
+	PrincipalID string     `json:"principal_id,omitempty" form:"principal_id"`
+	Transport   string     `json:"transport,omitempty" form:"transport"`
+	Limit       int        `json:"limit" form:"limit"`
+	Offset      int        `json:"offset" form:"offset"`
+	ErrorsOnly  bool       `json:"errors_only,omitempty" form:"errors_only"`
+}
+
+// RedactedValue replaces sensitive values in logged MCP params
+const RedactedValue = "[REDACTED]"
@@ -0,0 +1,47 @@
+package types
+
+import "time"
+
+// MaxMockToolDelay caps the artificial latency of a mock tool, in
+// milliseconds
+const MaxMockToolDelay = 60000
+
+// MockTool is a canned tool of a mock MCP server. The gateway answers calls
+// to it itself, without any upstream process.
+type MockTool struct {
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+	ID          string                 `json:"id"`
+	ServerID    string                 `json:"server_id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	// Response is a Go text/template rendered with the call: .arguments
+	// holds the call's arguments and .tool the tool's name. The json
+	// function renders a value as JSON.
+	Response string `json:"response"`
+	// IsError marks the rendered response as a tool error
+	IsError bool `json:"is_error"`
+	// DelayMs delays each response to mimic a slow upstream
+	DelayMs int `json:"delay_ms"`
+}
+
+// CreateMockToolRequest adds a canned tool to a mock server
+type CreateMockToolRequest struct {
+	InputSchema map[string]interface{} `json:"input_schema"`
+	Name        string                 `json:"name" binding:"required,max=255"`
+	Description string                 `json:"description"`
+	Response    string                 `json:"response"`
+	IsError     bool                   `json:"is_error"`
+	DelayMs     int                    `json:"delay_ms" binding:"omitempty,min=0,max=60000"`
+}
+
+// UpdateMockToolRequest changes a canned tool of a mock server
+type UpdateMockToolRequest struct {
+	InputSchema map[string]interface{} `json:"input_schema"`
+	Description *string                `json:"description"`
+	Response    *string                `json:"response"`
+	IsError     *bool                  `json:"is_error"`
+	DelayMs     *int                   `json:"delay_ms" binding:"omitempty,min=0,max=60000"`
+	Name        string                 `json:"name" binding:"omitempty,max=255"`
+}
@@ -11,6 +11,7 @@ type Namespace struct {
 	OrganizationID string                 `json:"organization_id" db:"organization_id"`
 	Name           string                 `json:"name" db:"name"`
 	Description    string                 `json:"description" db:"description"`
+	Region         string                 `json:"region,omitempty" db:"region"`
 	CreatedAt      time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time              `json:"updated_at" db:"updated_at"`
 	CreatedBy      *string                `json:"created_by" db:"created_by"`
@@ -29,16 +30,22 @@ type NamespaceServer struct {
 	Status     string    `json:"status" db:"status"`
 	Priority   int       `json:"priority" db:"priority"`
 	JoinedAt   time.Time `json:"joined_at" db:"created_at"`
+	// Region is the server's "region" metadata, empty if unmarked
+	Region string `json:"region,omitempty" db:"region"`
 }
 
 // NamespaceTool represents a tool exposed by a server in a namespace
 type NamespaceTool struct {
-	ServerID     string `json:"server_id" db:"server_id"`
-	ServerName   string `json:"server_name,omitempty"`
-	ToolName     string `json:"tool_name" db:"tool_name"`
-	PrefixedName string `json:"prefixed_name"`
-	Status       string `json:"status" db:"status"`
-	Description  string `json:"description,omitempty"`
+	InputSchema  map[string]interface{} `json:"input_schema,omitempty"`
+	OutputSchema map[string]interface{} `json:"output_schema,omitempty"`
+	Arguments    *ToolArgumentTemplate  `json:"argument_template,omitempty"`
+	Response     *ToolResponsePolicy    `json:"response_policy,omitempty"`
+	ServerID     string                 `json:"server_id" db:"server_id"`
+	ServerName   string                 `json:"server_name,omitempty"`
+	ToolName     string                 `json:"tool_name" db:"tool_name"`
+	PrefixedName string                 `json:"prefixed_name"`
+	Status       string                 `json:"status" db:"status"`
+	Description  string                 `json:"description,omitempty"`
 }
 
 // NamespaceServerMapping represents the mapping between namespace and server
@@ -78,6 +85,7 @@ func (s NamespaceStatus) Value() (driver.Value, error) {
 type CreateNamespaceRequest struct {
 	Name           string                 `json:"name" binding:"required"`
 	Description    string                 `json:"description"`
+	Region         string                 `json:"region"`
 	OrganizationID string                 `json:"organization_id"`
 	CreatedBy      *string                `json:"created_by,omitempty"`
 	Servers        []string               `json:"servers"`
@@ -88,6 +96,7 @@ type CreateNamespaceRequest struct {
 type UpdateNamespaceRequest struct {
 	Name        string                 `json:"name,omitempty"`
 	Description string                 `json:"description,omitempty"`
+	Region      *string                `json:"region,omitempty"`
 	IsActive    *bool                  `json:"is_active,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 	ServerIDs   []string               `json:"server_ids,omitempty"`
@@ -109,10 +118,65 @@ type UpdateToolStatusRequest struct {
 	Status string `json:"status" binding:"required,oneof=ACTIVE INACTIVE"`
 }
 
+// Conflict rules for callers that pass a value for a locked argument
+const (
+	ArgumentConflictReject   = "reject"   // fail the call
+	ArgumentConflictOverride = "override" // replace the caller's value
+)
+
+// ToolArgumentTemplate predefines arguments for a tool within a namespace.
+// Defaults apply only when the caller omits the argument; locked arguments
+// are always sent with the configured value.
+type ToolArgumentTemplate struct {
+	Defaults   map[string]interface{} `json:"defaults"`
+	Locked     map[string]interface{} `json:"locked"`
+	OnConflict string                 `json:"on_conflict"`
+}
+
+// UpdateToolArgumentsRequest sets the argument template of a tool in namespace
+type UpdateToolArgumentsRequest struct {
+	Defaults   map[string]interface{} `json:"defaults"`
+	Locked     map[string]interface{} `json:"locked"`
+	OnConflict string                 `json:"on_conflict" binding:"omitempty,oneof=reject override"`
+}
+
+// Response rule actions
+const (
+	ResponseActionDrop = "drop" // remove the field
+	ResponseActionMask = "mask" // replace the value with RedactedValue
+)
+
+// ResponseRule drops or masks the result fields a JSONPath expression selects
+type ResponseRule struct {
+	Path   string `json:"path" binding:"required"`
+	Action string `json:"action" binding:"required,oneof=drop mask"`
+}
+
+// ToolResponsePolicy transforms a tool's result before it reaches the caller.
+// Paths are evaluated against the tools/call result, e.g.
+// $.structuredContent.internal_id.
+type ToolResponsePolicy struct {
+	Rules []ResponseRule `json:"rules"`
+}
+
+// UpdateToolResponsePolicyRequest sets the response policy of a tool in namespace
+type UpdateToolResponsePolicyRequest struct {
+	Rules []ResponseRule `json:"rules" binding:"dive"`
+}
+
+// NamespaceToolConfig is the per-namespace configuration of one tool
+type NamespaceToolConfig struct {
+	Arguments *ToolArgumentTemplate
+	Response  *ToolResponsePolicy
+}
+
 // ExecuteNamespaceToolRequest represents the request to execute a tool in namespace
 type ExecuteNamespaceToolRequest struct {
 	Tool      string                 `json:"tool" binding:"required"`
 	Arguments map[string]interface{} `json:"arguments"`
+	// IdempotencyKey makes a failed call retryable and is forwarded to the
+	// server, which should apply calls with the same key once
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 // NamespaceToolResult represents the result of a tool execution
@@ -120,4 +184,16 @@ type NamespaceToolResult struct {
 	Success bool        `json:"success"`
 	Result  interface{} `json:"result,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	// Violations lists schema validation failures of the arguments or result
+	Violations []SchemaViolation `json:"violations,omitempty"`
+	// Attempts is how many times the call was sent to the server, when it
+	// was retried
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// SchemaViolation describes one way a value fails a JSON Schema
+type SchemaViolation struct {
+	Path    string `json:"path"` // JSON pointer to the offending value
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
 }
@@ -0,0 +1,44 @@
+package types
+
+import "time"
+
+// Gateway node statuses. A node is stale once it misses heartbeats, which
+// usually means it stopped without deregistering.
+const (
+	NodeStatusActive   = "active"
+	NodeStatusDraining = "draining"
+	NodeStatusStale    = "stale"
+)
+
+// GatewayNode is an API instance of the gateway as last reported by its
+// heartbeat. Sessions counts its client sessions by transport.
+type GatewayNode struct {
+	StartedAt        time.Time      `json:"started_at"`
+	LastHeartbeatAt  time.Time      `json:"last_heartbeat_at"`
+	DrainRequestedAt *time.Time     `json:"drain_requested_at,omitempty"`
+	Sessions         map[string]int `json:"sessions"`
+	ID               string         `json:"id"`
+	Hostname         string         `json:"hostname"`
+	Address          string         `json:"address,omitempty"`
+	Version          string         `json:"version"`
+	Region           string         `json:"region,omitempty"`
+	Status           string         `json:"status"`
+	HealthStatus     string         `json:"health_status"`
+	DrainRequestedBy string         `json:"drain_requested_by,omitempty"`
+	HealthScore      float64        `json:"health_score"`
+	UptimeSeconds    int64          `json:"uptime_seconds"`
+	SessionCount     int            `json:"session_count"`
+	Draining         bool           `json:"draining"`
+	// Self marks the node that answered the request
+	Self bool `json:"self"`
+}
+
+// NodeFleet lists the gateway's nodes with totals over the nodes that are
+// not stale
+type NodeFleet struct {
+	Nodes    []*GatewayNode `json:"nodes"`
+	Active   int            `json:"active"`
+	Draining int            `json:"draining"`
+	Stale    int            `json:"stale"`
+	Sessions int            `json:"sessions"`
+}
@@ -0,0 +1,101 @@
+package types
+
+import "time"
+
+// Notification types raised by gateway events
+const (
+	NotificationServerUnhealthy       = "server_unhealthy"
+	NotificationQuotaNearing          = "quota_nearing"
+	NotificationCertificateExpiring   = "certificate_expiring"
+	NotificationApprovalPending       = "approval_pending"
+	NotificationOwnerAlertEscalated   = "owner_alert_escalated"
+	NotificationGrantExpiring         = "grant_expiring"
+	NotificationBreakGlassActivated   = "break_glass_activated"
+	NotificationBreakGlassEnded       = "break_glass_ended"
+	NotificationCredentialCompromised = "credential_compromised"
+	NotificationBudgetThreshold       = "budget_threshold"
+)
+
+// Notification severities
+const (
+	NotificationSeverityInfo     = "info"
+	NotificationSeverityWarning  = "warning"
+	NotificationSeverityCritical = "critical"
+)
+
+// Notification is an in-app message delivered to a single admin
+type Notification struct {
+	CreatedAt      time.Time              `json:"created_at" db:"created_at"`
+	ReadAt         *time.Time             `json:"read_at,omitempty" db:"read_at"`
+	EmailedAt      *time.Time             `json:"emailed_at,omitempty" db:"emailed_at"`
+	Data           map[string]interface{} `json:"data,omitempty" db:"data"`
+	ID             string                 `json:"id" db:"id"`
+	OrganizationID string                 `json:"organization_id" db:"organization_id"`
+	UserID         string                 `json:"user_id" db:"user_id"`
+	Type           string                 `json:"type" db:"type"`
+	Severity       string                 `json:"severity" db:"severity"`
+	Title          string                 `json:"title" db:"title"`
+	Message        string                 `json:"message" db:"message"`
+	ResourceType   string                 `json:"resource_type,omitempty" db:"resource_type"`
+	ResourceID     string                 `json:"resource_id,omitempty" db:"resource_id"`
+	DedupKey       string                 `json:"-" db:"dedup_key"`
+}
+
+// NotificationEvent is a gateway event to be delivered to every active admin
+// of an organization. Events sharing a DedupKey are not repeated to an admin
+// while an earlier one is still unread.
+type NotificationEvent struct {
+	Data           map[string]interface{}
+	OrganizationID string
+	Type           string
+	Severity       string
+	Title          string
+	Message        string
+	ResourceType   string
+	ResourceID     string
+	DedupKey       string
+}
+
+// NotificationListFilter selects a page of a user's notifications
+type NotificationListFilter struct {
+	UserID     string
+	Limit      int
+	Offset     int
+	UnreadOnly bool
+}
+
+// NotificationListResponse is a page of notifications with the unread total
+type NotificationListResponse struct {
+	Notifications []*Notification `json:"notifications"`
+	UnreadCount   int             `json:"unread_count"`
+	Limit         int             `json:"limit"`
+	Offset        int             `json:"offset"`
+}
+
+// NotificationPreferences are a user's delivery settings
+type NotificationPreferences struct {
+	LastDigestAt *time.Time `json:"last_digest_at,omitempty" db:"last_digest_at"`
+	UserID       string     `json:"user_id" db:"user_id"`
+	EmailDigest  bool       `json:"email_digest" db:"email_digest"`
+}
+
+// UpdateNotificationPreferencesRequest changes a user's delivery settings
+type UpdateNotificationPreferencesRequest struct {
+	EmailDigest bool `json:"email_digest"`
+}
+
+// NotificationRecipient is an admin who receives notifications
+type NotificationRecipient struct {
+	UserID string
+	Email  string
+	Name   string
+}
+
+// TLSServerTarget is an active server reached over TLS whose certificate is
+// checked for upcoming expiry
+type TLSServerTarget struct {
+	ServerID       string
+	OrganizationID string
+	Name           string
+	URL            string
+}
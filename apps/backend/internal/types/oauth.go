@@ -75,6 +75,15 @@ const (
 	ErrorTemporarilyUnavailable  = "temporarily_unavailable"
 )
 
+// Dynamic Client Registration Error Codes (RFC 7591, RFC 6750)
+const (
+	ErrorInvalidRedirectURI          = "invalid_redirect_uri"
+	ErrorInvalidClientMetadata       = "invalid_client_metadata"
+	ErrorInvalidSoftwareStatement    = "invalid_software_statement"
+	ErrorUnapprovedSoftwareStatement = "unapproved_software_statement"
+	ErrorInvalidToken                = "invalid_token"
+)
+
 // OAuth Client represents a registered OAuth 2.0 client
 type OAuthClient struct {
 	ID                      string    `json:"id" db:"id"`
@@ -94,6 +103,7 @@ type OAuthClient struct {
 	JWKSURI                 *string   `json:"jwks_uri,omitempty" db:"jwks_uri"`
 	TokenEndpointAuthMethod string    `json:"token_endpoint_auth_method" db:"token_endpoint_auth_method"`
 	OrganizationID          string    `json:"organization_id" db:"organization_id"`
+	ServiceAccountID        *string   `json:"service_account_id,omitempty" db:"service_account_id"` // Set on clients that act as a service account
 	IsActive                bool      `json:"is_active" db:"is_active"`
 	CreatedAt               time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt               time.Time `json:"updated_at" db:"updated_at"`
@@ -117,6 +127,8 @@ type OAuthToken struct {
 	OrganizationID *string `json:"organization_id,omitempty" db:"organization_id"`
 	UserEmail      *string `json:"user_email,omitempty" db:"user_email"`
 	UserRole       *string `json:"user_role,omitempty" db:"user_role"`
+	// UserAccountType tells service accounts from people
+	UserAccountType *string `json:"user_account_type,omitempty" db:"user_account_type"`
 }
 
 // OAuth Authorization Code for authorization_code grant
@@ -179,6 +191,7 @@ type ProtectedResourceMetadata struct {
 	ScopesSupported                            []string `json:"scopes_supported,omitempty"`
 	BearerMethodsSupported                     []string `json:"bearer_methods_supported,omitempty"`
 	ResourceDocumentation                      *string  `json:"resource_documentation,omitempty"`
+	ResourceName                               *string  `json:"resource_name,omitempty"`
 	IntrospectionEndpoint                      *string  `json:"introspection_endpoint,omitempty"`
 	IntrospectionEndpointAuthMethodsSupported  []string `json:"introspection_endpoint_auth_methods_supported,omitempty"`
 }
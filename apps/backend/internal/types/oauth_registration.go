@@ -0,0 +1,76 @@
+package types
+
+import "time"
+
+// OAuthRegistrationPolicy restricts dynamic client registration for an
+// organization. Empty lists place no restriction.
+type OAuthRegistrationPolicy struct {
+	UpdatedAt                 time.Time `json:"updated_at" db:"updated_at"`
+	OrganizationID            string    `json:"organization_id" db:"organization_id"`
+	UpdatedBy                 string    `json:"updated_by,omitempty" db:"updated_by"`
+	AllowedGrantTypes         []string  `json:"allowed_grant_types" db:"allowed_grant_types"`
+	RedirectURIPatterns       []string  `json:"redirect_uri_patterns" db:"redirect_uri_patterns"`
+	TrustedSoftwareIssuers    []string  `json:"trusted_software_issuers" db:"trusted_software_issuers"`
+	SoftwareStatementKeys     []string  `json:"software_statement_keys" db:"software_statement_keys"`
+	UnusedClientTTLHours      int       `json:"unused_client_ttl_hours" db:"unused_client_ttl_hours"`
+	RequireInitialAccessToken bool      `json:"require_initial_access_token" db:"require_initial_access_token"`
+	RequireSoftwareStatement  bool      `json:"require_software_statement" db:"require_software_statement"`
+}
+
+// UpdateOAuthRegistrationPolicyRequest replaces an organization's policy.
+// Redirect URI patterns may use * as a wildcard within one URI component.
+type UpdateOAuthRegistrationPolicyRequest struct {
+	AllowedGrantTypes         []string `json:"allowed_grant_types"`
+	RedirectURIPatterns       []string `json:"redirect_uri_patterns"`
+	TrustedSoftwareIssuers    []string `json:"trusted_software_issuers"`
+	SoftwareStatementKeys     []string `json:"software_statement_keys"`
+	UnusedClientTTLHours      int      `json:"unused_client_ttl_hours" binding:"min=0"`
+	RequireInitialAccessToken bool     `json:"require_initial_access_token"`
+	RequireSoftwareStatement  bool     `json:"require_software_statement"`
+}
+
+// OAuthInitialAccessToken authorizes dynamic client registration for an
+// organization (RFC 7591 section 3)
+type OAuthInitialAccessToken struct {
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	ID             string     `json:"id" db:"id"`
+	OrganizationID string     `json:"organization_id" db:"organization_id"`
+	TokenHash      string     `json:"-" db:"token_hash"`
+	Description    string     `json:"description,omitempty" db:"description"`
+	CreatedBy      string     `json:"created_by,omitempty" db:"created_by"`
+	MaxUses        int        `json:"max_uses" db:"max_uses"`
+	UseCount       int        `json:"use_count" db:"use_count"`
+}
+
+// CreateInitialAccessTokenRequest issues an initial access token. Zero
+// values mean no expiry and unlimited uses.
+type CreateInitialAccessTokenRequest struct {
+	Description    string `json:"description"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"min=0"`
+	MaxUses        int    `json:"max_uses" binding:"min=0"`
+}
+
+// CreateInitialAccessTokenResponse returns the token value once
+type CreateInitialAccessTokenResponse struct {
+	*OAuthInitialAccessToken
+	Token string `json:"token"`
+}
+
+// RegistrationError rejects a dynamic client registration with an RFC 7591
+// error code
+type RegistrationError struct {
+	Code        string
+	Description string
+}
+
+// Error implements the error interface
+func (e *RegistrationError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// NewRegistrationError creates a dynamic client registration error
+func NewRegistrationError(code, description string) *RegistrationError {
+	return &RegistrationError{Code: code, Description: description}
+}
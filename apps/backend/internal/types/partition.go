@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// TablePartition is one partition of a range-partitioned table. A nil
+// start or end means the range is unbounded on that side.
+type TablePartition struct {
+	RangeStart *time.Time `json:"range_start,omitempty"`
+	RangeEnd   *time.Time `json:"range_end,omitempty"`
+	Table      string     `json:"table"`
+	Name       string     `json:"name"`
+}
+
+// PartitionMaintenance reports what a run of partition maintenance did.
+// Held partitions are past retention but kept because they hold rows of an
+// organization under legal hold.
+type PartitionMaintenance struct {
+	Created  []string `json:"created"`
+	Detached []string `json:"detached"`
+	Dropped  []string `json:"dropped"`
+	Held     []string `json:"held"`
+}
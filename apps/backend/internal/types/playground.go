@@ -0,0 +1,65 @@
+package types
+
+import "time"
+
+// Playground sources a recorded tool call was made from
+const (
+	PlaygroundSourceSandbox   = "sandbox"
+	PlaygroundSourceInspector = "inspector"
+)
+
+// MaxPlaygroundResultBytes bounds the encoded result kept for a playground
+// execution; larger results are replaced by a truncation marker
+const MaxPlaygroundResultBytes = 64 * 1024
+
+// PlaygroundExecution is a tool call a user made from the endpoint test
+// console or the inspector, kept in their personal history
+type PlaygroundExecution struct {
+	CreatedAt      time.Time              `json:"created_at"`
+	Arguments      map[string]interface{} `json:"arguments"`
+	Result         interface{}            `json:"result,omitempty"`
+	ID             string                 `json:"id"`
+	OrganizationID string                 `json:"organization_id"`
+	UserID         string                 `json:"user_id"`
+	Source         string                 `json:"source"`
+	EndpointID     string                 `json:"endpoint_id,omitempty"`
+	ServerID       string                 `json:"server_id,omitempty"`
+	NamespaceID    string                 `json:"namespace_id,omitempty"`
+	ToolName       string                 `json:"tool_name"`
+	ErrorMessage   string                 `json:"error_message,omitempty"`
+	RedactedFields []string               `json:"redacted_fields,omitempty"`
+	DurationMS     float64                `json:"duration_ms"`
+	IsError        bool                   `json:"is_error"`
+}
+
+// PlaygroundHistoryQuery filters a user's playground history. Search
+// matches the tool name and the arguments.
+type PlaygroundHistoryQuery struct {
+	Search     string `json:"search,omitempty" form:"search"`
+	ToolName   string `json:"tool_name,omitempty" form:"tool_name"`
+	Source     string `json:"source,omitempty" form:"source" binding:"omitempty,oneof=sandbox inspector"`
+	EndpointID string `json:"endpoint_id,omitempty" form:"endpoint_id"`
+	ServerID   string `json:"server_id,omitempty" form:"server_id"`
+	UserID     string `json:"user_id,omitempty" form:"user_id"`
+	Limit      int    `json:"limit" form:"limit"`
+	Offset     int    `json:"offset" form:"offset"`
+	ErrorsOnly bool   `json:"errors_only,omitempty" form:"errors_only"`
+}
+
+// PlaygroundHistorySettings controls playground history for an
+// organization. History is recorded by default and private to each user;
+// AdminsCanView lets organization admins read members' history.
+type PlaygroundHistorySettings struct {
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+	OrganizationID string     `json:"organization_id"`
+	UpdatedBy      string     `json:"updated_by,omitempty"`
+	Enabled        bool       `json:"enabled"`
+	AdminsCanView  bool       `json:"admins_can_view"`
+}
+
+// UpdatePlaygroundHistorySettingsRequest changes an organization's
+// playground history settings
+type UpdatePlaygroundHistorySettingsRequest struct {
+	Enabled       *bool `json:"enabled"`
+	AdminsCanView *bool `json:"admins_can_view"`
+}
@@ -0,0 +1,94 @@
+package types
+
+import "context"
+
+// Principal identifies the caller responsible for a request. Labels carry
+// the attribution labels of the credential merged over those of the
+// endpoint the request came through.
+type Principal struct {
+	Labels         Labels `json:"labels,omitempty"`
+	Type           string `json:"type"`
+	UserID         string `json:"user_id,omitempty"`
+	APIKeyID       string `json:"api_key_id,omitempty"`
+	ClientID       string `json:"client_id,omitempty"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	// Email is the email of the user the credential belongs to, when known
+	Email string `json:"email,omitempty"`
+	// OnBehalfOf is the user a service account acts for when it sent the
+	// X-On-Behalf-Of header. Authorization uses that user while metering
+	// stays with the service account.
+	OnBehalfOf string `json:"on_behalf_of,omitempty"`
+	// Scope limits what a scoped API key may reach; nil for other
+	// credentials and unscoped keys
+	Scope *APIKeyScope `json:"-"`
+}
+
+// Principal type constants
+const (
+	PrincipalTypeUser        = "user"
+	PrincipalTypeAPIKey      = "api_key"
+	PrincipalTypeOAuthClient = "oauth_client"
+	PrincipalTypeAnonymous   = "anonymous"
+
+	// PrincipalTypeServiceAccount attributes requests made with any API key
+	// or OAuth client of a service account to the account itself
+	PrincipalTypeServiceAccount = "service_account"
+)
+
+// ID returns the most specific identifier for the principal
+func (p *Principal) ID() string {
+	if p == nil {
+		return ""
+	}
+
+	switch p.Type {
+	case PrincipalTypeAPIKey:
+		return p.APIKeyID
+	case PrincipalTypeOAuthClient:
+		return p.ClientID
+	default:
+		return p.UserID
+	}
+}
+
+// EffectiveUserID returns the user whose access applies to the request: the
+// delegating user if any, otherwise the principal's own user
+func (p *Principal) EffectiveUserID() string {
+	if p == nil {
+		return ""
+	}
+	if p.OnBehalfOf != "" {
+		return p.OnBehalfOf
+	}
+	return p.UserID
+}
+
+// Key returns a stable "type:id" key suitable for grouping
+func (p *Principal) Key() string {
+	if p == nil || p.Type == "" {
+		return PrincipalTypeAnonymous
+	}
+	if id := p.ID(); id != "" {
+		return p.Type + ":" + id
+	}
+	return p.Type
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the given principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	if principal == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) *Principal {
+	if ctx == nil {
+		return nil
+	}
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
@@ -0,0 +1,59 @@
+package types
+
+// Priority classes derived from organization plan_type
+const (
+	PriorityClassEnterprise = "enterprise"
+	PriorityClassPro        = "pro"
+	PriorityClassFree       = "free"
+)
+
+// PriorityClasses lists the classes from highest to lowest priority
+var PriorityClasses = []string{
+	PriorityClassEnterprise,
+	PriorityClassPro,
+	PriorityClassFree,
+}
+
+// PriorityClassForPlan maps an organization plan to its priority class.
+// Unknown plans get the lowest class.
+func PriorityClassForPlan(plan string) string {
+	switch plan {
+	case "enterprise":
+		return PriorityClassEnterprise
+	case "pro":
+		return PriorityClassPro
+	default:
+		return PriorityClassFree
+	}
+}
+
+// PriorityClassStats reports admission activity for one priority class
+type PriorityClassStats struct {
+	Class     string  `json:"class"`
+	Admitted  int64   `json:"admitted"`
+	Rejected  int64   `json:"rejected"`
+	TimedOut  int64   `json:"timed_out"`
+	AvgWaitMS float64 `json:"avg_wait_ms"`
+	InFlight  int     `json:"in_flight"`
+	Queued    int     `json:"queued"`
+}
+
+// SchedulerStats reports per-class admission for tool executions and
+// transport connections
+type SchedulerStats struct {
+	LoadShedding         *LoadSheddingStats   `json:"load_shedding,omitempty"`
+	ToolExecutions       []PriorityClassStats `json:"tool_executions"`
+	TransportConnections []PriorityClassStats `json:"transport_connections"`
+}
+
+// LoadSheddingStats reports the adaptive concurrency limit of the gateway
+type LoadSheddingStats struct {
+	Limit             int     `json:"limit"`
+	MinLimit          int     `json:"min_limit"`
+	MaxLimit          int     `json:"max_limit"`
+	InFlight          int     `json:"in_flight"`
+	BaselineLatencyMS float64 `json:"baseline_latency_ms"`
+	LatencyMS         float64 `json:"latency_ms"`
+	Admitted          int64   `json:"admitted"`
+	Shed              int64   `json:"shed"`
+}
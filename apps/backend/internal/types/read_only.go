@@ -0,0 +1,25 @@
+package types
+
+import "time"
+
+// Read-only mode sources
+const (
+	ReadOnlySourceConfig   = "config"
+	ReadOnlySourceAPI      = "api"
+	ReadOnlySourceFailover = "failover"
+)
+
+// ReadOnlyStatus reports whether management writes are currently rejected
+type ReadOnlyStatus struct {
+	ChangedAt *time.Time `json:"changed_at,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	ChangedBy string     `json:"changed_by,omitempty"`
+	Source    string     `json:"source"`
+	Enabled   bool       `json:"enabled"`
+}
+
+// UpdateReadOnlyRequest toggles read-only mode
+type UpdateReadOnlyRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Reason  string `json:"reason" binding:"max=500"`
+}
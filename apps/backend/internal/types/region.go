@@ -0,0 +1,108 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Catalog entries replicated between regions
+const (
+	CatalogEntityServer    = "server"
+	CatalogEntityNamespace = "namespace"
+	CatalogEntityEndpoint  = "endpoint"
+)
+
+// Operations on replicated catalog entries
+const (
+	CatalogOperationUpsert = "upsert"
+	CatalogOperationDelete = "delete"
+)
+
+// CatalogChange is a change to a server, namespace or endpoint made in one
+// region, for the other regions to apply. Payload holds the entry's row, and
+// a namespace's server memberships, as of the change; deletes carry none.
+type CatalogChange struct {
+	Version        time.Time       `json:"version"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	EntityType     string          `json:"entity_type"`
+	EntityID       string          `json:"entity_id"`
+	OrganizationID string          `json:"organization_id"`
+	Operation      string          `json:"operation"`
+	ID             int64           `json:"id"`
+}
+
+// CatalogChangeBatch is a page of a region's catalog changes, in the order
+// they were made
+type CatalogChangeBatch struct {
+	Region  string          `json:"region"`
+	Changes []CatalogChange `json:"changes"`
+	// NextAfter is the id to resume from
+	NextAfter int64 `json:"next_after"`
+	HasMore   bool  `json:"has_more"`
+}
+
+// CatalogVersion is the last write to a catalog entry. Concurrent edits in
+// different regions resolve to the last writer; writes at the same instant
+// resolve to the region whose name sorts last, so every region agrees.
+type CatalogVersion struct {
+	Version time.Time
+	Region  string
+	Deleted bool
+}
+
+// Supersedes reports whether v wins over other
+func (v CatalogVersion) Supersedes(other CatalogVersion) bool {
+	if !v.Version.Equal(other.Version) {
+		return v.Version.After(other.Version)
+	}
+	return v.Region > other.Region
+}
+
+// RegionStatus reports the gateway's region and its replication from peers
+type RegionStatus struct {
+	Region string             `json:"region"`
+	Peers  []RegionPeerStatus `json:"peers"`
+}
+
+// RegionPeerStatus is the replication progress from a peer region
+type RegionPeerStatus struct {
+	LastPulledAt    *time.Time `json:"last_pulled_at,omitempty"`
+	LastAppliedAt   *time.Time `json:"last_applied_at,omitempty"`
+	Region          string     `json:"region"`
+	URL             string     `json:"url"`
+	LastError       string     `json:"last_error,omitempty"`
+	LastChangeID    int64      `json:"last_change_id"`
+	AppliedCount    int64      `json:"applied_count"`
+	SupersededCount int64      `json:"superseded_count"`
+}
+
+// RegionDNSGuidance describes the DNS records that route clients to the
+// nearest healthy region
+type RegionDNSGuidance struct {
+	GlobalHost string             `json:"global_host,omitempty"`
+	Records    []RegionDNSRecord  `json:"records"`
+	Endpoints  []EndpointDNSRoute `json:"endpoints"`
+	Notes      []string           `json:"notes"`
+}
+
+// RegionDNSRecord is a DNS record to create for the deployment
+type RegionDNSRecord struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Value   string `json:"value"`
+	Routing string `json:"routing"`
+	Region  string `json:"region,omitempty"`
+	// HealthCheck is the URL the DNS provider should probe to take the
+	// region out of rotation
+	HealthCheck string `json:"health_check,omitempty"`
+}
+
+// EndpointDNSRoute tells clients which URL to use for an endpoint.
+// Endpoints of namespaces pinned to a region are served from that region
+// only, so they skip the latency-routed name.
+type EndpointDNSRoute struct {
+	RegionalURLs map[string]string `json:"regional_urls"`
+	Name         string            `json:"name"`
+	URL          string            `json:"url"`
+	PinnedRegion string            `json:"pinned_region,omitempty"`
+}
@@ -0,0 +1,24 @@
+package types
+
+import "time"
+
+// Headers that protect signed inbound calls against replay. A sender
+// includes both in the signed message, so a captured request cannot be
+// resent with a fresh nonce.
+const (
+	RequestTimestampHeader = "X-Omnimesh-Timestamp"
+	RequestNonceHeader     = "X-Omnimesh-Nonce"
+)
+
+// DefaultReplayClockSkew is how far a signed call's timestamp may be from
+// the gateway's clock when auth.replay_protection.clock_skew is not set
+const DefaultReplayClockSkew = 5 * time.Minute
+
+// Reasons a signed inbound call is rejected as a possible replay, reported
+// on the inbound_replay_rejected_total metric
+const (
+	ReplayRejectMissing   = "missing"
+	ReplayRejectMalformed = "malformed"
+	ReplayRejectClockSkew = "clock_skew"
+	ReplayRejectReplayed  = "replayed"
+)
@@ -0,0 +1,41 @@
+package types
+
+import "time"
+
+// Destinations the gateway signs requests to
+const (
+	SigningDestinationA2AAgent     = "a2a_agent"
+	SigningDestinationOwnerWebhook = "owner_webhook"
+)
+
+// SigningKey signs the gateway's requests to one destination: an A2A agent,
+// or the owner webhook of an MCP server. Its ID is the key ID receivers see
+// in the X-Omnimesh-Request-Signature header.
+type SigningKey struct {
+	CreatedAt       time.Time  `json:"created_at"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	ID              string     `json:"id"`
+	OrganizationID  string     `json:"organization_id"`
+	DestinationType string     `json:"destination_type"`
+	DestinationID   string     `json:"destination_id"`
+	Algorithm       string     `json:"algorithm"`
+	PublicKey       string     `json:"public_key,omitempty"`
+	CreatedBy       string     `json:"created_by,omitempty"`
+	Secret          string     `json:"-"`
+}
+
+// CreateSigningKeyRequest creates a signing key for a destination. The key
+// replaces the destination's previous key for new requests.
+type CreateSigningKeyRequest struct {
+	DestinationType string `json:"destination_type" binding:"required,oneof=a2a_agent owner_webhook"`
+	DestinationID   string `json:"destination_id" binding:"required,uuid"`
+	Algorithm       string `json:"algorithm" binding:"omitempty,oneof=hmac-sha256 ed25519"`
+}
+
+// CreateSigningKeyResponse returns a new signing key. Secret, the HMAC
+// secret receivers verify with, is only ever shown here; ed25519 keys are
+// verified with the public key instead.
+type CreateSigningKeyResponse struct {
+	Key    *SigningKey `json:"key"`
+	Secret string      `json:"secret,omitempty"`
+}
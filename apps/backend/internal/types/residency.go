@@ -0,0 +1,21 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ServerMetadataRegion is the MCP server metadata key holding the region the
+// server runs in, e.g. {"region": "eu-west"}
+const ServerMetadataRegion = "region"
+
+var regionPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,62}[a-z0-9])?$`)
+
+// ValidateRegion checks that region is a lowercase region tag such as
+// "eu-west" or "us-east-1". The empty string means no region.
+func ValidateRegion(region string) error {
+	if region == "" || regionPattern.MatchString(region) {
+		return nil
+	}
+	return fmt.Errorf("invalid region %q: use lowercase letters, digits and hyphens, up to 64 characters", region)
+}
@@ -0,0 +1,76 @@
+package types
+
+import "time"
+
+// Resource types that can have an owner
+const (
+	OwnedResourceServer    = "server"
+	OwnedResourceTool      = "tool"
+	OwnedResourceNamespace = "namespace"
+	OwnedResourceEndpoint  = "endpoint"
+)
+
+// Resource owner types
+const (
+	ResourceOwnerUser = "user"
+	ResourceOwnerTeam = "team"
+)
+
+// Reasons a resource is orphaned
+const (
+	OrphanOwnerDeactivated = "owner_deactivated"
+	OrphanOwnerDeleted     = "owner_deleted"
+	OrphanTeamDeleted      = "team_deleted"
+	OrphanTeamEmpty        = "team_empty"
+)
+
+// ResourceOwner records the user or team accountable for a resource. A
+// resource is orphaned when its owning user was deactivated or deleted, or
+// its owning team was deleted or has no active members.
+type ResourceOwner struct {
+	AssignedAt     time.Time `json:"assigned_at"`
+	ResourceType   string    `json:"resource_type"`
+	ResourceID     string    `json:"resource_id"`
+	ResourceName   string    `json:"resource_name"`
+	OrganizationID string    `json:"organization_id"`
+	OwnerType      string    `json:"owner_type"`
+	OwnerID        string    `json:"owner_id"`
+	OwnerName      string    `json:"owner_name"`
+	Notes          string    `json:"notes,omitempty"`
+	AssignedBy     string    `json:"assigned_by"`
+	OrphanReason   string    `json:"orphan_reason,omitempty"`
+	Orphaned       bool      `json:"orphaned"`
+}
+
+// SetResourceOwnerRequest assigns a resource to a user or team, replacing
+// any previous owner
+type SetResourceOwnerRequest struct {
+	OwnerType string `json:"owner_type" binding:"required,oneof=user team"`
+	OwnerID   string `json:"owner_id" binding:"required,uuid"`
+	Notes     string `json:"notes,omitempty" binding:"max=2000"`
+}
+
+// ResourceOwnerFilter narrows a listing of resource owners
+type ResourceOwnerFilter struct {
+	ResourceType string `form:"resource_type" binding:"omitempty,oneof=server tool namespace endpoint"`
+	OwnerType    string `form:"owner_type" binding:"omitempty,oneof=user team"`
+	OwnerID      string `form:"owner_id" binding:"omitempty,uuid"`
+	OrphanedOnly bool   `form:"orphaned"`
+}
+
+// TransferOwnershipRequest moves resources from one owner to another. Every
+// resource of the current owner moves unless resource_type or resource_ids
+// narrow the transfer.
+type TransferOwnershipRequest struct {
+	FromType     string   `json:"from_type" binding:"required,oneof=user team"`
+	FromID       string   `json:"from_id" binding:"required,uuid"`
+	ToType       string   `json:"to_type" binding:"required,oneof=user team"`
+	ToID         string   `json:"to_id" binding:"required,uuid"`
+	ResourceType string   `json:"resource_type,omitempty" binding:"omitempty,oneof=server tool namespace endpoint"`
+	ResourceIDs  []string `json:"resource_ids,omitempty" binding:"omitempty,dive,uuid"`
+}
+
+// TransferOwnershipResult reports how many resources changed owner
+type TransferOwnershipResult struct {
+	Transferred int64 `json:"transferred"`
+}
@@ -0,0 +1,169 @@
+package types
+
+import "time"
+
+// Tables the retention job purges rows of
+const (
+	RetentionTableExecutionLogs = "log_index"
+	RetentionTableAuditLogs     = "audit_logs"
+	RetentionTableHealthChecks  = "health_checks"
+)
+
+// RetentionTables are the tables purged past an organization's log
+// retention, in the order they are purged
+var RetentionTables = []string{
+	RetentionTableExecutionLogs,
+	RetentionTableAuditLogs,
+	RetentionTableHealthChecks,
+}
+
+// OrganizationRetention is how long an organization keeps its logs. Days
+// overrides LogRetentionDays per table, and Export is set when logs are
+// exported to the organization's bucket before they are purged.
+type OrganizationRetention struct {
+	Days             map[string]int         `json:"days,omitempty"`
+	Export           *RetentionExportConfig `json:"-"`
+	OrganizationID   string                 `json:"organization_id"`
+	LogRetentionDays int                    `json:"log_retention_days"`
+}
+
+// RetentionPurge is what a retention run removed from one table of an
+// organization. In a dry run Rows counts the rows that would be removed.
+// Export is set when the rows were exported before they were purged.
+type RetentionPurge struct {
+	Cutoff         time.Time        `json:"cutoff"`
+	Export         *RetentionExport `json:"export,omitempty"`
+	OrganizationID string           `json:"organization_id"`
+	Table          string           `json:"table"`
+	Rows           int64            `json:"rows"`
+}
+
+// RetentionRun reports a run of the retention job. Held organizations are
+// under legal hold and were left alone.
+type RetentionRun struct {
+	Purges []*RetentionPurge `json:"purges"`
+	Held   []string          `json:"held"`
+	Rows   int64             `json:"rows"`
+	DryRun bool              `json:"dry_run"`
+}
+
+// TableDays returns how many days the organization keeps the rows of
+// table, 0 for as long as they exist
+func (r *OrganizationRetention) TableDays(table string) int {
+	if days, ok := r.Days[table]; ok && days > 0 {
+		return days
+	}
+	return r.LogRetentionDays
+}
+
+// RetentionExportConfig is an organization's S3 bucket its logs are
+// exported to before retention purges them
+type RetentionExportConfig struct {
+	// Endpoint is the service URL, AWS S3 of Region when empty
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	Prefix          string `json:"prefix,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"-"`
+	UsePathStyle    bool   `json:"use_path_style"`
+	HasSecret       bool   `json:"has_secret"`
+	Enabled         bool   `json:"enabled"`
+}
+
+// LogRetentionPolicy sets how long an organization keeps each type of log.
+// A type without its own retention is kept for DefaultDays, the
+// organization's log_retention_days.
+type LogRetentionPolicy struct {
+	UpdatedAt       *time.Time            `json:"updated_at,omitempty"`
+	RequestLogDays  *int                  `json:"request_log_days"`
+	AuditLogDays    *int                  `json:"audit_log_days"`
+	HealthCheckDays *int                  `json:"health_check_days"`
+	Export          RetentionExportConfig `json:"export"`
+	OrganizationID  string                `json:"organization_id"`
+	UpdatedBy       string                `json:"updated_by,omitempty"`
+	DefaultDays     int                   `json:"default_days"`
+}
+
+// TableDays returns the retention the policy sets for table, nil when the
+// table uses the default
+func (p *LogRetentionPolicy) TableDays(table string) *int {
+	switch table {
+	case RetentionTableExecutionLogs:
+		return p.RequestLogDays
+	case RetentionTableAuditLogs:
+		return p.AuditLogDays
+	case RetentionTableHealthChecks:
+		return p.HealthCheckDays
+	}
+	return nil
+}
+
+// UpdateRetentionExportRequest changes where logs are exported before they
+// are purged; nil fields are left as they are. An empty SecretAccessKey
+// removes the key.
+type UpdateRetentionExportRequest struct {
+	Enabled         *bool   `json:"enabled"`
+	Endpoint        *string `json:"endpoint" binding:"omitempty,max=1024"`
+	Region          *string `json:"region" binding:"omitempty,max=64"`
+	Bucket          *string `json:"bucket" binding:"omitempty,max=255"`
+	Prefix          *string `json:"prefix" binding:"omitempty,max=1024"`
+	AccessKeyID     *string `json:"access_key_id" binding:"omitempty,max=255"`
+	SecretAccessKey *string `json:"secret_access_key" binding:"omitempty,max=1024"`
+	UsePathStyle    *bool   `json:"use_path_style"`
+}
+
+// UpdateLogRetentionPolicyRequest changes an organization's retention per
+// type of log; nil fields are left as they are and 0 returns a type to the
+// organization's default retention
+type UpdateLogRetentionPolicyRequest struct {
+	RequestLogDays  *int                          `json:"request_log_days" binding:"omitempty,min=0"`
+	AuditLogDays    *int                          `json:"audit_log_days" binding:"omitempty,min=0"`
+	HealthCheckDays *int                          `json:"health_check_days" binding:"omitempty,min=0"`
+	Export          *UpdateRetentionExportRequest `json:"export"`
+}
+
+// RetentionExport is an export of the rows retention purged from one table
+// of an organization, as a gzipped JSON Lines object in its bucket
+type RetentionExport struct {
+	Cutoff         time.Time `json:"cutoff"`
+	CreatedAt      time.Time `json:"created_at"`
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Table          string    `json:"table"`
+	Bucket         string    `json:"bucket"`
+	StorageKey     string    `json:"storage_key"`
+	SHA256         string    `json:"sha256"`
+	RowCount       int64     `json:"row_count"`
+	SizeBytes      int64     `json:"size_bytes"`
+}
+
+// RetentionPurgeDay counts the rows of a table passing retention on one
+// UTC day
+type RetentionPurgeDay struct {
+	Date time.Time `json:"date"`
+	Rows int64     `json:"rows"`
+}
+
+// RetentionPurgeWindow shows what retention removes from one table of an
+// organization. Due rows are past retention and go on the next run;
+// Upcoming lists the days further rows pass it.
+type RetentionPurgeWindow struct {
+	Cutoff   *time.Time           `json:"cutoff,omitempty"`
+	Upcoming []*RetentionPurgeDay `json:"upcoming"`
+	Table    string               `json:"table"`
+	Days     int                  `json:"days"`
+	Due      int64                `json:"due"`
+	// Exported is set when the rows are exported to the organization's
+	// bucket before they are purged
+	Exported bool `json:"exported"`
+}
+
+// RetentionPurgeSchedule is an organization's upcoming purges over the
+// next WithinDays days. Nothing is purged while it is under legal hold.
+type RetentionPurgeSchedule struct {
+	Windows        []*RetentionPurgeWindow `json:"windows"`
+	OrganizationID string                  `json:"organization_id"`
+	WithinDays     int                     `json:"within_days"`
+	LegalHold      bool                    `json:"legal_hold"`
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// SandboxContextKey marks a gin request as a sandbox ("try it") call so that
+// request logging can exclude it from usage
+const SandboxContextKey = "sandbox"
+
+type sandboxContextKey struct{}
+
+// WithSandbox returns a copy of ctx flagged as a sandbox execution
+func WithSandbox(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sandboxContextKey{}, true)
+}
+
+// IsSandbox reports whether ctx belongs to a sandbox execution
+func IsSandbox(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	sandbox, _ := ctx.Value(sandboxContextKey{}).(bool)
+	return sandbox
+}
+
+// SandboxLimits are the stricter limits applied to sandbox executions
+type SandboxLimits struct {
+	RequestsPerMinute int `json:"requests_per_minute"`
+	TimeoutSeconds    int `json:"timeout_seconds"`
+	MaxArgumentBytes  int `json:"max_argument_bytes"`
+}
+
+// SandboxExecuteRequest is the body of a sandbox tool call
+type SandboxExecuteRequest struct {
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// SandboxExecutionResult wraps a tool result produced by the endpoint test
+// console. Sandbox results are never billed or counted in usage.
+type SandboxExecutionResult struct {
+	ExecutedAt time.Time            `json:"executed_at"`
+	Result     *NamespaceToolResult `json:"result"`
+	EndpointID string               `json:"endpoint_id"`
+	Tool       string               `json:"tool"`
+	HistoryID  string               `json:"history_id,omitempty"`
+	Limits     SandboxLimits        `json:"limits"`
+	DurationMS float64              `json:"duration_ms"`
+	Sandbox    bool                 `json:"sandbox"`
+	Billable   bool                 `json:"billable"`
+}
@@ -0,0 +1,69 @@
+package types
+
+// Kinds of catalog entries that are searched
+const (
+	SearchTypeTool     = "tool"
+	SearchTypePrompt   = "prompt"
+	SearchTypeResource = "resource"
+)
+
+// SearchTypes lists every searched kind of catalog entry
+var SearchTypes = []string{SearchTypeTool, SearchTypePrompt, SearchTypeResource}
+
+// Search modes
+const (
+	// SearchModeFullText ranks entries by the Postgres full-text index
+	SearchModeFullText = "fulltext"
+	// SearchModeSemantic ranks entries by the similarity of their
+	// embeddings to the query's
+	SearchModeSemantic = "semantic"
+	// SearchModeHybrid fuses the full-text and semantic rankings
+	SearchModeHybrid = "hybrid"
+)
+
+// SearchQuery searches an organization's tools, prompts and resources.
+// ServerID and NamespaceID narrow the results to tools, since prompts and
+// resources belong to no server. Tags must all be present.
+type SearchQuery struct {
+	Query       string   `form:"q" binding:"required"`
+	Types       []string `form:"types"`
+	Category    string   `form:"category"`
+	Tags        []string `form:"tags"`
+	ServerID    string   `form:"server_id"`
+	NamespaceID string   `form:"namespace_id"`
+	Mode        string   `form:"mode"`
+	Limit       int      `form:"limit" binding:"min=0,max=100"`
+	Offset      int      `form:"offset" binding:"min=0"`
+}
+
+// SearchResult is one ranked catalog entry. Category is the resource type
+// for resources.
+type SearchResult struct {
+	Type        string   `json:"type"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	ServerID    string   `json:"server_id,omitempty"`
+	Tags        []string `json:"tags"`
+	Score       float64  `json:"score"`
+	UsageCount  int64    `json:"usage_count"`
+}
+
+// SearchResponse is a page of search results. Total counts the full-text
+// matches; semantic results have no total beyond the page.
+type SearchResponse struct {
+	Results []*SearchResult `json:"results"`
+	Mode    string          `json:"mode"`
+	Total   int             `json:"total"`
+}
+
+// SearchDocument is the text of a catalog entry that is embedded for
+// semantic search
+type SearchDocument struct {
+	Type           string
+	ID             string
+	OrganizationID string
+	Text           string
+	ContentHash    string
+}
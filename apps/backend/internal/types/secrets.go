@@ -0,0 +1,26 @@
+package types
+
+// Secret scanning modes for server registration
+const (
+	SecretScanModeWarn  = "warn"
+	SecretScanModeBlock = "block"
+	SecretScanModeOff   = "off"
+)
+
+// SecretFinding describes a value that looks like a live credential. The
+// value itself is never included.
+type SecretFinding struct {
+	Field       string `json:"field"`
+	Location    string `json:"location"`
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+}
+
+// IsValidSecretScanMode reports whether mode is a known scanning mode
+func IsValidSecretScanMode(mode string) bool {
+	switch mode {
+	case SecretScanModeWarn, SecretScanModeBlock, SecretScanModeOff:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+// Security check outcomes
+const (
+	SecurityCheckPass = "pass"
+	SecurityCheckFail = "fail"
+	SecurityCheckSkip = "skip"
+)
+
+// Security check severities, which also determine each check's weight in the score
+const (
+	SecuritySeverityCritical = "critical"
+	SecuritySeverityHigh     = "high"
+	SecuritySeverityMedium   = "medium"
+	SecuritySeverityLow      = "low"
+)
+
+// SecurityCheck is the result of evaluating one best practice
+type SecurityCheck struct {
+	Findings    []string `json:"findings,omitempty"`
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Category    string   `json:"category"`
+	Severity    string   `json:"severity"`
+	Status      string   `json:"status"`
+	Details     string   `json:"details"`
+	Remediation string   `json:"remediation,omitempty"`
+	Weight      int      `json:"weight"`
+}
+
+// SecurityPostureReport scores an organization's configuration against best practices
+type SecurityPostureReport struct {
+	GeneratedAt    time.Time       `json:"generated_at"`
+	OrganizationID string          `json:"organization_id"`
+	Grade          string          `json:"grade"`
+	Checks         []SecurityCheck `json:"checks"`
+	Score          int             `json:"score"`
+	Passed         int             `json:"passed"`
+	Failed         int             `json:"failed"`
+	Skipped        int             `json:"skipped"`
+}
@@ -0,0 +1,67 @@
+package types
+
+import "time"
+
+// Load balancing strategies of server groups
+const (
+	LoadBalanceRoundRobin       = "round_robin"
+	LoadBalanceLeastConnections = "least_connections"
+	LoadBalanceWeighted         = "weighted"
+)
+
+// ServerGroup registers replicas of the same MCP server as one logical
+// server. Namespace tool calls to any replica are spread over the group's
+// healthy replicas with the group's strategy.
+type ServerGroup struct {
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+	Members        []ServerGroupMember `json:"members"`
+	ID             string              `json:"id"`
+	OrganizationID string              `json:"organization_id"`
+	Name           string              `json:"name"`
+	Description    string              `json:"description"`
+	Strategy       string              `json:"strategy"`
+}
+
+// ServerGroupMember is a replica in a server group
+type ServerGroupMember struct {
+	ServerID   string `json:"server_id"`
+	ServerName string `json:"server_name"`
+	// Status is the replica's server status, kept up to date by health
+	// checks
+	Status string `json:"status"`
+	// Region is the server's "region" metadata, empty if unmarked
+	Region   string `json:"region,omitempty"`
+	Weight   int    `json:"weight"`
+	IsActive bool   `json:"is_active"`
+	// ActiveRequests counts the tool calls this gateway instance has in
+	// flight on the replica
+	ActiveRequests int `json:"active_requests"`
+	// HealthScore runs from 0 to 1 and falls as this gateway instance sees
+	// the replica's calls fail or slow down
+	HealthScore float64 `json:"health_score"`
+}
+
+// CreateServerGroupRequest creates a server group
+type CreateServerGroupRequest struct {
+	Name        string                        `json:"name" binding:"required,max=255"`
+	Description string                        `json:"description"`
+	Strategy    string                        `json:"strategy" binding:"omitempty,oneof=round_robin least_connections weighted"`
+	Members     []AddServerGroupMemberRequest `json:"members" binding:"dive"`
+}
+
+// UpdateServerGroupRequest changes a server group
+type UpdateServerGroupRequest struct {
+	Description *string `json:"description"`
+	Name        string  `json:"name" binding:"omitempty,max=255"`
+	Strategy    string  `json:"strategy" binding:"omitempty,oneof=round_robin least_connections weighted"`
+}
+
+// AddServerGroupMemberRequest adds a replica to a server group, or changes
+// its weight
+type AddServerGroupMemberRequest struct {
+	ServerID string `json:"server_id" binding:"required"`
+	// Weight is the replica's share of traffic under the weighted
+	// strategy, 1 by default
+	Weight int `json:"weight" binding:"omitempty,min=1,max=1000"`
+}
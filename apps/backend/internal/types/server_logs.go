@@ -0,0 +1,17 @@
+package types
+
+import "time"
+
+// Streams of captured upstream server output
+const (
+	ServerLogStreamStdout = "stdout"
+	ServerLogStreamStderr = "stderr"
+)
+
+// ServerLogLine is one line of output captured from an upstream server
+type ServerLogLine struct {
+	Time     time.Time `json:"time"`
+	ServerID string    `json:"server_id"`
+	Stream   string    `json:"stream"`
+	Line     string    `json:"line"`
+}
@@ -0,0 +1,82 @@
+package types
+
+import "time"
+
+// Server owner webhook events
+const (
+	ServerOwnerEventStatusChanged = "server.status_changed"
+	ServerOwnerEventEscalated     = "server.alert_escalated"
+)
+
+// ServerOwner is the contact told directly when a server changes state
+type ServerOwner struct {
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+	EscalateAfterMinutes *int      `json:"escalate_after_minutes,omitempty"`
+	ServerID             string    `json:"server_id"`
+	OrganizationID       string    `json:"organization_id"`
+	ContactName          string    `json:"contact_name,omitempty"`
+	ContactEmail         string    `json:"contact_email,omitempty"`
+	WebhookURL           string    `json:"webhook_url,omitempty"`
+	WebhookSecret        string    `json:"-"`
+	HasWebhookSecret     bool      `json:"has_webhook_secret"`
+}
+
+// SetServerOwnerRequest records a server's owner. A nil WebhookSecret keeps
+// the current secret and an empty one removes it.
+type SetServerOwnerRequest struct {
+	WebhookSecret        *string `json:"webhook_secret" binding:"omitempty,max=255"`
+	EscalateAfterMinutes *int    `json:"escalate_after_minutes" binding:"omitempty,min=1,max=10080"`
+	ContactName          string  `json:"contact_name" binding:"max=255"`
+	ContactEmail         string  `json:"contact_email" binding:"omitempty,email,max=255"`
+	WebhookURL           string  `json:"webhook_url" binding:"omitempty,url"`
+}
+
+// ServerOwnerAlert records a state change the owner was told about. Alerts
+// for a server becoming unhealthy stay open until acknowledged or the server
+// recovers.
+type ServerOwnerAlert struct {
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	EscalatedAt    *time.Time `json:"escalated_at,omitempty"`
+	ID             string     `json:"id"`
+	ServerID       string     `json:"server_id"`
+	OrganizationID string     `json:"organization_id"`
+	ServerName     string     `json:"server_name,omitempty"`
+	PreviousStatus string     `json:"previous_status"`
+	Status         string     `json:"status"`
+	HealthStatus   string     `json:"health_status,omitempty"`
+	DeliveryError  string     `json:"delivery_error,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+}
+
+// Open reports whether the alert still awaits acknowledgement
+func (a *ServerOwnerAlert) Open() bool {
+	return a.AcknowledgedAt == nil && a.ResolvedAt == nil && a.EscalatedAt == nil
+}
+
+// ServerTransition is a server changing state after a health check
+type ServerTransition struct {
+	ServerID       string
+	OrganizationID string
+	ServerName     string
+	PreviousStatus string
+	Status         string
+	HealthStatus   string
+}
+
+// ServerOwnerWebhookPayload is the JSON body posted to an owner's webhook
+type ServerOwnerWebhookPayload struct {
+	OccurredAt     time.Time `json:"occurred_at"`
+	Event          string    `json:"event"`
+	AlertID        string    `json:"alert_id"`
+	ServerID       string    `json:"server_id"`
+	ServerName     string    `json:"server_name"`
+	OrganizationID string    `json:"organization_id"`
+	PreviousStatus string    `json:"previous_status"`
+	Status         string    `json:"status"`
+	HealthStatus   string    `json:"health_status,omitempty"`
+	AcknowledgeURL string    `json:"acknowledge_url,omitempty"`
+}
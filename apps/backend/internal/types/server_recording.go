@@ -0,0 +1,61 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Recording modes of upstream servers
+const (
+	// RecordingModeOff sends requests upstream without recording them
+	RecordingModeOff = "off"
+	// RecordingModeRecord sends requests upstream and records the responses
+	RecordingModeRecord = "record"
+	// RecordingModeReplay answers requests from recorded responses without
+	// reaching the upstream server
+	RecordingModeReplay = "replay"
+)
+
+// ServerRecording is a recorded upstream response to an MCP request.
+// Requests are matched to recordings by fingerprint: a hash of the method
+// and the params, ignoring _meta.
+type ServerRecording struct {
+	RecordedAt     time.Time       `json:"recorded_at"`
+	LastReplayedAt *time.Time      `json:"last_replayed_at,omitempty"`
+	Error          *MCPError       `json:"error,omitempty"`
+	Params         json.RawMessage `json:"params,omitempty"`
+	Result         json.RawMessage `json:"result,omitempty"`
+	ID             string          `json:"id"`
+	ServerID       string          `json:"server_id"`
+	Fingerprint    string          `json:"fingerprint"`
+	Method         string          `json:"method"`
+	ReplayCount    int             `json:"replay_count"`
+}
+
+// ServerRecordingStatus is the recording mode of a server and how many
+// responses it has recorded
+type ServerRecordingStatus struct {
+	ServerID   string `json:"server_id"`
+	Mode       string `json:"mode"`
+	Recordings int    `json:"recordings"`
+}
+
+// SetRecordingModeRequest changes the recording mode of a server
+type SetRecordingModeRequest struct {
+	Mode string `json:"mode" binding:"required,oneof=off record replay"`
+}
+
+// ImportRecordingsRequest adds recorded responses to a server, for instance
+// ones exported from another gateway for a deterministic test run
+type ImportRecordingsRequest struct {
+	Recordings []ImportedRecording `json:"recordings" binding:"required,min=1,dive"`
+}
+
+// ImportedRecording is a recorded response to import. Exactly one of Result
+// and Error is set.
+type ImportedRecording struct {
+	Error  *MCPError       `json:"error"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Method string          `json:"method" binding:"required"`
+}
@@ -0,0 +1,63 @@
+package types
+
+import "time"
+
+// Account types of users
+const (
+	AccountTypeHuman   = "human"
+	AccountTypeService = "service"
+)
+
+// ServiceAccountEmailDomain holds the placeholder addresses of service
+// accounts. The .invalid top-level domain never resolves, so no mail is
+// ever sent to them.
+const ServiceAccountEmailDomain = "service-accounts.invalid"
+
+// ServiceAccount is a non-human principal of an organization. It acts
+// through its own API keys and OAuth clients with the role bound to it,
+// cannot sign in interactively and does not take a licensed seat.
+type ServiceAccount struct {
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	ID               string    `json:"id"`
+	OrganizationID   string    `json:"organization_id"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description,omitempty"`
+	Role             string    `json:"role"`
+	CreatedBy        string    `json:"created_by,omitempty"`
+	APIKeyCount      int       `json:"api_key_count"`
+	OAuthClientCount int       `json:"oauth_client_count"`
+	IsActive         bool      `json:"is_active"`
+}
+
+// CreateServiceAccountRequest creates a service account bound to a role
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=1000"`
+	Role        string `json:"role" binding:"required,oneof=admin user viewer api_user"`
+}
+
+// UpdateServiceAccountRequest changes a service account. Disabling it stops
+// its keys and OAuth clients from authenticating until it is enabled again.
+type UpdateServiceAccountRequest struct {
+	Description *string `json:"description,omitempty" binding:"omitempty,max=1000"`
+	Role        *string `json:"role,omitempty" binding:"omitempty,oneof=admin user viewer api_user"`
+	IsActive    *bool   `json:"is_active,omitempty"`
+}
+
+// CreateServiceAccountClientRequest issues an OAuth client whose
+// client_credentials tokens act as the service account
+type CreateServiceAccountClientRequest struct {
+	Name  string `json:"name" binding:"required,max=255"`
+	Scope string `json:"scope" binding:"max=500"`
+}
+
+// CreateServiceAccountKeyRequest issues an API key acting as the service
+// account with the role bound to it
+type CreateServiceAccountKeyRequest struct {
+	Labels         Labels   `json:"labels,omitempty"`
+	Name           string   `json:"name" binding:"required,min=2"`
+	ExpiresAt      string   `json:"expires_at,omitempty"`
+	AllowedCIDRs   []string `json:"allowed_cidrs,omitempty"`
+	AllowedOrigins []string `json:"allowed_origins,omitempty"`
+}
@@ -0,0 +1,45 @@
+package types
+
+import "time"
+
+// Health of the gateway instance as reported by /readyz
+const (
+	ServiceHealthHealthy     = "healthy"
+	ServiceHealthDegraded    = "degraded"
+	ServiceHealthUnavailable = "unavailable"
+)
+
+// States of a dependency of the gateway
+const (
+	DependencyStatusUp      = "up"
+	DependencyStatusSlow    = "slow"
+	DependencyStatusDown    = "down"
+	DependencyStatusUnknown = "unknown"
+)
+
+// ServiceHealth is the health of a gateway instance's own dependencies.
+// Score runs from 0 to 1 and falls as dependencies slow down or fail; the
+// instance is unavailable, and should take no traffic, while a critical
+// dependency is down.
+type ServiceHealth struct {
+	CheckedAt    time.Time          `json:"checked_at"`
+	Status       string             `json:"status"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+	Score        float64            `json:"score"`
+	// Draining is set while an admin drains the node; it then fails
+	// readiness checks whatever its dependencies
+	Draining bool `json:"draining,omitempty"`
+}
+
+// DependencyHealth is the result of the last probe of a dependency
+type DependencyHealth struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs float64   `json:"latency_ms"`
+	Score     float64   `json:"score"`
+	// Critical dependencies make the instance unavailable when they are
+	// down; the gateway falls back without the others
+	Critical bool `json:"critical"`
+}
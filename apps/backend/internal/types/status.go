@@ -0,0 +1,58 @@
+package types
+
+import "time"
+
+// Coarse health reported on public status pages
+const (
+	ServiceStatusOperational = "operational"
+	ServiceStatusDegraded    = "degraded"
+	ServiceStatusDown        = "down"
+)
+
+// Status incident sources and states
+const (
+	IncidentSourceDetected = "detected"
+	IncidentStateOngoing   = "ongoing"
+	IncidentStateResolved  = "resolved"
+)
+
+// PublicStatus is the unauthenticated status of a public endpoint. It never
+// names the servers behind the endpoint.
+type PublicStatus struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Endpoint    string           `json:"endpoint"`
+	Status      string           `json:"status"`
+	Uptime      []UptimeWindow   `json:"uptime"`
+	Incidents   []StatusIncident `json:"incidents"`
+}
+
+// UptimeWindow is the share of healthy checks over a trailing window.
+// Percent is nil when there were no checks in the window.
+type UptimeWindow struct {
+	Percent *float64 `json:"percent"`
+	Window  string   `json:"window"`
+}
+
+// StatusIncident is a period of degraded or down service
+type StatusIncident struct {
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Title     string     `json:"title"`
+	Impact    string     `json:"impact"`
+	State     string     `json:"state"`
+	Source    string     `json:"source"`
+}
+
+// HealthSample is one health check of a server behind a status page
+type HealthSample struct {
+	CheckedAt time.Time
+	ServerID  string
+	Healthy   bool
+}
+
+// StatusPageLimits are the rate limit and cache lifetime of public status
+// pages
+type StatusPageLimits struct {
+	RequestsPerMinute int `json:"requests_per_minute"`
+	CacheTTLSeconds   int `json:"cache_ttl_seconds"`
+}
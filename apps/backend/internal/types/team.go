@@ -0,0 +1,72 @@
+package types
+
+import "time"
+
+// Team membership sources
+const (
+	TeamMemberSourceManual = "manual"
+	TeamMemberSourceIdP    = "idp"
+)
+
+// Team groups users of an organization. Members inherit the team's role
+// when it ranks above their own, share ownership of the team's API keys and
+// match namespace grants made to the team.
+type Team struct {
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Role           string    `json:"role,omitempty"`
+	IdPGroup       string    `json:"idp_group,omitempty"`
+	CreatedBy      string    `json:"created_by"`
+	MemberCount    int       `json:"member_count"`
+}
+
+// TeamMember is a user's membership of a team
+type TeamMember struct {
+	AddedAt time.Time `json:"added_at"`
+	TeamID  string    `json:"team_id"`
+	UserID  string    `json:"user_id"`
+	Email   string    `json:"email"`
+	Name    string    `json:"name"`
+	Source  string    `json:"source"`
+}
+
+// CreateTeamRequest creates a team. Role is optional; an empty role grants
+// members nothing beyond their own.
+type CreateTeamRequest struct {
+	Name        string `json:"name" binding:"required,min=2,max=255"`
+	Description string `json:"description,omitempty"`
+	Role        string `json:"role,omitempty" binding:"omitempty,oneof=admin user viewer api_user"`
+	IdPGroup    string `json:"idp_group,omitempty" binding:"max=255"`
+}
+
+// UpdateTeamRequest updates a team; nil fields are left unchanged and an
+// empty role or idp_group clears it
+type UpdateTeamRequest struct {
+	Name        *string `json:"name,omitempty" binding:"omitempty,min=2,max=255"`
+	Description *string `json:"description,omitempty"`
+	Role        *string `json:"role,omitempty"`
+	IdPGroup    *string `json:"idp_group,omitempty" binding:"omitempty,max=255"`
+}
+
+// AddTeamMemberRequest adds a user of the organization to a team
+type AddTeamMemberRequest struct {
+	UserID string `json:"user_id" binding:"required,uuid"`
+}
+
+// SyncTeamGroupsRequest reports the identity provider groups a user belongs
+// to, as asserted by the IdP at sign-in or by a provisioning push
+type SyncTeamGroupsRequest struct {
+	UserID string   `json:"user_id" binding:"required,uuid"`
+	Groups []string `json:"groups"`
+}
+
+// TeamSyncResult lists the teams a group sync added the user to and removed
+// the user from
+type TeamSyncResult struct {
+	Joined []string `json:"joined"`
+	Left   []string `json:"left"`
+}
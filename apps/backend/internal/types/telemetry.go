@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+// TelemetryUsage is aggregated locally from the gateway's own database.
+// It holds counts only: no names, URLs, addresses or identifiers.
+type TelemetryUsage struct {
+	ServersByProtocol  map[string]int `json:"servers_by_protocol"`
+	SessionsByProtocol map[string]int `json:"sessions_by_protocol"`
+	UserRange          string         `json:"user_range"`
+	Servers            int            `json:"servers"`
+	Namespaces         int            `json:"namespaces"`
+	Endpoints          int            `json:"endpoints"`
+	VirtualServers     int            `json:"virtual_servers"`
+}
+
+// TelemetryReport is the complete payload sent to the telemetry endpoint
+type TelemetryReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Version     string    `json:"version"`
+	GoVersion   string    `json:"go_version"`
+	Platform    string    `json:"platform"`
+	// SessionWindow is the period SessionsByProtocol covers
+	SessionWindow string `json:"session_window"`
+	TelemetryUsage
+}
+
+// TelemetryStatus is returned by GET /api/admin/telemetry so operators can
+// inspect exactly what would be sent
+type TelemetryStatus struct {
+	Report   *TelemetryReport `json:"report"`
+	Endpoint string           `json:"endpoint,omitempty"`
+	Reason   string           `json:"reason,omitempty"`
+	Interval string           `json:"interval"`
+	Enabled  bool             `json:"enabled"`
+}
@@ -0,0 +1,57 @@
+package types
+
+import "time"
+
+// Settings of queued executions of tools without job settings of their own
+const (
+	DefaultToolJobMaxAttempts  = 3
+	DefaultToolJobTimeout      = 10 * time.Minute
+	DefaultToolJobRetryBackoff = 30 * time.Second
+)
+
+// ToolJob is a namespace tool call queued for a worker to run. Failed
+// attempts are retried after a backoff that doubles each time, up to
+// MaxAttempts, unless the gateway refused the call.
+type ToolJob struct {
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	RunAfter   time.Time              `json:"run_after"`
+	StartedAt  *time.Time             `json:"started_at,omitempty"`
+	FinishedAt *time.Time             `json:"finished_at,omitempty"`
+	Arguments  map[string]interface{} `json:"arguments"`
+	Result     *NamespaceToolResult   `json:"result,omitempty"`
+	// Error is why the last attempt failed
+	Error *Error `json:"error,omitempty"`
+	// Principal is the caller the job runs as
+	Principal           *Principal `json:"-"`
+	CreatedBy           *string    `json:"created_by,omitempty"`
+	ID                  string     `json:"id"`
+	OrganizationID      string     `json:"organization_id"`
+	NamespaceID         string     `json:"namespace_id"`
+	Tool                string     `json:"tool"`
+	IdempotencyKey      string     `json:"idempotency_key"`
+	Status              string     `json:"status"`
+	Attempts            int        `json:"attempts"`
+	MaxAttempts         int        `json:"max_attempts"`
+	TimeoutSeconds      int        `json:"timeout_seconds"`
+	RetryBackoffSeconds int        `json:"retry_backoff_seconds"`
+}
+
+// ToolJobSettings are the attempts, timeout and retry backoff of queued
+// executions of a tool
+type ToolJobSettings struct {
+	UpdatedAt           *time.Time `json:"updated_at,omitempty"`
+	ToolID              string     `json:"tool_id"`
+	MaxAttempts         int        `json:"max_attempts"`
+	TimeoutSeconds      int        `json:"timeout_seconds"`
+	RetryBackoffSeconds int        `json:"retry_backoff_seconds"`
+	// Default reports that the tool has no settings of its own
+	Default bool `json:"default"`
+}
+
+// SetToolJobSettingsRequest sets the job settings of a tool
+type SetToolJobSettingsRequest struct {
+	MaxAttempts         int `json:"max_attempts" binding:"required,min=1,max=10"`
+	TimeoutSeconds      int `json:"timeout_seconds" binding:"required,min=1,max=86400"`
+	RetryBackoffSeconds int `json:"retry_backoff_seconds" binding:"min=0,max=86400"`
+}
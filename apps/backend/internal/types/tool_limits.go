@@ -0,0 +1,38 @@
+package types
+
+import "time"
+
+// DefaultToolRateWindow is the window of a tool rate limit that names none
+const DefaultToolRateWindow = time.Minute
+
+// ToolLimits cap how often an organization's calls reach a tool. A zero
+// limit leaves that dimension unlimited.
+type ToolLimits struct {
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	ToolID    string     `json:"tool_id"`
+	// RateLimit is how many calls the tool takes per RateWindowSeconds
+	RateLimit         int64 `json:"rate_limit,omitempty"`
+	RateWindowSeconds int   `json:"rate_window_seconds,omitempty"`
+	// DailyBudget is how many calls the tool takes per UTC day
+	DailyBudget int64 `json:"daily_budget,omitempty"`
+}
+
+// ToolLimitStatus is the limits of a tool with its usage today, as shown in
+// the tools API
+type ToolLimitStatus struct {
+	ToolLimits
+	// ResetsAt is when the daily budget is replenished
+	ResetsAt time.Time `json:"resets_at"`
+	// CallsToday counts the calls the daily budget admitted today
+	CallsToday int64 `json:"calls_today"`
+	// Remaining is how many calls today's budget still admits, or -1
+	// without a budget
+	Remaining int64 `json:"remaining"`
+}
+
+// SetToolLimitsRequest sets the limits of a tool
+type SetToolLimitsRequest struct {
+	RateLimit         int64 `json:"rate_limit" binding:"min=0"`
+	RateWindowSeconds int   `json:"rate_window_seconds" binding:"min=0,max=86400"`
+	DailyBudget       int64 `json:"daily_budget" binding:"min=0"`
+}
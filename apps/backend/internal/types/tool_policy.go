@@ -0,0 +1,84 @@
+package types
+
+// Tool policy effects. The conditions of a PolicyTypeTool policy decode into
+// ToolPolicyConditions and its actions into ToolPolicyActions.
+const (
+	ToolPolicyEffectAllow           = "allow"
+	ToolPolicyEffectDeny            = "deny"
+	ToolPolicyEffectRequireApproval = "require_approval"
+)
+
+// ToolPolicyConditions select the calls a tool policy applies to. Every
+// listed condition must match; an empty condition matches any call. Tools
+// and servers are glob patterns (path.Match syntax) matched against the
+// prefixed and bare tool name and against the server name and ID.
+type ToolPolicyConditions struct {
+	Callers    *ToolPolicyCallers    `json:"callers,omitempty"`
+	Namespaces []string              `json:"namespaces,omitempty"`
+	Tools      []string              `json:"tools,omitempty"`
+	Servers    []string              `json:"servers,omitempty"`
+	Arguments  []ToolArgumentPattern `json:"arguments,omitempty"`
+}
+
+// ToolArgumentPattern matches when a value the JSONPath selects from the
+// call's arguments matches the regular expression. Non-string values are
+// matched in their JSON encoding.
+type ToolArgumentPattern struct {
+	Path    string `json:"path"`
+	Pattern string `json:"pattern"`
+}
+
+// ToolPolicyCallers match the calling principal. A caller matches when it
+// matches any of the listed types or identifiers, and carries all labels.
+type ToolPolicyCallers struct {
+	Labels    Labels   `json:"labels,omitempty"`
+	Types     []string `json:"types,omitempty"`
+	UserIDs   []string `json:"user_ids,omitempty"`
+	APIKeyIDs []string `json:"api_key_ids,omitempty"`
+	ClientIDs []string `json:"client_ids,omitempty"`
+}
+
+// ToolPolicyActions is the outcome of a matching tool policy. Message is
+// returned to the caller when the call is denied or held for approval, and
+// Approval configures require_approval policies.
+type ToolPolicyActions struct {
+	Approval *ToolApprovalSettings `json:"approval,omitempty"`
+	Effect   string                `json:"effect"`
+	Message  string                `json:"message,omitempty"`
+}
+
+// ToolPolicyDecision is the outcome of evaluating a call against the
+// organization's tool policies. PolicyID is empty when no policy matched
+// and the call is allowed by default.
+type ToolPolicyDecision struct {
+	Effect     string                 `json:"effect"`
+	PolicyID   string                 `json:"policy_id,omitempty"`
+	PolicyName string                 `json:"policy_name,omitempty"`
+	Message    string                 `json:"message,omitempty"`
+	Trace      []ToolPolicyTraceEntry `json:"trace,omitempty"`
+	Approval   *ToolApprovalSettings  `json:"approval,omitempty"`
+}
+
+// ToolPolicyTraceEntry reports whether one policy matched the call, and
+// which condition ruled it out when it did not
+type ToolPolicyTraceEntry struct {
+	PolicyID   string `json:"policy_id,omitempty"`
+	PolicyName string `json:"policy_name"`
+	Effect     string `json:"effect"`
+	Reason     string `json:"reason,omitempty"`
+	Priority   int    `json:"priority"`
+	Matched    bool   `json:"matched"`
+}
+
+// TestToolPolicyRequest evaluates a hypothetical tool call against the
+// organization's active tool policies and any candidate policies, without
+// running it. IgnoreSaved evaluates the candidates alone.
+type TestToolPolicyRequest struct {
+	Arguments   map[string]interface{} `json:"arguments,omitempty"`
+	Caller      *Principal             `json:"caller,omitempty"`
+	NamespaceID string                 `json:"namespace_id" binding:"required"`
+	Tool        string                 `json:"tool" binding:"required"`
+	Server      string                 `json:"server,omitempty"`
+	Policies    []CreatePolicyRequest  `json:"policies,omitempty"`
+	IgnoreSaved bool                   `json:"ignore_saved,omitempty"`
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// Statuses of a streamed or queued tool execution. Only queued executions
+// wait in ToolJobStatusQueued or end ToolJobStatusCancelled.
+const (
+	ToolJobStatusQueued    = "queued"
+	ToolJobStatusRunning   = "running"
+	ToolJobStatusSucceeded = "succeeded"
+	ToolJobStatusFailed    = "failed"
+	ToolJobStatusCancelled = "cancelled"
+)
+
+// Events of a streamed tool execution
+const (
+	// ToolStreamEventJob opens the stream with the job to poll should the
+	// stream break
+	ToolStreamEventJob = "job"
+	// ToolStreamEventProgress carries a progress notification of the
+	// upstream server
+	ToolStreamEventProgress = "progress"
+	// ToolStreamEventResult carries the result and ends the stream
+	ToolStreamEventResult = "result"
+	// ToolStreamEventError carries the error the call failed with and ends
+	// the stream
+	ToolStreamEventError = "error"
+)
+
+// ToolProgress is a progress notification an upstream server sent while
+// running a tool call. Total is zero when the server does not know it.
+type ToolProgress struct {
+	Message  string  `json:"message,omitempty"`
+	Progress float64 `json:"progress"`
+	Total    float64 `json:"total,omitempty"`
+}
+
+// ToolExecutionJob is a streamed tool execution, which can be polled by ID
+// while it runs and for a while after it ends
+type ToolExecutionJob struct {
+	StartedAt   time.Time            `json:"started_at"`
+	FinishedAt  *time.Time           `json:"finished_at,omitempty"`
+	Progress    *ToolProgress        `json:"progress,omitempty"`
+	Result      *NamespaceToolResult `json:"result,omitempty"`
+	Error       *Error               `json:"error,omitempty"`
+	ID          string               `json:"id"`
+	NamespaceID string               `json:"namespace_id"`
+	Tool        string               `json:"tool"`
+	Status      string               `json:"status"`
+}
+
+type toolProgressKey struct{}
+
+// WithToolProgress returns a copy of ctx whose tool calls ask the upstream
+// server for progress notifications and pass them to report
+func WithToolProgress(ctx context.Context, report func(ToolProgress)) context.Context {
+	return context.WithValue(ctx, toolProgressKey{}, report)
+}
+
+// ToolProgressFromContext returns the function progress notifications of
+// tool calls made with ctx are reported to, or nil
+func ToolProgressFromContext(ctx context.Context) func(ToolProgress) {
+	report, _ := ctx.Value(toolProgressKey{}).(func(ToolProgress))
+	return report
+}
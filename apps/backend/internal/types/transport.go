@@ -15,6 +15,7 @@ const (
 	TransportTypeWebSocket  TransportType = "WEBSOCKET"
 	TransportTypeStreamable TransportType = "STREAMABLE"
 	TransportTypeSTDIO      TransportType = "STDIO"
+	TransportTypeGRPC       TransportType = "GRPC"
 )
 
 // Transport interface defines the contract for all transport implementations
@@ -77,11 +78,26 @@ type TransportConfig struct {
 	MaxConnections    int             `yaml:"max_connections" json:"max_connections"`
 	BufferSize        int             `yaml:"buffer_size" json:"buffer_size"`
 
+	// WebSocketRequireSubprotocol rejects upgrades that do not request a
+	// supported Sec-WebSocket-Protocol; by default they are accepted as-is
+	WebSocketRequireSubprotocol bool `yaml:"websocket_require_subprotocol" json:"websocket_require_subprotocol"`
+
 	// Streamable HTTP specific settings
 	StreamableStateful bool `yaml:"streamable_stateful" json:"streamable_stateful"`
 
 	// STDIO specific settings
-	STDIOTimeout time.Duration `yaml:"stdio_timeout" json:"stdio_timeout"`
+	STDIOCommands STDIOCommandPolicy `yaml:"stdio_commands" json:"stdio_commands"`
+	STDIOTimeout  time.Duration      `yaml:"stdio_timeout" json:"stdio_timeout"`
+}
+
+// STDIOCommandPolicy restricts which executables may be registered and
+// launched as STDIO servers. AllowedCommands holds executable names or
+// absolute paths and may use filepath.Match globs; an empty list allows any
+// command. DeniedArgPatterns are regular expressions matched against each
+// argument.
+type STDIOCommandPolicy struct {
+	AllowedCommands   []string `yaml:"allowed_commands" json:"allowed_commands"`
+	DeniedArgPatterns []string `yaml:"denied_arg_patterns" json:"denied_arg_patterns"`
 }
 
 // TransportRequest represents a request through any transport
@@ -0,0 +1,73 @@
+package types
+
+import "time"
+
+// Metered dimensions of an organization's usage. Usage is counted per
+// calendar month in UTC.
+const (
+	// MeterExecutions counts namespace tool calls that ran
+	MeterExecutions = "executions"
+	// MeterTokens estimates the tokens of tool call arguments and results at
+	// four bytes of JSON per token
+	MeterTokens = "tokens"
+	// MeterEgressBytes counts the bytes of tool results returned to callers
+	MeterEgressBytes = "egress_bytes"
+)
+
+// MeterDimensions are the metered dimensions budgets can be set on
+var MeterDimensions = []string{MeterExecutions, MeterTokens, MeterEgressBytes}
+
+// UsageBudgetThresholds are the percentages of a budget at which admins are
+// notified, once per month each
+var UsageBudgetThresholds = []int{50, 80, 100}
+
+// UsageBudget is an organization's monthly budget on a metered dimension
+type UsageBudget struct {
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	OrganizationID string    `json:"organization_id"`
+	Dimension      string    `json:"dimension"`
+	// CriticalNamespaces keep working when HardStop stops the rest of the
+	// organization's traffic
+	CriticalNamespaces []string `json:"critical_namespaces"`
+	MonthlyLimit       int64    `json:"monthly_limit"`
+	// HardStop refuses tool calls outside CriticalNamespaces once the
+	// month's usage reaches MonthlyLimit
+	HardStop bool `json:"hard_stop"`
+}
+
+// UsageBudgetStatus is a budget with the usage of the current month
+type UsageBudgetStatus struct {
+	UsageBudget
+	// ResetsAt is when the next month's usage starts
+	ResetsAt time.Time `json:"resets_at"`
+	// Period is the month usage is counted for, as YYYY-MM
+	Period string `json:"period"`
+	// AlertsSent are the thresholds admins were notified of this month
+	AlertsSent  []int   `json:"alerts_sent"`
+	Used        int64   `json:"used"`
+	PercentUsed float64 `json:"percent_used"`
+	// Stopping reports that non-critical tool calls are refused
+	Stopping bool `json:"stopping"`
+}
+
+// MeteredUsage is an organization's usage of every metered dimension in a
+// month
+type MeteredUsage struct {
+	Usage  map[string]int64 `json:"usage"`
+	Period string           `json:"period"`
+}
+
+// SetUsageBudgetRequest sets the monthly budget of a metered dimension
+type SetUsageBudgetRequest struct {
+	CriticalNamespaces []string `json:"critical_namespaces"`
+	MonthlyLimit       int64    `json:"monthly_limit" binding:"required,min=1"`
+	HardStop           bool     `json:"hard_stop"`
+}
+
+// UsageBudgetListResponse lists an organization's budgets with this month's
+// usage of every dimension
+type UsageBudgetListResponse struct {
+	Usage   *MeteredUsage        `json:"usage"`
+	Budgets []*UsageBudgetStatus `json:"budgets"`
+}
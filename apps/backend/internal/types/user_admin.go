@@ -0,0 +1,113 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// Lifecycle states of users managed through the admin API
+const (
+	UserStatusActive      = "active"
+	UserStatusInvited     = "invited"
+	UserStatusDeactivated = "deactivated"
+)
+
+// Purposes of single-use tokens emailed to users
+const (
+	UserTokenPurposeInvitation    = "invitation"
+	UserTokenPurposePasswordReset = "password_reset"
+)
+
+// ManagedUser is a human user of an organization as seen by its admins.
+// Invited users cannot sign in until they accept their invitation;
+// deactivated users cannot sign in or use their API keys until reactivated.
+type ManagedUser struct {
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	InvitedAt      *time.Time `json:"invited_at,omitempty"`
+	DeactivatedAt  *time.Time `json:"deactivated_at,omitempty"`
+	ID             string     `json:"id"`
+	OrganizationID string     `json:"organization_id"`
+	Email          string     `json:"email"`
+	Name           string     `json:"name"`
+	Role           string     `json:"role"`
+	Status         string     `json:"status"`
+	InvitedBy      string     `json:"invited_by,omitempty"`
+}
+
+// ManagedUserFilter narrows a listing of an organization's users
+type ManagedUserFilter struct {
+	Status string
+	Role   string
+	Search string // Substring of the email or name
+}
+
+// CreateManagedUserRequest adds an active user with a password set by the
+// admin
+type CreateManagedUserRequest struct {
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Name     string `json:"name" binding:"required,min=2,max=255"`
+	Password string `json:"password" binding:"required,min=8"`
+	Role     string `json:"role" binding:"required,oneof=admin user viewer api_user"`
+}
+
+// InviteUserRequest adds a user who chooses their own password through an
+// emailed invitation link
+type InviteUserRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+	Name  string `json:"name" binding:"required,min=2,max=255"`
+	Role  string `json:"role" binding:"required,oneof=admin user viewer api_user"`
+}
+
+// UpdateManagedUserRequest changes a user's name or role
+type UpdateManagedUserRequest struct {
+	Name *string `json:"name,omitempty" binding:"omitempty,min=2,max=255"`
+	Role *string `json:"role,omitempty" binding:"omitempty,oneof=admin user viewer api_user"`
+}
+
+// UserTokenDelivery reports how an invitation or password reset link
+// reached the user. The link is only returned when it could not be emailed,
+// for the admin to pass on.
+type UserTokenDelivery struct {
+	User      *ManagedUser `json:"user"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	URL       string       `json:"url,omitempty"`
+	EmailSent bool         `json:"email_sent"`
+}
+
+// AcceptInvitationRequest activates an invited user with the password they
+// chose
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// RequestPasswordResetRequest asks for a password reset link to be emailed
+type RequestPasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password with an emailed reset token
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// UserToken is a single-use token emailed to a user. Only its hash is
+// stored.
+type UserToken struct {
+	ExpiresAt      time.Time
+	UsedAt         *time.Time
+	ID             string
+	OrganizationID string
+	UserID         string
+	Purpose        string
+	TokenHash      string
+	CreatedBy      string
+}
+
+// NormalizeEmail returns the canonical form of an email address used for
+// uniqueness checks
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
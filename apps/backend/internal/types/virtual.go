@@ -109,9 +109,10 @@ type ListToolsResult struct {
 }
 
 type Tool struct {
-	InputSchema map[string]interface{} `json:"inputSchema"`
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
+	InputSchema  map[string]interface{} `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
 }
 
 type CallToolParams struct {
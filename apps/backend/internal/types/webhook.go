@@ -0,0 +1,144 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types. Admin notifications are delivered to webhooks too,
+// under the event type mapped from their notification type.
+const (
+	WebhookEventServerUnhealthy       = "server.unhealthy"
+	WebhookEventQuotaNearing          = "quota.nearing"
+	WebhookEventCertificateExpiring   = "certificate.expiring"
+	WebhookEventApprovalPending       = "approval.pending"
+	WebhookEventOwnerAlertEscalated   = "owner_alert.escalated"
+	WebhookEventGrantExpiring         = "grant.expiring"
+	WebhookEventBreakGlassActivated   = "break_glass.activated"
+	WebhookEventBreakGlassEnded       = "break_glass.ended"
+	WebhookEventCredentialCompromised = "credential.compromised"
+	WebhookEventBudgetThreshold       = "budget.threshold"
+	WebhookEventAgentFailed           = "agent.failed"
+	WebhookEventConfigImported        = "config.imported"
+	// WebhookEventPing is sent by the webhook test API only
+	WebhookEventPing = "webhook.ping"
+	// WebhookEventAll subscribes a webhook to every event type
+	WebhookEventAll = "*"
+)
+
+// WebhookEventTypes lists the event types webhooks can subscribe to
+var WebhookEventTypes = []string{
+	WebhookEventServerUnhealthy,
+	WebhookEventQuotaNearing,
+	WebhookEventCertificateExpiring,
+	WebhookEventApprovalPending,
+	WebhookEventOwnerAlertEscalated,
+	WebhookEventGrantExpiring,
+	WebhookEventBreakGlassActivated,
+	WebhookEventBreakGlassEnded,
+	WebhookEventCredentialCompromised,
+	WebhookEventBudgetThreshold,
+	WebhookEventAgentFailed,
+	WebhookEventConfigImported,
+}
+
+// WebhookEventForNotification maps notification types to the webhook
+// event types they are delivered under
+var WebhookEventForNotification = map[string]string{
+	NotificationServerUnhealthy:       WebhookEventServerUnhealthy,
+	NotificationQuotaNearing:          WebhookEventQuotaNearing,
+	NotificationCertificateExpiring:   WebhookEventCertificateExpiring,
+	NotificationApprovalPending:       WebhookEventApprovalPending,
+	NotificationOwnerAlertEscalated:   WebhookEventOwnerAlertEscalated,
+	NotificationGrantExpiring:         WebhookEventGrantExpiring,
+	NotificationBreakGlassActivated:   WebhookEventBreakGlassActivated,
+	NotificationBreakGlassEnded:       WebhookEventBreakGlassEnded,
+	NotificationCredentialCompromised: WebhookEventCredentialCompromised,
+	NotificationBudgetThreshold:       WebhookEventBudgetThreshold,
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is an organization's registration for gateway events
+type Webhook struct {
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Name           string    `json:"name"`
+	URL            string    `json:"url"`
+	// Secret keys the HMAC-SHA256 request signature of deliveries, whose
+	// key ID is SigningKeyID. Both are returned only when the webhook is
+	// created and when the secret is rotated.
+	Secret       string   `json:"secret,omitempty"`
+	SigningKeyID string   `json:"signing_key_id,omitempty"`
+	CreatedBy    string   `json:"created_by,omitempty"`
+	EventTypes   []string `json:"event_types"`
+	IsActive     bool     `json:"is_active"`
+}
+
+// CreateWebhookRequest registers a webhook
+type CreateWebhookRequest struct {
+	IsActive   *bool    `json:"is_active"`
+	Name       string   `json:"name" binding:"required,max=255"`
+	URL        string   `json:"url" binding:"required,url"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+}
+
+// UpdateWebhookRequest changes the fields of a webhook that are set
+type UpdateWebhookRequest struct {
+	Name       *string  `json:"name" binding:"omitempty,max=255"`
+	URL        *string  `json:"url" binding:"omitempty,url"`
+	IsActive   *bool    `json:"is_active"`
+	EventTypes []string `json:"event_types"`
+}
+
+// WebhookEvent is the body posted to webhooks
+type WebhookEvent struct {
+	CreatedAt      time.Time              `json:"created_at"`
+	Data           map[string]interface{} `json:"data"`
+	ID             string                 `json:"id"`
+	Type           string                 `json:"type"`
+	OrganizationID string                 `json:"organization_id"`
+}
+
+// WebhookDelivery is an event queued for, or delivered to, a webhook
+type WebhookDelivery struct {
+	CreatedAt      time.Time       `json:"created_at"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	OrganizationID string          `json:"organization_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	LastError      string          `json:"last_error,omitempty"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	DurationMs     int64           `json:"duration_ms,omitempty"`
+}
+
+// WebhookDeliveryFilter selects a page of a webhook's deliveries
+type WebhookDeliveryFilter struct {
+	WebhookID string
+	Status    string
+	Limit     int
+	Offset    int
+}
+
+// DueWebhookDelivery is a delivery claimed for an attempt, with the
+// webhook it goes to
+type DueWebhookDelivery struct {
+	WebhookDelivery
+	URL           string
+	Secret        string
+	WebhookActive bool
+}
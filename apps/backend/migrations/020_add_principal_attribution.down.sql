@@ -0,0 +1,9 @@
+-- Rollback: Remove principal attribution from log_index
+DROP INDEX IF EXISTS idx_log_index_org_principal;
+DROP INDEX IF EXISTS idx_log_index_api_key;
+DROP INDEX IF EXISTS idx_log_index_oauth_client;
+
+ALTER TABLE log_index
+DROP COLUMN IF EXISTS principal_type,
+DROP COLUMN IF EXISTS api_key_id,
+DROP COLUMN IF EXISTS oauth_client_id;
@@ -0,0 +1,10 @@
+-- Migration: Attribute log entries to the principal that issued the request
+ALTER TABLE log_index
+ADD COLUMN principal_type VARCHAR(20) CHECK (principal_type IN ('user', 'api_key', 'oauth_client', 'anonymous')),
+ADD COLUMN api_key_id UUID REFERENCES api_keys(id) ON DELETE SET NULL,
+ADD COLUMN oauth_client_id VARCHAR(255);
+
+-- Indexes for group-by-principal usage queries
+CREATE INDEX idx_log_index_org_principal ON log_index(organization_id, principal_type, started_at DESC);
+CREATE INDEX idx_log_index_api_key ON log_index(api_key_id, started_at DESC) WHERE api_key_id IS NOT NULL;
+CREATE INDEX idx_log_index_oauth_client ON log_index(oauth_client_id, started_at DESC) WHERE oauth_client_id IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Rollback: Remove attribution labels
+DROP INDEX IF EXISTS idx_api_keys_labels;
+DROP INDEX IF EXISTS idx_endpoints_labels;
+DROP INDEX IF EXISTS idx_log_index_labels;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS labels;
+ALTER TABLE endpoints DROP COLUMN IF EXISTS labels;
+ALTER TABLE log_index DROP COLUMN IF EXISTS labels;
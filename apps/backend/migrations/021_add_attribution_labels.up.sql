@@ -0,0 +1,14 @@
+-- Migration: Attribution labels (team, project, cost-center) for usage chargeback
+ALTER TABLE api_keys
+ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE endpoints
+ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE log_index
+ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';
+
+-- Indexes for label filters and group-bys
+CREATE INDEX idx_api_keys_labels ON api_keys USING GIN (labels);
+CREATE INDEX idx_endpoints_labels ON endpoints USING GIN (labels);
+CREATE INDEX idx_log_index_labels ON log_index USING GIN (labels);
@@ -0,0 +1,9 @@
+-- Rollback: Remove MCP message log table
+DROP INDEX IF EXISTS idx_mcp_message_logs_errors;
+DROP INDEX IF EXISTS idx_mcp_message_logs_session;
+DROP INDEX IF EXISTS idx_mcp_message_logs_tool;
+DROP INDEX IF EXISTS idx_mcp_message_logs_method;
+DROP INDEX IF EXISTS idx_mcp_message_logs_namespace;
+DROP INDEX IF EXISTS idx_mcp_message_logs_org_created;
+
+DROP TABLE IF EXISTS mcp_message_logs;
@@ -0,0 +1,39 @@
+-- Migration: Message-level MCP traffic log, separate from HTTP request logs
+CREATE TABLE mcp_message_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    namespace_id UUID REFERENCES namespaces(id) ON DELETE SET NULL,
+    endpoint_id UUID REFERENCES endpoints(id) ON DELETE SET NULL,
+    session_id VARCHAR(255),
+    transport VARCHAR(50) NOT NULL,
+    method VARCHAR(255) NOT NULL,
+    tool_name VARCHAR(255),
+    rpc_id VARCHAR(255),
+
+    -- Caller attribution
+    principal_type VARCHAR(20),
+    principal_id VARCHAR(255),
+
+    -- Outcome
+    is_error BOOLEAN NOT NULL DEFAULT false,
+    error_code INTEGER,
+    error_message TEXT,
+    status_code INTEGER,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    request_size INTEGER NOT NULL DEFAULT 0,
+    result_size INTEGER NOT NULL DEFAULT 0,
+
+    -- Request params with sensitive fields redacted
+    params JSONB DEFAULT '{}',
+    redacted_fields TEXT[] DEFAULT ARRAY[]::TEXT[],
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Indexes for debugging queries
+CREATE INDEX idx_mcp_message_logs_org_created ON mcp_message_logs(organization_id, created_at DESC);
+CREATE INDEX idx_mcp_message_logs_namespace ON mcp_message_logs(namespace_id, created_at DESC);
+CREATE INDEX idx_mcp_message_logs_method ON mcp_message_logs(method, created_at DESC);
+CREATE INDEX idx_mcp_message_logs_tool ON mcp_message_logs(tool_name, created_at DESC) WHERE tool_name IS NOT NULL;
+CREATE INDEX idx_mcp_message_logs_session ON mcp_message_logs(session_id) WHERE session_id IS NOT NULL;
+CREATE INDEX idx_mcp_message_logs_errors ON mcp_message_logs(organization_id, created_at DESC) WHERE is_error = true;
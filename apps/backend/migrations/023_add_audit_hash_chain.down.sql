@@ -0,0 +1,12 @@
+-- Rollback: Remove audit hash chain and severity tiers
+DROP INDEX IF EXISTS idx_audit_anchors_org_sequence;
+DROP TABLE IF EXISTS audit_anchors;
+
+DROP INDEX IF EXISTS idx_audit_logs_org_severity;
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS uq_audit_logs_org_sequence;
+
+ALTER TABLE audit_logs
+    DROP COLUMN IF EXISTS hash,
+    DROP COLUMN IF EXISTS prev_hash,
+    DROP COLUMN IF EXISTS sequence,
+    DROP COLUMN IF EXISTS severity;
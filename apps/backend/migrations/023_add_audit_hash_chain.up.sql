@@ -0,0 +1,28 @@
+-- Migration: Severity tiers and tamper-evident hash chain for audit logs
+ALTER TABLE audit_logs
+    ADD COLUMN severity VARCHAR(20) NOT NULL DEFAULT 'info'
+        CHECK (severity IN ('info', 'notice', 'warning', 'critical')),
+    ADD COLUMN sequence BIGINT,
+    ADD COLUMN prev_hash CHAR(64),
+    ADD COLUMN hash CHAR(64);
+
+-- Each organization has its own chain; sequence numbers must not repeat
+ALTER TABLE audit_logs
+    ADD CONSTRAINT uq_audit_logs_org_sequence UNIQUE (organization_id, sequence);
+
+CREATE INDEX idx_audit_logs_org_severity ON audit_logs(organization_id, severity, created_at DESC);
+
+-- Periodic digests of each organization's chain head
+CREATE TABLE audit_anchors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    sequence BIGINT NOT NULL,
+    record_hash CHAR(64) NOT NULL,
+    prev_digest CHAR(64) NOT NULL,
+    digest CHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE(organization_id, sequence)
+);
+
+CREATE INDEX idx_audit_anchors_org_sequence ON audit_anchors(organization_id, sequence DESC);
@@ -0,0 +1,5 @@
+-- Rollback: Remove feature flags table
+DROP INDEX IF EXISTS uq_feature_flags_org;
+DROP INDEX IF EXISTS uq_feature_flags_global;
+
+DROP TABLE IF EXISTS feature_flags;
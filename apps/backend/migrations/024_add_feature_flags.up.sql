@@ -0,0 +1,14 @@
+-- Migration: Feature flags for gradual rollouts of new subsystems
+CREATE TABLE feature_flags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    key VARCHAR(100) NOT NULL,
+    -- NULL organization_id is the deployment-wide value
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uq_feature_flags_global ON feature_flags(key) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX uq_feature_flags_org ON feature_flags(key, organization_id) WHERE organization_id IS NOT NULL;
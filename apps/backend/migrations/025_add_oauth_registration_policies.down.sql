@@ -0,0 +1,13 @@
+-- Rollback: Remove dynamic client registration policies
+DROP INDEX IF EXISTS idx_oauth_clients_dynamic_unused;
+
+ALTER TABLE oauth_clients
+    DROP COLUMN IF EXISTS last_used_at,
+    DROP COLUMN IF EXISTS software_version,
+    DROP COLUMN IF EXISTS software_id,
+    DROP COLUMN IF EXISTS dynamically_registered;
+
+DROP INDEX IF EXISTS idx_oauth_initial_access_tokens_org;
+
+DROP TABLE IF EXISTS oauth_initial_access_tokens;
+DROP TABLE IF EXISTS oauth_registration_policies;
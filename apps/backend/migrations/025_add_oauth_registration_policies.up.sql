@@ -0,0 +1,43 @@
+-- Migration: Dynamic client registration policies and initial access tokens
+
+-- Per-organization restrictions on dynamic client registration; organizations
+-- without a row register clients without restrictions
+CREATE TABLE oauth_registration_policies (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    require_initial_access_token BOOLEAN NOT NULL DEFAULT false,
+    require_software_statement BOOLEAN NOT NULL DEFAULT false,
+    allowed_grant_types TEXT[] DEFAULT ARRAY[]::TEXT[],
+    redirect_uri_patterns TEXT[] DEFAULT ARRAY[]::TEXT[],
+    trusted_software_issuers TEXT[] DEFAULT ARRAY[]::TEXT[],
+    software_statement_keys TEXT[] DEFAULT ARRAY[]::TEXT[], -- PEM encoded public keys
+    unused_client_ttl_hours INTEGER NOT NULL DEFAULT 0 CHECK (unused_client_ttl_hours >= 0),
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Initial access tokens (RFC 7591 section 3) authorize registration for an organization
+CREATE TABLE oauth_initial_access_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    token_hash VARCHAR(255) UNIQUE NOT NULL,
+    description TEXT,
+    max_uses INTEGER NOT NULL DEFAULT 0 CHECK (max_uses >= 0), -- 0 is unlimited
+    use_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_oauth_initial_access_tokens_org ON oauth_initial_access_tokens(organization_id);
+
+-- Track how clients were registered and when they were last used so unused
+-- dynamically registered clients can be expired
+ALTER TABLE oauth_clients
+    ADD COLUMN dynamically_registered BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN software_id VARCHAR(255),
+    ADD COLUMN software_version VARCHAR(100),
+    ADD COLUMN last_used_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_oauth_clients_dynamic_unused ON oauth_clients(organization_id, last_used_at) WHERE dynamically_registered;
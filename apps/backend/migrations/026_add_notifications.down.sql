@@ -0,0 +1,8 @@
+-- Rollback: Remove notification center tables
+DROP TABLE IF EXISTS notification_preferences;
+
+DROP INDEX IF EXISTS idx_notifications_dedup;
+DROP INDEX IF EXISTS idx_notifications_user_unread;
+DROP INDEX IF EXISTS idx_notifications_user_created;
+
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,4 @@
+ALTER TABLE namespace_tool_mappings
+    DROP COLUMN IF EXISTS argument_conflict,
+    DROP COLUMN IF EXISTS locked_arguments,
+    DROP COLUMN IF EXISTS argument_defaults;
@@ -0,0 +1,10 @@
+-- Migration: Argument templates for tools within a namespace
+
+-- Defaults fill in arguments the caller omits; locked arguments are always
+-- sent with the configured value. argument_conflict decides what happens
+-- when a caller passes a different value for a locked argument.
+ALTER TABLE namespace_tool_mappings
+    ADD COLUMN argument_defaults JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN locked_arguments JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN argument_conflict VARCHAR(20) NOT NULL DEFAULT 'reject'
+        CHECK (argument_conflict IN ('reject', 'override'));
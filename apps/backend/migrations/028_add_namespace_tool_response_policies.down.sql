@@ -0,0 +1,2 @@
+ALTER TABLE namespace_tool_mappings
+    DROP COLUMN IF EXISTS response_policy;
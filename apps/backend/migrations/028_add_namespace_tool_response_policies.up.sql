@@ -0,0 +1,6 @@
+-- Migration: Response policies for tools within a namespace
+
+-- JSONPath rules that drop or mask fields of a tool's result before it is
+-- returned to the caller, e.g. {"rules": [{"path": "$..api_key", "action": "mask"}]}
+ALTER TABLE namespace_tool_mappings
+    ADD COLUMN response_policy JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_namespaces_region;
+
+ALTER TABLE namespaces
+    DROP COLUMN IF EXISTS region;
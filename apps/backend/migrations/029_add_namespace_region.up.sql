@@ -0,0 +1,9 @@
+-- Migration: Data residency region for namespaces
+
+-- Region tag a namespace, and the endpoint that serves it, is pinned to.
+-- Empty means the namespace is not pinned. Servers declare their region with
+-- the "region" metadata key.
+ALTER TABLE namespaces
+    ADD COLUMN region VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_namespaces_region ON namespaces(region) WHERE region <> '';
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS organizations_legal_hold ON organizations;
+DROP TRIGGER IF EXISTS mcp_message_logs_legal_hold ON mcp_message_logs;
+DROP TRIGGER IF EXISTS log_index_legal_hold ON log_index;
+DROP TRIGGER IF EXISTS audit_anchors_legal_hold ON audit_anchors;
+DROP TRIGGER IF EXISTS audit_logs_legal_hold ON audit_logs;
+DROP FUNCTION IF EXISTS reject_legal_hold_changes();
+
+DROP TABLE IF EXISTS legal_holds;
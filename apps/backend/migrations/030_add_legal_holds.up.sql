@@ -0,0 +1,66 @@
+-- Migration: Legal holds on organizations
+
+-- A hold is active until it is released. Released holds are kept so the
+-- history of who placed and lifted each hold is preserved.
+CREATE TABLE legal_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id),
+    reason TEXT NOT NULL,
+    placed_by VARCHAR(255) NOT NULL,
+    placed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    released_by VARCHAR(255),
+    released_at TIMESTAMP WITH TIME ZONE,
+    release_reason TEXT
+);
+
+-- At most one active hold per organization
+CREATE UNIQUE INDEX idx_legal_holds_active ON legal_holds(organization_id) WHERE released_at IS NULL;
+CREATE INDEX idx_legal_holds_org_placed ON legal_holds(organization_id, placed_at DESC);
+
+-- Held logs and audit records cannot be modified or deleted, whatever the
+-- retention settings, and a held organization cannot be deleted
+CREATE OR REPLACE FUNCTION reject_legal_hold_changes()
+RETURNS TRIGGER AS $$
+DECLARE
+    held_org UUID;
+BEGIN
+    IF TG_TABLE_NAME = 'organizations' THEN
+        held_org := OLD.id;
+    ELSE
+        held_org := OLD.organization_id;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM legal_holds
+        WHERE organization_id = held_org AND released_at IS NULL
+    ) THEN
+        RAISE EXCEPTION 'organization % is under legal hold; % on % is not allowed', held_org, TG_OP, TG_TABLE_NAME
+            USING ERRCODE = 'restrict_violation';
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_legal_hold
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION reject_legal_hold_changes();
+
+CREATE TRIGGER audit_anchors_legal_hold
+    BEFORE UPDATE OR DELETE ON audit_anchors
+    FOR EACH ROW EXECUTE FUNCTION reject_legal_hold_changes();
+
+CREATE TRIGGER log_index_legal_hold
+    BEFORE UPDATE OR DELETE ON log_index
+    FOR EACH ROW EXECUTE FUNCTION reject_legal_hold_changes();
+
+CREATE TRIGGER mcp_message_logs_legal_hold
+    BEFORE UPDATE OR DELETE ON mcp_message_logs
+    FOR EACH ROW EXECUTE FUNCTION reject_legal_hold_changes();
+
+CREATE TRIGGER organizations_legal_hold
+    BEFORE DELETE ON organizations
+    FOR EACH ROW EXECUTE FUNCTION reject_legal_hold_changes();
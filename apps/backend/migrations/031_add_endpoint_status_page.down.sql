@@ -0,0 +1,2 @@
+ALTER TABLE endpoints
+    DROP COLUMN IF EXISTS enable_status_page;
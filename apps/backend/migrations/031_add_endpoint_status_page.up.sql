@@ -0,0 +1,3 @@
+-- Migration: Opt-in public status page per endpoint
+ALTER TABLE endpoints
+    ADD COLUMN enable_status_page BOOLEAN NOT NULL DEFAULT false;
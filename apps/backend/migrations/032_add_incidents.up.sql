@@ -0,0 +1,28 @@
+-- Migration: Admin-declared incidents
+
+-- Incidents annotate health history and metrics timelines and are referenced
+-- by alert notifications and public status pages. An incident is ongoing
+-- until it has an end time.
+CREATE TABLE incidents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('minor', 'major', 'critical')),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMP WITH TIME ZONE,
+    server_ids UUID[] NOT NULL DEFAULT '{}',
+    namespace_ids UUID[] NOT NULL DEFAULT '{}',
+    notes TEXT NOT NULL DEFAULT '',
+    postmortem_url TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE INDEX idx_incidents_org_started ON incidents(organization_id, started_at DESC);
+CREATE INDEX idx_incidents_ongoing ON incidents(organization_id) WHERE ended_at IS NULL;
+CREATE INDEX idx_incidents_server_ids ON incidents USING GIN (server_ids);
+CREATE INDEX idx_incidents_namespace_ids ON incidents USING GIN (namespace_ids);
+
+CREATE TRIGGER update_incidents_updated_at BEFORE UPDATE ON incidents FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
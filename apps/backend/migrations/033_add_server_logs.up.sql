@@ -0,0 +1,15 @@
+-- Migration: Captured output of gateway-launched upstream servers
+
+-- stdout/stderr lines of STDIO servers, kept for the configured retention so
+-- operators can debug upstream failures without host access. Rows are not
+-- tied to mcp_servers so output of servers being removed is still kept.
+CREATE TABLE server_logs (
+    id BIGSERIAL PRIMARY KEY,
+    server_id UUID NOT NULL,
+    stream VARCHAR(10) NOT NULL CHECK (stream IN ('stdout', 'stderr')),
+    line TEXT NOT NULL,
+    logged_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_server_logs_server_time ON server_logs(server_id, logged_at DESC);
+CREATE INDEX idx_server_logs_logged_at ON server_logs(logged_at);
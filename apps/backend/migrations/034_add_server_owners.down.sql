@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS server_owner_alerts;
+DROP TRIGGER IF EXISTS update_server_owners_updated_at ON server_owners;
+DROP TABLE IF EXISTS server_owners;
@@ -0,0 +1,44 @@
+-- Migration: Server owners and their state change alerts
+
+-- The owner of a server is told directly, by webhook and/or email, whenever
+-- that server changes state
+CREATE TABLE server_owners (
+    server_id UUID PRIMARY KEY REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    contact_name VARCHAR(255) NOT NULL DEFAULT '',
+    contact_email VARCHAR(255) NOT NULL DEFAULT '',
+    webhook_url TEXT NOT NULL DEFAULT '',
+    webhook_secret TEXT NOT NULL DEFAULT '',
+    -- Minutes an unhealthy alert may stay unacknowledged before organization
+    -- admins are alerted; NULL uses the gateway default
+    escalate_after_minutes INTEGER CHECK (escalate_after_minutes IS NULL OR escalate_after_minutes > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (contact_email <> '' OR webhook_url <> '')
+);
+
+CREATE INDEX idx_server_owners_org ON server_owners(organization_id);
+
+CREATE TRIGGER update_server_owners_updated_at BEFORE UPDATE ON server_owners FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- An alert is open until the owner acknowledges it or the server recovers.
+-- Open alerts past the escalation period are escalated once.
+CREATE TABLE server_owner_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    server_id UUID NOT NULL REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    previous_status VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    health_status VARCHAR(50) NOT NULL DEFAULT '',
+    delivery_error TEXT NOT NULL DEFAULT '',
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    acknowledged_by VARCHAR(255) NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    escalated_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_server_owner_alerts_server ON server_owner_alerts(server_id, created_at DESC);
+CREATE INDEX idx_server_owner_alerts_open ON server_owner_alerts(created_at)
+    WHERE acknowledged_at IS NULL AND resolved_at IS NULL AND escalated_at IS NULL;
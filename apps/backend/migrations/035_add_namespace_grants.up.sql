@@ -0,0 +1,19 @@
+-- Migration: Namespace-level access grants
+
+-- Grants give a user, or every user with an organization role, access to a
+-- single namespace. A namespace with grants is restricted to its grantees;
+-- namespaces without grants stay open to the whole organization.
+CREATE TABLE namespace_grants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    namespace_id UUID NOT NULL REFERENCES namespaces(id) ON DELETE CASCADE,
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('user', 'role')),
+    subject_id VARCHAR(255) NOT NULL,
+    access_level VARCHAR(20) NOT NULL CHECK (access_level IN ('read', 'execute', 'write', 'admin')),
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (namespace_id, subject_type, subject_id)
+);
+
+CREATE INDEX idx_namespace_grants_org ON namespace_grants(organization_id);
+CREATE INDEX idx_namespace_grants_subject ON namespace_grants(subject_type, subject_id);
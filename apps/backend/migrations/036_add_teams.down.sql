@@ -0,0 +1,10 @@
+DELETE FROM namespace_grants WHERE subject_type = 'team';
+ALTER TABLE namespace_grants DROP CONSTRAINT namespace_grants_subject_type_check;
+ALTER TABLE namespace_grants ADD CONSTRAINT namespace_grants_subject_type_check
+    CHECK (subject_type IN ('user', 'role'));
+
+DROP INDEX IF EXISTS idx_api_keys_team;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS team_id;
+
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
@@ -0,0 +1,43 @@
+-- Migration: Teams
+
+-- A team groups users of an organization. Members inherit the team's role
+-- when it ranks above their own, share ownership of the team's API keys and
+-- match namespace grants made to the team. Teams mapped to an identity
+-- provider group have their membership synced from that group.
+CREATE TABLE teams (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    role VARCHAR(50) NOT NULL DEFAULT '' CHECK (role IN ('', 'admin', 'user', 'viewer', 'api_user')),
+    idp_group VARCHAR(255) NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, name)
+);
+
+CREATE UNIQUE INDEX idx_teams_idp_group ON teams(organization_id, idp_group) WHERE idp_group <> '';
+
+CREATE TRIGGER update_teams_updated_at BEFORE UPDATE ON teams FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Members added by an admin are 'manual'; members added by an identity
+-- provider sync are 'idp' and are removed again when they leave the group.
+CREATE TABLE team_members (
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'idp')),
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX idx_team_members_user ON team_members(user_id);
+
+-- Team-owned API keys can be listed and revoked by every team member
+ALTER TABLE api_keys ADD COLUMN team_id UUID REFERENCES teams(id) ON DELETE SET NULL;
+CREATE INDEX idx_api_keys_team ON api_keys(team_id) WHERE team_id IS NOT NULL;
+
+-- Namespaces can be granted to teams
+ALTER TABLE namespace_grants DROP CONSTRAINT namespace_grants_subject_type_check;
+ALTER TABLE namespace_grants ADD CONSTRAINT namespace_grants_subject_type_check
+    CHECK (subject_type IN ('user', 'role', 'team'));
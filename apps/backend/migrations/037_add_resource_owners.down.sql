@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS delete_endpoints_owner ON endpoints;
+DROP TRIGGER IF EXISTS delete_namespaces_owner ON namespaces;
+DROP TRIGGER IF EXISTS delete_mcp_tools_owner ON mcp_tools;
+DROP TRIGGER IF EXISTS delete_mcp_servers_owner ON mcp_servers;
+DROP FUNCTION IF EXISTS delete_resource_owner();
+DROP TABLE IF EXISTS resource_owners;
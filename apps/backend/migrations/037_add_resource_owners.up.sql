@@ -0,0 +1,37 @@
+-- Migration: Resource ownership
+
+-- Records the user or team accountable for a server, tool, namespace or
+-- endpoint. Rows outlive their owners so that resources whose owning user
+-- was deactivated or deleted, or whose team was deleted or emptied, can be
+-- surfaced as orphaned and transferred.
+CREATE TABLE resource_owners (
+    resource_type VARCHAR(20) NOT NULL CHECK (resource_type IN ('server', 'tool', 'namespace', 'endpoint')),
+    resource_id UUID NOT NULL,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    owner_type VARCHAR(20) NOT NULL CHECK (owner_type IN ('user', 'team')),
+    owner_id UUID NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    assigned_by VARCHAR(255) NOT NULL DEFAULT '',
+    assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (resource_type, resource_id)
+);
+
+CREATE INDEX idx_resource_owners_owner ON resource_owners(organization_id, owner_type, owner_id);
+
+-- Ownership is forgotten with the resource
+CREATE OR REPLACE FUNCTION delete_resource_owner()
+RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM resource_owners WHERE resource_type = TG_ARGV[0] AND resource_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER delete_mcp_servers_owner AFTER DELETE ON mcp_servers
+    FOR EACH ROW EXECUTE FUNCTION delete_resource_owner('server');
+CREATE TRIGGER delete_mcp_tools_owner AFTER DELETE ON mcp_tools
+    FOR EACH ROW EXECUTE FUNCTION delete_resource_owner('tool');
+CREATE TRIGGER delete_namespaces_owner AFTER DELETE ON namespaces
+    FOR EACH ROW EXECUTE FUNCTION delete_resource_owner('namespace');
+CREATE TRIGGER delete_endpoints_owner AFTER DELETE ON endpoints
+    FOR EACH ROW EXECUTE FUNCTION delete_resource_owner('endpoint');
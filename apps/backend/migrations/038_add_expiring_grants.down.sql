@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS role_grants;
+
+DROP INDEX IF EXISTS idx_namespace_grants_expiry;
+ALTER TABLE namespace_grants DROP COLUMN IF EXISTS expired_at;
+ALTER TABLE namespace_grants DROP COLUMN IF EXISTS reminded_at;
+ALTER TABLE namespace_grants DROP COLUMN IF EXISTS expires_at;
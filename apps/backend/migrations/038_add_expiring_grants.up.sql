@@ -0,0 +1,30 @@
+-- Migration: Expiring access grants
+
+-- Namespace grants may be time-boxed. Expired grants are kept, and keep
+-- their namespace restricted, until an admin removes them.
+ALTER TABLE namespace_grants ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE namespace_grants ADD COLUMN reminded_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE namespace_grants ADD COLUMN expired_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_namespace_grants_expiry ON namespace_grants(expires_at)
+    WHERE expires_at IS NOT NULL AND expired_at IS NULL;
+
+-- Role grants raise a user's role until they expire or are revoked
+CREATE TABLE role_grants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL CHECK (role IN ('admin', 'user', 'viewer', 'api_user')),
+    reason TEXT NOT NULL DEFAULT '',
+    granted_by VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reminded_at TIMESTAMP WITH TIME ZONE,
+    expired_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_role_grants_org ON role_grants(organization_id, created_at DESC);
+CREATE INDEX idx_role_grants_active ON role_grants(user_id, expires_at)
+    WHERE revoked_at IS NULL AND expired_at IS NULL;
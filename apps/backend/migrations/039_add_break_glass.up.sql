@@ -0,0 +1,39 @@
+-- Migration: Break-glass emergency access
+
+-- Sealed emergency credentials. Only a hash of the credential is kept; the
+-- TOTP secret is the second factor every activation must present. A used
+-- credential stays unsealed until it is rotated.
+CREATE TABLE break_glass_credentials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    credential_hash VARCHAR(64) NOT NULL UNIQUE,
+    mfa_secret VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    rotated_at TIMESTAMP WITH TIME ZONE,
+    used_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE(organization_id, name)
+);
+
+CREATE INDEX idx_break_glass_credentials_org ON break_glass_credentials(organization_id);
+
+-- Emergency sessions opened with a credential. They end on their own at
+-- expires_at or earlier when an admin ends them.
+CREATE TABLE break_glass_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    credential_id UUID NOT NULL REFERENCES break_glass_credentials(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    remote_ip VARCHAR(64) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    ended_by VARCHAR(255) NOT NULL DEFAULT '',
+    action_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_break_glass_sessions_org ON break_glass_sessions(organization_id, started_at DESC);
+CREATE INDEX idx_break_glass_sessions_open ON break_glass_sessions(expires_at) WHERE ended_at IS NULL;
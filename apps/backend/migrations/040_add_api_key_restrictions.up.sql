@@ -0,0 +1,6 @@
+-- Migration: API key IP and origin restrictions
+
+-- Keys with allowed CIDR ranges or browser origins are refused when used
+-- from anywhere else. Empty lists leave the key unrestricted.
+ALTER TABLE api_keys ADD COLUMN allowed_cidrs TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_keys ADD COLUMN allowed_origins TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,19 @@
+-- Migration: Compromised credential reports
+
+-- Credentials revoked because a threat-intel feed or an admin reported them
+-- as leaked. Only a SHA-256 fingerprint of the secret is kept.
+CREATE TABLE compromised_credentials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    credential_type VARCHAR(20) NOT NULL CHECK (credential_type IN ('api_key', 'oauth_token', 'jwt')),
+    credential_id VARCHAR(255) NOT NULL DEFAULT '',
+    credential_name VARCHAR(255) NOT NULL DEFAULT '',
+    fingerprint VARCHAR(64) NOT NULL DEFAULT '',
+    source VARCHAR(20) NOT NULL CHECK (source IN ('threat_feed', 'admin')),
+    reporter VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_compromised_credentials_org ON compromised_credentials(organization_id, created_at DESC);
@@ -0,0 +1,5 @@
+ALTER TABLE oauth_clients DROP COLUMN IF EXISTS service_account_id;
+ALTER TABLE users DROP COLUMN IF EXISTS retired_at;
+ALTER TABLE users DROP COLUMN IF EXISTS created_by;
+ALTER TABLE users DROP COLUMN IF EXISTS description;
+ALTER TABLE users DROP COLUMN IF EXISTS account_type;
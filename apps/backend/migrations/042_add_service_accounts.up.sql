@@ -0,0 +1,20 @@
+-- Migration: Service accounts
+
+-- Service accounts are non-human principals of an organization. They live in
+-- users so that API keys, role grants, team memberships and audit logs refer
+-- to them like any other user, but they have no usable password and cannot
+-- sign in. Deleted service accounts are retired rather than removed so audit
+-- logs and usage keep naming them.
+ALTER TABLE users
+    ADD COLUMN account_type VARCHAR(20) NOT NULL DEFAULT 'human' CHECK (account_type IN ('human', 'service')),
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN retired_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_service_accounts ON users(organization_id) WHERE account_type = 'service' AND retired_at IS NULL;
+
+-- OAuth clients issued to a service account act as that account
+ALTER TABLE oauth_clients
+    ADD COLUMN service_account_id UUID REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_oauth_clients_service_account ON oauth_clients(service_account_id) WHERE service_account_id IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE mcp_message_logs DROP COLUMN IF EXISTS on_behalf_of;
+DROP TABLE IF EXISTS delegation_policies;
@@ -0,0 +1,18 @@
+-- Migration: Delegation policies
+
+-- A delegation policy lets a service account act on behalf of users of its
+-- organization with the X-On-Behalf-Of header. Empty lists place no limit.
+CREATE TABLE delegation_policies (
+    service_account_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    allowed_roles TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+    allowed_user_ids TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_delegation_policies_org ON delegation_policies(organization_id);
+
+-- The user a delegating service account acted for
+ALTER TABLE mcp_message_logs ADD COLUMN on_behalf_of VARCHAR(255);
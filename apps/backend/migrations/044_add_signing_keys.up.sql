@@ -0,0 +1,23 @@
+-- Migration: Request signing keys
+
+-- Keys the gateway signs outbound requests with, one set per destination:
+-- an A2A agent, or the owner webhook of an MCP server. The newest active key
+-- of a destination signs; revoked keys are kept so receivers can be told
+-- which key IDs to stop trusting. secret holds the HMAC secret or the
+-- ed25519 private key, as webhook secrets are stored.
+CREATE TABLE signing_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    destination_type VARCHAR(32) NOT NULL CHECK (destination_type IN ('a2a_agent', 'owner_webhook')),
+    destination_id UUID NOT NULL,
+    algorithm VARCHAR(32) NOT NULL CHECK (algorithm IN ('hmac-sha256', 'ed25519')),
+    secret TEXT NOT NULL,
+    public_key TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_signing_keys_destination ON signing_keys(destination_type, destination_id, created_at DESC)
+    WHERE revoked_at IS NULL;
+CREATE INDEX idx_signing_keys_org ON signing_keys(organization_id);
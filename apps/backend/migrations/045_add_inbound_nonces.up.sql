@@ -0,0 +1,12 @@
+-- Migration: Inbound request nonces
+
+-- Nonces of signed inbound calls, kept until their timestamp falls outside
+-- the accepted clock skew, so each call is accepted only once
+CREATE TABLE inbound_nonces (
+    scope VARCHAR(64) NOT NULL,
+    nonce VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (scope, nonce)
+);
+
+CREATE INDEX idx_inbound_nonces_expires_at ON inbound_nonces(expires_at);
@@ -0,0 +1,18 @@
+-- Migration: Organization branding
+
+-- Branding applied to the OpenAPI metadata and docs pages of an
+-- organization's public endpoints. Organizations without a row use the
+-- gateway's own branding.
+CREATE TABLE organization_branding (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    product_name VARCHAR(100),
+    logo_url TEXT,
+    primary_color VARCHAR(7),
+    accent_color VARCHAR(7),
+    contact_name VARCHAR(255),
+    contact_email VARCHAR(255),
+    contact_url TEXT,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS playground_history_settings;
+DROP TABLE IF EXISTS playground_executions;
@@ -0,0 +1,32 @@
+-- Migration: Tool playground execution history
+
+-- Tool calls users make from the endpoint test console and the inspector.
+-- History is private to the user unless the organization lets its admins
+-- view it.
+CREATE TABLE playground_executions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('sandbox', 'inspector')),
+    endpoint_id UUID REFERENCES endpoints(id) ON DELETE SET NULL,
+    server_id UUID REFERENCES mcp_servers(id) ON DELETE SET NULL,
+    namespace_id UUID REFERENCES namespaces(id) ON DELETE SET NULL,
+    tool_name VARCHAR(255) NOT NULL,
+    arguments JSONB NOT NULL DEFAULT '{}',
+    result JSONB,
+    is_error BOOLEAN NOT NULL DEFAULT false,
+    error_message TEXT,
+    duration_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_playground_executions_user ON playground_executions(organization_id, user_id, created_at DESC);
+
+-- Organizations without a row record history that only its owner can view
+CREATE TABLE playground_history_settings (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    admins_can_view BOOLEAN NOT NULL DEFAULT false,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,21 @@
+-- Migration: Saved log views
+
+-- Named log and audit queries. Views are private to their owner unless
+-- shared with the organization; pinned widgets are aggregates computed on
+-- demand over the view's logs.
+CREATE TABLE saved_log_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('logs', 'audit')),
+    filters JSONB NOT NULL DEFAULT '{}',
+    widgets JSONB NOT NULL DEFAULT '[]',
+    shared BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, owner_id, name)
+);
+
+CREATE INDEX idx_saved_log_views_shared ON saved_log_views(organization_id) WHERE shared;
@@ -0,0 +1,28 @@
+-- Migration: Bulk log exports
+
+-- Asynchronous CSV and Parquet exports of execution logs. The worker claims
+-- pending exports, writes the file to export storage and records where it
+-- is; files are removed once the export expires.
+CREATE TABLE log_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    format VARCHAR(20) NOT NULL CHECK (format IN ('csv', 'parquet')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'completed', 'failed', 'expired')),
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}',
+    row_count BIGINT NOT NULL DEFAULT 0,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    truncated BOOLEAN NOT NULL DEFAULT false,
+    storage_key TEXT,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_log_exports_org ON log_exports(organization_id, created_at DESC);
+CREATE INDEX idx_log_exports_pending ON log_exports(created_at) WHERE status = 'pending';
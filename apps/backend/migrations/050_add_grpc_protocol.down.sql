@@ -0,0 +1,3 @@
+-- PostgreSQL cannot drop an enum value, so 'grpc' stays in protocol_enum.
+-- Deactivate gRPC servers so nothing tries to reach them.
+UPDATE mcp_servers SET is_active = false WHERE protocol = 'grpc';
@@ -0,0 +1,4 @@
+-- Migration: gRPC upstream servers
+
+-- Upstream MCP servers reachable over a gRPC session stream
+ALTER TYPE protocol_enum ADD VALUE IF NOT EXISTS 'grpc';
@@ -0,0 +1,11 @@
+-- Migration: Log field policies
+
+-- Optional request log fields an organization leaves out, such as request
+-- and response bodies. Organizations without a row log every field.
+CREATE TABLE log_field_policies (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    omit_fields TEXT[] NOT NULL DEFAULT '{}',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sso_login_states;
+DROP TABLE IF EXISTS sso_identities;
+DROP TABLE IF EXISTS sso_providers;
@@ -0,0 +1,55 @@
+-- Migration: OIDC identity providers
+
+-- OpenID Connect providers members of an organization sign in with.
+-- group_roles maps IdP groups to roles; client_secret is stored as other
+-- integration secrets are. Providers only create accounts when asked to,
+-- and then only for an allow list of email domains.
+CREATE TABLE sso_providers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    display_name VARCHAR(255) NOT NULL,
+    issuer_url TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    client_secret TEXT NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{openid,email,profile}',
+    groups_claim VARCHAR(255) NOT NULL DEFAULT 'groups',
+    group_roles JSONB NOT NULL DEFAULT '{}',
+    default_role VARCHAR(50) NOT NULL DEFAULT 'user' CHECK (default_role IN ('admin', 'user', 'viewer', 'api_user')),
+    allowed_domains TEXT[] NOT NULL DEFAULT '{}',
+    auto_provision BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, issuer_url, client_id),
+    CHECK (NOT auto_provision OR cardinality(allowed_domains) > 0)
+);
+
+CREATE INDEX idx_sso_providers_org ON sso_providers(organization_id);
+
+-- Users' subjects at identity providers
+CREATE TABLE sso_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider_id UUID NOT NULL REFERENCES sso_providers(id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    email CITEXT NOT NULL,
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (provider_id, subject)
+);
+
+CREATE INDEX idx_sso_identities_user ON sso_identities(user_id);
+
+-- Sign-ins waiting for the identity provider to redirect back, keyed by a
+-- hash of the state parameter
+CREATE TABLE sso_login_states (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    provider_id UUID NOT NULL REFERENCES sso_providers(id) ON DELETE CASCADE,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    redirect_path TEXT NOT NULL DEFAULT '/',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_sso_login_states_expires ON sso_login_states(expires_at);
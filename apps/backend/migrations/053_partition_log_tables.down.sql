@@ -0,0 +1,57 @@
+-- Rollback: Copy partitioned log tables back into plain tables
+
+ALTER TABLE log_index RENAME TO log_index_partitioned;
+CREATE TABLE log_index (LIKE log_index_partitioned INCLUDING DEFAULTS INCLUDING CONSTRAINTS);
+INSERT INTO log_index SELECT * FROM log_index_partitioned;
+DROP TABLE log_index_partitioned CASCADE;
+ALTER TABLE log_index ADD PRIMARY KEY (id);
+ALTER TABLE log_index
+    ADD FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (server_id) REFERENCES mcp_servers(id) ON DELETE SET NULL,
+    ADD FOREIGN KEY (session_id) REFERENCES mcp_sessions(id) ON DELETE SET NULL,
+    ADD FOREIGN KEY (api_key_id) REFERENCES api_keys(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_log_index_org_time ON log_index(organization_id, started_at DESC);
+CREATE INDEX idx_log_index_server_time ON log_index(server_id, started_at DESC) WHERE server_id IS NOT NULL;
+CREATE INDEX idx_log_index_session ON log_index(session_id, started_at DESC) WHERE session_id IS NOT NULL;
+CREATE INDEX idx_log_index_method_time ON log_index(rpc_method, started_at DESC);
+CREATE INDEX idx_log_index_errors ON log_index(organization_id, started_at DESC) WHERE error_flag = true;
+CREATE INDEX idx_log_index_org_level_time ON log_index(organization_id, level, started_at DESC);
+CREATE INDEX idx_log_index_cleanup ON log_index(created_at);
+CREATE INDEX idx_log_index_client_id ON log_index(client_id) WHERE client_id IS NOT NULL;
+CREATE INDEX idx_log_index_org_method_time ON log_index(organization_id, rpc_method, started_at DESC);
+CREATE INDEX idx_log_index_org_principal ON log_index(organization_id, principal_type, started_at DESC);
+CREATE INDEX idx_log_index_api_key ON log_index(api_key_id, started_at DESC) WHERE api_key_id IS NOT NULL;
+CREATE INDEX idx_log_index_oauth_client ON log_index(oauth_client_id, started_at DESC) WHERE oauth_client_id IS NOT NULL;
+CREATE INDEX idx_log_index_labels ON log_index USING GIN (labels);
+
+CREATE TRIGGER log_index_legal_hold
+    BEFORE UPDATE OR DELETE ON log_index
+    FOR EACH ROW EXECUTE FUNCTION reject_legal_hold_changes();
+
+ALTER TABLE mcp_message_logs RENAME TO mcp_message_logs_partitioned;
+CREATE TABLE mcp_message_logs (LIKE mcp_message_logs_partitioned INCLUDING DEFAULTS INCLUDING CONSTRAINTS);
+INSERT INTO mcp_message_logs SELECT * FROM mcp_message_logs_partitioned;
+DROP TABLE mcp_message_logs_partitioned CASCADE;
+ALTER TABLE mcp_message_logs ADD PRIMARY KEY (id);
+ALTER TABLE mcp_message_logs ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE mcp_message_logs
+    ADD FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (namespace_id) REFERENCES namespaces(id) ON DELETE SET NULL,
+    ADD FOREIGN KEY (endpoint_id) REFERENCES endpoints(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_mcp_message_logs_org_created ON mcp_message_logs(organization_id, created_at DESC);
+CREATE INDEX idx_mcp_message_logs_namespace ON mcp_message_logs(namespace_id, created_at DESC);
+CREATE INDEX idx_mcp_message_logs_method ON mcp_message_logs(method, created_at DESC);
+CREATE INDEX idx_mcp_message_logs_tool ON mcp_message_logs(tool_name, created_at DESC) WHERE tool_name IS NOT NULL;
+CREATE INDEX idx_mcp_message_logs_session ON mcp_message_logs(session_id) WHERE session_id IS NOT NULL;
+CREATE INDEX idx_mcp_message_logs_errors ON mcp_message_logs(organization_id, created_at DESC) WHERE is_error = true;
+
+CREATE TRIGGER mcp_message_logs_legal_hold
+    BEFORE UPDATE OR DELETE ON mcp_message_logs
+    FOR EACH ROW EXECUTE FUNCTION reject_legal_hold_changes();
+
+-- health_checks stays partitioned as it was before, keeping the monthly
+-- partitions created since
+DROP FUNCTION IF EXISTS create_monthly_partition(TEXT, DATE);
+DROP VIEW IF EXISTS table_partitions;
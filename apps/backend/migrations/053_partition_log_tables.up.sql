@@ -0,0 +1,148 @@
+-- Migration: Monthly range partitions for log tables
+
+-- Partitions of partitioned tables with the range each covers; a NULL start
+-- or end stands for MINVALUE or MAXVALUE
+CREATE VIEW table_partitions AS
+SELECT parent.relname AS parent_table,
+       child.relname AS partition_name,
+       (regexp_match(pg_get_expr(child.relpartbound, child.oid), 'FROM \(''([^'']+)''\)'))[1]::timestamptz AS range_start,
+       (regexp_match(pg_get_expr(child.relpartbound, child.oid), 'TO \(''([^'']+)''\)'))[1]::timestamptz AS range_end
+FROM pg_inherits i
+JOIN pg_class parent ON parent.oid = i.inhparent
+JOIN pg_class child ON child.oid = i.inhrelid
+WHERE parent.relkind = 'p';
+
+-- Creates the partition of parent for the UTC month starting at
+-- month_start, named <parent>_pYYYYMM. Returns false when the month is
+-- already covered by a partition.
+CREATE OR REPLACE FUNCTION create_monthly_partition(parent TEXT, month_start DATE)
+RETURNS BOOLEAN AS $$
+DECLARE
+    range_from TIMESTAMPTZ := date_trunc('month', month_start::timestamp) AT TIME ZONE 'UTC';
+    range_to TIMESTAMPTZ := (date_trunc('month', month_start::timestamp) + INTERVAL '1 month') AT TIME ZONE 'UTC';
+    new_partition TEXT := parent || '_p' || to_char(month_start, 'YYYYMM');
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM table_partitions
+        WHERE parent_table = parent
+          AND COALESCE(range_start, '-infinity') < range_to
+          AND COALESCE(range_end, 'infinity') > range_from
+    ) THEN
+        RETURN false;
+    END IF;
+
+    EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+        new_partition, parent, range_from, range_to);
+    RETURN true;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Attaches a table that held all of parent's rows as the partition below
+-- the start of the month after its newest row, or of next month
+CREATE OR REPLACE FUNCTION attach_legacy_partition(parent TEXT, legacy TEXT, key_column TEXT)
+RETURNS VOID AS $$
+DECLARE
+    newest TIMESTAMPTZ;
+    range_to TIMESTAMPTZ;
+BEGIN
+    EXECUTE format('SELECT MAX(%I) FROM %I', key_column, legacy) INTO newest;
+    range_to := (date_trunc('month', GREATEST(COALESCE(newest, NOW()), NOW()) AT TIME ZONE 'UTC') + INTERVAL '1 month') AT TIME ZONE 'UTC';
+    EXECUTE format('ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (MINVALUE) TO (%L)', parent, legacy, range_to);
+END;
+$$ LANGUAGE plpgsql;
+
+-- log_index: the existing table becomes the partition holding everything
+-- up to next month, so no rows are copied. It is dropped once all of its
+-- rows are past retention.
+DROP TRIGGER IF EXISTS log_index_legal_hold ON log_index;
+DROP INDEX IF EXISTS idx_log_index_org_time;
+DROP INDEX IF EXISTS idx_log_index_server_time;
+DROP INDEX IF EXISTS idx_log_index_session;
+DROP INDEX IF EXISTS idx_log_index_method_time;
+DROP INDEX IF EXISTS idx_log_index_errors;
+DROP INDEX IF EXISTS idx_log_index_org_level_time;
+DROP INDEX IF EXISTS idx_log_index_cleanup;
+DROP INDEX IF EXISTS idx_log_index_client_id;
+DROP INDEX IF EXISTS idx_log_index_org_method_time;
+DROP INDEX IF EXISTS idx_log_index_org_principal;
+DROP INDEX IF EXISTS idx_log_index_api_key;
+DROP INDEX IF EXISTS idx_log_index_oauth_client;
+DROP INDEX IF EXISTS idx_log_index_labels;
+ALTER TABLE log_index RENAME TO log_index_legacy;
+ALTER TABLE log_index_legacy RENAME CONSTRAINT log_index_pkey TO log_index_legacy_pkey;
+
+CREATE TABLE log_index (LIKE log_index_legacy INCLUDING DEFAULTS INCLUDING CONSTRAINTS)
+    PARTITION BY RANGE (started_at);
+ALTER TABLE log_index ADD PRIMARY KEY (id, started_at);
+ALTER TABLE log_index
+    ADD FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (server_id) REFERENCES mcp_servers(id) ON DELETE SET NULL,
+    ADD FOREIGN KEY (session_id) REFERENCES mcp_sessions(id) ON DELETE SET NULL,
+    ADD FOREIGN KEY (api_key_id) REFERENCES api_keys(id) ON DELETE SET NULL;
+
+SELECT attach_legacy_partition('log_index', 'log_index_legacy', 'started_at');
+
+CREATE INDEX idx_log_index_org_time ON log_index(organization_id, started_at DESC);
+CREATE INDEX idx_log_index_server_time ON log_index(server_id, started_at DESC) WHERE server_id IS NOT NULL;
+CREATE INDEX idx_log_index_session ON log_index(session_id, started_at DESC) WHERE session_id IS NOT NULL;
+CREATE INDEX idx_log_index_method_time ON log_index(rpc_method, started_at DESC);
+CREATE INDEX idx_log_index_errors ON log_index(organization_id, started_at DESC) WHERE error_flag = true;
+CREATE INDEX idx_log_index_org_level_time ON log_index(organization_id, level, started_at DESC);
+CREATE INDEX idx_log_index_cleanup ON log_index(created_at);
+CREATE INDEX idx_log_index_client_id ON log_index(client_id) WHERE client_id IS NOT NULL;
+CREATE INDEX idx_log_index_org_method_time ON log_index(organization_id, rpc_method, started_at DESC);
+CREATE INDEX idx_log_index_org_principal ON log_index(organization_id, principal_type, started_at DESC);
+CREATE INDEX idx_log_index_api_key ON log_index(api_key_id, started_at DESC) WHERE api_key_id IS NOT NULL;
+CREATE INDEX idx_log_index_oauth_client ON log_index(oauth_client_id, started_at DESC) WHERE oauth_client_id IS NOT NULL;
+CREATE INDEX idx_log_index_labels ON log_index USING GIN (labels);
+
+CREATE TRIGGER log_index_legal_hold
+    BEFORE UPDATE OR DELETE ON log_index
+    FOR EACH ROW EXECUTE FUNCTION reject_legal_hold_changes();
+
+-- mcp_message_logs, likewise. Rows always get a creation time now that it
+-- is the partition key.
+DROP TRIGGER IF EXISTS mcp_message_logs_legal_hold ON mcp_message_logs;
+DROP INDEX IF EXISTS idx_mcp_message_logs_org_created;
+DROP INDEX IF EXISTS idx_mcp_message_logs_namespace;
+DROP INDEX IF EXISTS idx_mcp_message_logs_method;
+DROP INDEX IF EXISTS idx_mcp_message_logs_tool;
+DROP INDEX IF EXISTS idx_mcp_message_logs_session;
+DROP INDEX IF EXISTS idx_mcp_message_logs_errors;
+UPDATE mcp_message_logs SET created_at = 'epoch' WHERE created_at IS NULL;
+ALTER TABLE mcp_message_logs ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE mcp_message_logs RENAME TO mcp_message_logs_legacy;
+ALTER TABLE mcp_message_logs_legacy RENAME CONSTRAINT mcp_message_logs_pkey TO mcp_message_logs_legacy_pkey;
+
+CREATE TABLE mcp_message_logs (LIKE mcp_message_logs_legacy INCLUDING DEFAULTS INCLUDING CONSTRAINTS)
+    PARTITION BY RANGE (created_at);
+ALTER TABLE mcp_message_logs ADD PRIMARY KEY (id, created_at);
+ALTER TABLE mcp_message_logs
+    ADD FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    ADD FOREIGN KEY (namespace_id) REFERENCES namespaces(id) ON DELETE SET NULL,
+    ADD FOREIGN KEY (endpoint_id) REFERENCES endpoints(id) ON DELETE SET NULL;
+
+SELECT attach_legacy_partition('mcp_message_logs', 'mcp_message_logs_legacy', 'created_at');
+
+CREATE INDEX idx_mcp_message_logs_org_created ON mcp_message_logs(organization_id, created_at DESC);
+CREATE INDEX idx_mcp_message_logs_namespace ON mcp_message_logs(namespace_id, created_at DESC);
+CREATE INDEX idx_mcp_message_logs_method ON mcp_message_logs(method, created_at DESC);
+CREATE INDEX idx_mcp_message_logs_tool ON mcp_message_logs(tool_name, created_at DESC) WHERE tool_name IS NOT NULL;
+CREATE INDEX idx_mcp_message_logs_session ON mcp_message_logs(session_id) WHERE session_id IS NOT NULL;
+CREATE INDEX idx_mcp_message_logs_errors ON mcp_message_logs(organization_id, created_at DESC) WHERE is_error = true;
+
+CREATE TRIGGER mcp_message_logs_legal_hold
+    BEFORE UPDATE OR DELETE ON mcp_message_logs
+    FOR EACH ROW EXECUTE FUNCTION reject_legal_hold_changes();
+
+-- health_checks was partitioned from the start but only had a partition
+-- for the month it was created in
+ALTER TABLE IF EXISTS health_checks_current RENAME TO health_checks_legacy;
+
+DROP FUNCTION attach_legacy_partition(TEXT, TEXT, TEXT);
+
+-- Partitions for the next three months not already covered; the worker
+-- keeps creating them ahead of time from here on
+SELECT create_monthly_partition(t.name, (date_trunc('month', NOW() AT TIME ZONE 'UTC') + m.n * INTERVAL '1 month')::date)
+FROM (VALUES ('log_index'), ('mcp_message_logs'), ('health_checks')) AS t(name)
+CROSS JOIN generate_series(0, 2) AS m(n);
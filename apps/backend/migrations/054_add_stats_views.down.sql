@@ -0,0 +1,5 @@
+-- Rollback: Remove admin stats materialized views
+DROP TABLE IF EXISTS stats_view_refreshes;
+DROP MATERIALIZED VIEW IF EXISTS stats_active_users_daily;
+DROP MATERIALIZED VIEW IF EXISTS stats_server_requests_daily;
+DROP MATERIALIZED VIEW IF EXISTS stats_tool_usage_daily;
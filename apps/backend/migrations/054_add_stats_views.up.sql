@@ -0,0 +1,67 @@
+-- Migration: Materialized views behind the admin stats endpoint
+-- The views keep 90 days of daily aggregates and are refreshed by the
+-- worker; stats_view_refreshes records when each was last refreshed.
+
+CREATE MATERIALIZED VIEW stats_tool_usage_daily AS
+SELECT organization_id,
+       date_trunc('day', created_at AT TIME ZONE 'UTC')::date AS day,
+       tool_name,
+       COUNT(*) AS calls,
+       COUNT(*) FILTER (WHERE is_error) AS errors,
+       SUM(duration_ms) AS total_duration_ms
+FROM mcp_message_logs
+WHERE method = 'tools/call'
+  AND tool_name IS NOT NULL
+  AND organization_id IS NOT NULL
+  AND created_at >= NOW() - INTERVAL '90 days'
+GROUP BY 1, 2, 3;
+
+CREATE UNIQUE INDEX idx_stats_tool_usage_daily_key ON stats_tool_usage_daily(organization_id, day, tool_name);
+
+-- Requests not routed to a server count towards the nil server
+CREATE MATERIALIZED VIEW stats_server_requests_daily AS
+SELECT organization_id,
+       date_trunc('day', started_at AT TIME ZONE 'UTC')::date AS day,
+       COALESCE(server_id, '00000000-0000-0000-0000-000000000000') AS server_id,
+       COUNT(*) AS requests,
+       COUNT(*) FILTER (WHERE error_flag OR status_code >= 400) AS errors,
+       COALESCE(SUM(duration_ms), 0) AS total_duration_ms
+FROM log_index
+WHERE started_at >= NOW() - INTERVAL '90 days'
+GROUP BY 1, 2, 3;
+
+CREATE UNIQUE INDEX idx_stats_server_requests_daily_key ON stats_server_requests_daily(organization_id, day, server_id);
+
+CREATE MATERIALIZED VIEW stats_active_users_daily AS
+SELECT organization_id, day, user_id
+FROM (
+    SELECT organization_id,
+           date_trunc('day', started_at AT TIME ZONE 'UTC')::date AS day,
+           user_id
+    FROM log_index
+    WHERE principal_type = 'user'
+      AND user_id IS NOT NULL
+      AND started_at >= NOW() - INTERVAL '90 days'
+    UNION
+    SELECT organization_id,
+           date_trunc('day', created_at AT TIME ZONE 'UTC')::date,
+           principal_id
+    FROM mcp_message_logs
+    WHERE principal_type = 'user'
+      AND principal_id IS NOT NULL
+      AND organization_id IS NOT NULL
+      AND created_at >= NOW() - INTERVAL '90 days'
+) active;
+
+CREATE UNIQUE INDEX idx_stats_active_users_daily_key ON stats_active_users_daily(organization_id, day, user_id);
+
+CREATE TABLE stats_view_refreshes (
+    view_name VARCHAR(100) PRIMARY KEY,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_ms INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO stats_view_refreshes (view_name, refreshed_at)
+VALUES ('stats_tool_usage_daily', NOW()),
+       ('stats_server_requests_daily', NOW()),
+       ('stats_active_users_daily', NOW());
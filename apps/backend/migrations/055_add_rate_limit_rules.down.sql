@@ -0,0 +1,2 @@
+-- Rollback: Remove organization rate limit rules
+DROP TABLE IF EXISTS rate_limit_rules;
@@ -0,0 +1,30 @@
+-- Migration: Organization rate limit rules
+CREATE TABLE rate_limit_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+
+    -- organization, user, api_key or endpoint; target_id narrows a rule to
+    -- one user, API key or endpoint
+    type VARCHAR(20) NOT NULL CHECK (type IN ('organization', 'user', 'api_key', 'endpoint')),
+    target_id VARCHAR(255),
+
+    algorithm VARCHAR(20) NOT NULL CHECK (algorithm IN ('token_bucket', 'sliding_window')),
+    request_limit INTEGER NOT NULL CHECK (request_limit > 0),
+    window_seconds INTEGER NOT NULL CHECK (window_seconds > 0),
+    burst INTEGER NOT NULL DEFAULT 0 CHECK (burst >= 0),
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE (organization_id, name)
+);
+
+CREATE INDEX idx_rate_limit_rules_org_active ON rate_limit_rules(organization_id) WHERE is_active = true;
+
+CREATE TRIGGER rate_limit_rules_updated_at
+    BEFORE UPDATE ON rate_limit_rules
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,4 @@
+-- Rollback: Remove log archival
+DROP TABLE IF EXISTS archive_rehydrations;
+DROP TABLE IF EXISTS log_archives;
+DROP TABLE IF EXISTS archive_policies;
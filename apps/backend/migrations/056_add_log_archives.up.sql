@@ -0,0 +1,65 @@
+-- Migration: Archival of execution logs and session events to cold storage
+
+-- An organization's archive policy. Once enabled, the worker moves
+-- execution logs (log_index) and session events (mcp_message_logs) older
+-- than archive_after_days to archive storage, one object per UTC day.
+CREATE TABLE archive_policies (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    archive_after_days INTEGER NOT NULL DEFAULT 30 CHECK (archive_after_days > 0),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER archive_policies_updated_at
+    BEFORE UPDATE ON archive_policies
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- One archived day of a dataset. The rows live in storage_key as gzipped
+-- JSON Lines, described by the manifest next to it; what is kept here stays
+-- queryable after the rows left the database.
+CREATE TABLE log_archives (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    dataset VARCHAR(30) NOT NULL CHECK (dataset IN ('execution_logs', 'session_events')),
+    range_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    range_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    storage_key TEXT NOT NULL,
+    manifest_key TEXT NOT NULL,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    error_count BIGINT NOT NULL DEFAULT 0,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    sha256 VARCHAR(64) NOT NULL,
+    methods JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    UNIQUE (organization_id, dataset, range_start)
+);
+
+CREATE INDEX idx_log_archives_org_range ON log_archives(organization_id, range_start DESC);
+
+-- Temporary restores of archived days for investigation. The worker loads
+-- pending rehydrations back into the log tables and removes the rows again
+-- once they expire.
+CREATE TABLE archive_rehydrations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    datasets TEXT[] NOT NULL,
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    ttl_seconds INTEGER NOT NULL CHECK (ttl_seconds > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'completed', 'failed', 'expired')),
+    archive_count INTEGER NOT NULL DEFAULT 0,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_archive_rehydrations_org ON archive_rehydrations(organization_id, created_at DESC);
+CREATE INDEX idx_archive_rehydrations_pending ON archive_rehydrations(created_at) WHERE status = 'pending';
+CREATE INDEX idx_archive_rehydrations_expiry ON archive_rehydrations(expires_at) WHERE status = 'completed';
@@ -0,0 +1,19 @@
+-- Rollback: Remove catalog search
+DROP TRIGGER IF EXISTS delete_mcp_tools_embedding ON mcp_tools;
+DROP TRIGGER IF EXISTS delete_mcp_prompts_embedding ON mcp_prompts;
+DROP TRIGGER IF EXISTS delete_mcp_resources_embedding ON mcp_resources;
+DROP FUNCTION IF EXISTS delete_catalog_embedding();
+DROP TABLE IF EXISTS catalog_embeddings;
+
+DROP TRIGGER IF EXISTS mcp_tools_search_vector ON mcp_tools;
+DROP TRIGGER IF EXISTS mcp_prompts_search_vector ON mcp_prompts;
+DROP TRIGGER IF EXISTS mcp_resources_search_vector ON mcp_resources;
+DROP FUNCTION IF EXISTS mcp_tools_search_vector();
+DROP FUNCTION IF EXISTS mcp_prompts_search_vector();
+DROP FUNCTION IF EXISTS mcp_resources_search_vector();
+
+ALTER TABLE mcp_tools DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE mcp_prompts DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE mcp_resources DROP COLUMN IF EXISTS search_vector;
+
+DROP FUNCTION IF EXISTS catalog_search_vector(TEXT, TEXT, TEXT[], TEXT);
@@ -0,0 +1,105 @@
+-- Migration: Full-text and semantic search across tools, prompts and resources
+
+-- catalog_search_vector weighs names above descriptions and tags, and those
+-- above the remaining text. Names are indexed without stemming so partial
+-- identifiers such as "read_file" still match.
+CREATE OR REPLACE FUNCTION catalog_search_vector(names TEXT, summary TEXT, tags TEXT[], body TEXT)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(names, '')), 'A') ||
+           setweight(to_tsvector('english', coalesce(summary, '') || ' ' || coalesce(array_to_string(tags, ' '), '')), 'B') ||
+           setweight(to_tsvector('english', coalesce(body, '')), 'C');
+$$ LANGUAGE sql IMMUTABLE;
+
+ALTER TABLE mcp_tools ADD COLUMN search_vector tsvector;
+ALTER TABLE mcp_prompts ADD COLUMN search_vector tsvector;
+ALTER TABLE mcp_resources ADD COLUMN search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION mcp_tools_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := catalog_search_vector(NEW.name || ' ' || NEW.function_name, NEW.description, NEW.tags,
+        NEW.category::TEXT || ' ' || coalesce(NEW.documentation, ''));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION mcp_prompts_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := catalog_search_vector(NEW.name, NEW.description, NEW.tags,
+        NEW.category::TEXT || ' ' || NEW.prompt_template);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION mcp_resources_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := catalog_search_vector(NEW.name, NEW.description, NEW.tags,
+        NEW.resource_type::TEXT || ' ' || NEW.uri || ' ' || coalesce(NEW.mime_type, ''));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Index existing rows without touching updated_at
+ALTER TABLE mcp_tools DISABLE TRIGGER mcp_tools_updated_at;
+ALTER TABLE mcp_prompts DISABLE TRIGGER mcp_prompts_updated_at;
+ALTER TABLE mcp_resources DISABLE TRIGGER mcp_resources_updated_at;
+UPDATE mcp_tools SET search_vector = catalog_search_vector(name || ' ' || function_name, description, tags,
+    category::TEXT || ' ' || coalesce(documentation, ''));
+UPDATE mcp_prompts SET search_vector = catalog_search_vector(name, description, tags,
+    category::TEXT || ' ' || prompt_template);
+UPDATE mcp_resources SET search_vector = catalog_search_vector(name, description, tags,
+    resource_type::TEXT || ' ' || uri || ' ' || coalesce(mime_type, ''));
+ALTER TABLE mcp_tools ENABLE TRIGGER mcp_tools_updated_at;
+ALTER TABLE mcp_prompts ENABLE TRIGGER mcp_prompts_updated_at;
+ALTER TABLE mcp_resources ENABLE TRIGGER mcp_resources_updated_at;
+
+-- Keep the index current as entries change
+CREATE TRIGGER mcp_tools_search_vector
+    BEFORE INSERT OR UPDATE OF name, function_name, description, tags, category, documentation ON mcp_tools
+    FOR EACH ROW EXECUTE FUNCTION mcp_tools_search_vector();
+CREATE TRIGGER mcp_prompts_search_vector
+    BEFORE INSERT OR UPDATE OF name, description, tags, category, prompt_template ON mcp_prompts
+    FOR EACH ROW EXECUTE FUNCTION mcp_prompts_search_vector();
+CREATE TRIGGER mcp_resources_search_vector
+    BEFORE INSERT OR UPDATE OF name, description, tags, resource_type, uri, mime_type ON mcp_resources
+    FOR EACH ROW EXECUTE FUNCTION mcp_resources_search_vector();
+
+CREATE INDEX idx_mcp_tools_search ON mcp_tools USING gin(search_vector);
+CREATE INDEX idx_mcp_prompts_search ON mcp_prompts USING gin(search_vector);
+CREATE INDEX idx_mcp_resources_search ON mcp_resources USING gin(search_vector);
+
+-- Embeddings of catalog entries for semantic search, computed by the worker
+-- when an embeddings API is configured. content_hash is the MD5 of the
+-- embedded text, so changed entries are embedded again. Embeddings are
+-- plain arrays; where the pgvector extension is installed they are cast to
+-- vectors for ranking.
+CREATE TABLE catalog_embeddings (
+    item_type VARCHAR(20) NOT NULL CHECK (item_type IN ('tool', 'prompt', 'resource')),
+    item_id UUID NOT NULL,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    model VARCHAR(255) NOT NULL,
+    content_hash VARCHAR(32) NOT NULL,
+    embedding REAL[] NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (item_type, item_id)
+);
+
+CREATE INDEX idx_catalog_embeddings_org ON catalog_embeddings(organization_id, item_type);
+
+-- Embeddings are forgotten with their entry
+CREATE OR REPLACE FUNCTION delete_catalog_embedding()
+RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM catalog_embeddings WHERE item_type = TG_ARGV[0] AND item_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER delete_mcp_tools_embedding AFTER DELETE ON mcp_tools
+    FOR EACH ROW EXECUTE FUNCTION delete_catalog_embedding('tool');
+CREATE TRIGGER delete_mcp_prompts_embedding AFTER DELETE ON mcp_prompts
+    FOR EACH ROW EXECUTE FUNCTION delete_catalog_embedding('prompt');
+CREATE TRIGGER delete_mcp_resources_embedding AFTER DELETE ON mcp_resources
+    FOR EACH ROW EXECUTE FUNCTION delete_catalog_embedding('resource');
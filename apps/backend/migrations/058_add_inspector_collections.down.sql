@@ -0,0 +1,2 @@
+-- Rollback: Remove saved inspector request collections
+DROP TABLE IF EXISTS inspector_collections;
@@ -0,0 +1,23 @@
+-- Migration: Saved inspector request collections per server
+CREATE TABLE inspector_collections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    server_id UUID NOT NULL REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+
+    -- Ordered JSON-RPC requests: [{name, method, params, notification}]
+    requests JSONB NOT NULL DEFAULT '[]',
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE (server_id, name)
+);
+
+CREATE INDEX idx_inspector_collections_server ON inspector_collections(organization_id, server_id);
+
+CREATE TRIGGER inspector_collections_updated_at
+    BEFORE UPDATE ON inspector_collections
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
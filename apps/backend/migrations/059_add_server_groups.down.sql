@@ -0,0 +1,3 @@
+-- Rollback: Remove server groups
+DROP TABLE IF EXISTS server_group_members;
+DROP TABLE IF EXISTS server_groups;
@@ -0,0 +1,27 @@
+-- Migration: Server groups of interchangeable replicas of an MCP server
+CREATE TABLE server_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    strategy VARCHAR(32) NOT NULL DEFAULT 'round_robin'
+        CHECK (strategy IN ('round_robin', 'least_connections', 'weighted')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE (organization_id, name)
+);
+
+-- A server is a replica of at most one group
+CREATE TABLE server_group_members (
+    group_id UUID NOT NULL REFERENCES server_groups(id) ON DELETE CASCADE,
+    server_id UUID NOT NULL UNIQUE REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    weight INTEGER NOT NULL DEFAULT 1 CHECK (weight > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (group_id, server_id)
+);
+
+CREATE TRIGGER server_groups_updated_at
+    BEFORE UPDATE ON server_groups
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
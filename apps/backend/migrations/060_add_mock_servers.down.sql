@@ -0,0 +1,6 @@
+-- Rollback: Remove mock MCP servers
+DROP TABLE IF EXISTS mock_tools;
+
+-- PostgreSQL cannot drop an enum value, so 'mock' stays in protocol_enum.
+-- Deactivate mock servers since nothing answers them anymore.
+UPDATE mcp_servers SET is_active = false WHERE protocol = 'mock';
@@ -0,0 +1,25 @@
+-- Migration: Mock MCP servers
+
+-- Servers the gateway answers itself from canned tool definitions
+ALTER TYPE protocol_enum ADD VALUE IF NOT EXISTS 'mock';
+
+-- Canned tools of mock servers. response is a Go text/template rendered with
+-- the call's arguments.
+CREATE TABLE mock_tools (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    server_id UUID NOT NULL REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    input_schema JSONB NOT NULL DEFAULT '{"type": "object"}',
+    response TEXT NOT NULL DEFAULT '',
+    is_error BOOLEAN NOT NULL DEFAULT false,
+    delay_ms INTEGER NOT NULL DEFAULT 0 CHECK (delay_ms BETWEEN 0 AND 60000),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE (server_id, name)
+);
+
+CREATE TRIGGER mock_tools_updated_at
+    BEFORE UPDATE ON mock_tools
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
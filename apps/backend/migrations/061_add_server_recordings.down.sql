@@ -0,0 +1,3 @@
+-- Rollback: Remove record-and-replay of upstream server responses
+DROP TABLE IF EXISTS server_recordings;
+DROP TABLE IF EXISTS server_recording_modes;
@@ -0,0 +1,32 @@
+-- Migration: Record-and-replay of upstream server responses
+
+-- Servers that record their responses or are answered from recordings.
+-- Servers without a row send requests upstream unrecorded.
+CREATE TABLE server_recording_modes (
+    server_id UUID PRIMARY KEY REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    mode VARCHAR(16) NOT NULL CHECK (mode IN ('record', 'replay')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Recorded responses, matched to requests by fingerprint
+CREATE TABLE server_recordings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    server_id UUID NOT NULL REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    fingerprint VARCHAR(64) NOT NULL,
+    method VARCHAR(255) NOT NULL,
+    params JSONB,
+    result JSONB,
+    error JSONB,
+    replay_count INTEGER NOT NULL DEFAULT 0,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_replayed_at TIMESTAMP WITH TIME ZONE,
+
+    UNIQUE (server_id, fingerprint)
+);
+
+CREATE INDEX idx_server_recordings_method ON server_recordings(server_id, method);
+
+CREATE TRIGGER server_recording_modes_updated_at
+    BEFORE UPDATE ON server_recording_modes
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
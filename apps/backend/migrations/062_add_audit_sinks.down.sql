@@ -0,0 +1,2 @@
+-- Rollback: Remove streaming of audit records to SIEM sinks
+DROP TABLE IF EXISTS audit_sinks;
@@ -0,0 +1,35 @@
+-- Migration: Streaming of audit records to SIEM sinks
+
+-- Per-organization destinations audit records are streamed to by the
+-- worker. cursor_sequence is the sequence of the last chained record
+-- handled, so delivery resumes where it stopped; next_attempt_at holds
+-- back a sink that is being delivered to or is backing off after failures.
+CREATE TABLE audit_sinks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('syslog', 'webhook', 'kafka')),
+    format VARCHAR(16) NOT NULL DEFAULT 'json' CHECK (format IN ('json', 'cef')),
+    config JSONB NOT NULL DEFAULT '{}',
+    secret TEXT,
+    min_severity VARCHAR(16) NOT NULL DEFAULT 'info'
+        CHECK (min_severity IN ('info', 'notice', 'warning', 'critical')),
+    batch_size INTEGER NOT NULL DEFAULT 100 CHECK (batch_size BETWEEN 1 AND 1000),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    cursor_sequence BIGINT NOT NULL DEFAULT 0,
+    failures INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    last_delivered_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE (organization_id, name)
+);
+
+CREATE INDEX idx_audit_sinks_due ON audit_sinks(next_attempt_at) WHERE enabled;
+
+CREATE TRIGGER audit_sinks_updated_at
+    BEFORE UPDATE ON audit_sinks
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
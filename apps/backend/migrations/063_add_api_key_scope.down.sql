@@ -0,0 +1,3 @@
+ALTER TABLE api_keys DROP COLUMN IF EXISTS allowed_tools;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS allowed_servers;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS allowed_namespaces;
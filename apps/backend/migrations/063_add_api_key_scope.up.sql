@@ -0,0 +1,8 @@
+-- Migration: API key namespace, server and tool scoping
+
+-- Scoped keys may only reach the listed namespaces and servers and call
+-- tools matching the listed name patterns. Empty lists leave the key
+-- unrestricted.
+ALTER TABLE api_keys ADD COLUMN allowed_namespaces TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_keys ADD COLUMN allowed_servers TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_keys ADD COLUMN allowed_tools TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS user_tokens;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deactivated_at,
+    DROP COLUMN IF EXISTS invited_by,
+    DROP COLUMN IF EXISTS invited_at;
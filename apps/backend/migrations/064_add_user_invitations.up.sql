@@ -0,0 +1,25 @@
+-- Migration: User invitations, deactivation and password resets
+
+-- Invited users exist from the invitation on but cannot sign in until they
+-- accept it and choose a password. Deactivated users are kept with their
+-- API keys and memberships so they can be reactivated.
+ALTER TABLE users
+    ADD COLUMN invited_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN deactivated_at TIMESTAMP WITH TIME ZONE;
+
+-- Single-use tokens emailed to users to accept an invitation or reset their
+-- password. Only a SHA-256 hash of each token is stored.
+CREATE TABLE user_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL CHECK (purpose IN ('invitation', 'password_reset')),
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_tokens_user ON user_tokens(user_id, purpose) WHERE used_at IS NULL;
@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS endpoints_catalog_change ON endpoints;
+DROP TRIGGER IF EXISTS namespace_server_mappings_catalog_change ON namespace_server_mappings;
+DROP TRIGGER IF EXISTS namespaces_catalog_change ON namespaces;
+DROP TRIGGER IF EXISTS mcp_servers_catalog_change ON mcp_servers;
+
+DROP FUNCTION IF EXISTS log_namespace_membership_change();
+DROP FUNCTION IF EXISTS log_catalog_change();
+DROP FUNCTION IF EXISTS record_catalog_change(TEXT, UUID, UUID, TEXT, JSONB);
+DROP FUNCTION IF EXISTS catalog_namespace_payload(UUID);
+DROP FUNCTION IF EXISTS catalog_replicating();
+
+DROP TABLE IF EXISTS catalog_replication_peers;
+DROP TABLE IF EXISTS catalog_versions;
+DROP TABLE IF EXISTS catalog_changes;
@@ -0,0 +1,155 @@
+-- Migration: Asynchronous catalog replication between regions
+
+-- Every local change to a server, a namespace with its server memberships,
+-- or an endpoint is logged for peer regions to pull. Changes applied from a
+-- peer are not logged again, so each change is served by the region where
+-- it was made.
+CREATE TABLE catalog_changes (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('server', 'namespace', 'endpoint')),
+    entity_id UUID NOT NULL,
+    organization_id UUID NOT NULL,
+    operation VARCHAR(10) NOT NULL CHECK (operation IN ('upsert', 'delete')),
+    payload JSONB,
+    version TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- The version of each catalog entry, which decides between concurrent edits
+-- made in different regions. origin_region is empty for local edits.
+-- Deleted entries stay as tombstones so an older edit cannot revive them.
+CREATE TABLE catalog_versions (
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id UUID NOT NULL,
+    version TIMESTAMP WITH TIME ZONE NOT NULL,
+    origin_region VARCHAR(100) NOT NULL DEFAULT '',
+    deleted BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (entity_type, entity_id)
+);
+
+-- Replication progress from each peer region
+CREATE TABLE catalog_replication_peers (
+    region VARCHAR(100) PRIMARY KEY,
+    last_change_id BIGINT NOT NULL DEFAULT 0,
+    applied_count BIGINT NOT NULL DEFAULT 0,
+    superseded_count BIGINT NOT NULL DEFAULT 0,
+    last_pulled_at TIMESTAMP WITH TIME ZONE,
+    last_applied_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT ''
+);
+
+-- Sessions applying peer changes set omnimesh.replicating so the triggers
+-- below do not log them again
+CREATE OR REPLACE FUNCTION catalog_replicating()
+RETURNS BOOLEAN AS $$
+    SELECT COALESCE(current_setting('omnimesh.replicating', true), '') = 'on';
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION catalog_namespace_payload(ns_id UUID)
+RETURNS JSONB AS $$
+    SELECT to_jsonb(n) || jsonb_build_object('servers', COALESCE(
+        (SELECT jsonb_agg(to_jsonb(m) ORDER BY m.server_id)
+         FROM namespace_server_mappings m WHERE m.namespace_id = n.id),
+        '[]'::jsonb))
+    FROM namespaces n WHERE n.id = ns_id;
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION record_catalog_change(kind TEXT, entry_id UUID, org_id UUID, op TEXT, body JSONB)
+RETURNS void AS $$
+DECLARE
+    changed_at TIMESTAMP WITH TIME ZONE := clock_timestamp();
+BEGIN
+    INSERT INTO catalog_changes (entity_type, entity_id, organization_id, operation, payload, version)
+    VALUES (kind, entry_id, org_id, op, body, changed_at);
+
+    INSERT INTO catalog_versions (entity_type, entity_id, version, origin_region, deleted)
+    VALUES (kind, entry_id, changed_at, '', op = 'delete')
+    ON CONFLICT (entity_type, entity_id) DO UPDATE
+        SET version = EXCLUDED.version, origin_region = '', deleted = EXCLUDED.deleted;
+END;
+$$ LANGUAGE plpgsql;
+
+-- log_catalog_change logs a row change under the entity type given as the
+-- first argument. Further arguments name regional columns, such as health
+-- status, that are neither replicated nor count as a change.
+CREATE OR REPLACE FUNCTION log_catalog_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    kind TEXT := TG_ARGV[0];
+    regional TEXT[] := TG_ARGV[1:];
+    body JSONB;
+BEGIN
+    IF catalog_replicating() THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        PERFORM record_catalog_change(kind, OLD.id, OLD.organization_id, 'delete', NULL);
+        RETURN NULL;
+    END IF;
+
+    body := to_jsonb(NEW) - regional - 'updated_at';
+    IF TG_OP = 'UPDATE' AND body = to_jsonb(OLD) - regional - 'updated_at' THEN
+        RETURN NULL;
+    END IF;
+
+    IF kind = 'namespace' THEN
+        body := catalog_namespace_payload(NEW.id);
+    ELSE
+        body := to_jsonb(NEW) - regional;
+    END IF;
+    PERFORM record_catalog_change(kind, NEW.id, NEW.organization_id, 'upsert', body);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Membership changes are logged as a new version of the namespace. When the
+-- namespace itself is deleted its delete is logged instead.
+CREATE OR REPLACE FUNCTION log_namespace_membership_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    ns_id UUID;
+    org_id UUID;
+BEGIN
+    IF catalog_replicating() THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        ns_id := OLD.namespace_id;
+    ELSE
+        ns_id := NEW.namespace_id;
+    END IF;
+    SELECT organization_id INTO org_id FROM namespaces WHERE id = ns_id;
+    IF org_id IS NOT NULL THEN
+        PERFORM record_catalog_change('namespace', ns_id, org_id, 'upsert', catalog_namespace_payload(ns_id));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER mcp_servers_catalog_change
+    AFTER INSERT OR UPDATE OR DELETE ON mcp_servers
+    FOR EACH ROW EXECUTE FUNCTION log_catalog_change('server', 'status');
+
+CREATE TRIGGER namespaces_catalog_change
+    AFTER INSERT OR UPDATE OR DELETE ON namespaces
+    FOR EACH ROW EXECUTE FUNCTION log_catalog_change('namespace');
+
+CREATE TRIGGER namespace_server_mappings_catalog_change
+    AFTER INSERT OR UPDATE OR DELETE ON namespace_server_mappings
+    FOR EACH ROW EXECUTE FUNCTION log_namespace_membership_change();
+
+CREATE TRIGGER endpoints_catalog_change
+    AFTER INSERT OR UPDATE OR DELETE ON endpoints
+    FOR EACH ROW EXECUTE FUNCTION log_catalog_change('endpoint');
+
+-- Log the existing catalog so peers start from a full copy
+SELECT record_catalog_change('server', id, organization_id, 'upsert', to_jsonb(s) - 'status')
+FROM mcp_servers s ORDER BY created_at;
+SELECT record_catalog_change('namespace', id, organization_id, 'upsert', catalog_namespace_payload(id))
+FROM namespaces ORDER BY created_at;
+SELECT record_catalog_change('endpoint', id, organization_id, 'upsert', to_jsonb(e))
+FROM endpoints e ORDER BY created_at;
+
+CREATE INDEX idx_catalog_changes_entity ON catalog_changes(entity_type, entity_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS region_failovers;
+DROP TABLE IF EXISTS deployment_state;
@@ -0,0 +1,36 @@
+-- Migration: Disaster-recovery failover between regions
+
+-- The deployment's role, kept in its own database so every API and worker
+-- instance of the region agrees on it. Only the active region runs quota
+-- checks, telemetry and webhook deliveries; a fenced region is also
+-- read-only. epoch increases on every change so instances notice it and
+-- drop their caches. The row is absent until the first failover, and the
+-- configured role applies.
+CREATE TABLE deployment_state (
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    role VARCHAR(20) NOT NULL CHECK (role IN ('active', 'standby')),
+    read_only BOOLEAN NOT NULL DEFAULT false,
+    read_only_reason TEXT NOT NULL DEFAULT '',
+    -- fenced_by is the region that took over from this one
+    fenced_by VARCHAR(100) NOT NULL DEFAULT '',
+    epoch BIGINT NOT NULL DEFAULT 0,
+    changed_by VARCHAR(255) NOT NULL DEFAULT '',
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Every failover run in this region, dry runs included, with the outcome
+-- of each step
+CREATE TABLE region_failovers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    from_region VARCHAR(100) NOT NULL,
+    to_region VARCHAR(100) NOT NULL,
+    reason TEXT NOT NULL,
+    initiated_by VARCHAR(255) NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'completed', 'partial', 'failed')),
+    steps JSONB NOT NULL DEFAULT '[]',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_region_failovers_started ON region_failovers(started_at DESC);
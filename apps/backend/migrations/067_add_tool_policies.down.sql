@@ -0,0 +1,5 @@
+DELETE FROM policies WHERE type = 'tool';
+
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_type_check;
+ALTER TABLE policies ADD CONSTRAINT policies_type_check
+    CHECK (type IN ('access', 'rate_limit', 'security'));
@@ -0,0 +1,8 @@
+-- Migration: Tool policies
+-- Tool policies allow, deny or hold namespace tool calls for approval by
+-- tool, server, argument patterns and caller, before the call reaches the
+-- upstream server.
+
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_type_check;
+ALTER TABLE policies ADD CONSTRAINT policies_type_check
+    CHECK (type IN ('access', 'rate_limit', 'security', 'tool'));
@@ -0,0 +1,22 @@
+-- Migration: Gateway node registry
+-- Each API instance heartbeats its version, sessions and health here so
+-- admins can see the fleet. draining is set by admins and read back by the
+-- node on its next heartbeat; a draining node fails readiness checks and
+-- takes no new sessions while its existing ones finish.
+CREATE TABLE gateway_nodes (
+    id VARCHAR(100) PRIMARY KEY,
+    hostname VARCHAR(255) NOT NULL DEFAULT '',
+    address VARCHAR(255) NOT NULL DEFAULT '',
+    version VARCHAR(100) NOT NULL DEFAULT '',
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    sessions JSONB NOT NULL DEFAULT '{}',
+    health_status VARCHAR(20) NOT NULL DEFAULT '',
+    health_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    draining BOOLEAN NOT NULL DEFAULT false,
+    drain_requested_by VARCHAR(255) NOT NULL DEFAULT '',
+    drain_requested_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_gateway_nodes_heartbeat ON gateway_nodes(last_heartbeat_at);
@@ -0,0 +1,26 @@
+-- Migration: Human-in-the-loop approval of tool calls
+-- A tool call a require_approval policy holds waits here until an approver
+-- approves or rejects it, or it expires. The waiting instance polls the row,
+-- so any instance can take the decision.
+CREATE TABLE tool_approvals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    namespace_id UUID NOT NULL,
+    server_id VARCHAR(255) NOT NULL DEFAULT '',
+    tool VARCHAR(255) NOT NULL,
+    arguments JSONB NOT NULL DEFAULT '{}',
+    policy_id VARCHAR(255) NOT NULL DEFAULT '',
+    policy_name VARCHAR(255) NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    requested_by VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'expired', 'cancelled')),
+    decided_by VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    decided_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_tool_approvals_org_status ON tool_approvals(organization_id, status, created_at DESC);
+CREATE INDEX idx_tool_approvals_pending_expiry ON tool_approvals(expires_at) WHERE status = 'pending';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS header_propagation_rules;
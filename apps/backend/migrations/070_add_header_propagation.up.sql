@@ -0,0 +1,20 @@
+-- Migration: Propagation of caller headers to upstream servers
+-- Rules decide which inbound headers a server or every server of a
+-- namespace receives, which are stripped and which are derived from the
+-- caller's identity. Scopes without a row receive no caller headers.
+CREATE TABLE header_propagation_rules (
+    scope_type VARCHAR(16) NOT NULL CHECK (scope_type IN ('server', 'namespace')),
+    scope_id UUID NOT NULL,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    rules JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (scope_type, scope_id)
+);
+
+CREATE INDEX idx_header_propagation_rules_org ON header_propagation_rules(organization_id);
+
+CREATE TRIGGER header_propagation_rules_updated_at
+    BEFORE UPDATE ON header_propagation_rules
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
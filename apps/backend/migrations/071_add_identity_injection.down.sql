@@ -0,0 +1,2 @@
+-- Rollback: Remove caller identity injection
+DROP TABLE IF EXISTS server_identity_injection;
@@ -0,0 +1,15 @@
+-- Migration: Caller identity asserted to upstream servers
+-- Servers with a row receive the selected identity fields of the caller on
+-- every tool call, in _meta or in a tool argument.
+CREATE TABLE server_identity_injection (
+    server_id UUID PRIMARY KEY REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    target VARCHAR(16) NOT NULL CHECK (target IN ('meta', 'argument')),
+    argument VARCHAR(255),
+    fields JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER server_identity_injection_updated_at
+    BEFORE UPDATE ON server_identity_injection
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
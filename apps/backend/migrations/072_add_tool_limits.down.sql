@@ -0,0 +1,3 @@
+-- Rollback: Remove per-tool rate limits and daily budgets
+DROP TABLE IF EXISTS tool_budget_usage;
+DROP TABLE IF EXISTS tool_limits;
@@ -0,0 +1,25 @@
+-- Migration: Per-tool rate limits and daily budgets
+-- Tools with a row cap the calls their organization makes to them. Rate
+-- limits are counted by the gateway's rate limiter; daily budgets are
+-- counted here so that every instance enforces the same budget.
+CREATE TABLE tool_limits (
+    tool_id UUID PRIMARY KEY REFERENCES mcp_tools(id) ON DELETE CASCADE,
+    rate_limit BIGINT NOT NULL DEFAULT 0 CHECK (rate_limit >= 0),
+    rate_window_seconds INTEGER NOT NULL DEFAULT 60 CHECK (rate_window_seconds > 0),
+    daily_budget BIGINT NOT NULL DEFAULT 0 CHECK (daily_budget >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Calls each budgeted tool admitted per UTC day
+CREATE TABLE tool_budget_usage (
+    tool_id UUID NOT NULL REFERENCES mcp_tools(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    calls BIGINT NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (tool_id, day)
+);
+
+CREATE TRIGGER tool_limits_updated_at
+    BEFORE UPDATE ON tool_limits
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,4 @@
+-- Rollback: Remove metered usage and monthly budgets
+DROP TABLE IF EXISTS usage_budget_alerts;
+DROP TABLE IF EXISTS usage_budgets;
+DROP TABLE IF EXISTS usage_meters;
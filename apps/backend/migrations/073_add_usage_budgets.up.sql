@@ -0,0 +1,41 @@
+-- Migration: Metered usage and monthly budgets
+-- Usage of each metered dimension per organization and calendar month,
+-- added to by every gateway instance
+CREATE TABLE usage_meters (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    dimension VARCHAR(32) NOT NULL,
+    period DATE NOT NULL,
+    quantity BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (organization_id, dimension, period)
+);
+
+-- Monthly budgets. Admins are notified at 50, 80 and 100 percent; budgets
+-- with hard_stop refuse tool calls outside critical namespaces at 100.
+CREATE TABLE usage_budgets (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    dimension VARCHAR(32) NOT NULL CHECK (dimension IN ('executions', 'tokens', 'egress_bytes')),
+    monthly_limit BIGINT NOT NULL CHECK (monthly_limit > 0),
+    hard_stop BOOLEAN NOT NULL DEFAULT false,
+    critical_namespaces UUID[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (organization_id, dimension)
+);
+
+-- Thresholds admins were notified of, so each is sent once a month
+CREATE TABLE usage_budget_alerts (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    dimension VARCHAR(32) NOT NULL,
+    period DATE NOT NULL,
+    threshold INTEGER NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (organization_id, dimension, period, threshold)
+);
+
+CREATE TRIGGER usage_budgets_updated_at
+    BEFORE UPDATE ON usage_budgets
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
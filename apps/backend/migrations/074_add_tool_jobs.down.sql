@@ -0,0 +1,3 @@
+-- Rollback: Remove the async tool job queue
+DROP TABLE IF EXISTS tool_job_settings;
+DROP TABLE IF EXISTS tool_jobs;
@@ -0,0 +1,55 @@
+-- Migration: Async job queue for long-running tool executions
+-- Namespace tool calls can be queued as jobs that worker consumers claim,
+-- run and retry, so callers poll for results instead of holding a request
+-- open. Running jobs are leased until locked_until; a job whose worker
+-- died is claimed again once its lease runs out.
+CREATE TABLE tool_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    namespace_id UUID NOT NULL REFERENCES namespaces(id) ON DELETE CASCADE,
+    tool VARCHAR(255) NOT NULL,
+    arguments JSONB NOT NULL DEFAULT '{}',
+    idempotency_key VARCHAR(255) NOT NULL,
+    -- The caller the job runs as, with the scope of scoped API keys
+    principal JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued'
+        CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'cancelled')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL CHECK (max_attempts > 0),
+    timeout_seconds INTEGER NOT NULL CHECK (timeout_seconds > 0),
+    retry_backoff_seconds INTEGER NOT NULL CHECK (retry_backoff_seconds >= 0),
+    result JSONB,
+    error JSONB,
+    run_after TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_by VARCHAR(255),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_tool_jobs_claimable ON tool_jobs(run_after)
+    WHERE status IN ('queued', 'running');
+CREATE INDEX idx_tool_jobs_organization ON tool_jobs(organization_id, created_at DESC);
+CREATE INDEX idx_tool_jobs_finished ON tool_jobs(finished_at)
+    WHERE finished_at IS NOT NULL;
+
+-- Per-tool attempts, timeout and retry backoff of queued executions
+CREATE TABLE tool_job_settings (
+    tool_id UUID PRIMARY KEY REFERENCES mcp_tools(id) ON DELETE CASCADE,
+    max_attempts INTEGER NOT NULL CHECK (max_attempts > 0),
+    timeout_seconds INTEGER NOT NULL CHECK (timeout_seconds > 0),
+    retry_backoff_seconds INTEGER NOT NULL CHECK (retry_backoff_seconds >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER tool_jobs_updated_at
+    BEFORE UPDATE ON tool_jobs
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TRIGGER tool_job_settings_updated_at
+    BEFORE UPDATE ON tool_job_settings
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
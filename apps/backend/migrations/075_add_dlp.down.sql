@@ -0,0 +1,3 @@
+-- Rollback: Remove outbound data loss prevention
+DROP TABLE IF EXISTS dlp_events;
+DROP TABLE IF EXISTS server_dlp_policies;
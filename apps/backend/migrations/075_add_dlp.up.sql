@@ -0,0 +1,31 @@
+-- Migration: Outbound data loss prevention
+-- Servers with a policy are labelled with the most sensitive data their tool
+-- calls may carry; calls carrying more are blocked, redacted or logged.
+CREATE TABLE server_dlp_policies (
+    server_id UUID PRIMARY KEY REFERENCES mcp_servers(id) ON DELETE CASCADE,
+    sensitivity VARCHAR(16) NOT NULL CHECK (sensitivity IN ('public', 'internal', 'confidential', 'restricted')),
+    action VARCHAR(16) NOT NULL CHECK (action IN ('block', 'redact', 'log')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER server_dlp_policies_updated_at
+    BEFORE UPDATE ON server_dlp_policies
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Tool calls that carried data above their server's label. Findings name
+-- the argument paths and classifiers, never the data.
+CREATE TABLE dlp_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    namespace_id UUID NOT NULL,
+    server_id UUID NOT NULL,
+    tool VARCHAR(255) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    findings JSONB NOT NULL DEFAULT '[]',
+    user_id UUID,
+    api_key_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_dlp_events_organization ON dlp_events(organization_id, created_at DESC);
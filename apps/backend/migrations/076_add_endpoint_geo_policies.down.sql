@@ -0,0 +1,3 @@
+-- Rollback: Remove geo access policies for endpoints
+DROP TABLE IF EXISTS endpoint_geo_decisions;
+DROP TABLE IF EXISTS endpoint_geo_policies;
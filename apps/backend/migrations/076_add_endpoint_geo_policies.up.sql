@@ -0,0 +1,37 @@
+-- Migration: Geo access policies for endpoints
+-- Calls to an endpoint with a policy are allowed or denied by the country
+-- and autonomous system of the client's IP address.
+CREATE TABLE endpoint_geo_policies (
+    endpoint_id UUID PRIMARY KEY REFERENCES endpoints(id) ON DELETE CASCADE,
+    allow_countries TEXT[] NOT NULL DEFAULT '{}',
+    deny_countries TEXT[] NOT NULL DEFAULT '{}',
+    allow_asns BIGINT[] NOT NULL DEFAULT '{}',
+    deny_asns BIGINT[] NOT NULL DEFAULT '{}',
+    unknown_action VARCHAR(16) NOT NULL DEFAULT 'allow' CHECK (unknown_action IN ('allow', 'deny')),
+    log_allowed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER endpoint_geo_policies_updated_at
+    BEFORE UPDATE ON endpoint_geo_policies
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Decisions of geo policies: every denied call, and allowed calls of
+-- policies that log them
+CREATE TABLE endpoint_geo_decisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    endpoint_id UUID NOT NULL REFERENCES endpoints(id) ON DELETE CASCADE,
+    ip INET NOT NULL,
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    asn BIGINT NOT NULL DEFAULT 0,
+    as_organization VARCHAR(255) NOT NULL DEFAULT '',
+    allowed BOOLEAN NOT NULL,
+    rule VARCHAR(32) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_endpoint_geo_decisions_endpoint ON endpoint_geo_decisions(endpoint_id, created_at DESC);
+CREATE INDEX idx_endpoint_geo_decisions_organization ON endpoint_geo_decisions(organization_id, created_at DESC);
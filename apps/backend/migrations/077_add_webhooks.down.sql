@@ -0,0 +1,3 @@
+-- Rollback: Remove webhooks for gateway events
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
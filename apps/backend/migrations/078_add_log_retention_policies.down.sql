@@ -0,0 +1,3 @@
+-- Rollback: Remove per-log-type retention and exports before purge
+DROP TABLE IF EXISTS log_retention_exports;
+DROP TABLE IF EXISTS log_retention_policies;
@@ -0,0 +1,43 @@
+-- Migration: Per-log-type retention and exports before purge
+
+-- An organization's retention per type of log. A NULL retention falls back
+-- to organizations.log_retention_days. With export enabled, the worker
+-- uploads the rows it is about to purge to the organization's S3 bucket
+-- and keeps them when the upload fails.
+CREATE TABLE log_retention_policies (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    request_log_days INTEGER CHECK (request_log_days > 0),
+    audit_log_days INTEGER CHECK (audit_log_days > 0),
+    health_check_days INTEGER CHECK (health_check_days > 0),
+    export_enabled BOOLEAN NOT NULL DEFAULT false,
+    export_endpoint TEXT NOT NULL DEFAULT '',
+    export_region VARCHAR(64) NOT NULL DEFAULT '',
+    export_bucket VARCHAR(255) NOT NULL DEFAULT '',
+    export_prefix TEXT NOT NULL DEFAULT '',
+    export_access_key_id VARCHAR(255) NOT NULL DEFAULT '',
+    export_secret_access_key TEXT NOT NULL DEFAULT '',
+    export_use_path_style BOOLEAN NOT NULL DEFAULT false,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER log_retention_policies_updated_at
+    BEFORE UPDATE ON log_retention_policies
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- One upload of the rows a retention run purged from a table
+CREATE TABLE log_retention_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    log_table VARCHAR(30) NOT NULL CHECK (log_table IN ('log_index', 'audit_logs', 'health_checks')),
+    cutoff TIMESTAMP WITH TIME ZONE NOT NULL,
+    bucket VARCHAR(255) NOT NULL,
+    storage_key TEXT NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_log_retention_exports_organization ON log_retention_exports(organization_id, created_at DESC);
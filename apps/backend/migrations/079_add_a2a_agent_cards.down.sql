@@ -0,0 +1,2 @@
+-- Rollback: Remove A2A agent cards
+DROP TABLE IF EXISTS a2a_agent_cards;
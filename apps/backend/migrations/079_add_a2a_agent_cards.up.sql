@@ -0,0 +1,16 @@
+-- Migration: A2A agent cards
+-- The latest check of the agent card each A2A agent publishes at
+-- /.well-known/agent.json. A failed fetch keeps the last card fetched.
+CREATE TABLE a2a_agent_cards (
+    agent_id UUID PRIMARY KEY REFERENCES a2a_agents(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('valid', 'invalid', 'unavailable')),
+    card JSONB,
+    error TEXT,
+    problems TEXT[] NOT NULL DEFAULT '{}',
+    warnings TEXT[] NOT NULL DEFAULT '{}',
+    fetched_at TIMESTAMP WITH TIME ZONE,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_a2a_agent_cards_checked ON a2a_agent_cards(checked_at);
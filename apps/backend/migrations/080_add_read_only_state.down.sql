@@ -0,0 +1,2 @@
+-- Rollback: Remove shared read-only mode
+DROP TABLE IF EXISTS read_only_state;
@@ -0,0 +1,12 @@
+-- Migration: Shared read-only mode
+
+-- Read-only mode as last set through the API, so every instance follows it
+-- and restarted instances come back in it. The row is absent until it is
+-- first set, and the configured mode applies.
+CREATE TABLE read_only_state (
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    enabled BOOLEAN NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_by VARCHAR(255) NOT NULL DEFAULT '',
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,13 @@
+-- Rollback: Attribute service account requests to their credentials again.
+-- Fails while an organization with such entries is under legal hold, as
+-- held entries cannot be rewritten.
+UPDATE log_index SET principal_type = CASE
+    WHEN api_key_id IS NOT NULL THEN 'api_key'
+    WHEN oauth_client_id IS NOT NULL THEN 'oauth_client'
+    ELSE 'anonymous'
+END
+WHERE principal_type = 'service_account';
+
+ALTER TABLE log_index DROP CONSTRAINT IF EXISTS log_index_principal_type_check;
+ALTER TABLE log_index ADD CONSTRAINT log_index_principal_type_check
+    CHECK (principal_type IN ('user', 'api_key', 'oauth_client', 'anonymous'));
@@ -0,0 +1,11 @@
+-- Migration: Attribute log entries to service accounts
+
+-- Requests made with a service account's API keys or OAuth clients are
+-- attributed to the account itself. The check added with principal_type
+-- was copied onto the partitioned log_index, and is still local to the
+-- legacy partition, so it is dropped there too before being re-created on
+-- the parent and with it on every partition.
+ALTER TABLE log_index DROP CONSTRAINT IF EXISTS log_index_principal_type_check;
+ALTER TABLE IF EXISTS log_index_legacy DROP CONSTRAINT IF EXISTS log_index_principal_type_check;
+ALTER TABLE log_index ADD CONSTRAINT log_index_principal_type_check
+    CHECK (principal_type IN ('user', 'api_key', 'oauth_client', 'anonymous', 'service_account'));
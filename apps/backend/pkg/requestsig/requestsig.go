@@ -0,0 +1,193 @@
+// Package requestsig signs the requests the gateway sends to webhook
+// receivers and A2A agents, and lets receivers verify them.
+//
+// Each request carries one header:
+//
+//	X-Omnimesh-Request-Signature: kid=<key id>,alg=<algorithm>,t=<unix seconds>,sig=<signature>
+//
+// The signature covers "<t>.<body>" and is base64url encoded without
+// padding. hmac-sha256 keys are shared secrets; ed25519 keys are key pairs
+// whose public half the gateway hands out. Receivers look the key up by its
+// ID, which lets them accept old and new keys while a key is rotated.
+package requestsig
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header carries the signature of a request
+const Header = "X-Omnimesh-Request-Signature"
+
+// Signature algorithms
+const (
+	AlgHMACSHA256 = "hmac-sha256"
+	AlgEd25519    = "ed25519"
+)
+
+// DefaultTolerance is how far a request's timestamp may be from the
+// receiver's clock before Verify refuses it as a possible replay
+const DefaultTolerance = 5 * time.Minute
+
+// Errors returned by Verify
+var (
+	ErrMissingSignature = errors.New("requestsig: missing signature")
+	ErrMalformed        = errors.New("requestsig: malformed signature header")
+	ErrUnknownKey       = errors.New("requestsig: unknown key")
+	ErrExpired          = errors.New("requestsig: timestamp outside tolerance")
+	ErrInvalidSignature = errors.New("requestsig: invalid signature")
+)
+
+// Key is a signing or verification key. Secret is set for hmac-sha256;
+// PrivateKey for signing and PublicKey for verifying with ed25519.
+type Key struct {
+	ID         string
+	Algorithm  string
+	Secret     []byte
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+// NewHMACKey returns the hmac-sha256 key for a secret shared with a
+// receiver. Its ID is ownerID and a fingerprint of the secret, so it
+// changes when the secret is rotated.
+func NewHMACKey(ownerID, secret string) *Key {
+	sum := sha256.Sum256([]byte(secret))
+	return &Key{
+		ID:        ownerID + "." + hex.EncodeToString(sum[:4]),
+		Algorithm: AlgHMACSHA256,
+		Secret:    []byte(secret),
+	}
+}
+
+// Sign sets the signature header of req over body, timestamped now
+func (k *Key) Sign(req *http.Request, body []byte, now time.Time) error {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	message := signedMessage(timestamp, body)
+
+	var sig []byte
+	switch k.Algorithm {
+	case AlgHMACSHA256:
+		if len(k.Secret) == 0 {
+			return fmt.Errorf("requestsig: key %s has no secret", k.ID)
+		}
+		mac := hmac.New(sha256.New, k.Secret)
+		mac.Write(message)
+		sig = mac.Sum(nil)
+	case AlgEd25519:
+		if len(k.PrivateKey) != ed25519.PrivateKeySize {
+			return fmt.Errorf("requestsig: key %s has no private key", k.ID)
+		}
+		sig = ed25519.Sign(k.PrivateKey, message)
+	default:
+		return fmt.Errorf("requestsig: unsupported algorithm %q", k.Algorithm)
+	}
+
+	req.Header.Set(Header, fmt.Sprintf("kid=%s,alg=%s,t=%s,sig=%s",
+		k.ID, k.Algorithm, timestamp, base64.RawURLEncoding.EncodeToString(sig)))
+	return nil
+}
+
+// ParsePublicKey decodes an ed25519 public key as the gateway hands it
+// out, base64url encoded without padding
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("requestsig: invalid ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// KeyLookup returns the verification key with the given ID, or nil when
+// the receiver does not know it
+type KeyLookup func(keyID string) (*Key, error)
+
+// Verify checks a signature header against body. It returns the ID of the
+// key that signed it.
+func Verify(header string, body []byte, lookup KeyLookup, tolerance time.Duration, now time.Time) (string, error) {
+	if strings.TrimSpace(header) == "" {
+		return "", ErrMissingSignature
+	}
+	fields := map[string]string{}
+	for _, part := range strings.Split(header, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return "", ErrMalformed
+		}
+		fields[name] = value
+	}
+	keyID, timestamp, encoded := fields["kid"], fields["t"], fields["sig"]
+	if keyID == "" || timestamp == "" || encoded == "" {
+		return "", ErrMalformed
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > tolerance || skew < -tolerance {
+		return "", ErrExpired
+	}
+
+	key, err := lookup(keyID)
+	if err != nil {
+		return "", err
+	}
+	if key == nil || (fields["alg"] != "" && fields["alg"] != key.Algorithm) {
+		return "", ErrUnknownKey
+	}
+
+	message := signedMessage(timestamp, body)
+	switch key.Algorithm {
+	case AlgHMACSHA256:
+		mac := hmac.New(sha256.New, key.Secret)
+		mac.Write(message)
+		if len(key.Secret) == 0 || !hmac.Equal(sig, mac.Sum(nil)) {
+			return "", ErrInvalidSignature
+		}
+	case AlgEd25519:
+		if len(key.PublicKey) != ed25519.PublicKeySize || !ed25519.Verify(key.PublicKey, message, sig) {
+			return "", ErrInvalidSignature
+		}
+	default:
+		return "", ErrUnknownKey
+	}
+	return keyID, nil
+}
+
+// VerifyRequest verifies a received request with DefaultTolerance and
+// returns its body, which it leaves readable again on r
+func VerifyRequest(r *http.Request, lookup KeyLookup) ([]byte, string, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	keyID, err := Verify(r.Header.Get(Header), body, lookup, DefaultTolerance, time.Now())
+	return body, keyID, err
+}
+
+func signedMessage(timestamp string, body []byte) []byte {
+	message := make([]byte, 0, len(timestamp)+1+len(body))
+	message = append(message, timestamp...)
+	message = append(message, '.')
+	return append(message, body...)
+}
@@ -0,0 +1,281 @@
+package contract
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const fixtureGrantID = "f6e5d4c3-b2a1-4f0e-9d8c-7b6a5f4e3d40"
+
+func fixtureNamespaceGrant() *types.NamespaceGrant {
+	return &types.NamespaceGrant{
+		CreatedAt:      fixtureTime,
+		ExpiresAt:      &fixtureLaterTime,
+		ID:             fixtureGrantID,
+		OrganizationID: fixtureOrgID,
+		NamespaceID:    fixtureNamespaceID,
+		SubjectType:    types.NamespaceGrantSubjectTeam,
+		SubjectID:      fixtureTeamID,
+		AccessLevel:    types.NamespaceAccessExecute,
+		CreatedBy:      fixtureUserID,
+	}
+}
+
+// stubNamespaceGrants grants the fixture team execute access to the
+// fixture namespace
+type stubNamespaceGrants struct{}
+
+func (stubNamespaceGrants) ListGrants(ctx context.Context, orgID, namespaceID string) ([]*types.NamespaceGrant, error) {
+	if namespaceID == missingID {
+		return nil, notFound("Namespace")
+	}
+	return []*types.NamespaceGrant{fixtureNamespaceGrant()}, nil
+}
+
+func (stubNamespaceGrants) Grant(ctx context.Context, orgID, namespaceID, createdBy string, req *types.CreateNamespaceGrantRequest) (*types.NamespaceGrant, error) {
+	if req.SubjectType == types.NamespaceGrantSubjectTeam && req.SubjectID == fixtureTeamID {
+		return nil, types.NewConflictError("the team already has a grant on the namespace")
+	}
+	grant := fixtureNamespaceGrant()
+	grant.SubjectType = req.SubjectType
+	grant.SubjectID = req.SubjectID
+	grant.AccessLevel = req.AccessLevel
+	grant.ExpiresAt = req.ExpiresAt
+	return grant, nil
+}
+
+func (stubNamespaceGrants) Revoke(ctx context.Context, orgID, namespaceID, grantID string) error {
+	if grantID != fixtureGrantID {
+		return notFound("Grant")
+	}
+	return nil
+}
+
+func TestNamespaceGrantContracts(t *testing.T) {
+	h := handlers.NewNamespaceGrantHandler(stubNamespaceGrants{})
+	r := newContractRouter()
+	namespaces := r.Group("/api/namespaces")
+	namespaces.GET("/:id/grants", h.ListGrants)
+	namespaces.POST("/:id/grants", h.CreateGrant)
+	namespaces.DELETE("/:id/grants/:grant_id", h.DeleteGrant)
+
+	base := "/api/namespaces/" + fixtureNamespaceID + "/grants"
+	r.run(t, "namespace-grants", []contractCase{
+		{name: "list", method: http.MethodGet, path: base},
+		{name: "list-not-found", method: http.MethodGet, path: "/api/namespaces/" + missingID + "/grants"},
+		{name: "create", method: http.MethodPost, path: base,
+			body: map[string]interface{}{"subject_type": "user", "subject_id": fixtureUserID, "access_level": "read"}},
+		{name: "create-invalid", method: http.MethodPost, path: base,
+			body: map[string]interface{}{"subject_type": "group", "subject_id": "support", "access_level": "owner"}},
+		{name: "create-conflict", method: http.MethodPost, path: base,
+			body: map[string]interface{}{"subject_type": "team", "subject_id": fixtureTeamID, "access_level": "write"}},
+		{name: "delete", method: http.MethodDelete, path: base + "/" + fixtureGrantID},
+		{name: "delete-not-found", method: http.MethodDelete, path: base + "/" + missingID},
+	})
+}
+
+func fixtureResourceOwner() *types.ResourceOwner {
+	return &types.ResourceOwner{
+		AssignedAt:     fixtureTime,
+		ResourceType:   "server",
+		ResourceID:     fixtureServerID,
+		ResourceName:   "ticketing",
+		OrganizationID: fixtureOrgID,
+		OwnerType:      "team",
+		OwnerID:        fixtureTeamID,
+		OwnerName:      "Support",
+		Notes:          "Escalate to #support-tools",
+		AssignedBy:     fixtureUserID,
+	}
+}
+
+// stubResourceOwners has the fixture team own the fixture server, and the
+// second server orphaned by a deactivated user
+type stubResourceOwners struct{}
+
+func (stubResourceOwners) GetOwner(ctx context.Context, orgID, resourceType, resourceID string) (*types.ResourceOwner, error) {
+	if resourceID == missingID {
+		return nil, notFound("Resource")
+	}
+	return fixtureResourceOwner(), nil
+}
+
+func (stubResourceOwners) SetOwner(ctx context.Context, orgID, resourceType, resourceID, assignedBy string, req *types.SetResourceOwnerRequest) (*types.ResourceOwner, error) {
+	if resourceType != "server" && resourceType != "tool" && resourceType != "namespace" && resourceType != "endpoint" {
+		return nil, types.NewValidationError("resource type must be server, tool, namespace or endpoint")
+	}
+	owner := fixtureResourceOwner()
+	owner.OwnerType = req.OwnerType
+	owner.OwnerID = req.OwnerID
+	owner.OwnerName = "Alex Doe"
+	owner.Notes = req.Notes
+	owner.AssignedAt = fixtureLaterTime
+	owner.AssignedBy = assignedBy
+	return owner, nil
+}
+
+func (stubResourceOwners) RemoveOwner(ctx context.Context, orgID, resourceType, resourceID string) error {
+	if resourceID == missingID {
+		return notFound("Resource owner")
+	}
+	return nil
+}
+
+func (stubResourceOwners) ListOwners(ctx context.Context, orgID string, filter *types.ResourceOwnerFilter) ([]*types.ResourceOwner, error) {
+	orphan := fixtureResourceOwner()
+	orphan.ResourceID = fixtureServer2ID
+	orphan.ResourceName = "billing"
+	orphan.OwnerType = "user"
+	orphan.OwnerID = fixtureUserID
+	orphan.OwnerName = "Former Employee"
+	orphan.Notes = ""
+	orphan.Orphaned = true
+	orphan.OrphanReason = "owner is deactivated"
+	if filter.OrphanedOnly {
+		return []*types.ResourceOwner{orphan}, nil
+	}
+	return []*types.ResourceOwner{fixtureResourceOwner(), orphan}, nil
+}
+
+func (stubResourceOwners) Transfer(ctx context.Context, orgID, assignedBy string, req *types.TransferOwnershipRequest) (*types.TransferOwnershipResult, error) {
+	if len(req.ResourceIDs) > 0 {
+		return &types.TransferOwnershipResult{Transferred: int64(len(req.ResourceIDs))}, nil
+	}
+	return &types.TransferOwnershipResult{Transferred: 4}, nil
+}
+
+func TestResourceOwnerContracts(t *testing.T) {
+	h := handlers.NewResourceOwnerHandler(stubResourceOwners{})
+	r := newContractRouter()
+	ownership := r.Group("/api/ownership")
+	ownership.GET("", h.ListOwners)
+	ownership.GET("/orphaned", h.ListOrphaned)
+	ownership.POST("/transfer", h.Transfer)
+	ownership.GET("/:resource_type/:resource_id", h.GetOwner)
+	ownership.PUT("/:resource_type/:resource_id", h.SetOwner)
+	ownership.DELETE("/:resource_type/:resource_id", h.RemoveOwner)
+
+	server := "/api/ownership/server/" + fixtureServerID
+	r.run(t, "ownership", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/ownership", query: "resource_type=server"},
+		{name: "list-invalid", method: http.MethodGet, path: "/api/ownership", query: "owner_type=group"},
+		{name: "orphaned", method: http.MethodGet, path: "/api/ownership/orphaned"},
+		{name: "transfer", method: http.MethodPost, path: "/api/ownership/transfer",
+			body: map[string]interface{}{"from_type": "user", "from_id": fixtureUserID, "to_type": "team", "to_id": fixtureTeamID}},
+		{name: "transfer-invalid", method: http.MethodPost, path: "/api/ownership/transfer",
+			body: map[string]interface{}{"from_type": "user", "from_id": "alex", "to_type": "team"}},
+		{name: "get", method: http.MethodGet, path: server},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/ownership/server/" + missingID},
+		{name: "set", method: http.MethodPut, path: server,
+			body: map[string]interface{}{"owner_type": "user", "owner_id": fixtureUserID, "notes": "Primary maintainer"}},
+		{name: "set-invalid", method: http.MethodPut, path: server, body: map[string]interface{}{"owner_type": "user"}},
+		{name: "set-unknown-type", method: http.MethodPut, path: "/api/ownership/prompt/" + fixtureServerID,
+			body: map[string]interface{}{"owner_type": "team", "owner_id": fixtureTeamID}},
+		{name: "remove", method: http.MethodDelete, path: server},
+		{name: "remove-not-found", method: http.MethodDelete, path: "/api/ownership/server/" + missingID},
+	})
+}
+
+func fixtureGeoPolicy() *types.EndpointGeoPolicy {
+	return &types.EndpointGeoPolicy{
+		CreatedAt:      &fixtureTime,
+		UpdatedAt:      &fixtureLaterTime,
+		EndpointID:     fixtureEndpointID,
+		AllowCountries: []string{"DE", "FR", "NL"},
+		DenyCountries:  []string{},
+		AllowASNs:      []int64{},
+		DenyASNs:       []int64{14061},
+		UnknownAction:  "deny",
+	}
+}
+
+// stubGeoPolicies allows the fixture endpoint from three EU countries
+type stubGeoPolicies struct{}
+
+func (stubGeoPolicies) GetPolicy(ctx context.Context, orgID, endpointID string) (*types.EndpointGeoPolicy, error) {
+	if endpointID == missingID {
+		return nil, notFound("Endpoint")
+	}
+	return fixtureGeoPolicy(), nil
+}
+
+func (stubGeoPolicies) SetPolicy(ctx context.Context, orgID, endpointID string, req *types.SetEndpointGeoPolicyRequest) (*types.EndpointGeoPolicy, error) {
+	for _, country := range req.AllowCountries {
+		if len(country) != 2 {
+			return nil, types.NewValidationError("invalid country code " + country)
+		}
+	}
+	policy := fixtureGeoPolicy()
+	policy.AllowCountries = req.AllowCountries
+	policy.UnknownAction = req.UnknownAction
+	policy.LogAllowed = req.LogAllowed
+	return policy, nil
+}
+
+func (stubGeoPolicies) DeletePolicy(ctx context.Context, orgID, endpointID string) error {
+	if endpointID == missingID {
+		return notFound("Endpoint")
+	}
+	return nil
+}
+
+func (stubGeoPolicies) TestPolicy(ctx context.Context, orgID, endpointID string, req *types.TestGeoPolicyRequest) (*types.GeoDecision, error) {
+	if req.IP == "203.0.113.9" {
+		return &types.GeoDecision{
+			GeoIPInfo: types.GeoIPInfo{IP: req.IP, Country: "US", ASN: 14061, ASOrganization: "DigitalOcean"},
+			Rule:      "allow_countries",
+			Reason:    "US is not an allowed country",
+		}, nil
+	}
+	return &types.GeoDecision{
+		GeoIPInfo: types.GeoIPInfo{IP: req.IP, Country: "DE", ASN: 3320, ASOrganization: "Deutsche Telekom AG"},
+		Rule:      "allow_countries",
+		Reason:    "DE is an allowed country",
+		Allowed:   true,
+	}, nil
+}
+
+func (stubGeoPolicies) ListDecisions(ctx context.Context, orgID, endpointID string, deniedOnly bool, limit int) ([]*types.GeoDecisionEvent, error) {
+	return []*types.GeoDecisionEvent{{
+		CreatedAt:      fixtureLaterTime,
+		ID:             "c8b7a6f5-e4d3-4c2b-9a1f-0e9d8c7b6a51",
+		OrganizationID: orgID,
+		EndpointID:     endpointID,
+		GeoDecision: types.GeoDecision{
+			GeoIPInfo: types.GeoIPInfo{IP: "203.0.113.9", Country: "US", ASN: 14061, ASOrganization: "DigitalOcean"},
+			Rule:      "allow_countries",
+			Reason:    "US is not an allowed country",
+		},
+	}}, nil
+}
+
+func TestEndpointGeoPolicyContracts(t *testing.T) {
+	h := handlers.NewEndpointGeoPolicyHandler(stubGeoPolicies{})
+	r := newContractRouter()
+	endpoints := r.Group("/api/endpoints")
+	endpoints.GET("/:id/geo-policy", h.GetPolicy)
+	endpoints.PUT("/:id/geo-policy", h.SetPolicy)
+	endpoints.DELETE("/:id/geo-policy", h.DeletePolicy)
+	endpoints.POST("/:id/geo-policy/test", h.TestPolicy)
+	endpoints.GET("/:id/geo-decisions", h.ListDecisions)
+
+	base := "/api/endpoints/" + fixtureEndpointID
+	r.run(t, "geo-policy", []contractCase{
+		{name: "get", method: http.MethodGet, path: base + "/geo-policy"},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/endpoints/" + missingID + "/geo-policy"},
+		{name: "set", method: http.MethodPut, path: base + "/geo-policy",
+			body: map[string]interface{}{"allow_countries": []string{"DE", "AT"}, "unknown_action": "allow", "log_allowed": true}},
+		{name: "set-invalid", method: http.MethodPut, path: base + "/geo-policy", body: map[string]interface{}{"unknown_action": "block"}},
+		{name: "set-rejected", method: http.MethodPut, path: base + "/geo-policy", body: map[string]interface{}{"allow_countries": []string{"Germany"}}},
+		{name: "delete", method: http.MethodDelete, path: base + "/geo-policy"},
+		{name: "test-allowed", method: http.MethodPost, path: base + "/geo-policy/test", body: map[string]interface{}{"ip": "198.51.100.4"}},
+		{name: "test-denied", method: http.MethodPost, path: base + "/geo-policy/test", body: map[string]interface{}{"ip": "203.0.113.9"}},
+		{name: "test-invalid", method: http.MethodPost, path: base + "/geo-policy/test", body: map[string]interface{}{}},
+		{name: "decisions", method: http.MethodGet, path: base + "/geo-decisions", query: "denied=true&limit=50"},
+		{name: "decisions-invalid", method: http.MethodGet, path: base + "/geo-decisions", query: "limit=all"},
+	})
+}
@@ -0,0 +1,639 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	fixtureSinkID       = "8f7e6d5c-4b3a-4291-8f7e-6d5c4b3a29b9"
+	fixtureTeamID       = "5c4b3a29-1807-4f6e-9d5c-4b3a2918c0ca"
+	fixtureMemberUserID = "2918f7e6-d5c4-4b3a-8291-8f7e6d5c4bdb"
+	fixtureHoldID       = "a2918f7e-6d5c-4b3a-9291-8f7e6d5c4bec"
+)
+
+func fixtureAuditSink() *types.AuditSink {
+	return &types.AuditSink{
+		CreatedAt:       fixtureTime,
+		UpdatedAt:       fixtureLaterTime,
+		LastDeliveredAt: &fixtureLaterTime,
+		Config: types.AuditSinkConfig{
+			URL:     "https://siem.example.com/ingest",
+			Headers: map[string]string{"X-Source": "omnimesh"},
+		},
+		ID:             fixtureSinkID,
+		OrganizationID: fixtureOrgID,
+		Name:           "splunk",
+		Type:           types.AuditSinkTypeWebhook,
+		Format:         types.AuditSinkFormatJSON,
+		MinSeverity:    types.AuditSeverityInfo,
+		Secret:         "never-serialized",
+		CreatedBy:      fixtureUserID,
+		CursorSequence: 1200,
+		Lag:            14,
+		BatchSize:      100,
+		Enabled:        true,
+		HasSecret:      true,
+	}
+}
+
+// stubAuditSinks serves the fixture audit sink
+type stubAuditSinks struct{}
+
+func (stubAuditSinks) ListSinks(ctx context.Context, orgID string) ([]*types.AuditSink, error) {
+	return []*types.AuditSink{fixtureAuditSink()}, nil
+}
+
+func (stubAuditSinks) GetSink(ctx context.Context, orgID, id string) (*types.AuditSink, error) {
+	if id == missingID {
+		return nil, notFound("Audit sink")
+	}
+	return fixtureAuditSink(), nil
+}
+
+func (stubAuditSinks) CreateSink(ctx context.Context, orgID, userID string, req *types.CreateAuditSinkRequest) (*types.AuditSink, error) {
+	if req.Type == types.AuditSinkTypeKafka && req.Config.Topic == "" {
+		return nil, types.NewValidationError("kafka sinks need a topic")
+	}
+	sink := fixtureAuditSink()
+	sink.Name = req.Name
+	sink.LastDeliveredAt = nil
+	sink.CursorSequence = 0
+	return sink, nil
+}
+
+func (stubAuditSinks) UpdateSink(ctx context.Context, orgID, id string, req *types.UpdateAuditSinkRequest) (*types.AuditSink, error) {
+	sink := fixtureAuditSink()
+	if req.Enabled != nil {
+		sink.Enabled = *req.Enabled
+	}
+	return sink, nil
+}
+
+func (stubAuditSinks) DeleteSink(ctx context.Context, orgID, id string) error {
+	if id == missingID {
+		return notFound("Audit sink")
+	}
+	return nil
+}
+
+func (stubAuditSinks) TestSink(ctx context.Context, orgID, id, userID string) (*types.AuditSinkTestResult, error) {
+	if id == missingID {
+		return &types.AuditSinkTestResult{SinkID: id, Error: "webhook returned 503 Service Unavailable"}, nil
+	}
+	return &types.AuditSinkTestResult{SinkID: id, Delivered: true}, nil
+}
+
+func TestAuditSinkContracts(t *testing.T) {
+	h := handlers.NewAuditSinkHandler(stubAuditSinks{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/audit/sinks", h.ListSinks)
+	admin.POST("/audit/sinks", h.CreateSink)
+	admin.GET("/audit/sinks/:id", h.GetSink)
+	admin.PUT("/audit/sinks/:id", h.UpdateSink)
+	admin.DELETE("/audit/sinks/:id", h.DeleteSink)
+	admin.POST("/audit/sinks/:id/test", h.TestSink)
+
+	base := "/api/admin/audit/sinks/" + fixtureSinkID
+	r.run(t, "audit-sinks", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/audit/sinks"},
+		{name: "create", method: http.MethodPost, path: "/api/admin/audit/sinks",
+			body: map[string]interface{}{"name": "splunk", "type": "webhook", "secret": "s3cret",
+				"config": map[string]interface{}{"url": "https://siem.example.com/ingest"}}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/audit/sinks",
+			body: map[string]interface{}{"name": "splunk", "type": "email"}},
+		{name: "create-rejected", method: http.MethodPost, path: "/api/admin/audit/sinks",
+			body: map[string]interface{}{"name": "kafka", "type": "kafka", "config": map[string]interface{}{"url": "https://kafka.example.com"}}},
+		{name: "malformed-json", method: http.MethodPost, path: "/api/admin/audit/sinks", body: `{"name":`},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/audit/sinks/" + missingID},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"enabled": false}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "test", method: http.MethodPost, path: base + "/test"},
+		{name: "test-undelivered", method: http.MethodPost, path: "/api/admin/audit/sinks/" + missingID + "/test"},
+	})
+}
+
+func fixtureTeam() *types.Team {
+	return &types.Team{
+		CreatedAt:      fixtureTime,
+		UpdatedAt:      fixtureLaterTime,
+		ID:             fixtureTeamID,
+		OrganizationID: fixtureOrgID,
+		Name:           "Support",
+		Description:    "First-line support",
+		Role:           types.RoleUser,
+		IdPGroup:       "okta-support",
+		CreatedBy:      fixtureUserID,
+		MemberCount:    2,
+	}
+}
+
+// stubTeams serves the fixture team
+type stubTeams struct{}
+
+func (stubTeams) ListTeams(ctx context.Context, orgID string) ([]*types.Team, error) {
+	return []*types.Team{fixtureTeam()}, nil
+}
+
+func (stubTeams) ListUserTeams(ctx context.Context, userID string) ([]*types.Team, error) {
+	return []*types.Team{fixtureTeam()}, nil
+}
+
+func (stubTeams) GetTeam(ctx context.Context, orgID, teamID string) (*types.Team, error) {
+	if teamID == missingID {
+		return nil, notFound("Team")
+	}
+	return fixtureTeam(), nil
+}
+
+func (stubTeams) CreateTeam(ctx context.Context, orgID, createdBy string, req *types.CreateTeamRequest) (*types.Team, error) {
+	if req.Name == "Support" {
+		return nil, types.NewConflictError("a team named Support already exists")
+	}
+	team := fixtureTeam()
+	team.Name = req.Name
+	team.Role = req.Role
+	team.IdPGroup = req.IdPGroup
+	team.MemberCount = 0
+	return team, nil
+}
+
+func (stubTeams) UpdateTeam(ctx context.Context, orgID, teamID string, req *types.UpdateTeamRequest) (*types.Team, error) {
+	team := fixtureTeam()
+	if req.Description != nil {
+		team.Description = *req.Description
+	}
+	return team, nil
+}
+
+func (stubTeams) DeleteTeam(ctx context.Context, orgID, teamID string) error {
+	if teamID == missingID {
+		return notFound("Team")
+	}
+	return nil
+}
+
+func (stubTeams) ListMembers(ctx context.Context, orgID, teamID string) ([]*types.TeamMember, error) {
+	return []*types.TeamMember{
+		{AddedAt: fixtureTime, TeamID: teamID, UserID: fixtureUserID, Email: "ada@example.com", Name: "Ada", Source: "manual"},
+		{AddedAt: fixtureLaterTime, TeamID: teamID, UserID: fixtureMemberUserID, Email: "grace@example.com", Name: "Grace", Source: "idp"},
+	}, nil
+}
+
+func (stubTeams) AddMember(ctx context.Context, orgID, teamID, userID string) error {
+	if userID == fixtureMemberUserID {
+		return types.NewConflictError("user is already a member of the team")
+	}
+	return nil
+}
+
+func (stubTeams) RemoveMember(ctx context.Context, orgID, teamID, userID string) error {
+	return nil
+}
+
+func (stubTeams) SyncGroups(ctx context.Context, orgID, userID string, groups []string) (*types.TeamSyncResult, error) {
+	return &types.TeamSyncResult{Joined: []string{fixtureTeamID}, Left: []string{}}, nil
+}
+
+func TestTeamContracts(t *testing.T) {
+	h := handlers.NewTeamHandler(stubTeams{})
+	r := newContractRouter()
+	r.GET("/api/auth/teams", h.ListMyTeams)
+	admin := r.Group("/api/admin")
+	admin.GET("/teams", h.ListTeams)
+	admin.POST("/teams", h.CreateTeam)
+	admin.POST("/teams/sync", h.SyncGroups)
+	admin.GET("/teams/:id", h.GetTeam)
+	admin.PUT("/teams/:id", h.UpdateTeam)
+	admin.DELETE("/teams/:id", h.DeleteTeam)
+	admin.GET("/teams/:id/members", h.ListMembers)
+	admin.POST("/teams/:id/members", h.AddMember)
+	admin.DELETE("/teams/:id/members/:user_id", h.RemoveMember)
+
+	base := "/api/admin/teams/" + fixtureTeamID
+	r.run(t, "teams", []contractCase{
+		{name: "my-teams", method: http.MethodGet, path: "/api/auth/teams"},
+		{name: "list", method: http.MethodGet, path: "/api/admin/teams"},
+		{name: "create", method: http.MethodPost, path: "/api/admin/teams",
+			body: map[string]interface{}{"name": "Billing", "role": "viewer", "idp_group": "okta-billing"}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/teams",
+			body: map[string]interface{}{"name": "Billing", "role": "owner"}},
+		{name: "create-conflict", method: http.MethodPost, path: "/api/admin/teams", body: map[string]interface{}{"name": "Support"}},
+		{name: "sync", method: http.MethodPost, path: "/api/admin/teams/sync",
+			body: map[string]interface{}{"user_id": fixtureMemberUserID, "groups": []string{"okta-support"}}},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/teams/" + missingID},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"description": "Support, all tiers"}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "members", method: http.MethodGet, path: base + "/members"},
+		{name: "add-member", method: http.MethodPost, path: base + "/members", body: map[string]interface{}{"user_id": fixtureUserID}},
+		{name: "add-member-invalid", method: http.MethodPost, path: base + "/members", body: map[string]interface{}{"user_id": "ada"}},
+		{name: "add-member-conflict", method: http.MethodPost, path: base + "/members",
+			body: map[string]interface{}{"user_id": fixtureMemberUserID}},
+		{name: "remove-member", method: http.MethodDelete, path: base + "/members/" + fixtureMemberUserID},
+	})
+}
+
+func fixtureLegalHold() *types.LegalHold {
+	return &types.LegalHold{
+		PlacedAt:       fixtureTime,
+		ID:             fixtureHoldID,
+		OrganizationID: fixtureOrgID,
+		Reason:         "Litigation 2026-114",
+		PlacedBy:       fixtureUserID,
+	}
+}
+
+// stubLegalHolds holds fixtureOrgID and nothing else
+type stubLegalHolds struct{}
+
+func (stubLegalHolds) List(ctx context.Context, includeReleased bool) ([]*types.LegalHold, error) {
+	holds := []*types.LegalHold{fixtureLegalHold()}
+	if includeReleased {
+		released := fixtureLegalHold()
+		released.ID = missingID
+		released.ReleasedAt = &fixtureLaterTime
+		released.ReleasedBy = fixtureUserID
+		released.ReleaseReason = "Settled"
+		holds = append(holds, released)
+	}
+	return holds, nil
+}
+
+func (stubLegalHolds) Active(ctx context.Context, orgID string) (*types.LegalHold, error) {
+	if orgID != fixtureOrgID {
+		return nil, nil
+	}
+	return fixtureLegalHold(), nil
+}
+
+func (stubLegalHolds) Place(ctx context.Context, orgID, placedBy, reason string) (*types.LegalHold, error) {
+	if orgID == fixtureOrgID {
+		return nil, types.NewConflictError("organization is already under legal hold")
+	}
+	hold := fixtureLegalHold()
+	hold.OrganizationID = orgID
+	hold.Reason = reason
+	return hold, nil
+}
+
+func (stubLegalHolds) Release(ctx context.Context, orgID, releasedBy, reason string) (*types.LegalHold, error) {
+	if orgID != fixtureOrgID {
+		return nil, notFound("Legal hold")
+	}
+	hold := fixtureLegalHold()
+	hold.ReleasedAt = &fixtureLaterTime
+	hold.ReleasedBy = releasedBy
+	hold.ReleaseReason = reason
+	return hold, nil
+}
+
+// discardAudit drops the audit records handlers write
+type discardAudit struct{}
+
+func (discardAudit) LogAudit(ctx context.Context, event *types.AuditLog) error {
+	return nil
+}
+
+func TestLegalHoldContracts(t *testing.T) {
+	h := handlers.NewLegalHoldHandler(stubLegalHolds{}, discardAudit{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/legal-holds", h.ListHolds)
+	admin.GET("/legal-holds/:org_id", h.GetHold)
+	admin.POST("/legal-holds/:org_id", h.PlaceHold)
+	admin.POST("/legal-holds/:org_id/release", h.ReleaseHold)
+
+	r.run(t, "legal-holds", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/legal-holds"},
+		{name: "list-including-released", method: http.MethodGet, path: "/api/admin/legal-holds", query: "include_released=true"},
+		{name: "get", method: http.MethodGet, path: "/api/admin/legal-holds/" + fixtureOrgID},
+		{name: "get-not-held", method: http.MethodGet, path: "/api/admin/legal-holds/" + missingID},
+		{name: "place", method: http.MethodPost, path: "/api/admin/legal-holds/" + missingID,
+			body: map[string]interface{}{"reason": "Regulator inquiry"}},
+		{name: "place-invalid", method: http.MethodPost, path: "/api/admin/legal-holds/" + missingID, body: map[string]interface{}{}},
+		{name: "place-conflict", method: http.MethodPost, path: "/api/admin/legal-holds/" + fixtureOrgID,
+			body: map[string]interface{}{"reason": "Regulator inquiry"}},
+		{name: "release", method: http.MethodPost, path: "/api/admin/legal-holds/" + fixtureOrgID + "/release",
+			body: map[string]interface{}{"reason": "Settled"}},
+		{name: "release-not-held", method: http.MethodPost, path: "/api/admin/legal-holds/" + missingID + "/release",
+			body: map[string]interface{}{"reason": "Settled"}},
+	})
+}
+
+func fixtureManagedUser() *types.ManagedUser {
+	return &types.ManagedUser{
+		CreatedAt:      fixtureTime,
+		UpdatedAt:      fixtureLaterTime,
+		ID:             fixtureMemberUserID,
+		OrganizationID: fixtureOrgID,
+		Email:          "grace@example.com",
+		Name:           "Grace",
+		Role:           types.RoleUser,
+		Status:         types.UserStatusActive,
+	}
+}
+
+// fixtureDelivery is a link that could not be emailed, so it is returned
+func fixtureDelivery(user *types.ManagedUser) *types.UserTokenDelivery {
+	return &types.UserTokenDelivery{
+		User:      user,
+		ExpiresAt: fixtureLaterTime,
+		URL:       "https://gateway.example.com/invitations/accept?token=fixture-token",
+	}
+}
+
+// stubUsers serves the fixture user. Tokens other than fixture-token are
+// rejected, and the fixture user's email is taken.
+type stubUsers struct{}
+
+func (stubUsers) List(ctx context.Context, orgID string, filter *types.ManagedUserFilter) ([]*types.ManagedUser, error) {
+	if filter.Status != "" && filter.Status != types.UserStatusActive {
+		return []*types.ManagedUser{}, nil
+	}
+	return []*types.ManagedUser{fixtureManagedUser()}, nil
+}
+
+func (stubUsers) Get(ctx context.Context, orgID, id string) (*types.ManagedUser, error) {
+	if id == missingID {
+		return nil, notFound("User")
+	}
+	return fixtureManagedUser(), nil
+}
+
+func (stubUsers) Create(ctx context.Context, orgID, actorID string, req *types.CreateManagedUserRequest) (*types.ManagedUser, error) {
+	if req.Email == "grace@example.com" {
+		return nil, types.NewConflictError("a user with this email already exists")
+	}
+	user := fixtureManagedUser()
+	user.Email = req.Email
+	user.Name = req.Name
+	user.Role = req.Role
+	return user, nil
+}
+
+func (stubUsers) Invite(ctx context.Context, orgID, actorID string, req *types.InviteUserRequest) (*types.UserTokenDelivery, error) {
+	user := fixtureManagedUser()
+	user.Email = req.Email
+	user.Name = req.Name
+	user.Role = req.Role
+	user.Status = types.UserStatusInvited
+	user.InvitedAt = &fixtureTime
+	user.InvitedBy = actorID
+	return fixtureDelivery(user), nil
+}
+
+func (stubUsers) ResendInvitation(ctx context.Context, orgID, actorID, id string) (*types.UserTokenDelivery, error) {
+	if id != missingID {
+		return nil, types.NewValidationError("only invited users can be sent an invitation")
+	}
+	return nil, notFound("User")
+}
+
+func (stubUsers) Update(ctx context.Context, orgID, actorID, id string, req *types.UpdateManagedUserRequest) (*types.ManagedUser, error) {
+	user := fixtureManagedUser()
+	if req.Role != nil {
+		user.Role = *req.Role
+	}
+	return user, nil
+}
+
+func (stubUsers) Deactivate(ctx context.Context, orgID, actorID, id string) (*types.ManagedUser, error) {
+	if id == actorID {
+		return nil, types.NewValidationError("you cannot deactivate yourself")
+	}
+	user := fixtureManagedUser()
+	user.Status = types.UserStatusDeactivated
+	user.DeactivatedAt = &fixtureLaterTime
+	return user, nil
+}
+
+func (stubUsers) Reactivate(ctx context.Context, orgID, actorID, id string) (*types.ManagedUser, error) {
+	return fixtureManagedUser(), nil
+}
+
+func (stubUsers) SendPasswordReset(ctx context.Context, orgID, actorID, id string) (*types.UserTokenDelivery, error) {
+	delivery := fixtureDelivery(fixtureManagedUser())
+	delivery.URL = ""
+	delivery.EmailSent = true
+	return delivery, nil
+}
+
+func (stubUsers) RequestPasswordReset(ctx context.Context, email string) error {
+	return nil
+}
+
+func (stubUsers) AcceptInvitation(ctx context.Context, req *types.AcceptInvitationRequest) error {
+	if req.Token != "fixture-token" {
+		return types.NewValidationError("the invitation link is invalid or has expired")
+	}
+	return nil
+}
+
+func (stubUsers) ResetPassword(ctx context.Context, req *types.ResetPasswordRequest) error {
+	if req.Token != "fixture-token" {
+		return types.NewValidationError("the reset link is invalid or has expired")
+	}
+	return nil
+}
+
+func TestUserContracts(t *testing.T) {
+	h := handlers.NewUserHandler(stubUsers{})
+	r := newContractRouter()
+	auth := r.Group("/api/auth")
+	auth.POST("/invitations/accept", h.AcceptInvitation)
+	auth.POST("/password-reset", h.RequestPasswordReset)
+	auth.POST("/password-reset/confirm", h.ResetPassword)
+	admin := r.Group("/api/admin")
+	admin.GET("/users", h.List)
+	admin.POST("/users", h.Create)
+	admin.POST("/users/invitations", h.Invite)
+	admin.GET("/users/:id", h.Get)
+	admin.PUT("/users/:id", h.Update)
+	admin.POST("/users/:id/invitation", h.ResendInvitation)
+	admin.POST("/users/:id/deactivate", h.Deactivate)
+	admin.POST("/users/:id/reactivate", h.Reactivate)
+	admin.POST("/users/:id/password-reset", h.SendPasswordReset)
+
+	base := "/api/admin/users/" + fixtureMemberUserID
+	r.run(t, "users", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/users"},
+		{name: "list-filtered", method: http.MethodGet, path: "/api/admin/users", query: "status=invited&role=admin"},
+		{name: "create", method: http.MethodPost, path: "/api/admin/users",
+			body: map[string]interface{}{"email": "ada@example.com", "name": "Ada", "password": "correct-horse", "role": "admin"}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/users",
+			body: map[string]interface{}{"email": "ada", "name": "Ada", "password": "short", "role": "owner"}},
+		{name: "create-conflict", method: http.MethodPost, path: "/api/admin/users",
+			body: map[string]interface{}{"email": "grace@example.com", "name": "Grace", "password": "correct-horse", "role": "user"}},
+		{name: "invite", method: http.MethodPost, path: "/api/admin/users/invitations",
+			body: map[string]interface{}{"email": "ada@example.com", "name": "Ada", "role": "viewer"}},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/users/" + missingID},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"role": "viewer"}},
+		{name: "update-invalid", method: http.MethodPut, path: base, body: map[string]interface{}{"role": "owner"}},
+		{name: "resend-invitation-active", method: http.MethodPost, path: base + "/invitation"},
+		{name: "resend-invitation-not-found", method: http.MethodPost, path: "/api/admin/users/" + missingID + "/invitation"},
+		{name: "deactivate", method: http.MethodPost, path: base + "/deactivate"},
+		{name: "deactivate-self", method: http.MethodPost, path: "/api/admin/users/" + fixtureUserID + "/deactivate"},
+		{name: "reactivate", method: http.MethodPost, path: base + "/reactivate"},
+		{name: "send-password-reset", method: http.MethodPost, path: base + "/password-reset"},
+		{name: "request-password-reset", method: http.MethodPost, path: "/api/auth/password-reset",
+			body: map[string]interface{}{"email": "grace@example.com"}},
+		{name: "reset-password", method: http.MethodPost, path: "/api/auth/password-reset/confirm",
+			body: map[string]interface{}{"token": "fixture-token", "password": "correct-horse"}},
+		{name: "reset-password-expired", method: http.MethodPost, path: "/api/auth/password-reset/confirm",
+			body: map[string]interface{}{"token": "old-token", "password": "correct-horse"}},
+		{name: "accept-invitation", method: http.MethodPost, path: "/api/auth/invitations/accept",
+			body: map[string]interface{}{"token": "fixture-token", "password": "correct-horse"}},
+		{name: "accept-invitation-invalid", method: http.MethodPost, path: "/api/auth/invitations/accept",
+			body: map[string]interface{}{"token": "fixture-token", "password": "short"}},
+	})
+}
+
+const fixtureImportID = "b3a29180-7f6e-4d5c-9b3a-29180f7e6dfc"
+
+// stubAuthConfig keeps the default authentication configuration. It
+// validates updates and reports defaults like the real service.
+type stubAuthConfig struct {
+	*auth.ConfigService
+}
+
+func (stubAuthConfig) GetConfiguration(orgID uuid.UUID) (*types.AuthConfigurationResponse, error) {
+	defaults := types.GetAuthConfigDefaults()
+	updatedBy := uuid.MustParse(fixtureUserID)
+	return &types.AuthConfigurationResponse{
+		Methods:     defaults.Methods,
+		Session:     defaults.Session,
+		Security:    defaults.Security,
+		LastUpdated: fixtureLaterTime,
+		UpdatedBy:   &updatedBy,
+	}, nil
+}
+
+func (s stubAuthConfig) UpdateConfiguration(orgID uuid.UUID, req *types.CompleteAuthConfigurationRequest, updatedBy uuid.UUID) (*types.AuthConfigurationResponse, error) {
+	config, err := s.GetConfiguration(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Session != nil && req.Session.SessionTimeoutSeconds != nil {
+		config.Session.SessionTimeoutSeconds = *req.Session.SessionTimeoutSeconds
+	}
+	return config, nil
+}
+
+// stubConfigTransfer exports the fixture server and imports it as a skip
+type stubConfigTransfer struct{}
+
+func (stubConfigTransfer) ExportConfiguration(ctx context.Context, orgID, userID uuid.UUID, req *types.ExportRequest) (*types.ConfigurationExport, error) {
+	return &types.ConfigurationExport{
+		Servers: []interface{}{map[string]interface{}{"id": fixtureServerID, "name": "ticketing", "protocol": "http"}},
+		Metadata: types.ExportMetadata{
+			Timestamp:     fixtureTime,
+			ExportID:      fixtureImportID,
+			Version:       "1.0",
+			Gateway:       "omnimesh-gateway",
+			Organization:  orgID.String(),
+			ExportedBy:    userID.String(),
+			EntityTypes:   req.EntityTypes,
+			TotalEntities: 1,
+		},
+	}, nil
+}
+
+func (stubConfigTransfer) ImportConfiguration(ctx context.Context, orgID, userID uuid.UUID, req *types.ImportRequest) (*types.ImportResult, error) {
+	if req.ConflictStrategy == types.ConflictStrategyFail {
+		return nil, errors.New("server ticketing already exists")
+	}
+	return &types.ImportResult{
+		StartedAt:   fixtureTime,
+		CompletedAt: &fixtureLaterTime,
+		ImportID:    fixtureImportID,
+		Status:      types.ImportStatusCompleted,
+		Details: []types.ImportItemResult{
+			{EntityType: types.EntityTypeServer, EntityID: fixtureServerID, EntityName: "ticketing",
+				Action: types.ImportActionSkip, Status: "skipped", Message: "a server named ticketing exists"},
+		},
+		Summary: types.ImportSummary{
+			EntityCounts: map[string]types.ImportEntityCount{types.EntityTypeServer: {Total: 1, Skipped: 1}},
+			TotalItems:   1, ProcessedItems: 1, SkippedItems: 1,
+		},
+	}, nil
+}
+
+func (stubConfigTransfer) ValidateImport(ctx context.Context, orgID uuid.UUID, req *types.ValidateImportRequest) (*types.ValidationResult, error) {
+	return &types.ValidationResult{
+		EntityCounts: map[string]int{types.EntityTypeServer: len(req.ConfigData.Servers)},
+		Conflicts: []types.ConflictItem{
+			{EntityType: types.EntityTypeServer, EntityName: "ticketing", ConflictType: "name",
+				ExistingValue: fixtureServerID, ImportValue: "ticketing", Suggestion: "use conflict_strategy rename"},
+		},
+		CompatibilityCheck: types.CompatibilityResult{Version: "1.0", Compatible: true},
+		Valid:              true,
+	}, nil
+}
+
+func (stubConfigTransfer) GetImportHistory(ctx context.Context, orgID uuid.UUID, query *types.ImportHistoryQuery) ([]types.ImportHistory, int, error) {
+	if query.Status != "" && query.Status != types.ImportStatusCompleted {
+		return []types.ImportHistory{}, 0, nil
+	}
+	return []types.ImportHistory{{
+		CreatedAt:        fixtureTime,
+		CompletedAt:      &fixtureLaterTime,
+		Filename:         "omnimesh-gateway-config-export.json",
+		OrganizationID:   fixtureOrgID,
+		Status:           types.ImportStatusCompleted,
+		ConflictStrategy: types.ConflictStrategySkip,
+		ID:               fixtureImportID,
+		ImportedBy:       fixtureUserID,
+		EntityTypes:      []string{types.EntityTypeServer},
+		Summary:          types.ImportSummary{TotalItems: 1, ProcessedItems: 1, SkippedItems: 1},
+	}}, 1, nil
+}
+
+func TestAdminConfigContracts(t *testing.T) {
+	h := handlers.NewAdminHandler(nil, nil, stubConfigTransfer{}, stubAuthConfig{auth.NewConfigService(nil)})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/auth-config", h.GetAuthConfig)
+	admin.PUT("/auth-config", h.UpdateAuthConfig)
+	admin.GET("/auth-config/defaults", h.GetAuthConfigDefaults)
+	admin.POST("/config/export", h.ExportConfiguration)
+	admin.POST("/config/import", h.ImportConfiguration)
+	admin.POST("/config/validate-import", h.ValidateImport)
+	admin.GET("/config/import-history", h.GetImportHistory)
+
+	configData := map[string]interface{}{
+		"servers":  []interface{}{map[string]interface{}{"name": "ticketing", "protocol": "http"}},
+		"metadata": map[string]interface{}{"version": "1.0", "entity_types": []string{"server"}},
+	}
+	r.run(t, "admin-config", []contractCase{
+		{name: "auth-config", method: http.MethodGet, path: "/api/admin/auth-config"},
+		{name: "update-auth-config", method: http.MethodPut, path: "/api/admin/auth-config",
+			body: map[string]interface{}{"session": map[string]interface{}{"session_timeout_seconds": 3600}}},
+		{name: "update-auth-config-invalid", method: http.MethodPut, path: "/api/admin/auth-config",
+			body: map[string]interface{}{"session": map[string]interface{}{"refresh_strategy": "forever"}}},
+		{name: "auth-config-defaults", method: http.MethodGet, path: "/api/admin/auth-config/defaults"},
+		{name: "export", method: http.MethodPost, path: "/api/admin/config/export",
+			body: map[string]interface{}{"entity_types": []string{"server"}}},
+		{name: "export-invalid", method: http.MethodPost, path: "/api/admin/config/export", body: map[string]interface{}{}},
+		{name: "import", method: http.MethodPost, path: "/api/admin/config/import",
+			body: map[string]interface{}{"conflict_strategy": "skip", "config_data": configData}},
+		{name: "import-failed", method: http.MethodPost, path: "/api/admin/config/import",
+			body: map[string]interface{}{"conflict_strategy": "fail", "config_data": configData}},
+		{name: "validate-import", method: http.MethodPost, path: "/api/admin/config/validate-import",
+			body: map[string]interface{}{"config_data": configData}},
+		{name: "import-history", method: http.MethodGet, path: "/api/admin/config/import-history"},
+		{name: "import-history-filtered", method: http.MethodGet, path: "/api/admin/config/import-history",
+			query: "status=failed&limit=10&offset=5"},
+	})
+}
@@ -0,0 +1,203 @@
+package contract
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const fixtureAPIKeyID = "4a3f2e1d-0c9b-4a8f-9e7d-6c5b4a3f2eb9"
+
+func fixtureAuthUser() *types.User {
+	return &types.User{
+		CreatedAt:      fixtureTime,
+		UpdatedAt:      fixtureLaterTime,
+		ID:             fixtureUserID,
+		Email:          "ada@example.com",
+		Name:           "Ada",
+		PasswordHash:   "never-serialized",
+		OrganizationID: fixtureOrgID,
+		Role:           types.RoleAdmin,
+		AccountType:    types.AccountTypeHuman,
+		IsActive:       true,
+	}
+}
+
+func fixtureAPIKey() *types.APIKey {
+	return &types.APIKey{
+		CreatedAt:      fixtureTime,
+		UpdatedAt:      fixtureLaterTime,
+		LastUsedAt:     &fixtureLaterTime,
+		Labels:         types.Labels{"env": "prod"},
+		ID:             fixtureAPIKeyID,
+		UserID:         fixtureUserID,
+		OrganizationID: fixtureOrgID,
+		Name:           "ci",
+		Prefix:         "omk_3f9a",
+		Permissions:    []string{types.PermissionServerRead, types.PermissionToolExecute},
+		AllowedCIDRs:   []string{"10.0.0.0/8"},
+		Role:           types.RoleUser,
+		IsActive:       true,
+	}
+}
+
+func fixtureLogin() *types.LoginResponse {
+	return &types.LoginResponse{
+		User:         fixtureAuthUser(),
+		AccessToken:  "fixture-access-token",
+		RefreshToken: "fixture-refresh-token",
+		TokenType:    "Bearer",
+		ExpiresIn:    900,
+	}
+}
+
+// stubAuth signs in ada@example.com with password correct-horse and owns
+// the fixture API key
+type stubAuth struct{}
+
+func (stubAuth) Login(email, password string) (*types.LoginResponse, error) {
+	if email != "ada@example.com" || password != "correct-horse" {
+		return nil, types.NewUnauthorizedError("invalid credentials")
+	}
+	return fixtureLogin(), nil
+}
+
+func (stubAuth) RefreshToken(refreshToken string) (*types.LoginResponse, error) {
+	if refreshToken != "fixture-refresh-token" {
+		return nil, types.NewUnauthorizedError("invalid refresh token")
+	}
+	return fixtureLogin(), nil
+}
+
+func (stubAuth) Logout(accessToken string) error {
+	return nil
+}
+
+func (stubAuth) GetUserByID(userID string) (*types.User, error) {
+	return fixtureAuthUser(), nil
+}
+
+func (stubAuth) UpdateUser(userID string, req *types.UpdateUserRequest) (*types.User, error) {
+	user := fixtureAuthUser()
+	if req.Name != "" {
+		user.Name = req.Name
+	}
+	return user, nil
+}
+
+func (stubAuth) CreateAPIKey(userID string, req *types.CreateAPIKeyRequest) (*types.CreateAPIKeyResponse, error) {
+	if _, err := types.NormalizeAPIKeyCIDRs(req.AllowedCIDRs); err != nil {
+		return nil, err
+	}
+	key := fixtureAPIKey()
+	key.Name = req.Name
+	key.Labels = req.Labels
+	key.AllowedCIDRs = req.AllowedCIDRs
+	key.LastUsedAt = nil
+	return &types.CreateAPIKeyResponse{APIKey: key, Key: "omk_3f9a_fixture-secret"}, nil
+}
+
+func (stubAuth) ListAllAPIKeys(organizationID string) ([]*types.APIKey, error) {
+	return []*types.APIKey{fixtureAPIKey()}, nil
+}
+
+func (stubAuth) DeleteAPIKeyByAdmin(organizationID, keyID string) error {
+	if keyID == missingID {
+		return types.NewNotFoundError("API key not found")
+	}
+	return nil
+}
+
+func (stubAuth) UpdateAPIKeyRestrictions(userID, keyID string, req *types.UpdateAPIKeyRestrictionsRequest) (*types.APIKey, error) {
+	cidrs, err := types.NormalizeAPIKeyCIDRs(req.AllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+	origins, err := types.NormalizeAPIKeyOrigins(req.AllowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+	key := fixtureAPIKey()
+	key.AllowedCIDRs = cidrs
+	key.AllowedOrigins = origins
+	return key, nil
+}
+
+func (stubAuth) UpdateAPIKeyScope(userID, keyID string, req *types.UpdateAPIKeyScopeRequest) (*types.APIKey, error) {
+	if keyID == missingID {
+		return nil, types.NewNotFoundError("API key not found")
+	}
+	scope, err := types.NormalizeAPIKeyScope(req.AllowedNamespaces, req.AllowedServers, req.AllowedTools)
+	if err != nil {
+		return nil, err
+	}
+	key := fixtureAPIKey()
+	key.AllowedNamespaces = scope.Namespaces
+	key.AllowedServers = scope.Servers
+	key.AllowedTools = scope.Tools
+	return key, nil
+}
+
+func TestAuthContracts(t *testing.T) {
+	h := handlers.NewAuthHandler(stubAuth{})
+	r := newContractRouter()
+	auth := r.Group("/api/auth")
+	auth.POST("/login", h.Login)
+	auth.POST("/refresh", h.RefreshToken)
+	auth.POST("/logout", h.Logout)
+	auth.GET("/profile", h.GetProfile)
+	auth.PUT("/profile", h.UpdateProfile)
+
+	r.run(t, "auth", []contractCase{
+		{name: "login", method: http.MethodPost, path: "/api/auth/login",
+			body: map[string]interface{}{"email": "ada@example.com", "password": "correct-horse"}},
+		{name: "login-invalid", method: http.MethodPost, path: "/api/auth/login",
+			body: map[string]interface{}{"email": "ada"}},
+		{name: "login-wrong-password", method: http.MethodPost, path: "/api/auth/login",
+			body: map[string]interface{}{"email": "ada@example.com", "password": "wrong-horse"}},
+		{name: "refresh", method: http.MethodPost, path: "/api/auth/refresh",
+			body: map[string]interface{}{"refresh_token": "fixture-refresh-token"}},
+		{name: "refresh-expired", method: http.MethodPost, path: "/api/auth/refresh",
+			body: map[string]interface{}{"refresh_token": "old-refresh-token"}},
+		{name: "logout-without-token", method: http.MethodPost, path: "/api/auth/logout"},
+		{name: "profile", method: http.MethodGet, path: "/api/auth/profile"},
+		{name: "update-profile", method: http.MethodPut, path: "/api/auth/profile", body: map[string]interface{}{"name": "Ada Lovelace"}},
+		{name: "update-profile-invalid", method: http.MethodPut, path: "/api/auth/profile", body: map[string]interface{}{"name": "A"}},
+	})
+}
+
+func TestAPIKeyContracts(t *testing.T) {
+	h := handlers.NewAuthHandler(stubAuth{})
+	r := newContractRouter()
+	auth := r.Group("/api/auth")
+	auth.POST("/api-keys", h.CreateAPIKey)
+	auth.GET("/api-keys", h.ListAPIKeys)
+	auth.DELETE("/api-keys/:id", h.DeleteAPIKey)
+	auth.PUT("/api-keys/:id/restrictions", h.UpdateAPIKeyRestrictions)
+	auth.PUT("/api-keys/:id/scope", h.UpdateAPIKeyScope)
+
+	base := "/api/auth/api-keys/" + fixtureAPIKeyID
+	r.run(t, "api-keys", []contractCase{
+		{name: "create", method: http.MethodPost, path: "/api/auth/api-keys",
+			body: map[string]interface{}{"name": "deploy", "role": "user", "labels": map[string]string{"env": "staging"},
+				"allowed_cidrs": []string{"192.0.2.0/24"}}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/auth/api-keys", body: map[string]interface{}{"name": "d"}},
+		{name: "create-rejected", method: http.MethodPost, path: "/api/auth/api-keys",
+			body: map[string]interface{}{"name": "deploy", "role": "user", "allowed_cidrs": []string{"not-a-network"}}},
+		{name: "list", method: http.MethodGet, path: "/api/auth/api-keys"},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "delete-not-found", method: http.MethodDelete, path: "/api/auth/api-keys/" + missingID},
+		{name: "update-restrictions", method: http.MethodPut, path: base + "/restrictions",
+			body: map[string]interface{}{"allowed_cidrs": []string{"10.0.0.0/8"}, "allowed_origins": []string{"https://support.example.com"}}},
+		{name: "update-restrictions-rejected", method: http.MethodPut, path: base + "/restrictions",
+			body: map[string]interface{}{"allowed_origins": []string{"support.example.com/path"}}},
+		{name: "update-scope", method: http.MethodPut, path: base + "/scope",
+			body: map[string]interface{}{"allowed_namespaces": []string{fixtureNamespaceID}, "allowed_tools": []string{"github__*"}}},
+		{name: "update-scope-rejected", method: http.MethodPut, path: base + "/scope",
+			body: map[string]interface{}{"allowed_servers": []string{"ticketing"}}},
+		{name: "update-scope-not-found", method: http.MethodPut, path: "/api/auth/api-keys/" + missingID + "/scope",
+			body: map[string]interface{}{}},
+	})
+}
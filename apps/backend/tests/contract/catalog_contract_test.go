@@ -0,0 +1,630 @@
+package contract
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/a2a"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/discovery"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/plugins"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/plugins/content_filters/pii"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/virtual"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixturePromptID   = "c4b3a2f1-e0d9-4c8b-a7f6-e5d4c3b2a184"
+	fixtureResourceID = "d5c4b3a2-f1e0-4d9c-b8a7-f6e5d4c3b295"
+)
+
+var toolColumns = []string{"id", "organization_id", "name", "description", "function_name", "schema", "category",
+	"implementation_type", "endpoint_url", "timeout_seconds", "max_retries", "usage_count",
+	"access_permissions", "is_active", "is_public", "metadata", "tags", "examples",
+	"documentation", "created_at", "updated_at", "created_by", "server_id", "source_type",
+	"last_discovered_at", "discovery_metadata"}
+
+// toolRows returns the fixture tool as the tool model selects it
+func toolRows() *sqlmock.Rows {
+	return sqlmock.NewRows(toolColumns).
+		AddRow(fixtureToolID, fixtureOrgID, "search-tickets", "Search the ticket queue", "search_tickets",
+			[]byte(`{"type":"object","properties":{"query":{"type":"string"}}}`), types.ToolCategoryData,
+			types.ToolImplementationExternal, "https://tickets.example.com/search", 30, 3, 42,
+			nil, true, true, nil, []byte(`{support,tickets}`), nil,
+			nil, fixtureTime, fixtureLaterTime, fixtureUserID, nil, "manual",
+			nil, nil)
+}
+
+var promptColumns = []string{"id", "organization_id", "name", "description", "prompt_template", "parameters",
+	"category", "usage_count", "is_active", "metadata", "tags", "created_at", "updated_at", "created_by"}
+
+// promptRows returns the fixture prompt as the prompt model selects it
+func promptRows() *sqlmock.Rows {
+	return sqlmock.NewRows(promptColumns).
+		AddRow(fixturePromptID, fixtureOrgID, "triage", "Triage a support ticket",
+			"Summarize {{ticket}} and suggest a priority", []byte(`[{"name":"ticket","required":true}]`),
+			types.PromptCategoryBusiness, 7, true, nil, []byte(`{support}`), fixtureTime, fixtureLaterTime, fixtureUserID)
+}
+
+var resourceColumns = []string{"id", "organization_id", "name", "description", "resource_type", "uri", "mime_type",
+	"size_bytes", "access_permissions", "is_active", "metadata", "tags", "created_at", "updated_at", "created_by"}
+
+// resourceRows returns the fixture resource as the resource model selects it
+func resourceRows() *sqlmock.Rows {
+	return sqlmock.NewRows(resourceColumns).
+		AddRow(fixtureResourceID, fixtureOrgID, "runbook", "On-call runbook", types.ResourceTypeURL,
+			"https://wiki.example.com/runbook", "text/html", 2048, nil, true, nil, []byte(`{oncall}`),
+			fixtureTime, fixtureLaterTime, fixtureUserID)
+}
+
+// stubToolLimits limits the fixture tool to 100 calls a day
+type stubToolLimits struct{}
+
+func fixtureToolLimitStatus(toolID string) *types.ToolLimitStatus {
+	return &types.ToolLimitStatus{
+		ToolLimits: types.ToolLimits{UpdatedAt: &fixtureTime, ToolID: toolID, RateLimit: 10, RateWindowSeconds: 60, DailyBudget: 100},
+		ResetsAt:   fixtureLaterTime,
+		CallsToday: 42,
+		Remaining:  58,
+	}
+}
+
+func (stubToolLimits) GetLimits(ctx context.Context, orgID, toolID string) (*types.ToolLimitStatus, error) {
+	if toolID != fixtureToolID {
+		return nil, notFound("Tool")
+	}
+	return fixtureToolLimitStatus(toolID), nil
+}
+
+func (stubToolLimits) SetLimits(ctx context.Context, orgID, toolID string, req *types.SetToolLimitsRequest) (*types.ToolLimitStatus, error) {
+	status := fixtureToolLimitStatus(toolID)
+	status.RateLimit, status.RateWindowSeconds, status.DailyBudget = req.RateLimit, req.RateWindowSeconds, req.DailyBudget
+	status.Remaining = req.DailyBudget - status.CallsToday
+	return status, nil
+}
+
+func (stubToolLimits) DeleteLimits(ctx context.Context, orgID, toolID string) error {
+	if toolID != fixtureToolID {
+		return notFound("Tool")
+	}
+	return nil
+}
+
+func (stubToolLimits) UsageForTools(ctx context.Context, toolIDs []string) (map[string]*types.ToolLimitStatus, error) {
+	return map[string]*types.ToolLimitStatus{fixtureToolID: fixtureToolLimitStatus(fixtureToolID)}, nil
+}
+
+func TestToolContracts(t *testing.T) {
+	// Tools get their IDs from uuid.New
+	uuid.SetRand(rand.New(rand.NewSource(1)))
+	defer uuid.SetRand(nil)
+
+	// The tool model queries the database itself, so its queries are
+	// answered in the order of the cases below
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	byID := `SELECT .+ FROM mcp_tools\s+WHERE id = \$1`
+	byName := `SELECT .+ FROM mcp_tools\s+WHERE organization_id = \$1 AND name = \$2`
+	byFunction := `SELECT .+ FROM mcp_tools\s+WHERE organization_id = \$1 AND function_name = \$2`
+	mock.ExpectQuery(`SELECT .+ FROM mcp_tools\s+WHERE organization_id = \$1\s+ORDER BY`).WillReturnRows(toolRows())
+	mock.ExpectQuery(`SELECT .+ FROM mcp_tools\s+WHERE is_public = true AND is_active = true`).
+		WithArgs(20, 0).WillReturnRows(toolRows())
+	mock.ExpectQuery(byName).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byFunction).WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(`INSERT INTO mcp_tools`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byName).WillReturnRows(toolRows())
+	mock.ExpectQuery(byID).WillReturnRows(toolRows())
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byID).WillReturnRows(toolRows())
+	mock.ExpectExec(`UPDATE mcp_tools\s+SET name = \$2`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byID).WillReturnRows(toolRows())
+	mock.ExpectExec(`UPDATE mcp_tools SET usage_count = usage_count \+ 1 WHERE id = \$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byFunction).WillReturnRows(toolRows())
+	mock.ExpectQuery(byFunction).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byID).WillReturnRows(toolRows())
+	mock.ExpectExec(`UPDATE mcp_tools SET is_active = false WHERE id = \$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+
+	h := handlers.NewToolHandler(models.NewMCPToolModel(db), models.NewMCPServerModel(db))
+	h.SetToolLimits(stubToolLimits{})
+	r := newContractRouter()
+	r.GET("/api/mcp/tools/public", h.ListPublicTools)
+	tools := r.Group("/api/gateway/tools")
+	tools.GET("", h.ListTools)
+	tools.POST("", h.CreateTool)
+	tools.GET("/:id", h.GetTool)
+	tools.PUT("/:id", h.UpdateTool)
+	tools.DELETE("/:id", h.DeleteTool)
+	tools.GET("/:id/limits", h.GetLimits)
+	tools.PUT("/:id/limits", h.SetLimits)
+	tools.DELETE("/:id/limits", h.DeleteLimits)
+	tools.POST("/:id/execute", h.ExecuteTool)
+	tools.GET("/function/:function_name", h.GetToolByFunction)
+
+	base := "/api/gateway/tools/" + fixtureToolID
+	tool := map[string]interface{}{
+		"name": "search-tickets", "function_name": "search_tickets", "category": types.ToolCategoryData,
+		"implementation_type": types.ToolImplementationExternal, "endpoint_url": "https://tickets.example.com/search",
+		"schema": map[string]interface{}{"type": "object"},
+	}
+	r.run(t, "tools", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/gateway/tools"},
+		{name: "list-public", method: http.MethodGet, path: "/api/mcp/tools/public", query: "limit=20"},
+		{name: "create", method: http.MethodPost, path: "/api/gateway/tools", body: tool},
+		{name: "create-conflict", method: http.MethodPost, path: "/api/gateway/tools", body: tool},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/gateway/tools",
+			body: map[string]interface{}{"name": "weather", "function_name": "weather", "category": "weather", "schema": map[string]interface{}{}}},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/gateway/tools/" + missingID},
+		{name: "get-invalid", method: http.MethodGet, path: "/api/gateway/tools/search-tickets"},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"description": "Search open tickets", "timeout_seconds": 60}},
+		{name: "update-invalid", method: http.MethodPut, path: "/api/gateway/tools/search-tickets", body: map[string]interface{}{}},
+		{name: "execute", method: http.MethodPost, path: base + "/execute"},
+		{name: "function", method: http.MethodGet, path: "/api/gateway/tools/function/search_tickets"},
+		{name: "function-not-found", method: http.MethodGet, path: "/api/gateway/tools/function/weather"},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "delete-not-found", method: http.MethodDelete, path: "/api/gateway/tools/" + missingID},
+		{name: "limits", method: http.MethodGet, path: base + "/limits"},
+		{name: "limits-not-found", method: http.MethodGet, path: "/api/gateway/tools/" + missingID + "/limits"},
+		{name: "limits-set", method: http.MethodPut, path: base + "/limits",
+			body: map[string]interface{}{"rate_limit": 20, "rate_window_seconds": 60, "daily_budget": 500}},
+		{name: "limits-set-invalid", method: http.MethodPut, path: base + "/limits", body: map[string]interface{}{"rate_window_seconds": 100000}},
+		{name: "limits-delete", method: http.MethodDelete, path: base + "/limits"},
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromptContracts(t *testing.T) {
+	// Prompts get their IDs from uuid.New
+	uuid.SetRand(rand.New(rand.NewSource(1)))
+	defer uuid.SetRand(nil)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	byID := `SELECT .+ FROM mcp_prompts\s+WHERE id = \$1`
+	byName := `SELECT .+ FROM mcp_prompts\s+WHERE organization_id = \$1 AND name = \$2`
+	usage := `UPDATE mcp_prompts SET usage_count = usage_count \+ 1 WHERE id = \$1`
+	mock.ExpectQuery(`SELECT .+ FROM mcp_prompts\s+WHERE organization_id = \$1\s+ORDER BY`).WillReturnRows(promptRows())
+	mock.ExpectQuery(byName).WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(`INSERT INTO mcp_prompts`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byName).WillReturnRows(promptRows())
+	mock.ExpectQuery(byID).WillReturnRows(promptRows())
+	mock.ExpectExec(usage).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byID).WillReturnRows(promptRows())
+	mock.ExpectExec(`UPDATE mcp_prompts\s+SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byID).WillReturnRows(promptRows())
+	mock.ExpectExec(usage).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byID).WillReturnRows(promptRows())
+	mock.ExpectExec(`UPDATE mcp_prompts SET is_active = false WHERE id = \$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+
+	h := handlers.NewPromptHandler(models.NewMCPPromptModel(db))
+	r := newContractRouter()
+	prompts := r.Group("/api/gateway/prompts")
+	prompts.GET("", h.ListPrompts)
+	prompts.POST("", h.CreatePrompt)
+	prompts.GET("/:id", h.GetPrompt)
+	prompts.PUT("/:id", h.UpdatePrompt)
+	prompts.DELETE("/:id", h.DeletePrompt)
+	prompts.POST("/:id/use", h.UsePrompt)
+
+	base := "/api/gateway/prompts/" + fixturePromptID
+	prompt := map[string]interface{}{
+		"name": "triage", "prompt_template": "Summarize {{ticket}} and suggest a priority", "category": types.PromptCategoryBusiness,
+	}
+	r.run(t, "prompts", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/gateway/prompts"},
+		{name: "create", method: http.MethodPost, path: "/api/gateway/prompts", body: prompt},
+		{name: "create-conflict", method: http.MethodPost, path: "/api/gateway/prompts", body: prompt},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/gateway/prompts",
+			body: map[string]interface{}{"name": "triage", "prompt_template": "Summarize", "category": "poetry"}},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/gateway/prompts/" + missingID},
+		{name: "get-invalid", method: http.MethodGet, path: "/api/gateway/prompts/triage"},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"description": "Triage an escalated ticket"}},
+		{name: "use", method: http.MethodPost, path: base + "/use"},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "delete-not-found", method: http.MethodDelete, path: "/api/gateway/prompts/" + missingID},
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResourceContracts(t *testing.T) {
+	// Resources get their IDs from uuid.New
+	uuid.SetRand(rand.New(rand.NewSource(1)))
+	defer uuid.SetRand(nil)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	byID := `SELECT .+ FROM mcp_resources\s+WHERE id = \$1`
+	byName := `SELECT .+ FROM mcp_resources\s+WHERE organization_id = \$1 AND name = \$2`
+	mock.ExpectQuery(`SELECT .+ FROM mcp_resources\s+WHERE organization_id = \$1 AND is_active = true ORDER BY`).WillReturnRows(resourceRows())
+	mock.ExpectQuery(byName).WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(`INSERT INTO mcp_resources`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byName).WillReturnRows(resourceRows())
+	mock.ExpectQuery(byID).WillReturnRows(resourceRows())
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byID).WillReturnRows(resourceRows())
+	mock.ExpectExec(`UPDATE mcp_resources\s+SET name = \$2`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byID).WillReturnRows(resourceRows())
+	mock.ExpectExec(`UPDATE mcp_resources SET is_active = false WHERE id = \$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+
+	h := handlers.NewResourceHandler(models.NewMCPResourceModel(db))
+	r := newContractRouter()
+	resources := r.Group("/api/gateway/resources")
+	resources.GET("", h.ListResources)
+	resources.POST("", h.CreateResource)
+	resources.GET("/:id", h.GetResource)
+	resources.PUT("/:id", h.UpdateResource)
+	resources.DELETE("/:id", h.DeleteResource)
+
+	base := "/api/gateway/resources/" + fixtureResourceID
+	resource := map[string]interface{}{
+		"name": "runbook", "resource_type": types.ResourceTypeURL, "uri": "https://wiki.example.com/runbook", "mime_type": "text/html",
+	}
+	r.run(t, "resources", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/gateway/resources"},
+		{name: "create", method: http.MethodPost, path: "/api/gateway/resources", body: resource},
+		{name: "create-conflict", method: http.MethodPost, path: "/api/gateway/resources", body: resource},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/gateway/resources",
+			body: map[string]interface{}{"name": "runbook", "resource_type": "scroll", "uri": "https://wiki.example.com/runbook"}},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/gateway/resources/" + missingID},
+		{name: "get-invalid", method: http.MethodGet, path: "/api/gateway/resources/runbook"},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"description": "Primary on-call runbook"}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "delete-not-found", method: http.MethodDelete, path: "/api/gateway/resources/" + missingID},
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// packageCatalog is what the fixture package registry lists
+var packageCatalog = map[string]types.MCPPackage{
+	"github": {
+		Name: "GitHub", Description: "Repositories, issues and pull requests", GitHubURL: "https://github.com/example/mcp-github",
+		PackageRegistry: "npm", PackageName: "@example/mcp-github", Command: "npx", Args: []string{"-y", "@example/mcp-github"},
+		Envs: []string{"GITHUB_TOKEN"}, GitHubStars: 1200, PackageDownloadCount: 54000,
+	},
+	"postgres": {
+		Name: "Postgres", Description: "Read-only SQL against a Postgres database", GitHubURL: "https://github.com/example/mcp-postgres",
+		PackageRegistry: "npm", PackageName: "@example/mcp-postgres", Command: "npx", Args: []string{"-y", "@example/mcp-postgres"},
+		Envs: []string{"DATABASE_URL"}, GitHubStars: 800, PackageDownloadCount: 21000,
+	},
+}
+
+// packageRegistry serves packageCatalog, matching queries against package
+// keys
+func packageRegistry() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query().Get("query")
+		results := map[string]types.MCPPackage{}
+		for key, pkg := range packageCatalog {
+			if query == "" || strings.Contains(key, query) || strings.Contains(pkg.PackageName, query) {
+				results[key] = pkg
+			}
+		}
+		pageSize, _ := strconv.Atoi(req.URL.Query().Get("pageSize"))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(types.MCPDiscoveryResponse{Results: results, Total: len(results), PageSize: pageSize})
+	}))
+}
+
+func TestMCPPackageContracts(t *testing.T) {
+	registry := packageRegistry()
+	defer registry.Close()
+
+	h := handlers.NewMCPDiscoveryHandler(discovery.NewMCPDiscoveryService(registry.URL))
+	offline := handlers.NewMCPDiscoveryHandler(discovery.NewUnavailableMCPDiscoveryService(
+		types.NewConnectivityRequiredError(types.ConnectivityFeaturePackageDiscovery, "configure gateway.offline.mirrors.package_discovery to use a local mirror")))
+	r := newContractRouter()
+	r.GET("/api/mcp/search", h.SearchPackages)
+	r.GET("/api/mcp/packages", h.ListPackages)
+	r.GET("/api/mcp/packages/:packageName", h.GetPackageDetails)
+	r.GET("/offline/mcp/packages", offline.ListPackages)
+
+	r.run(t, "mcp-packages", []contractCase{
+		{name: "search", method: http.MethodGet, path: "/api/mcp/search", query: "query=postgres&pageSize=10"},
+		{name: "list", method: http.MethodGet, path: "/api/mcp/packages"},
+		{name: "get", method: http.MethodGet, path: "/api/mcp/packages/github"},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/mcp/packages/jira"},
+		{name: "list-offline", method: http.MethodGet, path: "/offline/mcp/packages"},
+	})
+}
+
+const fixtureVirtualServerID = "e6d5c4b3-a2f1-4e0d-9c8b-a7f6e5d4c3a6"
+
+var virtualServerColumns = []string{"id", "organization_id", "name", "description", "adapter_type", "tools",
+	"is_active", "metadata", "created_at", "updated_at"}
+
+// virtualServerRows returns the fixture virtual server, a Slack adapter, as
+// the virtual server model selects it
+func virtualServerRows() *sqlmock.Rows {
+	return sqlmock.NewRows(virtualServerColumns).
+		AddRow(fixtureVirtualServerID, uuid.Nil.String(), "slack", "Slack over REST", "REST",
+			[]byte(`[{"name":"list_channels","description":"List public channels","inputSchema":{"type":"object"},`+
+				`"REST":{"method":"GET","URLTemplate":"https://slack.com/api/conversations.list"}}]`),
+			true, []byte(`{}`), fixtureTime, fixtureLaterTime)
+}
+
+func TestVirtualServerContracts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// The service caches the servers it loads, so only the first lookup of
+	// the fixture server reaches the database
+	byID := `SELECT .+ FROM virtual_servers\s+WHERE id = \$1`
+	mock.ExpectQuery(byID).WillReturnRows(virtualServerRows())
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byID).WillReturnRows(virtualServerRows())
+	mock.ExpectQuery(`UPDATE virtual_servers\s+SET`).WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(fixtureLaterTime))
+	mock.ExpectQuery(`SELECT .+ FROM virtual_servers\s+WHERE organization_id = \$1\s+ORDER BY name ASC`).WillReturnRows(virtualServerRows())
+	mock.ExpectExec(`DELETE FROM virtual_servers WHERE id = \$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	service := virtual.NewService(db)
+	h := handlers.NewVirtualAdminHandler(service)
+	rpc := handlers.NewVirtualMCPHandler(service)
+	r := newContractRouter()
+	r.POST("/mcp/rpc", rpc.HandleMCPRPC)
+	servers := r.Group("/api/admin/virtual-servers")
+	servers.POST("", h.CreateVirtualServer)
+	servers.GET("", h.ListVirtualServers)
+	servers.GET("/:id", h.GetVirtualServer)
+	servers.PUT("/:id", h.UpdateVirtualServer)
+	servers.DELETE("/:id", h.DeleteVirtualServer)
+	servers.GET("/:id/tools", h.GetVirtualServerTools)
+	servers.POST("/:id/tools/:tool/test", h.TestVirtualServerTool)
+
+	base := "/api/admin/virtual-servers/" + fixtureVirtualServerID
+	rpcCall := func(method string, params map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params}
+	}
+	r.run(t, "virtual-servers", []contractCase{
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/virtual-servers/" + missingID},
+		{name: "tools", method: http.MethodGet, path: base + "/tools"},
+		{name: "test-tool", method: http.MethodPost, path: base + "/tools/list_channels/test", body: map[string]interface{}{}},
+		{name: "test-tool-unknown", method: http.MethodPost, path: base + "/tools/send_email/test"},
+		{name: "rpc-initialize", method: http.MethodPost, path: "/mcp/rpc",
+			body: rpcCall("initialize", map[string]interface{}{"protocolVersion": "2024-11-05", "server_id": fixtureVirtualServerID})},
+		{name: "rpc-tools-list", method: http.MethodPost, path: "/mcp/rpc",
+			body: rpcCall("tools/list", map[string]interface{}{"server_id": fixtureVirtualServerID})},
+		{name: "rpc-tools-call", method: http.MethodPost, path: "/mcp/rpc",
+			body: rpcCall("tools/call", map[string]interface{}{"server_id": fixtureVirtualServerID, "name": "list_channels"})},
+		{name: "rpc-unknown-method", method: http.MethodPost, path: "/mcp/rpc", body: rpcCall("prompts/list", nil)},
+		{name: "rpc-invalid-version", method: http.MethodPost, path: "/mcp/rpc",
+			body: map[string]interface{}{"jsonrpc": "1.0", "id": 1, "method": "tools/list"}},
+		{name: "rpc-parse-error", method: http.MethodPost, path: "/mcp/rpc", body: `{"jsonrpc":`},
+		{name: "update", method: http.MethodPut, path: base,
+			body: map[string]interface{}{"name": "slack", "description": "Slack workspace over REST", "adapterType": "REST"}},
+		{name: "update-invalid", method: http.MethodPut, path: base, body: map[string]interface{}{"description": "Slack"}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/virtual-servers", body: map[string]interface{}{"name": "slack"}},
+		{name: "list", method: http.MethodGet, path: "/api/admin/virtual-servers"},
+		{name: "delete", method: http.MethodDelete, path: base},
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+const fixtureAgentID = "f7e6d5c4-b3a2-4f1e-8d9c-b8a7f6e5d4b7"
+
+var agentColumns = []string{"id", "organization_id", "name", "description", "endpoint_url", "agent_type",
+	"protocol_version", "capabilities", "config", "auth_type", "auth_value",
+	"is_active", "tags", "metadata", "last_health_check", "health_status",
+	"health_error", "created_at", "updated_at"}
+
+// agentRows returns the fixture agent, an OpenAI agent offering tools, as
+// the agent model selects it
+func agentRows() *sqlmock.Rows {
+	return sqlmock.NewRows(agentColumns).
+		AddRow(fixtureAgentID, uuid.Nil.String(), "support-assistant", "Answers support questions", "https://agents.example.com/support",
+			string(types.AgentTypeOpenAI), "1.0", []byte(`{"tools":true}`), []byte(`{"model":"gpt-4o"}`), string(types.AuthTypeNone), nil,
+			true, []byte(`{support}`), []byte(`{}`), fixtureLaterTime, string(types.A2AHealthStatusHealthy),
+			nil, fixtureTime, fixtureLaterTime)
+}
+
+func TestA2AAgentContracts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Listing caches the agents, so the lookups after it are answered from
+	// the cache
+	list := `SELECT .+ FROM a2a_agents\s+WHERE organization_id = \$1 ORDER BY name ASC`
+	byID := `SELECT .+ FROM a2a_agents\s+WHERE id = \$1`
+	mock.ExpectQuery(list).WillReturnRows(agentRows())
+	mock.ExpectQuery(list).WillReturnRows(agentRows())
+	mock.ExpectQuery(`SELECT .+ FROM a2a_agents\s+WHERE organization_id = \$1 AND is_active = \$2 ORDER BY name ASC`).
+		WithArgs(uuid.Nil.String(), true).WillReturnRows(agentRows())
+	mock.ExpectQuery(`SELECT .+ FROM a2a_agent_cards\s+WHERE agent_id = \$1`).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`UPDATE a2a_agents\s+SET is_active = \$2`).WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(fixtureLaterTime))
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(byID).WillReturnRows(agentRows())
+	mock.ExpectExec(`DELETE FROM a2a_agent_tools WHERE agent_id = \$1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM a2a_agents WHERE id = \$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	service := a2a.NewService(db)
+	client := a2a.NewClient(time.Second, 0)
+	h := handlers.NewA2AHandler(service, client, a2a.NewAdapter(service, client))
+	r := newContractRouter()
+	agents := r.Group("/api/a2a")
+	agents.GET("", h.ListAgents)
+	agents.POST("", h.RegisterAgent)
+	agents.GET("/stats", h.GetAgentStats)
+	agents.GET("/:id", h.GetAgent)
+	agents.PUT("/:id", h.UpdateAgent)
+	agents.DELETE("/:id", h.DeleteAgent)
+	agents.POST("/:id/toggle", h.ToggleAgent)
+	agents.GET("/:id/health", h.HealthCheckAgent)
+	agents.POST("/:id/card/refresh", h.RefreshAgentCard)
+	agents.POST("/:id/test", h.TestAgent)
+	agents.GET("/:id/tools", h.GetAgentTools)
+	agents.POST("/:id/invoke", h.InvokeAgent)
+	agents.POST("/:id/chat", h.ChatWithAgent)
+
+	base := "/api/a2a/" + fixtureAgentID
+	missing := "/api/a2a/" + missingID
+	r.run(t, "a2a", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/a2a"},
+		{name: "stats", method: http.MethodGet, path: "/api/a2a/stats"},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: missing},
+		{name: "get-invalid", method: http.MethodGet, path: "/api/a2a/support-assistant"},
+		{name: "tools", method: http.MethodGet, path: base + "/tools"},
+		{name: "refresh-card-unsupported", method: http.MethodPost, path: base + "/card/refresh"},
+		{name: "health-not-found", method: http.MethodGet, path: missing + "/health"},
+		{name: "test-not-found", method: http.MethodPost, path: missing + "/test"},
+		{name: "tools-not-found", method: http.MethodGet, path: missing + "/tools"},
+		{name: "toggle", method: http.MethodPost, path: base + "/toggle", body: map[string]interface{}{"is_active": true}},
+		{name: "toggle-invalid", method: http.MethodPost, path: base + "/toggle", body: map[string]interface{}{}},
+		{name: "update-not-found", method: http.MethodPut, path: missing,
+			body: map[string]interface{}{"name": "support-assistant", "endpoint_url": "https://agents.example.com/support"}},
+		{name: "register-invalid", method: http.MethodPost, path: "/api/a2a", body: map[string]interface{}{"name": "support-assistant"}},
+		{name: "invoke", method: http.MethodPost, path: base + "/invoke", body: map[string]interface{}{}},
+		{name: "chat", method: http.MethodPost, path: base + "/chat", body: map[string]interface{}{}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "delete-invalid", method: http.MethodDelete, path: "/api/a2a/support-assistant"},
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+const fixtureFilterID = "a8f7e6d5-c4b3-4a2f-9e1d-c9b8a7f6e5c8"
+
+var filterColumns = []string{"id", "organization_id", "name", "description", "type", "enabled", "priority",
+	"config", "created_at", "updated_at", "created_by"}
+
+// filterRows returns the fixture PII filter as the filters handler selects it
+func filterRows() *sqlmock.Rows {
+	return sqlmock.NewRows(filterColumns).
+		AddRow(fixtureFilterID, fixtureOrgID, "mask-pii", "Redact emails and card numbers", string(plugins.PluginTypePII), true, 10,
+			[]byte(`{"action":"warn","masking_strategy":"redact"}`), fixtureTime, fixtureLaterTime, fixtureUserID)
+}
+
+// stubFilterPlugins offers only the PII filter and reports one violation.
+// The filters handler does not use the rest of the plugin service.
+type stubFilterPlugins struct {
+	plugins.PluginService
+	registry plugins.PluginRegistry
+}
+
+func newStubFilterPlugins(t *testing.T) stubFilterPlugins {
+	registry := plugins.NewPluginRegistry()
+	require.NoError(t, registry.Register(&pii.PIIFilterFactory{}))
+	return stubFilterPlugins{registry: registry}
+}
+
+func (s stubFilterPlugins) GetRegistry() plugins.PluginRegistry {
+	return s.registry
+}
+
+func (stubFilterPlugins) CheckEgress(pluginType plugins.PluginType, config map[string]interface{}) error {
+	return nil
+}
+
+func (stubFilterPlugins) ReloadOrganizationPlugins(ctx context.Context, organizationID string) error {
+	return nil
+}
+
+func (stubFilterPlugins) GetViolations(ctx context.Context, organizationID string, limit, offset int) ([]interface{}, error) {
+	return []interface{}{&models.FilterViolation{
+		CreatedAt: fixtureLaterTime, ID: "b9a8f7e6-d5c4-4b3a-8f2e-d0c9b8a7f6d9", OrganizationID: organizationID, FilterID: fixtureFilterID,
+		ViolationType: "email", ActionTaken: "warn", Severity: "medium",
+	}}, nil
+}
+
+func (stubFilterPlugins) GetMetrics() (*types.FilteringMetrics, error) {
+	return &types.FilteringMetrics{
+		LastReset:        fixtureTime,
+		FilterStats:      map[string]*types.FilterStat{},
+		ViolationsByType: map[string]int64{"email": 3},
+		TotalRequests:    120,
+		TotalModified:    3,
+	}, nil
+}
+
+func TestFilterContracts(t *testing.T) {
+	// Filters get their IDs from uuid.New
+	uuid.SetRand(rand.New(rand.NewSource(1)))
+	defer uuid.SetRand(nil)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	byID := `SELECT .+ FROM content_filters\s+WHERE id = \$1 AND organization_id = \$2`
+	mock.ExpectQuery(`SELECT .+ FROM content_filters\s+WHERE organization_id = \$1\s+ORDER BY priority ASC`).
+		WithArgs(fixtureOrgID, 50, 0).WillReturnRows(filterRows())
+	mock.ExpectQuery(`INSERT INTO content_filters`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(fixtureFilterID, fixtureTime, fixtureTime))
+	mock.ExpectQuery(byID).WillReturnRows(filterRows())
+	mock.ExpectQuery(byID).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`SELECT id, type FROM content_filters`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).AddRow(fixtureFilterID, string(plugins.PluginTypePII)))
+	mock.ExpectExec(`UPDATE content_filters SET priority = \$1, enabled = \$2, updated_at = NOW\(\) WHERE id = \$3 AND organization_id = \$4`).
+		WithArgs(20, false, fixtureFilterID, fixtureOrgID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id, type FROM content_filters`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).AddRow(fixtureFilterID, string(plugins.PluginTypePII)))
+	mock.ExpectQuery(`SELECT id FROM content_filters`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(fixtureFilterID))
+	mock.ExpectExec(`DELETE FROM content_filters`).WithArgs(fixtureFilterID, fixtureOrgID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id FROM content_filters`).WillReturnError(sql.ErrNoRows)
+
+	h := handlers.NewFiltersHandler(db, newStubFilterPlugins(t))
+	r := newContractRouter()
+	filters := r.Group("/api/admin/filters")
+	filters.GET("", h.ListFilters)
+	filters.POST("", h.CreateFilter)
+	filters.GET("/types", h.GetFilterTypes)
+	filters.GET("/violations", h.GetFilterViolations)
+	filters.GET("/metrics", h.GetFilterMetrics)
+	filters.GET("/:id", h.GetFilter)
+	filters.PUT("/:id", h.UpdateFilter)
+	filters.DELETE("/:id", h.DeleteFilter)
+
+	base := "/api/admin/filters/" + fixtureFilterID
+	r.run(t, "filters", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/filters"},
+		{name: "create", method: http.MethodPost, path: "/api/admin/filters",
+			body: map[string]interface{}{"name": "mask-pii", "type": "pii", "priority": 10, "enabled": true,
+				"config": map[string]interface{}{"action": "warn", "masking_strategy": "redact"}}},
+		{name: "create-invalid-type", method: http.MethodPost, path: "/api/admin/filters",
+			body: map[string]interface{}{"name": "toxicity", "type": "llamaguard", "priority": 10, "config": map[string]interface{}{}}},
+		{name: "create-invalid-config", method: http.MethodPost, path: "/api/admin/filters",
+			body: map[string]interface{}{"name": "mask-pii", "type": "pii", "priority": 10, "config": map[string]interface{}{"action": "shred"}}},
+		{name: "types", method: http.MethodGet, path: "/api/admin/filters/types"},
+		{name: "violations", method: http.MethodGet, path: "/api/admin/filters/violations", query: "limit=10"},
+		{name: "metrics", method: http.MethodGet, path: "/api/admin/filters/metrics"},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/filters/" + missingID},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"priority": 20, "enabled": false}},
+		{name: "update-invalid-config", method: http.MethodPut, path: base,
+			body: map[string]interface{}{"config": map[string]interface{}{"masking_strategy": "shred"}}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "delete-not-found", method: http.MethodDelete, path: "/api/admin/filters/" + missingID},
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
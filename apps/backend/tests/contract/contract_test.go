@@ -0,0 +1,180 @@
+// Package contract snapshots the JSON responses of the management API
+// against golden files under testdata, so that a change to the shape of a
+// response shows up as a diff before it is released. Handlers run against
+// stub services returning fixed fixtures, so responses never depend on the
+// clock, random IDs or a database.
+//
+// After an intended change, rewrite the golden files with
+//
+//	go test ./tests/contract -update
+//
+// and review the diff like any other change.
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current responses")
+
+// TestMain runs the suites and, when all of them ran, fails if a route of
+// the gateway was not reached by any contract case
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	code := m.Run()
+	if code == 0 && flag.Lookup("test.run").Value.String() == "" {
+		missing, err := uncoveredRoutes()
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "failed to list the gateway's routes: %v\n", err)
+			code = 1
+		case len(missing) > 0:
+			fmt.Fprintf(os.Stderr, "no contract case reaches %d routes of the gateway:\n", len(missing))
+			for _, route := range missing {
+				fmt.Fprintf(os.Stderr, "\t%s\n", route)
+			}
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// contractCase is one request whose response is snapshotted as
+// testdata/<suite>/<name>.json
+type contractCase struct {
+	body   interface{}
+	name   string
+	method string
+	path   string
+	// query is appended to path after a ?
+	query string
+	// headers are set on the request
+	headers map[string]string
+}
+
+// anyMethods are the methods gin registers a route added with Any for
+var anyMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodHead,
+	http.MethodOptions, http.MethodDelete, http.MethodConnect, http.MethodTrace,
+}
+
+// contractRouter is a gin engine set up like the gateway's authenticated API
+// groups, which records the routes its requests reach
+type contractRouter struct {
+	*gin.Engine
+	served map[string]bool
+}
+
+// newContractRouter creates a router whose requests are made by
+// fixtureUserID, an admin of fixtureOrgID
+func newContractRouter() *contractRouter {
+	r := &contractRouter{Engine: gin.New(), served: make(map[string]bool)}
+	r.Use(func(c *gin.Context) {
+		c.Set("organization_id", fixtureOrgID)
+		c.Set("user_id", fixtureUserID)
+		c.Set("role", "admin")
+		c.Next()
+		r.served[c.Request.Method+" "+c.FullPath()] = true
+		served[c.Request.Method+" "+c.FullPath()] = true
+	})
+	return r
+}
+
+// run sends every case through the router and compares the responses with
+// their golden files. Every route of the router needs a case, so that new
+// endpoints get a contract too.
+func (r *contractRouter) run(t *testing.T, suite string, cases []contractCase) {
+	t.Helper()
+
+	names := make(map[string]bool, len(cases))
+	for _, tc := range cases {
+		require.False(t, names[tc.name], "duplicate case %s", tc.name)
+		names[tc.name] = true
+
+		t.Run(tc.name, func(t *testing.T) {
+			assertGolden(t, filepath.Join("testdata", suite, tc.name+".json"), r.serve(t, tc))
+		})
+	}
+
+	for _, route := range r.Routes() {
+		assert.True(t, r.served[route.Method+" "+route.Path], "no contract case reaches %s %s", route.Method, route.Path)
+	}
+}
+
+func (r *contractRouter) serve(t *testing.T, tc contractCase) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body *bytes.Reader
+	switch b := tc.body.(type) {
+	case nil:
+		body = bytes.NewReader(nil)
+	case string:
+		body = bytes.NewReader([]byte(b))
+	default:
+		encoded, err := json.Marshal(b)
+		require.NoError(t, err)
+		body = bytes.NewReader(encoded)
+	}
+
+	target := tc.path
+	if tc.query != "" {
+		target += "?" + tc.query
+	}
+	req := httptest.NewRequest(tc.method, target, body)
+	if tc.body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range tc.headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+// snapshot is what a golden file holds: the status and the body, indented
+// with sorted keys so that diffs stay readable
+type snapshot struct {
+	Body   interface{} `json:"body"`
+	Status int         `json:"status"`
+}
+
+func assertGolden(t *testing.T, path string, rec *httptest.ResponseRecorder) {
+	t.Helper()
+
+	got := snapshot{Status: rec.Code}
+	if raw := bytes.TrimSpace(rec.Body.Bytes()); len(raw) > 0 {
+		if err := json.Unmarshal(raw, &got.Body); err != nil {
+			got.Body = string(raw)
+		}
+	}
+	encoded, err := json.MarshalIndent(got, "", "  ")
+	require.NoError(t, err)
+	encoded = append(encoded, '\n')
+
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, encoded, 0o644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("missing golden file %s; run go test ./tests/contract -update to create it", path)
+	}
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(encoded),
+		"response differs from %s; if the change is intended, run go test ./tests/contract -update", path)
+}
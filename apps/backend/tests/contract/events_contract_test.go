@@ -0,0 +1,410 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	fixtureWebhookID      = "4d3c2b1a-0f9e-4d8c-8b7a-6f5e4d3c2b1a"
+	fixtureDeliveryID     = "e4d3c2b1-a0f9-4e8d-9c7b-6a5f4e3d2c1b"
+	fixtureApprovalID     = "b1a0f9e8-d7c6-4b5a-8f4e-3d2c1b0a9f8e"
+	fixtureNotificationID = "c6b5a4f3-e2d1-4c0b-9a8f-7e6d5c4b3a2f"
+	fixtureIncidentID     = "f3e2d1c0-b9a8-4f7e-8d6c-5b4a3f2e1d0c"
+)
+
+func fixtureWebhook() *types.Webhook {
+	return &types.Webhook{
+		CreatedAt:      fixtureTime,
+		UpdatedAt:      fixtureLaterTime,
+		ID:             fixtureWebhookID,
+		OrganizationID: fixtureOrgID,
+		Name:           "ops-alerts",
+		URL:            "https://hooks.example.com/omnimesh",
+		CreatedBy:      fixtureUserID,
+		EventTypes:     []string{types.WebhookEventServerUnhealthy, types.WebhookEventBreakGlassActivated},
+		IsActive:       true,
+	}
+}
+
+func fixtureWebhookDelivery() *types.WebhookDelivery {
+	return &types.WebhookDelivery{
+		CreatedAt:      fixtureTime,
+		LastAttemptAt:  &fixtureLaterTime,
+		DeliveredAt:    &fixtureLaterTime,
+		Payload:        json.RawMessage(`{"type":"server.unhealthy","data":{"server_id":"` + fixtureServerID + `"}}`),
+		ID:             fixtureDeliveryID,
+		WebhookID:      fixtureWebhookID,
+		OrganizationID: fixtureOrgID,
+		EventID:        "evt_01",
+		EventType:      types.WebhookEventServerUnhealthy,
+		Status:         types.WebhookDeliverySucceeded,
+		Attempts:       1,
+		ResponseStatus: http.StatusOK,
+		DurationMs:     84,
+	}
+}
+
+// stubWebhooks serves the fixture webhook and its delivery
+type stubWebhooks struct{}
+
+func (stubWebhooks) List(ctx context.Context, orgID string) ([]*types.Webhook, error) {
+	return []*types.Webhook{fixtureWebhook()}, nil
+}
+
+func (stubWebhooks) Get(ctx context.Context, orgID, id string) (*types.Webhook, error) {
+	if id == missingID {
+		return nil, notFound("Webhook")
+	}
+	return fixtureWebhook(), nil
+}
+
+func (stubWebhooks) Create(ctx context.Context, orgID, userID string, req *types.CreateWebhookRequest) (*types.Webhook, error) {
+	if req.URL == "http://169.254.169.254/latest" {
+		return nil, types.NewValidationError("webhook URL must be a public host")
+	}
+	webhook := fixtureWebhook()
+	webhook.Name = req.Name
+	webhook.URL = req.URL
+	webhook.EventTypes = req.EventTypes
+	webhook.Secret = "whsec_fixture"
+	webhook.SigningKeyID = "whk_01"
+	return webhook, nil
+}
+
+func (stubWebhooks) Update(ctx context.Context, orgID, id string, req *types.UpdateWebhookRequest) (*types.Webhook, error) {
+	if id == missingID {
+		return nil, notFound("Webhook")
+	}
+	webhook := fixtureWebhook()
+	if req.IsActive != nil {
+		webhook.IsActive = *req.IsActive
+	}
+	return webhook, nil
+}
+
+func (stubWebhooks) Delete(ctx context.Context, orgID, id string) error {
+	if id == missingID {
+		return notFound("Webhook")
+	}
+	return nil
+}
+
+func (stubWebhooks) RotateSecret(ctx context.Context, orgID, id string) (*types.Webhook, error) {
+	webhook := fixtureWebhook()
+	webhook.Secret = "whsec_rotated"
+	webhook.SigningKeyID = "whk_02"
+	return webhook, nil
+}
+
+func (stubWebhooks) Test(ctx context.Context, orgID, id string) (*types.WebhookDelivery, error) {
+	if id == missingID {
+		return nil, notFound("Webhook")
+	}
+	delivery := fixtureWebhookDelivery()
+	delivery.EventType = types.WebhookEventPing
+	delivery.Status = types.WebhookDeliveryPending
+	delivery.Payload = json.RawMessage(`{"type":"webhook.ping","data":{}}`)
+	delivery.NextAttemptAt = &fixtureLaterTime
+	delivery.LastAttemptAt, delivery.DeliveredAt = nil, nil
+	delivery.Attempts, delivery.ResponseStatus, delivery.DurationMs = 0, 0, 0
+	return delivery, nil
+}
+
+func (stubWebhooks) ListDeliveries(ctx context.Context, orgID string, filter *types.WebhookDeliveryFilter) ([]*types.WebhookDelivery, error) {
+	failed := fixtureWebhookDelivery()
+	failed.ID = fixtureApprovalID
+	failed.Status = types.WebhookDeliveryFailed
+	failed.DeliveredAt = nil
+	failed.NextAttemptAt = &fixtureLaterTime
+	failed.LastError = "webhook returned HTTP 503"
+	failed.ResponseStatus = http.StatusServiceUnavailable
+	failed.Attempts = 3
+	return []*types.WebhookDelivery{fixtureWebhookDelivery(), failed}, nil
+}
+
+func (stubWebhooks) Redeliver(ctx context.Context, orgID, webhookID, deliveryID string) (*types.WebhookDelivery, error) {
+	if deliveryID == missingID {
+		return nil, notFound("Webhook delivery")
+	}
+	delivery := fixtureWebhookDelivery()
+	delivery.Status = types.WebhookDeliveryPending
+	delivery.NextAttemptAt = &fixtureLaterTime
+	return delivery, nil
+}
+
+func TestWebhookContracts(t *testing.T) {
+	h := handlers.NewWebhookHandler(stubWebhooks{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/webhooks", h.ListWebhooks)
+	admin.POST("/webhooks", h.CreateWebhook)
+	admin.GET("/webhooks/:id", h.GetWebhook)
+	admin.PUT("/webhooks/:id", h.UpdateWebhook)
+	admin.DELETE("/webhooks/:id", h.DeleteWebhook)
+	admin.POST("/webhooks/:id/rotate-secret", h.RotateSecret)
+	admin.POST("/webhooks/:id/test", h.TestWebhook)
+	admin.GET("/webhooks/:id/deliveries", h.ListDeliveries)
+	admin.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", h.Redeliver)
+
+	base := "/api/admin/webhooks/" + fixtureWebhookID
+	r.run(t, "webhooks", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/webhooks"},
+		{name: "create", method: http.MethodPost, path: "/api/admin/webhooks",
+			body: map[string]interface{}{"name": "ops-alerts", "url": "https://hooks.example.com/omnimesh",
+				"event_types": []string{types.WebhookEventServerUnhealthy}}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/webhooks",
+			body: map[string]interface{}{"name": "ops-alerts", "url": "not a url"}},
+		{name: "create-internal-url", method: http.MethodPost, path: "/api/admin/webhooks",
+			body: map[string]interface{}{"name": "metadata", "url": "http://169.254.169.254/latest",
+				"event_types": []string{types.WebhookEventAll}}},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/webhooks/" + missingID},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"is_active": false}},
+		{name: "update-not-found", method: http.MethodPut, path: "/api/admin/webhooks/" + missingID, body: map[string]interface{}{}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "rotate-secret", method: http.MethodPost, path: base + "/rotate-secret"},
+		{name: "test", method: http.MethodPost, path: base + "/test"},
+		{name: "deliveries", method: http.MethodGet, path: base + "/deliveries", query: "status=failed&limit=20"},
+		{name: "redeliver", method: http.MethodPost, path: base + "/deliveries/" + fixtureDeliveryID + "/redeliver"},
+		{name: "redeliver-not-found", method: http.MethodPost, path: base + "/deliveries/" + missingID + "/redeliver"},
+	})
+}
+
+func fixtureApproval() *types.ToolApproval {
+	return &types.ToolApproval{
+		CreatedAt:      fixtureTime,
+		ExpiresAt:      fixtureTime.Add(time.Hour),
+		Arguments:      map[string]interface{}{"ticket_id": 4521, "refund": 120},
+		ID:             fixtureApprovalID,
+		OrganizationID: fixtureOrgID,
+		NamespaceID:    fixtureNamespaceID,
+		ServerID:       fixtureServerID,
+		Tool:           "tickets__issue_refund",
+		PolicyID:       fixtureRuleID,
+		PolicyName:     "refunds need approval",
+		Message:        "Refunds over 100 need a second pair of eyes",
+		RequestedBy:    "api_key:" + fixtureSinkID,
+		Status:         "pending",
+	}
+}
+
+// stubApprovals serves the fixture approval
+type stubApprovals struct{}
+
+func (stubApprovals) List(ctx context.Context, orgID, status string, limit, offset int) ([]*types.ToolApproval, error) {
+	return []*types.ToolApproval{fixtureApproval()}, nil
+}
+
+func (stubApprovals) Get(ctx context.Context, orgID, id string) (*types.ToolApproval, error) {
+	if id == missingID {
+		return nil, notFound("Approval")
+	}
+	return fixtureApproval(), nil
+}
+
+func (stubApprovals) Decide(ctx context.Context, orgID, id string, approve bool, decidedBy, reason string) (*types.ToolApproval, error) {
+	if id == missingID {
+		return nil, notFound("Approval")
+	}
+	approval := fixtureApproval()
+	approval.Status = "rejected"
+	if approve {
+		approval.Status = "approved"
+	}
+	approval.DecidedAt = &fixtureLaterTime
+	approval.DecidedBy = decidedBy
+	approval.Reason = reason
+	return approval, nil
+}
+
+func TestApprovalContracts(t *testing.T) {
+	h := handlers.NewApprovalHandler(stubApprovals{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/approvals", h.ListApprovals)
+	admin.GET("/approvals/:id", h.GetApproval)
+	admin.POST("/approvals/:id/approve", h.ApproveApproval)
+	admin.POST("/approvals/:id/reject", h.RejectApproval)
+
+	base := "/api/admin/approvals/" + fixtureApprovalID
+	r.run(t, "approvals", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/approvals", query: "status=pending"},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/approvals/" + missingID},
+		{name: "approve", method: http.MethodPost, path: base + "/approve"},
+		{name: "reject", method: http.MethodPost, path: base + "/reject", body: map[string]interface{}{"reason": "customer already refunded"}},
+		{name: "reject-malformed-json", method: http.MethodPost, path: base + "/reject", body: `{"reason":`},
+		{name: "approve-not-found", method: http.MethodPost, path: "/api/admin/approvals/" + missingID + "/approve"},
+	})
+}
+
+func fixtureNotification() *types.Notification {
+	return &types.Notification{
+		CreatedAt:      fixtureTime,
+		Data:           map[string]interface{}{"server_name": "tickets"},
+		ID:             fixtureNotificationID,
+		OrganizationID: fixtureOrgID,
+		UserID:         fixtureUserID,
+		Type:           types.NotificationServerUnhealthy,
+		Severity:       "warning",
+		Title:          "Server tickets is unhealthy",
+		Message:        "Health checks have failed for 5 minutes",
+		ResourceType:   "server",
+		ResourceID:     fixtureServerID,
+	}
+}
+
+// stubNotifications serves the fixture notification
+type stubNotifications struct{}
+
+func (stubNotifications) List(ctx context.Context, filter *types.NotificationListFilter) (*types.NotificationListResponse, error) {
+	return &types.NotificationListResponse{
+		Notifications: []*types.Notification{fixtureNotification()},
+		UnreadCount:   1,
+		Limit:         filter.Limit,
+		Offset:        filter.Offset,
+	}, nil
+}
+
+func (stubNotifications) UnreadCount(ctx context.Context, userID string) (int, error) {
+	return 1, nil
+}
+
+func (stubNotifications) MarkRead(ctx context.Context, userID, id string) error {
+	if id == missingID {
+		return notFound("Notification")
+	}
+	return nil
+}
+
+func (stubNotifications) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	return 3, nil
+}
+
+func (stubNotifications) GetPreferences(ctx context.Context, userID string) (*types.NotificationPreferences, error) {
+	return &types.NotificationPreferences{LastDigestAt: &fixtureTime, UserID: userID, EmailDigest: true}, nil
+}
+
+func (stubNotifications) SetPreferences(ctx context.Context, userID string, req *types.UpdateNotificationPreferencesRequest) (*types.NotificationPreferences, error) {
+	return &types.NotificationPreferences{UserID: userID, EmailDigest: req.EmailDigest}, nil
+}
+
+func TestNotificationContracts(t *testing.T) {
+	h := handlers.NewNotificationHandler(stubNotifications{})
+	r := newContractRouter()
+	notifications := r.Group("/api/notifications")
+	notifications.GET("", h.List)
+	notifications.GET("/unread-count", h.UnreadCount)
+	notifications.POST("/:id/read", h.MarkRead)
+	notifications.POST("/read-all", h.MarkAllRead)
+	notifications.GET("/preferences", h.GetPreferences)
+	notifications.PUT("/preferences", h.UpdatePreferences)
+
+	r.run(t, "notifications", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/notifications", query: "unread=true&limit=10"},
+		{name: "unread-count", method: http.MethodGet, path: "/api/notifications/unread-count"},
+		{name: "mark-read", method: http.MethodPost, path: "/api/notifications/" + fixtureNotificationID + "/read"},
+		{name: "mark-read-not-found", method: http.MethodPost, path: "/api/notifications/" + missingID + "/read"},
+		{name: "mark-all-read", method: http.MethodPost, path: "/api/notifications/read-all"},
+		{name: "get-preferences", method: http.MethodGet, path: "/api/notifications/preferences"},
+		{name: "update-preferences", method: http.MethodPut, path: "/api/notifications/preferences",
+			body: map[string]interface{}{"email_digest": false}},
+		{name: "update-preferences-malformed-json", method: http.MethodPut, path: "/api/notifications/preferences", body: `{"email_digest":`},
+	})
+}
+
+func fixtureIncident() *types.Incident {
+	return &types.Incident{
+		StartedAt:      fixtureTime,
+		CreatedAt:      fixtureTime,
+		UpdatedAt:      fixtureLaterTime,
+		ID:             fixtureIncidentID,
+		OrganizationID: fixtureOrgID,
+		Title:          "Ticket search degraded",
+		Severity:       types.IncidentSeverityMajor,
+		Notes:          "Upstream search index rebuilding",
+		CreatedBy:      fixtureUserID,
+		ServerIDs:      []string{fixtureServerID},
+		NamespaceIDs:   []string{},
+	}
+}
+
+// stubIncidents serves the fixture incident and health history
+type stubIncidents struct{}
+
+func (stubIncidents) Create(ctx context.Context, orgID, createdBy string, req *types.CreateIncidentRequest) (*types.Incident, error) {
+	incident := fixtureIncident()
+	incident.Title = req.Title
+	incident.Severity = req.Severity
+	return incident, nil
+}
+
+func (stubIncidents) Get(ctx context.Context, orgID, id string) (*types.Incident, error) {
+	if id == missingID {
+		return nil, notFound("Incident")
+	}
+	return fixtureIncident(), nil
+}
+
+func (stubIncidents) List(ctx context.Context, filter *types.IncidentFilter) ([]*types.Incident, error) {
+	return []*types.Incident{fixtureIncident()}, nil
+}
+
+func (stubIncidents) Update(ctx context.Context, orgID, id string, req *types.UpdateIncidentRequest) (*types.Incident, error) {
+	if id == missingID {
+		return nil, notFound("Incident")
+	}
+	incident := fixtureIncident()
+	incident.EndedAt = req.EndedAt
+	return incident, nil
+}
+
+func (stubIncidents) Delete(ctx context.Context, orgID, id string) error {
+	if id == missingID {
+		return notFound("Incident")
+	}
+	return nil
+}
+
+func (stubIncidents) GetHealthHistory(serverID string, limit int) ([]*types.HealthCheck, error) {
+	return []*types.HealthCheck{
+		{CheckedAt: fixtureLaterTime, ID: fixtureDeliveryID, ServerID: serverID, Status: "unhealthy", Error: "connection refused", Latency: 5000},
+		{CheckedAt: fixtureTime, ID: fixtureWebhookID, ServerID: serverID, Status: "healthy", Response: "ok", Latency: 42},
+	}, nil
+}
+
+func TestIncidentContracts(t *testing.T) {
+	h := handlers.NewIncidentHandler(stubIncidents{}, stubIncidents{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/incidents", h.ListIncidents)
+	admin.POST("/incidents", h.CreateIncident)
+	admin.GET("/incidents/:id", h.GetIncident)
+	admin.PUT("/incidents/:id", h.UpdateIncident)
+	admin.DELETE("/incidents/:id", h.DeleteIncident)
+	r.GET("/api/gateway/servers/:id/health-history", h.GetServerHealthHistory)
+
+	base := "/api/admin/incidents/" + fixtureIncidentID
+	r.run(t, "incidents", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/incidents", query: "ongoing=true"},
+		{name: "list-invalid-from", method: http.MethodGet, path: "/api/admin/incidents", query: "from=yesterday"},
+		{name: "create", method: http.MethodPost, path: "/api/admin/incidents",
+			body: map[string]interface{}{"title": "Ticket search degraded", "severity": "major", "server_ids": []string{fixtureServerID}}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/incidents",
+			body: map[string]interface{}{"title": "Ticket search degraded", "severity": "catastrophic"}},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/incidents/" + missingID},
+		{name: "resolve", method: http.MethodPut, path: base, body: map[string]interface{}{"ended_at": fixtureLaterTime}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "delete-not-found", method: http.MethodDelete, path: "/api/admin/incidents/" + missingID},
+		{name: "health-history", method: http.MethodGet, path: "/api/gateway/servers/" + fixtureServerID + "/health-history", query: "limit=2"},
+		{name: "health-history-invalid-limit", method: http.MethodGet, path: "/api/gateway/servers/" + fixtureServerID + "/health-history", query: "limit=0"},
+	})
+}
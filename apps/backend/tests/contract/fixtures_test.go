@@ -0,0 +1,29 @@
+package contract
+
+import (
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// Identifiers and times shared by the fixtures. Stubs answer requests for
+// missingID with a not found error.
+const (
+	fixtureOrgID       = "7a0e5c1e-2f4b-4d8a-9c61-3b5e8f0d2a11"
+	fixtureUserID      = "0f6d2b9a-8e3c-4a71-b5d4-6c2e1f9a7b32"
+	fixtureNamespaceID = "5d3c9e7f-1a2b-4c8d-8e9f-0a1b2c3d4e53"
+	fixtureServerID    = "c2b1a0f9-e8d7-4c6b-9a5f-4e3d2c1b0a64"
+	fixtureServer2ID   = "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c75"
+	missingID          = "00000000-0000-0000-0000-000000000404"
+)
+
+var (
+	fixtureTime      = time.Date(2026, time.March, 2, 9, 30, 0, 0, time.UTC)
+	fixtureLaterTime = time.Date(2026, time.March, 9, 17, 45, 0, 0, time.UTC)
+	fixtureUser      = fixtureUserID
+)
+
+// notFound is the error services return for unknown IDs
+func notFound(resource string) error {
+	return types.NewNotFoundError(resource + " not found")
+}
@@ -0,0 +1,882 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixtureSSOProviderID       = "2f1e0d9c-8b7a-4e6d-9c5b-4a3f2e1d0cb1"
+	fixtureServiceAccountID    = "b8a7f6e5-d4c3-4b2a-8f1e-0d9c8b7a6fc2"
+	fixtureOAuthClientID       = "mcp_client_3f9a2b"
+	fixtureRoleGrantID         = "4c3b2a1f-0e9d-4c8b-9a7f-6e5d4c3b2ad3"
+	fixtureBreakGlassID        = "7e6d5c4b-3a2f-4e1d-8c0b-9a8f7e6d5ce4"
+	fixtureBreakGlassSessionID = "a1f0e9d8-c7b6-4a5f-9e4d-3c2b1a0f9ef5"
+	fixtureCompromisedID       = "d5c4b3a2-f1e0-4d9c-8b7a-6f5e4d3c2b06"
+	fixtureSigningKeyID        = "e9d8c7b6-a5f4-4e3d-9c2b-1a0f9e8d7c17"
+	fixtureAccessTokenID       = "f3e2d1c0-b9a8-4f7e-8d6c-5b4a3f2e1d28"
+	fixtureA2AAgentID          = "1b0a9f8e-7d6c-4b5a-9f4e-3d2c1b0a9f39"
+)
+
+func fixtureSSOProvider() *types.SSOProvider {
+	return &types.SSOProvider{
+		CreatedAt:      fixtureTime,
+		UpdatedAt:      fixtureLaterTime,
+		GroupRoles:     map[string]string{"platform-admins": types.RoleAdmin},
+		ID:             fixtureSSOProviderID,
+		OrganizationID: fixtureOrgID,
+		DisplayName:    "Okta",
+		IssuerURL:      "https://example.okta.com",
+		ClientID:       "0oa1b2c3d4",
+		ClientSecret:   "never-serialized",
+		GroupsClaim:    "groups",
+		DefaultRole:    types.RoleUser,
+		CreatedBy:      fixtureUserID,
+		Scopes:         []string{"openid", "email", "profile", "groups"},
+		AllowedDomains: []string{"example.com"},
+		IsActive:       true,
+	}
+}
+
+// stubSSO signs in through the fixture provider with the state "fixture-state"
+type stubSSO struct{}
+
+func (stubSSO) ListProviders(ctx context.Context, orgID string) ([]*types.SSOProvider, error) {
+	return []*types.SSOProvider{fixtureSSOProvider()}, nil
+}
+
+func (stubSSO) GetProvider(ctx context.Context, orgID, id string) (*types.SSOProvider, error) {
+	if id == missingID {
+		return nil, notFound("SSO provider")
+	}
+	return fixtureSSOProvider(), nil
+}
+
+func (stubSSO) CreateProvider(ctx context.Context, orgID, createdBy string, req *types.CreateSSOProviderRequest) (*types.SSOProvider, error) {
+	provider := fixtureSSOProvider()
+	provider.DisplayName = req.DisplayName
+	provider.IssuerURL = req.IssuerURL
+	provider.ClientID = req.ClientID
+	return provider, nil
+}
+
+func (stubSSO) UpdateProvider(ctx context.Context, orgID, id string, req *types.UpdateSSOProviderRequest) (*types.SSOProvider, error) {
+	if id == missingID {
+		return nil, notFound("SSO provider")
+	}
+	provider := fixtureSSOProvider()
+	if req.IsActive != nil {
+		provider.IsActive = *req.IsActive
+	}
+	return provider, nil
+}
+
+func (stubSSO) DeleteProvider(ctx context.Context, orgID, id string) error {
+	if id == missingID {
+		return notFound("SSO provider")
+	}
+	return nil
+}
+
+func (stubSSO) ListLoginProviders(ctx context.Context, orgSlug string) ([]*types.SSOProviderSummary, error) {
+	return []*types.SSOProviderSummary{{
+		ID:          fixtureSSOProviderID,
+		DisplayName: "Okta",
+		LoginURL:    "/api/auth/sso/" + fixtureSSOProviderID + "/login",
+	}}, nil
+}
+
+func (stubSSO) BeginLogin(ctx context.Context, providerID, redirectPath string) (string, error) {
+	if providerID == missingID {
+		return "", notFound("SSO provider")
+	}
+	return "https://example.okta.com/oauth2/v1/authorize?state=fixture-state", nil
+}
+
+func (stubSSO) CompleteLogin(ctx context.Context, state, code string, login *auth.LoginContext) (*types.LoginResponse, string, error) {
+	if state != "fixture-state" {
+		return nil, "", types.NewUnauthorizedError("Sign-in expired, please try again")
+	}
+	return fixtureLogin(), "/servers", nil
+}
+
+func TestSSOContracts(t *testing.T) {
+	h := handlers.NewSSOHandler(stubSSO{}, "https://gateway.example.com/auth/sso")
+	r := newContractRouter()
+	r.GET("/api/auth/sso/providers", h.ListLoginProviders)
+	r.GET("/api/auth/sso/:id/login", h.Login)
+	r.GET("/api/auth/sso/callback", h.Callback)
+	admin := r.Group("/api/admin")
+	admin.GET("/sso/providers", h.ListProviders)
+	admin.GET("/sso/providers/:id", h.GetProvider)
+	admin.POST("/sso/providers", h.CreateProvider)
+	admin.PUT("/sso/providers/:id", h.UpdateProvider)
+	admin.DELETE("/sso/providers/:id", h.DeleteProvider)
+
+	base := "/api/admin/sso/providers/" + fixtureSSOProviderID
+	r.run(t, "sso", []contractCase{
+		{name: "login-providers", method: http.MethodGet, path: "/api/auth/sso/providers", query: "organization=acme"},
+		{name: "login-providers-invalid", method: http.MethodGet, path: "/api/auth/sso/providers"},
+		{name: "login", method: http.MethodGet, path: "/api/auth/sso/" + fixtureSSOProviderID + "/login", query: "redirect=%2Fservers"},
+		{name: "login-not-found", method: http.MethodGet, path: "/api/auth/sso/" + missingID + "/login"},
+		{name: "callback", method: http.MethodGet, path: "/api/auth/sso/callback", query: "state=fixture-state&code=abc"},
+		{name: "callback-expired", method: http.MethodGet, path: "/api/auth/sso/callback", query: "state=stale&code=abc"},
+		{name: "callback-idp-error", method: http.MethodGet, path: "/api/auth/sso/callback",
+			query: "error=access_denied&error_description=User+is+not+assigned"},
+		{name: "list", method: http.MethodGet, path: "/api/admin/sso/providers"},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/sso/providers/" + missingID},
+		{name: "create", method: http.MethodPost, path: "/api/admin/sso/providers", body: map[string]interface{}{
+			"display_name": "Okta", "issuer_url": "https://example.okta.com", "client_id": "0oa1b2c3d4", "client_secret": "s3cret",
+		}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/sso/providers",
+			body: map[string]interface{}{"display_name": "Okta", "issuer_url": "not a url"}},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"is_active": false}},
+		{name: "update-not-found", method: http.MethodPut, path: "/api/admin/sso/providers/" + missingID, body: map[string]interface{}{}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "delete-not-found", method: http.MethodDelete, path: "/api/admin/sso/providers/" + missingID},
+	})
+}
+
+func fixtureServiceAccount() *types.ServiceAccount {
+	return &types.ServiceAccount{
+		CreatedAt:        fixtureTime,
+		UpdatedAt:        fixtureLaterTime,
+		ID:               fixtureServiceAccountID,
+		OrganizationID:   fixtureOrgID,
+		Name:             "deploy-bot",
+		Description:      "Deploys from CI",
+		Role:             types.RoleAPIUser,
+		CreatedBy:        fixtureUserID,
+		APIKeyCount:      1,
+		OAuthClientCount: 1,
+		IsActive:         true,
+	}
+}
+
+func fixtureOAuthClient() *types.OAuthClient {
+	return &types.OAuthClient{
+		ID:                      fixtureServiceAccountID,
+		ClientID:                fixtureOAuthClientID,
+		ClientName:              "deploy-bot",
+		ClientType:              types.ClientTypeConfidential,
+		RedirectURIs:            []string{},
+		GrantTypes:              []string{types.GrantTypeClientCredentials},
+		ResponseTypes:           []string{},
+		Scope:                   "read write",
+		Contacts:                []string{},
+		TokenEndpointAuthMethod: types.TokenEndpointAuthClientSecretBasic,
+		OrganizationID:          fixtureOrgID,
+		ServiceAccountID:        &[]string{fixtureServiceAccountID}[0],
+		IsActive:                true,
+		CreatedAt:               fixtureTime,
+		UpdatedAt:               fixtureLaterTime,
+	}
+}
+
+// stubServiceAccounts serves the fixture service account, its API key and
+// its OAuth client
+type stubServiceAccounts struct{}
+
+func (stubServiceAccounts) List(ctx context.Context, orgID string) ([]*types.ServiceAccount, error) {
+	return []*types.ServiceAccount{fixtureServiceAccount()}, nil
+}
+
+func (stubServiceAccounts) Get(ctx context.Context, orgID, id string) (*types.ServiceAccount, error) {
+	if id == missingID {
+		return nil, notFound("Service account")
+	}
+	return fixtureServiceAccount(), nil
+}
+
+func (stubServiceAccounts) Create(ctx context.Context, orgID, createdBy string, req *types.CreateServiceAccountRequest) (*types.ServiceAccount, error) {
+	if req.Name == "deploy-bot" {
+		return nil, types.NewConflictError("A service account with this name already exists")
+	}
+	account := fixtureServiceAccount()
+	account.Name = req.Name
+	account.Description = req.Description
+	account.Role = req.Role
+	account.APIKeyCount = 0
+	account.OAuthClientCount = 0
+	return account, nil
+}
+
+func (stubServiceAccounts) Update(ctx context.Context, orgID, id string, req *types.UpdateServiceAccountRequest) (*types.ServiceAccount, error) {
+	if id == missingID {
+		return nil, notFound("Service account")
+	}
+	account := fixtureServiceAccount()
+	if req.Role != nil {
+		account.Role = *req.Role
+	}
+	return account, nil
+}
+
+func (stubServiceAccounts) Delete(ctx context.Context, orgID, id string) error {
+	if id == missingID {
+		return notFound("Service account")
+	}
+	return nil
+}
+
+func (stubServiceAccounts) ListAPIKeys(ctx context.Context, orgID, id string) ([]*types.APIKey, error) {
+	if id == missingID {
+		return nil, notFound("Service account")
+	}
+	key := fixtureAPIKey()
+	key.UserID = fixtureServiceAccountID
+	return []*types.APIKey{key}, nil
+}
+
+func (stubServiceAccounts) CreateAPIKey(ctx context.Context, orgID, id string, req *types.CreateServiceAccountKeyRequest) (*types.CreateAPIKeyResponse, error) {
+	if id == missingID {
+		return nil, notFound("Service account")
+	}
+	key := fixtureAPIKey()
+	key.UserID = fixtureServiceAccountID
+	key.Name = req.Name
+	return &types.CreateAPIKeyResponse{APIKey: key, Key: "omk_3f9a_fixture-secret"}, nil
+}
+
+func (stubServiceAccounts) RevokeAPIKey(ctx context.Context, orgID, id, keyID string) error {
+	if keyID == missingID {
+		return notFound("API key")
+	}
+	return nil
+}
+
+func (stubServiceAccounts) ListClients(ctx context.Context, orgID, id string) ([]*types.OAuthClient, error) {
+	if id == missingID {
+		return nil, notFound("Service account")
+	}
+	return []*types.OAuthClient{fixtureOAuthClient()}, nil
+}
+
+func (stubServiceAccounts) CreateClient(ctx context.Context, orgID, id string, req *types.CreateServiceAccountClientRequest) (*types.ClientRegistrationResponse, error) {
+	if id == missingID {
+		return nil, notFound("Service account")
+	}
+	return &types.ClientRegistrationResponse{
+		ClientID:                fixtureOAuthClientID,
+		ClientSecret:            "fixture-client-secret",
+		ClientIdIssuedAt:        fixtureTime.Unix(),
+		TokenEndpointAuthMethod: types.TokenEndpointAuthClientSecretBasic,
+		GrantTypes:              []string{types.GrantTypeClientCredentials},
+		ClientName:              req.Name,
+		Scope:                   req.Scope,
+	}, nil
+}
+
+func (stubServiceAccounts) DeleteClient(ctx context.Context, orgID, id, clientID string) error {
+	if clientID != fixtureOAuthClientID {
+		return notFound("OAuth client")
+	}
+	return nil
+}
+
+// stubDelegation lets the fixture service account act for users and viewers
+type stubDelegation struct{}
+
+func (stubDelegation) GetPolicy(ctx context.Context, orgID, serviceAccountID string) (*types.DelegationPolicy, error) {
+	if serviceAccountID == missingID {
+		return nil, notFound("Delegation policy")
+	}
+	return &types.DelegationPolicy{
+		CreatedAt:        fixtureTime,
+		UpdatedAt:        fixtureLaterTime,
+		ServiceAccountID: fixtureServiceAccountID,
+		OrganizationID:   fixtureOrgID,
+		CreatedBy:        fixtureUserID,
+		AllowedRoles:     []string{types.RoleUser, types.RoleViewer},
+		AllowedUserIDs:   []string{},
+	}, nil
+}
+
+func (stubDelegation) SetPolicy(ctx context.Context, orgID, serviceAccountID, setBy string, req *types.SetDelegationPolicyRequest) (*types.DelegationPolicy, error) {
+	if serviceAccountID == missingID {
+		return nil, notFound("Service account")
+	}
+	return &types.DelegationPolicy{
+		CreatedAt:        fixtureTime,
+		UpdatedAt:        fixtureLaterTime,
+		ServiceAccountID: fixtureServiceAccountID,
+		OrganizationID:   fixtureOrgID,
+		CreatedBy:        setBy,
+		AllowedRoles:     req.AllowedRoles,
+		AllowedUserIDs:   req.AllowedUserIDs,
+	}, nil
+}
+
+func (stubDelegation) DeletePolicy(ctx context.Context, orgID, serviceAccountID string) error {
+	if serviceAccountID == missingID {
+		return notFound("Delegation policy")
+	}
+	return nil
+}
+
+func TestServiceAccountContracts(t *testing.T) {
+	h := handlers.NewServiceAccountHandler(stubServiceAccounts{})
+	delegation := handlers.NewDelegationHandler(stubDelegation{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/service-accounts", h.List)
+	admin.POST("/service-accounts", h.Create)
+	admin.GET("/service-accounts/:id", h.Get)
+	admin.PUT("/service-accounts/:id", h.Update)
+	admin.DELETE("/service-accounts/:id", h.Delete)
+	admin.GET("/service-accounts/:id/api-keys", h.ListAPIKeys)
+	admin.POST("/service-accounts/:id/api-keys", h.CreateAPIKey)
+	admin.DELETE("/service-accounts/:id/api-keys/:key_id", h.RevokeAPIKey)
+	admin.GET("/service-accounts/:id/oauth-clients", h.ListClients)
+	admin.POST("/service-accounts/:id/oauth-clients", h.CreateClient)
+	admin.DELETE("/service-accounts/:id/oauth-clients/:client_id", h.DeleteClient)
+	admin.GET("/service-accounts/:id/delegation", delegation.GetPolicy)
+	admin.PUT("/service-accounts/:id/delegation", delegation.SetPolicy)
+	admin.DELETE("/service-accounts/:id/delegation", delegation.DeletePolicy)
+
+	base := "/api/admin/service-accounts/" + fixtureServiceAccountID
+	missing := "/api/admin/service-accounts/" + missingID
+	r.run(t, "service-accounts", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/service-accounts"},
+		{name: "create", method: http.MethodPost, path: "/api/admin/service-accounts",
+			body: map[string]interface{}{"name": "sync-bot", "description": "Syncs the catalog", "role": "user"}},
+		{name: "create-conflict", method: http.MethodPost, path: "/api/admin/service-accounts",
+			body: map[string]interface{}{"name": "deploy-bot", "role": "api_user"}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/service-accounts",
+			body: map[string]interface{}{"name": "sync-bot", "role": "owner"}},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: missing},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"role": "viewer"}},
+		{name: "update-not-found", method: http.MethodPut, path: missing, body: map[string]interface{}{}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "delete-not-found", method: http.MethodDelete, path: missing},
+		{name: "list-api-keys", method: http.MethodGet, path: base + "/api-keys"},
+		{name: "create-api-key", method: http.MethodPost, path: base + "/api-keys", body: map[string]interface{}{"name": "ci"}},
+		{name: "create-api-key-invalid", method: http.MethodPost, path: base + "/api-keys", body: map[string]interface{}{"name": "x"}},
+		{name: "create-api-key-not-found", method: http.MethodPost, path: missing + "/api-keys", body: map[string]interface{}{"name": "ci"}},
+		{name: "revoke-api-key", method: http.MethodDelete, path: base + "/api-keys/" + fixtureAPIKeyID},
+		{name: "revoke-api-key-not-found", method: http.MethodDelete, path: base + "/api-keys/" + missingID},
+		{name: "list-clients", method: http.MethodGet, path: base + "/oauth-clients"},
+		{name: "create-client", method: http.MethodPost, path: base + "/oauth-clients",
+			body: map[string]interface{}{"name": "deploy-bot", "scope": "read write"}},
+		{name: "create-client-invalid", method: http.MethodPost, path: base + "/oauth-clients", body: map[string]interface{}{}},
+		{name: "delete-client", method: http.MethodDelete, path: base + "/oauth-clients/" + fixtureOAuthClientID},
+		{name: "delete-client-not-found", method: http.MethodDelete, path: base + "/oauth-clients/mcp_client_unknown"},
+		{name: "get-delegation", method: http.MethodGet, path: base + "/delegation"},
+		{name: "get-delegation-not-found", method: http.MethodGet, path: missing + "/delegation"},
+		{name: "set-delegation", method: http.MethodPut, path: base + "/delegation",
+			body: map[string]interface{}{"allowed_roles": []string{"user"}, "allowed_user_ids": []string{fixtureUserID}}},
+		{name: "set-delegation-invalid", method: http.MethodPut, path: base + "/delegation",
+			body: map[string]interface{}{"allowed_user_ids": []string{"ada"}}},
+		{name: "delete-delegation", method: http.MethodDelete, path: base + "/delegation"},
+		{name: "delete-delegation-not-found", method: http.MethodDelete, path: missing + "/delegation"},
+	})
+}
+
+func fixtureRoleGrant() *types.RoleGrant {
+	return &types.RoleGrant{
+		CreatedAt:      fixtureTime,
+		ExpiresAt:      fixtureLaterTime,
+		ID:             fixtureRoleGrantID,
+		OrganizationID: fixtureOrgID,
+		UserID:         fixtureUserID,
+		UserEmail:      "ada@example.com",
+		Role:           types.RoleAdmin,
+		Reason:         "On call this week",
+		GrantedBy:      fixtureUserID,
+	}
+}
+
+// stubRoleGrants serves the fixture grant
+type stubRoleGrants struct{}
+
+func (stubRoleGrants) ListRoleGrants(ctx context.Context, orgID string, filter *types.RoleGrantFilter) ([]*types.RoleGrant, error) {
+	return []*types.RoleGrant{fixtureRoleGrant()}, nil
+}
+
+func (stubRoleGrants) GrantRole(ctx context.Context, orgID, grantedBy string, req *types.CreateRoleGrantRequest) (*types.RoleGrant, error) {
+	if req.UserID == missingID {
+		return nil, notFound("User")
+	}
+	grant := fixtureRoleGrant()
+	grant.Role = req.Role
+	grant.Reason = req.Reason
+	return grant, nil
+}
+
+func (stubRoleGrants) RevokeRole(ctx context.Context, orgID, grantID, revokedBy string) error {
+	if grantID == missingID {
+		return notFound("Role grant")
+	}
+	return nil
+}
+
+func TestRoleGrantContracts(t *testing.T) {
+	h := handlers.NewRoleGrantHandler(stubRoleGrants{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/role-grants", h.ListRoleGrants)
+	admin.POST("/role-grants", h.CreateRoleGrant)
+	admin.DELETE("/role-grants/:id", h.RevokeRoleGrant)
+
+	r.run(t, "role-grants", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/role-grants", query: "user_id=" + fixtureUserID + "&include_inactive=true"},
+		{name: "list-invalid", method: http.MethodGet, path: "/api/admin/role-grants", query: "user_id=ada"},
+		{name: "create", method: http.MethodPost, path: "/api/admin/role-grants",
+			body: map[string]interface{}{"user_id": fixtureUserID, "role": "admin", "reason": "On call this week", "duration_days": 7}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/role-grants",
+			body: map[string]interface{}{"user_id": fixtureUserID, "role": "admin", "duration_days": 400}},
+		{name: "create-not-found", method: http.MethodPost, path: "/api/admin/role-grants",
+			body: map[string]interface{}{"user_id": missingID, "role": "admin"}},
+		{name: "revoke", method: http.MethodDelete, path: "/api/admin/role-grants/" + fixtureRoleGrantID},
+		{name: "revoke-not-found", method: http.MethodDelete, path: "/api/admin/role-grants/" + missingID},
+	})
+}
+
+func fixtureBreakGlassCredential() *types.BreakGlassCredential {
+	return &types.BreakGlassCredential{
+		CreatedAt:      fixtureTime,
+		ID:             fixtureBreakGlassID,
+		OrganizationID: fixtureOrgID,
+		UserID:         fixtureUserID,
+		UserEmail:      "ada@example.com",
+		Name:           "Safe deposit box",
+		CreatedBy:      fixtureUserID,
+		CredentialHash: "never-serialized",
+		MFASecret:      "never-serialized",
+	}
+}
+
+func fixtureBreakGlassSecret() *types.BreakGlassCredentialSecret {
+	return &types.BreakGlassCredentialSecret{
+		Credential: fixtureBreakGlassCredential(),
+		Secret:     "bg_fixture-secret",
+		MFASecret:  "JBSWY3DPEHPK3PXP",
+		MFAURI:     "otpauth://totp/Omnimesh:ada@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Omnimesh",
+	}
+}
+
+func fixtureBreakGlassSession() *types.BreakGlassSession {
+	return &types.BreakGlassSession{
+		StartedAt:      fixtureTime,
+		ExpiresAt:      fixtureTime.Add(time.Hour),
+		ID:             fixtureBreakGlassSessionID,
+		CredentialID:   fixtureBreakGlassID,
+		OrganizationID: fixtureOrgID,
+		UserID:         fixtureUserID,
+		UserEmail:      "ada@example.com",
+		Reason:         "Identity provider outage",
+		RemoteIP:       "192.0.2.1",
+		ActionCount:    3,
+	}
+}
+
+// stubBreakGlass opens a session for the credential bg_fixture-secret with
+// the MFA code 123456
+type stubBreakGlass struct{}
+
+func (stubBreakGlass) ListCredentials(ctx context.Context, orgID string) ([]*types.BreakGlassCredential, error) {
+	return []*types.BreakGlassCredential{fixtureBreakGlassCredential()}, nil
+}
+
+func (stubBreakGlass) CreateCredential(ctx context.Context, orgID, createdBy string, req *types.CreateBreakGlassCredentialRequest) (*types.BreakGlassCredentialSecret, error) {
+	if req.UserID == missingID {
+		return nil, notFound("User")
+	}
+	secret := fixtureBreakGlassSecret()
+	secret.Credential.Name = req.Name
+	return secret, nil
+}
+
+func (stubBreakGlass) RotateCredential(ctx context.Context, orgID, id string) (*types.BreakGlassCredentialSecret, error) {
+	if id == missingID {
+		return nil, notFound("Break-glass credential")
+	}
+	secret := fixtureBreakGlassSecret()
+	secret.Credential.RotatedAt = &fixtureLaterTime
+	return secret, nil
+}
+
+func (stubBreakGlass) DeleteCredential(ctx context.Context, orgID, id string) error {
+	if id == missingID {
+		return notFound("Break-glass credential")
+	}
+	return nil
+}
+
+func (stubBreakGlass) Activate(ctx context.Context, req *types.ActivateBreakGlassRequest, remoteIP string) (*types.BreakGlassActivation, error) {
+	if req.Credential != "bg_fixture-secret" || req.MFACode != "123456" {
+		return nil, types.NewInvalidCredentialsError()
+	}
+	session := fixtureBreakGlassSession()
+	session.Reason = req.Reason
+	session.ActionCount = 0
+	return &types.BreakGlassActivation{
+		Session:     session,
+		AccessToken: "fixture-break-glass-token",
+		TokenType:   "Bearer",
+		ExpiresIn:   3600,
+	}, nil
+}
+
+func (stubBreakGlass) ListSessions(ctx context.Context, orgID string) ([]*types.BreakGlassSession, error) {
+	return []*types.BreakGlassSession{fixtureBreakGlassSession()}, nil
+}
+
+func (stubBreakGlass) EndSession(ctx context.Context, orgID, sessionID, endedBy string) error {
+	if sessionID == missingID {
+		return notFound("Break-glass session")
+	}
+	return nil
+}
+
+func TestBreakGlassContracts(t *testing.T) {
+	h := handlers.NewBreakGlassHandler(stubBreakGlass{})
+	r := newContractRouter()
+	r.POST("/api/auth/break-glass", h.Activate)
+	admin := r.Group("/api/admin")
+	admin.GET("/break-glass/credentials", h.ListCredentials)
+	admin.POST("/break-glass/credentials", h.CreateCredential)
+	admin.POST("/break-glass/credentials/:id/rotate", h.RotateCredential)
+	admin.DELETE("/break-glass/credentials/:id", h.DeleteCredential)
+	admin.GET("/break-glass/sessions", h.ListSessions)
+	admin.DELETE("/break-glass/sessions/:id", h.EndSession)
+
+	credentials := "/api/admin/break-glass/credentials/"
+	r.run(t, "break-glass", []contractCase{
+		{name: "activate", method: http.MethodPost, path: "/api/auth/break-glass", body: map[string]interface{}{
+			"credential": "bg_fixture-secret", "mfa_code": "123456", "reason": "Identity provider outage",
+		}},
+		{name: "activate-invalid", method: http.MethodPost, path: "/api/auth/break-glass", body: map[string]interface{}{
+			"credential": "bg_fixture-secret", "mfa_code": "123456", "reason": "outage",
+		}},
+		{name: "activate-wrong-code", method: http.MethodPost, path: "/api/auth/break-glass", body: map[string]interface{}{
+			"credential": "bg_fixture-secret", "mfa_code": "000000", "reason": "Identity provider outage",
+		}},
+		{name: "list-credentials", method: http.MethodGet, path: "/api/admin/break-glass/credentials"},
+		{name: "create-credential", method: http.MethodPost, path: "/api/admin/break-glass/credentials",
+			body: map[string]interface{}{"user_id": fixtureUserID, "name": "Safe deposit box"}},
+		{name: "create-credential-invalid", method: http.MethodPost, path: "/api/admin/break-glass/credentials",
+			body: map[string]interface{}{"user_id": "ada", "name": "Safe deposit box"}},
+		{name: "create-credential-not-found", method: http.MethodPost, path: "/api/admin/break-glass/credentials",
+			body: map[string]interface{}{"user_id": missingID, "name": "Safe deposit box"}},
+		{name: "rotate-credential", method: http.MethodPost, path: credentials + fixtureBreakGlassID + "/rotate"},
+		{name: "rotate-credential-not-found", method: http.MethodPost, path: credentials + missingID + "/rotate"},
+		{name: "delete-credential", method: http.MethodDelete, path: credentials + fixtureBreakGlassID},
+		{name: "delete-credential-not-found", method: http.MethodDelete, path: credentials + missingID},
+		{name: "list-sessions", method: http.MethodGet, path: "/api/admin/break-glass/sessions"},
+		{name: "end-session", method: http.MethodDelete, path: "/api/admin/break-glass/sessions/" + fixtureBreakGlassSessionID},
+		{name: "end-session-not-found", method: http.MethodDelete, path: "/api/admin/break-glass/sessions/" + missingID},
+	})
+}
+
+func fixtureCompromisedCredential() *types.CompromisedCredential {
+	return &types.CompromisedCredential{
+		CreatedAt:      fixtureTime,
+		ID:             fixtureCompromisedID,
+		OrganizationID: fixtureOrgID,
+		UserID:         fixtureUserID,
+		CredentialType: types.CompromisedAPIKey,
+		CredentialID:   fixtureAPIKeyID,
+		CredentialName: "ci",
+		Source:         types.CompromiseSourceAdmin,
+		Reporter:       fixtureUserID,
+		Reason:         "Pasted into a public issue",
+	}
+}
+
+// stubCompromised revokes the fixture API key and accepts threat feed
+// reports signed "fixture-signature"
+type stubCompromised struct{}
+
+func (stubCompromised) List(ctx context.Context, orgID string) ([]*types.CompromisedCredential, error) {
+	return []*types.CompromisedCredential{fixtureCompromisedCredential()}, nil
+}
+
+func (stubCompromised) Report(ctx context.Context, orgID, reportedBy string, req *types.ReportCompromisedCredentialRequest) (*types.CompromiseReportResult, error) {
+	if req.APIKeyID != fixtureAPIKeyID {
+		return &types.CompromiseReportResult{Revoked: []*types.CompromisedCredential{}, Unmatched: 1}, nil
+	}
+	return &types.CompromiseReportResult{Revoked: []*types.CompromisedCredential{fixtureCompromisedCredential()}}, nil
+}
+
+func (stubCompromised) VerifyFeedRequest(ctx context.Context, body []byte, signature, timestamp, nonce string) error {
+	if signature != "fixture-signature" || timestamp == "" || nonce == "" {
+		return types.NewUnauthorizedError("Invalid threat feed signature")
+	}
+	return nil
+}
+
+func (stubCompromised) ReportFromFeed(ctx context.Context, report *types.ThreatFeedReport) (*types.CompromiseReportResult, error) {
+	credential := fixtureCompromisedCredential()
+	credential.Source = types.CompromiseSourceThreatFeed
+	credential.Reporter = report.Feed
+	return &types.CompromiseReportResult{Revoked: []*types.CompromisedCredential{credential}, Unmatched: len(report.Credentials) - 1}, nil
+}
+
+func TestCompromisedCredentialContracts(t *testing.T) {
+	h := handlers.NewCompromisedCredentialHandler(stubCompromised{})
+	r := newContractRouter()
+	r.POST("/api/auth/threat-feed", h.ThreatFeed)
+	admin := r.Group("/api/admin")
+	admin.GET("/compromised-credentials", h.List)
+	admin.POST("/compromised-credentials", h.Report)
+
+	signed := map[string]string{
+		types.ThreatFeedSignatureHeader: "fixture-signature",
+		types.RequestTimestampHeader:    "1772443800",
+		types.RequestNonceHeader:        "fixture-nonce",
+	}
+	feedReport := map[string]interface{}{
+		"feed": "github-secret-scanning",
+		"credentials": []map[string]interface{}{
+			{"api_key_id": fixtureAPIKeyID, "reason": "Found in a public repository"},
+			{"fingerprint": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+		},
+	}
+	r.run(t, "compromised-credentials", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/compromised-credentials"},
+		{name: "report", method: http.MethodPost, path: "/api/admin/compromised-credentials",
+			body: map[string]interface{}{"api_key_id": fixtureAPIKeyID, "reason": "Pasted into a public issue"}},
+		{name: "report-unmatched", method: http.MethodPost, path: "/api/admin/compromised-credentials",
+			body: map[string]interface{}{"api_key_id": missingID}},
+		{name: "report-invalid", method: http.MethodPost, path: "/api/admin/compromised-credentials",
+			body: map[string]interface{}{"fingerprint": "abc"}},
+		{name: "threat-feed", method: http.MethodPost, path: "/api/auth/threat-feed", body: feedReport, headers: signed},
+		{name: "threat-feed-unsigned", method: http.MethodPost, path: "/api/auth/threat-feed", body: feedReport},
+		{name: "threat-feed-invalid", method: http.MethodPost, path: "/api/auth/threat-feed",
+			body: map[string]interface{}{"feed": "github-secret-scanning"}, headers: signed},
+	})
+}
+
+func fixtureSigningKey() *types.SigningKey {
+	return &types.SigningKey{
+		CreatedAt:       fixtureTime,
+		ID:              fixtureSigningKeyID,
+		OrganizationID:  fixtureOrgID,
+		DestinationType: types.SigningDestinationA2AAgent,
+		DestinationID:   fixtureA2AAgentID,
+		Algorithm:       "ed25519",
+		PublicKey:       "MCowBQYDK2VwAyEAGb9ECWmEzf6FQbrBZ9w7lshQhqowtrbLDFw4rXAxZuE=",
+		CreatedBy:       fixtureUserID,
+		Secret:          "never-serialized",
+	}
+}
+
+// stubSigningKeys serves the fixture key of the fixture A2A agent
+type stubSigningKeys struct{}
+
+func (stubSigningKeys) CreateKey(ctx context.Context, orgID, createdBy string, req *types.CreateSigningKeyRequest) (*types.CreateSigningKeyResponse, error) {
+	if req.DestinationID == missingID {
+		return nil, notFound("A2A agent")
+	}
+	key := fixtureSigningKey()
+	if req.Algorithm == "hmac-sha256" {
+		key.Algorithm = req.Algorithm
+		key.PublicKey = ""
+		return &types.CreateSigningKeyResponse{Key: key, Secret: "fixture-hmac-secret"}, nil
+	}
+	return &types.CreateSigningKeyResponse{Key: key}, nil
+}
+
+func (stubSigningKeys) ListKeys(ctx context.Context, orgID, destinationType, destinationID string) ([]*types.SigningKey, error) {
+	return []*types.SigningKey{fixtureSigningKey()}, nil
+}
+
+func (stubSigningKeys) RevokeKey(ctx context.Context, orgID, id string) error {
+	if id == missingID {
+		return notFound("Signing key")
+	}
+	return nil
+}
+
+func TestSigningKeyContracts(t *testing.T) {
+	h := handlers.NewSigningKeyHandler(stubSigningKeys{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/signing-keys", h.ListKeys)
+	admin.POST("/signing-keys", h.CreateKey)
+	admin.DELETE("/signing-keys/:id", h.RevokeKey)
+
+	r.run(t, "signing-keys", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/signing-keys",
+			query: "destination_type=a2a_agent&destination_id=" + fixtureA2AAgentID},
+		{name: "create", method: http.MethodPost, path: "/api/admin/signing-keys",
+			body: map[string]interface{}{"destination_type": "a2a_agent", "destination_id": fixtureA2AAgentID, "algorithm": "ed25519"}},
+		{name: "create-hmac", method: http.MethodPost, path: "/api/admin/signing-keys",
+			body: map[string]interface{}{"destination_type": "a2a_agent", "destination_id": fixtureA2AAgentID, "algorithm": "hmac-sha256"}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/signing-keys",
+			body: map[string]interface{}{"destination_type": "mcp_server", "destination_id": fixtureServerID}},
+		{name: "create-not-found", method: http.MethodPost, path: "/api/admin/signing-keys",
+			body: map[string]interface{}{"destination_type": "a2a_agent", "destination_id": missingID}},
+		{name: "revoke", method: http.MethodDelete, path: "/api/admin/signing-keys/" + fixtureSigningKeyID},
+		{name: "revoke-not-found", method: http.MethodDelete, path: "/api/admin/signing-keys/" + missingID},
+	})
+}
+
+func fixtureAccessToken() *types.OAuthInitialAccessToken {
+	expires := fixtureTime.Add(72 * time.Hour)
+	return &types.OAuthInitialAccessToken{
+		CreatedAt:      fixtureTime,
+		ExpiresAt:      &expires,
+		ID:             fixtureAccessTokenID,
+		OrganizationID: fixtureOrgID,
+		TokenHash:      "never-serialized",
+		Description:    "Partner onboarding",
+		CreatedBy:      fixtureUserID,
+		MaxUses:        5,
+		UseCount:       2,
+	}
+}
+
+// stubRegistrationPolicies has no registration policy stored until one is
+// set
+type stubRegistrationPolicies struct{}
+
+func (stubRegistrationPolicies) GetRegistrationPolicy(ctx context.Context, orgID string) (*types.OAuthRegistrationPolicy, error) {
+	return nil, nil
+}
+
+func (stubRegistrationPolicies) SetRegistrationPolicy(ctx context.Context, orgID string, req *types.UpdateOAuthRegistrationPolicyRequest, updatedBy string) (*types.OAuthRegistrationPolicy, error) {
+	return &types.OAuthRegistrationPolicy{
+		UpdatedAt:                 fixtureLaterTime,
+		OrganizationID:            orgID,
+		UpdatedBy:                 updatedBy,
+		AllowedGrantTypes:         req.AllowedGrantTypes,
+		RedirectURIPatterns:       req.RedirectURIPatterns,
+		TrustedSoftwareIssuers:    []string{},
+		SoftwareStatementKeys:     []string{},
+		UnusedClientTTLHours:      req.UnusedClientTTLHours,
+		RequireInitialAccessToken: req.RequireInitialAccessToken,
+		RequireSoftwareStatement:  req.RequireSoftwareStatement,
+	}, nil
+}
+
+func (stubRegistrationPolicies) CreateInitialAccessToken(ctx context.Context, orgID string, req *types.CreateInitialAccessTokenRequest, createdBy string) (*types.CreateInitialAccessTokenResponse, error) {
+	token := fixtureAccessToken()
+	token.Description = req.Description
+	token.MaxUses = req.MaxUses
+	token.UseCount = 0
+	return &types.CreateInitialAccessTokenResponse{OAuthInitialAccessToken: token, Token: "iat_fixture-token"}, nil
+}
+
+func (stubRegistrationPolicies) ListInitialAccessTokens(ctx context.Context, orgID string) ([]*types.OAuthInitialAccessToken, error) {
+	return []*types.OAuthInitialAccessToken{fixtureAccessToken()}, nil
+}
+
+func (stubRegistrationPolicies) RevokeInitialAccessToken(ctx context.Context, orgID, id string) error {
+	if id == missingID {
+		return notFound("Initial access token")
+	}
+	return nil
+}
+
+func TestOAuthRegistrationContracts(t *testing.T) {
+	h := handlers.NewOAuthRegistrationHandler(stubRegistrationPolicies{}, discardAudit{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/oauth/registration-policy", h.GetPolicy)
+	admin.PUT("/oauth/registration-policy", h.UpdatePolicy)
+	admin.GET("/oauth/initial-access-tokens", h.ListInitialAccessTokens)
+	admin.POST("/oauth/initial-access-tokens", h.CreateInitialAccessToken)
+	admin.DELETE("/oauth/initial-access-tokens/:id", h.RevokeInitialAccessToken)
+
+	r.run(t, "oauth-registration", []contractCase{
+		{name: "get-policy-default", method: http.MethodGet, path: "/api/admin/oauth/registration-policy"},
+		{name: "update-policy", method: http.MethodPut, path: "/api/admin/oauth/registration-policy", body: map[string]interface{}{
+			"allowed_grant_types":          []string{"authorization_code", "refresh_token"},
+			"redirect_uri_patterns":        []string{"https://*.example.com/callback"},
+			"unused_client_ttl_hours":      720,
+			"require_initial_access_token": true,
+		}},
+		{name: "update-policy-invalid", method: http.MethodPut, path: "/api/admin/oauth/registration-policy",
+			body: map[string]interface{}{"unused_client_ttl_hours": -1}},
+		{name: "list-tokens", method: http.MethodGet, path: "/api/admin/oauth/initial-access-tokens"},
+		{name: "create-token", method: http.MethodPost, path: "/api/admin/oauth/initial-access-tokens",
+			body: map[string]interface{}{"description": "Partner onboarding", "expires_in_hours": 72, "max_uses": 5}},
+		{name: "create-token-invalid", method: http.MethodPost, path: "/api/admin/oauth/initial-access-tokens",
+			body: map[string]interface{}{"max_uses": -1}},
+		{name: "revoke-token", method: http.MethodDelete, path: "/api/admin/oauth/initial-access-tokens/" + fixtureAccessTokenID},
+		{name: "revoke-token-not-found", method: http.MethodDelete, path: "/api/admin/oauth/initial-access-tokens/" + missingID},
+	})
+}
+
+// stubLimits has no plan to report quotas from
+type stubLimits struct{}
+
+func (stubLimits) GetPlanQuotas(ctx context.Context, orgID string) (*types.PlanQuotas, error) {
+	return nil, errors.New("organization has no plan")
+}
+
+func (stubLimits) GetPrincipalUsage(ctx context.Context, principal *types.Principal) (*types.PrincipalUsageSummary, error) {
+	return &types.PrincipalUsageSummary{Window: "24h"}, nil
+}
+
+// TestLimitsContracts covers the failure response only, because a report
+// carries the time it was generated
+func TestLimitsContracts(t *testing.T) {
+	h := handlers.NewLimitsHandler(stubLimits{})
+	r := newContractRouter()
+	r.GET("/api/auth/limits", h.GetLimits)
+
+	r.run(t, "limits", []contractCase{
+		{name: "get-error", method: http.MethodGet, path: "/api/auth/limits"},
+	})
+}
+
+func TestOAuthContracts(t *testing.T) {
+	// The cases fail validation before the service reaches its database
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	oauthService := auth.NewOAuthService(sqlx.NewDb(db, "postgres"), "contract-test-secret", "https://gateway.example.com", nil)
+	h := handlers.NewOAuthHandler(oauthService)
+	h.SetEndpointResolver(stubEndpoints{})
+	r := newContractRouter()
+	r.GET("/.well-known/oauth-authorization-server", h.DiscoverAuthorizationServer)
+	r.GET("/.well-known/oauth-protected-resource", h.DiscoverProtectedResource)
+	r.GET("/.well-known/oauth-authorization-server/*path", h.DiscoverAuthorizationServer)
+	r.GET("/.well-known/oauth-protected-resource/*path", h.DiscoverProtectedResource)
+	oauth := r.Group("/oauth")
+	oauth.POST("/register", h.RegisterClient)
+	oauth.POST("/token", h.IssueToken)
+	oauth.POST("/introspect", h.IntrospectToken)
+	oauth.POST("/revoke", h.RevokeToken)
+	oauth.GET("/jwks", h.GetJWKS)
+	oauth.GET("/authorize", h.AuthorizeEndpoint)
+	oauth.POST("/authorize", h.AuthorizeEndpoint)
+	r.POST("/register", h.RegisterClient)
+
+	form := map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+	r.run(t, "oauth", []contractCase{
+		{name: "authorization-server", method: http.MethodGet, path: "/.well-known/oauth-authorization-server"},
+		{name: "authorization-server-path", method: http.MethodGet, path: "/.well-known/oauth-authorization-server/mcp"},
+		{name: "protected-resource", method: http.MethodGet, path: "/.well-known/oauth-protected-resource"},
+		{name: "protected-resource-endpoint-not-found", method: http.MethodGet,
+			path: "/.well-known/oauth-protected-resource/api/public/endpoints/unknown/mcp"},
+		{name: "register-invalid", method: http.MethodPost, path: "/oauth/register", body: "{"},
+		{name: "register-root-invalid", method: http.MethodPost, path: "/register", body: "{"},
+		{name: "token-missing-grant-type", method: http.MethodPost, path: "/oauth/token", body: "client_id=abc", headers: form},
+		{name: "token-missing-client", method: http.MethodPost, path: "/oauth/token", body: "grant_type=client_credentials", headers: form},
+		{name: "introspect-missing-token", method: http.MethodPost, path: "/oauth/introspect", body: "", headers: form},
+		{name: "introspect-unauthenticated", method: http.MethodPost, path: "/oauth/introspect", body: "token=abc", headers: form},
+		{name: "revoke-missing-token", method: http.MethodPost, path: "/oauth/revoke", body: "", headers: form},
+		{name: "revoke-unauthenticated", method: http.MethodPost, path: "/oauth/revoke", body: "token=abc", headers: form},
+		{name: "jwks", method: http.MethodGet, path: "/oauth/jwks"},
+		{name: "authorize-missing-response-type", method: http.MethodGet, path: "/oauth/authorize", query: "client_id=abc"},
+		{name: "authorize-missing-client", method: http.MethodPost, path: "/oauth/authorize", body: "response_type=code", headers: form},
+	})
+}
@@ -0,0 +1,348 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/inspector"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	fixtureInspectorSessionID = "1b0a9f8e-7d6c-4b5a-9f3e-2d1c0b9a8f19"
+	fixtureForeignSessionID   = "2c1b0a9f-8e7d-4c6b-8a4f-3e2d1c0b9a2a"
+	fixtureCollectionID       = "3d2c1b0a-9f8e-4d7c-9b5a-4f3e2d1c0b3b"
+	fixtureFrameID            = "4e3d2c1b-0a9f-4e8d-8c6b-5a4f3e2d1c4c"
+	fixtureAttachedServerID   = "5f4e3d2c-1b0a-4f9e-9d7c-6b5a4f3e2d5d"
+	fixtureOtherServerID      = "6a5f4e3d-2c1b-4a0f-8e8d-7c6b5a4f3e6e"
+)
+
+func fixtureInspectorSession() *inspector.InspectorSession {
+	return &inspector.InspectorSession{
+		ID:           fixtureInspectorSessionID,
+		ServerID:     fixtureServerID,
+		UserID:       fixtureUserID,
+		OrgID:        fixtureOrgID,
+		NamespaceID:  fixtureNamespaceID,
+		Status:       inspector.SessionStatusConnected,
+		Capabilities: map[string]interface{}{"tools": map[string]interface{}{"listChanged": true}},
+		CreatedAt:    fixtureTime,
+		LastActivity: fixtureLaterTime,
+		Metadata:     map[string]interface{}{},
+	}
+}
+
+func fixtureFrame(direction, kind, raw string) *inspector.Frame {
+	return &inspector.Frame{
+		ID:        fixtureFrameID,
+		SessionID: fixtureInspectorSessionID,
+		Direction: direction,
+		Kind:      kind,
+		Method:    "tools/list",
+		RPCID:     "1",
+		Raw:       json.RawMessage(raw),
+		Text:      raw,
+		Size:      len(raw),
+		Timestamp: fixtureTime,
+	}
+}
+
+func fixtureExchange() *inspector.RawExchange {
+	return &inspector.RawExchange{
+		Request:       fixtureFrame("outbound", "request", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`),
+		Response:      fixtureFrame("inbound", "response", `{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}`),
+		Notifications: []*inspector.Frame{},
+		Duration:      42,
+	}
+}
+
+func fixtureInspectorResponse() *inspector.InspectorResponse {
+	return &inspector.InspectorResponse{
+		ID:        "resp-1",
+		RequestID: "req-1",
+		Result:    map[string]interface{}{"content": []map[string]interface{}{{"type": "text", "text": "3 open issues"}}},
+		Duration:  182 * time.Millisecond,
+		Timestamp: fixtureLaterTime,
+	}
+}
+
+// stubInspector holds the fixture session of fixtureUserID, captures its
+// frames and broadcasts to the fixture namespace's servers. A second
+// session belongs to another user.
+type stubInspector struct{}
+
+func (stubInspector) CreateSession(ctx context.Context, serverID, userID, orgID, namespaceID string) (*inspector.InspectorSession, error) {
+	return fixtureInspectorSession(), nil
+}
+
+func (stubInspector) GetSession(sessionID string) (*inspector.InspectorSession, error) {
+	switch sessionID {
+	case fixtureInspectorSessionID:
+		return fixtureInspectorSession(), nil
+	case fixtureForeignSessionID:
+		session := fixtureInspectorSession()
+		session.ID = fixtureForeignSessionID
+		session.UserID = fixtureMemberUserID
+		return session, nil
+	}
+	return nil, fmt.Errorf("session not found: %s", sessionID)
+}
+
+func (stubInspector) CloseSession(ctx context.Context, sessionID string) error {
+	return nil
+}
+
+func (stubInspector) ExecuteRequest(ctx context.Context, sessionID string, req inspector.InspectorRequest) (*inspector.InspectorResponse, error) {
+	return fixtureInspectorResponse(), nil
+}
+
+func (stubInspector) GetEventChannel(sessionID string) (<-chan inspector.InspectorEvent, error) {
+	return nil, fmt.Errorf("session not found: %s", sessionID)
+}
+
+func (stubInspector) GetServerCapabilities(ctx context.Context, serverID string) (*inspector.ServerCapabilities, error) {
+	return &inspector.ServerCapabilities{
+		Tools:     &inspector.ToolsCapability{ListChanged: true},
+		Resources: &inspector.ResourcesCapability{Subscribe: true},
+	}, nil
+}
+
+func (stubInspector) ListFrames(sessionID string) ([]*inspector.Frame, error) {
+	exchange := fixtureExchange()
+	return []*inspector.Frame{exchange.Request, exchange.Response}, nil
+}
+
+func (stubInspector) GetFrame(sessionID, frameID string) (*inspector.Frame, error) {
+	if frameID != fixtureFrameID {
+		return nil, inspector.ErrFrameNotFound
+	}
+	return fixtureExchange().Request, nil
+}
+
+func (stubInspector) SendRaw(ctx context.Context, sessionID string, raw json.RawMessage) (*inspector.RawExchange, error) {
+	var frame map[string]interface{}
+	if err := json.Unmarshal(raw, &frame); err != nil || frame["jsonrpc"] != "2.0" {
+		return nil, fmt.Errorf("%w: jsonrpc must be \"2.0\"", inspector.ErrInvalidFrame)
+	}
+	return fixtureExchange(), nil
+}
+
+func (stubInspector) ResendFrame(ctx context.Context, sessionID, frameID string, edited json.RawMessage) (*inspector.RawExchange, error) {
+	if frameID != fixtureFrameID {
+		return nil, inspector.ErrFrameNotFound
+	}
+	return fixtureExchange(), nil
+}
+
+func (stubInspector) AttachServer(ctx context.Context, sessionID, serverID, serverName string) error {
+	return nil
+}
+
+func (stubInspector) DetachServer(ctx context.Context, sessionID, serverID string) error {
+	if serverID != fixtureAttachedServerID {
+		return inspector.ErrServerNotAttached
+	}
+	return nil
+}
+
+func (stubInspector) Broadcast(ctx context.Context, sessionID string, req inspector.BroadcastRequest) (*inspector.BroadcastResponse, error) {
+	return &inspector.BroadcastResponse{
+		Timestamp: fixtureLaterTime,
+		ID:        "broadcast-1",
+		Method:    req.Method,
+		Results: []*inspector.BroadcastResult{
+			{Response: fixtureInspectorResponse(), ServerID: fixtureServerID, ServerName: "github", Primary: true},
+			{ServerID: fixtureAttachedServerID, ServerName: "jira", Error: "request timed out"},
+		},
+	}, nil
+}
+
+// stubNamespaceServers lists the servers of the fixture namespace
+type stubNamespaceServers struct{}
+
+func (stubNamespaceServers) ListNamespaceServers(ctx context.Context, orgID, namespaceID string) ([]types.NamespaceServer, error) {
+	return []types.NamespaceServer{
+		{ServerID: fixtureServerID, ServerName: "github", Status: "ACTIVE", JoinedAt: fixtureTime},
+		{ServerID: fixtureAttachedServerID, ServerName: "jira", Status: "ACTIVE", JoinedAt: fixtureTime},
+	}, nil
+}
+
+// stubInspectorHistory holds one inspector call of the fixture session
+type stubInspectorHistory struct{}
+
+func (stubInspectorHistory) Record(ctx context.Context, execution *types.PlaygroundExecution) error {
+	return nil
+}
+
+func (stubInspectorHistory) OwnExecution(ctx context.Context, orgID, userID, id string) (*types.PlaygroundExecution, error) {
+	if id != fixtureExecutionID {
+		return nil, notFound("Execution")
+	}
+	execution := fixturePlaygroundExecution()
+	execution.Source = types.PlaygroundSourceInspector
+	execution.EndpointID = ""
+	execution.ServerID = fixtureServerID
+	return execution, nil
+}
+
+func fixtureInspectorCollection() *types.InspectorCollection {
+	return &types.InspectorCollection{
+		CreatedAt: fixtureTime,
+		UpdatedAt: fixtureLaterTime,
+		Requests: []types.InspectorCollectionRequest{
+			{Name: "list tools", Method: "tools/list"},
+			{Name: "initialized", Method: "notifications/initialized", Notification: true},
+		},
+		ID:             fixtureCollectionID,
+		OrganizationID: fixtureOrgID,
+		ServerID:       fixtureServerID,
+		Name:           "smoke test",
+		Description:    "Lists the tools after initializing",
+		CreatedBy:      fixtureUserID,
+	}
+}
+
+// stubInspectorCollections holds the fixture collection of the fixture
+// server
+type stubInspectorCollections struct{}
+
+func (stubInspectorCollections) ListCollections(ctx context.Context, orgID, serverID string) ([]*types.InspectorCollection, error) {
+	return []*types.InspectorCollection{fixtureInspectorCollection()}, nil
+}
+
+func (stubInspectorCollections) GetCollection(ctx context.Context, orgID, id string) (*types.InspectorCollection, error) {
+	switch id {
+	case fixtureCollectionID:
+		return fixtureInspectorCollection(), nil
+	case fixtureOtherServerID:
+		collection := fixtureInspectorCollection()
+		collection.ID = fixtureOtherServerID
+		collection.ServerID = fixtureOtherServerID
+		return collection, nil
+	}
+	return nil, notFound("Inspector collection")
+}
+
+func (stubInspectorCollections) CreateCollection(ctx context.Context, orgID, serverID, userID string, req *types.CreateInspectorCollectionRequest) (*types.InspectorCollection, error) {
+	collection := fixtureInspectorCollection()
+	collection.UpdatedAt = fixtureTime
+	collection.Name = req.Name
+	collection.Description = req.Description
+	collection.Requests = req.Requests
+	return collection, nil
+}
+
+func (stubInspectorCollections) UpdateCollection(ctx context.Context, orgID, id string, req *types.UpdateInspectorCollectionRequest) (*types.InspectorCollection, error) {
+	collection := fixtureInspectorCollection()
+	if req.Name != "" {
+		collection.Name = req.Name
+	}
+	if req.Requests != nil {
+		collection.Requests = *req.Requests
+	}
+	return collection, nil
+}
+
+func (stubInspectorCollections) DeleteCollection(ctx context.Context, orgID, id string) error {
+	if id != fixtureCollectionID {
+		return notFound("Inspector collection")
+	}
+	return nil
+}
+
+func TestInspectorContracts(t *testing.T) {
+	h := handlers.NewInspectorHandler(stubInspector{})
+	h.SetHistory(stubInspectorHistory{})
+	h.SetFrames(stubInspector{})
+	h.SetCollections(stubInspectorCollections{})
+	h.SetBroadcast(stubInspector{}, stubNamespaceServers{})
+	collections := handlers.NewInspectorCollectionHandler(stubInspectorCollections{})
+
+	r := newContractRouter()
+	r.POST("/api/inspector/sessions", h.CreateSession)
+	r.GET("/api/inspector/sessions/:id", h.GetSession)
+	r.DELETE("/api/inspector/sessions/:id", h.CloseSession)
+	r.POST("/api/inspector/sessions/:id/request", h.ExecuteRequest)
+	r.POST("/api/inspector/sessions/:id/history/:execution_id/rerun", h.RerunExecution)
+	r.POST("/api/inspector/sessions/:id/servers", h.AttachServers)
+	r.DELETE("/api/inspector/sessions/:id/servers/:server_id", h.DetachServer)
+	r.POST("/api/inspector/sessions/:id/broadcast", h.Broadcast)
+	r.GET("/api/inspector/sessions/:id/frames", h.ListFrames)
+	r.GET("/api/inspector/sessions/:id/frames/:frame_id", h.GetFrame)
+	r.POST("/api/inspector/sessions/:id/raw", h.SendRaw)
+	r.POST("/api/inspector/sessions/:id/frames/:frame_id/resend", h.ResendFrame)
+	r.GET("/api/inspector/servers/:id/collections", collections.ListCollections)
+	r.POST("/api/inspector/servers/:id/collections", collections.CreateCollection)
+	r.GET("/api/inspector/collections/:collection_id", collections.GetCollection)
+	r.PUT("/api/inspector/collections/:collection_id", collections.UpdateCollection)
+	r.DELETE("/api/inspector/collections/:collection_id", collections.DeleteCollection)
+	r.POST("/api/inspector/sessions/:id/collections/:collection_id/run", h.RunCollection)
+	r.GET("/api/inspector/sessions/:id/events", h.StreamEvents)
+	r.GET("/api/inspector/sessions/:id/ws", h.HandleWebSocket)
+	r.GET("/api/inspector/servers/:id/capabilities", h.GetServerCapabilities)
+
+	session := "/api/inspector/sessions/" + fixtureInspectorSessionID
+	foreign := "/api/inspector/sessions/" + fixtureForeignSessionID
+	missing := "/api/inspector/sessions/" + missingID
+	collection := "/api/inspector/collections/" + fixtureCollectionID
+	r.run(t, "inspector", []contractCase{
+		// The handler reads the organization from org_id, which the auth
+		// middleware does not set
+		{name: "create", method: http.MethodPost, path: "/api/inspector/sessions",
+			body: map[string]interface{}{"server_id": fixtureServerID, "namespace_id": fixtureNamespaceID}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/inspector/sessions", body: map[string]interface{}{}},
+		{name: "get", method: http.MethodGet, path: session},
+		{name: "get-not-found", method: http.MethodGet, path: missing},
+		{name: "get-forbidden", method: http.MethodGet, path: foreign},
+		{name: "request", method: http.MethodPost, path: session + "/request",
+			body: map[string]interface{}{"method": "tools/call", "params": map[string]interface{}{"name": "list_issues"}}},
+		{name: "request-invalid", method: http.MethodPost, path: session + "/request", body: map[string]interface{}{}},
+		{name: "request-forbidden", method: http.MethodPost, path: foreign + "/request",
+			body: map[string]interface{}{"method": "tools/list"}},
+		{name: "rerun", method: http.MethodPost, path: session + "/history/" + fixtureExecutionID + "/rerun"},
+		{name: "rerun-not-found", method: http.MethodPost, path: session + "/history/" + missingID + "/rerun"},
+		{name: "attach", method: http.MethodPost, path: session + "/servers"},
+		{name: "attach-outside-namespace", method: http.MethodPost, path: session + "/servers",
+			body: map[string]interface{}{"server_ids": []string{fixtureOtherServerID}}},
+		{name: "detach", method: http.MethodDelete, path: session + "/servers/" + fixtureAttachedServerID},
+		{name: "detach-not-attached", method: http.MethodDelete, path: session + "/servers/" + fixtureOtherServerID},
+		{name: "broadcast", method: http.MethodPost, path: session + "/broadcast",
+			body: map[string]interface{}{"method": "tools/list"}},
+		{name: "broadcast-invalid", method: http.MethodPost, path: session + "/broadcast", body: map[string]interface{}{}},
+		{name: "frames", method: http.MethodGet, path: session + "/frames", query: "direction=inbound"},
+		{name: "frames-not-found", method: http.MethodGet, path: missing + "/frames"},
+		{name: "frame", method: http.MethodGet, path: session + "/frames/" + fixtureFrameID},
+		{name: "frame-not-found", method: http.MethodGet, path: session + "/frames/" + missingID},
+		{name: "raw", method: http.MethodPost, path: session + "/raw",
+			body: map[string]interface{}{"frame": map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}}},
+		{name: "raw-invalid-frame", method: http.MethodPost, path: session + "/raw",
+			body: map[string]interface{}{"frame": map[string]interface{}{"id": 1, "method": "tools/list"}}},
+		{name: "resend", method: http.MethodPost, path: session + "/frames/" + fixtureFrameID + "/resend"},
+		{name: "resend-not-found", method: http.MethodPost, path: session + "/frames/" + missingID + "/resend"},
+		{name: "collections", method: http.MethodGet, path: "/api/inspector/servers/" + fixtureServerID + "/collections"},
+		{name: "collection-create", method: http.MethodPost, path: "/api/inspector/servers/" + fixtureServerID + "/collections",
+			body: map[string]interface{}{"name": "smoke test", "requests": []map[string]interface{}{{"name": "list tools", "method": "tools/list"}}}},
+		{name: "collection-create-invalid", method: http.MethodPost, path: "/api/inspector/servers/" + fixtureServerID + "/collections",
+			body: map[string]interface{}{"requests": []map[string]interface{}{{"name": "list tools"}}}},
+		{name: "collection", method: http.MethodGet, path: collection},
+		{name: "collection-not-found", method: http.MethodGet, path: "/api/inspector/collections/" + missingID},
+		{name: "collection-update", method: http.MethodPut, path: collection,
+			body: map[string]interface{}{"name": "tools smoke test"}},
+		{name: "collection-delete", method: http.MethodDelete, path: collection},
+		{name: "collection-delete-not-found", method: http.MethodDelete, path: "/api/inspector/collections/" + missingID},
+		{name: "run", method: http.MethodPost, path: session + "/collections/" + fixtureCollectionID + "/run"},
+		{name: "run-other-server", method: http.MethodPost, path: session + "/collections/" + fixtureOtherServerID + "/run"},
+		{name: "events-not-found", method: http.MethodGet, path: missing + "/events"},
+		{name: "events-forbidden", method: http.MethodGet, path: foreign + "/events"},
+		{name: "ws-not-found", method: http.MethodGet, path: missing + "/ws"},
+		{name: "ws-forbidden", method: http.MethodGet, path: foreign + "/ws"},
+		{name: "capabilities", method: http.MethodGet, path: "/api/inspector/servers/" + fixtureServerID + "/capabilities"},
+		{name: "close", method: http.MethodDelete, path: session},
+		{name: "close-not-found", method: http.MethodDelete, path: missing},
+	})
+}
@@ -0,0 +1,161 @@
+package contract
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	fixtureJobID  = "b6a5f4e3-d2c1-4b0a-9f8e-7d6c5b4a3f62"
+	fixtureToolID = "a3f2e1d0-c9b8-4a7f-8e6d-5c4b3a2f1e73"
+)
+
+func fixtureToolJob() *types.ToolJob {
+	return &types.ToolJob{
+		CreatedAt:  fixtureTime,
+		UpdatedAt:  fixtureLaterTime,
+		RunAfter:   fixtureTime,
+		StartedAt:  &fixtureTime,
+		FinishedAt: &fixtureLaterTime,
+		Arguments:  map[string]interface{}{"query": "refund"},
+		Result: &types.NamespaceToolResult{
+			Success: true,
+			Result:  map[string]interface{}{"content": []interface{}{map[string]interface{}{"type": "text", "text": "2 tickets found"}}},
+		},
+		CreatedBy:           &fixtureUser,
+		ID:                  fixtureJobID,
+		OrganizationID:      fixtureOrgID,
+		NamespaceID:         fixtureNamespaceID,
+		Tool:                "tickets__search_tickets",
+		IdempotencyKey:      "nightly-refund-report",
+		Status:              types.ToolJobStatusSucceeded,
+		Attempts:            1,
+		MaxAttempts:         3,
+		TimeoutSeconds:      600,
+		RetryBackoffSeconds: 30,
+	}
+}
+
+// stubToolJobs holds the fixture job, which already succeeded
+type stubToolJobs struct{}
+
+func (stubToolJobs) Enqueue(ctx context.Context, orgID, namespaceID, userID string, req types.ExecuteNamespaceToolRequest) (*types.ToolJob, error) {
+	if namespaceID == missingID {
+		return nil, notFound("Namespace")
+	}
+	job := fixtureToolJob()
+	job.StartedAt, job.FinishedAt, job.Result = nil, nil, nil
+	job.Status = types.ToolJobStatusQueued
+	job.Attempts = 0
+	job.Tool = req.Tool
+	job.Arguments = req.Arguments
+	job.IdempotencyKey = req.IdempotencyKey
+	return job, nil
+}
+
+func (stubToolJobs) Get(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, id string) (*types.ToolJob, error) {
+	if id == missingID {
+		return nil, notFound("Job")
+	}
+	return fixtureToolJob(), nil
+}
+
+func (stubToolJobs) List(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, status string, limit int) ([]*types.ToolJob, error) {
+	if status != "" && status != types.ToolJobStatusSucceeded {
+		return []*types.ToolJob{}, nil
+	}
+	return []*types.ToolJob{fixtureToolJob()}, nil
+}
+
+func (stubToolJobs) Cancel(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, id string) (*types.ToolJob, error) {
+	if id == fixtureJobID {
+		return nil, types.NewConflictError("job already finished")
+	}
+	return nil, notFound("Job")
+}
+
+func (stubToolJobs) GetSettings(ctx context.Context, orgID, toolID string) (*types.ToolJobSettings, error) {
+	if toolID != fixtureToolID {
+		return &types.ToolJobSettings{ToolID: toolID, MaxAttempts: 3, TimeoutSeconds: 300, RetryBackoffSeconds: 10, Default: true}, nil
+	}
+	return &types.ToolJobSettings{UpdatedAt: &fixtureLaterTime, ToolID: toolID, MaxAttempts: 3, TimeoutSeconds: 600, RetryBackoffSeconds: 30}, nil
+}
+
+func (stubToolJobs) SetSettings(ctx context.Context, orgID, toolID string, req *types.SetToolJobSettingsRequest) (*types.ToolJobSettings, error) {
+	return &types.ToolJobSettings{
+		UpdatedAt:           &fixtureLaterTime,
+		ToolID:              toolID,
+		MaxAttempts:         req.MaxAttempts,
+		TimeoutSeconds:      req.TimeoutSeconds,
+		RetryBackoffSeconds: req.RetryBackoffSeconds,
+	}, nil
+}
+
+func (stubToolJobs) DeleteSettings(ctx context.Context, orgID, toolID string) error {
+	return nil
+}
+
+func TestToolJobContracts(t *testing.T) {
+	h := handlers.NewToolJobHandler(stubToolJobs{})
+	r := newContractRouter()
+	r.POST("/api/namespaces/:id/jobs", h.EnqueueJob)
+	r.GET("/api/jobs", h.ListJobs)
+	r.GET("/api/jobs/:id", h.GetJob)
+	r.DELETE("/api/jobs/:id", h.CancelJob)
+	r.GET("/api/gateway/tools/:id/job-settings", h.GetSettings)
+	r.PUT("/api/gateway/tools/:id/job-settings", h.SetSettings)
+	r.DELETE("/api/gateway/tools/:id/job-settings", h.DeleteSettings)
+
+	enqueue := "/api/namespaces/" + fixtureNamespaceID + "/jobs"
+	settings := "/api/gateway/tools/" + fixtureToolID + "/job-settings"
+	r.run(t, "jobs", []contractCase{
+		{name: "enqueue", method: http.MethodPost, path: enqueue,
+			body:    map[string]interface{}{"tool": "tickets__search_tickets", "arguments": map[string]interface{}{"query": "refund"}},
+			headers: map[string]string{handlers.IdempotencyKeyHeader: "nightly-refund-report"}},
+		{name: "enqueue-invalid", method: http.MethodPost, path: enqueue, body: map[string]interface{}{"arguments": map[string]interface{}{}}},
+		{name: "enqueue-not-found", method: http.MethodPost, path: "/api/namespaces/" + missingID + "/jobs",
+			body: map[string]interface{}{"tool": "tickets__search_tickets"}},
+		{name: "list", method: http.MethodGet, path: "/api/jobs", query: "status=succeeded&limit=20"},
+		{name: "list-invalid", method: http.MethodGet, path: "/api/jobs", query: "limit=twenty"},
+		{name: "get", method: http.MethodGet, path: "/api/jobs/" + fixtureJobID},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/jobs/" + missingID},
+		{name: "cancel-finished", method: http.MethodDelete, path: "/api/jobs/" + fixtureJobID},
+		{name: "cancel-not-found", method: http.MethodDelete, path: "/api/jobs/" + missingID},
+		{name: "settings", method: http.MethodGet, path: settings},
+		{name: "settings-default", method: http.MethodGet, path: "/api/gateway/tools/" + missingID + "/job-settings"},
+		{name: "settings-set", method: http.MethodPut, path: settings,
+			body: map[string]interface{}{"max_attempts": 5, "timeout_seconds": 900, "retry_backoff_seconds": 60}},
+		{name: "settings-set-invalid", method: http.MethodPut, path: settings, body: map[string]interface{}{"max_attempts": 20}},
+		{name: "settings-delete", method: http.MethodDelete, path: settings},
+	})
+}
+
+// TestSandboxContracts covers the console's errors and tool listing. A
+// successful call reports when it ran and how long it took, which differs
+// on every run, so it has no golden file.
+func TestSandboxContracts(t *testing.T) {
+	h := handlers.NewSandboxHandler(stubEndpoints{}, stubNamespaces{},
+		types.SandboxLimits{RequestsPerMinute: 20, TimeoutSeconds: 10, MaxArgumentBytes: 64})
+	r := newContractRouter()
+	endpoints := r.Group("/api/endpoints")
+	endpoints.GET("/:id/sandbox/tools", h.ListTools)
+	endpoints.POST("/:id/sandbox/tools/:tool_name", h.ExecuteTool)
+	endpoints.POST("/:id/sandbox/history/:execution_id/rerun", h.RerunExecution)
+
+	base := "/api/endpoints/" + fixtureEndpointID + "/sandbox"
+	r.run(t, "sandbox", []contractCase{
+		{name: "tools", method: http.MethodGet, path: base + "/tools"},
+		{name: "tools-not-found", method: http.MethodGet, path: "/api/endpoints/" + missingID + "/sandbox/tools"},
+		{name: "execute-not-found", method: http.MethodPost, path: "/api/endpoints/" + missingID + "/sandbox/tools/tickets__search_tickets",
+			body: map[string]interface{}{"arguments": map[string]interface{}{"query": "refund"}}},
+		{name: "execute-too-large", method: http.MethodPost, path: base + "/tools/tickets__search_tickets",
+			body: map[string]interface{}{"arguments": map[string]interface{}{"query": strings.Repeat("refund ", 20)}}},
+		{name: "execute-invalid", method: http.MethodPost, path: base + "/tools/tickets__search_tickets", body: `{"arguments":`},
+		{name: "rerun-not-found", method: http.MethodPost, path: base + "/history/" + fixtureExecutionID + "/rerun"},
+	})
+}
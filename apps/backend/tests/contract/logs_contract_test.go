@@ -0,0 +1,760 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixtureLogViewID     = "d0c9b8a7-f6e5-4d4c-9b3a-2f1e0d9c8b7a"
+	fixtureLogExportID   = "a7b8c9d0-e1f2-4a3b-8c4d-5e6f7a8b9c0d"
+	fixtureArchiveID     = "9c8b7a6f-5e4d-4c3b-8a2f-1e0d9c8b7a6f"
+	fixtureRehydrationID = "6f5e4d3c-2b1a-4f0e-9d8c-7b6a5f4e3d2c"
+	fixtureMessageID     = "3c2b1a0f-9e8d-4c7b-8a6f-5e4d3c2b1a0f"
+	fixtureAnchorID      = "1a0f9e8d-7c6b-4a5f-9e4d-3c2b1a0f9e8d"
+)
+
+// fixtureLogBackend is a log storage backend holding fixture request and
+// tool execution logs, so that handlers built on logging.Service have
+// something to query
+type fixtureLogBackend struct{}
+
+func fixtureLogEntries() []*logging.LogEntry {
+	principal := &types.Principal{
+		Labels:         types.Labels{"team": "support"},
+		Type:           types.PrincipalTypeAPIKey,
+		APIKeyID:       fixtureAPIKeyID,
+		OrganizationID: fixtureOrgID,
+	}
+	return []*logging.LogEntry{
+		{
+			ID:         "log-1",
+			Timestamp:  fixtureTime,
+			Level:      logging.LogLevel("info"),
+			Message:    "POST /servers/tickets/mcp",
+			Logger:     logging.LoggerRequest,
+			OrgID:      fixtureOrgID,
+			StatusCode: http.StatusOK,
+			Data: map[string]interface{}{"method": "POST", "path": "/servers/tickets/mcp", "server_id": fixtureServerID,
+				"client": "claude-desktop", "client_version": "0.9.2"},
+			Principal: principal,
+		},
+		{
+			ID:         "log-2",
+			Timestamp:  fixtureLaterTime,
+			Level:      logging.LogLevel("error"),
+			Message:    "POST /servers/tickets/mcp",
+			Logger:     logging.LoggerRequest,
+			OrgID:      fixtureOrgID,
+			StatusCode: http.StatusBadGateway,
+			Data: map[string]interface{}{"method": "POST", "path": "/servers/tickets/mcp", "server_id": fixtureServerID,
+				"client": "cursor"},
+			Principal: principal,
+		},
+		{
+			ID:         "log-3",
+			Timestamp:  fixtureLaterTime,
+			Level:      logging.LogLevel("info"),
+			Message:    "tool tickets__search executed",
+			Logger:     logging.LoggerToolExecution,
+			OrgID:      fixtureOrgID,
+			EntityName: "tickets__search",
+			Data:       map[string]interface{}{"tool": "tickets__search", "success": true},
+			Principal:  &types.Principal{Type: types.PrincipalTypeUser, UserID: fixtureUserID, OrganizationID: fixtureOrgID},
+		},
+	}
+}
+
+func (fixtureLogBackend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	return nil
+}
+
+func (fixtureLogBackend) Store(ctx context.Context, entry *logging.LogEntry) error { return nil }
+
+func (fixtureLogBackend) StoreBatch(ctx context.Context, entries []*logging.LogEntry) error {
+	return nil
+}
+
+func (fixtureLogBackend) Query(ctx context.Context, query *logging.QueryRequest) ([]*logging.LogEntry, error) {
+	return fixtureLogEntries(), nil
+}
+
+func (fixtureLogBackend) Close() error                          { return nil }
+func (fixtureLogBackend) HealthCheck(ctx context.Context) error { return nil }
+
+func (fixtureLogBackend) GetCapabilities() logging.BackendCapabilities {
+	return logging.BackendCapabilities{SupportsQuery: true}
+}
+
+// fixtureLogPlugin registers fixtureLogBackend as the "contract-fixtures"
+// log backend
+type fixtureLogPlugin struct{}
+
+func (fixtureLogPlugin) Create() logging.StorageBackend { return fixtureLogBackend{} }
+func (fixtureLogPlugin) GetName() string                { return "contract-fixtures" }
+func (fixtureLogPlugin) GetDescription() string         { return "fixture logs for contract tests" }
+
+func (fixtureLogPlugin) ValidateConfig(config map[string]interface{}) error { return nil }
+
+var registerFixtureLogs sync.Once
+
+// newFixtureLogService creates a logging.Service whose queries return
+// fixtureLogEntries
+func newFixtureLogService(t *testing.T) *logging.Service {
+	t.Helper()
+	registerFixtureLogs.Do(func() {
+		require.NoError(t, logging.RegisterPlugin(fixtureLogPlugin{}))
+	})
+	logs, err := logging.NewService(&logging.LoggingConfig{Backend: "contract-fixtures", Level: logging.LogLevel("info")})
+	require.NoError(t, err)
+	return logs.(*logging.Service)
+}
+
+// stubAdminStats serves fixed activity aggregates
+type stubAdminStats struct{}
+
+func (stubAdminStats) GetStats(ctx context.Context, orgID string, days int) (*types.AdminStats, error) {
+	return &types.AdminStats{
+		Since:            fixtureTime,
+		TopTools:         []*types.ToolUsageStat{{Tool: "tickets__search", Calls: 120, Errors: 3, ErrorRate: 0.025, AvgDurationMS: 84}},
+		ServerErrorRates: []*types.ServerErrorStat{{ServerID: fixtureServerID, ServerName: "tickets", Requests: 400, Errors: 8, ErrorRate: 0.02, AvgDurationMS: 97}},
+		Freshness:        []*types.StatsFreshness{{RefreshedAt: &fixtureLaterTime, View: types.StatsViewToolUsage, AgeSeconds: 120}},
+		Users:            types.UserStats{Total: 12, Active: 5},
+		Servers:          types.ServerCounts{Total: 4, Healthy: 3},
+		Requests:         types.RequestTotals{Total: 400, Successful: 392, Failed: 8},
+		Days:             7,
+	}, nil
+}
+
+func TestAdminLogContracts(t *testing.T) {
+	h := handlers.NewAdminHandler(nil, newFixtureLogService(t), stubConfigTransfer{}, stubAuthConfig{})
+	h.SetStatsSource(stubAdminStats{})
+	h.SetMetricsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprint(w, "# TYPE omnimesh_requests_total counter\nomnimesh_requests_total 400\n")
+	}))
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/logs", h.GetLogs)
+	admin.GET("/stats", h.GetStats)
+	admin.GET("/stats/principals", h.GetPrincipalUsage)
+	admin.GET("/stats/labels", h.GetLabelUsage)
+	admin.GET("/stats/clients", h.GetClientUsage)
+	admin.GET("/stats/servers", h.GetServerUsage)
+	admin.GET("/stats/tools", h.GetToolUsage)
+	admin.GET("/metrics", h.GetMetrics)
+
+	r.run(t, "admin-logs", []contractCase{
+		{name: "logs", method: http.MethodGet, path: "/api/admin/logs", query: "level=error&limit=50"},
+		{name: "stats", method: http.MethodGet, path: "/api/admin/stats", query: "days=7"},
+		{name: "stats-by-server", method: http.MethodGet, path: "/api/admin/stats", query: "group_by=server"},
+		{name: "stats-invalid-days", method: http.MethodGet, path: "/api/admin/stats", query: "days=week"},
+		{name: "principals", method: http.MethodGet, path: "/api/admin/stats/principals"},
+		{name: "labels", method: http.MethodGet, path: "/api/admin/stats/labels", query: "key=team"},
+		{name: "labels-missing-key", method: http.MethodGet, path: "/api/admin/stats/labels"},
+		{name: "clients", method: http.MethodGet, path: "/api/admin/stats/clients"},
+		{name: "servers", method: http.MethodGet, path: "/api/admin/stats/servers"},
+		{name: "tools", method: http.MethodGet, path: "/api/admin/stats/tools"},
+		{name: "metrics", method: http.MethodGet, path: "/api/admin/metrics"},
+	})
+}
+
+func fixtureLogView() *types.SavedLogView {
+	return &types.SavedLogView{
+		CreatedAt:      fixtureTime,
+		UpdatedAt:      fixtureLaterTime,
+		Filters:        types.LogViewFilters{Window: "24h", Level: "error", Labels: map[string]string{"team": "support"}},
+		Widgets:        []types.LogWidget{{Type: types.LogWidgetErrorRateByServer, Title: "Errors by server", Limit: 5}},
+		ID:             fixtureLogViewID,
+		OrganizationID: fixtureOrgID,
+		OwnerID:        fixtureUserID,
+		Name:           "Support errors",
+		Kind:           types.LogViewKindLogs,
+		Shared:         true,
+	}
+}
+
+// stubLogViews serves the fixture log view
+type stubLogViews struct{}
+
+func (stubLogViews) ListViews(ctx context.Context, orgID, userID, kind string) ([]*types.SavedLogView, error) {
+	return []*types.SavedLogView{fixtureLogView()}, nil
+}
+
+func (stubLogViews) GetView(ctx context.Context, orgID, userID, id string) (*types.SavedLogView, error) {
+	if id == missingID {
+		return nil, notFound("Saved log view")
+	}
+	return fixtureLogView(), nil
+}
+
+func (stubLogViews) CreateView(ctx context.Context, orgID, userID string, req *types.CreateLogViewRequest) (*types.SavedLogView, error) {
+	view := fixtureLogView()
+	view.Name = req.Name
+	view.Kind = req.Kind
+	view.Filters = req.Filters
+	view.Widgets = req.Widgets
+	view.Shared = req.Shared
+	return view, nil
+}
+
+func (stubLogViews) UpdateView(ctx context.Context, orgID, userID, id string, req *types.UpdateLogViewRequest) (*types.SavedLogView, error) {
+	if id == missingID {
+		return nil, notFound("Saved log view")
+	}
+	view := fixtureLogView()
+	if req.Shared != nil {
+		view.Shared = *req.Shared
+	}
+	return view, nil
+}
+
+func (stubLogViews) DeleteView(ctx context.Context, orgID, userID, id string) error {
+	if id == missingID {
+		return notFound("Saved log view")
+	}
+	return nil
+}
+
+func (stubLogViews) RunView(ctx context.Context, orgID, userID, id string, limit, offset int) (*services.LogViewResults, error) {
+	return &services.LogViewResults{
+		StartTime: fixtureTime,
+		EndTime:   fixtureLaterTime,
+		View:      fixtureLogView(),
+		Logs:      fixtureLogEntries()[1:2],
+	}, nil
+}
+
+func (stubLogViews) Widgets(ctx context.Context, orgID, userID, id string) ([]*types.LogWidgetResult, error) {
+	return []*types.LogWidgetResult{{
+		Widget: fixtureLogView().Widgets[0],
+		Data:   logging.SummarizeByServer(fixtureLogEntries()),
+	}}, nil
+}
+
+func TestLogViewContracts(t *testing.T) {
+	h := handlers.NewLogViewHandler(stubLogViews{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/log-views", h.ListViews)
+	admin.POST("/log-views", h.CreateView)
+	admin.GET("/log-views/:id", h.GetView)
+	admin.PUT("/log-views/:id", h.UpdateView)
+	admin.DELETE("/log-views/:id", h.DeleteView)
+	admin.GET("/log-views/:id/results", h.RunView)
+	admin.GET("/log-views/:id/widgets", h.GetWidgets)
+
+	base := "/api/admin/log-views/" + fixtureLogViewID
+	r.run(t, "log-views", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/log-views", query: "kind=logs"},
+		{name: "create", method: http.MethodPost, path: "/api/admin/log-views",
+			body: map[string]interface{}{"name": "Support errors", "kind": "logs", "shared": true,
+				"filters": map[string]interface{}{"window": "24h", "level": "error"},
+				"widgets": []map[string]interface{}{{"type": "top_tools", "limit": 5}}}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/log-views",
+			body: map[string]interface{}{"name": "Support errors", "kind": "metrics"}},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/log-views/" + missingID},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"shared": false}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "results", method: http.MethodGet, path: base + "/results", query: "limit=20"},
+		{name: "widgets", method: http.MethodGet, path: base + "/widgets"},
+	})
+}
+
+func fixtureLogExport() *types.LogExport {
+	return &types.LogExport{
+		CreatedAt:      fixtureLaterTime,
+		StartTime:      fixtureTime,
+		EndTime:        fixtureLaterTime,
+		StartedAt:      &fixtureLaterTime,
+		CompletedAt:    &fixtureLaterTime,
+		ExpiresAt:      &fixtureLaterTime,
+		Filters:        types.LogViewFilters{Level: "error"},
+		ID:             fixtureLogExportID,
+		OrganizationID: fixtureOrgID,
+		RequestedBy:    fixtureUserID,
+		Format:         types.LogExportFormatCSV,
+		Status:         types.LogExportStatusCompleted,
+		StorageKey:     "exports/never-serialized.csv",
+		DownloadURL:    "https://gateway.example.com/api/public/log-exports/" + fixtureLogExportID + "/download?expires=1773078300&signature=sig",
+		RowCount:       2,
+		SizeBytes:      int64(len(fixtureExportCSV)),
+	}
+}
+
+const fixtureExportCSV = "timestamp,level,message\n2026-03-09T17:45:00Z,error,POST /servers/tickets/mcp\n"
+
+// stubLogExports serves the fixture export
+type stubLogExports struct{}
+
+func (stubLogExports) CreateExport(ctx context.Context, orgID, userID string, req *types.CreateLogExportRequest) (*types.LogExport, error) {
+	export := fixtureLogExport()
+	export.Format = req.Format
+	export.Status = types.LogExportStatusPending
+	export.StartedAt, export.CompletedAt, export.ExpiresAt = nil, nil, nil
+	export.DownloadURL = ""
+	export.RowCount, export.SizeBytes = 0, 0
+	return export, nil
+}
+
+func (stubLogExports) ListExports(ctx context.Context, orgID string) ([]*types.LogExport, error) {
+	return []*types.LogExport{fixtureLogExport()}, nil
+}
+
+func (stubLogExports) GetExport(ctx context.Context, orgID, id string) (*types.LogExport, error) {
+	if id == missingID {
+		return nil, notFound("Log export")
+	}
+	return fixtureLogExport(), nil
+}
+
+func (stubLogExports) PrepareStream(ctx context.Context, orgID string, req *types.CreateLogExportRequest) (*services.LogExportStream, error) {
+	return nil, types.NewValidationError("exports are limited to 31 days")
+}
+
+func (stubLogExports) OpenDownload(ctx context.Context, id, expires, signature string) (*types.LogExport, io.ReadCloser, error) {
+	if signature != "sig" {
+		return nil, nil, types.NewUnauthorizedError("invalid or expired download link")
+	}
+	return fixtureLogExport(), io.NopCloser(strings.NewReader(fixtureExportCSV)), nil
+}
+
+func TestLogExportContracts(t *testing.T) {
+	h := handlers.NewLogExportHandler(stubLogExports{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.POST("/log-exports", h.CreateExport)
+	admin.GET("/log-exports", h.ListExports)
+	admin.GET("/log-exports/stream", h.StreamExport)
+	admin.GET("/log-exports/:id", h.GetExport)
+	r.GET("/api/public/log-exports/:id/download", h.Download)
+
+	download := "/api/public/log-exports/" + fixtureLogExportID + "/download"
+	r.run(t, "log-exports", []contractCase{
+		{name: "create", method: http.MethodPost, path: "/api/admin/log-exports",
+			body: map[string]interface{}{"start_time": fixtureTime, "end_time": fixtureLaterTime, "format": "parquet"}},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/log-exports",
+			body: map[string]interface{}{"start_time": fixtureTime, "end_time": fixtureLaterTime, "format": "xlsx"}},
+		{name: "list", method: http.MethodGet, path: "/api/admin/log-exports"},
+		{name: "get", method: http.MethodGet, path: "/api/admin/log-exports/" + fixtureLogExportID},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/log-exports/" + missingID},
+		{name: "stream-invalid", method: http.MethodGet, path: "/api/admin/log-exports/stream", query: "format=csv"},
+		{name: "stream-range-too-long", method: http.MethodGet, path: "/api/admin/log-exports/stream",
+			query: "format=csv&start_time=2026-01-01T00:00:00Z&end_time=2026-03-09T00:00:00Z"},
+		{name: "download", method: http.MethodGet, path: download, query: "expires=1773078300&signature=sig"},
+		{name: "download-bad-signature", method: http.MethodGet, path: download, query: "expires=1773078300&signature=forged"},
+	})
+}
+
+func intPtr(v int) *int { return &v }
+
+func fixtureRetentionPolicy() *types.LogRetentionPolicy {
+	return &types.LogRetentionPolicy{
+		UpdatedAt:      &fixtureLaterTime,
+		RequestLogDays: intPtr(30),
+		AuditLogDays:   intPtr(365),
+		Export: types.RetentionExportConfig{
+			Region:          "eu-west-1",
+			Bucket:          "omnimesh-archive",
+			Prefix:          "retention/",
+			AccessKeyID:     "AKIAFIXTURE",
+			SecretAccessKey: "never-serialized",
+			HasSecret:       true,
+			Enabled:         true,
+		},
+		OrganizationID: fixtureOrgID,
+		UpdatedBy:      fixtureUserID,
+		DefaultDays:    90,
+	}
+}
+
+// stubRetention serves the fixture retention policy
+type stubRetention struct{}
+
+func (stubRetention) GetPolicy(ctx context.Context, orgID string) (*types.LogRetentionPolicy, error) {
+	return fixtureRetentionPolicy(), nil
+}
+
+func (stubRetention) UpdatePolicy(ctx context.Context, orgID, userID string, req *types.UpdateLogRetentionPolicyRequest) (*types.LogRetentionPolicy, error) {
+	if req.Export != nil && req.Export.Endpoint != nil {
+		return nil, types.NewValidationError("export endpoint must be a public host")
+	}
+	policy := fixtureRetentionPolicy()
+	if req.HealthCheckDays != nil {
+		policy.HealthCheckDays = req.HealthCheckDays
+	}
+	return policy, nil
+}
+
+func (stubRetention) ListPurgeWindows(ctx context.Context, orgID string, withinDays int) (*types.RetentionPurgeSchedule, error) {
+	return &types.RetentionPurgeSchedule{
+		Windows: []*types.RetentionPurgeWindow{{
+			Cutoff:   &fixtureTime,
+			Upcoming: []*types.RetentionPurgeDay{{Date: fixtureLaterTime, Rows: 1800}},
+			Table:    types.RetentionTableExecutionLogs,
+			Days:     30,
+			Due:      420,
+			Exported: true,
+		}},
+		OrganizationID: orgID,
+		WithinDays:     withinDays,
+	}, nil
+}
+
+func (stubRetention) ListExports(ctx context.Context, orgID string) ([]*types.RetentionExport, error) {
+	return []*types.RetentionExport{{
+		Cutoff:         fixtureTime,
+		CreatedAt:      fixtureLaterTime,
+		ID:             fixtureArchiveID,
+		OrganizationID: orgID,
+		Table:          types.RetentionTableExecutionLogs,
+		Bucket:         "omnimesh-archive",
+		StorageKey:     "retention/execution_logs/2026-03-02.jsonl.gz",
+		SHA256:         "9f2b7c",
+		RowCount:       420,
+		SizeBytes:      18230,
+	}}, nil
+}
+
+func TestLogRetentionContracts(t *testing.T) {
+	h := handlers.NewLogRetentionHandler(stubRetention{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/log-retention", h.GetPolicy)
+	admin.PUT("/log-retention", h.UpdatePolicy)
+	admin.GET("/log-retention/purge-windows", h.ListPurgeWindows)
+	admin.GET("/log-retention/exports", h.ListExports)
+
+	r.run(t, "log-retention", []contractCase{
+		{name: "get", method: http.MethodGet, path: "/api/admin/log-retention"},
+		{name: "update", method: http.MethodPut, path: "/api/admin/log-retention", body: map[string]interface{}{"health_check_days": 14}},
+		{name: "update-invalid", method: http.MethodPut, path: "/api/admin/log-retention", body: map[string]interface{}{"audit_log_days": -1}},
+		{name: "update-internal-endpoint", method: http.MethodPut, path: "/api/admin/log-retention",
+			body: map[string]interface{}{"export": map[string]interface{}{"endpoint": "http://10.0.0.5:9000"}}},
+		{name: "purge-windows", method: http.MethodGet, path: "/api/admin/log-retention/purge-windows", query: "within_days=7"},
+		{name: "purge-windows-invalid", method: http.MethodGet, path: "/api/admin/log-retention/purge-windows", query: "within_days=soon"},
+		{name: "exports", method: http.MethodGet, path: "/api/admin/log-retention/exports"},
+	})
+}
+
+func fixtureRehydration() *types.ArchiveRehydration {
+	return &types.ArchiveRehydration{
+		CreatedAt:      fixtureLaterTime,
+		StartTime:      fixtureTime,
+		EndTime:        fixtureTime.AddDate(0, 0, 1),
+		CompletedAt:    &fixtureLaterTime,
+		ExpiresAt:      &fixtureLaterTime,
+		ID:             fixtureRehydrationID,
+		OrganizationID: fixtureOrgID,
+		RequestedBy:    fixtureUserID,
+		Status:         types.RehydrationStatusCompleted,
+		Datasets:       types.ArchiveDatasets,
+		ArchiveCount:   2,
+		RowCount:       5120,
+	}
+}
+
+// stubArchives serves the fixture archive and rehydration
+type stubArchives struct{}
+
+func (stubArchives) GetPolicy(ctx context.Context, orgID string) (*types.ArchivePolicy, error) {
+	return &types.ArchivePolicy{OrganizationID: orgID, ArchiveAfterDays: 30}, nil
+}
+
+func (stubArchives) UpdatePolicy(ctx context.Context, orgID, userID string, req *types.UpdateArchivePolicyRequest) (*types.ArchivePolicy, error) {
+	policy := &types.ArchivePolicy{UpdatedAt: &fixtureLaterTime, OrganizationID: orgID, UpdatedBy: userID, ArchiveAfterDays: 30}
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+	if req.ArchiveAfterDays != nil {
+		policy.ArchiveAfterDays = *req.ArchiveAfterDays
+	}
+	return policy, nil
+}
+
+func (stubArchives) ListArchives(ctx context.Context, orgID string, query *types.ArchiveQuery) ([]*types.LogArchive, error) {
+	return []*types.LogArchive{{
+		RangeStart:     fixtureTime,
+		RangeEnd:       fixtureTime.AddDate(0, 0, 1),
+		CreatedAt:      fixtureLaterTime,
+		Methods:        map[string]int64{"tools/call": 3100, "tools/list": 120},
+		ID:             fixtureArchiveID,
+		OrganizationID: orgID,
+		Dataset:        types.ArchiveDatasetExecutionLogs,
+		StorageKey:     "archives/execution_logs/2026-03-02.jsonl.gz",
+		ManifestKey:    "archives/execution_logs/2026-03-02.manifest.json",
+		SHA256:         "4be1d0",
+		RowCount:       3220,
+		ErrorCount:     41,
+		SizeBytes:      220480,
+	}}, nil
+}
+
+func (stubArchives) CreateRehydration(ctx context.Context, orgID, userID string, req *types.CreateRehydrationRequest) (*types.ArchiveRehydration, error) {
+	rehydration := fixtureRehydration()
+	rehydration.Status = types.RehydrationStatusPending
+	rehydration.CompletedAt, rehydration.ExpiresAt = nil, nil
+	rehydration.ArchiveCount, rehydration.RowCount = 0, 0
+	return rehydration, nil
+}
+
+func (stubArchives) ListRehydrations(ctx context.Context, orgID string) ([]*types.ArchiveRehydration, error) {
+	return []*types.ArchiveRehydration{fixtureRehydration()}, nil
+}
+
+func (stubArchives) GetRehydration(ctx context.Context, orgID, id string) (*types.ArchiveRehydration, error) {
+	if id == missingID {
+		return nil, notFound("Rehydration")
+	}
+	return fixtureRehydration(), nil
+}
+
+func (stubArchives) ReleaseRehydration(ctx context.Context, orgID, id string) (*types.ArchiveRehydration, error) {
+	if id == missingID {
+		return nil, notFound("Rehydration")
+	}
+	rehydration := fixtureRehydration()
+	rehydration.Status = types.RehydrationStatusExpired
+	return rehydration, nil
+}
+
+func TestArchiveContracts(t *testing.T) {
+	h := handlers.NewArchiveHandler(stubArchives{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/archive-policy", h.GetPolicy)
+	admin.PUT("/archive-policy", h.UpdatePolicy)
+	admin.GET("/archives", h.ListArchives)
+	admin.POST("/archives/rehydrations", h.CreateRehydration)
+	admin.GET("/archives/rehydrations", h.ListRehydrations)
+	admin.GET("/archives/rehydrations/:id", h.GetRehydration)
+	admin.DELETE("/archives/rehydrations/:id", h.ReleaseRehydration)
+
+	rehydration := "/api/admin/archives/rehydrations/" + fixtureRehydrationID
+	r.run(t, "archives", []contractCase{
+		{name: "get-policy", method: http.MethodGet, path: "/api/admin/archive-policy"},
+		{name: "update-policy", method: http.MethodPut, path: "/api/admin/archive-policy",
+			body: map[string]interface{}{"enabled": true, "archive_after_days": 60}},
+		{name: "update-policy-invalid", method: http.MethodPut, path: "/api/admin/archive-policy",
+			body: map[string]interface{}{"archive_after_days": 0}},
+		{name: "list", method: http.MethodGet, path: "/api/admin/archives", query: "dataset=execution_logs"},
+		{name: "create-rehydration", method: http.MethodPost, path: "/api/admin/archives/rehydrations",
+			body: map[string]interface{}{"start_time": fixtureTime, "end_time": fixtureTime.AddDate(0, 0, 1), "ttl_hours": 24}},
+		{name: "create-rehydration-invalid", method: http.MethodPost, path: "/api/admin/archives/rehydrations",
+			body: map[string]interface{}{"start_time": fixtureTime}},
+		{name: "list-rehydrations", method: http.MethodGet, path: "/api/admin/archives/rehydrations"},
+		{name: "get-rehydration", method: http.MethodGet, path: rehydration},
+		{name: "get-rehydration-not-found", method: http.MethodGet, path: "/api/admin/archives/rehydrations/" + missingID},
+		{name: "release-rehydration", method: http.MethodDelete, path: rehydration},
+	})
+}
+
+// stubLogFieldPolicies serves a metadata-only policy
+type stubLogFieldPolicies struct{}
+
+func (stubLogFieldPolicies) GetPolicy(ctx context.Context, orgID string) (*types.LogFieldPolicy, error) {
+	return &types.LogFieldPolicy{
+		UpdatedAt:      &fixtureLaterTime,
+		OrganizationID: orgID,
+		UpdatedBy:      fixtureUserID,
+		OmitFields:     types.LogFieldPresetOmits(types.LogFieldPresetMetadataOnly),
+	}, nil
+}
+
+func (stubLogFieldPolicies) SetPolicy(ctx context.Context, orgID, updatedBy string, req *types.SetLogFieldPolicyRequest) (*types.LogFieldPolicy, error) {
+	return &types.LogFieldPolicy{
+		UpdatedAt:      &fixtureLaterTime,
+		OrganizationID: orgID,
+		UpdatedBy:      updatedBy,
+		OmitFields:     append(types.LogFieldPresetOmits(req.Preset), req.OmitFields...),
+	}, nil
+}
+
+func (stubLogFieldPolicies) ResetPolicy(ctx context.Context, orgID string) error { return nil }
+
+func TestLogFieldPolicyContracts(t *testing.T) {
+	h := handlers.NewLogFieldPolicyHandler(stubLogFieldPolicies{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/log-field-policy", h.GetPolicy)
+	admin.PUT("/log-field-policy", h.SetPolicy)
+	admin.DELETE("/log-field-policy", h.ResetPolicy)
+
+	r.run(t, "log-field-policy", []contractCase{
+		{name: "get", method: http.MethodGet, path: "/api/admin/log-field-policy"},
+		{name: "set", method: http.MethodPut, path: "/api/admin/log-field-policy",
+			body: map[string]interface{}{"preset": "metadata_only", "omit_fields": []string{"client"}}},
+		{name: "set-invalid", method: http.MethodPut, path: "/api/admin/log-field-policy",
+			body: map[string]interface{}{"omit_fields": []string{"status_code"}}},
+		{name: "reset", method: http.MethodDelete, path: "/api/admin/log-field-policy"},
+	})
+}
+
+func fixtureMCPMessage() *types.MCPMessageLog {
+	return &types.MCPMessageLog{
+		CreatedAt:      fixtureTime,
+		Params:         map[string]interface{}{"name": "tickets__search", "arguments": map[string]interface{}{"query": "refund", "api_token": types.RedactedValue}},
+		ID:             fixtureMessageID,
+		OrganizationID: fixtureOrgID,
+		NamespaceID:    fixtureNamespaceID,
+		SessionID:      "sess_01",
+		Transport:      "streamable_http",
+		Method:         "tools/call",
+		ToolName:       "tickets__search",
+		RPCID:          "7",
+		PrincipalType:  types.PrincipalTypeAPIKey,
+		PrincipalID:    fixtureAPIKeyID,
+		RedactedFields: []string{"arguments.api_token"},
+		StatusCode:     http.StatusOK,
+		DurationMS:     84,
+		RequestSize:    212,
+		ResultSize:     1830,
+	}
+}
+
+// stubMCPMessages serves the fixture MCP message
+type stubMCPMessages struct{}
+
+func (stubMCPMessages) ListMessages(ctx context.Context, orgID string, query *types.MCPMessageLogQuery) ([]*types.MCPMessageLog, error) {
+	return []*types.MCPMessageLog{fixtureMCPMessage()}, nil
+}
+
+func (stubMCPMessages) GetMessage(ctx context.Context, orgID, id string) (*types.MCPMessageLog, error) {
+	if id == missingID {
+		return nil, notFound("MCP message")
+	}
+	return fixtureMCPMessage(), nil
+}
+
+func TestMCPMessageContracts(t *testing.T) {
+	h := handlers.NewMCPMessageLogHandler(stubMCPMessages{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/mcp-messages", h.ListMessages)
+	admin.GET("/mcp-messages/:id", h.GetMessage)
+
+	r.run(t, "mcp-messages", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/mcp-messages", query: "method=tools/call&limit=20"},
+		{name: "list-invalid", method: http.MethodGet, path: "/api/admin/mcp-messages", query: "limit=many"},
+		{name: "get", method: http.MethodGet, path: "/api/admin/mcp-messages/" + fixtureMessageID},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/mcp-messages/" + missingID},
+	})
+}
+
+// stubAuditChain serves an intact audit chain with one record
+type stubAuditChain struct{}
+
+func (stubAuditChain) QueryAuditLogs(query *types.AuditLogQuery) ([]*types.AuditLog, error) {
+	return []*types.AuditLog{{
+		Timestamp:      fixtureTime,
+		Details:        map[string]interface{}{"name": "tickets"},
+		ID:             fixtureSinkID,
+		UserID:         fixtureUserID,
+		OrganizationID: fixtureOrgID,
+		Action:         "register",
+		Resource:       "server",
+		ResourceID:     fixtureServerID,
+		RemoteIP:       "203.0.113.7",
+		UserAgent:      "omnimesh-cli/1.4",
+		Severity:       types.AuditSeverityNotice,
+		PrevHash:       "61c0de",
+		Hash:           "9a4f11",
+		Sequence:       1200,
+		Success:        true,
+	}}, nil
+}
+
+func (stubAuditChain) VerifyChain(orgID string) (*types.AuditChainReport, error) {
+	return &types.AuditChainReport{
+		VerifiedAt:     fixtureLaterTime,
+		OrganizationID: orgID,
+		HeadHash:       "9a4f11",
+		RecordsChecked: 1200,
+		AnchorsChecked: 12,
+		HeadSequence:   1200,
+		Valid:          true,
+	}, nil
+}
+
+func fixtureAnchor() *types.AuditAnchor {
+	return &types.AuditAnchor{
+		CreatedAt:      fixtureLaterTime,
+		ID:             fixtureAnchorID,
+		OrganizationID: fixtureOrgID,
+		RecordHash:     "9a4f11",
+		PrevDigest:     "77b0e2",
+		Digest:         "c41d9e",
+		Sequence:       12,
+	}
+}
+
+func (stubAuditChain) ListAnchors(orgID string, limit int) ([]*types.AuditAnchor, error) {
+	return []*types.AuditAnchor{fixtureAnchor()}, nil
+}
+
+func (stubAuditChain) CreateAnchor(orgID string) (*types.AuditAnchor, error) {
+	return fixtureAnchor(), nil
+}
+
+func TestAuditContracts(t *testing.T) {
+	h := handlers.NewAuditHandler(stubAuditChain{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/audit", h.ListAuditLogs)
+	admin.GET("/audit/verify", h.VerifyChain)
+	admin.GET("/audit/anchors", h.ListAnchors)
+	admin.POST("/audit/anchors", h.CreateAnchor)
+
+	r.run(t, "audit", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/audit", query: "resource_type=server&limit=10"},
+		{name: "list-invalid-severity", method: http.MethodGet, path: "/api/admin/audit", query: "min_severity=loud"},
+		{name: "verify", method: http.MethodGet, path: "/api/admin/audit/verify"},
+		{name: "anchors", method: http.MethodGet, path: "/api/admin/audit/anchors"},
+		{name: "create-anchor", method: http.MethodPost, path: "/api/admin/audit/anchors"},
+	})
+}
+
+// stubServerLogs serves two captured lines and no live output
+type stubServerLogs struct{}
+
+func (stubServerLogs) Tail(ctx context.Context, serverID, stream string, n int) ([]types.ServerLogLine, error) {
+	if serverID == missingID {
+		return nil, errors.New("server is not running")
+	}
+	return []types.ServerLogLine{
+		{Time: fixtureTime, ServerID: serverID, Stream: types.ServerLogStreamStdout, Line: "listening on stdio"},
+		{Time: fixtureLaterTime, ServerID: serverID, Stream: types.ServerLogStreamStderr, Line: "warning: index rebuilding"},
+	}, nil
+}
+
+func (stubServerLogs) Follow(serverID string) (<-chan types.ServerLogLine, func()) {
+	return nil, func() {}
+}
+
+func TestServerLogContracts(t *testing.T) {
+	h := handlers.NewServerLogsHandler(stubServerLogs{})
+	r := newContractRouter()
+	r.GET("/api/gateway/servers/:id/logs", h.GetLogs)
+
+	base := "/api/gateway/servers/" + fixtureServerID + "/logs"
+	r.run(t, "server-logs", []contractCase{
+		{name: "tail", method: http.MethodGet, path: base, query: "tail=2"},
+		{name: "tail-invalid", method: http.MethodGet, path: base, query: "tail=10000"},
+		{name: "invalid-stream", method: http.MethodGet, path: base, query: "stream=stdin"},
+		{name: "invalid-id", method: http.MethodGet, path: "/api/gateway/servers/tickets/logs"},
+	})
+}
@@ -0,0 +1,763 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/buildinfo"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/cache"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixtureFailoverID    = "c7b6a5f4-e3d2-4c1b-8a9f-8e7d6c5b4a4a"
+	fixtureNodeID        = "gw-eu-west-1a-7f9c"
+	fixtureExecutionID   = "9a8f7e6d-5c4b-4a3f-9e2d-1c0b9a8f7e5b"
+	fixtureReplicaToken  = "fixture-replication-token"
+	fixtureFeatureFlag   = "semantic_search"
+	fixturePrimaryRegion = "eu-west-1"
+	fixtureStandbyRegion = "us-east-1"
+)
+
+// replicaAuth is how peer regions authenticate to the replication API
+var replicaAuth = map[string]string{"Authorization": "Bearer " + fixtureReplicaToken}
+
+func fixtureDeploymentState() *types.DeploymentState {
+	return &types.DeploymentState{
+		ChangedAt: &fixtureTime,
+		Region:    fixturePrimaryRegion,
+		Role:      types.DeploymentRoleActive,
+		ChangedBy: fixtureUserID,
+		Epoch:     3,
+	}
+}
+
+func fixtureFailover(dryRun bool) *types.Failover {
+	failover := &types.Failover{
+		StartedAt:   fixtureTime,
+		CompletedAt: &fixtureTime,
+		ID:          fixtureFailoverID,
+		FromRegion:  fixtureStandbyRegion,
+		ToRegion:    fixturePrimaryRegion,
+		Reason:      "us-east-1 outage",
+		InitiatedBy: fixtureUserID,
+		Status:      types.FailoverStatusCompleted,
+		Steps: []types.FailoverStep{
+			{Name: "fence", Status: "completed", Detail: "us-east-1 set read-only"},
+			{Name: "promote", Status: "completed"},
+		},
+		DryRun: dryRun,
+	}
+	if dryRun {
+		failover.ID = ""
+		failover.CompletedAt = nil
+		failover.Steps = []types.FailoverStep{{Name: "fence", Status: "planned"}, {Name: "promote", Status: "planned"}}
+	}
+	return failover
+}
+
+// stubFailover is the active region, which peers fence with the fixture
+// replication token
+type stubFailover struct{}
+
+func (stubFailover) Authorize(token string) bool {
+	return token == fixtureReplicaToken
+}
+
+func (stubFailover) Status(ctx context.Context) (*types.FailoverStatus, error) {
+	return &types.FailoverStatus{State: fixtureDeploymentState(), Failovers: []*types.Failover{fixtureFailover(false)}}, nil
+}
+
+func (stubFailover) Failover(ctx context.Context, req *types.FailoverRequest, initiatedBy string) (*types.Failover, error) {
+	if req.FromRegion == fixturePrimaryRegion {
+		return nil, types.NewValidationError("eu-west-1 is this region")
+	}
+	failover := fixtureFailover(req.DryRun)
+	failover.Reason = req.Reason
+	return failover, nil
+}
+
+func (stubFailover) Fence(ctx context.Context, req *types.FenceRequest, changedBy string) (*types.DeploymentState, error) {
+	state := fixtureDeploymentState()
+	state.Role = types.DeploymentRoleStandby
+	state.ReadOnly = true
+	state.ReadOnlyReason = req.Reason
+	state.FencedBy = req.Region
+	state.ChangedBy = changedBy
+	state.Epoch++
+	return state, nil
+}
+
+func TestFailoverContracts(t *testing.T) {
+	h := handlers.NewFailoverHandler(stubFailover{})
+	r := newContractRouter()
+	r.POST("/api/replication/fence", h.Fence)
+	r.GET("/api/admin/failover", h.GetStatus)
+	r.POST("/api/admin/failover", h.Failover)
+
+	r.run(t, "failover", []contractCase{
+		{name: "status", method: http.MethodGet, path: "/api/admin/failover"},
+		{name: "failover", method: http.MethodPost, path: "/api/admin/failover",
+			body: map[string]interface{}{"from_region": fixtureStandbyRegion, "reason": "us-east-1 outage"}},
+		{name: "failover-dry-run", method: http.MethodPost, path: "/api/admin/failover",
+			body: map[string]interface{}{"from_region": fixtureStandbyRegion, "reason": "us-east-1 outage", "dry_run": true}},
+		{name: "failover-invalid", method: http.MethodPost, path: "/api/admin/failover",
+			body: map[string]interface{}{"from_region": fixtureStandbyRegion}},
+		{name: "failover-own-region", method: http.MethodPost, path: "/api/admin/failover",
+			body: map[string]interface{}{"from_region": fixturePrimaryRegion, "reason": "drill"}},
+		{name: "fence", method: http.MethodPost, path: "/api/replication/fence",
+			body: map[string]interface{}{"region": fixtureStandbyRegion, "reason": "Failover to us-east-1"}, headers: replicaAuth},
+		{name: "fence-unauthorized", method: http.MethodPost, path: "/api/replication/fence",
+			body: map[string]interface{}{"region": fixtureStandbyRegion}},
+		{name: "fence-invalid", method: http.MethodPost, path: "/api/replication/fence",
+			body: map[string]interface{}{}, headers: replicaAuth},
+	})
+}
+
+// stubRegions replicates to one peer, which pulls with the fixture
+// replication token
+type stubRegions struct{}
+
+func (stubRegions) Authorize(token string) bool {
+	return token == fixtureReplicaToken
+}
+
+func (stubRegions) Changes(ctx context.Context, after int64, limit int) (*types.CatalogChangeBatch, error) {
+	return &types.CatalogChangeBatch{
+		Region: fixturePrimaryRegion,
+		Changes: []types.CatalogChange{{
+			Version:        fixtureLaterTime,
+			Payload:        json.RawMessage(`{"name":"github","protocol":"http"}`),
+			EntityType:     "mcp_server",
+			EntityID:       fixtureServerID,
+			OrganizationID: fixtureOrgID,
+			Operation:      "upsert",
+			ID:             after + 1,
+		}},
+		NextAfter: after + 1,
+		HasMore:   limit == 1,
+	}, nil
+}
+
+func (stubRegions) Sync(ctx context.Context) (int, error) {
+	return 1, nil
+}
+
+func (stubRegions) Status(ctx context.Context) (*types.RegionStatus, error) {
+	return &types.RegionStatus{
+		Region: fixturePrimaryRegion,
+		Peers: []types.RegionPeerStatus{{
+			LastPulledAt:  &fixtureLaterTime,
+			LastAppliedAt: &fixtureTime,
+			Region:        fixtureStandbyRegion,
+			URL:           "https://us-east-1.gateway.example.com",
+			LastChangeID:  42,
+			AppliedCount:  40,
+		}},
+	}, nil
+}
+
+func (stubRegions) DNSGuidance(ctx context.Context, orgID string) (*types.RegionDNSGuidance, error) {
+	return &types.RegionDNSGuidance{
+		GlobalHost: "gateway.example.com",
+		Records: []types.RegionDNSRecord{{
+			Name:        "gateway.example.com",
+			Type:        "CNAME",
+			Value:       "eu-west-1.gateway.example.com",
+			Routing:     "latency",
+			Region:      fixturePrimaryRegion,
+			HealthCheck: "https://eu-west-1.gateway.example.com/readyz",
+		}},
+		Endpoints: []types.EndpointDNSRoute{{
+			RegionalURLs: map[string]string{fixturePrimaryRegion: "https://eu-west-1.gateway.example.com/api/public/endpoints/support-tools/mcp"},
+			Name:         "support-tools",
+			URL:          "https://gateway.example.com/api/public/endpoints/support-tools/mcp",
+		}},
+		Notes: []string{"Point gateway.example.com at every region with latency routing"},
+	}, nil
+}
+
+func TestRegionContracts(t *testing.T) {
+	h := handlers.NewRegionHandler(stubRegions{})
+	r := newContractRouter()
+	r.GET("/api/replication/changes", h.ListChanges)
+	admin := r.Group("/api/admin")
+	admin.GET("/regions", h.GetStatus)
+	admin.POST("/regions/sync", h.Sync)
+	admin.GET("/regions/dns", h.GetDNSGuidance)
+
+	r.run(t, "regions", []contractCase{
+		{name: "changes", method: http.MethodGet, path: "/api/replication/changes", query: "after=41&limit=1", headers: replicaAuth},
+		{name: "changes-unauthorized", method: http.MethodGet, path: "/api/replication/changes"},
+		{name: "changes-invalid", method: http.MethodGet, path: "/api/replication/changes", query: "after=latest", headers: replicaAuth},
+		{name: "status", method: http.MethodGet, path: "/api/admin/regions"},
+		{name: "sync", method: http.MethodPost, path: "/api/admin/regions/sync"},
+		{name: "dns", method: http.MethodGet, path: "/api/admin/regions/dns"},
+	})
+}
+
+func fixtureGatewayNode() *types.GatewayNode {
+	return &types.GatewayNode{
+		StartedAt:       fixtureTime,
+		LastHeartbeatAt: fixtureLaterTime,
+		Sessions:        map[string]int{"sse": 2, "websocket": 1},
+		ID:              fixtureNodeID,
+		Hostname:        "gateway-7f9c",
+		Address:         "10.0.3.17:8080",
+		Version:         "1.4.0",
+		Region:          fixturePrimaryRegion,
+		Status:          types.NodeStatusActive,
+		HealthStatus:    "healthy",
+		HealthScore:     1,
+		UptimeSeconds:   634500,
+		SessionCount:    3,
+		Self:            true,
+	}
+}
+
+// stubNodes is a fleet of the fixture node
+type stubNodes struct{}
+
+func (stubNodes) List(ctx context.Context) (*types.NodeFleet, error) {
+	return &types.NodeFleet{Nodes: []*types.GatewayNode{fixtureGatewayNode()}, Active: 1, Sessions: 3}, nil
+}
+
+func (stubNodes) SetDraining(ctx context.Context, id string, draining bool, requestedBy string) (*types.GatewayNode, error) {
+	if id != fixtureNodeID {
+		return nil, notFound("Node")
+	}
+	node := fixtureGatewayNode()
+	if draining {
+		node.Status = types.NodeStatusDraining
+		node.Draining = true
+		node.DrainRequestedAt = &fixtureLaterTime
+		node.DrainRequestedBy = requestedBy
+	}
+	return node, nil
+}
+
+func TestNodeContracts(t *testing.T) {
+	h := handlers.NewNodeHandler(stubNodes{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/nodes", h.ListNodes)
+	admin.POST("/nodes/:id/drain", h.DrainNode)
+	admin.DELETE("/nodes/:id/drain", h.UndrainNode)
+
+	r.run(t, "nodes", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/nodes"},
+		{name: "drain", method: http.MethodPost, path: "/api/admin/nodes/" + fixtureNodeID + "/drain"},
+		{name: "drain-not-found", method: http.MethodPost, path: "/api/admin/nodes/gw-unknown/drain"},
+		{name: "undrain", method: http.MethodDelete, path: "/api/admin/nodes/" + fixtureNodeID + "/drain"},
+	})
+}
+
+// stubReadOnly is a gateway taking writes until an admin switches it
+type stubReadOnly struct{}
+
+func (stubReadOnly) Status() types.ReadOnlyStatus {
+	return types.ReadOnlyStatus{Source: types.ReadOnlySourceConfig}
+}
+
+func (stubReadOnly) Set(enabled bool, reason, changedBy string) (types.ReadOnlyStatus, error) {
+	return types.ReadOnlyStatus{
+		ChangedAt: &fixtureLaterTime,
+		Reason:    reason,
+		ChangedBy: changedBy,
+		Source:    types.ReadOnlySourceAPI,
+		Enabled:   enabled,
+	}, nil
+}
+
+func fixtureFeatureFlagState() *types.FeatureFlagState {
+	return &types.FeatureFlagState{
+		UpdatedAt:   &fixtureTime,
+		Key:         fixtureFeatureFlag,
+		Description: "Rank search results by embedding similarity",
+		Source:      types.FeatureFlagSourceOrganization,
+		UpdatedBy:   fixtureUserID,
+		Enabled:     true,
+	}
+}
+
+// stubFeatureFlags knows the fixture flag only
+type stubFeatureFlags struct{}
+
+func (stubFeatureFlags) ListFlags(ctx context.Context, orgID string) []*types.FeatureFlagState {
+	return []*types.FeatureFlagState{fixtureFeatureFlagState()}
+}
+
+func (stubFeatureFlags) GetFlag(ctx context.Context, orgID, key string) (*types.FeatureFlagState, error) {
+	if key != fixtureFeatureFlag {
+		return nil, notFound("Feature flag")
+	}
+	return fixtureFeatureFlagState(), nil
+}
+
+func (stubFeatureFlags) SetFlag(ctx context.Context, key, orgID string, enabled bool, updatedBy string) error {
+	if key != fixtureFeatureFlag {
+		return types.NewValidationError("unknown feature flag: " + key)
+	}
+	return nil
+}
+
+func (stubFeatureFlags) ClearFlag(ctx context.Context, key, orgID string) error {
+	if key != fixtureFeatureFlag {
+		return types.NewValidationError("unknown feature flag: " + key)
+	}
+	return nil
+}
+
+func TestGatewaySettingsContracts(t *testing.T) {
+	readOnly := handlers.NewReadOnlyHandler(stubReadOnly{}, discardAudit{})
+	flags := handlers.NewFeatureFlagHandler(stubFeatureFlags{}, stubReadOnly{}, discardAudit{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/read-only", readOnly.GetStatus)
+	admin.PUT("/read-only", readOnly.UpdateStatus)
+	admin.GET("/config", flags.GetConfig)
+	admin.GET("/feature-flags", flags.ListFlags)
+	admin.PUT("/feature-flags/:key", flags.SetFlag)
+	admin.DELETE("/feature-flags/:key", flags.ClearFlag)
+
+	flag := "/api/admin/feature-flags/" + fixtureFeatureFlag
+	r.run(t, "gateway-settings", []contractCase{
+		{name: "read-only", method: http.MethodGet, path: "/api/admin/read-only"},
+		{name: "read-only-enable", method: http.MethodPut, path: "/api/admin/read-only",
+			body: map[string]interface{}{"enabled": true, "reason": "Database migration"}},
+		{name: "read-only-invalid", method: http.MethodPut, path: "/api/admin/read-only", body: map[string]interface{}{"reason": "?"}},
+		{name: "config", method: http.MethodGet, path: "/api/admin/config"},
+		{name: "feature-flags", method: http.MethodGet, path: "/api/admin/feature-flags"},
+		{name: "feature-flag-set", method: http.MethodPut, path: flag, body: map[string]interface{}{"enabled": true}},
+		{name: "feature-flag-set-invalid", method: http.MethodPut, path: flag, body: map[string]interface{}{}},
+		{name: "feature-flag-set-unknown", method: http.MethodPut, path: "/api/admin/feature-flags/warp_drive",
+			body: map[string]interface{}{"enabled": true, "global": true}},
+		{name: "feature-flag-clear", method: http.MethodDelete, path: flag, query: "global=true"},
+		{name: "feature-flag-clear-unknown", method: http.MethodDelete, path: "/api/admin/feature-flags/warp_drive"},
+	})
+}
+
+// stubListCache is a Redis listing cache
+type stubListCache struct{}
+
+func (stubListCache) Stats() cache.Stats {
+	return cache.Stats{Backend: "redis", TTLSeconds: 30, Hits: 1200, Misses: 80, Invalidations: 12}
+}
+
+func (stubListCache) Flush(ctx context.Context, orgID, kind string) (int, error) {
+	if kind == "" {
+		return len(cache.Kinds), nil
+	}
+	return 1, nil
+}
+
+func TestCacheContracts(t *testing.T) {
+	r := newContractRouter()
+	enabled := handlers.NewCacheHandler(stubListCache{})
+	r.GET("/api/admin/cache", enabled.GetStats)
+	r.DELETE("/api/admin/cache", enabled.Flush)
+
+	r.run(t, "cache", []contractCase{
+		{name: "stats", method: http.MethodGet, path: "/api/admin/cache"},
+		{name: "flush", method: http.MethodDelete, path: "/api/admin/cache"},
+		{name: "flush-kind", method: http.MethodDelete, path: "/api/admin/cache", query: "kind=tools"},
+		{name: "flush-invalid", method: http.MethodDelete, path: "/api/admin/cache", query: "kind=servers"},
+	})
+}
+
+// stubLicense is an enterprise license with seats to spare
+type stubLicense struct{}
+
+func (stubLicense) Status(ctx context.Context) *types.LicenseStatus {
+	return &types.LicenseStatus{
+		CheckedAt: fixtureLaterTime,
+		License: &types.License{
+			IssuedAt:  fixtureTime,
+			ExpiresAt: time.Date(2027, time.March, 2, 0, 0, 0, 0, time.UTC),
+			ID:        "lic_fixture",
+			Licensee:  "Acme Corp",
+			Issuer:    "Omnimesh Labs",
+			Features:  []string{types.LicenseEntitlementAll},
+			Seats:     50,
+		},
+		Status:        types.LicenseStatusValid,
+		Entitlements:  []string{types.LicenseEntitlementAll},
+		SeatsUsed:     12,
+		SeatsLimit:    50,
+		DaysRemaining: 358,
+	}
+}
+
+// stubOffline is an air-gapped gateway mirroring the package registry
+type stubOffline struct{}
+
+func (stubOffline) Status() *types.OfflineStatus {
+	return &types.OfflineStatus{
+		AllowedHosts: []string{"registry.internal.example.com"},
+		Features: []types.ConnectivityFeatureStatus{{
+			ConnectivityFeature: types.ConnectivityFeature{Key: "mcp_registry", Description: "MCP package registry", Mirrorable: true},
+			Mirror:              "https://registry.internal.example.com",
+			Available:           true,
+		}},
+		Offline: true,
+	}
+}
+
+// stubTelemetry has telemetry turned off, with the report it would send
+type stubTelemetry struct{}
+
+func (stubTelemetry) Status(ctx context.Context) (*types.TelemetryStatus, error) {
+	return &types.TelemetryStatus{
+		Report: &types.TelemetryReport{
+			GeneratedAt:   fixtureLaterTime,
+			Version:       "1.4.0",
+			GoVersion:     "go1.24.0",
+			Platform:      "linux/amd64",
+			SessionWindow: "24h",
+			TelemetryUsage: types.TelemetryUsage{
+				ServersByProtocol:  map[string]int{"http": 2, "stdio": 1},
+				SessionsByProtocol: map[string]int{"sse": 4},
+				UserRange:          "11-50",
+				Servers:            3,
+				Namespaces:         1,
+				Endpoints:          1,
+			},
+		},
+		Reason:   "Disabled in configuration",
+		Interval: "24h0m0s",
+	}, nil
+}
+
+// stubPriorityStats reports one busy priority class
+type stubPriorityStats struct{}
+
+func (stubPriorityStats) Stats() []types.PriorityClassStats {
+	return []types.PriorityClassStats{
+		{Class: "interactive", Admitted: 920, Rejected: 3, AvgWaitMS: 1.5, InFlight: 4},
+		{Class: "batch", Admitted: 140, TimedOut: 2, AvgWaitMS: 48, Queued: 6},
+	}
+}
+
+// stubLoadShedding has shed a few requests
+type stubLoadShedding struct{}
+
+func (stubLoadShedding) Stats() *types.LoadSheddingStats {
+	return &types.LoadSheddingStats{Limit: 64, MinLimit: 8, MaxLimit: 256, InFlight: 12,
+		BaselineLatencyMS: 35, LatencyMS: 52.5, Admitted: 10450, Shed: 17}
+}
+
+// stubPosture grades the fixture organization
+type stubPosture struct{}
+
+func (stubPosture) GenerateReport(ctx context.Context, orgID string, maxKeyAge time.Duration) (*types.SecurityPostureReport, error) {
+	keyAge := types.SecurityCheck{
+		ID: "api_key_age", Title: "API keys are rotated", Category: "credentials",
+		Severity: types.SecuritySeverityMedium, Status: types.SecurityCheckPass,
+		Details: "No API key is older than 90 days", Weight: 10,
+	}
+	if maxKeyAge > 0 && maxKeyAge < 7*24*time.Hour {
+		keyAge.Status = types.SecurityCheckFail
+		keyAge.Details = "1 API key is older than the limit"
+		keyAge.Findings = []string{fixtureAPIKeyID}
+		keyAge.Remediation = "Rotate the API keys listed"
+	}
+	report := &types.SecurityPostureReport{
+		GeneratedAt:    fixtureLaterTime,
+		OrganizationID: orgID,
+		Grade:          "A",
+		Checks: []types.SecurityCheck{keyAge, {
+			ID: "sso", Title: "Single sign-on is configured", Category: "authentication",
+			Severity: types.SecuritySeverityHigh, Status: types.SecurityCheckPass, Details: "1 active SSO provider", Weight: 20,
+		}},
+		Score:  100,
+		Passed: 2,
+	}
+	if keyAge.Status == types.SecurityCheckFail {
+		report.Grade, report.Score, report.Passed, report.Failed = "B", 67, 1, 1
+	}
+	return report, nil
+}
+
+func TestGatewayStatusContracts(t *testing.T) {
+	scheduler := handlers.NewSchedulerHandler(stubPriorityStats{}, stubPriorityStats{})
+	scheduler.SetLoadShedding(stubLoadShedding{})
+	posture := handlers.NewSecurityPostureHandler(stubPosture{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/license", handlers.NewLicenseHandler(stubLicense{}).GetStatus)
+	admin.GET("/offline", handlers.NewOfflineHandler(stubOffline{}).GetStatus)
+	admin.GET("/telemetry", handlers.NewTelemetryHandler(stubTelemetry{}).GetStatus)
+	admin.GET("/scheduler", scheduler.GetStats)
+	admin.GET("/security/posture", posture.GetPostureReport)
+
+	r.run(t, "gateway-status", []contractCase{
+		{name: "license", method: http.MethodGet, path: "/api/admin/license"},
+		{name: "offline", method: http.MethodGet, path: "/api/admin/offline"},
+		{name: "telemetry", method: http.MethodGet, path: "/api/admin/telemetry"},
+		{name: "scheduler", method: http.MethodGet, path: "/api/admin/scheduler"},
+		{name: "security-posture", method: http.MethodGet, path: "/api/admin/security/posture"},
+		{name: "security-posture-max-key-age", method: http.MethodGet, path: "/api/admin/security/posture", query: "max_key_age_days=1"},
+		{name: "security-posture-invalid", method: http.MethodGet, path: "/api/admin/security/posture", query: "max_key_age_days=0"},
+	})
+}
+
+// TestChangelogContracts asks for the releases after the newest one, so
+// the golden file does not change with every release note
+func TestChangelogContracts(t *testing.T) {
+	releases, err := buildinfo.Changelog()
+	require.NoError(t, err)
+	require.NotEmpty(t, releases)
+
+	h := handlers.NewVersionHandler(stubFeatureFlags{})
+	r := newContractRouter()
+	r.GET("/api/changelog", h.GetChangelog)
+
+	r.run(t, "changelog", []contractCase{
+		{name: "since-latest", method: http.MethodGet, path: "/api/changelog", query: "since=" + url.QueryEscape(releases[0].Version)},
+	})
+}
+
+func fixtureBranding() *types.OrganizationBranding {
+	return &types.OrganizationBranding{
+		UpdatedAt:      &fixtureLaterTime,
+		OrganizationID: fixtureOrgID,
+		ProductName:    "Acme MCP",
+		LogoURL:        "https://cdn.example.com/acme.svg",
+		PrimaryColor:   "#1a73e8",
+		AccentColor:    "#fbbc04",
+		ContactName:    "Platform team",
+		ContactEmail:   "platform@example.com",
+		UpdatedBy:      fixtureUserID,
+	}
+}
+
+// stubBranding serves the fixture branding
+type stubBranding struct{}
+
+func (stubBranding) GetBranding(ctx context.Context, orgID string) (*types.OrganizationBranding, error) {
+	return fixtureBranding(), nil
+}
+
+func (stubBranding) SetBranding(ctx context.Context, orgID, updatedBy string, req *types.SetOrganizationBrandingRequest) (*types.OrganizationBranding, error) {
+	branding := fixtureBranding()
+	branding.ProductName = req.ProductName
+	branding.PrimaryColor = req.PrimaryColor
+	return branding, nil
+}
+
+func (stubBranding) ResetBranding(ctx context.Context, orgID string) error {
+	return nil
+}
+
+func TestBrandingContracts(t *testing.T) {
+	h := handlers.NewBrandingHandler(stubBranding{})
+	r := newContractRouter()
+	r.GET("/api/admin/branding", h.GetBranding)
+	r.PUT("/api/admin/branding", h.SetBranding)
+	r.DELETE("/api/admin/branding", h.ResetBranding)
+
+	r.run(t, "branding", []contractCase{
+		{name: "get", method: http.MethodGet, path: "/api/admin/branding"},
+		{name: "set", method: http.MethodPut, path: "/api/admin/branding",
+			body: map[string]interface{}{"product_name": "Acme Tools", "primary_color": "#0b8043"}},
+		{name: "set-invalid", method: http.MethodPut, path: "/api/admin/branding",
+			body: map[string]interface{}{"logo_url": "http://cdn.example.com/acme.svg", "primary_color": "blue"}},
+		{name: "reset", method: http.MethodDelete, path: "/api/admin/branding"},
+	})
+}
+
+func fixtureBudgetStatus() *types.UsageBudgetStatus {
+	return &types.UsageBudgetStatus{
+		UsageBudget: types.UsageBudget{
+			CreatedAt:          fixtureTime,
+			UpdatedAt:          fixtureLaterTime,
+			OrganizationID:     fixtureOrgID,
+			Dimension:          types.MeterExecutions,
+			CriticalNamespaces: []string{fixtureNamespaceID},
+			MonthlyLimit:       100000,
+			HardStop:           true,
+		},
+		ResetsAt:    time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+		Period:      "2026-03",
+		AlertsSent:  []int{50},
+		Used:        61250,
+		PercentUsed: 61.25,
+	}
+}
+
+// stubBudgets holds a budget on executions
+type stubBudgets struct{}
+
+func (stubBudgets) List(ctx context.Context, orgID string) (*types.UsageBudgetListResponse, error) {
+	return &types.UsageBudgetListResponse{
+		Usage: &types.MeteredUsage{
+			Usage:  map[string]int64{types.MeterExecutions: 61250, types.MeterTokens: 8400000, types.MeterEgressBytes: 52428800},
+			Period: "2026-03",
+		},
+		Budgets: []*types.UsageBudgetStatus{fixtureBudgetStatus()},
+	}, nil
+}
+
+func (stubBudgets) SetBudget(ctx context.Context, orgID, dimension string, req *types.SetUsageBudgetRequest) (*types.UsageBudgetStatus, error) {
+	if !slices.Contains(types.MeterDimensions, dimension) {
+		return nil, types.NewValidationError("dimension must be executions, tokens or egress_bytes")
+	}
+	status := fixtureBudgetStatus()
+	status.Dimension = dimension
+	status.MonthlyLimit = req.MonthlyLimit
+	status.HardStop = req.HardStop
+	status.CriticalNamespaces = req.CriticalNamespaces
+	return status, nil
+}
+
+func (stubBudgets) DeleteBudget(ctx context.Context, orgID, dimension string) error {
+	if dimension != types.MeterExecutions {
+		return notFound("Budget")
+	}
+	return nil
+}
+
+func TestUsageBudgetContracts(t *testing.T) {
+	h := handlers.NewUsageBudgetHandler(stubBudgets{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/budgets", h.ListBudgets)
+	admin.PUT("/budgets/:dimension", h.SetBudget)
+	admin.DELETE("/budgets/:dimension", h.DeleteBudget)
+
+	r.run(t, "budgets", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/budgets"},
+		{name: "set", method: http.MethodPut, path: "/api/admin/budgets/executions",
+			body: map[string]interface{}{"monthly_limit": 100000, "hard_stop": true, "critical_namespaces": []string{fixtureNamespaceID}}},
+		{name: "set-invalid", method: http.MethodPut, path: "/api/admin/budgets/executions", body: map[string]interface{}{"monthly_limit": 0}},
+		{name: "set-unknown-dimension", method: http.MethodPut, path: "/api/admin/budgets/requests",
+			body: map[string]interface{}{"monthly_limit": 10}},
+		{name: "delete", method: http.MethodDelete, path: "/api/admin/budgets/executions"},
+		{name: "delete-not-found", method: http.MethodDelete, path: "/api/admin/budgets/tokens"},
+	})
+}
+
+func fixturePlaygroundExecution() *types.PlaygroundExecution {
+	return &types.PlaygroundExecution{
+		CreatedAt:      fixtureTime,
+		Arguments:      map[string]interface{}{"owner": "acme", "repo": "gateway", "token": "[REDACTED]"},
+		Result:         map[string]interface{}{"content": []map[string]interface{}{{"type": "text", "text": "3 open issues"}}},
+		ID:             fixtureExecutionID,
+		OrganizationID: fixtureOrgID,
+		UserID:         fixtureUserID,
+		Source:         types.PlaygroundSourceSandbox,
+		EndpointID:     fixtureEndpointID,
+		ToolName:       "github__list_issues",
+		RedactedFields: []string{"token"},
+		DurationMS:     182.4,
+	}
+}
+
+// stubPlaygroundHistory serves the fixture execution
+type stubPlaygroundHistory struct{}
+
+func (stubPlaygroundHistory) ListHistory(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, query *types.PlaygroundHistoryQuery) ([]*types.PlaygroundExecution, error) {
+	return []*types.PlaygroundExecution{fixturePlaygroundExecution()}, nil
+}
+
+func (stubPlaygroundHistory) GetExecution(ctx context.Context, orgID, viewerID string, viewerIsAdmin bool, id string) (*types.PlaygroundExecution, error) {
+	if id == missingID {
+		return nil, notFound("Execution")
+	}
+	return fixturePlaygroundExecution(), nil
+}
+
+func (stubPlaygroundHistory) DeleteExecution(ctx context.Context, orgID, userID, id string) error {
+	if id == missingID {
+		return notFound("Execution")
+	}
+	return nil
+}
+
+func (stubPlaygroundHistory) ClearHistory(ctx context.Context, orgID, userID string) (int64, error) {
+	return 14, nil
+}
+
+func (stubPlaygroundHistory) GetSettings(ctx context.Context, orgID string) (*types.PlaygroundHistorySettings, error) {
+	return &types.PlaygroundHistorySettings{OrganizationID: orgID, Enabled: true}, nil
+}
+
+func (stubPlaygroundHistory) UpdateSettings(ctx context.Context, orgID, updatedBy string, req *types.UpdatePlaygroundHistorySettingsRequest) (*types.PlaygroundHistorySettings, error) {
+	settings := &types.PlaygroundHistorySettings{UpdatedAt: &fixtureLaterTime, OrganizationID: orgID, UpdatedBy: updatedBy, Enabled: true}
+	if req.Enabled != nil {
+		settings.Enabled = *req.Enabled
+	}
+	if req.AdminsCanView != nil {
+		settings.AdminsCanView = *req.AdminsCanView
+	}
+	return settings, nil
+}
+
+func TestPlaygroundHistoryContracts(t *testing.T) {
+	h := handlers.NewPlaygroundHistoryHandler(stubPlaygroundHistory{})
+	r := newContractRouter()
+	r.GET("/api/playground/history", h.ListHistory)
+	r.GET("/api/playground/history/:id", h.GetExecution)
+	r.DELETE("/api/playground/history/:id", h.DeleteExecution)
+	r.DELETE("/api/playground/history", h.ClearHistory)
+	r.GET("/api/admin/playground-history/settings", h.GetSettings)
+	r.PUT("/api/admin/playground-history/settings", h.UpdateSettings)
+
+	r.run(t, "playground-history", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/playground/history", query: "source=sandbox&limit=20"},
+		{name: "list-invalid", method: http.MethodGet, path: "/api/playground/history", query: "source=cli"},
+		{name: "get", method: http.MethodGet, path: "/api/playground/history/" + fixtureExecutionID},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/playground/history/" + missingID},
+		{name: "delete", method: http.MethodDelete, path: "/api/playground/history/" + fixtureExecutionID},
+		{name: "delete-not-found", method: http.MethodDelete, path: "/api/playground/history/" + missingID},
+		{name: "clear", method: http.MethodDelete, path: "/api/playground/history"},
+		{name: "settings", method: http.MethodGet, path: "/api/admin/playground-history/settings"},
+		{name: "settings-update", method: http.MethodPut, path: "/api/admin/playground-history/settings",
+			body: map[string]interface{}{"admins_can_view": true}},
+		{name: "settings-update-invalid", method: http.MethodPut, path: "/api/admin/playground-history/settings", body: "{"},
+	})
+}
+
+// stubSearch finds the fixture GitHub tool
+type stubSearch struct{}
+
+func (stubSearch) Search(ctx context.Context, orgID string, query *types.SearchQuery) (*types.SearchResponse, error) {
+	mode := query.Mode
+	if mode == "" {
+		mode = "keyword"
+	}
+	return &types.SearchResponse{
+		Results: []*types.SearchResult{{
+			Type:        types.SearchTypeTool,
+			ID:          fixtureServerID + ":list_issues",
+			Name:        "github__list_issues",
+			Description: "List the issues of a repository",
+			Category:    "development",
+			ServerID:    fixtureServerID,
+			Tags:        []string{"github"},
+			Score:       0.92,
+			UsageCount:  318,
+		}},
+		Mode:  mode,
+		Total: 1,
+	}, nil
+}
+
+func TestSearchContracts(t *testing.T) {
+	h := handlers.NewSearchHandler(stubSearch{})
+	r := newContractRouter()
+	r.GET("/api/search", h.Search)
+
+	r.run(t, "search", []contractCase{
+		{name: "search", method: http.MethodGet, path: "/api/search", query: "q=issues&types=tool&limit=10"},
+		{name: "search-invalid", method: http.MethodGet, path: "/api/search", query: "limit=10"},
+	})
+}
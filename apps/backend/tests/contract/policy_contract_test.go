@@ -0,0 +1,141 @@
+package contract
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fixturePolicyID     = "8d7c6b5a-4f3e-4d2c-9b1a-0f9e8d7c6bca"
+	fixtureToolPolicyID = "e1d0c9b8-a7f6-4e5d-8c4b-3a2f1e0d9cdb"
+)
+
+var policyColumns = []string{"id", "organization_id", "name", "description", "type", "priority",
+	"conditions", "actions", "is_active", "created_at", "updated_at"}
+
+// policyRows returns the fixture policies as the handler selects them
+func policyRows(includeAccess bool) *sqlmock.Rows {
+	rows := sqlmock.NewRows(policyColumns).
+		AddRow(fixtureToolPolicyID, fixtureOrgID, "no-deletes", "Block destructive GitHub tools", types.PolicyTypeTool, 100,
+			[]byte(`{"tools":["github__delete_*"]}`), []byte(`{"effect":"deny","message":"Deleting is disabled"}`),
+			true, fixtureTime, fixtureLaterTime)
+	if includeAccess {
+		rows.AddRow(fixturePolicyID, fixtureOrgID, "office-only", "Office network only", types.PolicyTypeAccess, 10,
+			[]byte(`{"ip_ranges":["10.0.0.0/8"]}`), []byte(`{"allow":true}`), true, fixtureTime, fixtureLaterTime)
+	}
+	return rows
+}
+
+// stubToolPolicies denies the fixture tool policy's tools
+type stubToolPolicies struct{}
+
+func (stubToolPolicies) Test(ctx context.Context, orgID string, req *types.TestToolPolicyRequest) (*types.ToolPolicyDecision, error) {
+	if req.NamespaceID == missingID {
+		return nil, notFound("Namespace")
+	}
+	return &types.ToolPolicyDecision{
+		Effect:     types.ToolPolicyEffectDeny,
+		PolicyID:   fixtureToolPolicyID,
+		PolicyName: "no-deletes",
+		Message:    "Deleting is disabled",
+		Trace: []types.ToolPolicyTraceEntry{
+			{PolicyID: fixtureToolPolicyID, PolicyName: "no-deletes", Effect: types.ToolPolicyEffectDeny, Priority: 100, Matched: true},
+		},
+	}, nil
+}
+
+func (stubToolPolicies) Invalidate(orgID string) {}
+
+func TestPolicyContracts(t *testing.T) {
+	// Policies get their IDs from uuid.New
+	uuid.SetRand(rand.New(rand.NewSource(1)))
+	defer uuid.SetRand(nil)
+
+	// The handler queries the database itself, so its queries are answered
+	// in the order of the cases below
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT .+ FROM policies\s+WHERE organization_id = \$1\s+ORDER BY`).
+		WithArgs(fixtureOrgID, 50, 0).WillReturnRows(policyRows(true))
+	mock.ExpectQuery(`SELECT .+ FROM policies\s+WHERE organization_id = \$1 AND type = \$2 AND is_active = \$3 ORDER BY`).
+		WithArgs(fixtureOrgID, types.PolicyTypeTool, true, 10, 20).WillReturnRows(policyRows(false))
+	mock.ExpectExec(`INSERT INTO policies`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO policies`).
+		WillReturnError(errors.New(`pq: duplicate key value violates unique constraint "policies_organization_id_name_key"`))
+	mock.ExpectQuery(`SELECT .+ FROM policies\s+WHERE id = \$1 AND organization_id = \$2`).
+		WithArgs(fixtureToolPolicyID, fixtureOrgID).WillReturnRows(policyRows(false))
+	mock.ExpectQuery(`SELECT .+ FROM policies\s+WHERE id = \$1 AND organization_id = \$2`).
+		WithArgs(missingID, fixtureOrgID).WillReturnError(sql.ErrNoRows)
+	existingToolPolicy := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "type", "conditions", "actions"}).
+			AddRow(fixtureToolPolicyID, types.PolicyTypeTool, []byte(`{"tools":["github__delete_*"]}`), []byte(`{"effect":"deny"}`))
+	}
+	mock.ExpectQuery(`SELECT id, type, conditions, actions FROM policies`).WillReturnRows(existingToolPolicy())
+	mock.ExpectExec(`UPDATE policies SET priority = \$1, is_active = \$2, updated_at = NOW\(\) WHERE id = \$3 AND organization_id = \$4`).
+		WithArgs(200, false, fixtureToolPolicyID, fixtureOrgID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id, type, conditions, actions FROM policies`).WillReturnRows(existingToolPolicy())
+	mock.ExpectQuery(`SELECT id, type, conditions, actions FROM policies`).WillReturnRows(existingToolPolicy())
+	mock.ExpectQuery(`SELECT id, type, conditions, actions FROM policies`).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`SELECT id, type FROM policies`).WithArgs(fixtureToolPolicyID, fixtureOrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).AddRow(fixtureToolPolicyID, types.PolicyTypeTool))
+	mock.ExpectExec(`DELETE FROM policies`).WithArgs(fixtureToolPolicyID, fixtureOrgID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id, type FROM policies`).WithArgs(missingID, fixtureOrgID).WillReturnError(sql.ErrNoRows)
+
+	h := handlers.NewPolicyHandler(db)
+	h.SetToolPolicies(stubToolPolicies{})
+	r := newContractRouter()
+	admin := r.Group("/api/admin")
+	admin.GET("/policies", h.ListPolicies)
+	admin.POST("/policies", h.CreatePolicy)
+	admin.POST("/policies/test", h.TestPolicy)
+	admin.GET("/policies/:id", h.GetPolicy)
+	admin.PUT("/policies/:id", h.UpdatePolicy)
+	admin.DELETE("/policies/:id", h.DeletePolicy)
+
+	base := "/api/admin/policies/" + fixtureToolPolicyID
+	toolPolicy := map[string]interface{}{
+		"name": "no-deletes", "type": "tool", "priority": 100,
+		"conditions": map[string]interface{}{"tools": []string{"github__delete_*"}},
+		"actions":    map[string]interface{}{"effect": "deny", "message": "Deleting is disabled"},
+	}
+	r.run(t, "policies", []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/admin/policies"},
+		{name: "list-filtered", method: http.MethodGet, path: "/api/admin/policies", query: "type=tool&is_active=true&limit=10&offset=20"},
+		{name: "create", method: http.MethodPost, path: "/api/admin/policies", body: toolPolicy},
+		{name: "create-conflict", method: http.MethodPost, path: "/api/admin/policies", body: toolPolicy},
+		{name: "create-invalid", method: http.MethodPost, path: "/api/admin/policies",
+			body: map[string]interface{}{"name": "x", "type": "tool"}},
+		{name: "create-invalid-tool-policy", method: http.MethodPost, path: "/api/admin/policies",
+			body: map[string]interface{}{"name": "no-deletes", "type": "tool",
+				"conditions": map[string]interface{}{"tools": []string{"github__["}}, "actions": map[string]interface{}{"effect": "deny"}}},
+		{name: "test", method: http.MethodPost, path: "/api/admin/policies/test",
+			body: map[string]interface{}{"namespace_id": fixtureNamespaceID, "tool": "github__delete_repo"}},
+		{name: "test-invalid", method: http.MethodPost, path: "/api/admin/policies/test", body: map[string]interface{}{"tool": "github__delete_repo"}},
+		{name: "test-not-found", method: http.MethodPost, path: "/api/admin/policies/test",
+			body: map[string]interface{}{"namespace_id": missingID, "tool": "github__delete_repo"}},
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/admin/policies/" + missingID},
+		{name: "update", method: http.MethodPut, path: base, body: map[string]interface{}{"priority": 200, "is_active": false}},
+		{name: "update-invalid-tool-policy", method: http.MethodPut, path: base,
+			body: map[string]interface{}{"actions": map[string]interface{}{"effect": "warn"}}},
+		{name: "update-nothing", method: http.MethodPut, path: base, body: map[string]interface{}{}},
+		{name: "update-not-found", method: http.MethodPut, path: "/api/admin/policies/" + missingID, body: map[string]interface{}{"priority": 1}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "delete-not-found", method: http.MethodDelete, path: "/api/admin/policies/" + missingID},
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,124 @@
+package contract
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const fixtureBaseURL = "https://gateway.example.com"
+
+// stubPublicEndpoints resolves the fixture endpoint by its name
+type stubPublicEndpoints struct {
+	stubEndpoints
+}
+
+func (stubPublicEndpoints) ResolveEndpoint(ctx context.Context, name string) (*types.EndpointConfig, error) {
+	if name != fixtureEndpoint().Name {
+		return nil, notFound("Endpoint")
+	}
+	namespace := fixtureNamespace()
+	namespace.Tools = fixtureNamespaceTools()
+	return &types.EndpointConfig{Endpoint: fixtureEndpoint(), Namespace: namespace}, nil
+}
+
+// stubStatuses serves the status page of the fixture endpoint
+type stubStatuses struct{}
+
+func (stubStatuses) GetStatus(ctx context.Context, endpointName string) (*types.PublicStatus, error) {
+	if endpointName != fixtureEndpoint().Name {
+		return nil, notFound("Endpoint")
+	}
+	dayUptime, weekUptime := 100.0, 99.7
+	return &types.PublicStatus{
+		GeneratedAt: fixtureLaterTime,
+		Endpoint:    endpointName,
+		Status:      "operational",
+		Uptime: []types.UptimeWindow{
+			{Percent: &dayUptime, Window: "24h"},
+			{Percent: &weekUptime, Window: "7d"},
+		},
+		Incidents: []types.StatusIncident{{
+			StartedAt: fixtureTime,
+			EndedAt:   &fixtureLaterTime,
+			Title:     "Elevated latency on tickets",
+			Impact:    "degraded",
+		}},
+	}, nil
+}
+
+func TestPublicEndpointContracts(t *testing.T) {
+	endpoints := stubPublicEndpoints{}
+	r := newContractRouter()
+	r.GET("/api/public/endpoints", handlers.NewEndpointHandler(endpoints).ListEndpoints)
+	r.GET("/api/public/status/:endpoint_name", handlers.NewStatusPageHandler(stubStatuses{}, types.StatusPageLimits{CacheTTLSeconds: 30}).GetStatus)
+	discovery := handlers.NewGatewayDiscoveryHandler(endpoints, fixtureBaseURL)
+	r.GET("/.well-known/mcp-gateway", discovery.Discover)
+	r.GET("/.well-known/mcp-gateway/:endpoint_name", discovery.DiscoverEndpoint)
+
+	endpoint := r.Group("/api/public/endpoints/:endpoint_name")
+	endpoint.Use(middleware.EndpointLookupMiddleware(endpoints))
+	endpoint.GET("/sse", handlers.HandleEndpointSSE(stubNamespaces{}))
+	endpoint.POST("/message", handlers.HandleEndpointSSEMessage(stubNamespaces{}))
+	endpoint.Any("/mcp", handlers.HandleEndpointHTTP(stubNamespaces{}))
+	endpoint.GET("/ws", handlers.HandleEndpointWebSocket(stubNamespaces{}, true))
+	endpoint.GET("/api/openapi.json", handlers.HandleEndpointOpenAPI(endpoints, stubNamespaces{}, stubBranding{}, nil, fixtureBaseURL))
+	endpoint.GET("/api/docs", handlers.HandleEndpointOpenAPI(endpoints, stubNamespaces{}, stubBranding{}, nil, fixtureBaseURL))
+	endpoint.GET("/sdk", handlers.HandleEndpointSDK(endpoints, stubNamespaces{}, stubBranding{}, nil, fixtureBaseURL))
+	endpoint.GET("/collection", handlers.HandleEndpointCollection(endpoints, stubNamespaces{}, stubBranding{}, nil, fixtureBaseURL))
+	endpoint.GET("/api/tools", handlers.HandleEndpointToolsList(stubNamespaces{}))
+	endpoint.POST("/api/tools/:tool_name", handlers.HandleEndpointToolExecution(stubNamespaces{}))
+	endpoint.GET("/health", handlers.HandleEndpointHealth())
+
+	base := "/api/public/endpoints/support-tools"
+	unknown := "/api/public/endpoints/billing-tools"
+	session := map[string]string{"mcp-session-id": "sse-session-1"}
+	cases := []contractCase{
+		{name: "list", method: http.MethodGet, path: "/api/public/endpoints"},
+		{name: "status", method: http.MethodGet, path: "/api/public/status/support-tools"},
+		{name: "status-not-modified", method: http.MethodGet, path: "/api/public/status/support-tools",
+			headers: map[string]string{"If-None-Match": "*"}},
+		{name: "status-not-found", method: http.MethodGet, path: "/api/public/status/billing-tools"},
+		{name: "discovery", method: http.MethodGet, path: "/.well-known/mcp-gateway"},
+		{name: "discovery-endpoint", method: http.MethodGet, path: "/.well-known/mcp-gateway/support-tools"},
+		{name: "discovery-endpoint-not-found", method: http.MethodGet, path: "/.well-known/mcp-gateway/billing-tools"},
+		// The stream itself never ends, so only the lookup is covered
+		{name: "sse-not-found", method: http.MethodGet, path: unknown + "/sse"},
+		{name: "message", method: http.MethodPost, path: base + "/message", headers: session,
+			body: map[string]interface{}{"type": "tool_call", "tool": "tickets__search_tickets", "arguments": map[string]interface{}{"query": "refund"}}},
+		{name: "message-without-session", method: http.MethodPost, path: base + "/message",
+			body: map[string]interface{}{"type": "tool_call", "tool": "tickets__search_tickets"}},
+		{name: "message-unknown-type", method: http.MethodPost, path: base + "/message", headers: session,
+			body: map[string]interface{}{"type": "resource_read"}},
+		{name: "mcp-initialize", method: http.MethodPost, path: base + "/mcp",
+			body: map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "initialize"}},
+		{name: "mcp-tools-list", method: http.MethodPost, path: base + "/mcp",
+			body: map[string]interface{}{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}},
+		{name: "ws-without-subprotocol", method: http.MethodGet, path: base + "/ws"},
+		{name: "ws-not-found", method: http.MethodGet, path: unknown + "/ws"},
+		{name: "openapi", method: http.MethodGet, path: base + "/api/openapi.json"},
+		{name: "openapi-not-found", method: http.MethodGet, path: unknown + "/api/openapi.json"},
+		{name: "docs", method: http.MethodGet, path: base + "/api/docs"},
+		{name: "sdk-without-language", method: http.MethodGet, path: base + "/sdk"},
+		{name: "sdk-unsupported-language", method: http.MethodGet, path: base + "/sdk", query: "lang=cobol"},
+		{name: "collection", method: http.MethodGet, path: base + "/collection"},
+		{name: "collection-unsupported-format", method: http.MethodGet, path: base + "/collection", query: "format=har"},
+		{name: "tools", method: http.MethodGet, path: base + "/api/tools"},
+		{name: "tool-execute", method: http.MethodPost, path: base + "/api/tools/search_tickets",
+			body: map[string]interface{}{"query": "refund"}},
+		{name: "tool-execute-invalid", method: http.MethodPost, path: base + "/api/tools/search_tickets", body: "["},
+		{name: "health", method: http.MethodGet, path: base + "/health"},
+	}
+	// Without a JSON-RPC body, every method of the MCP route answers
+	// without reaching the namespace
+	for _, method := range anyMethods {
+		cases = append(cases, contractCase{name: "mcp-" + strings.ToLower(method), method: method, path: base + "/mcp"})
+	}
+
+	r.run(t, "public-endpoints", cases)
+}
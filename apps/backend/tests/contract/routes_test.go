@@ -0,0 +1,140 @@
+package contract
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging/plugins/file"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// served holds every route, as "METHOD /path", that a contract case of any
+// suite reached
+var served = make(map[string]bool)
+
+// uncoveredRoutes lists the routes of the gateway, as registered by
+// Server.RegisterRoutes, that no contract case reached
+func uncoveredRoutes() ([]string, error) {
+	engine, err := gatewayEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, route := range engine.Routes() {
+		key := route.Method + " " + route.Path
+		if !served[key] && !exemptRoutes[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+var (
+	gatewayOnce    sync.Once
+	gatewayBuilt   *gin.Engine
+	gatewayFailure error
+)
+
+// gatewayEngine builds the gateway's engine once per process
+func gatewayEngine() (*gin.Engine, error) {
+	gatewayOnce.Do(func() {
+		gatewayBuilt, gatewayFailure = buildGatewayEngine()
+	})
+	return gatewayBuilt, gatewayFailure
+}
+
+// buildGatewayEngine registers the gateway's routes. Building the engine
+// needs no database connection, only a JWT secret and a log backend, and
+// content filtering is skipped since its rules live in the database.
+func buildGatewayEngine() (*gin.Engine, error) {
+	if os.Getenv("JWT_SECRET") == "" {
+		os.Setenv("JWT_SECRET", "contract-test-secret-not-used-for-signing")
+	}
+	os.Setenv("SKIP_CONTENT_FILTERING", "true")
+	// Registered once per process; a second registration is refused
+	_ = logging.RegisterPlugin(file.NewFilePlugin())
+
+	logs, err := logging.NewService(&logging.LoggingConfig{
+		Backend: "file",
+		Level:   logging.LogLevel("error"),
+		Config:  map[string]interface{}{"path": os.DevNull, "rotate": false},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log service: %w", err)
+	}
+
+	// Connections are only attempted by background work, and fail
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &config.Config{}
+	cfg.SetDefaults()
+
+	// Startup warnings about the missing database are expected, and the
+	// request log would only add noise to the test output
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	writer := gin.DefaultWriter
+	gin.DefaultWriter = io.Discard
+	defer func() { gin.DefaultWriter = writer }()
+	engine, ok := server.New(cfg, unreachableDatabase{db: db}, logs).RegisterRoutes().(*gin.Engine)
+	if !ok {
+		return nil, fmt.Errorf("RegisterRoutes no longer returns a gin engine")
+	}
+	return engine, nil
+}
+
+// TestGatewayContracts covers the routes the gateway registers outside its
+// handler packages, through the gateway's own engine
+func TestGatewayContracts(t *testing.T) {
+	engine, err := gatewayEngine()
+	require.NoError(t, err)
+
+	r := &contractRouter{Engine: engine}
+	for _, tc := range []contractCase{
+		{name: "root", method: http.MethodGet, path: "/"},
+		{name: "health", method: http.MethodGet, path: "/health"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assertGolden(t, filepath.Join("testdata", "gateway-engine", tc.name+".json"), r.serve(t, tc))
+		})
+		served[tc.method+" "+tc.path] = true
+	}
+}
+
+// unreachableDatabase is a database.Service whose connections fail
+type unreachableDatabase struct {
+	db *sql.DB
+}
+
+func (d unreachableDatabase) Health() map[string]string { return map[string]string{"status": "down"} }
+func (d unreachableDatabase) Close() error              { return d.db.Close() }
+func (d unreachableDatabase) GetDB() *sql.DB            { return d.db }
+
+// exemptRoutes answer with the build, the clock or the uptime of the
+// process, which golden files cannot pin
+var exemptRoutes = map[string]bool{
+	"GET /api/version": true,
+	"GET /readyz":      true,
+	"GET /sse/status":  true,
+	"GET /ws/status":   true,
+	"GET /mcp/status":  true,
+}
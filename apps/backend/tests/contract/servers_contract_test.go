@@ -0,0 +1,463 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	fixtureAlertID     = "b3a2f1e0-d9c8-4b7a-8f6e-5d4c3b2a1f0c"
+	fixtureRecordingID = "a9f8e7d6-c5b4-4a39-8e2d-1c0b9a8f7e1d"
+	fixtureMockToolID  = "d4c3b2a1-f0e9-4d8c-9b7a-6f5e4d3c2b2e"
+)
+
+func fixtureServerOwner() *types.ServerOwner {
+	escalateAfter := 30
+	return &types.ServerOwner{
+		CreatedAt:            fixtureTime,
+		UpdatedAt:            fixtureLaterTime,
+		EscalateAfterMinutes: &escalateAfter,
+		ServerID:             fixtureServerID,
+		OrganizationID:       fixtureOrgID,
+		ContactName:          "Ticketing on-call",
+		ContactEmail:         "tickets-oncall@example.com",
+		WebhookURL:           "https://hooks.example.com/tickets",
+		WebhookSecret:        "whsec_fixture",
+		HasWebhookSecret:     true,
+	}
+}
+
+// stubServerOwners serves the owner of the fixture server, the only server
+// with one
+type stubServerOwners struct{}
+
+func (stubServerOwners) GetOwner(ctx context.Context, orgID, serverID string) (*types.ServerOwner, error) {
+	if serverID != fixtureServerID {
+		return nil, notFound("Server owner")
+	}
+	return fixtureServerOwner(), nil
+}
+
+func (stubServerOwners) SetOwner(ctx context.Context, orgID, serverID string, req *types.SetServerOwnerRequest) (*types.ServerOwner, error) {
+	owner := fixtureServerOwner()
+	owner.ContactName = req.ContactName
+	owner.ContactEmail = req.ContactEmail
+	owner.WebhookURL = req.WebhookURL
+	owner.HasWebhookSecret = req.WebhookSecret != nil
+	owner.EscalateAfterMinutes = req.EscalateAfterMinutes
+	return owner, nil
+}
+
+func (stubServerOwners) DeleteOwner(ctx context.Context, orgID, serverID string) error {
+	if serverID != fixtureServerID {
+		return notFound("Server owner")
+	}
+	return nil
+}
+
+func (stubServerOwners) ListAlerts(ctx context.Context, orgID, serverID string, limit int) ([]*types.ServerOwnerAlert, error) {
+	return []*types.ServerOwnerAlert{{
+		CreatedAt:      fixtureTime,
+		DeliveredAt:    &fixtureTime,
+		ID:             fixtureAlertID,
+		ServerID:       serverID,
+		OrganizationID: orgID,
+		ServerName:     "ticketing",
+		PreviousStatus: "active",
+		Status:         "unhealthy",
+		HealthStatus:   "connection refused",
+	}}, nil
+}
+
+func (stubServerOwners) Acknowledge(ctx context.Context, orgID, serverID, alertID, userID string) error {
+	if alertID != fixtureAlertID {
+		return notFound("Alert")
+	}
+	return nil
+}
+
+func TestServerOwnerContracts(t *testing.T) {
+	h := handlers.NewServerOwnerHandler(stubServerOwners{})
+	r := newContractRouter()
+	gateway := r.Group("/api/gateway")
+	gateway.GET("/servers/:id/owner", h.GetOwner)
+	gateway.PUT("/servers/:id/owner", h.SetOwner)
+	gateway.DELETE("/servers/:id/owner", h.DeleteOwner)
+	gateway.GET("/servers/:id/owner/alerts", h.ListAlerts)
+	gateway.POST("/servers/:id/owner/alerts/:alert_id/acknowledge", h.AcknowledgeAlert)
+
+	base := "/api/gateway/servers/" + fixtureServerID + "/owner"
+	r.run(t, "server-owners", []contractCase{
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/gateway/servers/" + missingID + "/owner"},
+		{name: "get-invalid-id", method: http.MethodGet, path: "/api/gateway/servers/ticketing/owner"},
+		{name: "set", method: http.MethodPut, path: base,
+			body: map[string]interface{}{"contact_name": "Ticketing on-call", "contact_email": "tickets-oncall@example.com",
+				"webhook_url": "https://hooks.example.com/tickets", "webhook_secret": "whsec_fixture", "escalate_after_minutes": 30}},
+		{name: "set-invalid", method: http.MethodPut, path: base,
+			body: map[string]interface{}{"contact_email": "on-call", "escalate_after_minutes": 0}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "alerts", method: http.MethodGet, path: base + "/alerts", query: "limit=10"},
+		{name: "alerts-invalid", method: http.MethodGet, path: base + "/alerts", query: "limit=0"},
+		{name: "acknowledge", method: http.MethodPost, path: base + "/alerts/" + fixtureAlertID + "/acknowledge"},
+		{name: "acknowledge-not-found", method: http.MethodPost, path: base + "/alerts/" + missingID + "/acknowledge"},
+		{name: "acknowledge-invalid-id", method: http.MethodPost, path: base + "/alerts/latest/acknowledge"},
+	})
+}
+
+func fixtureServerRecording() *types.ServerRecording {
+	return &types.ServerRecording{
+		RecordedAt:     fixtureTime,
+		LastReplayedAt: &fixtureLaterTime,
+		Params:         json.RawMessage(`{"name":"search_tickets","arguments":{"query":"refund"}}`),
+		Result:         json.RawMessage(`{"content":[{"type":"text","text":"2 tickets found"}]}`),
+		ID:             fixtureRecordingID,
+		ServerID:       fixtureServerID,
+		Fingerprint:    "sha256:4f2a9c1e7b3d",
+		Method:         "tools/call",
+		ReplayCount:    7,
+	}
+}
+
+// stubServerRecordings replays the fixture recording
+type stubServerRecordings struct{}
+
+func (stubServerRecordings) GetStatus(ctx context.Context, orgID, serverID string) (*types.ServerRecordingStatus, error) {
+	return &types.ServerRecordingStatus{ServerID: serverID, Mode: types.RecordingModeReplay, Recordings: 1}, nil
+}
+
+func (stubServerRecordings) SetMode(ctx context.Context, orgID, serverID string, req *types.SetRecordingModeRequest) (*types.ServerRecordingStatus, error) {
+	return &types.ServerRecordingStatus{ServerID: serverID, Mode: req.Mode, Recordings: 1}, nil
+}
+
+func (stubServerRecordings) ListRecordings(ctx context.Context, orgID, serverID, method string) ([]*types.ServerRecording, error) {
+	if method != "" && method != "tools/call" {
+		return []*types.ServerRecording{}, nil
+	}
+	return []*types.ServerRecording{fixtureServerRecording()}, nil
+}
+
+func (stubServerRecordings) ImportRecordings(ctx context.Context, orgID, serverID string, req *types.ImportRecordingsRequest) ([]*types.ServerRecording, error) {
+	recordings := make([]*types.ServerRecording, 0, len(req.Recordings))
+	for _, imported := range req.Recordings {
+		recording := fixtureServerRecording()
+		recording.LastReplayedAt = nil
+		recording.ReplayCount = 0
+		recording.Method = imported.Method
+		recording.Params = imported.Params
+		recording.Result = imported.Result
+		recording.Error = imported.Error
+		recordings = append(recordings, recording)
+	}
+	return recordings, nil
+}
+
+func (stubServerRecordings) DeleteRecording(ctx context.Context, orgID, serverID, id string) error {
+	if id != fixtureRecordingID {
+		return notFound("Recording")
+	}
+	return nil
+}
+
+func (stubServerRecordings) ClearRecordings(ctx context.Context, orgID, serverID string) (int, error) {
+	return 1, nil
+}
+
+func TestServerRecordingContracts(t *testing.T) {
+	h := handlers.NewServerRecordingHandler(stubServerRecordings{})
+	r := newContractRouter()
+	gateway := r.Group("/api/gateway")
+	gateway.GET("/servers/:id/recording", h.GetStatus)
+	gateway.PUT("/servers/:id/recording", h.SetMode)
+	gateway.GET("/servers/:id/recordings", h.ListRecordings)
+	gateway.POST("/servers/:id/recordings", h.ImportRecordings)
+	gateway.DELETE("/servers/:id/recordings", h.ClearRecordings)
+	gateway.DELETE("/servers/:id/recordings/:recording_id", h.DeleteRecording)
+
+	base := "/api/gateway/servers/" + fixtureServerID
+	r.run(t, "server-recordings", []contractCase{
+		{name: "status", method: http.MethodGet, path: base + "/recording"},
+		{name: "set-mode", method: http.MethodPut, path: base + "/recording", body: map[string]interface{}{"mode": "record"}},
+		{name: "set-mode-invalid", method: http.MethodPut, path: base + "/recording", body: map[string]interface{}{"mode": "rewind"}},
+		{name: "list", method: http.MethodGet, path: base + "/recordings", query: "method=tools/call"},
+		{name: "import", method: http.MethodPost, path: base + "/recordings",
+			body: map[string]interface{}{"recordings": []interface{}{map[string]interface{}{
+				"method": "tools/call",
+				"params": map[string]interface{}{"name": "search_tickets", "arguments": map[string]interface{}{"query": "refund"}},
+				"error":  map[string]interface{}{"code": -32000, "message": "ticket index is rebuilding"},
+			}}}},
+		{name: "import-invalid", method: http.MethodPost, path: base + "/recordings", body: map[string]interface{}{"recordings": []interface{}{}}},
+		{name: "clear", method: http.MethodDelete, path: base + "/recordings"},
+		{name: "delete", method: http.MethodDelete, path: base + "/recordings/" + fixtureRecordingID},
+		{name: "delete-not-found", method: http.MethodDelete, path: base + "/recordings/" + missingID},
+	})
+}
+
+// stubHeaderPropagation propagates the caller's tenant header
+type stubHeaderPropagation struct{}
+
+func (stubHeaderPropagation) GetRules(ctx context.Context, orgID, scopeType, scopeID string) (*types.HeaderPropagationSettings, error) {
+	return &types.HeaderPropagationSettings{
+		UpdatedAt: &fixtureLaterTime,
+		Rules: types.HeaderPropagationRules{
+			Set:       map[string]string{"X-Caller": "{{.user_id}}"},
+			Propagate: []string{"X-Tenant-*"},
+			Strip:     []string{"X-Tenant-Secret"},
+		},
+		ScopeType: scopeType,
+		ScopeID:   scopeID,
+	}, nil
+}
+
+func (stubHeaderPropagation) SetRules(ctx context.Context, orgID, scopeType, scopeID string, rules *types.HeaderPropagationRules) (*types.HeaderPropagationSettings, error) {
+	for _, name := range rules.Propagate {
+		if name == "Authorization" {
+			return nil, types.NewValidationError("Authorization cannot be propagated")
+		}
+	}
+	return &types.HeaderPropagationSettings{UpdatedAt: &fixtureLaterTime, Rules: *rules, ScopeType: scopeType, ScopeID: scopeID}, nil
+}
+
+func (stubHeaderPropagation) DeleteRules(ctx context.Context, orgID, scopeType, scopeID string) error {
+	if scopeID == missingID {
+		return notFound("Header propagation rules")
+	}
+	return nil
+}
+
+func TestHeaderPropagationContracts(t *testing.T) {
+	h := handlers.NewHeaderPropagationHandler(stubHeaderPropagation{})
+	r := newContractRouter()
+	r.GET("/api/gateway/servers/:id/header-propagation", h.GetServerRules)
+	r.PUT("/api/gateway/servers/:id/header-propagation", h.SetServerRules)
+	r.DELETE("/api/gateway/servers/:id/header-propagation", h.DeleteServerRules)
+	r.GET("/api/namespaces/:id/header-propagation", h.GetNamespaceRules)
+	r.PUT("/api/namespaces/:id/header-propagation", h.SetNamespaceRules)
+	r.DELETE("/api/namespaces/:id/header-propagation", h.DeleteNamespaceRules)
+
+	server := "/api/gateway/servers/" + fixtureServerID + "/header-propagation"
+	namespace := "/api/namespaces/" + fixtureNamespaceID + "/header-propagation"
+	r.run(t, "header-propagation", []contractCase{
+		{name: "server-get", method: http.MethodGet, path: server},
+		{name: "server-set", method: http.MethodPut, path: server,
+			body: map[string]interface{}{"propagate": []string{"X-Request-ID"}, "set": map[string]string{"X-Caller": "{{.user_id}}"}}},
+		{name: "server-set-rejected", method: http.MethodPut, path: server, body: map[string]interface{}{"propagate": []string{"Authorization"}}},
+		{name: "server-delete", method: http.MethodDelete, path: server},
+		{name: "namespace-get", method: http.MethodGet, path: namespace},
+		{name: "namespace-set", method: http.MethodPut, path: namespace,
+			body: map[string]interface{}{"propagate": []string{"X-Tenant-*"}, "strip": []string{"X-Tenant-Secret"}}},
+		{name: "namespace-set-invalid", method: http.MethodPut, path: namespace, body: map[string]interface{}{"propagate": "X-Tenant-*"}},
+		{name: "namespace-delete", method: http.MethodDelete, path: namespace},
+		{name: "namespace-delete-not-found", method: http.MethodDelete, path: "/api/namespaces/" + missingID + "/header-propagation"},
+	})
+}
+
+// stubIdentityInjection asserts the caller in the _meta of tool calls
+type stubIdentityInjection struct{}
+
+func (stubIdentityInjection) GetSettings(ctx context.Context, orgID, serverID string) (*types.IdentityInjectionSettings, error) {
+	if serverID == missingID {
+		return nil, notFound("Identity injection settings")
+	}
+	return &types.IdentityInjectionSettings{
+		UpdatedAt: &fixtureLaterTime,
+		ServerID:  serverID,
+		Target:    "meta",
+		Fields:    []string{"user_id", "email", "organization_id"},
+	}, nil
+}
+
+func (stubIdentityInjection) SetSettings(ctx context.Context, orgID, serverID string, req *types.SetIdentityInjectionRequest) (*types.IdentityInjectionSettings, error) {
+	if req.Target == "argument" && req.Argument == "" {
+		return nil, types.NewValidationError("argument is required when target is argument")
+	}
+	return &types.IdentityInjectionSettings{
+		UpdatedAt: &fixtureLaterTime,
+		ServerID:  serverID,
+		Target:    req.Target,
+		Argument:  req.Argument,
+		Fields:    req.Fields,
+	}, nil
+}
+
+func (stubIdentityInjection) DeleteSettings(ctx context.Context, orgID, serverID string) error {
+	return nil
+}
+
+func TestIdentityInjectionContracts(t *testing.T) {
+	h := handlers.NewIdentityInjectionHandler(stubIdentityInjection{})
+	r := newContractRouter()
+	gateway := r.Group("/api/gateway")
+	gateway.GET("/servers/:id/identity-injection", h.GetSettings)
+	gateway.PUT("/servers/:id/identity-injection", h.SetSettings)
+	gateway.DELETE("/servers/:id/identity-injection", h.DeleteSettings)
+
+	base := "/api/gateway/servers/" + fixtureServerID + "/identity-injection"
+	r.run(t, "identity-injection", []contractCase{
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-not-found", method: http.MethodGet, path: "/api/gateway/servers/" + missingID + "/identity-injection"},
+		{name: "set", method: http.MethodPut, path: base,
+			body: map[string]interface{}{"target": "argument", "argument": "caller", "fields": []string{"user_id", "email"}}},
+		{name: "set-invalid", method: http.MethodPut, path: base, body: map[string]interface{}{"target": "header", "fields": []string{}}},
+		{name: "set-rejected", method: http.MethodPut, path: base, body: map[string]interface{}{"target": "argument", "fields": []string{"user_id"}}},
+		{name: "delete", method: http.MethodDelete, path: base},
+	})
+}
+
+func fixtureMockTool() *types.MockTool {
+	return &types.MockTool{
+		CreatedAt: fixtureTime,
+		UpdatedAt: fixtureLaterTime,
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"query": map[string]interface{}{"type": "string"}},
+		},
+		ID:          fixtureMockToolID,
+		ServerID:    fixtureServerID,
+		Name:        "search_tickets",
+		Description: "Search support tickets",
+		Response:    `{"tickets":[],"query":{{json .arguments.query}}}`,
+		DelayMs:     250,
+	}
+}
+
+// stubMockTools serves the fixture mock tool
+type stubMockTools struct{}
+
+func (stubMockTools) ListMockTools(ctx context.Context, orgID, serverID string) ([]*types.MockTool, error) {
+	return []*types.MockTool{fixtureMockTool()}, nil
+}
+
+func (stubMockTools) GetMockTool(ctx context.Context, orgID, serverID, id string) (*types.MockTool, error) {
+	if id == missingID {
+		return nil, notFound("Mock tool")
+	}
+	return fixtureMockTool(), nil
+}
+
+func (stubMockTools) CreateMockTool(ctx context.Context, orgID, serverID string, req *types.CreateMockToolRequest) (*types.MockTool, error) {
+	if req.Name == "search_tickets" {
+		return nil, types.NewConflictError("a mock tool named search_tickets already exists")
+	}
+	tool := fixtureMockTool()
+	tool.Name = req.Name
+	tool.Description = req.Description
+	tool.Response = req.Response
+	tool.IsError = req.IsError
+	tool.DelayMs = req.DelayMs
+	tool.InputSchema = req.InputSchema
+	return tool, nil
+}
+
+func (stubMockTools) UpdateMockTool(ctx context.Context, orgID, serverID, id string, req *types.UpdateMockToolRequest) (*types.MockTool, error) {
+	tool := fixtureMockTool()
+	if req.Response != nil {
+		tool.Response = *req.Response
+	}
+	if req.IsError != nil {
+		tool.IsError = *req.IsError
+	}
+	return tool, nil
+}
+
+func (stubMockTools) DeleteMockTool(ctx context.Context, orgID, serverID, id string) error {
+	if id == missingID {
+		return notFound("Mock tool")
+	}
+	return nil
+}
+
+func TestMockToolContracts(t *testing.T) {
+	h := handlers.NewMockServerHandler(stubMockTools{})
+	r := newContractRouter()
+	gateway := r.Group("/api/gateway")
+	gateway.GET("/servers/:id/mock-tools", h.ListTools)
+	gateway.POST("/servers/:id/mock-tools", h.CreateTool)
+	gateway.GET("/servers/:id/mock-tools/:tool_id", h.GetTool)
+	gateway.PUT("/servers/:id/mock-tools/:tool_id", h.UpdateTool)
+	gateway.DELETE("/servers/:id/mock-tools/:tool_id", h.DeleteTool)
+
+	base := "/api/gateway/servers/" + fixtureServerID + "/mock-tools"
+	r.run(t, "mock-tools", []contractCase{
+		{name: "list", method: http.MethodGet, path: base},
+		{name: "create", method: http.MethodPost, path: base,
+			body: map[string]interface{}{"name": "close_ticket", "response": "ticket is closed", "is_error": true}},
+		{name: "create-invalid", method: http.MethodPost, path: base, body: map[string]interface{}{"name": "slow", "delay_ms": 120000}},
+		{name: "create-conflict", method: http.MethodPost, path: base, body: map[string]interface{}{"name": "search_tickets"}},
+		{name: "get", method: http.MethodGet, path: base + "/" + fixtureMockToolID},
+		{name: "get-not-found", method: http.MethodGet, path: base + "/" + missingID},
+		{name: "update", method: http.MethodPut, path: base + "/" + fixtureMockToolID, body: map[string]interface{}{"response": `{"tickets":[]}`}},
+		{name: "delete", method: http.MethodDelete, path: base + "/" + fixtureMockToolID},
+		{name: "delete-not-found", method: http.MethodDelete, path: base + "/" + missingID},
+	})
+}
+
+func fixtureDLPPolicy() *types.DLPPolicy {
+	return &types.DLPPolicy{
+		UpdatedAt:   &fixtureLaterTime,
+		ServerID:    fixtureServerID,
+		Sensitivity: "confidential",
+		Action:      "redact",
+	}
+}
+
+// stubDLP holds a policy for the fixture server; other servers get the
+// gateway's default
+type stubDLP struct{}
+
+func (stubDLP) GetPolicy(ctx context.Context, orgID, serverID string) (*types.DLPPolicy, error) {
+	if serverID != fixtureServerID {
+		return &types.DLPPolicy{ServerID: serverID, Sensitivity: "internal", Action: "log", Default: true}, nil
+	}
+	return fixtureDLPPolicy(), nil
+}
+
+func (stubDLP) SetPolicy(ctx context.Context, orgID, serverID string, req *types.SetDLPPolicyRequest) (*types.DLPPolicy, error) {
+	policy := fixtureDLPPolicy()
+	policy.Sensitivity = req.Sensitivity
+	policy.Action = req.Action
+	return policy, nil
+}
+
+func (stubDLP) DeletePolicy(ctx context.Context, orgID, serverID string) error {
+	return nil
+}
+
+func (stubDLP) ListEvents(ctx context.Context, orgID, serverID string, limit int) ([]*types.DLPEvent, error) {
+	return []*types.DLPEvent{{
+		CreatedAt:      fixtureTime,
+		Findings:       []types.DLPFinding{{Path: "rows[2].email", Classifier: "email", Sensitivity: "confidential"}},
+		ID:             "e5d4c3b2-a1f0-4e9d-8c7b-6a5f4e3d2c3f",
+		OrganizationID: orgID,
+		NamespaceID:    fixtureNamespaceID,
+		ServerID:       fixtureServerID,
+		Tool:           "tickets__search_tickets",
+		Action:         "redact",
+		UserID:         fixtureUserID,
+	}}, nil
+}
+
+func TestDLPContracts(t *testing.T) {
+	h := handlers.NewDLPHandler(stubDLP{})
+	r := newContractRouter()
+	r.GET("/api/gateway/servers/:id/dlp-policy", h.GetPolicy)
+	r.PUT("/api/gateway/servers/:id/dlp-policy", h.SetPolicy)
+	r.DELETE("/api/gateway/servers/:id/dlp-policy", h.DeletePolicy)
+	r.GET("/api/admin/dlp/events", h.ListEvents)
+
+	base := "/api/gateway/servers/" + fixtureServerID + "/dlp-policy"
+	r.run(t, "dlp", []contractCase{
+		{name: "get", method: http.MethodGet, path: base},
+		{name: "get-default", method: http.MethodGet, path: "/api/gateway/servers/" + fixtureServer2ID + "/dlp-policy"},
+		{name: "set", method: http.MethodPut, path: base, body: map[string]interface{}{"sensitivity": "restricted", "action": "block"}},
+		{name: "set-invalid", method: http.MethodPut, path: base, body: map[string]interface{}{"sensitivity": "secret", "action": "drop"}},
+		{name: "delete", method: http.MethodDelete, path: base},
+		{name: "events", method: http.MethodGet, path: "/api/admin/dlp/events", query: "server_id=" + fixtureServerID + "&limit=20"},
+		{name: "events-invalid", method: http.MethodGet, path: "/api/admin/dlp/events", query: "limit=many"},
+	})
+}
@@ -0,0 +1,132 @@
+package contract
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+func TestTransportContracts(t *testing.T) {
+	// Every transport is disabled, so no connection is opened and the
+	// responses cover the gateway's answers around the protocols
+	manager := transport.NewManager(&types.TransportConfig{})
+	rpc := handlers.NewRPCHandler(manager, nil, nil)
+	sse := handlers.NewSSEHandler(manager)
+	ws := handlers.NewWebSocketHandler(manager)
+	mcp := handlers.NewMCPHandler(manager)
+	stdio := handlers.NewSTDIOHandler(manager)
+
+	r := newContractRouter()
+	r.Use(
+		middleware.NewPathRewriteMiddleware().Handler(),
+		middleware.ServerContextMiddleware(),
+		middleware.TransportTypeMiddleware(),
+		middleware.SessionIDMiddleware(),
+	)
+	r.POST("/rpc", rpc.HandleJSONRPC)
+	r.POST("/rpc/batch", rpc.HandleBatchRPC)
+	r.GET("/rpc/introspection", rpc.HandleRPCIntrospection)
+	r.GET("/rpc/health", rpc.HandleRPCHealth)
+	r.GET("/sse", sse.HandleSSE)
+	r.POST("/sse/events", sse.HandleSSEEvents)
+	r.POST("/sse/broadcast", sse.HandleSSEBroadcast)
+	r.GET("/sse/replay/:session_id", sse.HandleSSEReplay)
+	r.GET("/sse/health", sse.HandleSSEHealth)
+	r.GET("/ws", ws.HandleWebSocket)
+	r.POST("/ws/send", ws.HandleWebSocketSend)
+	r.POST("/ws/broadcast", ws.HandleWebSocketBroadcast)
+	r.POST("/ws/ping", ws.HandleWebSocketPing)
+	r.DELETE("/ws/close", ws.HandleWebSocketClose)
+	r.GET("/ws/health", ws.HandleWebSocketHealth)
+	r.GET("/ws/metrics", ws.HandleWebSocketMetrics)
+	r.Any("/mcp", mcp.HandleStreamableHTTP)
+	r.GET("/mcp/capabilities", mcp.HandleMCPCapabilities)
+	r.GET("/mcp/health", mcp.HandleMCPHealth)
+	r.POST("/stdio/execute", stdio.HandleSTDIOExecute)
+	r.Any("/stdio/process", stdio.HandleSTDIOProcess)
+	r.POST("/stdio/send", stdio.HandleSTDIOSend)
+	r.GET("/stdio/health", stdio.HandleSTDIOHealth)
+	r.POST("/servers/:server_id/rpc", rpc.HandleJSONRPC)
+	r.GET("/servers/:server_id/sse", sse.HandleServerSSE)
+	r.GET("/servers/:server_id/ws", ws.HandleServerWebSocket)
+	r.Any("/servers/:server_id/mcp", mcp.HandleServerStreamableHTTP)
+
+	server := "/servers/" + fixtureServerID
+	session := map[string]string{"X-Session-ID": "session-1"}
+	cases := []contractCase{
+		{name: "rpc-ping", method: http.MethodPost, path: "/rpc",
+			body: map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": map[string]interface{}{"name": "ping"}}},
+		{name: "rpc-resources-list", method: http.MethodPost, path: "/rpc",
+			body: map[string]interface{}{"jsonrpc": "2.0", "id": 2, "method": "resources/list"}},
+		{name: "rpc-prompts-list", method: http.MethodPost, path: "/rpc",
+			body: map[string]interface{}{"jsonrpc": "2.0", "id": 3, "method": "prompts/list"}},
+		{name: "rpc-unknown-tool", method: http.MethodPost, path: "/rpc",
+			body: map[string]interface{}{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": map[string]interface{}{"name": "deploy"}}},
+		{name: "rpc-parse-error", method: http.MethodPost, path: "/rpc", body: "{"},
+		{name: "rpc-invalid-version", method: http.MethodPost, path: "/rpc",
+			body: map[string]interface{}{"jsonrpc": "1.0", "id": 5, "method": "prompts/list"}},
+		{name: "server-rpc-invalid-version", method: http.MethodPost, path: server + "/rpc",
+			body: map[string]interface{}{"jsonrpc": "1.0", "id": 6, "method": "tools/list"}},
+		{name: "rpc-batch", method: http.MethodPost, path: "/rpc/batch",
+			body: []map[string]interface{}{
+				{"jsonrpc": "2.0", "id": 1, "method": "resources/list"},
+				{"jsonrpc": "2.0", "id": 2, "method": "prompts/list"},
+			}},
+		{name: "rpc-batch-empty", method: http.MethodPost, path: "/rpc/batch", body: []map[string]interface{}{}},
+		{name: "rpc-batch-parse-error", method: http.MethodPost, path: "/rpc/batch", body: "["},
+		{name: "rpc-introspection", method: http.MethodGet, path: "/rpc/introspection"},
+		{name: "rpc-health", method: http.MethodGet, path: "/rpc/health"},
+		{name: "sse", method: http.MethodGet, path: "/sse"},
+		{name: "server-sse", method: http.MethodGet, path: server + "/sse"},
+		{name: "sse-events-invalid", method: http.MethodPost, path: "/sse/events", body: "{"},
+		{name: "sse-events-not-found", method: http.MethodPost, path: "/sse/events", headers: session,
+			body: map[string]interface{}{"event": "message", "data": map[string]interface{}{"text": "hello"}}},
+		{name: "sse-broadcast", method: http.MethodPost, path: "/sse/broadcast",
+			body: map[string]interface{}{"event": "message", "data": map[string]interface{}{"text": "hello"}}},
+		{name: "sse-replay-not-found", method: http.MethodGet, path: "/sse/replay/session-1"},
+		{name: "sse-health", method: http.MethodGet, path: "/sse/health"},
+		{name: "ws", method: http.MethodGet, path: "/ws"},
+		{name: "server-ws", method: http.MethodGet, path: server + "/ws"},
+		{name: "ws-send-without-session", method: http.MethodPost, path: "/ws/send",
+			body: map[string]interface{}{"type": "text", "data": "hello"}},
+		{name: "ws-send-invalid-type", method: http.MethodPost, path: "/ws/send", headers: session,
+			body: map[string]interface{}{"type": "shout", "data": "hello"}},
+		{name: "ws-send-not-found", method: http.MethodPost, path: "/ws/send", headers: session,
+			body: map[string]interface{}{"type": "text", "data": "hello"}},
+		{name: "ws-broadcast", method: http.MethodPost, path: "/ws/broadcast",
+			body: map[string]interface{}{"type": "text", "data": "hello"}},
+		{name: "ws-ping-not-found", method: http.MethodPost, path: "/ws/ping", headers: session},
+		{name: "ws-close", method: http.MethodDelete, path: "/ws/close", headers: session},
+		{name: "ws-health", method: http.MethodGet, path: "/ws/health"},
+		{name: "ws-metrics", method: http.MethodGet, path: "/ws/metrics", query: "session_id=session-1"},
+		{name: "mcp-capabilities", method: http.MethodGet, path: "/mcp/capabilities"},
+		{name: "mcp-health", method: http.MethodGet, path: "/mcp/health"},
+		{name: "stdio-execute-invalid", method: http.MethodPost, path: "/stdio/execute", body: "{"},
+		{name: "stdio-execute", method: http.MethodPost, path: "/stdio/execute",
+			body: map[string]interface{}{"command": "echo", "args": []string{"hello"}}},
+		{name: "stdio-process-invalid-action", method: http.MethodPost, path: "/stdio/process", query: "action=pause"},
+		{name: "stdio-send-without-session", method: http.MethodPost, path: "/stdio/send",
+			body: map[string]interface{}{"message": "hello"}},
+		{name: "stdio-send", method: http.MethodPost, path: "/stdio/send", headers: session,
+			body: map[string]interface{}{"message": "hello"}},
+		{name: "stdio-health", method: http.MethodGet, path: "/stdio/health"},
+	}
+	// The streamable transport is disabled, so every method fails to open a
+	// connection, and without an action every method of the process route is
+	// rejected
+	for _, method := range anyMethods {
+		lower := strings.ToLower(method)
+		cases = append(cases,
+			contractCase{name: "mcp-" + lower, method: method, path: "/mcp"},
+			contractCase{name: "server-mcp-" + lower, method: method, path: server + "/mcp"},
+			contractCase{name: "stdio-process-" + lower, method: method, path: "/stdio/process"},
+		)
+	}
+
+	r.run(t, "transport", cases)
+}
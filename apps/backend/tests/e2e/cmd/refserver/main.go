@@ -0,0 +1,51 @@
+// Command refserver runs a reference MCP server for the end-to-end tests
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/tests/e2e/refserver"
+)
+
+func main() {
+	var (
+		transport = flag.String("transport", refserver.TransportHTTP, "Transport to serve: http, sse, websocket or stdio")
+		addr      = flag.String("addr", ":9000", "Address to listen on for network transports")
+		name      = flag.String("name", "", "Server name reported to clients (defaults to refserver-<transport>)")
+	)
+	flag.Parse()
+
+	if *name == "" {
+		*name = "refserver-" + *transport
+	}
+	server := refserver.New(*name)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	switch *transport {
+	case refserver.TransportSTDIO:
+		// Logs go to stderr; stdout carries the protocol
+		if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && err != context.Canceled {
+			log.Fatalf("STDIO server failed: %v", err)
+		}
+	case refserver.TransportHTTP, refserver.TransportSSE, refserver.TransportWebSocket:
+		httpServer := &http.Server{Addr: *addr, Handler: server.HTTPHandler(*transport)}
+		go func() {
+			<-ctx.Done()
+			httpServer.Close()
+		}()
+		log.Printf("Reference %s server %s listening on %s", *transport, *name, *addr)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Server failed: %v", err)
+		}
+	default:
+		log.Fatalf("Unknown transport %q", *transport)
+	}
+}
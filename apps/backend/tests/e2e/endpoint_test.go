@@ -0,0 +1,201 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/tests/e2e/harness"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/tests/e2e/refserver"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publishedServer is a STDIO reference server published through a namespace
+// and a public endpoint
+type publishedServer struct {
+	endpoint string
+	prefix   string
+}
+
+func publishReferenceServer(t *testing.T, h *harness.Harness) publishedServer {
+	t.Helper()
+	ctx := scenarioContext(t, time.Minute)
+
+	server, err := h.RegisterReferenceServer(ctx, refserver.TransportSTDIO, uniqueName("ref-stdio"))
+	require.NoError(t, err)
+	t.Cleanup(func() { h.DeleteServer(context.Background(), server.ID) })
+
+	namespace, err := h.CreateNamespace(ctx, uniqueName("e2e"), server.ID)
+	require.NoError(t, err)
+	t.Cleanup(func() { h.DeleteNamespace(context.Background(), namespace.ID) })
+
+	endpoint, err := h.CreatePublicEndpoint(ctx, namespace.ID, uniqueName("e2e"))
+	require.NoError(t, err)
+	t.Cleanup(func() { h.DeleteEndpoint(context.Background(), endpoint.ID) })
+
+	return publishedServer{
+		endpoint: endpoint.Name,
+		prefix:   services.SanitizeServerName(server.Name) + "__",
+	}
+}
+
+// toolText returns the text content of a successful namespace tool result
+func toolText(t *testing.T, result types.NamespaceToolResult) string {
+	t.Helper()
+	require.True(t, result.Success, result.Error)
+
+	encoded, err := json.Marshal(result.Result)
+	require.NoError(t, err)
+	var call struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	require.NoError(t, json.Unmarshal(encoded, &call))
+	require.NotEmpty(t, call.Content)
+	return call.Content[0].Text
+}
+
+func TestEndpoint_StreamableHTTP(t *testing.T) {
+	h := requireStack(t)
+	published := publishReferenceServer(t, h)
+	ctx := scenarioContext(t, time.Minute)
+	path := harness.EndpointPath(published.endpoint, "/mcp")
+
+	resp, err := h.RPC(ctx, path, "initialize", map[string]interface{}{
+		"protocolVersion": "2024-11-05",
+		"clientInfo":      map[string]string{"name": "e2e", "version": "1.0.0"},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+
+	resp, err = h.RPC(ctx, path, "tools/list", map[string]interface{}{})
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	var list struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Result, &list))
+	var names []string
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+	}
+	for _, tool := range refserver.Tools {
+		assert.Contains(t, names, published.prefix+tool["name"].(string))
+	}
+
+	resp, err = h.RPC(ctx, path, "tools/call", map[string]interface{}{
+		"name":      published.prefix + "add",
+		"arguments": map[string]interface{}{"a": 2, "b": 3},
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	var result types.NamespaceToolResult
+	require.NoError(t, json.Unmarshal(resp.Result, &result))
+	assert.Equal(t, "5", toolText(t, result))
+
+	// Tool errors of the server reach the client as failed results
+	resp, err = h.RPC(ctx, path, "tools/call", map[string]interface{}{"name": published.prefix + "fail"})
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	require.NoError(t, json.Unmarshal(resp.Result, &result))
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error, "tool execution failed")
+
+	resp, err = h.RPC(ctx, path, "resources/subscribe", nil)
+	require.NoError(t, err)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, types.MCPErrorCodeMethodNotFound, resp.Error.Code)
+}
+
+func TestEndpoint_SSE(t *testing.T) {
+	h := requireStack(t)
+	published := publishReferenceServer(t, h)
+	ctx := scenarioContext(t, time.Minute)
+
+	stream, err := h.OpenSSE(ctx, harness.EndpointPath(published.endpoint, "/sse"))
+	require.NoError(t, err)
+	defer stream.Close()
+
+	event, err := stream.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "connected", event.Name)
+	require.NotEmpty(t, stream.SessionID)
+
+	var result types.NamespaceToolResult
+	err = h.SendSSEMessage(ctx, published.endpoint, stream.SessionID, map[string]interface{}{
+		"type":      "tool_call",
+		"tool":      published.prefix + "echo",
+		"arguments": map[string]interface{}{"text": "over sse"},
+	}, &result)
+	require.NoError(t, err)
+	assert.Equal(t, "over sse", toolText(t, result))
+
+	// Messages need the session of a stream
+	err = h.SendSSEMessage(ctx, published.endpoint, "", map[string]interface{}{"type": "tool_call"}, nil)
+	var apiErr *harness.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+}
+
+func TestEndpoint_WebSocket(t *testing.T) {
+	h := requireStack(t)
+	published := publishReferenceServer(t, h)
+	ctx := scenarioContext(t, time.Minute)
+
+	conn, err := h.DialWebSocket(ctx, harness.EndpointPath(published.endpoint, "/ws"))
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(30*time.Second)))
+
+	var welcome map[string]interface{}
+	require.NoError(t, conn.ReadJSON(&welcome))
+	assert.Equal(t, "welcome", welcome["type"])
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "ping"}))
+	var pong map[string]interface{}
+	require.NoError(t, conn.ReadJSON(&pong))
+	assert.Equal(t, "pong", pong["type"])
+
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{
+		"type":      "tool_call",
+		"tool":      published.prefix + "echo",
+		"arguments": map[string]interface{}{"text": "over websocket"},
+	}))
+	var answer struct {
+		Type   string                    `json:"type"`
+		Result types.NamespaceToolResult `json:"result"`
+	}
+	require.NoError(t, conn.ReadJSON(&answer))
+	assert.Equal(t, "tool_result", answer.Type)
+	assert.Equal(t, "over websocket", toolText(t, answer.Result))
+}
+
+func TestEndpoint_REST(t *testing.T) {
+	h := requireStack(t)
+	published := publishReferenceServer(t, h)
+	ctx := scenarioContext(t, time.Minute)
+
+	var result types.NamespaceToolResult
+	err := h.Do(ctx, http.MethodPost,
+		harness.EndpointPath(published.endpoint, "/api/tools/"+published.prefix+"echo"),
+		map[string]interface{}{"text": "over rest"}, &result)
+	require.NoError(t, err)
+	assert.Equal(t, "over rest", toolText(t, result))
+
+	// Unknown servers are reported in the result
+	err = h.Do(ctx, http.MethodPost,
+		harness.EndpointPath(published.endpoint, "/api/tools/missing__echo"),
+		map[string]interface{}{}, &result)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error, "server not found")
+}
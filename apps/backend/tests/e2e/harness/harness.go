@@ -0,0 +1,412 @@
+// Package harness runs end-to-end scenarios against a live gateway. It can
+// bring up the docker compose e2e profile (gateway, Postgres, Redis and the
+// reference MCP servers) or drive a stack that is already running, and offers
+// helpers for the admin API and the MCP transports of public endpoints.
+package harness
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/tests/e2e/refserver"
+)
+
+// ErrNotConfigured is returned by ConfigFromEnv when no stack is configured,
+// in which case the scenarios are skipped
+var ErrNotConfigured = errors.New("e2e: set E2E_COMPOSE=1 or E2E_GATEWAY_URL to run the end-to-end tests")
+
+// Config describes the stack under test
+type Config struct {
+	// GatewayURL is where the tests reach the gateway
+	GatewayURL string
+	// ComposeFile, when set, is brought up with the e2e profile before the
+	// scenarios run and torn down after them
+	ComposeFile   string
+	AdminEmail    string
+	AdminPassword string
+	// StartupTimeout bounds the wait for the gateway to become healthy
+	StartupTimeout time.Duration
+	// ReferenceServers are the URLs of the network reference servers as the
+	// gateway reaches them, keyed by transport
+	ReferenceServers map[string]string
+	// StdioCommand starts the STDIO reference server on the gateway's host
+	StdioCommand string
+	StdioArgs    []string
+}
+
+// ConfigFromEnv reads the stack from the E2E_* environment variables:
+//
+//	E2E_COMPOSE=1          start the compose e2e profile (E2E_COMPOSE_FILE overrides the file)
+//	E2E_GATEWAY_URL        gateway to test, http://localhost:8080 by default
+//	E2E_ADMIN_EMAIL        admin login, admin@admin.com by default
+//	E2E_ADMIN_PASSWORD     admin password, qwerty123 by default
+//	E2E_HTTP_SERVER_URL    reference servers as seen by the gateway
+//	E2E_SSE_SERVER_URL
+//	E2E_WS_SERVER_URL
+//	E2E_STDIO_COMMAND      STDIO reference server binary on the gateway's host
+func ConfigFromEnv() (Config, error) {
+	compose := os.Getenv("E2E_COMPOSE") != ""
+	gatewayURL := os.Getenv("E2E_GATEWAY_URL")
+	if !compose && gatewayURL == "" {
+		return Config{}, ErrNotConfigured
+	}
+
+	cfg := Config{
+		GatewayURL:     strings.TrimSuffix(envOr("E2E_GATEWAY_URL", "http://localhost:8080"), "/"),
+		AdminEmail:     envOr("E2E_ADMIN_EMAIL", "admin@admin.com"),
+		AdminPassword:  envOr("E2E_ADMIN_PASSWORD", "qwerty123"),
+		StartupTimeout: 5 * time.Minute,
+		ReferenceServers: map[string]string{
+			refserver.TransportHTTP:      envOr("E2E_HTTP_SERVER_URL", "http://refserver-http:9000"),
+			refserver.TransportSSE:       envOr("E2E_SSE_SERVER_URL", "http://refserver-sse:9000"),
+			refserver.TransportWebSocket: envOr("E2E_WS_SERVER_URL", "http://refserver-ws:9000"),
+		},
+		StdioCommand: envOr("E2E_STDIO_COMMAND", "/refservers/refserver"),
+		StdioArgs:    []string{"-transport", refserver.TransportSTDIO},
+	}
+	if compose {
+		cfg.ComposeFile = os.Getenv("E2E_COMPOSE_FILE")
+		if cfg.ComposeFile == "" {
+			root, err := repoRoot()
+			if err != nil {
+				return Config{}, err
+			}
+			cfg.ComposeFile = filepath.Join(root, "docker-compose.dev.yml")
+		}
+	}
+	return cfg, nil
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// repoRoot walks up from the working directory to the directory holding
+// go.mod
+func repoRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("e2e: go.mod not found above the working directory")
+		}
+		dir = parent
+	}
+}
+
+// Harness is a logged-in client of the stack under test
+type Harness struct {
+	Config Config
+	client *http.Client
+	token  string
+	// started is set when the harness brought the stack up and must take it
+	// down again
+	started bool
+}
+
+// Start brings up the stack when a compose file is configured, waits for the
+// gateway to become healthy and logs in as the admin
+func Start(ctx context.Context, cfg Config) (*Harness, error) {
+	h := &Harness{Config: cfg, client: &http.Client{Timeout: 30 * time.Second}}
+
+	if cfg.ComposeFile != "" {
+		// The gateway migrates and seeds the database before it answers, so
+		// readiness is left to waitHealthy rather than the compose healthcheck
+		if err := h.compose(ctx, "up", "-d", "--build"); err != nil {
+			return nil, fmt.Errorf("failed to start the e2e stack: %w", err)
+		}
+		h.started = true
+	}
+
+	if err := h.waitHealthy(ctx); err != nil {
+		h.Stop()
+		return nil, err
+	}
+	if err := h.login(ctx); err != nil {
+		h.Stop()
+		return nil, err
+	}
+	return h, nil
+}
+
+// Stop takes down a stack the harness started, along with its volumes
+func (h *Harness) Stop() error {
+	if !h.started {
+		return nil
+	}
+	h.started = false
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	return h.compose(ctx, "down", "-v")
+}
+
+func (h *Harness) compose(ctx context.Context, args ...string) error {
+	root := filepath.Dir(h.Config.ComposeFile)
+	envFile := filepath.Join(root, ".env")
+	if _, err := os.Stat(envFile); err != nil {
+		envFile = filepath.Join(root, ".env.example")
+	}
+
+	base := []string{"compose", "-f", h.Config.ComposeFile, "--env-file", envFile, "--profile", "e2e"}
+	cmd := exec.CommandContext(ctx, "docker", append(base, args...)...)
+	cmd.Dir = root
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (h *Harness) waitHealthy(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, h.Config.StartupTimeout)
+	defer cancel()
+
+	var lastErr error
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.Config.GatewayURL+"/health", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := h.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+			err = fmt.Errorf("health check returned %d", resp.StatusCode)
+		}
+		lastErr = err
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gateway at %s did not become healthy: %w", h.Config.GatewayURL, lastErr)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (h *Harness) login(ctx context.Context) error {
+	var login struct {
+		Data struct {
+			AccessToken string `json:"access_token"`
+		} `json:"data"`
+	}
+	err := h.Do(ctx, http.MethodPost, "/api/auth/login", map[string]string{
+		"email":    h.Config.AdminEmail,
+		"password": h.Config.AdminPassword,
+	}, &login)
+	if err != nil {
+		return fmt.Errorf("failed to log in as %s: %w", h.Config.AdminEmail, err)
+	}
+	h.token = login.Data.AccessToken
+	return nil
+}
+
+// APIError is a response of the gateway with an error status
+type APIError struct {
+	Method string
+	Path   string
+	Status int
+	Body   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.Path, e.Status, e.Body)
+}
+
+// Do sends an authenticated JSON request to the gateway and decodes the
+// response into out, when out is not nil. Error statuses are returned as
+// *APIError.
+func (h *Harness) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	req, err := h.newJSONRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	return h.send(req, out)
+}
+
+func (h *Harness) newJSONRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, h.URL(path), reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+	return req, nil
+}
+
+func (h *Harness) send(req *http.Request, out interface{}) error {
+	method, path := req.Method, req.URL.Path
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return &APIError{Method: method, Path: path, Status: resp.StatusCode, Body: string(raw)}
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// URL resolves a gateway path
+func (h *Harness) URL(path string) string {
+	return h.Config.GatewayURL + path
+}
+
+// RegisterServer registers an MCP server with the gateway
+func (h *Harness) RegisterServer(ctx context.Context, req types.CreateMCPServerRequest) (*types.MCPServer, error) {
+	var created struct {
+		Data types.MCPServer `json:"data"`
+	}
+	if err := h.Do(ctx, http.MethodPost, "/api/gateway/servers", req, &created); err != nil {
+		return nil, err
+	}
+	return &created.Data, nil
+}
+
+// RegisterReferenceServer registers the reference server of a transport
+// under name
+func (h *Harness) RegisterReferenceServer(ctx context.Context, transport, name string) (*types.MCPServer, error) {
+	req := types.CreateMCPServerRequest{
+		Name:       name,
+		Protocol:   transport,
+		Version:    refserver.Version,
+		MaxRetries: 2,
+	}
+	if transport == refserver.TransportSTDIO {
+		req.Command = h.Config.StdioCommand
+		req.Args = h.Config.StdioArgs
+	} else {
+		url, ok := h.Config.ReferenceServers[transport]
+		if !ok {
+			return nil, fmt.Errorf("no reference server for transport %q", transport)
+		}
+		req.URL = url
+		req.HealthCheckURL = url + "/health"
+	}
+	return h.RegisterServer(ctx, req)
+}
+
+// DeleteServer unregisters an MCP server
+func (h *Harness) DeleteServer(ctx context.Context, id string) error {
+	return h.Do(ctx, http.MethodDelete, "/api/gateway/servers/"+id, nil, nil)
+}
+
+// Server fetches a registered MCP server
+func (h *Harness) Server(ctx context.Context, id string) (*types.MCPServer, error) {
+	var found struct {
+		Data types.MCPServer `json:"data"`
+	}
+	if err := h.Do(ctx, http.MethodGet, "/api/gateway/servers/"+id, nil, &found); err != nil {
+		return nil, err
+	}
+	return &found.Data, nil
+}
+
+// WaitForHealthCheck polls the health history of a server until the gateway
+// has checked it, and returns the latest check. Health checks run on the
+// discovery health interval, so ctx should allow for a few of them.
+func (h *Harness) WaitForHealthCheck(ctx context.Context, id string) (*types.HealthCheck, error) {
+	for {
+		var history struct {
+			Data types.ServerHealthHistory `json:"data"`
+		}
+		if err := h.Do(ctx, http.MethodGet, "/api/gateway/servers/"+id+"/health-history?limit=1", nil, &history); err != nil {
+			return nil, err
+		}
+		if len(history.Data.Checks) > 0 {
+			return history.Data.Checks[0], nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("server %s was not health checked: %w", id, ctx.Err())
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// DiscoverTools triggers tool discovery of a server
+func (h *Harness) DiscoverTools(ctx context.Context, id string) error {
+	return h.Do(ctx, http.MethodPost, "/api/gateway/servers/"+id+"/discover-tools", nil, nil)
+}
+
+// CreateNamespace creates a namespace holding the given servers
+func (h *Harness) CreateNamespace(ctx context.Context, name string, serverIDs ...string) (*types.Namespace, error) {
+	var namespace types.Namespace
+	err := h.Do(ctx, http.MethodPost, "/api/namespaces", types.CreateNamespaceRequest{
+		Name:    name,
+		Servers: serverIDs,
+	}, &namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &namespace, nil
+}
+
+// DeleteNamespace deletes a namespace
+func (h *Harness) DeleteNamespace(ctx context.Context, id string) error {
+	return h.Do(ctx, http.MethodDelete, "/api/namespaces/"+id, nil, nil)
+}
+
+// CreatePublicEndpoint publishes a namespace as an endpoint that needs no
+// credentials
+func (h *Harness) CreatePublicEndpoint(ctx context.Context, namespaceID, name string) (*types.Endpoint, error) {
+	var endpoint types.Endpoint
+	err := h.Do(ctx, http.MethodPost, "/api/endpoints", types.CreateEndpointRequest{
+		NamespaceID:        namespaceID,
+		Name:               name,
+		EnablePublicAccess: true,
+	}, &endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// DeleteEndpoint deletes an endpoint
+func (h *Harness) DeleteEndpoint(ctx context.Context, id string) error {
+	return h.Do(ctx, http.MethodDelete, "/api/endpoints/"+id, nil, nil)
+}
+
+// EndpointPath returns the path of a public endpoint's route, such as "/mcp"
+func EndpointPath(endpointName, route string) string {
+	return "/api/public/endpoints/" + endpointName + route
+}
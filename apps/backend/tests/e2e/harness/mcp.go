@@ -0,0 +1,141 @@
+package harness
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// RPCResponse is a JSON-RPC response of an endpoint
+type RPCResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *types.MCPError `json:"error,omitempty"`
+}
+
+var rpcIDs atomic.Int64
+
+// RPC sends a JSON-RPC request to a path of the gateway, such as the /mcp
+// route of an endpoint
+func (h *Harness) RPC(ctx context.Context, path, method string, params interface{}) (*RPCResponse, error) {
+	var resp RPCResponse
+	err := h.Do(ctx, http.MethodPost, path, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      rpcIDs.Add(1),
+		"method":  method,
+		"params":  params,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Event is a server-sent event
+type Event struct {
+	Name string
+	Data string
+}
+
+// EventStream reads the events of an SSE connection
+type EventStream struct {
+	resp    *http.Response
+	scanner *bufio.Scanner
+	// SessionID is the mcp-session-id the gateway assigned to the stream
+	SessionID string
+}
+
+// OpenSSE opens the event stream of a gateway path. The stream ends when ctx
+// is done or Close is called.
+func (h *Harness) OpenSSE(ctx context.Context, path string) (*EventStream, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.URL(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+
+	// The client timeout would cut the stream short
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &APIError{Method: http.MethodGet, Path: path, Status: resp.StatusCode}
+	}
+	return &EventStream{
+		resp:      resp,
+		scanner:   bufio.NewScanner(resp.Body),
+		SessionID: resp.Header.Get("mcp-session-id"),
+	}, nil
+}
+
+// Next reads the next event
+func (s *EventStream) Next() (Event, error) {
+	var event Event
+	var data []string
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		switch {
+		case line == "":
+			if event.Name != "" || len(data) > 0 {
+				event.Data = strings.Join(data, "\n")
+				return event, nil
+			}
+		case strings.HasPrefix(line, "event:"):
+			event.Name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+	return Event{}, fmt.Errorf("event stream closed")
+}
+
+// Close ends the stream
+func (s *EventStream) Close() error {
+	return s.resp.Body.Close()
+}
+
+// SendSSEMessage posts a message for the SSE session of a stream to the
+// /message route of an endpoint and decodes the answer into out
+func (h *Harness) SendSSEMessage(ctx context.Context, endpointName, sessionID string, message, out interface{}) error {
+	req, err := h.newJSONRequest(ctx, http.MethodPost, EndpointPath(endpointName, "/message"), message)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("mcp-session-id", sessionID)
+	return h.send(req, out)
+}
+
+// DialWebSocket opens a WebSocket to a gateway path, offering the mcp
+// subprotocol
+func (h *Harness) DialWebSocket(ctx context.Context, path string) (*websocket.Conn, error) {
+	url := "ws" + strings.TrimPrefix(h.URL(path), "http")
+	header := http.Header{}
+	if h.token != "" {
+		header.Set("Authorization", "Bearer "+h.token)
+	}
+	dialer := websocket.Dialer{Subprotocols: []string{"mcp"}}
+	conn, resp, err := dialer.DialContext(ctx, url, header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("websocket handshake returned %d: %w", resp.StatusCode, err)
+		}
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/tests/e2e/harness"
+)
+
+// stack is the gateway under test, nil when no stack is configured
+var stack *harness.Harness
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	cfg, err := harness.ConfigFromEnv()
+	if errors.Is(err, harness.ErrNotConfigured) {
+		// Scenarios skip themselves, so that go test ./... passes without
+		// docker
+		return m.Run()
+	}
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	// Building the images can take a while on the first run; waiting for the
+	// gateway is bounded by cfg.StartupTimeout
+	stack, err = harness.Start(context.Background(), cfg)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	defer stack.Stop()
+
+	return m.Run()
+}
+
+// requireStack skips the test when no stack is configured
+func requireStack(t *testing.T) *harness.Harness {
+	t.Helper()
+	if stack == nil {
+		t.Skip(harness.ErrNotConfigured)
+	}
+	return stack
+}
+
+// scenarioContext bounds a scenario
+func scenarioContext(t *testing.T, timeout time.Duration) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// uniqueName keeps the names of servers, namespaces and endpoints apart
+// across runs against the same stack
+func uniqueName(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+}
@@ -0,0 +1,164 @@
+// Package refserver is a reference MCP server for end-to-end tests. It
+// serves the same small set of tools over HTTP, SSE, WebSocket and STDIO so
+// that scenarios can check the gateway against every transport.
+package refserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Version is reported in the server info of initialize responses
+const Version = "1.0.0"
+
+// Tools are the tools every reference server offers
+var Tools = []map[string]interface{}{
+	{
+		"name":        "echo",
+		"description": "Returns the text it is called with",
+		"inputSchema": map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"text": map[string]interface{}{"type": "string"}},
+			"required":   []string{"text"},
+		},
+	},
+	{
+		"name":        "add",
+		"description": "Adds two numbers",
+		"inputSchema": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"a": map[string]interface{}{"type": "number"},
+				"b": map[string]interface{}{"type": "number"},
+			},
+			"required": []string{"a", "b"},
+		},
+	},
+	{
+		"name":        "fail",
+		"description": "Always answers with a tool error",
+		"inputSchema": map[string]interface{}{"type": "object"},
+	},
+}
+
+// Server answers MCP requests
+type Server struct {
+	name string
+}
+
+// New creates a reference server that introduces itself as name
+func New(name string) *Server {
+	return &Server{name: name}
+}
+
+type request struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Handle answers one JSON-RPC message. Notifications get no answer, so the
+// result is nil for them. The jsonrpc member is not required, since the
+// gateway's discovery omits it.
+func (s *Server) Handle(ctx context.Context, message []byte) []byte {
+	var req request
+	if err := json.Unmarshal(message, &req); err != nil {
+		return s.encode(response{ID: json.RawMessage("null"), Error: &rpcError{Code: -32700, Message: "parse error"}})
+	}
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	result, rpcErr := s.dispatch(req)
+	return s.encode(response{ID: req.ID, Result: result, Error: rpcErr})
+}
+
+func (s *Server) dispatch(req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		if params.ProtocolVersion == "" {
+			params.ProtocolVersion = "2024-11-05"
+		}
+		return map[string]interface{}{
+			"protocolVersion": params.ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": s.name, "version": Version},
+		}, nil
+
+	case "ping":
+		return map[string]interface{}{}, nil
+
+	case "tools/list":
+		return map[string]interface{}{"tools": Tools}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: -32602, Message: "invalid tools/call params"}
+		}
+		return callTool(params.Name, params.Arguments)
+
+	default:
+		return nil, &rpcError{Code: -32601, Message: "method not found: " + req.Method}
+	}
+}
+
+func callTool(name string, arguments map[string]interface{}) (interface{}, *rpcError) {
+	switch name {
+	case "echo":
+		text, _ := arguments["text"].(string)
+		return textResult(text, false), nil
+
+	case "add":
+		a, okA := arguments["a"].(float64)
+		b, okB := arguments["b"].(float64)
+		if !okA || !okB {
+			return nil, &rpcError{Code: -32602, Message: "a and b must be numbers"}
+		}
+		result := textResult(fmt.Sprint(a+b), false)
+		result["structuredContent"] = map[string]interface{}{"sum": a + b}
+		return result, nil
+
+	case "fail":
+		return textResult("this tool always fails", true), nil
+
+	default:
+		return nil, &rpcError{Code: -32602, Message: "unknown tool: " + name}
+	}
+}
+
+func textResult(text string, isError bool) map[string]interface{} {
+	result := map[string]interface{}{
+		"content": []map[string]interface{}{{"type": "text", "text": text}},
+	}
+	if isError {
+		result["isError"] = true
+	}
+	return result
+}
+
+func (s *Server) encode(resp response) []byte {
+	resp.JSONRPC = "2.0"
+	encoded, _ := json.Marshal(resp)
+	return encoded
+}
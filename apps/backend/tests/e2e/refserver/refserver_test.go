@@ -0,0 +1,168 @@
+package refserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type answer struct {
+	ID     json.RawMessage `json:"id"`
+	Result struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		StructuredContent map[string]float64 `json:"structuredContent"`
+		IsError           bool               `json:"isError"`
+		Tools             []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	} `json:"result"`
+	Error *rpcError `json:"error"`
+}
+
+func decode(t *testing.T, raw []byte) answer {
+	t.Helper()
+	var a answer
+	require.NoError(t, json.Unmarshal(raw, &a), string(raw))
+	return a
+}
+
+func TestHandle(t *testing.T) {
+	server := New("ref")
+	ctx := context.Background()
+
+	a := decode(t, server.Handle(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)))
+	assert.Equal(t, "1", string(a.ID))
+	assert.Len(t, a.Result.Tools, len(Tools))
+
+	// The discovery client sends string IDs and no jsonrpc member
+	a = decode(t, server.Handle(ctx, []byte(`{"id":"req-1","type":"request","method":"tools/call","params":{"name":"add","arguments":{"a":2,"b":3}}}`)))
+	assert.Equal(t, `"req-1"`, string(a.ID))
+	assert.Equal(t, "5", a.Result.Content[0].Text)
+	assert.Equal(t, 5.0, a.Result.StructuredContent["sum"])
+
+	a = decode(t, server.Handle(ctx, []byte(`{"id":2,"method":"tools/call","params":{"name":"fail"}}`)))
+	assert.True(t, a.Result.IsError)
+
+	a = decode(t, server.Handle(ctx, []byte(`{"id":3,"method":"resources/list"}`)))
+	require.NotNil(t, a.Error)
+	assert.Equal(t, -32601, a.Error.Code)
+
+	a = decode(t, server.Handle(ctx, []byte(`{`)))
+	assert.Equal(t, -32700, a.Error.Code)
+
+	assert.Nil(t, server.Handle(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+}
+
+func TestHTTPHandler(t *testing.T) {
+	ts := httptest.NewServer(New("ref").HTTPHandler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Post(ts.URL+"/mcp", "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	require.NoError(t, err)
+	raw, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Nil(t, decode(t, raw).Error)
+
+	// Only the listed transports are mounted
+	limited := httptest.NewServer(New("ref").HTTPHandler(TransportSSE))
+	defer limited.Close()
+	resp, err = http.Post(limited.URL+"/mcp", "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestSSE(t *testing.T) {
+	ts := httptest.NewServer(New("ref").HTTPHandler(TransportSSE))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/sse")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	events := bufio.NewScanner(resp.Body)
+
+	next := func() (string, string) {
+		var name, data string
+		for events.Scan() {
+			line := events.Text()
+			switch {
+			case line == "":
+				return name, data
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+		t.Fatal("stream closed")
+		return "", ""
+	}
+
+	name, endpoint := next()
+	require.Equal(t, "endpoint", name)
+
+	posted, err := http.Post(ts.URL+endpoint, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`))
+	require.NoError(t, err)
+	posted.Body.Close()
+	assert.Equal(t, http.StatusAccepted, posted.StatusCode)
+
+	name, data := next()
+	assert.Equal(t, "message", name)
+	assert.Equal(t, "hi", decode(t, []byte(data)).Result.Content[0].Text)
+
+	posted, err = http.Post(ts.URL+"/messages?session_id=unknown", "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	posted.Body.Close()
+	assert.Equal(t, http.StatusNotFound, posted.StatusCode)
+}
+
+func TestWebSocket(t *testing.T) {
+	ts := httptest.NewServer(New("ref").HTTPHandler(TransportWebSocket))
+	defer ts.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"mcp"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "mcp", conn.Subprotocol())
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`)))
+	_, raw, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "hi", decode(t, raw).Result.Content[0].Text)
+}
+
+func TestServeStdio(t *testing.T) {
+	in := strings.NewReader("{\"id\":1,\"method\":\"initialize\",\"params\":{\"protocolVersion\":\"2025-06-18\"}}\n" +
+		"{\"jsonrpc\":\"2.0\",\"method\":\"notifications/initialized\"}\n\n" +
+		"{\"id\":2,\"method\":\"tools/call\",\"params\":{\"name\":\"echo\",\"arguments\":{\"text\":\"hi\"}}}\n")
+	var out strings.Builder
+	require.NoError(t, New("ref").ServeStdio(context.Background(), in, &out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	var initialized struct {
+		Result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &initialized))
+	assert.Equal(t, "2025-06-18", initialized.Result.ProtocolVersion)
+	assert.Equal(t, "hi", decode(t, []byte(lines[1])).Result.Content[0].Text)
+}
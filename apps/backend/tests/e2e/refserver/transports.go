@@ -0,0 +1,175 @@
+package refserver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// Transports the reference server can serve
+const (
+	TransportHTTP      = "http"
+	TransportSSE       = "sse"
+	TransportWebSocket = "websocket"
+	TransportSTDIO     = "stdio"
+)
+
+// HTTPHandler serves the network transports: JSON-RPC over POST /mcp,
+// GET /sse with POST /messages, and GET /ws. Only the listed transports are
+// mounted, all of them when none are listed. GET /health always answers.
+func (s *Server) HTTPHandler(transports ...string) http.Handler {
+	enabled := map[string]bool{}
+	for _, transport := range transports {
+		enabled[transport] = true
+	}
+	all := len(enabled) == 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok","server":%q}`, s.name)
+	})
+	if all || enabled[TransportHTTP] {
+		mux.HandleFunc("POST /mcp", s.serveHTTP)
+	}
+	if all || enabled[TransportSSE] {
+		sse := &sseSessions{server: s, sessions: map[string]chan []byte{}}
+		mux.HandleFunc("GET /sse", sse.serveStream)
+		mux.HandleFunc("POST /messages", sse.serveMessage)
+	}
+	if all || enabled[TransportWebSocket] {
+		mux.HandleFunc("GET /ws", s.serveWebSocket)
+	}
+	return mux
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	message, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	answer := s.Handle(r.Context(), message)
+	if answer == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(answer)
+}
+
+// sseSessions implements the HTTP+SSE transport: a client opens GET /sse,
+// learns where to post from the endpoint event and receives its answers as
+// message events
+type sseSessions struct {
+	server   *Server
+	mu       sync.Mutex
+	sessions map[string]chan []byte
+}
+
+func (ss *sseSessions) serveStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sessionID := uuid.New().String()
+	answers := make(chan []byte, 16)
+	ss.mu.Lock()
+	ss.sessions[sessionID] = answers
+	ss.mu.Unlock()
+	defer func() {
+		ss.mu.Lock()
+		delete(ss.sessions, sessionID)
+		ss.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprintf(w, "event: endpoint\ndata: /messages?session_id=%s\n\n", sessionID)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case answer := <-answers:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", answer)
+			flusher.Flush()
+		}
+	}
+}
+
+func (ss *sseSessions) serveMessage(w http.ResponseWriter, r *http.Request) {
+	ss.mu.Lock()
+	answers, ok := ss.sessions[r.URL.Query().Get("session_id")]
+	ss.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	message, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if answer := ss.server.Handle(r.Context(), message); answer != nil {
+		answers <- answer
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin:  func(r *http.Request) bool { return true },
+	Subprotocols: []string{"mcp"},
+}
+
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if answer := s.Handle(r.Context(), message); answer != nil {
+			if err := conn.WriteMessage(websocket.TextMessage, answer); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ServeStdio answers newline-delimited JSON-RPC messages read from in until
+// in is closed or ctx is done
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if answer := s.Handle(ctx, line); answer != nil {
+			if _, err := out.Write(append(answer, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
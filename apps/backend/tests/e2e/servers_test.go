@@ -0,0 +1,49 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/tests/e2e/refserver"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferenceServers_HealthChecked(t *testing.T) {
+	h := requireStack(t)
+
+	for _, transport := range []string{refserver.TransportHTTP, refserver.TransportSSE, refserver.TransportWebSocket} {
+		t.Run(transport, func(t *testing.T) {
+			t.Parallel()
+			// Health checks run on the discovery interval, 30s by default
+			ctx := scenarioContext(t, 2*time.Minute)
+
+			server, err := h.RegisterReferenceServer(ctx, transport, uniqueName("ref-"+transport))
+			require.NoError(t, err)
+			t.Cleanup(func() { h.DeleteServer(context.Background(), server.ID) })
+			assert.Equal(t, transport, server.Protocol)
+
+			check, err := h.WaitForHealthCheck(ctx, server.ID)
+			require.NoError(t, err)
+			assert.Equal(t, types.HealthStatusHealthy, check.Status, check.Error)
+		})
+	}
+}
+
+func TestStdioServer_DiscoverTools(t *testing.T) {
+	h := requireStack(t)
+	ctx := scenarioContext(t, time.Minute)
+
+	server, err := h.RegisterReferenceServer(ctx, refserver.TransportSTDIO, uniqueName("ref-stdio"))
+	require.NoError(t, err)
+	t.Cleanup(func() { h.DeleteServer(context.Background(), server.ID) })
+
+	require.NoError(t, h.DiscoverTools(ctx, server.ID))
+
+	found, err := h.Server(ctx, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.ServerStatusActive, found.Status)
+}
@@ -0,0 +1,194 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/a2a"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubCardFetcher serves a fixed card, or fails
+type stubCardFetcher struct {
+	err  error
+	data string
+	url  string
+}
+
+func (f *stubCardFetcher) FetchAgentCard(ctx context.Context, cardURL string) ([]byte, error) {
+	f.url = cardURL
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []byte(f.data), nil
+}
+
+const testAgentCard = `{
+	"name": "Research Agent",
+	"url": "https://agents.example.com/a2a",
+	"version": "1.2.0",
+	"capabilities": {"streaming": true},
+	"securitySchemes": {"bearer": {"type": "http", "scheme": "bearer"}},
+	"security": [{"bearer": []}],
+	"skills": [{"id": "search", "name": "Web search"}]
+}`
+
+func newCardTestAgent() *types.A2AAgent {
+	return &types.A2AAgent{
+		ID:          uuid.New(),
+		Name:        "research",
+		EndpointURL: "https://agents.example.com/a2a/rpc",
+		AgentType:   types.AgentTypeCustom,
+		AuthType:    types.AuthTypeBearer,
+	}
+}
+
+func TestA2AClient_FetchAgentCard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, a2a.AgentCardPath, r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"), "cards are fetched without credentials")
+		if r.URL.Query().Get("missing") != "" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testAgentCard))
+	}))
+	defer server.Close()
+
+	agent := &types.A2AAgent{
+		EndpointURL: server.URL + "/a2a/rpc",
+		AgentType:   types.AgentTypeGeneric,
+		AuthType:    types.AuthTypeBearer,
+		AuthValue:   "secret",
+	}
+	cardURL, err := a2a.AgentCardURL(agent)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+a2a.AgentCardPath, cardURL)
+
+	client := a2a.NewClient(5*time.Second, 0)
+	_, err = client.FetchAgentCard(context.Background(), cardURL)
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "internal hosts need allow-listing")
+
+	client.SetEgressPolicy(allowLoopback(t))
+	data, err := client.FetchAgentCard(context.Background(), cardURL)
+	require.NoError(t, err)
+	assert.JSONEq(t, testAgentCard, string(data))
+
+	_, err = client.FetchAgentCard(context.Background(), cardURL+"?missing=1")
+	assert.Error(t, err)
+
+	// The card URL can be configured for agents publishing it elsewhere
+	agent.ConfigData = map[string]interface{}{"agent_card_url": "https://cards.example.com/research.json"}
+	cardURL, err = a2a.AgentCardURL(agent)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cards.example.com/research.json", cardURL)
+
+	// Cards are not fetched from internal or, offline, external hosts
+	_, err = client.FetchAgentCard(context.Background(), "http://169.254.169.254/latest/meta-data")
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	offline, err := airgap.New(true, nil, nil)
+	require.NoError(t, err)
+	client.SetEgressPolicy(offline)
+	_, err = client.FetchAgentCard(context.Background(), cardURL)
+	assert.True(t, types.IsError(err, types.ErrCodeConnectivityRequired))
+}
+
+func TestA2AHasAgentCard(t *testing.T) {
+	assert.True(t, a2a.HasAgentCard(&types.A2AAgent{AgentType: types.AgentTypeCustom}))
+	assert.True(t, a2a.HasAgentCard(&types.A2AAgent{AgentType: types.AgentTypeGeneric}))
+	assert.False(t, a2a.HasAgentCard(&types.A2AAgent{AgentType: types.AgentTypeOpenAI}))
+}
+
+func TestCheckAgentCard(t *testing.T) {
+	agent := newCardTestAgent()
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	fetcher := &stubCardFetcher{data: testAgentCard}
+	record := a2a.CheckAgentCard(context.Background(), fetcher, agent, nil, now)
+	assert.Equal(t, "https://agents.example.com"+a2a.AgentCardPath, fetcher.url)
+	assert.Equal(t, types.A2AAgentCardStatusValid, record.Status)
+	assert.Equal(t, agent.ID, record.AgentID)
+	assert.Empty(t, record.Problems)
+	assert.Empty(t, record.Warnings)
+	require.NotNil(t, record.Card)
+	assert.Equal(t, "Research Agent", record.Card.Name)
+	assert.True(t, record.Card.Capabilities.Streaming)
+	require.Len(t, record.Card.Skills, 1)
+	assert.Equal(t, "search", record.Card.Skills[0].ID)
+	require.NotNil(t, record.FetchedAt)
+	assert.Equal(t, now, *record.FetchedAt)
+
+	// An unreachable agent keeps its last card, marked unavailable
+	later := now.Add(time.Hour)
+	unavailable := a2a.CheckAgentCard(context.Background(), &stubCardFetcher{err: errors.New("connection refused")}, agent, record, later)
+	assert.Equal(t, types.A2AAgentCardStatusUnavailable, unavailable.Status)
+	assert.Equal(t, "connection refused", unavailable.Error)
+	assert.Equal(t, record.Card, unavailable.Card)
+	assert.Equal(t, now, *unavailable.FetchedAt)
+	assert.Equal(t, later, unavailable.CheckedAt)
+
+	invalid := a2a.CheckAgentCard(context.Background(), &stubCardFetcher{data: "<html>"}, agent, record, later)
+	assert.Equal(t, types.A2AAgentCardStatusInvalid, invalid.Status)
+	assert.Nil(t, invalid.Card)
+	assert.NotEmpty(t, invalid.Problems)
+
+	incomplete := a2a.CheckAgentCard(context.Background(), &stubCardFetcher{
+		data: `{"name": "x", "url": "agents", "skills": [{"id": "a", "name": "A"}, {"id": "a", "name": "B"}],
+			"securitySchemes": {"key": {"type": "apiKey"}}, "security": [{"oauth": []}]}`,
+	}, agent, nil, later)
+	assert.Equal(t, types.A2AAgentCardStatusInvalid, incomplete.Status)
+	assert.Equal(t, []string{
+		"version is required",
+		`url "agents" is not an absolute http(s) URL`,
+		`skill id "a" is declared twice`,
+		"securitySchemes.key needs a name and in of header, query or cookie",
+		`security requires undeclared scheme "oauth"`,
+	}, incomplete.Problems)
+}
+
+func TestValidateAgentCard_Warnings(t *testing.T) {
+	card := &types.A2AAgentCard{
+		Name:    "Research Agent",
+		URL:     "https://elsewhere.example.com/a2a",
+		Version: "1.0.0",
+		SecuritySchemes: map[string]*types.A2ASecurityScheme{
+			"key": {Type: types.A2ASecuritySchemeAPIKey, Name: "X-API-Key", In: "header"},
+		},
+	}
+	agent := newCardTestAgent()
+	agent.CapabilitiesData = map[string]interface{}{types.CapabilityStreaming: true}
+
+	problems, warnings := a2a.ValidateAgentCard(card, agent)
+	assert.Empty(t, problems)
+	assert.Equal(t, []string{
+		"card url https://elsewhere.example.com/a2a is not on the registered endpoint's host agents.example.com",
+		"the agent card does not accept auth_type bearer, only api_key",
+		"the agent is registered with streaming, which its card does not declare",
+	}, warnings)
+
+	agent.AuthType = types.AuthTypeNone
+	_, warnings = a2a.ValidateAgentCard(card, agent)
+	assert.Contains(t, warnings, "the agent card requires authentication but the agent is registered without auth")
+
+	// An empty requirement makes authentication optional
+	card.Security = []map[string][]string{{"key": nil}, {}}
+	_, warnings = a2a.ValidateAgentCard(card, agent)
+	assert.NotContains(t, warnings, "the agent card requires authentication but the agent is registered without auth")
+
+	// Older cards list their schemes under authentication
+	card.Security, card.SecuritySchemes = nil, nil
+	card.Authentication = &types.A2AAgentAuthentication{Schemes: []string{"Bearer"}}
+	agent.AuthType = types.AuthTypeOAuth
+	_, warnings = a2a.ValidateAgentCard(card, agent)
+	assert.Len(t, warnings, 2, "only the host and streaming warnings remain")
+}
@@ -0,0 +1,302 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAccessGrants keeps role grants and expiring namespace grants in
+// memory, mirroring AccessGrantModel
+type memoryAccessGrants struct {
+	roles      []*types.RoleGrant
+	namespaces []*types.NamespaceGrant
+	reminded   map[string]bool
+}
+
+func newMemoryAccessGrants() *memoryAccessGrants {
+	return &memoryAccessGrants{reminded: make(map[string]bool)}
+}
+
+func (m *memoryAccessGrants) ListRoleGrants(orgID string, filter *types.RoleGrantFilter, now time.Time) ([]*types.RoleGrant, error) {
+	grants := []*types.RoleGrant{}
+	for _, grant := range m.roles {
+		if grant.OrganizationID != orgID || (filter.UserID != "" && grant.UserID != filter.UserID) {
+			continue
+		}
+		if filter.IncludeInactive || grant.Active(now) {
+			grants = append(grants, grant)
+		}
+	}
+	return grants, nil
+}
+
+func (m *memoryAccessGrants) CreateRoleGrant(grant *types.RoleGrant) error {
+	grant.ID = uuid.New().String()
+	grant.CreatedAt = time.Now()
+	m.roles = append(m.roles, grant)
+	return nil
+}
+
+func (m *memoryAccessGrants) RevokeRoleGrant(orgID, id, revokedBy string) (bool, error) {
+	for _, grant := range m.roles {
+		if grant.ID == id && grant.OrganizationID == orgID && grant.Active(time.Now()) {
+			now := time.Now()
+			grant.RevokedAt = &now
+			grant.RevokedBy = revokedBy
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryAccessGrants) ActiveRoles(userID string, now time.Time) ([]string, error) {
+	roles := []string{}
+	for _, grant := range m.roles {
+		if grant.UserID == userID && grant.Active(now) {
+			roles = append(roles, grant.Role)
+		}
+	}
+	return roles, nil
+}
+
+func (m *memoryAccessGrants) UserOrganization(userID string) (string, error) {
+	return (&memoryNamespaceGrants{}).UserOrganization(userID)
+}
+
+func (m *memoryAccessGrants) ListDueReminders(before time.Time) ([]*types.ExpiringGrant, error) {
+	return m.expiring(func(expiresAt time.Time, id string) bool {
+		return !m.reminded[id] && expiresAt.Before(before)
+	}), nil
+}
+
+func (m *memoryAccessGrants) ListDueExpiry(now time.Time) ([]*types.ExpiringGrant, error) {
+	return m.expiring(func(expiresAt time.Time, id string) bool {
+		return !expiresAt.After(now)
+	}), nil
+}
+
+func (m *memoryAccessGrants) MarkReminded(kind, id string, at time.Time) error {
+	m.reminded[id] = true
+	return nil
+}
+
+func (m *memoryAccessGrants) MarkExpired(kind, id string, at time.Time) error {
+	for _, grant := range m.roles {
+		if grant.ID == id {
+			grant.ExpiredAt = &at
+		}
+	}
+	for _, grant := range m.namespaces {
+		if grant.ID == id {
+			grant.ExpiredAt = &at
+		}
+	}
+	return nil
+}
+
+func (m *memoryAccessGrants) expiring(due func(expiresAt time.Time, id string) bool) []*types.ExpiringGrant {
+	grants := []*types.ExpiringGrant{}
+	for _, grant := range m.roles {
+		if grant.RevokedAt == nil && grant.ExpiredAt == nil && due(grant.ExpiresAt, grant.ID) {
+			grants = append(grants, &types.ExpiringGrant{
+				Kind: types.AccessGrantKindRole, ID: grant.ID, OrganizationID: grant.OrganizationID,
+				UserID: grant.UserID, SubjectType: types.NamespaceGrantSubjectUser, SubjectID: grant.UserID,
+				Role: grant.Role, ExpiresAt: grant.ExpiresAt,
+			})
+		}
+	}
+	for _, grant := range m.namespaces {
+		if grant.ExpiresAt != nil && grant.ExpiredAt == nil && due(*grant.ExpiresAt, grant.ID) {
+			expiring := &types.ExpiringGrant{
+				Kind: types.AccessGrantKindNamespace, ID: grant.ID, OrganizationID: grant.OrganizationID,
+				SubjectType: grant.SubjectType, SubjectID: grant.SubjectID, NamespaceID: grant.NamespaceID,
+				NamespaceName: "restricted", AccessLevel: grant.AccessLevel, ExpiresAt: *grant.ExpiresAt,
+			}
+			if grant.SubjectType == types.NamespaceGrantSubjectUser {
+				expiring.UserID = grant.SubjectID
+			}
+			grants = append(grants, expiring)
+		}
+	}
+	return grants
+}
+
+// recordingGrantNotifier records admin and personal notifications
+type recordingGrantNotifier struct {
+	recordingAdminNotifier
+	personal map[string][]*types.NotificationEvent
+}
+
+func (r *recordingGrantNotifier) NotifyUser(ctx context.Context, userID string, event *types.NotificationEvent) (bool, error) {
+	if r.personal == nil {
+		r.personal = make(map[string][]*types.NotificationEvent)
+	}
+	r.personal[userID] = append(r.personal[userID], event)
+	return true, nil
+}
+
+type recordingGrantAuditor struct {
+	logs []*types.AuditLog
+}
+
+func (r *recordingGrantAuditor) LogAudit(audit *types.AuditLog) error {
+	r.logs = append(r.logs, audit)
+	return nil
+}
+
+func TestRoleGrantExpiryValidation(t *testing.T) {
+	svc := services.NewAccessGrantServiceWithStore(newMemoryAccessGrants(), 0)
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+	tooLate := time.Now().AddDate(2, 0, 0)
+	soon := time.Now().Add(time.Hour)
+
+	cases := []*types.CreateRoleGrantRequest{
+		{UserID: grantedUserID, Role: types.RoleAdmin},
+		{UserID: grantedUserID, Role: types.RoleAdmin, ExpiresAt: &past},
+		{UserID: grantedUserID, Role: types.RoleAdmin, ExpiresAt: &tooLate},
+		{UserID: grantedUserID, Role: types.RoleAdmin, ExpiresAt: &soon, DurationDays: 30},
+		{UserID: foreignUserID, Role: types.RoleAdmin, DurationDays: 30},
+	}
+	for _, req := range cases {
+		_, err := svc.GrantRole(ctx, grantOrgID, otherUserID, req)
+		assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "%+v", req)
+	}
+
+	grant, err := svc.GrantRole(ctx, grantOrgID, otherUserID, &types.CreateRoleGrantRequest{
+		UserID: grantedUserID, Role: types.RoleAdmin, DurationDays: 30, Reason: "contractor",
+	})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 30), grant.ExpiresAt, time.Minute)
+	assert.Equal(t, otherUserID, grant.GrantedBy)
+}
+
+func TestRoleGrantElevatesUntilRevoked(t *testing.T) {
+	svc := services.NewAccessGrantServiceWithStore(newMemoryAccessGrants(), 0)
+	ctx := context.Background()
+
+	user := &types.User{ID: grantedUserID, OrganizationID: grantOrgID, Role: types.RoleViewer, IsActive: true}
+	m := auth.NewMiddlewareWithInterface(nil, &teamAuthService{user: user})
+	m.SetRoleGrants(svc)
+
+	role := func() string {
+		var role string
+		router := gin.New()
+		router.GET("/", m.RequireAPIKey(), func(c *gin.Context) {
+			role = c.GetString("role")
+			c.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "key")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		return role
+	}
+
+	assert.Equal(t, types.RoleViewer, role())
+
+	grant, err := svc.GrantRole(ctx, grantOrgID, otherUserID, &types.CreateRoleGrantRequest{
+		UserID: grantedUserID, Role: types.RoleUser, DurationDays: 30,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.RoleUser, role())
+
+	require.NoError(t, svc.RevokeRole(ctx, grantOrgID, grant.ID, otherUserID))
+	assert.Equal(t, types.RoleViewer, role())
+	err = svc.RevokeRole(ctx, grantOrgID, grant.ID, otherUserID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "revoked grants cannot be revoked again")
+
+	listed, err := svc.ListRoleGrants(ctx, grantOrgID, &types.RoleGrantFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+	listed, err = svc.ListRoleGrants(ctx, grantOrgID, &types.RoleGrantFilter{IncludeInactive: true})
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+}
+
+func TestAccessGrantProcessExpiry(t *testing.T) {
+	store := newMemoryAccessGrants()
+	notifier := &recordingGrantNotifier{}
+	auditor := &recordingGrantAuditor{}
+	svc := services.NewAccessGrantServiceWithStore(store, 24*time.Hour)
+	svc.SetNotifier(notifier)
+	svc.SetAuditor(auditor)
+	ctx := context.Background()
+
+	lapsed := time.Now().Add(-time.Minute)
+	ending := time.Now().Add(time.Hour)
+	store.roles = append(store.roles, &types.RoleGrant{
+		ID: uuid.New().String(), OrganizationID: grantOrgID, UserID: grantedUserID,
+		Role: types.RoleAdmin, ExpiresAt: lapsed,
+	})
+	store.namespaces = append(store.namespaces,
+		&types.NamespaceGrant{
+			ID: uuid.New().String(), OrganizationID: grantOrgID, NamespaceID: restrictedNSID,
+			SubjectType: types.NamespaceGrantSubjectUser, SubjectID: otherUserID,
+			AccessLevel: types.NamespaceAccessRead, ExpiresAt: &ending,
+		},
+		&types.NamespaceGrant{
+			ID: uuid.New().String(), OrganizationID: grantOrgID, NamespaceID: restrictedNSID,
+			SubjectType: types.NamespaceGrantSubjectRole, SubjectID: types.RoleViewer,
+			AccessLevel: types.NamespaceAccessRead, ExpiresAt: &ending,
+		})
+
+	expired, reminded, err := svc.ProcessExpiry(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	assert.Equal(t, 2, reminded)
+
+	require.Len(t, auditor.logs, 1)
+	assert.Equal(t, "expire", auditor.logs[0].Action)
+	assert.Equal(t, "role_grant", auditor.logs[0].Resource)
+	assert.Equal(t, grantedUserID, auditor.logs[0].UserID)
+
+	require.Len(t, notifier.events, 2, "admins are reminded of every expiring grant")
+	assert.Equal(t, types.NotificationGrantExpiring, notifier.events[0].Type)
+	assert.Len(t, notifier.personal[otherUserID], 1, "user grantees are reminded personally")
+	assert.Len(t, notifier.personal, 1, "role subjects have no single grantee")
+
+	expired, reminded, err = svc.ProcessExpiry(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+	assert.Zero(t, reminded, "reminders are sent once")
+}
+
+func TestExpiredNamespaceGrantKeepsNamespaceRestricted(t *testing.T) {
+	store := &memoryNamespaceGrants{}
+	access := services.NewNamespaceAccessServiceWithStore(store, grantCacheWindow)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Hour)
+	_, err := access.Grant(ctx, grantOrgID, restrictedNSID, otherUserID, &types.CreateNamespaceGrantRequest{
+		SubjectType: types.NamespaceGrantSubjectUser, SubjectID: grantedUserID,
+		AccessLevel: types.NamespaceAccessRead, ExpiresAt: &past,
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	_, err = access.Grant(ctx, grantOrgID, restrictedNSID, otherUserID, &types.CreateNamespaceGrantRequest{
+		SubjectType: types.NamespaceGrantSubjectUser, SubjectID: grantedUserID,
+		AccessLevel: types.NamespaceAccessRead,
+	})
+	require.NoError(t, err)
+	store.grants[0].ExpiresAt = &past
+	access = services.NewNamespaceAccessServiceWithStore(store, grantCacheWindow)
+
+	allowed, err := access.Allowed(ctx, grantSubject(grantedUserID, types.RoleUser), restrictedNSID, types.NamespaceAccessRead)
+	require.NoError(t, err)
+	assert.False(t, allowed, "expired grants no longer admit their grantee")
+	allowed, err = access.Allowed(ctx, grantSubject(otherUserID, types.RoleUser), restrictedNSID, types.NamespaceAccessRead)
+	require.NoError(t, err)
+	assert.False(t, allowed, "expired grants still restrict the namespace")
+}
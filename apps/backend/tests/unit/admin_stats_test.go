@@ -0,0 +1,138 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAdminStats serves fixed aggregates, recording the windows asked for
+type memoryAdminStats struct {
+	refreshes  map[string]time.Time
+	failing    map[string]bool
+	tools      []*types.ToolUsageStat
+	servers    []*types.ServerErrorStat
+	since      time.Time
+	activeUser int64
+}
+
+func (m *memoryAdminStats) RefreshView(view string, refreshedAt time.Time) error {
+	if m.failing[view] {
+		return errors.New("refresh failed")
+	}
+	m.refreshes[view] = refreshedAt
+	return nil
+}
+
+func (m *memoryAdminStats) ListRefreshes() (map[string]time.Time, error) {
+	return m.refreshes, nil
+}
+
+func (m *memoryAdminStats) TopTools(orgID string, since time.Time, limit int) ([]*types.ToolUsageStat, error) {
+	m.since = since
+	return m.tools, nil
+}
+
+func (m *memoryAdminStats) ServerRequests(orgID string, since time.Time) ([]*types.ServerErrorStat, error) {
+	return m.servers, nil
+}
+
+func (m *memoryAdminStats) CountActiveUsers(orgID string, since time.Time) (int64, error) {
+	return m.activeUser, nil
+}
+
+func (m *memoryAdminStats) CountUsers(orgID string) (int64, error) {
+	return 12, nil
+}
+
+func (m *memoryAdminStats) CountServers(orgID string) (*types.ServerCounts, error) {
+	return &types.ServerCounts{Total: 3, Healthy: 2}, nil
+}
+
+func TestAdminStatsService(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.March, 10, 15, 30, 0, 0, time.UTC)
+
+	newStore := func() *memoryAdminStats {
+		return &memoryAdminStats{
+			refreshes: map[string]time.Time{
+				types.StatsViewToolUsage:      now.Add(-2 * time.Minute),
+				types.StatsViewServerRequests: now.Add(-time.Hour),
+			},
+			failing:    map[string]bool{},
+			activeUser: 5,
+			tools:      []*types.ToolUsageStat{{Tool: "search", Calls: 40, Errors: 2, ErrorRate: 0.05}},
+			servers: []*types.ServerErrorStat{
+				{ServerID: "server-a", Requests: 100, Errors: 5, ErrorRate: 0.05},
+				{ServerID: "", Requests: 20, Errors: 10, ErrorRate: 0.5},
+				{ServerID: "server-b", Requests: 10, Errors: 5, ErrorRate: 0.5},
+			},
+		}
+	}
+
+	t.Run("summarizes the window with freshness", func(t *testing.T) {
+		store := newStore()
+		service := services.NewAdminStatsServiceWithStore(store, 15*time.Minute)
+		service.SetClock(func() time.Time { return now })
+
+		stats, err := service.GetStats(ctx, "org-1", 7)
+		require.NoError(t, err)
+
+		assert.Equal(t, time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC), stats.Since)
+		assert.Equal(t, stats.Since, store.since)
+		assert.Equal(t, types.UserStats{Total: 12, Active: 5}, stats.Users)
+		assert.Equal(t, types.ServerCounts{Total: 3, Healthy: 2}, stats.Servers)
+		assert.Equal(t, types.RequestTotals{Total: 130, Successful: 110, Failed: 20}, stats.Requests)
+		assert.Len(t, stats.TopTools, 1)
+
+		// Unrouted requests count towards the totals but are not a server
+		require.Len(t, stats.ServerErrorRates, 2)
+		assert.Equal(t, "server-b", stats.ServerErrorRates[0].ServerID)
+		assert.Equal(t, "server-a", stats.ServerErrorRates[1].ServerID)
+
+		require.Len(t, stats.Freshness, 3)
+		assert.False(t, stats.Freshness[0].Stale)
+		assert.Equal(t, int64(120), stats.Freshness[0].AgeSeconds)
+		assert.True(t, stats.Freshness[1].Stale)
+		assert.True(t, stats.Freshness[2].Stale)
+		assert.Nil(t, stats.Freshness[2].RefreshedAt)
+		assert.True(t, stats.Stale)
+	})
+
+	t.Run("refresh makes every view fresh", func(t *testing.T) {
+		store := newStore()
+		service := services.NewAdminStatsServiceWithStore(store, 15*time.Minute)
+		service.SetClock(func() time.Time { return now })
+
+		require.NoError(t, service.Refresh(ctx))
+		stats, err := service.GetStats(ctx, "org-1", 0)
+		require.NoError(t, err)
+		assert.Equal(t, 30, stats.Days)
+		assert.False(t, stats.Stale)
+	})
+
+	t.Run("a failing view does not block the others", func(t *testing.T) {
+		store := newStore()
+		store.failing[types.StatsViewToolUsage] = true
+		service := services.NewAdminStatsServiceWithStore(store, 15*time.Minute)
+		service.SetClock(func() time.Time { return now })
+
+		err := service.Refresh(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), types.StatsViewToolUsage)
+		assert.Equal(t, now, store.refreshes[types.StatsViewActiveUsers])
+	})
+
+	t.Run("rejects windows beyond the views", func(t *testing.T) {
+		service := services.NewAdminStatsServiceWithStore(newStore(), 0)
+		_, err := service.GetStats(ctx, "org-1", 91)
+		assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	})
+}
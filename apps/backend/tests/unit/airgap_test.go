@@ -0,0 +1,172 @@
+package unit
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/discovery"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAirgapOnlineAllowsEverything(t *testing.T) {
+	policy, err := airgap.New(false, nil, nil)
+	require.NoError(t, err)
+
+	resolved, err := policy.ResolveURL(types.ConnectivityFeaturePackageDiscovery, "https://registry.example.com/search")
+	require.NoError(t, err)
+	assert.Equal(t, "https://registry.example.com/search", resolved)
+	assert.NoError(t, policy.CheckURL(types.ConnectivityFeatureAIModeration, "https://api.openai.com/v1/moderations"))
+
+	var nilPolicy *airgap.Policy
+	assert.False(t, nilPolicy.Offline())
+	assert.NoError(t, nilPolicy.CheckURL(types.ConnectivityFeatureAIModeration, "https://api.openai.com"))
+}
+
+func TestAirgapOfflineResolvesMirrors(t *testing.T) {
+	policy, err := airgap.New(true, map[string]string{
+		types.ConnectivityFeaturePackageDiscovery: "http://mirror.corp.example/search",
+	}, nil)
+	require.NoError(t, err)
+
+	resolved, err := policy.ResolveURL(types.ConnectivityFeaturePackageDiscovery, "https://registry.example.com/search")
+	require.NoError(t, err)
+	assert.Equal(t, "http://mirror.corp.example/search", resolved)
+
+	unmirrored, err := airgap.New(true, nil, nil)
+	require.NoError(t, err)
+	_, err = unmirrored.ResolveURL(types.ConnectivityFeaturePackageDiscovery, "https://registry.example.com/search")
+	var apiErr *types.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, types.ErrCodeConnectivityRequired, apiErr.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.Status)
+	assert.Contains(t, apiErr.Message, "MCP package search")
+	assert.Contains(t, apiErr.Details, "gateway.offline.mirrors."+types.ConnectivityFeaturePackageDiscovery)
+
+	_, err = airgap.New(true, map[string]string{"telemetry_unknown": "http://x.local"}, nil)
+	require.Error(t, err)
+	_, err = airgap.New(true, map[string]string{types.ConnectivityFeatureAIModeration: "http://x.local"}, nil)
+	require.Error(t, err)
+	_, err = airgap.New(true, map[string]string{types.ConnectivityFeaturePackageDiscovery: "not a url"}, nil)
+	require.Error(t, err)
+}
+
+func TestAirgapOfflineCheckURL(t *testing.T) {
+	policy, err := airgap.New(true, nil, []string{"moderation.example.com", "*.corp.example"})
+	require.NoError(t, err)
+
+	for _, allowed := range []string{
+		"http://localhost:8000/v1",
+		"http://10.0.0.5/v1",
+		"http://llamaguard:8080",
+		"http://guard.default.svc",
+		"https://moderation.example.com/v1",
+		"https://ai.corp.example/v1",
+	} {
+		assert.NoError(t, policy.CheckURL(types.ConnectivityFeatureAIModeration, allowed), allowed)
+	}
+
+	err = policy.CheckURL(types.ConnectivityFeatureAIModeration, "https://api.openai.com/v1/moderations")
+	var apiErr *types.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, types.ErrCodeConnectivityRequired, apiErr.Code)
+}
+
+func TestAirgapIsLocalHost(t *testing.T) {
+	for _, host := range []string{"localhost", "127.0.0.1", "::1", "192.168.1.10", "169.254.1.1", "db", "api.internal", "printer.local"} {
+		assert.True(t, airgap.IsLocalHost(host), host)
+	}
+	for _, host := range []string{"8.8.8.8", "example.com", "api.openai.com"} {
+		assert.False(t, airgap.IsLocalHost(host), host)
+	}
+}
+
+func TestAirgapPublicAddresses(t *testing.T) {
+	for _, ip := range []string{"8.8.8.8", "2606:4700::1111", "100.128.0.1"} {
+		assert.True(t, airgap.IsPublicIP(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "0.1.2.3", "::1", "fd00:ec2::254", "fe80::1", "::ffff:127.0.0.1", "224.0.0.1"} {
+		assert.False(t, airgap.IsPublicIP(net.ParseIP(ip)), ip)
+	}
+
+	policy, err := airgap.New(false, nil, []string{"minio.internal", "10.0.0.9"})
+	require.NoError(t, err)
+	for _, allowed := range []string{"https://hooks.example.com/x", "http://minio.internal:9000", "http://10.0.0.9/"} {
+		assert.NoError(t, policy.CheckPublicURL(allowed), allowed)
+	}
+	for _, refused := range []string{"http://169.254.169.254/", "http://localhost:8080", "http://db.internal",
+		"http://10.0.0.8/", "http://[::1]/", "ftp://files.example.com", "not a url"} {
+		assert.Error(t, policy.CheckPublicURL(refused), refused)
+	}
+}
+
+func TestAirgapPublicClientRefusesInternalAddresses(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits++ }))
+	defer server.Close()
+
+	var online *airgap.Policy
+	_, err := online.PublicClient(types.ConnectivityFeatureWebhooks, time.Second).Get(server.URL)
+	assert.ErrorIs(t, err, airgap.ErrNonPublicAddress)
+	assert.Zero(t, hits, "the connection is refused after resolution")
+
+	policy, err := airgap.New(false, nil, []string{"127.0.0.1"})
+	require.NoError(t, err)
+	resp, err := policy.PublicClient(types.ConnectivityFeatureWebhooks, time.Second).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 1, hits, "allow-listed hosts are reachable")
+}
+
+func TestUnavailableMCPDiscoveryReturnsConnectivityError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy, err := airgap.New(true, nil, nil)
+	require.NoError(t, err)
+	_, resolveErr := policy.ResolveURL(types.ConnectivityFeaturePackageDiscovery, "https://registry.example.com")
+	require.Error(t, resolveErr)
+
+	handler := handlers.NewMCPDiscoveryHandler(discovery.NewUnavailableMCPDiscoveryService(resolveErr))
+	router := gin.New()
+	router.GET("/api/mcp/search", handler.SearchPackages)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/mcp/search?query=files", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), types.ErrCodeConnectivityRequired)
+}
+
+func TestOfflineHandlerListsFeatureAvailability(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy, err := airgap.New(true, nil, []string{"moderation.example.com"})
+	require.NoError(t, err)
+	router := gin.New()
+	router.GET("/api/admin/offline", handlers.NewOfflineHandler(policy).GetStatus)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/offline", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data types.OfflineStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, body.Data.Offline)
+	assert.Equal(t, []string{"moderation.example.com"}, body.Data.AllowedHosts)
+	require.Len(t, body.Data.Features, len(types.ConnectivityFeatures))
+	for _, feature := range body.Data.Features {
+		if feature.Key == types.ConnectivityFeaturePackageDiscovery {
+			assert.False(t, feature.Available)
+			assert.NotEmpty(t, feature.Reason)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restrictedKeyAuthService validates every key as a restricted key of an
+// active user
+type restrictedKeyAuthService struct {
+	key *types.APIKey
+}
+
+func (s *restrictedKeyAuthService) GetUserByID(userID string) (*types.User, error) {
+	return &types.User{ID: userID, OrganizationID: grantOrgID, Role: types.RoleUser, IsActive: true}, nil
+}
+
+func (s *restrictedKeyAuthService) ValidateAPIKey(apiKey string) (*types.APIKey, error) {
+	return s.key, nil
+}
+
+func TestNormalizeAPIKeyRestrictions(t *testing.T) {
+	cidrs, err := types.NormalizeAPIKeyCIDRs([]string{"10.1.2.3", "192.168.1.77/24", "2001:db8::1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.1.2.3/32", "192.168.1.0/24", "2001:db8::1/128"}, cidrs)
+	_, err = types.NormalizeAPIKeyCIDRs([]string{"10.0.0.0/33"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	origins, err := types.NormalizeAPIKeyOrigins([]string{"https://App.Example.com/", "https://*.example.com", "http://localhost:3000"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://app.example.com", "https://*.example.com", "http://localhost:3000"}, origins)
+	for _, invalid := range []string{"app.example.com", "ftp://example.com", "https://example.com/path", "https://ex*.com", "https://*.*.example.com", "https://user@example.com"} {
+		_, err = types.NormalizeAPIKeyOrigins([]string{invalid})
+		assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), invalid)
+	}
+}
+
+func TestAPIKeyCheckClient(t *testing.T) {
+	key := &types.APIKey{AllowedCIDRs: []string{"10.0.0.0/8"}}
+	assert.Nil(t, key.CheckClient("10.20.30.40", "", ""))
+	assert.NotNil(t, key.CheckClient("192.168.0.1", "", ""))
+	assert.NotNil(t, key.CheckClient("not-an-ip", "", ""))
+
+	key = &types.APIKey{AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"}}
+	assert.Nil(t, key.CheckClient("1.2.3.4", "https://app.example.com", ""))
+	assert.Nil(t, key.CheckClient("1.2.3.4", "", "https://app.example.com/dashboard?tab=1"), "the referer's origin is used without an Origin header")
+	assert.Nil(t, key.CheckClient("1.2.3.4", "https://eu.example.org", ""))
+	assert.NotNil(t, key.CheckClient("1.2.3.4", "https://example.org", ""), "wildcards only match subdomains")
+	assert.NotNil(t, key.CheckClient("1.2.3.4", "https://evil-example.org", ""))
+	assert.NotNil(t, key.CheckClient("1.2.3.4", "http://app.example.com", ""))
+	assert.NotNil(t, key.CheckClient("1.2.3.4", "", ""), "origin-restricted keys need an origin")
+
+	assert.Nil(t, (&types.APIKey{}).CheckClient("1.2.3.4", "", ""), "unrestricted keys work anywhere")
+}
+
+func TestRequireAPIKeyEnforcesRestrictions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key := &types.APIKey{ID: "key-1", UserID: grantedUserID, AllowedCIDRs: []string{"10.0.0.0/8"}, AllowedOrigins: []string{"https://app.example.com"}}
+	m := auth.NewMiddlewareWithInterface(nil, &restrictedKeyAuthService{key: key})
+
+	router := gin.New()
+	router.GET("/", m.RequireAPIKey(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	call := func(remoteAddr, origin string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-API-Key", "key")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, call("10.1.1.1:5000", "https://app.example.com"))
+	assert.Equal(t, http.StatusForbidden, call("203.0.113.9:5000", "https://app.example.com"))
+	assert.Equal(t, http.StatusForbidden, call("10.1.1.1:5000", "https://attacker.example"))
+}
+
+func TestEndpointAuthEnforcesAPIKeyRestrictions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key := &types.APIKey{ID: "key-1", UserID: grantedUserID, AllowedCIDRs: []string{"10.0.0.0/8"}}
+	endpoint := &types.Endpoint{Name: "secure", IsActive: true, EnableAPIKeyAuth: true}
+
+	router := gin.New()
+	router.POST("/mcp", func(c *gin.Context) {
+		c.Set("endpoint", endpoint)
+		c.Next()
+	}, middleware.EndpointAuthMiddleware(nil, &restrictedKeyAuthService{key: key}, nil, "http://gateway/"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	call := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-API-Key", "key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, call("10.9.9.9:4000"))
+	assert.Equal(t, http.StatusForbidden, call("198.51.100.4:4000"))
+}
@@ -0,0 +1,178 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	scopeNamespaceID = "6f1c2a9e-0b7d-4e53-9a21-5d8e3c4b7f10"
+	scopeGitHubID    = "1b9e7c52-3f4a-4d6e-8a0b-c2d5e7f91a34"
+	scopeJiraID      = "9d2f4b61-7a8c-4e3d-b5f0-1c6e8a2d4b57"
+)
+
+// scopeServers maps tool prefixes to the servers of scopeNamespaceID
+type scopeServers map[string]string
+
+func (s scopeServers) ToolServerID(ctx context.Context, namespaceID, tool string) (string, error) {
+	prefix, _, _ := strings.Cut(tool, "__")
+	return s[prefix], nil
+}
+
+func TestNormalizeAPIKeyScope(t *testing.T) {
+	scope, err := types.NormalizeAPIKeyScope(
+		[]string{" " + strings.ToUpper(scopeNamespaceID) + " ", scopeNamespaceID},
+		[]string{scopeGitHubID},
+		[]string{"github__*", "*__read_?", "github__*"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{scopeNamespaceID}, scope.Namespaces, "IDs are normalized and deduplicated")
+	assert.Equal(t, []string{scopeGitHubID}, scope.Servers)
+	assert.Equal(t, []string{"github__*", "*__read_?"}, scope.Tools)
+
+	_, err = types.NormalizeAPIKeyScope([]string{"support"}, nil, nil)
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	_, err = types.NormalizeAPIKeyScope(nil, []string{"not-a-uuid"}, nil)
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	for _, invalid := range []string{"", "  ", "github__[", strings.Repeat("a", 256)} {
+		_, err = types.NormalizeAPIKeyScope(nil, nil, []string{invalid})
+		assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), invalid)
+	}
+
+	assert.Nil(t, (&types.APIKey{AllowedCIDRs: []string{"10.0.0.0/8"}}).Scope(), "network restrictions alone leave a key unscoped")
+	assert.NotNil(t, (&types.APIKey{AllowedTools: []string{"*"}}).Scope())
+}
+
+func TestAPIKeyScopeChecks(t *testing.T) {
+	scope := &types.APIKeyScope{
+		Namespaces: []string{scopeNamespaceID},
+		Servers:    []string{scopeGitHubID},
+		Tools:      []string{"github__list_*", "get_issue"},
+	}
+
+	assert.Nil(t, scope.CheckNamespace(scopeNamespaceID))
+	apiErr := scope.CheckNamespace(grantOrgID)
+	require.NotNil(t, apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.Status)
+
+	assert.Nil(t, scope.CheckTool(scopeGitHubID, "github__list_issues"))
+	assert.Nil(t, scope.CheckTool(scopeGitHubID, "github__get_issue"), "patterns also match the unprefixed tool name")
+	assert.NotNil(t, scope.CheckTool(scopeGitHubID, "github__delete_repo"))
+	apiErr = scope.CheckTool(scopeJiraID, "jira__list_issues")
+	require.NotNil(t, apiErr)
+	assert.Contains(t, apiErr.Message, "server of tool jira__list_issues")
+	assert.NotNil(t, scope.CheckTool("", "unknown__list_issues"), "tools of unknown servers are refused by server-scoped keys")
+
+	serversOnly := &types.APIKeyScope{Servers: []string{scopeGitHubID}}
+	assert.Nil(t, serversOnly.CheckTool(scopeGitHubID, "github__delete_repo"))
+	assert.Nil(t, serversOnly.CheckNamespace(grantOrgID))
+
+	var unscoped *types.APIKeyScope
+	assert.Nil(t, unscoped.CheckNamespace(scopeNamespaceID))
+	assert.Nil(t, unscoped.CheckTool(scopeJiraID, "jira__anything"))
+}
+
+func TestEndpointAPIKeyScopeMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resolver := scopeServers{"github": scopeGitHubID, "jira": scopeJiraID}
+	scoped := &types.Principal{
+		Type:           types.PrincipalTypeAPIKey,
+		UserID:         grantedUserID,
+		APIKeyID:       "key-1",
+		OrganizationID: grantOrgID,
+		Scope: &types.APIKeyScope{
+			Namespaces: []string{scopeNamespaceID},
+			Servers:    []string{scopeGitHubID},
+			Tools:      []string{"github__list_*"},
+		},
+	}
+
+	var received string
+	call := func(principal *types.Principal, namespaceID, method, path, body string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("endpoint", &types.Endpoint{Name: "dev", NamespaceID: namespaceID})
+			if principal != nil {
+				c.Set("principal", principal)
+			}
+			c.Next()
+		}, middleware.EndpointAPIKeyScopeMiddleware(resolver))
+		handler := func(c *gin.Context) {
+			raw, _ := io.ReadAll(c.Request.Body)
+			received = string(raw)
+			c.Status(http.StatusOK)
+		}
+		router.POST("/mcp", handler)
+		router.POST("/api/tools/:tool_name", handler)
+		router.GET("/sse", handler)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+	toolCall := func(name string) string {
+		return `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"` + name + `","arguments":{}}}`
+	}
+
+	w := call(scoped, scopeNamespaceID, http.MethodPost, "/mcp", toolCall("github__list_issues"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, toolCall("github__list_issues"), received, "the handler still reads the whole body")
+
+	w = call(scoped, grantOrgID, http.MethodGet, "/sse", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var resp types.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, types.ErrCodeAccessDenied, resp.Error.Code)
+	assert.Equal(t, "API key is not allowed to use namespace "+grantOrgID, resp.Error.Message)
+
+	assert.Equal(t, http.StatusForbidden, call(scoped, scopeNamespaceID, http.MethodPost, "/mcp", toolCall("jira__list_issues")).Code)
+	assert.Equal(t, http.StatusForbidden, call(scoped, scopeNamespaceID, http.MethodPost, "/mcp", toolCall("github__delete_repo")).Code)
+	batch := "[" + toolCall("github__list_issues") + "," + toolCall("github__delete_repo") + "]"
+	assert.Equal(t, http.StatusForbidden, call(scoped, scopeNamespaceID, http.MethodPost, "/mcp", batch).Code, "every call of a batch is checked")
+	assert.Equal(t, http.StatusOK, call(scoped, scopeNamespaceID, http.MethodPost, "/mcp", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`).Code)
+
+	assert.Equal(t, http.StatusOK, call(scoped, scopeNamespaceID, http.MethodPost, "/api/tools/github__list_pulls", "{}").Code)
+	assert.Equal(t, http.StatusForbidden, call(scoped, scopeNamespaceID, http.MethodPost, "/api/tools/github__merge_pull", "{}").Code)
+
+	unscoped := &types.Principal{Type: types.PrincipalTypeAPIKey, UserID: grantedUserID, OrganizationID: grantOrgID}
+	assert.Equal(t, http.StatusOK, call(unscoped, grantOrgID, http.MethodPost, "/mcp", toolCall("jira__delete_project")).Code)
+	assert.Equal(t, http.StatusOK, call(nil, grantOrgID, http.MethodPost, "/mcp", toolCall("jira__delete_project")).Code, "anonymous traffic follows the endpoint's auth settings")
+}
+
+func TestEndpointAuthCarriesAPIKeyScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key := &types.APIKey{ID: "key-1", UserID: grantedUserID, AllowedNamespaces: []string{scopeNamespaceID}}
+	endpoint := &types.Endpoint{Name: "scoped", NamespaceID: grantOrgID, IsActive: true, EnableAPIKeyAuth: true}
+
+	router := gin.New()
+	router.POST("/mcp", func(c *gin.Context) {
+		c.Set("endpoint", endpoint)
+		c.Next()
+	}, middleware.EndpointAuthMiddleware(nil, &restrictedKeyAuthService{key: key}, nil, "http://gateway/"),
+		middleware.EndpointAPIKeyScopeMiddleware(scopeServers{}),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	call := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.Header.Set("X-API-Key", "key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, call())
+	endpoint.NamespaceID = scopeNamespaceID
+	assert.Equal(t, http.StatusOK, call())
+}
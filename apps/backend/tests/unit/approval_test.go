@@ -0,0 +1,291 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/toolpolicy"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryApprovals keeps approvals in memory; held calls wait on another
+// goroutine, so access is locked
+type memoryApprovals struct {
+	approvals map[string]*types.ToolApproval
+	mu        sync.Mutex
+}
+
+func (m *memoryApprovals) Create(approval *types.ToolApproval) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	approval.ID = fmt.Sprintf("approval-%d", len(m.approvals)+1)
+	stored := *approval
+	m.approvals[approval.ID] = &stored
+	return nil
+}
+
+func (m *memoryApprovals) Get(orgID, id string) (*types.ToolApproval, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	approval, ok := m.approvals[id]
+	if !ok || (orgID != "" && approval.OrganizationID != orgID) {
+		return nil, nil
+	}
+	copied := *approval
+	return &copied, nil
+}
+
+func (m *memoryApprovals) List(filter *types.ApprovalListFilter) ([]*types.ToolApproval, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	approvals := []*types.ToolApproval{}
+	for _, approval := range m.approvals {
+		if approval.OrganizationID == filter.OrganizationID && (filter.Status == "" || approval.Status == filter.Status) {
+			copied := *approval
+			approvals = append(approvals, &copied)
+		}
+	}
+	return approvals, nil
+}
+
+func (m *memoryApprovals) Decide(id, status, decidedBy, reason string, at time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	approval, ok := m.approvals[id]
+	if !ok || approval.Status != types.ApprovalStatusPending {
+		return false, nil
+	}
+	approval.Status = status
+	approval.DecidedBy = decidedBy
+	approval.Reason = reason
+	approval.DecidedAt = &at
+	return true, nil
+}
+
+func (m *memoryApprovals) ExpirePending(orgID string, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var expired int64
+	for _, approval := range m.approvals {
+		if approval.OrganizationID == orgID && approval.Status == types.ApprovalStatusPending && approval.ExpiresAt.Before(now) {
+			approval.Status = types.ApprovalStatusExpired
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// pendingApproval waits for a held call to be recorded and returns its ID
+func pendingApproval(t *testing.T, store *memoryApprovals) string {
+	t.Helper()
+	var id string
+	require.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		for _, approval := range store.approvals {
+			if approval.Status == types.ApprovalStatusPending {
+				id = approval.ID
+				return true
+			}
+		}
+		return false
+	}, time.Second, 5*time.Millisecond)
+	return id
+}
+
+type heldCall struct {
+	approval *types.ToolApproval
+	err      error
+}
+
+// holdCall awaits approval of a shell__exec call on another goroutine
+func holdCall(ctx context.Context, service *services.ApprovalService, settings *types.ToolApprovalSettings) <-chan heldCall {
+	result := make(chan heldCall, 1)
+	go func() {
+		approval, err := service.AwaitApproval(ctx, "org-1", &toolpolicy.Call{
+			Arguments:   map[string]interface{}{"command": "rm -rf /tmp/cache"},
+			Principal:   &types.Principal{Type: types.PrincipalTypeAPIKey, APIKeyID: "key-1"},
+			NamespaceID: "ns-1",
+			ServerID:    "server-1",
+			Tool:        "shell__exec",
+		}, &types.ToolPolicyDecision{
+			Effect:     types.ToolPolicyEffectRequireApproval,
+			PolicyID:   "policy-1",
+			PolicyName: "hold shell",
+			Approval:   settings,
+		})
+		result <- heldCall{approval: approval, err: err}
+	}()
+	return result
+}
+
+func TestApprovalResumesApprovedCall(t *testing.T) {
+	store := &memoryApprovals{approvals: map[string]*types.ToolApproval{}}
+	service := services.NewApprovalServiceWithStore(store, "https://gateway.example.com", services.ApprovalSettings{})
+	notifier := &recordingAdminNotifier{}
+	mail := &recordingMailer{}
+	service.SetNotifier(notifier)
+	service.SetMailer(mail)
+
+	var payload types.ApprovalWebhookPayload
+	var event string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event = r.Header.Get("X-Omnimesh-Event")
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer webhook.Close()
+
+	result := holdCall(context.Background(), service, &types.ToolApprovalSettings{
+		Emails:     []string{"security@example.com"},
+		WebhookURL: webhook.URL,
+	})
+	id := pendingApproval(t, store)
+
+	approval, err := service.Decide(context.Background(), "org-1", id, true, "admin-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, types.ApprovalStatusApproved, approval.Status)
+
+	held := <-result
+	require.NoError(t, held.err)
+	assert.Equal(t, types.ApprovalStatusApproved, held.approval.Status)
+	assert.Equal(t, "admin-1", held.approval.DecidedBy)
+	assert.Equal(t, "api_key:key-1", held.approval.RequestedBy)
+
+	// Admins, the policy's approvers and its webhook were all told
+	require.Len(t, notifier.events, 1)
+	assert.Equal(t, types.NotificationApprovalPending, notifier.events[0].Type)
+	assert.Equal(t, id, notifier.events[0].ResourceID)
+	require.Len(t, mail.messages, 1)
+	assert.Equal(t, "security@example.com", mail.messages[0].To)
+	assert.Contains(t, mail.messages[0].Body, "https://gateway.example.com/api/admin/approvals/"+id+"/approve")
+	assert.Equal(t, types.ApprovalWebhookEvent, event)
+	assert.Equal(t, "shell__exec", payload.Approval.Tool)
+	assert.Equal(t, "https://gateway.example.com/api/admin/approvals/"+id, payload.DecideURL)
+
+	// A decided approval cannot be decided again
+	_, err = service.Decide(context.Background(), "org-1", id, false, "admin-2", "")
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+}
+
+func TestApprovalRejectFailsCall(t *testing.T) {
+	store := &memoryApprovals{approvals: map[string]*types.ToolApproval{}}
+	service := services.NewApprovalServiceWithStore(store, "", services.ApprovalSettings{})
+
+	result := holdCall(context.Background(), service, nil)
+	id := pendingApproval(t, store)
+
+	// Other organizations cannot see or decide the approval
+	_, err := service.Decide(context.Background(), "org-2", id, true, "admin-2", "")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	_, err = service.Decide(context.Background(), "org-1", id, false, "admin-1", "not during the freeze")
+	require.NoError(t, err)
+
+	held := <-result
+	require.NoError(t, held.err)
+	assert.Equal(t, types.ApprovalStatusRejected, held.approval.Status)
+	assert.Equal(t, "not during the freeze", held.approval.Reason)
+}
+
+func TestApprovalPicksUpDecisionFromAnotherInstance(t *testing.T) {
+	store := &memoryApprovals{approvals: map[string]*types.ToolApproval{}}
+	waiting := services.NewApprovalServiceWithStore(store, "", services.ApprovalSettings{PollInterval: 10 * time.Millisecond})
+	deciding := services.NewApprovalServiceWithStore(store, "", services.ApprovalSettings{})
+
+	result := holdCall(context.Background(), waiting, nil)
+	id := pendingApproval(t, store)
+	_, err := deciding.Decide(context.Background(), "org-1", id, true, "admin-1", "")
+	require.NoError(t, err)
+
+	held := <-result
+	require.NoError(t, held.err)
+	assert.Equal(t, types.ApprovalStatusApproved, held.approval.Status)
+}
+
+func TestApprovalTimesOut(t *testing.T) {
+	store := &memoryApprovals{approvals: map[string]*types.ToolApproval{}}
+	service := services.NewApprovalServiceWithStore(store, "", services.ApprovalSettings{
+		Timeout: 30 * time.Millisecond,
+	})
+
+	held := <-holdCall(context.Background(), service, nil)
+	require.NoError(t, held.err)
+	assert.Equal(t, types.ApprovalStatusExpired, held.approval.Status)
+
+	_, err := service.Decide(context.Background(), "org-1", held.approval.ID, true, "admin-1", "")
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+
+	// A caller that gives up cancels its approval
+	ctx, cancel := context.WithCancel(context.Background())
+	result := holdCall(ctx, services.NewApprovalServiceWithStore(store, "", services.ApprovalSettings{}), nil)
+	require.Eventually(t, func() bool {
+		approvals, _ := store.List(&types.ApprovalListFilter{OrganizationID: "org-1", Status: types.ApprovalStatusPending})
+		return len(approvals) == 1
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	held = <-result
+	require.NoError(t, held.err)
+	assert.Equal(t, types.ApprovalStatusCancelled, held.approval.Status)
+
+	_, err = service.List(context.Background(), "org-1", "unknown", 0, 0)
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+}
+
+func TestTimeoutMiddlewareExtendsDeadline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.TimeoutWithConfig(&middleware.TimeoutConfig{Timeout: 30 * time.Millisecond}))
+	router.GET("/held", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		require.True(t, types.ExtendDeadline(ctx, time.Now().Add(time.Second)))
+		time.Sleep(80 * time.Millisecond)
+		assert.NoError(t, ctx.Err())
+		c.Status(http.StatusOK)
+	})
+	router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		assert.ErrorIs(t, c.Request.Context().Err(), context.DeadlineExceeded)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/held", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestApprovalSettingsValidation(t *testing.T) {
+	held := map[string]interface{}{
+		"effect":   types.ToolPolicyEffectRequireApproval,
+		"approval": map[string]interface{}{"emails": []string{"security@example.com"}, "timeout_seconds": 600},
+	}
+	assert.NoError(t, toolpolicy.Validate(map[string]interface{}{"tools": []string{"shell__*"}}, held))
+
+	for name, actions := range map[string]map[string]interface{}{
+		"approval on allow": {"effect": types.ToolPolicyEffectAllow, "approval": map[string]interface{}{"timeout_seconds": 60}},
+		"bad email":         {"effect": types.ToolPolicyEffectRequireApproval, "approval": map[string]interface{}{"emails": []string{"security"}}},
+		"bad webhook":       {"effect": types.ToolPolicyEffectRequireApproval, "approval": map[string]interface{}{"webhook_url": "ftp://example.com"}},
+		"negative timeout":  {"effect": types.ToolPolicyEffectRequireApproval, "approval": map[string]interface{}{"timeout_seconds": -1}},
+	} {
+		assert.Error(t, toolpolicy.Validate(map[string]interface{}{}, actions), name)
+	}
+
+	assert.NoError(t, (&config.ApprovalConfig{Timeout: 5 * time.Minute, MaxTimeout: 30 * time.Minute}).Validate())
+	assert.Error(t, (&config.ApprovalConfig{Timeout: time.Hour, MaxTimeout: 30 * time.Minute}).Validate())
+	assert.Error(t, (&config.ApprovalConfig{PollInterval: -time.Second}).Validate())
+}
@@ -0,0 +1,199 @@
+package unit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const auditTestOrg = "00000000-0000-0000-0000-000000000000"
+
+// buildAuditChain creates a valid chain of n records
+func buildAuditChain(n int) []*types.AuditLog {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	prev := types.AuditGenesisHash
+	chain := make([]*types.AuditLog, 0, n)
+	for i := 1; i <= n; i++ {
+		audit := &types.AuditLog{
+			Timestamp:      base.Add(time.Duration(i) * time.Second),
+			Details:        map[string]interface{}{"method": "POST", "status_code": float64(200)},
+			ID:             fmt.Sprintf("record-%d", i),
+			UserID:         "user-1",
+			OrganizationID: auditTestOrg,
+			Action:         "update",
+			Resource:       "server",
+			ResourceID:     "server-1",
+			RemoteIP:       "10.0.0.1",
+			Severity:       types.AuditSeverityNotice,
+			PrevHash:       prev,
+			Sequence:       int64(i),
+			Success:        true,
+		}
+		audit.Hash = logging.ComputeAuditHash(audit)
+		prev = audit.Hash
+		chain = append(chain, audit)
+	}
+	return chain
+}
+
+func anchorFor(record *types.AuditLog, prevDigest string) *types.AuditAnchor {
+	anchor := &types.AuditAnchor{
+		CreatedAt:      record.Timestamp,
+		ID:             fmt.Sprintf("anchor-%d", record.Sequence),
+		OrganizationID: record.OrganizationID,
+		RecordHash:     record.Hash,
+		PrevDigest:     prevDigest,
+		Sequence:       record.Sequence,
+	}
+	anchor.Digest = logging.ComputeAnchorDigest(anchor)
+	return anchor
+}
+
+func verifyAuditChain(chain []*types.AuditLog, anchors []*types.AuditAnchor) *types.AuditChainReport {
+	verifier := logging.NewAuditChainVerifier(auditTestOrg, anchors)
+	for _, record := range chain {
+		verifier.Add(record)
+	}
+	return verifier.Finish()
+}
+
+func TestComputeAuditHash(t *testing.T) {
+	chain := buildAuditChain(1)
+	record := chain[0]
+
+	assert.Len(t, record.Hash, 64)
+	assert.Equal(t, record.Hash, logging.ComputeAuditHash(record), "hash must be deterministic")
+
+	// Timestamps are compared at database precision and independent of zone
+	shifted := *record
+	shifted.Timestamp = record.Timestamp.Add(300 * time.Nanosecond).In(time.FixedZone("X", 3600))
+	assert.Equal(t, record.Hash, logging.ComputeAuditHash(&shifted))
+
+	changed := *record
+	changed.Success = false
+	assert.NotEqual(t, record.Hash, logging.ComputeAuditHash(&changed))
+}
+
+func TestAuditChainVerifier(t *testing.T) {
+	t.Run("valid chain with anchors", func(t *testing.T) {
+		chain := buildAuditChain(5)
+		first := anchorFor(chain[1], types.AuditGenesisHash)
+		second := anchorFor(chain[4], first.Digest)
+
+		report := verifyAuditChain(chain, []*types.AuditAnchor{second, first})
+		assert.True(t, report.Valid, "%+v", report.Failures)
+		assert.Equal(t, 5, report.RecordsChecked)
+		assert.Equal(t, 2, report.AnchorsChecked)
+		assert.Equal(t, int64(5), report.HeadSequence)
+		assert.Equal(t, chain[4].Hash, report.HeadHash)
+	})
+
+	t.Run("modified record", func(t *testing.T) {
+		chain := buildAuditChain(3)
+		chain[1].Action = "read"
+
+		report := verifyAuditChain(chain, nil)
+		require.False(t, report.Valid)
+		require.Len(t, report.Failures, 1)
+		assert.Equal(t, int64(2), report.Failures[0].Sequence)
+		assert.Equal(t, "record hash mismatch", report.Failures[0].Reason)
+	})
+
+	t.Run("rehashed record breaks the next link", func(t *testing.T) {
+		chain := buildAuditChain(3)
+		chain[1].Action = "read"
+		chain[1].Hash = logging.ComputeAuditHash(chain[1])
+
+		report := verifyAuditChain(chain, nil)
+		require.False(t, report.Valid)
+		assert.Equal(t, int64(3), report.Failures[0].Sequence)
+		assert.Contains(t, report.Failures[0].Reason, "previous hash")
+	})
+
+	t.Run("deleted record", func(t *testing.T) {
+		chain := buildAuditChain(4)
+		chain = append(chain[:2], chain[3:]...)
+
+		report := verifyAuditChain(chain, nil)
+		require.False(t, report.Valid)
+		assert.Contains(t, report.Failures[0].Reason, "sequence gap")
+	})
+
+	t.Run("rewritten chain detected by anchor", func(t *testing.T) {
+		chain := buildAuditChain(3)
+		anchor := anchorFor(chain[2], types.AuditGenesisHash)
+
+		// Rewrite the whole chain consistently; only the anchor can catch it
+		chain[0].UserID = "attacker"
+		prev := types.AuditGenesisHash
+		for _, record := range chain {
+			record.PrevHash = prev
+			record.Hash = logging.ComputeAuditHash(record)
+			prev = record.Hash
+		}
+
+		report := verifyAuditChain(chain, []*types.AuditAnchor{anchor})
+		require.False(t, report.Valid)
+		assert.Contains(t, report.Failures[0].Reason, "does not match anchor")
+	})
+
+	t.Run("tampered anchor", func(t *testing.T) {
+		chain := buildAuditChain(2)
+		anchor := anchorFor(chain[1], types.AuditGenesisHash)
+		anchor.RecordHash = chain[0].Hash
+
+		report := verifyAuditChain(chain, []*types.AuditAnchor{anchor})
+		require.False(t, report.Valid)
+		assert.Equal(t, "anchor digest mismatch", report.Failures[0].Reason)
+	})
+
+	t.Run("truncated chain head", func(t *testing.T) {
+		chain := buildAuditChain(4)
+		anchor := anchorFor(chain[3], types.AuditGenesisHash)
+
+		report := verifyAuditChain(chain[:3], []*types.AuditAnchor{anchor})
+		require.False(t, report.Valid)
+		assert.Equal(t, "anchored record is missing", report.Failures[0].Reason)
+	})
+
+	t.Run("chain pruned by retention", func(t *testing.T) {
+		chain := buildAuditChain(5)
+		anchor := anchorFor(chain[1], types.AuditGenesisHash)
+
+		report := verifyAuditChain(chain[3:], []*types.AuditAnchor{anchor})
+		assert.True(t, report.Valid, "%+v", report.Failures)
+	})
+}
+
+func TestAuditSeverityFor(t *testing.T) {
+	tests := []struct {
+		action   string
+		resource string
+		expected string
+		success  bool
+	}{
+		{"read", "server", types.AuditSeverityInfo, true},
+		{"create", "server", types.AuditSeverityNotice, true},
+		{"create", "server", types.AuditSeverityWarning, false},
+		{"delete", "namespace", types.AuditSeverityWarning, true},
+		{"update", "auth-config", types.AuditSeverityCritical, true},
+		{"import", "configuration", types.AuditSeverityCritical, true},
+		{"export", "configuration", types.AuditSeverityNotice, true},
+		{"regenerate-keys", "oauth_client", types.AuditSeverityCritical, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action+"_"+tt.resource, func(t *testing.T) {
+			assert.Equal(t, tt.expected, types.AuditSeverityFor(tt.action, tt.resource, tt.success))
+		})
+	}
+
+	assert.Greater(t, types.AuditSeverityRank(types.AuditSeverityCritical), types.AuditSeverityRank(types.AuditSeverityWarning))
+	assert.False(t, types.IsValidAuditSeverity("debug"))
+}
@@ -0,0 +1,586 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/pkg/requestsig"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAuditSinks is an in-memory AuditSinkStore over a fixed audit chain
+type memoryAuditSinks struct {
+	sinks  map[string]*types.AuditSink
+	chains map[string][]*types.AuditLog
+	mu     sync.Mutex
+	nextID int
+}
+
+func newMemoryAuditSinks() *memoryAuditSinks {
+	return &memoryAuditSinks{
+		sinks:  make(map[string]*types.AuditSink),
+		chains: make(map[string][]*types.AuditLog),
+	}
+}
+
+// audit appends a record to the organization's chain
+func (m *memoryAuditSinks) audit(orgID, action, severity string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	chain := m.chains[orgID]
+	sequence := int64(len(chain) + 1)
+	m.chains[orgID] = append(chain, &types.AuditLog{
+		Timestamp:      time.Date(2026, 3, 1, 12, 0, int(sequence), 0, time.UTC),
+		ID:             fmt.Sprintf("audit-%d", sequence),
+		OrganizationID: orgID,
+		UserID:         "user-1",
+		Action:         action,
+		Resource:       "server",
+		Severity:       severity,
+		Sequence:       sequence,
+		Hash:           fmt.Sprintf("hash-%d", sequence),
+		Success:        true,
+	})
+}
+
+func (m *memoryAuditSinks) sink(id string) types.AuditSink {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return *m.sinks[id]
+}
+
+// due ends a sink's backoff, as if its retry time had come
+func (m *memoryAuditSinks) due(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sinks[id].NextAttemptAt = nil
+}
+
+func (m *memoryAuditSinks) CreateSink(sink *types.AuditSink) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	sink.ID = fmt.Sprintf("sink-%d", m.nextID)
+	sink.CreatedAt = time.Now()
+	stored := *sink
+	m.sinks[sink.ID] = &stored
+	return nil
+}
+
+func (m *memoryAuditSinks) GetSink(orgID, id string) (*types.AuditSink, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sink, ok := m.sinks[id]
+	if !ok || sink.OrganizationID != orgID {
+		return nil, nil
+	}
+	copied := *sink
+	return &copied, nil
+}
+
+func (m *memoryAuditSinks) ListSinks(orgID string) ([]*types.AuditSink, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sinks []*types.AuditSink
+	for _, sink := range m.sinks {
+		if sink.OrganizationID == orgID {
+			copied := *sink
+			sinks = append(sinks, &copied)
+		}
+	}
+	return sinks, nil
+}
+
+func (m *memoryAuditSinks) UpdateSink(sink *types.AuditSink, resetBackoff bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := m.sinks[sink.ID]
+	cursor, failures, next := stored.CursorSequence, stored.Failures, stored.NextAttemptAt
+	*stored = *sink
+	stored.CursorSequence, stored.Failures, stored.NextAttemptAt = cursor, failures, next
+	if resetBackoff {
+		stored.Failures, stored.NextAttemptAt = 0, nil
+	}
+	return nil
+}
+
+func (m *memoryAuditSinks) DeleteSink(orgID, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sink, ok := m.sinks[id]; !ok || sink.OrganizationID != orgID {
+		return false, nil
+	}
+	delete(m.sinks, id)
+	return true, nil
+}
+
+func (m *memoryAuditSinks) ChainHead(orgID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return int64(len(m.chains[orgID])), nil
+}
+
+func (m *memoryAuditSinks) ClaimDueSinks(now, leaseUntil time.Time, limit int) ([]*types.AuditSink, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var claimed []*types.AuditSink
+	for _, sink := range m.sinks {
+		if !sink.Enabled || (sink.NextAttemptAt != nil && sink.NextAttemptAt.After(now)) || len(claimed) == limit {
+			continue
+		}
+		lease := leaseUntil
+		sink.NextAttemptAt = &lease
+		copied := *sink
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (m *memoryAuditSinks) ListChainedAudits(orgID string, after int64, limit int) ([]*types.AuditLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var records []*types.AuditLog
+	for _, record := range m.chains[orgID] {
+		if record.Sequence > after && len(records) < limit {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (m *memoryAuditSinks) AdvanceSink(id string, cursor int64, deliveredAt *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sink := m.sinks[id]
+	sink.CursorSequence = max(sink.CursorSequence, cursor)
+	if deliveredAt != nil {
+		sink.LastDeliveredAt = deliveredAt
+	}
+	return nil
+}
+
+func (m *memoryAuditSinks) ReleaseSink(id string, failures int, nextAttemptAt *time.Time, lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sink := m.sinks[id]
+	sink.Failures, sink.NextAttemptAt, sink.LastError = failures, nextAttemptAt, lastError
+	return nil
+}
+
+const auditExportOrg = "org-1"
+
+func createAuditSink(t *testing.T, service *logging.AuditExportService, req *types.CreateAuditSinkRequest) *types.AuditSink {
+	t.Helper()
+	sink, err := service.CreateSink(context.Background(), auditExportOrg, "user-1", req)
+	require.NoError(t, err)
+	return sink
+}
+
+func TestAuditExport_WebhookRetriesFromCursor(t *testing.T) {
+	store := newMemoryAuditSinks()
+	store.audit(auditExportOrg, "create", types.AuditSeverityNotice)
+	store.audit(auditExportOrg, "delete", types.AuditSeverityWarning)
+	store.audit("org-2", "create", types.AuditSeverityNotice)
+
+	var mu sync.Mutex
+	failing := true
+	var received [][]int64
+	var signingKey *requestsig.Key
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, err := requestsig.Verify(r.Header.Get(requestsig.Header), body, func(keyID string) (*requestsig.Key, error) {
+			return signingKey, nil
+		}, 0, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, "audit.batch", r.Header.Get("X-Omnimesh-Event"))
+		assert.Equal(t, "Splunk abc", r.Header.Get("Authorization"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload logging.AuditWebhookPayload
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, auditExportOrg, payload.OrganizationID)
+		var sequences []int64
+		for _, event := range payload.Events {
+			sequences = append(sequences, event.Sequence)
+		}
+		received = append(received, sequences)
+	}))
+	defer server.Close()
+
+	service := logging.NewAuditExportServiceWithStore(store)
+	sink := createAuditSink(t, service, &types.CreateAuditSinkRequest{
+		Name:      "splunk",
+		Type:      types.AuditSinkTypeWebhook,
+		Config:    types.AuditSinkConfig{URL: server.URL, Headers: map[string]string{"Authorization": "Splunk abc"}},
+		Secret:    "s3cret",
+		BatchSize: 1,
+		Backfill:  true,
+	})
+	assert.True(t, sink.HasSecret)
+	assert.Equal(t, types.AuditSinkFormatJSON, sink.Format)
+	signingKey = requestsig.NewHMACKey(sink.ID, "s3cret")
+	assert.Equal(t, signingKey.ID, sink.SigningKeyID)
+
+	// The destination is down: nothing moves and the sink backs off
+	delivered, err := service.DeliverPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	state := store.sink(sink.ID)
+	assert.Equal(t, int64(0), state.CursorSequence)
+	assert.Equal(t, 1, state.Failures)
+	assert.Contains(t, state.LastError, "HTTP 503")
+	require.NotNil(t, state.NextAttemptAt)
+	assert.WithinDuration(t, time.Now().Add(logging.AuditSinkBackoff(1)), *state.NextAttemptAt, 5*time.Second)
+
+	delivered, err = service.DeliverPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered, "a backing off sink is not retried early")
+
+	// Once it recovers, delivery resumes from the first record, batch by batch
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	store.due(sink.ID)
+	delivered, err = service.DeliverPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	state = store.sink(sink.ID)
+	assert.Equal(t, int64(2), state.CursorSequence)
+	assert.Zero(t, state.Failures)
+	assert.Empty(t, state.LastError)
+	assert.Nil(t, state.NextAttemptAt)
+	assert.NotNil(t, state.LastDeliveredAt)
+
+	// Later records go out once, without repeating delivered ones
+	store.audit(auditExportOrg, "update", types.AuditSeverityNotice)
+	delivered, err = service.DeliverPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, [][]int64{{1}, {2}, {3}}, received)
+}
+
+func TestAuditExport_SkipsRecordsBelowSeverity(t *testing.T) {
+	store := newMemoryAuditSinks()
+	store.audit(auditExportOrg, "read", types.AuditSeverityInfo)
+	store.audit(auditExportOrg, "regenerate-keys", types.AuditSeverityCritical)
+	store.audit(auditExportOrg, "read", types.AuditSeverityInfo)
+
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "text/plain; charset=utf-8", r.Header.Get("Content-Type"))
+		received = append(received, strings.Split(strings.TrimSpace(string(body)), "\n")...)
+	}))
+	defer server.Close()
+
+	service := logging.NewAuditExportServiceWithStore(store)
+	sink := createAuditSink(t, service, &types.CreateAuditSinkRequest{
+		Name:        "qradar",
+		Type:        types.AuditSinkTypeWebhook,
+		Format:      types.AuditSinkFormatCEF,
+		MinSeverity: types.AuditSeverityWarning,
+		Config:      types.AuditSinkConfig{URL: server.URL},
+		Backfill:    true,
+	})
+
+	delivered, err := service.DeliverPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	require.Len(t, received, 1)
+	assert.Contains(t, received[0], "|server:regenerate-keys|")
+	assert.Equal(t, int64(3), store.sink(sink.ID).CursorSequence, "skipped records are not retried")
+}
+
+func TestAuditExport_NewSinkStartsAtChainHead(t *testing.T) {
+	store := newMemoryAuditSinks()
+	store.audit(auditExportOrg, "create", types.AuditSeverityNotice)
+	store.audit(auditExportOrg, "create", types.AuditSeverityNotice)
+
+	service := logging.NewAuditExportServiceWithStore(store)
+	sink := createAuditSink(t, service, &types.CreateAuditSinkRequest{
+		Name:   "siem",
+		Type:   types.AuditSinkTypeSyslog,
+		Config: types.AuditSinkConfig{Address: "127.0.0.1:514"},
+	})
+	assert.Equal(t, int64(2), sink.CursorSequence)
+	assert.Equal(t, types.AuditSyslogNetworkUDP, sink.Config.Network)
+	assert.Equal(t, 100, sink.BatchSize)
+}
+
+func TestAuditExport_SyslogTCPFraming(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	frames := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		var messages []string
+		for {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(length))
+			message := make([]byte, n)
+			if _, err := io.ReadFull(reader, message); err != nil {
+				break
+			}
+			messages = append(messages, string(message))
+		}
+		frames <- messages
+	}()
+
+	store := newMemoryAuditSinks()
+	store.audit(auditExportOrg, "create", types.AuditSeverityNotice)
+	store.audit(auditExportOrg, "regenerate-keys", types.AuditSeverityCritical)
+
+	facility := 10
+	service := logging.NewAuditExportServiceWithStore(store)
+	createAuditSink(t, service, &types.CreateAuditSinkRequest{
+		Name: "rsyslog",
+		Type: types.AuditSinkTypeSyslog,
+		Config: types.AuditSinkConfig{
+			Address:  listener.Addr().String(),
+			Network:  types.AuditSyslogNetworkTCP,
+			Facility: &facility,
+			AppName:  "gateway",
+		},
+		Backfill: true,
+	})
+
+	delivered, err := service.DeliverPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+
+	var messages []string
+	select {
+	case messages = <-frames:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no syslog messages received")
+	}
+	require.Len(t, messages, 2)
+	// facility 10 * 8 + notice (5), then + critical (2)
+	assert.True(t, strings.HasPrefix(messages[0], "<85>1 2026-03-01T12:00:01.000000Z "), messages[0])
+	assert.True(t, strings.HasPrefix(messages[1], "<82>1 "), messages[1])
+	assert.Contains(t, messages[0], " gateway - audit - {")
+
+	var record types.AuditLog
+	require.NoError(t, json.Unmarshal([]byte(messages[0][strings.Index(messages[0], "{"):]), &record))
+	assert.Equal(t, int64(1), record.Sequence)
+}
+
+func TestAuditExport_SyslogUDP(t *testing.T) {
+	conn := listenUDP(t)
+
+	store := newMemoryAuditSinks()
+	store.audit(auditExportOrg, "delete", types.AuditSeverityWarning)
+
+	service := logging.NewAuditExportServiceWithStore(store)
+	createAuditSink(t, service, &types.CreateAuditSinkRequest{
+		Name:     "udp",
+		Type:     types.AuditSinkTypeSyslog,
+		Format:   types.AuditSinkFormatCEF,
+		Config:   types.AuditSinkConfig{Address: conn.LocalAddr().String()},
+		Backfill: true,
+	})
+
+	delivered, err := service.DeliverPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	buf := make([]byte, 4096)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFromUDP(buf)
+	require.NoError(t, err)
+	// log audit facility (13) * 8 + warning (4)
+	assert.Regexp(t, `^<108>1 \S+ \S+ omnimesh-gateway - audit - CEF:0\|Omnimesh\|Omnimesh Gateway\|`, string(buf[:n]))
+}
+
+func TestAuditExport_KafkaRESTProxy(t *testing.T) {
+	var records []map[string]interface{}
+	rejectNext := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/topics/gateway-audit", r.URL.Path)
+		assert.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "svc", user)
+		assert.Equal(t, "pw", pass)
+
+		var body struct {
+			Records []map[string]interface{} `json:"records"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if rejectNext {
+			fmt.Fprint(w, `{"offsets":[{"partition":null,"offset":null,"error_code":40403,"error":"topic not authorized"}]}`)
+			return
+		}
+		records = append(records, body.Records...)
+		fmt.Fprint(w, `{"offsets":[{"partition":0,"offset":7,"error_code":null,"error":null}]}`)
+	}))
+	defer server.Close()
+
+	store := newMemoryAuditSinks()
+	store.audit(auditExportOrg, "create", types.AuditSeverityNotice)
+
+	service := logging.NewAuditExportServiceWithStore(store)
+	sink := createAuditSink(t, service, &types.CreateAuditSinkRequest{
+		Name:     "kafka",
+		Type:     types.AuditSinkTypeKafka,
+		Config:   types.AuditSinkConfig{URL: server.URL + "/v3/", Topic: "gateway-audit", Username: "svc"},
+		Secret:   "pw",
+		Backfill: true,
+	})
+
+	delivered, err := service.DeliverPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	require.Len(t, records, 1)
+	assert.Equal(t, auditExportOrg, records[0]["key"])
+	assert.Equal(t, "audit-1", records[0]["value"].(map[string]interface{})["id"])
+
+	// Per-record errors in a 200 answer fail the batch
+	rejectNext = true
+	store.audit(auditExportOrg, "update", types.AuditSeverityNotice)
+	delivered, err = service.DeliverPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	state := store.sink(sink.ID)
+	assert.Equal(t, int64(1), state.CursorSequence)
+	assert.Contains(t, state.LastError, "topic not authorized")
+}
+
+func TestAuditExport_ValidateSink(t *testing.T) {
+	service := logging.NewAuditExportServiceWithStore(newMemoryAuditSinks())
+	badFacility := 24
+
+	tests := []struct {
+		name string
+		req  types.CreateAuditSinkRequest
+		want string
+	}{
+		{name: "syslog without port", req: types.CreateAuditSinkRequest{Type: "syslog", Config: types.AuditSinkConfig{Address: "siem.local"}}, want: "config.address"},
+		{name: "syslog network", req: types.CreateAuditSinkRequest{Type: "syslog", Config: types.AuditSinkConfig{Address: "siem.local:514", Network: "quic"}}, want: "config.network"},
+		{name: "syslog facility", req: types.CreateAuditSinkRequest{Type: "syslog", Config: types.AuditSinkConfig{Address: "siem.local:514", Facility: &badFacility}}, want: "config.facility"},
+		{name: "webhook scheme", req: types.CreateAuditSinkRequest{Type: "webhook", Config: types.AuditSinkConfig{URL: "ftp://siem.local"}}, want: "config.url"},
+		{name: "kafka topic", req: types.CreateAuditSinkRequest{Type: "kafka", Config: types.AuditSinkConfig{URL: "https://proxy.local", Topic: "bad topic"}}, want: "config.topic"},
+		{name: "header name", req: types.CreateAuditSinkRequest{Type: "webhook", Config: types.AuditSinkConfig{URL: "https://siem.local", Headers: map[string]string{"X Bad": "1"}}}, want: "config.headers"},
+		{name: "unknown type", req: types.CreateAuditSinkRequest{Type: "s3"}, want: "type must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Name = tt.name
+			_, err := service.CreateSink(context.Background(), auditExportOrg, "user-1", &tt.req)
+			var typed *types.Error
+			require.ErrorAs(t, err, &typed)
+			assert.Equal(t, types.ErrCodeValidationFailed, typed.Code)
+			assert.Contains(t, typed.Message, tt.want)
+		})
+	}
+
+	createAuditSink(t, service, &types.CreateAuditSinkRequest{Name: "Splunk", Type: "webhook", Config: types.AuditSinkConfig{URL: "https://siem.local"}})
+	_, err := service.CreateSink(context.Background(), auditExportOrg, "user-1", &types.CreateAuditSinkRequest{
+		Name: "splunk", Type: "webhook", Config: types.AuditSinkConfig{URL: "https://other.local"},
+	})
+	var typed *types.Error
+	require.ErrorAs(t, err, &typed)
+	assert.Equal(t, types.ErrCodeConflict, typed.Code)
+}
+
+func TestAuditExport_UpdateResetsBackoff(t *testing.T) {
+	store := newMemoryAuditSinks()
+	service := logging.NewAuditExportServiceWithStore(store)
+	sink := createAuditSink(t, service, &types.CreateAuditSinkRequest{
+		Name:   "siem",
+		Type:   types.AuditSinkTypeWebhook,
+		Config: types.AuditSinkConfig{URL: "https://siem.local"},
+		Secret: "s3cret",
+	})
+	require.NoError(t, store.ReleaseSink(sink.ID, 4, func() *time.Time { t := time.Now().Add(time.Hour); return &t }(), "HTTP 500"))
+
+	// Tuning the batch leaves the backoff alone
+	batch := 50
+	updated, err := service.UpdateSink(context.Background(), auditExportOrg, sink.ID, &types.UpdateAuditSinkRequest{BatchSize: &batch})
+	require.NoError(t, err)
+	assert.Equal(t, 4, updated.Failures)
+
+	// A new destination is tried right away; an empty secret removes it
+	empty := ""
+	updated, err = service.UpdateSink(context.Background(), auditExportOrg, sink.ID, &types.UpdateAuditSinkRequest{
+		Config: &types.AuditSinkConfig{URL: "https://siem2.local"},
+		Secret: &empty,
+	})
+	require.NoError(t, err)
+	assert.Zero(t, updated.Failures)
+	assert.Nil(t, updated.NextAttemptAt)
+	assert.False(t, updated.HasSecret)
+	assert.Empty(t, updated.SigningKeyID)
+	assert.Nil(t, store.sink(sink.ID).NextAttemptAt)
+
+	_, err = service.UpdateSink(context.Background(), "org-2", sink.ID, &types.UpdateAuditSinkRequest{BatchSize: &batch})
+	var typed *types.Error
+	require.ErrorAs(t, err, &typed)
+	assert.Equal(t, types.ErrCodeNotFound, typed.Code)
+}
+
+func TestFormatAuditCEF(t *testing.T) {
+	line := logging.FormatAuditCEF(&types.AuditLog{
+		Timestamp:      time.UnixMilli(1772366400000),
+		ID:             "audit-9",
+		UserID:         "user-1",
+		OrganizationID: "org-1",
+		Action:         "update",
+		Resource:       "policy|acl",
+		ResourceID:     "p=1",
+		RemoteIP:       "10.0.0.7",
+		Error:          "line one\nline two \\ end",
+		Severity:       types.AuditSeverityCritical,
+		Sequence:       42,
+		Success:        false,
+	})
+
+	assert.True(t, strings.HasPrefix(line, "CEF:0|Omnimesh|Omnimesh Gateway|"), line)
+	assert.Contains(t, line, `|policy\|acl:update|update policy\|acl|10|`)
+	assert.Contains(t, line, "rt=1772366400000 externalId=audit-9 suid=user-1 src=10.0.0.7 act=update outcome=failure")
+	assert.Contains(t, line, `reason=line one\nline two \\ end`)
+	assert.Contains(t, line, `cs3Label=resourceId cs3=p\=1`)
+	assert.Contains(t, line, "cn1Label=sequence cn1=42")
+	assert.NotContains(t, line, "\n")
+	assert.NotContains(t, line, "requestClientApplication", "empty fields are left out")
+}
+
+func TestAuditSinkBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, logging.AuditSinkBackoff(1))
+	assert.Equal(t, time.Minute, logging.AuditSinkBackoff(2))
+	assert.Equal(t, 4*time.Minute, logging.AuditSinkBackoff(4))
+	assert.Equal(t, time.Hour, logging.AuditSinkBackoff(20))
+}
@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// memoryBranding keeps organizations' branding
+type memoryBranding map[string]*types.OrganizationBranding
+
+func (m memoryBranding) Get(orgID string) (*types.OrganizationBranding, error) {
+	return m[orgID], nil
+}
+
+func (m memoryBranding) Set(branding *types.OrganizationBranding) error {
+	m[branding.OrganizationID] = branding
+	return nil
+}
+
+func (m memoryBranding) Delete(orgID string) (bool, error) {
+	_, ok := m[orgID]
+	delete(m, orgID)
+	return ok, nil
+}
+
+func TestOrganizationBranding(t *testing.T) {
+	svc := services.NewBrandingServiceWithStore(memoryBranding{})
+	ctx := context.Background()
+
+	branding, err := svc.GetBranding(ctx, grantOrgID)
+	require.NoError(t, err)
+	assert.Equal(t, types.DefaultProductName, branding.DisplayName(), "unbranded organizations use the gateway's name")
+	assert.False(t, branding.HasContact())
+
+	req := &types.SetOrganizationBrandingRequest{
+		ProductName:  " Acme Tools ",
+		LogoURL:      "https://acme.example.com/logo.png",
+		PrimaryColor: "#1A2B3C",
+		ContactEmail: "api@acme.example.com",
+	}
+	require.NoError(t, binding.Validator.ValidateStruct(req))
+	branding, err = svc.SetBranding(ctx, grantOrgID, otherUserID, req)
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Tools", branding.DisplayName())
+	assert.Equal(t, "#1a2b3c", branding.PrimaryColor)
+
+	endpoint := &types.Endpoint{Name: "acme-tools", OrganizationID: grantOrgID}
+	generator := services.NewOpenAPIGenerator("https://gateway.example.com")
+	spec := generator.GenerateSpec(endpoint, &types.Namespace{Name: "tools"}, nil)
+	assert.Equal(t, "acme-tools - "+types.DefaultProductName, spec.Info.Title)
+
+	generator.ApplyBranding(spec, endpoint, branding)
+	assert.Equal(t, "acme-tools - Acme Tools", spec.Info.Title)
+	require.NotNil(t, spec.Info.Contact)
+	assert.Equal(t, "api@acme.example.com", spec.Info.Contact.Email)
+	require.NotNil(t, spec.Info.Logo)
+	assert.Equal(t, "https://acme.example.com/logo.png", spec.Info.Logo.URL)
+	assert.Equal(t, "Acme Tools Endpoint API", spec.Servers[0].Description)
+
+	require.NoError(t, svc.ResetBranding(ctx, grantOrgID))
+	assert.True(t, types.IsError(svc.ResetBranding(ctx, grantOrgID), types.ErrCodeNotFound))
+}
+
+func TestOrganizationBrandingValidation(t *testing.T) {
+	for name, req := range map[string]*types.SetOrganizationBrandingRequest{
+		"color name":      {PrimaryColor: "red"},
+		"short color":     {AccentColor: "#fff"},
+		"plain http logo": {LogoURL: "http://acme.example.com/logo.png"},
+		"script url":      {ContactURL: "javascript:alert(1)"},
+		"bad email":       {ContactEmail: "not-an-email"},
+	} {
+		assert.Error(t, binding.Validator.ValidateStruct(req), name)
+	}
+}
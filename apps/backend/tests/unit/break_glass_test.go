@@ -0,0 +1,310 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBreakGlass keeps credentials and sessions in memory, mirroring
+// BreakGlassModel
+type memoryBreakGlass struct {
+	credentials map[string]*types.BreakGlassCredential
+	sessions    map[string]*types.BreakGlassSession
+}
+
+func newMemoryBreakGlass() *memoryBreakGlass {
+	return &memoryBreakGlass{
+		credentials: make(map[string]*types.BreakGlassCredential),
+		sessions:    make(map[string]*types.BreakGlassSession),
+	}
+}
+
+func (m *memoryBreakGlass) ListCredentials(orgID string) ([]*types.BreakGlassCredential, error) {
+	credentials := []*types.BreakGlassCredential{}
+	for _, credential := range m.credentials {
+		if credential.OrganizationID == orgID {
+			credentials = append(credentials, credential)
+		}
+	}
+	return credentials, nil
+}
+
+func (m *memoryBreakGlass) GetCredential(orgID, id string) (*types.BreakGlassCredential, error) {
+	if credential, ok := m.credentials[id]; ok && credential.OrganizationID == orgID {
+		return credential, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryBreakGlass) GetCredentialByHash(hash string) (*types.BreakGlassCredential, error) {
+	for _, credential := range m.credentials {
+		if credential.CredentialHash == hash {
+			return credential, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryBreakGlass) CreateCredential(credential *types.BreakGlassCredential) error {
+	credential.ID = uuid.New().String()
+	credential.CreatedAt = time.Now()
+	m.credentials[credential.ID] = credential
+	return nil
+}
+
+func (m *memoryBreakGlass) RotateCredential(orgID, id, hash, mfaSecret string) (bool, error) {
+	credential, _ := m.GetCredential(orgID, id)
+	if credential == nil {
+		return false, nil
+	}
+	credential.CredentialHash = hash
+	credential.MFASecret = mfaSecret
+	credential.UsedAt = nil
+	return true, nil
+}
+
+func (m *memoryBreakGlass) DeleteCredential(orgID, id string) (bool, error) {
+	if credential, _ := m.GetCredential(orgID, id); credential != nil {
+		delete(m.credentials, id)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (m *memoryBreakGlass) ClaimCredential(id string, at time.Time) (bool, error) {
+	credential := m.credentials[id]
+	if credential == nil || credential.UsedAt != nil {
+		return false, nil
+	}
+	credential.UsedAt = &at
+	return true, nil
+}
+
+func (m *memoryBreakGlass) UserOrganization(userID string) (string, error) {
+	return (&memoryNamespaceGrants{}).UserOrganization(userID)
+}
+
+func (m *memoryBreakGlass) CreateSession(session *types.BreakGlassSession) error {
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *memoryBreakGlass) GetSession(id string) (*types.BreakGlassSession, error) {
+	return m.sessions[id], nil
+}
+
+func (m *memoryBreakGlass) ListSessions(orgID string) ([]*types.BreakGlassSession, error) {
+	sessions := []*types.BreakGlassSession{}
+	for _, session := range m.sessions {
+		if session.OrganizationID == orgID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *memoryBreakGlass) ListExpiredSessions(now time.Time) ([]*types.BreakGlassSession, error) {
+	sessions := []*types.BreakGlassSession{}
+	for _, session := range m.sessions {
+		if session.EndedAt == nil && !session.ExpiresAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *memoryBreakGlass) EndSession(id, endedBy string, at time.Time) (bool, error) {
+	session := m.sessions[id]
+	if session == nil || session.EndedAt != nil {
+		return false, nil
+	}
+	session.EndedAt = &at
+	session.EndedBy = endedBy
+	return true, nil
+}
+
+func (m *memoryBreakGlass) RecordAction(id string) error {
+	m.sessions[id].ActionCount++
+	return nil
+}
+
+func newBreakGlassFixture(t *testing.T, window time.Duration) (*services.BreakGlassService, *auth.JWTManager, *recordingAdminNotifier, *recordingGrantAuditor, *types.BreakGlassCredentialSecret) {
+	t.Helper()
+	jwtManager := auth.NewJWTManager("break-glass-test-secret-at-least-32-chars", time.Minute, time.Hour)
+	notifier := &recordingAdminNotifier{}
+	auditor := &recordingGrantAuditor{}
+	svc := services.NewBreakGlassServiceWithStore(newMemoryBreakGlass(), jwtManager, window)
+	svc.SetNotifier(notifier)
+	svc.SetAuditor(auditor)
+
+	secret, err := svc.CreateCredential(context.Background(), grantOrgID, otherUserID, &types.CreateBreakGlassCredentialRequest{
+		UserID: grantedUserID, Name: "vault-envelope-1",
+	})
+	require.NoError(t, err)
+	return svc, jwtManager, notifier, auditor, secret
+}
+
+func breakGlassCode(t *testing.T, secret *types.BreakGlassCredentialSecret) string {
+	t.Helper()
+	code, err := auth.TOTPCode(secret.MFASecret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func TestTOTPMatchesRFC6238(t *testing.T) {
+	// RFC 6238 appendix B SHA-1 seed, truncated to six digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	code, err := auth.TOTPCode(secret, time.Unix(59, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+	code, err = auth.TOTPCode(secret, time.Unix(1111111109, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "081804", code)
+
+	assert.True(t, auth.ValidateTOTP(secret, "287082", time.Unix(59+30, 0)), "one period of drift is tolerated")
+	assert.False(t, auth.ValidateTOTP(secret, "287082", time.Unix(59+90, 0)))
+	assert.False(t, auth.ValidateTOTP(secret, "28708", time.Unix(59, 0)))
+}
+
+func TestBreakGlassCredentialCreation(t *testing.T) {
+	svc, _, _, _, secret := newBreakGlassFixture(t, time.Hour)
+	ctx := context.Background()
+
+	assert.Contains(t, secret.Secret, types.BreakGlassCredentialPrefix)
+	assert.Contains(t, secret.MFAURI, "otpauth://totp/")
+	assert.NotContains(t, secret.Credential.CredentialHash, secret.Secret, "only a hash of the credential is kept")
+
+	_, err := svc.CreateCredential(ctx, grantOrgID, otherUserID, &types.CreateBreakGlassCredentialRequest{
+		UserID: grantedUserID, Name: "Vault-Envelope-1",
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+	_, err = svc.CreateCredential(ctx, grantOrgID, otherUserID, &types.CreateBreakGlassCredentialRequest{
+		UserID: foreignUserID, Name: "foreign",
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+}
+
+func TestBreakGlassActivation(t *testing.T) {
+	svc, jwtManager, notifier, auditor, secret := newBreakGlassFixture(t, time.Hour)
+	ctx := context.Background()
+	reason := "SSO provider outage, all admins locked out"
+
+	_, err := svc.Activate(ctx, &types.ActivateBreakGlassRequest{Credential: "mgw_bg_wrong", MFACode: "000000", Reason: reason}, "10.0.0.1")
+	assert.True(t, types.IsError(err, types.ErrCodeUnauthorized))
+
+	_, err = svc.Activate(ctx, &types.ActivateBreakGlassRequest{Credential: secret.Secret, MFACode: "000000", Reason: reason}, "10.0.0.1")
+	assert.True(t, types.IsError(err, types.ErrCodeUnauthorized), "MFA is required")
+	require.Len(t, auditor.logs, 1, "failed attempts on a real credential are audited")
+	assert.False(t, auditor.logs[0].Success)
+
+	activation, err := svc.Activate(ctx, &types.ActivateBreakGlassRequest{
+		Credential: secret.Secret, MFACode: breakGlassCode(t, secret), Reason: reason,
+	}, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, grantedUserID, activation.Session.UserID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), activation.Session.ExpiresAt, time.Minute)
+
+	claims, err := jwtManager.ValidateToken(activation.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, activation.Session.ID, claims.BreakGlassSessionID)
+	assert.WithinDuration(t, activation.Session.ExpiresAt, claims.ExpiresAt.Time, time.Second, "the token expires with the session")
+
+	require.Len(t, notifier.events, 1)
+	assert.Equal(t, types.NotificationBreakGlassActivated, notifier.events[0].Type)
+	assert.Equal(t, types.NotificationSeverityCritical, notifier.events[0].Severity)
+	last := auditor.logs[len(auditor.logs)-1]
+	assert.Equal(t, "activate", last.Action)
+	assert.True(t, last.Success)
+	assert.Equal(t, types.AuditSeverityCritical, types.AuditSeverityFor(last.Action, last.Resource, last.Success))
+
+	_, err = svc.Activate(ctx, &types.ActivateBreakGlassRequest{
+		Credential: secret.Secret, MFACode: breakGlassCode(t, secret), Reason: reason,
+	}, "10.0.0.1")
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied), "a used credential must be rotated")
+
+	rotated, err := svc.RotateCredential(ctx, grantOrgID, secret.Credential.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, secret.Secret, rotated.Secret)
+	_, err = svc.Activate(ctx, &types.ActivateBreakGlassRequest{
+		Credential: rotated.Secret, MFACode: breakGlassCode(t, rotated), Reason: reason,
+	}, "10.0.0.1")
+	assert.NoError(t, err)
+}
+
+func TestBreakGlassSessionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc, jwtManager, notifier, auditor, secret := newBreakGlassFixture(t, time.Hour)
+	ctx := context.Background()
+
+	user := &types.User{ID: grantedUserID, OrganizationID: grantOrgID, Email: "oncall@example.com", Role: types.RoleViewer, IsActive: true}
+	m := auth.NewMiddlewareWithInterface(jwtManager, &teamAuthService{user: user})
+
+	router := gin.New()
+	router.GET("/platform", m.RequireAuth(), m.RequireAdmin(), m.RequirePlatformAdmin(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	call := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/platform", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	activation, err := svc.Activate(ctx, &types.ActivateBreakGlassRequest{
+		Credential: secret.Secret, MFACode: breakGlassCode(t, secret), Reason: "database credentials rotated, admins locked out",
+	}, "10.0.0.1")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, call(activation.AccessToken), "break-glass tokens are rejected unless enabled")
+
+	m.SetBreakGlass(svc)
+	audited := len(auditor.logs)
+	assert.Equal(t, http.StatusOK, call(activation.AccessToken), "the session grants platform-admin access")
+	require.Len(t, auditor.logs, audited+1, "every request is audited")
+	assert.Equal(t, "request", auditor.logs[audited].Action)
+	assert.Equal(t, "/platform", auditor.logs[audited].Details["path"])
+
+	require.NoError(t, svc.EndSession(ctx, grantOrgID, activation.Session.ID, otherUserID))
+	assert.Equal(t, http.StatusUnauthorized, call(activation.AccessToken), "ended sessions are revoked at once")
+	assert.Equal(t, types.NotificationBreakGlassEnded, notifier.events[len(notifier.events)-1].Type)
+
+	err = svc.EndSession(ctx, grantOrgID, activation.Session.ID, otherUserID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+}
+
+func TestBreakGlassSessionsExpire(t *testing.T) {
+	svc, _, notifier, _, secret := newBreakGlassFixture(t, 20*time.Millisecond)
+	ctx := context.Background()
+
+	activation, err := svc.Activate(ctx, &types.ActivateBreakGlassRequest{
+		Credential: secret.Secret, MFACode: breakGlassCode(t, secret), Reason: "primary admin account compromised",
+	}, "")
+	require.NoError(t, err)
+
+	ended, err := svc.ExpireSessions(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, ended)
+
+	time.Sleep(30 * time.Millisecond)
+	active, err := svc.ActiveSession(ctx, activation.Session.ID)
+	require.NoError(t, err)
+	assert.Nil(t, active, "sessions stop granting access at expiry")
+
+	ended, err = svc.ExpireSessions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ended)
+	assert.Equal(t, types.NotificationBreakGlassEnded, notifier.events[len(notifier.events)-1].Type)
+}
@@ -0,0 +1,295 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCatalogSearch matches entries whose text contains a query word and
+// ranks embeddings by cosine similarity
+type memoryCatalogSearch struct {
+	entries    []*types.SearchResult
+	texts      map[string]string
+	embeddings map[string][]float32
+	hashes     map[string]string
+	lastQuery  *types.SearchQuery
+	lastPrefix string
+}
+
+func newMemoryCatalogSearch() *memoryCatalogSearch {
+	return &memoryCatalogSearch{
+		texts:      map[string]string{},
+		embeddings: map[string][]float32{},
+		hashes:     map[string]string{},
+	}
+}
+
+func (m *memoryCatalogSearch) add(itemType, id, name, text string) {
+	m.entries = append(m.entries, &types.SearchResult{Type: itemType, ID: id, Name: name})
+	m.texts[id] = text
+}
+
+func (m *memoryCatalogSearch) Search(orgID string, query *types.SearchQuery, prefix string) ([]*types.SearchResult, int, error) {
+	m.lastQuery, m.lastPrefix = query, prefix
+	var matches []*types.SearchResult
+	for _, entry := range m.entries {
+		for _, word := range strings.Fields(strings.ToLower(query.Query)) {
+			if strings.Contains(m.texts[entry.ID], word) {
+				matches = append(matches, entry)
+				break
+			}
+		}
+	}
+	total := len(matches)
+	if query.Offset >= len(matches) {
+		return []*types.SearchResult{}, total, nil
+	}
+	return matches[query.Offset:min(query.Offset+query.Limit, len(matches))], total, nil
+}
+
+func (m *memoryCatalogSearch) SemanticSearch(orgID string, query *types.SearchQuery, model string, vector []float32, limit int) ([]*types.SearchResult, error) {
+	var results []*types.SearchResult
+	for _, entry := range m.entries {
+		if embedding, ok := m.embeddings[entry.ID]; ok {
+			result := *entry
+			result.Score = models.CosineSimilarity(vector, embedding)
+			results = append(results, &result)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results[:min(limit, len(results))], nil
+}
+
+func (m *memoryCatalogSearch) ListEmbeddingCandidates(model string, limit int) ([]*types.SearchDocument, error) {
+	var documents []*types.SearchDocument
+	for _, entry := range m.entries {
+		if m.hashes[entry.ID] != m.texts[entry.ID] && len(documents) < limit {
+			documents = append(documents, &types.SearchDocument{
+				Type: entry.Type, ID: entry.ID, Text: m.texts[entry.ID], ContentHash: m.texts[entry.ID],
+			})
+		}
+	}
+	return documents, nil
+}
+
+func (m *memoryCatalogSearch) UpsertEmbedding(document *types.SearchDocument, model string, embedding []float32) error {
+	m.embeddings[document.ID] = embedding
+	m.hashes[document.ID] = document.ContentHash
+	return nil
+}
+
+// fakeEmbedder embeds text by which topics it mentions
+type fakeEmbedder struct {
+	calls int
+	fail  bool
+}
+
+func (e *fakeEmbedder) Model() string { return "fake-embedding" }
+
+func (e *fakeEmbedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	e.calls++
+	if e.fail {
+		return nil, errors.New("embeddings API unavailable")
+	}
+	embeddings := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		embedding := make([]float32, 3)
+		for topic, words := range [][]string{{"file", "disk", "directory"}, {"weather", "forecast"}, {"email", "mail"}} {
+			for _, word := range words {
+				if strings.Contains(strings.ToLower(input), word) {
+					embedding[topic]++
+				}
+			}
+		}
+		embeddings[i] = embedding
+	}
+	return embeddings, nil
+}
+
+func TestSearchNormalizesQuery(t *testing.T) {
+	store := newMemoryCatalogSearch()
+	searchService := services.NewSearchServiceWithStore(store, nil)
+
+	response, err := searchService.Search(context.Background(), "org-1", &types.SearchQuery{
+		Query: "  Read fil ",
+		Types: []string{"tools,Prompts", "tool"},
+		Tags:  []string{"fs, local"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.SearchModeFullText, response.Mode)
+	assert.Equal(t, "Read fil", store.lastQuery.Query)
+	assert.Equal(t, []string{types.SearchTypeTool, types.SearchTypePrompt}, store.lastQuery.Types)
+	assert.Equal(t, []string{"fs", "local"}, store.lastQuery.Tags)
+	assert.Equal(t, 20, store.lastQuery.Limit)
+	assert.Equal(t, "read:* & fil:*", store.lastPrefix)
+
+	for _, query := range []*types.SearchQuery{
+		{Query: "?!"},
+		{Query: "file", Types: []string{"servers"}},
+		{Query: "file", ServerID: "not-a-uuid"},
+		{Query: "file", Mode: types.SearchModeSemantic},
+		{Query: "file", Mode: "fuzzy"},
+	} {
+		_, err := searchService.Search(context.Background(), "org-1", query)
+		assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "query %+v", query)
+	}
+}
+
+func TestEmbedPendingEmbedsNewAndChangedEntries(t *testing.T) {
+	store := newMemoryCatalogSearch()
+	store.add(types.SearchTypeTool, "read", "read_file", "read a file from disk")
+	store.add(types.SearchTypeTool, "weather", "get_weather", "weather forecast")
+	embedder := &fakeEmbedder{}
+	searchService := services.NewSearchServiceWithStore(store, embedder)
+
+	embedded, err := searchService.EmbedPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, embedded)
+	assert.Equal(t, []float32{2, 0, 0}, store.embeddings["read"])
+
+	embedded, err = searchService.EmbedPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, embedded)
+	assert.Equal(t, 1, embedder.calls, "unchanged entries are not embedded again")
+
+	store.texts["weather"] = "weather forecast by email"
+	embedded, err = searchService.EmbedPending(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, embedded)
+	assert.Equal(t, []float32{0, 2, 2}, store.embeddings["weather"])
+
+	_, err = services.NewSearchServiceWithStore(store, nil).EmbedPending(context.Background(), 10)
+	assert.NoError(t, err, "without an embedder there is nothing to embed")
+}
+
+func TestHybridSearchFusesRankings(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryCatalogSearch()
+	store.add(types.SearchTypeTool, "list", "list_directory", "list directory entries")
+	store.add(types.SearchTypeTool, "read", "read_file", "read a file from disk")
+	store.add(types.SearchTypePrompt, "summarize", "summarize", "summarize a file")
+	store.add(types.SearchTypeTool, "mail", "send_mail", "send an email")
+	embedder := &fakeEmbedder{}
+	searchService := services.NewSearchServiceWithStore(store, embedder)
+	_, err := searchService.EmbedPending(ctx, 10)
+	require.NoError(t, err)
+
+	response, err := searchService.Search(ctx, "org-1", &types.SearchQuery{Query: "file"})
+	require.NoError(t, err)
+	assert.Equal(t, types.SearchModeHybrid, response.Mode)
+	require.NotEmpty(t, response.Results)
+	assert.Equal(t, "read", response.Results[0].ID, "ranked high by both searches")
+	assert.Equal(t, "mail", response.Results[len(response.Results)-1].ID)
+
+	semantic, err := searchService.Search(ctx, "org-1", &types.SearchQuery{
+		Query: "directory", Mode: types.SearchModeSemantic, Limit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, semantic.Results, 2)
+	assert.ElementsMatch(t, []string{"list", "read"}, []string{semantic.Results[0].ID, semantic.Results[1].ID},
+		"only the file system entries are about directories")
+
+	embedder.fail = true
+	fallback, err := searchService.Search(ctx, "org-1", &types.SearchQuery{Query: "file"})
+	require.NoError(t, err)
+	assert.Equal(t, types.SearchModeFullText, fallback.Mode)
+	assert.Equal(t, 2, fallback.Total)
+}
+
+// blockingEgress blocks every external call
+type blockingEgress struct{}
+
+func (blockingEgress) CheckURL(feature, rawURL string) error {
+	return errors.New(feature + " is blocked in offline mode")
+}
+
+func TestHTTPEmbedder(t *testing.T) {
+	var authorization string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Model != "text-embedding-3-small" {
+			http.Error(w, "unknown model", http.StatusBadRequest)
+			return
+		}
+		// Answer out of order; embeddings are matched by index
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]interface{}{
+			{"index": 1, "embedding": []float32{0, 1}},
+			{"index": 0, "embedding": []float32{1, 0}},
+		}})
+	}))
+	defer api.Close()
+
+	embedder, err := services.NewHTTPEmbedder(api.URL, "text-embedding-3-small", "sk-test", nil)
+	require.NoError(t, err)
+	embeddings, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, embeddings)
+	assert.Equal(t, "Bearer sk-test", authorization)
+
+	unknown, err := services.NewHTTPEmbedder(api.URL, "other", "", nil)
+	require.NoError(t, err)
+	_, err = unknown.Embed(context.Background(), []string{"a", "b"})
+	assert.ErrorContains(t, err, "400")
+
+	_, err = services.NewHTTPEmbedder(api.URL, "text-embedding-3-small", "", blockingEgress{})
+	assert.ErrorContains(t, err, types.ConnectivityFeatureSearchEmbeddings)
+}
+
+func TestSearchHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newMemoryCatalogSearch()
+	store.add(types.SearchTypeResource, "readme", "README", "project readme file")
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("organization_id", "org-1")
+		c.Next()
+	})
+	router.GET("/api/search", handlers.NewSearchHandler(services.NewSearchServiceWithStore(store, nil)).Search)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/search", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/search?q=readme&limit=500", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/search?q=readme&types=resources", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data types.SearchResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 1, body.Data.Total)
+	require.Len(t, body.Data.Results, 1)
+	assert.Equal(t, "README", body.Data.Results[0].Name)
+	assert.Equal(t, []string{types.SearchTypeResource}, store.lastQuery.Types)
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, models.CosineSimilarity([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0.0, models.CosineSimilarity([]float32{1, 0}, []float32{0, 3}), 1e-9)
+	assert.Equal(t, 0.0, models.CosineSimilarity([]float32{0, 0}, []float32{1, 1}))
+}
@@ -0,0 +1,134 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/clientcompat"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectClient(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		name      string
+		version   string
+	}{
+		{"Claude-User/1.2.3 (claude-desktop)", types.ClientClaudeDesktop, "1.2.3"},
+		{"Mozilla/5.0 Cursor/0.45.2 Chrome/128.0 Electron/32.2.6 Code/1.93", types.ClientCursor, "0.45.2"},
+		{"Mozilla/5.0 Code/1.95.0 Visual Studio Code", types.ClientVSCode, ""},
+		{"mcp-inspector/0.14.0", types.ClientInspector, "0.14.0"},
+		{"python-httpx/0.27.0", types.ClientPythonSDK, "0.27.0"},
+		{"node-fetch", types.ClientTypeScriptSDK, ""},
+		{"curl/8.5.0", types.ClientUnknown, ""},
+		{"", types.ClientUnknown, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userAgent, func(t *testing.T) {
+			client, _ := clientcompat.Detect(tt.userAgent)
+			assert.Equal(t, tt.name, client.Name)
+			assert.Equal(t, tt.version, client.Version)
+			assert.Contains(t, client.Shims, clientcompat.ShimSessionHeaderAlias)
+		})
+	}
+
+	_, shims := clientcompat.Detect("Cursor/0.45.2")
+	assert.True(t, shims.LowercaseSessionHeader)
+	assert.Positive(t, shims.SSERetry)
+}
+
+func newClientCompatRouter(applyShims bool, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ClientCompat(applyShims))
+	router.Any("/mcp", handler)
+	return router
+}
+
+func TestClientCompatAliasesSessionHeaders(t *testing.T) {
+	var seen string
+	router := newClientCompatRouter(true, func(c *gin.Context) {
+		seen = c.GetHeader("X-Session-ID")
+		c.Header("X-Session-ID", "session-out")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", http.NoBody)
+	req.Header.Set("User-Agent", "python-httpx/0.27.0")
+	req.Header.Set("mcp-session-id", "session-in")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "session-in", seen)
+	assert.Equal(t, "session-out", w.Header().Get("Mcp-Session-Id"))
+	assert.Equal(t, "session-out", w.Header().Get("X-Session-ID"))
+}
+
+func TestClientCompatLowercaseHeaderAndSSERetry(t *testing.T) {
+	router := newClientCompatRouter(true, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Mcp-Session-Id", "session-1")
+		c.Writer.WriteString("event: connected\ndata: {}\n\n")
+		c.Writer.Flush()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp", http.NoBody)
+	req.Header.Set("User-Agent", "Cursor/0.45.2")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, []string{"session-1"}, w.Header()["mcp-session-id"])
+	assert.Empty(t, w.Header()["Mcp-Session-Id"])
+	assert.True(t, strings.HasPrefix(w.Body.String(), "retry: 1000\n\nevent: connected"), w.Body.String())
+}
+
+func TestClientCompatDetectsWithoutShimsWhenDisabled(t *testing.T) {
+	var detected *types.DetectedClient
+	router := newClientCompatRouter(false, func(c *gin.Context) {
+		detected = middleware.DetectedClientFromContext(c)
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("X-Session-ID", "session-1")
+		c.Writer.WriteString("data: {}\n\n")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp", http.NoBody)
+	req.Header.Set("User-Agent", "Cursor/0.45.2")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.NotNil(t, detected)
+	assert.Equal(t, types.ClientCursor, detected.Name)
+	assert.Empty(t, detected.Shims)
+	assert.Empty(t, w.Header().Get("Mcp-Session-Id"))
+	assert.Equal(t, "data: {}\n\n", w.Body.String())
+}
+
+func TestSummarizeByClient(t *testing.T) {
+	entries := []*logging.LogEntry{
+		{Logger: logging.LoggerRequest, StatusCode: 200, Data: map[string]interface{}{"client": types.ClientCursor, "client_version": "0.45.2"}},
+		{Logger: logging.LoggerRequest, StatusCode: 500, Data: map[string]interface{}{"client": types.ClientCursor, "client_version": "0.46.0"}},
+		{Logger: logging.LoggerRequest, StatusCode: 200, Data: map[string]interface{}{"client": types.ClientClaudeDesktop}},
+		{Logger: logging.LoggerRequest, StatusCode: 200, Data: map[string]interface{}{}},
+		{Logger: logging.LoggerRequest, StatusCode: 200, Data: map[string]interface{}{"client": types.ClientCursor, "sandbox": true}},
+		{Logger: logging.LoggerToolExecution, Data: map[string]interface{}{"success": true}},
+	}
+
+	usage := logging.SummarizeByClient(entries)
+	require.Len(t, usage, 3)
+	assert.Equal(t, types.ClientCursor, usage[0].Client)
+	assert.Equal(t, int64(2), usage[0].Requests)
+	assert.Equal(t, int64(1), usage[0].Errors)
+	assert.Equal(t, map[string]int64{"0.45.2": 1, "0.46.0": 1}, usage[0].Versions)
+
+	clients := []string{usage[1].Client, usage[2].Client}
+	assert.ElementsMatch(t, []string{types.ClientClaudeDesktop, types.ClientUnknown}, clients)
+}
@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+)
+
+func TestConfigureClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	require.NoError(t, middleware.ConfigureClientIP(router, []string{"10.0.0.0/8"}, nil))
+	router.GET("/ip", func(c *gin.Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+
+	clientIP := func(remoteAddr string, headers map[string]string) string {
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = remoteAddr
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "203.0.113.9", clientIP("203.0.113.9:4000", map[string]string{"X-Forwarded-For": "198.51.100.1"}),
+		"headers from untrusted peers are ignored")
+	assert.Equal(t, "198.51.100.1", clientIP("10.0.0.2:4000", map[string]string{"X-Forwarded-For": "198.51.100.1"}))
+	assert.Equal(t, "198.51.100.1", clientIP("10.0.0.2:4000", map[string]string{"X-Forwarded-For": "6.6.6.6, 198.51.100.1, 10.0.0.7"}),
+		"addresses prepended by the client are skipped")
+	assert.Equal(t, "198.51.100.2", clientIP("10.0.0.2:4000", map[string]string{"X-Real-IP": "198.51.100.2"}))
+
+	defaults := gin.New()
+	require.NoError(t, middleware.ConfigureClientIP(defaults, nil, []string{"CF-Connecting-IP"}))
+	assert.Equal(t, []string{"CF-Connecting-IP"}, defaults.RemoteIPHeaders)
+}
+
+func TestServerConfigTrustedProxies(t *testing.T) {
+	cfg := &config.ServerConfig{Port: 8080, TrustedProxies: []string{"10.0.0.0/8", "::1"}}
+	assert.NoError(t, cfg.Validate())
+	cfg.TrustedProxies = []string{"load-balancer"}
+	assert.Error(t, cfg.Validate())
+}
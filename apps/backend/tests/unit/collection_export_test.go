@@ -0,0 +1,65 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+func exportCollection(t *testing.T, endpoint *types.Endpoint, format string) map[string]interface{} {
+	spec := services.NewOpenAPIGenerator("https://gateway.example.com").GenerateSpec(endpoint, &types.Namespace{}, []types.NamespaceTool{searchTool()})
+	encoded, err := services.NewCollectionExporter().Export(spec, endpoint, format)
+	require.NoError(t, err)
+	var collection map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &collection))
+	return collection
+}
+
+func TestPostmanCollectionExport(t *testing.T) {
+	endpoint := &types.Endpoint{ID: "ep-1", Name: "docs", EnableAPIKeyAuth: true}
+	collection := exportCollection(t, endpoint, services.CollectionFormatPostman)
+
+	auth := collection["auth"].(map[string]interface{})
+	assert.Equal(t, "apikey", auth["type"])
+	assert.Contains(t, auth["apikey"], map[string]interface{}{"key": "value", "value": "{{apiKey}}", "type": "string"})
+	assert.Contains(t, collection["variable"], map[string]interface{}{
+		"key": "baseUrl", "value": "https://gateway.example.com/api/public/endpoints/docs/api",
+	})
+
+	items := collection["item"].([]interface{})
+	require.Len(t, items, 2, "the tools listing and a call per tool")
+	search := items[1].(map[string]interface{})["request"].(map[string]interface{})
+	assert.Equal(t, "POST", search["method"])
+	assert.Equal(t, "{{baseUrl}}/tools/search", search["url"].(map[string]interface{})["raw"])
+	assert.JSONEq(t, `{"query":"gateway docs","limit":1,"mode":"fast","tags":["string"]}`,
+		search["body"].(map[string]interface{})["raw"].(string))
+
+	again := exportCollection(t, endpoint, services.CollectionFormatPostman)
+	assert.Equal(t, collection["info"], again["info"], "re-exports keep their ID")
+
+	public := exportCollection(t, &types.Endpoint{ID: "ep-2", Name: "open", EnablePublicAccess: true}, services.CollectionFormatPostman)
+	assert.Equal(t, "noauth", public["auth"].(map[string]interface{})["type"])
+}
+
+func TestInsomniaCollectionExport(t *testing.T) {
+	collection := exportCollection(t, &types.Endpoint{ID: "ep-1", Name: "docs", EnableOAuth: true}, services.CollectionFormatInsomnia)
+	assert.Equal(t, "export", collection["_type"])
+
+	resources := collection["resources"].([]interface{})
+	require.Len(t, resources, 4)
+	environment := resources[1].(map[string]interface{})
+	assert.Equal(t, "environment", environment["_type"])
+	assert.Contains(t, environment["data"], "accessToken")
+
+	request := resources[3].(map[string]interface{})
+	assert.Equal(t, "{{ _.baseUrl }}/tools/search", request["url"])
+	assert.Equal(t, map[string]interface{}{"type": "bearer", "token": "{{ _.accessToken }}"}, request["authentication"])
+
+	_, err := services.NewCollectionExporter().Export(&services.OpenAPISpec{}, &types.Endpoint{}, "curl")
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+}
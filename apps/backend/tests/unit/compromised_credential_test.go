@@ -0,0 +1,222 @@
+package unit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const (
+	leakedAPIKey     = "mgw_0123456789abcdef"
+	leakedAPIKeyID   = "a0000000-0000-0000-0000-000000000001"
+	leakedOAuthToken = "oauth-access-token"
+	threatFeedSecret = "threat-feed-secret-at-least-32-characters"
+)
+
+type leakableCredential struct {
+	credential types.CompromisedCredential
+	active     bool
+}
+
+// memoryCompromisedCredentials keeps API keys and OAuth tokens by the
+// digests their tables store
+type memoryCompromisedCredentials struct {
+	apiKeys  map[string]*leakableCredential
+	oauth    map[string]*leakableCredential
+	recorded []*types.CompromisedCredential
+}
+
+func fingerprintOf(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func newMemoryCompromisedCredentials() *memoryCompromisedCredentials {
+	oauthDigest := sha256.Sum256([]byte(leakedOAuthToken))
+	return &memoryCompromisedCredentials{
+		apiKeys: map[string]*leakableCredential{
+			fingerprintOf(leakedAPIKey): {active: true, credential: types.CompromisedCredential{
+				CredentialType: types.CompromisedAPIKey, CredentialID: leakedAPIKeyID,
+				UserID: grantedUserID, OrganizationID: grantOrgID, CredentialName: "ci",
+			}},
+		},
+		oauth: map[string]*leakableCredential{
+			base64.URLEncoding.EncodeToString(oauthDigest[:]): {active: true, credential: types.CompromisedCredential{
+				CredentialType: types.CompromisedOAuthToken, CredentialID: "token-1",
+				OrganizationID: "org-2", CredentialName: "agent",
+			}},
+		},
+	}
+}
+
+func revokeLeakable(entry *leakableCredential, orgID string) *types.CompromisedCredential {
+	if entry == nil || !entry.active || (orgID != "" && entry.credential.OrganizationID != orgID) {
+		return nil
+	}
+	entry.active = false
+	credential := entry.credential
+	return &credential
+}
+
+func (m *memoryCompromisedCredentials) RevokeAPIKeyByHash(orgID, keyHash string) (*types.CompromisedCredential, error) {
+	return revokeLeakable(m.apiKeys[keyHash], orgID), nil
+}
+
+func (m *memoryCompromisedCredentials) RevokeAPIKeyByID(orgID, id string) (*types.CompromisedCredential, error) {
+	for _, entry := range m.apiKeys {
+		if entry.credential.CredentialID == id {
+			return revokeLeakable(entry, orgID), nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryCompromisedCredentials) RevokeOAuthToken(orgID, tokenHash string) (*types.CompromisedCredential, error) {
+	return revokeLeakable(m.oauth[tokenHash], orgID), nil
+}
+
+func (m *memoryCompromisedCredentials) Create(credential *types.CompromisedCredential) error {
+	credential.ID = "report-" + credential.CredentialID
+	credential.CreatedAt = time.Now()
+	m.recorded = append(m.recorded, credential)
+	return nil
+}
+
+func (m *memoryCompromisedCredentials) List(orgID string) ([]*types.CompromisedCredential, error) {
+	var credentials []*types.CompromisedCredential
+	for _, credential := range m.recorded {
+		if credential.OrganizationID == orgID {
+			credentials = append(credentials, credential)
+		}
+	}
+	return credentials, nil
+}
+
+func newCompromisedService(tokens services.CompromisedTokenRevoker) (*services.CompromisedCredentialService, *memoryCompromisedCredentials, *recordingGrantNotifier, *recordingGrantAuditor) {
+	store := newMemoryCompromisedCredentials()
+	notifier := &recordingGrantNotifier{}
+	auditor := &recordingGrantAuditor{}
+	svc := services.NewCompromisedCredentialServiceWithStore(store, tokens, threatFeedSecret)
+	svc.SetNotifier(notifier)
+	svc.SetAuditor(auditor)
+	return svc, store, notifier, auditor
+}
+
+func TestCompromisedAPIKeyRevokedByAdmin(t *testing.T) {
+	svc, store, notifier, auditor := newCompromisedService(nil)
+	ctx := context.Background()
+
+	_, err := svc.Report(ctx, grantOrgID, otherUserID, &types.ReportCompromisedCredentialRequest{})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	_, err = svc.Report(ctx, grantOrgID, otherUserID, &types.ReportCompromisedCredentialRequest{Token: leakedAPIKey, APIKeyID: leakedAPIKeyID})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	// Admins of another organization cannot revoke the key
+	result, err := svc.Report(ctx, "org-2", foreignUserID, &types.ReportCompromisedCredentialRequest{APIKeyID: leakedAPIKeyID})
+	require.NoError(t, err)
+	assert.Empty(t, result.Revoked)
+	assert.Equal(t, 1, result.Unmatched)
+
+	result, err = svc.Report(ctx, grantOrgID, otherUserID, &types.ReportCompromisedCredentialRequest{APIKeyID: leakedAPIKeyID, Reason: "pasted in a public gist"})
+	require.NoError(t, err)
+	require.Len(t, result.Revoked, 1)
+	revoked := result.Revoked[0]
+	assert.Equal(t, types.CompromisedAPIKey, revoked.CredentialType)
+	assert.Equal(t, types.CompromiseSourceAdmin, revoked.Source)
+	assert.Equal(t, otherUserID, revoked.Reporter)
+	assert.Equal(t, "pasted in a public gist", revoked.Reason)
+	assert.False(t, store.apiKeys[fingerprintOf(leakedAPIKey)].active)
+
+	require.Len(t, notifier.personal[grantedUserID], 1, "the key's owner is told")
+	assert.Equal(t, types.NotificationCredentialCompromised, notifier.personal[grantedUserID][0].Type)
+	assert.Empty(t, notifier.events)
+	require.Len(t, auditor.logs, 1)
+	assert.Equal(t, "compromised_credential", auditor.logs[0].Resource)
+
+	listed, err := svc.List(ctx, grantOrgID)
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+
+	// Reporting it again changes nothing
+	result, err = svc.Report(ctx, grantOrgID, otherUserID, &types.ReportCompromisedCredentialRequest{Token: leakedAPIKey})
+	require.NoError(t, err)
+	assert.Empty(t, result.Revoked)
+	assert.Len(t, notifier.personal[grantedUserID], 1)
+}
+
+func TestCompromisedLoginTokenBlacklisted(t *testing.T) {
+	jwt := auth.NewJWTManager("compromised-token-test-secret-32-chars", 15*time.Minute, time.Hour)
+	svc, _, notifier, _ := newCompromisedService(jwt)
+	ctx := context.Background()
+
+	token, err := jwt.GenerateAccessToken(&types.User{ID: grantedUserID, OrganizationID: grantOrgID, Role: types.RoleUser})
+	require.NoError(t, err)
+	_, err = jwt.ValidateToken(token)
+	require.NoError(t, err)
+
+	result, err := svc.Report(ctx, "org-2", foreignUserID, &types.ReportCompromisedCredentialRequest{Token: token})
+	require.NoError(t, err)
+	assert.Empty(t, result.Revoked, "tokens of other organizations are left alone")
+
+	result, err = svc.Report(ctx, grantOrgID, otherUserID, &types.ReportCompromisedCredentialRequest{Token: token})
+	require.NoError(t, err)
+	require.Len(t, result.Revoked, 1)
+	assert.Equal(t, types.CompromisedJWT, result.Revoked[0].CredentialType)
+	assert.Equal(t, fingerprintOf(token), result.Revoked[0].Fingerprint)
+
+	_, err = jwt.ValidateToken(token)
+	assert.Error(t, err, "the token is blacklisted")
+	assert.Len(t, notifier.personal[grantedUserID], 1)
+}
+
+func TestThreatFeedReport(t *testing.T) {
+	svc, store, notifier, _ := newCompromisedService(nil)
+	ctx := context.Background()
+
+	body := []byte(`{"feed":"leakwatch"}`)
+	mac := hmac.New(sha256.New, []byte(threatFeedSecret))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+	assert.NoError(t, svc.VerifyFeedSignature(body, signature))
+	assert.NoError(t, svc.VerifyFeedSignature(body, "sha256="+signature))
+	assert.True(t, types.IsError(svc.VerifyFeedSignature(body, "deadbeef"), types.ErrCodeUnauthorized))
+	assert.True(t, types.IsError(svc.VerifyFeedSignature([]byte(`{"feed":"other"}`), signature), types.ErrCodeUnauthorized))
+
+	disabled := services.NewCompromisedCredentialServiceWithStore(store, nil, "")
+	assert.True(t, types.IsError(disabled.VerifyFeedSignature(body, signature), types.ErrCodeNotFound))
+
+	_, err := svc.ReportFromFeed(ctx, &types.ThreatFeedReport{Feed: "leakwatch", Credentials: []*types.ReportCompromisedCredentialRequest{
+		{APIKeyID: leakedAPIKeyID},
+	}})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	result, err := svc.ReportFromFeed(ctx, &types.ThreatFeedReport{Feed: "leakwatch", Credentials: []*types.ReportCompromisedCredentialRequest{
+		{Fingerprint: fingerprintOf(leakedAPIKey)},
+		{Token: leakedOAuthToken},
+		{Token: "never-issued"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, result.Revoked, 2, "feeds revoke credentials of every organization")
+	assert.Equal(t, 1, result.Unmatched)
+	for _, revoked := range result.Revoked {
+		assert.Equal(t, types.CompromiseSourceThreatFeed, revoked.Source)
+		assert.Equal(t, "leakwatch", revoked.Reporter)
+	}
+	assert.Equal(t, grantOrgID, result.Revoked[0].OrganizationID)
+	assert.Equal(t, "org-2", result.Revoked[1].OrganizationID)
+
+	assert.Len(t, notifier.personal[grantedUserID], 1)
+	require.Len(t, notifier.events, 1, "admins are told of client tokens without an owner")
+	assert.Equal(t, "org-2", notifier.events[0].OrganizationID)
+}
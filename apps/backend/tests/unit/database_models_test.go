@@ -2,16 +2,23 @@ package unit
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockDatabase is a mock implementation of models.Database
@@ -439,6 +446,51 @@ func TestMCPServerModel_ConcurrentOperations(t *testing.T) {
 	db.AssertExpectations(t)
 }
 
+// Log entries can be attributed to every principal type the gateway
+// resolves, including service accounts
+func TestLogIndexModel_PrincipalTypes(t *testing.T) {
+	principalTypes := []string{types.PrincipalTypeUser, types.PrincipalTypeAPIKey, types.PrincipalTypeOAuthClient,
+		types.PrincipalTypeAnonymous, types.PrincipalTypeServiceAccount}
+
+	// The newest migration constraining principal_type lists them all
+	files, err := filepath.Glob("../../migrations/*.up.sql")
+	require.NoError(t, err)
+	sort.Strings(files)
+	var allowed string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		if match := regexp.MustCompile(`principal_type IN \(([^)]*)\)`).FindSubmatch(content); match != nil {
+			allowed = string(match[1])
+		}
+	}
+	require.NotEmpty(t, allowed)
+	for _, principalType := range principalTypes {
+		assert.Contains(t, allowed, "'"+principalType+"'")
+	}
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	model := models.NewLogIndexModel(db)
+
+	args := make([]driver.Value, 21)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	args[17] = types.PrincipalTypeServiceAccount
+	mock.ExpectExec("INSERT INTO log_index").WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, model.Create(&models.LogIndex{
+		OrganizationID: uuid.New(),
+		RPCMethod:      sql.NullString{String: "tools/call", Valid: true},
+		Level:          "info",
+		StartedAt:      time.Now(),
+		PrincipalType:  sql.NullString{String: types.PrincipalTypeServiceAccount, Valid: true},
+	}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 // Benchmark model operations
 func BenchmarkMCPServer_MetadataAccess(b *testing.B) {
 	server := &models.MCPServer{
@@ -0,0 +1,156 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const delegatingAccountID = "sa-1"
+
+// memoryDelegation keeps delegation policies and the users of grantOrgID
+type memoryDelegation struct {
+	policies map[string]*types.DelegationPolicy
+	users    []*types.User
+}
+
+func newMemoryDelegation() *memoryDelegation {
+	return &memoryDelegation{
+		policies: map[string]*types.DelegationPolicy{},
+		users: []*types.User{
+			{ID: grantedUserID, Email: "ada@example.com", OrganizationID: grantOrgID, Role: types.RoleUser, IsActive: true},
+			{ID: otherUserID, Email: "root@example.com", OrganizationID: grantOrgID, Role: types.RoleAdmin, IsActive: true},
+			{ID: delegatingAccountID, OrganizationID: grantOrgID, Role: types.RoleUser, AccountType: types.AccountTypeService, IsActive: true},
+		},
+	}
+}
+
+func (m *memoryDelegation) Get(orgID, serviceAccountID string) (*types.DelegationPolicy, error) {
+	policy := m.policies[serviceAccountID]
+	if policy == nil || policy.OrganizationID != orgID {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+func (m *memoryDelegation) Set(policy *types.DelegationPolicy) (bool, error) {
+	if policy.ServiceAccountID != delegatingAccountID || policy.OrganizationID != grantOrgID {
+		return false, nil
+	}
+	m.policies[policy.ServiceAccountID] = policy
+	return true, nil
+}
+
+func (m *memoryDelegation) Delete(orgID, serviceAccountID string) (bool, error) {
+	if _, err := m.Get(orgID, serviceAccountID); err != nil || m.policies[serviceAccountID] == nil {
+		return false, err
+	}
+	delete(m.policies, serviceAccountID)
+	return true, nil
+}
+
+func (m *memoryDelegation) FindUser(orgID, subject string) (*types.User, error) {
+	for _, user := range m.users {
+		if user.OrganizationID == orgID && (user.ID == subject || strings.EqualFold(user.Email, subject)) {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestDelegationPolicyResolve(t *testing.T) {
+	svc := services.NewDelegationServiceWithStore(newMemoryDelegation())
+	ctx := context.Background()
+
+	_, err := svc.Resolve(ctx, grantOrgID, delegatingAccountID, grantedUserID)
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied), "accounts without a policy act for no one")
+
+	_, err = svc.SetPolicy(ctx, "org-2", delegatingAccountID, foreignUserID, &types.SetDelegationPolicyRequest{})
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+	policy, err := svc.SetPolicy(ctx, grantOrgID, delegatingAccountID, otherUserID, &types.SetDelegationPolicyRequest{
+		AllowedRoles: []string{types.RoleUser, types.RoleViewer},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, policy.AllowedUserIDs)
+
+	user, err := svc.Resolve(ctx, grantOrgID, delegatingAccountID, "ADA@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, grantedUserID, user.ID)
+
+	_, err = svc.Resolve(ctx, grantOrgID, delegatingAccountID, otherUserID)
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied), "admins are outside the allowed roles")
+	_, err = svc.Resolve(ctx, grantOrgID, delegatingAccountID, delegatingAccountID)
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied), "service accounts cannot be impersonated")
+	_, err = svc.Resolve(ctx, grantOrgID, delegatingAccountID, foreignUserID)
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied))
+
+	_, err = svc.SetPolicy(ctx, grantOrgID, delegatingAccountID, otherUserID, &types.SetDelegationPolicyRequest{
+		AllowedUserIDs: []string{otherUserID},
+	})
+	require.NoError(t, err)
+	_, err = svc.Resolve(ctx, grantOrgID, delegatingAccountID, grantedUserID)
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied), "only listed users may be acted for")
+	_, err = svc.Resolve(ctx, grantOrgID, delegatingAccountID, otherUserID)
+	assert.NoError(t, err)
+
+	require.NoError(t, svc.DeletePolicy(ctx, grantOrgID, delegatingAccountID))
+	assert.True(t, types.IsError(svc.DeletePolicy(ctx, grantOrgID, delegatingAccountID), types.ErrCodeNotFound))
+}
+
+func TestOnBehalfOfMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newMemoryDelegation()
+	svc := services.NewDelegationServiceWithStore(store)
+	_, err := svc.SetPolicy(context.Background(), grantOrgID, delegatingAccountID, otherUserID, &types.SetDelegationPolicyRequest{
+		AllowedRoles: []string{types.RoleUser},
+	})
+	require.NoError(t, err)
+
+	var seen *types.Principal
+	var seenUser, seenRole string
+	call := func(principalType, onBehalfOf string) int {
+		router := gin.New()
+		router.POST("/mcp", func(c *gin.Context) {
+			c.Set("user_id", delegatingAccountID)
+			c.Set("role", types.RoleViewer)
+			c.Set("principal", &types.Principal{Type: principalType, UserID: delegatingAccountID, APIKeyID: "key-1", OrganizationID: grantOrgID})
+			c.Next()
+		}, middleware.OnBehalfOfMiddleware(svc), func(c *gin.Context) {
+			value, _ := c.Get("principal")
+			seen, _ = value.(*types.Principal)
+			seenUser, seenRole = c.GetString("user_id"), c.GetString("role")
+			c.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if onBehalfOf != "" {
+			req.Header.Set(types.OnBehalfOfHeader, onBehalfOf)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, call(types.PrincipalTypeServiceAccount, ""))
+	assert.Empty(t, seen.OnBehalfOf)
+	assert.Equal(t, delegatingAccountID, seenUser)
+
+	require.Equal(t, http.StatusOK, call(types.PrincipalTypeServiceAccount, grantedUserID))
+	assert.Equal(t, grantedUserID, seenUser, "authorization uses the effective user")
+	assert.Equal(t, types.RoleUser, seenRole)
+	assert.Equal(t, grantedUserID, seen.OnBehalfOf)
+	assert.Equal(t, grantedUserID, seen.EffectiveUserID())
+	assert.Equal(t, delegatingAccountID, seen.ID(), "usage stays with the service account")
+
+	assert.Equal(t, http.StatusForbidden, call(types.PrincipalTypeServiceAccount, otherUserID))
+	assert.Equal(t, http.StatusForbidden, call(types.PrincipalTypeAPIKey, grantedUserID), "only service accounts may delegate")
+}
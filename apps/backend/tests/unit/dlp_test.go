@@ -0,0 +1,188 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/dlp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryDLP keeps DLP policies and events in memory
+type memoryDLP struct {
+	servers  map[string]string
+	policies map[string]*types.DLPPolicy
+	events   []*types.DLPEvent
+}
+
+func newMemoryDLP() *memoryDLP {
+	return &memoryDLP{servers: map[string]string{}, policies: map[string]*types.DLPPolicy{}}
+}
+
+func (m *memoryDLP) ServerExists(orgID, serverID string) (bool, error) {
+	return m.servers[serverID] == orgID, nil
+}
+
+func (m *memoryDLP) GetPolicy(serverID string) (*types.DLPPolicy, error) {
+	return m.policies[serverID], nil
+}
+
+func (m *memoryDLP) UpsertPolicy(policy *types.DLPPolicy) error {
+	m.policies[policy.ServerID] = policy
+	return nil
+}
+
+func (m *memoryDLP) DeletePolicy(serverID string) (bool, error) {
+	_, ok := m.policies[serverID]
+	delete(m.policies, serverID)
+	return ok, nil
+}
+
+func (m *memoryDLP) CreateEvent(event *types.DLPEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *memoryDLP) ListEvents(orgID, serverID string, limit int) ([]*types.DLPEvent, error) {
+	return m.events, nil
+}
+
+func TestDLPBuiltinClassifiers(t *testing.T) {
+	scanner := dlp.New(dlp.Builtin())
+	findings, err := scanner.Scan(context.Background(), map[string]interface{}{
+		"note":   "mail jane@example.com from 10.0.0.7",
+		"card":   "4111 1111 1111 1111",
+		"order":  "4111 1111 1111 1112",
+		"ssn":    "123-45-6789",
+		"iban":   "GB82 WEST 1234 5698 7654 32",
+		"config": map[string]interface{}{"env": []interface{}{"API_TOKEN=abcdef123456"}},
+		"count":  42,
+	})
+	require.NoError(t, err)
+
+	classifiers := map[string]string{}
+	for _, finding := range findings {
+		classifiers[finding.Path+" "+finding.Classifier] = finding.Sensitivity
+	}
+	assert.Equal(t, map[string]string{
+		"note email_address":       types.DLPSensitivityConfidential,
+		"note ip_address":          types.DLPSensitivityInternal,
+		"card credit_card_number":  types.DLPSensitivityRestricted,
+		"ssn us_ssn":               types.DLPSensitivityRestricted,
+		"iban iban":                types.DLPSensitivityRestricted,
+		"config.env[0] credential": types.DLPSensitivityRestricted,
+	}, classifiers)
+}
+
+func TestDLPRedactLeavesArgumentsUnchanged(t *testing.T) {
+	args := map[string]interface{}{
+		"query": "contact jane@example.com or 10.0.0.7",
+		"rows":  []interface{}{map[string]interface{}{"ssn": "123-45-6789"}},
+	}
+	findings, err := dlp.New(dlp.Builtin()).Scan(context.Background(), args)
+	require.NoError(t, err)
+
+	redacted := dlp.Redact(args, dlp.Above(findings, types.DLPSensitivityInternal))
+	assert.Equal(t, "contact [REDACTED] or 10.0.0.7", redacted["query"])
+	assert.Equal(t, "[REDACTED]", redacted["rows"].([]interface{})[0].(map[string]interface{})["ssn"])
+	assert.Equal(t, "123-45-6789", args["rows"].([]interface{})[0].(map[string]interface{})["ssn"])
+}
+
+func TestDLPServiceAppliesServerLabel(t *testing.T) {
+	store := newMemoryDLP()
+	serverID := uuid.New().String()
+	store.servers[serverID] = "org-1"
+	svc := services.NewDLPServiceWithStore(store, dlp.New(dlp.Builtin()), types.DLPSensitivityRestricted, types.DLPActionLog)
+	ctx := types.WithPrincipal(context.Background(), &types.Principal{UserID: "user-1"})
+	args := map[string]interface{}{"to": "jane@example.com", "host": "10.0.0.7"}
+
+	// Servers without a policy receive anything
+	sent, err := svc.CheckArguments(ctx, "org-1", "ns-1", serverID, "mail__send", args)
+	require.NoError(t, err)
+	assert.Equal(t, args, sent)
+	assert.Empty(t, store.events)
+
+	_, err = svc.SetPolicy(ctx, "org-1", serverID, &types.SetDLPPolicyRequest{Sensitivity: types.DLPSensitivityInternal, Action: types.DLPActionBlock})
+	require.NoError(t, err)
+	_, err = svc.CheckArguments(ctx, "org-1", "ns-1", serverID, "mail__send", args)
+	require.True(t, types.IsError(err, types.ErrCodeDLPViolation))
+	assert.Contains(t, err.Error(), "email_address")
+	require.Len(t, store.events, 1)
+	assert.Equal(t, []types.DLPFinding{{Path: "to", Classifier: "email_address", Sensitivity: types.DLPSensitivityConfidential}},
+		store.events[0].Findings)
+	assert.Equal(t, "user-1", store.events[0].UserID)
+
+	_, err = svc.SetPolicy(ctx, "org-1", serverID, &types.SetDLPPolicyRequest{Sensitivity: types.DLPSensitivityInternal, Action: types.DLPActionRedact})
+	require.NoError(t, err)
+	sent, err = svc.CheckArguments(ctx, "org-1", "ns-1", serverID, "mail__send", args)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"to": "[REDACTED]", "host": "10.0.0.7"}, sent)
+
+	_, err = svc.SetPolicy(ctx, "org-1", serverID, &types.SetDLPPolicyRequest{Sensitivity: types.DLPSensitivityPublic, Action: types.DLPActionLog})
+	require.NoError(t, err)
+	sent, err = svc.CheckArguments(ctx, "org-1", "ns-1", serverID, "mail__send", args)
+	require.NoError(t, err)
+	assert.Equal(t, args, sent)
+	require.Len(t, store.events, 3)
+	assert.Len(t, store.events[2].Findings, 2)
+
+	require.NoError(t, svc.DeletePolicy(ctx, "org-1", serverID))
+	policy, err := svc.GetPolicy(ctx, "org-1", serverID)
+	require.NoError(t, err)
+	assert.True(t, policy.Default)
+	_, err = svc.GetPolicy(ctx, "org-2", serverID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+}
+
+func TestDLPExternalClassifier(t *testing.T) {
+	var received struct {
+		Texts []string `json:"texts"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer dlp-key", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"findings": []map[string]interface{}{
+			{"index": 0, "classifier": "customer_id", "sensitivity": "confidential", "start": 9, "end": 16},
+			{"index": 0, "classifier": "bogus", "sensitivity": "top-secret", "start": 0, "end": 3},
+		}})
+	}))
+	defer server.Close()
+
+	scanner, err := services.NewDLPScanner(server.URL, "dlp-key", 0, false, nil)
+	require.NoError(t, err)
+	findings, err := scanner.Scan(context.Background(), map[string]interface{}{"id": "customer C-12345"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"customer C-12345"}, received.Texts)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "customer_id", findings[0].Classifier)
+	assert.Equal(t, "customer [REDACTED]", dlp.Redact(map[string]interface{}{"id": "customer C-12345"}, findings)["id"])
+}
+
+// failingClassifier stands in for an unreachable DLP API
+type failingClassifier struct{}
+
+func (failingClassifier) Classify(ctx context.Context, texts []string) ([][]dlp.Match, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestDLPFailOpenUsesOtherClassifiers(t *testing.T) {
+	args := map[string]interface{}{"to": "jane@example.com"}
+	_, err := dlp.New(dlp.Builtin(), failingClassifier{}).Scan(context.Background(), args)
+	assert.Error(t, err)
+
+	var reported error
+	findings, err := dlp.New(dlp.Builtin(), dlp.FailOpen(failingClassifier{}, func(err error) { reported = err })).
+		Scan(context.Background(), args)
+	require.NoError(t, err)
+	assert.Len(t, findings, 1)
+	assert.EqualError(t, reported, "connection refused")
+}
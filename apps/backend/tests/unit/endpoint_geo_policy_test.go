@@ -0,0 +1,398 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/geoip"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mmdbUint32 and mmdbUint16 mark integers for the MMDB test writer
+type mmdbUint32 uint32
+type mmdbUint16 uint16
+
+// encodeMMDB encodes a value of the MMDB data section
+func encodeMMDB(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		if len(v) < 29 {
+			buf.WriteByte(2<<5 | byte(len(v)))
+		} else {
+			buf.Write([]byte{2<<5 | 29, byte(len(v) - 29)})
+		}
+		buf.WriteString(v)
+	case mmdbUint16:
+		buf.WriteByte(5<<5 | 2)
+		_ = binary.Write(buf, binary.BigEndian, uint16(v))
+	case mmdbUint32:
+		buf.WriteByte(6<<5 | 4)
+		_ = binary.Write(buf, binary.BigEndian, uint32(v))
+	case map[string]interface{}:
+		buf.WriteByte(7<<5 | byte(len(v)))
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			encodeMMDB(buf, key)
+			encodeMMDB(buf, v[key])
+		}
+	default:
+		panic("unsupported MMDB test value")
+	}
+}
+
+// writeMMDB writes an MMDB file with 24-bit records mapping networks in
+// CIDR notation to records. IPv4 networks of IPv6 databases are stored
+// under ::/96.
+func writeMMDB(t *testing.T, ipVersion int, networks map[string]map[string]interface{}) string {
+	t.Helper()
+	const empty = -1
+	// Node records are node indexes, empty, or -2-i for the i-th record
+	nodes := [][2]int{{empty, empty}}
+	var data bytes.Buffer
+	var offsets []int
+
+	cidrs := make([]string, 0, len(networks))
+	for cidr := range networks {
+		cidrs = append(cidrs, cidr)
+	}
+	sort.Strings(cidrs)
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		ones, _ := network.Mask.Size()
+		address := []byte(network.IP)
+		if v4 := network.IP.To4(); v4 != nil {
+			address = v4
+			if ipVersion == 6 {
+				address = append(make([]byte, 12), v4...)
+				ones += 96
+			}
+		}
+
+		offsets = append(offsets, data.Len())
+		encodeMMDB(&data, networks[cidr])
+
+		node := 0
+		for bit := 0; bit < ones; bit++ {
+			side := int(address[bit/8]>>(7-bit%8)) & 1
+			if bit == ones-1 {
+				nodes[node][side] = -2 - i
+				break
+			}
+			if nodes[node][side] == empty {
+				nodes = append(nodes, [2]int{empty, empty})
+				nodes[node][side] = len(nodes) - 1
+			}
+			node = nodes[node][side]
+		}
+	}
+
+	var file bytes.Buffer
+	nodeCount := len(nodes)
+	for _, node := range nodes {
+		for _, record := range node {
+			value := nodeCount
+			switch {
+			case record >= 0:
+				value = record
+			case record < empty:
+				value = nodeCount + 16 + offsets[-2-record]
+			}
+			file.Write([]byte{byte(value >> 16), byte(value >> 8), byte(value)})
+		}
+	}
+	file.Write(make([]byte, 16))
+	file.Write(data.Bytes())
+	file.WriteString("\xab\xcd\xefMaxMind.com")
+	encodeMMDB(&file, map[string]interface{}{
+		"node_count":    mmdbUint32(nodeCount),
+		"record_size":   mmdbUint16(24),
+		"ip_version":    mmdbUint16(ipVersion),
+		"database_type": "Test",
+	})
+
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	require.NoError(t, os.WriteFile(path, file.Bytes(), 0o600))
+	return path
+}
+
+func testGeoResolver(t *testing.T) *geoip.Resolver {
+	t.Helper()
+	countries := writeMMDB(t, 6, map[string]map[string]interface{}{
+		"81.2.69.0/24":  {"country": map[string]interface{}{"iso_code": "GB"}},
+		"2001:db8::/32": {"country": map[string]interface{}{"iso_code": "DE"}},
+		"203.0.113.0/24": {
+			"registered_country": map[string]interface{}{"iso_code": "AU"},
+		},
+	})
+	asns := writeMMDB(t, 4, map[string]map[string]interface{}{
+		"81.2.69.0/24": {
+			"autonomous_system_number":       mmdbUint32(20712),
+			"autonomous_system_organization": "Andrews & Arnold Ltd",
+		},
+		"1.1.1.0/24": {
+			"autonomous_system_number":       mmdbUint32(13335),
+			"autonomous_system_organization": "Cloudflare",
+		},
+	})
+	resolver, err := geoip.OpenResolver(countries, "", asns)
+	require.NoError(t, err)
+	require.NotNil(t, resolver)
+	return resolver
+}
+
+func TestGeoIPResolverCombinesDatabases(t *testing.T) {
+	resolver := testGeoResolver(t)
+
+	info, err := resolver.Lookup("81.2.69.142")
+	require.NoError(t, err)
+	assert.Equal(t, &types.GeoIPInfo{IP: "81.2.69.142", Country: "GB", ASN: 20712, ASOrganization: "Andrews & Arnold Ltd"}, info)
+
+	info, err = resolver.Lookup("2001:db8::1")
+	require.NoError(t, err)
+	assert.Equal(t, &types.GeoIPInfo{IP: "2001:db8::1", Country: "DE"}, info)
+
+	// Anonymous networks fall back to the registered country
+	info, err = resolver.Lookup("203.0.113.9")
+	require.NoError(t, err)
+	assert.Equal(t, "AU", info.Country)
+
+	info, err = resolver.Lookup("10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, &types.GeoIPInfo{IP: "10.0.0.1"}, info)
+
+	_, err = resolver.Lookup("not-an-ip")
+	assert.Error(t, err)
+
+	resolver, err = geoip.OpenResolver("", "")
+	require.NoError(t, err)
+	assert.Nil(t, resolver)
+
+	_, err = geoip.OpenResolver(filepath.Join(t.TempDir(), "missing.mmdb"))
+	assert.Error(t, err)
+}
+
+// memoryGeoPolicies keeps endpoint geo policies and decisions in memory
+type memoryGeoPolicies struct {
+	endpoints map[string]string
+	policies  map[string]*types.EndpointGeoPolicy
+	decisions []*types.GeoDecisionEvent
+}
+
+func newMemoryGeoPolicies() *memoryGeoPolicies {
+	return &memoryGeoPolicies{endpoints: map[string]string{}, policies: map[string]*types.EndpointGeoPolicy{}}
+}
+
+func (m *memoryGeoPolicies) EndpointExists(orgID, endpointID string) (bool, error) {
+	return m.endpoints[endpointID] == orgID, nil
+}
+
+func (m *memoryGeoPolicies) GetPolicy(endpointID string) (*types.EndpointGeoPolicy, error) {
+	return m.policies[endpointID], nil
+}
+
+func (m *memoryGeoPolicies) UpsertPolicy(policy *types.EndpointGeoPolicy) error {
+	m.policies[policy.EndpointID] = policy
+	return nil
+}
+
+func (m *memoryGeoPolicies) DeletePolicy(endpointID string) (bool, error) {
+	_, ok := m.policies[endpointID]
+	delete(m.policies, endpointID)
+	return ok, nil
+}
+
+func (m *memoryGeoPolicies) CreateDecision(event *types.GeoDecisionEvent) error {
+	m.decisions = append(m.decisions, event)
+	return nil
+}
+
+func (m *memoryGeoPolicies) ListDecisions(endpointID string, deniedOnly bool, limit int) ([]*types.GeoDecisionEvent, error) {
+	var decisions []*types.GeoDecisionEvent
+	for _, decision := range m.decisions {
+		if decision.EndpointID == endpointID && (!deniedOnly || !decision.Allowed) {
+			decisions = append(decisions, decision)
+		}
+	}
+	return decisions, nil
+}
+
+func newGeoPolicyFixture(t *testing.T) (*services.EndpointGeoPolicyService, *memoryGeoPolicies, *types.Endpoint) {
+	t.Helper()
+	store := newMemoryGeoPolicies()
+	endpoint := &types.Endpoint{ID: uuid.NewString(), OrganizationID: uuid.NewString(), Name: "partners"}
+	store.endpoints[endpoint.ID] = endpoint.OrganizationID
+	return services.NewEndpointGeoPolicyServiceWithStore(store, testGeoResolver(t)), store, endpoint
+}
+
+func TestEndpointGeoPolicyDecisions(t *testing.T) {
+	service, _, endpoint := newGeoPolicyFixture(t)
+	ctx := context.Background()
+
+	_, err := service.SetPolicy(ctx, endpoint.OrganizationID, endpoint.ID, &types.SetEndpointGeoPolicyRequest{
+		AllowCountries: []string{"gb", "de", "GB"},
+		DenyASNs:       []int64{20712},
+		AllowASNs:      []int64{13335},
+		UnknownAction:  types.GeoActionDeny,
+	})
+	require.NoError(t, err)
+	policy, err := service.GetPolicy(ctx, endpoint.OrganizationID, endpoint.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GB", "DE"}, policy.AllowCountries)
+
+	cases := []struct {
+		ip      string
+		allowed bool
+		rule    string
+	}{
+		{"81.2.69.142", false, "deny_asns"},
+		{"2001:db8::1", true, "allow_countries"},
+		{"1.1.1.1", true, "allow_asns"},
+		{"203.0.113.9", false, ""},
+		{"10.0.0.1", false, "unknown_action"},
+	}
+	for _, tc := range cases {
+		decision, err := service.TestPolicy(ctx, endpoint.OrganizationID, endpoint.ID, &types.TestGeoPolicyRequest{IP: tc.ip})
+		require.NoError(t, err, tc.ip)
+		assert.Equal(t, tc.allowed, decision.Allowed, tc.ip)
+		assert.Equal(t, tc.rule, decision.Rule, tc.ip)
+	}
+
+	// Draft policies are tested without being saved
+	decision, err := service.TestPolicy(ctx, endpoint.OrganizationID, endpoint.ID, &types.TestGeoPolicyRequest{
+		IP:     "81.2.69.142",
+		Policy: &types.SetEndpointGeoPolicyRequest{DenyCountries: []string{"GB"}},
+	})
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "deny_countries", decision.Rule)
+	policy, err = service.GetPolicy(ctx, endpoint.OrganizationID, endpoint.ID)
+	require.NoError(t, err)
+	assert.Empty(t, policy.DenyCountries)
+}
+
+func TestEndpointGeoPolicyValidation(t *testing.T) {
+	service, _, endpoint := newGeoPolicyFixture(t)
+	ctx := context.Background()
+
+	_, err := service.SetPolicy(ctx, endpoint.OrganizationID, endpoint.ID, &types.SetEndpointGeoPolicyRequest{
+		DenyCountries: []string{"GBR"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, types.ErrCodeValidationFailed, err.(*types.Error).Code)
+
+	_, err = service.SetPolicy(ctx, endpoint.OrganizationID, endpoint.ID, &types.SetEndpointGeoPolicyRequest{
+		AllowASNs: []int64{0},
+	})
+	require.Error(t, err)
+	assert.Equal(t, types.ErrCodeValidationFailed, err.(*types.Error).Code)
+
+	_, err = service.SetPolicy(ctx, uuid.NewString(), endpoint.ID, &types.SetEndpointGeoPolicyRequest{})
+	require.Error(t, err)
+	assert.Equal(t, types.ErrCodeNotFound, err.(*types.Error).Code)
+
+	// Without a GeoIP database, policies could never match
+	store := newMemoryGeoPolicies()
+	store.endpoints[endpoint.ID] = endpoint.OrganizationID
+	unconfigured := services.NewEndpointGeoPolicyServiceWithStore(store, nil)
+	_, err = unconfigured.SetPolicy(ctx, endpoint.OrganizationID, endpoint.ID, &types.SetEndpointGeoPolicyRequest{})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, err.(*types.Error).Status)
+}
+
+func TestEndpointGeoPolicyRecordsDecisions(t *testing.T) {
+	service, store, endpoint := newGeoPolicyFixture(t)
+	now := time.Now()
+	service.SetClock(func() time.Time { return now })
+	ctx := context.Background()
+
+	// Endpoints without a policy accept everyone and record nothing
+	require.NoError(t, service.CheckEndpoint(ctx, endpoint, "81.2.69.142"))
+	assert.Empty(t, store.decisions)
+
+	_, err := service.SetPolicy(ctx, endpoint.OrganizationID, endpoint.ID, &types.SetEndpointGeoPolicyRequest{
+		DenyCountries: []string{"GB"},
+	})
+	require.NoError(t, err)
+
+	err = service.CheckEndpoint(ctx, endpoint, "81.2.69.142")
+	require.Error(t, err)
+	assert.Equal(t, types.ErrCodeGeoBlocked, err.(*types.Error).Code)
+	assert.Equal(t, http.StatusForbidden, err.(*types.Error).Status)
+	require.NoError(t, service.CheckEndpoint(ctx, endpoint, "1.1.1.1"))
+
+	// Repeated denials of a client are recorded once a minute
+	require.Error(t, service.CheckEndpoint(ctx, endpoint, "81.2.69.142"))
+	require.Len(t, store.decisions, 1)
+	assert.Equal(t, "GB", store.decisions[0].Country)
+	assert.Equal(t, int64(20712), store.decisions[0].ASN)
+	assert.Equal(t, endpoint.OrganizationID, store.decisions[0].OrganizationID)
+	now = now.Add(time.Minute)
+	require.Error(t, service.CheckEndpoint(ctx, endpoint, "81.2.69.142"))
+	assert.Len(t, store.decisions, 2)
+
+	_, err = service.SetPolicy(ctx, endpoint.OrganizationID, endpoint.ID, &types.SetEndpointGeoPolicyRequest{
+		DenyCountries: []string{"GB"},
+		LogAllowed:    true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.CheckEndpoint(ctx, endpoint, "1.1.1.1"))
+	decisions, err := service.ListDecisions(ctx, endpoint.OrganizationID, endpoint.ID, false, 0)
+	require.NoError(t, err)
+	assert.Len(t, decisions, 3)
+	decisions, err = service.ListDecisions(ctx, endpoint.OrganizationID, endpoint.ID, true, 0)
+	require.NoError(t, err)
+	assert.Len(t, decisions, 2)
+}
+
+func TestEndpointGeoMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service, _, endpoint := newGeoPolicyFixture(t)
+	_, err := service.SetPolicy(context.Background(), endpoint.OrganizationID, endpoint.ID, &types.SetEndpointGeoPolicyRequest{
+		AllowCountries: []string{"DE"},
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("endpoint", endpoint)
+		c.Next()
+	}, middleware.EndpointGeoMiddleware(service))
+	router.GET("/mcp", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req.RemoteAddr = "81.2.69.142:5000"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var body types.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, types.ErrCodeGeoBlocked, body.Error.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req.RemoteAddr = "[2001:db8::1]:5000"
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,153 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSandboxEndpoints struct{}
+
+func (stubSandboxEndpoints) GetEndpoint(ctx context.Context, id string) (*types.Endpoint, error) {
+	if id != "endpoint-1" {
+		return nil, types.NewNotFoundError("Endpoint not found")
+	}
+	return &types.Endpoint{ID: id, OrganizationID: flagOrgA, NamespaceID: "namespace-1"}, nil
+}
+
+type recordingSandboxTools struct {
+	ctx   context.Context
+	req   types.ExecuteNamespaceToolRequest
+	delay time.Duration
+}
+
+func (r *recordingSandboxTools) AggregateTools(ctx context.Context, namespaceID string) ([]types.NamespaceTool, error) {
+	return []types.NamespaceTool{{ToolName: "echo"}}, nil
+}
+
+func (r *recordingSandboxTools) ExecuteTool(ctx context.Context, namespaceID string, req types.ExecuteNamespaceToolRequest) (*types.NamespaceToolResult, error) {
+	r.ctx = ctx
+	r.req = req
+	if r.delay > 0 {
+		select {
+		case <-time.After(r.delay):
+		case <-ctx.Done():
+		}
+	}
+	return &types.NamespaceToolResult{Success: true, Result: req.Arguments}, nil
+}
+
+func newSandboxRouter(tools *recordingSandboxTools, limits types.SandboxLimits, orgID string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewSandboxHandler(stubSandboxEndpoints{}, tools, limits)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("organization_id", orgID)
+		c.Next()
+	})
+	router.GET("/api/endpoints/:id/sandbox/tools", handler.ListTools)
+	router.POST("/api/endpoints/:id/sandbox/tools/:tool_name",
+		middleware.SandboxRateLimit(limits.RequestsPerMinute), handler.ExecuteTool)
+	return router
+}
+
+func postSandbox(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSandboxExecutionIsFlaggedAndNonBillable(t *testing.T) {
+	tools := &recordingSandboxTools{}
+	limits := types.SandboxLimits{RequestsPerMinute: 5, TimeoutSeconds: 5, MaxArgumentBytes: 1024}
+	router := newSandboxRouter(tools, limits, flagOrgA)
+
+	w := postSandbox(router, "/api/endpoints/endpoint-1/sandbox/tools/server__echo", `{"arguments":{"text":"hi"}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "5", w.Header().Get("RateLimit-Limit"))
+
+	var resp struct {
+		Data types.SandboxExecutionResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Data.Sandbox)
+	assert.False(t, resp.Data.Billable)
+	assert.Equal(t, "server__echo", resp.Data.Tool)
+	assert.Equal(t, limits, resp.Data.Limits)
+	require.NotNil(t, resp.Data.Result)
+	assert.True(t, resp.Data.Result.Success)
+
+	require.NotNil(t, tools.ctx)
+	assert.True(t, types.IsSandbox(tools.ctx))
+	_, hasDeadline := tools.ctx.Deadline()
+	assert.True(t, hasDeadline)
+	principal := types.PrincipalFromContext(tools.ctx)
+	require.NotNil(t, principal)
+	assert.Equal(t, "user-1", principal.UserID)
+	assert.Equal(t, "hi", tools.req.Arguments["text"])
+}
+
+func TestSandboxEnforcesStricterLimits(t *testing.T) {
+	limits := types.SandboxLimits{RequestsPerMinute: 1, TimeoutSeconds: 5, MaxArgumentBytes: 32}
+	router := newSandboxRouter(&recordingSandboxTools{}, limits, flagOrgA)
+
+	w := postSandbox(router, "/api/endpoints/endpoint-1/sandbox/tools/server__echo",
+		`{"arguments":{"text":"this payload is longer than thirty-two bytes"}}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = postSandbox(router, "/api/endpoints/endpoint-1/sandbox/tools/server__echo", `{}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestSandboxTimesOut(t *testing.T) {
+	tools := &recordingSandboxTools{delay: 5 * time.Second}
+	limits := types.SandboxLimits{RequestsPerMinute: 5, TimeoutSeconds: 1, MaxArgumentBytes: 1024}
+	router := newSandboxRouter(tools, limits, flagOrgA)
+
+	w := postSandbox(router, "/api/endpoints/endpoint-1/sandbox/tools/server__echo", `{}`)
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestSandboxHidesOtherOrganizationsEndpoints(t *testing.T) {
+	limits := types.SandboxLimits{RequestsPerMinute: 5, TimeoutSeconds: 5, MaxArgumentBytes: 1024}
+	router := newSandboxRouter(&recordingSandboxTools{}, limits, flagOrgB)
+
+	w := postSandbox(router, "/api/endpoints/endpoint-1/sandbox/tools/server__echo", `{}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/endpoints/endpoint-1/sandbox/tools", http.NoBody)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSandboxEntriesExcludedFromUsage(t *testing.T) {
+	user := &types.Principal{Type: types.PrincipalTypeUser, UserID: "user-1"}
+	entries := []*logging.LogEntry{
+		{Logger: logging.LoggerToolExecution, Principal: user, Data: map[string]interface{}{"success": true}},
+		{Logger: logging.LoggerToolExecution, Principal: user, Data: map[string]interface{}{"success": true, "sandbox": true}},
+		{Logger: logging.LoggerRequest, Principal: user, StatusCode: 200, Data: map[string]interface{}{"sandbox": true}},
+	}
+
+	usage := logging.SummarizeByPrincipal(entries)
+	require.Len(t, usage, 1)
+	assert.Equal(t, int64(1), usage[0].ToolExecutions)
+	assert.Zero(t, usage[0].Requests)
+}
@@ -0,0 +1,241 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const platformOrgID = "platform-org"
+
+// memoryDeployment keeps the deployment state and failovers in memory
+type memoryDeployment struct {
+	state     *types.DeploymentState
+	failovers []*types.Failover
+	setErr    error
+}
+
+func (m *memoryDeployment) GetState() (*types.DeploymentState, error) {
+	if m.state == nil {
+		return nil, nil
+	}
+	state := *m.state
+	return &state, nil
+}
+
+func (m *memoryDeployment) SetState(state *types.DeploymentState) (*types.DeploymentState, error) {
+	if m.setErr != nil {
+		return nil, m.setErr
+	}
+	state.Epoch = 1
+	if m.state != nil {
+		state.Epoch = m.state.Epoch + 1
+	}
+	stored := *state
+	m.state = &stored
+	return state, nil
+}
+
+func (m *memoryDeployment) CreateFailover(failover *types.Failover) error {
+	failover.ID = "failover-1"
+	m.failovers = append(m.failovers, failover)
+	return nil
+}
+
+func (m *memoryDeployment) UpdateFailover(failover *types.Failover) error {
+	return nil
+}
+
+func (m *memoryDeployment) ListFailovers(limit int) ([]*types.Failover, error) {
+	return m.failovers, nil
+}
+
+func (m *memoryDeployment) PlatformOrganization() (string, error) {
+	return platformOrgID, nil
+}
+
+// peerFence records the fence requests sent to peers
+type peerFence struct {
+	err      error
+	requests []*types.FenceRequest
+}
+
+func (f *peerFence) Fence(ctx context.Context, peer services.RegionPeer, req *types.FenceRequest) error {
+	f.requests = append(f.requests, req)
+	return f.err
+}
+
+// failoverAuditLog collects audit records
+type failoverAuditLog struct {
+	records []*types.AuditLog
+}
+
+func (a *failoverAuditLog) LogAudit(audit *types.AuditLog) error {
+	a.records = append(a.records, audit)
+	return nil
+}
+
+func newFailoverService(role string) (*services.FailoverService, *memoryDeployment, *peerFence, *failoverAuditLog) {
+	store := &memoryDeployment{}
+	fencer := &peerFence{}
+	auditor := &failoverAuditLog{}
+	service := services.NewFailoverServiceWithStore(store, fencer, services.RegionSettings{
+		Region: "eu-west",
+		Token:  "replication-secret",
+		Peers:  []services.RegionPeer{{Name: "us-east", URL: "https://us-east.gateway.example.com"}},
+	}, role)
+	service.SetAuditor(auditor)
+	service.SetClock(func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) })
+	return service, store, fencer, auditor
+}
+
+func stepStatuses(failover *types.Failover) map[string]string {
+	statuses := make(map[string]string, len(failover.Steps))
+	for _, step := range failover.Steps {
+		statuses[step.Name] = step.Status
+	}
+	return statuses
+}
+
+func TestFailoverPromotesStandby(t *testing.T) {
+	service, store, fencer, auditor := newFailoverService(types.DeploymentRoleStandby)
+	ctx := context.Background()
+	assert.False(t, service.IsActive(ctx))
+
+	failover, err := service.Failover(ctx, &types.FailoverRequest{FromRegion: "us-east", Reason: "us-east outage"}, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, types.FailoverStatusCompleted, failover.Status)
+	assert.Equal(t, "eu-west", failover.ToRegion)
+	assert.NotNil(t, failover.CompletedAt)
+	assert.Equal(t, map[string]string{
+		services.FailoverStepFencePrevious:    types.FailoverStepDone,
+		services.FailoverStepPromote:          types.FailoverStepDone,
+		services.FailoverStepResumeDeliveries: types.FailoverStepDone,
+		services.FailoverStepInvalidateCaches: types.FailoverStepDone,
+	}, stepStatuses(failover))
+
+	require.Len(t, fencer.requests, 1)
+	assert.Equal(t, "eu-west", fencer.requests[0].Region)
+	assert.True(t, service.IsActive(ctx))
+	assert.Equal(t, int64(1), store.state.Epoch)
+
+	// Start, each step and the outcome are audited to the platform
+	// organization
+	require.Len(t, auditor.records, 6)
+	for _, record := range auditor.records {
+		assert.Equal(t, platformOrgID, record.OrganizationID)
+		assert.Equal(t, "failover", record.Resource)
+		assert.Equal(t, "admin-1", record.UserID)
+		assert.Equal(t, "failover-1", record.ResourceID)
+	}
+	assert.Equal(t, types.AuditSeverityCritical, types.AuditSeverityFor("complete", "failover", true))
+}
+
+func TestFailoverContinuesWhenPreviousRegionUnreachable(t *testing.T) {
+	service, _, fencer, auditor := newFailoverService(types.DeploymentRoleStandby)
+	fencer.err = errors.New("connection refused")
+
+	failover, err := service.Failover(context.Background(), &types.FailoverRequest{FromRegion: "us-east", Reason: "outage"}, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, types.FailoverStatusPartial, failover.Status)
+	assert.Equal(t, types.FailoverStepFailed, stepStatuses(failover)[services.FailoverStepFencePrevious])
+	assert.Contains(t, failover.Steps[0].Detail, "connection refused")
+	assert.True(t, service.IsActive(context.Background()))
+	assert.False(t, auditor.records[1].Success)
+}
+
+func TestFailoverPromotionFailure(t *testing.T) {
+	service, store, _, _ := newFailoverService(types.DeploymentRoleStandby)
+	store.setErr = errors.New("database unavailable")
+
+	failover, err := service.Failover(context.Background(), &types.FailoverRequest{FromRegion: "us-east", Reason: "outage"}, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, types.FailoverStatusFailed, failover.Status)
+	assert.Equal(t, types.FailoverStepSkipped, stepStatuses(failover)[services.FailoverStepResumeDeliveries])
+	assert.False(t, service.IsActive(context.Background()))
+}
+
+func TestFailoverDryRunChangesNothing(t *testing.T) {
+	service, store, fencer, _ := newFailoverService(types.DeploymentRoleStandby)
+
+	failover, err := service.Failover(context.Background(), &types.FailoverRequest{FromRegion: "us-east", Reason: "drill", DryRun: true}, "admin-1")
+	require.NoError(t, err)
+	assert.True(t, failover.DryRun)
+	assert.Len(t, failover.Steps, 4)
+	for _, step := range failover.Steps {
+		assert.Equal(t, types.FailoverStepPlanned, step.Status)
+	}
+	assert.Empty(t, fencer.requests)
+	assert.Nil(t, store.state)
+	assert.Len(t, store.failovers, 1)
+}
+
+func TestFailoverValidation(t *testing.T) {
+	service, _, _, _ := newFailoverService(types.DeploymentRoleStandby)
+
+	_, err := service.Failover(context.Background(), &types.FailoverRequest{FromRegion: "ap-south", Reason: "outage"}, "admin-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "region.peers")
+
+	_, err = service.Failover(context.Background(), &types.FailoverRequest{FromRegion: "eu-west", Reason: "outage"}, "admin-1")
+	require.Error(t, err)
+
+	_, err = service.Fence(context.Background(), &types.FenceRequest{Region: "eu-west"}, "region:eu-west")
+	require.Error(t, err)
+}
+
+func TestFenceMakesRegionReadOnlyStandby(t *testing.T) {
+	service, _, _, auditor := newFailoverService("")
+	ctx := context.Background()
+	assert.True(t, service.IsActive(ctx))
+	assert.True(t, service.Authorize("replication-secret"))
+	assert.False(t, service.Authorize(""))
+
+	state, err := service.Fence(ctx, &types.FenceRequest{Region: "us-east", Reason: "eu-west outage"}, "region:us-east")
+	require.NoError(t, err)
+	assert.Equal(t, types.DeploymentRoleStandby, state.Role)
+	assert.True(t, state.ReadOnly)
+	assert.Equal(t, "Failed over to us-east: eu-west outage", state.ReadOnlyReason)
+	assert.Equal(t, "eu-west", state.Region)
+	assert.False(t, service.IsActive(ctx))
+	require.Len(t, auditor.records, 1)
+	assert.Equal(t, "fence", auditor.records[0].Action)
+
+	// The watcher applies the change once
+	watchCtx, cancel := context.WithCancel(ctx)
+	var seen []*types.DeploymentState
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	service.Watch(watchCtx, 10*time.Millisecond, func(state *types.DeploymentState) {
+		seen = append(seen, state)
+	})
+	require.Len(t, seen, 1)
+	assert.Equal(t, int64(1), seen[0].Epoch)
+}
+
+func TestReadOnlyModeFollowsFailover(t *testing.T) {
+	mode := middleware.NewReadOnlyMode(false)
+
+	mode.SetFailover(true, "Failed over to us-east", "region:us-east")
+	assert.True(t, mode.Enabled())
+	assert.Equal(t, types.ReadOnlySourceFailover, mode.Status().Source)
+
+	mode.SetFailover(false, "", "admin-1")
+	assert.False(t, mode.Enabled())
+
+	// Promotion leaves read-only mode an admin turned on
+	mode.Set(true, "maintenance", "admin-1")
+	mode.SetFailover(false, "", "admin-1")
+	assert.True(t, mode.Enabled())
+	assert.Equal(t, "maintenance", mode.Status().Reason)
+}
@@ -0,0 +1,147 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flagOrgA = "11111111-1111-1111-1111-111111111111"
+	flagOrgB = "22222222-2222-2222-2222-222222222222"
+)
+
+type memoryFeatureFlagStore struct {
+	listErr error
+	rows    map[string]*types.FeatureFlagOverride
+	lists   int
+}
+
+func newMemoryFeatureFlagStore() *memoryFeatureFlagStore {
+	return &memoryFeatureFlagStore{rows: make(map[string]*types.FeatureFlagOverride)}
+}
+
+func (m *memoryFeatureFlagStore) List() ([]*types.FeatureFlagOverride, error) {
+	m.lists++
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	rows := make([]*types.FeatureFlagOverride, 0, len(m.rows))
+	for _, row := range m.rows {
+		copied := *row
+		rows = append(rows, &copied)
+	}
+	return rows, nil
+}
+
+func (m *memoryFeatureFlagStore) Upsert(override *types.FeatureFlagOverride) error {
+	override.UpdatedAt = time.Now()
+	m.rows[override.Key+"|"+override.OrganizationID] = override
+	return nil
+}
+
+func (m *memoryFeatureFlagStore) Delete(key, orgID string) (bool, error) {
+	id := key + "|" + orgID
+	_, ok := m.rows[id]
+	delete(m.rows, id)
+	return ok, nil
+}
+
+func noEnv(string) string { return "" }
+
+func TestFeatureFlagPrecedence(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryFeatureFlagStore()
+	flags := services.NewFeatureFlagServiceWithStore(store, noEnv)
+
+	state, err := flags.GetFlag(ctx, flagOrgA, types.FeatureSemanticSearch)
+	require.NoError(t, err)
+	assert.False(t, state.Enabled)
+	assert.Equal(t, types.FeatureFlagSourceDefault, state.Source)
+
+	// Global value applies to every organization
+	require.NoError(t, flags.SetFlag(ctx, types.FeatureSemanticSearch, "", true, "admin"))
+	assert.True(t, flags.IsEnabled(ctx, flagOrgA, types.FeatureSemanticSearch))
+	assert.True(t, flags.IsEnabled(ctx, flagOrgB, types.FeatureSemanticSearch))
+
+	// Organization value wins over the global one
+	require.NoError(t, flags.SetFlag(ctx, types.FeatureSemanticSearch, flagOrgB, false, "admin"))
+	assert.True(t, flags.IsEnabled(ctx, flagOrgA, types.FeatureSemanticSearch))
+	assert.False(t, flags.IsEnabled(ctx, flagOrgB, types.FeatureSemanticSearch))
+
+	state, err = flags.GetFlag(ctx, flagOrgB, types.FeatureSemanticSearch)
+	require.NoError(t, err)
+	assert.Equal(t, types.FeatureFlagSourceOrganization, state.Source)
+	assert.Equal(t, "admin", state.UpdatedBy)
+	assert.NotNil(t, state.UpdatedAt)
+
+	// Clearing the organization value falls back to global
+	require.NoError(t, flags.ClearFlag(ctx, types.FeatureSemanticSearch, flagOrgB))
+	state, err = flags.GetFlag(ctx, flagOrgB, types.FeatureSemanticSearch)
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, types.FeatureFlagSourceGlobal, state.Source)
+}
+
+func TestFeatureFlagEnvOverride(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryFeatureFlagStore()
+	env := map[string]string{
+		services.FeatureFlagEnvVar(types.FeatureAsyncExecution): "true",
+		services.FeatureFlagEnvVar(types.FeatureSemanticSearch): "maybe",
+	}
+	flags := services.NewFeatureFlagServiceWithStore(store, func(name string) string { return env[name] })
+
+	assert.Equal(t, "FEATURE_FLAG_ASYNC_EXECUTION", services.FeatureFlagEnvVar(types.FeatureAsyncExecution))
+
+	require.NoError(t, flags.SetFlag(ctx, types.FeatureAsyncExecution, flagOrgA, false, "admin"))
+	state, err := flags.GetFlag(ctx, flagOrgA, types.FeatureAsyncExecution)
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, types.FeatureFlagSourceEnv, state.Source)
+
+	// Unparseable values are ignored
+	state, err = flags.GetFlag(ctx, flagOrgA, types.FeatureSemanticSearch)
+	require.NoError(t, err)
+	assert.Equal(t, types.FeatureFlagSourceDefault, state.Source)
+}
+
+func TestFeatureFlagUnknownKey(t *testing.T) {
+	ctx := context.Background()
+	flags := services.NewFeatureFlagServiceWithStore(newMemoryFeatureFlagStore(), noEnv)
+
+	assert.False(t, flags.IsEnabled(ctx, flagOrgA, "does_not_exist"))
+
+	var apiErr *types.Error
+	require.ErrorAs(t, flags.SetFlag(ctx, "does_not_exist", flagOrgA, true, "admin"), &apiErr)
+	assert.Equal(t, types.ErrCodeNotFound, apiErr.Code)
+
+	require.ErrorAs(t, flags.ClearFlag(ctx, types.FeatureSemanticSearch, flagOrgA), &apiErr)
+	assert.Equal(t, types.ErrCodeNotFound, apiErr.Code)
+
+	assert.Len(t, flags.ListFlags(ctx, flagOrgA), len(types.FeatureFlagDefinitions))
+}
+
+func TestFeatureFlagCachingAndStoreFailure(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryFeatureFlagStore()
+	flags := services.NewFeatureFlagServiceWithStore(store, noEnv)
+
+	require.NoError(t, flags.SetFlag(ctx, types.FeatureSemanticSearch, flagOrgA, true, "admin"))
+	assert.True(t, flags.IsEnabled(ctx, flagOrgA, types.FeatureSemanticSearch))
+	assert.True(t, flags.IsEnabled(ctx, flagOrgA, types.FeatureSemanticSearch))
+	assert.Equal(t, 1, store.lists, "repeated lookups should be served from cache")
+
+	// A failing store keeps serving the last known values
+	store.listErr = errors.New("connection refused")
+	require.NoError(t, flags.SetFlag(ctx, types.FeatureAsyncExecution, flagOrgA, true, "admin"))
+	assert.True(t, flags.IsEnabled(ctx, flagOrgA, types.FeatureSemanticSearch))
+	assert.False(t, flags.IsEnabled(ctx, flagOrgA, types.FeatureAsyncExecution))
+}
@@ -0,0 +1,152 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const discoveryBaseURL = "https://gateway.example.com"
+
+type stubDiscoveryEndpoints struct {
+	*services.EndpointService
+	endpoints map[string]*types.EndpointConfig
+}
+
+func (s stubDiscoveryEndpoints) ResolveEndpoint(ctx context.Context, name string) (*types.EndpointConfig, error) {
+	if config, ok := s.endpoints[name]; ok {
+		return config, nil
+	}
+	return nil, types.NewNotFoundError("Endpoint not found")
+}
+
+func newDiscoveryRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	endpoints := stubDiscoveryEndpoints{
+		EndpointService: services.NewEndpointService(nil, discoveryBaseURL),
+		endpoints: map[string]*types.EndpointConfig{
+			"secure": {
+				Endpoint: &types.Endpoint{
+					Name:              "secure",
+					NamespaceID:       "namespace-1",
+					IsActive:          true,
+					EnableAPIKeyAuth:  true,
+					EnableOAuth:       true,
+					UseQueryParamAuth: true,
+				},
+				Namespace: &types.Namespace{ID: "namespace-1", Name: "engineering"},
+			},
+			"open": {
+				Endpoint: &types.Endpoint{Name: "open", NamespaceID: "namespace-2", IsActive: true, EnablePublicAccess: true},
+			},
+			"disabled": {
+				Endpoint: &types.Endpoint{Name: "disabled", NamespaceID: "namespace-3"},
+			},
+		},
+	}
+
+	handler := handlers.NewGatewayDiscoveryHandler(endpoints, discoveryBaseURL+"/")
+	router := gin.New()
+	router.GET("/.well-known/mcp-gateway", handler.Discover)
+	router.GET("/.well-known/mcp-gateway/:endpoint_name", handler.DiscoverEndpoint)
+	return router
+}
+
+func getDiscovery(t *testing.T, router *gin.Engine, path string) (int, types.GatewayDiscoveryDocument) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var doc types.GatewayDiscoveryDocument
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	}
+	return w.Code, doc
+}
+
+func transportByType(doc types.GatewayDiscoveryDocument, transportType string) *types.DiscoveredTransport {
+	for i := range doc.Transports {
+		if doc.Transports[i].Type == transportType {
+			return &doc.Transports[i]
+		}
+	}
+	return nil
+}
+
+func TestGatewayDiscoveryTemplates(t *testing.T) {
+	code, doc := getDiscovery(t, newDiscoveryRouter(), "/.well-known/mcp-gateway")
+	require.Equal(t, http.StatusOK, code)
+
+	assert.Equal(t, discoveryBaseURL, doc.Issuer)
+	assert.Equal(t, types.MCPProtocolVersions, doc.ProtocolVersions)
+	assert.Equal(t, discoveryBaseURL+"/api/public/endpoints", doc.EndpointsURL)
+	assert.Nil(t, doc.Endpoint)
+	require.NotNil(t, doc.OAuth)
+	assert.Len(t, doc.AuthMethods, 3)
+
+	streamable := transportByType(doc, types.DiscoveryTransportStreamableHTTP)
+	require.NotNil(t, streamable)
+	assert.True(t, streamable.Preferred)
+	assert.Equal(t, discoveryBaseURL+"/api/public/endpoints/{endpoint_name}/mcp", streamable.URL)
+}
+
+func TestGatewayDiscoveryEndpoint(t *testing.T) {
+	router := newDiscoveryRouter()
+
+	for _, path := range []string{"/.well-known/mcp-gateway/secure", "/.well-known/mcp-gateway?endpoint=secure"} {
+		t.Run(path, func(t *testing.T) {
+			code, doc := getDiscovery(t, router, path)
+			require.Equal(t, http.StatusOK, code)
+
+			require.NotNil(t, doc.Endpoint)
+			assert.Equal(t, "secure", doc.Endpoint.Name)
+			assert.Equal(t, "engineering", doc.Endpoint.NamespaceName)
+			assert.Empty(t, doc.EndpointsURL)
+
+			sse := transportByType(doc, types.DiscoveryTransportSSE)
+			require.NotNil(t, sse)
+			assert.Equal(t, discoveryBaseURL+"/api/public/endpoints/secure/sse", sse.URL)
+			assert.Equal(t, discoveryBaseURL+"/api/public/endpoints/secure/message", sse.MessageURL)
+
+			ws := transportByType(doc, types.DiscoveryTransportWebSocket)
+			require.NotNil(t, ws)
+			assert.Equal(t, "wss://gateway.example.com/api/public/endpoints/secure/ws", ws.URL)
+			assert.Equal(t, transport.WebSocketSubprotocols, ws.Subprotocols)
+
+			require.Len(t, doc.AuthMethods, 2)
+			assert.Equal(t, types.DiscoveryAuthAPIKey, doc.AuthMethods[0].Type)
+			assert.Contains(t, doc.AuthMethods[0].Locations, "query")
+			assert.Equal(t, types.DiscoveryAuthOAuth2, doc.AuthMethods[1].Type)
+			require.NotNil(t, doc.OAuth)
+		})
+	}
+}
+
+func TestGatewayDiscoveryPublicEndpoint(t *testing.T) {
+	code, doc := getDiscovery(t, newDiscoveryRouter(), "/.well-known/mcp-gateway/open")
+	require.Equal(t, http.StatusOK, code)
+
+	require.Len(t, doc.AuthMethods, 1)
+	assert.Equal(t, types.DiscoveryAuthNone, doc.AuthMethods[0].Type)
+	assert.Nil(t, doc.OAuth)
+}
+
+func TestGatewayDiscoveryUnknownOrInactive(t *testing.T) {
+	router := newDiscoveryRouter()
+
+	code, _ := getDiscovery(t, router, "/.well-known/mcp-gateway/missing")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = getDiscovery(t, router, "/.well-known/mcp-gateway?endpoint=disabled")
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -0,0 +1,170 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mcp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// grpcMCPSession answers every JSON-RPC request on the stream with a result
+// echoing its method, after sending a notification
+func grpcMCPSession(_ interface{}, stream grpc.ServerStream) error {
+	for {
+		frame := &wrapperspb.BytesValue{}
+		if err := stream.RecvMsg(frame); err != nil {
+			return nil
+		}
+
+		var request map[string]interface{}
+		if err := json.Unmarshal(frame.Value, &request); err != nil {
+			return err
+		}
+
+		notification, _ := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "notifications/progress",
+		})
+		if err := stream.SendMsg(wrapperspb.Bytes(notification)); err != nil {
+			return err
+		}
+
+		response, _ := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      request["id"],
+			"result":  map[string]interface{}{"method": request["method"]},
+		})
+		if err := stream.SendMsg(wrapperspb.Bytes(response)); err != nil {
+			return err
+		}
+	}
+}
+
+// startGRPCMCPServer runs an MCP server speaking the gRPC session contract
+// and returns its grpc:// URL and health server
+func startGRPCMCPServer(t *testing.T) (string, *health.Server) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: transport.GRPCServiceName,
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Session",
+			Handler:       grpcMCPSession,
+			ClientStreams: true,
+			ServerStreams: true,
+		}},
+	}, struct{}{})
+
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return "grpc://" + listener.Addr().String(), healthServer
+}
+
+func TestGRPCTransport_SendRequest(t *testing.T) {
+	url, _ := startGRPCMCPServer(t)
+
+	trans, err := transport.CreateTransport(types.TransportTypeGRPC, map[string]interface{}{
+		"url":     url,
+		"timeout": 5 * time.Second,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.TransportTypeGRPC, trans.GetTransportType())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, trans.Connect(ctx))
+	defer trans.Disconnect(ctx)
+
+	syncTrans, ok := trans.(interface {
+		SendRequest(ctx context.Context, request *types.MCPMessage) (*types.MCPMessage, error)
+	})
+	require.True(t, ok)
+
+	response, err := syncTrans.SendRequest(ctx, &types.MCPMessage{
+		ID:     "req-1",
+		Type:   types.MCPMessageTypeRequest,
+		Method: types.MCPMethodListTools,
+		Params: map[string]interface{}{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", response.ID)
+	assert.Equal(t, types.MCPMessageTypeResponse, response.Type)
+	assert.Equal(t, map[string]interface{}{"method": types.MCPMethodListTools}, response.Result)
+
+	// The notification sent ahead of the response is queued for readers
+	message, err := trans.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	notification := message.(*types.MCPMessage)
+	assert.Equal(t, types.MCPMessageTypeNotification, notification.Type)
+	assert.Equal(t, "notifications/progress", notification.Method)
+}
+
+func TestGRPCTransport_RejectsNonGRPCURL(t *testing.T) {
+	_, err := transport.CreateTransport(types.TransportTypeGRPC, map[string]interface{}{
+		"url": "https://example.com",
+	})
+	assert.Error(t, err)
+
+	_, err = transport.CreateTransport(types.TransportTypeGRPC, map[string]interface{}{})
+	assert.Error(t, err)
+}
+
+func TestCheckGRPCHealth(t *testing.T) {
+	url, healthServer := startGRPCMCPServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Unknown to the health service: the server's overall status applies
+	assert.NoError(t, transport.CheckGRPCHealth(ctx, url))
+
+	healthServer.SetServingStatus(transport.GRPCServiceName, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	assert.ErrorIs(t, transport.CheckGRPCHealth(ctx, url), transport.ErrGRPCNotServing)
+
+	healthServer.SetServingStatus(transport.GRPCServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	assert.NoError(t, transport.CheckGRPCHealth(ctx, url))
+}
+
+func TestMCPGRPCConnection(t *testing.T) {
+	url, _ := startGRPCMCPServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := mcp.NewTransportManager().CreateConnection(ctx, mcp.TransportConfig{Type: "grpc", URL: url})
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.True(t, conn.IsConnected())
+
+	require.NoError(t, conn.Send(ctx, []byte(`{"jsonrpc":"2.0","id":7,"method":"tools/call"}`)))
+
+	notification, err := conn.Receive(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, string(notification), "notifications/progress")
+
+	response, err := conn.Receive(ctx)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":7,"result":{"method":"tools/call"}}`, string(response))
+
+	require.NoError(t, conn.Close())
+	assert.False(t, conn.IsConnected())
+}
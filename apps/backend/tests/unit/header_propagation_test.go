@@ -0,0 +1,229 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/headerprop"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryHeaderRules keeps header propagation rules by scope and counts
+// loads
+type memoryHeaderRules struct {
+	rules  map[string]*types.HeaderPropagationRules
+	scopes map[string]string
+	loads  int
+}
+
+func newMemoryHeaderRules() *memoryHeaderRules {
+	return &memoryHeaderRules{
+		rules:  map[string]*types.HeaderPropagationRules{},
+		scopes: map[string]string{"server:srv-1": "org-1", "namespace:ns-1": "org-1"},
+	}
+}
+
+func (m *memoryHeaderRules) ScopeExists(orgID, scopeType, scopeID string) (bool, error) {
+	return m.scopes[scopeType+":"+scopeID] == orgID, nil
+}
+
+func (m *memoryHeaderRules) Get(scopeType, scopeID string) (*types.HeaderPropagationSettings, error) {
+	m.loads++
+	rules, ok := m.rules[scopeType+":"+scopeID]
+	if !ok {
+		return nil, nil
+	}
+	return &types.HeaderPropagationSettings{ScopeType: scopeType, ScopeID: scopeID, Rules: *rules}, nil
+}
+
+func (m *memoryHeaderRules) Upsert(orgID, scopeType, scopeID string, rules *types.HeaderPropagationRules) error {
+	m.rules[scopeType+":"+scopeID] = rules
+	return nil
+}
+
+func (m *memoryHeaderRules) Delete(scopeType, scopeID string) (bool, error) {
+	_, ok := m.rules[scopeType+":"+scopeID]
+	delete(m.rules, scopeType+":"+scopeID)
+	return ok, nil
+}
+
+func TestHeaderPropagationResolve(t *testing.T) {
+	inbound := http.Header{}
+	inbound.Set("X-Tenant-Id", "acme")
+	inbound.Set("X-Tenant-Debug", "1")
+	inbound.Set("X-Request-Id", "req-1")
+	inbound.Set("Authorization", "Bearer secret")
+	inbound.Set("Cookie", "session=secret")
+
+	rules := &types.HeaderPropagationRules{
+		Propagate: []string{"x-tenant-*", "X-Request-Id", "Authorization", "Cookie"},
+		Strip:     []string{"X-Tenant-Debug"},
+		Set: map[string]string{
+			"X-User-Email": "{{claims.email}}",
+			"X-Caller":     "{{ claims.type }}:{{claims.sub}}",
+			"X-Team":       "{{labels.team}}",
+			"X-Route":      "{{namespace.id}}/{{server.name}}",
+			"X-Request-Id": "gw-{{header.X-Request-Id}}",
+		},
+	}
+
+	out := headerprop.Resolve(rules, &headerprop.Request{
+		Header: inbound,
+		Principal: &types.Principal{
+			Type:   types.PrincipalTypeUser,
+			UserID: "user-1",
+			Email:  "dev@example.com",
+		},
+		NamespaceID: "ns-1",
+		ServerName:  "search",
+	})
+
+	assert.Equal(t, "acme", out.Get("X-Tenant-Id"))
+	assert.Empty(t, out.Values("X-Tenant-Debug"), "stripped headers are not propagated")
+	assert.Empty(t, out.Values("Authorization"), "credentials are never propagated")
+	assert.Empty(t, out.Values("Cookie"))
+	assert.Equal(t, "dev@example.com", out.Get("X-User-Email"))
+	assert.Equal(t, "user:user-1", out.Get("X-Caller"))
+	assert.Equal(t, "ns-1/search", out.Get("X-Route"))
+	assert.Equal(t, "gw-req-1", out.Get("X-Request-Id"), "templates replace propagated values")
+	assert.Empty(t, out.Values("X-Team"), "a template with a missing variable is not sent")
+}
+
+func TestHeaderPropagationResolveDropsLineBreaks(t *testing.T) {
+	inbound := http.Header{"X-Hint": {"a\r\nX-Injected: 1"}}
+	out := headerprop.Resolve(&types.HeaderPropagationRules{
+		Set: map[string]string{"X-Derived": "{{header.X-Hint}}"},
+	}, &headerprop.Request{Header: inbound})
+	assert.Empty(t, out.Values("X-Derived"))
+}
+
+func TestHeaderPropagationMerge(t *testing.T) {
+	merged := headerprop.Merge(
+		&types.HeaderPropagationRules{
+			Propagate: []string{"X-Tenant-*"},
+			Set:       map[string]string{"x-source": "namespace", "X-Org": "{{claims.organization_id}}"},
+		},
+		&types.HeaderPropagationRules{
+			Strip: []string{"X-Tenant-Secret"},
+			Set:   map[string]string{"X-Source": "server"},
+		},
+	)
+
+	assert.Equal(t, []string{"X-Tenant-*"}, merged.Propagate)
+	assert.Equal(t, []string{"X-Tenant-Secret"}, merged.Strip)
+	assert.Equal(t, "server", merged.Set["X-Source"], "server templates take precedence")
+	assert.Equal(t, "{{claims.organization_id}}", merged.Set["X-Org"])
+}
+
+func TestHeaderPropagationValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules types.HeaderPropagationRules
+		valid bool
+	}{
+		{"templates", types.HeaderPropagationRules{Set: map[string]string{"X-User": "{{claims.email}} {{labels.team}}"}}, true},
+		{"patterns", types.HeaderPropagationRules{Propagate: []string{"X-Tenant-*", "*"}}, true},
+		{"protected header", types.HeaderPropagationRules{Set: map[string]string{"authorization": "Bearer x"}}, false},
+		{"unknown claim", types.HeaderPropagationRules{Set: map[string]string{"X-User": "{{claims.password}}"}}, false},
+		{"unknown scope", types.HeaderPropagationRules{Set: map[string]string{"X-User": "{{env.HOME}}"}}, false},
+		{"invalid pattern", types.HeaderPropagationRules{Strip: []string{"X Tenant"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := headerprop.Validate(&tt.rules)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestHeaderPropagationServiceRules(t *testing.T) {
+	store := newMemoryHeaderRules()
+	svc := services.NewHeaderPropagationServiceWithStore(store)
+	ctx := context.Background()
+
+	settings, err := svc.GetRules(ctx, "org-1", types.HeaderPropagationScopeServer, "srv-1")
+	require.NoError(t, err)
+	assert.Empty(t, settings.Rules.Propagate)
+
+	_, err = svc.GetRules(ctx, "org-2", types.HeaderPropagationScopeServer, "srv-1")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "servers of other organizations are not found")
+
+	_, err = svc.SetRules(ctx, "org-1", types.HeaderPropagationScopeServer, "srv-1",
+		&types.HeaderPropagationRules{Set: map[string]string{"Cookie": "x"}})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	settings, err = svc.SetRules(ctx, "org-1", types.HeaderPropagationScopeServer, "srv-1",
+		&types.HeaderPropagationRules{Propagate: []string{"X-Tenant-Id"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"X-Tenant-Id"}, settings.Rules.Propagate)
+
+	require.NoError(t, svc.DeleteRules(ctx, "org-1", types.HeaderPropagationScopeServer, "srv-1"))
+	err = svc.DeleteRules(ctx, "org-1", types.HeaderPropagationScopeServer, "srv-1")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+}
+
+func TestHeaderPropagationServiceUpstreamHeaders(t *testing.T) {
+	store := newMemoryHeaderRules()
+	svc := services.NewHeaderPropagationServiceWithStore(store)
+	now := time.Now()
+	svc.SetClock(func() time.Time { return now })
+
+	inbound := http.Header{}
+	inbound.Set("X-Tenant-Id", "acme")
+	ctx := types.WithInboundHeaders(context.Background(), inbound)
+	ctx = types.WithPrincipal(ctx, &types.Principal{Type: types.PrincipalTypeUser, UserID: "user-1", Email: "dev@example.com"})
+
+	header, err := svc.UpstreamHeaders(ctx, "ns-1", "srv-1", "search")
+	require.NoError(t, err)
+	assert.Nil(t, header, "nothing is propagated without rules")
+
+	store.rules["namespace:ns-1"] = &types.HeaderPropagationRules{Propagate: []string{"X-Tenant-Id"}}
+	store.rules["server:srv-1"] = &types.HeaderPropagationRules{Set: map[string]string{"X-User-Email": "{{claims.email}}"}}
+
+	header, err = svc.UpstreamHeaders(ctx, "ns-1", "srv-1", "search")
+	require.NoError(t, err)
+	assert.Nil(t, header, "rules are cached")
+	loads := store.loads
+
+	now = now.Add(11 * time.Second)
+	header, err = svc.UpstreamHeaders(ctx, "ns-1", "srv-1", "search")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", header.Get("X-Tenant-Id"))
+	assert.Equal(t, "dev@example.com", header.Get("X-User-Email"))
+	assert.Equal(t, loads+2, store.loads)
+}
+
+func TestJSONRPCTransportSendsUpstreamHeaders(t *testing.T) {
+	received := make(chan http.Header, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":"1","result":{}}`))
+	}))
+	defer upstream.Close()
+
+	tr, err := transport.NewJSONRPCTransport(map[string]interface{}{"endpoint": upstream.URL})
+	require.NoError(t, err)
+	rpc := tr.(*transport.JSONRPCTransport)
+	require.NoError(t, rpc.Connect(context.Background()))
+
+	ctx := types.WithUpstreamHeaders(context.Background(), http.Header{"X-Tenant-Id": {"acme"}})
+	_, err = rpc.SendRequest(ctx, "tools/list", nil)
+	require.NoError(t, err)
+
+	header := <-received
+	assert.Equal(t, "acme", header.Get("X-Tenant-Id"))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+}
@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryIdentitySettings keeps identity injection settings by server
+type memoryIdentitySettings struct {
+	settings map[string]*types.IdentityInjectionSettings
+}
+
+func (m *memoryIdentitySettings) ServerExists(orgID, serverID string) (bool, error) {
+	return orgID == "org-1" && serverID == "srv-1", nil
+}
+
+func (m *memoryIdentitySettings) Get(serverID string) (*types.IdentityInjectionSettings, error) {
+	return m.settings[serverID], nil
+}
+
+func (m *memoryIdentitySettings) Upsert(settings *types.IdentityInjectionSettings) error {
+	m.settings[settings.ServerID] = settings
+	return nil
+}
+
+func (m *memoryIdentitySettings) Delete(serverID string) (bool, error) {
+	_, ok := m.settings[serverID]
+	delete(m.settings, serverID)
+	return ok, nil
+}
+
+func identityCaller() context.Context {
+	return types.WithPrincipal(context.Background(), &types.Principal{
+		Type:           types.PrincipalTypeUser,
+		UserID:         "user-1",
+		Email:          "dev@example.com",
+		OrganizationID: "org-1",
+		Labels:         types.Labels{"team": "search"},
+	})
+}
+
+func TestIdentityInjectionSettingsValidation(t *testing.T) {
+	svc := services.NewIdentityInjectionServiceWithStore(&memoryIdentitySettings{settings: map[string]*types.IdentityInjectionSettings{}})
+	ctx := context.Background()
+
+	settings, err := svc.GetSettings(ctx, "org-1", "srv-1")
+	require.NoError(t, err)
+	assert.Equal(t, types.IdentityTargetOff, settings.Target)
+
+	_, err = svc.GetSettings(ctx, "org-2", "srv-1")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	invalid := []*types.SetIdentityInjectionRequest{
+		{Target: types.IdentityTargetMeta, Fields: []string{"password"}},
+		{Target: types.IdentityTargetMeta, Argument: "caller", Fields: []string{"email"}},
+		{Target: types.IdentityTargetArgument, Fields: []string{"email"}},
+		{Target: types.IdentityTargetArgument, Argument: "has space", Fields: []string{"email"}},
+	}
+	for _, req := range invalid {
+		_, err := svc.SetSettings(ctx, "org-1", "srv-1", req)
+		assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "%+v", req)
+	}
+
+	settings, err = svc.SetSettings(ctx, "org-1", "srv-1", &types.SetIdentityInjectionRequest{
+		Target: types.IdentityTargetMeta,
+		Fields: []string{"email", "user_id", "email"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"email", "user_id"}, settings.Fields)
+
+	require.NoError(t, svc.DeleteSettings(ctx, "org-1", "srv-1"))
+	err = svc.DeleteSettings(ctx, "org-1", "srv-1")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+}
+
+func TestIdentityInjectionMeta(t *testing.T) {
+	store := &memoryIdentitySettings{settings: map[string]*types.IdentityInjectionSettings{
+		"srv-1": {ServerID: "srv-1", Target: types.IdentityTargetMeta, Fields: []string{"user_id", "email", "namespace_id"}},
+	}}
+	svc := services.NewIdentityInjectionServiceWithStore(store)
+
+	args := map[string]interface{}{"query": "x"}
+	out, meta, err := svc.InjectIdentity(identityCaller(), "ns-1", "srv-1", args)
+	require.NoError(t, err)
+	assert.Equal(t, args, out)
+
+	identity, ok := meta[types.IdentityMetaKey].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{
+		"version":      float64(types.IdentityAssertionVersion),
+		"iss":          types.IdentityAssertionIssuer,
+		"user_id":      "user-1",
+		"email":        "dev@example.com",
+		"namespace_id": "ns-1",
+	}, identity, "only selected fields are asserted")
+
+	_, meta, err = svc.InjectIdentity(context.Background(), "ns-1", "srv-1", args)
+	require.NoError(t, err)
+	assert.Nil(t, meta, "unauthenticated calls assert nothing")
+
+	_, meta, err = svc.InjectIdentity(identityCaller(), "ns-1", "srv-2", args)
+	require.NoError(t, err)
+	assert.Nil(t, meta, "servers without settings receive nothing")
+}
+
+func TestIdentityInjectionArgumentReplacesCallerValue(t *testing.T) {
+	store := &memoryIdentitySettings{settings: map[string]*types.IdentityInjectionSettings{
+		"srv-1": {ServerID: "srv-1", Target: types.IdentityTargetArgument, Argument: "caller", Fields: []string{"email", "labels"}},
+	}}
+	svc := services.NewIdentityInjectionServiceWithStore(store)
+
+	args := map[string]interface{}{"query": "x", "caller": map[string]interface{}{"email": "admin@example.com"}}
+	out, meta, err := svc.InjectIdentity(identityCaller(), "ns-1", "srv-1", args)
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+	assert.Equal(t, "x", out["query"])
+	identity := out["caller"].(map[string]interface{})
+	assert.Equal(t, "dev@example.com", identity["email"])
+	assert.Equal(t, map[string]interface{}{"team": "search"}, identity["labels"])
+	assert.Equal(t, "admin@example.com", args["caller"].(map[string]interface{})["email"], "the caller's arguments are not modified")
+
+	out, _, err = svc.InjectIdentity(context.Background(), "ns-1", "srv-1", args)
+	require.NoError(t, err)
+	assert.NotContains(t, out, "caller", "spoofed identities are removed from unauthenticated calls")
+}
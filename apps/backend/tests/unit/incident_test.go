@@ -0,0 +1,259 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const incidentServerID = "3f2b6c1e-8a4d-4e5f-9b7a-1c2d3e4f5a6b"
+
+// memoryIncidents keeps incidents in memory, mirroring IncidentService
+type memoryIncidents struct {
+	incidents []*types.Incident
+	filters   []*types.IncidentFilter
+}
+
+func (m *memoryIncidents) Create(ctx context.Context, orgID, createdBy string, req *types.CreateIncidentRequest) (*types.Incident, error) {
+	incident := &types.Incident{
+		ID:             fmt.Sprintf("incident-%d", len(m.incidents)+1),
+		OrganizationID: orgID,
+		Title:          req.Title,
+		Severity:       req.Severity,
+		StartedAt:      time.Now(),
+		EndedAt:        req.EndedAt,
+		ServerIDs:      req.ServerIDs,
+		NamespaceIDs:   req.NamespaceIDs,
+		CreatedBy:      createdBy,
+	}
+	if req.StartedAt != nil {
+		incident.StartedAt = *req.StartedAt
+	}
+	m.incidents = append(m.incidents, incident)
+	return incident, nil
+}
+
+func (m *memoryIncidents) Get(ctx context.Context, orgID, id string) (*types.Incident, error) {
+	for _, incident := range m.incidents {
+		if incident.ID == id && incident.OrganizationID == orgID {
+			return incident, nil
+		}
+	}
+	return nil, types.NewNotFoundError("incident not found")
+}
+
+func (m *memoryIncidents) List(ctx context.Context, filter *types.IncidentFilter) ([]*types.Incident, error) {
+	m.filters = append(m.filters, filter)
+	incidents := []*types.Incident{}
+	for _, incident := range m.incidents {
+		if incident.OrganizationID == filter.OrganizationID {
+			incidents = append(incidents, incident)
+		}
+	}
+	return incidents, nil
+}
+
+func (m *memoryIncidents) Update(ctx context.Context, orgID, id string, req *types.UpdateIncidentRequest) (*types.Incident, error) {
+	incident, err := m.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.EndedAt != nil {
+		incident.EndedAt = req.EndedAt
+	}
+	return incident, nil
+}
+
+func (m *memoryIncidents) Delete(ctx context.Context, orgID, id string) error {
+	_, err := m.Get(ctx, orgID, id)
+	return err
+}
+
+// ForNamespace returns every incident, as the status page only renders them
+func (m *memoryIncidents) ForNamespace(ctx context.Context, orgID, namespaceID string, serverIDs []string, since time.Time) ([]*types.Incident, error) {
+	return m.incidents, nil
+}
+
+type staticHealthHistory []*types.HealthCheck
+
+func (h staticHealthHistory) GetHealthHistory(serverID string, limit int) ([]*types.HealthCheck, error) {
+	return h, nil
+}
+
+func newIncidentRouter(incidents *memoryIncidents, history staticHealthHistory) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := handlers.NewIncidentHandler(incidents, history)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "admin-1")
+		c.Set("organization_id", "org-1")
+		c.Next()
+	})
+	router.GET("/incidents", h.ListIncidents)
+	router.POST("/incidents", h.CreateIncident)
+	router.PUT("/incidents/:id", h.UpdateIncident)
+	router.GET("/servers/:id/health-history", h.GetServerHealthHistory)
+	return router
+}
+
+func TestIncidentCreateValidation(t *testing.T) {
+	incidents := &memoryIncidents{}
+	router := newIncidentRouter(incidents, nil)
+
+	w := postJSONBody(router, "/incidents", `{"title":"DB failover","severity":"catastrophic"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = postJSONBody(router, "/incidents", `{"title":"DB failover","severity":"major","server_ids":["not-a-uuid"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = postJSONBody(router, "/incidents", `{"title":"DB failover","severity":"major","postmortem_url":"wiki page"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, incidents.incidents)
+
+	w = postJSONBody(router, "/incidents", `{"title":"DB failover","severity":"major",
+		"server_ids":["`+incidentServerID+`"],"postmortem_url":"https://wiki.example.com/pm/42"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.Len(t, incidents.incidents, 1)
+	assert.Equal(t, "admin-1", incidents.incidents[0].CreatedBy)
+	assert.Equal(t, "org-1", incidents.incidents[0].OrganizationID)
+	assert.True(t, incidents.incidents[0].Ongoing())
+}
+
+func TestIncidentListTimelineFilter(t *testing.T) {
+	incidents := &memoryIncidents{}
+	router := newIncidentRouter(incidents, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/incidents?from=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+		"/incidents?from=2026-10-01T00:00:00Z&to=2026-10-02T00:00:00Z&ongoing=true", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, incidents.filters, 1)
+	filter := incidents.filters[0]
+	assert.Equal(t, "org-1", filter.OrganizationID)
+	assert.True(t, filter.OngoingOnly)
+	require.NotNil(t, filter.From)
+	require.NotNil(t, filter.To)
+	assert.Equal(t, 24*time.Hour, filter.To.Sub(*filter.From))
+}
+
+func TestServerHealthHistoryAnnotatedWithIncidents(t *testing.T) {
+	oldest := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	history := staticHealthHistory{
+		{ServerID: incidentServerID, Status: types.HealthStatusHealthy, CheckedAt: oldest.Add(time.Minute)},
+		{ServerID: incidentServerID, Status: types.HealthStatusUnhealthy, CheckedAt: oldest},
+	}
+	incidents := &memoryIncidents{incidents: []*types.Incident{{
+		ID:             "incident-1",
+		OrganizationID: "org-1",
+		Title:          "Upstream outage",
+		Severity:       types.IncidentSeverityCritical,
+		StartedAt:      oldest,
+		ServerIDs:      []string{incidentServerID},
+	}}}
+	router := newIncidentRouter(incidents, history)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/servers/not-a-uuid/health-history", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/servers/"+incidentServerID+"/health-history?limit=2", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data types.ServerHealthHistory `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Data.Checks, 2)
+	require.Len(t, resp.Data.Incidents, 1)
+	assert.Equal(t, "Upstream outage", resp.Data.Incidents[0].Title)
+
+	// Incidents are looked up over the period the checks cover
+	require.Len(t, incidents.filters, 1)
+	assert.Equal(t, incidentServerID, incidents.filters[0].ServerID)
+	assert.Equal(t, oldest, *incidents.filters[0].From)
+}
+
+func TestIncidentStatusIncident(t *testing.T) {
+	ended := time.Now()
+	incident := &types.Incident{
+		Title:         "Partial outage",
+		Severity:      types.IncidentSeverityMajor,
+		Notes:         "internal details",
+		PostmortemURL: "https://wiki.example.com/pm/42",
+	}
+	public := incident.StatusIncident()
+	assert.Equal(t, types.ServiceStatusDegraded, public.Impact)
+	assert.Equal(t, types.IncidentStateOngoing, public.State)
+	assert.Equal(t, types.IncidentSourceDeclared, public.Source)
+
+	incident.Severity = types.IncidentSeverityCritical
+	incident.EndedAt = &ended
+	public = incident.StatusIncident()
+	assert.Equal(t, types.ServiceStatusDown, public.Impact)
+	assert.Equal(t, types.IncidentStateResolved, public.State)
+
+	body, err := json.Marshal(public)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "internal details")
+	assert.NotContains(t, string(body), "wiki.example.com")
+}
+
+type staticEndpointResolver map[string]*types.EndpointConfig
+
+func (r staticEndpointResolver) ResolveEndpoint(ctx context.Context, name string) (*types.EndpointConfig, error) {
+	config, ok := r[name]
+	if !ok {
+		return nil, fmt.Errorf("endpoint not found")
+	}
+	return config, nil
+}
+
+func TestStatusServiceDeclaredIncidents(t *testing.T) {
+	namespace := &types.Namespace{ID: "ns-1", Name: "shop"}
+	resolver := staticEndpointResolver{
+		"shop": {
+			Endpoint:  &types.Endpoint{Name: "shop", OrganizationID: "org-1", IsActive: true, EnableStatusPage: true},
+			Namespace: namespace,
+		},
+		"private": {
+			Endpoint:  &types.Endpoint{Name: "private", OrganizationID: "org-1", IsActive: true},
+			Namespace: namespace,
+		},
+	}
+	incidents := &memoryIncidents{incidents: []*types.Incident{{
+		Title:        "Payments provider outage",
+		Severity:     types.IncidentSeverityCritical,
+		StartedAt:    time.Now().Add(-time.Hour),
+		NamespaceIDs: []string{"ns-1"},
+	}}}
+
+	statuses := services.NewStatusService(nil, resolver, time.Minute)
+	statuses.SetIncidents(incidents)
+
+	_, err := statuses.GetStatus(context.Background(), "private")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+	_, err = statuses.GetStatus(context.Background(), "unknown")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	status, err := statuses.GetStatus(context.Background(), "shop")
+	require.NoError(t, err)
+	assert.Equal(t, types.ServiceStatusDown, status.Status, "ongoing critical incidents take the endpoint down")
+	require.Len(t, status.Incidents, 1)
+	assert.Equal(t, "Payments provider outage", status.Incidents[0].Title)
+	assert.Equal(t, types.IncidentSourceDeclared, status.Incidents[0].Source)
+	assert.Len(t, status.Uptime, 3)
+}
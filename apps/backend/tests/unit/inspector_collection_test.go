@@ -0,0 +1,169 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryInspectorCollections struct {
+	servers     map[string]string
+	collections map[string]*types.InspectorCollection
+	nextID      int
+}
+
+func newMemoryInspectorCollections() *memoryInspectorCollections {
+	return &memoryInspectorCollections{
+		servers:     map[string]string{"server-1": "org-1", "server-2": "org-1", "server-3": "org-2"},
+		collections: map[string]*types.InspectorCollection{},
+	}
+}
+
+func (m *memoryInspectorCollections) ServerExists(orgID, serverID string) (bool, error) {
+	return m.servers[serverID] == orgID, nil
+}
+
+func (m *memoryInspectorCollections) List(orgID, serverID string) ([]*types.InspectorCollection, error) {
+	var collections []*types.InspectorCollection
+	for _, collection := range m.collections {
+		if collection.OrganizationID == orgID && collection.ServerID == serverID {
+			copied := *collection
+			collections = append(collections, &copied)
+		}
+	}
+	return collections, nil
+}
+
+func (m *memoryInspectorCollections) Get(orgID, id string) (*types.InspectorCollection, error) {
+	collection, ok := m.collections[id]
+	if !ok || collection.OrganizationID != orgID {
+		return nil, nil
+	}
+	copied := *collection
+	return &copied, nil
+}
+
+func (m *memoryInspectorCollections) Create(collection *types.InspectorCollection) error {
+	m.nextID++
+	collection.ID = fmt.Sprintf("collection-%d", m.nextID)
+	copied := *collection
+	m.collections[collection.ID] = &copied
+	return nil
+}
+
+func (m *memoryInspectorCollections) Update(collection *types.InspectorCollection) error {
+	copied := *collection
+	m.collections[collection.ID] = &copied
+	return nil
+}
+
+func (m *memoryInspectorCollections) Delete(orgID, id string) (bool, error) {
+	collection, ok := m.collections[id]
+	if !ok || collection.OrganizationID != orgID {
+		return false, nil
+	}
+	delete(m.collections, id)
+	return true, nil
+}
+
+func TestInspectorCollectionService_Create(t *testing.T) {
+	ctx := context.Background()
+	service := services.NewInspectorCollectionServiceWithStore(newMemoryInspectorCollections())
+
+	collection, err := service.CreateCollection(ctx, "org-1", "server-1", "user-1", &types.CreateInspectorCollectionRequest{
+		Name: "  Smoke test ",
+		Requests: []types.InspectorCollectionRequest{
+			{Name: "List tools", Method: "tools/list"},
+			{Method: "tools/call", Params: map[string]interface{}{"name": "echo"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Smoke test", collection.Name)
+	assert.Equal(t, "server-1", collection.ServerID)
+	assert.Equal(t, "user-1", collection.CreatedBy)
+	assert.Len(t, collection.Requests, 2)
+
+	empty, err := service.CreateCollection(ctx, "org-1", "server-1", "user-1", &types.CreateInspectorCollectionRequest{Name: "Empty"})
+	require.NoError(t, err)
+	assert.NotNil(t, empty.Requests)
+
+	// Names are unique per server, ignoring case
+	_, err = service.CreateCollection(ctx, "org-1", "server-1", "user-1", &types.CreateInspectorCollectionRequest{Name: "smoke TEST"})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+	_, err = service.CreateCollection(ctx, "org-1", "server-2", "user-1", &types.CreateInspectorCollectionRequest{Name: "Smoke test"})
+	assert.NoError(t, err)
+
+	collections, err := service.ListCollections(ctx, "org-1", "server-1")
+	require.NoError(t, err)
+	assert.Len(t, collections, 2)
+}
+
+func TestInspectorCollectionService_Validation(t *testing.T) {
+	ctx := context.Background()
+	service := services.NewInspectorCollectionServiceWithStore(newMemoryInspectorCollections())
+
+	_, err := service.CreateCollection(ctx, "org-1", "server-1", "user-1", &types.CreateInspectorCollectionRequest{Name: "   "})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	_, err = service.CreateCollection(ctx, "org-1", "server-1", "user-1", &types.CreateInspectorCollectionRequest{
+		Name:     "No method",
+		Requests: []types.InspectorCollectionRequest{{Method: "ping"}, {Method: " "}},
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	tooMany := make([]types.InspectorCollectionRequest, types.MaxInspectorCollectionRequests+1)
+	for i := range tooMany {
+		tooMany[i].Method = "ping"
+	}
+	_, err = service.CreateCollection(ctx, "org-1", "server-1", "user-1", &types.CreateInspectorCollectionRequest{Name: "Too many", Requests: tooMany})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	// Servers of other organizations are not found
+	_, err = service.CreateCollection(ctx, "org-1", "server-3", "user-1", &types.CreateInspectorCollectionRequest{Name: "Foreign"})
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+	_, err = service.ListCollections(ctx, "org-1", "server-3")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+}
+
+func TestInspectorCollectionService_UpdateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	service := services.NewInspectorCollectionServiceWithStore(newMemoryInspectorCollections())
+
+	first, err := service.CreateCollection(ctx, "org-1", "server-1", "user-1", &types.CreateInspectorCollectionRequest{Name: "First"})
+	require.NoError(t, err)
+	second, err := service.CreateCollection(ctx, "org-1", "server-1", "user-1", &types.CreateInspectorCollectionRequest{Name: "Second"})
+	require.NoError(t, err)
+
+	description := "Calls echo"
+	requests := []types.InspectorCollectionRequest{{Method: "tools/call", Params: map[string]interface{}{"name": "echo"}}}
+	updated, err := service.UpdateCollection(ctx, "org-1", first.ID, &types.UpdateInspectorCollectionRequest{
+		Description: &description,
+		Requests:    &requests,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "First", updated.Name)
+	assert.Equal(t, "Calls echo", updated.Description)
+	assert.Len(t, updated.Requests, 1)
+
+	// Keeping its own name is not a conflict, taking another's is
+	_, err = service.UpdateCollection(ctx, "org-1", first.ID, &types.UpdateInspectorCollectionRequest{Name: "FIRST"})
+	assert.NoError(t, err)
+	_, err = service.UpdateCollection(ctx, "org-1", first.ID, &types.UpdateInspectorCollectionRequest{Name: second.Name})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+
+	// Other organizations cannot see or delete the collection
+	_, err = service.GetCollection(ctx, "org-2", first.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+	err = service.DeleteCollection(ctx, "org-2", first.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	require.NoError(t, service.DeleteCollection(ctx, "org-1", first.ID))
+	_, err = service.GetCollection(ctx, "org-1", first.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+}
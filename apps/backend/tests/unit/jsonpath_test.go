@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/jsonpath"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mcp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeJSONDoc(t *testing.T, raw string) interface{} {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(raw), &doc))
+	return doc
+}
+
+func TestJSONPathDeleteAndReplace(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		drop     bool
+		expected string
+	}{
+		{"child", "$.user.id", true, `{"user":{"name":"ada"},"items":[{"id":1,"secret":"a"},{"id":2,"secret":"b"}]}`},
+		{"bracket name", "$['user']['name']", false, `{"user":{"id":7,"name":"[REDACTED]"},"items":[{"id":1,"secret":"a"},{"id":2,"secret":"b"}]}`},
+		{"wildcard", "$.items[*].secret", true, `{"user":{"id":7,"name":"ada"},"items":[{"id":1},{"id":2}]}`},
+		{"negative index", "$.items[-1]", true, `{"user":{"id":7,"name":"ada"},"items":[{"id":1,"secret":"a"}]}`},
+		{"recursive descent", "$..id", false, `{"user":{"id":"[REDACTED]","name":"ada"},"items":[{"id":"[REDACTED]","secret":"a"},{"id":"[REDACTED]","secret":"b"}]}`},
+		{"missing path", "$.nothing.here", true, `{"user":{"id":7,"name":"ada"},"items":[{"id":1,"secret":"a"},{"id":2,"secret":"b"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := decodeJSONDoc(t, `{"user":{"id":7,"name":"ada"},"items":[{"id":1,"secret":"a"},{"id":2,"secret":"b"}]}`)
+			path, err := jsonpath.Parse(tt.path)
+			require.NoError(t, err)
+			if tt.drop {
+				doc = path.Delete(doc)
+			} else {
+				doc = path.Replace(doc, types.RedactedValue)
+			}
+			assert.Equal(t, decodeJSONDoc(t, tt.expected), doc)
+		})
+	}
+}
+
+func TestJSONPathRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{"", "user.id", "$", "$.", "$[", "$[?(@.id)]", "$.a[x]"} {
+		_, err := jsonpath.Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestApplyResponsePolicy(t *testing.T) {
+	result := mcp.ToolsCallResult{
+		Content: []mcp.ToolCallContent{{Type: "text", Text: "done"}},
+		StructuredContent: map[string]interface{}{
+			"ticket":      "OPS-1",
+			"internal_id": "db-42",
+			"credentials": map[string]interface{}{"token": "s3cr3t"},
+		},
+	}
+	policy := &types.ToolResponsePolicy{Rules: []types.ResponseRule{
+		{Path: "$.structuredContent.internal_id", Action: types.ResponseActionDrop},
+		{Path: "$..token", Action: types.ResponseActionMask},
+	}}
+
+	transformed, err := services.ApplyResponsePolicy(policy, result)
+	require.NoError(t, err)
+	structured := transformed.(map[string]interface{})["structuredContent"].(map[string]interface{})
+	assert.NotContains(t, structured, "internal_id")
+	assert.Equal(t, "OPS-1", structured["ticket"])
+	assert.Equal(t, types.RedactedValue, structured["credentials"].(map[string]interface{})["token"])
+
+	// The original result is left untouched
+	assert.Equal(t, "db-42", result.StructuredContent.(map[string]interface{})["internal_id"])
+
+	unchanged, err := services.ApplyResponsePolicy(nil, result)
+	require.NoError(t, err)
+	assert.Equal(t, result, unchanged)
+}
@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/jsonschema"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func violationKeywords(violations []types.SchemaViolation) map[string]string {
+	keywords := make(map[string]string, len(violations))
+	for _, v := range violations {
+		keywords[v.Path] = v.Keyword
+	}
+	return keywords
+}
+
+func TestJSONSchemaValidatesObjects(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"required":             []interface{}{"query", "limit"},
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string", "minLength": 1, "pattern": "^[a-z ]+$"},
+			"limit": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 100},
+			"mode":  map[string]interface{}{"enum": []interface{}{"fast", "exact"}},
+			"tags": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"maxItems":    2,
+				"uniqueItems": true,
+			},
+		},
+	}
+
+	assert.Empty(t, jsonschema.Validate(schema, map[string]interface{}{
+		"query": "open issues", "limit": float64(10), "mode": "fast", "tags": []interface{}{"a", "b"},
+	}))
+
+	violations := jsonschema.Validate(schema, map[string]interface{}{
+		"query": "Open!",
+		"limit": 1.5,
+		"mode":  "slow",
+		"tags":  []interface{}{"a", "a", float64(3)},
+		"extra": true,
+	})
+	assert.Equal(t, map[string]string{
+		"/query":  "pattern",
+		"/limit":  "type",
+		"/mode":   "enum",
+		"/tags":   "maxItems",
+		"/tags/1": "uniqueItems",
+		"/tags/2": "type",
+		"/extra":  "additionalProperties",
+	}, violationKeywords(violations))
+
+	violations = jsonschema.Validate(schema, map[string]interface{}{})
+	assert.Equal(t, map[string]string{"/query": "required", "/limit": "required"}, violationKeywords(violations))
+
+	violations = jsonschema.Validate(schema, []interface{}{})
+	require.Len(t, violations, 1)
+	assert.Equal(t, "/", violations[0].Path)
+	assert.Equal(t, "expected object, got array", violations[0].Message)
+}
+
+func TestJSONSchemaCompositionAndRefs(t *testing.T) {
+	schema := map[string]interface{}{
+		"$defs": map[string]interface{}{
+			"id": map[string]interface{}{"type": []interface{}{"string", "integer"}},
+		},
+		"type": "object",
+		"properties": map[string]interface{}{
+			"target": map[string]interface{}{"$ref": "#/$defs/id"},
+			"value": map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{"type": "number", "multipleOf": 5},
+				},
+			},
+			"name": map[string]interface{}{"not": map[string]interface{}{"const": "root"}},
+		},
+	}
+
+	assert.Empty(t, jsonschema.Validate(schema, map[string]interface{}{"target": float64(7), "value": float64(10), "name": "ops"}))
+
+	violations := jsonschema.Validate(schema, map[string]interface{}{"target": true, "value": float64(7), "name": "root"})
+	assert.Equal(t, map[string]string{"/target": "type", "/value": "oneOf", "/name": "not"}, violationKeywords(violations))
+
+	broken := map[string]interface{}{"$ref": "#/$defs/missing"}
+	require.Len(t, jsonschema.Validate(broken, "x"), 1)
+
+	// Self-referencing schemas terminate
+	loop := map[string]interface{}{"$ref": "#"}
+	assert.NotEmpty(t, jsonschema.Validate(loop, "x"))
+
+	assert.Empty(t, jsonschema.Validate(nil, "anything"))
+}
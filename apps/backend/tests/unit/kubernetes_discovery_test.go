@@ -0,0 +1,233 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/discovery"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRegistrar keeps servers in memory, mirroring the discovery service
+type memoryRegistrar struct {
+	servers map[string]*types.MCPServer
+	mu      sync.Mutex
+}
+
+func newMemoryRegistrar() *memoryRegistrar {
+	return &memoryRegistrar{servers: map[string]*types.MCPServer{}}
+}
+
+func (m *memoryRegistrar) ListServers(orgID string) ([]*types.MCPServer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var servers []*types.MCPServer
+	for _, server := range m.servers {
+		if server.IsActive {
+			copied := *server
+			servers = append(servers, &copied)
+		}
+	}
+	return servers, nil
+}
+
+func (m *memoryRegistrar) RegisterServer(orgID string, req *types.CreateMCPServerRequest) (*types.MCPServer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	server := &types.MCPServer{
+		ID: uuid.NewString(), Name: req.Name, Description: req.Description, Protocol: req.Protocol,
+		URL: req.URL, Metadata: req.Metadata, Status: types.ServerStatusInactive, IsActive: true,
+	}
+	m.servers[server.ID] = server
+	copied := *server
+	return &copied, nil
+}
+
+func (m *memoryRegistrar) UpdateServer(serverID string, req *types.UpdateMCPServerRequest) (*types.MCPServer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	server := m.servers[serverID]
+	server.Name, server.URL, server.Protocol = req.Name, req.URL, req.Protocol
+	server.Description, server.Metadata = req.Description, req.Metadata
+	copied := *server
+	return &copied, nil
+}
+
+func (m *memoryRegistrar) UnregisterServer(serverID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.servers[serverID].IsActive = false
+	return nil
+}
+
+func (m *memoryRegistrar) SetServerReadiness(serverID string, ready bool, message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.servers[serverID].Status = types.ServerStatusUnhealthy
+	if ready {
+		m.servers[serverID].Status = types.ServerStatusActive
+	}
+	return nil
+}
+
+func (m *memoryRegistrar) byName(name string) *types.MCPServer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, server := range m.servers {
+		if server.Name == name && server.IsActive {
+			return server
+		}
+	}
+	return nil
+}
+
+// fakeKubernetes serves Service and EndpointSlice lists and watches
+type fakeKubernetes struct {
+	server   *httptest.Server
+	services []map[string]interface{}
+	slices   []map[string]interface{}
+	lists    atomic.Int32
+	mu       sync.Mutex
+}
+
+func newFakeKubernetes(t *testing.T) *fakeKubernetes {
+	k := &fakeKubernetes{}
+	k.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "omnimesh.io/mcp-server=true", r.URL.Query().Get("labelSelector"))
+		if r.URL.Query().Get("watch") == "true" {
+			// Every watch reports a change at once
+			_, _ = w.Write([]byte(`{"type":"MODIFIED","object":{}}` + "\n"))
+			return
+		}
+		k.lists.Add(1)
+		k.mu.Lock()
+		items := k.services
+		if r.URL.Path == "/apis/discovery.k8s.io/v1/namespaces/mcp/endpointslices" {
+			items = k.slices
+		} else if r.URL.Path != "/api/v1/namespaces/mcp/services" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		data, _ := json.Marshal(map[string]interface{}{"metadata": map[string]string{"resourceVersion": "42"}, "items": items})
+		k.mu.Unlock()
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(k.server.Close)
+	return k
+}
+
+func kubeService(name string, annotations map[string]string, ports ...map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": name, "namespace": "mcp", "uid": "uid-" + name, "annotations": annotations,
+		},
+		"spec": map[string]interface{}{"ports": ports},
+	}
+}
+
+func kubeSlice(service string, ready ...bool) map[string]interface{} {
+	endpoints := make([]map[string]interface{}, len(ready))
+	for i, r := range ready {
+		endpoints[i] = map[string]interface{}{"conditions": map[string]interface{}{"ready": r}}
+	}
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": service + "-abcde", "namespace": "mcp", "labels": map[string]string{"kubernetes.io/service-name": service},
+		},
+		"endpoints": endpoints,
+	}
+}
+
+func newTestKubernetesProvider(t *testing.T, k *fakeKubernetes, registrar *memoryRegistrar) *discovery.KubernetesProvider {
+	provider, err := discovery.NewKubernetesProvider(registrar, discovery.KubernetesConfig{
+		APIServer:  k.server.URL,
+		Namespaces: []string{"mcp"},
+	})
+	require.NoError(t, err)
+	return provider
+}
+
+func TestKubernetesDiscoveryRegistersServices(t *testing.T) {
+	k := newFakeKubernetes(t)
+	k.services = []map[string]interface{}{
+		kubeService("search", map[string]string{
+			discovery.AnnotationMCPName:     "search",
+			discovery.AnnotationMCPProtocol: "sse",
+			discovery.AnnotationMCPPort:     "mcp",
+			discovery.AnnotationMCPPath:     "sse",
+		}, map[string]interface{}{"name": "metrics", "port": 9090}, map[string]interface{}{"name": "mcp", "port": 8080}),
+		kubeService("weather", nil, map[string]interface{}{"port": 3000}),
+		kubeService("broken", map[string]string{discovery.AnnotationMCPProtocol: "stdio"}, map[string]interface{}{"port": 1}),
+	}
+	k.slices = []map[string]interface{}{kubeSlice("search", false, true), kubeSlice("weather", false)}
+
+	registrar := newMemoryRegistrar()
+	manual, err := registrar.RegisterServer("", &types.CreateMCPServerRequest{Name: "manual", Protocol: types.ProtocolHTTP})
+	require.NoError(t, err)
+	provider := newTestKubernetesProvider(t, k, registrar)
+
+	result, err := provider.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &discovery.KubernetesSyncResult{Registered: 2, Failed: 1}, result)
+
+	search := registrar.byName("search")
+	require.NotNil(t, search)
+	assert.Equal(t, types.ProtocolSSE, search.Protocol)
+	assert.Equal(t, "http://search.mcp.svc.cluster.local:8080/sse", search.URL)
+	assert.Equal(t, discovery.DiscoverySourceKubernetes, search.Metadata[discovery.MetadataDiscoverySource])
+	assert.Equal(t, types.ServerStatusActive, search.Status, "one pod is ready")
+
+	weather := registrar.byName("weather.mcp")
+	require.NotNil(t, weather)
+	assert.Equal(t, "http://weather.mcp.svc.cluster.local:3000", weather.URL)
+	assert.Equal(t, types.ServerStatusUnhealthy, weather.Status, "no pod is ready")
+
+	// Readiness and changes to the Service follow on the next sync
+	k.mu.Lock()
+	k.services = []map[string]interface{}{
+		kubeService("weather", map[string]string{discovery.AnnotationMCPPath: "/mcp"}, map[string]interface{}{"port": 3000}),
+	}
+	k.slices = []map[string]interface{}{kubeSlice("weather", true)}
+	k.mu.Unlock()
+
+	result, err = provider.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &discovery.KubernetesSyncResult{Updated: 1, Deregistered: 1}, result)
+	assert.Nil(t, registrar.byName("search"))
+	assert.Equal(t, "http://weather.mcp.svc.cluster.local:3000/mcp", weather.URL)
+	assert.Equal(t, types.ServerStatusActive, weather.Status)
+	assert.NotNil(t, registrar.byName(manual.Name), "servers registered by hand are left alone")
+
+	// A failing API server deregisters nothing
+	k.server.Close()
+	_, err = provider.Sync(context.Background())
+	assert.Error(t, err)
+	assert.NotNil(t, registrar.byName("weather.mcp"))
+}
+
+func TestKubernetesDiscoveryResyncsOnWatchEvents(t *testing.T) {
+	k := newFakeKubernetes(t)
+	registrar := newMemoryRegistrar()
+	provider := newTestKubernetesProvider(t, k, registrar)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		provider.Run(ctx, nil)
+		close(done)
+	}()
+
+	// Each sync lists services and endpoint slices once
+	assert.Eventually(t, func() bool { return k.lists.Load() >= 4 }, 5*time.Second, 50*time.Millisecond)
+	cancel()
+	<-done
+}
@@ -0,0 +1,210 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLegalHolds keeps holds in memory, mirroring LegalHoldService
+type memoryLegalHolds struct {
+	holds []*types.LegalHold
+}
+
+func (m *memoryLegalHolds) List(ctx context.Context, includeReleased bool) ([]*types.LegalHold, error) {
+	var holds []*types.LegalHold
+	for _, hold := range m.holds {
+		if includeReleased || hold.Active() {
+			holds = append(holds, hold)
+		}
+	}
+	return holds, nil
+}
+
+func (m *memoryLegalHolds) Active(ctx context.Context, orgID string) (*types.LegalHold, error) {
+	for _, hold := range m.holds {
+		if hold.OrganizationID == orgID && hold.Active() {
+			return hold, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryLegalHolds) CheckNotHeld(ctx context.Context, orgID string) error {
+	if hold, _ := m.Active(ctx, orgID); hold != nil {
+		return types.NewLegalHoldError(orgID)
+	}
+	return nil
+}
+
+func (m *memoryLegalHolds) Place(ctx context.Context, orgID, placedBy, reason string) (*types.LegalHold, error) {
+	if hold, _ := m.Active(ctx, orgID); hold != nil {
+		return nil, types.NewConflictError("organization is already under legal hold")
+	}
+	hold := &types.LegalHold{
+		ID:             "hold-" + orgID,
+		OrganizationID: orgID,
+		Reason:         reason,
+		PlacedBy:       placedBy,
+		PlacedAt:       time.Now(),
+	}
+	m.holds = append(m.holds, hold)
+	return hold, nil
+}
+
+func (m *memoryLegalHolds) Release(ctx context.Context, orgID, releasedBy, reason string) (*types.LegalHold, error) {
+	hold, _ := m.Active(ctx, orgID)
+	if hold == nil {
+		return nil, types.NewNotFoundError("organization is not under legal hold")
+	}
+	now := time.Now()
+	hold.ReleasedAt = &now
+	hold.ReleasedBy = releasedBy
+	hold.ReleaseReason = reason
+	return hold, nil
+}
+
+func newLegalHoldRouter(holds *memoryLegalHolds, auditor *recordingAuditor) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := handlers.NewLegalHoldHandler(holds, auditor)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "platform-admin")
+		c.Set("organization_id", "org-platform")
+		c.Next()
+	})
+	router.GET("/legal-holds", h.ListHolds)
+	router.GET("/legal-holds/:org_id", h.GetHold)
+	router.POST("/legal-holds/:org_id", h.PlaceHold)
+	router.POST("/legal-holds/:org_id/release", h.ReleaseHold)
+	return router
+}
+
+func postJSONBody(router http.Handler, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLegalHoldLifecycle(t *testing.T) {
+	holds := &memoryLegalHolds{}
+	auditor := &recordingAuditor{}
+	router := newLegalHoldRouter(holds, auditor)
+
+	w := postJSONBody(router, "/legal-holds/org-1", `{"reason":"Litigation 2026-117"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = postJSONBody(router, "/legal-holds/org-1", `{"reason":"again"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/legal-holds/org-1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data types.LegalHold `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "platform-admin", resp.Data.PlacedBy)
+	assert.Equal(t, "Litigation 2026-117", resp.Data.Reason)
+
+	w = postJSONBody(router, "/legal-holds/org-1/release", `{"reason":"Case closed"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/legal-holds/org-1", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/legal-holds?include_released=true", nil))
+	assert.Contains(t, w.Body.String(), "Case closed")
+
+	// Every attempt lands in the held organization's audit trail
+	require.Len(t, auditor.events, 3)
+	for _, event := range auditor.events {
+		assert.Equal(t, "org-1", event.OrganizationID)
+		assert.Equal(t, "legal_hold", event.Resource)
+		assert.Equal(t, types.AuditSeverityCritical, event.Severity)
+		assert.Equal(t, "org-platform", event.Details["actor_organization_id"])
+	}
+	assert.True(t, auditor.events[0].Success)
+	assert.False(t, auditor.events[1].Success)
+	assert.Equal(t, "release", auditor.events[2].Action)
+}
+
+func TestLegalHoldRequiresReason(t *testing.T) {
+	holds := &memoryLegalHolds{}
+	auditor := &recordingAuditor{}
+	router := newLegalHoldRouter(holds, auditor)
+
+	w := postJSONBody(router, "/legal-holds/org-1", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = postJSONBody(router, "/legal-holds/org-1/release", `{"reason":"not held"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, holds.holds)
+}
+
+func TestLegalHoldGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	holds := &memoryLegalHolds{}
+	_, err := holds.Place(context.Background(), "org-held", "admin", "audit")
+	require.NoError(t, err)
+
+	serve := func(orgID string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.DELETE("/namespaces/:id", func(c *gin.Context) {
+			c.Set("organization_id", orgID)
+			c.Next()
+		}, middleware.LegalHoldGuard(holds), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/namespaces/ns-1", nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve("org-free").Code)
+	w := serve("org-held")
+	assert.Equal(t, http.StatusLocked, w.Code)
+	assert.Contains(t, w.Body.String(), types.ErrCodeLegalHold)
+}
+
+func TestRequirePlatformAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := auth.NewMiddlewareWithInterface(nil, nil)
+	m.SetPlatformAdmins([]string{" Compliance@Example.com "})
+
+	serve := func(user *types.User) int {
+		router := gin.New()
+		router.GET("/legal-holds", func(c *gin.Context) {
+			if user != nil {
+				c.Set("user", user)
+			}
+			c.Next()
+		}, m.RequirePlatformAdmin(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/legal-holds", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(&types.User{Email: "compliance@example.com", Role: types.RoleAdmin}))
+	assert.Equal(t, http.StatusForbidden, serve(&types.User{Email: "other@example.com", Role: types.RoleAdmin}))
+	assert.Equal(t, http.StatusForbidden, serve(&types.User{Email: "compliance@example.com", Role: types.RoleUser}))
+	assert.Equal(t, http.StatusUnauthorized, serve(nil))
+}
@@ -0,0 +1,160 @@
+package unit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/license"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedSeatCounter int
+
+func (f fixedSeatCounter) CountSeats(ctx context.Context) (int, error) {
+	return int(f), nil
+}
+
+func newLicenseSigningKey(t *testing.T) (ed25519.PrivateKey, string) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+	return private, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func signLicense(t *testing.T, key ed25519.PrivateKey, claims *license.Claims) string {
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims).SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func licenseClaims(expiresAt time.Time) *license.Claims {
+	return &license.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "lic-1",
+			Issuer:    "https://licensing.omnimesh.example",
+			Subject:   "Acme Corp",
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Features: []string{types.FeatureSemanticSearch},
+		Seats:    5,
+	}
+}
+
+func TestLicenseCommunityMode(t *testing.T) {
+	manager, err := license.NewManager("", "", 0, fixedSeatCounter(100))
+	require.NoError(t, err)
+
+	status := manager.Status(context.Background())
+	assert.Equal(t, types.LicenseStatusCommunity, status.Status)
+	assert.Equal(t, 100, status.SeatsUsed)
+	assert.False(t, manager.IsEntitled(types.FeatureSemanticSearch))
+	assert.NoError(t, manager.CheckSeatAvailable(context.Background()))
+}
+
+func TestLicenseValidEntitlesFeaturesAndSeats(t *testing.T) {
+	key, publicPEM := newLicenseSigningKey(t)
+	manager, err := license.NewManager(signLicense(t, key, licenseClaims(time.Now().Add(30*24*time.Hour))), publicPEM, 0, fixedSeatCounter(4))
+	require.NoError(t, err)
+
+	status := manager.Status(context.Background())
+	assert.Equal(t, types.LicenseStatusValid, status.Status)
+	require.NotNil(t, status.License)
+	assert.Equal(t, "Acme Corp", status.License.Licensee)
+	assert.Equal(t, 5, status.SeatsLimit)
+	assert.Equal(t, 30, status.DaysRemaining)
+	assert.True(t, manager.IsEntitled(types.FeatureSemanticSearch))
+	assert.False(t, manager.IsEntitled(types.FeatureAsyncExecution))
+	assert.NoError(t, manager.CheckSeatAvailable(context.Background()))
+
+	full, err := license.NewManager(signLicense(t, key, licenseClaims(time.Now().Add(time.Hour))), publicPEM, 0, fixedSeatCounter(5))
+	require.NoError(t, err)
+	err = full.CheckSeatAvailable(context.Background())
+	var apiErr *types.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, types.ErrCodeLicenseSeatsExceeded, apiErr.Code)
+	assert.Equal(t, http.StatusForbidden, apiErr.Status)
+}
+
+func TestLicenseGracePeriodAndExpiry(t *testing.T) {
+	key, publicPEM := newLicenseSigningKey(t)
+
+	grace, err := license.NewManager(signLicense(t, key, licenseClaims(time.Now().Add(-24*time.Hour))), publicPEM, 7*24*time.Hour, nil)
+	require.NoError(t, err)
+	status := grace.Status(context.Background())
+	assert.Equal(t, types.LicenseStatusGracePeriod, status.Status)
+	assert.Equal(t, 6, status.DaysRemaining)
+	assert.True(t, grace.IsEntitled(types.FeatureSemanticSearch))
+
+	// The license's own grace_days overrides the configured grace period
+	claims := licenseClaims(time.Now().Add(-3 * 24 * time.Hour))
+	claims.GraceDays = 2
+	expired, err := license.NewManager(signLicense(t, key, claims), publicPEM, 7*24*time.Hour, nil)
+	require.NoError(t, err)
+	status = expired.Status(context.Background())
+	assert.Equal(t, types.LicenseStatusExpired, status.Status)
+	assert.Empty(t, status.Entitlements)
+	assert.False(t, expired.IsEntitled(types.FeatureSemanticSearch))
+}
+
+func TestLicenseRejectsForgedKeys(t *testing.T) {
+	_, publicPEM := newLicenseSigningKey(t)
+	otherKey, _ := newLicenseSigningKey(t)
+
+	manager, err := license.NewManager(signLicense(t, otherKey, licenseClaims(time.Now().Add(time.Hour))), publicPEM, 0, nil)
+	require.NoError(t, err)
+	status := manager.Status(context.Background())
+	assert.Equal(t, types.LicenseStatusInvalid, status.Status)
+	assert.NotEmpty(t, status.Message)
+	assert.False(t, manager.IsEntitled(types.FeatureSemanticSearch))
+
+	// Shared-secret signatures made with the public key are not accepted
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, licenseClaims(time.Now().Add(time.Hour))).SignedString([]byte(publicPEM))
+	require.NoError(t, err)
+	manager, err = license.NewManager(hmac, publicPEM, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, types.LicenseStatusInvalid, manager.Status(context.Background()).Status)
+
+	_, err = license.NewManager("some.license.key", "", 0, nil)
+	require.Error(t, err)
+	_, err = license.NewManager("some.license.key", "not a key", 0, nil)
+	require.Error(t, err)
+}
+
+func TestEnterpriseFeatureFlagsFollowLicense(t *testing.T) {
+	env := map[string]string{services.FeatureFlagEnvVar(types.FeatureSemanticSearch): "true"}
+	flags := services.NewFeatureFlagServiceWithStore(newMemoryFeatureFlagStore(), func(k string) string { return env[k] })
+
+	community, err := license.NewManager("", "", 0, nil)
+	require.NoError(t, err)
+	flags.SetEntitlements(community)
+
+	// The license wins over environment overrides
+	state, err := flags.GetFlag(context.Background(), flagOrgA, types.FeatureSemanticSearch)
+	require.NoError(t, err)
+	assert.False(t, state.Enabled)
+	assert.Equal(t, types.FeatureFlagSourceLicense, state.Source)
+
+	err = flags.SetFlag(context.Background(), types.FeatureSemanticSearch, flagOrgA, true, "admin")
+	var apiErr *types.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.Status)
+
+	key, publicPEM := newLicenseSigningKey(t)
+	licensed, err := license.NewManager(signLicense(t, key, licenseClaims(time.Now().Add(time.Hour))), publicPEM, 0, nil)
+	require.NoError(t, err)
+	flags.SetEntitlements(licensed)
+	assert.True(t, flags.IsEnabled(context.Background(), flagOrgA, types.FeatureSemanticSearch))
+}
@@ -0,0 +1,149 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubLimitsProvider struct {
+	principal *types.Principal
+}
+
+func (s *stubLimitsProvider) GetPlanQuotas(ctx context.Context, orgID string) (*types.PlanQuotas, error) {
+	return &types.PlanQuotas{
+		OrganizationID:   orgID,
+		PlanType:         "pro",
+		Servers:          types.QuotaUsage{Limit: 50, Used: 3},
+		Sessions:         types.QuotaUsage{Limit: 500, Used: 12},
+		LogRetentionDays: 30,
+	}, nil
+}
+
+func (s *stubLimitsProvider) GetPrincipalUsage(ctx context.Context, principal *types.Principal) (*types.PrincipalUsageSummary, error) {
+	s.principal = principal
+	return &types.PrincipalUsageSummary{Window: "24h0m0s", Requests: 42, ToolExecutions: 7}, nil
+}
+
+func TestIPRateLimitSetsStandardHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.IPRateLimitWithMemory(2))
+	router.GET("/test", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/test", http.NoBody)
+		req.RemoteAddr = "203.0.113.7:1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request()
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	reset, err := strconv.ParseInt(w.Header().Get("RateLimit-Reset"), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, reset > 0 && reset <= 60, "RateLimit-Reset should be seconds until reset, got %d", reset)
+
+	resetAt, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+	require.NoError(t, err)
+	assert.Greater(t, resetAt, time.Now().Unix()-1, "X-RateLimit-Reset stays a Unix timestamp")
+
+	request()
+	w = request()
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	var resp types.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, types.ErrCodeRateLimitExceeded, resp.Error.Code)
+}
+
+func TestApplyRateLimitBucketReportsTightestBucket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	reset := time.Now().Add(time.Minute)
+
+	assert.True(t, middleware.ApplyRateLimitBucket(c, types.RateLimitBucket{
+		Scope: types.RateLimitScopeIP, Limit: 100, Remaining: 5, ResetAt: reset,
+	}))
+	assert.True(t, middleware.ApplyRateLimitBucket(c, types.RateLimitBucket{
+		Scope: types.RateLimitScopeEndpoint, Limit: 1000, Remaining: 900, ResetAt: reset,
+	}))
+
+	assert.Equal(t, "100", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "5", w.Header().Get("RateLimit-Remaining"))
+	assert.Len(t, middleware.RateLimitBucketsFromContext(c), 2)
+
+	assert.False(t, middleware.ApplyRateLimitBucket(c, types.RateLimitBucket{
+		Scope: types.RateLimitScopeEndpoint, Limit: 10, Remaining: 0, ResetAt: reset, Exceeded: true,
+	}))
+	assert.Equal(t, "10", w.Header().Get("RateLimit-Limit"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestGetLimitsReportsCallerBucketsQuotasAndUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider := &stubLimitsProvider{}
+	handler := handlers.NewLimitsHandler(provider)
+
+	router := gin.New()
+	router.Use(middleware.IPRateLimitWithMemory(100))
+	router.GET("/api/auth/limits", func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("organization_id", flagOrgA)
+		c.Next()
+	}, handler.GetLimits)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/limits", http.NoBody)
+	req.RemoteAddr = "198.51.100.4:5678"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data    types.LimitsReport `json:"data"`
+		Success bool               `json:"success"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Success)
+
+	require.Len(t, resp.Data.Buckets, 1)
+	bucket := resp.Data.Buckets[0]
+	assert.Equal(t, types.RateLimitScopeIP, bucket.Scope)
+	assert.Equal(t, "198.51.100.4", bucket.Key)
+	assert.Equal(t, int64(100), bucket.Limit)
+	assert.Equal(t, int64(99), bucket.Remaining)
+	assert.Equal(t, "1m0s", bucket.Window)
+
+	require.NotNil(t, resp.Data.Quotas)
+	assert.Equal(t, "pro", resp.Data.Quotas.PlanType)
+	assert.Equal(t, 3, resp.Data.Quotas.Servers.Used)
+
+	require.NotNil(t, resp.Data.Usage)
+	assert.Equal(t, int64(42), resp.Data.Usage.Requests)
+
+	require.NotNil(t, provider.principal)
+	assert.Equal(t, types.PrincipalTypeUser, provider.principal.Type)
+	assert.Equal(t, "user-1", provider.principal.UserID)
+}
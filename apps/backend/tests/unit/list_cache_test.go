@@ -0,0 +1,211 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/cache"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	store := cache.NewMemoryStore(2)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, store.Set(ctx, "b", []byte("2"), time.Minute))
+	_, ok, _ := store.Get(ctx, "a")
+	require.True(t, ok)
+	require.NoError(t, store.Set(ctx, "c", []byte("3"), time.Minute))
+
+	_, ok, _ = store.Get(ctx, "b")
+	assert.False(t, ok, "b was least recently used")
+	_, ok, _ = store.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestMemoryStoreExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := cache.NewMemoryStore(10)
+	store.SetClock(func() time.Time { return now })
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), 30*time.Second))
+	now = now.Add(29 * time.Second)
+	_, ok, _ := store.Get(ctx, "a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok, _ = store.Get(ctx, "a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestListCacheFlushesPerOrganizationAndKind(t *testing.T) {
+	ctx := context.Background()
+	listCache := cache.New(cache.NewMemoryStore(100), "memory", time.Minute)
+
+	listCache.Set(ctx, "org-1", cache.KindTools, "/api/gateway/tools", []byte("tools"))
+	listCache.Set(ctx, "org-1", cache.KindPrompts, "/api/gateway/prompts", []byte("prompts"))
+	listCache.Set(ctx, "org-2", cache.KindTools, "/api/gateway/tools", []byte("other"))
+
+	removed, err := listCache.Flush(ctx, "org-1", cache.KindTools)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, ok := listCache.Get(ctx, "org-1", cache.KindTools, "/api/gateway/tools")
+	assert.False(t, ok)
+	_, ok = listCache.Get(ctx, "org-1", cache.KindPrompts, "/api/gateway/prompts")
+	assert.True(t, ok)
+
+	require.NoError(t, listCache.InvalidateOrganization(ctx, "org-1"))
+	_, ok = listCache.Get(ctx, "org-1", cache.KindPrompts, "/api/gateway/prompts")
+	assert.False(t, ok)
+	body, ok := listCache.Get(ctx, "org-2", cache.KindTools, "/api/gateway/tools")
+	assert.True(t, ok, "other organizations keep their listings")
+	assert.Equal(t, "other", string(body))
+
+	stats := listCache.Stats()
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, int64(2), stats.Invalidations)
+	assert.Equal(t, int64(60), stats.TTLSeconds)
+}
+
+func setupListCacheRouter(listCache *cache.ListCache, lists *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("organization_id", c.GetHeader("X-Org"))
+		c.Next()
+	})
+
+	router.GET("/api/gateway/tools", middleware.CacheListings(listCache, cache.KindTools), func(c *gin.Context) {
+		*lists++
+		c.JSON(http.StatusOK, gin.H{"tools": []string{"search"}, "lists": *lists})
+	})
+	router.POST("/api/gateway/tools", middleware.InvalidateListings(listCache), func(c *gin.Context) {
+		if c.Query("fail") == "true" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"success": true})
+	})
+	router.GET("/endpoints/:name/api/tools", func(c *gin.Context) {
+		c.Set("endpoint", &types.Endpoint{OrganizationID: "org-1"})
+		c.Next()
+	}, middleware.CacheListings(listCache, cache.KindTools), func(c *gin.Context) {
+		*lists++
+		c.JSON(http.StatusOK, gin.H{"lists": *lists})
+	})
+	return router
+}
+
+func listCacheRequest(router *gin.Engine, method, path, orgID string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-Org", orgID)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCacheListingsServesRepeatedListings(t *testing.T) {
+	listCache := cache.New(cache.NewMemoryStore(100), "memory", time.Minute)
+	lists := 0
+	router := setupListCacheRouter(listCache, &lists)
+
+	first := listCacheRequest(router, http.MethodGet, "/api/gateway/tools?active=true", "org-1")
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+	second := listCacheRequest(router, http.MethodGet, "/api/gateway/tools?active=true", "org-1")
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+	assert.JSONEq(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, 1, lists)
+
+	listCacheRequest(router, http.MethodGet, "/api/gateway/tools?active=false", "org-1")
+	listCacheRequest(router, http.MethodGet, "/api/gateway/tools?active=true", "org-2")
+	assert.Equal(t, 3, lists, "queries and organizations are cached apart")
+
+	listCacheRequest(router, http.MethodGet, "/api/gateway/tools?active=true", "org-1", "Cache-Control", "no-cache")
+	assert.Equal(t, 4, lists)
+}
+
+func TestInvalidateListingsAfterChanges(t *testing.T) {
+	listCache := cache.New(cache.NewMemoryStore(100), "memory", time.Minute)
+	lists := 0
+	router := setupListCacheRouter(listCache, &lists)
+
+	listCacheRequest(router, http.MethodGet, "/api/gateway/tools", "org-1")
+	listCacheRequest(router, http.MethodPost, "/api/gateway/tools?fail=true", "org-1")
+	listCacheRequest(router, http.MethodGet, "/api/gateway/tools", "org-1")
+	assert.Equal(t, 1, lists, "failed changes keep the cache")
+
+	listCacheRequest(router, http.MethodPost, "/api/gateway/tools", "org-1")
+	w := listCacheRequest(router, http.MethodGet, "/api/gateway/tools", "org-1")
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, 2, lists)
+}
+
+func TestCacheListingsOnPublicEndpoints(t *testing.T) {
+	listCache := cache.New(cache.NewMemoryStore(100), "memory", time.Minute)
+	lists := 0
+	router := setupListCacheRouter(listCache, &lists)
+
+	listCacheRequest(router, http.MethodGet, "/endpoints/docs/api/tools", "")
+	listCacheRequest(router, http.MethodGet, "/endpoints/docs/api/tools", "")
+	assert.Equal(t, 1, lists)
+
+	// Changes in the endpoint's organization drop its listings too
+	listCacheRequest(router, http.MethodPost, "/api/gateway/tools", "org-1")
+	listCacheRequest(router, http.MethodGet, "/endpoints/docs/api/tools", "")
+	assert.Equal(t, 2, lists)
+}
+
+func TestCacheHandlerFlush(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	listCache := cache.New(cache.NewMemoryStore(100), "memory", time.Minute)
+	listCache.Set(ctx, "org-1", cache.KindResources, "/api/gateway/resources", []byte("[]"))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("organization_id", "org-1")
+		c.Next()
+	})
+	handler := handlers.NewCacheHandler(listCache)
+	router.GET("/api/admin/cache", handler.GetStats)
+	router.DELETE("/api/admin/cache", handler.Flush)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/admin/cache?kind=servers", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/admin/cache?kind=resources", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"removed":1`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/cache", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"backend":"memory"`)
+	assert.Contains(t, w.Body.String(), `"invalidations":1`)
+
+	disabled := gin.New()
+	disabled.GET("/api/admin/cache", handlers.NewCacheHandler(nil).GetStats)
+	w = httptest.NewRecorder()
+	disabled.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/cache", nil))
+	assert.Contains(t, w.Body.String(), `"enabled":false`)
+}
@@ -0,0 +1,126 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/scheduler"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveLimiterShedsOverLimit(t *testing.T) {
+	limiter := scheduler.NewAdaptiveLimiter("test", scheduler.AdaptiveLimiterConfig{InitialLimit: 2, MinLimit: 1, MaxLimit: 10})
+	var metrics []*types.Metric
+	limiter.SetMetricEmitter(func(m *types.Metric) { metrics = append(metrics, m) })
+
+	first, err := limiter.Acquire()
+	require.NoError(t, err)
+	_, err = limiter.Acquire()
+	require.NoError(t, err)
+
+	_, err = limiter.Acquire()
+	require.Error(t, err)
+	assert.True(t, types.IsError(err, types.ErrCodeServiceUnavailable))
+	assert.Equal(t, http.StatusServiceUnavailable, err.(*types.Error).Status)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "test_rejected_total", metrics[0].Name)
+
+	first(10*time.Millisecond, false)
+	first(10*time.Millisecond, false)
+	stats := limiter.Stats()
+	assert.Equal(t, 1, stats.InFlight, "release is idempotent")
+	assert.Equal(t, int64(2), stats.Admitted)
+	assert.Equal(t, int64(1), stats.Shed)
+
+	_, err = limiter.Acquire()
+	assert.NoError(t, err)
+}
+
+func TestAdaptiveLimiterFollowsLatency(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	limiter := scheduler.NewAdaptiveLimiter("test", scheduler.AdaptiveLimiterConfig{
+		InitialLimit:  20,
+		MinLimit:      5,
+		MaxLimit:      40,
+		LatencyFactor: 2,
+		Backoff:       0.5,
+	})
+	limiter.SetClock(func() time.Time { return now })
+
+	// run completes n requests of the given latency while the limiter is
+	// fully used
+	run := func(n int, latency time.Duration, overloaded bool) {
+		for i := 0; i < n; i++ {
+			var releases []func(time.Duration, bool)
+			for len(releases) < limiter.Stats().Limit {
+				release, err := limiter.Acquire()
+				require.NoError(t, err)
+				releases = append(releases, release)
+			}
+			for _, release := range releases {
+				now = now.Add(latency)
+				release(latency, overloaded)
+			}
+		}
+	}
+
+	run(5, 10*time.Millisecond, false)
+	grown := limiter.Stats()
+	assert.Greater(t, grown.Limit, 20, "healthy latency raises the limit")
+	assert.Equal(t, 10.0, grown.BaselineLatencyMS)
+
+	run(3, 100*time.Millisecond, false)
+	assert.Less(t, limiter.Stats().Limit, grown.Limit, "degraded latency lowers the limit")
+
+	run(10, 500*time.Millisecond, true)
+	assert.Equal(t, 5, limiter.Stats().Limit, "the limit never drops below min_limit")
+}
+
+func TestLoadSheddingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := scheduler.NewAdaptiveLimiter("load_shedding", scheduler.AdaptiveLimiterConfig{InitialLimit: 1, MinLimit: 1, MaxLimit: 1})
+
+	router := gin.New()
+	router.Use(middleware.LoadShedding(limiter, "/health"))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/health", ok)
+	router.GET("/api/servers", ok)
+	router.GET("/sse", ok)
+
+	call := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, call("/api/servers", nil).Code)
+	assert.Equal(t, 0, limiter.Stats().InFlight, "admitted requests are released")
+
+	// Fill the limit with a request that never finishes
+	_, err := limiter.Acquire()
+	require.NoError(t, err)
+
+	w := call("/api/servers", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	var resp types.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, types.ErrCodeServiceUnavailable, resp.Error.Code)
+	assert.Equal(t, "1 of 1 requests in flight", resp.Error.Details)
+
+	assert.Equal(t, http.StatusOK, call("/health", nil).Code, "exempt routes are never shed")
+	assert.Equal(t, http.StatusOK, call("/sse", map[string]string{"Accept": "text/event-stream"}).Code, "streams are not limited")
+	assert.Equal(t, http.StatusOK, call("/sse", map[string]string{"Upgrade": "websocket"}).Code)
+}
@@ -0,0 +1,395 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go/parquet"
+	parquetreader "github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const exportSecret = "export-signing-secret"
+
+// memoryLogExports keeps log export jobs
+type memoryLogExports struct {
+	exports []*types.LogExport
+}
+
+func (m *memoryLogExports) Create(export *types.LogExport) error {
+	export.ID = fmt.Sprintf("export-%d", len(m.exports)+1)
+	m.exports = append(m.exports, export)
+	return nil
+}
+
+func (m *memoryLogExports) Get(orgID, id string) (*types.LogExport, error) {
+	export, _ := m.GetByID(id)
+	if export == nil || export.OrganizationID != orgID {
+		return nil, nil
+	}
+	return export, nil
+}
+
+func (m *memoryLogExports) GetByID(id string) (*types.LogExport, error) {
+	for _, export := range m.exports {
+		if export.ID == id {
+			copied := *export
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryLogExports) List(orgID string, limit int) ([]*types.LogExport, error) {
+	var exports []*types.LogExport
+	for _, export := range m.exports {
+		if export.OrganizationID == orgID {
+			copied := *export
+			exports = append(exports, &copied)
+		}
+	}
+	return exports, nil
+}
+
+func (m *memoryLogExports) ClaimPending(limit int) ([]*types.LogExport, error) {
+	var claimed []*types.LogExport
+	for _, export := range m.exports {
+		if export.Status == types.LogExportStatusPending && len(claimed) < limit {
+			export.Status = types.LogExportStatusRunning
+			copied := *export
+			claimed = append(claimed, &copied)
+		}
+	}
+	return claimed, nil
+}
+
+func (m *memoryLogExports) Finish(export *types.LogExport) error {
+	for i, existing := range m.exports {
+		if existing.ID == export.ID {
+			m.exports[i] = export
+		}
+	}
+	return nil
+}
+
+func (m *memoryLogExports) ListExpired(now time.Time) ([]*types.LogExport, error) {
+	var expired []*types.LogExport
+	for _, export := range m.exports {
+		if export.Status == types.LogExportStatusCompleted && export.ExpiresAt.Before(now) {
+			expired = append(expired, export)
+		}
+	}
+	return expired, nil
+}
+
+func (m *memoryLogExports) MarkExpired(id string) error {
+	for _, export := range m.exports {
+		if export.ID == id {
+			export.Status = types.LogExportStatusExpired
+			export.StorageKey = ""
+		}
+	}
+	return nil
+}
+
+// memoryExportFiles keeps export files in memory
+type memoryExportFiles struct {
+	files map[string]*bytes.Buffer
+}
+
+type closingBuffer struct{ *bytes.Buffer }
+
+func (closingBuffer) Close() error { return nil }
+
+func (m *memoryExportFiles) Create(key string) (io.WriteCloser, error) {
+	if m.files == nil {
+		m.files = make(map[string]*bytes.Buffer)
+	}
+	m.files[key] = &bytes.Buffer{}
+	return closingBuffer{m.files[key]}, nil
+}
+
+func (m *memoryExportFiles) Open(key string) (io.ReadCloser, error) {
+	file, ok := m.files[key]
+	if !ok {
+		return nil, fmt.Errorf("no file %s", key)
+	}
+	return io.NopCloser(bytes.NewReader(file.Bytes())), nil
+}
+
+func (m *memoryExportFiles) Delete(key string) error {
+	delete(m.files, key)
+	return nil
+}
+
+// exportPlan reports every organization on one plan
+type exportPlan string
+
+func (p exportPlan) GetPlanQuotas(ctx context.Context, orgID string) (*types.PlanQuotas, error) {
+	return &types.PlanQuotas{OrganizationID: orgID, PlanType: string(p)}, nil
+}
+
+var exportLimits = map[string]types.LogExportLimits{
+	"free": {MaxRows: 3, MaxBytes: 1 << 20, MaxRangeDays: 7},
+	"pro":  {MaxRows: 100, MaxBytes: 1 << 20, MaxRangeDays: 31},
+}
+
+func newLogExportService(plan string, logs *recordedLogs) (*services.LogExportService, *memoryLogExports, *memoryExportFiles) {
+	store := &memoryLogExports{}
+	files := &memoryExportFiles{}
+	svc := services.NewLogExportServiceWithStore(store, logs, exportPlan(plan), files, exportLimits)
+	svc.SetSigning(exportSecret, "https://gateway.example.com/", time.Hour)
+	return svc, store, files
+}
+
+func exportEntries(n int) []*logging.LogEntry {
+	entries := make([]*logging.LogEntry, n)
+	for i := range entries {
+		entries[i] = toolExecution("search", true)
+		entries[i].Timestamp = time.Date(2026, 3, 1, 12, 0, i, 0, time.UTC)
+	}
+	return entries
+}
+
+func exportRequest(format string, days int) *types.CreateLogExportRequest {
+	end := time.Now()
+	return &types.CreateLogExportRequest{StartTime: end.Add(-time.Duration(days) * 24 * time.Hour), EndTime: end, Format: format}
+}
+
+func TestLogExportPlanLimits(t *testing.T) {
+	ctx := context.Background()
+	freeSvc, _, _ := newLogExportService("free", &recordedLogs{})
+	_, err := freeSvc.CreateExport(ctx, grantOrgID, grantedUserID, exportRequest(types.LogExportFormatCSV, 10))
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "free plans export at most 7 days")
+
+	enterpriseSvc, _, _ := newLogExportService("enterprise", &recordedLogs{})
+	_, err = enterpriseSvc.CreateExport(ctx, grantOrgID, grantedUserID, exportRequest(types.LogExportFormatCSV, 10))
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "unknown plans get the free plan's limits")
+
+	proSvc, _, _ := newLogExportService("pro", &recordedLogs{})
+	export, err := proSvc.CreateExport(ctx, grantOrgID, grantedUserID, exportRequest(types.LogExportFormatParquet, 10))
+	require.NoError(t, err)
+	assert.Equal(t, types.LogExportStatusPending, export.Status)
+
+	backwards := exportRequest(types.LogExportFormatCSV, 1)
+	backwards.StartTime, backwards.EndTime = backwards.EndTime, backwards.StartTime
+	_, err = proSvc.CreateExport(ctx, grantOrgID, grantedUserID, backwards)
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+}
+
+func TestLogExportProcessingCSV(t *testing.T) {
+	logs := &recordedLogs{entries: exportEntries(5)}
+	logs.entries[0].Data["error"] = "=HYPERLINK(\"http://evil\")"
+	svc, store, files := newLogExportService("free", logs)
+	ctx := context.Background()
+
+	req := exportRequest(types.LogExportFormatCSV, 1)
+	req.Filters = types.LogViewFilters{Level: "warning", Window: "1h"}
+	export, err := svc.CreateExport(ctx, grantOrgID, grantedUserID, req)
+	require.NoError(t, err)
+	assert.Empty(t, export.Filters.Window, "exports use their own time range")
+
+	processed, err := svc.ProcessPending(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	require.NotNil(t, logs.last)
+	assert.Equal(t, grantOrgID, logs.last.OrgID)
+	assert.Equal(t, logging.LogLevel("warning"), logs.last.Level)
+	assert.Equal(t, 4, logs.last.Limit, "one row over the plan limit detects truncation")
+
+	done := store.exports[0]
+	assert.Equal(t, types.LogExportStatusCompleted, done.Status)
+	assert.Equal(t, int64(3), done.RowCount)
+	assert.True(t, done.Truncated)
+	require.NotNil(t, done.ExpiresAt)
+
+	file := files.files[done.StorageKey]
+	require.NotNil(t, file)
+	assert.Equal(t, int64(file.Len()), done.SizeBytes)
+	records, err := csv.NewReader(bytes.NewReader(file.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4, "header and three rows")
+	assert.Equal(t, "timestamp", records[0][0])
+	assert.Equal(t, "search", records[1][13])
+	assert.Equal(t, "true", records[1][15])
+	assert.Equal(t, "'=HYPERLINK(\"http://evil\")", records[1][16], "formulas are neutralized")
+
+	processed, err = svc.ProcessPending(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, processed, "exports are processed once")
+}
+
+// parquetBytes serves an in-memory Parquet file to the reader
+type parquetBytes struct {
+	*bytes.Reader
+	data []byte
+}
+
+func newParquetBytes(data []byte) parquetBytes {
+	return parquetBytes{Reader: bytes.NewReader(data), data: data}
+}
+
+func (f parquetBytes) Write(p []byte) (int, error) { return 0, errors.New("read only") }
+func (f parquetBytes) Close() error                { return nil }
+
+func (f parquetBytes) Open(name string) (source.ParquetFile, error) {
+	return newParquetBytes(f.data), nil
+}
+
+func (f parquetBytes) Create(name string) (source.ParquetFile, error) {
+	return nil, errors.New("read only")
+}
+
+func TestLogExportProcessingParquet(t *testing.T) {
+	entries := exportEntries(20)
+	entries[3] = &logging.LogEntry{
+		Timestamp: time.Date(2026, 3, 1, 12, 0, 3, 250e6, time.UTC), Level: logging.LogLevelError,
+		Logger: logging.LoggerRequest, Message: "upstream failed – retrying ✓", RequestID: "req-3",
+		UserID: grantedUserID, OrgID: grantOrgID, StatusCode: 502,
+		Principal: &types.Principal{Type: types.PrincipalTypeAPIKey, APIKeyID: "key-1"},
+		Data: map[string]interface{}{"method": "POST", "path": "/mcp", "server_id": "server-a",
+			"duration_ms": 1234.5, "success": false, "error": "bad gateway"},
+	}
+	svc, store, files := newLogExportService("pro", &recordedLogs{entries: entries})
+	ctx := context.Background()
+
+	_, err := svc.CreateExport(ctx, grantOrgID, grantedUserID, exportRequest(types.LogExportFormatParquet, 1))
+	require.NoError(t, err)
+	_, err = svc.ProcessPending(ctx, 10)
+	require.NoError(t, err)
+
+	done := store.exports[0]
+	require.Equal(t, types.LogExportStatusCompleted, done.Status, done.Error)
+	assert.Equal(t, int64(20), done.RowCount)
+	assert.False(t, done.Truncated)
+
+	// Decode the file with an independent Parquet implementation
+	data := files.files[done.StorageKey].Bytes()
+	reader, err := parquetreader.NewParquetColumnReader(newParquetBytes(data), 1)
+	require.NoError(t, err)
+	defer reader.ReadStop()
+	require.Equal(t, int64(20), reader.GetNumRows())
+
+	type column struct {
+		name      string
+		physical  parquet.Type
+		converted *parquet.ConvertedType
+	}
+	utf8, timestamp := parquet.ConvertedType_UTF8, parquet.ConvertedType_TIMESTAMP_MILLIS
+	want := []column{
+		{"timestamp", parquet.Type_INT64, &timestamp}, {"level", parquet.Type_BYTE_ARRAY, &utf8},
+		{"logger", parquet.Type_BYTE_ARRAY, &utf8}, {"message", parquet.Type_BYTE_ARRAY, &utf8},
+		{"request_id", parquet.Type_BYTE_ARRAY, &utf8}, {"user_id", parquet.Type_BYTE_ARRAY, &utf8},
+		{"org_id", parquet.Type_BYTE_ARRAY, &utf8}, {"principal_type", parquet.Type_BYTE_ARRAY, &utf8},
+		{"principal_id", parquet.Type_BYTE_ARRAY, &utf8}, {"status_code", parquet.Type_INT32, nil},
+		{"method", parquet.Type_BYTE_ARRAY, &utf8}, {"path", parquet.Type_BYTE_ARRAY, &utf8},
+		{"server_id", parquet.Type_BYTE_ARRAY, &utf8}, {"tool", parquet.Type_BYTE_ARRAY, &utf8},
+		{"duration_ms", parquet.Type_DOUBLE, nil}, {"success", parquet.Type_BYTE_ARRAY, &utf8},
+		{"error", parquet.Type_BYTE_ARRAY, &utf8},
+	}
+	schema := reader.Footer.Schema[1:]
+	require.Len(t, schema, len(want))
+	for i, element := range schema {
+		got := column{reader.SchemaHandler.Infos[i+1].ExName, element.GetType(), element.ConvertedType}
+		assert.Equal(t, want[i], got, "column %d", i)
+		assert.Equal(t, parquet.FieldRepetitionType_REQUIRED, element.GetRepetitionType(), want[i].name)
+	}
+
+	rows := make([][]interface{}, 20)
+	for i := range want {
+		values, _, _, err := reader.ReadColumnByIndex(int64(i), 20)
+		require.NoError(t, err, want[i].name)
+		require.Len(t, values, 20, want[i].name)
+		for row, value := range values {
+			rows[row] = append(rows[row], value)
+		}
+	}
+	assert.Equal(t, []interface{}{
+		entries[0].Timestamp.UnixMilli(), "", logging.LoggerToolExecution, "", "", "", "", "", "", int32(0),
+		"", "", "", "search", float64(0), "true", "",
+	}, rows[0])
+	assert.Equal(t, []interface{}{
+		entries[3].Timestamp.UnixMilli(), "error", logging.LoggerRequest, "upstream failed – retrying ✓", "req-3",
+		grantedUserID, grantOrgID, types.PrincipalTypeAPIKey, "key-1", int32(502),
+		"POST", "/mcp", "server-a", "", 1234.5, "false", "bad gateway",
+	}, rows[3])
+	assert.Equal(t, entries[19].Timestamp.UnixMilli(), rows[19][0])
+}
+
+func TestLogExportDownloadLinks(t *testing.T) {
+	svc, store, _ := newLogExportService("pro", &recordedLogs{entries: exportEntries(2)})
+	ctx := context.Background()
+
+	export, err := svc.CreateExport(ctx, grantOrgID, grantedUserID, exportRequest(types.LogExportFormatCSV, 1))
+	require.NoError(t, err)
+	pending, err := svc.GetExport(ctx, grantOrgID, export.ID)
+	require.NoError(t, err)
+	assert.Empty(t, pending.DownloadURL, "only completed exports can be downloaded")
+	_, err = svc.GetExport(ctx, "other-org", export.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	_, err = svc.ProcessPending(ctx, 10)
+	require.NoError(t, err)
+	completed, err := svc.GetExport(ctx, grantOrgID, export.ID)
+	require.NoError(t, err)
+	link, err := url.Parse(completed.DownloadURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/public/log-exports/"+export.ID+"/download", link.Path)
+
+	opened, file, err := svc.OpenDownload(ctx, export.ID, link.Query().Get("expires"), link.Query().Get("signature"))
+	require.NoError(t, err)
+	defer file.Close()
+	contents, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, opened.SizeBytes, int64(len(contents)))
+
+	_, _, err = svc.OpenDownload(ctx, "export-2", link.Query().Get("expires"), link.Query().Get("signature"))
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied), "signatures are bound to the export")
+
+	past := time.Now().Add(-time.Minute).Unix()
+	mac := hmac.New(sha256.New, []byte(exportSecret))
+	fmt.Fprintf(mac, "%s\n%d", export.ID, past)
+	_, _, err = svc.OpenDownload(ctx, export.ID, strconv.FormatInt(past, 10), hex.EncodeToString(mac.Sum(nil)))
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied), "expired links are refused")
+
+	expiredAt := time.Now().Add(-time.Hour)
+	store.exports[0].ExpiresAt = &expiredAt
+	expired, err := svc.ExpireExports(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	_, _, err = svc.OpenDownload(ctx, export.ID, link.Query().Get("expires"), link.Query().Get("signature"))
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "expired exports are gone")
+}
+
+func TestLogExportStream(t *testing.T) {
+	svc, _, _ := newLogExportService("free", &recordedLogs{entries: exportEntries(2)})
+
+	_, err := svc.PrepareStream(context.Background(), grantOrgID, exportRequest("xlsx", 1))
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	stream, err := svc.PrepareStream(context.Background(), grantOrgID, exportRequest(types.LogExportFormatCSV, 1))
+	require.NoError(t, err)
+	assert.Contains(t, stream.ContentType, "text/csv")
+	var out bytes.Buffer
+	require.NoError(t, stream.Write(context.Background(), &out))
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+}
@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLogFieldPolicies is an in-memory log field policy store
+type memoryLogFieldPolicies struct {
+	policies map[string]*types.LogFieldPolicy
+	err      error
+	gets     int
+}
+
+func (m *memoryLogFieldPolicies) Get(orgID string) (*types.LogFieldPolicy, error) {
+	m.gets++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.policies[orgID], nil
+}
+
+func (m *memoryLogFieldPolicies) Set(policy *types.LogFieldPolicy) error {
+	m.policies[policy.OrganizationID] = policy
+	return nil
+}
+
+func (m *memoryLogFieldPolicies) Delete(orgID string) (bool, error) {
+	_, ok := m.policies[orgID]
+	delete(m.policies, orgID)
+	return ok, nil
+}
+
+// loggedRequest sends a POST with a query string and body through the
+// request logger as orgID and returns the stored log entry's data
+func loggedRequest(t *testing.T, policies *services.LogFieldPolicyService, orgID string) map[string]interface{} {
+	service, factory, err := setupLoggingService(false)
+	require.NoError(t, err)
+	defer service.Close()
+
+	middleware := logging.NewMiddleware(service.(*logging.Service))
+	middleware.SetFieldPolicy(policies)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestLogger())
+	router.POST("/api/tools", func(c *gin.Context) {
+		c.Set("organization_id", orgID)
+		c.JSON(http.StatusOK, gin.H{"result": "ok"})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tools?q=secret", strings.NewReader(`{"input":"private"}`))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := factory.backend.GetStoredEntries()
+	require.Len(t, entries, 1)
+	return entries[0].Data
+}
+
+func TestLogFieldPolicy_RequestLogger(t *testing.T) {
+	store := &memoryLogFieldPolicies{policies: map[string]*types.LogFieldPolicy{}}
+	policies := services.NewLogFieldPolicyServiceWithStore(store)
+
+	data := loggedRequest(t, policies, "org-full")
+	assert.Equal(t, "q=secret", data["query_params"])
+	assert.Contains(t, data["request_body"], "private")
+	assert.Contains(t, data["response_body"], "ok")
+
+	_, err := policies.SetPolicy(context.Background(), "org-minimal", "user-1", &types.SetLogFieldPolicyRequest{
+		Preset: types.LogFieldPresetMetadataOnly,
+	})
+	require.NoError(t, err)
+
+	data = loggedRequest(t, policies, "org-minimal")
+	assert.NotContains(t, data, "query_params")
+	assert.NotContains(t, data, "request_body")
+	assert.NotContains(t, data, "response_body")
+	assert.Equal(t, "/api/tools", data["path"])
+	assert.Contains(t, data, "duration_ms")
+}
+
+func TestLogFieldPolicy_SetAndReset(t *testing.T) {
+	store := &memoryLogFieldPolicies{policies: map[string]*types.LogFieldPolicy{}}
+	policies := services.NewLogFieldPolicyServiceWithStore(store)
+	ctx := context.Background()
+
+	policy, err := policies.GetPolicy(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Empty(t, policy.OmitFields)
+
+	assert.Nil(t, policies.FieldPolicy(ctx, "org-1"))
+
+	// Changes replace the cached policy at once
+	policy, err = policies.SetPolicy(ctx, "org-1", "user-1", &types.SetLogFieldPolicyRequest{
+		OmitFields: []string{types.LogFieldClient, types.LogFieldResponseBody, types.LogFieldClient},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{types.LogFieldResponseBody, types.LogFieldClient}, policy.OmitFields)
+	assert.True(t, policies.FieldPolicy(ctx, "org-1").Omits(types.LogFieldClient))
+	assert.False(t, policies.FieldPolicy(ctx, "org-1").Omits(types.LogFieldRequestBody))
+
+	require.NoError(t, policies.ResetPolicy(ctx, "org-1"))
+	assert.Nil(t, policies.FieldPolicy(ctx, "org-1"))
+
+	err = policies.ResetPolicy(ctx, "org-1")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+}
+
+func TestLogFieldPolicy_FailsClosed(t *testing.T) {
+	store := &memoryLogFieldPolicies{policies: map[string]*types.LogFieldPolicy{}, err: errors.New("database unavailable")}
+	policies := services.NewLogFieldPolicyServiceWithStore(store)
+
+	policy := policies.FieldPolicy(context.Background(), "org-1")
+	assert.True(t, policy.Omits(types.LogFieldRequestBody))
+	assert.True(t, policy.Omits(types.LogFieldResponseBody))
+	assert.True(t, policy.Omits(types.LogFieldQueryParams))
+
+	// Requests without an organization are never looked up
+	assert.Nil(t, policies.FieldPolicy(context.Background(), ""))
+	assert.Equal(t, 1, store.gets)
+}
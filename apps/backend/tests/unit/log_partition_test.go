@@ -0,0 +1,176 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPartitions keeps the partitions of each table in memory, mirroring
+// PartitionModel
+type memoryPartitions struct {
+	tables           map[string][]*types.TablePartition
+	detached         map[string]bool
+	held             map[string]bool
+	orgRetentionDays int
+}
+
+func newMemoryPartitions() *memoryPartitions {
+	return &memoryPartitions{
+		tables:   make(map[string][]*types.TablePartition),
+		detached: make(map[string]bool),
+		held:     make(map[string]bool),
+	}
+}
+
+func (m *memoryPartitions) CreateMonthlyPartition(table string, month time.Time) (bool, error) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	for _, partition := range m.tables[table] {
+		if (partition.RangeStart == nil || partition.RangeStart.Before(end)) &&
+			(partition.RangeEnd == nil || partition.RangeEnd.After(start)) {
+			return false, nil
+		}
+	}
+	m.tables[table] = append(m.tables[table], &types.TablePartition{
+		Table:      table,
+		Name:       fmt.Sprintf("%s_p%s", table, start.Format("200601")),
+		RangeStart: &start,
+		RangeEnd:   &end,
+	})
+	return true, nil
+}
+
+func (m *memoryPartitions) ListPartitions(table string) ([]*types.TablePartition, error) {
+	return append([]*types.TablePartition(nil), m.tables[table]...), nil
+}
+
+func (m *memoryPartitions) HasHeldRows(partition string) (bool, error) {
+	return m.held[partition], nil
+}
+
+func (m *memoryPartitions) DetachPartition(table, partition string) error {
+	kept := m.tables[table][:0]
+	for _, p := range m.tables[table] {
+		if p.Name != partition {
+			kept = append(kept, p)
+		}
+	}
+	m.tables[table] = kept
+	m.detached[partition] = true
+	return nil
+}
+
+func (m *memoryPartitions) DropPartition(partition string) error {
+	if !m.detached[partition] {
+		return fmt.Errorf("%s is still attached", partition)
+	}
+	delete(m.detached, partition)
+	return nil
+}
+
+func (m *memoryPartitions) LongestLogRetentionDays() (int, error) {
+	return m.orgRetentionDays, nil
+}
+
+func (m *memoryPartitions) names(table string) []string {
+	names := []string{}
+	for _, partition := range m.tables[table] {
+		names = append(names, partition.Name)
+	}
+	return names
+}
+
+func TestPartitionServiceCreatesUpcomingPartitions(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryPartitions()
+	legacyEnd := time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC)
+	store.tables["log_index"] = []*types.TablePartition{{Table: "log_index", Name: "log_index_legacy", RangeEnd: &legacyEnd}}
+
+	service := services.NewPartitionServiceWithStore(store, 2, 30*24*time.Hour, 0)
+	service.SetClock(func() time.Time { return time.Date(2026, time.April, 20, 12, 0, 0, 0, time.UTC) })
+
+	created, err := service.CreatePartitions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log_index_legacy", "log_index_p202605", "log_index_p202606"}, store.names("log_index"))
+	assert.Equal(t, []string{"health_checks_p202604", "health_checks_p202605", "health_checks_p202606"}, store.names("health_checks"))
+	assert.Len(t, created, 8)
+
+	// Running again creates nothing new
+	created, err = service.CreatePartitions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, created)
+}
+
+func TestPartitionServiceRemovesExpiredPartitions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+	newService := func(store *memoryPartitions) *services.PartitionService {
+		service := services.NewPartitionServiceWithStore(store, 1, 30*24*time.Hour, 7*24*time.Hour)
+		service.SetClock(func() time.Time { return now })
+		for _, month := range []time.Month{time.February, time.March, time.April, time.May, time.June} {
+			for _, table := range []string{"log_index", "mcp_message_logs", "health_checks"} {
+				_, err := store.CreateMonthlyPartition(table, time.Date(2026, month, 1, 0, 0, 0, 0, time.UTC))
+				require.NoError(t, err)
+			}
+		}
+		return service
+	}
+
+	t.Run("drops partitions past retention", func(t *testing.T) {
+		store := newMemoryPartitions()
+		result, err := newService(store).Maintain(ctx)
+		require.NoError(t, err)
+
+		// Log partitions ending before May 16 are past the 30 day retention,
+		// health check partitions ending before June 8 past 7 days
+		assert.Equal(t, []string{"log_index_p202605", "log_index_p202606", "log_index_p202607"}, store.names("log_index"))
+		assert.Equal(t, []string{"health_checks_p202606", "health_checks_p202607"}, store.names("health_checks"))
+		assert.Len(t, result.Dropped, 10)
+		assert.Equal(t, result.Detached, result.Dropped)
+		assert.Equal(t, []string{"log_index_p202607", "mcp_message_logs_p202607", "health_checks_p202607"}, result.Created)
+		assert.Empty(t, store.detached)
+	})
+
+	t.Run("keeps logs for the longest organization retention", func(t *testing.T) {
+		store := newMemoryPartitions()
+		store.orgRetentionDays = 90
+		_, err := newService(store).RemoveExpired(ctx)
+		require.NoError(t, err)
+
+		// 90 days before June 15 is March 17, so only February is expired
+		assert.Equal(t, []string{"log_index_p202603", "log_index_p202604", "log_index_p202605", "log_index_p202606"}, store.names("log_index"))
+		assert.Equal(t, []string{"health_checks_p202606"}, store.names("health_checks"))
+	})
+
+	t.Run("keeps partitions under legal hold", func(t *testing.T) {
+		store := newMemoryPartitions()
+		store.held["log_index_p202603"] = true
+		result, err := newService(store).RemoveExpired(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"log_index_p202603"}, result.Held)
+		assert.Contains(t, store.names("log_index"), "log_index_p202603")
+		assert.NotContains(t, store.names("log_index"), "log_index_p202602")
+	})
+
+	t.Run("detach only leaves tables behind", func(t *testing.T) {
+		store := newMemoryPartitions()
+		service := newService(store)
+		service.SetDetachOnly(true)
+		result, err := service.RemoveExpired(ctx)
+		require.NoError(t, err)
+
+		assert.Empty(t, result.Dropped)
+		assert.Len(t, result.Detached, 10)
+		assert.True(t, store.detached["mcp_message_logs_p202602"])
+	})
+}
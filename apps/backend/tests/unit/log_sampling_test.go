@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampler_FixedRate(t *testing.T) {
+	sampler := logging.NewSampler(0.25, 0)
+
+	kept := 0
+	for i := 0; i < 100; i++ {
+		keep, weight := sampler.Sample(http.StatusOK, false)
+		if keep {
+			kept++
+			assert.Equal(t, int64(4), weight)
+		}
+	}
+	assert.Equal(t, 25, kept)
+
+	// Failures are always kept at their own weight
+	for _, status := range []int{http.StatusBadRequest, http.StatusInternalServerError} {
+		keep, weight := sampler.Sample(status, false)
+		assert.True(t, keep)
+		assert.Equal(t, int64(1), weight)
+	}
+	keep, weight := sampler.Sample(http.StatusOK, true)
+	assert.True(t, keep)
+	assert.Equal(t, int64(1), weight)
+}
+
+func TestSampler_AdaptsToLoad(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sampler := logging.NewSampler(1, 100)
+	sampler.SetClock(func() time.Time { return now })
+
+	// Below the target everything is logged
+	for i := 0; i < 50; i++ {
+		keep, _ := sampler.Sample(http.StatusOK, false)
+		assert.True(t, keep)
+	}
+
+	// A spike of 1000 successes in a second makes the next second log 1 in 10
+	for i := 0; i < 950; i++ {
+		sampler.Sample(http.StatusOK, false)
+	}
+	now = now.Add(time.Second)
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		if keep, weight := sampler.Sample(http.StatusOK, false); keep {
+			kept++
+			assert.Equal(t, int64(10), weight)
+		}
+	}
+	assert.Equal(t, int64(10), sampler.Every())
+	assert.Equal(t, 100, kept)
+
+	// Once traffic calms down, every success is logged again
+	now = now.Add(time.Second)
+	sampler.Sample(http.StatusOK, false)
+	now = now.Add(10 * time.Second)
+	keep, weight := sampler.Sample(http.StatusOK, false)
+	assert.True(t, keep)
+	assert.Equal(t, int64(1), weight)
+}
+
+func TestSummarize_ExtrapolatesSampledRequests(t *testing.T) {
+	entries := []*logging.LogEntry{
+		{Logger: logging.LoggerRequest, StatusCode: 200, Data: map[string]interface{}{
+			"server_id": "srv-1", "client": "cursor", "duration_ms": 10.0, logging.SampleWeightKey: int64(10)}},
+		// Weights read back from JSON are floats
+		{Logger: logging.LoggerRequest, StatusCode: 200, Data: map[string]interface{}{
+			"server_id": "srv-1", "client": "cursor", "duration_ms": 10.0, logging.SampleWeightKey: float64(10)}},
+		{Logger: logging.LoggerRequest, StatusCode: 500, Data: map[string]interface{}{
+			"server_id": "srv-1", "client": "cursor", "duration_ms": 120.0}},
+	}
+
+	servers := logging.SummarizeByServer(entries)
+	require.Len(t, servers, 1)
+	assert.Equal(t, int64(21), servers[0].Requests)
+	assert.Equal(t, int64(1), servers[0].Errors)
+	assert.InDelta(t, 1.0/21, servers[0].ErrorRate, 1e-9)
+	assert.InDelta(t, 320.0/21, servers[0].AvgDurationMS, 1e-9)
+
+	clients := logging.SummarizeByClient(entries)
+	require.Len(t, clients, 1)
+	assert.Equal(t, int64(21), clients[0].Requests)
+}
+
+func TestRequestLogger_SamplesSuccesses(t *testing.T) {
+	service, factory, err := setupLoggingService(false)
+	require.NoError(t, err)
+	defer service.Close()
+
+	middleware := logging.NewMiddleware(service.(*logging.Service))
+	middleware.SetSampler(logging.NewSampler(0.5, 0))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestLogger())
+	router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/fail", func(c *gin.Context) { c.Status(http.StatusBadGateway) })
+
+	for i := 0; i < 4; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	entries := factory.backend.GetStoredEntries()
+	require.Len(t, entries, 3)
+	weights := map[int]int{}
+	for _, entry := range entries {
+		if entry.StatusCode == http.StatusOK {
+			assert.Equal(t, int64(2), entry.Data[logging.SampleWeightKey])
+		} else {
+			assert.NotContains(t, entry.Data, logging.SampleWeightKey)
+		}
+		weights[entry.StatusCode]++
+	}
+	assert.Equal(t, map[int]int{http.StatusOK: 2, http.StatusBadGateway: 1}, weights)
+}
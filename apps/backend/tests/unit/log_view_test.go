@@ -0,0 +1,239 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// memoryLogViews keeps saved log views
+type memoryLogViews struct {
+	views []*types.SavedLogView
+}
+
+func (m *memoryLogViews) Create(view *types.SavedLogView) error {
+	view.ID = fmt.Sprintf("view-%d", len(m.views)+1)
+	m.views = append(m.views, view)
+	return nil
+}
+
+func (m *memoryLogViews) List(orgID, userID, kind string) ([]*types.SavedLogView, error) {
+	var views []*types.SavedLogView
+	for _, view := range m.views {
+		if view.OrganizationID == orgID && (view.OwnerID == userID || view.Shared) && (kind == "" || view.Kind == kind) {
+			views = append(views, view)
+		}
+	}
+	return views, nil
+}
+
+func (m *memoryLogViews) Get(orgID, id string) (*types.SavedLogView, error) {
+	for _, view := range m.views {
+		if view.OrganizationID == orgID && view.ID == id {
+			copied := *view
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryLogViews) Update(view *types.SavedLogView) error {
+	for i, existing := range m.views {
+		if existing.ID == view.ID {
+			m.views[i] = view
+		}
+	}
+	return nil
+}
+
+func (m *memoryLogViews) Delete(orgID, id string) (bool, error) {
+	for i, view := range m.views {
+		if view.OrganizationID == orgID && view.ID == id {
+			m.views = append(m.views[:i], m.views[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordedLogs answers log queries with fixed entries and keeps the last query
+type recordedLogs struct {
+	entries []*logging.LogEntry
+	last    *logging.QueryRequest
+}
+
+func (r *recordedLogs) Query(ctx context.Context, query *logging.QueryRequest) ([]*logging.LogEntry, error) {
+	r.last = query
+	return r.entries, nil
+}
+
+// recordedAudits answers audit queries with no records and keeps the last query
+type recordedAudits struct {
+	last *types.AuditLogQuery
+}
+
+func (r *recordedAudits) QueryAuditLogs(query *types.AuditLogQuery) ([]*types.AuditLog, error) {
+	r.last = query
+	return nil, nil
+}
+
+func serverRequest(serverID string, status int) *logging.LogEntry {
+	return &logging.LogEntry{Logger: logging.LoggerRequest, StatusCode: status,
+		Data: map[string]interface{}{"server_id": serverID}}
+}
+
+func toolExecution(tool string, success bool) *logging.LogEntry {
+	return &logging.LogEntry{Logger: logging.LoggerToolExecution, EntityName: tool,
+		Data: map[string]interface{}{"tool": tool, "success": success}}
+}
+
+func TestSummarizeByServerAndTool(t *testing.T) {
+	entries := []*logging.LogEntry{
+		serverRequest("server-a", 200),
+		serverRequest("server-a", 200),
+		serverRequest("server-b", 502),
+		serverRequest("server-b", 200),
+		{Logger: logging.LoggerRequest, StatusCode: 500, Data: map[string]interface{}{}},
+		toolExecution("search", true),
+		toolExecution("search", false),
+		toolExecution("fetch", true),
+		{Logger: logging.LoggerToolExecution, Data: map[string]interface{}{"tool": "fetch", "sandbox": true}},
+	}
+
+	servers := logging.SummarizeByServer(entries)
+	require.Len(t, servers, 2, "requests not bound to a server are skipped")
+	assert.Equal(t, "server-b", servers[0].ServerID, "highest error rate first")
+	assert.Equal(t, 0.5, servers[0].ErrorRate)
+	assert.Equal(t, int64(2), servers[1].Requests)
+	assert.Zero(t, servers[1].ErrorRate)
+
+	tools := logging.SummarizeByTool(entries)
+	require.Len(t, tools, 2)
+	assert.Equal(t, "search", tools[0].Tool)
+	assert.Equal(t, int64(2), tools[0].ToolExecutions)
+	assert.Equal(t, int64(1), tools[0].Errors)
+	assert.Equal(t, int64(1), tools[1].ToolExecutions, "sandbox executions are left out")
+}
+
+func TestSavedLogViewSharing(t *testing.T) {
+	svc := services.NewLogViewServiceWithStore(&memoryLogViews{}, &recordedLogs{}, &recordedAudits{})
+	ctx := context.Background()
+
+	private, err := svc.CreateView(ctx, grantOrgID, grantedUserID, &types.CreateLogViewRequest{
+		Name: "My errors", Kind: types.LogViewKindLogs, Filters: types.LogViewFilters{Level: "error"},
+	})
+	require.NoError(t, err)
+	shared, err := svc.CreateView(ctx, grantOrgID, grantedUserID, &types.CreateLogViewRequest{
+		Name: "Team audit", Kind: types.LogViewKindAudit, Shared: true,
+	})
+	require.NoError(t, err)
+
+	_, err = svc.CreateView(ctx, grantOrgID, grantedUserID, &types.CreateLogViewRequest{Name: "my ERRORS", Kind: types.LogViewKindLogs})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict), "names are unique per owner")
+	_, err = svc.CreateView(ctx, grantOrgID, otherUserID, &types.CreateLogViewRequest{Name: "My errors", Kind: types.LogViewKindLogs})
+	assert.NoError(t, err, "other users can reuse the name")
+
+	views, err := svc.ListViews(ctx, grantOrgID, otherUserID, types.LogViewKindAudit)
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, shared.ID, views[0].ID)
+
+	_, err = svc.GetView(ctx, grantOrgID, otherUserID, private.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "private views stay private")
+
+	name := "Renamed"
+	_, err = svc.UpdateView(ctx, grantOrgID, otherUserID, shared.ID, &types.UpdateLogViewRequest{Name: &name})
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied), "only owners change shared views")
+	assert.True(t, types.IsError(svc.DeleteView(ctx, grantOrgID, otherUserID, private.ID), types.ErrCodeNotFound))
+
+	updated, err := svc.UpdateView(ctx, grantOrgID, grantedUserID, shared.ID, &types.UpdateLogViewRequest{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Name)
+	assert.True(t, updated.Shared)
+	require.NoError(t, svc.DeleteView(ctx, grantOrgID, grantedUserID, shared.ID))
+}
+
+func TestSavedLogViewValidation(t *testing.T) {
+	svc := services.NewLogViewServiceWithStore(&memoryLogViews{}, &recordedLogs{}, &recordedAudits{})
+	ctx := context.Background()
+
+	for name, req := range map[string]*types.CreateLogViewRequest{
+		"bad window":     {Name: "a", Kind: types.LogViewKindLogs, Filters: types.LogViewFilters{Window: "yesterday"}},
+		"long window":    {Name: "b", Kind: types.LogViewKindLogs, Filters: types.LogViewFilters{Window: "2400h"}},
+		"bad severity":   {Name: "c", Kind: types.LogViewKindAudit, Filters: types.LogViewFilters{MinSeverity: "loud"}},
+		"unknown widget": {Name: "d", Kind: types.LogViewKindLogs, Widgets: []types.LogWidget{{Type: "pie"}}},
+		"audit widgets":  {Name: "e", Kind: types.LogViewKindAudit, Widgets: []types.LogWidget{{Type: types.LogWidgetTopTools}}},
+		"large widget":   {Name: "f", Kind: types.LogViewKindLogs, Widgets: []types.LogWidget{{Type: types.LogWidgetTopTools, Limit: 1000}}},
+		"blank name":     {Name: "  ", Kind: types.LogViewKindLogs},
+	} {
+		_, err := svc.CreateView(ctx, grantOrgID, grantedUserID, req)
+		assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), name)
+	}
+}
+
+func TestSavedLogViewResultsAndWidgets(t *testing.T) {
+	logs := &recordedLogs{entries: []*logging.LogEntry{
+		serverRequest("server-a", 500),
+		serverRequest("server-b", 200),
+		toolExecution("search", true),
+		toolExecution("fetch", true),
+		toolExecution("search", true),
+	}}
+	audits := &recordedAudits{}
+	svc := services.NewLogViewServiceWithStore(&memoryLogViews{}, logs, audits)
+	ctx := context.Background()
+
+	view, err := svc.CreateView(ctx, grantOrgID, grantedUserID, &types.CreateLogViewRequest{
+		Name: "Gateway health",
+		Kind: types.LogViewKindLogs,
+		Filters: types.LogViewFilters{
+			Window: "1h", Level: "error", Method: "POST", Labels: map[string]string{"team": "search"},
+		},
+		Widgets: []types.LogWidget{
+			{Type: types.LogWidgetErrorRateByServer, Title: "Error rate"},
+			{Type: types.LogWidgetTopTools, Limit: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	results, err := svc.RunView(ctx, grantOrgID, grantedUserID, view.ID, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, results.Logs, 5)
+	require.NotNil(t, logs.last)
+	assert.Equal(t, grantOrgID, logs.last.OrgID, "views only query their organization")
+	assert.Equal(t, logging.LogLevel("error"), logs.last.Level)
+	assert.Equal(t, "POST", logs.last.Filters["method"])
+	assert.Equal(t, "search", logs.last.Labels["team"])
+	assert.Equal(t, 100, logs.last.Limit)
+	assert.WithinDuration(t, logs.last.EndTime.Add(-time.Hour), *logs.last.StartTime, time.Second)
+
+	widgets, err := svc.Widgets(ctx, grantOrgID, grantedUserID, view.ID)
+	require.NoError(t, err)
+	require.Len(t, widgets, 2)
+	servers := widgets[0].Data.([]*logging.ServerUsage)
+	require.Len(t, servers, 2)
+	assert.Equal(t, "server-a", servers[0].ServerID)
+	tools := widgets[1].Data.([]*logging.ToolUsage)
+	require.Len(t, tools, 1, "widgets honour their limit")
+	assert.Equal(t, "search", tools[0].Tool)
+
+	auditView, err := svc.CreateView(ctx, grantOrgID, grantedUserID, &types.CreateLogViewRequest{
+		Name: "Deletions", Kind: types.LogViewKindAudit, Filters: types.LogViewFilters{Action: "delete", MinSeverity: "warning"},
+	})
+	require.NoError(t, err)
+	results, err = svc.RunView(ctx, grantOrgID, grantedUserID, auditView.ID, 20, 0)
+	require.NoError(t, err)
+	assert.NotNil(t, results.AuditLogs)
+	require.NotNil(t, audits.last)
+	assert.Equal(t, "delete", audits.last.Action)
+	assert.Equal(t, grantOrgID, audits.last.OrganizationID)
+	assert.Equal(t, 20, audits.last.Limit)
+}
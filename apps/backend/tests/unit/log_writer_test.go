@@ -0,0 +1,203 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedLogBackend is a log plugin whose batch writes wait until the gate is
+// opened, standing in for a slow database
+type gatedLogBackend struct {
+	gate     chan struct{}
+	started  chan struct{}
+	name     string
+	messages []string
+	mu       sync.Mutex
+}
+
+func newGatedLogBackend() *gatedLogBackend {
+	return &gatedLogBackend{
+		gate:    make(chan struct{}),
+		started: make(chan struct{}, 100),
+		name:    fmt.Sprintf("gated-%d", time.Now().UnixNano()),
+	}
+}
+
+func (b *gatedLogBackend) Create() logging.StorageBackend { return b }
+func (b *gatedLogBackend) GetName() string                { return b.name }
+func (b *gatedLogBackend) GetDescription() string         { return "gated test backend" }
+func (b *gatedLogBackend) ValidateConfig(map[string]interface{}) error {
+	return nil
+}
+
+func (b *gatedLogBackend) Initialize(context.Context, map[string]interface{}) error { return nil }
+func (b *gatedLogBackend) Store(ctx context.Context, entry *logging.LogEntry) error {
+	return b.StoreBatch(ctx, []*logging.LogEntry{entry})
+}
+
+func (b *gatedLogBackend) StoreBatch(ctx context.Context, entries []*logging.LogEntry) error {
+	b.started <- struct{}{}
+	<-b.gate
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, entry := range entries {
+		b.messages = append(b.messages, entry.Message)
+	}
+	return nil
+}
+
+func (b *gatedLogBackend) Query(context.Context, *logging.QueryRequest) ([]*logging.LogEntry, error) {
+	return nil, nil
+}
+func (b *gatedLogBackend) Close() error                      { return nil }
+func (b *gatedLogBackend) HealthCheck(context.Context) error { return nil }
+func (b *gatedLogBackend) GetCapabilities() logging.BackendCapabilities {
+	return logging.BackendCapabilities{SupportsBatchWrite: true}
+}
+
+func (b *gatedLogBackend) stored() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.messages...)
+}
+
+// bufferedLogService starts an async logging service holding four entries
+// in batches of two, with its first batch stuck in the backend
+func bufferedLogService(t *testing.T, backend *gatedLogBackend, policy logging.OverflowPolicy, timeout time.Duration) *logging.Service {
+	require.NoError(t, logging.RegisterPlugin(backend))
+	service, err := logging.NewService(&logging.LoggingConfig{
+		Level:           logging.LogLevelInfo,
+		Backend:         backend.name,
+		BufferSize:      4,
+		BatchSize:       2,
+		FlushInterval:   time.Hour,
+		OverflowPolicy:  policy,
+		OverflowTimeout: timeout,
+		Async:           true,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, service.Log(ctx, &logging.LogEntry{Level: logging.LogLevelInfo, Message: "m0"}))
+	require.NoError(t, service.Log(ctx, &logging.LogEntry{Level: logging.LogLevelInfo, Message: "m1"}))
+	<-backend.started
+	return service.(*logging.Service)
+}
+
+func TestAsyncLog_DropOldest(t *testing.T) {
+	backend := newGatedLogBackend()
+	service := bufferedLogService(t, backend, logging.OverflowDropOldest, 0)
+
+	// Writes never wait on the stuck backend; the oldest buffered entries
+	// make room for the newest
+	for i := 2; i < 8; i++ {
+		err := service.Log(context.Background(), &logging.LogEntry{Level: logging.LogLevelInfo, Message: fmt.Sprintf("m%d", i)})
+		require.NoError(t, err)
+	}
+
+	metrics, err := service.GetMetrics()
+	require.NoError(t, err)
+	assert.Equal(t, 4, metrics.BufferSize)
+	assert.Equal(t, 4, metrics.BufferCapacity)
+	assert.Equal(t, int64(2), metrics.DroppedEntries)
+	assert.Equal(t, string(logging.OverflowDropOldest), metrics.OverflowPolicy)
+
+	close(backend.gate)
+	require.NoError(t, service.Close())
+	assert.Equal(t, []string{"m0", "m1", "m4", "m5", "m6", "m7"}, backend.stored())
+}
+
+func TestAsyncLog_Backpressure(t *testing.T) {
+	backend := newGatedLogBackend()
+	service := bufferedLogService(t, backend, logging.OverflowBackpressure, 0)
+	ctx := context.Background()
+
+	for i := 2; i < 6; i++ {
+		require.NoError(t, service.Log(ctx, &logging.LogEntry{Level: logging.LogLevelInfo, Message: fmt.Sprintf("m%d", i)}))
+	}
+
+	// A writer whose context ends before room frees up loses its entry
+	shortCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	err := service.Log(shortCtx, &logging.LogEntry{Level: logging.LogLevelInfo, Message: "late"})
+	assert.ErrorIs(t, err, logging.ErrBufferFull)
+
+	// Other writers wait until the backend catches up
+	done := make(chan error, 1)
+	go func() {
+		done <- service.Log(ctx, &logging.LogEntry{Level: logging.LogLevelInfo, Message: "m6"})
+	}()
+	select {
+	case <-done:
+		t.Fatal("write returned while the buffer was full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(backend.gate)
+	require.NoError(t, <-done)
+
+	metrics, err := service.GetMetrics()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), metrics.DroppedEntries)
+
+	require.NoError(t, service.Close())
+	assert.Equal(t, []string{"m0", "m1", "m2", "m3", "m4", "m5", "m6"}, backend.stored())
+}
+
+func TestAsyncLog_AuditRecordsStoredOffRequestPath(t *testing.T) {
+	backend := newGatedLogBackend()
+	close(backend.gate)
+	require.NoError(t, logging.RegisterPlugin(backend))
+	logService, err := logging.NewService(&logging.LoggingConfig{
+		Level:         logging.LogLevelInfo,
+		Backend:       backend.name,
+		FlushInterval: time.Hour,
+		Async:         true,
+	})
+	require.NoError(t, err)
+	service := logService.(*logging.Service)
+
+	store := &recordingAuditStore{}
+	service.SetAuditStore(store)
+
+	for _, action := range []string{"create", "update", "delete"} {
+		require.NoError(t, service.LogAudit(context.Background(), &types.AuditLog{
+			Action:   action,
+			Resource: "server",
+			Success:  true,
+		}))
+	}
+
+	// Buffered audit records are written in order when the service closes
+	require.NoError(t, service.Close())
+	assert.Equal(t, []string{"create", "update", "delete"}, store.actions())
+	assert.Len(t, backend.stored(), 3)
+}
+
+// recordingAuditStore records the actions of stored audit records
+type recordingAuditStore struct {
+	stored []string
+	mu     sync.Mutex
+}
+
+func (s *recordingAuditStore) LogAudit(audit *types.AuditLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stored = append(s.stored, audit.Action)
+	return nil
+}
+
+func (s *recordingAuditStore) actions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.stored...)
+}
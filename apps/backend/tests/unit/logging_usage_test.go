@@ -0,0 +1,152 @@
+package unit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/logging"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeByPrincipal(t *testing.T) {
+	now := time.Now()
+	user := &types.Principal{Type: types.PrincipalTypeUser, UserID: "user-1"}
+	key := &types.Principal{Type: types.PrincipalTypeAPIKey, UserID: "user-1", APIKeyID: "key-1"}
+
+	entries := []*logging.LogEntry{
+		{Logger: logging.LoggerRequest, Principal: user, StatusCode: 200, Timestamp: now, Data: map[string]interface{}{"duration_ms": 10.0}},
+		{Logger: logging.LoggerRequest, Principal: user, StatusCode: 500, Timestamp: now.Add(time.Second), Data: map[string]interface{}{"duration_ms": 30.0}},
+		{Logger: logging.LoggerToolExecution, Principal: key, Timestamp: now, Data: map[string]interface{}{"success": false}},
+		{Logger: logging.LoggerRequest, StatusCode: 200, Timestamp: now},
+		{Logger: "audit", Principal: user, Timestamp: now},
+	}
+
+	usage := logging.SummarizeByPrincipal(entries)
+	require.Len(t, usage, 3)
+
+	byKey := make(map[string]*logging.PrincipalUsage)
+	for _, u := range usage {
+		byKey[u.Key] = u
+	}
+
+	userUsage := byKey["user:user-1"]
+	require.NotNil(t, userUsage)
+	assert.Equal(t, int64(2), userUsage.Requests)
+	assert.Equal(t, int64(1), userUsage.Errors)
+	assert.Equal(t, 20.0, userUsage.AvgDurationMS)
+	assert.Equal(t, now.Add(time.Second), userUsage.LastSeen)
+
+	keyUsage := byKey["api_key:key-1"]
+	require.NotNil(t, keyUsage)
+	assert.Equal(t, int64(1), keyUsage.ToolExecutions)
+	assert.Equal(t, int64(1), keyUsage.Errors)
+
+	anonymous := byKey[types.PrincipalTypeAnonymous]
+	require.NotNil(t, anonymous)
+	assert.Equal(t, types.PrincipalTypeAnonymous, anonymous.Principal.Type)
+
+	// Highest activity first
+	assert.Equal(t, "user:user-1", usage[0].Key)
+}
+
+func TestMatchesPrincipal(t *testing.T) {
+	entry := &logging.LogEntry{Principal: &types.Principal{Type: types.PrincipalTypeOAuthClient, ClientID: "client-1"}}
+
+	assert.True(t, logging.MatchesPrincipal(entry, &logging.QueryRequest{}))
+	assert.True(t, logging.MatchesPrincipal(entry, &logging.QueryRequest{PrincipalType: types.PrincipalTypeOAuthClient}))
+	assert.True(t, logging.MatchesPrincipal(entry, &logging.QueryRequest{PrincipalID: "client-1"}))
+	assert.False(t, logging.MatchesPrincipal(entry, &logging.QueryRequest{PrincipalType: types.PrincipalTypeAPIKey}))
+	assert.False(t, logging.MatchesPrincipal(&logging.LogEntry{}, &logging.QueryRequest{PrincipalID: "client-1"}))
+	assert.True(t, logging.MatchesPrincipal(&logging.LogEntry{}, &logging.QueryRequest{PrincipalType: types.PrincipalTypeAnonymous}))
+}
+
+func TestPrincipalFromGinContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("explicit principal wins", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		principal := &types.Principal{Type: types.PrincipalTypeUser, UserID: "u"}
+		c.Set("principal", principal)
+		c.Set("api_key", &types.APIKey{ID: "k"})
+
+		assert.Same(t, principal, logging.PrincipalFromGinContext(c))
+	})
+
+	t.Run("falls back to api key context", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Set("user_id", "u")
+		c.Set("api_key", &types.APIKey{ID: "k"})
+
+		principal := logging.PrincipalFromGinContext(c)
+		require.NotNil(t, principal)
+		assert.Equal(t, types.PrincipalTypeAPIKey, principal.Type)
+		assert.Equal(t, "k", principal.ID())
+		assert.Equal(t, "u", principal.UserID)
+	})
+
+	t.Run("unauthenticated request", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+
+		assert.Nil(t, logging.PrincipalFromGinContext(c))
+	})
+}
+
+func TestSummarizeByLabel(t *testing.T) {
+	now := time.Now()
+	platform := &types.Principal{Type: types.PrincipalTypeAPIKey, APIKeyID: "key-1", Labels: types.Labels{types.LabelTeam: "platform"}}
+	search := &types.Principal{Type: types.PrincipalTypeAPIKey, APIKeyID: "key-2", Labels: types.Labels{types.LabelTeam: "search"}}
+
+	entries := []*logging.LogEntry{
+		{Logger: logging.LoggerRequest, Principal: platform, StatusCode: 200, Timestamp: now},
+		{Logger: logging.LoggerToolExecution, Principal: platform, Timestamp: now, Data: map[string]interface{}{"success": true}},
+		{Logger: logging.LoggerRequest, Principal: search, StatusCode: 429, Timestamp: now},
+		{Logger: logging.LoggerRequest, StatusCode: 200, Timestamp: now},
+	}
+
+	usage := logging.SummarizeByLabel(entries, types.LabelTeam)
+	require.Len(t, usage, 3)
+
+	assert.Equal(t, "platform", usage[0].Value)
+	assert.Equal(t, int64(1), usage[0].Requests)
+	assert.Equal(t, int64(1), usage[0].ToolExecutions)
+
+	byValue := make(map[string]*logging.LabelUsage)
+	for _, u := range usage {
+		assert.Equal(t, types.LabelTeam, u.Label)
+		byValue[u.Value] = u
+	}
+	assert.Equal(t, int64(1), byValue["search"].Errors)
+	assert.Equal(t, int64(1), byValue[""].Requests)
+}
+
+func TestMatchesPrincipalLabels(t *testing.T) {
+	entry := &logging.LogEntry{Principal: &types.Principal{
+		Type:   types.PrincipalTypeAPIKey,
+		Labels: types.Labels{types.LabelTeam: "platform", types.LabelCostCenter: "cc-42"},
+	}}
+
+	assert.True(t, logging.MatchesPrincipal(entry, &logging.QueryRequest{Labels: types.Labels{types.LabelTeam: "platform"}}))
+	assert.False(t, logging.MatchesPrincipal(entry, &logging.QueryRequest{Labels: types.Labels{types.LabelTeam: "search"}}))
+	assert.False(t, logging.MatchesPrincipal(&logging.LogEntry{}, &logging.QueryRequest{Labels: types.Labels{types.LabelTeam: "platform"}}))
+}
+
+func TestLabelsValidateAndMerge(t *testing.T) {
+	assert.NoError(t, types.Labels{types.LabelCostCenter: "cc-42", "env.tier": "prod"}.Validate())
+	assert.Error(t, types.Labels{"Team": "platform"}.Validate())
+	assert.Error(t, types.Labels{"-team": "platform"}.Validate())
+
+	endpoint := types.Labels{types.LabelTeam: "platform", types.LabelProject: "gateway"}
+	key := types.Labels{types.LabelTeam: "search"}
+	merged := endpoint.Merge(key)
+	assert.Equal(t, types.Labels{types.LabelTeam: "search", types.LabelProject: "gateway"}, merged)
+	assert.Equal(t, "platform", endpoint[types.LabelTeam], "merge must not mutate the receiver")
+	assert.Nil(t, types.Labels(nil).Merge(nil))
+}
@@ -0,0 +1,126 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactMessageParams(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query":    map[string]interface{}{"type": "string"},
+			"db_pass":  map[string]interface{}{"type": "string", "format": "password"},
+			"max_rows": map[string]interface{}{"type": "integer"},
+			"connection": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"host":    map[string]interface{}{"type": "string"},
+					"api_key": map[string]interface{}{"type": "string", "x-sensitive": true},
+				},
+			},
+			"headers": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string", "writeOnly": true},
+			},
+		},
+	}
+	params := map[string]interface{}{
+		"name": "db__query",
+		"arguments": map[string]interface{}{
+			"query":      "select 1",
+			"db_pass":    "hunter2",
+			"max_rows":   10.0,
+			"connection": map[string]interface{}{"host": "db", "api_key": "k"},
+			"headers":    []interface{}{"Bearer abc"},
+			"token":      "unlisted-but-obvious",
+		},
+	}
+
+	redacted, paths := services.RedactMessageParams("tools/call", params, schema)
+
+	args := redacted["arguments"].(map[string]interface{})
+	assert.Equal(t, "select 1", args["query"])
+	assert.Equal(t, 10.0, args["max_rows"])
+	assert.Equal(t, types.RedactedValue, args["db_pass"])
+	assert.Equal(t, types.RedactedValue, args["token"])
+	assert.Equal(t, "db", args["connection"].(map[string]interface{})["host"])
+	assert.Equal(t, types.RedactedValue, args["connection"].(map[string]interface{})["api_key"])
+	assert.Equal(t, []interface{}{types.RedactedValue}, args["headers"])
+	assert.Equal(t, []string{
+		"arguments.connection.api_key",
+		"arguments.db_pass",
+		"arguments.headers[]",
+		"arguments.token",
+	}, paths)
+
+	// The original params are left untouched
+	assert.Equal(t, "hunter2", params["arguments"].(map[string]interface{})["db_pass"])
+
+	// Without a schema only well-known credential names are masked
+	redacted, paths = services.RedactMessageParams("initialize", map[string]interface{}{
+		"clientInfo":    map[string]interface{}{"name": "cli"},
+		"Authorization": "Bearer x",
+	}, nil)
+	assert.Equal(t, types.RedactedValue, redacted["Authorization"])
+	assert.Equal(t, []string{"Authorization"}, paths)
+}
+
+type recordedMessages chan *types.MCPMessageLog
+
+func (r recordedMessages) RecordMessage(ctx context.Context, entry *types.MCPMessageLog) error {
+	r <- entry
+	return nil
+}
+
+func TestMCPMessageLoggerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := make(recordedMessages, 4)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("endpoint", &types.Endpoint{ID: "ep-1", NamespaceID: "ns-1", OrganizationID: "org-1"})
+		c.Set("principal", &types.Principal{Type: types.PrincipalTypeAPIKey, APIKeyID: "key-1"})
+	})
+	router.POST("/mcp", middleware.MCPMessageLogger(recorder, types.TransportTypeStreamable), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"jsonrpc": "2.0",
+			"id":      7,
+			"error":   gin.H{"code": -32603, "message": "Tool execution failed"},
+		})
+	})
+
+	body := `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"db__query","arguments":{"q":"x"}}}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	select {
+	case entry := <-recorder:
+		assert.Equal(t, "tools/call", entry.Method)
+		assert.Equal(t, "db__query", entry.ToolName)
+		assert.Equal(t, "7", entry.RPCID)
+		assert.Equal(t, "STREAMABLE", entry.Transport)
+		assert.Equal(t, "ns-1", entry.NamespaceID)
+		assert.Equal(t, "org-1", entry.OrganizationID)
+		assert.Equal(t, "key-1", entry.PrincipalID)
+		assert.True(t, entry.IsError)
+		assert.Equal(t, -32603, entry.ErrorCode)
+		assert.Equal(t, int64(len(body)), entry.RequestSize)
+		assert.Equal(t, int64(w.Body.Len()), entry.ResultSize)
+	case <-time.After(2 * time.Second):
+		t.Fatal("message was not recorded")
+	}
+}
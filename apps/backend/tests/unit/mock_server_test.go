@@ -0,0 +1,220 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mcp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryMockTools struct {
+	protocols map[string]string // "org/server" -> protocol
+	tools     map[string]*types.MockTool
+	nextID    int
+}
+
+func newMemoryMockTools() *memoryMockTools {
+	return &memoryMockTools{
+		protocols: map[string]string{
+			"org-1/mock-1": types.ProtocolMock,
+			"org-1/stdio":  types.ProtocolStdio,
+			"org-2/mock-2": types.ProtocolMock,
+		},
+		tools: map[string]*types.MockTool{},
+	}
+}
+
+func (m *memoryMockTools) ServerProtocol(orgID, serverID string) (string, error) {
+	return m.protocols[orgID+"/"+serverID], nil
+}
+
+func (m *memoryMockTools) List(serverID string) ([]*types.MockTool, error) {
+	tools := []*types.MockTool{}
+	for _, tool := range m.tools {
+		if tool.ServerID == serverID {
+			copied := *tool
+			tools = append(tools, &copied)
+		}
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools, nil
+}
+
+func (m *memoryMockTools) Get(serverID, id string) (*types.MockTool, error) {
+	tool, ok := m.tools[id]
+	if !ok || tool.ServerID != serverID {
+		return nil, nil
+	}
+	copied := *tool
+	return &copied, nil
+}
+
+func (m *memoryMockTools) Create(tool *types.MockTool) error {
+	m.nextID++
+	tool.ID = fmt.Sprintf("tool-%d", m.nextID)
+	copied := *tool
+	m.tools[tool.ID] = &copied
+	return nil
+}
+
+func (m *memoryMockTools) Update(tool *types.MockTool) error {
+	copied := *tool
+	m.tools[tool.ID] = &copied
+	return nil
+}
+
+func (m *memoryMockTools) Delete(serverID, id string) (bool, error) {
+	tool, ok := m.tools[id]
+	if !ok || tool.ServerID != serverID {
+		return false, nil
+	}
+	delete(m.tools, id)
+	return true, nil
+}
+
+type recordingRefresher struct {
+	refreshed []string
+}
+
+func (r *recordingRefresher) RefreshServerTools(ctx context.Context, serverID string) error {
+	r.refreshed = append(r.refreshed, serverID)
+	return nil
+}
+
+// connectMockServer connects an MCP client to a mock server serving tools
+func connectMockServer(t *testing.T, tools ...types.MockTool) *mcp.MCPClient {
+	t.Helper()
+	client := mcp.NewMCPClient(&mcp.MockTransport{})
+	err := client.Connect(context.Background(), mcp.TransportConfig{
+		Type: "mock",
+		MockTools: func(ctx context.Context) ([]types.MockTool, error) {
+			return tools, nil
+		},
+	}, mcp.ClientInfo{Name: "test", Version: "1.0.0"})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestMockServer_ListsAndCallsTools(t *testing.T) {
+	client := connectMockServer(t,
+		types.MockTool{
+			Name:        "get_weather",
+			Description: "Current weather",
+			Response:    `{"city": {{json .arguments.city}}, "temperature": 21}`,
+		},
+		types.MockTool{Name: "greet", Response: "Hello {{.arguments.name}} from {{.tool}}"},
+	)
+	ctx := context.Background()
+
+	tools, err := client.ListTools(ctx)
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+	assert.Equal(t, "get_weather", tools[0].Name)
+	assert.Equal(t, "Current weather", tools[0].Description)
+	assert.Equal(t, map[string]interface{}{"type": "object"}, tools[0].InputSchema)
+
+	result, err := client.CallTool(ctx, "greet", map[string]interface{}{"name": "Ada"})
+	require.NoError(t, err)
+	callResult := result.(mcp.ToolsCallResult)
+	assert.Equal(t, "Hello Ada from greet", callResult.Content[0].Text)
+	assert.Nil(t, callResult.StructuredContent)
+
+	// Responses that render to a JSON object are structured content too
+	result, err = client.CallTool(ctx, "get_weather", map[string]interface{}{"city": "Oslo"})
+	require.NoError(t, err)
+	callResult = result.(mcp.ToolsCallResult)
+	assert.Equal(t, map[string]interface{}{"city": "Oslo", "temperature": float64(21)}, callResult.StructuredContent)
+
+	_, err = client.CallTool(ctx, "missing", nil)
+	assert.ErrorContains(t, err, "unknown tool: missing")
+}
+
+func TestMockServer_ErrorsAndDelays(t *testing.T) {
+	client := connectMockServer(t,
+		types.MockTool{Name: "fail", Response: "quota exceeded", IsError: true},
+		types.MockTool{Name: "slow", Response: "done", DelayMs: 50},
+	)
+	ctx := context.Background()
+
+	_, err := client.CallTool(ctx, "fail", nil)
+	assert.ErrorContains(t, err, "quota exceeded")
+
+	start := time.Now()
+	_, err = client.CallTool(ctx, "slow", nil)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// A delayed call gives up with its caller
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = client.CallTool(shortCtx, "slow", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMockServer_TemplateErrorsBecomeToolErrors(t *testing.T) {
+	result := mcp.MockToolResult(&types.MockTool{Name: "broken", Response: "{{.arguments.x.y.z}}"},
+		map[string]interface{}{"x": "flat"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "failed to render mock response of broken")
+}
+
+func TestMockServerService_ManagesTools(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryMockTools()
+	refresher := &recordingRefresher{}
+	service := services.NewMockServerServiceWithStore(store)
+	service.SetToolRefresher(refresher)
+
+	tool, err := service.CreateMockTool(ctx, "org-1", "mock-1", &types.CreateMockToolRequest{
+		Name:     " search ",
+		Response: "results for {{.arguments.query}}",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "search", tool.Name)
+	assert.Equal(t, map[string]interface{}{"type": "object"}, tool.InputSchema)
+
+	_, err = service.CreateMockTool(ctx, "org-1", "mock-1", &types.CreateMockToolRequest{Name: "SEARCH"})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+	_, err = service.CreateMockTool(ctx, "org-1", "mock-1", &types.CreateMockToolRequest{Name: "bad", Response: "{{.arguments"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	// Only mock servers of the organization have mock tools
+	_, err = service.CreateMockTool(ctx, "org-1", "stdio", &types.CreateMockToolRequest{Name: "echo"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	_, err = service.ListMockTools(ctx, "org-1", "mock-2")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+	_, err = service.GetMockTool(ctx, "org-2", "mock-2", tool.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	delay := 250
+	isError := true
+	updated, err := service.UpdateMockTool(ctx, "org-1", "mock-1", tool.ID, &types.UpdateMockToolRequest{
+		DelayMs: &delay,
+		IsError: &isError,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 250, updated.DelayMs)
+	assert.True(t, updated.IsError)
+	assert.Equal(t, "results for {{.arguments.query}}", updated.Response)
+
+	loaded, err := service.LoadMockTools(ctx, "mock-1")
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, 250, loaded[0].DelayMs)
+
+	require.NoError(t, service.DeleteMockTool(ctx, "org-1", "mock-1", tool.ID))
+	err = service.DeleteMockTool(ctx, "org-1", "mock-1", tool.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	// Every change refreshes the server's discovered tools
+	assert.Equal(t, []string{"mock-1", "mock-1", "mock-1"}, refresher.refreshed)
+}
@@ -0,0 +1,320 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	grantOrgID       = "org-1"
+	restrictedNSID   = "0b1c2d3e-4f5a-4b6c-8d7e-9f0a1b2c3d4e"
+	openNSID         = "1c2d3e4f-5a6b-4c7d-8e9f-0a1b2c3d4e5f"
+	grantedUserID    = "2d3e4f5a-6b7c-4d8e-9f0a-1b2c3d4e5f6a"
+	otherUserID      = "3e4f5a6b-7c8d-4e9f-8a1b-2c3d4e5f6a7b"
+	restrictedEPID   = "4f5a6b7c-8d9e-4f0a-9b2c-3d4e5f6a7b8c"
+	foreignUserID    = "5a6b7c8d-9e0f-4a1b-8c3d-4e5f6a7b8c9d"
+	foreignNSID      = "6b7c8d9e-0f1a-4b2c-9d4e-5f6a7b8c9d0e"
+	grantTeamID      = "7c8d9e0f-1a2b-4c3d-8e5f-6a7b8c9d0e1f"
+	grantCacheWindow = time.Minute
+)
+
+// memoryNamespaceGrants keeps grants in memory, mirroring
+// NamespaceGrantModel
+type memoryNamespaceGrants struct {
+	teams       map[string][]string
+	grants      []*types.NamespaceGrant
+	orgListings int
+}
+
+func (m *memoryNamespaceGrants) ListForNamespace(namespaceID string) ([]*types.NamespaceGrant, error) {
+	grants := []*types.NamespaceGrant{}
+	for _, grant := range m.grants {
+		if grant.NamespaceID == namespaceID {
+			grants = append(grants, grant)
+		}
+	}
+	return grants, nil
+}
+
+func (m *memoryNamespaceGrants) ListForOrganization(orgID string) ([]*types.NamespaceGrant, error) {
+	m.orgListings++
+	grants := []*types.NamespaceGrant{}
+	for _, grant := range m.grants {
+		if grant.OrganizationID == orgID {
+			grants = append(grants, grant)
+		}
+	}
+	return grants, nil
+}
+
+func (m *memoryNamespaceGrants) Upsert(grant *types.NamespaceGrant) error {
+	for _, existing := range m.grants {
+		if existing.NamespaceID == grant.NamespaceID && existing.SubjectType == grant.SubjectType && existing.SubjectID == grant.SubjectID {
+			existing.AccessLevel = grant.AccessLevel
+			*grant = *existing
+			return nil
+		}
+	}
+	grant.ID = uuid.New().String()
+	grant.CreatedAt = time.Now()
+	m.grants = append(m.grants, grant)
+	return nil
+}
+
+func (m *memoryNamespaceGrants) Delete(namespaceID, id string) (bool, error) {
+	for i, grant := range m.grants {
+		if grant.ID == id && grant.NamespaceID == namespaceID {
+			m.grants = append(m.grants[:i], m.grants[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryNamespaceGrants) NamespaceOrganization(namespaceID string) (string, error) {
+	switch namespaceID {
+	case restrictedNSID, openNSID:
+		return grantOrgID, nil
+	case foreignNSID:
+		return "org-2", nil
+	}
+	return "", nil
+}
+
+func (m *memoryNamespaceGrants) UserOrganization(userID string) (string, error) {
+	switch userID {
+	case grantedUserID, otherUserID:
+		return grantOrgID, nil
+	case foreignUserID:
+		return "org-2", nil
+	}
+	return "", nil
+}
+
+func (m *memoryNamespaceGrants) TeamOrganization(teamID string) (string, error) {
+	if teamID == grantTeamID {
+		return grantOrgID, nil
+	}
+	return "", nil
+}
+
+func (m *memoryNamespaceGrants) UserTeams(userID string) ([]string, error) {
+	return m.teams[userID], nil
+}
+
+func (m *memoryNamespaceGrants) EndpointNamespace(endpointID string) (string, error) {
+	if endpointID == restrictedEPID {
+		return restrictedNSID, nil
+	}
+	return "", nil
+}
+
+func grantSubject(userID, role string) *types.NamespaceSubject {
+	return &types.NamespaceSubject{UserID: userID, Role: role, OrganizationID: grantOrgID}
+}
+
+func TestNamespaceAccessGrants(t *testing.T) {
+	store := &memoryNamespaceGrants{}
+	access := services.NewNamespaceAccessServiceWithStore(store, grantCacheWindow)
+	ctx := context.Background()
+	member := grantSubject(grantedUserID, types.RoleUser)
+	other := grantSubject(otherUserID, types.RoleUser)
+	viewer := grantSubject(otherUserID, types.RoleViewer)
+
+	// Namespaces without grants are open to the organization
+	allowed, err := access.Allowed(ctx, other, restrictedNSID, types.NamespaceAccessWrite)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	_, err = access.Grant(ctx, grantOrgID, restrictedNSID, "admin-1", &types.CreateNamespaceGrantRequest{
+		SubjectType: types.NamespaceGrantSubjectUser, SubjectID: grantedUserID, AccessLevel: types.NamespaceAccessExecute,
+	})
+	require.NoError(t, err)
+	_, err = access.Grant(ctx, grantOrgID, restrictedNSID, "admin-1", &types.CreateNamespaceGrantRequest{
+		SubjectType: types.NamespaceGrantSubjectRole, SubjectID: types.RoleViewer, AccessLevel: types.NamespaceAccessRead,
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		subject *types.NamespaceSubject
+		name    string
+		level   string
+		allowed bool
+	}{
+		{name: "granted user executes", subject: member, level: types.NamespaceAccessExecute, allowed: true},
+		{name: "granted user reads", subject: member, level: types.NamespaceAccessRead, allowed: true},
+		{name: "execute grant excludes write", subject: member, level: types.NamespaceAccessWrite},
+		{name: "ungranted user is shut out", subject: other, level: types.NamespaceAccessRead},
+		{name: "role grant applies", subject: viewer, level: types.NamespaceAccessRead, allowed: true},
+		{name: "role grant level applies", subject: viewer, level: types.NamespaceAccessExecute},
+		{name: "admins bypass grants", subject: grantSubject(otherUserID, types.RoleAdmin), level: types.NamespaceAccessAdmin, allowed: true},
+	} {
+		allowed, err := access.Allowed(ctx, tc.subject, restrictedNSID, tc.level)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.allowed, allowed, tc.name)
+	}
+
+	allowed, err = access.Allowed(ctx, other, openNSID, types.NamespaceAccessWrite)
+	require.NoError(t, err)
+	assert.True(t, allowed, "grants on one namespace leave others open")
+
+	err = access.CheckEndpoint(ctx, other, restrictedEPID, types.NamespaceAccessRead)
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied))
+	assert.NoError(t, access.CheckEndpoint(ctx, member, restrictedEPID, types.NamespaceAccessExecute))
+}
+
+func TestNamespaceAccessTeamGrants(t *testing.T) {
+	store := &memoryNamespaceGrants{teams: map[string][]string{grantedUserID: {grantTeamID}}}
+	access := services.NewNamespaceAccessServiceWithStore(store, grantCacheWindow)
+	ctx := context.Background()
+
+	_, err := access.Grant(ctx, grantOrgID, restrictedNSID, "admin-1", &types.CreateNamespaceGrantRequest{
+		SubjectType: types.NamespaceGrantSubjectTeam, SubjectID: grantTeamID, AccessLevel: types.NamespaceAccessWrite,
+	})
+	require.NoError(t, err)
+
+	allowed, err := access.Allowed(ctx, grantSubject(grantedUserID, types.RoleUser), restrictedNSID, types.NamespaceAccessWrite)
+	require.NoError(t, err)
+	assert.True(t, allowed, "team members match grants to the team")
+
+	allowed, err = access.Allowed(ctx, grantSubject(otherUserID, types.RoleUser), restrictedNSID, types.NamespaceAccessRead)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	_, err = access.Grant(ctx, grantOrgID, restrictedNSID, "admin-1", &types.CreateNamespaceGrantRequest{
+		SubjectType: types.NamespaceGrantSubjectTeam, SubjectID: uuid.New().String(), AccessLevel: types.NamespaceAccessRead,
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "teams of other organizations are rejected")
+}
+
+func TestNamespaceAccessCacheInvalidatedOnChange(t *testing.T) {
+	store := &memoryNamespaceGrants{}
+	access := services.NewNamespaceAccessServiceWithStore(store, grantCacheWindow)
+	ctx := context.Background()
+	other := grantSubject(otherUserID, types.RoleUser)
+
+	for i := 0; i < 3; i++ {
+		_, err := access.Allowed(ctx, other, restrictedNSID, types.NamespaceAccessRead)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, store.orgListings, "grants are cached per organization")
+
+	grant, err := access.Grant(ctx, grantOrgID, restrictedNSID, "admin-1", &types.CreateNamespaceGrantRequest{
+		SubjectType: types.NamespaceGrantSubjectUser, SubjectID: grantedUserID, AccessLevel: types.NamespaceAccessRead,
+	})
+	require.NoError(t, err)
+	allowed, err := access.Allowed(ctx, other, restrictedNSID, types.NamespaceAccessRead)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	require.NoError(t, access.Revoke(ctx, grantOrgID, restrictedNSID, grant.ID))
+	allowed, err = access.Allowed(ctx, other, restrictedNSID, types.NamespaceAccessRead)
+	require.NoError(t, err)
+	assert.True(t, allowed, "revoking the last grant opens the namespace again")
+}
+
+func TestNamespaceGrantValidation(t *testing.T) {
+	access := services.NewNamespaceAccessServiceWithStore(&memoryNamespaceGrants{}, grantCacheWindow)
+	ctx := context.Background()
+	grant := func(namespaceID, subjectType, subjectID string) error {
+		_, err := access.Grant(ctx, grantOrgID, namespaceID, "admin-1", &types.CreateNamespaceGrantRequest{
+			SubjectType: subjectType, SubjectID: subjectID, AccessLevel: types.NamespaceAccessRead,
+		})
+		return err
+	}
+
+	assert.True(t, types.IsError(grant(foreignNSID, types.NamespaceGrantSubjectUser, grantedUserID), types.ErrCodeNotFound))
+	assert.True(t, types.IsError(grant("not-a-uuid", types.NamespaceGrantSubjectUser, grantedUserID), types.ErrCodeNotFound))
+	assert.True(t, types.IsError(grant(restrictedNSID, types.NamespaceGrantSubjectUser, foreignUserID), types.ErrCodeValidationFailed))
+	assert.True(t, types.IsError(grant(restrictedNSID, types.NamespaceGrantSubjectUser, "alice"), types.ErrCodeValidationFailed))
+	assert.True(t, types.IsError(grant(restrictedNSID, types.NamespaceGrantSubjectRole, types.RoleAdmin), types.ErrCodeValidationFailed))
+	assert.True(t, types.IsError(grant(restrictedNSID, types.NamespaceGrantSubjectRole, "owner"), types.ErrCodeValidationFailed))
+	assert.NoError(t, grant(restrictedNSID, types.NamespaceGrantSubjectRole, types.RoleAPIUser))
+}
+
+func TestRequireNamespaceAccessMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &memoryNamespaceGrants{grants: []*types.NamespaceGrant{{
+		OrganizationID: grantOrgID, NamespaceID: restrictedNSID, SubjectType: types.NamespaceGrantSubjectUser,
+		SubjectID: grantedUserID, AccessLevel: types.NamespaceAccessWrite,
+	}}}
+	authMiddleware := auth.NewMiddlewareWithInterface(nil, nil)
+	authMiddleware.SetNamespaceAccess(services.NewNamespaceAccessServiceWithStore(store, grantCacheWindow))
+
+	request := func(userID, path string) int {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", userID)
+			c.Set("role", types.RoleUser)
+			c.Set("organization_id", grantOrgID)
+			c.Next()
+		})
+		ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+		router.PUT("/namespaces/:id", authMiddleware.RequireNamespaceAccess(types.NamespaceAccessWrite), ok)
+		router.DELETE("/namespaces/:id", authMiddleware.RequireNamespaceAccess(types.NamespaceAccessAdmin), ok)
+		router.GET("/endpoints/:id", authMiddleware.RequireEndpointNamespaceAccess(types.NamespaceAccessRead), ok)
+
+		method := http.MethodPut
+		if path[0] == '!' {
+			method, path = http.MethodDelete, path[1:]
+		} else if path[1] == 'e' {
+			method = http.MethodGet
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request(grantedUserID, "/namespaces/"+restrictedNSID))
+	assert.Equal(t, http.StatusForbidden, request(grantedUserID, "!/namespaces/"+restrictedNSID), "write grants do not allow deletion")
+	assert.Equal(t, http.StatusForbidden, request(otherUserID, "/namespaces/"+restrictedNSID))
+	assert.Equal(t, http.StatusOK, request(otherUserID, "/namespaces/"+openNSID))
+	assert.Equal(t, http.StatusForbidden, request(otherUserID, "/endpoints/"+restrictedEPID))
+	assert.Equal(t, http.StatusOK, request(grantedUserID, "/endpoints/"+restrictedEPID))
+}
+
+func TestEndpointNamespaceAccessMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &memoryNamespaceGrants{grants: []*types.NamespaceGrant{{
+		OrganizationID: grantOrgID, NamespaceID: restrictedNSID, SubjectType: types.NamespaceGrantSubjectRole,
+		SubjectID: types.RoleAPIUser, AccessLevel: types.NamespaceAccessExecute,
+	}}}
+	access := services.NewNamespaceAccessServiceWithStore(store, grantCacheWindow)
+
+	request := func(principal *types.Principal, role string) int {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("endpoint", &types.Endpoint{Name: "shop", NamespaceID: restrictedNSID})
+			if principal != nil {
+				c.Set("principal", principal)
+				c.Set("role", role)
+			}
+			c.Next()
+		}, middleware.EndpointNamespaceAccessMiddleware(access))
+		router.POST("/mcp", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+		return w.Code
+	}
+
+	apiUser := &types.Principal{Type: types.PrincipalTypeAPIKey, UserID: grantedUserID, OrganizationID: grantOrgID}
+	assert.Equal(t, http.StatusOK, request(apiUser, types.RoleAPIUser))
+	assert.Equal(t, http.StatusForbidden, request(apiUser, types.RoleUser))
+	assert.Equal(t, http.StatusOK, request(nil, ""), "anonymous traffic follows the endpoint's auth settings")
+	client := &types.Principal{Type: types.PrincipalTypeOAuthClient, ClientID: "client-1", OrganizationID: grantOrgID}
+	assert.Equal(t, http.StatusOK, request(client, ""))
+}
@@ -0,0 +1,192 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryNodes keeps the node registry in memory
+type memoryNodes struct {
+	nodes map[string]*types.GatewayNode
+}
+
+func (m *memoryNodes) Heartbeat(node *types.GatewayNode) (bool, error) {
+	stored := *node
+	if existing, ok := m.nodes[node.ID]; ok {
+		stored.Draining = existing.Draining
+		stored.DrainRequestedBy = existing.DrainRequestedBy
+	}
+	m.nodes[node.ID] = &stored
+	return stored.Draining, nil
+}
+
+func (m *memoryNodes) List() ([]*types.GatewayNode, error) {
+	nodes := []*types.GatewayNode{}
+	for _, node := range m.nodes {
+		copied := *node
+		nodes = append(nodes, &copied)
+	}
+	return nodes, nil
+}
+
+func (m *memoryNodes) SetDraining(id string, draining bool, requestedBy string, at time.Time) (bool, error) {
+	node, ok := m.nodes[id]
+	if !ok {
+		return false, nil
+	}
+	node.Draining = draining
+	node.DrainRequestedBy = requestedBy
+	return true, nil
+}
+
+func (m *memoryNodes) Delete(id string) error {
+	delete(m.nodes, id)
+	return nil
+}
+
+func (m *memoryNodes) DeleteStale(before time.Time) (int64, error) {
+	var deleted int64
+	for id, node := range m.nodes {
+		if node.LastHeartbeatAt.Before(before) {
+			delete(m.nodes, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type fixedSessions map[string]int
+
+func (f fixedSessions) SessionsByTransport() map[string]int {
+	return f
+}
+
+type fixedHealth types.ServiceHealth
+
+func (f *fixedHealth) Health() *types.ServiceHealth {
+	health := types.ServiceHealth(*f)
+	return &health
+}
+
+func newNodeRegistry(store *memoryNodes, id string, now *time.Time) *services.NodeRegistry {
+	registry := services.NewNodeRegistryWithStore(store, services.NodeSettings{
+		ID:       id,
+		Hostname: id + ".internal",
+		Version:  "1.4.0",
+		Region:   "eu-west",
+		Interval: 10 * time.Second,
+	}, fixedSessions{"sse": 2, "websocket": 1}, &fixedHealth{Status: types.ServiceHealthHealthy, Score: 0.9})
+	registry.SetClock(func() time.Time { return *now })
+	return registry
+}
+
+func findNode(t *testing.T, fleet *types.NodeFleet, id string) *types.GatewayNode {
+	t.Helper()
+	for _, node := range fleet.Nodes {
+		if node.ID == id {
+			return node
+		}
+	}
+	t.Fatalf("node %s not listed", id)
+	return nil
+}
+
+func TestNodeRegistryListsFleet(t *testing.T) {
+	store := &memoryNodes{nodes: map[string]*types.GatewayNode{}}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	nodeA := newNodeRegistry(store, "node-a", &now)
+	nodeB := newNodeRegistry(store, "node-b", &now)
+	ctx := context.Background()
+
+	require.NoError(t, nodeA.Heartbeat(ctx))
+	require.NoError(t, nodeB.Heartbeat(ctx))
+	now = now.Add(20 * time.Second)
+	require.NoError(t, nodeA.Heartbeat(ctx))
+
+	fleet, err := nodeA.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fleet.Active)
+	assert.Equal(t, 6, fleet.Sessions)
+
+	self := findNode(t, fleet, "node-a")
+	assert.True(t, self.Self)
+	assert.Equal(t, types.NodeStatusActive, self.Status)
+	assert.Equal(t, int64(20), self.UptimeSeconds)
+	assert.Equal(t, 3, self.SessionCount)
+	assert.Equal(t, "1.4.0", self.Version)
+	assert.Equal(t, types.ServiceHealthHealthy, self.HealthStatus)
+	assert.Equal(t, 0.9, self.HealthScore)
+
+	// Three missed heartbeats make a node stale; its uptime stops at its
+	// last heartbeat
+	now = now.Add(20 * time.Second)
+	fleet, err = nodeA.List(ctx)
+	require.NoError(t, err)
+	stale := findNode(t, fleet, "node-b")
+	assert.Equal(t, types.NodeStatusStale, stale.Status)
+	assert.Equal(t, int64(0), stale.UptimeSeconds)
+	assert.Equal(t, 1, fleet.Stale)
+	assert.Equal(t, 3, fleet.Sessions)
+
+	// A day later the stale node is forgotten
+	now = now.Add(25 * time.Hour)
+	require.NoError(t, nodeA.Heartbeat(ctx))
+	assert.NotContains(t, store.nodes, "node-b")
+}
+
+func TestNodeRegistryDrain(t *testing.T) {
+	store := &memoryNodes{nodes: map[string]*types.GatewayNode{}}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	nodeA := newNodeRegistry(store, "node-a", &now)
+	nodeB := newNodeRegistry(store, "node-b", &now)
+	ctx := context.Background()
+	require.NoError(t, nodeA.Heartbeat(ctx))
+	require.NoError(t, nodeB.Heartbeat(ctx))
+
+	// Node A drains node B, which notices on its next heartbeat
+	node, err := nodeA.SetDraining(ctx, "node-b", true, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, types.NodeStatusDraining, node.Status)
+	assert.Equal(t, "admin-1", node.DrainRequestedBy)
+	assert.False(t, nodeB.Draining())
+	require.NoError(t, nodeB.Heartbeat(ctx))
+	assert.True(t, nodeB.Draining())
+
+	// Draining the node itself applies at once
+	_, err = nodeA.SetDraining(ctx, "node-a", true, "admin-1")
+	require.NoError(t, err)
+	assert.True(t, nodeA.Draining())
+	_, err = nodeA.SetDraining(ctx, "node-a", false, "admin-1")
+	require.NoError(t, err)
+	assert.False(t, nodeA.Draining())
+
+	_, err = nodeA.SetDraining(ctx, "node-z", true, "admin-1")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+}
+
+func TestDrainingNodeRefusesNewSessions(t *testing.T) {
+	manager := transport.NewManager(&types.TransportConfig{
+		EnabledTransports: []types.TransportType{types.TransportTypeSSE},
+	})
+	draining := true
+	manager.SetDrainCheck(func() bool { return draining })
+
+	_, _, err := manager.CreateConnection(context.Background(), types.TransportTypeSSE, "user-1", "org-1", "server-1")
+	require.Error(t, err)
+	assert.True(t, types.IsError(err, types.ErrCodeServiceUnavailable))
+}
+
+func TestNodeRegistryConfigValidation(t *testing.T) {
+	assert.NoError(t, (&config.NodeRegistryConfig{HeartbeatInterval: 15 * time.Second, StaleAfter: 45 * time.Second}).Validate())
+	assert.Error(t, (&config.NodeRegistryConfig{HeartbeatInterval: 15 * time.Second, StaleAfter: 10 * time.Second}).Validate())
+	assert.Error(t, (&config.NodeRegistryConfig{HeartbeatInterval: -time.Second}).Validate())
+}
@@ -0,0 +1,352 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mailer"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryNotificationStore struct {
+	admins        map[string][]*types.NotificationRecipient
+	prefs         map[string]*types.NotificationPreferences
+	organizations []string
+	tlsServers    []*types.TLSServerTarget
+	notifications []*types.Notification
+}
+
+func newMemoryNotificationStore() *memoryNotificationStore {
+	return &memoryNotificationStore{
+		admins: map[string][]*types.NotificationRecipient{
+			flagOrgA: {
+				{UserID: "admin-1", Email: "one@example.com", Name: "One"},
+				{UserID: "admin-2", Email: "two@example.com"},
+			},
+		},
+		prefs:         map[string]*types.NotificationPreferences{},
+		organizations: []string{flagOrgA},
+	}
+}
+
+func (m *memoryNotificationStore) ListAdmins(orgID string) ([]*types.NotificationRecipient, error) {
+	return m.admins[orgID], nil
+}
+
+func (m *memoryNotificationStore) ListDigestRecipients() ([]*types.NotificationRecipient, error) {
+	var recipients []*types.NotificationRecipient
+	for _, admins := range m.admins {
+		for _, admin := range admins {
+			if p := m.prefs[admin.UserID]; p != nil && p.EmailDigest {
+				recipients = append(recipients, admin)
+			}
+		}
+	}
+	return recipients, nil
+}
+
+func (m *memoryNotificationStore) HasUnread(userID, dedupKey string) (bool, error) {
+	for _, n := range m.notifications {
+		if n.UserID == userID && n.DedupKey == dedupKey && n.ReadAt == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryNotificationStore) Create(n *types.Notification) error {
+	n.ID = n.UserID + "-" + time.Now().Format(time.RFC3339Nano)
+	n.CreatedAt = time.Now()
+	m.notifications = append(m.notifications, n)
+	return nil
+}
+
+func (m *memoryNotificationStore) List(filter *types.NotificationListFilter) ([]*types.Notification, error) {
+	var result []*types.Notification
+	for _, n := range m.notifications {
+		if n.UserID == filter.UserID && (!filter.UnreadOnly || n.ReadAt == nil) {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryNotificationStore) ListUndigested(userID string) ([]*types.Notification, error) {
+	var result []*types.Notification
+	for _, n := range m.notifications {
+		if n.UserID == userID && n.ReadAt == nil && n.EmailedAt == nil {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryNotificationStore) CountUnread(userID string) (int, error) {
+	unread, _ := m.List(&types.NotificationListFilter{UserID: userID, UnreadOnly: true})
+	return len(unread), nil
+}
+
+func (m *memoryNotificationStore) MarkRead(userID, id string) (bool, error) {
+	for _, n := range m.notifications {
+		if n.ID == id && n.UserID == userID {
+			now := time.Now()
+			n.ReadAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryNotificationStore) MarkAllRead(userID string) (int64, error) {
+	var count int64
+	for _, n := range m.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			now := time.Now()
+			n.ReadAt = &now
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryNotificationStore) MarkEmailed(userID string, ids []string, at time.Time) error {
+	for _, n := range m.notifications {
+		for _, id := range ids {
+			if n.ID == id && n.UserID == userID {
+				n.EmailedAt = &at
+			}
+		}
+	}
+	return nil
+}
+
+func (m *memoryNotificationStore) GetPreferences(userID string) (*types.NotificationPreferences, error) {
+	return m.prefs[userID], nil
+}
+
+func (m *memoryNotificationStore) UpsertPreferences(prefs *types.NotificationPreferences) error {
+	m.prefs[prefs.UserID] = prefs
+	return nil
+}
+
+func (m *memoryNotificationStore) ListActiveOrganizations() ([]string, error) {
+	return m.organizations, nil
+}
+
+func (m *memoryNotificationStore) ListTLSServers() ([]*types.TLSServerTarget, error) {
+	return m.tlsServers, nil
+}
+
+type recordingMailer struct {
+	failFor  string
+	messages []*mailer.Message
+}
+
+func (r *recordingMailer) Send(ctx context.Context, msg *mailer.Message) error {
+	if msg.To == r.failFor {
+		return errors.New("relay unavailable")
+	}
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+type fixedQuotas struct {
+	quotas *types.PlanQuotas
+}
+
+func (f fixedQuotas) GetPlanQuotas(ctx context.Context, orgID string) (*types.PlanQuotas, error) {
+	return f.quotas, nil
+}
+
+func TestNotifyFansOutToAdminsAndDeduplicates(t *testing.T) {
+	store := newMemoryNotificationStore()
+	service := services.NewNotificationServiceWithStore(store)
+	event := &types.NotificationEvent{
+		OrganizationID: flagOrgA,
+		Type:           types.NotificationServerUnhealthy,
+		Title:          "Server weather is unhealthy",
+		DedupKey:       "server_unhealthy:srv-1",
+	}
+
+	created, err := service.Notify(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, 2, created)
+	assert.Equal(t, types.NotificationSeverityInfo, store.notifications[0].Severity)
+
+	// Unread notifications suppress repeats
+	created, err = service.Notify(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, 0, created)
+
+	// Once read, the condition can be raised again for that admin only
+	require.NoError(t, service.MarkRead(context.Background(), "admin-1", store.notifications[0].ID))
+	created, err = service.Notify(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, 1, created)
+
+	_, err = service.Notify(context.Background(), &types.NotificationEvent{OrganizationID: flagOrgA})
+	require.Error(t, err)
+}
+
+func TestNotificationMarkReadScopedToUser(t *testing.T) {
+	store := newMemoryNotificationStore()
+	service := services.NewNotificationServiceWithStore(store)
+	_, err := service.Notify(context.Background(), &types.NotificationEvent{
+		OrganizationID: flagOrgA, Type: types.NotificationQuotaNearing, Title: "quota",
+	})
+	require.NoError(t, err)
+
+	err = service.MarkRead(context.Background(), "admin-2", store.notifications[0].ID)
+	var apiErr *types.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.Status)
+
+	updated, err := service.MarkAllRead(context.Background(), "admin-2")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+
+	count, err := service.UnreadCount(context.Background(), "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestSendDigestsOnlyToOptedInUsers(t *testing.T) {
+	store := newMemoryNotificationStore()
+	service := services.NewNotificationServiceWithStore(store)
+	_, err := service.Notify(context.Background(), &types.NotificationEvent{
+		OrganizationID: flagOrgA, Type: types.NotificationQuotaNearing, Title: "servers quota at 90%",
+		Severity: types.NotificationSeverityWarning, Message: "Using 9 of 10 servers.",
+	})
+	require.NoError(t, err)
+
+	_, err = service.SetPreferences(context.Background(), "admin-1", &types.UpdateNotificationPreferencesRequest{EmailDigest: true})
+	require.NoError(t, err)
+
+	m := &recordingMailer{}
+	sent, err := service.SendDigests(context.Background(), m)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, m.messages, 1)
+	assert.Equal(t, "one@example.com", m.messages[0].To)
+	assert.Contains(t, m.messages[0].Body, "[WARNING] servers quota at 90%")
+	assert.Contains(t, m.messages[0].Body, "Using 9 of 10 servers.")
+
+	// Notifications already in a digest are not emailed again
+	sent, err = service.SendDigests(context.Background(), m)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestSendDigestsKeepsNotificationsWhenDeliveryFails(t *testing.T) {
+	store := newMemoryNotificationStore()
+	service := services.NewNotificationServiceWithStore(store)
+	_, err := service.Notify(context.Background(), &types.NotificationEvent{
+		OrganizationID: flagOrgA, Type: types.NotificationQuotaNearing, Title: "quota",
+	})
+	require.NoError(t, err)
+	store.prefs["admin-1"] = &types.NotificationPreferences{UserID: "admin-1", EmailDigest: true}
+
+	sent, err := service.SendDigests(context.Background(), &recordingMailer{failFor: "one@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	pending, _ := store.ListUndigested("admin-1")
+	assert.Len(t, pending, 1)
+}
+
+func TestCheckQuotasNotifiesAboveThreshold(t *testing.T) {
+	store := newMemoryNotificationStore()
+	service := services.NewNotificationServiceWithStore(store)
+	quotas := fixedQuotas{quotas: &types.PlanQuotas{
+		OrganizationID: flagOrgA,
+		PlanType:       "free",
+		Servers:        types.QuotaUsage{Limit: 10, Used: 8},
+		Sessions:       types.QuotaUsage{Limit: 100, Used: 10},
+	}}
+
+	created, err := service.CheckQuotas(context.Background(), quotas, 80)
+	require.NoError(t, err)
+	assert.Equal(t, 2, created)
+	assert.Equal(t, "quota_nearing:"+flagOrgA+":servers", store.notifications[0].DedupKey)
+	assert.Equal(t, types.NotificationSeverityWarning, store.notifications[0].Severity)
+
+	created, err = service.CheckQuotas(context.Background(), quotas, 80)
+	require.NoError(t, err)
+	assert.Equal(t, 0, created)
+}
+
+func TestCheckCertificatesNotifiesBeforeExpiry(t *testing.T) {
+	store := newMemoryNotificationStore()
+	store.tlsServers = []*types.TLSServerTarget{
+		{ServerID: "srv-soon", OrganizationID: flagOrgA, Name: "soon", URL: "https://soon.example.com"},
+		{ServerID: "srv-later", OrganizationID: flagOrgA, Name: "later", URL: "https://later.example.com"},
+		{ServerID: "srv-expired", OrganizationID: flagOrgA, Name: "expired", URL: "https://expired.example.com"},
+	}
+	expiries := map[string]time.Time{
+		"https://soon.example.com":    time.Now().Add(3 * 24 * time.Hour),
+		"https://later.example.com":   time.Now().Add(90 * 24 * time.Hour),
+		"https://expired.example.com": time.Now().Add(-time.Hour),
+	}
+	probe := func(ctx context.Context, rawURL string) (time.Time, error) {
+		return expiries[rawURL], nil
+	}
+
+	service := services.NewNotificationServiceWithStore(store)
+	created, err := service.CheckCertificates(context.Background(), probe, 14)
+	require.NoError(t, err)
+	assert.Equal(t, 4, created)
+
+	severities := map[string]string{}
+	for _, n := range store.notifications {
+		severities[n.ResourceID] = n.Severity
+	}
+	assert.Equal(t, types.NotificationSeverityWarning, severities["srv-soon"])
+	assert.Equal(t, types.NotificationSeverityCritical, severities["srv-expired"])
+	assert.NotContains(t, severities, "srv-later")
+}
+
+func TestNotificationHandlerListsCallerNotifications(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newMemoryNotificationStore()
+	service := services.NewNotificationServiceWithStore(store)
+	_, err := service.Notify(context.Background(), &types.NotificationEvent{
+		OrganizationID: flagOrgA, Type: types.NotificationQuotaNearing, Title: "quota",
+	})
+	require.NoError(t, err)
+
+	handler := handlers.NewNotificationHandler(service)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", "admin-1") })
+	router.GET("/api/notifications", handler.List)
+	router.POST("/api/notifications/:id/read", handler.MarkRead)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/notifications?unread=true", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"unread_count":1`)
+	assert.Contains(t, w.Body.String(), `"title":"quota"`)
+	assert.NotContains(t, w.Body.String(), "dedup_key")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/notifications/missing/read", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBuildMessageHeaders(t *testing.T) {
+	raw := string(mailer.BuildMessage("gateway@example.com", &mailer.Message{
+		To: "admin@example.com", Subject: "Digest", Body: "line one\nline two",
+	}))
+	assert.Contains(t, raw, "From: gateway@example.com\r\n")
+	assert.Contains(t, raw, "Subject: Digest\r\n")
+	assert.Contains(t, raw, "\r\n\r\nline one\r\nline two")
+}
@@ -0,0 +1,123 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProtectedResourceRouter(withEndpoints bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	oauthService := auth.NewOAuthService(nil, "test-jwt-secret", discoveryBaseURL, auth.DefaultOAuthConfig())
+	handler := handlers.NewOAuthHandler(oauthService)
+	if withEndpoints {
+		handler.SetEndpointResolver(stubDiscoveryEndpoints{
+			EndpointService: services.NewEndpointService(nil, discoveryBaseURL),
+			endpoints: map[string]*types.EndpointConfig{
+				"secure": {Endpoint: &types.Endpoint{Name: "secure", IsActive: true, EnableOAuth: true}},
+				"keys":   {Endpoint: &types.Endpoint{Name: "keys", IsActive: true, EnableAPIKeyAuth: true}},
+				"open":   {Endpoint: &types.Endpoint{Name: "open", IsActive: true, EnableOAuth: true, EnablePublicAccess: true}},
+			},
+		})
+	}
+
+	router := gin.New()
+	router.GET("/.well-known/oauth-protected-resource", handler.DiscoverProtectedResource)
+	router.GET("/.well-known/oauth-protected-resource/*path", handler.DiscoverProtectedResource)
+	return router
+}
+
+func getProtectedResource(router *gin.Engine, path string) (*httptest.ResponseRecorder, types.ProtectedResourceMetadata) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var metadata types.ProtectedResourceMetadata
+	_ = json.Unmarshal(w.Body.Bytes(), &metadata)
+	return w, metadata
+}
+
+func TestEndpointProtectedResourceMetadata(t *testing.T) {
+	router := newProtectedResourceRouter(true)
+
+	w, metadata := getProtectedResource(router, "/.well-known/oauth-protected-resource/api/public/endpoints/secure/mcp")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, discoveryBaseURL+"/api/public/endpoints/secure/mcp", metadata.Resource)
+	assert.Equal(t, []string{discoveryBaseURL}, metadata.AuthorizationServers)
+	assert.Equal(t, []string{"header"}, metadata.BearerMethodsSupported)
+	require.NotNil(t, metadata.ResourceName)
+	assert.Equal(t, "secure", *metadata.ResourceName)
+	require.NotNil(t, metadata.ResourceDocumentation)
+	assert.Equal(t, discoveryBaseURL+"/api/public/endpoints/secure/api/docs", *metadata.ResourceDocumentation)
+
+	w, metadata = getProtectedResource(router, "/.well-known/oauth-protected-resource/api/public/endpoints/secure/sse")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, discoveryBaseURL+"/api/public/endpoints/secure/sse", metadata.Resource)
+}
+
+func TestEndpointProtectedResourceMetadataNotFound(t *testing.T) {
+	router := newProtectedResourceRouter(true)
+
+	for _, name := range []string{"missing", "keys", "open"} {
+		t.Run(name, func(t *testing.T) {
+			w, _ := getProtectedResource(router, "/.well-known/oauth-protected-resource/api/public/endpoints/"+name+"/mcp")
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		})
+	}
+}
+
+func TestGatewayProtectedResourceMetadata(t *testing.T) {
+	w, metadata := getProtectedResource(newProtectedResourceRouter(true), "/.well-known/oauth-protected-resource")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, discoveryBaseURL, metadata.Resource)
+
+	// Without an endpoint resolver every path gets the gateway metadata
+	w, metadata = getProtectedResource(newProtectedResourceRouter(false), "/.well-known/oauth-protected-resource/api/public/endpoints/secure/mcp")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, discoveryBaseURL, metadata.Resource)
+}
+
+func TestEndpointAuthAdvertisesResourceMetadata(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, tt := range []struct {
+		endpoint *types.Endpoint
+		name     string
+		header   string
+	}{
+		{
+			name:     "oauth endpoint",
+			endpoint: &types.Endpoint{Name: "secure", IsActive: true, EnableOAuth: true},
+			header:   `Bearer resource_metadata="` + discoveryBaseURL + `/.well-known/oauth-protected-resource/api/public/endpoints/secure/mcp"`,
+		},
+		{
+			name:     "api key endpoint",
+			endpoint: &types.Endpoint{Name: "secure", IsActive: true, EnableAPIKeyAuth: true},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/api/public/endpoints/:endpoint_name/mcp", func(c *gin.Context) {
+				c.Set("endpoint", tt.endpoint)
+				c.Next()
+			}, middleware.EndpointAuthMiddleware(nil, nil, nil, discoveryBaseURL+"/"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/public/endpoints/secure/mcp", nil))
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tt.header, w.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
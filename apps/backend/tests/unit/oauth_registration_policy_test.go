@@ -0,0 +1,194 @@
+package unit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSoftwareStatementKey(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func signSoftwareStatement(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	statement, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+	require.NoError(t, err)
+	return statement
+}
+
+func requireRegistrationError(t *testing.T, err error, code string) {
+	var regErr *types.RegistrationError
+	require.True(t, errors.As(err, &regErr), "expected a registration error, got %v", err)
+	assert.Equal(t, code, regErr.Code)
+}
+
+func TestValidateRegistrationGrantTypesAndRedirects(t *testing.T) {
+	policy := &types.OAuthRegistrationPolicy{
+		AllowedGrantTypes:   []string{types.GrantTypeAuthorizationCode, types.GrantTypeRefreshToken},
+		RedirectURIPatterns: []string{"https://*.example.com/callback", "http://localhost:*/cb"},
+	}
+
+	require.NoError(t, auth.ValidateRegistration(nil, &types.ClientRegistrationRequest{GrantTypes: []string{"anything"}}))
+
+	require.NoError(t, auth.ValidateRegistration(policy, &types.ClientRegistrationRequest{
+		GrantTypes:   []string{types.GrantTypeAuthorizationCode},
+		RedirectURIs: []string{"https://app.example.com/callback", "http://localhost:3000/cb"},
+	}))
+
+	// Omitted grant types default to client_credentials, which is not allowed
+	err := auth.ValidateRegistration(policy, &types.ClientRegistrationRequest{})
+	requireRegistrationError(t, err, types.ErrorInvalidClientMetadata)
+
+	err = auth.ValidateRegistration(policy, &types.ClientRegistrationRequest{
+		GrantTypes:   []string{types.GrantTypeAuthorizationCode},
+		RedirectURIs: []string{"https://evil.com/callback?x=.example.com/callback"},
+	})
+	requireRegistrationError(t, err, types.ErrorInvalidRedirectURI)
+
+	err = auth.ValidateRegistration(policy, &types.ClientRegistrationRequest{
+		GrantTypes:   []string{types.GrantTypeAuthorizationCode},
+		RedirectURIs: []string{"https://attacker@app.example.com/callback"},
+	})
+	requireRegistrationError(t, err, types.ErrorInvalidRedirectURI)
+}
+
+func TestValidateRegistrationSoftwareStatement(t *testing.T) {
+	key, publicPEM := newSoftwareStatementKey(t)
+	policy := &types.OAuthRegistrationPolicy{
+		RequireSoftwareStatement: true,
+		SoftwareStatementKeys:    []string{publicPEM},
+		TrustedSoftwareIssuers:   []string{"https://vendor.example.com"},
+		AllowedGrantTypes:        []string{types.GrantTypeAuthorizationCode},
+	}
+
+	t.Run("missing statement", func(t *testing.T) {
+		err := auth.ValidateRegistration(policy, &types.ClientRegistrationRequest{GrantTypes: []string{types.GrantTypeAuthorizationCode}})
+		requireRegistrationError(t, err, types.ErrorInvalidSoftwareStatement)
+	})
+
+	t.Run("claims override request metadata", func(t *testing.T) {
+		req := &types.ClientRegistrationRequest{
+			ClientName: "spoofed",
+			GrantTypes: []string{types.GrantTypeClientCredentials},
+			SoftwareStatement: signSoftwareStatement(t, key, jwt.MapClaims{
+				"iss":         "https://vendor.example.com",
+				"software_id": "vendor-agent",
+				"client_name": "Vendor Agent",
+				"grant_types": []string{types.GrantTypeAuthorizationCode},
+			}),
+		}
+		require.NoError(t, auth.ValidateRegistration(policy, req))
+		assert.Equal(t, "Vendor Agent", req.ClientName)
+		assert.Equal(t, "vendor-agent", req.SoftwareID)
+		assert.Equal(t, []string{types.GrantTypeAuthorizationCode}, req.GrantTypes)
+	})
+
+	t.Run("untrusted issuer", func(t *testing.T) {
+		err := auth.ValidateRegistration(policy, &types.ClientRegistrationRequest{
+			SoftwareStatement: signSoftwareStatement(t, key, jwt.MapClaims{"iss": "https://other.example.com"}),
+		})
+		requireRegistrationError(t, err, types.ErrorUnapprovedSoftwareStatement)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		otherKey, _ := newSoftwareStatementKey(t)
+		err := auth.ValidateRegistration(policy, &types.ClientRegistrationRequest{
+			SoftwareStatement: signSoftwareStatement(t, otherKey, jwt.MapClaims{"iss": "https://vendor.example.com"}),
+		})
+		requireRegistrationError(t, err, types.ErrorInvalidSoftwareStatement)
+	})
+
+	t.Run("shared secret algorithms rejected", func(t *testing.T) {
+		statement, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": "https://vendor.example.com"}).
+			SignedString([]byte(publicPEM))
+		require.NoError(t, err)
+		err = auth.ValidateRegistration(policy, &types.ClientRegistrationRequest{SoftwareStatement: statement})
+		requireRegistrationError(t, err, types.ErrorInvalidSoftwareStatement)
+	})
+
+	t.Run("not accepted without keys", func(t *testing.T) {
+		err := auth.ValidateRegistration(&types.OAuthRegistrationPolicy{}, &types.ClientRegistrationRequest{
+			SoftwareStatement: signSoftwareStatement(t, key, jwt.MapClaims{}),
+		})
+		requireRegistrationError(t, err, types.ErrorUnapprovedSoftwareStatement)
+	})
+}
+
+func TestParseSoftwareStatementKey(t *testing.T) {
+	_, publicPEM := newSoftwareStatementKey(t)
+	_, err := auth.ParseSoftwareStatementKey(publicPEM)
+	require.NoError(t, err)
+
+	_, err = auth.ParseSoftwareStatementKey("not a key")
+	require.Error(t, err)
+}
+
+func newMockOAuthService(t *testing.T) (*auth.OAuthService, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return auth.NewOAuthService(sqlx.NewDb(db, "postgres"), "test-jwt-secret", discoveryBaseURL, nil), mock
+}
+
+func policyRows(requireIAT bool) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{
+		"organization_id", "require_initial_access_token", "require_software_statement",
+		"allowed_grant_types", "redirect_uri_patterns", "trusted_software_issuers",
+		"software_statement_keys", "unused_client_ttl_hours", "updated_by", "updated_at",
+	}).AddRow(flagOrgA, requireIAT, false, pq.StringArray{}, pq.StringArray{}, pq.StringArray{},
+		pq.StringArray{}, 24, "", time.Now())
+}
+
+func TestRegisterDynamicClientRequiresInitialAccessToken(t *testing.T) {
+	service, mock := newMockOAuthService(t)
+	mock.ExpectQuery("FROM oauth_registration_policies").WithArgs(flagOrgA).WillReturnRows(policyRows(true))
+
+	_, err := service.RegisterDynamicClient(context.Background(), &types.ClientRegistrationRequest{ClientName: "agent"}, flagOrgA, "")
+	requireRegistrationError(t, err, types.ErrorInvalidToken)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegisterDynamicClientRejectsUnusableInitialAccessToken(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	columns := []string{"id", "organization_id", "max_uses", "use_count", "expires_at", "revoked_at"}
+
+	tests := []struct {
+		rows *sqlmock.Rows
+		name string
+	}{
+		{name: "unknown", rows: sqlmock.NewRows(columns)},
+		{name: "expired", rows: sqlmock.NewRows(columns).AddRow("iat-1", flagOrgA, 0, 0, past, nil)},
+		{name: "revoked", rows: sqlmock.NewRows(columns).AddRow("iat-1", flagOrgA, 0, 0, nil, past)},
+		{name: "used up", rows: sqlmock.NewRows(columns).AddRow("iat-1", flagOrgA, 2, 2, nil, nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockOAuthService(t)
+			mock.ExpectQuery("FROM oauth_initial_access_tokens").WillReturnRows(tt.rows)
+
+			_, err := service.RegisterDynamicClient(context.Background(), &types.ClientRegistrationRequest{}, "", "iat_token")
+			requireRegistrationError(t, err, types.ErrorInvalidToken)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+func searchTool() types.NamespaceTool {
+	var input map[string]interface{}
+	_ = json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["query"],
+		"properties": {
+			"query": {"type": "string", "examples": ["gateway docs"]},
+			"limit": {"type": "integer", "minimum": 1, "maximum": 50},
+			"mode": {"type": ["string", "null"], "enum": ["fast", "deep"]},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		}
+	}`), &input)
+	return types.NamespaceTool{ToolName: "search", PrefixedName: "docs__search", InputSchema: input}
+}
+
+func TestOpenAPISecuritySchemes(t *testing.T) {
+	generator := services.NewOpenAPIGenerator("https://gateway.example.com")
+
+	spec := generator.GenerateSpec(&types.Endpoint{Name: "keys", EnableAPIKeyAuth: true, UseQueryParamAuth: true}, &types.Namespace{}, nil)
+	assert.Contains(t, spec.Components.SecuritySchemes, "api_key")
+	assert.Contains(t, spec.Components.SecuritySchemes, "api_key_query")
+	assert.Contains(t, spec.Components.SecuritySchemes, "bearer")
+	assert.NotContains(t, spec.Components.SecuritySchemes, "oauth2")
+	assert.Len(t, spec.Security, 3, "each auth method is an alternative")
+
+	spec = generator.GenerateSpec(&types.Endpoint{Name: "oauth", EnableOAuth: true}, &types.Namespace{}, nil)
+	require.Contains(t, spec.Components.SecuritySchemes, "oauth2")
+	flows := spec.Components.SecuritySchemes["oauth2"].Flows
+	require.NotNil(t, flows.ClientCredentials)
+	assert.Equal(t, "https://gateway.example.com/oauth/token", flows.ClientCredentials.TokenURL)
+	assert.NotContains(t, spec.Components.SecuritySchemes, "api_key")
+
+	spec = generator.GenerateSpec(&types.Endpoint{Name: "open", EnablePublicAccess: true}, &types.Namespace{}, []types.NamespaceTool{searchTool()})
+	assert.Empty(t, spec.Security)
+	assert.NotContains(t, spec.Paths["/tools/search"].Post.Responses, "401", "public endpoints never answer 401")
+}
+
+func TestOpenAPIToolExamplesAndErrors(t *testing.T) {
+	generator := services.NewOpenAPIGenerator("https://gateway.example.com")
+	spec := generator.GenerateSpec(&types.Endpoint{Name: "docs", EnableAPIKeyAuth: true}, &types.Namespace{}, []types.NamespaceTool{searchTool()})
+
+	operation := spec.Paths["/tools/search"].Post
+	request := operation.RequestBody.Content["application/json"]
+	assert.Equal(t, []string{"query"}, request.Schema.Required)
+	assert.True(t, request.Schema.Properties["mode"].Nullable)
+	assert.Equal(t, 50.0, *request.Schema.Properties["limit"].Maximum)
+	assert.Equal(t, map[string]interface{}{
+		"query": "gateway docs",
+		"limit": int64(1),
+		"mode":  "fast",
+		"tags":  []interface{}{"string"},
+	}, request.Example)
+
+	for _, status := range []string{"400", "401", "403", "429", "500"} {
+		require.Contains(t, operation.Responses, status)
+		assert.Equal(t, "#/components/schemas/Error", operation.Responses[status].Content["application/json"].Schema.Ref, status)
+	}
+	assert.Len(t, spec.Components.Schemas["Error"].OneOf, 2)
+
+	// Recorded calls are logged under the namespaced tool name
+	recorded := services.LatestToolArguments([]*types.MCPMessageLog{
+		{ToolName: "docs__search", IsError: true, Params: map[string]interface{}{"arguments": map[string]interface{}{"query": "broken"}}},
+		{ToolName: "docs__search", RedactedFields: []string{"arguments.token"}, Params: map[string]interface{}{"arguments": map[string]interface{}{"query": "masked"}}},
+		{ToolName: "docs__search", Params: map[string]interface{}{"arguments": map[string]interface{}{"query": "rate limits"}}},
+		{ToolName: "docs__search", Params: map[string]interface{}{"arguments": map[string]interface{}{"query": "older"}}},
+	})
+	assert.Equal(t, map[string]map[string]interface{}{"docs__search": {"query": "rate limits"}}, recorded)
+
+	generator.ApplyRecordedExamples(spec, map[string]map[string]interface{}{"search": recorded["docs__search"]})
+	assert.Equal(t, map[string]interface{}{"query": "rate limits"}, spec.Paths["/tools/search"].Post.RequestBody.Content["application/json"].Example)
+}
@@ -0,0 +1,93 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+)
+
+var lightArgon2 = auth.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestPasswordHasherArgon2id(t *testing.T) {
+	hasher := auth.NewPasswordHasher(auth.HashAlgorithmArgon2id, bcrypt.MinCost, lightArgon2)
+
+	hash, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	assert.Equal(t, auth.HashAlgorithmArgon2id, auth.HashAlgorithmOf(hash))
+
+	other, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "each hash gets its own salt")
+
+	ok, rehash := hasher.Verify("correct horse", hash)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	ok, rehash = hasher.Verify("battery staple", hash)
+	assert.False(t, ok)
+	assert.False(t, rehash)
+	assert.True(t, auth.VerifySecret("correct horse", hash))
+}
+
+func TestPasswordHasherRehashesBcrypt(t *testing.T) {
+	legacy := auth.NewPasswordHasher(auth.HashAlgorithmBcrypt, bcrypt.MinCost, auth.Argon2Params{})
+	hash, err := legacy.Hash("correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, auth.HashAlgorithmBcrypt, auth.HashAlgorithmOf(hash))
+
+	ok, rehash := legacy.Verify("correct horse", hash)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	upgraded := auth.NewPasswordHasher(auth.HashAlgorithmArgon2id, bcrypt.MinCost, lightArgon2)
+	ok, rehash = upgraded.Verify("correct horse", hash)
+	assert.True(t, ok)
+	assert.True(t, rehash, "bcrypt hashes are upgraded to argon2id")
+
+	ok, rehash = upgraded.Verify("battery staple", hash)
+	assert.False(t, ok)
+	assert.False(t, rehash)
+
+	costlier := auth.NewPasswordHasher(auth.HashAlgorithmBcrypt, bcrypt.MinCost+1, auth.Argon2Params{})
+	_, rehash = costlier.Verify("correct horse", hash)
+	assert.True(t, rehash, "a new bcrypt cost rehashes")
+}
+
+func TestPasswordHasherRehashesOnParameterChange(t *testing.T) {
+	hash, err := auth.NewPasswordHasher(auth.HashAlgorithmArgon2id, 0, lightArgon2).Hash("correct horse")
+	require.NoError(t, err)
+
+	stronger := auth.NewPasswordHasher(auth.HashAlgorithmArgon2id, 0, auth.Argon2Params{Memory: 2048, Iterations: 1, Parallelism: 1})
+	ok, rehash := stronger.Verify("correct horse", hash)
+	assert.True(t, ok, "hashes verify with the parameters they were made with")
+	assert.True(t, rehash)
+
+	downgraded := auth.NewPasswordHasher(auth.HashAlgorithmBcrypt, bcrypt.MinCost, auth.Argon2Params{})
+	ok, rehash = downgraded.Verify("correct horse", hash)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+}
+
+func TestPasswordHasherRejectsMalformedHashes(t *testing.T) {
+	hasher := auth.NewPasswordHasher(auth.HashAlgorithmArgon2id, 0, lightArgon2)
+	for _, encoded := range []string{
+		"",
+		"plaintext",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0$a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHRzYWx0$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0$",
+	} {
+		ok, rehash := hasher.Verify("correct horse", encoded)
+		assert.False(t, ok, encoded)
+		assert.False(t, rehash, encoded)
+	}
+	assert.Equal(t, "", auth.HashAlgorithmOf("plaintext"))
+}
@@ -0,0 +1,186 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// memoryPlayground keeps playground executions and settings
+type memoryPlayground struct {
+	executions []*types.PlaygroundExecution
+	settings   map[string]*types.PlaygroundHistorySettings
+}
+
+func newMemoryPlayground() *memoryPlayground {
+	return &memoryPlayground{settings: map[string]*types.PlaygroundHistorySettings{}}
+}
+
+func (m *memoryPlayground) Create(execution *types.PlaygroundExecution) error {
+	execution.ID = fmt.Sprintf("exec-%d", len(m.executions)+1)
+	m.executions = append(m.executions, execution)
+	return nil
+}
+
+func (m *memoryPlayground) List(orgID, userID string, query *types.PlaygroundHistoryQuery) ([]*types.PlaygroundExecution, error) {
+	var executions []*types.PlaygroundExecution
+	for i := len(m.executions) - 1; i >= 0; i-- {
+		execution := m.executions[i]
+		if execution.OrganizationID != orgID || execution.UserID != userID {
+			continue
+		}
+		if query.Search != "" && !strings.Contains(execution.ToolName, query.Search) {
+			continue
+		}
+		copied := *execution
+		executions = append(executions, &copied)
+	}
+	return executions, nil
+}
+
+func (m *memoryPlayground) Get(orgID, id string) (*types.PlaygroundExecution, error) {
+	for _, execution := range m.executions {
+		if execution.OrganizationID == orgID && execution.ID == id {
+			copied := *execution
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryPlayground) Delete(orgID, userID, id string) (bool, error) {
+	for i, execution := range m.executions {
+		if execution.OrganizationID == orgID && execution.UserID == userID && execution.ID == id {
+			m.executions = append(m.executions[:i], m.executions[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryPlayground) Clear(orgID, userID string) (int64, error) {
+	var kept []*types.PlaygroundExecution
+	for _, execution := range m.executions {
+		if execution.OrganizationID != orgID || execution.UserID != userID {
+			kept = append(kept, execution)
+		}
+	}
+	cleared := int64(len(m.executions) - len(kept))
+	m.executions = kept
+	return cleared, nil
+}
+
+func (m *memoryPlayground) GetSettings(orgID string) (*types.PlaygroundHistorySettings, error) {
+	return m.settings[orgID], nil
+}
+
+func (m *memoryPlayground) SetSettings(settings *types.PlaygroundHistorySettings) error {
+	m.settings[settings.OrganizationID] = settings
+	return nil
+}
+
+func playgroundCall(userID, tool string, arguments map[string]interface{}) *types.PlaygroundExecution {
+	return &types.PlaygroundExecution{
+		OrganizationID: grantOrgID,
+		UserID:         userID,
+		Source:         types.PlaygroundSourceSandbox,
+		EndpointID:     "endpoint-1",
+		ToolName:       tool,
+		Arguments:      arguments,
+		Result:         map[string]interface{}{"success": true},
+		DurationMS:     12.5,
+	}
+}
+
+func TestPlaygroundHistoryIsPrivate(t *testing.T) {
+	store := newMemoryPlayground()
+	svc := services.NewPlaygroundHistoryServiceWithStore(store)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Record(ctx, playgroundCall(grantedUserID, "search", map[string]interface{}{"query": "go", "password": "hunter2"})))
+	require.NoError(t, svc.Record(ctx, playgroundCall(grantedUserID, "fetch", nil)))
+	require.NoError(t, svc.Record(ctx, playgroundCall(otherUserID, "search", nil)))
+
+	own, err := svc.ListHistory(ctx, grantOrgID, grantedUserID, false, &types.PlaygroundHistoryQuery{})
+	require.NoError(t, err)
+	require.Len(t, own, 2)
+	assert.Equal(t, "fetch", own[0].ToolName, "newest first")
+
+	found, err := svc.ListHistory(ctx, grantOrgID, grantedUserID, false, &types.PlaygroundHistoryQuery{Search: "sea"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "hunter2", found[0].Arguments["password"], "owners see their own arguments")
+
+	_, err = svc.ListHistory(ctx, grantOrgID, otherUserID, false, &types.PlaygroundHistoryQuery{UserID: grantedUserID})
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied))
+	_, err = svc.ListHistory(ctx, grantOrgID, otherUserID, true, &types.PlaygroundHistoryQuery{UserID: grantedUserID})
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied), "admins need the organization's consent")
+
+	_, err = svc.GetExecution(ctx, grantOrgID, otherUserID, true, found[0].ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+	_, err = svc.OwnExecution(ctx, grantOrgID, otherUserID, found[0].ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "only owners can re-run an execution")
+	assert.True(t, types.IsError(svc.DeleteExecution(ctx, grantOrgID, otherUserID, found[0].ID), types.ErrCodeNotFound))
+}
+
+func TestPlaygroundHistoryAdminVisibility(t *testing.T) {
+	store := newMemoryPlayground()
+	svc := services.NewPlaygroundHistoryServiceWithStore(store)
+	ctx := context.Background()
+
+	require.NoError(t, svc.Record(ctx, playgroundCall(grantedUserID, "login", map[string]interface{}{"user": "ada", "password": "hunter2"})))
+
+	allow := true
+	settings, err := svc.UpdateSettings(ctx, grantOrgID, otherUserID, &types.UpdatePlaygroundHistorySettingsRequest{AdminsCanView: &allow})
+	require.NoError(t, err)
+	assert.True(t, settings.Enabled, "unset fields keep their defaults")
+	assert.True(t, settings.AdminsCanView)
+
+	_, err = svc.ListHistory(ctx, grantOrgID, otherUserID, false, &types.PlaygroundHistoryQuery{UserID: grantedUserID})
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied), "members stay out even when admins may view")
+
+	executions, err := svc.ListHistory(ctx, grantOrgID, otherUserID, true, &types.PlaygroundHistoryQuery{UserID: grantedUserID})
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, types.RedactedValue, executions[0].Arguments["password"])
+	assert.Equal(t, "ada", executions[0].Arguments["user"])
+	assert.Equal(t, []string{"arguments.password"}, executions[0].RedactedFields)
+
+	execution, err := svc.GetExecution(ctx, grantOrgID, otherUserID, true, executions[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.RedactedValue, execution.Arguments["password"])
+
+	own, err := svc.OwnExecution(ctx, grantOrgID, grantedUserID, executions[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", own.Arguments["password"], "the stored arguments are left intact for re-runs")
+}
+
+func TestPlaygroundHistoryRecording(t *testing.T) {
+	store := newMemoryPlayground()
+	svc := services.NewPlaygroundHistoryServiceWithStore(store)
+	ctx := context.Background()
+
+	large := playgroundCall(grantedUserID, "dump", nil)
+	large.Result = map[string]interface{}{"data": strings.Repeat("x", types.MaxPlaygroundResultBytes)}
+	require.NoError(t, svc.Record(ctx, large))
+	require.Len(t, store.executions, 1)
+	assert.Equal(t, true, store.executions[0].Result.(map[string]interface{})["truncated"])
+	assert.NotNil(t, store.executions[0].Arguments, "missing arguments are stored as an empty object")
+
+	disabled := false
+	_, err := svc.UpdateSettings(ctx, grantOrgID, otherUserID, &types.UpdatePlaygroundHistorySettingsRequest{Enabled: &disabled})
+	require.NoError(t, err)
+	require.NoError(t, svc.Record(ctx, playgroundCall(grantedUserID, "search", nil)))
+	assert.Len(t, store.executions, 1, "organizations can turn history off")
+
+	cleared, err := svc.ClearHistory(ctx, grantOrgID, grantedUserID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cleared)
+}
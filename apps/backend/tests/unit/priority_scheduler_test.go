@@ -0,0 +1,204 @@
+package unit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/scheduler"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityClassForPlan(t *testing.T) {
+	assert.Equal(t, types.PriorityClassEnterprise, types.PriorityClassForPlan("enterprise"))
+	assert.Equal(t, types.PriorityClassPro, types.PriorityClassForPlan("pro"))
+	assert.Equal(t, types.PriorityClassFree, types.PriorityClassForPlan("free"))
+	assert.Equal(t, types.PriorityClassFree, types.PriorityClassForPlan(""))
+}
+
+func TestSchedulerAdmitsHigherClassesFirst(t *testing.T) {
+	sched := scheduler.New("test", scheduler.Config{MaxConcurrent: 1, MaxQueue: 10, MaxWait: 5 * time.Second})
+	ctx := context.Background()
+
+	release, err := sched.Acquire(ctx, types.PriorityClassFree)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	enqueue := func(class string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done, err := sched.Acquire(ctx, class)
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			order = append(order, class)
+			mu.Unlock()
+			done()
+		}()
+	}
+
+	// Queue lower classes first so FIFO order would be wrong
+	waitForQueued := func(class string, n int) {
+		require.Eventually(t, func() bool {
+			for _, stat := range sched.Stats() {
+				if stat.Class == class {
+					return stat.Queued == n
+				}
+			}
+			return false
+		}, time.Second, 5*time.Millisecond)
+	}
+	enqueue(types.PriorityClassFree)
+	waitForQueued(types.PriorityClassFree, 1)
+	enqueue(types.PriorityClassPro)
+	waitForQueued(types.PriorityClassPro, 1)
+	enqueue(types.PriorityClassEnterprise)
+	waitForQueued(types.PriorityClassEnterprise, 1)
+
+	release()
+	wg.Wait()
+
+	assert.Equal(t, []string{types.PriorityClassEnterprise, types.PriorityClassPro, types.PriorityClassFree}, order)
+
+	stats := sched.Stats()
+	require.Len(t, stats, 3)
+	for _, stat := range stats {
+		assert.Zero(t, stat.InFlight, stat.Class)
+		assert.Zero(t, stat.Queued, stat.Class)
+	}
+	assert.Equal(t, int64(2), stats[2].Admitted)
+}
+
+func TestSchedulerRejectsWhenQueueFullOrWaitExpires(t *testing.T) {
+	var metrics []*types.Metric
+	var mu sync.Mutex
+	sched := scheduler.New("test", scheduler.Config{MaxConcurrent: 1, MaxQueue: 1, MaxWait: 20 * time.Millisecond})
+	sched.SetMetricEmitter(func(m *types.Metric) {
+		mu.Lock()
+		metrics = append(metrics, m)
+		mu.Unlock()
+	})
+	ctx := context.Background()
+
+	release, err := sched.Acquire(ctx, types.PriorityClassEnterprise)
+	require.NoError(t, err)
+	defer release()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := sched.Acquire(ctx, types.PriorityClassFree)
+		errCh <- err
+	}()
+	require.Eventually(t, func() bool { return sched.Stats()[2].Queued == 1 }, time.Second, time.Millisecond)
+
+	_, err = sched.Acquire(ctx, types.PriorityClassFree)
+	var gwErr *types.Error
+	require.True(t, errors.As(err, &gwErr))
+	assert.Equal(t, http.StatusServiceUnavailable, gwErr.Status)
+
+	err = <-errCh
+	require.True(t, errors.As(err, &gwErr))
+	assert.Equal(t, types.ErrCodeServiceUnavailable, gwErr.Code)
+
+	free := sched.Stats()[2]
+	assert.Equal(t, int64(1), free.Rejected)
+	assert.Equal(t, int64(1), free.TimedOut)
+	assert.Zero(t, free.Queued)
+
+	mu.Lock()
+	defer mu.Unlock()
+	names := make(map[string]string)
+	for _, m := range metrics {
+		names[m.Name] = m.Tags["class"]
+	}
+	assert.Equal(t, types.PriorityClassEnterprise, names["test_admitted_total"])
+	assert.Equal(t, types.PriorityClassFree, names["test_rejected_total"])
+	assert.Equal(t, types.PriorityClassFree, names["test_timed_out_total"])
+}
+
+func TestSchedulerUnlimitedWhenDisabled(t *testing.T) {
+	sched := scheduler.New("test", scheduler.Config{})
+	for i := 0; i < 100; i++ {
+		_, err := sched.Acquire(context.Background(), types.PriorityClassFree)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 100, sched.Stats()[2].InFlight)
+}
+
+func TestBackpressureShedsLowerClassesFirst(t *testing.T) {
+	bp := scheduler.NewBackpressure("test", 10, nil)
+
+	var releases []func()
+	for i := 0; i < 7; i++ {
+		release, err := bp.Admit(types.PriorityClassFree)
+		require.NoError(t, err)
+		releases = append(releases, release)
+	}
+
+	_, err := bp.Admit(types.PriorityClassFree)
+	require.Error(t, err, "free tier is capped at 70% of capacity")
+
+	for i := 0; i < 2; i++ {
+		release, err := bp.Admit(types.PriorityClassPro)
+		require.NoError(t, err)
+		releases = append(releases, release)
+	}
+	_, err = bp.Admit(types.PriorityClassPro)
+	require.Error(t, err, "pro tier is capped at 90% of capacity")
+
+	release, err := bp.Admit(types.PriorityClassEnterprise)
+	require.NoError(t, err)
+	_, err = bp.Admit(types.PriorityClassEnterprise)
+	require.Error(t, err, "enterprise tier may use the full capacity")
+
+	release()
+	release() // releasing twice frees one slot only
+	for _, r := range releases[:3] {
+		r()
+	}
+	_, err = bp.Admit(types.PriorityClassFree)
+	assert.NoError(t, err)
+
+	stats := bp.Stats()
+	assert.Equal(t, int64(8), stats[2].Admitted)
+	assert.Equal(t, int64(1), stats[2].Rejected)
+	assert.Equal(t, 5, stats[2].InFlight)
+	assert.Equal(t, 0, stats[0].InFlight)
+}
+
+func TestPriorityServiceCachesPlanLookups(t *testing.T) {
+	calls := 0
+	svc := services.NewPriorityServiceWithLookup(func(ctx context.Context, orgID string) (string, error) {
+		calls++
+		switch orgID {
+		case flagOrgA:
+			return "enterprise", nil
+		case flagOrgB:
+			return "", sql.ErrNoRows
+		default:
+			return "", errors.New("database unavailable")
+		}
+	})
+	ctx := context.Background()
+
+	assert.Equal(t, types.PriorityClassEnterprise, svc.PriorityClass(ctx, flagOrgA))
+	assert.Equal(t, types.PriorityClassEnterprise, svc.PriorityClass(ctx, flagOrgA))
+	assert.Equal(t, types.PriorityClassFree, svc.PriorityClass(ctx, flagOrgB))
+	assert.Equal(t, types.PriorityClassFree, svc.PriorityClass(ctx, flagOrgB))
+	assert.Equal(t, 2, calls)
+
+	assert.Equal(t, types.PriorityClassFree, svc.PriorityClass(ctx, "unknown-org"))
+	assert.Equal(t, types.PriorityClassFree, svc.PriorityClass(ctx, ""))
+}
@@ -0,0 +1,189 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scrape renders the exporter's metrics
+func scrape(t *testing.T, exporter *observability.PrometheusExporter) string {
+	t.Helper()
+	var out strings.Builder
+	require.NoError(t, exporter.Write(&out))
+	return out.String()
+}
+
+func TestPrometheusExporter_Aggregates(t *testing.T) {
+	exporter := observability.NewPrometheusExporter(&observability.PrometheusConfig{
+		Namespace: "omnimesh",
+		Buckets:   []float64{0.1, 1},
+	})
+
+	calls := &types.Metric{
+		Name:     "mcp_server_calls_total",
+		Type:     types.MetricTypeCounter,
+		Value:    1,
+		ServerID: "srv-1",
+		Tags:     map[string]string{"method": "tools/call", "outcome": "success"},
+	}
+	require.NoError(t, exporter.Emit(calls))
+	require.NoError(t, exporter.Emit(calls))
+	require.NoError(t, exporter.Emit(&types.Metric{
+		Name: "health_checks", Type: types.MetricTypeCounter, Value: 1,
+		Tags: map[string]string{"status": "error"}, ServerID: "srv-\"2\"",
+	}))
+	require.NoError(t, exporter.Emit(&types.Metric{Name: "mcp_server_healthy", Type: types.MetricTypeGauge, Value: 1, ServerID: "srv-1"}))
+	require.NoError(t, exporter.Emit(&types.Metric{Name: "mcp_server_healthy", Type: types.MetricTypeGauge, Value: 0, ServerID: "srv-1"}))
+
+	// Durations are recorded in milliseconds and exported in seconds
+	for _, ms := range []float64{50, 500, 5000} {
+		require.NoError(t, exporter.Emit(&types.Metric{
+			Name: "http_request_duration", Type: types.MetricTypeHistogram, Value: ms,
+			Tags: map[string]string{"route": "/api/namespaces/:id"},
+		}))
+	}
+
+	out := scrape(t, exporter)
+	assert.Contains(t, out, "# HELP omnimesh_mcp_server_calls_total Calls to upstream MCP servers by method and outcome\n# TYPE omnimesh_mcp_server_calls_total counter\n")
+	assert.Contains(t, out, `omnimesh_mcp_server_calls_total{method="tools/call",outcome="success",server_id="srv-1"} 2`)
+	assert.Contains(t, out, `omnimesh_health_checks_total{server_id="srv-\"2\"",status="error"} 1`)
+	assert.Contains(t, out, `omnimesh_mcp_server_healthy{server_id="srv-1"} 0`)
+	assert.Contains(t, out, "# TYPE omnimesh_http_request_duration_seconds histogram\n")
+	assert.Contains(t, out, `omnimesh_http_request_duration_seconds_bucket{route="/api/namespaces/:id",le="0.1"} 1`)
+	assert.Contains(t, out, `omnimesh_http_request_duration_seconds_bucket{route="/api/namespaces/:id",le="1"} 2`)
+	assert.Contains(t, out, `omnimesh_http_request_duration_seconds_bucket{route="/api/namespaces/:id",le="+Inf"} 3`)
+	assert.Contains(t, out, `omnimesh_http_request_duration_seconds_sum{route="/api/namespaces/:id"} 5.55`)
+	assert.Contains(t, out, `omnimesh_http_request_duration_seconds_count{route="/api/namespaces/:id"} 3`)
+
+	// A name keeps the type it was first emitted with
+	assert.Error(t, exporter.Emit(&types.Metric{Name: "mcp_server_calls_total", Type: types.MetricTypeGauge, Value: 1}))
+}
+
+func TestPrometheusExporter_GaugesAndLimits(t *testing.T) {
+	exporter := observability.NewPrometheusExporter(&observability.PrometheusConfig{Namespace: "omnimesh", MaxSeries: 2})
+
+	sessions := map[string]int{"sse": 2, "websocket": 1}
+	exporter.RegisterGauge("transport_sessions", "Active client sessions by transport", func() []observability.Sample {
+		var samples []observability.Sample
+		for transport, count := range sessions {
+			samples = append(samples, observability.Sample{Labels: map[string]string{"transport": transport}, Value: float64(count)})
+		}
+		return samples
+	})
+	out := scrape(t, exporter)
+	assert.Contains(t, out, "omnimesh_transport_sessions{transport=\"sse\"} 2\nomnimesh_transport_sessions{transport=\"websocket\"} 1\n")
+
+	// Gauges are read at every scrape
+	sessions["sse"] = 0
+	assert.Contains(t, scrape(t, exporter), `omnimesh_transport_sessions{transport="sse"} 0`)
+
+	for _, tool := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, exporter.Emit(&types.Metric{
+			Name: "tool_executions_total", Type: types.MetricTypeCounter, Value: 1,
+			Tags: map[string]string{"tool": tool},
+		}))
+	}
+	out = scrape(t, exporter)
+	assert.Contains(t, out, `omnimesh_tool_executions_total{tool="b"} 1`)
+	assert.NotContains(t, out, `tool="c"`)
+	assert.Contains(t, out, `omnimesh_prometheus_series_dropped_total{metric="omnimesh_tool_executions_total"} 2`)
+}
+
+func TestPrometheusExporter_Handler(t *testing.T) {
+	exporter := observability.NewPrometheusExporter(nil)
+	require.NoError(t, exporter.Emit(&types.Metric{Name: "http_requests_total", Type: types.MetricTypeCounter, Value: 1}))
+
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		exporter.Handler("scrape-secret").ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve("").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("Bearer wrong").Code)
+
+	w := serve("Bearer scrape-secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, observability.PrometheusContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "http_requests_total 1")
+}
+
+func TestAdminHandler_GetMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewAdminHandler(nil, nil, nil, nil)
+	router := gin.New()
+	router.GET("/api/admin/metrics", handler.GetMetrics)
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/metrics", nil))
+		return w
+	}
+
+	// Nothing is served while the exporter is disabled
+	assert.Equal(t, http.StatusNotFound, serve().Code)
+
+	exporter := observability.NewPrometheusExporter(nil)
+	require.NoError(t, exporter.Emit(&types.Metric{Name: "http_requests_total", Type: types.MetricTypeCounter, Value: 3}))
+	handler.SetMetricsHandler(exporter.Handler(""))
+
+	w := serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, observability.PrometheusContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, scrape(t, exporter), w.Body.String())
+}
+
+func TestRequestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	exporter := observability.NewPrometheusExporter(&observability.PrometheusConfig{Namespace: "omnimesh"})
+	emit := func(metric *types.Metric) { _ = exporter.Emit(metric) }
+
+	r := gin.New()
+	r.Use(middleware.RequestMetrics(emit))
+	r.GET("/api/namespaces/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/limited", func(c *gin.Context) { c.AbortWithStatus(http.StatusTooManyRequests) })
+
+	for _, path := range []string{"/api/namespaces/ns-1", "/api/namespaces/ns-2", "/limited", "/scanner/probe"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	out := scrape(t, exporter)
+	// Requests are labeled by route template, not by path
+	assert.Contains(t, out, `omnimesh_http_requests_total{method="GET",route="/api/namespaces/:id",status="200"} 2`)
+	assert.Contains(t, out, `omnimesh_http_request_duration_seconds_count{method="GET",route="/api/namespaces/:id",status="200"} 2`)
+	assert.Contains(t, out, `omnimesh_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, out, `omnimesh_rate_limit_rejections_total{route="/limited"} 1`)
+	assert.NotContains(t, out, "ns-1")
+}
+
+func TestPrometheusConfigValidate(t *testing.T) {
+	valid := config.PrometheusConfig{Enabled: true, Path: "/metrics", Buckets: []float64{0.1, 1}}
+	assert.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.Path = "metrics"
+	assert.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.Buckets = []float64{1, 0.5}
+	assert.Error(t, invalid.Validate())
+
+	// Disabled endpoints are not validated
+	assert.NoError(t, (&config.PrometheusConfig{Path: "metrics"}).Validate())
+}
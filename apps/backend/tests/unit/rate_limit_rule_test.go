@@ -0,0 +1,330 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/ratelimit"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRateLimitRules keeps rate limit rules in memory, mirroring
+// RateLimitRuleModel
+type memoryRateLimitRules struct {
+	rules []*types.RateLimitRule
+	lists int
+}
+
+func (m *memoryRateLimitRules) ListRules(orgID string, activeOnly bool) ([]*types.RateLimitRule, error) {
+	m.lists++
+	rules := []*types.RateLimitRule{}
+	for _, rule := range m.rules {
+		if rule.OrganizationID == orgID && (rule.IsActive || !activeOnly) {
+			copied := *rule
+			rules = append(rules, &copied)
+		}
+	}
+	return rules, nil
+}
+
+func (m *memoryRateLimitRules) GetRule(orgID, id string) (*types.RateLimitRule, error) {
+	for _, rule := range m.rules {
+		if rule.OrganizationID == orgID && rule.ID == id {
+			copied := *rule
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryRateLimitRules) CreateRule(rule *types.RateLimitRule) error {
+	rule.ID = "rule-" + strconv.Itoa(len(m.rules)+1)
+	copied := *rule
+	m.rules = append(m.rules, &copied)
+	return nil
+}
+
+func (m *memoryRateLimitRules) UpdateRule(rule *types.RateLimitRule) error {
+	for i, existing := range m.rules {
+		if existing.ID == rule.ID {
+			copied := *rule
+			m.rules[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (m *memoryRateLimitRules) DeleteRule(orgID, id string) (bool, error) {
+	for i, rule := range m.rules {
+		if rule.OrganizationID == orgID && rule.ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// failingLimiter cannot reach its counters
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string, policy ratelimit.Policy) (*ratelimit.Result, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestMemoryLimiterTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.May, 1, 12, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewMemoryLimiter()
+	limiter.SetClock(func() time.Time { return now })
+	policy := ratelimit.Policy{Algorithm: ratelimit.AlgorithmTokenBucket, Limit: 60, Window: time.Minute, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow(ctx, "key", policy)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(2-i), result.Remaining)
+	}
+
+	result, err := limiter.Allow(ctx, "key", policy)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+
+	// Other keys have their own bucket
+	result, err = limiter.Allow(ctx, "other", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// One token is back a second later
+	now = now.Add(time.Second)
+	result, err = limiter.Allow(ctx, "key", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	result, err = limiter.Allow(ctx, "key", policy)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+}
+
+func TestMemoryLimiterSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.May, 1, 12, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewMemoryLimiter()
+	limiter.SetClock(func() time.Time { return now })
+	policy := ratelimit.Policy{Algorithm: ratelimit.AlgorithmSlidingWindow, Limit: 4, Window: time.Minute}
+
+	for i := 0; i < 4; i++ {
+		result, err := limiter.Allow(ctx, "key", policy)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+	result, err := limiter.Allow(ctx, "key", policy)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, int64(4), result.Limit)
+	assert.Positive(t, result.RetryAfter)
+
+	// Halfway through the next window half of the previous one still counts
+	now = now.Add(90 * time.Second)
+	allowed := 0
+	for i := 0; i < 4; i++ {
+		result, err := limiter.Allow(ctx, "key", policy)
+		require.NoError(t, err)
+		if result.Allowed {
+			allowed++
+		}
+	}
+	assert.Equal(t, 2, allowed)
+}
+
+func TestRateLimitServiceCheck(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	newService := func(store *memoryRateLimitRules) *services.RateLimitService {
+		limiter := ratelimit.NewMemoryLimiter()
+		limiter.SetClock(func() time.Time { return now })
+		service := services.NewRateLimitServiceWithStore(store, limiter)
+		service.SetClock(func() time.Time { return now })
+		return service
+	}
+	rule := func(id, ruleType, targetID string, limit, priority int) *types.RateLimitRule {
+		return &types.RateLimitRule{
+			ID: id, OrganizationID: "org-1", Name: id, Type: ruleType, TargetID: targetID,
+			Algorithm: types.RateLimitAlgorithmSlidingWindow, Limit: limit, WindowSeconds: 60,
+			Priority: priority, IsActive: true,
+		}
+	}
+
+	t.Run("counts each user separately", func(t *testing.T) {
+		service := newService(&memoryRateLimitRules{rules: []*types.RateLimitRule{
+			rule("per-user", types.RateLimitTypeUser, "", 1, 0),
+		}})
+
+		buckets, err := service.Check(ctx, &types.RateLimitSubject{OrganizationID: "org-1", UserID: "alice"})
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		assert.False(t, buckets[0].Exceeded)
+
+		buckets, err = service.Check(ctx, &types.RateLimitSubject{OrganizationID: "org-1", UserID: "bob"})
+		require.NoError(t, err)
+		assert.False(t, buckets[0].Exceeded)
+
+		buckets, err = service.Check(ctx, &types.RateLimitSubject{OrganizationID: "org-1", UserID: "alice"})
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		assert.True(t, buckets[0].Exceeded)
+		assert.Equal(t, types.RateLimitTypeUser, buckets[0].Scope)
+		assert.True(t, buckets[0].ResetAt.After(now))
+
+		// Requests without a user are not counted by user rules
+		buckets, err = service.Check(ctx, &types.RateLimitSubject{OrganizationID: "org-1", APIKeyID: "key-1"})
+		require.NoError(t, err)
+		assert.Empty(t, buckets)
+	})
+
+	t.Run("applies targeted rules to their target only", func(t *testing.T) {
+		service := newService(&memoryRateLimitRules{rules: []*types.RateLimitRule{
+			rule("search-endpoint", types.RateLimitTypeEndpoint, "endpoint-1", 5, 0),
+		}})
+
+		buckets, err := service.Check(ctx, &types.RateLimitSubject{OrganizationID: "org-1", EndpointID: "endpoint-2"})
+		require.NoError(t, err)
+		assert.Empty(t, buckets)
+
+		buckets, err = service.Check(ctx, &types.RateLimitSubject{OrganizationID: "org-1", EndpointID: "endpoint-1"})
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		assert.Equal(t, int64(4), buckets[0].Remaining)
+	})
+
+	t.Run("stops at the first exceeded rule by priority", func(t *testing.T) {
+		store := &memoryRateLimitRules{rules: []*types.RateLimitRule{
+			rule("org-wide", types.RateLimitTypeOrganization, "", 1, 10),
+			rule("per-key", types.RateLimitTypeAPIKey, "", 100, 0),
+		}}
+		service := newService(store)
+		subject := &types.RateLimitSubject{OrganizationID: "org-1", APIKeyID: "key-1"}
+
+		buckets, err := service.Check(ctx, subject)
+		require.NoError(t, err)
+		assert.Len(t, buckets, 2)
+
+		buckets, err = service.Check(ctx, subject)
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		assert.Equal(t, "org-wide", buckets[0].Key)
+		assert.True(t, buckets[0].Exceeded)
+
+		// Active rules are cached between requests
+		assert.Equal(t, 1, store.lists)
+	})
+
+	t.Run("lets requests through when counters are unreachable", func(t *testing.T) {
+		service := services.NewRateLimitServiceWithStore(&memoryRateLimitRules{rules: []*types.RateLimitRule{
+			rule("org-wide", types.RateLimitTypeOrganization, "", 1, 0),
+		}}, failingLimiter{})
+
+		buckets, err := service.Check(ctx, &types.RateLimitSubject{OrganizationID: "org-1"})
+		require.NoError(t, err)
+		assert.Empty(t, buckets)
+	})
+}
+
+func TestRateLimitServiceRules(t *testing.T) {
+	ctx := context.Background()
+	service := services.NewRateLimitServiceWithStore(&memoryRateLimitRules{}, ratelimit.NewMemoryLimiter())
+
+	create := func(req types.CreateRateLimitRuleRequest) (*types.RateLimitRule, error) {
+		return service.CreateRule(ctx, "org-1", &req)
+	}
+	valid := types.CreateRateLimitRuleRequest{
+		Name: "per-user", Type: types.RateLimitTypeUser,
+		Algorithm: types.RateLimitAlgorithmTokenBucket, Limit: 10, WindowSeconds: 60, Burst: 20,
+	}
+
+	created, err := create(valid)
+	require.NoError(t, err)
+	assert.True(t, created.IsActive)
+
+	_, err = create(valid)
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+
+	invalid := map[string]func(req *types.CreateRateLimitRuleRequest){
+		"unknown type":      func(req *types.CreateRateLimitRuleRequest) { req.Type = types.RateLimitTypeGlobal },
+		"unknown algorithm": func(req *types.CreateRateLimitRuleRequest) { req.Algorithm = "leaky_bucket" },
+		"targeted organization": func(req *types.CreateRateLimitRuleRequest) {
+			req.Type, req.TargetID = types.RateLimitTypeOrganization, "org-2"
+		},
+		"burst with sliding window": func(req *types.CreateRateLimitRuleRequest) { req.Algorithm = types.RateLimitAlgorithmSlidingWindow },
+		"window over a day":         func(req *types.CreateRateLimitRuleRequest) { req.WindowSeconds = 86401 },
+	}
+	for name, mutate := range invalid {
+		t.Run(name, func(t *testing.T) {
+			req := valid
+			req.Name = "other"
+			mutate(&req)
+			_, err := create(req)
+			assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "%v", err)
+		})
+	}
+
+	inactive := false
+	updated, err := service.UpdateRule(ctx, "org-1", created.ID, &types.UpdateRateLimitRuleRequest{IsActive: &inactive, Limit: 5})
+	require.NoError(t, err)
+	assert.False(t, updated.IsActive)
+	assert.Equal(t, 5, updated.Limit)
+
+	_, err = service.GetRule(ctx, "org-2", created.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	require.NoError(t, service.DeleteRule(ctx, "org-1", created.ID))
+	assert.True(t, types.IsError(service.DeleteRule(ctx, "org-1", created.ID), types.ErrCodeNotFound))
+}
+
+func TestRuleRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &memoryRateLimitRules{rules: []*types.RateLimitRule{{
+		ID: "rule-1", OrganizationID: "org-1", Name: "per-user", Type: types.RateLimitTypeUser,
+		Algorithm: types.RateLimitAlgorithmTokenBucket, Limit: 1, WindowSeconds: 30, IsActive: true,
+	}}}
+	service := services.NewRateLimitServiceWithStore(store, ratelimit.NewMemoryLimiter())
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("organization_id", "org-1")
+		c.Set("user_id", "user-1")
+		c.Next()
+	})
+	router.Use(middleware.RuleRateLimit(service))
+	router.GET("/tools", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tools", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tools", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "RATE_LIMIT_EXCEEDED")
+
+	// A nil checker turns the rules off
+	router = gin.New()
+	router.Use(middleware.RuleRateLimit(nil))
+	router.GET("/tools", func(c *gin.Context) { c.Status(http.StatusOK) })
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tools", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,184 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupReadOnlyRouter(mode *middleware.ReadOnlyMode, auditor handlers.AuditRecorder) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	handler := handlers.NewReadOnlyHandler(mode, auditor)
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"success": true}) }
+
+	api := router.Group("/api")
+	api.Use(func(c *gin.Context) {
+		c.Set("user_id", "admin-1")
+		c.Set("organization_id", "00000000-0000-0000-0000-000000000000")
+		c.Next()
+	})
+	api.Use(mode.Handler())
+	api.GET("/gateway/servers", ok)
+	api.POST("/gateway/servers", ok)
+	api.DELETE("/gateway/servers/:id", ok)
+	api.POST("/gateway/tools/:id/execute", ok)
+	api.GET("/admin/read-only", handler.GetStatus)
+	api.PUT("/admin/read-only", handler.UpdateStatus)
+
+	return router
+}
+
+func performReadOnlyRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestReadOnlyModeRejectsManagementWrites(t *testing.T) {
+	mode := middleware.NewReadOnlyMode(true, "/api/gateway/tools/:id/execute", "/api/admin/read-only")
+	router := setupReadOnlyRouter(mode, nil)
+
+	w := performReadOnlyRequest(router, http.MethodPost, "/api/gateway/servers", `{}`)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var resp types.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Success)
+	assert.Equal(t, types.ErrCodeReadOnlyMode, resp.Error.Code)
+
+	w = performReadOnlyRequest(router, http.MethodDelete, "/api/gateway/servers/abc", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	// Reads and exempt MCP traffic keep working
+	assert.Equal(t, http.StatusOK, performReadOnlyRequest(router, http.MethodGet, "/api/gateway/servers", "").Code)
+	assert.Equal(t, http.StatusOK, performReadOnlyRequest(router, http.MethodPost, "/api/gateway/tools/abc/execute", `{}`).Code)
+}
+
+func TestReadOnlyModeToggleViaAPI(t *testing.T) {
+	mode := middleware.NewReadOnlyMode(false, "/api/admin/read-only")
+	auditor := &recordingAuditor{}
+	router := setupReadOnlyRouter(mode, auditor)
+
+	assert.Equal(t, http.StatusOK, performReadOnlyRequest(router, http.MethodPost, "/api/gateway/servers", `{}`).Code)
+
+	w := performReadOnlyRequest(router, http.MethodPut, "/api/admin/read-only", `{"enabled": true, "reason": "incident 42"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data    types.ReadOnlyStatus `json:"data"`
+		Success bool                 `json:"success"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Data.Enabled)
+	assert.Equal(t, "incident 42", resp.Data.Reason)
+	assert.Equal(t, "admin-1", resp.Data.ChangedBy)
+	assert.Equal(t, types.ReadOnlySourceAPI, resp.Data.Source)
+	assert.NotNil(t, resp.Data.ChangedAt)
+
+	require.Len(t, auditor.events, 1)
+	assert.Equal(t, "read_only_mode", auditor.events[0].Resource)
+	assert.Equal(t, true, auditor.events[0].Details["enabled"])
+
+	w = performReadOnlyRequest(router, http.MethodPost, "/api/gateway/servers", `{}`)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "incident 42")
+
+	// The toggle stays writable so the mode can be turned off again
+	w = performReadOnlyRequest(router, http.MethodPut, "/api/admin/read-only", `{"enabled": false}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, mode.Enabled())
+	assert.Equal(t, http.StatusOK, performReadOnlyRequest(router, http.MethodPost, "/api/gateway/servers", `{}`).Code)
+}
+
+func TestReadOnlyModeRequiresEnabledField(t *testing.T) {
+	mode := middleware.NewReadOnlyMode(false, "/api/admin/read-only")
+	router := setupReadOnlyRouter(mode, nil)
+
+	w := performReadOnlyRequest(router, http.MethodPut, "/api/admin/read-only", `{"reason": "missing flag"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, mode.Enabled())
+	assert.Equal(t, types.ReadOnlySourceConfig, mode.Status().Source)
+}
+
+// memoryReadOnlyStore is a read-only store shared by several instances
+type memoryReadOnlyStore struct {
+	status *types.ReadOnlyStatus
+	err    error
+}
+
+func (s *memoryReadOnlyStore) Get() (*types.ReadOnlyStatus, error) {
+	if s.status == nil {
+		return nil, s.err
+	}
+	status := *s.status
+	return &status, s.err
+}
+
+func (s *memoryReadOnlyStore) Save(status *types.ReadOnlyStatus) error {
+	if s.err != nil {
+		return s.err
+	}
+	saved := *status
+	s.status = &saved
+	return nil
+}
+
+func TestReadOnlyModeSharedAcrossInstances(t *testing.T) {
+	store := &memoryReadOnlyStore{}
+	first := middleware.NewReadOnlyMode(false, "/api/admin/read-only")
+	second := middleware.NewReadOnlyMode(false, "/api/admin/read-only")
+	require.NoError(t, first.SetStore(store))
+	require.NoError(t, second.SetStore(store))
+
+	router := setupReadOnlyRouter(first, nil)
+	w := performReadOnlyRequest(router, http.MethodPut, "/api/admin/read-only", `{"enabled": true, "reason": "incident 42"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// The other instance follows on its next refresh
+	require.NoError(t, second.Refresh())
+	assert.True(t, second.Enabled())
+	assert.Equal(t, "incident 42", second.Status().Reason)
+	assert.Equal(t, http.StatusServiceUnavailable,
+		performReadOnlyRequest(setupReadOnlyRouter(second, nil), http.MethodPost, "/api/gateway/servers", `{}`).Code)
+
+	// A restarted instance comes back read-only despite its configuration
+	restarted := middleware.NewReadOnlyMode(false)
+	require.NoError(t, restarted.SetStore(store))
+	assert.True(t, restarted.Enabled())
+	assert.Equal(t, types.ReadOnlySourceAPI, restarted.Status().Source)
+
+	// Turning it off reaches every instance too
+	_, err := second.Set(false, "", "admin-2")
+	require.NoError(t, err)
+	require.NoError(t, first.Refresh())
+	require.NoError(t, restarted.Refresh())
+	assert.False(t, first.Enabled())
+	assert.False(t, restarted.Enabled())
+}
+
+func TestReadOnlyModeNotChangedWhenSaveFails(t *testing.T) {
+	store := &memoryReadOnlyStore{}
+	mode := middleware.NewReadOnlyMode(false, "/api/admin/read-only")
+	require.NoError(t, mode.SetStore(store))
+	store.err = errors.New("database unavailable")
+
+	w := performReadOnlyRequest(setupReadOnlyRouter(mode, nil), http.MethodPut, "/api/admin/read-only", `{"enabled": true}`)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.False(t, mode.Enabled())
+	assert.Error(t, mode.Refresh())
+}
@@ -0,0 +1,327 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/database/models"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const regionOrgID = "org-1"
+
+// catalogEntry is an entry of the in-memory catalog
+type catalogEntry struct {
+	createdAt time.Time
+	version   types.CatalogVersion
+	name      string
+}
+
+// memoryCatalog keeps a region's catalog in memory with the database's
+// versioning and name rules
+type memoryCatalog struct {
+	entries map[string]*catalogEntry
+	peers   map[string]*types.RegionPeerStatus
+	local   []types.CatalogChange
+	renamed []string
+	pinned  map[string]string
+}
+
+func newMemoryCatalog() *memoryCatalog {
+	return &memoryCatalog{
+		entries: make(map[string]*catalogEntry),
+		peers:   make(map[string]*types.RegionPeerStatus),
+		pinned:  make(map[string]string),
+	}
+}
+
+func (m *memoryCatalog) ListChanges(afterID int64, limit int) ([]types.CatalogChange, error) {
+	var changes []types.CatalogChange
+	for _, change := range m.local {
+		if change.ID > afterID && len(changes) < limit {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func (m *memoryCatalog) Apply(change *types.CatalogChange, region, localRegion string) (bool, error) {
+	incoming := types.CatalogVersion{Version: change.Version, Region: region, Deleted: change.Operation == types.CatalogOperationDelete}
+	existing := m.entries[change.EntityID]
+	if existing != nil && !incoming.Supersedes(existing.version) {
+		return false, nil
+	}
+
+	var row struct {
+		CreatedAt time.Time `json:"created_at"`
+		Name      string    `json:"name"`
+	}
+	if !incoming.Deleted {
+		if err := json.Unmarshal(change.Payload, &row); err != nil {
+			return false, err
+		}
+		for id, entry := range m.entries {
+			if id != change.EntityID && !entry.version.Deleted && entry.name == row.Name {
+				return false, models.ErrCatalogNameTaken
+			}
+		}
+	}
+	m.entries[change.EntityID] = &catalogEntry{createdAt: row.CreatedAt, name: row.Name, version: incoming}
+	return true, nil
+}
+
+func (m *memoryCatalog) FindNameHolder(entityType, orgID, name, excludeID string) (*models.CatalogNameHolder, error) {
+	for id, entry := range m.entries {
+		if id != excludeID && !entry.version.Deleted && entry.name == name {
+			return &models.CatalogNameHolder{ID: id, CreatedAt: entry.createdAt}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryCatalog) Rename(entityType, id, name string) error {
+	m.entries[id].name = name
+	m.renamed = append(m.renamed, id+"="+name)
+	return nil
+}
+
+func (m *memoryCatalog) PeerCursor(region string) (int64, error) {
+	if peer, ok := m.peers[region]; ok {
+		return peer.LastChangeID, nil
+	}
+	return 0, nil
+}
+
+func (m *memoryCatalog) RecordPull(region string, cursor int64, applied, superseded int, pulledAt time.Time, pullErr string) error {
+	peer, ok := m.peers[region]
+	if !ok {
+		peer = &types.RegionPeerStatus{Region: region}
+		m.peers[region] = peer
+	}
+	peer.LastChangeID = cursor
+	peer.AppliedCount += int64(applied)
+	peer.SupersededCount += int64(superseded)
+	peer.LastPulledAt = &pulledAt
+	peer.LastError = pullErr
+	return nil
+}
+
+func (m *memoryCatalog) ListPeers() ([]types.RegionPeerStatus, error) {
+	var peers []types.RegionPeerStatus
+	for _, peer := range m.peers {
+		peers = append(peers, *peer)
+	}
+	return peers, nil
+}
+
+func (m *memoryCatalog) ListEndpointRegions(orgID string) (map[string]string, error) {
+	return m.pinned, nil
+}
+
+// peerFeed serves a peer region's changes in pages
+type peerFeed struct {
+	err     error
+	region  string
+	changes []types.CatalogChange
+	fetches int
+}
+
+func (f *peerFeed) FetchChanges(ctx context.Context, peer services.RegionPeer, after int64, limit int) (*types.CatalogChangeBatch, error) {
+	f.fetches++
+	if f.err != nil {
+		return nil, f.err
+	}
+	batch := &types.CatalogChangeBatch{Region: f.region, NextAfter: after}
+	for _, change := range f.changes {
+		if change.ID <= after {
+			continue
+		}
+		if len(batch.Changes) == limit {
+			batch.HasMore = true
+			break
+		}
+		batch.Changes = append(batch.Changes, change)
+		batch.NextAfter = change.ID
+	}
+	return batch, nil
+}
+
+func serverChange(t *testing.T, id int64, entityID, name string, version, createdAt time.Time) types.CatalogChange {
+	t.Helper()
+	payload, err := json.Marshal(map[string]interface{}{"id": entityID, "name": name, "created_at": createdAt})
+	require.NoError(t, err)
+	return types.CatalogChange{
+		ID:             id,
+		EntityType:     types.CatalogEntityServer,
+		EntityID:       entityID,
+		OrganizationID: regionOrgID,
+		Operation:      types.CatalogOperationUpsert,
+		Payload:        payload,
+		Version:        version,
+	}
+}
+
+func newRegionPair(region string, feed *peerFeed, batchSize int) (*services.RegionService, *memoryCatalog) {
+	catalog := newMemoryCatalog()
+	service := services.NewRegionServiceWithStore(catalog, feed, services.RegionSettings{
+		Region:    region,
+		BaseURL:   "https://" + region + ".gateway.example.com",
+		GlobalURL: "https://gateway.example.com",
+		Token:     "replication-secret",
+		Peers:     []services.RegionPeer{{Name: feed.region, URL: "https://" + feed.region + ".gateway.example.com"}},
+		BatchSize: batchSize,
+		Interval:  15 * time.Second,
+	})
+	service.SetClock(func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) })
+	return service, catalog
+}
+
+func TestCatalogVersionLastWriterWins(t *testing.T) {
+	earlier := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Millisecond)
+
+	assert.True(t, types.CatalogVersion{Version: later, Region: "eu"}.Supersedes(types.CatalogVersion{Version: earlier, Region: "us"}))
+	assert.False(t, types.CatalogVersion{Version: earlier, Region: "us"}.Supersedes(types.CatalogVersion{Version: later, Region: "eu"}))
+
+	// A tie goes to the region whose name sorts last, the same answer in
+	// every region
+	assert.True(t, types.CatalogVersion{Version: earlier, Region: "us"}.Supersedes(types.CatalogVersion{Version: earlier, Region: "eu"}))
+	assert.False(t, types.CatalogVersion{Version: earlier, Region: "eu"}.Supersedes(types.CatalogVersion{Version: earlier, Region: "us"}))
+
+	// An older edit does not revive a deleted entry
+	tombstone := types.CatalogVersion{Version: later, Region: "eu", Deleted: true}
+	assert.False(t, types.CatalogVersion{Version: earlier, Region: "us"}.Supersedes(tombstone))
+}
+
+func TestRegionPullResumesFromCursor(t *testing.T) {
+	base := time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)
+	feed := &peerFeed{region: "us-east"}
+	for i := int64(1); i <= 5; i++ {
+		feed.changes = append(feed.changes, serverChange(t, i, fmt.Sprintf("srv-%d", i), fmt.Sprintf("server-%d", i), base.Add(time.Duration(i)*time.Second), base))
+	}
+	service, catalog := newRegionPair("eu-west", feed, 2)
+
+	applied, err := service.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, applied)
+	assert.Equal(t, 3, feed.fetches)
+	assert.Equal(t, int64(5), catalog.peers["us-east"].LastChangeID)
+
+	// A stale edit of an entry changed since is counted but not applied
+	feed.changes = append(feed.changes, serverChange(t, 6, "srv-1", "renamed", base, base))
+	applied, err = service.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, applied)
+	assert.Equal(t, "server-1", catalog.entries["srv-1"].name)
+	assert.Equal(t, int64(6), catalog.peers["us-east"].LastChangeID)
+	assert.Equal(t, int64(1), catalog.peers["us-east"].SupersededCount)
+
+	status, err := service.Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, status.Peers, 1)
+	assert.Equal(t, "eu-west", status.Region)
+	assert.Equal(t, "https://us-east.gateway.example.com", status.Peers[0].URL)
+	assert.Equal(t, int64(5), status.Peers[0].AppliedCount)
+}
+
+func TestRegionPullRecordsPeerErrors(t *testing.T) {
+	feed := &peerFeed{region: "us-east", err: errors.New("connection refused")}
+	service, catalog := newRegionPair("eu-west", feed, 10)
+
+	_, err := service.Sync(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, catalog.peers["us-east"].LastError, "connection refused")
+
+	// A peer answering for another region is rejected
+	feed.err, feed.region = nil, "ap-south"
+	_, err = service.PullPeer(context.Background(), services.RegionPeer{Name: "us-east", URL: "https://us-east.gateway.example.com"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ap-south")
+}
+
+func TestRegionNameConflictKeepsOlderEntry(t *testing.T) {
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	version := created.Add(time.Hour)
+
+	t.Run("older remote entry takes the name", func(t *testing.T) {
+		feed := &peerFeed{region: "us-east"}
+		feed.changes = []types.CatalogChange{serverChange(t, 1, "srv-remote", "github", version, created)}
+		service, catalog := newRegionPair("eu-west", feed, 10)
+		catalog.entries["srv-local"] = &catalogEntry{name: "github", createdAt: created.Add(time.Minute), version: types.CatalogVersion{Version: version}}
+
+		applied, err := service.Sync(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, applied)
+		assert.Equal(t, "github", catalog.entries["srv-remote"].name)
+		assert.Equal(t, "github-eu-west", catalog.entries["srv-local"].name)
+		assert.Equal(t, []string{"srv-local=github-eu-west"}, catalog.renamed)
+	})
+
+	t.Run("newer remote entry is renamed", func(t *testing.T) {
+		feed := &peerFeed{region: "us-east"}
+		feed.changes = []types.CatalogChange{serverChange(t, 1, "srv-remote", "github", version, created.Add(time.Minute))}
+		service, catalog := newRegionPair("eu-west", feed, 10)
+		catalog.entries["srv-local"] = &catalogEntry{name: "github", createdAt: created, version: types.CatalogVersion{Version: version}}
+
+		_, err := service.Sync(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "github", catalog.entries["srv-local"].name)
+		assert.Equal(t, "github-us-east", catalog.entries["srv-remote"].name)
+		assert.Empty(t, catalog.renamed)
+	})
+}
+
+func TestRegionChangesAndAuthorization(t *testing.T) {
+	base := time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)
+	service, catalog := newRegionPair("eu-west", &peerFeed{region: "us-east"}, 2)
+	for i := int64(1); i <= 3; i++ {
+		catalog.local = append(catalog.local, serverChange(t, i, "srv", "server", base, base))
+	}
+
+	batch, err := service.Changes(context.Background(), 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west", batch.Region)
+	assert.Len(t, batch.Changes, 2)
+	assert.True(t, batch.HasMore)
+	assert.Equal(t, int64(2), batch.NextAfter)
+
+	batch, err = service.Changes(context.Background(), batch.NextAfter, 0)
+	require.NoError(t, err)
+	assert.Len(t, batch.Changes, 1)
+	assert.False(t, batch.HasMore)
+
+	assert.True(t, service.Authorize("replication-secret"))
+	assert.False(t, service.Authorize("wrong"))
+	assert.False(t, service.Authorize(""))
+
+	unconfigured := services.NewRegionServiceWithStore(catalog, &peerFeed{}, services.RegionSettings{Region: "eu-west"})
+	assert.False(t, unconfigured.Authorize(""))
+}
+
+func TestRegionDNSGuidance(t *testing.T) {
+	service, catalog := newRegionPair("eu-west", &peerFeed{region: "us-east"}, 10)
+	catalog.pinned = map[string]string{"shared": "", "eu-only": "eu-west"}
+
+	guidance, err := service.DNSGuidance(context.Background(), regionOrgID)
+	require.NoError(t, err)
+	assert.Equal(t, "gateway.example.com", guidance.GlobalHost)
+	require.Len(t, guidance.Records, 2)
+	assert.Equal(t, "eu-west.gateway.example.com", guidance.Records[0].Value)
+	assert.Equal(t, "latency", guidance.Records[0].Routing)
+	assert.Equal(t, "https://us-east.gateway.example.com/readyz", guidance.Records[1].HealthCheck)
+
+	require.Len(t, guidance.Endpoints, 2)
+	assert.Equal(t, "eu-only", guidance.Endpoints[0].Name)
+	assert.Equal(t, "https://eu-west.gateway.example.com/api/public/endpoints/eu-only/mcp", guidance.Endpoints[0].URL)
+	assert.Equal(t, "https://gateway.example.com/api/public/endpoints/shared/mcp", guidance.Endpoints[1].URL)
+	assert.Equal(t, "https://us-east.gateway.example.com/api/public/endpoints/shared/mcp", guidance.Endpoints[1].RegionalURLs["us-east"])
+	assert.NotEmpty(t, guidance.Notes)
+}
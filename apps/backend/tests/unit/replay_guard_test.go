@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+const replayNonce = "4f1c2a9e7b3d5c60"
+
+// memoryNonces remembers claimed nonces until they expire
+type memoryNonces struct {
+	expiry map[string]time.Time
+	now    time.Time
+}
+
+func (m *memoryNonces) Claim(ctx context.Context, scope, nonce string, expiresAt time.Time) (bool, error) {
+	key := scope + ":" + nonce
+	if expires, ok := m.expiry[key]; ok && expires.After(m.now) {
+		return false, nil
+	}
+	m.expiry[key] = expiresAt
+	return true, nil
+}
+
+func TestReplayGuard(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	nonces := &memoryNonces{expiry: map[string]time.Time{}, now: now}
+	guard := services.NewReplayGuardWithStore(nonces, 2*time.Minute, false)
+	guard.SetClock(func() time.Time { return now })
+	var rejected []string
+	guard.SetMetricEmitter(func(metric *types.Metric) {
+		assert.Equal(t, "inbound_replay_rejected_total", metric.Name)
+		rejected = append(rejected, metric.Tags["reason"])
+	})
+	ctx := context.Background()
+	stamp := strconv.FormatInt(now.Unix(), 10)
+
+	assert.NoError(t, guard.Check(ctx, "feed", "", ""), "unstamped calls pass unless required")
+	require.NoError(t, guard.Check(ctx, "feed", stamp, replayNonce))
+	assert.True(t, types.IsError(guard.Check(ctx, "feed", stamp, replayNonce), types.ErrCodeUnauthorized))
+	assert.NoError(t, guard.Check(ctx, "other", stamp, replayNonce), "nonces are scoped")
+
+	old := strconv.FormatInt(now.Add(-3*time.Minute).Unix(), 10)
+	assert.True(t, types.IsError(guard.Check(ctx, "feed", old, "a-fresh-nonce-value"), types.ErrCodeUnauthorized))
+	assert.True(t, types.IsError(guard.Check(ctx, "feed", stamp, "short"), types.ErrCodeUnauthorized))
+
+	// A nonce is remembered until its timestamp falls outside the skew
+	assert.Equal(t, now.Add(2*time.Minute), nonces.expiry["feed:"+replayNonce])
+
+	strict := services.NewReplayGuardWithStore(nonces, 0, true)
+	strict.SetMetricEmitter(func(metric *types.Metric) { rejected = append(rejected, metric.Tags["reason"]) })
+	assert.True(t, types.IsError(strict.Check(ctx, "feed", "", ""), types.ErrCodeUnauthorized))
+
+	assert.Equal(t, []string{types.ReplayRejectReplayed, types.ReplayRejectClockSkew,
+		types.ReplayRejectMalformed, types.ReplayRejectMissing}, rejected)
+}
+
+func TestThreatFeedReplayProtection(t *testing.T) {
+	svc, _, _, _ := newCompromisedService(nil)
+	guard := services.NewReplayGuardWithStore(&memoryNonces{expiry: map[string]time.Time{}, now: time.Now()}, 0, true)
+	svc.SetReplayGuard(guard)
+	ctx := context.Background()
+
+	body := []byte(`{"feed":"leakwatch"}`)
+	stamp := strconv.FormatInt(time.Now().Unix(), 10)
+	sign := func(message string) string {
+		mac := hmac.New(sha256.New, []byte(threatFeedSecret))
+		mac.Write([]byte(message))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	signature := sign(stamp + "." + replayNonce + "." + string(body))
+
+	require.NoError(t, svc.VerifyFeedRequest(ctx, body, signature, stamp, replayNonce))
+	assert.True(t, types.IsError(svc.VerifyFeedRequest(ctx, body, signature, stamp, replayNonce), types.ErrCodeUnauthorized),
+		"a captured report cannot be resent")
+	assert.True(t, types.IsError(svc.VerifyFeedRequest(ctx, body, signature, stamp, "another-nonce-value"), types.ErrCodeUnauthorized),
+		"the signature covers the nonce")
+	assert.True(t, types.IsError(svc.VerifyFeedRequest(ctx, body, sign(string(body)), "", ""), types.ErrCodeUnauthorized),
+		"unstamped reports are refused when required")
+}
+
+func TestThreatFeedReplayWithoutHeaders(t *testing.T) {
+	ctx := context.Background()
+	body := []byte(`{"feed":"leakwatch"}`)
+	mac := hmac.New(sha256.New, []byte(threatFeedSecret))
+	mac.Write(body)
+	bodyOnly := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	// A body-only report captured in transit is refused however often it is
+	// resent, and before its signature is checked
+	var cfg config.ReplayProtectionConfig
+	svc, _, _, _ := newCompromisedService(nil)
+	var rejected []string
+	guard := services.NewReplayGuardWithStore(&memoryNonces{expiry: map[string]time.Time{}, now: time.Now()}, cfg.ClockSkew, !cfg.AllowUnstamped)
+	guard.SetMetricEmitter(func(metric *types.Metric) { rejected = append(rejected, metric.Tags["reason"]) })
+	svc.SetReplayGuard(guard)
+	for i := 0; i < 2; i++ {
+		assert.True(t, types.IsError(svc.VerifyFeedRequest(ctx, body, bodyOnly, "", ""), types.ErrCodeUnauthorized))
+	}
+	assert.Equal(t, []string{types.ReplayRejectMissing, types.ReplayRejectMissing}, rejected)
+
+	// Operators who opt out accept them unprotected
+	lenient, _, _, _ := newCompromisedService(nil)
+	lenient.SetReplayGuard(services.NewReplayGuardWithStore(&memoryNonces{expiry: map[string]time.Time{}, now: time.Now()}, 0, false))
+	assert.NoError(t, lenient.VerifyFeedRequest(ctx, body, bodyOnly, "", ""))
+	assert.True(t, types.IsError(lenient.VerifyFeedRequest(ctx, body, "sha256=deadbeef", "", ""), types.ErrCodeUnauthorized))
+}
@@ -33,11 +33,13 @@ func TestMCPServerRepository_GetByID(t *testing.T) {
 				rows := sqlmock.NewRows([]string{
 					"id", "organization_id", "name", "description", "protocol",
 					"url", "command", "args", "environment", "working_dir", "is_active",
+					"region", "max_retries",
 				}).AddRow(
 					serverID, "org-123", "test-server", "Test server", "http",
 					"http://localhost:8080", nil, "{}", "{}", nil, true,
+					"", 0,
 				)
-				mock.ExpectQuery(`SELECT id, organization_id, name, description, protocol, url, command, args, environment, working_dir, is_active FROM mcp_servers WHERE id = \$1`).
+				mock.ExpectQuery(`SELECT id, organization_id, name, description, protocol, url, command, args, environment, working_dir, is_active, COALESCE\(metadata->>'region', ''\), COALESCE\(max_retries, 0\) FROM mcp_servers WHERE id = \$1`).
 					WithArgs(serverID).
 					WillReturnRows(rows)
 			},
@@ -60,7 +62,7 @@ func TestMCPServerRepository_GetByID(t *testing.T) {
 			name:     "server not found",
 			serverID: "nonexistent-server",
 			mockSetup: func(mock sqlmock.Sqlmock, serverID string) {
-				mock.ExpectQuery(`SELECT id, organization_id, name, description, protocol, url, command, args, environment, working_dir, is_active FROM mcp_servers WHERE id = \$1`).
+				mock.ExpectQuery(`SELECT id, organization_id, name, description, protocol, url, command, args, environment, working_dir, is_active, COALESCE\(metadata->>'region', ''\), COALESCE\(max_retries, 0\) FROM mcp_servers WHERE id = \$1`).
 					WithArgs(serverID).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -72,7 +74,7 @@ func TestMCPServerRepository_GetByID(t *testing.T) {
 			name:     "database error",
 			serverID: "server-123",
 			mockSetup: func(mock sqlmock.Sqlmock, serverID string) {
-				mock.ExpectQuery(`SELECT id, organization_id, name, description, protocol, url, command, args, environment, working_dir, is_active FROM mcp_servers WHERE id = \$1`).
+				mock.ExpectQuery(`SELECT id, organization_id, name, description, protocol, url, command, args, environment, working_dir, is_active, COALESCE\(metadata->>'region', ''\), COALESCE\(max_retries, 0\) FROM mcp_servers WHERE id = \$1`).
 					WithArgs(serverID).
 					WillReturnError(sql.ErrConnDone)
 			},
@@ -132,7 +134,7 @@ func TestNamespaceRepository_Create(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock, ns *types.Namespace) {
 				mock.ExpectQuery(`INSERT INTO namespaces`).
-					WithArgs(ns.ID, ns.OrganizationID, ns.Name, ns.Description, nil, ns.IsActive, sqlmock.AnyArg()).
+					WithArgs(ns.ID, ns.OrganizationID, ns.Name, ns.Description, ns.Region, nil, ns.IsActive, sqlmock.AnyArg()).
 					WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).
 						AddRow(time.Now(), time.Now()))
 			},
@@ -149,7 +151,7 @@ func TestNamespaceRepository_Create(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock, ns *types.Namespace) {
 				mock.ExpectQuery(`INSERT INTO namespaces`).
-					WithArgs(sqlmock.AnyArg(), ns.OrganizationID, ns.Name, ns.Description, nil, ns.IsActive, sqlmock.AnyArg()).
+					WithArgs(sqlmock.AnyArg(), ns.OrganizationID, ns.Name, ns.Description, ns.Region, nil, ns.IsActive, sqlmock.AnyArg()).
 					WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).
 						AddRow(time.Now(), time.Now()))
 			},
@@ -167,7 +169,7 @@ func TestNamespaceRepository_Create(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock, ns *types.Namespace) {
 				mock.ExpectQuery(`INSERT INTO namespaces`).
-					WithArgs(ns.ID, ns.OrganizationID, ns.Name, ns.Description, nil, ns.IsActive, sqlmock.AnyArg()).
+					WithArgs(ns.ID, ns.OrganizationID, ns.Name, ns.Description, ns.Region, nil, ns.IsActive, sqlmock.AnyArg()).
 					WillReturnError(sql.ErrConnDone)
 			},
 			expectError:   true,
@@ -0,0 +1,163 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/pkg/requestsig"
+)
+
+const signedAgentID = "7f8e9d0c-1b2a-4c3d-8e5f-6a7b8c9d0e1f"
+
+// memorySigningKeys keeps signing keys for the agent signedAgentID of grantOrgID
+type memorySigningKeys struct {
+	keys []*types.SigningKey
+}
+
+func (m *memorySigningKeys) DestinationOrganization(destinationType, destinationID string) (string, error) {
+	if destinationType == types.SigningDestinationA2AAgent && destinationID == signedAgentID {
+		return grantOrgID, nil
+	}
+	return "", nil
+}
+
+func (m *memorySigningKeys) Create(key *types.SigningKey) error {
+	key.ID = "key-" + string(rune('a'+len(m.keys)))
+	key.CreatedAt = time.Now()
+	m.keys = append([]*types.SigningKey{key}, m.keys...)
+	return nil
+}
+
+func (m *memorySigningKeys) List(orgID, destinationType, destinationID string) ([]*types.SigningKey, error) {
+	var keys []*types.SigningKey
+	for _, key := range m.keys {
+		if key.OrganizationID == orgID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *memorySigningKeys) Revoke(orgID, id string) (bool, error) {
+	for _, key := range m.keys {
+		if key.ID == id && key.OrganizationID == orgID && key.RevokedAt == nil {
+			now := time.Now()
+			key.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memorySigningKeys) Active(destinationType, destinationID string) (*types.SigningKey, error) {
+	for _, key := range m.keys {
+		if key.DestinationType == destinationType && key.DestinationID == destinationID && key.RevokedAt == nil {
+			return key, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestRequestSignatureVerification(t *testing.T) {
+	body := []byte(`{"event":"server.down"}`)
+	now := time.Unix(1_700_000_000, 0)
+	hmacKey := &requestsig.Key{ID: "k1", Algorithm: requestsig.AlgHMACSHA256, Secret: []byte("s3cret")}
+	lookup := func(keyID string) (*requestsig.Key, error) {
+		if keyID == hmacKey.ID {
+			return hmacKey, nil
+		}
+		return nil, nil
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/hook", nil)
+	require.NoError(t, hmacKey.Sign(req, body, now))
+	header := req.Header.Get(requestsig.Header)
+	assert.True(t, strings.HasPrefix(header, "kid=k1,alg=hmac-sha256,"))
+
+	keyID, err := requestsig.Verify(header, body, lookup, 0, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "k1", keyID)
+
+	_, err = requestsig.Verify(header, []byte(`{"event":"server.up"}`), lookup, 0, now)
+	assert.ErrorIs(t, err, requestsig.ErrInvalidSignature)
+	_, err = requestsig.Verify(header, body, lookup, 0, now.Add(10*time.Minute))
+	assert.ErrorIs(t, err, requestsig.ErrExpired)
+	_, err = requestsig.Verify(strings.Replace(header, "kid=k1", "kid=k2", 1), body, lookup, 0, now)
+	assert.ErrorIs(t, err, requestsig.ErrUnknownKey)
+	_, err = requestsig.Verify("", body, lookup, 0, now)
+	assert.ErrorIs(t, err, requestsig.ErrMissingSignature)
+	_, err = requestsig.Verify("kid=k1", body, lookup, 0, now)
+	assert.ErrorIs(t, err, requestsig.ErrMalformed)
+}
+
+func TestRequestSigningServiceKeys(t *testing.T) {
+	store := &memorySigningKeys{}
+	svc := services.NewRequestSigningServiceWithStore(store)
+	ctx := context.Background()
+
+	key, err := svc.SigningKey(ctx, types.SigningDestinationA2AAgent, signedAgentID)
+	require.NoError(t, err)
+	assert.Nil(t, key, "destinations without keys go unsigned")
+
+	_, err = svc.CreateKey(ctx, "org-2", otherUserID, &types.CreateSigningKeyRequest{
+		DestinationType: types.SigningDestinationA2AAgent, DestinationID: signedAgentID,
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "keys are only created for the organization's destinations")
+
+	created, err := svc.CreateKey(ctx, grantOrgID, otherUserID, &types.CreateSigningKeyRequest{
+		DestinationType: types.SigningDestinationA2AAgent, DestinationID: signedAgentID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, requestsig.AlgHMACSHA256, created.Key.Algorithm)
+	require.NotEmpty(t, created.Secret)
+
+	// A receiver verifies with the secret it was shown
+	key, err = svc.SigningKey(ctx, types.SigningDestinationA2AAgent, signedAgentID)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/agent", strings.NewReader(`{"q":1}`))
+	require.NoError(t, key.Sign(req, []byte(`{"q":1}`), time.Now()))
+	body, keyID, err := requestsig.VerifyRequest(req, func(id string) (*requestsig.Key, error) {
+		return &requestsig.Key{ID: id, Algorithm: requestsig.AlgHMACSHA256, Secret: []byte(created.Secret)}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, created.Key.ID, keyID)
+	assert.Equal(t, `{"q":1}`, string(body))
+
+	// Rotating to ed25519 signs with the new key, verifiable by its public key
+	rotated, err := svc.CreateKey(ctx, grantOrgID, otherUserID, &types.CreateSigningKeyRequest{
+		DestinationType: types.SigningDestinationA2AAgent, DestinationID: signedAgentID, Algorithm: requestsig.AlgEd25519,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, rotated.Secret)
+	public, err := requestsig.ParsePublicKey(rotated.Key.PublicKey)
+	require.NoError(t, err)
+
+	key, err = svc.SigningKey(ctx, types.SigningDestinationA2AAgent, signedAgentID)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.Key.ID, key.ID)
+	req = httptest.NewRequest(http.MethodPost, "/agent", nil)
+	require.NoError(t, key.Sign(req, []byte("payload"), time.Now()))
+	_, err = requestsig.Verify(req.Header.Get(requestsig.Header), []byte("payload"), func(id string) (*requestsig.Key, error) {
+		return &requestsig.Key{ID: id, Algorithm: requestsig.AlgEd25519, PublicKey: public}, nil
+	}, 0, time.Now())
+	assert.NoError(t, err)
+
+	require.NoError(t, svc.RevokeKey(ctx, grantOrgID, rotated.Key.ID))
+	assert.True(t, types.IsError(svc.RevokeKey(ctx, grantOrgID, rotated.Key.ID), types.ErrCodeNotFound))
+	key, err = svc.SigningKey(ctx, types.SigningDestinationA2AAgent, signedAgentID)
+	require.NoError(t, err)
+	assert.Equal(t, created.Key.ID, key.ID, "revoking falls back to the previous key")
+
+	keys, err := svc.ListKeys(ctx, grantOrgID, "", "")
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/residency"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRegion(t *testing.T) {
+	for _, region := range []string{"", "eu", "eu-west", "us-east-1"} {
+		assert.NoError(t, types.ValidateRegion(region), region)
+	}
+	for _, region := range []string{"EU", "eu_west", "-eu", "eu-", "eu west"} {
+		assert.Error(t, types.ValidateRegion(region), region)
+	}
+}
+
+func TestResidencyPolicyServers(t *testing.T) {
+	policy, err := residency.New("eu-west", false)
+	require.NoError(t, err)
+
+	assert.True(t, policy.Allows("", "us-east"), "unpinned traffic goes anywhere")
+	assert.True(t, policy.Allows("eu-west", "eu-west"))
+	assert.True(t, policy.Allows("eu-west", ""), "unmarked servers are allowed by default")
+	assert.False(t, policy.Allows("eu-west", "us-east"))
+
+	assert.NoError(t, policy.CheckServer("eu-west", "files", "eu-west"))
+	err = policy.CheckServer("eu-west", "files", "us-east")
+	require.Error(t, err)
+	assert.True(t, types.IsError(err, types.ErrCodeResidencyViolation))
+	assert.Equal(t, http.StatusForbidden, types.GetStatusCode(err))
+	assert.Contains(t, err.Error(), "server region is us-east")
+}
+
+func TestResidencyPolicyRequireMarked(t *testing.T) {
+	policy, err := residency.New("", true)
+	require.NoError(t, err)
+
+	assert.True(t, policy.Allows("", ""))
+	assert.False(t, policy.Allows("eu-west", ""))
+	assert.Error(t, policy.CheckServer("eu-west", "files", ""))
+	assert.Error(t, policy.CheckStorage("eu-west"))
+	assert.NoError(t, policy.CheckStorage(""))
+}
+
+func TestResidencyPolicyStorage(t *testing.T) {
+	policy, err := residency.New("us-east", false)
+	require.NoError(t, err)
+	assert.Equal(t, "us-east", policy.StorageRegion())
+
+	assert.NoError(t, policy.CheckStorage("us-east"))
+	err = policy.CheckStorage("eu-west")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "storage region is us-east")
+
+	_, err = residency.New("US East", false)
+	assert.Error(t, err)
+}
+
+func TestResidencyNilPolicy(t *testing.T) {
+	var policy *residency.Policy
+	assert.Empty(t, policy.StorageRegion())
+	assert.NoError(t, policy.CheckStorage("eu-west"))
+	assert.NoError(t, policy.CheckServer("eu-west", "files", ""))
+	assert.Error(t, policy.CheckServer("eu-west", "files", "us-east"))
+}
+
+func TestEndpointResidencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy, err := residency.New("us-east", false)
+	require.NoError(t, err)
+
+	serve := func(namespace *types.Namespace) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/mcp", func(c *gin.Context) {
+			c.Set("namespace", namespace)
+			c.Next()
+		}, middleware.EndpointResidencyMiddleware(policy), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/mcp", nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve(&types.Namespace{Name: "open"}).Code)
+	assert.Equal(t, http.StatusOK, serve(&types.Namespace{Name: "local", Region: "us-east"}).Code)
+
+	w := serve(&types.Namespace{Name: "pinned", Region: "eu-west"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), types.ErrCodeResidencyViolation)
+}
@@ -0,0 +1,195 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	stewardServerID = "a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d"
+	ownerUserID     = "b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e"
+	ownerTeamID     = "c3d4e5f6-a7b8-4c9d-8e1f-2a3b4c5d6e7f"
+	departedUserID  = "d4e5f6a7-b8c9-4d0e-9f2a-3b4c5d6e7f8a"
+)
+
+// memoryResourceOwners keeps ownership in memory, mirroring
+// ResourceOwnerModel
+type memoryResourceOwners struct {
+	owners    map[string]*types.ResourceOwner
+	transfers []*types.TransferOwnershipRequest
+}
+
+func newMemoryResourceOwners() *memoryResourceOwners {
+	return &memoryResourceOwners{owners: make(map[string]*types.ResourceOwner)}
+}
+
+func (m *memoryResourceOwners) ResourceOrganization(resourceType, resourceID string) (string, error) {
+	if resourceType == types.OwnedResourceServer && resourceID == stewardServerID {
+		return grantOrgID, nil
+	}
+	return "", nil
+}
+
+func (m *memoryResourceOwners) OwnerOrganization(ownerType, ownerID string) (string, error) {
+	if (ownerType == types.ResourceOwnerUser && ownerID == ownerUserID) ||
+		(ownerType == types.ResourceOwnerTeam && ownerID == ownerTeamID) {
+		return grantOrgID, nil
+	}
+	return "", nil
+}
+
+func (m *memoryResourceOwners) Get(resourceType, resourceID string) (*types.ResourceOwner, error) {
+	return m.owners[resourceType+"/"+resourceID], nil
+}
+
+func (m *memoryResourceOwners) List(orgID string, filter *types.ResourceOwnerFilter) ([]*types.ResourceOwner, error) {
+	owners := []*types.ResourceOwner{}
+	for _, owner := range m.owners {
+		if owner.OrganizationID == orgID && (!filter.OrphanedOnly || owner.Orphaned) {
+			owners = append(owners, owner)
+		}
+	}
+	return owners, nil
+}
+
+func (m *memoryResourceOwners) Upsert(owner *types.ResourceOwner) error {
+	m.owners[owner.ResourceType+"/"+owner.ResourceID] = owner
+	return nil
+}
+
+func (m *memoryResourceOwners) Delete(resourceType, resourceID string) (bool, error) {
+	key := resourceType + "/" + resourceID
+	_, ok := m.owners[key]
+	delete(m.owners, key)
+	return ok, nil
+}
+
+func (m *memoryResourceOwners) Transfer(orgID string, req *types.TransferOwnershipRequest, assignedBy string) (int64, error) {
+	m.transfers = append(m.transfers, req)
+	var moved int64
+	for _, owner := range m.owners {
+		if owner.OrganizationID == orgID && owner.OwnerType == req.FromType && owner.OwnerID == req.FromID {
+			owner.OwnerType, owner.OwnerID, owner.AssignedBy = req.ToType, req.ToID, assignedBy
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+func TestResourceOwnerAssignment(t *testing.T) {
+	svc := services.NewResourceOwnerServiceWithStore(newMemoryResourceOwners())
+	ctx := context.Background()
+	assign := func(resourceType, resourceID, ownerType, ownerID string) error {
+		_, err := svc.SetOwner(ctx, grantOrgID, resourceType, resourceID, "admin-1", &types.SetResourceOwnerRequest{
+			OwnerType: ownerType, OwnerID: ownerID,
+		})
+		return err
+	}
+
+	_, err := svc.GetOwner(ctx, grantOrgID, types.OwnedResourceServer, stewardServerID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "unowned resources report no owner")
+
+	assert.True(t, types.IsError(assign("prompt", stewardServerID, types.ResourceOwnerUser, ownerUserID), types.ErrCodeValidationFailed))
+	assert.True(t, types.IsError(assign(types.OwnedResourceTool, stewardServerID, types.ResourceOwnerUser, ownerUserID), types.ErrCodeNotFound))
+	assert.True(t, types.IsError(assign(types.OwnedResourceServer, stewardServerID, types.ResourceOwnerUser, departedUserID), types.ErrCodeValidationFailed),
+		"inactive or foreign users cannot own resources")
+	assert.True(t, types.IsError(assign(types.OwnedResourceServer, stewardServerID, types.ResourceOwnerTeam, ownerUserID), types.ErrCodeValidationFailed))
+
+	require.NoError(t, assign(types.OwnedResourceServer, stewardServerID, types.ResourceOwnerTeam, ownerTeamID))
+	owner, err := svc.GetOwner(ctx, grantOrgID, types.OwnedResourceServer, stewardServerID)
+	require.NoError(t, err)
+	assert.Equal(t, ownerTeamID, owner.OwnerID)
+	assert.Equal(t, "admin-1", owner.AssignedBy)
+
+	_, err = svc.GetOwner(ctx, "org-2", types.OwnedResourceServer, stewardServerID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "other organizations cannot see the owner")
+
+	require.NoError(t, svc.RemoveOwner(ctx, grantOrgID, types.OwnedResourceServer, stewardServerID))
+	assert.True(t, types.IsError(svc.RemoveOwner(ctx, grantOrgID, types.OwnedResourceServer, stewardServerID), types.ErrCodeNotFound))
+}
+
+func TestResourceOwnershipTransfer(t *testing.T) {
+	store := newMemoryResourceOwners()
+	store.owners["server/"+stewardServerID] = &types.ResourceOwner{
+		ResourceType: types.OwnedResourceServer, ResourceID: stewardServerID, OrganizationID: grantOrgID,
+		OwnerType: types.ResourceOwnerUser, OwnerID: departedUserID, Orphaned: true, OrphanReason: types.OrphanOwnerDeactivated,
+	}
+	svc := services.NewResourceOwnerServiceWithStore(store)
+	ctx := context.Background()
+
+	orphaned, err := svc.ListOwners(ctx, grantOrgID, &types.ResourceOwnerFilter{OrphanedOnly: true})
+	require.NoError(t, err)
+	require.Len(t, orphaned, 1)
+	assert.Equal(t, types.OrphanOwnerDeactivated, orphaned[0].OrphanReason)
+
+	_, err = svc.Transfer(ctx, grantOrgID, "admin-1", &types.TransferOwnershipRequest{
+		FromType: types.ResourceOwnerUser, FromID: departedUserID, ToType: types.ResourceOwnerUser, ToID: departedUserID,
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	_, err = svc.Transfer(ctx, grantOrgID, "admin-1", &types.TransferOwnershipRequest{
+		FromType: types.ResourceOwnerUser, FromID: departedUserID, ToType: types.ResourceOwnerUser, ToID: departedUserID[:35] + "b",
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "the new owner must exist")
+
+	result, err := svc.Transfer(ctx, grantOrgID, "admin-1", &types.TransferOwnershipRequest{
+		FromType: types.ResourceOwnerUser, FromID: departedUserID, ToType: types.ResourceOwnerTeam, ToID: ownerTeamID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Transferred, "resources of departed owners can be reassigned")
+	assert.Equal(t, ownerTeamID, store.owners["server/"+stewardServerID].OwnerID)
+}
+
+func TestResourceOwnerHandlerValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newMemoryResourceOwners()
+	store.owners["server/"+stewardServerID] = &types.ResourceOwner{
+		ResourceType: types.OwnedResourceServer, ResourceID: stewardServerID, OrganizationID: grantOrgID,
+		OwnerType: types.ResourceOwnerTeam, OwnerID: ownerTeamID,
+	}
+	handler := handlers.NewResourceOwnerHandler(services.NewResourceOwnerServiceWithStore(store))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("organization_id", grantOrgID)
+		c.Set("user_id", "admin-1")
+		c.Next()
+	})
+	router.GET("/ownership", handler.ListOwners)
+	router.GET("/ownership/orphaned", handler.ListOrphaned)
+	router.POST("/ownership/transfer", handler.Transfer)
+
+	get := func(path string) (int, []types.ResourceOwner) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var body struct {
+			Data []types.ResourceOwner `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body.Data
+	}
+
+	code, owners := get("/ownership")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, owners, 1)
+	code, owners = get("/ownership/orphaned")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, owners)
+	code, _ = get("/ownership?resource_type=prompt")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	w := postJSONBody(router, "/ownership/transfer",
+		`{"from_type": "user", "from_id": "`+departedUserID+`", "to_type": "group", "to_id": "`+ownerTeamID+`"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, store.transfers)
+}
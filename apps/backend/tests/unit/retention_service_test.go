@@ -0,0 +1,534 @@
+package unit
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRetention keeps the row times of each organization and table in
+// memory, mirroring RetentionModel
+type memoryRetention struct {
+	rows       map[string]map[string][]retentionRow
+	held       map[string]bool
+	failing    map[string]bool
+	policies   map[string]*types.LogRetentionPolicy
+	retentions []*types.OrganizationRetention
+	exports    []*types.RetentionExport
+	deletes    []int
+	nextID     int
+}
+
+type retentionRow struct {
+	at time.Time
+	id int
+}
+
+func newMemoryRetention() *memoryRetention {
+	return &memoryRetention{
+		rows:     make(map[string]map[string][]retentionRow),
+		held:     make(map[string]bool),
+		failing:  make(map[string]bool),
+		policies: make(map[string]*types.LogRetentionPolicy),
+	}
+}
+
+func (m *memoryRetention) add(orgID, table string, at time.Time, n int) {
+	if m.rows[orgID] == nil {
+		m.rows[orgID] = make(map[string][]retentionRow)
+	}
+	for i := 0; i < n; i++ {
+		m.nextID++
+		m.rows[orgID][table] = append(m.rows[orgID][table], retentionRow{at: at, id: m.nextID})
+	}
+}
+
+func (m *memoryRetention) ListRetentions() ([]*types.OrganizationRetention, error) {
+	return m.retentions, nil
+}
+
+func (m *memoryRetention) HasActiveLegalHold(orgID string) (bool, error) {
+	return m.held[orgID], nil
+}
+
+func (m *memoryRetention) CountExpired(table, orgID string, cutoff time.Time) (int64, error) {
+	var n int64
+	for _, row := range m.rows[orgID][table] {
+		if row.at.Before(cutoff) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memoryRetention) PurgeExpired(table, orgID string, cutoff time.Time, limit int) (int64, error) {
+	if m.failing[table] {
+		return 0, errors.New("deadlock detected")
+	}
+	rows := m.rows[orgID][table]
+	sort.Slice(rows, func(i, j int) bool { return rows[i].at.Before(rows[j].at) })
+	n := 0
+	for n < len(rows) && n < limit && rows[n].at.Before(cutoff) {
+		n++
+	}
+	m.rows[orgID][table] = rows[n:]
+	m.deletes = append(m.deletes, n)
+	return int64(n), nil
+}
+
+func (m *memoryRetention) GetPolicy(orgID string) (*types.LogRetentionPolicy, error) {
+	if policy, ok := m.policies[orgID]; ok {
+		copied := *policy
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryRetention) UpsertPolicy(policy *types.LogRetentionPolicy) error {
+	updatedAt := retentionNow
+	policy.UpdatedAt = &updatedAt
+	copied := *policy
+	m.policies[policy.OrganizationID] = &copied
+	return nil
+}
+
+func (m *memoryRetention) ExportExpired(table, orgID string, cutoff time.Time, limit int, fn func(row *types.ArchiveRow) error) error {
+	if m.failing[table] {
+		return errors.New("deadlock detected")
+	}
+	rows := m.rows[orgID][table]
+	sort.Slice(rows, func(i, j int) bool { return rows[i].at.Before(rows[j].at) })
+	for i := 0; i < len(rows) && i < limit && rows[i].at.Before(cutoff); i++ {
+		data := fmt.Sprintf(`{"at":%q}`, rows[i].at.Format(time.RFC3339))
+		if err := fn(&types.ArchiveRow{ID: strconv.Itoa(rows[i].id), Data: []byte(data)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryRetention) DeleteExpired(table, orgID string, cutoff time.Time, ids []string) (int64, error) {
+	deleted := make(map[string]bool)
+	for _, id := range ids {
+		deleted[id] = true
+	}
+	var kept []retentionRow
+	for _, row := range m.rows[orgID][table] {
+		if !deleted[strconv.Itoa(row.id)] || !row.at.Before(cutoff) {
+			kept = append(kept, row)
+		}
+	}
+	n := len(m.rows[orgID][table]) - len(kept)
+	m.rows[orgID][table] = kept
+	m.deletes = append(m.deletes, n)
+	return int64(n), nil
+}
+
+func (m *memoryRetention) CountExpiringByDay(table, orgID string, from, cutoff time.Time) ([]*types.RetentionPurgeDay, error) {
+	counts := make(map[time.Time]int64)
+	for _, row := range m.rows[orgID][table] {
+		if !row.at.Before(from) && row.at.Before(cutoff) {
+			counts[row.at.UTC().Truncate(24*time.Hour)]++
+		}
+	}
+	days := []*types.RetentionPurgeDay{}
+	for day, rows := range counts {
+		days = append(days, &types.RetentionPurgeDay{Date: day, Rows: rows})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days, nil
+}
+
+func (m *memoryRetention) RecordExport(export *types.RetentionExport) error {
+	export.ID = fmt.Sprintf("export-%d", len(m.exports)+1)
+	export.CreatedAt = retentionNow
+	m.exports = append(m.exports, export)
+	return nil
+}
+
+func (m *memoryRetention) ListExports(orgID string, limit int) ([]*types.RetentionExport, error) {
+	var exports []*types.RetentionExport
+	for i := len(m.exports) - 1; i >= 0 && len(exports) < limit; i-- {
+		if m.exports[i].OrganizationID == orgID {
+			exports = append(exports, m.exports[i])
+		}
+	}
+	return exports, nil
+}
+
+var retentionNow = time.Date(2026, time.May, 10, 12, 0, 0, 0, time.UTC)
+
+func newRetentionTestService(store *memoryRetention, batchSize int, dryRun bool) *services.RetentionService {
+	service := services.NewRetentionServiceWithStore(store, batchSize, dryRun)
+	service.SetClock(func() time.Time { return retentionNow })
+	return service
+}
+
+func TestRetentionServicePurgesPerOrganizationRetention(t *testing.T) {
+	store := newMemoryRetention()
+	store.retentions = []*types.OrganizationRetention{
+		{OrganizationID: "org-7", LogRetentionDays: 7},
+		{OrganizationID: "org-30", LogRetentionDays: 30},
+		{OrganizationID: "org-none", LogRetentionDays: 0},
+	}
+	tenDaysAgo := retentionNow.AddDate(0, 0, -10)
+	for _, orgID := range []string{"org-7", "org-30", "org-none"} {
+		store.add(orgID, types.RetentionTableExecutionLogs, tenDaysAgo, 25)
+		store.add(orgID, types.RetentionTableExecutionLogs, retentionNow.Add(-time.Hour), 3)
+		store.add(orgID, types.RetentionTableAuditLogs, tenDaysAgo, 4)
+		store.add(orgID, types.RetentionTableHealthChecks, tenDaysAgo, 10)
+	}
+
+	run, err := newRetentionTestService(store, 10, false).Enforce(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, run.DryRun)
+	assert.Equal(t, int64(39), run.Rows)
+	require.Len(t, run.Purges, 3)
+	assert.Equal(t, "org-7", run.Purges[0].OrganizationID)
+	assert.Equal(t, types.RetentionTableExecutionLogs, run.Purges[0].Table)
+	assert.Equal(t, int64(25), run.Purges[0].Rows)
+	assert.Equal(t, retentionNow.AddDate(0, 0, -7), run.Purges[0].Cutoff)
+	assert.Equal(t, types.RetentionTableAuditLogs, run.Purges[1].Table)
+	assert.Equal(t, types.RetentionTableHealthChecks, run.Purges[2].Table)
+
+	// Deleted in batches, stopping at the first short one; org-30 has
+	// nothing past its retention yet
+	assert.Equal(t, []int{10, 10, 5, 4, 10, 0, 0, 0, 0}, store.deletes)
+
+	assert.Len(t, store.rows["org-7"][types.RetentionTableExecutionLogs], 3, "recent rows are kept")
+	assert.Len(t, store.rows["org-30"][types.RetentionTableExecutionLogs], 28, "rows within a longer retention are kept")
+	assert.Len(t, store.rows["org-none"][types.RetentionTableAuditLogs], 4, "organizations without retention are kept")
+}
+
+func TestRetentionServiceSkipsLegalHolds(t *testing.T) {
+	store := newMemoryRetention()
+	store.retentions = []*types.OrganizationRetention{
+		{OrganizationID: "held", LogRetentionDays: 1},
+		{OrganizationID: "free", LogRetentionDays: 1},
+	}
+	store.held["held"] = true
+	store.add("held", types.RetentionTableAuditLogs, retentionNow.AddDate(0, 0, -5), 2)
+	store.add("free", types.RetentionTableAuditLogs, retentionNow.AddDate(0, 0, -5), 2)
+
+	run, err := newRetentionTestService(store, 0, false).Enforce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"held"}, run.Held)
+	assert.Equal(t, int64(2), run.Rows)
+	assert.Len(t, store.rows["held"][types.RetentionTableAuditLogs], 2)
+	assert.Empty(t, store.rows["free"][types.RetentionTableAuditLogs])
+}
+
+func TestRetentionServiceDryRunOnlyCounts(t *testing.T) {
+	store := newMemoryRetention()
+	store.retentions = []*types.OrganizationRetention{{OrganizationID: "org-1", LogRetentionDays: 7}}
+	store.add("org-1", types.RetentionTableExecutionLogs, retentionNow.AddDate(0, 0, -8), 12)
+	store.add("org-1", types.RetentionTableHealthChecks, retentionNow.AddDate(0, 0, -8), 3)
+
+	var metrics []*types.Metric
+	service := newRetentionTestService(store, 5, true)
+	service.SetMetricEmitter(func(metric *types.Metric) { metrics = append(metrics, metric) })
+
+	run, err := service.Enforce(context.Background())
+	require.NoError(t, err)
+	assert.True(t, run.DryRun)
+	assert.Equal(t, int64(15), run.Rows)
+	require.Len(t, run.Purges, 2)
+	assert.Len(t, store.rows["org-1"][types.RetentionTableExecutionLogs], 12, "a dry run deletes nothing")
+	assert.Empty(t, store.deletes)
+
+	require.Len(t, metrics, len(types.RetentionTables))
+	for _, metric := range metrics {
+		assert.Equal(t, "retention_rows_expired", metric.Name)
+		assert.Equal(t, types.MetricTypeGauge, metric.Type)
+	}
+	assert.Equal(t, float64(12), metrics[0].Value)
+	assert.Equal(t, types.RetentionTableExecutionLogs, metrics[0].Tags["table"])
+	assert.Equal(t, float64(0), metrics[1].Value)
+}
+
+func TestRetentionServiceContinuesPastFailures(t *testing.T) {
+	store := newMemoryRetention()
+	store.retentions = []*types.OrganizationRetention{{OrganizationID: "org-1", LogRetentionDays: 1}}
+	store.failing[types.RetentionTableAuditLogs] = true
+	for _, table := range types.RetentionTables {
+		store.add("org-1", table, retentionNow.AddDate(0, 0, -2), 2)
+	}
+
+	var metrics []*types.Metric
+	service := newRetentionTestService(store, 0, false)
+	service.SetMetricEmitter(func(metric *types.Metric) { metrics = append(metrics, metric) })
+
+	run, err := service.Enforce(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to purge audit_logs of org-1")
+	require.NotNil(t, run)
+	assert.Equal(t, int64(4), run.Rows)
+	assert.Len(t, store.rows["org-1"][types.RetentionTableAuditLogs], 2)
+	assert.Empty(t, store.rows["org-1"][types.RetentionTableHealthChecks])
+
+	require.Len(t, metrics, 3)
+	assert.Equal(t, "retention_rows_purged_total", metrics[0].Name)
+	assert.Equal(t, types.MetricTypeCounter, metrics[0].Type)
+	assert.Equal(t, float64(2), metrics[2].Value)
+}
+
+func TestRetentionServiceStopsWhenCancelled(t *testing.T) {
+	store := newMemoryRetention()
+	store.retentions = []*types.OrganizationRetention{{OrganizationID: "org-1", LogRetentionDays: 1}}
+	store.add("org-1", types.RetentionTableExecutionLogs, retentionNow.AddDate(0, 0, -2), 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	run, err := newRetentionTestService(store, 0, false).Enforce(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, run.Rows)
+	assert.Len(t, store.rows["org-1"][types.RetentionTableExecutionLogs], 2)
+}
+
+func TestRetentionServiceAppliesRetentionPerLogType(t *testing.T) {
+	store := newMemoryRetention()
+	store.retentions = []*types.OrganizationRetention{{
+		OrganizationID:   "org-1",
+		LogRetentionDays: 30,
+		Days: map[string]int{
+			types.RetentionTableExecutionLogs: 7,
+			types.RetentionTableHealthChecks:  2,
+		},
+	}, {
+		OrganizationID: "org-audit-only",
+		Days:           map[string]int{types.RetentionTableAuditLogs: 5},
+	}}
+	for _, orgID := range []string{"org-1", "org-audit-only"} {
+		for _, table := range types.RetentionTables {
+			store.add(orgID, table, retentionNow.AddDate(0, 0, -10), 2)
+			store.add(orgID, table, retentionNow.AddDate(0, 0, -3), 1)
+		}
+	}
+
+	run, err := newRetentionTestService(store, 0, false).Enforce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, run.Purges, 3)
+	assert.Equal(t, retentionNow.AddDate(0, 0, -7), run.Purges[0].Cutoff)
+	assert.Equal(t, retentionNow.AddDate(0, 0, -2), run.Purges[1].Cutoff)
+	assert.Equal(t, types.RetentionTableAuditLogs, run.Purges[2].Table)
+
+	assert.Len(t, store.rows["org-1"][types.RetentionTableExecutionLogs], 1)
+	assert.Len(t, store.rows["org-1"][types.RetentionTableAuditLogs], 3, "audit logs keep the default retention")
+	assert.Empty(t, store.rows["org-1"][types.RetentionTableHealthChecks])
+	assert.Len(t, store.rows["org-audit-only"][types.RetentionTableExecutionLogs], 3, "without a default the other logs are kept")
+	assert.Len(t, store.rows["org-audit-only"][types.RetentionTableAuditLogs], 1)
+}
+
+// failingExportFiles fails every upload
+type failingExportFiles struct{ memoryExportFiles }
+
+func (f *failingExportFiles) Create(key string) (io.WriteCloser, error) {
+	return nil, errors.New("access denied")
+}
+
+func TestRetentionServiceExportsBeforePurging(t *testing.T) {
+	store := newMemoryRetention()
+	export := &types.RetentionExportConfig{Enabled: true, Bucket: "acme-logs", Region: "eu-west-1"}
+	store.retentions = []*types.OrganizationRetention{
+		{OrganizationID: "org-1", LogRetentionDays: 7, Export: export},
+	}
+	store.add("org-1", types.RetentionTableAuditLogs, retentionNow.AddDate(0, 0, -9), 3)
+	store.add("org-1", types.RetentionTableAuditLogs, retentionNow.AddDate(0, 0, -1), 1)
+
+	files := &memoryExportFiles{}
+	service := newRetentionTestService(store, 2, false)
+	service.SetExportStorage(func(cfg *types.RetentionExportConfig) (services.ExportStorage, error) {
+		assert.Equal(t, "acme-logs", cfg.Bucket)
+		return files, nil
+	})
+
+	run, err := service.Enforce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, run.Purges, 1)
+	require.NotNil(t, run.Purges[0].Export)
+	assert.Equal(t, int64(3), run.Purges[0].Rows)
+	assert.Equal(t, []int{2, 1}, store.deletes, "exported rows are deleted in batches")
+	assert.Len(t, store.rows["org-1"][types.RetentionTableAuditLogs], 1)
+
+	require.Len(t, store.exports, 1)
+	recorded := store.exports[0]
+	assert.Equal(t, "org-1/audit_logs/2026/05/10/120000.jsonl.gz", recorded.StorageKey)
+	assert.Equal(t, int64(3), recorded.RowCount)
+	assert.Len(t, recorded.SHA256, 64)
+	require.Contains(t, files.files, recorded.StorageKey)
+	assert.Len(t, files.files, 1, "tables with nothing expired leave no object")
+
+	gz, err := gzip.NewReader(files.files[recorded.StorageKey])
+	require.NoError(t, err)
+	lines := 0
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		assert.JSONEq(t, `{"at":"2026-05-01T12:00:00Z"}`, scanner.Text())
+		lines++
+	}
+	assert.Equal(t, 3, lines)
+
+	// Rows that cannot be exported are kept
+	store.add("org-1", types.RetentionTableHealthChecks, retentionNow.AddDate(0, 0, -9), 2)
+	service.SetExportStorage(func(cfg *types.RetentionExportConfig) (services.ExportStorage, error) {
+		return &failingExportFiles{}, nil
+	})
+	run, err = service.Enforce(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to purge health_checks of org-1: failed to export")
+	assert.Zero(t, run.Rows)
+	assert.Len(t, store.rows["org-1"][types.RetentionTableHealthChecks], 2)
+}
+
+func TestRetentionServiceUpdatesPolicy(t *testing.T) {
+	store := newMemoryRetention()
+	store.policies["org-1"] = &types.LogRetentionPolicy{OrganizationID: "org-1", DefaultDays: 30}
+	service := newRetentionTestService(store, 0, false)
+	var opened *types.RetentionExportConfig
+	service.SetExportStorage(func(cfg *types.RetentionExportConfig) (services.ExportStorage, error) {
+		opened = cfg
+		return &memoryExportFiles{}, nil
+	})
+
+	seven, zero, negative, enabled := 7, 0, -1, true
+	policy, err := service.UpdatePolicy(context.Background(), "org-1", "user-1", &types.UpdateLogRetentionPolicyRequest{
+		RequestLogDays: &seven,
+		Export: &types.UpdateRetentionExportRequest{
+			Enabled: &enabled, Bucket: stringPtr(" acme-logs "), Region: stringPtr("eu-west-1"),
+			AccessKeyID: stringPtr("AKIA"), SecretAccessKey: stringPtr("s3cr3t"),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 7, *policy.RequestLogDays)
+	assert.Nil(t, policy.AuditLogDays)
+	assert.Equal(t, "acme-logs", policy.Export.Bucket)
+	assert.True(t, policy.Export.HasSecret)
+	assert.Equal(t, "user-1", policy.UpdatedBy)
+	require.NotNil(t, opened, "an enabled export is checked")
+
+	data, err := json.Marshal(policy)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t")
+
+	// 0 returns a log type to the default
+	policy, err = service.UpdatePolicy(context.Background(), "org-1", "user-1", &types.UpdateLogRetentionPolicyRequest{RequestLogDays: &zero})
+	require.NoError(t, err)
+	assert.Nil(t, policy.RequestLogDays)
+	assert.Equal(t, "acme-logs", store.policies["org-1"].Export.Bucket)
+
+	_, err = service.UpdatePolicy(context.Background(), "org-1", "", &types.UpdateLogRetentionPolicyRequest{AuditLogDays: &negative})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	_, err = service.UpdatePolicy(context.Background(), "org-1", "", &types.UpdateLogRetentionPolicyRequest{
+		Export: &types.UpdateRetentionExportRequest{SecretAccessKey: stringPtr("")},
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	_, err = service.GetPolicy(context.Background(), "missing")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+}
+
+func TestRetentionServiceRefusesInternalExportEndpoints(t *testing.T) {
+	store := newMemoryRetention()
+	store.policies["org-1"] = &types.LogRetentionPolicy{OrganizationID: "org-1", DefaultDays: 30}
+	service := newRetentionTestService(store, 0, false)
+	opened := 0
+	service.SetExportStorage(func(cfg *types.RetentionExportConfig) (services.ExportStorage, error) {
+		opened++
+		return nil, errors.New("dial tcp 10.0.0.7:9000: connect: connection refused")
+	})
+	update := func(endpoint string) error {
+		enabled := true
+		_, err := service.UpdatePolicy(context.Background(), "org-1", "user-1", &types.UpdateLogRetentionPolicyRequest{
+			Export: &types.UpdateRetentionExportRequest{
+				Enabled: &enabled, Endpoint: stringPtr(endpoint), Bucket: stringPtr("acme-logs"), Region: stringPtr("eu-west-1"),
+				AccessKeyID: stringPtr("AKIA"), SecretAccessKey: stringPtr("s3cr3t"),
+			},
+		})
+		return err
+	}
+
+	for _, endpoint := range []string{"http://169.254.169.254", "http://10.0.0.7:9000", "http://minio.svc:9000", "http://localhost:9000"} {
+		err := update(endpoint)
+		assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), endpoint)
+	}
+	assert.Zero(t, opened, "internal endpoints are refused before they are opened")
+
+	// Failures to open a public endpoint are not echoed
+	err := update("https://storage.example.com")
+	require.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	assert.NotContains(t, err.Error(), "10.0.0.7")
+	assert.Equal(t, 1, opened)
+
+	// Allow-listed hosts may be used, and offline gateways only reach those
+	policy, err := airgap.New(true, nil, []string{"minio.svc"})
+	require.NoError(t, err)
+	service.SetEgressPolicy(policy)
+	service.SetExportStorage(func(cfg *types.RetentionExportConfig) (services.ExportStorage, error) {
+		return &memoryExportFiles{}, nil
+	})
+	assert.NoError(t, update("http://minio.svc:9000"))
+	assert.True(t, types.IsError(update(""), types.ErrCodeConnectivityRequired), "AWS S3 is not reachable offline")
+
+	// Stored exports to endpoints that are no longer reachable are not opened
+	store.retentions = []*types.OrganizationRetention{{OrganizationID: "org-1", LogRetentionDays: 7,
+		Export: &types.RetentionExportConfig{Enabled: true, Endpoint: "http://10.0.0.7:9000", Bucket: "acme-logs", Region: "eu-west-1"}}}
+	store.add("org-1", types.RetentionTableAuditLogs, retentionNow.AddDate(0, 0, -9), 1)
+	_, err = service.Enforce(context.Background())
+	require.Error(t, err)
+	assert.Len(t, store.rows["org-1"][types.RetentionTableAuditLogs], 1, "rows are kept")
+}
+
+func TestRetentionServiceListsPurgeWindows(t *testing.T) {
+	store := newMemoryRetention()
+	five := 5
+	store.policies["org-1"] = &types.LogRetentionPolicy{
+		OrganizationID: "org-1",
+		DefaultDays:    30,
+		AuditLogDays:   &five,
+		Export:         types.RetentionExportConfig{Enabled: true},
+	}
+	store.add("org-1", types.RetentionTableAuditLogs, retentionNow.AddDate(0, 0, -6), 2)
+	store.add("org-1", types.RetentionTableAuditLogs, retentionNow.AddDate(0, 0, -4), 3)
+	store.add("org-1", types.RetentionTableAuditLogs, retentionNow.AddDate(0, 0, -2), 1)
+	store.add("org-1", types.RetentionTableAuditLogs, retentionNow, 4)
+	store.held["org-1"] = true
+
+	schedule, err := newRetentionTestService(store, 0, false).ListPurgeWindows(context.Background(), "org-1", 4)
+	require.NoError(t, err)
+	assert.True(t, schedule.LegalHold)
+	assert.Equal(t, 4, schedule.WithinDays)
+	require.Len(t, schedule.Windows, len(types.RetentionTables))
+
+	requests := schedule.Windows[0]
+	assert.Equal(t, 30, requests.Days)
+	assert.Empty(t, requests.Upcoming)
+
+	audit := schedule.Windows[1]
+	assert.Equal(t, types.RetentionTableAuditLogs, audit.Table)
+	assert.Equal(t, 5, audit.Days)
+	assert.True(t, audit.Exported)
+	assert.Equal(t, retentionNow.AddDate(0, 0, -5), *audit.Cutoff)
+	assert.Equal(t, int64(2), audit.Due)
+	assert.Equal(t, []*types.RetentionPurgeDay{
+		{Date: time.Date(2026, time.May, 11, 0, 0, 0, 0, time.UTC), Rows: 3},
+		{Date: time.Date(2026, time.May, 13, 0, 0, 0, 0, time.UTC), Rows: 1},
+	}, audit.Upcoming)
+
+	_, err = newRetentionTestService(store, 0, false).ListPurgeWindows(context.Background(), "org-1", 365)
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+}
@@ -0,0 +1,144 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mcp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/retry"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastRetries creates a retrier with millisecond backoff
+func fastRetries(budgets map[string]retry.Budget) *retry.Retrier {
+	return retry.New(retry.Config{Budgets: budgets, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond})
+}
+
+// failingCall fails its first failures calls with err
+func failingCall(failures int, err error) (func(ctx context.Context) error, *int) {
+	calls := 0
+	return func(ctx context.Context) error {
+		calls++
+		if calls <= failures {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestRetrier_RetriesSafeMethods(t *testing.T) {
+	retrier := fastRetries(nil)
+	fn, calls := failingCall(2, errors.New("connection reset"))
+
+	attempts, err := retrier.Do(context.Background(), retry.Call{ServerID: "s1", Method: "tools/list", MaxRetries: 3}, fn)
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 3, *calls)
+	assert.Equal(t, &types.RetryStats{Calls: 1, Retries: 2, Recovered: 1}, retrier.Stats("s1"))
+
+	// Retries stop at the server's max_retries
+	fn, calls = failingCall(5, errors.New("connection reset"))
+	attempts, err = retrier.Do(context.Background(), retry.Call{ServerID: "s1", Method: "ping", MaxRetries: 1}, fn)
+	assert.EqualError(t, err, "connection reset")
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, int64(1), retrier.Stats("s1").Exhausted)
+
+	assert.Nil(t, retrier.Stats("unknown"))
+}
+
+func TestRetrier_Idempotency(t *testing.T) {
+	retrier := fastRetries(nil)
+
+	fn, calls := failingCall(1, errors.New("request timeout"))
+	_, err := retrier.Do(context.Background(), retry.Call{ServerID: "s1", Method: "tools/call", MaxRetries: 3}, fn)
+	assert.Error(t, err)
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, int64(1), retrier.Stats("s1").NotIdempotent)
+
+	fn, calls = failingCall(1, errors.New("request timeout"))
+	attempts, err := retrier.Do(context.Background(), retry.Call{
+		ServerID: "s1", Method: "tools/call", MaxRetries: 3, IdempotencyKey: "order-42",
+	}, fn)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 2, *calls)
+
+	assert.True(t, retry.IsSafeMethod("resources/read"))
+	assert.False(t, retry.IsSafeMethod("tools/call"))
+}
+
+func TestRetrier_PermanentAndCancelledFailures(t *testing.T) {
+	retrier := fastRetries(nil)
+	rejected := &types.MCPError{Code: types.MCPErrorCodeInvalidParams, Message: "bad arguments"}
+
+	fn, calls := failingCall(5, retry.Permanent(rejected))
+	_, err := retrier.Do(context.Background(), retry.Call{ServerID: "s1", Method: "tools/list", MaxRetries: 3}, fn)
+	assert.Same(t, rejected, err)
+	assert.Equal(t, 1, *calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fn, calls = failingCall(5, errors.New("connection reset"))
+	_, err = retrier.Do(ctx, retry.Call{ServerID: "s1", Method: "tools/list", MaxRetries: 3}, fn)
+	assert.Error(t, err)
+	assert.Equal(t, 1, *calls)
+
+	// A nil retrier calls once
+	var none *retry.Retrier
+	fn, calls = failingCall(5, errors.New("connection reset"))
+	attempts, err := none.Do(context.Background(), retry.Call{Method: "tools/list", MaxRetries: 3}, fn)
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, 1, *calls)
+	assert.Nil(t, none.Stats("s1"))
+}
+
+func TestRetrier_Budgets(t *testing.T) {
+	retrier := fastRetries(map[string]retry.Budget{
+		retry.DefaultBudget: {Ratio: 0, Reserve: 1},
+		"grpc":              {Ratio: 1, Reserve: 10},
+	})
+	call := retry.Call{ServerID: "stdio-server", Protocol: "stdio", Method: "tools/list", MaxRetries: 3}
+
+	// stdio has no budget of its own and shares the default one, which
+	// holds a single retry
+	fn, _ := failingCall(1, errors.New("connection reset"))
+	_, err := retrier.Do(context.Background(), call, fn)
+	require.NoError(t, err)
+
+	fn, calls := failingCall(1, errors.New("connection reset"))
+	_, err = retrier.Do(context.Background(), call, fn)
+	assert.Error(t, err)
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, int64(1), retrier.Stats("stdio-server").BudgetDenied)
+
+	// Other protocols keep their own budget
+	fn, _ = failingCall(2, errors.New("connection reset"))
+	_, err = retrier.Do(context.Background(), retry.Call{ServerID: "grpc-server", Protocol: "grpc", Method: "ping", MaxRetries: 3}, fn)
+	assert.NoError(t, err)
+}
+
+func TestMCPClient_ToolErrors(t *testing.T) {
+	client := mcp.NewMCPClient(&mcp.MockTransport{})
+	err := client.Connect(context.Background(), mcp.TransportConfig{
+		Type: "mock",
+		MockTools: func(ctx context.Context) ([]types.MockTool, error) {
+			return []types.MockTool{{Name: "fail", Response: "quota exceeded", IsError: true}}, nil
+		},
+	}, mcp.ClientInfo{Name: "test", Version: "1.0.0"})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Tool errors are answers of the server, which a retry would repeat
+	_, err = client.CallToolWithMeta(context.Background(), "fail", nil, map[string]interface{}{"idempotencyKey": "k"})
+	var toolErr *mcp.ToolError
+	require.ErrorAs(t, err, &toolErr)
+	assert.Equal(t, "quota exceeded", toolErr.Content[0].Text)
+	assert.ErrorContains(t, err, "tool execution failed")
+}
@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+func sdkFiles(t *testing.T, language string) map[string]string {
+	tools := []types.NamespaceTool{
+		searchTool(),
+		{ToolName: "list-tools", Description: "Shadows the client's own method"},
+	}
+	spec := services.NewOpenAPIGenerator("https://gateway.example.com").GenerateSpec(
+		&types.Endpoint{Name: "Acme-docs", EnableAPIKeyAuth: true, EnableOAuth: language == services.SDKLanguageTypeScript}, &types.Namespace{}, tools)
+
+	files, err := services.NewSDKGenerator().Generate(spec, "Acme-docs", language)
+	require.NoError(t, err)
+	contents := make(map[string]string, len(files))
+	for _, file := range files {
+		contents[file.Path] = string(file.Content)
+	}
+	return contents
+}
+
+func TestPythonSDKGeneration(t *testing.T) {
+	files := sdkFiles(t, services.SDKLanguagePython)
+	require.Contains(t, files, "acme_docs_client/client.py")
+	assert.Contains(t, files, "pyproject.toml")
+
+	client := files["acme_docs_client/client.py"]
+	assert.Contains(t, client, `DEFAULT_BASE_URL = "https://gateway.example.com/api/public/endpoints/Acme-docs/api"`)
+	assert.Contains(t, client, `self._headers["X-API-Key"] = api_key`)
+	assert.NotContains(t, client, "access_token", "endpoints without OAuth take no access token")
+	assert.Contains(t, client, "        query: str,\n        limit: Optional[int] = None,\n        mode: Optional[str] = None,")
+	assert.Contains(t, client, "def list_tools_2(", "tool methods never shadow the client's")
+	assert.Contains(t, files["README.md"], `client.search(query="gateway docs", limit=1, mode="fast", tags=["string"])`)
+	assert.Contains(t, files["README.md"], `- search: calls search`)
+}
+
+func TestTypeScriptSDKGeneration(t *testing.T) {
+	files := sdkFiles(t, services.SDKLanguageTypeScript)
+	require.Contains(t, files, "src/index.ts")
+	assert.Contains(t, files["package.json"], `"name": "acme-docs-client"`)
+
+	index := files["src/index.ts"]
+	assert.Contains(t, index, "export interface SearchInput {\n  query: string;\n  limit?: number;\n  mode?: \"fast\" | \"deep\" | null;\n  tags?: string[];\n}")
+	assert.Contains(t, index, "search(input: SearchInput): Promise<unknown>")
+	assert.Contains(t, index, `this.headers["Authorization"] = "Bearer " + options.accessToken;`)
+	assert.Contains(t, index, "listTools_2(input: Record<string, unknown> = {})")
+	assert.Contains(t, files["README.md"], `"query": "gateway docs"`)
+}
+
+func TestSDKGenerationArchive(t *testing.T) {
+	_, err := services.NewSDKGenerator().Generate(&services.OpenAPISpec{}, "docs", "cobol")
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	var buf bytes.Buffer
+	require.NoError(t, services.WriteSDKArchive(&buf, "docs-python-sdk", []services.SDKFile{{Path: "README.md", Content: []byte("# docs")}}))
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 1)
+	assert.Equal(t, "docs-python-sdk/README.md", archive.File[0].Name)
+}
@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hardenedAuthConfig() *types.AuthConfigurationResponse {
+	defaults := types.GetAuthConfigDefaults()
+	config := &types.AuthConfigurationResponse{
+		Methods:  defaults.Methods,
+		Session:  defaults.Session,
+		Security: defaults.Security,
+	}
+	config.Methods.MFARequired = true
+	config.Security.PasswordRequirements.MinLength = 14
+	config.Security.AccountSecurity.LockoutEnabled = true
+	return config
+}
+
+func findCheck(t *testing.T, report *types.SecurityPostureReport, id string) types.SecurityCheck {
+	t.Helper()
+	for _, check := range report.Checks {
+		if check.ID == id {
+			return check
+		}
+	}
+	t.Fatalf("check %s not found", id)
+	return types.SecurityCheck{}
+}
+
+func TestEvaluateSecurityPosture(t *testing.T) {
+	now := time.Now()
+
+	t.Run("hardened organization", func(t *testing.T) {
+		report := services.EvaluateSecurityPosture("org-1", &services.SecurityPostureFacts{
+			AuthConfig:   hardenedAuthConfig(),
+			MaxAPIKeyAge: services.DefaultMaxAPIKeyAge,
+		}, now)
+
+		assert.Equal(t, 100, report.Score)
+		assert.Equal(t, "A", report.Grade)
+		assert.Zero(t, report.Failed)
+		for _, check := range report.Checks {
+			assert.Empty(t, check.Remediation, check.ID)
+		}
+	})
+
+	t.Run("failures carry findings and remediation", func(t *testing.T) {
+		report := services.EvaluateSecurityPosture("org-1", &services.SecurityPostureFacts{
+			AuthConfig:      hardenedAuthConfig(),
+			StaleAPIKeys:    []string{"ci-key"},
+			PublicEndpoints: []string{"demo"},
+			MaxAPIKeyAge:    services.DefaultMaxAPIKeyAge,
+		}, now)
+
+		check := findCheck(t, report, "public_tool_exposure")
+		assert.Equal(t, types.SecurityCheckFail, check.Status)
+		assert.Equal(t, []string{"demo"}, check.Findings)
+		assert.NotEmpty(t, check.Remediation)
+
+		assert.Equal(t, 2, report.Failed)
+		assert.Less(t, report.Score, 100)
+		assert.Equal(t, "C", report.Grade)
+	})
+
+	t.Run("default credentials fail the grade", func(t *testing.T) {
+		report := services.EvaluateSecurityPosture("org-1", &services.SecurityPostureFacts{
+			AuthConfig:             hardenedAuthConfig(),
+			DefaultCredentialUsers: []string{"admin@admin.com"},
+			MaxAPIKeyAge:           services.DefaultMaxAPIKeyAge,
+		}, now)
+
+		assert.Greater(t, report.Score, 60)
+		assert.Equal(t, "F", report.Grade)
+	})
+
+	t.Run("missing auth config skips its checks", func(t *testing.T) {
+		report := services.EvaluateSecurityPosture("org-1", &services.SecurityPostureFacts{
+			MaxAPIKeyAge: services.DefaultMaxAPIKeyAge,
+		}, now)
+
+		require.Equal(t, 3, report.Skipped)
+		assert.Equal(t, types.SecurityCheckSkip, findCheck(t, report, "mfa_required").Status)
+		assert.Equal(t, 100, report.Score)
+	})
+}
+
+func TestIsPermissivePolicy(t *testing.T) {
+	tests := []struct {
+		policy   *types.Policy
+		name     string
+		expected bool
+	}{
+		{
+			name:     "allow without conditions",
+			policy:   &types.Policy{Actions: map[string]interface{}{"type": "allow"}},
+			expected: true,
+		},
+		{
+			name: "allow with wildcard condition",
+			policy: &types.Policy{
+				Conditions: map[string]interface{}{"tools": []interface{}{"*"}},
+				Actions:    map[string]interface{}{"allow": true},
+			},
+			expected: true,
+		},
+		{
+			name: "allow with specific condition",
+			policy: &types.Policy{
+				Conditions: map[string]interface{}{"tools": []interface{}{"search"}},
+				Actions:    map[string]interface{}{"type": "allow"},
+			},
+			expected: false,
+		},
+		{
+			name:     "deny without conditions",
+			policy:   &types.Policy{Actions: map[string]interface{}{"type": "deny"}},
+			expected: false,
+		},
+		{
+			name:     "allow explicitly disabled",
+			policy:   &types.Policy{Actions: map[string]interface{}{"allow": false}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, services.IsPermissivePolicy(tt.policy))
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/discovery"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type replicaServer struct {
+	orgID    string
+	status   string
+	region   string
+	inactive bool
+}
+
+type memoryServerGroups struct {
+	servers map[string]*replicaServer
+	groups  map[string]*types.ServerGroup
+	members map[string]map[string]int // group -> server -> weight
+	nextID  int
+}
+
+func newMemoryServerGroups() *memoryServerGroups {
+	servers := map[string]*replicaServer{"foreign": {orgID: "org-2", status: types.ServerStatusActive}}
+	for _, id := range []string{"replica-a", "replica-b", "replica-c", "standalone"} {
+		servers[id] = &replicaServer{orgID: "org-1", status: types.ServerStatusActive}
+	}
+	return &memoryServerGroups{
+		servers: servers,
+		groups:  map[string]*types.ServerGroup{},
+		members: map[string]map[string]int{},
+	}
+}
+
+func (m *memoryServerGroups) withMembers(group *types.ServerGroup) *types.ServerGroup {
+	copied := *group
+	copied.Members = []types.ServerGroupMember{}
+	for serverID, weight := range m.members[group.ID] {
+		server := m.servers[serverID]
+		copied.Members = append(copied.Members, types.ServerGroupMember{
+			ServerID:   serverID,
+			ServerName: serverID,
+			Status:     server.status,
+			Region:     server.region,
+			Weight:     weight,
+			IsActive:   !server.inactive,
+		})
+	}
+	sort.Slice(copied.Members, func(i, j int) bool { return copied.Members[i].ServerID < copied.Members[j].ServerID })
+	return &copied
+}
+
+func (m *memoryServerGroups) ServerInOrganization(orgID, serverID string) (bool, error) {
+	server, ok := m.servers[serverID]
+	return ok && server.orgID == orgID, nil
+}
+
+func (m *memoryServerGroups) List(orgID string) ([]*types.ServerGroup, error) {
+	groups := []*types.ServerGroup{}
+	for _, group := range m.groups {
+		if group.OrganizationID == orgID {
+			groups = append(groups, m.withMembers(group))
+		}
+	}
+	return groups, nil
+}
+
+func (m *memoryServerGroups) Get(orgID, id string) (*types.ServerGroup, error) {
+	group, ok := m.groups[id]
+	if !ok || group.OrganizationID != orgID {
+		return nil, nil
+	}
+	return m.withMembers(group), nil
+}
+
+func (m *memoryServerGroups) GetByServer(serverID string) (*types.ServerGroup, error) {
+	for groupID, members := range m.members {
+		if _, ok := members[serverID]; ok {
+			return m.withMembers(m.groups[groupID]), nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryServerGroups) Create(group *types.ServerGroup) error {
+	m.nextID++
+	group.ID = fmt.Sprintf("group-%d", m.nextID)
+	copied := *group
+	m.groups[group.ID] = &copied
+	m.members[group.ID] = map[string]int{}
+	for _, member := range group.Members {
+		m.members[group.ID][member.ServerID] = member.Weight
+	}
+	return nil
+}
+
+func (m *memoryServerGroups) Update(group *types.ServerGroup) error {
+	copied := *group
+	m.groups[group.ID] = &copied
+	return nil
+}
+
+func (m *memoryServerGroups) Delete(orgID, id string) (bool, error) {
+	group, ok := m.groups[id]
+	if !ok || group.OrganizationID != orgID {
+		return false, nil
+	}
+	delete(m.groups, id)
+	delete(m.members, id)
+	return true, nil
+}
+
+func (m *memoryServerGroups) SetMember(groupID, serverID string, weight int) error {
+	m.members[groupID][serverID] = weight
+	return nil
+}
+
+func (m *memoryServerGroups) RemoveMember(groupID, serverID string) (bool, error) {
+	if _, ok := m.members[groupID][serverID]; !ok {
+		return false, nil
+	}
+	delete(m.members[groupID], serverID)
+	return true, nil
+}
+
+// newReplicaGroup creates a group of replica-a, replica-b and replica-c with
+// strategy and weights 3, 1 and 1
+func newReplicaGroup(t *testing.T, strategy string) (*discovery.ServerGroups, *memoryServerGroups, *types.ServerGroup) {
+	t.Helper()
+	store := newMemoryServerGroups()
+	groups := discovery.NewServerGroups(store)
+	group, err := groups.CreateGroup(context.Background(), "org-1", &types.CreateServerGroupRequest{
+		Name:     "search",
+		Strategy: strategy,
+		Members: []types.AddServerGroupMemberRequest{
+			{ServerID: "replica-a", Weight: 3},
+			{ServerID: "replica-b"},
+			{ServerID: "replica-c"},
+		},
+	})
+	require.NoError(t, err)
+	return groups, store, group
+}
+
+// pickReplicas picks n replicas for calls to serverID, releasing each call
+// before the next unless hold is set
+func pickReplicas(t *testing.T, groups *discovery.ServerGroups, serverID string, n int, hold bool) []string {
+	t.Helper()
+	var picked []string
+	for i := 0; i < n; i++ {
+		replica, release, err := groups.PickReplica(context.Background(), serverID, nil)
+		require.NoError(t, err)
+		if !hold {
+			release()
+		}
+		picked = append(picked, replica)
+	}
+	return picked
+}
+
+func TestServerGroups_RoundRobin(t *testing.T) {
+	groups, _, group := newReplicaGroup(t, "")
+	assert.Equal(t, types.LoadBalanceRoundRobin, group.Strategy)
+	require.Len(t, group.Members, 3)
+	assert.Equal(t, 3, group.Members[0].Weight)
+	assert.Equal(t, 1, group.Members[1].Weight)
+
+	assert.Equal(t, []string{"replica-a", "replica-b", "replica-c", "replica-a", "replica-b", "replica-c"},
+		pickReplicas(t, groups, "replica-b", 6, false))
+}
+
+func TestServerGroups_SkipsUnhealthyReplicas(t *testing.T) {
+	groups, store, _ := newReplicaGroup(t, types.LoadBalanceRoundRobin)
+	store.servers["replica-b"].status = types.ServerStatusUnhealthy
+	store.servers["replica-c"].inactive = true
+
+	assert.Equal(t, []string{"replica-a", "replica-a"}, pickReplicas(t, groups, "replica-c", 2, false))
+
+	// Before health checks ran, replicas of unknown health are used
+	store.servers["replica-a"].status = types.ServerStatusInactive
+	assert.Equal(t, []string{"replica-a"}, pickReplicas(t, groups, "replica-a", 1, false))
+
+	store.servers["replica-a"].status = types.ServerStatusMaintenance
+	_, _, err := groups.PickReplica(context.Background(), "replica-a", nil)
+	assert.ErrorContains(t, err, "no healthy replica in server group search")
+}
+
+func TestServerGroups_RegionFilter(t *testing.T) {
+	groups, store, _ := newReplicaGroup(t, types.LoadBalanceRoundRobin)
+	store.servers["replica-b"].region = "eu"
+	euOnly := func(region string) bool { return region == "eu" }
+
+	for i := 0; i < 3; i++ {
+		replica, release, err := groups.PickReplica(context.Background(), "replica-a", euOnly)
+		require.NoError(t, err)
+		release()
+		assert.Equal(t, "replica-b", replica)
+	}
+}
+
+func TestServerGroups_LeastConnections(t *testing.T) {
+	groups, _, group := newReplicaGroup(t, types.LoadBalanceLeastConnections)
+
+	// Calls in flight spread over the replicas
+	held := pickReplicas(t, groups, "replica-a", 3, true)
+	assert.ElementsMatch(t, []string{"replica-a", "replica-b", "replica-c"}, held)
+
+	current, err := groups.GetGroup(context.Background(), "org-1", group.ID)
+	require.NoError(t, err)
+	for _, member := range current.Members {
+		assert.Equal(t, 1, member.ActiveRequests)
+	}
+
+	// The least busy replica gets the next call
+	busy, release, err := groups.PickReplica(context.Background(), "replica-a", nil)
+	require.NoError(t, err)
+	defer release()
+	_, releaseB, err := groups.PickReplica(context.Background(), "replica-a", nil)
+	require.NoError(t, err)
+	releaseB()
+	releaseB() // Releasing twice counts once
+
+	next, releaseNext, err := groups.PickReplica(context.Background(), "replica-a", nil)
+	require.NoError(t, err)
+	defer releaseNext()
+	assert.NotEqual(t, busy, next)
+}
+
+func TestServerGroups_Weighted(t *testing.T) {
+	groups, _, _ := newReplicaGroup(t, types.LoadBalanceWeighted)
+
+	picked := pickReplicas(t, groups, "replica-c", 10, false)
+	counts := map[string]int{}
+	for _, replica := range picked {
+		counts[replica]++
+	}
+	assert.Equal(t, map[string]int{"replica-a": 6, "replica-b": 2, "replica-c": 2}, counts)
+
+	// Smooth weighting does not send a replica's whole share in a row
+	assert.NotEqual(t, []string{"replica-a", "replica-a", "replica-a"}, picked[:3])
+}
+
+func TestServerGroups_ServerWithoutGroup(t *testing.T) {
+	groups, _, _ := newReplicaGroup(t, types.LoadBalanceRoundRobin)
+	assert.Equal(t, []string{"standalone", "standalone"}, pickReplicas(t, groups, "standalone", 2, false))
+}
+
+func TestServerGroups_Membership(t *testing.T) {
+	ctx := context.Background()
+	groups, _, group := newReplicaGroup(t, types.LoadBalanceRoundRobin)
+
+	// A server is a replica of one group only
+	_, err := groups.CreateGroup(ctx, "org-1", &types.CreateServerGroupRequest{
+		Name:    "other",
+		Members: []types.AddServerGroupMemberRequest{{ServerID: "replica-a"}},
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+
+	_, err = groups.CreateGroup(ctx, "org-1", &types.CreateServerGroupRequest{Name: "SEARCH"})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+	_, err = groups.CreateGroup(ctx, "org-1", &types.CreateServerGroupRequest{Name: "bad", Strategy: "random"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	_, err = groups.CreateGroup(ctx, "org-1", &types.CreateServerGroupRequest{
+		Name:    "twice",
+		Members: []types.AddServerGroupMemberRequest{{ServerID: "standalone"}, {ServerID: "standalone"}},
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	// Servers of other organizations cannot join
+	_, err = groups.SetMember(ctx, "org-1", group.ID, &types.AddServerGroupMemberRequest{ServerID: "foreign"})
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	updated, err := groups.SetMember(ctx, "org-1", group.ID, &types.AddServerGroupMemberRequest{ServerID: "standalone", Weight: 2})
+	require.NoError(t, err)
+	assert.Len(t, updated.Members, 4)
+
+	updated, err = groups.SetMember(ctx, "org-1", group.ID, &types.AddServerGroupMemberRequest{ServerID: "replica-a", Weight: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 5, updated.Members[0].Weight)
+
+	updated, err = groups.RemoveMember(ctx, "org-1", group.ID, "standalone")
+	require.NoError(t, err)
+	assert.Len(t, updated.Members, 3)
+	_, err = groups.RemoveMember(ctx, "org-1", group.ID, "standalone")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	// Other organizations do not see the group
+	_, err = groups.GetGroup(ctx, "org-2", group.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	require.NoError(t, groups.DeleteGroup(ctx, "org-1", group.ID))
+	assert.Equal(t, []string{"replica-b"}, pickReplicas(t, groups, "replica-b", 1, false))
+}
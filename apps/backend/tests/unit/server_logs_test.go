@@ -0,0 +1,175 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/serverlogs"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const logServerID = "7d1e2f3a-4b5c-4d6e-8f70-8192a3b4c5d6"
+
+// memoryServerLogStore records persisted lines
+type memoryServerLogStore struct {
+	appended chan []types.ServerLogLine
+	recent   []types.ServerLogLine
+}
+
+func (m *memoryServerLogStore) Append(ctx context.Context, lines []types.ServerLogLine) error {
+	m.appended <- append([]types.ServerLogLine(nil), lines...)
+	return nil
+}
+
+func (m *memoryServerLogStore) Recent(ctx context.Context, serverID, stream string, limit int) ([]types.ServerLogLine, error) {
+	return m.recent, nil
+}
+
+func TestServerLogCaptureRingBuffer(t *testing.T) {
+	capture := serverlogs.New(3, nil)
+	for i := 1; i <= 5; i++ {
+		stream := types.ServerLogStreamStdout
+		if i%2 == 0 {
+			stream = types.ServerLogStreamStderr
+		}
+		capture.Record(logServerID, stream, fmt.Sprintf("line %d", i))
+	}
+
+	lines, err := capture.Tail(context.Background(), logServerID, "", 10)
+	require.NoError(t, err)
+	require.Len(t, lines, 3, "only the buffer size is kept")
+	assert.Equal(t, "line 3", lines[0].Line)
+	assert.Equal(t, "line 5", lines[2].Line)
+
+	lines, err = capture.Tail(context.Background(), logServerID, types.ServerLogStreamStderr, 10)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "line 4", lines[0].Line)
+
+	lines, err = capture.Tail(context.Background(), logServerID, "", 1)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "line 5", lines[0].Line)
+
+	capture.Record(logServerID, types.ServerLogStreamStderr, strings.Repeat("x", 20000))
+	lines, _ = capture.Tail(context.Background(), logServerID, "", 1)
+	assert.Less(t, len(lines[0].Line), 9000, "long lines are truncated")
+}
+
+func TestServerLogCapturePersistsAndFallsBack(t *testing.T) {
+	store := &memoryServerLogStore{
+		appended: make(chan []types.ServerLogLine, 10),
+		recent:   []types.ServerLogLine{{ServerID: logServerID, Stream: types.ServerLogStreamStderr, Line: "from before restart"}},
+	}
+	capture := serverlogs.New(10, store)
+
+	lines, err := capture.Tail(context.Background(), logServerID, "", 10)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "from before restart", lines[0].Line)
+
+	sink := capture.Sink(logServerID)
+	require.NotNil(t, sink)
+	sink(types.ServerLogStreamStderr, "panic: boom")
+
+	select {
+	case batch := <-store.appended:
+		require.Len(t, batch, 1)
+		assert.Equal(t, "panic: boom", batch[0].Line)
+		assert.Equal(t, logServerID, batch[0].ServerID)
+	case <-time.After(3 * time.Second):
+		t.Fatal("captured line was not persisted")
+	}
+
+	assert.Nil(t, capture.Sink("not-a-server-id"))
+	var off *serverlogs.Capture
+	assert.Nil(t, off.Sink(logServerID))
+	off.Record(logServerID, types.ServerLogStreamStdout, "ignored")
+}
+
+func TestServerLogsHandlerTail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	capture := serverlogs.New(100, nil)
+	capture.Record(logServerID, types.ServerLogStreamStdout, "listening")
+	capture.Record(logServerID, types.ServerLogStreamStderr, "warning: deprecated flag")
+
+	router := gin.New()
+	router.GET("/servers/:id/logs", handlers.NewServerLogsHandler(capture).GetLogs)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, get("/servers/nope/logs").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/servers/"+logServerID+"/logs?stream=stdin").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/servers/"+logServerID+"/logs?tail=-1").Code)
+
+	w := get("/servers/" + logServerID + "/logs?stream=stderr")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data []types.ServerLogLine `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "warning: deprecated flag", resp.Data[0].Line)
+}
+
+func TestServerLogsHandlerFollow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	capture := serverlogs.New(100, nil)
+	capture.Record(logServerID, types.ServerLogStreamStderr, "starting")
+
+	router := gin.New()
+	router.GET("/servers/:id/logs", handlers.NewServerLogsHandler(capture).GetLogs)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/servers/"+logServerID+"/logs?follow=true", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				events <- data
+			}
+		}
+	}()
+
+	next := func() types.ServerLogLine {
+		select {
+		case data := <-events:
+			var line types.ServerLogLine
+			require.NoError(t, json.Unmarshal([]byte(data), &line))
+			return line
+		case <-time.After(3 * time.Second):
+			t.Fatal("no log event received")
+			return types.ServerLogLine{}
+		}
+	}
+
+	assert.Equal(t, "starting", next().Line)
+	capture.Record(logServerID, types.ServerLogStreamStderr, "connection refused")
+	assert.Equal(t, "connection refused", next().Line)
+}
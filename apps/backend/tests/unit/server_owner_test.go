@@ -0,0 +1,369 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ownedServerID = "5a6b7c8d-9e0f-4a1b-8c2d-3e4f5a6b7c8d"
+
+// memoryServerOwners keeps owners and alerts in memory, mirroring
+// ServerOwnerModel
+type memoryServerOwners struct {
+	servers map[string]string
+	owners  map[string]*types.ServerOwner
+	alerts  []*types.ServerOwnerAlert
+}
+
+func newMemoryServerOwners() *memoryServerOwners {
+	return &memoryServerOwners{
+		servers: map[string]string{ownedServerID: "org-1"},
+		owners:  map[string]*types.ServerOwner{},
+	}
+}
+
+func (m *memoryServerOwners) GetServerOrganization(serverID string) (string, error) {
+	return m.servers[serverID], nil
+}
+
+func (m *memoryServerOwners) GetOwner(serverID string) (*types.ServerOwner, error) {
+	return m.owners[serverID], nil
+}
+
+func (m *memoryServerOwners) UpsertOwner(owner *types.ServerOwner) error {
+	m.owners[owner.ServerID] = owner
+	return nil
+}
+
+func (m *memoryServerOwners) DeleteOwner(serverID string) (bool, error) {
+	_, ok := m.owners[serverID]
+	delete(m.owners, serverID)
+	return ok, nil
+}
+
+func (m *memoryServerOwners) CreateAlert(alert *types.ServerOwnerAlert) error {
+	alert.ID = uuid.New().String()
+	alert.CreatedAt = time.Now()
+	m.alerts = append(m.alerts, alert)
+	return nil
+}
+
+func (m *memoryServerOwners) alert(id string) *types.ServerOwnerAlert {
+	for _, alert := range m.alerts {
+		if alert.ID == id {
+			return alert
+		}
+	}
+	return nil
+}
+
+func (m *memoryServerOwners) MarkDelivered(id string, deliveredAt *time.Time, deliveryError string) error {
+	alert := m.alert(id)
+	if alert.DeliveredAt == nil {
+		alert.DeliveredAt = deliveredAt
+	}
+	alert.DeliveryError = deliveryError
+	return nil
+}
+
+func (m *memoryServerOwners) Acknowledge(serverID, id, userID string) (bool, error) {
+	alert := m.alert(id)
+	if alert == nil || alert.ServerID != serverID {
+		return false, nil
+	}
+	if alert.AcknowledgedAt == nil {
+		now := time.Now()
+		alert.AcknowledgedAt = &now
+		alert.AcknowledgedBy = userID
+	}
+	return true, nil
+}
+
+func (m *memoryServerOwners) ResolveOpen(serverID string) (int64, error) {
+	var resolved int64
+	for _, alert := range m.alerts {
+		if alert.ServerID == serverID && alert.Open() {
+			now := time.Now()
+			alert.ResolvedAt = &now
+			resolved++
+		}
+	}
+	return resolved, nil
+}
+
+func (m *memoryServerOwners) ListAlerts(serverID string, limit int) ([]*types.ServerOwnerAlert, error) {
+	return m.alerts, nil
+}
+
+func (m *memoryServerOwners) ListEscalationDue(defaultMinutes int) ([]*types.ServerOwnerAlert, error) {
+	var due []*types.ServerOwnerAlert
+	for _, alert := range m.alerts {
+		minutes := defaultMinutes
+		if owner := m.owners[alert.ServerID]; owner != nil && owner.EscalateAfterMinutes != nil {
+			minutes = *owner.EscalateAfterMinutes
+		}
+		if alert.Open() && !alert.CreatedAt.After(time.Now().Add(-time.Duration(minutes)*time.Minute)) {
+			due = append(due, alert)
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryServerOwners) MarkEscalated(id string) error {
+	now := time.Now()
+	m.alert(id).EscalatedAt = &now
+	return nil
+}
+
+type recordingAdminNotifier struct {
+	events []*types.NotificationEvent
+}
+
+func (r *recordingAdminNotifier) Notify(ctx context.Context, event *types.NotificationEvent) (int, error) {
+	r.events = append(r.events, event)
+	return 1, nil
+}
+
+// ownerWebhook records the deliveries posted to it
+type ownerWebhook struct {
+	server     *httptest.Server
+	payloads   []types.ServerOwnerWebhookPayload
+	signatures []string
+	status     int
+}
+
+func newOwnerWebhook(t *testing.T) *ownerWebhook {
+	hook := &ownerWebhook{status: http.StatusNoContent}
+	hook.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload types.ServerOwnerWebhookPayload
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, payload.Event, r.Header.Get("X-Omnimesh-Event"))
+		if signature := r.Header.Get("X-Omnimesh-Signature"); signature != "" {
+			assert.Equal(t, "sha256="+services.SignServerOwnerWebhook("s3cret", body), signature)
+			hook.signatures = append(hook.signatures, signature)
+		}
+		hook.payloads = append(hook.payloads, payload)
+		w.WriteHeader(hook.status)
+	}))
+	t.Cleanup(hook.server.Close)
+	return hook
+}
+
+func TestServerOwnerTransitionAlertsOwner(t *testing.T) {
+	store := newMemoryServerOwners()
+	hook := newOwnerWebhook(t)
+	mail := &recordingMailer{}
+	service := services.NewServerOwnerServiceWithStore(store, "https://gateway.example.com/", time.Minute)
+	service.SetMailer(mail)
+
+	secret := "s3cret"
+	owner, err := service.SetOwner(context.Background(), "org-1", ownedServerID, &types.SetServerOwnerRequest{
+		ContactName:   "Payments team",
+		ContactEmail:  "payments@example.com",
+		WebhookURL:    hook.server.URL,
+		WebhookSecret: &secret,
+	})
+	require.NoError(t, err)
+	assert.True(t, owner.HasWebhookSecret)
+
+	// Servers without an owner are skipped
+	require.NoError(t, service.ServerTransition(context.Background(), &types.ServerTransition{
+		ServerID: uuid.New().String(), PreviousStatus: "active", Status: "unhealthy",
+	}))
+	assert.Empty(t, store.alerts)
+
+	require.NoError(t, service.ServerTransition(context.Background(), &types.ServerTransition{
+		ServerID:       ownedServerID,
+		OrganizationID: "org-1",
+		ServerName:     "payments",
+		PreviousStatus: "active",
+		Status:         "unhealthy",
+		HealthStatus:   types.HealthStatusTimeout,
+	}))
+	require.Len(t, store.alerts, 1)
+	alert := store.alerts[0]
+	assert.True(t, alert.Open())
+	assert.NotNil(t, alert.DeliveredAt)
+	assert.Empty(t, alert.DeliveryError)
+
+	require.Len(t, hook.payloads, 1)
+	assert.Len(t, hook.signatures, 1)
+	payload := hook.payloads[0]
+	assert.Equal(t, types.ServerOwnerEventStatusChanged, payload.Event)
+	assert.Equal(t, "unhealthy", payload.Status)
+	assert.Equal(t, "https://gateway.example.com/api/gateway/servers/"+ownedServerID+"/owner/alerts/"+alert.ID+"/acknowledge",
+		payload.AcknowledgeURL)
+
+	require.Len(t, mail.messages, 1)
+	assert.Equal(t, "payments@example.com", mail.messages[0].To)
+	assert.Contains(t, mail.messages[0].Body, "Hello Payments team")
+	assert.Contains(t, mail.messages[0].Body, payload.AcknowledgeURL)
+
+	// Recovery closes the open alert and needs no acknowledgement
+	require.NoError(t, service.ServerTransition(context.Background(), &types.ServerTransition{
+		ServerID: ownedServerID, OrganizationID: "org-1", ServerName: "payments",
+		PreviousStatus: "unhealthy", Status: "active", HealthStatus: types.HealthStatusHealthy,
+	}))
+	require.Len(t, store.alerts, 2)
+	assert.NotNil(t, alert.ResolvedAt)
+	assert.False(t, store.alerts[1].Open())
+	require.Len(t, hook.payloads, 2)
+	assert.Empty(t, hook.payloads[1].AcknowledgeURL)
+}
+
+func TestServerOwnerWebhookFailureRecorded(t *testing.T) {
+	store := newMemoryServerOwners()
+	hook := newOwnerWebhook(t)
+	hook.status = http.StatusInternalServerError
+	service := services.NewServerOwnerServiceWithStore(store, "", time.Minute)
+
+	_, err := service.SetOwner(context.Background(), "org-1", ownedServerID, &types.SetServerOwnerRequest{WebhookURL: hook.server.URL})
+	require.NoError(t, err)
+	require.NoError(t, service.ServerTransition(context.Background(), &types.ServerTransition{
+		ServerID: ownedServerID, OrganizationID: "org-1", PreviousStatus: "active", Status: "unhealthy",
+	}))
+
+	require.Len(t, store.alerts, 1)
+	assert.Nil(t, store.alerts[0].DeliveredAt)
+	assert.Contains(t, store.alerts[0].DeliveryError, "HTTP 500")
+	assert.Empty(t, hook.signatures, "unsigned without a secret")
+}
+
+func TestServerOwnerEscalation(t *testing.T) {
+	store := newMemoryServerOwners()
+	hook := newOwnerWebhook(t)
+	notifier := &recordingAdminNotifier{}
+	service := services.NewServerOwnerServiceWithStore(store, "", 30*time.Minute)
+	service.SetNotifier(notifier)
+
+	fiveMinutes := 5
+	_, err := service.SetOwner(context.Background(), "org-1", ownedServerID, &types.SetServerOwnerRequest{
+		WebhookURL:           hook.server.URL,
+		EscalateAfterMinutes: &fiveMinutes,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, service.ServerTransition(context.Background(), &types.ServerTransition{
+			ServerID: ownedServerID, OrganizationID: "org-1", ServerName: "payments",
+			PreviousStatus: "active", Status: "unhealthy",
+		}))
+	}
+	stale, acknowledged := store.alerts[0], store.alerts[1]
+	stale.CreatedAt = time.Now().Add(-10 * time.Minute)
+	acknowledged.CreatedAt = time.Now().Add(-10 * time.Minute)
+	require.NoError(t, service.Acknowledge(context.Background(), "org-1", ownedServerID, acknowledged.ID, "user-1"))
+	assert.Equal(t, "user-1", acknowledged.AcknowledgedBy)
+
+	escalated, err := service.Escalate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, escalated)
+	assert.NotNil(t, stale.EscalatedAt)
+	assert.Nil(t, acknowledged.EscalatedAt)
+
+	require.Len(t, notifier.events, 1)
+	event := notifier.events[0]
+	assert.Equal(t, "org-1", event.OrganizationID)
+	assert.Equal(t, types.NotificationOwnerAlertEscalated, event.Type)
+	assert.Equal(t, types.NotificationSeverityCritical, event.Severity)
+	assert.Equal(t, stale.ID, event.Data["alert_id"])
+
+	// The owner is reminded, and each alert escalates once
+	assert.Equal(t, types.ServerOwnerEventEscalated, hook.payloads[len(hook.payloads)-1].Event)
+	escalated, err = service.Escalate(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, escalated)
+}
+
+func TestServerOwnerSetValidation(t *testing.T) {
+	store := newMemoryServerOwners()
+	service := services.NewServerOwnerServiceWithStore(store, "", time.Minute)
+	ctx := context.Background()
+
+	_, err := service.SetOwner(ctx, "org-1", ownedServerID, &types.SetServerOwnerRequest{ContactName: "Nobody"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	_, err = service.SetOwner(ctx, "org-1", ownedServerID, &types.SetServerOwnerRequest{WebhookURL: "ftp://example.com/hook"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	_, err = service.SetOwner(ctx, "org-2", ownedServerID, &types.SetServerOwnerRequest{ContactEmail: "ops@example.com"})
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "servers of other organizations are hidden")
+
+	// Offline deployments only reach local webhooks
+	policy, err := airgap.New(true, nil, nil)
+	require.NoError(t, err)
+	service.SetEgressPolicy(policy)
+	_, err = service.SetOwner(ctx, "org-1", ownedServerID, &types.SetServerOwnerRequest{WebhookURL: "https://hooks.example.com/ops"})
+	assert.True(t, types.IsError(err, types.ErrCodeConnectivityRequired))
+	_, err = service.SetOwner(ctx, "org-1", ownedServerID, &types.SetServerOwnerRequest{WebhookURL: "http://alerts.internal/ops"})
+	require.NoError(t, err)
+
+	// Updates without a secret keep the current one
+	secret := "s3cret"
+	_, err = service.SetOwner(ctx, "org-1", ownedServerID, &types.SetServerOwnerRequest{ContactEmail: "ops@example.com", WebhookSecret: &secret})
+	require.NoError(t, err)
+	owner, err := service.SetOwner(ctx, "org-1", ownedServerID, &types.SetServerOwnerRequest{ContactEmail: "oncall@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", owner.WebhookSecret)
+
+	body, err := json.Marshal(owner)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "s3cret")
+}
+
+func TestServerOwnerHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newMemoryServerOwners()
+	h := handlers.NewServerOwnerHandler(services.NewServerOwnerServiceWithStore(store, "", time.Minute))
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("organization_id", "org-1")
+		c.Next()
+	})
+	router.GET("/servers/:id/owner", h.GetOwner)
+	router.PUT("/servers/:id/owner", h.SetOwner)
+	router.POST("/servers/:id/owner/alerts/:alert_id/acknowledge", h.AcknowledgeAlert)
+
+	put := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, put("/servers/nope/owner", `{"contact_email":"ops@example.com"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, put("/servers/"+ownedServerID+"/owner", `{"contact_email":"not an email"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, put("/servers/"+ownedServerID+"/owner", `{"contact_email":"ops@example.com","escalate_after_minutes":0}`).Code)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/servers/"+ownedServerID+"/owner", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = put("/servers/"+ownedServerID+"/owner", `{"contact_email":"ops@example.com","escalate_after_minutes":15}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotNil(t, store.owners[ownedServerID])
+	assert.Equal(t, 15, *store.owners[ownedServerID].EscalateAfterMinutes)
+
+	w = postJSONBody(router, "/servers/"+ownedServerID+"/owner/alerts/not-an-id/acknowledge", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = postJSONBody(router, "/servers/"+ownedServerID+"/owner/alerts/"+uuid.New().String()+"/acknowledge", `{}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,241 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mcp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRecordings struct {
+	servers    map[string]string // server -> organization
+	modes      map[string]string
+	recordings []*types.ServerRecording
+	nextID     int
+}
+
+func newMemoryRecordings() *memoryRecordings {
+	return &memoryRecordings{
+		servers: map[string]string{"upstream": "org-1", "foreign": "org-2"},
+		modes:   map[string]string{},
+	}
+}
+
+func (m *memoryRecordings) ServerExists(orgID, serverID string) (bool, error) {
+	return m.servers[serverID] == orgID, nil
+}
+
+func (m *memoryRecordings) GetMode(serverID string) (string, error) {
+	if mode, ok := m.modes[serverID]; ok {
+		return mode, nil
+	}
+	return types.RecordingModeOff, nil
+}
+
+func (m *memoryRecordings) SetMode(serverID, mode string) error {
+	if mode == types.RecordingModeOff {
+		delete(m.modes, serverID)
+	} else {
+		m.modes[serverID] = mode
+	}
+	return nil
+}
+
+func (m *memoryRecordings) Count(serverID string) (int, error) {
+	recordings, _ := m.List(serverID, "")
+	return len(recordings), nil
+}
+
+func (m *memoryRecordings) List(serverID, method string) ([]*types.ServerRecording, error) {
+	recordings := []*types.ServerRecording{}
+	for _, recording := range m.recordings {
+		if recording.ServerID == serverID && (method == "" || recording.Method == method) {
+			recordings = append(recordings, recording)
+		}
+	}
+	return recordings, nil
+}
+
+func (m *memoryRecordings) Save(recording *types.ServerRecording) error {
+	for i, existing := range m.recordings {
+		if existing.ServerID == recording.ServerID && existing.Fingerprint == recording.Fingerprint {
+			recording.ID = existing.ID
+			m.recordings[i] = recording
+			return nil
+		}
+	}
+	m.nextID++
+	recording.ID = fmt.Sprintf("recording-%d", m.nextID)
+	m.recordings = append(m.recordings, recording)
+	return nil
+}
+
+func (m *memoryRecordings) Replay(serverID, fingerprint string) (*types.ServerRecording, error) {
+	for _, recording := range m.recordings {
+		if recording.ServerID == serverID && recording.Fingerprint == fingerprint {
+			recording.ReplayCount++
+			return recording, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryRecordings) Delete(serverID, id string) (bool, error) {
+	for i, recording := range m.recordings {
+		if recording.ServerID == serverID && recording.ID == id {
+			m.recordings = append(m.recordings[:i], m.recordings[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryRecordings) Clear(serverID string) (int, error) {
+	kept := m.recordings[:0]
+	cleared := 0
+	for _, recording := range m.recordings {
+		if recording.ServerID == serverID {
+			cleared++
+			continue
+		}
+		kept = append(kept, recording)
+	}
+	m.recordings = kept
+	return cleared, nil
+}
+
+type recordingSessions struct {
+	reset []string
+}
+
+func (r *recordingSessions) ResetServerSessions(serverID string) {
+	r.reset = append(r.reset, serverID)
+}
+
+// connectThrough connects an MCP client over transport to a mock upstream
+// with an echo tool
+func connectThrough(t *testing.T, transport mcp.Transport) *mcp.MCPClient {
+	t.Helper()
+	client := mcp.NewMCPClient(transport)
+	err := client.Connect(context.Background(), mcp.TransportConfig{
+		Type: "mock",
+		MockTools: func(ctx context.Context) ([]types.MockTool, error) {
+			return []types.MockTool{{Name: "echo", Response: "echo {{.arguments.text}}"}}, nil
+		},
+	}, mcp.ClientInfo{Name: "test", Version: "1.0.0"})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestServerRecording_RecordThenReplay(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryRecordings()
+	service := services.NewServerRecordingServiceWithStore(store)
+
+	recorder := connectThrough(t, mcp.NewRecordingTransport(&mcp.MockTransport{}, "upstream", service))
+	_, err := recorder.ListTools(ctx)
+	require.NoError(t, err)
+	_, err = recorder.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"})
+	require.NoError(t, err)
+
+	methods := []string{}
+	for _, recording := range store.recordings {
+		methods = append(methods, recording.Method)
+	}
+	assert.Equal(t, []string{"initialize", "tools/list", "tools/call"}, methods)
+
+	// The replay answers without the upstream, by fingerprint
+	replayer := connectThrough(t, mcp.NewReplayTransport("upstream", service))
+	tools, err := replayer.ListTools(ctx)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "echo", tools[0].Name)
+
+	result, err := replayer.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "echo hi", result.(mcp.ToolsCallResult).Content[0].Text)
+	assert.Equal(t, 1, store.recordings[2].ReplayCount)
+
+	_, err = replayer.CallTool(ctx, "echo", map[string]interface{}{"text": "unrecorded"})
+	assert.ErrorContains(t, err, "no recorded response for tools/call")
+}
+
+func TestServerRecording_Fingerprint(t *testing.T) {
+	fingerprint := func(params string) string {
+		t.Helper()
+		value, err := mcp.RecordingFingerprint("tools/call", json.RawMessage(params))
+		require.NoError(t, err)
+		return value
+	}
+
+	base := fingerprint(`{"name": "echo", "arguments": {"a": 1, "b": 2}}`)
+	assert.Equal(t, base, fingerprint(`{"arguments": {"b": 2, "a": 1}, "name": "echo"}`))
+	assert.Equal(t, base, fingerprint(`{"name": "echo", "arguments": {"a": 1, "b": 2}, "_meta": {"progressToken": 7}}`))
+	assert.NotEqual(t, base, fingerprint(`{"name": "echo", "arguments": {"a": 1, "b": 3}}`))
+
+	other, err := mcp.RecordingFingerprint("resources/read", json.RawMessage(`{"name": "echo", "arguments": {"a": 1, "b": 2}}`))
+	require.NoError(t, err)
+	assert.NotEqual(t, base, other)
+
+	// Missing and empty params are the same request
+	assert.Equal(t, fingerprint(``), fingerprint(`{}`))
+}
+
+func TestServerRecordingService_ModesAndImports(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryRecordings()
+	sessions := &recordingSessions{}
+	service := services.NewServerRecordingServiceWithStore(store)
+	service.SetSessionResetter(sessions)
+
+	status, err := service.SetMode(ctx, "org-1", "upstream", &types.SetRecordingModeRequest{Mode: types.RecordingModeReplay})
+	require.NoError(t, err)
+	assert.Equal(t, types.RecordingModeReplay, status.Mode)
+	assert.Equal(t, []string{"upstream"}, sessions.reset)
+
+	_, err = service.SetMode(ctx, "org-1", "upstream", &types.SetRecordingModeRequest{Mode: "rewind"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	_, err = service.SetMode(ctx, "org-1", "foreign", &types.SetRecordingModeRequest{Mode: types.RecordingModeRecord})
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	imported, err := service.ImportRecordings(ctx, "org-1", "upstream", &types.ImportRecordingsRequest{
+		Recordings: []types.ImportedRecording{
+			{Method: "tools/list", Result: json.RawMessage(`{"tools": []}`)},
+			{Method: "tools/call", Params: json.RawMessage(`{"name": "down"}`), Error: &types.MCPError{Code: -32000, Message: "offline"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, imported, 2)
+
+	_, err = service.ImportRecordings(ctx, "org-1", "upstream", &types.ImportRecordingsRequest{
+		Recordings: []types.ImportedRecording{{Method: "tools/list"}},
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	// Imported errors replay as errors
+	replayer := connectThrough(t, mcp.NewReplayTransport("upstream", service))
+	_, err = replayer.CallTool(ctx, "down", nil)
+	assert.ErrorContains(t, err, "offline")
+
+	calls, err := service.ListRecordings(ctx, "org-1", "upstream", "tools/call")
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	require.NoError(t, service.DeleteRecording(ctx, "org-1", "upstream", calls[0].ID))
+	assert.True(t, types.IsError(service.DeleteRecording(ctx, "org-1", "upstream", calls[0].ID), types.ErrCodeNotFound))
+
+	cleared, err := service.ClearRecordings(ctx, "org-1", "upstream")
+	require.NoError(t, err)
+	assert.Equal(t, 1, cleared)
+
+	status, err = service.GetStatus(ctx, "org-1", "upstream")
+	require.NoError(t, err)
+	assert.Equal(t, 0, status.Recordings)
+}
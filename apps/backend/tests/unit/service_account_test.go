@@ -0,0 +1,208 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+)
+
+// memoryServiceAccounts keeps service accounts, and the API keys and OAuth
+// clients acting as them, in memory
+type memoryServiceAccounts struct {
+	accounts map[string]*types.ServiceAccount
+	retired  map[string]bool
+	keys     map[string][]*types.CreateAPIKeyRequest
+	clients  map[string][]*types.OAuthClient
+}
+
+func newMemoryServiceAccounts() *memoryServiceAccounts {
+	return &memoryServiceAccounts{
+		accounts: map[string]*types.ServiceAccount{},
+		retired:  map[string]bool{},
+		keys:     map[string][]*types.CreateAPIKeyRequest{},
+		clients:  map[string][]*types.OAuthClient{},
+	}
+}
+
+func (m *memoryServiceAccounts) List(orgID string) ([]*types.ServiceAccount, error) {
+	var accounts []*types.ServiceAccount
+	for id, account := range m.accounts {
+		if account.OrganizationID == orgID && !m.retired[id] {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (m *memoryServiceAccounts) Get(orgID, id string) (*types.ServiceAccount, error) {
+	account := m.accounts[id]
+	if account == nil || account.OrganizationID != orgID || m.retired[id] {
+		return nil, nil
+	}
+	copied := *account
+	return &copied, nil
+}
+
+func (m *memoryServiceAccounts) Create(account *types.ServiceAccount) error {
+	account.ID = fmt.Sprintf("sa-%d", len(m.accounts)+1)
+	account.IsActive = true
+	m.accounts[account.ID] = account
+	return nil
+}
+
+func (m *memoryServiceAccounts) Update(account *types.ServiceAccount) error {
+	m.accounts[account.ID] = account
+	return nil
+}
+
+func (m *memoryServiceAccounts) Retire(orgID, id string) (bool, error) {
+	if account, _ := m.Get(orgID, id); account == nil {
+		return false, nil
+	}
+	m.retired[id] = true
+	delete(m.keys, id)
+	delete(m.clients, id)
+	return true, nil
+}
+
+func (m *memoryServiceAccounts) ListClients(serviceAccountID string) ([]*types.OAuthClient, error) {
+	return m.clients[serviceAccountID], nil
+}
+
+func (m *memoryServiceAccounts) DeleteClient(serviceAccountID, clientID string) (bool, error) {
+	for i, client := range m.clients[serviceAccountID] {
+		if client.ClientID == clientID {
+			m.clients[serviceAccountID] = append(m.clients[serviceAccountID][:i], m.clients[serviceAccountID][i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryServiceAccounts) CreateAPIKey(userID string, req *types.CreateAPIKeyRequest) (*types.CreateAPIKeyResponse, error) {
+	m.keys[userID] = append(m.keys[userID], req)
+	return &types.CreateAPIKeyResponse{APIKey: &types.APIKey{ID: req.Name, UserID: userID, Role: req.Role}, Key: "mgw_secret"}, nil
+}
+
+func (m *memoryServiceAccounts) ListAPIKeys(userID string) ([]*types.APIKey, error) {
+	var keys []*types.APIKey
+	for _, req := range m.keys[userID] {
+		keys = append(keys, &types.APIKey{ID: req.Name, UserID: userID, Role: req.Role})
+	}
+	return keys, nil
+}
+
+func (m *memoryServiceAccounts) DeleteAPIKey(userID, keyID string) error {
+	for i, req := range m.keys[userID] {
+		if req.Name == keyID {
+			m.keys[userID] = append(m.keys[userID][:i], m.keys[userID][i+1:]...)
+			return nil
+		}
+	}
+	return types.NewNotFoundError("API key not found")
+}
+
+func (m *memoryServiceAccounts) CreateServiceAccountClient(ctx context.Context, orgID, serviceAccountID, name, scope string) (*types.ClientRegistrationResponse, error) {
+	client := &types.OAuthClient{ClientID: "client-" + name, ClientName: name, Scope: scope, ServiceAccountID: &serviceAccountID}
+	m.clients[serviceAccountID] = append(m.clients[serviceAccountID], client)
+	return &types.ClientRegistrationResponse{ClientID: client.ClientID, ClientSecret: "secret"}, nil
+}
+
+func newServiceAccountService() (*services.ServiceAccountService, *memoryServiceAccounts) {
+	store := newMemoryServiceAccounts()
+	return services.NewServiceAccountServiceWithStore(store, store, store), store
+}
+
+func TestServiceAccountLifecycle(t *testing.T) {
+	svc, store := newServiceAccountService()
+	ctx := context.Background()
+
+	account, err := svc.Create(ctx, grantOrgID, grantedUserID, &types.CreateServiceAccountRequest{
+		Name: "CI Deployer", Description: "  deploys from CI ", Role: types.RoleUser,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, grantOrgID, account.OrganizationID)
+	assert.Equal(t, grantedUserID, account.CreatedBy)
+	assert.Equal(t, "deploys from CI", account.Description)
+	assert.True(t, account.IsActive)
+
+	_, err = svc.Create(ctx, grantOrgID, grantedUserID, &types.CreateServiceAccountRequest{Name: "ci deployer", Role: types.RoleViewer})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict), "names are unique within an organization")
+
+	_, err = svc.Get(ctx, "org-2", account.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "other organizations cannot see the account")
+
+	role := types.RoleViewer
+	updated, err := svc.Update(ctx, grantOrgID, account.ID, &types.UpdateServiceAccountRequest{Role: &role})
+	require.NoError(t, err)
+	assert.Equal(t, types.RoleViewer, updated.Role)
+
+	require.NoError(t, svc.Delete(ctx, grantOrgID, account.ID))
+	_, err = svc.Get(ctx, grantOrgID, account.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+	assert.True(t, types.IsError(svc.Delete(ctx, grantOrgID, account.ID), types.ErrCodeNotFound))
+	assert.NotNil(t, store.accounts[account.ID], "retired accounts stay for the audit trail")
+
+	// The name is free again once the account is retired
+	_, err = svc.Create(ctx, grantOrgID, grantedUserID, &types.CreateServiceAccountRequest{Name: "CI Deployer", Role: types.RoleUser})
+	require.NoError(t, err)
+}
+
+func TestServiceAccountCredentials(t *testing.T) {
+	svc, store := newServiceAccountService()
+	ctx := context.Background()
+
+	account, err := svc.Create(ctx, grantOrgID, grantedUserID, &types.CreateServiceAccountRequest{Name: "Indexer", Role: types.RoleViewer})
+	require.NoError(t, err)
+
+	key, err := svc.CreateAPIKey(ctx, grantOrgID, account.ID, &types.CreateServiceAccountKeyRequest{Name: "indexer-key"})
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, key.APIKey.UserID)
+	assert.Equal(t, types.RoleViewer, key.APIKey.Role, "keys carry the account's role")
+
+	_, err = svc.CreateAPIKey(ctx, "org-2", account.ID, &types.CreateServiceAccountKeyRequest{Name: "foreign"})
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	client, err := svc.CreateClient(ctx, grantOrgID, account.ID, &types.CreateServiceAccountClientRequest{Name: "indexer", Scope: "read"})
+	require.NoError(t, err)
+	clients, err := svc.ListClients(ctx, grantOrgID, account.ID)
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+	assert.Equal(t, account.ID, *clients[0].ServiceAccountID)
+
+	disabled := false
+	_, err = svc.Update(ctx, grantOrgID, account.ID, &types.UpdateServiceAccountRequest{IsActive: &disabled})
+	require.NoError(t, err)
+	_, err = svc.CreateAPIKey(ctx, grantOrgID, account.ID, &types.CreateServiceAccountKeyRequest{Name: "another"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "disabled accounts get no new credentials")
+	_, err = svc.CreateClient(ctx, grantOrgID, account.ID, &types.CreateServiceAccountClientRequest{Name: "another"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	// Revoking still works while disabled
+	require.NoError(t, svc.RevokeAPIKey(ctx, grantOrgID, account.ID, "indexer-key"))
+	keys, err := svc.ListAPIKeys(ctx, grantOrgID, account.ID)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	require.NoError(t, svc.DeleteClient(ctx, grantOrgID, account.ID, client.ClientID))
+	assert.True(t, types.IsError(svc.DeleteClient(ctx, grantOrgID, account.ID, client.ClientID), types.ErrCodeNotFound))
+	assert.Empty(t, store.clients[account.ID])
+}
+
+func TestServiceAccountPrincipal(t *testing.T) {
+	principal := &types.Principal{
+		Type:     types.PrincipalTypeServiceAccount,
+		UserID:   "sa-1",
+		APIKeyID: "key-1",
+	}
+	assert.Equal(t, "sa-1", principal.ID(), "usage from every credential is grouped under the account")
+	assert.Equal(t, "service_account:sa-1", principal.Key())
+
+	assert.True(t, (&types.User{AccountType: types.AccountTypeService}).IsServiceAccount())
+	assert.False(t, (&types.User{}).IsServiceAccount())
+}
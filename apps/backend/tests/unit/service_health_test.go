@@ -0,0 +1,152 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/health"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// healthClock is a clock that probes advance to simulate their latency
+type healthClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+func (c *healthClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *healthClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// dependencyProbe answers a monitor's checks with a set latency and error
+type dependencyProbe struct {
+	clock   *healthClock
+	err     error
+	latency time.Duration
+}
+
+func (p *dependencyProbe) Check(ctx context.Context) error {
+	p.clock.Advance(p.latency)
+	return p.err
+}
+
+func newHealthMonitor(t *testing.T) (*health.Monitor, *healthClock, *dependencyProbe, *dependencyProbe) {
+	t.Helper()
+	clock := &healthClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	database := &dependencyProbe{clock: clock, latency: 5 * time.Millisecond}
+	// Probes run in parallel on the shared clock, so only the database's
+	// latency is simulated and Redis never counts as slow
+	redis := &dependencyProbe{clock: clock}
+
+	monitor := health.NewMonitor(health.Config{ImpactHalfLife: 10 * time.Second})
+	monitor.SetClock(clock.Now)
+	monitor.AddDependency(health.Dependency{Name: "database", Check: database.Check, SlowAfter: 100 * time.Millisecond, Critical: true})
+	monitor.AddDependency(health.Dependency{Name: "redis", Check: redis.Check, SlowAfter: time.Minute})
+	return monitor, clock, database, redis
+}
+
+func TestServiceHealth_DependencyStates(t *testing.T) {
+	monitor, _, database, redis := newHealthMonitor(t)
+
+	// Nothing probed yet
+	status := monitor.Health()
+	assert.Equal(t, types.ServiceHealthHealthy, status.Status)
+	assert.Equal(t, types.DependencyStatusUnknown, status.Dependencies[0].Status)
+
+	status = monitor.CheckNow(context.Background())
+	assert.Equal(t, types.ServiceHealthHealthy, status.Status)
+	assert.Equal(t, 1.0, status.Score)
+	assert.Equal(t, 5.0, status.Dependencies[0].LatencyMs)
+
+	// A slow database lowers the score in proportion to its latency
+	database.latency = 400 * time.Millisecond
+	status = monitor.CheckNow(context.Background())
+	assert.Equal(t, types.ServiceHealthDegraded, status.Status)
+	assert.Equal(t, types.DependencyStatusSlow, status.Dependencies[0].Status)
+	assert.InDelta(t, 0.25, status.Score, 0.001)
+
+	// Redis is optional, so losing it costs at most half the score
+	database.latency = 5 * time.Millisecond
+	redis.err = errors.New("connection refused")
+	status = monitor.CheckNow(context.Background())
+	assert.Equal(t, types.ServiceHealthDegraded, status.Status)
+	assert.Equal(t, "connection refused", status.Dependencies[1].Error)
+	assert.InDelta(t, 0.5, monitor.Score(), 0.001)
+
+	// Losing the database takes the instance out of rotation
+	database.err = errors.New("too many connections")
+	status = monitor.CheckNow(context.Background())
+	assert.Equal(t, types.ServiceHealthUnavailable, status.Status)
+	assert.Equal(t, 0.0, status.Score)
+}
+
+func TestServiceHealth_ServerScores(t *testing.T) {
+	monitor, clock, _, _ := newHealthMonitor(t)
+	assert.Equal(t, 1.0, monitor.ServerScore("replica-a"))
+
+	for i := 0; i < 20; i++ {
+		monitor.ObserveServerCall("replica-a", 20*time.Millisecond, false)
+		monitor.ObserveServerCall("replica-b", 20*time.Millisecond, false)
+	}
+	assert.Equal(t, 1.0, monitor.ServerScore("replica-a"))
+
+	// Failures and slowdowns lower a server's score
+	for i := 0; i < 10; i++ {
+		monitor.ObserveServerCall("replica-a", 20*time.Millisecond, true)
+		monitor.ObserveServerCall("replica-b", 500*time.Millisecond, false)
+	}
+	failing, slow := monitor.ServerScore("replica-a"), monitor.ServerScore("replica-b")
+	assert.Less(t, failing, 0.7)
+	assert.Less(t, slow, 0.5)
+
+	// Without new calls the impact wears off
+	clock.Advance(10 * time.Second)
+	assert.InDelta(t, 1-(1-failing)/2, monitor.ServerScore("replica-a"), 0.001)
+	clock.Advance(time.Minute)
+	assert.Greater(t, monitor.ServerScore("replica-b"), 0.95)
+}
+
+func TestServiceHealth_ServerGroupsPreferLeastImpacted(t *testing.T) {
+	groups, _, group := newReplicaGroup(t, types.LoadBalanceRoundRobin)
+	monitor, _, _, _ := newHealthMonitor(t)
+	groups.SetReplicaScorer(monitor, 0.2)
+
+	for i := 0; i < 10; i++ {
+		monitor.ObserveServerCall("replica-b", 20*time.Millisecond, true)
+	}
+	picked := pickReplicas(t, groups, "replica-a", 4, false)
+	assert.NotContains(t, picked, "replica-b")
+	assert.Contains(t, picked, "replica-a")
+	assert.Contains(t, picked, "replica-c")
+
+	current, err := groups.GetGroup(context.Background(), "org-1", group.ID)
+	require.NoError(t, err)
+	for _, member := range current.Members {
+		if member.ServerID == "replica-b" {
+			assert.Less(t, member.HealthScore, 0.8)
+		} else {
+			assert.Equal(t, 1.0, member.HealthScore)
+		}
+	}
+
+	// When every replica is impacted alike, they share the calls again
+	for i := 0; i < 10; i++ {
+		monitor.ObserveServerCall("replica-a", 20*time.Millisecond, true)
+		monitor.ObserveServerCall("replica-c", 20*time.Millisecond, true)
+	}
+	assert.ElementsMatch(t, []string{"replica-a", "replica-b", "replica-c"}, pickReplicas(t, groups, "replica-a", 3, false))
+}
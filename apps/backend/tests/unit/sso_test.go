@@ -0,0 +1,389 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	ssoOrgID    = "11111111-1111-1111-1111-111111111111"
+	ssoClientID = "gateway-client"
+)
+
+// memorySSO keeps providers, identities, users and sign-ins in memory,
+// mirroring SSOModel
+type memorySSO struct {
+	providers  map[string]*types.SSOProvider
+	identities map[string]*types.SSOIdentity
+	users      map[string]*types.User
+	states     map[string]*types.SSOLoginState
+}
+
+func newMemorySSO() *memorySSO {
+	return &memorySSO{
+		providers:  make(map[string]*types.SSOProvider),
+		identities: make(map[string]*types.SSOIdentity),
+		users:      make(map[string]*types.User),
+		states:     make(map[string]*types.SSOLoginState),
+	}
+}
+
+func (m *memorySSO) ListProviders(orgID string) ([]*types.SSOProvider, error) {
+	providers := []*types.SSOProvider{}
+	for _, provider := range m.providers {
+		if provider.OrganizationID == orgID {
+			providers = append(providers, provider)
+		}
+	}
+	return providers, nil
+}
+
+func (m *memorySSO) ListActiveProvidersByOrgSlug(slug string) ([]*types.SSOProvider, error) {
+	return m.ListProviders(ssoOrgID)
+}
+
+func (m *memorySSO) GetProvider(id string) (*types.SSOProvider, error) {
+	return m.providers[id], nil
+}
+
+func (m *memorySSO) CreateProvider(provider *types.SSOProvider) error {
+	provider.ID = uuid.New().String()
+	m.providers[provider.ID] = provider
+	return nil
+}
+
+func (m *memorySSO) UpdateProvider(provider *types.SSOProvider) error {
+	m.providers[provider.ID] = provider
+	return nil
+}
+
+func (m *memorySSO) DeleteProvider(orgID, id string) (bool, error) {
+	if provider, ok := m.providers[id]; ok && provider.OrganizationID == orgID {
+		delete(m.providers, id)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (m *memorySSO) CreateLoginState(state *types.SSOLoginState) error {
+	m.states[state.StateHash] = state
+	return nil
+}
+
+func (m *memorySSO) ConsumeLoginState(stateHash string) (*types.SSOLoginState, error) {
+	state := m.states[stateHash]
+	delete(m.states, stateHash)
+	return state, nil
+}
+
+func (m *memorySSO) GetIdentity(providerID, subject string) (*types.SSOIdentity, error) {
+	return m.identities[providerID+"/"+subject], nil
+}
+
+func (m *memorySSO) CreateIdentity(identity *types.SSOIdentity) error {
+	identity.ID = uuid.New().String()
+	m.identities[identity.ProviderID+"/"+identity.Subject] = identity
+	return nil
+}
+
+func (m *memorySSO) TouchIdentity(id, email string) error {
+	return nil
+}
+
+func (m *memorySSO) GetUser(id string) (*types.User, error) {
+	return m.users[id], nil
+}
+
+func (m *memorySSO) GetUserByEmail(email string) (*types.User, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memorySSO) CreateUser(user *types.User) error {
+	user.ID = uuid.New().String()
+	user.IsActive = true
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m *memorySSO) UpdateUserRole(userID, role string) error {
+	m.users[userID].Role = role
+	return nil
+}
+
+// ssoTokenIssuer hands out the user it signs in as the access token
+type ssoTokenIssuer struct{}
+
+func (ssoTokenIssuer) IssueLogin(user *types.User, ctx *auth.LoginContext) (*types.LoginResponse, error) {
+	return &types.LoginResponse{User: user, AccessToken: user.ID, TokenType: "Bearer"}, nil
+}
+
+// fakeIdP is an OpenID Connect provider that issues ID tokens with the
+// claims set by the test
+type fakeIdP struct {
+	server    *httptest.Server
+	keys      *auth.KeySet
+	claims    jwt.MapClaims
+	challenge string
+	nonce     string
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	key, err := auth.GenerateSigningKey(auth.SigningAlgorithmES256)
+	require.NoError(t, err)
+	keys, err := auth.NewKeySet("", []*auth.SigningKey{key})
+	require.NoError(t, err)
+
+	idp := &fakeIdP{keys: keys}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(idp.keys.JWKS())
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ := r.BasicAuth()
+		if clientID != ssoClientID || clientSecret != "secret" || auth.PKCEChallenge(r.FormValue("code_verifier")) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss":   idp.server.URL,
+			"aud":   ssoClientID,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": idp.nonce,
+		}
+		for name, value := range idp.claims {
+			claims[name] = value
+		}
+		idToken, err := idp.keys.Sign(claims)
+		require.NoError(t, err)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// signIn runs a sign-in through the fake IdP as a user with claims
+func (idp *fakeIdP) signIn(t *testing.T, service *services.SSOService, providerID string, claims jwt.MapClaims) (*types.LoginResponse, error) {
+	authURL, err := service.BeginLogin(context.Background(), providerID, "/dashboard")
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	idp.challenge, idp.nonce, idp.claims = query.Get("code_challenge"), query.Get("nonce"), claims
+	response, redirectPath, err := service.CompleteLogin(context.Background(), query.Get("state"), "code", nil)
+	if err == nil {
+		assert.Equal(t, "/dashboard", redirectPath)
+	}
+	return response, err
+}
+
+func newSSOTestService(t *testing.T) (*services.SSOService, *memorySSO, *fakeIdP, *types.SSOProvider) {
+	idp := newFakeIdP(t)
+	store := newMemorySSO()
+	service := services.NewSSOServiceWithStore(store, ssoTokenIssuer{}, "https://gateway.example.com/api/auth/sso/callback", 0)
+
+	autoProvision := true
+	provider, err := service.CreateProvider(context.Background(), ssoOrgID, "", &types.CreateSSOProviderRequest{
+		DisplayName:    "Okta",
+		IssuerURL:      idp.server.URL,
+		ClientID:       ssoClientID,
+		ClientSecret:   "secret",
+		AutoProvision:  &autoProvision,
+		AllowedDomains: []string{"@Example.com"},
+		GroupRoles:     map[string]string{"gateway-admins": types.RoleAdmin, "engineers": types.RoleUser},
+		DefaultRole:    types.RoleViewer,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, provider.AllowedDomains)
+	assert.Equal(t, types.DefaultSSOGroupsClaim, provider.GroupsClaim)
+	return service, store, idp, provider
+}
+
+func TestSSOService_AutoProvisionNeedsAllowedDomains(t *testing.T) {
+	service, _, idp, provider := newSSOTestService(t)
+	ctx := context.Background()
+
+	// Provisioning is opt-in
+	manual, err := service.CreateProvider(ctx, ssoOrgID, "", &types.CreateSSOProviderRequest{
+		DisplayName: "Google", IssuerURL: idp.server.URL, ClientID: "google-client", ClientSecret: "secret",
+	})
+	require.NoError(t, err)
+	assert.False(t, manual.AutoProvision)
+
+	// Anyone the IdP verifies could join without a domain allow list
+	autoProvision := true
+	_, err = service.CreateProvider(ctx, ssoOrgID, "", &types.CreateSSOProviderRequest{
+		DisplayName: "Azure AD", IssuerURL: idp.server.URL, ClientID: "azure-client", ClientSecret: "secret",
+		AutoProvision: &autoProvision,
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "%v", err)
+	_, err = service.UpdateProvider(ctx, ssoOrgID, manual.ID, &types.UpdateSSOProviderRequest{AutoProvision: &autoProvision})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "%v", err)
+	_, err = service.UpdateProvider(ctx, ssoOrgID, provider.ID, &types.UpdateSSOProviderRequest{AllowedDomains: []string{}})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "clearing the domains of a provisioning provider: %v", err)
+}
+
+func TestSSOService_ProvisionsUsersAndMapsGroups(t *testing.T) {
+	service, store, idp, provider := newSSOTestService(t)
+
+	response, err := idp.signIn(t, service, provider.ID, jwt.MapClaims{
+		"sub":            "okta-1",
+		"email":          "Ada@example.com",
+		"email_verified": true,
+		"name":           "Ada",
+		"groups":         []string{"engineers", "gateway-admins"},
+	})
+	require.NoError(t, err)
+	user := response.User
+	assert.Equal(t, "ada@example.com", user.Email)
+	assert.Equal(t, ssoOrgID, user.OrganizationID)
+	assert.Equal(t, types.RoleAdmin, user.Role, "the most privileged mapped role wins")
+	assert.Len(t, store.users, 1)
+
+	// The next sign-in finds the user by subject and follows their groups
+	response, err = idp.signIn(t, service, provider.ID, jwt.MapClaims{
+		"sub":    "okta-1",
+		"groups": []string{"engineers"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, response.User.ID)
+	assert.Equal(t, types.RoleUser, store.users[user.ID].Role)
+
+	response, err = idp.signIn(t, service, provider.ID, jwt.MapClaims{"sub": "okta-1"})
+	require.NoError(t, err)
+	assert.Equal(t, types.RoleViewer, response.User.Role, "users in no mapped group get the default role")
+}
+
+// ssoTeams keeps teams of the SSO test organization in memory
+type ssoTeams struct {
+	*memoryTeams
+}
+
+func (ssoTeams) UserOrganization(userID string) (string, error) {
+	return ssoOrgID, nil
+}
+
+func TestSSOService_SyncsTeamsWithGroups(t *testing.T) {
+	service, _, idp, provider := newSSOTestService(t)
+	store := ssoTeams{newMemoryTeams()}
+	service.SetTeamSync(services.NewTeamServiceWithStore(store))
+	platform := &types.Team{OrganizationID: ssoOrgID, Name: "Platform", IdPGroup: "engineers"}
+	admins := &types.Team{OrganizationID: ssoOrgID, Name: "Admins", IdPGroup: "Gateway-Admins"}
+	oncall := &types.Team{OrganizationID: ssoOrgID, Name: "On-call", IdPGroup: "sre"}
+	for _, team := range []*types.Team{platform, admins, oncall} {
+		require.NoError(t, store.CreateTeam(team))
+	}
+
+	response, err := idp.signIn(t, service, provider.ID, jwt.MapClaims{
+		"sub":            "okta-1",
+		"email":          "ada@example.com",
+		"email_verified": true,
+		"groups":         []string{"engineers", "gateway-admins"},
+	})
+	require.NoError(t, err)
+	userID := response.User.ID
+	assert.Equal(t, types.TeamMemberSourceIdP, store.members[platform.ID][userID])
+	assert.Equal(t, types.TeamMemberSourceIdP, store.members[admins.ID][userID], "groups match case-insensitively")
+	assert.NotContains(t, store.members[oncall.ID], userID)
+
+	// Leaving a group at the provider leaves its team on the next sign-in,
+	// but memberships added by admins stay
+	require.NoError(t, store.AddMember(oncall.ID, userID, types.TeamMemberSourceManual))
+	_, err = idp.signIn(t, service, provider.ID, jwt.MapClaims{"sub": "okta-1", "groups": []string{"engineers"}})
+	require.NoError(t, err)
+	assert.Contains(t, store.members[platform.ID], userID)
+	assert.NotContains(t, store.members[admins.ID], userID)
+	assert.Equal(t, types.TeamMemberSourceManual, store.members[oncall.ID][userID])
+}
+
+func TestSSOService_LinksAndRefusesAccounts(t *testing.T) {
+	service, store, idp, provider := newSSOTestService(t)
+	store.users["local"] = &types.User{ID: "local", Email: "grace@example.com", OrganizationID: ssoOrgID, Role: types.RoleUser, IsActive: true}
+	store.users["foreign"] = &types.User{ID: "foreign", Email: "linus@example.com", OrganizationID: uuid.New().String(), Role: types.RoleAdmin, IsActive: true}
+
+	// A verified email links the existing user of the organization
+	response, err := idp.signIn(t, service, provider.ID, jwt.MapClaims{
+		"sub": "okta-2", "email": "grace@example.com", "email_verified": "true", "groups": []string{"engineers"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "local", response.User.ID)
+	assert.NotNil(t, store.identities[provider.ID+"/okta-2"])
+
+	refused := []jwt.MapClaims{
+		// Users of another organization are not taken over
+		{"sub": "okta-3", "email": "linus@example.com", "email_verified": true},
+		// Nor is any account claimed without a verified email
+		{"sub": "okta-4", "email": "grace@example.com", "email_verified": false},
+		// Domains outside the allow list cannot join
+		{"sub": "okta-5", "email": "mallory@evil.test", "email_verified": true},
+	}
+	for _, claims := range refused {
+		_, err := idp.signIn(t, service, provider.ID, claims)
+		assert.True(t, types.IsError(err, types.ErrCodeAccessDenied), "claims %v: %v", claims, err)
+	}
+	assert.Len(t, store.users, 2)
+}
+
+func TestSSOService_RejectsReplayedAndForgedSignIns(t *testing.T) {
+	service, _, idp, provider := newSSOTestService(t)
+
+	authURL, err := service.BeginLogin(context.Background(), provider.ID, "https://evil.test/")
+	require.NoError(t, err)
+	query, err := url.ParseQuery(authURL[len(idp.server.URL+"/authorize?"):])
+	require.NoError(t, err)
+	idp.challenge, idp.nonce = query.Get("code_challenge"), query.Get("nonce")
+	idp.claims = jwt.MapClaims{"sub": "okta-6", "email": "alan@example.com", "email_verified": true}
+
+	_, redirectPath, err := service.CompleteLogin(context.Background(), query.Get("state"), "code", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/", redirectPath, "off-site redirects are dropped")
+
+	// Each state is used once
+	_, _, err = service.CompleteLogin(context.Background(), query.Get("state"), "code", nil)
+	assert.True(t, types.IsError(err, types.ErrCodeUnauthorized))
+
+	// ID tokens for another nonce are refused
+	_, err = idp.signIn(t, service, provider.ID, jwt.MapClaims{"sub": "okta-6", "nonce": "replayed"})
+	assert.True(t, types.IsError(err, types.ErrCodeUnauthorized))
+
+	// As are ID tokens signed by a key the IdP does not publish
+	other, err := auth.GenerateSigningKey(auth.SigningAlgorithmES256)
+	require.NoError(t, err)
+	signer, err := auth.NewKeySet("", []*auth.SigningKey{other})
+	require.NoError(t, err)
+	forged, err := signer.Sign(jwt.MapClaims{"iss": idp.server.URL, "aud": ssoClientID, "sub": "okta-6", "exp": time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+	_, err = auth.NewOIDCClient(nil).VerifyIDToken(context.Background(), idp.server.URL, ssoClientID, forged, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package unit
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listenUDP(t *testing.T) *net.UDPConn {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestStatsDEmitterFormat(t *testing.T) {
+	listener := listenUDP(t)
+
+	metric := &types.Metric{
+		Name:           "http_requests_total",
+		Type:           types.MetricTypeCounter,
+		Value:          1,
+		OrganizationID: "org-1",
+		Tags:           map[string]string{"method": "GET", "status": "200"},
+	}
+
+	t.Run("dogstatsd maps names and tags", func(t *testing.T) {
+		emitter, err := observability.NewStatsDEmitter(&observability.StatsDConfig{
+			Address:    listener.LocalAddr().String(),
+			GlobalTags: map[string]string{"env": "test"},
+		})
+		require.NoError(t, err)
+		defer emitter.Close()
+
+		assert.Equal(t,
+			"omnimesh.http.requests:1|c|#env:test,http.method:GET,http.status_code:200,org_id:org-1",
+			emitter.Format(metric, 1))
+		assert.Equal(t,
+			"omnimesh.http.requests:1|c|@0.25|#env:test,http.method:GET,http.status_code:200,org_id:org-1",
+			emitter.Format(metric, 0.25))
+	})
+
+	t.Run("plain statsd drops tags and uses timers", func(t *testing.T) {
+		emitter, err := observability.NewStatsDEmitter(&observability.StatsDConfig{
+			Address:     listener.LocalAddr().String(),
+			Flavor:      observability.FlavorStatsD,
+			Prefix:      "gw.",
+			MetricNames: map[string]string{"http_request_duration": "latency"},
+		})
+		require.NoError(t, err)
+		defer emitter.Close()
+
+		assert.Equal(t, "gw.latency:12.5|ms", emitter.Format(&types.Metric{
+			Name:  "http_request_duration",
+			Type:  types.MetricTypeHistogram,
+			Value: 12.5,
+			Tags:  map[string]string{"method": "GET"},
+		}, 1))
+	})
+
+	t.Run("unsupported flavor", func(t *testing.T) {
+		_, err := observability.NewStatsDEmitter(&observability.StatsDConfig{
+			Address: listener.LocalAddr().String(),
+			Flavor:  "graphite",
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestStatsDEmitterEmit(t *testing.T) {
+	listener := listenUDP(t)
+
+	emitter, err := observability.NewStatsDEmitter(&observability.StatsDConfig{
+		Address: listener.LocalAddr().String(),
+	})
+	require.NoError(t, err)
+	defer emitter.Close()
+
+	require.NoError(t, emitter.Emit(&types.Metric{
+		Name:  "tool_execution_duration",
+		Type:  types.MetricTypeHistogram,
+		Value: 42,
+		Tags:  map[string]string{"tool": "search|files"},
+	}))
+
+	buf := make([]byte, 512)
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(2*time.Second)))
+	n, err := listener.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "omnimesh.mcp.tool.duration:42|h|#tool:search_files", string(buf[:n]))
+}
+
+func TestStatsDConfigValidate(t *testing.T) {
+	assert.NoError(t, (&config.StatsDConfig{}).Validate(), "disabled config is not validated")
+
+	valid := config.StatsDConfig{Enabled: true, Address: "127.0.0.1:8125", Flavor: "dogstatsd", SampleRate: 1}
+	assert.NoError(t, valid.Validate())
+
+	badRate := valid
+	badRate.SampleRates = map[string]float64{"http_requests_total": 1.5}
+	assert.Error(t, badRate.Validate())
+
+	badFlavor := valid
+	badFlavor.Flavor = "graphite"
+	assert.Error(t, badFlavor.Validate())
+}
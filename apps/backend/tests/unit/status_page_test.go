@@ -0,0 +1,127 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverallStatus(t *testing.T) {
+	assert.Equal(t, types.ServiceStatusOperational, services.OverallStatus(map[string]bool{}, 2))
+	assert.Equal(t, types.ServiceStatusOperational, services.OverallStatus(map[string]bool{"a": true, "b": true}, 2))
+	assert.Equal(t, types.ServiceStatusDegraded, services.OverallStatus(map[string]bool{"a": true, "b": false}, 2))
+	assert.Equal(t, types.ServiceStatusDegraded, services.OverallStatus(map[string]bool{"a": false}, 2),
+		"servers without checks count as healthy")
+	assert.Equal(t, types.ServiceStatusDown, services.OverallStatus(map[string]bool{"a": false, "b": false}, 2))
+}
+
+func TestDetectIncidents(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	samples := []types.HealthSample{
+		{ServerID: "a", Healthy: true, CheckedAt: at(0)},
+		{ServerID: "b", Healthy: true, CheckedAt: at(0)},
+		// a fails alone: degraded
+		{ServerID: "a", Healthy: false, CheckedAt: at(10)},
+		{ServerID: "a", Healthy: true, CheckedAt: at(15)},
+		// both fail: outage, still ongoing
+		{ServerID: "b", Healthy: false, CheckedAt: at(60)},
+		{ServerID: "a", Healthy: false, CheckedAt: at(61)},
+	}
+
+	incidents := services.DetectIncidents(samples, 2)
+	require.Len(t, incidents, 2)
+
+	assert.Equal(t, at(60), incidents[0].StartedAt)
+	assert.Nil(t, incidents[0].EndedAt)
+	assert.Equal(t, types.ServiceStatusDown, incidents[0].Impact)
+	assert.Equal(t, types.IncidentStateOngoing, incidents[0].State)
+	assert.Equal(t, "Service outage", incidents[0].Title)
+
+	assert.Equal(t, at(10), incidents[1].StartedAt)
+	require.NotNil(t, incidents[1].EndedAt)
+	assert.Equal(t, at(15), *incidents[1].EndedAt)
+	assert.Equal(t, types.ServiceStatusDegraded, incidents[1].Impact)
+	assert.Equal(t, types.IncidentStateResolved, incidents[1].State)
+	assert.Equal(t, types.IncidentSourceDetected, incidents[1].Source)
+
+	assert.Empty(t, services.DetectIncidents(nil, 2))
+}
+
+type staticStatuses map[string]*types.PublicStatus
+
+func (s staticStatuses) GetStatus(ctx context.Context, endpointName string) (*types.PublicStatus, error) {
+	status, ok := s[endpointName]
+	if !ok {
+		return nil, types.NewNotFoundError("status page not found")
+	}
+	return status, nil
+}
+
+func TestStatusPageHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	percent := 99.5
+	statuses := staticStatuses{"shop": {
+		Endpoint:  "shop",
+		Status:    types.ServiceStatusDegraded,
+		Uptime:    []types.UptimeWindow{{Window: "24h", Percent: &percent}},
+		Incidents: []types.StatusIncident{},
+	}}
+	h := handlers.NewStatusPageHandler(statuses, types.StatusPageLimits{RequestsPerMinute: 2, CacheTTLSeconds: 30})
+	router := gin.New()
+	router.GET("/status/:endpoint_name", middleware.StatusPageRateLimit(2), h.GetStatus)
+
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/status/shop", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "public, max-age=30", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	var status types.PublicStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, types.ServiceStatusDegraded, status.Status)
+	require.Len(t, status.Uptime, 1)
+	assert.Equal(t, 99.5, *status.Uptime[0].Percent)
+
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	w = get("/status/shop", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = get("/status/missing", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "limit is per client IP across endpoints")
+}
+
+func TestStatusPageHandlerNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handlers.NewStatusPageHandler(staticStatuses{}, types.StatusPageLimits{CacheTTLSeconds: 30})
+	router := gin.New()
+	router.GET("/status/:endpoint_name", h.GetStatus)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status/private", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,261 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	teamOrgID      = "org-1"
+	teamUserID     = "8d9e0f1a-2b3c-4d4e-9f6a-7b8c9d0e1f2a"
+	teamOutsiderID = "9e0f1a2b-3c4d-4e5f-8a7b-8c9d0e1f2a3b"
+)
+
+// memoryTeams keeps teams and memberships in memory, mirroring TeamModel
+type memoryTeams struct {
+	teams   []*types.Team
+	members map[string]map[string]string // team ID -> user ID -> source
+}
+
+func newMemoryTeams() *memoryTeams {
+	return &memoryTeams{members: make(map[string]map[string]string)}
+}
+
+func (m *memoryTeams) ListTeams(orgID string) ([]*types.Team, error) {
+	teams := []*types.Team{}
+	for _, team := range m.teams {
+		if team.OrganizationID == orgID {
+			teams = append(teams, team)
+		}
+	}
+	return teams, nil
+}
+
+func (m *memoryTeams) ListUserTeams(userID string) ([]*types.Team, error) {
+	teams := []*types.Team{}
+	for _, team := range m.teams {
+		if _, ok := m.members[team.ID][userID]; ok {
+			teams = append(teams, team)
+		}
+	}
+	return teams, nil
+}
+
+func (m *memoryTeams) GetTeam(id string) (*types.Team, error) {
+	for _, team := range m.teams {
+		if team.ID == id {
+			return team, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryTeams) CreateTeam(team *types.Team) error {
+	team.ID = uuid.New().String()
+	team.CreatedAt = time.Now()
+	m.teams = append(m.teams, team)
+	return nil
+}
+
+func (m *memoryTeams) UpdateTeam(team *types.Team) error {
+	return nil
+}
+
+func (m *memoryTeams) DeleteTeam(id string) (bool, error) {
+	for i, team := range m.teams {
+		if team.ID == id {
+			m.teams = append(m.teams[:i], m.teams[i+1:]...)
+			delete(m.members, id)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryTeams) ListMembers(teamID string) ([]*types.TeamMember, error) {
+	members := []*types.TeamMember{}
+	for userID, source := range m.members[teamID] {
+		members = append(members, &types.TeamMember{TeamID: teamID, UserID: userID, Source: source})
+	}
+	return members, nil
+}
+
+func (m *memoryTeams) AddMember(teamID, userID, source string) error {
+	if m.members[teamID] == nil {
+		m.members[teamID] = make(map[string]string)
+	}
+	if existing, ok := m.members[teamID][userID]; ok && source != types.TeamMemberSourceManual {
+		source = existing
+	}
+	m.members[teamID][userID] = source
+	return nil
+}
+
+func (m *memoryTeams) RemoveMember(teamID, userID string) (bool, error) {
+	if _, ok := m.members[teamID][userID]; !ok {
+		return false, nil
+	}
+	delete(m.members[teamID], userID)
+	return true, nil
+}
+
+func (m *memoryTeams) RemoveSyncedMember(teamID, userID string) (bool, error) {
+	if m.members[teamID][userID] != types.TeamMemberSourceIdP {
+		return false, nil
+	}
+	delete(m.members[teamID], userID)
+	return true, nil
+}
+
+func (m *memoryTeams) UserOrganization(userID string) (string, error) {
+	switch userID {
+	case teamUserID:
+		return teamOrgID, nil
+	case teamOutsiderID:
+		return "org-2", nil
+	}
+	return "", nil
+}
+
+func createTeam(t *testing.T, svc *services.TeamService, req *types.CreateTeamRequest) *types.Team {
+	t.Helper()
+	team, err := svc.CreateTeam(context.Background(), teamOrgID, "admin-1", req)
+	require.NoError(t, err)
+	return team
+}
+
+func TestTeamCRUD(t *testing.T) {
+	svc := services.NewTeamServiceWithStore(newMemoryTeams())
+	ctx := context.Background()
+
+	platform := createTeam(t, svc, &types.CreateTeamRequest{Name: "Platform", Role: types.RoleUser, IdPGroup: "eng-platform"})
+
+	_, err := svc.CreateTeam(ctx, teamOrgID, "admin-1", &types.CreateTeamRequest{Name: "platform"})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict), "team names are unique per organization")
+	_, err = svc.CreateTeam(ctx, teamOrgID, "admin-1", &types.CreateTeamRequest{Name: "Infra", IdPGroup: "ENG-PLATFORM"})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict), "a group maps to one team")
+	_, err = svc.CreateTeam(ctx, "org-2", "admin-1", &types.CreateTeamRequest{Name: "Platform"})
+	assert.NoError(t, err, "other organizations may reuse the name")
+
+	_, err = svc.GetTeam(ctx, "org-2", platform.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	unknown := "owner"
+	_, err = svc.UpdateTeam(ctx, teamOrgID, platform.ID, &types.UpdateTeamRequest{Role: &unknown})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+	cleared := ""
+	updated, err := svc.UpdateTeam(ctx, teamOrgID, platform.ID, &types.UpdateTeamRequest{Role: &cleared})
+	require.NoError(t, err)
+	assert.Empty(t, updated.Role)
+
+	assert.True(t, types.IsError(svc.AddMember(ctx, teamOrgID, platform.ID, teamOutsiderID), types.ErrCodeValidationFailed))
+	require.NoError(t, svc.AddMember(ctx, teamOrgID, platform.ID, teamUserID))
+	members, err := svc.ListMembers(ctx, teamOrgID, platform.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, types.TeamMemberSourceManual, members[0].Source)
+
+	require.NoError(t, svc.RemoveMember(ctx, teamOrgID, platform.ID, teamUserID))
+	assert.True(t, types.IsError(svc.RemoveMember(ctx, teamOrgID, platform.ID, teamUserID), types.ErrCodeNotFound))
+
+	require.NoError(t, svc.DeleteTeam(ctx, teamOrgID, platform.ID))
+	assert.True(t, types.IsError(svc.DeleteTeam(ctx, teamOrgID, platform.ID), types.ErrCodeNotFound))
+}
+
+func TestTeamSyncGroups(t *testing.T) {
+	store := newMemoryTeams()
+	svc := services.NewTeamServiceWithStore(store)
+	ctx := context.Background()
+
+	platform := createTeam(t, svc, &types.CreateTeamRequest{Name: "Platform", IdPGroup: "eng-platform"})
+	data := createTeam(t, svc, &types.CreateTeamRequest{Name: "Data", IdPGroup: "eng-data"})
+	oncall := createTeam(t, svc, &types.CreateTeamRequest{Name: "On-call", IdPGroup: "oncall"})
+	unmapped := createTeam(t, svc, &types.CreateTeamRequest{Name: "Unmapped"})
+	require.NoError(t, svc.AddMember(ctx, teamOrgID, oncall.ID, teamUserID))
+
+	result, err := svc.SyncGroups(ctx, teamOrgID, teamUserID, []string{"ENG-Platform", "eng-data", "unrelated"})
+	require.NoError(t, err)
+	sort.Strings(result.Joined)
+	expected := []string{platform.ID, data.ID}
+	sort.Strings(expected)
+	assert.Equal(t, expected, result.Joined)
+	assert.Empty(t, result.Left, "manual memberships survive a sync")
+
+	result, err = svc.SyncGroups(ctx, teamOrgID, teamUserID, []string{"eng-data"})
+	require.NoError(t, err)
+	assert.Empty(t, result.Joined)
+	assert.Equal(t, []string{platform.ID}, result.Left)
+
+	teams, err := svc.ListUserTeams(ctx, teamUserID)
+	require.NoError(t, err)
+	names := []string{}
+	for _, team := range teams {
+		names = append(names, team.Name)
+	}
+	assert.ElementsMatch(t, []string{"Data", "On-call"}, names)
+	assert.NotContains(t, names, unmapped.Name)
+
+	_, err = svc.SyncGroups(ctx, teamOrgID, teamOutsiderID, []string{"eng-data"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+}
+
+// teamAuthService authenticates a single API key for one user
+type teamAuthService struct {
+	user *types.User
+}
+
+func (s *teamAuthService) GetUserByID(userID string) (*types.User, error) {
+	return s.user, nil
+}
+
+func (s *teamAuthService) ValidateAPIKey(apiKey string) (*types.APIKey, error) {
+	return &types.APIKey{ID: "key-1", UserID: s.user.ID}, nil
+}
+
+func TestTeamRoleElevatesAuthenticatedUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newMemoryTeams()
+	svc := services.NewTeamServiceWithStore(store)
+	ctx := context.Background()
+
+	viewer := &types.User{ID: teamUserID, OrganizationID: teamOrgID, Role: types.RoleViewer, IsActive: true}
+	m := auth.NewMiddlewareWithInterface(nil, &teamAuthService{user: viewer})
+	m.SetTeams(svc)
+
+	role := func() string {
+		var role string
+		router := gin.New()
+		router.GET("/", m.RequireAPIKey(), func(c *gin.Context) {
+			role = c.GetString("role")
+			c.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "key")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		return role
+	}
+
+	assert.Equal(t, types.RoleViewer, role())
+
+	readers := createTeam(t, svc, &types.CreateTeamRequest{Name: "Readers", Role: types.RoleViewer})
+	builders := createTeam(t, svc, &types.CreateTeamRequest{Name: "Builders", Role: types.RoleUser})
+	require.NoError(t, svc.AddMember(ctx, teamOrgID, readers.ID, teamUserID))
+	require.NoError(t, svc.AddMember(ctx, teamOrgID, builders.ID, teamUserID))
+	assert.Equal(t, types.RoleUser, role(), "members act with the highest team role")
+
+	viewer.Role = types.RoleAdmin
+	assert.Equal(t, types.RoleAdmin, role(), "team roles never lower a user's own role")
+}
@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/telemetry"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticUsageSource types.TelemetryUsage
+
+func (s staticUsageSource) CollectUsage(ctx context.Context, since time.Time) (*types.TelemetryUsage, error) {
+	usage := types.TelemetryUsage(s)
+	return &usage, nil
+}
+
+var telemetryUsage = staticUsageSource{
+	Servers:            3,
+	ServersByProtocol:  map[string]int{"stdio": 2, "sse": 1},
+	SessionsByProtocol: map[string]int{"stdio": 40},
+	UserRange:          telemetry.UserRange(7),
+	Endpoints:          1,
+}
+
+func TestTelemetrySendsAggregateReport(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+	var received map[string]interface{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer collector.Close()
+
+	reporter := telemetry.NewReporter(telemetryUsage, true, collector.URL, time.Hour, nil)
+	require.True(t, reporter.Enabled())
+	require.NoError(t, reporter.Send(context.Background()))
+
+	assert.Equal(t, float64(3), received["servers"])
+	assert.Equal(t, "1-10", received["user_range"])
+	assert.Equal(t, map[string]interface{}{"stdio": float64(2), "sse": float64(1)}, received["servers_by_protocol"])
+	assert.NotEmpty(t, received["version"])
+}
+
+func TestTelemetryOptOut(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+	disabled := telemetry.NewReporter(telemetryUsage, false, "http://collector.local", time.Hour, nil)
+	assert.False(t, disabled.Enabled())
+	assert.NoError(t, disabled.Send(context.Background()))
+
+	noEndpoint := telemetry.NewReporter(telemetryUsage, true, "", time.Hour, nil)
+	assert.False(t, noEndpoint.Enabled())
+
+	t.Setenv("DO_NOT_TRACK", "1")
+	dnt := telemetry.NewReporter(telemetryUsage, true, "http://collector.local", time.Hour, nil)
+	assert.False(t, dnt.Enabled())
+	assert.Contains(t, dnt.DisabledReason(), "DO_NOT_TRACK")
+
+	env := map[string]string{"DO_NOT_TRACK": "false"}
+	assert.False(t, telemetry.DoNotTrack(func(k string) string { return env[k] }))
+}
+
+func TestTelemetryRespectsOfflineMode(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+	offline, err := airgap.New(true, nil, nil)
+	require.NoError(t, err)
+	reporter := telemetry.NewReporter(telemetryUsage, true, "https://telemetry.example.com", time.Hour, offline)
+	assert.False(t, reporter.Enabled())
+	assert.Contains(t, reporter.DisabledReason(), "offline")
+
+	mirrored, err := airgap.New(true, map[string]string{types.ConnectivityFeatureTelemetry: "http://collector.internal/v1"}, nil)
+	require.NoError(t, err)
+	reporter = telemetry.NewReporter(telemetryUsage, true, "https://telemetry.example.com", time.Hour, mirrored)
+	assert.True(t, reporter.Enabled())
+}
+
+func TestTelemetryUserRange(t *testing.T) {
+	assert.Equal(t, "0", telemetry.UserRange(0))
+	assert.Equal(t, "1-10", telemetry.UserRange(10))
+	assert.Equal(t, "11-50", telemetry.UserRange(11))
+	assert.Equal(t, "1000+", telemetry.UserRange(5000))
+}
+
+func TestTelemetryHandlerPreviewsReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("DO_NOT_TRACK", "")
+	reporter := telemetry.NewReporter(telemetryUsage, false, "", 24*time.Hour, nil)
+	router := gin.New()
+	router.GET("/api/admin/telemetry", handlers.NewTelemetryHandler(reporter).GetStatus)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/telemetry", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data types.TelemetryStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.False(t, body.Data.Enabled)
+	assert.NotEmpty(t, body.Data.Reason)
+	require.NotNil(t, body.Data.Report)
+	assert.Equal(t, 3, body.Data.Report.Servers)
+	assert.Equal(t, "168h0m0s", body.Data.Report.SessionWindow)
+}
@@ -0,0 +1,121 @@
+package unit
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// verifyWithJWKS verifies token offline against the published key set, as
+// a downstream MCP server would
+func verifyWithJWKS(jwks *auth.JWKS, token string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		for _, key := range jwks.Keys {
+			if key.KeyID == token.Header["kid"] {
+				return key.PublicKey()
+			}
+		}
+		return nil, jwt.ErrTokenUnverifiable
+	}, jwt.WithValidMethods([]string{auth.SigningAlgorithmRS256, auth.SigningAlgorithmES256}))
+	return claims, err
+}
+
+func TestOAuthService_SignsWithPublishedKeys(t *testing.T) {
+	oldKey, err := auth.GenerateSigningKey(auth.SigningAlgorithmES256)
+	require.NoError(t, err)
+	keys, err := auth.NewKeySet("", []*auth.SigningKey{oldKey})
+	require.NoError(t, err)
+
+	oldToken, err := keys.Sign(jwt.MapClaims{"sub": "client-1"})
+	require.NoError(t, err)
+
+	// Rotate to a new key while still publishing the old one
+	newKey, err := auth.GenerateSigningKey(auth.SigningAlgorithmES256)
+	require.NoError(t, err)
+	keys, err = auth.NewKeySet(newKey.ID, []*auth.SigningKey{oldKey, newKey})
+	require.NoError(t, err)
+
+	service := auth.NewOAuthService(nil, "test-jwt-secret", discoveryBaseURL, auth.DefaultOAuthConfig())
+	service.SetSigningKeys(keys)
+	jwks, err := service.GetJWKS()
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, newKey.ID, jwks.Keys[0].KeyID)
+	for _, key := range jwks.Keys {
+		assert.Equal(t, "EC", key.KeyType)
+		assert.Equal(t, auth.SigningAlgorithmES256, key.Algorithm)
+		assert.Empty(t, key.D)
+	}
+
+	newToken, err := keys.Sign(jwt.MapClaims{"sub": "client-2"})
+	require.NoError(t, err)
+
+	claims, err := verifyWithJWKS(jwks, newToken)
+	require.NoError(t, err)
+	assert.Equal(t, "client-2", claims["sub"])
+
+	// Tokens signed before the rotation still verify
+	claims, err = verifyWithJWKS(jwks, oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, "client-1", claims["sub"])
+
+	// Once the old key is removed they no longer do
+	keys, err = auth.NewKeySet("", []*auth.SigningKey{newKey})
+	require.NoError(t, err)
+	_, err = verifyWithJWKS(keys.JWKS(), oldToken)
+	assert.Error(t, err)
+}
+
+func TestParseSigningKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaDER, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	require.NoError(t, err)
+	rsaPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rsaDER})
+
+	key, err := auth.ParseSigningKey("2026-10", auth.SigningAlgorithmRS256, rsaPEM)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10", key.ID)
+
+	keys, err := auth.NewKeySet("", []*auth.SigningKey{key})
+	require.NoError(t, err)
+	token, err := keys.Sign(jwt.MapClaims{"sub": "client-1"})
+	require.NoError(t, err)
+
+	parsed, err := jwt.Parse(token, keys.Keyfunc)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10", parsed.Header["kid"])
+
+	// The published key verifies the token and names the key by thumbprint
+	// when no id is given
+	_, err = verifyWithJWKS(keys.JWKS(), token)
+	require.NoError(t, err)
+	unnamed, err := auth.ParseSigningKey("", auth.SigningAlgorithmRS256, rsaPEM)
+	require.NoError(t, err)
+	assert.Equal(t, unnamed.PublicJWK().Thumbprint(), unnamed.ID)
+
+	// Keys must suit the algorithm
+	_, err = auth.ParseSigningKey("rsa", auth.SigningAlgorithmES256, rsaPEM)
+	assert.Error(t, err)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	p384DER, err := x509.MarshalECPrivateKey(p384Key)
+	require.NoError(t, err)
+	_, err = auth.ParseSigningKey("p384", auth.SigningAlgorithmES256, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: p384DER}))
+	assert.Error(t, err)
+
+	_, err = auth.NewKeySet("missing", []*auth.SigningKey{key})
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeToolArgumentsAppliesDefaultsAndLocks(t *testing.T) {
+	template := &types.ToolArgumentTemplate{
+		Defaults:   map[string]interface{}{"limit": 20, "state": "open"},
+		Locked:     map[string]interface{}{"project_id": "proj-1"},
+		OnConflict: types.ArgumentConflictReject,
+	}
+
+	merged, err := services.MergeToolArguments(template, map[string]interface{}{"state": "closed", "query": "bug"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"limit":      20,
+		"state":      "closed",
+		"query":      "bug",
+		"project_id": "proj-1",
+	}, merged)
+
+	// Passing the locked value itself is not a conflict
+	_, err = services.MergeToolArguments(template, map[string]interface{}{"project_id": "proj-1"})
+	require.NoError(t, err)
+
+	_, err = services.MergeToolArguments(template, map[string]interface{}{"project_id": "proj-2"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "project_id")
+}
+
+func TestMergeToolArgumentsOverrideConflicts(t *testing.T) {
+	template := &types.ToolArgumentTemplate{
+		Locked:     map[string]interface{}{"max_results": float64(5)},
+		OnConflict: types.ArgumentConflictOverride,
+	}
+
+	merged, err := services.MergeToolArguments(template, map[string]interface{}{"max_results": 500})
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), merged["max_results"])
+
+	// Numbers compare by value regardless of their Go type
+	template.OnConflict = types.ArgumentConflictReject
+	_, err = services.MergeToolArguments(template, map[string]interface{}{"max_results": 5})
+	require.NoError(t, err)
+}
+
+func TestMergeToolArgumentsWithoutTemplate(t *testing.T) {
+	args := map[string]interface{}{"a": 1}
+	merged, err := services.MergeToolArguments(nil, args)
+	require.NoError(t, err)
+	assert.Equal(t, args, merged)
+
+	merged, err = services.MergeToolArguments(&types.ToolArgumentTemplate{Locked: map[string]interface{}{"a": 2}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": 2}, merged)
+}
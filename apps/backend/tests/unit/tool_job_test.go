@@ -0,0 +1,336 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryToolJobs keeps tool jobs in memory; claims ignore leases since the
+// tests run one worker
+type memoryToolJobs struct {
+	now      func() time.Time
+	jobs     map[string]*types.ToolJob
+	settings map[string]*types.ToolJobSettings
+	// serverTools maps server ID and tool name to tool IDs
+	serverTools map[string]string
+}
+
+func newMemoryToolJobs(now func() time.Time) *memoryToolJobs {
+	return &memoryToolJobs{
+		now:         now,
+		jobs:        map[string]*types.ToolJob{},
+		settings:    map[string]*types.ToolJobSettings{},
+		serverTools: map[string]string{},
+	}
+}
+
+func (m *memoryToolJobs) Create(job *types.ToolJob) error {
+	job.ID = uuid.New().String()
+	job.Status = types.ToolJobStatusQueued
+	job.CreatedAt = m.now()
+	job.RunAfter = m.now()
+	stored := *job
+	m.jobs[job.ID] = &stored
+	return nil
+}
+
+func (m *memoryToolJobs) Get(orgID, id string) (*types.ToolJob, error) {
+	job, ok := m.jobs[id]
+	if !ok || job.OrganizationID != orgID {
+		return nil, nil
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (m *memoryToolJobs) List(orgID, createdBy, status string, limit int) ([]*types.ToolJob, error) {
+	jobs := []*types.ToolJob{}
+	for _, job := range m.jobs {
+		if job.OrganizationID != orgID || (status != "" && job.Status != status) {
+			continue
+		}
+		if createdBy != "" && (job.CreatedBy == nil || *job.CreatedBy != createdBy) {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs[:min(limit, len(jobs))], nil
+}
+
+func (m *memoryToolJobs) Cancel(orgID, id string) (bool, error) {
+	job, ok := m.jobs[id]
+	if !ok || job.OrganizationID != orgID || job.Status != types.ToolJobStatusQueued {
+		return false, nil
+	}
+	job.Status = types.ToolJobStatusCancelled
+	return true, nil
+}
+
+func (m *memoryToolJobs) Claim(worker string) (*types.ToolJob, error) {
+	for _, job := range m.jobs {
+		if job.Status == types.ToolJobStatusQueued && !job.RunAfter.After(m.now()) {
+			job.Status = types.ToolJobStatusRunning
+			job.Attempts++
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryToolJobs) Finish(id, worker, status string, result *types.NamespaceToolResult, jobErr *types.Error) (bool, error) {
+	job := m.jobs[id]
+	job.Status, job.Result, job.Error = status, result, jobErr
+	return true, nil
+}
+
+func (m *memoryToolJobs) Retry(id, worker string, runAfter time.Time, result *types.NamespaceToolResult, jobErr *types.Error) (bool, error) {
+	job := m.jobs[id]
+	job.Status, job.RunAfter, job.Result, job.Error = types.ToolJobStatusQueued, runAfter, result, jobErr
+	return true, nil
+}
+
+func (m *memoryToolJobs) Release(id, worker string) error {
+	job := m.jobs[id]
+	job.Status = types.ToolJobStatusQueued
+	job.Attempts--
+	return nil
+}
+
+func (m *memoryToolJobs) Prune(before time.Time) (int64, error) { return 0, nil }
+
+func (m *memoryToolJobs) ToolExists(orgID, toolID string) (bool, error) {
+	for _, id := range m.serverTools {
+		if id == toolID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryToolJobs) GetSettings(toolID string) (*types.ToolJobSettings, error) {
+	return m.settings[toolID], nil
+}
+
+func (m *memoryToolJobs) GetSettingsForServerTool(serverID, name string) (*types.ToolJobSettings, error) {
+	return m.settings[m.serverTools[serverID+"/"+name]], nil
+}
+
+func (m *memoryToolJobs) UpsertSettings(settings *types.ToolJobSettings) error {
+	m.settings[settings.ToolID] = settings
+	return nil
+}
+
+func (m *memoryToolJobs) DeleteSettings(toolID string) (bool, error) {
+	_, ok := m.settings[toolID]
+	delete(m.settings, toolID)
+	return ok, nil
+}
+
+// namespaceTools resolves tools of the github server of every namespace
+type namespaceTools map[string]string
+
+func (n namespaceTools) ToolServerID(ctx context.Context, namespaceID, tool string) (string, error) {
+	server, _, _ := services.ParsePrefixedToolName(tool)
+	return n[server], nil
+}
+
+// scriptedExecutor answers tool calls with its results in turn
+type scriptedExecutor struct {
+	results []func(ctx context.Context) (*types.NamespaceToolResult, error)
+	calls   []types.ExecuteNamespaceToolRequest
+	callers []*types.Principal
+}
+
+func (e *scriptedExecutor) ExecuteTool(ctx context.Context, namespaceID string, req types.ExecuteNamespaceToolRequest) (*types.NamespaceToolResult, error) {
+	e.calls = append(e.calls, req)
+	e.callers = append(e.callers, types.PrincipalFromContext(ctx))
+	next := e.results[0]
+	e.results = e.results[1:]
+	return next(ctx)
+}
+
+func newToolJobFixture(t *testing.T) (*services.ToolJobService, *memoryToolJobs, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store := newMemoryToolJobs(clock)
+	store.serverTools["server-1/search"] = uuid.New().String()
+	svc := services.NewToolJobServiceWithStore(store, namespaceTools{"github": "server-1"})
+	svc.SetClock(clock)
+	return svc, store, &now
+}
+
+func TestToolJobEnqueueUsesToolSettings(t *testing.T) {
+	svc, store, _ := newToolJobFixture(t)
+	ctx := context.Background()
+	toolID := store.serverTools["server-1/search"]
+
+	job, err := svc.Enqueue(ctx, "org-1", "ns-1", "user-1", types.ExecuteNamespaceToolRequest{Tool: "github__search"})
+	require.NoError(t, err)
+	assert.Equal(t, types.DefaultToolJobMaxAttempts, job.MaxAttempts)
+	assert.Equal(t, int(types.DefaultToolJobTimeout.Seconds()), job.TimeoutSeconds)
+	assert.NotEmpty(t, job.IdempotencyKey)
+
+	settings, err := svc.GetSettings(ctx, "org-1", toolID)
+	require.NoError(t, err)
+	assert.True(t, settings.Default)
+
+	_, err = svc.SetSettings(ctx, "org-1", toolID, &types.SetToolJobSettingsRequest{MaxAttempts: 5, TimeoutSeconds: 3600, RetryBackoffSeconds: 10})
+	require.NoError(t, err)
+	job, err = svc.Enqueue(ctx, "org-1", "ns-1", "user-1", types.ExecuteNamespaceToolRequest{
+		Tool: "github__search", IdempotencyKey: "order-7",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, job.MaxAttempts)
+	assert.Equal(t, 3600, job.TimeoutSeconds)
+	assert.Equal(t, "order-7", job.IdempotencyKey)
+
+	_, err = svc.Enqueue(ctx, "org-1", "ns-1", "user-1", types.ExecuteNamespaceToolRequest{Tool: "gitlab__search"})
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	scoped := types.WithPrincipal(ctx, &types.Principal{Scope: &types.APIKeyScope{Tools: []string{"*__read_*"}}})
+	_, err = svc.Enqueue(scoped, "org-1", "ns-1", "user-1", types.ExecuteNamespaceToolRequest{Tool: "github__search"})
+	assert.True(t, types.IsError(err, types.ErrCodeAccessDenied))
+
+	_, err = svc.GetSettings(ctx, "org-1", uuid.New().String())
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+}
+
+func TestToolJobRetriesWithBackoffUntilAttemptsRunOut(t *testing.T) {
+	svc, store, now := newToolJobFixture(t)
+	ctx := context.Background()
+	failed := func(ctx context.Context) (*types.NamespaceToolResult, error) {
+		return &types.NamespaceToolResult{Success: false, Error: "upstream unavailable"}, nil
+	}
+	executor := &scriptedExecutor{results: []func(context.Context) (*types.NamespaceToolResult, error){failed, failed, failed}}
+	svc.SetExecutor(executor)
+
+	caller := &types.Principal{Type: "user", UserID: "user-1"}
+	job, err := svc.Enqueue(types.WithPrincipal(ctx, caller), "org-1", "ns-1", "user-1",
+		types.ExecuteNamespaceToolRequest{Tool: "github__search"})
+	require.NoError(t, err)
+
+	ran, err := svc.RunNext(ctx)
+	require.NoError(t, err)
+	assert.True(t, ran)
+	assert.Equal(t, types.ToolJobStatusQueued, store.jobs[job.ID].Status)
+	assert.Equal(t, now.Add(types.DefaultToolJobRetryBackoff), store.jobs[job.ID].RunAfter)
+	assert.Equal(t, types.ErrCodeBadGateway, store.jobs[job.ID].Error.Code)
+
+	// Nothing is due until the backoff passes
+	ran, err = svc.RunNext(ctx)
+	require.NoError(t, err)
+	assert.False(t, ran)
+
+	*now = now.Add(types.DefaultToolJobRetryBackoff)
+	_, err = svc.RunNext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(2*types.DefaultToolJobRetryBackoff), store.jobs[job.ID].RunAfter)
+
+	*now = now.Add(2 * types.DefaultToolJobRetryBackoff)
+	_, err = svc.RunNext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, types.ToolJobStatusFailed, store.jobs[job.ID].Status)
+
+	require.Len(t, executor.calls, 3)
+	for i := range executor.calls {
+		assert.Equal(t, job.IdempotencyKey, executor.calls[i].IdempotencyKey)
+		assert.Same(t, caller, executor.callers[i])
+	}
+}
+
+func TestToolJobFailsAtOnceWhenGatewayRefusesCall(t *testing.T) {
+	svc, store, _ := newToolJobFixture(t)
+	ctx := context.Background()
+	svc.SetExecutor(&scriptedExecutor{results: []func(context.Context) (*types.NamespaceToolResult, error){
+		func(ctx context.Context) (*types.NamespaceToolResult, error) {
+			return nil, types.NewForbiddenError("Tool call denied by policy")
+		},
+	}})
+
+	job, err := svc.Enqueue(ctx, "org-1", "ns-1", "user-1", types.ExecuteNamespaceToolRequest{Tool: "github__search"})
+	require.NoError(t, err)
+	_, err = svc.RunNext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, types.ToolJobStatusFailed, store.jobs[job.ID].Status)
+	assert.Equal(t, types.ErrCodeAccessDenied, store.jobs[job.ID].Error.Code)
+}
+
+func TestToolJobAttemptTimesOut(t *testing.T) {
+	svc, store, _ := newToolJobFixture(t)
+	ctx := context.Background()
+	toolID := store.serverTools["server-1/search"]
+	_, err := svc.SetSettings(ctx, "org-1", toolID, &types.SetToolJobSettingsRequest{MaxAttempts: 1, TimeoutSeconds: 1})
+	require.NoError(t, err)
+	svc.SetExecutor(&scriptedExecutor{results: []func(context.Context) (*types.NamespaceToolResult, error){
+		func(ctx context.Context) (*types.NamespaceToolResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}})
+
+	job, err := svc.Enqueue(ctx, "org-1", "ns-1", "user-1", types.ExecuteNamespaceToolRequest{Tool: "github__search"})
+	require.NoError(t, err)
+	_, err = svc.RunNext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, types.ToolJobStatusFailed, store.jobs[job.ID].Status)
+	assert.Equal(t, types.ErrCodeTimeout, store.jobs[job.ID].Error.Code)
+}
+
+func TestToolJobReleasedWhenWorkerStops(t *testing.T) {
+	svc, store, _ := newToolJobFixture(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	svc.SetExecutor(&scriptedExecutor{results: []func(context.Context) (*types.NamespaceToolResult, error){
+		func(ctx context.Context) (*types.NamespaceToolResult, error) {
+			cancel()
+			return nil, errors.New("connection closed")
+		},
+	}})
+
+	job, err := svc.Enqueue(context.Background(), "org-1", "ns-1", "user-1", types.ExecuteNamespaceToolRequest{Tool: "github__search"})
+	require.NoError(t, err)
+	_, err = svc.RunNext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, types.ToolJobStatusQueued, store.jobs[job.ID].Status)
+	assert.Equal(t, 0, store.jobs[job.ID].Attempts)
+}
+
+func TestToolJobsArePrivateToTheirCreator(t *testing.T) {
+	svc, store, _ := newToolJobFixture(t)
+	ctx := context.Background()
+
+	job, err := svc.Enqueue(ctx, "org-1", "ns-1", "user-1", types.ExecuteNamespaceToolRequest{Tool: "github__search"})
+	require.NoError(t, err)
+
+	_, err = svc.Get(ctx, "org-1", "user-2", false, job.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+	_, err = svc.Get(ctx, "org-1", "admin", true, job.ID)
+	assert.NoError(t, err)
+	jobs, err := svc.List(ctx, "org-1", "user-2", false, "", 0)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	_, err = svc.List(ctx, "org-1", "user-1", false, "done", 0)
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	store.jobs[job.ID].Status = types.ToolJobStatusRunning
+	_, err = svc.Cancel(ctx, "org-1", "user-1", false, job.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+
+	store.jobs[job.ID].Status = types.ToolJobStatusQueued
+	cancelled, err := svc.Cancel(ctx, "org-1", "user-1", false, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.ToolJobStatusCancelled, cancelled.Status)
+}
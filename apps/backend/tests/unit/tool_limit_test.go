@@ -0,0 +1,155 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/ratelimit"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryToolLimits keeps the limits of the tool "tool-1", exposed by server
+// "srv-1" as "search", and counts budget usage per day
+type memoryToolLimits struct {
+	limits map[string]*types.ToolLimits
+	usage  map[string]int64
+	pruned []time.Time
+}
+
+func newMemoryToolLimits() *memoryToolLimits {
+	return &memoryToolLimits{limits: map[string]*types.ToolLimits{}, usage: map[string]int64{}}
+}
+
+func (m *memoryToolLimits) ToolExists(orgID, toolID string) (bool, error) {
+	return orgID == "org-1" && toolID == "tool-1", nil
+}
+
+func (m *memoryToolLimits) Get(toolID string) (*types.ToolLimits, error) {
+	return m.limits[toolID], nil
+}
+
+func (m *memoryToolLimits) GetForServerTool(serverID, name string) (*types.ToolLimits, error) {
+	if serverID != "srv-1" || name != "search" {
+		return nil, nil
+	}
+	return m.limits["tool-1"], nil
+}
+
+func (m *memoryToolLimits) ListForTools(toolIDs []string) (map[string]*types.ToolLimits, error) {
+	limits := map[string]*types.ToolLimits{}
+	for _, id := range toolIDs {
+		if l, ok := m.limits[id]; ok {
+			limits[id] = l
+		}
+	}
+	return limits, nil
+}
+
+func (m *memoryToolLimits) Upsert(limits *types.ToolLimits) error {
+	m.limits[limits.ToolID] = limits
+	return nil
+}
+
+func (m *memoryToolLimits) Delete(toolID string) (bool, error) {
+	_, ok := m.limits[toolID]
+	delete(m.limits, toolID)
+	return ok, nil
+}
+
+func (m *memoryToolLimits) ConsumeBudget(toolID string, day time.Time, budget int64) (bool, error) {
+	key := toolID + "@" + day.Format(time.DateOnly)
+	if m.usage[key] >= budget {
+		return false, nil
+	}
+	m.usage[key]++
+	return true, nil
+}
+
+func (m *memoryToolLimits) Usage(toolIDs []string, day time.Time) (map[string]int64, error) {
+	usage := map[string]int64{}
+	for _, id := range toolIDs {
+		usage[id] = m.usage[id+"@"+day.Format(time.DateOnly)]
+	}
+	return usage, nil
+}
+
+func (m *memoryToolLimits) PruneUsage(before time.Time) error {
+	m.pruned = append(m.pruned, before)
+	return nil
+}
+
+func TestToolLimitsSettings(t *testing.T) {
+	store := newMemoryToolLimits()
+	svc := services.NewToolLimitServiceWithStore(store, ratelimit.NewMemoryLimiter())
+	ctx := context.Background()
+
+	status, err := svc.GetLimits(ctx, "org-1", "tool-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), status.Remaining, "tools without a budget report no remaining count")
+
+	_, err = svc.GetLimits(ctx, "org-2", "tool-1")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	_, err = svc.SetLimits(ctx, "org-1", "tool-1", &types.SetToolLimitsRequest{})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	status, err = svc.SetLimits(ctx, "org-1", "tool-1", &types.SetToolLimitsRequest{RateLimit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 60, status.RateWindowSeconds, "rate limits default to a one-minute window")
+
+	require.NoError(t, svc.DeleteLimits(ctx, "org-1", "tool-1"))
+	err = svc.DeleteLimits(ctx, "org-1", "tool-1")
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+}
+
+func TestToolLimitsDailyBudget(t *testing.T) {
+	store := newMemoryToolLimits()
+	svc := services.NewToolLimitServiceWithStore(store, ratelimit.NewMemoryLimiter())
+	now := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	svc.SetClock(func() time.Time { return now })
+	ctx := context.Background()
+
+	_, err := svc.SetLimits(ctx, "org-1", "tool-1", &types.SetToolLimitsRequest{DailyBudget: 2})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.CheckToolLimits(ctx, "srv-1", "search", "web__search"))
+	require.NoError(t, svc.CheckToolLimits(ctx, "srv-1", "search", "web__search"))
+	err = svc.CheckToolLimits(ctx, "srv-1", "search", "web__search")
+	require.True(t, types.IsError(err, types.ErrCodeToolBudgetExceeded))
+	assert.Contains(t, err.Error(), "daily budget of 2 calls of tool web__search")
+	assert.Contains(t, err.Error(), "resets at 2026-10-19T00:00:00Z")
+
+	assert.NoError(t, svc.CheckToolLimits(ctx, "srv-2", "search", "other__search"), "other tools are not limited")
+
+	statuses, err := svc.UsageForTools(ctx, []string{"tool-1", "tool-2"})
+	require.NoError(t, err)
+	require.Contains(t, statuses, "tool-1")
+	assert.NotContains(t, statuses, "tool-2")
+	assert.Equal(t, int64(2), statuses["tool-1"].CallsToday)
+	assert.Equal(t, int64(0), statuses["tool-1"].Remaining)
+
+	now = now.Add(2 * time.Hour)
+	assert.NoError(t, svc.CheckToolLimits(ctx, "srv-1", "search", "web__search"), "budgets are replenished each UTC day")
+	assert.Len(t, store.pruned, 2, "old usage is pruned once a day")
+}
+
+func TestToolLimitsRateLimit(t *testing.T) {
+	store := newMemoryToolLimits()
+	limiter := ratelimit.NewMemoryLimiter()
+	svc := services.NewToolLimitServiceWithStore(store, limiter)
+	ctx := context.Background()
+
+	_, err := svc.SetLimits(ctx, "org-1", "tool-1", &types.SetToolLimitsRequest{RateLimit: 2, RateWindowSeconds: 60})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.CheckToolLimits(ctx, "srv-1", "search", "web__search"))
+	require.NoError(t, svc.CheckToolLimits(ctx, "srv-1", "search", "web__search"))
+	err = svc.CheckToolLimits(ctx, "srv-1", "search", "web__search")
+	require.True(t, types.IsError(err, types.ErrCodeRateLimitExceeded))
+	assert.Contains(t, err.Error(), "Tool web__search allows 2 calls per 1m0s")
+}
@@ -0,0 +1,223 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/jsonpath"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/toolpolicy"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func toolRule(t *testing.T, name string, priority int, effect string, conditions map[string]interface{}) *toolpolicy.Rule {
+	t.Helper()
+	rule, err := toolpolicy.Compile(&types.Policy{
+		ID:         name + "-id",
+		Name:       name,
+		Type:       types.PolicyTypeTool,
+		Priority:   priority,
+		Conditions: conditions,
+		Actions:    map[string]interface{}{"effect": effect},
+	})
+	require.NoError(t, err)
+	return rule
+}
+
+// memoryToolPolicies counts how often the active policies are loaded
+type memoryToolPolicies struct {
+	policies []*types.Policy
+	loads    int
+}
+
+func (m *memoryToolPolicies) ListActive(orgID string) ([]*types.Policy, error) {
+	m.loads++
+	return m.policies, nil
+}
+
+func TestToolPolicyDeniesMatchingTool(t *testing.T) {
+	rules := []*toolpolicy.Rule{
+		toolRule(t, "block-shell", 100, types.ToolPolicyEffectDeny, map[string]interface{}{
+			"namespaces": []interface{}{"ns-1"},
+			"tools":      []interface{}{"exec", "*__run_*"},
+		}),
+	}
+
+	decision := toolpolicy.Evaluate(rules, &toolpolicy.Call{NamespaceID: "ns-1", ServerName: "shell", Tool: "shell__exec"})
+	assert.Equal(t, types.ToolPolicyEffectDeny, decision.Effect)
+	assert.Equal(t, "block-shell-id", decision.PolicyID)
+
+	decision = toolpolicy.Evaluate(rules, &toolpolicy.Call{NamespaceID: "ns-1", Tool: "ci__run_pipeline"})
+	assert.Equal(t, types.ToolPolicyEffectDeny, decision.Effect)
+
+	// Other namespaces and tools are allowed by default
+	decision = toolpolicy.Evaluate(rules, &toolpolicy.Call{NamespaceID: "ns-2", Tool: "shell__exec"})
+	assert.Equal(t, types.ToolPolicyEffectAllow, decision.Effect)
+	assert.Empty(t, decision.PolicyID)
+	require.Len(t, decision.Trace, 1)
+	assert.Equal(t, "namespace does not match", decision.Trace[0].Reason)
+}
+
+func TestToolPolicyPriorityAndTies(t *testing.T) {
+	conditions := map[string]interface{}{"servers": []interface{}{"shell"}}
+	rules := []*toolpolicy.Rule{
+		toolRule(t, "deny-shell", 100, types.ToolPolicyEffectDeny, conditions),
+		toolRule(t, "allow-ops", 500, types.ToolPolicyEffectAllow, map[string]interface{}{
+			"servers": []interface{}{"shell"},
+			"callers": map[string]interface{}{"labels": map[string]interface{}{"team": "ops"}},
+		}),
+		toolRule(t, "allow-shell", 100, types.ToolPolicyEffectAllow, conditions),
+	}
+	call := &toolpolicy.Call{ServerName: "shell", Tool: "shell__exec", Principal: &types.Principal{
+		Type: types.PrincipalTypeAPIKey, APIKeyID: "key-1", Labels: types.Labels{"team": "ops"},
+	}}
+
+	// The higher-priority allow for the ops team decides first
+	decision := toolpolicy.Evaluate(rules, call)
+	assert.Equal(t, "allow-ops", decision.PolicyName)
+	assert.Len(t, decision.Trace, 3)
+
+	// Otherwise deny wins over allow at the same priority
+	call.Principal.Labels = types.Labels{"team": "dev"}
+	decision = toolpolicy.Evaluate(rules, call)
+	assert.Equal(t, types.ToolPolicyEffectDeny, decision.Effect)
+	assert.Equal(t, "deny-shell", decision.PolicyName)
+	assert.Equal(t, "caller does not match", decision.Trace[0].Reason)
+}
+
+func TestToolPolicyArgumentsAndCallers(t *testing.T) {
+	rules := []*toolpolicy.Rule{
+		toolRule(t, "review-rm", 100, types.ToolPolicyEffectRequireApproval, map[string]interface{}{
+			"arguments": []interface{}{map[string]interface{}{"path": "$.command", "pattern": `\brm\s+-rf\b`}},
+			"callers":   map[string]interface{}{"types": []interface{}{types.PrincipalTypeAPIKey}, "user_ids": []interface{}{"user-1"}},
+		}),
+		toolRule(t, "deny-prod-hosts", 100, types.ToolPolicyEffectDeny, map[string]interface{}{
+			"arguments": []interface{}{map[string]interface{}{"path": "$..host", "pattern": `^prod-`}},
+		}),
+	}
+
+	decision := toolpolicy.Evaluate(rules, &toolpolicy.Call{
+		Tool:      "shell__exec",
+		Arguments: map[string]interface{}{"command": "rm -rf /tmp/cache"},
+		Principal: &types.Principal{Type: types.PrincipalTypeUser, UserID: "user-1"},
+	})
+	assert.Equal(t, types.ToolPolicyEffectRequireApproval, decision.Effect)
+
+	decision = toolpolicy.Evaluate(rules, &toolpolicy.Call{
+		Tool:      "shell__exec",
+		Arguments: map[string]interface{}{"command": "rm -rf /tmp/cache"},
+		Principal: &types.Principal{Type: types.PrincipalTypeUser, UserID: "user-2"},
+	})
+	assert.Equal(t, types.ToolPolicyEffectAllow, decision.Effect)
+
+	decision = toolpolicy.Evaluate(rules, &toolpolicy.Call{
+		Tool: "ssh__connect",
+		Arguments: map[string]interface{}{
+			"targets": []interface{}{map[string]interface{}{"host": "dev-1"}, map[string]interface{}{"host": "prod-db"}},
+		},
+	})
+	assert.Equal(t, types.ToolPolicyEffectDeny, decision.Effect)
+}
+
+func TestToolPolicyValidation(t *testing.T) {
+	assert.NoError(t, toolpolicy.Validate(map[string]interface{}{"tools": []interface{}{"shell__*"}},
+		map[string]interface{}{"effect": "deny", "message": "Shell access is disabled"}))
+
+	err := toolpolicy.Validate(map[string]interface{}{}, map[string]interface{}{"effect": "block"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "effect must be one of")
+
+	err = toolpolicy.Validate(map[string]interface{}{"tool": "shell__exec"}, map[string]interface{}{"effect": "deny"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown field")
+
+	err = toolpolicy.Validate(map[string]interface{}{
+		"arguments": []interface{}{map[string]interface{}{"path": "$.command", "pattern": "("}},
+	}, map[string]interface{}{"effect": "deny"})
+	require.Error(t, err)
+
+	err = toolpolicy.Validate(map[string]interface{}{"tools": []interface{}{"shell["}}, map[string]interface{}{"effect": "deny"})
+	require.Error(t, err)
+}
+
+func TestToolPolicyServiceCachesAndTests(t *testing.T) {
+	store := &memoryToolPolicies{policies: []*types.Policy{{
+		ID:         "policy-1",
+		Name:       "block-shell",
+		Type:       types.PolicyTypeTool,
+		Priority:   100,
+		Conditions: map[string]interface{}{"tools": []interface{}{"shell__exec"}},
+		Actions:    map[string]interface{}{"effect": "deny"},
+	}}}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := services.NewToolPolicyServiceWithStore(store)
+	service.SetClock(func() time.Time { return now })
+	ctx := context.Background()
+
+	call := &toolpolicy.Call{NamespaceID: "ns-1", Tool: "shell__exec"}
+	decision, err := service.CheckToolCall(ctx, "org-1", call)
+	require.NoError(t, err)
+	assert.Equal(t, types.ToolPolicyEffectDeny, decision.Effect)
+	_, err = service.CheckToolCall(ctx, "org-1", call)
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.loads)
+
+	service.Invalidate("org-1")
+	_, err = service.CheckToolCall(ctx, "org-1", call)
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.loads)
+
+	// A higher-priority draft allow would let the call through
+	decision, err = service.Test(ctx, "org-1", &types.TestToolPolicyRequest{
+		NamespaceID: "ns-1",
+		Tool:        "shell__exec",
+		Policies: []types.CreatePolicyRequest{{
+			Name:       "allow-shell",
+			Type:       types.PolicyTypeTool,
+			Priority:   200,
+			Conditions: map[string]interface{}{"servers": []interface{}{"shell"}},
+			Actions:    map[string]interface{}{"effect": "allow"},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "allow-shell", decision.PolicyName)
+	require.Len(t, decision.Trace, 2)
+	assert.False(t, decision.Trace[1].Matched)
+
+	_, err = service.Test(ctx, "org-1", &types.TestToolPolicyRequest{
+		NamespaceID: "ns-1",
+		Tool:        "shell__exec",
+		Policies:    []types.CreatePolicyRequest{{Type: types.PolicyTypeAccess}},
+	})
+	require.Error(t, err)
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+}
+
+func TestJSONPathSelect(t *testing.T) {
+	doc := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "a", "tags": []interface{}{"x"}},
+			map[string]interface{}{"name": "b"},
+		},
+	}
+
+	path, err := jsonpath.Parse("$.items[*].name")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []interface{}{"a", "b"}, path.Select(doc))
+
+	path, err = jsonpath.Parse("$.items[-1].name")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"b"}, path.Select(doc))
+
+	path, err = jsonpath.Parse("$..tags[0]")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"x"}, path.Select(doc))
+
+	path, err = jsonpath.Parse("$.missing")
+	require.NoError(t, err)
+	assert.Empty(t, path.Select(doc))
+}
@@ -0,0 +1,238 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mcp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// progressTransport connects to a server whose tools report two progress
+// notifications for calls that ask for them, plus one for an unknown
+// token, before answering
+type progressTransport struct {
+	calls []map[string]interface{}
+}
+
+type progressConnection struct {
+	transport *progressTransport
+	in        chan []byte
+}
+
+func (t *progressTransport) Type() string { return "progress" }
+
+func (t *progressTransport) Connect(ctx context.Context, config mcp.TransportConfig) (mcp.Connection, error) {
+	return &progressConnection{transport: t, in: make(chan []byte, 16)}, nil
+}
+
+func (c *progressConnection) Send(ctx context.Context, message []byte) error {
+	var req struct {
+		Params map[string]interface{} `json:"params"`
+		Method string                 `json:"method"`
+		ID     string                 `json:"id"`
+	}
+	if err := json.Unmarshal(message, &req); err != nil {
+		return err
+	}
+
+	var result interface{} = map[string]interface{}{}
+	switch req.Method {
+	case "initialize":
+		result = map[string]interface{}{"protocolVersion": "2024-11-05", "capabilities": map[string]interface{}{}}
+	case "tools/call":
+		c.transport.calls = append(c.transport.calls, req.Params)
+		meta, _ := req.Params["_meta"].(map[string]interface{})
+		if token, ok := meta["progressToken"]; ok {
+			for _, n := range []map[string]interface{}{
+				{"progressToken": "someone-else", "progress": 9},
+				{"progressToken": token, "progress": 1, "total": 2, "message": "halfway"},
+				{"progressToken": token, "progress": 2, "total": 2},
+			} {
+				c.push(map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/progress", "params": n})
+			}
+		}
+		result = map[string]interface{}{"content": []map[string]interface{}{{"type": "text", "text": "done"}}}
+	}
+	c.push(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	return nil
+}
+
+func (c *progressConnection) push(message map[string]interface{}) {
+	encoded, _ := json.Marshal(message)
+	c.in <- encoded
+}
+
+func (c *progressConnection) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case message := <-c.in:
+		return message, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *progressConnection) Close() error      { return nil }
+func (c *progressConnection) IsConnected() bool { return true }
+
+func TestMCPClientReportsToolProgress(t *testing.T) {
+	transport := &progressTransport{}
+	client := mcp.NewMCPClient(transport)
+	require.NoError(t, client.Connect(context.Background(), mcp.TransportConfig{}, mcp.ClientInfo{Name: "test"}))
+	defer client.Close()
+
+	var others int
+	client.SetMessageHandler(func(message []byte) { others++ })
+
+	var reports []types.ToolProgress
+	ctx := types.WithToolProgress(context.Background(), func(p types.ToolProgress) {
+		reports = append(reports, p)
+	})
+	result, err := client.CallToolWithMeta(ctx, "build", nil, map[string]interface{}{"idempotencyKey": "k"})
+	require.NoError(t, err)
+	assert.Equal(t, "done", result.(mcp.ToolsCallResult).Content[0].Text)
+	assert.Equal(t, []types.ToolProgress{
+		{Progress: 1, Total: 2, Message: "halfway"},
+		{Progress: 2, Total: 2},
+	}, reports)
+	assert.Equal(t, 1, others, "progress of unknown calls goes to the message handler")
+
+	meta := transport.calls[0]["_meta"].(map[string]interface{})
+	assert.Equal(t, "k", meta["idempotencyKey"])
+	assert.NotEmpty(t, meta["progressToken"])
+
+	// Calls without a reporter do not ask for progress
+	_, err = client.CallTool(context.Background(), "build", nil)
+	require.NoError(t, err)
+	meta, _ = transport.calls[1]["_meta"].(map[string]interface{})
+	_, asked := meta["progressToken"]
+	assert.False(t, asked)
+}
+
+// progressExecutor reports progress and returns its result or error once
+// released
+type progressExecutor struct {
+	err     error
+	release chan struct{}
+	ctxErr  error
+}
+
+func (e *progressExecutor) ExecuteTool(ctx context.Context, namespaceID string, req types.ExecuteNamespaceToolRequest) (*types.NamespaceToolResult, error) {
+	report := types.ToolProgressFromContext(ctx)
+	report(types.ToolProgress{Progress: 1, Message: "started"})
+	<-e.release
+	e.ctxErr = ctx.Err()
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &types.NamespaceToolResult{Success: true, Result: "built"}, nil
+}
+
+func TestToolStreamJobOutlivesCaller(t *testing.T) {
+	executor := &progressExecutor{release: make(chan struct{})}
+	streams := services.NewToolStreamService(executor, time.Minute)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	streams.SetClock(func() time.Time { return now })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job, progress := streams.Start(ctx, "org-1", "ns-1", types.ExecuteNamespaceToolRequest{Tool: "ci__build"})
+	assert.Equal(t, types.ToolJobStatusRunning, job.Status)
+	assert.Equal(t, types.ToolProgress{Progress: 1, Message: "started"}, <-progress)
+
+	// The caller leaves; the job keeps running and can be polled
+	cancel()
+	polled, err := streams.Get(context.Background(), "org-1", "ns-1", job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.ToolJobStatusRunning, polled.Status)
+	assert.Equal(t, "started", polled.Progress.Message)
+
+	close(executor.release)
+	for range progress {
+	}
+	assert.NoError(t, executor.ctxErr)
+
+	polled, err = streams.Get(context.Background(), "org-1", "ns-1", job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.ToolJobStatusSucceeded, polled.Status)
+	assert.Equal(t, "built", polled.Result.Result)
+	require.NotNil(t, polled.FinishedAt)
+
+	_, err = streams.Get(context.Background(), "org-2", "ns-1", job.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+	_, err = streams.Get(context.Background(), "org-1", "ns-2", job.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+
+	now = now.Add(2 * time.Minute)
+	_, err = streams.Get(context.Background(), "org-1", "ns-1", job.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "ended jobs are kept for the retention period")
+}
+
+func TestToolStreamJobRecordsErrors(t *testing.T) {
+	executor := &progressExecutor{release: make(chan struct{}), err: errors.New("connection refused")}
+	close(executor.release)
+	streams := services.NewToolStreamService(executor, 0)
+
+	job, progress := streams.Start(context.Background(), "org-1", "ns-1", types.ExecuteNamespaceToolRequest{Tool: "ci__build"})
+	for range progress {
+	}
+	polled, err := streams.Get(context.Background(), "org-1", "ns-1", job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.ToolJobStatusFailed, polled.Status)
+	require.NotNil(t, polled.Error)
+	assert.Contains(t, polled.Error.Message, "connection refused")
+}
+
+func TestNamespaceExecuteStreamsOverSSE(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	executor := &progressExecutor{release: make(chan struct{})}
+	close(executor.release)
+	handler := handlers.NewNamespaceHandler(nil)
+	handler.SetToolStreams(services.NewToolStreamService(executor, 0))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("organization_id", "org-1") })
+	router.POST("/namespaces/:id/execute", handler.ExecuteNamespaceTool)
+	router.GET("/namespaces/:id/executions/:job_id", handler.GetExecution)
+
+	req := httptest.NewRequest(http.MethodPost, "/namespaces/ns-1/execute", strings.NewReader(`{"tool":"ci__build"}`))
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	var events []string
+	data := map[string]string{}
+	scanner := bufio.NewScanner(w.Body)
+	var event string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			event = name
+			events = append(events, event)
+		} else if payload, ok := strings.CutPrefix(line, "data: "); ok {
+			data[event] = payload
+		}
+	}
+	assert.Equal(t, []string{types.ToolStreamEventJob, types.ToolStreamEventProgress, types.ToolStreamEventResult}, events)
+	assert.JSONEq(t, `{"success":true,"result":"built"}`, data[types.ToolStreamEventResult])
+
+	var job types.ToolExecutionJob
+	require.NoError(t, json.Unmarshal([]byte(data[types.ToolStreamEventJob]), &job))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/namespaces/ns-1/executions/"+job.ID, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"succeeded"`)
+}
@@ -427,7 +427,7 @@ func TestMCPToolModel_SearchTools(t *testing.T) {
 	schemaJSON, _ := json.Marshal(schema)
 
 	// Expect the search query
-	mock.ExpectQuery(`SELECT (.+) FROM mcp_tools WHERE organization_id = \$1 AND is_active = true AND \((.+)\) ORDER BY usage_count DESC, created_at DESC LIMIT \$4 OFFSET \$5`).
+	mock.ExpectQuery(`SELECT (.+) FROM mcp_tools WHERE organization_id = \$1 AND is_active = true AND \((.+)\) ORDER BY ts_rank_cd\(search_vector, (.+)\) DESC, usage_count DESC, created_at DESC LIMIT \$4 OFFSET \$5`).
 		WithArgs(orgID, "%search%", "search", 10, 0).
 		WillReturnRows(sqlmock.NewRows([]string{
 			"id", "organization_id", "name", "description", "function_name", "schema", "category",
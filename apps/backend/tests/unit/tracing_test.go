@@ -0,0 +1,238 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/config"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/mcp"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/middleware"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/observability"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// installSpanRecorder records every span in memory, propagating W3C trace
+// context, until the test ends
+func installSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func spansByName(spans []sdktrace.ReadOnlySpan) map[string]sdktrace.ReadOnlySpan {
+	byName := make(map[string]sdktrace.ReadOnlySpan, len(spans))
+	for _, span := range spans {
+		byName[span.Name()] = span
+	}
+	return byName
+}
+
+const callerTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTracing_StagesAreSiblingSpans(t *testing.T) {
+	recorder := installSpanRecorder(t)
+	gin.SetMode(gin.TestMode)
+
+	passOn := func(c *gin.Context) { c.Next() }
+	r := gin.New()
+	r.Use(middleware.Tracing())
+	group := r.Group("/endpoints/:name")
+	group.Use(
+		middleware.TraceStage("endpoint.lookup", passOn),
+		middleware.TraceStage("endpoint.auth", passOn),
+		middleware.EndTraceStages(),
+	)
+	group.GET("/mcp", func(c *gin.Context) {
+		_, span := observability.StartSpan(c.Request.Context(), "handler")
+		span.End()
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/endpoints/demo/mcp", nil)
+	req.Header.Set("traceparent", callerTraceparent)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := spansByName(recorder.Ended())
+	require.Len(t, spans, 4)
+
+	server := spans["GET /endpoints/:name/mcp"]
+	require.NotNil(t, server)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+
+	lookup, auth, handler := spans["endpoint.lookup"], spans["endpoint.auth"], spans["handler"]
+	for _, span := range []sdktrace.ReadOnlySpan{lookup, auth, handler} {
+		require.NotNil(t, span)
+		assert.Equal(t, server.SpanContext().SpanID(), span.Parent().SpanID(), span.Name())
+	}
+	// Each stage span ends when it hands the request on
+	assert.False(t, lookup.EndTime().After(auth.StartTime()))
+	assert.False(t, auth.EndTime().After(handler.StartTime()))
+}
+
+func TestTracing_AbortedStage(t *testing.T) {
+	recorder := installSpanRecorder(t)
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(middleware.Tracing())
+	r.GET("/limited",
+		middleware.TraceStage("endpoint.rate_limit", func(c *gin.Context) {
+			c.AbortWithStatus(http.StatusTooManyRequests)
+		}),
+		middleware.EndTraceStages(),
+		func(c *gin.Context) { t.Fatal("handler must not run") },
+	)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/limited", nil))
+
+	spans := spansByName(recorder.Ended())
+	stage := spans["endpoint.rate_limit"]
+	require.NotNil(t, stage)
+	attrs := map[string]interface{}{}
+	for _, attr := range stage.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	assert.Equal(t, true, attrs["gateway.aborted"])
+	assert.Equal(t, int64(http.StatusTooManyRequests), attrs["http.response.status_code"])
+}
+
+// capturingTransport connects to a mock server and keeps the messages sent
+// to it
+type capturingTransport struct {
+	mcp.MockTransport
+	mu   sync.Mutex
+	sent []map[string]interface{}
+}
+
+type capturingConnection struct {
+	mcp.Connection
+	transport *capturingTransport
+}
+
+func (t *capturingTransport) Connect(ctx context.Context, config mcp.TransportConfig) (mcp.Connection, error) {
+	conn, err := t.MockTransport.Connect(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return &capturingConnection{Connection: conn, transport: t}, nil
+}
+
+func (c *capturingConnection) Send(ctx context.Context, message []byte) error {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(message, &decoded); err == nil {
+		c.transport.mu.Lock()
+		c.transport.sent = append(c.transport.sent, decoded)
+		c.transport.mu.Unlock()
+	}
+	return c.Connection.Send(ctx, message)
+}
+
+func (t *capturingTransport) lastParams() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	params, _ := t.sent[len(t.sent)-1]["params"].(map[string]interface{})
+	return params
+}
+
+func TestTracing_MCPClientPropagatesTraceContext(t *testing.T) {
+	recorder := installSpanRecorder(t)
+
+	transport := &capturingTransport{}
+	client := mcp.NewMCPClient(transport)
+	require.NoError(t, client.Connect(context.Background(), mcp.TransportConfig{
+		Type: "mock",
+		MockTools: func(ctx context.Context) ([]types.MockTool, error) {
+			return []types.MockTool{{Name: "echo", Response: "{{.arguments.text}}"}}, nil
+		},
+	}, mcp.ClientInfo{Name: "test", Version: "1.0.0"}))
+	t.Cleanup(func() { client.Close() })
+
+	ctx, parent := observability.StartSpan(context.Background(), "namespace.execute_tool")
+	_, err := client.CallToolWithMeta(ctx, "echo", map[string]interface{}{"text": "hi"},
+		map[string]interface{}{"idempotencyKey": "key-1"})
+	require.NoError(t, err)
+	parent.End()
+
+	var call sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "mcp.client tools/call" {
+			call = span
+		}
+	}
+	require.NotNil(t, call)
+	assert.Equal(t, trace.SpanKindClient, call.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), call.Parent().SpanID())
+
+	// The server sees the client span as the parent of its work, next to
+	// the meta the gateway already sent
+	meta, _ := transport.lastParams()["_meta"].(map[string]interface{})
+	require.NotNil(t, meta)
+	assert.Equal(t, "key-1", meta["idempotencyKey"])
+	remote := trace.SpanContextFromContext(observability.ExtractMeta(context.Background(), meta))
+	assert.Equal(t, call.SpanContext().TraceID(), remote.TraceID())
+	assert.Equal(t, call.SpanContext().SpanID(), remote.SpanID())
+
+	// tools/list params, a plain map, carry the context too
+	_, err = client.ListTools(ctx)
+	require.NoError(t, err)
+	meta, _ = transport.lastParams()["_meta"].(map[string]interface{})
+	assert.Contains(t, meta, "traceparent")
+}
+
+func TestTracing_MetaRoundTrip(t *testing.T) {
+	installSpanRecorder(t)
+
+	// Outside a trace the meta is passed on untouched
+	meta := map[string]interface{}{"idempotencyKey": "key-1"}
+	assert.Equal(t, meta, observability.InjectMeta(context.Background(), meta))
+
+	ctx := observability.ExtractHTTP(context.Background(), http.Header{"Traceparent": []string{callerTraceparent}})
+	injected := observability.InjectMeta(ctx, meta)
+	assert.Equal(t, callerTraceparent, injected["traceparent"])
+	assert.NotContains(t, meta, "traceparent", "the caller's meta must not be modified")
+
+	// Keys are matched case-insensitively, as clients may send Traceparent
+	remote := trace.SpanContextFromContext(observability.ExtractMeta(context.Background(),
+		map[string]interface{}{"Traceparent": callerTraceparent}))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", remote.TraceID().String())
+	assert.True(t, remote.IsRemote())
+
+	header := http.Header{}
+	observability.InjectHTTP(ctx, header)
+	assert.Equal(t, callerTraceparent, header.Get("traceparent"))
+}
+
+func TestTracingConfigValidate(t *testing.T) {
+	ratio := func(v float64) *float64 { return &v }
+
+	assert.NoError(t, (&config.TracingConfig{}).Validate())
+	assert.NoError(t, (&config.TracingConfig{Enabled: true, Protocol: "http", SampleRatio: ratio(0)}).Validate())
+	assert.Error(t, (&config.TracingConfig{Enabled: true, Protocol: "zipkin"}).Validate())
+	assert.Error(t, (&config.TracingConfig{Enabled: true, SampleRatio: ratio(1.5)}).Validate())
+
+	assert.Equal(t, 1.0, (&config.TracingConfig{}).GetSampleRatio())
+	assert.Equal(t, 0.25, (&config.TracingConfig{SampleRatio: ratio(0.25)}).GetSampleRatio())
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/eventbus"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
 	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
 
@@ -399,6 +400,39 @@ func TestManager_BroadcastMessage(t *testing.T) {
 	manager.CloseConnection(session2.ID)
 }
 
+func TestManager_BroadcastAcrossInstances(t *testing.T) {
+	transport.RegisterTransport(types.TransportTypeWebSocket, mockTransportFactory(types.TransportTypeWebSocket))
+
+	// Two managers on one bus stand in for two gateway instances
+	bus := eventbus.NewLocalBus()
+	first := setupTransportManager()
+	second := setupTransportManager()
+	require.NoError(t, first.SetEventBus(bus))
+	require.NoError(t, second.SetEventBus(bus))
+	ctx := context.Background()
+
+	local, localSession, err := first.CreateConnection(ctx, types.TransportTypeWebSocket, "user1", "org1", "server1")
+	require.NoError(t, err)
+	defer first.CloseConnection(localSession.ID)
+	remote, remoteSession, err := second.CreateConnection(ctx, types.TransportTypeWebSocket, "user2", "org1", "server1")
+	require.NoError(t, err)
+	defer second.CloseConnection(remoteSession.ID)
+
+	message := &types.WebSocketMessage{Type: "alert", Data: map[string]interface{}{"level": "high"}, Timestamp: time.Now()}
+	require.NoError(t, first.BroadcastMessage(ctx, types.TransportTypeWebSocket, message))
+
+	// The origin delivers once, not again when its own event comes back
+	local.(*MockTransport).AssertNumberOfCalls(t, "SendMessage", 1)
+	local.(*MockTransport).AssertCalled(t, "SendMessage", mock.Anything, message)
+
+	remoteMock := remote.(*MockTransport)
+	remoteMock.AssertNumberOfCalls(t, "SendMessage", 1)
+	received, ok := remoteMock.Calls[len(remoteMock.Calls)-1].Arguments.Get(1).(*types.WebSocketMessage)
+	require.True(t, ok)
+	assert.Equal(t, "alert", received.Type)
+	assert.Equal(t, map[string]interface{}{"level": "high"}, received.Data)
+}
+
 func TestManager_GetMetrics(t *testing.T) {
 	// Setup mocks
 	transport.RegisterTransport(types.TransportTypeHTTP, mockTransportFactory(types.TransportTypeHTTP))
@@ -482,6 +516,7 @@ func TestManager_GetSupportedTransports(t *testing.T) {
 		types.TransportTypeWebSocket,
 		types.TransportTypeStreamable,
 		types.TransportTypeSTDIO,
+		types.TransportTypeGRPC,
 	}
 
 	assert.Len(t, supported, len(expectedTransports))
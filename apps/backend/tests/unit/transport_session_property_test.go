@@ -0,0 +1,266 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionOp is one generated operation against the session manager; Target
+// picks one of the sessions created so far
+type sessionOp struct {
+	Kind   uint8
+	Target uint8
+}
+
+const (
+	opCreate = iota
+	opTouch
+	opEvent
+	opClose
+	opExpire
+	opUpdate
+	opShutdown
+	sessionOpKinds
+)
+
+func (op sessionOp) String() string {
+	names := [...]string{"create", "touch", "event", "close", "expire", "update", "shutdown"}
+	return fmt.Sprintf("%s(%d)", names[int(op.Kind)%sessionOpKinds], op.Target)
+}
+
+// sessionModel is the expected state of a session
+type sessionModel struct {
+	id        string
+	transport types.TransportType
+	events    int
+	closed    bool
+	expired   bool
+}
+
+func (m *sessionModel) live() bool { return !m.closed && !m.expired }
+
+var propertyTransports = []types.TransportType{types.TransportTypeWebSocket, types.TransportTypeSSE, types.TransportTypeSTDIO}
+
+// checkEventLog verifies that a session's events start with its connect,
+// end with at most one disconnect and are in time order
+func checkEventLog(t *testing.T, sessionID string, events []types.TransportEvent) {
+	t.Helper()
+	require.NotEmpty(t, events, "session %s has no events", sessionID)
+	assert.Equal(t, types.TransportEventTypeConnect, events[0].Type, "first event of %s", sessionID)
+	for i, event := range events {
+		assert.Equal(t, sessionID, event.SessionID)
+		if event.Type == types.TransportEventTypeDisconnect {
+			assert.Equal(t, len(events)-1, i, "session %s has events after its disconnect", sessionID)
+		}
+		if i > 0 {
+			assert.False(t, event.Timestamp.Before(events[i-1].Timestamp), "events of %s out of order", sessionID)
+		}
+	}
+}
+
+// runSessionOps applies ops to a fresh manager and to the model, checking
+// after every step that the manager agrees with the model
+func runSessionOps(t *testing.T, ops []sessionOp) bool {
+	sm := transport.NewSessionManager(&types.TransportConfig{SessionTimeout: time.Hour})
+	defer sm.Shutdown(context.Background())
+
+	ctx := context.Background()
+	var models []*sessionModel
+	shutdown := false
+
+	for step, op := range ops {
+		kind := int(op.Kind) % sessionOpKinds
+		if kind == opCreate || len(models) == 0 {
+			transportType := propertyTransports[int(op.Target)%len(propertyTransports)]
+			session, err := sm.CreateSession(ctx, "user", "org", "server", transportType)
+			if shutdown {
+				if !assert.Error(t, err, "create after shutdown") {
+					return false
+				}
+				continue
+			}
+			if !assert.NoError(t, err) {
+				return false
+			}
+			models = append(models, &sessionModel{id: session.ID, transport: transportType, events: 1})
+			continue
+		}
+
+		m := models[int(op.Target)%len(models)]
+		switch kind {
+		case opTouch:
+			err := sm.TouchSession(m.id)
+			assert.Equal(t, m.live(), err == nil, "step %d %s: %v", step, op, err)
+		case opEvent:
+			err := sm.AddEvent(m.id, types.TransportEventTypeMessage, map[string]interface{}{"step": step})
+			assert.Equal(t, m.live(), err == nil, "step %d %s: %v", step, op, err)
+			if err == nil {
+				m.events++
+			}
+		case opClose:
+			require.NoError(t, sm.CloseSession(m.id), "closing is idempotent")
+			if !m.closed {
+				m.closed = true
+				m.events++
+			}
+		case opExpire:
+			err := sm.UpdateSession(m.id, map[string]interface{}{"expires_at": time.Now().Add(-time.Second)})
+			assert.Equal(t, m.live(), err == nil, "step %d %s: %v", step, op, err)
+			if err == nil {
+				m.expired = true
+			}
+		case opUpdate:
+			// Closing through an update must not bypass CloseSession
+			err := sm.UpdateSession(m.id, map[string]interface{}{"status": types.TransportSessionStatusClosed, "step": step})
+			assert.Equal(t, m.live(), err == nil, "step %d %s: %v", step, op, err)
+		case opShutdown:
+			require.NoError(t, sm.Shutdown(ctx))
+			shutdown = true
+			for _, other := range models {
+				if !other.closed {
+					other.closed = true
+					other.events++
+				}
+			}
+		}
+
+		if !checkSessionModels(t, sm, models) {
+			t.Logf("after step %d %s of %v", step, op, ops)
+			return false
+		}
+	}
+	return true
+}
+
+func checkSessionModels(t *testing.T, sm *transport.SessionManager, models []*sessionModel) bool {
+	active := make(map[string]int)
+	for _, m := range models {
+		session, err := sm.GetSession(m.id)
+		if m.expired {
+			assert.Error(t, err, "expired session %s", m.id)
+		} else if assert.NoError(t, err) {
+			assert.Equal(t, m.closed, session.Status == types.TransportSessionStatusClosed, "status of %s", m.id)
+			assert.Len(t, session.EventStore, m.events)
+		}
+
+		events, err := sm.GetEvents(m.id, nil, 0)
+		if assert.NoError(t, err) {
+			assert.Len(t, events, m.events, "events of %s", m.id)
+			checkEventLog(t, m.id, events)
+		}
+
+		if m.live() {
+			active[string(m.transport)]++
+		}
+	}
+
+	assert.Len(t, sm.GetActiveSessions(), sumCounts(active))
+	assert.Equal(t, active, sm.CountByTransport())
+	return !t.Failed()
+}
+
+func sumCounts(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+func TestSessionManager_PropertyMatchesModel(t *testing.T) {
+	property := func(ops []sessionOp) bool { return runSessionOps(t, ops) }
+	require.NoError(t, quick.Check(property, &quick.Config{MaxCount: 300}))
+}
+
+func TestSessionManager_PropertyRegressions(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []sessionOp
+	}{
+		{name: "event after close", ops: []sessionOp{{opCreate, 0}, {opClose, 0}, {opEvent, 0}}},
+		{name: "double close", ops: []sessionOp{{opCreate, 0}, {opClose, 0}, {opClose, 0}}},
+		{name: "touch revives expired session", ops: []sessionOp{{opCreate, 0}, {opExpire, 0}, {opTouch, 0}}},
+		{name: "reopen closed session by update", ops: []sessionOp{{opCreate, 0}, {opClose, 0}, {opUpdate, 0}}},
+		{name: "shutdown after close", ops: []sessionOp{{opCreate, 0}, {opClose, 0}, {opShutdown, 0}, {opShutdown, 0}, {opCreate, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, runSessionOps(t, tt.ops))
+		})
+	}
+}
+
+// TestSessionManager_PropertyConcurrentHistory runs random operations from
+// many goroutines; whatever the interleaving, every event log must stay well
+// formed and sessions handed out must not share state with the manager
+// (which the race detector checks)
+func TestSessionManager_PropertyConcurrentHistory(t *testing.T) {
+	sm := transport.NewSessionManager(&types.TransportConfig{SessionTimeout: time.Hour})
+	ctx := context.Background()
+
+	ids := make([]string, 8)
+	for i := range ids {
+		session, err := sm.CreateSession(ctx, "user", "org", "server", types.TransportTypeWebSocket)
+		require.NoError(t, err)
+		ids[i] = session.ID
+	}
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < 300; i++ {
+				id := ids[rng.Intn(len(ids))]
+				switch rng.Intn(6) {
+				case 0:
+					sm.TouchSession(id)
+				case 1:
+					sm.AddEvent(id, types.TransportEventTypeMessage, map[string]interface{}{"i": i})
+				case 2:
+					if rng.Intn(10) == 0 {
+						sm.CloseSession(id)
+					}
+				case 3:
+					sm.UpdateSession(id, map[string]interface{}{fmt.Sprintf("key-%d", rng.Intn(4)): i})
+				case 4:
+					if session, err := sm.GetSession(id); err == nil {
+						for range session.Metadata {
+						}
+						session.Metadata["caller"] = i
+					}
+				case 5:
+					for _, session := range sm.GetActiveSessions() {
+						session.Metadata["caller"] = i
+					}
+				}
+			}
+		}(int64(worker))
+	}
+	wg.Wait()
+
+	require.NoError(t, sm.Shutdown(ctx))
+	for _, id := range ids {
+		events, err := sm.GetEvents(id, nil, 0)
+		require.NoError(t, err)
+		checkEventLog(t, id, events)
+		assert.Equal(t, types.TransportEventTypeDisconnect, events[len(events)-1].Type, "session %s was not closed", id)
+
+		session, err := sm.GetSession(id)
+		require.NoError(t, err)
+		assert.NotContains(t, session.Metadata, "caller", "copies must not write through to the manager")
+	}
+}
@@ -0,0 +1,270 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	budgetCriticalNamespace = "7b0e8f3c-5c36-4f5e-9d5c-1a2b3c4d5e6f"
+	budgetOtherNamespace    = "0f1e2d3c-4b5a-4968-8776-655443322110"
+)
+
+// memoryUsageBudgets keeps budgets, usage and alerts per organization;
+// every namespace belongs to "org-1"
+type memoryUsageBudgets struct {
+	usage   map[string]int64
+	budgets map[string]*types.UsageBudget
+	alerts  map[string]bool
+	failAdd bool
+}
+
+func newMemoryUsageBudgets() *memoryUsageBudgets {
+	return &memoryUsageBudgets{
+		usage:   map[string]int64{},
+		budgets: map[string]*types.UsageBudget{},
+		alerts:  map[string]bool{},
+	}
+}
+
+func usageKey(orgID, dimension string, period time.Time) string {
+	return orgID + ":" + dimension + ":" + period.Format("2006-01")
+}
+
+func (m *memoryUsageBudgets) AddUsage(orgID, dimension string, period time.Time, quantity int64) error {
+	if m.failAdd {
+		return errors.New("database unavailable")
+	}
+	m.usage[usageKey(orgID, dimension, period)] += quantity
+	return nil
+}
+
+func (m *memoryUsageBudgets) Usage(orgID string, period time.Time) (map[string]int64, error) {
+	usage := map[string]int64{}
+	for _, dimension := range types.MeterDimensions {
+		if q, ok := m.usage[usageKey(orgID, dimension, period)]; ok {
+			usage[dimension] = q
+		}
+	}
+	return usage, nil
+}
+
+func (m *memoryUsageBudgets) ListBudgets(orgID string) ([]*types.UsageBudget, error) {
+	var budgets []*types.UsageBudget
+	for _, b := range m.budgets {
+		if orgID == "" || b.OrganizationID == orgID {
+			budgets = append(budgets, b)
+		}
+	}
+	return budgets, nil
+}
+
+func (m *memoryUsageBudgets) UpsertBudget(budget *types.UsageBudget) error {
+	m.budgets[budget.OrganizationID+":"+budget.Dimension] = budget
+	return nil
+}
+
+func (m *memoryUsageBudgets) DeleteBudget(orgID, dimension string) (bool, error) {
+	_, ok := m.budgets[orgID+":"+dimension]
+	delete(m.budgets, orgID+":"+dimension)
+	return ok, nil
+}
+
+func (m *memoryUsageBudgets) NamespacesExist(orgID string, namespaceIDs []string) (bool, error) {
+	return orgID == "org-1", nil
+}
+
+func (m *memoryUsageBudgets) AlertsSent(orgID, dimension string, period time.Time) ([]int, error) {
+	sent := []int{}
+	for _, threshold := range types.UsageBudgetThresholds {
+		if m.alerts[fmt.Sprintf("%s:%d", usageKey(orgID, dimension, period), threshold)] {
+			sent = append(sent, threshold)
+		}
+	}
+	return sent, nil
+}
+
+func (m *memoryUsageBudgets) RecordAlert(orgID, dimension string, period time.Time, threshold int) (bool, error) {
+	key := fmt.Sprintf("%s:%d", usageKey(orgID, dimension, period), threshold)
+	if m.alerts[key] {
+		return false, nil
+	}
+	m.alerts[key] = true
+	return true, nil
+}
+
+func (m *memoryUsageBudgets) ClearAlerts(orgID, dimension string, period time.Time) error {
+	for _, threshold := range types.UsageBudgetThresholds {
+		delete(m.alerts, fmt.Sprintf("%s:%d", usageKey(orgID, dimension, period), threshold))
+	}
+	return nil
+}
+
+func newTestUsageBudgets(t *testing.T) (*services.UsageBudgetService, *services.MeteringService, *memoryUsageBudgets, *recordingAdminNotifier, *time.Time) {
+	t.Helper()
+	store := newMemoryUsageBudgets()
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	budgets := services.NewUsageBudgetServiceWithStore(store)
+	budgets.SetClock(clock)
+	notifier := &recordingAdminNotifier{}
+	budgets.SetNotifier(notifier)
+
+	meter := services.NewMeteringServiceWithStore(store, 0)
+	meter.SetClock(clock)
+	return budgets, meter, store, notifier, &now
+}
+
+func TestUsageBudgetSetValidates(t *testing.T) {
+	budgets, _, _, _, _ := newTestUsageBudgets(t)
+	ctx := context.Background()
+
+	_, err := budgets.SetBudget(ctx, "org-1", "dollars", &types.SetUsageBudgetRequest{MonthlyLimit: 10})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	_, err = budgets.SetBudget(ctx, "org-1", types.MeterExecutions, &types.SetUsageBudgetRequest{
+		MonthlyLimit: 10, CriticalNamespaces: []string{"not-a-uuid"},
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	_, err = budgets.SetBudget(ctx, "org-2", types.MeterExecutions, &types.SetUsageBudgetRequest{
+		MonthlyLimit: 10, CriticalNamespaces: []string{budgetCriticalNamespace},
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+
+	status, err := budgets.SetBudget(ctx, "org-1", types.MeterExecutions, &types.SetUsageBudgetRequest{
+		MonthlyLimit: 10, CriticalNamespaces: []string{budgetCriticalNamespace, budgetCriticalNamespace},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{budgetCriticalNamespace}, status.CriticalNamespaces)
+	assert.Equal(t, "2026-03", status.Period)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), status.ResetsAt)
+
+	err = budgets.DeleteBudget(ctx, "org-1", types.MeterTokens)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound))
+	require.NoError(t, budgets.DeleteBudget(ctx, "org-1", types.MeterExecutions))
+}
+
+func TestMeteringFlushesUsageByMonth(t *testing.T) {
+	budgets, meter, store, _, now := newTestUsageBudgets(t)
+	ctx := context.Background()
+
+	meter.Record("org-1", map[string]int64{types.MeterExecutions: 1, types.MeterEgressBytes: 200})
+	meter.Record("org-1", map[string]int64{types.MeterExecutions: 1, types.MeterTokens: 0})
+	meter.Record("", map[string]int64{types.MeterExecutions: 1})
+
+	store.failAdd = true
+	require.Error(t, meter.Flush(ctx))
+	store.failAdd = false
+	require.NoError(t, meter.Flush(ctx))
+
+	resp, err := budgets.List(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03", resp.Usage.Period)
+	assert.Equal(t, map[string]int64{
+		types.MeterExecutions:  2,
+		types.MeterTokens:      0,
+		types.MeterEgressBytes: 200,
+	}, resp.Usage.Usage)
+
+	*now = time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	meter.Record("org-1", map[string]int64{types.MeterExecutions: 5})
+	require.NoError(t, meter.Flush(ctx))
+	resp, err = budgets.List(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), resp.Usage.Usage[types.MeterExecutions])
+}
+
+func TestUsageBudgetThresholdAlertsOncePerMonth(t *testing.T) {
+	budgets, meter, _, notifier, now := newTestUsageBudgets(t)
+	ctx := context.Background()
+
+	_, err := budgets.SetBudget(ctx, "org-1", types.MeterExecutions, &types.SetUsageBudgetRequest{MonthlyLimit: 100})
+	require.NoError(t, err)
+
+	meter.Record("org-1", map[string]int64{types.MeterExecutions: 40})
+	require.NoError(t, meter.Flush(ctx))
+	created, err := budgets.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, created)
+
+	meter.Record("org-1", map[string]int64{types.MeterExecutions: 10})
+	require.NoError(t, meter.Flush(ctx))
+	created, err = budgets.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, created)
+	require.Len(t, notifier.events, 1)
+	assert.Equal(t, types.NotificationBudgetThreshold, notifier.events[0].Type)
+	assert.Equal(t, types.NotificationSeverityInfo, notifier.events[0].Severity)
+	assert.Equal(t, "budget_threshold:org-1:executions:2026-03:50", notifier.events[0].DedupKey)
+
+	created, err = budgets.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, created, "a threshold alerts once a month")
+
+	// Crossing 80 and 100 at once notifies only the highest
+	meter.Record("org-1", map[string]int64{types.MeterExecutions: 60})
+	require.NoError(t, meter.Flush(ctx))
+	created, err = budgets.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, created)
+	require.Len(t, notifier.events, 2)
+	assert.Equal(t, types.NotificationSeverityCritical, notifier.events[1].Severity)
+	assert.Equal(t, 100, notifier.events[1].Data["threshold"])
+
+	resp, err := budgets.List(ctx, "org-1")
+	require.NoError(t, err)
+	require.Len(t, resp.Budgets, 1)
+	assert.Equal(t, []int{50, 80, 100}, resp.Budgets[0].AlertsSent)
+	assert.Equal(t, float64(110), resp.Budgets[0].PercentUsed)
+	assert.False(t, resp.Budgets[0].Stopping)
+
+	// A new month starts afresh
+	*now = time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+	created, err = budgets.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, created)
+}
+
+func TestUsageBudgetHardStopSparesCriticalNamespaces(t *testing.T) {
+	budgets, meter, _, _, now := newTestUsageBudgets(t)
+	ctx := context.Background()
+
+	_, err := budgets.SetBudget(ctx, "org-1", types.MeterTokens, &types.SetUsageBudgetRequest{
+		MonthlyLimit: 1000, HardStop: true, CriticalNamespaces: []string{budgetCriticalNamespace},
+	})
+	require.NoError(t, err)
+
+	meter.Record("org-1", map[string]int64{types.MeterTokens: 999})
+	require.NoError(t, meter.Flush(ctx))
+	require.NoError(t, budgets.CheckBudget(ctx, "org-1", budgetOtherNamespace))
+
+	meter.Record("org-1", map[string]int64{types.MeterTokens: 1})
+	require.NoError(t, meter.Flush(ctx))
+	// Usage is cached briefly
+	require.NoError(t, budgets.CheckBudget(ctx, "org-1", budgetOtherNamespace))
+
+	*now = now.Add(11 * time.Second)
+	err = budgets.CheckBudget(ctx, "org-1", budgetOtherNamespace)
+	require.True(t, types.IsError(err, types.ErrCodeBudgetExhausted))
+	assert.Contains(t, err.Error(), "tokens")
+	assert.NoError(t, budgets.CheckBudget(ctx, "org-1", budgetCriticalNamespace))
+	assert.NoError(t, budgets.CheckBudget(ctx, "org-2", budgetOtherNamespace))
+
+	// Raising the budget resumes traffic at once
+	_, err = budgets.SetBudget(ctx, "org-1", types.MeterTokens, &types.SetUsageBudgetRequest{
+		MonthlyLimit: 2000, HardStop: true, CriticalNamespaces: []string{budgetCriticalNamespace},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, budgets.CheckBudget(ctx, "org-1", budgetOtherNamespace))
+}
@@ -0,0 +1,316 @@
+package unit
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/auth"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userAdminOrgID = "3c1f9a7e-2d4b-4e8a-9b5c-6f0d1e2a3b4c"
+
+type memoryUser struct {
+	user         *types.ManagedUser
+	passwordHash string
+}
+
+type memoryUsers struct {
+	users  map[string]*memoryUser
+	tokens map[string]*types.UserToken
+}
+
+func newMemoryUsers() *memoryUsers {
+	return &memoryUsers{users: map[string]*memoryUser{}, tokens: map[string]*types.UserToken{}}
+}
+
+func (m *memoryUsers) add(email, role string) *types.ManagedUser {
+	user := &types.ManagedUser{
+		ID:             uuid.New().String(),
+		OrganizationID: userAdminOrgID,
+		Email:          email,
+		Name:           "User " + email,
+		Role:           role,
+		Status:         types.UserStatusActive,
+	}
+	m.users[user.ID] = &memoryUser{user: user, passwordHash: "hash"}
+	return user
+}
+
+func (m *memoryUsers) copyOf(u *memoryUser) *types.ManagedUser {
+	user := *u.user
+	return &user
+}
+
+func (m *memoryUsers) List(orgID string, filter *types.ManagedUserFilter) ([]*types.ManagedUser, error) {
+	users := []*types.ManagedUser{}
+	for _, u := range m.users {
+		if u.user.OrganizationID == orgID && (filter.Status == "" || u.user.Status == filter.Status) {
+			users = append(users, m.copyOf(u))
+		}
+	}
+	return users, nil
+}
+
+func (m *memoryUsers) Get(orgID, id string) (*types.ManagedUser, error) {
+	if u, ok := m.users[id]; ok && u.user.OrganizationID == orgID {
+		return m.copyOf(u), nil
+	}
+	return nil, nil
+}
+
+func (m *memoryUsers) GetByEmail(email string) (*types.ManagedUser, error) {
+	for _, u := range m.users {
+		if strings.EqualFold(u.user.Email, email) {
+			return m.copyOf(u), nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryUsers) Create(user *types.ManagedUser, passwordHash string) error {
+	user.ID = uuid.New().String()
+	if user.Status == types.UserStatusInvited {
+		now := time.Now()
+		user.InvitedAt = &now
+		passwordHash = "!"
+	}
+	stored := *user
+	m.users[user.ID] = &memoryUser{user: &stored, passwordHash: passwordHash}
+	return nil
+}
+
+func (m *memoryUsers) Update(user *types.ManagedUser) error {
+	m.users[user.ID].user.Name = user.Name
+	m.users[user.ID].user.Role = user.Role
+	return nil
+}
+
+func (m *memoryUsers) SetDeactivated(orgID, id string, deactivated bool) (bool, error) {
+	u := m.users[id]
+	switch {
+	case deactivated && u.user.Status != types.UserStatusDeactivated:
+		now := time.Now()
+		u.user.Status, u.user.DeactivatedAt = types.UserStatusDeactivated, &now
+		for hash, token := range m.tokens {
+			if token.UserID == id && token.UsedAt == nil {
+				delete(m.tokens, hash)
+			}
+		}
+	case !deactivated && u.user.Status == types.UserStatusDeactivated:
+		u.user.Status, u.user.DeactivatedAt = types.UserStatusActive, nil
+		if u.passwordHash == "!" {
+			u.user.Status = types.UserStatusInvited
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func (m *memoryUsers) CountActiveAdmins(orgID string) (int, error) {
+	count := 0
+	for _, u := range m.users {
+		if u.user.OrganizationID == orgID && u.user.Role == types.RoleAdmin && u.user.Status == types.UserStatusActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryUsers) CreateToken(token *types.UserToken) error {
+	for hash, existing := range m.tokens {
+		if existing.UserID == token.UserID && existing.Purpose == token.Purpose && existing.UsedAt == nil {
+			delete(m.tokens, hash)
+		}
+	}
+	token.ID = uuid.New().String()
+	stored := *token
+	m.tokens[token.TokenHash] = &stored
+	return nil
+}
+
+func (m *memoryUsers) ConsumeToken(purpose, tokenHash string, now time.Time) (*types.UserToken, error) {
+	token, ok := m.tokens[tokenHash]
+	if !ok || token.Purpose != purpose || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	token.UsedAt = &now
+	consumed := *token
+	return &consumed, nil
+}
+
+func (m *memoryUsers) SetPassword(userID, passwordHash string, activate bool) (bool, error) {
+	u := m.users[userID]
+	if u == nil || u.user.Status == types.UserStatusDeactivated {
+		return false, nil
+	}
+	u.passwordHash = passwordHash
+	if activate {
+		u.user.Status = types.UserStatusActive
+	}
+	return true, nil
+}
+
+func newUserAdminService(store *memoryUsers) *services.UserAdminService {
+	hasher := auth.NewPasswordHasher("bcrypt", 4, auth.Argon2Params{})
+	return services.NewUserAdminServiceWithStore(store, hasher, "https://gateway.example.com/", 0, 0)
+}
+
+// linkToken returns the token of an invitation or reset link
+func linkToken(t *testing.T, link string) string {
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	return parsed.Query().Get("token")
+}
+
+func TestUserInvitationFlow(t *testing.T) {
+	store := newMemoryUsers()
+	admin := store.add("admin@example.com", types.RoleAdmin)
+	svc := newUserAdminService(store)
+	auditor := &recordingGrantAuditor{}
+	svc.SetAuditor(auditor)
+	ctx := context.Background()
+
+	delivery, err := svc.Invite(ctx, userAdminOrgID, admin.ID, &types.InviteUserRequest{
+		Email: " New.User@Example.com ", Name: "New User", Role: types.RoleUser,
+	})
+	require.NoError(t, err)
+	assert.False(t, delivery.EmailSent)
+	assert.True(t, strings.HasPrefix(delivery.URL, "https://gateway.example.com/accept-invitation?token="),
+		"without a mailer the link is returned to the admin")
+	assert.Equal(t, "new.user@example.com", delivery.User.Email)
+	assert.Equal(t, types.UserStatusInvited, delivery.User.Status)
+	assert.Equal(t, admin.ID, delivery.User.InvitedBy)
+
+	_, err = svc.Invite(ctx, userAdminOrgID, admin.ID, &types.InviteUserRequest{
+		Email: "NEW.USER@example.com", Name: "Again", Role: types.RoleUser,
+	})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict), "emails are unique regardless of case")
+
+	// Resending replaces the first link
+	mail := &recordingMailer{}
+	svc.SetMailer(mail)
+	resent, err := svc.ResendInvitation(ctx, userAdminOrgID, admin.ID, delivery.User.ID)
+	require.NoError(t, err)
+	assert.True(t, resent.EmailSent)
+	assert.Empty(t, resent.URL, "emailed links are not returned")
+	require.Len(t, mail.messages, 1)
+	assert.Equal(t, "new.user@example.com", mail.messages[0].To)
+	link := mail.messages[0].Body[strings.Index(mail.messages[0].Body, "https://"):]
+	link = strings.Fields(link)[0]
+
+	err = svc.AcceptInvitation(ctx, &types.AcceptInvitationRequest{Token: linkToken(t, delivery.URL), Password: "password123"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "earlier links stop working")
+
+	require.NoError(t, svc.AcceptInvitation(ctx, &types.AcceptInvitationRequest{Token: linkToken(t, link), Password: "password123"}))
+	user, err := svc.Get(ctx, userAdminOrgID, delivery.User.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.UserStatusActive, user.Status)
+	valid, _ := auth.NewPasswordHasher("bcrypt", 4, auth.Argon2Params{}).Verify("password123", store.users[user.ID].passwordHash)
+	assert.True(t, valid)
+
+	err = svc.AcceptInvitation(ctx, &types.AcceptInvitationRequest{Token: linkToken(t, link), Password: "password123"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "links can be used once")
+
+	var actions []string
+	for _, log := range auditor.logs {
+		assert.Equal(t, "user", log.Resource)
+		assert.Equal(t, delivery.User.ID, log.ResourceID)
+		actions = append(actions, log.Action)
+	}
+	assert.Equal(t, []string{"invite", "resend_invitation", "accept_invitation"}, actions)
+}
+
+func TestUserInvitationExpires(t *testing.T) {
+	store := newMemoryUsers()
+	admin := store.add("admin@example.com", types.RoleAdmin)
+	svc := newUserAdminService(store)
+	now := time.Now()
+	svc.SetClock(func() time.Time { return now })
+	ctx := context.Background()
+
+	delivery, err := svc.Invite(ctx, userAdminOrgID, admin.ID, &types.InviteUserRequest{
+		Email: "late@example.com", Name: "Late User", Role: types.RoleViewer,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(7*24*time.Hour), delivery.ExpiresAt)
+
+	now = now.Add(8 * 24 * time.Hour)
+	err = svc.AcceptInvitation(ctx, &types.AcceptInvitationRequest{Token: linkToken(t, delivery.URL), Password: "password123"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed))
+}
+
+func TestUserDeactivation(t *testing.T) {
+	store := newMemoryUsers()
+	admin := store.add("admin@example.com", types.RoleAdmin)
+	member := store.add("member@example.com", types.RoleUser)
+	svc := newUserAdminService(store)
+	auditor := &recordingGrantAuditor{}
+	svc.SetAuditor(auditor)
+	ctx := context.Background()
+
+	_, err := svc.Deactivate(ctx, userAdminOrgID, admin.ID, admin.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "admins cannot deactivate themselves")
+
+	otherAdmin := store.add("other-admin@example.com", types.RoleAdmin)
+	_, err = svc.Deactivate(ctx, userAdminOrgID, otherAdmin.ID, admin.ID)
+	require.NoError(t, err, "another admin remains")
+	_, err = svc.Deactivate(ctx, userAdminOrgID, member.ID, otherAdmin.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeConflict), "the last active admin is kept")
+	_, err = svc.Update(ctx, userAdminOrgID, member.ID, otherAdmin.ID, &types.UpdateManagedUserRequest{Role: stringPtr(types.RoleViewer)})
+	assert.True(t, types.IsError(err, types.ErrCodeConflict), "the last active admin cannot be demoted")
+
+	deactivated, err := svc.Deactivate(ctx, userAdminOrgID, otherAdmin.ID, member.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.UserStatusDeactivated, deactivated.Status)
+	_, err = svc.SendPasswordReset(ctx, userAdminOrgID, otherAdmin.ID, member.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+
+	reactivated, err := svc.Reactivate(ctx, userAdminOrgID, otherAdmin.ID, member.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.UserStatusActive, reactivated.Status)
+	_, err = svc.Reactivate(ctx, userAdminOrgID, otherAdmin.ID, member.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeConflict))
+
+	_, err = svc.Get(ctx, "7a0e4c1d-9b2f-4d3e-8c5a-1f6b2e7d9a04", member.ID)
+	assert.True(t, types.IsError(err, types.ErrCodeNotFound), "users of other organizations are not found")
+
+	require.Len(t, auditor.logs, 3)
+	assert.Equal(t, "deactivate", auditor.logs[0].Action)
+	assert.Equal(t, otherAdmin.ID, auditor.logs[0].UserID, "the acting admin is audited")
+	assert.Equal(t, "reactivate", auditor.logs[2].Action)
+}
+
+func TestUserPasswordReset(t *testing.T) {
+	store := newMemoryUsers()
+	member := store.add("member@example.com", types.RoleUser)
+	svc := newUserAdminService(store)
+	mail := &recordingMailer{}
+	svc.SetMailer(mail)
+	ctx := context.Background()
+
+	require.NoError(t, svc.RequestPasswordReset(ctx, "nobody@example.com"))
+	assert.Empty(t, mail.messages, "unknown emails succeed silently")
+
+	require.NoError(t, svc.RequestPasswordReset(ctx, "MEMBER@example.com"))
+	require.Len(t, mail.messages, 1)
+	assert.Contains(t, mail.messages[0].Subject, "reset your password")
+	link := strings.Fields(mail.messages[0].Body[strings.Index(mail.messages[0].Body, "https://"):])[0]
+	assert.True(t, strings.HasPrefix(link, "https://gateway.example.com/reset-password?token="))
+
+	err := svc.AcceptInvitation(ctx, &types.AcceptInvitationRequest{Token: linkToken(t, link), Password: "newpassword"})
+	assert.True(t, types.IsError(err, types.ErrCodeValidationFailed), "reset tokens cannot accept invitations")
+
+	require.NoError(t, svc.ResetPassword(ctx, &types.ResetPasswordRequest{Token: linkToken(t, link), Password: "newpassword"}))
+	valid, _ := auth.NewPasswordHasher("bcrypt", 4, auth.Argon2Params{}).Verify("newpassword", store.users[member.ID].passwordHash)
+	assert.True(t, valid)
+}
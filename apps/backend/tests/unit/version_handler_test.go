@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/buildinfo"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticFlagLister []*types.FeatureFlagState
+
+func (s staticFlagLister) ListFlags(ctx context.Context, orgID string) []*types.FeatureFlagState {
+	return s
+}
+
+func TestBundledChangelogParses(t *testing.T) {
+	releases, err := buildinfo.Changelog()
+	require.NoError(t, err)
+	require.NotEmpty(t, releases)
+	for _, release := range releases {
+		for _, change := range release.Changes {
+			assert.NotEmpty(t, change.Title, "release %s has an untitled change", release.Version)
+			assert.Contains(t, []string{"added", "changed", "fixed", "security", "deprecated", "removed"}, change.Type)
+		}
+	}
+}
+
+func TestReleasesSince(t *testing.T) {
+	releases, err := buildinfo.ParseChangelog([]byte(`
+- version: 1.2.0
+  changes:
+    - type: added
+      title: C
+- version: 1.1.0
+- version: 1.0.0
+`))
+	require.NoError(t, err)
+	require.Len(t, releases, 3)
+	assert.NotNil(t, releases[1].Changes)
+
+	assert.Len(t, buildinfo.ReleasesSince(releases, ""), 3)
+	assert.Len(t, buildinfo.ReleasesSince(releases, "unknown"), 3)
+	since := buildinfo.ReleasesSince(releases, "1.1.0")
+	require.Len(t, since, 1)
+	assert.Equal(t, "1.2.0", since[0].Version)
+	assert.Empty(t, buildinfo.ReleasesSince(releases, "1.2.0"))
+
+	_, err = buildinfo.ParseChangelog([]byte(`- changes: []`))
+	require.Error(t, err)
+}
+
+func TestVersionHandlerReportsBuildAndEnabledFeatures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewVersionHandler(staticFlagLister{
+		{Key: types.FeatureSemanticSearch, Enabled: true},
+		{Key: types.FeatureAsyncExecution, Enabled: false},
+	})
+	router := gin.New()
+	router.GET("/api/version", handler.GetVersion)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/version", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data types.VersionResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.NotNil(t, body.Data.Build)
+	assert.NotEmpty(t, body.Data.Build.Version)
+	assert.NotEmpty(t, body.Data.Build.GoVersion)
+	assert.Equal(t, types.MCPProtocolVersion, body.Data.ProtocolVersion)
+	assert.Equal(t, []string{types.FeatureSemanticSearch}, body.Data.Features)
+}
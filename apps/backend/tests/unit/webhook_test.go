@@ -0,0 +1,468 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/airgap"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/services"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/pkg/requestsig"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWebhooks keeps webhooks and deliveries in memory, mirroring
+// WebhookModel
+type memoryWebhooks struct {
+	now        time.Time
+	webhooks   map[string]*types.Webhook
+	deliveries []*types.WebhookDelivery
+	backoffs   []time.Duration
+}
+
+func newMemoryWebhooks() *memoryWebhooks {
+	return &memoryWebhooks{
+		now:      time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		webhooks: map[string]*types.Webhook{},
+	}
+}
+
+func (m *memoryWebhooks) clock() time.Time { return m.now }
+
+func (m *memoryWebhooks) List(orgID string) ([]*types.Webhook, error) {
+	var webhooks []*types.Webhook
+	for _, webhook := range m.webhooks {
+		if webhook.OrganizationID == orgID {
+			copied := *webhook
+			webhooks = append(webhooks, &copied)
+		}
+	}
+	return webhooks, nil
+}
+
+func (m *memoryWebhooks) ListSubscribers(orgID, eventType string) ([]*types.Webhook, error) {
+	var webhooks []*types.Webhook
+	for _, webhook := range m.webhooks {
+		if webhook.OrganizationID == orgID && webhook.IsActive &&
+			(slices.Contains(webhook.EventTypes, eventType) || slices.Contains(webhook.EventTypes, types.WebhookEventAll)) {
+			copied := *webhook
+			webhooks = append(webhooks, &copied)
+		}
+	}
+	return webhooks, nil
+}
+
+func (m *memoryWebhooks) Get(orgID, id string) (*types.Webhook, error) {
+	webhook, ok := m.webhooks[id]
+	if !ok || webhook.OrganizationID != orgID {
+		return nil, nil
+	}
+	copied := *webhook
+	return &copied, nil
+}
+
+func (m *memoryWebhooks) Create(webhook *types.Webhook) error {
+	webhook.ID = uuid.NewString()
+	webhook.CreatedAt, webhook.UpdatedAt = m.now, m.now
+	copied := *webhook
+	m.webhooks[webhook.ID] = &copied
+	return nil
+}
+
+func (m *memoryWebhooks) Update(webhook *types.Webhook) error {
+	webhook.UpdatedAt = m.now
+	copied := *webhook
+	m.webhooks[webhook.ID] = &copied
+	return nil
+}
+
+func (m *memoryWebhooks) Delete(orgID, id string) (bool, error) {
+	if webhook, ok := m.webhooks[id]; !ok || webhook.OrganizationID != orgID {
+		return false, nil
+	}
+	delete(m.webhooks, id)
+	return true, nil
+}
+
+func (m *memoryWebhooks) CreateDelivery(delivery *types.WebhookDelivery) error {
+	next := m.now
+	delivery.ID = uuid.NewString()
+	delivery.Status = types.WebhookDeliveryPending
+	delivery.NextAttemptAt = &next
+	delivery.CreatedAt = m.now
+	copied := *delivery
+	m.deliveries = append(m.deliveries, &copied)
+	return nil
+}
+
+func (m *memoryWebhooks) ClaimDue(limit int, lease time.Duration) ([]*types.DueWebhookDelivery, error) {
+	var due []*types.DueWebhookDelivery
+	for _, delivery := range m.deliveries {
+		if len(due) == limit {
+			break
+		}
+		if delivery.Status != types.WebhookDeliveryPending || delivery.NextAttemptAt.After(m.now) {
+			continue
+		}
+		attempted, next := m.now, m.now.Add(lease)
+		delivery.Attempts++
+		delivery.LastAttemptAt, delivery.NextAttemptAt = &attempted, &next
+		webhook := m.webhooks[delivery.WebhookID]
+		due = append(due, &types.DueWebhookDelivery{
+			WebhookDelivery: *delivery,
+			URL:             webhook.URL,
+			Secret:          webhook.Secret,
+			WebhookActive:   webhook.IsActive,
+		})
+	}
+	return due, nil
+}
+
+func (m *memoryWebhooks) FinishDelivery(delivery *types.WebhookDelivery, retryAfter time.Duration) error {
+	for _, stored := range m.deliveries {
+		if stored.ID != delivery.ID {
+			continue
+		}
+		stored.Status, stored.ResponseStatus = delivery.Status, delivery.ResponseStatus
+		stored.LastError, stored.DurationMs = delivery.LastError, delivery.DurationMs
+		switch delivery.Status {
+		case types.WebhookDeliveryPending:
+			next := m.now.Add(retryAfter)
+			stored.NextAttemptAt = &next
+			m.backoffs = append(m.backoffs, retryAfter)
+		case types.WebhookDeliverySucceeded:
+			delivered := m.now
+			stored.DeliveredAt = &delivered
+		}
+	}
+	return nil
+}
+
+func (m *memoryWebhooks) GetDelivery(orgID, webhookID, id string) (*types.WebhookDelivery, error) {
+	for _, delivery := range m.deliveries {
+		if delivery.ID == id && delivery.WebhookID == webhookID && delivery.OrganizationID == orgID {
+			copied := *delivery
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryWebhooks) ListDeliveries(filter *types.WebhookDeliveryFilter) ([]*types.WebhookDelivery, error) {
+	deliveries := []*types.WebhookDelivery{}
+	for _, delivery := range m.deliveries {
+		if delivery.WebhookID == filter.WebhookID && (filter.Status == "" || delivery.Status == filter.Status) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (m *memoryWebhooks) PruneDeliveries(cutoff time.Time) (int64, error) {
+	kept := m.deliveries[:0]
+	for _, delivery := range m.deliveries {
+		if delivery.Status == types.WebhookDeliveryPending || !delivery.CreatedAt.Before(cutoff) {
+			kept = append(kept, delivery)
+		}
+	}
+	pruned := int64(len(m.deliveries) - len(kept))
+	m.deliveries = kept
+	return pruned, nil
+}
+
+// webhookReceiver records the events posted to it
+type webhookReceiver struct {
+	server *httptest.Server
+	events []types.WebhookEvent
+	status int
+	mu     sync.Mutex
+}
+
+func newWebhookReceiver(t *testing.T, store *memoryWebhooks, secret *string) *webhookReceiver {
+	receiver := &webhookReceiver{status: http.StatusOK}
+	receiver.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var event types.WebhookEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, event.Type, r.Header.Get("X-Omnimesh-Event"))
+		assert.NotEmpty(t, r.Header.Get("X-Omnimesh-Delivery"))
+		_, err = requestsig.Verify(r.Header.Get(requestsig.Header), body, func(keyID string) (*requestsig.Key, error) {
+			webhookID, _, _ := strings.Cut(keyID, ".")
+			if key := requestsig.NewHMACKey(webhookID, *secret); key.ID == keyID {
+				return key, nil
+			}
+			return nil, nil
+		}, 0, store.now)
+		assert.NoError(t, err, "deliveries carry a request signature")
+		assert.Empty(t, r.Header.Get(types.ThreatFeedSignatureHeader))
+
+		receiver.mu.Lock()
+		receiver.events = append(receiver.events, event)
+		status := receiver.status
+		receiver.mu.Unlock()
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			_, _ = w.Write([]byte("connect to db.internal:5432 refused"))
+		}
+	}))
+	t.Cleanup(receiver.server.Close)
+	return receiver
+}
+
+// allowLoopback lets outbound clients reach test servers, which listen on
+// the loopback address
+func allowLoopback(t *testing.T) *airgap.Policy {
+	policy, err := airgap.New(false, nil, []string{"127.0.0.1"})
+	require.NoError(t, err)
+	return policy
+}
+
+func TestWebhookRegistrationValidation(t *testing.T) {
+	store := newMemoryWebhooks()
+	service := services.NewWebhookServiceWithStore(store, services.WebhookSettings{})
+	ctx := context.Background()
+
+	webhook, err := service.Create(ctx, "org-1", "user-1", &types.CreateWebhookRequest{
+		Name:       "Pager",
+		URL:        "https://hooks.example.com/omnimesh",
+		EventTypes: []string{types.WebhookEventServerUnhealthy, types.WebhookEventAgentFailed, types.WebhookEventServerUnhealthy},
+	})
+	require.NoError(t, err)
+	assert.True(t, webhook.IsActive)
+	assert.Contains(t, webhook.Secret, "whsec_", "the secret is returned on creation")
+	assert.Equal(t, requestsig.NewHMACKey(webhook.ID, webhook.Secret).ID, webhook.SigningKeyID)
+	assert.Equal(t, []string{types.WebhookEventServerUnhealthy, types.WebhookEventAgentFailed}, webhook.EventTypes)
+
+	listed, err := service.List(ctx, "org-1")
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Empty(t, listed[0].Secret)
+	assert.Empty(t, listed[0].SigningKeyID)
+
+	rotated, err := service.RotateSecret(ctx, "org-1", webhook.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, webhook.Secret, rotated.Secret)
+	assert.NotEqual(t, webhook.SigningKeyID, rotated.SigningKeyID, "the key ID changes with the secret")
+
+	_, err = service.Create(ctx, "org-1", "user-1", &types.CreateWebhookRequest{
+		Name: "Bad", URL: "ftp://hooks.example.com", EventTypes: []string{types.WebhookEventAll},
+	})
+	assert.Error(t, err)
+	_, err = service.Create(ctx, "org-1", "user-1", &types.CreateWebhookRequest{
+		Name: "Bad", URL: "https://hooks.example.com", EventTypes: []string{"server.exploded"},
+	})
+	assert.Error(t, err)
+
+	// Other organizations cannot see or change the webhook
+	_, err = service.Get(ctx, "org-2", webhook.ID)
+	assert.Error(t, err)
+	assert.Error(t, service.Delete(ctx, "org-2", webhook.ID))
+
+	// Offline gateways refuse webhooks to hosts they may not reach
+	policy, err := airgap.New(true, nil, []string{"hooks.internal"})
+	require.NoError(t, err)
+	service.SetEgressPolicy(policy)
+	_, err = service.Update(ctx, "org-1", webhook.ID, &types.UpdateWebhookRequest{URL: stringPtr("https://hooks.example.com/other")})
+	assert.Error(t, err)
+	_, err = service.Update(ctx, "org-1", webhook.ID, &types.UpdateWebhookRequest{URL: stringPtr("https://hooks.internal/omnimesh")})
+	assert.NoError(t, err)
+
+	// Only allow-listed hosts inside the gateway's network may be used
+	for _, internal := range []string{"http://169.254.169.254/latest/meta-data", "http://10.0.0.5/hook",
+		"http://localhost:8080/hook", "http://[::1]/hook", "http://metadata.internal/hook"} {
+		_, err = service.Update(ctx, "org-1", webhook.ID, &types.UpdateWebhookRequest{URL: stringPtr(internal)})
+		assert.Error(t, err, internal)
+	}
+}
+
+func TestWebhookDeliveryRefusesInternalAddresses(t *testing.T) {
+	store := newMemoryWebhooks()
+	service := services.NewWebhookServiceWithStore(store, services.WebhookSettings{MaxAttempts: 1})
+	service.SetClock(store.clock)
+	ctx := context.Background()
+
+	var secret string
+	receiver := newWebhookReceiver(t, store, &secret)
+	internalHits := 0
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internalHits++
+	}))
+	t.Cleanup(internal.Close)
+
+	// A URL that resolves to an internal address once registered is
+	// refused when the delivery connects
+	webhook := &types.Webhook{OrganizationID: "org-1", Name: "Rebound", URL: receiver.server.URL,
+		EventTypes: []string{types.WebhookEventAll}, IsActive: true, Secret: "whsec_test"}
+	require.NoError(t, store.Create(webhook))
+	_, err := service.Test(ctx, "org-1", webhook.ID)
+	require.NoError(t, err)
+	_, err = service.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "webhook URL does not resolve to a public address", store.deliveries[0].LastError)
+	assert.Empty(t, receiver.events)
+
+	// Redirects to internal hosts are not followed
+	redirecting := httptest.NewServer(http.RedirectHandler(strings.Replace(internal.URL, "127.0.0.1", "localhost", 1), http.StatusFound))
+	t.Cleanup(redirecting.Close)
+	service.SetEgressPolicy(allowLoopback(t))
+	webhook.URL = redirecting.URL
+	require.NoError(t, store.Update(webhook))
+	_, err = service.Test(ctx, "org-1", webhook.ID)
+	require.NoError(t, err)
+	_, err = service.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, types.WebhookDeliveryFailed, store.deliveries[1].Status)
+	assert.Zero(t, internalHits)
+}
+
+func TestWebhookEventsFilteredAndSigned(t *testing.T) {
+	store := newMemoryWebhooks()
+	service := services.NewWebhookServiceWithStore(store, services.WebhookSettings{})
+	service.SetClock(store.clock)
+	service.SetEgressPolicy(allowLoopback(t))
+	ctx := context.Background()
+
+	var secret string
+	receiver := newWebhookReceiver(t, store, &secret)
+	register := func(eventTypes []string, active bool) *types.Webhook {
+		webhook, err := service.Create(ctx, "org-1", "user-1", &types.CreateWebhookRequest{
+			Name: "Receiver", URL: receiver.server.URL, EventTypes: eventTypes, IsActive: &active,
+		})
+		require.NoError(t, err)
+		return webhook
+	}
+	agents := register([]string{types.WebhookEventAgentFailed}, true)
+	register([]string{types.WebhookEventConfigImported}, true)
+	register([]string{types.WebhookEventAll}, false)
+	secret = agents.Secret
+
+	require.NoError(t, service.Emit(ctx, "org-1", types.WebhookEventAgentFailed, map[string]interface{}{"agent_name": "triage"}))
+	require.NoError(t, service.Emit(ctx, "org-2", types.WebhookEventAgentFailed, nil))
+	require.Len(t, store.deliveries, 1, "only the active subscriber of the organization")
+
+	attempted, err := service.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	require.Len(t, receiver.events, 1)
+	event := receiver.events[0]
+	assert.Equal(t, types.WebhookEventAgentFailed, event.Type)
+	assert.Equal(t, "org-1", event.OrganizationID)
+	assert.Equal(t, "triage", event.Data["agent_name"])
+
+	delivery := store.deliveries[0]
+	assert.Equal(t, types.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+	assert.NotNil(t, delivery.DeliveredAt)
+
+	// Redelivery posts the same event again
+	redelivered, err := service.Redeliver(ctx, "org-1", agents.ID, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, event.ID, redelivered.EventID)
+	_, err = service.DeliverDue(ctx)
+	require.NoError(t, err)
+	require.Len(t, receiver.events, 2)
+	assert.Equal(t, event.ID, receiver.events[1].ID)
+
+	attempted, err = service.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, attempted)
+}
+
+func TestWebhookDeliveryRetriesWithBackoff(t *testing.T) {
+	store := newMemoryWebhooks()
+	service := services.NewWebhookServiceWithStore(store, services.WebhookSettings{MaxAttempts: 3, RetryBackoff: time.Minute})
+	service.SetClock(store.clock)
+	service.SetEgressPolicy(allowLoopback(t))
+	ctx := context.Background()
+
+	var secret string
+	receiver := newWebhookReceiver(t, store, &secret)
+	receiver.status = http.StatusServiceUnavailable
+	webhook, err := service.Create(ctx, "org-1", "user-1", &types.CreateWebhookRequest{
+		Name: "Flaky", URL: receiver.server.URL, EventTypes: []string{types.WebhookEventAll},
+	})
+	require.NoError(t, err)
+	secret = webhook.Secret
+
+	_, err = service.Test(ctx, "org-1", webhook.ID)
+	require.NoError(t, err)
+	delivery := store.deliveries[0]
+
+	_, err = service.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, types.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, "webhook returned HTTP 503", delivery.LastError, "the response body is not kept")
+
+	// Not retried before the backoff has passed
+	attempted, err := service.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, attempted)
+
+	store.now = store.now.Add(time.Minute)
+	_, err = service.DeliverDue(ctx)
+	require.NoError(t, err)
+	store.now = store.now.Add(2 * time.Minute)
+	_, err = service.DeliverDue(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute}, store.backoffs)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, types.WebhookDeliveryFailed, delivery.Status, "attempts ran out")
+	assert.Len(t, receiver.events, 3)
+
+	// Receivers refusing an event are not retried
+	receiver.status = http.StatusBadRequest
+	_, err = service.Test(ctx, "org-1", webhook.ID)
+	require.NoError(t, err)
+	_, err = service.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, types.WebhookDeliveryFailed, store.deliveries[1].Status)
+	assert.Equal(t, 1, store.deliveries[1].Attempts)
+
+	failed, err := service.ListDeliveries(ctx, "org-1", &types.WebhookDeliveryFilter{WebhookID: webhook.ID, Status: types.WebhookDeliveryFailed})
+	require.NoError(t, err)
+	assert.Len(t, failed, 2)
+}
+
+// recordingEmitter records the webhook events published to it
+type recordingEmitter struct {
+	types []string
+}
+
+func (r *recordingEmitter) Emit(ctx context.Context, orgID, eventType string, data map[string]interface{}) error {
+	r.types = append(r.types, eventType)
+	return nil
+}
+
+func TestNotifyPublishesWebhookEvents(t *testing.T) {
+	store := newMemoryNotificationStore()
+	service := services.NewNotificationServiceWithStore(store)
+	emitter := &recordingEmitter{}
+	service.SetWebhooks(emitter)
+	event := &types.NotificationEvent{
+		OrganizationID: flagOrgA,
+		Type:           types.NotificationServerUnhealthy,
+		Title:          "Server weather is unhealthy",
+		DedupKey:       "server_unhealthy:srv-1",
+	}
+
+	_, err := service.Notify(context.Background(), event)
+	require.NoError(t, err)
+	// Suppressed repeats are not published again
+	_, err = service.Notify(context.Background(), event)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{types.WebhookEventServerUnhealthy}, emitter.types)
+}
@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/server/handlers"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/transport"
+	"github.com/omnimesh-labs/omnimesh-gateway/apps/backend/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func subprotocolRequest(protocols ...string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	if len(protocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(protocols, ", "))
+	}
+	return req
+}
+
+func TestNegotiateSubprotocol(t *testing.T) {
+	t.Run("no offer is accepted when not required", func(t *testing.T) {
+		proto, err := transport.NegotiateSubprotocol(subprotocolRequest(), false)
+		require.NoError(t, err)
+		assert.Empty(t, proto)
+	})
+
+	t.Run("no offer is rejected when required", func(t *testing.T) {
+		_, err := transport.NegotiateSubprotocol(subprotocolRequest(), true)
+		require.Error(t, err)
+		assert.True(t, types.IsError(err, types.ErrCodeUnsupportedSubprotocol))
+	})
+
+	t.Run("mismatch is rejected", func(t *testing.T) {
+		_, err := transport.NegotiateSubprotocol(subprotocolRequest("graphql-ws"), false)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, types.GetStatusCode(err))
+		assert.Contains(t, err.Error(), "graphql-ws")
+		assert.Contains(t, err.Error(), "mcp")
+	})
+
+	t.Run("server preference wins", func(t *testing.T) {
+		proto, err := transport.NegotiateSubprotocol(subprotocolRequest("mcp", "mcp.2024-11-05"), true)
+		require.NoError(t, err)
+		assert.Equal(t, "mcp.2024-11-05", proto)
+	})
+}
+
+func newEndpointWebSocketServer(t *testing.T, required bool) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", func(c *gin.Context) {
+		c.Set("namespace", &types.Namespace{ID: "namespace-1"})
+		c.Next()
+	}, handlers.HandleEndpointWebSocket(nil, required))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestEndpointWebSocketSubprotocol(t *testing.T) {
+	server := newEndpointWebSocketServer(t, true)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	t.Run("negotiates mcp", func(t *testing.T) {
+		dialer := websocket.Dialer{Subprotocols: []string{"mcp"}}
+		conn, resp, err := dialer.Dial(url, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+		assert.Equal(t, "mcp", conn.Subprotocol())
+	})
+
+	t.Run("rejects mismatch with supported list", func(t *testing.T) {
+		dialer := websocket.Dialer{Subprotocols: []string{"wamp"}}
+		_, resp, err := dialer.Dial(url, nil)
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, strings.Join(transport.WebSocketSubprotocols, ", "), resp.Header.Get(transport.WebSocketProtocolsHeader))
+
+		var body types.ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, types.ErrCodeUnsupportedSubprotocol, body.Error.Code)
+	})
+
+	t.Run("rejects missing subprotocol when required", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -608,7 +608,7 @@ export interface Policy {
 	organization_id: string;
 	name: string;
 	description?: string;
-	type: 'access' | 'rate_limit' | 'security';
+	type: 'access' | 'rate_limit' | 'security' | 'tool';
 	priority: number;
 	conditions: Record<string, any>;
 	actions: Record<string, any>;
@@ -620,7 +620,7 @@ export interface Policy {
 export interface CreatePolicyRequest {
 	name: string;
 	description?: string;
-	type: 'access' | 'rate_limit' | 'security';
+	type: 'access' | 'rate_limit' | 'security' | 'tool';
 	priority?: number;
 	conditions: Record<string, any>;
 	actions: Record<string, any>;